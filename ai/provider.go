@@ -70,6 +70,18 @@ type TokenUsage struct {
 	OutputTokens int `json:"outputTokens"`
 }
 
+// ModelPrice is the price of a model's tokens, in the same currency units as
+// ModelInfo costs.
+type ModelPrice struct {
+	InputPer1K  float64 `json:"inputPer1K" yaml:"inputPer1K"`
+	OutputPer1K float64 `json:"outputPer1K" yaml:"outputPer1K"`
+}
+
+// Cost returns the cost of usage at this price.
+func (p ModelPrice) Cost(usage TokenUsage) float64 {
+	return float64(usage.InputTokens)/1000*p.InputPer1K + float64(usage.OutputTokens)/1000*p.OutputPer1K
+}
+
 // StreamChunk is one piece of a streaming completion response.
 type StreamChunk struct {
 	Content string `json:"content,omitempty"`
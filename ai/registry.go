@@ -13,6 +13,7 @@ type AIModelRegistry struct {
 	models    map[string]ModelInfo     // modelID -> info
 	defaults  map[string]string        // use-case -> preferred modelID
 	overrides map[string]ModelOverride // "tenant:modelID" -> override
+	prices    map[string]ModelPrice    // modelID -> configured price
 }
 
 // ModelOverride allows per-tenant or per-user overrides for model parameters.
@@ -29,6 +30,7 @@ func NewAIModelRegistry() *AIModelRegistry {
 		models:    make(map[string]ModelInfo),
 		defaults:  make(map[string]string),
 		overrides: make(map[string]ModelOverride),
+		prices:    make(map[string]ModelPrice),
 	}
 }
 
@@ -132,6 +134,36 @@ func (r *AIModelRegistry) RemoveOverride(key string) {
 	delete(r.overrides, key)
 }
 
+// SetPrice sets the token price of a model, overriding the costs its
+// provider reports in ModelInfo.
+func (r *AIModelRegistry) SetPrice(modelID string, price ModelPrice) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prices[modelID] = price
+}
+
+// Price returns the token price of a model: the price set with SetPrice, or
+// else the costs in the model's ModelInfo. It reports false when the model
+// has no known price.
+func (r *AIModelRegistry) Price(modelID string) (ModelPrice, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if p, ok := r.prices[modelID]; ok {
+		return p, true
+	}
+	if m, ok := r.models[modelID]; ok && (m.CostPer1KInput != 0 || m.CostPer1KOutput != 0) {
+		return ModelPrice{InputPer1K: m.CostPer1KInput, OutputPer1K: m.CostPer1KOutput}, true
+	}
+	return ModelPrice{}, false
+}
+
+// Cost returns the cost of usage at the model's price, or 0 when the model
+// has no known price.
+func (r *AIModelRegistry) Cost(modelID string, usage TokenUsage) float64 {
+	price, _ := r.Price(modelID)
+	return price.Cost(usage)
+}
+
 // ProviderForModel returns the provider that owns a given model ID.
 func (r *AIModelRegistry) ProviderForModel(modelID string) (AIProvider, bool) {
 	r.mu.RLock()
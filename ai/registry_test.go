@@ -2,6 +2,7 @@ package ai
 
 import (
 	"context"
+	"math"
 	"testing"
 )
 
@@ -280,3 +281,32 @@ func TestMultipleProvidersModelIndexing(t *testing.T) {
 		}
 	}
 }
+
+func TestModelPricing(t *testing.T) {
+	reg := NewAIModelRegistry()
+	_ = reg.RegisterProvider(&mockProvider{name: "p1", models: []ModelInfo{
+		{ID: "priced", Provider: "p1", CostPer1KInput: 0.003, CostPer1KOutput: 0.015},
+		{ID: "unpriced", Provider: "p1"},
+	}})
+	usage := TokenUsage{InputTokens: 2000, OutputTokens: 1000}
+
+	if got, want := reg.Cost("priced", usage), 0.021; math.Abs(got-want) > 1e-9 {
+		t.Errorf("provider price: cost = %v, want %v", got, want)
+	}
+	if _, ok := reg.Price("unpriced"); ok {
+		t.Error("Price should report false for a model without costs")
+	}
+	if got := reg.Cost("unpriced", usage); got != 0 {
+		t.Errorf("unpriced model: cost = %v, want 0", got)
+	}
+
+	// A configured price overrides the provider's, including for unknown models.
+	reg.SetPrice("priced", ModelPrice{InputPer1K: 0.001, OutputPer1K: 0.002})
+	reg.SetPrice("self-hosted", ModelPrice{InputPer1K: 0.0005})
+	if got, want := reg.Cost("priced", usage), 0.004; math.Abs(got-want) > 1e-9 {
+		t.Errorf("configured price: cost = %v, want %v", got, want)
+	}
+	if got, want := reg.Cost("self-hosted", usage), 0.001; math.Abs(got-want) > 1e-9 {
+		t.Errorf("self-hosted: cost = %v, want %v", got, want)
+	}
+}
@@ -8,10 +8,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/GoCodeAlone/workflow/auth/password"
 	"github.com/GoCodeAlone/workflow/store"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
 )
 
 // AuthHandler handles authentication endpoints.
@@ -22,6 +22,8 @@ type AuthHandler struct {
 	issuer     string
 	accessTTL  time.Duration
 	refreshTTL time.Duration
+	hasher     *password.Hasher // bcrypt at the default cost when nil
+	policy     *password.Policy // any password when nil
 }
 
 // NewAuthHandler creates a new AuthHandler.
@@ -58,8 +60,11 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		WriteError(w, http.StatusBadRequest, "email and password are required")
 		return
 	}
+	if !h.checkPasswordPolicy(w, req.Password, req.Email) {
+		return
+	}
 
-	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hash, err := h.passwordHasher().Hash(req.Password)
 	if err != nil {
 		WriteError(w, http.StatusInternalServerError, "internal error")
 		return
@@ -69,7 +74,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	user := &store.User{
 		ID:           uuid.New(),
 		Email:        req.Email,
-		PasswordHash: string(hash),
+		PasswordHash: hash,
 		DisplayName:  req.DisplayName,
 		Active:       true,
 		CreatedAt:    now,
@@ -115,12 +120,19 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		WriteError(w, http.StatusUnauthorized, "invalid credentials")
 		return
 	}
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+	match, rehash := h.passwordHasher().Verify(user.PasswordHash, req.Password)
+	if !match {
 		WriteError(w, http.StatusUnauthorized, "invalid credentials")
 		return
 	}
 
-	// Update last login
+	// Update last login, upgrading a hash made with another algorithm or
+	// other parameters than the configured ones.
+	if rehash {
+		if hash, err := h.passwordHasher().Hash(req.Password); err == nil {
+			user.PasswordHash = hash
+		}
+	}
 	now := time.Now()
 	user.LastLoginAt = &now
 	user.UpdatedAt = now
@@ -251,6 +263,71 @@ func (h *AuthHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
 	WriteJSON(w, http.StatusOK, user)
 }
 
+// ChangePassword handles PUT /api/v1/auth/password.
+func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	if user == nil {
+		WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	var req struct {
+		CurrentPassword string `json:"current_password"` //nolint:gosec // G117: request DTO field
+		NewPassword     string `json:"new_password"`     //nolint:gosec // G117: request DTO field
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.CurrentPassword == "" || req.NewPassword == "" {
+		WriteError(w, http.StatusBadRequest, "current_password and new_password are required")
+		return
+	}
+	if match, _ := h.passwordHasher().Verify(user.PasswordHash, req.CurrentPassword); !match {
+		WriteError(w, http.StatusUnauthorized, "invalid credentials")
+		return
+	}
+	if !h.checkPasswordPolicy(w, req.NewPassword, user.Email) {
+		return
+	}
+
+	hash, err := h.passwordHasher().Hash(req.NewPassword)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	user.PasswordHash = hash
+	user.UpdatedAt = time.Now()
+	if err := h.users.Update(r.Context(), user); err != nil {
+		WriteError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	WriteJSON(w, http.StatusOK, map[string]string{"status": "password changed"})
+}
+
+func (h *AuthHandler) passwordHasher() *password.Hasher {
+	if h.hasher != nil {
+		return h.hasher
+	}
+	return password.DefaultHasher()
+}
+
+// checkPasswordPolicy validates a new password for the account with the
+// given email. When it fails the policy, it writes a 400 response listing
+// the violations as field errors and returns false.
+func (h *AuthHandler) checkPasswordPolicy(w http.ResponseWriter, pw, email string) bool {
+	err := h.policy.Validate(pw, email)
+	if err == nil {
+		return true
+	}
+	var pe *password.PolicyError
+	if !errors.As(err, &pe) {
+		WriteError(w, http.StatusInternalServerError, "internal error")
+		return false
+	}
+	WriteFieldErrors(w, http.StatusBadRequest, "password does not meet the password policy", pe.Violations)
+	return false
+}
+
 // tokenResponse is the JSON shape returned to callers.
 type tokenResponse struct {
 	AccessToken  string `json:"access_token"`  //nolint:gosec // G117: token response field
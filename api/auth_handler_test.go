@@ -7,9 +7,11 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/GoCodeAlone/workflow/auth/password"
 	"github.com/GoCodeAlone/workflow/store"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
@@ -425,6 +427,85 @@ func TestUpdateMe(t *testing.T) {
 	}
 }
 
+func TestRegisterPasswordPolicy(t *testing.T) {
+	h, users, _ := newTestAuthHandler()
+	h.policy = &password.Policy{MinLength: 12, RequireUppercase: true, DisallowEmail: true}
+
+	req := httptest.NewRequest("POST", "/api/v1/auth/register",
+		makeJSON(map[string]string{"email": "weak@example.com", "password": "weak"}))
+	w := httptest.NewRecorder()
+	h.Register(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Error  string               `json:"error"`
+		Errors []password.Violation `json:"errors"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Error != "password does not meet the password policy" || len(body.Errors) != 2 ||
+		body.Errors[0].Code != password.CodeTooShort || body.Errors[1].Code != password.CodeMissingUppercase {
+		t.Fatalf("unexpected response %+v", body)
+	}
+	if _, err := users.GetByEmail(context.Background(), "weak@example.com"); err == nil {
+		t.Fatal("expected no user to be created")
+	}
+}
+
+func TestLoginRehashesPassword(t *testing.T) {
+	h, users, _ := newTestAuthHandler()
+	hash, _ := bcrypt.GenerateFromPassword([]byte("Password123!"), bcrypt.MinCost)
+	_ = users.Create(context.Background(), &store.User{
+		ID: uuid.New(), Email: "rehash@example.com", PasswordHash: string(hash), Active: true,
+	})
+	h.hasher, _ = password.NewHasher(password.HashConfig{Algorithm: password.AlgorithmArgon2id, Memory: 64, Iterations: 1, Parallelism: 1})
+
+	req := httptest.NewRequest("POST", "/api/v1/auth/login",
+		makeJSON(map[string]string{"email": "rehash@example.com", "password": "Password123!"}))
+	w := httptest.NewRecorder()
+	h.Login(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	user, _ := users.GetByEmail(context.Background(), "rehash@example.com")
+	if !strings.HasPrefix(user.PasswordHash, "$argon2id$") {
+		t.Fatalf("expected the hash to be upgraded to argon2id, got %q", user.PasswordHash)
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	h, users, _ := newTestAuthHandler()
+	h.policy = &password.Policy{MinLength: 12}
+	hash, _ := bcrypt.GenerateFromPassword([]byte("old-password"), bcrypt.MinCost)
+	user := &store.User{ID: uuid.New(), Email: "change@example.com", PasswordHash: string(hash), Active: true}
+	_ = users.Create(context.Background(), user)
+
+	change := func(current, next string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("PUT", "/api/v1/auth/password",
+			makeJSON(map[string]string{"current_password": current, "new_password": next}))
+		req = req.WithContext(SetUserContext(req.Context(), user))
+		w := httptest.NewRecorder()
+		h.ChangePassword(w, req)
+		return w
+	}
+	if w := change("wrong", "new-password-22"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", w.Code)
+	}
+	if w := change("old-password", "short"); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), `"code":"too_short"`) {
+		t.Fatalf("expected a too_short field error, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := change("old-password", "new-password-22"); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	stored, _ := users.Get(context.Background(), user.ID)
+	if bcrypt.CompareHashAndPassword([]byte(stored.PasswordHash), []byte("new-password-22")) != nil {
+		t.Fatal("expected the new password to be stored")
+	}
+}
+
 // --- Middleware tests ---
 
 func TestRequireAuth(t *testing.T) {
@@ -7,8 +7,9 @@ import (
 
 // envelope is a standard JSON response wrapper.
 type envelope struct {
-	Data  any    `json:"data,omitempty"`
-	Error string `json:"error,omitempty"`
+	Data   any    `json:"data,omitempty"`
+	Error  string `json:"error,omitempty"`
+	Errors any    `json:"errors,omitempty"`
 }
 
 // paginatedEnvelope wraps a list response with pagination metadata.
@@ -33,6 +34,14 @@ func WriteError(w http.ResponseWriter, status int, message string) {
 	_ = json.NewEncoder(w).Encode(envelope{Error: message})
 }
 
+// WriteFieldErrors writes a JSON error response that lists the invalid
+// request fields under "errors".
+func WriteFieldErrors(w http.ResponseWriter, status int, message string, errs any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(envelope{Error: message, Errors: errs})
+}
+
 // WritePaginated writes a paginated JSON response.
 func WritePaginated(w http.ResponseWriter, items any, total, page, pageSize int) {
 	w.Header().Set("Content-Type", "application/json")
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/GoCodeAlone/workflow/auth/password"
 	"github.com/GoCodeAlone/workflow/iam"
 	"github.com/GoCodeAlone/workflow/store"
 )
@@ -20,6 +21,14 @@ type Config struct {
 	// Defaults to 10 when zero.
 	AuthRateLimit int
 
+	// PasswordHasher hashes new passwords and replaces older hashes on
+	// login. Defaults to bcrypt at the default cost when nil.
+	PasswordHasher *password.Hasher
+
+	// PasswordPolicy is checked on registration and password changes.
+	// Any password is accepted when nil.
+	PasswordPolicy *password.Policy
+
 	// OAuth providers keyed by provider name (e.g. "google", "okta").
 	OAuthProviders map[string]*OAuthProviderConfig
 
@@ -58,6 +67,7 @@ func NewRouterWithIAM(stores Stores, cfg Config, iamResolver *iam.IAMResolver) h
 
 	// --- Auth ---
 	authH := NewAuthHandler(stores.Users, stores.Sessions, secret, cfg.JWTIssuer, cfg.AccessTTL, cfg.RefreshTTL)
+	authH.hasher, authH.policy = cfg.PasswordHasher, cfg.PasswordPolicy
 	authRL := mw.RateLimit(cfg.AuthRateLimit)
 	mux.Handle("POST /api/v1/auth/register", authRL(http.HandlerFunc(authH.Register)))
 	mux.Handle("POST /api/v1/auth/login", authRL(http.HandlerFunc(authH.Login)))
@@ -65,6 +75,7 @@ func NewRouterWithIAM(stores Stores, cfg Config, iamResolver *iam.IAMResolver) h
 	mux.Handle("POST /api/v1/auth/logout", mw.RequireAuth(http.HandlerFunc(authH.Logout)))
 	mux.Handle("GET /api/v1/auth/me", mw.RequireAuth(http.HandlerFunc(authH.Me)))
 	mux.Handle("PUT /api/v1/auth/me", mw.RequireAuth(http.HandlerFunc(authH.UpdateMe)))
+	mux.Handle("PUT /api/v1/auth/password", authRL(mw.RequireAuth(http.HandlerFunc(authH.ChangePassword))))
 
 	// --- OAuth2 ---
 	if len(cfg.OAuthProviders) > 0 {
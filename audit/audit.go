@@ -3,6 +3,7 @@ package audit
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"log/slog"
 	"os"
@@ -21,6 +22,7 @@ const (
 	EventDataAccess   EventType = "data_access"
 	EventConfigChange EventType = "config_change"
 	EventComponentOp  EventType = "component_op"
+	EventBusiness     EventType = "business"
 )
 
 // Event is a single audit log entry.
@@ -36,6 +38,12 @@ type Event struct {
 	Metadata  map[string]any `json:"metadata,omitempty"`
 }
 
+// Store persists audit events. Record must be synchronous: a nil error means
+// the event has been durably written.
+type Store interface {
+	Record(ctx context.Context, event Event) error
+}
+
 // Logger records security-relevant audit events as structured JSON.
 type Logger struct {
 	mu     sync.Mutex
@@ -56,25 +64,33 @@ func NewLogger(w io.Writer) *Logger {
 }
 
 // Log records an audit event. It is safe for concurrent use.
-func (l *Logger) Log(_ context.Context, event Event) {
+func (l *Logger) Log(ctx context.Context, event Event) {
+	if err := l.Record(ctx, event); err != nil {
+		l.slog.Error("failed to record audit event", "error", err)
+	}
+}
+
+// Record writes an audit event and reports write failures. It implements
+// Store and is safe for concurrent use.
+func (l *Logger) Record(_ context.Context, event Event) error {
 	if event.Timestamp.IsZero() {
 		event.Timestamp = time.Now().UTC()
 	}
 
-	l.mu.Lock()
-	defer l.mu.Unlock()
-
 	data, err := json.Marshal(event)
 	if err != nil {
-		l.slog.Error("failed to marshal audit event", "error", err)
-		return
+		return fmt.Errorf("marshal audit event: %w", err)
 	}
 
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
 	// Write one JSON line per event
 	data = append(data, '\n')
 	if _, err := l.writer.Write(data); err != nil {
-		l.slog.Error("failed to write audit event", "error", err)
+		return fmt.Errorf("write audit event: %w", err)
 	}
+	return nil
 }
 
 // LogAuth records an authentication event.
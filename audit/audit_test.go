@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"strings"
 	"testing"
 	"time"
@@ -297,3 +298,15 @@ func TestLogger_ConcurrentWrites(t *testing.T) {
 		t.Errorf("expected 10 lines from concurrent writes, got %d", len(lines))
 	}
 }
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestLogger_RecordReturnsWriteError(t *testing.T) {
+	var store Store = NewLogger(failingWriter{})
+	err := store.Record(context.Background(), Event{Type: EventBusiness, Action: "approve"})
+	if err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Errorf("expected write error, got %v", err)
+	}
+}
@@ -0,0 +1,177 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1" //nolint:gosec // G505: SHA-1 is the hash breach lists are published with
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RangeDir checks passwords against a local copy of the Have I Been Pwned
+// Pwned Passwords range files. The directory holds one file per 5-character
+// uppercase SHA-1 prefix, named <PREFIX>.txt or <PREFIX>, each listing the
+// remaining 35 characters of the breached hashes as SUFFIX:COUNT lines, the
+// format of the k-anonymity range API and of its downloader. Only the file
+// for the password's prefix is read on each check.
+type RangeDir struct {
+	dir string
+}
+
+// NewRangeDir creates a RangeDir for dir, which must exist.
+func NewRangeDir(dir string) (*RangeDir, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("password: breached password range directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("password: breached password range directory %s is not a directory", dir)
+	}
+	return &RangeDir{dir: dir}, nil
+}
+
+// IsBreached implements BreachedChecker. A missing range file means no
+// breached hash has the password's prefix. Suffixes with a count of 0,
+// which padded range responses contain, are ignored.
+func (d *RangeDir) IsBreached(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password)) //nolint:gosec // G401: see import
+	digest := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := digest[:5], digest[5:]
+
+	f, err := os.Open(filepath.Join(d.dir, prefix+".txt"))
+	if errors.Is(err, os.ErrNotExist) {
+		f, err = os.Open(filepath.Join(d.dir, prefix))
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		hash, count, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if strings.EqualFold(hash, suffix) {
+			return strings.TrimLeft(count, "0") != "", nil
+		}
+	}
+	return false, scanner.Err()
+}
+
+// bloomMagic starts every bloom filter file.
+const bloomMagic = "WFPWBF01"
+
+// BloomFilter is an offline set of breached password SHA-1 hashes with a
+// configurable false positive rate, compact enough to ship with a server.
+// Lookups use double hashing over the SHA-1 digest: the i-th of k bit
+// indexes is (h1 + i*h2) mod m, where h1 and h2 are the big-endian uint64
+// values of digest bytes 0-7 and 8-15 (h2 with its lowest bit set). It is
+// stored as the magic "WFPWBF01", k as a big-endian uint32, m as a
+// big-endian uint64 and the m bits, lowest bit first.
+type BloomFilter struct {
+	k    uint32
+	m    uint64
+	bits []byte
+}
+
+// NewBloomFilter creates an empty filter sized for n hashes at the given
+// false positive rate.
+func NewBloomFilter(n int, falsePositiveRate float64) (*BloomFilter, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("password: bloom filter needs room for at least 1 hash")
+	}
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		return nil, fmt.Errorf("password: bloom filter false positive rate must be between 0 and 1")
+	}
+	m := uint64(math.Ceil(-float64(n) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	k := uint32(max(1, math.Round(float64(m)/float64(n)*math.Ln2)))
+	return &BloomFilter{k: k, m: m, bits: make([]byte, (m+7)/8)}, nil
+}
+
+// LoadBloomFilter reads a filter written by BloomFilter.WriteTo.
+func LoadBloomFilter(path string) (*BloomFilter, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("password: breached password bloom filter: %w", err)
+	}
+	defer f.Close()
+	b, err := ReadBloomFilter(bufio.NewReader(f))
+	if err != nil {
+		return nil, fmt.Errorf("password: breached password bloom filter %s: %w", path, err)
+	}
+	return b, nil
+}
+
+// ReadBloomFilter reads a filter written by BloomFilter.WriteTo.
+func ReadBloomFilter(r io.Reader) (*BloomFilter, error) {
+	var header [len(bloomMagic) + 12]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	if string(header[:len(bloomMagic)]) != bloomMagic {
+		return nil, fmt.Errorf("not a bloom filter file")
+	}
+	k := binary.BigEndian.Uint32(header[len(bloomMagic):])
+	m := binary.BigEndian.Uint64(header[len(bloomMagic)+4:])
+	if k == 0 || m == 0 || m > 1<<40 {
+		return nil, fmt.Errorf("invalid bloom filter size")
+	}
+	bits := make([]byte, (m+7)/8)
+	if _, err := io.ReadFull(r, bits); err != nil {
+		return nil, fmt.Errorf("read bits: %w", err)
+	}
+	return &BloomFilter{k: k, m: m, bits: bits}, nil
+}
+
+// WriteTo writes the filter in the format ReadBloomFilter reads.
+func (b *BloomFilter) WriteTo(w io.Writer) (int64, error) {
+	header := make([]byte, 0, len(bloomMagic)+12)
+	header = append(header, bloomMagic...)
+	header = binary.BigEndian.AppendUint32(header, b.k)
+	header = binary.BigEndian.AppendUint64(header, b.m)
+	n, err := w.Write(header)
+	if err != nil {
+		return int64(n), err
+	}
+	n2, err := w.Write(b.bits)
+	return int64(n + n2), err
+}
+
+// Add adds a password to the filter.
+func (b *BloomFilter) Add(password string) {
+	b.AddSHA1(sha1.Sum([]byte(password))) //nolint:gosec // G401: see import
+}
+
+// AddSHA1 adds the SHA-1 digest of a password, as listed in breach
+// corpora, to the filter.
+func (b *BloomFilter) AddSHA1(digest [sha1.Size]byte) {
+	h1, h2 := bloomHashes(digest)
+	for i := range uint64(b.k) {
+		idx := (h1 + i*h2) % b.m
+		b.bits[idx/8] |= 1 << (idx % 8)
+	}
+}
+
+// IsBreached implements BreachedChecker. It never returns an error.
+func (b *BloomFilter) IsBreached(password string) (bool, error) {
+	h1, h2 := bloomHashes(sha1.Sum([]byte(password))) //nolint:gosec // G401: see import
+	for i := range uint64(b.k) {
+		idx := (h1 + i*h2) % b.m
+		if b.bits[idx/8]&(1<<(idx%8)) == 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func bloomHashes(digest [sha1.Size]byte) (uint64, uint64) {
+	return binary.BigEndian.Uint64(digest[:8]), binary.BigEndian.Uint64(digest[8:16]) | 1
+}
@@ -0,0 +1,157 @@
+package password
+
+import (
+	"fmt"
+	"math"
+)
+
+// PolicyConfig is the configured form of a Policy, naming breach lists by
+// path.
+type PolicyConfig struct {
+	MinLength        int
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	DisallowEmail    bool
+	// BreachedRangeDir is a directory of Pwned Passwords range files (see RangeDir).
+	BreachedRangeDir string
+	// BreachedBloomFilter is a bloom filter file (see BloomFilter).
+	BreachedBloomFilter string
+}
+
+// NewPolicy creates a Policy from cfg, opening its breach lists. The bloom
+// filter is loaded into memory.
+func NewPolicy(cfg PolicyConfig) (*Policy, error) {
+	if cfg.MinLength < 0 {
+		return nil, fmt.Errorf("password: minLength must not be negative")
+	}
+	p := &Policy{
+		MinLength:        cfg.MinLength,
+		RequireUppercase: cfg.RequireUppercase,
+		RequireLowercase: cfg.RequireLowercase,
+		RequireDigit:     cfg.RequireDigit,
+		RequireSymbol:    cfg.RequireSymbol,
+		DisallowEmail:    cfg.DisallowEmail,
+	}
+	if cfg.BreachedBloomFilter != "" {
+		b, err := LoadBloomFilter(cfg.BreachedBloomFilter)
+		if err != nil {
+			return nil, err
+		}
+		p.Breached = append(p.Breached, b)
+	}
+	if cfg.BreachedRangeDir != "" {
+		d, err := NewRangeDir(cfg.BreachedRangeDir)
+		if err != nil {
+			return nil, err
+		}
+		p.Breached = append(p.Breached, d)
+	}
+	return p, nil
+}
+
+// HashConfigFromMap parses a passwordHashing config block with the keys
+// algorithm, cost, memory, iterations and parallelism.
+func HashConfigFromMap(m map[string]any) (HashConfig, error) {
+	var cfg HashConfig
+	var err error
+	if cfg.Algorithm, err = stringField(m, "algorithm"); err != nil {
+		return cfg, err
+	}
+	cost, err := intField(m, "cost", 0, math.MaxInt32)
+	if err != nil {
+		return cfg, err
+	}
+	memory, err := intField(m, "memory", 0, math.MaxUint32)
+	if err != nil {
+		return cfg, err
+	}
+	iterations, err := intField(m, "iterations", 0, math.MaxUint32)
+	if err != nil {
+		return cfg, err
+	}
+	parallelism, err := intField(m, "parallelism", 0, math.MaxUint8)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Cost, cfg.Memory, cfg.Iterations, cfg.Parallelism = cost, uint32(memory), uint32(iterations), uint8(parallelism) //nolint:gosec // G115: bounded above
+	return cfg, nil
+}
+
+// PolicyConfigFromMap parses a passwordPolicy config block with the keys
+// minLength, requireUppercase, requireLowercase, requireDigit,
+// requireSymbol, disallowEmail, breachedRangeDir and breachedBloomFilter.
+func PolicyConfigFromMap(m map[string]any) (PolicyConfig, error) {
+	var cfg PolicyConfig
+	var err error
+	if cfg.MinLength, err = intField(m, "minLength", 0, MaxLength); err != nil {
+		return cfg, err
+	}
+	for key, dst := range map[string]*bool{
+		"requireUppercase": &cfg.RequireUppercase,
+		"requireLowercase": &cfg.RequireLowercase,
+		"requireDigit":     &cfg.RequireDigit,
+		"requireSymbol":    &cfg.RequireSymbol,
+		"disallowEmail":    &cfg.DisallowEmail,
+	} {
+		if v, ok := m[key]; ok {
+			b, isBool := v.(bool)
+			if !isBool {
+				return cfg, fmt.Errorf("password: %s must be a boolean", key)
+			}
+			*dst = b
+		}
+	}
+	if cfg.BreachedRangeDir, err = stringField(m, "breachedRangeDir"); err != nil {
+		return cfg, err
+	}
+	if cfg.BreachedBloomFilter, err = stringField(m, "breachedBloomFilter"); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+func stringField(m map[string]any, key string) (string, error) {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return "", nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("password: %s must be a string", key)
+	}
+	return s, nil
+}
+
+// intField reads an integer that YAML or JSON decoding may have produced
+// as any numeric type.
+func intField(m map[string]any, key string, lo, hi int64) (int, error) {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return 0, nil
+	}
+	var n int64
+	switch x := v.(type) {
+	case int:
+		n = int64(x)
+	case int64:
+		n = x
+	case uint64:
+		if x > math.MaxInt64 {
+			return 0, fmt.Errorf("password: %s must be between %d and %d", key, lo, hi)
+		}
+		n = int64(x)
+	case float64:
+		if x != math.Trunc(x) || x < math.MinInt64 || x > math.MaxInt64 {
+			return 0, fmt.Errorf("password: %s must be an integer", key)
+		}
+		n = int64(x)
+	default:
+		return 0, fmt.Errorf("password: %s must be an integer", key)
+	}
+	if n < lo || n > hi {
+		return 0, fmt.Errorf("password: %s must be between %d and %d", key, lo, hi)
+	}
+	return int(n), nil
+}
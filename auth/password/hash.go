@@ -0,0 +1,172 @@
+// Package password hashes and verifies user passwords and checks new
+// passwords against a credential policy.
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Supported hashing algorithms.
+const (
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmArgon2id = "argon2id"
+)
+
+// Default argon2id parameters, following the second recommended option of
+// RFC 9106: 64 MiB of memory, 3 passes and 4 lanes.
+const (
+	DefaultArgon2Memory      uint32 = 64 * 1024
+	DefaultArgon2Iterations  uint32 = 3
+	DefaultArgon2Parallelism uint8  = 4
+)
+
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+// HashConfig selects the algorithm and parameters new password hashes use.
+// The zero value hashes with bcrypt at bcrypt.DefaultCost.
+type HashConfig struct {
+	// Algorithm is AlgorithmBcrypt (default) or AlgorithmArgon2id.
+	Algorithm string
+	// Cost is the bcrypt cost (default bcrypt.DefaultCost).
+	Cost int
+	// Memory is the argon2id memory in KiB (default DefaultArgon2Memory).
+	Memory uint32
+	// Iterations is the number of argon2id passes (default DefaultArgon2Iterations).
+	Iterations uint32
+	// Parallelism is the number of argon2id lanes (default DefaultArgon2Parallelism).
+	Parallelism uint8
+}
+
+// Hasher hashes passwords with one configured algorithm and verifies hashes
+// made with any supported algorithm, so the configuration can change
+// without locking out existing users.
+type Hasher struct {
+	cfg HashConfig
+}
+
+// NewHasher creates a Hasher, filling in defaults and validating cfg.
+func NewHasher(cfg HashConfig) (*Hasher, error) {
+	switch cfg.Algorithm {
+	case "", AlgorithmBcrypt:
+		cfg.Algorithm = AlgorithmBcrypt
+		if cfg.Cost == 0 {
+			cfg.Cost = bcrypt.DefaultCost
+		}
+		if cfg.Cost < bcrypt.MinCost || cfg.Cost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("password: bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	case AlgorithmArgon2id:
+		if cfg.Memory == 0 {
+			cfg.Memory = DefaultArgon2Memory
+		}
+		if cfg.Iterations == 0 {
+			cfg.Iterations = DefaultArgon2Iterations
+		}
+		if cfg.Parallelism == 0 {
+			cfg.Parallelism = DefaultArgon2Parallelism
+		}
+		if cfg.Memory < 8*uint32(cfg.Parallelism) {
+			return nil, fmt.Errorf("password: argon2id memory must be at least 8 KiB per lane")
+		}
+	default:
+		return nil, fmt.Errorf("password: unsupported algorithm %q (want %s or %s)", cfg.Algorithm, AlgorithmBcrypt, AlgorithmArgon2id)
+	}
+	return &Hasher{cfg: cfg}, nil
+}
+
+// DefaultHasher returns a Hasher using bcrypt at bcrypt.DefaultCost, the
+// algorithm used before hashing was configurable.
+func DefaultHasher() *Hasher {
+	return &Hasher{cfg: HashConfig{Algorithm: AlgorithmBcrypt, Cost: bcrypt.DefaultCost}}
+}
+
+// Config returns the hasher's configuration with defaults filled in.
+func (h *Hasher) Config() HashConfig {
+	return h.cfg
+}
+
+// Hash returns the encoded hash of password. bcrypt hashes use the modular
+// crypt format ($2a$...), argon2id hashes the PHC string format
+// ($argon2id$v=19$m=...,t=...,p=...$salt$key).
+func (h *Hasher) Hash(password string) (string, error) {
+	if h.cfg.Algorithm == AlgorithmArgon2id {
+		salt := make([]byte, argon2SaltLength)
+		if _, err := rand.Read(salt); err != nil {
+			return "", fmt.Errorf("password: generate salt: %w", err)
+		}
+		key := argon2.IDKey([]byte(password), salt, h.cfg.Iterations, h.cfg.Memory, h.cfg.Parallelism, argon2KeyLength)
+		return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, h.cfg.Memory, h.cfg.Iterations, h.cfg.Parallelism,
+			base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cfg.Cost)
+	if err != nil {
+		return "", fmt.Errorf("password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// Verify reports whether password matches the encoded hash and, when it
+// does, whether the hash should be replaced by Hash(password) because it
+// uses another algorithm or other parameters than the hasher. Malformed
+// and empty hashes never match.
+func (h *Hasher) Verify(encoded, password string) (match, rehash bool) {
+	if strings.HasPrefix(encoded, "$argon2id$") {
+		params, salt, key, err := decodeArgon2id(encoded)
+		if err != nil {
+			return false, false
+		}
+		got := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+		if subtle.ConstantTimeCompare(got, key) != 1 {
+			return false, false
+		}
+		return true, h.cfg.Algorithm != AlgorithmArgon2id || params.Memory != h.cfg.Memory ||
+			params.Iterations != h.cfg.Iterations || params.Parallelism != h.cfg.Parallelism || len(key) != argon2KeyLength
+	}
+	if encoded == "" || bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password)) != nil {
+		return false, false
+	}
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return true, err != nil || h.cfg.Algorithm != AlgorithmBcrypt || cost != h.cfg.Cost
+}
+
+var errMalformedHash = errors.New("password: malformed argon2id hash")
+
+// decodeArgon2id parses a PHC argon2id string into its parameters, salt
+// and key.
+func decodeArgon2id(encoded string) (HashConfig, []byte, []byte, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != AlgorithmArgon2id {
+		return HashConfig{}, nil, nil, errMalformedHash
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return HashConfig{}, nil, nil, errMalformedHash
+	}
+	cfg := HashConfig{Algorithm: AlgorithmArgon2id}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &cfg.Memory, &cfg.Iterations, &cfg.Parallelism); err != nil {
+		return HashConfig{}, nil, nil, errMalformedHash
+	}
+	if cfg.Iterations == 0 || cfg.Parallelism == 0 {
+		return HashConfig{}, nil, nil, errMalformedHash
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return HashConfig{}, nil, nil, errMalformedHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return HashConfig{}, nil, nil, errMalformedHash
+	}
+	return cfg, salt, key, nil
+}
@@ -0,0 +1,127 @@
+package password
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestHasherBcrypt(t *testing.T) {
+	h, err := NewHasher(HashConfig{Cost: bcrypt.MinCost})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := h.Hash("s3cret-pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, "$2a$04$") {
+		t.Fatalf("unexpected bcrypt hash %q", hash)
+	}
+	if match, rehash := h.Verify(hash, "s3cret-pass"); !match || rehash {
+		t.Errorf("Verify = %v, %v; want match without rehash", match, rehash)
+	}
+	if match, _ := h.Verify(hash, "wrong"); match {
+		t.Error("expected wrong password not to match")
+	}
+
+	stronger, _ := NewHasher(HashConfig{Algorithm: AlgorithmBcrypt, Cost: bcrypt.MinCost + 1})
+	if match, rehash := stronger.Verify(hash, "s3cret-pass"); !match || !rehash {
+		t.Errorf("Verify with another cost = %v, %v; want match with rehash", match, rehash)
+	}
+}
+
+func TestHasherArgon2id(t *testing.T) {
+	h, err := NewHasher(HashConfig{Algorithm: AlgorithmArgon2id, Memory: 64, Iterations: 1, Parallelism: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := h.Hash("s3cret-pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Fatalf("unexpected argon2id hash %q", hash)
+	}
+	other, _ := h.Hash("s3cret-pass")
+	if other == hash {
+		t.Error("expected a fresh salt per hash")
+	}
+	if match, rehash := h.Verify(hash, "s3cret-pass"); !match || rehash {
+		t.Errorf("Verify = %v, %v; want match without rehash", match, rehash)
+	}
+	if match, _ := h.Verify(hash, "wrong"); match {
+		t.Error("expected wrong password not to match")
+	}
+
+	tuned, _ := NewHasher(HashConfig{Algorithm: AlgorithmArgon2id, Memory: 128, Iterations: 1, Parallelism: 1})
+	if match, rehash := tuned.Verify(hash, "s3cret-pass"); !match || !rehash {
+		t.Errorf("Verify with other parameters = %v, %v; want match with rehash", match, rehash)
+	}
+
+	// A bcrypt hash is verified and upgraded by an argon2id hasher, and
+	// the other way round.
+	legacy, _ := bcrypt.GenerateFromPassword([]byte("s3cret-pass"), bcrypt.MinCost)
+	if match, rehash := h.Verify(string(legacy), "s3cret-pass"); !match || !rehash {
+		t.Errorf("Verify of bcrypt hash = %v, %v; want match with rehash", match, rehash)
+	}
+	if match, rehash := DefaultHasher().Verify(hash, "s3cret-pass"); !match || !rehash {
+		t.Errorf("bcrypt Verify of argon2id hash = %v, %v; want match with rehash", match, rehash)
+	}
+}
+
+func TestHasherVerifyMalformed(t *testing.T) {
+	h := DefaultHasher()
+	for _, hash := range []string{
+		"",
+		"plain",
+		"$argon2id$v=19$m=64,t=1,p=1$c2FsdA",
+		"$argon2id$v=18$m=64,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=64,t=0,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=64,t=1,p=1$!!$a2V5",
+	} {
+		if match, _ := h.Verify(hash, ""); match {
+			t.Errorf("expected %q not to match", hash)
+		}
+	}
+}
+
+func TestNewHasherInvalid(t *testing.T) {
+	for _, cfg := range []HashConfig{
+		{Algorithm: "md5"},
+		{Algorithm: AlgorithmBcrypt, Cost: 40},
+		{Algorithm: AlgorithmArgon2id, Memory: 8, Parallelism: 4},
+	} {
+		if _, err := NewHasher(cfg); err == nil {
+			t.Errorf("expected %+v to be rejected", cfg)
+		}
+	}
+	h, err := NewHasher(HashConfig{Algorithm: AlgorithmArgon2id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := h.Config(); got.Memory != DefaultArgon2Memory || got.Iterations != DefaultArgon2Iterations || got.Parallelism != DefaultArgon2Parallelism {
+		t.Errorf("unexpected argon2id defaults %+v", got)
+	}
+}
+
+func TestHashConfigFromMap(t *testing.T) {
+	cfg, err := HashConfigFromMap(map[string]any{"algorithm": "argon2id", "memory": 19456, "iterations": float64(2), "parallelism": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg != (HashConfig{Algorithm: AlgorithmArgon2id, Memory: 19456, Iterations: 2, Parallelism: 1}) {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+	for _, m := range []map[string]any{
+		{"algorithm": 1},
+		{"cost": "high"},
+		{"iterations": 1.5},
+		{"parallelism": 300},
+	} {
+		if _, err := HashConfigFromMap(m); err == nil {
+			t.Errorf("expected %v to be rejected", m)
+		}
+	}
+}
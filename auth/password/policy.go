@@ -0,0 +1,135 @@
+package password
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Violation codes reported by Policy.Validate.
+const (
+	CodeTooShort         = "too_short"
+	CodeTooLong          = "too_long"
+	CodeMissingUppercase = "missing_uppercase"
+	CodeMissingLowercase = "missing_lowercase"
+	CodeMissingDigit     = "missing_digit"
+	CodeMissingSymbol    = "missing_symbol"
+	CodeMatchesEmail     = "matches_email"
+	CodeBreached         = "breached"
+)
+
+// MaxLength bounds passwords so a hash request cannot be made arbitrarily
+// expensive; bcrypt itself only uses the first 72 bytes.
+const MaxLength = 1024
+
+// Violation is one way a password fails a policy, reported to clients as a
+// field error.
+type Violation struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// PolicyError is returned by Policy.Validate when a password fails the
+// policy.
+type PolicyError struct {
+	Violations []Violation
+}
+
+func (e *PolicyError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.Message
+	}
+	return "password does not meet the password policy: " + strings.Join(msgs, "; ")
+}
+
+// BreachedChecker reports whether a password appears in a list of
+// breached passwords.
+type BreachedChecker interface {
+	IsBreached(password string) (bool, error)
+}
+
+// Policy is the set of requirements a new password must meet. The zero
+// value accepts any non-empty password up to MaxLength.
+type Policy struct {
+	MinLength        int
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	// DisallowEmail rejects a password equal to the account's email,
+	// ignoring case.
+	DisallowEmail bool
+	// Breached rejects passwords found in breach lists, in order.
+	Breached []BreachedChecker
+}
+
+// Validate checks password, set for the account with the given email,
+// against the policy. It returns a *PolicyError listing every violation,
+// or another error when a breach list cannot be read.
+func (p *Policy) Validate(password, email string) error {
+	var violations []Violation
+	add := func(code, msg string) {
+		violations = append(violations, Violation{Field: "password", Code: code, Message: msg})
+	}
+
+	length := utf8.RuneCountInString(password)
+	if p != nil && length < p.MinLength {
+		add(CodeTooShort, fmt.Sprintf("must be at least %d characters", p.MinLength))
+	}
+	if len(password) > MaxLength {
+		add(CodeTooLong, fmt.Sprintf("must be at most %d bytes", MaxLength))
+	}
+	if p == nil {
+		return policyResult(violations)
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if p.RequireUppercase && !upper {
+		add(CodeMissingUppercase, "must contain an uppercase letter")
+	}
+	if p.RequireLowercase && !lower {
+		add(CodeMissingLowercase, "must contain a lowercase letter")
+	}
+	if p.RequireDigit && !digit {
+		add(CodeMissingDigit, "must contain a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		add(CodeMissingSymbol, "must contain a symbol")
+	}
+	if p.DisallowEmail && email != "" && strings.EqualFold(strings.TrimSpace(password), strings.TrimSpace(email)) {
+		add(CodeMatchesEmail, "must not be the same as the email address")
+	}
+	for _, b := range p.Breached {
+		breached, err := b.IsBreached(password)
+		if err != nil {
+			return fmt.Errorf("password: check breached passwords: %w", err)
+		}
+		if breached {
+			add(CodeBreached, "appears in a list of breached passwords; choose another")
+			break
+		}
+	}
+	return policyResult(violations)
+}
+
+func policyResult(violations []Violation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+	return &PolicyError{Violations: violations}
+}
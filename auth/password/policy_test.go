@@ -0,0 +1,154 @@
+package password
+
+import (
+	"bytes"
+	"crypto/sha1" //nolint:gosec // G505: matches the breach list format
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func violationCodes(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var pe *PolicyError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected a *PolicyError, got %v", err)
+	}
+	codes := make([]string, len(pe.Violations))
+	for i, v := range pe.Violations {
+		if v.Field != "password" {
+			t.Errorf("unexpected field %q", v.Field)
+		}
+		codes[i] = v.Code
+	}
+	return codes
+}
+
+func TestPolicyValidate(t *testing.T) {
+	p := &Policy{MinLength: 10, RequireUppercase: true, RequireLowercase: true, RequireDigit: true, RequireSymbol: true, DisallowEmail: true}
+	for _, tc := range []struct {
+		password, email string
+		want            string
+	}{
+		{"Correct-Horse-9", "a@b.c", ""},
+		{"short", "a@b.c", "too_short,missing_uppercase,missing_digit,missing_symbol"},
+		{"ALLUPPERCASE1!", "a@b.c", "missing_lowercase"},
+		{"Ada@Example.com1", "", ""},
+		{"ADA@example.COM1", "ada@example.com1", "matches_email"},
+		{strings.Repeat("Aa1!", MaxLength), "", "too_long"},
+	} {
+		if got := strings.Join(violationCodes(t, p.Validate(tc.password, tc.email)), ","); got != tc.want {
+			t.Errorf("Validate(%q, %q) = %q, want %q", tc.password, tc.email, got, tc.want)
+		}
+	}
+
+	var none *Policy
+	if err := none.Validate("x", "x"); err != nil {
+		t.Errorf("expected a nil policy to accept any password, got %v", err)
+	}
+	err := p.Validate("short", "")
+	if !strings.HasPrefix(err.Error(), "password does not meet the password policy: must be at least 10 characters; ") {
+		t.Errorf("unexpected error message %q", err)
+	}
+}
+
+func sha1Hex(s string) string {
+	sum := sha1.Sum([]byte(s)) //nolint:gosec // G401: see import
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func TestRangeDir(t *testing.T) {
+	dir := t.TempDir()
+	breached, padded := sha1Hex("password1"), sha1Hex("padding")
+	if err := os.WriteFile(filepath.Join(dir, breached[:5]+".txt"), []byte("0000000000000000000000000000000000A:3\r\n"+breached[5:]+":2427\r\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, padded[:5]), []byte(strings.ToLower(padded[5:])+":0\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	d, err := NewRangeDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for pw, want := range map[string]bool{"password1": true, "padding": false, "unlisted-Passw0rd": false} {
+		if got, err := d.IsBreached(pw); err != nil || got != want {
+			t.Errorf("IsBreached(%q) = %v, %v; want %v", pw, got, err, want)
+		}
+	}
+	if _, err := NewRangeDir(filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected a missing directory to be rejected")
+	}
+}
+
+func TestBloomFilter(t *testing.T) {
+	b, err := NewBloomFilter(100, 0.001)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Add("password1")
+	b.AddSHA1(sha1.Sum([]byte("letmein"))) //nolint:gosec // G401: see import
+
+	var buf bytes.Buffer
+	if _, err := b.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "pwned.bloom")
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadBloomFilter(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for pw, want := range map[string]bool{"password1": true, "letmein": true, "Correct-Horse-9": false} {
+		if got, _ := loaded.IsBreached(pw); got != want {
+			t.Errorf("IsBreached(%q) = %v, want %v", pw, got, want)
+		}
+	}
+
+	if _, err := ReadBloomFilter(strings.NewReader("NOTABLOOMFILTER!!!!!")); err == nil {
+		t.Error("expected a bad magic to be rejected")
+	}
+	if _, err := ReadBloomFilter(bytes.NewReader(buf.Bytes()[:buf.Len()-1])); err == nil {
+		t.Error("expected a truncated filter to be rejected")
+	}
+}
+
+func TestNewPolicyFromConfig(t *testing.T) {
+	b, _ := NewBloomFilter(10, 0.01)
+	b.Add("password1")
+	path := filepath.Join(t.TempDir(), "pwned.bloom")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.WriteTo(f); err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+
+	cfg, err := PolicyConfigFromMap(map[string]any{"minLength": 8, "requireDigit": true, "disallowEmail": true, "breachedBloomFilter": path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := NewPolicy(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(violationCodes(t, p.Validate("password1", "")), ","); got != "breached" {
+		t.Errorf("expected breached, got %q", got)
+	}
+
+	if _, err := PolicyConfigFromMap(map[string]any{"requireDigit": "yes"}); err == nil {
+		t.Error("expected a non-boolean flag to be rejected")
+	}
+	if _, err := NewPolicy(PolicyConfig{BreachedBloomFilter: filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Error("expected a missing bloom filter to be rejected")
+	}
+}
@@ -130,6 +130,42 @@ func TestInMemoryMeter_RecordAndGetUsage(t *testing.T) {
 	}
 }
 
+func TestMeters_RecordAIUsage(t *testing.T) {
+	ctx := context.Background()
+	sqliteMeter, err := NewSQLiteMeter(openTestDB(t))
+	if err != nil {
+		t.Fatalf("NewSQLiteMeter: %v", err)
+	}
+
+	for name, m := range map[string]UsageMeter{"memory": NewInMemoryMeter(), "sqlite": sqliteMeter} {
+		t.Run(name, func(t *testing.T) {
+			if err := m.RecordAIUsage(ctx, "t1", "summarize", 1200, 300, 0.0081); err != nil {
+				t.Fatalf("RecordAIUsage: %v", err)
+			}
+			if err := m.RecordAIUsage(ctx, "t1", "classify", 800, 100, 0.0039); err != nil {
+				t.Fatalf("RecordAIUsage: %v", err)
+			}
+			if err := m.RecordAIUsage(ctx, "t2", "summarize", 50, 50, 0.001); err != nil {
+				t.Fatalf("RecordAIUsage: %v", err)
+			}
+
+			report, err := m.GetUsage(ctx, "t1", time.Now())
+			if err != nil {
+				t.Fatalf("GetUsage: %v", err)
+			}
+			if report.AIInputTokens != 2000 || report.AIOutputTokens != 400 {
+				t.Errorf("AI tokens = %d/%d, want 2000/400", report.AIInputTokens, report.AIOutputTokens)
+			}
+			if report.AICost < 0.01199 || report.AICost > 0.01201 {
+				t.Errorf("AICost = %v, want 0.012", report.AICost)
+			}
+			if report.ExecutionCount != 0 {
+				t.Errorf("ExecutionCount = %d, want 0: AI usage is not an execution", report.ExecutionCount)
+			}
+		})
+	}
+}
+
 func TestInMemoryMeter_CheckLimit_UnderLimit(t *testing.T) {
 	ctx := context.Background()
 	m := NewInMemoryMeter()
@@ -16,12 +16,18 @@ type UsageReport struct {
 	PipelineCount  int       `json:"pipeline_count"`
 	StepCount      int       `json:"step_count"`
 	WorkerPeak     int       `json:"worker_peak"`
+	AIInputTokens  int64     `json:"ai_input_tokens"`
+	AIOutputTokens int64     `json:"ai_output_tokens"`
+	AICost         float64   `json:"ai_cost"`
 }
 
 // UsageMeter tracks and queries resource consumption per tenant.
 type UsageMeter interface {
 	// RecordExecution records a single pipeline execution for the tenant.
 	RecordExecution(ctx context.Context, tenantID, pipelineName string) error
+	// RecordAIUsage records the tokens used and the cost of an AI step run by
+	// one of the tenant's pipelines.
+	RecordAIUsage(ctx context.Context, tenantID, pipelineName string, inputTokens, outputTokens int64, cost float64) error
 	// GetUsage returns the usage report for the given billing period.
 	GetUsage(ctx context.Context, tenantID string, period time.Time) (*UsageReport, error)
 	// CheckLimit checks whether the tenant is allowed to run another execution
@@ -33,8 +39,16 @@ type UsageMeter interface {
 
 // tenantUsage holds per-period counters for a single tenant.
 type tenantUsage struct {
-	executions map[string]int64 // period key -> count
-	pipelines  map[string]bool  // unique pipeline names
+	executions map[string]int64   // period key -> count
+	pipelines  map[string]bool    // unique pipeline names
+	ai         map[string]aiUsage // period key -> AI usage
+}
+
+// aiUsage accumulates AI token usage and cost for one period.
+type aiUsage struct {
+	inputTokens  int64
+	outputTokens int64
+	cost         float64
 }
 
 // InMemoryMeter is a thread-safe in-memory UsageMeter suitable for tests.
@@ -73,6 +87,7 @@ func (m *InMemoryMeter) getOrCreate(tenantID string) *tenantUsage {
 		tu = &tenantUsage{
 			executions: make(map[string]int64),
 			pipelines:  make(map[string]bool),
+			ai:         make(map[string]aiUsage),
 		}
 		m.tenants[tenantID] = tu
 	}
@@ -91,6 +106,21 @@ func (m *InMemoryMeter) RecordExecution(_ context.Context, tenantID, pipelineNam
 	return nil
 }
 
+// RecordAIUsage adds AI token usage and cost to the tenant's current period.
+func (m *InMemoryMeter) RecordAIUsage(_ context.Context, tenantID, _ string, inputTokens, outputTokens int64, cost float64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tu := m.getOrCreate(tenantID)
+	pk := currentPeriodKey()
+	u := tu.ai[pk]
+	u.inputTokens += inputTokens
+	u.outputTokens += outputTokens
+	u.cost += cost
+	tu.ai[pk] = u
+	return nil
+}
+
 // GetUsage returns the usage report for the given period.
 func (m *InMemoryMeter) GetUsage(_ context.Context, tenantID string, period time.Time) (*UsageReport, error) {
 	m.mu.RLock()
@@ -109,6 +139,10 @@ func (m *InMemoryMeter) GetUsage(_ context.Context, tenantID string, period time
 	pk := periodKey(period)
 	report.ExecutionCount = tu.executions[pk]
 	report.PipelineCount = len(tu.pipelines)
+	ai := tu.ai[pk]
+	report.AIInputTokens = ai.inputTokens
+	report.AIOutputTokens = ai.outputTokens
+	report.AICost = ai.cost
 	return report, nil
 }
 
@@ -176,6 +210,20 @@ CREATE TABLE IF NOT EXISTS billing_executions (
 
 CREATE INDEX IF NOT EXISTS idx_billing_exec_tenant_period
     ON billing_executions(tenant_id, period);
+
+CREATE TABLE IF NOT EXISTS billing_ai_usage (
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    tenant_id     TEXT    NOT NULL,
+    pipeline_name TEXT    NOT NULL,
+    period        TEXT    NOT NULL,
+    input_tokens  INTEGER NOT NULL DEFAULT 0,
+    output_tokens INTEGER NOT NULL DEFAULT 0,
+    cost          REAL    NOT NULL DEFAULT 0,
+    created_at    TEXT    NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_billing_ai_usage_tenant_period
+    ON billing_ai_usage(tenant_id, period);
 `
 	_, err := m.db.Exec(ddl)
 	return err
@@ -201,6 +249,18 @@ func (m *SQLiteMeter) RecordExecution(ctx context.Context, tenantID, pipelineNam
 	return nil
 }
 
+// RecordAIUsage records AI token usage and cost in SQLite.
+func (m *SQLiteMeter) RecordAIUsage(ctx context.Context, tenantID, pipelineName string, inputTokens, outputTokens int64, cost float64) error {
+	_, err := m.db.ExecContext(ctx,
+		`INSERT INTO billing_ai_usage (tenant_id, pipeline_name, period, input_tokens, output_tokens, cost) VALUES (?, ?, ?, ?, ?, ?)`,
+		tenantID, pipelineName, currentPeriodKey(), inputTokens, outputTokens, cost,
+	)
+	if err != nil {
+		return fmt.Errorf("billing: record ai usage: %w", err)
+	}
+	return nil
+}
+
 // GetUsage returns the usage report for the given period from SQLite.
 func (m *SQLiteMeter) GetUsage(ctx context.Context, tenantID string, period time.Time) (*UsageReport, error) {
 	pk := periodKey(period)
@@ -216,6 +276,14 @@ func (m *SQLiteMeter) GetUsage(ctx context.Context, tenantID string, period time
 	if err := row.Scan(&report.ExecutionCount, &report.PipelineCount); err != nil {
 		return nil, fmt.Errorf("billing: get usage: %w", err)
 	}
+
+	row = m.db.QueryRowContext(ctx,
+		`SELECT COALESCE(SUM(input_tokens), 0), COALESCE(SUM(output_tokens), 0), COALESCE(SUM(cost), 0) FROM billing_ai_usage WHERE tenant_id = ? AND period = ?`,
+		tenantID, pk,
+	)
+	if err := row.Scan(&report.AIInputTokens, &report.AIOutputTokens, &report.AICost); err != nil {
+		return nil, fmt.Errorf("billing: get ai usage: %w", err)
+	}
 	return report, nil
 }
 
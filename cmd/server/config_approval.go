@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/environment"
+	"github.com/GoCodeAlone/workflow/module"
+)
+
+// approvalSettings resolves the approval settings of the environment named by
+// -environment. When the environment cannot be read the server fails closed
+// and requires approval.
+func (app *serverApp) approvalSettings(ctx context.Context) (environment.ApprovalSettings, bool) {
+	if *environmentName == "" {
+		return environment.ApprovalSettings{}, false
+	}
+	store, ok := app.stores.envStore.(environment.Store)
+	if !ok {
+		app.logger.Warn("Environment store unavailable; requiring approval for config changes", "environment", *environmentName)
+		return environment.ApprovalSettings{RequireApproval: true}, true
+	}
+	env, err := environment.FindByName(ctx, store, *environmentName)
+	if err != nil {
+		app.logger.Warn("Failed to read environment; requiring approval for config changes", "environment", *environmentName, "error", err)
+		return environment.ApprovalSettings{RequireApproval: true}, true
+	}
+	if env == nil {
+		return environment.ApprovalSettings{}, false
+	}
+	settings, err := env.ApprovalSettings()
+	if err != nil {
+		app.logger.Warn("Invalid approval settings; requiring approval for config changes", "environment", env.Name, "error", err)
+		return environment.ApprovalSettings{RequireApproval: true}, true
+	}
+	return settings, true
+}
+
+// configApprovalPolicy is the management handler's approval policy callback.
+func (app *serverApp) configApprovalPolicy(ctx context.Context) module.ConfigApprovalPolicy {
+	settings, _ := app.approvalSettings(ctx)
+	return module.ConfigApprovalPolicy{
+		RequireApproval: settings.RequireApproval,
+		Environment:     *environmentName,
+		Approvers:       settings.Approvers,
+		TTL:             settings.TTL,
+	}
+}
+
+// notifyConfigApprovers logs a staged config change and posts it to the
+// environment's approval_webhook, if one is configured.
+func (app *serverApp) notifyConfigApprovers(ctx context.Context, change *module.PendingConfigChange, approvers []string) {
+	app.logger.Info("Config change staged for approval",
+		"id", change.ID, "author", change.Author, "environment", change.Environment,
+		"approvers", approvers, "expires_at", change.ExpiresAt)
+
+	settings, _ := app.approvalSettings(ctx)
+	if settings.Webhook == "" {
+		return
+	}
+	payload, err := json.Marshal(map[string]any{
+		"event":       "config.pending",
+		"id":          change.ID,
+		"environment": change.Environment,
+		"author":      change.Author,
+		"approvers":   approvers,
+		"createdAt":   change.CreatedAt,
+		"expiresAt":   change.ExpiresAt,
+	})
+	if err != nil {
+		return
+	}
+	go func() {
+		if err := postApprovalWebhook(settings.Webhook, payload); err != nil {
+			app.logger.Warn("Failed to notify approvers", "id", change.ID, "webhook", settings.Webhook, "error", err)
+		}
+	}()
+}
+
+func postApprovalWebhook(url string, payload []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req) //nolint:gosec // G107: operator-configured webhook URL
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
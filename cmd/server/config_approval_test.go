@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/GoCodeAlone/workflow/environment"
+)
+
+func TestConfigApprovalPolicy(t *testing.T) {
+	orig := *environmentName
+	t.Cleanup(func() { *environmentName = orig })
+
+	app := &serverApp{logger: slog.Default()}
+	ctx := context.Background()
+
+	*environmentName = ""
+	if app.configApprovalPolicy(ctx).RequireApproval {
+		t.Error("no environment configured: approval should not be required")
+	}
+
+	*environmentName = "production"
+	if !app.configApprovalPolicy(ctx).RequireApproval {
+		t.Error("environment store unavailable: approval should be required (fail closed)")
+	}
+
+	store, err := environment.NewSQLiteStore(filepath.Join(t.TempDir(), "env.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	app.stores.envStore = store
+	if err := store.Create(ctx, &environment.Environment{
+		Name: "production", Provider: "aws", WorkflowID: "wf",
+		Config: map[string]any{"require_approval": true, "approvers": []any{"ops-lead"}, "approval_ttl": "1h"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	policy := app.configApprovalPolicy(ctx)
+	if !policy.RequireApproval || policy.Environment != "production" || len(policy.Approvers) != 1 || policy.TTL.String() != "1h0m0s" {
+		t.Errorf("unexpected policy: %+v", policy)
+	}
+
+	*environmentName = "staging"
+	if app.configApprovalPolicy(ctx).RequireApproval {
+		t.Error("unknown environment: approval should not be required")
+	}
+}
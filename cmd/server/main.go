@@ -25,6 +25,7 @@ import (
 	"github.com/GoCodeAlone/workflow/ai/llm"
 	apihandler "github.com/GoCodeAlone/workflow/api"
 	"github.com/GoCodeAlone/workflow/audit"
+	"github.com/GoCodeAlone/workflow/auth/password"
 	"github.com/GoCodeAlone/workflow/billing"
 	"github.com/GoCodeAlone/workflow/bundle"
 	"github.com/GoCodeAlone/workflow/config"
@@ -40,15 +41,21 @@ import (
 	pluginexternal "github.com/GoCodeAlone/workflow/plugin/external"
 	_ "github.com/GoCodeAlone/workflow/plugins/admincore"
 	allplugins "github.com/GoCodeAlone/workflow/plugins/all"
+	plugindlq "github.com/GoCodeAlone/workflow/plugins/dlq"
 	_ "github.com/GoCodeAlone/workflow/plugins/docmanager"
+	pluginfeatureflags "github.com/GoCodeAlone/workflow/plugins/featureflags"
 	pluginpipeline "github.com/GoCodeAlone/workflow/plugins/pipelinesteps"
+	pluginstatemachine "github.com/GoCodeAlone/workflow/plugins/statemachine"
 	_ "github.com/GoCodeAlone/workflow/plugins/storebrowser"
+	plugintimeline "github.com/GoCodeAlone/workflow/plugins/timeline"
 	"github.com/GoCodeAlone/workflow/provider"
+	"github.com/GoCodeAlone/workflow/replication"
 	"github.com/GoCodeAlone/workflow/schema"
 	evstore "github.com/GoCodeAlone/workflow/store"
+	"github.com/GoCodeAlone/workflow/version"
+	"github.com/GoCodeAlone/workflow/worker"
 	"github.com/google/uuid"
 	_ "github.com/jackc/pgx/v5/stdlib"
-	"golang.org/x/crypto/bcrypt"
 	_ "modernc.org/sqlite"
 )
 
@@ -66,6 +73,7 @@ var (
 	jwtSecret         = flag.String("jwt-secret", "", "JWT signing secret for API authentication")
 	adminEmail        = flag.String("admin-email", "", "Initial admin user email (first-run bootstrap)")
 	adminPassword     = flag.String("admin-password", "", "Initial admin user password (first-run bootstrap)")
+	passwordPolicy    = flag.String("password-policy", "", "YAML file with passwordHashing and passwordPolicy blocks for API users and the bootstrapped admin")
 
 	// License flags
 	licenseKey = flag.String("license-key", "", "License key for the workflow engine (or set WORKFLOW_LICENSE_KEY env var)")
@@ -79,6 +87,40 @@ var (
 	_ = flag.String("admin-ui-dir", "", "Deprecated: admin UI is now served by the external workflow-plugin-admin binary")
 
 	watchConfig = flag.Bool("watch", false, "Watch config file for changes and auto-reload")
+
+	// environmentName selects the environment (from the environment store)
+	// whose require_approval/approvers settings guard config changes.
+	environmentName = flag.String("environment", "", "Environment this server runs as; its require_approval setting stages config changes for a second approver")
+
+	// envSnapshotKey signs environment snapshots exported for promotion.
+	envSnapshotKey = flag.String("env-snapshot-key", "", "Key that signs exported environment snapshots (or set ENV_SNAPSHOT_KEY env; defaults to the JWT secret)")
+
+	// retentionInterval sets how often workflow retention policies are enforced.
+	retentionInterval = flag.Duration("retention-interval", time.Hour, "How often to enforce workflow retention policies (0 disables periodic runs; the admin API can still trigger them)")
+
+	// moduleStartConcurrency bounds how many independent modules start at once.
+	moduleStartConcurrency = flag.Int("module-start-concurrency", workflow.DefaultModuleStartConcurrency, "Maximum number of independent modules started in parallel (1 starts modules sequentially)")
+
+	// runtimeHealthInterval sets how often runtime instances are probed and
+	// their health forwarded to the ingest store.
+	runtimeHealthInterval = flag.Duration("runtime-health-interval", 30*time.Second, "How often to probe deployed workflow instances' HTTP servers and record their health (0 disables)")
+
+	// Warm standby: a standby replicates the primary's SQLite stores and
+	// starts its engines only when promoted. Leases keep two servers from
+	// acting as primary at once.
+	standbyMode         = flag.Bool("standby", false, "Run as a warm standby: replicate the primary's stores and start engines only when promoted")
+	primaryURL          = flag.String("primary-url", "", "Replication API base URL of the primary a standby replicates from")
+	replicationAddr     = flag.String("replication-addr", "", "Listen address for the replication API: health, readiness, lag metrics, snapshots and promotion (required with -standby)")
+	replicationToken    = flag.String("replication-token", "", "Shared secret authorizing snapshot and promotion requests (or set WORKFLOW_REPLICATION_TOKEN env)")
+	replicationInterval = flag.Duration("replication-interval", 15*time.Second, "How often a standby fetches snapshots from the primary")
+	replicationMaxLag   = flag.Duration("replication-max-lag", time.Minute, "Replication lag above which a standby reports itself stale")
+	leaseRedis          = flag.String("lease-redis", "", "Redis address holding the primary, scheduler and retention leases (multi-workflow mode uses PostgreSQL)")
+	leaseTTL            = flag.Duration("lease-ttl", 15*time.Second, "How long a Redis lease outlives a primary that stopped renewing it")
+
+	// Worker pool: routes with execution: worker are queued for
+	// workflow-worker processes instead of running in the server.
+	executionQueueDSN = flag.String("execution-queue", "", "Execution queue shared with workflow-worker processes: a postgres:// URL, or a SQLite path (default: the server's workflow database)")
+	workerTTL         = flag.Duration("worker-ttl", time.Minute, "How long a worker may miss heartbeats before it is reported dead; its jobs are requeued once their leases expire")
 )
 
 // defaultEnginePlugins returns the standard set of engine plugins used by all engine instances.
@@ -91,6 +133,7 @@ func defaultEnginePlugins() []plugin.EnginePlugin {
 func buildEngine(cfg *config.WorkflowConfig, logger *slog.Logger) (*workflow.StdEngine, *dynamic.Loader, *dynamic.ComponentRegistry, error) {
 	app := modular.NewStdApplication(nil, logger)
 	engine := workflow.NewStdEngine(app, logger)
+	engine.SetModuleStartConcurrency(*moduleStartConcurrency)
 
 	// Load all engine plugins — each registers its module factories, step factories,
 	// trigger factories, and workflow handlers via engine.LoadPlugin.
@@ -140,8 +183,9 @@ func buildEngine(cfg *config.WorkflowConfig, logger *slog.Logger) (*workflow.Std
 		}
 	}
 
-	// Set up dynamic component system
-	pool := dynamic.NewInterpreterPool()
+	// Set up dynamic component system. Importable modules are fixed for the
+	// life of the process; changing engine.dynamic.modules needs a restart.
+	pool := dynamic.NewInterpreterPool(dynamic.WithImportableModules(cfg.DynamicModules()...))
 	registry := dynamic.NewComponentRegistry()
 	loader := dynamic.NewLoader(pool, registry)
 	engine.SetDynamicRegistry(registry)
@@ -163,6 +207,14 @@ func buildEngine(cfg *config.WorkflowConfig, logger *slog.Logger) (*workflow.Std
 	if err := engine.BuildFromConfig(cfg); err != nil {
 		return nil, nil, nil, fmt.Errorf("failed to build workflow: %w", err)
 	}
+	logger.Info("Workflow engine built", engine.VersionReport().LogArgs()...)
+
+	var metrics *module.MetricsCollector
+	if err := engine.GetApp().GetService("metrics.collector", &metrics); err == nil && metrics != nil {
+		if err := metrics.RegisterCollector(extMgr.MetricsCollector(metrics.Namespace())); err != nil {
+			logger.Warn("Failed to register external plugin metrics", "error", err)
+		}
+	}
 
 	return engine, loader, registry, nil
 }
@@ -239,6 +291,8 @@ type closableEventStore interface {
 type executionTrackerIface interface {
 	module.ExecutionTrackerProvider
 	SetEventStoreRecorder(r module.EventRecorder)
+	SetWebhookDispatcher(d *module.WebhookDispatcher)
+	SetAIUsageMeter(m module.AIUsageMeter)
 }
 
 // ExecutionTrackerSetter is implemented by any module that accepts an
@@ -249,6 +303,22 @@ type ExecutionTrackerSetter interface {
 	SetExecutionTracker(module.ExecutionTrackerProvider)
 }
 
+// delegateResolver is implemented by the CQRS handlers, which resolve their
+// delegate services once all services are registered.
+type delegateResolver interface {
+	ResolveDelegatePostStart()
+}
+
+// warnAmbiguousService logs a service lookup that matched more than one
+// service. The locator has already picked the candidate whose name sorts
+// first; other lookup errors (not found) are left to the caller.
+func warnAmbiguousService(logger *slog.Logger, what string, err error) {
+	var ambiguous *module.AmbiguousServiceError
+	if errors.As(err, &ambiguous) {
+		logger.Warn("Multiple services match "+what+"; using the first", "candidates", ambiguous.Names, "using", ambiguous.Names[0])
+	}
+}
+
 // runtimeLifecycle manages the lifecycle of running workflow instances.
 type runtimeLifecycle interface {
 	StopAll(ctx context.Context) error
@@ -271,6 +341,7 @@ type storeComponents struct {
 	eventStore    closableEventStore // execution event store
 	idempotencyDB *sql.DB            // idempotency store DB connection
 	envStore      ioCloser           // environment management store
+	execQueue     *worker.SQLQueue   // queue of executions placed on workers
 }
 
 // mgmtComponents holds management HTTP service handlers created at startup
@@ -302,6 +373,13 @@ type serviceComponents struct {
 	pluginRegMux     http.Handler          // plugin registry mux
 	runtimeMux       http.Handler          // runtime instances API
 	ingestMux        http.Handler          // ingest API for remote workers
+	workersMux       http.Handler          // worker pool dashboard API
+	stopSupervisor   context.CancelFunc    // stops requeueing dead workers' jobs
+	healthMonitor    func(context.Context) // starts probing runtime instances until the context ends
+	retention        *evstore.RetentionJanitor
+	retentionMux     http.Handler              // retention policy/report API
+	webhooks         *module.WebhookDispatcher // webhook subscription delivery
+	trackedWorkflow  string                    // workflow ID executions and transitions are recorded under
 }
 
 // serverApp holds all components needed to run the server. Persistent resources
@@ -318,7 +396,9 @@ type serverApp struct {
 	stores         storeComponents
 	mgmt           mgmtComponents
 	services       serviceComponents
-	currentConfig  *config.WorkflowConfig // last loaded config, used by dynamic config watcher
+	currentConfig  *config.WorkflowConfig  // last loaded config, used by dynamic config watcher
+	replication    *replication.Controller // replication role and leases; nil when not configured
+	replicationSrv *http.Server            // replication API listener
 }
 
 // ReconfigureModules delegates to the current engine, ensuring the reloader
@@ -358,10 +438,11 @@ func setup(logger *slog.Logger, cfg *config.WorkflowConfig) (*serverApp, error)
 		return app.registerPostStartServices(logger)
 	}, func() error {
 		return app.importBundles(logger)
+	}, func() error {
+		app.recoverDurableExecutions(logger)
+		return nil
 	})
 
-	// Initialize audit logger (writes structured JSON to stdout)
-	app.mgmt.auditLogger = audit.NewLogger(os.Stdout)
 	app.mgmt.auditLogger.LogConfigChange(context.Background(), "system", "server", "server started")
 
 	return app, nil
@@ -401,9 +482,11 @@ func setupFromAppConfig(logger *slog.Logger, appCfg *config.ApplicationConfig) (
 		return sApp.registerPostStartServices(logger)
 	}, func() error {
 		return sApp.importBundles(logger)
+	}, func() error {
+		sApp.recoverDurableExecutions(logger)
+		return nil
 	})
 
-	sApp.mgmt.auditLogger = audit.NewLogger(os.Stdout)
 	sApp.mgmt.auditLogger.LogConfigChange(context.Background(), "system", "server",
 		"server started with application config: "+appCfg.Application.Name)
 
@@ -419,15 +502,43 @@ func initManagementHandlers(logger *slog.Logger, engine *workflow.StdEngine, cfg
 	mgmtHandler.SetReloadFunc(func(newCfg *config.WorkflowConfig) error {
 		return app.reloadEngine(newCfg)
 	})
+	// Management reloads always rebuild the engine, so plan a full reload.
+	mgmtHandler.SetReloadPlanFunc(func(newCfg *config.WorkflowConfig) (*config.ReloadPlan, error) {
+		return config.PlanReload(app.currentConfig, newCfg, false), nil
+	})
 	mgmtHandler.SetTryActivateFunc(func(newCfg *config.WorkflowConfig) (*module.TryActivateResult, error) {
 		return app.tryActivateEngine(newCfg)
 	})
 	mgmtHandler.SetStatusFunc(func() map[string]any {
-		return map[string]any{"status": "running"}
+		status := map[string]any{"status": "running", "resources": app.engine.ResourceUsage()}
+		if projections := app.engine.Projections(); len(projections) > 0 {
+			status["projections"] = projections
+		}
+		degraded := app.engine.DegradedModules()
+		if len(degraded) == 0 {
+			return status
+		}
+		modules := make(map[string]any, len(degraded))
+		for name, err := range degraded {
+			modules[name] = err.Error()
+		}
+		status["status"] = "degraded"
+		status["degraded_modules"] = modules
+		return status
 	})
 	mgmtHandler.SetServiceRegistry(func() map[string]any {
 		return app.engine.GetApp().SvcRegistry()
 	})
+	mgmtHandler.SetVersionFunc(func() version.Report {
+		return app.engine.VersionReport()
+	})
+	mgmtHandler.SetStepRegistryFunc(func() interfaces.StepRegistrar {
+		return app.engine.GetStepRegistry()
+	})
+	mgmtHandler.SetApprovalPolicyFunc(app.configApprovalPolicy)
+	mgmtHandler.SetApprovalNotifier(app.notifyConfigApprovers)
+	// The startup config is the first known-good version to roll back to.
+	_, _ = mgmtHandler.RecordConfigVersion(context.Background(), cfg, "", module.ConfigSourceStartup)
 	app.mgmt.mgmtHandler = mgmtHandler
 
 	// AI handlers (combined into a single http.Handler)
@@ -440,6 +551,11 @@ func initManagementHandlers(logger *slog.Logger, engine *workflow.StdEngine, cfg
 
 	// Schema handler
 	app.mgmt.schemaSvc = schema.NewSchemaService()
+
+	// Audit logger (writes structured JSON to stdout); also the default store
+	// for step.audit and the record of config approvals.
+	app.mgmt.auditLogger = audit.NewLogger(os.Stdout)
+	mgmtHandler.SetAuditStore(app.mgmt.auditLogger)
 }
 
 // registerManagementServices registers the pre-start management service handlers
@@ -451,10 +567,11 @@ func registerManagementServices(logger *slog.Logger, app *serverApp) {
 
 	// Register service modules — these are resolved by delegate config in admin/config.yaml
 	svcModules := map[string]any{
-		"admin-engine-mgmt":    app.mgmt.mgmtHandler,
-		"admin-schema-mgmt":    app.mgmt.schemaSvc,
-		"admin-ai-mgmt":        app.mgmt.combinedAI,
-		"admin-component-mgmt": app.mgmt.dynHandler,
+		"admin-engine-mgmt":             app.mgmt.mgmtHandler,
+		"admin-schema-mgmt":             app.mgmt.schemaSvc,
+		"admin-ai-mgmt":                 app.mgmt.combinedAI,
+		"admin-component-mgmt":          app.mgmt.dynHandler,
+		module.DefaultAuditStoreService: app.mgmt.auditLogger,
 	}
 	for name, svc := range svcModules {
 		engine.GetApp().RegisterModule(module.NewServiceModule(name, svc))
@@ -464,24 +581,85 @@ func registerManagementServices(logger *slog.Logger, app *serverApp) {
 	}
 
 	// Re-resolve delegates on CQRS handlers now that services are available
-	for _, svc := range engine.GetApp().SvcRegistry() {
-		switch h := svc.(type) {
-		case *module.QueryHandler:
-			h.ResolveDelegatePostStart()
-		case *module.CommandHandler:
-			h.ResolveDelegatePostStart()
-		}
+	for _, h := range module.FindAllByInterface[delegateResolver](engine.GetApp().SvcRegistry()) {
+		h.Service.ResolveDelegatePostStart()
 	}
 	logger.Info("Registered admin service modules for delegate dispatch")
 
 	// Enrich OpenAPI spec via the service registry
-	for _, svc := range engine.GetApp().SvcRegistry() {
-		if gen, ok := svc.(interfaces.SchemaRegistrar); ok {
-			gen.RegisterAdminSchemas()
-			gen.ApplySchemas()
-			logger.Info("Registered typed OpenAPI schemas", "module", gen.Name())
+	for _, gen := range module.FindAllByInterface[interfaces.SchemaRegistrar](engine.GetApp().SvcRegistry()) {
+		gen.Service.RegisterAdminSchemas()
+		gen.Service.ApplySchemas()
+		logger.Info("Registered typed OpenAPI schemas", "module", gen.Service.Name())
+	}
+}
+
+// openStores resolves the V1, event and environment stores, opening
+// standalone databases in the data directory when no module provides them.
+// Stores already opened are kept, so a standby can open them before its
+// engine starts and replicate into them.
+func (app *serverApp) openStores(logger *slog.Logger) error {
+	svcRegistry := app.engine.GetApp().SvcRegistry()
+
+	// V1Store — the main workflow/company/project data store. Discover the
+	// WorkflowRegistry from the service registry, falling back to a
+	// standalone store.
+	if app.stores.v1Store == nil {
+		var store *module.V1Store
+		for _, provider := range module.FindAllByInterface[interfaces.WorkflowStoreProvider](svcRegistry) {
+			if s, ok := provider.Service.WorkflowStore().(*module.V1Store); ok {
+				store = s
+				logger.Info("Using WorkflowRegistry store", "module", provider.Service.Name())
+				break
+			}
+		}
+		if store == nil {
+			dbPath := filepath.Join(*dataDir, "workflow.db")
+			var err error
+			store, err = module.OpenV1Store(dbPath)
+			if err != nil {
+				return fmt.Errorf("open v1 store at %s: %w", dbPath, err)
+			}
+			logger.Info("Opened standalone v1 data store (no WorkflowRegistry found)", "path", dbPath)
+		}
+		app.stores.v1Store = store
+	}
+
+	// Event store — registered by an eventstore.service module declared in
+	// config, or created directly if no module was configured.
+	if app.stores.eventStore == nil {
+		eventStoreMatch, err := module.FindByInterface[*evstore.SQLiteEventStore](svcRegistry)
+		warnAmbiguousService(logger, "event store", err)
+		eventStore := eventStoreMatch.Service
+		if eventStore != nil {
+			logger.Info("Discovered event store from service registry", "service", eventStoreMatch.Name)
+		} else {
+			eventsDBPath := filepath.Join(*dataDir, "events.db")
+			var esErr error
+			eventStore, esErr = evstore.NewSQLiteEventStore(eventsDBPath)
+			if esErr != nil {
+				logger.Warn("Failed to create event store — timeline/replay/diff features disabled", "error", esErr)
+			} else {
+				logger.Info("Opened event store (fallback)", "path", eventsDBPath)
+			}
+		}
+		if eventStore != nil {
+			app.stores.eventStore = eventStore
+		}
+	}
+
+	// Environment management store.
+	if app.stores.envStore == nil {
+		envDBPath := filepath.Join(*dataDir, "environments.db")
+		envStore, envErr := environment.NewSQLiteStore(envDBPath)
+		if envErr != nil {
+			logger.Warn("Failed to create environment store — environment management disabled", "error", envErr)
+		} else {
+			app.stores.envStore = envStore
+			logger.Info("Opened environment store", "path", envDBPath)
 		}
 	}
+	return nil
 }
 
 // initStores opens all persistent databases and creates service handlers.
@@ -497,32 +675,14 @@ func (app *serverApp) initStores(logger *slog.Logger) error {
 	}
 
 	// -----------------------------------------------------------------------
-	// V1Store — the main workflow/company/project data store
+	// V1Store, event store, environment store
 	// -----------------------------------------------------------------------
 
-	// Discover the WorkflowRegistry from the service registry
-	var store *module.V1Store
-	for _, svc := range engine.GetApp().SvcRegistry() {
-		if provider, ok := svc.(interfaces.WorkflowStoreProvider); ok {
-			if s, ok := provider.WorkflowStore().(*module.V1Store); ok {
-				store = s
-				logger.Info("Using WorkflowRegistry store", "module", provider.Name())
-				break
-			}
-		}
-	}
-
-	// Fallback: open a standalone V1Store if no WorkflowRegistry was found
-	if store == nil {
-		dbPath := filepath.Join(*dataDir, "workflow.db")
-		var err error
-		store, err = module.OpenV1Store(dbPath)
-		if err != nil {
-			return fmt.Errorf("open v1 store at %s: %w", dbPath, err)
-		}
-		logger.Info("Opened standalone v1 data store (no WorkflowRegistry found)", "path", dbPath)
+	// A standby has already opened them to replicate into.
+	if err := app.openStores(logger); err != nil {
+		return err
 	}
-	app.stores.v1Store = store
+	store := app.stores.v1Store.(*module.V1Store)
 
 	// Ensure the system hierarchy exists (Company -> Org -> Project -> Workflow).
 	// This is idempotent — if it already exists, it returns the existing IDs.
@@ -540,45 +700,37 @@ func (app *serverApp) initStores(logger *slog.Logger) error {
 	// inactive and an authenticated admin could read arbitrary files off the host
 	// via the /workflows/load-from-path endpoint.
 	v1Handler.SetDataDir(*dataDir)
-	v1Handler.SetReloadFunc(func(configYAML string) error {
+	// System workflow deploys go through the management handler so they are
+	// staged for approval like any other config change when policy requires.
+	mgmtHandler, _ := app.mgmt.mgmtHandler.(*module.WorkflowUIHandler)
+	if mgmtHandler != nil {
+		if err := mgmtHandler.SetPendingConfigStore(store); err != nil {
+			return err
+		}
+	}
+	v1Handler.SetReloadFunc(func(ctx context.Context, author, configYAML string) error {
 		cfg, parseErr := config.LoadFromString(configYAML)
 		if parseErr != nil {
 			return fmt.Errorf("invalid config: %w", parseErr)
 		}
-		return app.reloadEngine(cfg)
+		if mgmtHandler == nil {
+			return errors.New("config management is not available")
+		}
+		return mgmtHandler.SubmitConfigChange(ctx, author, cfg, module.ConfigSourceReload)
 	})
+	if app.mgmt.auditLogger != nil {
+		v1Handler.SetAuditStore(app.mgmt.auditLogger)
+	}
 	app.services.v1Handler = v1Handler
 
 	// -----------------------------------------------------------------------
-	// Event store, idempotency store
+	// Idempotency store
 	// -----------------------------------------------------------------------
 
-	// Try to discover the event store from the service registry (registered
-	// by an eventstore.service module declared in config). Fall back to
-	// creating one directly if no module was configured.
-	var eventStore *evstore.SQLiteEventStore
-	for _, svc := range engine.GetApp().SvcRegistry() {
-		if es, ok := svc.(*evstore.SQLiteEventStore); ok {
-			eventStore = es
-			logger.Info("Discovered event store from service registry")
-			break
-		}
-	}
-	if eventStore == nil {
-		eventsDBPath := filepath.Join(*dataDir, "events.db")
-		var esErr error
-		eventStore, esErr = evstore.NewSQLiteEventStore(eventsDBPath)
-		if esErr != nil {
-			logger.Warn("Failed to create event store — timeline/replay/diff features disabled", "error", esErr)
-		} else {
-			logger.Info("Opened event store (fallback)", "path", eventsDBPath)
-		}
-	}
-	if eventStore != nil {
-		app.stores.eventStore = eventStore
-	}
+	eventStore, _ := app.stores.eventStore.(*evstore.SQLiteEventStore)
 
 	// Create SQLite idempotency store (separate DB connection, same data dir)
+	var idempotencyLister evstore.IdempotencyLister
 	idempotencyDBPath := filepath.Join(*dataDir, "idempotency.db")
 	idempotencyDSN := idempotencyDBPath + "?_journal_mode=WAL&_busy_timeout=5000"
 	idempotencyDB, err := sql.Open("sqlite", idempotencyDSN)
@@ -592,7 +744,7 @@ func (app *serverApp) initStores(logger *slog.Logger) error {
 			logger.Warn("Failed to create idempotency store", "error", idErr)
 		} else {
 			logger.Info("Opened idempotency store", "path", idempotencyDBPath)
-			_ = idempotencyStore // registered for future pipeline integration
+			idempotencyLister = idempotencyStore
 		}
 	}
 
@@ -602,40 +754,38 @@ func (app *serverApp) initStores(logger *slog.Logger) error {
 
 	// Discover timeline/replay/backfill mux services registered by ProvidesServices
 	// (registered by a timeline.service module). Fall back to direct creation.
+	// The replay and backfill muxes are taken from the same module as the
+	// timeline mux, so muxes from different modules are never mixed.
 	timelineDiscovered := false
-	var (
-		discoveredTimelineMux http.Handler
-		discoveredReplayMux   http.Handler
-		discoveredBackfillMux http.Handler
-	)
-	for svcName, svc := range engine.GetApp().SvcRegistry() {
-		if strings.HasSuffix(svcName, ".timeline") {
-			if h, ok := svc.(http.Handler); ok {
-				discoveredTimelineMux = h
-				logger.Info("Discovered timeline mux from service registry", "service", svcName)
-			}
-		}
-		if strings.HasSuffix(svcName, ".replay") {
-			if h, ok := svc.(http.Handler); ok {
-				discoveredReplayMux = h
-			}
-		}
-		if strings.HasSuffix(svcName, ".backfill") {
-			if h, ok := svc.(http.Handler); ok {
-				discoveredBackfillMux = h
-			}
+	svcRegistry := engine.GetApp().SvcRegistry()
+	timelineMatch, err := module.FindByNameSuffixAndType[http.Handler](svcRegistry, plugintimeline.TimelineServiceSuffix)
+	warnAmbiguousService(logger, "timeline mux", err)
+	if timelineMatch.Service != nil {
+		base := strings.TrimSuffix(timelineMatch.Name, plugintimeline.TimelineServiceSuffix)
+		replayMux, _ := svcRegistry[base+plugintimeline.ReplayServiceSuffix].(http.Handler)
+		backfillMux, _ := svcRegistry[base+plugintimeline.BackfillServiceSuffix].(http.Handler)
+		if replayMux != nil && backfillMux != nil {
+			app.services.timelineMux = timelineMatch.Service
+			app.services.replayMux = replayMux
+			app.services.backfillMux = backfillMux
+			timelineDiscovered = true
+			logger.Info("Discovered timeline, replay, and backfill muxes from service registry", "module", base)
 		}
 	}
-	if discoveredTimelineMux != nil && discoveredReplayMux != nil && discoveredBackfillMux != nil {
-		app.services.timelineMux = discoveredTimelineMux
-		app.services.replayMux = discoveredReplayMux
-		app.services.backfillMux = discoveredBackfillMux
-		timelineDiscovered = true
-		logger.Info("Discovered timeline, replay, and backfill muxes from service registry")
-	}
 	if !timelineDiscovered {
 		if eventStore != nil {
-			timelineHandler := evstore.NewTimelineHandler(eventStore, logger)
+			// Timelines, replays and diffs read through to archived
+			// executions when the event store has tiering; recording,
+			// retention and recovery keep using the hot store.
+			var readStore evstore.EventStore = eventStore
+			tieredMatch, err := module.FindByInterface[*evstore.TieredEventStore](svcRegistry)
+			warnAmbiguousService(logger, "tiered event store", err)
+			if tieredMatch.Service != nil {
+				readStore = tieredMatch.Service
+				logger.Info("Discovered tiered event store from service registry", "service", tieredMatch.Name)
+			}
+
+			timelineHandler := evstore.NewTimelineHandler(readStore, logger)
 			if store != nil {
 				timelineHandler.WithLogQuerier(store)
 			}
@@ -643,15 +793,21 @@ func (app *serverApp) initStores(logger *slog.Logger) error {
 			timelineHandler.RegisterRoutes(timelineMux)
 			app.services.timelineMux = timelineMux
 
-			replayHandler := evstore.NewReplayHandler(eventStore, logger)
+			replayHandler := evstore.NewReplayHandler(readStore, logger)
 			replayMux := http.NewServeMux()
 			replayHandler.RegisterRoutes(replayMux)
 			app.services.replayMux = replayMux
 
 			backfillStore := evstore.NewInMemoryBackfillStore()
 			mockStore := evstore.NewInMemoryStepMockStore()
-			diffCalc := evstore.NewDiffCalculator(eventStore)
+			diffCalc := evstore.NewDiffCalculator(readStore)
 			bmdHandler := evstore.NewBackfillMockDiffHandler(backfillStore, mockStore, diffCalc, logger)
+			// Backfills replay through the pipelines of the current engine,
+			// which a config reload replaces.
+			runBackfill := module.BackfillRunFunc(func(name string) (*module.Pipeline, bool) {
+				return app.engine.GetPipeline(name)
+			})
+			bmdHandler.WithRunner(evstore.NewBackfillRunner(backfillStore, mockStore, readStore, runBackfill, logger))
 			bmdMux := http.NewServeMux()
 			bmdHandler.RegisterRoutes(bmdMux)
 			app.services.backfillMux = bmdMux
@@ -665,6 +821,13 @@ func (app *serverApp) initStores(logger *slog.Logger) error {
 			logger.Info("Created stub handlers for timeline/replay/backfill (event store unavailable)")
 		}
 	}
+	if timelineDiscovered || eventStore != nil {
+		// Scope every timeline, replay and backfill request to the tenant of
+		// the authenticated caller.
+		app.services.timelineMux = module.AdminTenantScope(secret, app.services.timelineMux)
+		app.services.replayMux = module.AdminTenantScope(secret, app.services.replayMux)
+		app.services.backfillMux = module.AdminTenantScope(secret, app.services.backfillMux)
+	}
 
 	// -----------------------------------------------------------------------
 	// DLQ handler
@@ -672,27 +835,20 @@ func (app *serverApp) initStores(logger *slog.Logger) error {
 
 	// Discover DLQ mux and store from the service registry (registered by a
 	// dlq.service module). Fall back to direct creation.
+	// The admin mux is taken from the module that registered the store.
 	dlqDiscovered := false
 	var dlqStore evstore.DLQStore
-	var discoveredDLQMux http.Handler
-	for svcName, svc := range engine.GetApp().SvcRegistry() {
-		if strings.HasSuffix(svcName, ".store") {
-			if ds, ok := svc.(*evstore.InMemoryDLQStore); ok {
-				dlqStore = ds
-			}
-		}
-		if strings.HasSuffix(svcName, ".admin") {
-			if h, ok := svc.(http.Handler); ok && strings.Contains(svcName, "dlq") {
-				discoveredDLQMux = h
-				logger.Info("Discovered DLQ mux from service registry", "service", svcName)
-			}
+	dlqMatch, err := module.FindByNameSuffixAndType[*evstore.InMemoryDLQStore](svcRegistry, plugindlq.StoreServiceSuffix)
+	warnAmbiguousService(logger, "DLQ store", err)
+	if dlqMatch.Service != nil {
+		base := strings.TrimSuffix(dlqMatch.Name, plugindlq.StoreServiceSuffix)
+		if dlqMux, ok := svcRegistry[base+plugindlq.AdminServiceSuffix].(http.Handler); ok {
+			dlqStore = dlqMatch.Service
+			app.services.dlqMux = dlqMux
+			dlqDiscovered = true
+			logger.Info("Discovered DLQ service from service registry", "module", base)
 		}
 	}
-	if discoveredDLQMux != nil && dlqStore != nil {
-		app.services.dlqMux = discoveredDLQMux
-		dlqDiscovered = true
-		logger.Info("Discovered DLQ service from service registry")
-	}
 	if !dlqDiscovered {
 		inMemDLQStore := evstore.NewInMemoryDLQStore()
 		dlqStore = inMemDLQStore
@@ -703,6 +859,12 @@ func (app *serverApp) initStores(logger *slog.Logger) error {
 		logger.Info("Created DLQ handler (fallback)")
 	}
 
+	// -----------------------------------------------------------------------
+	// Retention janitor
+	// -----------------------------------------------------------------------
+
+	app.initRetention(logger, idempotencyLister, dlqStore)
+
 	// -----------------------------------------------------------------------
 	// Billing handler
 	// -----------------------------------------------------------------------
@@ -819,18 +981,23 @@ func (app *serverApp) initStores(logger *slog.Logger) error {
 	// Environment management
 	// -----------------------------------------------------------------------
 
-	envDBPath := filepath.Join(*dataDir, "environments.db")
-	envStore, envErr := environment.NewSQLiteStore(envDBPath)
-	if envErr != nil {
-		logger.Warn("Failed to create environment store — environment management disabled", "error", envErr)
+	if envStore, ok := app.stores.envStore.(*environment.SQLiteStore); !ok {
 		app.services.envMux = featureDisabledHandler("environment store unavailable — environment management disabled")
 	} else {
-		app.stores.envStore = envStore
 		envHandler := environment.NewHandler(envStore)
+		if key := envOrFlag("ENV_SNAPSHOT_KEY", envSnapshotKey); key != "" {
+			envHandler.SetSigningKey([]byte(key))
+		} else if secret != "" {
+			envHandler.SetSigningKey([]byte(secret))
+		} else {
+			logger.Warn("No environment snapshot key configured; environment export and promotion disabled")
+		}
+		envHandler.SetAuditStore(app.mgmt.auditLogger)
+		envHandler.SetActorFunc(module.RequestUser)
 		envMux := http.NewServeMux()
 		envHandler.RegisterRoutes(envMux)
 		app.services.envMux = envMux
-		logger.Info("Registered environment management service", "path", envDBPath)
+		logger.Info("Registered environment management service")
 	}
 
 	// -----------------------------------------------------------------------
@@ -878,6 +1045,23 @@ func (app *serverApp) initStores(logger *slog.Logger) error {
 		Tracer:     tracing.NewWorkflowTracer(nil), // uses global OTEL provider
 		ConfigHash: app.engine.ConfigHash(),
 	}
+	// AI step usage is metered to the tracked workflow's company.
+	app.services.executionTracker.SetAIUsageMeter(billingMeter)
+	app.services.trackedWorkflow = workflowID
+
+	// -----------------------------------------------------------------------
+	// Webhook dispatcher — delivers execution and state transition events to
+	// webhook subscriptions, dead-lettering permanent failures into the DLQ
+	// -----------------------------------------------------------------------
+
+	webhooks := module.NewWebhookDispatcher(store, logger)
+	webhooks.SetDLQStore(dlqStore)
+	if startErr := webhooks.Start(context.Background()); startErr != nil {
+		logger.Warn("Failed to resume webhook deliveries", "error", startErr)
+	}
+	app.services.webhooks = webhooks
+	app.services.executionTracker.SetWebhookDispatcher(webhooks)
+	v1Handler.SetWebhookDispatcher(webhooks)
 
 	// -----------------------------------------------------------------------
 	// Ingest handler — receives observability data from remote workers
@@ -888,14 +1072,45 @@ func (app *serverApp) initStores(logger *slog.Logger) error {
 	ingestMux := http.NewServeMux()
 	ingestHandler.RegisterRoutes(ingestMux)
 	app.services.ingestMux = ingestMux
+	// Events streamed by workers go to the event store too, so the
+	// timelines of their executions are complete.
+	if app.stores.eventStore != nil {
+		ingestHandler.SetEventRecorder(evstore.NewEventRecorderAdapter(app.stores.eventStore))
+	}
 	logger.Info("Registered ingest handler for remote worker observability")
 
+	// -----------------------------------------------------------------------
+	// Execution queue — routes placed on workers enqueue their executions
+	// here for workflow-worker processes
+	// -----------------------------------------------------------------------
+
+	var execQueue *worker.SQLQueue
+	var queueErr error
+	if *executionQueueDSN != "" {
+		execQueue, queueErr = worker.OpenQueue(*executionQueueDSN)
+	} else {
+		execQueue, queueErr = worker.NewSQLQueue(store.DB(), module.JobQueueSQLite)
+	}
+	if queueErr != nil {
+		logger.Warn("Failed to open execution queue; routes placed on workers will answer 503", "error", queueErr)
+	} else {
+		app.stores.execQueue = execQueue
+		workersMux := http.NewServeMux()
+		worker.NewHandler(execQueue).RegisterRoutes(workersMux)
+		app.services.workersMux = workersMux
+		supervisorCtx, stopSupervisor := context.WithCancel(context.Background())
+		app.services.stopSupervisor = stopSupervisor
+		go worker.Supervise(supervisorCtx, execQueue, 10*time.Second, *workerTTL, logger)
+		logger.Info("Execution queue ready for workers")
+	}
+
 	// -----------------------------------------------------------------------
 	// Reporter — if WORKFLOW_ADMIN_URL is set, report to admin server
 	// -----------------------------------------------------------------------
 
 	if reporter := observability.ReporterFromEnv(logger); reporter != nil {
 		app.services.reporter = reporter
+		ingestMux.HandleFunc("GET /api/v1/admin/reporter/health", reporter.HealthHandler())
 		reporter.Start(context.Background())
 		logger.Info("Started observability reporter", "admin_url", os.Getenv("WORKFLOW_ADMIN_URL"))
 	}
@@ -931,8 +1146,19 @@ func (app *serverApp) initStores(logger *slog.Logger) error {
 
 	// Create runtime handler
 	runtimeHandler := module.NewRuntimeHandler(rm)
+	runtimeHandler.SetHealthStore(ingestStore)
 	app.services.runtimeMux = runtimeHandler
 
+	// Forward instance health through the ingest handler so local instances
+	// are recorded exactly like remote workers' heartbeats. run starts the
+	// monitor on the server's context, so it stops on shutdown.
+	if *runtimeHealthInterval > 0 {
+		app.services.healthMonitor = func(ctx context.Context) {
+			rm.StartHealthMonitor(ctx, *runtimeHealthInterval, ingestHandler)
+			logger.Info("Runtime health monitor started", "interval", *runtimeHealthInterval)
+		}
+	}
+
 	if *loadWorkflows != "" {
 		// Parse comma-separated paths
 		paths := strings.Split(*loadWorkflows, ",")
@@ -971,6 +1197,11 @@ func (app *serverApp) registerPostStartServices(logger *slog.Logger) error {
 		}
 	}
 
+	// Routes placed on workers enqueue into the execution queue.
+	if app.stores.execQueue != nil {
+		engine.SetExecutionQueue(app.stores.execQueue, app.services.trackedWorkflow)
+	}
+
 	// Register V1 handler
 	if app.services.v1Handler != nil {
 		engine.GetApp().RegisterModule(module.NewServiceModule("admin-v1-mgmt", app.services.v1Handler))
@@ -979,19 +1210,56 @@ func (app *serverApp) registerPostStartServices(logger *slog.Logger) error {
 		}
 	}
 
+	// The statemachine plugin registers its visualization API with every
+	// engine build, so it is looked up in the new Application.
+	stateMachineAPI, _ := engine.GetApp().SvcRegistry()[pluginstatemachine.APIServiceName].(http.Handler)
+
+	// Likewise the messaging plugin registers the topic registry API when a
+	// messaging.topic_registry is configured.
+	topicsAPI, _ := engine.GetApp().SvcRegistry()[module.TopicRegistryAPIServiceName].(http.Handler)
+
+	// Scheduled jobs belong to this engine's schedule triggers, so the
+	// handler is rebuilt with every engine.
+	schedulerMux := http.NewServeMux()
+	module.NewScheduleJobsHandler(func() []*module.ScheduleTrigger {
+		var triggers []*module.ScheduleTrigger
+		for _, t := range engine.Triggers() {
+			if st, ok := t.(*module.ScheduleTrigger); ok {
+				triggers = append(triggers, st)
+			}
+		}
+		return triggers
+	}).RegisterRoutes(schedulerMux)
+
+	// Likewise the database.query_insights modules belong to this engine.
+	dbQueriesMux := http.NewServeMux()
+	module.NewDBQueryInsightsHandler(func() []*module.DBQueryInsights {
+		var insights []*module.DBQueryInsights
+		for _, match := range module.FindAllByInterface[*module.DBQueryInsights](engine.GetApp().SvcRegistry()) {
+			insights = append(insights, match.Service)
+		}
+		return insights
+	}).RegisterRoutes(dbQueriesMux)
+
 	// Register all delegate service modules with the new Application
 	delegateServices := map[string]http.Handler{
-		"admin-timeline-mgmt":   app.services.timelineMux,
-		"admin-replay-mgmt":     app.services.replayMux,
-		"admin-backfill-mgmt":   app.services.backfillMux,
-		"admin-dlq-mgmt":        app.services.dlqMux,
-		"admin-billing-mgmt":    app.services.billingMux,
-		"admin-native-plugins":  app.services.nativeHandler,
-		"admin-env-mgmt":        app.services.envMux,
-		"admin-cloud-providers": app.services.cloudMux,
-		"admin-plugin-registry": app.services.pluginRegMux,
-		"admin-ingest-mgmt":     app.services.ingestMux,
-		"admin-runtime-mgmt":    app.services.runtimeMux,
+		"admin-timeline-mgmt":     app.services.timelineMux,
+		"admin-replay-mgmt":       app.services.replayMux,
+		"admin-backfill-mgmt":     app.services.backfillMux,
+		"admin-dlq-mgmt":          app.services.dlqMux,
+		"admin-billing-mgmt":      app.services.billingMux,
+		"admin-native-plugins":    app.services.nativeHandler,
+		"admin-env-mgmt":          app.services.envMux,
+		"admin-cloud-providers":   app.services.cloudMux,
+		"admin-plugin-registry":   app.services.pluginRegMux,
+		"admin-ingest-mgmt":       app.services.ingestMux,
+		"admin-workers-mgmt":      app.services.workersMux,
+		"admin-runtime-mgmt":      app.services.runtimeMux,
+		"admin-retention-mgmt":    app.services.retentionMux,
+		"admin-statemachine-mgmt": stateMachineAPI,
+		"admin-scheduler-mgmt":    schedulerMux,
+		"admin-topics-mgmt":       topicsAPI,
+		"admin-dbqueries-mgmt":    dbQueriesMux,
 	}
 	for name, handler := range delegateServices {
 		if handler == nil {
@@ -1005,22 +1273,18 @@ func (app *serverApp) registerPostStartServices(logger *slog.Logger) error {
 
 	// Auto-discover FeatureFlagAdmin from the service registry and wire to the
 	// V1 API handler. The featureflag.service module (admin-feature-flags in
-	// admin/config.yaml) registers its admin adapter under a well-known name.
+	// admin/config.yaml) registers its admin adapter as <name>.admin.
 	type featureFlagSetter interface {
 		SetFeatureFlagService(module.FeatureFlagAdmin)
 	}
-	const featureFlagAdminSvcName = "admin-feature-flags.admin"
 	if ffSetter, ok := app.services.v1Handler.(featureFlagSetter); ok {
-		svcRegistry := engine.GetApp().SvcRegistry()
-		if svc, ok := svcRegistry[featureFlagAdminSvcName]; ok {
-			if ffAdmin, ok := svc.(module.FeatureFlagAdmin); ok {
-				ffSetter.SetFeatureFlagService(ffAdmin)
-				logger.Info("Auto-wired FeatureFlagAdmin to V1 API handler from service registry", "service", featureFlagAdminSvcName)
-			} else {
-				logger.Warn("Service registered under feature flag admin name does not implement FeatureFlagAdmin", "service", featureFlagAdminSvcName)
-			}
+		ffAdmin, err := module.FindByNameSuffixAndType[module.FeatureFlagAdmin](engine.GetApp().SvcRegistry(), pluginfeatureflags.AdminServiceSuffix)
+		warnAmbiguousService(logger, "FeatureFlagAdmin", err)
+		if ffAdmin.Service != nil {
+			ffSetter.SetFeatureFlagService(ffAdmin.Service)
+			logger.Info("Auto-wired FeatureFlagAdmin to V1 API handler from service registry", "service", ffAdmin.Name)
 		} else {
-			logger.Debug("FeatureFlagAdmin service not found in service registry; feature flags disabled", "service", featureFlagAdminSvcName)
+			logger.Debug("FeatureFlagAdmin service not found in service registry; feature flags disabled")
 		}
 	}
 
@@ -1033,24 +1297,26 @@ func (app *serverApp) registerPostStartServices(logger *slog.Logger) error {
 			logger.Info("Wired EventStoreRecorder to execution tracker")
 		}
 
-		for _, svc := range engine.GetApp().SvcRegistry() {
-			if h, ok := svc.(ExecutionTrackerSetter); ok {
-				h.SetExecutionTracker(app.services.executionTracker)
-			}
+		for _, h := range module.FindAllByInterface[ExecutionTrackerSetter](engine.GetApp().SvcRegistry()) {
+			h.Service.SetExecutionTracker(app.services.executionTracker)
 		}
 		logger.Info("Wired execution tracker to CQRS handlers")
 	}
 
-	// Resolve delegates that couldn't be resolved during Init
-	for _, svc := range engine.GetApp().SvcRegistry() {
-		switch h := svc.(type) {
-		case *module.QueryHandler:
-			h.ResolveDelegatePostStart()
-		case *module.CommandHandler:
-			h.ResolveDelegatePostStart()
+	// Publish committed state machine transitions to webhook subscriptions.
+	// Each engine reload creates new state machine engines, so this is wired
+	// on every call.
+	if app.services.webhooks != nil {
+		for _, sm := range module.FindAllByInterface[*module.StateMachineEngine](engine.GetApp().SvcRegistry()) {
+			app.services.webhooks.WatchStateMachine(sm.Service, app.services.trackedWorkflow)
 		}
 	}
 
+	// Resolve delegates that couldn't be resolved during Init
+	for _, h := range module.FindAllByInterface[delegateResolver](engine.GetApp().SvcRegistry()) {
+		h.Service.ResolveDelegatePostStart()
+	}
+
 	logger.Info("Registered all post-start services for delegate dispatch")
 	return nil
 }
@@ -1121,11 +1387,19 @@ func (app *serverApp) reloadEngine(newCfg *config.WorkflowConfig) error {
 			return fmt.Errorf("failed to re-register post-start services: %w", regErr)
 		}
 	}
+	app.updateRetentionPolicies()
 
 	logger.Info("Engine reloaded successfully — all services preserved")
 	return nil
 }
 
+// recordConfigVersion adds cfg to the management handler's config history.
+func (app *serverApp) recordConfigVersion(cfg *config.WorkflowConfig, source string) {
+	if h, ok := app.mgmt.mgmtHandler.(*module.WorkflowUIHandler); ok {
+		_, _ = h.RecordConfigVersion(context.Background(), cfg, "", source)
+	}
+}
+
 // tryActivateEngine builds a candidate engine from cfg without stopping the
 // current engine or swapping any active pointers. It is a probe-only operation
 // that returns a structured result describing what the candidate would expose.
@@ -1149,6 +1423,31 @@ func (app *serverApp) tryActivateEngine(cfg *config.WorkflowConfig) (*module.Try
 	return result, nil
 }
 
+// recoverDurableExecutions runs the recovery pass of durable pipelines in
+// the background: executions the previous server process stopped in the
+// middle of are marked interrupted or resumed, as their pipeline's
+// recovery policy says.
+func (app *serverApp) recoverDurableExecutions(logger *slog.Logger) {
+	if app.stores.eventStore == nil || app.engine == nil {
+		return
+	}
+	engine := app.engine
+	go func() {
+		results, err := module.RecoverDurableExecutions(context.Background(), app.stores.eventStore, engine.DurablePipeline, logger)
+		if err != nil {
+			logger.Error("Durable execution recovery failed", "error", err)
+		}
+		for _, r := range results {
+			if r.Err != nil {
+				logger.Warn("Resumed durable execution failed", "pipeline", r.Pipeline, "execution_id", r.ExecutionID, "error", r.Err)
+			}
+		}
+		if len(results) > 0 {
+			logger.Info("Recovered durable executions", "count", len(results))
+		}
+	}()
+}
+
 // importBundles imports and deploys workflow bundles specified via --import-bundle.
 func (app *serverApp) importBundles(logger *slog.Logger) error {
 	if *importBundle == "" {
@@ -1254,22 +1553,47 @@ func (app *serverApp) importBundles(logger *slog.Logger) error {
 // run starts the engine and HTTP server, blocking until ctx is canceled.
 // It performs graceful shutdown when the context is done.
 func run(ctx context.Context, app *serverApp, listenAddr string) error {
+	// A primary that loses its lease shuts down rather than serve alongside
+	// the server that took it.
+	ctx, fence := context.WithCancel(ctx)
+	defer fence()
+
+	// A standby replicates and waits here, engines built but not started,
+	// until it is promoted; a primary claims its leases first.
+	if err := app.initReplication(ctx, fence); err != nil {
+		return err
+	}
+	defer app.stopReplication()
+	if err := app.awaitPromotion(ctx); err != nil {
+		app.closeStores()
+		return nil
+	}
+
 	// Start the workflow engine (single-config mode).
 	// The engine may start its own HTTP server (via http.server module) on the
 	// configured address. The management mux (AI, dynamic components, workflow UI)
 	// listens on a separate management port to avoid conflicts.
 	if app.engine != nil {
 		if err := app.engine.Start(ctx); err != nil {
-			return fmt.Errorf("failed to start workflow engine: %w", err)
+			err = fmt.Errorf("failed to start workflow engine: %w", err)
+			app.markReplicationActive(err)
+			return err
 		}
 	}
 
 	// Run post-start hooks (e.g., wiring handlers that depend on started modules)
 	for _, fn := range app.postStartFuncs {
 		if err := fn(); err != nil {
-			return fmt.Errorf("post-start hook failed: %w", err)
+			err = fmt.Errorf("post-start hook failed: %w", err)
+			app.markReplicationActive(err)
+			return err
 		}
 	}
+	app.markReplicationActive(nil)
+
+	if app.services.healthMonitor != nil {
+		app.services.healthMonitor(ctx)
+	}
 
 	// Config file watcher — started after the engine and all post-start hooks are up.
 	var reloader *config.ConfigReloader
@@ -1293,7 +1617,9 @@ func run(ctx context.Context, app *serverApp, listenAddr string) error {
 					"new_hash", evt.NewHash[:8])
 				if err := reloader.HandleChange(evt); err != nil {
 					app.logger.Error("Config reload failed", "error", err)
+					return
 				}
+				app.recordConfigVersion(evt.Config, module.ConfigSourceWatcher)
 			}, config.WithWatchLogger(app.logger))
 
 			if err := configWatcher.Start(); err != nil {
@@ -1327,7 +1653,9 @@ func run(ctx context.Context, app *serverApp, listenAddr string) error {
 					"source", evt.Source)
 				if err := reloader.HandleChange(evt); err != nil {
 					app.logger.Error("DB config reload failed", "error", err)
+					return
 				}
+				app.recordConfigVersion(evt.Config, module.ConfigSourceWatcher)
 			}, app.logger)
 
 			if err := poller.Start(ctx); err != nil {
@@ -1342,6 +1670,21 @@ func run(ctx context.Context, app *serverApp, listenAddr string) error {
 	// Wait for context cancellation
 	<-ctx.Done()
 
+	// Stop retention enforcement before closing the stores it uses.
+	if app.services.retention != nil {
+		app.services.retention.Stop()
+	}
+
+	// Stop requeueing jobs before closing the execution queue.
+	if app.services.stopSupervisor != nil {
+		app.services.stopSupervisor()
+	}
+
+	// Stop webhook deliveries; unfinished ones resume on the next start.
+	if app.services.webhooks != nil {
+		_ = app.services.webhooks.Stop(context.Background())
+	}
+
 	// Stop observability reporter (final flush)
 	if app.services.reporter != nil {
 		app.services.reporter.Stop()
@@ -1364,6 +1707,25 @@ func run(ctx context.Context, app *serverApp, listenAddr string) error {
 		}
 	}
 
+	app.closeStores()
+
+	// Clean up temp files and directories
+	for _, f := range app.cleanupFiles {
+		if err := os.Remove(f); err != nil && !os.IsNotExist(err) { //nolint:gosec // G703: cleaning up server-managed temp files
+			app.logger.Error("Temp file cleanup error", "path", f, "error", err)
+		}
+	}
+	for _, d := range app.cleanupDirs {
+		if err := os.RemoveAll(d); err != nil && !os.IsNotExist(err) { //nolint:gosec // G703: cleaning up server-managed temp dirs
+			app.logger.Error("Temp directory cleanup error", "path", d, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// closeStores closes the persistent stores on shutdown.
+func (app *serverApp) closeStores() {
 	// Close v1 store
 	if app.stores.v1Store != nil {
 		if err := app.stores.v1Store.Close(); err != nil {
@@ -1392,24 +1754,17 @@ func run(ctx context.Context, app *serverApp, listenAddr string) error {
 		}
 	}
 
+	// Close execution queue (a no-op when it shares the v1 store's DB)
+	if app.stores.execQueue != nil {
+		if err := app.stores.execQueue.Close(); err != nil {
+			app.logger.Error("Execution queue close error", "error", err)
+		}
+	}
+
 	// Close PG store (multi-workflow mode)
 	if app.pgStore != nil {
 		app.pgStore.Close()
 	}
-
-	// Clean up temp files and directories
-	for _, f := range app.cleanupFiles {
-		if err := os.Remove(f); err != nil && !os.IsNotExist(err) { //nolint:gosec // G703: cleaning up server-managed temp files
-			app.logger.Error("Temp file cleanup error", "path", f, "error", err)
-		}
-	}
-	for _, d := range app.cleanupDirs {
-		if err := os.RemoveAll(d); err != nil && !os.IsNotExist(err) { //nolint:gosec // G703: cleaning up server-managed temp dirs
-			app.logger.Error("Temp directory cleanup error", "path", d, "error", err)
-		}
-	}
-
-	return nil
 }
 
 // envOrFlag returns the environment variable value if set, otherwise the flag value.
@@ -1427,16 +1782,22 @@ func envOrFlag(envKey string, flagVal *string) string {
 // corresponding flag was not explicitly provided on the command line.
 func applyEnvOverrides() {
 	envMap := map[string]string{ //nolint:gosec // G101: env var name mapping, not credentials
-		"config":          "WORKFLOW_CONFIG",
-		"addr":            "WORKFLOW_ADDR",
-		"anthropic-key":   "WORKFLOW_AI_API_KEY",
-		"anthropic-model": "WORKFLOW_AI_MODEL",
-		"jwt-secret":      "WORKFLOW_JWT_SECRET",
-		"data-dir":        "WORKFLOW_DATA_DIR",
-		"load-workflows":  "WORKFLOW_LOAD_WORKFLOWS",
-		"import-bundle":   "WORKFLOW_IMPORT_BUNDLE",
-		"license-key":     "WORKFLOW_LICENSE_KEY",
-		"watch":           "WORKFLOW_WATCH",
+		"config":           "WORKFLOW_CONFIG",
+		"addr":             "WORKFLOW_ADDR",
+		"anthropic-key":    "WORKFLOW_AI_API_KEY",
+		"anthropic-model":  "WORKFLOW_AI_MODEL",
+		"jwt-secret":       "WORKFLOW_JWT_SECRET",
+		"data-dir":         "WORKFLOW_DATA_DIR",
+		"load-workflows":   "WORKFLOW_LOAD_WORKFLOWS",
+		"import-bundle":    "WORKFLOW_IMPORT_BUNDLE",
+		"license-key":      "WORKFLOW_LICENSE_KEY",
+		"watch":            "WORKFLOW_WATCH",
+		"environment":      "WORKFLOW_ENVIRONMENT",
+		"standby":          "WORKFLOW_STANDBY",
+		"primary-url":      "WORKFLOW_PRIMARY_URL",
+		"replication-addr": "WORKFLOW_REPLICATION_ADDR",
+		"lease-redis":      "WORKFLOW_LEASE_REDIS",
+		"password-policy":  "WORKFLOW_PASSWORD_POLICY",
 	}
 
 	// Track which flags were explicitly set on the command line.
@@ -1472,10 +1833,12 @@ func main() {
 		_ = os.Setenv("WORKFLOW_LICENSE_KEY", *licenseKey)
 	}
 
-	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+	// The context handler tags log lines emitted during a request's
+	// execution with its request, execution and tenant IDs.
+	logger := slog.New(tracing.NewContextHandler(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
 		AddSource: true,
 		Level:     slog.LevelDebug,
-	}))
+	})))
 
 	if *databaseDSN != "" {
 		// Multi-workflow mode: delegates to runMultiWorkflow which connects to
@@ -1551,10 +1914,14 @@ func runMultiWorkflow(logger *slog.Logger) error {
 	logger.Info("Database migrations applied")
 
 	// 3. Bootstrap admin user if credentials provided
+	hasher, policy, err := loadPasswordSettings(*passwordPolicy)
+	if err != nil {
+		return err
+	}
 	var adminUserID uuid.UUID
 	if *adminEmail != "" && *adminPassword != "" {
 		var err error
-		adminUserID, err = bootstrapAdmin(ctx, pg.Users(), *adminEmail, *adminPassword, logger)
+		adminUserID, err = bootstrapAdmin(ctx, pg.Users(), *adminEmail, *adminPassword, hasher, policy, logger)
 		if err != nil {
 			return fmt.Errorf("bootstrap admin: %w", err)
 		}
@@ -1564,6 +1931,7 @@ func runMultiWorkflow(logger *slog.Logger) error {
 	engineBuilder := func(cfg *config.WorkflowConfig, l *slog.Logger) (*workflow.StdEngine, modular.Application, error) {
 		app := modular.NewStdApplication(nil, l)
 		engine := workflow.NewStdEngine(app, l)
+		engine.SetModuleStartConcurrency(*moduleStartConcurrency)
 		for _, p := range defaultEnginePlugins() {
 			if loadErr := engine.LoadPlugin(p); loadErr != nil {
 				return nil, nil, fmt.Errorf("load plugin %s: %w", p.Name(), loadErr)
@@ -1611,10 +1979,12 @@ func runMultiWorkflow(logger *slog.Logger) error {
 		IAM:         pg.IAM(),
 	}
 	apiCfg := apihandler.Config{
-		JWTSecret:  secret,
-		JWTIssuer:  "workflow-server",
-		AccessTTL:  15 * time.Minute,
-		RefreshTTL: 7 * 24 * time.Hour,
+		JWTSecret:      secret,
+		JWTIssuer:      "workflow-server",
+		AccessTTL:      15 * time.Minute,
+		RefreshTTL:     7 * 24 * time.Hour,
+		PasswordHasher: hasher,
+		PasswordPolicy: policy,
 	}
 	apiRouter := apihandler.NewRouter(stores, apiCfg)
 
@@ -1698,10 +2068,28 @@ func runMultiWorkflow(logger *slog.Logger) error {
 		ReadHeaderTimeout: 10 * time.Second,
 	}
 
+	// A standby waits here, engines built but not started and the API not
+	// listening, until it is promoted; a primary claims its leases first.
+	// A primary that loses its lease shuts down.
+	if err := app.initReplication(ctx, cancel); err != nil {
+		return err
+	}
+	defer app.stopReplication()
+	standbyCtx, stopWaiting := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	err = app.awaitPromotion(standbyCtx)
+	stopWaiting()
+	if err != nil {
+		fmt.Println("Shutting down...")
+		app.closeStores()
+		return nil
+	}
+
 	// Start admin engine (background — handles admin UI on :8081)
 	if app.engine != nil {
 		if err := app.engine.Start(ctx); err != nil {
-			return fmt.Errorf("start admin engine: %w", err)
+			err = fmt.Errorf("start admin engine: %w", err)
+			app.markReplicationActive(err)
+			return err
 		}
 	}
 	for _, fn := range app.postStartFuncs {
@@ -1728,6 +2116,7 @@ func runMultiWorkflow(logger *slog.Logger) error {
 	}
 	fmt.Printf("Multi-workflow API on http://localhost%s/api/v1/\n", displayAddr)
 	fmt.Println("Admin UI on http://localhost:8081")
+	app.markReplicationActive(nil)
 
 	// Wait for termination signal or server failure.
 	sigCh := make(chan os.Signal, 1)
@@ -1737,6 +2126,8 @@ func runMultiWorkflow(logger *slog.Logger) error {
 		fmt.Println("Shutting down...")
 	case <-srvErrCh:
 		logger.Error("API server failed; initiating shutdown")
+	case <-ctx.Done():
+		logger.Error("Primary lease lost; initiating shutdown")
 	}
 	cancel()
 
@@ -1761,7 +2152,12 @@ func runMultiWorkflow(logger *slog.Logger) error {
 
 // bootstrapAdmin creates an admin user if one doesn't already exist.
 // It returns the admin user's UUID so callers can associate resources with them.
-func bootstrapAdmin(ctx context.Context, users evstore.UserStore, email, password string, logger *slog.Logger) (uuid.UUID, error) {
+// The password must meet policy even when the admin exists, so a weak
+// -admin-password is reported at every startup.
+func bootstrapAdmin(ctx context.Context, users evstore.UserStore, email, pw string, hasher *password.Hasher, policy *password.Policy, logger *slog.Logger) (uuid.UUID, error) {
+	if err := policy.Validate(pw, email); err != nil {
+		return uuid.Nil, fmt.Errorf("admin password for %s: %w", email, err)
+	}
 	existing, err := users.GetByEmail(ctx, email)
 	if err != nil && !errors.Is(err, evstore.ErrNotFound) {
 		return uuid.Nil, fmt.Errorf("check existing admin: %w", err)
@@ -1771,7 +2167,7 @@ func bootstrapAdmin(ctx context.Context, users evstore.UserStore, email, passwor
 		return existing.ID, nil
 	}
 
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hash, err := hasher.Hash(pw)
 	if err != nil {
 		return uuid.Nil, fmt.Errorf("hash password: %w", err)
 	}
@@ -1779,7 +2175,7 @@ func bootstrapAdmin(ctx context.Context, users evstore.UserStore, email, passwor
 	admin := &evstore.User{
 		ID:           uuid.New(),
 		Email:        email,
-		PasswordHash: string(hash),
+		PasswordHash: hash,
 		DisplayName:  "Admin",
 		Active:       true,
 		CreatedAt:    now,
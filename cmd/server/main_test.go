@@ -816,6 +816,7 @@ func (m *mockFeatureFlagAdmin) EvaluateFlag(key string, user string, group strin
 func (m *mockFeatureFlagAdmin) SSEHandler() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {})
 }
+func (m *mockFeatureFlagAdmin) SubscribedEngines() []any { return nil }
 
 // TestFeatureFlagAutoWiring verifies that registerPostStartServices wires a
 // FeatureFlagAdmin from the service registry into the V1 API handler.
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/GoCodeAlone/workflow/auth/password"
+	"gopkg.in/yaml.v3"
+)
+
+// loadPasswordSettings reads the -password-policy file: a YAML document
+// with optional passwordHashing and passwordPolicy blocks, using the same
+// keys as the auth.jwt module. Breach list paths are relative to the file.
+// Without a file, passwords are hashed with bcrypt and any is accepted.
+func loadPasswordSettings(path string) (*password.Hasher, *password.Policy, error) {
+	if path == "" {
+		return password.DefaultHasher(), nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read password policy: %w", err)
+	}
+	var doc struct {
+		Hashing map[string]any `yaml:"passwordHashing"`
+		Policy  map[string]any `yaml:"passwordPolicy"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("parse password policy %s: %w", path, err)
+	}
+
+	hashCfg, err := password.HashConfigFromMap(doc.Hashing)
+	if err != nil {
+		return nil, nil, fmt.Errorf("password policy %s: passwordHashing: %w", path, err)
+	}
+	hasher, err := password.NewHasher(hashCfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("password policy %s: %w", path, err)
+	}
+	if doc.Policy == nil {
+		return hasher, nil, nil
+	}
+
+	policyCfg, err := password.PolicyConfigFromMap(doc.Policy)
+	if err != nil {
+		return nil, nil, fmt.Errorf("password policy %s: passwordPolicy: %w", path, err)
+	}
+	dir := filepath.Dir(path)
+	for _, p := range []*string{&policyCfg.BreachedRangeDir, &policyCfg.BreachedBloomFilter} {
+		if *p != "" && !filepath.IsAbs(*p) {
+			*p = filepath.Join(dir, *p)
+		}
+	}
+	policy, err := password.NewPolicy(policyCfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("password policy %s: %w", path, err)
+	}
+	return hasher, policy, nil
+}
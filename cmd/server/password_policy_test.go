@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/GoCodeAlone/workflow/auth/password"
+)
+
+func TestLoadPasswordSettings(t *testing.T) {
+	hasher, policy, err := loadPasswordSettings("")
+	if err != nil || hasher.Config().Algorithm != password.AlgorithmBcrypt || policy != nil {
+		t.Fatalf("expected the bcrypt default without a file, got %v, %v, %v", hasher, policy, err)
+	}
+
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "hibp"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "passwords.yaml")
+	doc := "passwordHashing:\n  algorithm: argon2id\n  memory: 19456\n  iterations: 2\n  parallelism: 1\npasswordPolicy:\n  minLength: 12\n  disallowEmail: true\n  breachedRangeDir: hibp\n"
+	if err := os.WriteFile(path, []byte(doc), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	hasher, policy, err = loadPasswordSettings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg := hasher.Config(); cfg.Algorithm != password.AlgorithmArgon2id || cfg.Memory != 19456 || cfg.Iterations != 2 || cfg.Parallelism != 1 {
+		t.Errorf("unexpected hashing %+v", cfg)
+	}
+	if policy == nil || policy.MinLength != 12 || !policy.DisallowEmail || len(policy.Breached) != 1 {
+		t.Errorf("unexpected policy %+v", policy)
+	}
+
+	if err := os.WriteFile(path, []byte("passwordPolicy:\n  breachedRangeDir: missing\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := loadPasswordSettings(path); err == nil {
+		t.Error("expected a missing range directory to be reported")
+	}
+}
+
+func TestBootstrapAdminRejectsWeakPassword(t *testing.T) {
+	policy := &password.Policy{MinLength: 12}
+	_, err := bootstrapAdmin(context.Background(), nil, "admin@example.com", "admin", password.DefaultHasher(), policy, slog.Default())
+	if err == nil {
+		t.Fatal("expected a weak admin password to be rejected")
+	}
+	if want := "admin password for admin@example.com: password does not meet the password policy: must be at least 12 characters"; err.Error() != want {
+		t.Errorf("unexpected error %q", err)
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/replication"
+	"github.com/GoCodeAlone/workflow/scale"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// leaseKeyPrefix prefixes the keys of the server's primary leases.
+const leaseKeyPrefix = "workflow-server/"
+
+// sqlDBProvider is implemented by the SQLite stores a standby replicates.
+type sqlDBProvider interface {
+	DB() *sql.DB
+}
+
+// leaseLock returns the lock backing the primary leases: Redis with
+// -lease-redis, otherwise PostgreSQL advisory locks in multi-workflow mode.
+// It returns nil when neither is available.
+func (app *serverApp) leaseLock() scale.DistributedLock {
+	if *leaseRedis != "" {
+		return scale.NewRedisLock(*leaseRedis)
+	}
+	if app.pgStore != nil {
+		return scale.NewPGAdvisoryLock(stdlib.OpenDBFromPool(app.pgStore.Pool()))
+	}
+	return nil
+}
+
+// initReplication sets up the server's replication role. A standby opens
+// its stores, replicates the primary's into them and serves the replication
+// API until promoted. A primary with a lease backend claims its leases,
+// failing while another server holds them; fence is called if it later
+// loses the primary lease. Without -standby, -replication-addr or a lease
+// backend it does nothing.
+func (app *serverApp) initReplication(ctx context.Context, fence func()) error {
+	lock := app.leaseLock()
+	if !*standbyMode && *replicationAddr == "" && lock == nil {
+		return nil
+	}
+	if *standbyMode {
+		switch {
+		case lock == nil:
+			return errors.New("standby mode requires a lease backend (-lease-redis, or -database-dsn in multi-workflow mode) to prevent two primaries")
+		case *replicationAddr == "":
+			return errors.New("standby mode requires -replication-addr for readiness checks and promotion")
+		case *primaryURL == "" && app.pgStore == nil:
+			return errors.New("standby mode requires -primary-url")
+		}
+	}
+
+	role := replication.RolePrimary
+	if *standbyMode {
+		role = replication.RoleStandby
+	}
+	ctrl := replication.NewController(role, replication.Options{
+		PrimaryURL: *primaryURL,
+		Token:      envOrFlag("WORKFLOW_REPLICATION_TOKEN", replicationToken),
+		Interval:   *replicationInterval,
+		MaxLag:     *replicationMaxLag,
+	}, app.logger)
+	if lock != nil {
+		ctrl.SetLeases(replication.NewLeases(lock, leaseKeyPrefix, *leaseTTL, app.logger))
+		ctrl.OnFenced(func() {
+			app.logger.Error("Primary lease lost; shutting down so only one server acts as primary")
+			fence()
+		})
+	}
+
+	if *standbyMode {
+		if *primaryURL != "" {
+			if err := app.openStores(app.logger); err != nil {
+				return err
+			}
+			for name, db := range app.replicatedStores() {
+				ctrl.AddReplica(name, db)
+			}
+		}
+	} else if err := ctrl.ClaimPrimary(ctx); err != nil {
+		return fmt.Errorf("another server is primary; start this one with -standby: %w", err)
+	}
+	app.replication = ctrl
+
+	if *replicationAddr != "" {
+		ln, err := net.Listen("tcp", *replicationAddr)
+		if err != nil {
+			ctrl.Stop()
+			return fmt.Errorf("replication API listen on %s: %w", *replicationAddr, err)
+		}
+		mux := http.NewServeMux()
+		replication.NewHandler(ctrl, envOrFlag("WORKFLOW_REPLICATION_TOKEN", replicationToken), app.logger).RegisterRoutes(mux)
+		app.replicationSrv = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			if err := app.replicationSrv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				app.logger.Error("Replication API server error", "error", err)
+			}
+		}()
+		app.logger.Info("Replication API listening", "addr", ln.Addr().String(), "role", role)
+	}
+
+	if *standbyMode {
+		ctrl.Start()
+		app.logger.Info("Running as warm standby; engines start on promotion", "primary", *primaryURL, "interval", *replicationInterval)
+	}
+	return nil
+}
+
+// replicatedStores returns the SQLite databases of the V1, event and
+// environment stores, by replication store name.
+func (app *serverApp) replicatedStores() map[string]*sql.DB {
+	dbs := map[string]*sql.DB{}
+	if app.stores.v1Store != nil {
+		dbs[replication.StoreWorkflows] = app.stores.v1Store.DB()
+	}
+	if p, ok := app.stores.eventStore.(sqlDBProvider); ok {
+		dbs[replication.StoreEvents] = p.DB()
+	}
+	if p, ok := app.stores.envStore.(sqlDBProvider); ok {
+		dbs[replication.StoreEnvironments] = p.DB()
+	}
+	return dbs
+}
+
+// awaitPromotion blocks a standby until a promotion has claimed the primary
+// leases. It returns ctx.Err() if the server shuts down first, and nil at
+// once on a primary.
+func (app *serverApp) awaitPromotion(ctx context.Context) error {
+	if app.replication == nil || app.replication.Role() != replication.RoleStandby {
+		return nil
+	}
+	select {
+	case <-app.replication.Promoted():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// markReplicationActive reports the result of starting the engines. On
+// success the server's stores are served as snapshots and it becomes a
+// ready primary; a pending promotion receives the result either way.
+func (app *serverApp) markReplicationActive(startErr error) {
+	if app.replication == nil {
+		return
+	}
+	if startErr == nil {
+		for name, db := range app.replicatedStores() {
+			app.replication.Source().Add(name, db)
+		}
+	}
+	if app.replication.Role() == replication.RolePromoting {
+		app.replication.Activated(startErr)
+		return
+	}
+	if startErr == nil {
+		app.replication.MarkActive()
+	}
+}
+
+// stopReplication stops replicating, closes the replication API and
+// releases the leases. It runs after the engines have stopped.
+func (app *serverApp) stopReplication() {
+	if app.replicationSrv != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		_ = app.replicationSrv.Shutdown(ctx)
+		cancel()
+	}
+	if app.replication != nil {
+		app.replication.Stop()
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/config"
+	"github.com/GoCodeAlone/workflow/environment"
+	"github.com/GoCodeAlone/workflow/module"
+	"github.com/GoCodeAlone/workflow/replication"
+	"github.com/GoCodeAlone/workflow/scale"
+	evstore "github.com/GoCodeAlone/workflow/store"
+	"github.com/alicebob/miniredis/v2"
+)
+
+// freeAddr returns a loopback address with a currently unused port.
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	return addr
+}
+
+func replicationRequest(t *testing.T, method, url string) (int, replication.Status) {
+	t.Helper()
+	req, _ := http.NewRequest(method, url, nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, replication.Status{}
+	}
+	defer resp.Body.Close()
+	var st replication.Status
+	_ = json.NewDecoder(resp.Body).Decode(&st)
+	return resp.StatusCode, st
+}
+
+func TestRun_StandbyReplicatesAndPromotes(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	t.Setenv("JWT_SECRET", "test-secret-that-is-at-least-32-bytes-long")
+	t.Setenv("WORKFLOW_REPLICATION_TOKEN", "")
+	*anthropicKey = ""
+	*copilotCLI = ""
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	mr := miniredis.RunT(t)
+
+	// The primary: the server's stores served over the replication API,
+	// holding the primary leases.
+	primaryDir := t.TempDir()
+	primaryStore, err := module.OpenV1Store(filepath.Join(primaryDir, "workflow.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer primaryStore.Close()
+	if _, _, _, _, err := primaryStore.EnsureSystemHierarchy("system", ""); err != nil {
+		t.Fatal(err)
+	}
+	primaryEvents, err := evstore.NewSQLiteEventStore(filepath.Join(primaryDir, "events.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer primaryEvents.Close()
+	primaryEnvs, err := environment.NewSQLiteStore(filepath.Join(primaryDir, "environments.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer primaryEnvs.Close()
+	primary := replication.NewController(replication.RolePrimary, replication.Options{}, logger)
+	primary.Source().Add(replication.StoreWorkflows, primaryStore.DB())
+	primary.Source().Add(replication.StoreEvents, primaryEvents.DB())
+	primary.Source().Add(replication.StoreEnvironments, primaryEnvs.DB())
+	primary.SetLeases(replication.NewLeases(scale.NewRedisLock(mr.Addr()), leaseKeyPrefix, time.Second, logger))
+	if err := primary.ClaimPrimary(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	primary.MarkActive()
+	defer primary.Stop()
+	mux := http.NewServeMux()
+	replication.NewHandler(primary, "secret", logger).RegisterRoutes(mux)
+	primarySrv := httptest.NewServer(mux)
+	defer primarySrv.Close()
+
+	standbyAddr := freeAddr(t)
+	orig := []any{*standbyMode, *primaryURL, *replicationAddr, *replicationToken, *replicationInterval, *leaseRedis, *dataDir}
+	t.Cleanup(func() {
+		*standbyMode = orig[0].(bool)
+		*primaryURL = orig[1].(string)
+		*replicationAddr = orig[2].(string)
+		*replicationToken = orig[3].(string)
+		*replicationInterval = orig[4].(time.Duration)
+		*leaseRedis = orig[5].(string)
+		*dataDir = orig[6].(string)
+	})
+	*standbyMode = true
+	*primaryURL = primarySrv.URL
+	*replicationAddr = standbyAddr
+	*replicationToken = "secret"
+	*replicationInterval = 50 * time.Millisecond
+	*leaseRedis = mr.Addr()
+	*dataDir = t.TempDir()
+
+	app, err := setup(logger, config.NewEmptyWorkflowConfig())
+	if err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- run(ctx, app, ":0") }()
+
+	base := "http://" + standbyAddr
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		code, st := replicationRequest(t, http.MethodGet, base+"/readyz")
+		if st.State == replication.StateInSync {
+			if code != http.StatusServiceUnavailable || st.Role != replication.RoleStandby {
+				t.Fatalf("in-sync standby readyz = %d %+v", code, st)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("standby did not sync: %d %+v", code, st)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// The primary still holds its lease, so promotion is refused.
+	if code, _ := replicationRequest(t, http.MethodPost, base+"/api/v1/replication/promote"); code != http.StatusConflict {
+		t.Fatalf("promote while the primary is alive = %d, want 409", code)
+	}
+
+	primary.Stop()
+	code, st := replicationRequest(t, http.MethodPost, base+"/api/v1/replication/promote")
+	if code != http.StatusOK || st.Role != replication.RolePrimary || !st.Ready {
+		t.Fatalf("promote = %d %+v", code, st)
+	}
+	if code, _ := replicationRequest(t, http.MethodGet, base+"/readyz"); code != http.StatusOK {
+		t.Errorf("promoted readyz = %d, want 200", code)
+	}
+	if wf, err := app.stores.v1Store.GetSystemWorkflow(); err != nil || wf == nil {
+		t.Errorf("promoted server has no replicated system workflow: %v", err)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+}
+
+func TestInitReplication_StandbyRequiresLeaseBackend(t *testing.T) {
+	orig := *standbyMode
+	t.Cleanup(func() { *standbyMode = orig })
+	*standbyMode = true
+
+	app := &serverApp{logger: slog.Default()}
+	if err := app.initReplication(context.Background(), func() {}); err == nil {
+		t.Fatal("expected standby without a lease backend to fail")
+	}
+}
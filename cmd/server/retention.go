@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/GoCodeAlone/workflow/module"
+	evstore "github.com/GoCodeAlone/workflow/store"
+)
+
+// retentionWorkflowName names the workflow of a single-file config for
+// retention policies and reports: the config file name without extension.
+func retentionWorkflowName() string {
+	if *configFile == "" {
+		return "default"
+	}
+	base := filepath.Base(*configFile)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// initRetention creates the retention janitor over the server's stores,
+// applies the current config's policies, and starts periodic enforcement.
+func (app *serverApp) initRetention(logger *slog.Logger, idempotency evstore.IdempotencyLister, dlq evstore.DLQStore) {
+	janitor := evstore.NewRetentionJanitor(logger)
+
+	if es, ok := app.stores.eventStore.(evstore.ExecutionDeleter); ok {
+		janitor.SetSource(evstore.RetentionExecutions, &evstore.ExecutionRetentionSource{Store: es})
+		if idempotency != nil {
+			janitor.SetSource(evstore.RetentionIdempotency, &evstore.IdempotencyRetentionSource{Store: idempotency, Events: es})
+		}
+	}
+	if dlq != nil {
+		janitor.SetSource(evstore.RetentionDLQ, &evstore.DLQRetentionSource{Store: dlq})
+	}
+	audit, err := module.FindByInterface[evstore.AuditDeleter](app.engine.GetApp().SvcRegistry())
+	warnAmbiguousService(logger, "audit store", err)
+	if audit.Service != nil {
+		janitor.SetSource(evstore.RetentionAudit, &evstore.AuditRetentionSource{Store: audit.Service})
+	}
+	janitor.SetSource(evstore.RetentionStateHistory, &module.StateMachineRetentionSource{
+		Engines: func() []*module.StateMachineEngine {
+			var engines []*module.StateMachineEngine
+			for _, e := range module.FindAllByInterface[*module.StateMachineEngine](app.engine.GetApp().SvcRegistry()) {
+				engines = append(engines, e.Service)
+			}
+			return engines
+		},
+	})
+	janitor.SetArchiveResolver(app.resolveRetentionArchive)
+
+	app.services.retention = janitor
+	app.updateRetentionPolicies()
+
+	mux := http.NewServeMux()
+	evstore.NewRetentionHandler(janitor, logger).RegisterRoutes(mux)
+	app.services.retentionMux = mux
+
+	if *retentionInterval > 0 {
+		janitor.Start(*retentionInterval)
+		logger.Info("Retention janitor started", "interval", *retentionInterval)
+	}
+}
+
+// updateRetentionPolicies applies the current config's retention policies.
+// It runs at startup and after every reload.
+func (app *serverApp) updateRetentionPolicies() {
+	if app.services.retention == nil {
+		return
+	}
+	policies := app.currentConfig.EffectiveRetentionPolicies(retentionWorkflowName())
+	if err := app.services.retention.SetPolicies(policies); err != nil {
+		app.logger.Error("Invalid retention policies; keeping previous policies", "error", err)
+		return
+	}
+	for _, p := range policies {
+		app.logger.Info("Retention policy active", "workflow", p.Workflow, "pipelines", len(p.Pipelines), "state_machines", len(p.StateMachines))
+	}
+}
+
+// resolveRetentionArchive looks up an archive storage service by name in the
+// current engine. Storage providers (storage.local) and artifact stores are
+// accepted.
+func (app *serverApp) resolveRetentionArchive(name string) (evstore.RetentionArchiver, error) {
+	svc, ok := app.engine.GetApp().SvcRegistry()[name]
+	if !ok {
+		return nil, fmt.Errorf("service not found")
+	}
+	switch s := svc.(type) {
+	case evstore.RetentionArchiver:
+		return s, nil
+	case module.ArtifactStore:
+		return artifactArchiver{s}, nil
+	default:
+		return nil, fmt.Errorf("service %T is not a storage service", svc)
+	}
+}
+
+// artifactArchiver writes retention archives to an artifact store.
+type artifactArchiver struct {
+	store module.ArtifactStore
+}
+
+func (a artifactArchiver) Put(ctx context.Context, path string, r io.Reader) error {
+	return a.store.Upload(ctx, path, r, map[string]string{"content-type": "application/x-ndjson", "content-encoding": "gzip"})
+}
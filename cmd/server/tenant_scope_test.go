@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GoCodeAlone/workflow/config"
+	evstore "github.com/GoCodeAlone/workflow/store"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func TestAdminTimeline_ScopedToCallerTenant(t *testing.T) {
+	const secret = "test-secret-that-is-at-least-32-bytes-long"
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	t.Setenv("JWT_SECRET", secret)
+	*anthropicKey = ""
+	*copilotCLI = ""
+	origDataDir := *dataDir
+	t.Cleanup(func() { *dataDir = origDataDir })
+	*dataDir = t.TempDir()
+
+	logger := slog.Default()
+	app, err := setup(logger, config.NewEmptyWorkflowConfig())
+	if err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	if err := app.initStores(logger); err != nil {
+		t.Fatalf("initStores failed: %v", err)
+	}
+	t.Cleanup(app.closeStores)
+
+	// One execution each for tenants acme and globex.
+	ctx := context.Background()
+	acme, globex := uuid.New(), uuid.New()
+	for id, tenantID := range map[uuid.UUID]string{acme: "acme", globex: "globex"} {
+		if err := app.stores.eventStore.Append(ctx, id, evstore.EventExecutionStarted, map[string]any{"pipeline": "orders", "tenant_id": tenantID}); err != nil {
+			t.Fatal(err)
+		}
+		if err := app.stores.eventStore.Append(ctx, id, evstore.EventExecutionCompleted, map[string]any{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	get := func(path string, claims jwt.MapClaims) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if claims != nil {
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		app.services.timelineMux.ServeHTTP(w, req)
+		return w
+	}
+	acmeUser := jwt.MapClaims{"sub": "1", "role": "user", "tenant_id": "acme"}
+
+	if w := get("/api/v1/admin/executions/"+acme.String()+"/timeline", acmeUser); w.Code != http.StatusOK {
+		t.Errorf("own timeline: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := get("/api/v1/admin/executions/"+globex.String()+"/timeline", acmeUser); w.Code != http.StatusNotFound {
+		t.Errorf("other tenant's timeline: expected 404, got %d", w.Code)
+	}
+	// The tenant comes from the token, not from the request.
+	w := get("/api/v1/admin/executions?tenant_id=globex", acmeUser)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("listing another tenant: expected 403, got %d", w.Code)
+	}
+
+	w = get("/api/v1/admin/executions", acmeUser)
+	var list struct {
+		Executions []evstore.MaterializedExecution `json:"executions"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatalf("decode list: %v: %s", err, w.Body.String())
+	}
+	if len(list.Executions) != 1 || list.Executions[0].ExecutionID != acme {
+		t.Errorf("expected only acme's execution, got %+v", list.Executions)
+	}
+
+	if w := get("/api/v1/admin/executions/"+globex.String()+"/timeline", jwt.MapClaims{"sub": "2", "role": "user"}); w.Code != http.StatusForbidden {
+		t.Errorf("caller without tenant: expected 403, got %d", w.Code)
+	}
+	if w := get("/api/v1/admin/executions/"+globex.String()+"/timeline", jwt.MapClaims{"sub": "3", "role": "admin"}); w.Code != http.StatusOK {
+		t.Errorf("admin without tenant: expected 200, got %d", w.Code)
+	}
+	if w := get("/api/v1/admin/executions/"+globex.String()+"/timeline", nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("unauthenticated caller: expected 401, got %d", w.Code)
+	}
+}
@@ -0,0 +1,377 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/config"
+	"github.com/GoCodeAlone/workflow/module"
+	"gopkg.in/yaml.v3"
+)
+
+// maxRecordedBody bounds the request and response bodies the recorder keeps.
+// Larger bodies are proxied but not recorded.
+const maxRecordedBody = 1 << 20
+
+func runAPIExamples(args []string) error {
+	if len(args) < 1 {
+		return apiExamplesUsage()
+	}
+	switch args[0] {
+	case "record":
+		return runAPIExamplesRecord(args[1:])
+	default:
+		return apiExamplesUsage()
+	}
+}
+
+func apiExamplesUsage() error {
+	fmt.Fprintf(flag.CommandLine.Output(), `Usage: wfctl api examples <subcommand> [options]
+
+Subcommands:
+  record    Record request/response examples from a running server through a proxy
+`)
+	return fmt.Errorf("api examples subcommand is required")
+}
+
+// reportRouteExamples prints the findings of the examples declared on the
+// routes of gen to w. Invalid examples blocks always fail; examples that do
+// not match their schema are warnings unless strict is set.
+func reportRouteExamples(w io.Writer, cfgPath string, gen *module.OpenAPIGenerator, strict bool) error {
+	errs, warnings := gen.CheckRouteExamples()
+	if strict {
+		errs = append(errs, warnings...)
+	} else {
+		for _, warn := range warnings {
+			fmt.Fprintf(w, "  WARN %s: example: %s\n", cfgPath, warn)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("route examples:\n  %s", strings.Join(errs, "\n  "))
+	}
+	return nil
+}
+
+// runAPIExamplesRecord proxies requests to a running server and records the
+// exchanges on the config's routes as examples in the config format.
+func runAPIExamplesRecord(args []string) error {
+	fs := flag.NewFlagSet("api examples record", flag.ContinueOnError)
+	target := fs.String("target", "", "Base URL of the running server (required)")
+	listen := fs.String("listen", "127.0.0.1:8089", "Address the recording proxy listens on")
+	output := fs.String("output", "", "Write the recorded examples to this file instead of stdout")
+	maxPerStatus := fs.Int("max-per-status", 1, "Examples recorded per route and response status")
+	var redact stringSliceFlag
+	fs.Var(&redact, "redact", "Extra field, query or header name pattern to redact (repeatable)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), `Usage: wfctl api examples record [options] <config.yaml>
+
+Start a proxy in front of a running server and record the request/response
+pairs of the config's HTTP routes until interrupted. The examples are then
+written in the config format, keyed by "METHOD path", ready to paste into the
+examples block of each route.
+
+Bodies and query parameters are recorded; values whose names match a
+sensitive pattern (password, token, secret, authorization, ...) or a -redact
+pattern are replaced with %s. Request headers are not recorded, and of the
+response headers only Location is.
+
+Examples:
+  wfctl api examples record -target http://localhost:8080 config.yaml
+  wfctl api examples record -target http://localhost:8080 -output examples.yaml -redact ssn config.yaml
+
+Options:
+`, module.RedactionPlaceholder)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(reorderFlags(args)); err != nil {
+		return err
+	}
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return fmt.Errorf("config file path is required")
+	}
+	if *target == "" {
+		return fmt.Errorf("-target is required")
+	}
+	targetURL, err := url.Parse(*target)
+	if err != nil || targetURL.Scheme == "" || targetURL.Host == "" {
+		return fmt.Errorf("-target must be an absolute URL, got %q", *target)
+	}
+
+	cfg, err := config.LoadFromFile(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	gen := buildConfigOpenAPIGenerator(cfg, module.OpenAPIGeneratorConfig{Title: "record"}, false)
+	rec := newExampleRecorder(configOpenAPISpec(gen, module.OpenAPIGeneratorConfig{}), targetURL, *maxPerStatus, redact)
+
+	ln, err := net.Listen("tcp", *listen)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", *listen, err)
+	}
+	srv := &http.Server{Handler: rec, ReadHeaderTimeout: 10 * time.Second}
+	go func() { _ = srv.Serve(ln) }()
+	fmt.Fprintf(os.Stderr, "Recording %s through http://%s. Press Ctrl+C to stop.\n", targetURL, ln.Addr())
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	<-ctx.Done()
+	stop()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_ = srv.Shutdown(shutdownCtx)
+
+	w := io.Writer(os.Stdout)
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+	n, err := rec.writeYAML(w)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Recorded %d example(s)\n", n)
+	return nil
+}
+
+// exampleRecorder is a reverse proxy that records the exchanges on known
+// routes as route examples.
+type exampleRecorder struct {
+	routes       *http.ServeMux // matches requests to "METHOD path" route patterns
+	proxy        *httputil.ReverseProxy
+	maxPerStatus int
+	patterns     []string // redaction patterns added to the defaults
+
+	mu       sync.Mutex
+	examples map[string][]config.RouteExample // key: "METHOD path"
+}
+
+func newExampleRecorder(spec *module.OpenAPISpec, target *url.URL, maxPerStatus int, patterns []string) *exampleRecorder {
+	rec := &exampleRecorder{
+		routes:       http.NewServeMux(),
+		proxy:        httputil.NewSingleHostReverseProxy(target),
+		maxPerStatus: maxPerStatus,
+		patterns:     patterns,
+		examples:     make(map[string][]config.RouteExample),
+	}
+	noop := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+	for _, op := range specOperations(spec) {
+		if err := handleRoutePattern(rec.routes, op.method+" "+op.path, noop); err != nil {
+			fmt.Fprintf(os.Stderr, "  WARN %s %s: not recorded: %v\n", op.method, op.path, err)
+		}
+	}
+	return rec
+}
+
+// specOperation is an operation of an OpenAPI spec with its method and path.
+type specOperation struct {
+	method string
+	path   string
+	op     *module.OpenAPIOperation
+}
+
+// specOperations returns the operations of spec sorted by path and method.
+func specOperations(spec *module.OpenAPISpec) []specOperation {
+	paths := make([]string, 0, len(spec.Paths))
+	for p := range spec.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	var out []specOperation
+	for _, p := range paths {
+		item := spec.Paths[p]
+		for _, m := range []struct {
+			method string
+			op     *module.OpenAPIOperation
+		}{
+			{"DELETE", item.Delete}, {"GET", item.Get}, {"OPTIONS", item.Options},
+			{"PATCH", item.Patch}, {"POST", item.Post}, {"PUT", item.Put},
+		} {
+			if m.op != nil {
+				out = append(out, specOperation{method: m.method, path: p, op: m.op})
+			}
+		}
+	}
+	return out
+}
+
+// handleRoutePattern registers h for pattern, returning an error instead of
+// panicking when the pattern is invalid or conflicts with another route.
+func handleRoutePattern(mux *http.ServeMux, pattern string, h http.Handler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	mux.Handle(pattern, h)
+	return nil
+}
+
+func (rec *exampleRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	_, pattern := rec.routes.Handler(r)
+	if pattern == "" {
+		rec.proxy.ServeHTTP(w, r)
+		return
+	}
+
+	var reqBody []byte
+	if r.Body != nil {
+		reqBody, _ = io.ReadAll(io.LimitReader(r.Body, maxRecordedBody+1))
+		r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(reqBody), r.Body))
+	}
+	cw := &capturingResponseWriter{ResponseWriter: w, status: http.StatusOK}
+	rec.proxy.ServeHTTP(cw, r)
+
+	if len(reqBody) > maxRecordedBody || cw.body.Len() > maxRecordedBody {
+		return
+	}
+	rec.record(pattern, r, reqBody, cw)
+}
+
+// record adds the exchange as an example of the route, unless the route
+// already has maxPerStatus examples with the response status.
+func (rec *exampleRecorder) record(key string, r *http.Request, reqBody []byte, cw *capturingResponseWriter) {
+	ex := config.RouteExample{Response: config.RouteExampleResponse{Status: cw.status}}
+	if len(reqBody) > 0 || len(r.URL.Query()) > 0 {
+		ex.Request = &config.RouteExampleRequest{Body: rec.redact(decodeExampleBody(reqBody))}
+		if q := r.URL.Query(); len(q) > 0 {
+			ex.Request.Query = make(map[string]string, len(q))
+			redacted, _ := rec.redact(queryMap(q)).(map[string]any)
+			for k, v := range redacted {
+				ex.Request.Query[k] = fmt.Sprint(v)
+			}
+		}
+	}
+	if loc := cw.Header().Get("Location"); loc != "" {
+		ex.Response.Headers = map[string]string{"Location": loc}
+	}
+	ex.Response.Body = rec.redact(decodeExampleBody(cw.body.Bytes()))
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	n := 0
+	for _, existing := range rec.examples[key] {
+		if existing.Response.Status == ex.Response.Status {
+			n++
+		}
+	}
+	if n >= rec.maxPerStatus {
+		return
+	}
+	ex.Name = statusExampleName(ex.Response.Status)
+	if n > 0 {
+		ex.Name = fmt.Sprintf("%s-%d", ex.Name, n+1)
+	}
+	rec.examples[key] = append(rec.examples[key], ex)
+}
+
+// redact replaces the values of sensitive fields in v.
+func (rec *exampleRecorder) redact(v any) any {
+	if v == nil {
+		return nil
+	}
+	return module.RedactStepOutputWithPatterns(map[string]any{"value": v}, rec.patterns)["value"]
+}
+
+// writeYAML writes the recorded examples keyed by "METHOD path" and returns
+// how many there are.
+func (rec *exampleRecorder) writeYAML(w io.Writer) (int, error) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	out := make(map[string][]map[string]any, len(rec.examples))
+	n := 0
+	for key, examples := range rec.examples {
+		for _, ex := range examples {
+			out[key] = append(out[key], ex.ConfigMap())
+			n++
+		}
+	}
+	fmt.Fprintln(w, "# Recorded by wfctl api examples record. Copy each list into the")
+	fmt.Fprintln(w, "# examples block of its route or pipeline HTTP trigger config.")
+	if n == 0 {
+		_, err := fmt.Fprintln(w, "{}")
+		return 0, err
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(out); err != nil {
+		return 0, fmt.Errorf("failed to encode examples: %w", err)
+	}
+	return n, enc.Close()
+}
+
+// statusExampleName names an example after its status, e.g. "created".
+func statusExampleName(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return fmt.Sprintf("status-%d", status)
+	}
+	return strings.ReplaceAll(strings.ToLower(text), " ", "-")
+}
+
+// decodeExampleBody decodes a JSON body, returning nil when the body is
+// empty and the text when it is not JSON.
+func decodeExampleBody(b []byte) any {
+	if len(bytes.TrimSpace(b)) == 0 {
+		return nil
+	}
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return string(b)
+	}
+	return v
+}
+
+func queryMap(q url.Values) map[string]any {
+	m := make(map[string]any, len(q))
+	for k, v := range q {
+		if len(v) > 0 {
+			m[k] = v[0]
+		}
+	}
+	return m
+}
+
+// capturingResponseWriter copies the status and body written through it.
+type capturingResponseWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (c *capturingResponseWriter) WriteHeader(status int) {
+	c.status = status
+	c.ResponseWriter.WriteHeader(status)
+}
+
+func (c *capturingResponseWriter) Write(b []byte) (int, error) {
+	if c.body.Len() <= maxRecordedBody {
+		c.body.Write(b)
+	}
+	return c.ResponseWriter.Write(b)
+}
+
+// Flush supports streaming responses through the proxy.
+func (c *capturingResponseWriter) Flush() {
+	if f, ok := c.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
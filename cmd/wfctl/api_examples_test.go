@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/GoCodeAlone/workflow/config"
+	"github.com/GoCodeAlone/workflow/module"
+	"gopkg.in/yaml.v3"
+)
+
+const configWithRouteExamples = `
+modules:
+  - name: server
+    type: http.server
+    config:
+      address: ":8080"
+  - name: router
+    type: http.router
+    dependsOn: [server]
+workflows:
+  http:
+    server: server
+    router: router
+    routes:
+      - method: GET
+        path: /health
+        handler: health
+        examples:
+          - name: up
+            response:
+              body: {status: up}
+pipelines:
+  create-user:
+    trigger:
+      type: http
+      config:
+        path: /users
+        method: POST
+        examples:
+          - name: created
+            request:
+              body: {email: a@example.com, password: hunter22}
+            response:
+              status: 201
+              headers: {Location: /users/7}
+              body: {id: "7", email: a@example.com}
+          - name: no-email
+            request:
+              body: {password: 5}
+            response:
+              status: 400
+              body: {type: about:blank, title: Bad Request, status: 400, code: validation_failed}
+    steps:
+      - type: step.user_register
+`
+
+func TestRunAPIExtract_RouteExamples(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := writeTestConfig(t, dir, "config.yaml", configWithRouteExamples)
+
+	// The no-email request does not match the inferred credentials schema:
+	// a warning by default, an error with -strict.
+	if err := runAPIExtract([]string{"-output", dir + "/spec.json", cfgPath}); err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+	err := runAPIExtract([]string{"-strict", "-output", dir + "/spec.json", cfgPath})
+	if err == nil || !strings.Contains(err.Error(), `POST /users: example "no-email": request body.email: required property is missing`) {
+		t.Errorf("strict error = %v", err)
+	}
+}
+
+func TestMockHandler_Examples(t *testing.T) {
+	cfg, err := config.LoadFromString(configWithRouteExamples)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(newMockHandler(buildConfigOpenAPIGenerator(cfg, module.OpenAPIGeneratorConfig{}, true)))
+	defer srv.Close()
+
+	do := func(method, path, body string, header http.Header) (*http.Response, map[string]any) {
+		t.Helper()
+		req, _ := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var out map[string]any
+		_ = json.NewDecoder(resp.Body).Decode(&out)
+		return resp, out
+	}
+
+	resp, body := do("GET", "/health", "", nil)
+	if resp.StatusCode != 200 || body["status"] != "up" || resp.Header.Get(mockExampleHeader) != "up" {
+		t.Errorf("health = %d %v", resp.StatusCode, body)
+	}
+
+	// The example whose request matches is served.
+	resp, _ = do("POST", "/users", `{"password": 5}`, nil)
+	if resp.StatusCode != 400 || resp.Header.Get(mockExampleHeader) != "no-email" {
+		t.Errorf("matching request = %d %s", resp.StatusCode, resp.Header.Get(mockExampleHeader))
+	}
+	// Otherwise the first example.
+	resp, body = do("POST", "/users", `{}`, nil)
+	if resp.StatusCode != 201 || resp.Header.Get("Location") != "/users/7" || body["id"] != "7" {
+		t.Errorf("fallback = %d %v", resp.StatusCode, body)
+	}
+	// Prefer selects by name or status.
+	resp, _ = do("POST", "/users", `{}`, http.Header{"Prefer": {"example=no-email"}})
+	if resp.StatusCode != 400 {
+		t.Errorf("Prefer example = %d", resp.StatusCode)
+	}
+	resp, _ = do("POST", "/users", `{}`, http.Header{"Prefer": {"example=missing"}})
+	if resp.StatusCode != 404 {
+		t.Errorf("Prefer unknown example = %d", resp.StatusCode)
+	}
+	resp, body = do("POST", "/users", `{}`, http.Header{"Prefer": {"code=500"}})
+	if resp.StatusCode != 500 || body["title"] != "string" {
+		t.Errorf("Prefer code = %d %v", resp.StatusCode, body)
+	}
+}
+
+func TestExampleRecorder(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		w.Header().Set("Location", "/users/9")
+		w.WriteHeader(http.StatusCreated)
+		var in map[string]any
+		_ = json.Unmarshal(b, &in)
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "9", "email": in["email"], "token": "abc", "ssn": "123"})
+	}))
+	defer upstream.Close()
+
+	cfg, err := config.LoadFromString(configWithRouteExamples)
+	if err != nil {
+		t.Fatal(err)
+	}
+	target, _ := url.Parse(upstream.URL)
+	spec := configOpenAPISpec(buildConfigOpenAPIGenerator(cfg, module.OpenAPIGeneratorConfig{}, false), module.OpenAPIGeneratorConfig{})
+	rec := newExampleRecorder(spec, target, 1, []string{"ssn"})
+	proxy := httptest.NewServer(rec)
+	defer proxy.Close()
+
+	for i := 0; i < 2; i++ {
+		resp, err := http.Post(proxy.URL+"/users?invite=yes", "application/json", strings.NewReader(`{"email":"b@example.com","password":"pw"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b, _ := io.ReadAll(resp.Body); !strings.Contains(string(b), `"token":"abc"`) {
+			t.Errorf("proxied body = %s", b)
+		}
+		resp.Body.Close()
+	}
+	// Not a config route: proxied but not recorded.
+	resp, err := http.Get(proxy.URL + "/other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	var buf bytes.Buffer
+	n, err := rec.writeYAML(&buf)
+	if err != nil || n != 1 {
+		t.Fatalf("writeYAML = %d, %v", n, err)
+	}
+	var out map[string]any
+	if err := yaml.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	examples, err := config.ParseRouteExamples(out["POST /users"])
+	if err != nil || len(examples) != 1 {
+		t.Fatalf("recorded = %v, %v\n%s", examples, err, buf.String())
+	}
+	ex := examples[0]
+	if ex.Name != "created" || ex.Response.Status != 201 || ex.Response.Headers["Location"] != "/users/9" {
+		t.Errorf("example = %+v", ex)
+	}
+	if ex.Request.Query["invite"] != "yes" {
+		t.Errorf("query = %v", ex.Request.Query)
+	}
+	reqBody := ex.Request.Body.(map[string]any)
+	respBody := ex.Response.Body.(map[string]any)
+	if reqBody["password"] != module.RedactionPlaceholder || reqBody["email"] != "b@example.com" {
+		t.Errorf("request body = %v", reqBody)
+	}
+	if respBody["token"] != module.RedactionPlaceholder || respBody["ssn"] != module.RedactionPlaceholder || respBody["id"] != "9" {
+		t.Errorf("response body = %v", respBody)
+	}
+}
@@ -35,6 +35,8 @@ func runAPI(args []string) error {
 	switch args[0] {
 	case "extract":
 		return runAPIExtract(args[1:])
+	case "examples":
+		return runAPIExamples(args[1:])
 	default:
 		return apiUsage()
 	}
@@ -45,6 +47,7 @@ func apiUsage() error {
 
 Subcommands:
   extract   Extract OpenAPI 3.0 spec from a workflow config file (offline)
+  examples  Work with the request/response examples declared on routes
 `)
 	return fmt.Errorf("api subcommand is required")
 }
@@ -60,17 +63,20 @@ func runAPIExtract(args []string) error {
 	fs.Var(&servers, "server", "Server URL to include (repeatable)")
 	output := fs.String("output", "", "Write to file instead of stdout")
 	includeSchemas := fs.Bool("include-schemas", true, "Attempt to infer request/response schemas from step types")
+	strict := fs.Bool("strict", false, "Fail when a route example does not match its schema")
 	fs.Usage = func() {
 		fmt.Fprintf(fs.Output(), `Usage: wfctl api extract [options] <config.yaml>
 
 Parse a workflow config file offline and output an OpenAPI 3.0 specification
-of all HTTP endpoints defined in the config.
+of all HTTP endpoints defined in the config. Route examples that do not match
+their schema are reported as warnings, or as errors with -strict.
 
 Examples:
   wfctl api extract config.yaml
   wfctl api extract -format yaml -output openapi.yaml config.yaml
   wfctl api extract -title "My API" -version "2.0.0" config.yaml
   wfctl api extract -server https://api.example.com config.yaml
+  wfctl api extract -strict config.yaml
 
 Options:
 `)
@@ -99,40 +105,16 @@ Options:
 		apiTitle = "Workflow API"
 	}
 
-	// Build generator with settings
 	genCfg := module.OpenAPIGeneratorConfig{
 		Title:   apiTitle,
 		Version: *version,
 		Servers: []string(servers),
 	}
-	gen := module.NewOpenAPIGenerator("api-extract", genCfg)
-
-	// Build spec from workflow routes
-	gen.BuildSpec(cfg.Workflows)
-
-	// Extract pipeline HTTP endpoints and add them to the spec
-	if len(cfg.Pipelines) > 0 {
-		pipelineRoutes := extractPipelineRoutes(cfg.Pipelines, *includeSchemas, gen)
-		if len(pipelineRoutes) > 0 {
-			gen.BuildSpecFromRoutes(appendToExistingSpec(gen, pipelineRoutes))
-		}
-	}
-
-	if *includeSchemas {
-		gen.ApplySchemas()
-	}
-
-	spec := gen.GetSpec()
-	if spec == nil {
-		spec = &module.OpenAPISpec{
-			OpenAPI: "3.0.3",
-			Info: module.OpenAPIInfo{
-				Title:   apiTitle,
-				Version: *version,
-			},
-			Paths: make(map[string]*module.OpenAPIPath),
-		}
+	gen := buildConfigOpenAPIGenerator(cfg, genCfg, *includeSchemas)
+	if err := reportRouteExamples(os.Stderr, configPath, gen, *strict); err != nil {
+		return err
 	}
+	spec := configOpenAPISpec(gen, genCfg)
 
 	// Determine output writer
 	var w *os.File
@@ -171,6 +153,52 @@ Options:
 	return nil
 }
 
+// buildConfigOpenAPISpec builds the OpenAPI spec of every HTTP endpoint in
+// cfg: http workflow routes and HTTP-triggered pipelines. The result is never
+// nil.
+func buildConfigOpenAPISpec(cfg *config.WorkflowConfig, genCfg module.OpenAPIGeneratorConfig, includeSchemas bool) *module.OpenAPISpec {
+	return configOpenAPISpec(buildConfigOpenAPIGenerator(cfg, genCfg, includeSchemas), genCfg)
+}
+
+// buildConfigOpenAPIGenerator returns the generator holding the spec of
+// buildConfigOpenAPISpec and the examples declared on its routes.
+func buildConfigOpenAPIGenerator(cfg *config.WorkflowConfig, genCfg module.OpenAPIGeneratorConfig, includeSchemas bool) *module.OpenAPIGenerator {
+	gen := module.NewOpenAPIGenerator("api-extract", genCfg)
+
+	// Build spec from workflow routes
+	gen.BuildSpec(cfg.Workflows)
+
+	// Extract pipeline HTTP endpoints and add them to the spec
+	if len(cfg.Pipelines) > 0 {
+		pipelineRoutes := extractPipelineRoutes(cfg.Pipelines, includeSchemas, gen)
+		if len(pipelineRoutes) > 0 {
+			gen.BuildSpecFromRoutes(appendToExistingSpec(gen, pipelineRoutes))
+		}
+	}
+
+	if includeSchemas {
+		gen.ApplySchemas()
+	}
+	return gen
+}
+
+// configOpenAPISpec returns the spec of gen, or an empty spec when gen has
+// none.
+func configOpenAPISpec(gen *module.OpenAPIGenerator, genCfg module.OpenAPIGeneratorConfig) *module.OpenAPISpec {
+	spec := gen.GetSpec()
+	if spec == nil {
+		spec = &module.OpenAPISpec{
+			OpenAPI: "3.0.3",
+			Info: module.OpenAPIInfo{
+				Title:   genCfg.Title,
+				Version: genCfg.Version,
+			},
+			Paths: make(map[string]*module.OpenAPIPath),
+		}
+	}
+	return spec
+}
+
 // extractTitleFromConfig attempts to derive a meaningful API title from the config.
 // It looks for module names that suggest an application name.
 func extractTitleFromConfig(cfg *config.WorkflowConfig) string {
@@ -189,6 +217,7 @@ type pipelineEndpoint struct {
 	method         string
 	path           string
 	steps          []map[string]any
+	examples       any // the trigger's examples block
 	includeSchemas bool
 }
 
@@ -226,6 +255,9 @@ func extractPipelineRoutes(pipelines map[string]any, includeSchemas bool, gen *m
 		if includeSchemas && len(ep.steps) > 0 {
 			applyPipelineSchemas(gen, ep)
 		}
+		if ep.examples != nil {
+			_ = gen.SetRouteExamples(ep.method, ep.path, ep.examples)
+		}
 
 		routes = append(routes, route)
 	}
@@ -264,6 +296,7 @@ func parsePipelineEndpoint(name string, pipelineMap map[string]any, includeSchem
 		name:           name,
 		method:         method,
 		path:           path,
+		examples:       triggerConfig["examples"],
 		includeSchemas: includeSchemas,
 	}
 
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// configServerFlags registers the --server and --token flags shared by the
+// config approval subcommands.
+func configServerFlags(fs *flag.FlagSet) (server, token *string) {
+	server = fs.String("server", envDefault("WFCTL_SERVER", "http://localhost:8081"), "Workflow engine management API base URL (or WFCTL_SERVER)")
+	token = fs.String("token", os.Getenv("WFCTL_TOKEN"), "Bearer token identifying you to the engine (or WFCTL_TOKEN)")
+	return server, token
+}
+
+func envDefault(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// configAPIRequest sends a request to the engine's /api/workflow endpoints
+// and decodes a JSON response into out. Non-2xx responses become errors
+// carrying the server's error message.
+func configAPIRequest(server, token, method, path, contentType string, body io.Reader, out any) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(server, "/")+path, body)
+	if err != nil {
+		return 0, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req) //nolint:gosec // G107: user-supplied server URL
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error != "" {
+			return resp.StatusCode, fmt.Errorf("%s: %s", resp.Status, apiErr.Error)
+		}
+		return resp.StatusCode, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return resp.StatusCode, fmt.Errorf("decode response: %w", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// runConfigStage submits a config file to the engine. When the engine's
+// environment requires approval the change is staged and its ID printed.
+func runConfigStage(args []string) error {
+	fs := flag.NewFlagSet("config stage", flag.ContinueOnError)
+	server, token := configServerFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), `Usage: wfctl config stage [options] <config.yaml>
+
+Submit a workflow config to a running engine. In environments with
+require_approval set, the change is staged until a second user approves it
+with 'wfctl config approve <id>'.
+
+Options:
+`)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("config file path is required")
+	}
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	var resp struct {
+		Status    string    `json:"status"`
+		ID        string    `json:"id"`
+		ExpiresAt time.Time `json:"expiresAt"`
+	}
+	code, err := configAPIRequest(*server, *token, http.MethodPut, "/api/workflow/config", "application/x-yaml", bytes.NewReader(data), &resp)
+	if err != nil {
+		return fmt.Errorf("stage config: %w", err)
+	}
+	if code == http.StatusAccepted {
+		fmt.Printf("Staged config change %s (expires %s).\n", resp.ID, resp.ExpiresAt.Local().Format(time.RFC3339))
+		fmt.Printf("A second user must approve it: wfctl config approve %s\n", resp.ID)
+		return nil
+	}
+	fmt.Println("Config updated (approval not required). Run a reload to apply it.")
+	return nil
+}
+
+// pendingConfigChange mirrors the engine's pending change JSON.
+type pendingConfigChange struct {
+	ID          string    `json:"id"`
+	Environment string    `json:"environment"`
+	Author      string    `json:"author"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"createdAt"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+	ApprovedBy  string    `json:"approvedBy"`
+	DecidedBy   string    `json:"decidedBy"`
+	Error       string    `json:"error"`
+	Diff        *struct {
+		ModulesAdded    []string `json:"modulesAdded"`
+		ModulesRemoved  []string `json:"modulesRemoved"`
+		ModulesModified []string `json:"modulesModified"`
+		Sections        []string `json:"sections"`
+	} `json:"diff"`
+}
+
+// runConfigPending lists staged config changes, or shows one with its diff
+// against the running config.
+func runConfigPending(args []string) error {
+	fs := flag.NewFlagSet("config pending", flag.ContinueOnError)
+	server, token := configServerFlags(fs)
+	status := fs.String("status", "pending", "Only list changes with this status (pending, approved, rejected, expired, failed; empty for all)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), `Usage: wfctl config pending [options] [id]
+
+List staged config changes, or show one change and its diff against the
+running config.
+
+Options:
+`)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 1 {
+		var c pendingConfigChange
+		if _, err := configAPIRequest(*server, *token, http.MethodGet, "/api/workflow/config/pending/"+url.PathEscape(fs.Arg(0)), "", nil, &c); err != nil {
+			return fmt.Errorf("get pending change: %w", err)
+		}
+		printPendingChange(os.Stdout, c)
+		return nil
+	}
+
+	path := "/api/workflow/config/pending"
+	if *status != "" {
+		path += "?status=" + *status
+	}
+	var changes []pendingConfigChange
+	if _, err := configAPIRequest(*server, *token, http.MethodGet, path, "", nil, &changes); err != nil {
+		return fmt.Errorf("list pending changes: %w", err)
+	}
+	if len(changes) == 0 {
+		fmt.Println("No config changes found.")
+		return nil
+	}
+	fmt.Printf("%-36s  %-9s  %-24s  %s\n", "ID", "STATUS", "AUTHOR", "EXPIRES")
+	for _, c := range changes {
+		fmt.Printf("%-36s  %-9s  %-24s  %s\n", c.ID, c.Status, c.Author, c.ExpiresAt.Local().Format(time.RFC3339))
+	}
+	return nil
+}
+
+func printPendingChange(w io.Writer, c pendingConfigChange) {
+	fmt.Fprintf(w, "ID:          %s\n", c.ID)
+	if c.Environment != "" {
+		fmt.Fprintf(w, "Environment: %s\n", c.Environment)
+	}
+	fmt.Fprintf(w, "Status:      %s\n", c.Status)
+	fmt.Fprintf(w, "Author:      %s\n", c.Author)
+	fmt.Fprintf(w, "Created:     %s\n", c.CreatedAt.Local().Format(time.RFC3339))
+	fmt.Fprintf(w, "Expires:     %s\n", c.ExpiresAt.Local().Format(time.RFC3339))
+	if c.ApprovedBy != "" {
+		fmt.Fprintf(w, "Approved by: %s\n", c.ApprovedBy)
+	} else if c.DecidedBy != "" {
+		fmt.Fprintf(w, "Decided by:  %s\n", c.DecidedBy)
+	}
+	if c.Error != "" {
+		fmt.Fprintf(w, "Error:       %s\n", c.Error)
+	}
+	if c.Diff == nil {
+		return
+	}
+	fmt.Fprintln(w, "\nChanges against the running config:")
+	empty := true
+	for _, group := range []struct {
+		prefix string
+		names  []string
+	}{
+		{"+ module ", c.Diff.ModulesAdded},
+		{"- module ", c.Diff.ModulesRemoved},
+		{"~ module ", c.Diff.ModulesModified},
+		{"~ ", c.Diff.Sections},
+	} {
+		for _, name := range group.names {
+			fmt.Fprintf(w, "  %s%s\n", group.prefix, name)
+			empty = false
+		}
+	}
+	if empty {
+		fmt.Fprintln(w, "  (none)")
+	}
+}
+
+// runConfigApprove approves a staged config change, which applies it and
+// reloads the engine. The approver must be a different user than the author.
+func runConfigApprove(args []string) error {
+	fs := flag.NewFlagSet("config approve", flag.ContinueOnError)
+	server, token := configServerFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), `Usage: wfctl config approve [options] <id>
+
+Approve a staged config change. The engine applies and reloads it. The
+author of a change cannot approve it.
+
+Options:
+`)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("pending change id is required")
+	}
+	var resp struct {
+		Author     string `json:"author"`
+		ApprovedBy string `json:"approvedBy"`
+	}
+	if _, err := configAPIRequest(*server, *token, http.MethodPost, "/api/workflow/config/pending/"+url.PathEscape(fs.Arg(0))+"/approve", "", nil, &resp); err != nil {
+		return fmt.Errorf("approve config change: %w", err)
+	}
+	fmt.Printf("Approved config change %s (author %s, approver %s); engine reloaded.\n", fs.Arg(0), resp.Author, resp.ApprovedBy)
+	return nil
+}
+
+// runConfigReject rejects a staged config change.
+func runConfigReject(args []string) error {
+	fs := flag.NewFlagSet("config reject", flag.ContinueOnError)
+	server, token := configServerFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), `Usage: wfctl config reject [options] <id>
+
+Reject a staged config change so it can no longer be approved.
+
+Options:
+`)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("pending change id is required")
+	}
+	if _, err := configAPIRequest(*server, *token, http.MethodDelete, "/api/workflow/config/pending/"+url.PathEscape(fs.Arg(0)), "", nil, nil); err != nil {
+		return fmt.Errorf("reject config change: %w", err)
+	}
+	fmt.Printf("Rejected config change %s.\n", fs.Arg(0))
+	return nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigStageAndApprove(t *testing.T) {
+	var gotAuth, gotBody, gotContentType []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotAuth = append(gotAuth, r.Header.Get("Authorization"))
+		gotBody = append(gotBody, string(body))
+		gotContentType = append(gotContentType, r.Header.Get("Content-Type"))
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/api/workflow/config":
+			w.WriteHeader(http.StatusAccepted)
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": "pending_approval", "id": "c1", "expiresAt": "2026-01-01T00:00:00Z"})
+		case r.Method == http.MethodPost && r.URL.Path == "/api/workflow/config/pending/c1/approve":
+			if r.Header.Get("Authorization") == "Bearer alice" {
+				w.WriteHeader(http.StatusForbidden)
+				_ = json.NewEncoder(w).Encode(map[string]string{"error": "the author of a change cannot approve it"})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": "reloaded", "author": "alice", "approvedBy": "bob"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	cfgPath := filepath.Join(t.TempDir(), "app.yaml")
+	if err := os.WriteFile(cfgPath, []byte("modules: []\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := runConfigStage([]string{"--server", srv.URL, "--token", "alice", cfgPath}); err != nil {
+		t.Fatalf("stage: %v", err)
+	}
+	if gotAuth[0] != "Bearer alice" || gotContentType[0] != "application/x-yaml" || gotBody[0] != "modules: []\n" {
+		t.Errorf("unexpected stage request: auth=%q type=%q body=%q", gotAuth[0], gotContentType[0], gotBody[0])
+	}
+
+	err := runConfigApprove([]string{"--server", srv.URL, "--token", "alice", "c1"})
+	if err == nil || !strings.Contains(err.Error(), "cannot approve") {
+		t.Fatalf("expected self-approval error from server, got %v", err)
+	}
+	t.Setenv("WFCTL_TOKEN", "bob")
+	if err := runConfigApprove([]string{"--server", srv.URL, "c1"}); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	if gotAuth[2] != "Bearer bob" {
+		t.Errorf("expected WFCTL_TOKEN to be used, got %q", gotAuth[2])
+	}
+}
+
+func TestPrintPendingChange(t *testing.T) {
+	var c pendingConfigChange
+	if err := json.Unmarshal([]byte(`{"id":"c1","author":"alice","status":"pending",
+		"diff":{"modulesAdded":["cache"],"sections":["pipelines"]}}`), &c); err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	printPendingChange(&b, c)
+	out := b.String()
+	for _, want := range []string{"Author:      alice", "+ module cache", "~ pipelines"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
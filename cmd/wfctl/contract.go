@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/json"
 	"flag"
@@ -119,11 +120,21 @@ func runContractTest(args []string) error {
 	baseline := fs2.String("baseline", "", "Previous version's contract file for comparison")
 	output := fs2.String("output", "", "Write contract file to this path")
 	format := fs2.String("format", "text", "Output format: text or json")
+	server := fs2.String("server", "", "Base URL of a running server to exercise the contract's endpoints against")
+	credentials := fs2.String("credentials", "", "Credentials file with auth, TLS and per-route expectations for --server")
+	caFile := fs2.String("ca-cert", "", "CA bundle for verifying the server certificate (overrides tls.ca_file)")
+	certFile := fs2.String("client-cert", "", "Client certificate for mTLS (overrides tls.cert_file)")
+	keyFile := fs2.String("client-key", "", "Client key for mTLS (overrides tls.key_file)")
+	insecure := fs2.Bool("insecure-skip-verify", false, "Skip server certificate verification (self-signed test servers only)")
+	timeout := fs2.Duration("timeout", 10*time.Second, "Per-request timeout for --server checks")
+	junit := fs2.String("junit", "", "Write --server check results as JUnit XML to this path")
 	fs2.Usage = func() {
 		fmt.Fprintf(fs2.Output(), `Usage: wfctl contract test [options] <config.yaml>
 
 Generate a contract snapshot from a workflow config file.
 Optionally compare against a baseline contract to detect breaking changes.
+With --server, call every endpoint on a running server with and without
+credentials and report contract, auth and connectivity failures.
 
 Options:
 `)
@@ -145,6 +156,27 @@ Options:
 
 	contract := generateContract(cfg)
 
+	var creds *contractCredentials
+	if *server != "" {
+		if creds, err = loadContractCredentials(*credentials); err != nil {
+			return err
+		}
+		if *caFile != "" {
+			creds.TLS.CAFile = *caFile
+		}
+		if *certFile != "" {
+			creds.TLS.CertFile = *certFile
+		}
+		if *keyFile != "" {
+			creds.TLS.KeyFile = *keyFile
+		}
+		if *insecure {
+			creds.TLS.InsecureSkipVerify = true
+		}
+	} else if *credentials != "" || *junit != "" {
+		return fmt.Errorf("--credentials and --junit require --server")
+	}
+
 	// Write contract to output file if requested
 	if *output != "" {
 		f, err := os.Create(*output)
@@ -172,6 +204,9 @@ Options:
 		}
 
 		comparison := compareContracts(&baseContract, contract)
+		if *server != "" {
+			return runLiveContractTest(*server, creds, *timeout, *junit, *format, contract, comparison)
+		}
 
 		switch strings.ToLower(*format) {
 		case "json":
@@ -188,6 +223,10 @@ Options:
 		return nil
 	}
 
+	if *server != "" {
+		return runLiveContractTest(*server, creds, *timeout, *junit, *format, contract, nil)
+	}
+
 	// Print contract summary
 	switch strings.ToLower(*format) {
 	case "json":
@@ -201,6 +240,38 @@ Options:
 	return nil
 }
 
+// runLiveContractTest exercises the contract against a running server and
+// reports the results. Baseline breaking changes, when comp is set, are
+// reported alongside as contract failures.
+func runLiveContractTest(server string, creds *contractCredentials, timeout time.Duration, junitPath, format string, contract *Contract, comp *contractComparison) error {
+	client, err := buildContractHTTPClient(creds.TLS, timeout)
+	if err != nil {
+		return err
+	}
+	report := newLiveContractRunner(server, creds, client).run(context.Background(), contract, comp)
+
+	if junitPath != "" {
+		if err := writeLiveReportJUnit(junitPath, report); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "JUnit report written to %s\n", junitPath)
+	}
+	switch strings.ToLower(format) {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	default:
+		if comp != nil {
+			printContractComparison(comp)
+		}
+		printLiveReport(report)
+	}
+	return liveReportError(report)
+}
+
 // generateContract builds a Contract from a WorkflowConfig.
 func generateContract(cfg *config.WorkflowConfig) *Contract {
 	knownModules := KnownModuleTypes()
@@ -0,0 +1,645 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// contractCredentials is the --credentials file for live contract tests.
+// ${VAR} references are expanded from the environment before parsing so
+// secrets can stay out of the file.
+type contractCredentials struct {
+	Auth   contractAuthConfig    `yaml:"auth"`
+	TLS    contractTLSConfig     `yaml:"tls"`
+	Routes []contractRouteConfig `yaml:"routes"`
+}
+
+// contractAuthConfig selects how authenticated requests are made: a static
+// token, a token fetched from a login endpoint, or an API key header.
+type contractAuthConfig struct {
+	Token  string                `yaml:"token"`
+	Header string                `yaml:"header"` // default Authorization
+	Scheme string                `yaml:"scheme"` // default Bearer
+	Login  *contractLoginConfig  `yaml:"login"`
+	APIKey *contractAPIKeyConfig `yaml:"api_key"`
+}
+
+type contractLoginConfig struct {
+	Endpoint      string `yaml:"endpoint"`
+	Method        string `yaml:"method"` // default POST
+	Username      string `yaml:"username"`
+	Password      string `yaml:"password"`
+	UsernameField string `yaml:"username_field"` // default username
+	PasswordField string `yaml:"password_field"` // default password
+	TokenField    string `yaml:"token_field"`    // dot path; default token, access_token or accessToken
+}
+
+type contractAPIKeyConfig struct {
+	Header string `yaml:"header"`
+	Value  string `yaml:"value"`
+}
+
+type contractTLSConfig struct {
+	CAFile             string `yaml:"ca_file"`
+	CertFile           string `yaml:"cert_file"`
+	KeyFile            string `yaml:"key_file"`
+	ServerName         string `yaml:"server_name"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+}
+
+// contractRouteConfig overrides how one contract endpoint is exercised.
+type contractRouteConfig struct {
+	Method          string `yaml:"method"`
+	Path            string `yaml:"path"`         // path as it appears in the contract
+	RequestPath     string `yaml:"request_path"` // concrete path to call, for paths with parameters
+	Body            any    `yaml:"body"`
+	Authenticated   int    `yaml:"authenticated"`   // expected status with credentials
+	Unauthenticated int    `yaml:"unauthenticated"` // expected status without credentials
+	Skip            bool   `yaml:"skip"`
+}
+
+// Failure categories in a live contract test report.
+const (
+	liveCategoryContract     = "contract"
+	liveCategoryAuth         = "auth"
+	liveCategoryConnectivity = "connectivity"
+)
+
+// liveCheckResult is the outcome of one live request.
+type liveCheckResult struct {
+	Name       string  `json:"name"`
+	Method     string  `json:"method,omitempty"`
+	Path       string  `json:"path,omitempty"`
+	Mode       string  `json:"mode"` // authenticated, unauthenticated, login or baseline
+	Status     int     `json:"status,omitempty"`
+	Expected   string  `json:"expected,omitempty"`
+	Category   string  `json:"category,omitempty"`
+	Failure    string  `json:"failure,omitempty"`
+	Skipped    string  `json:"skipped,omitempty"`
+	DurationMS float64 `json:"durationMs"`
+}
+
+// liveReport collects the live checks run against one server.
+type liveReport struct {
+	Server   string            `json:"server"`
+	Passed   int               `json:"passed"`
+	Failed   int               `json:"failed"`
+	Skipped  int               `json:"skipped"`
+	Failures map[string]int    `json:"failures"` // category → count
+	Results  []liveCheckResult `json:"results"`
+}
+
+func (r *liveReport) add(res liveCheckResult) {
+	switch {
+	case res.Failure != "":
+		r.Failed++
+		r.Failures[res.Category]++
+	case res.Skipped != "":
+		r.Skipped++
+	default:
+		r.Passed++
+	}
+	r.Results = append(r.Results, res)
+}
+
+// liveContractRunner exercises a contract's endpoints against a running
+// server.
+type liveContractRunner struct {
+	baseURL string
+	client  *http.Client
+	creds   *contractCredentials
+	routes  map[string]contractRouteConfig // "METHOD path" → override
+
+	authHeader string
+	authValue  string
+	authErr    string // why authenticated checks cannot run
+}
+
+// loadContractCredentials reads a credentials file, expanding ${VAR}
+// references from the environment.
+func loadContractCredentials(path string) (*contractCredentials, error) {
+	creds := &contractCredentials{}
+	if path == "" {
+		return creds, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials: %w", err)
+	}
+	if err := yaml.Unmarshal([]byte(os.ExpandEnv(string(data))), creds); err != nil {
+		return nil, fmt.Errorf("failed to parse credentials %s: %w", path, err)
+	}
+	return creds, nil
+}
+
+// buildContractHTTPClient returns a client honouring the TLS options: a
+// custom CA bundle, a client certificate for mTLS, a server name override
+// and, for local testing only, skipped verification.
+func buildContractHTTPClient(cfg contractTLSConfig, timeout time.Duration) (*http.Client, error) {
+	tlsCfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify, //nolint:gosec // opt-in flag for self-signed test servers
+	}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", cfg.CAFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		if cfg.CertFile == "" || cfg.KeyFile == "" {
+			return nil, fmt.Errorf("client certificate and key must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsCfg
+	return &http.Client{Transport: transport, Timeout: timeout}, nil
+}
+
+func newLiveContractRunner(baseURL string, creds *contractCredentials, client *http.Client) *liveContractRunner {
+	r := &liveContractRunner{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  client,
+		creds:   creds,
+		routes:  make(map[string]contractRouteConfig, len(creds.Routes)),
+	}
+	for _, rt := range creds.Routes {
+		r.routes[strings.ToUpper(rt.Method)+" "+rt.Path] = rt
+	}
+	return r
+}
+
+// run authenticates, then calls every endpoint with and without
+// credentials. Breaking changes from a baseline comparison are reported as
+// contract failures so CI sees one result set.
+func (r *liveContractRunner) run(ctx context.Context, c *Contract, comp *contractComparison) *liveReport {
+	report := &liveReport{Server: r.baseURL, Failures: map[string]int{}}
+	if comp != nil {
+		for _, ec := range comp.Endpoints {
+			if ec.IsBreaking {
+				report.add(liveCheckResult{
+					Name:     fmt.Sprintf("%s %s (baseline)", ec.Method, ec.Path),
+					Method:   ec.Method,
+					Path:     ec.Path,
+					Mode:     "baseline",
+					Category: liveCategoryContract,
+					Failure:  ec.Detail,
+				})
+			}
+		}
+	}
+	if res, ok := r.authenticate(ctx); ok {
+		report.add(res)
+	}
+	for _, ep := range c.Endpoints {
+		for _, res := range r.checkEndpoint(ctx, ep) {
+			report.add(res)
+		}
+	}
+	return report
+}
+
+// authenticate resolves the credential header. It returns a result only
+// when a login request was made.
+func (r *liveContractRunner) authenticate(ctx context.Context) (liveCheckResult, bool) {
+	a := r.creds.Auth
+	switch {
+	case a.APIKey != nil:
+		if a.APIKey.Header == "" || a.APIKey.Value == "" {
+			r.authErr = "api_key requires header and value"
+			return liveCheckResult{}, false
+		}
+		r.authHeader, r.authValue = a.APIKey.Header, a.APIKey.Value
+		return liveCheckResult{}, false
+	case a.Token != "":
+		r.setToken(a.Token)
+		return liveCheckResult{}, false
+	case a.Login != nil:
+		return r.login(ctx, a.Login), true
+	default:
+		r.authErr = "no credentials configured"
+		return liveCheckResult{}, false
+	}
+}
+
+func (r *liveContractRunner) setToken(token string) {
+	r.authHeader = r.creds.Auth.Header
+	if r.authHeader == "" {
+		r.authHeader = "Authorization"
+	}
+	scheme := r.creds.Auth.Scheme
+	if scheme == "" && r.authHeader == "Authorization" {
+		scheme = "Bearer"
+	}
+	r.authValue = strings.TrimSpace(scheme + " " + token)
+}
+
+func (r *liveContractRunner) login(ctx context.Context, l *contractLoginConfig) liveCheckResult {
+	method := strings.ToUpper(l.Method)
+	if method == "" {
+		method = http.MethodPost
+	}
+	res := liveCheckResult{Name: "login " + l.Endpoint, Method: method, Path: l.Endpoint, Mode: "login", Expected: "2xx with token"}
+	userField, passField := l.UsernameField, l.PasswordField
+	if userField == "" {
+		userField = "username"
+	}
+	if passField == "" {
+		passField = "password"
+	}
+	body := map[string]any{userField: l.Username, passField: l.Password}
+
+	status, respBody, elapsed, err := r.do(ctx, method, l.Endpoint, body, false)
+	res.Status, res.DurationMS = status, elapsed
+	if err != nil {
+		res.Category, res.Failure = liveCategoryConnectivity, err.Error()
+		r.authErr = "login failed"
+		return res
+	}
+	if status < 200 || status > 299 {
+		res.Category, res.Failure = liveCategoryAuth, fmt.Sprintf("login returned %d", status)
+		r.authErr = "login failed"
+		return res
+	}
+	token := extractLoginToken(respBody, l.TokenField)
+	if token == "" {
+		res.Category, res.Failure = liveCategoryAuth, "login response did not contain a token"
+		r.authErr = "login failed"
+		return res
+	}
+	r.setToken(token)
+	return res
+}
+
+// extractLoginToken reads a token from a JSON login response. field is a
+// dot path; when empty the common token field names are tried.
+func extractLoginToken(body []byte, field string) string {
+	var doc map[string]any
+	if json.Unmarshal(body, &doc) != nil {
+		return ""
+	}
+	fields := []string{"token", "access_token", "accessToken"}
+	if field != "" {
+		fields = []string{field}
+	}
+	for _, f := range fields {
+		var cur any = doc
+		for _, part := range strings.Split(f, ".") {
+			m, ok := cur.(map[string]any)
+			if !ok {
+				cur = nil
+				break
+			}
+			cur = m[part]
+		}
+		if s, ok := cur.(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// checkEndpoint runs the authenticated call and, for protected routes or
+// routes with an explicit expectation, the unauthenticated call.
+func (r *liveContractRunner) checkEndpoint(ctx context.Context, ep EndpointContract) []liveCheckResult {
+	route := r.routes[ep.Method+" "+ep.Path]
+	label := ep.Method + " " + ep.Path
+	path := ep.Path
+	if route.RequestPath != "" {
+		path = route.RequestPath
+	}
+
+	var skip string
+	switch {
+	case route.Skip:
+		skip = "skipped by route config"
+	case hasPathParams(path):
+		skip = "path has parameters; set request_path in the route config"
+	}
+	if skip != "" {
+		return []liveCheckResult{
+			{Name: label + " (authenticated)", Method: ep.Method, Path: ep.Path, Mode: "authenticated", Skipped: skip},
+		}
+	}
+
+	var results []liveCheckResult
+	authed := liveCheckResult{Name: label + " (authenticated)", Method: ep.Method, Path: ep.Path, Mode: "authenticated"}
+	if r.authErr != "" && ep.AuthRequired {
+		authed.Skipped = r.authErr
+	} else {
+		r.exercise(ctx, &authed, ep, path, route.Body, true, route.Authenticated)
+	}
+	results = append(results, authed)
+
+	if ep.AuthRequired || route.Unauthenticated != 0 {
+		want := route.Unauthenticated
+		if want == 0 {
+			want = http.StatusUnauthorized
+		}
+		anon := liveCheckResult{Name: label + " (unauthenticated)", Method: ep.Method, Path: ep.Path, Mode: "unauthenticated"}
+		r.exercise(ctx, &anon, ep, path, route.Body, false, want)
+		results = append(results, anon)
+	}
+	return results
+}
+
+// exercise makes one request and classifies the outcome. want of zero means
+// any status that shows the route exists and accepted the caller.
+func (r *liveContractRunner) exercise(ctx context.Context, res *liveCheckResult, ep EndpointContract, path string, body any, withAuth bool, want int) {
+	status, respBody, elapsed, err := r.do(ctx, ep.Method, path, body, withAuth)
+	res.Status, res.DurationMS = status, elapsed
+	if err != nil {
+		res.Category, res.Failure = liveCategoryConnectivity, err.Error()
+		return
+	}
+
+	if want != 0 {
+		res.Expected = fmt.Sprint(want)
+		if status != want {
+			res.Failure = fmt.Sprintf("expected status %d, got %d", want, status)
+			res.Category = liveCategoryContract
+			if !withAuth {
+				if status < 400 {
+					res.Failure = fmt.Sprintf("protected route accepted an unauthenticated request (status %d)", status)
+				}
+				res.Category = liveCategoryAuth
+			} else if status == http.StatusUnauthorized || status == http.StatusForbidden {
+				res.Category = liveCategoryAuth
+			}
+			return
+		}
+	} else {
+		res.Expected = "not 401/403/404/405/5xx"
+		switch {
+		case status == http.StatusUnauthorized || status == http.StatusForbidden:
+			res.Category, res.Failure = liveCategoryAuth, fmt.Sprintf("credentials rejected (status %d)", status)
+			return
+		case status == http.StatusNotFound || status == http.StatusMethodNotAllowed:
+			res.Category, res.Failure = liveCategoryContract, fmt.Sprintf("route not served (status %d)", status)
+			return
+		case status >= 500:
+			res.Category, res.Failure = liveCategoryContract, fmt.Sprintf("server error (status %d)", status)
+			return
+		}
+	}
+
+	if withAuth && status >= 200 && status < 300 && len(ep.ResponseSchema) > 0 {
+		if mismatch := checkResponseSchema(respBody, ep.ResponseSchema); mismatch != "" {
+			res.Category, res.Failure = liveCategoryContract, mismatch
+		}
+	}
+}
+
+// do sends one request. It returns the status, body and elapsed milliseconds,
+// or an error when no response was received.
+func (r *liveContractRunner) do(ctx context.Context, method, path string, body any, withAuth bool) (int, []byte, float64, error) {
+	var reader io.Reader
+	if body != nil || method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch {
+		if body == nil {
+			body = map[string]any{}
+		}
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, nil, 0, fmt.Errorf("encode request body: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, r.baseURL+path, reader)
+	if err != nil {
+		return 0, nil, 0, err
+	}
+	if reader != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	if withAuth && r.authHeader != "" {
+		req.Header.Set(r.authHeader, r.authValue)
+	}
+
+	start := time.Now()
+	resp, err := r.client.Do(req)
+	elapsed := float64(time.Since(start).Microseconds()) / 1000
+	if err != nil {
+		return 0, nil, elapsed, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return resp.StatusCode, nil, elapsed, fmt.Errorf("read response: %w", err)
+	}
+	return resp.StatusCode, data, elapsed, nil
+}
+
+// hasPathParams reports whether path has {name} or :name segments.
+func hasPathParams(path string) bool {
+	for _, seg := range strings.Split(path, "/") {
+		if strings.HasPrefix(seg, ":") || strings.Contains(seg, "{") {
+			return true
+		}
+	}
+	return false
+}
+
+// checkResponseSchema compares a JSON object response to the declared
+// field types and describes the first mismatch.
+func checkResponseSchema(body []byte, schema map[string]string) string {
+	var doc map[string]any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return "response is not a JSON object"
+	}
+	fields := make([]string, 0, len(schema))
+	for f := range schema {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	for _, f := range fields {
+		v, ok := doc[f]
+		if !ok {
+			return fmt.Sprintf("response field %q missing", f)
+		}
+		if got := jsonTypeName(v); !jsonTypeMatches(schema[f], got) {
+			return fmt.Sprintf("response field %q is %s, contract says %s", f, got, schema[f])
+		}
+	}
+	return ""
+}
+
+func jsonTypeName(v any) string {
+	switch n := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		if n == float64(int64(n)) {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func jsonTypeMatches(want, got string) bool {
+	switch want {
+	case "", "any", got:
+		return true
+	case "number":
+		return got == "integer"
+	default:
+		return got == "null"
+	}
+}
+
+// printLiveReport prints the live results followed by a failure summary
+// split by category.
+func printLiveReport(r *liveReport) {
+	fmt.Printf("\nLive checks against %s\n\n", r.Server)
+	for _, res := range r.Results {
+		switch {
+		case res.Failure != "":
+			fmt.Printf("  FAIL  %s [%s]\n        %s\n", res.Name, res.Category, res.Failure)
+		case res.Skipped != "":
+			fmt.Printf("  SKIP  %s: %s\n", res.Name, res.Skipped)
+		default:
+			fmt.Printf("  PASS  %s (%d)\n", res.Name, res.Status)
+		}
+	}
+	fmt.Printf("\n%d passed, %d failed, %d skipped\n", r.Passed, r.Failed, r.Skipped)
+	if r.Failed == 0 {
+		return
+	}
+	fmt.Println("\nFailure summary:")
+	for _, cat := range []struct{ key, title string }{
+		{liveCategoryContract, "Contract mismatches"},
+		{liveCategoryAuth, "Auth problems"},
+		{liveCategoryConnectivity, "Connectivity problems"},
+	} {
+		if r.Failures[cat.key] == 0 {
+			continue
+		}
+		fmt.Printf("  %s (%d):\n", cat.title, r.Failures[cat.key])
+		for _, res := range r.Results {
+			if res.Category == cat.key && res.Failure != "" {
+				fmt.Printf("    - %s: %s\n", res.Name, res.Failure)
+			}
+		}
+	}
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// writeLiveReportJUnit writes the report as JUnit XML. The failure type is
+// the category so CI dashboards can group contract and auth failures.
+func writeLiveReportJUnit(path string, r *liveReport) error {
+	suite := junitTestSuite{
+		Name:     "wfctl contract test",
+		Tests:    len(r.Results),
+		Failures: r.Failed,
+		Skipped:  r.Skipped,
+	}
+	var total float64
+	for _, res := range r.Results {
+		total += res.DurationMS
+		tc := junitTestCase{
+			Name:      res.Name,
+			ClassName: "contract." + res.Mode,
+			Time:      fmt.Sprintf("%.3f", res.DurationMS/1000),
+		}
+		switch {
+		case res.Failure != "":
+			tc.Failure = &junitFailure{
+				Message: res.Failure,
+				Type:    res.Category,
+				Body:    fmt.Sprintf("expected: %s\nstatus: %d\n", res.Expected, res.Status),
+			}
+		case res.Skipped != "":
+			tc.Skipped = &junitSkipped{Message: res.Skipped}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	suite.Time = fmt.Sprintf("%.3f", total/1000)
+
+	data, err := xml.MarshalIndent(junitTestSuites{Suites: []junitTestSuite{suite}}, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append([]byte(xml.Header), append(data, '\n')...)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	return nil
+}
+
+// liveReportError summarises a failing report as the command's error.
+func liveReportError(r *liveReport) error {
+	if r.Failed == 0 {
+		return nil
+	}
+	var parts []string
+	for _, cat := range []string{liveCategoryContract, liveCategoryAuth, liveCategoryConnectivity} {
+		if n := r.Failures[cat]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, cat))
+		}
+	}
+	return errors.New("live contract checks failed: " + strings.Join(parts, ", "))
+}
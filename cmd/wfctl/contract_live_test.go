@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const liveContractConfig = `
+pipelines:
+  list-items:
+    trigger:
+      type: http
+      config:
+        path: /api/items
+        method: GET
+    outputs:
+      items:
+        type: array
+      count:
+        type: integer
+    steps:
+      - name: respond
+        type: step.json_response
+  create-item:
+    trigger:
+      type: http
+      config:
+        path: /api/items
+        method: POST
+    steps:
+      - name: auth
+        type: step.auth_required
+  get-item:
+    trigger:
+      type: http
+      config:
+        path: /api/items/{id}
+        method: GET
+    steps:
+      - name: auth
+        type: step.auth_required
+  admin:
+    trigger:
+      type: http
+      config:
+        path: /api/admin
+        method: GET
+    steps:
+      - name: auth
+        type: step.auth_required
+`
+
+// liveContractHandler enforces a bearer token on POST /api/items and
+// /api/items/42 but forgets to protect /api/admin.
+func liveContractHandler(token string) http.Handler {
+	mux := http.NewServeMux()
+	authed := func(r *http.Request) bool { return r.Header.Get("Authorization") == "Bearer "+token }
+	mux.HandleFunc("POST /api/login", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["email"] != "alice@example.com" || body["password"] != "pw" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]string{"jwt": token}})
+	})
+	mux.HandleFunc("GET /api/items", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"items": []string{}, "count": 0})
+	})
+	mux.HandleFunc("POST /api/items", func(w http.ResponseWriter, r *http.Request) {
+		if !authed(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("GET /api/items/42", func(w http.ResponseWriter, r *http.Request) {
+		if !authed(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("GET /api/admin", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return mux
+}
+
+func writeLiveContractFiles(t *testing.T, creds string) (dir, configPath, credsPath string) {
+	t.Helper()
+	dir = t.TempDir()
+	configPath = filepath.Join(dir, "workflow.yaml")
+	credsPath = filepath.Join(dir, "credentials.yaml")
+	if err := os.WriteFile(configPath, []byte(liveContractConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(credsPath, []byte(creds), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return dir, configPath, credsPath
+}
+
+func TestRunContractTestLiveTLSWithToken(t *testing.T) {
+	srv := httptest.NewTLSServer(liveContractHandler("secret"))
+	defer srv.Close()
+	t.Setenv("CONTRACT_TOKEN", "secret")
+
+	dir, configPath, credsPath := writeLiveContractFiles(t, `
+auth:
+  token: ${CONTRACT_TOKEN}
+routes:
+  - method: GET
+    path: /api/items/{id}
+    request_path: /api/items/42
+`)
+	caPath := filepath.Join(dir, "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caPath, caPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	junitPath := filepath.Join(dir, "junit.xml")
+
+	err := runContractTest([]string{
+		"-server", srv.URL, "-credentials", credsPath, "-ca-cert", caPath, "-junit", junitPath, configPath,
+	})
+	if err == nil || !strings.HasSuffix(err.Error(), "failed: 1 auth") {
+		t.Fatalf("expected only the unprotected /api/admin to fail as an auth problem, got: %v", err)
+	}
+
+	data, err := os.ReadFile(junitPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var suites junitTestSuites
+	if err := xml.Unmarshal(data, &suites); err != nil {
+		t.Fatalf("invalid JUnit XML: %v", err)
+	}
+	suite := suites.Suites[0]
+	// admin, items POST and items/{id} each get two checks; GET /api/items one.
+	if suite.Tests != 7 || suite.Failures != 1 || suite.Skipped != 0 {
+		t.Fatalf("suite counts = tests %d failures %d skipped %d", suite.Tests, suite.Failures, suite.Skipped)
+	}
+	for _, tc := range suite.Cases {
+		if tc.Failure != nil && (tc.Name != "GET /api/admin (unauthenticated)" || tc.Failure.Type != liveCategoryAuth) {
+			t.Errorf("unexpected failure %s: %+v", tc.Name, tc.Failure)
+		}
+	}
+}
+
+func TestRunContractTestLiveRequiresServer(t *testing.T) {
+	_, configPath, credsPath := writeLiveContractFiles(t, "")
+	if err := runContractTest([]string{"-credentials", credsPath, configPath}); err == nil {
+		t.Fatal("expected --credentials without --server to fail")
+	}
+}
+
+func TestLiveContractRunnerLogin(t *testing.T) {
+	srv := httptest.NewServer(liveContractHandler("jwt-123"))
+	defer srv.Close()
+
+	contract := &Contract{Endpoints: []EndpointContract{{Method: "POST", Path: "/api/items", AuthRequired: true}}}
+	creds := &contractCredentials{Auth: contractAuthConfig{Login: &contractLoginConfig{
+		Endpoint: "/api/login", Username: "alice@example.com", Password: "pw",
+		UsernameField: "email", TokenField: "data.jwt",
+	}}}
+	report := newLiveContractRunner(srv.URL, creds, srv.Client()).run(context.Background(), contract, nil)
+	if report.Failed != 0 || report.Passed != 3 {
+		t.Fatalf("report = %+v", report)
+	}
+
+	creds.Auth.Login.Password = "wrong"
+	report = newLiveContractRunner(srv.URL, creds, srv.Client()).run(context.Background(), contract, nil)
+	if report.Failures[liveCategoryAuth] != 1 || report.Skipped != 1 {
+		t.Fatalf("failed login should be one auth failure and skip the authenticated call: %+v", report)
+	}
+}
+
+func TestLiveContractRunnerCategories(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/wrong-shape":
+			_ = json.NewEncoder(w).Encode(map[string]any{"count": "many"})
+		case "/forbidden":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	contract := &Contract{Endpoints: []EndpointContract{
+		{Method: "GET", Path: "/wrong-shape", ResponseSchema: map[string]string{"count": "integer"}},
+		{Method: "GET", Path: "/forbidden"},
+		{Method: "GET", Path: "/missing"},
+	}}
+	comp := &contractComparison{Endpoints: []endpointChange{
+		{Method: "GET", Path: "/old", Change: changeRemoved, Detail: "endpoint removed", IsBreaking: true},
+	}}
+	creds := &contractCredentials{Auth: contractAuthConfig{APIKey: &contractAPIKeyConfig{Header: "X-API-Key", Value: "k"}}}
+	report := newLiveContractRunner(srv.URL, creds, srv.Client()).run(context.Background(), contract, comp)
+	if report.Failures[liveCategoryContract] != 3 || report.Failures[liveCategoryAuth] != 1 {
+		t.Fatalf("failures = %v", report.Failures)
+	}
+
+	srv.Close()
+	client, err := buildContractHTTPClient(contractTLSConfig{}, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report = newLiveContractRunner(srv.URL, creds, client).run(context.Background(), contract, nil)
+	if report.Failures[liveCategoryConnectivity] != 3 {
+		t.Fatalf("closed server should be connectivity failures: %v", report.Failures)
+	}
+}
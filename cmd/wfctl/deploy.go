@@ -22,6 +22,7 @@ import (
 )
 
 func runDeploy(args []string) error {
+	args = moveLeadingPlanFlag(args)
 	if len(args) < 1 {
 		return deployUsage()
 	}
@@ -39,6 +40,23 @@ func runDeploy(args []string) error {
 	}
 }
 
+// moveLeadingPlanFlag rewrites "deploy --plan <target> ..." so the flag is
+// parsed by the target: after the target name, or after the subcommand for
+// kubernetes.
+func moveLeadingPlanFlag(args []string) []string {
+	if len(args) < 2 || (args[0] != "--plan" && args[0] != "-plan") {
+		return args
+	}
+	rest := args[1:]
+	at := 1
+	if (rest[0] == "kubernetes" || rest[0] == "k8s") && len(rest) > 1 {
+		at = 2
+	}
+	out := append([]string{}, rest[:at]...)
+	out = append(out, "--plan")
+	return append(out, rest[at:]...)
+}
+
 func deployUsage() error {
 	fmt.Fprintf(flag.CommandLine.Output(), `Usage: wfctl deploy <target> [options]
 
@@ -105,6 +123,11 @@ Persisting defaults (.wfctl.yaml):
       runtime: minikube
       registry: ghcr.io/myorg
 
+Plan mode (render artifacts and list resources without applying):
+  wfctl deploy --plan docker -config workflow.yaml
+  wfctl deploy --plan k8s apply -config app.yaml -image myapp:v1
+  wfctl deploy helm --plan -namespace prod
+
 Other examples:
   wfctl deploy docker -config workflow.yaml
   wfctl deploy helm -namespace prod -values custom.yaml
@@ -119,6 +142,7 @@ func runDeployDocker(args []string) error {
 	config := fs.String("config", "workflow.yaml", "Workflow config file to deploy")
 	image := fs.String("image", "workflow-app:local", "Docker image name:tag to build")
 	noCompose := fs.Bool("no-compose", false, "Build image only, skip docker compose up")
+	plan := fs.Bool("plan", false, "Print the Dockerfile, compose file and resources that would be used, without writing, building or starting anything")
 	fs.Usage = func() {
 		fmt.Fprintf(fs.Output(), `Usage: wfctl deploy docker [options]
 
@@ -141,6 +165,15 @@ Options:
 		return fmt.Errorf("get working directory: %w", err)
 	}
 
+	if *plan {
+		p, err := planDocker(cwd, *config, *image, *noCompose)
+		if err != nil {
+			return err
+		}
+		printDeployPlan(os.Stdout, p)
+		return nil
+	}
+
 	// Generate Dockerfile if missing
 	dockerfilePath := filepath.Join(cwd, "Dockerfile")
 	if _, err := os.Stat(dockerfilePath); os.IsNotExist(err) {
@@ -197,6 +230,7 @@ func runDeployHelm(args []string) error {
 	valuesFile := fs.String("values", "", "Additional Helm values file (-f flag passed to helm)")
 	setValues := fs.String("set", "", "Comma-separated key=value pairs to override (--set passed to helm)")
 	dryRun := fs.Bool("dry-run", false, "Pass --dry-run to helm (simulate install)")
+	plan := fs.Bool("plan", false, "Render the chart with helm template and list its resources, without contacting the cluster")
 	fs.Usage = func() {
 		fmt.Fprintf(fs.Output(), `Usage: wfctl deploy helm [options]
 
@@ -236,27 +270,30 @@ Options:
 		return fmt.Errorf("helm not found in PATH: install from https://helm.sh/docs/intro/install/")
 	}
 
-	helmArgs := []string{
-		"upgrade", "--install",
-		*releaseName,
-		chart,
-		"--namespace", *namespace,
-		"--create-namespace",
-	}
-
+	valueArgs := []string{"--namespace", *namespace}
 	if *valuesFile != "" {
-		helmArgs = append(helmArgs, "-f", *valuesFile)
+		valueArgs = append(valueArgs, "-f", *valuesFile)
 	}
-
 	if *setValues != "" {
 		for _, pair := range strings.Split(*setValues, ",") {
 			pair = strings.TrimSpace(pair)
 			if pair != "" {
-				helmArgs = append(helmArgs, "--set", pair)
+				valueArgs = append(valueArgs, "--set", pair)
 			}
 		}
 	}
 
+	if *plan {
+		p, err := planHelm(*releaseName, chart, valueArgs)
+		if err != nil {
+			return err
+		}
+		printDeployPlan(os.Stdout, p)
+		return nil
+	}
+
+	helmArgs := append([]string{"upgrade", "--install", *releaseName, chart, "--create-namespace"}, valueArgs...)
+
 	if *dryRun {
 		helmArgs = append(helmArgs, "--dry-run")
 	}
@@ -473,6 +510,7 @@ func runK8sApply(args []string) error {
 	buildArgStr := fs.String("build-arg", "", "Docker build args (comma-separated KEY=VALUE pairs)")
 	runtime := fs.String("runtime", "", "Cluster runtime override (minikube|kind|docker-desktop|k3d|remote)")
 	registry := fs.String("registry", "", "Registry for remote clusters (e.g. ghcr.io/org)")
+	plan := fs.Bool("plan", false, "Print the rendered manifests and which resources would be created or updated, without building, loading or applying")
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
@@ -510,7 +548,11 @@ func runK8sApply(args []string) error {
 		}
 	}
 
-	if *build {
+	if *build && *plan {
+		// A plan renders the image reference the build would produce but
+		// does not build or load it.
+		f.image = resolveImageTag(f.image)
+	} else if *build {
 		// Resolve image tag if none provided
 		f.image = resolveImageTag(f.image)
 
@@ -599,6 +641,14 @@ func runK8sApply(args []string) error {
 	req.Sidecars = sidecarSpecs
 
 	target := k8s.NewDeployTarget()
+	if *plan {
+		p, err := planK8s(context.Background(), target, req)
+		if err != nil {
+			return err
+		}
+		printDeployPlan(os.Stdout, p)
+		return nil
+	}
 	artifacts, err := target.Generate(context.Background(), req)
 	if err != nil {
 		return fmt.Errorf("generate: %w", err)
@@ -773,6 +823,7 @@ func runDeployCloud(args []string) error {
 	target := fs.String("target", "", "Deployment target: staging or production")
 	configFile := fs.String("config", "", "Workflow config file (default: app.yaml or workflow.yaml)")
 	dryRun := fs.Bool("dry-run", false, "Show plan without applying changes")
+	plan := fs.Bool("plan", false, "Alias for --dry-run")
 	yes := fs.Bool("yes", false, "Skip confirmation prompt")
 	fs.Usage = func() {
 		fmt.Fprintf(fs.Output(), `Usage: wfctl deploy cloud [options]
@@ -912,7 +963,7 @@ Options:
 	}
 	fmt.Println()
 
-	if *dryRun {
+	if *dryRun || *plan {
 		fmt.Printf("Dry run complete. Use 'wfctl deploy cloud --yes' to apply.\n")
 		return nil
 	}
@@ -971,7 +1022,12 @@ func runDirectMigrationDeployGuard(configFile, envName string) error {
 
 // writeDockerfile writes a minimal multi-stage Dockerfile suitable for workflow engine projects.
 func writeDockerfile(path string) error {
-	const content = `# Auto-generated by wfctl deploy docker
+	return os.WriteFile(path, []byte(renderDockerfile()), 0640) //nolint:gosec // G306: generated project file
+}
+
+// renderDockerfile returns the Dockerfile writeDockerfile generates.
+func renderDockerfile() string {
+	return `# Auto-generated by wfctl deploy docker
 # Multi-stage build for a workflow engine application.
 
 FROM golang:1.26.5-alpine AS builder
@@ -991,12 +1047,17 @@ USER nonroot
 EXPOSE 8080 8081
 ENTRYPOINT ["./app"]
 `
-	return os.WriteFile(path, []byte(content), 0640) //nolint:gosec // G306: generated project file
 }
 
 // writeDockerCompose writes a minimal docker-compose.yml for the workflow app.
 func writeDockerCompose(path, configFile, image string) error {
-	content := fmt.Sprintf(`# Auto-generated by wfctl deploy docker
+	return os.WriteFile(path, []byte(renderDockerCompose(configFile, image)), 0640) //nolint:gosec // G306: generated project file
+}
+
+// renderDockerCompose returns the docker-compose.yml writeDockerCompose
+// generates.
+func renderDockerCompose(configFile, image string) string {
+	return fmt.Sprintf(`# Auto-generated by wfctl deploy docker
 services:
   app:
     image: %s
@@ -1018,5 +1079,4 @@ services:
       retries: 5
       start_period: 30s
 `, image, configFile)
-	return os.WriteFile(path, []byte(content), 0640) //nolint:gosec // G306: generated project file
 }
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/GoCodeAlone/workflow/deploy"
+	k8s "github.com/GoCodeAlone/workflow/pkg/k8s"
+)
+
+// deployPlan is what a deploy would do, rendered without applying anything:
+// the artifacts it would write or hand to the target, and the resources it
+// would create or change.
+type deployPlan struct {
+	Target    string
+	Artifacts []deployPlanArtifact
+	Resources []deployPlanResource
+	Notes     []string
+}
+
+// deployPlanArtifact is one rendered file. Action is "create" for a file the
+// deploy would generate, or "existing" for one it would use as-is.
+type deployPlanArtifact struct {
+	Path    string
+	Action  string
+	Content []byte
+}
+
+// deployPlanResource is one resource the deploy would touch. Action is
+// "create", "update", "build", "apply" (create or update; live state not
+// checked) or "unknown".
+type deployPlanResource struct {
+	Kind      string
+	Name      string
+	Namespace string
+	Action    string
+	Detail    string
+}
+
+// printDeployPlan writes the rendered artifacts followed by a resource
+// summary.
+func printDeployPlan(w io.Writer, p *deployPlan) {
+	fmt.Fprintf(w, "Deploy plan (%s) — nothing will be applied\n", p.Target)
+	for _, a := range p.Artifacts {
+		fmt.Fprintf(w, "\n# ---- %s (%s) ----\n", a.Path, a.Action)
+		w.Write(a.Content) //nolint:errcheck // best-effort terminal output
+		if len(a.Content) > 0 && a.Content[len(a.Content)-1] != '\n' {
+			fmt.Fprintln(w)
+		}
+	}
+
+	counts := map[string]int{}
+	fmt.Fprintf(w, "\nResources (%d):\n", len(p.Resources))
+	for _, r := range p.Resources {
+		counts[r.Action]++
+		name := r.Name
+		if r.Namespace != "" {
+			name = r.Namespace + "/" + r.Name
+		}
+		line := fmt.Sprintf("  %s %-7s %s %s", planActionSymbol(r.Action), strings.ToUpper(r.Action), r.Kind, name)
+		if r.Detail != "" {
+			line += "  (" + r.Detail + ")"
+		}
+		fmt.Fprintln(w, line)
+	}
+	if len(counts) > 0 {
+		actions := make([]string, 0, len(counts))
+		for a := range counts {
+			actions = append(actions, a)
+		}
+		sort.Strings(actions)
+		parts := make([]string, 0, len(actions))
+		for _, a := range actions {
+			parts = append(parts, fmt.Sprintf("%d to %s", counts[a], a))
+		}
+		fmt.Fprintf(w, "\nPlan: %s.\n", strings.Join(parts, ", "))
+	}
+	for _, n := range p.Notes {
+		fmt.Fprintf(w, "Note: %s\n", n)
+	}
+}
+
+func planActionSymbol(action string) string {
+	switch action {
+	case "create", "build":
+		return "+"
+	case "update", "apply":
+		return "~"
+	default:
+		return "?"
+	}
+}
+
+// planDocker renders the Dockerfile and docker-compose.yml that
+// runDeployDocker would use: existing files as they are, missing ones as
+// they would be generated.
+func planDocker(dir, configFile, image string, noCompose bool) (*deployPlan, error) {
+	plan := &deployPlan{Target: "docker"}
+
+	dockerfile, err := planArtifact(dir, "Dockerfile", func() []byte { return []byte(renderDockerfile()) })
+	if err != nil {
+		return nil, err
+	}
+	compose, err := planArtifact(dir, "docker-compose.yml", func() []byte { return []byte(renderDockerCompose(configFile, image)) })
+	if err != nil {
+		return nil, err
+	}
+	plan.Artifacts = []deployPlanArtifact{dockerfile, compose}
+	plan.Resources = append(plan.Resources, deployPlanResource{Kind: "Image", Name: image, Action: "build", Detail: "docker build"})
+	if noCompose {
+		return plan, nil
+	}
+
+	var doc struct {
+		Services map[string]any `yaml:"services"`
+	}
+	if err := yaml.Unmarshal(compose.Content, &doc); err != nil {
+		return nil, fmt.Errorf("parse docker-compose.yml: %w", err)
+	}
+	services := make([]string, 0, len(doc.Services))
+	for name := range doc.Services {
+		services = append(services, name)
+	}
+	sort.Strings(services)
+	for _, name := range services {
+		plan.Resources = append(plan.Resources, deployPlanResource{Kind: "ComposeService", Name: name, Action: "apply", Detail: "docker compose up -d"})
+	}
+	plan.Notes = append(plan.Notes, "docker compose creates missing containers and recreates changed ones")
+	return plan, nil
+}
+
+// planArtifact reads dir/name if it exists, otherwise renders it.
+func planArtifact(dir, name string, render func() []byte) (deployPlanArtifact, error) {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	switch {
+	case err == nil:
+		return deployPlanArtifact{Path: name, Action: "existing", Content: data}, nil
+	case os.IsNotExist(err):
+		return deployPlanArtifact{Path: name, Action: "create", Content: render()}, nil
+	default:
+		return deployPlanArtifact{}, fmt.Errorf("read %s: %w", name, err)
+	}
+}
+
+// planK8s renders the manifests runK8sApply would apply and asks the
+// cluster which of them already exist. When the cluster cannot be reached
+// the resources are listed without live state.
+func planK8s(ctx context.Context, target *k8s.K8sDeployTarget, req *deploy.DeployRequest) (*deployPlan, error) {
+	artifacts, err := target.Generate(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("generate: %w", err)
+	}
+	ms := &k8s.ManifestSet{}
+	for _, obj := range artifacts.Objects {
+		if u, ok := obj.(*unstructured.Unstructured); ok {
+			ms.Add(u)
+		}
+	}
+	data, err := ms.Render()
+	if err != nil {
+		return nil, fmt.Errorf("render manifests: %w", err)
+	}
+	plan := &deployPlan{
+		Target:    "kubernetes",
+		Artifacts: []deployPlanArtifact{{Path: "manifests.yaml", Action: "create", Content: data}},
+	}
+
+	liveCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	live, err := target.Plan(liveCtx, artifacts)
+	if err != nil {
+		for _, u := range ms.Objects {
+			plan.Resources = append(plan.Resources, deployPlanResource{Kind: u.GetKind(), Name: u.GetName(), Namespace: u.GetNamespace(), Action: "apply"})
+		}
+		plan.Notes = append(plan.Notes, fmt.Sprintf("live cluster state not checked: %v", err))
+		return plan, nil
+	}
+	for _, rs := range live {
+		plan.Resources = append(plan.Resources, deployPlanResource{Kind: rs.Kind, Name: rs.Name, Namespace: rs.Namespace, Action: rs.Status, Detail: rs.Message})
+	}
+	return plan, nil
+}
+
+// planHelm renders the chart with `helm template`, which needs no cluster,
+// and lists the rendered resources.
+func planHelm(release, chart string, helmArgs []string) (*deployPlan, error) {
+	args := append([]string{"template", release, chart}, helmArgs...)
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("helm", args...) //nolint:gosec // G204: helm args are constructed from validated inputs
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("helm template failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	plan := &deployPlan{
+		Target:    "helm",
+		Artifacts: []deployPlanArtifact{{Path: "helm template " + release, Action: "create", Content: stdout.Bytes()}},
+		Notes:     []string{"helm upgrade --install creates missing resources and updates existing ones"},
+	}
+	resources, err := manifestResources(stdout.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range resources {
+		r.Action = "apply"
+		plan.Resources = append(plan.Resources, r)
+	}
+	return plan, nil
+}
+
+// manifestResources lists the kind, name and namespace of each document in
+// a multi-document Kubernetes YAML stream.
+func manifestResources(data []byte) ([]deployPlanResource, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	var out []deployPlanResource
+	for {
+		var doc struct {
+			Kind     string `yaml:"kind"`
+			Metadata struct {
+				Name      string `yaml:"name"`
+				Namespace string `yaml:"namespace"`
+			} `yaml:"metadata"`
+		}
+		err := dec.Decode(&doc)
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse rendered manifests: %w", err)
+		}
+		if doc.Kind == "" {
+			continue
+		}
+		out = append(out, deployPlanResource{Kind: doc.Kind, Name: doc.Metadata.Name, Namespace: doc.Metadata.Namespace})
+	}
+}
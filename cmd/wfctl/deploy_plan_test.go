@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/GoCodeAlone/workflow/config"
+	"github.com/GoCodeAlone/workflow/manifest"
+	k8s "github.com/GoCodeAlone/workflow/pkg/k8s"
+)
+
+func TestMoveLeadingPlanFlag(t *testing.T) {
+	for _, tc := range []struct{ in, want []string }{
+		{[]string{"--plan", "docker", "-config", "a.yaml"}, []string{"docker", "--plan", "-config", "a.yaml"}},
+		{[]string{"-plan", "k8s", "apply", "-image", "x:1"}, []string{"k8s", "apply", "--plan", "-image", "x:1"}},
+		{[]string{"docker", "--plan"}, []string{"docker", "--plan"}},
+		{[]string{"--plan"}, []string{"--plan"}},
+	} {
+		if got := moveLeadingPlanFlag(tc.in); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("moveLeadingPlanFlag(%v) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestPlanDocker(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM scratch\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := planDocker(dir, "workflow.yaml", "my-app:v1", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Artifacts[0].Action != "existing" || string(p.Artifacts[0].Content) != "FROM scratch\n" {
+		t.Errorf("Dockerfile artifact = %+v", p.Artifacts[0])
+	}
+	if p.Artifacts[1].Action != "create" || !strings.Contains(string(p.Artifacts[1].Content), "my-app:v1") {
+		t.Errorf("compose artifact = %+v", p.Artifacts[1])
+	}
+	if len(p.Resources) != 2 || p.Resources[0].Action != "build" || p.Resources[1].Name != "app" {
+		t.Errorf("resources = %+v", p.Resources)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "docker-compose.yml")); !os.IsNotExist(err) {
+		t.Error("plan must not write docker-compose.yml")
+	}
+
+	var out bytes.Buffer
+	printDeployPlan(&out, p)
+	for _, want := range []string{"# ---- docker-compose.yml (create) ----", "+ BUILD   Image my-app:v1", "Plan: 1 to apply, 1 to build."} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("plan output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestPlanK8sWithoutCluster(t *testing.T) {
+	t.Setenv("KUBECONFIG", filepath.Join(t.TempDir(), "missing-kubeconfig"))
+	cfg := &config.WorkflowConfig{Modules: []config.ModuleConfig{{Name: "server", Type: "http.server", Config: map[string]any{"address": ":8080"}}}}
+	f := k8sCommonFlags{image: "my-app:v1", namespace: "prod", appName: "my-app", replicas: 1}
+	req := f.toDeployRequest(cfg, manifest.Analyze(cfg))
+
+	p, err := planK8s(context.Background(), k8s.NewDeployTarget(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(p.Artifacts[0].Content), `"kind": "Deployment"`) {
+		t.Errorf("rendered manifests missing the Deployment:\n%s", p.Artifacts[0].Content)
+	}
+	kinds := map[string]bool{}
+	for _, r := range p.Resources {
+		if r.Action != "apply" {
+			t.Errorf("%s/%s: action %q, want apply without a cluster", r.Kind, r.Name, r.Action)
+		}
+		kinds[r.Kind] = true
+	}
+	if !kinds["Deployment"] || !kinds["Service"] || len(p.Notes) != 1 {
+		t.Errorf("resources = %+v, notes = %v", p.Resources, p.Notes)
+	}
+}
+
+func TestManifestResources(t *testing.T) {
+	data := []byte(`---
+# Source: workflow/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: wf
+  namespace: prod
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: wf
+`)
+	got, err := manifestResources(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []deployPlanResource{{Kind: "Deployment", Name: "wf", Namespace: "prod"}, {Kind: "Service", Name: "wf"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("manifestResources = %+v, want %+v", got, want)
+	}
+}
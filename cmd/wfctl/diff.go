@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"sort"
 	"strings"
@@ -302,13 +303,18 @@ func statusSymbol(s DiffStatus) string {
 
 // printDiffText writes a human-readable diff report to stdout.
 func printDiffText(result DiffResult) {
-	fmt.Println("Modules:")
+	writeDiffText(os.Stdout, result)
+}
+
+// writeDiffText writes a human-readable diff report to w.
+func writeDiffText(w io.Writer, result DiffResult) {
+	fmt.Fprintln(w, "Modules:")
 	for _, m := range result.Modules {
 		statefulTag := ""
 		if m.Stateful && m.Status != DiffStatusUnchanged {
 			statefulTag = " [STATEFUL]"
 		}
-		fmt.Printf("  %s %-28s  (%-30s)  [%s]%s\n",
+		fmt.Fprintf(w, "  %s %-28s  (%-30s)  [%s]%s\n",
 			statusSymbol(m.Status),
 			m.Name,
 			moduleTypeLabel(m.Type),
@@ -316,14 +322,14 @@ func printDiffText(result DiffResult) {
 			statefulTag,
 		)
 		if m.ResourceID != "" && m.Status != DiffStatusAdded {
-			fmt.Printf("    resource: %s\n", m.ResourceID)
+			fmt.Fprintf(w, "    resource: %s\n", m.ResourceID)
 		}
 	}
 
 	if len(result.Pipelines) > 0 {
-		fmt.Println("\nPipelines:")
+		fmt.Fprintln(w, "\nPipelines:")
 		for _, p := range result.Pipelines {
-			fmt.Printf("  %s %-28s  %-36s  [%s]\n",
+			fmt.Fprintf(w, "  %s %-28s  %-36s  [%s]\n",
 				statusSymbol(p.Status),
 				p.Name,
 				fmt.Sprintf("(%s)", p.Trigger),
@@ -333,14 +339,14 @@ func printDiffText(result DiffResult) {
 	}
 
 	if len(result.BreakingChanges) > 0 {
-		fmt.Println("\n[BREAKING CHANGES]")
+		fmt.Fprintln(w, "\n[BREAKING CHANGES]")
 		for _, bc := range result.BreakingChanges {
-			fmt.Printf("  Module %q:\n", bc.ModuleName)
+			fmt.Fprintf(w, "  Module %q:\n", bc.ModuleName)
 			for _, ch := range bc.Changes {
-				fmt.Printf("    - %s\n", ch.Message)
+				fmt.Fprintf(w, "    - %s\n", ch.Message)
 				if ch.Field != "" && ch.Field != "type" {
-					fmt.Printf("      This is a STATEFUL module. Data at the old location may be lost.\n")
-					fmt.Printf("      Recommendation: add a migration step or keep the old value.\n")
+					fmt.Fprintf(w, "      This is a STATEFUL module. Data at the old location may be lost.\n")
+					fmt.Fprintf(w, "      Recommendation: add a migration step or keep the old value.\n")
 				}
 			}
 		}
@@ -23,6 +23,12 @@ func runEnvWithOutput(args []string, out io.Writer) error {
 		return flag.ErrHelp
 	case "setup":
 		return runEnvSetup(args[1:], out)
+	case "export":
+		return runEnvExport(args[1:], out)
+	case "diff":
+		return runEnvDiff(args[1:], out)
+	case "promote":
+		return runEnvPromote(args[1:], out)
 	default:
 		printEnvUsage(out)
 		return fmt.Errorf("unknown env subcommand %q", args[0])
@@ -33,10 +39,14 @@ func printEnvUsage(out io.Writer) {
 	fmt.Fprintln(out, `Usage: wfctl env <subcommand> [options]
 
 Manage environment input setup across provider secrets, provider variables,
-and workflow config references.
+and workflow config references, and promote environment variables between
+environments on a running engine.
 
 Subcommands:
-  setup   Configure declared environment inputs for an application
+  setup     Configure declared environment inputs for an application
+  export    Export an environment's variables as a signed snapshot
+  diff      Show what a snapshot would change in an environment
+  promote   Apply a snapshot to an environment after confirmation
 
 Use "wfctl env <subcommand> -h" for subcommand options.`)
 }
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/GoCodeAlone/workflow/cmd/wfctl/internal/prompt"
+)
+
+// envSecretPrompt and envConfirmPrompt ask for missing secret values and for
+// confirmation before a promotion is applied. Tests replace them.
+var (
+	envSecretPrompt = func(key string) (string, error) {
+		return prompt.Input(fmt.Sprintf("Value for secret %s (empty to reference the secrets provider)", key), true)
+	}
+	envConfirmPrompt = func(question string) (bool, error) {
+		return prompt.Confirm(question, false)
+	}
+)
+
+const envAPIPath = "/api/v1/admin/environments"
+
+// envChange and envSnapshotDiff mirror the engine's snapshot diff JSON.
+type envChange struct {
+	Key  string `json:"key"`
+	Op   string `json:"op"`
+	From any    `json:"from,omitempty"`
+	To   any    `json:"to,omitempty"`
+}
+
+type envSnapshotDiff struct {
+	Source    string      `json:"source"`
+	Target    string      `json:"target"`
+	Variables []envChange `json:"variables"`
+	Secrets   []envChange `json:"secrets"`
+}
+
+type envPromoteResult struct {
+	Applied        bool             `json:"applied"`
+	Diff           *envSnapshotDiff `json:"diff"`
+	MissingSecrets []string         `json:"missing_secrets"`
+	ChangedKeys    []string         `json:"changed_keys"`
+}
+
+// resolveEnvID maps an environment name or ID to its ID.
+func resolveEnvID(server, token, nameOrID string) (string, error) {
+	var envs []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	if _, err := configAPIRequest(server, token, http.MethodGet, envAPIPath, "", nil, &envs); err != nil {
+		return "", fmt.Errorf("list environments: %w", err)
+	}
+	for _, e := range envs {
+		if e.ID == nameOrID || e.Name == nameOrID {
+			return e.ID, nil
+		}
+	}
+	return "", fmt.Errorf("environment %q not found", nameOrID)
+}
+
+// runEnvExport writes a signed snapshot of an environment's variables.
+func runEnvExport(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("env export", flag.ContinueOnError)
+	fs.SetOutput(out)
+	server, token := configServerFlags(fs)
+	output := fs.String("output", "", "Write the snapshot to this file instead of stdout")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), `Usage: wfctl env export [options] <environment>
+
+Export an environment's variables as a signed JSON snapshot. Secret values
+are never exported; the snapshot lists secret keys only.
+
+Options:
+`)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("environment name or id is required")
+	}
+	id, err := resolveEnvID(*server, *token, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	var snap json.RawMessage
+	if _, err := configAPIRequest(*server, *token, http.MethodGet, envAPIPath+"/"+url.PathEscape(id)+"/export", "", nil, &snap); err != nil {
+		return fmt.Errorf("export environment: %w", err)
+	}
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, snap, "", "  "); err != nil {
+		return fmt.Errorf("format snapshot: %w", err)
+	}
+	pretty.WriteByte('\n')
+	if *output == "" {
+		_, err := out.Write(pretty.Bytes())
+		return err
+	}
+	if err := os.WriteFile(*output, pretty.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+	fmt.Fprintf(out, "Wrote snapshot of %s to %s\n", fs.Arg(0), *output)
+	return nil
+}
+
+// runEnvDiff shows what applying a snapshot would change in an environment.
+func runEnvDiff(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("env diff", flag.ContinueOnError)
+	fs.SetOutput(out)
+	server, token := configServerFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), `Usage: wfctl env diff [options] <snapshot.json> <environment>
+
+Show the variables and secret keys that applying a snapshot would add,
+remove or change in an environment. Secret values are never shown.
+
+Options:
+`)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return fmt.Errorf("snapshot file and environment are required")
+	}
+	snap, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("read snapshot: %w", err)
+	}
+	id, err := resolveEnvID(*server, *token, fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	var diff envSnapshotDiff
+	if _, err := configAPIRequest(*server, *token, http.MethodPost, envAPIPath+"/"+url.PathEscape(id)+"/diff", "application/json", bytes.NewReader(snap), &diff); err != nil {
+		return fmt.Errorf("diff snapshot: %w", err)
+	}
+	printEnvDiff(out, &diff)
+	return nil
+}
+
+// runEnvPromote applies a snapshot to an environment after showing the diff
+// and asking for confirmation. Values for secrets the target is missing are
+// taken from --secret, prompted for, or referenced from the secrets provider.
+func runEnvPromote(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("env promote", flag.ContinueOnError)
+	fs.SetOutput(out)
+	server, token := configServerFlags(fs)
+	var secretFlags stringSliceFlag
+	fs.Var(&secretFlags, "secret", "KEY=VALUE for a secret missing in the target (repeatable; a value may be a secret:// reference)")
+	yes := fs.Bool("yes", false, "Apply without asking for confirmation")
+	prune := fs.Bool("prune", false, "Remove variables and secrets the snapshot does not contain")
+	reference := fs.Bool("reference-missing-secrets", false, "Store secret://<KEY> references for missing secrets instead of prompting")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), `Usage: wfctl env promote [options] <snapshot.json> <environment>
+
+Apply a snapshot exported with 'wfctl env export' to an environment. The
+changes are shown first and applied after confirmation; the engine records
+every changed key in its audit log.
+
+Snapshots carry no secret values. For each secret the target is missing,
+supply a value with --secret, enter it when prompted, or leave it empty to
+store a secret:// reference resolved by the secrets provider.
+
+Options:
+`)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return fmt.Errorf("snapshot file and environment are required")
+	}
+	snap, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("read snapshot: %w", err)
+	}
+	if !json.Valid(snap) {
+		return fmt.Errorf("snapshot %s is not valid JSON", fs.Arg(0))
+	}
+	secrets := map[string]string{}
+	for _, kv := range secretFlags {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok || k == "" {
+			return fmt.Errorf("invalid --secret %q: want KEY=VALUE", kv)
+		}
+		secrets[k] = v
+	}
+	id, err := resolveEnvID(*server, *token, fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	path := envAPIPath + "/" + url.PathEscape(id) + "/promote"
+	promote := func(confirm bool) (envPromoteResult, error) {
+		body, err := json.Marshal(map[string]any{
+			"snapshot":                  json.RawMessage(snap),
+			"confirm":                   confirm,
+			"prune":                     *prune,
+			"secrets":                   secrets,
+			"reference_missing_secrets": *reference,
+		})
+		if err != nil {
+			return envPromoteResult{}, err
+		}
+		var result envPromoteResult
+		_, err = configAPIRequest(*server, *token, http.MethodPost, path, "application/json", bytes.NewReader(body), &result)
+		return result, err
+	}
+
+	preview, err := promote(false)
+	if err != nil {
+		return fmt.Errorf("preview promotion: %w", err)
+	}
+	printEnvDiff(out, preview.Diff)
+	for _, key := range preview.MissingSecrets {
+		value, err := envSecretPrompt(key)
+		if errors.Is(err, prompt.ErrNotInteractive) {
+			return fmt.Errorf("secret %s is missing in %s: pass --secret %s=VALUE or --reference-missing-secrets", key, fs.Arg(1), key)
+		}
+		if err != nil {
+			return err
+		}
+		if value == "" {
+			value = "secret://" + key
+		}
+		secrets[key] = value
+	}
+
+	if !*yes {
+		ok, err := envConfirmPrompt(fmt.Sprintf("Apply these changes to %s?", fs.Arg(1)))
+		if errors.Is(err, prompt.ErrNotInteractive) {
+			return fmt.Errorf("confirmation required: re-run with --yes to apply without a terminal")
+		}
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Fprintln(out, "Promotion cancelled.")
+			return nil
+		}
+	}
+	result, err := promote(true)
+	if err != nil {
+		return fmt.Errorf("promote snapshot: %w", err)
+	}
+	if len(result.ChangedKeys) == 0 {
+		fmt.Fprintf(out, "%s already matches the snapshot; nothing changed.\n", fs.Arg(1))
+		return nil
+	}
+	fmt.Fprintf(out, "Promoted to %s; changed %s.\n", fs.Arg(1), strings.Join(result.ChangedKeys, ", "))
+	return nil
+}
+
+func printEnvDiff(w io.Writer, d *envSnapshotDiff) {
+	if d == nil {
+		return
+	}
+	fmt.Fprintf(w, "Changes from %s to %s:\n", d.Source, d.Target)
+	if len(d.Variables) == 0 && len(d.Secrets) == 0 {
+		fmt.Fprintln(w, "  (none)")
+		return
+	}
+	for _, c := range d.Variables {
+		switch c.Op {
+		case "added":
+			fmt.Fprintf(w, "  + %s = %v\n", c.Key, c.To)
+		case "removed":
+			fmt.Fprintf(w, "  - %s = %v\n", c.Key, c.From)
+		default:
+			fmt.Fprintf(w, "  ~ %s: %v -> %v\n", c.Key, c.From, c.To)
+		}
+	}
+	for _, c := range d.Secrets {
+		sign := "+"
+		if c.Op == "removed" {
+			sign = "-"
+		}
+		fmt.Fprintf(w, "  %s secret %s\n", sign, c.Key)
+	}
+}
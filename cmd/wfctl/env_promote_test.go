@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newEnvPromoteServer fakes the engine's environment endpoints for a
+// "production" environment missing the API_TOKEN secret. Promote request
+// bodies are appended to *requests.
+func newEnvPromoteServer(t *testing.T, requests *[]map[string]any) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/admin/environments":
+			_, _ = w.Write([]byte(`[{"id":"env-1","name":"staging"},{"id":"env-2","name":"production"}]`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/admin/environments/env-1/export":
+			_, _ = w.Write([]byte(`{"environment":"staging","variables":{"replicas":2},"secrets":["API_TOKEN"],"signature":"abc"}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/admin/environments/env-2/promote":
+			var body map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			*requests = append(*requests, body)
+			result := map[string]any{
+				"applied":         false,
+				"missing_secrets": []string{"API_TOKEN"},
+				"diff": map[string]any{"source": "staging", "target": "production",
+					"variables": []map[string]any{{"key": "replicas", "op": "changed", "from": 5, "to": 2}},
+					"secrets":   []map[string]any{{"key": "API_TOKEN", "op": "added"}}},
+			}
+			if body["confirm"] == true {
+				result["applied"] = true
+				result["missing_secrets"] = []string{}
+				result["changed_keys"] = []string{"replicas", "secrets.API_TOKEN"}
+			}
+			_ = json.NewEncoder(w).Encode(result)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"not found"}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRunEnvExport(t *testing.T) {
+	srv := newEnvPromoteServer(t, nil)
+	var out bytes.Buffer
+	if err := runEnvWithOutput([]string{"export", "--server", srv.URL, "staging"}, &out); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	var snap map[string]any
+	if err := json.Unmarshal(out.Bytes(), &snap); err != nil || snap["signature"] != "abc" {
+		t.Fatalf("output = %s (%v), want the signed snapshot", out.String(), err)
+	}
+	if err := runEnvWithOutput([]string{"export", "--server", srv.URL, "qa"}, &out); err == nil || !strings.Contains(err.Error(), `environment "qa" not found`) {
+		t.Errorf("unknown environment: err = %v", err)
+	}
+}
+
+func TestRunEnvPromote(t *testing.T) {
+	snapPath := filepath.Join(t.TempDir(), "staging.json")
+	if err := os.WriteFile(snapPath, []byte(`{"environment":"staging","signature":"abc"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var prompted []string
+	origSecret, origConfirm := envSecretPrompt, envConfirmPrompt
+	t.Cleanup(func() { envSecretPrompt, envConfirmPrompt = origSecret, origConfirm })
+	envSecretPrompt = func(key string) (string, error) {
+		prompted = append(prompted, key)
+		return "tok-123", nil
+	}
+	confirm := false
+	envConfirmPrompt = func(string) (bool, error) { return confirm, nil }
+
+	var requests []map[string]any
+	srv := newEnvPromoteServer(t, &requests)
+	var out bytes.Buffer
+	if err := runEnvWithOutput([]string{"promote", "--server", srv.URL, snapPath, "production"}, &out); err != nil {
+		t.Fatalf("promote: %v", err)
+	}
+	if !strings.Contains(out.String(), "~ replicas: 5 -> 2") || !strings.Contains(out.String(), "Promotion cancelled.") {
+		t.Errorf("declined promotion output:\n%s", out.String())
+	}
+	if len(requests) != 1 {
+		t.Fatalf("declined promotion sent %d requests, want only the preview", len(requests))
+	}
+
+	confirm = true
+	requests, prompted = nil, nil
+	out.Reset()
+	if err := runEnvWithOutput([]string{"promote", "--server", srv.URL, snapPath, "production"}, &out); err != nil {
+		t.Fatalf("promote: %v", err)
+	}
+	if len(prompted) != 1 || prompted[0] != "API_TOKEN" {
+		t.Errorf("prompted for %v, want API_TOKEN", prompted)
+	}
+	if len(requests) != 2 || requests[1]["confirm"] != true {
+		t.Fatalf("requests = %v, want a preview then a confirmed promote", requests)
+	}
+	if secrets, _ := requests[1]["secrets"].(map[string]any); secrets["API_TOKEN"] != "tok-123" {
+		t.Errorf("confirmed request secrets = %v", requests[1]["secrets"])
+	}
+	if snap, _ := requests[1]["snapshot"].(map[string]any); snap["signature"] != "abc" {
+		t.Errorf("snapshot not forwarded as-is: %v", requests[1]["snapshot"])
+	}
+	if !strings.Contains(out.String(), "changed replicas, secrets.API_TOKEN") {
+		t.Errorf("output:\n%s", out.String())
+	}
+}
@@ -0,0 +1,576 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow"
+	"github.com/GoCodeAlone/workflow/config"
+	"github.com/GoCodeAlone/workflow/module"
+)
+
+// Kinds of fuzz findings.
+const (
+	fuzzFindingPanic       = "panic"
+	fuzzFindingServerError = "server_error"
+	fuzzFindingSlow        = "slow"
+)
+
+// fuzzOptions holds the settings of a fuzz run.
+type fuzzOptions struct {
+	configPath    string
+	duration      time.Duration
+	maxRequests   int
+	seed          int64
+	routes        []string
+	latencyBudget time.Duration
+	timeout       time.Duration
+	outDir        string
+	pluginDir     string
+	mockSteps     []string
+	live          bool
+}
+
+// fuzzFinding is one problem found by a fuzz run. Findings of the same kind
+// on the same route that fail the same step, or have the same detail, are
+// reported once with a count; the detail is that of the first.
+type fuzzFinding struct {
+	Kind       string  `json:"kind"`
+	Route      string  `json:"route"`
+	Status     int     `json:"status"`
+	Detail     string  `json:"detail,omitempty"`
+	LatencyMS  float64 `json:"latencyMs"`
+	Iteration  int     `json:"iteration"`
+	Count      int     `json:"count"`
+	Reproducer string  `json:"reproducer,omitempty"`
+}
+
+// fuzzReport is the JSON report of a fuzz run.
+type fuzzReport struct {
+	Config   string         `json:"config"`
+	Seed     int64          `json:"seed"`
+	Duration string         `json:"duration"`
+	Requests int            `json:"requests"`
+	Routes   []string       `json:"routes"`
+	Findings []*fuzzFinding `json:"findings"`
+}
+
+// fuzzReproducer is the content of a reproducer file: the request that
+// triggered a finding and how to regenerate it.
+type fuzzReproducer struct {
+	Config    string      `json:"config"`
+	Seed      int64       `json:"seed"`
+	Iteration int         `json:"iteration"`
+	Kind      string      `json:"kind"`
+	Detail    string      `json:"detail,omitempty"`
+	Request   fuzzRequest `json:"request"`
+}
+
+// fuzzOutcome is what happened to one request.
+type fuzzOutcome struct {
+	status  int
+	latency time.Duration
+	kind    string
+	detail  string
+	// group identifies the failure for deduplication: the failing step, or
+	// the detail with varying numbers replaced.
+	group string
+}
+
+func runFuzz(args []string) error {
+	fs := flag.NewFlagSet("fuzz", flag.ContinueOnError)
+	var opts fuzzOptions
+	fs.StringVar(&opts.configPath, "config", "", "Workflow config file (or pass it as the first argument)")
+	fs.DurationVar(&opts.duration, "duration", 30*time.Second, "How long to send requests")
+	fs.IntVar(&opts.maxRequests, "requests", 0, "Stop after this many requests (0 = run for -duration)")
+	fs.Int64Var(&opts.seed, "seed", 0, "Random seed; the same seed sends the same requests (0 = pick one and print it)")
+	var routes multiStringFlag
+	fs.Var(&routes, "routes", "Only fuzz routes matching these patterns, e.g. '/api/orders/*' or 'POST /api/*' (repeatable or comma-separated)")
+	fs.DurationVar(&opts.latencyBudget, "latency-budget", 2*time.Second, "Report requests slower than this (0 disables)")
+	fs.DurationVar(&opts.timeout, "timeout", 30*time.Second, "Cancel a request's pipeline after this long")
+	fs.StringVar(&opts.outDir, "out", "fuzz-findings", "Directory for reproducer files")
+	reportPath := fs.String("report", "", "Write the JSON report to this file")
+	format := fs.String("format", "text", "Output format: text or json")
+	fs.StringVar(&opts.pluginDir, "plugin-dir", "", "External plugins directory")
+	var mockSteps multiStringFlag
+	fs.Var(&mockSteps, "mock-step", "Step type to replace with a no-op mock, e.g. step.http_call (repeatable or comma-separated)")
+	fs.BoolVar(&opts.live, "live", false, "Execute destructive steps instead of running pipelines in dry-run mode")
+	replay := fs.String("replay", "", "Re-send the request in a reproducer file and report what happens")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), `Usage: wfctl fuzz [options] [config.yaml]
+
+Build the engine in-process and send generated requests to every HTTP route
+of a workflow config, looking for pipelines that panic, fail with an
+unhandled 500, or exceed the latency budget.
+
+Requests follow the route schemas that 'wfctl api extract' infers, with some
+values deliberately of the wrong shape; routes without a schema get random
+JSON. Each finding's request is saved to a reproducer file in -out, which
+-replay re-sends. Pipelines run in dry-run mode, so steps marked
+destructive: true report what they would do instead of doing it.
+
+Exit code is 0 when nothing is found and 1 when there are findings or the
+run fails.
+
+Examples:
+  wfctl fuzz --config workflow.yaml --duration 2m
+  wfctl fuzz --config workflow.yaml --routes 'POST /api/orders*' --seed 42
+  wfctl fuzz --config workflow.yaml --requests 500 --format json --report fuzz.json
+  wfctl fuzz --config workflow.yaml --replay fuzz-findings/panic-POST-api-orders-17.json
+
+Options:
+`)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	opts.routes = routes
+	opts.mockSteps = mockSteps
+	if opts.configPath == "" && fs.NArg() > 0 {
+		opts.configPath = fs.Arg(0)
+	}
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("unsupported format %q: use text or json", *format)
+	}
+
+	if *replay != "" {
+		return runFuzzReplay(*replay, opts)
+	}
+	if opts.configPath == "" {
+		fs.Usage()
+		return fmt.Errorf("a config file is required")
+	}
+	if opts.seed == 0 {
+		opts.seed = time.Now().UnixNano()
+	}
+
+	report, err := fuzzConfig(opts)
+	if err != nil {
+		return err
+	}
+	if *reportPath != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encode report: %w", err)
+		}
+		if err := os.WriteFile(*reportPath, append(data, '\n'), 0o600); err != nil {
+			return fmt.Errorf("write report: %w", err)
+		}
+	}
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return fmt.Errorf("encode report: %w", err)
+		}
+	} else {
+		printFuzzReport(report)
+	}
+	if len(report.Findings) > 0 {
+		return fmt.Errorf("%d finding(s); rerun with -seed %d to reproduce", len(report.Findings), report.Seed)
+	}
+	return nil
+}
+
+// fuzzConfig sends generated requests to the routes of the config until the
+// duration or request limit is reached, and reports what it found.
+func fuzzConfig(opts fuzzOptions) (*fuzzReport, error) {
+	cfg, err := config.LoadFromFile(opts.configPath)
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	spec := buildConfigOpenAPISpec(cfg, module.OpenAPIGeneratorConfig{Title: "fuzz", Version: "0"}, true)
+	routes := collectFuzzRoutes(spec, opts.routes)
+	if len(routes) == 0 {
+		if len(opts.routes) > 0 {
+			return nil, fmt.Errorf("no HTTP routes match -routes %s", strings.Join(opts.routes, ","))
+		}
+		return nil, fmt.Errorf("config defines no HTTP routes")
+	}
+
+	target, err := startFuzzEngine(cfg, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer target.stop()
+
+	report := &fuzzReport{Config: opts.configPath, Seed: opts.seed, Findings: []*fuzzFinding{}}
+	for _, r := range routes {
+		report.Routes = append(report.Routes, r.String())
+	}
+	seen := map[string]*fuzzFinding{}
+	master := rand.New(rand.NewSource(opts.seed)) //nolint:gosec // G404: fuzz inputs must be reproducible, not secure
+	start := time.Now()
+	for i := 1; ; i++ {
+		if opts.maxRequests > 0 && i > opts.maxRequests {
+			break
+		}
+		if opts.maxRequests == 0 && time.Since(start) >= opts.duration {
+			break
+		}
+		reqSeed := master.Int63()
+		route := routes[master.Intn(len(routes))]
+		req := newFuzzGenerator(reqSeed, spec).request(route)
+		if !opts.live {
+			req.Headers[module.DryRunHeader] = "true"
+		}
+		out := target.send(req, opts)
+		report.Requests++
+		if out.kind == "" {
+			continue
+		}
+
+		key := out.kind + "\x00" + req.Route + "\x00" + out.group
+		if f := seen[key]; f != nil {
+			f.Count++
+			continue
+		}
+		f := &fuzzFinding{
+			Kind:      out.kind,
+			Route:     req.Route,
+			Status:    out.status,
+			Detail:    out.detail,
+			LatencyMS: float64(out.latency.Microseconds()) / 1000,
+			Iteration: i,
+			Count:     1,
+		}
+		f.Reproducer, err = writeFuzzReproducer(opts.outDir, fuzzReproducer{
+			Config:    opts.configPath,
+			Seed:      opts.seed,
+			Iteration: i,
+			Kind:      out.kind,
+			Detail:    out.detail,
+			Request:   req,
+		})
+		if err != nil {
+			return nil, err
+		}
+		seen[key] = f
+		report.Findings = append(report.Findings, f)
+	}
+	report.Duration = time.Since(start).Round(time.Millisecond).String()
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		return fuzzKindRank(report.Findings[i].Kind) < fuzzKindRank(report.Findings[j].Kind)
+	})
+	return report, nil
+}
+
+// runFuzzReplay re-sends the request saved in a reproducer file.
+func runFuzzReplay(path string, opts fuzzOptions) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read reproducer: %w", err)
+	}
+	var repro fuzzReproducer
+	if err := json.Unmarshal(data, &repro); err != nil {
+		return fmt.Errorf("parse reproducer %s: %w", path, err)
+	}
+	if opts.configPath == "" {
+		opts.configPath = repro.Config
+	}
+	if opts.configPath == "" {
+		return fmt.Errorf("a config file is required to replay %s", path)
+	}
+	cfg, err := config.LoadFromFile(opts.configPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	target, err := startFuzzEngine(cfg, opts)
+	if err != nil {
+		return err
+	}
+	defer target.stop()
+
+	out := target.send(repro.Request, opts)
+	fmt.Printf("%s %s -> %d in %s\n", repro.Request.Method, repro.Request.URL, out.status, out.latency.Round(time.Millisecond))
+	if out.kind == "" {
+		fmt.Println("no finding: the request no longer reproduces")
+		return nil
+	}
+	fmt.Printf("%s: %s\n", out.kind, out.detail)
+	return fmt.Errorf("reproduced %s", out.kind)
+}
+
+// fuzzTarget is a started engine that requests are sent to in-process.
+type fuzzTarget struct {
+	handler http.Handler
+	panics  *fuzzPanicCapture
+	stop    func()
+}
+
+// fuzzRouterHandler is an HTTP router that serves its routes.
+type fuzzRouterHandler interface {
+	http.Handler
+	module.HTTPRouter
+}
+
+// startFuzzEngine builds and starts an engine for cfg with every built-in
+// plugin, mocking opts.mockSteps, and returns its HTTP router. HTTP servers
+// listen on an ephemeral loopback port so a fuzz run never clashes with a
+// running instance of the app.
+func startFuzzEngine(cfg *config.WorkflowConfig, opts fuzzOptions) (*fuzzTarget, error) {
+	panics := &fuzzPanicCapture{}
+	prevLogger := slog.Default()
+	slog.SetDefault(slog.New(panics))
+	// Some modules print lifecycle messages to stdout; send them to stderr
+	// so stdout carries only the report.
+	prevStdout := os.Stdout
+	os.Stdout = os.Stderr
+	restore := func() {
+		slog.SetDefault(prevLogger)
+		os.Stdout = prevStdout
+	}
+
+	logger := &testDiscardLogger{}
+	app := modular.NewStdApplication(nil, logger)
+	eng := workflow.NewStdEngine(app, logger)
+	for _, p := range testBuiltinPlugins() {
+		if err := eng.LoadPlugin(p); err != nil {
+			restore()
+			return nil, fmt.Errorf("LoadPlugin(%s): %w", p.Name(), err)
+		}
+	}
+	shutdownPlugins, err := loadExternalPluginsForLocalEngine(eng, opts.pluginDir, prevLogger)
+	if err != nil {
+		restore()
+		return nil, err
+	}
+	if shutdownPlugins == nil {
+		shutdownPlugins = func() {}
+	}
+	for _, stepType := range opts.mockSteps {
+		eng.AddStepType(stepType, newTestMockStepFactory(map[string]any{}))
+	}
+
+	for i := range cfg.Modules {
+		if cfg.Modules[i].Type == "http.server" {
+			if cfg.Modules[i].Config == nil {
+				cfg.Modules[i].Config = map[string]any{}
+			}
+			cfg.Modules[i].Config["address"] = "127.0.0.1:0"
+			delete(cfg.Modules[i].Config, "port")
+		}
+	}
+
+	fail := func(err error) (*fuzzTarget, error) {
+		shutdownPlugins()
+		restore()
+		return nil, err
+	}
+	if err := eng.BuildFromConfig(cfg); err != nil {
+		return fail(fmt.Errorf("BuildFromConfig: %w", err))
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := eng.Start(ctx); err != nil {
+		cancel()
+		return fail(fmt.Errorf("start engine: %w", err))
+	}
+	stop := func() {
+		cancel()
+		_ = eng.Stop(context.Background())
+		shutdownPlugins()
+		restore()
+	}
+
+	registry := eng.App().SvcRegistry()
+	var handler http.Handler
+	if router, err := module.FindByInterface[fuzzRouterHandler](registry); err == nil {
+		handler = router.Service
+	} else if h, err := module.FindByInterface[http.Handler](registry); err == nil {
+		handler = h.Service
+	}
+	if handler == nil {
+		stop()
+		return nil, fmt.Errorf("no HTTP router found; the config needs an http.router module")
+	}
+	return &fuzzTarget{handler: handler, panics: panics, stop: stop}, nil
+}
+
+// send executes req against the router and classifies the outcome.
+func (t *fuzzTarget) send(req fuzzRequest, opts fuzzOptions) fuzzOutcome {
+	var body io.Reader
+	if req.Body != "" {
+		body = strings.NewReader(req.Body)
+	}
+	r := httptest.NewRequest(req.Method, req.URL, body)
+	for k, v := range req.Headers {
+		r.Header.Set(k, v)
+	}
+	ctx := r.Context()
+	if !opts.live {
+		ctx = module.WithDryRun(ctx)
+	}
+	if opts.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.timeout)
+		defer cancel()
+	}
+	r = r.WithContext(ctx)
+
+	t.panics.reset()
+	rec := httptest.NewRecorder()
+	start := time.Now()
+	t.handler.ServeHTTP(rec, r)
+	out := fuzzOutcome{status: rec.Code, latency: time.Since(start)}
+
+	switch {
+	case t.panics.value() != "":
+		out.kind, out.detail = fuzzFindingPanic, t.panics.value()
+		out.group = fuzzVariableRe.ReplaceAllString(out.detail, "N")
+	case rec.Code >= http.StatusInternalServerError:
+		if p, ok := unhandledErrorProblem(rec); ok {
+			out.kind, out.detail = fuzzFindingServerError, p.Detail
+			if out.detail == "" {
+				out.detail = p.Title
+			}
+			out.group = "step " + p.Step
+			if p.Step == "" {
+				out.group = fuzzVariableRe.ReplaceAllString(out.detail, "N")
+			}
+		}
+	}
+	if out.kind == "" && opts.latencyBudget > 0 && out.latency > opts.latencyBudget {
+		out.kind = fuzzFindingSlow
+		out.detail = fmt.Sprintf("took longer than the %s latency budget", opts.latencyBudget)
+		out.group = out.detail
+	}
+	return out
+}
+
+// fuzzVariableRe matches parts of a message that vary between requests,
+// such as numbers and IDs, so repeats of one failure deduplicate.
+var fuzzVariableRe = regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f-]{27}|\d+`)
+
+// unhandledErrorProblem reports whether a 5xx response is an error no step
+// handled: the engine's pipeline_error problem or a plain-text 500. A
+// response a step wrote on purpose, such as step.json_response with status
+// 500, is not a finding.
+func unhandledErrorProblem(rec *httptest.ResponseRecorder) (*module.Problem, bool) {
+	contentType := rec.Header().Get("Content-Type")
+	switch {
+	case strings.HasPrefix(contentType, module.ProblemContentType):
+		var p module.Problem
+		if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil || p.Code != string(module.ProblemPipelineError) {
+			return nil, false
+		}
+		return &p, true
+	case strings.HasPrefix(contentType, "text/plain"):
+		return &module.Problem{Detail: strings.TrimSpace(rec.Body.String())}, true
+	}
+	return nil, false
+}
+
+// fuzzPanicCapture is a slog handler that discards engine logs but keeps
+// the panic the HTTP router recovered, if any.
+type fuzzPanicCapture struct {
+	mu    sync.Mutex
+	panic string
+}
+
+func (c *fuzzPanicCapture) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= slog.LevelError
+}
+
+func (c *fuzzPanicCapture) Handle(_ context.Context, rec slog.Record) error {
+	if !strings.HasPrefix(rec.Message, "panic") {
+		return nil
+	}
+	rec.Attrs(func(a slog.Attr) bool {
+		if a.Key == "panic" {
+			c.mu.Lock()
+			c.panic = a.Value.String()
+			c.mu.Unlock()
+			return false
+		}
+		return true
+	})
+	return nil
+}
+
+func (c *fuzzPanicCapture) WithAttrs([]slog.Attr) slog.Handler { return c }
+func (c *fuzzPanicCapture) WithGroup(string) slog.Handler      { return c }
+
+func (c *fuzzPanicCapture) reset() {
+	c.mu.Lock()
+	c.panic = ""
+	c.mu.Unlock()
+}
+
+func (c *fuzzPanicCapture) value() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.panic
+}
+
+// fuzzFileNameRe matches runs of characters not kept in reproducer names.
+var fuzzFileNameRe = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// writeFuzzReproducer saves repro to dir and returns the file's path.
+func writeFuzzReproducer(dir string, repro fuzzReproducer) (string, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", fmt.Errorf("create reproducer directory: %w", err)
+	}
+	name := strings.Trim(fuzzFileNameRe.ReplaceAllString(repro.Request.Route, "-"), "-")
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s-%d.json", repro.Kind, name, repro.Iteration))
+	data, err := json.MarshalIndent(repro, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("encode reproducer: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return "", fmt.Errorf("write reproducer: %w", err)
+	}
+	return path, nil
+}
+
+func fuzzKindRank(kind string) int {
+	switch kind {
+	case fuzzFindingPanic:
+		return 0
+	case fuzzFindingServerError:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// printFuzzReport writes a human-readable summary of report to stdout.
+func printFuzzReport(report *fuzzReport) {
+	fmt.Printf("Fuzzed %d route(s) with %d request(s) in %s (seed %d)\n",
+		len(report.Routes), report.Requests, report.Duration, report.Seed)
+	if len(report.Findings) == 0 {
+		fmt.Println("No findings.")
+		return
+	}
+	fmt.Printf("\n%d finding(s):\n", len(report.Findings))
+	for _, f := range report.Findings {
+		fmt.Printf("  %-12s %-36s status %d  x%d\n", strings.ToUpper(f.Kind), f.Route, f.Status, f.Count)
+		if f.Detail != "" {
+			fmt.Printf("    %s\n", truncateFuzzDetail(f.Detail))
+		}
+		fmt.Printf("    reproduce: wfctl fuzz --config %s --replay %s\n", report.Config, f.Reproducer)
+	}
+}
+
+func truncateFuzzDetail(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) > 200 {
+		return s[:200] + "…"
+	}
+	return s
+}
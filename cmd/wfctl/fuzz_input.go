@@ -0,0 +1,435 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/GoCodeAlone/workflow/module"
+)
+
+// fuzzRoute is one HTTP operation of the config's API.
+type fuzzRoute struct {
+	Method string
+	Path   string
+	Op     *module.OpenAPIOperation
+}
+
+// String returns the route as "METHOD /path".
+func (r fuzzRoute) String() string { return r.Method + " " + r.Path }
+
+// fuzzRequest is one generated request. Reproducer files hold it as JSON.
+type fuzzRequest struct {
+	Route   string            `json:"route"`
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+// fuzzPathParamRe matches {name} and {name...} path parameters.
+var fuzzPathParamRe = regexp.MustCompile(`\{([^}.]+)(\.\.\.)?\}`)
+
+// fuzzMaxDepth bounds how deeply generated JSON values nest.
+const fuzzMaxDepth = 4
+
+// fuzzMutationRate is the chance that a schema-directed value is replaced
+// with a value of an unexpected shape.
+const fuzzMutationRate = 0.15
+
+// fuzzStrings are string values that commonly break input handling.
+var fuzzStrings = []string{
+	"",
+	" ",
+	"null",
+	"undefined",
+	"0",
+	"-1",
+	"NaN",
+	"true",
+	"{}",
+	"[]",
+	"' OR '1'='1",
+	"<script>alert(1)</script>",
+	"{{ .secret }}",
+	"${jndi:ldap://x}",
+	"../../etc/passwd",
+	"%00",
+	"\x00",
+	"\t\r\n",
+	"Ω≈ç√∫˜µ≤ 日本語 🙂",
+	"‮​",
+}
+
+// fuzzKeys are object keys used when a value has no schema.
+var fuzzKeys = []string{"id", "name", "email", "count", "amount", "items", "data", "user", "type", "status"}
+
+// collectFuzzRoutes returns the operations of spec that match patterns,
+// sorted by path then method. With no patterns every operation matches.
+func collectFuzzRoutes(spec *module.OpenAPISpec, patterns []string) []fuzzRoute {
+	var routes []fuzzRoute
+	for p, item := range spec.Paths {
+		for _, op := range []struct {
+			method string
+			op     *module.OpenAPIOperation
+		}{
+			{http.MethodGet, item.Get},
+			{http.MethodPost, item.Post},
+			{http.MethodPut, item.Put},
+			{http.MethodDelete, item.Delete},
+			{http.MethodPatch, item.Patch},
+			{http.MethodOptions, item.Options},
+		} {
+			if op.op == nil {
+				continue
+			}
+			r := fuzzRoute{Method: op.method, Path: p, Op: op.op}
+			if matchesFuzzRoute(r, patterns) {
+				routes = append(routes, r)
+			}
+		}
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// matchesFuzzRoute reports whether r matches any pattern. A pattern is a
+// path glob, optionally preceded by a method ("POST /api/orders/*"); a
+// trailing * also matches across slashes.
+func matchesFuzzRoute(r fuzzRoute, patterns []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		method, glob, ok := strings.Cut(strings.TrimSpace(p), " ")
+		if !ok {
+			method, glob = "", method
+		}
+		glob = strings.TrimSpace(glob)
+		if method != "" && !strings.EqualFold(method, r.Method) {
+			continue
+		}
+		if ok, _ := path.Match(glob, r.Path); ok {
+			return true
+		}
+		if prefix, wild := strings.CutSuffix(glob, "*"); wild && strings.HasPrefix(r.Path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// fuzzGenerator generates requests from a single seed, so the same seed
+// always yields the same request.
+type fuzzGenerator struct {
+	rng     *rand.Rand
+	schemas map[string]*module.OpenAPISchema
+}
+
+func newFuzzGenerator(seed int64, spec *module.OpenAPISpec) *fuzzGenerator {
+	g := &fuzzGenerator{rng: rand.New(rand.NewSource(seed))} //nolint:gosec // G404: fuzz inputs must be reproducible, not secure
+	if spec.Components != nil {
+		g.schemas = spec.Components.Schemas
+	}
+	return g
+}
+
+// request generates a request for route. Parameters and JSON bodies follow
+// the route's schemas where it has them, with some values deliberately of
+// the wrong shape; without a schema the body is a random JSON value.
+func (g *fuzzGenerator) request(route fuzzRoute) fuzzRequest {
+	params := map[string]*module.OpenAPIParameter{}
+	for i := range route.Op.Parameters {
+		p := &route.Op.Parameters[i]
+		params[p.In+":"+p.Name] = p
+	}
+
+	target := fuzzPathParamRe.ReplaceAllStringFunc(route.Path, func(m string) string {
+		name := fuzzPathParamRe.FindStringSubmatch(m)[1]
+		var schema *module.OpenAPISchema
+		if p := params["path:"+name]; p != nil {
+			schema = p.Schema
+		}
+		v := g.scalarString(schema)
+		if v == "" {
+			v = "0"
+		}
+		return url.PathEscape(v)
+	})
+
+	query := url.Values{}
+	for _, p := range route.Op.Parameters {
+		if p.In != "query" || (!p.Required && g.rng.Intn(3) == 0) {
+			continue
+		}
+		query.Set(p.Name, g.scalarString(p.Schema))
+	}
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	req := fuzzRequest{
+		Route:   route.String(),
+		Method:  route.Method,
+		URL:     target,
+		Headers: map[string]string{"Accept": "application/json"},
+	}
+	if route.Method == http.MethodGet || route.Method == http.MethodDelete || route.Method == http.MethodOptions {
+		if route.Op.RequestBody == nil {
+			return req
+		}
+	}
+	req.Headers["Content-Type"] = "application/json"
+	if g.rng.Intn(20) == 0 {
+		req.Body = g.malformedJSON()
+		return req
+	}
+	var schema *module.OpenAPISchema
+	if route.Op.RequestBody != nil {
+		if mt := route.Op.RequestBody.Content["application/json"]; mt != nil {
+			schema = mt.Schema
+		}
+	}
+	var body any
+	if schema != nil {
+		body = g.value(schema, 0)
+	} else {
+		body = g.randomObject(0)
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		data = []byte("{}")
+	}
+	req.Body = string(data)
+	return req
+}
+
+// value generates a JSON value for schema.
+func (g *fuzzGenerator) value(schema *module.OpenAPISchema, depth int) any {
+	schema = g.resolve(schema)
+	if schema == nil || depth > fuzzMaxDepth {
+		return g.randomJSON(depth)
+	}
+	if g.rng.Float64() < fuzzMutationRate {
+		return g.mutant(depth)
+	}
+	if len(schema.Enum) > 0 {
+		return schema.Enum[g.rng.Intn(len(schema.Enum))]
+	}
+	switch schema.Type {
+	case "object":
+		if len(schema.Properties) == 0 {
+			return g.randomObject(depth)
+		}
+		required := map[string]bool{}
+		for _, name := range schema.Required {
+			required[name] = true
+		}
+		obj := map[string]any{}
+		for _, name := range sortedSchemaKeys(schema.Properties) {
+			if required[name] || g.rng.Intn(5) < 3 {
+				obj[name] = g.value(schema.Properties[name], depth+1)
+			}
+		}
+		return obj
+	case "array":
+		n := g.rng.Intn(4)
+		arr := make([]any, n)
+		for i := range arr {
+			arr[i] = g.value(schema.Items, depth+1)
+		}
+		return arr
+	case "string":
+		return g.formattedString(schema.Format)
+	case "integer":
+		return g.integer()
+	case "number":
+		return g.number()
+	case "boolean":
+		return g.rng.Intn(2) == 0
+	default:
+		return g.randomJSON(depth)
+	}
+}
+
+// resolve follows a #/components/schemas reference.
+func (g *fuzzGenerator) resolve(schema *module.OpenAPISchema) *module.OpenAPISchema {
+	for i := 0; schema != nil && schema.Ref != "" && i < 8; i++ {
+		schema = g.schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+	}
+	return schema
+}
+
+// scalarString generates a path or query parameter value.
+func (g *fuzzGenerator) scalarString(schema *module.OpenAPISchema) string {
+	v := g.value(schema, fuzzMaxDepth)
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case map[string]any, []any:
+		data, _ := json.Marshal(val)
+		return string(data)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// mutant returns a value of a shape the receiver probably does not expect.
+func (g *fuzzGenerator) mutant(depth int) any {
+	switch g.rng.Intn(10) {
+	case 0:
+		return nil
+	case 1:
+		return g.hugeString()
+	case 2:
+		return int64(-1)
+	case 3:
+		return int64(math.MaxInt64)
+	case 4:
+		return -math.MaxFloat64
+	case 5:
+		return map[string]any{}
+	case 6:
+		return []any{}
+	case 7:
+		return g.rng.Intn(2) == 0
+	case 8:
+		return fuzzStrings[g.rng.Intn(len(fuzzStrings))]
+	default:
+		return g.randomJSON(depth)
+	}
+}
+
+// randomJSON returns a random JSON value of any type.
+func (g *fuzzGenerator) randomJSON(depth int) any {
+	kinds := 6
+	if depth >= fuzzMaxDepth {
+		kinds = 4 // scalars only
+	}
+	switch g.rng.Intn(kinds) {
+	case 0:
+		return nil
+	case 1:
+		return g.rng.Intn(2) == 0
+	case 2:
+		if g.rng.Intn(2) == 0 {
+			return g.integer()
+		}
+		return g.number()
+	case 3:
+		return g.formattedString("")
+	case 4:
+		n := g.rng.Intn(4)
+		arr := make([]any, n)
+		for i := range arr {
+			arr[i] = g.randomJSON(depth + 1)
+		}
+		return arr
+	default:
+		return g.randomObject(depth)
+	}
+}
+
+// randomObject returns an object with random keys and values.
+func (g *fuzzGenerator) randomObject(depth int) map[string]any {
+	obj := map[string]any{}
+	for range g.rng.Intn(5) {
+		obj[fuzzKeys[g.rng.Intn(len(fuzzKeys))]] = g.randomJSON(depth + 1)
+	}
+	return obj
+}
+
+// formattedString returns a string valid for format most of the time.
+func (g *fuzzGenerator) formattedString(format string) string {
+	if g.rng.Intn(3) == 0 {
+		return fuzzStrings[g.rng.Intn(len(fuzzStrings))]
+	}
+	switch format {
+	case "email":
+		return g.word() + "@example.com"
+	case "uuid":
+		b := make([]byte, 16)
+		g.rng.Read(b)
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+	case "date-time":
+		return fmt.Sprintf("20%02d-%02d-%02dT%02d:%02d:00Z", g.rng.Intn(100), 1+g.rng.Intn(12), 1+g.rng.Intn(28), g.rng.Intn(24), g.rng.Intn(60))
+	case "date":
+		return fmt.Sprintf("20%02d-%02d-%02d", g.rng.Intn(100), 1+g.rng.Intn(12), 1+g.rng.Intn(28))
+	}
+	if g.rng.Intn(10) == 0 {
+		return g.hugeString()
+	}
+	return g.word()
+}
+
+func (g *fuzzGenerator) word() string {
+	const letters = "abcdefghijklmnopqrstuvwxyz0123456789"
+	b := make([]byte, 1+g.rng.Intn(12))
+	for i := range b {
+		b[i] = letters[g.rng.Intn(len(letters))]
+	}
+	return string(b)
+}
+
+func (g *fuzzGenerator) hugeString() string {
+	return strings.Repeat("A", 1024<<g.rng.Intn(7))
+}
+
+func (g *fuzzGenerator) integer() int64 {
+	switch g.rng.Intn(6) {
+	case 0:
+		return 0
+	case 1:
+		return -1
+	case 2:
+		return math.MaxInt32
+	case 3:
+		return math.MinInt64
+	default:
+		return g.rng.Int63n(1000)
+	}
+}
+
+func (g *fuzzGenerator) number() float64 {
+	switch g.rng.Intn(5) {
+	case 0:
+		return 0
+	case 1:
+		return -0.5
+	case 2:
+		return math.MaxFloat64
+	case 3:
+		return math.SmallestNonzeroFloat64
+	default:
+		return g.rng.Float64() * 1000
+	}
+}
+
+// malformedJSON returns a body that is not valid JSON.
+func (g *fuzzGenerator) malformedJSON() string {
+	bodies := []string{`{`, `{"id":`, `[1,2`, `"unterminated`, `{"a":1}}`, `null`, `nan`, "\xff\xfe"}
+	return bodies[g.rng.Intn(len(bodies))]
+}
+
+func sortedSchemaKeys(m map[string]*module.OpenAPISchema) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
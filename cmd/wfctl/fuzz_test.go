@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/module"
+)
+
+const fuzzTestConfig = `
+modules:
+  - name: server
+    type: http.server
+    config:
+      address: ":8080"
+  - name: router
+    type: http.router
+workflows:
+  http:
+    server: server
+    router: router
+pipelines:
+  create-order:
+    trigger:
+      type: http
+      config:
+        path: /orders
+        method: POST
+    steps:
+      - name: total
+        type: step.jq
+        config:
+          expression: '.body.count + 1'
+      - name: respond
+        type: step.json_response
+        config:
+          status: 201
+          body:
+            ok: true
+  get-order:
+    trigger:
+      type: http
+      config:
+        path: /orders/{id}
+        method: GET
+    steps:
+      - name: respond
+        type: step.json_response
+        config:
+          status: 200
+          body:
+            id: "{{ .id }}"
+  wipe-orders:
+    trigger:
+      type: http
+      config:
+        path: /orders
+        method: DELETE
+    steps:
+      - name: wipe
+        type: step.jq
+        destructive: true
+        config:
+          expression: 'error("wiped")'
+      - name: respond
+        type: step.json_response
+        config:
+          status: 204
+`
+
+func writeFuzzTestConfig(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "workflow.yaml")
+	if err := os.WriteFile(path, []byte(fuzzTestConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFuzzConfigFindsUnhandledErrors(t *testing.T) {
+	cfgPath := writeFuzzTestConfig(t)
+	opts := fuzzOptions{
+		configPath:  cfgPath,
+		maxRequests: 200,
+		seed:        7,
+		timeout:     10 * time.Second,
+		outDir:      filepath.Join(t.TempDir(), "findings"),
+	}
+	report, err := fuzzConfig(opts)
+	if err != nil {
+		t.Fatalf("fuzzConfig: %v", err)
+	}
+	if report.Requests != 200 {
+		t.Errorf("requests = %d, want 200", report.Requests)
+	}
+	wantRoutes := []string{"DELETE /orders", "POST /orders", "GET /orders/{id}"}
+	if !reflect.DeepEqual(report.Routes, wantRoutes) {
+		t.Errorf("routes = %v, want %v", report.Routes, wantRoutes)
+	}
+	// The destructive step runs in dry-run mode, so only the jq type error
+	// on POST /orders is found, deduplicated to one finding.
+	if len(report.Findings) != 1 {
+		t.Fatalf("findings = %+v, want one", report.Findings)
+	}
+	f := report.Findings[0]
+	if f.Kind != fuzzFindingServerError || f.Route != "POST /orders" || f.Status != http.StatusInternalServerError {
+		t.Errorf("finding = %+v", f)
+	}
+	if !strings.Contains(f.Detail, `step "total" failed`) {
+		t.Errorf("detail = %q", f.Detail)
+	}
+
+	data, err := os.ReadFile(f.Reproducer)
+	if err != nil {
+		t.Fatalf("read reproducer: %v", err)
+	}
+	var repro fuzzReproducer
+	if err := json.Unmarshal(data, &repro); err != nil {
+		t.Fatalf("parse reproducer: %v", err)
+	}
+	if repro.Seed != 7 || repro.Iteration != f.Iteration || repro.Request.Method != http.MethodPost || repro.Request.Headers[module.DryRunHeader] != "true" {
+		t.Errorf("reproducer = %+v", repro)
+	}
+	if err := runFuzzReplay(f.Reproducer, fuzzOptions{timeout: 10 * time.Second}); err == nil || !strings.Contains(err.Error(), "reproduced server_error") {
+		t.Errorf("replay: got %v, want the finding reproduced", err)
+	}
+
+	// The same seed sends the same requests.
+	opts.outDir = filepath.Join(t.TempDir(), "again")
+	again, err := fuzzConfig(opts)
+	if err != nil {
+		t.Fatalf("fuzzConfig: %v", err)
+	}
+	if len(again.Findings) != 1 || again.Findings[0].Iteration != f.Iteration || again.Findings[0].Count != f.Count {
+		t.Errorf("rerun with the same seed found %+v, want %+v", again.Findings, f)
+	}
+}
+
+func TestFuzzConfigLiveRunsDestructiveSteps(t *testing.T) {
+	report, err := fuzzConfig(fuzzOptions{
+		configPath:  writeFuzzTestConfig(t),
+		maxRequests: 20,
+		seed:        1,
+		routes:      []string{"DELETE /orders"},
+		live:        true,
+		timeout:     10 * time.Second,
+		outDir:      t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("fuzzConfig: %v", err)
+	}
+	if len(report.Routes) != 1 || len(report.Findings) != 1 || report.Findings[0].Count != 20 {
+		t.Fatalf("report = %+v, want every request to fail the destructive step", report)
+	}
+}
+
+func TestFuzzConfigNoMatchingRoutes(t *testing.T) {
+	_, err := fuzzConfig(fuzzOptions{configPath: writeFuzzTestConfig(t), routes: []string{"/users/*"}, seed: 1})
+	if err == nil || !strings.Contains(err.Error(), "no HTTP routes match") {
+		t.Fatalf("expected no-match error, got %v", err)
+	}
+}
+
+func TestFuzzTargetReportsPanics(t *testing.T) {
+	capture := &fuzzPanicCapture{}
+	prev := slog.Default()
+	slog.SetDefault(slog.New(capture))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	router := module.NewStandardHTTPRouter("router")
+	router.AddRoute(http.MethodPost, "/boom", panickingHandler{})
+	if err := router.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	target := &fuzzTarget{handler: router, panics: capture}
+
+	out := target.send(fuzzRequest{Route: "POST /boom", Method: http.MethodPost, URL: "/boom", Body: "{}"}, fuzzOptions{})
+	if out.kind != fuzzFindingPanic || !strings.Contains(out.detail, "nil map") {
+		t.Fatalf("outcome = %+v, want a panic finding", out)
+	}
+}
+
+type panickingHandler struct{}
+
+func (panickingHandler) Handle(http.ResponseWriter, *http.Request) {
+	var m map[string]any
+	m["x"] = 1 // assignment to entry in nil map
+}
+
+func TestMatchesFuzzRoute(t *testing.T) {
+	route := fuzzRoute{Method: http.MethodPost, Path: "/api/orders/{id}/items"}
+	tests := []struct {
+		patterns []string
+		want     bool
+	}{
+		{nil, true},
+		{[]string{"/api/orders/{id}/items"}, true},
+		{[]string{"/api/orders/*/items"}, true},
+		{[]string{"/api/*"}, true},
+		{[]string{"POST /api/*"}, true},
+		{[]string{"post /api/orders*"}, true},
+		{[]string{"GET /api/*"}, false},
+		{[]string{"/api/users/*"}, false},
+		{[]string{"/api/users/*", "POST /api/orders/*/items"}, true},
+	}
+	for _, tt := range tests {
+		if got := matchesFuzzRoute(route, tt.patterns); got != tt.want {
+			t.Errorf("matchesFuzzRoute(%q) = %v, want %v", tt.patterns, got, tt.want)
+		}
+	}
+}
+
+func TestFuzzGeneratorFollowsSchema(t *testing.T) {
+	spec := &module.OpenAPISpec{
+		Components: &module.OpenAPIComponents{Schemas: map[string]*module.OpenAPISchema{
+			"Order": {
+				Type:     "object",
+				Required: []string{"sku"},
+				Properties: map[string]*module.OpenAPISchema{
+					"sku":      {Type: "string"},
+					"quantity": {Type: "integer"},
+				},
+			},
+		}},
+	}
+	route := fuzzRoute{Method: http.MethodPut, Path: "/orders/{id}", Op: &module.OpenAPIOperation{
+		Parameters: []module.OpenAPIParameter{
+			{Name: "id", In: "path", Required: true, Schema: &module.OpenAPISchema{Type: "integer"}},
+			{Name: "dry", In: "query", Required: true, Schema: &module.OpenAPISchema{Type: "boolean"}},
+		},
+		RequestBody: &module.OpenAPIRequestBody{Content: map[string]*module.OpenAPIMediaType{
+			"application/json": {Schema: module.SchemaRef("Order")},
+		}},
+	}}
+
+	withSKU := 0
+	for seed := int64(1); seed <= 50; seed++ {
+		req := newFuzzGenerator(seed, spec).request(route)
+		if again := newFuzzGenerator(seed, spec).request(route); !reflect.DeepEqual(req, again) {
+			t.Fatalf("seed %d generated different requests:\n%+v\n%+v", seed, req, again)
+		}
+		if !strings.HasPrefix(req.URL, "/orders/") || strings.Contains(req.URL, "{id}") || !strings.Contains(req.URL, "?dry=") {
+			t.Errorf("url = %q, want the path parameter filled and the required query parameter set", req.URL)
+		}
+		var body map[string]any
+		if json.Unmarshal([]byte(req.Body), &body) == nil {
+			if _, ok := body["sku"]; ok {
+				withSKU++
+			}
+		}
+	}
+	if withSKU < 25 {
+		t.Errorf("only %d of 50 bodies had the required sku property", withSKU)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/GoCodeAlone/workflow/config"
@@ -45,6 +46,48 @@ type projectFeatures struct {
 	hasPlugin   bool
 	hasHTTP     bool
 	configFile  string
+	// plugins lists the external plugins the config depends on
+	// (requires.plugins and plugins.external), sorted and deduplicated.
+	plugins []string
+	// deployEnvs lists ci.deploy.environments in deploy order.
+	deployEnvs []deployTarget
+}
+
+// deployTarget is one environment from ci.deploy.environments.
+type deployTarget struct {
+	name            string
+	requireApproval bool
+}
+
+// githubActionJobs are the job groups `generate github-actions -jobs` selects
+// from. validate, test and build go in ci.yml; deploy adds one job per
+// deploy environment to cd.yml.
+var githubActionJobs = []string{"validate", "test", "build", "deploy"}
+
+// githubActionsOptions holds the generate github-actions flags that shape the
+// generated workflows.
+type githubActionsOptions struct {
+	jobs       map[string]bool
+	goVersions []string
+	runners    []string
+	cache      bool
+	registry   string
+	platforms  string
+}
+
+func defaultGithubActionsOptions() githubActionsOptions {
+	jobs := make(map[string]bool, len(githubActionJobs))
+	for _, j := range githubActionJobs {
+		jobs[j] = true
+	}
+	return githubActionsOptions{
+		jobs:       jobs,
+		goVersions: []string{githubActionsGoVersion},
+		runners:    []string{"ubuntu-latest"},
+		cache:      true,
+		registry:   "ghcr.io",
+		platforms:  "linux/amd64,linux/arm64",
+	}
 }
 
 const (
@@ -56,6 +99,9 @@ const (
 	githubActionsDockerLoginRef       = "docker/login-action@c94ce9fb468520275223c153574b00df6fe4bcc9 # v3"
 	githubActionsDockerSetupBuildxRef = "docker/setup-buildx-action@f7ce87c1d6bead3e36075b2ce75da1f6cc28aaca # v3.9.0"
 	githubActionsDockerBuildPushRef   = "docker/build-push-action@4f58ea79222b3b9dc2c8bbdd6debcef730109a75 # v6.9.0"
+	githubActionsCacheRef             = "actions/cache@27d5ce7f107fe9357f9df03efb73ab90386fccae # v5.0.5"
+
+	githubActionsGoVersion = "1.26.5"
 )
 
 func runGenerateGithubActions(args []string) error {
@@ -65,11 +111,20 @@ func runGenerateGithubActions(args []string) error {
 	genCD := fs.Bool("cd", true, "Generate CD workflow (build, deploy)")
 	registry := fs.String("registry", "ghcr.io", "Container registry for Docker images")
 	platforms := fs.String("platforms", "linux/amd64,linux/arm64", "Platforms to build for")
+	jobs := fs.String("jobs", strings.Join(githubActionJobs, ","), "Comma-separated jobs to generate: validate, test, build, deploy")
+	goVersions := fs.String("go-versions", githubActionsGoVersion, "Comma-separated Go versions for the test matrix")
+	runners := fs.String("os", "ubuntu-latest", "Comma-separated runner images for the test matrix")
+	cache := fs.Bool("cache", true, "Cache Go modules, wfctl plugins and Docker layers")
 	fs.Usage = func() {
 		fmt.Fprintf(fs.Output(), `Usage: wfctl generate github-actions [options] <config.yaml>
 
 Generate GitHub Actions CI/CD workflow files based on config analysis.
 
+ci.yml holds the validate, test (Go version x OS matrix) and build jobs.
+cd.yml builds the container image and, with the deploy job selected, adds one
+deploy job per ci.deploy.environments entry bound to the GitHub environment of
+the same name, so the repository's environment protection rules gate it.
+
 Options:
 `)
 		fs.PrintDefaults()
@@ -84,11 +139,35 @@ Options:
 		return fmt.Errorf("config file path is required")
 	}
 
+	opts := githubActionsOptions{
+		goVersions: splitList(*goVersions),
+		runners:    splitList(*runners),
+		cache:      *cache,
+		registry:   *registry,
+		platforms:  *platforms,
+		jobs:       make(map[string]bool),
+	}
+	for _, j := range splitList(*jobs) {
+		if !containsString(githubActionJobs, j) {
+			return fmt.Errorf("unknown job %q in -jobs (valid: %s)", j, strings.Join(githubActionJobs, ", "))
+		}
+		opts.jobs[j] = true
+	}
+	if len(opts.jobs) == 0 {
+		return fmt.Errorf("-jobs must name at least one job")
+	}
+	if opts.jobs["test"] && (len(opts.goVersions) == 0 || len(opts.runners) == 0) {
+		return fmt.Errorf("-go-versions and -os must each list at least one value for the test matrix")
+	}
+
 	configFile := fs.Arg(0)
 	features, err := detectProjectFeatures(configFile)
 	if err != nil {
 		return fmt.Errorf("failed to analyze config: %w", err)
 	}
+	if opts.jobs["deploy"] && *genCD && len(features.deployEnvs) == 0 {
+		fmt.Println("  note    no ci.deploy.environments in config; cd.yml has no deploy jobs")
+	}
 
 	if err := os.MkdirAll(*output, 0750); err != nil {
 		return fmt.Errorf("failed to create output directory %s: %w", *output, err)
@@ -96,9 +175,9 @@ Options:
 
 	var generated []string
 
-	if *genCI {
+	if *genCI && (opts.jobs["validate"] || opts.jobs["test"] || opts.jobs["build"]) {
 		ciPath := filepath.Join(*output, "ci.yml")
-		if err := writeCIWorkflow(ciPath, features); err != nil {
+		if err := writeCIWorkflow(ciPath, features, opts); err != nil {
 			return fmt.Errorf("failed to generate CI workflow: %w", err)
 		}
 		generated = append(generated, ciPath)
@@ -107,7 +186,7 @@ Options:
 
 	if *genCD {
 		cdPath := filepath.Join(*output, "cd.yml")
-		if err := writeCDWorkflow(cdPath, features, *registry, *platforms); err != nil {
+		if err := writeCDWorkflow(cdPath, features, opts); err != nil {
 			return fmt.Errorf("failed to generate CD workflow: %w", err)
 		}
 		generated = append(generated, cdPath)
@@ -157,10 +236,90 @@ func detectProjectFeatures(configFile string) (*projectFeatures, error) {
 		features.hasPlugin = true
 	}
 
+	seen := make(map[string]bool)
+	if cfg.Requires != nil {
+		for _, p := range cfg.Requires.Plugins {
+			seen[p.Name] = p.Name != ""
+		}
+	}
+	if cfg.Plugins != nil {
+		for _, p := range cfg.Plugins.External {
+			seen[p.Name] = p.Name != ""
+		}
+	}
+	for name, ok := range seen {
+		if ok {
+			features.plugins = append(features.plugins, name)
+		}
+	}
+	sort.Strings(features.plugins)
+
+	if cfg.CI != nil && cfg.CI.Deploy != nil {
+		for name, env := range cfg.CI.Deploy.Environments {
+			// Names end up in job IDs and shell commands; skip anything
+			// outside [a-zA-Z0-9_.-] as wfctl ci init does.
+			if env == nil || !safeEnvNameRe.MatchString(name) {
+				continue
+			}
+			features.deployEnvs = append(features.deployEnvs, deployTarget{name: name, requireApproval: env.RequireApproval})
+		}
+		// Unprotected environments deploy first; environments that require
+		// approval are promoted once those succeed.
+		sort.Slice(features.deployEnvs, func(i, j int) bool {
+			a, b := features.deployEnvs[i], features.deployEnvs[j]
+			if a.requireApproval != b.requireApproval {
+				return !a.requireApproval
+			}
+			return a.name < b.name
+		})
+	}
+
 	return features, nil
 }
 
-func writeCIWorkflow(path string, features *projectFeatures) error {
+// splitList splits a comma-separated flag value, dropping empty entries.
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// writePluginSteps emits steps that restore the wfctl plugin cache and
+// install the plugins the config depends on.
+func writePluginSteps(b *strings.Builder, features *projectFeatures, opts githubActionsOptions) {
+	if len(features.plugins) == 0 {
+		return
+	}
+	if opts.cache {
+		b.WriteString("      - name: Cache wfctl plugins\n")
+		fmt.Fprintf(b, "        uses: %s\n", githubActionsCacheRef)
+		b.WriteString("        with:\n")
+		b.WriteString("          path: data/plugins\n")
+		fmt.Fprintf(b, "          key: wfctl-plugins-${{ runner.os }}-${{ hashFiles('%s', '.wfctl.yaml') }}\n", features.configFile)
+	}
+	fmt.Fprintf(b, "      - name: Install plugins (%s)\n", strings.Join(features.plugins, ", "))
+	fmt.Fprintf(b, "        run: wfctl plugin install --config %s\n", features.configFile)
+}
+
+// writeSetupGoStep emits actions/setup-go for version, with module and build
+// caching keyed on go.sum when caching is enabled.
+func writeSetupGoStep(b *strings.Builder, version string, cache bool) {
+	fmt.Fprintf(b, "      - uses: %s\n", githubActionsSetupGoRef)
+	b.WriteString("        with:\n")
+	fmt.Fprintf(b, "          go-version: '%s'\n", version)
+	if cache {
+		b.WriteString("          cache: true\n")
+		b.WriteString("          cache-dependency-path: go.sum\n")
+	} else {
+		b.WriteString("          cache: false\n")
+	}
+}
+
+func writeCIWorkflow(path string, features *projectFeatures, opts githubActionsOptions) error {
 	var b strings.Builder
 
 	b.WriteString("name: CI\n")
@@ -171,22 +330,66 @@ func writeCIWorkflow(path string, features *projectFeatures) error {
 	b.WriteString("    branches: [main]\n")
 	b.WriteString("\n")
 	b.WriteString("jobs:\n")
+
+	var needs []string
+	if opts.jobs["validate"] {
+		writeValidateJob(&b, features, opts)
+		needs = []string{"validate"}
+	}
+
+	if opts.jobs["test"] {
+		b.WriteString("  test:\n")
+		if len(needs) > 0 {
+			fmt.Fprintf(&b, "    needs: [%s]\n", strings.Join(needs, ", "))
+		}
+		b.WriteString("    strategy:\n")
+		b.WriteString("      fail-fast: false\n")
+		b.WriteString("      matrix:\n")
+		fmt.Fprintf(&b, "        os: [%s]\n", strings.Join(opts.runners, ", "))
+		fmt.Fprintf(&b, "        go: ['%s']\n", strings.Join(opts.goVersions, "', '"))
+		b.WriteString("    runs-on: ${{ matrix.os }}\n")
+		b.WriteString("    steps:\n")
+		fmt.Fprintf(&b, "      - uses: %s\n", githubActionsCheckoutRef)
+		writeSetupGoStep(&b, "${{ matrix.go }}", opts.cache)
+		b.WriteString("      - name: Test\n")
+		b.WriteString("        run: go test ./...\n")
+		needs = []string{"test"}
+	}
+
+	if opts.jobs["build"] {
+		b.WriteString("  build:\n")
+		if len(needs) > 0 {
+			fmt.Fprintf(&b, "    needs: [%s]\n", strings.Join(needs, ", "))
+		}
+		b.WriteString("    runs-on: ubuntu-latest\n")
+		b.WriteString("    steps:\n")
+		fmt.Fprintf(&b, "      - uses: %s\n", githubActionsCheckoutRef)
+		writeSetupGoStep(&b, opts.goVersions[len(opts.goVersions)-1], opts.cache)
+		b.WriteString("      - name: Build\n")
+		b.WriteString("        run: go build ./...\n")
+	}
+
+	return os.WriteFile(path, []byte(b.String()), 0600)
+}
+
+// writeValidateJob emits the validate job: config validation plus the checks
+// implied by the detected features.
+func writeValidateJob(b *strings.Builder, features *projectFeatures, opts githubActionsOptions) {
 	b.WriteString("  validate:\n")
 	b.WriteString("    runs-on: ubuntu-latest\n")
 	b.WriteString("    steps:\n")
-	fmt.Fprintf(&b, "      - uses: %s\n", githubActionsCheckoutRef)
-	fmt.Fprintf(&b, "      - uses: %s\n", githubActionsSetupGoRef)
-	b.WriteString("        with:\n")
-	b.WriteString("          go-version: '1.26.5'\n")
+	fmt.Fprintf(b, "      - uses: %s\n", githubActionsCheckoutRef)
+	writeSetupGoStep(b, githubActionsGoVersion, opts.cache)
 	b.WriteString("      - name: Install wfctl\n")
 	b.WriteString("        run: go install github.com/GoCodeAlone/workflow/cmd/wfctl@latest\n")
+	writePluginSteps(b, features, opts)
 	b.WriteString("      - name: Validate config\n")
-	fmt.Fprintf(&b, "        run: wfctl validate %s\n", features.configFile)
+	fmt.Fprintf(b, "        run: wfctl validate %s\n", features.configFile)
 	b.WriteString("      - name: Inspect config\n")
-	fmt.Fprintf(&b, "        run: wfctl inspect %s\n", features.configFile)
+	fmt.Fprintf(b, "        run: wfctl inspect %s\n", features.configFile)
 
 	if features.hasUI {
-		fmt.Fprintf(&b, "      - uses: %s\n", githubActionsSetupNodeRef)
+		fmt.Fprintf(b, "      - uses: %s\n", githubActionsSetupNodeRef)
 		b.WriteString("        with:\n")
 		b.WriteString("          node-version: '24'\n")
 		b.WriteString("      - name: Build UI\n")
@@ -205,11 +408,9 @@ func writeCIWorkflow(path string, features *projectFeatures) error {
 		b.WriteString("        run: wfctl migrate --config " + features.configFile + "\n")
 		b.WriteString("        continue-on-error: true\n")
 	}
-
-	return os.WriteFile(path, []byte(b.String()), 0600)
 }
 
-func writeCDWorkflow(path string, features *projectFeatures, registry, platforms string) error {
+func writeCDWorkflow(path string, features *projectFeatures, opts githubActionsOptions) error {
 	var b strings.Builder
 
 	b.WriteString("name: CD\n")
@@ -218,7 +419,7 @@ func writeCDWorkflow(path string, features *projectFeatures, registry, platforms
 	b.WriteString("    tags: ['v*']\n")
 	b.WriteString("\n")
 	b.WriteString("env:\n")
-	fmt.Fprintf(&b, "  REGISTRY: %s\n", registry)
+	fmt.Fprintf(&b, "  REGISTRY: %s\n", opts.registry)
 	b.WriteString("\n")
 	b.WriteString("jobs:\n")
 	b.WriteString("  build:\n")
@@ -228,9 +429,7 @@ func writeCDWorkflow(path string, features *projectFeatures, registry, platforms
 	b.WriteString("      packages: write\n")
 	b.WriteString("    steps:\n")
 	fmt.Fprintf(&b, "      - uses: %s\n", githubActionsCheckoutRef)
-	fmt.Fprintf(&b, "      - uses: %s\n", githubActionsSetupGoRef)
-	b.WriteString("        with:\n")
-	b.WriteString("          go-version: '1.26.5'\n")
+	writeSetupGoStep(&b, githubActionsGoVersion, opts.cache)
 
 	if features.hasUI {
 		fmt.Fprintf(&b, "      - uses: %s\n", githubActionsSetupNodeRef)
@@ -257,14 +456,55 @@ func writeCDWorkflow(path string, features *projectFeatures, registry, platforms
 	b.WriteString("        with:\n")
 	b.WriteString("          context: .\n")
 	b.WriteString("          push: true\n")
-	fmt.Fprintf(&b, "          platforms: %s\n", platforms)
+	fmt.Fprintf(&b, "          platforms: %s\n", opts.platforms)
+	if opts.cache {
+		b.WriteString("          cache-from: type=gha\n")
+		b.WriteString("          cache-to: type=gha,mode=max\n")
+	}
 	b.WriteString("          tags: |\n")
 	b.WriteString("            ${{ env.REGISTRY }}/${{ github.repository }}:${{ github.ref_name }}\n")
 	b.WriteString("            ${{ env.REGISTRY }}/${{ github.repository }}:latest\n")
 
+	if opts.jobs["deploy"] {
+		writeDeployJobs(&b, features, opts)
+	}
+
 	return os.WriteFile(path, []byte(b.String()), 0600)
 }
 
+// writeDeployJobs emits one deploy job per deploy environment. Each job is
+// bound to the GitHub environment of the same name, so required reviewers,
+// wait timers and branch rules configured for that environment gate it, and
+// environment-scoped secrets are available to it. Environments that set
+// requireApproval run only after every unprotected environment succeeded.
+func writeDeployJobs(b *strings.Builder, features *projectFeatures, opts githubActionsOptions) {
+	var unprotected []string
+	for _, env := range features.deployEnvs {
+		job := "deploy-" + strings.ReplaceAll(env.name, ".", "-")
+		needs := []string{"build"}
+		if env.requireApproval {
+			needs = append(needs, unprotected...)
+		}
+
+		fmt.Fprintf(b, "  %s:\n", job)
+		fmt.Fprintf(b, "    needs: [%s]\n", strings.Join(needs, ", "))
+		b.WriteString("    runs-on: ubuntu-latest\n")
+		b.WriteString("    environment:\n")
+		fmt.Fprintf(b, "      name: %s\n", env.name)
+		fmt.Fprintf(b, "    concurrency: deploy-%s\n", env.name)
+		b.WriteString("    steps:\n")
+		fmt.Fprintf(b, "      - uses: %s\n", githubActionsCheckoutRef)
+		fmt.Fprintf(b, "      - uses: %s\n", githubActionsSetupWfctlRef)
+		writePluginSteps(b, features, opts)
+		fmt.Fprintf(b, "      - name: Deploy to %s\n", env.name)
+		fmt.Fprintf(b, "        run: wfctl ci run --config %s --phase deploy --env %s\n", features.configFile, env.name)
+
+		if !env.requireApproval {
+			unprotected = append(unprotected, job)
+		}
+	}
+}
+
 func writeReleaseWorkflow(path string) error {
 	content := `name: Release
 on:
@@ -82,6 +82,36 @@ triggers:
     server: test-server
 `
 
+// configWithDeploy has deploy environments and plugin dependencies.
+const configWithDeploy = `
+modules:
+  - name: test-server
+    type: http.server
+    config:
+      address: ":8080"
+
+requires:
+  plugins:
+    - name: workflow-plugin-payments
+      version: ">=1.2.0"
+
+plugins:
+  external:
+    - name: workflow-plugin-audit
+    - name: workflow-plugin-payments
+
+ci:
+  deploy:
+    environments:
+      production:
+        provider: kubernetes
+        requireApproval: true
+      staging:
+        provider: kubernetes
+      "bad name; rm -rf /":
+        provider: kubernetes
+`
+
 func writeConfigFile(t *testing.T, dir, content string) string {
 	t.Helper()
 	path := filepath.Join(dir, "workflow.yaml")
@@ -394,7 +424,7 @@ func TestCIWorkflowContent(t *testing.T) {
 	features := &projectFeatures{configFile: "workflow.yaml"}
 	path := filepath.Join(dir, "ci.yml")
 
-	if err := writeCIWorkflow(path, features); err != nil {
+	if err := writeCIWorkflow(path, features, defaultGithubActionsOptions()); err != nil {
 		t.Fatalf("writeCIWorkflow failed: %v", err)
 	}
 
@@ -423,7 +453,7 @@ func TestCDWorkflowContent(t *testing.T) {
 	features := &projectFeatures{configFile: "workflow.yaml"}
 	path := filepath.Join(dir, "cd.yml")
 
-	if err := writeCDWorkflow(path, features, "ghcr.io", "linux/amd64,linux/arm64"); err != nil {
+	if err := writeCDWorkflow(path, features, defaultGithubActionsOptions()); err != nil {
 		t.Fatalf("writeCDWorkflow failed: %v", err)
 	}
 
@@ -475,6 +505,93 @@ func TestReleaseWorkflowContent(t *testing.T) {
 	}
 }
 
+func TestRunGenerateGithubActionsMatrixAndDeployJobs(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := writeConfigFile(t, dir, configWithDeploy)
+	outDir := filepath.Join(dir, ".github", "workflows")
+
+	err := runGenerateGithubActions([]string{
+		"-go-versions", "1.25.9, 1.26.5", "-os", "ubuntu-latest,macos-latest",
+		"-output", outDir, cfgPath,
+	})
+	if err != nil {
+		t.Fatalf("generate github-actions failed: %v", err)
+	}
+
+	ci, err := os.ReadFile(filepath.Join(outDir, "ci.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"  test:\n    needs: [validate]\n",
+		"        os: [ubuntu-latest, macos-latest]\n",
+		"        go: ['1.25.9', '1.26.5']\n",
+		"    runs-on: ${{ matrix.os }}\n",
+		"          go-version: '${{ matrix.go }}'\n          cache: true\n",
+		"  build:\n    needs: [test]\n",
+		"Install plugins (workflow-plugin-audit, workflow-plugin-payments)",
+		githubActionsCacheRef,
+	} {
+		if !strings.Contains(string(ci), want) {
+			t.Errorf("ci.yml missing %q:\n%s", want, ci)
+		}
+	}
+
+	cd, err := os.ReadFile(filepath.Join(outDir, "cd.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(cd)
+	for _, want := range []string{
+		"cache-from: type=gha",
+		"  deploy-staging:\n    needs: [build]\n",
+		"    environment:\n      name: staging\n",
+		"  deploy-production:\n    needs: [build, deploy-staging]\n",
+		"wfctl ci run --config " + cfgPath + " --phase deploy --env production",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("cd.yml missing %q:\n%s", want, content)
+		}
+	}
+	if strings.Contains(content, "rm -rf") {
+		t.Error("cd.yml must skip environment names that are unsafe in job IDs and shell commands")
+	}
+	if strings.Index(content, "deploy-staging:") > strings.Index(content, "deploy-production:") {
+		t.Error("unprotected environments should be emitted before approval-gated ones")
+	}
+}
+
+func TestRunGenerateGithubActionsJobSelection(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := writeConfigFile(t, dir, configWithDeploy)
+	outDir := filepath.Join(dir, ".github", "workflows")
+
+	if err := runGenerateGithubActions([]string{"-jobs", "test", "-cache=false", "-output", outDir, cfgPath}); err != nil {
+		t.Fatalf("generate github-actions failed: %v", err)
+	}
+	ci, err := os.ReadFile(filepath.Join(outDir, "ci.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(ci), "  validate:") || strings.Contains(string(ci), "  build:") || strings.Contains(string(ci), "needs:") {
+		t.Errorf("only the test job should be generated:\n%s", ci)
+	}
+	if !strings.Contains(string(ci), "cache: false") {
+		t.Error("-cache=false should disable setup-go caching")
+	}
+	cd, err := os.ReadFile(filepath.Join(outDir, "cd.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(cd), "deploy-") || strings.Contains(string(cd), "cache-from") {
+		t.Errorf("cd.yml should have no deploy jobs or layer cache:\n%s", cd)
+	}
+
+	if err := runGenerateGithubActions([]string{"-jobs", "lint", "-output", outDir, cfgPath}); err == nil || !strings.Contains(err.Error(), `unknown job "lint"`) {
+		t.Errorf("expected unknown job error, got %v", err)
+	}
+}
+
 // TestInitTemplatesIncludeGithubWorkflows checks that wfctl init now generates .github/workflows/.
 func TestInitTemplatesIncludeGithubWorkflows(t *testing.T) {
 	cases := []struct {
@@ -16,6 +16,8 @@ type wfctlConfig struct {
 	ProjectName     string
 	ProjectVersion  string
 	ConfigFile      string
+	GitProvider     string
+	GitHost         string
 	GitRepository   string
 	GitBranch       string
 	GitAutoPush     bool
@@ -37,6 +39,8 @@ func runGit(args []string) error {
 		return runGitConnect(args[1:])
 	case "push":
 		return runGitPush(args[1:])
+	case "trigger":
+		return runGitTrigger(args[1:])
 	default:
 		return gitUsage()
 	}
@@ -48,23 +52,29 @@ func gitUsage() error {
 Git integration for workflow projects.
 
 Subcommands:
-  connect   Connect a workflow project to a GitHub repository
-  push      Push workflow project to the configured GitHub repository
+  connect   Connect a workflow project to a GitHub or GitLab repository
+  push      Push workflow project to the configured repository
+  trigger   Start a CI run (GitHub Actions workflow or GitLab pipeline)
 
 Examples:
   wfctl git connect -repo GoCodeAlone/my-api
   wfctl git connect -repo GoCodeAlone/my-api -init
+  wfctl git connect -provider gitlab -repo my-group/my-api
   wfctl git push -message "update config"
   wfctl git push -tag v1.0.0
+  wfctl git push -pull-request -label config -reviewer alice
+  wfctl git trigger -ref main -var DEPLOY_ENV=staging
 `)
-	return fmt.Errorf("subcommand is required (connect, push)")
+	return fmt.Errorf("subcommand is required (connect, push, trigger)")
 }
 
 func runGitConnect(args []string) error {
 	fs := flag.NewFlagSet("git connect", flag.ContinueOnError)
-	repo := fs.String("repo", "", "GitHub repository (owner/name)")
-	token := fs.String("token", "", "GitHub personal access token (or set GITHUB_TOKEN env)")
-	initRepo := fs.Bool("init", false, "Initialize git repo and push to GitHub if not already set up")
+	providerName := fs.String("provider", "github", "Git hosting provider (github, gitlab)")
+	host := fs.String("host", "", "Self-managed GitLab host (default: gitlab.com)")
+	repo := fs.String("repo", "", "Repository (owner/name; GitLab also accepts group/subgroup/name)")
+	token := fs.String("token", "", "Access token for HTTPS remotes (or set GITHUB_TOKEN / GITLAB_TOKEN env)")
+	initRepo := fs.Bool("init", false, "Initialize git repo and set the origin remote if not already set up")
 	configFile := fs.String("config", "workflow.yaml", "Workflow config file for the project")
 	deployTarget := fs.String("deploy-target", "kubernetes", "Deployment target (docker, kubernetes, cloud)")
 	namespace := fs.String("namespace", "default", "Kubernetes namespace for deployment")
@@ -72,7 +82,7 @@ func runGitConnect(args []string) error {
 	fs.Usage = func() {
 		fmt.Fprintf(fs.Output(), `Usage: wfctl git connect [options]
 
-Connect a workflow project to a GitHub repository.
+Connect a workflow project to a GitHub or GitLab repository.
 Writes a .wfctl.yaml project file and optionally initializes the git repo.
 
 Options:
@@ -89,24 +99,27 @@ Options:
 		return fmt.Errorf("-repo is required (e.g. -repo owner/my-api)")
 	}
 
-	// Validate repo format
-	parts := strings.SplitN(*repo, "/", 2)
-	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
-		return fmt.Errorf("invalid repository format %q: expected owner/name", *repo)
+	provider, err := newGitProvider(*providerName, *host)
+	if err != nil {
+		return err
+	}
+	if err := provider.ValidateRepo(*repo); err != nil {
+		return err
 	}
 
-	projectName := parts[1]
+	projectName := (*repo)[strings.LastIndex(*repo, "/")+1:]
 
 	// Determine token for later use
-	ghToken := *token
-	if ghToken == "" {
-		ghToken = os.Getenv("GITHUB_TOKEN")
+	remoteToken := *token
+	if remoteToken == "" {
+		remoteToken = provider.Token()
 	}
 
 	cfg := &wfctlConfig{
 		ProjectName:     projectName,
 		ProjectVersion:  "1.0.0",
 		ConfigFile:      *configFile,
+		GitHost:         *host,
 		GitRepository:   *repo,
 		GitBranch:       *branch,
 		GitAutoPush:     false,
@@ -114,6 +127,9 @@ Options:
 		DeployTarget:    *deployTarget,
 		DeployNamespace: *namespace,
 	}
+	if provider.Name() != "github" {
+		cfg.GitProvider = provider.Name()
+	}
 
 	if err := writeWfctlConfig(cfg); err != nil {
 		return fmt.Errorf("failed to write .wfctl.yaml: %w", err)
@@ -121,14 +137,18 @@ Options:
 	fmt.Println("  create  .wfctl.yaml")
 
 	if *initRepo {
-		if err := initGitRepo(*repo, *branch, ghToken); err != nil {
+		if err := initGitRepo(provider, *repo, *branch, remoteToken); err != nil {
 			return fmt.Errorf("failed to initialize git repo: %w", err)
 		}
 	}
 
 	fmt.Printf("\nProject connected to %s\n", *repo)
 	fmt.Println("\nNext steps:")
-	fmt.Println("  wfctl generate github-actions workflow.yaml")
+	if provider.Name() == "gitlab" {
+		fmt.Println("  wfctl ci generate --platform gitlab_ci --write")
+	} else {
+		fmt.Println("  wfctl generate github-actions workflow.yaml")
+	}
 	fmt.Println("  wfctl git push -message \"initial commit\"")
 	return nil
 }
@@ -141,6 +161,12 @@ func writeWfctlConfig(cfg *wfctlConfig) error {
 	fmt.Fprintf(&b, "  version: %q\n", cfg.ProjectVersion)
 	fmt.Fprintf(&b, "  configFile: %s\n", cfg.ConfigFile)
 	b.WriteString("git:\n")
+	if cfg.GitProvider != "" {
+		fmt.Fprintf(&b, "  provider: %s\n", cfg.GitProvider)
+	}
+	if cfg.GitHost != "" {
+		fmt.Fprintf(&b, "  host: %s\n", cfg.GitHost)
+	}
 	fmt.Fprintf(&b, "  repository: %s\n", cfg.GitRepository)
 	fmt.Fprintf(&b, "  branch: %s\n", cfg.GitBranch)
 	fmt.Fprintf(&b, "  autoPush: %v\n", cfg.GitAutoPush)
@@ -167,7 +193,7 @@ func writeWfctlConfig(cfg *wfctlConfig) error {
 }
 
 // initGitRepo initializes a git repository and sets up the remote.
-func initGitRepo(repo, branch, token string) error {
+func initGitRepo(provider gitProvider, repo, branch, token string) error {
 	// Check if already a git repo
 	if _, err := os.Stat(".git"); err == nil {
 		fmt.Println("  git repo already initialized")
@@ -190,11 +216,8 @@ func initGitRepo(repo, branch, token string) error {
 	}
 
 	// Set remote
-	remoteURL := fmt.Sprintf("git@github.com:%s.git", repo)
-	if token != "" {
-		// Use HTTPS with token when token provided
-		remoteURL = fmt.Sprintf("https://%s@github.com/%s.git", token, repo)
-	}
+	// Use HTTPS with token when token provided
+	remoteURL := provider.RemoteURL(repo, token)
 
 	// Check if origin already set
 	out, err := exec.Command("git", "remote", "get-url", "origin").CombinedOutput()
@@ -202,12 +225,12 @@ func initGitRepo(repo, branch, token string) error {
 		if err := runCmd("git", "remote", "add", "origin", remoteURL); err != nil {
 			return fmt.Errorf("git remote add failed: %w", err)
 		}
-		fmt.Printf("  git remote add origin %s\n", repoDisplayURL(repo, token != ""))
+		fmt.Printf("  git remote add origin %s\n", provider.DisplayURL(repo, token != ""))
 	} else {
 		if err := runCmd("git", "remote", "set-url", "origin", remoteURL); err != nil {
 			return fmt.Errorf("git remote set-url failed: %w", err)
 		}
-		fmt.Printf("  git remote set-url origin %s\n", repoDisplayURL(repo, token != ""))
+		fmt.Printf("  git remote set-url origin %s\n", provider.DisplayURL(repo, token != ""))
 	}
 
 	return nil
@@ -265,6 +288,8 @@ type wfctlConfigFile struct {
 		ConfigFile string `yaml:"configFile"`
 	} `yaml:"project"`
 	Git struct {
+		Provider        string `yaml:"provider"`
+		Host            string `yaml:"host"`
 		Repository      string `yaml:"repository"`
 		Branch          string `yaml:"branch"`
 		AutoPush        bool   `yaml:"autoPush"`
@@ -298,6 +323,8 @@ func loadWfctlConfig() (*wfctlConfig, error) {
 		ProjectName:     raw.Project.Name,
 		ProjectVersion:  raw.Project.Version,
 		ConfigFile:      raw.Project.ConfigFile,
+		GitProvider:     raw.Git.Provider,
+		GitHost:         raw.Git.Host,
 		GitRepository:   raw.Git.Repository,
 		GitBranch:       raw.Git.Branch,
 		GitAutoPush:     raw.Git.AutoPush,
@@ -0,0 +1,353 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// gitProvider is the code-hosting service behind `wfctl git`. It is selected
+// by git.provider in .wfctl.yaml; GitHub is the default.
+type gitProvider interface {
+	// Name is the provider key written to .wfctl.yaml.
+	Name() string
+	// ValidateRepo checks that repo is a repository path the provider accepts.
+	ValidateRepo(repo string) error
+	// RemoteURL returns the origin URL for repo: HTTPS carrying token when one
+	// is given, SSH otherwise.
+	RemoteURL(repo, token string) string
+	// DisplayURL returns the origin URL without credentials.
+	DisplayURL(repo string, useHTTPS bool) string
+	// Token returns the API token from the environment, or "".
+	Token() string
+	// TokenHelp names the environment variables Token reads, for errors.
+	TokenHelp() string
+	// ChangeRequestName is what the provider calls a change request.
+	ChangeRequestName() string
+	// OpenChangeRequest opens a pull or merge request from cr.Head into
+	// cr.Base and applies its labels and reviewers.
+	OpenChangeRequest(token, repo string, cr changeRequest) (*changeRequestResult, error)
+	// TriggerPipeline starts a CI run on run.Ref.
+	TriggerPipeline(token, repo string, run pipelineTrigger) (*pipelineRunResult, error)
+}
+
+// changeRequest describes a pull request (GitHub) or merge request (GitLab).
+type changeRequest struct {
+	Title     string
+	Head      string
+	Base      string
+	Body      string
+	Draft     bool
+	Labels    []string
+	Reviewers []string
+}
+
+// changeRequestResult identifies an opened change request.
+type changeRequestResult struct {
+	// Ref is the provider's short reference, e.g. #42 or !42.
+	Ref string
+	URL string
+}
+
+// pipelineTrigger describes a CI run to start.
+type pipelineTrigger struct {
+	Ref string
+	// Workflow is the GitHub Actions workflow file or ID to dispatch. GitLab
+	// runs the project's .gitlab-ci.yml and ignores it.
+	Workflow  string
+	Variables map[string]string
+}
+
+// pipelineRunResult describes a started CI run. GitHub does not return the
+// run a dispatch creates, so ID and Status are empty there and URL points at
+// the workflow's runs.
+type pipelineRunResult struct {
+	ID     string
+	Status string
+	URL    string
+}
+
+// newGitProvider returns the provider named in .wfctl.yaml. host selects a
+// self-managed GitLab instance and may include a scheme.
+func newGitProvider(name, host string) (gitProvider, error) {
+	switch strings.ToLower(name) {
+	case "", "github":
+		if host != "" && !isGitHubHost(strings.TrimPrefix(host, "https://")) {
+			return nil, fmt.Errorf("git host %q is only supported with provider gitlab", host)
+		}
+		return gitHubProvider{}, nil
+	case "gitlab":
+		return newGitLabProvider(host)
+	default:
+		return nil, fmt.Errorf("unknown git provider %q (expected github or gitlab)", name)
+	}
+}
+
+// gitProviderFor returns the provider configured in cfg.
+func gitProviderFor(cfg *wfctlConfig) (gitProvider, error) {
+	return newGitProvider(cfg.GitProvider, cfg.GitHost)
+}
+
+// gitHubProvider talks to github.com.
+type gitHubProvider struct{}
+
+func (gitHubProvider) Name() string { return "github" }
+
+func (gitHubProvider) ValidateRepo(repo string) error {
+	parts := strings.SplitN(repo, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return fmt.Errorf("invalid repository format %q: expected owner/name", repo)
+	}
+	return nil
+}
+
+func (gitHubProvider) RemoteURL(repo, token string) string {
+	if token != "" {
+		return fmt.Sprintf("https://%s@github.com/%s.git", token, repo)
+	}
+	return repoDisplayURL(repo, false)
+}
+
+func (gitHubProvider) DisplayURL(repo string, useHTTPS bool) string {
+	return repoDisplayURL(repo, useHTTPS)
+}
+
+func (gitHubProvider) Token() string             { return gitHubToken() }
+func (gitHubProvider) TokenHelp() string         { return "GITHUB_TOKEN or GH_TOKEN" }
+func (gitHubProvider) ChangeRequestName() string { return "pull request" }
+
+func (gitHubProvider) OpenChangeRequest(token, repo string, cr changeRequest) (*changeRequestResult, error) {
+	pr, err := openPullRequest(token, repo, cr.Title, cr.Head, cr.Base, cr.Body, cr.Draft)
+	if err != nil {
+		return nil, err
+	}
+	if len(cr.Labels) > 0 {
+		if err := addPullRequestLabels(token, repo, pr.Number, cr.Labels); err != nil {
+			return nil, err
+		}
+	}
+	if len(cr.Reviewers) > 0 {
+		if err := requestPullRequestReviewers(token, repo, pr.Number, cr.Reviewers); err != nil {
+			return nil, err
+		}
+	}
+	return &changeRequestResult{Ref: fmt.Sprintf("#%d", pr.Number), URL: pr.HTMLURL}, nil
+}
+
+// TriggerPipeline dispatches a workflow_dispatch event for run.Workflow.
+// Variables are passed as workflow inputs.
+func (gitHubProvider) TriggerPipeline(token, repo string, run pipelineTrigger) (*pipelineRunResult, error) {
+	if run.Workflow == "" {
+		return nil, fmt.Errorf("-workflow is required for GitHub (e.g. -workflow cd.yml)")
+	}
+	inputs := run.Variables
+	if inputs == nil {
+		inputs = map[string]string{}
+	}
+	path := fmt.Sprintf("repos/%s/actions/workflows/%s/dispatches", escapedGitHubRepoPath(repo), url.PathEscape(run.Workflow))
+	if err := gitHubAPIPost(token, path, map[string]any{"ref": run.Ref, "inputs": inputs}, nil); err != nil {
+		return nil, fmt.Errorf("dispatch workflow %s: %w", run.Workflow, err)
+	}
+	return &pipelineRunResult{URL: fmt.Sprintf("https://github.com/%s/actions/workflows/%s", repo, run.Workflow)}, nil
+}
+
+// gitLabAPIClient is used for GitLab API calls. Tests override it.
+var gitLabAPIClient = &http.Client{Timeout: 10 * time.Minute}
+
+const gitLabAPITimeout = 30 * time.Second
+
+// gitLabProvider talks to gitlab.com or a self-managed GitLab instance.
+type gitLabProvider struct {
+	// baseURL is the instance's web root, e.g. https://gitlab.com.
+	baseURL string
+	// host is baseURL's host, used for git remotes.
+	host string
+}
+
+func newGitLabProvider(host string) (gitLabProvider, error) {
+	if host == "" {
+		host = "gitlab.com"
+	}
+	if !strings.Contains(host, "://") {
+		host = "https://" + host
+	}
+	u, err := url.Parse(host)
+	if err != nil || u.Host == "" {
+		return gitLabProvider{}, fmt.Errorf("invalid GitLab host %q", host)
+	}
+	return gitLabProvider{baseURL: u.Scheme + "://" + u.Host, host: u.Host}, nil
+}
+
+func (gitLabProvider) Name() string { return "gitlab" }
+
+// ValidateRepo accepts group/name and nested group/subgroup/name paths.
+func (gitLabProvider) ValidateRepo(repo string) error {
+	parts := strings.Split(repo, "/")
+	if len(parts) < 2 {
+		return fmt.Errorf("invalid repository format %q: expected group/name", repo)
+	}
+	for _, p := range parts {
+		if p == "" {
+			return fmt.Errorf("invalid repository format %q: expected group/name", repo)
+		}
+	}
+	return nil
+}
+
+func (p gitLabProvider) RemoteURL(repo, token string) string {
+	if token != "" {
+		return fmt.Sprintf("https://oauth2:%s@%s/%s.git", token, p.host, repo)
+	}
+	return p.DisplayURL(repo, false)
+}
+
+func (p gitLabProvider) DisplayURL(repo string, useHTTPS bool) string {
+	if useHTTPS {
+		return fmt.Sprintf("https://%s/%s.git", p.host, repo)
+	}
+	return fmt.Sprintf("git@%s:%s.git", p.host, repo)
+}
+
+func (gitLabProvider) Token() string             { return os.Getenv("GITLAB_TOKEN") }
+func (gitLabProvider) TokenHelp() string         { return "GITLAB_TOKEN" }
+func (gitLabProvider) ChangeRequestName() string { return "merge request" }
+
+// gitLabMergeRequest is the subset of the GitLab merge request response wfctl uses.
+type gitLabMergeRequest struct {
+	IID    int    `json:"iid"`
+	WebURL string `json:"web_url"`
+}
+
+func (p gitLabProvider) OpenChangeRequest(token, repo string, cr changeRequest) (*changeRequestResult, error) {
+	reviewerIDs, err := p.reviewerIDs(token, cr.Reviewers)
+	if err != nil {
+		return nil, err
+	}
+	title := cr.Title
+	if cr.Draft {
+		title = "Draft: " + title
+	}
+	payload := map[string]any{
+		"source_branch": cr.Head,
+		"target_branch": cr.Base,
+		"title":         title,
+		"description":   cr.Body,
+	}
+	if len(cr.Labels) > 0 {
+		payload["labels"] = strings.Join(cr.Labels, ",")
+	}
+	if len(reviewerIDs) > 0 {
+		payload["reviewer_ids"] = reviewerIDs
+	}
+	var mr gitLabMergeRequest
+	if err := p.api(http.MethodPost, token, gitLabProjectPath(repo)+"/merge_requests", payload, &mr); err != nil {
+		return nil, fmt.Errorf("open merge request: %w", err)
+	}
+	return &changeRequestResult{Ref: fmt.Sprintf("!%d", mr.IID), URL: mr.WebURL}, nil
+}
+
+// reviewerIDs resolves GitLab usernames to the user IDs the merge request API
+// expects. GitLab cannot request reviews from groups.
+func (p gitLabProvider) reviewerIDs(token string, reviewers []string) ([]int, error) {
+	ids := make([]int, 0, len(reviewers))
+	for _, r := range reviewers {
+		if strings.Contains(r, "/") {
+			return nil, fmt.Errorf("GitLab merge request reviewers must be usernames, got %q", r)
+		}
+		var users []struct {
+			ID int `json:"id"`
+		}
+		if err := p.api(http.MethodGet, token, "users?username="+url.QueryEscape(strings.TrimPrefix(r, "@")), nil, &users); err != nil {
+			return nil, fmt.Errorf("look up reviewer %s: %w", r, err)
+		}
+		if len(users) == 0 {
+			return nil, fmt.Errorf("look up reviewer %s: no such GitLab user", r)
+		}
+		ids = append(ids, users[0].ID)
+	}
+	return ids, nil
+}
+
+// TriggerPipeline creates a pipeline for run.Ref with run.Variables set as
+// CI/CD variables.
+func (p gitLabProvider) TriggerPipeline(token, repo string, run pipelineTrigger) (*pipelineRunResult, error) {
+	if run.Workflow != "" {
+		return nil, fmt.Errorf("-workflow is only supported for GitHub; GitLab runs the project's .gitlab-ci.yml")
+	}
+	keys := make([]string, 0, len(run.Variables))
+	for k := range run.Variables {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	variables := make([]map[string]string, 0, len(keys))
+	for _, k := range keys {
+		variables = append(variables, map[string]string{"key": k, "value": run.Variables[k]})
+	}
+	var pipeline struct {
+		ID     int    `json:"id"`
+		Status string `json:"status"`
+		WebURL string `json:"web_url"`
+	}
+	payload := map[string]any{"ref": run.Ref, "variables": variables}
+	if err := p.api(http.MethodPost, token, gitLabProjectPath(repo)+"/pipeline", payload, &pipeline); err != nil {
+		return nil, fmt.Errorf("trigger pipeline: %w", err)
+	}
+	return &pipelineRunResult{ID: fmt.Sprintf("#%d", pipeline.ID), Status: pipeline.Status, URL: pipeline.WebURL}, nil
+}
+
+// gitLabProjectPath returns the API path of project repo.
+func gitLabProjectPath(repo string) string {
+	return "projects/" + url.PathEscape(repo)
+}
+
+// api sends payload as JSON to the GitLab v4 API path and decodes the
+// response into out, if non-nil.
+func (p gitLabProvider) api(method, token, apiPath string, payload, out any) error {
+	var reqBody io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	apiURL := p.baseURL + "/api/v4/" + strings.TrimLeft(apiPath, "/")
+	ctx, cancel := context.WithTimeout(context.Background(), gitLabAPITimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, apiURL, reqBody) //nolint:gosec // URL is built from the configured host and repository.
+	if err != nil {
+		return err
+	}
+	req.Header.Set("PRIVATE-TOKEN", token)
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("User-Agent", "wfctl/"+version)
+
+	resp, err := gitLabAPIClient.Do(req)
+	if err != nil {
+		closeResponseBody(resp)
+		return fmt.Errorf("GitLab API request %s: %w", apiPath, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("GitLab API: HTTP %d for %s: %s", resp.StatusCode, apiPath, strings.TrimSpace(string(body)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode GitLab API response: %w", err)
+	}
+	return nil
+}
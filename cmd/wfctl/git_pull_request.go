@@ -0,0 +1,318 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/config"
+)
+
+// pullRequestOptions holds the -pull-request flags of `wfctl git push`.
+type pullRequestOptions struct {
+	branch       string
+	base         string
+	title        string
+	bodyTemplate string
+	labels       multiStringFlag
+	reviewers    multiStringFlag
+	draft        bool
+}
+
+// pullRequestData is the data a pull request description template renders.
+type pullRequestData struct {
+	Title      string
+	Repository string
+	Base       string
+	Branch     string
+	ConfigFile string
+	// Files lists the paths committed on the branch.
+	Files []string
+	// Diff is the wfctl diff of ConfigFile between Base and the branch. It is
+	// nil when the diff could not be computed; DiffError then says why.
+	Diff      *DiffResult
+	DiffText  string
+	DiffError string
+	// Added, Changed and Removed count the modules and pipelines in Diff.
+	Added   int
+	Changed int
+	Removed int
+}
+
+// defaultPullRequestBody is the description template used without
+// -body-template.
+const defaultPullRequestBody = "## Workflow config changes\n\n" +
+	"Updates `{{.ConfigFile}}` for `{{.Base}}`.\n\n" +
+	"{{if .Diff}}{{.Added}} added, {{.Changed}} changed, {{.Removed}} removed (modules and pipelines).\n\n" +
+	"```text\n{{.DiffText}}```\n" +
+	"{{if .Diff.BreakingChanges}}\n### Breaking changes\n" +
+	"{{range .Diff.BreakingChanges}}{{$module := .ModuleName}}{{range .Changes}}\n- `{{$module}}`: {{.Message}}{{end}}{{end}}\n" +
+	"{{end}}{{else if .DiffError}}The config diff could not be computed: {{.DiffError}}\n{{end}}" +
+	"\n### Files\n{{range .Files}}\n- `{{.}}`{{end}}\n\n" +
+	"_Opened by `wfctl git push -pull-request`._\n"
+
+// gitHubPullRequest is the subset of the GitHub pull request response wfctl uses.
+type gitHubPullRequest struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+}
+
+// pushPullRequest commits the staged project files to a new branch, pushes
+// it, and opens a pull request (a merge request on GitLab) against opts.base
+// whose description summarizes the config diff. The previously checked-out
+// branch is restored afterwards.
+func pushPullRequest(cfg *wfctlConfig, configOnly bool, commitMsg string, opts pullRequestOptions) error {
+	provider, err := gitProviderFor(cfg)
+	if err != nil {
+		return err
+	}
+	token := provider.Token()
+	if token == "" {
+		return fmt.Errorf("-pull-request requires a token in %s", provider.TokenHelp())
+	}
+	if err := provider.ValidateRepo(cfg.GitRepository); err != nil {
+		return fmt.Errorf("repository in .wfctl.yaml: %w", err)
+	}
+	tmpl, err := loadPullRequestTemplate(opts.bodyTemplate)
+	if err != nil {
+		return err
+	}
+
+	staged, err := stageFiles(cfg, configOnly)
+	if err != nil {
+		return err
+	}
+	if len(staged) == 0 {
+		return fmt.Errorf("nothing to commit — no %s opened", provider.ChangeRequestName())
+	}
+
+	head := opts.branch
+	if head == "" {
+		head = "wfctl/config-" + time.Now().UTC().Format("20060102-150405")
+	}
+	title := opts.title
+	if title == "" {
+		title = commitMsg
+	}
+	data := pullRequestData{
+		Title:      title,
+		Repository: cfg.GitRepository,
+		Base:       opts.base,
+		Branch:     head,
+		ConfigFile: cfg.ConfigFile,
+		Files:      staged,
+	}
+	summarizeConfigDiff(&data)
+
+	original, _ := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err := runCmd("git", "checkout", "-b", head); err != nil {
+		return fmt.Errorf("git checkout -b %s failed: %w", head, err)
+	}
+	if prev := strings.TrimSpace(string(original)); prev != "" && prev != "HEAD" {
+		defer func() {
+			if err := runCmd("git", "checkout", prev); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: could not switch back to %s: %v\n", prev, err)
+			}
+		}()
+	}
+	if err := runCmd("git", "commit", "-m", commitMsg); err != nil {
+		return fmt.Errorf("git commit failed: %w", err)
+	}
+	fmt.Printf("Committed: %s\n", commitMsg)
+	if err := runCmd("git", "push", "-u", "origin", head); err != nil {
+		return fmt.Errorf("git push failed: %w", err)
+	}
+	fmt.Printf("Pushed to origin/%s\n", head)
+
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, data); err != nil {
+		return fmt.Errorf("render %s description: %w", provider.ChangeRequestName(), err)
+	}
+	opened, err := provider.OpenChangeRequest(token, cfg.GitRepository, changeRequest{
+		Title:     title,
+		Head:      head,
+		Base:      opts.base,
+		Body:      body.String(),
+		Draft:     opts.draft,
+		Labels:    opts.labels,
+		Reviewers: opts.reviewers,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Opened %s %s: %s\n", provider.ChangeRequestName(), opened.Ref, opened.URL)
+	return nil
+}
+
+// loadPullRequestTemplate parses the description template in path, or the
+// default template when path is empty.
+func loadPullRequestTemplate(path string) (*template.Template, error) {
+	text := defaultPullRequestBody
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read -body-template: %w", err)
+		}
+		text = string(data)
+	}
+	tmpl, err := template.New("pull-request").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse pull request template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// summarizeConfigDiff fills data's diff fields by comparing the config file
+// on the base branch with the staged copy. A config file missing on the base
+// branch diffs against an empty config.
+func summarizeConfigDiff(data *pullRequestData) {
+	if data.ConfigFile == "" {
+		data.DiffError = "no configFile in .wfctl.yaml"
+		return
+	}
+	newData, err := exec.Command("git", "show", ":"+data.ConfigFile).Output()
+	if err != nil {
+		data.DiffError = fmt.Sprintf("%s is not staged", data.ConfigFile)
+		return
+	}
+	newCfg, err := config.LoadFromBytes(newData)
+	if err != nil {
+		data.DiffError = fmt.Sprintf("parse %s: %v", data.ConfigFile, err)
+		return
+	}
+	oldCfg := &config.WorkflowConfig{}
+	if oldData, ok := baseFileContent(data.Base, data.ConfigFile); ok {
+		if oldCfg, err = config.LoadFromBytes(oldData); err != nil {
+			data.DiffError = fmt.Sprintf("parse %s on %s: %v", data.ConfigFile, data.Base, err)
+			return
+		}
+	}
+
+	result := diffConfigs(oldCfg, newCfg, nil)
+	result.OldConfig = data.Base + ":" + data.ConfigFile
+	result.NewConfig = data.ConfigFile
+	for _, m := range result.Modules {
+		data.countStatus(m.Status)
+	}
+	for _, p := range result.Pipelines {
+		data.countStatus(p.Status)
+	}
+	var text bytes.Buffer
+	writeDiffText(&text, result)
+	data.Diff = &result
+	data.DiffText = text.String()
+}
+
+func (d *pullRequestData) countStatus(s DiffStatus) {
+	switch s {
+	case DiffStatusAdded:
+		d.Added++
+	case DiffStatusChanged:
+		d.Changed++
+	case DiffStatusRemoved:
+		d.Removed++
+	}
+}
+
+// baseFileContent returns path as committed on the base branch, preferring
+// the remote-tracking branch after a best-effort fetch.
+func baseFileContent(base, path string) ([]byte, bool) {
+	_ = exec.Command("git", "fetch", "--quiet", "origin", base).Run()
+	for _, ref := range []string{"origin/" + base, base} {
+		if out, err := exec.Command("git", "show", ref+":"+path).Output(); err == nil { //nolint:gosec // G204: ref and path come from wfctl flags and .wfctl.yaml
+			return out, true
+		}
+	}
+	return nil, false
+}
+
+// openPullRequest creates a pull request from head into base.
+func openPullRequest(token, repo, title, head, base, body string, draft bool) (*gitHubPullRequest, error) {
+	payload := map[string]any{
+		"title": title,
+		"head":  head,
+		"base":  base,
+		"body":  body,
+		"draft": draft,
+	}
+	var pr gitHubPullRequest
+	if err := gitHubAPIPost(token, "repos/"+escapedGitHubRepoPath(repo)+"/pulls", payload, &pr); err != nil {
+		return nil, fmt.Errorf("open pull request: %w", err)
+	}
+	return &pr, nil
+}
+
+// addPullRequestLabels adds labels to pull request number.
+func addPullRequestLabels(token, repo string, number int, labels []string) error {
+	path := fmt.Sprintf("repos/%s/issues/%d/labels", escapedGitHubRepoPath(repo), number)
+	if err := gitHubAPIPost(token, path, map[string]any{"labels": labels}, nil); err != nil {
+		return fmt.Errorf("add labels to pull request #%d: %w", number, err)
+	}
+	return nil
+}
+
+// requestPullRequestReviewers requests reviews on pull request number.
+// Reviewers of the form org/team are requested as teams.
+func requestPullRequestReviewers(token, repo string, number int, reviewers []string) error {
+	users := []string{}
+	teams := []string{}
+	for _, r := range reviewers {
+		if _, team, ok := strings.Cut(r, "/"); ok {
+			teams = append(teams, team)
+		} else {
+			users = append(users, r)
+		}
+	}
+	path := fmt.Sprintf("repos/%s/pulls/%d/requested_reviewers", escapedGitHubRepoPath(repo), number)
+	if err := gitHubAPIPost(token, path, map[string]any{"reviewers": users, "team_reviewers": teams}, nil); err != nil {
+		return fmt.Errorf("request reviewers on pull request #%d: %w", number, err)
+	}
+	return nil
+}
+
+// gitHubAPIPost sends payload as JSON to the GitHub API path and decodes the
+// response into out, if non-nil.
+func gitHubAPIPost(token, apiPath string, payload, out any) error {
+	reqBody, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	apiURL := strings.TrimRight(gitHubAPIBaseURL, "/") + "/" + strings.TrimLeft(apiPath, "/")
+	ctx, cancel := context.WithTimeout(context.Background(), gitHubReleaseMetadataTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, bytes.NewReader(reqBody)) //nolint:gosec // URL is built from the configured repository.
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("User-Agent", "wfctl/"+version)
+
+	resp, err := gitHubAPIClient.Do(req)
+	if err != nil {
+		closeResponseBody(resp)
+		return fmt.Errorf("GitHub API request %s: %w", apiPath, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("GitHub API: HTTP %d for %s: %s", resp.StatusCode, apiPath, strings.TrimSpace(string(body)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode GitHub API response: %w", err)
+	}
+	return nil
+}
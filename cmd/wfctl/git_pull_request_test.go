@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRunGitPushPullRequestRejectsTag(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	content := `project:
+  name: test
+  configFile: workflow.yaml
+git:
+  repository: GoCodeAlone/my-api
+  branch: main
+`
+	if err := os.WriteFile(".wfctl.yaml", []byte(content), 0640); err != nil {
+		t.Fatalf("failed to write .wfctl.yaml: %v", err)
+	}
+
+	err := runGitPush([]string{"-pull-request", "-tag", "v1.0.0"})
+	if err == nil || !strings.Contains(err.Error(), "-tag cannot be used with -pull-request") {
+		t.Fatalf("expected -tag conflict error, got: %v", err)
+	}
+}
+
+func TestRunGitPushPullRequest(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	root := t.TempDir()
+	remote := filepath.Join(root, "remote.git")
+	work := filepath.Join(root, "work")
+	for _, k := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(k, "wfctl test")
+	}
+	for _, k := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(k, "wfctl@example.com")
+	}
+	t.Setenv("RELEASES_TOKEN", "")
+	t.Setenv("GH_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN", "test-token")
+
+	git := func(dir string, args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git(root, "init", "--bare", "-b", "main", remote)
+	git(root, "init", "-b", "main", work)
+	git(work, "remote", "add", "origin", remote)
+
+	wfctlYAML := `project:
+  name: my-api
+  configFile: workflow.yaml
+git:
+  repository: GoCodeAlone/my-api
+  branch: main
+`
+	baseConfig := `modules:
+  - name: server
+    type: http.server
+    config:
+      address: ":8080"
+`
+	writeFile := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(work, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(".wfctl.yaml", wfctlYAML)
+	writeFile("workflow.yaml", baseConfig)
+	git(work, "add", ".")
+	git(work, "commit", "-m", "initial")
+	git(work, "push", "origin", "main")
+
+	writeFile("workflow.yaml", baseConfig+`  - name: router
+    type: http.router
+`)
+
+	var mu sync.Mutex
+	requests := map[string]map[string]any{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer test-token" {
+			t.Errorf("Authorization = %q", got)
+		}
+		var payload map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		requests[r.URL.Path] = payload
+		mu.Unlock()
+		if r.URL.Path == "/repos/GoCodeAlone/my-api/pulls" {
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"number":42,"html_url":"https://github.com/GoCodeAlone/my-api/pull/42"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+	origBaseURL, origClient := gitHubAPIBaseURL, gitHubAPIClient
+	gitHubAPIBaseURL, gitHubAPIClient = srv.URL, srv.Client()
+	t.Cleanup(func() { gitHubAPIBaseURL, gitHubAPIClient = origBaseURL, origClient })
+
+	t.Chdir(work)
+	err := runGitPush([]string{
+		"-pull-request", "-config-only",
+		"-branch", "config-update",
+		"-message", "add router",
+		"-label", "config,gitops",
+		"-reviewer", "alice",
+		"-reviewer", "GoCodeAlone/platform",
+	})
+	if err != nil {
+		t.Fatalf("runGitPush: %v", err)
+	}
+
+	if got := git(work, "rev-parse", "--abbrev-ref", "HEAD"); got != "main" {
+		t.Errorf("current branch = %q, want main restored", got)
+	}
+	if got := git(remote, "log", "-1", "--format=%s", "config-update"); got != "add router" {
+		t.Errorf("remote config-update head = %q, want the pushed commit", got)
+	}
+
+	pr := requests["/repos/GoCodeAlone/my-api/pulls"]
+	if pr == nil {
+		t.Fatal("pull request was not created")
+	}
+	if pr["head"] != "config-update" || pr["base"] != "main" || pr["title"] != "add router" {
+		t.Errorf("pull request payload = %v", pr)
+	}
+	body, _ := pr["body"].(string)
+	for _, want := range []string{"1 added, 0 changed, 0 removed", "router", "`workflow.yaml`"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q:\n%s", want, body)
+		}
+	}
+
+	labels, _ := json.Marshal(requests["/repos/GoCodeAlone/my-api/issues/42/labels"])
+	if string(labels) != `{"labels":["config","gitops"]}` {
+		t.Errorf("labels payload = %s", labels)
+	}
+	reviewers, _ := json.Marshal(requests["/repos/GoCodeAlone/my-api/pulls/42/requested_reviewers"])
+	if string(reviewers) != `{"reviewers":["alice"],"team_reviewers":["platform"]}` {
+		t.Errorf("reviewers payload = %s", reviewers)
+	}
+}
@@ -13,12 +13,27 @@ func runGitPush(args []string) error {
 	message := fs.String("message", "", "Commit message")
 	tag := fs.String("tag", "", "Create and push an annotated version tag (e.g. v1.0.0)")
 	configOnly := fs.Bool("config-only", false, "Stage only config files (not generated build artifacts)")
+	pullRequest := fs.Bool("pull-request", false, "Commit to a new branch, push it, and open a pull request instead of pushing to the configured branch")
+	fs.BoolVar(pullRequest, "merge-request", false, "Alias for -pull-request (GitLab naming)")
+	var pr pullRequestOptions
+	fs.StringVar(&pr.branch, "branch", "", "Branch to create for -pull-request (default: wfctl/config-<timestamp>)")
+	fs.StringVar(&pr.base, "base", "", "Branch the pull request targets (default: the branch in .wfctl.yaml)")
+	fs.StringVar(&pr.title, "title", "", "Pull request title (default: the commit message)")
+	fs.StringVar(&pr.bodyTemplate, "body-template", "", "Go text/template file for the pull request description")
+	fs.Var(&pr.labels, "label", "Label to add to the pull request (repeatable or comma-separated)")
+	fs.Var(&pr.reviewers, "reviewer", "User or org/team to request a review from (repeatable or comma-separated)")
+	fs.BoolVar(&pr.draft, "draft", false, "Open the pull request as a draft")
 	fs.Usage = func() {
 		fmt.Fprintf(fs.Output(), `Usage: wfctl git push [options]
 
-Stage, commit, and push workflow project files to the configured GitHub repository.
+Stage, commit, and push workflow project files to the configured repository.
 Reads .wfctl.yaml for repository information.
 
+With -pull-request the commit goes to a new branch and a pull request (a
+merge request on GitLab) is opened against the configured branch, with a
+description summarizing the config diff. Requires GITHUB_TOKEN (or GH_TOKEN),
+or GITLAB_TOKEN when git.provider is gitlab.
+
 Options:
 `)
 		fs.PrintDefaults()
@@ -43,38 +58,26 @@ Options:
 		commitMsg = "chore: update workflow config [wfctl]"
 	}
 
-	// Stage files
-	if *configOnly {
-		files := configOnlyFiles(cfg)
-		fmt.Printf("Staging config files: %s\n", strings.Join(files, ", "))
-		for _, f := range files {
-			// Only stage if the file actually exists
-			if _, err := os.Stat(f); err == nil {
-				if err := runCmd("git", "add", f); err != nil {
-					return fmt.Errorf("git add %s: %w", f, err)
-				}
-			}
-		}
-	} else {
-		if err := runCmd("git", "add", "."); err != nil {
-			return fmt.Errorf("git add failed: %w", err)
-		}
+	branch := cfg.GitBranch
+	if branch == "" {
+		branch = "main"
 	}
 
-	// Check if there's anything to commit
-	statusOut, err := exec.Command("git", "status", "--porcelain").Output()
-	if err != nil {
-		return fmt.Errorf("git status failed: %w", err)
+	if *pullRequest {
+		if *tag != "" {
+			return fmt.Errorf("-tag cannot be used with -pull-request: tag the merge commit instead")
+		}
+		if pr.base == "" {
+			pr.base = branch
+		}
+		return pushPullRequest(cfg, *configOnly, commitMsg, pr)
 	}
 
-	// Check staged changes specifically
-	stagedOut, err := exec.Command("git", "diff", "--cached", "--name-only").Output()
+	staged, err := stageFiles(cfg, *configOnly)
 	if err != nil {
-		return fmt.Errorf("git diff --cached failed: %w", err)
+		return err
 	}
-
-	_ = statusOut
-	if strings.TrimSpace(string(stagedOut)) == "" {
+	if len(staged) == 0 {
 		fmt.Println("Nothing to commit — working tree clean.")
 	} else {
 		if err := runCmd("git", "commit", "-m", commitMsg); err != nil {
@@ -84,10 +87,6 @@ Options:
 	}
 
 	// Push to remote
-	branch := cfg.GitBranch
-	if branch == "" {
-		branch = "main"
-	}
 	if err := runCmd("git", "push", "-u", "origin", branch); err != nil {
 		return fmt.Errorf("git push failed: %w", err)
 	}
@@ -103,6 +102,39 @@ Options:
 	return nil
 }
 
+// stageFiles stages the project files, or only the config files when
+// configOnly is set, and returns the paths that are staged for commit.
+func stageFiles(cfg *wfctlConfig, configOnly bool) ([]string, error) {
+	if configOnly {
+		files := configOnlyFiles(cfg)
+		fmt.Printf("Staging config files: %s\n", strings.Join(files, ", "))
+		for _, f := range files {
+			// Only stage if the file actually exists
+			if _, err := os.Stat(f); err == nil {
+				if err := runCmd("git", "add", f); err != nil {
+					return nil, fmt.Errorf("git add %s: %w", f, err)
+				}
+			}
+		}
+	} else {
+		if err := runCmd("git", "add", "."); err != nil {
+			return nil, fmt.Errorf("git add failed: %w", err)
+		}
+	}
+
+	stagedOut, err := exec.Command("git", "diff", "--cached", "--name-only").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff --cached failed: %w", err)
+	}
+	var staged []string
+	for _, line := range strings.Split(string(stagedOut), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			staged = append(staged, line)
+		}
+	}
+	return staged, nil
+}
+
 // createAndPushTag creates an annotated git tag and pushes it.
 func createAndPushTag(tag, message string) error {
 	tagMsg := fmt.Sprintf("Release %s\n\n%s", tag, message)
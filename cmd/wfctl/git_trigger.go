@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// runGitTrigger starts a CI run for the configured repository: a GitLab
+// pipeline, or a GitHub Actions workflow_dispatch run.
+func runGitTrigger(args []string) error {
+	fs := flag.NewFlagSet("git trigger", flag.ContinueOnError)
+	ref := fs.String("ref", "", "Branch or tag to run on (default: the branch in .wfctl.yaml)")
+	workflow := fs.String("workflow", "", "GitHub Actions workflow file or ID to dispatch (GitHub only, e.g. cd.yml)")
+	var vars multiStringFlag
+	fs.Var(&vars, "var", "KEY=VALUE passed as a GitLab CI/CD variable or GitHub workflow input (repeatable or comma-separated)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), `Usage: wfctl git trigger [options]
+
+Start a CI run for the repository in .wfctl.yaml. On GitLab this creates a
+pipeline for -ref; on GitHub it dispatches the -workflow workflow, which must
+declare a workflow_dispatch trigger. Requires GITLAB_TOKEN or GITHUB_TOKEN
+(or GH_TOKEN) for the configured provider.
+
+Options:
+`)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := loadWfctlConfig()
+	if err != nil {
+		return err
+	}
+	if cfg.GitRepository == "" {
+		return fmt.Errorf("no repository configured in .wfctl.yaml (run 'wfctl git connect' first)")
+	}
+	provider, err := gitProviderFor(cfg)
+	if err != nil {
+		return err
+	}
+	token := provider.Token()
+	if token == "" {
+		return fmt.Errorf("git trigger requires a token in %s", provider.TokenHelp())
+	}
+
+	run := pipelineTrigger{Ref: *ref, Workflow: *workflow, Variables: map[string]string{}}
+	if run.Ref == "" {
+		run.Ref = cfg.GitBranch
+	}
+	for _, v := range vars {
+		key, value, ok := strings.Cut(v, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("invalid -var %q: expected KEY=VALUE", v)
+		}
+		run.Variables[key] = value
+	}
+
+	result, err := provider.TriggerPipeline(token, cfg.GitRepository, run)
+	if err != nil {
+		return err
+	}
+	if result.ID != "" {
+		fmt.Printf("Started pipeline %s on %s (%s): %s\n", result.ID, run.Ref, result.Status, result.URL)
+	} else {
+		fmt.Printf("Dispatched %s on %s: %s\n", run.Workflow, run.Ref, result.URL)
+	}
+	return nil
+}
@@ -1,19 +1,35 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow"
 	"github.com/GoCodeAlone/workflow/config"
+	allplugins "github.com/GoCodeAlone/workflow/plugins/all"
+	"github.com/GoCodeAlone/workflow/schema"
 )
 
 func runInspect(args []string) error {
 	fs := flag.NewFlagSet("inspect", flag.ExitOnError)
 	showDeps := fs.Bool("deps", false, "Show module dependency graph")
+	validateRuntime := fs.Bool("validate-runtime", false, "Build every module and wire workflows without starting the engine, reporting construction errors")
+	pluginDir := fs.String("plugin-dir", "", "Directory of external plugins to load for --validate-runtime")
+	stateMachine := fs.String("statemachine", "", "Print the graph of the named statemachine definition instead of the summary")
+	format := fs.String("format", "mermaid", "Graph format for --statemachine: mermaid or dot")
+	showCORS := fs.Bool("cors", false, "Print every HTTP route with its effective CORS policy, preflight cache hints and CORS warnings instead of the summary")
+	simulate := fs.String("simulate", "", "With --statemachine, fire these comma-separated transitions from the initial state and print the state path; fails at the first transition that cannot fire")
 	fs.Usage = func() {
-		fmt.Fprintf(fs.Output(), "Usage: wfctl inspect [options] <config.yaml>\n\nInspect modules, workflows, and triggers in a config.\n\nOptions:\n")
+		fmt.Fprintf(fs.Output(), "Usage: wfctl inspect [options] <config.yaml>\n\nInspect modules, workflows, and triggers in a config.\n\nExamples:\n  wfctl inspect --statemachine order-processing config.yaml\n  wfctl inspect --statemachine order-processing --format dot config.yaml | dot -Tsvg > order.svg\n  wfctl inspect --statemachine order-processing --simulate validate_order,store_order config.yaml\n  wfctl inspect --cors config.yaml\n\nOptions:\n")
 		fs.PrintDefaults()
 	}
 	if err := fs.Parse(args); err != nil {
@@ -23,12 +39,29 @@ func runInspect(args []string) error {
 		fs.Usage()
 		return fmt.Errorf("config file path is required")
 	}
+	if *simulate != "" && *stateMachine == "" {
+		return fmt.Errorf("--simulate requires --statemachine")
+	}
 
 	cfg, err := config.LoadFromFile(fs.Arg(0))
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
+	if *showCORS {
+		return inspectCORS(os.Stdout, cfg)
+	}
+
+	if *stateMachine != "" {
+		var sequence []string
+		for _, t := range strings.Split(*simulate, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				sequence = append(sequence, t)
+			}
+		}
+		return inspectStateMachine(os.Stdout, cfg, *stateMachine, *format, sequence)
+	}
+
 	// Modules summary
 	fmt.Printf("Modules (%d):\n", len(cfg.Modules))
 	typeCount := make(map[string]int)
@@ -38,6 +71,12 @@ func runInspect(args []string) error {
 		if *showDeps && len(mod.DependsOn) > 0 {
 			fmt.Printf("    depends on: %s\n", strings.Join(mod.DependsOn, ", "))
 		}
+		if *showDeps && len(mod.Provides) > 0 {
+			fmt.Printf("    provides: %s\n", joinContracts(mod.Provides))
+		}
+		if *showDeps && len(mod.Needs) > 0 {
+			fmt.Printf("    needs: %s\n", joinContracts(mod.Needs))
+		}
 	}
 
 	// Module type summary
@@ -79,5 +118,168 @@ func runInspect(args []string) error {
 		}
 	}
 
+	// Service wiring resolved from provides/needs declarations
+	if config.HasServiceContracts(cfg.Modules) {
+		fmt.Printf("\nService wiring:\n")
+		wiring, err := config.ResolveServiceContracts(cfg.Modules, nil)
+		for _, w := range wiring {
+			fmt.Printf("  %s needs %s -> %s\n", w.Consumer, w.Need, w.Provider)
+		}
+		for _, e := range unwrapJoined(err) {
+			fmt.Printf("  UNRESOLVED %v\n", e)
+		}
+	}
+
+	if *validateRuntime {
+		fmt.Printf("\nRuntime validation (modules built, not started):\n")
+		problems := dryRunBuild(cfg, *pluginDir)
+		if len(problems) == 0 {
+			fmt.Printf("  PASS all %d modules constructed and wired\n", len(cfg.Modules))
+			return nil
+		}
+		for _, p := range problems {
+			if p.Module != "" {
+				fmt.Printf("  FAIL module %s: %s\n", p.Module, p.Message)
+			} else {
+				fmt.Printf("  FAIL %s\n", p.Message)
+			}
+		}
+		return fmt.Errorf("runtime validation failed with %d problem(s)", len(problems))
+	}
+
 	return nil
 }
+
+// joinContracts formats service contracts as a comma-separated list.
+func joinContracts(contracts []config.ServiceContract) string {
+	parts := make([]string, len(contracts))
+	for i, c := range contracts {
+		parts[i] = c.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+// unwrapJoined returns the errors joined in err, or err alone.
+func unwrapJoined(err error) []error {
+	if err == nil {
+		return nil
+	}
+	if j, ok := err.(interface{ Unwrap() []error }); ok {
+		return j.Unwrap()
+	}
+	return []error{err}
+}
+
+// runtimeProblem is one construction or wiring error found by dryRunBuild.
+type runtimeProblem struct {
+	// Module is the module the error is attributed to, if any.
+	Module  string
+	Message string
+}
+
+// moduleIndexPattern matches the module path schema validation reports.
+var moduleIndexPattern = regexp.MustCompile(`^modules\[(\d+)\]`)
+
+// dryRunBuild runs BuildFromConfig on a fresh engine with the built-in (and
+// any external) plugins, without calling Start: module factories and Init
+// run and workflows and triggers are wired, but no listener is opened and no
+// background work begins. Modules that open a database in Init still do so.
+func dryRunBuild(cfg *config.WorkflowConfig, pluginDir string) (problems []runtimeProblem) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	app := modular.NewStdApplication(nil, logger)
+	eng := workflow.NewStdEngine(app, logger)
+	for _, p := range allplugins.DefaultPlugins() {
+		if err := eng.LoadPlugin(p); err != nil {
+			return []runtimeProblem{{Message: fmt.Sprintf("load plugin %s: %v", p.Name(), err)}}
+		}
+	}
+	shutdown, err := loadExternalPluginsForLocalEngine(eng, pluginDir, logger)
+	if err != nil {
+		return []runtimeProblem{{Message: err.Error()}}
+	}
+	if shutdown != nil {
+		defer shutdown()
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			problems = []runtimeProblem{{Message: fmt.Sprintf("panic while building: %v", r)}}
+		}
+	}()
+	return runtimeProblems(eng.BuildFromConfig(cfg), enabledModuleNames(cfg))
+}
+
+// enabledModuleNames returns the names of the modules the engine builds, in
+// config order; schema errors index modules in this list.
+func enabledModuleNames(cfg *config.WorkflowConfig) []string {
+	var names []string
+	for i := range cfg.Modules {
+		if cfg.Modules[i].IsEnabled() {
+			names = append(names, cfg.Modules[i].Name)
+		}
+	}
+	return names
+}
+
+// runtimeProblems splits a build error into one problem per joined error and
+// attributes each to its module with problemModule.
+func runtimeProblems(err error, modules []string) []runtimeProblem {
+	if err == nil {
+		return nil
+	}
+	var leaves []error
+	var flatten func(error)
+	flatten = func(e error) {
+		if j, ok := e.(interface{ Unwrap() []error }); ok {
+			for _, inner := range j.Unwrap() {
+				flatten(inner)
+			}
+			return
+		}
+		var ve schema.ValidationErrors
+		if errors.As(e, &ve) {
+			for _, v := range ve {
+				leaves = append(leaves, v)
+			}
+			return
+		}
+		if next := errors.Unwrap(e); next != nil {
+			if _, ok := next.(interface{ Unwrap() []error }); ok {
+				flatten(next)
+				return
+			}
+		}
+		leaves = append(leaves, e)
+	}
+	flatten(err)
+
+	problems := make([]runtimeProblem, 0, len(leaves))
+	for _, e := range leaves {
+		problems = append(problems, runtimeProblem{Module: problemModule(e, modules), Message: e.Error()})
+	}
+	return problems
+}
+
+// problemModule returns the module a build error is attributed to, or "".
+// The engine attributes module errors with a workflow.ModuleError or, for a
+// single stuck module, a workflow.StartupTimeoutError; schema validation
+// errors name modules by their index in modules.
+func problemModule(err error, modules []string) string {
+	var modErr *workflow.ModuleError
+	if errors.As(err, &modErr) {
+		return modErr.Module
+	}
+	var timeout *workflow.StartupTimeoutError
+	if errors.As(err, &timeout) && len(timeout.Modules) == 1 {
+		return timeout.Modules[0]
+	}
+	var ve *schema.ValidationError
+	if errors.As(err, &ve) {
+		if m := moduleIndexPattern.FindStringSubmatch(ve.Path); m != nil {
+			if i, convErr := strconv.Atoi(m[1]); convErr == nil && i < len(modules) {
+				return modules[i]
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/GoCodeAlone/workflow/config"
+	"github.com/GoCodeAlone/workflow/module"
+)
+
+// corsPreflightCacheCap is the longest Chromium caches a preflight, in
+// seconds; a larger maxAge is cut to it.
+const corsPreflightCacheCap = 7200
+
+// corsRouteReport is the effective CORS policy of one route.
+type corsRouteReport struct {
+	route  config.HTTPRouteConfig
+	router string
+	// source names where the policy comes from: "route", "module <name>",
+	// "route middleware <name>" or "none".
+	source string
+	policy *module.CORSMiddlewareConfig
+}
+
+// inspectCORS prints every HTTP route with the CORS policy that applies to
+// it once the router's CORS middlewares and the route's own cors block are
+// composed: a route policy wins, then the CORS module attached to the
+// route's router, then a CORS module among the route's middlewares. It
+// follows the attachment rules of the engine: a CORS module attaches to the
+// routers in its dependsOn, else to the routers of the servers in its
+// dependsOn, else to every router. It then reports preflight cache settings
+// worth tuning and CORS configuration problems.
+func inspectCORS(w io.Writer, cfg *config.WorkflowConfig) error {
+	var routers []string
+	serverToRouter := make(map[string]string)
+	corsModules := make(map[string]config.ModuleConfig)
+	var corsNames []string
+	for _, mod := range cfg.Modules {
+		switch mod.Type {
+		case "http.router":
+			routers = append(routers, mod.Name)
+			for _, dep := range mod.DependsOn {
+				serverToRouter[dep] = mod.Name
+			}
+		case "http.middleware.cors":
+			corsModules[mod.Name] = mod
+			corsNames = append(corsNames, mod.Name)
+		}
+	}
+
+	attached := make(map[string][]string) // router → CORS modules
+	for _, name := range corsNames {
+		var targets []string
+		for _, dep := range corsModules[name].DependsOn {
+			if slices.Contains(routers, dep) {
+				targets = append(targets, dep)
+			}
+		}
+		if len(targets) == 0 {
+			for _, dep := range corsModules[name].DependsOn {
+				if r, ok := serverToRouter[dep]; ok {
+					targets = append(targets, r)
+				}
+			}
+		}
+		if len(targets) == 0 {
+			targets = routers
+		}
+		for _, r := range targets {
+			attached[r] = append(attached[r], name)
+		}
+	}
+
+	var problems []string
+	modulePolicies := make(map[string]*module.CORSMiddlewareConfig, len(corsNames))
+	for _, name := range corsNames {
+		policy := module.CORSModuleConfig(corsModules[name].Config)
+		if err := policy.Validate(); err != nil {
+			problems = append(problems, fmt.Sprintf("cors module %q: %v", name, err))
+		}
+		modulePolicies[name] = &policy
+	}
+	for _, r := range routers {
+		if len(attached[r]) > 1 {
+			problems = append(problems, fmt.Sprintf("router %q has several CORS modules (%s); only one applies to each request", r, strings.Join(attached[r], ", ")))
+		}
+	}
+
+	routes := cfg.HTTPRoutes()
+	reports := make([]corsRouteReport, 0, len(routes))
+	for _, route := range routes {
+		rep := corsRouteReport{route: route, router: corsRouteRouter(route, routers), source: "none"}
+		policy, err := module.CORSPolicyFromConfig(route.CORS, route.Method)
+		switch {
+		case err != nil:
+			problems = append(problems, fmt.Sprintf("%s: %v", route.Path, err))
+		case policy != nil:
+			rep.source, rep.policy = "route", policy
+		case len(attached[rep.router]) > 0:
+			name := attached[rep.router][0]
+			rep.source, rep.policy = "module "+name, modulePolicies[name]
+		default:
+			for _, mw := range route.Middlewares {
+				if p, ok := modulePolicies[mw]; ok {
+					rep.source, rep.policy = "route middleware "+mw, p
+					break
+				}
+			}
+		}
+		reports = append(reports, rep)
+	}
+
+	fmt.Fprintf(w, "CORS policies (%d routes):\n", len(reports))
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  ROUTE\tROUTER\tPOLICY\tORIGINS\tMETHODS\tHEADERS\tCREDENTIALS\tMAX-AGE")
+	for _, rep := range reports {
+		origins, methods, headers, creds, maxAge := "-", "-", "-", "-", "-"
+		if p := rep.policy; p != nil {
+			origins = strings.Join(p.AllowedOrigins, ", ")
+			methods = strings.Join(p.AllowedMethods, ", ")
+			headers = strings.Join(p.AllowedHeaders, ", ")
+			creds = "no"
+			if p.AllowCredentials {
+				creds = "yes"
+			}
+			maxAge = strconv.Itoa(p.MaxAge)
+		}
+		router := rep.router
+		if router == "" {
+			router = "-"
+		}
+		fmt.Fprintf(tw, "  %s %s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", rep.route.Method, rep.route.Route, router, rep.source, origins, methods, headers, creds, maxAge)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	// One hint per policy, not per route sharing it.
+	var hints []string
+	seen := make(map[string]bool)
+	for _, rep := range reports {
+		key := rep.source
+		if key == "route" {
+			key = rep.route.Method + " " + rep.route.Route
+		}
+		if rep.policy == nil || seen[key] {
+			continue
+		}
+		seen[key] = true
+		switch {
+		case rep.policy.MaxAge == 0:
+			hints = append(hints, fmt.Sprintf("%s: maxAge is not set, so browsers send a preflight before nearly every request; maxAge: 600 caches it for 10 minutes", key))
+		case rep.policy.MaxAge > corsPreflightCacheCap:
+			hints = append(hints, fmt.Sprintf("%s: maxAge %d exceeds the %d seconds Chromium caches preflights for", key, rep.policy.MaxAge, corsPreflightCacheCap))
+		}
+	}
+	if len(hints) > 0 {
+		fmt.Fprintf(w, "\nPreflight cache:\n")
+		for _, h := range hints {
+			fmt.Fprintf(w, "  %s\n", h)
+		}
+	}
+
+	problems = append(problems, config.CORSWarnings(cfg)...)
+	if len(problems) > 0 {
+		fmt.Fprintf(w, "\nWarnings:\n")
+		for _, p := range problems {
+			fmt.Fprintf(w, "  WARN %s\n", p)
+		}
+	}
+	return nil
+}
+
+// corsRouteRouter returns the router a route is registered on: the one it
+// names, else for HTTP triggers the first of the names the trigger looks
+// up, else the first router declared. It returns "" without routers.
+func corsRouteRouter(route config.HTTPRouteConfig, routers []string) string {
+	if route.Router != "" {
+		return route.Router
+	}
+	if !strings.HasPrefix(route.Path, "workflows.") {
+		for _, name := range []string{"httpRouter", "api-router", "router"} {
+			if slices.Contains(routers, name) {
+				return name
+			}
+		}
+	}
+	if len(routers) > 0 {
+		return routers[0]
+	}
+	return ""
+}
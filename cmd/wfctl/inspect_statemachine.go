@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/GoCodeAlone/workflow/config"
+	"github.com/GoCodeAlone/workflow/validation"
+)
+
+// inspectStateMachine prints the graph of one statemachine definition, or
+// with a non-empty simulate sequence, the state path those transitions take.
+// A simulation that fails returns an error so CI jobs can guard definition
+// changes with a known-good sequence.
+func inspectStateMachine(w io.Writer, cfg *config.WorkflowConfig, name, format string, simulate []string) error {
+	defs := validation.StateMachineDefinitions(cfg.Workflows)
+	var def *validation.StateMachineDef
+	names := make([]string, 0, len(defs))
+	for i := range defs {
+		names = append(names, defs[i].Name)
+		if defs[i].Name == name {
+			def = &defs[i]
+		}
+	}
+	if def == nil {
+		if len(names) == 0 {
+			return fmt.Errorf("statemachine %q not found: config has no statemachine definitions", name)
+		}
+		return fmt.Errorf("statemachine %q not found (definitions: %s)", name, strings.Join(names, ", "))
+	}
+
+	if len(simulate) > 0 {
+		return printSimulation(w, *def, simulate)
+	}
+	switch format {
+	case "mermaid":
+		writeStateMachineMermaid(w, *def)
+	case "dot":
+		writeStateMachineDOT(w, *def)
+	default:
+		return fmt.Errorf("unsupported --format %q (supported: mermaid, dot)", format)
+	}
+	return nil
+}
+
+func printSimulation(w io.Writer, def validation.StateMachineDef, sequence []string) error {
+	res := def.Simulate(sequence)
+	fmt.Fprintf(w, "Simulating %s from %q:\n", def.Name, def.InitialState)
+	for _, s := range res.Steps {
+		auto := ""
+		if s.Auto {
+			auto = " (auto)"
+		}
+		fmt.Fprintf(w, "  %-24s %s -> %s%s\n", s.Transition, s.From, s.To, auto)
+	}
+	if res.Error != "" {
+		fmt.Fprintf(w, "  FAIL at step %d (%s): %s\n", res.FailedAt+1, sequence[min(res.FailedAt, len(sequence)-1)], res.Error)
+		return fmt.Errorf("simulation of %s failed at step %d", def.Name, res.FailedAt+1)
+	}
+	status := "not final"
+	if res.Completed {
+		status = "final"
+	}
+	fmt.Fprintf(w, "Result: %s (%s)\n", res.FinalState, status)
+	return nil
+}
+
+// stateMachineGraph lists the nodes and edges of def in a stable order,
+// including states the initial state or transitions reference but states
+// does not declare.
+func stateMachineGraph(def validation.StateMachineDef) (states []string, transitions []string) {
+	seen := maps.Clone(def.States)
+	refs := []string{def.InitialState}
+	for _, t := range def.Transitions {
+		refs = append(refs, t.FromState, t.ToState)
+	}
+	for _, s := range refs {
+		if _, ok := seen[s]; !ok {
+			seen[s] = validation.StateMachineStateDef{}
+		}
+	}
+	return slices.Sorted(maps.Keys(seen)), slices.Sorted(maps.Keys(def.Transitions))
+}
+
+var mermaidIDUnsafe = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+func writeStateMachineMermaid(w io.Writer, def validation.StateMachineDef) {
+	states, transitions := stateMachineGraph(def)
+	ids := make(map[string]string, len(states))
+	fmt.Fprintln(w, "stateDiagram-v2")
+	for _, s := range states {
+		id := mermaidIDUnsafe.ReplaceAllString(s, "_")
+		ids[s] = id
+		if id != s {
+			fmt.Fprintf(w, "    state %q as %s\n", s, id)
+		}
+	}
+	fmt.Fprintf(w, "    [*] --> %s\n", ids[def.InitialState])
+	for _, name := range transitions {
+		t := def.Transitions[name]
+		label := name
+		if t.AutoTransform {
+			label += " (auto)"
+		}
+		fmt.Fprintf(w, "    %s --> %s: %s\n", ids[t.FromState], ids[t.ToState], label)
+	}
+	for _, s := range states {
+		if def.States[s].IsFinal {
+			fmt.Fprintf(w, "    %s --> [*]\n", ids[s])
+		}
+	}
+	for _, s := range states {
+		if _, declared := def.States[s]; !declared {
+			fmt.Fprintf(w, "    note right of %s: undeclared state\n", ids[s])
+		}
+	}
+}
+
+func writeStateMachineDOT(w io.Writer, def validation.StateMachineDef) {
+	states, transitions := stateMachineGraph(def)
+	fmt.Fprintf(w, "digraph %q {\n", def.Name)
+	fmt.Fprintln(w, "  rankdir=LR;")
+	fmt.Fprintln(w, `  "__start" [shape=point];`)
+	fmt.Fprintf(w, "  \"__start\" -> %q;\n", def.InitialState)
+	for _, s := range states {
+		st, declared := def.States[s]
+		var attrs []string
+		switch {
+		case !declared:
+			attrs = append(attrs, "style=dashed", `xlabel="undeclared"`)
+		case st.IsFinal:
+			attrs = append(attrs, "shape=doublecircle")
+		default:
+			attrs = append(attrs, "shape=circle")
+		}
+		if st.IsError {
+			attrs = append(attrs, "color=red")
+		}
+		fmt.Fprintf(w, "  %q [%s];\n", s, strings.Join(attrs, ", "))
+	}
+	for _, name := range transitions {
+		t := def.Transitions[name]
+		style := ""
+		if t.AutoTransform {
+			style = ", style=dashed"
+		}
+		fmt.Fprintf(w, "  %q -> %q [label=%q%s];\n", t.FromState, t.ToState, name, style)
+	}
+	fmt.Fprintln(w, "}")
+}
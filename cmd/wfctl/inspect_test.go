@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/GoCodeAlone/workflow"
+	"github.com/GoCodeAlone/workflow/config"
+	"github.com/GoCodeAlone/workflow/schema"
+)
+
+func TestRuntimeProblems_AttributesModules(t *testing.T) {
+	err := errors.Join(
+		fmt.Errorf("module 'store' failed to initialize: %w", &workflow.ModuleError{Module: "store", Err: errors.New("boom")}),
+		&workflow.StartupTimeoutError{Phase: "Init", Modules: []string{"cache"}, PerModule: true},
+		// Naming a module in the message alone does not attribute the error.
+		errors.New("module 'web' failed"),
+		fmt.Errorf("config validation failed: %w", schema.ValidationErrors{
+			{Path: "modules[1].type", Message: "unknown module type"},
+		}),
+		errors.New("failed to configure workflows: no server"),
+	)
+	problems := runtimeProblems(err, []string{"web", "bogus"})
+	if len(problems) != 5 {
+		t.Fatalf("got %d problems, want 5: %+v", len(problems), problems)
+	}
+	for i, want := range []string{"store", "cache", "", "bogus", ""} {
+		if problems[i].Module != want {
+			t.Errorf("problem %d module = %q, want %q (%s)", i, problems[i].Module, want, problems[i].Message)
+		}
+	}
+	if runtimeProblems(nil, nil) != nil {
+		t.Error("nil error produced problems")
+	}
+}
+
+func TestDryRunBuild(t *testing.T) {
+	ok := &config.WorkflowConfig{Modules: []config.ModuleConfig{
+		{Name: "web", Type: "http.server", Config: map[string]any{"address": ":0"}},
+		{Name: "router", Type: "http.router", DependsOn: []string{"web"}},
+	}}
+	if problems := dryRunBuild(ok, ""); len(problems) != 0 {
+		t.Fatalf("valid config reported problems: %+v", problems)
+	}
+
+	bad := &config.WorkflowConfig{Modules: []config.ModuleConfig{
+		{Name: "store", Type: "persistence.store", Config: map[string]any{"database": "missing-db"}},
+	}}
+	problems := dryRunBuild(bad, "")
+	if len(problems) != 1 || problems[0].Module != "store" {
+		t.Fatalf("problems = %+v, want one for module store", problems)
+	}
+}
+
+func TestRunInspect_ValidateRuntimeFails(t *testing.T) {
+	path := writeTestConfig(t, t.TempDir(), "config.yaml", `
+modules:
+  - name: store
+    type: persistence.store
+    config:
+      database: missing-db
+`)
+	if err := runInspect([]string{"--validate-runtime", path}); err == nil {
+		t.Fatal("expected runtime validation to fail")
+	}
+}
+
+func stateMachineTestConfig() *config.WorkflowConfig {
+	return &config.WorkflowConfig{Workflows: map[string]any{
+		"statemachine": map[string]any{"definitions": []any{map[string]any{
+			"name":         "orders",
+			"initialState": "new",
+			"states": map[string]any{
+				"new":     map[string]any{},
+				"paid":    map[string]any{},
+				"shipped": map[string]any{"isFinal": true},
+			},
+			"transitions": map[string]any{
+				"pay":    map[string]any{"fromState": "new", "toState": "paid"},
+				"ship":   map[string]any{"fromState": "paid", "toState": "shipped", "autoTransform": true},
+				"cancel": map[string]any{"fromState": "new", "toState": "in-review"},
+			},
+		}}},
+	}}
+}
+
+func TestInspectStateMachine_Graph(t *testing.T) {
+	cfg := stateMachineTestConfig()
+
+	var mermaid bytes.Buffer
+	if err := inspectStateMachine(&mermaid, cfg, "orders", "mermaid", nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"stateDiagram-v2",
+		"[*] --> new",
+		"new --> paid: pay",
+		"paid --> shipped: ship (auto)",
+		"shipped --> [*]",
+		`state "in-review" as in_review`,
+		"note right of in_review: undeclared state",
+	} {
+		if !strings.Contains(mermaid.String(), want) {
+			t.Errorf("mermaid output missing %q:\n%s", want, mermaid.String())
+		}
+	}
+
+	var dot bytes.Buffer
+	if err := inspectStateMachine(&dot, cfg, "orders", "dot", nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`digraph "orders" {`,
+		`"shipped" [shape=doublecircle];`,
+		`"paid" -> "shipped" [label="ship", style=dashed];`,
+		`"in-review" [style=dashed, xlabel="undeclared"];`,
+	} {
+		if !strings.Contains(dot.String(), want) {
+			t.Errorf("dot output missing %q:\n%s", want, dot.String())
+		}
+	}
+
+	if err := inspectStateMachine(io.Discard, cfg, "orders", "svg", nil); err == nil {
+		t.Error("expected an error for an unsupported format")
+	}
+	if err := inspectStateMachine(io.Discard, cfg, "invoices", "mermaid", nil); err == nil || !strings.Contains(err.Error(), "definitions: orders") {
+		t.Errorf("err = %v, want a not-found error listing the definitions", err)
+	}
+}
+
+func TestInspectStateMachine_Simulate(t *testing.T) {
+	cfg := stateMachineTestConfig()
+
+	var out bytes.Buffer
+	if err := inspectStateMachine(&out, cfg, "orders", "mermaid", []string{"pay"}); err != nil {
+		t.Fatalf("simulation failed: %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "(auto)") || !strings.Contains(out.String(), "Result: shipped (final)") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+
+	out.Reset()
+	err := inspectStateMachine(&out, cfg, "orders", "mermaid", []string{"pay", "cancel"})
+	if err == nil || !strings.Contains(err.Error(), "failed at step 2") {
+		t.Fatalf("err = %v, want a failure at step 2", err)
+	}
+	if !strings.Contains(out.String(), "FAIL at step 2 (cancel)") {
+		t.Errorf("output missing the failing step:\n%s", out.String())
+	}
+}
+
+func TestInspectCORS(t *testing.T) {
+	path := writeTestConfig(t, t.TempDir(), "config.yaml", `
+modules:
+  - name: server
+    type: http.server
+    config:
+      address: ":8080"
+  - name: router
+    type: http.router
+    dependsOn: [server]
+  - name: admin-router
+    type: http.router
+  - name: cors
+    type: http.middleware.cors
+    config:
+      allowedOrigins: ["https://app.example.com"]
+      allowedMethods: ["GET", "POST", "PATCH"]
+      maxAge: 86400
+    dependsOn: [server]
+workflows:
+  http:
+    router: admin-router
+    routes:
+      - method: DELETE
+        path: /admin/items/{id}
+pipelines:
+  list-items:
+    trigger:
+      type: http
+      config:
+        method: GET
+        path: /api/items
+  create-item:
+    trigger:
+      type: http
+      config:
+        method: POST
+        path: /api/items
+        cors:
+          allowedOrigins: ["https://admin.example.com"]
+          allowCredentials: true
+`)
+	cfg, err := config.LoadFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := inspectCORS(&out, cfg); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+	lines := strings.Split(got, "\n")
+	rowFor := func(route string) string {
+		for _, l := range lines {
+			if strings.HasPrefix(strings.TrimSpace(l), route+" ") {
+				return strings.Join(strings.Fields(l), " ")
+			}
+		}
+		t.Fatalf("no row for %s:\n%s", route, got)
+		return ""
+	}
+	for route, want := range map[string]string{
+		// The route's own policy wins, with the route method as default.
+		"POST /api/items": "POST /api/items router route https://admin.example.com POST Content-Type, Authorization yes 0",
+		// The module attaches to the router of the server it depends on.
+		"GET /api/items": "GET /api/items router module cors https://app.example.com GET, POST, PATCH Content-Type, Authorization no 86400",
+		// The admin router has no CORS middleware.
+		"DELETE /admin/items/{id}": "DELETE /admin/items/{id} admin-router none - - - - -",
+	} {
+		if row := rowFor(route); row != want {
+			t.Errorf("row = %q\nwant  %q", row, want)
+		}
+	}
+	for _, want := range []string{
+		"POST /api/items: maxAge is not set",
+		"module cors: maxAge 86400 exceeds the 7200 seconds",
+		`WARN cors module "cors" allows method PATCH but no route declared in the config serves it`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+}
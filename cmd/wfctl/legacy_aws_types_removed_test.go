@@ -47,7 +47,7 @@ func TestValidateFile_LegacyAWSModule_ReturnsActionableError(t *testing.T) {
 	if err := os.WriteFile(cfgPath, yamlContent, 0o600); err != nil {
 		t.Fatal(err)
 	}
-	err := validateFile(cfgPath, false, false, false, false)
+	err := validateFile(cfgPath, validateOptions{})
 	if err == nil {
 		t.Fatal("expected error for legacy AWS module type")
 	}
@@ -18,6 +18,7 @@ import (
 	"github.com/GoCodeAlone/workflow/config"
 	"github.com/GoCodeAlone/workflow/handlers"
 	"github.com/GoCodeAlone/workflow/module"
+	wfversion "github.com/GoCodeAlone/workflow/version"
 )
 
 // wfctlConfigBytes is the embedded workflow config that declares wfctl's CLI
@@ -40,6 +41,9 @@ func init() {
 	if version == "dev" {
 		version = buildVersion()
 	}
+	// Share the resolved version with packages that report through the
+	// version package (engine version endpoint, execution stamps).
+	wfversion.SetVersion(version)
 }
 
 func buildVersion() string {
@@ -150,6 +154,7 @@ var commands = map[string]func([]string) error{
 	"ci":              runCI,
 	"override":        runOverride,
 	"test":            runTest,
+	"fuzz":            runFuzz,
 	"env":             runEnv,
 	"secrets":         runSecrets,
 	"vars":            runVars,
@@ -161,6 +166,7 @@ var commands = map[string]func([]string) error{
 	"scaffold":        runScaffoldCmd,
 	"tenant":          runTenant,
 	"capability":      runCapability,
+	"standby":         runStandby,
 }
 
 func main() {
@@ -288,6 +288,27 @@ func TestRunValidateValid(t *testing.T) {
 	}
 }
 
+func TestRunValidateStrictLocales(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "locales"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestConfig(t, filepath.Join(dir, "locales"), "en.yaml", "greeting: Hello\nfarewell: Bye\n")
+	writeTestConfig(t, filepath.Join(dir, "locales"), "de.yaml", "greeting: Hallo\n")
+	path := writeTestConfig(t, dir, "app.yaml", minimalConfig+`
+locales:
+  default: en
+  dir: locales
+`)
+	if err := runValidate([]string{path}); err != nil {
+		t.Fatalf("missing translations should only warn by default, got: %v", err)
+	}
+	err := runValidate([]string{"--strict-locales", path})
+	if err == nil || !strings.Contains(err.Error(), "locales: de lacks 1 message(s) of the default locale en: farewell") {
+		t.Fatalf("expected --strict-locales to fail on the missing translation, got: %v", err)
+	}
+}
+
 func TestRunValidateAllowsInfraSecretPseudoModules(t *testing.T) {
 	dir := t.TempDir()
 	cfg := `
@@ -688,7 +709,7 @@ triggers:
 `
 	path := writeTestConfig(t, dir, "snake.yaml", snakeCaseConfig)
 	// validateFile returns the detailed error; runValidate returns a summary
-	err := validateFile(path, false, false, false, false)
+	err := validateFile(path, validateOptions{})
 	if err == nil {
 		t.Fatal("expected error for snake_case config field")
 	}
@@ -4,10 +4,14 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 
 	"github.com/GoCodeAlone/workflow/config"
 	"github.com/GoCodeAlone/workflow/manifest"
+	"github.com/GoCodeAlone/workflow/manifest/render"
+	"github.com/GoCodeAlone/workflow/pkg/k8s"
 	"gopkg.in/yaml.v3"
 )
 
@@ -15,15 +19,28 @@ func runManifest(args []string) error {
 	fs := flag.NewFlagSet("manifest", flag.ContinueOnError)
 	format := fs.String("format", "json", "Output format: json or yaml")
 	name := fs.String("name", "", "Override the workflow name in the manifest")
+	output := fs.String("output", "report", "Output: report, terraform or kubernetes")
+	image := fs.String("image", "ghcr.io/gocodealone/workflow:latest", "Workflow server image (kubernetes output)")
+	namespace := fs.String("namespace", "default", "Namespace of generated objects (kubernetes output)")
+	outFile := fs.String("out", "", "Write generated output to this file instead of stdout")
 	fs.Usage = func() {
 		fmt.Fprintf(fs.Output(), `Usage: wfctl manifest [options] <config.yaml>
 
-Analyze a workflow configuration and report its infrastructure requirements.
+Analyze a workflow configuration and report its infrastructure requirements,
+or generate the resources that run it.
+
+Outputs:
+  report       Infrastructure requirements as JSON or YAML (default)
+  terraform    Terraform HCL for the config's platform.resource declarations
+  kubernetes   Deployment, Service, ConfigMap, Secret and PVC manifests for
+               the server and its application-tier platform resources
 
 Examples:
   wfctl manifest config.yaml
   wfctl manifest -format yaml config.yaml
   wfctl manifest -name my-service config.yaml
+  wfctl manifest --output terraform -out main.tf config.yaml
+  wfctl manifest --output kubernetes -image registry/app:v1 config.yaml
 
 Options:
 `)
@@ -43,6 +60,18 @@ Options:
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
+	switch *output {
+	case "report":
+	case "terraform", "kubernetes":
+		data, err := os.ReadFile(fs.Arg(0))
+		if err != nil {
+			return fmt.Errorf("failed to read config: %w", err)
+		}
+		return writeGeneratedManifest(*output, fs.Arg(0), data, cfg, *name, *image, *namespace, *outFile)
+	default:
+		return fmt.Errorf("unsupported output: %s (use report, terraform or kubernetes)", *output)
+	}
+
 	m := manifest.AnalyzeWithName(cfg, *name)
 
 	switch *format {
@@ -64,3 +93,51 @@ Options:
 
 	return nil
 }
+
+// writeGeneratedManifest renders the terraform or kubernetes output for cfg,
+// prefixed with a header recording the config file and its hash.
+func writeGeneratedManifest(target, path string, data []byte, cfg *config.WorkflowConfig, name, image, namespace, outFile string) error {
+	var body []byte
+	switch target {
+	case "terraform":
+		hcl, err := render.GenerateTerraform(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to generate terraform: %w", err)
+		}
+		body = hcl
+	case "kubernetes":
+		if name == "" {
+			name = manifest.AnalyzeWithName(cfg, "").Name
+		}
+		ms, warnings, err := k8s.Generate(cfg, k8s.GenerateOpts{
+			Name:           name,
+			Namespace:      namespace,
+			Image:          image,
+			ConfigFileData: data,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to generate kubernetes manifests: %w", err)
+		}
+		for _, w := range warnings {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+		}
+		if body, err = ms.Render(); err != nil {
+			return fmt.Errorf("failed to render kubernetes manifests: %w", err)
+		}
+	}
+
+	var w io.Writer = os.Stdout
+	if outFile != "" {
+		f, err := os.Create(outFile)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", outFile, err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if _, err := io.WriteString(w, render.GeneratedHeader(target, filepath.Base(path), data)); err != nil {
+		return err
+	}
+	_, err := w.Write(body)
+	return err
+}
@@ -33,6 +33,10 @@ Subcommands:
   validate  Validate wfctl.yaml and .wfctl-lock.yaml project config files
   migrate   Manage engine config database schema migrations
             (replaces the deprecated wfctl migrate command)
+  stage     Submit a config to a running engine (staged when approval is required)
+  pending   List staged config changes or show one with its diff
+  approve   Approve a staged config change (must not be its author)
+  reject    Reject a staged config change
 
 `)
 		return fmt.Errorf("missing or unknown subcommand")
@@ -42,8 +46,16 @@ Subcommands:
 		return runConfigValidate(args[1:])
 	case "migrate":
 		return runConfigMigrate(args[1:])
+	case "stage":
+		return runConfigStage(args[1:])
+	case "pending":
+		return runConfigPending(args[1:])
+	case "approve":
+		return runConfigApprove(args[1:])
+	case "reject":
+		return runConfigReject(args[1:])
 	default:
-		return fmt.Errorf("unknown wfctl config subcommand %q (available: validate, migrate)", args[0])
+		return fmt.Errorf("unknown wfctl config subcommand %q (available: validate, migrate, stage, pending, approve, reject)", args[0])
 	}
 }
 
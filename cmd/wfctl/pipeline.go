@@ -27,6 +27,10 @@ func runPipeline(args []string) error {
 		return runPipelineList(args[1:])
 	case "run":
 		return runPipelineRun(args[1:])
+	case "schedule-preview":
+		return runPipelineSchedulePreview(args[1:])
+	case "snapshot":
+		return runPipelineSnapshot(args[1:])
 	default:
 		return pipelineUsage()
 	}
@@ -36,8 +40,10 @@ func pipelineUsage() error {
 	fmt.Fprintf(flag.CommandLine.Output(), `Usage: wfctl pipeline <subcommand> [options]
 
 Subcommands:
-  list   List available pipelines in a config file
-  run    Execute a pipeline from a config file
+  list              List available pipelines in a config file
+  run               Execute a pipeline from a config file
+  schedule-preview  Show the next run times of scheduled jobs
+  snapshot          Record or verify golden snapshots of pipeline results
 `)
 	return fmt.Errorf("pipeline subcommand is required")
 }
@@ -113,6 +119,7 @@ func runPipelineRun(args []string) error {
 	pluginDir := fs.String("plugin-dir", "", "Directory containing installed external plugins")
 	inputJSON := fs.String("input", "", "Input data as JSON object")
 	verbose := fs.Bool("verbose", false, "Show detailed step output")
+	dryRun := fs.Bool("dry-run", false, "Preview steps marked destructive: true instead of executing them")
 	var vars stringSliceFlag
 	fs.Var(&vars, "var", "Variable in key=value format (repeatable)")
 	fs.Usage = func() {
@@ -125,6 +132,7 @@ Examples:
   wfctl pipeline run -c app.yaml -p deploy --var env=staging --var version=1.2.3
   wfctl pipeline run -c app.yaml -p process-data --input '{"items":[1,2,3]}'
   wfctl pipeline run -c app.yaml -p verify --plugin-dir .wfctl/plugins
+  wfctl pipeline run -c app.yaml -p purge-accounts --dry-run
 
 Options:
 `)
@@ -224,6 +232,9 @@ Options:
 
 	// Print execution header
 	fmt.Printf("Pipeline: %s\n", *pipelineName)
+	if *dryRun {
+		fmt.Println("Mode: dry run (destructive steps are previewed, not executed)")
+	}
 	if len(triggerData) > 0 {
 		inputBytes, _ := json.Marshal(triggerData)
 		fmt.Printf("Input: %s\n", inputBytes)
@@ -233,7 +244,11 @@ Options:
 	totalStart := time.Now()
 
 	// Execute the pipeline, printing step progress inline.
-	pc, execErr := executePipelineWithProgress(context.Background(), pipeline, triggerData, *verbose)
+	ctx := context.Background()
+	if *dryRun {
+		ctx = module.WithDryRun(ctx)
+	}
+	pc, execErr := executePipelineWithProgress(ctx, pipeline, triggerData, *verbose)
 
 	totalElapsed := time.Since(totalStart)
 
@@ -297,6 +312,14 @@ func (ps *progressStep) Execute(ctx context.Context, pc *module.PipelineContext)
 		return result, err
 	}
 
+	if result != nil && result.DryRun {
+		// Always show the preview: it is what a dry run is for.
+		fmt.Printf("DRY RUN (%s)\n", elapsed.Round(time.Millisecond))
+		preview, _ := json.MarshalIndent(result.Output["would_execute"], "  ", "  ")
+		fmt.Printf("  Would execute: %s\n", preview)
+		return result, nil
+	}
+
 	fmt.Printf("OK (%s)\n", elapsed.Round(time.Millisecond))
 
 	if ps.verbose && result != nil && len(result.Output) > 0 {
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/config"
+	"github.com/GoCodeAlone/workflow/scheduler/cron"
+)
+
+// schedulePreview is one scheduled job with its upcoming run times, as
+// printed by `wfctl pipeline schedule-preview --json`.
+type schedulePreview struct {
+	Path     string      `json:"path"`
+	Pipeline string      `json:"pipeline,omitempty"`
+	Workflow string      `json:"workflow,omitempty"`
+	Cron     string      `json:"cron"`
+	Timezone string      `json:"timezone"`
+	NextRuns []time.Time `json:"nextRuns,omitempty"`
+	Error    string      `json:"error,omitempty"`
+}
+
+// runPipelineSchedulePreview prints the next run times of every scheduled
+// job in a config file, evaluated in each job's timezone.
+func runPipelineSchedulePreview(args []string) error {
+	fs := flag.NewFlagSet("pipeline schedule-preview", flag.ContinueOnError)
+	configPath := fs.String("c", "", "Path to workflow config YAML file (required)")
+	count := fs.Int("n", 5, "Number of upcoming runs to show per job")
+	fromFlag := fs.String("from", "", "Start time in RFC 3339 format (default: now)")
+	jsonOut := fs.Bool("json", false, "Write JSON output")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), `Usage: wfctl pipeline schedule-preview -c <config.yaml> [options]
+
+Show the next run times of every scheduled job: triggers.schedule jobs and
+pipelines with a schedule trigger. Times are shown in each job's timezone
+(UTC when none is set).
+
+Options:
+`)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *configPath == "" {
+		fs.Usage()
+		return fmt.Errorf("-c (config file) is required")
+	}
+	if *count < 1 {
+		return fmt.Errorf("-n must be at least 1")
+	}
+	from := time.Now()
+	if *fromFlag != "" {
+		t, err := time.Parse(time.RFC3339, *fromFlag)
+		if err != nil {
+			return fmt.Errorf("invalid -from: %w", err)
+		}
+		from = t
+	}
+
+	cfg, err := config.LoadFromFile(*configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	previews, invalid := buildSchedulePreviews(cfg.ScheduleJobs(), from, *count)
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(previews); err != nil {
+			return err
+		}
+	} else {
+		printSchedulePreviews(previews)
+	}
+	if invalid > 0 {
+		return fmt.Errorf("%d scheduled job(s) have an invalid schedule", invalid)
+	}
+	return nil
+}
+
+// buildSchedulePreviews computes the next n runs after from for each job and
+// reports how many jobs have an invalid cron expression or timezone.
+func buildSchedulePreviews(jobs []config.ScheduleJobConfig, from time.Time, n int) ([]schedulePreview, int) {
+	previews := make([]schedulePreview, 0, len(jobs))
+	invalid := 0
+	for _, job := range jobs {
+		p := schedulePreview{
+			Path:     job.Path,
+			Pipeline: job.Pipeline,
+			Workflow: job.Workflow,
+			Cron:     job.Cron,
+			Timezone: job.Timezone,
+		}
+		if p.Timezone == "" {
+			p.Timezone = "UTC"
+		}
+		s, err := cron.ParseInLocation(job.Cron, job.Timezone)
+		if err != nil {
+			p.Error = err.Error()
+			invalid++
+		} else {
+			p.NextRuns = s.NextN(from, n)
+		}
+		previews = append(previews, p)
+	}
+	return previews, invalid
+}
+
+func printSchedulePreviews(previews []schedulePreview) {
+	if len(previews) == 0 {
+		fmt.Println("No scheduled jobs defined in config.")
+		return
+	}
+	for i, p := range previews {
+		if i > 0 {
+			fmt.Println()
+		}
+		target := p.Workflow
+		if p.Pipeline != "" {
+			target = "pipeline " + p.Pipeline
+		}
+		fmt.Printf("%s  %q (%s)  %s\n", target, p.Cron, p.Timezone, p.Path)
+		if p.Error != "" {
+			fmt.Printf("  ERROR: %s\n", p.Error)
+			continue
+		}
+		for _, t := range p.NextRuns {
+			fmt.Printf("  %s\n", t.Format("Mon 2006-01-02 15:04:05 MST"))
+		}
+	}
+}
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow"
+	"github.com/GoCodeAlone/workflow/config"
+	"github.com/GoCodeAlone/workflow/module"
+	"github.com/GoCodeAlone/workflow/wftest"
+)
+
+// snapshotOptions configures wfctl pipeline snapshot record and verify.
+type snapshotOptions struct {
+	casesPath  string
+	configPath string // overrides the cases file's config
+	only       string // run only this case
+	pluginDir  string
+	record     bool // write every snapshot
+	update     bool // verify, rewriting changed and missing snapshots
+}
+
+func runPipelineSnapshot(args []string) error {
+	if len(args) < 1 {
+		return pipelineSnapshotUsage()
+	}
+	switch args[0] {
+	case "record", "verify":
+	default:
+		return pipelineSnapshotUsage()
+	}
+	mode := args[0]
+
+	set := flag.NewFlagSet("pipeline snapshot "+mode, flag.ContinueOnError)
+	opts := snapshotOptions{record: mode == "record"}
+	set.StringVar(&opts.casesPath, "cases", "", "Path to the snapshot cases YAML file (required)")
+	set.StringVar(&opts.configPath, "config", "", "Workflow config to run, overriding the cases file's config")
+	set.StringVar(&opts.only, "case", "", "Run only the named case")
+	set.StringVar(&opts.pluginDir, "plugin-dir", "", "Directory containing installed external plugins")
+	if mode == "verify" {
+		set.BoolVar(&opts.update, "update", false, "Rewrite snapshots that changed or are missing instead of failing")
+	}
+	set.Usage = func() {
+		fmt.Fprintf(set.Output(), `Usage: wfctl pipeline snapshot %s --cases <cases.yaml> [options]
+
+`, mode)
+		if mode == "record" {
+			fmt.Fprintf(set.Output(), `Run every case of a cases file and write its snapshot: the response or
+pipeline output and the selected step outputs, normalized and canonical.
+`)
+		} else {
+			fmt.Fprintf(set.Output(), `Run every case of a cases file and compare it with its recorded snapshot.
+Exits non-zero and prints a diff when a result changed or a snapshot is
+missing; --update rewrites those snapshots instead.
+`)
+		}
+		fmt.Fprintf(set.Output(), `
+Examples:
+  wfctl pipeline snapshot %[1]s --cases tests/snapshots.yaml
+  wfctl pipeline snapshot %[1]s --config app.yaml --cases tests/snapshots.yaml --case create-order
+
+Options:
+`, mode)
+		set.PrintDefaults()
+	}
+	if err := set.Parse(args[1:]); err != nil {
+		return err
+	}
+	if opts.casesPath == "" {
+		set.Usage()
+		return fmt.Errorf("--cases is required")
+	}
+	if opts.pluginDir == "" {
+		opts.pluginDir = strings.TrimSpace(os.Getenv("WFCTL_PLUGIN_DIR"))
+	}
+	return runSnapshotCases(opts, os.Stdout)
+}
+
+func pipelineSnapshotUsage() error {
+	fmt.Fprintf(flag.CommandLine.Output(), `Usage: wfctl pipeline snapshot <record|verify> --cases <cases.yaml> [options]
+
+Subcommands:
+  record  Run each case and write its snapshot
+  verify  Run each case and fail on any change from its snapshot
+`)
+	return fmt.Errorf("snapshot subcommand is required")
+}
+
+// runSnapshotCases records or verifies the snapshots of a cases file and
+// writes one line per case to w.
+func runSnapshotCases(opts snapshotOptions, w io.Writer) error {
+	f, err := wftest.LoadSnapshotFile(opts.casesPath)
+	if err != nil {
+		return err
+	}
+	if opts.configPath != "" {
+		f.Config, f.YAML = opts.configPath, ""
+	}
+	if f.Config == "" && f.YAML == "" {
+		return fmt.Errorf("%s: set 'config' or 'yaml', or pass --config", opts.casesPath)
+	}
+	names := f.CaseNames()
+	if opts.only != "" {
+		if _, ok := f.Cases[opts.only]; !ok {
+			return fmt.Errorf("case %q not found in %s", opts.only, opts.casesPath)
+		}
+		names = []string{opts.only}
+	}
+
+	// Suppress pipeline engine logs, and send module lifecycle messages to
+	// stderr, so w carries only the report.
+	prevLogger, prevStdout := slog.Default(), os.Stdout
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Stdout = os.Stderr
+	defer func() {
+		slog.SetDefault(prevLogger)
+		os.Stdout = prevStdout
+	}()
+
+	fmt.Fprintf(w, "%s (%d cases)\n", filepath.Base(opts.casesPath), len(names))
+	failed := 0
+	for _, name := range names {
+		path := f.SnapshotPath(name)
+		rel := path
+		if r, err := filepath.Rel(filepath.Dir(opts.casesPath), path); err == nil {
+			rel = r
+		}
+		result, err := runSnapshotCase(f, name, opts.pluginDir)
+		var got []byte
+		if err == nil {
+			got, err = f.Snapshot(name, result)
+		}
+		if err != nil {
+			failed++
+			fmt.Fprintf(w, "  ERROR   %s: %v\n", name, err)
+			continue
+		}
+
+		want, readErr := os.ReadFile(path)
+		missing := errors.Is(readErr, fs.ErrNotExist)
+		if readErr != nil && !missing {
+			return fmt.Errorf("read snapshot: %w", readErr)
+		}
+		switch {
+		case !missing && bytes.Equal(want, got):
+			if opts.record {
+				fmt.Fprintf(w, "  SAME    %s\n", name)
+			} else {
+				fmt.Fprintf(w, "  PASS    %s\n", name)
+			}
+		case opts.record || opts.update:
+			if err := writeSnapshot(path, got); err != nil {
+				return err
+			}
+			verb := "UPDATED"
+			if missing {
+				verb = "WROTE  "
+			}
+			fmt.Fprintf(w, "  %s %s  %s\n", verb, name, rel)
+		case missing:
+			failed++
+			fmt.Fprintf(w, "  FAIL    %s: no snapshot at %s; run wfctl pipeline snapshot record\n", name, rel)
+		default:
+			failed++
+			lines, err := wftest.DiffSnapshots(want, got)
+			if err != nil {
+				return fmt.Errorf("snapshot %s: %w", rel, err)
+			}
+			if len(lines) == 0 {
+				lines = []string{"the recorded snapshot is not in canonical form; re-record it"}
+			}
+			fmt.Fprintf(w, "  FAIL    %s: %s changed\n", name, rel)
+			for _, line := range lines {
+				fmt.Fprintf(w, "          %s\n", line)
+			}
+		}
+	}
+	if failed > 0 {
+		if !opts.record && !opts.update {
+			fmt.Fprintf(w, "\nRun with --update to accept the changes.\n")
+		}
+		return fmt.Errorf("%d of %d snapshot case(s) failed", failed, len(names))
+	}
+	return nil
+}
+
+func writeSnapshot(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil { //nolint:gosec // G306: snapshots are committed test data
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+	return nil
+}
+
+// runSnapshotCase builds an engine for one case, with the case's mocks in
+// place of the real steps, and runs its trigger. HTTP triggers are served
+// in-process by the started engine's router, with HTTP servers on an
+// ephemeral loopback port; pipeline triggers run without starting it.
+func runSnapshotCase(f *wftest.SnapshotFile, name, pluginDir string) (*wftest.Result, error) {
+	trigger := f.Cases[name].Trigger
+	method, isHTTP := trigger.HTTPMethod()
+	if !isHTTP && trigger.Type != "" && !strings.EqualFold(trigger.Type, "pipeline") {
+		return nil, fmt.Errorf("unsupported trigger type %q (snapshots support pipeline and http triggers)", trigger.Type)
+	}
+
+	var cfg *config.WorkflowConfig
+	var err error
+	if f.YAML != "" {
+		cfg, err = config.LoadFromString(f.YAML)
+	} else {
+		cfg, err = config.LoadFromFile(f.Config)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	mocks, err := f.CaseMocks(name)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := &testDiscardLogger{}
+	eng := workflow.NewStdEngine(modular.NewStdApplication(nil, logger), logger)
+	for _, p := range testBuiltinPlugins() {
+		if err := eng.LoadPlugin(p); err != nil {
+			return nil, fmt.Errorf("LoadPlugin(%s): %w", p.Name(), err)
+		}
+	}
+	shutdownPlugins, err := loadExternalPluginsForLocalEngine(eng, pluginDir, slog.Default())
+	if err != nil {
+		return nil, err
+	}
+	if shutdownPlugins != nil {
+		defer shutdownPlugins()
+	}
+	for stepType, output := range mocks {
+		eng.AddStepType(stepType, newTestMockStepFactory(output))
+	}
+	for i := range cfg.Modules {
+		if cfg.Modules[i].Type == "http.server" {
+			if cfg.Modules[i].Config == nil {
+				cfg.Modules[i].Config = map[string]any{}
+			}
+			cfg.Modules[i].Config["address"] = "127.0.0.1:0"
+			delete(cfg.Modules[i].Config, "port")
+		}
+	}
+	if err := eng.BuildFromConfig(cfg); err != nil {
+		return nil, fmt.Errorf("BuildFromConfig: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if !isHTTP {
+		if trigger.Name == "" {
+			return nil, fmt.Errorf("trigger.name is required for pipeline triggers")
+		}
+		pc, err := eng.ExecutePipelineContext(ctx, trigger.Name, trigger.Data)
+		if err != nil {
+			return &wftest.Result{Error: err}, nil
+		}
+		output := pc.Current
+		if pipeOut, ok := pc.Metadata["_pipeline_output"].(map[string]any); ok {
+			output = pipeOut
+		}
+		return &wftest.Result{Output: output, StepResults: pc.StepOutputs}, nil
+	}
+
+	if trigger.Path == "" {
+		return nil, fmt.Errorf("trigger.path is required for http triggers")
+	}
+	body, err := trigger.HTTPBody()
+	if err != nil {
+		return nil, err
+	}
+	if err := eng.Start(ctx); err != nil {
+		return nil, fmt.Errorf("start engine: %w", err)
+	}
+	defer func() { _ = eng.Stop(context.Background()) }()
+
+	registry := eng.App().SvcRegistry()
+	var handler http.Handler
+	if router, err := module.FindByInterface[fuzzRouterHandler](registry); err == nil {
+		handler = router.Service
+	} else if h, err := module.FindByInterface[http.Handler](registry); err == nil {
+		handler = h.Service
+	}
+	if handler == nil {
+		return nil, fmt.Errorf("no HTTP router found; the config needs an http.router module")
+	}
+
+	var bodyReader io.Reader
+	if body != "" {
+		bodyReader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, trigger.Path, bodyReader)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range trigger.Headers {
+		req.Header.Set(k, v)
+	}
+	holder := &module.PipelineContextHolder{}
+	req = req.WithContext(context.WithValue(ctx, module.PipelineContextKey, holder))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	result := &wftest.Result{
+		StatusCode: rec.Code,
+		Headers:    make(map[string]string, len(rec.Header())),
+		RawBody:    rec.Body.Bytes(),
+	}
+	for k, v := range rec.Header() {
+		if len(v) > 0 {
+			result.Headers[k] = v[0]
+		}
+	}
+	if pc := holder.Get(); pc != nil {
+		result.StepResults = pc.StepOutputs
+	}
+	return result, nil
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const snapshotTestConfig = `
+modules:
+  - name: router
+    type: http.router
+pipelines:
+  quote:
+    steps:
+      - name: price
+        type: step.set
+        config:
+          values:
+            total: TOTAL
+            quoted_at: "2026-03-01T10:15:00Z"
+  lookup:
+    trigger:
+      type: http
+      config:
+        path: /orders/{id}
+        method: GET
+    steps:
+      - name: fetch
+        type: step.order_lookup
+      - name: respond
+        type: step.json_response
+        config:
+          status: 200
+          body:
+            status: "{{ .steps.fetch.status }}"
+`
+
+func writeSnapshotTestFiles(t *testing.T, total string) (dir, casesPath string) {
+	t.Helper()
+	dir = t.TempDir()
+	cfg := strings.Replace(snapshotTestConfig, "TOTAL", total, 1)
+	if err := os.WriteFile(filepath.Join(dir, "app.yaml"), []byte(cfg), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cases := `config: app.yaml
+mocks:
+  steps:
+    step.order_lookup: {status: shipped}
+cases:
+  quote:
+    trigger: {type: pipeline, name: quote}
+    steps: [price]
+  lookup:
+    trigger: {type: http, method: GET, path: /orders/42}
+    steps: [fetch]
+`
+	casesPath = filepath.Join(dir, "cases.yaml")
+	if err := os.WriteFile(casesPath, []byte(cases), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return dir, casesPath
+}
+
+func TestPipelineSnapshotRecordAndVerify(t *testing.T) {
+	dir, casesPath := writeSnapshotTestFiles(t, "10")
+
+	var out bytes.Buffer
+	if err := runSnapshotCases(snapshotOptions{casesPath: casesPath, record: true}, &out); err != nil {
+		t.Fatalf("record: %v\n%s", err, out.String())
+	}
+	quote, err := os.ReadFile(filepath.Join(dir, "__snapshots__", "quote.snap.json"))
+	if err != nil {
+		t.Fatalf("quote snapshot not written: %v", err)
+	}
+	if !strings.Contains(string(quote), `"quoted_at": "<timestamp>"`) {
+		t.Errorf("expected normalized timestamp in snapshot:\n%s", quote)
+	}
+	lookup, err := os.ReadFile(filepath.Join(dir, "__snapshots__", "lookup.snap.json"))
+	if err != nil {
+		t.Fatalf("lookup snapshot not written: %v", err)
+	}
+	for _, want := range []string{`"status": 200`, `"status": "shipped"`} {
+		if !strings.Contains(string(lookup), want) {
+			t.Errorf("lookup snapshot missing %s:\n%s", want, lookup)
+		}
+	}
+
+	out.Reset()
+	if err := runSnapshotCases(snapshotOptions{casesPath: casesPath}, &out); err != nil {
+		t.Fatalf("verify: %v\n%s", err, out.String())
+	}
+	if strings.Count(out.String(), "PASS") != 2 {
+		t.Errorf("expected 2 passing cases:\n%s", out.String())
+	}
+}
+
+func TestPipelineSnapshotVerifyDetectsChange(t *testing.T) {
+	dir, casesPath := writeSnapshotTestFiles(t, "10")
+	var out bytes.Buffer
+	if err := runSnapshotCases(snapshotOptions{casesPath: casesPath, record: true}, &out); err != nil {
+		t.Fatalf("record: %v\n%s", err, out.String())
+	}
+
+	// Change the pipeline's result and verify against the old snapshot.
+	cfg := strings.Replace(snapshotTestConfig, "TOTAL", "12", 1)
+	if err := os.WriteFile(filepath.Join(dir, "app.yaml"), []byte(cfg), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	err := runSnapshotCases(snapshotOptions{casesPath: casesPath}, &out)
+	if err == nil {
+		t.Fatalf("expected verify to fail:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "~ output.total: 10 -> 12") {
+		t.Errorf("expected a diff line for output.total:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := runSnapshotCases(snapshotOptions{casesPath: casesPath, update: true}, &out); err != nil {
+		t.Fatalf("verify --update: %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "UPDATED quote") {
+		t.Errorf("expected quote to be updated:\n%s", out.String())
+	}
+	out.Reset()
+	if err := runSnapshotCases(snapshotOptions{casesPath: casesPath}, &out); err != nil {
+		t.Fatalf("verify after update: %v\n%s", err, out.String())
+	}
+}
+
+func TestPipelineSnapshotVerifyMissingSnapshot(t *testing.T) {
+	_, casesPath := writeSnapshotTestFiles(t, "10")
+	var out bytes.Buffer
+	err := runSnapshotCases(snapshotOptions{casesPath: casesPath, only: "quote"}, &out)
+	if err == nil {
+		t.Fatal("expected verify to fail without a recorded snapshot")
+	}
+	if !strings.Contains(out.String(), "no snapshot") {
+		t.Errorf("expected a missing snapshot message:\n%s", out.String())
+	}
+}
+
+func TestPipelineSnapshotRequiresCases(t *testing.T) {
+	if err := runPipelineSnapshot([]string{"verify"}); err == nil {
+		t.Fatal("expected error when --cases is missing")
+	}
+	if err := runPipelineSnapshot([]string{"bogus"}); err == nil {
+		t.Fatal("expected error for unknown snapshot subcommand")
+	}
+}
@@ -5,6 +5,9 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/config"
 )
 
 // pipelineConfig is a minimal config with two pipelines using only
@@ -392,3 +395,61 @@ func TestStringSliceFlag(t *testing.T) {
 		t.Errorf("String() missing entry: %s", f.String())
 	}
 }
+
+// --- pipeline schedule-preview ---
+
+const scheduleConfig = `
+modules: []
+
+triggers:
+  schedule:
+    jobs:
+      - cron: "30 2 * * *"
+        timezone: America/New_York
+        workflow: report
+        action: run
+      - cron: "0 24 * * *"
+        workflow: broken
+        action: run
+
+pipelines:
+  nightly:
+    trigger:
+      type: schedule
+      config:
+        cron: "@daily"
+    steps:
+      - name: log
+        type: step.log
+        config:
+          message: nightly
+`
+
+func TestBuildSchedulePreviews(t *testing.T) {
+	dir := t.TempDir()
+	path := writePipelineConfig(t, dir, "schedule.yaml", scheduleConfig)
+	err := runPipelineSchedulePreview([]string{"-c", path, "-n", "2", "-from", "2025-03-08T12:00:00Z"})
+	if err == nil || !strings.Contains(err.Error(), "1 scheduled job(s) have an invalid schedule") {
+		t.Fatalf("expected the invalid job to fail the preview, got: %v", err)
+	}
+
+	cfg, err := config.LoadFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2025, 3, 8, 12, 0, 0, 0, time.UTC)
+	previews, invalid := buildSchedulePreviews(cfg.ScheduleJobs(), from, 2)
+	if invalid != 1 || len(previews) != 3 {
+		t.Fatalf("got %d previews with %d invalid", len(previews), invalid)
+	}
+	// 02:30 does not exist in New York on 2025-03-09 and is skipped.
+	if got := previews[0].NextRuns[0].Format("2006-01-02 15:04 MST"); got != "2025-03-10 02:30 EDT" {
+		t.Errorf("first New York run = %s", got)
+	}
+	if !strings.Contains(previews[1].Error, `field 2 (hour) "24"`) {
+		t.Errorf("invalid job error = %q", previews[1].Error)
+	}
+	if previews[2].Pipeline != "nightly" || previews[2].Timezone != "UTC" || len(previews[2].NextRuns) != 2 {
+		t.Errorf("pipeline preview = %+v", previews[2])
+	}
+}
@@ -13,6 +13,13 @@ import (
 type RegistryConfig struct {
 	Registries    []RegistrySourceConfig      `yaml:"registries" json:"registries"`
 	Compatibility RegistryCompatibilityConfig `yaml:"compatibility,omitempty" json:"compatibility,omitempty"`
+	Marketplace   RegistryMarketplaceConfig   `yaml:"marketplace,omitempty" json:"marketplace,omitempty"`
+}
+
+// RegistryMarketplaceConfig points wfctl registry search/info/install at a
+// plugin marketplace.
+type RegistryMarketplaceConfig struct {
+	URL string `yaml:"url,omitempty" json:"url,omitempty"` // Marketplace base URL, serving /api/v1/plugins
 }
 
 type RegistryCompatibilityConfig struct {
@@ -28,17 +28,24 @@ func runRegistry(args []string) error {
 		return runRegistryPrune(rest)
 	case "logout":
 		return runRegistryLogout(rest)
+	case "search":
+		return runRegistrySearch(rest)
+	case "info":
+		return runRegistryInfo(rest)
+	case "install":
+		return runRegistryInstall(rest)
 	default:
 		fmt.Fprintf(os.Stderr, "wfctl registry: unknown subcommand %q\n", sub)
 		_ = registryContainerUsage()
-		return fmt.Errorf("unknown registry subcommand %q — valid: login, push, prune, logout", sub)
+		return fmt.Errorf("unknown registry subcommand %q — valid: login, push, prune, logout, search, info, install", sub)
 	}
 }
 
 func registryContainerUsage() error {
 	fmt.Fprintf(os.Stderr, `Usage: wfctl registry <subcommand> [options]
 
-Manage container registries declared in ci.registries[].
+Manage container registries declared in ci.registries[], and find and
+install plugins from the plugin marketplace.
 
 Subcommands:
   login   Authenticate to a container registry
@@ -46,6 +53,11 @@ Subcommands:
   prune   Garbage-collect and prune old tags
   logout  Remove stored registry credentials
 
+Plugin marketplace:
+  search  Search marketplace plugins (-category, -sort downloads|rating|name)
+  info    Show a marketplace plugin's details and versions
+  install Install a marketplace plugin
+
 Options:
   --config <file>   Config file (default: workflow.yaml)
   --registry <name> Registry name from ci.registries[] (default: all)
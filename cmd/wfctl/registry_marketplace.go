@@ -0,0 +1,385 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/module"
+	"github.com/GoCodeAlone/workflow/plugin"
+)
+
+// marketplaceURLEnv overrides the marketplace URL from the registry config.
+const marketplaceURLEnv = "WFCTL_MARKETPLACE_URL"
+
+// defaultMarketplaceCacheTTL is how long a cached marketplace catalog is used
+// before wfctl fetches it again.
+const defaultMarketplaceCacheTTL = time.Hour
+
+// marketplaceHTTPClient is used for marketplace catalog and install requests.
+// It is a package-level variable so tests can override it.
+var marketplaceHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// marketplaceCatalog is the marketplace plugin list as cached on disk.
+type marketplaceCatalog struct {
+	URL       string                    `json:"url"`
+	FetchedAt time.Time                 `json:"fetchedAt"`
+	Plugins   []module.MarketplaceEntry `json:"plugins"`
+}
+
+// marketplaceFlags are the flags shared by the registry search, info and
+// install subcommands.
+type marketplaceFlags struct {
+	url      string
+	config   string
+	refresh  bool
+	cacheTTL time.Duration
+}
+
+func addMarketplaceFlags(fs *flag.FlagSet) *marketplaceFlags {
+	f := &marketplaceFlags{}
+	fs.StringVar(&f.url, "url", "", "Marketplace base URL (default: $"+marketplaceURLEnv+" or marketplace.url in the registry config)")
+	fs.StringVar(&f.config, "config", "", "Registry config file path")
+	fs.BoolVar(&f.refresh, "refresh", false, "Fetch the catalog even if the local cache is fresh")
+	fs.DurationVar(&f.cacheTTL, "cache-ttl", defaultMarketplaceCacheTTL, "How long the cached catalog is used before it is fetched again")
+	return f
+}
+
+// baseURL resolves the marketplace URL from the -url flag, the environment,
+// then the registry config.
+func (f *marketplaceFlags) baseURL() (string, error) {
+	if f.url != "" {
+		return strings.TrimRight(f.url, "/"), nil
+	}
+	if env := os.Getenv(marketplaceURLEnv); env != "" {
+		return strings.TrimRight(env, "/"), nil
+	}
+	cfg, err := LoadRegistryConfig(f.config)
+	if err != nil {
+		return "", fmt.Errorf("load registry config: %w", err)
+	}
+	if cfg.Marketplace.URL != "" {
+		return strings.TrimRight(cfg.Marketplace.URL, "/"), nil
+	}
+	return "", fmt.Errorf("no marketplace configured: pass -url, set %s, or add marketplace.url to the registry config", marketplaceURLEnv)
+}
+
+// catalog returns the marketplace plugin list, from the local cache while it
+// is fresh. When the marketplace cannot be reached a stale cache is used
+// with a warning.
+func (f *marketplaceFlags) catalog(ctx context.Context) (*marketplaceCatalog, string, error) {
+	base, err := f.baseURL()
+	if err != nil {
+		return nil, "", err
+	}
+	cachePath := marketplaceCachePath(base)
+	cached, _ := readMarketplaceCache(cachePath)
+	if cached != nil && !f.refresh && time.Since(cached.FetchedAt) < f.cacheTTL {
+		return cached, base, nil
+	}
+
+	plugins, err := fetchMarketplaceCatalog(ctx, base)
+	if err != nil {
+		if cached == nil {
+			return nil, "", err
+		}
+		fmt.Fprintf(os.Stderr, "warning: %v; using catalog cached %s\n", err, cached.FetchedAt.Local().Format(time.RFC3339))
+		return cached, base, nil
+	}
+	catalog := &marketplaceCatalog{URL: base, FetchedAt: time.Now().UTC(), Plugins: plugins}
+	if err := writeMarketplaceCache(cachePath, catalog); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: cache marketplace catalog: %v\n", err)
+	}
+	return catalog, base, nil
+}
+
+func fetchMarketplaceCatalog(ctx context.Context, base string) ([]module.MarketplaceEntry, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"/api/v1/plugins?q=", nil)
+	if err != nil {
+		return nil, fmt.Errorf("create marketplace request: %w", err)
+	}
+	resp, err := marketplaceHTTPClient.Do(req) //nolint:gosec // G704: URL from configured marketplace
+	if err != nil {
+		return nil, fmt.Errorf("fetch marketplace catalog: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("marketplace returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	var plugins []module.MarketplaceEntry
+	if err := json.NewDecoder(resp.Body).Decode(&plugins); err != nil {
+		return nil, fmt.Errorf("decode marketplace catalog: %w", err)
+	}
+	return plugins, nil
+}
+
+// marketplaceCachePath returns the cache file for the marketplace at base,
+// under the user cache directory so each marketplace has its own catalog.
+func marketplaceCachePath(base string) string {
+	dir, err := os.UserCacheDir()
+	if err != nil || dir == "" {
+		dir = os.TempDir()
+	}
+	sum := sha256.Sum256([]byte(base))
+	return filepath.Join(dir, "wfctl", "marketplace", hex.EncodeToString(sum[:8])+".json")
+}
+
+func readMarketplaceCache(path string) (*marketplaceCatalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var catalog marketplaceCatalog
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		return nil, err
+	}
+	return &catalog, nil
+}
+
+func writeMarketplaceCache(path string, catalog *marketplaceCatalog) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(catalog, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// find returns the catalog entry named name.
+func (c *marketplaceCatalog) find(name string) (*module.MarketplaceEntry, bool) {
+	for i := range c.Plugins {
+		if c.Plugins[i].Name == name {
+			return &c.Plugins[i], true
+		}
+	}
+	return nil, false
+}
+
+// filterMarketplacePlugins returns the plugins matching query (in the name,
+// description or tags, case-insensitively) and category, sorted by sortBy:
+// downloads or rating (highest first), or name.
+func filterMarketplacePlugins(plugins []module.MarketplaceEntry, query, category, sortBy string) ([]module.MarketplaceEntry, error) {
+	query = strings.ToLower(query)
+	var out []module.MarketplaceEntry
+	for i := range plugins {
+		p := plugins[i]
+		if category != "" && !strings.EqualFold(p.Category, category) {
+			continue
+		}
+		if query != "" && !marketplaceEntryMatches(&p, query) {
+			continue
+		}
+		out = append(out, p)
+	}
+
+	var less func(a, b *module.MarketplaceEntry) bool
+	switch sortBy {
+	case "", "downloads":
+		less = func(a, b *module.MarketplaceEntry) bool { return a.Downloads > b.Downloads }
+	case "rating":
+		less = func(a, b *module.MarketplaceEntry) bool { return a.Rating > b.Rating }
+	case "name":
+		less = func(a, b *module.MarketplaceEntry) bool { return a.Name < b.Name }
+	default:
+		return nil, fmt.Errorf("unknown -sort %q (want downloads, rating, or name)", sortBy)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if less(&out[i], &out[j]) {
+			return true
+		}
+		if less(&out[j], &out[i]) {
+			return false
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out, nil
+}
+
+func marketplaceEntryMatches(p *module.MarketplaceEntry, query string) bool {
+	if strings.Contains(strings.ToLower(p.Name), query) || strings.Contains(strings.ToLower(p.Description), query) {
+		return true
+	}
+	for _, tag := range p.Tags {
+		if strings.EqualFold(tag, query) {
+			return true
+		}
+	}
+	return false
+}
+
+func runRegistrySearch(args []string) error {
+	fs := flag.NewFlagSet("registry search", flag.ContinueOnError)
+	mf := addMarketplaceFlags(fs)
+	category := fs.String("category", "", "Only show plugins in this category")
+	sortBy := fs.String("sort", "downloads", "Sort by downloads, rating, or name")
+	limit := fs.Int("limit", 0, "Show at most this many plugins (0 = all)")
+	jsonOut := fs.Bool("json", false, "Print results as JSON")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: wfctl registry search [options] [<term>]\n\nSearch the plugin marketplace by name, description, or tag.\n\nOptions:\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	catalog, _, err := mf.catalog(context.Background())
+	if err != nil {
+		return err
+	}
+	plugins, err := filterMarketplacePlugins(catalog.Plugins, strings.Join(fs.Args(), " "), *category, *sortBy)
+	if err != nil {
+		return err
+	}
+	if *limit > 0 && len(plugins) > *limit {
+		plugins = plugins[:*limit]
+	}
+	if *jsonOut {
+		if plugins == nil {
+			plugins = []module.MarketplaceEntry{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(plugins)
+	}
+	if len(plugins) == 0 {
+		fmt.Println("No plugins found.")
+		return nil
+	}
+	fmt.Print(formatMarketplaceResults(plugins))
+	return nil
+}
+
+// formatMarketplaceResults renders the wfctl registry search table.
+func formatMarketplaceResults(plugins []module.MarketplaceEntry) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-22s %-10s %-14s %10s %6s  %s\n", "NAME", "VERSION", "CATEGORY", "DOWNLOADS", "RATING", "DESCRIPTION")
+	fmt.Fprintf(&b, "%-22s %-10s %-14s %10s %6s  %s\n", "----", "-------", "--------", "---------", "------", "-----------")
+	for i := range plugins {
+		p := &plugins[i]
+		desc := p.Description
+		if len(desc) > 50 {
+			desc = desc[:47] + "..."
+		}
+		fmt.Fprintf(&b, "%-22s %-10s %-14s %10d %6.1f  %s\n", p.Name, p.Version, p.Category, p.Downloads, p.Rating, desc)
+	}
+	return b.String()
+}
+
+func runRegistryInfo(args []string) error {
+	if err := checkTrailingFlags(args); err != nil {
+		return err
+	}
+	fs := flag.NewFlagSet("registry info", flag.ContinueOnError)
+	mf := addMarketplaceFlags(fs)
+	jsonOut := fs.Bool("json", false, "Print the plugin as JSON")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: wfctl registry info [options] <plugin>\n\nShow marketplace details and available versions for a plugin.\n\nOptions:\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("plugin name is required")
+	}
+
+	ctx := context.Background()
+	catalog, base, err := mf.catalog(ctx)
+	if err != nil {
+		return err
+	}
+	entry, ok := catalog.find(fs.Arg(0))
+	if !ok {
+		return fmt.Errorf("plugin %q not found in the marketplace (try: wfctl registry search %s)", fs.Arg(0), fs.Arg(0))
+	}
+	remote := plugin.NewRemoteRegistry(base, plugin.WithHTTPClient(marketplaceHTTPClient))
+	versions, err := remote.ListVersions(ctx, entry.Name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		versions = []string{entry.Version}
+	}
+
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			module.MarketplaceEntry
+			Versions []string `json:"versions"`
+		}{*entry, versions})
+	}
+	fmt.Printf("Name:        %s\n", entry.Name)
+	fmt.Printf("Version:     %s\n", entry.Version)
+	fmt.Printf("Author:      %s\n", entry.Author)
+	fmt.Printf("Category:    %s\n", entry.Category)
+	fmt.Printf("Downloads:   %d\n", entry.Downloads)
+	fmt.Printf("Rating:      %.1f\n", entry.Rating)
+	if len(entry.Tags) > 0 {
+		fmt.Printf("Tags:        %s\n", strings.Join(entry.Tags, ", "))
+	}
+	fmt.Printf("Versions:    %s\n", strings.Join(versions, ", "))
+	fmt.Printf("Description: %s\n", entry.Description)
+	return nil
+}
+
+func runRegistryInstall(args []string) error {
+	if err := checkTrailingFlags(args); err != nil {
+		return err
+	}
+	fs := flag.NewFlagSet("registry install", flag.ContinueOnError)
+	mf := addMarketplaceFlags(fs)
+	var pluginDirVal string
+	var global bool
+	fs.StringVar(&pluginDirVal, "plugin-dir", defaultDataDir, "Plugin directory")
+	addGlobalPluginFlags(fs, &global)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: wfctl registry install [options] <plugin>[@<version>]\n\nInstall a plugin from the marketplace. Without a version the marketplace's\ncurrent version is installed.\n\nOptions:\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("plugin name is required")
+	}
+	name, version := parseNameVersion(fs.Arg(0))
+
+	ctx := context.Background()
+	catalog, base, err := mf.catalog(ctx)
+	if err != nil {
+		return err
+	}
+	entry, ok := catalog.find(name)
+	if !ok {
+		return fmt.Errorf("plugin %q not found in the marketplace (try: wfctl registry search %s)", name, name)
+	}
+	if version == "" {
+		version = entry.Version
+	}
+
+	pluginDir := resolvePluginDir(pluginDirVal, global)
+	installer := plugin.NewPluginInstaller(plugin.NewRemoteRegistry(base, plugin.WithHTTPClient(marketplaceHTTPClient)), nil, nil, pluginDir)
+	if installer.IsInstalled(name) {
+		fmt.Printf("%s is already installed in %s (remove it with: wfctl plugin remove %s)\n", name, pluginDir, name)
+		return nil
+	}
+	if err := installer.Install(ctx, name, version); err != nil {
+		return err
+	}
+	fmt.Printf("Installed %s@%s to %s\n", name, version, filepath.Join(pluginDir, name))
+	return nil
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/module"
+)
+
+var testMarketplacePlugins = []module.MarketplaceEntry{
+	{Name: "storage-s3", Version: "2.0.1", Description: "AWS S3 blob storage", Category: "storage", Tags: []string{"aws"}, Downloads: 8900, Rating: 4.6},
+	{Name: "storage-gcs", Version: "1.0.0", Description: "Google Cloud Storage", Category: "storage", Tags: []string{"gcp"}, Downloads: 1200, Rating: 4.9},
+	{Name: "auth-oidc", Version: "1.2.0", Description: "OpenID Connect auth", Category: "auth", Downloads: 4200, Rating: 4.8},
+}
+
+// newTestMarketplace serves the catalog, versions, manifests and downloads
+// for testMarketplacePlugins and counts catalog requests.
+func newTestMarketplace(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var catalogHits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v1/plugins":
+			catalogHits.Add(1)
+			_ = json.NewEncoder(w).Encode(testMarketplacePlugins)
+		case r.URL.Path == "/api/v1/plugins/storage-s3/versions":
+			_ = json.NewEncoder(w).Encode([]string{"2.0.1", "2.0.0"})
+		case strings.HasSuffix(r.URL.Path, "/download"):
+			_, _ = w.Write([]byte("not a real archive"))
+		case strings.HasPrefix(r.URL.Path, "/api/v1/plugins/storage-s3/versions/"):
+			version := strings.TrimPrefix(r.URL.Path, "/api/v1/plugins/storage-s3/versions/")
+			_ = json.NewEncoder(w).Encode(map[string]string{"name": "storage-s3", "version": version, "author": "test", "description": "AWS S3 blob storage"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &catalogHits
+}
+
+func TestFilterMarketplacePlugins(t *testing.T) {
+	tests := []struct {
+		query, category, sortBy string
+		want                    []string
+	}{
+		{"", "", "downloads", []string{"storage-s3", "auth-oidc", "storage-gcs"}},
+		{"", "", "rating", []string{"storage-gcs", "auth-oidc", "storage-s3"}},
+		{"", "storage", "name", []string{"storage-gcs", "storage-s3"}},
+		{"GCP", "", "", []string{"storage-gcs"}},
+		{"connect", "", "", []string{"auth-oidc"}},
+	}
+	for _, tt := range tests {
+		got, err := filterMarketplacePlugins(testMarketplacePlugins, tt.query, tt.category, tt.sortBy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, p := range got {
+			names = append(names, p.Name)
+		}
+		if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("filter(%q, %q, %q) = %v, want %v", tt.query, tt.category, tt.sortBy, names, tt.want)
+		}
+	}
+	if _, err := filterMarketplacePlugins(testMarketplacePlugins, "", "", "stars"); err == nil {
+		t.Error("expected an error for an unknown sort")
+	}
+}
+
+func TestMarketplaceCatalogCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	srv, hits := newTestMarketplace(t)
+	mf := &marketplaceFlags{url: srv.URL, cacheTTL: time.Hour}
+
+	for range 2 {
+		catalog, base, err := mf.catalog(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if base != srv.URL || len(catalog.Plugins) != len(testMarketplacePlugins) {
+			t.Fatalf("catalog = %+v from %s", catalog, base)
+		}
+	}
+	if got := hits.Load(); got != 1 {
+		t.Errorf("catalog fetched %d times, want 1 (second read from cache)", got)
+	}
+
+	mf.refresh = true
+	if _, _, err := mf.catalog(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := hits.Load(); got != 2 {
+		t.Errorf("catalog fetched %d times after -refresh, want 2", got)
+	}
+
+	// An unreachable marketplace falls back to the stale cache.
+	srv.Close()
+	catalog, _, err := mf.catalog(context.Background())
+	if err != nil {
+		t.Fatalf("expected stale cache fallback, got %v", err)
+	}
+	if _, ok := catalog.find("auth-oidc"); !ok {
+		t.Error("stale catalog is missing auth-oidc")
+	}
+}
+
+func TestMarketplaceURLRequired(t *testing.T) {
+	t.Setenv(marketplaceURLEnv, "")
+	t.Chdir(t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	err := runRegistrySearch([]string{"s3"})
+	if err == nil || !strings.Contains(err.Error(), "no marketplace configured") {
+		t.Fatalf("err = %v, want no marketplace configured", err)
+	}
+}
+
+func TestRunRegistryInstall(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	srv, _ := newTestMarketplace(t)
+	t.Setenv(marketplaceURLEnv, srv.URL)
+	dir := t.TempDir()
+
+	if err := runRegistryInstall([]string{"-plugin-dir", dir, "storage-s3"}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "storage-s3", "plugin.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"version": "2.0.1"`) {
+		t.Errorf("plugin.json = %s, want the marketplace's current version", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "storage-s3", "storage-s3-2.0.1.tar.gz")); err != nil {
+		t.Errorf("archive not saved: %v", err)
+	}
+
+	if err := runRegistryInstall([]string{"-plugin-dir", dir, "missing"}); err == nil || !strings.Contains(err.Error(), "not found in the marketplace") {
+		t.Errorf("err = %v, want not found", err)
+	}
+}
+
+func TestRunRegistryInfo(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	srv, _ := newTestMarketplace(t)
+	if err := runRegistryInfo([]string{"-url", srv.URL, "storage-s3"}); err != nil {
+		t.Fatal(err)
+	}
+}
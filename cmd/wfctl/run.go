@@ -20,8 +20,11 @@ func runRun(args []string) error {
 	fs := flag.NewFlagSet("run", flag.ExitOnError)
 	logLevel := fs.String("log-level", "info", "Log level (debug, info, warn, error)")
 	env := fs.String("env", "", "Environment name (sets WORKFLOW_ENV)")
+	mock := fs.Bool("mock", false, "Serve the HTTP routes from their declared examples instead of running the engine")
+	addr := fs.String("addr", "", "Listen address of the mock server (default: the http.server module address, or :8080)")
+	strict := fs.Bool("strict", false, "With -mock, fail when a route example does not match its schema")
 	fs.Usage = func() {
-		fmt.Fprintf(fs.Output(), "Usage: wfctl run [options] <config.yaml>\n\nRun a workflow engine from a config file.\n\nOptions:\n")
+		fmt.Fprintf(fs.Output(), "Usage: wfctl run [options] <config.yaml>\n\nRun a workflow engine from a config file. With -mock, serve the config's\nHTTP routes from their examples instead.\n\nOptions:\n")
 		fs.PrintDefaults()
 	}
 	if err := fs.Parse(args); err != nil {
@@ -41,6 +44,9 @@ func runRun(args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
+	if *mock {
+		return runMock(fs.Arg(0), cfg, *addr, *strict)
+	}
 
 	var level slog.Level
 	switch *logLevel {
@@ -63,6 +69,7 @@ func runRun(args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to build workflow: %w", err)
 	}
+	logger.Info("Workflow engine built", engine.VersionReport().LogArgs()...)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -0,0 +1,328 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/config"
+	"github.com/GoCodeAlone/workflow/module"
+)
+
+// mockExampleHeader names the example a mock response was served from.
+const mockExampleHeader = "X-Mock-Example"
+
+// runMock serves the HTTP routes of cfg from their declared examples, without
+// building the engine. Routes without examples answer with data synthesized
+// from their response schema.
+func runMock(cfgPath string, cfg *config.WorkflowConfig, addr string, strict bool) error {
+	gen := buildConfigOpenAPIGenerator(cfg, module.OpenAPIGeneratorConfig{Title: "mock"}, true)
+	if err := reportRouteExamples(os.Stderr, cfgPath, gen, strict); err != nil {
+		return err
+	}
+	handler := newMockHandler(gen)
+
+	if addr == "" {
+		addr = mockAddress(cfg)
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", addr, err)
+	}
+	srv := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	go func() { _ = srv.Serve(ln) }()
+	fmt.Printf("Mock server listening on http://%s. Press Ctrl+C to stop.\n", ln.Addr())
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	<-ctx.Done()
+	stop()
+	fmt.Println("Shutting down...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return srv.Shutdown(shutdownCtx)
+}
+
+// mockAddress returns the address of the config's first http.server module,
+// or ":8080".
+func mockAddress(cfg *config.WorkflowConfig) string {
+	for _, m := range cfg.Modules {
+		if m.Type != "http.server" {
+			continue
+		}
+		if addr, ok := m.Config["address"].(string); ok && addr != "" {
+			return addr
+		}
+	}
+	return ":8080"
+}
+
+// newMockHandler returns a handler serving every operation of gen's spec.
+func newMockHandler(gen *module.OpenAPIGenerator) http.Handler {
+	mux := http.NewServeMux()
+	spec := configOpenAPISpec(gen, module.OpenAPIGeneratorConfig{})
+	for _, so := range specOperations(spec) {
+		route := &mockRoute{
+			op:       so.op,
+			spec:     spec,
+			examples: normalizeMockExamples(gen.RouteExamples(so.method, so.path)),
+		}
+		if err := handleRoutePattern(mux, so.method+" "+so.path, route); err != nil {
+			fmt.Fprintf(os.Stderr, "  WARN %s %s: not mocked: %v\n", so.method, so.path, err)
+		}
+	}
+	return mux
+}
+
+// mockRoute serves one operation.
+type mockRoute struct {
+	op       *module.OpenAPIOperation
+	spec     *module.OpenAPISpec
+	examples []config.RouteExample
+}
+
+// normalizeMockExamples round-trips the example bodies through JSON so that
+// they compare equal to decoded request bodies.
+func normalizeMockExamples(examples []config.RouteExample) []config.RouteExample {
+	out := make([]config.RouteExample, len(examples))
+	for i, ex := range examples {
+		if ex.Request != nil {
+			req := *ex.Request
+			req.Body = jsonRoundTrip(req.Body)
+			ex.Request = &req
+		}
+		ex.Response.Body = jsonRoundTrip(ex.Response.Body)
+		out[i] = ex
+	}
+	return out
+}
+
+func jsonRoundTrip(v any) any {
+	if v == nil {
+		return nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out any
+	if err := json.Unmarshal(b, &out); err != nil {
+		return v
+	}
+	return out
+}
+
+// ServeHTTP answers with an example of the route or, when the route has
+// none, with a synthesized response.
+//
+// An example is selected by name with "Prefer: example=<name>", by status
+// with "Prefer: code=<status>", or else as the first example whose request
+// matches: its query and headers are present with the same values and its
+// body, if any, equals the request body. Examples without a request match
+// any request; when none matches, the first example is served.
+func (m *mockRoute) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	prefer := parsePrefer(r.Header.Get("Prefer"))
+	if name, ok := prefer["example"]; ok {
+		for _, ex := range m.examples {
+			if ex.Name == name {
+				writeMockExample(w, ex)
+				return
+			}
+		}
+		writeMockError(w, http.StatusNotFound, fmt.Sprintf("no example named %q", name))
+		return
+	}
+	if code, ok := prefer["code"]; ok {
+		status, err := strconv.Atoi(code)
+		if err != nil {
+			writeMockError(w, http.StatusBadRequest, fmt.Sprintf("invalid Prefer code %q", code))
+			return
+		}
+		for _, ex := range m.examples {
+			if ex.Response.Status == status {
+				writeMockExample(w, ex)
+				return
+			}
+		}
+		m.writeSynthesized(w, strconv.Itoa(status))
+		return
+	}
+
+	if len(m.examples) > 0 {
+		var body any
+		if r.Body != nil {
+			b, _ := io.ReadAll(io.LimitReader(r.Body, maxRecordedBody))
+			body = decodeExampleBody(b)
+		}
+		for _, ex := range m.examples {
+			if mockRequestMatches(ex.Request, r, body) {
+				writeMockExample(w, ex)
+				return
+			}
+		}
+		writeMockExample(w, m.examples[0])
+		return
+	}
+	m.writeSynthesized(w, "")
+}
+
+// parsePrefer parses the key=value preferences of a Prefer header.
+func parsePrefer(header string) map[string]string {
+	out := map[string]string{}
+	for _, part := range strings.FieldsFunc(header, func(r rune) bool { return r == ',' || r == ';' }) {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if ok {
+			out[strings.ToLower(strings.TrimSpace(k))] = strings.Trim(strings.TrimSpace(v), `"`)
+		}
+	}
+	return out
+}
+
+func mockRequestMatches(req *config.RouteExampleRequest, r *http.Request, body any) bool {
+	if req == nil {
+		return true
+	}
+	q := r.URL.Query()
+	for k, v := range req.Query {
+		if q.Get(k) != v {
+			return false
+		}
+	}
+	for k, v := range req.Headers {
+		if r.Header.Get(k) != v {
+			return false
+		}
+	}
+	return req.Body == nil || reflect.DeepEqual(req.Body, body)
+}
+
+func writeMockExample(w http.ResponseWriter, ex config.RouteExample) {
+	for k, v := range ex.Response.Headers {
+		w.Header().Set(k, v)
+	}
+	w.Header().Set(mockExampleHeader, ex.Name)
+	writeMockBody(w, ex.Response.Status, ex.Response.Body)
+}
+
+func writeMockError(w http.ResponseWriter, status int, msg string) {
+	writeMockBody(w, status, map[string]string{"error": msg})
+}
+
+func writeMockBody(w http.ResponseWriter, status int, body any) {
+	if body == nil {
+		w.WriteHeader(status)
+		return
+	}
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// writeSynthesized answers with the response of code, or the operation's
+// lowest 2xx response when code is empty, using its example when it has one
+// and otherwise a value built from its schema.
+func (m *mockRoute) writeSynthesized(w http.ResponseWriter, code string) {
+	if code == "" {
+		code = "200"
+		var codes []string
+		for c := range m.op.Responses {
+			if strings.HasPrefix(c, "2") {
+				codes = append(codes, c)
+			}
+		}
+		if len(codes) > 0 {
+			sort.Strings(codes)
+			code = codes[0]
+		}
+	}
+	status, _ := strconv.Atoi(code)
+	resp := m.op.Responses[code]
+	if resp == nil {
+		w.WriteHeader(status)
+		return
+	}
+	var media *module.OpenAPIMediaType
+	contentType := "application/json"
+	if media = resp.Content[contentType]; media == nil {
+		for ct, mt := range resp.Content {
+			contentType, media = ct, mt
+			break
+		}
+	}
+	if media == nil {
+		w.WriteHeader(status)
+		return
+	}
+	var body any
+	switch {
+	case media.Example != nil:
+		body = media.Example
+	case len(media.Examples) > 0:
+		names := make([]string, 0, len(media.Examples))
+		for n := range media.Examples {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		body = media.Examples[names[0]].Value
+	default:
+		body = m.mockValue(media.Schema, 0)
+	}
+	w.Header().Set("Content-Type", contentType)
+	writeMockBody(w, status, body)
+}
+
+// mockValue builds a placeholder value that satisfies schema.
+func (m *mockRoute) mockValue(schema *module.OpenAPISchema, depth int) any {
+	if schema != nil && schema.Ref != "" && m.spec.Components != nil {
+		schema = m.spec.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+	}
+	if schema == nil || depth > 8 {
+		return map[string]any{}
+	}
+	if schema.Example != nil {
+		return schema.Example
+	}
+	if len(schema.Enum) > 0 {
+		return schema.Enum[0]
+	}
+	switch schema.Type {
+	case "string":
+		switch schema.Format {
+		case "date-time":
+			return "2024-01-01T00:00:00Z"
+		case "date":
+			return "2024-01-01"
+		case "email":
+			return "user@example.com"
+		case "uuid":
+			return "00000000-0000-0000-0000-000000000000"
+		case "uri", "url":
+			return "https://example.com"
+		}
+		return "string"
+	case "integer", "number":
+		return 0
+	case "boolean":
+		return false
+	case "array":
+		return []any{m.mockValue(schema.Items, depth+1)}
+	default:
+		out := make(map[string]any, len(schema.Properties))
+		for name, prop := range schema.Properties {
+			out[name] = m.mockValue(prop, depth+1)
+		}
+		return out
+	}
+}
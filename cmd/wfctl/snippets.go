@@ -11,7 +11,7 @@ import (
 
 func runSnippets(args []string) error {
 	fs := flag.NewFlagSet("snippets", flag.ExitOnError)
-	format := fs.String("format", "json", "Output format: json, vscode, jetbrains")
+	format := fs.String("format", "json", "Output format: json, vscode, jetbrains, lsp, nvim")
 	output := fs.String("output", "", "Write output to file instead of stdout")
 	fs.Usage = func() {
 		fmt.Fprintf(fs.Output(), `Usage: wfctl snippets [options]
@@ -26,10 +26,16 @@ Formats:
   json        Raw snippet list as JSON (default)
   vscode      VSCode .code-snippets JSON format
   jetbrains   JetBrains live templates XML format
+  lsp         Snippet set served by workflow-lsp-server, including a snippet
+              for every module and step type (load an edited copy with
+              workflow-lsp-server --snippets)
+  nvim        The lsp snippet set as a LuaSnip Lua file for Neovim
 
 Examples:
   wfctl snippets --format vscode --output workflow.code-snippets
   wfctl snippets --format jetbrains --output workflow.xml
+  wfctl snippets --format lsp --output workflow-snippets.json
+  wfctl snippets --format nvim --output ~/.config/nvim/lua/workflow_snippets.lua
 `)
 	}
 	if err := fs.Parse(args); err != nil {
@@ -50,6 +56,16 @@ Examples:
 		if err != nil {
 			return fmt.Errorf("jetbrains export failed: %w", err)
 		}
+	case "lsp":
+		data, err = schema.ExportSnippetsLSP()
+		if err != nil {
+			return fmt.Errorf("lsp export failed: %w", err)
+		}
+	case "nvim":
+		data, err = schema.ExportSnippetsNeovim()
+		if err != nil {
+			return fmt.Errorf("nvim export failed: %w", err)
+		}
 	case "json", "":
 		snips := schema.GetSnippets()
 		data, err = json.MarshalIndent(snips, "", "  ")
@@ -57,7 +73,7 @@ Examples:
 			return fmt.Errorf("json export failed: %w", err)
 		}
 	default:
-		return fmt.Errorf("unknown format %q; choose json, vscode, or jetbrains", *format)
+		return fmt.Errorf("unknown format %q; choose json, vscode, jetbrains, lsp, or nvim", *format)
 	}
 
 	w := os.Stdout
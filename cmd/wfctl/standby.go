@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/replication"
+)
+
+// runStandby is the wfctl standby command dispatcher. Its subcommands talk to
+// a server's replication API (the workflow server's -replication-addr).
+func runStandby(args []string) error {
+	if len(args) < 1 {
+		fmt.Fprintf(flag.CommandLine.Output(), `Usage: wfctl standby <subcommand> [options]
+
+Inspect and promote warm standby servers.
+
+Subcommands:
+  status   Show a server's replication role, readiness and lag
+  promote  Promote a standby to primary once the primary's lease is free
+
+`)
+		return fmt.Errorf("missing or unknown subcommand")
+	}
+	switch args[0] {
+	case "status":
+		return runStandbyStatus(args[1:])
+	case "promote":
+		return runStandbyPromote(args[1:])
+	default:
+		return fmt.Errorf("unknown wfctl standby subcommand %q (available: status, promote)", args[0])
+	}
+}
+
+// standbyServerFlags registers the --server and --token flags of the standby
+// subcommands.
+func standbyServerFlags(fs *flag.FlagSet) (server, token *string) {
+	server = fs.String("server", envDefault("WFCTL_REPLICATION_SERVER", "http://localhost:8090"), "Replication API base URL of the server (or WFCTL_REPLICATION_SERVER)")
+	token = fs.String("token", os.Getenv("WORKFLOW_REPLICATION_TOKEN"), "Replication token (or WORKFLOW_REPLICATION_TOKEN)")
+	return server, token
+}
+
+func runStandbyStatus(args []string) error {
+	fs := flag.NewFlagSet("standby status", flag.ContinueOnError)
+	server, token := standbyServerFlags(fs)
+	format := fs.String("format", "text", "Output format: text or json")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), `Usage: wfctl standby status [options]
+
+Show a server's replication role, whether it is the ready primary, and for a
+standby how far behind the primary each replicated store is.
+
+Options:
+`)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var st replication.Status
+	if _, err := configAPIRequest(*server, *token, http.MethodGet, "/api/v1/replication/status", "", nil, &st); err != nil {
+		return fmt.Errorf("replication status: %w", err)
+	}
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(st)
+	}
+	printReplicationStatus(os.Stdout, st)
+	return nil
+}
+
+// runStandbyPromote promotes a standby. The server answers once its engines
+// have started, or with a conflict while the old primary still holds its
+// lease.
+func runStandbyPromote(args []string) error {
+	fs := flag.NewFlagSet("standby promote", flag.ContinueOnError)
+	server, token := standbyServerFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), `Usage: wfctl standby promote [options]
+
+Promote a standby to primary. The standby runs a final sync, claims the
+primary, scheduler and retention leases and starts its engines. Promotion is
+refused while another server holds the primary lease.
+
+Options:
+`)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var st replication.Status
+	if _, err := configAPIRequest(*server, *token, http.MethodPost, "/api/v1/replication/promote", "", nil, &st); err != nil {
+		return fmt.Errorf("promote: %w", err)
+	}
+	fmt.Printf("Promoted to primary at %s.\n", st.PromotedAt.Local().Format(time.RFC3339))
+	printReplicationStatus(os.Stdout, st)
+	return nil
+}
+
+func printReplicationStatus(w io.Writer, st replication.Status) {
+	fmt.Fprintf(w, "Role:     %s\n", st.Role)
+	fmt.Fprintf(w, "State:    %s\n", st.State)
+	fmt.Fprintf(w, "Ready:    %t\n", st.Ready)
+	if st.Summary != "" {
+		fmt.Fprintf(w, "Summary:  %s\n", st.Summary)
+	}
+	if st.PrimaryURL != "" {
+		fmt.Fprintf(w, "Primary:  %s\n", st.PrimaryURL)
+	}
+	if len(st.Leases) > 0 {
+		fmt.Fprintf(w, "Leases:   %v\n", st.Leases)
+	}
+	if st.Error != "" {
+		fmt.Fprintf(w, "Error:    %s\n", st.Error)
+	}
+	if len(st.Stores) == 0 {
+		return
+	}
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STORE\tSYNCED\tBYTES\tFAILURES\tERROR")
+	for _, s := range st.Stores {
+		synced := "never"
+		if !s.SyncedAt.IsZero() {
+			synced = s.SyncedAt.Local().Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%s\n", s.Store, synced, s.Bytes, s.Failures, s.Error)
+	}
+	_ = tw.Flush()
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/replication"
+)
+
+func TestStandbyStatusAndPromote(t *testing.T) {
+	var gotAuth []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = append(gotAuth, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/replication/status":
+			_ = json.NewEncoder(w).Encode(replication.Status{Role: replication.RoleStandby, State: replication.StateInSync, Summary: "standby, in sync, lag=2s"})
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/replication/promote":
+			w.WriteHeader(http.StatusConflict)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "lease held by another server"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	t.Setenv("WORKFLOW_REPLICATION_TOKEN", "secret")
+	if err := runStandby([]string{"status", "--server", srv.URL}); err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if gotAuth[0] != "Bearer secret" {
+		t.Errorf("expected WORKFLOW_REPLICATION_TOKEN to be used, got %q", gotAuth[0])
+	}
+	err := runStandby([]string{"promote", "--server", srv.URL})
+	if err == nil || !strings.Contains(err.Error(), "lease held") {
+		t.Fatalf("expected lease conflict from server, got %v", err)
+	}
+}
+
+func TestPrintReplicationStatus(t *testing.T) {
+	var b strings.Builder
+	printReplicationStatus(&b, replication.Status{
+		Role:    replication.RoleStandby,
+		State:   replication.StateStale,
+		Summary: "standby, stale, not yet synced",
+		Stores: []replication.StoreStatus{
+			{Store: "events", Failures: 3, Error: "primary returned 503"},
+			{Store: "workflows", SyncedAt: time.Now(), Bytes: 4096},
+		},
+	})
+	out := b.String()
+	for _, want := range []string{"Role:     standby", "standby, stale, not yet synced", "never", "primary returned 503", "4096"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -53,7 +53,7 @@ func KnownModuleTypes() map[string]ModuleTypeInfo {
 			Type:       "database.workflow",
 			Plugin:     "storage",
 			Stateful:   true,
-			ConfigKeys: []string{"driver", "dsn", "maxOpenConns", "maxIdleConns"},
+			ConfigKeys: []string{"driver", "dsn", "maxOpenConns", "maxIdleConns", "connMaxLifetime", "connMaxIdleTime", "replicas", "replicaHealthInterval"},
 		},
 		"database.partitioned": {
 			Type:       "database.partitioned",
@@ -61,6 +61,12 @@ func KnownModuleTypes() map[string]ModuleTypeInfo {
 			Stateful:   true,
 			ConfigKeys: []string{"driver", "dsn", "partitionKey", "tables", "partitionType", "partitionNameFormat", "sourceTable", "sourceColumn", "maxOpenConns", "maxIdleConns"},
 		},
+		"database.query_insights": {
+			Type:       "database.query_insights",
+			Plugin:     "storage",
+			Stateful:   false,
+			ConfigKeys: []string{"databases", "sampleRate", "slowThreshold", "slowSampleRate", "maxSlowQueries", "explain", "retention", "maxStatements", "redactPatterns"},
+		},
 		"persistence.store": {
 			Type:       "persistence.store",
 			Plugin:     "storage",
@@ -87,7 +93,7 @@ func KnownModuleTypes() map[string]ModuleTypeInfo {
 			Type:       "http.server",
 			Plugin:     "http",
 			Stateful:   false,
-			ConfigKeys: []string{"address", "port", "readTimeout", "writeTimeout", "idleTimeout"},
+			ConfigKeys: []string{"address", "port", "readTimeout", "writeTimeout", "idleTimeout", "tls", "clientAuth", "maxRequestBytes", "maxResponseBytes", "maxInFlight", "maxQueued", "queueTimeout", "retryAfter"},
 		},
 		"http.client": {
 			Type:       "http.client",
@@ -167,19 +173,31 @@ func KnownModuleTypes() map[string]ModuleTypeInfo {
 			Stateful:   false,
 			ConfigKeys: []string{},
 		},
+		"http.middleware.clientcert": {
+			Type:       "http.middleware.clientcert",
+			Plugin:     "http",
+			Stateful:   false,
+			ConfigKeys: []string{"caFile", "crlFile", "reloadInterval", "rules"},
+		},
+		"http.session": {
+			Type:       "http.session",
+			Plugin:     "http",
+			Stateful:   true,
+			ConfigKeys: []string{"store", "secret", "dbPath", "cache", "cookie", "idleTimeout", "absoluteTimeout", "rolling", "cleanupInterval"},
+		},
 
 		// auth plugin
 		"auth.jwt": {
 			Type:       "auth.jwt",
 			Plugin:     "auth",
 			Stateful:   false,
-			ConfigKeys: []string{"secret", "tokenExpiry", "issuer", "seedFile", "responseFormat"},
+			ConfigKeys: []string{"secret", "tokenExpiry", "issuer", "seedFile", "responseFormat", "allowRegistration", "passwordHashing", "passwordPolicy"},
 		},
 		"auth.user-store": {
 			Type:       "auth.user-store",
 			Plugin:     "auth",
 			Stateful:   true,
-			ConfigKeys: []string{},
+			ConfigKeys: []string{"passwordHashing"},
 		},
 		"auth.oauth2": {
 			Type:       "auth.oauth2",
@@ -237,6 +255,18 @@ func KnownModuleTypes() map[string]ModuleTypeInfo {
 			Stateful:   false,
 			ConfigKeys: []string{"brokers", "groupId"},
 		},
+		"messaging.topic_registry": {
+			Type:       "messaging.topic_registry",
+			Plugin:     "messaging",
+			Stateful:   false,
+			ConfigKeys: []string{"topics", "onInvalidPublish", "onInvalidDelivery", "dlq"},
+		},
+		"jobqueue.service": {
+			Type:       "jobqueue.service",
+			Plugin:     "messaging",
+			Stateful:   true,
+			ConfigKeys: []string{"backend", "storage", "dbPath", "workers", "pollInterval", "maxAttempts", "retryBackoff", "jobTimeout"},
+		},
 		"notification.slack": {
 			Type:       "notification.slack",
 			Plugin:     "messaging",
@@ -289,6 +319,12 @@ func KnownModuleTypes() map[string]ModuleTypeInfo {
 			Stateful:   false,
 			ConfigKeys: []string{"distribution", "topology", "signals", "receivers", "processors", "exporters", "routes"},
 		},
+		"alerting.rules": {
+			Type:       "alerting.rules",
+			Plugin:     "observability",
+			Stateful:   true,
+			ConfigKeys: []string{"eventStore", "interval", "repeatInterval", "dbPath", "path", "router", "rules", "silences"},
+		},
 		"health.checker": {
 			Type:       "health.checker",
 			Plugin:     "observability",
@@ -299,7 +335,7 @@ func KnownModuleTypes() map[string]ModuleTypeInfo {
 			Type:       "log.collector",
 			Plugin:     "observability",
 			Stateful:   false,
-			ConfigKeys: []string{"logLevel", "outputFormat", "retentionDays"},
+			ConfigKeys: []string{"logLevel", "outputFormat", "retentionDays", "storage", "maxEntries", "directory", "maxSegmentBytes", "maxSegmentEntries", "maxSegmentAge", "diskQuotaBytes"},
 		},
 		"observability.otel": {
 			Type:       "observability.otel",
@@ -313,6 +349,12 @@ func KnownModuleTypes() map[string]ModuleTypeInfo {
 			Stateful:   false,
 			ConfigKeys: []string{"title", "version", "description", "servers"},
 		},
+		"openapi.consumer": {
+			Type:       "openapi.consumer",
+			Plugin:     "observability",
+			Stateful:   false,
+			ConfigKeys: []string{"specUrl", "specFile", "fieldMapping", "auth"},
+		},
 		"http.middleware.otel": {
 			Type:       "http.middleware.otel",
 			Plugin:     "observability",
@@ -325,7 +367,7 @@ func KnownModuleTypes() map[string]ModuleTypeInfo {
 			Type:       "api.query",
 			Plugin:     "api",
 			Stateful:   false,
-			ConfigKeys: []string{"delegate", "routes"},
+			ConfigKeys: []string{"delegate", "routes", "recordExamples"},
 		},
 		"api.command": {
 			Type:       "api.command",
@@ -371,6 +413,12 @@ func KnownModuleTypes() map[string]ModuleTypeInfo {
 			Stateful:   false,
 			ConfigKeys: []string{"remote_runners", "secrets_provider"},
 		},
+		"schema.registry": {
+			Type:       "schema.registry",
+			Plugin:     "pipelinesteps",
+			Stateful:   false,
+			ConfigKeys: []string{"schemas", "dir"},
+		},
 
 		// secrets plugin
 		"secrets.vault": {
@@ -393,6 +441,12 @@ func KnownModuleTypes() map[string]ModuleTypeInfo {
 		},
 
 		// ai plugin
+		"ai.pricing": {
+			Type:       "ai.pricing",
+			Plugin:     "ai",
+			Stateful:   false,
+			ConfigKeys: []string{"models"},
+		},
 		"dynamic.component": {
 			Type:       "dynamic.component",
 			Plugin:     "ai",
@@ -405,7 +459,7 @@ func KnownModuleTypes() map[string]ModuleTypeInfo {
 			Type:       "featureflag.service",
 			Plugin:     "featureflags",
 			Stateful:   true,
-			ConfigKeys: []string{"provider", "cache_ttl", "sse_enabled", "db_path"},
+			ConfigKeys: []string{"provider", "cache_ttl", "sse_enabled", "db_path", "admin_url", "engine_name"},
 		},
 
 		// eventstore plugin
@@ -413,7 +467,13 @@ func KnownModuleTypes() map[string]ModuleTypeInfo {
 			Type:       "eventstore.service",
 			Plugin:     "eventstore",
 			Stateful:   true,
-			ConfigKeys: []string{"db_path", "retention_days"},
+			ConfigKeys: []string{"db_path", "retention_days", "tiering"},
+		},
+		"eventstore.projection": {
+			Type:       "eventstore.projection",
+			Plugin:     "eventstore",
+			Stateful:   true,
+			ConfigKeys: []string{"event_store", "database", "table", "key", "columns", "events", "pipelines", "steps", "component", "poll_interval", "batch_size"},
 		},
 
 		// dlq plugin
@@ -457,7 +517,7 @@ func KnownModuleTypes() map[string]ModuleTypeInfo {
 			Type:       "nosql.dynamodb",
 			Plugin:     "datastores",
 			Stateful:   false,
-			ConfigKeys: []string{"tableName", "region", "endpoint", "credentials"},
+			ConfigKeys: []string{"tableName", "region", "endpoint", "credentials", "partitionKey", "sortKey", "keySeparator"},
 		},
 		"nosql.mongodb": {
 			Type:       "nosql.mongodb",
@@ -496,13 +556,19 @@ func KnownModuleTypes() map[string]ModuleTypeInfo {
 			ConfigKeys: []string{"account", "region", "service_role", "compute_type", "image", "source_type"},
 		},
 
-		// policy plugin (OPA and Cedar are external plugins: workflow-plugin-policy-opa, workflow-plugin-policy-cedar)
+		// policy plugin (Cedar is an external plugin: workflow-plugin-policy-cedar)
 		"policy.mock": {
 			Type:       "policy.mock",
 			Plugin:     "policy",
 			Stateful:   false,
 			ConfigKeys: []string{"policies"},
 		},
+		"policy.service": {
+			Type:       "policy.service",
+			Plugin:     "policy",
+			Stateful:   false,
+			ConfigKeys: []string{"policies", "data", "dataFile", "decision"},
+		},
 
 		// observability plugin (tracing)
 		"tracing.propagation": {
@@ -680,18 +746,23 @@ func KnownStepTypes() map[string]StepTypeInfo {
 		"step.validate": {
 			Type:       "step.validate",
 			Plugin:     "pipelinesteps",
-			ConfigKeys: []string{"rules", "required", "schema"},
+			ConfigKeys: []string{"rules", "required", "schema", "constraints"},
 		},
 		"step.transform": {
 			Type:       "step.transform",
 			Plugin:     "pipelinesteps",
-			ConfigKeys: []string{"mapping", "template"},
+			ConfigKeys: []string{"transformer", "pipeline", "operations"},
 		},
 		"step.conditional": {
 			Type:       "step.conditional",
 			Plugin:     "pipelinesteps",
 			ConfigKeys: []string{"field", "routes", "default", "if", "then", "else"},
 		},
+		"step.assert": {
+			Type:       "step.assert",
+			Plugin:     "pipelinesteps",
+			ConfigKeys: []string{"assertions", "status", "warn_only"},
+		},
 		"step.set": {
 			Type:       "step.set",
 			Plugin:     "pipelinesteps",
@@ -702,6 +773,11 @@ func KnownStepTypes() map[string]StepTypeInfo {
 			Plugin:     "pipelinesteps",
 			ConfigKeys: []string{"message", "level"},
 		},
+		"step.audit": {
+			Type:       "step.audit",
+			Plugin:     "pipelinesteps",
+			ConfigKeys: []string{"action", "resource", "actor", "type", "detail", "details", "success", "store"},
+		},
 		"step.cli_print": {
 			Type:       "step.cli_print",
 			Plugin:     "pipelinesteps",
@@ -715,7 +791,12 @@ func KnownStepTypes() map[string]StepTypeInfo {
 		"step.delegate": {
 			Type:       "step.delegate",
 			Plugin:     "pipelinesteps",
-			ConfigKeys: []string{"service", "action"},
+			ConfigKeys: []string{"service", "action", "capture", "body", "body_from", "headers"},
+		},
+		"step.service_call": {
+			Type:       "step.service_call",
+			Plugin:     "pipelinesteps",
+			ConfigKeys: []string{"service", "method", "path", "body", "body_from", "headers", "forward_headers", "error_on_status", "max_depth"},
 		},
 		"step.jq": {
 			Type:       "step.jq",
@@ -727,6 +808,11 @@ func KnownStepTypes() map[string]StepTypeInfo {
 			Plugin:     "pipelinesteps",
 			ConfigKeys: []string{"topic", "broker", "payload"},
 		},
+		"step.enqueue": {
+			Type:       "step.enqueue",
+			Plugin:     "pipelinesteps",
+			ConfigKeys: []string{"pipeline", "queue", "data", "delay", "priority"},
+		},
 		"step.event_publish": {
 			Type:       "step.event_publish",
 			Plugin:     "pipelinesteps",
@@ -737,6 +823,11 @@ func KnownStepTypes() map[string]StepTypeInfo {
 			Plugin:     "pipelinesteps",
 			ConfigKeys: []string{"key_id"},
 		},
+		"step.api_call": {
+			Type:       "step.api_call",
+			Plugin:     "pipelinesteps",
+			ConfigKeys: []string{"consumer", "operation", "params", "body", "body_from", "timeout", "error_on_status"},
+		},
 		"step.http_call": {
 			Type:       "step.http_call",
 			Plugin:     "pipelinesteps",
@@ -755,7 +846,7 @@ func KnownStepTypes() map[string]StepTypeInfo {
 		"step.db_query": {
 			Type:       "step.db_query",
 			Plugin:     "pipelinesteps",
-			ConfigKeys: []string{"database", "module", "query", "params", "args", "mode", "tenantKey"},
+			ConfigKeys: []string{"database", "module", "query", "params", "args", "mode", "tenantKey", "allow_dynamic_sql", "use_primary"},
 		},
 		"step.db_exec": {
 			Type:       "step.db_exec",
@@ -780,12 +871,12 @@ func KnownStepTypes() map[string]StepTypeInfo {
 		"step.json_response": {
 			Type:       "step.json_response",
 			Plugin:     "pipelinesteps",
-			ConfigKeys: []string{"status", "status_from", "body", "body_from", "headers"},
+			ConfigKeys: []string{"status", "status_from", "body", "body_from", "headers", "problem"},
 		},
 		"step.response": {
 			Type:       "step.response",
 			Plugin:     "pipelinesteps",
-			ConfigKeys: []string{"status", "status_from", "body", "body_from", "headers"},
+			ConfigKeys: []string{"status", "status_from", "body", "body_from", "headers", "problem"},
 		},
 		"step.static_file": {
 			Type:       "step.static_file",
@@ -812,6 +903,21 @@ func KnownStepTypes() map[string]StepTypeInfo {
 			Plugin:     "pipelinesteps",
 			ConfigKeys: []string{"schema", "required"},
 		},
+		"step.validate_response": {
+			Type:       "step.validate_response",
+			Plugin:     "pipelinesteps",
+			ConfigKeys: []string{"schema", "body_from", "warn_only"},
+		},
+		"step.schema_validate": {
+			Type:       "step.schema_validate",
+			Plugin:     "pipelinesteps",
+			ConfigKeys: []string{"registry", "schema", "source", "on_invalid"},
+		},
+		"step.localize": {
+			Type:       "step.localize",
+			Plugin:     "pipelinesteps",
+			ConfigKeys: []string{"locale", "messages"},
+		},
 		"step.foreach": {
 			Type:       "step.foreach",
 			Plugin:     "pipelinesteps",
@@ -842,6 +948,11 @@ func KnownStepTypes() map[string]StepTypeInfo {
 			Plugin:     "pipelinesteps",
 			ConfigKeys: []string{"cache", "key"},
 		},
+		"step.dlq_publish": {
+			Type:       "step.dlq_publish",
+			Plugin:     "dlq",
+			ConfigKeys: []string{"reason", "dlq", "error_type", "payload", "source", "metadata", "max_retries"},
+		},
 		"step.dlq_send": {
 			Type:       "step.dlq_send",
 			Plugin:     "pipelinesteps",
@@ -867,11 +978,21 @@ func KnownStepTypes() map[string]StepTypeInfo {
 			Plugin:     "pipelinesteps",
 			ConfigKeys: []string{"source", "target"},
 		},
+		"step.csv_parse": {
+			Type:       "step.csv_parse",
+			Plugin:     "pipelinesteps",
+			ConfigKeys: []string{"source", "file_field", "delimiter", "header", "columns", "types", "trim_space", "max_rows", "max_errors", "max_bytes", "target"},
+		},
 		"step.raw_response": {
 			Type:       "step.raw_response",
 			Plugin:     "pipelinesteps",
 			ConfigKeys: []string{"content_type", "status", "headers", "body", "body_from"},
 		},
+		"step.pdf_render": {
+			Type:       "step.pdf_render",
+			Plugin:     "pipelinesteps",
+			ConfigKeys: []string{"template", "template_file", "assets_dir", "page_size", "orientation", "margins", "output", "output_key", "filename", "disposition"},
+		},
 		"step.pipeline_output": {
 			Type:       "step.pipeline_output",
 			Plugin:     "pipelinesteps",
@@ -900,7 +1021,17 @@ func KnownStepTypes() map[string]StepTypeInfo {
 		"step.parallel": {
 			Type:       "step.parallel",
 			Plugin:     "pipelinesteps",
-			ConfigKeys: []string{"steps", "error_strategy"},
+			ConfigKeys: []string{"branches", "steps", "error_strategy", "first_error", "timeout", "max_concurrency"},
+		},
+		"step.switch": {
+			Type:       "step.switch",
+			Plugin:     "pipelinesteps",
+			ConfigKeys: []string{"field", "cases", "default", "merge_step"},
+		},
+		"step.retry": {
+			Type:       "step.retry",
+			Plugin:     "pipelinesteps",
+			ConfigKeys: []string{"steps", "max_attempts", "initial_delay", "max_delay", "multiplier", "jitter", "retry_if"},
 		},
 		"step.field_reencrypt": {
 			Type:       "step.field_reencrypt",
@@ -956,6 +1087,28 @@ func KnownStepTypes() map[string]StepTypeInfo {
 			Plugin:     "http",
 			ConfigKeys: []string{"threshold", "timeout", "halfOpenRequests"},
 		},
+		"step.session_get": {
+			Type:       "step.session_get",
+			Plugin:     "http",
+			ConfigKeys: []string{"key", "delete", "session"},
+		},
+		"step.session_set": {
+			Type:       "step.session_set",
+			Plugin:     "http",
+			ConfigKeys: []string{"values", "delete", "regenerate", "session"},
+		},
+		"step.session_destroy": {
+			Type:       "step.session_destroy",
+			Plugin:     "http",
+			ConfigKeys: []string{"session"},
+		},
+
+		// eventstore plugin steps
+		"step.projection_rebuild": {
+			Type:       "step.projection_rebuild",
+			Plugin:     "eventstore",
+			ConfigKeys: []string{"projection", "wait"},
+		},
 
 		// statemachine plugin steps
 		"step.statemachine_transition": {
@@ -983,7 +1136,7 @@ func KnownStepTypes() map[string]StepTypeInfo {
 		"step.ai_extract": {
 			Type:       "step.ai_extract",
 			Plugin:     "ai",
-			ConfigKeys: []string{"model", "input", "schema"},
+			ConfigKeys: []string{"model", "input", "schema", "max_schema_retries", "coerce"},
 		},
 		"step.sub_workflow": {
 			Type:       "step.sub_workflow",
@@ -1042,18 +1195,33 @@ func KnownStepTypes() map[string]StepTypeInfo {
 		"step.scan_container": {
 			Type:       "step.scan_container",
 			Plugin:     "cicd",
-			ConfigKeys: []string{"image", "severity"},
+			ConfigKeys: []string{"image", "severity", "sbom"},
 		},
 		"step.scan_deps": {
 			Type:       "step.scan_deps",
 			Plugin:     "cicd",
-			ConfigKeys: []string{"path", "severity"},
+			ConfigKeys: []string{"path", "severity", "sbom"},
 		},
 		"step.deploy": {
 			Type:       "step.deploy",
 			Plugin:     "cicd",
 			ConfigKeys: []string{"target", "config", "namespace"},
 		},
+		"step.test_report": {
+			Type:       "step.test_report",
+			Plugin:     "cicd",
+			ConfigKeys: []string{"path", "artifact", "from", "format", "max_failures", "min_tests", "report_key"},
+		},
+		"step.sbom_generate": {
+			Type:       "step.sbom_generate",
+			Plugin:     "cicd",
+			ConfigKeys: []string{"source_path", "image", "generator", "format", "output_path", "artifact_key"},
+		},
+		"step.attest": {
+			Type:       "step.attest",
+			Plugin:     "cicd",
+			ConfigKeys: []string{"subject", "digest", "signing_key_provider", "signing_key", "key_id", "builder_id", "materials", "include_artifacts", "artifact_key", "output_path", "push"},
+		},
 		"step.gate": {
 			Type:       "step.gate",
 			Plugin:     "cicd",
@@ -1072,7 +1240,7 @@ func KnownStepTypes() map[string]StepTypeInfo {
 		"step.git_clone": {
 			Type:       "step.git_clone",
 			Plugin:     "cicd",
-			ConfigKeys: []string{"repository", "directory", "branch", "token", "ssh_key", "depth"},
+			ConfigKeys: []string{"repository", "url", "directory", "path", "ref", "branch", "depth", "token", "username", "password", "ssh_key", "ssh_key_passphrase", "known_hosts"},
 		},
 		"step.git_commit": {
 			Type:       "step.git_commit",
@@ -1092,7 +1260,7 @@ func KnownStepTypes() map[string]StepTypeInfo {
 		"step.git_checkout": {
 			Type:       "step.git_checkout",
 			Plugin:     "cicd",
-			ConfigKeys: []string{"directory", "branch", "create"},
+			ConfigKeys: []string{"directory", "path", "branch", "ref", "create", "token", "username", "password", "ssh_key", "ssh_key_passphrase", "known_hosts"},
 		},
 
 		// auth-related steps (from pipelinesteps but auth-aware)
@@ -1146,7 +1314,7 @@ func KnownStepTypes() map[string]StepTypeInfo {
 		"step.nosql_query": {
 			Type:       "step.nosql_query",
 			Plugin:     "datastores",
-			ConfigKeys: []string{"store", "prefix", "output"},
+			ConfigKeys: []string{"store", "prefix", "partitionKey", "sortKeyPrefix", "descending", "limit", "output"},
 		},
 
 		// storage plugin steps (artifact)
@@ -1226,6 +1394,11 @@ func KnownStepTypes() map[string]StepTypeInfo {
 			Plugin:     "policy",
 			ConfigKeys: []string{"engine", "input_from"},
 		},
+		"step.policy_check": {
+			Type:       "step.policy_check",
+			Plugin:     "policy",
+			ConfigKeys: []string{"engine", "policy", "input", "input_from", "on_deny"},
+		},
 		"step.policy_list": {
 			Type:       "step.policy_list",
 			Plugin:     "policy",
@@ -1263,6 +1436,11 @@ func KnownStepTypes() map[string]StepTypeInfo {
 			Plugin:     "observability",
 			ConfigKeys: []string{"parent_field"},
 		},
+		"step.metric": {
+			Type:       "step.metric",
+			Plugin:     "observability",
+			ConfigKeys: []string{"metric", "type", "value", "labels", "operation", "buckets", "help", "max_cardinality", "collector"},
+		},
 
 		// marketplace plugin steps
 		"step.marketplace_search": {
@@ -6,11 +6,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/GoCodeAlone/workflow/config"
+	"github.com/GoCodeAlone/workflow/i18n"
 	"github.com/GoCodeAlone/workflow/internal/legacyaws"
 	"github.com/GoCodeAlone/workflow/internal/legacydo"
+	"github.com/GoCodeAlone/workflow/module"
 	"github.com/GoCodeAlone/workflow/schema"
 	"github.com/GoCodeAlone/workflow/validation"
 	"gopkg.in/yaml.v3"
@@ -28,6 +31,7 @@ func runValidate(args []string) error {
 	var pluginManifests stringSliceFlag
 	fs.Var(&pluginManifests, "plugin-manifest", "Path to a plugin.json file, or a directory containing one (or one level of subdirs that do). Repeatable. Loaded before validation so the declared types pass.")
 	noAutoResolve := fs.Bool("no-resolve-plugins", false, "Disable auto-resolution of requires.plugins[] against sibling/ancestor checkouts")
+	strictLocales := fs.Bool("strict-locales", false, "Fail when a locale's catalog lacks messages of the default locale, or the default locale lacks messages other locales define")
 	fs.Usage = func() {
 		fmt.Fprintf(fs.Output(), `Usage: wfctl validate [options] <config.yaml> [config2.yaml ...]
 
@@ -42,6 +46,7 @@ Examples:
   wfctl validate --plugin-dir data/plugins config.yaml
   wfctl validate --plugin-manifest ../workflow-plugin-foo config.yaml
   wfctl validate --plugin-manifest ../workflow-plugin-foo/plugin.json config.yaml
+  wfctl validate --strict-locales config.yaml
 
 Options:
 `)
@@ -105,7 +110,13 @@ Options:
 	)
 
 	for _, f := range files {
-		if err := validateFile(f, *strict, *skipUnknownTypes, *allowNoEntryPoints, !*noAutoResolve); err != nil {
+		if err := validateFile(f, validateOptions{
+			strict:             *strict,
+			skipUnknownTypes:   *skipUnknownTypes,
+			allowNoEntryPoints: *allowNoEntryPoints,
+			autoResolvePlugins: !*noAutoResolve,
+			strictLocales:      *strictLocales,
+		}); err != nil {
 			failed++
 			errors = append(errors, fmt.Sprintf("  FAIL %s\n       %s", f, indentError(err)))
 		} else {
@@ -136,15 +147,30 @@ Options:
 	return nil
 }
 
+// indentErrorMessage returns the error lines of a FAIL entry without its
+// header, one finding per line.
 func indentErrorMessage(message string) string {
 	lines := strings.Split(message, "\n")
-	if len(lines) == 0 {
-		return message
+	if len(lines) < 2 {
+		return strings.TrimSpace(message)
 	}
-	return strings.TrimSpace(lines[len(lines)-1])
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	return strings.Join(lines[1:], "\n")
+}
+
+// validateOptions are the wfctl validate flags that change how validateFile
+// checks a config.
+type validateOptions struct {
+	strict             bool
+	skipUnknownTypes   bool
+	allowNoEntryPoints bool
+	autoResolvePlugins bool
+	strictLocales      bool
 }
 
-func validateFile(cfgPath string, strict, skipUnknownTypes, allowNoEntryPoints, autoResolvePlugins bool) error {
+func validateFile(cfgPath string, opts validateOptions) error {
 	// Read raw YAML to extract imports list for verbose feedback.
 	imports := extractImports(cfgPath)
 	if isLikelyWfctlProjectManifest(cfgPath) {
@@ -163,29 +189,29 @@ func validateFile(cfgPath string, strict, skipUnknownTypes, allowNoEntryPoints,
 		fmt.Fprintf(os.Stderr, "  Resolved %d import(s): %s\n", len(imports), strings.Join(imports, ", "))
 	}
 
-	if autoResolvePlugins && cfg.Requires != nil {
+	if opts.autoResolvePlugins && cfg.Requires != nil {
 		autoResolveRequiredPlugins(cfgPath, cfg.Requires.Plugins)
 	}
 
-	var opts []schema.ValidationOption
-	if !strict {
-		opts = append(opts, schema.WithAllowEmptyModules())
+	var schemaOpts []schema.ValidationOption
+	if !opts.strict {
+		schemaOpts = append(schemaOpts, schema.WithAllowEmptyModules())
 	}
-	if skipUnknownTypes {
-		opts = append(opts, schema.WithSkipModuleTypeCheck(), schema.WithSkipWorkflowTypeCheck(), schema.WithSkipTriggerTypeCheck())
+	if opts.skipUnknownTypes {
+		schemaOpts = append(schemaOpts, schema.WithSkipModuleTypeCheck(), schema.WithSkipWorkflowTypeCheck(), schema.WithSkipTriggerTypeCheck())
 	} else {
 		// Still skip workflow/trigger type checks by default (dynamic dispatch)
-		opts = append(opts, schema.WithSkipWorkflowTypeCheck(), schema.WithSkipTriggerTypeCheck())
+		schemaOpts = append(schemaOpts, schema.WithSkipWorkflowTypeCheck(), schema.WithSkipTriggerTypeCheck())
 	}
-	if allowNoEntryPoints {
-		opts = append(opts, schema.WithAllowNoEntryPoints())
+	if opts.allowNoEntryPoints {
+		schemaOpts = append(schemaOpts, schema.WithAllowNoEntryPoints())
 	}
 
-	if allowNoEntryPoints && !skipUnknownTypes {
+	if opts.allowNoEntryPoints && !opts.skipUnknownTypes {
 		// Infra pseudo-modules are consumed by wfctl infra plan/align/bootstrap,
 		// not by the runtime engine, so keep them scoped to infra-style CLI
 		// validation rather than adding them to the engine's core registry.
-		opts = append(opts,
+		schemaOpts = append(schemaOpts,
 			schema.WithExtraModuleTypes("secrets.generate"),
 			schema.WithExtraModuleTypes("secrets.requires"),
 		)
@@ -194,14 +220,25 @@ func validateFile(cfgPath string, strict, skipUnknownTypes, allowNoEntryPoints,
 	// Pass legacy DO module types through schema validation so the actionable
 	// migration error fires below instead of a generic "unknown module type".
 	for t := range legacydo.ModuleTypes {
-		opts = append(opts, schema.WithExtraModuleTypes(t))
+		schemaOpts = append(schemaOpts, schema.WithExtraModuleTypes(t))
 	}
 	// Same for legacy AWS module types removed in issue #653.
 	for t := range legacyaws.ModuleTypes {
-		opts = append(opts, schema.WithExtraModuleTypes(t))
+		schemaOpts = append(schemaOpts, schema.WithExtraModuleTypes(t))
+	}
+
+	cfg, err = config.ResolveModuleConfigRefs(cfg)
+	if err != nil {
+		return err
+	}
+
+	// Modules and routes with enabled: false are still validated, but
+	// nothing enabled may depend on them.
+	if _, err := config.ApplyEnabled(cfg); err != nil {
+		return err
 	}
 
-	if err := schema.ValidateConfig(cfg, opts...); err != nil {
+	if err := schema.ValidateConfig(cfg, schemaOpts...); err != nil {
 		return err
 	}
 
@@ -263,9 +300,17 @@ func validateFile(cfgPath string, strict, skipUnknownTypes, allowNoEntryPoints,
 			return fmt.Errorf("security section: %w", err)
 		}
 	}
+	if cfg.Retention != nil {
+		if err := config.ValidateRetention(cfg.Retention); err != nil {
+			return fmt.Errorf("retention section: %w", err)
+		}
+	}
 	for _, warn := range config.CrossValidate(cfg) {
 		fmt.Fprintf(os.Stderr, "  WARN %s: %s\n", cfgPath, warn)
 	}
+	for _, warn := range config.CORSWarnings(cfg) {
+		fmt.Fprintf(os.Stderr, "  WARN %s: %s\n", cfgPath, warn)
+	}
 
 	if cfg.Pipelines != nil {
 		if refs := validation.ValidatePipelineTemplateRefs(cfg.Pipelines, schema.GetStepSchemaRegistry()); refs.HasIssues() {
@@ -274,7 +319,7 @@ func validateFile(cfgPath string, strict, skipUnknownTypes, allowNoEntryPoints,
 			for _, w := range refs.Warnings {
 				msg := "pipeline-refs warning: " + w
 				findings = append(findings, msg)
-				if !strict || !containsString(blocking, w) {
+				if !opts.strict || !containsString(blocking, w) {
 					fmt.Fprintf(os.Stderr, "  WARN %s: %s\n", cfgPath, msg)
 				}
 			}
@@ -284,13 +329,46 @@ func validateFile(cfgPath string, strict, skipUnknownTypes, allowNoEntryPoints,
 			if len(refs.Errors) > 0 {
 				return fmt.Errorf("%s", strings.Join(findings, "\n"))
 			}
-			if strict && len(blocking) > 0 {
+			if opts.strict && len(blocking) > 0 {
 				for i, w := range blocking {
 					blocking[i] = "pipeline-refs warning: " + w
 				}
 				return fmt.Errorf("%s", strings.Join(blocking, "\n"))
 			}
 		}
+		if errs := validation.ValidateMetricSteps(cfg.Pipelines); len(errs) > 0 {
+			for i, e := range errs {
+				errs[i] = "metric error: " + e
+			}
+			return fmt.Errorf("%s", strings.Join(errs, "\n"))
+		}
+	}
+
+	if len(cfg.Workflows) > 0 {
+		severities, err := validation.ParseStateMachineSeverities(cfg.StateMachineSeverities())
+		if err != nil {
+			return fmt.Errorf("engine.validation.stateMachines: %w", err)
+		}
+		sm := validation.ValidateStateMachines(cfg.Workflows, severities)
+		for _, w := range sm.Warnings {
+			fmt.Fprintf(os.Stderr, "  WARN %s: %s\n", cfgPath, w)
+		}
+		if len(sm.Errors) > 0 {
+			return fmt.Errorf("%s", strings.Join(sm.Errors, "\n"))
+		}
+	}
+
+	// Route examples that do not match their schema are warnings here;
+	// wfctl api extract -strict fails on them.
+	if len(cfg.Workflows) > 0 || len(cfg.Pipelines) > 0 {
+		gen := buildConfigOpenAPIGenerator(cfg, module.OpenAPIGeneratorConfig{}, true)
+		if err := reportRouteExamples(os.Stderr, cfgPath, gen, false); err != nil {
+			return err
+		}
+	}
+
+	if err := checkLocaleCoverage(cfgPath, cfg, opts.strictLocales); err != nil {
+		return err
 	}
 
 	fmt.Printf("  PASS %s (%d modules, %d workflows, %d triggers)\n",
@@ -298,6 +376,39 @@ func validateFile(cfgPath string, strict, skipUnknownTypes, allowNoEntryPoints,
 	return nil
 }
 
+// checkLocaleCoverage reports messages missing from the locale catalogs of
+// a config: keys of the default locale a locale
+// lacks (requests in it fall back to the default language), and keys only
+// other locales define. They are warnings unless strictLocales is set.
+func checkLocaleCoverage(cfgPath string, cfg *config.WorkflowConfig, strictLocales bool) error {
+	catalog, err := i18n.LoadConfig(cfg)
+	if err != nil || catalog == nil {
+		return err
+	}
+	missing := catalog.MissingKeys()
+	locales := make([]string, 0, len(missing))
+	for locale := range missing {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	var findings []string
+	for _, locale := range locales {
+		keys := missing[locale]
+		if locale == catalog.Default() {
+			findings = append(findings, fmt.Sprintf("locales: default locale %s lacks %d message(s) other locales define: %s", locale, len(keys), strings.Join(keys, ", ")))
+		} else {
+			findings = append(findings, fmt.Sprintf("locales: %s lacks %d message(s) of the default locale %s: %s", locale, len(keys), catalog.Default(), strings.Join(keys, ", ")))
+		}
+	}
+	if strictLocales && len(findings) > 0 {
+		return fmt.Errorf("%s", strings.Join(findings, "\n"))
+	}
+	for _, f := range findings {
+		fmt.Fprintf(os.Stderr, "  WARN %s: %s\n", cfgPath, f)
+	}
+	return nil
+}
+
 func validateConditionalRouteKeySyntax(cfgPath string) error {
 	return validateConditionalRouteKeySyntaxFile(cfgPath, make(map[string]bool))
 }
@@ -6,32 +6,30 @@ import (
 	"flag"
 	"fmt"
 	"os"
-	"runtime/debug"
 
 	"github.com/GoCodeAlone/workflow/lsp"
+	"github.com/GoCodeAlone/workflow/version"
 )
 
-var version = buildVersion()
-
-func buildVersion() string {
-	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
-		return info.Main.Version
-	}
-	return "dev"
-}
-
 func main() {
 	showVersion := flag.Bool("version", false, "Print version and exit")
 	pluginDir := flag.String("plugin-dir", "", "Directory containing external plugin manifests for step schema support")
+	snippets := flag.String("snippets", "", "Snippet file written by `wfctl snippets --format lsp` to add to the built-in snippets")
 	flag.Parse()
 
 	if *showVersion {
-		fmt.Println(version)
+		fmt.Println(version.String())
 		os.Exit(0)
 	}
 
-	lsp.Version = version
+	lsp.Version = version.Get().Version
 	s := lsp.NewServer(*pluginDir)
+	if *snippets != "" {
+		if err := s.LoadSnippets(*snippets); err != nil {
+			fmt.Fprintf(os.Stderr, "workflow-lsp-server error: %v\n", err)
+			os.Exit(1)
+		}
+	}
 	if err := s.RunStdio(); err != nil {
 		fmt.Fprintf(os.Stderr, "workflow-lsp-server error: %v\n", err)
 		os.Exit(1)
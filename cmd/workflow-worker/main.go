@@ -0,0 +1,144 @@
+// Package main is the entrypoint for workflow-worker, a process that runs
+// the executions a workflow server places on workers.
+//
+// A worker loads the same config as the server, without opening its HTTP
+// listeners, claims jobs from the server's execution queue and executes
+// their pipelines. Executions and their events stream to the server's ingest
+// API when WORKFLOW_ADMIN_URL is set, so they show up there like local ones.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"runtime/debug"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/GoCodeAlone/workflow"
+	"github.com/GoCodeAlone/workflow/config"
+	"github.com/GoCodeAlone/workflow/observability"
+	"github.com/GoCodeAlone/workflow/observability/tracing"
+	allplugins "github.com/GoCodeAlone/workflow/plugins/all"
+	"github.com/GoCodeAlone/workflow/worker"
+)
+
+// version is set at build time via -ldflags "-X main.version=<version>".
+// When built without ldflags (e.g. go run), buildVersion() reads the module
+// version from the embedded build info, falling back to "dev".
+var version = buildVersion()
+
+func buildVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "dev"
+}
+
+func main() {
+	showVersion := flag.Bool("version", false, "Print version and exit")
+	configFile := flag.String("config", "", "Workflow config file; the same one the server runs")
+	queueDSN := flag.String("queue", "", "Execution queue the server enqueues into: a postgres:// URL or a SQLite path (required)")
+	workerID := flag.String("id", "", "Worker ID (default: hostname plus a random suffix)")
+	labels := flag.String("labels", "", "Comma-separated labels advertising the worker's capabilities, e.g. docker-capable")
+	concurrency := flag.Int("concurrency", 4, "Number of jobs run at once")
+	lease := flag.Duration("lease", 30*time.Second, "How long a claimed job stays with the worker without a heartbeat")
+	pollInterval := flag.Duration("poll-interval", time.Second, "How often an idle worker checks the queue")
+	jobTimeout := flag.Duration("job-timeout", 0, "Maximum duration of a single job run (0 = no limit)")
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(version)
+		os.Exit(0)
+	}
+
+	logger := slog.New(tracing.NewContextHandler(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})))
+	if err := run(logger, *configFile, *queueDSN, worker.Config{
+		ID:           *workerID,
+		Labels:       splitLabels(*labels),
+		Concurrency:  *concurrency,
+		Lease:        *lease,
+		PollInterval: *pollInterval,
+		JobTimeout:   *jobTimeout,
+	}); err != nil {
+		logger.Error("Worker failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+func run(logger *slog.Logger, configFile, queueDSN string, cfg worker.Config) error {
+	if configFile == "" {
+		return fmt.Errorf("-config is required")
+	}
+	if queueDSN == "" {
+		return fmt.Errorf("-queue is required")
+	}
+	wfCfg, err := config.LoadFromFile(configFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config %q: %w", configFile, err)
+	}
+	queue, err := worker.OpenQueue(queueDSN)
+	if err != nil {
+		return err
+	}
+	defer queue.Close()
+
+	engine, err := workflow.NewEngineBuilder().
+		WithLogger(logger).
+		WithPlugins(allplugins.DefaultPlugins()...).
+		WithDynamicModules(wfCfg.DynamicModules()...).
+		BuildFromConfig(wfCfg)
+	if err != nil {
+		return err
+	}
+	disableListeners(engine)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := engine.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start engine: %w", err)
+	}
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := engine.Stop(shutdownCtx); err != nil {
+			logger.Error("Engine shutdown error", "error", err)
+		}
+	}()
+
+	var reporter worker.Reporter
+	if r := observability.ReporterFromEnv(logger); r != nil {
+		r.Start(ctx)
+		defer r.Stop()
+		reporter = r
+	} else {
+		logger.Warn("WORKFLOW_ADMIN_URL is not set; executions are only recorded in the queue")
+	}
+
+	return worker.NewRunner(cfg, queue, engine, reporter, logger).Run(ctx)
+}
+
+// disableListeners keeps the config's HTTP servers from listening: the
+// server takes the requests, the worker only runs what they enqueue.
+func disableListeners(engine *workflow.StdEngine) {
+	for _, svc := range engine.GetApp().SvcRegistry() {
+		if s, ok := svc.(interface{ DisableListening() }); ok {
+			s.DisableListening()
+		}
+	}
+}
+
+func splitLabels(s string) []string {
+	var labels []string
+	for l := range strings.SplitSeq(s, ",") {
+		if l = strings.TrimSpace(l); l != "" {
+			labels = append(labels, l)
+		}
+	}
+	return labels
+}
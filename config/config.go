@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	pathpkg "path/filepath"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -75,12 +76,23 @@ type ModuleConfig struct {
 	Type         string                                 `json:"type" yaml:"type"`
 	Satisfies    []string                               `json:"satisfies,omitempty" yaml:"satisfies,omitempty"`
 	Protected    bool                                   `json:"protected,omitempty" yaml:"protected,omitempty"`
+	Enabled      *bool                                  `json:"enabled,omitempty" yaml:"enabled,omitempty"`
 	Config       map[string]any                         `json:"config,omitempty" yaml:"config,omitempty"`
 	DependsOn    []string                               `json:"dependsOn,omitempty" yaml:"dependsOn,omitempty"`
+	Provides     []ServiceContract                      `json:"provides,omitempty" yaml:"provides,omitempty"` // services registered; see ResolveServiceContracts
+	Needs        []ServiceContract                      `json:"needs,omitempty" yaml:"needs,omitempty"`       // services consumed; ordered like dependsOn
+	StartPolicy  string                                 `json:"startPolicy,omitempty" yaml:"startPolicy,omitempty"`
 	Branches     map[string]string                      `json:"branches,omitempty" yaml:"branches,omitempty"`
 	Environments map[string]*InfraEnvironmentResolution `json:"environments,omitempty" yaml:"environments,omitempty"`
 }
 
+// Module start policies. A required module (the default) that fails to start
+// aborts engine start; an optional one is reported as degraded instead.
+const (
+	StartPolicyRequired = "required"
+	StartPolicyOptional = "optional"
+)
+
 // RequiresConfig declares what capabilities and plugins a workflow needs.
 type RequiresConfig struct {
 	Capabilities []string            `json:"capabilities,omitempty" yaml:"capabilities,omitempty"`
@@ -166,12 +178,88 @@ type WorkflowConfig struct {
 	Mesh           *MeshConfig                   `json:"mesh,omitempty" yaml:"mesh,omitempty"`
 	Networking     *NetworkingConfig             `json:"networking,omitempty" yaml:"networking,omitempty"`
 	Security       *SecurityConfig               `json:"security,omitempty" yaml:"security,omitempty"`
+	Retention      *RetentionConfig              `json:"retention,omitempty" yaml:"retention,omitempty"`
+	Locales        *LocalesConfig                `json:"locales,omitempty" yaml:"locales,omitempty"`
 	ConfigDir      string                        `json:"-" yaml:"-"` // directory containing the config file, used for relative path resolution
+	// RetentionPolicies holds the per-workflow retention policies collected
+	// by MergeApplicationConfig, one per workflow file with a retention block.
+	RetentionPolicies []*RetentionPolicy `json:"-" yaml:"-"`
 }
 
 // EngineConfig holds engine-level runtime settings.
 type EngineConfig struct {
 	Validation *EngineValidationConfig `json:"validation,omitempty" yaml:"validation,omitempty"`
+	Dynamic    *EngineDynamicConfig    `json:"dynamic,omitempty" yaml:"dynamic,omitempty"`
+	Startup    *EngineStartupConfig    `json:"startup,omitempty" yaml:"startup,omitempty"`
+	// StrictTemplates turns on strict template resolution for every pipeline,
+	// as if each set PipelineConfig.StrictTemplates.
+	StrictTemplates bool `json:"strict_templates,omitempty" yaml:"strict_templates,omitempty"`
+	// Errors configures the problem+json error responses of HTTP routes.
+	Errors *EngineErrorsConfig `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// EngineErrorsConfig controls how much problem+json error responses reveal
+// and how step panics are handled.
+type EngineErrorsConfig struct {
+	// Detail is "full" (default) to include the error message and failing
+	// step, or "minimal" for production: the step is omitted, and server
+	// errors carry only their code, title and execution ID.
+	Detail string `json:"detail,omitempty" yaml:"detail,omitempty"`
+	// RecoverPanics converts a panicking step into a step error, so the
+	// pipeline's on_error strategy runs (default true). Set false while
+	// debugging to let panics propagate with their original stack.
+	RecoverPanics *bool `json:"recover_panics,omitempty" yaml:"recover_panics,omitempty"`
+}
+
+// EngineStartupConfig bounds how long the engine waits for modules to come
+// up. Durations use Go syntax ("30s", "2m"); empty means no limit.
+type EngineStartupConfig struct {
+	// Timeout bounds each startup phase as a whole: module initialization
+	// during BuildFromConfig, and module start during Start.
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// ModuleTimeout bounds a single module's Init and a single module's Start.
+	ModuleTimeout string `json:"moduleTimeout,omitempty" yaml:"moduleTimeout,omitempty"`
+}
+
+// StartupTimeouts parses the engine.startup durations. Unset values are 0.
+func (c *WorkflowConfig) StartupTimeouts() (total, perModule time.Duration, err error) {
+	if c == nil || c.Engine == nil || c.Engine.Startup == nil {
+		return 0, 0, nil
+	}
+	parse := func(field, s string) (time.Duration, error) {
+		if s == "" {
+			return 0, nil
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil || d < 0 {
+			return 0, fmt.Errorf("engine.startup.%s: invalid duration %q", field, s)
+		}
+		return d, nil
+	}
+	if total, err = parse("timeout", c.Engine.Startup.Timeout); err != nil {
+		return 0, 0, err
+	}
+	if perModule, err = parse("moduleTimeout", c.Engine.Startup.ModuleTimeout); err != nil {
+		return 0, 0, err
+	}
+	return total, perModule, nil
+}
+
+// EngineDynamicConfig configures the interpreter that runs dynamic components.
+type EngineDynamicConfig struct {
+	// Modules lists third-party packages dynamic components may import, in
+	// addition to the standard library allowlist. Each must be one of the
+	// curated modules compiled into the engine (see dynamic.ImportableModules).
+	Modules []string `json:"modules,omitempty" yaml:"modules,omitempty"`
+}
+
+// DynamicModules returns the importable modules enabled for dynamic
+// components, or nil when none are configured.
+func (c *WorkflowConfig) DynamicModules() []string {
+	if c == nil || c.Engine == nil || c.Engine.Dynamic == nil {
+		return nil
+	}
+	return c.Engine.Dynamic.Modules
 }
 
 // EngineValidationConfig controls startup and execution-time validation behaviour.
@@ -179,6 +267,20 @@ type EngineValidationConfig struct {
 	// TemplateRefs controls template cross-reference validation at startup.
 	// Allowed values: "off" (skip), "warn" (log warnings, default), "error" (fail on any validation issues).
 	TemplateRefs string `json:"templateRefs,omitempty" yaml:"templateRefs,omitempty"`
+	// StateMachines sets the severity ("off", "warn" or "error") of the static
+	// checks of statemachine workflow definitions, keyed by check name
+	// (unreachable_state, undeclared_state, final_state_transitions,
+	// absorbing_state, name_collision) or "default" for every check.
+	StateMachines map[string]string `json:"stateMachines,omitempty" yaml:"stateMachines,omitempty"`
+}
+
+// StateMachineSeverities returns the engine.validation.stateMachines
+// overrides, or nil when none are set.
+func (c *WorkflowConfig) StateMachineSeverities() map[string]string {
+	if c.Engine == nil || c.Engine.Validation == nil {
+		return nil
+	}
+	return c.Engine.Validation.StateMachines
 }
 
 // InfrastructureConfig holds infrastructure resource declarations.
@@ -811,6 +913,21 @@ func MergeApplicationConfig(appCfg *ApplicationConfig) (*WorkflowConfig, error)
 
 		combined.Modules = append(combined.Modules, wfCfg.Modules...)
 
+		if policy := NewRetentionPolicy(wfName, wfCfg); policy != nil {
+			if err := ValidateRetention(wfCfg.Retention); err != nil {
+				return nil, fmt.Errorf("application %q: workflow %q: %w", appCfg.Application.Name, wfName, err)
+			}
+			combined.RetentionPolicies = append(combined.RetentionPolicies, policy)
+		}
+
+		// The first workflow file that declares locales provides them; its
+		// catalog directory stays relative to that file.
+		if wfCfg.Locales != nil && combined.Locales == nil {
+			locales := *wfCfg.Locales
+			locales.Dir = wfCfg.ResolveRelativePath(locales.Dir)
+			combined.Locales = &locales
+		}
+
 		// Merge external plugin declarations — deduplicate by name (first definition wins).
 		if wfCfg.Plugins != nil && len(wfCfg.Plugins.External) > 0 {
 			if combined.Plugins == nil {
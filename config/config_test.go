@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestNewEmptyWorkflowConfig(t *testing.T) {
@@ -243,3 +244,47 @@ triggers: {}
 		t.Errorf("expected Plugins to be nil when not declared, got %+v", cfg.Plugins)
 	}
 }
+
+func TestDynamicModulesParsing(t *testing.T) {
+	cfg, err := LoadFromString(`
+modules: []
+engine:
+  dynamic:
+    modules:
+      - github.com/google/uuid
+`)
+	if err != nil {
+		t.Fatalf("LoadFromString failed: %v", err)
+	}
+	if got := cfg.DynamicModules(); len(got) != 1 || got[0] != "github.com/google/uuid" {
+		t.Errorf("DynamicModules() = %v", got)
+	}
+	if got := (&WorkflowConfig{}).DynamicModules(); got != nil {
+		t.Errorf("DynamicModules() without engine config = %v, want nil", got)
+	}
+}
+
+func TestStartupTimeouts(t *testing.T) {
+	cfg, err := LoadFromString(`
+modules: []
+engine:
+  startup:
+    timeout: 2m
+    moduleTimeout: 30s
+`)
+	if err != nil {
+		t.Fatalf("LoadFromString failed: %v", err)
+	}
+	total, perModule, err := cfg.StartupTimeouts()
+	if err != nil || total != 2*time.Minute || perModule != 30*time.Second {
+		t.Errorf("StartupTimeouts() = %v, %v, %v", total, perModule, err)
+	}
+
+	cfg.Engine.Startup.Timeout = "-1s"
+	if _, _, err := cfg.StartupTimeouts(); err == nil {
+		t.Error("expected an error for a negative timeout")
+	}
+	if total, perModule, err := (&WorkflowConfig{}).StartupTimeouts(); total != 0 || perModule != 0 || err != nil {
+		t.Errorf("StartupTimeouts() without engine config = %v, %v, %v", total, perModule, err)
+	}
+}
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// HTTPRouteConfig is one HTTP route declared in a workflow config: a
+// triggers.http route, an http workflow route or a pipeline trigger of type
+// http.
+type HTTPRouteConfig struct {
+	// Path locates the route in the config, e.g. "triggers.http.routes[0]"
+	// or "pipelines.orders.trigger.config".
+	Path   string
+	Method string
+	Route  string // URL path pattern
+	// Router is the router the route names explicitly; empty when the
+	// engine picks one.
+	Router      string
+	Middlewares []string
+	CORS        any // the route's cors block, nil when it has none
+}
+
+// HTTPRoutes returns every HTTP route declared in the config: triggers.http
+// routes in declaration order, then http workflow routes and pipeline HTTP
+// triggers sorted by name. Entries that are not maps are skipped.
+func (c *WorkflowConfig) HTTPRoutes() []HTTPRouteConfig {
+	var routes []HTTPRouteConfig
+	addRoutes := func(prefix, router string, list any) {
+		items, _ := list.([]any)
+		for i, raw := range items {
+			m, ok := raw.(map[string]any)
+			if !ok {
+				continue
+			}
+			route := httpRouteFromMap(fmt.Sprintf("%s.routes[%d]", prefix, i), m)
+			route.Router = router
+			routes = append(routes, route)
+		}
+	}
+
+	if trig, ok := c.Triggers["http"].(map[string]any); ok {
+		addRoutes("triggers.http", "", trig["routes"])
+	}
+	for _, name := range sortedMapKeys(c.Workflows) {
+		if name != "http" && !strings.HasPrefix(name, "http-") {
+			continue
+		}
+		wf, _ := c.Workflows[name].(map[string]any)
+		router, _ := wf["router"].(string)
+		addRoutes("workflows."+name, router, wf["routes"])
+	}
+	for _, name := range sortedMapKeys(c.Pipelines) {
+		pipeline, _ := c.Pipelines[name].(map[string]any)
+		trigger, _ := pipeline["trigger"].(map[string]any)
+		if t, _ := trigger["type"].(string); t != "http" {
+			continue
+		}
+		cfg, _ := trigger["config"].(map[string]any)
+		routes = append(routes, httpRouteFromMap(fmt.Sprintf("pipelines.%s.trigger.config", name), cfg))
+	}
+	return routes
+}
+
+func httpRouteFromMap(path string, m map[string]any) HTTPRouteConfig {
+	route := HTTPRouteConfig{Path: path, CORS: m["cors"]}
+	method, _ := m["method"].(string)
+	route.Method = strings.ToUpper(method)
+	route.Route, _ = m["path"].(string)
+	if list, ok := m["middlewares"].([]any); ok {
+		for _, item := range list {
+			if name, ok := item.(string); ok {
+				route.Middlewares = append(route.Middlewares, name)
+			}
+		}
+	}
+	return route
+}
+
+func sortedMapKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ValidateCORSOrigin checks one allowedOrigins entry. Browsers send the
+// Origin header as scheme://host[:port] and origins are compared exactly, so
+// an entry with a trailing slash, a path or no scheme never matches. Besides
+// such origins, "*" allows every origin and "*.example.com" every subdomain
+// of example.com on any scheme and port.
+func ValidateCORSOrigin(origin string) error {
+	if origin == "*" {
+		return nil
+	}
+	if domain, ok := strings.CutPrefix(origin, "*."); ok {
+		if domain == "" || strings.ContainsAny(domain, "*/:") {
+			return fmt.Errorf("origin %q: a wildcard origin is *. followed by a domain, e.g. *.example.com", origin)
+		}
+		return nil
+	}
+	if strings.Contains(origin, "*") {
+		return fmt.Errorf("origin %q: wildcards are only supported as a leading *. on a domain, e.g. *.example.com", origin)
+	}
+	if !strings.Contains(origin, "://") {
+		return fmt.Errorf("origin %q has no scheme; write it as https://%s", origin, strings.TrimSuffix(origin, "/"))
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("origin %q is not scheme://host[:port]", origin)
+	}
+	switch {
+	case u.Path == "/" && u.RawQuery == "" && u.Fragment == "":
+		return fmt.Errorf("origin %q has a trailing slash; browsers send origins without one, so it never matches", origin)
+	case u.Path != "" || u.RawQuery != "" || u.Fragment != "" || u.User != nil:
+		return fmt.Errorf("origin %q is not scheme://host[:port]; origins have no path, query or credentials", origin)
+	}
+	return nil
+}
+
+// CORSWarnings reports CORS methods that no route can use: allowedMethods
+// of an http.middleware.cors module that no declared route serves, and
+// methods in a route's own cors block other than the route's method, since
+// the preflight of another method is matched to the route serving it.
+// OPTIONS is ignored and a served GET covers HEAD.
+func CORSWarnings(cfg *WorkflowConfig) []string {
+	routes := cfg.HTTPRoutes()
+	served := make(map[string]bool)
+	for _, route := range routes {
+		served[route.Method] = true
+	}
+	usable := func(method string, served map[string]bool) bool {
+		method = strings.ToUpper(method)
+		return method == http.MethodOptions || served[method] || (method == http.MethodHead && served[http.MethodGet])
+	}
+
+	var warnings []string
+	if len(routes) > 0 {
+		for _, mod := range cfg.Modules {
+			if mod.Type != "http.middleware.cors" {
+				continue
+			}
+			methods, _ := mod.Config["allowedMethods"].([]any)
+			for _, raw := range methods {
+				if method, ok := raw.(string); ok && !usable(method, served) {
+					warnings = append(warnings, fmt.Sprintf("cors module %q allows method %s but no route declared in the config serves it", mod.Name, method))
+				}
+			}
+		}
+	}
+	for _, route := range routes {
+		block, _ := route.CORS.(map[string]any)
+		methods, _ := block["allowedMethods"].([]any)
+		for _, raw := range methods {
+			if method, ok := raw.(string); ok && !usable(method, map[string]bool{route.Method: true}) {
+				warnings = append(warnings, fmt.Sprintf("%s.cors: allowedMethods lists %s but the route serves %s; preflights for %s are matched to the route serving it", route.Path, method, route.Method, method))
+			}
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func corsTestConfig() *WorkflowConfig {
+	return &WorkflowConfig{
+		Modules: []ModuleConfig{
+			{Name: "cors", Type: "http.middleware.cors", Config: map[string]any{
+				"allowedMethods": []any{"GET", "HEAD", "POST", "PATCH", "OPTIONS"},
+			}},
+		},
+		Triggers: map[string]any{
+			"http": map[string]any{"routes": []any{
+				map[string]any{"method": "get", "path": "/items", "workflow": "w", "action": "list"},
+				"not-a-route",
+			}},
+		},
+		Workflows: map[string]any{
+			"http": map[string]any{
+				"router": "admin-router",
+				"routes": []any{
+					map[string]any{"method": "DELETE", "path": "/items/{id}", "middlewares": []any{"auth"}},
+				},
+			},
+			"messaging": map[string]any{"routes": []any{map[string]any{"method": "PUT", "path": "/ignored"}}},
+		},
+		Pipelines: map[string]any{
+			"create-item": map[string]any{"trigger": map[string]any{"type": "http", "config": map[string]any{
+				"method": "POST",
+				"path":   "/items",
+				"cors": map[string]any{
+					"allowedOrigins": []any{"https://admin.example.com"},
+					"allowedMethods": []any{"POST", "PUT", "OPTIONS"},
+				},
+			}}},
+			"nightly": map[string]any{"trigger": map[string]any{"type": "schedule"}},
+		},
+	}
+}
+
+func TestHTTPRoutes(t *testing.T) {
+	routes := corsTestConfig().HTTPRoutes()
+	if len(routes) != 3 {
+		t.Fatalf("got %d routes, want 3: %+v", len(routes), routes)
+	}
+	want := []HTTPRouteConfig{
+		{Path: "triggers.http.routes[0]", Method: "GET", Route: "/items"},
+		{Path: "workflows.http.routes[0]", Method: "DELETE", Route: "/items/{id}", Router: "admin-router", Middlewares: []string{"auth"}},
+		{Path: "pipelines.create-item.trigger.config", Method: "POST", Route: "/items"},
+	}
+	for i, w := range want {
+		got := routes[i]
+		if got.Path != w.Path || got.Method != w.Method || got.Route != w.Route || got.Router != w.Router || !slices.Equal(got.Middlewares, w.Middlewares) {
+			t.Errorf("route %d = %+v, want %+v", i, got, w)
+		}
+	}
+	if routes[2].CORS == nil || routes[0].CORS != nil {
+		t.Errorf("cors blocks = %v, %v", routes[0].CORS, routes[2].CORS)
+	}
+}
+
+func TestValidateCORSOrigin(t *testing.T) {
+	for _, origin := range []string{"*", "*.example.com", "https://app.example.com", "http://localhost:3000", "app://desktop"} {
+		if err := ValidateCORSOrigin(origin); err != nil {
+			t.Errorf("%q: %v", origin, err)
+		}
+	}
+	tests := []struct {
+		origin string
+		want   string
+	}{
+		{"https://app.example.com/", "trailing slash"},
+		{"app.example.com", "has no scheme; write it as https://app.example.com"},
+		{"localhost:3000/", "has no scheme; write it as https://localhost:3000"},
+		{"https://app.example.com/path", "origins have no path"},
+		{"https://app.example.com?x=1", "origins have no path"},
+		{"https://*.example.com", "wildcards are only supported"},
+		{"*.example.com:8080", "a wildcard origin is *. followed by a domain"},
+		{"https://", "is not scheme://host[:port]"},
+	}
+	for _, tt := range tests {
+		if err := ValidateCORSOrigin(tt.origin); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: err = %v, want %q", tt.origin, err, tt.want)
+		}
+	}
+}
+
+func TestCORSWarnings(t *testing.T) {
+	got := CORSWarnings(corsTestConfig())
+	want := []string{
+		`cors module "cors" allows method PATCH but no route declared in the config serves it`,
+		"pipelines.create-item.trigger.config.cors: allowedMethods lists PUT but the route serves POST; preflights for PUT are matched to the route serving it",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("warnings =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if w := CORSWarnings(&WorkflowConfig{Modules: corsTestConfig().Modules}); len(w) != 0 {
+		t.Errorf("config without routes warned: %v", w)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"sort"
 
 	"gopkg.in/yaml.v3"
 )
@@ -93,3 +94,136 @@ func hashAny(v any) string {
 	sum := sha256.Sum256(data)
 	return hex.EncodeToString(sum[:])
 }
+
+// DiffSummary is a serializable overview of the differences between two
+// configs, used when reviewing a staged change before it is applied.
+type DiffSummary struct {
+	ModulesAdded    []string `json:"modulesAdded,omitempty"`
+	ModulesRemoved  []string `json:"modulesRemoved,omitempty"`
+	ModulesModified []string `json:"modulesModified,omitempty"`
+	// Sections lists the changed top-level keys other than modules
+	// (workflows, triggers, pipelines, ...).
+	Sections []string `json:"sections,omitempty"`
+}
+
+// Empty reports whether the summary records no changes.
+func (s *DiffSummary) Empty() bool {
+	return len(s.ModulesAdded) == 0 && len(s.ModulesRemoved) == 0 &&
+		len(s.ModulesModified) == 0 && len(s.Sections) == 0
+}
+
+// SummarizeDiff compares two configs and returns a sorted summary of the
+// modules and top-level sections that differ.
+func SummarizeDiff(old, new *WorkflowConfig) *DiffSummary {
+	diff := DiffModuleConfigs(old, new)
+	summary := &DiffSummary{}
+	for _, m := range diff.Added {
+		summary.ModulesAdded = append(summary.ModulesAdded, m.Name)
+	}
+	for _, m := range diff.Removed {
+		summary.ModulesRemoved = append(summary.ModulesRemoved, m.Name)
+	}
+	for _, m := range diff.Modified {
+		summary.ModulesModified = append(summary.ModulesModified, m.Name)
+	}
+	sort.Strings(summary.ModulesAdded)
+	sort.Strings(summary.ModulesRemoved)
+	sort.Strings(summary.ModulesModified)
+
+	oldSections, newSections := topLevelSections(old), topLevelSections(new)
+	for key, v := range newSections {
+		if hashAny(v) != hashAny(oldSections[key]) {
+			summary.Sections = append(summary.Sections, key)
+		}
+	}
+	for key := range oldSections {
+		if _, ok := newSections[key]; !ok {
+			summary.Sections = append(summary.Sections, key)
+		}
+	}
+	sort.Strings(summary.Sections)
+	return summary
+}
+
+// topLevelSections returns the config's non-empty top-level YAML sections,
+// excluding modules. Empty and absent sections are treated alike.
+func topLevelSections(cfg *WorkflowConfig) map[string]any {
+	sections := map[string]any{}
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return sections
+	}
+	if err := yaml.Unmarshal(data, &sections); err != nil {
+		return map[string]any{}
+	}
+	delete(sections, "modules")
+	for key, v := range sections {
+		switch t := v.(type) {
+		case nil:
+			delete(sections, key)
+		case map[string]any:
+			if len(t) == 0 {
+				delete(sections, key)
+			}
+		case []any:
+			if len(t) == 0 {
+				delete(sections, key)
+			}
+		}
+	}
+	return sections
+}
+
+// Reload strategies reported by PlanReload.
+const (
+	ReloadStrategyNone    = "none"    // nothing the engine acts on changed
+	ReloadStrategyPartial = "partial" // modified modules are reconfigured in place
+	ReloadStrategyFull    = "full"    // the engine is rebuilt and restarted
+)
+
+// ReloadPlan describes what applying a config to a running engine would do,
+// without doing it.
+type ReloadPlan struct {
+	Strategy       string   `json:"strategy"`
+	ModulesAdded   []string `json:"modulesAdded,omitempty"`
+	ModulesRemoved []string `json:"modulesRemoved,omitempty"`
+	// ModulesReplaced are modified modules rebuilt by a full reload.
+	ModulesReplaced []string `json:"modulesReplaced,omitempty"`
+	// ModulesReconfigured are modified modules reconfigured in place.
+	ModulesReconfigured []string `json:"modulesReconfigured,omitempty"`
+	Sections            []string `json:"sections,omitempty"`
+	Warnings            []string `json:"warnings,omitempty"`
+}
+
+// PlanReload predicts how a reload from old to new is applied, using the
+// same rules as ConfigReloader.HandleChange. reconfigurable reports whether
+// modified modules can be reconfigured in place; when false any change
+// needs a full reload.
+func PlanReload(old, new *WorkflowConfig, reconfigurable bool) *ReloadPlan {
+	summary := SummarizeDiff(old, new)
+	plan := &ReloadPlan{
+		Strategy:       ReloadStrategyNone,
+		ModulesAdded:   summary.ModulesAdded,
+		ModulesRemoved: summary.ModulesRemoved,
+		Sections:       summary.Sections,
+	}
+	nonModule := HasNonModuleChanges(old, new)
+	switch {
+	case nonModule || len(summary.ModulesAdded) > 0 || len(summary.ModulesRemoved) > 0 ||
+		(len(summary.ModulesModified) > 0 && !reconfigurable):
+		plan.Strategy = ReloadStrategyFull
+		plan.ModulesReplaced = summary.ModulesModified
+		plan.Warnings = append(plan.Warnings, "full reload stops and restarts the engine; in-flight requests may be interrupted")
+	case len(summary.ModulesModified) > 0:
+		plan.Strategy = ReloadStrategyPartial
+		plan.ModulesReconfigured = summary.ModulesModified
+		plan.Warnings = append(plan.Warnings, "modules that cannot be reconfigured in place fall back to a full reload")
+	}
+	for _, name := range summary.ModulesRemoved {
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf("module %q will be stopped and removed", name))
+	}
+	if !nonModule && len(summary.Sections) > 0 {
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf("changes to %v are not applied until the next full reload", summary.Sections))
+	}
+	return plan
+}
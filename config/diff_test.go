@@ -193,3 +193,64 @@ func TestHasNonModuleChanges_PipelineChanged(t *testing.T) {
 		t.Error("expected non-module changes when pipeline differs")
 	}
 }
+
+func TestSummarizeDiff(t *testing.T) {
+	old := makeConfig([]ModuleConfig{
+		{Name: "server", Type: "http.server", Config: map[string]any{"address": ":8080"}},
+		{Name: "legacy", Type: "http.router"},
+		{Name: "db", Type: "storage.sqlite"},
+	}, map[string]any{}, nil, map[string]any{"a": map[string]any{"steps": []any{}}})
+	new := makeConfig([]ModuleConfig{
+		{Name: "server", Type: "http.server", Config: map[string]any{"address": ":9090"}},
+		{Name: "db", Type: "storage.sqlite"},
+		{Name: "cache", Type: "cache.redis"},
+	}, nil, map[string]any{"http": map[string]any{}}, map[string]any{"a": map[string]any{"steps": []any{"x"}}})
+
+	s := SummarizeDiff(old, new)
+	if len(s.ModulesAdded) != 1 || s.ModulesAdded[0] != "cache" {
+		t.Errorf("added = %v", s.ModulesAdded)
+	}
+	if len(s.ModulesRemoved) != 1 || s.ModulesRemoved[0] != "legacy" {
+		t.Errorf("removed = %v", s.ModulesRemoved)
+	}
+	if len(s.ModulesModified) != 1 || s.ModulesModified[0] != "server" {
+		t.Errorf("modified = %v", s.ModulesModified)
+	}
+	// Empty workflows on one side and nil on the other is not a change.
+	if len(s.Sections) != 2 || s.Sections[0] != "pipelines" || s.Sections[1] != "triggers" {
+		t.Errorf("sections = %v, want [pipelines triggers]", s.Sections)
+	}
+	if SummarizeDiff(old, old).Empty() != true {
+		t.Error("expected empty summary for identical configs")
+	}
+}
+
+func TestPlanReload(t *testing.T) {
+	base := []ModuleConfig{
+		{Name: "server", Type: "http.server", Config: map[string]any{"address": ":8080"}},
+		{Name: "db", Type: "storage.sqlite"},
+	}
+	modified := []ModuleConfig{
+		{Name: "server", Type: "http.server", Config: map[string]any{"address": ":9090"}},
+		{Name: "db", Type: "storage.sqlite"},
+	}
+
+	if p := PlanReload(makeConfig(base, nil, nil, nil), makeConfig(base, nil, nil, nil), true); p.Strategy != ReloadStrategyNone || len(p.Warnings) != 0 {
+		t.Errorf("identical configs: %+v", p)
+	}
+
+	p := PlanReload(makeConfig(base, nil, nil, nil), makeConfig(modified, nil, nil, nil), true)
+	if p.Strategy != ReloadStrategyPartial || len(p.ModulesReconfigured) != 1 || p.ModulesReconfigured[0] != "server" || len(p.ModulesReplaced) != 0 {
+		t.Errorf("module-only change with reconfigurer: %+v", p)
+	}
+	p = PlanReload(makeConfig(base, nil, nil, nil), makeConfig(modified, nil, nil, nil), false)
+	if p.Strategy != ReloadStrategyFull || len(p.ModulesReplaced) != 1 {
+		t.Errorf("module-only change without reconfigurer: %+v", p)
+	}
+
+	p = PlanReload(makeConfig(base, nil, nil, nil), makeConfig(modified[:1], nil, nil, map[string]any{"a": map[string]any{}}), true)
+	if p.Strategy != ReloadStrategyFull || len(p.ModulesRemoved) != 1 || p.ModulesRemoved[0] != "db" ||
+		len(p.Sections) != 1 || p.Sections[0] != "pipelines" || len(p.Warnings) != 2 {
+		t.Errorf("removal with pipeline change: %+v", p)
+	}
+}
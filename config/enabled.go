@@ -0,0 +1,220 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// IsEnabled reports whether the module should be built. Modules are enabled
+// unless they set `enabled: false`.
+func (m *ModuleConfig) IsEnabled() bool {
+	return m.Enabled == nil || *m.Enabled
+}
+
+// entryEnabled reports whether a raw route or pipeline entry is enabled and
+// fails when its `enabled` key is not a boolean.
+func entryEnabled(entry map[string]any) (bool, error) {
+	v, ok := entry["enabled"]
+	if !ok || v == nil {
+		return true, nil
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("enabled must be a boolean, got %T", v)
+	}
+	return b, nil
+}
+
+// ApplyEnabled returns the config the engine builds: cfg without the modules
+// and HTTP routes that set `enabled: false`, and without the HTTP triggers of
+// pipelines that set it: both the pipeline's inline `http` trigger and the
+// triggers.http routes to `pipeline:<name>`. A disabled pipeline is otherwise
+// still built, so it can be called with step.workflow_call. cfg itself is not
+// modified, so disabled entries survive reloads and config export; when
+// nothing is disabled cfg is returned unchanged.
+//
+// It fails when an enabled module lists a disabled module in dependsOn, when
+// an HTTP workflow's server or router is disabled, or when an enabled route's
+// handler or middleware is disabled.
+func ApplyEnabled(cfg *WorkflowConfig) (*WorkflowConfig, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	disabled := make(map[string]bool)
+	for i := range cfg.Modules {
+		if !cfg.Modules[i].IsEnabled() {
+			disabled[cfg.Modules[i].Name] = true
+		}
+	}
+
+	var errs []error
+	for _, m := range cfg.Modules {
+		if !m.IsEnabled() {
+			continue
+		}
+		for _, dep := range m.DependsOn {
+			if disabled[dep] {
+				errs = append(errs, fmt.Errorf("module %q depends on disabled module %q", m.Name, dep))
+			}
+		}
+	}
+
+	var workflows map[string]any
+	for _, wfType := range slices.Sorted(maps.Keys(cfg.Workflows)) {
+		wf, ok := cfg.Workflows[wfType].(map[string]any)
+		if !ok || (wfType != "http" && !strings.HasPrefix(wfType, "http-")) {
+			continue
+		}
+		for _, key := range []string{"server", "router"} {
+			if name, _ := wf[key].(string); disabled[name] {
+				errs = append(errs, fmt.Errorf("workflows.%s.%s references disabled module %q", wfType, key, name))
+			}
+		}
+		routes, _ := wf["routes"].([]any)
+		kept := make([]any, 0, len(routes))
+		for i, r := range routes {
+			route, ok := r.(map[string]any)
+			if !ok {
+				kept = append(kept, r)
+				continue
+			}
+			label := fmt.Sprintf("workflows.%s.routes[%d]", wfType, i)
+			enabled, err := entryEnabled(route)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", label, err))
+				continue
+			}
+			if !enabled {
+				continue
+			}
+			if handler, _ := route["handler"].(string); disabled[handler] {
+				errs = append(errs, fmt.Errorf("%s: handler %q is disabled", label, handler))
+			}
+			for _, mw := range routeMiddlewares(route) {
+				if disabled[mw] {
+					errs = append(errs, fmt.Errorf("%s: middleware %q is disabled", label, mw))
+				}
+			}
+			kept = append(kept, route)
+		}
+		if len(kept) == len(routes) {
+			continue
+		}
+		if workflows == nil {
+			workflows = make(map[string]any, len(cfg.Workflows))
+			for k, v := range cfg.Workflows {
+				workflows[k] = v
+			}
+		}
+		pruned := make(map[string]any, len(wf))
+		for k, v := range wf {
+			pruned[k] = v
+		}
+		pruned["routes"] = kept
+		workflows[wfType] = pruned
+	}
+
+	disabledPipelines := make(map[string]bool)
+	for _, name := range slices.Sorted(maps.Keys(cfg.Pipelines)) {
+		p, ok := cfg.Pipelines[name].(map[string]any)
+		if !ok {
+			continue
+		}
+		enabled, err := entryEnabled(p)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("pipelines.%s: %w", name, err))
+			continue
+		}
+		if !enabled {
+			disabledPipelines[name] = true
+		}
+	}
+	pipelines, triggers := pruneDisabledPipelineTriggers(cfg, disabledPipelines)
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	if len(disabled) == 0 && workflows == nil && pipelines == nil && triggers == nil {
+		return cfg, nil
+	}
+
+	out := *cfg
+	if len(disabled) > 0 {
+		out.Modules = slices.DeleteFunc(slices.Clone(cfg.Modules), func(m ModuleConfig) bool {
+			return !m.IsEnabled()
+		})
+	}
+	if workflows != nil {
+		out.Workflows = workflows
+	}
+	if pipelines != nil {
+		out.Pipelines = pipelines
+	}
+	if triggers != nil {
+		out.Triggers = triggers
+	}
+	return &out, nil
+}
+
+// pruneDisabledPipelineTriggers returns copies of cfg's pipelines and
+// triggers without the HTTP triggers of the disabled pipelines. Either is nil
+// when nothing was pruned from it.
+func pruneDisabledPipelineTriggers(cfg *WorkflowConfig, disabled map[string]bool) (pipelines, triggers map[string]any) {
+	if len(disabled) == 0 {
+		return nil, nil
+	}
+
+	for name := range disabled {
+		p := cfg.Pipelines[name].(map[string]any)
+		trigger, _ := p["trigger"].(map[string]any)
+		if trigger == nil || trigger["type"] != "http" {
+			continue
+		}
+		if pipelines == nil {
+			pipelines = maps.Clone(cfg.Pipelines)
+		}
+		pruned := maps.Clone(p)
+		delete(pruned, "trigger")
+		pipelines[name] = pruned
+	}
+
+	httpTrigger, ok := cfg.Triggers["http"].(map[string]any)
+	if !ok {
+		return pipelines, nil
+	}
+	routes, _ := httpTrigger["routes"].([]any)
+	kept := slices.DeleteFunc(slices.Clone(routes), func(r any) bool {
+		route, _ := r.(map[string]any)
+		target, _ := route["workflow"].(string)
+		name, ok := strings.CutPrefix(target, "pipeline:")
+		return ok && disabled[name]
+	})
+	if len(kept) == len(routes) {
+		return pipelines, nil
+	}
+	pruned := maps.Clone(httpTrigger)
+	pruned["routes"] = kept
+	triggers = maps.Clone(cfg.Triggers)
+	triggers["http"] = pruned
+	return pipelines, triggers
+}
+
+// routeMiddlewares returns the module names in a route's middlewares list.
+func routeMiddlewares(route map[string]any) []string {
+	var names []string
+	switch mws := route["middlewares"].(type) {
+	case []any:
+		for _, mw := range mws {
+			if s, ok := mw.(string); ok {
+				names = append(names, s)
+			}
+		}
+	case []string:
+		names = mws
+	}
+	return names
+}
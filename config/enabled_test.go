@@ -0,0 +1,203 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyEnabled(t *testing.T) {
+	cfg, err := LoadFromString(`
+modules:
+  - name: server
+    type: http.server
+  - name: router
+    type: http.router
+  - name: orders
+    type: http.handler
+  - name: legacy
+    type: http.handler
+    enabled: false
+  - name: auth
+    type: http.middleware.auth
+    enabled: true
+workflows:
+  http:
+    server: server
+    router: router
+    routes:
+      - method: GET
+        path: /orders
+        handler: orders
+        middlewares: [auth]
+      - method: GET
+        path: /legacy
+        handler: legacy
+        enabled: false
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	built, err := ApplyEnabled(cfg)
+	if err != nil {
+		t.Fatalf("ApplyEnabled: %v", err)
+	}
+	var names []string
+	for _, m := range built.Modules {
+		names = append(names, m.Name)
+	}
+	if got := strings.Join(names, ","); got != "server,router,orders,auth" {
+		t.Errorf("built modules = %s", got)
+	}
+	routes := built.Workflows["http"].(map[string]any)["routes"].([]any)
+	if len(routes) != 1 || routes[0].(map[string]any)["path"] != "/orders" {
+		t.Errorf("built routes = %v, want only /orders", routes)
+	}
+
+	// The caller's config keeps its disabled entries.
+	if len(cfg.Modules) != 5 {
+		t.Errorf("source modules = %d, want 5", len(cfg.Modules))
+	}
+	if n := len(cfg.Workflows["http"].(map[string]any)["routes"].([]any)); n != 2 {
+		t.Errorf("source routes = %d, want 2", n)
+	}
+}
+
+func TestApplyEnabled_NothingDisabled(t *testing.T) {
+	cfg, err := LoadFromString(`
+modules:
+  - name: server
+    type: http.server
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	built, err := ApplyEnabled(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if built != cfg {
+		t.Error("expected the config itself when nothing is disabled")
+	}
+}
+
+func TestApplyEnabled_EnabledDependsOnDisabled(t *testing.T) {
+	cfg, err := LoadFromString(`
+modules:
+  - name: db
+    type: storage.sqlite
+    enabled: false
+  - name: cache
+    type: cache.redis
+    dependsOn: [db]
+  - name: server
+    type: http.server
+    enabled: false
+  - name: auth
+    type: http.middleware.auth
+    enabled: false
+  - name: orders
+    type: http.handler
+workflows:
+  http:
+    server: server
+    routes:
+      - method: GET
+        path: /orders
+        handler: orders
+        middlewares: [auth]
+      - method: GET
+        path: /flag
+        handler: orders
+        enabled: "no"
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ApplyEnabled(cfg)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, want := range []string{
+		`module "cache" depends on disabled module "db"`,
+		`workflows.http.server references disabled module "server"`,
+		`workflows.http.routes[0]: middleware "auth" is disabled`,
+		`workflows.http.routes[1]: enabled must be a boolean`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
+
+func TestApplyEnabled_DisabledPipelineHTTPTriggers(t *testing.T) {
+	cfg, err := LoadFromString(`
+modules:
+  - name: server
+    type: http.server
+triggers:
+  http:
+    routes:
+      - method: POST
+        path: /orders
+        workflow: "pipeline:create-order"
+      - method: POST
+        path: /legacy
+        workflow: "pipeline:legacy-import"
+pipelines:
+  create-order:
+    trigger:
+      type: http
+      config:
+        path: /orders/inline
+        method: POST
+    steps:
+      - name: ok
+        type: step.set
+  legacy-import:
+    enabled: false
+    trigger:
+      type: http
+      config:
+        path: /legacy/inline
+        method: POST
+    steps:
+      - name: ok
+        type: step.set
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	built, err := ApplyEnabled(cfg)
+	if err != nil {
+		t.Fatalf("ApplyEnabled: %v", err)
+	}
+	legacy := built.Pipelines["legacy-import"].(map[string]any)
+	if _, ok := legacy["trigger"]; ok {
+		t.Error("disabled pipeline kept its http trigger")
+	}
+	if _, ok := legacy["steps"]; !ok {
+		t.Error("disabled pipeline lost its steps")
+	}
+	if _, ok := built.Pipelines["create-order"].(map[string]any)["trigger"]; !ok {
+		t.Error("enabled pipeline lost its http trigger")
+	}
+	routes := built.Triggers["http"].(map[string]any)["routes"].([]any)
+	if len(routes) != 1 || routes[0].(map[string]any)["path"] != "/orders" {
+		t.Errorf("built trigger routes = %v, want only /orders", routes)
+	}
+
+	// The caller's config keeps the disabled pipeline's triggers.
+	if _, ok := cfg.Pipelines["legacy-import"].(map[string]any)["trigger"]; !ok {
+		t.Error("source pipeline lost its trigger")
+	}
+	if n := len(cfg.Triggers["http"].(map[string]any)["routes"].([]any)); n != 2 {
+		t.Errorf("source trigger routes = %d, want 2", n)
+	}
+
+	cfg.Pipelines["create-order"].(map[string]any)["enabled"] = "off"
+	if _, err := ApplyEnabled(cfg); err == nil || !strings.Contains(err.Error(), "pipelines.create-order: enabled must be a boolean") {
+		t.Errorf("expected non-boolean enabled error, got %v", err)
+	}
+}
@@ -0,0 +1,16 @@
+package config
+
+// LocalesConfig is the locales: section of a workflow config. It declares
+// the message catalogs that the t template function and step.localize
+// translate with, and the locale requests fall back to.
+type LocalesConfig struct {
+	// Default is the locale used when a request's Accept-Language matches
+	// no catalog, and the last fallback for a missing message.
+	Default string `json:"default" yaml:"default"`
+	// Dir is a directory of catalog files named <locale>.yaml, .yml or
+	// .json, relative to the config file.
+	Dir string `json:"dir,omitempty" yaml:"dir,omitempty"`
+	// Messages holds inline catalogs keyed by locale. They take precedence
+	// over messages with the same key loaded from Dir.
+	Messages map[string]map[string]any `json:"messages,omitempty" yaml:"messages,omitempty"`
+}
@@ -0,0 +1,232 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// moduleRefPattern matches ${module.<name>.config.<key>} references. The key
+// may be a dotted path into nested config maps.
+var moduleRefPattern = regexp.MustCompile(`\$\{module\.([^}]+?)\.config\.([^}]+)\}`)
+
+// ResolveModuleConfigRefs returns cfg with every ${module.<name>.config.<key>}
+// reference in module config replaced by the referenced module's config
+// value, so modules can share a value (a base URL, a table name) instead of
+// repeating it. A string that consists of a single reference takes the
+// referenced value with its type; a reference embedded in a longer string is
+// formatted into it and must point at a scalar. Referenced values may
+// themselves contain references.
+//
+// cfg itself is not modified, so references survive reloads and config
+// export; when no module config contains a reference cfg is returned
+// unchanged. It fails on references to unknown modules or missing keys and
+// on reference cycles.
+func ResolveModuleConfigRefs(cfg *WorkflowConfig) (*WorkflowConfig, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	found := false
+	for i := range cfg.Modules {
+		if containsModuleRef(cfg.Modules[i].Config) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return cfg, nil
+	}
+
+	r := &moduleRefResolver{
+		modules:  make(map[string]map[string]any, len(cfg.Modules)),
+		resolved: make(map[string]moduleRefResult),
+		visiting: make(map[string]bool),
+	}
+	for i := range cfg.Modules {
+		r.modules[cfg.Modules[i].Name] = cfg.Modules[i].Config
+	}
+
+	var errs []error
+	seen := make(map[string]bool)
+	out := *cfg
+	out.Modules = make([]ModuleConfig, len(cfg.Modules))
+	for i, m := range cfg.Modules {
+		out.Modules[i] = m
+		if !containsModuleRef(m.Config) {
+			continue
+		}
+		resolved := make(map[string]any, len(m.Config))
+		for _, k := range slices.Sorted(maps.Keys(m.Config)) {
+			v, err := r.resolveNode(m.Name, []string{k})
+			if err != nil {
+				if !seen[err.Error()] {
+					seen[err.Error()] = true
+					errs = append(errs, err)
+				}
+				continue
+			}
+			resolved[k] = v
+		}
+		out.Modules[i].Config = resolved
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return &out, nil
+}
+
+// moduleRefResult caches the outcome of resolving one config node.
+type moduleRefResult struct {
+	value any
+	err   error
+}
+
+// moduleRefResolver resolves module config nodes on demand, memoizing each
+// node by module and path so shared values are resolved once.
+type moduleRefResolver struct {
+	modules  map[string]map[string]any
+	resolved map[string]moduleRefResult
+	visiting map[string]bool
+	stack    []string
+}
+
+// resolveNode returns the resolved value at path in the named module's config.
+func (r *moduleRefResolver) resolveNode(module string, path []string) (any, error) {
+	key := module + "\x00" + strings.Join(path, ".")
+	if res, ok := r.resolved[key]; ok {
+		return res.value, res.err
+	}
+	label := fmt.Sprintf("module %q config.%s", module, strings.Join(path, "."))
+	if r.visiting[key] {
+		start := 0
+		for i, s := range r.stack {
+			if s == label {
+				start = i
+				break
+			}
+		}
+		chain := append(append([]string{}, r.stack[start:]...), label)
+		return nil, fmt.Errorf("config reference cycle: %s", strings.Join(chain, " -> "))
+	}
+
+	raw, _ := lookupConfigPath(r.modules[module], path)
+	r.visiting[key] = true
+	r.stack = append(r.stack, label)
+	value, err := r.resolveValue(module, path, raw)
+	r.stack = r.stack[:len(r.stack)-1]
+	delete(r.visiting, key)
+
+	r.resolved[key] = moduleRefResult{value: value, err: err}
+	return value, err
+}
+
+// resolveValue resolves the references in raw, which sits at path in the
+// named module's config. Maps and slices are copied rather than modified.
+func (r *moduleRefResolver) resolveValue(module string, path []string, raw any) (any, error) {
+	switch val := raw.(type) {
+	case string:
+		return r.resolveString(module, path, val)
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for _, k := range slices.Sorted(maps.Keys(val)) {
+			v, err := r.resolveNode(module, append(path[:len(path):len(path)], k))
+			if err != nil {
+				return nil, err
+			}
+			out[k] = v
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			v, err := r.resolveValue(module, path, item)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = v
+		}
+		return out, nil
+	default:
+		return raw, nil
+	}
+}
+
+// resolveString substitutes the references in s.
+func (r *moduleRefResolver) resolveString(module string, path []string, s string) (any, error) {
+	matches := moduleRefPattern.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return s, nil
+	}
+	where := fmt.Sprintf("module %q: config.%s", module, strings.Join(path, "."))
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		ref := s[m[0]:m[1]]
+		target, keyPath := s[m[2]:m[3]], strings.Split(s[m[4]:m[5]], ".")
+		targetCfg, ok := r.modules[target]
+		if !ok {
+			return nil, fmt.Errorf("%s: %s references unknown module %q", where, ref, target)
+		}
+		if _, ok := lookupConfigPath(targetCfg, keyPath); !ok {
+			return nil, fmt.Errorf("%s: %s: module %q has no config key %q", where, ref, target, s[m[4]:m[5]])
+		}
+		v, err := r.resolveNode(target, keyPath)
+		if err != nil {
+			return nil, err
+		}
+		if m[0] == 0 && m[1] == len(s) {
+			return deepCopyValue(v), nil
+		}
+		switch v.(type) {
+		case map[string]any, []any:
+			return nil, fmt.Errorf("%s: %s is not a scalar and cannot be embedded in a string", where, ref)
+		}
+		b.WriteString(s[last:m[0]])
+		if v != nil {
+			fmt.Fprint(&b, v)
+		}
+		last = m[1]
+	}
+	b.WriteString(s[last:])
+	return b.String(), nil
+}
+
+// lookupConfigPath returns the value at a dotted path in cfg.
+func lookupConfigPath(cfg map[string]any, path []string) (any, bool) {
+	var cur any = cfg
+	for _, seg := range path {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = m[seg]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// containsModuleRef reports whether v holds a module config reference.
+func containsModuleRef(v any) bool {
+	switch val := v.(type) {
+	case string:
+		return moduleRefPattern.MatchString(val)
+	case map[string]any:
+		for _, item := range val {
+			if containsModuleRef(item) {
+				return true
+			}
+		}
+	case []any:
+		for _, item := range val {
+			if containsModuleRef(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
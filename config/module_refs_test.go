@@ -0,0 +1,181 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolveModuleConfigRefs(t *testing.T) {
+	cfg, err := LoadFromString(`
+modules:
+  - name: api
+    type: http.client
+    config:
+      baseUrl: https://api.example.com
+      timeout: 30
+      database:
+        table: orders
+        url: "${module.api.config.baseUrl}/db"
+  - name: orders
+    type: http.handler
+    config:
+      endpoint: "${module.api.config.baseUrl}/orders"
+      timeout: "${module.api.config.timeout}"
+      table: "${module.api.config.database.table}"
+      mirrors:
+        - "${module.api.config.database.url}"
+  - name: legacy
+    type: http.handler
+    enabled: false
+    config:
+      table: legacy_orders
+  - name: archive
+    type: http.handler
+    config:
+      table: "${module.legacy.config.table}"
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := ResolveModuleConfigRefs(cfg)
+	if err != nil {
+		t.Fatalf("ResolveModuleConfigRefs: %v", err)
+	}
+	orders := out.Modules[1].Config
+	if got := orders["endpoint"]; got != "https://api.example.com/orders" {
+		t.Errorf("endpoint = %v", got)
+	}
+	if got := orders["timeout"]; got != 30 {
+		t.Errorf("timeout = %#v, want int 30", got)
+	}
+	if got := orders["table"]; got != "orders" {
+		t.Errorf("table = %v", got)
+	}
+	if got := orders["mirrors"].([]any)[0]; got != "https://api.example.com/db" {
+		t.Errorf("mirrors[0] = %v", got)
+	}
+	if got := out.Modules[3].Config["table"]; got != "legacy_orders" {
+		t.Errorf("table from disabled module = %v", got)
+	}
+
+	// The caller's config keeps its references.
+	if got := cfg.Modules[1].Config["endpoint"]; got != "${module.api.config.baseUrl}/orders" {
+		t.Errorf("input config modified: endpoint = %v", got)
+	}
+}
+
+func TestResolveModuleConfigRefs_NoRefs(t *testing.T) {
+	cfg, err := LoadFromString(`
+modules:
+  - name: api
+    type: http.client
+    config:
+      baseUrl: ${BASE_URL}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := ResolveModuleConfigRefs(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != cfg {
+		t.Error("expected config without references to be returned unchanged")
+	}
+}
+
+func TestResolveModuleConfigRefs_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr []string
+	}{
+		{
+			name: "unknown module",
+			yaml: `
+modules:
+  - name: orders
+    type: http.handler
+    config:
+      endpoint: "${module.apii.config.baseUrl}/orders"
+`,
+			wantErr: []string{`module "orders": config.endpoint`, `unknown module "apii"`},
+		},
+		{
+			name: "missing key",
+			yaml: `
+modules:
+  - name: api
+    type: http.client
+    config:
+      baseUrl: https://api.example.com
+  - name: orders
+    type: http.handler
+    config:
+      endpoint: "${module.api.config.baseURL}"
+`,
+			wantErr: []string{`module "api" has no config key "baseURL"`},
+		},
+		{
+			name: "cycle",
+			yaml: `
+modules:
+  - name: a
+    type: http.client
+    config:
+      url: "${module.b.config.url}"
+  - name: b
+    type: http.client
+    config:
+      url: "${module.a.config.url}/b"
+`,
+			wantErr: []string{`config reference cycle: module "a" config.url -> module "b" config.url -> module "a" config.url`},
+		},
+		{
+			name: "self containing",
+			yaml: `
+modules:
+  - name: a
+    type: http.client
+    config:
+      database:
+        url: "${module.a.config.database}"
+`,
+			wantErr: []string{`config reference cycle`},
+		},
+		{
+			name: "map embedded in string",
+			yaml: `
+modules:
+  - name: a
+    type: http.client
+    config:
+      database:
+        table: orders
+      url: "db://${module.a.config.database}"
+`,
+			wantErr: []string{`not a scalar`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := LoadFromString(tt.yaml)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = ResolveModuleConfigRefs(cfg)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not contain %q", err, want)
+				}
+			}
+			if n := strings.Count(err.Error(), "\n"); n != 0 {
+				t.Errorf("expected a single error, got:\n%v", err)
+			}
+		})
+	}
+}
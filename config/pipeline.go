@@ -29,6 +29,38 @@ type PipelineConfig struct {
 	// value. Useful for catching typos in step field references at runtime.
 	// Default is false (missing keys produce a warning log and resolve to zero).
 	StrictTemplates bool `json:"strict_templates,omitempty" yaml:"strict_templates,omitempty"`
+	// Durable opts the pipeline into durable execution: before each step the
+	// executor journals the step and a snapshot of the pipeline context to
+	// the event store, so executions the server stopped in the middle of can
+	// be recovered on the next start. Nil (the default) disables it.
+	Durable *PipelineDurableConfig `json:"durable,omitempty" yaml:"durable,omitempty"`
+}
+
+// PipelineDurableConfig configures durable execution of a pipeline. The
+// presence of the block enables it; an empty block uses the defaults.
+type PipelineDurableConfig struct {
+	// Recovery is what the startup recovery pass does with an execution that
+	// was still running when the server stopped: "fail" (default) marks it
+	// interrupted, "resume" runs it again from the step it was on when every
+	// step it had started is marked resumable, and marks it interrupted
+	// otherwise.
+	Recovery string `json:"recovery,omitempty" yaml:"recovery,omitempty"`
+	// MaxSnapshotBytes bounds the JSON size of the context snapshot
+	// journaled before each step. A larger snapshot is not journaled, and an
+	// execution interrupted at that step cannot be resumed. Default 65536.
+	MaxSnapshotBytes int `json:"max_snapshot_bytes,omitempty" yaml:"max_snapshot_bytes,omitempty"`
+}
+
+// RouteScheduleConfig runs an HTTP route's inline pipeline on a cron
+// schedule, without an HTTP request. The schedule is validated whether or not
+// it is enabled.
+type RouteScheduleConfig struct {
+	// Cron is a cron expression or descriptor such as @hourly.
+	Cron string `json:"cron" yaml:"cron"`
+	// Timezone is the IANA zone Cron is evaluated in; empty means UTC.
+	Timezone string `json:"timezone,omitempty" yaml:"timezone,omitempty"`
+	// Enabled registers the schedule with the scheduler. Default false.
+	Enabled bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
 }
 
 // PipelineTriggerConfig defines what starts a pipeline.
@@ -57,4 +89,12 @@ type PipelineStepConfig struct {
 	// When set, the step error is wrapped in a ValidationError so the HTTP
 	// handler returns the specified status code instead of 500.
 	ErrorStatus int `json:"error_status,omitempty" yaml:"error_status,omitempty"`
+	// Destructive marks a step with side effects that should be confirmed
+	// before they happen. In a dry run the step is not executed and reports
+	// what it would have done instead; other steps run normally.
+	Destructive bool `json:"destructive,omitempty" yaml:"destructive,omitempty"`
+	// Resumable marks a step as safe to run again, e.g. because it is
+	// idempotent. A durable pipeline with recovery "resume" resumes an
+	// interrupted execution only when every step it had started is resumable.
+	Resumable bool `json:"resumable,omitempty" yaml:"resumable,omitempty"`
 }
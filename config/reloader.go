@@ -61,6 +61,15 @@ func (r *ConfigReloader) SetReconfigurer(reconfigurer ModuleReconfigurer) {
 	r.reconfigurer = reconfigurer
 }
 
+// Plan reports what HandleChange would do with cfg, without applying it.
+func (r *ConfigReloader) Plan(cfg *WorkflowConfig) *ReloadPlan {
+	r.mu.Lock()
+	current := r.current
+	reconfigurable := r.reconfigurer != nil
+	r.mu.Unlock()
+	return PlanReload(current, cfg, reconfigurable)
+}
+
 // HandleChange processes a config change event. It diffs the old and new configs,
 // attempts per-module reconfiguration for module-only changes, and falls back
 // to a full reload when necessary.
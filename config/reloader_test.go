@@ -524,3 +524,20 @@ type callbackReconfigurer struct {
 func (c *callbackReconfigurer) ReconfigureModules(ctx context.Context, changes []ModuleConfigChange) ([]string, error) {
 	return c.fn(ctx, changes)
 }
+
+func TestConfigReloader_Plan(t *testing.T) {
+	initial := makeConfig([]ModuleConfig{{Name: "alpha", Type: "http.server", Config: map[string]any{"port": 8080}}}, nil, nil, nil)
+	changed := makeConfig([]ModuleConfig{{Name: "alpha", Type: "http.server", Config: map[string]any{"port": 9090}}}, nil, nil, nil)
+	reloaded := false
+	r := newTestReloader(t, initial, func(*WorkflowConfig) error { reloaded = true; return nil }, nil)
+	if p := r.Plan(changed); p.Strategy != ReloadStrategyFull {
+		t.Errorf("without reconfigurer: strategy %q, want full", p.Strategy)
+	}
+	r.SetReconfigurer(&mockReconfigurer{})
+	if p := r.Plan(changed); p.Strategy != ReloadStrategyPartial {
+		t.Errorf("with reconfigurer: strategy %q, want partial", p.Strategy)
+	}
+	if reloaded {
+		t.Error("Plan must not reload")
+	}
+}
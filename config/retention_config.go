@@ -0,0 +1,173 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Retention actions.
+const (
+	RetentionActionDelete  = "delete"
+	RetentionActionArchive = "archive"
+)
+
+// RetentionConfig declares how long a workflow's persisted data is kept and
+// what happens to it afterwards. Each data kind is optional; kinds without a
+// rule are kept indefinitely.
+type RetentionConfig struct {
+	// Archive names the storage service that receives archived rows.
+	// Required when any rule uses action: archive.
+	Archive      *RetentionArchiveConfig `json:"archive,omitempty" yaml:"archive,omitempty"`
+	Executions   *RetentionRule          `json:"executions,omitempty" yaml:"executions,omitempty"`
+	DLQ          *RetentionRule          `json:"dlq,omitempty" yaml:"dlq,omitempty"`
+	Audit        *RetentionRule          `json:"audit,omitempty" yaml:"audit,omitempty"`
+	Idempotency  *RetentionRule          `json:"idempotency,omitempty" yaml:"idempotency,omitempty"`
+	StateHistory *RetentionRule          `json:"stateHistory,omitempty" yaml:"stateHistory,omitempty"`
+}
+
+// RetentionArchiveConfig selects where archived data is written.
+type RetentionArchiveConfig struct {
+	// Storage is the name of a storage service (e.g. a storage.local module).
+	Storage string `json:"storage" yaml:"storage"`
+	// Prefix is prepended to archive object paths. Defaults to "retention".
+	Prefix string `json:"prefix,omitempty" yaml:"prefix,omitempty"`
+}
+
+// RetentionRule keeps data for MaxAge, then applies Action.
+type RetentionRule struct {
+	// MaxAge accepts Go durations plus d (days), w (weeks), and y (365 days),
+	// e.g. "30d" or "7y".
+	MaxAge string `json:"maxAge" yaml:"maxAge"`
+	// Action is "delete" (default) or "archive".
+	Action string `json:"action,omitempty" yaml:"action,omitempty"`
+}
+
+// RetentionPolicy is a retention block resolved to the workflow that owns
+// it: the pipelines and state machine definitions whose data it governs.
+type RetentionPolicy struct {
+	Workflow      string           `json:"workflow"`
+	Pipelines     []string         `json:"pipelines"`
+	StateMachines []string         `json:"stateMachines,omitempty"`
+	Retention     *RetentionConfig `json:"retention"`
+}
+
+// Rules returns the configured rules keyed by data kind name (executions,
+// dlq, audit, idempotency, state_history).
+func (r *RetentionConfig) Rules() map[string]*RetentionRule {
+	rules := make(map[string]*RetentionRule)
+	if r == nil {
+		return rules
+	}
+	for kind, rule := range map[string]*RetentionRule{
+		"executions":    r.Executions,
+		"dlq":           r.DLQ,
+		"audit":         r.Audit,
+		"idempotency":   r.Idempotency,
+		"state_history": r.StateHistory,
+	} {
+		if rule != nil {
+			rules[kind] = rule
+		}
+	}
+	return rules
+}
+
+// ValidateRetention checks the retention: section.
+func ValidateRetention(r *RetentionConfig) error {
+	if r == nil {
+		return nil
+	}
+	var errs []error
+	kinds := make([]string, 0, 5)
+	rules := r.Rules()
+	for kind := range rules {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	archives := false
+	for _, kind := range kinds {
+		rule := rules[kind]
+		if rule.MaxAge == "" {
+			errs = append(errs, fmt.Errorf("retention.%s.maxAge is required", kind))
+		} else if d, err := ParseRetentionAge(rule.MaxAge); err != nil {
+			errs = append(errs, fmt.Errorf("retention.%s.maxAge: %w", kind, err))
+		} else if d <= 0 {
+			errs = append(errs, fmt.Errorf("retention.%s.maxAge must be positive", kind))
+		}
+		switch rule.Action {
+		case "", RetentionActionDelete:
+		case RetentionActionArchive:
+			archives = true
+		default:
+			errs = append(errs, fmt.Errorf("retention.%s.action=%q is not valid (valid: delete, archive)", kind, rule.Action))
+		}
+	}
+	if archives && (r.Archive == nil || r.Archive.Storage == "") {
+		errs = append(errs, fmt.Errorf("retention.archive.storage is required when a rule uses action: archive"))
+	}
+	return errors.Join(errs...)
+}
+
+// ParseRetentionAge parses a retention age. In addition to Go durations it
+// accepts whole-number d (days), w (weeks), and y (365-day years) suffixes.
+func ParseRetentionAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	units := map[byte]time.Duration{'d': 24 * time.Hour, 'w': 7 * 24 * time.Hour, 'y': 365 * 24 * time.Hour}
+	if n := len(s); n > 1 {
+		if unit, ok := units[s[n-1]]; ok {
+			count, err := strconv.Atoi(s[:n-1])
+			if err != nil {
+				return 0, fmt.Errorf("invalid age %q", s)
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid age %q", s)
+	}
+	return d, nil
+}
+
+// NewRetentionPolicy resolves cfg's retention block to the workflow named
+// name. The workflow owns every pipeline and state machine definition
+// declared in cfg. It returns nil when cfg has no retention block.
+func NewRetentionPolicy(name string, cfg *WorkflowConfig) *RetentionPolicy {
+	if cfg == nil || cfg.Retention == nil {
+		return nil
+	}
+	p := &RetentionPolicy{Workflow: name, Pipelines: []string{}, Retention: cfg.Retention}
+	for pipeline := range cfg.Pipelines {
+		p.Pipelines = append(p.Pipelines, pipeline)
+	}
+	sort.Strings(p.Pipelines)
+	if sm, ok := cfg.Workflows["statemachine"].(map[string]any); ok {
+		defs, _ := sm["definitions"].([]any)
+		for _, d := range defs {
+			if def, ok := d.(map[string]any); ok {
+				if defName, _ := def["name"].(string); defName != "" {
+					p.StateMachines = append(p.StateMachines, defName)
+				}
+			}
+		}
+		sort.Strings(p.StateMachines)
+	}
+	return p
+}
+
+// EffectiveRetentionPolicies returns the per-workflow retention policies for
+// cfg. A merged application config carries one policy per workflow file; a
+// single workflow config yields one policy named defaultName.
+func (cfg *WorkflowConfig) EffectiveRetentionPolicies(defaultName string) []*RetentionPolicy {
+	if len(cfg.RetentionPolicies) > 0 {
+		return cfg.RetentionPolicies
+	}
+	if p := NewRetentionPolicy(defaultName, cfg); p != nil {
+		return []*RetentionPolicy{p}
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRetentionAge(t *testing.T) {
+	tests := map[string]time.Duration{
+		"30d":  30 * 24 * time.Hour,
+		"2w":   14 * 24 * time.Hour,
+		"7y":   7 * 365 * 24 * time.Hour,
+		"36h":  36 * time.Hour,
+		" 1d ": 24 * time.Hour,
+	}
+	for in, want := range tests {
+		got, err := ParseRetentionAge(in)
+		if err != nil {
+			t.Errorf("ParseRetentionAge(%q): %v", in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("ParseRetentionAge(%q) = %v, want %v", in, got, want)
+		}
+	}
+	for _, in := range []string{"", "d", "1.5d", "thirty days"} {
+		if _, err := ParseRetentionAge(in); err == nil {
+			t.Errorf("ParseRetentionAge(%q): expected error", in)
+		}
+	}
+}
+
+func TestValidateRetention(t *testing.T) {
+	cfg, err := LoadFromString(`
+retention:
+  executions:
+    maxAge: 30d
+  dlq:
+    maxAge: 1w
+    action: archive
+  audit:
+    action: shred
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ValidateRetention(cfg.Retention)
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	for _, want := range []string{
+		"retention.audit.maxAge is required",
+		`retention.audit.action="shred" is not valid`,
+		"retention.archive.storage is required",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+
+	cfg.Retention.Audit = &RetentionRule{MaxAge: "7y"}
+	cfg.Retention.Archive = &RetentionArchiveConfig{Storage: "archive"}
+	if err := ValidateRetention(cfg.Retention); err != nil {
+		t.Errorf("expected valid retention, got %v", err)
+	}
+}
+
+func TestMergeApplicationConfig_RetentionPolicies(t *testing.T) {
+	dir := t.TempDir()
+	const orders = `
+pipelines:
+  create-order:
+    steps: []
+  refund-order:
+    steps: []
+workflows:
+  statemachine:
+    engine: orders-engine
+    definitions:
+      - name: order-lifecycle
+retention:
+  executions:
+    maxAge: 30d
+  stateHistory:
+    maxAge: 90d
+`
+	const reports = `
+pipelines:
+  nightly-report:
+    steps: []
+`
+	if err := writeFileContent(dir+"/orders.yaml", orders); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFileContent(dir+"/reports.yaml", reports); err != nil {
+		t.Fatal(err)
+	}
+	appCfg := &ApplicationConfig{
+		ConfigDir: dir,
+		Application: ApplicationInfo{
+			Name: "shop",
+			Workflows: []WorkflowRef{
+				{File: "orders.yaml"},
+				{File: "reports.yaml", Name: "reports"},
+			},
+		},
+	}
+
+	combined, err := MergeApplicationConfig(appCfg)
+	if err != nil {
+		t.Fatalf("MergeApplicationConfig failed: %v", err)
+	}
+	if len(combined.RetentionPolicies) != 1 {
+		t.Fatalf("expected 1 retention policy, got %d", len(combined.RetentionPolicies))
+	}
+	p := combined.RetentionPolicies[0]
+	if p.Workflow != "orders" {
+		t.Errorf("workflow = %q, want orders", p.Workflow)
+	}
+	if want := []string{"create-order", "refund-order"}; !reflect.DeepEqual(p.Pipelines, want) {
+		t.Errorf("pipelines = %v, want %v", p.Pipelines, want)
+	}
+	if want := []string{"order-lifecycle"}; !reflect.DeepEqual(p.StateMachines, want) {
+		t.Errorf("state machines = %v, want %v", p.StateMachines, want)
+	}
+	if got := combined.EffectiveRetentionPolicies("ignored"); len(got) != 1 || got[0].Workflow != "orders" {
+		t.Errorf("EffectiveRetentionPolicies = %+v", got)
+	}
+}
+
+func TestMergeApplicationConfig_InvalidRetention(t *testing.T) {
+	dir := t.TempDir()
+	if err := writeFileContent(dir+"/orders.yaml", "retention:\n  dlq:\n    maxAge: soon\n"); err != nil {
+		t.Fatal(err)
+	}
+	appCfg := &ApplicationConfig{
+		ConfigDir:   dir,
+		Application: ApplicationInfo{Name: "shop", Workflows: []WorkflowRef{{File: "orders.yaml"}}},
+	}
+	_, err := MergeApplicationConfig(appCfg)
+	if err == nil || !strings.Contains(err.Error(), `workflow "orders"`) {
+		t.Fatalf("expected retention error naming the workflow, got %v", err)
+	}
+}
+
+func TestEffectiveRetentionPolicies_SingleConfig(t *testing.T) {
+	cfg, err := LoadFromString(`
+pipelines:
+  signup:
+    steps: []
+retention:
+  audit:
+    maxAge: 7y
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	policies := cfg.EffectiveRetentionPolicies("accounts")
+	if len(policies) != 1 {
+		t.Fatalf("expected 1 policy, got %d", len(policies))
+	}
+	if policies[0].Workflow != "accounts" || !reflect.DeepEqual(policies[0].Pipelines, []string{"signup"}) {
+		t.Errorf("unexpected policy %+v", policies[0])
+	}
+
+	if got := NewEmptyWorkflowConfig().EffectiveRetentionPolicies("x"); got != nil {
+		t.Errorf("expected no policies without a retention block, got %v", got)
+	}
+}
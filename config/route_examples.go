@@ -0,0 +1,225 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+)
+
+// RouteExample is a named request/response pair declared in the `examples`
+// block of an HTTP workflow route or of an HTTP trigger's config:
+//
+//	examples:
+//	  - name: created
+//	    summary: A new order
+//	    request:
+//	      query: {dry_run: "false"}
+//	      body: {sku: A-1, quantity: 2}
+//	    response:
+//	      status: 201
+//	      headers: {Location: /orders/42}
+//	      body: {id: 42, status: pending}
+//
+// The response status defaults to 200. Examples feed the generated OpenAPI
+// spec, the mock server of `wfctl run --mock` and LSP hovers.
+type RouteExample struct {
+	Name     string
+	Summary  string
+	Request  *RouteExampleRequest
+	Response RouteExampleResponse
+}
+
+// RouteExampleRequest is the request half of a RouteExample.
+type RouteExampleRequest struct {
+	Headers map[string]string
+	Query   map[string]string
+	Body    any
+}
+
+// RouteExampleResponse is the response half of a RouteExample.
+type RouteExampleResponse struct {
+	Status  int
+	Headers map[string]string
+	Body    any
+}
+
+// ParseRouteExamples parses the raw `examples` list of a route. A nil list
+// has no examples. Names must be unique, and unknown keys are rejected so
+// that typos do not silently drop part of an example.
+func ParseRouteExamples(raw any) ([]RouteExample, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	list, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("examples must be a list")
+	}
+	out := make([]RouteExample, 0, len(list))
+	seen := make(map[string]bool, len(list))
+	for i, entry := range list {
+		m, ok := entry.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("examples[%d]: must be a map", i)
+		}
+		ex, err := parseRouteExample(m)
+		if err != nil {
+			return nil, fmt.Errorf("examples[%d]: %w", i, err)
+		}
+		if seen[ex.Name] {
+			return nil, fmt.Errorf("examples[%d]: duplicate name %q", i, ex.Name)
+		}
+		seen[ex.Name] = true
+		out = append(out, ex)
+	}
+	return out, nil
+}
+
+func parseRouteExample(m map[string]any) (RouteExample, error) {
+	var ex RouteExample
+	if err := checkExampleKeys(m, "name", "summary", "request", "response"); err != nil {
+		return ex, err
+	}
+	ex.Name, _ = m["name"].(string)
+	if ex.Name == "" {
+		return ex, fmt.Errorf("'name' is required")
+	}
+	if v, ok := m["summary"]; ok {
+		if ex.Summary, ok = v.(string); !ok {
+			return ex, fmt.Errorf("example %q: 'summary' must be a string", ex.Name)
+		}
+	}
+
+	if v, ok := m["request"]; ok && v != nil {
+		req, ok := v.(map[string]any)
+		if !ok {
+			return ex, fmt.Errorf("example %q: 'request' must be a map", ex.Name)
+		}
+		if err := checkExampleKeys(req, "headers", "query", "body"); err != nil {
+			return ex, fmt.Errorf("example %q: request: %w", ex.Name, err)
+		}
+		ex.Request = &RouteExampleRequest{Body: req["body"]}
+		var err error
+		if ex.Request.Headers, err = exampleStringMap(req["headers"]); err != nil {
+			return ex, fmt.Errorf("example %q: request headers: %w", ex.Name, err)
+		}
+		if ex.Request.Query, err = exampleStringMap(req["query"]); err != nil {
+			return ex, fmt.Errorf("example %q: request query: %w", ex.Name, err)
+		}
+	}
+
+	resp, ok := m["response"].(map[string]any)
+	if !ok {
+		return ex, fmt.Errorf("example %q: 'response' is required and must be a map", ex.Name)
+	}
+	if err := checkExampleKeys(resp, "status", "headers", "body"); err != nil {
+		return ex, fmt.Errorf("example %q: response: %w", ex.Name, err)
+	}
+	ex.Response.Status = http.StatusOK
+	if v, ok := resp["status"]; ok {
+		status, ok := exampleStatus(v)
+		if !ok {
+			return ex, fmt.Errorf("example %q: response status must be an HTTP status code, got %v", ex.Name, v)
+		}
+		ex.Response.Status = status
+	}
+	var err error
+	if ex.Response.Headers, err = exampleStringMap(resp["headers"]); err != nil {
+		return ex, fmt.Errorf("example %q: response headers: %w", ex.Name, err)
+	}
+	ex.Response.Body = resp["body"]
+	return ex, nil
+}
+
+func checkExampleKeys(m map[string]any, allowed ...string) error {
+	var unknown []string
+	for k := range m {
+		known := false
+		for _, a := range allowed {
+			if k == a {
+				known = true
+				break
+			}
+		}
+		if !known {
+			unknown = append(unknown, k)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown key %q", unknown[0])
+	}
+	return nil
+}
+
+// exampleStringMap converts a map of scalars to a map of strings.
+func exampleStringMap(v any) (map[string]string, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if m, ok := v.(map[string]string); ok {
+		return m, nil
+	}
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("must be a map")
+	}
+	out := make(map[string]string, len(m))
+	for k, val := range m {
+		switch val.(type) {
+		case map[string]any, []any, nil:
+			return nil, fmt.Errorf("%s: must be a scalar", k)
+		}
+		out[k] = fmt.Sprint(val)
+	}
+	return out, nil
+}
+
+func exampleStatus(v any) (int, bool) {
+	var status int
+	switch n := v.(type) {
+	case int:
+		status = n
+	case int64:
+		status = int(n)
+	case float64:
+		if n != math.Trunc(n) {
+			return 0, false
+		}
+		status = int(n)
+	default:
+		return 0, false
+	}
+	return status, status >= 100 && status <= 599
+}
+
+// ConfigMap returns the example in the form ParseRouteExamples reads, for
+// writing examples back into a config file.
+func (e RouteExample) ConfigMap() map[string]any {
+	m := map[string]any{"name": e.Name}
+	if e.Summary != "" {
+		m["summary"] = e.Summary
+	}
+	if e.Request != nil {
+		req := map[string]any{}
+		if len(e.Request.Headers) > 0 {
+			req["headers"] = e.Request.Headers
+		}
+		if len(e.Request.Query) > 0 {
+			req["query"] = e.Request.Query
+		}
+		if e.Request.Body != nil {
+			req["body"] = e.Request.Body
+		}
+		m["request"] = req
+	}
+	resp := map[string]any{"status": e.Response.Status}
+	if len(e.Response.Headers) > 0 {
+		resp["headers"] = e.Response.Headers
+	}
+	if e.Response.Body != nil {
+		resp["body"] = e.Response.Body
+	}
+	m["response"] = resp
+	return m
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRouteExamples(t *testing.T) {
+	raw := []any{
+		map[string]any{
+			"name":    "created",
+			"summary": "A new order",
+			"request": map[string]any{
+				"query": map[string]any{"dry_run": false},
+				"body":  map[string]any{"sku": "A-1"},
+			},
+			"response": map[string]any{
+				"status":  201,
+				"headers": map[string]any{"Location": "/orders/42"},
+				"body":    map[string]any{"id": 42},
+			},
+		},
+		map[string]any{"name": "default", "response": map[string]any{}},
+	}
+	examples, err := ParseRouteExamples(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(examples) != 2 {
+		t.Fatalf("examples = %d", len(examples))
+	}
+	ex := examples[0]
+	if ex.Name != "created" || ex.Summary != "A new order" || ex.Response.Status != 201 {
+		t.Errorf("example = %+v", ex)
+	}
+	if ex.Request.Query["dry_run"] != "false" || ex.Response.Headers["Location"] != "/orders/42" {
+		t.Errorf("query/headers = %v %v", ex.Request.Query, ex.Response.Headers)
+	}
+	if examples[1].Response.Status != 200 || examples[1].Request != nil {
+		t.Errorf("default example = %+v", examples[1])
+	}
+
+	// ConfigMap round-trips.
+	again, err := ParseRouteExamples([]any{ex.ConfigMap()})
+	if err != nil || !reflect.DeepEqual(again[0], ex) {
+		t.Errorf("round trip = %+v, %v", again, err)
+	}
+}
+
+func TestParseRouteExamples_Errors(t *testing.T) {
+	resp := map[string]any{"status": 200}
+	tests := []struct {
+		name string
+		raw  any
+		want string
+	}{
+		{"not a list", map[string]any{}, "must be a list"},
+		{"no name", []any{map[string]any{"response": resp}}, "'name' is required"},
+		{"duplicate", []any{map[string]any{"name": "a", "response": resp}, map[string]any{"name": "a", "response": resp}}, "duplicate name"},
+		{"no response", []any{map[string]any{"name": "a"}}, "'response' is required"},
+		{"bad status", []any{map[string]any{"name": "a", "response": map[string]any{"status": 42}}}, "HTTP status code"},
+		{"unknown key", []any{map[string]any{"name": "a", "respone": resp}}, `unknown key "respone"`},
+		{"unknown request key", []any{map[string]any{"name": "a", "request": map[string]any{"bdy": 1}, "response": resp}}, `request: unknown key "bdy"`},
+		{"nested header", []any{map[string]any{"name": "a", "response": map[string]any{"headers": map[string]any{"X": []any{1}}}}}, "must be a scalar"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRouteExamples(tt.raw)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
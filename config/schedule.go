@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ScheduleJobConfig is one cron job declared in a workflow config, either in
+// triggers.schedule.jobs or as a pipeline trigger of type schedule.
+type ScheduleJobConfig struct {
+	// Path locates the job in the config, e.g. "triggers.schedule.jobs[0]" or
+	// "pipelines.nightly.trigger.config". Validation errors are reported
+	// against Path + ".cron" and Path + ".timezone".
+	Path     string
+	Pipeline string // set for pipeline triggers
+	Workflow string
+	Action   string
+	Cron     string
+	// Timezone is the IANA zone the cron fields are evaluated in; empty
+	// means UTC.
+	Timezone string
+}
+
+// ScheduleJobs returns every scheduled job declared in the config. Jobs from
+// triggers.schedule come first in declaration order, followed by pipeline
+// triggers sorted by pipeline name. Entries that are not maps are skipped;
+// structural problems are left to the trigger's own configuration.
+func (c *WorkflowConfig) ScheduleJobs() []ScheduleJobConfig {
+	var jobs []ScheduleJobConfig
+
+	if trig, ok := c.Triggers["schedule"].(map[string]any); ok {
+		list, _ := trig["jobs"].([]any)
+		for i, raw := range list {
+			m, ok := raw.(map[string]any)
+			if !ok {
+				continue
+			}
+			job := scheduleJobFromMap(fmt.Sprintf("triggers.schedule.jobs[%d]", i), m)
+			job.Workflow, _ = m["workflow"].(string)
+			job.Action, _ = m["action"].(string)
+			jobs = append(jobs, job)
+		}
+	}
+
+	names := make([]string, 0, len(c.Pipelines))
+	for name := range c.Pipelines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		pipeline, _ := c.Pipelines[name].(map[string]any)
+		trigger, _ := pipeline["trigger"].(map[string]any)
+		if t, _ := trigger["type"].(string); t != "schedule" {
+			continue
+		}
+		cfg, _ := trigger["config"].(map[string]any)
+		job := scheduleJobFromMap(fmt.Sprintf("pipelines.%s.trigger.config", name), cfg)
+		job.Pipeline = name
+		job.Workflow = "pipeline:" + name
+		jobs = append(jobs, job)
+	}
+	return jobs
+}
+
+func scheduleJobFromMap(path string, m map[string]any) ScheduleJobConfig {
+	job := ScheduleJobConfig{Path: path}
+	job.Cron, _ = m["cron"].(string)
+	job.Timezone, _ = m["timezone"].(string)
+	return job
+}
@@ -0,0 +1,35 @@
+package config
+
+import "testing"
+
+func TestScheduleJobs(t *testing.T) {
+	cfg := &WorkflowConfig{
+		Triggers: map[string]any{
+			"schedule": map[string]any{
+				"jobs": []any{
+					map[string]any{"cron": "@hourly", "workflow": "sync", "action": "run", "timezone": "Europe/Berlin"},
+					"not-a-job",
+				},
+			},
+		},
+		Pipelines: map[string]any{
+			"b-nightly": map[string]any{"trigger": map[string]any{"type": "schedule", "config": map[string]any{"cron": "0 2 * * *"}}},
+			"a-webhook": map[string]any{"trigger": map[string]any{"type": "http"}},
+			"a-weekly":  map[string]any{"trigger": map[string]any{"type": "schedule", "config": map[string]any{"cron": "@weekly", "timezone": "UTC"}}},
+		},
+	}
+	want := []ScheduleJobConfig{
+		{Path: "triggers.schedule.jobs[0]", Workflow: "sync", Action: "run", Cron: "@hourly", Timezone: "Europe/Berlin"},
+		{Path: "pipelines.a-weekly.trigger.config", Pipeline: "a-weekly", Workflow: "pipeline:a-weekly", Cron: "@weekly", Timezone: "UTC"},
+		{Path: "pipelines.b-nightly.trigger.config", Pipeline: "b-nightly", Workflow: "pipeline:b-nightly", Cron: "0 2 * * *"},
+	}
+	got := cfg.ScheduleJobs()
+	if len(got) != len(want) {
+		t.Fatalf("got %d jobs, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("job %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
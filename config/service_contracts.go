@@ -0,0 +1,161 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ServiceContract names a service a module provides or needs. Name is the
+// service registry name; Interface is a hint naming the Go interface the
+// service implements (e.g. "http.Handler"). A need with only an Interface
+// matches the providers declaring the same Interface.
+//
+// In YAML a contract is either a mapping or a bare service name:
+//
+//	provides:
+//	  - orders-db
+//	needs:
+//	  - name: event-broker
+//	    interface: module.MessageBroker
+type ServiceContract struct {
+	Name      string `json:"name,omitempty" yaml:"name,omitempty"`
+	Interface string `json:"interface,omitempty" yaml:"interface,omitempty"`
+}
+
+// UnmarshalYAML accepts a bare service name as well as a mapping.
+func (c *ServiceContract) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*c = ServiceContract{Name: value.Value}
+		return nil
+	}
+	type plain ServiceContract
+	return value.Decode((*plain)(c))
+}
+
+// UnmarshalJSON accepts a bare service name as well as an object.
+func (c *ServiceContract) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*c = ServiceContract{Name: name}
+		return nil
+	}
+	type plain ServiceContract
+	return json.Unmarshal(data, (*plain)(c))
+}
+
+// String returns the contract as name, name (interface) or (interface).
+func (c ServiceContract) String() string {
+	switch {
+	case c.Interface == "":
+		return c.Name
+	case c.Name == "":
+		return "(" + c.Interface + ")"
+	default:
+		return c.Name + " (" + c.Interface + ")"
+	}
+}
+
+// ModuleContracts are the services one module provides and needs.
+type ModuleContracts struct {
+	Provides []ServiceContract
+	Needs    []ServiceContract
+}
+
+// ServiceWiring is one resolved need: Consumer needs Need, which Provider
+// provides as Service.
+type ServiceWiring struct {
+	Consumer string          `json:"consumer"`
+	Need     ServiceContract `json:"need"`
+	Provider string          `json:"provider"`
+	Service  ServiceContract `json:"service"`
+}
+
+// HasServiceContracts reports whether any module declares provides or needs.
+func HasServiceContracts(modules []ModuleConfig) bool {
+	for i := range modules {
+		if len(modules[i].Provides) > 0 || len(modules[i].Needs) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveServiceContracts matches every need of modules to the one provides
+// that satisfies it. Contracts declared in each module's config are combined
+// with extra, keyed by module name, which holds contracts modules declare in
+// code. A need matches the provides of the same Name, or, when the need has
+// no Name, of the same Interface; a need and provide that both set an
+// Interface must agree on it. Every need that matches no provides, or
+// provides of several modules, is reported in the joined error. Wirings are
+// returned in module order.
+func ResolveServiceContracts(modules []ModuleConfig, extra map[string]ModuleContracts) ([]ServiceWiring, error) {
+	type provider struct {
+		module   string
+		contract ServiceContract
+	}
+	contracts := make([]ModuleContracts, len(modules))
+	var providers []provider
+	for i := range modules {
+		c := ModuleContracts{
+			Provides: append(append([]ServiceContract(nil), modules[i].Provides...), extra[modules[i].Name].Provides...),
+			Needs:    append(append([]ServiceContract(nil), modules[i].Needs...), extra[modules[i].Name].Needs...),
+		}
+		contracts[i] = c
+		for _, p := range c.Provides {
+			providers = append(providers, provider{module: modules[i].Name, contract: p})
+		}
+	}
+
+	var wirings []ServiceWiring
+	var errs []error
+	for i := range modules {
+		consumer := modules[i].Name
+		for _, need := range contracts[i].Needs {
+			if need.Name == "" && need.Interface == "" {
+				errs = append(errs, fmt.Errorf("module %q: needs entry must set name or interface", consumer))
+				continue
+			}
+			var matches []provider
+			for _, p := range providers {
+				if need.Name != "" && p.contract.Name != need.Name {
+					continue
+				}
+				if need.Name == "" && p.contract.Interface != need.Interface {
+					continue
+				}
+				matches = append(matches, p)
+			}
+			// A module declaring the same service in config and in code is
+			// still one provider.
+			owners := make(map[string]struct{}, len(matches))
+			for _, m := range matches {
+				owners[m.module] = struct{}{}
+			}
+			switch {
+			case len(owners) == 0:
+				errs = append(errs, fmt.Errorf("module %q needs service %s: no module provides it", consumer, need))
+				continue
+			case len(owners) > 1:
+				names := make([]string, 0, len(owners))
+				for name := range owners {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				errs = append(errs, fmt.Errorf("module %q needs service %s: provided by multiple modules: %s", consumer, need, strings.Join(names, ", ")))
+				continue
+			}
+			match := matches[0]
+			if need.Interface != "" && match.contract.Interface != "" && need.Interface != match.contract.Interface {
+				errs = append(errs, fmt.Errorf("module %q needs service %s: module %q provides it as %s", consumer, need, match.module, match.contract.Interface))
+				continue
+			}
+			wirings = append(wirings, ServiceWiring{Consumer: consumer, Need: need, Provider: match.module, Service: match.contract})
+		}
+	}
+	return wirings, errors.Join(errs...)
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestServiceContractsYAML(t *testing.T) {
+	cfg, err := LoadFromString(`
+modules:
+  - name: db
+    type: database.workflow
+    provides:
+      - orders-db
+  - name: api
+    type: http.handler
+    needs:
+      - name: orders-db
+        interface: module.DBProvider
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Modules[0].Provides; len(got) != 1 || got[0] != (ServiceContract{Name: "orders-db"}) {
+		t.Errorf("provides = %+v", got)
+	}
+	if got := cfg.Modules[1].Needs; len(got) != 1 || got[0] != (ServiceContract{Name: "orders-db", Interface: "module.DBProvider"}) {
+		t.Errorf("needs = %+v", got)
+	}
+
+	var contracts []ServiceContract
+	if err := json.Unmarshal([]byte(`["bus", {"interface": "module.MessageBroker"}]`), &contracts); err != nil {
+		t.Fatal(err)
+	}
+	if contracts[0] != (ServiceContract{Name: "bus"}) || contracts[1] != (ServiceContract{Interface: "module.MessageBroker"}) {
+		t.Errorf("JSON contracts = %+v", contracts)
+	}
+}
+
+func TestResolveServiceContracts(t *testing.T) {
+	modules := []ModuleConfig{
+		{Name: "api", Needs: []ServiceContract{{Name: "orders-db"}, {Interface: "module.MessageBroker"}}},
+		{Name: "db", Provides: []ServiceContract{{Name: "orders-db", Interface: "module.DBProvider"}}},
+		{Name: "worker", Needs: []ServiceContract{{Name: "orders-db", Interface: "module.DBProvider"}}},
+	}
+	// The broker is declared in code and in config by the same module.
+	extra := map[string]ModuleContracts{
+		"bus": {Provides: []ServiceContract{{Name: "bus", Interface: "module.MessageBroker"}}},
+	}
+	modules = append(modules, ModuleConfig{Name: "bus", Provides: []ServiceContract{{Name: "bus2", Interface: "module.MessageBroker"}}})
+
+	wiring, err := ResolveServiceContracts(modules, extra)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]string, 0, len(wiring))
+	for _, w := range wiring {
+		got = append(got, w.Consumer+" "+w.Need.String()+" -> "+w.Provider)
+	}
+	want := "api orders-db -> db|api (module.MessageBroker) -> bus|worker orders-db (module.DBProvider) -> db"
+	if strings.Join(got, "|") != want {
+		t.Errorf("wiring = %q, want %q", strings.Join(got, "|"), want)
+	}
+}
+
+func TestResolveServiceContractsErrors(t *testing.T) {
+	modules := []ModuleConfig{
+		{Name: "api", Needs: []ServiceContract{{Name: "cache"}, {Interface: "module.DBProvider"}, {Name: "bus", Interface: "http.Handler"}, {}}},
+		{Name: "db-a", Provides: []ServiceContract{{Name: "a", Interface: "module.DBProvider"}}},
+		{Name: "db-b", Provides: []ServiceContract{{Name: "b", Interface: "module.DBProvider"}}},
+		{Name: "broker", Provides: []ServiceContract{{Name: "bus", Interface: "module.MessageBroker"}}},
+	}
+	_, err := ResolveServiceContracts(modules, nil)
+	if err == nil {
+		t.Fatal("expected errors")
+	}
+	for _, want := range []string{
+		`module "api" needs service cache: no module provides it`,
+		`module "api" needs service (module.DBProvider): provided by multiple modules: db-a, db-b`,
+		`module "api" needs service bus (http.Handler): module "broker" provides it as module.MessageBroker`,
+		`module "api": needs entry must set name or interface`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+}
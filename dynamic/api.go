@@ -3,15 +3,21 @@ package dynamic
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"strings"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/sandbox"
 )
 
 // APIHandler exposes HTTP endpoints for managing dynamic components.
 type APIHandler struct {
 	loader   *Loader
 	registry *ComponentRegistry
+	preview  sandbox.PreviewPolicy
 }
 
 // NewAPIHandler creates a new API handler.
@@ -19,12 +25,54 @@ func NewAPIHandler(loader *Loader, registry *ComponentRegistry) *APIHandler {
 	return &APIHandler{
 		loader:   loader,
 		registry: registry,
+		preview:  sandbox.DefaultPreviewPolicy(),
 	}
 }
 
-// loadComponentRequest is the JSON body for loading/updating a component.
+// loadComponentRequest is the JSON body for loading/updating a component:
+// either a single Source or Files, a map of file name to source.
 type loadComponentRequest struct {
-	Source string `json:"source"`
+	ID     string            `json:"id,omitempty"`
+	Source string            `json:"source,omitempty"`
+	Files  map[string]string `json:"files,omitempty"`
+}
+
+// maxComponentUploadBytes bounds a component upload body. Zip archives are
+// additionally bounded by their uncompressed size in FilesFromZip.
+const maxComponentUploadBytes = 2 * MaxComponentBytes
+
+// readComponentRequest reads a create or update body. A body sent as
+// application/zip is a zip archive of the component's files, with the ID (on
+// create) taken from the id query parameter; anything else is a JSON
+// loadComponentRequest. The returned files hold exactly one source form.
+func readComponentRequest(w http.ResponseWriter, r *http.Request) (string, map[string]string, error) {
+	defer func() { _ = r.Body.Close() }()
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxComponentUploadBytes))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read body: %w", err)
+	}
+
+	if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt == "application/zip" {
+		files, err := FilesFromZip(body)
+		if err != nil {
+			return "", nil, err
+		}
+		return r.URL.Query().Get("id"), files, nil
+	}
+
+	var req loadComponentRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return "", nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	switch {
+	case req.Source != "" && len(req.Files) > 0:
+		return "", nil, fmt.Errorf("set either source or files, not both")
+	case req.Source != "":
+		return req.ID, map[string]string{SingleFileName: req.Source}, nil
+	case len(req.Files) > 0:
+		return req.ID, req.Files, nil
+	}
+	return req.ID, nil, nil
 }
 
 // RegisterRoutes registers the dynamic component API routes on the given mux.
@@ -59,13 +107,21 @@ func (h *APIHandler) HandleComponents(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// HandleComponentByID handles GET/PUT/DELETE for a component by ID.
-// The ID is extracted as the last path segment, making this work with any
-// URL prefix (e.g. /api/dynamic/components/{id} or /api/v1/admin/components/{id}).
+// HandleComponentByID handles GET/PUT/DELETE for a component by ID, and
+// POST {id}/preview. The ID is extracted as the last path segment, making this
+// work with any URL prefix (e.g. /api/dynamic/components/{id} or
+// /api/v1/admin/components/{id}).
 func (h *APIHandler) HandleComponentByID(w http.ResponseWriter, r *http.Request) {
 	// Extract ID as last path segment (works with any URL prefix)
 	path := strings.TrimRight(r.URL.Path, "/")
 	id := path[strings.LastIndex(path, "/")+1:]
+	if r.Method == http.MethodPost && id == "preview" {
+		path = strings.TrimSuffix(path, "/preview")
+		if id = path[strings.LastIndex(path, "/")+1:]; id != "" && id != "components" {
+			h.previewComponent(w, r, id)
+			return
+		}
+	}
 	if id == "" || id == "components" {
 		http.Error(w, "component id required", http.StatusBadRequest)
 		return
@@ -89,28 +145,17 @@ func (h *APIHandler) listComponents(w http.ResponseWriter, _ *http.Request) {
 }
 
 func (h *APIHandler) createComponent(w http.ResponseWriter, r *http.Request) {
-	body, err := io.ReadAll(r.Body)
+	id, files, err := readComponentRequest(w, r)
 	if err != nil {
-		http.Error(w, "failed to read body", http.StatusBadRequest)
-		return
-	}
-	defer func() { _ = r.Body.Close() }()
-
-	// Expect JSON with "id" and "source" fields
-	var req struct {
-		ID     string `json:"id"`
-		Source string `json:"source"`
-	}
-	if err := json.Unmarshal(body, &req); err != nil {
-		http.Error(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	if req.ID == "" || req.Source == "" {
-		http.Error(w, "id and source are required", http.StatusBadRequest)
+	if id == "" || files == nil {
+		http.Error(w, "id and source (or files) are required", http.StatusBadRequest)
 		return
 	}
 
-	comp, err := h.loader.LoadFromString(req.ID, req.Source)
+	comp, err := h.loader.LoadFromFiles(id, files)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
 		return
@@ -126,37 +171,34 @@ func (h *APIHandler) getComponent(w http.ResponseWriter, id string) {
 		return
 	}
 
-	// Return info plus source
+	// Return info plus source; multi-file components also list each file.
 	type response struct {
 		ComponentInfo
-		Source string `json:"source"`
+		Source      string            `json:"source"`
+		FileSources map[string]string `json:"file_sources,omitempty"`
 	}
 	resp := response{
 		ComponentInfo: comp.Info(),
 		Source:        comp.Source(),
 	}
+	if files := comp.Files(); len(files) > 1 {
+		resp.FileSources = files
+	}
 	writeJSON(w, http.StatusOK, resp)
 }
 
 func (h *APIHandler) updateComponent(w http.ResponseWriter, r *http.Request, id string) {
-	body, err := io.ReadAll(r.Body)
+	_, files, err := readComponentRequest(w, r)
 	if err != nil {
-		http.Error(w, "failed to read body", http.StatusBadRequest)
-		return
-	}
-	defer func() { _ = r.Body.Close() }()
-
-	var req loadComponentRequest
-	if err := json.Unmarshal(body, &req); err != nil {
-		http.Error(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	if req.Source == "" {
-		http.Error(w, "source is required", http.StatusBadRequest)
+	if files == nil {
+		http.Error(w, "source or files is required", http.StatusBadRequest)
 		return
 	}
 
-	comp, err := h.loader.Reload(id, req.Source)
+	comp, err := h.loader.ReloadFiles(id, files)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
 		return
@@ -186,6 +228,86 @@ func (h *APIHandler) deleteComponent(w http.ResponseWriter, id string) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// previewComponentRequest is the JSON body for previewing a component. When
+// Source and Files are empty the registered component's files are previewed.
+type previewComponentRequest struct {
+	Source string            `json:"source,omitempty"`
+	Files  map[string]string `json:"files,omitempty"`
+	Input  map[string]any    `json:"input,omitempty"`
+}
+
+// PreviewResult is the outcome of a component preview. On success Output
+// holds the Execute result; on failure Error and Code (one of the
+// sandbox.PreviewCode values) describe why.
+type PreviewResult struct {
+	Output     map[string]any `json:"output,omitempty"`
+	Error      string         `json:"error,omitempty"`
+	Code       string         `json:"code,omitempty"`
+	DurationMs int64          `json:"duration_ms"`
+}
+
+// previewComponent runs a component's Execute once against the request input.
+// The source is compiled into a fresh, unregistered component, so the
+// registered instance and its state are never touched, and it must pass the
+// preview policy's import check in addition to the loader's.
+func (h *APIHandler) previewComponent(w http.ResponseWriter, r *http.Request, id string) {
+	var req previewComponentRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, h.preview.MaxRequestBytes)).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	files := req.Files
+	switch {
+	case req.Source != "" && len(files) > 0:
+		http.Error(w, "set either source or files, not both", http.StatusBadRequest)
+		return
+	case req.Source != "":
+		files = map[string]string{SingleFileName: req.Source}
+	case len(files) == 0:
+		comp, ok := h.registry.Get(id)
+		if !ok {
+			http.Error(w, "component not found", http.StatusNotFound)
+			return
+		}
+		files = comp.Files()
+	}
+
+	start := time.Now()
+	fail := func(status int, code string, err error) {
+		writeJSON(w, status, PreviewResult{Error: err.Error(), Code: code, DurationMs: time.Since(start).Milliseconds()})
+	}
+
+	imports, err := FilesImports(files)
+	if err != nil {
+		fail(http.StatusUnprocessableEntity, sandbox.PreviewCodeInvalidConfig, err)
+		return
+	}
+	if err := h.preview.CheckImports(imports); err != nil {
+		fail(http.StatusForbidden, sandbox.PreviewCodeNotAllowed, err)
+		return
+	}
+	comp, err := h.loader.CompileFiles(id, files)
+	if err != nil {
+		fail(http.StatusUnprocessableEntity, sandbox.PreviewCodeInvalidConfig, err)
+		return
+	}
+
+	limits := DefaultResourceLimits()
+	limits.MaxExecutionTime = h.preview.Timeout
+	ctx, cancel := h.preview.WithTimeout(r.Context())
+	defer cancel()
+	out, err := ExecuteWithLimits(ctx, comp, req.Input, limits)
+	if err != nil {
+		code := sandbox.PreviewCodeExecutionFailed
+		if ctx.Err() != nil {
+			code = sandbox.PreviewCodeTimeout
+		}
+		fail(http.StatusUnprocessableEntity, code, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, PreviewResult{Output: out, DurationMs: time.Since(start).Milliseconds()})
+}
+
 func writeJSON(w http.ResponseWriter, status int, v any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -7,10 +7,12 @@ import (
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
 
 	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow/sandbox"
 )
 
 func TestAPI_ComponentsMethodNotAllowed(t *testing.T) {
@@ -405,3 +407,64 @@ func TestAPI_RegisterRoutes_Patterns(t *testing.T) {
 		t.Errorf("expected 0 components, got %d", len(infos))
 	}
 }
+
+func TestAPI_PreviewComponent(t *testing.T) {
+	pool := NewInterpreterPool()
+	reg := NewComponentRegistry()
+	loader := NewLoader(pool, reg)
+	if _, err := loader.LoadFromString("greeter", simpleComponentSource); err != nil {
+		t.Fatal(err)
+	}
+
+	api := NewAPIHandler(loader, reg)
+	mux := http.NewServeMux()
+	api.RegisterRoutes(mux)
+
+	preview := func(id, body string) (int, PreviewResult) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/api/dynamic/components/"+id+"/preview", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		var res PreviewResult
+		if strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+			if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+				t.Fatalf("decode %q: %v", w.Body.String(), err)
+			}
+		}
+		return w.Code, res
+	}
+
+	// Registered source.
+	code, res := preview("greeter", `{"input": {"name": "ada"}}`)
+	if code != http.StatusOK || res.Output["greeting"] != "hello ada" {
+		t.Errorf("registered: status = %d, result = %+v", code, res)
+	}
+
+	// Unsaved source replaces the registered one for the preview only.
+	code, res = preview("greeter", `{"source": `+strconv.Quote(minimalComponentSource)+`}`)
+	if code != http.StatusOK || res.Output["ok"] != true {
+		t.Errorf("unsaved source: status = %d, result = %+v", code, res)
+	}
+	if comp, _ := reg.Get("greeter"); comp.Source() != simpleComponentSource {
+		t.Error("preview replaced the registered component")
+	}
+
+	code, res = preview("greeter", `{}`)
+	if code != http.StatusUnprocessableEntity || res.Code != sandbox.PreviewCodeExecutionFailed {
+		t.Errorf("execute error: status = %d, code = %q", code, res.Code)
+	}
+
+	// Components reaching the network are rejected by the shared preview policy.
+	netSource := strings.Replace(minimalComponentSource, `"context"`, "\"context\"\n\t\"net/http\"\n", 1) + "\nvar _ = http.MethodGet\n"
+	code, res = preview("greeter", `{"source": `+strconv.Quote(netSource)+`}`)
+	if code != http.StatusForbidden || res.Code != sandbox.PreviewCodeNotAllowed {
+		t.Errorf("net/http: status = %d, code = %q (%s)", code, res.Code, res.Error)
+	}
+
+	if code, _ = preview("missing", `{}`); code != http.StatusNotFound {
+		t.Errorf("unknown component: status = %d, want 404", code)
+	}
+	if reg.Count() != 1 {
+		t.Errorf("registry has %d components after previews, want 1", reg.Count())
+	}
+}
@@ -0,0 +1,78 @@
+// Package builtinmodules registers the third-party modules the engine vendors
+// for dynamic components (see dynamic.RegisterImportableModule). It is kept
+// out of package dynamic, which external plugins import through the SDK, so
+// plugin binaries do not link these modules; the engine imports it for its
+// side effects.
+package builtinmodules
+
+import (
+	"reflect"
+
+	"github.com/GoCodeAlone/workflow/dynamic"
+	"github.com/google/uuid"
+	"github.com/iancoleman/strcase"
+)
+
+// Built-in importable modules. Each symbol set is curated by hand rather
+// than generated: functions that read the host's network interfaces or
+// change package-level state shared with the host are left out.
+func init() {
+	dynamic.RegisterImportableModule(dynamic.ImportableModule{
+		Path:        "github.com/google/uuid",
+		Description: "UUID generation and parsing (versions 3, 4, 5, and 7)",
+		Symbols: map[string]map[string]reflect.Value{
+			"github.com/google/uuid/uuid": {
+				"FromBytes":            reflect.ValueOf(uuid.FromBytes),
+				"IsInvalidLengthError": reflect.ValueOf(uuid.IsInvalidLengthError),
+				"Must":                 reflect.ValueOf(uuid.Must),
+				"MustParse":            reflect.ValueOf(uuid.MustParse),
+				"New":                  reflect.ValueOf(uuid.New),
+				"NewMD5":               reflect.ValueOf(uuid.NewMD5),
+				"NewRandom":            reflect.ValueOf(uuid.NewRandom),
+				"NewSHA1":              reflect.ValueOf(uuid.NewSHA1),
+				"NewString":            reflect.ValueOf(uuid.NewString),
+				"NewV7":                reflect.ValueOf(uuid.NewV7),
+				"Parse":                reflect.ValueOf(uuid.Parse),
+				"ParseBytes":           reflect.ValueOf(uuid.ParseBytes),
+				"Validate":             reflect.ValueOf(uuid.Validate),
+
+				"Max":           reflect.ValueOf(&uuid.Max).Elem(),
+				"NameSpaceDNS":  reflect.ValueOf(&uuid.NameSpaceDNS).Elem(),
+				"NameSpaceOID":  reflect.ValueOf(&uuid.NameSpaceOID).Elem(),
+				"NameSpaceURL":  reflect.ValueOf(&uuid.NameSpaceURL).Elem(),
+				"NameSpaceX500": reflect.ValueOf(&uuid.NameSpaceX500).Elem(),
+				"Nil":           reflect.ValueOf(&uuid.Nil).Elem(),
+
+				"Invalid":   reflect.ValueOf(uuid.Invalid),
+				"RFC4122":   reflect.ValueOf(uuid.RFC4122),
+				"Reserved":  reflect.ValueOf(uuid.Reserved),
+				"Microsoft": reflect.ValueOf(uuid.Microsoft),
+				"Future":    reflect.ValueOf(uuid.Future),
+
+				"NullUUID": reflect.ValueOf((*uuid.NullUUID)(nil)),
+				"UUID":     reflect.ValueOf((*uuid.UUID)(nil)),
+				"UUIDs":    reflect.ValueOf((*uuid.UUIDs)(nil)),
+				"Variant":  reflect.ValueOf((*uuid.Variant)(nil)),
+				"Version":  reflect.ValueOf((*uuid.Version)(nil)),
+			},
+		},
+	})
+
+	dynamic.RegisterImportableModule(dynamic.ImportableModule{
+		Path:        "github.com/iancoleman/strcase",
+		Description: "Case conversion (snake_case, camelCase, kebab-case)",
+		Symbols: map[string]map[string]reflect.Value{
+			"github.com/iancoleman/strcase/strcase": {
+				"ToCamel":              reflect.ValueOf(strcase.ToCamel),
+				"ToDelimited":          reflect.ValueOf(strcase.ToDelimited),
+				"ToKebab":              reflect.ValueOf(strcase.ToKebab),
+				"ToLowerCamel":         reflect.ValueOf(strcase.ToLowerCamel),
+				"ToScreamingDelimited": reflect.ValueOf(strcase.ToScreamingDelimited),
+				"ToScreamingKebab":     reflect.ValueOf(strcase.ToScreamingKebab),
+				"ToScreamingSnake":     reflect.ValueOf(strcase.ToScreamingSnake),
+				"ToSnake":              reflect.ValueOf(strcase.ToSnake),
+				"ToSnakeWithIgnore":    reflect.ValueOf(strcase.ToSnakeWithIgnore),
+			},
+		},
+	})
+}
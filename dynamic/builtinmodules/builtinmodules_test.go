@@ -0,0 +1,75 @@
+package builtinmodules_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/GoCodeAlone/workflow/dynamic"
+	_ "github.com/GoCodeAlone/workflow/dynamic/builtinmodules"
+	"github.com/google/uuid"
+)
+
+const uuidComponentSource = `package component
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/iancoleman/strcase"
+)
+
+func Name() string { return "ids" }
+
+func Execute(ctx context.Context, params map[string]interface{}) (map[string]interface{}, error) {
+	id := uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://example.com"))
+	return map[string]interface{}{"id": id.String(), "key": strcase.ToSnake("OrderID")}, nil
+}
+`
+
+func TestImportableModules(t *testing.T) {
+	t.Run("not enabled", func(t *testing.T) {
+		loader := dynamic.NewLoader(dynamic.NewInterpreterPool(), dynamic.NewComponentRegistry())
+		_, err := loader.LoadFromString("ids", uuidComponentSource)
+		if err == nil || !strings.Contains(err.Error(), "github.com/google/uuid") {
+			t.Errorf("error = %v, want uuid import rejected", err)
+		}
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		pool := dynamic.NewInterpreterPool(dynamic.WithImportableModules("github.com/google/uuid", "github.com/iancoleman/strcase"))
+		loader := dynamic.NewLoader(pool, dynamic.NewComponentRegistry())
+		comp, err := loader.LoadFromString("ids", uuidComponentSource)
+		if err != nil {
+			t.Fatalf("LoadFromString failed: %v", err)
+		}
+		out, err := comp.Execute(context.Background(), map[string]any{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://example.com")).String()
+		if out["id"] != want || out["key"] != "order_id" {
+			t.Errorf("output = %v", out)
+		}
+	})
+
+	t.Run("only the enabled module", func(t *testing.T) {
+		pool := dynamic.NewInterpreterPool(dynamic.WithImportableModules("github.com/iancoleman/strcase"))
+		if !pool.IsPackageAllowed("github.com/iancoleman/strcase") || pool.IsPackageAllowed("github.com/google/uuid") {
+			t.Error("pool should allow exactly the enabled module")
+		}
+		files := map[string]string{dynamic.SingleFileName: uuidComponentSource}
+		if err := dynamic.ValidateFiles(files, pool.IsPackageAllowed); err == nil {
+			t.Error("expected uuid import to be rejected")
+		}
+	})
+
+	t.Run("unknown module", func(t *testing.T) {
+		if err := dynamic.ValidateImportableModules([]string{"github.com/google/uuid", "example.com/nope"}); err == nil || !strings.Contains(err.Error(), "example.com/nope") {
+			t.Errorf("error = %v, want unknown module named", err)
+		}
+		if _, err := dynamic.NewInterpreterPool(dynamic.WithImportableModules("example.com/nope")).NewInterpreter(); err == nil {
+			t.Error("expected NewInterpreter to fail for an unknown module")
+		}
+	})
+}
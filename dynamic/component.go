@@ -3,7 +3,9 @@ package dynamic
 import (
 	"context"
 	"fmt"
+	"maps"
 	"reflect"
+	"slices"
 	"sync"
 	"time"
 
@@ -27,6 +29,7 @@ type ComponentInfo struct {
 	ID       string          `json:"id"`
 	Name     string          `json:"name"`
 	Source   string          `json:"source,omitempty"`
+	Files    []string        `json:"files,omitempty"`
 	Status   ComponentStatus `json:"status"`
 	LoadedAt time.Time       `json:"loaded_at"`
 	Error    string          `json:"error,omitempty"`
@@ -38,6 +41,7 @@ type DynamicComponent struct {
 	mu     sync.RWMutex
 	id     string
 	source string
+	files  map[string]string
 	info   ComponentInfo
 
 	pool        *InterpreterPool
@@ -150,7 +154,23 @@ func (dc *DynamicComponent) Execute(ctx context.Context, params map[string]any)
 func (dc *DynamicComponent) LoadFromSource(source string) error {
 	dc.mu.Lock()
 	defer dc.mu.Unlock()
+	return dc.load(source, map[string]string{SingleFileName: source})
+}
 
+// LoadFromFiles compiles and loads a multi-file component. The files are
+// merged with MergeFiles and evaluated as one source.
+func (dc *DynamicComponent) LoadFromFiles(files map[string]string) error {
+	source, err := MergeFiles(files)
+	if err != nil {
+		return err
+	}
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	return dc.load(source, maps.Clone(files))
+}
+
+// load evaluates source in a fresh interpreter. The caller holds dc.mu.
+func (dc *DynamicComponent) load(source string, files map[string]string) error {
 	i, err := dc.pool.NewInterpreter()
 	if err != nil {
 		return fmt.Errorf("failed to create interpreter: %w", err)
@@ -169,7 +189,9 @@ func (dc *DynamicComponent) LoadFromSource(source string) error {
 
 	dc.interpreter = i
 	dc.source = source
+	dc.files = files
 	dc.info.Source = source
+	dc.info.Files = slices.Sorted(maps.Keys(files))
 
 	// Extract known function symbols from interpreted code.
 	dc.extractFunctions(i)
@@ -204,6 +226,14 @@ func (dc *DynamicComponent) Source() string {
 	return dc.source
 }
 
+// Files returns a copy of the component's source files. A component loaded
+// from a single source has one file, SingleFileName.
+func (dc *DynamicComponent) Files() map[string]string {
+	dc.mu.RLock()
+	defer dc.mu.RUnlock()
+	return maps.Clone(dc.files)
+}
+
 // extractFunctions looks up well-known function symbols in the interpreter
 // and binds them as typed Go function references.
 func (dc *DynamicComponent) extractFunctions(i *interp.Interpreter) {
@@ -0,0 +1,287 @@
+package dynamic
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Limits on a multi-file component.
+const (
+	// MaxComponentFiles bounds the number of source files in one component.
+	MaxComponentFiles = 64
+	// MaxComponentBytes bounds the total source size of one component,
+	// including the uncompressed contents of a zip upload.
+	MaxComponentBytes = 4 << 20
+)
+
+// SingleFileName is the file name a single-source component is known by.
+const SingleFileName = "component.go"
+
+// parsedFile is one source file of a component, parsed for merging.
+type parsedFile struct {
+	name    string
+	src     string
+	pkg     string
+	imports []importSpec
+	body    int // offset just past the package clause and imports
+}
+
+type importSpec struct {
+	name string // explicit name, "" when unnamed
+	path string
+}
+
+// localName returns the identifier an import binds in its file.
+func (s importSpec) localName() string {
+	if s.name != "" {
+		return s.name
+	}
+	return path.Base(s.path)
+}
+
+// parseComponentFiles parses every file of a component in name order and
+// checks the set is well formed: at least one file, within the limits, all
+// .go files of the same package.
+func parseComponentFiles(files map[string]string) ([]parsedFile, error) {
+	if len(files) == 0 {
+		return nil, fmt.Errorf("component has no source files")
+	}
+	if len(files) > MaxComponentFiles {
+		return nil, fmt.Errorf("component has %d files, more than the limit of %d", len(files), MaxComponentFiles)
+	}
+	total := 0
+	parsed := make([]parsedFile, 0, len(files))
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		if err := checkComponentFileName(name); err != nil {
+			return nil, err
+		}
+		src := files[name]
+		if total += len(src); total > MaxComponentBytes {
+			return nil, fmt.Errorf("component source exceeds %d bytes", MaxComponentBytes)
+		}
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, name, src, parser.ImportsOnly)
+		if err != nil {
+			return nil, fmt.Errorf("file %s: syntax error: %w", name, err)
+		}
+		pf := parsedFile{name: name, src: src, pkg: f.Name.Name, body: fset.Position(f.Name.End()).Offset}
+		for _, decl := range f.Decls {
+			pf.body = fset.Position(decl.End()).Offset
+		}
+		for _, imp := range f.Imports {
+			spec := importSpec{path: strings.Trim(imp.Path.Value, `"`)}
+			if imp.Name != nil {
+				spec.name = imp.Name.Name
+			}
+			pf.imports = append(pf.imports, spec)
+		}
+		if len(parsed) > 0 && pf.pkg != parsed[0].pkg {
+			return nil, fmt.Errorf("file %s: package %s does not match package %s in %s", name, pf.pkg, parsed[0].pkg, parsed[0].name)
+		}
+		parsed = append(parsed, pf)
+	}
+	return parsed, nil
+}
+
+// checkComponentFileName rejects names that are not plain .go file names.
+func checkComponentFileName(name string) error {
+	if name == "" || name != filepath.Base(name) || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return fmt.Errorf("invalid file name %q: component files must be plain names without directories", name)
+	}
+	if !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+		return fmt.Errorf("invalid file name %q: component files must be non-test .go files", name)
+	}
+	return nil
+}
+
+// ValidateFiles checks every file of a multi-file component against the
+// sandbox: each must parse, all must share a package, and no file may import
+// a package that allowed rejects. Errors name the offending file.
+func ValidateFiles(files map[string]string, allowed func(string) bool) error {
+	parsed, err := parseComponentFiles(files)
+	if err != nil {
+		return err
+	}
+	for _, pf := range parsed {
+		for _, imp := range pf.imports {
+			if !allowed(imp.path) {
+				return fmt.Errorf("file %s: import %q is not allowed in dynamic components", pf.name, imp.path)
+			}
+		}
+	}
+	return nil
+}
+
+// FilesImports returns the distinct import paths of all files, sorted.
+func FilesImports(files map[string]string) ([]string, error) {
+	parsed, err := parseComponentFiles(files)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for _, pf := range parsed {
+		for _, imp := range pf.imports {
+			seen[imp.path] = true
+		}
+	}
+	return slices.Sorted(maps.Keys(seen)), nil
+}
+
+// MergeFiles combines the files of a component into one source the
+// interpreter evaluates: a single package clause, the union of the imports,
+// then each file's declarations in file name order. Imports are file-scoped in
+// Go but shared once merged, so files that bind the same name to different
+// packages are rejected. A single file is returned unchanged.
+func MergeFiles(files map[string]string) (string, error) {
+	parsed, err := parseComponentFiles(files)
+	if err != nil {
+		return "", err
+	}
+	if len(parsed) == 1 {
+		return parsed[0].src, nil
+	}
+
+	type binding struct {
+		path string
+		file string
+	}
+	bound := make(map[string]binding)
+	var imports []importSpec
+	for _, pf := range parsed {
+		for _, imp := range pf.imports {
+			local := imp.localName()
+			if local == "_" || local == "." {
+				if !slices.Contains(imports, imp) {
+					imports = append(imports, imp)
+				}
+				continue
+			}
+			if prev, ok := bound[local]; ok {
+				if prev.path != imp.path {
+					return "", fmt.Errorf("file %s: import name %s refers to %q, but %s uses it for %q", pf.name, local, imp.path, prev.file, prev.path)
+				}
+				continue
+			}
+			bound[local] = binding{path: imp.path, file: pf.name}
+			imports = append(imports, imp)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "package %s\n", parsed[0].pkg)
+	if len(imports) > 0 {
+		b.WriteString("\nimport (\n")
+		for _, imp := range imports {
+			b.WriteByte('\t')
+			if imp.name != "" {
+				b.WriteString(imp.name + " ")
+			}
+			b.WriteString(strconv.Quote(imp.path) + "\n")
+		}
+		b.WriteString(")\n")
+	}
+	for _, pf := range parsed {
+		fmt.Fprintf(&b, "\n// ---- %s ----\n\n", pf.name)
+		b.WriteString(strings.TrimSpace(pf.src[pf.body:]))
+		b.WriteByte('\n')
+	}
+	return b.String(), nil
+}
+
+// ReadComponentDir reads the non-test .go files directly inside dir as the
+// files of one component.
+func ReadComponentDir(dir string) (map[string]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+	files := make(map[string]string)
+	for _, entry := range entries {
+		if entry.IsDir() || !isGoFile(entry.Name()) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+		}
+		files[entry.Name()] = string(data)
+	}
+	return files, nil
+}
+
+// FilesFromZip extracts the files of a component from a zip archive. Only
+// .go files at the archive root are used (a single top-level directory
+// wrapping them is allowed); test files and other files are ignored, and
+// nested directories are rejected. The uncompressed total is bounded by
+// MaxComponentBytes.
+func FilesFromZip(data []byte) (map[string]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid zip archive: %w", err)
+	}
+
+	// Accept archives created by zipping a directory: strip one shared
+	// top-level directory.
+	prefix := ""
+	for i, f := range zr.File {
+		dir, _, found := strings.Cut(f.Name, "/")
+		if !found {
+			prefix = ""
+			break
+		}
+		if i == 0 {
+			prefix = dir + "/"
+		} else if dir+"/" != prefix {
+			prefix = ""
+			break
+		}
+	}
+
+	files := make(map[string]string)
+	var total int64
+	for _, f := range zr.File {
+		name := strings.TrimPrefix(f.Name, prefix)
+		if name == "" || strings.HasSuffix(name, "/") {
+			continue
+		}
+		if strings.Contains(name, "/") || strings.Contains(name, `\`) {
+			return nil, fmt.Errorf("zip entry %q: component archives must not contain nested directories", f.Name)
+		}
+		if !isGoFile(name) {
+			continue
+		}
+		if total += int64(f.UncompressedSize64); total > MaxComponentBytes {
+			return nil, fmt.Errorf("zip archive exceeds %d bytes of source", MaxComponentBytes)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("zip entry %q: %w", f.Name, err)
+		}
+		// Read one byte past the declared size so a lying header cannot
+		// smuggle in more data.
+		src, err := io.ReadAll(io.LimitReader(rc, int64(f.UncompressedSize64)+1))
+		_ = rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("zip entry %q: %w", f.Name, err)
+		}
+		if uint64(len(src)) > f.UncompressedSize64 {
+			return nil, fmt.Errorf("zip entry %q is larger than its declared size", f.Name)
+		}
+		files[name] = string(src)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("zip archive contains no .go files")
+	}
+	return files, nil
+}
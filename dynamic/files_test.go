@@ -0,0 +1,384 @@
+package dynamic
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// A component split across three files: main.go calls helpers defined in
+// format.go and uses a type declared in types.go.
+var multiFileComponent = map[string]string{
+	"main.go": `package component
+
+import (
+	"context"
+)
+
+func Name() string {
+	return "multi"
+}
+
+func Execute(ctx context.Context, params map[string]interface{}) (map[string]interface{}, error) {
+	name, _ := params["name"].(string)
+	g := greeting{name: name}
+	return map[string]interface{}{"greeting": format(g)}, nil
+}
+`,
+	"format.go": `package component
+
+import (
+	"fmt"
+	"strings"
+)
+
+func format(g greeting) string {
+	return fmt.Sprintf("hello %s", strings.ToUpper(g.name))
+}
+`,
+	"types.go": `package component
+
+type greeting struct {
+	name string
+}
+`,
+}
+
+func cloneFiles(files map[string]string) map[string]string {
+	out := make(map[string]string, len(files))
+	for k, v := range files {
+		out[k] = v
+	}
+	return out
+}
+
+func TestLoader_LoadFromFiles_MultiFile(t *testing.T) {
+	loader := NewLoader(NewInterpreterPool(), NewComponentRegistry())
+
+	comp, err := loader.LoadFromFiles("multi", multiFileComponent)
+	if err != nil {
+		t.Fatalf("LoadFromFiles failed: %v", err)
+	}
+	out, err := comp.Execute(context.Background(), map[string]any{"name": "ada"})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if out["greeting"] != "hello ADA" {
+		t.Errorf("greeting = %v, want %q", out["greeting"], "hello ADA")
+	}
+
+	info := comp.Info()
+	want := []string{"format.go", "main.go", "types.go"}
+	if strings.Join(info.Files, ",") != strings.Join(want, ",") {
+		t.Errorf("Info().Files = %v, want %v", info.Files, want)
+	}
+	if got := comp.Files(); len(got) != 3 || got["types.go"] != multiFileComponent["types.go"] {
+		t.Errorf("Files() = %v", got)
+	}
+}
+
+// TestSandbox_BlockedImportInAnyFile verifies that the sandbox checks every
+// file of a component, not just the first or the one holding Execute.
+func TestSandbox_BlockedImportInAnyFile(t *testing.T) {
+	blocked := `package component
+
+import "os/exec"
+
+var _ = exec.Command
+`
+	// Sorted, the blocked file is evaluated first, in the middle, and last.
+	for _, name := range []string{"a_exec.go", "g_exec.go", "z_exec.go"} {
+		t.Run(name, func(t *testing.T) {
+			files := cloneFiles(multiFileComponent)
+			files[name] = blocked
+
+			loader := NewLoader(NewInterpreterPool(), NewComponentRegistry())
+			_, err := loader.LoadFromFiles("multi", files)
+			if err == nil {
+				t.Fatal("expected blocked import to be rejected")
+			}
+			if !strings.Contains(err.Error(), name) || !strings.Contains(err.Error(), `"os/exec"`) {
+				t.Errorf("error %q should name file %s and the import", err, name)
+			}
+		})
+	}
+
+	// A blocked import added to an existing file is caught the same way.
+	for name := range multiFileComponent {
+		t.Run("edit_"+name, func(t *testing.T) {
+			files := cloneFiles(multiFileComponent)
+			files[name] = strings.Replace(files[name], "package component\n", "package component\n\nimport _ \"syscall\"\n", 1)
+
+			if err := ValidateFiles(files, IsPackageAllowed); err == nil || !strings.Contains(err.Error(), name) {
+				t.Errorf("ValidateFiles error = %v, want one naming %s", err, name)
+			}
+		})
+	}
+}
+
+func TestValidateFiles_Malformed(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{"empty", map[string]string{}, "no source files"},
+		{"package mismatch", map[string]string{
+			"a.go": "package component\n",
+			"b.go": "package other\n",
+		}, "does not match"},
+		{"directory", map[string]string{"sub/a.go": "package component\n"}, "without directories"},
+		{"not go", map[string]string{"README.md": "hi"}, "non-test .go"},
+		{"test file", map[string]string{"a_test.go": "package component\n"}, "non-test .go"},
+		{"syntax", map[string]string{"a.go": badSyntaxSource}, "file a.go: syntax error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateFiles(tt.files, IsPackageAllowed)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeFiles_ImportNameConflict(t *testing.T) {
+	files := map[string]string{
+		"a.go": "package component\n\nimport rand \"math/rand\"\n\nvar _ = rand.Int\n",
+		"b.go": "package component\n\nimport rand \"crypto/rand\"\n\nvar _ = rand.Reader\n",
+	}
+	if _, err := MergeFiles(files); err == nil || !strings.Contains(err.Error(), "import name rand") {
+		t.Errorf("error = %v, want import name conflict", err)
+	}
+
+	// The same import in several files is merged once.
+	files["b.go"] = "package component\n\nimport \"math/rand\"\n\nvar _ = rand.Intn\n"
+	merged, err := MergeFiles(files)
+	if err != nil {
+		t.Fatalf("MergeFiles: %v", err)
+	}
+	if strings.Count(merged, `"math/rand"`) != 1 {
+		t.Errorf("merged source should import math/rand once:\n%s", merged)
+	}
+}
+
+func TestMergeFiles_SingleFileUnchanged(t *testing.T) {
+	merged, err := MergeFiles(map[string]string{SingleFileName: simpleComponentSource})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged != simpleComponentSource {
+		t.Error("single-file component should be returned unchanged")
+	}
+}
+
+func zipFiles(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, src := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(src)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestFilesFromZip(t *testing.T) {
+	t.Run("flat", func(t *testing.T) {
+		data := zipFiles(t, map[string]string{"main.go": "package component\n", "README.md": "docs"})
+		files, err := FilesFromZip(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) != 1 || files["main.go"] == "" {
+			t.Errorf("files = %v, want only main.go", files)
+		}
+	})
+	t.Run("wrapped in a directory", func(t *testing.T) {
+		data := zipFiles(t, map[string]string{"greeter/main.go": "package component\n", "greeter/util.go": "package component\n"})
+		files, err := FilesFromZip(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) != 2 || files["util.go"] == "" {
+			t.Errorf("files = %v, want main.go and util.go", files)
+		}
+	})
+	t.Run("nested directory", func(t *testing.T) {
+		data := zipFiles(t, map[string]string{"main.go": "package component\n", "sub/util.go": "package component\n"})
+		if _, err := FilesFromZip(data); err == nil || !strings.Contains(err.Error(), "nested") {
+			t.Errorf("error = %v, want nested directory rejection", err)
+		}
+	})
+	t.Run("no go files", func(t *testing.T) {
+		if _, err := FilesFromZip(zipFiles(t, map[string]string{"README.md": "docs"})); err == nil {
+			t.Error("expected error for archive without .go files")
+		}
+	})
+	t.Run("not a zip", func(t *testing.T) {
+		if _, err := FilesFromZip([]byte("nope")); err == nil {
+			t.Error("expected error for invalid archive")
+		}
+	})
+}
+
+func TestAPI_CreateComponent_Files(t *testing.T) {
+	reg := NewComponentRegistry()
+	api := NewAPIHandler(NewLoader(NewInterpreterPool(), reg), reg)
+	mux := http.NewServeMux()
+	api.RegisterRoutes(mux)
+
+	body, _ := json.Marshal(map[string]any{"id": "multi", "files": multiFileComponent})
+	req := httptest.NewRequest(http.MethodPost, "/api/dynamic/components", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("files: status = %d, body = %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/dynamic/components/multi", nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	var got map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if fs, _ := got["file_sources"].(map[string]any); len(fs) != 3 {
+		t.Errorf("file_sources = %v, want 3 files", got["file_sources"])
+	}
+
+	// Zip upload of the same files, with one file importing a blocked package.
+	files := cloneFiles(multiFileComponent)
+	files["types.go"] = "package component\n\nimport _ \"os\"\n\ntype greeting struct{ name string }\n"
+	req = httptest.NewRequest(http.MethodPost, "/api/dynamic/components?id=zipped", bytes.NewReader(zipFiles(t, files)))
+	req.Header.Set("Content-Type", "application/zip")
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), "types.go") {
+		t.Errorf("blocked zip: status = %d, body = %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/dynamic/components?id=zipped", bytes.NewReader(zipFiles(t, multiFileComponent)))
+	req.Header.Set("Content-Type", "application/zip")
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("zip: status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if _, ok := reg.Get("zipped"); !ok {
+		t.Error("zipped component not registered")
+	}
+
+	// source and files are mutually exclusive.
+	body, _ = json.Marshal(map[string]any{"id": "both", "source": simpleComponentSource, "files": multiFileComponent})
+	req = httptest.NewRequest(http.MethodPost, "/api/dynamic/components", bytes.NewReader(body))
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("source and files: status = %d, want 400", w.Code)
+	}
+}
+
+func TestLoader_LoadFromDirectory_Subdirectory(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "single.go"), []byte(minimalComponentSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sub := filepath.Join(dir, "multi")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, src := range multiFileComponent {
+		if err := os.WriteFile(filepath.Join(sub, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "empty"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	reg := NewComponentRegistry()
+	comps, err := NewLoader(NewInterpreterPool(), reg).LoadFromDirectory(dir)
+	if err != nil {
+		t.Fatalf("LoadFromDirectory failed: %v", err)
+	}
+	if len(comps) != 2 {
+		t.Errorf("loaded %d components, want 2", len(comps))
+	}
+	comp, ok := reg.Get("multi")
+	if !ok {
+		t.Fatal("multi-file component not registered under its directory name")
+	}
+	if len(comp.Info().Files) != 3 {
+		t.Errorf("Files = %v, want 3", comp.Info().Files)
+	}
+}
+
+func TestWatcher_DirectoryComponentReload(t *testing.T) {
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "multi")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, src := range multiFileComponent {
+		if err := os.WriteFile(filepath.Join(sub, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reg := NewComponentRegistry()
+	loader := NewLoader(NewInterpreterPool(), reg)
+	if _, err := loader.LoadFromComponentDir("", sub); err != nil {
+		t.Fatal(err)
+	}
+
+	watcher := NewWatcher(loader, dir, WithDebounce(100*time.Millisecond))
+	if err := watcher.Start(); err != nil {
+		t.Fatalf("Start watcher failed: %v", err)
+	}
+	defer func() { _ = watcher.Stop() }()
+
+	// Editing a helper file reloads the whole component.
+	updated := strings.Replace(multiFileComponent["format.go"], "hello %s", "hi %s", 1)
+	if err := os.WriteFile(filepath.Join(sub, "format.go"), []byte(updated), 0644); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(800 * time.Millisecond)
+
+	comp, ok := reg.Get("multi")
+	if !ok {
+		t.Fatal("component unregistered after edit")
+	}
+	out, err := comp.Execute(context.Background(), map[string]any{"name": "ada"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out["greeting"] != "hi ADA" {
+		t.Errorf("greeting = %v, want reloaded %q", out["greeting"], "hi ADA")
+	}
+
+	// Removing the directory unregisters the component.
+	if err := os.RemoveAll(sub); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(800 * time.Millisecond)
+	if _, ok := reg.Get("multi"); ok {
+		t.Error("expected component to be unregistered after its directory was removed")
+	}
+}
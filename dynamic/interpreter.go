@@ -2,6 +2,7 @@ package dynamic
 
 import (
 	"fmt"
+	"slices"
 	"sync"
 
 	"github.com/GoCodeAlone/yaegi/interp"
@@ -18,6 +19,15 @@ func WithAllowedPackages(pkgs map[string]bool) Option {
 	}
 }
 
+// WithImportableModules enables registered importable modules (see
+// RegisterImportableModule) for components loaded through the pool. Paths
+// that are not registered make NewInterpreter fail.
+func WithImportableModules(paths ...string) Option {
+	return func(p *InterpreterPool) {
+		p.modules = append(p.modules, paths...)
+	}
+}
+
 // WithGoPath sets the GOPATH for interpreters.
 func WithGoPath(path string) Option {
 	return func(p *InterpreterPool) {
@@ -29,6 +39,7 @@ func WithGoPath(path string) Option {
 type InterpreterPool struct {
 	mu              sync.Mutex
 	allowedPackages map[string]bool
+	modules         []string
 	goPath          string
 }
 
@@ -63,6 +74,29 @@ func (p *InterpreterPool) NewInterpreter() (*interp.Interpreter, error) {
 	if err := i.Use(stdlib.Symbols); err != nil {
 		return nil, fmt.Errorf("failed to load stdlib symbols: %w", err)
 	}
+	for _, path := range p.modules {
+		m, ok := LookupImportableModule(path)
+		if !ok {
+			return nil, fmt.Errorf("dynamic module %q is not importable", path)
+		}
+		if err := i.Use(m.Symbols); err != nil {
+			return nil, fmt.Errorf("failed to load symbols for %s: %w", path, err)
+		}
+	}
 
 	return i, nil
 }
+
+// Modules returns the importable modules enabled on the pool.
+func (p *InterpreterPool) Modules() []string {
+	return slices.Clone(p.modules)
+}
+
+// IsPackageAllowed reports whether components loaded through the pool may
+// import pkg: an allowed standard library package or an enabled module.
+func (p *InterpreterPool) IsPackageAllowed(pkg string) bool {
+	if IsPackageAllowed(pkg) {
+		return true
+	}
+	return p != nil && slices.Contains(p.modules, pkg)
+}
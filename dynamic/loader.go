@@ -27,33 +27,67 @@ func NewLoader(pool *InterpreterPool, registry *ComponentRegistry) *Loader {
 // ValidateSource performs a basic syntax check and verifies that only allowed
 // packages are imported.
 func ValidateSource(source string) error {
+	imports, err := SourceImports(source)
+	if err != nil {
+		return err
+	}
+	for _, pkg := range imports {
+		if !IsPackageAllowed(pkg) {
+			return fmt.Errorf("import %q is not allowed in dynamic components", pkg)
+		}
+	}
+	return nil
+}
+
+// SourceImports returns the import paths of a component's source.
+func SourceImports(source string) ([]string, error) {
 	fset := token.NewFileSet()
 	f, err := parser.ParseFile(fset, "dynamic.go", source, parser.ImportsOnly)
 	if err != nil {
-		return fmt.Errorf("syntax error: %w", err)
+		return nil, fmt.Errorf("syntax error: %w", err)
 	}
 
+	imports := make([]string, 0, len(f.Imports))
 	for _, imp := range f.Imports {
 		// imp.Path.Value includes surrounding quotes
-		pkg := strings.Trim(imp.Path.Value, `"`)
-		if !IsPackageAllowed(pkg) {
-			return fmt.Errorf("import %q is not allowed in dynamic components", pkg)
-		}
+		imports = append(imports, strings.Trim(imp.Path.Value, `"`))
 	}
-	return nil
+	return imports, nil
 }
 
-// LoadFromString validates, compiles, and registers a component from source.
-func (l *Loader) LoadFromString(id, source string) (*DynamicComponent, error) {
-	if err := ValidateSource(source); err != nil {
+// Compile validates and compiles source into a component without registering
+// it, for one-off executions such as previews.
+func (l *Loader) Compile(id, source string) (*DynamicComponent, error) {
+	return l.CompileFiles(id, map[string]string{SingleFileName: source})
+}
+
+// CompileFiles validates and compiles a multi-file component without
+// registering it.
+func (l *Loader) CompileFiles(id string, files map[string]string) (*DynamicComponent, error) {
+	if err := ValidateFiles(files, l.pool.IsPackageAllowed); err != nil {
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 
 	comp := NewDynamicComponent(id, l.pool)
-	if err := comp.LoadFromSource(source); err != nil {
+	if err := comp.LoadFromFiles(files); err != nil {
 		return nil, err
 	}
+	return comp, nil
+}
 
+// LoadFromString validates, compiles, and registers a component from source.
+func (l *Loader) LoadFromString(id, source string) (*DynamicComponent, error) {
+	return l.LoadFromFiles(id, map[string]string{SingleFileName: source})
+}
+
+// LoadFromFiles validates, compiles, and registers a component made of
+// several source files (file name to source). Every file is checked against
+// the sandbox before any is compiled.
+func (l *Loader) LoadFromFiles(id string, files map[string]string) (*DynamicComponent, error) {
+	comp, err := l.CompileFiles(id, files)
+	if err != nil {
+		return nil, err
+	}
 	if err := l.registry.Register(id, comp); err != nil {
 		return nil, err
 	}
@@ -77,7 +111,35 @@ func (l *Loader) LoadFromFile(id, path string) (*DynamicComponent, error) {
 	return l.LoadFromString(id, string(data))
 }
 
-// LoadFromDirectory scans a directory for .go files and loads each one.
+// LoadFromComponentDir loads the .go files directly inside dir as one
+// multi-file component. The component ID defaults to the directory name.
+func (l *Loader) LoadFromComponentDir(id, dir string) (*DynamicComponent, error) {
+	files, err := ReadComponentDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	if id == "" {
+		id = filepath.Base(dir)
+	}
+	return l.LoadFromFiles(id, files)
+}
+
+// LoadFromPath loads a component from a .go file or, when path is a
+// directory, from all of its .go files.
+func (l *Loader) LoadFromPath(id, path string) (*DynamicComponent, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if info.IsDir() {
+		return l.LoadFromComponentDir(id, path)
+	}
+	return l.LoadFromFile(id, path)
+}
+
+// LoadFromDirectory loads each .go file in dir as a component, and each
+// subdirectory holding .go files as a multi-file component named after the
+// subdirectory.
 func (l *Loader) LoadFromDirectory(dir string) ([]*DynamicComponent, error) {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
@@ -86,14 +148,25 @@ func (l *Loader) LoadFromDirectory(dir string) ([]*DynamicComponent, error) {
 
 	var components []*DynamicComponent
 	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".go") {
+		path := filepath.Join(dir, entry.Name())
+		if entry.IsDir() {
+			files, err := ReadComponentDir(path)
+			if err != nil {
+				return components, err
+			}
+			if len(files) == 0 {
+				continue
+			}
+			comp, err := l.LoadFromFiles(entry.Name(), files)
+			if err != nil {
+				return components, fmt.Errorf("failed to load %s: %w", path, err)
+			}
+			components = append(components, comp)
 			continue
 		}
-		// Skip test files
-		if strings.HasSuffix(entry.Name(), "_test.go") {
+		if !isGoFile(entry.Name()) {
 			continue
 		}
-		path := filepath.Join(dir, entry.Name())
 		comp, err := l.LoadFromFile("", path)
 		if err != nil {
 			return components, fmt.Errorf("failed to load %s: %w", path, err)
@@ -105,6 +178,11 @@ func (l *Loader) LoadFromDirectory(dir string) ([]*DynamicComponent, error) {
 
 // Reload unloads an existing component and reloads it from new source.
 func (l *Loader) Reload(id, source string) (*DynamicComponent, error) {
+	return l.ReloadFiles(id, map[string]string{SingleFileName: source})
+}
+
+// ReloadFiles unloads an existing component and reloads it from new files.
+func (l *Loader) ReloadFiles(id string, files map[string]string) (*DynamicComponent, error) {
 	// Stop old component if it was running
 	if old, ok := l.registry.Get(id); ok {
 		info := old.Info()
@@ -114,17 +192,5 @@ func (l *Loader) Reload(id, source string) (*DynamicComponent, error) {
 		}
 	}
 
-	if err := ValidateSource(source); err != nil {
-		return nil, fmt.Errorf("validation failed: %w", err)
-	}
-
-	comp := NewDynamicComponent(id, l.pool)
-	if err := comp.LoadFromSource(source); err != nil {
-		return nil, err
-	}
-
-	if err := l.registry.Register(id, comp); err != nil {
-		return nil, err
-	}
-	return comp, nil
+	return l.LoadFromFiles(id, files)
 }
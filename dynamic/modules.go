@@ -0,0 +1,69 @@
+package dynamic
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/GoCodeAlone/yaegi/interp"
+)
+
+// ImportableModule is a third-party Go package that dynamic components may
+// import once the engine enables it. Its symbols are compiled into the host
+// binary and handed to the interpreter, so a component cannot pull in code
+// beyond what the host vendored.
+//
+// Modules must be pure Go and must not reach the filesystem, network, or
+// process state; Symbols should list only the functions and types that keep
+// that promise (e.g. omit setters for package-level state).
+type ImportableModule struct {
+	// Path is the import path components use.
+	Path string
+	// Description says what the module is for, for listings.
+	Description string
+	// Symbols are the interpreter exports, keyed "path/name" as generated by
+	// `yaegi extract`.
+	Symbols interp.Exports
+}
+
+var (
+	importableMu      sync.RWMutex
+	importableModules = map[string]ImportableModule{}
+)
+
+// RegisterImportableModule adds m to the modules an engine can enable for
+// dynamic components. Hosts embedding the engine use it to vendor their own
+// curated packages; registering a path twice replaces the earlier entry.
+func RegisterImportableModule(m ImportableModule) {
+	importableMu.Lock()
+	defer importableMu.Unlock()
+	importableModules[m.Path] = m
+}
+
+// ImportableModules returns the import paths of all registered modules in
+// sorted order.
+func ImportableModules() []string {
+	importableMu.RLock()
+	defer importableMu.RUnlock()
+	return slices.Sorted(maps.Keys(importableModules))
+}
+
+// LookupImportableModule returns the registered module with the given path.
+func LookupImportableModule(path string) (ImportableModule, bool) {
+	importableMu.RLock()
+	defer importableMu.RUnlock()
+	m, ok := importableModules[path]
+	return m, ok
+}
+
+// ValidateImportableModules returns an error naming the first path that is
+// not a registered importable module.
+func ValidateImportableModules(paths []string) error {
+	for _, p := range paths {
+		if _, ok := LookupImportableModule(p); !ok {
+			return fmt.Errorf("dynamic module %q is not importable (available: %v)", p, ImportableModules())
+		}
+	}
+	return nil
+}
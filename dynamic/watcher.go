@@ -29,6 +29,9 @@ func WithLogger(l *log.Logger) WatcherOption {
 }
 
 // Watcher monitors a directory for .go file changes and hot-reloads components.
+// Each .go file directly in the directory is a component named after the
+// file; each subdirectory is a multi-file component named after the
+// subdirectory and reloaded as a whole when any of its files change.
 type Watcher struct {
 	loader   *Loader
 	dir      string
@@ -72,6 +75,19 @@ func (w *Watcher) Start() error {
 		_ = fsw.Close()
 		return err
 	}
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		_ = fsw.Close()
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			if err := fsw.Add(filepath.Join(w.dir, entry.Name())); err != nil {
+				_ = fsw.Close()
+				return err
+			}
+		}
+	}
 
 	w.wg.Add(1)
 	go w.loop()
@@ -103,17 +119,7 @@ func (w *Watcher) loop() {
 			if !ok {
 				return
 			}
-			if !isGoFile(event.Name) {
-				continue
-			}
-			if event.Op&(fsnotify.Write|fsnotify.Create) != 0 {
-				w.mu.Lock()
-				w.pending[event.Name] = time.Now()
-				w.mu.Unlock()
-			}
-			if event.Op&fsnotify.Remove != 0 {
-				w.handleRemove(event.Name)
-			}
+			w.handleEvent(event)
 
 		case err, ok := <-w.fsWatcher.Errors:
 			if !ok {
@@ -127,6 +133,48 @@ func (w *Watcher) loop() {
 	}
 }
 
+// handleEvent queues a reload for the component an event affects: the file
+// itself for a top-level .go file, or the enclosing component directory.
+func (w *Watcher) handleEvent(event fsnotify.Event) {
+	parent := filepath.Dir(event.Name)
+	switch {
+	case parent == filepath.Clean(w.dir) && isGoFile(event.Name):
+		if event.Op&(fsnotify.Write|fsnotify.Create) != 0 {
+			w.queue(event.Name)
+		}
+		if event.Op&fsnotify.Remove != 0 {
+			w.handleRemove(fileToID(event.Name))
+		}
+
+	case parent == filepath.Clean(w.dir):
+		// A component directory appeared or went away.
+		if event.Op&fsnotify.Create != 0 {
+			if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+				if err := w.fsWatcher.Add(event.Name); err != nil {
+					w.logger.Printf("failed to watch %s: %v", event.Name, err)
+				}
+				w.queue(event.Name)
+			}
+		}
+		if event.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+			if id := filepath.Base(event.Name); w.isRegistered(id) {
+				w.handleRemove(id)
+			}
+		}
+
+	case filepath.Dir(parent) == filepath.Clean(w.dir) && isGoFile(event.Name):
+		if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) != 0 {
+			w.queue(parent)
+		}
+	}
+}
+
+func (w *Watcher) queue(path string) {
+	w.mu.Lock()
+	w.pending[path] = time.Now()
+	w.mu.Unlock()
+}
+
 func (w *Watcher) processPending() {
 	w.mu.Lock()
 	now := time.Now()
@@ -148,6 +196,15 @@ func (w *Watcher) processPending() {
 
 func (w *Watcher) handleChange(path string) {
 	id := fileToID(path)
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		// Removed before the debounce fired; the remove event handles it.
+		return
+	}
+	if err == nil && info.IsDir() {
+		w.handleDirChange(filepath.Base(path), path)
+		return
+	}
 	data, err := os.ReadFile(path)
 	if err != nil {
 		w.logger.Printf("failed to read %s: %v", path, err)
@@ -162,13 +219,38 @@ func (w *Watcher) handleChange(path string) {
 	w.logger.Printf("reloaded component %s from %s", id, path)
 }
 
-func (w *Watcher) handleRemove(path string) {
-	id := fileToID(path)
+// handleDirChange reloads a multi-file component from its directory, or
+// unregisters it once the directory holds no .go files.
+func (w *Watcher) handleDirChange(id, dir string) {
+	files, err := ReadComponentDir(dir)
+	if err != nil {
+		w.logger.Printf("failed to read %s: %v", dir, err)
+		return
+	}
+	if len(files) == 0 {
+		if w.isRegistered(id) {
+			w.handleRemove(id)
+		}
+		return
+	}
+	if _, err := w.loader.ReloadFiles(id, files); err != nil {
+		w.logger.Printf("failed to reload component %s: %v", id, err)
+		return
+	}
+	w.logger.Printf("reloaded component %s from %d files in %s", id, len(files), dir)
+}
+
+func (w *Watcher) isRegistered(id string) bool {
+	_, ok := w.loader.registry.Get(id)
+	return ok
+}
+
+func (w *Watcher) handleRemove(id string) {
 	if err := w.loader.registry.Unregister(id); err != nil {
 		w.logger.Printf("failed to unregister component %s: %v", id, err)
 		return
 	}
-	w.logger.Printf("unregistered component %s (file removed)", id)
+	w.logger.Printf("unregistered component %s (source removed)", id)
 }
 
 func isGoFile(name string) bool {
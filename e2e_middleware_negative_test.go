@@ -217,7 +217,7 @@ func TestE2E_Negative_Auth_TokenContentPropagation(t *testing.T) {
 		body, _ := io.ReadAll(resp.Body)
 		bodyStr := strings.TrimSpace(string(body))
 
-		// http.Error produces text/plain response; verify it contains an error description
+		// The problem document's detail describes the error
 		if bodyStr == "" {
 			t.Error("Error response body should not be empty")
 		}
@@ -329,12 +329,11 @@ func TestE2E_Negative_RateLimit_PerClientIsolation(t *testing.T) {
 			t.Fatalf("Expected 429, got %d", resp.StatusCode)
 		}
 
-		bodyStr := strings.TrimSpace(string(body))
-		// The middleware uses http.Error(w, "Rate limit exceeded", 429)
-		if bodyStr != "Rate limit exceeded" {
-			t.Errorf("Expected exact error 'Rate limit exceeded', got %q", bodyStr)
+		p := decodeProblemBody(t, resp, body)
+		if p.Code != string(module.ProblemRateLimited) || p.Detail != "rate limit exceeded" {
+			t.Errorf("Expected rate_limited problem, got %+v", p)
 		}
-		t.Logf("429 body matches expected message: %q", bodyStr)
+		t.Logf("429 body matches expected problem: %+v", p)
 	})
 }
 
@@ -1109,18 +1108,11 @@ func TestE2E_Negative_Auth_ErrorResponses(t *testing.T) {
 			t.Fatalf("Expected 401, got %d", resp.StatusCode)
 		}
 
-		bodyStr := strings.TrimSpace(string(body))
-		// The middleware uses: http.Error(w, "Authorization header required", 401)
-		if bodyStr != "Authorization header required" {
-			t.Errorf("Expected exact error 'Authorization header required', got %q", bodyStr)
-		}
-
-		// http.Error sets text/plain content type
-		ct := resp.Header.Get("Content-Type")
-		if !strings.Contains(ct, "text/plain") {
-			t.Errorf("Expected Content-Type containing 'text/plain', got %q", ct)
+		p := decodeProblemBody(t, resp, body)
+		if p.Code != string(module.ProblemUnauthorized) || p.Detail != "Authorization header required" {
+			t.Errorf("Expected unauthorized problem 'Authorization header required', got %+v", p)
 		}
-		t.Logf("No auth header error: status=401, body=%q, content-type=%q", bodyStr, ct)
+		t.Logf("No auth header error: status=401, problem=%+v", p)
 	})
 
 	t.Run("wrong_scheme_error", func(t *testing.T) {
@@ -1139,12 +1131,11 @@ func TestE2E_Negative_Auth_ErrorResponses(t *testing.T) {
 			t.Fatalf("Expected 401, got %d", resp.StatusCode)
 		}
 
-		bodyStr := strings.TrimSpace(string(body))
-		// The middleware uses: http.Error(w, fmt.Sprintf("%s authorization required", m.authType), 401)
-		if bodyStr != "Bearer authorization required" {
-			t.Errorf("Expected 'Bearer authorization required', got %q", bodyStr)
+		p := decodeProblemBody(t, resp, body)
+		if p.Detail != "Bearer authorization required" {
+			t.Errorf("Expected 'Bearer authorization required', got %+v", p)
 		}
-		t.Logf("Wrong scheme error: %q", bodyStr)
+		t.Logf("Wrong scheme error: %+v", p)
 	})
 
 	t.Run("invalid_token_error", func(t *testing.T) {
@@ -1163,12 +1154,11 @@ func TestE2E_Negative_Auth_ErrorResponses(t *testing.T) {
 			t.Fatalf("Expected 401, got %d", resp.StatusCode)
 		}
 
-		bodyStr := strings.TrimSpace(string(body))
-		// The middleware uses: http.Error(w, "Invalid credentials", 401)
-		if bodyStr != "Invalid credentials" {
-			t.Errorf("Expected 'Invalid credentials', got %q", bodyStr)
+		p := decodeProblemBody(t, resp, body)
+		if p.Detail != "Invalid credentials" {
+			t.Errorf("Expected 'Invalid credentials', got %+v", p)
 		}
-		t.Logf("Invalid token error: %q", bodyStr)
+		t.Logf("Invalid token error: %+v", p)
 	})
 
 	t.Run("error_does_not_leak_sensitive_info", func(t *testing.T) {
@@ -1236,12 +1226,11 @@ func TestE2E_Negative_Auth_ErrorResponses(t *testing.T) {
 			resp.Body.Close()
 
 			ct := resp.Header.Get("Content-Type")
-			// http.Error always sets text/plain; charset=utf-8
-			if !strings.Contains(ct, "text/plain") {
-				t.Errorf("%s: Expected Content-Type 'text/plain', got %q", tc.name, ct)
+			if ct != module.ProblemContentType {
+				t.Errorf("%s: Expected Content-Type %q, got %q", tc.name, module.ProblemContentType, ct)
 			}
 		}
-		t.Log("All auth error responses use consistent text/plain Content-Type")
+		t.Log("All auth error responses use consistent application/problem+json Content-Type")
 	})
 }
 
@@ -1430,11 +1419,28 @@ func TestE2E_Negative_Middleware_ResponseHeaders(t *testing.T) {
 		if !strings.Contains(successCT, "application/json") {
 			t.Errorf("Expected success Content-Type to contain 'application/json', got %q", successCT)
 		}
-		// Error uses http.Error which sets text/plain
-		if !strings.Contains(errorCT, "text/plain") {
-			t.Errorf("Expected error Content-Type to contain 'text/plain', got %q", errorCT)
+		// Errors are problem documents
+		if errorCT != module.ProblemContentType {
+			t.Errorf("Expected error Content-Type %q, got %q", module.ProblemContentType, errorCT)
 		}
 
 		t.Logf("Content-Type - success: %q, error: %q", successCT, errorCT)
 	})
 }
+
+// decodeProblemBody decodes a middleware error response, which is an
+// application/problem+json document.
+func decodeProblemBody(t *testing.T, resp *http.Response, body []byte) module.Problem {
+	t.Helper()
+	if ct := resp.Header.Get("Content-Type"); ct != module.ProblemContentType {
+		t.Errorf("Expected Content-Type %q, got %q", module.ProblemContentType, ct)
+	}
+	var p module.Problem
+	if err := json.Unmarshal(body, &p); err != nil {
+		t.Fatalf("Error body is not a problem document: %v: %s", err, body)
+	}
+	if p.Status != resp.StatusCode {
+		t.Errorf("Problem status %d does not match response status %d", p.Status, resp.StatusCode)
+	}
+	return p
+}
@@ -0,0 +1,162 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow/config"
+	"github.com/GoCodeAlone/workflow/handlers"
+	plugineventstore "github.com/GoCodeAlone/workflow/plugins/eventstore"
+	evstore "github.com/GoCodeAlone/workflow/store"
+	"github.com/google/uuid"
+)
+
+// TestE2E_EventStoreProjection verifies that an eventstore.projection
+// folds execution events into a table that a step.db_query pipeline can
+// read, and that its lag is reported by the engine.
+func TestE2E_EventStoreProjection(t *testing.T) {
+	port := getFreePort(t)
+	baseURL := fmt.Sprintf("http://127.0.0.1:%d", port)
+	dir := t.TempDir()
+
+	cfg, err := config.LoadFromString(fmt.Sprintf(`
+modules:
+  - name: server
+    type: http.server
+    config:
+      address: ":%d"
+  - name: router
+    type: http.router
+    dependsOn: [server]
+  - name: events
+    type: eventstore.service
+    config:
+      db_path: %q
+  - name: views
+    type: storage.sqlite
+    config:
+      dbPath: %q
+  - name: runs_per_pipeline
+    type: eventstore.projection
+    config:
+      event_store: events
+      database: views
+      table: runs_per_pipeline
+      key: "{{ .event.pipeline }}"
+      events: [execution.completed, execution.failed]
+      poll_interval: 50ms
+      columns:
+        completed: INTEGER
+        failed: INTEGER
+      steps:
+        - name: count
+          type: step.set
+          config:
+            values:
+              row:
+                completed: '{{ if .row }}{{ .row.completed }}{{ else }}0{{ end }}'
+                failed: '{{ if .row }}{{ .row.failed }}{{ else }}0{{ end }}'
+        - name: increment
+          type: step.set
+          config:
+            values:
+              row:
+                completed: '{{ if eq .event.type "execution.completed" }}{{ add .row.completed 1 }}{{ else }}{{ .row.completed }}{{ end }}'
+                failed: '{{ if eq .event.type "execution.failed" }}{{ add .row.failed 1 }}{{ else }}{{ .row.failed }}{{ end }}'
+
+workflows:
+  http:
+    server: server
+    router: router
+    routes: []
+
+pipelines:
+  stats:
+    trigger:
+      type: http
+      config: {method: GET, path: /stats}
+    steps:
+      - name: query
+        type: step.db_query
+        config:
+          database: views
+          query: "SELECT id, completed, failed FROM runs_per_pipeline ORDER BY id"
+      - name: respond
+        type: step.json_response
+        config:
+          body: '{{ json .steps.query.rows }}'
+`, port, filepath.Join(dir, "events.db"), filepath.Join(dir, "views.db")))
+	if err != nil {
+		t.Fatalf("LoadFromString: %v", err)
+	}
+
+	logger := &mockLogger{}
+	app := modular.NewStdApplication(modular.NewStdConfigProvider(nil), logger)
+	engine := NewStdEngine(app, logger)
+	loadAllPlugins(t, engine)
+	if err := engine.LoadPlugin(plugineventstore.New()); err != nil {
+		t.Fatalf("LoadPlugin(eventstore) failed: %v", err)
+	}
+	engine.RegisterWorkflowHandler(handlers.NewHTTPWorkflowHandler())
+	if err := engine.BuildFromConfig(cfg); err != nil {
+		t.Fatalf("BuildFromConfig failed: %v", err)
+	}
+	if err := engine.Start(t.Context()); err != nil {
+		t.Fatalf("Engine start failed: %v", err)
+	}
+	defer engine.Stop(context.Background())
+	waitForServer(t, baseURL, 5*time.Second)
+
+	store, ok := app.SvcRegistry()["events"].(*evstore.SQLiteEventStore)
+	if !ok {
+		t.Fatal("event store service not registered")
+	}
+	ctx := context.Background()
+	for _, run := range []struct{ pipeline, outcome string }{
+		{"orders", evstore.EventExecutionCompleted},
+		{"orders", evstore.EventExecutionFailed},
+		{"orders", evstore.EventExecutionCompleted},
+		{"refunds", evstore.EventExecutionCompleted},
+	} {
+		id := uuid.New()
+		if err := store.Append(ctx, id, evstore.EventExecutionStarted, map[string]any{"pipeline": run.pipeline}); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Append(ctx, id, run.outcome, map[string]any{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		st := engine.Projections()["runs_per_pipeline"]
+		if st.Head == 8 && st.Lag == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("projection did not catch up: %+v", st)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	resp, err := http.Get(baseURL + "/stats")
+	if err != nil {
+		t.Fatalf("GET /stats: %v", err)
+	}
+	defer resp.Body.Close()
+	var rows []map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&rows); err != nil {
+		t.Fatalf("decode /stats: %v", err)
+	}
+	got := fmt.Sprint(rows)
+	want := "[map[completed:2 failed:1 id:orders] map[completed:1 failed:0 id:refunds]]"
+	if got != want {
+		t.Errorf("/stats = %s, want %s", got, want)
+	}
+}
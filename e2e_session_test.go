@@ -0,0 +1,162 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow/config"
+	"github.com/GoCodeAlone/workflow/handlers"
+)
+
+// TestE2E_HTTPSession_Pipelines verifies that pipeline routes see the
+// router's http.session: a login pipeline stores a value and regenerates
+// the session ID, a later request reads it back, and logout ends it.
+func TestE2E_HTTPSession_Pipelines(t *testing.T) {
+	port := getFreePort(t)
+	baseURL := fmt.Sprintf("http://127.0.0.1:%d", port)
+
+	cfg, err := config.LoadFromString(fmt.Sprintf(`
+modules:
+  - name: server
+    type: http.server
+    config:
+      address: ":%d"
+  - name: router
+    type: http.router
+    dependsOn: [server]
+  - name: sessions
+    type: http.session
+    dependsOn: [router]
+    config:
+      store: memory
+      cookie:
+        secure: false
+
+workflows:
+  http:
+    server: server
+    router: router
+    routes: []
+
+pipelines:
+  login:
+    trigger:
+      type: http
+      config: {method: POST, path: /login}
+    steps:
+      - name: store
+        type: step.session_set
+        config:
+          values: {user_id: "u-42"}
+          regenerate: true
+      - name: respond
+        type: step.json_response
+        config:
+          body: {ok: true}
+  me:
+    trigger:
+      type: http
+      config: {method: GET, path: /me}
+    steps:
+      - name: user
+        type: step.session_get
+        config: {key: user_id}
+      - name: respond
+        type: step.json_response
+        config:
+          body:
+            user_id: "{{ .steps.user.value }}"
+            found: "{{ .steps.user.found }}"
+  logout:
+    trigger:
+      type: http
+      config: {method: POST, path: /logout}
+    steps:
+      - name: end
+        type: step.session_destroy
+      - name: respond
+        type: step.json_response
+        config:
+          body: {ok: true}
+`, port))
+	if err != nil {
+		t.Fatalf("LoadFromString: %v", err)
+	}
+
+	logger := &mockLogger{}
+	app := modular.NewStdApplication(modular.NewStdConfigProvider(nil), logger)
+	engine := NewStdEngine(app, logger)
+	loadAllPlugins(t, engine)
+	engine.RegisterWorkflowHandler(handlers.NewHTTPWorkflowHandler())
+	if err := engine.BuildFromConfig(cfg); err != nil {
+		t.Fatalf("BuildFromConfig failed: %v", err)
+	}
+	if err := engine.Start(t.Context()); err != nil {
+		t.Fatalf("Engine start failed: %v", err)
+	}
+	defer engine.Stop(context.Background())
+	waitForServer(t, baseURL, 5*time.Second)
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{Timeout: 5 * time.Second, Jar: jar}
+	u, _ := url.Parse(baseURL)
+	sessionCookie := func() string {
+		for _, c := range jar.Cookies(u) {
+			if c.Name == "wf_session" {
+				return c.Value
+			}
+		}
+		return ""
+	}
+	me := func() map[string]any {
+		resp, err := client.Get(baseURL + "/me")
+		if err != nil {
+			t.Fatalf("GET /me: %v", err)
+		}
+		defer resp.Body.Close()
+		var body map[string]any
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatalf("decode /me: %v", err)
+		}
+		return body
+	}
+
+	if body := me(); body["found"] != "false" {
+		t.Errorf("before login: /me = %v", body)
+	}
+	resp, err := client.Post(baseURL+"/login", "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatalf("POST /login: %v", err)
+	}
+	resp.Body.Close()
+	cookie := sessionCookie()
+	if cookie == "" {
+		t.Fatal("login set no session cookie")
+	}
+	if body := me(); body["user_id"] != "u-42" || body["found"] != "true" {
+		t.Errorf("after login: /me = %v", body)
+	}
+	if sessionCookie() != cookie {
+		t.Error("reading the session changed its ID")
+	}
+
+	resp, err = client.Post(baseURL+"/logout", "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatalf("POST /logout: %v", err)
+	}
+	resp.Body.Close()
+	if c := sessionCookie(); c != "" {
+		t.Errorf("logout left the session cookie set")
+	}
+	if body := me(); body["found"] != "false" {
+		t.Errorf("after logout: /me = %v", body)
+	}
+}
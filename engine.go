@@ -8,21 +8,27 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/GoCodeAlone/modular"
 	"github.com/GoCodeAlone/workflow/capability"
 	"github.com/GoCodeAlone/workflow/config"
 	"github.com/GoCodeAlone/workflow/dynamic"
+	_ "github.com/GoCodeAlone/workflow/dynamic/builtinmodules" // registers the built-in importable modules
+	"github.com/GoCodeAlone/workflow/i18n"
 	"github.com/GoCodeAlone/workflow/infra"
 	"github.com/GoCodeAlone/workflow/interfaces"
 	"github.com/GoCodeAlone/workflow/internal/legacyaws"
 	"github.com/GoCodeAlone/workflow/internal/legacydo"
 	"github.com/GoCodeAlone/workflow/module"
 	"github.com/GoCodeAlone/workflow/plugin"
+	"github.com/GoCodeAlone/workflow/scheduler/cron"
 	"github.com/GoCodeAlone/workflow/schema"
 	"github.com/GoCodeAlone/workflow/secrets"
 	"github.com/GoCodeAlone/workflow/validation"
+	"github.com/GoCodeAlone/workflow/version"
+	"github.com/GoCodeAlone/workflow/worker"
 	"gopkg.in/yaml.v3"
 )
 
@@ -90,6 +96,10 @@ type StdEngine struct {
 	// step.workflow_call to look up sibling pipelines at execution time.
 	pipelineRegistry map[string]*module.Pipeline
 
+	// durablePipelines holds the durable pipelines, including route
+	// pipelines, by name for the startup recovery pass.
+	durablePipelines map[string]*module.Pipeline
+
 	// provisioner holds the infrastructure provisioner when an infrastructure
 	// block is declared in the config. Nil when no infrastructure is declared.
 	provisioner *infra.Provisioner
@@ -97,6 +107,51 @@ type StdEngine struct {
 	// configHash is the SHA-256 hash of the last config built via BuildFromConfig.
 	// Format: "sha256:<hex>". Empty until BuildFromConfig is called.
 	configHash string
+
+	// moduleDependsOn records each config module's resolvable dependsOn so
+	// Start can order modules that do not implement SetDependencies.
+	moduleDependsOn map[string][]string
+	// serviceWiring and serviceContracts record the provides/needs
+	// contracts of the last build; see orderByServiceContracts.
+	serviceWiring    []config.ServiceWiring
+	serviceContracts map[string]config.ModuleContracts
+	// startConcurrency bounds parallel module start (see startModules), and
+	// stopModuleCtx cancels the lifecycle context modules were started with.
+	startConcurrency int
+	stopModuleCtx    context.CancelFunc
+	// startupTimeout and moduleTimeout bound module Init and Start (see
+	// SetStartupTimeouts); zero means no limit.
+	startupTimeout time.Duration
+	moduleTimeout  time.Duration
+	// startGrace bounds how long a timed-out Start waits for the starts it
+	// cancelled to return (see DefaultStartTimeoutGrace).
+	startGrace time.Duration
+	// moduleOptional records the modules whose startPolicy is optional;
+	// degraded holds those of them that failed to start (DegradedModules).
+	moduleOptional map[string]bool
+	degradedMu     sync.RWMutex
+	degraded       map[string]error
+	// startedModules lists the modules startModules started, in start
+	// order; rolledBack is set once a failed Start has stopped them again.
+	startedModules []string
+	rolledBack     bool
+	// strictTemplates is engine.strict_templates from the last build; it
+	// makes every pipeline resolve templates strictly.
+	strictTemplates bool
+	// problemDetail is engine.errors.detail from the last build; it sets
+	// the detail level of problem+json error responses.
+	problemDetail module.ProblemDetailLevel
+
+	// propagatePanics is set when engine.errors.recover_panics is false; it
+	// sets Pipeline.PropagatePanics.
+	propagatePanics bool
+	// locales is the catalog of the locales: section from the last build,
+	// or nil when the config declares none.
+	locales *i18n.Catalog
+	// executionQueue receives the executions of routes placed on workers,
+	// recorded under executionWorkflowID; see SetExecutionQueue.
+	executionQueue      worker.Queue
+	executionWorkflowID string
 }
 
 // App returns the underlying modular.Application.
@@ -110,6 +165,16 @@ func (e *StdEngine) ConfigHash() string {
 	return e.configHash
 }
 
+// ProblemDetailLevel returns the detail level of problem+json error
+// responses, from engine.errors.detail. It implements
+// module.ProblemDetailProvider for the HTTP trigger.
+func (e *StdEngine) ProblemDetailLevel() module.ProblemDetailLevel {
+	if e.problemDetail == "" {
+		return module.ProblemDetailFull
+	}
+	return e.problemDetail
+}
+
 // SetDynamicRegistry sets the dynamic component registry on the engine
 // and propagates it to any loaded plugins that support it.
 func (e *StdEngine) SetDynamicRegistry(registry *dynamic.ComponentRegistry) {
@@ -160,6 +225,9 @@ func NewStdEngine(app modular.Application, logger modular.Logger) *StdEngine {
 		triggerTypeMap:        make(map[string]string),
 		triggerConfigWrappers: make(map[string]plugin.TriggerConfigWrapperFunc),
 		pipelineRegistry:      make(map[string]*module.Pipeline),
+		durablePipelines:      make(map[string]*module.Pipeline),
+		startConcurrency:      DefaultModuleStartConcurrency,
+		startGrace:            DefaultStartTimeoutGrace,
 	}
 	// Register the step.workflow_call factory with a closure that looks up
 	// pipelines from this engine's registry at execution time.
@@ -301,6 +369,12 @@ func (e *StdEngine) loadPluginInternal(p plugin.EnginePlugin, allowOverride bool
 	if err != nil {
 		return fmt.Errorf("load plugin: %w", err)
 	}
+	// Register template functions from plugin (optional interface).
+	if provider, ok := p.(plugin.TemplateFuncProvider); ok {
+		if err := module.RegisterTemplateFuncs(p.EngineManifest().Name, provider.TemplateFuncs()); err != nil {
+			return fmt.Errorf("load plugin: %w", err)
+		}
+	}
 	if module.DetectTransportMutation(transportSnapshot) {
 		pluginName := p.EngineManifest().Name
 		e.logger.Warn(fmt.Sprintf("plugin %q mutated http.DefaultTransport; restoring original to prevent cross-plugin interference", pluginName))
@@ -448,6 +522,26 @@ func (e *StdEngine) validateRequirements(req *config.RequiresConfig) error {
 
 // BuildFromConfig builds a workflow from configuration
 func (e *StdEngine) BuildFromConfig(cfg *config.WorkflowConfig) error {
+	// Drop modules and routes marked enabled: false. The caller's config is
+	// left intact so the disabled entries survive reloads.
+	if cfg != nil {
+		for i := range cfg.Modules {
+			if !cfg.Modules[i].IsEnabled() {
+				e.logger.Info("Skipping disabled module: " + cfg.Modules[i].Name)
+			}
+		}
+	}
+	// Resolve ${module.<name>.config.<key>} references before dropping
+	// disabled modules, so their config values can still be shared.
+	cfg, err := config.ResolveModuleConfigRefs(cfg)
+	if err != nil {
+		return fmt.Errorf("config validation failed: %w", err)
+	}
+	cfg, err = config.ApplyEnabled(cfg)
+	if err != nil {
+		return fmt.Errorf("config validation failed: %w", err)
+	}
+
 	// Validate configuration before building.
 	// Allow empty modules (the engine handles that gracefully) and pass
 	// registered custom module factory types so they are not rejected.
@@ -477,6 +571,9 @@ func (e *StdEngine) BuildFromConfig(cfg *config.WorkflowConfig) error {
 	if err := schema.ValidateConfig(cfg, valOpts...); err != nil {
 		return fmt.Errorf("config validation failed: %w", err)
 	}
+	for _, msg := range config.CORSWarnings(cfg) {
+		e.logger.Warn(msg)
+	}
 
 	// Run pipeline template cross-reference validation.
 	// Mode is controlled by engine.validation.templateRefs in the config:
@@ -508,6 +605,54 @@ func (e *StdEngine) BuildFromConfig(cfg *config.WorkflowConfig) error {
 				}
 			}
 		}
+		// step.metric declarations sharing a metric must agree on its shape;
+		// the collector would reject the second registration at runtime.
+		if errs := validation.ValidateMetricSteps(cfg.Pipelines); len(errs) > 0 {
+			return fmt.Errorf("metric validation failed: %s", strings.Join(errs, "; "))
+		}
+	}
+
+	// Static checks of statemachine definitions: unreachable and undeclared
+	// states, dead ends and name collisions. Severity per check comes from
+	// engine.validation.stateMachines.
+	if len(cfg.Workflows) > 0 {
+		severities, err := validation.ParseStateMachineSeverities(cfg.StateMachineSeverities())
+		if err != nil {
+			return fmt.Errorf("engine.validation.stateMachines: %w", err)
+		}
+		sm := validation.ValidateStateMachines(cfg.Workflows, severities)
+		for _, msg := range sm.Warnings {
+			e.logger.Warn(msg)
+		}
+		if len(sm.Errors) > 0 {
+			return fmt.Errorf("state machine validation failed: %s", strings.Join(sm.Errors, "; "))
+		}
+	}
+
+	if err := dynamic.ValidateImportableModules(cfg.DynamicModules()); err != nil {
+		return fmt.Errorf("engine.dynamic.modules: %w", err)
+	}
+	startupTimeout, moduleTimeout, err := cfg.StartupTimeouts()
+	if err != nil {
+		return err
+	}
+	var errorDetail string
+	if cfg.Engine != nil && cfg.Engine.Errors != nil {
+		errorDetail = cfg.Engine.Errors.Detail
+	}
+	if e.problemDetail, err = module.ParseProblemDetailLevel(errorDetail); err != nil {
+		return fmt.Errorf("engine.errors.detail: %w", err)
+	}
+	e.propagatePanics = cfg.Engine != nil && cfg.Engine.Errors != nil &&
+		cfg.Engine.Errors.RecoverPanics != nil && !*cfg.Engine.Errors.RecoverPanics
+	if e.locales, err = i18n.LoadConfig(cfg); err != nil {
+		return fmt.Errorf("locales: %w", err)
+	}
+	if startupTimeout > 0 {
+		e.startupTimeout = startupTimeout
+	}
+	if moduleTimeout > 0 {
+		e.moduleTimeout = moduleTimeout
 	}
 
 	// Validate plugin requirements if declared
@@ -588,6 +733,19 @@ func (e *StdEngine) BuildFromConfig(cfg *config.WorkflowConfig) error {
 			moduleNameSet[m.Name] = struct{}{}
 		}
 	}
+	e.moduleDependsOn = make(map[string][]string, len(cfg.Modules))
+	for _, m := range cfg.Modules {
+		if filtered := filterResolvableDeps(m.DependsOn, moduleNameSet, m.Name); len(filtered) > 0 {
+			e.moduleDependsOn[m.Name] = filtered
+		}
+	}
+	e.strictTemplates = cfg.Engine != nil && cfg.Engine.StrictTemplates
+	e.moduleOptional = make(map[string]bool)
+	for _, m := range cfg.Modules {
+		if m.StartPolicy == config.StartPolicyOptional {
+			e.moduleOptional[m.Name] = true
+		}
+	}
 
 	// Compute config hash after transform hooks + dependency ordering so the
 	// hash reflects the effective runtime config (hooks may mutate cfg, and
@@ -599,7 +757,9 @@ func (e *StdEngine) BuildFromConfig(cfg *config.WorkflowConfig) error {
 		e.configHash = fmt.Sprintf("sha256:%x", h)
 	}
 
-	// Register all modules from config
+	// Build all modules from config before registering any, so contracts
+	// modules declare in code can take part in ordering.
+	built := make([]modular.Module, 0, len(cfg.Modules))
 	for _, modCfg := range cfg.Modules {
 		// Expand secret references in all string config values before module instantiation.
 		// This replaces ${vault:...}, ${aws-sm:...}, ${env:...}, and ${VAR_NAME} patterns.
@@ -627,14 +787,31 @@ func (e *StdEngine) BuildFromConfig(cfg *config.WorkflowConfig) error {
 				_, iacLoaded := e.moduleFactories["iac.provider"]
 				return legacyaws.FormatModuleError(modCfg.Type, modCfg.Name, iacLoaded)
 			}
-			return fmt.Errorf("unknown module type %q for module %q — ensure the required plugin is loaded", modCfg.Type, modCfg.Name)
+			return &ModuleError{Module: modCfg.Name, Err: fmt.Errorf("unknown module type %q for module %q — ensure the required plugin is loaded", modCfg.Type, modCfg.Name)}
 		}
 		e.logger.Debug("Using factory for module type: " + modCfg.Type)
 		mod = factory(modCfg.Name, modCfg.Config)
 		if mod == nil {
-			return fmt.Errorf("factory for module type %q returned nil for module %q", modCfg.Type, modCfg.Name)
+			return &ModuleError{Module: modCfg.Name, Err: fmt.Errorf("factory for module type %q returned nil for module %q", modCfg.Type, modCfg.Name)}
 		}
 
+		built = append(built, mod)
+	}
+
+	// Modules declaring provides/needs are also ordered after the providers
+	// of their needs. Configs without contracts keep the dependsOn order.
+	modules, built, contractDeps, err := e.orderByServiceContracts(cfg.Modules, built, moduleNameSet)
+	if err != nil {
+		return err
+	}
+	if contractDeps != nil {
+		e.moduleDependsOn = contractDeps
+	}
+
+	// Register all modules
+	for i, mod := range built {
+		modCfg := modules[i]
+
 		// Plumb yaml-level `dependsOn:` into the module so modular's Init()
 		// walker honours it (workflow#663). Without this, external-plugin
 		// modules — which all return nil from Dependencies() by default —
@@ -645,9 +822,13 @@ func (e *StdEngine) BuildFromConfig(cfg *config.WorkflowConfig) error {
 		// information to modular so its own initialisation graph agrees
 		// with engine-level ordering. filterResolvableDeps drops empty
 		// entries + unknown names so the slice modular sees matches the
-		// edge set topoSortModules used.
+		// edge set topoSortModules used. With service contracts the
+		// providers of the module's needs are included.
 		if depTarget, ok := mod.(interface{ SetDependencies([]string) }); ok {
 			filtered := filterResolvableDeps(modCfg.DependsOn, moduleNameSet, modCfg.Name)
+			if contractDeps != nil {
+				filtered = contractDeps[modCfg.Name]
+			}
 			if len(filtered) > 0 {
 				depTarget.SetDependencies(filtered)
 			}
@@ -664,9 +845,17 @@ func (e *StdEngine) BuildFromConfig(cfg *config.WorkflowConfig) error {
 			}
 		}
 	}
-	if err := e.app.Init(); err != nil {
+	if err := e.initModules(); err != nil {
 		return fmt.Errorf("failed to initialize modules: %w", err)
 	}
+	if err := e.verifyProvidedServices(); err != nil {
+		return fmt.Errorf("service contracts: %w", err)
+	}
+	if len(e.moduleOptional) > 0 {
+		if err := e.app.RegisterService(ModuleStartHealthService, moduleStartHealth{e}); err != nil {
+			return fmt.Errorf("failed to register %s: %w", ModuleStartHealthService, err)
+		}
+	}
 
 	// Log loaded services
 	for name := range e.app.SvcRegistry() {
@@ -782,17 +971,20 @@ func (e *StdEngine) BuildFromApplicationConfig(appCfg *config.ApplicationConfig)
 	return e.BuildFromConfig(combined)
 }
 
-// Start starts all modules and triggers
+// Start starts all modules and triggers. Start is all or nothing: if a
+// required module or a trigger fails, everything already started is stopped
+// again before the error, a *StartRollbackError, is returned.
 func (e *StdEngine) Start(ctx context.Context) error {
-	err := e.app.Start()
+	err := e.startModules(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to start application: %w", err)
 	}
+	e.exportResourceUsage()
 
 	// Start all triggers
-	for _, trigger := range e.triggers {
+	for i, trigger := range e.triggers {
 		if err := trigger.Start(ctx); err != nil {
-			return fmt.Errorf("failed to start trigger '%s': %w", trigger.Name(), err)
+			return e.rollbackTriggerStart(ctx, i, fmt.Errorf("failed to start trigger '%s': %w", trigger.Name(), err))
 		}
 	}
 
@@ -811,11 +1003,14 @@ func (e *StdEngine) Stop(ctx context.Context) error {
 		}
 	}
 
-	err := e.app.Stop()
-	if err != nil {
-		lastErr = fmt.Errorf("failed to stop application: %w", err)
-		e.logger.Error(lastErr.Error())
+	// A Start that failed has already stopped the modules it started.
+	if !e.rolledBack {
+		if err := e.stopApp(); err != nil {
+			lastErr = fmt.Errorf("failed to stop application: %w", err)
+			e.logger.Error(lastErr.Error())
+		}
 	}
+	e.stopModuleContext()
 
 	return lastErr
 }
@@ -1045,13 +1240,24 @@ func (e *StdEngine) configurePipelines(pipelineCfg map[string]any) error {
 			}
 		}
 
+		durable, err := durableOptions(pipeCfg.Durable, pipeCfg.Steps)
+		if err != nil {
+			return fmt.Errorf("pipeline %q: %w", pipelineName, err)
+		}
+
 		pipeline := &module.Pipeline{
 			Name:            pipelineName,
 			Steps:           steps,
 			OnError:         onError,
 			Timeout:         timeout,
 			Compensation:    compSteps,
-			StrictTemplates: pipeCfg.StrictTemplates,
+			StrictTemplates: pipeCfg.StrictTemplates || e.strictTemplates,
+			ProblemDetail:   e.problemDetail,
+			PropagatePanics: e.propagatePanics,
+			Locales:         e.locales,
+			SecretLookup:    e.lookupSecret,
+			ConfigHash:      e.configHash,
+			Durable:         durable,
 		}
 
 		// Propagate the engine's logger to the pipeline so that execution logs
@@ -1075,6 +1281,9 @@ func (e *StdEngine) configurePipelines(pipelineCfg map[string]any) error {
 		// Register in the engine's pipeline registry so step.workflow_call can
 		// look up this pipeline at execution time.
 		e.pipelineRegistry[pipelineName] = pipeline
+		if durable != nil {
+			e.durablePipelines[pipelineName] = pipeline
+		}
 		e.logger.Info(fmt.Sprintf("Configured pipeline: %s (%d steps)", pipelineName, len(steps)))
 
 		// Create trigger from inline trigger config if present.
@@ -1136,6 +1345,39 @@ type RoutePipelineSetter interface {
 	SetRoutePipeline(routePath string, pipeline interfaces.PipelineRunner)
 }
 
+// RouteETagSetter is implemented by handlers (QueryHandler) that answer
+// conditional requests on opted-in routes.
+type RouteETagSetter interface {
+	SetRouteETag(routeKey string, cfg *module.QueryETagConfig)
+}
+
+// configureRouteETag applies the `etag` setting of an HTTP route to its
+// handler. A handler that does not support it is an error, so that the
+// setting is not silently ignored.
+func (e *StdEngine) configureRouteETag(handlerName string, routeMap map[string]any, raw any) error {
+	path, _ := routeMap["path"].(string)
+	method, _ := routeMap["method"].(string)
+	routeKey := method + " " + path
+	etag, err := module.ParseQueryETagConfig(raw)
+	if err != nil {
+		return fmt.Errorf("route %q: %w", routeKey, err)
+	}
+	if etag == nil {
+		return nil
+	}
+	svc, ok := e.app.SvcRegistry()[handlerName]
+	if !ok {
+		e.logger.Warn("Handler service not found for route etag", "handler", handlerName, "route", routeKey)
+		return nil
+	}
+	setter, ok := svc.(RouteETagSetter)
+	if !ok {
+		return fmt.Errorf("route %q: handler %q does not support etag", routeKey, handlerName)
+	}
+	setter.SetRouteETag(routeKey, etag)
+	return nil
+}
+
 // configureRoutePipelines scans HTTP workflow routes for inline pipeline steps
 // and attaches them to the appropriate CQRS handlers.
 func (e *StdEngine) configureRoutePipelines(cfg *config.WorkflowConfig) error {
@@ -1161,17 +1403,36 @@ func (e *StdEngine) configureRoutePipelines(cfg *config.WorkflowConfig) error {
 				continue
 			}
 
-			// Check for inline pipeline steps on this route
+			// Check for inline pipeline steps on this route. strict_templates
+			// and durable may be set on the route or on its inline pipeline.
 			var stepCfgs []config.PipelineStepConfig
+			strict, _ := routeMap["strict_templates"].(bool)
+			durableRaw := routeMap["durable"]
+			scheduleRaw := routeMap["schedule"]
 
 			if pipelineCfg, ok := routeMap["pipeline"].(map[string]any); ok {
 				if stepsRaw, ok := pipelineCfg["steps"].([]any); ok {
 					stepCfgs = parseRoutePipelineSteps(stepsRaw)
 				}
+				if b, _ := pipelineCfg["strict_templates"].(bool); b {
+					strict = true
+				}
+				if raw, ok := pipelineCfg["durable"]; ok {
+					durableRaw = raw
+				}
+				if raw, ok := pipelineCfg["schedule"]; ok {
+					scheduleRaw = raw
+				}
 			} else if stepsRaw, ok := routeMap["steps"].([]any); ok {
 				stepCfgs = parseRoutePipelineSteps(stepsRaw)
 			}
 
+			if raw, ok := routeMap["etag"]; ok {
+				if err := e.configureRouteETag(handlerName, routeMap, raw); err != nil {
+					return err
+				}
+			}
+
 			if len(stepCfgs) == 0 {
 				continue
 			}
@@ -1182,6 +1443,7 @@ func (e *StdEngine) configureRoutePipelines(cfg *config.WorkflowConfig) error {
 			// POST /projects/{id}/workflows vs POST /workflows).
 			path, _ := routeMap["path"].(string)
 			method, _ := routeMap["method"].(string)
+			locale, _ := routeMap["locale"].(string)
 			routeKey := method + " " + path
 			pipelineName := handlerName + ":" + lastRouteSegment(path)
 
@@ -1190,10 +1452,39 @@ func (e *StdEngine) configureRoutePipelines(cfg *config.WorkflowConfig) error {
 				return fmt.Errorf("route pipeline %q: %w", pipelineName, err)
 			}
 
+			var durableCfg *config.PipelineDurableConfig
+			if durableRaw != nil {
+				if durableCfg, err = parseRouteDurableConfig(durableRaw); err != nil {
+					return fmt.Errorf("route pipeline %q: %w", pipelineName, err)
+				}
+			}
+			durable, err := durableOptions(durableCfg, stepCfgs)
+			if err != nil {
+				return fmt.Errorf("route pipeline %q: %w", pipelineName, err)
+			}
+
+			var schedule *config.RouteScheduleConfig
+			if scheduleRaw != nil {
+				if schedule, err = parseRouteScheduleConfig(scheduleRaw); err != nil {
+					return fmt.Errorf("route pipeline %q: %w", pipelineName, err)
+				}
+			}
+
 			pipeline := &module.Pipeline{
-				Name:         pipelineName,
-				Steps:        steps,
-				RoutePattern: path,
+				Name:            pipelineName,
+				Steps:           steps,
+				RoutePattern:    path,
+				StrictTemplates: strict || e.strictTemplates,
+				ProblemDetail:   e.problemDetail,
+				PropagatePanics: e.propagatePanics,
+				Locales:         e.locales,
+				SecretLookup:    e.lookupSecret,
+				Locale:          locale,
+				ConfigHash:      e.configHash,
+				Durable:         durable,
+			}
+			if durable != nil {
+				e.durablePipelines[pipelineName] = pipeline
 			}
 
 			// Find the handler service and attach the pipeline
@@ -1207,6 +1498,12 @@ func (e *StdEngine) configureRoutePipelines(cfg *config.WorkflowConfig) error {
 				setter.SetRoutePipeline(routeKey, pipeline)
 				e.logger.Info("Attached route pipeline", "handler", handlerName, "route", routeKey, "steps", len(steps))
 			}
+
+			if schedule != nil && schedule.Enabled {
+				if err := e.scheduleRoutePipeline(svc, pipelineName, routeKey, schedule); err != nil {
+					return fmt.Errorf("route pipeline %q: %w", pipelineName, err)
+				}
+			}
 		}
 	}
 	return nil
@@ -1228,17 +1525,142 @@ func parseRoutePipelineSteps(stepsRaw []any) []config.PipelineStepConfig {
 		}
 		skipIf, _ := stepMap["skip_if"].(string)
 		ifExpr, _ := stepMap["if"].(string)
+		destructive, _ := stepMap["destructive"].(bool)
+		resumable, _ := stepMap["resumable"].(bool)
 		cfgs = append(cfgs, config.PipelineStepConfig{
-			Name:   name,
-			Type:   stepType,
-			Config: stepConfig,
-			SkipIf: skipIf,
-			If:     ifExpr,
+			Name:        name,
+			Type:        stepType,
+			Config:      stepConfig,
+			SkipIf:      skipIf,
+			If:          ifExpr,
+			Destructive: destructive,
+			Resumable:   resumable,
 		})
 	}
 	return cfgs
 }
 
+// parseRouteDurableConfig decodes the durable block of an HTTP route or its
+// inline pipeline.
+func parseRouteDurableConfig(raw any) (*config.PipelineDurableConfig, error) {
+	yamlBytes, err := yaml.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid durable config: %w", err)
+	}
+	var cfg config.PipelineDurableConfig
+	if err := yaml.Unmarshal(yamlBytes, &cfg); err != nil {
+		return nil, fmt.Errorf("invalid durable config: %w", err)
+	}
+	return &cfg, nil
+}
+
+// parseRouteScheduleConfig decodes and validates the schedule block of an
+// HTTP route or its inline pipeline.
+func parseRouteScheduleConfig(raw any) (*config.RouteScheduleConfig, error) {
+	yamlBytes, err := yaml.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule config: %w", err)
+	}
+	var cfg config.RouteScheduleConfig
+	if err := yaml.Unmarshal(yamlBytes, &cfg); err != nil {
+		return nil, fmt.Errorf("invalid schedule config: %w", err)
+	}
+	if cfg.Cron == "" {
+		return nil, fmt.Errorf("invalid schedule config: cron is required")
+	}
+	if _, err := cron.ParseInLocation(cfg.Cron, cfg.Timezone); err != nil {
+		return nil, fmt.Errorf("invalid schedule config: %w", err)
+	}
+	return &cfg, nil
+}
+
+// scheduleRoutePipeline registers a route pipeline with the schedule trigger.
+// The pipeline is added to the PipelineWorkflowHandler under its own name,
+// as a runner that executes it through the route's handler so scheduled runs
+// are recorded by the handler's execution tracker like HTTP-triggered ones.
+// Unlike a named pipeline's trigger, an enabled route schedule that cannot be
+// registered fails the build.
+func (e *StdEngine) scheduleRoutePipeline(svc any, pipelineName, routeKey string, schedule *config.RouteScheduleConfig) error {
+	runner, ok := svc.(module.RoutePipelineRunner)
+	if !ok {
+		return fmt.Errorf("schedule: handler %T cannot run route pipelines outside a request", svc)
+	}
+	var adder PipelineAdder
+	for _, handler := range e.workflowHandlers {
+		if a, ok := handler.(PipelineAdder); ok {
+			adder = a
+			break
+		}
+	}
+	if adder == nil {
+		return fmt.Errorf("schedule: no PipelineWorkflowHandler registered")
+	}
+	adder.AddPipeline(pipelineName, &scheduledRoutePipeline{runner: runner, routeKey: routeKey})
+
+	triggerCfg := map[string]any{"cron": schedule.Cron}
+	if schedule.Timezone != "" {
+		triggerCfg["timezone"] = schedule.Timezone
+	}
+	wrappedCfg := e.wrapPipelineTriggerConfig("schedule", pipelineName, triggerCfg)
+	for _, trigger := range e.triggers {
+		if e.canHandleTrigger(trigger, "schedule") {
+			if err := trigger.Configure(e.app, wrappedCfg); err != nil {
+				return fmt.Errorf("schedule: %w", err)
+			}
+			e.logger.Info("Scheduled route pipeline", "pipeline", pipelineName, "route", routeKey, "cron", schedule.Cron)
+			return nil
+		}
+	}
+	return fmt.Errorf("schedule: no schedule trigger registered")
+}
+
+// scheduledRoutePipeline is the PipelineRunner a scheduled route pipeline is
+// registered as. Its runs are recorded by the route handler's execution
+// tracker with trigger type "schedule", so it ignores the handler's logger
+// and event recorder.
+type scheduledRoutePipeline struct {
+	runner   module.RoutePipelineRunner
+	routeKey string
+}
+
+func (p *scheduledRoutePipeline) Run(ctx context.Context, data map[string]any) (map[string]any, error) {
+	return p.runner.RunRoutePipeline(module.WithTriggerType(ctx, "schedule"), p.routeKey, data)
+}
+
+func (p *scheduledRoutePipeline) SetLogger(*slog.Logger) {}
+
+func (p *scheduledRoutePipeline) SetEventRecorder(interfaces.EventRecorder) {}
+
+// durableOptions converts a pipeline's durable block into the executor's
+// options, collecting the steps marked resumable. It returns nil when the
+// pipeline is not durable.
+func durableOptions(cfg *config.PipelineDurableConfig, steps []config.PipelineStepConfig) (*module.DurableOptions, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	opts := &module.DurableOptions{
+		Recovery:         module.DurableRecoveryFail,
+		MaxSnapshotBytes: cfg.MaxSnapshotBytes,
+		Resumable:        make(map[string]bool),
+	}
+	switch cfg.Recovery {
+	case "", string(module.DurableRecoveryFail):
+	case string(module.DurableRecoveryResume):
+		opts.Recovery = module.DurableRecoveryResume
+	default:
+		return nil, fmt.Errorf("invalid durable recovery %q (want fail or resume)", cfg.Recovery)
+	}
+	if cfg.MaxSnapshotBytes < 0 {
+		return nil, fmt.Errorf("invalid durable max_snapshot_bytes %d", cfg.MaxSnapshotBytes)
+	}
+	for _, s := range steps {
+		if s.Resumable {
+			opts.Resumable[s.Name] = true
+		}
+	}
+	return opts, nil
+}
+
 // lastRouteSegment extracts the last segment of a URL path.
 func lastRouteSegment(path string) string {
 	path = strings.TrimRight(path, "/")
@@ -1271,6 +1693,22 @@ func (e *StdEngine) buildPipelineSteps(pipelineName string, stepCfgs []config.Pi
 			return nil, fmt.Errorf("step %q (type %s): %w", sc.Name, sc.Type, err)
 		}
 
+		// Apply the step timeout innermost so its deadline reaches the
+		// step's own calls (HTTP requests, external plugin RPCs).
+		if sc.Timeout != "" {
+			timeout, err := time.ParseDuration(sc.Timeout)
+			if err != nil || timeout <= 0 {
+				return nil, fmt.Errorf("step %q: invalid timeout %q", sc.Name, sc.Timeout)
+			}
+			step = module.NewTimeoutStep(step, timeout)
+		}
+
+		// Wrap destructive steps first so that dry runs preview the concrete
+		// step, and skip_if / if guards are still evaluated before it.
+		if sc.Destructive {
+			step = module.NewDestructiveStep(step)
+		}
+
 		// Wrap the step with skip_if / if guard when either field is set.
 		if sc.SkipIf != "" || sc.If != "" {
 			step = module.NewSkippableStep(step, sc.SkipIf, sc.If)
@@ -1334,6 +1772,20 @@ func (e *StdEngine) GetPipeline(name string) (*module.Pipeline, bool) {
 	return p, ok
 }
 
+// lookupSecret resolves the secret references of the hmac template function
+// against the secrets provider modules of the engine's application.
+func (e *StdEngine) lookupSecret(ctx context.Context, provider, key string) (string, error) {
+	return module.LookupSecret(ctx, e.app, provider, key)
+}
+
+// DurablePipeline returns the named durable pipeline, a pipeline or route
+// pipeline with a durable block. It is the pipeline lookup of
+// module.RecoverDurableExecutions.
+func (e *StdEngine) DurablePipeline(name string) (*module.Pipeline, bool) {
+	p, ok := e.durablePipelines[name]
+	return p, ok
+}
+
 // LoadedPlugins returns all engine plugins that were loaded via LoadPlugin.
 func (e *StdEngine) LoadedPlugins() []plugin.EnginePlugin {
 	out := make([]plugin.EnginePlugin, len(e.enginePlugins))
@@ -1341,6 +1793,24 @@ func (e *StdEngine) LoadedPlugins() []plugin.EnginePlugin {
 	return out
 }
 
+// VersionReport returns the engine build identity, the active config hash,
+// and the inventory of loaded plugins.
+func (e *StdEngine) VersionReport() version.Report {
+	r := version.Report{
+		Info:       version.Get(),
+		ConfigHash: e.configHash,
+		Plugins:    make([]version.PluginInfo, 0, len(e.enginePlugins)),
+	}
+	for _, p := range e.enginePlugins {
+		source := version.SourceBuiltin
+		if ext, ok := p.(plugin.ExternalPlugin); ok && ext.IsExternal() {
+			source = version.SourceExternal
+		}
+		r.Plugins = append(r.Plugins, version.PluginInfo{Name: p.Name(), Version: p.Version(), Source: source})
+	}
+	return r
+}
+
 // Compile-time interface check: StdEngine must satisfy PipelineExecutor.
 var _ interfaces.PipelineExecutor = (*StdEngine)(nil)
 
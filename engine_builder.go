@@ -13,6 +13,7 @@ import (
 	"github.com/GoCodeAlone/workflow/config"
 	"github.com/GoCodeAlone/workflow/dynamic"
 	"github.com/GoCodeAlone/workflow/interfaces"
+	"github.com/GoCodeAlone/workflow/observability/tracing"
 	"github.com/GoCodeAlone/workflow/plugin"
 )
 
@@ -58,8 +59,12 @@ type EngineBuilder struct {
 	useDefaultTriggers   bool
 
 	// Optional overrides
-	pluginLoader *plugin.PluginLoader
-	configPath   string
+	pluginLoader     *plugin.PluginLoader
+	configPath       string
+	startConcurrency int
+	dynamicModules   []string
+	startupTimeout   time.Duration
+	moduleTimeout    time.Duration
 
 	// Track if the caller set explicit app/logger so Build can create defaults.
 	appSet    bool
@@ -115,6 +120,15 @@ func (b *EngineBuilder) WithDynamicComponents() *EngineBuilder {
 	return b
 }
 
+// WithDynamicModules enables registered importable modules (see
+// dynamic.ImportableModules) for dynamic components. It implies
+// WithDynamicComponents.
+func (b *EngineBuilder) WithDynamicModules(paths ...string) *EngineBuilder {
+	b.useDynamicComponents = true
+	b.dynamicModules = append(b.dynamicModules, paths...)
+	return b
+}
+
 // WithAllDefaults is a convenience method that enables default handlers,
 // default triggers, and dynamic components.
 // Requires importing the setup package: import _ "github.com/GoCodeAlone/workflow/setup"
@@ -158,18 +172,39 @@ func (b *EngineBuilder) WithConfigPath(path string) *EngineBuilder {
 	return b
 }
 
+// WithModuleStartConcurrency bounds how many independent modules the engine
+// starts at once. 1 starts modules sequentially. Defaults to
+// DefaultModuleStartConcurrency.
+func (b *EngineBuilder) WithModuleStartConcurrency(n int) *EngineBuilder {
+	b.startConcurrency = n
+	return b
+}
+
+// WithStartupTimeouts bounds engine startup: total limits module Init and
+// module Start as a whole, perModule limits a single module's Init or Start.
+// Zero disables a limit. engine.startup in the config overrides these.
+func (b *EngineBuilder) WithStartupTimeouts(total, perModule time.Duration) *EngineBuilder {
+	b.startupTimeout = total
+	b.moduleTimeout = perModule
+	return b
+}
+
 // Build creates a fully-configured StdEngine from the builder's settings.
 // It returns an error if any plugin fails to load.
 func (b *EngineBuilder) Build() (*StdEngine, error) {
 	// Create defaults for app and logger if not set
 	if !b.loggerSet || b.logger == nil {
-		b.logger = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
+		b.logger = slog.New(tracing.NewContextHandler(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})))
 	}
 	if !b.appSet || b.app == nil {
 		b.app = modular.NewStdApplication(nil, b.logger)
 	}
 
 	engine := NewStdEngine(b.app, b.logger)
+	if b.startConcurrency > 0 {
+		engine.SetModuleStartConcurrency(b.startConcurrency)
+	}
+	engine.SetStartupTimeouts(b.startupTimeout, b.moduleTimeout)
 
 	// Set custom plugin loader if provided
 	if b.pluginLoader != nil {
@@ -202,7 +237,10 @@ func (b *EngineBuilder) Build() (*StdEngine, error) {
 
 	// Set up dynamic components
 	if b.useDynamicComponents {
-		pool := dynamic.NewInterpreterPool()
+		if err := dynamic.ValidateImportableModules(b.dynamicModules); err != nil {
+			return nil, err
+		}
+		pool := dynamic.NewInterpreterPool(dynamic.WithImportableModules(b.dynamicModules...))
 		registry := dynamic.NewComponentRegistry()
 		loader := dynamic.NewLoader(pool, registry)
 		engine.SetDynamicRegistry(registry)
@@ -13,6 +13,8 @@ import (
 	"github.com/GoCodeAlone/workflow/interfaces"
 	"github.com/GoCodeAlone/workflow/mock"
 	"github.com/GoCodeAlone/workflow/module"
+	"github.com/GoCodeAlone/workflow/plugin"
+	"github.com/GoCodeAlone/workflow/version"
 )
 
 func init() {
@@ -409,3 +411,42 @@ func hasHandlerForType(engine *StdEngine, workflowType string) bool {
 	}
 	return false
 }
+
+// externalTestPlugin marks a builtin plugin as external for inventory tests.
+type externalTestPlugin struct {
+	plugin.EnginePlugin
+}
+
+func (externalTestPlugin) IsExternal() bool { return true }
+
+func TestStdEngine_VersionReport(t *testing.T) {
+	plugins := allPlugins()
+	if len(plugins) < 2 {
+		t.Skip("need at least two plugins")
+	}
+	engine, err := NewEngineBuilder().
+		WithLogger(&mock.Logger{LogEntries: make([]string, 0)}).
+		WithPlugin(plugins[0]).
+		WithPlugin(externalTestPlugin{plugins[1]}).
+		BuildFromConfig(config.NewEmptyWorkflowConfig())
+	if err != nil {
+		t.Fatalf("BuildFromConfig() error: %v", err)
+	}
+
+	report := engine.VersionReport()
+	if report.Version != version.Get().Version {
+		t.Errorf("Version = %q, want %q", report.Version, version.Get().Version)
+	}
+	if report.ConfigHash == "" || report.ConfigHash != engine.ConfigHash() {
+		t.Errorf("ConfigHash = %q, want engine hash %q", report.ConfigHash, engine.ConfigHash())
+	}
+	if len(report.Plugins) != 2 {
+		t.Fatalf("expected 2 plugins, got %+v", report.Plugins)
+	}
+	if p := report.Plugins[0]; p.Name != plugins[0].Name() || p.Version != plugins[0].Version() || p.Source != version.SourceBuiltin {
+		t.Errorf("unexpected builtin entry: %+v", p)
+	}
+	if p := report.Plugins[1]; p.Name != plugins[1].Name() || p.Source != version.SourceExternal {
+		t.Errorf("unexpected external entry: %+v", p)
+	}
+}
@@ -0,0 +1,56 @@
+package workflow
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/GoCodeAlone/workflow/config"
+)
+
+func TestEngine_BuildFromConfig_SkipsDisabledModules(t *testing.T) {
+	app := newMockApplication()
+	engine := NewStdEngine(app, app.Logger())
+	loadAllPlugins(t, engine)
+
+	disabled := false
+	cfg := &config.WorkflowConfig{
+		Modules: []config.ModuleConfig{
+			{Name: "router", Type: "http.router"},
+			// A disabled module is not built, so its plugin need not be loaded.
+			{Name: "ghost", Type: "nonexistent.type", Enabled: &disabled},
+		},
+		Workflows: map[string]any{},
+		Triggers:  map[string]any{},
+	}
+
+	if err := engine.BuildFromConfig(cfg); err != nil {
+		t.Fatalf("BuildFromConfig: %v", err)
+	}
+	if _, ok := app.SvcRegistry()["ghost"]; ok {
+		t.Error("disabled module was registered")
+	}
+	if len(cfg.Modules) != 2 {
+		t.Errorf("caller's config has %d modules, want the disabled module kept", len(cfg.Modules))
+	}
+}
+
+func TestEngine_BuildFromConfig_RejectsDependencyOnDisabledModule(t *testing.T) {
+	app := newMockApplication()
+	engine := NewStdEngine(app, app.Logger())
+	loadAllPlugins(t, engine)
+
+	disabled := false
+	cfg := &config.WorkflowConfig{
+		Modules: []config.ModuleConfig{
+			{Name: "router", Type: "http.router", Enabled: &disabled},
+			{Name: "handler", Type: "http.handler", DependsOn: []string{"router"}},
+		},
+		Workflows: map[string]any{},
+		Triggers:  map[string]any{},
+	}
+
+	err := engine.BuildFromConfig(cfg)
+	if err == nil || !strings.Contains(err.Error(), `module "handler" depends on disabled module "router"`) {
+		t.Fatalf("expected disabled dependency error, got %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+package workflow
+
+import (
+	"context"
+
+	"github.com/GoCodeAlone/workflow/module"
+	"github.com/GoCodeAlone/workflow/worker"
+)
+
+// SetExecutionQueue sets the queue that routes with `execution: worker`
+// enqueue their executions into. Jobs are recorded under workflowID, the
+// workflow whose timelines the workers' executions appear in.
+func (e *StdEngine) SetExecutionQueue(q worker.Queue, workflowID string) {
+	e.executionQueue = q
+	e.executionWorkflowID = workflowID
+}
+
+// EnqueueExecution implements module.ExecutionEnqueuer.
+func (e *StdEngine) EnqueueExecution(ctx context.Context, pipeline string, data map[string]any, labels []string, idempotencyKey string) (string, bool, error) {
+	if e.executionQueue == nil {
+		return "", false, module.ErrNoExecutionQueue
+	}
+	job, duplicate, err := e.executionQueue.Enqueue(ctx, &worker.Job{
+		IdempotencyKey: idempotencyKey,
+		WorkflowID:     e.executionWorkflowID,
+		Pipeline:       pipeline,
+		Input:          data,
+		Labels:         labels,
+	})
+	if err != nil {
+		return "", false, err
+	}
+	if duplicate {
+		e.logger.Info("Execution already queued", "pipeline", pipeline, "job", job.ID, "idempotency_key", idempotencyKey)
+	} else {
+		e.logger.Info("Queued execution for a worker", "pipeline", pipeline, "job", job.ID, "labels", labels)
+	}
+	return job.ID, duplicate, nil
+}
+
+var _ module.ExecutionEnqueuer = (*StdEngine)(nil)
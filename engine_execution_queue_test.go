@@ -0,0 +1,45 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/GoCodeAlone/workflow/module"
+	"github.com/GoCodeAlone/workflow/worker"
+)
+
+func TestEnqueueExecution(t *testing.T) {
+	ctx := context.Background()
+	app := newMockApplication()
+	engine := NewStdEngine(app, app.Logger())
+
+	if _, _, err := engine.EnqueueExecution(ctx, "build", nil, nil, ""); !errors.Is(err, module.ErrNoExecutionQueue) {
+		t.Fatalf("without a queue: err = %v, want ErrNoExecutionQueue", err)
+	}
+
+	q, err := worker.OpenQueue(filepath.Join(t.TempDir(), "queue.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = q.Close() })
+	engine.SetExecutionQueue(q, "wf-1")
+
+	id, dup, err := engine.EnqueueExecution(ctx, "build", map[string]any{"repo": "app"}, []string{"docker-capable"}, "k1")
+	if err != nil || dup || id == "" {
+		t.Fatalf("enqueue = %q, %v, %v", id, dup, err)
+	}
+	again, dup, err := engine.EnqueueExecution(ctx, "build", nil, nil, "k1")
+	if err != nil || !dup || again != id {
+		t.Fatalf("enqueue with a repeated key = %q, %v, %v; want %q duplicate", again, dup, err, id)
+	}
+
+	job, err := q.Get(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.WorkflowID != "wf-1" || job.Pipeline != "build" || job.Input["repo"] != "app" || len(job.Labels) != 1 {
+		t.Errorf("queued job = %+v", job)
+	}
+}
@@ -39,6 +39,10 @@ type WorkflowStatus struct {
 	Uptime      time.Duration `json:"uptime"`
 	Error       string        `json:"error,omitempty"`
 	ModuleCount int           `json:"module_count"`
+	// Resources is the usage self-reported by each module of the workflow
+	// (see module.ResourceReporter), and ResourceTotals its sum by gauge.
+	Resources      map[string]module.ResourceUsage `json:"resources,omitempty"`
+	ResourceTotals module.ResourceUsage            `json:"resource_totals,omitempty"`
 }
 
 // EngineBuilderFunc is called by the manager to create and configure an engine
@@ -216,19 +220,8 @@ func (m *WorkflowEngineManager) GetStatus(workflowID uuid.UUID) (*WorkflowStatus
 		return nil, fmt.Errorf("workflow %s is not running", workflowID)
 	}
 
-	status := &WorkflowStatus{
-		WorkflowID:  me.WorkflowID,
-		Status:      me.Status,
-		StartedAt:   me.StartedAt,
-		Uptime:      time.Since(me.StartedAt),
-		ModuleCount: len(me.App.SvcRegistry()),
-	}
-
-	if me.Error != nil {
-		status.Error = me.Error.Error()
-	}
-
-	return status, nil
+	status := me.status()
+	return &status, nil
 }
 
 // ListActive returns the status of all running workflows.
@@ -238,22 +231,31 @@ func (m *WorkflowEngineManager) ListActive() []WorkflowStatus {
 
 	statuses := make([]WorkflowStatus, 0, len(m.engines))
 	for _, me := range m.engines {
-		s := WorkflowStatus{
-			WorkflowID:  me.WorkflowID,
-			Status:      me.Status,
-			StartedAt:   me.StartedAt,
-			Uptime:      time.Since(me.StartedAt),
-			ModuleCount: len(me.App.SvcRegistry()),
-		}
-		if me.Error != nil {
-			s.Error = me.Error.Error()
-		}
-		statuses = append(statuses, s)
+		statuses = append(statuses, me.status())
 	}
 
 	return statuses
 }
 
+// status reports the engine's runtime state and resource usage.
+func (me *ManagedEngine) status() WorkflowStatus {
+	s := WorkflowStatus{
+		WorkflowID:  me.WorkflowID,
+		Status:      me.Status,
+		StartedAt:   me.StartedAt,
+		Uptime:      time.Since(me.StartedAt),
+		ModuleCount: len(me.App.SvcRegistry()),
+	}
+	if me.Error != nil {
+		s.Error = me.Error.Error()
+	}
+	if me.Engine != nil && me.Status == "running" {
+		s.Resources = me.Engine.ResourceUsage()
+		s.ResourceTotals = module.TotalResourceUsage(s.Resources)
+	}
+	return s
+}
+
 // StopAll gracefully stops all running engines.
 func (m *WorkflowEngineManager) StopAll(ctx context.Context) error {
 	m.mu.Lock()
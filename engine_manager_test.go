@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"reflect"
 	"sync"
 	"testing"
 
 	"github.com/GoCodeAlone/modular"
 	"github.com/GoCodeAlone/workflow/config"
+	"github.com/GoCodeAlone/workflow/handlers"
+	"github.com/GoCodeAlone/workflow/module"
 	"github.com/GoCodeAlone/workflow/store"
 	"github.com/google/uuid"
 )
@@ -460,3 +463,44 @@ func TestEngineManager_Router_NotNil(t *testing.T) {
 		t.Fatal("expected non-nil router")
 	}
 }
+
+func TestEngineManager_GetStatus_Resources(t *testing.T) {
+	ws := newEMMockWorkflowStore()
+	id := uuid.New()
+	emSeedWorkflow(ws, id, validConfigYAML)
+
+	broker := module.NewInMemoryMessageBroker("broker")
+	_ = broker.Subscribe("orders", module.NewFunctionMessageHandler(func([]byte) error { return nil }))
+	sm := module.NewStateMachineEngine("states")
+	builder := func(_ *config.WorkflowConfig, _ *slog.Logger) (*StdEngine, modular.Application, error) {
+		app := newMockApplication()
+		app.RegisterModule(broker)
+		app.RegisterModule(sm)
+		engine := &StdEngine{app: app, logger: app.logger}
+		engine.RegisterWorkflowHandler(handlers.NewPipelineWorkflowHandler())
+		return engine, app, nil
+	}
+	m := NewWorkflowEngineManager(ws, &emMockLinkStore{}, emTestLogger(), builder)
+	if err := m.DeployWorkflow(context.Background(), id); err != nil {
+		t.Fatalf("deploy failed: %v", err)
+	}
+
+	status, err := m.GetStatus(id)
+	if err != nil {
+		t.Fatalf("expected status, got %v", err)
+	}
+	want := map[string]module.ResourceUsage{
+		"broker":                             {"topics": 1, "subscriptions": 1, module.ResourceInFlight: 0},
+		"states":                             {"instances": 0, "definitions": 0, module.ResourceGoroutines: 0},
+		handlers.PipelineWorkflowHandlerName: {"pipelines": 0, module.ResourceInFlight: 0},
+	}
+	if !reflect.DeepEqual(status.Resources, want) {
+		t.Errorf("resources = %v, want %v", status.Resources, want)
+	}
+	if status.ResourceTotals["subscriptions"] != 1 || status.ResourceTotals[module.ResourceInFlight] != 0 {
+		t.Errorf("resource totals = %v", status.ResourceTotals)
+	}
+	if active := m.ListActive(); len(active) != 1 || !reflect.DeepEqual(active[0].Resources, want) {
+		t.Errorf("ListActive resources = %+v", active)
+	}
+}
@@ -0,0 +1,30 @@
+package workflow
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/GoCodeAlone/workflow/config"
+)
+
+func TestEngine_BuildFromConfig_RejectsMissingModuleConfigRef(t *testing.T) {
+	app := newMockApplication()
+	engine := NewStdEngine(app, app.Logger())
+	loadAllPlugins(t, engine)
+
+	cfg := &config.WorkflowConfig{
+		Modules: []config.ModuleConfig{
+			{Name: "router", Type: "http.router", Config: map[string]any{"prefix": "/api"}},
+			{Name: "handler", Type: "http.handler", Config: map[string]any{
+				"contentType": "${module.router.config.contentType}",
+			}},
+		},
+		Workflows: map[string]any{},
+		Triggers:  map[string]any{},
+	}
+
+	err := engine.BuildFromConfig(cfg)
+	if err == nil || !strings.Contains(err.Error(), `module "router" has no config key "contentType"`) {
+		t.Fatalf("expected missing reference error, got %v", err)
+	}
+}
@@ -0,0 +1,509 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow/module"
+)
+
+// DefaultModuleStartConcurrency bounds how many modules the engine starts at
+// once. Module Start methods mostly wait on I/O (opening pools, binding
+// listeners, connecting to brokers), so the bound is about not stampeding
+// shared backends rather than CPU.
+const DefaultModuleStartConcurrency = 8
+
+// SetModuleStartConcurrency sets how many independent modules Start may start
+// at once. Values below 2 start modules one at a time.
+func (e *StdEngine) SetModuleStartConcurrency(n int) {
+	e.startConcurrency = n
+}
+
+// startedModule stands in for a module in the application registry while
+// app.Start runs. It implements neither Startable nor DependencyAware, so
+// modular only moves the application into its running phase and does not
+// start modules the engine has already started.
+type startedModule struct{ name string }
+
+func (m startedModule) Name() string                   { return m.name }
+func (m startedModule) Init(modular.Application) error { return nil }
+
+// startModules starts every Startable module, running modules whose
+// dependencies have started concurrently (at most e.startConcurrency at a
+// time), then lets the application enter its running phase.
+//
+// modular's own Start walks modules one by one in dependency order and
+// leaves already started modules running when one fails, so the engine
+// builds the same dependency graph (Dependencies(), dependsOn, and service
+// requirements) and schedules it itself. If a required module fails, no new
+// modules are started, in-flight starts are awaited, and every module started
+// so far is stopped in reverse start order before a *StartRollbackError is
+// returned. When the startup timeout passes, the modules still starting are
+// cancelled and given DefaultStartTimeoutGrace to return; those that started
+// after all are stopped with the rest. A module with startPolicy optional that fails is recorded as
+// degraded instead (see DegradedModules) and its dependents still start.
+//
+// A graph with a cycle modular tolerates (e.g. through service edges it
+// prunes) is started one module at a time with the cycle's back edges
+// dropped.
+func (e *StdEngine) startModules(ctx context.Context) error {
+	e.rolledBack = false
+	e.startedModules = nil
+	e.degradedMu.Lock()
+	e.degraded = nil
+	e.degradedMu.Unlock()
+
+	// Clone: the registry entries are swapped below, and not every
+	// Application returns a copy.
+	modules := maps.Clone(e.app.GetAllModules())
+	deps := e.moduleStartGraph(modules)
+	limit := max(e.startConcurrency, 1)
+	if cycle := findStartCycle(deps); cycle != "" {
+		e.logger.Warn("Module dependency graph has a cycle; starting modules sequentially", "cycle", cycle)
+		deps = breakStartCycles(deps)
+		limit = 1
+	}
+
+	// Modules run on a lifecycle context that outlives Start, as with
+	// modular; it is cancelled when the engine stops or the start fails.
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	began := time.Now()
+	started, sequential, err := e.runStartGraph(runCtx, cancel, modules, deps, limit)
+	if err != nil {
+		err = e.rollbackStart(modules, started, err)
+		cancel()
+		return err
+	}
+	elapsed := time.Since(began)
+
+	for name := range modules {
+		e.app.RegisterModule(startedModule{name: name})
+	}
+	err = e.app.Start()
+	for _, mod := range modules {
+		e.app.RegisterModule(mod)
+	}
+	if err != nil {
+		err = e.rollbackStart(modules, started, err)
+		cancel()
+		return err
+	}
+	e.stopModuleCtx = cancel
+	e.startedModules = started
+
+	speedup := 1.0
+	if elapsed > 0 {
+		speedup = float64(sequential) / float64(elapsed)
+	}
+	e.logger.Info("Started modules",
+		"modules", len(started),
+		"concurrency", limit,
+		"elapsed", elapsed.Round(time.Millisecond),
+		"sequential_estimate", sequential.Round(time.Millisecond),
+		"speedup", fmt.Sprintf("%.1fx", speedup))
+	return nil
+}
+
+// runStartGraph starts modules in dependency order, at most limit at a time.
+// It returns the names of modules that started, in completion order, and the
+// sum of their start durations (what a sequential start would have taken).
+// If the startup timeout passes it reports the modules still starting,
+// cancels ctx through cancel, and waits out the grace period for their starts
+// to return, counting those that succeed as started so they are rolled back.
+func (e *StdEngine) runStartGraph(ctx context.Context, cancel context.CancelFunc, modules map[string]modular.Module, deps map[string][]string, limit int) ([]string, time.Duration, error) {
+	pending := make(map[string]int, len(deps))
+	dependents := make(map[string][]string, len(deps))
+	var ready []string
+	for name, ds := range deps {
+		pending[name] = len(ds)
+		for _, d := range ds {
+			dependents[d] = append(dependents[d], name)
+		}
+		if len(ds) == 0 {
+			ready = append(ready, name)
+		}
+	}
+	slices.Sort(ready)
+	release := func(name string) {
+		for _, d := range dependents[name] {
+			if pending[d]--; pending[d] == 0 {
+				ready = append(ready, d)
+			}
+		}
+	}
+
+	type result struct {
+		name string
+		took time.Duration
+		err  error
+	}
+	// Buffered so starts abandoned after the deadline can still finish.
+	results := make(chan result, len(modules))
+	var deadline <-chan time.Time
+	if e.startupTimeout > 0 {
+		timer := time.NewTimer(e.startupTimeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	var (
+		started    []string
+		sequential time.Duration
+		errs       []error
+		inFlight   = make(map[string]bool)
+	)
+	for len(ready) > 0 || len(inFlight) > 0 {
+		for len(errs) == 0 && len(ready) > 0 && len(inFlight) < limit {
+			name := ready[0]
+			ready = ready[1:]
+			startable, ok := modules[name].(modular.Startable)
+			if !ok {
+				// Nothing to start; release dependents right away.
+				release(name)
+				continue
+			}
+			inFlight[name] = true
+			go func() {
+				e.logger.Debug("Starting module", "module", name)
+				t := time.Now()
+				err := e.startModule(ctx, name, startable)
+				results <- result{name: name, took: time.Since(t), err: err}
+			}()
+		}
+		if len(inFlight) == 0 {
+			break
+		}
+		var res result
+		select {
+		case res = <-results:
+		case <-deadline:
+			stuck := slices.Sorted(maps.Keys(inFlight))
+			errs = append(errs, &StartupTimeoutError{Phase: "Start", Modules: stuck, Timeout: e.startupTimeout})
+			cancel()
+			grace := time.NewTimer(e.startGrace)
+			defer grace.Stop()
+			for len(inFlight) > 0 {
+				select {
+				case res = <-results:
+				case <-grace.C:
+					e.logger.Warn("Modules did not return from a cancelled Start; they are not stopped",
+						"modules", slices.Sorted(maps.Keys(inFlight)), "grace", e.startGrace)
+					return started, sequential, errors.Join(errs...)
+				}
+				delete(inFlight, res.name)
+				if res.err == nil {
+					started = append(started, res.name)
+					sequential += res.took
+				}
+			}
+			return started, sequential, errors.Join(errs...)
+		}
+		delete(inFlight, res.name)
+		if res.err != nil && e.moduleOptional[res.name] {
+			e.logger.Warn("Optional module failed to start; continuing degraded", "module", res.name, "error", res.err)
+			e.degradedMu.Lock()
+			if e.degraded == nil {
+				e.degraded = make(map[string]error)
+			}
+			e.degraded[res.name] = res.err
+			e.degradedMu.Unlock()
+			release(res.name)
+			continue
+		}
+		var timeout *StartupTimeoutError
+		if errors.As(res.err, &timeout) {
+			errs = append(errs, res.err)
+			continue
+		}
+		if res.err != nil {
+			errs = append(errs, &ModuleError{Module: res.name, Err: fmt.Errorf("failed to start module %s: %w", res.name, res.err)})
+			continue
+		}
+		started = append(started, res.name)
+		sequential += res.took
+		release(res.name)
+	}
+	return started, sequential, errors.Join(errs...)
+}
+
+// StartRollbackError is returned by Start when a required module or a
+// trigger failed to start. Before returning, Start stopped everything that
+// had started; RolledBack and RollbackErr report how that went. It unwraps
+// to the start failure, so errors.As still finds a *StartupTimeoutError.
+type StartRollbackError struct {
+	// Err is the start failure, naming the module or trigger that failed.
+	Err error
+	// RolledBack lists the modules stopped again, in stop order.
+	RolledBack []string
+	// RollbackErr joins the errors of modules or triggers that failed to
+	// stop; nil when the rollback was clean.
+	RollbackErr error
+}
+
+func (e *StartRollbackError) Error() string {
+	switch {
+	case e.RollbackErr != nil:
+		return fmt.Sprintf("%v; rollback incomplete: %v", e.Err, e.RollbackErr)
+	case len(e.RolledBack) == 0:
+		return fmt.Sprintf("%v; no started modules to roll back", e.Err)
+	default:
+		return fmt.Sprintf("%v; rolled back %s", e.Err, strings.Join(e.RolledBack, ", "))
+	}
+}
+
+func (e *StartRollbackError) Unwrap() error { return e.Err }
+
+// rollbackStart stops the started modules in reverse start order and wraps
+// cause with the outcome.
+func (e *StdEngine) rollbackStart(modules map[string]modular.Module, started []string, cause error) error {
+	e.rolledBack = true
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	rb := &StartRollbackError{Err: cause}
+	var errs []error
+	for _, name := range slices.Backward(started) {
+		stoppable, ok := modules[name].(modular.Stoppable)
+		if !ok {
+			continue
+		}
+		e.logger.Info("Stopping module after failed start", "module", name)
+		if err := stoppable.Stop(ctx); err != nil {
+			e.logger.Error("Error stopping module after failed start", "module", name, "error", err)
+			errs = append(errs, fmt.Errorf("module %s: %w", name, err))
+			continue
+		}
+		rb.RolledBack = append(rb.RolledBack, name)
+	}
+	rb.RollbackErr = errors.Join(errs...)
+	return rb
+}
+
+// rollbackTriggerStart undoes Start after e.triggers[failed] failed to
+// start: it stops the triggers started before it in reverse order, then the
+// modules.
+func (e *StdEngine) rollbackTriggerStart(ctx context.Context, failed int, cause error) error {
+	e.rolledBack = true
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+	defer cancel()
+	var errs []error
+	for _, trigger := range slices.Backward(e.triggers[:failed]) {
+		if err := trigger.Stop(ctx); err != nil {
+			e.logger.Error("Error stopping trigger after failed start", "trigger", trigger.Name(), "error", err)
+			errs = append(errs, fmt.Errorf("trigger '%s': %w", trigger.Name(), err))
+		}
+	}
+	if err := e.stopApp(); err != nil {
+		errs = append(errs, err)
+	}
+	e.stopModuleContext()
+	rolledBack := slices.Clone(e.startedModules)
+	slices.Reverse(rolledBack)
+	return &StartRollbackError{Err: cause, RolledBack: rolledBack, RollbackErr: errors.Join(errs...)}
+}
+
+// stopApp runs app.Stop with degraded modules swapped for stand-ins, so
+// modules that never started are not stopped.
+func (e *StdEngine) stopApp() error {
+	degraded := e.DegradedModules()
+	if len(degraded) == 0 {
+		return e.app.Stop()
+	}
+	modules := maps.Clone(e.app.GetAllModules())
+	for name := range degraded {
+		if _, ok := modules[name]; ok {
+			e.app.RegisterModule(startedModule{name: name})
+		}
+	}
+	err := e.app.Stop()
+	for name := range degraded {
+		if mod, ok := modules[name]; ok {
+			e.app.RegisterModule(mod)
+		}
+	}
+	return err
+}
+
+// DegradedModules returns the modules with startPolicy optional that failed
+// during the last Start, with their start errors. It is empty when every
+// module started.
+func (e *StdEngine) DegradedModules() map[string]error {
+	e.degradedMu.RLock()
+	defer e.degradedMu.RUnlock()
+	return maps.Clone(e.degraded)
+}
+
+// ModuleStartHealthService is the service through which the health checker
+// reports optional modules that failed to start. The engine registers it
+// when a config declares a module with startPolicy optional.
+const ModuleStartHealthService = "workflow.module-start"
+
+// moduleStartHealth reports the engine's degraded modules as a health check.
+type moduleStartHealth struct{ e *StdEngine }
+
+func (h moduleStartHealth) HealthStatus() module.HealthCheckResult {
+	degraded := h.e.DegradedModules()
+	if len(degraded) == 0 {
+		return module.HealthCheckResult{Status: "healthy"}
+	}
+	failures := make([]string, 0, len(degraded))
+	for _, name := range slices.Sorted(maps.Keys(degraded)) {
+		failures = append(failures, fmt.Sprintf("%s: %v", name, degraded[name]))
+	}
+	return module.HealthCheckResult{
+		Status:  "degraded",
+		Message: "optional modules failed to start: " + strings.Join(failures, "; "),
+	}
+}
+
+// moduleStartGraph returns, for every module, the modules that must have
+// started before it: its Dependencies(), its config dependsOn, and the
+// providers of the services it requires. Edges to unknown modules are
+// dropped, as modular's Init would already have rejected a missing required
+// dependency.
+func (e *StdEngine) moduleStartGraph(modules map[string]modular.Module) map[string][]string {
+	providers := make(map[string]string)
+	type provided struct {
+		module string
+		typ    reflect.Type
+	}
+	var instances []provided
+	for name, mod := range modules {
+		sa, ok := mod.(modular.ServiceAware)
+		if !ok {
+			continue
+		}
+		for _, p := range sa.ProvidesServices() {
+			providers[p.Name] = name
+			if p.Instance != nil {
+				instances = append(instances, provided{module: name, typ: reflect.TypeOf(p.Instance)})
+			}
+		}
+	}
+
+	graph := make(map[string][]string, len(modules))
+	for name, mod := range modules {
+		seen := map[string]bool{name: true}
+		var ds []string
+		add := func(dep string) {
+			if _, ok := modules[dep]; ok && !seen[dep] {
+				seen[dep] = true
+				ds = append(ds, dep)
+			}
+		}
+		if da, ok := mod.(modular.DependencyAware); ok {
+			for _, d := range da.Dependencies() {
+				add(d)
+			}
+		}
+		for _, d := range e.moduleDependsOn[name] {
+			add(d)
+		}
+		if sa, ok := mod.(modular.ServiceAware); ok {
+			for _, req := range sa.RequiresServices() {
+				if req.MatchByInterface && req.SatisfiesInterface != nil {
+					for _, p := range instances {
+						if p.typ.Implements(req.SatisfiesInterface) {
+							add(p.module)
+						}
+					}
+					continue
+				}
+				add(providers[req.Name])
+			}
+		}
+		slices.Sort(ds)
+		graph[name] = ds
+	}
+	return graph
+}
+
+// findStartCycle returns a description of one dependency cycle in graph, or
+// "" when the graph is acyclic.
+func findStartCycle(graph map[string][]string) string {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int, len(graph))
+	var path []string
+	var visit func(string) string
+	visit = func(n string) string {
+		switch state[n] {
+		case visiting:
+			i := slices.Index(path, n)
+			return fmt.Sprint(append(slices.Clone(path[i:]), n))
+		case done:
+			return ""
+		}
+		state[n] = visiting
+		path = append(path, n)
+		for _, d := range graph[n] {
+			if c := visit(d); c != "" {
+				return c
+			}
+		}
+		path = path[:len(path)-1]
+		state[n] = done
+		return ""
+	}
+	names := make([]string, 0, len(graph))
+	for n := range graph {
+		names = append(names, n)
+	}
+	slices.Sort(names)
+	for _, n := range names {
+		if c := visit(n); c != "" {
+			return c
+		}
+	}
+	return ""
+}
+
+// breakStartCycles returns a copy of graph without the edges that close a
+// cycle, found by a depth-first walk in name order, so it can be scheduled.
+func breakStartCycles(graph map[string][]string) map[string][]string {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int, len(graph))
+	out := make(map[string][]string, len(graph))
+	var visit func(string)
+	visit = func(n string) {
+		state[n] = visiting
+		ds := []string{}
+		for _, d := range graph[n] {
+			if state[d] == visiting {
+				continue
+			}
+			if state[d] == 0 {
+				visit(d)
+			}
+			ds = append(ds, d)
+		}
+		out[n] = ds
+		state[n] = done
+	}
+	for _, n := range slices.Sorted(maps.Keys(graph)) {
+		if state[n] == 0 {
+			visit(n)
+		}
+	}
+	return out
+}
+
+// stopModuleContext cancels the lifecycle context handed to modules by
+// startModules. It is safe to call when the engine did not start modules
+// itself.
+func (e *StdEngine) stopModuleContext() {
+	if e.stopModuleCtx != nil {
+		e.stopModuleCtx()
+		e.stopModuleCtx = nil
+	}
+}
@@ -0,0 +1,293 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GoCodeAlone/modular"
+)
+
+// startRecorder tracks module start/stop calls across a test.
+type startRecorder struct {
+	mu       sync.Mutex
+	started  map[string]time.Time
+	finished map[string]time.Time
+	stopped  []string
+	inFlight atomic.Int32
+	peak     atomic.Int32
+}
+
+func newStartRecorder() *startRecorder {
+	return &startRecorder{started: map[string]time.Time{}, finished: map[string]time.Time{}}
+}
+
+type timedModule struct {
+	name  string
+	deps  []string
+	delay time.Duration
+	err   error
+	rec   *startRecorder
+	calls atomic.Int32
+}
+
+func (m *timedModule) Name() string                   { return m.name }
+func (m *timedModule) Init(modular.Application) error { return nil }
+func (m *timedModule) Dependencies() []string         { return m.deps }
+
+func (m *timedModule) Start(context.Context) error {
+	m.calls.Add(1)
+	n := m.rec.inFlight.Add(1)
+	for {
+		p := m.rec.peak.Load()
+		if n <= p || m.rec.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+	m.rec.mu.Lock()
+	m.rec.started[m.name] = time.Now()
+	m.rec.mu.Unlock()
+	time.Sleep(m.delay)
+	m.rec.inFlight.Add(-1)
+	m.rec.mu.Lock()
+	m.rec.finished[m.name] = time.Now()
+	m.rec.mu.Unlock()
+	return m.err
+}
+
+func (m *timedModule) Stop(context.Context) error {
+	m.rec.mu.Lock()
+	m.rec.stopped = append(m.rec.stopped, m.name)
+	m.rec.mu.Unlock()
+	return nil
+}
+
+func newStartTestEngine(t *testing.T, mods ...*timedModule) *StdEngine {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	app := modular.NewStdApplication(nil, logger)
+	for _, m := range mods {
+		app.RegisterModule(m)
+	}
+	if err := app.Init(); err != nil {
+		t.Fatal(err)
+	}
+	return NewStdEngine(app, logger)
+}
+
+func TestEngineStart_IndependentModulesStartConcurrently(t *testing.T) {
+	rec := newStartRecorder()
+	var mods []*timedModule
+	for _, name := range []string{"a", "b", "c", "d"} {
+		mods = append(mods, &timedModule{name: name, delay: 100 * time.Millisecond, rec: rec})
+	}
+	e := newStartTestEngine(t, mods...)
+
+	began := time.Now()
+	if err := e.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer e.Stop(context.Background()) //nolint:errcheck
+	if took := time.Since(began); took > 300*time.Millisecond {
+		t.Errorf("starting 4 independent 100ms modules took %v, want well under the 400ms sequential time", took)
+	}
+	for _, m := range mods {
+		if n := m.calls.Load(); n != 1 {
+			t.Errorf("module %s started %d times, want 1", m.name, n)
+		}
+	}
+	if phase := e.GetApp().(*modular.StdApplication).Phase(); phase != modular.PhaseRunning {
+		t.Errorf("application phase = %v, want running", phase)
+	}
+	if _, ok := e.GetApp().GetModule("a").(*timedModule); !ok {
+		t.Error("registry still holds a start stand-in after Start")
+	}
+}
+
+func TestEngineStart_RespectsDependencyOrder(t *testing.T) {
+	rec := newStartRecorder()
+	e := newStartTestEngine(t,
+		&timedModule{name: "db", delay: 50 * time.Millisecond, rec: rec},
+		&timedModule{name: "cache", delay: 50 * time.Millisecond, rec: rec},
+		&timedModule{name: "api", deps: []string{"db", "cache"}, rec: rec},
+		&timedModule{name: "worker", deps: []string{"api"}, rec: rec},
+	)
+	if err := e.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer e.Stop(context.Background()) //nolint:errcheck
+
+	for _, edge := range [][2]string{{"api", "db"}, {"api", "cache"}, {"worker", "api"}} {
+		if rec.started[edge[0]].Before(rec.finished[edge[1]]) {
+			t.Errorf("%s started before its dependency %s finished", edge[0], edge[1])
+		}
+	}
+}
+
+func TestEngineStart_BoundsParallelism(t *testing.T) {
+	rec := newStartRecorder()
+	var mods []*timedModule
+	for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		mods = append(mods, &timedModule{name: name, delay: 20 * time.Millisecond, rec: rec})
+	}
+	e := newStartTestEngine(t, mods...)
+	e.SetModuleStartConcurrency(2)
+	if err := e.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer e.Stop(context.Background()) //nolint:errcheck
+	if peak := rec.peak.Load(); peak != 2 {
+		t.Errorf("peak concurrent starts = %d, want 2", peak)
+	}
+}
+
+func TestEngineStart_FailureRollsBackStartedModules(t *testing.T) {
+	rec := newStartRecorder()
+	e := newStartTestEngine(t,
+		&timedModule{name: "db", rec: rec},
+		&timedModule{name: "broker", deps: []string{"db"}, delay: 20 * time.Millisecond, err: errors.New("connection refused"), rec: rec},
+		&timedModule{name: "consumer", deps: []string{"broker"}, rec: rec},
+		&timedModule{name: "cache", delay: 50 * time.Millisecond, rec: rec},
+	)
+	err := e.Start(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "failed to start module broker: connection refused") {
+		t.Fatalf("Start error = %v, want broker failure", err)
+	}
+	if _, ok := rec.started["consumer"]; ok {
+		t.Error("consumer started although its dependency failed")
+	}
+	// db and the in-flight cache started; both are stopped, most recent first.
+	if got := strings.Join(rec.stopped, ","); got != "cache,db" {
+		t.Errorf("stopped = %q, want cache,db", got)
+	}
+	if phase := e.GetApp().(*modular.StdApplication).Phase(); phase == modular.PhaseRunning {
+		t.Error("application entered the running phase after a failed start")
+	}
+}
+
+func TestEngineStart_SequentialWhenConcurrencyIsOne(t *testing.T) {
+	rec := newStartRecorder()
+	e := newStartTestEngine(t,
+		&timedModule{name: "a", delay: 10 * time.Millisecond, rec: rec},
+		&timedModule{name: "b", delay: 10 * time.Millisecond, rec: rec},
+	)
+	e.SetModuleStartConcurrency(1)
+	if err := e.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer e.Stop(context.Background()) //nolint:errcheck
+	if peak := rec.peak.Load(); peak != 1 {
+		t.Errorf("peak concurrent starts = %d, want 1", peak)
+	}
+}
+
+func TestFindStartCycle(t *testing.T) {
+	if c := findStartCycle(map[string][]string{"a": {"b"}, "b": {"c"}, "c": nil}); c != "" {
+		t.Errorf("acyclic graph reported cycle %s", c)
+	}
+	if c := findStartCycle(map[string][]string{"a": {"b"}, "b": {"a"}}); c != "[a b a]" {
+		t.Errorf("cycle = %q, want [a b a]", c)
+	}
+}
+
+func TestEngineStart_MiddleFailureRollsBackInReverseOrder(t *testing.T) {
+	rec := newStartRecorder()
+	e := newStartTestEngine(t,
+		&timedModule{name: "db", rec: rec},
+		&timedModule{name: "cache", deps: []string{"db"}, rec: rec},
+		&timedModule{name: "broker", deps: []string{"cache"}, err: errors.New("connection refused"), rec: rec},
+		&timedModule{name: "api", deps: []string{"broker"}, rec: rec},
+	)
+	e.SetModuleStartConcurrency(1)
+	err := e.Start(context.Background())
+
+	var rb *StartRollbackError
+	if !errors.As(err, &rb) {
+		t.Fatalf("Start error = %v, want *StartRollbackError", err)
+	}
+	if !strings.Contains(err.Error(), "failed to start module broker: connection refused; rolled back cache, db") {
+		t.Errorf("Start error = %q, want the failing module and the rollback outcome", err)
+	}
+	if rb.RollbackErr != nil {
+		t.Errorf("RollbackErr = %v, want a clean rollback", rb.RollbackErr)
+	}
+	if got := strings.Join(rec.stopped, ","); got != "cache,db" {
+		t.Errorf("stopped = %q, want cache,db", got)
+	}
+	if _, ok := rec.started["api"]; ok {
+		t.Error("api started although its dependency failed")
+	}
+	// Stop after a failed Start does not stop the modules a second time.
+	_ = e.Stop(context.Background())
+	if len(rec.stopped) != 2 {
+		t.Errorf("stopped = %v after Stop, want no second stop", rec.stopped)
+	}
+}
+
+func TestEngineStart_OptionalModuleFailureDegrades(t *testing.T) {
+	rec := newStartRecorder()
+	e := newStartTestEngine(t,
+		&timedModule{name: "db", rec: rec},
+		&timedModule{name: "metrics", err: errors.New("exporter unreachable"), rec: rec},
+		&timedModule{name: "api", deps: []string{"db", "metrics"}, rec: rec},
+	)
+	e.moduleOptional = map[string]bool{"metrics": true}
+	if err := e.Start(context.Background()); err != nil {
+		t.Fatalf("Start error = %v, want the optional failure tolerated", err)
+	}
+	if _, ok := rec.started["api"]; !ok {
+		t.Error("api did not start after its optional dependency failed")
+	}
+	degraded := e.DegradedModules()
+	if len(degraded) != 1 || degraded["metrics"] == nil {
+		t.Fatalf("DegradedModules = %v, want metrics", degraded)
+	}
+	health := moduleStartHealth{e}.HealthStatus()
+	if health.Status != "degraded" || !strings.Contains(health.Message, "metrics: exporter unreachable") {
+		t.Errorf("health = %+v, want degraded naming metrics", health)
+	}
+
+	if err := e.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range rec.stopped {
+		if name == "metrics" {
+			t.Error("Stop stopped metrics, which never started")
+		}
+	}
+}
+
+func TestEngineStart_TriggerFailureStopsModules(t *testing.T) {
+	rec := newStartRecorder()
+	e := newStartTestEngine(t, &timedModule{name: "db", rec: rec})
+	first := &mockTrigger{name: "http"}
+	e.triggers = append(e.triggers, first, &errorMockTrigger{mockTrigger: mockTrigger{name: "cron"}, startErr: errors.New("bad schedule")})
+
+	err := e.Start(context.Background())
+	var rb *StartRollbackError
+	if !errors.As(err, &rb) || !strings.Contains(err.Error(), "failed to start trigger 'cron': bad schedule") {
+		t.Fatalf("Start error = %v, want the trigger failure", err)
+	}
+	if !first.stopCalled {
+		t.Error("trigger started before the failure was not stopped")
+	}
+	if got := strings.Join(rec.stopped, ","); got != "db" {
+		t.Errorf("stopped = %q, want db", got)
+	}
+}
+
+func TestBreakStartCycles(t *testing.T) {
+	graph := breakStartCycles(map[string][]string{"a": {"b"}, "b": {"c"}, "c": {"a"}, "d": {"a"}})
+	if c := findStartCycle(graph); c != "" {
+		t.Errorf("cycle %s left in %v", c, graph)
+	}
+	if len(graph["a"]) != 1 || len(graph["b"]) != 1 || len(graph["d"]) != 1 {
+		t.Errorf("graph = %v, want only the closing edge c->a dropped", graph)
+	}
+}
@@ -2,10 +2,15 @@ package workflow
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
 	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow/config"
 	"github.com/GoCodeAlone/workflow/handlers"
 	"github.com/GoCodeAlone/workflow/module"
 	pluginpipeline "github.com/GoCodeAlone/workflow/plugins/pipelinesteps"
@@ -889,3 +894,259 @@ func stepKeys(m map[string]map[string]any) []string {
 	}
 	return keys
 }
+
+func TestPipeline_ConfigurePipelines_EngineStrictTemplates(t *testing.T) {
+	pipelineCfg := map[string]any{
+		"greet": map[string]any{
+			"steps": []any{
+				map[string]any{
+					"name": "reply",
+					"type": "step.set",
+					"config": map[string]any{
+						"values": map[string]any{"message": "hello {{ .nmae }}"},
+					},
+				},
+			},
+		},
+	}
+	for _, strict := range []bool{false, true} {
+		engine, pipelineHandler := setupPipelineEngine(t)
+		engine.strictTemplates = strict
+		if err := engine.configurePipelines(pipelineCfg); err != nil {
+			t.Fatalf("configurePipelines failed: %v", err)
+		}
+
+		_, err := pipelineHandler.ExecuteWorkflow(context.Background(), "greet", "", map[string]any{"name": "Ada"})
+		if !strict {
+			if err != nil {
+				t.Fatalf("lenient mode failed: %v", err)
+			}
+			continue
+		}
+		var undef *module.UndefinedPathError
+		if !errors.As(err, &undef) || undef.Path != ".nmae" {
+			t.Fatalf("strict mode err = %v, want undefined path .nmae", err)
+		}
+		if !strings.Contains(err.Error(), "reply") {
+			t.Errorf("err = %v, want it to name the failing step", err)
+		}
+	}
+}
+
+func TestPipeline_ConfigurePipelines_Durable(t *testing.T) {
+	engine, _ := setupPipelineEngine(t)
+	pipelineCfg := map[string]any{
+		"orders": map[string]any{
+			"durable": map[string]any{"recovery": "resume", "max_snapshot_bytes": 4096},
+			"steps": []any{
+				map[string]any{"name": "load", "type": "step.set", "resumable": true, "config": map[string]any{"values": map[string]any{"a": "1"}}},
+				map[string]any{"name": "charge", "type": "step.set", "config": map[string]any{"values": map[string]any{"b": "2"}}},
+			},
+		},
+		"plain": map[string]any{
+			"steps": []any{map[string]any{"name": "load", "type": "step.set", "config": map[string]any{"values": map[string]any{"a": "1"}}}},
+		},
+	}
+	if err := engine.configurePipelines(pipelineCfg); err != nil {
+		t.Fatalf("configurePipelines failed: %v", err)
+	}
+
+	p, ok := engine.DurablePipeline("orders")
+	if !ok || p.Durable.Recovery != module.DurableRecoveryResume || p.Durable.MaxSnapshotBytes != 4096 ||
+		!p.Durable.Resumable["load"] || p.Durable.Resumable["charge"] {
+		t.Fatalf("durable options = %+v", p.Durable)
+	}
+	if _, ok := engine.DurablePipeline("plain"); ok {
+		t.Fatal("pipeline without a durable block is durable")
+	}
+	if plain, _ := engine.GetPipeline("plain"); plain.Durable != nil {
+		t.Fatalf("plain pipeline durable options = %+v", plain.Durable)
+	}
+
+	engine, _ = setupPipelineEngine(t)
+	err := engine.configurePipelines(map[string]any{
+		"bad": map[string]any{
+			"durable": map[string]any{"recovery": "retry"},
+			"steps":   []any{map[string]any{"name": "load", "type": "step.set", "config": map[string]any{"values": map[string]any{"a": "1"}}}},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), `invalid durable recovery "retry"`) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestPipeline_ConfigurePipelines_ProblemDetail(t *testing.T) {
+	// The first step fails on a strict template and is skipped; the second
+	// answers with a problem naming it.
+	pipelineCfg := map[string]any{
+		"greet": map[string]any{
+			"on_error": "skip",
+			"steps": []any{
+				map[string]any{
+					"name":   "reply",
+					"type":   "step.set",
+					"config": map[string]any{"values": map[string]any{"message": "hello {{ .nmae }}"}},
+				},
+				map[string]any{
+					"name": "respond",
+					"type": "step.json_response",
+					"config": map[string]any{
+						"problem": map[string]any{"code": "pipeline_error", "detail": "{{ .steps.reply._error }}"},
+					},
+				},
+			},
+		},
+	}
+	for _, level := range []module.ProblemDetailLevel{module.ProblemDetailFull, module.ProblemDetailMinimal} {
+		engine, pipelineHandler := setupPipelineEngine(t)
+		engine.strictTemplates = true
+		engine.problemDetail = level
+		if err := engine.configurePipelines(pipelineCfg); err != nil {
+			t.Fatalf("configurePipelines failed: %v", err)
+		}
+
+		w := httptest.NewRecorder()
+		ctx := context.WithValue(context.Background(), module.HTTPResponseWriterContextKey, w)
+		if _, err := pipelineHandler.ExecuteWorkflow(ctx, "greet", "", map[string]any{}); err != nil {
+			t.Fatalf("%s: %v", level, err)
+		}
+		var p module.Problem
+		if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+			t.Fatalf("%s: body %q: %v", level, w.Body.String(), err)
+		}
+		if w.Code != 500 || p.Code != "pipeline_error" {
+			t.Errorf("%s: got %d %+v", level, w.Code, p)
+		}
+		full := level == module.ProblemDetailFull
+		if (p.Step == "reply") != full || strings.Contains(p.Detail, ".nmae") != full {
+			t.Errorf("%s: step = %q, detail = %q", level, p.Step, p.Detail)
+		}
+	}
+}
+
+func TestPipeline_ConfigureRoutePipelines_Schedule(t *testing.T) {
+	routeCfg := func(schedule map[string]any) *config.WorkflowConfig {
+		return &config.WorkflowConfig{Workflows: map[string]any{
+			"http": map[string]any{
+				"routes": []any{map[string]any{
+					"method":  "GET",
+					"path":    "/api/reports",
+					"handler": "reports",
+					"pipeline": map[string]any{
+						"schedule": schedule,
+						"steps": []any{map[string]any{
+							"name": "build", "type": "step.set",
+							"config": map[string]any{"values": map[string]any{"built": "yes"}},
+						}},
+					},
+				}},
+			},
+		}}
+	}
+	setup := func(t *testing.T) (*StdEngine, *handlers.PipelineWorkflowHandler, *[]any) {
+		t.Helper()
+		app := newMockApplication()
+		engine := NewStdEngine(app, app.Logger())
+		engine.AddStepType("step.set", module.NewSetStepFactory())
+		pipelineHandler := handlers.NewPipelineWorkflowHandler()
+		engine.RegisterWorkflowHandler(pipelineHandler)
+		if err := app.RegisterService("reports", module.NewQueryHandler("reports")); err != nil {
+			t.Fatal(err)
+		}
+		var configured []any
+		engine.RegisterTrigger(&configCapturingTrigger{
+			mockTrigger: mockTrigger{name: module.ScheduleTriggerName, configType: "schedule"},
+			captureFunc: func(cfg any) { configured = append(configured, cfg) },
+		})
+		return engine, pipelineHandler, &configured
+	}
+
+	engine, pipelineHandler, configured := setup(t)
+	err := engine.configureRoutePipelines(routeCfg(map[string]any{"cron": "*/5 * * * *", "timezone": "Europe/Berlin", "enabled": true}))
+	if err != nil {
+		t.Fatalf("configureRoutePipelines failed: %v", err)
+	}
+	if len(*configured) != 1 {
+		t.Fatalf("schedule trigger configured %d times, want 1", len(*configured))
+	}
+	cfg, _ := (*configured)[0].(map[string]any)
+	if cfg["cron"] != "*/5 * * * *" || cfg["timezone"] != "Europe/Berlin" || cfg["workflowType"] != "pipeline:reports:reports" {
+		t.Errorf("trigger config = %v", cfg)
+	}
+	result, err := pipelineHandler.ExecuteWorkflow(context.Background(), "pipeline:reports:reports", "execute", map[string]any{})
+	if err != nil {
+		t.Fatalf("scheduled run: %v", err)
+	}
+	if result["built"] != "yes" {
+		t.Errorf("scheduled run result = %v", result)
+	}
+
+	// Disabled by default: validated, but not registered.
+	engine, pipelineHandler, configured = setup(t)
+	if err := engine.configureRoutePipelines(routeCfg(map[string]any{"cron": "@hourly"})); err != nil {
+		t.Fatalf("configureRoutePipelines failed: %v", err)
+	}
+	if len(*configured) != 0 || pipelineHandler.CanHandle("pipeline:reports:reports") {
+		t.Error("disabled schedule was registered")
+	}
+
+	for _, bad := range []map[string]any{
+		{"cron": "61 * * * *"},
+		{"cron": "@hourly", "timezone": "Mars/Olympus"},
+		{"enabled": true},
+	} {
+		engine, _, _ = setup(t)
+		err := engine.configureRoutePipelines(routeCfg(bad))
+		if err == nil || !strings.Contains(err.Error(), "invalid schedule config") {
+			t.Errorf("schedule %v: err = %v", bad, err)
+		}
+	}
+}
+
+func TestPipeline_ConfigureRoutePipelines_ETag(t *testing.T) {
+	routeCfg := func(etag any) *config.WorkflowConfig {
+		return &config.WorkflowConfig{Workflows: map[string]any{
+			"http": map[string]any{
+				"routes": []any{map[string]any{
+					"method":  "GET",
+					"path":    "/api/reports",
+					"handler": "reports",
+					"etag":    etag,
+				}},
+			},
+		}}
+	}
+	app := newMockApplication()
+	engine := NewStdEngine(app, app.Logger())
+	reports := module.NewQueryHandler("reports")
+	reports.RegisterQuery("reports", func(context.Context, *http.Request) (any, error) {
+		return map[string]any{"total": 3}, nil
+	})
+	if err := app.RegisterService("reports", reports); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.configureRoutePipelines(routeCfg(map[string]any{"cacheControl": "max-age=10"})); err != nil {
+		t.Fatalf("configureRoutePipelines failed: %v", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /api/reports", reports)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/reports", nil))
+	if w.Header().Get("ETag") == "" || w.Header().Get("Cache-Control") != "max-age=10" {
+		t.Errorf("headers = %v", w.Header())
+	}
+
+	err := engine.configureRoutePipelines(routeCfg("always"))
+	if err == nil || !strings.Contains(err.Error(), `route "GET /api/reports": etag must be a bool or a map`) {
+		t.Errorf("invalid etag: err = %v", err)
+	}
+	if err := app.RegisterService("other", struct{}{}); err != nil {
+		t.Fatal(err)
+	}
+	cfg := routeCfg(true)
+	cfg.Workflows["http"].(map[string]any)["routes"].([]any)[0].(map[string]any)["handler"] = "other"
+	err = engine.configureRoutePipelines(cfg)
+	if err == nil || !strings.Contains(err.Error(), "does not support etag") {
+		t.Errorf("unsupported handler: err = %v", err)
+	}
+}
@@ -0,0 +1,18 @@
+package workflow
+
+import (
+	"github.com/GoCodeAlone/workflow/module"
+)
+
+// Projections returns the status of every module that implements
+// module.ProjectionReporter, such as eventstore.projection, keyed by
+// module name.
+func (e *StdEngine) Projections() map[string]module.ProjectionStatus {
+	statuses := map[string]module.ProjectionStatus{}
+	for name, m := range e.app.GetAllModules() {
+		if r, ok := m.(module.ProjectionReporter); ok {
+			statuses[name] = r.ProjectionStatus()
+		}
+	}
+	return statuses
+}
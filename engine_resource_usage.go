@@ -0,0 +1,33 @@
+package workflow
+
+import (
+	"github.com/GoCodeAlone/workflow/module"
+)
+
+// ResourceUsage returns the resource usage self-reported by each module and
+// named workflow handler that implements module.ResourceReporter, keyed by
+// module or handler name.
+func (e *StdEngine) ResourceUsage() map[string]module.ResourceUsage {
+	reporters := map[string]module.ResourceReporter{}
+	for name, m := range e.app.GetAllModules() {
+		if r, ok := m.(module.ResourceReporter); ok {
+			reporters[name] = r
+		}
+	}
+	for _, h := range e.workflowHandlers {
+		named, ok := h.(interface{ Name() string })
+		if r, isReporter := h.(module.ResourceReporter); ok && isReporter {
+			reporters[named.Name()] = r
+		}
+	}
+	return module.CollectResourceUsage(reporters)
+}
+
+// exportResourceUsage points the metrics collector, when one is registered,
+// at ResourceUsage so the exported gauges include workflow handlers.
+func (e *StdEngine) exportResourceUsage() {
+	var metrics *module.MetricsCollector
+	if err := e.app.GetService("metrics.collector", &metrics); err == nil && metrics != nil {
+		metrics.SetResourceUsageFunc(e.ResourceUsage)
+	}
+}
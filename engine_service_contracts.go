@@ -0,0 +1,109 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow/config"
+	"github.com/GoCodeAlone/workflow/module"
+)
+
+// ServiceWiring returns how the needs of the modules built by the last
+// BuildFromConfig were resolved to providers. It is empty when no module
+// declares provides or needs.
+func (e *StdEngine) ServiceWiring() []config.ServiceWiring {
+	return e.serviceWiring
+}
+
+// orderByServiceContracts resolves the provides/needs contracts of modules,
+// declared in config or in code by module.ServiceContractDeclarer, and
+// returns modules and their built instances reordered so every module
+// follows its dependsOn targets and the providers of its needs. deps holds
+// each module's resolvable dependencies, including its providers. Without
+// any contract the input order is returned unchanged.
+func (e *StdEngine) orderByServiceContracts(modules []config.ModuleConfig, built []modular.Module, names map[string]struct{}) ([]config.ModuleConfig, []modular.Module, map[string][]string, error) {
+	e.serviceWiring = nil
+	e.serviceContracts = nil
+	declared := make(map[string]config.ModuleContracts)
+	for i, mod := range built {
+		if d, ok := mod.(module.ServiceContractDeclarer); ok {
+			declared[modules[i].Name] = d.ServiceContracts()
+		}
+	}
+	if len(declared) == 0 && !config.HasServiceContracts(modules) {
+		return modules, built, nil, nil
+	}
+
+	wiring, err := config.ResolveServiceContracts(modules, declared)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("service contracts: %w", err)
+	}
+
+	// Order by dependsOn plus an edge from every consumer to its providers.
+	// The copies keep the providers out of the caller's config.
+	providers := make(map[string][]string)
+	for _, w := range wiring {
+		if w.Provider != w.Consumer && !slices.Contains(providers[w.Consumer], w.Provider) {
+			providers[w.Consumer] = append(providers[w.Consumer], w.Provider)
+		}
+	}
+	withEdges := make([]config.ModuleConfig, len(modules))
+	byName := make(map[string][]int, len(modules))
+	for i, m := range modules {
+		m.DependsOn = append(slices.Clone(m.DependsOn), providers[m.Name]...)
+		withEdges[i] = m
+		byName[m.Name] = append(byName[m.Name], i)
+	}
+	sorted, err := topoSortModules(withEdges)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("module ordering with service contracts failed: %w", err)
+	}
+
+	orderedModules := make([]config.ModuleConfig, 0, len(modules))
+	orderedBuilt := make([]modular.Module, 0, len(built))
+	deps := make(map[string][]string, len(modules))
+	for _, m := range sorted {
+		i := byName[m.Name][0]
+		byName[m.Name] = byName[m.Name][1:]
+		orderedModules = append(orderedModules, modules[i])
+		orderedBuilt = append(orderedBuilt, built[i])
+		if filtered := filterResolvableDeps(m.DependsOn, names, m.Name); len(filtered) > 0 {
+			deps[m.Name] = filtered
+		}
+	}
+
+	e.serviceWiring = wiring
+	e.serviceContracts = make(map[string]config.ModuleContracts, len(modules))
+	for _, m := range modules {
+		c := declared[m.Name]
+		c.Provides = append(slices.Clone(m.Provides), c.Provides...)
+		c.Needs = append(slices.Clone(m.Needs), c.Needs...)
+		if len(c.Provides) > 0 || len(c.Needs) > 0 {
+			e.serviceContracts[m.Name] = c
+		}
+	}
+	return orderedModules, orderedBuilt, deps, nil
+}
+
+// verifyProvidedServices checks after Init that every named service a module
+// declares it provides is in the service registry, so a module that fails to
+// register a promised service is reported at build time rather than when a
+// consumer first looks it up.
+func (e *StdEngine) verifyProvidedServices() error {
+	registry := e.app.SvcRegistry()
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(e.serviceContracts)) {
+		for _, p := range e.serviceContracts[name].Provides {
+			if p.Name == "" {
+				continue
+			}
+			if _, ok := registry[p.Name]; !ok {
+				errs = append(errs, &ModuleError{Module: name, Err: fmt.Errorf("module %q declares it provides service %q but did not register it", name, p.Name)})
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,163 @@
+package workflow
+
+import (
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow/config"
+)
+
+// contractModule records its Init order, registers the services named in
+// its "registers" config and, when declared is set, declares its contracts
+// in code.
+type contractModule struct {
+	initOrderRecorderModule
+	registers []string
+	declared  *config.ModuleContracts
+}
+
+func (m *contractModule) ProvidesServices() []modular.ServiceProvider {
+	out := make([]modular.ServiceProvider, 0, len(m.registers))
+	for _, name := range m.registers {
+		out = append(out, modular.ServiceProvider{Name: name, Instance: m})
+	}
+	return out
+}
+
+// codeContractModule is a contractModule implementing
+// module.ServiceContractDeclarer.
+type codeContractModule struct{ contractModule }
+
+func (m *codeContractModule) ServiceContracts() config.ModuleContracts { return *m.declared }
+
+func newContractEngine(t *testing.T, initOrder *[]string, declared map[string]config.ModuleContracts) *StdEngine {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	engine := NewStdEngine(modular.NewStdApplication(stubConfigProvider{}, logger), logger)
+	engine.AddModuleType("test.contract", func(name string, cfg map[string]any) modular.Module {
+		m := contractModule{initOrderRecorderModule: initOrderRecorderModule{name: name, initOrder: initOrder}}
+		if regs, ok := cfg["registers"].([]string); ok {
+			m.registers = regs
+		}
+		if c, ok := declared[name]; ok {
+			m.declared = &c
+			return &codeContractModule{m}
+		}
+		return &m
+	})
+	return engine
+}
+
+func TestEngine_ServiceContractsOrderModules(t *testing.T) {
+	var initOrder []string
+	engine := newContractEngine(t, &initOrder, nil)
+	cfg := &config.WorkflowConfig{
+		Modules: []config.ModuleConfig{
+			{Name: "api", Type: "test.contract", Needs: []config.ServiceContract{{Name: "orders-db"}}},
+			{Name: "z-db", Type: "test.contract", Config: map[string]any{"registers": []string{"orders-db"}},
+				Provides: []config.ServiceContract{{Name: "orders-db", Interface: "module.DBProvider"}}},
+		},
+	}
+	if err := engine.BuildFromConfig(cfg); err != nil {
+		t.Fatalf("BuildFromConfig: %v", err)
+	}
+	if !slices.Equal(initOrder, []string{"z-db", "api"}) {
+		t.Errorf("init order = %v, want [z-db api]", initOrder)
+	}
+	want := []config.ServiceWiring{{
+		Consumer: "api", Need: config.ServiceContract{Name: "orders-db"},
+		Provider: "z-db", Service: config.ServiceContract{Name: "orders-db", Interface: "module.DBProvider"},
+	}}
+	if got := engine.ServiceWiring(); !slices.Equal(got, want) {
+		t.Errorf("wiring = %+v, want %+v", got, want)
+	}
+	if len(cfg.Modules[0].DependsOn) != 0 {
+		t.Errorf("contract edges leaked into the config: %v", cfg.Modules[0].DependsOn)
+	}
+}
+
+func TestEngine_ServiceContractsDeclaredInCode(t *testing.T) {
+	var initOrder []string
+	engine := newContractEngine(t, &initOrder, map[string]config.ModuleContracts{
+		"api":   {Needs: []config.ServiceContract{{Interface: "module.MessageBroker"}}},
+		"z-bus": {Provides: []config.ServiceContract{{Name: "bus", Interface: "module.MessageBroker"}}},
+	})
+	cfg := &config.WorkflowConfig{
+		Modules: []config.ModuleConfig{
+			{Name: "api", Type: "test.contract"},
+			{Name: "z-bus", Type: "test.contract", Config: map[string]any{"registers": []string{"bus"}}},
+		},
+	}
+	if err := engine.BuildFromConfig(cfg); err != nil {
+		t.Fatalf("BuildFromConfig: %v", err)
+	}
+	if !slices.Equal(initOrder, []string{"z-bus", "api"}) {
+		t.Errorf("init order = %v, want [z-bus api]", initOrder)
+	}
+}
+
+func TestEngine_ServiceContractErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		modules []config.ModuleConfig
+		wantErr string
+	}{
+		{
+			name: "unsatisfied need",
+			modules: []config.ModuleConfig{
+				{Name: "api", Type: "test.contract", Needs: []config.ServiceContract{{Name: "orders-db"}}},
+			},
+			wantErr: `module "api" needs service orders-db: no module provides it`,
+		},
+		{
+			name: "ambiguous need",
+			modules: []config.ModuleConfig{
+				{Name: "api", Type: "test.contract", Needs: []config.ServiceContract{{Interface: "module.DBProvider"}}},
+				{Name: "db-a", Type: "test.contract", Provides: []config.ServiceContract{{Name: "a", Interface: "module.DBProvider"}}},
+				{Name: "db-b", Type: "test.contract", Provides: []config.ServiceContract{{Name: "b", Interface: "module.DBProvider"}}},
+			},
+			wantErr: "provided by multiple modules: db-a, db-b",
+		},
+		{
+			name: "provided service never registered",
+			modules: []config.ModuleConfig{
+				{Name: "db", Type: "test.contract", Provides: []config.ServiceContract{{Name: "orders-db"}}},
+			},
+			wantErr: `module "db" declares it provides service "orders-db" but did not register it`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var initOrder []string
+			engine := newContractEngine(t, &initOrder, nil)
+			err := engine.BuildFromConfig(&config.WorkflowConfig{Modules: tt.modules})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("BuildFromConfig error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEngine_NoServiceContractsKeepsOrder(t *testing.T) {
+	var initOrder []string
+	engine := newContractEngine(t, &initOrder, nil)
+	cfg := &config.WorkflowConfig{
+		Modules: []config.ModuleConfig{
+			{Name: "consumer", Type: "test.contract", DependsOn: []string{"z-broker"}},
+			{Name: "z-broker", Type: "test.contract"},
+		},
+	}
+	if err := engine.BuildFromConfig(cfg); err != nil {
+		t.Fatalf("BuildFromConfig: %v", err)
+	}
+	if !slices.Equal(initOrder, []string{"z-broker", "consumer"}) {
+		t.Errorf("init order = %v, want [z-broker consumer]", initOrder)
+	}
+	if engine.ServiceWiring() != nil {
+		t.Errorf("wiring = %v, want none", engine.ServiceWiring())
+	}
+}
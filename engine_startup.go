@@ -0,0 +1,356 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/GoCodeAlone/modular"
+)
+
+// ModuleError attributes a build or start error to the module it concerns,
+// so callers can find the module with errors.As instead of parsing the
+// message. Its message is Err's, which already names the module.
+type ModuleError struct {
+	Module string
+	Err    error
+}
+
+func (e *ModuleError) Error() string { return e.Err.Error() }
+
+func (e *ModuleError) Unwrap() error { return e.Err }
+
+// StartupTimeoutError reports modules that were still in Init or Start when a
+// startup deadline passed. It matches context.DeadlineExceeded with errors.Is.
+type StartupTimeoutError struct {
+	// Phase is "Init" or "Start".
+	Phase string
+	// Modules are the modules that had not finished. A per-module Init
+	// timeout can list more than one module when modules are initialized in
+	// parallel.
+	Modules []string
+	// Timeout is the deadline that passed.
+	Timeout time.Duration
+	// PerModule is true when a single module's timeout expired rather than
+	// the deadline for the whole phase.
+	PerModule bool
+}
+
+func (e *StartupTimeoutError) Error() string {
+	if e.PerModule {
+		return fmt.Sprintf("module %s did not finish %s within %s", strings.Join(e.Modules, " or "), e.Phase, e.Timeout)
+	}
+	if len(e.Modules) == 0 {
+		return fmt.Sprintf("module %s did not finish within %s", e.Phase, e.Timeout)
+	}
+	return fmt.Sprintf("module %s did not finish within %s; unfinished: %s", e.Phase, e.Timeout, strings.Join(e.Modules, ", "))
+}
+
+func (e *StartupTimeoutError) Unwrap() error { return context.DeadlineExceeded }
+
+// DefaultStartTimeoutGrace is how long Start waits, after a startup timeout
+// cancelled the modules still starting, for their Start methods to return.
+// Modules that return without error within it are stopped again with the
+// rest; a module still running after it is left behind and logged.
+const DefaultStartTimeoutGrace = 5 * time.Second
+
+// SetStartupTimeouts bounds engine startup. total limits module Init (during
+// BuildFromConfig) and module Start (during Start) as a whole; perModule
+// limits any single module's Init or Start. Zero disables a limit.
+func (e *StdEngine) SetStartupTimeouts(total, perModule time.Duration) {
+	e.startupTimeout = total
+	e.moduleTimeout = perModule
+}
+
+// initModules runs app.Init, enforcing the startup timeouts.
+//
+// Module Init takes no context, so a stuck Init cannot be cancelled: the
+// engine stops waiting, leaves it running, and fails the build with the
+// module it was stuck in. modular does not expose which module it is
+// initializing, so each module is wrapped in an initTracker for the duration
+// of Init to report when its Init starts and returns and to attribute its
+// errors with a ModuleError. The wrappers are replaced by the modules again
+// once app.Init returns, even when the engine stopped waiting for it.
+func (e *StdEngine) initModules() error {
+	// A copy: modular drops a module from the application when injecting
+	// its services fails, and attributeInitError still needs it.
+	modules := maps.Clone(e.app.GetAllModules())
+	names := slices.Sorted(maps.Keys(modules))
+	events := make(chan initEvent, 2*len(names))
+	for _, name := range names {
+		e.app.RegisterModule(trackInit(modules[name], events))
+	}
+	result := make(chan error, 1)
+	go func() {
+		err := e.app.Init()
+		e.untrackInit()
+		result <- e.attributeInitError(err, modules)
+	}()
+
+	var overall <-chan time.Time
+	if e.startupTimeout > 0 {
+		t := time.NewTimer(e.startupTimeout)
+		defer t.Stop()
+		overall = t.C
+	}
+
+	running := make(map[string]time.Time)
+	finished := make(map[string]bool, len(names))
+	var stall *time.Timer
+	var stalled <-chan time.Time
+	defer func() {
+		if stall != nil {
+			stall.Stop()
+		}
+	}()
+	// resetStall arms the per-module timer for the module that has been in
+	// Init the longest.
+	resetStall := func() {
+		if stall != nil {
+			stall.Stop()
+			stall, stalled = nil, nil
+		}
+		if e.moduleTimeout <= 0 || len(running) == 0 {
+			return
+		}
+		earliest := time.Now()
+		for _, started := range running {
+			if started.Before(earliest) {
+				earliest = started
+			}
+		}
+		stall = time.NewTimer(time.Until(earliest.Add(e.moduleTimeout)))
+		stalled = stall.C
+	}
+
+	for {
+		select {
+		case err := <-result:
+			return err
+		case ev := <-events:
+			if ev.done {
+				delete(running, ev.module)
+				finished[ev.module] = true
+			} else {
+				running[ev.module] = ev.at
+			}
+			resetStall()
+		case <-overall:
+			var unfinished []string
+			for _, name := range names {
+				if !finished[name] {
+					unfinished = append(unfinished, name)
+				}
+			}
+			return &StartupTimeoutError{Phase: "Init", Modules: unfinished, Timeout: e.startupTimeout}
+		case now := <-stalled:
+			var stuck []string
+			for name, started := range running {
+				if now.Sub(started) >= e.moduleTimeout {
+					stuck = append(stuck, name)
+				}
+			}
+			slices.Sort(stuck)
+			return &StartupTimeoutError{Phase: "Init", Modules: stuck, Timeout: e.moduleTimeout, PerModule: true}
+		}
+	}
+}
+
+// untrackInit replaces the initTrackers in the application with the modules
+// they wrap.
+func (e *StdEngine) untrackInit() {
+	for _, m := range e.app.GetAllModules() {
+		if inner := untrackedModule(m); inner != m {
+			e.app.RegisterModule(inner)
+		}
+	}
+}
+
+// attributeInitError attributes an Init error that modular raised while
+// injecting a missing required service, before the module's initTracker ran,
+// to the one module of modules requiring a service by name that is not
+// registered. The error is returned as is when no single module accounts for
+// it.
+func (e *StdEngine) attributeInitError(err error, modules map[string]modular.Module) error {
+	var modErr *ModuleError
+	if err == nil || errors.As(err, &modErr) || !errors.Is(err, modular.ErrRequiredServiceNotFound) {
+		return err
+	}
+	registry := e.app.SvcRegistry()
+	var missing []string
+	for name, m := range modules {
+		sa, ok := m.(modular.ServiceAware)
+		if !ok {
+			continue
+		}
+		for _, dep := range sa.RequiresServices() {
+			if _, found := registry[dep.Name]; dep.Required && !dep.MatchByInterface && !found {
+				missing = append(missing, name)
+				break
+			}
+		}
+	}
+	if len(missing) != 1 {
+		return err
+	}
+	return &ModuleError{Module: missing[0], Err: err}
+}
+
+// initEvent reports that a module's Init started or, with done, returned.
+type initEvent struct {
+	module string
+	done   bool
+	at     time.Time
+}
+
+// initTracker wraps a module during app.Init and reports on events when its
+// Init starts and returns. modular consults the optional interfaces below
+// while it initializes modules, so initTracker forwards them, answering as
+// modular would for a module without them when the wrapped module does not
+// implement one. It does not forward modular.TenantAwareModule: the engine
+// registers no tenant service for modular to hand tenant-aware modules to.
+type initTracker struct {
+	modular.Module
+	events chan<- initEvent
+}
+
+// constructableInitTracker is the initTracker of a modular.Constructable
+// module. Its constructor wraps the module it constructs.
+type constructableInitTracker struct {
+	*initTracker
+}
+
+func trackInit(m modular.Module, events chan<- initEvent) modular.Module {
+	t := &initTracker{Module: m, events: events}
+	if _, ok := m.(modular.Constructable); ok {
+		return constructableInitTracker{t}
+	}
+	return t
+}
+
+// untrackedModule returns the module m wraps, or m itself.
+func untrackedModule(m modular.Module) modular.Module {
+	switch t := m.(type) {
+	case *initTracker:
+		return t.Module
+	case constructableInitTracker:
+		return t.Module
+	}
+	return m
+}
+
+func (t *initTracker) report(done bool) {
+	select {
+	case t.events <- initEvent{module: t.Name(), done: done, at: time.Now()}:
+	default:
+	}
+}
+
+// attribute wraps err in a ModuleError naming the tracked module.
+func (t *initTracker) attribute(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ModuleError{Module: t.Name(), Err: err}
+}
+
+func (t *initTracker) Init(app modular.Application) error {
+	t.report(false)
+	defer t.report(true)
+	return t.attribute(t.Module.Init(app))
+}
+
+func (t *initTracker) RegisterConfig(app modular.Application) error {
+	if c, ok := t.Module.(modular.Configurable); ok {
+		return t.attribute(c.RegisterConfig(app))
+	}
+	return nil
+}
+
+func (t *initTracker) Dependencies() []string {
+	if d, ok := t.Module.(modular.DependencyAware); ok {
+		return d.Dependencies()
+	}
+	return nil
+}
+
+func (t *initTracker) ProvidesServices() []modular.ServiceProvider {
+	if s, ok := t.Module.(modular.ServiceAware); ok {
+		return s.ProvidesServices()
+	}
+	return nil
+}
+
+func (t *initTracker) RequiresServices() []modular.ServiceDependency {
+	if s, ok := t.Module.(modular.ServiceAware); ok {
+		return s.RequiresServices()
+	}
+	return nil
+}
+
+func (t constructableInitTracker) Constructor() modular.ModuleConstructor {
+	construct := t.Module.(modular.Constructable).Constructor()
+	return func(app modular.Application, services map[string]any) (modular.Module, error) {
+		m, err := construct(app, services)
+		if err != nil {
+			return nil, t.attribute(err)
+		}
+		return trackInit(m, t.events), nil
+	}
+}
+
+// startModule calls m.Start. With a per-module timeout it gives up once the
+// timeout passes and cancels the context the module was started with;
+// otherwise that context lives on as the module's lifecycle context. A
+// module that still starts within the grace period after its timeout is
+// stopped again, since the failed start leaves it out of the rollback.
+func (e *StdEngine) startModule(ctx context.Context, name string, m modular.Startable) error {
+	if e.moduleTimeout <= 0 {
+		return m.Start(ctx)
+	}
+	mctx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() { done <- m.Start(mctx) }()
+	timer := time.NewTimer(e.moduleTimeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		if err != nil {
+			cancel()
+		} else {
+			// Released when the lifecycle context ends.
+			context.AfterFunc(ctx, cancel)
+		}
+		return err
+	case <-timer.C:
+		cancel()
+		e.stopLateStart(name, m, done)
+		return &StartupTimeoutError{Phase: "Start", Modules: []string{name}, Timeout: e.moduleTimeout, PerModule: true}
+	}
+}
+
+// stopLateStart waits up to the grace period for the cancelled start of m to
+// report on done and stops the module if it started after all.
+func (e *StdEngine) stopLateStart(name string, m modular.Startable, done <-chan error) {
+	grace := time.NewTimer(e.startGrace)
+	defer grace.Stop()
+	select {
+	case err := <-done:
+		stoppable, ok := m.(modular.Stoppable)
+		if err != nil || !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		e.logger.Info("Stopping module that started after its timeout", "module", name)
+		if err := stoppable.Stop(ctx); err != nil {
+			e.logger.Error("Error stopping module that started after its timeout", "module", name, "error", err)
+		}
+	case <-grace.C:
+		e.logger.Warn("Module did not return from a cancelled Start; it is not stopped", "module", name, "grace", e.startGrace)
+	}
+}
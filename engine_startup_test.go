@@ -0,0 +1,288 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow/config"
+)
+
+// hangingModule blocks in Init or Start until released or, for Start, until
+// its context is cancelled. With startOnCancel its Start succeeds as soon as
+// it is cancelled, like a module that finishes starting late.
+type hangingModule struct {
+	name          string
+	deps          []string
+	hangInit      bool
+	hangStart     bool
+	startOnCancel bool
+	initErr       error
+	release       chan struct{}
+	cancelled     chan struct{}
+	stopped       atomic.Bool
+}
+
+func newHangingModule(name string) *hangingModule {
+	return &hangingModule{name: name, release: make(chan struct{}), cancelled: make(chan struct{})}
+}
+
+func (m *hangingModule) Name() string           { return m.name }
+func (m *hangingModule) Dependencies() []string { return m.deps }
+
+func (m *hangingModule) Init(modular.Application) error {
+	if m.hangInit {
+		<-m.release
+	}
+	return m.initErr
+}
+
+func (m *hangingModule) Start(ctx context.Context) error {
+	if !m.hangStart {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		close(m.cancelled)
+		if !m.startOnCancel {
+			<-m.release
+		}
+	case <-m.release:
+	}
+	return nil
+}
+
+func (m *hangingModule) Stop(context.Context) error {
+	m.stopped.Store(true)
+	return nil
+}
+
+func newTimeoutTestEngine(t *testing.T, total, perModule time.Duration, mods ...modular.Module) *StdEngine {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	app := modular.NewStdApplication(nil, logger)
+	for _, m := range mods {
+		app.RegisterModule(m)
+	}
+	e := NewStdEngine(app, logger)
+	e.SetStartupTimeouts(total, perModule)
+	e.startGrace = 100 * time.Millisecond
+	return e
+}
+
+func TestEngineStart_ModuleTimeoutCancelsAndReportsModule(t *testing.T) {
+	rec := newStartRecorder()
+	db := newHangingModule("db")
+	db.hangStart = true
+	t.Cleanup(func() { close(db.release) })
+	cache := &timedModule{name: "cache", rec: rec}
+	e := newTimeoutTestEngine(t, 0, 100*time.Millisecond, db, cache)
+	if err := e.initModules(); err != nil {
+		t.Fatal(err)
+	}
+
+	began := time.Now()
+	err := e.Start(context.Background())
+	if err == nil {
+		t.Fatal("expected Start to fail")
+	}
+	if took := time.Since(began); took > time.Second {
+		t.Errorf("Start took %v, want it to give up after the 100ms module timeout", took)
+	}
+	var timeout *StartupTimeoutError
+	if !errors.As(err, &timeout) || !timeout.PerModule || timeout.Phase != "Start" || !slices.Equal(timeout.Modules, []string{"db"}) {
+		t.Fatalf("error = %v, want a per-module Start timeout for db", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("timeout error should match context.DeadlineExceeded")
+	}
+	if !strings.Contains(err.Error(), "module db did not finish Start within 100ms") {
+		t.Errorf("error = %q", err)
+	}
+	select {
+	case <-db.cancelled:
+	case <-time.After(time.Second):
+		t.Error("stuck module's context was not cancelled")
+	}
+	if !slices.Equal(rec.stopped, []string{"cache"}) {
+		t.Errorf("stopped = %v, want the started cache module rolled back", rec.stopped)
+	}
+}
+
+func TestEngineStart_StartupTimeoutReportsInFlightModules(t *testing.T) {
+	a, b := newHangingModule("a"), newHangingModule("b")
+	a.hangStart, b.hangStart = true, true
+	t.Cleanup(func() { close(a.release); close(b.release) })
+	e := newTimeoutTestEngine(t, 100*time.Millisecond, 0, a, b)
+	if err := e.initModules(); err != nil {
+		t.Fatal(err)
+	}
+
+	err := e.Start(context.Background())
+	var timeout *StartupTimeoutError
+	if !errors.As(err, &timeout) || timeout.PerModule || !slices.Equal(timeout.Modules, []string{"a", "b"}) {
+		t.Fatalf("error = %v, want a startup timeout naming a and b", err)
+	}
+	for _, m := range []*hangingModule{a, b} {
+		select {
+		case <-m.cancelled:
+		case <-time.After(time.Second):
+			t.Errorf("module %s context was not cancelled", m.name)
+		}
+	}
+}
+
+func TestEngineStart_StartupTimeoutRollsBackLateStarts(t *testing.T) {
+	late, stuck := newHangingModule("late"), newHangingModule("stuck")
+	late.hangStart, late.startOnCancel, stuck.hangStart = true, true, true
+	t.Cleanup(func() { close(stuck.release) })
+	e := newTimeoutTestEngine(t, 100*time.Millisecond, 0, late, stuck)
+	if err := e.initModules(); err != nil {
+		t.Fatal(err)
+	}
+
+	err := e.Start(context.Background())
+	var rb *StartRollbackError
+	if !errors.As(err, &rb) || !slices.Equal(rb.RolledBack, []string{"late"}) {
+		t.Fatalf("error = %v, want the module that started after the timeout rolled back", err)
+	}
+	if !late.stopped.Load() || stuck.stopped.Load() {
+		t.Errorf("stopped late=%v stuck=%v, want only the late module stopped", late.stopped.Load(), stuck.stopped.Load())
+	}
+}
+
+func TestEngineStart_ModuleTimeoutStopsLateStart(t *testing.T) {
+	db := newHangingModule("db")
+	db.hangStart, db.startOnCancel = true, true
+	e := newTimeoutTestEngine(t, 0, 50*time.Millisecond, db)
+	if err := e.initModules(); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Start(context.Background()); err == nil || !strings.Contains(err.Error(), "module db did not finish Start") {
+		t.Fatalf("error = %v, want db start timeout", err)
+	}
+	if !db.stopped.Load() {
+		t.Error("module that started after its timeout was not stopped")
+	}
+}
+
+func TestEngineStart_TimeoutsApplyWithSequentialStart(t *testing.T) {
+	db := newHangingModule("db")
+	db.hangStart = true
+	t.Cleanup(func() { close(db.release) })
+	e := newTimeoutTestEngine(t, 0, 50*time.Millisecond, db)
+	e.SetModuleStartConcurrency(1)
+	if err := e.initModules(); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Start(context.Background()); err == nil || !strings.Contains(err.Error(), "module db did not finish Start") {
+		t.Errorf("error = %v, want db start timeout", err)
+	}
+}
+
+func TestEngineInit_ModuleTimeoutReportsStuckModule(t *testing.T) {
+	db := newHangingModule("db")
+	db.hangInit = true
+	t.Cleanup(func() { close(db.release) })
+	api := newHangingModule("api")
+	api.deps = []string{"db"}
+	cache := newHangingModule("cache")
+	cache.deps = []string{"db"}
+	e := newTimeoutTestEngine(t, 0, 100*time.Millisecond, cache, db, api)
+
+	err := e.initModules()
+	var timeout *StartupTimeoutError
+	if !errors.As(err, &timeout) || timeout.Phase != "Init" || !timeout.PerModule {
+		t.Fatalf("error = %v, want a per-module Init timeout", err)
+	}
+	// api and cache wait on db, so only db can be the one stuck.
+	if !slices.Equal(timeout.Modules, []string{"db"}) {
+		t.Errorf("Modules = %v, want [db]", timeout.Modules)
+	}
+}
+
+func TestEngineInit_StartupTimeout(t *testing.T) {
+	db := newHangingModule("db")
+	db.hangInit = true
+	t.Cleanup(func() { close(db.release) })
+	api := newHangingModule("api")
+	api.deps = []string{"db"}
+	e := newTimeoutTestEngine(t, 100*time.Millisecond, 0, db, api)
+
+	err := e.initModules()
+	var timeout *StartupTimeoutError
+	if !errors.As(err, &timeout) || timeout.PerModule || !slices.Equal(timeout.Modules, []string{"api", "db"}) {
+		t.Fatalf("error = %v, want a startup timeout listing api and db", err)
+	}
+}
+
+func TestEngineInit_NoTimeoutWhenModulesAreQuick(t *testing.T) {
+	a, b := newHangingModule("a"), newHangingModule("b")
+	e := newTimeoutTestEngine(t, time.Second, 500*time.Millisecond, a, b)
+	if err := e.initModules(); err != nil {
+		t.Fatal(err)
+	}
+	if e.app.GetModule("a") != a || e.app.GetModule("b") != b {
+		t.Error("Init trackers were not replaced by the modules after Init")
+	}
+}
+
+func TestEngineInit_AttributesModuleErrors(t *testing.T) {
+	db := newHangingModule("db")
+	db.initErr = errors.New("connection refused")
+	e := newTimeoutTestEngine(t, 0, 0, newHangingModule("api"), db)
+
+	err := e.initModules()
+	var modErr *ModuleError
+	if !errors.As(err, &modErr) || modErr.Module != "db" {
+		t.Fatalf("error = %v, want a ModuleError for db", err)
+	}
+	if !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("error = %v, want the Init error", err)
+	}
+}
+
+func TestEngineInit_TracksModulesThatLogNothing(t *testing.T) {
+	db := newHangingModule("db")
+	db.hangInit = true
+	t.Cleanup(func() { close(db.release) })
+	e := newTimeoutTestEngine(t, 0, 50*time.Millisecond, db)
+	// Progress does not depend on what modular logs.
+	e.app.SetLogger(slog.New(slog.DiscardHandler))
+
+	err := e.initModules()
+	var timeout *StartupTimeoutError
+	if !errors.As(err, &timeout) || !timeout.PerModule || !slices.Equal(timeout.Modules, []string{"db"}) {
+		t.Fatalf("error = %v, want db Init timeout", err)
+	}
+}
+
+func TestEngine_StartupTimeoutsFromConfig(t *testing.T) {
+	app := newMockApplication()
+	engine := NewStdEngine(app, app.logger)
+	loadAllPlugins(t, engine)
+
+	cfg := &config.WorkflowConfig{
+		Modules:   []config.ModuleConfig{},
+		Workflows: map[string]any{},
+		Engine:    &config.EngineConfig{Startup: &config.EngineStartupConfig{Timeout: "2m", ModuleTimeout: "soon"}},
+	}
+	if err := engine.BuildFromConfig(cfg); err == nil || !strings.Contains(err.Error(), `engine.startup.moduleTimeout: invalid duration "soon"`) {
+		t.Fatalf("error = %v, want invalid moduleTimeout", err)
+	}
+
+	cfg.Engine.Startup.ModuleTimeout = "30s"
+	if err := engine.BuildFromConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if engine.startupTimeout != 2*time.Minute || engine.moduleTimeout != 30*time.Second {
+		t.Errorf("timeouts = %v, %v; want 2m, 30s", engine.startupTimeout, engine.moduleTimeout)
+	}
+}
@@ -569,7 +569,9 @@ func (a *mockApplication) SetVerboseConfig(enabled bool) {
 
 // SetLogger sets the application's logger
 func (a *mockApplication) SetLogger(logger modular.Logger) {
-	a.logger = logger.(*mockLogger) // Assume it's a mockLogger for tests
+	if l, ok := logger.(*mockLogger); ok {
+		a.logger = l
+	}
 }
 
 // GetServicesByModule returns all services provided by a specific module
@@ -1509,6 +1511,43 @@ func newMinimalPlugin(name, version string) *minimalPlugin {
 	}
 }
 
+// templateFuncPlugin is a test-only EnginePlugin that provides template
+// functions.
+type templateFuncPlugin struct {
+	*minimalPlugin
+	funcs map[string]any
+}
+
+func (p *templateFuncPlugin) TemplateFuncs() map[string]any { return p.funcs }
+
+// TestEngine_LoadPlugin_TemplateFuncs verifies that plugin template functions
+// are registered on load and that a name collision fails the load.
+func TestEngine_LoadPlugin_TemplateFuncs(t *testing.T) {
+	t.Cleanup(func() { _ = module.RegisterTemplateFuncs("slug-funcs", nil) })
+	app := newMockApplication()
+	engine := NewStdEngine(app, app.Logger())
+
+	slug := &templateFuncPlugin{newMinimalPlugin("slug-funcs", "1.0.0"), map[string]any{
+		"slug": func(s string) string { return strings.ReplaceAll(strings.ToLower(s), " ", "-") },
+	}}
+	if err := engine.LoadPlugin(slug); err != nil {
+		t.Fatalf("LoadPlugin failed: %v", err)
+	}
+	got, err := module.NewTemplateEngine().Resolve(`{{ slug .title }}`, module.NewPipelineContext(map[string]any{"title": "Hello World"}, nil))
+	if err != nil || got != "hello-world" {
+		t.Fatalf("slug = %q, %v", got, err)
+	}
+
+	for _, p := range []*templateFuncPlugin{
+		{newMinimalPlugin("other-funcs", "1.0.0"), map[string]any{"slug": strings.ToLower}},
+		{newMinimalPlugin("upper-funcs", "1.0.0"), map[string]any{"upper": strings.ToUpper}},
+	} {
+		if err := NewStdEngine(app, app.Logger()).LoadPlugin(p); err == nil || !strings.Contains(err.Error(), "is taken by") {
+			t.Errorf("LoadPlugin(%s): error = %v, want a name collision", p.Name(), err)
+		}
+	}
+}
+
 // TestEngine_BuildFromConfig_RequiresPlugins_NilRequires verifies that a
 // config with no requires section is accepted without error.
 func TestEngine_BuildFromConfig_RequiresPlugins_NilRequires(t *testing.T) {
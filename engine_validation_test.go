@@ -209,3 +209,123 @@ func TestEngine_TemplateValidation_ValidPipeline(t *testing.T) {
 		t.Fatalf("BuildFromConfig failed for valid pipeline: %v", err)
 	}
 }
+
+// TestEngine_MetricStepValidation verifies that step.metric declarations of the
+// same metric with different label sets fail the build instead of failing the
+// second pipeline at runtime.
+func TestEngine_MetricStepValidation(t *testing.T) {
+	app := newMockApplication()
+	engine := NewStdEngine(app, app.logger)
+	loadAllPlugins(t, engine)
+
+	metricStep := func(labels map[string]any) map[string]any {
+		return map[string]any{
+			"name":   "count",
+			"type":   "step.metric",
+			"config": map[string]any{"metric": "orders_created_total", "labels": labels},
+		}
+	}
+	cfg := &config.WorkflowConfig{
+		Modules:   []config.ModuleConfig{},
+		Workflows: map[string]any{},
+		Pipelines: map[string]any{
+			"create-order": map[string]any{"steps": []any{metricStep(map[string]any{"country": "US"})}},
+			"import-order": map[string]any{"steps": []any{metricStep(map[string]any{"source": "csv"})}},
+		},
+	}
+
+	err := engine.BuildFromConfig(cfg)
+	if err == nil || !strings.Contains(err.Error(), "metric validation failed") {
+		t.Fatalf("expected metric validation error, got %v", err)
+	}
+}
+
+func TestEngine_DynamicModulesValidation(t *testing.T) {
+	app := newMockApplication()
+	engine := NewStdEngine(app, app.logger)
+	loadAllPlugins(t, engine)
+
+	cfg := &config.WorkflowConfig{
+		Modules:   []config.ModuleConfig{},
+		Workflows: map[string]any{},
+		Engine: &config.EngineConfig{
+			Dynamic: &config.EngineDynamicConfig{Modules: []string{"github.com/google/uuid", "example.com/unvetted"}},
+		},
+	}
+
+	err := engine.BuildFromConfig(cfg)
+	if err == nil || !strings.Contains(err.Error(), `engine.dynamic.modules: dynamic module "example.com/unvetted"`) {
+		t.Fatalf("expected dynamic modules error, got %v", err)
+	}
+}
+
+// TestEngine_StateMachineValidation verifies that an undeclared state fails
+// BuildFromConfig by default, and that engine.validation.stateMachines can
+// override the severity of individual checks.
+func TestEngine_StateMachineValidation(t *testing.T) {
+	workflows := map[string]any{
+		"statemachine": map[string]any{
+			"definitions": []any{
+				map[string]any{
+					"name":         "orders",
+					"initialState": "new",
+					"states": map[string]any{
+						"new":  map[string]any{},
+						"done": map[string]any{"isFinal": true},
+					},
+					"transitions": map[string]any{
+						"finish": map[string]any{"fromState": "new", "toState": "finished"},
+					},
+				},
+			},
+		},
+	}
+
+	app := newMockApplication()
+	engine := NewStdEngine(app, app.logger)
+	loadAllPlugins(t, engine)
+	err := engine.BuildFromConfig(&config.WorkflowConfig{Workflows: workflows})
+	if err == nil || !strings.Contains(err.Error(), `toState "finished" is not a declared state`) {
+		t.Fatalf("expected state machine validation error, got: %v", err)
+	}
+
+	app = newMockApplication()
+	engine = NewStdEngine(app, app.logger)
+	loadAllPlugins(t, engine)
+	err = engine.BuildFromConfig(&config.WorkflowConfig{
+		Workflows: workflows,
+		Engine: &config.EngineConfig{
+			Validation: &config.EngineValidationConfig{
+				StateMachines: map[string]string{"undeclared_state": "bogus"},
+			},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "engine.validation.stateMachines") {
+		t.Fatalf("expected invalid severity error, got: %v", err)
+	}
+
+	app = newMockApplication()
+	engine = NewStdEngine(app, app.logger)
+	loadAllPlugins(t, engine)
+	err = engine.BuildFromConfig(&config.WorkflowConfig{
+		Workflows: workflows,
+		Engine: &config.EngineConfig{
+			Validation: &config.EngineValidationConfig{
+				StateMachines: map[string]string{"undeclared_state": "warn"},
+			},
+		},
+	})
+	if err != nil && strings.Contains(err.Error(), "state machine validation failed") {
+		t.Fatalf("undeclared_state: warn should not fail validation, got: %v", err)
+	}
+	found := false
+	for _, entry := range app.logger.logs {
+		if strings.Contains(entry, "finished") {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Errorf("expected warning log about state 'finished', log entries: %v", app.logger.logs)
+	}
+}
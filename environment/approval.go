@@ -0,0 +1,90 @@
+package environment
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Config keys that control two-person approval of engine config changes.
+const (
+	ConfigKeyRequireApproval = "require_approval"
+	ConfigKeyApprovers       = "approvers"
+	ConfigKeyApprovalTTL     = "approval_ttl"
+	ConfigKeyApprovalWebhook = "approval_webhook"
+)
+
+// ApprovalSettings are the config-change approval settings of an environment,
+// read from its Config map.
+type ApprovalSettings struct {
+	// RequireApproval stages config changes until a second user approves.
+	RequireApproval bool
+	// Approvers restricts who may approve; empty means any other user.
+	Approvers []string
+	// TTL is how long a staged change stays approvable; zero means the
+	// server default.
+	TTL time.Duration
+	// Webhook receives a JSON notification when a change is staged.
+	Webhook string
+}
+
+// ApprovalSettings parses the environment's approval settings. Approvers may
+// be a list or a comma-separated string.
+func (e *Environment) ApprovalSettings() (ApprovalSettings, error) {
+	var s ApprovalSettings
+	switch v := e.Config[ConfigKeyRequireApproval].(type) {
+	case nil:
+	case bool:
+		s.RequireApproval = v
+	case string:
+		s.RequireApproval = strings.EqualFold(v, "true")
+	default:
+		return s, fmt.Errorf("environment %q: %s must be a boolean", e.Name, ConfigKeyRequireApproval)
+	}
+
+	switch v := e.Config[ConfigKeyApprovers].(type) {
+	case nil:
+	case string:
+		for _, a := range strings.Split(v, ",") {
+			if a = strings.TrimSpace(a); a != "" {
+				s.Approvers = append(s.Approvers, a)
+			}
+		}
+	case []any:
+		for _, a := range v {
+			str, ok := a.(string)
+			if !ok {
+				return s, fmt.Errorf("environment %q: %s must contain strings", e.Name, ConfigKeyApprovers)
+			}
+			s.Approvers = append(s.Approvers, str)
+		}
+	default:
+		return s, fmt.Errorf("environment %q: %s must be a list of users", e.Name, ConfigKeyApprovers)
+	}
+
+	if v, ok := e.Config[ConfigKeyApprovalTTL].(string); ok && v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil {
+			return s, fmt.Errorf("environment %q: invalid %s %q: %w", e.Name, ConfigKeyApprovalTTL, v, err)
+		}
+		s.TTL = ttl
+	}
+	s.Webhook, _ = e.Config[ConfigKeyApprovalWebhook].(string)
+	return s, nil
+}
+
+// FindByName returns the environment with the given name (case-insensitive),
+// or nil when there is none.
+func FindByName(ctx context.Context, store Store, name string) (*Environment, error) {
+	envs, err := store.List(ctx, Filter{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range envs {
+		if strings.EqualFold(envs[i].Name, name) {
+			return &envs[i], nil
+		}
+	}
+	return nil, nil
+}
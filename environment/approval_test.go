@@ -0,0 +1,59 @@
+package environment
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestApprovalSettings(t *testing.T) {
+	env := &Environment{Name: "production", Config: map[string]any{
+		"require_approval": true,
+		"approvers":        []any{"alice", "bob"},
+		"approval_ttl":     "2h",
+		"approval_webhook": "https://hooks.example.com/approvals",
+	}}
+	s, err := env.ApprovalSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.RequireApproval || len(s.Approvers) != 2 || s.TTL != 2*time.Hour || s.Webhook == "" {
+		t.Errorf("unexpected settings: %+v", s)
+	}
+
+	s, err = (&Environment{Config: map[string]any{"approvers": "alice, bob ,"}}).ApprovalSettings()
+	if err != nil || s.RequireApproval || len(s.Approvers) != 2 || s.Approvers[1] != "bob" {
+		t.Errorf("unexpected settings from string approvers: %+v, %v", s, err)
+	}
+
+	for _, cfg := range []map[string]any{
+		{"require_approval": 1},
+		{"approvers": []any{1}},
+		{"approval_ttl": "soon"},
+	} {
+		if _, err := (&Environment{Config: cfg}).ApprovalSettings(); err == nil {
+			t.Errorf("expected error for %v", cfg)
+		}
+	}
+}
+
+func TestFindByName(t *testing.T) {
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "env.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+	if err := store.Create(ctx, &Environment{Name: "production", Provider: "aws", WorkflowID: "wf"}); err != nil {
+		t.Fatal(err)
+	}
+
+	env, err := FindByName(ctx, store, "Production")
+	if err != nil || env == nil || env.Name != "production" {
+		t.Fatalf("FindByName = %+v, %v", env, err)
+	}
+	if env, err := FindByName(ctx, store, "staging"); err != nil || env != nil {
+		t.Errorf("expected no match, got %+v, %v", env, err)
+	}
+}
@@ -7,11 +7,17 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/GoCodeAlone/workflow/audit"
 )
 
 // Handler exposes environment CRUD endpoints over HTTP.
 type Handler struct {
-	store Store
+	store      Store
+	signingKey []byte
+	auditStore audit.Store
+	actor      func(*http.Request) string
+	now        func() time.Time
 }
 
 // NewHandler creates a new environment HTTP handler.
@@ -19,6 +25,24 @@ func NewHandler(store Store) *Handler {
 	return &Handler{store: store}
 }
 
+// SetSigningKey sets the key used to sign exported snapshots and verify
+// snapshots submitted for diff and promotion. Without a key the snapshot
+// endpoints are unavailable.
+func (h *Handler) SetSigningKey(key []byte) {
+	h.signingKey = key
+}
+
+// SetAuditStore sets where snapshot promotions are recorded.
+func (h *Handler) SetAuditStore(store audit.Store) {
+	h.auditStore = store
+}
+
+// SetActorFunc sets how the user behind a request is identified in audit
+// records.
+func (h *Handler) SetActorFunc(fn func(*http.Request) string) {
+	h.actor = fn
+}
+
 // RegisterRoutes registers environment endpoints on the given mux.
 func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /api/v1/admin/environments", h.handleList)
@@ -27,6 +51,9 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("PUT /api/v1/admin/environments/{id}", h.handleUpdate)
 	mux.HandleFunc("DELETE /api/v1/admin/environments/{id}", h.handleDelete)
 	mux.HandleFunc("POST /api/v1/admin/environments/{id}/test", h.handleTestConnection)
+	mux.HandleFunc("GET /api/v1/admin/environments/{id}/export", h.handleExport)
+	mux.HandleFunc("POST /api/v1/admin/environments/{id}/diff", h.handleDiff)
+	mux.HandleFunc("POST /api/v1/admin/environments/{id}/promote", h.handlePromote)
 }
 
 // ---------- GET /api/v1/admin/environments ----------
@@ -0,0 +1,206 @@
+package environment
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// SnapshotVersion is the format version written into exported snapshots.
+const SnapshotVersion = 1
+
+// SecretRefPrefix marks a secret value that is resolved by the secrets
+// provider at runtime instead of being stored in the environment.
+const SecretRefPrefix = "secret://"
+
+// Change operations reported in a SnapshotDiff.
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// ErrInvalidSignature is returned when a snapshot's signature does not match
+// its contents.
+var ErrInvalidSignature = errors.New("snapshot signature is invalid")
+
+// Snapshot is a portable export of an environment's variables, used to
+// promote a reviewed set of values from one environment to another. Secret
+// values never leave the store: a snapshot lists secret keys only.
+type Snapshot struct {
+	Version     int            `json:"version"`
+	Environment string         `json:"environment"`
+	WorkflowID  string         `json:"workflow_id"`
+	ExportedAt  time.Time      `json:"exported_at"`
+	Variables   map[string]any `json:"variables"`
+	Secrets     []string       `json:"secrets"`
+	Signature   string         `json:"signature,omitempty"`
+}
+
+// NewSnapshot exports env as an unsigned snapshot.
+func NewSnapshot(env *Environment, now time.Time) *Snapshot {
+	vars := maps.Clone(env.Config)
+	if vars == nil {
+		vars = map[string]any{}
+	}
+	return &Snapshot{
+		Version:     SnapshotVersion,
+		Environment: env.Name,
+		WorkflowID:  env.WorkflowID,
+		ExportedAt:  now.UTC(),
+		Variables:   vars,
+		Secrets:     slices.Sorted(maps.Keys(env.Secrets)),
+	}
+}
+
+// Sign sets the snapshot's HMAC-SHA256 signature using key.
+func (s *Snapshot) Sign(key []byte) error {
+	sig, err := s.signature(key)
+	if err != nil {
+		return err
+	}
+	s.Signature = sig
+	return nil
+}
+
+// Verify checks the snapshot's signature against key.
+func (s *Snapshot) Verify(key []byte) error {
+	if s.Signature == "" {
+		return fmt.Errorf("%w: snapshot is not signed", ErrInvalidSignature)
+	}
+	want, err := s.signature(key)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(want), []byte(s.Signature)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// signature computes the HMAC over the snapshot's JSON encoding without its
+// signature field. encoding/json sorts map keys, so the encoding is stable.
+func (s *Snapshot) signature(key []byte) (string, error) {
+	unsigned := *s
+	unsigned.Signature = ""
+	data, err := json.Marshal(unsigned)
+	if err != nil {
+		return "", fmt.Errorf("encode snapshot: %w", err)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// Change is one key that differs between a snapshot and an environment. From
+// and To are only set for variables; secret changes carry the key alone.
+type Change struct {
+	Key  string `json:"key"`
+	Op   string `json:"op"`
+	From any    `json:"from,omitempty"`
+	To   any    `json:"to,omitempty"`
+}
+
+// SnapshotDiff lists what applying a snapshot would change in an environment.
+type SnapshotDiff struct {
+	Source    string   `json:"source"`
+	Target    string   `json:"target"`
+	Variables []Change `json:"variables"`
+	Secrets   []Change `json:"secrets"`
+}
+
+// DiffSnapshot compares snapshot s against the target environment. Secrets
+// are compared by key only: a secret is added when the snapshot lists a key
+// the target lacks and removed when the target holds a key the snapshot does
+// not.
+func DiffSnapshot(s *Snapshot, target *Environment) *SnapshotDiff {
+	d := &SnapshotDiff{Source: s.Environment, Target: target.Name, Variables: []Change{}, Secrets: []Change{}}
+	for _, key := range slices.Sorted(maps.Keys(s.Variables)) {
+		to := s.Variables[key]
+		from, ok := target.Config[key]
+		switch {
+		case !ok:
+			d.Variables = append(d.Variables, Change{Key: key, Op: ChangeAdded, To: to})
+		case !reflect.DeepEqual(from, to):
+			d.Variables = append(d.Variables, Change{Key: key, Op: ChangeChanged, From: from, To: to})
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(target.Config)) {
+		if _, ok := s.Variables[key]; !ok {
+			d.Variables = append(d.Variables, Change{Key: key, Op: ChangeRemoved, From: target.Config[key]})
+		}
+	}
+	for _, key := range s.Secrets {
+		if _, ok := target.Secrets[key]; !ok {
+			d.Secrets = append(d.Secrets, Change{Key: key, Op: ChangeAdded})
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(target.Secrets)) {
+		if !slices.Contains(s.Secrets, key) {
+			d.Secrets = append(d.Secrets, Change{Key: key, Op: ChangeRemoved})
+		}
+	}
+	return d
+}
+
+// MissingSecrets returns the secret keys the snapshot needs that the target
+// does not hold yet. Their values must be supplied when the snapshot is
+// applied.
+func (d *SnapshotDiff) MissingSecrets() []string {
+	var keys []string
+	for _, c := range d.Secrets {
+		if c.Op == ChangeAdded {
+			keys = append(keys, c.Key)
+		}
+	}
+	return keys
+}
+
+// ApplySnapshot writes the snapshot's variables into env and sets the given
+// secret values, which must only name secrets listed in the snapshot. With
+// prune, variables and secrets the snapshot does not contain are removed.
+// It returns the variable and secret keys that changed.
+func ApplySnapshot(env *Environment, s *Snapshot, secrets map[string]string, prune bool) (changedVars, changedSecrets []string) {
+	diff := DiffSnapshot(s, env)
+	if env.Config == nil {
+		env.Config = map[string]any{}
+	}
+	if env.Secrets == nil {
+		env.Secrets = map[string]string{}
+	}
+	for _, c := range diff.Variables {
+		if c.Op == ChangeRemoved {
+			if !prune {
+				continue
+			}
+			delete(env.Config, c.Key)
+		} else {
+			env.Config[c.Key] = c.To
+		}
+		changedVars = append(changedVars, c.Key)
+	}
+	if prune {
+		for _, c := range diff.Secrets {
+			if c.Op == ChangeRemoved {
+				delete(env.Secrets, c.Key)
+				changedSecrets = append(changedSecrets, c.Key)
+			}
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(secrets)) {
+		if env.Secrets[key] != secrets[key] {
+			env.Secrets[key] = secrets[key]
+			changedSecrets = append(changedSecrets, key)
+		}
+	}
+	slices.Sort(changedVars)
+	slices.Sort(changedSecrets)
+	return changedVars, changedSecrets
+}
@@ -0,0 +1,223 @@
+package environment
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/audit"
+)
+
+// PromoteRequest is the body of POST /api/v1/admin/environments/{id}/promote.
+type PromoteRequest struct {
+	Snapshot Snapshot `json:"snapshot"`
+	// Confirm applies the snapshot. Without it the call only previews the
+	// changes, so a client can show them before asking for confirmation.
+	Confirm bool `json:"confirm"`
+	// Prune removes variables and secrets the snapshot does not contain.
+	Prune bool `json:"prune,omitempty"`
+	// Secrets supplies values for snapshot secrets, typically the ones the
+	// target is missing. A value may be a secret:// reference.
+	Secrets map[string]string `json:"secrets,omitempty"`
+	// ReferenceMissingSecrets stores secret://<key> for every missing secret
+	// that has no supplied value, deferring it to the secrets provider.
+	ReferenceMissingSecrets bool `json:"reference_missing_secrets,omitempty"`
+}
+
+// PromoteResult is the response of a promote call.
+type PromoteResult struct {
+	Applied        bool          `json:"applied"`
+	Diff           *SnapshotDiff `json:"diff"`
+	MissingSecrets []string      `json:"missing_secrets"`
+	ChangedKeys    []string      `json:"changed_keys,omitempty"`
+}
+
+// ---------- GET /api/v1/admin/environments/{id}/export ----------
+
+func (h *Handler) handleExport(w http.ResponseWriter, r *http.Request) {
+	if !h.requireSigningKey(w) {
+		return
+	}
+	env, ok := h.lookup(w, r)
+	if !ok {
+		return
+	}
+	snap := NewSnapshot(env, h.clock())
+	if err := snap.Sign(h.signingKey); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to sign snapshot")
+		return
+	}
+	writeJSON(w, http.StatusOK, snap)
+}
+
+// ---------- POST /api/v1/admin/environments/{id}/diff ----------
+
+func (h *Handler) handleDiff(w http.ResponseWriter, r *http.Request) {
+	if !h.requireSigningKey(w) {
+		return
+	}
+	var snap Snapshot
+	if err := json.NewDecoder(r.Body).Decode(&snap); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := snap.Verify(h.signingKey); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	env, ok := h.lookup(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, DiffSnapshot(&snap, env))
+}
+
+// ---------- POST /api/v1/admin/environments/{id}/promote ----------
+
+func (h *Handler) handlePromote(w http.ResponseWriter, r *http.Request) {
+	if !h.requireSigningKey(w) {
+		return
+	}
+	var req PromoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := req.Snapshot.Verify(h.signingKey); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	for key := range req.Secrets {
+		if !slices.Contains(req.Snapshot.Secrets, key) {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("secret %q is not part of the snapshot", key))
+			return
+		}
+	}
+	env, ok := h.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	diff := DiffSnapshot(&req.Snapshot, env)
+	secrets := maps.Clone(req.Secrets)
+	if secrets == nil {
+		secrets = map[string]string{}
+	}
+	missing := []string{}
+	for _, key := range diff.MissingSecrets() {
+		switch {
+		case secrets[key] != "":
+		case req.ReferenceMissingSecrets:
+			secrets[key] = SecretRefPrefix + key
+		default:
+			missing = append(missing, key)
+		}
+	}
+
+	result := PromoteResult{Diff: diff, MissingSecrets: missing}
+	if !req.Confirm {
+		writeJSON(w, http.StatusOK, result)
+		return
+	}
+	if len(missing) > 0 {
+		writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf(
+			"no value for secrets missing in %q: %s (supply them or set reference_missing_secrets)",
+			env.Name, strings.Join(missing, ", ")))
+		return
+	}
+
+	vars, changedSecrets := ApplySnapshot(env, &req.Snapshot, secrets, req.Prune)
+	actor := h.requestActor(r)
+	if err := h.store.Update(r.Context(), env); err != nil {
+		_ = h.recordPromotion(r, actor, env, &req.Snapshot, vars, changedSecrets, false, "update failed")
+		writeError(w, http.StatusInternalServerError, "failed to update environment")
+		return
+	}
+	if err := h.recordPromotion(r, actor, env, &req.Snapshot, vars, changedSecrets, true, ""); err != nil {
+		writeError(w, http.StatusInternalServerError, "environment updated but the audit record failed: "+err.Error())
+		return
+	}
+
+	result.Applied = true
+	result.ChangedKeys = vars
+	for _, key := range changedSecrets {
+		result.ChangedKeys = append(result.ChangedKeys, "secrets."+key)
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// recordPromotion writes an audit entry listing every key a promotion
+// changed. Secret values are never recorded.
+func (h *Handler) recordPromotion(r *http.Request, actor string, env *Environment, snap *Snapshot, vars, secrets []string, success bool, detail string) error {
+	if h.auditStore == nil {
+		return nil
+	}
+	if detail == "" {
+		detail = fmt.Sprintf("promoted snapshot of %q to %q: %d variables, %d secrets changed",
+			snap.Environment, env.Name, len(vars), len(secrets))
+	}
+	return h.auditStore.Record(r.Context(), audit.Event{
+		Timestamp: h.clock(),
+		Type:      audit.EventConfigChange,
+		Action:    "environment.promote",
+		Actor:     actor,
+		Resource:  "environment/" + env.ID,
+		Detail:    detail,
+		Success:   success,
+		Metadata: map[string]any{
+			"source":             snap.Environment,
+			"target":             env.Name,
+			"snapshot_signature": snap.Signature,
+			"changed_variables":  vars,
+			"changed_secrets":    secrets,
+		},
+	})
+}
+
+// lookup loads the environment named by the {id} path value, writing an
+// error response when it cannot.
+func (h *Handler) lookup(w http.ResponseWriter, r *http.Request) (*Environment, bool) {
+	id := r.PathValue("id")
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "missing environment id")
+		return nil, false
+	}
+	env, err := h.store.Get(r.Context(), id)
+	if err != nil {
+		if isNotFound(err) {
+			writeError(w, http.StatusNotFound, "environment not found")
+			return nil, false
+		}
+		writeError(w, http.StatusInternalServerError, "failed to get environment")
+		return nil, false
+	}
+	return env, true
+}
+
+func (h *Handler) requireSigningKey(w http.ResponseWriter) bool {
+	if len(h.signingKey) == 0 {
+		writeError(w, http.StatusServiceUnavailable, "snapshot signing key is not configured")
+		return false
+	}
+	return true
+}
+
+func (h *Handler) requestActor(r *http.Request) string {
+	if h.actor != nil {
+		if actor := h.actor(r); actor != "" {
+			return actor
+		}
+	}
+	return "unknown"
+}
+
+func (h *Handler) clock() time.Time {
+	if h.now != nil {
+		return h.now().UTC()
+	}
+	return time.Now().UTC()
+}
@@ -0,0 +1,215 @@
+package environment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/audit"
+)
+
+type recordingAuditStore struct{ events []audit.Event }
+
+func (s *recordingAuditStore) Record(_ context.Context, e audit.Event) error {
+	s.events = append(s.events, e)
+	return nil
+}
+
+// setupPromotionServer creates a staging and a production environment and
+// returns a mux whose handler signs snapshots and records audit events.
+func setupPromotionServer(t *testing.T) (mux *http.ServeMux, store Store, auditStore *recordingAuditStore, stagingID, prodID string) {
+	t.Helper()
+	h, mux := setupTestServer(t)
+	auditStore = &recordingAuditStore{}
+	h.SetSigningKey([]byte("test-key"))
+	h.SetAuditStore(auditStore)
+	h.SetActorFunc(func(*http.Request) string { return "alice" })
+
+	staging := &Environment{Name: "staging", WorkflowID: "wf-1", Provider: "aws",
+		Config:  map[string]any{"replicas": float64(2), "log_level": "debug", "feature_x": true},
+		Secrets: map[string]string{"DB_PASSWORD": "staging-pw", "API_TOKEN": "staging-token"}}
+	prod := &Environment{Name: "production", WorkflowID: "wf-1", Provider: "aws",
+		Config:  map[string]any{"replicas": float64(5), "log_level": "debug", "endpoint": "https://prod.example.com"},
+		Secrets: map[string]string{"DB_PASSWORD": "prod-pw", "LEGACY_KEY": "old"}}
+	for _, env := range []*Environment{staging, prod} {
+		if err := h.store.Create(context.Background(), env); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return mux, h.store, auditStore, staging.ID, prod.ID
+}
+
+func doJSON(t *testing.T, mux http.Handler, method, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(method, path, &buf))
+	return w
+}
+
+func exportSnapshot(t *testing.T, mux http.Handler, id string) Snapshot {
+	t.Helper()
+	w := doJSON(t, mux, http.MethodGet, "/api/v1/admin/environments/"+id+"/export", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("export: %d %s", w.Code, w.Body)
+	}
+	if strings.Contains(w.Body.String(), "staging-pw") {
+		t.Fatal("export leaked a secret value")
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(w.Body.Bytes(), &snap); err != nil {
+		t.Fatal(err)
+	}
+	return snap
+}
+
+func TestSnapshotExportAndDiff(t *testing.T) {
+	mux, _, _, stagingID, prodID := setupPromotionServer(t)
+	snap := exportSnapshot(t, mux, stagingID)
+	if snap.Environment != "staging" || snap.Signature == "" || !slices.Equal(snap.Secrets, []string{"API_TOKEN", "DB_PASSWORD"}) {
+		t.Fatalf("snapshot = %+v", snap)
+	}
+
+	w := doJSON(t, mux, http.MethodPost, "/api/v1/admin/environments/"+prodID+"/diff", snap)
+	if w.Code != http.StatusOK {
+		t.Fatalf("diff: %d %s", w.Code, w.Body)
+	}
+	if strings.Contains(w.Body.String(), "-pw") {
+		t.Fatal("diff leaked a secret value")
+	}
+	var diff SnapshotDiff
+	if err := json.Unmarshal(w.Body.Bytes(), &diff); err != nil {
+		t.Fatal(err)
+	}
+	wantVars := []Change{
+		{Key: "feature_x", Op: ChangeAdded, To: true},
+		{Key: "replicas", Op: ChangeChanged, From: float64(5), To: float64(2)},
+		{Key: "endpoint", Op: ChangeRemoved, From: "https://prod.example.com"},
+	}
+	if !slices.Equal(diff.Variables, wantVars) {
+		t.Errorf("variable changes = %+v, want %+v", diff.Variables, wantVars)
+	}
+	wantSecrets := []Change{{Key: "API_TOKEN", Op: ChangeAdded}, {Key: "LEGACY_KEY", Op: ChangeRemoved}}
+	if !slices.Equal(diff.Secrets, wantSecrets) {
+		t.Errorf("secret changes = %+v, want %+v", diff.Secrets, wantSecrets)
+	}
+
+	snap.Variables["replicas"] = float64(50)
+	if w := doJSON(t, mux, http.MethodPost, "/api/v1/admin/environments/"+prodID+"/diff", snap); w.Code != http.StatusBadRequest {
+		t.Errorf("tampered snapshot: got %d, want 400", w.Code)
+	}
+}
+
+func TestSnapshotPromote(t *testing.T) {
+	mux, store, auditStore, stagingID, prodID := setupPromotionServer(t)
+	snap := exportSnapshot(t, mux, stagingID)
+	path := "/api/v1/admin/environments/" + prodID + "/promote"
+
+	// Without confirm the call is a preview and reports the missing secret.
+	w := doJSON(t, mux, http.MethodPost, path, PromoteRequest{Snapshot: snap})
+	var preview PromoteResult
+	if err := json.Unmarshal(w.Body.Bytes(), &preview); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("preview: %d %s", w.Code, w.Body)
+	}
+	if preview.Applied || !slices.Equal(preview.MissingSecrets, []string{"API_TOKEN"}) {
+		t.Fatalf("preview = %+v", preview)
+	}
+
+	// Confirming without a value for the missing secret is refused.
+	if w := doJSON(t, mux, http.MethodPost, path, PromoteRequest{Snapshot: snap, Confirm: true}); w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("confirm without secret: %d %s", w.Code, w.Body)
+	}
+	if w := doJSON(t, mux, http.MethodPost, path, PromoteRequest{Snapshot: snap, Confirm: true, Secrets: map[string]string{"OTHER": "x"}}); w.Code != http.StatusBadRequest {
+		t.Fatalf("secret outside snapshot: %d %s", w.Code, w.Body)
+	}
+	if len(auditStore.events) != 0 {
+		t.Fatalf("refused promotions were audited: %+v", auditStore.events)
+	}
+
+	w = doJSON(t, mux, http.MethodPost, path, PromoteRequest{Snapshot: snap, Confirm: true, ReferenceMissingSecrets: true})
+	var result PromoteResult
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("promote: %d %s", w.Code, w.Body)
+	}
+	wantKeys := []string{"feature_x", "replicas", "secrets.API_TOKEN"}
+	if !result.Applied || !slices.Equal(result.ChangedKeys, wantKeys) {
+		t.Errorf("result = %+v, want changed keys %v", result, wantKeys)
+	}
+
+	prod, err := store.Get(context.Background(), prodID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Without prune, keys only the target has are kept.
+	if prod.Config["replicas"] != float64(2) || prod.Config["endpoint"] == nil || prod.Secrets["LEGACY_KEY"] != "old" {
+		t.Errorf("config = %v, secrets = %v", prod.Config, prod.Secrets)
+	}
+	if prod.Secrets["API_TOKEN"] != "secret://API_TOKEN" || prod.Secrets["DB_PASSWORD"] != "prod-pw" {
+		t.Errorf("secrets = %v, want a reference for API_TOKEN and DB_PASSWORD untouched", prod.Secrets)
+	}
+
+	if len(auditStore.events) != 1 {
+		t.Fatalf("audit events = %d, want 1", len(auditStore.events))
+	}
+	ev := auditStore.events[0]
+	if ev.Action != "environment.promote" || ev.Actor != "alice" || !ev.Success {
+		t.Errorf("audit event = %+v", ev)
+	}
+	if vars, _ := ev.Metadata["changed_variables"].([]string); !slices.Equal(vars, []string{"feature_x", "replicas"}) {
+		t.Errorf("audited variables = %v", ev.Metadata["changed_variables"])
+	}
+	if secrets, _ := ev.Metadata["changed_secrets"].([]string); !slices.Equal(secrets, []string{"API_TOKEN"}) {
+		t.Errorf("audited secrets = %v", ev.Metadata["changed_secrets"])
+	}
+}
+
+func TestApplySnapshotPrune(t *testing.T) {
+	env := &Environment{Name: "production",
+		Config:  map[string]any{"a": 1, "stale": true},
+		Secrets: map[string]string{"KEEP": "v", "OLD": "x"}}
+	snap := &Snapshot{Variables: map[string]any{"a": 2}, Secrets: []string{"KEEP", "NEW"}}
+
+	vars, secrets := ApplySnapshot(env, snap, map[string]string{"NEW": "n"}, true)
+	if !slices.Equal(vars, []string{"a", "stale"}) || !slices.Equal(secrets, []string{"NEW", "OLD"}) {
+		t.Errorf("changed = %v, %v", vars, secrets)
+	}
+	if _, ok := env.Config["stale"]; ok || env.Config["a"] != 2 {
+		t.Errorf("config = %v", env.Config)
+	}
+	if _, ok := env.Secrets["OLD"]; ok || env.Secrets["NEW"] != "n" || env.Secrets["KEEP"] != "v" {
+		t.Errorf("secrets = %v", env.Secrets)
+	}
+}
+
+func TestSnapshotSignature(t *testing.T) {
+	snap := NewSnapshot(&Environment{Name: "staging", Config: map[string]any{"a": "b"}}, time.Now())
+	if err := snap.Verify([]byte("k")); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("unsigned snapshot: err = %v", err)
+	}
+	if err := snap.Sign([]byte("k")); err != nil {
+		t.Fatal(err)
+	}
+	if err := snap.Verify([]byte("k")); err != nil {
+		t.Errorf("verify with signing key: %v", err)
+	}
+	if err := snap.Verify([]byte("other")); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("verify with other key: err = %v", err)
+	}
+
+	_, mux := setupTestServer(t)
+	if w := doJSON(t, mux, http.MethodGet, "/api/v1/admin/environments/x/export", nil); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("export without key: got %d, want 503", w.Code)
+	}
+}
@@ -67,6 +67,11 @@ func (s *SQLiteStore) Close() error {
 	return s.db.Close()
 }
 
+// DB returns the underlying *sql.DB connection.
+func (s *SQLiteStore) DB() *sql.DB {
+	return s.db
+}
+
 // Create inserts a new environment. A UUID is generated for ID, and
 // CreatedAt/UpdatedAt are set to now.
 func (s *SQLiteStore) Create(ctx context.Context, env *Environment) error {
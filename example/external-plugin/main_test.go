@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/module"
+	"github.com/GoCodeAlone/workflow/plugin/external"
+)
+
+// TestMain serves the plugin when the test binary is launched by the plugin
+// manager, so the tests below can run it as a real plugin process.
+func TestMain(m *testing.M) {
+	if os.Getenv(external.MagicCookieKey) == external.MagicCookieValue {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// installTestPlugin lays out a plugins directory whose uppercase-plugin
+// binary is this test binary.
+func installTestPlugin(t *testing.T) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "uppercase-plugin")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	self, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := os.Open(self)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(filepath.Join(dir, "uppercase-plugin"), os.O_CREATE|os.O_WRONLY, 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		t.Fatal(err)
+	}
+	if err := dst.Close(); err != nil {
+		t.Fatal(err)
+	}
+	manifest, _ := json.Marshal(map[string]any{
+		"name":        "uppercase-plugin",
+		"version":     "0.1.0",
+		"author":      "workflow-examples",
+		"description": "Example plugin that provides a step to uppercase strings",
+	})
+	if err := os.WriteFile(filepath.Join(dir, "plugin.json"), manifest, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Dir(dir)
+}
+
+func executeUppercaseStep(step module.PipelineStep, input string) (string, error) {
+	res, err := step.Execute(context.Background(), module.NewPipelineContext(map[string]any{"value": input}, nil))
+	if err != nil {
+		return "", err
+	}
+	out, _ := res.Output["value"].(string)
+	return out, nil
+}
+
+func TestUppercasePluginRecoversAfterKill(t *testing.T) {
+	manager := external.NewExternalPluginManager(installTestPlugin(t), log.New(io.Discard, "", 0))
+	manager.SetResilienceOptions(external.ResilienceOptions{
+		HealthInterval: 50 * time.Millisecond,
+		RestartBackoff: 50 * time.Millisecond,
+	})
+	defer manager.Shutdown()
+
+	adapter, err := manager.LoadPlugin("uppercase-plugin")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	raw, err := adapter.StepFactories()["step.uppercase"]("upper", nil, nil)
+	if err != nil {
+		t.Fatalf("create step: %v", err)
+	}
+	step := raw.(module.PipelineStep)
+	if out, err := executeUppercaseStep(step, "hello"); err != nil || out != "HELLO" {
+		t.Fatalf("execute: %q, %v", out, err)
+	}
+
+	before, _ := manager.PluginHealth("uppercase-plugin")
+	if before.PID == 0 {
+		t.Fatal("plugin health reports no process ID")
+	}
+	if err := syscall.Kill(before.PID, syscall.SIGKILL); err != nil {
+		t.Fatalf("kill plugin process: %v", err)
+	}
+
+	var after *external.PluginHealth
+	deadline := time.Now().Add(20 * time.Second)
+	for time.Now().Before(deadline) {
+		after, _ = manager.PluginHealth("uppercase-plugin")
+		if after.Restarts == 1 && after.State == external.PluginHealthy {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if after.Restarts != 1 || after.State != external.PluginHealthy || after.PID == before.PID {
+		t.Fatalf("plugin did not recover: %+v", after)
+	}
+
+	if out, err := executeUppercaseStep(step, "again"); err != nil || out != "AGAIN" {
+		t.Fatalf("execute after restart: %q, %v", out, err)
+	}
+}
@@ -0,0 +1,195 @@
+package featureflag
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RemoteStreamPath is the admin server's flag change stream that remote
+// engines subscribe to.
+const RemoteStreamPath = "/api/v1/admin/feature-flags/stream"
+
+// RemoteConfig configures a RemoteSubscriber.
+type RemoteConfig struct {
+	// AdminURL is the admin server base URL (e.g. WORKFLOW_ADMIN_URL).
+	AdminURL string
+	// Token is sent as a bearer token when set.
+	Token string
+	// Engine names this engine in the admin's list of subscribed engines.
+	Engine string
+	// RetryInterval is the base delay between reconnect attempts while the
+	// stream is down; each wait is jittered between half and one and a half
+	// times this value. Defaults to 30s.
+	RetryInterval time.Duration
+	// Client is the HTTP client used for the stream. It must not set a
+	// response timeout. Defaults to a client without one.
+	Client *http.Client
+}
+
+// RemoteSubscriber keeps a Service's cache in step with flag changes made on
+// an admin server. It follows the admin's SSE stream and invalidates each
+// changed flag as soon as its event arrives.
+//
+// While the stream is down, cached values expire after the cache TTL as they
+// would without a subscriber, and the subscriber retries the connection at a
+// jittered interval. After every (re)connect the whole cache is flushed, since
+// events may have been missed.
+type RemoteSubscriber struct {
+	service *Service
+	cfg     RemoteConfig
+	logger  *slog.Logger
+
+	connected atomic.Bool
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewRemoteSubscriber creates a subscriber that applies the admin's flag
+// changes to service.
+func NewRemoteSubscriber(service *Service, cfg RemoteConfig, logger *slog.Logger) *RemoteSubscriber {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if cfg.RetryInterval <= 0 {
+		cfg.RetryInterval = 30 * time.Second
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{}
+	}
+	return &RemoteSubscriber{service: service, cfg: cfg, logger: logger}
+}
+
+// Start connects to the admin stream in the background. It is a no-op if the
+// subscriber is already running.
+func (r *RemoteSubscriber) Start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cancel != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+	go r.run(ctx, r.done)
+}
+
+// Stop disconnects from the admin stream and waits for the background
+// goroutine to exit.
+func (r *RemoteSubscriber) Stop() {
+	r.mu.Lock()
+	cancel, done := r.cancel, r.done
+	r.cancel, r.done = nil, nil
+	r.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// Connected reports whether the subscriber currently holds an open stream.
+func (r *RemoteSubscriber) Connected() bool {
+	return r.connected.Load()
+}
+
+func (r *RemoteSubscriber) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	for {
+		err := r.follow(ctx)
+		r.connected.Store(false)
+		if ctx.Err() != nil {
+			return
+		}
+		wait := r.retryDelay()
+		r.logger.Warn("Feature flag stream disconnected; falling back to cache TTL",
+			"admin_url", r.cfg.AdminURL, "error", err, "retry_in", wait)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// retryDelay returns RetryInterval jittered by ±50% so that engines
+// disconnected together do not reconnect together.
+func (r *RemoteSubscriber) retryDelay() time.Duration {
+	base := r.cfg.RetryInterval
+	return base/2 + time.Duration(rand.Int64N(int64(base)+1)) //nolint:gosec // G404: jitter, not security
+}
+
+// follow holds one stream connection until it fails or ctx is cancelled.
+func (r *RemoteSubscriber) follow(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	streamURL := strings.TrimRight(r.cfg.AdminURL, "/") + RemoteStreamPath + "?engine=" + url.QueryEscape(r.cfg.Engine)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, streamURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if r.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.cfg.Token)
+	}
+	resp, err := r.cfg.Client.Do(req) //nolint:gosec // G704: URL from configured admin endpoint
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("stream returned status %d", resp.StatusCode)
+	}
+
+	r.service.FlushCache()
+	r.connected.Store(true)
+	r.logger.Info("Following feature flag changes", "admin_url", r.cfg.AdminURL, "engine", r.cfg.Engine)
+
+	// The admin writes a keepalive every sseKeepAlive; a stream silent for
+	// three intervals is treated as dead.
+	watchdog := time.AfterFunc(3*sseKeepAlive, cancel)
+	defer watchdog.Stop()
+
+	var event, data string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		watchdog.Reset(3 * sseKeepAlive)
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if event == "flag.updated" && data != "" {
+				r.apply(data)
+			}
+			event, data = "", ""
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data += strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("stream closed")
+}
+
+func (r *RemoteSubscriber) apply(data string) {
+	var evt FlagChangeEvent
+	if err := json.Unmarshal([]byte(data), &evt); err != nil {
+		r.logger.Warn("Invalid feature flag event from admin", "error", err)
+		return
+	}
+	r.service.ApplyRemoteChange(evt)
+}
@@ -0,0 +1,119 @@
+package featureflag_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/featureflag"
+)
+
+// newRemotePair starts an "admin" service serving the flag stream and an
+// "engine" service following it. Both providers stand in for a shared flag
+// store: tests change the engine's provider and notify through the admin.
+func newRemotePair(t *testing.T, down *atomic.Bool) (admin *featureflag.Service, engine *featureflag.Service, engineProvider *stubProvider, sub *featureflag.RemoteSubscriber, srv *httptest.Server) {
+	t.Helper()
+	admin = featureflag.NewService(newStubProvider("admin"), featureflag.NewFlagCache(0), nil)
+
+	engineProvider = newStubProvider("generic")
+	engineProvider.flags["checkout-v2"] = featureflag.FlagValue{Key: "checkout-v2", Value: true, Type: featureflag.FlagTypeBoolean}
+	engine = featureflag.NewService(engineProvider, featureflag.NewFlagCache(time.Hour), nil)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+featureflag.RemoteStreamPath, func(w http.ResponseWriter, r *http.Request) {
+		if down != nil && down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		admin.SSEHandler().ServeHTTP(w, r)
+	})
+	srv = httptest.NewServer(mux)
+
+	sub = featureflag.NewRemoteSubscriber(engine, featureflag.RemoteConfig{
+		AdminURL:      srv.URL,
+		Engine:        "engine-b",
+		RetryInterval: 50 * time.Millisecond,
+	}, nil)
+	sub.Start()
+	t.Cleanup(func() {
+		sub.Stop()
+		srv.Close()
+	})
+	return admin, engine, engineProvider, sub, srv
+}
+
+func waitFor(t *testing.T, timeout time.Duration, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before timeout")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func evalBool(t *testing.T, svc *featureflag.Service) any {
+	t.Helper()
+	v, err := svc.Evaluate(context.Background(), "checkout-v2", featureflag.EvaluationContext{UserKey: "u1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v.Value
+}
+
+func TestRemoteSubscriberPropagatesChanges(t *testing.T) {
+	admin, engine, engineProvider, sub, _ := newRemotePair(t, nil)
+	waitFor(t, 2*time.Second, sub.Connected)
+	waitFor(t, 2*time.Second, func() bool { return len(admin.Engines()) == 1 })
+	if got := admin.Engines()[0].Engine; got != "engine-b" {
+		t.Errorf("subscribed engine = %q, want engine-b", got)
+	}
+
+	// Prime the engine's cache, then change the flag in the shared store.
+	if v := evalBool(t, engine); v != true {
+		t.Fatalf("initial value = %v, want true", v)
+	}
+	engineProvider.flags["checkout-v2"] = featureflag.FlagValue{Key: "checkout-v2", Value: false, Type: featureflag.FlagTypeBoolean}
+	if v := evalBool(t, engine); v != true {
+		t.Fatalf("value before notification = %v, want cached true", v)
+	}
+
+	start := time.Now()
+	admin.NotifyChange(featureflag.FlagChangeEvent{Key: "checkout-v2", Value: false, Type: featureflag.FlagTypeBoolean, Source: "admin"})
+	waitFor(t, time.Second, func() bool { return evalBool(t, engine) == false })
+	if latency := time.Since(start); latency > 500*time.Millisecond {
+		t.Errorf("propagation took %v, want under 500ms", latency)
+	}
+
+	sub.Stop()
+	waitFor(t, 2*time.Second, func() bool { return len(admin.Engines()) == 0 })
+}
+
+func TestRemoteSubscriberReconnectFlushesCache(t *testing.T) {
+	var down atomic.Bool
+	_, engine, engineProvider, sub, srv := newRemotePair(t, &down)
+	waitFor(t, 2*time.Second, sub.Connected)
+	if v := evalBool(t, engine); v != true {
+		t.Fatalf("initial value = %v, want true", v)
+	}
+
+	// The admin goes away; a change made meanwhile produces no event.
+	down.Store(true)
+	srv.CloseClientConnections()
+	waitFor(t, 2*time.Second, func() bool { return !sub.Connected() })
+	engineProvider.flags["checkout-v2"] = featureflag.FlagValue{Key: "checkout-v2", Value: false, Type: featureflag.FlagTypeBoolean}
+	if v := evalBool(t, engine); v != true {
+		t.Fatalf("value while disconnected = %v, want cached true until TTL", v)
+	}
+
+	// On reconnect the cache is flushed, so the missed change is picked up.
+	down.Store(false)
+	waitFor(t, 2*time.Second, sub.Connected)
+	if v := evalBool(t, engine); v != false {
+		t.Errorf("value after reconnect = %v, want false", v)
+	}
+}
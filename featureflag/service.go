@@ -6,10 +6,17 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"sort"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
+// sseKeepAlive is how often SSEHandler writes a comment line so that idle
+// connections are kept open by proxies and remote engines can detect a dead
+// stream.
+var sseKeepAlive = 15 * time.Second
+
 // Service is a caching proxy that sits between consumers and a Provider.
 // It adds an in-memory cache, SSE broadcasting, and audit logging.
 type Service struct {
@@ -19,10 +26,26 @@ type Service struct {
 
 	// SSE broadcaster
 	mu          sync.RWMutex
-	subscribers map[uint64]chan FlagChangeEvent
+	subscribers map[uint64]*sseSubscriber
 	nextID      atomic.Uint64
 }
 
+// sseSubscriber is one connected SSE client. Engine is set when the client is
+// a remote workflow engine (it connected with ?engine=<name>).
+type sseSubscriber struct {
+	ch          chan FlagChangeEvent
+	engine      string
+	remoteAddr  string
+	connectedAt time.Time
+}
+
+// EngineSubscription describes a remote engine following the SSE stream.
+type EngineSubscription struct {
+	Engine      string    `json:"engine"`
+	RemoteAddr  string    `json:"remote_addr"`
+	ConnectedAt time.Time `json:"connected_at"`
+}
+
 // NewService creates a Service wrapping the given provider and cache.
 func NewService(provider Provider, cache *FlagCache, logger *slog.Logger) *Service {
 	if logger == nil {
@@ -32,22 +55,44 @@ func NewService(provider Provider, cache *FlagCache, logger *slog.Logger) *Servi
 		provider:    provider,
 		cache:       cache,
 		logger:      logger,
-		subscribers: make(map[uint64]chan FlagChangeEvent),
+		subscribers: make(map[uint64]*sseSubscriber),
 	}
 
 	// Forward provider change events to the SSE broadcaster and invalidate cache.
-	provider.Subscribe(func(evt FlagChangeEvent) {
-		s.cache.InvalidateFlag(evt.Key)
-		s.broadcast(evt)
-		s.logger.Info("flag changed",
-			"key", evt.Key,
-			"source", evt.Source,
-		)
-	})
+	provider.Subscribe(s.NotifyChange)
 
 	return s
 }
 
+// NotifyChange invalidates the cached values of the changed flag and
+// broadcasts the event to all SSE subscribers, including remote engines.
+// Providers' change events are routed here; it should also be called after a
+// flag is changed outside the provider (e.g. directly in its store).
+func (s *Service) NotifyChange(evt FlagChangeEvent) {
+	s.cache.InvalidateFlag(evt.Key)
+	s.broadcast(evt, true)
+	s.logger.Info("flag changed",
+		"key", evt.Key,
+		"source", evt.Source,
+	)
+}
+
+// ApplyRemoteChange applies a change event received from another engine's
+// SSE stream: the flag's cached values are invalidated and the event is
+// relayed to local SSE clients, but not to remote engines, so engines that
+// follow each other cannot loop an event.
+func (s *Service) ApplyRemoteChange(evt FlagChangeEvent) {
+	s.cache.InvalidateFlag(evt.Key)
+	s.broadcast(evt, false)
+	s.logger.Debug("remote flag change applied", "key", evt.Key, "source", evt.Source)
+}
+
+// FlushCache drops every cached flag value. Remote subscribers call it after
+// reconnecting, since events may have been missed while disconnected.
+func (s *Service) FlushCache() {
+	s.cache.Flush()
+}
+
 // Evaluate returns the flag value for the given key. Cache is checked first;
 // on a miss the provider is queried and the result is cached.
 func (s *Service) Evaluate(ctx context.Context, key string, evalCtx EvaluationContext) (FlagValue, error) {
@@ -72,13 +117,17 @@ func (s *Service) AllFlags(ctx context.Context, evalCtx EvaluationContext) ([]Fl
 }
 
 // broadcast sends a change event to all SSE subscribers (non-blocking).
-func (s *Service) broadcast(evt FlagChangeEvent) {
+// Remote engines are skipped unless toEngines is set.
+func (s *Service) broadcast(evt FlagChangeEvent, toEngines bool) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	for id, ch := range s.subscribers {
+	for id, sub := range s.subscribers {
+		if sub.engine != "" && !toEngines {
+			continue
+		}
 		select {
-		case ch <- evt:
+		case sub.ch <- evt:
 		default:
 			s.logger.Warn("SSE flag event dropped for slow subscriber", "subscriber_id", id, "key", evt.Key)
 		}
@@ -86,12 +135,12 @@ func (s *Service) broadcast(evt FlagChangeEvent) {
 }
 
 // subscribe registers a new SSE subscriber. Returns the channel and an unsubscribe function.
-func (s *Service) subscribe() (<-chan FlagChangeEvent, func()) {
+func (s *Service) subscribe(engine, remoteAddr string) (<-chan FlagChangeEvent, func()) {
 	ch := make(chan FlagChangeEvent, 64)
 	id := s.nextID.Add(1)
 
 	s.mu.Lock()
-	s.subscribers[id] = ch
+	s.subscribers[id] = &sseSubscriber{ch: ch, engine: engine, remoteAddr: remoteAddr, connectedAt: time.Now()}
 	s.mu.Unlock()
 
 	unsub := sync.Once{}
@@ -110,6 +159,10 @@ func (s *Service) subscribe() (<-chan FlagChangeEvent, func()) {
 //
 //	event: flag.updated
 //	data: {"key":"my-flag","value":true,"type":"boolean","source":"generic"}
+//
+// Remote workflow engines identify themselves with an ?engine=<name> query
+// parameter and are listed by Engines. A ": keepalive" comment is written
+// every 15 seconds.
 func (s *Service) SSEHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		flusher, ok := w.(http.Flusher)
@@ -118,6 +171,12 @@ func (s *Service) SSEHandler() http.HandlerFunc {
 			return
 		}
 
+		// Subscribe before the headers go out so that a client that saw
+		// the response cannot miss a later event.
+		engine := r.URL.Query().Get("engine")
+		ch, unsubscribe := s.subscribe(engine, r.RemoteAddr)
+		defer unsubscribe()
+
 		w.Header().Set("Content-Type", "text/event-stream")
 		w.Header().Set("Cache-Control", "no-cache")
 		w.Header().Set("Connection", "keep-alive")
@@ -125,18 +184,20 @@ func (s *Service) SSEHandler() http.HandlerFunc {
 		w.WriteHeader(http.StatusOK)
 		flusher.Flush()
 
-		ch, unsubscribe := s.subscribe()
-		defer unsubscribe()
-
 		ctx := r.Context()
+		keepAlive := time.NewTicker(sseKeepAlive)
+		defer keepAlive.Stop()
 
-		s.logger.Info("SSE flag client connected", "remote_addr", r.RemoteAddr)
+		s.logger.Info("SSE flag client connected", "remote_addr", r.RemoteAddr, "engine", engine)
 
 		for {
 			select {
 			case <-ctx.Done():
-				s.logger.Info("SSE flag client disconnected", "remote_addr", r.RemoteAddr)
+				s.logger.Info("SSE flag client disconnected", "remote_addr", r.RemoteAddr, "engine", engine)
 				return
+			case <-keepAlive.C:
+				fmt.Fprint(w, ": keepalive\n\n")
+				flusher.Flush()
 			case evt, open := <-ch:
 				if !open {
 					return
@@ -160,3 +221,24 @@ func (s *Service) SubscriberCount() int {
 	s.mu.RUnlock()
 	return n
 }
+
+// Engines returns the remote engines currently following the SSE stream,
+// ordered by engine name.
+func (s *Service) Engines() []EngineSubscription {
+	s.mu.RLock()
+	out := make([]EngineSubscription, 0, len(s.subscribers))
+	for _, sub := range s.subscribers {
+		if sub.engine == "" {
+			continue
+		}
+		out = append(out, EngineSubscription{Engine: sub.engine, RemoteAddr: sub.remoteAddr, ConnectedAt: sub.connectedAt})
+	}
+	s.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Engine != out[j].Engine {
+			return out[i].Engine < out[j].Engine
+		}
+		return out[i].ConnectedAt.Before(out[j].ConnectedAt)
+	})
+	return out
+}
@@ -16,6 +16,14 @@ type HTTPRouteConfig struct {
 	Handler     string         `json:"handler" yaml:"handler"`
 	Middlewares []string       `json:"middlewares,omitempty" yaml:"middlewares,omitempty"`
 	Config      map[string]any `json:"config,omitempty" yaml:"config,omitempty"`
+	// BodyLimits (maxRequestBytes, maxResponseBytes) override the server's
+	// body limits for this route.
+	workflowmodule.BodyLimits `yaml:",inline"`
+	// InFlightLimits (maxInFlight, maxQueued, queueTimeout, retryAfter) limit
+	// concurrent requests to this route.
+	workflowmodule.InFlightLimits `yaml:",inline"`
+	// Enabled set to false skips the route; see config.ApplyEnabled.
+	Enabled *bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
 }
 
 // HTTPWorkflowHandler handles HTTP-based workflows
@@ -160,6 +168,29 @@ func (h *HTTPWorkflowHandler) ConfigureWorkflow(app modular.Application, workflo
 			}
 		}
 
+		if limits := workflowmodule.BodyLimitsFromConfig(routeMap); !limits.IsZero() {
+			if stdRouter, ok := router.(*workflowmodule.StandardHTTPRouter); ok {
+				stdRouter.SetRouteBodyLimits(method, path, limits)
+			}
+		}
+		if limits := workflowmodule.InFlightLimitsFromConfig(routeMap); !limits.IsZero() {
+			if stdRouter, ok := router.(*workflowmodule.StandardHTTPRouter); ok {
+				stdRouter.SetRouteInFlightLimits(method, path, limits)
+			}
+		}
+
+		cors, err := workflowmodule.CORSPolicyFromConfig(routeMap["cors"], method)
+		if err != nil {
+			return fmt.Errorf("route %s %s: %w", method, path, err)
+		}
+		if cors != nil {
+			stdRouter, ok := router.(*workflowmodule.StandardHTTPRouter)
+			if !ok {
+				return fmt.Errorf("route %s %s: router does not support per-route cors", method, path)
+			}
+			stdRouter.SetRouteCORSPolicy(method, path, *cors)
+		}
+
 		// Add route to router with middleware if any
 		if stdRouter, ok := router.(*workflowmodule.StandardHTTPRouter); ok && len(middlewares) > 0 {
 			stdRouter.AddRouteWithMiddleware(method, path, httpHandler, middlewares)
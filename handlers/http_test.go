@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"context"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	workflowmodule "github.com/GoCodeAlone/workflow/module"
@@ -333,3 +335,43 @@ func (s *mockHTTPServer) Start(ctx context.Context) error {
 func (s *mockHTTPServer) Stop(ctx context.Context) error {
 	return nil
 }
+
+func TestHTTPWorkflowHandler_ConfigureWorkflow_RouteCORS(t *testing.T) {
+	configure := func(cors map[string]any) (*workflowmodule.StandardHTTPRouter, error) {
+		app := NewTestServiceRegistry()
+		router := workflowmodule.NewStandardHTTPRouter("router")
+		app.services["router"] = router
+		app.services["server"] = &mockHTTPServer{}
+		app.services["handler"] = workflowmodule.NewSimpleHTTPHandler("handler", "application/json")
+		err := NewHTTPWorkflowHandler().ConfigureWorkflow(app, map[string]any{
+			"routes": []any{map[string]any{
+				"method":  "POST",
+				"path":    "/internal/jobs",
+				"handler": "handler",
+				"cors":    cors,
+			}},
+		})
+		return router, err
+	}
+
+	if _, err := configure(map[string]any{"allowedOrigins": []any{"*"}, "allowCredentials": true}); err == nil ||
+		!strings.Contains(err.Error(), "route POST /internal/jobs: cors:") {
+		t.Errorf("expected cors error, got %v", err)
+	}
+
+	router, err := configure(map[string]any{"allowedOrigins": []any{"https://admin.example.com"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := router.Start(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("OPTIONS", "/internal/jobs", nil)
+	req.Header.Set("Origin", "https://admin.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Header().Get("Access-Control-Allow-Origin") != "https://admin.example.com" || w.Header().Get("Access-Control-Allow-Methods") != "POST" {
+		t.Errorf("preflight headers = %v", w.Header())
+	}
+}
@@ -5,9 +5,16 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+	"sync/atomic"
 
 	"github.com/GoCodeAlone/modular"
 	"github.com/GoCodeAlone/workflow/interfaces"
+	"github.com/GoCodeAlone/workflow/module"
+)
+
+// Standard handler name constants
+const (
+	PipelineWorkflowHandlerName = "workflow.handler.pipeline"
 )
 
 // PipelineWorkflowHandler manages and executes pipeline-based workflows.
@@ -16,6 +23,8 @@ type PipelineWorkflowHandler struct {
 	stepRegistry  interfaces.StepRegistryProvider
 	logger        *slog.Logger
 	eventRecorder interfaces.EventRecorder
+	// inFlight counts ExecuteWorkflow calls that have not returned.
+	inFlight atomic.Int64
 }
 
 // NewPipelineWorkflowHandler creates a new PipelineWorkflowHandler.
@@ -25,6 +34,21 @@ func NewPipelineWorkflowHandler() *PipelineWorkflowHandler {
 	}
 }
 
+// Name returns the name of this handler
+func (h *PipelineWorkflowHandler) Name() string {
+	return PipelineWorkflowHandlerName
+}
+
+// ResourceUsage reports the registered pipelines and the executions running
+// through ExecuteWorkflow. Pipelines run outside the handler, such as
+// route pipelines, are not counted.
+func (h *PipelineWorkflowHandler) ResourceUsage() module.ResourceUsage {
+	return module.ResourceUsage{
+		"pipelines":             float64(len(h.pipelines)),
+		module.ResourceInFlight: float64(h.inFlight.Load()),
+	}
+}
+
 // SetStepRegistry sets the step registry used to create pipeline steps.
 func (h *PipelineWorkflowHandler) SetStepRegistry(registry interfaces.StepRegistryProvider) {
 	h.stepRegistry = registry
@@ -104,6 +128,8 @@ func (h *PipelineWorkflowHandler) ExecuteWorkflow(ctx context.Context, workflowT
 		return nil, fmt.Errorf("pipeline %q not found", name)
 	}
 
+	h.inFlight.Add(1)
+	defer h.inFlight.Add(-1)
 	result, err := pipeline.Run(ctx, data)
 	if err != nil {
 		return nil, fmt.Errorf("pipeline %q execution failed: %w", name, err)
@@ -0,0 +1,418 @@
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Options describes where a catalog's messages come from.
+type Options struct {
+	// Default is the locale used when a request matches no catalog, and the
+	// last step of every fallback chain. It must have messages.
+	Default string
+	// Dir is a directory of catalog files named after their locale, such as
+	// de.yaml, de-AT.yml or fr.json.
+	Dir string
+	// Messages holds inline catalogs keyed by locale. An inline message
+	// replaces the message with the same key loaded from Dir.
+	Messages map[string]map[string]any
+}
+
+// message is one catalog entry: plain text, or one text per plural
+// category.
+type message struct {
+	text   string
+	plural map[string]string
+}
+
+// Catalog holds the messages of every configured locale. Keys are dotted
+// paths into the catalog's nested maps, e.g. "errors.not_found". A message
+// is a string with optional {name} placeholders, or a map from CLDR plural
+// category (zero, one, two, few, many, other) to such a string; "other" is
+// required. A Catalog is read-only once loaded and safe for concurrent use.
+type Catalog struct {
+	def     string
+	locales map[string]map[string]message
+	tags    []string // sorted keys of locales
+}
+
+// Load builds a catalog from the given options. Every problem found is
+// reported, joined into a single error.
+func Load(opts Options) (*Catalog, error) {
+	var errs []error
+	def, err := NormalizeTag(opts.Default)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("default: %w", err))
+	}
+	c := &Catalog{def: def, locales: make(map[string]map[string]message)}
+
+	if opts.Dir != "" {
+		errs = append(errs, c.loadDir(opts.Dir)...)
+	}
+	inline := make([]string, 0, len(opts.Messages))
+	for locale := range opts.Messages {
+		inline = append(inline, locale)
+	}
+	sort.Strings(inline)
+	for _, locale := range inline {
+		errs = append(errs, c.add("messages."+locale, locale, opts.Messages[locale])...)
+	}
+
+	if def != "" {
+		if _, ok := c.locales[def]; !ok {
+			errs = append(errs, fmt.Errorf("default: locale %q has no messages", def))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	for tag := range c.locales {
+		c.tags = append(c.tags, tag)
+	}
+	sort.Strings(c.tags)
+	return c, nil
+}
+
+// loadDir reads every .yaml, .yml and .json file in dir as the catalog of
+// the locale its base name names. Other files are ignored.
+func (c *Catalog) loadDir(dir string) []error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return []error{fmt.Errorf("dir: %w", err)}
+	}
+	var errs []error
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if e.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", e.Name(), err))
+			continue
+		}
+		var messages map[string]any
+		if err := yaml.Unmarshal(data, &messages); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", e.Name(), err))
+			continue
+		}
+		errs = append(errs, c.add(e.Name(), strings.TrimSuffix(e.Name(), ext), messages)...)
+	}
+	return errs
+}
+
+// add merges messages into the catalog of locale. source names where the
+// messages came from in error messages.
+func (c *Catalog) add(source, locale string, messages map[string]any) []error {
+	tag, err := NormalizeTag(locale)
+	if err != nil {
+		return []error{fmt.Errorf("%s: %w", source, err)}
+	}
+	if c.locales[tag] == nil {
+		c.locales[tag] = make(map[string]message)
+	}
+	return flatten(source, "", messages, c.locales[tag])
+}
+
+// flatten stores the messages of m under dotted keys prefixed with prefix.
+func flatten(source, prefix string, m map[string]any, out map[string]message) []error {
+	var errs []error
+	for k, v := range m {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		switch val := v.(type) {
+		case string:
+			out[key] = message{text: val}
+		case map[string]any:
+			if isPluralMap(val) {
+				msg, err := pluralMessage(val)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s: %s: %w", source, key, err))
+					continue
+				}
+				out[key] = msg
+				continue
+			}
+			errs = append(errs, flatten(source, key, val, out)...)
+		default:
+			errs = append(errs, fmt.Errorf("%s: %s: message must be a string or a map, got %T", source, key, v))
+		}
+	}
+	return errs
+}
+
+// isPluralMap reports whether m holds plural forms rather than nested
+// messages: every key is a plural category.
+func isPluralMap(m map[string]any) bool {
+	if len(m) == 0 {
+		return false
+	}
+	for k := range m {
+		if !IsPluralCategory(k) {
+			return false
+		}
+	}
+	return true
+}
+
+func pluralMessage(m map[string]any) (message, error) {
+	forms := make(map[string]string, len(m))
+	for category, v := range m {
+		s, ok := v.(string)
+		if !ok {
+			return message{}, fmt.Errorf("plural form %q must be a string, got %T", category, v)
+		}
+		forms[category] = s
+	}
+	if _, ok := forms[PluralOther]; !ok {
+		return message{}, fmt.Errorf("plural message must define the %q form", PluralOther)
+	}
+	return message{plural: forms}, nil
+}
+
+// Default returns the default locale.
+func (c *Catalog) Default() string { return c.def }
+
+// Locales returns the locales that have messages, sorted.
+func (c *Catalog) Locales() []string { return append([]string(nil), c.tags...) }
+
+// Keys returns the keys defined by locale's own catalog, sorted.
+func (c *Catalog) Keys(locale string) []string {
+	keys := make([]string, 0, len(c.locales[locale]))
+	for k := range c.locales[locale] {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Chain returns the locales consulted, in order, when looking up a message
+// for locale: the locale, its parents, then the default locale and its
+// parents. For example "de-AT" with default "en" yields de-AT, de, en.
+func (c *Catalog) Chain(locale string) []string {
+	var chain []string
+	seen := make(map[string]bool)
+	candidates := []string{c.def}
+	if tag, err := NormalizeTag(locale); err == nil {
+		candidates = append(Parents(tag), Parents(c.def)...)
+	}
+	for _, tag := range candidates {
+		if !seen[tag] {
+			seen[tag] = true
+			chain = append(chain, tag)
+		}
+	}
+	return chain
+}
+
+// Resolve returns the best supported locale for the preferred tags, tried
+// in order. A tag matches a locale with a catalog exactly or through a
+// parent ("de-AT" matches "de"), and otherwise any catalog of the same
+// language ("de" matches "de-CH"). The default locale is returned when no
+// tag matches.
+func (c *Catalog) Resolve(preferred ...string) string {
+	for _, raw := range preferred {
+		tag, err := NormalizeTag(raw)
+		if err != nil {
+			continue
+		}
+		for _, p := range Parents(tag) {
+			if _, ok := c.locales[p]; ok {
+				return p
+			}
+		}
+		lang, _, _ := strings.Cut(tag, "-")
+		for _, supported := range c.tags {
+			if strings.HasPrefix(supported, lang+"-") {
+				return supported
+			}
+		}
+	}
+	return c.def
+}
+
+// Has reports whether key resolves to a message for locale, counting
+// fallbacks.
+func (c *Catalog) Has(locale, key string) bool {
+	for _, tag := range c.Chain(locale) {
+		if _, ok := c.locales[tag][key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Translate returns the message for key in locale, following the fallback
+// chain. {name} placeholders are replaced with args[name]; unknown
+// placeholders are left as written. For a plural message, args["count"]
+// selects the form using the plural rules of the locale that supplied it,
+// falling back to the "other" form.
+func (c *Catalog) Translate(locale, key string, args map[string]any) (string, error) {
+	for _, tag := range c.Chain(locale) {
+		msg, ok := c.locales[tag][key]
+		if !ok {
+			continue
+		}
+		text := msg.text
+		if msg.plural != nil {
+			text = msg.plural[PluralOther]
+			if n, ok := toFloat(args["count"]); ok {
+				if form, ok := msg.plural[PluralCategory(tag, n)]; ok {
+					text = form
+				}
+			}
+		}
+		return substitute(text, args), nil
+	}
+	return "", fmt.Errorf("no message %q for locale %q", key, locale)
+}
+
+// MissingKeys reports, for each locale, the keys a request in that locale
+// would not find in its own language. For the default locale these are keys
+// other locales define that it lacks, which have no fallback at all. For
+// other locales they are keys of the default locale that the locale and
+// its parents lack, so requests fall back to the default language. Regional
+// locales whose parent has a catalog are not reported separately, since
+// they inherit their parent's messages. Locales with nothing missing are
+// omitted.
+func (c *Catalog) MissingKeys() map[string][]string {
+	missing := make(map[string][]string)
+	for _, tag := range c.tags {
+		if tag == c.def {
+			continue
+		}
+		parents := Parents(tag)
+		inherits := false
+		for _, p := range parents[1:] {
+			if _, ok := c.locales[p]; ok {
+				inherits = true
+			}
+		}
+		for key := range c.locales[tag] {
+			if _, ok := c.locales[c.def][key]; !ok && !slices.Contains(missing[c.def], key) {
+				missing[c.def] = append(missing[c.def], key)
+			}
+		}
+		if inherits {
+			continue
+		}
+		for key := range c.locales[c.def] {
+			found := false
+			for _, p := range parents {
+				if _, ok := c.locales[p][key]; ok {
+					found = true
+					break
+				}
+			}
+			if !found {
+				missing[tag] = append(missing[tag], key)
+			}
+		}
+	}
+	for _, keys := range missing {
+		sort.Strings(keys)
+	}
+	return missing
+}
+
+// substitute replaces {name} placeholders in text with values from args.
+func substitute(text string, args map[string]any) string {
+	if len(args) == 0 || !strings.Contains(text, "{") {
+		return text
+	}
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(text, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(text[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start
+		name := text[start+1 : end]
+		v, ok := args[name]
+		if !ok || !isPlaceholderName(name) {
+			b.WriteString(text[:start+1])
+			text = text[start+1:]
+			continue
+		}
+		b.WriteString(text[:start])
+		b.WriteString(formatArg(v))
+		text = text[end+1:]
+	}
+	b.WriteString(text)
+	return b.String()
+}
+
+func isPlaceholderName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// formatArg renders a placeholder value. Whole floats, as decoded from
+// JSON, are printed without a fractional part.
+func formatArg(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		if val == math.Trunc(val) && math.Abs(val) < 1e15 {
+			return strconv.FormatInt(int64(val), 10)
+		}
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// toFloat converts a count argument to a number.
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		return f, err == nil
+	case interface{ Float64() (float64, error) }:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,143 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testCatalog(t *testing.T) *Catalog {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"en.yaml": `
+greeting: "Hello, {name}!"
+cart:
+  items:
+    one: "{count} item"
+    other: "{count} items"
+errors:
+  not_found: "Not found"
+`,
+		"de.json":    `{"greeting": "Hallo, {name}!", "cart": {"items": {"one": "{count} Artikel", "other": "{count} Artikel"}}}`,
+		"de-AT.yaml": `greeting: "Servus, {name}!"`,
+		"README.md":  "ignored",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c, err := Load(Options{
+		Default: "en",
+		Dir:     dir,
+		Messages: map[string]map[string]any{
+			"ru": {"cart": map[string]any{"items": map[string]any{
+				"one": "{count} товар", "few": "{count} товара", "many": "{count} товаров", "other": "{count} товара",
+			}}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestCatalogTranslateFallbackChain(t *testing.T) {
+	c := testCatalog(t)
+	if got := c.Chain("de-AT"); !reflect.DeepEqual(got, []string{"de-AT", "de", "en"}) {
+		t.Errorf("Chain(de-AT) = %v", got)
+	}
+	tests := []struct {
+		locale, key string
+		args        map[string]any
+		want        string
+	}{
+		{"de-AT", "greeting", map[string]any{"name": "Anna"}, "Servus, Anna!"},
+		{"de-AT", "cart.items", map[string]any{"count": 1}, "1 Artikel"},
+		{"de-AT", "errors.not_found", nil, "Not found"},
+		{"en", "cart.items", map[string]any{"count": float64(3)}, "3 items"},
+		{"en", "cart.items", nil, "{count} items"},
+		{"ru", "cart.items", map[string]any{"count": 22}, "22 товара"},
+		{"ru", "cart.items", map[string]any{"count": "25"}, "25 товаров"},
+		{"fr", "greeting", map[string]any{"name": "Zoé", "unused": 1}, "Hello, Zoé!"},
+		{"en", "greeting", map[string]any{}, "Hello, {name}!"},
+	}
+	for _, tc := range tests {
+		got, err := c.Translate(tc.locale, tc.key, tc.args)
+		if err != nil || got != tc.want {
+			t.Errorf("Translate(%q, %q) = %q, %v; want %q", tc.locale, tc.key, got, err, tc.want)
+		}
+	}
+	if _, err := c.Translate("de", "nope", nil); err == nil {
+		t.Error("expected an error for an unknown key")
+	}
+}
+
+func TestCatalogResolve(t *testing.T) {
+	c := testCatalog(t)
+	tests := []struct {
+		preferred []string
+		want      string
+	}{
+		{[]string{"de-AT"}, "de-AT"},
+		{[]string{"de-CH"}, "de"},
+		{[]string{"fr", "ru-RU"}, "ru"},
+		{[]string{"fr"}, "en"},
+		{nil, "en"},
+	}
+	for _, tc := range tests {
+		if got := c.Resolve(tc.preferred...); got != tc.want {
+			t.Errorf("Resolve(%v) = %q, want %q", tc.preferred, got, tc.want)
+		}
+	}
+
+	regional, err := Load(Options{Default: "en", Messages: map[string]map[string]any{
+		"en": {"a": "A"}, "pt-BR": {"a": "A"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := regional.Resolve("pt"); got != "pt-BR" {
+		t.Errorf("Resolve(pt) = %q, want pt-BR", got)
+	}
+}
+
+func TestCatalogMissingKeys(t *testing.T) {
+	c := testCatalog(t)
+	got := c.MissingKeys()
+	want := map[string][]string{
+		"de": {"errors.not_found"},
+		"ru": {"errors.not_found", "greeting"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MissingKeys = %v, want %v", got, want)
+	}
+}
+
+func TestLoadReportsEveryProblem(t *testing.T) {
+	_, err := Load(Options{
+		Default: "fr",
+		Dir:     filepath.Join(t.TempDir(), "missing"),
+		Messages: map[string]map[string]any{
+			"en":      {"count": map[string]any{"one": "x"}, "n": 3},
+			"english": {"a": "b"},
+		},
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{
+		"dir:",
+		`messages.en: count: plural message must define the "other" form`,
+		"messages.en: n: message must be a string or a map, got int",
+		`messages.english: invalid locale "english"`,
+		`default: locale "fr" has no messages`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/GoCodeAlone/workflow/config"
+	"github.com/GoCodeAlone/workflow/schema"
+)
+
+func init() {
+	schema.RegisterLocaleValidator(validateConfig)
+}
+
+// translateKeyRe matches the literal message key of a t template function
+// call: {{ t "key" ... }}, {{- t "key" }} or (t "key" ...).
+var translateKeyRe = regexp.MustCompile(`(?:\{\{-?|\(|\|)\s*t\s+"((?:[^"\\]|\\.)+)"`)
+
+// LoadConfig loads the message catalogs of cfg's locales: section, with
+// the catalog directory resolved against the config file. It returns nil
+// and no error when the config declares no locales.
+func LoadConfig(cfg *config.WorkflowConfig) (*Catalog, error) {
+	if cfg.Locales == nil {
+		return nil, nil
+	}
+	return Load(Options{
+		Default:  cfg.Locales.Default,
+		Dir:      cfg.ResolveRelativePath(cfg.Locales.Dir),
+		Messages: cfg.Locales.Messages,
+	})
+}
+
+// validateConfig is the locale validator schema.ValidateConfig runs. It
+// loads the locales: catalogs so a malformed catalog fails the build, checks
+// the locale of HTTP routes, and checks that every message key passed
+// literally to t or used by step.localize exists in the default locale,
+// since a request in any locale falls back to it.
+func validateConfig(cfg *config.WorkflowConfig) schema.ValidationErrors {
+	var errs schema.ValidationErrors
+	catalog, err := LoadConfig(cfg)
+	if err != nil {
+		var joined interface{ Unwrap() []error }
+		if errors.As(err, &joined) {
+			for _, e := range joined.Unwrap() {
+				errs = append(errs, &schema.ValidationError{Path: "locales", Message: e.Error()})
+			}
+		} else {
+			errs = append(errs, &schema.ValidationError{Path: "locales", Message: err.Error()})
+		}
+	}
+
+	for _, ref := range routeLocales(cfg) {
+		if _, err := NormalizeTag(ref.value); err != nil {
+			errs = append(errs, &schema.ValidationError{Path: ref.path, Message: err.Error()})
+		} else if cfg.Locales == nil {
+			errs = append(errs, &schema.ValidationError{Path: ref.path, Message: "locale is set but the config declares no locales: section"})
+		}
+	}
+
+	if catalog == nil {
+		return errs
+	}
+	var refs []localeRef
+	collectMessageKeys("pipelines", cfg.Pipelines, &refs)
+	collectMessageKeys("workflows", cfg.Workflows, &refs)
+	for _, ref := range refs {
+		if !catalog.Has(catalog.Default(), ref.value) {
+			errs = append(errs, &schema.ValidationError{
+				Path:    ref.path,
+				Message: fmt.Sprintf("message key %q is not defined for the default locale %q", ref.value, catalog.Default()),
+			})
+		}
+	}
+	return errs
+}
+
+// localeRef is a locale or message key found in a config, with its path.
+type localeRef struct {
+	path  string
+	value string
+}
+
+// routeLocales returns the locale: of every HTTP trigger route, inline
+// pipeline HTTP trigger and http.router workflow route.
+func routeLocales(cfg *config.WorkflowConfig) []localeRef {
+	var refs []localeRef
+	addRoutes := func(prefix string, routes any) {
+		list, _ := routes.([]any)
+		for i, raw := range list {
+			route, _ := raw.(map[string]any)
+			if locale, ok := route["locale"].(string); ok {
+				refs = append(refs, localeRef{path: fmt.Sprintf("%s.routes[%d].locale", prefix, i), value: locale})
+			}
+		}
+	}
+	if trig, ok := cfg.Triggers["http"].(map[string]any); ok {
+		addRoutes("triggers.http", trig["routes"])
+	}
+	for _, name := range sortedKeys(cfg.Workflows) {
+		if wf, ok := cfg.Workflows[name].(map[string]any); ok {
+			addRoutes("workflows."+name, wf["routes"])
+		}
+	}
+	for _, name := range sortedKeys(cfg.Pipelines) {
+		pipeline, _ := cfg.Pipelines[name].(map[string]any)
+		trigger, _ := pipeline["trigger"].(map[string]any)
+		if t, _ := trigger["type"].(string); t != "http" {
+			continue
+		}
+		triggerCfg, _ := trigger["config"].(map[string]any)
+		if locale, ok := triggerCfg["locale"].(string); ok {
+			refs = append(refs, localeRef{path: "pipelines." + name + ".trigger.config.locale", value: locale})
+		}
+	}
+	return refs
+}
+
+// collectMessageKeys walks v and records the message keys of t calls with a
+// literal key in string values, and the keys of step.localize messages.
+func collectMessageKeys(path string, v any, refs *[]localeRef) {
+	switch val := v.(type) {
+	case string:
+		for _, m := range translateKeyRe.FindAllStringSubmatch(val, -1) {
+			*refs = append(*refs, localeRef{path: path, value: m[1]})
+		}
+	case map[string]any:
+		if t, _ := val["type"].(string); t == "step.localize" {
+			stepCfg, _ := val["config"].(map[string]any)
+			messages, _ := stepCfg["messages"].(map[string]any)
+			for _, out := range sortedKeys(messages) {
+				key, _ := messages[out].(string)
+				if m, ok := messages[out].(map[string]any); ok {
+					key, _ = m["key"].(string)
+				}
+				if key != "" {
+					*refs = append(*refs, localeRef{path: path + ".config.messages." + out, value: key})
+				}
+			}
+		}
+		for _, k := range sortedKeys(val) {
+			collectMessageKeys(path+"."+k, val[k], refs)
+		}
+	case []any:
+		for i, item := range val {
+			collectMessageKeys(fmt.Sprintf("%s[%d]", path, i), item, refs)
+		}
+	}
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,83 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/GoCodeAlone/workflow/config"
+	"github.com/GoCodeAlone/workflow/schema"
+)
+
+func TestValidateConfigLocales(t *testing.T) {
+	cfg := &config.WorkflowConfig{
+		Modules: []config.ModuleConfig{
+			{Name: "server", Type: "http.server", Config: map[string]any{"address": ":8080"}},
+		},
+		Locales: &config.LocalesConfig{
+			Default: "en",
+			Messages: map[string]map[string]any{
+				"en": {"greeting": "Hello", "cart": map[string]any{"items": map[string]any{"one": "1 item", "other": "{count} items"}}},
+				"de": {"greeting": "Hallo"},
+			},
+		},
+		Triggers: map[string]any{
+			"http": map[string]any{"routes": []any{
+				map[string]any{"path": "/a", "method": "GET", "workflow": "pipeline:hello", "action": "execute", "locale": "de_at"},
+				map[string]any{"path": "/b", "method": "GET", "workflow": "pipeline:hello", "action": "execute", "locale": "deutsch"},
+			}},
+		},
+		Pipelines: map[string]any{
+			"hello": map[string]any{
+				"steps": []any{
+					map[string]any{"name": "respond", "type": "step.json_response", "config": map[string]any{
+						"body": map[string]any{
+							"message": `{{ t "greeting" }}`,
+							"items":   `{{ t "cart.items" "count" .count }}`,
+							"typo":    `{{ t "greting" }}`,
+						},
+					}},
+					map[string]any{"name": "msgs", "type": "step.localize", "config": map[string]any{
+						"messages": map[string]any{"bye": map[string]any{"key": "farewell"}},
+					}},
+				},
+			},
+		},
+	}
+	err := schema.ValidateConfig(cfg, schema.WithAllowNoEntryPoints())
+	if err == nil {
+		t.Fatal("expected locale validation errors")
+	}
+	for _, want := range []string{
+		`triggers.http.routes[1].locale: invalid locale "deutsch"`,
+		`pipelines.hello.steps[0].config.body.typo: message key "greting" is not defined for the default locale "en"`,
+		`pipelines.hello.steps[1].config.messages.bye: message key "farewell"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q to contain %q", err, want)
+		}
+	}
+	for _, ok := range []string{"routes[0]", `"greeting"`, "cart.items"} {
+		if strings.Contains(err.Error(), ok) {
+			t.Errorf("valid reference %s reported: %v", ok, err)
+		}
+	}
+
+	cfg.Locales.Messages["de"] = map[string]any{"broken": map[string]any{"one": "kaputt"}}
+	err = schema.ValidateConfig(cfg, schema.WithAllowNoEntryPoints())
+	if err == nil {
+		t.Fatal("expected a catalog error")
+	}
+	if want := `locales: messages.de: broken: plural message must define the "other" form`; !strings.Contains(err.Error(), want) {
+		t.Errorf("expected %q to contain %q", err, want)
+	}
+
+	cfg.Locales = nil
+	cfg.Pipelines = nil
+	err = schema.ValidateConfig(cfg, schema.WithAllowNoEntryPoints())
+	if err == nil {
+		t.Fatal("expected an error for a route locale without locales")
+	}
+	if want := "triggers.http.routes[0].locale: locale is set but the config declares no locales: section"; !strings.Contains(err.Error(), want) {
+		t.Errorf("expected %q to contain %q", err, want)
+	}
+}
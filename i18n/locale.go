@@ -0,0 +1,134 @@
+// Package i18n provides message catalogs, Accept-Language negotiation and
+// CLDR plural selection for localized pipeline responses.
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxAcceptLanguageEntries bounds how many language ranges of an
+// Accept-Language header are considered.
+const maxAcceptLanguageEntries = 32
+
+// NormalizeTag validates a BCP 47 language tag and returns it in canonical
+// case: "en_us" becomes "en-US" and "zh-hant-tw" becomes "zh-Hant-TW".
+// Only the language, script, region and variant subtags are recognised;
+// extensions and private-use subtags are rejected.
+func NormalizeTag(tag string) (string, error) {
+	tag = strings.TrimSpace(strings.ReplaceAll(tag, "_", "-"))
+	if tag == "" {
+		return "", fmt.Errorf("empty locale tag")
+	}
+	parts := strings.Split(tag, "-")
+	lang := parts[0]
+	if len(lang) < 2 || len(lang) > 3 || !isAlpha(lang) {
+		return "", fmt.Errorf("invalid locale %q: language must be 2 or 3 letters", tag)
+	}
+	out := []string{strings.ToLower(lang)}
+	for _, p := range parts[1:] {
+		switch {
+		case len(p) == 4 && isAlpha(p):
+			out = append(out, strings.ToUpper(p[:1])+strings.ToLower(p[1:]))
+		case len(p) == 2 && isAlpha(p), len(p) == 3 && isDigits(p):
+			out = append(out, strings.ToUpper(p))
+		case len(p) >= 5 && len(p) <= 8 && isAlnum(p), len(p) == 4 && isDigits(p[:1]) && isAlnum(p):
+			out = append(out, strings.ToLower(p))
+		default:
+			return "", fmt.Errorf("invalid locale %q: unsupported subtag %q", tag, p)
+		}
+	}
+	return strings.Join(out, "-"), nil
+}
+
+// Parents returns tag followed by its successively shorter prefixes, so
+// "de-Latn-AT" yields "de-Latn-AT", "de-Latn", "de". tag must be normalized.
+func Parents(tag string) []string {
+	chain := []string{tag}
+	for {
+		i := strings.LastIndexByte(tag, '-')
+		if i < 0 {
+			return chain
+		}
+		tag = tag[:i]
+		chain = append(chain, tag)
+	}
+}
+
+// ParseAcceptLanguage parses an Accept-Language header into normalized
+// language tags ordered by descending quality. Ranges with q=0, the "*"
+// wildcard and malformed entries are dropped; ties keep header order.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var ranges []weighted
+	for i, part := range strings.Split(header, ",") {
+		if i >= maxAcceptLanguageEntries {
+			break
+		}
+		fields := strings.Split(part, ";")
+		tag := strings.TrimSpace(fields[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		valid := true
+		for _, param := range fields[1:] {
+			name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || !strings.EqualFold(strings.TrimSpace(name), "q") {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || parsed < 0 || parsed > 1 {
+				valid = false
+				break
+			}
+			q = parsed
+		}
+		if !valid || q == 0 {
+			continue
+		}
+		normalized, err := NormalizeTag(tag)
+		if err != nil {
+			continue
+		}
+		ranges = append(ranges, weighted{tag: normalized, q: q})
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+	tags := make([]string, len(ranges))
+	for i, r := range ranges {
+		tags[i] = r.tag
+	}
+	return tags
+}
+
+func isAlpha(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlnum(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,52 @@
+package i18n
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeTag(t *testing.T) {
+	tests := map[string]string{
+		"en":         "en",
+		"EN_us":      "en-US",
+		"zh-hant-tw": "zh-Hant-TW",
+		"es-419":     "es-419",
+		"de-CH-1996": "de-CH-1996",
+	}
+	for in, want := range tests {
+		got, err := NormalizeTag(in)
+		if err != nil || got != want {
+			t.Errorf("NormalizeTag(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"", "e", "english", "en-", "en-x-private", "12"} {
+		if _, err := NormalizeTag(bad); err == nil {
+			t.Errorf("NormalizeTag(%q) should fail", bad)
+		}
+	}
+}
+
+func TestParents(t *testing.T) {
+	got := Parents("de-Latn-AT")
+	if want := []string{"de-Latn-AT", "de-Latn", "de"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Parents = %v, want %v", got, want)
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []string
+	}{
+		{"", []string{}},
+		{"de-AT", []string{"de-AT"}},
+		{"fr;q=0.5, de-at, en;q=0.8", []string{"de-AT", "en", "fr"}},
+		{"en;q=0.8, *;q=0.5, es;q=0.8", []string{"en", "es"}},
+		{"en;q=0, fr;q=bogus, ja;q=2, !!, it", []string{"it"}},
+	}
+	for _, tc := range tests {
+		if got := ParseAcceptLanguage(tc.header); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("ParseAcceptLanguage(%q) = %v, want %v", tc.header, got, tc.want)
+		}
+	}
+}
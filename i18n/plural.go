@@ -0,0 +1,205 @@
+package i18n
+
+import (
+	"math"
+	"strings"
+)
+
+// Plural categories defined by CLDR.
+const (
+	PluralZero  = "zero"
+	PluralOne   = "one"
+	PluralTwo   = "two"
+	PluralFew   = "few"
+	PluralMany  = "many"
+	PluralOther = "other"
+)
+
+// IsPluralCategory reports whether s names a CLDR plural category.
+func IsPluralCategory(s string) bool {
+	switch s {
+	case PluralZero, PluralOne, PluralTwo, PluralFew, PluralMany, PluralOther:
+		return true
+	}
+	return false
+}
+
+// pluralRule selects the plural category of a non-negative integer count.
+type pluralRule func(n int64) string
+
+// pluralRules maps a language subtag to the CLDR cardinal rule for integer
+// counts. Languages not listed use ruleOneOther.
+var pluralRules = map[string]pluralRule{}
+
+func init() {
+	for _, lang := range []string{"ja", "zh", "ko", "th", "vi", "id", "ms", "lo", "my", "km", "yue"} {
+		pluralRules[lang] = ruleOther
+	}
+	for _, lang := range []string{"fr", "pt", "hi", "bn", "fa", "gu", "kn", "zu", "am", "hy"} {
+		pluralRules[lang] = ruleZeroOne
+	}
+	for _, lang := range []string{"ru", "uk", "be"} {
+		pluralRules[lang] = ruleEastSlavic
+	}
+	for _, lang := range []string{"hr", "sr", "bs"} {
+		pluralRules[lang] = ruleSouthSlavic
+	}
+	pluralRules["pl"] = rulePolish
+	pluralRules["cs"] = ruleCzech
+	pluralRules["sk"] = ruleCzech
+	pluralRules["lt"] = ruleLithuanian
+	pluralRules["lv"] = ruleLatvian
+	pluralRules["ro"] = ruleRomanian
+	pluralRules["ar"] = ruleArabic
+	pluralRules["he"] = ruleHebrew
+}
+
+// PluralCategory returns the CLDR cardinal plural category of count in
+// locale. Rules cover integer counts; a count with a fractional part
+// selects "other". Regional variants use their language's rule, except
+// pt-PT, which follows the one-is-exactly-1 rule.
+func PluralCategory(locale string, count float64) string {
+	if count != math.Trunc(count) || math.IsInf(count, 0) {
+		return PluralOther
+	}
+	n := int64(math.Abs(count))
+	lang, _, _ := strings.Cut(locale, "-")
+	lang = strings.ToLower(lang)
+	if lang == "pt" && strings.EqualFold(locale, "pt-PT") {
+		return ruleOneOther(n)
+	}
+	if rule, ok := pluralRules[lang]; ok {
+		return rule(n)
+	}
+	return ruleOneOther(n)
+}
+
+func ruleOther(int64) string { return PluralOther }
+
+func ruleOneOther(n int64) string {
+	if n == 1 {
+		return PluralOne
+	}
+	return PluralOther
+}
+
+func ruleZeroOne(n int64) string {
+	if n == 0 || n == 1 {
+		return PluralOne
+	}
+	return PluralOther
+}
+
+func ruleEastSlavic(n int64) string {
+	mod10, mod100 := n%10, n%100
+	switch {
+	case mod10 == 1 && mod100 != 11:
+		return PluralOne
+	case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+		return PluralFew
+	default:
+		return PluralMany
+	}
+}
+
+func ruleSouthSlavic(n int64) string {
+	mod10, mod100 := n%10, n%100
+	switch {
+	case mod10 == 1 && mod100 != 11:
+		return PluralOne
+	case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+		return PluralFew
+	default:
+		return PluralOther
+	}
+}
+
+func rulePolish(n int64) string {
+	mod10, mod100 := n%10, n%100
+	switch {
+	case n == 1:
+		return PluralOne
+	case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+		return PluralFew
+	default:
+		return PluralMany
+	}
+}
+
+func ruleCzech(n int64) string {
+	switch {
+	case n == 1:
+		return PluralOne
+	case n >= 2 && n <= 4:
+		return PluralFew
+	default:
+		return PluralOther
+	}
+}
+
+func ruleLithuanian(n int64) string {
+	mod10, mod100 := n%10, n%100
+	switch {
+	case mod100 >= 11 && mod100 <= 19:
+		return PluralOther
+	case mod10 == 1:
+		return PluralOne
+	case mod10 >= 2:
+		return PluralFew
+	default:
+		return PluralOther
+	}
+}
+
+func ruleLatvian(n int64) string {
+	mod10, mod100 := n%10, n%100
+	switch {
+	case mod10 == 0 || (mod100 >= 11 && mod100 <= 19):
+		return PluralZero
+	case mod10 == 1:
+		return PluralOne
+	default:
+		return PluralOther
+	}
+}
+
+func ruleRomanian(n int64) string {
+	mod100 := n % 100
+	switch {
+	case n == 1:
+		return PluralOne
+	case n == 0 || (mod100 >= 2 && mod100 <= 19):
+		return PluralFew
+	default:
+		return PluralOther
+	}
+}
+
+func ruleArabic(n int64) string {
+	mod100 := n % 100
+	switch {
+	case n == 0:
+		return PluralZero
+	case n == 1:
+		return PluralOne
+	case n == 2:
+		return PluralTwo
+	case mod100 >= 3 && mod100 <= 10:
+		return PluralFew
+	case mod100 >= 11:
+		return PluralMany
+	default:
+		return PluralOther
+	}
+}
+
+func ruleHebrew(n int64) string {
+	switch n {
+	case 1:
+		return PluralOne
+	case 2:
+		return PluralTwo
+	default:
+		return PluralOther
+	}
+}
@@ -0,0 +1,52 @@
+package i18n
+
+import "testing"
+
+func TestPluralCategory(t *testing.T) {
+	tests := []struct {
+		locale string
+		count  float64
+		want   string
+	}{
+		{"en", 1, PluralOne},
+		{"en", 0, PluralOther},
+		{"en-GB", 2, PluralOther},
+		{"en", 1.5, PluralOther},
+		{"fr", 0, PluralOne},
+		{"fr", 2, PluralOther},
+		{"pt-BR", 0, PluralOne},
+		{"pt-PT", 0, PluralOther},
+		{"ja", 1, PluralOther},
+		{"ru", 21, PluralOne},
+		{"ru", 11, PluralMany},
+		{"ru", 23, PluralFew},
+		{"ru", 13, PluralMany},
+		{"ru", 5, PluralMany},
+		{"pl", 1, PluralOne},
+		{"pl", 22, PluralFew},
+		{"pl", 21, PluralMany},
+		{"cs", 3, PluralFew},
+		{"cs", 5, PluralOther},
+		{"hr", 21, PluralOne},
+		{"hr", 5, PluralOther},
+		{"lv", 0, PluralZero},
+		{"lv", 11, PluralZero},
+		{"lv", 21, PluralOne},
+		{"lt", 12, PluralOther},
+		{"lt", 22, PluralFew},
+		{"ro", 0, PluralFew},
+		{"ro", 20, PluralOther},
+		{"ar", 0, PluralZero},
+		{"ar", 2, PluralTwo},
+		{"ar", 105, PluralFew},
+		{"ar", 111, PluralMany},
+		{"ar", 100, PluralOther},
+		{"he", 2, PluralTwo},
+		{"xx", 1, PluralOne},
+	}
+	for _, tc := range tests {
+		if got := PluralCategory(tc.locale, tc.count); got != tc.want {
+			t.Errorf("PluralCategory(%q, %v) = %q, want %q", tc.locale, tc.count, got, tc.want)
+		}
+	}
+}
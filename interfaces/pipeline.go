@@ -9,6 +9,7 @@ import (
 	"errors"
 	"log/slog"
 	"maps"
+	"time"
 )
 
 // EventRecorder records pipeline execution events for observability.
@@ -61,7 +62,8 @@ type PipelineContext struct {
 	// StrictTemplates causes template execution to return an error instead of
 	// the zero value when a template expression references a missing map key.
 	// When false (the default), missing keys resolve to the zero value with a
-	// warning logged via Logger. Enable via pipeline config: strict_templates: true.
+	// warning logged via Logger. Enable with strict_templates: true on a
+	// pipeline, an HTTP route, or the engine block.
 	StrictTemplates bool
 
 	// Logger is used to emit warnings when a template expression resolves a
@@ -123,6 +125,38 @@ type StepResult struct {
 	// Skipped indicates the step was bypassed by a guard (skip_if or if).
 	// When true, the pipeline executor will not record this step in StepOutputs.
 	Skipped bool
+
+	// DryRun indicates a destructive step was not executed because the
+	// pipeline ran in dry-run mode. Output describes what it would have done.
+	DryRun bool
+
+	// AIUsage reports the tokens an AI step used and their cost. The
+	// pipeline executor records it on the step.completed event.
+	AIUsage *AIUsage
+
+	// DBQuery reports the SQL statement a database step ran. The pipeline
+	// executor records it on the step.completed event.
+	DBQuery *DBQueryStats
+}
+
+// AIUsage is the token usage and cost of the AI model calls made by one step.
+type AIUsage struct {
+	Provider     string
+	Model        string
+	InputTokens  int
+	OutputTokens int
+	Cost         float64
+}
+
+// DBQueryStats describes the SQL statement run by one database step.
+// Statement is the SQL with its placeholders; parameter values are never
+// part of it, and literals matching the redaction patterns are redacted.
+type DBQueryStats struct {
+	Database  string
+	Operation string // select, insert, update, delete or other
+	Statement string
+	Duration  time.Duration
+	Rows      int64 // rows returned, or affected by a write
 }
 
 // PipelineStep is a single composable unit of work in a pipeline.
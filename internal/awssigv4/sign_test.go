@@ -0,0 +1,49 @@
+package awssigv4
+
+import (
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSigningKey(t *testing.T) {
+	// The example from the AWS Signature Version 4 documentation.
+	key := SigningKey("wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "20120215", "us-east-1", "iam")
+	if got, want := hex.EncodeToString(key), "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d"; got != want {
+		t.Errorf("SigningKey = %s, want %s", got, want)
+	}
+}
+
+func TestSign(t *testing.T) {
+	now := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	newRequest := func() *http.Request {
+		req, _ := http.NewRequest(http.MethodPost, "https://dynamodb.eu-west-1.amazonaws.com/", strings.NewReader(`{}`))
+		req.Header.Set("Content-Type", "application/x-amz-json-1.0")
+		req.Header.Set("X-Amz-Target", "DynamoDB_20120810.GetItem")
+		return req
+	}
+
+	req := newRequest()
+	Sign(req, []byte(`{}`), Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "tok"}, "eu-west-1", "dynamodb", now)
+	auth := req.Header.Get("Authorization")
+	for _, want := range []string{
+		"AWS4-HMAC-SHA256 Credential=AKID/20260304/eu-west-1/dynamodb/aws4_request",
+		"SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date;x-amz-security-token;x-amz-target,",
+	} {
+		if !strings.Contains(auth, want) {
+			t.Errorf("Authorization = %q, want it to contain %q", auth, want)
+		}
+	}
+	if req.Header.Get("X-Amz-Date") != "20260304T050607Z" || req.Header.Get("X-Amz-Security-Token") != "tok" {
+		t.Errorf("unexpected signing headers: %v", req.Header)
+	}
+
+	// The signature covers the payload.
+	other := newRequest()
+	Sign(other, []byte(`{"a":1}`), Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "tok"}, "eu-west-1", "dynamodb", now)
+	if other.Header.Get("Authorization") == auth {
+		t.Error("different payloads produced the same signature")
+	}
+}
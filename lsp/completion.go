@@ -219,6 +219,8 @@ func getModuleNamesFromContent(content string) []protocol.CompletionItem {
 }
 
 // Completions returns completion items for the given document and position context.
+// Outside template expressions, snippets that fit the position follow the key
+// and value completions.
 func Completions(reg *Registry, doc *Document, ctx PositionContext) []protocol.CompletionItem {
 	if ctx.InTemplate {
 		return getTemplateCompletions(reg, doc, ctx)
@@ -226,7 +228,11 @@ func Completions(reg *Registry, doc *Document, ctx PositionContext) []protocol.C
 	if ctx.InExpr {
 		return getExprCompletions(reg, doc, ctx)
 	}
+	return append(getKeyCompletions(reg, doc, ctx), snippetCompletions(reg, doc, ctx)...)
+}
 
+// getKeyCompletions returns key and value completions for the position.
+func getKeyCompletions(reg *Registry, doc *Document, ctx PositionContext) []protocol.CompletionItem {
 	switch ctx.Section {
 	case SectionTopLevel:
 		return getTopLevelKeys(reg)
@@ -267,7 +273,7 @@ func Completions(reg *Registry, doc *Document, ctx PositionContext) []protocol.C
 // moduleItemKeys returns field key completions for a modules[] item.
 func moduleItemKeys() []protocol.CompletionItem {
 	kind := protocol.CompletionItemKindProperty
-	keys := []string{"name", "type", "config", "dependsOn", "branches"}
+	keys := []string{"name", "type", "config", "dependsOn", "provides", "needs", "branches", "enabled", "startPolicy"}
 	items := make([]protocol.CompletionItem, 0, len(keys))
 	for _, k := range keys {
 		key := k
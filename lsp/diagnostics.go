@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/GoCodeAlone/workflow/schema"
+	"github.com/GoCodeAlone/workflow/validation"
 	protocol "github.com/tliron/glsp/protocol_3_16"
 	"gopkg.in/yaml.v3"
 )
@@ -44,6 +46,8 @@ func Diagnostics(reg *Registry, doc *Document) []protocol.Diagnostic {
 			diags = append(diags, validateTriggers(reg, valNode)...)
 		case "workflows":
 			diags = append(diags, validateWorkflows(reg, valNode)...)
+		case "pipelines":
+			diags = append(diags, validatePipelineRefs(valNode)...)
 		}
 	}
 
@@ -221,6 +225,103 @@ func validateWorkflows(reg *Registry, node *yaml.Node) []protocol.Diagnostic {
 	return diags
 }
 
+// validatePipelineRefs checks the step references of every pipeline, the
+// same checks wfctl validate runs. References that fail at runtime are errors;
+// the rest, such as fields of steps with dynamic outputs, are warnings. Each
+// diagnostic is placed on the config value holding the reference, falling
+// back to the step's name.
+func validatePipelineRefs(node *yaml.Node) []protocol.Diagnostic {
+	var diags []protocol.Diagnostic
+	if node.Kind != yaml.MappingNode {
+		return diags
+	}
+	var pipelines map[string]any
+	if err := node.Decode(&pipelines); err != nil {
+		return diags
+	}
+	result := validation.ValidatePipelineTemplateRefs(pipelines, schema.GetStepSchemaRegistry())
+	for _, f := range result.Findings {
+		sev := protocol.DiagnosticSeverityWarning
+		if validation.IsBlockingRefWarningCode(f.Code) {
+			sev = protocol.DiagnosticSeverityError
+		}
+		diags = append(diags, protocol.Diagnostic{
+			Range:    nodeRange(findingNode(node, f)),
+			Severity: &sev,
+			Code:     &protocol.IntegerOrString{Value: string(f.Code)},
+			Message:  f.Message,
+			Source:   strPtr("workflow-lsp"),
+		})
+	}
+	return diags
+}
+
+// findingNode returns the node a reference finding is reported on: the
+// scalar in the step that contains the reference, else the step's name,
+// else the pipeline's key.
+func findingNode(pipelines *yaml.Node, f validation.RefFinding) *yaml.Node {
+	for i := 0; i+1 < len(pipelines.Content); i += 2 {
+		if pipelines.Content[i].Value != f.Pipeline {
+			continue
+		}
+		pipelineNode := pipelines.Content[i+1]
+		steps := mappingValue(pipelineNode, "steps")
+		if steps == nil || steps.Kind != yaml.SequenceNode {
+			return pipelines.Content[i]
+		}
+		for _, step := range steps.Content {
+			name := mappingValue(step, "name")
+			if name == nil || name.Value != f.Step {
+				continue
+			}
+			if f.Ref != "" {
+				stepRef := strings.Join(strings.SplitN(f.Ref, ".", 3)[:2], ".")
+				for _, needle := range []string{f.Ref, stepRef} {
+					if n := findScalarContaining(mappingValue(step, "config"), needle); n != nil {
+						return n
+					}
+				}
+			}
+			return name
+		}
+		return pipelines.Content[i]
+	}
+	return pipelines
+}
+
+// mappingValue returns the value of key in a mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// findScalarContaining returns the first scalar under node whose value
+// contains s.
+func findScalarContaining(node *yaml.Node, s string) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+	if node.Kind == yaml.ScalarNode {
+		if strings.Contains(node.Value, s) {
+			return node
+		}
+		return nil
+	}
+	for _, child := range node.Content {
+		if n := findScalarContaining(child, s); n != nil {
+			return n
+		}
+	}
+	return nil
+}
+
 // checkUnclosedTemplates scans for unclosed {{ template expressions.
 func checkUnclosedTemplates(content string) []protocol.Diagnostic {
 	var diags []protocol.Diagnostic
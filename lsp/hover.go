@@ -18,6 +18,9 @@ func Hover(reg *Registry, doc *Document, ctx PositionContext) *protocol.Hover {
 	if ctx.InExpr {
 		return hoverExprExpr(reg, doc, ctx)
 	}
+	if h := hoverRoute(doc, ctx); h != nil {
+		return h
+	}
 
 	switch ctx.Section {
 	case SectionTopLevel:
@@ -438,6 +441,10 @@ func hoverTemplateFunction(name string) *protocol.Hover {
 		"join":       "Joins a list of strings with a separator.",
 		"trimSpace":  "Removes leading and trailing whitespace.",
 		"urlEncode":  "URL-encodes a string.",
+		"b64enc":     "Encodes a string as standard base64.",
+		"b64dec":     "Decodes a standard base64 string.",
+		"env":        "Returns the value of an environment variable.",
+		"formatTime": "Formats a time, RFC 3339 string or Unix seconds with a Go time layout.",
 		"add":        "Adds two numbers.",
 		"sub":        "Subtracts the second number from the first.",
 		"mul":        "Multiplies two numbers.",
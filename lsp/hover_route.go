@@ -0,0 +1,116 @@
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/GoCodeAlone/workflow/config"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+	"gopkg.in/yaml.v3"
+)
+
+// hoverRoute returns hover markdown listing the examples of the HTTP route
+// whose method or path line is under the cursor: an entry of a workflow's
+// routes or the config of a pipeline's http trigger. Routes without an
+// examples block have no route hover.
+func hoverRoute(doc *Document, ctx PositionContext) *protocol.Hover {
+	if doc == nil || doc.Node == nil {
+		return nil
+	}
+	route := findRouteNode(doc.Node, ctx.Line+1)
+	if route == nil {
+		return nil
+	}
+	var method, path string
+	var examplesNode *yaml.Node
+	for i := 0; i+1 < len(route.Content); i += 2 {
+		switch route.Content[i].Value {
+		case "method":
+			method = strings.ToUpper(route.Content[i+1].Value)
+		case "path":
+			path = route.Content[i+1].Value
+		case "examples":
+			examplesNode = route.Content[i+1]
+		}
+	}
+	if examplesNode == nil {
+		return nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "**%s %s**\n", method, path)
+	var raw any
+	if err := examplesNode.Decode(&raw); err != nil {
+		fmt.Fprintf(&sb, "\nInvalid examples: %v\n", err)
+		return markdownHover(sb.String())
+	}
+	examples, err := config.ParseRouteExamples(raw)
+	if err != nil {
+		fmt.Fprintf(&sb, "\nInvalid examples: %v\n", err)
+		return markdownHover(sb.String())
+	}
+	sb.WriteString("\n**Examples:**\n")
+	for _, ex := range examples {
+		fmt.Fprintf(&sb, "\n`%s`", ex.Name)
+		if ex.Summary != "" {
+			fmt.Fprintf(&sb, " — %s", ex.Summary)
+		}
+		sb.WriteString("\n")
+		if ex.Request != nil && ex.Request.Body != nil {
+			sb.WriteString("\nRequest:\n")
+			writeJSONBlock(&sb, ex.Request.Body)
+		}
+		fmt.Fprintf(&sb, "\nResponse `%d`:\n", ex.Response.Status)
+		if ex.Response.Body != nil {
+			writeJSONBlock(&sb, ex.Response.Body)
+		}
+	}
+	return markdownHover(sb.String())
+}
+
+// findRouteNode returns the innermost mapping node with method and path keys
+// whose method or path key is on line (one-based).
+func findRouteNode(n *yaml.Node, line int) *yaml.Node {
+	if n == nil {
+		return nil
+	}
+	for _, c := range n.Content {
+		if found := findRouteNode(c, line); found != nil {
+			return found
+		}
+	}
+	if n.Kind != yaml.MappingNode {
+		return nil
+	}
+	var hasMethod, hasPath, onLine bool
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key := n.Content[i]
+		switch key.Value {
+		case "method":
+			hasMethod = true
+		case "path":
+			hasPath = true
+		default:
+			continue
+		}
+		if key.Line == line {
+			onLine = true
+		}
+	}
+	if hasMethod && hasPath && onLine {
+		return n
+	}
+	return nil
+}
+
+func writeJSONBlock(sb *strings.Builder, v any) {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		fmt.Fprintf(sb, "`%v`\n", v)
+		return
+	}
+	sb.WriteString("```json\n")
+	sb.Write(b)
+	sb.WriteString("\n```\n")
+}
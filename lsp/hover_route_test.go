@@ -0,0 +1,64 @@
+package lsp
+
+import (
+	"strings"
+	"testing"
+
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+const routeExamplesYAML = `workflows:
+  http:
+    routes:
+      - method: POST
+        path: /orders
+        handler: orders
+        examples:
+          - name: created
+            summary: A new order
+            request:
+              body: {sku: A-1}
+            response:
+              status: 201
+              body: {id: 42}
+      - method: GET
+        path: /orders
+        handler: orders
+pipelines:
+  get-order:
+    trigger:
+      type: http
+      config:
+        method: GET
+        path: /orders/{id}
+        examples: oops
+`
+
+func TestHover_RouteExamples(t *testing.T) {
+	reg := NewRegistry()
+	doc := NewDocumentStore().Set("file:///test.yaml", routeExamplesYAML)
+
+	hover := Hover(reg, doc, ContextAt(routeExamplesYAML, 4, 10))
+	if hover == nil {
+		t.Fatal("expected hover on the route path")
+	}
+	content := hover.Contents.(protocol.MarkupContent).Value
+	for _, want := range []string{"**POST /orders**", "`created` — A new order", `"sku": "A-1"`, "Response `201`", `"id": 42`} {
+		if !strings.Contains(content, want) {
+			t.Errorf("hover missing %q:\n%s", want, content)
+		}
+	}
+
+	// A pipeline trigger with an invalid examples block.
+	hover = Hover(reg, doc, ContextAt(routeExamplesYAML, 22, 10))
+	if hover == nil || !strings.Contains(hover.Contents.(protocol.MarkupContent).Value, "Invalid examples: examples must be a list") {
+		t.Errorf("trigger hover = %+v", hover)
+	}
+
+	// Routes without examples and lines inside the examples keep the default hover.
+	for _, line := range []int{14, 9} {
+		if h := hoverRoute(doc, ContextAt(routeExamplesYAML, line, 10)); h != nil {
+			t.Errorf("line %d: unexpected route hover", line)
+		}
+	}
+}
@@ -159,6 +159,49 @@ triggers:
 	}
 }
 
+// TestDiagnostics_PipelineRefs checks that mistyped step references are
+// reported on the config value holding them, with a suggestion.
+func TestDiagnostics_PipelineRefs(t *testing.T) {
+	pipelineYAML := `pipelines:
+  get-company:
+    steps:
+      - name: get-company
+        type: step.db_query
+        config:
+          database: db
+          query: SELECT id, name FROM companies
+          mode: single
+      - name: respond
+        type: step.json_response
+        config:
+          body_from: steps.get-compny.row
+`
+	reg := NewRegistry()
+	store := NewDocumentStore()
+	doc := store.Set("file:///pipelines.yaml", pipelineYAML)
+	diags := Diagnostics(reg, doc)
+
+	var found bool
+	for _, d := range diags {
+		if !containsStr(d.Message, "get-compny") {
+			continue
+		}
+		found = true
+		if !containsStr(d.Message, `did you mean "steps.get-company.row"`) {
+			t.Errorf("expected a suggestion, got %q", d.Message)
+		}
+		if d.Severity == nil || *d.Severity != 1 {
+			t.Errorf("expected an error, got severity %v", d.Severity)
+		}
+		if d.Range.Start.Line != 12 || d.Range.Start.Character != 21 {
+			t.Errorf("expected the diagnostic on the body_from value, got %+v", d.Range.Start)
+		}
+	}
+	if !found {
+		t.Errorf("expected diagnostic for get-compny, got: %v", diagMessages(diags))
+	}
+}
+
 // TestCompletions_ModuleType checks that module type completions are returned.
 func TestCompletions_ModuleType(t *testing.T) {
 	reg := NewRegistry()
@@ -37,6 +37,7 @@ type Registry struct {
 	TriggerTypes  map[string]TriggerTypeInfo
 	WorkflowTypes []string
 	DSLSections   map[string]*DSLSectionDoc // section ID → parsed doc from dsl-reference.md
+	Snippets      []schema.LSPSnippet       // snippets offered as completions
 }
 
 // NewRegistry builds a Registry from the schema package's known types and registry.
@@ -47,6 +48,7 @@ func NewRegistry() *Registry {
 		TriggerTypes:  make(map[string]TriggerTypeInfo),
 		WorkflowTypes: schema.KnownWorkflowTypes(),
 		DSLSections:   loadDSLSections(),
+		Snippets:      schema.GetLSPSnippets(),
 	}
 
 	// Build module type info from ModuleSchemaRegistry.
@@ -121,6 +123,23 @@ func NewRegistry() *Registry {
 	return r
 }
 
+// AddSnippets adds snippets to the registry. A snippet replaces an existing
+// snippet with the same prefix.
+func (r *Registry) AddSnippets(snippets []schema.LSPSnippet) {
+	index := make(map[string]int, len(r.Snippets))
+	for i, s := range r.Snippets {
+		index[s.Prefix] = i
+	}
+	for _, s := range snippets {
+		if i, ok := index[s.Prefix]; ok {
+			r.Snippets[i] = s
+			continue
+		}
+		index[s.Prefix] = len(r.Snippets)
+		r.Snippets = append(r.Snippets, s)
+	}
+}
+
 // templateFunctions returns the list of template functions available in pipeline templates.
 func templateFunctions() []string {
 	return []string{
@@ -144,6 +163,10 @@ func templateFunctions() []string {
 		"join",
 		"trimSpace",
 		"urlEncode",
+		"b64enc",
+		"b64dec",
+		"env",
+		"formatTime",
 		"add",
 		"sub",
 		"mul",
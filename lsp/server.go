@@ -1,14 +1,19 @@
 package lsp
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/GoCodeAlone/workflow/schema"
+	"github.com/GoCodeAlone/workflow/version"
 	"github.com/tliron/glsp"
 	protocol "github.com/tliron/glsp/protocol_3_16"
 	glspserver "github.com/tliron/glsp/server"
 )
 
-// Version is set at build time.
-var Version = "dev"
+// Version is the LSP server version. It defaults to the build version from
+// the version package.
+var Version = version.Get().Version
 
 // Server is the workflow LSP server.
 type Server struct {
@@ -43,6 +48,21 @@ func NewServer(pluginDir ...string) *Server {
 	return s
 }
 
+// LoadSnippets adds the snippets in a file written by
+// `wfctl snippets --format lsp` to the completion snippets.
+func (s *Server) LoadSnippets(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read snippets: %w", err)
+	}
+	snippets, err := schema.ParseSnippetsLSP(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	s.registry.AddSnippets(snippets)
+	return nil
+}
+
 // RunStdio starts the LSP server over stdio (blocking).
 func (s *Server) RunStdio() error {
 	return s.server.RunStdio()
@@ -0,0 +1,133 @@
+package lsp
+
+import (
+	"strings"
+
+	"github.com/GoCodeAlone/workflow/schema"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+// snippetCompletions returns completion items for the registry snippets whose
+// scope matches the cursor position. Accepting an item replaces the text
+// typed on the line so far with the snippet body, indented to the line.
+func snippetCompletions(reg *Registry, doc *Document, ctx PositionContext) []protocol.CompletionItem {
+	if reg == nil || doc == nil || len(reg.Snippets) == 0 {
+		return nil
+	}
+	lines := strings.Split(doc.Content, "\n")
+	if ctx.Line >= len(lines) {
+		return nil
+	}
+	scope, indent := snippetScopeAt(lines, ctx.Line, ctx.Character)
+	if scope == "" {
+		return nil
+	}
+
+	kind := protocol.CompletionItemKindSnippet
+	format := protocol.InsertTextFormatSnippet
+	mode := protocol.InsertTextModeAsIs
+	editRange := protocol.Range{
+		Start: protocol.Position{Line: protocol.UInteger(ctx.Line), Character: protocol.UInteger(indent)},
+		End:   protocol.Position{Line: protocol.UInteger(ctx.Line), Character: protocol.UInteger(ctx.Character)},
+	}
+	pad := "\n" + strings.Repeat(" ", indent)
+
+	var items []protocol.CompletionItem
+	for _, s := range reg.Snippets {
+		if s.Scope != scope {
+			continue
+		}
+		detail := s.Name
+		filter := s.Prefix
+		items = append(items, protocol.CompletionItem{
+			Label:            s.Prefix,
+			Kind:             &kind,
+			Detail:           &detail,
+			Documentation:    s.Description,
+			FilterText:       &filter,
+			InsertTextFormat: &format,
+			InsertTextMode:   &mode,
+			TextEdit: protocol.TextEdit{
+				Range:   editRange,
+				NewText: strings.Join(s.Body, pad),
+			},
+		})
+	}
+	return items
+}
+
+// snippetScopeAt returns the snippet scope at a position and the indentation
+// of its line. The scope is empty when the text before the cursor is not the
+// start of a new key or list item.
+func snippetScopeAt(lines []string, line, char int) (string, int) {
+	cur := lines[line]
+	if char > len(cur) {
+		char = len(cur)
+	}
+	typed := cur[:char]
+	if strings.Contains(typed, ":") {
+		return "", 0
+	}
+	indent := leadingSpaces(typed)
+	isItem := strings.HasPrefix(strings.TrimSpace(typed), "-")
+	if indent == 0 && !isItem {
+		return schema.SnippetScopeTopLevel, 0
+	}
+
+	parent := snippetParent(lines, line, indent, isItem)
+	if parent < 0 {
+		return "", 0
+	}
+	switch key := blockKey(lines[parent]); key {
+	case "":
+		return "", 0
+	case "modules":
+		return schema.SnippetScopeModules, indent
+	case "steps":
+		return schema.SnippetScopeSteps, indent
+	case "pipelines":
+		if leadingSpaces(lines[parent]) == 0 && !isItem {
+			return schema.SnippetScopePipelines, indent
+		}
+	default:
+		if isItem {
+			return "", 0
+		}
+		grand := snippetParent(lines, parent, leadingSpaces(lines[parent]), false)
+		if grand >= 0 && leadingSpaces(lines[grand]) == 0 && blockKey(lines[grand]) == "pipelines" {
+			return schema.SnippetScopePipeline, indent
+		}
+	}
+	return "", 0
+}
+
+// snippetParent returns the index of the line that owns a line at the given
+// indentation: the nearest line above it that is less indented, or, for a
+// list item, a key line at the same indentation. It returns -1 when there is
+// none.
+func snippetParent(lines []string, line, indent int, isItem bool) int {
+	for i := line - 1; i >= 0; i-- {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		ind := leadingSpaces(lines[i])
+		if ind < indent || (isItem && ind == indent && !strings.HasPrefix(trimmed, "-")) {
+			return i
+		}
+	}
+	return -1
+}
+
+// blockKey returns the key of a line that opens a block ("key:" with no
+// inline value), or "" for any other line.
+func blockKey(l string) string {
+	trimmed := strings.TrimSpace(l)
+	if i := strings.Index(trimmed, " #"); i >= 0 {
+		trimmed = strings.TrimSpace(trimmed[:i])
+	}
+	if strings.HasPrefix(trimmed, "-") || !strings.HasSuffix(trimmed, ":") {
+		return ""
+	}
+	return strings.TrimSuffix(trimmed, ":")
+}
@@ -0,0 +1,144 @@
+package lsp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/GoCodeAlone/workflow/schema"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+// snippetYAML ends with a blank pipeline key line (4 spaces), a blank
+// pipeline name line (2 spaces) and an empty top-level line.
+var snippetYAML = strings.Join([]string{
+	"modules:",
+	"  - name: server",
+	"    type: http.server",
+	"  -",
+	"pipelines:",
+	"  create-order:",
+	"    steps:",
+	"      - name: parse",
+	"        type: step.request_parse",
+	"      -",
+	"    ",
+	"  ",
+	"",
+}, "\n")
+
+func snippetItems(t *testing.T, content string, line, char int) map[string]protocol.CompletionItem {
+	t.Helper()
+	reg := NewRegistry()
+	doc := NewDocumentStore().Set("file:///test.yaml", content)
+	items := Completions(reg, doc, ContextAt(content, line, char))
+	out := make(map[string]protocol.CompletionItem)
+	for _, item := range items {
+		if item.Kind != nil && *item.Kind == protocol.CompletionItemKindSnippet {
+			out[item.Label] = item
+		}
+	}
+	return out
+}
+
+func TestSnippetCompletions_Scopes(t *testing.T) {
+	tests := []struct {
+		name       string
+		line, char int
+		want       []string
+		notWant    []string
+	}{
+		{"module list item", 3, 4, []string{"mod-http-server", "mod-jobqueue-service"}, []string{"step-set", "app"}},
+		{"step list item", 9, 8, []string{"step-set", "step-db-query"}, []string{"mod-http-server"}},
+		{"pipeline key", 10, 4, []string{"trigger-http"}, []string{"step-set"}},
+		{"pipeline name", 11, 2, []string{"pipeline"}, []string{"trigger-http"}},
+		{"top level", 12, 0, []string{"app", "workflow-http"}, []string{"mod-http-server"}},
+		{"value position", 2, 10, nil, []string{"mod-http-server", "app"}},
+	}
+	content := snippetYAML
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := snippetItems(t, content, tt.line, tt.char)
+			for _, want := range tt.want {
+				if _, ok := items[want]; !ok {
+					t.Errorf("missing snippet %q", want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if _, ok := items[notWant]; ok {
+					t.Errorf("unexpected snippet %q", notWant)
+				}
+			}
+		})
+	}
+}
+
+func TestSnippetCompletions_TextEdit(t *testing.T) {
+	items := snippetItems(t, snippetYAML, 3, 4)
+	item, ok := items["mod-http-server"]
+	if !ok {
+		t.Fatal("missing mod-http-server snippet")
+	}
+	if item.InsertTextFormat == nil || *item.InsertTextFormat != protocol.InsertTextFormatSnippet {
+		t.Error("snippet completion is not in snippet format")
+	}
+	edit, ok := item.TextEdit.(protocol.TextEdit)
+	if !ok {
+		t.Fatalf("TextEdit = %T, want protocol.TextEdit", item.TextEdit)
+	}
+	if edit.Range.Start.Character != 2 || edit.Range.End.Character != 4 {
+		t.Errorf("edit range = %+v, want characters 2-4 to replace the typed dash", edit.Range)
+	}
+	want := "- name: ${1:server}\n    type: http.server\n    config:\n      address: ${2::8080}"
+	if edit.NewText != want {
+		t.Errorf("NewText = %q, want %q", edit.NewText, want)
+	}
+}
+
+func TestServer_LoadSnippets(t *testing.T) {
+	data := []byte(`{"version": 1, "snippets": [
+		{"prefix": "mod-http-server", "name": "Team server", "scope": "modules", "body": ["- name: api", "  type: http.server"]},
+		{"prefix": "mod-team-cache", "name": "Team cache", "scope": "modules", "body": ["- name: cache", "  type: cache.redis"]}
+	]}`)
+	path := filepath.Join(t.TempDir(), "snippets.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewServer()
+	builtin := len(s.registry.Snippets)
+	if err := s.LoadSnippets(path); err != nil {
+		t.Fatalf("LoadSnippets: %v", err)
+	}
+	if got := len(s.registry.Snippets); got != builtin+1 {
+		t.Errorf("snippets = %d, want %d (one replaced, one added)", got, builtin+1)
+	}
+	for _, sn := range s.registry.Snippets {
+		if sn.Prefix == "mod-http-server" && sn.Name != "Team server" {
+			t.Errorf("mod-http-server not replaced: %+v", sn)
+		}
+	}
+
+	bad := filepath.Join(t.TempDir(), "bad.json")
+	if err := os.WriteFile(bad, []byte(`{"version": 1, "snippets": [{"prefix": "x", "scope": "nowhere", "body": ["x"]}]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.LoadSnippets(bad); err == nil || !strings.Contains(err.Error(), `unknown scope "nowhere"`) {
+		t.Errorf("expected unknown scope error, got %v", err)
+	}
+}
+
+func TestRegistry_SnippetsMatchExport(t *testing.T) {
+	data, err := schema.ExportSnippetsLSP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exported, err := schema.ParseSnippetsLSP(data)
+	if err != nil {
+		t.Fatalf("ParseSnippetsLSP: %v", err)
+	}
+	if got := len(NewRegistry().Snippets); got != len(exported) {
+		t.Errorf("registry has %d snippets, export has %d", got, len(exported))
+	}
+}
@@ -0,0 +1,249 @@
+// Package render turns a WorkflowConfig into deployment artifacts: the
+// Terraform output of wfctl manifest and the platform resources and
+// environment references the Kubernetes generator uses. It is kept apart
+// from package manifest, which plugins import through the SDK, so that
+// plugin builds do not link the platform providers.
+package render
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/GoCodeAlone/workflow/config"
+	"github.com/GoCodeAlone/workflow/platform"
+	"github.com/GoCodeAlone/workflow/schema"
+)
+
+// ConfigHash returns the SHA-256 of a config file's contents in the
+// "sha256:<hex>" form used by the engine for configuration hashes.
+func ConfigHash(data []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+}
+
+// GeneratedHeader returns the comment header written at the top of files
+// generated by wfctl manifest for the given output target.
+func GeneratedHeader(target, source string, data []byte) string {
+	return fmt.Sprintf("# Code generated by wfctl manifest --output %s from %s. DO NOT EDIT.\n# config-hash: %s\n", target, source, ConfigHash(data))
+}
+
+// PlatformResource is a platform.resource module resolved to a capability
+// declaration and the platform.provider that provisions it.
+type PlatformResource struct {
+	ModuleName  string
+	Provider    string
+	Declaration platform.CapabilityDeclaration
+}
+
+// PlatformResources collects the platform.resource modules of cfg, sorted
+// by tier and then by name. Each resource is assigned the provider named by
+// its "provider" key, or the only platform.provider module of the config;
+// Provider is empty when the config declares no provider.
+func PlatformResources(cfg *config.WorkflowConfig) ([]PlatformResource, error) {
+	var providers []string
+	resourceNames := make(map[string]string)
+	for _, mod := range cfg.Modules {
+		switch mod.Type {
+		case "platform.provider":
+			name, _ := mod.Config["name"].(string)
+			if name == "" {
+				return nil, fmt.Errorf("platform.provider %q: name is required", mod.Name)
+			}
+			providers = append(providers, name)
+		case "platform.resource":
+			resourceNames[mod.Name] = resourceName(mod)
+		}
+	}
+
+	var out []PlatformResource
+	for _, mod := range cfg.Modules {
+		if mod.Type != "platform.resource" {
+			continue
+		}
+		decl, err := capabilityDeclaration(mod)
+		if err != nil {
+			return nil, err
+		}
+		for _, dep := range mod.DependsOn {
+			if name, ok := resourceNames[dep]; ok {
+				decl.DependsOn = append(decl.DependsOn, name)
+			}
+		}
+
+		provider, _ := mod.Config["provider"].(string)
+		switch {
+		case provider != "":
+			if !containsString(providers, provider) {
+				return nil, fmt.Errorf("platform.resource %q: no platform.provider named %q", mod.Name, provider)
+			}
+		case len(providers) == 1:
+			provider = providers[0]
+		case len(providers) > 1:
+			return nil, fmt.Errorf("platform.resource %q: set provider to one of %s", mod.Name, strings.Join(providers, ", "))
+		}
+		out = append(out, PlatformResource{ModuleName: mod.Name, Provider: provider, Declaration: decl})
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Declaration.Tier != out[j].Declaration.Tier {
+			return out[i].Declaration.Tier < out[j].Declaration.Tier
+		}
+		return out[i].Declaration.Name < out[j].Declaration.Name
+	})
+	return out, nil
+}
+
+func resourceName(mod config.ModuleConfig) string {
+	if name, ok := mod.Config["name"].(string); ok && name != "" {
+		return name
+	}
+	return mod.Name
+}
+
+func capabilityDeclaration(mod config.ModuleConfig) (platform.CapabilityDeclaration, error) {
+	decl := platform.CapabilityDeclaration{
+		Name: resourceName(mod),
+		Tier: platform.TierApplication,
+	}
+	decl.Type, _ = mod.Config["type"].(string)
+	if decl.Type == "" {
+		return decl, fmt.Errorf("platform.resource %q: type is required", mod.Name)
+	}
+
+	switch tier := mod.Config["tier"].(type) {
+	case nil:
+	case string:
+		switch tier {
+		case "infrastructure":
+			decl.Tier = platform.TierInfrastructure
+		case "shared_primitive":
+			decl.Tier = platform.TierSharedPrimitive
+		case "application":
+			decl.Tier = platform.TierApplication
+		default:
+			return decl, fmt.Errorf("platform.resource %q: unknown tier %q", mod.Name, tier)
+		}
+	default:
+		n, ok := toInt(tier)
+		if !ok || !platform.Tier(n).Valid() {
+			return decl, fmt.Errorf("platform.resource %q: invalid tier %v", mod.Name, tier)
+		}
+		decl.Tier = platform.Tier(n)
+	}
+
+	if props, ok := mod.Config["capabilities"].(map[string]any); ok {
+		decl.Properties = props
+	}
+	if raw, ok := mod.Config["constraints"].([]any); ok {
+		for _, item := range raw {
+			c, ok := item.(map[string]any)
+			if !ok {
+				return decl, fmt.Errorf("platform.resource %q: each constraint must be a map", mod.Name)
+			}
+			field, _ := c["field"].(string)
+			op, _ := c["operator"].(string)
+			source, _ := c["source"].(string)
+			decl.Constraints = append(decl.Constraints, platform.Constraint{Field: field, Operator: op, Value: c["value"], Source: source})
+		}
+	}
+	return decl, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// EnvReferences lists the environment variables a config reads through
+// ${NAME} placeholders in module configs.
+type EnvReferences struct {
+	// Secrets are referenced from config fields marked sensitive.
+	Secrets []string `json:"secrets,omitempty"`
+	// Plain are the remaining referenced variables.
+	Plain []string `json:"plain,omitempty"`
+	// Warnings name sensitive fields that hold a literal value instead of
+	// a reference, which generated manifests would embed as-is.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// envRefPattern matches ${NAME} placeholders resolved from the environment.
+// Scheme-qualified references such as ${vault:path} are resolved by a
+// secrets provider at runtime and are not environment variables.
+var envRefPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// AnalyzeEnv finds the environment variables referenced by the module
+// configs of cfg. A variable referenced from any field that the module's
+// schema marks sensitive is reported as a secret.
+func AnalyzeEnv(cfg *config.WorkflowConfig) EnvReferences {
+	registry := schema.NewModuleSchemaRegistry()
+	secret := make(map[string]bool)
+	plain := make(map[string]bool)
+	var refs EnvReferences
+
+	for _, mod := range cfg.Modules {
+		sensitive := make(map[string]bool)
+		if s := registry.Get(mod.Type); s != nil {
+			for _, f := range s.ConfigFields {
+				if f.Sensitive {
+					sensitive[f.Key] = true
+				}
+			}
+		}
+		keys := make([]string, 0, len(mod.Config))
+		for k := range mod.Config {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			names := envNames(mod.Config[key], nil)
+			if sensitive[key] {
+				if lit, ok := mod.Config[key].(string); ok && lit != "" && !strings.Contains(lit, "${") {
+					refs.Warnings = append(refs.Warnings, fmt.Sprintf("module %q: sensitive field %q holds a literal value; use a ${VAR} reference", mod.Name, key))
+				}
+				for _, n := range names {
+					secret[n] = true
+				}
+				continue
+			}
+			for _, n := range names {
+				plain[n] = true
+			}
+		}
+	}
+
+	for n := range secret {
+		refs.Secrets = append(refs.Secrets, n)
+	}
+	for n := range plain {
+		if !secret[n] {
+			refs.Plain = append(refs.Plain, n)
+		}
+	}
+	sort.Strings(refs.Secrets)
+	sort.Strings(refs.Plain)
+	return refs
+}
+
+func envNames(v any, names []string) []string {
+	switch val := v.(type) {
+	case string:
+		for _, m := range envRefPattern.FindAllStringSubmatch(val, -1) {
+			names = append(names, m[1])
+		}
+	case map[string]any:
+		for _, item := range val {
+			names = envNames(item, names)
+		}
+	case []any:
+		for _, item := range val {
+			names = envNames(item, names)
+		}
+	}
+	return names
+}
@@ -0,0 +1,309 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/GoCodeAlone/workflow/config"
+	"github.com/GoCodeAlone/workflow/platform"
+	"github.com/GoCodeAlone/workflow/platform/providers/dockercompose"
+)
+
+// terraformMappers returns the capability mapper of each platform provider
+// that Terraform output supports, keyed by platform.provider name.
+func terraformMappers() map[string]platform.CapabilityMapper {
+	return map[string]platform.CapabilityMapper{
+		dockercompose.ProviderName: dockercompose.NewCapabilityMapper(),
+	}
+}
+
+// dataPaths maps database images to the directory their data volume is
+// mounted at.
+var dataPaths = map[string]string{
+	"postgres": "/var/lib/postgresql/data",
+	"mysql":    "/var/lib/mysql",
+	"redis":    "/data",
+}
+
+// GenerateTerraform renders the platform.resource declarations of cfg as
+// Terraform HCL. Declarations are mapped to resources by the capability
+// mapper of their platform provider, exactly as step.platform_plan plans
+// them, and emitted in tier then name order so that the output is stable
+// for a given config.
+func GenerateTerraform(cfg *config.WorkflowConfig) ([]byte, error) {
+	resources, err := PlatformResources(cfg)
+	if err != nil {
+		return nil, err
+	}
+	mappers := terraformMappers()
+
+	type mapped struct {
+		res   PlatformResource
+		plans []platform.ResourcePlan
+	}
+	var all []mapped
+	// addresses maps a capability name to the Terraform address of its
+	// primary resource, for depends_on.
+	addresses := make(map[string]string)
+	for _, res := range resources {
+		if res.Provider == "" {
+			return nil, fmt.Errorf("platform.resource %q: no platform.provider module is declared", res.ModuleName)
+		}
+		mapper, ok := mappers[res.Provider]
+		if !ok {
+			return nil, fmt.Errorf("platform.resource %q: provider %q has no terraform mapping", res.ModuleName, res.Provider)
+		}
+		decl := res.Declaration
+		if violations := mapper.ValidateConstraints(decl, decl.Constraints); len(violations) > 0 {
+			return nil, fmt.Errorf("platform.resource %q: %s", res.ModuleName, violations[0].Message)
+		}
+		plans, err := mapper.Map(decl, &platform.PlatformContext{Tier: decl.Tier})
+		if err != nil {
+			return nil, fmt.Errorf("platform.resource %q: %w", res.ModuleName, err)
+		}
+		all = append(all, mapped{res: res, plans: plans})
+		if len(plans) > 0 && terraformAddress(plans[0]) != "" {
+			addresses[decl.Name] = terraformAddress(plans[0])
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("terraform {\n  required_providers {\n    docker = {\n      source = \"kreuzwerker/docker\"\n    }\n  }\n}\n")
+	for _, m := range all {
+		decl := m.res.Declaration
+		fmt.Fprintf(&buf, "\n# %s (%s, tier %s)\n", decl.Name, decl.Type, decl.Tier)
+		var deps []string
+		for _, d := range decl.DependsOn {
+			if addr, ok := addresses[d]; ok {
+				deps = append(deps, addr)
+			}
+		}
+		for i, plan := range m.plans {
+			if i > 0 {
+				buf.WriteString("\n")
+			}
+			if err := writeTerraformPlan(&buf, plan, deps); err != nil {
+				return nil, fmt.Errorf("platform.resource %q: %w", m.res.ModuleName, err)
+			}
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func terraformAddress(plan platform.ResourcePlan) string {
+	switch plan.ResourceType {
+	case "docker-compose.service":
+		return "docker_container." + hclIdent(plan.Name)
+	case "docker-compose.volume":
+		return "docker_volume." + hclIdent(plan.Name)
+	case "docker-compose.network":
+		return "docker_network." + hclIdent(plan.Name)
+	}
+	return ""
+}
+
+func writeTerraformPlan(buf *bytes.Buffer, plan platform.ResourcePlan, deps []string) error {
+	id := hclIdent(plan.Name)
+	props := plan.Properties
+	switch plan.ResourceType {
+	case "docker-compose.service":
+		image, _ := props["image"].(string)
+		fmt.Fprintf(buf, "resource \"docker_image\" %q {\n  name = %s\n}\n\n", id, hclString(image))
+		fmt.Fprintf(buf, "resource \"docker_container\" %q {\n", id)
+		fmt.Fprintf(buf, "  name    = %s\n", hclString(plan.Name))
+		fmt.Fprintf(buf, "  image   = docker_image.%s.image_id\n", id)
+		buf.WriteString("  restart = \"unless-stopped\"\n")
+		if cmd, ok := props["command"].(string); ok && cmd != "" {
+			fmt.Fprintf(buf, "  command = %s\n", hclList(strings.Fields(cmd)))
+		}
+		if mem, ok := props["memory"].(string); ok {
+			mb, err := memoryMB(mem)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(buf, "  memory  = %d\n", mb)
+		}
+		if env := envList(props["env"]); len(env) > 0 {
+			fmt.Fprintf(buf, "  env     = %s\n", hclList(env))
+		}
+		ports, err := portPairs(props["ports"])
+		if err != nil {
+			return err
+		}
+		for _, p := range ports {
+			fmt.Fprintf(buf, "\n  ports {\n    internal = %d\n", p[0])
+			if p[1] > 0 {
+				fmt.Fprintf(buf, "    external = %d\n", p[1])
+			}
+			buf.WriteString("  }\n")
+		}
+		if vol, ok := props["volume"].(string); ok {
+			repo, _, _ := strings.Cut(image, ":")
+			if path, ok := dataPaths[repo]; ok {
+				fmt.Fprintf(buf, "\n  volumes {\n    volume_name    = docker_volume.%s.name\n    container_path = %q\n  }\n", hclIdent(vol), path)
+			}
+		}
+		var unmapped []string
+		for _, key := range []string{"cpu", "health_check", "replicas", "storage_gb"} {
+			if _, ok := props[key]; ok {
+				unmapped = append(unmapped, key)
+			}
+		}
+		if len(unmapped) > 0 {
+			fmt.Fprintf(buf, "\n  # Not mapped to docker_container: %s\n", strings.Join(unmapped, ", "))
+		}
+		writeDependsOn(buf, deps)
+		buf.WriteString("}\n")
+	case "docker-compose.volume":
+		driver, _ := props["driver"].(string)
+		fmt.Fprintf(buf, "resource \"docker_volume\" %q {\n  name   = %s\n  driver = %s\n}\n", id, hclString(plan.Name), hclString(driver))
+	case "docker-compose.network":
+		driver, _ := props["driver"].(string)
+		fmt.Fprintf(buf, "resource \"docker_network\" %q {\n  name   = %s\n  driver = %s\n", id, hclString(plan.Name), hclString(driver))
+		if subnet, ok := props["subnet"].(string); ok && subnet != "" {
+			fmt.Fprintf(buf, "\n  ipam_config {\n    subnet = %s\n  }\n", hclString(subnet))
+		}
+		writeDependsOn(buf, deps)
+		buf.WriteString("}\n")
+	case "docker-compose.stub":
+		reason, _ := props["stub_reason"].(string)
+		fmt.Fprintf(buf, "# %s is not provisioned: %s\n", plan.Name, reason)
+	default:
+		return fmt.Errorf("no terraform mapping for resource type %q", plan.ResourceType)
+	}
+	return nil
+}
+
+func writeDependsOn(buf *bytes.Buffer, deps []string) {
+	if len(deps) == 0 {
+		return
+	}
+	fmt.Fprintf(buf, "\n  depends_on = [%s]\n", strings.Join(deps, ", "))
+}
+
+// envList renders an environment map as sorted KEY=value entries.
+func envList(v any) []string {
+	var env []string
+	switch m := v.(type) {
+	case map[string]string:
+		for k, val := range m {
+			env = append(env, k+"="+val)
+		}
+	case map[string]any:
+		for k, val := range m {
+			env = append(env, fmt.Sprintf("%s=%v", k, val))
+		}
+	}
+	sort.Strings(env)
+	return env
+}
+
+// portPairs reads container/host port pairs from a ports property, which
+// holds either bare container ports or maps with container_port and
+// host_port keys.
+func portPairs(v any) ([][2]int, error) {
+	var items []any
+	switch ports := v.(type) {
+	case nil:
+		return nil, nil
+	case []any:
+		items = ports
+	case []map[string]any:
+		for _, p := range ports {
+			items = append(items, p)
+		}
+	default:
+		return nil, fmt.Errorf("ports must be a list")
+	}
+	var pairs [][2]int
+	for _, item := range items {
+		if n, ok := toInt(item); ok {
+			pairs = append(pairs, [2]int{n, 0})
+			continue
+		}
+		p, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("invalid port %v", item)
+		}
+		container, ok := toInt(p["container_port"])
+		if !ok {
+			return nil, fmt.Errorf("port %v: container_port is required", item)
+		}
+		host, _ := toInt(p["host_port"])
+		pairs = append(pairs, [2]int{container, host})
+	}
+	return pairs, nil
+}
+
+// memoryMB converts a memory quantity such as "512Mi", "512M" or "1Gi" to
+// megabytes.
+func memoryMB(s string) (int, error) {
+	units := []struct {
+		suffix string
+		mb     float64
+	}{{"Gi", 1024}, {"Mi", 1}, {"G", 1000}, {"M", 1}}
+	for _, u := range units {
+		if num, ok := strings.CutSuffix(s, u.suffix); ok {
+			f, err := strconv.ParseFloat(num, 64)
+			if err != nil {
+				break
+			}
+			return int(f * u.mb), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid memory %q (use e.g. 512Mi or 1Gi)", s)
+}
+
+// hclIdent turns a name into a valid Terraform identifier.
+func hclIdent(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+			b.WriteRune(r)
+		case r >= '0' && r <= '9', r == '-':
+			if i == 0 {
+				b.WriteRune('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+// hclString quotes s as an HCL string literal, escaping template sequences
+// so that values are emitted verbatim.
+func hclString(s string) string {
+	q := strconv.Quote(s)
+	q = strings.ReplaceAll(q, "${", "$${")
+	return strings.ReplaceAll(q, "%{", "%%{")
+}
+
+func hclList(items []string) string {
+	quoted := make([]string, len(items))
+	for i, s := range items {
+		quoted[i] = hclString(s)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+func toInt(v any) (int, bool) {
+	switch val := v.(type) {
+	case int:
+		return val, true
+	case int64:
+		return int(val), true
+	case float64:
+		return int(val), true
+	case string:
+		n, err := strconv.Atoi(val)
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,134 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/GoCodeAlone/workflow/config"
+	"github.com/GoCodeAlone/workflow/platform"
+)
+
+func platformConfig() *config.WorkflowConfig {
+	return &config.WorkflowConfig{
+		Modules: []config.ModuleConfig{
+			{Name: "compose", Type: "platform.provider", Config: map[string]any{"name": "docker-compose"}},
+			{Name: "worker", Type: "platform.resource", DependsOn: []string{"orders-db"}, Config: map[string]any{
+				"type": "container_runtime",
+				"capabilities": map[string]any{
+					"image":  "example/worker:1.2",
+					"memory": "1Gi",
+					"ports":  []any{9000},
+					"env":    map[string]any{"TOKEN": "${TOKEN}"},
+				},
+			}},
+			{Name: "orders-db", Type: "platform.resource", Config: map[string]any{
+				"type":         "database",
+				"tier":         "shared_primitive",
+				"capabilities": map[string]any{"engine": "postgresql", "storage_gb": 10},
+			}},
+			{Name: "net", Type: "platform.resource", Config: map[string]any{
+				"name":         "backbone",
+				"type":         "network",
+				"tier":         1,
+				"capabilities": map[string]any{"cidr": "10.0.0.0/16"},
+			}},
+		},
+	}
+}
+
+func TestPlatformResources_SortedByTierAndName(t *testing.T) {
+	res, err := PlatformResources(platformConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		name string
+		tier platform.Tier
+	}{{"backbone", platform.TierInfrastructure}, {"orders-db", platform.TierSharedPrimitive}, {"worker", platform.TierApplication}}
+	if len(res) != len(want) {
+		t.Fatalf("got %d resources, want %d", len(res), len(want))
+	}
+	for i, w := range want {
+		if res[i].Declaration.Name != w.name || res[i].Declaration.Tier != w.tier || res[i].Provider != "docker-compose" {
+			t.Errorf("resource %d = %+v, want %s at tier %s", i, res[i], w.name, w.tier)
+		}
+	}
+	if deps := res[2].Declaration.DependsOn; len(deps) != 1 || deps[0] != "orders-db" {
+		t.Errorf("worker dependsOn = %v", deps)
+	}
+}
+
+func TestPlatformResources_AmbiguousProvider(t *testing.T) {
+	cfg := platformConfig()
+	cfg.Modules = append(cfg.Modules, config.ModuleConfig{Name: "aws", Type: "platform.provider", Config: map[string]any{"name": "aws"}})
+	if _, err := PlatformResources(cfg); err == nil || !strings.Contains(err.Error(), "set provider") {
+		t.Fatalf("expected ambiguous provider error, got %v", err)
+	}
+}
+
+func TestGenerateTerraform(t *testing.T) {
+	out, err := GenerateTerraform(platformConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	hcl := string(out)
+	for _, want := range []string{
+		`source = "kreuzwerker/docker"`,
+		`resource "docker_network" "backbone"`,
+		`subnet = "10.0.0.0/16"`,
+		`resource "docker_image" "orders-db"`,
+		`volume_name    = docker_volume.orders-db-data.name`,
+		`resource "docker_volume" "orders-db-data"`,
+		`image   = docker_image.worker.image_id`,
+		`memory  = 1024`,
+		`env     = ["TOKEN=$${TOKEN}"]`,
+		`depends_on = [docker_container.orders-db]`,
+	} {
+		if !strings.Contains(hcl, want) {
+			t.Errorf("output missing %q:\n%s", want, hcl)
+		}
+	}
+	if i, j := strings.Index(hcl, "backbone"), strings.Index(hcl, "# worker"); i > j {
+		t.Error("infrastructure tier should come before application tier")
+	}
+
+	again, _ := GenerateTerraform(platformConfig())
+	if string(again) != hcl {
+		t.Error("output is not deterministic")
+	}
+}
+
+func TestGenerateTerraform_UnsupportedProvider(t *testing.T) {
+	cfg := platformConfig()
+	cfg.Modules[0].Config["name"] = "aws"
+	if _, err := GenerateTerraform(cfg); err == nil || !strings.Contains(err.Error(), "no terraform mapping") {
+		t.Fatalf("expected unsupported provider error, got %v", err)
+	}
+}
+
+func TestAnalyzeEnv(t *testing.T) {
+	cfg := &config.WorkflowConfig{
+		Modules: []config.ModuleConfig{
+			{Name: "db", Type: "database.workflow", Config: map[string]any{"driver": "postgres", "dsn": "${DATABASE_URL}"}},
+			{Name: "auth", Type: "auth.jwt", Config: map[string]any{"secret": "hunter2", "issuer": "${ISSUER}"}},
+			{Name: "cache", Type: "cache.redis", Config: map[string]any{"address": "${REDIS_ADDR}", "password": "${vault:redis/password}"}},
+		},
+	}
+	refs := AnalyzeEnv(cfg)
+	if strings.Join(refs.Secrets, ",") != "DATABASE_URL" {
+		t.Errorf("Secrets = %v", refs.Secrets)
+	}
+	if strings.Join(refs.Plain, ",") != "ISSUER,REDIS_ADDR" {
+		t.Errorf("Plain = %v", refs.Plain)
+	}
+	if len(refs.Warnings) != 1 || !strings.Contains(refs.Warnings[0], `"secret"`) {
+		t.Errorf("Warnings = %v", refs.Warnings)
+	}
+}
+
+func TestGeneratedHeader(t *testing.T) {
+	h := GeneratedHeader("terraform", "app.yaml", []byte("modules: []\n"))
+	if !strings.HasPrefix(h, "# Code generated by wfctl manifest --output terraform from app.yaml. DO NOT EDIT.\n# config-hash: sha256:") {
+		t.Errorf("unexpected header %q", h)
+	}
+}
@@ -16,14 +16,16 @@ import (
 
 	"github.com/GoCodeAlone/workflow/config"
 	"github.com/GoCodeAlone/workflow/schema"
+	"github.com/GoCodeAlone/workflow/version"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
 
-// Version is the MCP server version, set at build time.
-var Version = "dev"
+// Version is the MCP server version. It defaults to the build version from
+// the version package; wfctl overrides it with its own release stamp.
+var Version = version.Get().Version
 
 // EngineProvider is the interface that the MCP server requires from the
 // workflow engine. It is kept intentionally narrow so that the mcp package
@@ -738,6 +738,9 @@ func mcpExtractPipelineRoutes(pipelines map[string]any, includeSchemas bool, gen
 				mcpApplyPipelineSchemas(gen, name, method, path, stepsRaw)
 			}
 		}
+		if examples, ok := triggerConfig["examples"]; ok {
+			_ = gen.SetRouteExamples(method, path, examples)
+		}
 
 		routes = append(routes, route)
 	}
@@ -0,0 +1,35 @@
+package module
+
+import (
+	"net/http"
+
+	evstore "github.com/GoCodeAlone/workflow/store"
+	"github.com/GoCodeAlone/workflow/tenant"
+)
+
+// AdminTenantScope wraps the timeline, replay and backfill admin APIs so they
+// are scoped to the tenant of the authenticated caller rather than to
+// whatever tenant the request names. The tenant comes from the tenant_id
+// claim of the caller's JWT, validated with jwtSecret unless the auth
+// middleware already put the claims in the context. A caller without a
+// tenant is granted unscoped access only when its role is "admin"; any other
+// caller without a tenant is rejected, as is an unauthenticated one.
+func AdminTenantScope(jwtSecret string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, err := requestClaims(r, jwtSecret)
+		if err != nil {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+		ctx := r.Context()
+		if tenantID, _ := claims["tenant_id"].(string); tenantID != "" {
+			ctx = tenant.ContextWithTenant(ctx, tenantID)
+		} else if role, _ := claims["role"].(string); role == "admin" {
+			ctx = evstore.WithUnscopedAccess(ctx)
+		} else {
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": "caller is not scoped to a tenant"})
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
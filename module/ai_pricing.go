@@ -0,0 +1,60 @@
+package module
+
+import (
+	"fmt"
+
+	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow/ai"
+)
+
+// AIPricingModule configures the per-1K-token prices AI steps use to cost
+// their model calls. Prices override those of the registered models.
+type AIPricingModule struct {
+	name   string
+	prices map[string]ai.ModelPrice
+}
+
+// NewAIPricingModule parses the "models" config, a map of model ID to
+// {inputPer1K, outputPer1K}, and applies the prices to registry.
+func NewAIPricingModule(name string, cfg map[string]any, registry *ai.AIModelRegistry) (*AIPricingModule, error) {
+	m := &AIPricingModule{name: name, prices: make(map[string]ai.ModelPrice)}
+	models, _ := cfg["models"].(map[string]any)
+	for modelID, raw := range models {
+		entry, ok := raw.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("ai.pricing %q: model %q must be a map of inputPer1K and outputPer1K", name, modelID)
+		}
+		var price ai.ModelPrice
+		for key, dst := range map[string]*float64{"inputPer1K": &price.InputPer1K, "outputPer1K": &price.OutputPer1K} {
+			v, ok := entry[key]
+			if !ok {
+				continue
+			}
+			f, ok := toFloat64(v)
+			if !ok || f < 0 {
+				return nil, fmt.Errorf("ai.pricing %q: model %q %s must be a non-negative number", name, modelID, key)
+			}
+			*dst = f
+		}
+		m.prices[modelID] = price
+	}
+	for modelID, price := range m.prices {
+		registry.SetPrice(modelID, price)
+	}
+	return m, nil
+}
+
+// Name returns the module name.
+func (m *AIPricingModule) Name() string {
+	return m.name
+}
+
+// Init is a no-op; prices are applied when the module is created.
+func (m *AIPricingModule) Init(_ modular.Application) error {
+	return nil
+}
+
+// Prices returns the configured prices by model ID.
+func (m *AIPricingModule) Prices() map[string]ai.ModelPrice {
+	return m.prices
+}
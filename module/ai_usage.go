@@ -0,0 +1,245 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/ai"
+)
+
+// ErrAIBudgetExceeded is matched (with errors.Is) by the error AI steps fail
+// with when their workflow is over a budget whose action is "fail".
+var ErrAIBudgetExceeded = errors.New("AI budget exceeded")
+
+// AIBudgetExceededError reports that a workflow used its monthly AI budget.
+type AIBudgetExceededError struct {
+	WorkflowID string
+	Period     string
+	Budget     AIBudget
+	Usage      AIUsageTotals
+}
+
+func (e *AIBudgetExceededError) Error() string {
+	return fmt.Sprintf("AI budget exceeded for workflow %s in %s: used %d tokens costing %.4f (budget: %d tokens, %.4f cost)",
+		e.WorkflowID, e.Period, e.Usage.TotalTokens, e.Usage.Cost, e.Budget.MonthlyTokens, e.Budget.MonthlyCost)
+}
+
+// Is makes errors.Is(err, ErrAIBudgetExceeded) true.
+func (e *AIBudgetExceededError) Is(target error) bool { return target == ErrAIBudgetExceeded }
+
+// AIBudgetCheck returns an error when AI steps may not call their model,
+// typically an *AIBudgetExceededError.
+type AIBudgetCheck func(ctx context.Context) error
+
+// aiBudgetContextKey is the context key of the AI budget check.
+type aiBudgetContextKey struct{}
+
+// WithAIBudgetCheck returns a context whose AI steps run check before calling
+// their model and fail with its error.
+func WithAIBudgetCheck(ctx context.Context, check AIBudgetCheck) context.Context {
+	return context.WithValue(ctx, aiBudgetContextKey{}, check)
+}
+
+// checkAIBudget runs the context's AI budget check, if any.
+func checkAIBudget(ctx context.Context) error {
+	if check, ok := ctx.Value(aiBudgetContextKey{}).(AIBudgetCheck); ok && check != nil {
+		return check(ctx)
+	}
+	return nil
+}
+
+// AIUsageMeter receives the AI usage recorded by an ExecutionTracker, so that
+// billing meters the same numbers the usage API reports. The billing
+// package's meters implement it.
+type AIUsageMeter interface {
+	RecordAIUsage(ctx context.Context, tenantID, pipelineName string, inputTokens, outputTokens int64, cost float64) error
+}
+
+// newAIUsage prices the usage of a model call made through a provider. The
+// model the provider reports is priced, falling back to the requested model
+// when only that one has a price (providers often report a dated version).
+func newAIUsage(registry *ai.AIModelRegistry, provider, requested, reported string, usage ai.TokenUsage) *AIUsage {
+	model := reported
+	if model == "" {
+		model = requested
+	}
+	price, ok := registry.Price(model)
+	if !ok && requested != "" {
+		price, _ = registry.Price(requested)
+	}
+	return &AIUsage{
+		Provider:     provider,
+		Model:        model,
+		InputTokens:  usage.InputTokens,
+		OutputTokens: usage.OutputTokens,
+		Cost:         price.Cost(usage),
+	}
+}
+
+// aiUsageOutput returns the "usage" output of an AI step.
+func aiUsageOutput(u *AIUsage) map[string]any {
+	return map[string]any{
+		"input_tokens":  u.InputTokens,
+		"output_tokens": u.OutputTokens,
+		"cost":          u.Cost,
+	}
+}
+
+// aiUsageReporter is implemented by step errors that carry the usage of the
+// model calls made before the step failed.
+type aiUsageReporter interface {
+	AIUsage() *AIUsage
+}
+
+// aiUsageEventData returns the "ai_usage" field of a step event.
+func aiUsageEventData(u *AIUsage) map[string]any {
+	return map[string]any{
+		"provider":      u.Provider,
+		"model":         u.Model,
+		"input_tokens":  u.InputTokens,
+		"output_tokens": u.OutputTokens,
+		"cost":          u.Cost,
+	}
+}
+
+// aiUsageFromEventData parses the "ai_usage" field of a step event.
+func aiUsageFromEventData(data map[string]any) AIUsage {
+	u := AIUsage{}
+	u.Provider, _ = data["provider"].(string)
+	u.Model, _ = data["model"].(string)
+	if n, ok := toFloat64(data["input_tokens"]); ok {
+		u.InputTokens = int(n)
+	}
+	if n, ok := toFloat64(data["output_tokens"]); ok {
+		u.OutputTokens = int(n)
+	}
+	u.Cost, _ = toFloat64(data["cost"])
+	return u
+}
+
+// SetAIUsageMeter sets the optional billing meter that AI usage is also
+// recorded to. The tenant is the company owning the tracked workflow.
+func (t *ExecutionTracker) SetAIUsageMeter(m AIUsageMeter) {
+	t.AIUsageMeter = m
+}
+
+// recordAIUsage stores the AI usage carried by a step.completed or
+// step.failed event, forwards it to the billing meter and raises the
+// workflow's budget alert when the usage takes it over budget. Recording is
+// best-effort like the rest of tracking.
+func (t *ExecutionTracker) recordAIUsage(ctx context.Context, state *executionState, executionID string, data map[string]any) {
+	raw, ok := data["ai_usage"].(map[string]any)
+	if !ok {
+		return
+	}
+	pipeline := ""
+	if state != nil {
+		pipeline = state.pipeline
+	}
+	usage := aiUsageFromEventData(raw)
+	stepName, _ := data["step_name"].(string)
+
+	rec := &AIUsageRecord{
+		ExecutionID:  executionID,
+		WorkflowID:   t.WorkflowID,
+		Pipeline:     pipeline,
+		StepName:     stepName,
+		Provider:     usage.Provider,
+		Model:        usage.Model,
+		InputTokens:  int64(usage.InputTokens),
+		OutputTokens: int64(usage.OutputTokens),
+		Cost:         usage.Cost,
+	}
+	if err := t.Store.RecordAIUsage(rec); err != nil {
+		slog.Warn("execution tracker: failed to record AI usage", "execution_id", executionID, "step", stepName, "error", err)
+		return
+	}
+
+	if t.AIUsageMeter != nil {
+		if tenantID := t.tenantID(); tenantID != "" {
+			if err := t.AIUsageMeter.RecordAIUsage(ctx, tenantID, pipeline, rec.InputTokens, rec.OutputTokens, rec.Cost); err != nil {
+				slog.Warn("execution tracker: failed to meter AI usage", "tenant_id", tenantID, "error", err)
+			}
+		}
+	}
+
+	t.alertAIBudget(ctx, rec.CreatedAt)
+}
+
+// alertAIBudget logs and publishes an ai.budget_exceeded event the first time
+// in a month that the workflow's usage exceeds its budget.
+func (t *ExecutionTracker) alertAIBudget(ctx context.Context, now time.Time) {
+	budget, err := t.Store.GetAIBudget(t.WorkflowID)
+	if err != nil {
+		return
+	}
+	usage, err := t.Store.MonthlyAIUsage(t.WorkflowID, now)
+	if err != nil || !budget.Exceeded(usage) {
+		return
+	}
+	period := aiUsageMonth(now)
+	if first, err := t.Store.MarkAIBudgetAlerted(t.WorkflowID, period); err != nil || !first {
+		return
+	}
+
+	slog.Warn("AI budget exceeded",
+		"workflow_id", t.WorkflowID, "period", period, "action", budget.Action,
+		"total_tokens", usage.TotalTokens, "cost", usage.Cost,
+		"monthly_tokens", budget.MonthlyTokens, "monthly_cost", budget.MonthlyCost)
+	if t.Webhooks != nil {
+		_ = t.Webhooks.Publish(context.WithoutCancel(ctx), AIBudgetWebhookEvent(budget, period, usage))
+	}
+}
+
+// checkAIBudget is the AIBudgetCheck of tracked executions: it fails when the
+// workflow's budget action is "fail" and its usage this month has reached the
+// budget.
+func (t *ExecutionTracker) checkAIBudget(_ context.Context) error {
+	budget, err := t.Store.GetAIBudget(t.WorkflowID)
+	if err != nil || budget.Action != AIBudgetActionFail {
+		return nil
+	}
+	now := time.Now()
+	usage, err := t.Store.MonthlyAIUsage(t.WorkflowID, now)
+	if err != nil || !budget.Exceeded(usage) {
+		return nil
+	}
+	return &AIBudgetExceededError{WorkflowID: t.WorkflowID, Period: aiUsageMonth(now), Budget: *budget, Usage: usage}
+}
+
+// tenantID returns the billing tenant of the tracked workflow: the company
+// owning its project. It is looked up once.
+func (t *ExecutionTracker) tenantID() string {
+	t.tenantOnce.Do(func() {
+		wf, err := t.Store.GetWorkflow(t.WorkflowID)
+		if err != nil {
+			return
+		}
+		p, err := t.Store.GetProject(wf.ProjectID)
+		if err != nil {
+			return
+		}
+		t.tenant = p.CompanyID
+	})
+	return t.tenant
+}
+
+// AIBudgetWebhookEvent builds the event published when a workflow's AI usage
+// first exceeds its monthly budget.
+func AIBudgetWebhookEvent(budget *AIBudget, period string, usage AIUsageTotals) WebhookEvent {
+	return WebhookEvent{
+		Type:       WebhookEventAIBudgetExceeded,
+		WorkflowID: budget.WorkflowID,
+		Data: map[string]any{
+			"period":         period,
+			"action":         budget.Action,
+			"monthly_tokens": budget.MonthlyTokens,
+			"monthly_cost":   budget.MonthlyCost,
+			"total_tokens":   usage.TotalTokens,
+			"cost":           usage.Cost,
+		},
+	}
+}
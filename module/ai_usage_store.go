@@ -0,0 +1,379 @@
+package module
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AI budget actions, taken when a workflow's AI usage exceeds its monthly
+// budget. Both log the first time the budget is exceeded in a month and
+// publish an ai.budget_exceeded webhook event; fail also makes further AI
+// steps of the workflow fail fast until the month ends or the budget is
+// raised.
+const (
+	AIBudgetActionNotify = "notify"
+	AIBudgetActionFail   = "fail"
+)
+
+// AI usage series bucket sizes.
+const (
+	AIUsageBucketHour  = "hour"
+	AIUsageBucketDay   = "day"
+	AIUsageBucketMonth = "month"
+)
+
+// aiUsageBucketFormats maps a bucket size to the SQLite strftime format of
+// its bucket key.
+var aiUsageBucketFormats = map[string]string{
+	AIUsageBucketHour:  "%Y-%m-%dT%H:00:00Z",
+	AIUsageBucketDay:   "%Y-%m-%d",
+	AIUsageBucketMonth: "%Y-%m",
+}
+
+// AIUsageRecord is the usage of one AI step run.
+type AIUsageRecord struct {
+	ID           string    `json:"id"`
+	ExecutionID  string    `json:"execution_id"`
+	WorkflowID   string    `json:"workflow_id"`
+	Pipeline     string    `json:"pipeline"`
+	StepName     string    `json:"step_name"`
+	Provider     string    `json:"provider,omitempty"`
+	Model        string    `json:"model,omitempty"`
+	InputTokens  int64     `json:"input_tokens"`
+	OutputTokens int64     `json:"output_tokens"`
+	Cost         float64   `json:"cost"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// AIUsageTotals sums the usage of a number of AI step runs.
+type AIUsageTotals struct {
+	Calls        int64   `json:"calls"`
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	TotalTokens  int64   `json:"total_tokens"`
+	Cost         float64 `json:"cost"`
+}
+
+// AIPipelineUsage is the usage rolled up for one pipeline.
+type AIPipelineUsage struct {
+	Pipeline string `json:"pipeline"`
+	AIUsageTotals
+}
+
+// AIExecutionUsage is the usage rolled up for one execution.
+type AIExecutionUsage struct {
+	ExecutionID string `json:"execution_id"`
+	WorkflowID  string `json:"workflow_id"`
+	Pipeline    string `json:"pipeline"`
+	UpdatedAt   string `json:"updated_at"`
+	AIUsageTotals
+}
+
+// AIUsageBucket is the usage in one time bucket of a series.
+type AIUsageBucket struct {
+	Bucket string `json:"bucket"`
+	AIUsageTotals
+}
+
+// AIUsageFilter selects the AI usage a report covers. Empty fields match
+// everything; From is inclusive and To exclusive.
+type AIUsageFilter struct {
+	WorkflowID  string
+	Pipeline    string
+	ExecutionID string
+	Model       string
+	From        time.Time
+	To          time.Time
+	Bucket      string
+}
+
+// AIUsageReport is the AI usage matching a filter: its totals, its rollups
+// per pipeline and per execution (most recent first), and a time series.
+type AIUsageReport struct {
+	Totals     AIUsageTotals      `json:"totals"`
+	Pipelines  []AIPipelineUsage  `json:"pipelines"`
+	Executions []AIExecutionUsage `json:"executions"`
+	Bucket     string             `json:"bucket"`
+	Series     []AIUsageBucket    `json:"series"`
+}
+
+// maxAIUsageReportExecutions bounds the executions listed in a report.
+const maxAIUsageReportExecutions = 100
+
+// AIBudget is a monthly AI token and/or cost budget for a workflow. A zero
+// limit is not enforced.
+type AIBudget struct {
+	WorkflowID    string  `json:"workflow_id"`
+	MonthlyTokens int64   `json:"monthly_tokens,omitempty"`
+	MonthlyCost   float64 `json:"monthly_cost,omitempty"`
+	Action        string  `json:"action"`
+	UpdatedAt     string  `json:"updated_at"`
+}
+
+// Validate checks that the budget sets a limit and a known action.
+func (b *AIBudget) Validate() error {
+	if b.MonthlyTokens < 0 || b.MonthlyCost < 0 {
+		return errors.New("monthly_tokens and monthly_cost must not be negative")
+	}
+	if b.MonthlyTokens == 0 && b.MonthlyCost == 0 {
+		return errors.New("monthly_tokens or monthly_cost is required")
+	}
+	switch b.Action {
+	case AIBudgetActionNotify, AIBudgetActionFail:
+	default:
+		return fmt.Errorf("action must be %q or %q", AIBudgetActionNotify, AIBudgetActionFail)
+	}
+	return nil
+}
+
+// Exceeded reports whether usage has reached one of the budget's limits.
+func (b *AIBudget) Exceeded(usage AIUsageTotals) bool {
+	return (b.MonthlyTokens > 0 && usage.TotalTokens >= b.MonthlyTokens) ||
+		(b.MonthlyCost > 0 && usage.Cost >= b.MonthlyCost)
+}
+
+// aiUsageMonth returns the UTC month of t as "2006-01".
+func aiUsageMonth(t time.Time) string {
+	return t.UTC().Format("2006-01")
+}
+
+// RecordAIUsage stores the usage of an AI step run and adds it to the
+// execution and the workflow/pipeline/day rollups.
+func (s *V1Store) RecordAIUsage(rec *AIUsageRecord) error {
+	if rec.ID == "" {
+		rec.ID = newID()
+	}
+	if rec.CreatedAt.IsZero() {
+		rec.CreatedAt = time.Now()
+	}
+	createdAt := rec.CreatedAt.UTC().Format(time.RFC3339)
+	day := rec.CreatedAt.UTC().Format("2006-01-02")
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`INSERT INTO ai_usage (id, execution_id, workflow_id, pipeline, step_name, provider, model, input_tokens, output_tokens, cost, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		rec.ID, rec.ExecutionID, rec.WorkflowID, rec.Pipeline, rec.StepName, rec.Provider, rec.Model,
+		rec.InputTokens, rec.OutputTokens, rec.Cost, createdAt); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO ai_usage_executions (execution_id, workflow_id, pipeline, calls, input_tokens, output_tokens, cost, updated_at)
+		VALUES (?, ?, ?, 1, ?, ?, ?, ?)
+		ON CONFLICT (execution_id) DO UPDATE SET
+			calls = calls + 1,
+			input_tokens = input_tokens + excluded.input_tokens,
+			output_tokens = output_tokens + excluded.output_tokens,
+			cost = cost + excluded.cost,
+			updated_at = excluded.updated_at`,
+		rec.ExecutionID, rec.WorkflowID, rec.Pipeline, rec.InputTokens, rec.OutputTokens, rec.Cost, createdAt); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO ai_usage_daily (workflow_id, pipeline, day, calls, input_tokens, output_tokens, cost)
+		VALUES (?, ?, ?, 1, ?, ?, ?)
+		ON CONFLICT (workflow_id, pipeline, day) DO UPDATE SET
+			calls = calls + 1,
+			input_tokens = input_tokens + excluded.input_tokens,
+			output_tokens = output_tokens + excluded.output_tokens,
+			cost = cost + excluded.cost`,
+		rec.WorkflowID, rec.Pipeline, day, rec.InputTokens, rec.OutputTokens, rec.Cost); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// MonthlyAIUsage returns a workflow's AI usage in the UTC month containing t,
+// from the daily rollup.
+func (s *V1Store) MonthlyAIUsage(workflowID string, t time.Time) (AIUsageTotals, error) {
+	var totals AIUsageTotals
+	err := s.db.QueryRow(`SELECT COALESCE(SUM(calls), 0), COALESCE(SUM(input_tokens), 0), COALESCE(SUM(output_tokens), 0), COALESCE(SUM(cost), 0)
+		FROM ai_usage_daily WHERE workflow_id = ? AND substr(day, 1, 7) = ?`,
+		workflowID, aiUsageMonth(t)).Scan(&totals.Calls, &totals.InputTokens, &totals.OutputTokens, &totals.Cost)
+	totals.TotalTokens = totals.InputTokens + totals.OutputTokens
+	return totals, err
+}
+
+// AIUsageReport aggregates the AI usage matching f. Totals, per-pipeline
+// rollups and the series come from the per-step records, so every filter
+// applies to them; the execution rollups are listed for the workflow,
+// pipeline, execution and time filters.
+func (s *V1Store) AIUsageReport(f AIUsageFilter) (*AIUsageReport, error) {
+	if f.Bucket == "" {
+		f.Bucket = AIUsageBucketDay
+	}
+	format, ok := aiUsageBucketFormats[f.Bucket]
+	if !ok {
+		return nil, fmt.Errorf("bucket must be %q, %q or %q", AIUsageBucketHour, AIUsageBucketDay, AIUsageBucketMonth)
+	}
+
+	var conds []string
+	var args []any
+	add := func(cond string, arg any) {
+		conds = append(conds, cond)
+		args = append(args, arg)
+	}
+	if f.WorkflowID != "" {
+		add("workflow_id = ?", f.WorkflowID)
+	}
+	if f.Pipeline != "" {
+		add("pipeline = ?", f.Pipeline)
+	}
+	if f.ExecutionID != "" {
+		add("execution_id = ?", f.ExecutionID)
+	}
+	execConds, execArgs := append([]string(nil), conds...), append([]any(nil), args...)
+	if f.Model != "" {
+		add("model = ?", f.Model)
+	}
+	if !f.From.IsZero() {
+		from := f.From.UTC().Format(time.RFC3339)
+		add("created_at >= ?", from)
+		execConds, execArgs = append(execConds, "updated_at >= ?"), append(execArgs, from)
+	}
+	if !f.To.IsZero() {
+		to := f.To.UTC().Format(time.RFC3339)
+		add("created_at < ?", to)
+		execConds, execArgs = append(execConds, "updated_at < ?"), append(execArgs, to)
+	}
+	where, execWhere := sqlWhere(conds), sqlWhere(execConds)
+
+	const sums = `COUNT(*), COALESCE(SUM(input_tokens), 0), COALESCE(SUM(output_tokens), 0), COALESCE(SUM(cost), 0)`
+	report := &AIUsageReport{
+		Bucket:     f.Bucket,
+		Pipelines:  []AIPipelineUsage{},
+		Executions: []AIExecutionUsage{},
+		Series:     []AIUsageBucket{},
+	}
+
+	if err := scanAIUsageTotals(s.db.QueryRow(`SELECT `+sums+` FROM ai_usage`+where, args...), &report.Totals); err != nil { //nolint:gosec // G202: where holds only fixed conditions; values are parameters
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`SELECT pipeline, `+sums+` FROM ai_usage`+where+` GROUP BY pipeline ORDER BY pipeline`, args...) //nolint:gosec // G202: see above
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var p AIPipelineUsage
+		if err := scanAIUsageTotals(rows, &p.AIUsageTotals, &p.Pipeline); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		report.Pipelines = append(report.Pipelines, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = s.db.Query(`SELECT strftime('`+format+`', created_at) AS bucket, `+sums+` FROM ai_usage`+where+` GROUP BY bucket ORDER BY bucket`, args...) //nolint:gosec // G202: format is a fixed strftime pattern
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var b AIUsageBucket
+		if err := scanAIUsageTotals(rows, &b.AIUsageTotals, &b.Bucket); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		report.Series = append(report.Series, b)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = s.db.Query(`SELECT execution_id, workflow_id, pipeline, updated_at, calls, input_tokens, output_tokens, cost
+		FROM ai_usage_executions`+execWhere+` ORDER BY updated_at DESC LIMIT ?`, append(execArgs, maxAIUsageReportExecutions)...) //nolint:gosec // G202: see above
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var e AIExecutionUsage
+		if err := rows.Scan(&e.ExecutionID, &e.WorkflowID, &e.Pipeline, &e.UpdatedAt,
+			&e.Calls, &e.InputTokens, &e.OutputTokens, &e.Cost); err != nil {
+			return nil, err
+		}
+		e.TotalTokens = e.InputTokens + e.OutputTokens
+		report.Executions = append(report.Executions, e)
+	}
+	return report, rows.Err()
+}
+
+// sqlWhere joins conditions into a WHERE clause, or returns "" for none.
+func sqlWhere(conds []string) string {
+	if len(conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conds, " AND ")
+}
+
+// scanAIUsageTotals scans the leading key columns followed by the count and
+// sums selected by AIUsageReport.
+func scanAIUsageTotals(row rowScanner, totals *AIUsageTotals, keys ...any) error {
+	dest := append(keys, &totals.Calls, &totals.InputTokens, &totals.OutputTokens, &totals.Cost)
+	if err := row.Scan(dest...); err != nil {
+		return err
+	}
+	totals.TotalTokens = totals.InputTokens + totals.OutputTokens
+	return nil
+}
+
+// GetAIBudget returns a workflow's AI budget.
+func (s *V1Store) GetAIBudget(workflowID string) (*AIBudget, error) {
+	b := &AIBudget{}
+	err := s.db.QueryRow(`SELECT workflow_id, monthly_tokens, monthly_cost, action, updated_at FROM ai_budgets WHERE workflow_id = ?`,
+		workflowID).Scan(&b.WorkflowID, &b.MonthlyTokens, &b.MonthlyCost, &b.Action, &b.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// SetAIBudget creates or replaces a workflow's AI budget. Changing the budget
+// re-arms its alert for the current month.
+func (s *V1Store) SetAIBudget(b *AIBudget) error {
+	b.UpdatedAt = nowStr()
+	_, err := s.db.Exec(`INSERT INTO ai_budgets (workflow_id, monthly_tokens, monthly_cost, action, alerted_period, updated_at)
+		VALUES (?, ?, ?, ?, '', ?)
+		ON CONFLICT (workflow_id) DO UPDATE SET
+			monthly_tokens = excluded.monthly_tokens,
+			monthly_cost = excluded.monthly_cost,
+			action = excluded.action,
+			alerted_period = '',
+			updated_at = excluded.updated_at`,
+		b.WorkflowID, b.MonthlyTokens, b.MonthlyCost, b.Action, b.UpdatedAt)
+	return err
+}
+
+// DeleteAIBudget removes a workflow's AI budget.
+func (s *V1Store) DeleteAIBudget(workflowID string) error {
+	res, err := s.db.Exec(`DELETE FROM ai_budgets WHERE workflow_id = ?`, workflowID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// MarkAIBudgetAlerted records that a workflow's budget alert fired for the
+// given month. It reports false when the alert had already fired that month,
+// so each budget alerts at most once per month.
+func (s *V1Store) MarkAIBudgetAlerted(workflowID, period string) (bool, error) {
+	res, err := s.db.Exec(`UPDATE ai_budgets SET alerted_period = ? WHERE workflow_id = ? AND alerted_period <> ?`,
+		period, workflowID, period)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
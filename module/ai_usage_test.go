@@ -0,0 +1,178 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/ai"
+	"github.com/stretchr/testify/require"
+)
+
+// newAIUsageStep returns a step that reports usage like an AI step does,
+// honouring the budget check.
+func newAIUsageStep(name string, usage AIUsage) *mockStep {
+	return &mockStep{
+		name: name,
+		execFn: func(ctx context.Context, _ *PipelineContext) (*StepResult, error) {
+			if err := checkAIBudget(ctx); err != nil {
+				return nil, err
+			}
+			u := usage
+			return &StepResult{Output: map[string]any{"usage": aiUsageOutput(&u)}, AIUsage: &u}, nil
+		},
+	}
+}
+
+type recordedAIMeter struct {
+	tenantID     string
+	pipeline     string
+	inputTokens  int64
+	outputTokens int64
+	cost         float64
+}
+
+func (m *recordedAIMeter) RecordAIUsage(_ context.Context, tenantID, pipelineName string, inputTokens, outputTokens int64, cost float64) error {
+	m.tenantID, m.pipeline = tenantID, pipelineName
+	m.inputTokens += inputTokens
+	m.outputTokens += outputTokens
+	m.cost += cost
+	return nil
+}
+
+func TestNewAIUsage_PricesReportedThenRequestedModel(t *testing.T) {
+	registry := ai.NewAIModelRegistry()
+	registry.SetPrice("claude-sonnet", ai.ModelPrice{InputPer1K: 0.003, OutputPer1K: 0.015})
+
+	u := newAIUsage(registry, "anthropic", "claude-sonnet", "claude-sonnet-20250101", ai.TokenUsage{InputTokens: 1000, OutputTokens: 2000})
+	require.Equal(t, "claude-sonnet-20250101", u.Model)
+	require.InDelta(t, 0.033, u.Cost, 1e-9)
+
+	u = newAIUsage(registry, "anthropic", "unknown", "", ai.TokenUsage{InputTokens: 1000})
+	require.Equal(t, "unknown", u.Model)
+	require.Zero(t, u.Cost)
+}
+
+func TestExecutionTracker_RecordsAIUsageAndEnforcesBudget(t *testing.T) {
+	_, store, _, wf := setupWebhookHandler(t)
+	meter := &recordedAIMeter{}
+	tracker := &ExecutionTracker{Store: store, WorkflowID: wf.ID}
+	tracker.SetAIUsageMeter(meter)
+
+	pipeline := &Pipeline{
+		Name: "summarize",
+		Steps: []PipelineStep{
+			newAIUsageStep("draft", AIUsage{Provider: "mock", Model: "m1", InputTokens: 100, OutputTokens: 50, Cost: 0.01}),
+			newAIUsageStep("review", AIUsage{Provider: "mock", Model: "m2", InputTokens: 40, OutputTokens: 10, Cost: 0.002}),
+		},
+	}
+	for range 2 {
+		_, err := tracker.TrackPipelineExecution(context.Background(), pipeline, nil, nil)
+		require.NoError(t, err)
+	}
+
+	report, err := store.AIUsageReport(AIUsageFilter{WorkflowID: wf.ID, Bucket: AIUsageBucketDay})
+	require.NoError(t, err)
+	require.Equal(t, int64(4), report.Totals.Calls)
+	require.Equal(t, int64(400), report.Totals.TotalTokens)
+	require.InDelta(t, 0.024, report.Totals.Cost, 1e-9)
+	require.Len(t, report.Pipelines, 1)
+	require.Equal(t, "summarize", report.Pipelines[0].Pipeline)
+	require.Len(t, report.Executions, 2)
+	require.Equal(t, int64(200), report.Executions[0].TotalTokens)
+	require.Len(t, report.Series, 1)
+
+	byModel, err := store.AIUsageReport(AIUsageFilter{WorkflowID: wf.ID, Model: "m2"})
+	require.NoError(t, err)
+	require.Equal(t, int64(100), byModel.Totals.TotalTokens)
+
+	project, err := store.GetProject(wf.ProjectID)
+	require.NoError(t, err)
+	require.Equal(t, project.CompanyID, meter.tenantID)
+	require.Equal(t, "summarize", meter.pipeline)
+	require.Equal(t, int64(280), meter.inputTokens)
+
+	// A notify budget alerts once but lets steps run.
+	require.NoError(t, store.SetAIBudget(&AIBudget{WorkflowID: wf.ID, MonthlyTokens: 300, Action: AIBudgetActionNotify}))
+	_, err = tracker.TrackPipelineExecution(context.Background(), pipeline, nil, nil)
+	require.NoError(t, err)
+	first, err := store.MarkAIBudgetAlerted(wf.ID, aiUsageMonth(time.Now()))
+	require.NoError(t, err)
+	require.False(t, first, "the budget alert should already have been raised")
+
+	// A fail budget stops AI steps before they call their model.
+	require.NoError(t, store.SetAIBudget(&AIBudget{WorkflowID: wf.ID, MonthlyTokens: 300, Action: AIBudgetActionFail}))
+	_, err = tracker.TrackPipelineExecution(context.Background(), pipeline, nil, nil)
+	require.ErrorIs(t, err, ErrAIBudgetExceeded)
+	var exceeded *AIBudgetExceededError
+	require.True(t, errors.As(err, &exceeded))
+	require.Equal(t, int64(600), exceeded.Usage.TotalTokens)
+}
+
+func TestV1Handler_AIUsageAndBudget(t *testing.T) {
+	handler, store, token, wf := setupWebhookHandler(t)
+	require.NoError(t, store.RecordAIUsage(&AIUsageRecord{
+		ExecutionID: "exec-1", WorkflowID: wf.ID, Pipeline: "summarize", StepName: "draft",
+		Model: "m1", InputTokens: 100, OutputTokens: 50, Cost: 0.01,
+	}))
+
+	rr := doRequest(handler, "GET", "/api/v1/ai/usage?workflow_id="+wf.ID+"&bucket=month", "", token)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var resp aiUsageResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Equal(t, int64(150), resp.Totals.TotalTokens)
+	require.Len(t, resp.Series, 1)
+	require.Nil(t, resp.Budget)
+
+	rr = doRequest(handler, "GET", "/api/v1/ai/usage", "", token)
+	require.Equal(t, http.StatusForbidden, rr.Code, "usage across workflows is admin-only")
+	rr = doRequest(handler, "GET", "/api/v1/ai/usage?workflow_id="+wf.ID+"&bucket=week", "", token)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	rr = doRequest(handler, "GET", "/api/v1/ai/usage?workflow_id="+wf.ID+"&from=yesterday", "", token)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	path := "/api/v1/workflows/" + wf.ID + "/ai-budget"
+	rr = doRequest(handler, "GET", path, "", token)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	rr = doRequest(handler, "PUT", path, `{"monthly_cost":0.005,"action":"halt"}`, token)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = doRequest(handler, "PUT", path, `{"monthly_cost":0.005}`, token)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var status aiBudgetStatus
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &status))
+	require.Equal(t, AIBudgetActionNotify, status.Action)
+	require.True(t, status.Exceeded)
+	require.Equal(t, int64(1), status.Usage.Calls)
+
+	rr = doRequest(handler, "GET", "/api/v1/ai/usage?workflow_id="+wf.ID, "", token)
+	require.Equal(t, http.StatusOK, rr.Code)
+	resp = aiUsageResponse{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.NotNil(t, resp.Budget)
+	require.True(t, resp.Budget.Exceeded)
+
+	rr = doRequest(handler, "DELETE", path, "", token)
+	require.Equal(t, http.StatusOK, rr.Code)
+	rr = doRequest(handler, "DELETE", path, "", token)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestAIPricingModule(t *testing.T) {
+	registry := ai.NewAIModelRegistry()
+	_, err := NewAIPricingModule("pricing", map[string]any{
+		"models": map[string]any{"m1": map[string]any{"inputPer1K": 0.5, "outputPer1K": 1}},
+	}, registry)
+	require.NoError(t, err)
+	price, ok := registry.Price("m1")
+	require.True(t, ok)
+	require.Equal(t, ai.ModelPrice{InputPer1K: 0.5, OutputPer1K: 1}, price)
+
+	_, err = NewAIPricingModule("pricing", map[string]any{
+		"models": map[string]any{"m1": map[string]any{"inputPer1K": "cheap"}},
+	}, registry)
+	require.ErrorContains(t, err, "inputPer1K")
+}
@@ -0,0 +1,868 @@
+package module
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoCodeAlone/modular"
+	evstore "github.com/GoCodeAlone/workflow/store"
+)
+
+// Alert rule metrics.
+const (
+	AlertMetricErrorRate  = "error_rate"
+	AlertMetricP95Latency = "p95_latency"
+	AlertMetricQueueDepth = "queue_depth"
+	AlertMetricDLQSize    = "dlq_size"
+)
+
+// AlertRule is one alerting rule: the alert fires when the metric stays
+// above Threshold for the For duration.
+type AlertRule struct {
+	Name string
+	// Metric is error_rate (failed/finished executions, 0-1), p95_latency
+	// (milliseconds), queue_depth (pending jobs of the jobqueue.service
+	// named by Target) or dlq_size (pending entries of the dlq.service
+	// named by Target).
+	Metric string
+	// Scope selects the executions error_rate and p95_latency are computed
+	// over: "pipeline" (the pipeline named by Target), "route" (the HTTP
+	// route pattern in Target) or "workflow" (every execution).
+	Scope  string
+	Target string
+	// Window is how far back execution metrics look.
+	Window    time.Duration
+	Threshold float64
+	For       time.Duration
+	// MinSamples is the fewest finished executions in the window for
+	// execution metrics to have a value; below it the rule has no data
+	// and does not breach.
+	MinSamples int
+	Actions    []AlertAction
+}
+
+// AlertAction is one notification of an alert rule. Exactly one field is
+// set: Pipeline runs the named pipeline with the alert as its trigger data,
+// Slack sends the alert message to the named notification.slack module,
+// and Webhook POSTs the alert as JSON to the URL.
+type AlertAction struct {
+	Pipeline string
+	Slack    string
+	Webhook  string
+}
+
+// AlertSilence suppresses the notifications of Rules, or of every rule when
+// Rules is empty, between Start and End. Silenced alerts still change state.
+type AlertSilence struct {
+	Rules   []string
+	Start   time.Time
+	End     time.Time
+	Comment string
+}
+
+// AlertingConfig holds the configuration for the alerting.rules module.
+type AlertingConfig struct {
+	// EventStore names the event store execution metrics are read from;
+	// the first event store when empty.
+	EventStore string
+	// Interval is how often the rules are evaluated.
+	Interval time.Duration
+	// RepeatInterval, when set, repeats the notification of an alert that
+	// is still firing; otherwise a firing alert notifies once.
+	RepeatInterval time.Duration
+	// DBPath is the SQLite database alert state is persisted in.
+	DBPath string
+	// Path, when set, serves alert state at GET {Path} and GET {Path}/{rule}.
+	Path string
+	// Router names the http.router the API is added to; the first router
+	// when empty.
+	Router   string
+	Rules    []AlertRule
+	Silences []AlertSilence
+}
+
+// ParseAlertingConfig reads an alerting.rules config map and applies the
+// defaults.
+func ParseAlertingConfig(raw map[string]any) (AlertingConfig, error) {
+	cfg := AlertingConfig{
+		Interval: 30 * time.Second,
+		DBPath:   "./data/alerts.db",
+	}
+	var err error
+	cfg.EventStore, _ = raw["eventStore"].(string)
+	cfg.Router, _ = raw["router"].(string)
+	if v, _ := raw["dbPath"].(string); v != "" {
+		cfg.DBPath = v
+	}
+	if v, _ := raw["path"].(string); v != "" {
+		cfg.Path = "/" + strings.Trim(v, "/")
+	}
+	if cfg.Interval, err = alertDuration(raw, "interval", cfg.Interval); err != nil {
+		return cfg, err
+	}
+	if cfg.Interval <= 0 {
+		return cfg, fmt.Errorf("'interval' must be positive")
+	}
+	if cfg.RepeatInterval, err = alertDuration(raw, "repeatInterval", 0); err != nil {
+		return cfg, err
+	}
+
+	rawRules, _ := raw["rules"].([]any)
+	seen := make(map[string]bool, len(rawRules))
+	for i, item := range rawRules {
+		rm, ok := item.(map[string]any)
+		if !ok {
+			return cfg, fmt.Errorf("rules[%d] must be a map", i)
+		}
+		rule, err := parseAlertRule(rm)
+		if err != nil {
+			return cfg, fmt.Errorf("rules[%d]: %w", i, err)
+		}
+		if seen[rule.Name] {
+			return cfg, fmt.Errorf("rules[%d]: duplicate rule name %q", i, rule.Name)
+		}
+		seen[rule.Name] = true
+		cfg.Rules = append(cfg.Rules, rule)
+	}
+
+	rawSilences, _ := raw["silences"].([]any)
+	for i, item := range rawSilences {
+		sm, ok := item.(map[string]any)
+		if !ok {
+			return cfg, fmt.Errorf("silences[%d] must be a map", i)
+		}
+		silence, err := parseAlertSilence(sm)
+		if err != nil {
+			return cfg, fmt.Errorf("silences[%d]: %w", i, err)
+		}
+		for _, name := range silence.Rules {
+			if !seen[name] {
+				return cfg, fmt.Errorf("silences[%d]: unknown rule %q", i, name)
+			}
+		}
+		cfg.Silences = append(cfg.Silences, silence)
+	}
+	return cfg, nil
+}
+
+func parseAlertRule(raw map[string]any) (AlertRule, error) {
+	rule := AlertRule{MinSamples: 1}
+	rule.Name, _ = raw["name"].(string)
+	if rule.Name == "" {
+		return rule, fmt.Errorf("'name' is required")
+	}
+	rule.Metric, _ = raw["metric"].(string)
+	rule.Scope, _ = raw["scope"].(string)
+	rule.Target, _ = raw["target"].(string)
+	switch rule.Metric {
+	case AlertMetricErrorRate, AlertMetricP95Latency:
+		switch rule.Scope {
+		case "":
+			rule.Scope = "workflow"
+		case "workflow":
+		case "pipeline", "route":
+			if rule.Target == "" {
+				return rule, fmt.Errorf("rule %q: 'target' is required for scope %q", rule.Name, rule.Scope)
+			}
+		default:
+			return rule, fmt.Errorf("rule %q: unknown scope %q (expected pipeline, route or workflow)", rule.Name, rule.Scope)
+		}
+	case AlertMetricQueueDepth, AlertMetricDLQSize:
+		if rule.Target == "" {
+			return rule, fmt.Errorf("rule %q: 'target' is required for metric %s", rule.Name, rule.Metric)
+		}
+	default:
+		return rule, fmt.Errorf("rule %q: unknown metric %q (expected error_rate, p95_latency, queue_depth or dlq_size)", rule.Name, rule.Metric)
+	}
+
+	threshold, ok := toFloat64(raw["threshold"])
+	if !ok {
+		return rule, fmt.Errorf("rule %q: 'threshold' must be a number", rule.Name)
+	}
+	rule.Threshold = threshold
+	var err error
+	if rule.Window, err = alertDuration(raw, "window", 5*time.Minute); err != nil {
+		return rule, fmt.Errorf("rule %q: %w", rule.Name, err)
+	}
+	if rule.For, err = alertDuration(raw, "for", 0); err != nil {
+		return rule, fmt.Errorf("rule %q: %w", rule.Name, err)
+	}
+	if v, ok := raw["minSamples"]; ok {
+		n, isInt := intFromAny(v)
+		if !isInt || n < 1 {
+			return rule, fmt.Errorf("rule %q: 'minSamples' must be a positive integer", rule.Name)
+		}
+		rule.MinSamples = n
+	}
+
+	rawActions, _ := raw["actions"].([]any)
+	if len(rawActions) == 0 {
+		return rule, fmt.Errorf("rule %q: 'actions' list is required and must not be empty", rule.Name)
+	}
+	for i, item := range rawActions {
+		am, ok := item.(map[string]any)
+		if !ok {
+			return rule, fmt.Errorf("rule %q: actions[%d] must be a map", rule.Name, i)
+		}
+		a := AlertAction{}
+		a.Pipeline, _ = am["pipeline"].(string)
+		a.Slack, _ = am["slack"].(string)
+		a.Webhook, _ = am["webhook"].(string)
+		set := 0
+		for _, s := range []string{a.Pipeline, a.Slack, a.Webhook} {
+			if s != "" {
+				set++
+			}
+		}
+		if set != 1 {
+			return rule, fmt.Errorf("rule %q: actions[%d] must set exactly one of pipeline, slack or webhook", rule.Name, i)
+		}
+		rule.Actions = append(rule.Actions, a)
+	}
+	return rule, nil
+}
+
+func parseAlertSilence(raw map[string]any) (AlertSilence, error) {
+	silence := AlertSilence{}
+	silence.Comment, _ = raw["comment"].(string)
+	if rules, ok := raw["rules"].([]any); ok {
+		for _, r := range rules {
+			if s, ok := r.(string); ok && s != "" {
+				silence.Rules = append(silence.Rules, s)
+			}
+		}
+	}
+	var err error
+	if v, ok := raw["start"]; ok {
+		if silence.Start, err = alertTime(v); err != nil {
+			return silence, fmt.Errorf("'start': %w", err)
+		}
+	}
+	v, ok := raw["end"]
+	if !ok {
+		return silence, fmt.Errorf("'end' is required")
+	}
+	if silence.End, err = alertTime(v); err != nil {
+		return silence, fmt.Errorf("'end': %w", err)
+	}
+	if !silence.End.After(silence.Start) {
+		return silence, fmt.Errorf("'end' must be after 'start'")
+	}
+	return silence, nil
+}
+
+// alertDuration reads a duration string such as "5m" from raw[key].
+func alertDuration(raw map[string]any, key string, def time.Duration) (time.Duration, error) {
+	v, ok := raw[key]
+	if !ok {
+		return def, nil
+	}
+	s, isString := v.(string)
+	if !isString {
+		return 0, fmt.Errorf("'%s' must be a duration string such as \"5m\"", key)
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("'%s' must be a duration string such as \"5m\"", key)
+	}
+	return d, nil
+}
+
+// alertTime reads an RFC 3339 timestamp, which YAML may already have decoded.
+func alertTime(v any) (time.Time, error) {
+	switch t := v.(type) {
+	case time.Time:
+		return t.UTC(), nil
+	case string:
+		parsed, err := time.Parse(time.RFC3339, t)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("must be an RFC 3339 timestamp")
+		}
+		return parsed.UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("must be an RFC 3339 timestamp")
+}
+
+// silenced reports whether a silence covers rule at now.
+func (c *AlertingConfig) silenced(rule string, now time.Time) bool {
+	for _, s := range c.Silences {
+		if now.Before(s.Start) || !now.Before(s.End) {
+			continue
+		}
+		if len(s.Rules) == 0 || slices.Contains(s.Rules, rule) {
+			return true
+		}
+	}
+	return false
+}
+
+// AlertingModule evaluates alerting rules against execution metrics from
+// the event store, job queue depth and DLQ size. A breached rule is pending
+// until it has been breached for the rule's For duration, then fires and
+// runs its actions once (or every RepeatInterval while it keeps firing);
+// when the condition clears, the alert resolves and the actions run again
+// with status "resolved". Silences suppress notifications without
+// affecting state.
+//
+// Alert state is persisted in SQLite so a restart neither loses a firing
+// alert nor notifies it again, and is served at GET {path} for dashboards.
+// Rules, silences and the intervals can be changed by a partial reload.
+type AlertingModule struct {
+	name      string
+	config    AlertingConfig
+	configErr error
+	states    map[string]*AlertState
+	mu        sync.Mutex // guards config and states
+	evalMu    sync.Mutex // serializes evaluations and reconfiguration
+
+	store  *sqliteAlertStateStore
+	events evstore.EventStore
+	engine WorkflowEngine
+	app    modular.Application
+	logger modular.Logger
+	client *http.Client
+
+	wake   chan struct{}
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+	now    func() time.Time
+}
+
+// NewAlertingModule creates an alerting.rules module from its config map.
+// Config errors are reported by Init.
+func NewAlertingModule(name string, raw map[string]any) *AlertingModule {
+	cfg, err := ParseAlertingConfig(raw)
+	return &AlertingModule{
+		name:      name,
+		config:    cfg,
+		configErr: err,
+		states:    make(map[string]*AlertState),
+		logger:    &noopLogger{},
+		client:    &http.Client{Timeout: 10 * time.Second},
+		wake:      make(chan struct{}, 1),
+		stopCh:    make(chan struct{}),
+		now:       time.Now,
+	}
+}
+
+// Name implements modular.Module.
+func (m *AlertingModule) Name() string { return m.name }
+
+// Init implements modular.Module.
+func (m *AlertingModule) Init(app modular.Application) error {
+	if m.configErr != nil {
+		return fmt.Errorf("alerting.rules %q: %w", m.name, m.configErr)
+	}
+	m.app = app
+	m.logger = app.Logger()
+	return nil
+}
+
+// SetEventStore overrides the event store execution metrics are read from.
+// It must be called before Start.
+func (m *AlertingModule) SetEventStore(s evstore.EventStore) { m.events = s }
+
+// SetEngine overrides the engine pipeline actions run on. It must be
+// called before Start.
+func (m *AlertingModule) SetEngine(e WorkflowEngine) { m.engine = e }
+
+// Path returns the path alert state is served under, or "" when the API is
+// disabled.
+func (m *AlertingModule) Path() string { return m.config.Path }
+
+// RouterName returns the http.router the API is added to, or "" for the
+// first router.
+func (m *AlertingModule) RouterName() string { return m.config.Router }
+
+// Start opens the state database, resolves the event store and engine, and
+// starts periodic evaluation.
+func (m *AlertingModule) Start(ctx context.Context) error {
+	if m.store == nil {
+		st, err := openSQLiteAlertStateStore(m.config.DBPath, m.name)
+		if err != nil {
+			return fmt.Errorf("alerting.rules %q: %w", m.name, err)
+		}
+		m.store = st
+	}
+	states, err := m.store.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("alerting.rules %q: %w", m.name, err)
+	}
+	m.mu.Lock()
+	m.states = states
+	m.mu.Unlock()
+	m.pruneStates(ctx)
+
+	if m.app != nil {
+		registry := m.app.SvcRegistry()
+		if m.events == nil {
+			if m.config.EventStore != "" {
+				es, ok := registry[m.config.EventStore].(evstore.EventStore)
+				if !ok {
+					return fmt.Errorf("alerting.rules %q: event store %q not found", m.name, m.config.EventStore)
+				}
+				m.events = es
+			} else if match, err := FindByInterface[evstore.EventStore](registry); !errors.Is(err, ErrServiceNotFound) {
+				m.events = match.Service
+			}
+		}
+		if m.engine == nil {
+			for _, name := range []string{"workflowEngine", "engine"} {
+				if e, ok := registry[name].(WorkflowEngine); ok {
+					m.engine = e
+					break
+				}
+			}
+		}
+	}
+
+	m.wg.Add(1)
+	go m.run()
+	m.logger.Info("Alerting started", "module", m.name, "rules", len(m.config.Rules), "interval", m.config.Interval)
+	return nil
+}
+
+// Stop stops evaluation and closes the state database.
+func (m *AlertingModule) Stop(_ context.Context) error {
+	select {
+	case <-m.stopCh:
+	default:
+		close(m.stopCh)
+	}
+	m.wg.Wait()
+	if m.store != nil {
+		err := m.store.Close()
+		m.store = nil
+		return err
+	}
+	return nil
+}
+
+func (m *AlertingModule) run() {
+	defer m.wg.Done()
+	for {
+		m.mu.Lock()
+		interval := m.config.Interval
+		m.mu.Unlock()
+		timer := time.NewTimer(interval)
+		select {
+		case <-m.stopCh:
+			timer.Stop()
+			return
+		case <-m.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		if err := m.Evaluate(ctx); err != nil {
+			m.logger.Error("Alert evaluation failed", "module", m.name, "error", err)
+		}
+		cancel()
+	}
+}
+
+// Evaluate evaluates every rule once, updates and persists alert state, and
+// sends the resulting notifications. Rules that cannot be measured keep
+// their state and are reported in the returned error.
+func (m *AlertingModule) Evaluate(ctx context.Context) error {
+	m.evalMu.Lock()
+	defer m.evalMu.Unlock()
+
+	m.mu.Lock()
+	cfg := m.config
+	m.mu.Unlock()
+	now := m.now().UTC()
+
+	var errs []error
+	for _, rule := range cfg.Rules {
+		value, hasValue, err := m.measure(ctx, rule, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rule %q: %w", rule.Name, err))
+			continue
+		}
+
+		m.mu.Lock()
+		st := m.states[rule.Name]
+		if st == nil {
+			st = &AlertState{Rule: rule.Name, State: AlertStateOK}
+			m.states[rule.Name] = st
+		}
+		status := transitionAlert(st, rule, value, hasValue, now, cfg.RepeatInterval, cfg.silenced(rule.Name, now))
+		snapshot := *st
+		m.mu.Unlock()
+
+		if status != "" {
+			if err := m.notify(ctx, rule, &snapshot, status); err != nil {
+				errs = append(errs, fmt.Errorf("rule %q: %w", rule.Name, err))
+			}
+			m.mu.Lock()
+			notified := now
+			st.LastNotified = &notified
+			snapshot = *st
+			m.mu.Unlock()
+		}
+		if m.store != nil {
+			if err := m.store.Save(ctx, &snapshot); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// transitionAlert moves st to its next state for a measurement and returns
+// the notification to send: "firing", "resolved" or "".
+func transitionAlert(st *AlertState, rule AlertRule, value float64, hasValue bool, now time.Time, repeat time.Duration, silenced bool) string {
+	st.Value, st.HasValue = value, hasValue
+	st.EvaluatedAt = &now
+	breached := hasValue && value > rule.Threshold
+
+	if !breached {
+		status := ""
+		if st.State == AlertStateFiring {
+			if st.notifiedForCurrent() && !silenced {
+				status = "resolved"
+			}
+			st.ResolvedAt = &now
+		}
+		st.State, st.Since = AlertStateOK, nil
+		return status
+	}
+
+	switch st.State {
+	case AlertStateFiring:
+	case AlertStatePending:
+		if st.Since == nil {
+			st.Since = &now
+		}
+		if now.Sub(*st.Since) < rule.For {
+			return ""
+		}
+		st.State, st.Since = AlertStateFiring, &now
+	default:
+		if rule.For > 0 {
+			st.State, st.Since = AlertStatePending, &now
+			return ""
+		}
+		st.State, st.Since = AlertStateFiring, &now
+	}
+
+	if silenced {
+		return ""
+	}
+	if !st.notifiedForCurrent() || (repeat > 0 && now.Sub(*st.LastNotified) >= repeat) {
+		return "firing"
+	}
+	return ""
+}
+
+// measure returns the rule's metric value, or hasValue false when the window
+// holds too few executions.
+func (m *AlertingModule) measure(ctx context.Context, rule AlertRule, now time.Time) (value float64, hasValue bool, err error) {
+	switch rule.Metric {
+	case AlertMetricQueueDepth:
+		var registry map[string]any
+		if m.app != nil {
+			registry = m.app.SvcRegistry()
+		}
+		q, ok := registry[rule.Target].(*JobQueueService)
+		if !ok || q.Queue() == nil {
+			return 0, false, fmt.Errorf("jobqueue.service %q not found", rule.Target)
+		}
+		stats, err := q.Queue().Stats(ctx)
+		if err != nil {
+			return 0, false, err
+		}
+		return float64(stats.Pending), true, nil
+
+	case AlertMetricDLQSize:
+		var registry map[string]any
+		if m.app != nil {
+			registry = m.app.SvcRegistry()
+		}
+		dlq, ok := registry[rule.Target+DLQStoreServiceSuffix].(evstore.DLQStore)
+		if !ok {
+			return 0, false, fmt.Errorf("dlq.service %q not found", rule.Target)
+		}
+		n, err := dlq.Count(ctx, evstore.DLQFilter{Status: evstore.DLQStatusPending})
+		if err != nil {
+			return 0, false, err
+		}
+		return float64(n), true, nil
+	}
+
+	if m.events == nil {
+		return 0, false, fmt.Errorf("no event store to read execution metrics from")
+	}
+	since := now.Add(-rule.Window)
+	filter := evstore.ExecutionEventFilter{Since: &since}
+	if rule.Scope == "pipeline" {
+		filter.Pipeline = rule.Target
+	}
+	execs, err := m.events.ListExecutions(ctx, filter)
+	if err != nil {
+		return 0, false, err
+	}
+	var failed int
+	var durations []float64
+	for i := range execs {
+		e := &execs[i]
+		if e.Status != "completed" && e.Status != "failed" {
+			continue
+		}
+		if rule.Scope == "pipeline" && e.Pipeline != rule.Target || rule.Scope == "route" && e.Route != rule.Target {
+			continue
+		}
+		if e.Status == "failed" {
+			failed++
+		}
+		if e.StartedAt != nil && e.CompletedAt != nil {
+			durations = append(durations, float64(e.CompletedAt.Sub(*e.StartedAt).Milliseconds()))
+		} else {
+			durations = append(durations, 0)
+		}
+	}
+	if len(durations) < rule.MinSamples || len(durations) == 0 {
+		return 0, false, nil
+	}
+	if rule.Metric == AlertMetricErrorRate {
+		return float64(failed) / float64(len(durations)), true, nil
+	}
+	sort.Float64s(durations)
+	idx := int(math.Ceil(0.95*float64(len(durations)))) - 1
+	return durations[max(idx, 0)], true, nil
+}
+
+// alertPayload is the alert context passed to every action.
+func alertPayload(rule AlertRule, st *AlertState, status string) map[string]any {
+	payload := map[string]any{
+		"alert":     rule.Name,
+		"status":    status,
+		"metric":    rule.Metric,
+		"target":    rule.Target,
+		"value":     st.Value,
+		"threshold": rule.Threshold,
+		"message":   alertMessage(rule, st, status),
+	}
+	if rule.Metric == AlertMetricErrorRate || rule.Metric == AlertMetricP95Latency {
+		payload["scope"] = rule.Scope
+		payload["window"] = rule.Window.String()
+	}
+	if st.Since != nil {
+		payload["since"] = st.Since.Format(time.RFC3339)
+	}
+	if status == "resolved" && st.ResolvedAt != nil {
+		payload["resolved_at"] = st.ResolvedAt.Format(time.RFC3339)
+	}
+	return payload
+}
+
+func alertMessage(rule AlertRule, st *AlertState, status string) string {
+	subject := rule.Metric
+	if rule.Target != "" {
+		subject += " of " + rule.Target
+	}
+	if status == "resolved" {
+		return fmt.Sprintf("[RESOLVED] %s: %s is %g, back under %g", rule.Name, subject, st.Value, rule.Threshold)
+	}
+	return fmt.Sprintf("[FIRING] %s: %s is %g, above %g", rule.Name, subject, st.Value, rule.Threshold)
+}
+
+// notify runs every action of the rule, continuing past failed ones.
+func (m *AlertingModule) notify(ctx context.Context, rule AlertRule, st *AlertState, status string) error {
+	payload := alertPayload(rule, st, status)
+	m.logger.Warn("Alert "+status, "module", m.name, "rule", rule.Name, "value", st.Value, "threshold", rule.Threshold)
+
+	var errs []error
+	for _, a := range rule.Actions {
+		var err error
+		switch {
+		case a.Pipeline != "":
+			if m.engine == nil {
+				err = fmt.Errorf("pipeline %q: workflow engine not found", a.Pipeline)
+			} else if runErr := m.engine.TriggerWorkflow(ctx, "pipeline:"+a.Pipeline, "", payload); runErr != nil {
+				err = fmt.Errorf("pipeline %q: %w", a.Pipeline, runErr)
+			}
+		case a.Slack != "":
+			var handler MessageHandler
+			if m.app != nil {
+				handler, _ = m.app.SvcRegistry()[a.Slack].(MessageHandler)
+			}
+			if handler == nil {
+				err = fmt.Errorf("slack %q: notification module not found", a.Slack)
+			} else if sendErr := handler.HandleMessage([]byte(payload["message"].(string))); sendErr != nil {
+				err = fmt.Errorf("slack %q: %w", a.Slack, sendErr)
+			}
+		case a.Webhook != "":
+			err = m.postWebhook(ctx, a.Webhook, payload)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *AlertingModule) postWebhook(ctx context.Context, url string, payload map[string]any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook %s: %w", url, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s: unexpected status %d", url, resp.StatusCode)
+	}
+	return nil
+}
+
+// pruneStates forgets the state of rules that are no longer configured.
+func (m *AlertingModule) pruneStates(ctx context.Context) {
+	m.mu.Lock()
+	var removed []string
+	for name := range m.states {
+		if !slices.ContainsFunc(m.config.Rules, func(r AlertRule) bool { return r.Name == name }) {
+			removed = append(removed, name)
+			delete(m.states, name)
+		}
+	}
+	m.mu.Unlock()
+	if m.store == nil {
+		return
+	}
+	for _, name := range removed {
+		if err := m.store.Delete(ctx, name); err != nil {
+			m.logger.Warn("Failed to delete alert state", "module", m.name, "rule", name, "error", err)
+		}
+	}
+}
+
+// Reconfigure implements interfaces.Reconfigurable. Rules, silences and the
+// intervals take effect immediately; alerts of rules that still exist keep
+// their state. Changing the database, event store or API requires a
+// restart.
+func (m *AlertingModule) Reconfigure(ctx context.Context, newConfig map[string]any) error {
+	cfg, err := ParseAlertingConfig(newConfig)
+	if err != nil {
+		return fmt.Errorf("alerting.rules %q: %w", m.name, err)
+	}
+	m.evalMu.Lock()
+	defer m.evalMu.Unlock()
+
+	m.mu.Lock()
+	old := m.config
+	if cfg.DBPath != old.DBPath || cfg.EventStore != old.EventStore || cfg.Path != old.Path || cfg.Router != old.Router {
+		m.mu.Unlock()
+		return fmt.Errorf("alerting.rules %q: changing dbPath, eventStore, path or router requires restart", m.name)
+	}
+	m.config = cfg
+	m.mu.Unlock()
+	m.pruneStates(ctx)
+
+	// Re-arm the evaluation timer with the new interval.
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+	m.logger.Info("Alerting rules reconfigured", "module", m.name, "rules", len(cfg.Rules))
+	return nil
+}
+
+// AlertStatus is the API view of an alert: its rule and current state.
+type AlertStatus struct {
+	AlertState
+	Metric    string  `json:"metric"`
+	Scope     string  `json:"scope,omitempty"`
+	Target    string  `json:"target,omitempty"`
+	Threshold float64 `json:"threshold"`
+	Silenced  bool    `json:"silenced"`
+}
+
+// Alerts returns the status of every configured rule, ordered by rule name.
+func (m *AlertingModule) Alerts() []AlertStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now().UTC()
+	alerts := make([]AlertStatus, 0, len(m.config.Rules))
+	for _, rule := range m.config.Rules {
+		st := AlertState{Rule: rule.Name, State: AlertStateOK}
+		if s := m.states[rule.Name]; s != nil {
+			st = *s
+		}
+		a := AlertStatus{
+			AlertState: st,
+			Metric:     rule.Metric,
+			Target:     rule.Target,
+			Threshold:  rule.Threshold,
+			Silenced:   m.config.silenced(rule.Name, now),
+		}
+		if rule.Metric == AlertMetricErrorRate || rule.Metric == AlertMetricP95Latency {
+			a.Scope = rule.Scope
+		}
+		alerts = append(alerts, a)
+	}
+	sort.Slice(alerts, func(i, j int) bool { return alerts[i].Rule < alerts[j].Rule })
+	return alerts
+}
+
+// ServeAlerts serves GET {path} with every alert's status.
+func (m *AlertingModule) ServeAlerts(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"alerts": m.Alerts()})
+}
+
+// ServeAlert serves GET {path}/{rule} with one alert's status. Unknown
+// rules are 404.
+func (m *AlertingModule) ServeAlert(w http.ResponseWriter, r *http.Request) {
+	rule := r.PathValue("rule")
+	for _, a := range m.Alerts() {
+		if a.Rule == rule {
+			writeJSON(w, http.StatusOK, a)
+			return
+		}
+	}
+	writeRequestProblem(w, r, ProblemNotFound, fmt.Sprintf("alert rule %q not found", rule))
+}
+
+// AlertsHTTPHandler adapts the AlertingModule handlers to the HTTPHandler
+// interface.
+type AlertsHTTPHandler struct {
+	Handler http.HandlerFunc
+}
+
+// Handle implements the HTTPHandler interface.
+func (h *AlertsHTTPHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	h.Handler(w, r)
+}
+
+// ProvidesServices implements modular.Module.
+func (m *AlertingModule) ProvidesServices() []modular.ServiceProvider {
+	return []modular.ServiceProvider{
+		{Name: m.name, Description: "Alerting rules: " + m.name, Instance: m},
+	}
+}
+
+// RequiresServices implements modular.Module.
+func (m *AlertingModule) RequiresServices() []modular.ServiceDependency {
+	if m.config.EventStore != "" {
+		return []modular.ServiceDependency{{Name: m.config.EventStore, Required: false}}
+	}
+	return nil
+}
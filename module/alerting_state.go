@@ -0,0 +1,151 @@
+package module
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Alert states. An alert is pending while its rule is breached for less than
+// the rule's For duration, and firing after that until the condition clears.
+const (
+	AlertStateOK      = "ok"
+	AlertStatePending = "pending"
+	AlertStateFiring  = "firing"
+)
+
+// AlertState is the persisted state of one alert rule.
+type AlertState struct {
+	Rule  string `json:"rule"`
+	State string `json:"state"`
+	// Value is the last measured value; HasValue is false when the rule's
+	// window held no data.
+	Value    float64 `json:"value"`
+	HasValue bool    `json:"has_value"`
+	// Since is when the alert entered its current pending or firing state.
+	Since        *time.Time `json:"since,omitempty"`
+	LastNotified *time.Time `json:"last_notified,omitempty"`
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty"`
+	EvaluatedAt  *time.Time `json:"evaluated_at,omitempty"`
+}
+
+// notifiedForCurrent reports whether a notification was sent since the alert
+// entered its current state.
+func (s *AlertState) notifiedForCurrent() bool {
+	return s.LastNotified != nil && s.Since != nil && !s.LastNotified.Before(*s.Since)
+}
+
+// sqliteAlertStateStore persists alert states in a SQLite table, keyed by
+// alerting module and rule name.
+type sqliteAlertStateStore struct {
+	db     *sql.DB
+	module string
+}
+
+// openSQLiteAlertStateStore opens (or creates) the database at dbPath.
+func openSQLiteAlertStateStore(dbPath, module string) (*sqliteAlertStateStore, error) {
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0o750); err != nil {
+		return nil, fmt.Errorf("create data directory: %w", err)
+	}
+	db, err := sql.Open("sqlite", dbPath+"?_journal_mode=WAL&_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("open alert state database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	_, err = db.Exec(`
+CREATE TABLE IF NOT EXISTS alert_state (
+	module        TEXT NOT NULL,
+	rule          TEXT NOT NULL,
+	state         TEXT NOT NULL,
+	value         REAL NOT NULL DEFAULT 0,
+	has_value     INTEGER NOT NULL DEFAULT 0,
+	since         INTEGER NOT NULL DEFAULT 0,
+	last_notified INTEGER NOT NULL DEFAULT 0,
+	resolved_at   INTEGER NOT NULL DEFAULT 0,
+	evaluated_at  INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (module, rule)
+)`)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("init alert state schema: %w", err)
+	}
+	return &sqliteAlertStateStore{db: db, module: module}, nil
+}
+
+// Load returns the stored state of every rule of the module.
+func (s *sqliteAlertStateStore) Load(ctx context.Context) (map[string]*AlertState, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT rule, state, value, has_value, since, last_notified, resolved_at, evaluated_at
+FROM alert_state WHERE module = ?`, s.module)
+	if err != nil {
+		return nil, fmt.Errorf("load alert states: %w", err)
+	}
+	defer rows.Close()
+	states := make(map[string]*AlertState)
+	for rows.Next() {
+		st := &AlertState{}
+		var hasValue int
+		var since, notified, resolved, evaluated int64
+		if err := rows.Scan(&st.Rule, &st.State, &st.Value, &hasValue, &since, &notified, &resolved, &evaluated); err != nil {
+			return nil, fmt.Errorf("scan alert state: %w", err)
+		}
+		st.HasValue = hasValue != 0
+		st.Since = alertTimeFromUnix(since)
+		st.LastNotified = alertTimeFromUnix(notified)
+		st.ResolvedAt = alertTimeFromUnix(resolved)
+		st.EvaluatedAt = alertTimeFromUnix(evaluated)
+		states[st.Rule] = st
+	}
+	return states, rows.Err()
+}
+
+// Save upserts a rule's state.
+func (s *sqliteAlertStateStore) Save(ctx context.Context, st *AlertState) error {
+	hasValue := 0
+	if st.HasValue {
+		hasValue = 1
+	}
+	_, err := s.db.ExecContext(ctx, `
+INSERT INTO alert_state (module, rule, state, value, has_value, since, last_notified, resolved_at, evaluated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (module, rule) DO UPDATE SET
+	state = excluded.state, value = excluded.value, has_value = excluded.has_value,
+	since = excluded.since, last_notified = excluded.last_notified,
+	resolved_at = excluded.resolved_at, evaluated_at = excluded.evaluated_at`,
+		s.module, st.Rule, st.State, st.Value, hasValue,
+		alertTimeToUnix(st.Since), alertTimeToUnix(st.LastNotified),
+		alertTimeToUnix(st.ResolvedAt), alertTimeToUnix(st.EvaluatedAt))
+	if err != nil {
+		return fmt.Errorf("save alert state %q: %w", st.Rule, err)
+	}
+	return nil
+}
+
+// Delete removes a rule's state.
+func (s *sqliteAlertStateStore) Delete(ctx context.Context, rule string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM alert_state WHERE module = ? AND rule = ?`, s.module, rule); err != nil {
+		return fmt.Errorf("delete alert state %q: %w", rule, err)
+	}
+	return nil
+}
+
+// Close closes the database.
+func (s *sqliteAlertStateStore) Close() error { return s.db.Close() }
+
+func alertTimeToUnix(t *time.Time) int64 {
+	if t == nil {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func alertTimeFromUnix(n int64) *time.Time {
+	if n == 0 {
+		return nil
+	}
+	t := time.Unix(0, n).UTC()
+	return &t
+}
@@ -0,0 +1,421 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	evstore "github.com/GoCodeAlone/workflow/store"
+)
+
+// fixedExecutionStore serves a canned execution list to alerting rules.
+type fixedExecutionStore struct {
+	evstore.EventStore
+	mu    sync.Mutex
+	execs []evstore.MaterializedExecution
+}
+
+func (s *fixedExecutionStore) set(execs ...evstore.MaterializedExecution) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.execs = execs
+}
+
+func (s *fixedExecutionStore) ListExecutions(_ context.Context, _ evstore.ExecutionEventFilter) ([]evstore.MaterializedExecution, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.execs, nil
+}
+
+func alertTestExecs(pipeline, route string, completed, failed int, duration time.Duration) []evstore.MaterializedExecution {
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(duration)
+	var execs []evstore.MaterializedExecution
+	for i := 0; i < completed+failed; i++ {
+		status := "completed"
+		if i < failed {
+			status = "failed"
+		}
+		execs = append(execs, evstore.MaterializedExecution{
+			Pipeline: pipeline, Route: route, Status: status, StartedAt: &start, CompletedAt: &end,
+		})
+	}
+	return execs
+}
+
+// recordingAlertEngine records the pipelines alert actions trigger.
+type recordingAlertEngine struct {
+	mu    sync.Mutex
+	calls []map[string]any
+}
+
+func (e *recordingAlertEngine) TriggerWorkflow(_ context.Context, workflowType string, _ string, data map[string]any) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	data["_type"] = workflowType
+	e.calls = append(e.calls, data)
+	return nil
+}
+
+func (e *recordingAlertEngine) statuses() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var out []string
+	for _, c := range e.calls {
+		out = append(out, c["status"].(string))
+	}
+	return out
+}
+
+func alertTestConfig(dbPath string) map[string]any {
+	return map[string]any{
+		"dbPath":   dbPath,
+		"interval": "1h",
+		"path":     "/api/alerts",
+		"rules": []any{
+			map[string]any{
+				"name":      "orders-errors",
+				"metric":    "error_rate",
+				"scope":     "pipeline",
+				"target":    "create-order",
+				"threshold": 0.2,
+				"for":       "2m",
+				"actions":   []any{map[string]any{"pipeline": "notify-oncall"}},
+			},
+		},
+	}
+}
+
+type alertTestClock struct{ t time.Time }
+
+func (c *alertTestClock) now() time.Time          { return c.t }
+func (c *alertTestClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func startAlertTestModule(t *testing.T, cfg map[string]any, store evstore.EventStore, engine WorkflowEngine, clock *alertTestClock) *AlertingModule {
+	t.Helper()
+	m := NewAlertingModule("alerts", cfg)
+	m.now = clock.now
+	if err := m.Init(NewMockApplication()); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	m.SetEventStore(store)
+	m.SetEngine(engine)
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { _ = m.Stop(context.Background()) })
+	return m
+}
+
+func mustEvaluate(t *testing.T, m *AlertingModule) {
+	t.Helper()
+	if err := m.Evaluate(context.Background()); err != nil {
+		t.Fatalf("Evaluate: %v", err)
+	}
+}
+
+func alertStateOf(m *AlertingModule, rule string) AlertStatus {
+	for _, a := range m.Alerts() {
+		if a.Rule == rule {
+			return a
+		}
+	}
+	return AlertStatus{}
+}
+
+func TestAlerting_FiresAfterForAndResolves(t *testing.T) {
+	store := &fixedExecutionStore{}
+	engine := &recordingAlertEngine{}
+	clock := &alertTestClock{t: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)}
+	m := startAlertTestModule(t, alertTestConfig(filepath.Join(t.TempDir(), "alerts.db")), store, engine, clock)
+
+	// 3 of 10 failed: breached, but pending until the for duration passes.
+	store.set(alertTestExecs("create-order", "", 7, 3, time.Second)...)
+	mustEvaluate(t, m)
+	if st := alertStateOf(m, "orders-errors"); st.State != AlertStatePending || st.Value != 0.3 {
+		t.Fatalf("expected pending at 0.3, got %+v", st)
+	}
+	clock.advance(2 * time.Minute)
+	mustEvaluate(t, m)
+	if st := alertStateOf(m, "orders-errors"); st.State != AlertStateFiring || st.LastNotified == nil {
+		t.Fatalf("expected firing and notified, got %+v", st)
+	}
+
+	// Still firing: no duplicate notification.
+	clock.advance(time.Minute)
+	mustEvaluate(t, m)
+	if got := engine.statuses(); len(got) != 1 || got[0] != "firing" {
+		t.Fatalf("expected one firing notification, got %v", got)
+	}
+	call := engine.calls[0]
+	if call["_type"] != "pipeline:notify-oncall" || call["alert"] != "orders-errors" || call["target"] != "create-order" {
+		t.Errorf("unexpected alert context: %v", call)
+	}
+
+	// Failures of other pipelines do not count; the alert resolves.
+	store.set(append(alertTestExecs("create-order", "", 10, 0, time.Second), alertTestExecs("other", "", 0, 10, time.Second)...)...)
+	clock.advance(time.Minute)
+	mustEvaluate(t, m)
+	if got := engine.statuses(); len(got) != 2 || got[1] != "resolved" {
+		t.Fatalf("expected a resolved notification, got %v", got)
+	}
+	if st := alertStateOf(m, "orders-errors"); st.State != AlertStateOK || st.ResolvedAt == nil {
+		t.Errorf("expected ok with resolved_at, got %+v", st)
+	}
+}
+
+func TestAlerting_RepeatIntervalAndMinSamples(t *testing.T) {
+	store := &fixedExecutionStore{}
+	engine := &recordingAlertEngine{}
+	clock := &alertTestClock{t: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)}
+	cfg := map[string]any{
+		"dbPath":         filepath.Join(t.TempDir(), "alerts.db"),
+		"interval":       "1h",
+		"repeatInterval": "10m",
+		"rules": []any{map[string]any{
+			"name":       "slow-checkout",
+			"metric":     "p95_latency",
+			"scope":      "route",
+			"target":     "/checkout",
+			"threshold":  500,
+			"minSamples": 5,
+			"actions":    []any{map[string]any{"pipeline": "notify"}},
+		}},
+	}
+	m := startAlertTestModule(t, cfg, store, engine, clock)
+
+	// Too few samples: no value, no alert.
+	store.set(alertTestExecs("", "/checkout", 2, 0, 2*time.Second)...)
+	mustEvaluate(t, m)
+	if st := alertStateOf(m, "slow-checkout"); st.HasValue || st.State != AlertStateOK {
+		t.Fatalf("expected no data, got %+v", st)
+	}
+
+	store.set(alertTestExecs("", "/checkout", 5, 0, 2*time.Second)...)
+	mustEvaluate(t, m)
+	if st := alertStateOf(m, "slow-checkout"); st.State != AlertStateFiring || st.Value != 2000 {
+		t.Fatalf("expected firing at 2000ms, got %+v", st)
+	}
+	clock.advance(5 * time.Minute)
+	mustEvaluate(t, m)
+	clock.advance(5 * time.Minute)
+	mustEvaluate(t, m)
+	if got := engine.statuses(); len(got) != 2 {
+		t.Errorf("expected the notification to repeat after 10m, got %v", got)
+	}
+}
+
+func TestAlerting_SilenceSuppressesNotifications(t *testing.T) {
+	store := &fixedExecutionStore{}
+	engine := &recordingAlertEngine{}
+	clock := &alertTestClock{t: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)}
+	cfg := alertTestConfig(filepath.Join(t.TempDir(), "alerts.db"))
+	cfg["silences"] = []any{map[string]any{
+		"rules":   []any{"orders-errors"},
+		"start":   "2026-03-01T09:00:00Z",
+		"end":     "2026-03-01T11:00:00Z",
+		"comment": "maintenance",
+	}}
+	m := startAlertTestModule(t, cfg, store, engine, clock)
+
+	store.set(alertTestExecs("create-order", "", 0, 5, time.Second)...)
+	mustEvaluate(t, m)
+	clock.advance(5 * time.Minute)
+	mustEvaluate(t, m)
+	st := alertStateOf(m, "orders-errors")
+	if st.State != AlertStateFiring || !st.Silenced {
+		t.Fatalf("expected a silenced firing alert, got %+v", st)
+	}
+	if len(engine.statuses()) != 0 {
+		t.Fatalf("silenced alert notified: %v", engine.statuses())
+	}
+
+	// Once the silence ends, the still-firing alert notifies.
+	clock.advance(2 * time.Hour)
+	mustEvaluate(t, m)
+	if got := engine.statuses(); len(got) != 1 || got[0] != "firing" {
+		t.Errorf("expected a firing notification after the silence, got %v", got)
+	}
+}
+
+func TestAlerting_StatePersistsAcrossRestart(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "alerts.db")
+	store := &fixedExecutionStore{}
+	engine := &recordingAlertEngine{}
+	clock := &alertTestClock{t: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)}
+	cfg := alertTestConfig(dbPath)
+	cfg["rules"].([]any)[0].(map[string]any)["for"] = "0s"
+
+	m := startAlertTestModule(t, cfg, store, engine, clock)
+	store.set(alertTestExecs("create-order", "", 0, 5, time.Second)...)
+	mustEvaluate(t, m)
+	if err := m.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+
+	restarted := startAlertTestModule(t, cfg, store, engine, clock)
+	st := alertStateOf(restarted, "orders-errors")
+	if st.State != AlertStateFiring || st.Since == nil || st.LastNotified == nil {
+		t.Fatalf("state not restored: %+v", st)
+	}
+	clock.advance(time.Minute)
+	mustEvaluate(t, restarted)
+	if got := engine.statuses(); len(got) != 1 {
+		t.Errorf("restart re-notified a firing alert: %v", got)
+	}
+}
+
+func TestAlerting_Reconfigure(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "alerts.db")
+	store := &fixedExecutionStore{}
+	clock := &alertTestClock{t: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)}
+	m := startAlertTestModule(t, alertTestConfig(dbPath), store, &recordingAlertEngine{}, clock)
+	store.set(alertTestExecs("create-order", "", 0, 5, time.Second)...)
+	mustEvaluate(t, m)
+
+	// Raising the threshold keeps the rule's state until the next evaluation.
+	cfg := alertTestConfig(dbPath)
+	cfg["rules"].([]any)[0].(map[string]any)["threshold"] = 2
+	if err := m.Reconfigure(context.Background(), cfg); err != nil {
+		t.Fatalf("Reconfigure: %v", err)
+	}
+	if st := alertStateOf(m, "orders-errors"); st.State != AlertStatePending || st.Threshold != 2 {
+		t.Fatalf("expected the pending state to survive with the new threshold, got %+v", st)
+	}
+	mustEvaluate(t, m)
+	if st := alertStateOf(m, "orders-errors"); st.State != AlertStateOK {
+		t.Errorf("expected ok under the new threshold, got %+v", st)
+	}
+
+	// Removing every rule forgets their state.
+	cfg["rules"] = []any{}
+	if err := m.Reconfigure(context.Background(), cfg); err != nil {
+		t.Fatalf("Reconfigure: %v", err)
+	}
+	if alerts := m.Alerts(); len(alerts) != 0 {
+		t.Errorf("expected no alerts, got %+v", alerts)
+	}
+
+	cfg["dbPath"] = filepath.Join(t.TempDir(), "other.db")
+	if err := m.Reconfigure(context.Background(), cfg); err == nil || !strings.Contains(err.Error(), "requires restart") {
+		t.Errorf("expected a restart error for a dbPath change, got %v", err)
+	}
+	if err := m.Reconfigure(context.Background(), map[string]any{"rules": []any{"x"}}); err == nil {
+		t.Error("expected an error for an invalid config")
+	}
+}
+
+func TestAlerting_SlackAndWebhookActions(t *testing.T) {
+	var mu sync.Mutex
+	var posted map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		_ = json.NewDecoder(r.Body).Decode(&posted)
+	}))
+	defer srv.Close()
+
+	var slackMsg string
+	app := NewMockApplication()
+	app.Services["ops-slack"] = &FunctionMessageHandler{handleFunc: func(msg []byte) error {
+		slackMsg = string(msg)
+		return nil
+	}}
+	cfg := map[string]any{
+		"dbPath":   filepath.Join(t.TempDir(), "alerts.db"),
+		"interval": "1h",
+		"rules": []any{map[string]any{
+			"name":      "errors",
+			"metric":    "error_rate",
+			"threshold": 0.5,
+			"actions":   []any{map[string]any{"slack": "ops-slack"}, map[string]any{"webhook": srv.URL}},
+		}},
+	}
+	m := NewAlertingModule("alerts", cfg)
+	if err := m.Init(app); err != nil {
+		t.Fatal(err)
+	}
+	store := &fixedExecutionStore{}
+	store.set(alertTestExecs("p", "", 1, 3, time.Second)...)
+	m.SetEventStore(store)
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop(context.Background())
+	mustEvaluate(t, m)
+
+	if !strings.HasPrefix(slackMsg, "[FIRING] errors: error_rate is 0.75") {
+		t.Errorf("unexpected slack message %q", slackMsg)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if posted["alert"] != "errors" || posted["status"] != "firing" || posted["scope"] != "workflow" {
+		t.Errorf("unexpected webhook payload: %v", posted)
+	}
+}
+
+func TestAlerting_API(t *testing.T) {
+	store := &fixedExecutionStore{}
+	clock := &alertTestClock{t: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)}
+	m := startAlertTestModule(t, alertTestConfig(filepath.Join(t.TempDir(), "alerts.db")), store, &recordingAlertEngine{}, clock)
+	store.set(alertTestExecs("create-order", "", 0, 5, time.Second)...)
+	mustEvaluate(t, m)
+
+	rec := httptest.NewRecorder()
+	m.ServeAlerts(rec, httptest.NewRequest(http.MethodGet, "/api/alerts", nil))
+	var list struct {
+		Alerts []map[string]any `json:"alerts"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(list.Alerts) != 1 || list.Alerts[0]["rule"] != "orders-errors" || list.Alerts[0]["state"] != AlertStatePending {
+		t.Errorf("unexpected alerts: %s", rec.Body.String())
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/alerts/nope", nil)
+	req.SetPathValue("rule", "nope")
+	rec = httptest.NewRecorder()
+	m.ServeAlert(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown rule status = %d, want 404", rec.Code)
+	}
+}
+
+func TestAlerting_ConfigErrors(t *testing.T) {
+	rule := func(overrides map[string]any) map[string]any {
+		r := map[string]any{
+			"name": "r", "metric": "error_rate", "threshold": 0.1,
+			"actions": []any{map[string]any{"pipeline": "p"}},
+		}
+		for k, v := range overrides {
+			r[k] = v
+		}
+		return map[string]any{"rules": []any{r}}
+	}
+	tests := map[string]map[string]any{
+		"unknown metric":    rule(map[string]any{"metric": "cpu"}),
+		"missing threshold": rule(map[string]any{"threshold": nil}),
+		"pipeline target":   rule(map[string]any{"scope": "pipeline"}),
+		"queue target":      rule(map[string]any{"metric": "queue_depth"}),
+		"bad window":        rule(map[string]any{"window": "soon"}),
+		"no actions":        rule(map[string]any{"actions": []any{}}),
+		"two action kinds":  rule(map[string]any{"actions": []any{map[string]any{"pipeline": "p", "slack": "s"}}}),
+		"unknown silence":   {"rules": []any{}, "silences": []any{map[string]any{"rules": []any{"x"}, "end": "2026-03-01T00:00:00Z"}}},
+		"silence no end":    {"silences": []any{map[string]any{}}},
+		"bad interval":      {"interval": "0s"},
+	}
+	for name, cfg := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := NewAlertingModule("alerts", cfg).Init(NewMockApplication()); err == nil {
+				t.Fatal("expected a config error")
+			}
+		})
+	}
+}
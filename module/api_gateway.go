@@ -2,7 +2,6 @@ package module
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httputil"
@@ -188,14 +187,8 @@ func (g *APIGateway) SetRoutes(routes []GatewayRoute) error {
 
 		rp := httputil.NewSingleHostReverseProxy(backend)
 		backendHost := backend.Host
-		rp.ErrorHandler = func(w http.ResponseWriter, r *http.Request, _ error) {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadGateway)
-			_ = json.NewEncoder(w).Encode(map[string]string{
-				"error":   "backend unavailable",
-				"backend": backendHost,
-				"path":    r.URL.Path,
-			})
+		rp.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+			writeProxyProblem(w, r, backendHost, err)
 		}
 		g.proxies[route.PathPrefix] = rp
 
@@ -301,7 +294,7 @@ func (g *APIGateway) Handle(w http.ResponseWriter, r *http.Request) {
 	// Find matching route
 	route, proxy := g.matchRoute(r.URL.Path)
 	if route == nil {
-		http.Error(w, `{"error":"no route matched"}`, http.StatusNotFound)
+		writeRequestProblem(w, r, ProblemNotFound, "no route matched")
 		return
 	}
 
@@ -310,11 +303,7 @@ func (g *APIGateway) Handle(w http.ResponseWriter, r *http.Request) {
 	// Instance-level rate limiting (applied before per-route limits)
 	if g.instanceRateLimiter != nil {
 		if !g.instanceRateLimiter.allow(clientIP) {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusTooManyRequests)
-			_ = json.NewEncoder(w).Encode(map[string]string{
-				"error": "rate limit exceeded",
-			})
+			writeRequestProblem(w, r, ProblemRateLimited, "rate limit exceeded")
 			return
 		}
 	}
@@ -322,11 +311,7 @@ func (g *APIGateway) Handle(w http.ResponseWriter, r *http.Request) {
 	// Per-route rate limiting
 	if rl, ok := g.rateLimiters[route.PathPrefix]; ok {
 		if !rl.allow(clientIP) {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusTooManyRequests)
-			_ = json.NewEncoder(w).Encode(map[string]string{
-				"error": "rate limit exceeded",
-			})
+			writeRequestProblem(w, r, ProblemRateLimited, "rate limit exceeded")
 			return
 		}
 	}
@@ -334,22 +319,15 @@ func (g *APIGateway) Handle(w http.ResponseWriter, r *http.Request) {
 	// Auth check
 	if route.Auth && g.auth != nil {
 		if !g.checkAuth(r) {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusUnauthorized)
-			_ = json.NewEncoder(w).Encode(map[string]string{
-				"error": "unauthorized",
-			})
+			writeRequestProblem(w, r, ProblemUnauthorized, "missing or invalid credentials")
 			return
 		}
 	}
 
 	// Method check
 	if len(route.Methods) > 0 && !g.methodAllowed(r.Method, route.Methods) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"error": "method not allowed",
-		})
+		w.Header().Set("Allow", strings.Join(route.Methods, ", "))
+		writeRequestProblem(w, r, ProblemMethodNotAllowed, "method "+r.Method+" not allowed")
 		return
 	}
 
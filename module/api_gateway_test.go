@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 )
@@ -65,6 +66,9 @@ func TestAPIGateway_NoMatchReturns404(t *testing.T) {
 	if w.Code != http.StatusNotFound {
 		t.Errorf("expected 404, got %d", w.Code)
 	}
+	if ct := w.Header().Get("Content-Type"); ct != ProblemContentType || !strings.Contains(w.Body.String(), `"code":"not_found"`) {
+		t.Errorf("expected a not_found problem, got %q %s", ct, w.Body)
+	}
 }
 
 func TestAPIGateway_MethodNotAllowed(t *testing.T) {
@@ -85,6 +89,9 @@ func TestAPIGateway_MethodNotAllowed(t *testing.T) {
 	if w.Code != http.StatusMethodNotAllowed {
 		t.Errorf("expected 405, got %d", w.Code)
 	}
+	if w.Header().Get("Allow") != "GET" || !strings.Contains(w.Body.String(), `"code":"method_not_allowed"`) {
+		t.Errorf("expected a method_not_allowed problem with Allow: GET, got %q %s", w.Header().Get("Allow"), w.Body)
+	}
 }
 
 func TestAPIGateway_ProxiesToBackend(t *testing.T) {
@@ -0,0 +1,142 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow/interfaces"
+	"github.com/GoCodeAlone/workflow/sandbox"
+)
+
+// StepPreviewRequest is the body of POST /api/workflow/steps/preview.
+type StepPreviewRequest struct {
+	Type   string         `json:"type"`
+	Name   string         `json:"name,omitempty"`
+	Config map[string]any `json:"config,omitempty"`
+	Input  map[string]any `json:"input,omitempty"`
+}
+
+// StepPreviewResult is the outcome of a step preview. On success Output holds
+// the step output; on failure Error and Code (one of the sandbox.PreviewCode
+// values) describe why.
+type StepPreviewResult struct {
+	Output     map[string]any `json:"output,omitempty"`
+	NextStep   string         `json:"next_step,omitempty"`
+	Stop       bool           `json:"stop,omitempty"`
+	Error      string         `json:"error,omitempty"`
+	Code       string         `json:"code,omitempty"`
+	DurationMs int64          `json:"duration_ms"`
+}
+
+// SetStepRegistryFunc sets the callback returning the engine's step registry,
+// used to instantiate steps for previews. Without it the preview endpoint
+// responds 503.
+func (h *WorkflowUIHandler) SetStepRegistryFunc(fn func() interfaces.StepRegistrar) {
+	h.stepRegistry = fn
+}
+
+// handleStepPreview executes a single step in isolation. Only step types
+// accepted by the preview policy run; each one is built against an empty
+// application, so it cannot resolve engine services, and runs on a fresh
+// pipeline context seeded with the request input.
+func (h *WorkflowUIHandler) handleStepPreview(w http.ResponseWriter, r *http.Request) {
+	if h.stepRegistry == nil {
+		http.Error(w, "step preview not configured", http.StatusServiceUnavailable)
+		return
+	}
+	policy := h.previewPolicy
+
+	var req StepPreviewRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, policy.MaxRequestBytes)).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	if req.Type == "" {
+		http.Error(w, "type is required", http.StatusBadRequest)
+		return
+	}
+
+	status, result := previewStep(r.Context(), h.stepRegistry(), policy, req)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+// previewStep runs req under policy and returns the HTTP status and result.
+func previewStep(ctx context.Context, registry interfaces.StepRegistrar, policy sandbox.PreviewPolicy, req StepPreviewRequest) (int, *StepPreviewResult) {
+	start := time.Now()
+	fail := func(status int, code string, err error) (int, *StepPreviewResult) {
+		return status, &StepPreviewResult{Error: err.Error(), Code: code, DurationMs: time.Since(start).Milliseconds()}
+	}
+
+	if err := policy.CheckStepType(req.Type); err != nil {
+		return fail(http.StatusForbidden, sandbox.PreviewCodeNotAllowed, err)
+	}
+	name := req.Name
+	if name == "" {
+		name = "preview"
+	}
+	cfg := req.Config
+	if cfg == nil {
+		cfg = map[string]any{}
+	}
+	step, err := registry.Create(req.Type, name, cfg, previewApplication())
+	if err != nil {
+		return fail(http.StatusUnprocessableEntity, sandbox.PreviewCodeInvalidConfig, err)
+	}
+
+	ctx, cancel := policy.WithTimeout(ctx)
+	defer cancel()
+	type outcome struct {
+		res *StepResult
+		err error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- outcome{err: fmt.Errorf("step %q panicked: %v", name, p)}
+			}
+		}()
+		pc := NewPipelineContext(req.Input, map[string]any{"pipeline": "preview"})
+		res, err := step.Execute(ctx, pc)
+		done <- outcome{res, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return fail(http.StatusUnprocessableEntity, sandbox.PreviewCodeTimeout,
+			fmt.Errorf("step %q did not finish within %v", name, policy.Timeout))
+	case o := <-done:
+		if o.err != nil {
+			if errors.Is(o.err, context.DeadlineExceeded) {
+				return fail(http.StatusUnprocessableEntity, sandbox.PreviewCodeTimeout, o.err)
+			}
+			return fail(http.StatusUnprocessableEntity, sandbox.PreviewCodeExecutionFailed, o.err)
+		}
+		result := &StepPreviewResult{DurationMs: time.Since(start).Milliseconds()}
+		if o.res != nil {
+			result.Output = o.res.Output
+			result.NextStep = o.res.NextStep
+			result.Stop = o.res.Stop
+		}
+		return http.StatusOK, result
+	}
+}
+
+// previewApplication returns an empty application for preview steps, so a step
+// that looks up a service fails instead of reaching the running engine.
+func previewApplication() modular.Application {
+	return modular.NewStdApplication(modular.NewStdConfigProvider(nil), slog.New(slog.DiscardHandler))
+}
+
+// HandleStepPreview handles POST /api/workflow/steps/preview.
+func (h *WorkflowUIHandler) HandleStepPreview(w http.ResponseWriter, r *http.Request) {
+	h.handleStepPreview(w, r)
+}
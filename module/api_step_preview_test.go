@@ -0,0 +1,135 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow/interfaces"
+	"github.com/GoCodeAlone/workflow/sandbox"
+)
+
+// blockingStep never returns until its context is cancelled.
+type blockingStep struct{ name string }
+
+func (s *blockingStep) Name() string { return s.name }
+func (s *blockingStep) Execute(ctx context.Context, _ *PipelineContext) (*StepResult, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func newStepPreviewHandler(t *testing.T) (*WorkflowUIHandler, *http.ServeMux) {
+	t.Helper()
+	reg := NewStepRegistry()
+	reg.Register("step.set", NewSetStepFactory())
+	reg.Register("step.conditional", NewConditionalStepFactory())
+	reg.Register("step.transform", NewTransformStepFactory())
+	reg.Register("step.db_query", NewDBQueryStepFactory())
+	h := NewWorkflowUIHandler(nil)
+	h.SetStepRegistryFunc(func() interfaces.StepRegistrar { return reg })
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	return h, mux
+}
+
+func postStepPreview(t *testing.T, mux http.Handler, body string) (int, StepPreviewResult) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/workflow/steps/preview", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	var res StepPreviewResult
+	if w.Header().Get("Content-Type") == "application/json" {
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("decode %q: %v", w.Body.String(), err)
+		}
+	}
+	return w.Code, res
+}
+
+func TestStepPreview_RendersOutput(t *testing.T) {
+	_, mux := newStepPreviewHandler(t)
+	code, res := postStepPreview(t, mux, `{
+		"type": "step.set",
+		"config": {"values": {"greeting": "hello {{ .name }}"}},
+		"input": {"name": "ada"}
+	}`)
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, error = %q", code, res.Error)
+	}
+	if res.Output["greeting"] != "hello ada" {
+		t.Errorf("output = %v, want greeting=hello ada", res.Output)
+	}
+}
+
+func TestStepPreview_ConditionalRouting(t *testing.T) {
+	_, mux := newStepPreviewHandler(t)
+	code, res := postStepPreview(t, mux, `{
+		"type": "step.conditional",
+		"config": {"field": "tier", "routes": {"gold": "vip"}, "default": "standard"},
+		"input": {"tier": "gold"}
+	}`)
+	if code != http.StatusOK || res.NextStep != "vip" {
+		t.Errorf("status = %d, next_step = %q (error %q), want 200 and vip", code, res.NextStep, res.Error)
+	}
+}
+
+func TestStepPreview_RejectsSideEffectingStep(t *testing.T) {
+	_, mux := newStepPreviewHandler(t)
+	code, res := postStepPreview(t, mux, `{"type": "step.db_query", "config": {"database": "db", "query": "DELETE FROM users"}}`)
+	if code != http.StatusForbidden || res.Code != sandbox.PreviewCodeNotAllowed {
+		t.Fatalf("status = %d, code = %q, want 403 not_allowed", code, res.Code)
+	}
+	if !strings.Contains(res.Error, "side-effect-free") {
+		t.Errorf("error %q should explain why the step was rejected", res.Error)
+	}
+}
+
+func TestStepPreview_StructuredErrors(t *testing.T) {
+	_, mux := newStepPreviewHandler(t)
+
+	code, res := postStepPreview(t, mux, `{"type": "step.conditional", "config": {}}`)
+	if code != http.StatusUnprocessableEntity || res.Code != sandbox.PreviewCodeInvalidConfig {
+		t.Errorf("missing config: status = %d, code = %q, want 422 invalid_config", code, res.Code)
+	}
+
+	// A transformer reference cannot resolve: previews never see engine services.
+	code, res = postStepPreview(t, mux, `{"type": "step.transform", "config": {"transformer": "shared", "pipeline": "p"}}`)
+	if code != http.StatusUnprocessableEntity || res.Code != sandbox.PreviewCodeExecutionFailed {
+		t.Errorf("service lookup: status = %d, code = %q (%s), want 422 execution_failed", code, res.Code, res.Error)
+	}
+
+	code, _ = postStepPreview(t, mux, `{"config": {}}`)
+	if code != http.StatusBadRequest {
+		t.Errorf("missing type: status = %d, want 400", code)
+	}
+}
+
+func TestStepPreview_Timeout(t *testing.T) {
+	h, mux := newStepPreviewHandler(t)
+	reg := NewStepRegistry()
+	reg.Register("step.jq", func(name string, _ map[string]any, _ modular.Application) (PipelineStep, error) {
+		return &blockingStep{name: name}, nil
+	})
+	h.SetStepRegistryFunc(func() interfaces.StepRegistrar { return reg })
+	h.previewPolicy.Timeout = 20 * time.Millisecond
+
+	code, res := postStepPreview(t, mux, `{"type": "step.jq"}`)
+	if code != http.StatusUnprocessableEntity || res.Code != sandbox.PreviewCodeTimeout {
+		t.Errorf("status = %d, code = %q, want 422 timeout", code, res.Code)
+	}
+}
+
+func TestStepPreview_NotConfigured(t *testing.T) {
+	h := NewWorkflowUIHandler(nil)
+	req := httptest.NewRequest(http.MethodPost, "/api/workflow/steps/preview", strings.NewReader(`{"type":"step.set"}`))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", w.Code)
+	}
+}
@@ -0,0 +1,192 @@
+package module
+
+import (
+	"net/http"
+	"time"
+)
+
+// aiUsageResponse is the body of GET /ai/usage. Budget is set when the report
+// is for a single workflow that has an AI budget.
+type aiUsageResponse struct {
+	*AIUsageReport
+	Budget *aiBudgetStatus `json:"budget,omitempty"`
+}
+
+// aiBudgetStatus is a workflow's AI budget with its usage this month.
+type aiBudgetStatus struct {
+	*AIBudget
+	Period   string        `json:"period"`
+	Usage    AIUsageTotals `json:"usage"`
+	Exceeded bool          `json:"exceeded"`
+}
+
+// aiBudgetRequest is the body of PUT /workflows/{id}/ai-budget.
+type aiBudgetRequest struct {
+	MonthlyTokens int64   `json:"monthly_tokens"`
+	MonthlyCost   float64 `json:"monthly_cost"`
+	Action        string  `json:"action"`
+}
+
+// handleAI dispatches AI accounting requests.
+//
+// Handles:
+//
+//	GET /ai/usage -> AI token usage and cost: totals, per-pipeline and
+//	                 per-execution rollups and a time series
+//	                 (?workflow_id=, ?pipeline=, ?execution_id=, ?model=,
+//	                 ?from=, ?to=, ?bucket=hour|day|month)
+func (h *V1APIHandler) handleAI(w http.ResponseWriter, r *http.Request, rest []string) {
+	if len(rest) != 1 || rest[0] != "usage" {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	claims := h.requireAuth(w, r)
+	if claims == nil {
+		return
+	}
+
+	q := r.URL.Query()
+	filter := AIUsageFilter{
+		WorkflowID:  q.Get("workflow_id"),
+		Pipeline:    q.Get("pipeline"),
+		ExecutionID: q.Get("execution_id"),
+		Model:       q.Get("model"),
+		Bucket:      q.Get("bucket"),
+	}
+	// Usage across all workflows is only visible to admins.
+	if filter.WorkflowID == "" {
+		if claims.Role != "admin" {
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": "admin role required without workflow_id"})
+			return
+		}
+	} else if !h.authorizeScope(w, claims, filter.WorkflowID, "") {
+		return
+	}
+	for _, p := range []struct {
+		key string
+		dst *time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		v := q.Get(p.key)
+		if v == "" {
+			continue
+		}
+		t, err := parseAIUsageTime(v)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": p.key + " must be an RFC 3339 time or a YYYY-MM-DD date"})
+			return
+		}
+		*p.dst = t
+	}
+
+	if _, ok := aiUsageBucketFormats[filter.Bucket]; !ok && filter.Bucket != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "bucket must be hour, day or month"})
+		return
+	}
+
+	report, err := h.store.AIUsageReport(filter)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	resp := aiUsageResponse{AIUsageReport: report}
+	if filter.WorkflowID != "" {
+		if status, err := h.aiBudgetStatus(filter.WorkflowID); err == nil {
+			resp.Budget = status
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// parseAIUsageTime parses an RFC 3339 time or a date (midnight UTC).
+func parseAIUsageTime(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, v)
+}
+
+// aiBudgetStatus returns the workflow's budget and its usage this month.
+func (h *V1APIHandler) aiBudgetStatus(workflowID string) (*aiBudgetStatus, error) {
+	budget, err := h.store.GetAIBudget(workflowID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	usage, err := h.store.MonthlyAIUsage(workflowID, now)
+	if err != nil {
+		return nil, err
+	}
+	return &aiBudgetStatus{AIBudget: budget, Period: aiUsageMonth(now), Usage: usage, Exceeded: budget.Exceeded(usage)}, nil
+}
+
+// handleAIBudget handles a workflow's monthly AI budget.
+//
+// Handles:
+//
+//	GET    /workflows/{id}/ai-budget -> budget with this month's usage
+//	PUT    /workflows/{id}/ai-budget -> set budget {monthly_tokens, monthly_cost, action: notify|fail}
+//	DELETE /workflows/{id}/ai-budget -> remove budget
+func (h *V1APIHandler) handleAIBudget(w http.ResponseWriter, r *http.Request, workflowID string) {
+	claims := h.requireAuth(w, r)
+	if claims == nil {
+		return
+	}
+	if !h.authorizeScope(w, claims, workflowID, "") {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		status, err := h.aiBudgetStatus(workflowID)
+		if err != nil {
+			if isNotFound(err) {
+				writeJSON(w, http.StatusNotFound, map[string]string{"error": "no AI budget set"})
+			} else {
+				writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			}
+			return
+		}
+		writeJSON(w, http.StatusOK, status)
+	case http.MethodPut:
+		var req aiBudgetRequest
+		if err := decodeBody(r, &req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+			return
+		}
+		budget := &AIBudget{WorkflowID: workflowID, MonthlyTokens: req.MonthlyTokens, MonthlyCost: req.MonthlyCost, Action: req.Action}
+		if budget.Action == "" {
+			budget.Action = AIBudgetActionNotify
+		}
+		if err := budget.Validate(); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		if err := h.store.SetAIBudget(budget); err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		status, err := h.aiBudgetStatus(workflowID)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, status)
+	case http.MethodDelete:
+		if err := h.store.DeleteAIBudget(workflowID); err != nil {
+			if isNotFound(err) {
+				writeJSON(w, http.StatusNotFound, map[string]string{"error": "no AI budget set"})
+			} else {
+				writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			}
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+	}
+}
@@ -0,0 +1,531 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/audit"
+)
+
+// Bulk workflow actions.
+const (
+	BulkActionDeploy    = "deploy"
+	BulkActionStop      = "stop"
+	BulkActionRestart   = "restart"
+	BulkActionDelete    = "delete"
+	BulkActionAddTag    = "add_tag"
+	BulkActionRemoveTag = "remove_tag"
+)
+
+// Bulk operation and item statuses. Items start pending, run, and end
+// succeeded, failed or cancelled. An operation is running until no item is
+// pending or running; it then ends failed if any item failed, cancelled if
+// any item was cancelled, and succeeded otherwise.
+const (
+	BulkStatusPending   = "pending"
+	BulkStatusRunning   = "running"
+	BulkStatusSucceeded = "succeeded"
+	BulkStatusFailed    = "failed"
+	BulkStatusCancelled = "cancelled"
+)
+
+const (
+	// defaultBulkConcurrency is the number of items run at once when a
+	// request does not set one.
+	defaultBulkConcurrency = 4
+	// maxBulkConcurrency bounds the concurrency a request may ask for.
+	maxBulkConcurrency = 32
+	// maxBulkItems bounds the workflows one operation may select.
+	maxBulkItems = 1000
+	// maxRetainedBulkOperations bounds the operations kept in memory; the
+	// oldest finished ones are dropped first.
+	maxRetainedBulkOperations = 100
+)
+
+// BulkSelector selects the workflows of a bulk operation: either explicit
+// IDs, or every non-system workflow carrying all of Tags and/or belonging to
+// ProjectID.
+type BulkSelector struct {
+	IDs       []string `json:"ids,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+	ProjectID string   `json:"project_id,omitempty"`
+}
+
+// BulkOptions control how a bulk operation runs. Without ContinueOnError the
+// first failed item cancels the items that have not started.
+type BulkOptions struct {
+	ContinueOnError bool `json:"continue_on_error"`
+	Concurrency     int  `json:"concurrency"`
+}
+
+// BulkOperationItem is the progress of one workflow in a bulk operation.
+type BulkOperationItem struct {
+	WorkflowID string `json:"workflow_id"`
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	StartedAt  string `json:"started_at,omitempty"`
+	FinishedAt string `json:"finished_at,omitempty"`
+}
+
+// BulkOperationCounts counts a bulk operation's items by status.
+type BulkOperationCounts struct {
+	Total     int `json:"total"`
+	Pending   int `json:"pending"`
+	Running   int `json:"running"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	Cancelled int `json:"cancelled"`
+}
+
+// BulkOperation is an asynchronous action applied to a set of workflows.
+type BulkOperation struct {
+	ID              string              `json:"id"`
+	Action          string              `json:"action"`
+	Tags            []string            `json:"tags,omitempty"`
+	Selector        BulkSelector        `json:"selector"`
+	Options         BulkOptions         `json:"options"`
+	Status          string              `json:"status"`
+	CancelRequested bool                `json:"cancel_requested,omitempty"`
+	Counts          BulkOperationCounts `json:"counts"`
+	Items           []BulkOperationItem `json:"items,omitempty"`
+	CreatedBy       string              `json:"created_by"`
+	CreatedAt       string              `json:"created_at"`
+	FinishedAt      string              `json:"finished_at,omitempty"`
+
+	owner string // user ID of the creator
+}
+
+// bulkOperations tracks bulk operations in memory.
+type bulkOperations struct {
+	mu    sync.Mutex
+	ops   map[string]*BulkOperation
+	order []string // IDs in creation order
+}
+
+func newBulkOperations() *bulkOperations {
+	return &bulkOperations{ops: make(map[string]*BulkOperation)}
+}
+
+// snapshot returns a copy of op that is safe to use without the lock. The
+// caller holds b.mu.
+func (op *BulkOperation) snapshot(withItems bool) BulkOperation {
+	c := *op
+	c.Items = nil
+	if withItems {
+		c.Items = slices.Clone(op.Items)
+	}
+	return c
+}
+
+// add tracks a new operation, dropping the oldest finished operations past
+// maxRetainedBulkOperations, and returns its snapshot.
+func (b *bulkOperations) add(op *BulkOperation) BulkOperation {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	op.refresh()
+	b.ops[op.ID] = op
+	b.order = append(b.order, op.ID)
+	for i := 0; len(b.order) > maxRetainedBulkOperations && i < len(b.order); {
+		id := b.order[i]
+		if b.ops[id].FinishedAt == "" {
+			i++
+			continue
+		}
+		delete(b.ops, id)
+		b.order = slices.Delete(b.order, i, i+1)
+	}
+	return op.snapshot(true)
+}
+
+// get returns a snapshot of an operation visible to the claims: their own,
+// or any operation for admins.
+func (b *bulkOperations) get(id string, claims *userClaims) (BulkOperation, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	op, ok := b.ops[id]
+	if !ok || !op.visibleTo(claims) {
+		return BulkOperation{}, false
+	}
+	return op.snapshot(true), true
+}
+
+// list returns the operations visible to the claims, newest first, without
+// their items.
+func (b *bulkOperations) list(claims *userClaims) []BulkOperation {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	result := []BulkOperation{}
+	for i := len(b.order) - 1; i >= 0; i-- {
+		if op := b.ops[b.order[i]]; op.visibleTo(claims) {
+			result = append(result, op.snapshot(false))
+		}
+	}
+	return result
+}
+
+// start moves a pending item to running and reports whether it should run.
+// Cancelled items are skipped.
+func (b *bulkOperations) start(op *BulkOperation, i int) (string, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	item := &op.Items[i]
+	if item.Status != BulkStatusPending {
+		return "", false
+	}
+	item.Status = BulkStatusRunning
+	item.StartedAt = nowStr()
+	op.refresh()
+	return item.WorkflowID, true
+}
+
+// finish records an item's result. Without ContinueOnError a failure
+// cancels every item that has not started.
+func (b *bulkOperations) finish(op *BulkOperation, i int, err error) BulkOperationItem {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	item := &op.Items[i]
+	item.FinishedAt = nowStr()
+	item.Status = BulkStatusSucceeded
+	if err != nil {
+		item.Status = BulkStatusFailed
+		item.Error = err.Error()
+		if !op.Options.ContinueOnError {
+			op.cancelPending("cancelled after an earlier item failed")
+		}
+	}
+	op.refresh()
+	return *item
+}
+
+// cancel cancels the items of an operation that have not started. Running
+// items finish. It returns the operation's snapshot, or an error if it does
+// not exist or has already finished.
+func (b *bulkOperations) cancel(id string, claims *userClaims) (BulkOperation, int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	op, ok := b.ops[id]
+	if !ok || !op.visibleTo(claims) {
+		return BulkOperation{}, http.StatusNotFound, errors.New("operation not found")
+	}
+	if op.FinishedAt != "" {
+		return BulkOperation{}, http.StatusConflict, fmt.Errorf("operation already %s", op.Status)
+	}
+	op.CancelRequested = true
+	op.cancelPending("cancelled by " + claims.actor())
+	op.refresh()
+	return op.snapshot(true), http.StatusOK, nil
+}
+
+// cancelPending cancels every pending item. The caller holds the lock.
+func (op *BulkOperation) cancelPending(reason string) {
+	now := nowStr()
+	for i := range op.Items {
+		if op.Items[i].Status == BulkStatusPending {
+			op.Items[i].Status = BulkStatusCancelled
+			op.Items[i].Error = reason
+			op.Items[i].FinishedAt = now
+		}
+	}
+}
+
+// refresh recounts the items and derives the operation's status. The caller
+// holds the lock.
+func (op *BulkOperation) refresh() {
+	c := BulkOperationCounts{Total: len(op.Items)}
+	for i := range op.Items {
+		switch op.Items[i].Status {
+		case BulkStatusPending:
+			c.Pending++
+		case BulkStatusRunning:
+			c.Running++
+		case BulkStatusSucceeded:
+			c.Succeeded++
+		case BulkStatusFailed:
+			c.Failed++
+		case BulkStatusCancelled:
+			c.Cancelled++
+		}
+	}
+	op.Counts = c
+	switch {
+	case c.Pending > 0 || c.Running > 0:
+		op.Status = BulkStatusRunning
+	case c.Failed > 0:
+		op.Status = BulkStatusFailed
+	case c.Cancelled > 0:
+		op.Status = BulkStatusCancelled
+	default:
+		op.Status = BulkStatusSucceeded
+	}
+	if op.Status != BulkStatusRunning && op.FinishedAt == "" {
+		op.FinishedAt = nowStr()
+	}
+}
+
+func (op *BulkOperation) visibleTo(claims *userClaims) bool {
+	return claims.Role == "admin" || op.owner == claims.UserID
+}
+
+// actor returns the name recorded for actions taken with the claims: the
+// email, falling back to the user ID.
+func (c *userClaims) actor() string {
+	if c.Email != "" {
+		return c.Email
+	}
+	return c.UserID
+}
+
+// SetAuditStore sets where the item-level actions of bulk operations are
+// recorded. Bulk operations work without it.
+func (h *V1APIHandler) SetAuditStore(store audit.Store) {
+	h.auditStore = store
+}
+
+// createBulkOperation handles POST /workflows/bulk. It resolves the selector,
+// starts the operation in the background and answers 202 with the operation
+// and its Location.
+func (h *V1APIHandler) createBulkOperation(w http.ResponseWriter, r *http.Request) {
+	claims := h.requireAuth(w, r)
+	if claims == nil {
+		return
+	}
+
+	var req struct {
+		Action   string       `json:"action"`
+		Tags     []string     `json:"tags"`
+		Selector BulkSelector `json:"selector"`
+		Options  BulkOptions  `json:"options"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	switch req.Action {
+	case BulkActionDeploy, BulkActionStop, BulkActionRestart, BulkActionDelete:
+		req.Tags = nil
+	case BulkActionAddTag, BulkActionRemoveTag:
+		tags, err := normalizeWorkflowTags(req.Tags)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		if len(tags) == 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": req.Action + " requires tags"})
+			return
+		}
+		req.Tags = tags
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("unknown action %q (valid: deploy, stop, restart, delete, add_tag, remove_tag)", req.Action)})
+		return
+	}
+
+	if req.Options.Concurrency == 0 {
+		req.Options.Concurrency = defaultBulkConcurrency
+	}
+	if req.Options.Concurrency < 1 || req.Options.Concurrency > maxBulkConcurrency {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("options.concurrency must be between 1 and %d", maxBulkConcurrency)})
+		return
+	}
+
+	items, err := h.selectBulkWorkflows(&req.Selector)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	op := &BulkOperation{
+		ID:        newID(),
+		Action:    req.Action,
+		Tags:      req.Tags,
+		Selector:  req.Selector,
+		Options:   req.Options,
+		Items:     items,
+		CreatedBy: claims.actor(),
+		CreatedAt: nowStr(),
+		owner:     claims.UserID,
+	}
+	snap := h.bulk.add(op)
+	go h.runBulkOperation(op, claims)
+
+	w.Header().Set("Location", "/api/v1/operations/"+op.ID)
+	writeJSON(w, http.StatusAccepted, snap)
+}
+
+// selectBulkWorkflows resolves a selector to pending items. Explicit IDs must
+// all exist; tag and project filters skip the system workflow. The selector's
+// tags are normalized in place.
+func (h *V1APIHandler) selectBulkWorkflows(sel *BulkSelector) ([]BulkOperationItem, error) {
+	var items []BulkOperationItem
+	switch {
+	case len(sel.IDs) > 0:
+		if len(sel.Tags) > 0 || sel.ProjectID != "" {
+			return nil, errors.New("selector.ids cannot be combined with selector.tags or selector.project_id")
+		}
+		seen := make(map[string]bool, len(sel.IDs))
+		for _, id := range sel.IDs {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			wf, err := h.store.GetWorkflow(id)
+			if err != nil {
+				return nil, fmt.Errorf("workflow %s not found", id)
+			}
+			items = append(items, BulkOperationItem{WorkflowID: wf.ID, Name: wf.Name, Status: BulkStatusPending})
+		}
+	case len(sel.Tags) > 0 || sel.ProjectID != "":
+		tags, err := normalizeWorkflowTags(sel.Tags)
+		if err != nil {
+			return nil, fmt.Errorf("selector: %w", err)
+		}
+		sel.Tags = tags
+		wfs, err := h.store.ListWorkflows(sel.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		for i := range wfs {
+			if !wfs[i].IsSystem && wfs[i].hasAllTags(tags) {
+				items = append(items, BulkOperationItem{WorkflowID: wfs[i].ID, Name: wfs[i].Name, Status: BulkStatusPending})
+			}
+		}
+	default:
+		return nil, errors.New("selector requires ids, tags or project_id")
+	}
+	if len(items) == 0 {
+		return nil, errors.New("selector matched no workflows")
+	}
+	if len(items) > maxBulkItems {
+		return nil, fmt.Errorf("selector matched %d workflows; at most %d can be changed at once", len(items), maxBulkItems)
+	}
+	return items, nil
+}
+
+// runBulkOperation runs an operation's items with at most
+// Options.Concurrency at once.
+func (h *V1APIHandler) runBulkOperation(op *BulkOperation, claims *userClaims) {
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for range min(op.Options.Concurrency, len(op.Items)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				h.runBulkItem(op, i, claims)
+			}
+		}()
+	}
+	for i := range op.Items {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+}
+
+// runBulkItem applies the operation's action to one workflow, unless the item
+// was cancelled, and audits the result.
+func (h *V1APIHandler) runBulkItem(op *BulkOperation, i int, claims *userClaims) {
+	id, ok := h.bulk.start(op, i)
+	if !ok {
+		return
+	}
+	ctx := context.Background()
+	err := h.applyBulkAction(ctx, op, id, claims)
+	item := h.bulk.finish(op, i, err)
+
+	if h.auditStore == nil {
+		return
+	}
+	meta := map[string]any{"operation_id": op.ID, "workflow_name": item.Name}
+	if len(op.Tags) > 0 {
+		meta["tags"] = op.Tags
+	}
+	if auditErr := h.auditStore.Record(ctx, audit.Event{
+		Timestamp: time.Now().UTC(),
+		Type:      audit.EventAdminOp,
+		Action:    "workflow.bulk." + op.Action,
+		Actor:     claims.actor(),
+		Resource:  "workflow:" + id,
+		Detail:    item.Error,
+		Success:   err == nil,
+		Metadata:  meta,
+	}); auditErr != nil {
+		log.Printf("workflow engine: failed to audit bulk %s of workflow %s: %v", op.Action, id, auditErr)
+	}
+}
+
+// applyBulkAction applies an operation's action to one workflow with the
+// same access rules as the single-workflow endpoints.
+func (h *V1APIHandler) applyBulkAction(ctx context.Context, op *BulkOperation, id string, claims *userClaims) error {
+	wf, err := h.store.GetWorkflow(id)
+	if err != nil {
+		return errors.New("workflow not found")
+	}
+	if wf.IsSystem && claims.Role != "admin" {
+		return errors.New("admin role required")
+	}
+
+	switch op.Action {
+	case BulkActionDeploy:
+		_, err = h.startWorkflow(ctx, wf, false, claims.actor())
+	case BulkActionRestart:
+		_, err = h.startWorkflow(ctx, wf, true, claims.actor())
+	case BulkActionStop:
+		h.stopRuntimeInstance(ctx, id)
+		_, err = h.store.SetWorkflowStatus(id, "stopped")
+	case BulkActionDelete:
+		if wf.IsSystem {
+			return errors.New("cannot delete system workflow")
+		}
+		h.stopRuntimeInstance(ctx, id)
+		err = h.store.DeleteWorkflow(id)
+	case BulkActionAddTag:
+		_, err = h.store.AddWorkflowTags(id, op.Tags...)
+	case BulkActionRemoveTag:
+		_, err = h.store.RemoveWorkflowTags(id, op.Tags...)
+	}
+	return err
+}
+
+// handleOperations dispatches bulk operation requests. Users see their own
+// operations; admins see every operation.
+//
+// Handles:
+//
+//	GET  /operations              -> list operations, newest first, without items
+//	GET  /operations/{id}         -> operation with per-item status
+//	POST /operations/{id}/cancel  -> cancel the items that have not started
+func (h *V1APIHandler) handleOperations(w http.ResponseWriter, r *http.Request, rest []string) {
+	claims := h.requireAuth(w, r)
+	if claims == nil {
+		return
+	}
+
+	switch {
+	case len(rest) == 0 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, h.bulk.list(claims))
+	case len(rest) == 1 && r.Method == http.MethodGet:
+		op, ok := h.bulk.get(rest[0], claims)
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "operation not found"})
+			return
+		}
+		writeJSON(w, http.StatusOK, op)
+	case len(rest) == 2 && rest[1] == "cancel" && r.Method == http.MethodPost:
+		op, status, err := h.bulk.cancel(rest[0], claims)
+		if err != nil {
+			writeJSON(w, status, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, op)
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+	}
+}
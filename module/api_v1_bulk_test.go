@@ -0,0 +1,384 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/audit"
+	"github.com/GoCodeAlone/workflow/config"
+	"github.com/stretchr/testify/require"
+)
+
+// brokenWorkflowYAML is a config the bulk test builder refuses to start.
+const brokenWorkflowYAML = "modules:\n  - name: broken\n    type: test.broken\n"
+
+// lockedAuditStore records audit events from concurrent bulk items.
+type lockedAuditStore struct {
+	mu     sync.Mutex
+	events []audit.Event
+}
+
+func (s *lockedAuditStore) Record(_ context.Context, event audit.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *lockedAuditStore) recorded() []audit.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]audit.Event(nil), s.events...)
+}
+
+// bulkTestBuilder starts engines instantly, fails broken configs, and blocks
+// while gate is non-nil until it is closed.
+func bulkTestBuilder(gate <-chan struct{}) RuntimeEngineBuilder {
+	return func(cfg *config.WorkflowConfig, _ *slog.Logger) (func(context.Context) error, error) {
+		if gate != nil {
+			<-gate
+		}
+		if len(cfg.Modules) > 0 && cfg.Modules[0].Name == "broken" {
+			return nil, errors.New("engine build failed")
+		}
+		return func(context.Context) error { return nil }, nil
+	}
+}
+
+func setupBulkHandler(t *testing.T, gate <-chan struct{}) (*V1APIHandler, *V1Store, *RuntimeManager, *lockedAuditStore, string, *V1Project) {
+	t.Helper()
+	handler, store, secret := setupTestHandler(t)
+	rm := NewRuntimeManager(store, bulkTestBuilder(gate), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	handler.SetRuntimeManager(rm)
+	auditStore := &lockedAuditStore{}
+	handler.SetAuditStore(auditStore)
+	company := mustCreateCompany(t, store, "Acme", "", "user-1")
+	org := mustCreateOrganization(t, store, company.ID, "Acme Org", "", "user-1")
+	project := mustCreateProject(t, store, org.ID, "Orders", "", "")
+	return handler, store, rm, auditStore, generateTestToken(secret, "user-1", "user@test.com", "user"), project
+}
+
+func mustCreateBulkWorkflow(t *testing.T, store *V1Store, projectID, name, configYAML string, tags ...string) *V1Workflow {
+	t.Helper()
+	wf, err := store.CreateWorkflow(projectID, name, "", "", configYAML, "user-1")
+	require.NoError(t, err)
+	if len(tags) > 0 {
+		_, err = store.SetWorkflowTags(wf.ID, tags)
+		require.NoError(t, err)
+	}
+	return wf
+}
+
+func startBulkOperation(t *testing.T, handler *V1APIHandler, token, body string) BulkOperation {
+	t.Helper()
+	rr := doRequest(handler, "POST", "/api/v1/workflows/bulk", body, token)
+	require.Equal(t, http.StatusAccepted, rr.Code, rr.Body.String())
+	var op BulkOperation
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &op))
+	require.Equal(t, "/api/v1/operations/"+op.ID, rr.Header().Get("Location"))
+	return op
+}
+
+func getBulkOperation(t *testing.T, handler *V1APIHandler, token, id string) BulkOperation {
+	t.Helper()
+	rr := doRequest(handler, "GET", "/api/v1/operations/"+id, "", token)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var op BulkOperation
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &op))
+	return op
+}
+
+func awaitBulkOperation(t *testing.T, handler *V1APIHandler, token, id string) BulkOperation {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		op := getBulkOperation(t, handler, token, id)
+		if op.Status != BulkStatusRunning {
+			return op
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("operation still running: %+v", op)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestV1Handler_WorkflowTags(t *testing.T) {
+	handler, store, _, _, token, project := setupBulkHandler(t, nil)
+	wf := mustCreateBulkWorkflow(t, store, project.ID, "Orders", "modules: []")
+	other := mustCreateBulkWorkflow(t, store, project.ID, "Billing", "modules: []", "billing")
+	path := "/api/v1/workflows/" + wf.ID + "/tags"
+
+	rr := doRequest(handler, "PUT", path, `{"tags":["Nightly","team-a","nightly"]}`, token)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	require.JSONEq(t, `{"workflow_id":"`+wf.ID+`","tags":["nightly","team-a"]}`, rr.Body.String())
+
+	rr = doRequest(handler, "POST", path, `{"tags":["critical"]}`, token)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.JSONEq(t, `{"workflow_id":"`+wf.ID+`","tags":["critical","nightly","team-a"]}`, rr.Body.String())
+
+	rr = doRequest(handler, "DELETE", path+"/team-a", "", token)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.JSONEq(t, `{"workflow_id":"`+wf.ID+`","tags":["critical","nightly"]}`, rr.Body.String())
+
+	rr = doRequest(handler, "POST", path, `{"tags":["no spaces"]}`, token)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	// Tags are part of the workflow record and filter the workflow list.
+	got, err := store.GetWorkflow(wf.ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"critical", "nightly"}, got.Tags)
+
+	rr = doRequest(handler, "GET", "/api/v1/workflows?tag=nightly", "", token)
+	require.Equal(t, http.StatusOK, rr.Code)
+	var wfs []V1Workflow
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &wfs))
+	require.Len(t, wfs, 1)
+	require.Equal(t, wf.ID, wfs[0].ID)
+
+	rr = doRequest(handler, "GET", "/api/v1/workflows/"+other.ID+"/tags", "", token)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.JSONEq(t, `{"workflow_id":"`+other.ID+`","tags":["billing"]}`, rr.Body.String())
+
+	// Tags are removed with their workflow.
+	require.NoError(t, store.DeleteWorkflow(wf.ID))
+	tags, err := store.WorkflowTags(wf.ID)
+	require.NoError(t, err)
+	require.Empty(t, tags)
+}
+
+func TestV1Handler_BulkRestartByTagAndStopByIDs(t *testing.T) {
+	handler, store, rm, auditStore, token, project := setupBulkHandler(t, nil)
+	a := mustCreateBulkWorkflow(t, store, project.ID, "A", "modules: []", "nightly")
+	b := mustCreateBulkWorkflow(t, store, project.ID, "B", "modules: []", "nightly", "team-a")
+	c := mustCreateBulkWorkflow(t, store, project.ID, "C", "modules: []")
+	require.NoError(t, rm.LaunchFromWorkspace(context.Background(), a.ID, a.Name, a.ConfigYAML, ""))
+
+	op := startBulkOperation(t, handler, token, `{"action":"restart","selector":{"tags":["nightly"]},"options":{"concurrency":2}}`)
+	require.Equal(t, 2, op.Counts.Total)
+	op = awaitBulkOperation(t, handler, token, op.ID)
+	require.Equal(t, BulkStatusSucceeded, op.Status, "%+v", op)
+	require.Equal(t, 2, op.Counts.Succeeded)
+	for _, id := range []string{a.ID, b.ID} {
+		inst, ok := rm.GetInstance(id)
+		require.True(t, ok)
+		require.Equal(t, "running", inst.Status)
+		wf, err := store.GetWorkflow(id)
+		require.NoError(t, err)
+		require.Equal(t, "active", wf.Status)
+	}
+	_, ok := rm.GetInstance(c.ID)
+	require.False(t, ok, "untagged workflow must not be restarted")
+
+	op = startBulkOperation(t, handler, token, `{"action":"stop","selector":{"ids":["`+a.ID+`","`+b.ID+`","`+a.ID+`"]}}`)
+	require.Equal(t, 2, op.Counts.Total, "duplicate IDs are selected once")
+	op = awaitBulkOperation(t, handler, token, op.ID)
+	require.Equal(t, BulkStatusSucceeded, op.Status)
+	inst, _ := rm.GetInstance(a.ID)
+	require.Equal(t, "stopped", inst.Status)
+
+	// Every item produced its own audit record.
+	events := auditStore.recorded()
+	require.Len(t, events, 4)
+	counts := map[string]int{}
+	for _, e := range events {
+		require.Equal(t, audit.EventAdminOp, e.Type)
+		require.Equal(t, "user@test.com", e.Actor)
+		require.True(t, e.Success)
+		require.NotEmpty(t, e.Metadata["operation_id"])
+		counts[e.Action+" "+e.Resource]++
+	}
+	require.Equal(t, map[string]int{
+		"workflow.bulk.restart workflow:" + a.ID: 1,
+		"workflow.bulk.restart workflow:" + b.ID: 1,
+		"workflow.bulk.stop workflow:" + a.ID:    1,
+		"workflow.bulk.stop workflow:" + b.ID:    1,
+	}, counts)
+
+	rr := doRequest(handler, "GET", "/api/v1/operations", "", token)
+	require.Equal(t, http.StatusOK, rr.Code)
+	var ops []BulkOperation
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &ops))
+	require.Len(t, ops, 2)
+	require.Equal(t, BulkActionStop, ops[0].Action, "newest first")
+	require.Empty(t, ops[0].Items)
+}
+
+func TestV1Handler_BulkFailureHandling(t *testing.T) {
+	handler, store, _, auditStore, token, project := setupBulkHandler(t, nil)
+	broken := mustCreateBulkWorkflow(t, store, project.ID, "Broken", brokenWorkflowYAML)
+	a := mustCreateBulkWorkflow(t, store, project.ID, "A", "modules: []")
+	b := mustCreateBulkWorkflow(t, store, project.ID, "B", "modules: []")
+	ids := `["` + broken.ID + `","` + a.ID + `","` + b.ID + `"]`
+
+	// Without continue_on_error the first failure cancels the rest.
+	op := startBulkOperation(t, handler, token, `{"action":"deploy","selector":{"ids":`+ids+`},"options":{"concurrency":1}}`)
+	op = awaitBulkOperation(t, handler, token, op.ID)
+	require.Equal(t, BulkStatusFailed, op.Status)
+	require.Equal(t, BulkOperationCounts{Total: 3, Failed: 1, Cancelled: 2}, op.Counts)
+	require.Contains(t, op.Items[0].Error, "engine build failed")
+	require.Equal(t, BulkStatusCancelled, op.Items[1].Status)
+	require.Len(t, auditStore.recorded(), 1, "cancelled items are not audited")
+	require.False(t, auditStore.recorded()[0].Success)
+
+	op = startBulkOperation(t, handler, token, `{"action":"deploy","selector":{"ids":`+ids+`},"options":{"concurrency":1,"continue_on_error":true}}`)
+	op = awaitBulkOperation(t, handler, token, op.ID)
+	require.Equal(t, BulkStatusFailed, op.Status)
+	require.Equal(t, BulkOperationCounts{Total: 3, Failed: 1, Succeeded: 2}, op.Counts)
+	got, err := store.GetWorkflow(broken.ID)
+	require.NoError(t, err)
+	require.Equal(t, "error", got.Status)
+}
+
+func TestV1Handler_BulkCancelAndAccess(t *testing.T) {
+	gate := make(chan struct{})
+	handler, store, _, _, token, project := setupBulkHandler(t, gate)
+	var ids []string
+	for _, name := range []string{"A", "B", "C"} {
+		ids = append(ids, mustCreateBulkWorkflow(t, store, project.ID, name, "modules: []", "batch").ID)
+	}
+
+	op := startBulkOperation(t, handler, token, `{"action":"deploy","selector":{"tags":["batch"]},"options":{"concurrency":1}}`)
+	deadline := time.Now().Add(5 * time.Second)
+	for getBulkOperation(t, handler, token, op.ID).Counts.Running != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("first item did not start")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Other users cannot see or cancel the operation.
+	other := generateTestToken("test-secret-key", "user-2", "other@test.com", "user")
+	rr := doRequest(handler, "POST", "/api/v1/operations/"+op.ID+"/cancel", "", other)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = doRequest(handler, "POST", "/api/v1/operations/"+op.ID+"/cancel", "", token)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var cancelled BulkOperation
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &cancelled))
+	require.True(t, cancelled.CancelRequested)
+	require.Equal(t, BulkOperationCounts{Total: 3, Running: 1, Cancelled: 2}, cancelled.Counts)
+
+	close(gate)
+	op = awaitBulkOperation(t, handler, token, op.ID)
+	require.Equal(t, BulkStatusCancelled, op.Status)
+	require.Equal(t, BulkOperationCounts{Total: 3, Succeeded: 1, Cancelled: 2}, op.Counts)
+
+	rr = doRequest(handler, "POST", "/api/v1/operations/"+op.ID+"/cancel", "", token)
+	require.Equal(t, http.StatusConflict, rr.Code)
+
+	admin := generateTestToken("test-secret-key", "admin-1", "admin@test.com", "admin")
+	rr = doRequest(handler, "GET", "/api/v1/operations/"+op.ID, "", admin)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	// Bulk delete removes the workflows and their tags.
+	op = startBulkOperation(t, handler, token, `{"action":"delete","selector":{"project_id":"`+project.ID+`"}}`)
+	op = awaitBulkOperation(t, handler, token, op.ID)
+	require.Equal(t, BulkStatusSucceeded, op.Status, "%+v", op)
+	for _, id := range ids {
+		_, err := store.GetWorkflow(id)
+		require.Error(t, err)
+	}
+}
+
+func TestV1Handler_BulkTagActionsAndValidation(t *testing.T) {
+	handler, store, _, _, token, project := setupBulkHandler(t, nil)
+	a := mustCreateBulkWorkflow(t, store, project.ID, "A", "modules: []", "team-a")
+	b := mustCreateBulkWorkflow(t, store, project.ID, "B", "modules: []", "team-a", "legacy")
+	_, _, _, systemID, err := store.EnsureSystemHierarchy("system", "")
+	require.NoError(t, err)
+
+	op := startBulkOperation(t, handler, token, `{"action":"add_tag","tags":["Nightly"],"selector":{"tags":["team-a"]}}`)
+	op = awaitBulkOperation(t, handler, token, op.ID)
+	require.Equal(t, BulkStatusSucceeded, op.Status)
+	op = startBulkOperation(t, handler, token, `{"action":"remove_tag","tags":["legacy"],"selector":{"ids":["`+b.ID+`"]}}`)
+	awaitBulkOperation(t, handler, token, op.ID)
+	for _, id := range []string{a.ID, b.ID} {
+		tags, err := store.WorkflowTags(id)
+		require.NoError(t, err)
+		require.Equal(t, []string{"nightly", "team-a"}, tags)
+	}
+
+	// The system workflow fails per item for non-admins.
+	op = startBulkOperation(t, handler, token, `{"action":"stop","selector":{"ids":["`+systemID+`","`+a.ID+`"]},"options":{"continue_on_error":true}}`)
+	op = awaitBulkOperation(t, handler, token, op.ID)
+	require.Equal(t, BulkStatusFailed, op.Items[0].Status)
+	require.Equal(t, "admin role required", op.Items[0].Error)
+	require.Equal(t, BulkStatusSucceeded, op.Items[1].Status)
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"unknown action", `{"action":"explode","selector":{"ids":["` + a.ID + `"]}}`, "unknown action"},
+		{"no selector", `{"action":"stop"}`, "selector requires"},
+		{"ids and tags", `{"action":"stop","selector":{"ids":["` + a.ID + `"],"tags":["team-a"]}}`, "cannot be combined"},
+		{"unknown id", `{"action":"stop","selector":{"ids":["missing"]}}`, "not found"},
+		{"no match", `{"action":"stop","selector":{"tags":["nobody"]}}`, "matched no workflows"},
+		{"tag action without tags", `{"action":"add_tag","selector":{"ids":["` + a.ID + `"]}}`, "requires tags"},
+		{"concurrency", `{"action":"stop","selector":{"ids":["` + a.ID + `"]},"options":{"concurrency":100}}`, "concurrency"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := doRequest(handler, "POST", "/api/v1/workflows/bulk", tt.body, token)
+			require.Equal(t, http.StatusBadRequest, rr.Code)
+			require.Contains(t, rr.Body.String(), tt.want)
+		})
+	}
+}
+
+func TestRuntimeManager_ConcurrentLifecycle(t *testing.T) {
+	var mu sync.Mutex
+	active := 0
+	builder := func(*config.WorkflowConfig, *slog.Logger) (func(context.Context) error, error) {
+		mu.Lock()
+		active++
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		return func(context.Context) error {
+			mu.Lock()
+			active--
+			mu.Unlock()
+			return nil
+		}, nil
+	}
+	rm := NewRuntimeManager(nil, builder, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := range 30 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			switch i % 3 {
+			case 0:
+				_ = rm.LaunchFromWorkspace(ctx, "wf", "wf", "modules: []", "")
+			case 1:
+				_ = rm.StopWorkflow(ctx, "wf")
+			default:
+				_ = rm.RestartWorkflow(ctx, "wf", "wf", "modules: []", "")
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Launches and stops never interleave, so at most one engine is left
+	// running and the instance reports it.
+	inst, ok := rm.GetInstance("wf")
+	require.True(t, ok)
+	mu.Lock()
+	defer mu.Unlock()
+	if inst.Status == "running" {
+		require.Equal(t, 1, active)
+	} else {
+		require.Equal(t, 0, active)
+	}
+}
@@ -25,6 +25,8 @@ type FeatureFlagAdmin interface {
 	EvaluateFlag(key string, user string, group string) (any, error)
 	// SSEHandler returns an http.Handler that streams flag change events.
 	SSEHandler() http.Handler
+	// SubscribedEngines returns the remote engines following the stream.
+	SubscribedEngines() []any
 }
 
 // SetFeatureFlagService sets the optional feature flag service for admin API.
@@ -39,6 +41,7 @@ func (h *V1APIHandler) SetFeatureFlagService(svc FeatureFlagAdmin) {
 //	GET    /feature-flags              -> list all flags
 //	POST   /feature-flags              -> create flag
 //	GET    /feature-flags/stream       -> SSE stream
+//	GET    /feature-flags/engines      -> subscribed remote engines
 //	GET    /feature-flags/{key}        -> get flag
 //	PUT    /feature-flags/{key}        -> update flag
 //	DELETE /feature-flags/{key}        -> delete flag
@@ -75,6 +78,14 @@ func (h *V1APIHandler) handleFeatureFlags(w http.ResponseWriter, r *http.Request
 		}
 		h.featureFlagService.SSEHandler().ServeHTTP(w, r)
 
+	// /feature-flags/engines
+	case len(rest) == 1 && rest[0] == "engines":
+		if r.Method != http.MethodGet {
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+		h.listFeatureFlagEngines(w)
+
 	// /feature-flags/{key}
 	case len(rest) == 1:
 		key := rest[0]
@@ -124,6 +135,17 @@ func (h *V1APIHandler) listFeatureFlags(w http.ResponseWriter) {
 	writeJSON(w, http.StatusOK, flags)
 }
 
+func (h *V1APIHandler) listFeatureFlagEngines(w http.ResponseWriter) {
+	engines := h.featureFlagService.SubscribedEngines()
+	if engines == nil {
+		engines = []any{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"engines": engines,
+		"total":   len(engines),
+	})
+}
+
 func (h *V1APIHandler) createFeatureFlag(w http.ResponseWriter, r *http.Request) {
 	body, err := readBody(r)
 	if err != nil {
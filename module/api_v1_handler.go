@@ -1,7 +1,9 @@
 package module
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -9,6 +11,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/GoCodeAlone/workflow/audit"
 	"github.com/GoCodeAlone/workflow/bundle"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
@@ -20,11 +23,14 @@ import (
 type V1APIHandler struct {
 	store              *V1Store
 	jwtSecret          string
-	dataDir            string                        // base data directory for workspace extraction
-	reloadFn           func(configYAML string) error // callback to reload engine with new admin config
-	runtimeManager     *RuntimeManager               // optional runtime manager for deploy/stop
-	workspaceHandler   *WorkspaceHandler             // optional workspace file management handler
-	featureFlagService FeatureFlagAdmin              // optional feature flag admin service
+	dataDir            string             // base data directory for workspace extraction
+	reloadFn           systemReloadFunc   // callback to reload engine with new admin config
+	runtimeManager     *RuntimeManager    // optional runtime manager for deploy/stop
+	workspaceHandler   *WorkspaceHandler  // optional workspace file management handler
+	featureFlagService FeatureFlagAdmin   // optional feature flag admin service
+	webhooks           *WebhookDispatcher // optional dispatcher for webhook test events
+	auditStore         audit.Store        // optional store for bulk operation audit records
+	bulk               *bulkOperations    // bulk operations started through this handler
 }
 
 // NewV1APIHandler creates a new handler backed by the given store.
@@ -32,16 +38,24 @@ func NewV1APIHandler(store *V1Store, jwtSecret string) *V1APIHandler {
 	return &V1APIHandler{
 		store:     store,
 		jwtSecret: jwtSecret,
+		bulk:      newBulkOperations(),
 	}
 }
 
+// systemReloadFunc reloads the engine with a system workflow config deployed
+// by author.
+type systemReloadFunc func(ctx context.Context, author, configYAML string) error
+
 // SetWorkspaceHandler sets the optional workspace file management handler.
 func (h *V1APIHandler) SetWorkspaceHandler(wh *WorkspaceHandler) {
 	h.workspaceHandler = wh
 }
 
 // SetReloadFunc sets the callback invoked when deploying the system workflow.
-func (h *V1APIHandler) SetReloadFunc(fn func(configYAML string) error) {
+// author is the deploying user. The callback should pass the change through
+// the config approval gate (WorkflowUIHandler.SubmitConfigChange) and return
+// a *ConfigChangeStagedError when the change was staged instead of applied.
+func (h *V1APIHandler) SetReloadFunc(fn func(ctx context.Context, author, configYAML string) error) {
 	h.reloadFn = fn
 }
 
@@ -99,6 +113,14 @@ func (h *V1APIHandler) HandleV1(w http.ResponseWriter, r *http.Request) {
 	//   /api/v1/workflows/{id}/versions
 	//   /api/v1/workflows/{id}/deploy
 	//   /api/v1/workflows/{id}/stop
+	//   /api/v1/workflows/{id}/webhooks
+	//   /api/v1/projects/{id}/webhooks
+	//   /api/v1/webhooks/{id}
+	//   /api/v1/workflows/{id}/ai-budget
+	//   /api/v1/workflows/{id}/tags
+	//   /api/v1/workflows/bulk
+	//   /api/v1/operations/{id}
+	//   /api/v1/ai/usage
 	//   /api/v1/dashboard
 	segments := parsePathSegments(path)
 
@@ -121,6 +143,12 @@ func (h *V1APIHandler) HandleV1(w http.ResponseWriter, r *http.Request) {
 		h.handleDashboard(w, r)
 	case "feature-flags":
 		h.handleFeatureFlags(w, r, segments[1:])
+	case "webhooks":
+		h.handleWebhooks(w, r, segments[1:])
+	case "ai":
+		h.handleAI(w, r, segments[1:])
+	case "operations":
+		h.handleOperations(w, r, segments[1:])
 	default:
 		writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
 	}
@@ -141,7 +169,8 @@ func parsePathSegments(path string) []string {
 	resources := map[string]bool{
 		"companies": true, "organizations": true,
 		"projects": true, "workflows": true, "dashboard": true,
-		"feature-flags": true,
+		"feature-flags": true, "webhooks": true, "ai": true,
+		"operations": true,
 	}
 
 	startIdx := -1
@@ -256,7 +285,13 @@ func (h *V1APIHandler) handleOrganizations(w http.ResponseWriter, r *http.Reques
 //
 //	GET    /projects/{id}/workflows  -> list workflows by project
 //	POST   /projects/{id}/workflows  -> create workflow
+//	GET    /projects/{id}/webhooks   -> list webhook subscriptions (delegates)
+//	POST   /projects/{id}/webhooks   -> create webhook subscription (delegates)
 func (h *V1APIHandler) handleProjects(w http.ResponseWriter, r *http.Request, rest []string) {
+	if len(rest) >= 2 && rest[1] == "webhooks" {
+		h.handleScopedWebhooks(w, r, "", rest[0], rest[2:])
+		return
+	}
 	if len(rest) < 2 || rest[1] != "workflows" {
 		writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
 		return
@@ -291,6 +326,11 @@ func (h *V1APIHandler) handleProjects(w http.ResponseWriter, r *http.Request, re
 //	GET    /workflows/{id}/versions -> list versions
 //	POST   /workflows/{id}/deploy   -> deploy workflow
 //	POST   /workflows/{id}/stop     -> stop workflow
+//	GET    /workflows/{id}/webhooks -> list webhook subscriptions (delegates)
+//	POST   /workflows/{id}/webhooks -> create webhook subscription (delegates)
+//	GET    /workflows/{id}/ai-budget -> AI budget and usage this month (delegates)
+//	PUT    /workflows/{id}/ai-budget -> set AI budget (delegates)
+//	DELETE /workflows/{id}/ai-budget -> remove AI budget (delegates)
 func (h *V1APIHandler) handleWorkflows(w http.ResponseWriter, r *http.Request, rest []string) {
 	switch {
 	// /workflows (no ID)
@@ -309,6 +349,14 @@ func (h *V1APIHandler) handleWorkflows(w http.ResponseWriter, r *http.Request, r
 			writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
 		}
 
+	// /workflows/bulk (asynchronous bulk operation)
+	case len(rest) == 1 && rest[0] == "bulk":
+		if r.Method == http.MethodPost {
+			h.createBulkOperation(w, r)
+		} else {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+		}
+
 	// /workflows/import (bundle import)
 	case len(rest) == 1 && rest[0] == "import":
 		if r.Method == http.MethodPost {
@@ -331,6 +379,18 @@ func (h *V1APIHandler) handleWorkflows(w http.ResponseWriter, r *http.Request, r
 			writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
 		}
 
+	// /workflows/{id}/webhooks
+	case len(rest) >= 2 && rest[1] == "webhooks":
+		h.handleScopedWebhooks(w, r, rest[0], "", rest[2:])
+
+	// /workflows/{id}/tags
+	case len(rest) >= 2 && rest[1] == "tags":
+		h.handleWorkflowTags(w, r, rest[0], rest[2:])
+
+	// /workflows/{id}/ai-budget
+	case len(rest) == 2 && rest[1] == "ai-budget":
+		h.handleAIBudget(w, r, rest[0])
+
 	// /workflows/{id}/{action}
 	case len(rest) == 2:
 		workflowID := rest[0]
@@ -378,24 +438,32 @@ type userClaims struct {
 }
 
 func (h *V1APIHandler) extractClaims(r *http.Request) (*userClaims, error) {
-	// The auth middleware has already validated the token and put claims in context.
-	// Try context first.
+	claims, err := requestClaims(r, h.jwtSecret)
+	if err != nil {
+		return nil, err
+	}
+	uc := &userClaims{}
+	if sub, ok := claims["sub"].(string); ok {
+		uc.UserID = sub
+	}
+	if email, ok := claims["email"].(string); ok {
+		uc.Email = email
+	}
+	if role, ok := claims["role"].(string); ok {
+		uc.Role = role
+	}
+	return uc, nil
+}
+
+// requestClaims returns the claims of the caller of r. The auth middleware
+// has usually validated the token and put its claims in the context already;
+// otherwise the HS256 JWT is parsed from the Authorization header or, since
+// EventSource for SSE cannot set custom headers, the token query parameter.
+func requestClaims(r *http.Request, jwtSecret string) (map[string]any, error) {
 	if claims, ok := r.Context().Value(authClaimsContextKey).(map[string]any); ok {
-		uc := &userClaims{}
-		if sub, ok := claims["sub"].(string); ok {
-			uc.UserID = sub
-		}
-		if email, ok := claims["email"].(string); ok {
-			uc.Email = email
-		}
-		if role, ok := claims["role"].(string); ok {
-			uc.Role = role
-		}
-		return uc, nil
+		return claims, nil
 	}
 
-	// Fallback: parse JWT directly from Authorization header or token query param
-	// (EventSource for SSE cannot set custom headers, so falls back to query param)
 	authHeader := r.Header.Get("Authorization")
 	tokenStr := strings.TrimPrefix(authHeader, "Bearer ")
 	if authHeader == "" || tokenStr == authHeader {
@@ -415,7 +483,7 @@ func (h *V1APIHandler) extractClaims(r *http.Request) (*userClaims, error) {
 		if token.Method.Alg() != jwt.SigningMethodHS256.Alg() {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte(h.jwtSecret), nil
+		return []byte(jwtSecret), nil
 	})
 	if err != nil {
 		return nil, fmt.Errorf("invalid token: %w", err)
@@ -425,18 +493,7 @@ func (h *V1APIHandler) extractClaims(r *http.Request) (*userClaims, error) {
 	if !ok || !token.Valid {
 		return nil, fmt.Errorf("invalid claims")
 	}
-
-	uc := &userClaims{}
-	if sub, ok := claims["sub"].(string); ok {
-		uc.UserID = sub
-	}
-	if email, ok := claims["email"].(string); ok {
-		uc.Email = email
-	}
-	if role, ok := claims["role"].(string); ok {
-		uc.Role = role
-	}
-	return uc, nil
+	return claims, nil
 }
 
 func (h *V1APIHandler) requireAuth(w http.ResponseWriter, r *http.Request) *userClaims {
@@ -750,6 +807,22 @@ func (h *V1APIHandler) listAllWorkflows(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Filter by tag: ?tag=a&tag=b lists workflows carrying every tag
+	if tags := r.URL.Query()["tag"]; len(tags) > 0 {
+		tags, err = normalizeWorkflowTags(tags)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		filtered := make([]V1Workflow, 0, len(wfs))
+		for i := range wfs {
+			if wfs[i].hasAllTags(tags) {
+				filtered = append(filtered, wfs[i])
+			}
+		}
+		wfs = filtered
+	}
+
 	// Filter system workflows for non-admins
 	if claims.Role != "admin" {
 		filtered := make([]V1Workflow, 0, len(wfs))
@@ -882,30 +955,55 @@ func (h *V1APIHandler) deployWorkflow(w http.ResponseWriter, r *http.Request, id
 		return
 	}
 
+	updated, err := h.startWorkflow(r.Context(), wf, false, claims.actor())
+	var staged *ConfigChangeStagedError
+	if errors.As(err, &staged) {
+		writeJSON(w, http.StatusAccepted, map[string]any{
+			"status":    "pending_approval",
+			"id":        staged.Change.ID,
+			"author":    staged.Change.Author,
+			"expiresAt": staged.Change.ExpiresAt,
+		})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// startWorkflow deploys wf and marks it active: the system workflow reloads
+// the engine, other workflows are launched as runtime instances. With restart
+// a running instance is stopped and relaunched instead of being rejected.
+// A system workflow deploy that was staged for approval returns the
+// *ConfigChangeStagedError and leaves the workflow's status unchanged.
+func (h *V1APIHandler) startWorkflow(ctx context.Context, wf *V1Workflow, restart bool, author string) (*V1Workflow, error) {
 	// For system workflows, trigger engine reload
 	if wf.IsSystem && h.reloadFn != nil {
-		if err := h.reloadFn(wf.ConfigYAML); err != nil {
-			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": fmt.Sprintf("deploy failed: %v", err)})
-			return
+		if err := h.reloadFn(ctx, author, wf.ConfigYAML); err != nil {
+			var staged *ConfigChangeStagedError
+			if errors.As(err, &staged) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("deploy failed: %w", err)
 		}
 	}
 
 	// For non-system workflows, start as a runtime instance
 	if !wf.IsSystem && h.runtimeManager != nil && wf.ConfigYAML != "" {
-		if launchErr := h.runtimeManager.LaunchFromWorkspace(r.Context(), id, wf.Name, wf.ConfigYAML, wf.WorkspaceDir); launchErr != nil {
-			_, _ = h.store.SetWorkflowStatus(id, "error")
-			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": fmt.Sprintf("launch failed: %v", launchErr)})
-			return
+		launch := h.runtimeManager.LaunchFromWorkspace
+		if restart {
+			launch = h.runtimeManager.RestartWorkflow
+		}
+		if launchErr := launch(ctx, wf.ID, wf.Name, wf.ConfigYAML, wf.WorkspaceDir); launchErr != nil {
+			_, _ = h.store.SetWorkflowStatus(wf.ID, "error")
+			return nil, fmt.Errorf("launch failed: %w", launchErr)
 		}
 	}
 
-	updated, err := h.store.SetWorkflowStatus(id, "active")
-	if err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
-		return
-	}
-
-	writeJSON(w, http.StatusOK, updated)
+	return h.store.SetWorkflowStatus(wf.ID, "active")
 }
 
 func (h *V1APIHandler) stopWorkflow(w http.ResponseWriter, r *http.Request, id string) {
@@ -930,15 +1028,7 @@ func (h *V1APIHandler) stopWorkflow(w http.ResponseWriter, r *http.Request, id s
 		return
 	}
 
-	// Stop the runtime instance if running
-	if h.runtimeManager != nil {
-		if inst, ok := h.runtimeManager.GetInstance(id); ok && inst.Status == "running" {
-			if stopErr := h.runtimeManager.StopWorkflow(r.Context(), id); stopErr != nil {
-				// Log but don't fail — the DB status update should still proceed
-				log.Printf("workflow engine: failed to stop workflow %s: %v", id, stopErr)
-			}
-		}
-	}
+	h.stopRuntimeInstance(r.Context(), id)
 
 	updated, err := h.store.SetWorkflowStatus(id, "stopped")
 	if err != nil {
@@ -948,6 +1038,20 @@ func (h *V1APIHandler) stopWorkflow(w http.ResponseWriter, r *http.Request, id s
 	writeJSON(w, http.StatusOK, updated)
 }
 
+// stopRuntimeInstance stops a workflow's runtime instance if it is starting
+// or running. Failures are logged: the workflow's status is updated anyway.
+func (h *V1APIHandler) stopRuntimeInstance(ctx context.Context, id string) {
+	if h.runtimeManager == nil {
+		return
+	}
+	if inst, ok := h.runtimeManager.GetInstance(id); ok && (inst.Status == "running" || inst.Status == "starting") {
+		if stopErr := h.runtimeManager.StopWorkflow(ctx, id); stopErr != nil {
+			// Log but don't fail — the DB status update should still proceed
+			log.Printf("workflow engine: failed to stop workflow %s: %v", id, stopErr)
+		}
+	}
+}
+
 // loadWorkflowFromPath reads a workflow config from a server-local file path
 // and creates a workflow record in the store.
 func (h *V1APIHandler) loadWorkflowFromPath(w http.ResponseWriter, r *http.Request) {
@@ -204,6 +204,118 @@ func (s *V1Store) initSchema() error {
 		updated_at  TEXT NOT NULL,
 		FOREIGN KEY (workflow_id) REFERENCES workflows(id) ON DELETE CASCADE
 	);
+
+	CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+		id          TEXT PRIMARY KEY,
+		workflow_id TEXT,
+		project_id  TEXT,
+		name        TEXT NOT NULL,
+		url         TEXT NOT NULL,
+		secret      TEXT NOT NULL,
+		filter      TEXT NOT NULL DEFAULT '{}',
+		delivery    TEXT NOT NULL DEFAULT '{}',
+		enabled     INTEGER NOT NULL DEFAULT 1,
+		created_by  TEXT NOT NULL DEFAULT '',
+		created_at  TEXT NOT NULL,
+		updated_at  TEXT NOT NULL,
+		FOREIGN KEY (workflow_id) REFERENCES workflows(id) ON DELETE CASCADE,
+		FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		id               TEXT PRIMARY KEY,
+		subscription_id  TEXT NOT NULL,
+		event_id         TEXT NOT NULL,
+		event_type       TEXT NOT NULL,
+		payload          TEXT NOT NULL,
+		status           TEXT NOT NULL DEFAULT 'pending',
+		attempts         INTEGER NOT NULL DEFAULT 0,
+		last_error       TEXT NOT NULL DEFAULT '',
+		last_status_code INTEGER NOT NULL DEFAULT 0,
+		next_attempt_at  TEXT NOT NULL DEFAULT '',
+		created_at       TEXT NOT NULL,
+		updated_at       TEXT NOT NULL,
+		delivered_at     TEXT NOT NULL DEFAULT '',
+		FOREIGN KEY (subscription_id) REFERENCES webhook_subscriptions(id) ON DELETE CASCADE
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription ON webhook_deliveries(subscription_id, created_at);
+	CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_status ON webhook_deliveries(status);
+
+	CREATE TABLE IF NOT EXISTS pending_config_changes (
+		id          TEXT PRIMARY KEY,
+		environment TEXT NOT NULL DEFAULT '',
+		author      TEXT NOT NULL,
+		status      TEXT NOT NULL,
+		created_at  TEXT NOT NULL,
+		expires_at  TEXT NOT NULL,
+		approved_by TEXT NOT NULL DEFAULT '',
+		decided_by  TEXT NOT NULL DEFAULT '',
+		decided_at  TEXT NOT NULL DEFAULT '',
+		error       TEXT NOT NULL DEFAULT '',
+		config      TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS ai_usage (
+		id            TEXT PRIMARY KEY,
+		execution_id  TEXT NOT NULL,
+		workflow_id   TEXT NOT NULL,
+		pipeline      TEXT NOT NULL DEFAULT '',
+		step_name     TEXT NOT NULL,
+		provider      TEXT NOT NULL DEFAULT '',
+		model         TEXT NOT NULL DEFAULT '',
+		input_tokens  INTEGER NOT NULL DEFAULT 0,
+		output_tokens INTEGER NOT NULL DEFAULT 0,
+		cost          REAL NOT NULL DEFAULT 0,
+		created_at    TEXT NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_ai_usage_workflow ON ai_usage(workflow_id, created_at);
+	CREATE INDEX IF NOT EXISTS idx_ai_usage_execution ON ai_usage(execution_id);
+
+	CREATE TABLE IF NOT EXISTS ai_usage_executions (
+		execution_id  TEXT PRIMARY KEY,
+		workflow_id   TEXT NOT NULL,
+		pipeline      TEXT NOT NULL DEFAULT '',
+		calls         INTEGER NOT NULL DEFAULT 0,
+		input_tokens  INTEGER NOT NULL DEFAULT 0,
+		output_tokens INTEGER NOT NULL DEFAULT 0,
+		cost          REAL NOT NULL DEFAULT 0,
+		updated_at    TEXT NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_ai_usage_executions_workflow ON ai_usage_executions(workflow_id, updated_at);
+
+	CREATE TABLE IF NOT EXISTS ai_usage_daily (
+		workflow_id   TEXT NOT NULL,
+		pipeline      TEXT NOT NULL DEFAULT '',
+		day           TEXT NOT NULL,
+		calls         INTEGER NOT NULL DEFAULT 0,
+		input_tokens  INTEGER NOT NULL DEFAULT 0,
+		output_tokens INTEGER NOT NULL DEFAULT 0,
+		cost          REAL NOT NULL DEFAULT 0,
+		PRIMARY KEY (workflow_id, pipeline, day)
+	);
+
+	CREATE TABLE IF NOT EXISTS ai_budgets (
+		workflow_id    TEXT PRIMARY KEY,
+		monthly_tokens INTEGER NOT NULL DEFAULT 0,
+		monthly_cost   REAL NOT NULL DEFAULT 0,
+		action         TEXT NOT NULL DEFAULT 'notify',
+		alerted_period TEXT NOT NULL DEFAULT '',
+		updated_at     TEXT NOT NULL,
+		FOREIGN KEY (workflow_id) REFERENCES workflows(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS workflow_tags (
+		workflow_id TEXT NOT NULL,
+		tag         TEXT NOT NULL,
+		created_at  TEXT NOT NULL,
+		PRIMARY KEY (workflow_id, tag),
+		FOREIGN KEY (workflow_id) REFERENCES workflows(id) ON DELETE CASCADE
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_workflow_tags_tag ON workflow_tags(tag);
 	`
 	_, err := s.db.Exec(schema)
 	if err != nil {
@@ -257,20 +369,21 @@ type V1Project struct {
 
 // V1Workflow represents a workflow record.
 type V1Workflow struct {
-	ID           string `json:"id"`
-	ProjectID    string `json:"project_id"`
-	Name         string `json:"name"`
-	Slug         string `json:"slug"`
-	Description  string `json:"description,omitempty"`
-	ConfigYAML   string `json:"config_yaml"`
-	Version      int    `json:"version"`
-	Status       string `json:"status"`
-	IsSystem     bool   `json:"is_system,omitempty"`
-	WorkspaceDir string `json:"workspace_dir,omitempty"`
-	CreatedBy    string `json:"created_by"`
-	UpdatedBy    string `json:"updated_by"`
-	CreatedAt    string `json:"created_at"`
-	UpdatedAt    string `json:"updated_at"`
+	ID           string   `json:"id"`
+	ProjectID    string   `json:"project_id"`
+	Name         string   `json:"name"`
+	Slug         string   `json:"slug"`
+	Description  string   `json:"description,omitempty"`
+	ConfigYAML   string   `json:"config_yaml"`
+	Version      int      `json:"version"`
+	Status       string   `json:"status"`
+	IsSystem     bool     `json:"is_system,omitempty"`
+	WorkspaceDir string   `json:"workspace_dir,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	CreatedBy    string   `json:"created_by"`
+	UpdatedBy    string   `json:"updated_by"`
+	CreatedAt    string   `json:"created_at"`
+	UpdatedAt    string   `json:"updated_at"`
 }
 
 // V1WorkflowVersion represents a snapshot of a workflow at a specific version.
@@ -548,6 +661,9 @@ func (s *V1Store) GetWorkflow(id string) (*V1Workflow, error) {
 		return nil, err
 	}
 	w.IsSystem = isSys == 1
+	if w.Tags, err = s.WorkflowTags(id); err != nil {
+		return nil, err
+	}
 	return w, nil
 }
 
@@ -644,7 +760,14 @@ func (s *V1Store) ListWorkflows(projectID string) ([]V1Workflow, error) {
 		w.IsSystem = isSys == 1
 		result = append(result, w)
 	}
-	return result, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+	if err := s.attachWorkflowTags(result); err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
 // SetWorkflowStatus updates a workflow's status field.
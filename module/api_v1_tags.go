@@ -0,0 +1,76 @@
+package module
+
+import (
+	"net/http"
+)
+
+// handleWorkflowTags dispatches tag requests nested under a workflow.
+//
+// Handles:
+//
+//	GET    /workflows/{id}/tags        -> list the workflow's tags
+//	PUT    /workflows/{id}/tags        -> replace the tags ({"tags": [...]})
+//	POST   /workflows/{id}/tags        -> add tags ({"tags": [...]})
+//	DELETE /workflows/{id}/tags/{tag}  -> remove one tag
+//
+// Every response is the workflow's resulting tag list.
+func (h *V1APIHandler) handleWorkflowTags(w http.ResponseWriter, r *http.Request, workflowID string, rest []string) {
+	if len(rest) > 1 {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+		return
+	}
+
+	claims := h.requireAuth(w, r)
+	if claims == nil {
+		return
+	}
+
+	wf, err := h.store.GetWorkflow(workflowID)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "workflow not found"})
+		return
+	}
+	if wf.IsSystem && claims.Role != "admin" {
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": "admin role required"})
+		return
+	}
+
+	var tags []string
+	switch {
+	case len(rest) == 1 && r.Method == http.MethodDelete:
+		tags, err = h.store.RemoveWorkflowTags(wf.ID, rest[0])
+	case len(rest) == 1:
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	case r.Method == http.MethodGet:
+		tags = wf.Tags
+	case r.Method == http.MethodPut || r.Method == http.MethodPost:
+		var req struct {
+			Tags []string `json:"tags"`
+		}
+		if err := decodeBody(r, &req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+			return
+		}
+		if _, err := normalizeWorkflowTags(req.Tags); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		if r.Method == http.MethodPut {
+			tags, err = h.store.SetWorkflowTags(wf.ID, req.Tags)
+		} else {
+			tags, err = h.store.AddWorkflowTags(wf.ID, req.Tags...)
+		}
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	if tags == nil {
+		tags = []string{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"workflow_id": wf.ID, "tags": tags})
+}
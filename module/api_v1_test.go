@@ -688,7 +688,7 @@ func TestV1Handler_WorkflowDeploy(t *testing.T) {
 
 	// Track reload calls
 	reloadCalled := false
-	handler.SetReloadFunc(func(configYAML string) error {
+	handler.SetReloadFunc(func(_ context.Context, _, configYAML string) error {
 		reloadCalled = true
 		return nil
 	})
@@ -0,0 +1,267 @@
+package module
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// SetWebhookDispatcher sets the optional dispatcher used to send test events
+// to webhook subscriptions. Subscription management works without it.
+func (h *V1APIHandler) SetWebhookDispatcher(d *WebhookDispatcher) {
+	h.webhooks = d
+}
+
+// webhookSubscriptionRequest is the body of webhook subscription create and
+// update requests. Fields omitted from an update are left unchanged.
+type webhookSubscriptionRequest struct {
+	Name     string                   `json:"name"`
+	URL      string                   `json:"url"`
+	Secret   string                   `json:"secret"`
+	Filter   *WebhookFilter           `json:"filter"`
+	Delivery *WebhookDeliverySettings `json:"delivery"`
+	Enabled  *bool                    `json:"enabled"`
+}
+
+// apply copies the fields set in the request onto sub.
+func (req *webhookSubscriptionRequest) apply(sub *WebhookSubscription) {
+	if req.Name != "" {
+		sub.Name = req.Name
+	}
+	if req.URL != "" {
+		sub.URL = req.URL
+	}
+	if req.Secret != "" {
+		sub.Secret = req.Secret
+	}
+	if req.Filter != nil {
+		sub.Filter = *req.Filter
+	}
+	if req.Delivery != nil {
+		sub.Delivery = *req.Delivery
+	}
+	if req.Enabled != nil {
+		sub.Enabled = *req.Enabled
+	}
+}
+
+// handleScopedWebhooks dispatches webhook subscription requests nested under
+// a workflow or a project (exactly one of workflowID and projectID is set).
+//
+// Handles:
+//
+//	GET    /workflows/{id}/webhooks  -> list the workflow's subscriptions
+//	POST   /workflows/{id}/webhooks  -> subscribe to the workflow's events
+//	GET    /projects/{id}/webhooks   -> list the project's subscriptions
+//	POST   /projects/{id}/webhooks   -> subscribe to events of every workflow in the project
+func (h *V1APIHandler) handleScopedWebhooks(w http.ResponseWriter, r *http.Request, workflowID, projectID string, rest []string) {
+	if len(rest) != 0 {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+		return
+	}
+
+	claims := h.requireAuth(w, r)
+	if claims == nil {
+		return
+	}
+	if !h.authorizeScope(w, claims, workflowID, projectID) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		subs, err := h.store.ListWebhookSubscriptions(workflowID, projectID)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		if subs == nil {
+			subs = []WebhookSubscription{}
+		}
+		writeJSON(w, http.StatusOK, subs)
+	case http.MethodPost:
+		h.createWebhookSubscription(w, r, claims, workflowID, projectID)
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+	}
+}
+
+// handleWebhooks dispatches requests for individual webhook subscriptions.
+//
+// Handles:
+//
+//	GET    /webhooks/{id}             -> get subscription (the secret is never returned)
+//	PUT    /webhooks/{id}             -> update subscription
+//	DELETE /webhooks/{id}             -> delete subscription and its delivery log
+//	GET    /webhooks/{id}/deliveries  -> delivery log (?status=, ?limit=)
+//	POST   /webhooks/{id}/test        -> send a webhook.test event
+func (h *V1APIHandler) handleWebhooks(w http.ResponseWriter, r *http.Request, rest []string) {
+	if len(rest) == 0 || len(rest) > 2 {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+		return
+	}
+
+	claims := h.requireAuth(w, r)
+	if claims == nil {
+		return
+	}
+
+	sub, err := h.store.GetWebhookSubscription(rest[0])
+	if err != nil {
+		if isNotFound(err) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "webhook subscription not found"})
+		} else {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+		return
+	}
+	if !h.authorizeScope(w, claims, sub.WorkflowID, sub.ProjectID) {
+		return
+	}
+
+	switch {
+	// /webhooks/{id}
+	case len(rest) == 1:
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, sub)
+		case http.MethodPut:
+			h.updateWebhookSubscription(w, r, sub)
+		case http.MethodDelete:
+			if err := h.store.DeleteWebhookSubscription(sub.ID); err != nil {
+				writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+		default:
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		}
+
+	// /webhooks/{id}/deliveries
+	case rest[1] == "deliveries":
+		if r.Method != http.MethodGet {
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+		h.listWebhookDeliveries(w, r, sub.ID)
+
+	// /webhooks/{id}/test
+	case rest[1] == "test":
+		if r.Method != http.MethodPost {
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+		if h.webhooks == nil {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "webhook dispatcher not available"})
+			return
+		}
+		delivery, err := h.webhooks.SendTest(r.Context(), sub.ID)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusAccepted, delivery)
+
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+	}
+}
+
+// validateWebhookSubscription validates sub, permitting private targets only
+// when the webhook dispatcher allows them.
+func (h *V1APIHandler) validateWebhookSubscription(sub *WebhookSubscription) error {
+	if h.webhooks != nil {
+		return h.webhooks.validateSubscription(sub)
+	}
+	return sub.Validate()
+}
+
+func (h *V1APIHandler) createWebhookSubscription(w http.ResponseWriter, r *http.Request, claims *userClaims, workflowID, projectID string) {
+	var req webhookSubscriptionRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	sub := &WebhookSubscription{
+		WorkflowID: workflowID,
+		ProjectID:  projectID,
+		Enabled:    true,
+		CreatedBy:  claims.UserID,
+	}
+	req.apply(sub)
+	if err := h.validateWebhookSubscription(sub); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if err := h.store.CreateWebhookSubscription(sub); err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusCreated, sub)
+}
+
+func (h *V1APIHandler) updateWebhookSubscription(w http.ResponseWriter, r *http.Request, sub *WebhookSubscription) {
+	var req webhookSubscriptionRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	req.apply(sub)
+	if err := h.validateWebhookSubscription(sub); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if err := h.store.UpdateWebhookSubscription(sub); err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, sub)
+}
+
+func (h *V1APIHandler) listWebhookDeliveries(w http.ResponseWriter, r *http.Request, subscriptionID string) {
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "limit must be a positive integer"})
+			return
+		}
+		limit = n
+	}
+	deliveries, err := h.store.ListWebhookDeliveries(subscriptionID, r.URL.Query().Get("status"), limit)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	if deliveries == nil {
+		deliveries = []WebhookSubscriptionDelivery{}
+	}
+	writeJSON(w, http.StatusOK, deliveries)
+}
+
+// authorizeScope checks that a workflow or project (exactly one of workflowID
+// and projectID is set) exists and that the caller may manage its webhooks and
+// AI budget: like the workflows themselves, system workflows and projects
+// require the admin role. It writes the error response on failure.
+func (h *V1APIHandler) authorizeScope(w http.ResponseWriter, claims *userClaims, workflowID, projectID string) bool {
+	var isSystem bool
+	if workflowID != "" {
+		wf, err := h.store.GetWorkflow(workflowID)
+		if err != nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "workflow not found"})
+			return false
+		}
+		isSystem = wf.IsSystem
+	} else {
+		p, err := h.store.GetProject(projectID)
+		if err != nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "project not found"})
+			return false
+		}
+		isSystem = p.IsSystem
+	}
+	if isSystem && claims.Role != "admin" {
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": "admin role required"})
+		return false
+	}
+	return true
+}
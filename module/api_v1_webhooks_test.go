@@ -0,0 +1,160 @@
+package module
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func setupWebhookHandler(t *testing.T) (*V1APIHandler, *V1Store, string, *V1Workflow) {
+	t.Helper()
+	handler, store, secret := setupTestHandler(t)
+	company := mustCreateCompany(t, store, "Acme", "", "user-1")
+	org := mustCreateOrganization(t, store, company.ID, "Acme Org", "", "user-1")
+	project := mustCreateProject(t, store, org.ID, "Orders", "", "")
+	wf, err := store.CreateWorkflow(project.ID, "Order Flow", "", "", "", "user-1")
+	require.NoError(t, err)
+	return handler, store, generateTestToken(secret, "user-1", "user@test.com", "user"), wf
+}
+
+func TestV1Handler_WebhookSubscriptionLifecycle(t *testing.T) {
+	handler, store, token, wf := setupWebhookHandler(t)
+	_, srv := newWebhookReceiver(t)
+	handler.SetWebhookDispatcher(startTestDispatcher(t, store))
+
+	body := `{"name":"partner","url":"` + srv.URL + `","secret":"` + testWebhookSecret + `",
+		"filter":{"event_types":["state.transition"],"states":["shipped"]},
+		"delivery":{"max_attempts":4,"initial_backoff":"30s"}}`
+	rr := doRequest(handler, "POST", "/api/v1/workflows/"+wf.ID+"/webhooks", body, token)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	require.NotContains(t, rr.Body.String(), testWebhookSecret, "secrets are write-only")
+
+	var sub WebhookSubscription
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &sub))
+	require.Equal(t, wf.ID, sub.WorkflowID)
+	require.True(t, sub.Enabled)
+	require.Equal(t, []string{"shipped"}, sub.Filter.States)
+	require.Equal(t, 4, sub.Delivery.MaxAttempts)
+
+	rr = doRequest(handler, "GET", "/api/v1/workflows/"+wf.ID+"/webhooks", "", token)
+	require.Equal(t, http.StatusOK, rr.Code)
+	var subs []WebhookSubscription
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &subs))
+	require.Len(t, subs, 1)
+
+	// Updates change only the fields sent; a new secret replaces the old one.
+	rr = doRequest(handler, "PUT", "/api/v1/webhooks/"+sub.ID, `{"enabled":false,"secret":"rotated-secret-0123456789"}`, token)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	require.NotContains(t, rr.Body.String(), "rotated-secret")
+	stored, err := store.GetWebhookSubscription(sub.ID)
+	require.NoError(t, err)
+	require.False(t, stored.Enabled)
+	require.Equal(t, "rotated-secret-0123456789", stored.Secret)
+	require.Equal(t, srv.URL, stored.URL)
+
+	rr = doRequest(handler, "POST", "/api/v1/webhooks/"+sub.ID+"/test", "", token)
+	require.Equal(t, http.StatusAccepted, rr.Code, rr.Body.String())
+	var delivery WebhookSubscriptionDelivery
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &delivery))
+	require.Equal(t, WebhookDeliveryPending, delivery.Status)
+	waitForWebhookDelivery(t, store, sub.ID, WebhookDeliveryDelivered)
+
+	rr = doRequest(handler, "GET", "/api/v1/webhooks/"+sub.ID+"/deliveries?status=delivered", "", token)
+	require.Equal(t, http.StatusOK, rr.Code)
+	var deliveries []WebhookSubscriptionDelivery
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &deliveries))
+	require.Len(t, deliveries, 1)
+	require.Equal(t, WebhookEventTest, deliveries[0].EventType)
+
+	rr = doRequest(handler, "DELETE", "/api/v1/webhooks/"+sub.ID, "", token)
+	require.Equal(t, http.StatusOK, rr.Code)
+	rr = doRequest(handler, "GET", "/api/v1/webhooks/"+sub.ID, "", token)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestV1Handler_ProjectWebhookSubscriptions(t *testing.T) {
+	handler, _, token, wf := setupWebhookHandler(t)
+
+	body := `{"name":"all-orders","url":"https://partner.example.com/hooks","secret":"` + testWebhookSecret + `"}`
+	rr := doRequest(handler, "POST", "/api/v1/projects/"+wf.ProjectID+"/webhooks", body, token)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+	rr = doRequest(handler, "GET", "/api/v1/projects/"+wf.ProjectID+"/webhooks", "", token)
+	require.Equal(t, http.StatusOK, rr.Code)
+	var subs []WebhookSubscription
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &subs))
+	require.Len(t, subs, 1)
+	require.Equal(t, wf.ProjectID, subs[0].ProjectID)
+
+	// Project workflows are still listed as before.
+	rr = doRequest(handler, "GET", "/api/v1/projects/"+wf.ProjectID+"/workflows", "", token)
+	require.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestV1Handler_WebhookSubscriptionValidation(t *testing.T) {
+	handler, _, token, wf := setupWebhookHandler(t)
+	path := "/api/v1/workflows/" + wf.ID + "/webhooks"
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"short secret", `{"name":"p","url":"https://x.example.com","secret":"short"}`, "secret"},
+		{"bad url", `{"name":"p","url":"ftp://x.example.com","secret":"` + testWebhookSecret + `"}`, "url"},
+		{"unknown event", `{"name":"p","url":"https://x.example.com","secret":"` + testWebhookSecret + `","filter":{"event_types":["step.started"]}}`, "event type"},
+		{"bad backoff", `{"name":"p","url":"https://x.example.com","secret":"` + testWebhookSecret + `","delivery":{"max_backoff":"never"}}`, "max_backoff"},
+		{"loopback url", `{"name":"p","url":"http://127.0.0.1:8080/hook","secret":"` + testWebhookSecret + `"}`, "private"},
+		{"metadata url", `{"name":"p","url":"http://169.254.169.254/latest","secret":"` + testWebhookSecret + `"}`, "private"},
+		{"localhost url", `{"name":"p","url":"http://localhost/hook","secret":"` + testWebhookSecret + `"}`, "not allowed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := doRequest(handler, "POST", path, tt.body, token)
+			require.Equal(t, http.StatusBadRequest, rr.Code)
+			require.True(t, strings.Contains(rr.Body.String(), tt.want), rr.Body.String())
+		})
+	}
+
+	// Updates are validated too.
+	rr := doRequest(handler, "POST", path, `{"name":"p","url":"https://x.example.com","secret":"`+testWebhookSecret+`"}`, token)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	var sub WebhookSubscription
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &sub))
+	rr = doRequest(handler, "PUT", "/api/v1/webhooks/"+sub.ID, `{"url":"http://10.0.0.5/hook"}`, token)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), "private")
+
+	rr = doRequest(handler, "POST", "/api/v1/workflows/missing/webhooks", `{}`, token)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestV1Handler_WebhookSubscriptionAccessControl(t *testing.T) {
+	handler, store, secret := setupTestHandler(t)
+	_, _, projectID, workflowID, err := store.EnsureSystemHierarchy("system", "")
+	require.NoError(t, err)
+	user := generateTestToken(secret, "user-1", "user@test.com", "user")
+	admin := generateTestToken(secret, "admin-1", "admin@test.com", "admin")
+	body := `{"name":"p","url":"https://x.example.com","secret":"` + testWebhookSecret + `"}`
+
+	rr := doRequest(handler, "POST", "/api/v1/workflows/"+workflowID+"/webhooks", body, user)
+	require.Equal(t, http.StatusForbidden, rr.Code)
+	rr = doRequest(handler, "GET", "/api/v1/projects/"+projectID+"/webhooks", "", user)
+	require.Equal(t, http.StatusForbidden, rr.Code)
+	rr = doRequest(handler, "GET", "/api/v1/workflows/"+workflowID+"/webhooks", "", "")
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	rr = doRequest(handler, "POST", "/api/v1/workflows/"+workflowID+"/webhooks", body, admin)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	var sub WebhookSubscription
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &sub))
+	rr = doRequest(handler, "GET", "/api/v1/webhooks/"+sub.ID, "", user)
+	require.Equal(t, http.StatusForbidden, rr.Code)
+
+	// Without a dispatcher, test events are unavailable.
+	rr = doRequest(handler, "POST", "/api/v1/webhooks/"+sub.ID+"/test", "", admin)
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+}
@@ -1,13 +1,20 @@
 package module
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/GoCodeAlone/workflow/audit"
 	"github.com/GoCodeAlone/workflow/config"
+	"github.com/GoCodeAlone/workflow/interfaces"
+	"github.com/GoCodeAlone/workflow/sandbox"
+	"github.com/GoCodeAlone/workflow/version"
 	"gopkg.in/yaml.v3"
 )
 
@@ -36,9 +43,25 @@ type WorkflowUIHandler struct {
 	mu            sync.RWMutex
 	config        *config.WorkflowConfig
 	reloadFn      func(*config.WorkflowConfig) error
+	reloadPlanFn  func(*config.WorkflowConfig) (*config.ReloadPlan, error)
 	tryActivateFn func(*config.WorkflowConfig) (*TryActivateResult, error)
 	engineStatus  func() map[string]any
 	svcRegistry   func() map[string]any
+	versionFn     func() version.Report
+
+	// Single-step previews (see api_step_preview.go).
+	stepRegistry  func() interfaces.StepRegistrar
+	previewPolicy sandbox.PreviewPolicy
+
+	// Two-person approval for config changes (see config_approval.go).
+	approvalPolicy   func(context.Context) ConfigApprovalPolicy
+	approvalNotifier ConfigApprovalNotifier
+	auditStore       audit.Store
+	pending          *pendingConfigStore
+	now              func() time.Time
+
+	// Configs the engine was loaded with, for rollback (see config_history.go).
+	history ConfigHistoryStore
 }
 
 // NewWorkflowUIHandler creates a new handler with an optional initial config.
@@ -46,7 +69,12 @@ func NewWorkflowUIHandler(cfg *config.WorkflowConfig) *WorkflowUIHandler {
 	if cfg == nil {
 		cfg = config.NewEmptyWorkflowConfig()
 	}
-	return &WorkflowUIHandler{config: cfg}
+	return &WorkflowUIHandler{
+		config:        cfg,
+		pending:       newPendingConfigStore(),
+		previewPolicy: sandbox.DefaultPreviewPolicy(),
+		history:       NewMemoryConfigHistory(),
+	}
 }
 
 // SetReloadFunc sets the callback for reloading the engine with new config.
@@ -54,6 +82,14 @@ func (h *WorkflowUIHandler) SetReloadFunc(fn func(*config.WorkflowConfig) error)
 	h.reloadFn = fn
 }
 
+// SetReloadPlanFunc sets the callback for dry-run reloads. It must report
+// what the reload callback would do with the given config, typically via
+// config.PlanReload against the running config, without applying it. When
+// unset, dry runs are planned against the latest recorded config version.
+func (h *WorkflowUIHandler) SetReloadPlanFunc(fn func(*config.WorkflowConfig) (*config.ReloadPlan, error)) {
+	h.reloadPlanFn = fn
+}
+
 // SetTryActivateFunc sets the callback for try-activate (build without deploy).
 // The callback should build a candidate engine from the given config and return
 // a TryActivateResult describing what the candidate would expose. It must not
@@ -72,16 +108,41 @@ func (h *WorkflowUIHandler) SetServiceRegistry(fn func() map[string]any) {
 	h.svcRegistry = fn
 }
 
+// SetVersionFunc sets the callback that reports the engine build and loaded
+// plugin inventory. Without it the version endpoint reports the build only.
+func (h *WorkflowUIHandler) SetVersionFunc(fn func() version.Report) {
+	h.versionFn = fn
+}
+
 // RegisterRoutes registers all workflow UI routes on the given mux.
 func (h *WorkflowUIHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /api/workflow/config", h.handleGetConfig)
 	mux.HandleFunc("PUT /api/workflow/config", h.handlePutConfig)
+	mux.HandleFunc("GET /api/workflow/config/pending", h.handleListPendingConfigs)
+	mux.HandleFunc("GET /api/workflow/config/pending/{id}", func(w http.ResponseWriter, r *http.Request) {
+		h.handleGetPendingConfig(w, r, r.PathValue("id"))
+	})
+	mux.HandleFunc("DELETE /api/workflow/config/pending/{id}", func(w http.ResponseWriter, r *http.Request) {
+		h.handleRejectPendingConfig(w, r, r.PathValue("id"))
+	})
+	mux.HandleFunc("POST /api/workflow/config/pending/{id}/approve", func(w http.ResponseWriter, r *http.Request) {
+		h.handleApprovePendingConfig(w, r, r.PathValue("id"))
+	})
+	mux.HandleFunc("GET /api/workflow/config/history", h.handleConfigHistory)
+	mux.HandleFunc("GET /api/workflow/config/history/{version}", func(w http.ResponseWriter, r *http.Request) {
+		h.handleGetConfigVersion(w, r, r.PathValue("version"))
+	})
+	mux.HandleFunc("POST /api/workflow/config/rollback/{version}", func(w http.ResponseWriter, r *http.Request) {
+		h.handleConfigRollback(w, r, r.PathValue("version"))
+	})
 	mux.HandleFunc("GET /api/workflow/modules", h.handleGetModules)
 	mux.HandleFunc("GET /api/workflow/services", h.handleGetServices)
 	mux.HandleFunc("POST /api/workflow/validate", h.handleValidate)
 	mux.HandleFunc("POST /api/workflow/reload", h.handleReload)
 	mux.HandleFunc("POST /api/workflow/try-activate", h.handleTryActivate)
 	mux.HandleFunc("GET /api/workflow/status", h.handleStatus)
+	mux.HandleFunc("GET /api/workflow/version", h.handleVersion)
+	mux.HandleFunc("POST /api/workflow/steps/preview", h.handleStepPreview)
 }
 
 func (h *WorkflowUIHandler) handleGetConfig(w http.ResponseWriter, _ *http.Request) {
@@ -112,6 +173,11 @@ func (h *WorkflowUIHandler) handlePutConfig(w http.ResponseWriter, r *http.Reque
 		}
 	}
 
+	if policy := h.resolveApprovalPolicy(r.Context()); policy.RequireApproval {
+		h.stageConfigChange(w, r, &cfg, policy)
+		return
+	}
+
 	h.mu.Lock()
 	h.config = &cfg
 	h.mu.Unlock()
@@ -221,6 +287,9 @@ var availableModules = []moduleTypeDef{
 // It handles both query (GET) and command (PUT/POST) operations for engine
 // management, dispatching based on the last path segment.
 func (h *WorkflowUIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.servePendingConfig(w, r) || h.serveConfigHistory(w, r) {
+		return
+	}
 	seg := lastPathSegment(r.URL.Path)
 
 	switch r.Method {
@@ -230,6 +299,8 @@ func (h *WorkflowUIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			h.handleGetConfig(w, r)
 		case "status":
 			h.handleStatus(w, r)
+		case "version":
+			h.handleVersion(w, r)
 		case "modules":
 			h.handleGetModules(w, r)
 		case "services":
@@ -256,6 +327,8 @@ func (h *WorkflowUIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			h.handleReload(w, r)
 		case "try-activate":
 			h.handleTryActivate(w, r)
+		case "preview":
+			h.handleStepPreview(w, r)
 		default:
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusNotFound)
@@ -310,6 +383,11 @@ func (h *WorkflowUIHandler) HandleStatus(w http.ResponseWriter, r *http.Request)
 	h.handleStatus(w, r)
 }
 
+// HandleVersion returns the engine build and plugin inventory (GET /engine/version).
+func (h *WorkflowUIHandler) HandleVersion(w http.ResponseWriter, r *http.Request) {
+	h.handleVersion(w, r)
+}
+
 func init() {
 	// This ensures handleGetModules is implemented at package init time.
 	// The handler function is set as a method below.
@@ -358,6 +436,10 @@ type validationResult struct {
 }
 
 func (h *WorkflowUIHandler) handleReload(w http.ResponseWriter, r *http.Request) {
+	if dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run")); dryRun {
+		h.handleReloadDryRun(w, r)
+		return
+	}
 	if h.reloadFn == nil {
 		http.Error(w, "reload not configured", http.StatusServiceUnavailable)
 		return
@@ -367,7 +449,9 @@ func (h *WorkflowUIHandler) handleReload(w http.ResponseWriter, r *http.Request)
 	cfg := h.config
 	h.mu.RUnlock()
 
+	user := extractTriggeredBy(r)
 	if err := h.reloadFn(cfg); err != nil {
+		h.recordHistoryAudit(r.Context(), "config.reload", user, ConfigVersion{Source: ConfigSourceReload}, false, "reload failed: "+err.Error())
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
 		if encErr := json.NewEncoder(w).Encode(map[string]string{"error": err.Error()}); encErr != nil {
@@ -375,6 +459,9 @@ func (h *WorkflowUIHandler) handleReload(w http.ResponseWriter, r *http.Request)
 		}
 		return
 	}
+	if v, err := h.recordConfigVersion(r.Context(), ConfigVersion{Author: user, Source: ConfigSourceReload, Config: cfg}); err == nil {
+		h.recordHistoryAudit(r.Context(), "config.reload", user, v, true, "engine reloaded")
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(map[string]string{"status": "reloaded"}); err != nil {
@@ -382,6 +469,50 @@ func (h *WorkflowUIHandler) handleReload(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+// reloadDryRunResult is the response to POST /api/workflow/reload?dry_run=true.
+type reloadDryRunResult struct {
+	DryRun bool               `json:"dryRun"`
+	Valid  bool               `json:"valid"`
+	Errors []string           `json:"errors,omitempty"`
+	Plan   *config.ReloadPlan `json:"plan"`
+}
+
+// handleReloadDryRun validates the current config and reports the modules a
+// reload would add, remove, replace or reconfigure, without reloading. An
+// invalid config is reported with 422 alongside its plan.
+func (h *WorkflowUIHandler) handleReloadDryRun(w http.ResponseWriter, r *http.Request) {
+	h.mu.RLock()
+	cfg := h.config
+	h.mu.RUnlock()
+
+	var plan *config.ReloadPlan
+	switch {
+	case h.reloadPlanFn != nil:
+		var err error
+		if plan, err = h.reloadPlanFn(cfg); err != nil {
+			writeJSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	default:
+		running, ok := h.latestConfigVersion(r.Context())
+		if !ok {
+			writeJSONError(w, http.StatusServiceUnavailable, "reload planning not configured")
+			return
+		}
+		plan = config.PlanReload(running, cfg, false)
+	}
+
+	result := reloadDryRunResult{DryRun: true, Errors: validateWorkflowConfig(cfg), Plan: plan}
+	result.Valid = len(result.Errors) == 0
+	w.Header().Set("Content-Type", "application/json")
+	if !result.Valid {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
 func (h *WorkflowUIHandler) handleStatus(w http.ResponseWriter, _ *http.Request) {
 	status := map[string]any{
 		"status": "running",
@@ -404,6 +535,18 @@ func (h *WorkflowUIHandler) handleStatus(w http.ResponseWriter, _ *http.Request)
 	}
 }
 
+func (h *WorkflowUIHandler) handleVersion(w http.ResponseWriter, _ *http.Request) {
+	report := version.Report{Info: version.Get(), Plugins: []version.PluginInfo{}}
+	if h.versionFn != nil {
+		report = h.versionFn()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		http.Error(w, "failed to encode version", http.StatusInternalServerError)
+	}
+}
+
 func (h *WorkflowUIHandler) handleValidate(w http.ResponseWriter, r *http.Request) {
 	var cfg config.WorkflowConfig
 	if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
@@ -411,6 +554,21 @@ func (h *WorkflowUIHandler) handleValidate(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	errors := validateWorkflowConfig(&cfg)
+	result := validationResult{
+		Valid:  len(errors) == 0,
+		Errors: errors,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		http.Error(w, "failed to encode result", http.StatusInternalServerError)
+	}
+}
+
+// validateWorkflowConfig runs the editor's structural checks on cfg and
+// returns the problems found.
+func validateWorkflowConfig(cfg *config.WorkflowConfig) []string {
 	var errors []string
 
 	if len(cfg.Modules) == 0 {
@@ -449,16 +607,7 @@ func (h *WorkflowUIHandler) handleValidate(w http.ResponseWriter, r *http.Reques
 			}
 		}
 	}
-
-	result := validationResult{
-		Valid:  len(errors) == 0,
-		Errors: errors,
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(result); err != nil {
-		http.Error(w, "failed to encode result", http.StatusInternalServerError)
-	}
+	return errors
 }
 
 // handleTryActivate builds a candidate engine from the request body without
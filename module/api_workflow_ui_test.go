@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/GoCodeAlone/workflow/config"
+	"github.com/GoCodeAlone/workflow/version"
 )
 
 func TestNewWorkflowUIHandler_NilConfig(t *testing.T) {
@@ -48,6 +49,7 @@ func TestWorkflowUIHandler_RegisterRoutes(t *testing.T) {
 	}{
 		{http.MethodGet, "/api/workflow/config"},
 		{http.MethodGet, "/api/workflow/modules"},
+		{http.MethodGet, "/api/workflow/version"},
 	}
 
 	for _, tc := range tests {
@@ -532,6 +534,76 @@ func TestWorkflowUIHandler_HandleReload_Error(t *testing.T) {
 	}
 }
 
+func TestWorkflowUIHandler_HandleReload_DryRun(t *testing.T) {
+	running := &config.WorkflowConfig{Modules: []config.ModuleConfig{
+		{Name: "server", Type: "http.server", Config: map[string]any{"address": ":8080"}},
+		{Name: "legacy", Type: "http.router"},
+	}}
+	h := NewWorkflowUIHandler(running)
+	reloaded := false
+	h.SetReloadFunc(func(cfg *config.WorkflowConfig) error {
+		reloaded = true
+		return nil
+	})
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	// Without a plan func or recorded version there is nothing to plan against.
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/workflow/reload?dry_run=true", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 with no running config, got %d", w.Code)
+	}
+
+	if _, err := h.RecordConfigVersion(t.Context(), running, "", ConfigSourceStartup); err != nil {
+		t.Fatal(err)
+	}
+	h.mu.Lock()
+	h.config = &config.WorkflowConfig{Modules: []config.ModuleConfig{
+		{Name: "server", Type: "http.server", Config: map[string]any{"address": ":9090"}},
+		{Name: "cache", Type: "cache.memory"},
+	}}
+	h.mu.Unlock()
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/workflow/reload?dry_run=true", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if reloaded {
+		t.Error("dry run must not call the reload function")
+	}
+	var result reloadDryRunResult
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	p := result.Plan
+	if !result.DryRun || !result.Valid || p.Strategy != config.ReloadStrategyFull ||
+		fmt.Sprint(p.ModulesAdded, p.ModulesRemoved, p.ModulesReplaced) != "[cache] [legacy] [server]" || len(p.Warnings) == 0 {
+		t.Errorf("dry run result = %+v, plan = %+v", result, p)
+	}
+
+	// A plan func takes precedence, and an invalid config is reported with 422.
+	h.SetReloadPlanFunc(func(cfg *config.WorkflowConfig) (*config.ReloadPlan, error) {
+		return &config.ReloadPlan{Strategy: config.ReloadStrategyNone}, nil
+	})
+	h.mu.Lock()
+	h.config = &config.WorkflowConfig{}
+	h.mu.Unlock()
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/workflow/reload?dry_run=true", nil))
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for an invalid config, got %d", w.Code)
+	}
+	result = reloadDryRunResult{}
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if result.Valid || len(result.Errors) == 0 || result.Plan.Strategy != config.ReloadStrategyNone {
+		t.Errorf("invalid dry run result = %+v", result)
+	}
+}
+
 func TestWorkflowUIHandler_SetReloadFunc(t *testing.T) {
 	h := NewWorkflowUIHandler(nil)
 	if h.reloadFn != nil {
@@ -776,3 +848,42 @@ func TestWorkflowUIHandler_TryActivate_ServeHTTP(t *testing.T) {
 		t.Error("expected tryActivateFn to be called via ServeHTTP")
 	}
 }
+
+func TestWorkflowUIHandler_HandleVersion(t *testing.T) {
+	h := NewWorkflowUIHandler(nil)
+
+	// Without a version func the endpoint reports the build only.
+	w := httptest.NewRecorder()
+	h.HandleVersion(w, httptest.NewRequest(http.MethodGet, "/api/workflow/version", nil))
+	var report version.Report
+	if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if report.Version == "" || report.GoVersion == "" || report.Plugins == nil {
+		t.Errorf("unexpected default report: %+v", report)
+	}
+
+	h.SetVersionFunc(func() version.Report {
+		return version.Report{
+			Info:       version.Info{Version: "v1.2.3"},
+			ConfigHash: "sha256:abc",
+			Plugins:    []version.PluginInfo{{Name: "http", Version: "1.0.0", Source: version.SourceBuiltin}},
+		}
+	})
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/admin/engine/version", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var body map[string]any
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body["version"] != "v1.2.3" || body["configHash"] != "sha256:abc" {
+		t.Errorf("unexpected body: %v", body)
+	}
+	plugins, _ := body["plugins"].([]any)
+	if len(plugins) != 1 || plugins[0].(map[string]any)["source"] != "builtin" {
+		t.Errorf("unexpected plugins: %v", body["plugins"])
+	}
+}
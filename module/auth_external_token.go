@@ -0,0 +1,458 @@
+package module
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrAuthUnavailable is returned by an AuthProvider that cannot reach the
+// service it validates tokens against, such as an unreachable JWKS or
+// introspection endpoint. AuthMiddleware answers 503 instead of 401 when no
+// provider accepted a token and one of them was unavailable.
+var ErrAuthUnavailable = errors.New("authentication service unavailable")
+
+const (
+	defaultJWKSRefreshInterval = time.Hour
+	// minJWKSRefreshInterval bounds how often an unknown kid, or a JWKS that
+	// failed to load, triggers a fetch so forged kids cannot flood the IdP.
+	minJWKSRefreshInterval = 10 * time.Second
+	// maxIntrospectionCacheEntries caps the introspection cache; it is
+	// cleared when full.
+	maxIntrospectionCacheEntries = 10000
+)
+
+// defaultExternalTokenAlgorithms are the asymmetric algorithms accepted for
+// JWKS-verified tokens when none are configured. HMAC is never accepted
+// since a JWKS only publishes public keys.
+var defaultExternalTokenAlgorithms = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// ExternalTokenConfig configures validation of tokens issued by an external
+// identity provider. Set JWKSURL to verify JWTs against the IdP's published
+// keys, IntrospectionURL to validate opaque tokens with RFC 7662 token
+// introspection, or both.
+type ExternalTokenConfig struct {
+	JWKSURL string
+	// Issuer, when set, must match the token's iss claim.
+	Issuer string
+	// Audiences, when set, must include one of the token's aud values.
+	Audiences []string
+	// Algorithms restricts the accepted JWT signing algorithms.
+	Algorithms []string
+	// ClockSkew is the tolerance applied to exp, nbf and iat.
+	ClockSkew time.Duration
+	// JWKSRefreshInterval is how long fetched keys are used before the JWKS
+	// is fetched again (default 1h). Unknown key IDs refresh it early.
+	JWKSRefreshInterval time.Duration
+
+	IntrospectionURL string
+	ClientID         string
+	ClientSecret     string
+	// IntrospectionCacheTTL caches active introspection results for up to
+	// this long, never past the token's exp. Zero disables the cache.
+	IntrospectionCacheTTL time.Duration
+
+	// HTTPClient is used for JWKS and introspection requests. If nil, a
+	// client with a 10s timeout is used.
+	HTTPClient *http.Client
+}
+
+// ExternalTokenProvider is an AuthProvider for tokens issued by an external
+// identity provider such as Auth0 or Keycloak. JWTs are verified against
+// the IdP's JWKS when one is configured; other tokens are introspected.
+// Validated claims are returned as-is, so they reach the request context
+// exactly like claims of locally issued tokens.
+type ExternalTokenProvider struct {
+	cfg    ExternalTokenConfig
+	client *http.Client
+	parser *jwt.Parser
+	jwks   *jwksCache
+
+	mu    sync.Mutex
+	cache map[[sha256.Size]byte]introspectionCacheEntry
+}
+
+type introspectionCacheEntry struct {
+	claims  map[string]any
+	expires time.Time
+}
+
+// NewExternalTokenProvider validates cfg and creates the provider. No
+// requests are made until Warm or the first Authenticate.
+func NewExternalTokenProvider(cfg ExternalTokenConfig) (*ExternalTokenProvider, error) {
+	if cfg.JWKSURL == "" && cfg.IntrospectionURL == "" {
+		return nil, fmt.Errorf("external token validation requires a JWKS url or an introspection url")
+	}
+	if cfg.IntrospectionURL != "" && cfg.ClientID == "" {
+		return nil, fmt.Errorf("token introspection requires a clientId")
+	}
+	if len(cfg.Algorithms) == 0 {
+		cfg.Algorithms = defaultExternalTokenAlgorithms
+	}
+	for _, alg := range cfg.Algorithms {
+		if strings.HasPrefix(alg, "HS") || alg == "none" {
+			return nil, fmt.Errorf("algorithm %q cannot be verified with a JWKS", alg)
+		}
+		if jwt.GetSigningMethod(alg) == nil {
+			return nil, fmt.Errorf("unknown JWT algorithm %q", alg)
+		}
+	}
+	if cfg.JWKSRefreshInterval <= 0 {
+		cfg.JWKSRefreshInterval = defaultJWKSRefreshInterval
+	}
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(cfg.Algorithms),
+		jwt.WithLeeway(cfg.ClockSkew),
+		jwt.WithExpirationRequired(),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if len(cfg.Audiences) > 0 {
+		opts = append(opts, jwt.WithAudience(cfg.Audiences...))
+	}
+
+	p := &ExternalTokenProvider{
+		cfg:    cfg,
+		client: client,
+		parser: jwt.NewParser(opts...),
+		cache:  make(map[[sha256.Size]byte]introspectionCacheEntry),
+	}
+	if cfg.JWKSURL != "" {
+		p.jwks = &jwksCache{url: cfg.JWKSURL, client: client, refreshInterval: cfg.JWKSRefreshInterval}
+	}
+	return p, nil
+}
+
+// Warm fetches the JWKS so the first request does not pay for it, and so an
+// unreachable JWKS is reported at startup.
+func (p *ExternalTokenProvider) Warm(ctx context.Context) error {
+	if p.jwks == nil {
+		return nil
+	}
+	p.jwks.mu.Lock()
+	defer p.jwks.mu.Unlock()
+	return p.jwks.refreshLocked(ctx)
+}
+
+// Authenticate implements AuthProvider. An invalid token returns false; an
+// unreachable IdP returns an error wrapping ErrAuthUnavailable.
+func (p *ExternalTokenProvider) Authenticate(token string) (bool, map[string]any, error) {
+	ctx := context.Background()
+	if p.jwks != nil && strings.Count(token, ".") == 2 {
+		return p.verifyJWT(ctx, token)
+	}
+	if p.cfg.IntrospectionURL != "" {
+		return p.introspect(ctx, token)
+	}
+	return false, nil, nil
+}
+
+func (p *ExternalTokenProvider) verifyJWT(ctx context.Context, token string) (bool, map[string]any, error) {
+	var unavailable error
+	parsed, err := p.parser.Parse(token, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, err := p.jwks.key(ctx, kid)
+		if errors.Is(err, ErrAuthUnavailable) {
+			unavailable = err
+		}
+		return key, err
+	})
+	if unavailable != nil {
+		return false, nil, unavailable
+	}
+	if err != nil {
+		return false, nil, nil //nolint:nilerr // Invalid token is a failed auth, not an error
+	}
+	claims, ok := parsed.Claims.(jwt.MapClaims)
+	if !ok || !parsed.Valid {
+		return false, nil, nil
+	}
+	result := make(map[string]any, len(claims))
+	maps.Copy(result, claims)
+	return true, result, nil
+}
+
+func (p *ExternalTokenProvider) introspect(ctx context.Context, token string) (bool, map[string]any, error) {
+	cacheKey := sha256.Sum256([]byte(token))
+	if p.cfg.IntrospectionCacheTTL > 0 {
+		p.mu.Lock()
+		entry, ok := p.cache[cacheKey]
+		if ok && time.Now().After(entry.expires) {
+			delete(p.cache, cacheKey)
+			ok = false
+		}
+		p.mu.Unlock()
+		if ok {
+			return true, maps.Clone(entry.claims), nil
+		}
+	}
+
+	form := url.Values{"token": {token}, "token_type_hint": {"access_token"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.IntrospectionURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, nil, fmt.Errorf("create introspection request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	// RFC 6749 §2.3.1: client credentials are form-encoded before Basic auth.
+	req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+
+	resp, err := p.client.Do(req) //nolint:gosec // G704: URL from module configuration
+	if err != nil {
+		return false, nil, fmt.Errorf("%w: introspection: %v", ErrAuthUnavailable, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return false, nil, fmt.Errorf("%w: introspection returned %d: %s", ErrAuthUnavailable, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	var claims map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&claims); err != nil {
+		return false, nil, fmt.Errorf("%w: decode introspection response: %v", ErrAuthUnavailable, err)
+	}
+	if active, _ := claims["active"].(bool); !active {
+		return false, nil, nil
+	}
+	delete(claims, "active")
+	if !p.introspectedClaimsValid(claims) {
+		return false, nil, nil
+	}
+
+	if p.cfg.IntrospectionCacheTTL > 0 {
+		expires := time.Now().Add(p.cfg.IntrospectionCacheTTL)
+		if exp, ok := claims["exp"].(float64); ok {
+			if t := time.Unix(int64(exp), 0); t.Before(expires) {
+				expires = t
+			}
+		}
+		p.mu.Lock()
+		if len(p.cache) >= maxIntrospectionCacheEntries {
+			clear(p.cache)
+		}
+		p.cache[cacheKey] = introspectionCacheEntry{claims: maps.Clone(claims), expires: expires}
+		p.mu.Unlock()
+	}
+	return true, claims, nil
+}
+
+// introspectedClaimsValid applies the expiry, issuer and audience checks to
+// an active introspection response. The IdP has already judged the token;
+// these guard against a token minted for a different issuer or API.
+func (p *ExternalTokenProvider) introspectedClaimsValid(claims map[string]any) bool {
+	if exp, ok := claims["exp"].(float64); ok && time.Now().After(time.Unix(int64(exp), 0).Add(p.cfg.ClockSkew)) {
+		return false
+	}
+	if p.cfg.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != p.cfg.Issuer {
+			return false
+		}
+	}
+	if len(p.cfg.Audiences) > 0 {
+		aud, err := jwt.MapClaims(claims).GetAudience()
+		if err != nil {
+			return false
+		}
+		for _, want := range p.cfg.Audiences {
+			for _, have := range aud {
+				if have == want {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// jwksCache holds the public keys of a JWKS by key ID. Keys are refreshed
+// after refreshInterval, and early when a token names an unknown kid. A
+// failed refresh keeps the previous keys so an IdP outage does not reject
+// tokens signed with keys that were already known.
+type jwksCache struct {
+	url             string
+	client          *http.Client
+	refreshInterval time.Duration
+
+	mu          sync.Mutex
+	keys        map[string]crypto.PublicKey
+	fetchedAt   time.Time
+	lastAttempt time.Time
+	lastErr     error
+}
+
+func (c *jwksCache) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key, found := c.lookupLocked(kid)
+	stale := c.keys == nil || time.Since(c.fetchedAt) >= c.refreshInterval
+	if (!found || stale) && time.Since(c.lastAttempt) >= minJWKSRefreshInterval {
+		if c.refreshLocked(ctx) == nil {
+			key, found = c.lookupLocked(kid)
+		}
+	}
+	if c.keys == nil {
+		return nil, fmt.Errorf("%w: JWKS %s: %v", ErrAuthUnavailable, c.url, c.lastErr)
+	}
+	if !found {
+		return nil, fmt.Errorf("no JWKS key for kid %q", kid)
+	}
+	return key, nil
+}
+
+// lookupLocked finds the key for kid. A token without a kid matches the
+// only key of a single-key JWKS.
+func (c *jwksCache) lookupLocked(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(c.keys) == 1 {
+		for _, k := range c.keys {
+			return k, true
+		}
+	}
+	k, ok := c.keys[kid]
+	return k, ok
+}
+
+func (c *jwksCache) refreshLocked(ctx context.Context) error {
+	c.lastAttempt = time.Now()
+	keys, err := fetchJWKS(ctx, c.client, c.url)
+	if err != nil {
+		c.lastErr = err
+		return fmt.Errorf("%w: JWKS %s: %v", ErrAuthUnavailable, c.url, err)
+	}
+	c.keys = keys
+	c.fetchedAt = c.lastAttempt
+	c.lastErr = nil
+	return nil
+}
+
+// jsonWebKey is the subset of RFC 7517 fields needed for signature keys.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func fetchJWKS(ctx context.Context, client *http.Client, jwksURL string) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req) //nolint:gosec // G704: URL from module configuration
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	var doc struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(doc.Keys))
+	for i := range doc.Keys {
+		k := &doc.Keys[i]
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		// Keys of unsupported types are skipped rather than failing the set.
+		if pub, err := k.publicKey(); err == nil {
+			keys[k.Kid] = pub
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no usable signing keys")
+	}
+	return keys, nil
+}
+
+func (k *jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeJWKInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeJWKInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported EC curve %q", k.Crv)
+		}
+		x, err := decodeJWKInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeJWKInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) { //nolint:staticcheck // SA1019: validates decoded JWK coordinates
+			return nil, fmt.Errorf("EC key is not on curve %s", k.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported OKP curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeJWKInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, fmt.Errorf("invalid JWK integer")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
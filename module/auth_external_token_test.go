@@ -0,0 +1,309 @@
+package module
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testIdP serves a JWKS whose keys can be rotated and counts JWKS fetches.
+type testIdP struct {
+	t       *testing.T
+	mu      sync.Mutex
+	keys    map[string]*rsa.PrivateKey
+	fetches atomic.Int32
+	srv     *httptest.Server
+}
+
+func newTestIdP(t *testing.T) *testIdP {
+	t.Helper()
+	idp := &testIdP{t: t, keys: map[string]*rsa.PrivateKey{}}
+	idp.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		idp.fetches.Add(1)
+		idp.mu.Lock()
+		defer idp.mu.Unlock()
+		var keys []map[string]string
+		for kid, k := range idp.keys {
+			keys = append(keys, map[string]string{
+				"kty": "RSA", "kid": kid, "use": "sig", "alg": "RS256",
+				"n": base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+				"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+			})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": keys})
+	}))
+	t.Cleanup(idp.srv.Close)
+	return idp
+}
+
+func (idp *testIdP) addKey(kid string) {
+	k, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		idp.t.Fatal(err)
+	}
+	idp.mu.Lock()
+	idp.keys[kid] = k
+	idp.mu.Unlock()
+}
+
+func (idp *testIdP) sign(kid string, claims jwt.MapClaims) string {
+	idp.mu.Lock()
+	k := idp.keys[kid]
+	idp.mu.Unlock()
+	tok := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	tok.Header["kid"] = kid
+	s, err := tok.SignedString(k)
+	if err != nil {
+		idp.t.Fatal(err)
+	}
+	return s
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{"sub": "user-1", "iss": "https://idp.test/", "aud": "orders-api", "scope": "orders:read", "exp": time.Now().Add(time.Hour).Unix()}
+}
+
+func TestExternalTokenProvider_JWKS(t *testing.T) {
+	idp := newTestIdP(t)
+	idp.addKey("k1")
+	p, err := NewExternalTokenProvider(ExternalTokenConfig{
+		JWKSURL:   idp.srv.URL,
+		Issuer:    "https://idp.test/",
+		Audiences: []string{"admin-api", "orders-api"},
+		ClockSkew: time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Warm(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ok, claims, err := p.Authenticate(idp.sign("k1", validClaims()))
+	if err != nil || !ok {
+		t.Fatalf("valid token rejected: ok=%v err=%v", ok, err)
+	}
+	if claims["sub"] != "user-1" || claims["scope"] != "orders:read" {
+		t.Errorf("claims = %v", claims)
+	}
+
+	tests := map[string]func(jwt.MapClaims){
+		"wrong issuer":   func(c jwt.MapClaims) { c["iss"] = "https://other.test/" },
+		"wrong audience": func(c jwt.MapClaims) { c["aud"] = "billing-api" },
+		"expired":        func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-2 * time.Minute).Unix() },
+		"no expiry":      func(c jwt.MapClaims) { delete(c, "exp") },
+	}
+	for name, mutate := range tests {
+		c := validClaims()
+		mutate(c)
+		if ok, _, err := p.Authenticate(idp.sign("k1", c)); ok || err != nil {
+			t.Errorf("%s: ok=%v err=%v, want rejected", name, ok, err)
+		}
+	}
+
+	// Expired within the clock skew is still accepted.
+	c := validClaims()
+	c["exp"] = time.Now().Add(-30 * time.Second).Unix()
+	if ok, _, _ := p.Authenticate(idp.sign("k1", c)); !ok {
+		t.Error("token expired within clock skew rejected")
+	}
+
+	// HMAC tokens are never accepted, even with a kid the JWKS knows.
+	hs := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims())
+	hs.Header["kid"] = "k1"
+	hsToken, _ := hs.SignedString([]byte("secret"))
+	if ok, _, _ := p.Authenticate(hsToken); ok {
+		t.Error("HS256 token accepted")
+	}
+}
+
+func TestExternalTokenProvider_KeyRotation(t *testing.T) {
+	idp := newTestIdP(t)
+	idp.addKey("k1")
+	p, err := NewExternalTokenProvider(ExternalTokenConfig{JWKSURL: idp.srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, _, err := p.Authenticate(idp.sign("k1", validClaims())); !ok || err != nil {
+		t.Fatalf("ok=%v err=%v", ok, err)
+	}
+
+	// The IdP rotates in k2; the unknown kid triggers one refresh.
+	idp.addKey("k2")
+	p.jwks.mu.Lock()
+	p.jwks.lastAttempt = time.Time{}
+	p.jwks.mu.Unlock()
+	if ok, _, err := p.Authenticate(idp.sign("k2", validClaims())); !ok || err != nil {
+		t.Fatalf("rotated key: ok=%v err=%v", ok, err)
+	}
+	fetches := idp.fetches.Load()
+
+	// A forged kid does not refetch within the minimum refresh interval.
+	forged := forgedKidToken(t)
+	for range 5 {
+		if ok, _, _ := p.Authenticate(forged); ok {
+			t.Fatal("forged token accepted")
+		}
+	}
+	if got := idp.fetches.Load(); got != fetches {
+		t.Errorf("JWKS fetched %d more times for unknown kids, want 0", got-fetches)
+	}
+
+	// An IdP outage keeps already-known keys working.
+	idp.srv.Close()
+	p.jwks.mu.Lock()
+	p.jwks.fetchedAt = time.Time{}
+	p.jwks.lastAttempt = time.Time{}
+	p.jwks.mu.Unlock()
+	if ok, _, err := p.Authenticate(idp.sign("k1", validClaims())); !ok || err != nil {
+		t.Errorf("known key after outage: ok=%v err=%v", ok, err)
+	}
+}
+
+func forgedKidToken(t *testing.T) string {
+	t.Helper()
+	k, _ := rsa.GenerateKey(rand.Reader, 2048)
+	tok := jwt.NewWithClaims(jwt.SigningMethodRS256, validClaims())
+	tok.Header["kid"] = "forged"
+	s, err := tok.SignedString(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestExternalTokenProvider_Introspection(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if id, secret, ok := r.BasicAuth(); !ok || id != "api" || secret != "s3cr%2Ft" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = r.ParseForm()
+		switch r.PostForm.Get("token") {
+		case "opaque-good":
+			_ = json.NewEncoder(w).Encode(map[string]any{"active": true, "sub": "svc-1", "scope": "orders:write", "aud": []string{"orders-api"}, "exp": time.Now().Add(time.Hour).Unix()})
+		case "opaque-other-aud":
+			_ = json.NewEncoder(w).Encode(map[string]any{"active": true, "sub": "svc-1", "aud": "billing-api"})
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]any{"active": false})
+		}
+	}))
+	defer srv.Close()
+
+	p, err := NewExternalTokenProvider(ExternalTokenConfig{
+		IntrospectionURL:      srv.URL,
+		ClientID:              "api",
+		ClientSecret:          "s3cr/t",
+		Audiences:             []string{"orders-api"},
+		IntrospectionCacheTTL: time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		ok, claims, err := p.Authenticate("opaque-good")
+		if !ok || err != nil {
+			t.Fatalf("ok=%v err=%v", ok, err)
+		}
+		if _, has := claims["active"]; has || claims["sub"] != "svc-1" {
+			t.Errorf("claims = %v", claims)
+		}
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("introspection calls = %d, want 1 (second cached)", got)
+	}
+	for _, tok := range []string{"opaque-bad", "opaque-other-aud"} {
+		if ok, _, err := p.Authenticate(tok); ok || err != nil {
+			t.Errorf("%s: ok=%v err=%v, want rejected", tok, ok, err)
+		}
+	}
+
+	wrongCreds, _ := NewExternalTokenProvider(ExternalTokenConfig{IntrospectionURL: srv.URL, ClientID: "api", ClientSecret: "wrong"})
+	if _, _, err := wrongCreds.Authenticate("opaque-good"); err == nil {
+		t.Error("expected ErrAuthUnavailable for rejected client credentials")
+	}
+}
+
+func TestNewExternalTokenProvider_Validation(t *testing.T) {
+	for name, cfg := range map[string]ExternalTokenConfig{
+		"nothing":        {},
+		"no client id":   {IntrospectionURL: "https://idp.test/introspect"},
+		"hmac algorithm": {JWKSURL: "https://idp.test/jwks", Algorithms: []string{"HS256"}},
+		"unknown alg":    {JWKSURL: "https://idp.test/jwks", Algorithms: []string{"XX999"}},
+	} {
+		if _, err := NewExternalTokenProvider(cfg); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestAuthMiddleware_ExternalTokens(t *testing.T) {
+	idp := newTestIdP(t)
+	idp.addKey("k1")
+
+	m := NewAuthMiddleware("auth", "Bearer")
+	m.SetExternalTokenConfig(ExternalTokenConfig{JWKSURL: idp.srv.URL})
+	if err := m.Init(NewMockApplication()); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var gotClaims map[string]any
+	handler := m.Process(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotClaims, _ = r.Context().Value(authClaimsContextKey).(map[string]any)
+	}))
+	serve := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := serve(idp.sign("k1", validClaims())); code != http.StatusOK {
+		t.Fatalf("valid token: status %d", code)
+	}
+	if gotClaims["sub"] != "user-1" {
+		t.Errorf("context claims = %v", gotClaims)
+	}
+	if code := serve(forgedKidToken(t)); code != http.StatusUnauthorized {
+		t.Errorf("forged token: status %d, want 401", code)
+	}
+}
+
+func TestAuthMiddleware_UnreachableJWKSIs503(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	m := NewAuthMiddleware("auth", "Bearer")
+	m.SetExternalTokenConfig(ExternalTokenConfig{JWKSURL: srv.URL})
+	if err := m.Init(NewMockApplication()); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start must not fail on an unreachable JWKS: %v", err)
+	}
+	handler := m.Process(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set("Authorization", "Bearer "+forgedKidToken(t))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", rec.Code)
+	}
+}
@@ -2,6 +2,7 @@ package module
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -19,6 +20,8 @@ type AuthMiddleware struct {
 	name      string
 	authType  string // e.g., "Bearer", "Basic", etc.
 	providers []AuthProvider
+	external  *ExternalTokenConfig
+	logger    modular.Logger
 }
 
 // AuthProvider defines methods for authentication providers
@@ -40,8 +43,22 @@ func (m *AuthMiddleware) Name() string {
 	return m.name
 }
 
+// SetExternalTokenConfig enables validation of tokens issued by an external
+// identity provider. The provider is created in Init.
+func (m *AuthMiddleware) SetExternalTokenConfig(cfg ExternalTokenConfig) {
+	m.external = &cfg
+}
+
 // Init initializes the middleware with the application context
 func (m *AuthMiddleware) Init(app modular.Application) error {
+	m.logger = app.Logger()
+	if m.external != nil {
+		provider, err := NewExternalTokenProvider(*m.external)
+		if err != nil {
+			return fmt.Errorf("auth middleware %q: %w", m.name, err)
+		}
+		m.RegisterProvider(provider)
+	}
 	return nil
 }
 
@@ -51,13 +68,13 @@ func (m *AuthMiddleware) Process(next http.Handler) http.Handler {
 		// Extract authorization header
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
-			http.Error(w, "Authorization header required", http.StatusUnauthorized)
+			writeRequestProblem(w, r, ProblemUnauthorized, "Authorization header required")
 			return
 		}
 
 		// Check for correct auth type
 		if !strings.HasPrefix(authHeader, m.authType+" ") {
-			http.Error(w, fmt.Sprintf("%s authorization required", m.authType), http.StatusUnauthorized)
+			writeRequestProblem(w, r, ProblemUnauthorized, m.authType+" authorization required")
 			return
 		}
 
@@ -65,11 +82,13 @@ func (m *AuthMiddleware) Process(next http.Handler) http.Handler {
 		token := strings.TrimPrefix(authHeader, m.authType+" ")
 
 		// Try to authenticate with each provider
+		unavailable := false
 		for _, provider := range m.providers {
 			valid, claims, err := provider.Authenticate(token)
 			if err != nil {
 				// Log error but continue with other providers
 				fmt.Printf("Authentication error: %v\n", err)
+				unavailable = unavailable || errors.Is(err, ErrAuthUnavailable)
 				continue
 			}
 
@@ -83,8 +102,14 @@ func (m *AuthMiddleware) Process(next http.Handler) http.Handler {
 			}
 		}
 
-		// If we get here, authentication failed
-		http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+		// If we get here, authentication failed. When a provider could not
+		// reach its identity provider the token may well be valid, so say so
+		// rather than rejecting it.
+		if unavailable {
+			writeRequestProblem(w, r, ProblemServiceUnavailable, "Authentication service unavailable")
+			return
+		}
+		writeRequestProblem(w, r, ProblemUnauthorized, "Invalid credentials")
 	})
 }
 
@@ -100,8 +125,19 @@ func (m *AuthMiddleware) AddProvider(validTokens map[string]map[string]any) {
 	})
 }
 
-// Start is a no-op for this middleware
+// Start fetches the keys of providers that validate against an external
+// identity provider. Failure is logged rather than returned: protected routes
+// answer 503 until the keys can be fetched.
 func (m *AuthMiddleware) Start(ctx context.Context) error {
+	for _, provider := range m.providers {
+		w, ok := provider.(interface{ Warm(context.Context) error })
+		if !ok {
+			continue
+		}
+		if err := w.Warm(ctx); err != nil && m.logger != nil {
+			m.logger.Warn("auth middleware: external token validation unavailable", "module", m.name, "error", err)
+		}
+	}
 	return nil
 }
 
@@ -10,7 +10,7 @@ import (
 	"time"
 
 	"github.com/GoCodeAlone/modular"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/GoCodeAlone/workflow/auth/password"
 )
 
 // UserStore provides user CRUD operations backed by an in-memory store
@@ -22,6 +22,7 @@ type UserStore struct {
 	mu          sync.RWMutex
 	nextID      int
 	persistence *PersistenceStore
+	hasher      *password.Hasher // bcrypt at the default cost when nil
 }
 
 // NewUserStore creates a new user store module.
@@ -33,6 +34,13 @@ func NewUserStore(name string) *UserStore {
 	}
 }
 
+// SetPasswordHasher sets the algorithm used to hash new passwords. Stored
+// hashes made with another algorithm or other parameters are replaced when
+// their user next logs in.
+func (u *UserStore) SetPasswordHasher(h *password.Hasher) {
+	u.hasher = h
+}
+
 func (u *UserStore) Name() string { return u.name }
 
 func (u *UserStore) Init(app modular.Application) error {
@@ -114,7 +122,7 @@ func (u *UserStore) CreateUser(email, name, password string, metadata map[string
 		return nil, fmt.Errorf("user with email %q already exists", email)
 	}
 
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hash, err := u.passwordHasher().Hash(password)
 	if err != nil {
 		return nil, fmt.Errorf("hash password: %w", err)
 	}
@@ -123,7 +131,7 @@ func (u *UserStore) CreateUser(email, name, password string, metadata map[string
 		ID:           fmt.Sprintf("user_%d", u.nextID),
 		Email:        email,
 		Name:         name,
-		PasswordHash: string(hash),
+		PasswordHash: hash,
 		Metadata:     metadata,
 		CreatedAt:    time.Now(),
 	}
@@ -164,22 +172,59 @@ func (u *UserStore) UpdateUserMetadata(id string, metadata map[string]any) error
 }
 
 // VerifyPassword checks if the password matches the stored hash for the given email.
+// A hash made with another algorithm or other parameters than the store's
+// is replaced on success.
 func (u *UserStore) VerifyPassword(email, password string) (*User, error) {
 	u.mu.RLock()
 	user, exists := u.users[email]
+	var stored string
+	if exists {
+		stored = user.PasswordHash
+	}
 	u.mu.RUnlock()
 
 	if !exists {
 		return nil, fmt.Errorf("user not found")
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+	match, rehash := u.passwordHasher().Verify(stored, password)
+	if !match {
 		return nil, fmt.Errorf("invalid credentials")
 	}
 
+	if rehash {
+		if hash, err := u.passwordHasher().Hash(password); err == nil {
+			u.mu.Lock()
+			if user.PasswordHash == stored {
+				user.PasswordHash = hash
+				u.persistUserLocked(user)
+			}
+			u.mu.Unlock()
+		}
+	}
 	return user, nil
 }
 
+// SetPassword replaces the password of the user identified by ID.
+func (u *UserStore) SetPassword(id, password string) error {
+	hash, err := u.passwordHasher().Hash(password)
+	if err != nil {
+		return fmt.Errorf("hash password: %w", err)
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for _, user := range u.users {
+		if user.ID == id {
+			user.PasswordHash = hash
+			u.persistUserLocked(user)
+			return nil
+		}
+	}
+	return fmt.Errorf("user %q not found", id)
+}
+
 // UserCount returns the number of users.
 func (u *UserStore) UserCount() int {
 	u.mu.RLock()
@@ -224,6 +269,13 @@ func (u *UserStore) LoadSeedFile(path string) error {
 	return nil
 }
 
+func (u *UserStore) passwordHasher() *password.Hasher {
+	if u.hasher != nil {
+		return u.hasher
+	}
+	return password.DefaultHasher()
+}
+
 func (u *UserStore) persistUserLocked(user *User) {
 	if u.persistence == nil {
 		return
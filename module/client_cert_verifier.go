@@ -0,0 +1,316 @@
+package module
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Errors returned by ClientCertVerifier.Verify.
+var (
+	ErrClientCertUntrusted = errors.New("client certificate is not signed by a trusted CA")
+	ErrClientCertRevoked   = errors.New("client certificate has been revoked")
+)
+
+// clientCertContextKey is the request context key for the verified client
+// certificate identity.
+const clientCertContextKey authContextKey = "client_cert"
+
+// ClientCertIdentity describes a verified client certificate.
+type ClientCertIdentity struct {
+	CommonName  string    `json:"cn"`
+	Subject     string    `json:"subject"`
+	Issuer      string    `json:"issuer"`
+	Serial      string    `json:"serial"`
+	Fingerprint string    `json:"fingerprint"` // hex-encoded SHA-256 of the DER certificate
+	DNSNames    []string  `json:"dns_names,omitempty"`
+	Emails      []string  `json:"emails,omitempty"`
+	URIs        []string  `json:"uris,omitempty"`
+	IPs         []string  `json:"ips,omitempty"`
+	NotAfter    time.Time `json:"not_after"`
+}
+
+// NewClientCertIdentity extracts the identity fields from cert.
+func NewClientCertIdentity(cert *x509.Certificate) *ClientCertIdentity {
+	sum := sha256.Sum256(cert.Raw)
+	id := &ClientCertIdentity{
+		CommonName:  cert.Subject.CommonName,
+		Subject:     cert.Subject.String(),
+		Issuer:      cert.Issuer.String(),
+		Serial:      cert.SerialNumber.String(),
+		Fingerprint: hex.EncodeToString(sum[:]),
+		DNSNames:    cert.DNSNames,
+		Emails:      cert.EmailAddresses,
+		NotAfter:    cert.NotAfter,
+	}
+	for _, u := range cert.URIs {
+		id.URIs = append(id.URIs, u.String())
+	}
+	for _, ip := range cert.IPAddresses {
+		id.IPs = append(id.IPs, ip.String())
+	}
+	return id
+}
+
+// SANs returns every subject alternative name on the certificate.
+func (id *ClientCertIdentity) SANs() []string {
+	sans := make([]string, 0, len(id.DNSNames)+len(id.Emails)+len(id.URIs)+len(id.IPs))
+	sans = append(sans, id.DNSNames...)
+	sans = append(sans, id.Emails...)
+	sans = append(sans, id.URIs...)
+	return append(sans, id.IPs...)
+}
+
+// ToMap returns the identity as a map for use in pipeline contexts.
+func (id *ClientCertIdentity) ToMap() map[string]any {
+	return map[string]any{
+		"cn":          id.CommonName,
+		"subject":     id.Subject,
+		"issuer":      id.Issuer,
+		"serial":      id.Serial,
+		"fingerprint": id.Fingerprint,
+		"dns_names":   id.DNSNames,
+		"emails":      id.Emails,
+		"uris":        id.URIs,
+		"ips":         id.IPs,
+		"sans":        id.SANs(),
+		"not_after":   id.NotAfter.UTC().Format(time.RFC3339),
+	}
+}
+
+// ClientCertIdentityFromContext returns the client certificate identity stored
+// by the clientcert middleware, or nil when none is present.
+func ClientCertIdentityFromContext(ctx context.Context) *ClientCertIdentity {
+	id, _ := ctx.Value(clientCertContextKey).(*ClientCertIdentity)
+	return id
+}
+
+// ClientCertVerifier verifies client certificates against a CA bundle and an
+// optional CRL. Both files are re-read when their modification time changes,
+// checked at most once per reload interval, so rotating the CA bundle or
+// publishing a new CRL does not require a restart.
+type ClientCertVerifier struct {
+	caFile         string
+	crlFile        string
+	reloadInterval time.Duration
+
+	mu          sync.RWMutex
+	pool        *x509.CertPool
+	revoked     map[string]struct{} // "issuer|serial"
+	caModTime   time.Time
+	crlModTime  time.Time
+	lastChecked time.Time
+}
+
+// NewClientCertVerifier loads caFile (and crlFile when set) and returns a
+// verifier. A zero reloadInterval defaults to 30 seconds.
+func NewClientCertVerifier(caFile, crlFile string, reloadInterval time.Duration) (*ClientCertVerifier, error) {
+	if caFile == "" {
+		return nil, fmt.Errorf("client cert verifier: caFile is required")
+	}
+	if reloadInterval <= 0 {
+		reloadInterval = 30 * time.Second
+	}
+	v := &ClientCertVerifier{caFile: caFile, crlFile: crlFile, reloadInterval: reloadInterval}
+	if err := v.Reload(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Pool returns the current trusted CA pool, reloading it first if the CA
+// bundle changed on disk.
+func (v *ClientCertVerifier) Pool() *x509.CertPool {
+	v.maybeReload()
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.pool
+}
+
+// Reload re-reads the CA bundle and CRL unconditionally.
+func (v *ClientCertVerifier) Reload() error {
+	caInfo, err := os.Stat(v.caFile)
+	if err != nil {
+		return fmt.Errorf("client cert verifier: stat CA file: %w", err)
+	}
+	cas, err := loadCACertificates(v.caFile)
+	if err != nil {
+		return err
+	}
+	pool := x509.NewCertPool()
+	for _, c := range cas {
+		pool.AddCert(c)
+	}
+
+	var revoked map[string]struct{}
+	var crlModTime time.Time
+	if v.crlFile != "" {
+		crlInfo, err := os.Stat(v.crlFile)
+		if err != nil {
+			return fmt.Errorf("client cert verifier: stat CRL file: %w", err)
+		}
+		revoked, err = loadRevocationList(v.crlFile, cas)
+		if err != nil {
+			return err
+		}
+		crlModTime = crlInfo.ModTime()
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.pool = pool
+	v.revoked = revoked
+	v.caModTime = caInfo.ModTime()
+	v.crlModTime = crlModTime
+	v.lastChecked = time.Now()
+	return nil
+}
+
+// maybeReload reloads the CA bundle and CRL when the reload interval has
+// elapsed and either file has a new modification time. Reload failures keep
+// the previous trust material in place.
+func (v *ClientCertVerifier) maybeReload() {
+	v.mu.RLock()
+	due := time.Since(v.lastChecked) >= v.reloadInterval
+	caMod, crlMod := v.caModTime, v.crlModTime
+	v.mu.RUnlock()
+	if !due {
+		return
+	}
+
+	changed := false
+	if info, err := os.Stat(v.caFile); err == nil && !info.ModTime().Equal(caMod) {
+		changed = true
+	}
+	if v.crlFile != "" {
+		if info, err := os.Stat(v.crlFile); err == nil && !info.ModTime().Equal(crlMod) {
+			changed = true
+		}
+	}
+	if changed && v.Reload() == nil {
+		return
+	}
+	v.mu.Lock()
+	v.lastChecked = time.Now()
+	v.mu.Unlock()
+}
+
+// Verify checks that cert chains to a trusted CA (using intermediates when
+// provided) and has not been revoked.
+func (v *ClientCertVerifier) Verify(cert *x509.Certificate, intermediates []*x509.Certificate) ([][]*x509.Certificate, error) {
+	pool := v.Pool()
+	inter := x509.NewCertPool()
+	for _, c := range intermediates {
+		inter.AddCert(c)
+	}
+	chains, err := cert.Verify(x509.VerifyOptions{
+		Roots:         pool,
+		Intermediates: inter,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrClientCertUntrusted, err)
+	}
+	if err := v.CheckRevocation(chains); err != nil {
+		return nil, err
+	}
+	return chains, nil
+}
+
+// CheckRevocation returns ErrClientCertRevoked when any certificate in the
+// verified chains appears on the CRL.
+func (v *ClientCertVerifier) CheckRevocation(chains [][]*x509.Certificate) error {
+	v.maybeReload()
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	if len(v.revoked) == 0 {
+		return nil
+	}
+	for _, chain := range chains {
+		for _, c := range chain {
+			if _, ok := v.revoked[revocationKey(c.RawIssuer, c.SerialNumber.String())]; ok {
+				return ErrClientCertRevoked
+			}
+		}
+	}
+	return nil
+}
+
+// loadCACertificates parses every PEM certificate in path.
+func loadCACertificates(path string) ([]*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("client cert verifier: read CA file: %w", err)
+	}
+	var cas []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		c, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("client cert verifier: parse CA certificate: %w", err)
+		}
+		cas = append(cas, c)
+	}
+	if len(cas) == 0 {
+		return nil, fmt.Errorf("client cert verifier: no valid certificates found in %s", path)
+	}
+	return cas, nil
+}
+
+// loadRevocationList parses a PEM or DER CRL and verifies its signature
+// against one of the trusted CAs.
+func loadRevocationList(path string, cas []*x509.Certificate) (map[string]struct{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("client cert verifier: read CRL file: %w", err)
+	}
+	revoked := make(map[string]struct{})
+	for len(data) > 0 {
+		der := data
+		if block, rest := pem.Decode(data); block != nil {
+			der, data = block.Bytes, rest
+		} else {
+			data = nil
+		}
+		crl, err := x509.ParseRevocationList(der)
+		if err != nil {
+			return nil, fmt.Errorf("client cert verifier: parse CRL: %w", err)
+		}
+		if !crlSignedByAny(crl, cas) {
+			return nil, fmt.Errorf("client cert verifier: CRL in %s is not signed by a trusted CA", path)
+		}
+		for _, entry := range crl.RevokedCertificateEntries {
+			revoked[revocationKey(crl.RawIssuer, entry.SerialNumber.String())] = struct{}{}
+		}
+	}
+	return revoked, nil
+}
+
+// crlSignedByAny reports whether crl was signed by one of cas.
+func crlSignedByAny(crl *x509.RevocationList, cas []*x509.Certificate) bool {
+	for _, c := range cas {
+		if crl.CheckSignatureFrom(c) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func revocationKey(rawIssuer []byte, serial string) string {
+	sum := sha256.Sum256(rawIssuer)
+	return hex.EncodeToString(sum[:]) + "|" + serial
+}
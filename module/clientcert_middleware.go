@@ -0,0 +1,237 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/GoCodeAlone/modular"
+)
+
+// Reasons reported in clientcert middleware 403 responses.
+const (
+	ClientCertReasonMissing       = "no_client_cert"
+	ClientCertReasonUntrusted     = "untrusted_client_cert"
+	ClientCertReasonNotAuthorized = "client_cert_not_authorized"
+)
+
+// ClientCertRule authorizes client certificates for requests whose path
+// matches Path. A certificate is authorized when its subject (CN or full DN)
+// matches one of Subjects or any SAN matches one of SANs. A rule with neither
+// list set admits any trusted certificate. Patterns support '*' wildcards.
+type ClientCertRule struct {
+	Path     string   `yaml:"path" json:"path"`
+	Subjects []string `yaml:"subjects" json:"subjects"`
+	SANs     []string `yaml:"sans" json:"sans"`
+}
+
+// ClientCertMiddlewareConfig holds configuration for the clientcert middleware.
+type ClientCertMiddlewareConfig struct {
+	// CAFile is the PEM bundle of CAs trusted to issue client certificates.
+	// When empty, the middleware relies on chains verified by the TLS listener
+	// (http.server clientAuth.mode: verify).
+	CAFile string `yaml:"caFile" json:"caFile"`
+	// CRLFile is an optional PEM or DER certificate revocation list.
+	CRLFile string `yaml:"crlFile" json:"crlFile"`
+	// ReloadInterval bounds how often the CA and CRL files are checked for changes.
+	ReloadInterval time.Duration `yaml:"reloadInterval" json:"reloadInterval"`
+	// Rules are evaluated in order; the first rule whose path matches applies.
+	// When rules are configured and none match, the request is rejected.
+	Rules []ClientCertRule `yaml:"rules" json:"rules"`
+}
+
+// compiledClientCertRule is a ClientCertRule with precompiled patterns.
+type compiledClientCertRule struct {
+	path     *regexp.Regexp
+	subjects []*regexp.Regexp
+	sans     []*regexp.Regexp
+}
+
+// ClientCertMiddleware authenticates requests using TLS client certificates
+// and injects the certificate identity into the request context.
+type ClientCertMiddleware struct {
+	name     string
+	config   ClientCertMiddlewareConfig
+	verifier *ClientCertVerifier
+	rules    []compiledClientCertRule
+}
+
+// NewClientCertMiddleware creates a clientcert middleware. Patterns are
+// compiled immediately; the CA bundle is loaded in Init.
+func NewClientCertMiddleware(name string, cfg ClientCertMiddlewareConfig) *ClientCertMiddleware {
+	m := &ClientCertMiddleware{name: name, config: cfg}
+	for _, r := range cfg.Rules {
+		path := r.Path
+		if path == "" {
+			path = "*"
+		}
+		rule := compiledClientCertRule{path: globPattern(path)}
+		for _, s := range r.Subjects {
+			rule.subjects = append(rule.subjects, globPattern(s))
+		}
+		for _, s := range r.SANs {
+			rule.sans = append(rule.sans, globPattern(s))
+		}
+		m.rules = append(m.rules, rule)
+	}
+	return m
+}
+
+// globPattern compiles a '*' wildcard pattern into an anchored regexp.
+func globPattern(pattern string) *regexp.Regexp {
+	quoted := regexp.QuoteMeta(pattern)
+	return regexp.MustCompile("^" + strings.ReplaceAll(quoted, `\*`, ".*") + "$")
+}
+
+// Name returns the module name.
+func (m *ClientCertMiddleware) Name() string {
+	return m.name
+}
+
+// Init loads the CA bundle and CRL when configured.
+func (m *ClientCertMiddleware) Init(_ modular.Application) error {
+	if m.config.CAFile == "" {
+		if m.config.CRLFile != "" {
+			return fmt.Errorf("clientcert middleware %q: crlFile requires caFile", m.name)
+		}
+		return nil
+	}
+	v, err := NewClientCertVerifier(m.config.CAFile, m.config.CRLFile, m.config.ReloadInterval)
+	if err != nil {
+		return fmt.Errorf("clientcert middleware %q: %w", m.name, err)
+	}
+	m.verifier = v
+	return nil
+}
+
+// Process implements the HTTPMiddleware interface.
+func (m *ClientCertMiddleware) Process(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+			writeClientCertError(w, ClientCertReasonMissing, "client certificate required")
+			return
+		}
+		leaf := r.TLS.PeerCertificates[0]
+
+		if m.verifier != nil {
+			if _, err := m.verifier.Verify(leaf, r.TLS.PeerCertificates[1:]); err != nil {
+				msg := "client certificate is not trusted"
+				if errors.Is(err, ErrClientCertRevoked) {
+					msg = "client certificate has been revoked"
+				}
+				writeClientCertError(w, ClientCertReasonUntrusted, msg)
+				return
+			}
+		} else if len(r.TLS.VerifiedChains) == 0 && !clientCertVerifiedByListener(r.Context()) {
+			writeClientCertError(w, ClientCertReasonUntrusted, "client certificate is not trusted")
+			return
+		}
+
+		id := NewClientCertIdentity(leaf)
+		if !m.authorized(r.URL.Path, id) {
+			writeClientCertError(w, ClientCertReasonNotAuthorized, "client certificate is not authorized for this route")
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), clientCertContextKey, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// listenerVerifiedKey marks the context of connections accepted by a
+// listener in clientAuth verify mode.
+type listenerVerifiedKey struct{}
+
+// withListenerVerifiedClientCert marks ctx as belonging to a connection
+// whose client certificate chain the listener verified during the handshake.
+// Verify mode checks chains itself rather than through crypto/tls, so
+// tls.ConnectionState.VerifiedChains stays empty on those connections.
+func withListenerVerifiedClientCert(ctx context.Context) context.Context {
+	return context.WithValue(ctx, listenerVerifiedKey{}, true)
+}
+
+func clientCertVerifiedByListener(ctx context.Context) bool {
+	verified, _ := ctx.Value(listenerVerifiedKey{}).(bool)
+	return verified
+}
+
+// authorized applies the first rule whose path pattern matches.
+func (m *ClientCertMiddleware) authorized(path string, id *ClientCertIdentity) bool {
+	if len(m.rules) == 0 {
+		return true
+	}
+	for _, rule := range m.rules {
+		if !rule.path.MatchString(path) {
+			continue
+		}
+		if len(rule.subjects) == 0 && len(rule.sans) == 0 {
+			return true
+		}
+		for _, p := range rule.subjects {
+			if p.MatchString(id.CommonName) || p.MatchString(id.Subject) {
+				return true
+			}
+		}
+		for _, p := range rule.sans {
+			for _, san := range id.SANs() {
+				if p.MatchString(san) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return false
+}
+
+func writeClientCertError(w http.ResponseWriter, reason, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg, "reason": reason})
+}
+
+// Start is a no-op for this middleware.
+func (m *ClientCertMiddleware) Start(_ context.Context) error {
+	return nil
+}
+
+// Stop is a no-op for this middleware.
+func (m *ClientCertMiddleware) Stop(_ context.Context) error {
+	return nil
+}
+
+// ProvidesServices returns the services provided by this module.
+func (m *ClientCertMiddleware) ProvidesServices() []modular.ServiceProvider {
+	return []modular.ServiceProvider{
+		{
+			Name:        m.name,
+			Description: "HTTP Client Certificate Authentication Middleware",
+			Instance:    m,
+		},
+	}
+}
+
+// RequiresServices returns services required by this module.
+func (m *ClientCertMiddleware) RequiresServices() []modular.ServiceDependency {
+	return nil
+}
+
+// withClientCertAuth returns a copy of data with the certificate identity
+// merged into data["_auth"]["client_cert"].
+func withClientCertAuth(data map[string]any, id *ClientCertIdentity) map[string]any {
+	out := make(map[string]any, len(data)+1)
+	maps.Copy(out, data)
+	auth := make(map[string]any)
+	if existing, ok := data["_auth"].(map[string]any); ok {
+		maps.Copy(auth, existing)
+	}
+	auth["client_cert"] = id.ToMap()
+	out["_auth"] = auth
+	return out
+}
@@ -0,0 +1,403 @@
+package module
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/pkg/tlsutil"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T, cn string) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+func (ca *testCA) issue(t *testing.T, serial int64, cn string, dnsNames ...string) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: cn},
+		DNSNames:     dnsNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, _ := x509.ParseCertificate(der)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+// serverFiles issues a server certificate for 127.0.0.1 and writes it and
+// its key as PEM files to dir.
+func (ca *testCA) serverFiles(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1000),
+		Subject:      pkix.Name{CommonName: "server"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile = writeTestFile(t, dir, "server.pem", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	keyFile = writeTestFile(t, dir, "server-key.pem", pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+	return certFile, keyFile
+}
+
+func (ca *testCA) crl(t *testing.T, serials ...int64) []byte {
+	t.Helper()
+	tmpl := &x509.RevocationList{
+		Number:     big.NewInt(time.Now().UnixNano()),
+		ThisUpdate: time.Now().Add(-time.Minute),
+		NextUpdate: time.Now().Add(time.Hour),
+	}
+	for _, s := range serials {
+		tmpl.RevokedCertificateEntries = append(tmpl.RevokedCertificateEntries, x509.RevocationListEntry{
+			SerialNumber:   big.NewInt(s),
+			RevocationTime: time.Now(),
+		})
+	}
+	der, err := x509.CreateRevocationList(rand.Reader, tmpl, ca.cert, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der})
+}
+
+func writeTestFile(t *testing.T, dir, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// serveWithCert runs a request through m with cert presented as the TLS peer.
+func serveWithCert(m *ClientCertMiddleware, path string, cert *tls.Certificate) (*httptest.ResponseRecorder, *ClientCertIdentity) {
+	var seen *ClientCertIdentity
+	handler := m.Process(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = ClientCertIdentityFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.TLS = &tls.ConnectionState{}
+	if cert != nil {
+		req.TLS.PeerCertificates = []*x509.Certificate{cert.Leaf}
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec, seen
+}
+
+func clientCertReason(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", rec.Code)
+	}
+	var body map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	return body["reason"]
+}
+
+func TestClientCertMiddleware(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, "clients-ca")
+	other := newTestCA(t, "other-ca")
+
+	admin := ca.issue(t, 10, "admin-alice")
+	service := ca.issue(t, 11, "billing", "billing.svc.internal")
+	revoked := ca.issue(t, 12, "admin-mallory")
+	untrusted := other.issue(t, 10, "admin-eve")
+
+	m := NewClientCertMiddleware("mtls", ClientCertMiddlewareConfig{
+		CAFile:  writeTestFile(t, dir, "ca.pem", ca.pem),
+		CRLFile: writeTestFile(t, dir, "ca.crl", ca.crl(t, 12)),
+		Rules: []ClientCertRule{
+			{Path: "/admin/*", Subjects: []string{"admin-*"}},
+			{Path: "/api/*", SANs: []string{"*.svc.internal"}},
+		},
+	})
+	if err := m.Init(nil); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		cert   *tls.Certificate
+		reason string
+	}{
+		{"no certificate", "/admin/users", nil, ClientCertReasonMissing},
+		{"untrusted CA", "/admin/users", &untrusted, ClientCertReasonUntrusted},
+		{"revoked", "/admin/users", &revoked, ClientCertReasonUntrusted},
+		{"subject mismatch", "/admin/users", &service, ClientCertReasonNotAuthorized},
+		{"no matching rule", "/other", &admin, ClientCertReasonNotAuthorized},
+		{"subject match", "/admin/users", &admin, ""},
+		{"SAN match", "/api/invoices", &service, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, id := serveWithCert(m, tt.path, tt.cert)
+			if tt.reason != "" {
+				if got := clientCertReason(t, rec); got != tt.reason {
+					t.Errorf("reason = %q, want %q", got, tt.reason)
+				}
+				return
+			}
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+			if id == nil || id.CommonName != tt.cert.Leaf.Subject.CommonName {
+				t.Errorf("expected identity for %q in context, got %+v", tt.cert.Leaf.Subject.CommonName, id)
+			}
+		})
+	}
+}
+
+func TestClientCertMiddleware_ReloadsCABundle(t *testing.T) {
+	dir := t.TempDir()
+	oldCA := newTestCA(t, "old-ca")
+	newCA := newTestCA(t, "new-ca")
+	cert := newCA.issue(t, 5, "client")
+
+	caFile := writeTestFile(t, dir, "ca.pem", oldCA.pem)
+	m := NewClientCertMiddleware("mtls", ClientCertMiddlewareConfig{CAFile: caFile, ReloadInterval: time.Millisecond})
+	if err := m.Init(nil); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	if rec, _ := serveWithCert(m, "/", &cert); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 before rotation, got %d", rec.Code)
+	}
+
+	writeTestFile(t, dir, "ca.pem", append(oldCA.pem, newCA.pem...))
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(caFile, future, future); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	if rec, _ := serveWithCert(m, "/", &cert); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 after CA rotation, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestClientCertMiddleware_ListenerVerifiedChains(t *testing.T) {
+	m := NewClientCertMiddleware("mtls", ClientCertMiddlewareConfig{})
+	if err := m.Init(nil); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	cert := newTestCA(t, "ca").issue(t, 1, "client")
+
+	// Without a CA bundle the middleware trusts only chains verified by the listener.
+	rec, _ := serveWithCert(m, "/", &cert)
+	if got := clientCertReason(t, rec); got != ClientCertReasonUntrusted {
+		t.Errorf("reason = %q, want %q", got, ClientCertReasonUntrusted)
+	}
+
+	if err := NewClientCertMiddleware("bad", ClientCertMiddlewareConfig{CRLFile: "x.crl"}).Init(nil); err == nil {
+		t.Error("expected error for crlFile without caFile")
+	}
+}
+
+// TestClientCertMiddleware_BehindVerifyListener covers the documented setup:
+// clientcert middleware without caFile on an http.server whose clientAuth
+// mode is verify.
+func TestClientCertMiddleware_BehindVerifyListener(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, "clients-ca")
+	client := ca.issue(t, 30, "billing")
+	certFile, keyFile := ca.serverFiles(t, dir)
+
+	m := NewClientCertMiddleware("mtls", ClientCertMiddlewareConfig{
+		Rules: []ClientCertRule{{Path: "/billing", Subjects: []string{"billing"}}},
+	})
+	if err := m.Init(nil); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	srv := NewStandardHTTPServer("https", addr)
+	srv.logger = &slogLogger{slog.Default()}
+	mux := http.NewServeMux()
+	mux.Handle("/", m.Process(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(ClientCertIdentityFromContext(r.Context()).CommonName))
+	})))
+	srv.AddRouter(&muxRouter{mux})
+	srv.SetTLSConfig(HTTPServerTLSConfig{Mode: "manual", Manual: tlsutil.TLSConfig{CertFile: certFile, KeyFile: keyFile}})
+	srv.SetClientAuthConfig(HTTPServerClientAuthConfig{Mode: "verify", CAFile: writeTestFile(t, dir, "ca.pem", ca.pem)})
+	if err := srv.Start(t.Context()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer func() { _ = srv.Stop(context.Background()) }()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		RootCAs:      roots,
+		Certificates: []tls.Certificate{client},
+		MinVersion:   tls.VersionTLS12,
+	}}}
+	defer httpClient.CloseIdleConnections()
+	get := func(path string) (int, string) {
+		t.Helper()
+		var resp *http.Response
+		for range 50 { // wait for the listener
+			if resp, err = httpClient.Get("https://" + addr + path); err == nil {
+				break
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	if code, body := get("/billing"); code != http.StatusOK || body != "billing" {
+		t.Errorf("GET /billing = %d %q, want 200 with the certificate identity", code, body)
+	}
+	if code, _ := get("/other"); code != http.StatusForbidden {
+		t.Errorf("GET /other = %d, want 403 from the rules", code)
+	}
+}
+
+func TestApplyClientAuth_VerifyMode(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, "clients-ca")
+	good := ca.issue(t, 20, "good")
+	revoked := ca.issue(t, 21, "revoked")
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	srv.TLS = &tls.Config{MinVersion: tls.VersionTLS12}
+	err := applyClientAuth(srv.TLS, HTTPServerClientAuthConfig{
+		Mode:    "verify",
+		CAFile:  writeTestFile(t, dir, "ca.pem", ca.pem),
+		CRLFile: writeTestFile(t, dir, "ca.crl", ca.crl(t, 21)),
+	})
+	if err != nil {
+		t.Fatalf("applyClientAuth: %v", err)
+	}
+	srv.StartTLS()
+	defer srv.Close()
+
+	get := func(cert *tls.Certificate) error {
+		tr := srv.Client().Transport.(*http.Transport).Clone()
+		tr.TLSClientConfig.Certificates = nil
+		if cert != nil {
+			tr.TLSClientConfig.Certificates = []tls.Certificate{*cert}
+		}
+		defer tr.CloseIdleConnections()
+		resp, err := (&http.Client{Transport: tr}).Get(srv.URL)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return nil
+	}
+
+	if err := get(&good); err != nil {
+		t.Errorf("expected trusted certificate to connect: %v", err)
+	}
+	if err := get(&revoked); err == nil {
+		t.Error("expected handshake failure for revoked certificate")
+	}
+	if err := get(nil); err == nil {
+		t.Error("expected handshake failure without a certificate")
+	}
+
+	if err := applyClientAuth(&tls.Config{}, HTTPServerClientAuthConfig{Mode: "bogus"}); err == nil {
+		t.Error("expected error for unknown mode")
+	}
+}
+
+func TestWithClientCertAuth(t *testing.T) {
+	cert := newTestCA(t, "ca").issue(t, 7, "svc", "svc.internal")
+	data := map[string]any{"_auth": map[string]any{"sub": "user-1"}, "body": "x"}
+
+	out := withClientCertAuth(data, NewClientCertIdentity(cert.Leaf))
+	auth := out["_auth"].(map[string]any)
+	if auth["sub"] != "user-1" {
+		t.Errorf("existing _auth claims lost: %v", auth)
+	}
+	cc := auth["client_cert"].(map[string]any)
+	if cc["cn"] != "svc" || cc["serial"] != "7" {
+		t.Errorf("unexpected client_cert map: %v", cc)
+	}
+	if _, ok := data["_auth"].(map[string]any)["client_cert"]; ok {
+		t.Error("input map was mutated")
+	}
+}
@@ -47,6 +47,15 @@ func (h *CommandHandler) SetRoutePipeline(routePath string, pipeline interfaces.
 	h.routePipelines[routePath] = pipeline
 }
 
+// RunRoutePipeline runs the pipeline attached to routeKey ("METHOD /path")
+// without an HTTP request, recording it with the execution tracker if set.
+func (h *CommandHandler) RunRoutePipeline(ctx context.Context, routeKey string, data map[string]any) (map[string]any, error) {
+	h.mu.RLock()
+	pipeline := h.routePipelines[routeKey]
+	h.mu.RUnlock()
+	return runRoutePipeline(ctx, routeKey, pipeline, h.executionTracker, data)
+}
+
 // Name returns the unique identifier for this module.
 func (h *CommandHandler) Name() string {
 	return h.name
@@ -133,9 +142,7 @@ func (h *CommandHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if exists {
 		result, err := fn(r.Context(), r)
 		if err != nil {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusInternalServerError)
-			_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			writeErrorProblem(w, r, err, ProblemDetailFull)
 			return
 		}
 		w.Header().Set("Content-Type", "application/json")
@@ -157,7 +164,10 @@ func (h *CommandHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 		// Buffer the request body so it can be read by both trigger data parsing
 		// and downstream delegate steps that forward the original request.
-		bodyBytes, _ := io.ReadAll(r.Body)
+		bodyBytes, readErr := io.ReadAll(r.Body)
+		if handleRequestTooLarge(w, readErr) {
+			return
+		}
 		if len(bodyBytes) > 0 {
 			var body map[string]any
 			if json.Unmarshal(bodyBytes, &body) == nil {
@@ -182,18 +192,21 @@ func (h *CommandHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			if concretePipeline.RoutePattern != "" {
 				concretePipeline.Metadata["_route_pattern"] = concretePipeline.RoutePattern
 			}
+			ctx := r.Context()
+			if DryRunRequested(r) {
+				ctx = WithDryRun(ctx)
+				w.Header().Set(DryRunHeader, "true")
+			}
 			var pc *PipelineContext
 			var err error
 			if h.executionTracker != nil {
-				pc, err = h.executionTracker.TrackPipelineExecution(r.Context(), concretePipeline, triggerData, r)
+				pc, err = h.executionTracker.TrackPipelineExecution(ctx, concretePipeline, triggerData, r)
 			} else {
-				pc, err = concretePipeline.Execute(r.Context(), triggerData)
+				pc, err = concretePipeline.Execute(ctx, triggerData)
 			}
 			if err != nil {
 				if pc == nil || pc.Metadata["_response_handled"] != true {
-					w.Header().Set("Content-Type", "application/json")
-					w.WriteHeader(http.StatusInternalServerError)
-					_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+					writeErrorProblem(w, r, err, concretePipeline.ProblemDetail)
 				}
 				return
 			}
@@ -209,9 +222,7 @@ func (h *CommandHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			// Fallback for non-*Pipeline implementations: use the PipelineRunner interface.
 			result, err := pipeline.Run(r.Context(), triggerData)
 			if err != nil {
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusInternalServerError)
-				_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+				writeErrorProblem(w, r, err, ProblemDetailFull)
 				return
 			}
 			// Allow the runner to signal that it has already written the response.
@@ -232,9 +243,9 @@ func (h *CommandHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusNotFound)
-	_ = json.NewEncoder(w).Encode(map[string]string{"error": "unknown command: " + commandName})
+	p := NewProblem(ProblemNotFound, "unknown command: "+commandName)
+	p.Instance = r.URL.Path
+	WriteProblem(w, p, ProblemDetailFull)
 }
 
 // ProvidesServices returns a list of services provided by this module.
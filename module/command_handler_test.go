@@ -123,12 +123,12 @@ func TestCommandHandler_DispatchError(t *testing.T) {
 		t.Errorf("expected 500, got %d", rr.Code)
 	}
 
-	var result map[string]string
+	var result Problem
 	if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
 		t.Fatalf("failed to decode response: %v", err)
 	}
-	if result["error"] != "command failed" {
-		t.Errorf("expected error 'command failed', got %v", result)
+	if result.Code != "pipeline_error" || result.Detail != "command failed" {
+		t.Errorf("expected error 'command failed', got %+v", result)
 	}
 }
 
@@ -266,12 +266,12 @@ func TestCommandHandler_RoutePipeline_MockRunner_Error(t *testing.T) {
 	if rr.Code != http.StatusInternalServerError {
 		t.Errorf("expected 500, got %d", rr.Code)
 	}
-	var got map[string]string
+	var got Problem
 	if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
 		t.Fatalf("failed to decode error response: %v", err)
 	}
-	if got["error"] != "runner failed" {
-		t.Errorf("expected error=runner failed, got %v", got)
+	if got.Detail != "runner failed" {
+		t.Errorf("expected detail=runner failed, got %+v", got)
 	}
 }
 
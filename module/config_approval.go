@@ -0,0 +1,564 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/audit"
+	"github.com/GoCodeAlone/workflow/config"
+	"github.com/google/uuid"
+)
+
+// DefaultConfigApprovalTTL is how long a staged config change waits for
+// approval when the policy does not set a TTL.
+const DefaultConfigApprovalTTL = 24 * time.Hour
+
+// Pending config change statuses.
+const (
+	PendingConfigStatusPending  = "pending"
+	PendingConfigStatusApproved = "approved"
+	PendingConfigStatusRejected = "rejected"
+	PendingConfigStatusExpired  = "expired"
+	PendingConfigStatusFailed   = "failed"
+)
+
+// ConfigApprovalPolicy decides whether config changes submitted through
+// PUT /api/workflow/config are applied directly or staged for a second
+// person's approval (the two-person rule).
+type ConfigApprovalPolicy struct {
+	// RequireApproval stages every config change as a pending revision.
+	RequireApproval bool
+	// Environment names the environment the policy was resolved for; it is
+	// recorded on staged changes and audit entries.
+	Environment string
+	// Approvers restricts who may approve. When empty, any authenticated user
+	// other than the author may approve.
+	Approvers []string
+	// TTL is how long a staged change stays approvable. Defaults to
+	// DefaultConfigApprovalTTL.
+	TTL time.Duration
+}
+
+// canApprove reports whether user is allowed to approve under the policy.
+func (p ConfigApprovalPolicy) canApprove(user string) bool {
+	if len(p.Approvers) == 0 {
+		return true
+	}
+	for _, a := range p.Approvers {
+		if strings.EqualFold(a, user) {
+			return true
+		}
+	}
+	return false
+}
+
+// PendingConfigChange is a config revision staged for approval.
+type PendingConfigChange struct {
+	ID          string                 `json:"id"`
+	Environment string                 `json:"environment,omitempty"`
+	Author      string                 `json:"author"`
+	Status      string                 `json:"status"`
+	CreatedAt   time.Time              `json:"createdAt"`
+	ExpiresAt   time.Time              `json:"expiresAt"`
+	ApprovedBy  string                 `json:"approvedBy,omitempty"`
+	DecidedBy   string                 `json:"decidedBy,omitempty"`
+	DecidedAt   *time.Time             `json:"decidedAt,omitempty"`
+	Error       string                 `json:"error,omitempty"`
+	Config      *config.WorkflowConfig `json:"config,omitempty"`
+}
+
+// ConfigApprovalNotifier is called when a change is staged so the configured
+// approvers can be told about it. It must not block for long.
+type ConfigApprovalNotifier func(ctx context.Context, change *PendingConfigChange, approvers []string)
+
+// PendingConfigPersister saves staged config changes so they survive a
+// restart. *V1Store implements it.
+type PendingConfigPersister interface {
+	// SavePendingConfigChange inserts c or replaces the change with its ID.
+	SavePendingConfigChange(c PendingConfigChange) error
+	// ListPendingConfigChanges returns every saved change.
+	ListPendingConfigChanges() ([]PendingConfigChange, error)
+}
+
+// pendingConfigStore holds staged config changes in memory and writes every
+// change through to persist, when set.
+type pendingConfigStore struct {
+	mu      sync.Mutex
+	changes map[string]*PendingConfigChange
+	persist PendingConfigPersister
+}
+
+func newPendingConfigStore() *pendingConfigStore {
+	return &pendingConfigStore{changes: make(map[string]*PendingConfigChange)}
+}
+
+// load replaces the held changes with those saved in persist and writes
+// later changes through to it.
+func (s *pendingConfigStore) load(persist PendingConfigPersister) error {
+	saved, err := persist.ListPendingConfigChanges()
+	if err != nil {
+		return fmt.Errorf("load pending config changes: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.changes = make(map[string]*PendingConfigChange, len(saved))
+	for i := range saved {
+		s.changes[saved[i].ID] = &saved[i]
+	}
+	s.persist = persist
+	return nil
+}
+
+// save writes c through to the persister. The caller holds s.mu.
+func (s *pendingConfigStore) save(c *PendingConfigChange) error {
+	if s.persist == nil {
+		return nil
+	}
+	if err := s.persist.SavePendingConfigChange(*c); err != nil {
+		return fmt.Errorf("save pending change %q: %w", c.ID, err)
+	}
+	return nil
+}
+
+func (s *pendingConfigStore) add(c *PendingConfigChange) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.save(c); err != nil {
+		return err
+	}
+	s.changes[c.ID] = c
+	return nil
+}
+
+// expire marks overdue pending changes as expired and returns copies of them.
+// A change that cannot be saved stays pending until the next call.
+func (s *pendingConfigStore) expire(now time.Time) []PendingConfigChange {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var expired []PendingConfigChange
+	for _, c := range s.changes {
+		if c.Status == PendingConfigStatusPending && !now.Before(c.ExpiresAt) {
+			previous := *c
+			c.Status = PendingConfigStatusExpired
+			decided := now
+			c.DecidedAt = &decided
+			if s.save(c) != nil {
+				*c = previous
+				continue
+			}
+			expired = append(expired, *c)
+		}
+	}
+	return expired
+}
+
+func (s *pendingConfigStore) get(id string) (PendingConfigChange, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.changes[id]
+	if !ok {
+		return PendingConfigChange{}, false
+	}
+	return *c, true
+}
+
+func (s *pendingConfigStore) list() []PendingConfigChange {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]PendingConfigChange, 0, len(s.changes))
+	for _, c := range s.changes {
+		out = append(out, *c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out
+}
+
+var errPendingConfigNotPending = errors.New("change is no longer pending")
+
+// decide moves a pending change to status, recording who decided. The change
+// is left pending if the decision cannot be saved.
+func (s *pendingConfigStore) decide(id, status, user, detail string, now time.Time) (PendingConfigChange, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.changes[id]
+	if !ok {
+		return PendingConfigChange{}, fmt.Errorf("pending change %q not found", id)
+	}
+	if c.Status != PendingConfigStatusPending {
+		return *c, errPendingConfigNotPending
+	}
+	previous := *c
+	c.Status = status
+	c.DecidedBy = user
+	if status == PendingConfigStatusApproved {
+		c.ApprovedBy = user
+	}
+	c.Error = detail
+	decided := now
+	c.DecidedAt = &decided
+	if err := s.save(c); err != nil {
+		*c = previous
+		return previous, err
+	}
+	return *c, nil
+}
+
+// fail marks an approved change whose reload failed. Failing to save the
+// status only leaves the stored change marked approved.
+func (s *pendingConfigStore) fail(id, detail string) PendingConfigChange {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.changes[id]
+	if !ok {
+		return PendingConfigChange{}
+	}
+	c.Status = PendingConfigStatusFailed
+	c.Error = detail
+	_ = s.save(c)
+	return *c
+}
+
+// ConfigChangeStagedError is returned by SubmitConfigChange when the change
+// was staged for approval instead of applied.
+type ConfigChangeStagedError struct {
+	Change PendingConfigChange
+}
+
+func (e *ConfigChangeStagedError) Error() string {
+	return fmt.Sprintf("config change %s staged for approval", e.Change.ID)
+}
+
+// SetPendingConfigStore persists staged config changes in store and loads the
+// changes it already holds, so pending approvals survive a restart. Without
+// it, staged changes are held in memory only.
+func (h *WorkflowUIHandler) SetPendingConfigStore(store PendingConfigPersister) error {
+	return h.pending.load(store)
+}
+
+// SetApprovalPolicyFunc sets the callback that resolves the approval policy.
+// It is evaluated on every config change so policy edits (for example in the
+// environment store) take effect without a restart.
+func (h *WorkflowUIHandler) SetApprovalPolicyFunc(fn func(context.Context) ConfigApprovalPolicy) {
+	h.approvalPolicy = fn
+}
+
+// SetApprovalNotifier sets the callback used to notify approvers of staged
+// changes.
+func (h *WorkflowUIHandler) SetApprovalNotifier(fn ConfigApprovalNotifier) {
+	h.approvalNotifier = fn
+}
+
+// SetAuditStore sets where config staging and approval actions are recorded.
+func (h *WorkflowUIHandler) SetAuditStore(store audit.Store) {
+	h.auditStore = store
+}
+
+func (h *WorkflowUIHandler) resolveApprovalPolicy(ctx context.Context) ConfigApprovalPolicy {
+	if h.approvalPolicy == nil {
+		return ConfigApprovalPolicy{}
+	}
+	policy := h.approvalPolicy(ctx)
+	if policy.TTL <= 0 {
+		policy.TTL = DefaultConfigApprovalTTL
+	}
+	return policy
+}
+
+func (h *WorkflowUIHandler) clock() time.Time {
+	if h.now != nil {
+		return h.now()
+	}
+	return time.Now()
+}
+
+// recordConfigAudit writes an audit entry for a pending change. Failures are
+// reported in the response by the caller only for the initial stage; later
+// actions have already taken effect.
+func (h *WorkflowUIHandler) recordConfigAudit(ctx context.Context, action, actor string, c PendingConfigChange, success bool, detail string) error {
+	if h.auditStore == nil {
+		return nil
+	}
+	meta := map[string]any{
+		"change_id": c.ID,
+		"author":    c.Author,
+	}
+	if c.ApprovedBy != "" {
+		meta["approver"] = c.ApprovedBy
+	}
+	if c.DecidedBy != "" && c.DecidedBy != c.ApprovedBy {
+		meta["decided_by"] = c.DecidedBy
+	}
+	if c.Environment != "" {
+		meta["environment"] = c.Environment
+	}
+	return h.auditStore.Record(ctx, audit.Event{
+		Timestamp: h.clock().UTC(),
+		Type:      audit.EventConfigChange,
+		Action:    action,
+		Actor:     actor,
+		Resource:  "workflow-config",
+		Detail:    detail,
+		Success:   success,
+		Metadata:  meta,
+	})
+}
+
+// expirePendingConfigs expires overdue changes and audits each one.
+func (h *WorkflowUIHandler) expirePendingConfigs(ctx context.Context) {
+	for _, c := range h.pending.expire(h.clock()) {
+		_ = h.recordConfigAudit(ctx, "config.expire", "system", c, true, "pending change expired without approval")
+	}
+}
+
+// stageConfigChange records cfg as a pending change by the request's user.
+func (h *WorkflowUIHandler) stageConfigChange(w http.ResponseWriter, r *http.Request, cfg *config.WorkflowConfig, policy ConfigApprovalPolicy) {
+	author := extractTriggeredBy(r)
+	if author == "" {
+		writeJSONError(w, http.StatusUnauthorized, "config changes require approval; an authenticated user is required to stage them")
+		return
+	}
+	change, err := h.stage(r.Context(), author, cfg, policy)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"status":    "pending_approval",
+		"id":        change.ID,
+		"author":    change.Author,
+		"expiresAt": change.ExpiresAt,
+	})
+}
+
+// stage records cfg as a pending change by author and notifies the approvers.
+func (h *WorkflowUIHandler) stage(ctx context.Context, author string, cfg *config.WorkflowConfig, policy ConfigApprovalPolicy) (*PendingConfigChange, error) {
+	now := h.clock().UTC()
+	change := &PendingConfigChange{
+		ID:          uuid.NewString(),
+		Environment: policy.Environment,
+		Author:      author,
+		Status:      PendingConfigStatusPending,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(policy.TTL),
+		Config:      cfg,
+	}
+	// The audit record is the evidence the two-person rule was followed, so
+	// refuse to stage a change that cannot be audited.
+	if err := h.recordConfigAudit(ctx, "config.stage", author, *change, true, "config change staged for approval"); err != nil {
+		return nil, fmt.Errorf("failed to record audit entry: %w", err)
+	}
+	if err := h.pending.add(change); err != nil {
+		return nil, err
+	}
+	if h.approvalNotifier != nil {
+		h.approvalNotifier(ctx, change, policy.Approvers)
+	}
+	return change, nil
+}
+
+// SubmitConfigChange passes a reload that does not come through this
+// handler, such as a V1 deploy of the system workflow, through the approval
+// gate. When the policy requires approval, cfg is staged by author and a
+// *ConfigChangeStagedError is returned; otherwise the engine is reloaded with
+// cfg, which is recorded in the config history under source.
+func (h *WorkflowUIHandler) SubmitConfigChange(ctx context.Context, author string, cfg *config.WorkflowConfig, source string) error {
+	if policy := h.resolveApprovalPolicy(ctx); policy.RequireApproval {
+		if author == "" {
+			return errors.New("config changes require approval; an authenticated user is required to stage them")
+		}
+		change, err := h.stage(ctx, author, cfg, policy)
+		if err != nil {
+			return err
+		}
+		return &ConfigChangeStagedError{Change: *change}
+	}
+	if h.reloadFn == nil {
+		return errors.New("reload not configured")
+	}
+	if err := h.reloadFn(cfg); err != nil {
+		return err
+	}
+	h.mu.Lock()
+	h.config = cfg
+	h.mu.Unlock()
+	_, _ = h.recordConfigVersion(ctx, ConfigVersion{Author: author, Source: source, Config: cfg})
+	return nil
+}
+
+// pendingConfigView is a pending change with its diff against the running
+// config.
+type pendingConfigView struct {
+	PendingConfigChange
+	Diff *config.DiffSummary `json:"diff,omitempty"`
+}
+
+func (h *WorkflowUIHandler) handleListPendingConfigs(w http.ResponseWriter, r *http.Request) {
+	h.expirePendingConfigs(r.Context())
+	status := r.URL.Query().Get("status")
+	changes := make([]PendingConfigChange, 0)
+	for _, c := range h.pending.list() {
+		if status != "" && c.Status != status {
+			continue
+		}
+		c.Config = nil // the list is a summary; fetch one change for its config
+		changes = append(changes, c)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(changes)
+}
+
+func (h *WorkflowUIHandler) handleGetPendingConfig(w http.ResponseWriter, r *http.Request, id string) {
+	h.expirePendingConfigs(r.Context())
+	c, ok := h.pending.get(id)
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, fmt.Sprintf("pending change %q not found", id))
+		return
+	}
+	h.mu.RLock()
+	running := h.config
+	h.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(pendingConfigView{
+		PendingConfigChange: c,
+		Diff:                config.SummarizeDiff(running, c.Config),
+	})
+}
+
+func (h *WorkflowUIHandler) handleApprovePendingConfig(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+	h.expirePendingConfigs(ctx)
+
+	approver := extractTriggeredBy(r)
+	if approver == "" {
+		writeJSONError(w, http.StatusUnauthorized, "an authenticated user is required to approve config changes")
+		return
+	}
+	c, ok := h.pending.get(id)
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, fmt.Sprintf("pending change %q not found", id))
+		return
+	}
+	if c.Status != PendingConfigStatusPending {
+		writeJSONError(w, http.StatusConflict, fmt.Sprintf("pending change %q is %s", id, c.Status))
+		return
+	}
+	if strings.EqualFold(approver, c.Author) {
+		_ = h.recordConfigAudit(ctx, "config.approve", approver, c, false, "author cannot approve their own change")
+		writeJSONError(w, http.StatusForbidden, "the author of a change cannot approve it")
+		return
+	}
+	if policy := h.resolveApprovalPolicy(ctx); !policy.canApprove(approver) {
+		_ = h.recordConfigAudit(ctx, "config.approve", approver, c, false, "user is not a configured approver")
+		writeJSONError(w, http.StatusForbidden, fmt.Sprintf("%s is not a configured approver", approver))
+		return
+	}
+
+	// Claim the change before applying it so two concurrent approvals cannot
+	// both reload.
+	c, err := h.pending.decide(id, PendingConfigStatusApproved, approver, "", h.clock().UTC())
+	if errors.Is(err, errPendingConfigNotPending) {
+		writeJSONError(w, http.StatusConflict, fmt.Sprintf("pending change %q is %s", id, c.Status))
+		return
+	}
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.mu.Lock()
+	previous := h.config
+	h.config = c.Config
+	h.mu.Unlock()
+
+	if h.reloadFn != nil {
+		if err := h.reloadFn(c.Config); err != nil {
+			h.mu.Lock()
+			h.config = previous
+			h.mu.Unlock()
+			c = h.pending.fail(id, err.Error())
+			_ = h.recordConfigAudit(ctx, "config.approve", approver, c, false, "reload failed: "+err.Error())
+			writeJSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	_, _ = h.recordConfigVersion(ctx, ConfigVersion{Author: c.Author, ApprovedBy: c.ApprovedBy, Source: ConfigSourceApproval, Config: c.Config})
+	_ = h.recordConfigAudit(ctx, "config.approve", approver, c, true, "config change approved and applied")
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"status":     "reloaded",
+		"id":         c.ID,
+		"author":     c.Author,
+		"approvedBy": c.ApprovedBy,
+	})
+}
+
+func (h *WorkflowUIHandler) handleRejectPendingConfig(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+	h.expirePendingConfigs(ctx)
+
+	user := extractTriggeredBy(r)
+	if user == "" {
+		writeJSONError(w, http.StatusUnauthorized, "an authenticated user is required to reject config changes")
+		return
+	}
+	if _, ok := h.pending.get(id); !ok {
+		writeJSONError(w, http.StatusNotFound, fmt.Sprintf("pending change %q not found", id))
+		return
+	}
+	c, err := h.pending.decide(id, PendingConfigStatusRejected, user, "", h.clock().UTC())
+	if errors.Is(err, errPendingConfigNotPending) {
+		writeJSONError(w, http.StatusConflict, fmt.Sprintf("pending change %q is %s", id, c.Status))
+		return
+	}
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	_ = h.recordConfigAudit(ctx, "config.reject", user, c, true, "pending change rejected")
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"status": PendingConfigStatusRejected, "id": c.ID})
+}
+
+// servePendingConfig dispatches .../config/pending[/{id}[/approve]] requests.
+// It reports false when the path is not a pending-config path.
+func (h *WorkflowUIHandler) servePendingConfig(w http.ResponseWriter, r *http.Request) bool {
+	_, rest, ok := strings.Cut(r.URL.Path, "/config/pending")
+	if !ok {
+		return false
+	}
+	parts := strings.Split(strings.Trim(rest, "/"), "/")
+	switch {
+	case rest == "" || rest == "/":
+		if r.Method != http.MethodGet {
+			return false
+		}
+		h.handleListPendingConfigs(w, r)
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		h.handleGetPendingConfig(w, r, parts[0])
+	case len(parts) == 1 && r.Method == http.MethodDelete:
+		h.handleRejectPendingConfig(w, r, parts[0])
+	case len(parts) == 2 && parts[1] == "approve" && r.Method == http.MethodPost:
+		h.handleApprovePendingConfig(w, r, parts[0])
+	default:
+		return false
+	}
+	return true
+}
+
+func writeJSONError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
@@ -0,0 +1,330 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/config"
+)
+
+func (s *recordingAuditStore) actions() []string {
+	out := make([]string, 0, len(s.events))
+	for _, e := range s.events {
+		out = append(out, e.Action)
+	}
+	return out
+}
+
+// newApprovalHandler returns a handler that requires approval, with reloads
+// recorded in *reloaded.
+func newApprovalHandler(t *testing.T, policy ConfigApprovalPolicy) (*WorkflowUIHandler, *http.ServeMux, *recordingAuditStore, *[]*config.WorkflowConfig) {
+	t.Helper()
+	policy.RequireApproval = true
+	running := &config.WorkflowConfig{Modules: []config.ModuleConfig{{Name: "server", Type: "http.server"}}}
+	h := NewWorkflowUIHandler(running)
+	var reloaded []*config.WorkflowConfig
+	h.SetReloadFunc(func(cfg *config.WorkflowConfig) error {
+		reloaded = append(reloaded, cfg)
+		return nil
+	})
+	h.SetApprovalPolicyFunc(func(context.Context) ConfigApprovalPolicy { return policy })
+	store := &recordingAuditStore{}
+	h.SetAuditStore(store)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	return h, mux, store, &reloaded
+}
+
+func approvalRequest(method, path, user, body string) *http.Request {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if user != "" {
+		req = req.WithContext(context.WithValue(req.Context(), authClaimsContextKey, map[string]any{"email": user}))
+	}
+	return req
+}
+
+const stagedConfigJSON = `{"modules":[{"name":"server","type":"http.server"},{"name":"cache","type":"cache.redis"}]}`
+
+func stageConfig(t *testing.T, handler http.Handler, user string) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, approvalRequest(http.MethodPut, "/api/workflow/config", user, stagedConfigJSON))
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("stage: expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp map[string]any
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp["status"] != "pending_approval" {
+		t.Fatalf("unexpected stage response: %v", resp)
+	}
+	return resp["id"].(string)
+}
+
+func TestConfigApproval_StageAndApprove(t *testing.T) {
+	h, mux, store, reloaded := newApprovalHandler(t, ConfigApprovalPolicy{Environment: "production"})
+
+	id := stageConfig(t, mux, "alice@example.com")
+	h.mu.RLock()
+	if len(h.config.Modules) != 1 {
+		t.Error("staged change must not replace the running config")
+	}
+	h.mu.RUnlock()
+
+	// The pending change is listed and diffable against the running config.
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, approvalRequest(http.MethodGet, "/api/workflow/config/pending/"+id, "bob@example.com", ""))
+	var view struct {
+		PendingConfigChange
+		Diff *config.DiffSummary `json:"diff"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&view); err != nil {
+		t.Fatal(err)
+	}
+	if view.Author != "alice@example.com" || view.Environment != "production" || view.Status != PendingConfigStatusPending {
+		t.Errorf("unexpected pending change: %+v", view.PendingConfigChange)
+	}
+	if view.Diff == nil || len(view.Diff.ModulesAdded) != 1 || view.Diff.ModulesAdded[0] != "cache" {
+		t.Errorf("unexpected diff: %+v", view.Diff)
+	}
+
+	// The author cannot self-approve.
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, approvalRequest(http.MethodPost, "/api/workflow/config/pending/"+id+"/approve", "Alice@example.com", ""))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("self-approve: expected 403, got %d", w.Code)
+	}
+	if len(*reloaded) != 0 {
+		t.Fatal("self-approval must not reload")
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, approvalRequest(http.MethodPost, "/api/workflow/config/pending/"+id+"/approve", "bob@example.com", ""))
+	if w.Code != http.StatusOK {
+		t.Fatalf("approve: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(*reloaded) != 1 || len((*reloaded)[0].Modules) != 2 {
+		t.Fatalf("expected one reload with the staged config, got %v", *reloaded)
+	}
+
+	// A second approval is a conflict.
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, approvalRequest(http.MethodPost, "/api/workflow/config/pending/"+id+"/approve", "carol@example.com", ""))
+	if w.Code != http.StatusConflict {
+		t.Errorf("re-approve: expected 409, got %d", w.Code)
+	}
+
+	want := []string{"config.stage", "config.approve", "config.approve"}
+	if got := store.actions(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("audit actions = %v, want %v", got, want)
+	}
+	approved := store.events[2]
+	if !approved.Success || approved.Actor != "bob@example.com" ||
+		approved.Metadata["author"] != "alice@example.com" || approved.Metadata["approver"] != "bob@example.com" {
+		t.Errorf("approval audit entry missing actors: %+v", approved)
+	}
+	if store.events[1].Success {
+		t.Error("self-approval attempt should be audited as unsuccessful")
+	}
+}
+
+func TestConfigApproval_RequiresAuthenticatedAuthor(t *testing.T) {
+	_, mux, _, _ := newApprovalHandler(t, ConfigApprovalPolicy{})
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, approvalRequest(http.MethodPut, "/api/workflow/config", "", stagedConfigJSON))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", w.Code)
+	}
+}
+
+func TestConfigApproval_RefusesUnauditableStage(t *testing.T) {
+	h, mux, store, _ := newApprovalHandler(t, ConfigApprovalPolicy{})
+	store.err = errors.New("disk full")
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, approvalRequest(http.MethodPut, "/api/workflow/config", "alice", stagedConfigJSON))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", w.Code)
+	}
+	if len(h.pending.list()) != 0 {
+		t.Error("change must not be staged without an audit record")
+	}
+}
+
+func TestConfigApproval_ApproverListAndExpiry(t *testing.T) {
+	h, mux, store, reloaded := newApprovalHandler(t, ConfigApprovalPolicy{Approvers: []string{"ops-lead"}, TTL: time.Hour})
+	now := time.Now()
+	h.now = func() time.Time { return now }
+
+	id := stageConfig(t, mux, "alice")
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, approvalRequest(http.MethodPost, "/api/workflow/config/pending/"+id+"/approve", "bob", ""))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("non-approver: expected 403, got %d", w.Code)
+	}
+
+	now = now.Add(2 * time.Hour)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, approvalRequest(http.MethodGet, "/api/workflow/config/pending?status=expired", "bob", ""))
+	var listed []PendingConfigChange
+	if err := json.NewDecoder(w.Body).Decode(&listed); err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != 1 || listed[0].ID != id || listed[0].Config != nil {
+		t.Fatalf("expected the expired change without its config, got %+v", listed)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, approvalRequest(http.MethodPost, "/api/workflow/config/pending/"+id+"/approve", "ops-lead", ""))
+	if w.Code != http.StatusConflict {
+		t.Errorf("approve expired: expected 409, got %d", w.Code)
+	}
+	if len(*reloaded) != 0 {
+		t.Error("expired change must not reload")
+	}
+	if got := store.actions(); got[len(got)-1] != "config.expire" {
+		t.Errorf("expected expiry to be audited, got %v", got)
+	}
+}
+
+func TestConfigApproval_RejectAndFailedReload(t *testing.T) {
+	h, mux, store, _ := newApprovalHandler(t, ConfigApprovalPolicy{})
+
+	id := stageConfig(t, mux, "alice")
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, approvalRequest(http.MethodDelete, "/api/workflow/config/pending/"+id, "bob", ""))
+	if w.Code != http.StatusOK {
+		t.Fatalf("reject: expected 200, got %d", w.Code)
+	}
+	if c, _ := h.pending.get(id); c.Status != PendingConfigStatusRejected || c.DecidedBy != "bob" {
+		t.Errorf("unexpected rejected change: %+v", c)
+	}
+
+	h.SetReloadFunc(func(*config.WorkflowConfig) error { return errors.New("build failed") })
+	id = stageConfig(t, mux, "alice")
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, approvalRequest(http.MethodPost, "/api/workflow/config/pending/"+id+"/approve", "bob", ""))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("failed reload: expected 500, got %d", w.Code)
+	}
+	h.mu.RLock()
+	if len(h.config.Modules) != 1 {
+		t.Error("running config should be restored after a failed reload")
+	}
+	h.mu.RUnlock()
+	if c, _ := h.pending.get(id); c.Status != PendingConfigStatusFailed || c.ApprovedBy != "bob" {
+		t.Errorf("unexpected failed change: %+v", c)
+	}
+	last := store.events[len(store.events)-1]
+	if last.Success || last.Action != "config.approve" {
+		t.Errorf("failed reload should be audited as unsuccessful: %+v", last)
+	}
+}
+
+func TestConfigApproval_ServeHTTPDispatch(t *testing.T) {
+	h, _, _, reloaded := newApprovalHandler(t, ConfigApprovalPolicy{})
+	id := stageConfig(t, h, "alice")
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, approvalRequest(http.MethodGet, "/api/v1/admin/engine/config/pending", "bob", ""))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), id) {
+		t.Fatalf("list via ServeHTTP: %d %s", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, approvalRequest(http.MethodPost, "/api/v1/admin/engine/config/pending/"+id+"/approve", "bob", ""))
+	if w.Code != http.StatusOK || len(*reloaded) != 1 {
+		t.Fatalf("approve via ServeHTTP: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestConfigApproval_DisabledAppliesDirectly(t *testing.T) {
+	h := NewWorkflowUIHandler(nil)
+	h.SetApprovalPolicyFunc(func(context.Context) ConfigApprovalPolicy { return ConfigApprovalPolicy{} })
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, approvalRequest(http.MethodPut, "/api/workflow/config", "", stagedConfigJSON))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if len(h.config.Modules) != 2 {
+		t.Error("expected config to be applied directly")
+	}
+}
+
+func TestConfigApproval_PendingChangesSurviveRestart(t *testing.T) {
+	store := setupTestStore(t)
+	h, mux, _, _ := newApprovalHandler(t, ConfigApprovalPolicy{Environment: "production"})
+	if err := h.SetPendingConfigStore(store); err != nil {
+		t.Fatal(err)
+	}
+	id := stageConfig(t, mux, "alice@example.com")
+
+	// A new handler backed by the same store sees the change and can approve it.
+	restarted, mux2, _, reloaded := newApprovalHandler(t, ConfigApprovalPolicy{Environment: "production"})
+	if err := restarted.SetPendingConfigStore(store); err != nil {
+		t.Fatal(err)
+	}
+	c, ok := restarted.pending.get(id)
+	if !ok || c.Status != PendingConfigStatusPending || c.Author != "alice@example.com" || len(c.Config.Modules) != 2 {
+		t.Fatalf("staged change not restored: %+v", c)
+	}
+	w := httptest.NewRecorder()
+	mux2.ServeHTTP(w, approvalRequest(http.MethodPost, "/api/workflow/config/pending/"+id+"/approve", "bob@example.com", ""))
+	if w.Code != http.StatusOK {
+		t.Fatalf("approve: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(*reloaded) != 1 {
+		t.Fatalf("expected one reload, got %d", len(*reloaded))
+	}
+
+	saved, err := store.ListPendingConfigChanges()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 1 || saved[0].Status != PendingConfigStatusApproved || saved[0].ApprovedBy != "bob@example.com" || saved[0].DecidedAt == nil {
+		t.Errorf("approval not persisted: %+v", saved)
+	}
+}
+
+func TestConfigApproval_V1DeployIsStaged(t *testing.T) {
+	h, _, _, reloaded := newApprovalHandler(t, ConfigApprovalPolicy{})
+	v1, store, secret := setupTestHandler(t)
+	v1.SetReloadFunc(func(ctx context.Context, author, configYAML string) error {
+		cfg, err := config.LoadFromString(configYAML)
+		if err != nil {
+			return err
+		}
+		return h.SubmitConfigChange(ctx, author, cfg, ConfigSourceReload)
+	})
+	if _, _, _, _, err := store.EnsureSystemHierarchy("1", "modules:\n  - name: cache\n    type: cache.redis\n"); err != nil {
+		t.Fatal(err)
+	}
+	sysWf, _ := store.GetSystemWorkflow()
+
+	rr := doRequest(v1, http.MethodPost, "/api/v1/workflows/"+sysWf.ID+"/deploy", "", generateTestToken(secret, "1", "admin@test.com", "admin"))
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("deploy: expected 202, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp map[string]any
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp["status"] != "pending_approval" || resp["author"] != "admin@test.com" {
+		t.Errorf("unexpected deploy response: %v", resp)
+	}
+	if len(*reloaded) != 0 {
+		t.Error("a deploy that requires approval must not reload")
+	}
+	if _, ok := h.pending.get(resp["id"].(string)); !ok {
+		t.Error("deploy was not staged")
+	}
+	if wf, _ := store.GetWorkflow(sysWf.ID); wf.Status != sysWf.Status {
+		t.Errorf("a staged deploy must not change the workflow status, got %q", wf.Status)
+	}
+}
@@ -0,0 +1,291 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/audit"
+	"github.com/GoCodeAlone/workflow/config"
+)
+
+// Config version sources: what loaded a config into the engine.
+const (
+	ConfigSourceStartup  = "startup"
+	ConfigSourceReload   = "reload"
+	ConfigSourceApproval = "approval"
+	ConfigSourceRollback = "rollback"
+	ConfigSourceWatcher  = "watcher"
+)
+
+// ErrConfigVersionNotFound is returned by ConfigHistoryStore.Get for a
+// version that was never recorded.
+var ErrConfigVersionNotFound = errors.New("config version not found")
+
+// ConfigVersion is a config the engine was successfully loaded with. Versions
+// are numbered from 1 in the order they were recorded and never change.
+type ConfigVersion struct {
+	Version    int                    `json:"version"`
+	Author     string                 `json:"author,omitempty"`
+	ApprovedBy string                 `json:"approvedBy,omitempty"`
+	Source     string                 `json:"source"`
+	RollbackOf int                    `json:"rollbackOf,omitempty"`
+	Hash       string                 `json:"hash,omitempty"`
+	CreatedAt  time.Time              `json:"createdAt"`
+	Config     *config.WorkflowConfig `json:"config,omitempty"`
+}
+
+// ConfigHistoryStore is an append-only record of the configs the engine has
+// run, used to roll back to a known-good config.
+type ConfigHistoryStore interface {
+	// Append records v, assigning its Version, and returns the stored entry.
+	Append(ctx context.Context, v ConfigVersion) (ConfigVersion, error)
+	// List returns every version, newest first.
+	List(ctx context.Context) ([]ConfigVersion, error)
+	// Get returns one version, or ErrConfigVersionNotFound.
+	Get(ctx context.Context, version int) (ConfigVersion, error)
+}
+
+// MemoryConfigHistory is a ConfigHistoryStore held in memory. The history
+// starts over when the process restarts.
+type MemoryConfigHistory struct {
+	mu       sync.RWMutex
+	versions []ConfigVersion
+}
+
+// NewMemoryConfigHistory returns an empty in-memory config history.
+func NewMemoryConfigHistory() *MemoryConfigHistory {
+	return &MemoryConfigHistory{}
+}
+
+// Append implements ConfigHistoryStore.
+func (s *MemoryConfigHistory) Append(_ context.Context, v ConfigVersion) (ConfigVersion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v.Version = len(s.versions) + 1
+	s.versions = append(s.versions, v)
+	return v, nil
+}
+
+// List implements ConfigHistoryStore.
+func (s *MemoryConfigHistory) List(_ context.Context) ([]ConfigVersion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := slices.Clone(s.versions)
+	slices.Reverse(out)
+	return out, nil
+}
+
+// Get implements ConfigHistoryStore.
+func (s *MemoryConfigHistory) Get(_ context.Context, version int) (ConfigVersion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if version < 1 || version > len(s.versions) {
+		return ConfigVersion{}, ErrConfigVersionNotFound
+	}
+	return s.versions[version-1], nil
+}
+
+// SetConfigHistory sets where successfully loaded configs are recorded.
+// The handler starts with a MemoryConfigHistory.
+func (h *WorkflowUIHandler) SetConfigHistory(store ConfigHistoryStore) {
+	h.history = store
+}
+
+// RecordConfigVersion adds cfg to the config history after the engine was
+// successfully loaded with it. The server calls it for loads that do not go
+// through the handler, such as startup and the config file watcher.
+func (h *WorkflowUIHandler) RecordConfigVersion(ctx context.Context, cfg *config.WorkflowConfig, author, source string) (ConfigVersion, error) {
+	return h.recordConfigVersion(ctx, ConfigVersion{Author: author, Source: source, Config: cfg})
+}
+
+func (h *WorkflowUIHandler) recordConfigVersion(ctx context.Context, v ConfigVersion) (ConfigVersion, error) {
+	if h.history == nil {
+		return ConfigVersion{}, nil
+	}
+	if v.Config != nil {
+		if hash, err := config.HashConfig(v.Config); err == nil {
+			v.Hash = hash
+		}
+	}
+	v.CreatedAt = h.clock().UTC()
+	stored, err := h.history.Append(ctx, v)
+	if err != nil {
+		slog.Warn("failed to record config version", "source", v.Source, "error", err)
+		return ConfigVersion{}, fmt.Errorf("record config version: %w", err)
+	}
+	return stored, nil
+}
+
+// latestConfigVersion returns the config of the most recently recorded
+// version, which is the config the engine is running.
+func (h *WorkflowUIHandler) latestConfigVersion(ctx context.Context) (*config.WorkflowConfig, bool) {
+	if h.history == nil {
+		return nil, false
+	}
+	versions, err := h.history.List(ctx)
+	if err != nil || len(versions) == 0 || versions[0].Config == nil {
+		return nil, false
+	}
+	return versions[0].Config, true
+}
+
+// recordHistoryAudit writes an audit entry for a reload or rollback.
+func (h *WorkflowUIHandler) recordHistoryAudit(ctx context.Context, action, actor string, v ConfigVersion, success bool, detail string) {
+	if h.auditStore == nil {
+		return
+	}
+	meta := map[string]any{"source": v.Source}
+	if v.Version > 0 {
+		meta["version"] = v.Version
+	}
+	if v.RollbackOf > 0 {
+		meta["rollback_of"] = v.RollbackOf
+	}
+	if v.Hash != "" {
+		meta["hash"] = v.Hash
+	}
+	_ = h.auditStore.Record(ctx, audit.Event{
+		Timestamp: h.clock().UTC(),
+		Type:      audit.EventConfigChange,
+		Action:    action,
+		Actor:     actor,
+		Resource:  "workflow-config",
+		Detail:    detail,
+		Success:   success,
+		Metadata:  meta,
+	})
+}
+
+func (h *WorkflowUIHandler) handleConfigHistory(w http.ResponseWriter, r *http.Request) {
+	if h.history == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "config history not configured")
+		return
+	}
+	versions, err := h.history.List(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for i := range versions {
+		versions[i].Config = nil // the list is a summary; fetch one version for its config
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(versions)
+}
+
+func (h *WorkflowUIHandler) handleGetConfigVersion(w http.ResponseWriter, r *http.Request, raw string) {
+	v, ok := h.lookupConfigVersion(w, r, raw)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// handleConfigRollback reloads the engine with a recorded config version.
+// The rollback is itself recorded as a new version. Under a two-person
+// approval policy it is staged like any other config change.
+func (h *WorkflowUIHandler) handleConfigRollback(w http.ResponseWriter, r *http.Request, raw string) {
+	ctx := r.Context()
+	target, ok := h.lookupConfigVersion(w, r, raw)
+	if !ok {
+		return
+	}
+	if target.Config == nil {
+		writeJSONError(w, http.StatusConflict, fmt.Sprintf("config version %d has no stored config", target.Version))
+		return
+	}
+	if policy := h.resolveApprovalPolicy(ctx); policy.RequireApproval {
+		h.stageConfigChange(w, r, target.Config, policy)
+		return
+	}
+	if h.reloadFn == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "reload not configured")
+		return
+	}
+
+	user := extractTriggeredBy(r)
+	v := ConfigVersion{Author: user, Source: ConfigSourceRollback, RollbackOf: target.Version, Hash: target.Hash}
+	if err := h.reloadFn(target.Config); err != nil {
+		h.recordHistoryAudit(ctx, "config.rollback", user, v, false, "reload failed: "+err.Error())
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.mu.Lock()
+	h.config = target.Config
+	h.mu.Unlock()
+
+	v.Config = target.Config
+	stored, err := h.recordConfigVersion(ctx, v)
+	if err != nil {
+		h.recordHistoryAudit(ctx, "config.rollback", user, v, true, fmt.Sprintf("rolled back to version %d; %v", target.Version, err))
+	} else {
+		h.recordHistoryAudit(ctx, "config.rollback", user, stored, true, fmt.Sprintf("rolled back to version %d", target.Version))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"status":       "reloaded",
+		"version":      stored.Version,
+		"rolledBackTo": target.Version,
+	})
+}
+
+// lookupConfigVersion parses raw as a version number and loads it, writing
+// the error response when it cannot.
+func (h *WorkflowUIHandler) lookupConfigVersion(w http.ResponseWriter, r *http.Request, raw string) (ConfigVersion, bool) {
+	if h.history == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "config history not configured")
+		return ConfigVersion{}, false
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid config version %q", raw))
+		return ConfigVersion{}, false
+	}
+	v, err := h.history.Get(r.Context(), n)
+	if errors.Is(err, ErrConfigVersionNotFound) {
+		writeJSONError(w, http.StatusNotFound, fmt.Sprintf("config version %d not found", n))
+		return ConfigVersion{}, false
+	}
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return ConfigVersion{}, false
+	}
+	return v, true
+}
+
+// serveConfigHistory dispatches .../config/history[/{version}] and
+// .../config/rollback/{version} requests. It reports false when the path is
+// not a config history path.
+func (h *WorkflowUIHandler) serveConfigHistory(w http.ResponseWriter, r *http.Request) bool {
+	if _, rest, ok := strings.Cut(r.URL.Path, "/config/history"); ok && r.Method == http.MethodGet {
+		rest = strings.Trim(rest, "/")
+		switch {
+		case rest == "":
+			h.handleConfigHistory(w, r)
+		case !strings.Contains(rest, "/"):
+			h.handleGetConfigVersion(w, r, rest)
+		default:
+			return false
+		}
+		return true
+	}
+	if _, rest, ok := strings.Cut(r.URL.Path, "/config/rollback/"); ok && r.Method == http.MethodPost {
+		rest = strings.Trim(rest, "/")
+		if rest == "" || strings.Contains(rest, "/") {
+			return false
+		}
+		h.handleConfigRollback(w, r, rest)
+		return true
+	}
+	return false
+}
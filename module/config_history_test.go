@@ -0,0 +1,152 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GoCodeAlone/workflow/config"
+)
+
+func TestConfigHistory_ReloadAndRollback(t *testing.T) {
+	good := &config.WorkflowConfig{Modules: []config.ModuleConfig{{Name: "server", Type: "http.server"}}}
+	h := NewWorkflowUIHandler(good)
+	var reloaded []*config.WorkflowConfig
+	failNext := false
+	h.SetReloadFunc(func(cfg *config.WorkflowConfig) error {
+		if failNext {
+			failNext = false
+			return errors.New("boom")
+		}
+		reloaded = append(reloaded, cfg)
+		return nil
+	})
+	store := &recordingAuditStore{}
+	h.SetAuditStore(store)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	if _, err := h.RecordConfigVersion(context.Background(), good, "", ConfigSourceStartup); err != nil {
+		t.Fatal(err)
+	}
+
+	// A bad push is applied and reloaded as version 2.
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, approvalRequest(http.MethodPut, "/api/workflow/config", "alice", stagedConfigJSON))
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, approvalRequest(http.MethodPost, "/api/workflow/reload", "alice", ""))
+	if w.Code != http.StatusOK {
+		t.Fatalf("reload: %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, approvalRequest(http.MethodGet, "/api/workflow/config/history", "bob", ""))
+	var history []ConfigVersion
+	if err := json.NewDecoder(w.Body).Decode(&history); err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 || history[0].Version != 2 || history[0].Author != "alice" || history[0].Source != ConfigSourceReload ||
+		history[1].Source != ConfigSourceStartup || history[0].Hash == "" || history[0].Config != nil {
+		t.Fatalf("history = %+v", history)
+	}
+
+	// Rolling back to version 1 reloads its config and records version 3.
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, approvalRequest(http.MethodPost, "/api/workflow/config/rollback/1", "bob", ""))
+	if w.Code != http.StatusOK {
+		t.Fatalf("rollback: %d %s", w.Code, w.Body.String())
+	}
+	if last := reloaded[len(reloaded)-1]; last != good {
+		t.Errorf("rollback reloaded %v, want version 1's config", last)
+	}
+	h.mu.RLock()
+	running := h.config
+	h.mu.RUnlock()
+	if running != good {
+		t.Error("rollback did not restore the running config")
+	}
+	v3, err := h.history.Get(context.Background(), 3)
+	if err != nil || v3.Source != ConfigSourceRollback || v3.RollbackOf != 1 || v3.Author != "bob" || v3.Hash != history[1].Hash {
+		t.Errorf("version 3 = %+v (%v)", v3, err)
+	}
+	last := store.events[len(store.events)-1]
+	if last.Action != "config.rollback" || !last.Success || last.Actor != "bob" || last.Metadata["rollback_of"] != 1 {
+		t.Errorf("rollback audit entry = %+v", last)
+	}
+
+	// A failed rollback is audited and leaves the history alone.
+	failNext = true
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, approvalRequest(http.MethodPost, "/api/workflow/config/rollback/2", "bob", ""))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("failed rollback: %d", w.Code)
+	}
+	if all, _ := h.history.List(context.Background()); len(all) != 3 {
+		t.Errorf("failed rollback recorded a version: %d versions", len(all))
+	}
+	if last := store.events[len(store.events)-1]; last.Success {
+		t.Error("failed rollback should be audited as unsuccessful")
+	}
+
+	for path, want := range map[string]int{
+		"/api/workflow/config/rollback/9":   http.StatusNotFound,
+		"/api/workflow/config/rollback/abc": http.StatusBadRequest,
+	} {
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, approvalRequest(http.MethodPost, path, "bob", ""))
+		if w.Code != want {
+			t.Errorf("%s: got %d, want %d", path, w.Code, want)
+		}
+	}
+}
+
+func TestConfigHistory_RollbackStagedUnderApproval(t *testing.T) {
+	h, mux, _, reloaded := newApprovalHandler(t, ConfigApprovalPolicy{})
+	h.mu.RLock()
+	running := h.config
+	h.mu.RUnlock()
+	if _, err := h.RecordConfigVersion(context.Background(), running, "", ConfigSourceStartup); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, approvalRequest(http.MethodPost, "/api/workflow/config/rollback/1", "alice", ""))
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("rollback: expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(*reloaded) != 0 {
+		t.Error("rollback under approval must not reload until approved")
+	}
+	id := h.pending.list()[0].ID
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, approvalRequest(http.MethodPost, "/api/workflow/config/pending/"+id+"/approve", "bob", ""))
+	if w.Code != http.StatusOK {
+		t.Fatalf("approve: %d %s", w.Code, w.Body.String())
+	}
+	v, err := h.history.Get(context.Background(), 2)
+	if err != nil || v.Source != ConfigSourceApproval || v.Author != "alice" || v.ApprovedBy != "bob" {
+		t.Errorf("approved version = %+v (%v)", v, err)
+	}
+}
+
+func TestConfigHistory_ServeHTTPDispatch(t *testing.T) {
+	h := NewWorkflowUIHandler(nil)
+	if _, err := h.RecordConfigVersion(context.Background(), config.NewEmptyWorkflowConfig(), "ops", ConfigSourceStartup); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, approvalRequest(http.MethodGet, "/api/v1/admin/engine/config/history/1", "", ""))
+	var v ConfigVersion
+	if err := json.NewDecoder(w.Body).Decode(&v); err != nil || v.Version != 1 || v.Config == nil {
+		t.Fatalf("get version via ServeHTTP: %d %+v (%v)", w.Code, v, err)
+	}
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, approvalRequest(http.MethodPost, "/api/v1/admin/engine/config/rollback/1", "", ""))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("rollback without reload func: got %d, want 503", w.Code)
+	}
+}
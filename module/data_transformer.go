@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"maps"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -14,7 +15,7 @@ import (
 
 // TransformOperation defines a single transformation step
 type TransformOperation struct {
-	Type   string         `json:"type" yaml:"type"` // "extract", "map", "convert", "filter"
+	Type   string         `json:"type" yaml:"type"` // "extract", "map", "convert", "filter", "rename", "default", "pick", "omit"
 	Config map[string]any `json:"config" yaml:"config"`
 }
 
@@ -99,6 +100,14 @@ func (dt *DataTransformer) applyOperation(op TransformOperation, data any) (any,
 		return dt.opFilter(op.Config, data)
 	case "convert":
 		return dt.opConvert(op.Config, data)
+	case "rename":
+		return dt.opRename(op.Config, data)
+	case "default":
+		return dt.opDefault(op.Config, data)
+	case "pick":
+		return dt.opPick(op.Config, data)
+	case "omit":
+		return dt.opOmit(op.Config, data)
 	default:
 		return nil, fmt.Errorf("unknown operation type: %s", op.Type)
 	}
@@ -245,3 +254,245 @@ func (dt *DataTransformer) opConvert(config map[string]any, data any) (any, erro
 		return nil, fmt.Errorf("unsupported conversion: %s -> %s", from, to)
 	}
 }
+
+// The rename, default, pick and omit operations adapt the shape of a record,
+// such as an API response, between internal and external versions. Each
+// accepts a map or a list of maps (applied to every item) and returns new
+// values, leaving its input unmodified.
+
+// opRename renames keys according to 'mappings' (old name → new name). With
+// 'deep' (the default) keys are renamed at every level, including inside
+// lists; with deep: false only top-level keys are renamed. A renamed key
+// replaces an existing key of the same name.
+func (dt *DataTransformer) opRename(config map[string]any, data any) (any, error) {
+	mappingsRaw, _ := config["mappings"].(map[string]any)
+	if len(mappingsRaw) == 0 {
+		return nil, fmt.Errorf("rename requires 'mappings' config")
+	}
+	mappings := make(map[string]string, len(mappingsRaw))
+	targets := make(map[string]string, len(mappingsRaw))
+	for oldName, newNameRaw := range mappingsRaw {
+		newName, ok := newNameRaw.(string)
+		if !ok || newName == "" {
+			return nil, fmt.Errorf("rename mapping for %q must be a non-empty string", oldName)
+		}
+		if other, dup := targets[newName]; dup {
+			return nil, fmt.Errorf("rename maps both %q and %q to %q", other, oldName, newName)
+		}
+		targets[newName] = oldName
+		mappings[oldName] = newName
+	}
+	deep := true
+	if v, ok := config["deep"].(bool); ok {
+		deep = v
+	}
+	if _, err := recordsOf("rename", data); err != nil {
+		return nil, err
+	}
+	return renameKeys(data, mappings, deep), nil
+}
+
+// renameKeys returns a copy of v with its map keys renamed. Keys that are not
+// renamed are copied first so that renamed keys win on collision regardless
+// of map iteration order.
+func renameKeys(v any, mappings map[string]string, deep bool) any {
+	switch val := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			if _, renamed := mappings[k]; !renamed {
+				out[k] = renameChild(item, mappings, deep)
+			}
+		}
+		for k, item := range val {
+			if newName, renamed := mappings[k]; renamed {
+				out[newName] = renameChild(item, mappings, deep)
+			}
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = renameKeys(item, mappings, deep)
+		}
+		return out
+	case []map[string]any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = renameKeys(item, mappings, deep)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func renameChild(v any, mappings map[string]string, deep bool) any {
+	if !deep {
+		return v
+	}
+	return renameKeys(v, mappings, true)
+}
+
+// opDefault fills keys missing from each record with the values in 'values'.
+// When both the default and the existing value are maps, the default is
+// applied to the nested map as well. Present keys, including ones set to
+// null, are left as they are.
+func (dt *DataTransformer) opDefault(config map[string]any, data any) (any, error) {
+	defaults, _ := config["values"].(map[string]any)
+	if len(defaults) == 0 {
+		return nil, fmt.Errorf("default requires 'values' config")
+	}
+	return eachRecord("default", data, func(record map[string]any) map[string]any {
+		return applyDefaults(record, defaults)
+	})
+}
+
+func applyDefaults(record, defaults map[string]any) map[string]any {
+	out := maps.Clone(record)
+	for k, def := range defaults {
+		current, exists := out[k]
+		if !exists {
+			out[k] = deepCopyValue(def)
+			continue
+		}
+		defMap, defIsMap := def.(map[string]any)
+		currentMap, currentIsMap := current.(map[string]any)
+		if defIsMap && currentIsMap {
+			out[k] = applyDefaults(currentMap, defMap)
+		}
+	}
+	return out
+}
+
+// opPick keeps only the keys listed in 'fields'. A dot-separated path such as
+// "address.city" keeps that key of a nested map and the maps leading to it.
+func (dt *DataTransformer) opPick(config map[string]any, data any) (any, error) {
+	paths, err := fieldPaths("pick", config)
+	if err != nil {
+		return nil, err
+	}
+	return eachRecord("pick", data, func(record map[string]any) map[string]any {
+		out := make(map[string]any)
+		for _, path := range paths {
+			pickPath(out, record, path)
+		}
+		return out
+	})
+}
+
+func pickPath(dst, src map[string]any, path []string) {
+	val, exists := src[path[0]]
+	if !exists {
+		return
+	}
+	if len(path) == 1 {
+		dst[path[0]] = val
+		return
+	}
+	nested, ok := val.(map[string]any)
+	if !ok {
+		return
+	}
+	child, ok := dst[path[0]].(map[string]any)
+	if !ok {
+		child = make(map[string]any)
+		dst[path[0]] = child
+	}
+	pickPath(child, nested, path[1:])
+}
+
+// opOmit removes the keys listed in 'fields'. A dot-separated path such as
+// "internal.debug" removes that key of a nested map.
+func (dt *DataTransformer) opOmit(config map[string]any, data any) (any, error) {
+	paths, err := fieldPaths("omit", config)
+	if err != nil {
+		return nil, err
+	}
+	return eachRecord("omit", data, func(record map[string]any) map[string]any {
+		out := record
+		for _, path := range paths {
+			out = omitPath(out, path)
+		}
+		return out
+	})
+}
+
+// omitPath returns a copy of m without the key at path, or m itself when
+// there is nothing to remove, copying only the maps along the path.
+func omitPath(m map[string]any, path []string) map[string]any {
+	val, exists := m[path[0]]
+	if !exists {
+		return m
+	}
+	if len(path) == 1 {
+		out := maps.Clone(m)
+		delete(out, path[0])
+		return out
+	}
+	nested, ok := val.(map[string]any)
+	if !ok {
+		return m
+	}
+	out := maps.Clone(m)
+	out[path[0]] = omitPath(nested, path[1:])
+	return out
+}
+
+// fieldPaths parses the 'fields' list of a pick or omit operation into
+// dot-separated paths.
+func fieldPaths(op string, config map[string]any) ([][]string, error) {
+	fieldsRaw, _ := config["fields"].([]any)
+	if len(fieldsRaw) == 0 {
+		return nil, fmt.Errorf("%s requires 'fields' config", op)
+	}
+	paths := make([][]string, 0, len(fieldsRaw))
+	for _, f := range fieldsRaw {
+		s, ok := f.(string)
+		if !ok || s == "" || slices.Contains(strings.Split(s, "."), "") {
+			return nil, fmt.Errorf("%s field %v must be a key or a dot-separated path", op, f)
+		}
+		paths = append(paths, strings.Split(s, "."))
+	}
+	return paths, nil
+}
+
+// recordsOf returns data as a list of records: the map itself, or the items
+// of a list of maps.
+func recordsOf(op string, data any) ([]map[string]any, error) {
+	switch v := data.(type) {
+	case map[string]any:
+		return []map[string]any{v}, nil
+	case []map[string]any:
+		return v, nil
+	case []any:
+		records := make([]map[string]any, len(v))
+		for i, item := range v {
+			m, ok := item.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("%s operation requires a map or a list of maps, got %T at index %d", op, item, i)
+			}
+			records[i] = m
+		}
+		return records, nil
+	default:
+		return nil, fmt.Errorf("%s operation requires a map or a list of maps, got %T", op, data)
+	}
+}
+
+// eachRecord applies fn to data when it is a map, or to each item when it is
+// a list of maps, in which case the result is a []any.
+func eachRecord(op string, data any, fn func(map[string]any) map[string]any) (any, error) {
+	records, err := recordsOf(op, data)
+	if err != nil {
+		return nil, err
+	}
+	if m, ok := data.(map[string]any); ok {
+		return fn(m), nil
+	}
+	out := make([]any, len(records))
+	for i, record := range records {
+		out[i] = fn(record)
+	}
+	return out, nil
+}
@@ -2,6 +2,7 @@ package module
 
 import (
 	"context"
+	"reflect"
 	"testing"
 )
 
@@ -560,3 +561,225 @@ func TestDataTransformer_ContextCancellation(t *testing.T) {
 		t.Fatal("expected error for cancelled context")
 	}
 }
+
+// Rename, default, pick and omit operation tests
+
+func TestDataTransformer_RenameDeep(t *testing.T) {
+	dt := NewDataTransformer("t")
+	ops := []TransformOperation{
+		{Type: "rename", Config: map[string]any{
+			"mappings": map[string]any{"user_id": "userId", "created_at": "createdAt"},
+		}},
+	}
+	data := map[string]any{
+		"user_id": 1,
+		"profile": map[string]any{"created_at": "2026-01-01"},
+		"orders":  []any{map[string]any{"user_id": 1, "total": 5}},
+	}
+
+	result, err := dt.TransformWithOps(context.Background(), ops, data)
+	if err != nil {
+		t.Fatalf("TransformWithOps failed: %v", err)
+	}
+	want := map[string]any{
+		"userId":  1,
+		"profile": map[string]any{"createdAt": "2026-01-01"},
+		"orders":  []any{map[string]any{"userId": 1, "total": 5}},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("got %v, want %v", result, want)
+	}
+	if _, ok := data["user_id"]; !ok {
+		t.Error("rename modified its input")
+	}
+}
+
+func TestDataTransformer_RenameShallow(t *testing.T) {
+	dt := NewDataTransformer("t")
+	ops := []TransformOperation{
+		{Type: "rename", Config: map[string]any{"mappings": map[string]any{"id": "ID"}, "deep": false}},
+	}
+	data := map[string]any{"id": 1, "owner": map[string]any{"id": 2}}
+
+	result, err := dt.TransformWithOps(context.Background(), ops, data)
+	if err != nil {
+		t.Fatalf("TransformWithOps failed: %v", err)
+	}
+	want := map[string]any{"ID": 1, "owner": map[string]any{"id": 2}}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("got %v, want %v", result, want)
+	}
+}
+
+func TestDataTransformer_RenameCollisions(t *testing.T) {
+	dt := NewDataTransformer("t")
+
+	// A renamed key replaces an existing key of the same name.
+	ops := []TransformOperation{
+		{Type: "rename", Config: map[string]any{"mappings": map[string]any{"name_v2": "name"}}},
+	}
+	result, err := dt.TransformWithOps(context.Background(), ops, map[string]any{"name": "old", "name_v2": "new"})
+	if err != nil {
+		t.Fatalf("TransformWithOps failed: %v", err)
+	}
+	if want := map[string]any{"name": "new"}; !reflect.DeepEqual(result, want) {
+		t.Errorf("got %v, want %v", result, want)
+	}
+
+	// Two keys renamed to the same name is a configuration error.
+	ops = []TransformOperation{
+		{Type: "rename", Config: map[string]any{"mappings": map[string]any{"a": "x", "b": "x"}}},
+	}
+	if _, err := dt.TransformWithOps(context.Background(), ops, map[string]any{}); err == nil {
+		t.Error("expected error for duplicate rename target")
+	}
+}
+
+func TestDataTransformer_RenameInvalid(t *testing.T) {
+	dt := NewDataTransformer("t")
+	cases := []struct {
+		name string
+		op   TransformOperation
+		data any
+	}{
+		{"no mappings", TransformOperation{Type: "rename", Config: map[string]any{}}, map[string]any{}},
+		{"non-string target", TransformOperation{Type: "rename", Config: map[string]any{"mappings": map[string]any{"a": 1}}}, map[string]any{}},
+		{"non-map input", TransformOperation{Type: "rename", Config: map[string]any{"mappings": map[string]any{"a": "b"}}}, "text"},
+	}
+	for _, tc := range cases {
+		if _, err := dt.TransformWithOps(context.Background(), []TransformOperation{tc.op}, tc.data); err == nil {
+			t.Errorf("%s: expected error", tc.name)
+		}
+	}
+}
+
+func TestDataTransformer_DefaultNested(t *testing.T) {
+	dt := NewDataTransformer("t")
+	ops := []TransformOperation{
+		{Type: "default", Config: map[string]any{"values": map[string]any{
+			"status":   "active",
+			"currency": "USD",
+			"settings": map[string]any{"theme": "light", "locale": "en"},
+			"tags":     []any{},
+		}}},
+	}
+	data := []any{
+		map[string]any{"status": nil, "settings": map[string]any{"theme": "dark"}},
+		map[string]any{"currency": "EUR"},
+	}
+
+	result, err := dt.TransformWithOps(context.Background(), ops, data)
+	if err != nil {
+		t.Fatalf("TransformWithOps failed: %v", err)
+	}
+	want := []any{
+		map[string]any{
+			"status":   nil, // present keys are kept, even when null
+			"currency": "USD",
+			"settings": map[string]any{"theme": "dark", "locale": "en"},
+			"tags":     []any{},
+		},
+		map[string]any{
+			"status":   "active",
+			"currency": "EUR",
+			"settings": map[string]any{"theme": "light", "locale": "en"},
+			"tags":     []any{},
+		},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("got %v, want %v", result, want)
+	}
+	if _, ok := data[0].(map[string]any)["settings"].(map[string]any)["locale"]; ok {
+		t.Error("default modified its input")
+	}
+}
+
+func TestDataTransformer_PickPaths(t *testing.T) {
+	dt := NewDataTransformer("t")
+	ops := []TransformOperation{
+		{Type: "pick", Config: map[string]any{"fields": []any{"id", "address.city", "missing"}}},
+	}
+	data := map[string]any{
+		"id":       1,
+		"password": "secret",
+		"address":  map[string]any{"city": "Oslo", "street": "Main St"},
+	}
+
+	result, err := dt.TransformWithOps(context.Background(), ops, data)
+	if err != nil {
+		t.Fatalf("TransformWithOps failed: %v", err)
+	}
+	want := map[string]any{"id": 1, "address": map[string]any{"city": "Oslo"}}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("got %v, want %v", result, want)
+	}
+}
+
+func TestDataTransformer_OmitPaths(t *testing.T) {
+	dt := NewDataTransformer("t")
+	ops := []TransformOperation{
+		{Type: "omit", Config: map[string]any{"fields": []any{"password", "meta.internal", "missing.key"}}},
+	}
+	data := []map[string]any{
+		{"id": 1, "password": "a", "meta": map[string]any{"internal": true, "version": 2}},
+		{"id": 2},
+	}
+
+	result, err := dt.TransformWithOps(context.Background(), ops, data)
+	if err != nil {
+		t.Fatalf("TransformWithOps failed: %v", err)
+	}
+	want := []any{
+		map[string]any{"id": 1, "meta": map[string]any{"version": 2}},
+		map[string]any{"id": 2},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("got %v, want %v", result, want)
+	}
+	if _, ok := data[0]["meta"].(map[string]any)["internal"]; !ok {
+		t.Error("omit modified its input")
+	}
+}
+
+func TestDataTransformer_PickOmitInvalid(t *testing.T) {
+	dt := NewDataTransformer("t")
+	cases := []struct {
+		name string
+		op   TransformOperation
+		data any
+	}{
+		{"no fields", TransformOperation{Type: "pick", Config: map[string]any{}}, map[string]any{}},
+		{"empty path segment", TransformOperation{Type: "omit", Config: map[string]any{"fields": []any{"a..b"}}}, map[string]any{}},
+		{"non-map list item", TransformOperation{Type: "pick", Config: map[string]any{"fields": []any{"a"}}}, []any{map[string]any{}, "text"}},
+	}
+	for _, tc := range cases {
+		if _, err := dt.TransformWithOps(context.Background(), []TransformOperation{tc.op}, tc.data); err == nil {
+			t.Errorf("%s: expected error", tc.name)
+		}
+	}
+}
+
+func TestDataTransformer_VersionedResponse(t *testing.T) {
+	// Shape an internal record into a v2 API response by composing the
+	// new operations with extract.
+	dt := NewDataTransformer("t")
+	ops := []TransformOperation{
+		{Type: "extract", Config: map[string]any{"path": "account"}},
+		{Type: "omit", Config: map[string]any{"fields": []any{"password_hash"}}},
+		{Type: "rename", Config: map[string]any{"mappings": map[string]any{"display_name": "name"}}},
+		{Type: "default", Config: map[string]any{"values": map[string]any{"plan": "free"}}},
+		{Type: "pick", Config: map[string]any{"fields": []any{"id", "name", "plan"}}},
+	}
+	data := map[string]any{"account": map[string]any{
+		"id": 7, "display_name": "Ada", "password_hash": "x", "last_login": "2026-10-01",
+	}}
+
+	result, err := dt.TransformWithOps(context.Background(), ops, data)
+	if err != nil {
+		t.Fatalf("TransformWithOps failed: %v", err)
+	}
+	want := map[string]any{"id": 7, "name": "Ada", "plan": "free"}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("got %v, want %v", result, want)
+	}
+}
@@ -3,12 +3,14 @@ package module
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"math"
 	"regexp"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/GoCodeAlone/modular"
@@ -39,8 +41,14 @@ type DatabaseConfig struct {
 	MaxOpenConns    int               `json:"maxOpenConns" yaml:"maxOpenConns" editor:"type=number,description=Maximum number of open database connections,default=25,label=Max Open Connections"`
 	MaxIdleConns    int               `json:"maxIdleConns" yaml:"maxIdleConns" editor:"type=number,description=Maximum number of idle connections in the pool,default=5,label=Max Idle Connections"`
 	ConnMaxLifetime time.Duration     `json:"connMaxLifetime" yaml:"connMaxLifetime"`
+	ConnMaxIdleTime time.Duration     `json:"connMaxIdleTime" yaml:"connMaxIdleTime"`
 	MigrationsDir   string            `json:"migrationsDir" yaml:"migrationsDir"`
 	TLS             DatabaseTLSConfig `json:"tls" yaml:"tls"`
+	// Replicas are read replicas of the primary. Reads made through ReadDB
+	// go to a healthy replica; writes and transactions always use DB().
+	Replicas []DatabaseReplicaConfig `json:"replicas" yaml:"replicas"`
+	// ReplicaHealthInterval is how often replicas are pinged (default 10s).
+	ReplicaHealthInterval time.Duration `json:"replicaHealthInterval" yaml:"replicaHealthInterval"`
 }
 
 // QueryResult represents the result of a query
@@ -52,10 +60,18 @@ type QueryResult struct {
 
 // WorkflowDatabase wraps database/sql for workflow use
 type WorkflowDatabase struct {
-	name   string
-	config DatabaseConfig
-	db     *sql.DB
-	mu     sync.RWMutex
+	name     string
+	config   DatabaseConfig
+	db       *sql.DB
+	replicas []*databaseReplica
+	mu       sync.RWMutex
+
+	app          modular.Application
+	logger       modular.Logger
+	nextReplica  atomic.Uint64
+	primaryReads atomic.Int64
+	healthStop   chan struct{}
+	goroutines   GoroutineCounter
 }
 
 // NewWorkflowDatabase creates a new WorkflowDatabase module
@@ -71,8 +87,16 @@ func (w *WorkflowDatabase) Name() string {
 	return w.name
 }
 
-// Init registers the database as a service
+// Init validates the replica configuration and registers the database as a
+// service.
 func (w *WorkflowDatabase) Init(app modular.Application) error {
+	for i, rc := range w.config.Replicas {
+		if rc.DSN == "" {
+			return fmt.Errorf("database %q: replica %q: 'dsn' is required", w.name, replicaName(rc, i))
+		}
+	}
+	w.app = app
+	w.logger = app.Logger()
 	return app.RegisterService(w.name, w)
 }
 
@@ -95,7 +119,11 @@ func (w *WorkflowDatabase) RequiresServices() []modular.ServiceDependency {
 
 // buildDSN returns the DSN with TLS parameters appended for supported drivers.
 func (w *WorkflowDatabase) buildDSN() string {
-	dsn := w.config.DSN
+	return w.tlsDSN(w.config.DSN)
+}
+
+// tlsDSN returns dsn with TLS parameters appended for supported drivers.
+func (w *WorkflowDatabase) tlsDSN(dsn string) string {
 	mode := w.config.TLS.Mode
 	if mode == "" || mode == "disable" {
 		return dsn
@@ -115,7 +143,7 @@ func (w *WorkflowDatabase) buildDSN() string {
 	return dsn
 }
 
-// Open opens the database connection using config
+// Open opens the database connection, and the replica pools, using config
 func (w *WorkflowDatabase) Open() (*sql.DB, error) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
@@ -124,19 +152,13 @@ func (w *WorkflowDatabase) Open() (*sql.DB, error) {
 		return w.db, nil
 	}
 
-	db, err := sql.Open(w.config.Driver, w.buildDSN())
+	db, err := w.openPool(w.config.DSN)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
-
-	if w.config.MaxOpenConns > 0 {
-		db.SetMaxOpenConns(w.config.MaxOpenConns)
-	}
-	if w.config.MaxIdleConns > 0 {
-		db.SetMaxIdleConns(w.config.MaxIdleConns)
-	}
-	if w.config.ConnMaxLifetime > 0 {
-		db.SetConnMaxLifetime(w.config.ConnMaxLifetime)
+	if err := w.openReplicas(); err != nil {
+		_ = db.Close()
+		return nil, err
 	}
 
 	w.db = db
@@ -145,13 +167,31 @@ func (w *WorkflowDatabase) Open() (*sql.DB, error) {
 
 // Start opens the database connection during application startup so that
 // pipeline steps (db_query, db_exec) can use DB() without requiring a
-// separate persistence.store module.
+// separate persistence.store module. Replicas are health-checked before
+// Start returns and then every ReplicaHealthInterval.
 func (w *WorkflowDatabase) Start(ctx context.Context) error {
 	if w.config.DSN == "" {
 		return nil // no DSN configured, skip auto-open
 	}
-	_, err := w.Open()
-	return err
+	if _, err := w.Open(); err != nil {
+		return err
+	}
+	w.registerPoolMetrics()
+	if len(w.config.Replicas) == 0 {
+		return nil
+	}
+
+	w.checkReplicas(ctx)
+	interval := w.config.ReplicaHealthInterval
+	if interval <= 0 {
+		interval = defaultReplicaHealthInterval
+	}
+	stop := make(chan struct{})
+	w.mu.Lock()
+	w.healthStop = stop
+	w.mu.Unlock()
+	w.goroutines.Go(func() { w.watchReplicas(interval, stop) })
+	return nil
 }
 
 // Stop closes the database connection during application shutdown.
@@ -159,17 +199,26 @@ func (w *WorkflowDatabase) Stop(ctx context.Context) error {
 	return w.Close()
 }
 
-// Close closes the database connection
+// Close stops the replica health checks and closes the database and replica
+// connections
 func (w *WorkflowDatabase) Close() error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
+	if w.healthStop != nil {
+		close(w.healthStop)
+		w.healthStop = nil
+	}
+	var errs []error
+	for _, r := range w.replicas {
+		errs = append(errs, r.db.Close())
+	}
+	w.replicas = nil
 	if w.db != nil {
-		err := w.db.Close()
+		errs = append(errs, w.db.Close())
 		w.db = nil
-		return err
 	}
-	return nil
+	return errors.Join(errs...)
 }
 
 // DB returns the underlying *sql.DB
@@ -179,6 +228,34 @@ func (w *WorkflowDatabase) DB() *sql.DB {
 	return w.db
 }
 
+// ResourceUsage reports the connection pools, summed over the primary and
+// its replicas, once the database is open.
+func (w *WorkflowDatabase) ResourceUsage() ResourceUsage {
+	usage := ResourceUsage{}
+	stats := w.PoolStats()
+	if len(stats) == 0 {
+		return usage
+	}
+	var total sql.DBStats
+	healthy := 0
+	for _, st := range stats {
+		total.OpenConnections += st.Pool.OpenConnections
+		total.InUse += st.Pool.InUse
+		total.Idle += st.Pool.Idle
+		if st.Role == "replica" && st.Healthy {
+			healthy++
+		}
+	}
+	addDBStatsUsage(usage, total)
+	if len(stats) > 1 {
+		usage[resourceDBHealthyReplicas] = float64(healthy)
+	}
+	if n := w.goroutines.Count(); n > 0 {
+		usage[ResourceGoroutines] = float64(n)
+	}
+	return usage
+}
+
 // DriverName returns the configured database driver (e.g. "pgx", "sqlite3").
 func (w *WorkflowDatabase) DriverName() string {
 	return w.config.Driver
@@ -196,11 +273,10 @@ func (w *WorkflowDatabase) Ping(ctx context.Context) error {
 	return db.PingContext(ctx)
 }
 
-// Query executes a query and returns structured results
+// Query executes a read-only query, on a replica when one is healthy, and
+// returns structured results
 func (w *WorkflowDatabase) Query(ctx context.Context, sqlStr string, args ...any) (*QueryResult, error) {
-	w.mu.RLock()
-	db := w.db
-	w.mu.RUnlock()
+	db := w.ReadDB()
 
 	if db == nil {
 		return nil, fmt.Errorf("database not open")
@@ -150,8 +150,9 @@ type PartitionedDatabase struct {
 	logger     modular.Logger
 
 	// periodic sync state
-	syncStop chan struct{}
-	syncWg   sync.WaitGroup
+	syncStop       chan struct{}
+	syncWg         sync.WaitGroup
+	syncGoroutines GoroutineCounter
 }
 
 // normalizePartitionConfig applies defaults to a PartitionConfig and returns the result.
@@ -277,7 +278,11 @@ func (p *PartitionedDatabase) Start(ctx context.Context) error {
 			}
 			p.syncStop = make(chan struct{})
 			p.syncWg.Add(1)
-			go p.runPeriodicSync(ctx, interval)
+			done := p.syncGoroutines.Track()
+			go func() {
+				defer done()
+				p.runPeriodicSync(ctx, interval)
+			}()
 		}
 	}
 
@@ -322,6 +327,14 @@ func (p *PartitionedDatabase) DB() *sql.DB {
 	return p.base.DB()
 }
 
+// ResourceUsage reports the connection pool and the periodic partition sync
+// goroutine.
+func (p *PartitionedDatabase) ResourceUsage() ResourceUsage {
+	usage := ResourceUsage{ResourceGoroutines: float64(p.syncGoroutines.Count())}
+	addDBPoolUsage(usage, p.base.DB())
+	return usage
+}
+
 // DriverName returns the configured database driver (satisfies DBDriverProvider).
 func (p *PartitionedDatabase) DriverName() string {
 	return p.config.Driver
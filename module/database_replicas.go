@@ -0,0 +1,259 @@
+package module
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// defaultReplicaHealthInterval is how often replicas are pinged when
+	// replicaHealthInterval is not configured.
+	defaultReplicaHealthInterval = 10 * time.Second
+	// replicaPingTimeout bounds a single replica health check.
+	replicaPingTimeout = 5 * time.Second
+	// resourceDBHealthyReplicas counts the read replicas currently serving reads.
+	resourceDBHealthyReplicas = "db_healthy_replicas"
+)
+
+// DatabaseReplicaConfig describes a read replica of a workflow database.
+// Replicas use the primary's driver, TLS and pool settings.
+type DatabaseReplicaConfig struct {
+	Name string `json:"name" yaml:"name"`
+	DSN  string `json:"dsn" yaml:"dsn"`
+}
+
+// DatabasePoolStats describes one connection pool of a workflow database.
+type DatabasePoolStats struct {
+	Name string `json:"name"`
+	Role string `json:"role"` // "primary" or "replica"
+	// Healthy reports whether the pool serves reads. A replica is healthy
+	// after its last health check succeeded; the primary while it is open.
+	Healthy bool `json:"healthy"`
+	// Reads counts the reads routed to the pool by ReadDB: every replica
+	// read, and primary reads made while no replica was healthy.
+	Reads int64 `json:"reads"`
+	// FailedHealthChecks counts the replica's failed pings since start.
+	FailedHealthChecks int64       `json:"failedHealthChecks"`
+	Pool               sql.DBStats `json:"pool"`
+}
+
+// databaseReplica is an open read replica and its health.
+type databaseReplica struct {
+	name         string
+	db           *sql.DB
+	healthy      atomic.Bool
+	reads        atomic.Int64
+	failedChecks atomic.Int64
+}
+
+// openPool opens a connection pool for dsn with the configured pool limits.
+func (w *WorkflowDatabase) openPool(dsn string) (*sql.DB, error) {
+	db, err := sql.Open(w.config.Driver, w.tlsDSN(dsn))
+	if err != nil {
+		return nil, err
+	}
+	if w.config.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(w.config.MaxOpenConns)
+	}
+	if w.config.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(w.config.MaxIdleConns)
+	}
+	if w.config.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(w.config.ConnMaxLifetime)
+	}
+	if w.config.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(w.config.ConnMaxIdleTime)
+	}
+	return db, nil
+}
+
+// openReplicas opens a pool per configured replica. Replicas serve no reads
+// until a health check succeeds. The caller holds w.mu.
+func (w *WorkflowDatabase) openReplicas() error {
+	replicas := make([]*databaseReplica, 0, len(w.config.Replicas))
+	for i, rc := range w.config.Replicas {
+		db, err := w.openPool(rc.DSN)
+		if err != nil {
+			for _, r := range replicas {
+				_ = r.db.Close()
+			}
+			return fmt.Errorf("failed to open replica %q: %w", replicaName(rc, i), err)
+		}
+		replicas = append(replicas, &databaseReplica{name: replicaName(rc, i), db: db})
+	}
+	w.replicas = replicas
+	return nil
+}
+
+func replicaName(rc DatabaseReplicaConfig, i int) string {
+	if rc.Name != "" {
+		return rc.Name
+	}
+	return fmt.Sprintf("replica-%d", i)
+}
+
+// ReadDB returns the pool a read-only query should use: the next healthy
+// replica in round-robin order, or the primary when there are no replicas
+// or none is healthy.
+func (w *WorkflowDatabase) ReadDB() *sql.DB {
+	w.mu.RLock()
+	primary, replicas := w.db, w.replicas
+	w.mu.RUnlock()
+
+	if n := uint64(len(replicas)); n > 0 {
+		start := w.nextReplica.Add(1)
+		for i := range n {
+			if r := replicas[(start+i)%n]; r.healthy.Load() {
+				r.reads.Add(1)
+				return r.db
+			}
+		}
+	}
+	if primary != nil {
+		w.primaryReads.Add(1)
+	}
+	return primary
+}
+
+// checkReplicas pings every replica and updates its health, logging
+// replicas that stop or resume serving reads.
+func (w *WorkflowDatabase) checkReplicas(ctx context.Context) {
+	w.mu.RLock()
+	replicas := w.replicas
+	w.mu.RUnlock()
+
+	for _, r := range replicas {
+		pingCtx, cancel := context.WithTimeout(ctx, replicaPingTimeout)
+		err := r.db.PingContext(pingCtx)
+		cancel()
+		if err != nil {
+			r.failedChecks.Add(1)
+		}
+		if was := r.healthy.Swap(err == nil); was != (err == nil) && w.logger != nil {
+			if err != nil {
+				w.logger.Warn("Database replica unhealthy, reads fall back to other replicas or the primary", "database", w.name, "replica", r.name, "error", err)
+			} else {
+				w.logger.Info("Database replica serving reads", "database", w.name, "replica", r.name)
+			}
+		}
+	}
+}
+
+// watchReplicas runs checkReplicas every interval until stop is closed.
+func (w *WorkflowDatabase) watchReplicas(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			w.checkReplicas(context.Background())
+		}
+	}
+}
+
+// PoolStats returns the stats of the primary pool followed by each replica
+// pool, in configuration order. It is empty until the database is opened.
+func (w *WorkflowDatabase) PoolStats() []DatabasePoolStats {
+	w.mu.RLock()
+	primary, replicas := w.db, w.replicas
+	w.mu.RUnlock()
+
+	if primary == nil {
+		return nil
+	}
+	stats := make([]DatabasePoolStats, 0, len(replicas)+1)
+	stats = append(stats, DatabasePoolStats{
+		Name:    "primary",
+		Role:    "primary",
+		Healthy: true,
+		Reads:   w.primaryReads.Load(),
+		Pool:    primary.Stats(),
+	})
+	for _, r := range replicas {
+		stats = append(stats, DatabasePoolStats{
+			Name:               r.name,
+			Role:               "replica",
+			Healthy:            r.healthy.Load(),
+			Reads:              r.reads.Load(),
+			FailedHealthChecks: r.failedChecks.Load(),
+			Pool:               r.db.Stats(),
+		})
+	}
+	return stats
+}
+
+// registerPoolMetrics exports PoolStats through the metrics collector, when
+// one is registered.
+func (w *WorkflowDatabase) registerPoolMetrics() {
+	if w.app == nil {
+		return
+	}
+	var metrics *MetricsCollector
+	if err := w.app.GetService("metrics.collector", &metrics); err != nil || metrics == nil {
+		return
+	}
+	if err := metrics.RegisterCollector(newDatabasePoolMetrics(metrics.config.Namespace, metrics.config.Subsystem, w.name, w.PoolStats)); err != nil && w.logger != nil {
+		w.logger.Warn("Failed to register database pool metrics", "database", w.name, "error", err)
+	}
+}
+
+// databasePoolMetrics is a prometheus.Collector over the pools of a
+// workflow database, labeled by database, pool and role.
+type databasePoolMetrics struct {
+	database string
+	stats    func() []DatabasePoolStats
+	open     *prometheus.Desc
+	inUse    *prometheus.Desc
+	idle     *prometheus.Desc
+	waits    *prometheus.Desc
+	waitTime *prometheus.Desc
+	reads    *prometheus.Desc
+	healthy  *prometheus.Desc
+}
+
+func newDatabasePoolMetrics(namespace, subsystem, database string, stats func() []DatabasePoolStats) *databasePoolMetrics {
+	labels := []string{"database", "pool", "role"}
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, name), help, labels, nil)
+	}
+	return &databasePoolMetrics{
+		database: database,
+		stats:    stats,
+		open:     desc("db_pool_open_connections", "Number of open connections in the database pool"),
+		inUse:    desc("db_pool_in_use_connections", "Number of connections of the database pool in use"),
+		idle:     desc("db_pool_idle_connections", "Number of idle connections in the database pool"),
+		waits:    desc("db_pool_wait_count_total", "Total number of connections waited for"),
+		waitTime: desc("db_pool_wait_seconds_total", "Total time spent waiting for a connection"),
+		reads:    desc("db_pool_reads_total", "Total number of reads routed to the database pool"),
+		healthy:  desc("db_pool_healthy", "Whether the database pool serves reads (1) or not (0)"),
+	}
+}
+
+// Describe sends no descriptors, making the collector unchecked so that
+// several databases can export the same metric names.
+func (m *databasePoolMetrics) Describe(chan<- *prometheus.Desc) {}
+
+// Collect implements prometheus.Collector.
+func (m *databasePoolMetrics) Collect(ch chan<- prometheus.Metric) {
+	for _, st := range m.stats() {
+		labels := []string{m.database, st.Name, st.Role}
+		healthy := 0.0
+		if st.Healthy {
+			healthy = 1
+		}
+		ch <- prometheus.MustNewConstMetric(m.open, prometheus.GaugeValue, float64(st.Pool.OpenConnections), labels...)
+		ch <- prometheus.MustNewConstMetric(m.inUse, prometheus.GaugeValue, float64(st.Pool.InUse), labels...)
+		ch <- prometheus.MustNewConstMetric(m.idle, prometheus.GaugeValue, float64(st.Pool.Idle), labels...)
+		ch <- prometheus.MustNewConstMetric(m.waits, prometheus.CounterValue, float64(st.Pool.WaitCount), labels...)
+		ch <- prometheus.MustNewConstMetric(m.waitTime, prometheus.CounterValue, st.Pool.WaitDuration.Seconds(), labels...)
+		ch <- prometheus.MustNewConstMetric(m.reads, prometheus.CounterValue, float64(st.Reads), labels...)
+		ch <- prometheus.MustNewConstMetric(m.healthy, prometheus.GaugeValue, healthy, labels...)
+	}
+}
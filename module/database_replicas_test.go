@@ -0,0 +1,120 @@
+package module
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+// setupReplicaFile creates a SQLite database file whose only row names it.
+func setupReplicaFile(t *testing.T, name string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name+".db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`CREATE TABLE source (name TEXT); INSERT INTO source VALUES (?)`, name); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestWorkflowDatabase_ReadReplicaRouting(t *testing.T) {
+	app, _ := NewTestApplication()
+	wdb := NewWorkflowDatabase("db", DatabaseConfig{
+		Driver: "sqlite",
+		DSN:    setupReplicaFile(t, "primary"),
+		Replicas: []DatabaseReplicaConfig{
+			{Name: "r1", DSN: setupReplicaFile(t, "r1")},
+			{DSN: "file:" + filepath.Join(t.TempDir(), "missing", "r2.db") + "?mode=rw"},
+		},
+	})
+	if err := wdb.Init(app); err != nil {
+		t.Fatal(err)
+	}
+	if err := wdb.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer wdb.Stop(context.Background())
+
+	query := func(cfg map[string]any) string {
+		t.Helper()
+		cfg["database"] = "db"
+		cfg["query"] = "SELECT name FROM source"
+		cfg["mode"] = "single"
+		step, err := NewDBQueryStepFactory()("q", cfg, app)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := step.Execute(context.Background(), NewPipelineContext(nil, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res.Output["row"].(map[string]any)["name"].(string)
+	}
+
+	// replica-1 cannot be opened, so every read goes to r1.
+	for range 3 {
+		if got := query(map[string]any{}); got != "r1" {
+			t.Fatalf("read from %q, want r1", got)
+		}
+	}
+	if got := query(map[string]any{"use_primary": true}); got != "primary" {
+		t.Errorf("use_primary read from %q, want primary", got)
+	}
+
+	exec, err := NewDBExecStepFactory()("w", map[string]any{
+		"database": "db",
+		"query":    "INSERT INTO source VALUES ('written')",
+	}, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := exec.Execute(context.Background(), NewPipelineContext(nil, nil)); err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	if err := wdb.DB().QueryRow(`SELECT COUNT(*) FROM source WHERE name = 'written'`).Scan(&n); err != nil || n != 1 {
+		t.Errorf("write not on the primary: count=%d err=%v", n, err)
+	}
+
+	// With r1 down too, reads fall back to the primary.
+	_ = wdb.replicas[0].db.Close()
+	wdb.checkReplicas(context.Background())
+	if got := query(map[string]any{}); got != "primary" {
+		t.Errorf("read from %q with no healthy replica, want primary", got)
+	}
+
+	stats := wdb.PoolStats()
+	if len(stats) != 3 {
+		t.Fatalf("PoolStats() = %d pools, want 3", len(stats))
+	}
+	want := []struct {
+		name    string
+		healthy bool
+		reads   int64
+	}{{"primary", true, 1}, {"r1", false, 3}, {"replica-1", false, 0}}
+	for i, w := range want {
+		st := stats[i]
+		if st.Name != w.name || st.Healthy != w.healthy || st.Reads != w.reads {
+			t.Errorf("pool %d = {%s healthy=%v reads=%d}, want {%s healthy=%v reads=%d}", i, st.Name, st.Healthy, st.Reads, w.name, w.healthy, w.reads)
+		}
+	}
+	if stats[2].FailedHealthChecks != 2 {
+		t.Errorf("replica-1 failed checks = %d, want 2", stats[2].FailedHealthChecks)
+	}
+	if got := wdb.ResourceUsage()[resourceDBHealthyReplicas]; got != 0 {
+		t.Errorf("healthy replicas gauge = %v, want 0", got)
+	}
+}
+
+func TestWorkflowDatabase_ReplicaRequiresDSN(t *testing.T) {
+	app, _ := NewTestApplication()
+	wdb := NewWorkflowDatabase("db", DatabaseConfig{Driver: "sqlite", DSN: ":memory:", Replicas: []DatabaseReplicaConfig{{Name: "r1"}}})
+	if err := wdb.Init(app); err == nil {
+		t.Fatal("expected error for replica without dsn")
+	}
+}
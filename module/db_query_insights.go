@@ -0,0 +1,549 @@
+package module
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/GoCodeAlone/modular"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// dbQueryExplainTimeout bounds how long capturing an EXPLAIN plan may take.
+const dbQueryExplainTimeout = 5 * time.Second
+
+// maxDBStatementSteps bounds how many pipeline steps a statement summary
+// lists as running it.
+const maxDBStatementSteps = 5
+
+// DBQueryOtherStatement groups the statements run once a database.query_insights
+// module tracks MaxStatements distinct statements in a minute.
+const DBQueryOtherStatement = "(other)"
+
+// DBQueryInsightsConfig configures a database.query_insights module.
+type DBQueryInsightsConfig struct {
+	// Databases lists the database services observed; empty observes all.
+	Databases []string
+	// SampleRate is the fraction of statements recorded in execution
+	// events, statement summaries and metrics, from 0 to 1.
+	SampleRate float64
+	// SlowThreshold is the duration from which a statement is slow.
+	SlowThreshold time.Duration
+	// SlowSampleRate is the fraction of slow statements added to the
+	// slow-query log, from 0 to 1.
+	SlowSampleRate float64
+	// MaxSlowQueries is how many slow queries the log keeps.
+	MaxSlowQueries int
+	// Explain captures the plan of slow PostgreSQL statements.
+	Explain bool
+	// Retention is how long statement summaries are kept, and so the
+	// longest window they can be reported over.
+	Retention time.Duration
+	// MaxStatements bounds the distinct statements summarized per minute;
+	// further statements are counted as DBQueryOtherStatement.
+	MaxStatements int
+	// RedactPatterns are added to SensitiveFieldPatterns when redacting the
+	// literals of recorded statements.
+	RedactPatterns []string
+}
+
+// DefaultDBQueryInsightsConfig returns the default configuration.
+func DefaultDBQueryInsightsConfig() DBQueryInsightsConfig {
+	return DBQueryInsightsConfig{
+		SampleRate:     1,
+		SlowThreshold:  500 * time.Millisecond,
+		SlowSampleRate: 1,
+		MaxSlowQueries: 100,
+		Explain:        true,
+		Retention:      time.Hour,
+		MaxStatements:  500,
+	}
+}
+
+// SlowQuery is an entry of the slow-query log. Statement has its literals
+// redacted like the statements of execution events. The error of a failed
+// statement is not kept, as database errors often quote values.
+type SlowQuery struct {
+	At          time.Time `json:"at"`
+	Database    string    `json:"database"`
+	Operation   string    `json:"operation"`
+	Statement   string    `json:"statement"`
+	DurationMs  float64   `json:"durationMs"`
+	Rows        int64     `json:"rows"`
+	Failed      bool      `json:"failed,omitempty"`
+	Pipeline    string    `json:"pipeline,omitempty"`
+	Step        string    `json:"step"`
+	ExecutionID string    `json:"executionId,omitempty"`
+	// Plan is the EXPLAIN (FORMAT JSON) output of a PostgreSQL statement,
+	// with literals replaced by "?". It is captured asynchronously.
+	Plan      any    `json:"plan,omitempty"`
+	PlanError string `json:"planError,omitempty"`
+}
+
+// DBStatementSummary summarizes the runs of one normalized statement of a
+// database over a window.
+type DBStatementSummary struct {
+	Database  string   `json:"database"`
+	Statement string   `json:"statement"`
+	Operation string   `json:"operation"`
+	Count     int64    `json:"count"`
+	Failures  int64    `json:"failures"`
+	TotalMs   float64  `json:"totalMs"`
+	AvgMs     float64  `json:"avgMs"`
+	MaxMs     float64  `json:"maxMs"`
+	Rows      int64    `json:"rows"`
+	Steps     []string `json:"steps"`
+}
+
+type dbStatementKey struct {
+	database  string
+	statement string
+}
+
+type dbStatementStats struct {
+	operation string
+	count     int64
+	failures  int64
+	total     time.Duration
+	max       time.Duration
+	rows      int64
+	steps     []string
+}
+
+// dbQueryBucket holds the statement stats of one minute.
+type dbQueryBucket struct {
+	start      time.Time
+	statements map[dbStatementKey]*dbStatementStats
+}
+
+// DBQueryInsights observes the SQL statements run by step.db_query and
+// step.db_exec: it samples them into their step.completed events, keeps
+// per-minute summaries grouped by normalized statement, logs slow statements
+// with their PostgreSQL plans, and exports duration histograms through the
+// metrics collector when one is configured.
+type DBQueryInsights struct {
+	name     string
+	config   DBQueryInsightsConfig
+	patterns []string
+	app      modular.Application
+	logger   modular.Logger
+
+	mu       sync.Mutex
+	buckets  []*dbQueryBucket // oldest first
+	slow     []*SlowQuery     // oldest first
+	duration *prometheus.HistogramVec
+	slowRuns *prometheus.CounterVec
+
+	// explaining allows one EXPLAIN at a time; slow queries arriving
+	// meanwhile are logged without a plan.
+	explaining chan struct{}
+	explains   sync.WaitGroup
+	stopCtx    context.Context
+	stop       context.CancelFunc
+
+	now func() time.Time
+}
+
+// NewDBQueryInsights creates a database.query_insights module. Zero
+// durations and limits take their defaults.
+func NewDBQueryInsights(name string, cfg DBQueryInsightsConfig) *DBQueryInsights {
+	defaults := DefaultDBQueryInsightsConfig()
+	if cfg.SlowThreshold <= 0 {
+		cfg.SlowThreshold = defaults.SlowThreshold
+	}
+	if cfg.MaxSlowQueries <= 0 {
+		cfg.MaxSlowQueries = defaults.MaxSlowQueries
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = defaults.Retention
+	}
+	if cfg.MaxStatements <= 0 {
+		cfg.MaxStatements = defaults.MaxStatements
+	}
+	patterns := make([]string, 0, len(SensitiveFieldPatterns)+len(cfg.RedactPatterns))
+	patterns = append(patterns, SensitiveFieldPatterns...)
+	patterns = append(patterns, cfg.RedactPatterns...)
+	stopCtx, stop := context.WithCancel(context.Background())
+	return &DBQueryInsights{
+		name:       name,
+		config:     cfg,
+		patterns:   patterns,
+		explaining: make(chan struct{}, 1),
+		stopCtx:    stopCtx,
+		stop:       stop,
+		now:        time.Now,
+	}
+}
+
+// Name returns the module name.
+func (q *DBQueryInsights) Name() string { return q.name }
+
+// Init validates the configuration and registers the module as a service.
+func (q *DBQueryInsights) Init(app modular.Application) error {
+	for key, rate := range map[string]float64{"sampleRate": q.config.SampleRate, "slowSampleRate": q.config.SlowSampleRate} {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("query insights %q: %s must be between 0 and 1, got %v", q.name, key, rate)
+		}
+	}
+	q.app = app
+	q.logger = app.Logger()
+	return app.RegisterService(q.name, q)
+}
+
+// Start registers the query metrics with the metrics collector, when one is
+// registered.
+func (q *DBQueryInsights) Start(_ context.Context) error {
+	var metrics *MetricsCollector
+	if err := q.app.GetService("metrics.collector", &metrics); err != nil || metrics == nil {
+		return nil
+	}
+	ns, sub := metrics.Namespace()
+	duration, err := registerQueryMetric(metrics, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: ns,
+		Subsystem: sub,
+		Name:      "db_query_duration_seconds",
+		Help:      "Duration of the SQL statements run by database steps, sampled by database.query_insights",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"database", "operation"}))
+	if err != nil {
+		return fmt.Errorf("query insights %q: %w", q.name, err)
+	}
+	slowRuns, err := registerQueryMetric(metrics, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: ns,
+		Subsystem: sub,
+		Name:      "db_slow_queries_total",
+		Help:      "Total number of slow SQL statements logged by database.query_insights",
+	}, []string{"database"}))
+	if err != nil {
+		return fmt.Errorf("query insights %q: %w", q.name, err)
+	}
+	q.mu.Lock()
+	q.duration, q.slowRuns = duration, slowRuns
+	q.mu.Unlock()
+	return nil
+}
+
+// registerQueryMetric registers c, or returns the identical metric another
+// database.query_insights module registered.
+func registerQueryMetric[T prometheus.Collector](metrics *MetricsCollector, c T) (T, error) {
+	err := metrics.RegisterCollector(c)
+	var registered prometheus.AlreadyRegisteredError
+	if errors.As(err, &registered) {
+		if existing, ok := registered.ExistingCollector.(T); ok {
+			return existing, nil
+		}
+	}
+	return c, err
+}
+
+// Stop cancels and waits for the EXPLAIN plans being captured.
+func (q *DBQueryInsights) Stop(_ context.Context) error {
+	q.stop()
+	q.explains.Wait()
+	return nil
+}
+
+// ProvidesServices implements modular.Module.
+func (q *DBQueryInsights) ProvidesServices() []modular.ServiceProvider {
+	return []modular.ServiceProvider{
+		{Name: q.name, Description: "Database query insights: " + q.name, Instance: q},
+	}
+}
+
+// RequiresServices implements modular.Module.
+func (q *DBQueryInsights) RequiresServices() []modular.ServiceDependency {
+	return nil
+}
+
+// Covers reports whether the module observes the statements of database.
+func (q *DBQueryInsights) Covers(database string) bool {
+	return len(q.config.Databases) == 0 || slices.Contains(q.config.Databases, database)
+}
+
+// dbQuery is a statement run by a database step.
+type dbQuery struct {
+	database string
+	db       *sql.DB
+	driver   string
+	query    string // as sent to the driver
+	params   []any  // only used to EXPLAIN the statement, never recorded
+	duration time.Duration
+	rows     int64
+	err      error
+}
+
+// observe records a statement and returns its stats for the step.completed
+// event, or nil when it is not sampled.
+func (q *DBQueryInsights) observe(pc *PipelineContext, step string, dq dbQuery) *DBQueryStats {
+	sampled := sampleDBQuery(q.config.SampleRate)
+	slow := dq.duration >= q.config.SlowThreshold && sampleDBQuery(q.config.SlowSampleRate)
+	if !sampled && !slow {
+		return nil
+	}
+
+	tokens := tokenizeSQL(dq.query)
+	stats := &DBQueryStats{
+		Database:  dq.database,
+		Operation: sqlOperation(tokens),
+		Statement: redactSQLStatement(tokens, q.patterns),
+		Duration:  dq.duration,
+		Rows:      dq.rows,
+	}
+	pipeline, _ := pc.Metadata["pipeline"].(string)
+	now := q.now()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if sampled {
+		q.summarize(now, normalizeSQLStatement(tokens), pipeline+"/"+step, stats, dq.err != nil)
+		if q.duration != nil {
+			q.duration.WithLabelValues(dq.database, stats.Operation).Observe(dq.duration.Seconds())
+		}
+	}
+	if slow {
+		entry := &SlowQuery{
+			At:         now,
+			Database:   dq.database,
+			Operation:  stats.Operation,
+			Statement:  stats.Statement,
+			DurationMs: durationMs(dq.duration),
+			Rows:       dq.rows,
+			Failed:     dq.err != nil,
+			Pipeline:   pipeline,
+			Step:       step,
+		}
+		entry.ExecutionID, _ = pc.Metadata["execution_id"].(string)
+		q.slow = append(q.slow, entry)
+		if len(q.slow) > q.config.MaxSlowQueries {
+			q.slow = slices.Delete(q.slow, 0, len(q.slow)-q.config.MaxSlowQueries)
+		}
+		if q.slowRuns != nil {
+			q.slowRuns.WithLabelValues(dq.database).Inc()
+		}
+		if q.config.Explain && isPostgresDriver(dq.driver) && stats.Operation != "other" {
+			q.explainAsync(entry, dq)
+		}
+	}
+	if !sampled {
+		return nil
+	}
+	return stats
+}
+
+// sampleDBQuery reports whether a statement is sampled at rate.
+func sampleDBQuery(rate float64) bool {
+	return rate >= 1 || (rate > 0 && rand.Float64() < rate) //nolint:gosec // G404: sampling needs no cryptographic randomness
+}
+
+// summarize adds a statement to the current minute's bucket. q.mu must be
+// held.
+func (q *DBQueryInsights) summarize(now time.Time, statement, step string, stats *DBQueryStats, failed bool) {
+	start := now.Truncate(time.Minute)
+	if n := len(q.buckets); n == 0 || !q.buckets[n-1].start.Equal(start) {
+		cutoff := start.Add(-q.config.Retention)
+		q.buckets = slices.DeleteFunc(q.buckets, func(b *dbQueryBucket) bool { return !b.start.After(cutoff) })
+		q.buckets = append(q.buckets, &dbQueryBucket{start: start, statements: map[dbStatementKey]*dbStatementStats{}})
+	}
+	bucket := q.buckets[len(q.buckets)-1]
+
+	key := dbStatementKey{database: stats.Database, statement: statement}
+	s, ok := bucket.statements[key]
+	if !ok && len(bucket.statements) >= q.config.MaxStatements {
+		key.statement = DBQueryOtherStatement
+		s, ok = bucket.statements[key]
+	}
+	if !ok {
+		s = &dbStatementStats{operation: stats.Operation}
+		bucket.statements[key] = s
+	}
+	s.count++
+	if failed {
+		s.failures++
+	}
+	s.total += stats.Duration
+	s.max = max(s.max, stats.Duration)
+	s.rows += stats.Rows
+	if len(s.steps) < maxDBStatementSteps && !slices.Contains(s.steps, step) {
+		s.steps = append(s.steps, step)
+	}
+}
+
+// Statements summarizes the sampled statements run in the last window,
+// which is capped at the retention. Summaries are in no particular order.
+func (q *DBQueryInsights) Statements(window time.Duration) []DBStatementSummary {
+	window = min(window, q.config.Retention)
+	cutoff := q.now().Add(-window)
+
+	q.mu.Lock()
+	merged := map[dbStatementKey]*dbStatementStats{}
+	for _, b := range q.buckets {
+		if !b.start.Add(time.Minute).After(cutoff) {
+			continue
+		}
+		for key, s := range b.statements {
+			m, ok := merged[key]
+			if !ok {
+				m = &dbStatementStats{operation: s.operation}
+				merged[key] = m
+			}
+			m.count += s.count
+			m.failures += s.failures
+			m.total += s.total
+			m.max = max(m.max, s.max)
+			m.rows += s.rows
+			for _, step := range s.steps {
+				if len(m.steps) < maxDBStatementSteps && !slices.Contains(m.steps, step) {
+					m.steps = append(m.steps, step)
+				}
+			}
+		}
+	}
+	q.mu.Unlock()
+
+	summaries := make([]DBStatementSummary, 0, len(merged))
+	for key, s := range merged {
+		summaries = append(summaries, DBStatementSummary{
+			Database:  key.database,
+			Statement: key.statement,
+			Operation: s.operation,
+			Count:     s.count,
+			Failures:  s.failures,
+			TotalMs:   durationMs(s.total),
+			AvgMs:     durationMs(s.total) / float64(s.count),
+			MaxMs:     durationMs(s.max),
+			Rows:      s.rows,
+			Steps:     s.steps,
+		})
+	}
+	return summaries
+}
+
+// SlowQueries returns the slow-query log, newest first.
+func (q *DBQueryInsights) SlowQueries() []SlowQuery {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	entries := make([]SlowQuery, 0, len(q.slow))
+	for i := len(q.slow) - 1; i >= 0; i-- {
+		entries = append(entries, *q.slow[i])
+	}
+	return entries
+}
+
+// explainAsync captures the plan of a slow statement in the background,
+// unless another plan is being captured. q.mu must be held.
+func (q *DBQueryInsights) explainAsync(entry *SlowQuery, dq dbQuery) {
+	select {
+	case q.explaining <- struct{}{}:
+	default:
+		entry.PlanError = "skipped: another plan was being captured"
+		return
+	}
+	q.explains.Add(1)
+	go func() {
+		defer q.explains.Done()
+		defer func() { <-q.explaining }()
+		ctx, cancel := context.WithTimeout(q.stopCtx, dbQueryExplainTimeout)
+		defer cancel()
+		plan, err := explainDBQuery(ctx, dq)
+
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		if err != nil {
+			entry.PlanError = err.Error()
+			return
+		}
+		entry.Plan = plan
+	}()
+}
+
+// explainDBQuery returns the PostgreSQL plan of a statement, with every
+// string in it normalized so that literal and parameter values in
+// conditions are replaced by "?".
+func explainDBQuery(ctx context.Context, dq dbQuery) (any, error) {
+	var raw []byte
+	if err := dq.db.QueryRowContext(ctx, "EXPLAIN (FORMAT JSON) "+dq.query, dq.params...).Scan(&raw); err != nil {
+		return nil, fmt.Errorf("explain failed: %w", err)
+	}
+	var plan any
+	if err := json.Unmarshal(raw, &plan); err != nil {
+		return nil, fmt.Errorf("explain returned invalid JSON: %w", err)
+	}
+	return normalizePlanStrings(plan), nil
+}
+
+func normalizePlanStrings(v any) any {
+	switch val := v.(type) {
+	case string:
+		return normalizeSQLStatement(tokenizeSQL(val))
+	case map[string]any:
+		for k, item := range val {
+			val[k] = normalizePlanStrings(item)
+		}
+	case []any:
+		for i, item := range val {
+			val[i] = normalizePlanStrings(item)
+		}
+	}
+	return v
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// dbQueryRecorder reports the statements of a database step to the
+// database.query_insights module observing its database, if any.
+type dbQueryRecorder struct {
+	step     string
+	app      modular.Application
+	once     sync.Once
+	insights *DBQueryInsights
+}
+
+func newDBQueryRecorder(step string, app modular.Application) *dbQueryRecorder {
+	return &dbQueryRecorder{step: step, app: app}
+}
+
+// record observes a statement and returns its stats for the step result.
+// Without a database.query_insights module every statement is recorded.
+func (r *dbQueryRecorder) record(pc *PipelineContext, dq dbQuery) *DBQueryStats {
+	r.once.Do(func() {
+		if r.app == nil {
+			return
+		}
+		for _, match := range FindAllByInterface[*DBQueryInsights](r.app.SvcRegistry()) {
+			if match.Service.Covers(dq.database) {
+				r.insights = match.Service
+				break
+			}
+		}
+	})
+	if r.insights != nil {
+		return r.insights.observe(pc, r.step, dq)
+	}
+	tokens := tokenizeSQL(dq.query)
+	return &DBQueryStats{
+		Database:  dq.database,
+		Operation: sqlOperation(tokens),
+		Statement: redactSQLStatement(tokens, SensitiveFieldPatterns),
+		Duration:  dq.duration,
+		Rows:      dq.rows,
+	}
+}
+
+// dbQueryEventData returns the "db_query" field of a step.completed event.
+func dbQueryEventData(s *DBQueryStats) map[string]any {
+	return map[string]any{
+		"database":    s.Database,
+		"operation":   s.Operation,
+		"statement":   s.Statement,
+		"duration_ms": durationMs(s.Duration),
+		"rows":        s.Rows,
+	}
+}
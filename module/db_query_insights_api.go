@@ -0,0 +1,129 @@
+package module
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// DefaultDBQueryWindow is the window the top queries endpoint summarizes
+// unless the request asks for another one.
+const DefaultDBQueryWindow = 15 * time.Minute
+
+// DefaultDBQueryLimit is how many statements each top queries list and the
+// slow-query endpoint return unless the request asks for another count.
+const DefaultDBQueryLimit = 10
+
+// maxDBQueryLimit bounds the ?limit= query parameter.
+const maxDBQueryLimit = 100
+
+// DBQueryInsightsHandler serves the statement summaries and slow-query logs
+// of the running engine's database.query_insights modules.
+type DBQueryInsightsHandler struct {
+	insights func() []*DBQueryInsights
+}
+
+// NewDBQueryInsightsHandler creates a DBQueryInsightsHandler. insights is
+// called on every request so the handler follows engine reloads.
+func NewDBQueryInsightsHandler(insights func() []*DBQueryInsights) *DBQueryInsightsHandler {
+	return &DBQueryInsightsHandler{insights: insights}
+}
+
+// RegisterRoutes registers the database query routes on the given mux.
+func (h *DBQueryInsightsHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/admin/db/queries", h.handleTopQueries)
+	mux.HandleFunc("GET /api/v1/admin/db/slow-queries", h.handleSlowQueries)
+}
+
+// handleTopQueries returns the statements with the most total time and the
+// most runs over ?window=, grouped by normalized statement.
+func (h *DBQueryInsightsHandler) handleTopQueries(w http.ResponseWriter, r *http.Request) {
+	limit, ok := dbQueryLimit(w, r)
+	if !ok {
+		return
+	}
+	window := DefaultDBQueryWindow
+	if v := r.URL.Query().Get("window"); v != "" {
+		parsed, err := time.ParseDuration(v)
+		if err != nil || parsed <= 0 {
+			writeJSONError(w, http.StatusBadRequest, "window must be a positive duration such as 15m")
+			return
+		}
+		window = parsed
+	}
+	database := r.URL.Query().Get("database")
+
+	var summaries []DBStatementSummary
+	for _, q := range h.insights() {
+		for _, s := range q.Statements(window) {
+			if database == "" || s.Database == database {
+				summaries = append(summaries, s)
+			}
+		}
+	}
+	byTotal := topDBStatements(summaries, limit, func(a, b DBStatementSummary) int { return cmp.Compare(b.TotalMs, a.TotalMs) })
+	byCount := topDBStatements(summaries, limit, func(a, b DBStatementSummary) int { return cmp.Compare(b.Count, a.Count) })
+
+	w.Header().Set("Content-Type", "application/json")
+	writeJSON(w, http.StatusOK, map[string]any{
+		"window":      window.String(),
+		"byTotalTime": byTotal,
+		"byCount":     byCount,
+		"total":       len(summaries),
+	})
+}
+
+// topDBStatements returns the first limit summaries in the order of cmp,
+// breaking ties by statement.
+func topDBStatements(summaries []DBStatementSummary, limit int, order func(a, b DBStatementSummary) int) []DBStatementSummary {
+	sorted := slices.Clone(summaries)
+	slices.SortFunc(sorted, func(a, b DBStatementSummary) int {
+		return cmp.Or(order(a, b), cmp.Compare(a.Database, b.Database), cmp.Compare(a.Statement, b.Statement))
+	})
+	if sorted == nil {
+		sorted = []DBStatementSummary{}
+	}
+	return sorted[:min(limit, len(sorted))]
+}
+
+// handleSlowQueries returns the most recent slow queries, newest first.
+func (h *DBQueryInsightsHandler) handleSlowQueries(w http.ResponseWriter, r *http.Request) {
+	limit, ok := dbQueryLimit(w, r)
+	if !ok {
+		return
+	}
+	database := r.URL.Query().Get("database")
+
+	queries := make([]SlowQuery, 0)
+	for _, q := range h.insights() {
+		for _, s := range q.SlowQueries() {
+			if database == "" || s.Database == database {
+				queries = append(queries, s)
+			}
+		}
+	}
+	slices.SortStableFunc(queries, func(a, b SlowQuery) int { return b.At.Compare(a.At) })
+	total := len(queries)
+
+	w.Header().Set("Content-Type", "application/json")
+	writeJSON(w, http.StatusOK, map[string]any{
+		"queries": queries[:min(limit, total)],
+		"total":   total,
+	})
+}
+
+// dbQueryLimit parses ?limit=, writing a 400 response when it is invalid.
+func dbQueryLimit(w http.ResponseWriter, r *http.Request) (int, bool) {
+	v := r.URL.Query().Get("limit")
+	if v == "" {
+		return DefaultDBQueryLimit, true
+	}
+	limit, err := strconv.Atoi(v)
+	if err != nil || limit < 1 || limit > maxDBQueryLimit {
+		writeJSONError(w, http.StatusBadRequest, "limit must be an integer between 1 and "+strconv.Itoa(maxDBQueryLimit))
+		return 0, false
+	}
+	return limit, true
+}
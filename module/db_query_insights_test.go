@@ -0,0 +1,305 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestDBQueryInsights registers a database.query_insights module and a
+// SQLite "db" service with the companies test data on a mock application.
+func newTestDBQueryInsights(t *testing.T, cfg DBQueryInsightsConfig) (*MockApplication, *DBQueryInsights) {
+	t.Helper()
+	app := mockAppWithDBDriver("db", setupTestDB(t), "sqlite")
+	insights := NewDBQueryInsights("insights", cfg)
+	if err := insights.Init(app); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	t.Cleanup(func() { _ = insights.Stop(context.Background()) })
+	return app, insights
+}
+
+func runDBStep(t *testing.T, factory StepFactory, app *MockApplication, config map[string]any, current map[string]any) *StepResult {
+	t.Helper()
+	step, err := factory("lookup", config, app)
+	if err != nil {
+		t.Fatalf("factory error: %v", err)
+	}
+	result, err := step.Execute(context.Background(), NewPipelineContext(current, map[string]any{"pipeline": "orders", "execution_id": "exec-1"}))
+	if err != nil {
+		t.Fatalf("execute error: %v", err)
+	}
+	return result
+}
+
+func TestDBQueryInsights_RecordsStepStatements(t *testing.T) {
+	cfg := DefaultDBQueryInsightsConfig()
+	cfg.SlowThreshold = time.Nanosecond
+	app, insights := newTestDBQueryInsights(t, cfg)
+
+	for _, id := range []string{"c1", "c2", "missing"} {
+		result := runDBStep(t, NewDBQueryStepFactory(), app, map[string]any{
+			"database": "db",
+			"query":    "SELECT id, name FROM companies WHERE id = $1 AND owner_id <> 'x'",
+			"params":   []any{"{{ .id }}"},
+		}, map[string]any{"id": id})
+		q := result.DBQuery
+		if q == nil || q.Database != "db" || q.Operation != "select" || q.Duration <= 0 {
+			t.Fatalf("unexpected stats: %+v", q)
+		}
+		if q.Statement != "SELECT id, name FROM companies WHERE id = ? AND owner_id <> 'x'" || strings.Contains(q.Statement, id) {
+			t.Errorf("unexpected statement %q", q.Statement)
+		}
+	}
+	result := runDBStep(t, NewDBExecStepFactory(), app, map[string]any{
+		"database": "db",
+		"query":    "UPDATE companies SET name = $1 WHERE slug LIKE 'a%'",
+		"params":   []any{"Renamed"},
+	}, nil)
+	if q := result.DBQuery; q == nil || q.Operation != "update" || q.Rows != 2 {
+		t.Fatalf("unexpected exec stats: %+v", q)
+	}
+
+	summaries := insights.Statements(time.Hour)
+	if len(summaries) != 2 {
+		t.Fatalf("expected 2 statements, got %+v", summaries)
+	}
+	for _, s := range summaries {
+		switch s.Operation {
+		case "select":
+			if s.Count != 3 || s.Rows != 2 || s.Statement != "SELECT id, name FROM companies WHERE id = ? AND owner_id <> ?" || s.Steps[0] != "orders/lookup" {
+				t.Errorf("unexpected select summary: %+v", s)
+			}
+		case "update":
+			if s.Count != 1 || s.Rows != 2 || s.AvgMs != s.TotalMs {
+				t.Errorf("unexpected update summary: %+v", s)
+			}
+		default:
+			t.Errorf("unexpected summary: %+v", s)
+		}
+	}
+
+	slow := insights.SlowQueries()
+	if len(slow) != 4 || slow[0].Operation != "update" || slow[0].ExecutionID != "exec-1" || slow[0].Pipeline != "orders" {
+		t.Fatalf("unexpected slow queries: %+v", slow)
+	}
+	if slow[0].Plan != nil || slow[0].PlanError != "" {
+		t.Errorf("plans are only captured for PostgreSQL: %+v", slow[0])
+	}
+}
+
+func TestDBQueryInsights_Sampling(t *testing.T) {
+	cfg := DefaultDBQueryInsightsConfig()
+	cfg.SampleRate = 0
+	cfg.SlowSampleRate = 0
+	cfg.SlowThreshold = time.Nanosecond
+	app, insights := newTestDBQueryInsights(t, cfg)
+
+	result := runDBStep(t, NewDBQueryStepFactory(), app, map[string]any{"database": "db", "query": "SELECT id FROM companies"}, nil)
+	if result.DBQuery != nil || len(insights.Statements(time.Hour)) != 0 || len(insights.SlowQueries()) != 0 {
+		t.Fatalf("expected nothing recorded at rate 0: %+v", result.DBQuery)
+	}
+
+	// Outside the module's databases, and without any module, every
+	// statement is recorded on the step result.
+	cfg.Databases = []string{"other"}
+	app, _ = newTestDBQueryInsights(t, cfg)
+	db := app.Services["db"].(DBProvider).DB()
+	if _, err := db.Exec("ALTER TABLE companies ADD COLUMN password TEXT"); err != nil {
+		t.Fatal(err)
+	}
+	result = runDBStep(t, NewDBQueryStepFactory(), app, map[string]any{"database": "db", "query": "SELECT id FROM companies WHERE password IS NULL OR password = 'p'"}, nil)
+	if result.DBQuery == nil || result.DBQuery.Rows != 3 || !strings.Contains(result.DBQuery.Statement, "'[REDACTED]'") {
+		t.Fatalf("expected redacted stats without a covering module: %+v", result.DBQuery)
+	}
+}
+
+func TestDBQueryInsights_WindowAndStatementLimit(t *testing.T) {
+	cfg := DefaultDBQueryInsightsConfig()
+	cfg.MaxStatements = 1
+	cfg.Retention = 10 * time.Minute
+	insights := NewDBQueryInsights("insights", cfg)
+	now := time.Date(2026, 10, 19, 12, 0, 30, 0, time.UTC)
+	insights.now = func() time.Time { return now }
+
+	pc := NewPipelineContext(nil, map[string]any{"pipeline": "p"})
+	for _, q := range []string{"SELECT 1", "SELECT a FROM t", "SELECT b FROM t"} {
+		insights.observe(pc, "s", dbQuery{database: "db", query: q, duration: time.Millisecond})
+	}
+	summaries := insights.Statements(time.Minute)
+	if len(summaries) != 2 {
+		t.Fatalf("expected a statement and (other), got %+v", summaries)
+	}
+	for _, s := range summaries {
+		if s.Statement == DBQueryOtherStatement && s.Count != 2 {
+			t.Errorf("expected 2 statements grouped as other: %+v", s)
+		}
+	}
+
+	now = now.Add(5 * time.Minute)
+	if got := insights.Statements(time.Minute); len(got) != 0 {
+		t.Errorf("expected the old minute outside a 1m window, got %+v", got)
+	}
+	if got := insights.Statements(time.Hour); len(got) != 2 {
+		t.Errorf("expected the old minute inside the retention, got %+v", got)
+	}
+	now = now.Add(10 * time.Minute)
+	insights.observe(pc, "s", dbQuery{database: "db", query: "SELECT 1", duration: time.Millisecond})
+	if got := insights.Statements(time.Hour); len(got) != 1 || got[0].Count != 1 {
+		t.Errorf("expected minutes past the retention dropped, got %+v", got)
+	}
+}
+
+func TestDBQueryInsights_Metrics(t *testing.T) {
+	app, insights := newTestDBQueryInsights(t, DefaultDBQueryInsightsConfig())
+	metrics := NewMetricsCollector("metrics")
+	app.Services["metrics.collector"] = metrics
+	if err := insights.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	// A second module shares the registered metrics.
+	second := NewDBQueryInsights("second", DefaultDBQueryInsightsConfig())
+	if err := second.Init(app); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	if err := second.Start(context.Background()); err != nil {
+		t.Fatalf("second Start: %v", err)
+	}
+
+	runDBStep(t, NewDBQueryStepFactory(), app, map[string]any{"database": "db", "query": "SELECT id FROM companies"}, nil)
+
+	families, err := metrics.Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+	for _, f := range families {
+		if f.GetName() == "workflow_db_query_duration_seconds" {
+			m := f.GetMetric()[0]
+			if m.GetHistogram().GetSampleCount() != 1 || m.GetLabel()[0].GetValue() != "db" {
+				t.Errorf("unexpected histogram: %v", m)
+			}
+			return
+		}
+	}
+	t.Fatal("db_query_duration_seconds not exported")
+}
+
+func TestDBQueryInsights_InvalidSampleRate(t *testing.T) {
+	cfg := DefaultDBQueryInsightsConfig()
+	cfg.SampleRate = 1.5
+	if err := NewDBQueryInsights("insights", cfg).Init(NewMockApplication()); err == nil || !strings.Contains(err.Error(), "sampleRate") {
+		t.Fatalf("expected sampleRate error, got %v", err)
+	}
+}
+
+func TestDBQueryInsightsHandler(t *testing.T) {
+	cfg := DefaultDBQueryInsightsConfig()
+	cfg.SlowThreshold = 50 * time.Millisecond
+	insights := NewDBQueryInsights("insights", cfg)
+	pc := NewPipelineContext(nil, map[string]any{"pipeline": "p"})
+	for _, run := range []struct {
+		query    string
+		duration time.Duration
+	}{
+		{"SELECT a FROM t WHERE id = 1", 100 * time.Millisecond},
+		{"SELECT b FROM t", time.Millisecond},
+		{"SELECT b FROM t", time.Millisecond},
+		{"SELECT a FROM t WHERE id = 2", time.Millisecond},
+		{"SELECT b FROM t", time.Millisecond},
+	} {
+		insights.observe(pc, "s", dbQuery{database: "db", query: run.query, duration: run.duration})
+	}
+
+	mux := http.NewServeMux()
+	NewDBQueryInsightsHandler(func() []*DBQueryInsights { return []*DBQueryInsights{insights} }).RegisterRoutes(mux)
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	w := get("/api/v1/admin/db/queries?limit=1&window=5m")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+	var top struct {
+		Window      string               `json:"window"`
+		ByTotalTime []DBStatementSummary `json:"byTotalTime"`
+		ByCount     []DBStatementSummary `json:"byCount"`
+		Total       int                  `json:"total"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &top); err != nil {
+		t.Fatal(err)
+	}
+	if top.Window != "5m0s" || top.Total != 2 || len(top.ByTotalTime) != 1 || len(top.ByCount) != 1 {
+		t.Fatalf("unexpected response: %s", w.Body.String())
+	}
+	if top.ByTotalTime[0].Statement != "SELECT a FROM t WHERE id = ?" || top.ByCount[0].Statement != "SELECT b FROM t" || top.ByCount[0].Count != 3 {
+		t.Errorf("unexpected ordering: %s", w.Body.String())
+	}
+
+	w = get("/api/v1/admin/db/slow-queries")
+	var slow struct {
+		Queries []SlowQuery `json:"queries"`
+		Total   int         `json:"total"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &slow); err != nil {
+		t.Fatal(err)
+	}
+	if slow.Total != 1 || slow.Queries[0].DurationMs != 100 || slow.Queries[0].Statement != "SELECT a FROM t WHERE id = 1" {
+		t.Errorf("unexpected slow queries: %s", w.Body.String())
+	}
+
+	if w := get("/api/v1/admin/db/queries?database=other"); !strings.Contains(w.Body.String(), `"total":0`) {
+		t.Errorf("expected no statements for another database: %s", w.Body.String())
+	}
+	for _, path := range []string{"/api/v1/admin/db/queries?window=soon", "/api/v1/admin/db/slow-queries?limit=0"} {
+		if w := get(path); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", path, w.Code)
+		}
+	}
+}
+
+func TestPipeline_EventRecorder_RecordsDBQuery(t *testing.T) {
+	recorder := &mockEventRecorder{}
+	app := mockAppWithDBDriver("db", setupTestDB(t), "sqlite")
+	step, err := NewDBQueryStepFactory()("lookup", map[string]any{
+		"database": "db",
+		"query":    "SELECT id FROM companies WHERE slug = $1",
+		"params":   []any{"acme"},
+	}, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &Pipeline{Name: "orders", Steps: []PipelineStep{step}, EventRecorder: recorder, ExecutionID: "exec-1"}
+	if _, err := p.Execute(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, e := range recorder.getEvents() {
+		if e.EventType != "step.completed" {
+			continue
+		}
+		q, ok := e.Data["db_query"].(map[string]any)
+		if !ok || q["statement"] != "SELECT id FROM companies WHERE slug = ?" || q["rows"] != int64(1) || q["operation"] != "select" {
+			t.Fatalf("unexpected db_query event data: %v", e.Data)
+		}
+		return
+	}
+	t.Fatal("no step.completed event")
+}
+
+func TestNormalizePlanStrings(t *testing.T) {
+	var plan any
+	if err := json.Unmarshal([]byte(`[{"Plan": {"Node Type": "Seq Scan", "Relation Name": "users", "Total Cost": 12.5, "Filter": "((email)::text = 'bob@example.com'::text)"}}]`), &plan); err != nil {
+		t.Fatal(err)
+	}
+	node := normalizePlanStrings(plan).([]any)[0].(map[string]any)["Plan"].(map[string]any)
+	if node["Filter"] != "((email)::text = ?::text)" || node["Node Type"] != "Seq Scan" || node["Total Cost"] != 12.5 {
+		t.Errorf("unexpected plan node: %v", node)
+	}
+}
@@ -55,6 +55,13 @@ func (m *DLQServiceModule) Name() string { return m.name }
 // Init implements modular.Module.
 func (m *DLQServiceModule) Init(_ modular.Application) error { return nil }
 
+// Service name suffixes under which a DLQ service module named <name> also
+// registers its admin handler mux and its store, e.g. "dlq.admin".
+const (
+	DLQAdminServiceSuffix = ".admin"
+	DLQStoreServiceSuffix = ".store"
+)
+
 // ProvidesServices implements modular.Module. The DLQ handler mux is registered
 // under the module name and also under {name}.admin for admin route delegation.
 func (m *DLQServiceModule) ProvidesServices() []modular.ServiceProvider {
@@ -65,12 +72,12 @@ func (m *DLQServiceModule) ProvidesServices() []modular.ServiceProvider {
 			Instance:    m.mux,
 		},
 		{
-			Name:        m.name + ".admin",
+			Name:        m.name + DLQAdminServiceSuffix,
 			Description: "DLQ admin handler: " + m.name,
 			Instance:    http.Handler(m.mux),
 		},
 		{
-			Name:        m.name + ".store",
+			Name:        m.name + DLQStoreServiceSuffix,
 			Description: "DLQ store: " + m.name,
 			Instance:    m.store,
 		},
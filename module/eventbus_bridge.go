@@ -21,6 +21,7 @@ type EventBusBridge struct {
 	eventBus      *eventbus.EventBusModule
 	subscriptions map[string]eventbus.Subscription
 	mu            sync.RWMutex
+	topicSchemaGuard
 }
 
 // NewEventBusBridge creates a new EventBusBridge with the given name.
@@ -86,6 +87,9 @@ func (b *EventBusBridge) SendMessage(topic string, message []byte) error {
 	if eb == nil {
 		return nil
 	}
+	if err := b.checkPublish(topic, message); err != nil {
+		return err
+	}
 
 	var payload any
 	if err := json.Unmarshal(message, &payload); err != nil {
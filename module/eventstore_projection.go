@@ -0,0 +1,989 @@
+package module
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow/interfaces"
+	evstore "github.com/GoCodeAlone/workflow/store"
+)
+
+// Projection states reported by ProjectionStatus.
+const (
+	ProjectionStateStarting   = "starting"
+	ProjectionStateRunning    = "running"
+	ProjectionStateRebuilding = "rebuilding"
+	ProjectionStateFailing    = "failing"
+	ProjectionStateStopped    = "stopped"
+)
+
+// projectionCheckpointTable holds one row per projection in the target
+// database: the last event position folded into the projection's table and
+// the hash of the definition that produced it.
+const projectionCheckpointTable = "workflow_projection_checkpoints"
+
+// ErrProjectionRebuilding is returned by Rebuild while a rebuild of the
+// projection is already running.
+var ErrProjectionRebuilding = errors.New("projection rebuild already in progress")
+
+// validColumnType matches the column types a projection may declare, such
+// as TEXT, INTEGER, REAL, NUMERIC(10,2) or DOUBLE PRECISION.
+var validColumnType = regexp.MustCompile(`^[A-Za-z][A-Za-z ]*(\(\d+(,\s*\d+)?\))?$`)
+
+// ProjectionStep is one step of a projection's reduce step list.
+type ProjectionStep struct {
+	Name   string
+	Type   string
+	Config map[string]any
+}
+
+// ProjectionConfig holds the configuration for the eventstore.projection
+// module.
+type ProjectionConfig struct {
+	// EventStore names the event store events are read from; the first
+	// event store when empty.
+	EventStore string
+	// Database names the database service the projection table lives in.
+	Database string
+	// Table is the projection table. Its primary key column is "id", filled
+	// from Key; the other columns are Columns.
+	Table string
+	// Key is a template resolving to the row an event folds into. Events
+	// whose key resolves to "" are skipped.
+	Key     string
+	Columns map[string]string
+	// Events and Pipelines filter the events folded into the projection:
+	// event types (path.Match patterns such as "step.*") and pipeline
+	// names. Empty filters match every event.
+	Events    []string
+	Pipelines []string
+	// Steps, or else Component, reduce an event into the row.
+	Steps        []ProjectionStep
+	Component    string
+	PollInterval time.Duration
+	BatchSize    int
+}
+
+// ParseProjectionConfig reads an eventstore.projection config map and
+// applies the defaults.
+func ParseProjectionConfig(raw map[string]any) (ProjectionConfig, error) {
+	cfg := ProjectionConfig{
+		PollInterval: time.Second,
+		BatchSize:    500,
+	}
+	cfg.EventStore, _ = raw["event_store"].(string)
+	cfg.Database, _ = raw["database"].(string)
+	if cfg.Database == "" {
+		return cfg, fmt.Errorf("'database' is required")
+	}
+	cfg.Table, _ = raw["table"].(string)
+	if cfg.Table == "" {
+		return cfg, fmt.Errorf("'table' is required")
+	}
+	if err := validateIdentifier(cfg.Table); err != nil {
+		return cfg, fmt.Errorf("'table': %w", err)
+	}
+	cfg.Key, _ = raw["key"].(string)
+	if cfg.Key == "" {
+		return cfg, fmt.Errorf("'key' is required")
+	}
+
+	rawColumns, _ := raw["columns"].(map[string]any)
+	if len(rawColumns) == 0 {
+		return cfg, fmt.Errorf("'columns' is required")
+	}
+	cfg.Columns = make(map[string]string, len(rawColumns))
+	for col, v := range rawColumns {
+		typ, _ := v.(string)
+		if err := validateIdentifier(col); err != nil || strings.Contains(col, ".") {
+			return cfg, fmt.Errorf("columns: invalid column name %q", col)
+		}
+		if strings.EqualFold(col, "id") {
+			return cfg, fmt.Errorf("columns: %q is the key column and cannot be declared", col)
+		}
+		if !validColumnType.MatchString(typ) {
+			return cfg, fmt.Errorf("columns: invalid type %q for column %q", typ, col)
+		}
+		cfg.Columns[col] = strings.ToUpper(typ)
+	}
+
+	cfg.Events = projectionStrings(raw["events"])
+	for _, pattern := range cfg.Events {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return cfg, fmt.Errorf("events: invalid pattern %q", pattern)
+		}
+	}
+	cfg.Pipelines = projectionStrings(raw["pipelines"])
+
+	rawSteps, _ := raw["steps"].([]any)
+	for i, item := range rawSteps {
+		sm, ok := item.(map[string]any)
+		if !ok {
+			return cfg, fmt.Errorf("steps[%d] must be a map", i)
+		}
+		step := ProjectionStep{}
+		step.Name, _ = sm["name"].(string)
+		step.Type, _ = sm["type"].(string)
+		step.Config, _ = sm["config"].(map[string]any)
+		if step.Name == "" || step.Type == "" {
+			return cfg, fmt.Errorf("steps[%d]: 'name' and 'type' are required", i)
+		}
+		if step.Config == nil {
+			step.Config = map[string]any{}
+		}
+		cfg.Steps = append(cfg.Steps, step)
+	}
+	cfg.Component, _ = raw["component"].(string)
+	if (len(cfg.Steps) == 0) == (cfg.Component == "") {
+		return cfg, fmt.Errorf("exactly one of 'steps' or 'component' is required")
+	}
+
+	if v, _ := raw["poll_interval"].(string); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return cfg, fmt.Errorf("invalid 'poll_interval' %q", v)
+		}
+		cfg.PollInterval = d
+	}
+	if v, ok := raw["batch_size"]; ok {
+		n, ok := toFloat64(v)
+		if !ok || n < 1 {
+			return cfg, fmt.Errorf("'batch_size' must be a positive number")
+		}
+		cfg.BatchSize = int(n)
+	}
+	return cfg, nil
+}
+
+func projectionStrings(v any) []string {
+	switch list := v.(type) {
+	case string:
+		return []string{list}
+	case []string:
+		return list
+	case []any:
+		out := make([]string, 0, len(list))
+		for _, item := range list {
+			if s, ok := item.(string); ok && s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// definitionHash identifies everything that shapes the projection's table
+// contents. A checkpoint written under another hash belongs to an older
+// definition, and the table must be rebuilt.
+func (c ProjectionConfig) definitionHash() string {
+	steps := make([]map[string]any, len(c.Steps))
+	for i, s := range c.Steps {
+		steps[i] = map[string]any{"name": s.Name, "type": s.Type, "config": s.Config}
+	}
+	data, _ := json.Marshal(map[string]any{
+		"table":     c.Table,
+		"key":       c.Key,
+		"columns":   c.Columns,
+		"events":    c.Events,
+		"pipelines": c.Pipelines,
+		"steps":     steps,
+		"component": c.Component,
+	})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// columnNames returns the declared columns in a stable order.
+func (c ProjectionConfig) columnNames() []string {
+	cols := make([]string, 0, len(c.Columns))
+	for col := range c.Columns {
+		cols = append(cols, col)
+	}
+	sort.Strings(cols)
+	return cols
+}
+
+// ProjectionStatus reports how far a projection has caught up with the
+// event log. Lag is the number of log positions between the projection's
+// checkpoint and the head of the log.
+type ProjectionStatus struct {
+	Name     string `json:"name"`
+	Table    string `json:"table"`
+	State    string `json:"state"`
+	Position int64  `json:"position"`
+	Head     int64  `json:"head"`
+	Lag      int64  `json:"lag"`
+	// Progress is the fraction (0-1) of the event log replayed by a running
+	// rebuild.
+	Progress  float64    `json:"progress,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// ProjectionReporter is implemented by modules that maintain projections,
+// so the engine can include them in its status.
+type ProjectionReporter interface {
+	ProjectionStatus() ProjectionStatus
+}
+
+// ProjectionModule maintains a materialized view of the execution event
+// log: it folds matching events, in log order, into rows of a table in a
+// database service, through a reduce step list or a dynamic component.
+// The table is updated in the same transaction as the projection's
+// checkpoint, so a restart resumes after the last folded event without
+// folding any event twice.
+//
+// A checkpoint written by a different definition (columns, key, filter or
+// reducer) makes Start drop the table and replay the log from the start,
+// with a warning, rather than mixing rows of both definitions. Rebuild
+// does the same on demand.
+type ProjectionModule struct {
+	name      string
+	config    ProjectionConfig
+	configErr error
+	hash      string
+
+	app      modular.Application
+	logger   modular.Logger
+	registry interfaces.StepRegistrar
+	log      evstore.EventLog
+	db       *sql.DB
+	driver   string
+	steps    []PipelineStep
+	reducer  Executor
+	tmpl     *TemplateEngine
+
+	runMu sync.Mutex // serializes batches and table resets
+
+	mu            sync.Mutex // guards the fields below
+	position      int64
+	head          int64
+	state         string
+	lastErr       string
+	updatedAt     time.Time
+	rebuilding    bool  // the table is being replayed from the start
+	rebuildCall   bool  // a Rebuild call is running
+	rebuildTarget int64 // head of the log when the rebuild started
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wake   chan struct{}
+	wg     sync.WaitGroup
+	now    func() time.Time
+}
+
+// NewProjectionModule creates an eventstore.projection module from its
+// config map. Config errors are reported by Init.
+func NewProjectionModule(name string, raw map[string]any) *ProjectionModule {
+	cfg, err := ParseProjectionConfig(raw)
+	m := &ProjectionModule{
+		name:      name,
+		config:    cfg,
+		configErr: err,
+		logger:    &noopLogger{},
+		tmpl:      NewTemplateEngine(),
+		state:     ProjectionStateStarting,
+		wake:      make(chan struct{}, 1),
+		now:       time.Now,
+	}
+	if err == nil {
+		m.hash = cfg.definitionHash()
+	}
+	return m
+}
+
+// Name implements modular.Module.
+func (m *ProjectionModule) Name() string { return m.name }
+
+// Table returns the name of the projection table.
+func (m *ProjectionModule) Table() string { return m.config.Table }
+
+// SetStepRegistry sets the registry the reduce steps are created from. It
+// must be called before Start when the projection uses steps.
+func (m *ProjectionModule) SetStepRegistry(registry interfaces.StepRegistrar) {
+	m.registry = registry
+}
+
+// SetEventLog overrides the event log events are read from. It must be
+// called before Start.
+func (m *ProjectionModule) SetEventLog(log evstore.EventLog) { m.log = log }
+
+// Init implements modular.Module.
+func (m *ProjectionModule) Init(app modular.Application) error {
+	if m.configErr != nil {
+		return fmt.Errorf("eventstore.projection %q: %w", m.name, m.configErr)
+	}
+	m.app = app
+	m.logger = app.Logger()
+	return nil
+}
+
+// Start resolves the event store, database and reducer, loads the
+// checkpoint (rebuilding the table when the definition changed) and starts
+// folding new events.
+func (m *ProjectionModule) Start(ctx context.Context) error {
+	if err := m.resolve(); err != nil {
+		return fmt.Errorf("eventstore.projection %q: %w", m.name, err)
+	}
+	if err := m.load(ctx); err != nil {
+		return fmt.Errorf("eventstore.projection %q: %w", m.name, err)
+	}
+
+	m.ctx, m.cancel = context.WithCancel(context.Background())
+	m.wg.Add(1)
+	go m.run()
+	m.logger.Info("Projection started", "projection", m.name, "table", m.config.Table, "position", m.Position())
+	return nil
+}
+
+// Stop stops folding events and waits for a running batch or rebuild.
+func (m *ProjectionModule) Stop(_ context.Context) error {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.wg.Wait()
+	m.mu.Lock()
+	m.state = ProjectionStateStopped
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *ProjectionModule) resolve() error {
+	registry := m.app.SvcRegistry()
+	if m.log == nil {
+		if m.config.EventStore != "" {
+			log, ok := registry[m.config.EventStore].(evstore.EventLog)
+			if !ok {
+				return fmt.Errorf("event store %q not found or cannot be read as a log", m.config.EventStore)
+			}
+			m.log = log
+		} else {
+			match, err := FindByInterface[evstore.EventLog](registry)
+			if err != nil {
+				return fmt.Errorf("event store: %w", err)
+			}
+			m.log = match.Service
+		}
+	}
+
+	provider, ok := registry[m.config.Database].(DBProvider)
+	if !ok {
+		return fmt.Errorf("database %q not found or does not provide a *sql.DB", m.config.Database)
+	}
+	m.db = provider.DB()
+	if m.db == nil {
+		return fmt.Errorf("database %q is not connected", m.config.Database)
+	}
+	if dp, ok := provider.(DBDriverProvider); ok {
+		m.driver = dp.DriverName()
+	}
+
+	if m.config.Component != "" {
+		exec, ok := registry[m.config.Component].(Executor)
+		if !ok {
+			return fmt.Errorf("component %q not found", m.config.Component)
+		}
+		m.reducer = exec
+		return nil
+	}
+	if m.registry == nil {
+		return fmt.Errorf("no step registry to create reduce steps from")
+	}
+	m.steps = m.steps[:0]
+	for _, s := range m.config.Steps {
+		step, err := m.registry.Create(s.Type, s.Name, s.Config, m.app)
+		if err != nil {
+			return fmt.Errorf("step %q: %w", s.Name, err)
+		}
+		m.steps = append(m.steps, step)
+	}
+	return nil
+}
+
+// load creates the checkpoint table and reads the projection's checkpoint.
+// A missing checkpoint creates the projection table; a checkpoint of
+// another definition, or one ahead of the log, resets it for a rebuild.
+func (m *ProjectionModule) load(ctx context.Context) error {
+	if _, err := m.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+projectionCheckpointTable+` (
+		name            TEXT PRIMARY KEY,
+		position        BIGINT NOT NULL,
+		definition_hash TEXT NOT NULL,
+		updated_at      TEXT NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("create checkpoint table: %w", err)
+	}
+	head, err := m.log.HeadPosition(ctx)
+	if err != nil {
+		return err
+	}
+
+	var position int64
+	var hash string
+	err = m.db.QueryRowContext(ctx, m.sql(`SELECT position, definition_hash FROM `+projectionCheckpointTable+` WHERE name = $1`), m.name).
+		Scan(&position, &hash)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		if err := m.reset(ctx, false); err != nil {
+			return err
+		}
+		position = 0
+	case err != nil:
+		return fmt.Errorf("load checkpoint: %w", err)
+	case hash != m.hash:
+		m.logger.Warn("Projection definition changed; dropping the table and rebuilding it from the event log",
+			"projection", m.name, "table", m.config.Table)
+		if err := m.reset(ctx, true); err != nil {
+			return err
+		}
+		m.startRebuild(head)
+		position = 0
+	case position > head:
+		m.logger.Warn("Projection checkpoint is ahead of the event log; dropping the table and rebuilding it",
+			"projection", m.name, "position", position, "head", head)
+		if err := m.reset(ctx, true); err != nil {
+			return err
+		}
+		m.startRebuild(head)
+		position = 0
+	}
+
+	m.mu.Lock()
+	m.position = position
+	m.head = head
+	if !m.rebuilding {
+		m.state = ProjectionStateRunning
+	}
+	m.mu.Unlock()
+	return nil
+}
+
+// reset recreates the projection table, dropping it first when drop is
+// set, and points the checkpoint at the start of the log.
+func (m *ProjectionModule) reset(ctx context.Context, drop bool) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin reset: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if drop {
+		if _, err := tx.ExecContext(ctx, `DROP TABLE IF EXISTS `+m.config.Table); err != nil {
+			return fmt.Errorf("drop table %s: %w", m.config.Table, err)
+		}
+	}
+	cols := []string{"id TEXT PRIMARY KEY"}
+	for _, col := range m.config.columnNames() {
+		cols = append(cols, col+" "+m.config.Columns[col])
+	}
+	if _, err := tx.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+m.config.Table+` (`+strings.Join(cols, ", ")+`)`); err != nil {
+		return fmt.Errorf("create table %s: %w", m.config.Table, err)
+	}
+	if err := m.saveCheckpoint(ctx, tx, 0); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit reset: %w", err)
+	}
+	m.mu.Lock()
+	m.position = 0
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *ProjectionModule) saveCheckpoint(ctx context.Context, tx *sql.Tx, position int64) error {
+	_, err := tx.ExecContext(ctx, m.sql(`INSERT INTO `+projectionCheckpointTable+` (name, position, definition_hash, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (name) DO UPDATE SET position = excluded.position,
+			definition_hash = excluded.definition_hash, updated_at = excluded.updated_at`),
+		m.name, position, m.hash, m.now().UTC().Format(time.RFC3339Nano))
+	if err != nil {
+		return fmt.Errorf("save checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (m *ProjectionModule) sql(query string) string {
+	return normalizePlaceholders(query, m.driver)
+}
+
+func (m *ProjectionModule) startRebuild(target int64) {
+	m.mu.Lock()
+	m.rebuilding = true
+	m.rebuildTarget = target
+	m.state = ProjectionStateRebuilding
+	m.mu.Unlock()
+}
+
+func (m *ProjectionModule) run() {
+	defer m.wg.Done()
+	for {
+		if err := m.CatchUp(m.ctx); err != nil && m.ctx.Err() == nil {
+			m.logger.Error("Projection update failed", "projection", m.name, "error", err)
+		}
+		timer := time.NewTimer(m.config.PollInterval)
+		select {
+		case <-m.ctx.Done():
+			timer.Stop()
+			return
+		case <-m.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// CatchUp folds events into the projection in batches until it reaches
+// the head of the event log.
+func (m *ProjectionModule) CatchUp(ctx context.Context) error {
+	for {
+		n, err := m.processBatch(ctx)
+		if err != nil {
+			m.mu.Lock()
+			m.lastErr = err.Error()
+			m.state = ProjectionStateFailing
+			m.mu.Unlock()
+			return err
+		}
+		if n == 0 {
+			m.mu.Lock()
+			m.lastErr = ""
+			m.state = ProjectionStateRunning
+			m.mu.Unlock()
+			return nil
+		}
+	}
+}
+
+// projectionRow is a row of the projection table read or written while
+// folding a batch.
+type projectionRow struct {
+	values map[string]any // nil when the row does not exist
+	dirty  bool
+}
+
+// processBatch folds the next batch of events and advances the
+// checkpoint in the same transaction. It returns the number of events
+// read from the log.
+func (m *ProjectionModule) processBatch(ctx context.Context) (int, error) {
+	m.runMu.Lock()
+	defer m.runMu.Unlock()
+
+	m.mu.Lock()
+	after := m.position
+	m.mu.Unlock()
+
+	events, err := m.log.ReadEvents(ctx, after, m.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	head, err := m.log.HeadPosition(ctx)
+	if err != nil {
+		return 0, err
+	}
+	m.mu.Lock()
+	m.head = head
+	m.mu.Unlock()
+	if len(events) == 0 {
+		// Caught up: events up to the rebuild target may have been
+		// deleted from the log meanwhile.
+		m.mu.Lock()
+		if m.rebuildTarget > after {
+			m.rebuildTarget = after
+		}
+		m.mu.Unlock()
+		m.finishRebuild(after)
+		return 0, nil
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin batch: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	rows := make(map[string]*projectionRow)
+	var order []string
+	for i := range events {
+		ev := &events[i]
+		if !m.matches(ev) {
+			continue
+		}
+		if err := m.fold(ctx, tx, ev, rows, &order); err != nil {
+			return 0, fmt.Errorf("event %d (%s of execution %s): %w", ev.Position, ev.EventType, ev.ExecutionID, err)
+		}
+	}
+	for _, key := range order {
+		if row := rows[key]; row.dirty {
+			if err := m.writeRow(ctx, tx, key, row.values); err != nil {
+				return 0, err
+			}
+		}
+	}
+	last := events[len(events)-1].Position
+	if err := m.saveCheckpoint(ctx, tx, last); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit batch: %w", err)
+	}
+
+	m.mu.Lock()
+	m.position = last
+	m.updatedAt = m.now().UTC()
+	m.lastErr = ""
+	m.state = ProjectionStateRunning
+	if m.rebuilding {
+		m.state = ProjectionStateRebuilding
+	}
+	m.mu.Unlock()
+	m.logRebuildProgress(after, last)
+	return len(events), nil
+}
+
+func (m *ProjectionModule) matches(ev *evstore.LoggedEvent) bool {
+	if len(m.config.Pipelines) > 0 && !projectionContains(m.config.Pipelines, ev.Pipeline) {
+		return false
+	}
+	if len(m.config.Events) == 0 {
+		return true
+	}
+	for _, pattern := range m.config.Events {
+		if ok, _ := path.Match(pattern, ev.EventType); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func projectionContains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// fold reduces one event into the row its key selects.
+func (m *ProjectionModule) fold(ctx context.Context, tx *sql.Tx, ev *evstore.LoggedEvent, rows map[string]*projectionRow, order *[]string) error {
+	var data any = map[string]any{}
+	if len(ev.EventData) > 0 {
+		if err := json.Unmarshal(ev.EventData, &data); err != nil {
+			data = string(ev.EventData)
+		}
+	}
+	event := map[string]any{
+		"id":           ev.ID.String(),
+		"execution_id": ev.ExecutionID.String(),
+		"sequence":     ev.SequenceNum,
+		"type":         ev.EventType,
+		"data":         data,
+		"pipeline":     ev.Pipeline,
+		"position":     ev.Position,
+		"created_at":   ev.CreatedAt.UTC().Format(time.RFC3339Nano),
+	}
+	pc := NewPipelineContext(map[string]any{"event": event}, map[string]any{"projection": m.name})
+	key, err := m.tmpl.Resolve(m.config.Key, pc)
+	if err != nil {
+		return fmt.Errorf("resolve key: %w", err)
+	}
+	if key == "" || key == "<no value>" {
+		return nil
+	}
+
+	row, ok := rows[key]
+	if !ok {
+		values, err := m.readRow(ctx, tx, key)
+		if err != nil {
+			return err
+		}
+		row = &projectionRow{values: values}
+		rows[key] = row
+		*order = append(*order, key)
+	}
+
+	input := map[string]any{"event": event, "key": key, "row": nil}
+	if row.values != nil {
+		input["row"] = copyRow(row.values)
+	}
+
+	var next any
+	if m.reducer != nil {
+		out, err := m.reducer.Execute(ctx, input)
+		if err != nil {
+			return fmt.Errorf("component %q: %w", m.config.Component, err)
+		}
+		v, ok := out["row"]
+		if !ok {
+			return nil
+		}
+		next = v
+	} else {
+		pc := NewPipelineContext(input, map[string]any{"projection": m.name})
+		for _, step := range m.steps {
+			result, err := step.Execute(ctx, pc)
+			if err != nil {
+				return fmt.Errorf("step %q: %w", step.Name(), err)
+			}
+			if result != nil && result.Output != nil {
+				pc.MergeStepOutput(step.Name(), result.Output)
+			} else {
+				pc.MergeStepOutput(step.Name(), map[string]any{})
+			}
+			if result != nil && result.Stop {
+				break
+			}
+		}
+		next = pc.Current["row"]
+	}
+
+	switch v := next.(type) {
+	case nil:
+		row.values = nil
+	case map[string]any:
+		row.values = copyRow(v)
+	default:
+		return fmt.Errorf("the reduced row must be a map or null, got %T", next)
+	}
+	row.dirty = true
+	return nil
+}
+
+func copyRow(row map[string]any) map[string]any {
+	cp := make(map[string]any, len(row))
+	for k, v := range row {
+		cp[k] = v
+	}
+	return cp
+}
+
+// readRow reads the declared columns of the row with the given key, or
+// returns nil when there is none.
+func (m *ProjectionModule) readRow(ctx context.Context, tx *sql.Tx, key string) (map[string]any, error) {
+	cols := m.config.columnNames()
+	dest := make([]any, len(cols))
+	ptrs := make([]any, len(cols))
+	for i := range dest {
+		ptrs[i] = &dest[i]
+	}
+	err := tx.QueryRowContext(ctx, m.sql(`SELECT `+strings.Join(cols, ", ")+` FROM `+m.config.Table+` WHERE id = $1`), key).Scan(ptrs...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read row %q: %w", key, err)
+	}
+	row := make(map[string]any, len(cols))
+	for i, col := range cols {
+		if b, ok := dest[i].([]byte); ok {
+			row[col] = string(b)
+		} else {
+			row[col] = dest[i]
+		}
+	}
+	return row, nil
+}
+
+// writeRow upserts the row with the given key, or deletes it when values
+// is nil. Declared columns missing from values are written as NULL, and
+// maps and lists are stored as JSON.
+func (m *ProjectionModule) writeRow(ctx context.Context, tx *sql.Tx, key string, values map[string]any) error {
+	if values == nil {
+		if _, err := tx.ExecContext(ctx, m.sql(`DELETE FROM `+m.config.Table+` WHERE id = $1`), key); err != nil {
+			return fmt.Errorf("delete row %q: %w", key, err)
+		}
+		return nil
+	}
+	cols := m.config.columnNames()
+	placeholders := make([]string, 0, len(cols)+1)
+	updates := make([]string, 0, len(cols))
+	args := make([]any, 0, len(cols)+1)
+	placeholders = append(placeholders, "$1")
+	args = append(args, key)
+	for i, col := range cols {
+		placeholders = append(placeholders, fmt.Sprintf("$%d", i+2))
+		updates = append(updates, col+" = excluded."+col)
+		v := values[col]
+		switch v.(type) {
+		case map[string]any, []any:
+			data, err := json.Marshal(v)
+			if err != nil {
+				return fmt.Errorf("encode column %s: %w", col, err)
+			}
+			v = string(data)
+		}
+		args = append(args, v)
+	}
+	query := `INSERT INTO ` + m.config.Table + ` (id, ` + strings.Join(cols, ", ") + `) VALUES (` + strings.Join(placeholders, ", ") + `)
+		ON CONFLICT (id) DO UPDATE SET ` + strings.Join(updates, ", ")
+	if _, err := tx.ExecContext(ctx, m.sql(query), args...); err != nil {
+		return fmt.Errorf("write row %q: %w", key, err)
+	}
+	return nil
+}
+
+// Rebuild drops the projection table and replays the event log from the
+// start, returning once the replay has caught up with the head of the log.
+// Progress is reported by ProjectionStatus while it runs.
+func (m *ProjectionModule) Rebuild(ctx context.Context) error {
+	if m.db == nil || m.log == nil {
+		return fmt.Errorf("eventstore.projection %q: not started", m.name)
+	}
+	m.mu.Lock()
+	if m.rebuildCall {
+		m.mu.Unlock()
+		return ErrProjectionRebuilding
+	}
+	m.rebuildCall = true
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		m.rebuildCall = false
+		m.mu.Unlock()
+	}()
+
+	head, err := m.log.HeadPosition(ctx)
+	if err != nil {
+		return fmt.Errorf("eventstore.projection %q: %w", m.name, err)
+	}
+	m.runMu.Lock()
+	m.startRebuild(head)
+	err = m.reset(ctx, true)
+	m.runMu.Unlock()
+	if err != nil {
+		return fmt.Errorf("eventstore.projection %q: %w", m.name, err)
+	}
+	m.logger.Info("Projection rebuild started", "projection", m.name, "table", m.config.Table, "target", head)
+
+	if err := m.CatchUp(ctx); err != nil {
+		return fmt.Errorf("eventstore.projection %q: rebuild: %w", m.name, err)
+	}
+	return nil
+}
+
+// RebuildAsync starts Rebuild in the background; its progress and outcome
+// are reported by ProjectionStatus.
+func (m *ProjectionModule) RebuildAsync() error {
+	if m.ctx == nil {
+		return fmt.Errorf("eventstore.projection %q: not started", m.name)
+	}
+	m.mu.Lock()
+	running := m.rebuildCall
+	m.mu.Unlock()
+	if running {
+		return ErrProjectionRebuilding
+	}
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		if err := m.Rebuild(m.ctx); err != nil && !errors.Is(err, ErrProjectionRebuilding) && m.ctx.Err() == nil {
+			m.logger.Error("Projection rebuild failed", "projection", m.name, "error", err)
+		}
+	}()
+	return nil
+}
+
+// finishRebuild ends a running rebuild once the projection has caught up
+// with the head the rebuild started at.
+func (m *ProjectionModule) finishRebuild(position int64) {
+	m.mu.Lock()
+	if !m.rebuilding || position < m.rebuildTarget {
+		m.mu.Unlock()
+		return
+	}
+	m.rebuilding = false
+	m.state = ProjectionStateRunning
+	m.mu.Unlock()
+	m.logger.Info("Projection rebuild finished", "projection", m.name, "position", position)
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+// logRebuildProgress logs the progress of a running rebuild each time it
+// passes a tenth of the replay, and ends the rebuild at its target.
+func (m *ProjectionModule) logRebuildProgress(from, to int64) {
+	m.mu.Lock()
+	rebuilding, target := m.rebuilding, m.rebuildTarget
+	m.mu.Unlock()
+	if !rebuilding {
+		return
+	}
+	if target > 0 && from*10/target != to*10/target && to < target {
+		m.logger.Info("Projection rebuild progress", "projection", m.name, "position", to, "target", target,
+			"percent", to*100/target)
+	}
+	m.finishRebuild(to)
+}
+
+// Position returns the position of the last event folded into the
+// projection.
+func (m *ProjectionModule) Position() int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.position
+}
+
+// ProjectionStatus implements ProjectionReporter.
+func (m *ProjectionModule) ProjectionStatus() ProjectionStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	st := ProjectionStatus{
+		Name:      m.name,
+		Table:     m.config.Table,
+		State:     m.state,
+		Position:  m.position,
+		Head:      m.head,
+		LastError: m.lastErr,
+	}
+	if m.head > m.position {
+		st.Lag = m.head - m.position
+	}
+	if m.rebuilding {
+		st.Progress = 1
+		if m.rebuildTarget > 0 && m.position < m.rebuildTarget {
+			st.Progress = float64(m.position) / float64(m.rebuildTarget)
+		}
+	}
+	if !m.updatedAt.IsZero() {
+		t := m.updatedAt
+		st.UpdatedAt = &t
+	}
+	return st
+}
+
+// ProvidesServices implements modular.Module.
+func (m *ProjectionModule) ProvidesServices() []modular.ServiceProvider {
+	return []modular.ServiceProvider{
+		{Name: m.name, Description: "Event store projection: " + m.name, Instance: m},
+	}
+}
+
+// RequiresServices implements modular.Module.
+func (m *ProjectionModule) RequiresServices() []modular.ServiceDependency {
+	var deps []modular.ServiceDependency
+	if m.config.Database != "" {
+		deps = append(deps, modular.ServiceDependency{Name: m.config.Database, Required: true})
+	}
+	if m.config.EventStore != "" {
+		deps = append(deps, modular.ServiceDependency{Name: m.config.EventStore, Required: true})
+	}
+	if m.config.Component != "" {
+		deps = append(deps, modular.ServiceDependency{Name: m.config.Component, Required: true})
+	}
+	return deps
+}
@@ -0,0 +1,306 @@
+package module
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	evstore "github.com/GoCodeAlone/workflow/store"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+// projectionTestLogger records warnings; projections log from their
+// background goroutine, so it is safe for concurrent use.
+type projectionTestLogger struct {
+	noopLogger
+	mu    sync.Mutex
+	warns []string
+}
+
+func (l *projectionTestLogger) Warn(msg string, _ ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.warns = append(l.warns, msg)
+}
+
+func (l *projectionTestLogger) warnings() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.warns...)
+}
+
+type projectionTestEnv struct {
+	db     *sql.DB
+	events *evstore.InMemoryEventStore
+	app    *MockApplication
+}
+
+func newProjectionTestEnv(t *testing.T) *projectionTestEnv {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "views.db"))
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	env := &projectionTestEnv{db: db, events: evstore.NewInMemoryEventStore(), app: NewMockApplication()}
+	env.app.Services["db"] = &testDBDriverProvider{db: db, driver: "sqlite"}
+	env.app.Services["events"] = env.events
+	return env
+}
+
+// execution appends a completed execution of pipeline to the event store.
+func (env *projectionTestEnv) execution(t *testing.T, pipeline string) {
+	t.Helper()
+	ctx := context.Background()
+	id := uuid.New()
+	require.NoError(t, env.events.Append(ctx, id, evstore.EventExecutionStarted, map[string]any{"pipeline": pipeline}))
+	require.NoError(t, env.events.Append(ctx, id, evstore.EventStepCompleted, map[string]any{"step_name": "a"}))
+	require.NoError(t, env.events.Append(ctx, id, evstore.EventExecutionCompleted, map[string]any{}))
+}
+
+func (env *projectionTestEnv) start(t *testing.T, cfg map[string]any) (*ProjectionModule, *projectionTestLogger) {
+	t.Helper()
+	m := NewProjectionModule("runs_per_pipeline", cfg)
+	registry := NewStepRegistry()
+	registry.Register("step.set", NewSetStepFactory())
+	m.SetStepRegistry(registry)
+	require.NoError(t, m.Init(env.app))
+	logger := &projectionTestLogger{}
+	m.logger = logger
+	require.NoError(t, m.Start(context.Background()))
+	t.Cleanup(func() { _ = m.Stop(context.Background()) })
+	require.NoError(t, m.CatchUp(context.Background()))
+	return m, logger
+}
+
+func (env *projectionTestEnv) rows(t *testing.T, query string) []string {
+	t.Helper()
+	rows, err := env.db.Query(query)
+	require.NoError(t, err)
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var id string
+		var v any
+		require.NoError(t, rows.Scan(&id, &v))
+		out = append(out, fmt.Sprintf("%s=%v", id, v))
+	}
+	require.NoError(t, rows.Err())
+	return out
+}
+
+func runsProjectionConfig() map[string]any {
+	return map[string]any{
+		"event_store":   "events",
+		"database":      "db",
+		"table":         "runs_per_pipeline",
+		"key":           "{{ .event.pipeline }}",
+		"columns":       map[string]any{"runs": "INTEGER", "last_execution": "TEXT"},
+		"events":        []any{"execution.*"},
+		"poll_interval": "1h",
+		"steps": []any{
+			map[string]any{
+				"name": "count",
+				"type": "step.set",
+				"config": map[string]any{
+					"values": map[string]any{
+						"row": map[string]any{
+							"runs":           `{{ if .row }}{{ add .row.runs 1 }}{{ else }}1{{ end }}`,
+							"last_execution": "{{ .event.execution_id }}",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestProjection_FoldsEventsAndResumes(t *testing.T) {
+	env := newProjectionTestEnv(t)
+	cfg := runsProjectionConfig()
+	cfg["events"] = []any{"execution.started"}
+	env.execution(t, "orders")
+	env.execution(t, "orders")
+	env.execution(t, "refunds")
+
+	m, _ := env.start(t, cfg)
+	require.Equal(t, []string{"orders=2", "refunds=1"},
+		env.rows(t, `SELECT id, runs FROM runs_per_pipeline ORDER BY id`))
+	st := m.ProjectionStatus()
+	require.Equal(t, ProjectionStateRunning, st.State)
+	require.Equal(t, int64(9), st.Position)
+	require.Zero(t, st.Lag)
+
+	// Events appended while the projection is stopped are folded on restart.
+	require.NoError(t, m.Stop(context.Background()))
+	env.execution(t, "orders")
+	head, err := env.events.HeadPosition(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(12), head)
+
+	// A restart resumes at the checkpoint without folding events twice.
+	m2, logger := env.start(t, cfg)
+	require.Equal(t, []string{"orders=3", "refunds=1"},
+		env.rows(t, `SELECT id, runs FROM runs_per_pipeline ORDER BY id`))
+	require.Equal(t, int64(12), m2.Position())
+	require.Empty(t, logger.warnings())
+}
+
+func TestProjection_DefinitionChangeRebuilds(t *testing.T) {
+	env := newProjectionTestEnv(t)
+	env.execution(t, "orders")
+	env.execution(t, "refunds")
+
+	// execution.started and execution.completed both count.
+	_, _ = env.start(t, runsProjectionConfig())
+	require.Equal(t, []string{"orders=2", "refunds=2"},
+		env.rows(t, `SELECT id, runs FROM runs_per_pipeline ORDER BY id`))
+
+	// Narrowing the filter to the orders pipeline changes the definition:
+	// the table is dropped and replayed instead of mixing both.
+	cfg := runsProjectionConfig()
+	cfg["pipelines"] = []any{"orders"}
+	m, logger := env.start(t, cfg)
+	require.Len(t, logger.warnings(), 1)
+	require.Contains(t, logger.warnings()[0], "definition changed")
+	require.Equal(t, []string{"orders=2"},
+		env.rows(t, `SELECT id, runs FROM runs_per_pipeline ORDER BY id`))
+	require.Equal(t, ProjectionStateRunning, m.ProjectionStatus().State)
+	require.Zero(t, m.ProjectionStatus().Progress)
+}
+
+func TestProjection_RebuildStep(t *testing.T) {
+	env := newProjectionTestEnv(t)
+	env.execution(t, "orders")
+	m, _ := env.start(t, runsProjectionConfig())
+	env.app.Services["runs_per_pipeline"] = m
+
+	// Rows edited behind the projection's back are replaced by the replay.
+	_, err := env.db.Exec(`UPDATE runs_per_pipeline SET runs = 100`)
+	require.NoError(t, err)
+	env.execution(t, "orders")
+
+	step, err := NewProjectionRebuildStepFactory()("rebuild", map[string]any{"projection": "runs_per_pipeline", "wait": true}, env.app)
+	require.NoError(t, err)
+	res, err := step.Execute(context.Background(), NewPipelineContext(nil, nil))
+	require.NoError(t, err)
+	require.Equal(t, true, res.Output["rebuilt"])
+	require.Equal(t, int64(6), res.Output["position"])
+	require.Equal(t, []string{"orders=4"},
+		env.rows(t, `SELECT id, runs FROM runs_per_pipeline ORDER BY id`))
+
+	_, err = NewProjectionRebuildStepFactory()("rebuild", map[string]any{}, env.app)
+	require.ErrorContains(t, err, "'projection' is required")
+	missing, err := NewProjectionRebuildStepFactory()("rebuild", map[string]any{"projection": "nope"}, env.app)
+	require.NoError(t, err)
+	_, err = missing.Execute(context.Background(), NewPipelineContext(nil, nil))
+	require.ErrorContains(t, err, `projection "nope" not found`)
+}
+
+// lastStatusComponent keeps the status of each execution's pipeline,
+// deleting the row once the execution completes.
+type lastStatusComponent struct{}
+
+func (lastStatusComponent) Execute(_ context.Context, params map[string]any) (map[string]any, error) {
+	event := params["event"].(map[string]any)
+	switch event["type"] {
+	case evstore.EventExecutionCompleted:
+		return map[string]any{"row": nil}, nil
+	case evstore.EventExecutionStarted:
+		return map[string]any{"row": map[string]any{"status": "running", "data": event["data"]}}, nil
+	}
+	return map[string]any{}, nil
+}
+
+func TestProjection_Component(t *testing.T) {
+	env := newProjectionTestEnv(t)
+	env.app.Services["reducer"] = lastStatusComponent{}
+	ctx := context.Background()
+	done, running := uuid.New(), uuid.New()
+	require.NoError(t, env.events.Append(ctx, done, evstore.EventExecutionStarted, map[string]any{"pipeline": "orders"}))
+	require.NoError(t, env.events.Append(ctx, running, evstore.EventExecutionStarted, map[string]any{"pipeline": "orders"}))
+	require.NoError(t, env.events.Append(ctx, done, evstore.EventExecutionCompleted, map[string]any{}))
+
+	m := NewProjectionModule("active", map[string]any{
+		"database":      "db",
+		"table":         "active_executions",
+		"key":           "{{ .event.execution_id }}",
+		"columns":       map[string]any{"status": "TEXT", "data": "TEXT"},
+		"component":     "reducer",
+		"poll_interval": "1h",
+	})
+	require.NoError(t, m.Init(env.app))
+	require.NoError(t, m.Start(ctx))
+	defer m.Stop(ctx)
+	require.NoError(t, m.CatchUp(ctx))
+
+	var id, status, data string
+	require.NoError(t, env.db.QueryRow(`SELECT id, status, data FROM active_executions`).Scan(&id, &status, &data))
+	require.Equal(t, running.String(), id)
+	require.Equal(t, "running", status)
+	require.JSONEq(t, `{"pipeline":"orders"}`, data)
+}
+
+func TestProjection_ReduceErrorStopsAtEvent(t *testing.T) {
+	env := newProjectionTestEnv(t)
+	env.execution(t, "orders")
+	cfg := runsProjectionConfig()
+	cfg["steps"].([]any)[0].(map[string]any)["config"] = map[string]any{
+		"values": map[string]any{"row": "not a row"},
+	}
+	m := NewProjectionModule("runs_per_pipeline", cfg)
+	registry := NewStepRegistry()
+	registry.Register("step.set", NewSetStepFactory())
+	m.SetStepRegistry(registry)
+	require.NoError(t, m.Init(env.app))
+	require.NoError(t, m.Start(context.Background()))
+	defer m.Stop(context.Background())
+
+	err := m.CatchUp(context.Background())
+	require.ErrorContains(t, err, "must be a map or null")
+	st := m.ProjectionStatus()
+	require.Equal(t, ProjectionStateFailing, st.State)
+	require.Equal(t, int64(0), st.Position)
+	require.Equal(t, int64(3), st.Lag)
+	require.NotEmpty(t, st.LastError)
+}
+
+func TestParseProjectionConfig_Errors(t *testing.T) {
+	base := func() map[string]any {
+		cfg := runsProjectionConfig()
+		delete(cfg, "steps")
+		cfg["component"] = "reducer"
+		return cfg
+	}
+	tests := []struct {
+		name   string
+		modify func(map[string]any)
+		want   string
+	}{
+		{"no database", func(c map[string]any) { delete(c, "database") }, "'database' is required"},
+		{"bad table", func(c map[string]any) { c["table"] = "runs; DROP" }, "invalid SQL identifier"},
+		{"no key", func(c map[string]any) { delete(c, "key") }, "'key' is required"},
+		{"id column", func(c map[string]any) { c["columns"] = map[string]any{"ID": "TEXT"} }, "key column"},
+		{"bad type", func(c map[string]any) { c["columns"] = map[string]any{"n": "INT; DROP"} }, "invalid type"},
+		{"no reducer", func(c map[string]any) { delete(c, "component") }, "exactly one of 'steps' or 'component'"},
+		{"bad pattern", func(c map[string]any) { c["events"] = []any{"["} }, "invalid pattern"},
+		{"bad batch", func(c map[string]any) { c["batch_size"] = 0 }, "'batch_size' must be a positive number"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := base()
+			tt.modify(cfg)
+			_, err := ParseProjectionConfig(cfg)
+			require.Error(t, err)
+			require.True(t, strings.Contains(err.Error(), tt.want), err.Error())
+		})
+	}
+
+	m := NewProjectionModule("p", map[string]any{})
+	require.ErrorContains(t, m.Init(NewMockApplication()), `eventstore.projection "p": 'database' is required`)
+}
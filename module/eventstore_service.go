@@ -1,21 +1,31 @@
 package module
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow/config"
 	evstore "github.com/GoCodeAlone/workflow/store"
 )
 
+// TieredEventStoreServiceSuffix is the suffix of the service name under which
+// an eventstore.service module with tiering registers its
+// *evstore.TieredEventStore, e.g. "admin-event-store.tiered".
+const TieredEventStoreServiceSuffix = ".tiered"
+
 // EventStoreServiceConfig holds the configuration for the event store service module.
 type EventStoreServiceConfig struct {
 	DBPath string `yaml:"db_path" default:"data/events.db"`
 	// RetentionDays is reserved for future implementation of automatic event pruning.
 	// It is stored and exposed via RetentionDays() but not yet applied to the store.
 	RetentionDays int `yaml:"retention_days" default:"90"`
+	// Tiering, when set, archives old executions to object storage.
+	Tiering *EventStoreTieringConfig `yaml:"tiering"`
 }
 
 // EventStoreServiceModule wraps an evstore.SQLiteEventStore as a modular.Module.
@@ -24,6 +34,11 @@ type EventStoreServiceModule struct {
 	name   string
 	config EventStoreServiceConfig
 	store  *evstore.SQLiteEventStore
+	app    modular.Application
+
+	// tiered reads through to the archive; nil without tiering.
+	tiered          *evstore.TieredEventStore
+	archiveInterval time.Duration
 }
 
 // NewEventStoreServiceModule creates a new event store service module with the given name and config.
@@ -40,6 +55,24 @@ func NewEventStoreServiceModule(name string, cfg EventStoreServiceConfig) (*Even
 		}
 	}
 
+	var tieringOpts evstore.TieringOptions
+	archiveInterval := time.Hour
+	if t := cfg.Tiering; t != nil {
+		after, err := config.ParseRetentionAge(t.After)
+		if err != nil || after <= 0 {
+			return nil, fmt.Errorf("eventstore.service %q: tiering.after must be a positive age, got %q", name, t.After)
+		}
+		if t.Storage == "" {
+			return nil, fmt.Errorf("eventstore.service %q: tiering.storage is required", name)
+		}
+		if t.Interval != "" {
+			if archiveInterval, err = config.ParseRetentionAge(t.Interval); err != nil || archiveInterval < 0 {
+				return nil, fmt.Errorf("eventstore.service %q: invalid tiering.interval %q", name, t.Interval)
+			}
+		}
+		tieringOpts = evstore.TieringOptions{After: after, Prefix: t.Prefix, BatchSize: t.BatchSize, CacheBatches: t.CacheBatches}
+	}
+
 	store, err := evstore.NewSQLiteEventStore(dbPath)
 	if err != nil {
 		return nil, fmt.Errorf("eventstore.service %q: failed to open store: %w", name, err)
@@ -47,23 +80,66 @@ func NewEventStoreServiceModule(name string, cfg EventStoreServiceConfig) (*Even
 
 	slog.Default().Info("Opened event store", "module", name, "path", dbPath)
 
-	return &EventStoreServiceModule{
-		name:   name,
-		config: cfg,
-		store:  store,
-	}, nil
+	m := &EventStoreServiceModule{
+		name:            name,
+		config:          cfg,
+		store:           store,
+		archiveInterval: archiveInterval,
+	}
+	if cfg.Tiering != nil {
+		m.tiered = evstore.NewTieredEventStore(store, nil, tieringOpts, slog.Default())
+	}
+	return m, nil
 }
 
 // Name implements modular.Module.
 func (m *EventStoreServiceModule) Name() string { return m.name }
 
 // Init implements modular.Module.
-func (m *EventStoreServiceModule) Init(_ modular.Application) error { return nil }
+func (m *EventStoreServiceModule) Init(app modular.Application) error {
+	m.app = app
+	return nil
+}
+
+// Start connects the tiered store to its cold storage, loads the archive
+// index and starts periodic archival. It is a no-op without tiering.
+func (m *EventStoreServiceModule) Start(ctx context.Context) error {
+	if m.tiered == nil {
+		return nil
+	}
+	svc, ok := m.app.SvcRegistry()[m.config.Tiering.Storage]
+	if !ok {
+		return fmt.Errorf("eventstore.service %q: tiering storage %q not found", m.name, m.config.Tiering.Storage)
+	}
+	cold, err := coldStorageFor(svc)
+	if err != nil {
+		return fmt.Errorf("eventstore.service %q: tiering storage %q: %w", m.name, m.config.Tiering.Storage, err)
+	}
+	m.tiered.SetColdStorage(cold)
+	if err := m.tiered.LoadIndex(ctx); err != nil {
+		slog.Default().Warn("Failed to load event archive index", "module", m.name, "error", err)
+	}
+	if m.archiveInterval > 0 {
+		m.tiered.Start(m.archiveInterval)
+	}
+	return nil
+}
+
+// Stop ends periodic archival.
+func (m *EventStoreServiceModule) Stop(_ context.Context) error {
+	if m.tiered != nil {
+		m.tiered.Stop()
+	}
+	return nil
+}
 
 // ProvidesServices implements modular.Module. The event store is registered under
 // the module name so other modules (timeline, replay, DLQ) can look it up.
+// With tiering, the tiered store is also registered under the module name
+// plus TieredEventStoreServiceSuffix; the plain store stays the hot tier that
+// executions are recorded to.
 func (m *EventStoreServiceModule) ProvidesServices() []modular.ServiceProvider {
-	return []modular.ServiceProvider{
+	services := []modular.ServiceProvider{
 		{
 			Name:        m.name,
 			Description: "Event store service: " + m.name,
@@ -75,11 +151,23 @@ func (m *EventStoreServiceModule) ProvidesServices() []modular.ServiceProvider {
 			Instance:    m.store,
 		},
 	}
+	if m.tiered != nil {
+		services = append(services, modular.ServiceProvider{
+			Name:        m.name + TieredEventStoreServiceSuffix,
+			Description: "Tiered event store: " + m.name,
+			Instance:    m.tiered,
+		})
+	}
+	return services
 }
 
-// RequiresServices implements modular.Module.
+// RequiresServices implements modular.Module. With tiering, the archive
+// storage must start first.
 func (m *EventStoreServiceModule) RequiresServices() []modular.ServiceDependency {
-	return nil
+	if m.tiered == nil {
+		return nil
+	}
+	return []modular.ServiceDependency{{Name: m.config.Tiering.Storage, Required: true}}
 }
 
 // Store returns the underlying SQLiteEventStore for direct use.
@@ -87,6 +175,18 @@ func (m *EventStoreServiceModule) Store() *evstore.SQLiteEventStore {
 	return m.store
 }
 
+// Tiered returns the tiered store, or nil without tiering.
+func (m *EventStoreServiceModule) Tiered() *evstore.TieredEventStore {
+	return m.tiered
+}
+
+// ResourceUsage reports the event store's connection pool.
+func (m *EventStoreServiceModule) ResourceUsage() ResourceUsage {
+	usage := ResourceUsage{}
+	addDBStatsUsage(usage, m.store.DBStats())
+	return usage
+}
+
 // RetentionDays returns the configured retention period.
 func (m *EventStoreServiceModule) RetentionDays() int {
 	return m.config.RetentionDays
@@ -102,5 +102,40 @@ func TestEventStoreServiceModule_DefaultDBPath(t *testing.T) {
 	_ = os.RemoveAll("data")
 }
 
+func TestEventStoreServiceModule_Tiering(t *testing.T) {
+	dbPath := t.TempDir() + "/test-events.db"
+	if _, err := NewEventStoreServiceModule("test-es", EventStoreServiceConfig{
+		DBPath:  dbPath,
+		Tiering: &EventStoreTieringConfig{After: "90d"},
+	}); err == nil {
+		t.Error("expected an error for tiering without storage")
+	}
+	if _, err := NewEventStoreServiceModule("test-es", EventStoreServiceConfig{
+		DBPath:  dbPath,
+		Tiering: &EventStoreTieringConfig{After: "soon", Storage: "archive"},
+	}); err == nil {
+		t.Error("expected an error for an invalid tiering age")
+	}
+
+	m, err := NewEventStoreServiceModule("test-es", EventStoreServiceConfig{
+		DBPath:  dbPath,
+		Tiering: &EventStoreTieringConfig{After: "90d", Storage: "archive"},
+	})
+	if err != nil {
+		t.Fatalf("NewEventStoreServiceModule() error = %v", err)
+	}
+	if m.Tiered() == nil {
+		t.Fatal("Tiered() returned nil with tiering configured")
+	}
+	providers := m.ProvidesServices()
+	if len(providers) != 3 || providers[2].Name != "test-es"+TieredEventStoreServiceSuffix {
+		t.Errorf("ProvidesServices() = %+v", providers)
+	}
+	deps := m.RequiresServices()
+	if len(deps) != 1 || deps[0].Name != "archive" {
+		t.Errorf("RequiresServices() = %+v", deps)
+	}
+}
+
 // Verify EventStoreServiceModule satisfies the modular.Module interface.
 var _ modular.Module = (*EventStoreServiceModule)(nil)
@@ -0,0 +1,105 @@
+package module
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	evstore "github.com/GoCodeAlone/workflow/store"
+)
+
+// EventStoreTieringConfig moves old executions from an event store to
+// object storage. See evstore.TieredEventStore.
+type EventStoreTieringConfig struct {
+	// After is the age, from an execution's last event, at which a finished
+	// execution is archived, e.g. "90d".
+	After string `yaml:"after"`
+	// Storage names a storage.local or storage.artifact module.
+	Storage string `yaml:"storage"`
+	// Prefix is the key prefix of the archive. Default "event-archive".
+	Prefix string `yaml:"prefix"`
+	// BatchSize is the number of executions per archived batch. Default 500.
+	BatchSize int `yaml:"batch_size"`
+	// Interval is how often archival runs, e.g. "1h". Default 1h; "0"
+	// disables the periodic run, leaving the archive endpoint.
+	Interval string `yaml:"interval"`
+	// CacheBatches is the number of fetched batches kept in memory. Default 8.
+	CacheBatches int `yaml:"cache_batches"`
+}
+
+// coldStorageFor adapts a storage service to evstore.ColdStorage.
+func coldStorageFor(svc any) (evstore.ColdStorage, error) {
+	switch s := svc.(type) {
+	case evstore.ColdStorage:
+		return s, nil
+	case evstore.StorageProvider:
+		return providerColdStorage{s}, nil
+	case ArtifactStore:
+		return artifactColdStorage{s}, nil
+	default:
+		return nil, fmt.Errorf("service %T is not a storage service", svc)
+	}
+}
+
+// providerColdStorage stores archive objects in a filesystem-like
+// evstore.StorageProvider.
+type providerColdStorage struct {
+	p evstore.StorageProvider
+}
+
+func (s providerColdStorage) Put(ctx context.Context, key string, r io.Reader) error {
+	return s.p.Put(ctx, key, r)
+}
+
+func (s providerColdStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	return s.p.Get(ctx, key)
+}
+
+func (s providerColdStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	files, err := s.p.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(files))
+	for _, f := range files {
+		if !f.IsDir {
+			keys = append(keys, f.Path)
+		}
+	}
+	return keys, nil
+}
+
+func (s providerColdStorage) Delete(ctx context.Context, key string) error {
+	return s.p.Delete(ctx, key)
+}
+
+// artifactColdStorage stores archive objects in an ArtifactStore.
+type artifactColdStorage struct {
+	store ArtifactStore
+}
+
+func (s artifactColdStorage) Put(ctx context.Context, key string, r io.Reader) error {
+	return s.store.Upload(ctx, key, r, nil)
+}
+
+func (s artifactColdStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	rc, _, err := s.store.Download(ctx, key)
+	return rc, err
+}
+
+func (s artifactColdStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	infos, err := s.store.List(ctx, strings.TrimSuffix(prefix, "/")+"/")
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(infos))
+	for _, info := range infos {
+		keys = append(keys, info.Key)
+	}
+	return keys, nil
+}
+
+func (s artifactColdStorage) Delete(ctx context.Context, key string) error {
+	return s.store.Delete(ctx, key)
+}
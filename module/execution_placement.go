@@ -0,0 +1,115 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// Execution placements of an HTTP trigger route.
+const (
+	// ExecutionLocal runs the route's pipeline in the server process.
+	ExecutionLocal = "local"
+	// ExecutionWorker enqueues the route's executions for workflow-worker
+	// processes and answers 202 Accepted with the job ID.
+	ExecutionWorker = "worker"
+)
+
+// IdempotencyKeyHeader is the request header whose value deduplicates the
+// executions a worker-placed route enqueues.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// ErrNoExecutionQueue is returned when a route placed on workers is
+// requested but the server has no execution queue.
+var ErrNoExecutionQueue = errors.New("no execution queue is configured for worker placement")
+
+// ExecutionEnqueuer places pipeline executions on remote workers.
+// *workflow.StdEngine satisfies it once the server gives it an execution
+// queue.
+type ExecutionEnqueuer interface {
+	// EnqueueExecution queues an execution of pipeline with data for a
+	// worker having all of labels. An execution already queued with
+	// idempotencyKey is returned with duplicate set instead.
+	EnqueueExecution(ctx context.Context, pipeline string, data map[string]any, labels []string, idempotencyKey string) (jobID string, duplicate bool, err error)
+}
+
+// ExecutionPlacement says where a route's executions run, set with the
+// `execution` and `workerLabels` keys of an HTTP trigger:
+//
+//	trigger:
+//	  type: http
+//	  config:
+//	    path: /builds
+//	    method: POST
+//	    execution: worker
+//	    workerLabels: [docker-capable]
+type ExecutionPlacement struct {
+	// Mode is ExecutionLocal (the default) or ExecutionWorker.
+	Mode string
+	// WorkerLabels a worker must all have to run the executions.
+	WorkerLabels []string
+}
+
+// OnWorker reports whether executions are offloaded to workers.
+func (p ExecutionPlacement) OnWorker() bool { return p.Mode == ExecutionWorker }
+
+// ExecutionPlacementFromConfig parses the placement keys of a route.
+func ExecutionPlacementFromConfig(route map[string]any) (ExecutionPlacement, error) {
+	var p ExecutionPlacement
+	mode, ok := route["execution"].(string)
+	if !ok && route["execution"] != nil {
+		return p, fmt.Errorf("execution must be %q or %q", ExecutionLocal, ExecutionWorker)
+	}
+	switch mode {
+	case "", ExecutionLocal:
+		p.Mode = ExecutionLocal
+	case ExecutionWorker:
+		p.Mode = ExecutionWorker
+	default:
+		return p, fmt.Errorf("execution must be %q or %q, got %q", ExecutionLocal, ExecutionWorker, mode)
+	}
+	if raw, ok := route["workerLabels"]; ok {
+		labels, err := corsStringList(raw)
+		if err != nil {
+			return p, fmt.Errorf("workerLabels %w", err)
+		}
+		if !p.OnWorker() {
+			return p, fmt.Errorf("workerLabels requires execution: %s", ExecutionWorker)
+		}
+		p.WorkerLabels = labels
+	}
+	return p, nil
+}
+
+// serveEnqueued enqueues the execution of a worker-placed route and answers
+// 202 Accepted with the job ID, which the worker pool API reports the
+// outcome under. A repeated Idempotency-Key gets the job it first created.
+func serveEnqueued(w http.ResponseWriter, r *http.Request, engine WorkflowEngine, route HTTPTriggerRoute, data map[string]any, level ProblemDetailLevel) {
+	enq, ok := engine.(ExecutionEnqueuer)
+	if !ok {
+		writeErrorProblem(w, r, NewProblem(ProblemServiceUnavailable, ErrNoExecutionQueue.Error()), level)
+		return
+	}
+	if id := GetRequestID(r.Context()); id != "" {
+		data["_request_id"] = id
+	}
+	pipeline := strings.TrimPrefix(route.Workflow, "pipeline:")
+	jobID, duplicate, err := enq.EnqueueExecution(r.Context(), pipeline, data, route.Placement.WorkerLabels, r.Header.Get(IdempotencyKeyHeader))
+	if err != nil {
+		writeErrorProblem(w, r, NewProblem(ProblemServiceUnavailable, err.Error()), level)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"status":    "queued",
+		"job_id":    jobID,
+		"duplicate": duplicate,
+	}); err != nil {
+		log.Printf("http trigger: failed to write response: %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestExecutionPlacementFromConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		route   map[string]any
+		want    ExecutionPlacement
+		wantErr string
+	}{
+		{name: "default is local", route: map[string]any{}, want: ExecutionPlacement{Mode: ExecutionLocal}},
+		{name: "local", route: map[string]any{"execution": "local"}, want: ExecutionPlacement{Mode: ExecutionLocal}},
+		{
+			name:  "worker with labels",
+			route: map[string]any{"execution": "worker", "workerLabels": []any{"docker-capable", "gpu"}},
+			want:  ExecutionPlacement{Mode: ExecutionWorker, WorkerLabels: []string{"docker-capable", "gpu"}},
+		},
+		{name: "unknown mode", route: map[string]any{"execution": "remote"}, wantErr: `got "remote"`},
+		{name: "non-string mode", route: map[string]any{"execution": true}, wantErr: "execution must be"},
+		{name: "labels need worker", route: map[string]any{"workerLabels": []any{"gpu"}}, wantErr: "requires execution: worker"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExecutionPlacementFromConfig(tt.route)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Mode != tt.want.Mode || !slices.Equal(got.WorkerLabels, tt.want.WorkerLabels) {
+				t.Errorf("placement = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// enqueueingEngine records the executions a worker-placed route enqueues.
+type enqueueingEngine struct {
+	MockWorkflowEngine
+	pipeline string
+	data     map[string]any
+	labels   []string
+	key      string
+	err      error
+}
+
+func (e *enqueueingEngine) EnqueueExecution(_ context.Context, pipeline string, data map[string]any, labels []string, key string) (string, bool, error) {
+	if e.err != nil {
+		return "", false, e.err
+	}
+	e.pipeline, e.data, e.labels, e.key = pipeline, data, labels, key
+	return "job-1", key == "seen", nil
+}
+
+func startPlacedTrigger(t *testing.T, engine WorkflowEngine) HTTPHandler {
+	t.Helper()
+	app := NewMockApplication()
+	router := NewMockHTTPRouter("test-router")
+	_ = app.RegisterService("httpRouter", router)
+	_ = app.RegisterService("workflowEngine", engine)
+	trigger := NewHTTPTrigger()
+	app.RegisterModule(trigger)
+	if err := trigger.Configure(app, map[string]any{
+		"routes": []any{map[string]any{
+			"path":         "/builds/{repo}",
+			"method":       "POST",
+			"workflow":     "pipeline:build",
+			"action":       "execute",
+			"execution":    "worker",
+			"workerLabels": []any{"docker-capable"},
+		}},
+	}); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	if err := trigger.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	handler := router.routes["POST /builds/{repo}"]
+	if handler == nil {
+		t.Fatal("handler not registered")
+	}
+	return handler
+}
+
+func TestHTTPTrigger_WorkerPlacementEnqueues(t *testing.T) {
+	engine := &enqueueingEngine{MockWorkflowEngine: *NewMockWorkflowEngine()}
+	handler := startPlacedTrigger(t, engine)
+
+	req := httptest.NewRequest("POST", "/builds/app", strings.NewReader(`{"ref":"main"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(IdempotencyKeyHeader, "seen")
+	req = req.WithContext(WithRequestID(req.Context(), "req-1"))
+	w := httptest.NewRecorder()
+	handler.Handle(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202: %s", w.Code, w.Body.String())
+	}
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body["status"] != "queued" || body["job_id"] != "job-1" || body["duplicate"] != true {
+		t.Errorf("body = %v", body)
+	}
+	if engine.pipeline != "build" || engine.key != "seen" || !slices.Equal(engine.labels, []string{"docker-capable"}) {
+		t.Errorf("enqueued pipeline=%q key=%q labels=%v", engine.pipeline, engine.key, engine.labels)
+	}
+	if in, _ := engine.data["body"].(map[string]any); in["ref"] != "main" || engine.data["_request_id"] != "req-1" {
+		t.Errorf("enqueued data = %v", engine.data)
+	}
+	if len(engine.triggeredWorkflows) != 0 {
+		t.Errorf("worker-placed route ran locally: %v", engine.triggeredWorkflows)
+	}
+}
+
+func TestHTTPTrigger_WorkerPlacementWithoutQueue(t *testing.T) {
+	for name, engine := range map[string]WorkflowEngine{
+		"no enqueuer":   NewMockWorkflowEngine(),
+		"enqueue error": &enqueueingEngine{MockWorkflowEngine: *NewMockWorkflowEngine(), err: ErrNoExecutionQueue},
+	} {
+		t.Run(name, func(t *testing.T) {
+			handler := startPlacedTrigger(t, engine)
+			w := httptest.NewRecorder()
+			handler.Handle(w, httptest.NewRequest("POST", "/builds/app", strings.NewReader("")))
+			if w.Code != http.StatusServiceUnavailable {
+				t.Errorf("status = %d, want 503", w.Code)
+			}
+		})
+	}
+}
+
+func TestHTTPTrigger_WorkerPlacementRequiresPipeline(t *testing.T) {
+	app := NewMockApplication()
+	_ = app.RegisterService("httpRouter", NewMockHTTPRouter("test-router"))
+	_ = app.RegisterService("workflowEngine", NewMockWorkflowEngine())
+	err := NewHTTPTrigger().Configure(app, map[string]any{
+		"routes": []any{map[string]any{
+			"path": "/builds", "method": "POST", "workflow": "build-workflow", "action": "execute", "execution": "worker",
+		}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "only supported for pipeline triggers") {
+		t.Fatalf("err = %v, want worker placement rejected for a non-pipeline workflow", err)
+	}
+}
@@ -29,6 +29,16 @@ func isExplicitTrace(ctx context.Context) bool {
 	return v
 }
 
+// triggerTypeContextKey is the context key type for WithTriggerType.
+type triggerTypeContextKey struct{}
+
+// WithTriggerType returns a context whose tracked executions without an HTTP
+// request are recorded with triggerType, such as "schedule", instead of
+// "http".
+func WithTriggerType(ctx context.Context, triggerType string) context.Context {
+	return context.WithValue(ctx, triggerTypeContextKey{}, triggerType)
+}
+
 // ExecutionTrackerProvider is the minimal interface required to track pipeline executions.
 // *ExecutionTracker satisfies this interface.
 type ExecutionTrackerProvider interface {
@@ -46,6 +56,7 @@ type executionState struct {
 	execSpan      trace.Span            // OTEL span for this execution
 	explicitTrace bool                  // true when X-Workflow-Trace: true
 	chained       EventRecorder         // upstream recorder to forward events to
+	pipeline      string                // name of the executing pipeline
 }
 
 // ExecutionTracker wraps pipeline execution with V1Store recording.
@@ -75,6 +86,19 @@ type ExecutionTracker struct {
 	// link traces back to the config version that generated them.
 	ConfigHash string
 
+	// Webhooks is an optional dispatcher that is sent an execution.completed
+	// or execution.failed event for every tracked execution that is not a
+	// dry run, delivering it to the workflow's webhook subscriptions.
+	Webhooks *WebhookDispatcher
+
+	// AIUsageMeter is an optional billing meter that receives the AI usage
+	// recorded for the workflow's executions.
+	AIUsageMeter AIUsageMeter
+
+	// tenantOnce guards the lookup of tenant, the company owning WorkflowID.
+	tenantOnce sync.Once
+	tenant     string
+
 	// execMu protects the executions map (not the individual execution states).
 	execMu     sync.Mutex
 	executions map[string]*executionState // executionID -> per-execution state
@@ -87,6 +111,12 @@ func (t *ExecutionTracker) SetEventStoreRecorder(r EventRecorder) {
 	t.EventStoreRecorder = r
 }
 
+// SetWebhookDispatcher sets the optional dispatcher that finished executions
+// are published to.
+func (t *ExecutionTracker) SetWebhookDispatcher(d *WebhookDispatcher) {
+	t.Webhooks = d
+}
+
 // getExecutionState returns the per-execution state for the given executionID, or nil.
 func (t *ExecutionTracker) getExecutionState(executionID string) *executionState {
 	t.execMu.Lock()
@@ -117,8 +147,10 @@ func (t *ExecutionTracker) RecordEvent(ctx context.Context, executionID string,
 		t.handleStepStarted(ctx, state, executionID, data, now)
 	case "step.completed":
 		t.handleStepCompleted(state, data, now)
+		t.recordAIUsage(ctx, state, executionID, data)
 	case "step.failed":
 		t.handleStepFailed(state, data, now)
+		t.recordAIUsage(ctx, state, executionID, data)
 	case "step.input_recorded":
 		// Only process and log I/O events for explicitly-traced executions.
 		// This prevents PII leakage and unnecessary storage for normal runs.
@@ -143,6 +175,13 @@ func (t *ExecutionTracker) RecordEvent(ctx context.Context, executionID string,
 			t.writeLog(executionID, eventType, minimal, now)
 		}
 		return nil
+	case "step.journaled":
+		// The journal's context snapshot is kept in the event store only.
+		t.writeLog(executionID, eventType, map[string]any{
+			"step_name": data["step_name"],
+			"index":     data["index"],
+		}, now)
+		return nil
 	}
 
 	// Write to execution_logs for all non-I/O event types.
@@ -204,7 +243,17 @@ func (t *ExecutionTracker) handleStepCompleted(state *executionState, data map[s
 	}
 
 	elapsed := parseDuration(data)
-	_ = t.Store.CompleteExecutionStep(stepID, "completed", now, elapsed, "")
+	status := "completed"
+	if dryRun, _ := data["dry_run"].(bool); dryRun {
+		// Record what the destructive step would have done as its output,
+		// whether or not I/O capture is on: it is the point of the dry run.
+		status = "dry_run"
+		t.handleStepOutputRecorded(state, map[string]any{
+			"step_name": stepName,
+			"output":    map[string]any{"dry_run": true, "would_execute": data["would_execute"]},
+		})
+	}
+	_ = t.Store.CompleteExecutionStep(stepID, status, now, elapsed, "")
 
 	// End OTEL span
 	if stepSpan != nil {
@@ -321,7 +370,11 @@ func (t *ExecutionTracker) writeLog(executionID, eventType string, data map[stri
 		message = fmt.Sprintf("Step started: %s", moduleName)
 	case "step.completed":
 		level = "info"
-		if elapsed, ok := data["elapsed"].(string); ok {
+		if dryRun, _ := data["dry_run"].(bool); dryRun {
+			message = fmt.Sprintf("Step dry run: %s (not executed)", moduleName)
+			// The preview is stored, redacted, as the step output.
+			data = map[string]any{"step_name": moduleName, "dry_run": true}
+		} else if elapsed, ok := data["elapsed"].(string); ok {
 			message = fmt.Sprintf("Step completed: %s (%s)", moduleName, elapsed)
 		} else {
 			message = fmt.Sprintf("Step completed: %s", moduleName)
@@ -352,6 +405,23 @@ func (t *ExecutionTracker) writeLog(executionID, eventType string, data map[stri
 	}
 }
 
+// baggageTenant returns the tenant recorded in execution baggage: the company
+// owning the tracked workflow, or else the tenant resolved for the request.
+func (t *ExecutionTracker) baggageTenant(ctx context.Context) string {
+	if t.WorkflowID != "" {
+		if id := t.tenantID(); id != "" {
+			return id
+		}
+	}
+	return TenantFromContext(ctx).ID
+}
+
+// RequestUser returns the authenticated user the auth middleware recorded on
+// r, or "" for an unauthenticated request.
+func RequestUser(r *http.Request) string {
+	return extractTriggeredBy(r)
+}
+
 // extractTriggeredBy tries to extract the user identity from JWT claims
 // on the request context. It checks for email, sub, and user_id fields.
 func extractTriggeredBy(r *http.Request) string {
@@ -395,12 +465,19 @@ func (t *ExecutionTracker) TrackPipelineExecution(
 	triggerType := "http"
 	if r != nil {
 		triggerType = fmt.Sprintf("%s %s", r.Method, r.URL.Path)
+	} else if tt, _ := ctx.Value(triggerTypeContextKey{}).(string); tt != "" {
+		triggerType = tt
 	}
 	startedAt := time.Now()
 
 	// Detect explicit trace request header
 	explicitTrace := r != nil && r.Header.Get("X-Workflow-Trace") == "true"
 
+	// Detect a dry run, requested on the context or the request, and the
+	// dry run a real run was confirmed from.
+	dryRun := IsDryRun(ctx) || DryRunRequested(r)
+	confirmedFrom := dryRunOf(r)
+
 	// Determine the chained recorder for this execution.
 	// IMPORTANT: Never chain to ourselves — that causes infinite recursion.
 	var chained EventRecorder
@@ -418,6 +495,7 @@ func (t *ExecutionTracker) TrackPipelineExecution(
 		stepSpans:     make(map[string]trace.Span),
 		explicitTrace: explicitTrace,
 		chained:       chained,
+		pipeline:      pipeline.Name,
 	}
 	t.execMu.Lock()
 	if t.executions == nil {
@@ -439,11 +517,18 @@ func (t *ExecutionTracker) TrackPipelineExecution(
 		triggeredBy = extractTriggeredBy(r)
 	}
 
+	// Set the tenant, workflow and execution baggage at ingress. These
+	// replace any values an inbound request carried.
+	execCtx := tracing.WithBaggage(ctx, map[string]string{
+		tracing.BaggageTenantID:    t.baggageTenant(ctx),
+		tracing.BaggageWorkflowID:  t.WorkflowID,
+		tracing.BaggageExecutionID: execID,
+	})
+
 	// Start OTEL execution span if tracer is configured
-	execCtx := ctx
 	if t.Tracer != nil {
 		var span trace.Span
-		execCtx, span = t.Tracer.StartWorkflow(ctx, t.WorkflowID, triggerType)
+		execCtx, span = t.Tracer.StartWorkflow(execCtx, t.WorkflowID, triggerType)
 		span.SetAttributes(
 			attribute.String("execution.id", execID),
 			attribute.String("workflow.id", t.WorkflowID),
@@ -462,12 +547,20 @@ func (t *ExecutionTracker) TrackPipelineExecution(
 	if explicitTrace {
 		execCtx = withExplicitTrace(execCtx)
 	}
+	if dryRun {
+		execCtx = WithDryRun(execCtx)
+	}
+	// AI steps check the workflow's budget before calling their model.
+	if t.WorkflowID != "" {
+		execCtx = WithAIBudgetCheck(execCtx, t.checkAIBudget)
+	}
 
 	// Best-effort: don't fail the request if tracking fails
 	_ = t.Store.InsertExecution(execID, t.WorkflowID, triggerType, "running", triggeredBy, startedAt)
 
-	// Build execution metadata (config hash always included when set; explicit trace flags when active)
-	if t.ConfigHash != "" || explicitTrace {
+	// Build execution metadata (config hash always included when set; explicit
+	// trace and dry-run flags when active)
+	if t.ConfigHash != "" || explicitTrace || dryRun || confirmedFrom != "" {
 		meta := map[string]any{}
 		if t.ConfigHash != "" {
 			meta["config_version"] = t.ConfigHash
@@ -476,6 +569,12 @@ func (t *ExecutionTracker) TrackPipelineExecution(
 			meta["explicit_trace"] = true
 			meta["capture_io"] = true
 		}
+		if dryRun {
+			meta["dry_run"] = true
+		}
+		if confirmedFrom != "" {
+			meta["dry_run_of"] = confirmedFrom
+		}
 		metaJSON, _ := json.Marshal(meta)
 		_ = t.Store.UpdateExecutionMetadata(execID, string(metaJSON))
 	}
@@ -502,6 +601,12 @@ func (t *ExecutionTracker) TrackPipelineExecution(
 
 	_ = t.Store.CompleteExecution(execID, status, completedAt, durationMs, errMsg)
 
+	// Best-effort: a webhook store error must not fail the request.
+	if t.Webhooks != nil && !dryRun {
+		_ = t.Webhooks.Publish(context.WithoutCancel(ctx),
+			ExecutionWebhookEvent(t.WorkflowID, execID, pipeline.Name, status, errMsg, durationMs))
+	}
+
 	// End OTEL execution span
 	state.mu.Lock()
 	span := state.execSpan
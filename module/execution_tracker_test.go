@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/GoCodeAlone/workflow/observability/tracing"
 	"github.com/stretchr/testify/require"
 )
 
@@ -199,3 +200,23 @@ func TestExecutionTracker_TruncatesLargeIO(t *testing.T) {
 	require.LessOrEqual(t, len(outputData), 10240)
 	require.Contains(t, outputData, "[truncated]")
 }
+
+func TestTrackPipelineExecution_SetsBaggage(t *testing.T) {
+	store := setupTestStoreWithWorkflow(t, "test-wf")
+	tracker := &ExecutionTracker{Store: store, WorkflowID: "test-wf"}
+
+	var got []any
+	step := &mockStep{name: "step1", execFn: func(ctx context.Context, _ *PipelineContext) (*StepResult, error) {
+		got = tracing.BaggageLogArgs(ctx)
+		return &StepResult{}, nil
+	}}
+	// An inbound tenant the engine cannot vouch for is dropped.
+	ctx := tracing.WithBaggage(context.Background(), map[string]string{tracing.BaggageTenantID: "spoofed"})
+	_, err := tracker.TrackPipelineExecution(ctx, &Pipeline{Name: "p", Steps: []PipelineStep{step}}, nil, httptest.NewRequest("GET", "/test", nil))
+	require.NoError(t, err)
+
+	require.Len(t, got, 4)
+	require.Equal(t, []any{"workflow_id", "test-wf"}, got[:2])
+	require.Equal(t, "execution_id", got[2])
+	require.NotEmpty(t, got[3])
+}
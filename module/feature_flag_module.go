@@ -19,6 +19,11 @@ type FeatureFlagModuleConfig struct {
 	CacheTTL   string `yaml:"cache_ttl" default:"30s"`
 	SSEEnabled bool   `yaml:"sse_enabled" default:"true"`
 	DBPath     string `yaml:"db_path" default:"data/featureflags.db"`
+	// AdminURL is the admin server whose flag changes this engine follows.
+	// Defaults to WORKFLOW_ADMIN_URL; empty disables following.
+	AdminURL string `yaml:"admin_url"`
+	// EngineName identifies this engine to the admin. Defaults to the hostname.
+	EngineName string `yaml:"engine_name"`
 }
 
 // FeatureFlagModule wraps a featureflag.Service as a modular.Module.
@@ -29,6 +34,7 @@ type FeatureFlagModule struct {
 	config  FeatureFlagModuleConfig
 	service *featureflag.Service
 	store   *generic.Store
+	remote  *featureflag.RemoteSubscriber
 }
 
 // NewFeatureFlagModule creates a new feature flag module with the given name and config.
@@ -65,12 +71,33 @@ func NewFeatureFlagModule(name string, cfg FeatureFlagModuleConfig) (*FeatureFla
 	cache := featureflag.NewFlagCache(cacheTTL)
 	service := featureflag.NewService(provider, cache, slog.Default())
 
-	return &FeatureFlagModule{
+	m := &FeatureFlagModule{
 		name:    name,
 		config:  cfg,
 		service: service,
 		store:   store,
-	}, nil
+	}
+
+	adminURL := cfg.AdminURL
+	if adminURL == "" {
+		adminURL = os.Getenv("WORKFLOW_ADMIN_URL")
+	}
+	if adminURL != "" {
+		engine := cfg.EngineName
+		if engine == "" {
+			engine, _ = os.Hostname()
+		}
+		// While the admin stream is down, cached values expire after the
+		// cache TTL; reconnects are attempted on the same cadence.
+		m.remote = featureflag.NewRemoteSubscriber(service, featureflag.RemoteConfig{
+			AdminURL:      adminURL,
+			Token:         os.Getenv("WORKFLOW_ADMIN_TOKEN"),
+			Engine:        engine,
+			RetryInterval: cacheTTL,
+		}, slog.Default())
+	}
+
+	return m, nil
 }
 
 // Name implements modular.Module.
@@ -79,6 +106,11 @@ func (m *FeatureFlagModule) Name() string { return m.name }
 // Init implements modular.Module.
 func (m *FeatureFlagModule) Init(_ modular.Application) error { return nil }
 
+// FeatureFlagAdminServiceSuffix is the service name suffix under which a
+// feature flag module named <name> registers its FeatureFlagAdmin adapter,
+// e.g. "admin-feature-flags.admin".
+const FeatureFlagAdminServiceSuffix = ".admin"
+
 // ProvidesServices implements modular.Module. The service is registered under
 // the module name so other modules (and the engine) can look it up.
 // A FeatureFlagAdmin adapter is also registered so that the V1 API handler
@@ -97,7 +129,7 @@ func (m *FeatureFlagModule) ProvidesServices() []modular.ServiceProvider {
 		adapter := NewFeatureFlagAdminAdapter(m.service, m.store)
 		providers = append(providers,
 			modular.ServiceProvider{
-				Name:        m.name + ".admin",
+				Name:        m.name + FeatureFlagAdminServiceSuffix,
 				Description: "Feature flag admin adapter: " + m.name,
 				Instance:    FeatureFlagAdmin(adapter),
 			},
@@ -127,14 +159,33 @@ func (m *FeatureFlagModule) Store() *generic.Store {
 	return m.store
 }
 
-// Stop closes the underlying store's database connection during shutdown.
+// Start begins following the admin's flag changes when an admin URL is
+// configured.
+func (m *FeatureFlagModule) Start(_ context.Context) error {
+	if m.remote != nil {
+		m.remote.Start()
+	}
+	return nil
+}
+
+// Stop disconnects from the admin and closes the underlying store's database
+// connection during shutdown.
 func (m *FeatureFlagModule) Stop(_ context.Context) error {
+	if m.remote != nil {
+		m.remote.Stop()
+	}
 	if m.store != nil {
 		return m.store.Close()
 	}
 	return nil
 }
 
+// Remote returns the subscriber that follows the admin's flag changes, or
+// nil when no admin URL is configured.
+func (m *FeatureFlagModule) Remote() *featureflag.RemoteSubscriber {
+	return m.remote
+}
+
 // SSEEnabled returns whether SSE streaming is enabled for this module.
 func (m *FeatureFlagModule) SSEEnabled() bool {
 	return m.config.SSEEnabled
@@ -0,0 +1,86 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/featureflag"
+)
+
+// TestFeatureFlagModule_RemoteEnginePropagation runs an admin and a worker
+// engine in-process over one flag database. The worker caches evaluations for
+// an hour, so only the admin's change stream can make it see an update.
+func TestFeatureFlagModule_RemoteEnginePropagation(t *testing.T) {
+	t.Setenv("WORKFLOW_ADMIN_URL", "")
+	dbPath := filepath.Join(t.TempDir(), "flags.db")
+	ctx := context.Background()
+
+	admin, err := NewFeatureFlagModule("admin-feature-flags", FeatureFlagModuleConfig{CacheTTL: "1h", DBPath: dbPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer admin.Stop(ctx) //nolint:errcheck
+	if admin.Remote() != nil {
+		t.Fatal("admin without an admin URL should not follow another admin")
+	}
+	srv := httptest.NewServer(admin.Service().SSEHandler())
+	defer srv.Close()
+
+	worker, err := NewFeatureFlagModule("flags", FeatureFlagModuleConfig{
+		CacheTTL:   "1h",
+		DBPath:     dbPath,
+		AdminURL:   srv.URL,
+		EngineName: "worker-1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := worker.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer worker.Stop(ctx) //nolint:errcheck
+
+	adapter := NewFeatureFlagAdminAdapter(admin.Service(), admin.Store())
+	deadline := time.Now().Add(2 * time.Second)
+	for len(adapter.SubscribedEngines()) != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("worker never subscribed to the admin")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if sub := adapter.SubscribedEngines()[0].(featureflag.EngineSubscription); sub.Engine != "worker-1" {
+		t.Errorf("subscribed engine = %q, want worker-1", sub.Engine)
+	}
+
+	if _, err := adapter.CreateFlag(json.RawMessage(`{"key":"beta","default_val":"true"}`)); err != nil {
+		t.Fatal(err)
+	}
+	eval := func() any {
+		v, err := worker.Service().Evaluate(ctx, "beta", featureflag.EvaluationContext{UserKey: "u1"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v.Value
+	}
+	if v := eval(); v != true {
+		t.Fatalf("initial value = %v, want true", v)
+	}
+
+	start := time.Now()
+	if _, err := adapter.UpdateFlag("beta", json.RawMessage(`{"default_val":"false"}`)); err != nil {
+		t.Fatal(err)
+	}
+	for eval() != false {
+		if time.Since(start) > time.Second {
+			t.Fatal("worker still serves the cached value after 1s")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if latency := time.Since(start); latency > 500*time.Millisecond {
+		t.Errorf("propagation took %v, want under 500ms", latency)
+	}
+}
@@ -88,6 +88,7 @@ func (a *FeatureFlagAdminAdapter) CreateFlag(data json.RawMessage) (any, error)
 	if err := a.store.UpsertFlag(context.Background(), row); err != nil {
 		return nil, err
 	}
+	a.notify(row)
 	// Re-read to get timestamps
 	return a.store.GetFlag(context.Background(), req.Key)
 }
@@ -126,11 +127,16 @@ func (a *FeatureFlagAdminAdapter) UpdateFlag(key string, data json.RawMessage) (
 	if err := a.store.UpsertFlag(context.Background(), existing); err != nil {
 		return nil, err
 	}
+	a.notify(existing)
 	return a.store.GetFlag(context.Background(), key)
 }
 
 func (a *FeatureFlagAdminAdapter) DeleteFlag(key string) error {
-	return a.store.DeleteFlag(context.Background(), key)
+	if err := a.store.DeleteFlag(context.Background(), key); err != nil {
+		return err
+	}
+	a.service.NotifyChange(featureflag.FlagChangeEvent{Key: key, Source: "admin"})
+	return nil
 }
 
 type overrideRequest struct {
@@ -166,6 +172,7 @@ func (a *FeatureFlagAdminAdapter) SetOverrides(key string, data json.RawMessage)
 	if err != nil {
 		return nil, err
 	}
+	a.notify(flag)
 	overrides, _ := a.store.GetOverrides(context.Background(), key)
 	return map[string]any{
 		"flag":      flag,
@@ -191,3 +198,23 @@ func (a *FeatureFlagAdminAdapter) EvaluateFlag(key string, user string, group st
 func (a *FeatureFlagAdminAdapter) SSEHandler() http.Handler {
 	return a.service.SSEHandler()
 }
+
+func (a *FeatureFlagAdminAdapter) SubscribedEngines() []any {
+	engines := a.service.Engines()
+	result := make([]any, len(engines))
+	for i := range engines {
+		result[i] = engines[i]
+	}
+	return result
+}
+
+// notify publishes a change of the given flag so that this engine's cache
+// and every subscribed engine drop their cached values for it.
+func (a *FeatureFlagAdminAdapter) notify(flag *generic.FlagRow) {
+	a.service.NotifyChange(featureflag.FlagChangeEvent{
+		Key:    flag.Key,
+		Value:  flag.DefaultVal,
+		Type:   featureflag.FlagType(flag.Type),
+		Source: "admin",
+	})
+}
@@ -0,0 +1,182 @@
+package module
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// BodyLimits caps the size of request and response bodies. A zero field is
+// unset: a route inherits the server's limit, and the server applies none.
+// A negative field on a route removes the server's limit for that route.
+type BodyLimits struct {
+	MaxRequestBytes  int64 `json:"maxRequestBytes,omitempty" yaml:"maxRequestBytes,omitempty"`
+	MaxResponseBytes int64 `json:"maxResponseBytes,omitempty" yaml:"maxResponseBytes,omitempty"`
+}
+
+// ErrResponseTooLarge is returned by writes that would take a response past
+// its maxResponseBytes limit.
+var ErrResponseTooLarge = errors.New("response body too large")
+
+// BodyLimitsFromConfig reads the maxRequestBytes and maxResponseBytes keys of
+// a server or route config.
+func BodyLimitsFromConfig(cfg map[string]any) BodyLimits {
+	var limits BodyLimits
+	if n, ok := toFloat64(cfg["maxRequestBytes"]); ok {
+		limits.MaxRequestBytes = int64(n)
+	}
+	if n, ok := toFloat64(cfg["maxResponseBytes"]); ok {
+		limits.MaxResponseBytes = int64(n)
+	}
+	return limits
+}
+
+// IsZero reports whether no limit is set.
+func (l BodyLimits) IsZero() bool {
+	return l.MaxRequestBytes == 0 && l.MaxResponseBytes == 0
+}
+
+// Merge returns the limits of a route with these route overrides applied on
+// top of the server defaults l.
+func (l BodyLimits) Merge(route BodyLimits) BodyLimits {
+	if route.MaxRequestBytes != 0 {
+		l.MaxRequestBytes = route.MaxRequestBytes
+	}
+	if route.MaxResponseBytes != 0 {
+		l.MaxResponseBytes = route.MaxResponseBytes
+	}
+	return l
+}
+
+// BodyLimitHandler enforces limits on next. Requests that declare a larger
+// Content-Length are rejected with 413 before next runs; larger bodies of
+// unknown length fail with *http.MaxBytesError when read. A response that
+// exceeds its limit on its first write is replaced with a 500; one that
+// exceeds it later is truncated, since its status is already sent.
+func BodyLimitHandler(next http.Handler, limits BodyLimits) http.Handler {
+	if limits.MaxRequestBytes <= 0 && limits.MaxResponseBytes <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if limit := limits.MaxRequestBytes; limit > 0 && r.Body != nil && r.Body != http.NoBody {
+			if r.ContentLength > limit {
+				WriteRequestTooLarge(w, limit)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+		}
+		if limit := limits.MaxResponseBytes; limit > 0 {
+			lw := &limitedResponseWriter{ResponseWriter: w, limit: limit, method: r.Method, path: r.URL.Path}
+			defer lw.finish()
+			w = lw
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// WriteRequestTooLarge writes the 413 response for a request body larger
+// than limit bytes.
+func WriteRequestTooLarge(w http.ResponseWriter, limit int64) {
+	w.Header().Set("Connection", "close")
+	WriteProblem(w, NewProblem(ProblemPayloadTooLarge,
+		"request body too large: limit is "+strconv.FormatInt(limit, 10)+" bytes"), ProblemDetailFull)
+}
+
+// handleRequestTooLarge writes the 413 response and returns true when err
+// came from reading a request body past its maxRequestBytes limit.
+func handleRequestTooLarge(w http.ResponseWriter, err error) bool {
+	var maxErr *http.MaxBytesError
+	if !errors.As(err, &maxErr) {
+		return false
+	}
+	WriteRequestTooLarge(w, maxErr.Limit)
+	return true
+}
+
+// limitedResponseWriter caps the bytes written to a response. It holds back
+// the status until the first write so that a response which is already too
+// large on its first write can still be turned into an error.
+type limitedResponseWriter struct {
+	http.ResponseWriter
+	limit       int64
+	written     int64
+	status      int
+	wroteHeader bool
+	exceeded    bool
+	method      string
+	path        string
+}
+
+func (lw *limitedResponseWriter) WriteHeader(code int) {
+	if lw.wroteHeader || lw.status != 0 {
+		return
+	}
+	if code >= 100 && code < 200 {
+		lw.ResponseWriter.WriteHeader(code)
+		return
+	}
+	lw.status = code
+}
+
+func (lw *limitedResponseWriter) Write(b []byte) (int, error) {
+	if lw.exceeded {
+		return 0, ErrResponseTooLarge
+	}
+	if lw.written+int64(len(b)) <= lw.limit {
+		lw.sendHeader()
+		n, err := lw.ResponseWriter.Write(b)
+		lw.written += int64(n)
+		return n, err
+	}
+
+	lw.exceeded = true
+	slog.Warn("http: response body exceeds maxResponseBytes", "method", lw.method, "path", lw.path, "limit", lw.limit)
+	if !lw.wroteHeader {
+		lw.wroteHeader = true
+		h := lw.Header()
+		h.Del("Content-Length")
+		h.Set("Content-Type", "application/json")
+		lw.ResponseWriter.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(lw.ResponseWriter).Encode(map[string]string{"error": ErrResponseTooLarge.Error()})
+		return 0, ErrResponseTooLarge
+	}
+	n, err := lw.ResponseWriter.Write(b[:lw.limit-lw.written])
+	lw.written += int64(n)
+	if err != nil {
+		return n, err
+	}
+	return n, ErrResponseTooLarge
+}
+
+// sendHeader sends the held-back status, if any.
+func (lw *limitedResponseWriter) sendHeader() {
+	if lw.wroteHeader {
+		return
+	}
+	lw.wroteHeader = true
+	if lw.status != 0 {
+		lw.ResponseWriter.WriteHeader(lw.status)
+	}
+}
+
+// finish sends a status that was set without a body.
+func (lw *limitedResponseWriter) finish() {
+	if !lw.wroteHeader && lw.status != 0 {
+		lw.sendHeader()
+	}
+}
+
+// Flush implements http.Flusher when the underlying writer does.
+func (lw *limitedResponseWriter) Flush() {
+	lw.sendHeader()
+	if f, ok := lw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying writer for http.ResponseController.
+func (lw *limitedResponseWriter) Unwrap() http.ResponseWriter {
+	return lw.ResponseWriter
+}
@@ -0,0 +1,114 @@
+package module
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// bodyEchoHandler reads the request body and writes it back, answering 413
+// when the body is over its limit.
+type bodyEchoHandler struct{}
+
+func (bodyEchoHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if handleRequestTooLarge(w, err) {
+		return
+	}
+	_, _ = w.Write(body)
+}
+
+func TestBodyLimits_Merge(t *testing.T) {
+	server := BodyLimits{MaxRequestBytes: 100, MaxResponseBytes: 200}
+	require.Equal(t, server, server.Merge(BodyLimits{}))
+	require.Equal(t, BodyLimits{MaxRequestBytes: 10, MaxResponseBytes: 200}, server.Merge(BodyLimits{MaxRequestBytes: 10}))
+	// A negative route limit removes the server's limit.
+	merged := server.Merge(BodyLimits{MaxResponseBytes: -1})
+	require.Equal(t, int64(-1), merged.MaxResponseBytes)
+	h := BodyLimitHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("x", 500)))
+	}), merged)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, 500, rr.Body.Len())
+
+	require.Equal(t, BodyLimits{MaxRequestBytes: 1024, MaxResponseBytes: 2048},
+		BodyLimitsFromConfig(map[string]any{"maxRequestBytes": 1024, "maxResponseBytes": 2048.0}))
+}
+
+func TestBodyLimitHandler_Request(t *testing.T) {
+	h := BodyLimitHandler(http.HandlerFunc(bodyEchoHandler{}.Handle), BodyLimits{MaxRequestBytes: 8})
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("small")))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "small", rr.Body.String())
+
+	// A declared Content-Length over the limit is rejected before the handler runs.
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("much too large")))
+	require.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	require.Contains(t, rr.Body.String(), "limit is 8 bytes")
+
+	// A body of unknown length fails when read past the limit.
+	req := httptest.NewRequest(http.MethodPost, "/", io.NopCloser(strings.NewReader("much too large")))
+	req.ContentLength = -1
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+}
+
+func TestBodyLimitHandler_Response(t *testing.T) {
+	var writeErr error
+	h := BodyLimitHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusCreated)
+		for _, chunk := range strings.Split(r.URL.Query().Get("chunks"), ",") {
+			if _, writeErr = w.Write([]byte(chunk)); writeErr != nil {
+				return
+			}
+		}
+	}), BodyLimits{MaxResponseBytes: 6})
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/?chunks=abc,def", nil))
+	require.NoError(t, writeErr)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	require.Equal(t, "abcdef", rr.Body.String())
+
+	// Too large on the first write: the response becomes a 500.
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/?chunks=abcdefgh", nil))
+	require.ErrorIs(t, writeErr, ErrResponseTooLarge)
+	require.Equal(t, http.StatusInternalServerError, rr.Code)
+	require.Contains(t, rr.Body.String(), "response body too large")
+
+	// Too large later: the status is sent, so the body is truncated.
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/?chunks=abcd,efgh", nil))
+	require.ErrorIs(t, writeErr, ErrResponseTooLarge)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	require.Equal(t, "abcdef", rr.Body.String())
+}
+
+func TestRouter_RouteBodyLimits(t *testing.T) {
+	router := NewStandardHTTPRouter("router")
+	router.SetDefaultBodyLimits(BodyLimits{MaxRequestBytes: 4})
+	router.AddRoute("POST", "/small", bodyEchoHandler{})
+	router.AddRoute("POST", "/upload", bodyEchoHandler{})
+	router.SetRouteBodyLimits("POST", "/upload", BodyLimits{MaxRequestBytes: 64})
+	require.NoError(t, router.Start(t.Context()))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/small", strings.NewReader("payload")))
+	require.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("payload")))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "payload", rr.Body.String())
+}
@@ -231,12 +231,19 @@ func (m *HTTPClientModule) resolveCredentials(ctx context.Context) error {
 
 // resolveSecretRef looks up a {provider, key} pair from the service registry.
 func (m *HTTPClientModule) resolveSecretRef(ctx context.Context, ref SecretRef) (string, error) {
-	if m.app == nil {
+	return LookupSecret(ctx, m.app, ref.Provider, ref.Key)
+}
+
+// LookupSecret returns the value of key in the secrets provider registered in
+// app under the service name provider. The service is a secrets.Provider or
+// exposes one through a Provider() method.
+func LookupSecret(ctx context.Context, app modular.Application, provider, key string) (string, error) {
+	if app == nil {
 		return "", fmt.Errorf("application not initialised")
 	}
-	svc, ok := m.app.SvcRegistry()[ref.Provider]
+	svc, ok := app.SvcRegistry()[provider]
 	if !ok {
-		return "", fmt.Errorf("provider %q not found in service registry", ref.Provider)
+		return "", fmt.Errorf("provider %q not found in service registry", provider)
 	}
 	var p secrets.Provider
 	switch v := svc.(type) {
@@ -251,9 +258,9 @@ func (m *HTTPClientModule) resolveSecretRef(ctx context.Context, ref SecretRef)
 		}
 	}
 	if p == nil {
-		return "", fmt.Errorf("service %q does not implement secrets.Provider", ref.Provider)
+		return "", fmt.Errorf("service %q does not implement secrets.Provider", provider)
 	}
-	return p.Get(ctx, ref.Key)
+	return p.Get(ctx, key)
 }
 
 // validateAuth asserts that required fields are non-empty for each auth type.
@@ -0,0 +1,241 @@
+package module
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/GoCodeAlone/workflow/config"
+	"golang.org/x/net/http/httpguts"
+)
+
+// Validate reports CORS policies that are misconfigured: a "*" origin with
+// credentials, which would let every site make credentialed requests,
+// origins a browser never sends (see config.ValidateCORSOrigin), methods that
+// are not HTTP method tokens and a negative max age.
+func (c CORSMiddlewareConfig) Validate() error {
+	if c.AllowCredentials && slices.Contains(c.AllowedOrigins, "*") {
+		return errors.New(`allowedOrigins "*" cannot be combined with allowCredentials; list the trusted origins instead`)
+	}
+	for _, origin := range c.AllowedOrigins {
+		if err := config.ValidateCORSOrigin(origin); err != nil {
+			return fmt.Errorf("allowedOrigins: %w", err)
+		}
+	}
+	for _, method := range c.AllowedMethods {
+		// Methods are tokens, like header names.
+		if !httpguts.ValidHeaderFieldName(method) {
+			return fmt.Errorf("allowedMethods: %q is not an HTTP method", method)
+		}
+	}
+	if c.MaxAge < 0 {
+		return fmt.Errorf("maxAge must not be negative, got %d", c.MaxAge)
+	}
+	return nil
+}
+
+// CORSModuleConfig parses the config of an http.middleware.cors module. It
+// allows every origin and GET, POST, PUT, DELETE and OPTIONS with the
+// default headers unless the config says otherwise.
+func CORSModuleConfig(cfg map[string]any) CORSMiddlewareConfig {
+	corsCfg := CORSMiddlewareConfig{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedHeaders: defaultCORSHeaders,
+	}
+	stringList := func(v []any) []string {
+		out := make([]string, len(v))
+		for i, item := range v {
+			out[i], _ = item.(string)
+		}
+		return out
+	}
+	if origins, ok := cfg["allowedOrigins"].([]any); ok {
+		corsCfg.AllowedOrigins = stringList(origins)
+	}
+	if methods, ok := cfg["allowedMethods"].([]any); ok {
+		corsCfg.AllowedMethods = stringList(methods)
+	}
+	if headers, ok := cfg["allowedHeaders"].([]any); ok && len(headers) > 0 {
+		corsCfg.AllowedHeaders = stringList(headers)
+	}
+	if allowCreds, ok := cfg["allowCredentials"].(bool); ok {
+		corsCfg.AllowCredentials = allowCreds
+	}
+	if maxAge, ok := cfg["maxAge"].(int); ok {
+		corsCfg.MaxAge = maxAge
+	} else if maxAgeFloat, ok := cfg["maxAge"].(float64); ok {
+		corsCfg.MaxAge = int(maxAgeFloat)
+	}
+	return corsCfg
+}
+
+// CORSPolicyFromConfig parses the `cors` block of an HTTP route:
+//
+//	cors:
+//	  allowedOrigins: [https://admin.example.com]
+//	  allowedMethods: [GET, POST]          # default: the route's method
+//	  allowedHeaders: [Authorization]      # default: Content-Type, Authorization
+//	  allowCredentials: true
+//	  maxAge: 600                          # seconds preflights may be cached
+//
+// allowedOrigins is required. It returns nil when raw is nil.
+func CORSPolicyFromConfig(raw any, method string) (*CORSMiddlewareConfig, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	m, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("cors must be a map, got %T", raw)
+	}
+	policy := &CORSMiddlewareConfig{}
+	for key, val := range m {
+		var err error
+		switch key {
+		case "allowedOrigins":
+			policy.AllowedOrigins, err = corsStringList(val)
+		case "allowedMethods":
+			policy.AllowedMethods, err = corsStringList(val)
+		case "allowedHeaders":
+			policy.AllowedHeaders, err = corsStringList(val)
+		case "allowCredentials":
+			if policy.AllowCredentials, ok = val.(bool); !ok {
+				err = errors.New("must be a bool")
+			}
+		case "maxAge":
+			n, ok := toFloat64(val)
+			if !ok || n != float64(int(n)) {
+				err = errors.New("must be a whole number of seconds")
+			}
+			policy.MaxAge = int(n)
+		default:
+			return nil, fmt.Errorf("cors: unknown key %q", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cors: %s %w", key, err)
+		}
+	}
+	if len(policy.AllowedOrigins) == 0 {
+		return nil, errors.New("cors: allowedOrigins is required")
+	}
+	if len(policy.AllowedMethods) == 0 && method != "" {
+		policy.AllowedMethods = []string{strings.ToUpper(method)}
+	}
+	if len(policy.AllowedHeaders) == 0 {
+		policy.AllowedHeaders = defaultCORSHeaders
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("cors: %w", err)
+	}
+	return policy, nil
+}
+
+// corsStringList converts a YAML list of strings.
+func corsStringList(v any) ([]string, error) {
+	switch list := v.(type) {
+	case []string:
+		return list, nil
+	case []any:
+		out := make([]string, len(list))
+		for i, item := range list {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("[%d] must be a string", i)
+			}
+			out[i] = s
+		}
+		return out, nil
+	default:
+		return nil, errors.New("must be a list of strings")
+	}
+}
+
+// writeCORSHeaders sets the CORS headers that let origin read the response.
+// The origin is reflected rather than answered with "*", so responses stay
+// valid when credentials are allowed.
+func writeCORSHeaders(w http.ResponseWriter, origin string, policy *CORSMiddlewareConfig) {
+	h := w.Header()
+	h.Set("Access-Control-Allow-Origin", origin)
+	h.Set("Access-Control-Allow-Methods", strings.Join(policy.AllowedMethods, ", "))
+	h.Set("Access-Control-Allow-Headers", strings.Join(policy.AllowedHeaders, ", "))
+	if policy.AllowCredentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+	if policy.MaxAge > 0 {
+		h.Set("Access-Control-Max-Age", strconv.Itoa(policy.MaxAge))
+	}
+}
+
+// writeCORSPreflight sets the headers of a preflight response. The request
+// is allowed when its origin, method and headers all are; otherwise no
+// Access-Control-Allow-* header is set and the browser blocks the request.
+// The response varies with all three request headers, so that caches do not
+// serve one origin's preflight to another.
+func writeCORSPreflight(w http.ResponseWriter, r *http.Request, policy *CORSMiddlewareConfig) {
+	w.Header().Add("Vary", "Origin, Access-Control-Request-Method, Access-Control-Request-Headers")
+	origin := r.Header.Get("Origin")
+	if !corsOriginAllowed(origin, policy.AllowedOrigins) {
+		return
+	}
+	if len(policy.AllowedMethods) > 0 && !corsMethodAllowed(r.Header.Get("Access-Control-Request-Method"), policy.AllowedMethods) {
+		return
+	}
+	for _, header := range strings.Split(r.Header.Get("Access-Control-Request-Headers"), ",") {
+		header = strings.TrimSpace(header)
+		if header != "" && !slices.ContainsFunc(policy.AllowedHeaders, func(h string) bool { return strings.EqualFold(h, header) }) {
+			return
+		}
+	}
+	writeCORSHeaders(w, origin, policy)
+}
+
+// corsMethodAllowed reports whether method is in allowed. GET and HEAD allow
+// each other, as routes do.
+func corsMethodAllowed(method string, allowed []string) bool {
+	for _, m := range allowed {
+		m = strings.ToUpper(m)
+		if m == method || (m == http.MethodGet && method == http.MethodHead) {
+			return true
+		}
+	}
+	return false
+}
+
+// corsRoutes matches requests to the per-route CORS policies of a router.
+type corsRoutes struct {
+	mux      *http.ServeMux
+	policies map[string]*CORSMiddlewareConfig
+}
+
+// newCORSRoutes compiles policies keyed by "METHOD path". It returns nil when
+// there are none.
+func newCORSRoutes(policies map[string]CORSMiddlewareConfig) *corsRoutes {
+	if len(policies) == 0 {
+		return nil
+	}
+	routes := &corsRoutes{mux: http.NewServeMux(), policies: make(map[string]*CORSMiddlewareConfig, len(policies))}
+	for pattern, policy := range policies {
+		routes.mux.Handle(pattern, http.NotFoundHandler())
+		routes.policies[pattern] = &policy
+	}
+	return routes
+}
+
+// match returns the policy of the route r is for, or nil when the route has
+// none. A preflight is for the route of its Access-Control-Request-Method.
+func (c *corsRoutes) match(r *http.Request) *CORSMiddlewareConfig {
+	if c == nil {
+		return nil
+	}
+	req := r
+	if method := r.Header.Get("Access-Control-Request-Method"); r.Method == http.MethodOptions && method != "" {
+		preflight := *r
+		preflight.Method = method
+		req = &preflight
+	}
+	_, pattern := c.mux.Handler(req)
+	return c.policies[pattern]
+}
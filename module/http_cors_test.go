@@ -0,0 +1,314 @@
+package module
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func corsRequest(router http.Handler, method, path, origin string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Origin", origin)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func preflight(method string, headers ...string) http.Header {
+	h := http.Header{"Access-Control-Request-Method": {method}}
+	if len(headers) > 0 {
+		h.Set("Access-Control-Request-Headers", strings.Join(headers, ", "))
+	}
+	return h
+}
+
+func TestRouter_RouteCORSPolicies(t *testing.T) {
+	router := NewStandardHTTPRouter("router")
+	router.AddGlobalMiddleware(NewCORSMiddlewareWithConfig("cors", CORSMiddlewareConfig{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{"GET"},
+	}))
+	router.AddRoute("GET", "/public/items", &slowHTTPHandler{})
+	router.AddRoute("POST", "/internal/jobs/{id}", &slowHTTPHandler{})
+	internal, err := CORSPolicyFromConfig(map[string]any{
+		"allowedOrigins":   []any{"https://admin.example.com"},
+		"allowedHeaders":   []any{"Authorization", "X-Request-Id"},
+		"allowCredentials": true,
+		"maxAge":           600,
+	}, "post")
+	if err != nil {
+		t.Fatal(err)
+	}
+	router.SetRouteCORSPolicy("POST", "/internal/jobs/{id}", *internal)
+	if err := router.Start(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	// The global policy applies to routes without their own.
+	w := corsRequest(router, "GET", "/public/items", "https://anyone.test", nil)
+	if w.Header().Get("Access-Control-Allow-Origin") != "https://anyone.test" {
+		t.Errorf("public origin = %q", w.Header().Get("Access-Control-Allow-Origin"))
+	}
+
+	// The route policy replaces it: other origins are not allowed.
+	w = corsRequest(router, "OPTIONS", "/internal/jobs/7", "https://anyone.test", preflight("POST"))
+	if w.Code != http.StatusOK || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("foreign preflight = %d %v", w.Code, w.Header())
+	}
+	w = corsRequest(router, "OPTIONS", "/internal/jobs/7", "https://admin.example.com", preflight("POST", "x-request-id"))
+	h := w.Header()
+	if w.Code != http.StatusOK || h.Get("Access-Control-Allow-Origin") != "https://admin.example.com" {
+		t.Fatalf("preflight = %d %v", w.Code, h)
+	}
+	if h.Get("Access-Control-Allow-Methods") != "POST" || h.Get("Access-Control-Allow-Credentials") != "true" || h.Get("Access-Control-Max-Age") != "600" {
+		t.Errorf("preflight headers = %v", h)
+	}
+	if vary := h.Get("Vary"); !strings.Contains(vary, "Access-Control-Request-Method") {
+		t.Errorf("Vary = %q", vary)
+	}
+	// Disallowed methods and headers get no CORS headers.
+	for _, hdr := range []http.Header{preflight("DELETE"), preflight("POST", "X-Secret")} {
+		w = corsRequest(router, "OPTIONS", "/internal/jobs/7", "https://admin.example.com", hdr)
+		if w.Header().Get("Access-Control-Allow-Origin") != "" {
+			t.Errorf("preflight %v allowed", hdr)
+		}
+	}
+	w = corsRequest(router, "POST", "/internal/jobs/7", "https://admin.example.com", nil)
+	if w.Code != http.StatusOK || w.Header().Get("Access-Control-Allow-Credentials") != "true" {
+		t.Errorf("actual request = %d %v", w.Code, w.Header())
+	}
+}
+
+func TestRouter_RouteCORSWithoutMiddleware(t *testing.T) {
+	router := NewStandardHTTPRouter("router")
+	router.AddRoute("GET", "/items", &slowHTTPHandler{})
+	router.AddRoute("GET", "/other", &slowHTTPHandler{})
+	if err := router.Start(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	// Policies set after Start apply too.
+	router.SetRouteCORSPolicy("GET", "/items", CORSMiddlewareConfig{
+		AllowedOrigins: []string{"https://app.example.com"},
+		AllowedMethods: []string{"GET"},
+		AllowedHeaders: defaultCORSHeaders,
+	})
+
+	w := corsRequest(router, "OPTIONS", "/items", "https://app.example.com", preflight("GET"))
+	if w.Code != http.StatusOK || w.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" {
+		t.Errorf("preflight = %d %v", w.Code, w.Header())
+	}
+	// Routes without a policy are left alone.
+	w = corsRequest(router, "GET", "/other", "https://app.example.com", nil)
+	if w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("other route = %v", w.Header())
+	}
+	w = corsRequest(router, "OPTIONS", "/other", "https://app.example.com", preflight("GET"))
+	if w.Code == http.StatusOK || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("other route preflight answered")
+	}
+}
+
+func TestCORSPolicyFromConfig(t *testing.T) {
+	policy, err := CORSPolicyFromConfig(map[string]any{"allowedOrigins": []any{"*"}}, "get")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(policy.AllowedMethods) != 1 || policy.AllowedMethods[0] != "GET" || len(policy.AllowedHeaders) != 2 {
+		t.Errorf("defaults = %+v", policy)
+	}
+	if policy, err := CORSPolicyFromConfig(nil, "GET"); policy != nil || err != nil {
+		t.Errorf("nil = %v, %v", policy, err)
+	}
+
+	tests := []struct {
+		raw  map[string]any
+		want string
+	}{
+		{map[string]any{}, "allowedOrigins is required"},
+		{map[string]any{"allowedOrigins": []any{"*"}, "allowCredentials": true}, `"*" cannot be combined with allowCredentials`},
+		{map[string]any{"allowedOrigins": []any{"*"}, "maxAge": -1}, "maxAge must not be negative"},
+		{map[string]any{"allowedOrigins": "*"}, "allowedOrigins must be a list of strings"},
+		{map[string]any{"allowedOrigins": []any{"*"}, "origins": []any{"x"}}, `unknown key "origins"`},
+	}
+	for _, tt := range tests {
+		_, err := CORSPolicyFromConfig(tt.raw, "GET")
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%v: err = %v, want %q", tt.raw, err, tt.want)
+		}
+	}
+}
+
+func TestCORSMiddleware_InitRejectsWildcardCredentials(t *testing.T) {
+	m := NewCORSMiddlewareWithConfig("cors", CORSMiddlewareConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true})
+	if err := m.Init(nil); err == nil || !strings.Contains(err.Error(), `cors middleware "cors"`) {
+		t.Errorf("Init = %v", err)
+	}
+	m = NewCORSMiddlewareWithConfig("cors", CORSMiddlewareConfig{AllowedOrigins: []string{"*.example.com"}, AllowCredentials: true})
+	if err := m.Init(nil); err != nil {
+		t.Errorf("subdomain wildcard: %v", err)
+	}
+}
+
+// corsErrorHandler fails the way pipelines do: with http.Error, or with a
+// step.json_response writing an error status.
+type corsErrorHandler struct{ jsonResponse bool }
+
+func (h corsErrorHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	if !h.jsonResponse {
+		http.Error(w, "pipeline failed", http.StatusInternalServerError)
+		return
+	}
+	step, err := NewJSONResponseStepFactory()("respond", map[string]any{
+		"status":  422,
+		"body":    map[string]any{"error": "invalid"},
+		"headers": map[string]any{"X-Reason": "invalid"},
+	}, nil)
+	if err != nil {
+		panic(err)
+	}
+	pc := NewPipelineContext(nil, map[string]any{"_http_response_writer": w})
+	if _, err := step.Execute(r.Context(), pc); err != nil {
+		panic(err)
+	}
+}
+
+// requireToken rejects requests without an Authorization header, as an
+// auth middleware does.
+type requireToken struct{}
+
+func (requireToken) Process(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func TestRouter_CORSHeadersOnErrorResponses(t *testing.T) {
+	router := NewStandardHTTPRouter("router")
+	// The auth middleware is added first, but CORS still runs outermost.
+	router.AddGlobalMiddleware(requireToken{})
+	router.AddGlobalMiddleware(NewCORSMiddlewareWithConfig("cors", CORSMiddlewareConfig{
+		AllowedOrigins: []string{"https://app.example.com"},
+		AllowedMethods: []string{"GET", "POST"},
+		MaxAge:         600,
+	}))
+	router.AddRoute("GET", "/items", &slowHTTPHandler{})
+	router.AddRoute("POST", "/items", corsErrorHandler{})
+	router.AddRoute("PUT", "/items", corsErrorHandler{jsonResponse: true})
+	router.AddRoute("GET", "/panic", &panicHTTPHandler{})
+	if err := router.Start(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	token := http.Header{"Authorization": {"Bearer t"}}
+
+	// Preflights carry no credentials and are answered before auth.
+	w := corsRequest(router, "OPTIONS", "/items", "https://app.example.com", preflight("POST", "Authorization"))
+	if w.Code != http.StatusOK || w.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" || w.Header().Get("Access-Control-Max-Age") != "600" {
+		t.Errorf("preflight = %d %v", w.Code, w.Header())
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		header http.Header
+		code   int
+	}{
+		{"success", "GET", "/items", token, http.StatusOK},
+		{"middleware 401", "GET", "/items", nil, http.StatusUnauthorized},
+		{"unknown route 404", "GET", "/missing", token, http.StatusNotFound},
+		{"pipeline 500", "POST", "/items", token, http.StatusInternalServerError},
+		{"json_response 422", "PUT", "/items", token, http.StatusUnprocessableEntity},
+		{"panic 500", "GET", "/panic", token, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := corsRequest(router, tt.method, tt.path, "https://app.example.com", tt.header)
+			if w.Code != tt.code {
+				t.Fatalf("code = %d, want %d", w.Code, tt.code)
+			}
+			if w.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" {
+				t.Errorf("allowed origin got headers %v", w.Header())
+			}
+			w = corsRequest(router, tt.method, tt.path, "https://evil.test", tt.header)
+			if w.Code != tt.code || w.Header().Get("Access-Control-Allow-Origin") != "" {
+				t.Errorf("disallowed origin = %d %v", w.Code, w.Header())
+			}
+			if w.Header().Get("Vary") != "Origin" {
+				t.Errorf("Vary = %q", w.Header().Get("Vary"))
+			}
+		})
+	}
+
+	w = corsRequest(router, "PUT", "/items", "https://app.example.com", token)
+	if w.Header().Get("X-Reason") != "invalid" || !strings.Contains(w.Body.String(), "invalid") {
+		t.Errorf("json_response = %v %s", w.Header(), w.Body.String())
+	}
+}
+
+func TestRouter_CORSHandlerCoversServerResponses(t *testing.T) {
+	router := NewStandardHTTPRouter("router")
+	router.AddGlobalMiddleware(NewCORSMiddlewareWithConfig("cors", CORSMiddlewareConfig{
+		AllowedOrigins: []string{"https://app.example.com"},
+		AllowedMethods: []string{"GET"},
+	}))
+	router.AddRoute("GET", "/items", &slowHTTPHandler{})
+	// The server wraps its handler before the router starts; the router's
+	// CORS middlewares are looked up per request.
+	shed := router.CORSHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "busy", http.StatusServiceUnavailable)
+	}))
+	served := router.CORSHandler(router)
+	if err := router.Start(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	w := corsRequest(shed, "GET", "/items", "https://app.example.com", nil)
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" {
+		t.Errorf("503 = %d %v", w.Code, w.Header())
+	}
+	w = corsRequest(shed, "GET", "/items", "https://evil.test", nil)
+	if w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("disallowed 503 = %v", w.Header())
+	}
+
+	// The router does not apply the policy a second time.
+	w = corsRequest(served, "GET", "/items", "https://app.example.com", nil)
+	if w.Code != http.StatusOK || len(w.Header().Values("Vary")) != 1 {
+		t.Errorf("served = %d %v", w.Code, w.Header())
+	}
+}
+
+func TestCORSMiddlewareConfig_Validate(t *testing.T) {
+	valid := CORSMiddlewareConfig{
+		AllowedOrigins: []string{"*.example.com", "https://app.example.com", "http://localhost:3000"},
+		AllowedMethods: []string{"GET", "PROPFIND"},
+	}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("valid policy: %v", err)
+	}
+
+	tests := []struct {
+		cfg  CORSMiddlewareConfig
+		want string
+	}{
+		{CORSMiddlewareConfig{AllowedOrigins: []string{"https://app.example.com/"}}, "trailing slash"},
+		{CORSMiddlewareConfig{AllowedOrigins: []string{"app.example.com"}}, "has no scheme; write it as https://app.example.com"},
+		{CORSMiddlewareConfig{AllowedOrigins: []string{"https://app.example.com/api"}}, "not scheme://host[:port]"},
+		{CORSMiddlewareConfig{AllowedOrigins: []string{"https://*.example.com"}}, "wildcards are only supported"},
+		{CORSMiddlewareConfig{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET, POST"}}, `"GET, POST" is not an HTTP method`},
+		{CORSMiddlewareConfig{AllowedOrigins: []string{"*"}, AllowedMethods: []string{""}}, `"" is not an HTTP method`},
+	}
+	for _, tt := range tests {
+		if err := tt.cfg.Validate(); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%+v: err = %v, want %q", tt.cfg, err, tt.want)
+		}
+	}
+}
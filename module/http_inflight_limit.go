@@ -0,0 +1,222 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"math"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Defaults of InFlightLimits fields left unset.
+const (
+	defaultInFlightQueueTimeout = 10 * time.Second
+	defaultInFlightRetryAfter   = time.Second
+)
+
+// InFlightLimits bounds the number of requests handled at once. Requests
+// beyond MaxInFlight wait in a queue of at most MaxQueued requests for up to
+// QueueTimeout; requests that find the queue full or time out in it are shed
+// with 503 and a Retry-After of RetryAfter. MaxInFlight of 0 means no limit.
+type InFlightLimits struct {
+	MaxInFlight  int           `json:"maxInFlight,omitempty" yaml:"maxInFlight,omitempty"`
+	MaxQueued    int           `json:"maxQueued,omitempty" yaml:"maxQueued,omitempty"`
+	QueueTimeout time.Duration `json:"queueTimeout,omitempty" yaml:"queueTimeout,omitempty"`
+	RetryAfter   time.Duration `json:"retryAfter,omitempty" yaml:"retryAfter,omitempty"`
+}
+
+// InFlightLimitsFromConfig reads the maxInFlight, maxQueued, queueTimeout and
+// retryAfter keys of a server or route config.
+func InFlightLimitsFromConfig(cfg map[string]any) InFlightLimits {
+	var limits InFlightLimits
+	if n, ok := toFloat64(cfg["maxInFlight"]); ok {
+		limits.MaxInFlight = int(n)
+	}
+	if n, ok := toFloat64(cfg["maxQueued"]); ok {
+		limits.MaxQueued = int(n)
+	}
+	if v, ok := cfg["queueTimeout"].(string); ok {
+		if d, err := time.ParseDuration(v); err == nil {
+			limits.QueueTimeout = d
+		}
+	}
+	if v, ok := cfg["retryAfter"].(string); ok {
+		if d, err := time.ParseDuration(v); err == nil {
+			limits.RetryAfter = d
+		}
+	}
+	return limits
+}
+
+// IsZero reports whether no in-flight limit is set.
+func (l InFlightLimits) IsZero() bool {
+	return l.MaxInFlight <= 0
+}
+
+// InFlightStats is a snapshot of an InFlightLimiter.
+type InFlightStats struct {
+	MaxInFlight int   `json:"maxInFlight"`
+	InFlight    int64 `json:"inFlight"`
+	Queued      int64 `json:"queued"`
+	Rejected    int64 `json:"rejected"`
+}
+
+// InFlightLimiter enforces InFlightLimits. It is safe for concurrent use.
+type InFlightLimiter struct {
+	limits   InFlightLimits
+	slots    chan struct{}
+	inFlight atomic.Int64
+	queued   atomic.Int64
+	rejected atomic.Int64
+}
+
+// NewInFlightLimiter creates a limiter for limits, or returns nil when
+// limits sets no limit. A nil limiter admits every request.
+func NewInFlightLimiter(limits InFlightLimits) *InFlightLimiter {
+	if limits.IsZero() {
+		return nil
+	}
+	if limits.QueueTimeout <= 0 {
+		limits.QueueTimeout = defaultInFlightQueueTimeout
+	}
+	if limits.RetryAfter <= 0 {
+		limits.RetryAfter = defaultInFlightRetryAfter
+	}
+	return &InFlightLimiter{limits: limits, slots: make(chan struct{}, limits.MaxInFlight)}
+}
+
+// Acquire takes a slot, waiting in the queue if none is free. It returns
+// false when the request is shed: the queue is full, the queue timeout
+// elapsed or ctx was done. Release must be called after a successful Acquire.
+func (l *InFlightLimiter) Acquire(ctx context.Context) bool {
+	if l == nil {
+		return true
+	}
+	select {
+	case l.slots <- struct{}{}:
+		l.inFlight.Add(1)
+		return true
+	default:
+	}
+
+	if l.queued.Add(1) > int64(l.limits.MaxQueued) {
+		l.queued.Add(-1)
+		l.rejected.Add(1)
+		return false
+	}
+	defer l.queued.Add(-1)
+
+	timer := time.NewTimer(l.limits.QueueTimeout)
+	defer timer.Stop()
+	select {
+	case l.slots <- struct{}{}:
+		l.inFlight.Add(1)
+		return true
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	l.rejected.Add(1)
+	return false
+}
+
+// Release frees a slot taken by Acquire.
+func (l *InFlightLimiter) Release() {
+	if l == nil {
+		return
+	}
+	l.inFlight.Add(-1)
+	<-l.slots
+}
+
+// Stats returns the limiter's current counts.
+func (l *InFlightLimiter) Stats() InFlightStats {
+	if l == nil {
+		return InFlightStats{}
+	}
+	return InFlightStats{
+		MaxInFlight: l.limits.MaxInFlight,
+		InFlight:    l.inFlight.Load(),
+		Queued:      l.queued.Load(),
+		Rejected:    l.rejected.Load(),
+	}
+}
+
+// Handler wraps next so that it runs only while holding a slot. Shed
+// requests get 503 with a Retry-After header. A nil limiter returns next.
+func (l *InFlightLimiter) Handler(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !l.Acquire(r.Context()) {
+			WriteServerBusy(w, l.limits.RetryAfter)
+			return
+		}
+		defer l.Release()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// WriteServerBusy writes the 503 response for a request shed by an
+// InFlightLimiter, asking the client to retry after retryAfter.
+func WriteServerBusy(w http.ResponseWriter, retryAfter time.Duration) {
+	seconds := int64(math.Ceil(retryAfter.Seconds()))
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", strconv.FormatInt(max(seconds, 1), 10))
+	w.WriteHeader(http.StatusServiceUnavailable)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": "server busy: too many requests in flight"})
+}
+
+// inFlightMetrics exports the stats of a server's in-flight limiters. The
+// route label is empty for the server-wide limiter.
+type inFlightMetrics struct {
+	server string
+	stats  func() map[string]InFlightStats
+
+	inFlight *prometheus.Desc
+	queued   *prometheus.Desc
+	rejected *prometheus.Desc
+}
+
+// newInFlightMetrics creates the collector of a server's limiters. stats is
+// called on every scrape so limiters of routes added later are included.
+func newInFlightMetrics(namespace, subsystem, server string, stats func() map[string]InFlightStats) *inFlightMetrics {
+	labels := []string{"server", "route"}
+	return &inFlightMetrics{
+		server:   server,
+		stats:    stats,
+		inFlight: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "http_in_flight_requests"), "Number of HTTP requests currently being handled under an in-flight limit", labels, nil),
+		queued:   prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "http_queued_requests"), "Number of HTTP requests waiting for an in-flight slot", labels, nil),
+		rejected: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "http_rejected_requests_total"), "Total number of HTTP requests shed with 503 by an in-flight limit", labels, nil),
+	}
+}
+
+// Describe sends no descriptors, making the collector unchecked so that
+// several servers can export the same metric names.
+func (m *inFlightMetrics) Describe(chan<- *prometheus.Desc) {}
+
+// Collect implements prometheus.Collector.
+func (m *inFlightMetrics) Collect(ch chan<- prometheus.Metric) {
+	for route, st := range m.stats() {
+		ch <- prometheus.MustNewConstMetric(m.inFlight, prometheus.GaugeValue, float64(st.InFlight), m.server, route)
+		ch <- prometheus.MustNewConstMetric(m.queued, prometheus.GaugeValue, float64(st.Queued), m.server, route)
+		ch <- prometheus.MustNewConstMetric(m.rejected, prometheus.CounterValue, float64(st.Rejected), m.server, route)
+	}
+}
+
+// mergeInFlightStats adds the server-wide stats, keyed "", to route stats.
+func mergeInFlightStats(server *InFlightLimiter, routes map[string]InFlightStats) map[string]InFlightStats {
+	out := maps.Clone(routes)
+	if out == nil {
+		out = make(map[string]InFlightStats)
+	}
+	if server != nil {
+		out[""] = server.Stats()
+	}
+	return out
+}
@@ -0,0 +1,155 @@
+package module
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// blockingHandler holds every request until release is closed, signalling
+// entered as each request starts.
+type blockingHandler struct {
+	entered chan struct{}
+	release chan struct{}
+}
+
+func newBlockingHandler() *blockingHandler {
+	return &blockingHandler{entered: make(chan struct{}, 16), release: make(chan struct{})}
+}
+
+func (h *blockingHandler) Handle(w http.ResponseWriter, _ *http.Request) {
+	h.entered <- struct{}{}
+	<-h.release
+	w.WriteHeader(http.StatusOK)
+}
+
+// noContentHandler answers 204.
+type noContentHandler struct{}
+
+func (noContentHandler) Handle(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func TestInFlightLimitsFromConfig(t *testing.T) {
+	require.Equal(t,
+		InFlightLimits{MaxInFlight: 100, MaxQueued: 10, QueueTimeout: 2 * time.Second, RetryAfter: 5 * time.Second},
+		InFlightLimitsFromConfig(map[string]any{"maxInFlight": 100, "maxQueued": 10.0, "queueTimeout": "2s", "retryAfter": "5s"}))
+	require.True(t, InFlightLimitsFromConfig(map[string]any{}).IsZero())
+	require.Nil(t, NewInFlightLimiter(InFlightLimits{}))
+}
+
+func TestInFlightLimiter_QueuesThenSheds(t *testing.T) {
+	limiter := NewInFlightLimiter(InFlightLimits{MaxInFlight: 1, MaxQueued: 1, RetryAfter: 1500 * time.Millisecond})
+	backend := newBlockingHandler()
+	h := limiter.Handler(http.HandlerFunc(backend.Handle))
+
+	codes := make(chan int, 2)
+	var wg sync.WaitGroup
+	serve := func() {
+		defer wg.Done()
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+		codes <- rr.Code
+	}
+
+	wg.Add(1)
+	go serve()
+	<-backend.entered
+	wg.Add(1)
+	go serve()
+	require.Eventually(t, func() bool { return limiter.Stats().Queued == 1 }, time.Second, time.Millisecond)
+
+	// The slot and the queue are taken, so a third request is shed.
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	require.Equal(t, "2", rr.Header().Get("Retry-After"))
+	require.Equal(t, InFlightStats{MaxInFlight: 1, InFlight: 1, Queued: 1, Rejected: 1}, limiter.Stats())
+
+	// Releasing the first request lets the queued one run.
+	close(backend.release)
+	<-backend.entered
+	wg.Wait()
+	require.Equal(t, http.StatusOK, <-codes)
+	require.Equal(t, http.StatusOK, <-codes)
+	require.Equal(t, InFlightStats{MaxInFlight: 1, Rejected: 1}, limiter.Stats())
+}
+
+func TestInFlightLimiter_QueueTimeout(t *testing.T) {
+	limiter := NewInFlightLimiter(InFlightLimits{MaxInFlight: 1, MaxQueued: 5, QueueTimeout: 20 * time.Millisecond})
+	require.True(t, limiter.Acquire(t.Context()))
+	defer limiter.Release()
+
+	start := time.Now()
+	require.False(t, limiter.Acquire(t.Context()))
+	require.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	require.Equal(t, int64(1), limiter.Stats().Rejected)
+	require.Zero(t, limiter.Stats().Queued)
+}
+
+func TestStandardHTTPRouter_RouteInFlightLimits(t *testing.T) {
+	router := NewStandardHTTPRouter("router")
+	backend := newBlockingHandler()
+	router.AddRoute(http.MethodGet, "/slow", backend)
+	router.AddRoute(http.MethodGet, "/fast", noContentHandler{})
+	router.SetRouteInFlightLimits(http.MethodGet, "/slow", InFlightLimits{MaxInFlight: 1})
+	require.NoError(t, router.Start(t.Context()))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+	}()
+	<-backend.entered
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+
+	// Other routes are not limited.
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	require.Equal(t, http.StatusNoContent, rr.Code)
+
+	require.Equal(t, map[string]InFlightStats{"GET /slow": {MaxInFlight: 1, InFlight: 1, Rejected: 1}}, router.InFlightStats())
+	close(backend.release)
+	<-done
+}
+
+func TestInFlightMetrics(t *testing.T) {
+	mc := NewMetricsCollector("metrics")
+	server := NewInFlightLimiter(InFlightLimits{MaxInFlight: 2})
+	require.True(t, server.Acquire(t.Context()))
+	defer server.Release()
+	routes := map[string]InFlightStats{"POST /upload": {MaxInFlight: 1, Rejected: 3}}
+	require.NoError(t, mc.RegisterCollector(newInFlightMetrics("workflow", "", "server", func() map[string]InFlightStats {
+		return mergeInFlightStats(server, routes)
+	})))
+
+	families, err := mc.Gather()
+	require.NoError(t, err)
+	values := make(map[string]float64)
+	for _, f := range families {
+		for _, m := range f.GetMetric() {
+			route := ""
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "route" {
+					route = l.GetValue()
+				}
+			}
+			switch {
+			case m.GetGauge() != nil:
+				values[f.GetName()+"|"+route] = m.GetGauge().GetValue()
+			case m.GetCounter() != nil:
+				values[f.GetName()+"|"+route] = m.GetCounter().GetValue()
+			}
+		}
+	}
+	require.Equal(t, 1.0, values["workflow_http_in_flight_requests|"])
+	require.Equal(t, 3.0, values["workflow_http_rejected_requests_total|POST /upload"])
+	require.Contains(t, values, "workflow_http_queued_requests|POST /upload")
+}
@@ -182,7 +182,7 @@ func (m *RateLimitMiddleware) Process(next http.Handler) http.Handler {
 				retryAfter = strconv.Itoa(int(math.Ceil(secondsUntilToken)))
 			}
 			w.Header().Set("Retry-After", retryAfter)
-			http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+			writeRequestProblem(w, r, ProblemRateLimited, "rate limit exceeded")
 			return
 		}
 
@@ -341,14 +341,15 @@ type CORSMiddlewareConfig struct {
 	MaxAge int
 }
 
-// CORSMiddleware provides CORS support
+// CORSMiddleware provides CORS support. Routes with a CORS policy of their
+// own (see StandardHTTPRouter.SetRouteCORSPolicy) use it instead of the
+// middleware's.
 type CORSMiddleware struct {
-	name             string
-	allowedOrigins   []string
-	allowedMethods   []string
-	allowedHeaders   []string
-	allowCredentials bool
-	maxAge           int
+	name   string
+	policy CORSMiddlewareConfig
+	// routeOnly marks the implicit middleware of a router without one: it
+	// answers only requests to routes with a policy of their own.
+	routeOnly bool
 }
 
 // defaultCORSHeaders is the default set of allowed headers for backward compatibility.
@@ -365,17 +366,12 @@ func NewCORSMiddleware(name string, allowedOrigins, allowedMethods []string) *CO
 // NewCORSMiddlewareWithConfig creates a new CORS middleware with full configuration.
 // If AllowedHeaders is empty, it defaults to ["Content-Type", "Authorization"].
 func NewCORSMiddlewareWithConfig(name string, cfg CORSMiddlewareConfig) *CORSMiddleware {
-	headers := cfg.AllowedHeaders
-	if len(headers) == 0 {
-		headers = defaultCORSHeaders
+	if len(cfg.AllowedHeaders) == 0 {
+		cfg.AllowedHeaders = defaultCORSHeaders
 	}
 	return &CORSMiddleware{
-		name:             name,
-		allowedOrigins:   cfg.AllowedOrigins,
-		allowedMethods:   cfg.AllowedMethods,
-		allowedHeaders:   headers,
-		allowCredentials: cfg.AllowCredentials,
-		maxAge:           cfg.MaxAge,
+		name:   name,
+		policy: cfg,
 	}
 }
 
@@ -384,8 +380,11 @@ func (m *CORSMiddleware) Name() string {
 	return m.name
 }
 
-// Init initializes the middleware
+// Init validates the middleware's policy.
 func (m *CORSMiddleware) Init(app modular.Application) error {
+	if err := m.policy.Validate(); err != nil {
+		return fmt.Errorf("cors middleware %q: %w", m.name, err)
+	}
 	return nil
 }
 
@@ -416,26 +415,48 @@ func corsOriginAllowed(origin string, allowedOrigins []string) bool {
 
 // Process implements middleware processing
 func (m *CORSMiddleware) Process(next http.Handler) http.Handler {
+	return m.processRoutes(next, nil)
+}
+
+// corsAppliedKey marks a request whose CORS headers a CORS middleware has
+// already written, so that CORS middlewares further in, such as a router's
+// when its server applies the router's policies too, pass it through.
+type corsAppliedKey struct{}
+
+// processRoutes is Process with the per-route policies of a router, which
+// replace the middleware's policy on the routes they match.
+func (m *CORSMiddleware) processRoutes(next http.Handler, routes *corsRoutes) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Context().Value(corsAppliedKey{}) != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		policy := routes.match(r)
+		if policy == nil {
+			if m.routeOnly {
+				next.ServeHTTP(w, r)
+				return
+			}
+			policy = &m.policy
+		}
+		r = r.WithContext(context.WithValue(r.Context(), corsAppliedKey{}, true))
 		origin := r.Header.Get("Origin")
 
 		// Only apply CORS headers when the request includes an Origin header.
 		// Requests without Origin are not cross-origin requests and need no CORS response.
-		if origin != "" && corsOriginAllowed(origin, m.allowedOrigins) {
-			w.Header().Add("Vary", "Origin")
-			w.Header().Set("Access-Control-Allow-Origin", origin)
-			w.Header().Set("Access-Control-Allow-Methods", strings.Join(m.allowedMethods, ", "))
-			w.Header().Set("Access-Control-Allow-Headers", strings.Join(m.allowedHeaders, ", "))
-			if m.allowCredentials {
-				w.Header().Set("Access-Control-Allow-Credentials", "true")
-			}
-			if m.maxAge > 0 {
-				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(m.maxAge))
+		if origin != "" {
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				writeCORSPreflight(w, r, policy)
+			} else {
+				w.Header().Add("Vary", "Origin")
+				if corsOriginAllowed(origin, policy.AllowedOrigins) {
+					writeCORSHeaders(w, origin, policy)
+				}
 			}
 		}
 
 		// Handle preflight requests
-		if r.Method == "OPTIONS" {
+		if r.Method == http.MethodOptions {
 			w.WriteHeader(http.StatusOK)
 			return
 		}
@@ -57,6 +57,12 @@ type StandardHTTPRouter struct {
 	serveMux          *http.ServeMux
 	wrappedMux        http.Handler // pre-built: serveMux wrapped with globalMiddlewares
 	globalMiddlewares []HTTPMiddleware
+	defaultLimits     BodyLimits                      // server-wide body limits
+	routeLimits       map[string]BodyLimits           // per-route overrides, key: "METHOD path"
+	routeInFlight     map[string]*InFlightLimiter     // per-route in-flight limiters, key: "METHOD path"
+	routeCORS         map[string]CORSMiddlewareConfig // per-route CORS policies, key: "METHOD path"
+	cors              []*CORSMiddleware               // pre-built: CORS middlewares applied outermost
+	corsRoutes        *corsRoutes                     // pre-built: compiled routeCORS
 }
 
 // NewStandardHTTPRouter creates a new HTTP router
@@ -144,7 +150,8 @@ func (r *StandardHTTPRouter) AddRouteWithMiddleware(method, path string, handler
 
 // AddGlobalMiddleware appends a middleware that wraps every request served by
 // this router, regardless of which route is matched. Global middlewares are
-// applied in the order they are added, before any per-route middlewares.
+// applied in the order they are added, before any per-route middlewares,
+// except that CORS middlewares always run first.
 // This is the correct place to attach cross-cutting concerns such as
 // distributed tracing that must observe all traffic.
 func (r *StandardHTTPRouter) AddGlobalMiddleware(mw HTTPMiddleware) {
@@ -158,6 +165,77 @@ func (r *StandardHTTPRouter) AddGlobalMiddleware(mw HTTPMiddleware) {
 	}
 }
 
+// SetDefaultBodyLimits sets the body limits of every route without its own,
+// normally the limits of the HTTP server the router is attached to.
+func (r *StandardHTTPRouter) SetDefaultBodyLimits(limits BodyLimits) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.defaultLimits = limits
+	if r.serveMux != nil {
+		r.rebuildMuxLocked()
+	}
+}
+
+// SetRouteBodyLimits overrides the default body limits for one route. The
+// route may be added before or after its limits are set.
+func (r *StandardHTTPRouter) SetRouteBodyLimits(method, path string, limits BodyLimits) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.routeLimits == nil {
+		r.routeLimits = make(map[string]BodyLimits)
+	}
+	r.routeLimits[method+" "+path] = limits
+	if r.serveMux != nil {
+		r.rebuildMuxLocked()
+	}
+}
+
+// SetRouteInFlightLimits limits the number of concurrent requests to one
+// route, in addition to any server-wide limit. The route may be added before
+// or after its limits are set.
+func (r *StandardHTTPRouter) SetRouteInFlightLimits(method, path string, limits InFlightLimits) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.routeInFlight == nil {
+		r.routeInFlight = make(map[string]*InFlightLimiter)
+	}
+	r.routeInFlight[method+" "+path] = NewInFlightLimiter(limits)
+	if r.serveMux != nil {
+		r.rebuildMuxLocked()
+	}
+}
+
+// SetRouteCORSPolicy gives one route a CORS policy of its own. The router's
+// CORS middleware applies it, instead of its own policy, to requests to the
+// route and to their preflights; a router without a CORS middleware applies
+// route policies only. The route may be added before or after its policy is
+// set.
+func (r *StandardHTTPRouter) SetRouteCORSPolicy(method, path string, policy CORSMiddlewareConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.routeCORS == nil {
+		r.routeCORS = make(map[string]CORSMiddlewareConfig)
+	}
+	r.routeCORS[method+" "+path] = policy
+	if r.serveMux != nil {
+		r.rebuildMuxLocked()
+	}
+}
+
+// InFlightStats returns the stats of the per-route in-flight limiters, keyed
+// by "METHOD path".
+func (r *StandardHTTPRouter) InFlightStats() map[string]InFlightStats {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	stats := make(map[string]InFlightStats, len(r.routeInFlight))
+	for key, limiter := range r.routeInFlight {
+		if limiter != nil {
+			stats[key] = limiter.Stats()
+		}
+	}
+	return stats
+}
+
 // HasRoute checks if a route with the given method and path already exists
 func (r *StandardHTTPRouter) HasRoute(method, path string) bool {
 	r.mu.RLock()
@@ -192,7 +270,7 @@ func (r *StandardHTTPRouter) ServeHTTP(w http.ResponseWriter, req *http.Request)
 		if rec := recover(); rec != nil {
 			slog.Error("panic in HTTP handler", "panic", rec, "stack", string(debug.Stack()))
 			if !rw.written.Load() {
-				http.Error(rw, "Internal Server Error", http.StatusInternalServerError)
+				writeRequestProblem(rw, req, ProblemInternalError, "")
 				return
 			}
 		}
@@ -214,6 +292,8 @@ func (r *StandardHTTPRouter) Start(ctx context.Context) error {
 func (r *StandardHTTPRouter) rebuildMuxLocked() {
 	mux := http.NewServeMux()
 	for _, route := range r.routes {
+		limits := r.defaultLimits.Merge(r.routeLimits[route.Method+" "+route.Path])
+		limiter := r.routeInFlight[route.Method+" "+route.Path]
 		mux.HandleFunc(fmt.Sprintf("%s %s", route.Method, route.Path), func(w http.ResponseWriter, r *http.Request) {
 			// Inject path params into context so triggers can read them via r.Context().Value(paramsKey).
 			if params := extractRouteParams(route.Path, r); len(params) > 0 {
@@ -232,6 +312,12 @@ func (r *StandardHTTPRouter) rebuildMuxLocked() {
 				}
 			}
 
+			// Body limits apply before any middleware reads the body
+			handler = BodyLimitHandler(handler, limits)
+
+			// Shed excess requests before doing any work for them
+			handler = limiter.Handler(handler)
+
 			// Execute the handler chain
 			handler.ServeHTTP(w, r)
 		})
@@ -240,11 +326,49 @@ func (r *StandardHTTPRouter) rebuildMuxLocked() {
 	r.serveMux = mux
 
 	// Pre-wrap with global middlewares so ServeHTTP only needs to read one pointer.
+	// CORS middlewares go outermost, whatever their position, so responses
+	// other middlewares reject a request with, such as a 401, carry the CORS
+	// headers too and preflights are answered before authentication. They
+	// also get the per-route CORS policies.
 	var h http.Handler = mux
+	r.cors = nil
+	for _, mw := range r.globalMiddlewares {
+		if cors, ok := mw.(*CORSMiddleware); ok {
+			r.cors = append(r.cors, cors)
+		}
+	}
 	for i := len(r.globalMiddlewares) - 1; i >= 0; i-- {
-		h = r.globalMiddlewares[i].Process(h)
+		if _, ok := r.globalMiddlewares[i].(*CORSMiddleware); !ok {
+			h = r.globalMiddlewares[i].Process(h)
+		}
+	}
+	r.corsRoutes = newCORSRoutes(r.routeCORS)
+	if r.corsRoutes != nil && len(r.cors) == 0 {
+		r.cors = []*CORSMiddleware{{name: r.name + "-cors", routeOnly: true}}
 	}
-	r.wrappedMux = h
+	r.wrappedMux = wrapCORS(h, r.cors, r.corsRoutes)
+}
+
+// wrapCORS wraps h with CORS middlewares, the first outermost.
+func wrapCORS(h http.Handler, cors []*CORSMiddleware, routes *corsRoutes) http.Handler {
+	for i := len(cors) - 1; i >= 0; i-- {
+		h = cors[i].processRoutes(h, routes)
+	}
+	return h
+}
+
+// CORSHandler wraps next, typically the handler chain of the HTTP server
+// the router is attached to, with the router's CORS middlewares and route
+// policies as they are when a request arrives. Responses the server writes
+// itself, such as a 503 from its in-flight limit, then carry the same CORS
+// headers as the router's; the router does not apply them a second time.
+func (r *StandardHTTPRouter) CORSHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.mu.RLock()
+		cors, routes := r.cors, r.corsRoutes
+		r.mu.RUnlock()
+		wrapCORS(next, cors, routes).ServeHTTP(w, req)
+	})
 }
 
 // Stop is a no-op for router (implements Stoppable interface)
@@ -3,8 +3,10 @@ package module
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"time"
 
@@ -29,6 +31,25 @@ type HTTPServerTLSConfig struct {
 	ClientAuth   string                 `yaml:"client_auth" json:"client_auth"` // require | request | none
 }
 
+// HTTPServerClientAuthConfig configures TLS client certificate (mTLS)
+// authentication for the HTTP server.
+//
+// Modes:
+//   - request: ask for a client certificate but accept connections without one.
+//   - require: reject connections that present no certificate.
+//   - verify:  require a certificate that chains to CAFile and is not on
+//     CRLFile; failures abort the TLS handshake.
+//
+// In request and require modes the certificate is not verified during the
+// handshake; pair them with http.middleware.clientcert to verify and authorize
+// certificates with descriptive 403 responses.
+type HTTPServerClientAuthConfig struct {
+	Mode           string        `yaml:"mode" json:"mode"`
+	CAFile         string        `yaml:"caFile" json:"caFile"`
+	CRLFile        string        `yaml:"crlFile" json:"crlFile"`
+	ReloadInterval time.Duration `yaml:"reloadInterval" json:"reloadInterval"`
+}
+
 // StandardHTTPServer implements the HTTPServer interface and modular.Module interfaces
 type StandardHTTPServer struct {
 	name             string
@@ -40,8 +61,14 @@ type StandardHTTPServer struct {
 	writeTimeout     time.Duration
 	idleTimeout      time.Duration
 	tlsCfg           HTTPServerTLSConfig
+	clientAuth       HTTPServerClientAuthConfig
+	bodyLimits       BodyLimits
+	inFlightLimits   InFlightLimits
+	inFlight         *InFlightLimiter
+	app              modular.Application
 	listenErr        chan error
 	acmeChallengeErr chan error
+	noListen         bool
 }
 
 // ListenError returns a channel that receives the first fatal error from
@@ -61,6 +88,13 @@ func (s *StandardHTTPServer) ACMEChallengeListenError() <-chan error {
 	return s.acmeChallengeErr
 }
 
+// DisableListening makes Start skip opening the listener. Workflow-worker
+// processes use it: they run the same config as the server, including its
+// HTTP modules, but only execute queued jobs.
+func (s *StandardHTTPServer) DisableListening() {
+	s.noListen = true
+}
+
 // NewStandardHTTPServer creates a new HTTP server with the given name and address
 func NewStandardHTTPServer(name, address string) *StandardHTTPServer {
 	return &StandardHTTPServer{
@@ -77,11 +111,42 @@ func (s *StandardHTTPServer) SetTimeouts(read, write, idle time.Duration) {
 	s.idleTimeout = idle
 }
 
+// SetBodyLimits sets the default request and response body limits of every
+// route. Routes can override them; see StandardHTTPRouter.SetRouteBodyLimits.
+func (s *StandardHTTPServer) SetBodyLimits(limits BodyLimits) {
+	s.bodyLimits = limits
+}
+
+// SetInFlightLimits limits the number of requests the server handles at
+// once across all routes. Routes can add their own limits; see
+// StandardHTTPRouter.SetRouteInFlightLimits.
+func (s *StandardHTTPServer) SetInFlightLimits(limits InFlightLimits) {
+	s.inFlightLimits = limits
+}
+
+// InFlightStats returns the stats of the server-wide in-flight limiter,
+// keyed "", and of the router's per-route limiters, keyed "METHOD path".
+func (s *StandardHTTPServer) InFlightStats() map[string]InFlightStats {
+	var routes map[string]InFlightStats
+	if sr, ok := s.router.(interface {
+		InFlightStats() map[string]InFlightStats
+	}); ok {
+		routes = sr.InFlightStats()
+	}
+	return mergeInFlightStats(s.inFlight, routes)
+}
+
 // SetTLSConfig configures TLS for the HTTP server.
 func (s *StandardHTTPServer) SetTLSConfig(cfg HTTPServerTLSConfig) {
 	s.tlsCfg = cfg
 }
 
+// SetClientAuthConfig configures mTLS client certificate authentication.
+// It only takes effect when TLS is enabled.
+func (s *StandardHTTPServer) SetClientAuthConfig(cfg HTTPServerClientAuthConfig) {
+	s.clientAuth = cfg
+}
+
 // Name returns the unique identifier for this module
 func (s *StandardHTTPServer) Name() string {
 	return s.name
@@ -90,6 +155,7 @@ func (s *StandardHTTPServer) Name() string {
 // Init initializes the module with the application context
 func (s *StandardHTTPServer) Init(app modular.Application) error {
 	s.logger = app.Logger()
+	s.app = app
 	// Get configuration if available
 	configSection, err := app.GetConfigSection("http")
 	if err == nil {
@@ -112,6 +178,12 @@ func (s *StandardHTTPServer) AddRouter(router HTTPRouter) {
 
 // Start starts the HTTP server
 func (s *StandardHTTPServer) Start(ctx context.Context) error {
+	if s.noListen {
+		if s.logger != nil {
+			s.logger.Info("HTTP server listening disabled", "name", s.name)
+		}
+		return nil
+	}
 	if s.router == nil {
 		return fmt.Errorf("no router configured for HTTP server")
 	}
@@ -122,6 +194,29 @@ func (s *StandardHTTPServer) Start(ctx context.Context) error {
 		return fmt.Errorf("router does not implement http.Handler")
 	}
 
+	// Routers that know their routes apply the limits per route; others
+	// get them applied to every request.
+	if !s.bodyLimits.IsZero() {
+		if lr, ok := s.router.(interface{ SetDefaultBodyLimits(BodyLimits) }); ok {
+			lr.SetDefaultBodyLimits(s.bodyLimits)
+		} else {
+			handler = BodyLimitHandler(handler, s.bodyLimits)
+		}
+	}
+
+	// The server-wide limiter sheds requests before any route work.
+	s.inFlight = NewInFlightLimiter(s.inFlightLimits)
+	handler = s.inFlight.Handler(handler)
+	s.registerInFlightMetrics()
+
+	// The router's CORS policies also cover the responses of the limits
+	// above, so browsers can read a 503 or 413 instead of a CORS failure.
+	if cr, ok := s.router.(interface {
+		CORSHandler(next http.Handler) http.Handler
+	}); ok {
+		handler = cr.CORSHandler(handler)
+	}
+
 	s.server = &http.Server{
 		Addr:              s.address,
 		Handler:           handler,
@@ -152,6 +247,22 @@ func (s *StandardHTTPServer) Start(ctx context.Context) error {
 	}
 }
 
+// registerInFlightMetrics exports the in-flight limiter stats through the
+// metrics collector, when one is registered. Routes given limits after the
+// server started are picked up on the next scrape.
+func (s *StandardHTTPServer) registerInFlightMetrics() {
+	if s.app == nil {
+		return
+	}
+	var metrics *MetricsCollector
+	if err := s.app.GetService("metrics.collector", &metrics); err != nil || metrics == nil {
+		return
+	}
+	if err := metrics.RegisterCollector(newInFlightMetrics(metrics.config.Namespace, metrics.config.Subsystem, s.name, s.InFlightStats)); err != nil {
+		s.logger.Warn("Failed to register in-flight metrics", "server", s.name, "error", err)
+	}
+}
+
 // startManualTLS starts the server with manually configured TLS certificates.
 func (s *StandardHTTPServer) startManualTLS(ctx context.Context) error {
 	manualCfg := s.tlsCfg.Manual
@@ -170,6 +281,9 @@ func (s *StandardHTTPServer) startManualTLS(ctx context.Context) error {
 		return fmt.Errorf("http server TLS config: %w", err)
 	}
 	s.server.TLSConfig = tlsConfig
+	if err := s.applyClientAuth(); err != nil {
+		return err
+	}
 
 	go func() {
 		defer close(s.listenErr)
@@ -202,6 +316,9 @@ func (s *StandardHTTPServer) startAutocert(ctx context.Context) error {
 		GetCertificate: m.GetCertificate,
 		MinVersion:     tls.VersionTLS12,
 	}
+	if err := s.applyClientAuth(); err != nil {
+		return err
+	}
 
 	// ACME HTTP-01 challenge listener on :80 — auxiliary, owns its own error channel.
 	s.acmeChallengeErr = make(chan error, 1)
@@ -229,6 +346,67 @@ func (s *StandardHTTPServer) startAutocert(ctx context.Context) error {
 	return nil
 }
 
+// applyClientAuth configures client certificate authentication on the
+// server's TLS config. In verify mode a connection only completes the
+// handshake with a trusted certificate, so its requests are marked verified
+// for clientcert middleware that has no CA bundle of its own.
+func (s *StandardHTTPServer) applyClientAuth() error {
+	if err := applyClientAuth(s.server.TLSConfig, s.clientAuth); err != nil {
+		return fmt.Errorf("http server client auth: %w", err)
+	}
+	if s.clientAuth.Mode == "verify" {
+		s.server.ConnContext = func(ctx context.Context, _ net.Conn) context.Context {
+			return withListenerVerifiedClientCert(ctx)
+		}
+	}
+	return nil
+}
+
+// applyClientAuth configures client certificate authentication on cfg.
+// In verify mode the chain is checked by the ClientCertVerifier rather than
+// crypto/tls so that CA bundle and CRL changes on disk are picked up without
+// restarting the listener.
+func applyClientAuth(cfg *tls.Config, ca HTTPServerClientAuthConfig) error {
+	switch ca.Mode {
+	case "":
+		return nil
+	case "request":
+		cfg.ClientAuth = tls.RequestClientCert
+		return nil
+	case "require":
+		cfg.ClientAuth = tls.RequireAnyClientCert
+		return nil
+	case "verify":
+	default:
+		return fmt.Errorf("unknown clientAuth mode %q (valid: request, require, verify)", ca.Mode)
+	}
+
+	verifier, err := NewClientCertVerifier(ca.CAFile, ca.CRLFile, ca.ReloadInterval)
+	if err != nil {
+		return err
+	}
+	cfg.ClientAuth = tls.RequireAnyClientCert
+	// ClientCAs only advertises acceptable issuers to clients; trust is
+	// enforced by VerifyPeerCertificate below.
+	cfg.ClientCAs = verifier.Pool()
+	cfg.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		certs := make([]*x509.Certificate, 0, len(rawCerts))
+		for _, raw := range rawCerts {
+			c, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return fmt.Errorf("parse client certificate: %w", err)
+			}
+			certs = append(certs, c)
+		}
+		if len(certs) == 0 {
+			return errors.New("client certificate required")
+		}
+		_, err := verifier.Verify(certs[0], certs[1:])
+		return err
+	}
+	return nil
+}
+
 // Stop stops the HTTP server
 func (s *StandardHTTPServer) Stop(ctx context.Context) error {
 	if s.server == nil {
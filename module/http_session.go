@@ -0,0 +1,749 @@
+package module
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoCodeAlone/modular"
+)
+
+// Session store kinds of the http.session module.
+const (
+	SessionStoreCookie = "cookie"
+	SessionStoreMemory = "memory"
+	SessionStoreSQLite = "sqlite"
+	SessionStoreCache  = "cache"
+)
+
+// maxSessionCookieSize is the largest cookie value browsers reliably keep.
+const maxSessionCookieSize = 4096
+
+// HTTPSessionConfig configures the http.session module.
+type HTTPSessionConfig struct {
+	// Store is where session data lives: "cookie" (default) keeps it in a
+	// signed and encrypted cookie; "memory", "sqlite" and "cache" keep it on
+	// the server and put only a random session ID in the cookie.
+	Store string
+	// Secret is the key material for cookie sessions.
+	Secret string
+	// DBPath is the database file of the sqlite store.
+	DBPath string
+	// Cache names the cache module (such as cache.redis) of the cache store.
+	Cache string
+
+	CookieName   string
+	CookiePath   string
+	CookieDomain string
+	Secure       bool
+	HTTPOnly     bool
+	SameSite     http.SameSite
+	// Persistent cookies carry an expiry; otherwise the browser drops the
+	// cookie when it closes.
+	Persistent bool
+
+	// IdleTimeout ends a session that has not been used for this long;
+	// AbsoluteTimeout ends it this long after it was created.
+	IdleTimeout     time.Duration
+	AbsoluteTimeout time.Duration
+	// Rolling renews the idle timeout on every request rather than only
+	// when the session changes.
+	Rolling bool
+	// CleanupInterval is how often server stores drop expired sessions.
+	CleanupInterval time.Duration
+}
+
+// ParseHTTPSessionConfig parses the http.session module config.
+func ParseHTTPSessionConfig(raw map[string]any) (HTTPSessionConfig, error) {
+	cfg := HTTPSessionConfig{
+		Store:           SessionStoreCookie,
+		DBPath:          "./data/sessions.db",
+		CookieName:      "wf_session",
+		CookiePath:      "/",
+		Secure:          true,
+		HTTPOnly:        true,
+		SameSite:        http.SameSiteLaxMode,
+		IdleTimeout:     30 * time.Minute,
+		AbsoluteTimeout: 24 * time.Hour,
+		Rolling:         true,
+		CleanupInterval: 10 * time.Minute,
+	}
+	if v, ok := raw["store"].(string); ok && v != "" {
+		cfg.Store = v
+	}
+	cfg.Secret, _ = raw["secret"].(string)
+	if v, ok := raw["dbPath"].(string); ok && v != "" {
+		cfg.DBPath = v
+	}
+	cfg.Cache, _ = raw["cache"].(string)
+	if v, ok := raw["rolling"].(bool); ok {
+		cfg.Rolling = v
+	}
+	for key, dst := range map[string]*time.Duration{
+		"idleTimeout":     &cfg.IdleTimeout,
+		"absoluteTimeout": &cfg.AbsoluteTimeout,
+		"cleanupInterval": &cfg.CleanupInterval,
+	} {
+		v, ok := raw[key].(string)
+		if !ok || v == "" {
+			continue
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return cfg, fmt.Errorf("%s: invalid duration %q", key, v)
+		}
+		*dst = d
+	}
+
+	if cookie, ok := raw["cookie"].(map[string]any); ok {
+		if v, ok := cookie["name"].(string); ok && v != "" {
+			cfg.CookieName = v
+		}
+		if v, ok := cookie["path"].(string); ok && v != "" {
+			cfg.CookiePath = v
+		}
+		cfg.CookieDomain, _ = cookie["domain"].(string)
+		if v, ok := cookie["secure"].(bool); ok {
+			cfg.Secure = v
+		}
+		if v, ok := cookie["httpOnly"].(bool); ok {
+			cfg.HTTPOnly = v
+		}
+		if v, ok := cookie["persistent"].(bool); ok {
+			cfg.Persistent = v
+		}
+		if v, ok := cookie["sameSite"].(string); ok && v != "" {
+			switch strings.ToLower(v) {
+			case "lax":
+				cfg.SameSite = http.SameSiteLaxMode
+			case "strict":
+				cfg.SameSite = http.SameSiteStrictMode
+			case "none":
+				cfg.SameSite = http.SameSiteNoneMode
+			default:
+				return cfg, fmt.Errorf("cookie.sameSite: must be lax, strict or none, got %q", v)
+			}
+		}
+	}
+
+	switch cfg.Store {
+	case SessionStoreCookie:
+		if len(cfg.Secret) < 32 {
+			return cfg, fmt.Errorf("secret: the cookie store needs a secret of at least 32 characters")
+		}
+	case SessionStoreMemory, SessionStoreSQLite:
+	case SessionStoreCache:
+		if cfg.Cache == "" {
+			return cfg, fmt.Errorf("cache: required for the cache store")
+		}
+	default:
+		return cfg, fmt.Errorf("store: must be cookie, memory, sqlite or cache, got %q", cfg.Store)
+	}
+	if cfg.SameSite == http.SameSiteNoneMode && !cfg.Secure {
+		return cfg, fmt.Errorf("cookie.sameSite none requires cookie.secure")
+	}
+	return cfg, nil
+}
+
+// Session is the session of one HTTP request, attached to the request
+// context by the http.session middleware. It is safe for concurrent use.
+type Session struct {
+	mu         sync.Mutex
+	id         string
+	values     map[string]any
+	changes    map[string]sessionChange
+	createdAt  time.Time
+	lastSeen   time.Time
+	isNew      bool
+	regenerate bool
+	destroyed  bool
+}
+
+// sessionChange records a write made during the request: a new value, or a
+// deletion.
+type sessionChange struct {
+	value   any
+	deleted bool
+}
+
+// Get returns the value stored under key.
+func (s *Session) Get(key string) (any, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.values[key]
+	return v, ok
+}
+
+// Values returns a copy of the session's values.
+func (s *Session) Values() map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.values)
+}
+
+// Set stores value under key. Values must be JSON-serializable.
+func (s *Session) Set(key string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = value
+	s.changes[key] = sessionChange{value: value}
+}
+
+// Delete removes key from the session.
+func (s *Session) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, key)
+	s.changes[key] = sessionChange{deleted: true}
+}
+
+// Destroy ends the session: its data is deleted and the cookie expired.
+// Values set afterwards start a new session.
+func (s *Session) Destroy() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values = make(map[string]any)
+	s.changes = make(map[string]sessionChange)
+	s.destroyed = true
+}
+
+// RegenerateID gives the session a new ID when the response is sent,
+// keeping its data. Call it when the user's privileges change, such as at
+// login, so an ID planted before the change is worthless afterwards.
+func (s *Session) RegenerateID() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.regenerate = true
+}
+
+// IsNew reports whether the request arrived without a valid session.
+func (s *Session) IsNew() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.isNew
+}
+
+// httpSessionContextKey keys a request's sessions by module name; the empty
+// name holds the innermost session.
+type httpSessionContextKey struct{ name string }
+
+// SessionFromContext returns the session attached by the named http.session
+// module, or by the innermost one when name is empty. It returns nil when no
+// session middleware handled the request.
+func SessionFromContext(ctx context.Context, name string) *Session {
+	s, _ := ctx.Value(httpSessionContextKey{name: name}).(*Session)
+	return s
+}
+
+// HTTPSessionModule is the http.session middleware. It loads the session of
+// each request, attaches it to the request context and saves it, setting the
+// session cookie, before the response headers are sent.
+//
+// Session IDs are random 256-bit values. They are only ever sent in the
+// cookie: server stores key sessions by the SHA-256 of the ID, and the ID is
+// never logged or exposed to pipeline steps.
+type HTTPSessionModule struct {
+	name      string
+	cfg       HTTPSessionConfig
+	configErr error
+	store     HTTPSessionStore
+	aead      cipher.AEAD
+	app       modular.Application
+	logger    modular.Logger
+	now       func() time.Time
+	// locks serialize the read-modify-write of concurrent requests on the
+	// same server-side session.
+	locks  [64]sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewHTTPSessionModule creates an http.session module from its raw config.
+// Config errors are reported by Init.
+func NewHTTPSessionModule(name string, raw map[string]any) *HTTPSessionModule {
+	cfg, err := ParseHTTPSessionConfig(raw)
+	return &HTTPSessionModule{
+		name:      name,
+		cfg:       cfg,
+		configErr: err,
+		logger:    &noopLogger{},
+		now:       time.Now,
+	}
+}
+
+// Name returns the module name.
+func (m *HTTPSessionModule) Name() string { return m.name }
+
+// Config returns the parsed module config.
+func (m *HTTPSessionModule) Config() HTTPSessionConfig { return m.cfg }
+
+// SetStore replaces the configured server-side store.
+func (m *HTTPSessionModule) SetStore(store HTTPSessionStore) { m.store = store }
+
+// Init validates the config and prepares the cookie cipher.
+func (m *HTTPSessionModule) Init(app modular.Application) error {
+	if m.configErr != nil {
+		return fmt.Errorf("http.session %q: %w", m.name, m.configErr)
+	}
+	m.app = app
+	if app != nil {
+		m.logger = app.Logger()
+	}
+	if m.cfg.Store == SessionStoreCookie {
+		key := sha256.Sum256([]byte("workflow-http-session:" + m.cfg.Secret))
+		block, err := aes.NewCipher(key[:])
+		if err != nil {
+			return fmt.Errorf("http.session %q: %w", m.name, err)
+		}
+		if m.aead, err = cipher.NewGCM(block); err != nil {
+			return fmt.Errorf("http.session %q: %w", m.name, err)
+		}
+	}
+	return nil
+}
+
+// Start opens the server-side store and starts pruning expired sessions.
+func (m *HTTPSessionModule) Start(ctx context.Context) error {
+	if m.store == nil {
+		switch m.cfg.Store {
+		case SessionStoreMemory:
+			m.store = newMemorySessionStore()
+		case SessionStoreSQLite:
+			store, err := openSQLiteSessionStore(m.cfg.DBPath, m.name)
+			if err != nil {
+				return fmt.Errorf("http.session %q: %w", m.name, err)
+			}
+			m.store = store
+		case SessionStoreCache:
+			if m.app == nil {
+				return fmt.Errorf("http.session %q: no application to resolve cache %q", m.name, m.cfg.Cache)
+			}
+			svc, ok := m.app.SvcRegistry()[m.cfg.Cache]
+			if !ok {
+				return fmt.Errorf("http.session %q: cache service %q not found", m.name, m.cfg.Cache)
+			}
+			cm, ok := svc.(CacheModule)
+			if !ok {
+				return fmt.Errorf("http.session %q: service %q does not implement CacheModule", m.name, m.cfg.Cache)
+			}
+			m.store = &cacheSessionStore{cache: cm, prefix: "session:" + m.name + ":"}
+		}
+	}
+	if p, ok := m.store.(sessionPruner); ok {
+		loopCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		m.cancel = cancel
+		m.done = make(chan struct{})
+		go m.pruneLoop(loopCtx, p)
+	}
+	return nil
+}
+
+// Stop stops pruning and closes the store.
+func (m *HTTPSessionModule) Stop(_ context.Context) error {
+	if m.cancel != nil {
+		m.cancel()
+		<-m.done
+		m.cancel = nil
+	}
+	if c, ok := m.store.(interface{ Close() error }); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// ProvidesServices registers the module as a middleware service.
+func (m *HTTPSessionModule) ProvidesServices() []modular.ServiceProvider {
+	return []modular.ServiceProvider{
+		{
+			Name:        m.name,
+			Description: "HTTP Session Middleware",
+			Instance:    m,
+		},
+	}
+}
+
+// RequiresServices declares the cache module of the cache store.
+func (m *HTTPSessionModule) RequiresServices() []modular.ServiceDependency {
+	if m.cfg.Store != SessionStoreCache {
+		return nil
+	}
+	return []modular.ServiceDependency{{Name: m.cfg.Cache, Required: true}}
+}
+
+func (m *HTTPSessionModule) pruneLoop(ctx context.Context, p sessionPruner) {
+	defer close(m.done)
+	ticker := time.NewTicker(m.cfg.CleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := p.Prune(ctx, m.now()); err != nil {
+				m.logger.Warn("http.session: pruning expired sessions failed", "module", m.name, "error", err)
+			}
+		}
+	}
+}
+
+// Process implements HTTPMiddleware.
+func (m *HTTPSessionModule) Process(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if SessionFromContext(r.Context(), m.name) != nil {
+			// Already applied as a global middleware of the router.
+			next.ServeHTTP(w, r)
+			return
+		}
+		s, hadCookie := m.load(r)
+		sw := &sessionResponseWriter{ResponseWriter: w, m: m, s: s, ctx: r.Context(), hadCookie: hadCookie}
+		ctx := context.WithValue(r.Context(), httpSessionContextKey{name: m.name}, s)
+		ctx = context.WithValue(ctx, httpSessionContextKey{}, s)
+		next.ServeHTTP(sw, r.WithContext(ctx))
+		sw.commit()
+	})
+}
+
+// load returns the request's session, or a new one when the request has no
+// valid, unexpired session. hadCookie reports whether a session cookie was
+// sent.
+func (m *HTTPSessionModule) load(r *http.Request) (*Session, bool) {
+	now := m.now()
+	c, err := r.Cookie(m.cfg.CookieName)
+	if err != nil || c.Value == "" {
+		return m.newSession(now), false
+	}
+	if m.cfg.Store == SessionStoreCookie {
+		payload, err := m.decodeCookie(c.Value)
+		if err != nil || m.expired(payload.CreatedAt, payload.LastSeen, now) {
+			return m.newSession(now), true
+		}
+		return &Session{
+			id:        payload.ID,
+			values:    payload.Values,
+			changes:   make(map[string]sessionChange),
+			createdAt: payload.CreatedAt,
+			lastSeen:  payload.LastSeen,
+		}, true
+	}
+
+	if !validSessionID(c.Value) {
+		return m.newSession(now), true
+	}
+	key := sessionKey(c.Value)
+	rec, err := m.store.Load(r.Context(), key)
+	if err != nil {
+		m.logger.Error("http.session: loading session failed", "module", m.name, "error", err)
+		return m.newSession(now), true
+	}
+	if rec == nil {
+		return m.newSession(now), true
+	}
+	if m.expired(rec.CreatedAt, rec.LastSeen, now) {
+		if err := m.store.Delete(r.Context(), key); err != nil {
+			m.logger.Warn("http.session: deleting expired session failed", "module", m.name, "error", err)
+		}
+		return m.newSession(now), true
+	}
+	if rec.Values == nil {
+		rec.Values = make(map[string]any)
+	}
+	return &Session{
+		id:        c.Value,
+		values:    rec.Values,
+		changes:   make(map[string]sessionChange),
+		createdAt: rec.CreatedAt,
+		lastSeen:  rec.LastSeen,
+	}, true
+}
+
+func (m *HTTPSessionModule) newSession(now time.Time) *Session {
+	return &Session{
+		values:    make(map[string]any),
+		changes:   make(map[string]sessionChange),
+		createdAt: now,
+		lastSeen:  now,
+		isNew:     true,
+	}
+}
+
+func (m *HTTPSessionModule) expired(created, lastSeen, now time.Time) bool {
+	return now.Sub(lastSeen) > m.cfg.IdleTimeout || now.Sub(created) > m.cfg.AbsoluteTimeout
+}
+
+// expiry returns when a session last used at lastSeen expires.
+func (m *HTTPSessionModule) expiry(created, lastSeen time.Time) time.Time {
+	idle := lastSeen.Add(m.cfg.IdleTimeout)
+	if abs := created.Add(m.cfg.AbsoluteTimeout); abs.Before(idle) {
+		return abs
+	}
+	return idle
+}
+
+// commit saves the session's changes. When headersSent is false it also
+// sets or expires the session cookie; otherwise a change that needs a new
+// cookie is logged and dropped.
+func (m *HTTPSessionModule) commit(ctx context.Context, w http.ResponseWriter, s *Session, hadCookie, headersSent bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := m.now()
+
+	if s.destroyed {
+		s.destroyed = false
+		if !s.isNew && m.cfg.Store != SessionStoreCookie {
+			if err := m.store.Delete(ctx, sessionKey(s.id)); err != nil {
+				m.logger.Error("http.session: deleting session failed", "module", m.name, "error", err)
+			}
+		}
+		if hadCookie || !s.isNew {
+			m.setCookie(w, "", time.Time{}, headersSent)
+		}
+		s.id, s.createdAt, s.lastSeen, s.isNew, s.regenerate = "", now, now, true, false
+		hadCookie = false
+	}
+
+	modified := len(s.changes) > 0 || s.regenerate
+	if s.isNew && !modified {
+		return
+	}
+	if !modified && !m.cfg.Rolling {
+		return
+	}
+	changes := s.changes
+	s.changes = make(map[string]sessionChange)
+	oldID, regenerate, isNew := s.id, s.regenerate || s.isNew, s.isNew
+	s.regenerate = false
+	s.isNew = false
+	if regenerate {
+		id, err := newSessionID()
+		if err != nil {
+			m.logger.Error("http.session: generating session ID failed", "module", m.name, "error", err)
+			return
+		}
+		s.id = id
+	}
+	s.lastSeen = now
+	expires := m.expiry(s.createdAt, now)
+
+	if m.cfg.Store == SessionStoreCookie {
+		value, err := m.encodeCookie(sessionCookiePayload{ID: s.id, Values: s.values, CreatedAt: s.createdAt, LastSeen: now})
+		if err != nil {
+			m.logger.Error("http.session: encoding session cookie failed", "module", m.name, "error", err)
+			return
+		}
+		m.setCookie(w, value, expires, headersSent)
+		return
+	}
+
+	// Server store: apply this request's changes to the stored record, so
+	// concurrent requests changing different keys do not overwrite each other.
+	oldKey := sessionKey(oldID)
+	lock := m.lock(oldKey)
+	if isNew {
+		lock = m.lock(sessionKey(s.id))
+	}
+	lock.Lock()
+	defer lock.Unlock()
+
+	var rec *HTTPSessionRecord
+	if !isNew {
+		var err error
+		if rec, err = m.store.Load(ctx, oldKey); err != nil {
+			m.logger.Error("http.session: loading session failed", "module", m.name, "error", err)
+			return
+		}
+	}
+	if rec == nil {
+		rec = &HTTPSessionRecord{Values: maps.Clone(s.values), CreatedAt: s.createdAt}
+	}
+	if rec.Values == nil {
+		rec.Values = make(map[string]any)
+	}
+	for k, c := range changes {
+		if c.deleted {
+			delete(rec.Values, k)
+		} else {
+			rec.Values[k] = c.value
+		}
+	}
+	rec.LastSeen = now
+	s.values = maps.Clone(rec.Values)
+
+	if err := m.store.Save(ctx, sessionKey(s.id), rec, expires.Sub(now)); err != nil {
+		m.logger.Error("http.session: saving session failed", "module", m.name, "error", err)
+		return
+	}
+	if regenerate && !isNew {
+		if err := m.store.Delete(ctx, oldKey); err != nil {
+			m.logger.Error("http.session: deleting replaced session failed", "module", m.name, "error", err)
+		}
+	}
+	if regenerate || (m.cfg.Persistent && m.cfg.Rolling) || !hadCookie {
+		m.setCookie(w, s.id, expires, headersSent)
+	}
+}
+
+// setCookie sets the session cookie, or expires it when value is empty.
+func (m *HTTPSessionModule) setCookie(w http.ResponseWriter, value string, expires time.Time, headersSent bool) {
+	if headersSent {
+		m.logger.Warn("http.session: session changed after the response headers were sent; the cookie was not updated", "module", m.name)
+		return
+	}
+	c := &http.Cookie{
+		Name:     m.cfg.CookieName,
+		Value:    value,
+		Path:     m.cfg.CookiePath,
+		Domain:   m.cfg.CookieDomain,
+		Secure:   m.cfg.Secure,
+		HttpOnly: m.cfg.HTTPOnly,
+		SameSite: m.cfg.SameSite,
+	}
+	switch {
+	case value == "":
+		c.MaxAge = -1
+	case m.cfg.Persistent:
+		c.Expires = expires.UTC()
+		c.MaxAge = int(time.Until(expires).Seconds())
+		if c.MaxAge <= 0 {
+			c.MaxAge = 1
+		}
+	}
+	http.SetCookie(w, c)
+}
+
+func (m *HTTPSessionModule) lock(key string) *sync.Mutex {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return &m.locks[h.Sum32()%uint32(len(m.locks))]
+}
+
+// sessionCookiePayload is the encrypted content of a cookie-store session.
+type sessionCookiePayload struct {
+	ID        string         `json:"id"`
+	Values    map[string]any `json:"values"`
+	CreatedAt time.Time      `json:"created_at"`
+	LastSeen  time.Time      `json:"last_seen"`
+}
+
+// encodeCookie encrypts the payload with AES-GCM, authenticating the cookie
+// name with it.
+func (m *HTTPSessionModule) encodeCookie(p sessionCookiePayload) (string, error) {
+	plain, err := json.Marshal(p)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, m.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := m.aead.Seal(nonce, nonce, plain, []byte(m.cfg.CookieName))
+	value := base64.RawURLEncoding.EncodeToString(sealed)
+	if len(value) > maxSessionCookieSize {
+		return "", fmt.Errorf("session is %d bytes, more than a cookie can hold (%d); use a server-side store", len(value), maxSessionCookieSize)
+	}
+	return value, nil
+}
+
+func (m *HTTPSessionModule) decodeCookie(value string) (sessionCookiePayload, error) {
+	var p sessionCookiePayload
+	sealed, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return p, err
+	}
+	if len(sealed) < m.aead.NonceSize() {
+		return p, errors.New("session cookie too short")
+	}
+	nonce, ct := sealed[:m.aead.NonceSize()], sealed[m.aead.NonceSize():]
+	plain, err := m.aead.Open(nil, nonce, ct, []byte(m.cfg.CookieName))
+	if err != nil {
+		return p, err
+	}
+	if err := json.Unmarshal(plain, &p); err != nil {
+		return p, err
+	}
+	if p.Values == nil {
+		p.Values = make(map[string]any)
+	}
+	return p, nil
+}
+
+// newSessionID returns a random 256-bit session ID.
+func newSessionID() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// validSessionID reports whether id has the shape of an ID from newSessionID.
+func validSessionID(id string) bool {
+	b, err := base64.RawURLEncoding.DecodeString(id)
+	return err == nil && len(b) == 32
+}
+
+// sessionKey is the store key of a session ID.
+func sessionKey(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])
+}
+
+// sessionResponseWriter commits the session before the response headers are
+// sent, so the session cookie can still be set.
+type sessionResponseWriter struct {
+	http.ResponseWriter
+	m           *HTTPSessionModule
+	s           *Session
+	ctx         context.Context
+	hadCookie   bool
+	headersSent bool
+}
+
+func (sw *sessionResponseWriter) WriteHeader(code int) {
+	if !sw.headersSent {
+		sw.m.commit(sw.ctx, sw.ResponseWriter, sw.s, sw.hadCookie, false)
+		sw.hadCookie = true
+		sw.headersSent = true
+	}
+	sw.ResponseWriter.WriteHeader(code)
+}
+
+func (sw *sessionResponseWriter) Write(b []byte) (int, error) {
+	if !sw.headersSent {
+		sw.WriteHeader(http.StatusOK)
+	}
+	return sw.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher when the underlying writer does.
+func (sw *sessionResponseWriter) Flush() {
+	if !sw.headersSent {
+		sw.WriteHeader(http.StatusOK)
+	}
+	if f, ok := sw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying writer for http.ResponseController.
+func (sw *sessionResponseWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
+
+// commit saves the session once the handler returns. Changes made after
+// the headers were sent are still saved to server stores.
+func (sw *sessionResponseWriter) commit() {
+	sw.m.commit(sw.ctx, sw.ResponseWriter, sw.s, sw.hadCookie, sw.headersSent)
+}
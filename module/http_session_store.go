@@ -0,0 +1,220 @@
+package module
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// HTTPSessionRecord is a session as kept by a server-side store.
+type HTTPSessionRecord struct {
+	Values    map[string]any `json:"values"`
+	CreatedAt time.Time      `json:"created_at"`
+	LastSeen  time.Time      `json:"last_seen"`
+}
+
+// HTTPSessionStore keeps server-side sessions. Keys are SHA-256 hashes of
+// session IDs, never the IDs themselves.
+type HTTPSessionStore interface {
+	// Load returns the session stored under key, or nil when there is none
+	// or it has expired.
+	Load(ctx context.Context, key string) (*HTTPSessionRecord, error)
+	// Save stores the session under key for ttl.
+	Save(ctx context.Context, key string, rec *HTTPSessionRecord, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+// sessionPruner is implemented by stores that need expired sessions removed
+// periodically.
+type sessionPruner interface {
+	Prune(ctx context.Context, now time.Time) error
+}
+
+// memorySessionStore keeps sessions in process memory. Sessions are lost on
+// restart and not shared between instances.
+type memorySessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]memorySession
+	now      func() time.Time
+}
+
+type memorySession struct {
+	data    []byte
+	expires time.Time
+}
+
+func newMemorySessionStore() *memorySessionStore {
+	return &memorySessionStore{sessions: make(map[string]memorySession), now: time.Now}
+}
+
+func (s *memorySessionStore) Load(_ context.Context, key string) (*HTTPSessionRecord, error) {
+	s.mu.Lock()
+	e, ok := s.sessions[key]
+	s.mu.Unlock()
+	if !ok || !s.now().Before(e.expires) {
+		return nil, nil
+	}
+	return decodeSessionRecord(e.data)
+}
+
+func (s *memorySessionStore) Save(_ context.Context, key string, rec *HTTPSessionRecord, ttl time.Duration) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("encode session: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[key] = memorySession{data: data, expires: s.now().Add(ttl)}
+	return nil
+}
+
+func (s *memorySessionStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, key)
+	return nil
+}
+
+func (s *memorySessionStore) Prune(_ context.Context, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, e := range s.sessions {
+		if !now.Before(e.expires) {
+			delete(s.sessions, key)
+		}
+	}
+	return nil
+}
+
+// sqliteSessionStore keeps sessions in a SQLite table, keyed by session
+// module and session key.
+type sqliteSessionStore struct {
+	db     *sql.DB
+	module string
+	now    func() time.Time
+}
+
+// openSQLiteSessionStore opens (or creates) the database at dbPath.
+func openSQLiteSessionStore(dbPath, module string) (*sqliteSessionStore, error) {
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0o750); err != nil {
+		return nil, fmt.Errorf("create data directory: %w", err)
+	}
+	db, err := sql.Open("sqlite", dbPath+"?_journal_mode=WAL&_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("open session database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	_, err = db.Exec(`
+CREATE TABLE IF NOT EXISTS http_sessions (
+	module     TEXT NOT NULL,
+	key        TEXT NOT NULL,
+	data       BLOB NOT NULL,
+	expires_at INTEGER NOT NULL,
+	PRIMARY KEY (module, key)
+)`)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("init session schema: %w", err)
+	}
+	return &sqliteSessionStore{db: db, module: module, now: time.Now}, nil
+}
+
+func (s *sqliteSessionStore) Load(ctx context.Context, key string) (*HTTPSessionRecord, error) {
+	var data []byte
+	err := s.db.QueryRowContext(ctx,
+		`SELECT data FROM http_sessions WHERE module = ? AND key = ? AND expires_at > ?`,
+		s.module, key, s.now().UnixNano()).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load session: %w", err)
+	}
+	return decodeSessionRecord(data)
+}
+
+func (s *sqliteSessionStore) Save(ctx context.Context, key string, rec *HTTPSessionRecord, ttl time.Duration) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("encode session: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx, `
+INSERT INTO http_sessions (module, key, data, expires_at) VALUES (?, ?, ?, ?)
+ON CONFLICT (module, key) DO UPDATE SET data = excluded.data, expires_at = excluded.expires_at`,
+		s.module, key, data, s.now().Add(ttl).UnixNano())
+	if err != nil {
+		return fmt.Errorf("save session: %w", err)
+	}
+	return nil
+}
+
+func (s *sqliteSessionStore) Delete(ctx context.Context, key string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM http_sessions WHERE module = ? AND key = ?`, s.module, key); err != nil {
+		return fmt.Errorf("delete session: %w", err)
+	}
+	return nil
+}
+
+func (s *sqliteSessionStore) Prune(ctx context.Context, now time.Time) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM http_sessions WHERE module = ? AND expires_at <= ?`, s.module, now.UnixNano()); err != nil {
+		return fmt.Errorf("prune sessions: %w", err)
+	}
+	return nil
+}
+
+// Close closes the database.
+func (s *sqliteSessionStore) Close() error {
+	return s.db.Close()
+}
+
+// cacheSessionStore keeps sessions in a cache module such as cache.redis,
+// which expires them itself.
+type cacheSessionStore struct {
+	cache  CacheModule
+	prefix string
+}
+
+func (s *cacheSessionStore) Load(ctx context.Context, key string) (*HTTPSessionRecord, error) {
+	data, err := s.cache.Get(ctx, s.prefix+key)
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load session: %w", err)
+	}
+	return decodeSessionRecord([]byte(data))
+}
+
+func (s *cacheSessionStore) Save(ctx context.Context, key string, rec *HTTPSessionRecord, ttl time.Duration) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("encode session: %w", err)
+	}
+	if err := s.cache.Set(ctx, s.prefix+key, string(data), ttl); err != nil {
+		return fmt.Errorf("save session: %w", err)
+	}
+	return nil
+}
+
+func (s *cacheSessionStore) Delete(ctx context.Context, key string) error {
+	if err := s.cache.Delete(ctx, s.prefix+key); err != nil {
+		return fmt.Errorf("delete session: %w", err)
+	}
+	return nil
+}
+
+func decodeSessionRecord(data []byte) (*HTTPSessionRecord, error) {
+	rec := &HTTPSessionRecord{}
+	if err := json.Unmarshal(data, rec); err != nil {
+		return nil, fmt.Errorf("decode session: %w", err)
+	}
+	return rec, nil
+}
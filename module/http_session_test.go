@@ -0,0 +1,328 @@
+package module
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSessionSecret = "0123456789abcdef0123456789abcdef"
+
+// sessionClock is a settable clock shared by a session module and its store.
+type sessionClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *sessionClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *sessionClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestSessionModule(t *testing.T, raw map[string]any) (*HTTPSessionModule, *sessionClock) {
+	t.Helper()
+	clock := &sessionClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	m := NewHTTPSessionModule("sessions", raw)
+	m.now = clock.Now
+	require.NoError(t, m.Init(nil))
+	switch m.cfg.Store {
+	case SessionStoreMemory:
+		store := newMemorySessionStore()
+		store.now = clock.Now
+		m.SetStore(store)
+	case SessionStoreSQLite:
+		store, err := openSQLiteSessionStore(filepath.Join(t.TempDir(), "sessions.db"), m.name)
+		require.NoError(t, err)
+		store.now = clock.Now
+		m.SetStore(store)
+	}
+	require.NoError(t, m.Start(context.Background()))
+	t.Cleanup(func() { _ = m.Stop(context.Background()) })
+	return m, clock
+}
+
+// sessionHandler serves /set?k=v, /get?k=, /regenerate and /destroy.
+func sessionHandler(m *HTTPSessionModule) http.Handler {
+	return m.Process(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s := SessionFromContext(r.Context(), "")
+		switch r.URL.Path {
+		case "/set":
+			for k, v := range r.URL.Query() {
+				s.Set(k, v[0])
+			}
+		case "/get":
+			if v, ok := s.Get(r.URL.Query().Get("k")); ok {
+				fmt.Fprint(w, v)
+			}
+		case "/regenerate":
+			s.RegenerateID()
+		case "/destroy":
+			s.Destroy()
+		}
+	}))
+}
+
+// sessionRequest sends a request with the given session cookie value and
+// returns the response and the session cookie it set, if any.
+func sessionRequest(h http.Handler, path, cookie string) (*httptest.ResponseRecorder, *http.Cookie) {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if cookie != "" {
+		req.AddCookie(&http.Cookie{Name: "wf_session", Value: cookie})
+	}
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	for _, c := range rr.Result().Cookies() {
+		if c.Name == "wf_session" {
+			return rr, c
+		}
+	}
+	return rr, nil
+}
+
+func TestParseHTTPSessionConfig(t *testing.T) {
+	cfg, err := ParseHTTPSessionConfig(map[string]any{
+		"store":       "sqlite",
+		"idleTimeout": "5m",
+		"cookie":      map[string]any{"name": "sid", "sameSite": "strict", "persistent": true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 5*time.Minute, cfg.IdleTimeout)
+	require.Equal(t, 24*time.Hour, cfg.AbsoluteTimeout)
+	require.Equal(t, "sid", cfg.CookieName)
+	require.Equal(t, http.SameSiteStrictMode, cfg.SameSite)
+	require.True(t, cfg.Persistent && cfg.Secure && cfg.HTTPOnly && cfg.Rolling)
+
+	for _, tc := range []struct {
+		raw  map[string]any
+		want string
+	}{
+		{map[string]any{}, "secret"},
+		{map[string]any{"store": "cache"}, "cache: required"},
+		{map[string]any{"store": "disk"}, "store: must be"},
+		{map[string]any{"store": "memory", "idleTimeout": "soon"}, "idleTimeout"},
+		{map[string]any{"store": "memory", "cookie": map[string]any{"sameSite": "none", "secure": false}}, "requires cookie.secure"},
+	} {
+		_, err := ParseHTTPSessionConfig(tc.raw)
+		require.ErrorContains(t, err, tc.want)
+	}
+	require.ErrorContains(t, NewHTTPSessionModule("s", map[string]any{}).Init(nil), `http.session "s"`)
+}
+
+func TestHTTPSession_CookieStore(t *testing.T) {
+	m, _ := newTestSessionModule(t, map[string]any{"secret": testSessionSecret})
+	h := sessionHandler(m)
+
+	// Reading an empty session creates no cookie.
+	_, c := sessionRequest(h, "/get?k=user", "")
+	require.Nil(t, c)
+
+	_, c = sessionRequest(h, "/set?user=alice", "")
+	require.NotNil(t, c)
+	require.True(t, c.HttpOnly)
+	require.True(t, c.Secure)
+	require.Equal(t, http.SameSiteLaxMode, c.SameSite)
+	require.NotContains(t, c.Value, "alice", "cookie sessions are encrypted")
+
+	rr, _ := sessionRequest(h, "/get?k=user", c.Value)
+	require.Equal(t, "alice", rr.Body.String())
+
+	// A tampered cookie, or one sealed with another secret, is a new session.
+	tampered := []byte(c.Value)
+	tampered[len(tampered)/2] ^= 1
+	rr, _ = sessionRequest(h, "/get?k=user", string(tampered))
+	require.Empty(t, rr.Body.String())
+	other, _ := newTestSessionModule(t, map[string]any{"secret": "another secret that is long enough"})
+	rr, _ = sessionRequest(sessionHandler(other), "/get?k=user", c.Value)
+	require.Empty(t, rr.Body.String())
+}
+
+func TestHTTPSession_Expiry(t *testing.T) {
+	for _, store := range []string{SessionStoreCookie, SessionStoreMemory, SessionStoreSQLite} {
+		t.Run(store, func(t *testing.T) {
+			raw := map[string]any{"store": store, "secret": testSessionSecret, "idleTimeout": "30m", "absoluteTimeout": "2h"}
+			m, clock := newTestSessionModule(t, raw)
+			h := sessionHandler(m)
+			_, c := sessionRequest(h, "/set?user=alice", "")
+			require.NotNil(t, c)
+			cookie := c.Value
+
+			// Rolling renewal: each request within the idle timeout extends it.
+			for range 3 {
+				clock.Advance(20 * time.Minute)
+				rr, renewed := sessionRequest(h, "/get?k=user", cookie)
+				require.Equal(t, "alice", rr.Body.String())
+				if renewed != nil {
+					cookie = renewed.Value
+				}
+			}
+
+			// Idle timeout.
+			clock.Advance(31 * time.Minute)
+			rr, _ := sessionRequest(h, "/get?k=user", cookie)
+			require.Empty(t, rr.Body.String())
+
+			// Absolute timeout, despite renewal.
+			_, c = sessionRequest(h, "/set?user=bob", "")
+			cookie = c.Value
+			for range 6 {
+				clock.Advance(25 * time.Minute)
+				_, renewed := sessionRequest(h, "/get?k=user", cookie)
+				if renewed != nil {
+					cookie = renewed.Value
+				}
+			}
+			rr, _ = sessionRequest(h, "/get?k=user", cookie)
+			require.Empty(t, rr.Body.String())
+		})
+	}
+}
+
+func TestHTTPSession_NoRolling(t *testing.T) {
+	m, clock := newTestSessionModule(t, map[string]any{"store": "memory", "idleTimeout": "30m", "rolling": false})
+	h := sessionHandler(m)
+	_, c := sessionRequest(h, "/set?user=alice", "")
+
+	clock.Advance(20 * time.Minute)
+	rr, _ := sessionRequest(h, "/get?k=user", c.Value)
+	require.Equal(t, "alice", rr.Body.String())
+	// Reads do not renew the session, so it expires 30m after the last write.
+	clock.Advance(20 * time.Minute)
+	rr, _ = sessionRequest(h, "/get?k=user", c.Value)
+	require.Empty(t, rr.Body.String())
+}
+
+func TestHTTPSession_RegenerateAndDestroy(t *testing.T) {
+	for _, store := range []string{SessionStoreCookie, SessionStoreMemory} {
+		t.Run(store, func(t *testing.T) {
+			m, _ := newTestSessionModule(t, map[string]any{"store": store, "secret": testSessionSecret})
+			h := sessionHandler(m)
+			_, c := sessionRequest(h, "/set?user=alice", "")
+			old := c.Value
+
+			_, c = sessionRequest(h, "/regenerate", old)
+			require.NotNil(t, c)
+			require.NotEqual(t, old, c.Value)
+			rr, _ := sessionRequest(h, "/get?k=user", c.Value)
+			require.Equal(t, "alice", rr.Body.String(), "regeneration keeps the data")
+			if store != SessionStoreCookie {
+				rr, _ = sessionRequest(h, "/get?k=user", old)
+				require.Empty(t, rr.Body.String(), "the old ID no longer works")
+			}
+
+			current := c.Value
+			_, c = sessionRequest(h, "/destroy", current)
+			require.NotNil(t, c)
+			require.Equal(t, -1, c.MaxAge)
+			if store != SessionStoreCookie {
+				rr, _ = sessionRequest(h, "/get?k=user", current)
+				require.Empty(t, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestHTTPSession_ConcurrentRequests(t *testing.T) {
+	for _, store := range []string{SessionStoreMemory, SessionStoreSQLite} {
+		t.Run(store, func(t *testing.T) {
+			m, _ := newTestSessionModule(t, map[string]any{"store": store})
+			h := sessionHandler(m)
+			_, c := sessionRequest(h, "/set?init=1", "")
+
+			// Requests changing different keys of one session must not lose
+			// each other's writes.
+			var wg sync.WaitGroup
+			for i := range 20 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					sessionRequest(h, fmt.Sprintf("/set?k%d=%d", i, i), c.Value)
+				}()
+			}
+			wg.Wait()
+
+			for i := range 20 {
+				rr, _ := sessionRequest(h, fmt.Sprintf("/get?k=k%d", i), c.Value)
+				require.Equal(t, fmt.Sprint(i), rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestHTTPSession_StoreKeysAreHashed(t *testing.T) {
+	m, _ := newTestSessionModule(t, map[string]any{"store": "memory"})
+	_, c := sessionRequest(sessionHandler(m), "/set?user=alice", "")
+	store := m.store.(*memorySessionStore)
+	require.Len(t, store.sessions, 1)
+	_, raw := store.sessions[c.Value]
+	require.False(t, raw, "the raw session ID must not be a store key")
+	_, hashed := store.sessions[sessionKey(c.Value)]
+	require.True(t, hashed)
+}
+
+func TestHTTPSession_PersistentCookie(t *testing.T) {
+	m, _ := newTestSessionModule(t, map[string]any{
+		"store":  "memory",
+		"cookie": map[string]any{"persistent": true, "domain": "example.com", "path": "/app"},
+	})
+	_, c := sessionRequest(sessionHandler(m), "/set?user=alice", "")
+	require.NotNil(t, c)
+	require.Positive(t, c.MaxAge)
+	require.Equal(t, "example.com", c.Domain)
+	require.Equal(t, "/app", c.Path)
+}
+
+func TestHTTPSession_JWTLoginRegeneratesID(t *testing.T) {
+	m, _ := newTestSessionModule(t, map[string]any{"store": "memory"})
+	j := setupJWTAuth(t)
+	registerUser(t, j, "fixation@example.com", "User", "mypassword")
+	h := m.Process(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/set" {
+			SessionFromContext(r.Context(), "").Set("cart", "3 items")
+			return
+		}
+		j.Handle(w, r)
+	}))
+
+	_, c := sessionRequest(h, "/set", "")
+	planted := c.Value
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(`{"email":"fixation@example.com","password":"mypassword"}`))
+	req.AddCookie(&http.Cookie{Name: "wf_session", Value: planted})
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	cookies := rr.Result().Cookies()
+	require.Len(t, cookies, 1)
+	require.NotEqual(t, planted, cookies[0].Value, "login must regenerate the session ID")
+
+	store := m.store.(*memorySessionStore)
+	_, stillThere := store.sessions[sessionKey(planted)]
+	require.False(t, stillThere)
+	_, moved := store.sessions[sessionKey(cookies[0].Value)]
+	require.True(t, moved)
+
+	// Logout ends the session.
+	req = httptest.NewRequest(http.MethodPost, "/auth/logout", nil)
+	req.AddCookie(cookies[0])
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	cookies = rr.Result().Cookies()
+	require.Len(t, cookies, 1)
+	require.Equal(t, -1, cookies[0].MaxAge)
+	require.Empty(t, store.sessions)
+}
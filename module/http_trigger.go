@@ -17,7 +17,7 @@ import (
 	"sync/atomic"
 
 	"github.com/GoCodeAlone/modular"
-	"github.com/GoCodeAlone/workflow/interfaces"
+	"github.com/GoCodeAlone/workflow/observability/tracing"
 )
 
 // httpRWContextKey is the unexported type for the HTTP response writer context key.
@@ -38,6 +38,13 @@ type httpReqContextKey struct{}
 // headers, path parameters, and the request body.
 var HTTPRequestContextKey = httpReqContextKey{}
 
+// routeLocaleKey is the unexported type for the route locale context key.
+type routeLocaleKey struct{}
+
+// routeLocaleContextKey carries the locale: of an HTTP trigger route to
+// Pipeline.Execute, overriding the request's Accept-Language.
+var routeLocaleContextKey = routeLocaleKey{}
+
 // pipelineResultKey is the unexported type for the pipeline result context key.
 type pipelineResultKey struct{}
 
@@ -249,6 +256,18 @@ type HTTPTriggerRoute struct {
 	Action         string         `json:"action" yaml:"action"`
 	Params         map[string]any `json:"params,omitempty" yaml:"params,omitempty"`
 	IncludeRawBody bool           `json:"include_raw_body,omitempty" yaml:"include_raw_body,omitempty"`
+	// Locale forces the locale of the route's executions instead of
+	// negotiating it from Accept-Language. Only used with a locales: section.
+	Locale string `json:"locale,omitempty" yaml:"locale,omitempty"`
+	// BodyLimits override the server's body limits for this route.
+	BodyLimits `yaml:",inline"`
+	// InFlightLimits limit concurrent requests to this route.
+	InFlightLimits `yaml:",inline"`
+	// CORS is the route's own CORS policy, if any.
+	CORS *CORSMiddlewareConfig `json:"-" yaml:"-"`
+	// Placement says whether the route's pipeline runs locally or on
+	// remote workers.
+	Placement ExecutionPlacement `json:"-" yaml:"-"`
 }
 
 // HTTPTrigger implements a trigger that starts workflows from HTTP requests
@@ -313,6 +332,29 @@ func (t *HTTPTrigger) Start(ctx context.Context) error {
 
 	// Register all routes with the router
 	for _, route := range t.routes {
+		if !route.BodyLimits.IsZero() {
+			if lr, ok := t.router.(interface {
+				SetRouteBodyLimits(method, path string, limits BodyLimits)
+			}); ok {
+				lr.SetRouteBodyLimits(route.Method, route.Path, route.BodyLimits)
+			}
+		}
+		if !route.InFlightLimits.IsZero() {
+			if lr, ok := t.router.(interface {
+				SetRouteInFlightLimits(method, path string, limits InFlightLimits)
+			}); ok {
+				lr.SetRouteInFlightLimits(route.Method, route.Path, route.InFlightLimits)
+			}
+		}
+		if route.CORS != nil {
+			cr, ok := t.router.(interface {
+				SetRouteCORSPolicy(method, path string, policy CORSMiddlewareConfig)
+			})
+			if !ok {
+				return fmt.Errorf("route %s %s: router does not support per-route cors", route.Method, route.Path)
+			}
+			cr.SetRouteCORSPolicy(route.Method, route.Path, *route.CORS)
+		}
 		t.router.AddRoute(route.Method, route.Path, t.createHandler(route))
 	}
 
@@ -413,10 +455,22 @@ func (t *HTTPTrigger) Configure(app modular.Application, triggerConfig any) erro
 
 		// Get optional params
 		params, _ := routeMap["params"].(map[string]any)
+		locale, _ := routeMap["locale"].(string)
 		includeRawBody := boolConfigValue(routeMap["include_raw_body"])
 		if _, ok := routeMap["include_raw_body"]; !ok {
 			includeRawBody = boolConfigValue(routeMap["raw_body"])
 		}
+		cors, err := CORSPolicyFromConfig(routeMap["cors"], method)
+		if err != nil {
+			return fmt.Errorf("route %s %s: %w", method, path, err)
+		}
+		placement, err := ExecutionPlacementFromConfig(routeMap)
+		if err != nil {
+			return fmt.Errorf("route %s %s: %w", method, path, err)
+		}
+		if placement.OnWorker() && !strings.HasPrefix(workflow, "pipeline:") {
+			return fmt.Errorf("route %s %s: execution: %s is only supported for pipeline triggers", method, path, ExecutionWorker)
+		}
 
 		// Add the route
 		t.routes = append(t.routes, HTTPTriggerRoute{
@@ -426,12 +480,26 @@ func (t *HTTPTrigger) Configure(app modular.Application, triggerConfig any) erro
 			Action:         action,
 			Params:         params,
 			IncludeRawBody: includeRawBody,
+			Locale:         locale,
+			BodyLimits:     BodyLimitsFromConfig(routeMap),
+			InFlightLimits: InFlightLimitsFromConfig(routeMap),
+			CORS:           cors,
+			Placement:      placement,
 		})
 	}
 
 	return nil
 }
 
+// problemDetailLevel returns the engine's problem detail level, or
+// ProblemDetailFull when the engine does not configure one.
+func (t *HTTPTrigger) problemDetailLevel() ProblemDetailLevel {
+	if p, ok := t.engine.(ProblemDetailProvider); ok {
+		return p.ProblemDetailLevel()
+	}
+	return ProblemDetailFull
+}
+
 func boolConfigValue(v any) bool {
 	switch b := v.(type) {
 	case bool:
@@ -467,6 +535,16 @@ func (t *HTTPTrigger) createHandler(route HTTPTriggerRoute) HTTPHandler {
 		// to headers (e.g. Authorization), method, URL, and body.
 		ctx = context.WithValue(ctx, HTTPRequestContextKey, r)
 
+		if route.Locale != "" {
+			ctx = context.WithValue(ctx, routeLocaleContextKey, route.Locale)
+		}
+
+		// Carry a tenant resolved for the request as OTEL baggage so it
+		// reaches step logs and outbound calls.
+		if tenant := TenantFromContext(ctx); !tenant.IsZero() {
+			ctx = tracing.WithBaggage(ctx, map[string]string{tracing.BaggageTenantID: tenant.ID})
+		}
+
 		// Inject a result holder so the engine can pass the pipeline's result.Current
 		// back to this handler without changing the WorkflowEngine interface.
 		resultHolder := &PipelineResultHolder{}
@@ -494,7 +572,10 @@ func (t *HTTPTrigger) createHandler(route HTTPTriggerRoute) HTTPHandler {
 
 		// Parse JSON request body if present
 		if r.Body != nil {
-			bodyBytes, _ := io.ReadAll(r.Body)
+			bodyBytes, readErr := io.ReadAll(r.Body)
+			if handleRequestTooLarge(w, readErr) {
+				return
+			}
 			if len(bodyBytes) > 0 {
 				var body map[string]any
 				if err := json.Unmarshal(bodyBytes, &body); err == nil {
@@ -512,19 +593,21 @@ func (t *HTTPTrigger) createHandler(route HTTPTriggerRoute) HTTPHandler {
 		// Add any static params from the route configuration
 		maps.Copy(data, route.Params)
 
+		// Routes placed on workers are queued rather than run here.
+		if route.Placement.OnWorker() {
+			serveEnqueued(w, r, t.engine, route, data, t.problemDetailLevel())
+			return
+		}
+
 		// Call the workflow engine to trigger the workflow
 		err := t.engine.TriggerWorkflow(ctx, route.Workflow, route.Action, data)
 		if err != nil {
-			if interfaces.IsValidationError(err) {
-				status := interfaces.ValidationErrorStatus(err)
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(status)
-				if encErr := json.NewEncoder(w).Encode(map[string]any{"error": err.Error()}); encErr != nil {
-					log.Printf("http trigger: failed to write validation error response: %v", encErr)
-				}
+			// A step (e.g. a compensation json_response) may already have
+			// answered the request.
+			if rw.written.Load() {
 				return
 			}
-			http.Error(w, fmt.Sprintf("Error triggering workflow: %v", err), http.StatusInternalServerError)
+			writeErrorProblem(w, r, err, t.problemDetailLevel())
 			return
 		}
 
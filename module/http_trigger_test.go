@@ -3,6 +3,7 @@ package module
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -473,6 +474,40 @@ func TestHTTPTrigger_ResponseWriterInContext(t *testing.T) {
 	}
 }
 
+// TestHTTPTrigger_RouteLocaleInContext verifies that a route's locale is
+// passed to the pipeline to override Accept-Language.
+func TestHTTPTrigger_RouteLocaleInContext(t *testing.T) {
+	app := NewMockApplication()
+	router := NewMockHTTPRouter("test-router")
+	_ = app.RegisterService("httpRouter", router)
+
+	var capturedCtx context.Context
+	_ = app.RegisterService("workflowEngine", &captureContextEngine{capture: &capturedCtx})
+
+	trigger := NewHTTPTrigger()
+	app.RegisterModule(trigger)
+
+	cfg := map[string]any{
+		"routes": []any{
+			map[string]any{"path": "/de", "method": "GET", "workflow": "test-wf", "action": "execute", "locale": "de"},
+			map[string]any{"path": "/any", "method": "GET", "workflow": "test-wf", "action": "execute"},
+		},
+	}
+	if err := trigger.Configure(app, cfg); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	if err := trigger.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	for path, want := range map[string]any{"/de": "de", "/any": nil} {
+		router.routes["GET "+path].Handle(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+		if got := capturedCtx.Value(routeLocaleContextKey); got != want {
+			t.Errorf("%s: route locale = %v, want %v", path, got, want)
+		}
+	}
+}
+
 // captureContextEngine captures the context passed to TriggerWorkflow for inspection.
 type captureContextEngine struct {
 	capture *context.Context
@@ -839,7 +874,7 @@ func setupErrorEngineRoute(t *testing.T, eng WorkflowEngine) HTTPHandler {
 
 // TestHTTPHandler_ValidationError_Returns400 verifies that when a workflow step
 // returns a ValidationError with status 400, the HTTP trigger responds with 400
-// and a JSON body containing the error message, not 500.
+// and a validation_failed problem containing the error message, not 500.
 func TestHTTPHandler_ValidationError_Returns400(t *testing.T) {
 	valErr := interfaces.NewValidationError("invalid move: card doesn't match", 400)
 	eng := &errorEngine{err: valErr}
@@ -853,15 +888,15 @@ func TestHTTPHandler_ValidationError_Returns400(t *testing.T) {
 	if resp.StatusCode != 400 {
 		t.Errorf("expected 400, got %d", resp.StatusCode)
 	}
-	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
-		t.Errorf("expected Content-Type application/json, got %q", ct)
+	if ct := w.Header().Get("Content-Type"); ct != ProblemContentType {
+		t.Errorf("expected Content-Type %s, got %q", ProblemContentType, ct)
 	}
-	var body map[string]any
+	var body Problem
 	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
 		t.Fatalf("failed to decode response body: %v", err)
 	}
-	if body["error"] != "invalid move: card doesn't match" {
-		t.Errorf("unexpected error message: %v", body["error"])
+	if body.Code != "validation_failed" || body.Detail != "invalid move: card doesn't match" || body.Instance != "/api/validate" {
+		t.Errorf("unexpected problem: %+v", body)
 	}
 }
 
@@ -895,3 +930,49 @@ func TestHTTPHandler_InfraError_Returns500(t *testing.T) {
 		t.Errorf("expected 500, got %d", w.Result().StatusCode)
 	}
 }
+
+// minimalErrorEngine is an errorEngine configured with engine.errors.detail
+// set to minimal.
+type minimalErrorEngine struct{ errorEngine }
+
+func (*minimalErrorEngine) ProblemDetailLevel() ProblemDetailLevel { return ProblemDetailMinimal }
+
+// TestHTTPHandler_StepError_Problem verifies that a failed step is reported
+// as a pipeline_error problem naming the step and execution, and that the
+// minimal detail level hides the step and the message.
+func TestHTTPHandler_StepError_Problem(t *testing.T) {
+	stepErr := fmt.Errorf("workflow execution failed: %w", &PipelineStepError{
+		Pipeline: "orders", Step: "charge", ExecutionID: "exec-1",
+		Err: errors.New(`step "charge" failed: card declined`),
+	})
+
+	for _, tc := range []struct {
+		name       string
+		eng        WorkflowEngine
+		wantStep   string
+		wantDetail bool
+	}{
+		{"full", &errorEngine{err: stepErr}, "charge", true},
+		{"minimal", &minimalErrorEngine{errorEngine{err: stepErr}}, "", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := setupErrorEngineRoute(t, tc.eng)
+			w := httptest.NewRecorder()
+			handler.Handle(w, httptest.NewRequest("POST", "/api/validate", nil))
+
+			var body Problem
+			if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if w.Code != 500 || body.Code != "pipeline_error" || body.Type != ProblemTypeBaseURI+"pipeline_error" {
+				t.Errorf("got %d %+v", w.Code, body)
+			}
+			if body.ExecutionID != "exec-1" || body.Step != tc.wantStep {
+				t.Errorf("execution_id = %q, step = %q; want exec-1, %q", body.ExecutionID, body.Step, tc.wantStep)
+			}
+			if got := strings.Contains(body.Detail, "card declined"); got != tc.wantDetail {
+				t.Errorf("detail = %q, want message included: %v", body.Detail, tc.wantDetail)
+			}
+		})
+	}
+}
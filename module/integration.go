@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/GoCodeAlone/modular"
@@ -44,6 +45,22 @@ func isPrivateIP(ip net.IP) bool {
 	return false
 }
 
+// publicDialControl is a net.Dialer Control function that refuses connections
+// to private, loopback, link-local and unspecified addresses. It sees the
+// address actually dialed, after DNS resolution, so a host that passed
+// validation cannot later be re-pointed at an internal service.
+func publicDialControl(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsUnspecified() || isPrivateIP(ip) {
+		return fmt.Errorf("connection to private/internal IP address %s is not allowed", host)
+	}
+	return nil
+}
+
 // validateURLHost checks that the constructed request URL targets the same host
 // as the configured base URL. This prevents host injection via path manipulation
 // (e.g. a crafted path that tries to redirect the request to a different server).
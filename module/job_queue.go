@@ -0,0 +1,910 @@
+package module
+
+import (
+	"container/heap"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "modernc.org/sqlite"
+)
+
+// Job states. A job is pending until a worker claims it, running while the
+// worker runs its pipeline, and then succeeded, or pending again for a retry,
+// or failed once it exhausted its attempts.
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+)
+
+// QueuedJob is a unit of deferred work: a target pipeline and the trigger
+// data to run it with, and the state of its runs.
+type QueuedJob struct {
+	ID         string         `json:"id"`
+	Pipeline   string         `json:"pipeline"`
+	Data       map[string]any `json:"data,omitempty"`
+	Priority   int            `json:"priority"` // higher runs first
+	RunAt      time.Time      `json:"run_at"`
+	Attempts   int            `json:"attempts"` // incremented on each claim
+	EnqueuedAt time.Time      `json:"enqueued_at"`
+	LastError  string         `json:"last_error,omitempty"`
+	Status     string         `json:"status"`
+	// Result is the output of a succeeded run.
+	Result     map[string]any `json:"result,omitempty"`
+	StartedAt  *time.Time     `json:"started_at,omitempty"` // of the latest attempt
+	FinishedAt *time.Time     `json:"finished_at,omitempty"`
+	// IdempotencyKey, when set, is unique in the queue: enqueueing a second
+	// job with the same key fails with ErrDuplicateJob.
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+	// Labels a worker must have to claim the job with ClaimLease.
+	Labels []string `json:"labels,omitempty"`
+	// MaxAttempts bounds the attempts of a leased job; zero retries forever.
+	MaxAttempts int `json:"max_attempts,omitempty"`
+	// WorkerID and LeaseExpiresAt identify the worker holding a leased job
+	// and when its lease lapses.
+	WorkerID       string     `json:"worker_id,omitempty"`
+	LeaseExpiresAt *time.Time `json:"lease_expires_at,omitempty"`
+}
+
+// JobQueue stores jobs until a worker claims them. Ready jobs (RunAt <= now)
+// are claimed highest priority first, then oldest RunAt first. A claimed job
+// stays in the queue until it is completed, rescheduled, or failed, so a
+// crash mid-run redelivers it (at-least-once). Finished jobs are kept with
+// their outcome, so clients can poll them, until they are pruned.
+//
+// Jobs are stored in a table rather than published to a messaging.broker:
+// no broker persists messages (the in-process broker loses them on exit),
+// and none supports delayed delivery, priorities, re-claiming a crashed
+// worker's job, or status lookups.
+type JobQueue interface {
+	// Enqueue adds a job.
+	Enqueue(ctx context.Context, job *QueuedJob) error
+	// Claim returns the next ready job and marks it running, or nil when no
+	// job is ready.
+	Claim(ctx context.Context, now time.Time) (*QueuedJob, error)
+	// Complete marks a claimed job succeeded with the result of its run.
+	Complete(ctx context.Context, id string, result map[string]any) error
+	// Reschedule returns a claimed job to the queue to run again at runAt.
+	Reschedule(ctx context.Context, id string, runAt time.Time, lastErr string) error
+	// Fail parks a job that exhausted its attempts; it is never claimed again.
+	Fail(ctx context.Context, id string, lastErr string) error
+	// Recover returns jobs left running by a previous process to the queue.
+	Recover(ctx context.Context) (int, error)
+	// Get returns a job in any state, or nil when the queue has no job id.
+	Get(ctx context.Context, id string) (*QueuedJob, error)
+	// Prune removes succeeded and failed jobs that finished before before.
+	Prune(ctx context.Context, before time.Time) (int, error)
+	// Stats reports the number of jobs in each state.
+	Stats(ctx context.Context) (JobQueueStats, error)
+}
+
+// JobQueueStats is a snapshot of job counts by state.
+type JobQueueStats struct {
+	Pending   int `json:"pending"`
+	Running   int `json:"running"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	// OldestPending is when the longest-waiting pending job was enqueued.
+	OldestPending     *time.Time     `json:"oldest_pending,omitempty"`
+	PendingByPipeline map[string]int `json:"pending_by_pipeline,omitempty"`
+	// RunningByWorker counts the leased jobs of each worker.
+	RunningByWorker map[string]int `json:"running_by_worker,omitempty"`
+}
+
+func (s *JobQueueStats) addPending(pipeline string, n int, enqueuedAt time.Time) {
+	s.Pending += n
+	if s.PendingByPipeline == nil {
+		s.PendingByPipeline = make(map[string]int)
+	}
+	s.PendingByPipeline[pipeline] += n
+	if s.OldestPending == nil || enqueuedAt.Before(*s.OldestPending) {
+		s.OldestPending = &enqueuedAt
+	}
+}
+
+// ---------------------------------------------------------------------------
+// In-memory queue
+// ---------------------------------------------------------------------------
+
+// InMemoryJobQueue is a non-durable JobQueue for development and tests. Jobs
+// are lost when the process exits.
+type InMemoryJobQueue struct {
+	mu      sync.Mutex
+	pending jobHeap
+	running map[string]*QueuedJob
+	done    map[string]*QueuedJob // succeeded and failed
+	jobs    map[string]*QueuedJob // every job, by ID
+}
+
+// NewInMemoryJobQueue creates an empty in-memory job queue.
+func NewInMemoryJobQueue() *InMemoryJobQueue {
+	return &InMemoryJobQueue{
+		running: make(map[string]*QueuedJob),
+		done:    make(map[string]*QueuedJob),
+		jobs:    make(map[string]*QueuedJob),
+	}
+}
+
+// Enqueue implements JobQueue.
+func (q *InMemoryJobQueue) Enqueue(_ context.Context, job *QueuedJob) error {
+	cp := *job
+	cp.Status = JobStatusPending
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	heap.Push(&q.pending, &cp)
+	q.jobs[cp.ID] = &cp
+	return nil
+}
+
+// Claim implements JobQueue. The heap is ordered by priority first, so a
+// delayed high-priority job at the top would hide ready lower-priority jobs;
+// Claim therefore pops past not-yet-ready jobs and pushes them back.
+func (q *InMemoryJobQueue) Claim(_ context.Context, now time.Time) (*QueuedJob, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var deferred []*QueuedJob
+	defer func() {
+		for _, j := range deferred {
+			heap.Push(&q.pending, j)
+		}
+	}()
+	for q.pending.Len() > 0 {
+		job := heap.Pop(&q.pending).(*QueuedJob)
+		if job.RunAt.After(now) {
+			deferred = append(deferred, job)
+			continue
+		}
+		job.Attempts++
+		job.Status = JobStatusRunning
+		started := now
+		job.StartedAt = &started
+		q.running[job.ID] = job
+		cp := *job
+		return &cp, nil
+	}
+	return nil, nil
+}
+
+// Complete implements JobQueue.
+func (q *InMemoryJobQueue) Complete(_ context.Context, id string, result map[string]any) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, ok := q.running[id]
+	if !ok {
+		return fmt.Errorf("job %q is not running", id)
+	}
+	delete(q.running, id)
+	finished := time.Now()
+	job.Status = JobStatusSucceeded
+	job.Result = result
+	job.FinishedAt = &finished
+	q.done[id] = job
+	return nil
+}
+
+// Reschedule implements JobQueue.
+func (q *InMemoryJobQueue) Reschedule(_ context.Context, id string, runAt time.Time, lastErr string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, ok := q.running[id]
+	if !ok {
+		return fmt.Errorf("job %q is not running", id)
+	}
+	delete(q.running, id)
+	job.Status = JobStatusPending
+	job.RunAt = runAt
+	job.LastError = lastErr
+	heap.Push(&q.pending, job)
+	return nil
+}
+
+// Fail implements JobQueue.
+func (q *InMemoryJobQueue) Fail(_ context.Context, id string, lastErr string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, ok := q.running[id]
+	if !ok {
+		return fmt.Errorf("job %q is not running", id)
+	}
+	delete(q.running, id)
+	finished := time.Now()
+	job.Status = JobStatusFailed
+	job.LastError = lastErr
+	job.FinishedAt = &finished
+	q.done[id] = job
+	return nil
+}
+
+// Recover implements JobQueue. Nothing survives a restart in memory.
+func (q *InMemoryJobQueue) Recover(_ context.Context) (int, error) { return 0, nil }
+
+// Get implements JobQueue.
+func (q *InMemoryJobQueue) Get(_ context.Context, id string) (*QueuedJob, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, ok := q.jobs[id]
+	if !ok {
+		return nil, nil
+	}
+	cp := *job
+	return &cp, nil
+}
+
+// Prune implements JobQueue.
+func (q *InMemoryJobQueue) Prune(_ context.Context, before time.Time) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := 0
+	for id, job := range q.done {
+		if job.FinishedAt.Before(before) {
+			delete(q.done, id)
+			delete(q.jobs, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// Stats implements JobQueue.
+func (q *InMemoryJobQueue) Stats(_ context.Context) (JobQueueStats, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	stats := JobQueueStats{Running: len(q.running)}
+	for _, job := range q.pending {
+		stats.addPending(job.Pipeline, 1, job.EnqueuedAt)
+	}
+	for _, job := range q.done {
+		if job.Status == JobStatusSucceeded {
+			stats.Succeeded++
+		} else {
+			stats.Failed++
+		}
+	}
+	return stats, nil
+}
+
+// jobHeap orders jobs by priority (desc), then RunAt (asc).
+type jobHeap []*QueuedJob
+
+func (h jobHeap) Len() int { return len(h) }
+func (h jobHeap) Less(i, j int) bool {
+	if h[i].Priority != h[j].Priority {
+		return h[i].Priority > h[j].Priority
+	}
+	return h[i].RunAt.Before(h[j].RunAt)
+}
+func (h jobHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *jobHeap) Push(x any)   { *h = append(*h, x.(*QueuedJob)) }
+func (h *jobHeap) Pop() any {
+	old := *h
+	n := len(old)
+	job := old[n-1]
+	*h = old[:n-1]
+	return job
+}
+
+// ---------------------------------------------------------------------------
+// SQL queue
+// ---------------------------------------------------------------------------
+
+// JobQueueDialect selects the SQL flavor of a SQLJobQueue.
+type JobQueueDialect string
+
+const (
+	JobQueueSQLite   JobQueueDialect = "sqlite"
+	JobQueuePostgres JobQueueDialect = "postgres"
+)
+
+var (
+	// ErrDuplicateJob is returned by SQLJobQueue.Enqueue when a job with the
+	// same IdempotencyKey is already in the queue.
+	ErrDuplicateJob = errors.New("job with this idempotency key is already queued")
+	// ErrJobNotFound is returned for a job ID the queue does not have.
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobLeaseLost is returned when a worker reports the outcome of a job
+	// it no longer holds, because its lease expired and the job went back to
+	// the queue. The outcome is discarded; the job's next holder reports its
+	// own.
+	ErrJobLeaseLost = errors.New("job lease lost")
+)
+
+// leaseClaimBatch is how many ready jobs ClaimLease considers at once when
+// looking for one whose labels the worker has.
+const leaseClaimBatch = 50
+
+// SQLJobQueue is a durable JobQueue backed by a job_queue table in SQLite or
+// PostgreSQL. Several queues can share one database; rows are partitioned by
+// queue name.
+//
+// Besides the JobQueue methods, for a process that runs its own jobs, it
+// leases jobs to workers in other processes (ClaimLease). A lease names its
+// worker and lapses unless renewed, and outcomes are only accepted from the
+// worker holding the lease, so a worker whose lease expired cannot overwrite
+// the outcome of the job's next attempt.
+type SQLJobQueue struct {
+	db      *sql.DB
+	dialect JobQueueDialect
+	queue   string
+	owned   bool // close db on Close
+}
+
+// NewSQLJobQueue creates a job queue named queue on an existing database
+// and ensures the schema exists.
+func NewSQLJobQueue(db *sql.DB, dialect JobQueueDialect, queue string) (*SQLJobQueue, error) {
+	q := &SQLJobQueue{db: db, dialect: dialect, queue: queue}
+	if err := q.initSchema(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// OpenJobQueue opens the named queue at dsn: a postgres:// or postgresql://
+// URL, or the path of a SQLite database, which is created if missing.
+func OpenJobQueue(dsn, queue string) (*SQLJobQueue, error) {
+	dialect, driver := JobQueueSQLite, "sqlite"
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		dialect, driver = JobQueuePostgres, "pgx"
+	} else {
+		if err := os.MkdirAll(filepath.Dir(dsn), 0o750); err != nil {
+			return nil, fmt.Errorf("create data directory: %w", err)
+		}
+		dsn += "?_journal_mode=WAL&_busy_timeout=5000"
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open job queue database: %w", err)
+	}
+	if dialect == JobQueueSQLite {
+		db.SetMaxOpenConns(1)
+	}
+	q, err := NewSQLJobQueue(db, dialect, queue)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	q.owned = true
+	return q, nil
+}
+
+// DB returns the queue's database.
+func (q *SQLJobQueue) DB() *sql.DB { return q.db }
+
+// Dialect returns the SQL flavor of the queue's database.
+func (q *SQLJobQueue) Dialect() JobQueueDialect { return q.dialect }
+
+// Close closes the database if the queue opened it.
+func (q *SQLJobQueue) Close() error {
+	if q.owned {
+		return q.db.Close()
+	}
+	return nil
+}
+
+func (q *SQLJobQueue) initSchema() error {
+	if _, err := q.db.Exec(`
+CREATE TABLE IF NOT EXISTS job_queue (
+	id               TEXT PRIMARY KEY,
+	queue            TEXT NOT NULL,
+	pipeline         TEXT NOT NULL,
+	data             TEXT NOT NULL,
+	priority         INTEGER NOT NULL DEFAULT 0,
+	run_at           BIGINT NOT NULL,
+	status           TEXT NOT NULL,
+	attempts         INTEGER NOT NULL DEFAULT 0,
+	last_error       TEXT NOT NULL DEFAULT '',
+	enqueued_at      BIGINT NOT NULL,
+	result           TEXT NOT NULL DEFAULT '',
+	started_at       BIGINT NOT NULL DEFAULT 0,
+	finished_at      BIGINT NOT NULL DEFAULT 0,
+	idempotency_key  TEXT,
+	labels           TEXT NOT NULL DEFAULT '[]',
+	max_attempts     INTEGER NOT NULL DEFAULT 0,
+	worker_id        TEXT NOT NULL DEFAULT '',
+	lease_expires_at BIGINT NOT NULL DEFAULT 0
+)`); err != nil {
+		return fmt.Errorf("init job queue schema: %w", err)
+	}
+	// Migration: queues created before job status tracking lack the result
+	// and timing columns, and queues created before leases the lease
+	// columns. SQLite has no ADD COLUMN IF NOT EXISTS, so adding an existing
+	// column fails there and is ignored.
+	addColumn := "ALTER TABLE job_queue ADD COLUMN "
+	if q.dialect == JobQueuePostgres {
+		addColumn += "IF NOT EXISTS "
+	}
+	for _, column := range []string{
+		"result TEXT NOT NULL DEFAULT ''",
+		"started_at BIGINT NOT NULL DEFAULT 0",
+		"finished_at BIGINT NOT NULL DEFAULT 0",
+		"idempotency_key TEXT",
+		"labels TEXT NOT NULL DEFAULT '[]'",
+		"max_attempts INTEGER NOT NULL DEFAULT 0",
+		"worker_id TEXT NOT NULL DEFAULT ''",
+		"lease_expires_at BIGINT NOT NULL DEFAULT 0",
+	} {
+		if _, err := q.db.Exec(addColumn + column); err != nil && q.dialect == JobQueuePostgres {
+			return fmt.Errorf("migrate job queue schema: %w", err)
+		}
+	}
+	for _, stmt := range []string{
+		`CREATE INDEX IF NOT EXISTS idx_job_queue_ready ON job_queue (queue, status, priority DESC, run_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_job_queue_finished ON job_queue (queue, finished_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_job_queue_worker ON job_queue (queue, worker_id, status)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_job_queue_idempotency_key ON job_queue (queue, idempotency_key)`,
+	} {
+		if _, err := q.db.Exec(stmt); err != nil {
+			return fmt.Errorf("init job queue schema: %w", err)
+		}
+	}
+	return nil
+}
+
+// rebind rewrites ? placeholders to PostgreSQL's $n.
+func (q *SQLJobQueue) rebind(query string) string {
+	if q.dialect != JobQueuePostgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func (q *SQLJobQueue) exec(ctx context.Context, query string, args ...any) (int64, error) {
+	res, err := q.db.ExecContext(ctx, q.rebind(query), args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// Enqueue implements JobQueue. A job whose IdempotencyKey is already in the
+// queue is not added; the error wraps ErrDuplicateJob.
+func (q *SQLJobQueue) Enqueue(ctx context.Context, job *QueuedJob) error {
+	data, err := json.Marshal(job.Data)
+	if err != nil {
+		return fmt.Errorf("encode job data: %w", err)
+	}
+	labels := "[]"
+	if len(job.Labels) > 0 {
+		encoded, err := json.Marshal(job.Labels)
+		if err != nil {
+			return fmt.Errorf("encode job labels: %w", err)
+		}
+		labels = string(encoded)
+	}
+	var key any
+	if job.IdempotencyKey != "" {
+		key = job.IdempotencyKey
+	}
+	_, err = q.exec(ctx,
+		`INSERT INTO job_queue (id, queue, pipeline, data, priority, run_at, status, attempts, enqueued_at,
+		                        idempotency_key, labels, max_attempts)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		job.ID, q.queue, job.Pipeline, string(data), job.Priority, job.RunAt.UnixNano(),
+		JobStatusPending, job.Attempts, job.EnqueuedAt.UnixNano(), key, labels, job.MaxAttempts)
+	if err != nil {
+		if job.IdempotencyKey != "" {
+			if existing, getErr := q.GetByIdempotencyKey(ctx, job.IdempotencyKey); getErr == nil && existing != nil {
+				return fmt.Errorf("%w: %s", ErrDuplicateJob, job.IdempotencyKey)
+			}
+		}
+		return fmt.Errorf("enqueue job: %w", err)
+	}
+	return nil
+}
+
+// Claim implements JobQueue. The select-and-mark is a single statement, and
+// re-checks the status it selected on, so concurrent workers never claim the
+// same job.
+func (q *SQLJobQueue) Claim(ctx context.Context, now time.Time) (*QueuedJob, error) {
+	lock := ""
+	if q.dialect == JobQueuePostgres {
+		lock = " FOR UPDATE SKIP LOCKED"
+	}
+	row := q.db.QueryRowContext(ctx, q.rebind(`
+UPDATE job_queue SET status = ?, attempts = attempts + 1, started_at = ?
+WHERE status = ? AND id = (
+	SELECT id FROM job_queue
+	WHERE queue = ? AND status = ? AND run_at <= ?
+	ORDER BY priority DESC, run_at ASC
+	LIMIT 1`+lock+`
+)
+RETURNING `+sqlJobColumns),
+		JobStatusRunning, now.UnixNano(), JobStatusPending, q.queue, JobStatusPending, now.UnixNano())
+
+	job, err := scanSQLJob(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("claim job: %w", err)
+	}
+	return job, nil
+}
+
+// ClaimLease claims the next ready job whose Labels are all in labels for
+// workerID, leased until now plus lease. The worker keeps the job by
+// renewing its leases (RenewLeases) and reports the outcome with
+// CompleteLease or FailLease; ExpireLeases returns the job to the queue if
+// the lease lapses.
+func (q *SQLJobQueue) ClaimLease(ctx context.Context, now time.Time, workerID string, labels []string, lease time.Duration) (*QueuedJob, error) {
+	rows, err := q.db.QueryContext(ctx, q.rebind(`
+SELECT id, labels FROM job_queue
+WHERE queue = ? AND status = ? AND run_at <= ?
+ORDER BY priority DESC, run_at ASC
+LIMIT ?`), q.queue, JobStatusPending, now.UnixNano(), leaseClaimBatch)
+	if err != nil {
+		return nil, fmt.Errorf("claim job: %w", err)
+	}
+	var candidates []string
+	for rows.Next() {
+		var id, rawLabels string
+		if err := rows.Scan(&id, &rawLabels); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("claim job: %w", err)
+		}
+		var required []string
+		_ = json.Unmarshal([]byte(rawLabels), &required)
+		if hasAllLabels(labels, required) {
+			candidates = append(candidates, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("claim job: %w", err)
+	}
+
+	for _, id := range candidates {
+		row := q.db.QueryRowContext(ctx, q.rebind(`
+UPDATE job_queue SET status = ?, attempts = attempts + 1, started_at = ?, worker_id = ?, lease_expires_at = ?
+WHERE queue = ? AND id = ? AND status = ?
+RETURNING `+sqlJobColumns),
+			JobStatusRunning, now.UnixNano(), workerID, now.Add(lease).UnixNano(), q.queue, id, JobStatusPending)
+		job, err := scanSQLJob(row)
+		if err == sql.ErrNoRows {
+			continue // another worker claimed it first
+		}
+		if err != nil {
+			return nil, fmt.Errorf("claim job %q: %w", id, err)
+		}
+		return job, nil
+	}
+	return nil, nil
+}
+
+// hasAllLabels reports whether labels include every label in required.
+func hasAllLabels(labels, required []string) bool {
+	for _, l := range required {
+		if !slices.Contains(labels, l) {
+			return false
+		}
+	}
+	return true
+}
+
+// RenewLeases extends the leases of the jobs workerID holds to until.
+func (q *SQLJobQueue) RenewLeases(ctx context.Context, workerID string, until time.Time) error {
+	_, err := q.exec(ctx,
+		`UPDATE job_queue SET lease_expires_at = ? WHERE queue = ? AND worker_id = ? AND status = ? AND lease_expires_at > 0`,
+		until.UnixNano(), q.queue, workerID, JobStatusRunning)
+	if err != nil {
+		return fmt.Errorf("renew leases of worker %q: %w", workerID, err)
+	}
+	return nil
+}
+
+// ExpireLeases returns jobs whose lease lapsed before now to the queue, or
+// fails them when they used up MaxAttempts. It returns the number of
+// expired leases.
+func (q *SQLJobQueue) ExpireLeases(ctx context.Context, now time.Time) (int, error) {
+	n, err := q.exec(ctx, `
+UPDATE job_queue SET
+	status = CASE WHEN max_attempts > 0 AND attempts >= max_attempts THEN ? ELSE ? END,
+	finished_at = CASE WHEN max_attempts > 0 AND attempts >= max_attempts THEN ? ELSE 0 END,
+	last_error = 'lease expired on worker ' || worker_id, lease_expires_at = 0
+WHERE queue = ? AND status = ? AND lease_expires_at > 0 AND lease_expires_at < ?`,
+		JobStatusFailed, JobStatusPending, now.UnixNano(), q.queue, JobStatusRunning, now.UnixNano())
+	if err != nil {
+		return 0, fmt.Errorf("expire job leases: %w", err)
+	}
+	return int(n), nil
+}
+
+// CompleteLease marks a job workerID holds succeeded with the result of its
+// run. It returns an error wrapping ErrJobLeaseLost when the worker no longer
+// holds the job.
+func (q *SQLJobQueue) CompleteLease(ctx context.Context, id, workerID string, result map[string]any) error {
+	encoded, err := encodeJobResult(id, result)
+	if err != nil {
+		return err
+	}
+	n, err := q.exec(ctx, `
+UPDATE job_queue SET status = ?, result = ?, finished_at = ?, lease_expires_at = 0
+WHERE queue = ? AND id = ? AND worker_id = ? AND status = ?`,
+		JobStatusSucceeded, encoded, time.Now().UnixNano(), q.queue, id, workerID, JobStatusRunning)
+	if err != nil {
+		return fmt.Errorf("complete job %q: %w", id, err)
+	}
+	return q.checkLease(ctx, id, n)
+}
+
+// FailLease records a failed attempt of a job workerID holds. The job goes
+// back to the queue while it has attempts left (always, when MaxAttempts is
+// zero) and fails otherwise. It returns an error wrapping ErrJobLeaseLost
+// when the worker no longer holds the job.
+func (q *SQLJobQueue) FailLease(ctx context.Context, id, workerID, lastErr string) error {
+	n, err := q.exec(ctx, `
+UPDATE job_queue SET
+	status = CASE WHEN max_attempts > 0 AND attempts >= max_attempts THEN ? ELSE ? END,
+	finished_at = CASE WHEN max_attempts > 0 AND attempts >= max_attempts THEN ? ELSE 0 END,
+	last_error = ?, lease_expires_at = 0
+WHERE queue = ? AND id = ? AND worker_id = ? AND status = ?`,
+		JobStatusFailed, JobStatusPending, time.Now().UnixNano(), lastErr, q.queue, id, workerID, JobStatusRunning)
+	if err != nil {
+		return fmt.Errorf("fail job %q: %w", id, err)
+	}
+	return q.checkLease(ctx, id, n)
+}
+
+// checkLease turns a lease outcome update that matched no row into
+// ErrJobNotFound or ErrJobLeaseLost.
+func (q *SQLJobQueue) checkLease(ctx context.Context, id string, updated int64) error {
+	if updated == 1 {
+		return nil
+	}
+	job, err := q.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if job == nil {
+		return fmt.Errorf("%w: %s", ErrJobNotFound, id)
+	}
+	return fmt.Errorf("%w: %s", ErrJobLeaseLost, id)
+}
+
+// sqlJobColumns are the job_queue columns scanSQLJob reads.
+const sqlJobColumns = `id, pipeline, data, priority, run_at, attempts, last_error, enqueued_at, status, result, started_at, finished_at,
+	COALESCE(idempotency_key, ''), labels, max_attempts, worker_id, lease_expires_at`
+
+func scanSQLJob(row interface{ Scan(...any) error }) (*QueuedJob, error) {
+	var (
+		job                                      QueuedJob
+		data, result, labels                     string
+		runAt, enqueuedAt, startedAt, finishedAt int64
+		leaseExpiresAt                           int64
+	)
+	err := row.Scan(&job.ID, &job.Pipeline, &data, &job.Priority, &runAt, &job.Attempts, &job.LastError, &enqueuedAt,
+		&job.Status, &result, &startedAt, &finishedAt,
+		&job.IdempotencyKey, &labels, &job.MaxAttempts, &job.WorkerID, &leaseExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(data), &job.Data); err != nil {
+		return nil, fmt.Errorf("decode job %q data: %w", job.ID, err)
+	}
+	if result != "" {
+		if err := json.Unmarshal([]byte(result), &job.Result); err != nil {
+			return nil, fmt.Errorf("decode job %q result: %w", job.ID, err)
+		}
+	}
+	_ = json.Unmarshal([]byte(labels), &job.Labels)
+	job.RunAt = time.Unix(0, runAt).UTC()
+	job.EnqueuedAt = time.Unix(0, enqueuedAt).UTC()
+	if startedAt != 0 {
+		t := time.Unix(0, startedAt).UTC()
+		job.StartedAt = &t
+	}
+	if finishedAt != 0 {
+		t := time.Unix(0, finishedAt).UTC()
+		job.FinishedAt = &t
+	}
+	if leaseExpiresAt != 0 {
+		t := time.Unix(0, leaseExpiresAt).UTC()
+		job.LeaseExpiresAt = &t
+	}
+	return &job, nil
+}
+
+func encodeJobResult(id string, result map[string]any) (string, error) {
+	if result == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("encode job %q result: %w", id, err)
+	}
+	return string(data), nil
+}
+
+// Complete implements JobQueue.
+func (q *SQLJobQueue) Complete(ctx context.Context, id string, result map[string]any) error {
+	encoded, err := encodeJobResult(id, result)
+	if err != nil {
+		return err
+	}
+	_, err = q.exec(ctx,
+		`UPDATE job_queue SET status = ?, result = ?, finished_at = ? WHERE queue = ? AND id = ?`,
+		JobStatusSucceeded, encoded, time.Now().UnixNano(), q.queue, id)
+	if err != nil {
+		return fmt.Errorf("complete job %q: %w", id, err)
+	}
+	return nil
+}
+
+// Reschedule implements JobQueue.
+func (q *SQLJobQueue) Reschedule(ctx context.Context, id string, runAt time.Time, lastErr string) error {
+	_, err := q.exec(ctx,
+		`UPDATE job_queue SET status = ?, run_at = ?, last_error = ? WHERE queue = ? AND id = ?`,
+		JobStatusPending, runAt.UnixNano(), lastErr, q.queue, id)
+	if err != nil {
+		return fmt.Errorf("reschedule job %q: %w", id, err)
+	}
+	return nil
+}
+
+// Fail implements JobQueue.
+func (q *SQLJobQueue) Fail(ctx context.Context, id string, lastErr string) error {
+	_, err := q.exec(ctx,
+		`UPDATE job_queue SET status = ?, last_error = ?, finished_at = ? WHERE queue = ? AND id = ?`,
+		JobStatusFailed, lastErr, time.Now().UnixNano(), q.queue, id)
+	if err != nil {
+		return fmt.Errorf("fail job %q: %w", id, err)
+	}
+	return nil
+}
+
+// Recover implements JobQueue. Leased jobs are left to ExpireLeases, since
+// their workers may still be running them.
+func (q *SQLJobQueue) Recover(ctx context.Context) (int, error) {
+	n, err := q.exec(ctx,
+		`UPDATE job_queue SET status = ? WHERE queue = ? AND status = ? AND lease_expires_at = 0`,
+		JobStatusPending, q.queue, JobStatusRunning)
+	if err != nil {
+		return 0, fmt.Errorf("recover jobs: %w", err)
+	}
+	return int(n), nil
+}
+
+// Get implements JobQueue.
+func (q *SQLJobQueue) Get(ctx context.Context, id string) (*QueuedJob, error) {
+	row := q.db.QueryRowContext(ctx, q.rebind(`SELECT `+sqlJobColumns+` FROM job_queue WHERE queue = ? AND id = ?`), q.queue, id)
+	job, err := scanSQLJob(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get job %q: %w", id, err)
+	}
+	return job, nil
+}
+
+// GetByIdempotencyKey returns the job enqueued with key, or nil when the
+// queue has none.
+func (q *SQLJobQueue) GetByIdempotencyKey(ctx context.Context, key string) (*QueuedJob, error) {
+	row := q.db.QueryRowContext(ctx, q.rebind(`SELECT `+sqlJobColumns+` FROM job_queue WHERE queue = ? AND idempotency_key = ?`), q.queue, key)
+	job, err := scanSQLJob(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get job by idempotency key %q: %w", key, err)
+	}
+	return job, nil
+}
+
+// JobQueueFilter selects jobs for SQLJobQueue.List. Empty fields match
+// every job.
+type JobQueueFilter struct {
+	Status   string
+	Pipeline string
+	WorkerID string
+	Limit    int // defaults to 100
+}
+
+// List returns the jobs matching filter, most recently enqueued first.
+func (q *SQLJobQueue) List(ctx context.Context, filter JobQueueFilter) ([]*QueuedJob, error) {
+	conds := []string{"queue = ?"}
+	args := []any{q.queue}
+	if filter.Status != "" {
+		conds = append(conds, "status = ?")
+		args = append(args, filter.Status)
+	}
+	if filter.Pipeline != "" {
+		conds = append(conds, "pipeline = ?")
+		args = append(args, filter.Pipeline)
+	}
+	if filter.WorkerID != "" {
+		conds = append(conds, "worker_id = ?")
+		args = append(args, filter.WorkerID)
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	args = append(args, limit)
+	rows, err := q.db.QueryContext(ctx, q.rebind(`SELECT `+sqlJobColumns+` FROM job_queue WHERE `+
+		strings.Join(conds, " AND ")+` ORDER BY enqueued_at DESC, id LIMIT ?`), args...)
+	if err != nil {
+		return nil, fmt.Errorf("list jobs: %w", err)
+	}
+	defer rows.Close()
+	var jobs []*QueuedJob
+	for rows.Next() {
+		job, err := scanSQLJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("list jobs: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+// Prune implements JobQueue.
+func (q *SQLJobQueue) Prune(ctx context.Context, before time.Time) (int, error) {
+	n, err := q.exec(ctx,
+		`DELETE FROM job_queue WHERE queue = ? AND status IN (?, ?) AND finished_at < ?`,
+		q.queue, JobStatusSucceeded, JobStatusFailed, before.UnixNano())
+	if err != nil {
+		return 0, fmt.Errorf("prune jobs: %w", err)
+	}
+	return int(n), nil
+}
+
+// Stats implements JobQueue.
+func (q *SQLJobQueue) Stats(ctx context.Context) (JobQueueStats, error) {
+	rows, err := q.db.QueryContext(ctx, q.rebind(`
+SELECT status, pipeline, worker_id, COUNT(*), MIN(enqueued_at) FROM job_queue
+WHERE queue = ? GROUP BY status, pipeline, worker_id`), q.queue)
+	if err != nil {
+		return JobQueueStats{}, fmt.Errorf("job queue stats: %w", err)
+	}
+	defer rows.Close()
+	var stats JobQueueStats
+	for rows.Next() {
+		var (
+			status, pipeline, workerID string
+			n                          int
+			oldest                     int64
+		)
+		if err := rows.Scan(&status, &pipeline, &workerID, &n, &oldest); err != nil {
+			return JobQueueStats{}, fmt.Errorf("job queue stats: %w", err)
+		}
+		switch status {
+		case JobStatusPending:
+			stats.addPending(pipeline, n, time.Unix(0, oldest).UTC())
+		case JobStatusRunning:
+			stats.Running += n
+			if workerID != "" {
+				if stats.RunningByWorker == nil {
+					stats.RunningByWorker = make(map[string]int)
+				}
+				stats.RunningByWorker[workerID] += n
+			}
+		case JobStatusSucceeded:
+			stats.Succeeded += n
+		case JobStatusFailed:
+			stats.Failed += n
+		}
+	}
+	return stats, rows.Err()
+}
@@ -0,0 +1,428 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoCodeAlone/modular"
+	"github.com/google/uuid"
+)
+
+// JobQueueConfig holds the configuration for the jobqueue.service module.
+type JobQueueConfig struct {
+	// Backend is "sqlite" (durable, default) or "memory".
+	Backend string `yaml:"backend" default:"sqlite"`
+	// Storage names a storage.sqlite module whose database holds the queue.
+	// When empty the sqlite backend opens its own database at DBPath.
+	Storage string `yaml:"storage"`
+	// DBPath is the standalone database path for the sqlite backend.
+	DBPath string `yaml:"dbPath" default:"./data/job-queue.db"`
+	// Workers is the number of jobs run concurrently.
+	Workers int `yaml:"workers" default:"4"`
+	// PollInterval is how often idle workers check for delayed jobs.
+	PollInterval time.Duration `yaml:"pollInterval" default:"1s"`
+	// MaxAttempts is how many times a job runs before it is parked as failed.
+	MaxAttempts int `yaml:"maxAttempts" default:"3"`
+	// RetryBackoff is the delay before the first retry; it doubles per attempt.
+	RetryBackoff time.Duration `yaml:"retryBackoff" default:"5s"`
+	// JobTimeout bounds a single job run.
+	JobTimeout time.Duration `yaml:"jobTimeout" default:"5m"`
+	// Retention is how long succeeded and failed jobs are kept for status
+	// lookups before they are pruned.
+	Retention time.Duration `yaml:"retention" default:"24h"`
+	// StatusPath, when set, serves job status at GET {StatusPath}/{id}.
+	StatusPath string `yaml:"statusPath"`
+	// Router names the http.router the status endpoint is added to; the
+	// first router when empty.
+	Router string `yaml:"router"`
+}
+
+// jobPruneInterval is how often finished jobs past their retention are
+// removed.
+const jobPruneInterval = 10 * time.Minute
+
+// JobQueueService is a background job queue with a worker pool. step.enqueue
+// adds jobs; workers claim ready jobs (highest priority first) and run the
+// target pipeline through the workflow engine. Failed runs are retried with
+// exponential backoff up to MaxAttempts, then parked as failed. Finished
+// jobs keep their status and result for Retention so clients can poll them.
+//
+// With the sqlite backend, jobs survive restarts: jobs that were running when
+// the process stopped are returned to the queue on Start, so a job may run
+// more than once and target pipelines should be idempotent.
+type JobQueueService struct {
+	name   string
+	config JobQueueConfig
+	queue  JobQueue
+	engine WorkflowEngine
+	app    modular.Application
+	logger modular.Logger
+
+	wake   chan struct{}
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+	now    func() time.Time
+}
+
+// NewJobQueueService creates a job queue module. Zero config values take the
+// documented defaults.
+func NewJobQueueService(name string, cfg JobQueueConfig) *JobQueueService {
+	if cfg.Backend == "" {
+		cfg.Backend = "sqlite"
+	}
+	if cfg.DBPath == "" {
+		cfg.DBPath = "./data/job-queue.db"
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = 4
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 3
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = 5 * time.Second
+	}
+	if cfg.JobTimeout <= 0 {
+		cfg.JobTimeout = 5 * time.Minute
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = 24 * time.Hour
+	}
+	if cfg.StatusPath != "" {
+		cfg.StatusPath = "/" + strings.Trim(cfg.StatusPath, "/")
+	}
+	return &JobQueueService{
+		name:   name,
+		config: cfg,
+		logger: &noopLogger{},
+		wake:   make(chan struct{}, 1),
+		stopCh: make(chan struct{}),
+		now:    time.Now,
+	}
+}
+
+// Name implements modular.Module.
+func (s *JobQueueService) Name() string { return s.name }
+
+// Init implements modular.Module.
+func (s *JobQueueService) Init(app modular.Application) error {
+	s.app = app
+	s.logger = app.Logger()
+	switch s.config.Backend {
+	case "sqlite", "memory":
+	default:
+		return fmt.Errorf("jobqueue.service %q: unknown backend %q (expected sqlite or memory)", s.name, s.config.Backend)
+	}
+	return nil
+}
+
+// SetQueue overrides the backing queue. It must be called before Start.
+func (s *JobQueueService) SetQueue(q JobQueue) { s.queue = q }
+
+// Queue returns the backing queue.
+func (s *JobQueueService) Queue() JobQueue { return s.queue }
+
+// StatusPath returns the path prefix job status is served under, or "" when
+// the status endpoint is disabled.
+func (s *JobQueueService) StatusPath() string { return s.config.StatusPath }
+
+// RouterName returns the http.router the status endpoint is added to, or ""
+// for the first router.
+func (s *JobQueueService) RouterName() string { return s.config.Router }
+
+// Job returns the job with the given ID, or nil when the queue has no such
+// job or it was pruned.
+func (s *JobQueueService) Job(ctx context.Context, id string) (*QueuedJob, error) {
+	if s.queue == nil {
+		return nil, fmt.Errorf("jobqueue.service %q: not started", s.name)
+	}
+	return s.queue.Get(ctx, id)
+}
+
+// Start opens the queue, recovers interrupted jobs, and starts the workers.
+func (s *JobQueueService) Start(ctx context.Context) error {
+	if s.queue == nil {
+		q, err := s.openQueue()
+		if err != nil {
+			return fmt.Errorf("jobqueue.service %q: %w", s.name, err)
+		}
+		s.queue = q
+	}
+
+	if s.engine == nil && s.app != nil {
+		for _, name := range []string{"workflowEngine", "engine"} {
+			if e, ok := s.app.SvcRegistry()[name].(WorkflowEngine); ok {
+				s.engine = e
+				break
+			}
+		}
+	}
+	if s.engine == nil {
+		return fmt.Errorf("jobqueue.service %q: workflow engine not found", s.name)
+	}
+
+	n, err := s.queue.Recover(ctx)
+	if err != nil {
+		return fmt.Errorf("jobqueue.service %q: %w", s.name, err)
+	}
+	if n > 0 {
+		s.logger.Warn("Requeued jobs interrupted by previous shutdown", "queue", s.name, "count", n)
+	}
+
+	s.prune(ctx)
+
+	for i := 0; i < s.config.Workers; i++ {
+		s.wg.Add(1)
+		go s.runWorker()
+	}
+	s.wg.Add(1)
+	go s.runPruner()
+	s.logger.Info("Job queue started", "queue", s.name, "backend", s.config.Backend, "workers", s.config.Workers)
+	return nil
+}
+
+// Stop signals the workers, waits for in-flight jobs, and closes an owned
+// database.
+func (s *JobQueueService) Stop(ctx context.Context) error {
+	select {
+	case <-s.stopCh:
+		return nil
+	default:
+		close(s.stopCh)
+	}
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.logger.Warn("Job queue stopped before in-flight jobs finished", "queue", s.name)
+	}
+	if c, ok := s.queue.(interface{ Close() error }); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+func (s *JobQueueService) openQueue() (JobQueue, error) {
+	if s.config.Backend == "memory" {
+		return NewInMemoryJobQueue(), nil
+	}
+	if s.config.Storage != "" {
+		storage, ok := s.app.SvcRegistry()[s.config.Storage].(*SQLiteStorage)
+		if !ok || storage.DB() == nil {
+			return nil, fmt.Errorf("storage %q is not a started storage.sqlite module", s.config.Storage)
+		}
+		return NewSQLJobQueue(storage.DB(), JobQueueSQLite, s.name)
+	}
+	return OpenJobQueue(s.config.DBPath, s.name)
+}
+
+// Enqueue adds a job for pipeline to run no earlier than delay from now.
+// It returns the stored job.
+func (s *JobQueueService) Enqueue(ctx context.Context, pipeline string, data map[string]any, priority int, delay time.Duration) (*QueuedJob, error) {
+	if s.queue == nil {
+		return nil, fmt.Errorf("jobqueue.service %q: not started", s.name)
+	}
+	now := s.now().UTC()
+	job := &QueuedJob{
+		ID:         uuid.NewString(),
+		Pipeline:   pipeline,
+		Data:       data,
+		Priority:   priority,
+		RunAt:      now.Add(delay),
+		EnqueuedAt: now,
+		Status:     JobStatusPending,
+	}
+	if err := s.queue.Enqueue(ctx, job); err != nil {
+		return nil, err
+	}
+	if delay <= 0 {
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+	return job, nil
+}
+
+func (s *JobQueueService) runWorker() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		// Drain every ready job before waiting again.
+		for s.runNext() {
+			select {
+			case <-s.stopCh:
+				return
+			default:
+			}
+		}
+		select {
+		case <-s.stopCh:
+			return
+		case <-s.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *JobQueueService) runPruner() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(jobPruneInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			s.prune(context.Background())
+		}
+	}
+}
+
+// prune removes finished jobs older than the retention period.
+func (s *JobQueueService) prune(ctx context.Context) {
+	n, err := s.queue.Prune(ctx, s.now().Add(-s.config.Retention))
+	if err != nil {
+		s.logger.Error("Failed to prune finished jobs", "queue", s.name, "error", err)
+		return
+	}
+	if n > 0 {
+		s.logger.Debug("Pruned finished jobs", "queue", s.name, "count", n)
+	}
+}
+
+// runNext claims and runs one job. It reports whether a job was claimed.
+func (s *JobQueueService) runNext() bool {
+	ctx := context.Background()
+	job, err := s.queue.Claim(ctx, s.now())
+	if err != nil {
+		s.logger.Error("Failed to claim job", "queue", s.name, "error", err)
+		return false
+	}
+	if job == nil {
+		return false
+	}
+
+	data := make(map[string]any, len(job.Data)+1)
+	for k, v := range job.Data {
+		data[k] = v
+	}
+	data["_job"] = map[string]any{
+		"id":          job.ID,
+		"queue":       s.name,
+		"attempt":     job.Attempts,
+		"enqueued_at": job.EnqueuedAt.Format(time.RFC3339Nano),
+	}
+
+	result, runErr := s.runJob(job, data)
+	if runErr == nil {
+		if err := s.queue.Complete(ctx, job.ID, result); err != nil {
+			s.logger.Error("Failed to complete job", "queue", s.name, "job", job.ID, "error", err)
+		}
+		return true
+	}
+
+	if job.Attempts >= s.config.MaxAttempts {
+		s.logger.Error("Job failed permanently", "queue", s.name, "job", job.ID, "pipeline", job.Pipeline, "attempts", job.Attempts, "error", runErr)
+		if err := s.queue.Fail(ctx, job.ID, runErr.Error()); err != nil {
+			s.logger.Error("Failed to park job", "queue", s.name, "job", job.ID, "error", err)
+		}
+		return true
+	}
+	backoff := s.config.RetryBackoff << (job.Attempts - 1)
+	s.logger.Warn("Job failed; retrying", "queue", s.name, "job", job.ID, "pipeline", job.Pipeline, "attempt", job.Attempts, "retry_in", backoff, "error", runErr)
+	if err := s.queue.Reschedule(ctx, job.ID, s.now().Add(backoff), runErr.Error()); err != nil {
+		s.logger.Error("Failed to reschedule job", "queue", s.name, "job", job.ID, "error", err)
+	}
+	return true
+}
+
+// runJob runs the job's pipeline and returns what it produced: the
+// step.pipeline_output output when there is one, otherwise the pipeline's
+// merged state without values that cannot be stored as JSON.
+func (s *JobQueueService) runJob(job *QueuedJob, data map[string]any) (result map[string]any, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.JobTimeout)
+	defer cancel()
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("panic: %v", rec)
+		}
+	}()
+	holder := &PipelineResultHolder{}
+	ctx = context.WithValue(ctx, PipelineResultContextKey, holder)
+	if err := s.engine.TriggerWorkflow(ctx, "pipeline:"+job.Pipeline, "", data); err != nil {
+		return nil, err
+	}
+	current := holder.Get()
+	if out, ok := current["_pipeline_output"].(map[string]any); ok {
+		return out, nil
+	}
+	if current == nil {
+		return nil, nil
+	}
+	result = make(map[string]any, len(current))
+	for k, v := range current {
+		if _, err := json.Marshal(v); err == nil {
+			result[k] = v
+		}
+	}
+	return result, nil
+}
+
+// ServeJobStatus serves GET {statusPath}/{id}: the job's status, attempts,
+// timestamps, last error and, once it succeeded, its result. The job's input
+// data is left out. Unknown and pruned jobs are 404.
+func (s *JobQueueService) ServeJobStatus(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	job, err := s.Job(r.Context(), id)
+	if err != nil {
+		s.logger.Error("Failed to look up job", "queue", s.name, "job", id, "error", err)
+		writeRequestProblem(w, r, ProblemInternalError, "")
+		return
+	}
+	if job == nil {
+		writeRequestProblem(w, r, ProblemNotFound, fmt.Sprintf("job %q not found", id))
+		return
+	}
+	job.Data = nil
+	writeJSON(w, http.StatusOK, job)
+}
+
+// JobStatusHTTPHandler adapts JobQueueService.ServeJobStatus to the
+// HTTPHandler interface.
+type JobStatusHTTPHandler struct {
+	Handler http.HandlerFunc
+}
+
+// Handle implements the HTTPHandler interface.
+func (h *JobStatusHTTPHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	h.Handler(w, r)
+}
+
+// ProvidesServices implements modular.Module.
+func (s *JobQueueService) ProvidesServices() []modular.ServiceProvider {
+	return []modular.ServiceProvider{
+		{Name: s.name, Description: "Background job queue: " + s.name, Instance: s},
+	}
+}
+
+// RequiresServices implements modular.Module.
+func (s *JobQueueService) RequiresServices() []modular.ServiceDependency {
+	if s.config.Storage != "" {
+		return []modular.ServiceDependency{{Name: s.config.Storage, Required: false}}
+	}
+	return nil
+}
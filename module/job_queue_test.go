@@ -0,0 +1,501 @@
+package module
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func testJobQueues(t *testing.T) map[string]func() JobQueue {
+	return map[string]func() JobQueue{
+		"memory": func() JobQueue { return NewInMemoryJobQueue() },
+		"sqlite": func() JobQueue {
+			q, err := OpenJobQueue(filepath.Join(t.TempDir(), "jobs.db"), "test")
+			if err != nil {
+				t.Fatalf("open sqlite queue: %v", err)
+			}
+			t.Cleanup(func() { _ = q.Close() })
+			return q
+		},
+	}
+}
+
+func TestJobQueue_ClaimOrder(t *testing.T) {
+	for name, newQueue := range testJobQueues(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			q := newQueue()
+			now := time.Now()
+			for _, j := range []*QueuedJob{
+				{ID: "low", Pipeline: "p", Priority: 0, RunAt: now.Add(-2 * time.Second)},
+				{ID: "high-delayed", Pipeline: "p", Priority: 10, RunAt: now.Add(time.Hour)},
+				{ID: "high", Pipeline: "p", Priority: 5, RunAt: now.Add(-time.Second), Data: map[string]any{"k": "v"}},
+				{ID: "low-newer", Pipeline: "p", Priority: 0, RunAt: now.Add(-time.Second)},
+			} {
+				if err := q.Enqueue(ctx, j); err != nil {
+					t.Fatalf("enqueue %s: %v", j.ID, err)
+				}
+			}
+
+			var order []string
+			for {
+				job, err := q.Claim(ctx, now)
+				if err != nil {
+					t.Fatalf("claim: %v", err)
+				}
+				if job == nil {
+					break
+				}
+				if job.Attempts != 1 {
+					t.Errorf("job %s attempts = %d, want 1", job.ID, job.Attempts)
+				}
+				if job.ID == "high" && job.Data["k"] != "v" {
+					t.Errorf("job data lost: %v", job.Data)
+				}
+				order = append(order, job.ID)
+			}
+			want := []string{"high", "low", "low-newer"}
+			if len(order) != len(want) {
+				t.Fatalf("claim order = %v, want %v", order, want)
+			}
+			for i := range want {
+				if order[i] != want[i] {
+					t.Fatalf("claim order = %v, want %v", order, want)
+				}
+			}
+
+			stats, err := q.Stats(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stats.Pending != 1 || stats.Running != 3 {
+				t.Errorf("stats = %+v, want 1 pending, 3 running", stats)
+			}
+		})
+	}
+}
+
+func TestJobQueue_RescheduleFailComplete(t *testing.T) {
+	for name, newQueue := range testJobQueues(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			q := newQueue()
+			now := time.Now()
+			for _, id := range []string{"a", "b"} {
+				if err := q.Enqueue(ctx, &QueuedJob{ID: id, Pipeline: "p", RunAt: now}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			a, _ := q.Claim(ctx, now)
+			b, _ := q.Claim(ctx, now)
+
+			if err := q.Reschedule(ctx, a.ID, now.Add(time.Minute), "boom"); err != nil {
+				t.Fatalf("reschedule: %v", err)
+			}
+			if job, _ := q.Claim(ctx, now); job != nil {
+				t.Fatalf("rescheduled job claimed before run_at: %+v", job)
+			}
+			again, err := q.Claim(ctx, now.Add(2*time.Minute))
+			if err != nil || again == nil {
+				t.Fatalf("expected rescheduled job, got %v, %v", again, err)
+			}
+			if again.Attempts != 2 || again.LastError != "boom" {
+				t.Errorf("rescheduled job = %+v", again)
+			}
+
+			if err := q.Fail(ctx, again.ID, "boom again"); err != nil {
+				t.Fatalf("fail: %v", err)
+			}
+			if err := q.Complete(ctx, b.ID, map[string]any{"sent": true}); err != nil {
+				t.Fatalf("complete: %v", err)
+			}
+			stats, _ := q.Stats(ctx)
+			if stats.Pending != 0 || stats.Running != 0 || stats.Succeeded != 1 || stats.Failed != 1 {
+				t.Errorf("stats = %+v, want 1 succeeded, 1 failed", stats)
+			}
+
+			done, err := q.Get(ctx, b.ID)
+			if err != nil || done == nil {
+				t.Fatalf("Get(%s) = %v, %v", b.ID, done, err)
+			}
+			if done.Status != JobStatusSucceeded || done.Result["sent"] != true || done.StartedAt == nil || done.FinishedAt == nil {
+				t.Errorf("completed job = %+v", done)
+			}
+			failed, _ := q.Get(ctx, a.ID)
+			if failed == nil || failed.Status != JobStatusFailed || failed.LastError != "boom again" || failed.FinishedAt == nil {
+				t.Errorf("failed job = %+v", failed)
+			}
+			if missing, err := q.Get(ctx, "missing"); missing != nil || err != nil {
+				t.Errorf("Get(missing) = %v, %v; want nil, nil", missing, err)
+			}
+		})
+	}
+}
+
+func TestJobQueue_Prune(t *testing.T) {
+	for name, newQueue := range testJobQueues(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			q := newQueue()
+			now := time.Now()
+			for _, id := range []string{"done", "pending"} {
+				if err := q.Enqueue(ctx, &QueuedJob{ID: id, Pipeline: "p", RunAt: now.Add(time.Duration(len(id)) * time.Millisecond)}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			job, _ := q.Claim(ctx, now.Add(4*time.Millisecond))
+			if job == nil || job.ID != "done" {
+				t.Fatalf("claimed %+v, want done", job)
+			}
+			if err := q.Complete(ctx, job.ID, nil); err != nil {
+				t.Fatal(err)
+			}
+
+			if n, err := q.Prune(ctx, time.Now().Add(-time.Hour)); err != nil || n != 0 {
+				t.Fatalf("Prune(an hour ago) = %d, %v; want 0", n, err)
+			}
+			if n, err := q.Prune(ctx, time.Now().Add(time.Second)); err != nil || n != 1 {
+				t.Fatalf("Prune(now) = %d, %v; want 1", n, err)
+			}
+			if got, _ := q.Get(ctx, "done"); got != nil {
+				t.Errorf("pruned job still returned: %+v", got)
+			}
+			if got, _ := q.Get(ctx, "pending"); got == nil || got.Status != JobStatusPending {
+				t.Errorf("pending job = %+v, want it kept", got)
+			}
+		})
+	}
+}
+
+func TestSQLJobQueue_MigratesStatusColumns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`CREATE TABLE job_queue (
+	id TEXT PRIMARY KEY, queue TEXT NOT NULL, pipeline TEXT NOT NULL, data TEXT NOT NULL,
+	priority INTEGER NOT NULL DEFAULT 0, run_at INTEGER NOT NULL, status TEXT NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0, last_error TEXT NOT NULL DEFAULT '', enqueued_at INTEGER NOT NULL);
+INSERT INTO job_queue VALUES ('old', 'jobs', 'p', '{}', 0, 0, 'pending', 0, '', 0);`)
+	_ = db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	q, err := OpenJobQueue(path, "jobs")
+	if err != nil {
+		t.Fatalf("open pre-status queue: %v", err)
+	}
+	defer q.Close()
+	job, err := q.Claim(ctx, time.Now())
+	if err != nil || job == nil || job.ID != "old" {
+		t.Fatalf("claim = %+v, %v", job, err)
+	}
+	if err := q.Complete(ctx, job.ID, map[string]any{"ok": true}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := q.Get(ctx, "old"); got == nil || got.Status != JobStatusSucceeded || got.Result["ok"] != true {
+		t.Errorf("migrated job = %+v", got)
+	}
+}
+
+func TestSQLJobQueue_RecoversRunningJobs(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "jobs.db")
+	q, err := OpenJobQueue(path, "emails")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	if err := q.Enqueue(ctx, &QueuedJob{ID: "j1", Pipeline: "send-email", RunAt: now, EnqueuedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+	if job, _ := q.Claim(ctx, now); job == nil {
+		t.Fatal("expected claim")
+	}
+	_ = q.Close() // simulate a crash mid-run
+
+	q, err = OpenJobQueue(path, "emails")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+	n, err := q.Recover(ctx)
+	if err != nil || n != 1 {
+		t.Fatalf("Recover() = %d, %v; want 1", n, err)
+	}
+	job, err := q.Claim(ctx, now)
+	if err != nil || job == nil || job.ID != "j1" || job.Attempts != 2 {
+		t.Fatalf("expected redelivered j1 on attempt 2, got %+v, %v", job, err)
+	}
+}
+
+// recordingJobEngine records TriggerWorkflow calls and fails the first
+// failures calls.
+type recordingJobEngine struct {
+	mu       sync.Mutex
+	calls    []map[string]any
+	types    []string
+	failures int
+	done     chan struct{}
+}
+
+func (e *recordingJobEngine) TriggerWorkflow(_ context.Context, workflowType, _ string, data map[string]any) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.calls = append(e.calls, data)
+	e.types = append(e.types, workflowType)
+	if len(e.calls) <= e.failures {
+		return errors.New("transient")
+	}
+	close(e.done)
+	return nil
+}
+
+func TestJobQueueService_RunsAndRetriesJobs(t *testing.T) {
+	engine := &recordingJobEngine{failures: 1, done: make(chan struct{})}
+	app := NewMockApplication()
+	app.Services["workflowEngine"] = engine
+
+	svc := NewJobQueueService("jobs", JobQueueConfig{
+		Backend:      "memory",
+		Workers:      2,
+		PollInterval: 5 * time.Millisecond,
+		RetryBackoff: time.Millisecond,
+	})
+	if err := svc.Init(app); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Start(context.Background()); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer svc.Stop(context.Background())
+
+	if _, err := svc.Enqueue(context.Background(), "send-email", map[string]any{"to": "a@example.com"}, 0, 0); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	select {
+	case <-engine.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("job did not complete")
+	}
+
+	engine.mu.Lock()
+	defer engine.mu.Unlock()
+	if len(engine.calls) != 2 {
+		t.Fatalf("expected 2 runs (1 retry), got %d", len(engine.calls))
+	}
+	if engine.types[1] != "pipeline:send-email" || engine.calls[1]["to"] != "a@example.com" {
+		t.Errorf("unexpected run: %s %v", engine.types[1], engine.calls[1])
+	}
+	if meta, _ := engine.calls[1]["_job"].(map[string]any); meta["attempt"] != 2 {
+		t.Errorf("expected _job.attempt 2, got %v", engine.calls[1]["_job"])
+	}
+}
+
+func TestJobQueueService_ParksAfterMaxAttempts(t *testing.T) {
+	engine := &recordingJobEngine{failures: 100, done: make(chan struct{})}
+	app := NewMockApplication()
+	app.Services["workflowEngine"] = engine
+
+	svc := NewJobQueueService("jobs", JobQueueConfig{
+		Backend:      "memory",
+		Workers:      1,
+		PollInterval: time.Millisecond,
+		RetryBackoff: time.Millisecond,
+		MaxAttempts:  2,
+	})
+	if err := svc.Init(app); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer svc.Stop(context.Background())
+
+	if _, err := svc.Enqueue(context.Background(), "flaky", nil, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		stats, _ := svc.Queue().Stats(context.Background())
+		if stats.Failed == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job not parked, stats %+v", stats)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+	engine.mu.Lock()
+	defer engine.mu.Unlock()
+	if len(engine.calls) != 2 {
+		t.Errorf("expected 2 attempts, got %d", len(engine.calls))
+	}
+}
+
+func TestJobQueueService_Errors(t *testing.T) {
+	if err := NewJobQueueService("q", JobQueueConfig{Backend: "redis"}).Init(NewMockApplication()); err == nil {
+		t.Error("expected error for unknown backend")
+	}
+	svc := NewJobQueueService("q", JobQueueConfig{Backend: "memory"})
+	if err := svc.Init(NewMockApplication()); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Start(context.Background()); err == nil {
+		t.Error("expected error without a workflow engine")
+	}
+}
+
+// outputJobEngine completes every run with a step.pipeline_output result.
+type outputJobEngine struct{}
+
+func (outputJobEngine) TriggerWorkflow(ctx context.Context, _, _ string, data map[string]any) error {
+	if holder, ok := ctx.Value(PipelineResultContextKey).(*PipelineResultHolder); ok {
+		holder.Set(map[string]any{
+			"to":               data["to"],
+			"_pipeline_output": map[string]any{"message_id": "m-1"},
+		})
+	}
+	return nil
+}
+
+func TestJobQueueService_JobStatus(t *testing.T) {
+	app := NewMockApplication()
+	app.Services["workflowEngine"] = outputJobEngine{}
+	svc := NewJobQueueService("jobs", JobQueueConfig{
+		Backend:      "memory",
+		PollInterval: time.Millisecond,
+		StatusPath:   "jobs/",
+	})
+	if err := svc.Init(app); err != nil {
+		t.Fatal(err)
+	}
+	if svc.StatusPath() != "/jobs" {
+		t.Errorf("StatusPath() = %q, want /jobs", svc.StatusPath())
+	}
+	if err := svc.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer svc.Stop(context.Background())
+
+	queued, err := svc.Enqueue(context.Background(), "send-email", map[string]any{"to": "a@example.com"}, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if queued.Status != JobStatusPending {
+		t.Errorf("enqueued status = %q, want pending", queued.Status)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, _ := svc.Job(context.Background(), queued.ID)
+		if job != nil && job.Status == JobStatusSucceeded {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job did not succeed: %+v", job)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /jobs/{id}", svc.ServeJobStatus)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jobs/"+queued.ID, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["status"] != JobStatusSucceeded || got["attempts"] != float64(1) || got["finished_at"] == nil {
+		t.Errorf("job status = %v", got)
+	}
+	if result, _ := got["result"].(map[string]any); result["message_id"] != "m-1" {
+		t.Errorf("result = %v, want the pipeline output", got["result"])
+	}
+	if _, ok := got["data"]; ok {
+		t.Errorf("job input data exposed: %v", got["data"])
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jobs/missing", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown job status = %d, want 404", rec.Code)
+	}
+}
+
+func TestSQLJobQueue_Leases(t *testing.T) {
+	ctx := context.Background()
+	q, err := OpenJobQueue(filepath.Join(t.TempDir(), "jobs.db"), "builds")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+	now := time.Now()
+	for _, j := range []*QueuedJob{
+		{ID: "gpu", Pipeline: "train", Labels: []string{"gpu"}, MaxAttempts: 2, RunAt: now, EnqueuedAt: now},
+		{ID: "any", Pipeline: "build", IdempotencyKey: "k1", MaxAttempts: 2, RunAt: now, EnqueuedAt: now.Add(time.Second)},
+	} {
+		if err := q.Enqueue(ctx, j); err != nil {
+			t.Fatalf("enqueue %s: %v", j.ID, err)
+		}
+	}
+	if err := q.Enqueue(ctx, &QueuedJob{ID: "dup", Pipeline: "build", IdempotencyKey: "k1", RunAt: now, EnqueuedAt: now}); !errors.Is(err, ErrDuplicateJob) {
+		t.Errorf("expected ErrDuplicateJob, got %v", err)
+	}
+
+	// A worker without the gpu label skips the gpu job.
+	job, err := q.ClaimLease(ctx, now, "w1", nil, time.Minute)
+	if err != nil || job == nil || job.ID != "any" || job.WorkerID != "w1" || job.LeaseExpiresAt == nil {
+		t.Fatalf("claim = %+v, %v; want any leased to w1", job, err)
+	}
+	if stats, _ := q.Stats(ctx); stats.RunningByWorker["w1"] != 1 || stats.PendingByPipeline["train"] != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	// The lease lapses and the job goes back to the queue; the old holder's
+	// outcome is refused and the next holder's accepted.
+	if err := q.RenewLeases(ctx, "w1", now.Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := q.ExpireLeases(ctx, now.Add(2*time.Second)); err != nil || n != 1 {
+		t.Fatalf("ExpireLeases() = %d, %v; want 1", n, err)
+	}
+	job, _ = q.ClaimLease(ctx, now, "w2", []string{"gpu"}, time.Minute)
+	if job == nil || job.ID != "gpu" {
+		t.Fatalf("claim = %+v, want gpu", job)
+	}
+	job, _ = q.ClaimLease(ctx, now, "w2", nil, time.Minute)
+	if job == nil || job.ID != "any" || job.Attempts != 2 || job.LastError != "lease expired on worker w1" {
+		t.Fatalf("reclaim = %+v", job)
+	}
+	if err := q.CompleteLease(ctx, "any", "w1", nil); !errors.Is(err, ErrJobLeaseLost) {
+		t.Errorf("expected ErrJobLeaseLost, got %v", err)
+	}
+	if err := q.CompleteLease(ctx, "missing", "w1", nil); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("expected ErrJobNotFound, got %v", err)
+	}
+	// The last attempt's failure fails the job.
+	if err := q.FailLease(ctx, "any", "w2", "boom"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := q.GetByIdempotencyKey(ctx, "k1"); got == nil || got.Status != JobStatusFailed || got.FinishedAt == nil {
+		t.Errorf("job = %+v, want failed", got)
+	}
+	if jobs, err := q.List(ctx, JobQueueFilter{WorkerID: "w2", Status: JobStatusRunning}); err != nil || len(jobs) != 1 || jobs[0].ID != "gpu" {
+		t.Errorf("List() = %v, %v; want gpu", jobs, err)
+	}
+}
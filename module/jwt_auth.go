@@ -3,6 +3,7 @@ package module
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"maps"
 	"net/http"
@@ -12,9 +13,9 @@ import (
 	"time"
 
 	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow/auth/password"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
 )
 
 // User represents a user in the in-memory store
@@ -46,6 +47,8 @@ type JWTAuthModule struct {
 	userStore         *UserStore        // optional external user store (from auth.user-store module)
 	allowRegistration bool              // when true, any visitor may self-register
 	tokenBlacklist    TokenBlacklist    // optional revocation check (wired by auth plugin)
+	hasher            *password.Hasher  // hashes passwords of the internal map (bcrypt when nil)
+	policy            *password.Policy  // requirements for new passwords (any when nil)
 }
 
 // NewJWTAuthModule creates a new JWT auth module
@@ -92,6 +95,18 @@ func (j *JWTAuthModule) SetTokenBlacklist(bl TokenBlacklist) {
 	j.tokenBlacklist = bl
 }
 
+// SetPasswordHasher sets the algorithm used to hash passwords of users kept
+// by this module. Users of an auth.user-store are hashed by the store.
+func (j *JWTAuthModule) SetPasswordHasher(h *password.Hasher) {
+	j.hasher = h
+}
+
+// SetPasswordPolicy sets the requirements passwords given to the register,
+// setup, user creation and password endpoints must meet.
+func (j *JWTAuthModule) SetPasswordPolicy(p *password.Policy) {
+	j.policy = p
+}
+
 // Name returns the module name
 func (j *JWTAuthModule) Name() string {
 	return j.name
@@ -195,6 +210,10 @@ func (j *JWTAuthModule) Handle(w http.ResponseWriter, r *http.Request) {
 		j.handleDeleteUser(w, r)
 	case r.Method == http.MethodPut && strings.Contains(path, "/auth/users/") && strings.HasSuffix(path, "/role"):
 		j.handleUpdateUserRole(w, r)
+	case r.Method == http.MethodPut && strings.Contains(path, "/auth/users/") && strings.HasSuffix(path, "/password"):
+		j.handleResetUserPassword(w, r)
+	case r.Method == http.MethodPut && strings.HasSuffix(path, "/auth/password"):
+		j.handleChangePassword(w, r)
 	default:
 		w.WriteHeader(http.StatusNotFound)
 		_ = json.NewEncoder(w).Encode(map[string]string{"error": "not found"})
@@ -226,6 +245,9 @@ func (j *JWTAuthModule) handleRegister(w http.ResponseWriter, r *http.Request) {
 		_ = json.NewEncoder(w).Encode(map[string]string{"error": "email and password are required"})
 		return
 	}
+	if !j.checkPasswordPolicy(w, req.Password, req.Email) {
+		return
+	}
 
 	var user *User
 	if j.userStore != nil {
@@ -252,7 +274,7 @@ func (j *JWTAuthModule) handleRegister(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		hash, err := j.passwordHasher().Hash(req.Password)
 		if err != nil {
 			j.mu.Unlock()
 			w.WriteHeader(http.StatusInternalServerError)
@@ -264,7 +286,7 @@ func (j *JWTAuthModule) handleRegister(w http.ResponseWriter, r *http.Request) {
 			ID:           fmt.Sprintf("%d", j.nextID),
 			Email:        req.Email,
 			Name:         req.Name,
-			PasswordHash: string(hash),
+			PasswordHash: hash,
 			CreatedAt:    time.Now(),
 		}
 		j.nextID++
@@ -290,6 +312,7 @@ func (j *JWTAuthModule) handleRegister(w http.ResponseWriter, r *http.Request) {
 		_ = json.NewEncoder(w).Encode(map[string]string{"error": "failed to generate token"})
 		return
 	}
+	regenerateSession(r)
 
 	w.WriteHeader(http.StatusCreated)
 	if j.responseFormat == "v1" {
@@ -324,15 +347,8 @@ func (j *JWTAuthModule) handleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, exists := j.lookupUser(req.Email)
-
-	if !exists {
-		w.WriteHeader(http.StatusUnauthorized)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid credentials"})
-		return
-	}
-
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+	user, ok := j.verifyCredentials(req.Email, req.Password)
+	if !ok {
 		w.WriteHeader(http.StatusUnauthorized)
 		_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid credentials"})
 		return
@@ -344,6 +360,7 @@ func (j *JWTAuthModule) handleLogin(w http.ResponseWriter, r *http.Request) {
 		_ = json.NewEncoder(w).Encode(map[string]string{"error": "failed to generate token"})
 		return
 	}
+	regenerateSession(r)
 
 	if j.responseFormat == "v1" {
 		refreshToken, err := j.generateRefreshToken(user)
@@ -590,11 +607,23 @@ func (j *JWTAuthModule) handleRefresh(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// handleLogout is a no-op that returns 200 OK (JWT tokens are stateless).
-func (j *JWTAuthModule) handleLogout(w http.ResponseWriter, _ *http.Request) {
+// handleLogout returns 200 OK. JWT tokens are stateless, so only the
+// request's http.session, if any, is ended.
+func (j *JWTAuthModule) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if s := SessionFromContext(r.Context(), ""); s != nil {
+		s.Destroy()
+	}
 	_ = json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 }
 
+// regenerateSession gives the request's http.session, if any, a new ID once
+// the user has authenticated, so a session ID fixed before login is useless.
+func regenerateSession(r *http.Request) {
+	if s := SessionFromContext(r.Context(), ""); s != nil {
+		s.RegenerateID()
+	}
+}
+
 // handleSetupStatus returns whether the system needs initial setup (no users exist).
 func (j *JWTAuthModule) handleSetupStatus(w http.ResponseWriter, _ *http.Request) {
 	count := j.userCount()
@@ -630,6 +659,9 @@ func (j *JWTAuthModule) handleSetup(w http.ResponseWriter, r *http.Request) {
 		_ = json.NewEncoder(w).Encode(map[string]string{"error": "email and password are required"})
 		return
 	}
+	if !j.checkPasswordPolicy(w, req.Password, req.Email) {
+		return
+	}
 
 	// Verify no users exist
 	if j.userCount() > 0 {
@@ -660,7 +692,7 @@ func (j *JWTAuthModule) handleSetup(w http.ResponseWriter, r *http.Request) {
 		}
 	} else {
 		j.mu.Lock()
-		hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		hash, err := j.passwordHasher().Hash(req.Password)
 		if err != nil {
 			j.mu.Unlock()
 			w.WriteHeader(http.StatusInternalServerError)
@@ -672,7 +704,7 @@ func (j *JWTAuthModule) handleSetup(w http.ResponseWriter, r *http.Request) {
 			ID:           fmt.Sprintf("%d", j.nextID),
 			Email:        req.Email,
 			Name:         req.Name,
-			PasswordHash: string(hash),
+			PasswordHash: hash,
 			Metadata:     meta,
 			CreatedAt:    time.Now(),
 		}
@@ -699,6 +731,7 @@ func (j *JWTAuthModule) handleSetup(w http.ResponseWriter, r *http.Request) {
 		_ = json.NewEncoder(w).Encode(map[string]string{"error": "failed to generate token"})
 		return
 	}
+	regenerateSession(r)
 
 	w.WriteHeader(http.StatusCreated)
 	if j.responseFormat == "v1" {
@@ -778,6 +811,9 @@ func (j *JWTAuthModule) handleCreateUser(w http.ResponseWriter, r *http.Request)
 		_ = json.NewEncoder(w).Encode(map[string]string{"error": "email and password are required"})
 		return
 	}
+	if !j.checkPasswordPolicy(w, req.Password, req.Email) {
+		return
+	}
 
 	if req.Role == "" {
 		req.Role = "user"
@@ -812,7 +848,7 @@ func (j *JWTAuthModule) handleCreateUser(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hash, err := j.passwordHasher().Hash(req.Password)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		_ = json.NewEncoder(w).Encode(map[string]string{"error": "failed to hash password"})
@@ -823,7 +859,7 @@ func (j *JWTAuthModule) handleCreateUser(w http.ResponseWriter, r *http.Request)
 		ID:           fmt.Sprintf("%d", j.nextID),
 		Email:        req.Email,
 		Name:         req.Name,
-		PasswordHash: string(hash),
+		PasswordHash: hash,
 		Metadata:     meta,
 		CreatedAt:    time.Now(),
 	}
@@ -987,6 +1023,108 @@ func (j *JWTAuthModule) handleUpdateUserRole(w http.ResponseWriter, r *http.Requ
 	_ = json.NewEncoder(w).Encode(j.buildUserResponse(target))
 }
 
+// handleChangePassword changes the password of the authenticated user, who
+// must give their current password.
+func (j *JWTAuthModule) handleChangePassword(w http.ResponseWriter, r *http.Request) {
+	user, err := j.extractUserFromRequest(r)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	var req struct {
+		CurrentPassword string `json:"current_password"` //nolint:gosec // G117: request DTO field
+		NewPassword     string `json:"new_password"`     //nolint:gosec // G117: request DTO field
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	if req.CurrentPassword == "" || req.NewPassword == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "current_password and new_password are required"})
+		return
+	}
+
+	if _, ok := j.verifyCredentials(user.Email, req.CurrentPassword); !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid credentials"})
+		return
+	}
+	if !j.checkPasswordPolicy(w, req.NewPassword, user.Email) {
+		return
+	}
+
+	if err := j.setPassword(user, req.NewPassword); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "failed to update password"})
+		return
+	}
+	regenerateSession(r)
+
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// handleResetUserPassword sets another user's password. Requires admin role.
+func (j *JWTAuthModule) handleResetUserPassword(w http.ResponseWriter, r *http.Request) {
+	requestor, err := j.extractUserFromRequest(r)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	if !j.isAdmin(requestor) {
+		w.WriteHeader(http.StatusForbidden)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "admin role required"})
+		return
+	}
+
+	// Extract user ID from URL: .../auth/users/{id}/password
+	userID := j.extractPathParam(strings.TrimSuffix(r.URL.Path, "/password"), "/auth/users/")
+	if userID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "user ID required"})
+		return
+	}
+
+	var req struct {
+		Password string `json:"password"` //nolint:gosec // G117: request DTO field
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	if req.Password == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "password is required"})
+		return
+	}
+
+	target, found := j.lookupUserByID(userID)
+	if !found {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "user not found"})
+		return
+	}
+	if !j.checkPasswordPolicy(w, req.Password, target.Email) {
+		return
+	}
+
+	if err := j.setPassword(target, req.Password); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "failed to update password"})
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(j.buildUserResponse(target))
+}
+
 // isAdmin checks if a user has the admin role.
 func (j *JWTAuthModule) isAdmin(user *User) bool {
 	if user.Metadata == nil {
@@ -1057,6 +1195,104 @@ func (j *JWTAuthModule) userCount() int {
 	return len(j.users)
 }
 
+// --- Password handling ---
+
+func (j *JWTAuthModule) passwordHasher() *password.Hasher {
+	if j.hasher != nil {
+		return j.hasher
+	}
+	return password.DefaultHasher()
+}
+
+// checkPasswordPolicy validates a new password for the account with the
+// given email. When it fails the policy, it writes a 400 response listing
+// the violations as field errors and returns false.
+func (j *JWTAuthModule) checkPasswordPolicy(w http.ResponseWriter, pw, email string) bool {
+	err := j.policy.Validate(pw, email)
+	if err == nil {
+		return true
+	}
+	var pe *password.PolicyError
+	if !errors.As(err, &pe) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "failed to check password"})
+		return false
+	}
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"error":  "password does not meet the password policy",
+		"errors": pe.Violations,
+	})
+	return false
+}
+
+// verifyCredentials returns the user with the given email when pw is their
+// password. A hash made with another algorithm or other parameters than
+// the configured ones is replaced on success.
+func (j *JWTAuthModule) verifyCredentials(email, pw string) (*User, bool) {
+	if j.userStore != nil {
+		user, err := j.userStore.VerifyPassword(email, pw)
+		return user, err == nil
+	}
+
+	user, exists := j.lookupUser(email)
+	if !exists {
+		return nil, false
+	}
+	j.mu.RLock()
+	stored := user.PasswordHash
+	j.mu.RUnlock()
+	match, rehash := j.passwordHasher().Verify(stored, pw)
+	if !match {
+		return nil, false
+	}
+	if rehash {
+		if hash, err := j.passwordHasher().Hash(pw); err == nil {
+			j.mu.Lock()
+			if user.PasswordHash == stored {
+				user.PasswordHash = hash
+			}
+			j.mu.Unlock()
+			j.persistUser(user)
+		}
+	}
+	return user, true
+}
+
+// setPassword replaces a user's password hash.
+func (j *JWTAuthModule) setPassword(user *User, pw string) error {
+	if j.userStore != nil {
+		return j.userStore.SetPassword(user.ID, pw)
+	}
+	hash, err := j.passwordHasher().Hash(pw)
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	user.PasswordHash = hash
+	j.mu.Unlock()
+	j.persistUser(user)
+	return nil
+}
+
+// persistUser writes a user of the internal map through to persistence.
+func (j *JWTAuthModule) persistUser(user *User) {
+	if j.persistence == nil {
+		return
+	}
+	j.mu.RLock()
+	record := UserRecord{
+		ID:           user.ID,
+		Email:        user.Email,
+		Name:         user.Name,
+		PasswordHash: user.PasswordHash,
+		Metadata:     user.Metadata,
+		CreatedAt:    user.CreatedAt,
+	}
+	j.mu.RUnlock()
+	_ = j.persistence.SaveUser(record)
+}
+
 // extractPathParam extracts the value after a prefix in a URL path.
 // For example, extractPathParam("/api/v1/auth/users/42", "/auth/users/") returns "42".
 func (j *JWTAuthModule) extractPathParam(path, prefix string) string {
@@ -1090,12 +1326,12 @@ func (j *JWTAuthModule) loadSeedUsers(path string) ([]*User, error) {
 			continue
 		}
 
-		password, _ := seed.Data["password"].(string)
-		if password == "" {
-			password = "changeme" // fallback for seed users
+		pw, _ := seed.Data["password"].(string)
+		if pw == "" {
+			pw = "changeme" // fallback for seed users
 		}
 
-		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		hash, err := j.passwordHasher().Hash(pw)
 		if err != nil {
 			continue
 		}
@@ -1117,7 +1353,7 @@ func (j *JWTAuthModule) loadSeedUsers(path string) ([]*User, error) {
 			ID:           seed.ID,
 			Email:        email,
 			Name:         name,
-			PasswordHash: string(hash),
+			PasswordHash: hash,
 			Metadata:     metadata,
 			CreatedAt:    time.Now(),
 		}
@@ -7,9 +7,11 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/GoCodeAlone/workflow/auth/password"
 	"github.com/golang-jwt/jwt/v5"
 )
 
@@ -882,3 +884,168 @@ func TestJWTAuth_ExtractUser_RejectsNonHS256(t *testing.T) {
 		})
 	}
 }
+
+// --- Password hashing and policy tests ---
+
+func testArgon2Hasher(t *testing.T) *password.Hasher {
+	t.Helper()
+	h, err := password.NewHasher(password.HashConfig{Algorithm: password.AlgorithmArgon2id, Memory: 64, Iterations: 1, Parallelism: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func loginStatus(j *JWTAuthModule, email, pw string) int {
+	body, _ := json.Marshal(map[string]string{"email": email, "password": pw})
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	j.Handle(w, req)
+	return w.Code
+}
+
+func TestJWTAuth_RegisterPasswordPolicy(t *testing.T) {
+	j := setupJWTAuth(t)
+	j.SetPasswordPolicy(&password.Policy{MinLength: 12, RequireDigit: true, DisallowEmail: true})
+
+	for body, want := range map[string]string{
+		`{"email":"a@example.com","password":"short"}`:                     "too_short,missing_digit",
+		`{"email":"longer1@example.com","password":"LONGER1@example.com"}`: "matches_email",
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/auth/register", bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+		j.Handle(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d; body: %s", w.Code, w.Body.String())
+		}
+		var resp struct {
+			Error  string               `json:"error"`
+			Errors []password.Violation `json:"errors"`
+		}
+		json.NewDecoder(w.Body).Decode(&resp)
+		codes := make([]string, len(resp.Errors))
+		for i, v := range resp.Errors {
+			if v.Field != "password" || v.Message == "" {
+				t.Errorf("unexpected field error %+v", v)
+			}
+			codes[i] = v.Code
+		}
+		if got := strings.Join(codes, ","); got != want {
+			t.Errorf("register %s: got codes %q, want %q", body, got, want)
+		}
+	}
+	if j.userCount() != 0 {
+		t.Error("expected rejected registrations not to create users")
+	}
+
+	registerUser(t, j, "ok@example.com", "OK", "correct-horse-9")
+}
+
+func TestJWTAuth_LoginRehashesPassword(t *testing.T) {
+	j := setupJWTAuth(t)
+	registerUser(t, j, "rehash@example.com", "Rehash", "mypassword")
+	user, _ := j.lookupUser("rehash@example.com")
+	if !strings.HasPrefix(user.PasswordHash, "$2a$") {
+		t.Fatalf("expected a bcrypt hash by default, got %q", user.PasswordHash)
+	}
+
+	j.SetPasswordHasher(testArgon2Hasher(t))
+	if code := loginStatus(j, "rehash@example.com", "wrong"); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for a wrong password, got %d", code)
+	}
+	if !strings.HasPrefix(user.PasswordHash, "$2a$") {
+		t.Error("expected a failed login not to rehash")
+	}
+	if code := loginStatus(j, "rehash@example.com", "mypassword"); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if !strings.HasPrefix(user.PasswordHash, "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Errorf("expected the hash to be upgraded to argon2id, got %q", user.PasswordHash)
+	}
+	if code := loginStatus(j, "rehash@example.com", "mypassword"); code != http.StatusOK {
+		t.Errorf("expected login with the upgraded hash to succeed, got %d", code)
+	}
+}
+
+func TestJWTAuth_UserStoreRehashesPassword(t *testing.T) {
+	j := setupJWTAuth(t)
+	store := NewUserStore("users")
+	j.userStore = store
+	registerUser(t, j, "store@example.com", "Store", "mypassword")
+
+	store.SetPasswordHasher(testArgon2Hasher(t))
+	if code := loginStatus(j, "store@example.com", "mypassword"); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	user, _ := store.GetUser("store@example.com")
+	if !strings.HasPrefix(user.PasswordHash, "$argon2id$") {
+		t.Errorf("expected the store to upgrade the hash, got %q", user.PasswordHash)
+	}
+}
+
+func TestJWTAuth_ChangePassword(t *testing.T) {
+	j := setupJWTAuth(t)
+	token := registerUser(t, j, "change@example.com", "Change", "old-password-1")
+	j.SetPasswordPolicy(&password.Policy{MinLength: 12})
+
+	change := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/auth/password", bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		j.Handle(w, req)
+		return w
+	}
+	if w := change(`{"current_password":"wrong","new_password":"new-password-22"}`); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for a wrong current password, got %d", w.Code)
+	}
+	if w := change(`{"current_password":"old-password-1","new_password":"short"}`); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), `"code":"too_short"`) {
+		t.Errorf("expected a too_short field error, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := change(`{"current_password":"old-password-1","new_password":"new-password-22"}`); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if code := loginStatus(j, "change@example.com", "old-password-1"); code != http.StatusUnauthorized {
+		t.Errorf("expected the old password to be rejected, got %d", code)
+	}
+	if code := loginStatus(j, "change@example.com", "new-password-22"); code != http.StatusOK {
+		t.Errorf("expected the new password to be accepted, got %d", code)
+	}
+}
+
+func TestJWTAuth_ResetUserPassword_Admin(t *testing.T) {
+	j, adminToken := setupAdminUser(t)
+	j.SetPasswordPolicy(&password.Policy{MinLength: 10, DisallowEmail: true})
+
+	body := `{"email":"user@test.com","name":"User","password":"pass123456","role":"user"}`
+	req := httptest.NewRequest(http.MethodPost, "/auth/users", bytes.NewBufferString(body))
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	w := httptest.NewRecorder()
+	j.Handle(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create user failed: %d: %s", w.Code, w.Body.String())
+	}
+	var created map[string]any
+	json.NewDecoder(w.Body).Decode(&created)
+	userID := created["id"].(string)
+
+	reset := func(token, body string) int {
+		req := httptest.NewRequest(http.MethodPut, "/auth/users/"+userID+"/password", bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		j.Handle(w, req)
+		return w.Code
+	}
+	userToken, _ := j.generateToken(&User{ID: userID, Email: "user@test.com"})
+	if code := reset(userToken, `{"password":"reset-pass-1"}`); code != http.StatusForbidden {
+		t.Errorf("expected 403 for a non-admin, got %d", code)
+	}
+	if code := reset(adminToken, `{"password":"USER@test.com"}`); code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a password equal to the email, got %d", code)
+	}
+	if code := reset(adminToken, `{"password":"reset-pass-1"}`); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if code := loginStatus(j, "user@test.com", "reset-pass-1"); code != http.StatusOK {
+		t.Errorf("expected the reset password to be accepted, got %d", code)
+	}
+}
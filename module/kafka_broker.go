@@ -1,10 +1,12 @@
 package module
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/GoCodeAlone/modular"
 	"github.com/GoCodeAlone/workflow/pkg/tlsutil"
@@ -42,17 +44,29 @@ type KafkaBroker struct {
 	encryptor      *FieldEncryptor
 	fieldProtector *ProtectedFieldManager
 	tlsCfg         KafkaTLSConfig
+	consumerCfg    KafkaConsumerConfig
+	retryBackoff   time.Duration
+	initErr        error
+
+	// Set instead of consumerGroup when partitions are assigned explicitly.
+	client            sarama.Client
+	partitionConsumer sarama.Consumer
+	offsetManager     sarama.OffsetManager
+	consumerWG        sync.WaitGroup
+
+	topicSchemaGuard
 }
 
 // NewKafkaBroker creates a new Kafka message broker.
 func NewKafkaBroker(name string) *KafkaBroker {
 	broker := &KafkaBroker{
-		name:      name,
-		brokers:   []string{"localhost:9092"},
-		groupID:   "workflow-group",
-		handlers:  make(map[string]MessageHandler),
-		logger:    &noopLogger{},
-		encryptor: NewFieldEncryptorFromEnv(),
+		name:         name,
+		brokers:      []string{"localhost:9092"},
+		groupID:      "workflow-group",
+		handlers:     make(map[string]MessageHandler),
+		logger:       &noopLogger{},
+		encryptor:    NewFieldEncryptorFromEnv(),
+		retryBackoff: time.Second,
 	}
 	broker.kafkaProducer = &kafkaProducerAdapter{broker: broker}
 	broker.kafkaConsumer = &kafkaConsumerAdapter{broker: broker}
@@ -64,12 +78,22 @@ func (b *KafkaBroker) Name() string {
 	return b.name
 }
 
-// Init initializes the module with the application context.
+// Init initializes the module with the application context. It also
+// surfaces any configuration error recorded by the factory.
 func (b *KafkaBroker) Init(app modular.Application) error {
 	b.logger = app.Logger()
+	if b.initErr != nil {
+		return fmt.Errorf("kafka broker %q: %w", b.name, b.initErr)
+	}
 	return nil
 }
 
+// SetInitErr stores a deferred configuration error to be returned by Init.
+// This is used by factory functions which cannot return errors directly.
+func (b *KafkaBroker) SetInitErr(err error) {
+	b.initErr = err
+}
+
 // ProvidesServices returns the services provided by this module.
 func (b *KafkaBroker) ProvidesServices() []modular.ServiceProvider {
 	return []modular.ServiceProvider{
@@ -177,7 +201,7 @@ func (b *KafkaBroker) Start(ctx context.Context) error {
 	config.Producer.Retry.Max = 3
 	config.Producer.Return.Successes = true
 	config.Consumer.Group.Rebalance.GroupStrategies = []sarama.BalanceStrategy{sarama.NewBalanceStrategyRoundRobin()}
-	config.Consumer.Offsets.Initial = sarama.OffsetNewest
+	b.applyConsumerConfig(config)
 
 	// Apply TLS configuration
 	if b.tlsCfg.Enabled {
@@ -220,8 +244,21 @@ func (b *KafkaBroker) Start(ctx context.Context) error {
 	}
 	b.producer = producer
 
-	// Create consumer group and start consuming if there are handlers
-	if len(b.handlers) > 0 {
+	// With explicit partition assignment, consume those partitions directly
+	if len(b.handlers) > 0 && len(b.consumerCfg.Partitions) > 0 {
+		consumerCtx, cancel := context.WithCancel(ctx)
+		if err := b.startPartitionConsumers(consumerCtx, config); err != nil {
+			cancel()
+			_ = b.closePartitionConsumers()
+			_ = producer.Close()
+			b.producer = nil
+			b.healthy = false
+			b.healthMsg = fmt.Sprintf("partition consumer failed: %v", err)
+			return err
+		}
+		b.cancelFunc = cancel
+	} else if len(b.handlers) > 0 {
+		// Otherwise create a consumer group and start consuming
 		topics := make([]string, 0, len(b.handlers))
 		for topic := range b.handlers {
 			topics = append(topics, topic)
@@ -266,21 +303,33 @@ func (b *KafkaBroker) Start(ctx context.Context) error {
 
 	b.healthy = true
 	b.healthMsg = "connected"
-	b.logger.Info("Kafka broker started", "brokers", b.brokers, "groupID", b.groupID)
+	b.logger.Info("Kafka broker started", "brokers", b.brokers, "groupID", b.groupID,
+		"commitMode", cmp.Or(b.consumerCfg.CommitMode, KafkaCommitAuto))
 	return nil
 }
 
 // Stop disconnects from Kafka.
 func (b *KafkaBroker) Stop(_ context.Context) error {
 	b.mu.Lock()
-	defer b.mu.Unlock()
-
-	if b.cancelFunc != nil {
-		b.cancelFunc()
-		b.cancelFunc = nil
+	cancel := b.cancelFunc
+	b.cancelFunc = nil
+	b.mu.Unlock()
+
+	// Partition consumers read b.mu while delivering, so wait for them to
+	// finish without holding it.
+	if cancel != nil {
+		cancel()
 	}
+	b.consumerWG.Wait()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
 
 	var lastErr error
+	if err := b.closePartitionConsumers(); err != nil {
+		lastErr = err
+		b.logger.Error("Failed to close Kafka partition consumer", "error", err)
+	}
 
 	if b.consumerGroup != nil {
 		if err := b.consumerGroup.Close(); err != nil {
@@ -304,6 +353,31 @@ func (b *KafkaBroker) Stop(_ context.Context) error {
 	return lastErr
 }
 
+// closePartitionConsumers closes the offset manager, consumer and client
+// used for explicit partition assignment. The caller holds b.mu.
+func (b *KafkaBroker) closePartitionConsumers() error {
+	var lastErr error
+	if b.offsetManager != nil {
+		if err := b.offsetManager.Close(); err != nil {
+			lastErr = fmt.Errorf("failed to close offset manager: %w", err)
+		}
+		b.offsetManager = nil
+	}
+	if b.partitionConsumer != nil {
+		if err := b.partitionConsumer.Close(); err != nil {
+			lastErr = fmt.Errorf("failed to close consumer: %w", err)
+		}
+		b.partitionConsumer = nil
+	}
+	if b.client != nil {
+		if err := b.client.Close(); err != nil {
+			lastErr = fmt.Errorf("failed to close client: %w", err)
+		}
+		b.client = nil
+	}
+	return lastErr
+}
+
 // kafkaProducerAdapter implements MessageProducer for Kafka.
 type kafkaProducerAdapter struct {
 	broker *KafkaBroker
@@ -321,6 +395,9 @@ func (p *kafkaProducerAdapter) SendMessage(topic string, message []byte) error {
 	if producer == nil {
 		return fmt.Errorf("kafka producer not initialized; call Start first")
 	}
+	if err := p.broker.checkPublish(topic, message); err != nil {
+		return err
+	}
 
 	payload := message
 
@@ -393,44 +470,23 @@ type kafkaGroupHandler struct {
 func (h *kafkaGroupHandler) Setup(_ sarama.ConsumerGroupSession) error   { return nil }
 func (h *kafkaGroupHandler) Cleanup(_ sarama.ConsumerGroupSession) error { return nil }
 
+// ConsumeClaim delivers the claim's messages in order. In manual commit mode
+// each offset is committed once its handler succeeds; if the session ends
+// while a message is still failing, it is left uncommitted so the
+// partition's next owner receives it again.
 func (h *kafkaGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
 	h.broker.setHealthy("consuming")
+	h.broker.mu.RLock()
+	manual := h.broker.consumerCfg.manualCommit()
+	h.broker.mu.RUnlock()
 	for msg := range claim.Messages() {
-		h.broker.mu.RLock()
-		handler, ok := h.broker.handlers[msg.Topic]
-		encryptor := h.broker.encryptor
-		fieldProt := h.broker.fieldProtector
-		h.broker.mu.RUnlock()
-
-		if ok {
-			// Legacy whole-message decryption first.
-			payload := msg.Value
-			if encryptor != nil && encryptor.Enabled() {
-				decrypted, err := encryptor.DecryptJSON(payload)
-				if err != nil {
-					h.broker.logger.Error("Error decrypting Kafka message", "topic", msg.Topic, "error", err)
-					session.MarkMessage(msg, "")
-					continue
-				}
-				payload = decrypted
-			}
-			// Field-level decryption: decrypt individual protected fields.
-			if fieldProt != nil {
-				var data map[string]any
-				if err := json.Unmarshal(payload, &data); err == nil {
-					if decErr := fieldProt.DecryptMap(context.Background(), "", data); decErr == nil {
-						if out, err := json.Marshal(data); err == nil {
-							payload = out
-						}
-					}
-				}
-			}
-
-			if err := handler.HandleMessage(payload); err != nil {
-				h.broker.logger.Error("Error handling Kafka message", "topic", msg.Topic, "error", err)
-			}
+		if !h.broker.deliver(session.Context(), msg) {
+			return nil
 		}
 		session.MarkMessage(msg, "")
+		if manual {
+			session.Commit()
+		}
 	}
 	return nil
 }
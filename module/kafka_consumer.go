@@ -0,0 +1,327 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// Kafka offset reset and commit modes.
+const (
+	KafkaOffsetEarliest = "earliest"
+	KafkaOffsetLatest   = "latest"
+
+	// KafkaCommitAuto marks every message once it has been handed to its
+	// handler, whatever the outcome, and commits marked offsets periodically.
+	KafkaCommitAuto = "auto"
+	// KafkaCommitManualAfterHandler commits a message's offset only after its
+	// handler succeeds, giving at-least-once delivery. A failing message is
+	// retried with backoff and blocks its partition until it succeeds.
+	KafkaCommitManualAfterHandler = "manual-after-handler"
+)
+
+// KafkaConsumerConfig controls where consumption starts and how offsets are
+// committed.
+type KafkaConsumerConfig struct {
+	// AutoOffsetReset is where to start when the group has no committed
+	// offset for a partition: "earliest" or "latest" (the default).
+	AutoOffsetReset string `yaml:"autoOffsetReset" json:"autoOffsetReset"`
+	// CommitMode is "auto" (the default) or "manual-after-handler".
+	CommitMode string `yaml:"commitMode" json:"commitMode"`
+	// Partitions, when set, assigns partitions explicitly per topic instead
+	// of joining the consumer group's rebalancing. Offsets are still
+	// committed under the group ID.
+	Partitions map[string][]int32 `yaml:"partitions" json:"partitions"`
+}
+
+// Validate checks the offset reset and commit modes and the partitions.
+func (c KafkaConsumerConfig) Validate() error {
+	if c.AutoOffsetReset != "" && c.AutoOffsetReset != KafkaOffsetEarliest && c.AutoOffsetReset != KafkaOffsetLatest {
+		return fmt.Errorf("autoOffsetReset must be %q or %q, got %q", KafkaOffsetEarliest, KafkaOffsetLatest, c.AutoOffsetReset)
+	}
+	if c.CommitMode != "" && c.CommitMode != KafkaCommitAuto && c.CommitMode != KafkaCommitManualAfterHandler {
+		return fmt.Errorf("commitMode must be %q or %q, got %q", KafkaCommitAuto, KafkaCommitManualAfterHandler, c.CommitMode)
+	}
+	for topic, parts := range c.Partitions {
+		if len(parts) == 0 {
+			return fmt.Errorf("partitions.%s: at least one partition is required", topic)
+		}
+		for _, p := range parts {
+			if p < 0 {
+				return fmt.Errorf("partitions.%s: invalid partition %d", topic, p)
+			}
+		}
+	}
+	return nil
+}
+
+// manualCommit reports whether offsets are committed only after the handler
+// succeeds.
+func (c KafkaConsumerConfig) manualCommit() bool {
+	return c.CommitMode == KafkaCommitManualAfterHandler
+}
+
+// ParseKafkaConsumerConfig reads autoOffsetReset, commitMode and partitions
+// from a messaging.kafka module config.
+func ParseKafkaConsumerConfig(cfg map[string]any) (KafkaConsumerConfig, error) {
+	var out KafkaConsumerConfig
+	out.AutoOffsetReset, _ = cfg["autoOffsetReset"].(string)
+	out.CommitMode, _ = cfg["commitMode"].(string)
+	if raw, ok := cfg["partitions"]; ok && raw != nil {
+		topics, ok := raw.(map[string]any)
+		if !ok {
+			return out, fmt.Errorf("partitions must be a map of topic to partition list")
+		}
+		out.Partitions = make(map[string][]int32, len(topics))
+		for topic, v := range topics {
+			var list []any
+			switch v := v.(type) {
+			case []any:
+				list = v
+			case string:
+				// "0,1,2", as entered in a key/value editor.
+				for _, part := range strings.Split(v, ",") {
+					n, err := strconv.ParseInt(strings.TrimSpace(part), 10, 32)
+					if err != nil {
+						return out, fmt.Errorf("partitions.%s: invalid partition %q", topic, part)
+					}
+					list = append(list, n)
+				}
+			default:
+				return out, fmt.Errorf("partitions.%s must be a list of partition numbers", topic)
+			}
+			out.Partitions[topic] = make([]int32, 0, len(list))
+			for _, p := range list {
+				n, ok := kafkaPartitionNumber(p)
+				if !ok {
+					return out, fmt.Errorf("partitions.%s: invalid partition %v", topic, p)
+				}
+				out.Partitions[topic] = append(out.Partitions[topic], n)
+			}
+		}
+	}
+	return out, out.Validate()
+}
+
+// kafkaPartitionNumber converts a YAML or JSON number to a partition number.
+func kafkaPartitionNumber(v any) (int32, bool) {
+	switch n := v.(type) {
+	case int:
+		return int32(n), n >= 0 && n <= 1<<31-1 //nolint:gosec // G115: range checked
+	case int64:
+		return int32(n), n >= 0 && n <= 1<<31-1 //nolint:gosec // G115: range checked
+	case float64:
+		return int32(n), n >= 0 && n <= 1<<31-1 && n == float64(int32(n))
+	default:
+		return 0, false
+	}
+}
+
+// SetConsumerConfig sets the offset reset, commit mode and partition
+// assignment used from the next Start.
+func (b *KafkaBroker) SetConsumerConfig(cfg KafkaConsumerConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.consumerCfg = cfg
+	return nil
+}
+
+// applyConsumerConfig sets the sarama consumer options for the broker's
+// consumer config.
+func (b *KafkaBroker) applyConsumerConfig(config *sarama.Config) {
+	config.Consumer.Offsets.Initial = sarama.OffsetNewest
+	if b.consumerCfg.AutoOffsetReset == KafkaOffsetEarliest {
+		config.Consumer.Offsets.Initial = sarama.OffsetOldest
+	}
+	// Manual mode commits synchronously after each handled message.
+	config.Consumer.Offsets.AutoCommit.Enable = !b.consumerCfg.manualCommit()
+}
+
+// deliver decrypts msg and passes it to its topic's handler. It reports
+// whether the message is finished with and its offset can be committed.
+// In auto commit mode that is always true. In manual mode a failing handler
+// is retried with backoff until it succeeds, or until ctx ends, in which
+// case deliver returns false and the message is left uncommitted.
+func (b *KafkaBroker) deliver(ctx context.Context, msg *sarama.ConsumerMessage) bool {
+	b.mu.RLock()
+	handler, ok := b.handlers[msg.Topic]
+	encryptor := b.encryptor
+	fieldProt := b.fieldProtector
+	manual := b.consumerCfg.manualCommit()
+	backoff := b.retryBackoff
+	b.mu.RUnlock()
+
+	if !ok {
+		return true
+	}
+
+	// Legacy whole-message decryption first.
+	payload := msg.Value
+	if encryptor != nil && encryptor.Enabled() {
+		decrypted, err := encryptor.DecryptJSON(payload)
+		if err != nil {
+			// Retrying cannot fix an undecryptable message, so skip it.
+			b.logger.Error("Error decrypting Kafka message", "topic", msg.Topic, "error", err)
+			return true
+		}
+		payload = decrypted
+	}
+	// Field-level decryption: decrypt individual protected fields.
+	if fieldProt != nil {
+		var data map[string]any
+		if err := json.Unmarshal(payload, &data); err == nil {
+			if decErr := fieldProt.DecryptMap(context.Background(), "", data); decErr == nil {
+				if out, err := json.Marshal(data); err == nil {
+					payload = out
+				}
+			}
+		}
+	}
+	// Invalid messages go to the DLQ instead of the handler; retrying
+	// cannot fix them either.
+	if !b.acceptDelivery(msg.Topic, payload, b.name) {
+		return true
+	}
+
+	for {
+		err := handler.HandleMessage(payload)
+		if err == nil {
+			return true
+		}
+		if !manual {
+			b.logger.Error("Error handling Kafka message", "topic", msg.Topic, "error", err)
+			return true
+		}
+		b.logger.Error("Error handling Kafka message, will retry",
+			"topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset, "error", err, "retry_in", backoff)
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, kafkaMaxRetryBackoff)
+	}
+}
+
+// kafkaMaxRetryBackoff caps the delay between retries of a failing message
+// in manual commit mode.
+const kafkaMaxRetryBackoff = 30 * time.Second
+
+// startPartitionConsumers consumes the explicitly assigned partitions of
+// subscribed topics, resuming from the offsets committed under the group ID.
+// The caller holds b.mu and closes the client, consumer and offset manager
+// on error.
+func (b *KafkaBroker) startPartitionConsumers(ctx context.Context, config *sarama.Config) error {
+	client, err := sarama.NewClient(b.brokers, config)
+	if err != nil {
+		return fmt.Errorf("failed to create Kafka client: %w", err)
+	}
+	b.client = client
+	consumer, err := sarama.NewConsumerFromClient(client)
+	if err != nil {
+		return fmt.Errorf("failed to create Kafka consumer: %w", err)
+	}
+	b.partitionConsumer = consumer
+	offsets, err := sarama.NewOffsetManagerFromClient(b.groupID, client)
+	if err != nil {
+		return fmt.Errorf("failed to create Kafka offset manager: %w", err)
+	}
+	b.offsetManager = offsets
+
+	// Open every partition before consuming any, so a failure leaves no
+	// consumer goroutines behind.
+	type assigned struct {
+		pc  sarama.PartitionConsumer
+		pom sarama.PartitionOffsetManager
+	}
+	var opened []assigned
+	closeOpened := func() {
+		for _, a := range opened {
+			_ = a.pc.Close()
+			_ = a.pom.Close()
+		}
+	}
+	topics := make([]string, 0, len(b.consumerCfg.Partitions))
+	for topic := range b.consumerCfg.Partitions {
+		topics = append(topics, topic)
+	}
+	slices.Sort(topics)
+	for _, topic := range topics {
+		if _, ok := b.handlers[topic]; !ok {
+			b.logger.Warn("Kafka partitions assigned for a topic with no handler", "topic", topic)
+			continue
+		}
+		for _, partition := range b.consumerCfg.Partitions[topic] {
+			pom, err := offsets.ManagePartition(topic, partition)
+			if err != nil {
+				closeOpened()
+				return fmt.Errorf("failed to manage offsets for %s/%d: %w", topic, partition, err)
+			}
+			next, _ := pom.NextOffset()
+			pc, err := consumer.ConsumePartition(topic, partition, next)
+			if err != nil {
+				_ = pom.Close()
+				closeOpened()
+				return fmt.Errorf("failed to consume %s/%d: %w", topic, partition, err)
+			}
+			opened = append(opened, assigned{pc: pc, pom: pom})
+		}
+	}
+
+	manual := b.consumerCfg.manualCommit()
+	for _, a := range opened {
+		b.consumerWG.Add(1)
+		go func() {
+			defer b.consumerWG.Done()
+			defer func() {
+				if rec := recover(); rec != nil {
+					b.logger.Error("panic in Kafka partition consumer", "panic", rec)
+					b.setUnhealthy(fmt.Sprintf("consumer panic: %v", rec))
+				}
+			}()
+			b.consumePartition(ctx, a.pc, a.pom, manual, offsets.Commit)
+		}()
+	}
+	for topic := range b.handlers {
+		if _, ok := b.consumerCfg.Partitions[topic]; !ok {
+			b.logger.Warn("Kafka topic has a handler but no assigned partitions; it will not be consumed", "topic", topic)
+		}
+	}
+	return nil
+}
+
+// consumePartition delivers messages from one assigned partition in order,
+// marking each offset once delivered and, in manual commit mode, committing
+// it straight away.
+func (b *KafkaBroker) consumePartition(ctx context.Context, pc sarama.PartitionConsumer, pom sarama.PartitionOffsetManager, manual bool, commit func()) {
+	defer func() {
+		_ = pc.Close()
+		_ = pom.Close()
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-pc.Messages():
+			if !ok {
+				return
+			}
+			if !b.deliver(ctx, msg) {
+				return
+			}
+			pom.MarkOffset(msg.Offset+1, "")
+			if manual {
+				commit()
+			}
+		}
+	}
+}
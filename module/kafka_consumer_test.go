@@ -0,0 +1,250 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+func TestParseKafkaConsumerConfig(t *testing.T) {
+	cfg, err := ParseKafkaConsumerConfig(map[string]any{
+		"autoOffsetReset": "earliest",
+		"commitMode":      "manual-after-handler",
+		"partitions":      map[string]any{"orders": []any{0, 2.0}, "audit": "1, 3"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.AutoOffsetReset != KafkaOffsetEarliest || !cfg.manualCommit() {
+		t.Errorf("cfg = %+v", cfg)
+	}
+	if !slices.Equal(cfg.Partitions["orders"], []int32{0, 2}) || !slices.Equal(cfg.Partitions["audit"], []int32{1, 3}) {
+		t.Errorf("partitions = %v", cfg.Partitions)
+	}
+
+	for name, raw := range map[string]map[string]any{
+		"offset reset":  {"autoOffsetReset": "oldest"},
+		"commit mode":   {"commitMode": "manual"},
+		"not a map":     {"partitions": []any{0}},
+		"negative":      {"partitions": map[string]any{"orders": []any{-1}}},
+		"fractional":    {"partitions": map[string]any{"orders": []any{1.5}}},
+		"empty list":    {"partitions": map[string]any{"orders": []any{}}},
+		"bad string":    {"partitions": map[string]any{"orders": "0,x"}},
+		"not a number":  {"partitions": map[string]any{"orders": []any{"0"}}},
+		"not list type": {"partitions": map[string]any{"orders": true}},
+	} {
+		if _, err := ParseKafkaConsumerConfig(raw); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestKafkaBrokerApplyConsumerConfig(t *testing.T) {
+	b := NewKafkaBroker("kafka-test")
+	config := sarama.NewConfig()
+	b.applyConsumerConfig(config)
+	if config.Consumer.Offsets.Initial != sarama.OffsetNewest || !config.Consumer.Offsets.AutoCommit.Enable {
+		t.Errorf("defaults: initial %d, autocommit %v; want newest with autocommit", config.Consumer.Offsets.Initial, config.Consumer.Offsets.AutoCommit.Enable)
+	}
+
+	if err := b.SetConsumerConfig(KafkaConsumerConfig{AutoOffsetReset: KafkaOffsetEarliest, CommitMode: KafkaCommitManualAfterHandler}); err != nil {
+		t.Fatal(err)
+	}
+	b.applyConsumerConfig(config)
+	if config.Consumer.Offsets.Initial != sarama.OffsetOldest || config.Consumer.Offsets.AutoCommit.Enable {
+		t.Errorf("earliest/manual: initial %d, autocommit %v; want oldest without autocommit", config.Consumer.Offsets.Initial, config.Consumer.Offsets.AutoCommit.Enable)
+	}
+
+	if err := b.SetConsumerConfig(KafkaConsumerConfig{CommitMode: "sometimes"}); err == nil {
+		t.Error("expected SetConsumerConfig to reject an unknown commit mode")
+	}
+}
+
+func TestKafkaBrokerInitReportsConfigError(t *testing.T) {
+	b := NewKafkaBroker("kafka-test")
+	b.SetInitErr(errors.New("commitMode must be set"))
+	app, _ := NewTestApplication()
+	if err := b.Init(app); err == nil || !strings.Contains(err.Error(), `kafka broker "kafka-test": commitMode must be set`) {
+		t.Errorf("Init error = %v", err)
+	}
+}
+
+// flakyKafkaHandler fails the first failures messages it is given.
+type flakyKafkaHandler struct {
+	mu       sync.Mutex
+	failures int
+	calls    int
+	handled  []string
+}
+
+func (h *flakyKafkaHandler) HandleMessage(msg []byte) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.calls++
+	if h.failures > 0 {
+		h.failures--
+		return errors.New("downstream unavailable")
+	}
+	h.handled = append(h.handled, string(msg))
+	return nil
+}
+
+type fakeGroupSession struct {
+	sarama.ConsumerGroupSession
+	ctx     context.Context
+	marked  []int64
+	commits int
+}
+
+func (s *fakeGroupSession) Context() context.Context { return s.ctx }
+func (s *fakeGroupSession) MarkMessage(msg *sarama.ConsumerMessage, _ string) {
+	s.marked = append(s.marked, msg.Offset)
+}
+func (s *fakeGroupSession) Commit() { s.commits++ }
+
+type fakeGroupClaim struct {
+	sarama.ConsumerGroupClaim
+	msgs chan *sarama.ConsumerMessage
+}
+
+func (c *fakeGroupClaim) Messages() <-chan *sarama.ConsumerMessage { return c.msgs }
+
+func kafkaMessages(topic string, values ...string) chan *sarama.ConsumerMessage {
+	ch := make(chan *sarama.ConsumerMessage, len(values))
+	for i, v := range values {
+		ch <- &sarama.ConsumerMessage{Topic: topic, Offset: int64(i), Value: []byte(v)}
+	}
+	close(ch)
+	return ch
+}
+
+func newConsumerTestBroker(t *testing.T, commitMode string, handler MessageHandler) *KafkaBroker {
+	t.Helper()
+	b := NewKafkaBroker("kafka-test")
+	b.encryptor = nil
+	b.retryBackoff = time.Millisecond
+	if err := b.SetConsumerConfig(KafkaConsumerConfig{CommitMode: commitMode}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Subscribe("orders", handler); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestKafkaConsumeClaim_ManualCommitRetriesUntilHandled(t *testing.T) {
+	handler := &flakyKafkaHandler{failures: 2}
+	b := newConsumerTestBroker(t, KafkaCommitManualAfterHandler, handler)
+	session := &fakeGroupSession{ctx: context.Background()}
+
+	err := (&kafkaGroupHandler{broker: b}).ConsumeClaim(session, &fakeGroupClaim{msgs: kafkaMessages("orders", "a", "b")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if handler.calls != 4 || !slices.Equal(handler.handled, []string{"a", "b"}) {
+		t.Errorf("calls = %d, handled = %v; want a retried twice then b", handler.calls, handler.handled)
+	}
+	if !slices.Equal(session.marked, []int64{0, 1}) || session.commits != 2 {
+		t.Errorf("marked = %v, commits = %d; want each offset committed after its handler", session.marked, session.commits)
+	}
+}
+
+func TestKafkaConsumeClaim_ManualCommitLeavesFailingMessageUncommitted(t *testing.T) {
+	handler := &flakyKafkaHandler{failures: 1 << 30}
+	b := newConsumerTestBroker(t, KafkaCommitManualAfterHandler, handler)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	session := &fakeGroupSession{ctx: ctx}
+
+	err := (&kafkaGroupHandler{broker: b}).ConsumeClaim(session, &fakeGroupClaim{msgs: kafkaMessages("orders", "a", "b")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(session.marked) != 0 || session.commits != 0 {
+		t.Errorf("marked = %v, commits = %d; want the failing message left for redelivery", session.marked, session.commits)
+	}
+	if len(handler.handled) != 0 {
+		t.Errorf("handled = %v; later messages must wait for the failing one", handler.handled)
+	}
+}
+
+func TestKafkaConsumeClaim_AutoCommitMarksFailures(t *testing.T) {
+	handler := &flakyKafkaHandler{failures: 1}
+	b := newConsumerTestBroker(t, KafkaCommitAuto, handler)
+	session := &fakeGroupSession{ctx: context.Background()}
+
+	if err := (&kafkaGroupHandler{broker: b}).ConsumeClaim(session, &fakeGroupClaim{msgs: kafkaMessages("orders", "a", "b")}); err != nil {
+		t.Fatal(err)
+	}
+	if handler.calls != 2 || !slices.Equal(handler.handled, []string{"b"}) {
+		t.Errorf("calls = %d, handled = %v; auto mode must not retry", handler.calls, handler.handled)
+	}
+	if !slices.Equal(session.marked, []int64{0, 1}) || session.commits != 0 {
+		t.Errorf("marked = %v, commits = %d; want both marked and left to auto commit", session.marked, session.commits)
+	}
+}
+
+type fakePartitionConsumer struct {
+	sarama.PartitionConsumer
+	msgs   chan *sarama.ConsumerMessage
+	closed bool
+}
+
+func (pc *fakePartitionConsumer) Messages() <-chan *sarama.ConsumerMessage { return pc.msgs }
+func (pc *fakePartitionConsumer) Close() error                             { pc.closed = true; return nil }
+
+type fakePartitionOffsets struct {
+	sarama.PartitionOffsetManager
+	marked []int64
+	closed bool
+}
+
+func (p *fakePartitionOffsets) MarkOffset(offset int64, _ string) {
+	p.marked = append(p.marked, offset)
+}
+func (p *fakePartitionOffsets) Close() error { p.closed = true; return nil }
+
+func TestKafkaConsumePartition_ManualCommit(t *testing.T) {
+	handler := &flakyKafkaHandler{failures: 1}
+	b := newConsumerTestBroker(t, KafkaCommitManualAfterHandler, handler)
+	pc := &fakePartitionConsumer{msgs: kafkaMessages("orders", "a", "b")}
+	pom := &fakePartitionOffsets{}
+	commits := 0
+
+	b.consumePartition(context.Background(), pc, pom, true, func() { commits++ })
+
+	if !slices.Equal(handler.handled, []string{"a", "b"}) {
+		t.Errorf("handled = %v", handler.handled)
+	}
+	// The next offset to consume is committed, i.e. the message offset + 1.
+	if !slices.Equal(pom.marked, []int64{1, 2}) || commits != 2 {
+		t.Errorf("marked = %v, commits = %d", pom.marked, commits)
+	}
+	if !pc.closed || !pom.closed {
+		t.Error("partition consumer and offset manager should be closed when consumption ends")
+	}
+}
+
+func TestKafkaConsumePartition_StopsOnCancel(t *testing.T) {
+	b := newConsumerTestBroker(t, KafkaCommitAuto, &flakyKafkaHandler{})
+	pc := &fakePartitionConsumer{msgs: make(chan *sarama.ConsumerMessage)}
+	pom := &fakePartitionOffsets{}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		b.consumePartition(ctx, pc, pom, false, func() {})
+		close(done)
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("consumePartition did not return after cancel")
+	}
+}
@@ -5,7 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"sync"
+	"strconv"
 	"time"
 
 	"github.com/GoCodeAlone/modular"
@@ -17,6 +17,11 @@ type LogEntry struct {
 	Module    string    `json:"module"`
 	Level     string    `json:"level"`
 	Message   string    `json:"message"`
+	// ExecutionID links the entry to a workflow execution, if any.
+	ExecutionID string `json:"executionId,omitempty"`
+	// RequestID links the entry to the HTTP request that started the
+	// execution, if any.
+	RequestID string `json:"requestId,omitempty"`
 }
 
 // LogEmitter is implemented by modules that produce log entries.
@@ -26,22 +31,40 @@ type LogEmitter interface {
 }
 
 // LogCollectorConfig holds the configuration for the log collector module.
+//
+// With Storage "memory" (the default) the most recent MaxEntries entries are
+// kept in memory. With Storage "file" entries are appended to JSONL segments
+// in Directory, rotated by MaxSegmentBytes, MaxSegmentEntries and
+// MaxSegmentAge, and the oldest segments are deleted once older than
+// RetentionDays or while the segments exceed DiskQuotaBytes. A negative
+// limit disables it.
 type LogCollectorConfig struct {
-	LogLevel      string `yaml:"logLevel" json:"logLevel"`
-	OutputFormat  string `yaml:"outputFormat" json:"outputFormat"`
-	RetentionDays int    `yaml:"retentionDays" json:"retentionDays"`
+	LogLevel          string        `yaml:"logLevel" json:"logLevel"`
+	OutputFormat      string        `yaml:"outputFormat" json:"outputFormat"`
+	RetentionDays     int           `yaml:"retentionDays" json:"retentionDays"`
+	Storage           string        `yaml:"storage" json:"storage"`
+	MaxEntries        int           `yaml:"maxEntries" json:"maxEntries"`
+	Directory         string        `yaml:"directory" json:"directory"`
+	MaxSegmentBytes   int64         `yaml:"maxSegmentBytes" json:"maxSegmentBytes"`
+	MaxSegmentEntries int           `yaml:"maxSegmentEntries" json:"maxSegmentEntries"`
+	MaxSegmentAge     time.Duration `yaml:"maxSegmentAge" json:"maxSegmentAge"`
+	DiskQuotaBytes    int64         `yaml:"diskQuotaBytes" json:"diskQuotaBytes"`
 }
 
+// logMaintenanceInterval is how often a file-backed collector enforces
+// segment age, retention and the disk quota when no entries arrive.
+const logMaintenanceInterval = time.Minute
+
 // LogCollector collects log entries from modules implementing LogEmitter
 // and exposes them via a /logs HTTP endpoint.
 type LogCollector struct {
 	name   string
 	config LogCollectorConfig
 	app    modular.Application
+	store  logStore
 
-	mu      sync.RWMutex
-	entries []LogEntry
-	maxSize int
+	stopMaintenance context.CancelFunc
+	goroutines      GoroutineCounter
 }
 
 // NewLogCollector creates a new LogCollector module.
@@ -56,12 +79,14 @@ func NewLogCollector(name string, cfg LogCollectorConfig) *LogCollector {
 		cfg.RetentionDays = 7
 	}
 
-	return &LogCollector{
-		name:    name,
-		config:  cfg,
-		entries: make([]LogEntry, 0, 1024),
-		maxSize: 10000,
+	lc := &LogCollector{name: name, config: cfg}
+	if cfg.Storage == LogStorageFile {
+		lc.store = newFileLogStore(cfg)
+	} else {
+		lc.config.Storage = LogStorageMemory
+		lc.store = newMemoryLogStore(cfg.MaxEntries)
 	}
+	return lc
 }
 
 // Name returns the module name.
@@ -69,12 +94,59 @@ func (lc *LogCollector) Name() string {
 	return lc.name
 }
 
-// Init registers the log collector as a service.
+// Init registers the log collector as a service. A file-backed collector
+// also opens its directory, loading the segments of earlier runs.
 func (lc *LogCollector) Init(app modular.Application) error {
 	lc.app = app
+	if fs, ok := lc.store.(*fileLogStore); ok {
+		if err := fs.Maintain(); err != nil {
+			return err
+		}
+	}
 	return app.RegisterService("log.collector", lc)
 }
 
+// Start begins the periodic maintenance of a file-backed collector.
+func (lc *LogCollector) Start(_ context.Context) error {
+	fs, ok := lc.store.(*fileLogStore)
+	if !ok {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.stopMaintenance = cancel
+	lc.goroutines.Go(func() {
+		ticker := time.NewTicker(logMaintenanceInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := fs.Maintain(); err != nil {
+					lc.logError("log collector maintenance failed", err)
+				}
+			}
+		}
+	})
+	return nil
+}
+
+// Stop ends maintenance and closes the active segment of a file-backed
+// collector.
+func (lc *LogCollector) Stop(_ context.Context) error {
+	if lc.stopMaintenance != nil {
+		lc.stopMaintenance()
+		lc.stopMaintenance = nil
+	}
+	return lc.store.Close()
+}
+
+func (lc *LogCollector) logError(msg string, err error) {
+	if lc.app != nil && lc.app.Logger() != nil {
+		lc.app.Logger().Error(msg, "module", lc.name, "error", err)
+	}
+}
+
 // CollectFromEmitters scans the service registry for LogEmitter services
 // and drains their log entries.
 func (lc *LogCollector) CollectFromEmitters() {
@@ -91,30 +163,21 @@ func (lc *LogCollector) CollectFromEmitters() {
 
 // AddEntry adds a single log entry to the collector.
 func (lc *LogCollector) AddEntry(entry LogEntry) {
-	lc.mu.Lock()
-	defer lc.mu.Unlock()
-
-	if !lc.shouldLog(entry.Level) {
-		return
-	}
-
-	lc.entries = append(lc.entries, entry)
-	if len(lc.entries) > lc.maxSize {
-		lc.entries = lc.entries[len(lc.entries)-lc.maxSize:]
-	}
+	lc.addEntries([]LogEntry{entry})
 }
 
 func (lc *LogCollector) addEntries(entries []LogEntry) {
-	lc.mu.Lock()
-	defer lc.mu.Unlock()
-
+	kept := make([]LogEntry, 0, len(entries))
 	for _, entry := range entries {
 		if lc.shouldLog(entry.Level) {
-			lc.entries = append(lc.entries, entry)
+			kept = append(kept, entry)
 		}
 	}
-	if len(lc.entries) > lc.maxSize {
-		lc.entries = lc.entries[len(lc.entries)-lc.maxSize:]
+	if len(kept) == 0 {
+		return
+	}
+	if err := lc.store.Append(kept); err != nil {
+		lc.logError("log collector failed to store entries", err)
 	}
 }
 
@@ -133,50 +196,81 @@ func (lc *LogCollector) shouldLog(level string) bool {
 
 // Entries returns a copy of the current log entries.
 func (lc *LogCollector) Entries() []LogEntry {
-	lc.mu.RLock()
-	defer lc.mu.RUnlock()
-	out := make([]LogEntry, len(lc.entries))
-	copy(out, lc.entries)
-	return out
+	entries, err := lc.store.Query(LogQuery{})
+	if err != nil {
+		lc.logError("log collector query failed", err)
+	}
+	return entries
+}
+
+// Query returns the retained entries matching q, oldest first. Entries older
+// than RetentionDays are never returned.
+func (lc *LogCollector) Query(q LogQuery) ([]LogEntry, error) {
+	if cutoff := time.Now().AddDate(0, 0, -lc.config.RetentionDays); q.Since.Before(cutoff) {
+		q.Since = cutoff
+	}
+	return lc.store.Query(q)
+}
+
+// Stats returns the state of the collector's storage, including the number
+// of entries dropped to stay within its limits.
+func (lc *LogCollector) Stats() LogStoreStats {
+	return lc.store.Stats()
+}
+
+// ResourceUsage reports the collector's goroutines and, for memory storage,
+// the retained entries and their estimated size.
+func (lc *LogCollector) ResourceUsage() ResourceUsage {
+	usage := ResourceUsage{ResourceGoroutines: float64(lc.goroutines.Count())}
+	// The file store holds its lock while writing segments, so it is not
+	// asked; its entries are on disk rather than in memory anyway.
+	if lc.config.Storage == LogStorageMemory {
+		stats := lc.store.Stats()
+		usage[ResourceBufferedMessages] = float64(stats.Entries)
+		usage[ResourceMemoryEstimateBytes] = float64(stats.MemoryBytes)
+	}
+	return usage
 }
 
-// LogHandler returns an HTTP handler that serves collected logs.
+// LogHandler returns an HTTP handler that serves collected logs. The level,
+// module, executionId and requestId query parameters select entries by exact match,
+// since and until (RFC 3339) bound their timestamps, and limit keeps only
+// the most recent matches.
 func (lc *LogCollector) LogHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Collect from emitters on each request
 		lc.CollectFromEmitters()
 
-		entries := lc.Entries()
-
-		// Apply retention filter
-		cutoff := time.Now().AddDate(0, 0, -lc.config.RetentionDays)
-		filtered := make([]LogEntry, 0, len(entries))
-		for _, e := range entries {
-			if e.Timestamp.After(cutoff) {
-				filtered = append(filtered, e)
-			}
+		params := r.URL.Query()
+		q := LogQuery{
+			Level:       params.Get("level"),
+			Module:      params.Get("module"),
+			ExecutionID: params.Get("executionId"),
+			RequestID:   params.Get("requestId"),
 		}
-
-		// Optional level query filter
-		if levelFilter := r.URL.Query().Get("level"); levelFilter != "" {
-			var levelFiltered []LogEntry
-			for _, e := range filtered {
-				if e.Level == levelFilter {
-					levelFiltered = append(levelFiltered, e)
+		for key, dst := range map[string]*time.Time{"since": &q.Since, "until": &q.Until} {
+			if v := params.Get(key); v != "" {
+				t, err := time.Parse(time.RFC3339, v)
+				if err != nil {
+					http.Error(w, fmt.Sprintf("invalid %s: %v", key, err), http.StatusBadRequest)
+					return
 				}
+				*dst = t
 			}
-			filtered = levelFiltered
 		}
-
-		// Optional module query filter
-		if moduleFilter := r.URL.Query().Get("module"); moduleFilter != "" {
-			var moduleFiltered []LogEntry
-			for _, e := range filtered {
-				if e.Module == moduleFilter {
-					moduleFiltered = append(moduleFiltered, e)
-				}
+		if v := params.Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				http.Error(w, "invalid limit", http.StatusBadRequest)
+				return
 			}
-			filtered = moduleFiltered
+			q.Limit = n
+		}
+
+		filtered, err := lc.Query(q)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
 		}
 
 		w.Header().Set("Content-Type", "application/json")
@@ -187,7 +281,9 @@ func (lc *LogCollector) LogHandler() http.HandlerFunc {
 				"logLevel":      lc.config.LogLevel,
 				"outputFormat":  lc.config.OutputFormat,
 				"retentionDays": lc.config.RetentionDays,
+				"storage":       lc.config.Storage,
 			},
+			"stats": lc.Stats(),
 		}
 		_ = json.NewEncoder(w).Encode(resp)
 	}
@@ -197,7 +293,7 @@ func (lc *LogCollector) LogHandler() http.HandlerFunc {
 // logs from emitters. Call the returned cancel function to stop.
 func (lc *LogCollector) StartCollectionLoop(ctx context.Context, interval time.Duration) context.CancelFunc {
 	ctx, cancel := context.WithCancel(ctx)
-	go func() {
+	lc.goroutines.Go(func() {
 		defer func() {
 			if rec := recover(); rec != nil {
 				fmt.Printf("panic in log collection goroutine: %v\n", rec)
@@ -213,7 +309,7 @@ func (lc *LogCollector) StartCollectionLoop(ctx context.Context, interval time.D
 				lc.CollectFromEmitters()
 			}
 		}
-	}()
+	})
 	return cancel
 }
 
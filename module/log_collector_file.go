@@ -0,0 +1,531 @@
+package module
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults of the file-backed log store.
+const (
+	defaultLogMaxSegmentBytes = 16 << 20
+	defaultLogMaxSegmentAge   = time.Hour
+	defaultLogDiskQuotaBytes  = 256 << 20
+
+	// maxIndexedExecutions caps the execution IDs indexed per segment; a
+	// segment with more is scanned for every execution ID query.
+	maxIndexedExecutions = 4096
+
+	logSegmentExt = ".jsonl"
+	logIndexExt   = ".idx"
+)
+
+// logSegmentIndex summarizes one segment so queries can skip segments that
+// hold no matching entry without reading them. It is stored next to the
+// segment when the segment is rotated out.
+type logSegmentIndex struct {
+	Count      int64           `json:"count"`
+	Bytes      int64           `json:"bytes"`
+	Created    time.Time       `json:"created"`
+	MinTime    time.Time       `json:"minTime"`
+	MaxTime    time.Time       `json:"maxTime"`
+	Levels     map[string]bool `json:"levels"`
+	Modules    map[string]bool `json:"modules"`
+	Executions map[string]bool `json:"executions,omitempty"`
+	// AllExecutions is set once the segment holds more than
+	// maxIndexedExecutions execution IDs and Executions is dropped.
+	AllExecutions bool            `json:"allExecutions,omitempty"`
+	Requests      map[string]bool `json:"requests,omitempty"`
+	// AllRequests is set once the segment holds more than
+	// maxIndexedExecutions request IDs and Requests is dropped.
+	AllRequests bool `json:"allRequests,omitempty"`
+}
+
+func newLogSegmentIndex(created time.Time) logSegmentIndex {
+	return logSegmentIndex{
+		Created:    created,
+		Levels:     make(map[string]bool),
+		Modules:    make(map[string]bool),
+		Executions: make(map[string]bool),
+		Requests:   make(map[string]bool),
+	}
+}
+
+// add records e, encoded in n bytes.
+func (x *logSegmentIndex) add(e LogEntry, n int) {
+	if x.Count == 0 || e.Timestamp.Before(x.MinTime) {
+		x.MinTime = e.Timestamp
+	}
+	if x.Count == 0 || e.Timestamp.After(x.MaxTime) {
+		x.MaxTime = e.Timestamp
+	}
+	x.Count++
+	x.Bytes += int64(n)
+	x.Levels[e.Level] = true
+	x.Modules[e.Module] = true
+	if e.ExecutionID != "" && !x.AllExecutions {
+		x.Executions[e.ExecutionID] = true
+		if len(x.Executions) > maxIndexedExecutions {
+			x.Executions = nil
+			x.AllExecutions = true
+		}
+	}
+	if e.RequestID != "" && !x.AllRequests {
+		x.Requests[e.RequestID] = true
+		if len(x.Requests) > maxIndexedExecutions {
+			x.Requests = nil
+			x.AllRequests = true
+		}
+	}
+}
+
+// mayMatch reports whether the segment can hold an entry matching q.
+func (x *logSegmentIndex) mayMatch(q LogQuery) bool {
+	switch {
+	case x.Count == 0:
+		return false
+	case q.Level != "" && !x.Levels[q.Level]:
+		return false
+	case q.Module != "" && !x.Modules[q.Module]:
+		return false
+	case q.ExecutionID != "" && !x.AllExecutions && !x.Executions[q.ExecutionID]:
+		return false
+	case q.RequestID != "" && !x.AllRequests && !x.Requests[q.RequestID]:
+		return false
+	case !q.Since.IsZero() && !x.MaxTime.After(q.Since):
+		return false
+	case !q.Until.IsZero() && x.MinTime.After(q.Until):
+		return false
+	}
+	return true
+}
+
+type logSegment struct {
+	name string // file name of the segment in the store directory
+	logSegmentIndex
+}
+
+// fileLogStore persists entries as JSONL segment files in a directory. The
+// active segment is rotated once it reaches maxSegmentBytes,
+// maxSegmentEntries or maxSegmentAge. Rotated segments older than the
+// retention are deleted, and the oldest segments are evicted while the store
+// exceeds its disk quota. The directory is opened on first use.
+type fileLogStore struct {
+	dir               string
+	maxSegmentBytes   int64
+	maxSegmentEntries int64
+	maxSegmentAge     time.Duration
+	diskQuotaBytes    int64
+	retention         time.Duration
+	now               func() time.Time
+
+	mu        sync.Mutex
+	opened    bool
+	segments  []*logSegment // oldest first; the last is active while active is set
+	active    *os.File
+	seq       int
+	dropped   int64
+	expired   int64
+	rotations int64
+}
+
+func newFileLogStore(cfg LogCollectorConfig) *fileLogStore {
+	s := &fileLogStore{
+		dir:               cfg.Directory,
+		maxSegmentBytes:   cfg.MaxSegmentBytes,
+		maxSegmentEntries: int64(cfg.MaxSegmentEntries),
+		maxSegmentAge:     cfg.MaxSegmentAge,
+		diskQuotaBytes:    cfg.DiskQuotaBytes,
+		retention:         time.Duration(cfg.RetentionDays) * 24 * time.Hour,
+		now:               time.Now,
+	}
+	if s.maxSegmentAge == 0 {
+		s.maxSegmentAge = defaultLogMaxSegmentAge
+	}
+	if s.diskQuotaBytes == 0 {
+		s.diskQuotaBytes = defaultLogDiskQuotaBytes
+	}
+	if s.maxSegmentBytes == 0 {
+		s.maxSegmentBytes = defaultLogMaxSegmentBytes
+	}
+	// Keep several segments within the quota so eviction frees space in
+	// steps rather than deleting everything.
+	if s.diskQuotaBytes > 0 && (s.maxSegmentBytes <= 0 || s.maxSegmentBytes > s.diskQuotaBytes/4) {
+		s.maxSegmentBytes = max(s.diskQuotaBytes/4, 1)
+	}
+	return s
+}
+
+// openLocked loads the segments already in the directory. The last one is
+// not reopened; the next Append starts a new segment.
+func (s *fileLogStore) openLocked() error {
+	if s.opened {
+		return nil
+	}
+	if s.dir == "" {
+		return errors.New("log collector: file storage requires a directory")
+	}
+	if err := os.MkdirAll(s.dir, 0o750); err != nil {
+		return fmt.Errorf("log collector: %w", err)
+	}
+	names, err := filepath.Glob(filepath.Join(s.dir, "*"+logSegmentExt))
+	if err != nil {
+		return fmt.Errorf("log collector: %w", err)
+	}
+	sort.Strings(names)
+	s.segments = s.segments[:0]
+	for _, path := range names {
+		seg, err := loadLogSegment(path)
+		if err != nil {
+			return fmt.Errorf("log collector: load segment %s: %w", filepath.Base(path), err)
+		}
+		s.segments = append(s.segments, seg)
+	}
+	s.opened = true
+	s.enforceLimitsLocked()
+	return nil
+}
+
+// loadLogSegment reads the index of the segment at path, rebuilding it from
+// the segment when it is missing or stale.
+func loadLogSegment(path string) (*logSegment, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	seg := &logSegment{name: filepath.Base(path)}
+	idxPath := strings.TrimSuffix(path, logSegmentExt) + logIndexExt
+	if data, err := os.ReadFile(idxPath); err == nil {
+		if json.Unmarshal(data, &seg.logSegmentIndex) == nil && seg.Bytes == info.Size() && seg.Levels != nil && seg.Modules != nil {
+			if seg.Executions == nil && !seg.AllExecutions {
+				seg.Executions = make(map[string]bool)
+			}
+			if seg.Requests == nil && !seg.AllRequests {
+				seg.Requests = make(map[string]bool)
+			}
+			return seg, nil
+		}
+	}
+
+	seg.logSegmentIndex = newLogSegmentIndex(info.ModTime())
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	err = scanLogSegment(f, func(e LogEntry, n int) bool {
+		seg.add(e, n)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Lines that failed to decode, such as one cut short by a crash, are
+	// not indexed but still take up disk space.
+	seg.Bytes = info.Size()
+	return seg, writeLogSegmentIndex(idxPath, &seg.logSegmentIndex)
+}
+
+// scanLogSegment decodes the entries of r, calling fn with each entry and
+// the size of its line until fn returns false. Lines that do not decode are
+// skipped.
+func scanLogSegment(r io.Reader, fn func(e LogEntry, n int) bool) error {
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			var e LogEntry
+			if json.Unmarshal(line, &e) == nil && !fn(e, len(line)) {
+				return nil
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func writeLogSegmentIndex(path string, x *logSegmentIndex) error {
+	data, err := json.Marshal(x)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (s *fileLogStore) segmentPath(seg *logSegment) string {
+	return filepath.Join(s.dir, seg.name)
+}
+
+func (s *fileLogStore) indexPath(seg *logSegment) string {
+	return filepath.Join(s.dir, strings.TrimSuffix(seg.name, logSegmentExt)+logIndexExt)
+}
+
+// rotateLocked closes the active segment, if any, and starts a new one.
+func (s *fileLogStore) rotateLocked() error {
+	if s.active != nil {
+		s.rotations++
+	}
+	if err := s.closeActiveLocked(); err != nil {
+		return err
+	}
+	now := s.now()
+	s.seq++
+	seg := &logSegment{
+		name:            fmt.Sprintf("%020d-%06d%s", now.UnixNano(), s.seq, logSegmentExt),
+		logSegmentIndex: newLogSegmentIndex(now),
+	}
+	f, err := os.OpenFile(s.segmentPath(seg), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("log collector: %w", err)
+	}
+	s.active = f
+	s.segments = append(s.segments, seg)
+	return nil
+}
+
+// closeActiveLocked closes the active segment and writes its index.
+func (s *fileLogStore) closeActiveLocked() error {
+	if s.active == nil {
+		return nil
+	}
+	seg := s.segments[len(s.segments)-1]
+	err := s.active.Close()
+	s.active = nil
+	if idxErr := writeLogSegmentIndex(s.indexPath(seg), &seg.logSegmentIndex); err == nil {
+		err = idxErr
+	}
+	if err != nil {
+		return fmt.Errorf("log collector: close segment %s: %w", seg.name, err)
+	}
+	return nil
+}
+
+// needsRotationLocked reports whether the active segment must be rotated
+// before a line of n bytes is added to it.
+func (s *fileLogStore) needsRotationLocked(n int) bool {
+	if s.active == nil {
+		return true
+	}
+	seg := s.segments[len(s.segments)-1]
+	if seg.Count == 0 {
+		return false
+	}
+	return (s.maxSegmentBytes > 0 && seg.Bytes+int64(n) > s.maxSegmentBytes) ||
+		(s.maxSegmentEntries > 0 && seg.Count >= s.maxSegmentEntries) ||
+		(s.maxSegmentAge > 0 && s.now().Sub(seg.Created) >= s.maxSegmentAge)
+}
+
+func (s *fileLogStore) Append(entries []LogEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.openLocked(); err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	flush := func() error {
+		if buf.Len() == 0 {
+			return nil
+		}
+		_, err := s.active.Write(buf.Bytes())
+		buf.Reset()
+		if err != nil {
+			return fmt.Errorf("log collector: %w", err)
+		}
+		return nil
+	}
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("log collector: %w", err)
+		}
+		line = append(line, '\n')
+		if s.needsRotationLocked(len(line)) {
+			if err := flush(); err != nil {
+				return err
+			}
+			if err := s.rotateLocked(); err != nil {
+				return err
+			}
+		}
+		buf.Write(line)
+		s.segments[len(s.segments)-1].add(e, len(line))
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	s.enforceLimitsLocked()
+	return nil
+}
+
+// Maintain rotates an active segment that reached maxSegmentAge without new
+// writes and deletes segments past the retention or the disk quota.
+func (s *fileLogStore) Maintain() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.openLocked(); err != nil {
+		return err
+	}
+	if s.active != nil && s.maxSegmentAge > 0 {
+		if seg := s.segments[len(s.segments)-1]; seg.Count > 0 && s.now().Sub(seg.Created) >= s.maxSegmentAge {
+			s.rotations++
+			if err := s.closeActiveLocked(); err != nil {
+				return err
+			}
+		}
+	}
+	s.enforceLimitsLocked()
+	return nil
+}
+
+// enforceLimitsLocked deletes rotated segments whose entries are all older
+// than the retention, then the oldest rotated segments while the store
+// exceeds its disk quota. The active segment is never deleted.
+func (s *fileLogStore) enforceLimitsLocked() {
+	closed := len(s.segments)
+	if s.active != nil {
+		closed--
+	}
+	if s.retention > 0 {
+		cutoff := s.now().Add(-s.retention)
+		kept := s.segments[:0]
+		for i, seg := range s.segments {
+			if i < closed && seg.MaxTime.Before(cutoff) && s.removeSegmentLocked(seg) {
+				s.expired += seg.Count
+				continue
+			}
+			kept = append(kept, seg)
+		}
+		closed -= len(s.segments) - len(kept)
+		s.segments = kept
+	}
+	if s.diskQuotaBytes > 0 {
+		var total int64
+		for _, seg := range s.segments {
+			total += seg.Bytes
+		}
+		for closed > 0 && total > s.diskQuotaBytes {
+			seg := s.segments[0]
+			if !s.removeSegmentLocked(seg) {
+				break
+			}
+			total -= seg.Bytes
+			s.dropped += seg.Count
+			s.segments = s.segments[1:]
+			closed--
+		}
+	}
+}
+
+// removeSegmentLocked deletes a segment and its index, reporting whether the
+// segment is gone.
+func (s *fileLogStore) removeSegmentLocked(seg *logSegment) bool {
+	if err := os.Remove(s.segmentPath(seg)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false
+	}
+	_ = os.Remove(s.indexPath(seg))
+	return true
+}
+
+func (s *fileLogStore) Query(q LogQuery) ([]LogEntry, error) {
+	type candidate struct {
+		path string
+		size int64
+	}
+	s.mu.Lock()
+	if err := s.openLocked(); err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	var candidates []candidate
+	for _, seg := range s.segments {
+		if seg.mayMatch(q) {
+			candidates = append(candidates, candidate{path: s.segmentPath(seg), size: seg.Bytes})
+		}
+	}
+	s.mu.Unlock()
+
+	// Read newest first so a limited query stops early. Each segment is read
+	// only up to its indexed size, so lines being appended concurrently are
+	// not seen half written. A segment evicted since the snapshot is skipped.
+	var found [][]LogEntry
+	total := 0
+	for i := len(candidates) - 1; i >= 0; i-- {
+		if q.Limit > 0 && total >= q.Limit {
+			break
+		}
+		f, err := os.Open(candidates[i].path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("log collector: %w", err)
+		}
+		var matched []LogEntry
+		err = scanLogSegment(io.LimitReader(f, candidates[i].size), func(e LogEntry, _ int) bool {
+			if q.Matches(e) {
+				matched = append(matched, e)
+			}
+			return true
+		})
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("log collector: %w", err)
+		}
+		found = append(found, matched)
+		total += len(matched)
+	}
+
+	out := make([]LogEntry, 0, total)
+	for i := len(found) - 1; i >= 0; i-- {
+		out = append(out, found[i]...)
+	}
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[len(out)-q.Limit:]
+	}
+	return out, nil
+}
+
+func (s *fileLogStore) Stats() LogStoreStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := LogStoreStats{
+		Storage:   LogStorageFile,
+		Dropped:   s.dropped,
+		Expired:   s.expired,
+		Segments:  len(s.segments),
+		Rotations: s.rotations,
+	}
+	for _, seg := range s.segments {
+		st.Entries += seg.Count
+		st.DiskBytes += seg.Bytes
+	}
+	return st
+}
+
+// Close closes the active segment. A later Append starts a new one.
+func (s *fileLogStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closeActiveLocked()
+}
@@ -0,0 +1,142 @@
+package module
+
+import (
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// Log collector storage modes.
+const (
+	LogStorageMemory = "memory"
+	LogStorageFile   = "file"
+)
+
+// defaultLogMaxEntries is the in-memory entry cap when maxEntries is unset.
+const defaultLogMaxEntries = 10000
+
+// LogQuery selects log entries. Empty fields match every entry. Limit keeps
+// only the most recent matches; 0 means no limit.
+type LogQuery struct {
+	Level       string
+	Module      string
+	ExecutionID string
+	RequestID   string
+	Since       time.Time
+	Until       time.Time
+	Limit       int
+}
+
+// Matches reports whether e is selected by q.
+func (q LogQuery) Matches(e LogEntry) bool {
+	if q.Level != "" && e.Level != q.Level {
+		return false
+	}
+	if q.Module != "" && e.Module != q.Module {
+		return false
+	}
+	if q.ExecutionID != "" && e.ExecutionID != q.ExecutionID {
+		return false
+	}
+	if q.RequestID != "" && e.RequestID != q.RequestID {
+		return false
+	}
+	if !q.Since.IsZero() && !e.Timestamp.After(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && e.Timestamp.After(q.Until) {
+		return false
+	}
+	return true
+}
+
+// LogStoreStats describes the state of a log collector's storage.
+type LogStoreStats struct {
+	Storage string `json:"storage"`
+	// Entries is the number of entries currently retained.
+	Entries int64 `json:"entries"`
+	// Dropped counts entries discarded to stay within maxEntries or the
+	// disk quota.
+	Dropped int64 `json:"dropped"`
+	// Expired counts entries deleted because they exceeded retentionDays.
+	Expired int64 `json:"expired"`
+	// MemoryBytes estimates the memory held by retained entries of a
+	// memory store.
+	MemoryBytes int64 `json:"memoryBytes,omitempty"`
+	Segments    int   `json:"segments,omitempty"`
+	DiskBytes   int64 `json:"diskBytes,omitempty"`
+	Rotations   int64 `json:"rotations,omitempty"`
+}
+
+// logStore holds the entries of a LogCollector. Implementations are safe for
+// concurrent use.
+type logStore interface {
+	Append(entries []LogEntry) error
+	// Query returns the entries matching q in timestamp order.
+	Query(q LogQuery) ([]LogEntry, error)
+	Stats() LogStoreStats
+	Close() error
+}
+
+// memoryLogStore keeps the most recent maxEntries entries in a ring buffer,
+// dropping the oldest entry for every entry added beyond the cap.
+type memoryLogStore struct {
+	mu      sync.Mutex
+	max     int
+	ring    []LogEntry // grows up to max, then wraps at start
+	start   int
+	dropped int64
+	bytes   int64 // estimated size of ring, see logEntrySize
+}
+
+func newMemoryLogStore(maxEntries int) *memoryLogStore {
+	if maxEntries <= 0 {
+		maxEntries = defaultLogMaxEntries
+	}
+	return &memoryLogStore{max: maxEntries}
+}
+
+func (s *memoryLogStore) Append(entries []LogEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range entries {
+		s.bytes += logEntrySize(e)
+		if len(s.ring) < s.max {
+			s.ring = append(s.ring, e)
+			continue
+		}
+		s.bytes -= logEntrySize(s.ring[s.start])
+		s.ring[s.start] = e
+		s.start = (s.start + 1) % len(s.ring)
+		s.dropped++
+	}
+	return nil
+}
+
+func (s *memoryLogStore) Query(q LogQuery) ([]LogEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]LogEntry, 0)
+	for i := range s.ring {
+		if e := s.ring[(s.start+i)%len(s.ring)]; q.Matches(e) {
+			out = append(out, e)
+		}
+	}
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[len(out)-q.Limit:]
+	}
+	return out, nil
+}
+
+func (s *memoryLogStore) Stats() LogStoreStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return LogStoreStats{Storage: LogStorageMemory, Entries: int64(len(s.ring)), Dropped: s.dropped, MemoryBytes: s.bytes}
+}
+
+// logEntrySize estimates the bytes e holds: the struct plus its strings.
+func logEntrySize(e LogEntry) int64 {
+	return int64(unsafe.Sizeof(e)) + int64(len(e.Module)+len(e.Level)+len(e.Message)+len(e.ExecutionID)+len(e.RequestID))
+}
+
+func (s *memoryLogStore) Close() error { return nil }
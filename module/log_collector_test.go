@@ -0,0 +1,307 @@
+package module
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func logEntryAt(ts time.Time, level, module, execID string, i int) LogEntry {
+	return LogEntry{Timestamp: ts, Level: level, Module: module, ExecutionID: execID, Message: fmt.Sprintf("message %d", i)}
+}
+
+func TestLogCollectorMemoryCapDropsOldest(t *testing.T) {
+	lc := NewLogCollector("logs", LogCollectorConfig{MaxEntries: 3})
+	now := time.Now()
+	for i := range 5 {
+		lc.AddEntry(logEntryAt(now, "info", "m", "", i))
+	}
+
+	entries := lc.Entries()
+	if len(entries) != 3 || entries[0].Message != "message 2" || entries[2].Message != "message 4" {
+		t.Fatalf("entries = %+v, want messages 2..4", entries)
+	}
+	if st := lc.Stats(); st.Storage != LogStorageMemory || st.Entries != 3 || st.Dropped != 2 {
+		t.Errorf("stats = %+v, want 3 entries and 2 dropped", st)
+	}
+}
+
+func TestFileLogStoreRotationAndQuery(t *testing.T) {
+	dir := t.TempDir()
+	lc := NewLogCollector("logs", LogCollectorConfig{
+		LogLevel:          "debug",
+		Storage:           LogStorageFile,
+		Directory:         dir,
+		MaxSegmentEntries: 4,
+		DiskQuotaBytes:    -1,
+	})
+	base := time.Now().Add(-time.Hour)
+	var batch []LogEntry
+	for i := range 10 {
+		level, exec := "info", "exec-a"
+		if i%3 == 0 {
+			level, exec = "error", "exec-b"
+		}
+		batch = append(batch, logEntryAt(base.Add(time.Duration(i)*time.Minute), level, fmt.Sprintf("mod-%d", i%2), exec, i))
+	}
+	lc.addEntries(batch)
+
+	st := lc.Stats()
+	if st.Storage != LogStorageFile || st.Segments != 3 || st.Entries != 10 || st.Rotations != 2 {
+		t.Fatalf("stats = %+v, want 3 segments holding 10 entries", st)
+	}
+
+	tests := []struct {
+		name string
+		q    LogQuery
+		want []string
+	}{
+		{"level", LogQuery{Level: "error"}, []string{"message 0", "message 3", "message 6", "message 9"}},
+		{"module and execution", LogQuery{Module: "mod-1", ExecutionID: "exec-a"}, []string{"message 1", "message 5", "message 7"}},
+		{"time range", LogQuery{Since: base.Add(4 * time.Minute), Until: base.Add(6 * time.Minute)}, []string{"message 5", "message 6"}},
+		{"limit keeps newest", LogQuery{Limit: 2}, []string{"message 8", "message 9"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := lc.Query(tt.q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var msgs []string
+			for _, e := range got {
+				msgs = append(msgs, e.Message)
+			}
+			if fmt.Sprint(msgs) != fmt.Sprint(tt.want) {
+				t.Errorf("messages = %v, want %v", msgs, tt.want)
+			}
+		})
+	}
+
+	// Only the segment holding messages 4..7 can match this range.
+	fs := lc.store.(*fileLogStore)
+	matching := 0
+	for _, seg := range fs.segments {
+		if seg.mayMatch(LogQuery{Since: base.Add(4 * time.Minute), Until: base.Add(6 * time.Minute)}) {
+			matching++
+		}
+	}
+	if matching != 1 {
+		t.Errorf("%d segments may match the time range, want 1", matching)
+	}
+}
+
+func TestFileLogStoreReopen(t *testing.T) {
+	dir := t.TempDir()
+	cfg := LogCollectorConfig{Storage: LogStorageFile, Directory: dir, MaxSegmentEntries: 2}
+	lc := NewLogCollector("logs", cfg)
+	now := time.Now()
+	for i := range 5 {
+		lc.AddEntry(logEntryAt(now, "info", "m", "", i))
+	}
+	if err := lc.Stop(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	// A missing index is rebuilt from its segment.
+	idx, _ := filepath.Glob(filepath.Join(dir, "*"+logIndexExt))
+	if len(idx) != 3 {
+		t.Fatalf("found %d index files, want 3", len(idx))
+	}
+	if err := os.Remove(idx[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened := NewLogCollector("logs", cfg)
+	reopened.AddEntry(logEntryAt(now, "info", "m", "", 5))
+	entries := reopened.Entries()
+	if len(entries) != 6 || entries[0].Message != "message 0" || entries[5].Message != "message 5" {
+		t.Fatalf("entries after reopen = %+v", entries)
+	}
+	if st := reopened.Stats(); st.Segments != 4 {
+		t.Errorf("segments = %d, want 4", st.Segments)
+	}
+}
+
+func TestFileLogStoreQuotaAndRetention(t *testing.T) {
+	dir := t.TempDir()
+	lc := NewLogCollector("logs", LogCollectorConfig{
+		Storage:           LogStorageFile,
+		Directory:         dir,
+		MaxSegmentEntries: 10,
+		RetentionDays:     1,
+	})
+	fs := lc.store.(*fileLogStore)
+	now := time.Now()
+	fs.now = func() time.Time { return now }
+
+	line, _ := json.Marshal(logEntryAt(now, "info", "m", "", 0))
+	fs.diskQuotaBytes = int64(len(line)+1) * 25
+	for i := range 50 {
+		lc.AddEntry(logEntryAt(now, "info", "m", "", i))
+	}
+	st := lc.Stats()
+	if st.DiskBytes > fs.diskQuotaBytes || st.Dropped != 30 || st.Entries != 20 {
+		t.Fatalf("stats after quota = %+v, want 20 entries within %d bytes and 30 dropped", st, fs.diskQuotaBytes)
+	}
+	entries := lc.Entries()
+	if len(entries) != 20 || entries[0].Message != "message 30" {
+		t.Fatalf("oldest entry after eviction = %+v", entries[0])
+	}
+
+	// Two days later every rotated segment is past the retention; maintenance
+	// rotates the idle active segment and deletes them all.
+	fs.now = func() time.Time { return now.Add(48 * time.Hour) }
+	fs.maxSegmentAge = time.Hour
+	if err := fs.Maintain(); err != nil {
+		t.Fatal(err)
+	}
+	if st := lc.Stats(); st.Segments != 0 || st.Expired != 20 {
+		t.Fatalf("stats after retention = %+v, want no segments and 20 expired", st)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*")); len(files) != 0 {
+		t.Errorf("files left after retention: %v", files)
+	}
+}
+
+func TestFileLogStoreConcurrentWritersAndRotation(t *testing.T) {
+	lc := NewLogCollector("logs", LogCollectorConfig{
+		Storage:         LogStorageFile,
+		Directory:       t.TempDir(),
+		MaxSegmentBytes: 2048,
+		DiskQuotaBytes:  -1,
+	})
+	const writers, perWriter = 8, 200
+	now := time.Now()
+
+	var wg sync.WaitGroup
+	for w := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range perWriter {
+				lc.AddEntry(logEntryAt(now, "info", fmt.Sprintf("writer-%d", w), "", i))
+			}
+		}()
+	}
+	stop := make(chan struct{})
+	readerDone := make(chan error)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				readerDone <- nil
+				return
+			default:
+			}
+			entries, err := lc.Query(LogQuery{Module: "writer-0"})
+			if err != nil {
+				readerDone <- err
+				return
+			}
+			// Each writer's entries are appended in order and never torn.
+			for i, e := range entries {
+				if e.Message != fmt.Sprintf("message %d", i) {
+					readerDone <- fmt.Errorf("entry %d of writer-0 is %q", i, e.Message)
+					return
+				}
+			}
+		}
+	}()
+	wg.Wait()
+	close(stop)
+	if err := <-readerDone; err != nil {
+		t.Fatal(err)
+	}
+
+	st := lc.Stats()
+	if st.Entries != writers*perWriter || st.Rotations == 0 {
+		t.Fatalf("stats = %+v, want %d entries across rotated segments", st, writers*perWriter)
+	}
+	if got := len(lc.Entries()); got != writers*perWriter {
+		t.Errorf("read back %d entries, want %d", got, writers*perWriter)
+	}
+}
+
+func TestLogHandlerContract(t *testing.T) {
+	lc := NewLogCollector("logs", LogCollectorConfig{MaxEntries: 100})
+	now := time.Now()
+	lc.AddEntry(logEntryAt(now.AddDate(0, 0, -30), "info", "m", "", 0))
+	for i := 1; i <= 3; i++ {
+		lc.AddEntry(logEntryAt(now, "info", "m", "exec-1", i))
+	}
+	lc.AddEntry(logEntryAt(now, "error", "other", "", 4))
+
+	get := func(query string) (int, map[string]any) {
+		rec := httptest.NewRecorder()
+		lc.LogHandler()(rec, httptest.NewRequest(http.MethodGet, "/logs"+query, nil))
+		var body map[string]any
+		_ = json.Unmarshal(rec.Body.Bytes(), &body)
+		return rec.Code, body
+	}
+
+	code, body := get("")
+	if code != http.StatusOK || body["count"] != float64(4) {
+		t.Fatalf("GET /logs = %d %v, want 4 entries within retention", code, body)
+	}
+	for _, key := range []string{"entries", "config", "stats"} {
+		if _, ok := body[key]; !ok {
+			t.Errorf("response lacks %q", key)
+		}
+	}
+	if _, body := get("?executionId=exec-1&limit=2"); body["count"] != float64(2) {
+		t.Errorf("executionId and limit: count = %v, want 2", body["count"])
+	}
+	if _, body := get("?level=error&module=other"); body["count"] != float64(1) {
+		t.Errorf("level and module: count = %v, want 1", body["count"])
+	}
+	if code, _ := get("?since=yesterday"); code != http.StatusBadRequest {
+		t.Errorf("invalid since: status = %d, want 400", code)
+	}
+}
+
+func TestFileLogStoreRequestIDIndex(t *testing.T) {
+	dir := t.TempDir()
+	cfg := LogCollectorConfig{Storage: LogStorageFile, Directory: dir, MaxSegmentEntries: 2, DiskQuotaBytes: -1}
+	lc := NewLogCollector("logs", cfg)
+	now := time.Now()
+	for i := range 6 {
+		e := logEntryAt(now, "info", "m", "", i)
+		if i == 4 {
+			e.RequestID = "req-4"
+		}
+		lc.AddEntry(e)
+	}
+	if err := lc.Stop(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	// The index survives a reopen and only one segment is read.
+	reopened := NewLogCollector("logs", cfg)
+	got, err := reopened.Query(LogQuery{RequestID: "req-4"})
+	if err != nil || len(got) != 1 || got[0].Message != "message 4" {
+		t.Fatalf("query = %+v, %v", got, err)
+	}
+	matching := 0
+	for _, seg := range reopened.store.(*fileLogStore).segments {
+		if seg.mayMatch(LogQuery{RequestID: "req-4"}) {
+			matching++
+		}
+	}
+	if matching != 1 {
+		t.Errorf("%d segments may match req-4, want 1", matching)
+	}
+
+	rec := httptest.NewRecorder()
+	reopened.LogHandler()(rec, httptest.NewRequest(http.MethodGet, "/logs?requestId=req-4", nil))
+	var body map[string]any
+	_ = json.Unmarshal(rec.Body.Bytes(), &body)
+	if body["count"] != float64(1) {
+		t.Errorf("GET /logs?requestId = %v", body)
+	}
+}
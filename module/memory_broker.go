@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/GoCodeAlone/modular"
@@ -25,6 +26,9 @@ type InMemoryMessageBroker struct {
 	logger          modular.Logger
 	maxQueueSize    int
 	deliveryTimeout time.Duration
+	// inFlight counts messages being delivered to subscribers.
+	inFlight atomic.Int64
+	topicSchemaGuard
 }
 
 // NewInMemoryMessageBroker creates a new in-memory message broker
@@ -123,6 +127,22 @@ func (b *InMemoryMessageBroker) DeliveryTimeout() time.Duration {
 	return b.deliveryTimeout
 }
 
+// ResourceUsage reports the subscribed topics and handlers, and the messages
+// being delivered. Delivery is synchronous, so there is no queue to report.
+func (b *InMemoryMessageBroker) ResourceUsage() ResourceUsage {
+	b.mu.RLock()
+	topics, handlers := len(b.subscriptions), 0
+	for _, hs := range b.subscriptions {
+		handlers += len(hs)
+	}
+	b.mu.RUnlock()
+	return ResourceUsage{
+		"topics":         float64(topics),
+		"subscriptions":  float64(handlers),
+		ResourceInFlight: float64(b.inFlight.Load()),
+	}
+}
+
 // ProvidesServices returns a list of services provided by this module
 func (b *InMemoryMessageBroker) ProvidesServices() []modular.ServiceProvider {
 	return []modular.ServiceProvider{
@@ -155,8 +175,14 @@ type inMemoryProducer struct {
 	broker *InMemoryMessageBroker
 }
 
-// SendMessage sends a message to a topic
+// SendMessage sends a message to a topic. Messages are validated against the
+// attached topic registry here only: delivery is in-process, so there is
+// nothing further to check before the handlers run.
 func (p *inMemoryProducer) SendMessage(topic string, message []byte) error {
+	if err := p.broker.checkPublish(topic, message); err != nil {
+		return err
+	}
+
 	p.broker.mu.RLock()
 	defer p.broker.mu.RUnlock()
 
@@ -172,6 +198,8 @@ func (p *inMemoryProducer) SendMessage(topic string, message []byte) error {
 	}
 
 	// Deliver message to all subscribers
+	p.broker.inFlight.Add(1)
+	defer p.broker.inFlight.Add(-1)
 	for _, handler := range handlers {
 		if err := handler.HandleMessage(message); err != nil {
 			p.broker.logger.Error("Error handling message on", "topic", topic, "error", err)
@@ -1,7 +1,11 @@
 package module
 
 import (
+	"fmt"
+	"slices"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/GoCodeAlone/modular"
@@ -13,7 +17,7 @@ import (
 type MetricsCollectorConfig struct {
 	Namespace      string   `yaml:"namespace" json:"namespace" editor:"type=string,description=Prometheus metric namespace prefix,default=workflow,placeholder=workflow"`
 	Subsystem      string   `yaml:"subsystem" json:"subsystem" editor:"type=string,description=Prometheus metric subsystem,placeholder=api"`
-	EnabledMetrics []string `yaml:"enabledMetrics" json:"enabledMetrics" editor:"type=array,arrayItemType=string,description=Which metric groups to register (workflow http module active_workflows)"`
+	EnabledMetrics []string `yaml:"enabledMetrics" json:"enabledMetrics" editor:"type=array,arrayItemType=string,description=Which metric groups to register (workflow http module active_workflows module_resources)"`
 }
 
 // DefaultMetricsCollectorConfig returns the default configuration.
@@ -21,7 +25,7 @@ func DefaultMetricsCollectorConfig() MetricsCollectorConfig {
 	return MetricsCollectorConfig{
 		Namespace:      "workflow",
 		Subsystem:      "",
-		EnabledMetrics: []string{"workflow", "http", "module", "active_workflows"},
+		EnabledMetrics: []string{"workflow", "http", "module", "active_workflows", "module_resources"},
 	}
 }
 
@@ -47,6 +51,51 @@ type MetricsCollector struct {
 	HTTPRequestDuration *prometheus.HistogramVec
 	ModuleOperations    *prometheus.CounterVec
 	ActiveWorkflows     *prometheus.GaugeVec
+
+	customMu sync.Mutex
+	custom   map[string]*customMetric
+
+	// resourceUsage supplies the module_resource gauge; see
+	// SetResourceUsageFunc.
+	resourceMu    sync.RWMutex
+	resourceUsage func() map[string]ResourceUsage
+}
+
+// Custom metric kinds accepted by RecordCustomMetric.
+const (
+	CustomMetricCounter   = "counter"
+	CustomMetricGauge     = "gauge"
+	CustomMetricHistogram = "histogram"
+)
+
+// CustomMetricOverflowValue replaces every label value once a custom metric
+// has reached its cardinality limit.
+const CustomMetricOverflowValue = "_overflow"
+
+// defaultCustomMetricCardinality bounds the number of distinct label sets a
+// custom metric may create when the spec does not set MaxCardinality.
+const defaultCustomMetricCardinality = 100
+
+// CustomMetricSpec describes a pipeline-defined metric. Metrics are registered
+// lazily on first use; later calls must use the same kind and label names.
+type CustomMetricSpec struct {
+	Name           string
+	Help           string
+	Kind           string // counter, gauge, or histogram
+	Labels         []string
+	Buckets        []float64 // histogram only; defaults to prometheus.DefBuckets
+	MaxCardinality int       // distinct label sets before overflow; defaults to 100
+}
+
+// customMetric is a lazily registered metric vector plus its cardinality state.
+type customMetric struct {
+	kind           string
+	labels         []string
+	counter        *prometheus.CounterVec
+	gauge          *prometheus.GaugeVec
+	histogram      *prometheus.HistogramVec
+	maxCardinality int
+	seen           map[string]struct{}
 }
 
 // NewMetricsCollector creates a new MetricsCollector with its own Prometheus registry.
@@ -129,6 +178,10 @@ func NewMetricsCollectorWithConfig(name string, cfg MetricsCollectorConfig) *Met
 		reg.MustRegister(mc.ActiveWorkflows)
 	}
 
+	if metricsEnabled(enabled, "module_resources") {
+		reg.MustRegister(newModuleResourceMetrics(ns, sub, mc.moduleResourceUsage))
+	}
+
 	return mc
 }
 
@@ -137,16 +190,61 @@ func (m *MetricsCollector) Name() string {
 	return m.name
 }
 
-// Init registers the metrics collector as a service.
+// Init registers the metrics collector as a service. Until
+// SetResourceUsageFunc is called, module resources are read from the
+// application's modules that implement ResourceReporter.
 func (m *MetricsCollector) Init(app modular.Application) error {
+	m.resourceMu.Lock()
+	if m.resourceUsage == nil {
+		m.resourceUsage = func() map[string]ResourceUsage {
+			reporters := map[string]ResourceReporter{}
+			for name, mod := range app.GetAllModules() {
+				if r, ok := mod.(ResourceReporter); ok {
+					reporters[name] = r
+				}
+			}
+			return CollectResourceUsage(reporters)
+		}
+	}
+	m.resourceMu.Unlock()
 	return app.RegisterService("metrics.collector", m)
 }
 
+// SetResourceUsageFunc sets the source of the module_resource gauge. The
+// engine uses it to include workflow handlers alongside modules.
+func (m *MetricsCollector) SetResourceUsageFunc(fn func() map[string]ResourceUsage) {
+	m.resourceMu.Lock()
+	defer m.resourceMu.Unlock()
+	m.resourceUsage = fn
+}
+
+func (m *MetricsCollector) moduleResourceUsage() map[string]ResourceUsage {
+	m.resourceMu.RLock()
+	fn := m.resourceUsage
+	m.resourceMu.RUnlock()
+	if fn == nil {
+		return nil
+	}
+	return fn()
+}
+
 // Gather returns the current Prometheus metric families without exposing an HTTP endpoint.
 func (m *MetricsCollector) Gather() ([]*dto.MetricFamily, error) {
 	return m.registry.Gather()
 }
 
+// RegisterCollector registers a collector owned by another module, such as
+// the in-flight limiters of an HTTP server, with the collector's registry.
+func (m *MetricsCollector) RegisterCollector(c prometheus.Collector) error {
+	return m.registry.Register(c)
+}
+
+// Namespace returns the configured metric namespace and subsystem, for
+// collectors registered from outside this package.
+func (m *MetricsCollector) Namespace() (namespace, subsystem string) {
+	return m.config.Namespace, m.config.Subsystem
+}
+
 // RecordWorkflowExecution increments the workflow execution counter.
 func (m *MetricsCollector) RecordWorkflowExecution(workflowType, action, status string) {
 	if m.WorkflowExecutions != nil {
@@ -185,6 +283,131 @@ func (m *MetricsCollector) SetActiveWorkflows(workflowType string, count float64
 	}
 }
 
+// RecordCustomMetric records value against the custom metric described by spec,
+// registering the metric on first use. For counters the value is added; for
+// histograms it is observed; for gauges op selects set (default), inc, dec, or
+// add. When a new label set would exceed the metric's cardinality limit, all
+// label values are replaced with CustomMetricOverflowValue and overflowed is
+// returned as true.
+func (m *MetricsCollector) RecordCustomMetric(spec CustomMetricSpec, labelValues map[string]string, op string, value float64) (overflowed bool, err error) {
+	cm, err := m.customMetricFor(spec)
+	if err != nil {
+		return false, err
+	}
+
+	values := make([]string, len(cm.labels))
+	for i, l := range cm.labels {
+		values[i] = labelValues[l]
+	}
+
+	m.customMu.Lock()
+	key := strings.Join(values, "\xff")
+	if _, ok := cm.seen[key]; !ok {
+		if len(cm.seen) >= cm.maxCardinality {
+			overflowed = true
+			for i := range values {
+				values[i] = CustomMetricOverflowValue
+			}
+		} else {
+			cm.seen[key] = struct{}{}
+		}
+	}
+	m.customMu.Unlock()
+
+	switch cm.kind {
+	case CustomMetricCounter:
+		if value < 0 {
+			return overflowed, fmt.Errorf("metric %q: counter value must not be negative, got %v", spec.Name, value)
+		}
+		cm.counter.WithLabelValues(values...).Add(value)
+	case CustomMetricHistogram:
+		cm.histogram.WithLabelValues(values...).Observe(value)
+	case CustomMetricGauge:
+		g := cm.gauge.WithLabelValues(values...)
+		switch op {
+		case "", "set":
+			g.Set(value)
+		case "inc":
+			g.Inc()
+		case "dec":
+			g.Dec()
+		case "add":
+			g.Add(value)
+		default:
+			return overflowed, fmt.Errorf("metric %q: unsupported gauge operation %q", spec.Name, op)
+		}
+	}
+	return overflowed, nil
+}
+
+// customMetricFor returns the registered custom metric for spec, creating and
+// registering it if needed.
+func (m *MetricsCollector) customMetricFor(spec CustomMetricSpec) (*customMetric, error) {
+	m.customMu.Lock()
+	defer m.customMu.Unlock()
+
+	kind := spec.Kind
+	if kind == "" {
+		kind = CustomMetricCounter
+	}
+	if cm, ok := m.custom[spec.Name]; ok {
+		if cm.kind != kind || !slices.Equal(cm.labels, spec.Labels) {
+			return nil, fmt.Errorf("metric %q already registered as %s with labels %v", spec.Name, cm.kind, cm.labels)
+		}
+		return cm, nil
+	}
+
+	help := spec.Help
+	if help == "" {
+		help = "Custom pipeline metric " + spec.Name
+	}
+	maxCard := spec.MaxCardinality
+	if maxCard <= 0 {
+		maxCard = defaultCustomMetricCardinality
+	}
+	cm := &customMetric{
+		kind:           kind,
+		labels:         slices.Clone(spec.Labels),
+		maxCardinality: maxCard,
+		seen:           make(map[string]struct{}),
+	}
+
+	ns, sub := m.config.Namespace, m.config.Subsystem
+	var collector prometheus.Collector
+	switch kind {
+	case CustomMetricCounter:
+		cm.counter = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns, Subsystem: sub, Name: spec.Name, Help: help,
+		}, cm.labels)
+		collector = cm.counter
+	case CustomMetricGauge:
+		cm.gauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns, Subsystem: sub, Name: spec.Name, Help: help,
+		}, cm.labels)
+		collector = cm.gauge
+	case CustomMetricHistogram:
+		buckets := spec.Buckets
+		if len(buckets) == 0 {
+			buckets = prometheus.DefBuckets
+		}
+		cm.histogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: ns, Subsystem: sub, Name: spec.Name, Help: help, Buckets: buckets,
+		}, cm.labels)
+		collector = cm.histogram
+	default:
+		return nil, fmt.Errorf("metric %q: unsupported kind %q (expected counter, gauge, or histogram)", spec.Name, kind)
+	}
+
+	if err := m.registry.Register(collector); err != nil {
+		return nil, fmt.Errorf("metric %q: register: %w", spec.Name, err)
+	}
+	if m.custom == nil {
+		m.custom = make(map[string]*customMetric)
+	}
+	m.custom[spec.Name] = cm
+	return cm, nil
+}
+
 // ProvidesServices returns the services provided by this module.
 func (m *MetricsCollector) ProvidesServices() []modular.ServiceProvider {
 	return []modular.ServiceProvider{
@@ -22,6 +22,7 @@ type NATSBroker struct {
 	consumer      *natsConsumer
 	logger        modular.Logger
 	tlsCfg        tlsutil.TLSConfig
+	topicSchemaGuard
 }
 
 // NewNATSBroker creates a new NATS message broker.
@@ -126,12 +127,7 @@ func (b *NATSBroker) Start(ctx context.Context) error {
 
 	// Activate pending subscriptions
 	for topic, handler := range b.handlers {
-		h := handler // capture for closure
-		sub, subErr := conn.Subscribe(topic, func(msg *nats.Msg) {
-			if handleErr := h.HandleMessage(msg.Data); handleErr != nil {
-				b.logger.Error("Error handling NATS message", "topic", topic, "error", handleErr)
-			}
-		})
+		sub, subErr := conn.Subscribe(topic, b.natsHandler(topic, handler))
 		if subErr != nil {
 			return fmt.Errorf("failed to subscribe to topic %q: %w", topic, subErr)
 		}
@@ -163,6 +159,19 @@ func (b *NATSBroker) Stop(_ context.Context) error {
 	return nil
 }
 
+// natsHandler adapts a MessageHandler to a NATS subscription callback.
+// Messages that fail the schema of their subject do not reach the handler.
+func (b *NATSBroker) natsHandler(topic string, h MessageHandler) nats.MsgHandler {
+	return func(msg *nats.Msg) {
+		if !b.acceptDelivery(msg.Subject, msg.Data, b.name) {
+			return
+		}
+		if handleErr := h.HandleMessage(msg.Data); handleErr != nil {
+			b.logger.Error("Error handling NATS message", "topic", topic, "error", handleErr)
+		}
+	}
+}
+
 // natsProducer implements MessageProducer for NATS.
 type natsProducer struct {
 	broker *NATSBroker
@@ -177,6 +186,9 @@ func (p *natsProducer) SendMessage(topic string, message []byte) error {
 	if conn == nil {
 		return fmt.Errorf("NATS connection not established; call Start first")
 	}
+	if err := p.broker.checkPublish(topic, message); err != nil {
+		return err
+	}
 
 	if err := conn.Publish(topic, message); err != nil {
 		return fmt.Errorf("failed to publish to topic %q: %w", topic, err)
@@ -201,12 +213,7 @@ func (c *natsConsumer) Subscribe(topic string, handler MessageHandler) error {
 
 	// If already connected, subscribe immediately
 	if c.broker.conn != nil {
-		h := handler
-		sub, err := c.broker.conn.Subscribe(topic, func(msg *nats.Msg) {
-			if handleErr := h.HandleMessage(msg.Data); handleErr != nil {
-				c.broker.logger.Error("Error handling NATS message", "topic", topic, "error", handleErr)
-			}
-		})
+		sub, err := c.broker.conn.Subscribe(topic, c.broker.natsHandler(topic, handler))
 		if err != nil {
 			return fmt.Errorf("failed to subscribe to topic %q: %w", topic, err)
 		}
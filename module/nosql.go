@@ -15,6 +15,7 @@ type NoSQLStore interface {
 	Delete(ctx context.Context, key string) error
 
 	// Query returns all items that match the provided filter params.
-	// Supported params: "prefix" (string) — key prefix filter.
+	// Supported params: "prefix" (string) — key prefix filter. Backends with
+	// partition and sort keys accept further params; see DynamoDBNoSQL.Query.
 	Query(ctx context.Context, params map[string]any) ([]map[string]any, error)
 }
@@ -1,57 +1,132 @@
 package module
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow/internal/awssigv4"
 )
 
 // DynamoDBNoSQLConfig holds configuration for the nosql.dynamodb module.
 //
-// Full AWS DynamoDB implementation would use:
-//   - github.com/aws/aws-sdk-go-v2/service/dynamodb
-//   - github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue
-//
-// When endpoint == "local" the module falls back to the in-memory backend,
-// which is useful for local development and tests without a real DynamoDB endpoint.
+// The module calls the DynamoDB JSON API directly with Signature V4 signing,
+// so no AWS SDK is required. Endpoint selects where items are stored:
+//   - "" uses the regional AWS endpoint, https://dynamodb.<region>.amazonaws.com
+//   - a URL such as http://localhost:8000 targets DynamoDB Local or another
+//     DynamoDB-compatible endpoint
+//   - "local" uses an in-memory store, for tests without any endpoint
 type DynamoDBNoSQLConfig struct {
 	TableName   string `json:"tableName"   yaml:"tableName"`
 	Region      string `json:"region"      yaml:"region"`
 	Endpoint    string `json:"endpoint"    yaml:"endpoint"`    // "local" => in-memory fallback
 	Credentials string `json:"credentials" yaml:"credentials"` // ref to cloud.account module name
+	// PartitionKey is the table's partition key attribute. Defaults to "pk".
+	PartitionKey string `json:"partitionKey" yaml:"partitionKey"`
+	// SortKey is the table's sort key attribute, for tables with a composite
+	// primary key. Store keys then take the form "<partition><sep><sort>".
+	SortKey string `json:"sortKey" yaml:"sortKey"`
+	// KeySeparator splits a store key into partition and sort key values.
+	// Defaults to "|".
+	KeySeparator string `json:"keySeparator" yaml:"keySeparator"`
 }
 
-// DynamoDBNoSQL is the nosql.dynamodb module.
-// In local mode (endpoint: "local") it delegates to MemoryNoSQL.
-// For a real AWS implementation, replace the backend field with a DynamoDB client
-// and implement Get/Put/Delete/Query using dynamodb.GetItem, PutItem, DeleteItem, Scan.
+// DynamoDBNoSQL is the nosql.dynamodb module. Items are whole documents:
+// Put replaces the stored item and Get returns it as written, with the key
+// attributes included. Key attributes are strings.
 type DynamoDBNoSQL struct {
-	name    string
-	cfg     DynamoDBNoSQLConfig
-	backend NoSQLStore // in-memory fallback when endpoint == "local"
+	name     string
+	cfg      DynamoDBNoSQLConfig
+	client   *http.Client
+	provider CloudCredentialProvider
+	backend  NoSQLStore // *dynamoDBTable, or MemoryNoSQL when endpoint == "local"
 }
 
 // NewDynamoDBNoSQL creates a new DynamoDBNoSQL module.
 func NewDynamoDBNoSQL(name string, cfg DynamoDBNoSQLConfig) *DynamoDBNoSQL {
-	return &DynamoDBNoSQL{name: name, cfg: cfg}
+	return &DynamoDBNoSQL{name: name, cfg: cfg, client: &http.Client{Timeout: 30 * time.Second}}
 }
 
 func (d *DynamoDBNoSQL) Name() string { return d.name }
 
-func (d *DynamoDBNoSQL) Init(_ modular.Application) error {
-	if d.cfg.Endpoint == "local" || d.cfg.Endpoint == "" {
+// Init selects the backend. For a real endpoint it resolves the credentials
+// named by the credentials key from the service registry; without one it
+// uses the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN
+// environment variables.
+func (d *DynamoDBNoSQL) Init(app modular.Application) error {
+	if d.cfg.Endpoint == "local" {
 		d.backend = NewMemoryNoSQL(d.name+"-mem", MemoryNoSQLConfig{Collection: d.cfg.TableName})
 		return nil
 	}
-	// Full AWS implementation:
-	// cfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(d.cfg.Region))
-	// if err != nil { return err }
-	// if d.cfg.Endpoint != "" {
-	//     cfg.EndpointResolverWithOptions = aws.EndpointResolverWithOptionsFunc(...)
-	// }
-	// d.client = dynamodb.NewFromConfig(cfg)
-	return fmt.Errorf("nosql.dynamodb %q: real AWS endpoint not yet implemented; use endpoint: local for testing", d.name)
+	if d.cfg.TableName == "" {
+		return fmt.Errorf("nosql.dynamodb %q: tableName is required", d.name)
+	}
+	if d.cfg.Credentials != "" {
+		if app == nil {
+			return fmt.Errorf("nosql.dynamodb %q: no application to resolve credentials %q", d.name, d.cfg.Credentials)
+		}
+		svc, ok := app.SvcRegistry()[d.cfg.Credentials]
+		if !ok {
+			return fmt.Errorf("nosql.dynamodb %q: credentials service %q not found", d.name, d.cfg.Credentials)
+		}
+		provider, ok := svc.(CloudCredentialProvider)
+		if !ok {
+			return fmt.Errorf("nosql.dynamodb %q: service %q does not implement CloudCredentialProvider", d.name, d.cfg.Credentials)
+		}
+		d.provider = provider
+	}
+
+	region := d.cfg.Region
+	if region == "" && d.provider != nil {
+		region = d.provider.Region()
+	}
+	region = cmp.Or(region, os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION"), "us-east-1")
+	endpoint := d.cfg.Endpoint
+	if endpoint == "" {
+		endpoint = "https://dynamodb." + region + ".amazonaws.com"
+	}
+
+	table, err := newDynamoDBTable(d.name, d.cfg, region, endpoint, d.client, d.credentials)
+	if err != nil {
+		return err
+	}
+	d.backend = table
+	return nil
+}
+
+// credentials returns the credentials to sign a request with. They are
+// fetched on every request so rotated credentials are picked up. A custom
+// endpoint without credentials gets placeholder ones, which DynamoDB Local
+// accepts.
+func (d *DynamoDBNoSQL) credentials(ctx context.Context) (awssigv4.Credentials, error) {
+	if d.provider != nil {
+		creds, err := d.provider.GetCredentials(ctx)
+		if err != nil {
+			return awssigv4.Credentials{}, err
+		}
+		if creds.AccessKey == "" || creds.SecretKey == "" {
+			return awssigv4.Credentials{}, fmt.Errorf("credentials %q have no AWS access key", d.cfg.Credentials)
+		}
+		return awssigv4.Credentials{AccessKeyID: creds.AccessKey, SecretAccessKey: creds.SecretKey, SessionToken: creds.SessionToken}, nil
+	}
+	creds := awssigv4.Credentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		if d.cfg.Endpoint != "" {
+			return awssigv4.Credentials{AccessKeyID: "local", SecretAccessKey: "local"}, nil
+		}
+		return awssigv4.Credentials{}, fmt.Errorf("AWS credentials required: set credentials to a cloud.account module or AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY")
+	}
+	return creds, nil
 }
 
 func (d *DynamoDBNoSQL) ProvidesServices() []modular.ServiceProvider {
@@ -62,34 +137,82 @@ func (d *DynamoDBNoSQL) ProvidesServices() []modular.ServiceProvider {
 
 func (d *DynamoDBNoSQL) RequiresServices() []modular.ServiceDependency { return nil }
 
+// Get retrieves an item by key. Returns nil, nil when the key does not exist.
 func (d *DynamoDBNoSQL) Get(ctx context.Context, key string) (map[string]any, error) {
 	if d.backend == nil {
 		return nil, fmt.Errorf("nosql.dynamodb %q: not initialized", d.name)
 	}
-	// Real implementation: dynamodb.GetItem with Key: {PK: {S: &key}}
 	return d.backend.Get(ctx, key)
 }
 
+// Put inserts or replaces an item.
 func (d *DynamoDBNoSQL) Put(ctx context.Context, key string, item map[string]any) error {
 	if d.backend == nil {
 		return fmt.Errorf("nosql.dynamodb %q: not initialized", d.name)
 	}
-	// Real implementation: attributevalue.MarshalMap(item) then dynamodb.PutItem
 	return d.backend.Put(ctx, key, item)
 }
 
+// Delete removes an item by key. Does not error if the key does not exist.
 func (d *DynamoDBNoSQL) Delete(ctx context.Context, key string) error {
 	if d.backend == nil {
 		return fmt.Errorf("nosql.dynamodb %q: not initialized", d.name)
 	}
-	// Real implementation: dynamodb.DeleteItem with Key: {PK: {S: &key}}
 	return d.backend.Delete(ctx, key)
 }
 
+// Query returns matching items, each with its store key under "_key".
+// Supported params:
+//   - "partitionKey" (string): query a single partition
+//   - "sortKeyPrefix" (string): with partitionKey, keep sort keys with this prefix
+//   - "descending" (bool): with partitionKey, return items in descending sort key order
+//   - "prefix" (string): without partitionKey, scan for partition keys with this prefix
+//   - "limit" (int): maximum number of items to return
 func (d *DynamoDBNoSQL) Query(ctx context.Context, params map[string]any) ([]map[string]any, error) {
 	if d.backend == nil {
 		return nil, fmt.Errorf("nosql.dynamodb %q: not initialized", d.name)
 	}
-	// Real implementation: dynamodb.Scan or Query with FilterExpression
+	if mem, ok := d.backend.(*MemoryNoSQL); ok {
+		return d.queryLocal(ctx, mem, params)
+	}
 	return d.backend.Query(ctx, params)
 }
+
+// queryLocal emulates the partition and sort key params on the in-memory
+// store, whose keys are the store keys, and orders results by key as a
+// DynamoDB query does.
+func (d *DynamoDBNoSQL) queryLocal(ctx context.Context, mem *MemoryNoSQL, params map[string]any) ([]map[string]any, error) {
+	partition, _ := params["partitionKey"].(string)
+	sortPrefix, _ := params["sortKeyPrefix"].(string)
+	prefix, _ := params["prefix"].(string)
+	sep := cmp.Or(d.cfg.KeySeparator, "|")
+	exact := ""
+	switch {
+	case partition != "" && d.cfg.SortKey != "":
+		prefix = partition + sep + sortPrefix
+	case partition != "":
+		if sortPrefix != "" {
+			return nil, fmt.Errorf("nosql.dynamodb %q: sortKeyPrefix requires sortKey to be configured", d.name)
+		}
+		exact, prefix = partition, partition
+	case sortPrefix != "":
+		return nil, fmt.Errorf("nosql.dynamodb %q: sortKeyPrefix requires partitionKey", d.name)
+	}
+	items, err := mem.Query(ctx, map[string]any{"prefix": prefix})
+	if err != nil {
+		return nil, err
+	}
+	if exact != "" {
+		items = slices.DeleteFunc(items, func(item map[string]any) bool { return item["_key"] != exact })
+	}
+	slices.SortFunc(items, func(a, b map[string]any) int {
+		return strings.Compare(a["_key"].(string), b["_key"].(string))
+	})
+	if desc, _ := params["descending"].(bool); desc {
+		slices.Reverse(items)
+	}
+	if n, ok := intFromAny(params["limit"]); ok && n > 0 && len(items) > n {
+		items = items[:n]
+	}
+	return items, nil
+}
@@ -0,0 +1,423 @@
+package module
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/internal/awssigv4"
+)
+
+// dynamoDBTable is the DynamoDB backend of nosql.dynamodb. It calls the
+// DynamoDB JSON API (version 2012-08-10) over HTTP.
+type dynamoDBTable struct {
+	name     string
+	table    string
+	region   string
+	endpoint *url.URL
+	pk, sk   string
+	sep      string
+	client   *http.Client
+	creds    func(context.Context) (awssigv4.Credentials, error)
+	now      func() time.Time
+}
+
+func newDynamoDBTable(name string, cfg DynamoDBNoSQLConfig, region, endpoint string, client *http.Client, creds func(context.Context) (awssigv4.Credentials, error)) (*dynamoDBTable, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("nosql.dynamodb %q: endpoint %q must be an http(s) URL or \"local\"", name, endpoint)
+	}
+	t := &dynamoDBTable{
+		name:     name,
+		table:    cfg.TableName,
+		region:   region,
+		endpoint: u,
+		pk:       cfg.PartitionKey,
+		sk:       cfg.SortKey,
+		sep:      cfg.KeySeparator,
+		client:   client,
+		creds:    creds,
+		now:      time.Now,
+	}
+	if t.pk == "" {
+		t.pk = "pk"
+	}
+	if t.sep == "" {
+		t.sep = "|"
+	}
+	if t.sk == t.pk {
+		return nil, fmt.Errorf("nosql.dynamodb %q: sortKey must differ from partitionKey", name)
+	}
+	return t, nil
+}
+
+// primaryKey maps a store key to the table's key attributes. With a sort key
+// the store key is split at the first separator.
+func (t *dynamoDBTable) primaryKey(key string) (map[string]any, error) {
+	if key == "" {
+		return nil, fmt.Errorf("nosql.dynamodb %q: key must not be empty", t.name)
+	}
+	if t.sk == "" {
+		return map[string]any{t.pk: map[string]any{"S": key}}, nil
+	}
+	partition, sort, ok := strings.Cut(key, t.sep)
+	if !ok || partition == "" || sort == "" {
+		return nil, fmt.Errorf("nosql.dynamodb %q: key %q must have the form <%s>%s<%s>", t.name, key, t.pk, t.sep, t.sk)
+	}
+	return map[string]any{
+		t.pk: map[string]any{"S": partition},
+		t.sk: map[string]any{"S": sort},
+	}, nil
+}
+
+// storeKey rebuilds the store key of a decoded item.
+func (t *dynamoDBTable) storeKey(item map[string]any) string {
+	partition, _ := item[t.pk].(string)
+	if t.sk == "" {
+		return partition
+	}
+	sort, _ := item[t.sk].(string)
+	return partition + t.sep + sort
+}
+
+// Get retrieves an item by key with a strongly consistent read.
+func (t *dynamoDBTable) Get(ctx context.Context, key string) (map[string]any, error) {
+	pk, err := t.primaryKey(key)
+	if err != nil {
+		return nil, err
+	}
+	var out struct {
+		Item map[string]map[string]any `json:"Item"`
+	}
+	if err := t.call(ctx, "GetItem", map[string]any{"TableName": t.table, "Key": pk, "ConsistentRead": true}, &out); err != nil {
+		return nil, err
+	}
+	if out.Item == nil {
+		return nil, nil
+	}
+	return t.decodeItem(out.Item)
+}
+
+// Put replaces the item stored under key. The key attributes are set from
+// key, overriding any attributes of the same name in item.
+func (t *dynamoDBTable) Put(ctx context.Context, key string, item map[string]any) error {
+	pk, err := t.primaryKey(key)
+	if err != nil {
+		return err
+	}
+	attrs := make(map[string]any, len(item)+len(pk))
+	for k, v := range item {
+		av, err := dynamoDBAttributeValue(v)
+		if err != nil {
+			return fmt.Errorf("nosql.dynamodb %q: attribute %q: %w", t.name, k, err)
+		}
+		attrs[k] = av
+	}
+	for k, v := range pk {
+		attrs[k] = v
+	}
+	return t.call(ctx, "PutItem", map[string]any{"TableName": t.table, "Item": attrs}, nil)
+}
+
+// Delete removes an item by key. Deleting a missing item is not an error.
+func (t *dynamoDBTable) Delete(ctx context.Context, key string) error {
+	pk, err := t.primaryKey(key)
+	if err != nil {
+		return err
+	}
+	return t.call(ctx, "DeleteItem", map[string]any{"TableName": t.table, "Key": pk}, nil)
+}
+
+// Query runs a DynamoDB Query when partitionKey is given and a Scan
+// otherwise, following LastEvaluatedKey until the results are complete or
+// the limit is reached. See DynamoDBNoSQL.Query for the params.
+func (t *dynamoDBTable) Query(ctx context.Context, params map[string]any) ([]map[string]any, error) {
+	limit := 0
+	if v, ok := params["limit"]; ok {
+		n, ok := intFromAny(v)
+		if !ok || n < 0 {
+			return nil, fmt.Errorf("nosql.dynamodb %q: limit must be a non-negative integer", t.name)
+		}
+		limit = n
+	}
+
+	op := "Scan"
+	req := map[string]any{"TableName": t.table}
+	names := map[string]string{"#pk": t.pk}
+	values := map[string]any{}
+	partition, _ := params["partitionKey"].(string)
+	sortPrefix, _ := params["sortKeyPrefix"].(string)
+	prefix, _ := params["prefix"].(string)
+	switch {
+	case partition != "":
+		op = "Query"
+		cond := "#pk = :pk"
+		values[":pk"] = map[string]any{"S": partition}
+		if sortPrefix != "" {
+			if t.sk == "" {
+				return nil, fmt.Errorf("nosql.dynamodb %q: sortKeyPrefix requires sortKey to be configured", t.name)
+			}
+			names["#sk"] = t.sk
+			values[":skp"] = map[string]any{"S": sortPrefix}
+			cond += " AND begins_with(#sk, :skp)"
+		}
+		req["KeyConditionExpression"] = cond
+		if desc, _ := params["descending"].(bool); desc {
+			req["ScanIndexForward"] = false
+		}
+	case sortPrefix != "":
+		return nil, fmt.Errorf("nosql.dynamodb %q: sortKeyPrefix requires partitionKey", t.name)
+	case prefix != "":
+		req["FilterExpression"] = "begins_with(#pk, :prefix)"
+		values[":prefix"] = map[string]any{"S": prefix}
+	}
+	if len(values) > 0 {
+		req["ExpressionAttributeNames"] = names
+		req["ExpressionAttributeValues"] = values
+	}
+
+	results := []map[string]any{}
+	for {
+		if limit > 0 {
+			req["Limit"] = limit - len(results)
+		}
+		var out struct {
+			Items            []map[string]map[string]any `json:"Items"`
+			LastEvaluatedKey map[string]any              `json:"LastEvaluatedKey"`
+		}
+		if err := t.call(ctx, op, req, &out); err != nil {
+			return nil, err
+		}
+		for _, raw := range out.Items {
+			item, err := t.decodeItem(raw)
+			if err != nil {
+				return nil, err
+			}
+			item["_key"] = t.storeKey(item)
+			results = append(results, item)
+		}
+		if len(out.LastEvaluatedKey) == 0 || (limit > 0 && len(results) >= limit) {
+			break
+		}
+		req["ExclusiveStartKey"] = out.LastEvaluatedKey
+	}
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+func (t *dynamoDBTable) decodeItem(raw map[string]map[string]any) (map[string]any, error) {
+	item := make(map[string]any, len(raw))
+	for k, av := range raw {
+		v, err := dynamoDBValue(av)
+		if err != nil {
+			return nil, fmt.Errorf("nosql.dynamodb %q: attribute %q: %w", t.name, k, err)
+		}
+		item[k] = v
+	}
+	return item, nil
+}
+
+// call sends a signed request for the DynamoDB operation op and decodes the
+// response into out, when out is not nil.
+func (t *dynamoDBTable) call(ctx context.Context, op string, in, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("nosql.dynamodb %q: %s: %w", t.name, op, err)
+	}
+	creds, err := t.creds(ctx)
+	if err != nil {
+		return fmt.Errorf("nosql.dynamodb %q: %w", t.name, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("nosql.dynamodb %q: %s: %w", t.name, op, err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.0")
+	req.Header.Set("X-Amz-Target", "DynamoDB_20120810."+op)
+	awssigv4.Sign(req, body, creds, t.region, "dynamodb", t.now())
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("nosql.dynamodb %q: %s: %w", t.name, op, err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("nosql.dynamodb %q: %s: reading response: %w", t.name, op, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Type     string `json:"__type"`
+			Message  string `json:"message"`
+			MessageU string `json:"Message"`
+		}
+		_ = json.Unmarshal(respBody, &apiErr)
+		code := apiErr.Type
+		if i := strings.LastIndex(code, "#"); i >= 0 {
+			code = code[i+1:]
+		}
+		msg := apiErr.Message
+		if msg == "" {
+			msg = apiErr.MessageU
+		}
+		if code == "" && msg == "" {
+			msg = strings.TrimSpace(string(respBody))
+		}
+		return fmt.Errorf("nosql.dynamodb %q: %s failed with status %d: %s %s", t.name, op, resp.StatusCode, code, msg)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("nosql.dynamodb %q: %s: decoding response: %w", t.name, op, err)
+	}
+	return nil
+}
+
+// dynamoDBAttributeValue converts a Go value to a DynamoDB attribute value.
+// Values other than the JSON kinds are converted through their JSON form.
+func dynamoDBAttributeValue(v any) (map[string]any, error) {
+	switch v := v.(type) {
+	case nil:
+		return map[string]any{"NULL": true}, nil
+	case string:
+		return map[string]any{"S": v}, nil
+	case bool:
+		return map[string]any{"BOOL": v}, nil
+	case []byte:
+		return map[string]any{"B": base64.StdEncoding.EncodeToString(v)}, nil
+	case json.Number:
+		return map[string]any{"N": v.String()}, nil
+	case int:
+		return map[string]any{"N": strconv.Itoa(v)}, nil
+	case int64:
+		return map[string]any{"N": strconv.FormatInt(v, 10)}, nil
+	case int32:
+		return map[string]any{"N": strconv.FormatInt(int64(v), 10)}, nil
+	case uint64:
+		return map[string]any{"N": strconv.FormatUint(v, 10)}, nil
+	case float32:
+		return dynamoDBAttributeValue(float64(v))
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("cannot store %v as a DynamoDB number", v)
+		}
+		return map[string]any{"N": strconv.FormatFloat(v, 'f', -1, 64)}, nil
+	case []any:
+		list := make([]any, 0, len(v))
+		for i, e := range v {
+			av, err := dynamoDBAttributeValue(e)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			list = append(list, av)
+		}
+		return map[string]any{"L": list}, nil
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			av, err := dynamoDBAttributeValue(e)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", k, err)
+			}
+			m[k] = av
+		}
+		return map[string]any{"M": m}, nil
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		var generic any
+		if err := dec.Decode(&generic); err != nil {
+			return nil, err
+		}
+		return dynamoDBAttributeValue(generic)
+	}
+}
+
+// dynamoDBValue converts a DynamoDB attribute value to a Go value. Numbers
+// become int64 when integral and float64 otherwise; sets become lists.
+func dynamoDBValue(av map[string]any) (any, error) {
+	for typ, raw := range av {
+		switch typ {
+		case "S":
+			s, _ := raw.(string)
+			return s, nil
+		case "N":
+			s, _ := raw.(string)
+			return dynamoDBNumber(s)
+		case "BOOL":
+			b, _ := raw.(bool)
+			return b, nil
+		case "NULL":
+			return nil, nil
+		case "B":
+			s, _ := raw.(string)
+			return base64.StdEncoding.DecodeString(s)
+		case "M":
+			m, _ := raw.(map[string]any)
+			out := make(map[string]any, len(m))
+			for k, e := range m {
+				ev, _ := e.(map[string]any)
+				v, err := dynamoDBValue(ev)
+				if err != nil {
+					return nil, err
+				}
+				out[k] = v
+			}
+			return out, nil
+		case "L":
+			l, _ := raw.([]any)
+			out := make([]any, 0, len(l))
+			for _, e := range l {
+				ev, _ := e.(map[string]any)
+				v, err := dynamoDBValue(ev)
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, v)
+			}
+			return out, nil
+		case "SS", "NS", "BS":
+			l, _ := raw.([]any)
+			out := make([]any, 0, len(l))
+			for _, e := range l {
+				s, _ := e.(string)
+				v, err := dynamoDBValue(map[string]any{typ[:1]: s})
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, v)
+			}
+			return out, nil
+		default:
+			return nil, fmt.Errorf("unsupported attribute type %q", typ)
+		}
+	}
+	return nil, fmt.Errorf("empty attribute value")
+}
+
+func dynamoDBNumber(s string) (any, error) {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number %q", s)
+	}
+	return f, nil
+}
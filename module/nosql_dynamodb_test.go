@@ -0,0 +1,318 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeDynamoDB emulates the DynamoDB JSON API for a table keyed by "pk" and
+// "sk", returning at most two items per Query or Scan page.
+type fakeDynamoDB struct {
+	mu    sync.Mutex
+	items map[string]map[string]any
+	auth  []string
+}
+
+func newFakeDynamoDB(t *testing.T) (*fakeDynamoDB, *httptest.Server) {
+	t.Helper()
+	f := &fakeDynamoDB{items: map[string]map[string]any{}}
+	srv := httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func fakeKeyString(item map[string]any, attr string) string {
+	av, _ := item[attr].(map[string]any)
+	s, _ := av["S"].(string)
+	return s
+}
+
+func (f *fakeDynamoDB) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	op := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "DynamoDB_20120810.")
+	f.auth = append(f.auth, r.Header.Get("Authorization"))
+
+	var in map[string]any
+	_ = json.NewDecoder(r.Body).Decode(&in)
+	if in["TableName"] != "events" {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"__type":"com.amazonaws.dynamodb.v20120810#ResourceNotFoundException","message":"Requested resource not found"}`))
+		return
+	}
+	id := func(item map[string]any) string {
+		return fakeKeyString(item, "pk") + "\x00" + fakeKeyString(item, "sk")
+	}
+	var out any = map[string]any{}
+	switch op {
+	case "PutItem":
+		item := in["Item"].(map[string]any)
+		f.items[id(item)] = item
+	case "GetItem":
+		if item, ok := f.items[id(in["Key"].(map[string]any))]; ok {
+			out = map[string]any{"Item": item}
+		}
+	case "DeleteItem":
+		delete(f.items, id(in["Key"].(map[string]any)))
+	case "Query", "Scan":
+		values, _ := in["ExpressionAttributeValues"].(map[string]any)
+		str := func(name string) string { av, _ := values[name].(map[string]any); s, _ := av["S"].(string); return s }
+		var matched []map[string]any
+		for _, item := range f.items {
+			pk, sk := fakeKeyString(item, "pk"), fakeKeyString(item, "sk")
+			if op == "Query" && (pk != str(":pk") || !strings.HasPrefix(sk, str(":skp"))) {
+				continue
+			}
+			if op == "Scan" && !strings.HasPrefix(pk, str(":prefix")) {
+				continue
+			}
+			matched = append(matched, item)
+		}
+		slices.SortFunc(matched, func(a, b map[string]any) int { return strings.Compare(id(a), id(b)) })
+		if forward, ok := in["ScanIndexForward"].(bool); ok && !forward {
+			slices.Reverse(matched)
+		}
+		if start, ok := in["ExclusiveStartKey"].(map[string]any); ok {
+			i := slices.IndexFunc(matched, func(item map[string]any) bool { return id(item) == id(start) })
+			matched = matched[i+1:]
+		}
+		page := 2
+		if limit, ok := in["Limit"].(float64); ok && int(limit) < page {
+			page = int(limit)
+		}
+		resp := map[string]any{"Items": matched[:min(page, len(matched))]}
+		if len(matched) > page {
+			last := matched[page-1]
+			resp["LastEvaluatedKey"] = map[string]any{"pk": last["pk"], "sk": last["sk"]}
+		}
+		out = resp
+	}
+	_ = json.NewEncoder(w).Encode(out)
+}
+
+func newTestDynamoDB(t *testing.T, endpoint string) *DynamoDBNoSQL {
+	t.Helper()
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDTEST")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+	d := NewDynamoDBNoSQL("events-store", DynamoDBNoSQLConfig{
+		TableName: "events",
+		Region:    "eu-west-1",
+		Endpoint:  endpoint,
+		SortKey:   "sk",
+	})
+	if err := d.Init(nil); err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestDynamoDBNoSQL_Endpoint(t *testing.T) {
+	fake, srv := newFakeDynamoDB(t)
+	d := newTestDynamoDB(t, srv.URL)
+	ctx := context.Background()
+
+	item := map[string]any{
+		"count":   3,
+		"ratio":   0.5,
+		"active":  true,
+		"missing": nil,
+		"tags":    []any{"a", 1.0},
+		"meta":    map[string]any{"source": "api"},
+	}
+	if err := d.Put(ctx, "user-1|2026-01-01", item); err != nil {
+		t.Fatal(err)
+	}
+	got, err := d.Get(ctx, "user-1|2026-01-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"pk": "user-1", "sk": "2026-01-01",
+		"count": int64(3), "ratio": 0.5, "active": true, "missing": nil,
+		"tags": []any{"a", int64(1)}, "meta": map[string]any{"source": "api"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Get = %#v\nwant %#v", got, want)
+	}
+	if missing, err := d.Get(ctx, "user-1|2026-02-01"); err != nil || missing != nil {
+		t.Errorf("Get missing = %v, %v; want nil, nil", missing, err)
+	}
+	if !strings.HasPrefix(fake.auth[0], "AWS4-HMAC-SHA256 Credential=AKIDTEST/") || !strings.Contains(fake.auth[0], "/eu-west-1/dynamodb/aws4_request") {
+		t.Errorf("Authorization = %q", fake.auth[0])
+	}
+
+	for _, key := range []string{"user-1|2026-01-02", "user-1|2026-02-01", "user-1|2026-02-02", "user-2|2026-01-01"} {
+		if err := d.Put(ctx, key, map[string]any{"n": 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	keys := func(items []map[string]any) []string {
+		var out []string
+		for _, item := range items {
+			out = append(out, item["_key"].(string))
+		}
+		return out
+	}
+
+	// A partition spans several pages.
+	items, err := d.Query(ctx, map[string]any{"partitionKey": "user-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"user-1|2026-01-01", "user-1|2026-01-02", "user-1|2026-02-01", "user-1|2026-02-02"}; !slices.Equal(keys(items), want) {
+		t.Errorf("partition query = %v, want %v", keys(items), want)
+	}
+	items, err = d.Query(ctx, map[string]any{"partitionKey": "user-1", "sortKeyPrefix": "2026-02", "descending": true, "limit": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"user-1|2026-02-02"}; !slices.Equal(keys(items), want) {
+		t.Errorf("sort key query = %v, want %v", keys(items), want)
+	}
+	items, err = d.Query(ctx, map[string]any{"prefix": "user-2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"user-2|2026-01-01"}; !slices.Equal(keys(items), want) {
+		t.Errorf("prefix scan = %v, want %v", keys(items), want)
+	}
+
+	if err := d.Delete(ctx, "user-1|2026-01-01"); err != nil {
+		t.Fatal(err)
+	}
+	if gone, _ := d.Get(ctx, "user-1|2026-01-01"); gone != nil {
+		t.Errorf("item still present after Delete: %v", gone)
+	}
+
+	if err := d.Put(ctx, "user-1", map[string]any{}); err == nil || !strings.Contains(err.Error(), "<pk>|<sk>") {
+		t.Errorf("Put without sort key: err = %v", err)
+	}
+	if _, err := d.Query(ctx, map[string]any{"sortKeyPrefix": "2026"}); err == nil {
+		t.Error("expected sortKeyPrefix without partitionKey to fail")
+	}
+}
+
+func TestDynamoDBNoSQL_APIError(t *testing.T) {
+	_, srv := newFakeDynamoDB(t)
+	d := newTestDynamoDB(t, srv.URL)
+	d.backend.(*dynamoDBTable).table = "missing"
+
+	_, err := d.Get(context.Background(), "a|b")
+	if err == nil || !strings.Contains(err.Error(), "GetItem failed with status 400: ResourceNotFoundException Requested resource not found") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestDynamoDBNoSQL_InitErrors(t *testing.T) {
+	app := NewMockApplication()
+	app.Services["not-an-account"] = "nope"
+	tests := map[string]struct {
+		cfg  DynamoDBNoSQLConfig
+		want string
+	}{
+		"no table":        {DynamoDBNoSQLConfig{}, "tableName is required"},
+		"bad endpoint":    {DynamoDBNoSQLConfig{TableName: "t", Endpoint: "localhost:8000"}, "must be an http(s) URL"},
+		"same key attrs":  {DynamoDBNoSQLConfig{TableName: "t", SortKey: "pk"}, "sortKey must differ"},
+		"missing account": {DynamoDBNoSQLConfig{TableName: "t", Credentials: "aws"}, `credentials service "aws" not found`},
+		"not an account":  {DynamoDBNoSQLConfig{TableName: "t", Credentials: "not-an-account"}, "does not implement CloudCredentialProvider"},
+	}
+	for name, tt := range tests {
+		err := NewDynamoDBNoSQL("d", tt.cfg).Init(app)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: err = %v, want %q", name, err, tt.want)
+		}
+	}
+
+	// The AWS endpoint needs real credentials; they are checked per request.
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	d := NewDynamoDBNoSQL("d", DynamoDBNoSQLConfig{TableName: "t", Region: "us-west-2"})
+	if err := d.Init(app); err != nil {
+		t.Fatal(err)
+	}
+	if got := d.backend.(*dynamoDBTable).endpoint.String(); got != "https://dynamodb.us-west-2.amazonaws.com" {
+		t.Errorf("endpoint = %q", got)
+	}
+	if _, err := d.Get(context.Background(), "k"); err == nil || !strings.Contains(err.Error(), "AWS credentials required") {
+		t.Errorf("Get without credentials: err = %v", err)
+	}
+}
+
+func TestDynamoDBNoSQL_CloudAccountCredentials(t *testing.T) {
+	fake, srv := newFakeDynamoDB(t)
+	app := NewMockApplication()
+	app.Services["aws"] = &CloudAccount{name: "aws", region: "ap-south-1", creds: &CloudCredentials{AccessKey: "AKIDACCOUNT", SecretKey: "s", SessionToken: "tok"}}
+	d := NewDynamoDBNoSQL("d", DynamoDBNoSQLConfig{TableName: "events", Endpoint: srv.URL, Credentials: "aws", SortKey: "sk"})
+	if err := d.Init(app); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Put(context.Background(), "a|b", map[string]any{}); err != nil {
+		t.Fatal(err)
+	}
+	auth := fake.auth[0]
+	if !strings.Contains(auth, "Credential=AKIDACCOUNT/") || !strings.Contains(auth, "/ap-south-1/dynamodb/") || !strings.Contains(auth, "x-amz-security-token") {
+		t.Errorf("Authorization = %q, want the account's key, region and session token", auth)
+	}
+}
+
+func TestDynamoDBNoSQL_LocalModeQueriesByPartition(t *testing.T) {
+	ctx := context.Background()
+	d := NewDynamoDBNoSQL("d", DynamoDBNoSQLConfig{Endpoint: "local", SortKey: "sk"})
+	if err := d.Init(nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"u1|a", "u1|b", "u10|a", "u2|a"} {
+		_ = d.Put(ctx, key, map[string]any{})
+	}
+	items, err := d.Query(ctx, map[string]any{"partitionKey": "u1", "descending": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0]["_key"] != "u1|b" || items[1]["_key"] != "u1|a" {
+		t.Errorf("local partition query = %v", items)
+	}
+}
+
+func TestNoSQLQueryStep_PartitionKey(t *testing.T) {
+	ctx := context.Background()
+	store := NewDynamoDBNoSQL("events", DynamoDBNoSQLConfig{Endpoint: "local", SortKey: "sk"})
+	if err := store.Init(nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"u1|2026-01", "u1|2026-02", "u1|2026-03", "u2|2026-01"} {
+		_ = store.Put(ctx, key, map[string]any{})
+	}
+	app := NewMockApplication()
+	app.Services["events"] = NoSQLStore(store)
+
+	step, err := NewNoSQLQueryStepFactory()("query-step", map[string]any{
+		"store":         "events",
+		"partitionKey":  "{{ .user }}",
+		"sortKeyPrefix": "2026-0",
+		"descending":    true,
+		"limit":         2,
+	}, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := step.Execute(ctx, NewPipelineContext(map[string]any{"user": "u1"}, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := result.Output["items"].([]map[string]any)
+	if result.Output["count"] != 2 || items[0]["_key"] != "u1|2026-03" || items[1]["_key"] != "u1|2026-02" {
+		t.Errorf("output = %v", result.Output)
+	}
+
+	if _, err := NewNoSQLQueryStepFactory()("bad", map[string]any{"store": "events", "limit": "ten"}, app); err == nil {
+		t.Error("expected a non-numeric limit to be rejected")
+	}
+}
@@ -54,9 +54,15 @@ const defaultMaxBodyBytes int64 = 1 << 20 // 1 MiB
 
 // openAPISpec is a minimal representation of an OpenAPI 3.x specification.
 type openAPISpec struct {
-	OpenAPI string                     `yaml:"openapi" json:"openapi"`
-	Info    openAPIInfo                `yaml:"info"    json:"info"`
-	Paths   map[string]openAPIPathItem `yaml:"paths"   json:"paths"`
+	OpenAPI    string                     `yaml:"openapi"    json:"openapi"`
+	Info       openAPIInfo                `yaml:"info"       json:"info"`
+	Paths      map[string]openAPIPathItem `yaml:"paths"      json:"paths"`
+	Components *openAPIComponents         `yaml:"components" json:"components,omitempty"`
+}
+
+// openAPIComponents holds the reusable schemas that $ref values point to.
+type openAPIComponents struct {
+	Schemas map[string]*openAPISchema `yaml:"schemas" json:"schemas"`
 }
 
 type openAPIInfo struct {
@@ -163,6 +169,7 @@ func (a openAPIAdditionalProperties) MarshalJSON() ([]byte, error) {
 
 // openAPISchema is a minimal JSON Schema subset used for parameter/body validation.
 type openAPISchema struct {
+	Ref                  string                       `yaml:"$ref"                 json:"$ref,omitempty"`
 	Type                 string                       `yaml:"type"                 json:"type"`
 	Required             []string                     `yaml:"required"             json:"required"`
 	Properties           map[string]*openAPISchema    `yaml:"properties"           json:"properties"`
@@ -38,7 +38,7 @@ func registerComponentSchemas(gen *OpenAPIGenerator) {
 	gen.RegisterComponentSchema("AuthResponse", &OpenAPISchema{
 		Type: "object",
 		Properties: map[string]*OpenAPISchema{
-			"token": {Type: "string"},
+			"token": {Type: "string", Sensitive: true},
 			"user":  {Ref: "#/components/schemas/UserProfile"},
 		},
 	})
@@ -127,6 +127,29 @@ func registerComponentSchemas(gen *OpenAPIGenerator) {
 		},
 	})
 
+	gen.RegisterComponentSchema("StepPreviewRequest", &OpenAPISchema{
+		Type:     "object",
+		Required: []string{"type"},
+		Properties: map[string]*OpenAPISchema{
+			"type":   {Type: "string"},
+			"name":   {Type: "string"},
+			"config": {Type: "object"},
+			"input":  {Type: "object"},
+		},
+	})
+
+	gen.RegisterComponentSchema("StepPreviewResult", &OpenAPISchema{
+		Type: "object",
+		Properties: map[string]*OpenAPISchema{
+			"output":      {Type: "object"},
+			"next_step":   {Type: "string"},
+			"stop":        {Type: "boolean"},
+			"error":       {Type: "string"},
+			"code":        {Type: "string", Enum: []string{"not_allowed", "invalid_config", "execution_failed", "timeout"}},
+			"duration_ms": {Type: "integer"},
+		},
+	})
+
 	gen.RegisterComponentSchema("ModuleTypeInfo", &OpenAPISchema{
 		Type: "object",
 		Properties: map[string]*OpenAPISchema{
@@ -585,6 +608,7 @@ func registerEngineOperationSchemas(gen *OpenAPIGenerator) {
 	gen.SetOperationSchema("POST", "/api/v1/admin/engine/validate", SchemaRef("WorkflowConfig"), SchemaRef("ValidationResult"))
 	gen.SetOperationSchema("POST", "/api/v1/admin/engine/reload", nil, SchemaRef("SuccessResponse"))
 	gen.SetOperationSchema("POST", "/api/v1/admin/engine/try-activate", SchemaRef("WorkflowConfig"), SchemaRef("TryActivateResult"))
+	gen.SetOperationSchema("POST", "/api/v1/admin/engine/steps/preview", SchemaRef("StepPreviewRequest"), SchemaRef("StepPreviewResult"))
 }
 
 func registerSchemaOperationSchemas(gen *OpenAPIGenerator) {
@@ -8,6 +8,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -17,8 +18,76 @@ import (
 
 // OpenAPIConsumerConfig holds configuration for the OpenAPI consumer module.
 type OpenAPIConsumerConfig struct {
-	SpecURL  string `json:"specUrl" yaml:"specUrl"`
-	SpecFile string `json:"specFile" yaml:"specFile"`
+	SpecURL  string               `json:"specUrl" yaml:"specUrl"`
+	SpecFile string               `json:"specFile" yaml:"specFile"`
+	Auth     *OpenAPIConsumerAuth `json:"auth,omitempty" yaml:"auth,omitempty"`
+}
+
+// Authentication types supported by OpenAPIConsumerAuth.
+const (
+	OpenAPIAuthBearer = "bearer"
+	OpenAPIAuthBasic  = "basic"
+	OpenAPIAuthAPIKey = "apiKey"
+)
+
+// OpenAPIConsumerAuth holds the credentials the consumer attaches to every
+// request it sends to the external API.
+type OpenAPIConsumerAuth struct {
+	Type     string `json:"type" yaml:"type"` // bearer | basic | apiKey
+	Token    string `json:"token,omitempty" yaml:"token,omitempty"`
+	Username string `json:"username,omitempty" yaml:"username,omitempty"`
+	Password string `json:"password,omitempty" yaml:"password,omitempty"`
+	// Name is the header or query parameter carrying an API key (default X-API-Key).
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// In is where the API key is sent: header (default) or query.
+	In    string `json:"in,omitempty" yaml:"in,omitempty"`
+	Value string `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
+// validate checks that the auth type is known and its credentials are set.
+func (a *OpenAPIConsumerAuth) validate() error {
+	switch a.Type {
+	case OpenAPIAuthBearer:
+		if a.Token == "" {
+			return fmt.Errorf("bearer auth requires a token")
+		}
+	case OpenAPIAuthBasic:
+		if a.Username == "" {
+			return fmt.Errorf("basic auth requires a username")
+		}
+	case OpenAPIAuthAPIKey:
+		if a.Value == "" {
+			return fmt.Errorf("apiKey auth requires a value")
+		}
+		if a.In != "" && a.In != "header" && a.In != "query" {
+			return fmt.Errorf("apiKey auth 'in' must be header or query, got %q", a.In)
+		}
+	default:
+		return fmt.Errorf("unsupported auth type %q (expected bearer, basic or apiKey)", a.Type)
+	}
+	return nil
+}
+
+// apply adds the credentials to req.
+func (a *OpenAPIConsumerAuth) apply(req *http.Request) {
+	switch a.Type {
+	case OpenAPIAuthBearer:
+		req.Header.Set("Authorization", "Bearer "+a.Token)
+	case OpenAPIAuthBasic:
+		req.SetBasicAuth(a.Username, a.Password)
+	case OpenAPIAuthAPIKey:
+		name := a.Name
+		if name == "" {
+			name = "X-API-Key"
+		}
+		if a.In == "query" {
+			q := req.URL.Query()
+			q.Set(name, a.Value)
+			req.URL.RawQuery = q.Encode()
+			return
+		}
+		req.Header.Set(name, a.Value)
+	}
 }
 
 // OpenAPIConsumer parses an external OpenAPI spec and generates typed HTTP
@@ -31,6 +100,12 @@ type OpenAPIConsumer struct {
 	client       *http.Client
 	fieldMapping *FieldMapping
 	mu           sync.RWMutex
+
+	// contract is the spec parsed with the validation schema subset and its
+	// local $ref schemas inlined; it backs step.api_call. contractErr records
+	// why it is nil when the spec uses constructs the subset cannot parse.
+	contract    *openAPISpec
+	contractErr error
 }
 
 // NewOpenAPIConsumer creates a new OpenAPI consumer module.
@@ -48,6 +123,11 @@ func (c *OpenAPIConsumer) Name() string { return c.name }
 
 // Init registers the consumer as a service and loads the spec.
 func (c *OpenAPIConsumer) Init(app modular.Application) error {
+	if c.config.Auth != nil {
+		if err := c.config.Auth.validate(); err != nil {
+			return fmt.Errorf("openapi consumer %q: %w", c.name, err)
+		}
+	}
 	if err := c.loadSpec(); err != nil {
 		return fmt.Errorf("openapi consumer %q: failed to load spec: %w", c.name, err)
 	}
@@ -135,6 +215,7 @@ func (c *OpenAPIConsumer) parseSpec(data []byte) error {
 	}
 
 	c.spec = &spec
+	c.contract, c.contractErr = parseConsumerContract(data)
 
 	// Auto-generate field mappings from the spec
 	c.generateFieldMappings()
@@ -305,6 +386,9 @@ func (c *OpenAPIConsumer) CallOperation(ctx context.Context, operationID string,
 	if bodyReader != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
+	if c.config.Auth != nil {
+		c.config.Auth.apply(req)
+	}
 
 	resp, err := c.client.Do(req) //nolint:gosec // G704: URL from configured OpenAPI endpoint
 	if err != nil {
@@ -333,6 +417,178 @@ func (c *OpenAPIConsumer) CallOperation(ctx context.Context, operationID string,
 	return result, nil
 }
 
+// consumerOperation is an operation of the consumer's contract, located by
+// its operationId.
+type consumerOperation struct {
+	id     string
+	method string
+	path   string
+	op     *openAPIOperation
+}
+
+// parameter returns the declared parameter with the given name, or nil.
+func (o *consumerOperation) parameter(name string) *openAPIParameter {
+	for i := range o.op.Parameters {
+		if o.op.Parameters[i].Name == name {
+			return &o.op.Parameters[i]
+		}
+	}
+	return nil
+}
+
+// requestSchema returns the JSON request body schema, or nil when the
+// operation declares none.
+func (o *consumerOperation) requestSchema() *openAPISchema {
+	if o.op.RequestBody == nil {
+		return nil
+	}
+	return jsonMediaSchema(o.op.RequestBody.Content)
+}
+
+// response returns the response declared for statusCode, falling back to
+// the status class ("2XX") and then to "default".
+func (o *consumerOperation) response(statusCode int) (openAPIResponse, bool) {
+	code := strconv.Itoa(statusCode)
+	for _, key := range []string{code, code[:1] + "XX", code[:1] + "xx", "default"} {
+		if resp, ok := o.op.Responses[key]; ok {
+			return resp, true
+		}
+	}
+	return openAPIResponse{}, false
+}
+
+// jsonMediaSchema returns the schema of the JSON entry of a content map.
+func jsonMediaSchema(content map[string]openAPIMediaType) *openAPISchema {
+	if mt, ok := content["application/json"]; ok {
+		return mt.Schema
+	}
+	for ct, mt := range content {
+		if strings.HasSuffix(strings.SplitN(ct, ";", 2)[0], "+json") {
+			return mt.Schema
+		}
+	}
+	return nil
+}
+
+// operation looks up an operation of the contract by its operationId.
+func (c *OpenAPIConsumer) operation(operationID string) (*consumerOperation, error) {
+	c.mu.RLock()
+	contract, contractErr := c.contract, c.contractErr
+	c.mu.RUnlock()
+
+	if contract == nil {
+		if contractErr != nil {
+			return nil, fmt.Errorf("spec cannot be used for validation: %w", contractErr)
+		}
+		return nil, fmt.Errorf("no spec loaded")
+	}
+	for path, item := range contract.Paths {
+		for method, op := range item {
+			if op != nil && op.OperationID == operationID {
+				return &consumerOperation{id: operationID, method: strings.ToUpper(method), path: path, op: op}, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("operation %q not found in spec", operationID)
+}
+
+// baseURL returns the URL of the first server declared by the spec.
+func (c *OpenAPIConsumer) baseURL() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.spec == nil || len(c.spec.Servers) == 0 {
+		return ""
+	}
+	return strings.TrimRight(c.spec.Servers[0].URL, "/")
+}
+
+// parseConsumerContract parses spec data into the validation schema subset
+// and inlines every local "#/components/schemas/..." reference.
+func parseConsumerContract(data []byte) (*openAPISpec, error) {
+	contract, err := parseOpenAPISpec(data)
+	if err != nil {
+		return nil, err
+	}
+	var components map[string]*openAPISchema
+	if contract.Components != nil {
+		components = contract.Components.Schemas
+	}
+	inline := func(s *openAPISchema) (*openAPISchema, error) {
+		return inlineSchemaRefs(s, components, map[string]bool{})
+	}
+	for path, item := range contract.Paths {
+		for method, op := range item {
+			if op == nil {
+				continue
+			}
+			where := strings.ToUpper(method) + " " + path
+			for i := range op.Parameters {
+				if op.Parameters[i].Schema, err = inline(op.Parameters[i].Schema); err != nil {
+					return nil, fmt.Errorf("%s: parameter %q: %w", where, op.Parameters[i].Name, err)
+				}
+			}
+			if op.RequestBody != nil {
+				for ct, mt := range op.RequestBody.Content {
+					if mt.Schema, err = inline(mt.Schema); err != nil {
+						return nil, fmt.Errorf("%s: request body: %w", where, err)
+					}
+					op.RequestBody.Content[ct] = mt
+				}
+			}
+			for code, resp := range op.Responses {
+				for ct, mt := range resp.Content {
+					if mt.Schema, err = inline(mt.Schema); err != nil {
+						return nil, fmt.Errorf("%s: response %s: %w", where, code, err)
+					}
+					resp.Content[ct] = mt
+				}
+			}
+		}
+	}
+	return contract, nil
+}
+
+// inlineSchemaRefs replaces local component references in s and its nested
+// schemas with the referenced schemas. A reference back to a schema that is
+// still being inlined becomes an empty schema, so recursive types are
+// validated down to their first repetition.
+func inlineSchemaRefs(s *openAPISchema, components map[string]*openAPISchema, resolving map[string]bool) (*openAPISchema, error) {
+	if s == nil {
+		return nil, nil
+	}
+	if s.Ref != "" {
+		name, ok := strings.CutPrefix(s.Ref, "#/components/schemas/")
+		if !ok {
+			return nil, fmt.Errorf("unsupported $ref %q: only local component schemas can be referenced", s.Ref)
+		}
+		target, ok := components[name]
+		if !ok {
+			return nil, fmt.Errorf("$ref %q points to an undefined schema", s.Ref)
+		}
+		if resolving[name] {
+			return &openAPISchema{}, nil
+		}
+		resolving[name] = true
+		defer delete(resolving, name)
+		return inlineSchemaRefs(target, components, resolving)
+	}
+	var err error
+	for prop, ps := range s.Properties {
+		if s.Properties[prop], err = inlineSchemaRefs(ps, components, resolving); err != nil {
+			return nil, err
+		}
+	}
+	if s.Items, err = inlineSchemaRefs(s.Items, components, resolving); err != nil {
+		return nil, err
+	}
+	if s.AdditionalProperties != nil {
+		if s.AdditionalProperties.Schema, err = inlineSchemaRefs(s.AdditionalProperties.Schema, components, resolving); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
 // ProvidesServices returns the services provided by this module.
 func (c *OpenAPIConsumer) ProvidesServices() []modular.ServiceProvider {
 	return []modular.ServiceProvider{
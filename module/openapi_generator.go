@@ -10,6 +10,7 @@ import (
 	"sync"
 
 	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow/config"
 	"github.com/GoCodeAlone/workflow/interfaces"
 	"gopkg.in/yaml.v3"
 )
@@ -17,6 +18,9 @@ import (
 // Compile-time assertion: *OpenAPIGenerator must satisfy interfaces.SchemaRegistrar.
 var _ interfaces.SchemaRegistrar = (*OpenAPIGenerator)(nil)
 
+// Compile-time assertion: *OpenAPIGenerator records QueryHandler response examples.
+var _ ResponseExampleRecorder = (*OpenAPIGenerator)(nil)
+
 // --- OpenAPI 3.0 spec structs (minimal inline definitions) ---
 
 // OpenAPISpec represents a minimal OpenAPI 3.0 specification document.
@@ -85,7 +89,15 @@ type OpenAPIResponse struct {
 
 // OpenAPIMediaType describes a media type with schema.
 type OpenAPIMediaType struct {
-	Schema *OpenAPISchema `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Schema   *OpenAPISchema             `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Example  any                        `json:"example,omitempty" yaml:"example,omitempty"`
+	Examples map[string]*OpenAPIExample `json:"examples,omitempty" yaml:"examples,omitempty"`
+}
+
+// OpenAPIExample is a named example of a media type.
+type OpenAPIExample struct {
+	Summary string `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Value   any    `json:"value" yaml:"value"`
 }
 
 // OpenAPISchema is a minimal JSON Schema subset for OpenAPI.
@@ -101,6 +113,8 @@ type OpenAPISchema struct {
 	AdditionalProperties *OpenAPISchema            `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
 	Nullable             bool                      `json:"nullable,omitempty" yaml:"nullable,omitempty"`
 	Example              any                       `json:"example,omitempty" yaml:"example,omitempty"`
+	// Sensitive marks a value that is scrubbed from recorded response examples.
+	Sensitive bool `json:"x-sensitive,omitempty" yaml:"x-sensitive,omitempty"`
 }
 
 // OpenAPIComponents holds reusable schema components.
@@ -128,6 +142,10 @@ type OpenAPIGenerator struct {
 	mu         sync.RWMutex
 	opSchemas  map[string]*operationSchemaOverride // key: "METHOD path"
 	compSchema map[string]*OpenAPISchema           // component schemas to add
+	examples   map[string]any                      // recorded 200 response examples, key: "METHOD path"
+	// routeExamples are the examples declared on routes, key: "METHOD path".
+	routeExamples map[string][]config.RouteExample
+	exampleErrs   []string // invalid examples blocks
 }
 
 // operationSchemaOverride holds request/response schema overrides for a specific operation.
@@ -187,8 +205,10 @@ func (g *OpenAPIGenerator) BuildSpec(workflows map[string]any) {
 	for workflowType, wfConfig := range workflows {
 		g.extractRoutes(spec, workflowType, wfConfig)
 	}
+	withProblemSchema(spec)
 
 	g.spec = spec
+	g.applyExamples()
 }
 
 // pathParamRegex matches {paramName} in route paths.
@@ -236,6 +256,9 @@ func (g *OpenAPIGenerator) extractRoutes(spec *OpenAPISpec, workflowType string,
 
 		// Build operation
 		op := g.buildOperation(method, path, handler, workflowType, route)
+		if raw, ok := route["examples"]; ok {
+			_ = g.setRouteExamples(method, path, raw)
+		}
 
 		// Get or create path item
 		pathItem, exists := spec.Paths[path]
@@ -336,15 +359,15 @@ func (g *OpenAPIGenerator) buildOperation(method, path, handler, workflowType st
 			},
 		},
 	}
-	op.Responses["400"] = &OpenAPIResponse{Description: "Bad request"}
-	op.Responses["500"] = &OpenAPIResponse{Description: "Internal server error"}
+	op.Responses["400"] = problemResponse("Bad request")
+	op.Responses["500"] = problemResponse("Internal server error")
 
 	// Check middlewares for auth → add 401
 	if middlewares, ok := route["middlewares"].([]any); ok {
 		for _, mw := range middlewares {
 			if mwStr, ok := mw.(string); ok && strings.Contains(mwStr, "auth") {
-				op.Responses["401"] = &OpenAPIResponse{Description: "Unauthorized"}
-				op.Responses["403"] = &OpenAPIResponse{Description: "Forbidden"}
+				op.Responses["401"] = problemResponse("Unauthorized")
+				op.Responses["403"] = problemResponse("Forbidden")
 				break
 			}
 		}
@@ -353,6 +376,28 @@ func (g *OpenAPIGenerator) buildOperation(method, path, handler, workflowType st
 	return op
 }
 
+// problemResponse describes an error response carrying a Problem.
+func problemResponse(description string) *OpenAPIResponse {
+	return &OpenAPIResponse{
+		Description: description,
+		Content: map[string]*OpenAPIMediaType{
+			ProblemContentType: {Schema: SchemaRef("Problem")},
+		},
+	}
+}
+
+// withProblemSchema adds the Problem schema referenced by error responses to
+// the spec's components.
+func withProblemSchema(spec *OpenAPISpec) {
+	if spec.Components == nil {
+		spec.Components = &OpenAPIComponents{}
+	}
+	if spec.Components.Schemas == nil {
+		spec.Components.Schemas = make(map[string]*OpenAPISchema)
+	}
+	spec.Components.Schemas["Problem"] = problemOpenAPISchema()
+}
+
 // generateOperationID creates a camelCase operation ID from method + path.
 func generateOperationID(method, path string) string {
 	// Remove leading slash and replace special chars
@@ -536,8 +581,10 @@ func (g *OpenAPIGenerator) BuildSpecFromRoutes(routes []RouteDefinition) {
 			pathItem.Patch = op
 		}
 	}
+	withProblemSchema(spec)
 
 	g.spec = spec
+	g.applyExamples()
 }
 
 // SortedPaths returns the spec paths sorted alphabetically (useful for stable output).
@@ -684,6 +731,8 @@ func (g *OpenAPIGenerator) ApplySchemas() {
 			op.Tags = override.Tags
 		}
 	}
+
+	g.applyExamples()
 }
 
 // RegisterAdminSchemas satisfies the interfaces.SchemaRegistrar interface.
@@ -692,3 +741,123 @@ func (g *OpenAPIGenerator) ApplySchemas() {
 func (g *OpenAPIGenerator) RegisterAdminSchemas() {
 	RegisterAdminSchemas(g)
 }
+
+// --- Response Examples ---
+
+// HasResponseExample reports whether an example has been recorded for the
+// operation.
+func (g *OpenAPIGenerator) HasResponseExample(method, path string) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	_, ok := g.examples[strings.ToUpper(method)+" "+path]
+	return ok
+}
+
+// RecordResponseExample sets body, a sample successful response, as the
+// example of the operation's 200 response. Only the first example recorded
+// for an operation is kept. Values that the operation's response schema
+// marks Sensitive, or formats as "password", are replaced with
+// RedactionPlaceholder.
+func (g *OpenAPIGenerator) RecordResponseExample(method, path string, body any) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	key := strings.ToUpper(method) + " " + path
+	if _, ok := g.examples[key]; ok {
+		return
+	}
+	var schema *OpenAPISchema
+	if override := g.opSchemas[key]; override != nil {
+		schema = override.ResponseSchema
+	}
+	if g.examples == nil {
+		g.examples = make(map[string]any)
+	}
+	g.examples[key] = g.scrubExample(body, schema, 0)
+	g.applyExamples()
+}
+
+// maxExampleDepth bounds how deep scrubExample descends. Deeper values are
+// redacted, since recursive schemas could mark them sensitive.
+const maxExampleDepth = 32
+
+// scrubExample returns a copy of v with the sensitive values of schema
+// redacted. Values without a schema are copied as is.
+func (g *OpenAPIGenerator) scrubExample(v any, schema *OpenAPISchema, depth int) any {
+	if depth > maxExampleDepth {
+		return RedactionPlaceholder
+	}
+	if schema != nil && schema.Ref != "" {
+		schema = g.compSchema[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+	}
+	if schema != nil && (schema.Sensitive || schema.Format == "password") {
+		return RedactionPlaceholder
+	}
+	switch val := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			var propSchema *OpenAPISchema
+			if schema != nil {
+				propSchema = schema.Properties[k]
+				if propSchema == nil {
+					propSchema = schema.AdditionalProperties
+				}
+			}
+			out[k] = g.scrubExample(item, propSchema, depth+1)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		var itemSchema *OpenAPISchema
+		if schema != nil {
+			itemSchema = schema.Items
+		}
+		for i, item := range val {
+			out[i] = g.scrubExample(item, itemSchema, depth+1)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// applyExamples sets the declared route examples and the recorded examples
+// on the operations of the current spec. A recorded example is not set on a
+// response that has declared examples. The caller must hold g.mu.
+func (g *OpenAPIGenerator) applyExamples() {
+	if g.spec == nil {
+		return
+	}
+	g.applyRouteExamples()
+	for key, example := range g.examples {
+		method, path, _ := strings.Cut(key, " ")
+		pathItem, exists := g.spec.Paths[path]
+		if !exists {
+			continue
+		}
+		var op *OpenAPIOperation
+		switch strings.ToLower(method) {
+		case "get":
+			op = pathItem.Get
+		case "post":
+			op = pathItem.Post
+		case "put":
+			op = pathItem.Put
+		case "delete":
+			op = pathItem.Delete
+		case "patch":
+			op = pathItem.Patch
+		}
+		if op == nil {
+			continue
+		}
+		resp := op.Responses["200"]
+		if resp == nil {
+			continue
+		}
+		if media := resp.Content["application/json"]; media != nil && len(media.Examples) == 0 {
+			media.Example = example
+		}
+	}
+}
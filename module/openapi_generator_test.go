@@ -146,6 +146,15 @@ func TestOpenAPIGeneratorBuildSpec(t *testing.T) {
 		}
 	}
 
+	// Error responses carry the Problem schema.
+	if resp := usersPath.Get.Responses["500"]; resp == nil || resp.Content[ProblemContentType] == nil ||
+		resp.Content[ProblemContentType].Schema.Ref != "#/components/schemas/Problem" {
+		t.Errorf("expected 500 response to reference the Problem schema, got %+v", resp)
+	}
+	if spec.Components == nil || spec.Components.Schemas["Problem"] == nil {
+		t.Error("expected Problem schema in components")
+	}
+
 	// Check path params
 	userByIDPath := spec.Paths["/api/v1/users/{id}"]
 	if userByIDPath == nil {
@@ -837,3 +846,57 @@ func TestOpenAPIServeJSONParseable(t *testing.T) {
 		t.Error("expected OpenAPI version in JSON output")
 	}
 }
+
+func TestOpenAPIGeneratorRecordResponseExample(t *testing.T) {
+	g := NewOpenAPIGenerator("test", OpenAPIGeneratorConfig{})
+	g.RegisterComponentSchema("Credentials", &OpenAPISchema{
+		Type: "object",
+		Properties: map[string]*OpenAPISchema{
+			"user":   {Type: "string"},
+			"apiKey": {Type: "string", Sensitive: true},
+		},
+	})
+	g.SetOperationSchema("GET", "/api/accounts/{id}", nil, &OpenAPISchema{
+		Type: "object",
+		Properties: map[string]*OpenAPISchema{
+			"id":          {Type: "string"},
+			"password":    {Type: "string", Format: "password"},
+			"credentials": SchemaArray(SchemaRef("Credentials")),
+		},
+	})
+	g.BuildSpecFromRoutes([]RouteDefinition{{Method: "GET", Path: "/api/accounts/{id}"}})
+	g.ApplySchemas()
+
+	if g.HasResponseExample("GET", "/api/accounts/{id}") {
+		t.Fatal("expected no example before one is recorded")
+	}
+	g.RecordResponseExample("GET", "/api/accounts/{id}", map[string]any{
+		"id":          "acc-1",
+		"password":    "hunter2",
+		"credentials": []any{map[string]any{"user": "ci", "apiKey": "sk-live-123"}},
+	})
+	g.RecordResponseExample("GET", "/api/accounts/{id}", map[string]any{"id": "acc-2"})
+
+	example := g.GetSpec().Paths["/api/accounts/{id}"].Get.Responses["200"].Content["application/json"].Example
+	data, err := json.Marshal(example)
+	if err != nil {
+		t.Fatalf("marshal example: %v", err)
+	}
+	got := string(data)
+	for _, secret := range []string{"hunter2", "sk-live-123", "acc-2"} {
+		if strings.Contains(got, secret) {
+			t.Errorf("example should not contain %q: %s", secret, got)
+		}
+	}
+	for _, want := range []string{`"id":"acc-1"`, `"user":"ci"`, `"apiKey":"[REDACTED]"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("example should contain %s: %s", want, got)
+		}
+	}
+
+	// Examples survive rebuilding the spec.
+	g.BuildSpecFromRoutes([]RouteDefinition{{Method: "GET", Path: "/api/accounts/{id}"}})
+	if g.GetSpec().Paths["/api/accounts/{id}"].Get.Responses["200"].Content["application/json"].Example == nil {
+		t.Error("expected the example to be kept after BuildSpecFromRoutes")
+	}
+}
@@ -0,0 +1,321 @@
+package module
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/GoCodeAlone/workflow/config"
+)
+
+// --- Declared Route Examples ---
+
+// SetRouteExamples parses raw, the `examples` block of the route, and sets
+// the examples on the operation. An invalid block is reported by
+// CheckRouteExamples as well as returned.
+func (g *OpenAPIGenerator) SetRouteExamples(method, path string, raw any) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	err := g.setRouteExamples(method, path, raw)
+	g.applyExamples()
+	return err
+}
+
+// setRouteExamples is SetRouteExamples without locking and without updating
+// the spec.
+func (g *OpenAPIGenerator) setRouteExamples(method, path string, raw any) error {
+	key := strings.ToUpper(method) + " " + path
+	examples, err := config.ParseRouteExamples(raw)
+	if err != nil {
+		g.exampleErrs = append(g.exampleErrs, fmt.Sprintf("%s: %v", key, err))
+		return err
+	}
+	if g.routeExamples == nil {
+		g.routeExamples = make(map[string][]config.RouteExample)
+	}
+	if len(examples) == 0 {
+		delete(g.routeExamples, key)
+	} else {
+		g.routeExamples[key] = examples
+	}
+	return nil
+}
+
+// RouteExamples returns the examples declared on the operation, in
+// declaration order.
+func (g *OpenAPIGenerator) RouteExamples(method, path string) []config.RouteExample {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.routeExamples[strings.ToUpper(method)+" "+path]
+}
+
+// specOperation returns the operation of spec for method and path, or nil.
+func specOperation(spec *OpenAPISpec, method, path string) *OpenAPIOperation {
+	pathItem := spec.Paths[path]
+	if pathItem == nil {
+		return nil
+	}
+	switch strings.ToLower(method) {
+	case "get":
+		return pathItem.Get
+	case "post":
+		return pathItem.Post
+	case "put":
+		return pathItem.Put
+	case "delete":
+		return pathItem.Delete
+	case "patch":
+		return pathItem.Patch
+	case "options":
+		return pathItem.Options
+	}
+	return nil
+}
+
+// applyRouteExamples sets the declared examples on the request bodies and
+// responses of the current spec. Responses with an example status the route
+// does not describe yet are added. The caller must hold g.mu.
+func (g *OpenAPIGenerator) applyRouteExamples() {
+	for key, examples := range g.routeExamples {
+		method, path, _ := strings.Cut(key, " ")
+		op := specOperation(g.spec, method, path)
+		if op == nil {
+			continue
+		}
+		for _, ex := range examples {
+			if ex.Request != nil && ex.Request.Body != nil && op.RequestBody != nil {
+				if op.RequestBody.Content == nil {
+					op.RequestBody.Content = make(map[string]*OpenAPIMediaType)
+				}
+				media := op.RequestBody.Content["application/json"]
+				if media == nil {
+					media = &OpenAPIMediaType{}
+					op.RequestBody.Content["application/json"] = media
+				}
+				setMediaExample(media, ex, ex.Request.Body)
+			}
+
+			code := strconv.Itoa(ex.Response.Status)
+			resp := op.Responses[code]
+			if resp == nil {
+				desc := http.StatusText(ex.Response.Status)
+				if desc == "" {
+					desc = "Example response"
+				}
+				resp = &OpenAPIResponse{Description: desc}
+				op.Responses[code] = resp
+			}
+			if ex.Response.Body == nil {
+				continue
+			}
+			if resp.Content == nil {
+				resp.Content = make(map[string]*OpenAPIMediaType)
+			}
+			setMediaExample(exampleMedia(resp.Content), ex, ex.Response.Body)
+		}
+	}
+}
+
+// exampleMedia returns the JSON media type of content, creating it when content
+// has no media type yet.
+func exampleMedia(content map[string]*OpenAPIMediaType) *OpenAPIMediaType {
+	if m := content["application/json"]; m != nil {
+		return m
+	}
+	types := make([]string, 0, len(content))
+	for t := range content {
+		types = append(types, t)
+	}
+	if len(types) > 0 {
+		sort.Strings(types)
+		return content[types[0]]
+	}
+	m := &OpenAPIMediaType{}
+	content["application/json"] = m
+	return m
+}
+
+func setMediaExample(m *OpenAPIMediaType, ex config.RouteExample, value any) {
+	if m.Examples == nil {
+		m.Examples = make(map[string]*OpenAPIExample)
+	}
+	m.Examples[ex.Name] = &OpenAPIExample{Summary: ex.Summary, Value: value}
+	// example and examples are mutually exclusive in OpenAPI.
+	m.Example = nil
+}
+
+// CheckRouteExamples checks the declared examples against the request and
+// response schemas of the current spec. errs lists invalid examples blocks;
+// warnings lists example bodies that do not match their schema, such as a
+// wrong type or a missing required property. Both are sorted.
+func (g *OpenAPIGenerator) CheckRouteExamples() (errs, warnings []string) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	errs = append(errs, g.exampleErrs...)
+	sort.Strings(errs)
+	if g.spec == nil {
+		return errs, nil
+	}
+	for key, examples := range g.routeExamples {
+		method, path, _ := strings.Cut(key, " ")
+		op := specOperation(g.spec, method, path)
+		if op == nil {
+			continue
+		}
+		for _, ex := range examples {
+			prefix := fmt.Sprintf("%s: example %q", key, ex.Name)
+			if ex.Request != nil && ex.Request.Body != nil {
+				if op.RequestBody == nil {
+					warnings = append(warnings, prefix+": request has a body but the operation takes none")
+				} else if m := op.RequestBody.Content["application/json"]; m != nil {
+					for _, msg := range g.checkExampleValue(ex.Request.Body, m.Schema, "body", 0) {
+						warnings = append(warnings, prefix+": request "+msg)
+					}
+				}
+			}
+			resp := op.Responses[strconv.Itoa(ex.Response.Status)]
+			if resp == nil || ex.Response.Body == nil {
+				continue
+			}
+			for _, m := range resp.Content {
+				for _, msg := range g.checkExampleValue(ex.Response.Body, m.Schema, "body", 0) {
+					warnings = append(warnings, fmt.Sprintf("%s: response %d %s", prefix, ex.Response.Status, msg))
+				}
+				break
+			}
+		}
+	}
+	sort.Strings(warnings)
+	return errs, warnings
+}
+
+// resolveSchema follows a component $ref of schema.
+func (g *OpenAPIGenerator) resolveSchema(schema *OpenAPISchema) *OpenAPISchema {
+	for depth := 0; schema != nil && schema.Ref != "" && depth < maxExampleDepth; depth++ {
+		name := strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+		var next *OpenAPISchema
+		if g.spec != nil && g.spec.Components != nil {
+			next = g.spec.Components.Schemas[name]
+		}
+		if next == nil {
+			next = g.compSchema[name]
+		}
+		schema = next
+	}
+	return schema
+}
+
+// checkExampleValue returns the mismatches of v against schema. Schemas are
+// often inferred and loose, so only what the schema states is checked: the
+// type, enum values, required properties, properties and items.
+func (g *OpenAPIGenerator) checkExampleValue(v any, schema *OpenAPISchema, at string, depth int) []string {
+	schema = g.resolveSchema(schema)
+	if schema == nil || depth > maxExampleDepth {
+		return nil
+	}
+	if v == nil {
+		if schema.Type == "" || schema.Nullable {
+			return nil
+		}
+		return []string{fmt.Sprintf("%s: expected %s, got null", at, schema.Type)}
+	}
+	if schema.Type != "" && !exampleHasType(v, schema.Type) {
+		return []string{fmt.Sprintf("%s: expected %s, got %s", at, schema.Type, exampleTypeName(v))}
+	}
+
+	var out []string
+	if s, ok := v.(string); ok && len(schema.Enum) > 0 {
+		found := false
+		for _, e := range schema.Enum {
+			if e == s {
+				found = true
+				break
+			}
+		}
+		if !found {
+			out = append(out, fmt.Sprintf("%s: %q is not one of %s", at, s, strings.Join(schema.Enum, ", ")))
+		}
+	}
+	switch val := v.(type) {
+	case map[string]any:
+		for _, name := range schema.Required {
+			if _, ok := val[name]; !ok {
+				out = append(out, fmt.Sprintf("%s.%s: required property is missing", at, name))
+			}
+		}
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			prop := schema.Properties[k]
+			if prop == nil {
+				prop = schema.AdditionalProperties
+			}
+			out = append(out, g.checkExampleValue(val[k], prop, at+"."+k, depth+1)...)
+		}
+	case []any:
+		for i, item := range val {
+			out = append(out, g.checkExampleValue(item, schema.Items, fmt.Sprintf("%s[%d]", at, i), depth+1)...)
+		}
+	}
+	return out
+}
+
+func exampleHasType(v any, typ string) bool {
+	switch typ {
+	case "object":
+		_, ok := v.(map[string]any)
+		return ok
+	case "array":
+		_, ok := v.([]any)
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "integer":
+		switch n := v.(type) {
+		case int, int64, uint64:
+			return true
+		case float64:
+			return n == math.Trunc(n)
+		}
+		return false
+	case "number":
+		switch v.(type) {
+		case int, int64, uint64, float64:
+			return true
+		}
+		return false
+	}
+	return true
+}
+
+func exampleTypeName(v any) string {
+	switch n := v.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int, int64, uint64:
+		return "integer"
+	case float64:
+		if n == math.Trunc(n) {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", v)
+}
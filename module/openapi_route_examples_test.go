@@ -0,0 +1,100 @@
+package module
+
+import (
+	"strings"
+	"testing"
+)
+
+func routeExamplesWorkflows(examples any) map[string]any {
+	return map[string]any{
+		"http": map[string]any{
+			"routes": []any{
+				map[string]any{"method": "POST", "path": "/orders", "handler": "orders", "examples": examples},
+				map[string]any{"method": "GET", "path": "/orders/{id}", "handler": "orders"},
+			},
+		},
+	}
+}
+
+func TestOpenAPIGenerator_RouteExamples(t *testing.T) {
+	gen := NewOpenAPIGenerator("openapi", OpenAPIGeneratorConfig{})
+	gen.BuildSpec(routeExamplesWorkflows([]any{
+		map[string]any{
+			"name":    "created",
+			"summary": "A new order",
+			"request": map[string]any{"body": map[string]any{"sku": "A-1", "quantity": 2}},
+			"response": map[string]any{
+				"status": 201,
+				"body":   map[string]any{"id": 42},
+			},
+		},
+		map[string]any{
+			"name":     "listed",
+			"response": map[string]any{"body": map[string]any{"id": "x"}},
+		},
+	}))
+	gen.SetOperationSchema("POST", "/orders", &OpenAPISchema{
+		Type:       "object",
+		Required:   []string{"sku"},
+		Properties: map[string]*OpenAPISchema{"sku": {Type: "string"}, "quantity": {Type: "integer"}},
+	}, &OpenAPISchema{Type: "object", Properties: map[string]*OpenAPISchema{"id": {Type: "integer"}}})
+	gen.ApplySchemas()
+	// A recorded example does not replace declared ones.
+	gen.RecordResponseExample("POST", "/orders", map[string]any{"id": 1})
+
+	op := gen.GetSpec().Paths["/orders"].Post
+	reqMedia := op.RequestBody.Content["application/json"]
+	if ex := reqMedia.Examples["created"]; ex == nil || ex.Summary != "A new order" {
+		t.Fatalf("request examples = %v", reqMedia.Examples)
+	}
+	created := op.Responses["201"]
+	if created == nil || created.Description != "Created" || created.Content["application/json"].Examples["created"] == nil {
+		t.Fatalf("201 response = %+v", created)
+	}
+	ok := op.Responses["200"].Content["application/json"]
+	if ok.Examples["listed"] == nil || ok.Example != nil {
+		t.Errorf("200 media = %+v", ok)
+	}
+	if got := gen.RouteExamples("post", "/orders"); len(got) != 2 || got[0].Name != "created" {
+		t.Errorf("RouteExamples = %+v", got)
+	}
+
+	errs, warnings := gen.CheckRouteExamples()
+	if len(errs) != 0 {
+		t.Errorf("errs = %v", errs)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], `POST /orders: example "listed": response 200 body.id: expected integer, got string`) {
+		t.Errorf("warnings = %v", warnings)
+	}
+}
+
+func TestOpenAPIGenerator_CheckRouteExamples(t *testing.T) {
+	gen := NewOpenAPIGenerator("openapi", OpenAPIGeneratorConfig{})
+	gen.BuildSpec(routeExamplesWorkflows([]any{map[string]any{"name": "bad"}}))
+	if err := gen.SetRouteExamples("GET", "/orders/{id}", []any{map[string]any{
+		"name":     "with-body",
+		"request":  map[string]any{"body": map[string]any{"x": 1}},
+		"response": map[string]any{"status": 400, "body": map[string]any{"title": "Bad Request", "status": "400"}},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	errs, warnings := gen.CheckRouteExamples()
+	if len(errs) != 1 || !strings.Contains(errs[0], "POST /orders: examples[0]: example \"bad\": 'response' is required") {
+		t.Errorf("errs = %v", errs)
+	}
+	want := `GET /orders/{id}: example "with-body": request has a body but the operation takes none`
+	if len(warnings) == 0 || warnings[0] != want {
+		t.Fatalf("warnings = %v", warnings)
+	}
+	// The 400 example is checked against the Problem schema.
+	var problemWarning bool
+	for _, w := range warnings {
+		if strings.Contains(w, "response 400 body.status: expected integer, got string") {
+			problemWarning = true
+		}
+	}
+	if !problemWarning {
+		t.Errorf("warnings = %v", warnings)
+	}
+}
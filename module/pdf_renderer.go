@@ -0,0 +1,755 @@
+package module
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// PDFPageOptions controls the page layout used by RenderHTMLToPDF. Lengths
+// are in millimetres.
+type PDFPageOptions struct {
+	// PageSize is A3, A4, A5, Letter, Legal, or Tabloid (default A4).
+	PageSize string
+	// Landscape rotates the page.
+	Landscape bool
+	// Margins around the content area.
+	MarginTop, MarginRight, MarginBottom, MarginLeft float64
+	// AssetsDir is the directory <img src> file paths are resolved against.
+	// When empty only data: URI images are allowed.
+	AssetsDir string
+}
+
+// pdfPageSizes lists the supported page sizes by lower-case name.
+var pdfPageSizes = map[string]string{
+	"a3": "A3", "a4": "A4", "a5": "A5", "letter": "Letter", "legal": "Legal", "tabloid": "Tabloid",
+}
+
+// RenderHTMLToPDF lays out an HTML document as a PDF and returns its bytes
+// and page count.
+//
+// The renderer is pure Go and supports the subset of HTML used by documents
+// such as invoices and receipts: headings, paragraphs, divs, line breaks,
+// bold/italic/underline/code text, links, ordered and unordered lists,
+// horizontal rules, tables (with repeating <thead> rows), and PNG/JPEG/GIF
+// images from data: URIs or AssetsDir. The style attribute honours
+// text-align, font-size, font-weight, color, and page-break-before/after.
+// Scripts, stylesheets, and other CSS are ignored. Text is encoded as
+// Windows-1252, so characters outside it are replaced.
+func RenderHTMLToPDF(src string, opts PDFPageOptions) ([]byte, int, error) {
+	doc, err := html.Parse(strings.NewReader(src))
+	if err != nil {
+		return nil, 0, fmt.Errorf("parse html: %w", err)
+	}
+
+	size := "A4"
+	if opts.PageSize != "" {
+		s, ok := pdfPageSizes[strings.ToLower(opts.PageSize)]
+		if !ok {
+			return nil, 0, fmt.Errorf("unsupported page size %q", opts.PageSize)
+		}
+		size = s
+	}
+	orientation := "P"
+	if opts.Landscape {
+		orientation = "L"
+	}
+
+	pdf := fpdf.NewCustom(&fpdf.InitType{OrientationStr: orientation, UnitStr: "mm", SizeStr: size})
+	pdf.SetMargins(opts.MarginLeft, opts.MarginTop, opts.MarginRight)
+	pdf.SetAutoPageBreak(true, opts.MarginBottom)
+	pdf.SetCreator("workflow step.pdf_render", false)
+	pdf.AddPage()
+
+	r := &pdfRenderer{
+		pdf:       pdf,
+		tr:        pdf.UnicodeTranslatorFromDescriptor(""),
+		assetsDir: opts.AssetsDir,
+		left:      opts.MarginLeft,
+		style:     pdfTextStyle{size: pdfBaseFontSize},
+		lineStart: true,
+	}
+	r.applyFont()
+	r.render(doc)
+	if r.err != nil {
+		return nil, 0, r.err
+	}
+	if pdf.Err() {
+		return nil, 0, pdf.Error()
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, 0, err
+	}
+	return buf.Bytes(), pdf.PageCount(), nil
+}
+
+const (
+	pdfBaseFontSize = 11.0 // pt
+	pdfPtToMM       = 0.3528
+	pdfPxToMM       = 0.2646 // CSS px at 96 dpi
+	pdfLineFactor   = 1.4
+	pdfListIndent   = 7.0
+	pdfCellPadding  = 1.5
+)
+
+var pdfHeadingSizes = map[atom.Atom]float64{
+	atom.H1: 22, atom.H2: 18, atom.H3: 15, atom.H4: 13, atom.H5: 12, atom.H6: 11,
+}
+
+type pdfTextStyle struct {
+	bold, italic, underline, mono bool
+	size                          float64
+	color                         [3]int
+}
+
+type pdfRenderer struct {
+	pdf       *fpdf.Fpdf
+	tr        func(string) string
+	assetsDir string
+	left      float64 // current left edge, including list indentation
+	style     pdfTextStyle
+	link      string
+	lineStart bool // nothing written on the current line yet
+	images    int
+	err       error
+}
+
+func (r *pdfRenderer) lineHeight() float64 {
+	return r.style.size * pdfPtToMM * pdfLineFactor
+}
+
+func (r *pdfRenderer) applyFont() {
+	family := "Helvetica"
+	if r.style.mono {
+		family = "Courier"
+	}
+	var s string
+	if r.style.bold {
+		s += "B"
+	}
+	if r.style.italic {
+		s += "I"
+	}
+	if r.style.underline {
+		s += "U"
+	}
+	r.pdf.SetFont(family, s, r.style.size)
+	r.pdf.SetTextColor(r.style.color[0], r.style.color[1], r.style.color[2])
+}
+
+// withStyle renders fn with a modified text style, restoring it afterwards.
+func (r *pdfRenderer) withStyle(modify func(*pdfTextStyle), fn func()) {
+	saved := r.style
+	modify(&r.style)
+	r.applyFont()
+	fn()
+	r.style = saved
+	r.applyFont()
+}
+
+// newLine ends the current line if anything has been written on it.
+func (r *pdfRenderer) newLine() {
+	if !r.lineStart {
+		r.pdf.Ln(r.lineHeight())
+		r.lineStart = true
+	}
+	r.pdf.SetX(r.left)
+}
+
+func (r *pdfRenderer) space(mm float64) {
+	r.pdf.Ln(mm)
+	r.pdf.SetX(r.left)
+}
+
+func (r *pdfRenderer) render(n *html.Node) {
+	if r.err != nil {
+		return
+	}
+	switch n.Type {
+	case html.TextNode:
+		r.text(n.Data)
+	case html.ElementNode:
+		r.element(n)
+	default:
+		r.children(n)
+	}
+}
+
+func (r *pdfRenderer) children(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		r.render(c)
+	}
+}
+
+var pdfSpaceRe = regexp.MustCompile(`\s+`)
+
+func (r *pdfRenderer) text(s string) {
+	s = pdfSpaceRe.ReplaceAllString(s, " ")
+	if r.lineStart {
+		s = strings.TrimLeft(s, " ")
+	}
+	if s == "" {
+		return
+	}
+	if r.link != "" {
+		r.pdf.WriteLinkString(r.lineHeight(), r.tr(s), r.link)
+	} else {
+		r.pdf.Write(r.lineHeight(), r.tr(s))
+	}
+	r.lineStart = false
+}
+
+func (r *pdfRenderer) element(n *html.Node) {
+	css := parseInlineStyle(pdfAttr(n, "style"))
+	if pageBreak(css, "before") {
+		r.newLine()
+		r.pdf.AddPage()
+		r.pdf.SetX(r.left)
+	}
+	defer func() {
+		if pageBreak(css, "after") {
+			r.newLine()
+			r.pdf.AddPage()
+			r.pdf.SetX(r.left)
+		}
+	}()
+
+	switch n.DataAtom {
+	case atom.Head:
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.DataAtom == atom.Title {
+				r.pdf.SetTitle(strings.TrimSpace(nodeText(c, false)), true)
+			}
+		}
+		return
+	case atom.Script, atom.Style, atom.Template, atom.Noscript, atom.Title:
+		return
+	case atom.Br:
+		r.pdf.Ln(r.lineHeight())
+		r.pdf.SetX(r.left)
+		r.lineStart = true
+		return
+	case atom.Hr:
+		r.newLine()
+		r.space(1.5)
+		w, _ := r.pdf.GetPageSize()
+		_, _, right, _ := r.pdf.GetMargins()
+		y := r.pdf.GetY()
+		r.pdf.SetDrawColor(160, 160, 160)
+		r.pdf.Line(r.left, y, w-right, y)
+		r.space(2.5)
+		return
+	case atom.Img:
+		r.image(n)
+		return
+	case atom.Table:
+		r.newLine()
+		r.table(n)
+		r.space(2)
+		return
+	case atom.Ul, atom.Ol:
+		r.list(n)
+		return
+	case atom.Pre:
+		r.newLine()
+		r.withStyle(func(s *pdfTextStyle) { s.mono = true; s.size = pdfBaseFontSize - 1 }, func() {
+			r.pdf.MultiCell(0, r.lineHeight(), r.tr(strings.Trim(nodeText(n, true), "\n")), "", "L", false)
+		})
+		r.pdf.SetX(r.left)
+		r.space(2)
+		return
+	}
+
+	if size, ok := pdfHeadingSizes[n.DataAtom]; ok {
+		r.block(n, css, 2, func(s *pdfTextStyle) { s.bold = true; s.size = size })
+		return
+	}
+	switch n.DataAtom {
+	case atom.P:
+		r.block(n, css, 2, nil)
+	case atom.Div, atom.Section, atom.Article, atom.Header, atom.Footer, atom.Main,
+		atom.Address, atom.Blockquote, atom.Figure, atom.Figcaption, atom.Li, atom.Dl, atom.Dt, atom.Dd:
+		r.block(n, css, 0, nil)
+	case atom.B, atom.Strong, atom.Th:
+		r.inline(n, css, func(s *pdfTextStyle) { s.bold = true })
+	case atom.I, atom.Em, atom.Cite:
+		r.inline(n, css, func(s *pdfTextStyle) { s.italic = true })
+	case atom.U, atom.Ins:
+		r.inline(n, css, func(s *pdfTextStyle) { s.underline = true })
+	case atom.Code, atom.Kbd, atom.Samp, atom.Tt:
+		r.inline(n, css, func(s *pdfTextStyle) { s.mono = true })
+	case atom.Small:
+		r.inline(n, css, func(s *pdfTextStyle) { s.size *= 0.85 })
+	case atom.A:
+		saved := r.link
+		if href := pdfAttr(n, "href"); href != "" {
+			r.link = href
+		}
+		r.inline(n, css, func(s *pdfTextStyle) { s.underline = true; s.color = [3]int{0, 0, 180} })
+		r.link = saved
+	default:
+		r.inline(n, css, nil)
+	}
+}
+
+// inline renders an element's children with a style change and any
+// style-attribute overrides.
+func (r *pdfRenderer) inline(n *html.Node, css map[string]string, modify func(*pdfTextStyle)) {
+	r.withStyle(func(s *pdfTextStyle) {
+		if modify != nil {
+			modify(s)
+		}
+		applyCSSText(s, css)
+	}, func() { r.children(n) })
+}
+
+// block renders an element on its own lines followed by after mm of space.
+// Centred and right-aligned blocks are laid out as a single run of text in
+// the block's style.
+func (r *pdfRenderer) block(n *html.Node, css map[string]string, after float64, modify func(*pdfTextStyle)) {
+	r.newLine()
+	align := alignOf(n, css)
+	r.withStyle(func(s *pdfTextStyle) {
+		if modify != nil {
+			modify(s)
+		}
+		applyCSSText(s, css)
+	}, func() {
+		if align == "C" || align == "R" {
+			r.pdf.MultiCell(0, r.lineHeight(), r.tr(strings.TrimSpace(nodeText(n, false))), "", align, false)
+			r.pdf.SetX(r.left)
+			r.lineStart = true
+			return
+		}
+		r.children(n)
+		r.newLine()
+	})
+	if after > 0 {
+		r.space(after)
+	}
+}
+
+func (r *pdfRenderer) list(n *html.Node) {
+	r.newLine()
+	savedLeft := r.left
+	r.left += pdfListIndent
+	r.pdf.SetLeftMargin(r.left)
+	i := 0
+	if start, err := strconv.Atoi(pdfAttr(n, "start")); err == nil {
+		i = start - 1
+	}
+	for c := n.FirstChild; c != nil && r.err == nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || c.DataAtom != atom.Li {
+			continue
+		}
+		i++
+		marker := "•"
+		if n.DataAtom == atom.Ol {
+			marker = strconv.Itoa(i) + "."
+		}
+		r.newLine()
+		r.pdf.SetX(r.left - pdfListIndent + 1)
+		r.pdf.CellFormat(pdfListIndent-1, r.lineHeight(), r.tr(marker), "", 0, "L", false, 0, "")
+		r.lineStart = false
+		r.children(c)
+		r.newLine()
+	}
+	r.left = savedLeft
+	r.pdf.SetLeftMargin(r.left)
+	r.pdf.SetX(r.left)
+	r.space(1)
+}
+
+// image draws an <img> on its own line, scaled to fit the content width.
+func (r *pdfRenderer) image(n *html.Node) {
+	data, imgType, err := r.loadImage(pdfAttr(n, "src"))
+	if err != nil {
+		r.err = err
+		return
+	}
+	r.images++
+	name := fmt.Sprintf("img%d", r.images)
+	opts := fpdf.ImageOptions{ImageType: imgType}
+	info := r.pdf.RegisterImageOptionsReader(name, opts, bytes.NewReader(data))
+	if r.pdf.Err() {
+		r.err = fmt.Errorf("image %q: %w", pdfTruncate(pdfAttr(n, "src"), 40), r.pdf.Error())
+		return
+	}
+
+	w, h := lengthMM(pdfAttr(n, "width")), lengthMM(pdfAttr(n, "height"))
+	switch {
+	case w == 0 && h == 0:
+		// Width/Height are at 72 dpi; lay images out as CSS pixels (96 dpi).
+		w, h = info.Width()*0.75, info.Height()*0.75
+	case w == 0:
+		w = h * info.Width() / info.Height()
+	case h == 0:
+		h = w * info.Height() / info.Width()
+	}
+	pageW, _ := r.pdf.GetPageSize()
+	_, _, right, _ := r.pdf.GetMargins()
+	if maxW := pageW - right - r.left; w > maxW {
+		h = h * maxW / w
+		w = maxW
+	}
+
+	r.newLine()
+	x := r.left
+	switch alignOf(n, parseInlineStyle(pdfAttr(n, "style"))) {
+	case "C":
+		x = r.left + (pageW-right-r.left-w)/2
+	case "R":
+		x = pageW - right - w
+	}
+	r.pdf.ImageOptions(name, x, -1, w, h, true, opts, 0, r.link)
+	r.pdf.SetX(r.left)
+	r.lineStart = true
+}
+
+// loadImage returns image bytes and type for a data: URI or a file under the
+// assets directory. Remote images are not fetched.
+func (r *pdfRenderer) loadImage(src string) ([]byte, string, error) {
+	if rest, ok := strings.CutPrefix(src, "data:"); ok {
+		meta, payload, ok := strings.Cut(rest, ",")
+		if !ok || !strings.HasSuffix(meta, ";base64") {
+			return nil, "", fmt.Errorf("image data URI must be base64 encoded")
+		}
+		imgType := imageTypeOf(strings.TrimSuffix(meta, ";base64"))
+		if imgType == "" {
+			return nil, "", fmt.Errorf("unsupported image type %q (expected png, jpeg, or gif)", meta)
+		}
+		data, err := base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid image data URI: %w", err)
+		}
+		return data, imgType, nil
+	}
+	if strings.Contains(src, "://") {
+		return nil, "", fmt.Errorf("image %q: remote images are not fetched; embed them as data: URIs", pdfTruncate(src, 60))
+	}
+	if r.assetsDir == "" {
+		return nil, "", fmt.Errorf("image %q: file images require assets_dir", src)
+	}
+	imgType := imageTypeOf(filepath.Ext(src))
+	if imgType == "" {
+		return nil, "", fmt.Errorf("image %q: unsupported type (expected png, jpeg, or gif)", src)
+	}
+	// Clean against a rooted path so src cannot escape the assets directory.
+	path := filepath.Join(r.assetsDir, filepath.Clean("/"+src))
+	data, err := os.ReadFile(path) //nolint:gosec // G304: confined to the configured assets directory
+	if err != nil {
+		return nil, "", fmt.Errorf("image %q: %w", src, err)
+	}
+	return data, imgType, nil
+}
+
+func imageTypeOf(s string) string {
+	s = strings.ToLower(s)
+	switch {
+	case strings.HasSuffix(s, "png"):
+		return "PNG"
+	case strings.HasSuffix(s, "jpeg"), strings.HasSuffix(s, "jpg"):
+		return "JPG"
+	case strings.HasSuffix(s, "gif"):
+		return "GIF"
+	}
+	return ""
+}
+
+type pdfCell struct {
+	text   string
+	header bool
+	align  string
+	width  float64 // percentage of the table width, 0 when unset
+}
+
+// table draws a bordered grid. Each cell's content is laid out as plain
+// text; header rows (<thead>) repeat after a page break.
+func (r *pdfRenderer) table(n *html.Node) {
+	var rows [][]pdfCell
+	var headerRows int
+	var walk func(*html.Node, bool)
+	walk = func(node *html.Node, inHead bool) {
+		for c := node.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			switch c.DataAtom {
+			case atom.Thead:
+				walk(c, true)
+			case atom.Tbody, atom.Tfoot:
+				walk(c, false)
+			case atom.Tr:
+				var row []pdfCell
+				for cell := c.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.Type != html.ElementNode || (cell.DataAtom != atom.Td && cell.DataAtom != atom.Th) {
+						continue
+					}
+					css := parseInlineStyle(pdfAttr(cell, "style"))
+					width := css["width"]
+					if width == "" {
+						width = pdfAttr(cell, "width")
+					}
+					pct, _ := strconv.ParseFloat(strings.TrimSuffix(width, "%"), 64)
+					if !strings.HasSuffix(width, "%") {
+						pct = 0
+					}
+					row = append(row, pdfCell{
+						text:   strings.TrimSpace(nodeText(cell, false)),
+						header: inHead || cell.DataAtom == atom.Th,
+						align:  alignOf(cell, css),
+						width:  pct,
+					})
+				}
+				if len(row) > 0 {
+					rows = append(rows, row)
+					if inHead && headerRows == len(rows)-1 {
+						headerRows++
+					}
+				}
+			}
+		}
+	}
+	walk(n, false)
+	if len(rows) == 0 {
+		return
+	}
+
+	cols := 0
+	for _, row := range rows {
+		cols = max(cols, len(row))
+	}
+	pageW, pageH := r.pdf.GetPageSize()
+	_, _, right, bottom := r.pdf.GetMargins()
+	tableW := pageW - right - r.left
+	widths := make([]float64, cols)
+	var fixed, fixedCount float64
+	for i, cell := range rows[0] {
+		if cell.width > 0 {
+			widths[i] = tableW * cell.width / 100
+			fixed += widths[i]
+			fixedCount++
+		}
+	}
+	for i := range widths {
+		if widths[i] == 0 {
+			widths[i] = max(tableW-fixed, 0) / (float64(cols) - fixedCount)
+		}
+	}
+
+	lh := r.lineHeight()
+	r.pdf.SetAutoPageBreak(false, bottom)
+	defer r.pdf.SetAutoPageBreak(true, bottom)
+	drawRow := func(row []pdfCell) {
+		lines := make([][]string, cols)
+		height := lh
+		for i := 0; i < cols; i++ {
+			cell := pdfCell{}
+			if i < len(row) {
+				cell = row[i]
+			}
+			r.withStyle(func(s *pdfTextStyle) { s.bold = cell.header }, func() {
+				lines[i] = r.pdf.SplitText(r.tr(cell.text), widths[i]-2*pdfCellPadding)
+			})
+			height = max(height, float64(len(lines[i]))*lh)
+		}
+		height += 2 * pdfCellPadding
+
+		y := r.pdf.GetY()
+		if y+height > pageH-bottom {
+			r.pdf.AddPage()
+			y = r.pdf.GetY()
+		}
+		x := r.left
+		r.pdf.SetDrawColor(160, 160, 160)
+		for i := 0; i < cols; i++ {
+			cell := pdfCell{}
+			if i < len(row) {
+				cell = row[i]
+			}
+			style := "D"
+			if cell.header {
+				r.pdf.SetFillColor(235, 235, 235)
+				style = "FD"
+			}
+			r.pdf.Rect(x, y, widths[i], height, style)
+			r.withStyle(func(s *pdfTextStyle) { s.bold = cell.header }, func() {
+				for j, line := range lines[i] {
+					r.pdf.SetXY(x+pdfCellPadding, y+pdfCellPadding+float64(j)*lh)
+					align := cell.align
+					if align == "" {
+						align = "L"
+					}
+					r.pdf.CellFormat(widths[i]-2*pdfCellPadding, lh, line, "", 0, align, false, 0, "")
+				}
+			})
+			x += widths[i]
+		}
+		r.pdf.SetXY(r.left, y+height)
+	}
+
+	for i, row := range rows {
+		if i >= headerRows && headerRows > 0 {
+			// Repeat the header when this row starts a new page.
+			if r.pdf.GetY()+lh+2*pdfCellPadding > pageH-bottom {
+				r.pdf.AddPage()
+				for _, h := range rows[:headerRows] {
+					drawRow(h)
+				}
+			}
+		}
+		drawRow(row)
+	}
+	r.lineStart = true
+}
+
+// nodeText returns the text content of n. Whitespace is collapsed unless
+// preserve is set; <br> becomes a newline.
+func nodeText(n *html.Node, preserve bool) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(node *html.Node) {
+		switch {
+		case node.Type == html.TextNode:
+			if preserve {
+				b.WriteString(node.Data)
+			} else {
+				b.WriteString(pdfSpaceRe.ReplaceAllString(node.Data, " "))
+			}
+		case node.Type == html.ElementNode && node.DataAtom == atom.Br:
+			b.WriteString("\n")
+		case node.Type == html.ElementNode && (node.DataAtom == atom.Script || node.DataAtom == atom.Style):
+		default:
+			for c := node.FirstChild; c != nil; c = c.NextSibling {
+				walk(c)
+			}
+		}
+	}
+	walk(n)
+	if preserve {
+		return b.String()
+	}
+	lines := strings.Split(b.String(), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	return strings.Join(lines, "\n")
+}
+
+func pdfAttr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func parseInlineStyle(style string) map[string]string {
+	if style == "" {
+		return nil
+	}
+	css := map[string]string{}
+	for _, decl := range strings.Split(style, ";") {
+		k, v, ok := strings.Cut(decl, ":")
+		if ok {
+			css[strings.ToLower(strings.TrimSpace(k))] = strings.ToLower(strings.TrimSpace(v))
+		}
+	}
+	return css
+}
+
+func pageBreak(css map[string]string, side string) bool {
+	return css["page-break-"+side] == "always" || css["break-"+side] == "page"
+}
+
+// alignOf returns the fpdf alignment ("L", "C", "R") from the align
+// attribute or text-align style, or "" when unset.
+func alignOf(n *html.Node, css map[string]string) string {
+	a := css["text-align"]
+	if a == "" {
+		a = strings.ToLower(pdfAttr(n, "align"))
+	}
+	switch a {
+	case "center":
+		return "C"
+	case "right":
+		return "R"
+	case "left":
+		return "L"
+	}
+	return ""
+}
+
+func applyCSSText(s *pdfTextStyle, css map[string]string) {
+	switch css["font-weight"] {
+	case "bold", "bolder", "600", "700", "800", "900":
+		s.bold = true
+	case "normal", "400":
+		s.bold = false
+	}
+	if css["font-style"] == "italic" {
+		s.italic = true
+	}
+	if v := css["font-size"]; v != "" {
+		switch {
+		case strings.HasSuffix(v, "pt"):
+			if f, err := strconv.ParseFloat(strings.TrimSuffix(v, "pt"), 64); err == nil && f > 0 {
+				s.size = f
+			}
+		case strings.HasSuffix(v, "px"):
+			if f, err := strconv.ParseFloat(strings.TrimSuffix(v, "px"), 64); err == nil && f > 0 {
+				s.size = f * 0.75
+			}
+		}
+	}
+	if c, ok := parseHexColor(css["color"]); ok {
+		s.color = c
+	}
+}
+
+func parseHexColor(v string) ([3]int, bool) {
+	v = strings.TrimPrefix(v, "#")
+	if len(v) == 3 {
+		v = string([]byte{v[0], v[0], v[1], v[1], v[2], v[2]})
+	}
+	if len(v) != 6 {
+		return [3]int{}, false
+	}
+	n, err := strconv.ParseUint(v, 16, 32)
+	if err != nil {
+		return [3]int{}, false
+	}
+	return [3]int{int(n >> 16 & 0xff), int(n >> 8 & 0xff), int(n & 0xff)}, true
+}
+
+// lengthMM converts an HTML length ("120", "120px", "30mm") to millimetres.
+func lengthMM(v string) float64 {
+	v = strings.TrimSpace(strings.ToLower(v))
+	if f, err := strconv.ParseFloat(strings.TrimSuffix(v, "mm"), 64); err == nil && strings.HasSuffix(v, "mm") {
+		return f
+	}
+	f, err := strconv.ParseFloat(strings.TrimSuffix(v, "px"), 64)
+	if err != nil || f <= 0 {
+		return 0
+	}
+	return f * pdfPxToMM
+}
+
+func pdfTruncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,67 @@
+package module
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// --- Pending config changes ---
+
+// SavePendingConfigChange implements PendingConfigPersister, inserting c or
+// replacing the stored change with the same ID.
+func (s *V1Store) SavePendingConfigChange(c PendingConfigChange) error {
+	cfg, err := json.Marshal(c.Config)
+	if err != nil {
+		return fmt.Errorf("marshal config: %w", err)
+	}
+	decidedAt := ""
+	if c.DecidedAt != nil {
+		decidedAt = c.DecidedAt.UTC().Format(time.RFC3339Nano)
+	}
+	_, err = s.db.Exec(
+		`INSERT INTO pending_config_changes (id, environment, author, status, created_at, expires_at, approved_by, decided_by, decided_at, error, config)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(id) DO UPDATE SET status = excluded.status, approved_by = excluded.approved_by,
+		   decided_by = excluded.decided_by, decided_at = excluded.decided_at, error = excluded.error`,
+		c.ID, c.Environment, c.Author, c.Status,
+		c.CreatedAt.UTC().Format(time.RFC3339Nano), c.ExpiresAt.UTC().Format(time.RFC3339Nano),
+		c.ApprovedBy, c.DecidedBy, decidedAt, c.Error, string(cfg),
+	)
+	return err
+}
+
+// ListPendingConfigChanges implements PendingConfigPersister, returning every
+// stored change.
+func (s *V1Store) ListPendingConfigChanges() ([]PendingConfigChange, error) {
+	rows, err := s.db.Query(
+		`SELECT id, environment, author, status, created_at, expires_at, approved_by, decided_by, decided_at, error, config
+		 FROM pending_config_changes ORDER BY created_at, id`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []PendingConfigChange
+	for rows.Next() {
+		var c PendingConfigChange
+		var createdAt, expiresAt, decidedAt, cfg string
+		if err := rows.Scan(&c.ID, &c.Environment, &c.Author, &c.Status, &createdAt, &expiresAt,
+			&c.ApprovedBy, &c.DecidedBy, &decidedAt, &c.Error, &cfg); err != nil {
+			return nil, err
+		}
+		c.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
+		c.ExpiresAt, _ = time.Parse(time.RFC3339Nano, expiresAt)
+		if decidedAt != "" {
+			if t, err := time.Parse(time.RFC3339Nano, decidedAt); err == nil {
+				c.DecidedAt = &t
+			}
+		}
+		if err := json.Unmarshal([]byte(cfg), &c.Config); err != nil {
+			return nil, fmt.Errorf("pending change %q: unmarshal config: %w", c.ID, err)
+		}
+		result = append(result, c)
+	}
+	return result, rows.Err()
+}
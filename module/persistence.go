@@ -139,6 +139,20 @@ func (p *PersistenceStore) migrate() error {
 		`CREATE INDEX IF NOT EXISTS idx_instances_type ON workflow_instances(workflow_type)`,
 		`CREATE INDEX IF NOT EXISTS idx_instances_state ON workflow_instances(current_state)`,
 		`CREATE INDEX IF NOT EXISTS idx_instances_completed ON workflow_instances(completed)`,
+		// Serves per-state counts and filtered instance listings without
+		// scanning other types' rows.
+		`CREATE INDEX IF NOT EXISTS idx_instances_type_state ON workflow_instances(workflow_type, current_state, last_updated)`,
+		`CREATE TABLE IF NOT EXISTS workflow_transitions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			instance_id TEXT NOT NULL,
+			workflow_type TEXT NOT NULL,
+			transition TEXT NOT NULL,
+			from_state TEXT NOT NULL,
+			to_state TEXT NOT NULL,
+			created_at INTEGER NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_transitions_type_time ON workflow_transitions(workflow_type, created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_transitions_time ON workflow_transitions(created_at)`,
 		`CREATE INDEX IF NOT EXISTS idx_resources_type ON resources(resource_type)`,
 	}
 
@@ -229,23 +243,148 @@ func (p *PersistenceStore) LoadWorkflowInstances(workflowType string) ([]*Workfl
 
 	var instances []*WorkflowInstance
 	for rows.Next() {
-		inst, err := scanWorkflowInstance(rows)
+		inst, err := p.scanDecryptedInstance(rows)
 		if err != nil {
 			return nil, err
 		}
+		instances = append(instances, inst)
+	}
+	return instances, rows.Err()
+}
 
-		// Decrypt PII fields after loading
-		if p.encryptor != nil && p.encryptor.Enabled() && inst.Data != nil {
-			decrypted, decErr := p.encryptor.DecryptPIIFields(inst.Data)
-			if decErr != nil {
-				return nil, fmt.Errorf("failed to decrypt instance PII for %s: %w", inst.ID, decErr)
-			}
-			inst.Data = decrypted
+// scanDecryptedInstance scans a workflow instance row and decrypts its PII
+// fields.
+func (p *PersistenceStore) scanDecryptedInstance(rows *sql.Rows) (*WorkflowInstance, error) {
+	inst, err := scanWorkflowInstance(rows)
+	if err != nil {
+		return nil, err
+	}
+	if p.encryptor != nil && p.encryptor.Enabled() && inst.Data != nil {
+		decrypted, decErr := p.encryptor.DecryptPIIFields(inst.Data)
+		if decErr != nil {
+			return nil, fmt.Errorf("failed to decrypt instance PII for %s: %w", inst.ID, decErr)
+		}
+		inst.Data = decrypted
+	}
+	return inst, nil
+}
+
+// ListWorkflowInstances returns one page of the instances matching filter,
+// most recently updated first, and the number of matching instances.
+func (p *PersistenceStore) ListWorkflowInstances(filter StateMachineInstanceFilter) ([]*WorkflowInstance, int, error) {
+	where := ""
+	var args []any
+	for _, c := range []struct{ col, value string }{
+		{"workflow_type", filter.WorkflowType},
+		{"current_state", filter.State},
+	} {
+		if c.value == "" {
+			continue
+		}
+		if where == "" {
+			where = " WHERE "
+		} else {
+			where += " AND "
 		}
+		where += c.col + " = ?"
+		args = append(args, c.value)
+	}
+
+	var total int
+	if err := p.db.QueryRow(`SELECT COUNT(*) FROM workflow_instances`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := p.db.Query(
+		`SELECT id, workflow_type, current_state, previous_state, data, start_time, last_updated, completed, error_msg
+		FROM workflow_instances`+where+` ORDER BY last_updated DESC, id LIMIT ? OFFSET ?`,
+		append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = rows.Close() }()
 
+	instances := make([]*WorkflowInstance, 0, filter.Limit)
+	for rows.Next() {
+		inst, err := p.scanDecryptedInstance(rows)
+		if err != nil {
+			return nil, 0, err
+		}
 		instances = append(instances, inst)
 	}
-	return instances, rows.Err()
+	return instances, total, rows.Err()
+}
+
+// WorkflowInstanceStateCounts counts the instances of a workflow type in each
+// state, with the earliest last update in each state.
+func (p *PersistenceStore) WorkflowInstanceStateCounts(workflowType string) ([]StateMachineStateStats, error) {
+	rows, err := p.db.Query(
+		`SELECT current_state, COUNT(*), MIN(last_updated) FROM workflow_instances
+		WHERE workflow_type = ? GROUP BY current_state`, workflowType)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []StateMachineStateStats
+	for rows.Next() {
+		var st StateMachineStateStats
+		var oldest string
+		if err := rows.Scan(&st.State, &st.Count, &oldest); err != nil {
+			return nil, err
+		}
+		if t, err := time.Parse(time.RFC3339Nano, oldest); err == nil {
+			st.OldestSince = &t
+		}
+		out = append(out, st)
+	}
+	return out, rows.Err()
+}
+
+// SaveWorkflowTransition appends a committed transition to the transition log.
+func (p *PersistenceStore) SaveWorkflowTransition(workflowType string, event TransitionEvent) error {
+	_, err := p.db.Exec(`INSERT INTO workflow_transitions (instance_id, workflow_type, transition, from_state, to_state, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		event.WorkflowID, workflowType, event.TransitionID, event.FromState, event.ToState, event.Timestamp.UnixMilli())
+	return err
+}
+
+// WorkflowTransitionCounts counts the logged transitions of a workflow type
+// since a time, by transition name.
+func (p *PersistenceStore) WorkflowTransitionCounts(workflowType string, since time.Time) (map[string]int64, error) {
+	rows, err := p.db.Query(
+		`SELECT transition, COUNT(*) FROM workflow_transitions
+		WHERE workflow_type = ? AND created_at >= ? GROUP BY transition`, workflowType, since.UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	counts := make(map[string]int64)
+	for rows.Next() {
+		var name string
+		var n int64
+		if err := rows.Scan(&name, &n); err != nil {
+			return nil, err
+		}
+		counts[name] = n
+	}
+	return counts, rows.Err()
+}
+
+// PruneWorkflowTransitions deletes transition log entries older than before.
+func (p *PersistenceStore) PruneWorkflowTransitions(before time.Time) (int64, error) {
+	res, err := p.db.Exec(`DELETE FROM workflow_transitions WHERE created_at < ?`, before.UnixMilli())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// DeleteWorkflowInstance deletes a persisted workflow instance.
+func (p *PersistenceStore) DeleteWorkflowInstance(id string) error {
+	_, err := p.db.Exec(`DELETE FROM workflow_instances WHERE id = ?`, id)
+	return err
 }
 
 func scanWorkflowInstance(rows *sql.Rows) (*WorkflowInstance, error) {
@@ -0,0 +1,85 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+
+	evstore "github.com/GoCodeAlone/workflow/store"
+	"github.com/GoCodeAlone/workflow/tenant"
+)
+
+// stepMocksContextKey carries the step mocks of a pipeline to Execute.
+type stepMocksContextKey struct{}
+
+type stepMocks struct {
+	pipeline string
+	mocks    map[string]*evstore.StepMock
+}
+
+// WithStepMocks returns a context under which the named pipeline returns the
+// mock of a top-level step, keyed by step name, instead of executing the
+// step. A mock with an ErrorResponse fails the step with that error.
+func WithStepMocks(ctx context.Context, pipeline string, mocks map[string]*evstore.StepMock) context.Context {
+	return context.WithValue(ctx, stepMocksContextKey{}, &stepMocks{pipeline: pipeline, mocks: mocks})
+}
+
+// mockedStep returns the result of the mock of step under ctx, and whether
+// the step is mocked.
+func (p *Pipeline) mockedStep(ctx context.Context, step PipelineStep) (*StepResult, bool, error) {
+	sm, _ := ctx.Value(stepMocksContextKey{}).(*stepMocks)
+	if sm == nil || sm.pipeline != p.Name {
+		return nil, false, nil
+	}
+	mock := sm.mocks[step.Name()]
+	if mock == nil {
+		return nil, false, nil
+	}
+	if mock.Delay > 0 {
+		select {
+		case <-time.After(mock.Delay):
+		case <-ctx.Done():
+			return nil, true, ctx.Err()
+		}
+	}
+	if mock.ErrorResponse != "" {
+		return nil, true, errors.New(mock.ErrorResponse)
+	}
+	return &StepResult{Output: maps.Clone(mock.Response)}, true, nil
+}
+
+// RunBackfill runs the input of a recorded execution through the pipeline
+// for a backfill: the run is not recorded, its steps mocked by run.Mocks
+// return their mocks, and the outputs of the steps that ran are returned.
+// It implements evstore.BackfillRunFunc given a pipeline lookup.
+func RunBackfill(ctx context.Context, p *Pipeline, run evstore.BackfillRun) (map[string]map[string]any, error) {
+	shadow := *p
+	shadow.EventRecorder = nil
+	shadow.ExecutionID = ""
+	shadow.Durable = nil
+
+	ctx = WithStepMocks(ctx, p.Name, run.Mocks)
+	if run.TenantID != "" {
+		ctx = tenant.ContextWithTenant(ctx, run.TenantID)
+	}
+	pc, err := shadow.Execute(ctx, run.Input)
+	if pc == nil {
+		return nil, err
+	}
+	return pc.StepOutputs, err
+}
+
+// BackfillRunFunc returns an evstore.BackfillRunFunc running backfills
+// through the pipelines lookup returns, resolved when each run starts so a
+// reloaded config's pipelines are used.
+func BackfillRunFunc(lookup func(name string) (*Pipeline, bool)) evstore.BackfillRunFunc {
+	return func(ctx context.Context, run evstore.BackfillRun) (map[string]map[string]any, error) {
+		p, ok := lookup(run.Pipeline)
+		if !ok {
+			return nil, fmt.Errorf("pipeline %q not found", run.Pipeline)
+		}
+		return RunBackfill(ctx, p, run)
+	}
+}
@@ -0,0 +1,68 @@
+package module
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	evstore "github.com/GoCodeAlone/workflow/store"
+	"github.com/GoCodeAlone/workflow/tenant"
+)
+
+func TestRunBackfill(t *testing.T) {
+	var tenantSeen string
+	charge := &mockStep{name: "charge", execFn: func(context.Context, *PipelineContext) (*StepResult, error) {
+		t.Error("expected the mocked charge step not to run")
+		return nil, nil
+	}}
+	total := &mockStep{name: "total", execFn: func(ctx context.Context, pc *PipelineContext) (*StepResult, error) {
+		tenantSeen = tenant.TenantFromContext(ctx)
+		return &StepResult{Output: map[string]any{"total": pc.Current["qty"].(int) * 10, "charged": pc.Current["charged"]}}, nil
+	}}
+	recorder := &mockEventRecorder{}
+	p := &Pipeline{
+		Name:          "orders",
+		Steps:         []PipelineStep{charge, total},
+		EventRecorder: recorder,
+		ExecutionID:   "exec-1",
+	}
+
+	outputs, err := RunBackfill(context.Background(), p, evstore.BackfillRun{
+		Pipeline: "orders",
+		TenantID: "acme",
+		Input:    map[string]any{"qty": 3},
+		Mocks:    map[string]*evstore.StepMock{"charge": {Response: map[string]any{"charged": true}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outputs["charge"]["charged"] != true || outputs["total"]["total"] != 30 || outputs["total"]["charged"] != true {
+		t.Errorf("unexpected outputs %v", outputs)
+	}
+	if tenantSeen != "acme" {
+		t.Errorf("expected the run to be scoped to tenant acme, got %q", tenantSeen)
+	}
+	if events := recorder.getEvents(); len(events) != 0 {
+		t.Errorf("expected a backfill run not to be recorded, got %v", recorder.eventTypes())
+	}
+
+	// A mock with an error response fails the step, and mocks of another
+	// pipeline are ignored.
+	outputs, err = RunBackfill(context.Background(), p, evstore.BackfillRun{
+		Pipeline: "orders",
+		Input:    map[string]any{"qty": 1},
+		Mocks:    map[string]*evstore.StepMock{"charge": {ErrorResponse: "card declined"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "card declined") || len(outputs) != 0 {
+		t.Errorf("expected the mocked failure, got %v, %v", outputs, err)
+	}
+	ctx := WithStepMocks(context.Background(), "invoices", map[string]*evstore.StepMock{"charge": {}})
+	if _, mocked, _ := p.mockedStep(ctx, charge); mocked {
+		t.Error("expected mocks of another pipeline not to apply")
+	}
+
+	run := BackfillRunFunc(func(name string) (*Pipeline, bool) { return nil, false })
+	if _, err := run(context.Background(), evstore.BackfillRun{Pipeline: "missing"}); err == nil {
+		t.Error("expected an unknown pipeline to fail the run")
+	}
+}
@@ -10,6 +10,14 @@ type PipelineContext = interfaces.PipelineContext
 // Aliased from interfaces.StepResult for backwards compatibility.
 type StepResult = interfaces.StepResult
 
+// AIUsage is the token usage and cost reported by an AI step.
+// Aliased from interfaces.AIUsage.
+type AIUsage = interfaces.AIUsage
+
+// DBQueryStats describes the SQL statement run by a database step.
+// Aliased from interfaces.DBQueryStats.
+type DBQueryStats = interfaces.DBQueryStats
+
 // NewPipelineContext creates a PipelineContext initialized with trigger data.
 // Delegates to interfaces.NewPipelineContext.
 var NewPipelineContext = interfaces.NewPipelineContext
@@ -0,0 +1,209 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/GoCodeAlone/workflow/store"
+)
+
+// DurableRecovery is what the startup recovery pass does with a durable
+// execution that was still running when the server stopped.
+type DurableRecovery string
+
+const (
+	// DurableRecoveryFail marks the execution interrupted.
+	DurableRecoveryFail DurableRecovery = "fail"
+	// DurableRecoveryResume runs the execution again from the step it was
+	// on, when every step it had started is resumable.
+	DurableRecoveryResume DurableRecovery = "resume"
+)
+
+// DefaultDurableSnapshotBytes is the default limit on the JSON size of a
+// journaled context snapshot.
+const DefaultDurableSnapshotBytes = 64 << 10
+
+// DurableOptions enables durable execution of a pipeline. Before each step
+// the pipeline records a step.journaled event carrying the step and a
+// redacted snapshot of its context, which RecoverDurableExecutions uses to
+// recover executions the server stopped in the middle of.
+type DurableOptions struct {
+	Recovery DurableRecovery
+	// MaxSnapshotBytes bounds the JSON size of a journaled snapshot; zero
+	// means DefaultDurableSnapshotBytes. Larger snapshots are left out of
+	// the journal.
+	MaxSnapshotBytes int
+	// Resumable holds the names of the steps that are safe to run again.
+	Resumable map[string]bool
+}
+
+// durableSnapshot is the pipeline context journaled before a step.
+type durableSnapshot struct {
+	Trigger map[string]any            `json:"trigger"`
+	Current map[string]any            `json:"current"`
+	Steps   map[string]map[string]any `json:"steps"`
+}
+
+// durableResume is the journal entry an execution resumes from.
+type durableResume struct {
+	index    int
+	step     string
+	snapshot durableSnapshot
+}
+
+// journalStep records the step.journaled event of the step about to run at
+// index. Sensitive fields of the snapshot are redacted; a snapshot that
+// cannot be encoded or exceeds the size limit is left out, with the reason.
+func (p *Pipeline) journalStep(ctx context.Context, pc *PipelineContext, step PipelineStep, index int) {
+	data := map[string]any{
+		"step_name": step.Name(),
+		"index":     index,
+		"resumable": p.Durable.Resumable[step.Name()],
+	}
+	limit := p.Durable.MaxSnapshotBytes
+	if limit <= 0 {
+		limit = DefaultDurableSnapshotBytes
+	}
+	steps := make(map[string]map[string]any, len(pc.StepOutputs))
+	for name, out := range pc.StepOutputs {
+		steps[name] = RedactStepOutput(out)
+	}
+	raw, err := json.Marshal(durableSnapshot{
+		Trigger: RedactStepOutput(pc.TriggerData),
+		Current: RedactStepOutput(pc.Current),
+		Steps:   steps,
+	})
+	switch {
+	case err != nil:
+		data["snapshot_omitted"] = fmt.Sprintf("encode snapshot: %v", err)
+	case len(raw) > limit:
+		data["snapshot_omitted"] = fmt.Sprintf("snapshot is %d bytes, over the %d byte limit", len(raw), limit)
+	default:
+		data["snapshot"] = json.RawMessage(raw)
+	}
+	p.recordEvent(ctx, store.EventStepJournaled, data)
+}
+
+// DurableRecoveryResult reports what RecoverDurableExecutions did with one
+// execution.
+type DurableRecoveryResult struct {
+	ExecutionID string
+	Pipeline    string
+	// Action is "interrupted" or "resumed".
+	Action string
+	// Reason explains why an execution was marked interrupted.
+	Reason string
+	// Err is the error a resumed execution ended with.
+	Err error
+}
+
+// RecoverDurableExecutions finds the durable executions in events that are
+// still running, as left behind by a server that stopped in the middle of
+// them, and applies their pipeline's recovery policy. An execution is
+// marked interrupted unless its pipeline resumes and every step it had
+// started is resumable, in which case it runs again from the last journaled
+// step with the journaled context, under the same execution ID. Resumed
+// executions run before RecoverDurableExecutions returns.
+//
+// lookup returns the pipeline of a given name. Recovery assumes no other
+// server is running executions recorded in events.
+func RecoverDurableExecutions(ctx context.Context, events store.EventStore, lookup func(name string) (*Pipeline, bool), logger *slog.Logger) ([]DurableRecoveryResult, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	running, err := events.ListExecutions(ctx, store.ExecutionEventFilter{Status: "running"})
+	if err != nil {
+		return nil, fmt.Errorf("list running executions: %w", err)
+	}
+	var results []DurableRecoveryResult
+	for i := range running {
+		exec := &running[i]
+		if !exec.Durable {
+			continue
+		}
+		res := DurableRecoveryResult{ExecutionID: exec.ExecutionID.String(), Pipeline: exec.Pipeline}
+		p, resume, reason, err := planRecovery(ctx, events, exec, lookup)
+		if err != nil {
+			return results, err
+		}
+		if resume == nil {
+			res.Action = "interrupted"
+			res.Reason = reason
+			if err := events.Append(ctx, exec.ExecutionID, store.EventExecutionInterrupted, map[string]any{
+				"pipeline": exec.Pipeline,
+				"reason":   reason,
+			}); err != nil {
+				return results, fmt.Errorf("mark execution %s interrupted: %w", exec.ExecutionID, err)
+			}
+			logger.Warn("Marked interrupted durable execution", "pipeline", exec.Pipeline, "execution_id", res.ExecutionID, "reason", reason)
+			results = append(results, res)
+			continue
+		}
+
+		run := *p
+		run.ExecutionID = res.ExecutionID
+		run.EventRecorder = store.NewEventRecorderAdapter(events)
+		logger.Info("Resuming durable execution", "pipeline", exec.Pipeline, "execution_id", res.ExecutionID, "step", resume.step)
+		res.Action = "resumed"
+		_, res.Err = run.execute(ctx, resume.snapshot.Trigger, resume)
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// planRecovery decides how to recover a running durable execution: it
+// returns the pipeline and journal entry to resume from, or the reason the
+// execution is interrupted instead.
+func planRecovery(ctx context.Context, events store.EventStore, exec *store.MaterializedExecution, lookup func(string) (*Pipeline, bool)) (*Pipeline, *durableResume, string, error) {
+	evs, err := events.GetEvents(ctx, exec.ExecutionID)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("read events of execution %s: %w", exec.ExecutionID, err)
+	}
+	type journalEntry struct {
+		StepName        string          `json:"step_name"`
+		Index           int             `json:"index"`
+		Snapshot        json.RawMessage `json:"snapshot"`
+		SnapshotOmitted string          `json:"snapshot_omitted"`
+	}
+	var started []string
+	var last journalEntry
+	for _, ev := range evs {
+		if ev.EventType != store.EventStepJournaled {
+			continue
+		}
+		var entry journalEntry
+		if err := json.Unmarshal(ev.EventData, &entry); err != nil {
+			return nil, nil, "", fmt.Errorf("decode journal of execution %s: %w", exec.ExecutionID, err)
+		}
+		started = append(started, entry.StepName)
+		last = entry
+	}
+	if len(started) == 0 {
+		return nil, nil, "server stopped before the first step was journaled", nil
+	}
+	reason := fmt.Sprintf("server stopped during step %q", last.StepName)
+
+	p, ok := lookup(exec.Pipeline)
+	switch {
+	case !ok || p.Durable == nil:
+		return nil, nil, reason + "; pipeline is no longer durable", nil
+	case p.Durable.Recovery != DurableRecoveryResume:
+		return nil, nil, reason, nil
+	case last.Snapshot == nil:
+		return nil, nil, reason + "; cannot resume: " + last.SnapshotOmitted, nil
+	case last.Index >= len(p.Steps) || p.Steps[last.Index].Name() != last.StepName:
+		return nil, nil, reason + "; cannot resume: pipeline steps changed", nil
+	}
+	for _, name := range started {
+		if !p.Durable.Resumable[name] {
+			return nil, nil, fmt.Sprintf("%s; cannot resume: step %q is not resumable", reason, name), nil
+		}
+	}
+	resume := &durableResume{index: last.Index, step: last.StepName}
+	if err := json.Unmarshal(last.Snapshot, &resume.snapshot); err != nil {
+		return nil, nil, reason + "; cannot resume: unreadable snapshot", nil
+	}
+	return p, resume, "", nil
+}
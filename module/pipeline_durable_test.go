@@ -0,0 +1,209 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/GoCodeAlone/workflow/store"
+)
+
+// crashRecorder records events until the process "crashes", after which
+// every event is lost, as if the server stopped.
+type crashRecorder struct {
+	store.EventRecorderAdapter
+	crashed atomic.Bool
+}
+
+func (r *crashRecorder) RecordEvent(ctx context.Context, executionID, eventType string, data map[string]any) error {
+	if r.crashed.Load() {
+		return nil
+	}
+	return r.EventRecorderAdapter.RecordEvent(ctx, executionID, eventType, data)
+}
+
+// runUntilCrash runs a durable pipeline a → b → c whose step b crashes the
+// server, and returns the event store and the execution's ID.
+func runUntilCrash(t *testing.T, durable *DurableOptions) (*store.InMemoryEventStore, string) {
+	t.Helper()
+	events := store.NewInMemoryEventStore()
+	rec := &crashRecorder{EventRecorderAdapter: *store.NewEventRecorderAdapter(events)}
+	crashing := &Pipeline{
+		Name: "orders",
+		Steps: []PipelineStep{
+			newMockStep("a", map[string]any{"order_id": "o-1"}),
+			&mockStep{name: "b", execFn: func(context.Context, *PipelineContext) (*StepResult, error) {
+				rec.crashed.Store(true)
+				return nil, errors.New("server stopped")
+			}},
+			newMockStep("c", nil),
+		},
+		Durable:       durable,
+		EventRecorder: rec,
+	}
+	pc, _ := crashing.Execute(context.Background(), map[string]any{"customer": "acme", "password": "hunter2"})
+	id, _ := pc.Metadata["execution_id"].(string)
+	if id == "" {
+		t.Fatal("durable execution got no execution ID")
+	}
+	return events, id
+}
+
+func TestDurablePipelineJournalsSteps(t *testing.T) {
+	events, id := runUntilCrash(t, &DurableOptions{Resumable: map[string]bool{"a": true}})
+	timeline := mustTimeline(t, events, id)
+	if timeline.Status != "running" || !timeline.Durable {
+		t.Fatalf("timeline = %+v", timeline)
+	}
+
+	evs, _ := events.GetEvents(context.Background(), timeline.ExecutionID)
+	var types []string
+	var journal []map[string]any
+	for _, ev := range evs {
+		types = append(types, ev.EventType)
+		if ev.EventType == store.EventStepJournaled {
+			var data map[string]any
+			_ = json.Unmarshal(ev.EventData, &data)
+			journal = append(journal, data)
+		}
+	}
+	want := "execution.started step.journaled step.started step.completed step.journaled step.started"
+	if got := strings.Join(types, " "); got != want {
+		t.Fatalf("events = %s, want %s", got, want)
+	}
+	if journal[0]["step_name"] != "a" || journal[0]["resumable"] != true || journal[1]["step_name"] != "b" || journal[1]["resumable"] != false {
+		t.Fatalf("journal = %v", journal)
+	}
+	snapshot, _ := json.Marshal(journal[1]["snapshot"])
+	if !strings.Contains(string(snapshot), `"order_id":"o-1"`) || strings.Contains(string(snapshot), "hunter2") || !strings.Contains(string(snapshot), RedactionPlaceholder) {
+		t.Fatalf("snapshot = %s", snapshot)
+	}
+}
+
+func TestDurablePipelineOmitsOversizedSnapshot(t *testing.T) {
+	events, id := runUntilCrash(t, &DurableOptions{MaxSnapshotBytes: 10})
+	evs, _ := events.GetEvents(context.Background(), mustTimeline(t, events, id).ExecutionID)
+	for _, ev := range evs {
+		if ev.EventType != store.EventStepJournaled {
+			continue
+		}
+		var data map[string]any
+		_ = json.Unmarshal(ev.EventData, &data)
+		if data["snapshot"] != nil || !strings.Contains(data["snapshot_omitted"].(string), "over the 10 byte limit") {
+			t.Fatalf("journal entry = %v", data)
+		}
+	}
+}
+
+func TestNonDurablePipelineDoesNotJournal(t *testing.T) {
+	events := store.NewInMemoryEventStore()
+	p := &Pipeline{
+		Name:          "plain",
+		Steps:         []PipelineStep{newMockStep("a", nil)},
+		EventRecorder: store.NewEventRecorderAdapter(events),
+		ExecutionID:   "0b5a39a4-6f0e-4a53-9a43-4d9c6b1f7f10",
+	}
+	if _, err := p.Execute(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	timeline := mustTimeline(t, events, p.ExecutionID)
+	if timeline.Durable || timeline.EventCount != 4 {
+		t.Fatalf("timeline = %+v", timeline)
+	}
+}
+
+func TestRecoverDurableExecutionsResumes(t *testing.T) {
+	resumable := &DurableOptions{Recovery: DurableRecoveryResume, Resumable: map[string]bool{"a": true, "b": true}}
+	events, id := runUntilCrash(t, resumable)
+
+	var aRuns, bRuns atomic.Int32
+	var seen map[string]any
+	restarted := &Pipeline{
+		Name: "orders",
+		Steps: []PipelineStep{
+			&mockStep{name: "a", execFn: func(context.Context, *PipelineContext) (*StepResult, error) {
+				aRuns.Add(1)
+				return &StepResult{}, nil
+			}},
+			&mockStep{name: "b", execFn: func(_ context.Context, pc *PipelineContext) (*StepResult, error) {
+				bRuns.Add(1)
+				seen = pc.Current
+				return &StepResult{Output: map[string]any{"charged": true}}, nil
+			}},
+			newMockStep("c", nil),
+		},
+		Durable: resumable,
+	}
+	results, err := RecoverDurableExecutions(context.Background(), events, func(name string) (*Pipeline, bool) {
+		return restarted, name == "orders"
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Action != "resumed" || results[0].Err != nil || results[0].ExecutionID != id {
+		t.Fatalf("results = %+v", results)
+	}
+	if aRuns.Load() != 0 || bRuns.Load() != 1 || seen["order_id"] != "o-1" || seen["customer"] != "acme" {
+		t.Fatalf("a ran %d times, b ran %d times with %v", aRuns.Load(), bRuns.Load(), seen)
+	}
+	timeline := mustTimeline(t, events, id)
+	if timeline.Status != "completed" || timeline.Resumed != 1 {
+		t.Fatalf("timeline = %+v", timeline)
+	}
+
+	// A finished execution is left alone by the next recovery pass.
+	if results, _ := RecoverDurableExecutions(context.Background(), events, func(string) (*Pipeline, bool) { return restarted, true }, nil); len(results) != 0 {
+		t.Fatalf("second pass results = %+v", results)
+	}
+}
+
+func TestRecoverDurableExecutionsInterrupts(t *testing.T) {
+	tests := []struct {
+		name       string
+		durable    *DurableOptions
+		wantReason string
+	}{
+		{"fail policy", &DurableOptions{Recovery: DurableRecoveryFail, Resumable: map[string]bool{"a": true, "b": true}}, `server stopped during step "b"`},
+		{"step not resumable", &DurableOptions{Recovery: DurableRecoveryResume, Resumable: map[string]bool{"a": true}}, `step "b" is not resumable`},
+		{"snapshot omitted", &DurableOptions{Recovery: DurableRecoveryResume, MaxSnapshotBytes: 10, Resumable: map[string]bool{"a": true, "b": true}}, "cannot resume: snapshot is"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, id := runUntilCrash(t, tt.durable)
+			restarted := &Pipeline{
+				Name:    "orders",
+				Steps:   []PipelineStep{newMockStep("a", nil), newFailingStep("b", errors.New("must not run")), newMockStep("c", nil)},
+				Durable: tt.durable,
+			}
+			results, err := RecoverDurableExecutions(context.Background(), events, func(string) (*Pipeline, bool) { return restarted, true }, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(results) != 1 || results[0].Action != "interrupted" || !strings.Contains(results[0].Reason, tt.wantReason) {
+				t.Fatalf("results = %+v", results)
+			}
+			timeline := mustTimeline(t, events, id)
+			if timeline.Status != "interrupted" || !strings.Contains(timeline.Error, tt.wantReason) || timeline.CompletedAt == nil {
+				t.Fatalf("timeline = %+v", timeline)
+			}
+		})
+	}
+}
+
+func mustTimeline(t *testing.T, events store.EventStore, id string) *store.MaterializedExecution {
+	t.Helper()
+	execs, err := events.ListExecutions(context.Background(), store.ExecutionEventFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range execs {
+		if execs[i].ExecutionID.String() == id {
+			return &execs[i]
+		}
+	}
+	t.Fatalf("execution %s not found", id)
+	return nil
+}
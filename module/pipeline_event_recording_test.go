@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"sync"
 	"testing"
+
+	"github.com/GoCodeAlone/workflow/interfaces"
+	"github.com/GoCodeAlone/workflow/version"
 )
 
 // recordedEvent captures a single event recorded by mockEventRecorder.
@@ -164,6 +167,12 @@ func TestPipeline_EventRecorder_SuccessfulExecution(t *testing.T) {
 	if startEvent.Data["step_count"] != len(p.Steps) {
 		t.Errorf("expected step_count=%d, got %v", len(p.Steps), startEvent.Data["step_count"])
 	}
+	if startEvent.Data["engine_version"] != version.Get().Version {
+		t.Errorf("expected engine_version=%q, got %v", version.Get().Version, startEvent.Data["engine_version"])
+	}
+	if _, ok := startEvent.Data["config_hash"]; ok {
+		t.Errorf("expected no config_hash when pipeline has none, got %v", startEvent.Data["config_hash"])
+	}
 
 	// Verify step.started data for step1
 	step1Started := events[1]
@@ -526,3 +535,39 @@ func TestPipeline_EventRecorder_EmptyPipeline(t *testing.T) {
 		}
 	}
 }
+
+func TestPipeline_EventRecorder_StampsConfigHash(t *testing.T) {
+	recorder := &mockEventRecorder{}
+	p := &Pipeline{
+		Name:          "stamped",
+		Steps:         []PipelineStep{newMockStep("step1", nil)},
+		EventRecorder: recorder,
+		ExecutionID:   "exec-1",
+		ConfigHash:    "sha256:deadbeef",
+	}
+	if _, err := p.Execute(context.Background(), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	started := recorder.getEvents()[0]
+	if started.EventType != "execution.started" || started.Data["config_hash"] != "sha256:deadbeef" {
+		t.Errorf("expected config_hash on execution.started, got %s %v", started.EventType, started.Data)
+	}
+}
+
+func TestPipeline_EventRecorder_StampsTenant(t *testing.T) {
+	recorder := &mockEventRecorder{}
+	p := &Pipeline{
+		Name:          "tenanted",
+		Steps:         []PipelineStep{newMockStep("step1", nil)},
+		EventRecorder: recorder,
+		ExecutionID:   "exec-1",
+	}
+	ctx := WithTenant(context.Background(), interfaces.Tenant{ID: "acme"})
+	if _, err := p.Execute(ctx, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	started := recorder.getEvents()[0]
+	if started.EventType != "execution.started" || started.Data["tenant_id"] != "acme" {
+		t.Errorf("expected tenant_id on execution.started, got %s %v", started.EventType, started.Data)
+	}
+}
@@ -2,11 +2,21 @@ package module
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"net/http"
+	"runtime/debug"
 	"time"
 
+	"github.com/GoCodeAlone/workflow/i18n"
 	"github.com/GoCodeAlone/workflow/interfaces"
+	"github.com/GoCodeAlone/workflow/observability/tracing"
+	"github.com/GoCodeAlone/workflow/pipeline"
+	"github.com/GoCodeAlone/workflow/tenant"
+	"github.com/GoCodeAlone/workflow/version"
+	"github.com/google/uuid"
 )
 
 // ErrorStrategy defines how a pipeline handles step errors.
@@ -42,10 +52,35 @@ type Pipeline struct {
 
 	// StrictTemplates enables strict template key resolution: when true, any
 	// template expression that references a missing map key returns an error
-	// instead of silently resolving to the zero value. Corresponds to the
-	// pipeline config field strict_templates.
+	// instead of silently resolving to the zero value. Set from strict_templates
+	// on the pipeline, its HTTP route, or the engine block.
 	StrictTemplates bool
 
+	// ProblemDetail is the detail level of problem+json error responses
+	// written by the pipeline's steps. Set from engine.errors.detail; empty
+	// means ProblemDetailFull.
+	ProblemDetail ProblemDetailLevel
+
+	// PropagatePanics lets a panicking step crash the caller instead of
+	// failing with a *StepPanicError. Set from engine.errors.recover_panics
+	// for debugging; false (the default) recovers panics.
+	PropagatePanics bool
+
+	// Locales is the message catalog of the config's locales: section, or
+	// nil when none is declared. Execute resolves the request's locale
+	// against it and exposes both to templates and steps.
+	Locales *i18n.Catalog
+
+	// Locale forces the locale of every execution instead of negotiating it
+	// from Accept-Language. Set from the locale of the pipeline's route.
+	Locale string
+
+	// ConfigHash is the hash of the config revision that built this pipeline
+	// (see StdEngine.ConfigHash). It is stamped on execution.started events
+	// alongside the engine version, and exposed to steps as the config_hash
+	// metadata.
+	ConfigHash string
+
 	// EventRecorder is an optional recorder for execution events.
 	// When nil (the default), no events are recorded. Events are best-effort:
 	// recording failures are logged but never fail the pipeline.
@@ -55,11 +90,41 @@ type Pipeline struct {
 	// Set by the caller when event recording is desired.
 	ExecutionID string
 
+	// SecretLookup resolves the secret references of the hmac template
+	// function. Nil leaves hmac without secrets.
+	SecretLookup pipeline.SecretLookup
+
+	// Durable enables durable execution: each step is journaled to the
+	// EventRecorder before it runs. Nil (the default) disables it.
+	Durable *DurableOptions
+
 	// seqNum tracks the auto-incrementing sequence number for events within
 	// this execution. It is reset at the start of each Execute call.
 	seqNum int64
 }
 
+// resolveLocale picks the locale of an execution: the locale of the HTTP
+// trigger route, then the pipeline's own route locale, then the request's
+// Accept-Language header, then the default locale.
+func (p *Pipeline) resolveLocale(ctx context.Context) string {
+	if locale, _ := ctx.Value(routeLocaleContextKey).(string); locale != "" {
+		return p.Locales.Resolve(locale)
+	}
+	if p.Locale != "" {
+		return p.Locales.Resolve(p.Locale)
+	}
+	if req, ok := ctx.Value(HTTPRequestContextKey).(*http.Request); ok {
+		return p.Locales.Resolve(i18n.ParseAcceptLanguage(req.Header.Get("Accept-Language"))...)
+	}
+	return p.Locales.Default()
+}
+
+// stepError wraps the error a failed step ended the pipeline with, naming
+// the step and execution for error responses.
+func (p *Pipeline) stepError(step PipelineStep, err error) error {
+	return &PipelineStepError{Pipeline: p.Name, Step: step.Name(), ExecutionID: p.ExecutionID, Err: err}
+}
+
 // recordEvent is a nil-safe helper that records an event via EventRecorder.
 // If EventRecorder is nil or ExecutionID is empty, this is a no-op. Errors are
 // logged but never returned — event recording is best-effort and must not fail
@@ -70,6 +135,7 @@ func (p *Pipeline) recordEvent(ctx context.Context, eventType string, data map[s
 	}
 
 	p.seqNum++
+	data = withRequestID(ctx, data)
 
 	logger := p.Logger
 	if logger == nil {
@@ -85,8 +151,63 @@ func (p *Pipeline) recordEvent(ctx context.Context, eventType string, data map[s
 	}
 }
 
+// stepEventsContextKey carries the event recorder of the running pipeline to
+// its steps, so a step that runs children of its own (step.parallel) can
+// record them as nested step events.
+type stepEventsContextKey struct{}
+
+type stepEventSink struct {
+	recorder    EventRecorder
+	executionID string
+	logger      *slog.Logger
+}
+
+// recordNestedEvent records an event for a child of the running step, such
+// as a step.parallel branch. It is safe for concurrent use, best-effort like
+// recordEvent, and a no-op when the pipeline records no events.
+func recordNestedEvent(ctx context.Context, eventType string, data map[string]any) {
+	sink, _ := ctx.Value(stepEventsContextKey{}).(*stepEventSink)
+	if sink == nil {
+		return
+	}
+	if err := sink.recorder.RecordEvent(ctx, sink.executionID, eventType, withRequestID(ctx, data)); err != nil {
+		sink.logger.Warn("Failed to record execution event",
+			"event_type", eventType,
+			"execution_id", sink.executionID,
+			"error", err,
+		)
+	}
+}
+
+// withRequestID adds the request ID of ctx, if any, to the data of an
+// execution event, so every event can be correlated with its request.
+func withRequestID(ctx context.Context, data map[string]any) map[string]any {
+	id := GetRequestID(ctx)
+	if id == "" {
+		return data
+	}
+	if data == nil {
+		data = map[string]any{}
+	}
+	data["request_id"] = id
+	return data
+}
+
 // Execute runs the pipeline from trigger data.
 func (p *Pipeline) Execute(ctx context.Context, triggerData map[string]any) (*PipelineContext, error) {
+	// A durable pipeline journals every execution, so one run without an
+	// execution ID gets its own on a copy of the shared pipeline.
+	if p.Durable != nil && p.ExecutionID == "" && p.EventRecorder != nil {
+		run := *p
+		run.ExecutionID = uuid.New().String()
+		return run.execute(ctx, triggerData, nil)
+	}
+	return p.execute(ctx, triggerData, nil)
+}
+
+// execute runs the pipeline, from its first step or, when resume is set,
+// from a journaled step of an interrupted durable execution.
+func (p *Pipeline) execute(ctx context.Context, triggerData map[string]any, resume *durableResume) (*PipelineContext, error) {
 	// Reset sequence counter for this execution.
 	p.seqNum = 0
 
@@ -103,6 +224,18 @@ func (p *Pipeline) Execute(ctx context.Context, triggerData map[string]any) (*Pi
 		"pipeline":   p.Name,
 		"started_at": pipelineStart.UTC().Format(time.RFC3339),
 	}
+	if p.ExecutionID != "" {
+		md["execution_id"] = p.ExecutionID
+	}
+	if p.ConfigHash != "" {
+		md["config_hash"] = p.ConfigHash
+	}
+	if id := GetRequestID(ctx); id != "" {
+		md["request_id"] = id
+	}
+	if p.ProblemDetail != "" {
+		md["_problem_detail"] = string(p.ProblemDetail)
+	}
 	// Merge pre-seeded metadata (e.g., HTTP context for delegate steps)
 	for k, v := range p.Metadata {
 		md[k] = v
@@ -119,6 +252,23 @@ func (p *Pipeline) Execute(ctx context.Context, triggerData map[string]any) (*Pi
 	if req := ctx.Value(HTTPRequestContextKey); req != nil {
 		md["_http_request"] = req
 	}
+	// Expose the template clock and randomness threaded through the Go
+	// context (by the test harness or a replay) and the secret lookup of the
+	// hmac template function.
+	if src, ok := pipeline.TemplateSourcesFromContext(ctx); ok {
+		md["_template_sources"] = src
+	}
+	if p.SecretLookup != nil {
+		md["_secret_lookup"] = p.SecretLookup
+	}
+	// Resolve the execution's locale when the config declares locales. A
+	// locale pre-seeded in Metadata is kept.
+	if p.Locales != nil {
+		md["_i18n_catalog"] = p.Locales
+		if _, seeded := md["locale"]; !seeded {
+			md["locale"] = p.resolveLocale(ctx)
+		}
+	}
 	// Seed _route_pattern from RoutePattern if not already provided via Metadata
 	// (e.g. by CQRS handlers). This ensures step.request_parse can extract path
 	// parameters when a pipeline is executed via an inline HTTP trigger.
@@ -127,22 +277,50 @@ func (p *Pipeline) Execute(ctx context.Context, triggerData map[string]any) (*Pi
 			md["_route_pattern"] = p.RoutePattern
 		}
 	}
+	// Expose a verified mTLS client certificate (set by http.middleware.clientcert)
+	// to steps under _auth.client_cert.
+	if id := ClientCertIdentityFromContext(ctx); id != nil {
+		triggerData = withClientCertAuth(triggerData, id)
+	}
+	// Expose the request ID to steps and templates as _request_id.
+	if id := GetRequestID(ctx); id != "" {
+		data := make(map[string]any, len(triggerData)+1)
+		maps.Copy(data, triggerData)
+		data["_request_id"] = id
+		triggerData = data
+	}
 	pc := NewPipelineContext(triggerData, md)
 	pc.StrictTemplates = p.StrictTemplates
+	if resume != nil {
+		if resume.snapshot.Current != nil {
+			pc.Current = resume.snapshot.Current
+		}
+		for name, out := range resume.snapshot.Steps {
+			pc.StepOutputs[name] = out
+		}
+	}
 
+	// Carry the execution ID as OTEL baggage so outbound calls and
+	// sub-workflows stay correlated with this execution, and tag every log
+	// line with the tenant, workflow and execution the context carries.
+	if p.ExecutionID != "" {
+		ctx = tracing.WithBaggage(ctx, map[string]string{tracing.BaggageExecutionID: p.ExecutionID})
+	}
 	logger := p.Logger
 	if logger == nil {
 		logger = slog.Default()
 	}
+	if args := tracing.BaggageLogArgs(ctx); len(args) > 0 {
+		logger = logger.With(args...)
+	}
 	pc.Logger = logger
-
-	logger.Info("Pipeline started", "pipeline", p.Name, "steps", len(p.Steps))
-
-	// Record execution.started
-	p.recordEvent(ctx, "execution.started", map[string]any{
-		"pipeline":   p.Name,
-		"step_count": len(p.Steps),
-	})
+	if p.EventRecorder != nil && p.ExecutionID != "" {
+		ctx = context.WithValue(ctx, stepEventsContextKey{}, &stepEventSink{
+			recorder:    p.EventRecorder,
+			executionID: p.ExecutionID,
+			logger:      logger,
+		})
+	}
 
 	// Build step index for conditional routing
 	stepIndex := make(map[string]int, len(p.Steps))
@@ -150,8 +328,42 @@ func (p *Pipeline) Execute(ctx context.Context, triggerData map[string]any) (*Pi
 		stepIndex[s.Name()] = i
 	}
 
-	// Execute steps
 	i := 0
+	if resume != nil {
+		logger.Info("Pipeline resumed", "pipeline", p.Name, "step", resume.step)
+		p.recordEvent(ctx, "execution.resumed", map[string]any{
+			"pipeline":       p.Name,
+			"step_name":      resume.step,
+			"index":          resume.index,
+			"engine_version": version.Get().Version,
+		})
+		i = resume.index
+	} else {
+		logger.Info("Pipeline started", "pipeline", p.Name, "steps", len(p.Steps))
+
+		// Record execution.started, stamped with the engine build and config
+		// revision so timelines show exactly what produced the execution.
+		startedData := map[string]any{
+			"pipeline":       p.Name,
+			"step_count":     len(p.Steps),
+			"engine_version": version.Get().Version,
+		}
+		if p.ConfigHash != "" {
+			startedData["config_hash"] = p.ConfigHash
+		}
+		if p.RoutePattern != "" {
+			startedData["route"] = p.RoutePattern
+		}
+		if p.Durable != nil {
+			startedData["durable"] = true
+		}
+		if tenantID := tenant.TenantFromContext(ctx); tenantID != "" {
+			startedData["tenant_id"] = tenantID
+		}
+		p.recordEvent(ctx, "execution.started", startedData)
+	}
+
+	// Execute steps
 	for i < len(p.Steps) {
 		step := p.Steps[i]
 
@@ -165,6 +377,11 @@ func (p *Pipeline) Execute(ctx context.Context, triggerData map[string]any) (*Pi
 		default:
 		}
 
+		// Journal the step and the context it starts from before it runs.
+		if p.Durable != nil {
+			p.journalStep(ctx, pc, step, i)
+		}
+
 		startTime := time.Now()
 		logger.Info("Step started", "pipeline", p.Name, "step", step.Name(), "index", i)
 
@@ -183,18 +400,29 @@ func (p *Pipeline) Execute(ctx context.Context, triggerData map[string]any) (*Pi
 			})
 		}
 
-		result, err := step.Execute(ctx, pc)
+		result, err := p.executeStep(ctx, step, pc)
 		elapsed := time.Since(startTime)
 
 		if err != nil {
 			logger.Error("Step failed", "pipeline", p.Name, "step", step.Name(), "error", err, "elapsed", elapsed)
 
-			// Record step.failed
-			p.recordEvent(ctx, "step.failed", map[string]any{
+			// Record step.failed, with the tokens spent when an AI step
+			// failed after calling its model, or the stack of a panic.
+			failed := map[string]any{
 				"step_name": step.Name(),
 				"error":     err.Error(),
 				"elapsed":   elapsed.String(),
-			})
+			}
+			var usageErr aiUsageReporter
+			if errors.As(err, &usageErr) && usageErr.AIUsage() != nil {
+				failed["ai_usage"] = aiUsageEventData(usageErr.AIUsage())
+			}
+			var panicErr *StepPanicError
+			if errors.As(err, &panicErr) {
+				failed["panic"] = true
+				failed["stack"] = panicErr.Stack
+			}
+			p.recordEvent(ctx, "step.failed", failed)
 
 			switch p.OnError {
 			case ErrorStrategySkip:
@@ -207,6 +435,7 @@ func (p *Pipeline) Execute(ctx context.Context, triggerData map[string]any) (*Pi
 				})
 
 				pc.MergeStepOutput(step.Name(), map[string]any{"_error": err.Error(), "_skipped": true})
+				pc.Metadata["_failed_step"] = step.Name()
 				i++
 				continue
 			case ErrorStrategyCompensate:
@@ -217,11 +446,12 @@ func (p *Pipeline) Execute(ctx context.Context, triggerData map[string]any) (*Pi
 					"strategy": "compensate",
 				})
 
+				pc.Metadata["_failed_step"] = step.Name()
 				compErr := p.runCompensation(ctx, pc, logger)
 				if compErr != nil {
-					return pc, fmt.Errorf("step %q failed: %w (compensation also failed: %v)", step.Name(), err, compErr)
+					return pc, p.stepError(step, fmt.Errorf("step %q failed: %w (compensation also failed: %v)", step.Name(), err, compErr))
 				}
-				return pc, fmt.Errorf("step %q failed: %w (compensation executed)", step.Name(), err)
+				return pc, p.stepError(step, fmt.Errorf("step %q failed: %w (compensation executed)", step.Name(), err))
 			default: // stop
 				// Record execution.failed
 				p.recordEvent(ctx, "execution.failed", map[string]any{
@@ -229,7 +459,7 @@ func (p *Pipeline) Execute(ctx context.Context, triggerData map[string]any) (*Pi
 					"elapsed": time.Since(pipelineStart).String(),
 				})
 
-				return pc, fmt.Errorf("step %q failed: %w", step.Name(), err)
+				return pc, p.stepError(step, fmt.Errorf("step %q failed: %w", step.Name(), err))
 			}
 		}
 
@@ -247,11 +477,24 @@ func (p *Pipeline) Execute(ctx context.Context, triggerData map[string]any) (*Pi
 
 		logger.Info("Step completed", "pipeline", p.Name, "step", step.Name(), "elapsed", elapsed)
 
-		// Record step.completed
-		p.recordEvent(ctx, "step.completed", map[string]any{
+		// Record step.completed. A destructive step previewed in dry-run mode
+		// carries its preview so the run can be reviewed before a real one.
+		completed := map[string]any{
 			"step_name": step.Name(),
 			"elapsed":   elapsed.String(),
-		})
+		}
+		if result != nil && result.DryRun {
+			logger.Info("Step dry run", "pipeline", p.Name, "step", step.Name())
+			completed["dry_run"] = true
+			completed["would_execute"] = result.Output["would_execute"]
+		}
+		if result != nil && result.AIUsage != nil {
+			completed["ai_usage"] = aiUsageEventData(result.AIUsage)
+		}
+		if result != nil && result.DBQuery != nil {
+			completed["db_query"] = dbQueryEventData(result.DBQuery)
+		}
+		p.recordEvent(ctx, "step.completed", completed)
 
 		// Record step output only when explicit tracing is enabled.
 		if isExplicitTrace(ctx) {
@@ -308,6 +551,44 @@ func (p *Pipeline) Execute(ctx context.Context, triggerData map[string]any) (*Pi
 	return pc, nil
 }
 
+// StepPanicError is the error of a step that panicked. The pipeline
+// recovers the panic and handles it like any other step failure.
+type StepPanicError struct {
+	Step  string
+	Value any
+	// Stack is the goroutine stack at the panic.
+	Stack string
+}
+
+func (e *StepPanicError) Error() string {
+	return fmt.Sprintf("step %q panicked: %v", e.Step, e.Value)
+}
+
+// Unwrap returns the panic value when it is an error, such as a
+// runtime.Error.
+func (e *StepPanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// executeStep runs step, converting a panic into a *StepPanicError unless
+// PropagatePanics is set. A step mocked under ctx (see WithStepMocks)
+// returns its mock instead.
+func (p *Pipeline) executeStep(ctx context.Context, step PipelineStep, pc *PipelineContext) (result *StepResult, err error) {
+	if result, mocked, err := p.mockedStep(ctx, step); mocked {
+		return result, err
+	}
+	if p.PropagatePanics {
+		return step.Execute(ctx, pc)
+	}
+	defer func() {
+		if rec := recover(); rec != nil {
+			result, err = nil, &StepPanicError{Step: step.Name(), Value: rec, Stack: string(debug.Stack())}
+		}
+	}()
+	return step.Execute(ctx, pc)
+}
+
 // runCompensation executes compensation steps in reverse order.
 func (p *Pipeline) runCompensation(ctx context.Context, pc *PipelineContext, logger *slog.Logger) error {
 	if len(p.Compensation) == 0 {
@@ -331,7 +612,7 @@ func (p *Pipeline) runCompensation(ctx context.Context, pc *PipelineContext, log
 			"step_type": "compensation",
 		})
 
-		_, err := step.Execute(ctx, pc)
+		_, err := p.executeStep(ctx, step, pc)
 		if err != nil {
 			logger.Error("Compensation step failed", "step", step.Name(), "error", err)
 
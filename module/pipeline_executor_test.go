@@ -1,11 +1,17 @@
 package module
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"log/slog"
+	"runtime"
+	"slices"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/GoCodeAlone/workflow/observability/tracing"
 )
 
 // mockStep is a configurable test double for PipelineStep.
@@ -583,3 +589,101 @@ func TestPipeline_Execute_NoRoutePatternNoMetadata(t *testing.T) {
 		t.Error("expected _route_pattern key to be absent from metadata")
 	}
 }
+
+func TestPipeline_BaggageInLogs(t *testing.T) {
+	var logs bytes.Buffer
+	var stepArgs []any
+	step := &mockStep{name: "s", execFn: func(ctx context.Context, _ *PipelineContext) (*StepResult, error) {
+		stepArgs = tracing.BaggageLogArgs(ctx)
+		return &StepResult{}, nil
+	}}
+	p := &Pipeline{
+		Name:        "orders",
+		Steps:       []PipelineStep{step},
+		ExecutionID: "exec-9",
+		Logger:      slog.New(slog.NewTextHandler(&logs, nil)),
+	}
+	ctx := tracing.WithBaggage(context.Background(), map[string]string{tracing.BaggageTenantID: "acme"})
+	if _, err := p.Execute(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []any{"tenant_id", "acme", "execution_id", "exec-9"}
+	if !slices.Equal(stepArgs, want) {
+		t.Errorf("step context baggage = %v, want %v", stepArgs, want)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		if !strings.Contains(line, "tenant_id=acme") || !strings.Contains(line, "execution_id=exec-9") {
+			t.Errorf("log line without baggage attributes: %s", line)
+		}
+	}
+}
+
+func newPanickingStep(name string) *mockStep {
+	return &mockStep{
+		name: name,
+		execFn: func(context.Context, *PipelineContext) (*StepResult, error) {
+			var m map[string]any
+			m["boom"] = true
+			return nil, nil
+		},
+	}
+}
+
+func TestPipeline_StepPanicRecovered(t *testing.T) {
+	rec := &mockEventRecorder{}
+	p := &Pipeline{
+		Name:          "panicky",
+		Steps:         []PipelineStep{newPanickingStep("bad"), newMockStep("after", nil)},
+		EventRecorder: rec,
+		ExecutionID:   "exec-1",
+	}
+	_, err := p.Execute(context.Background(), nil)
+
+	var panicErr *StepPanicError
+	if !errors.As(err, &panicErr) || panicErr.Step != "bad" || !strings.Contains(err.Error(), `step "bad" panicked: assignment to entry in nil map`) {
+		t.Fatalf("error = %v", err)
+	}
+	var runtimeErr runtime.Error
+	if !errors.As(err, &runtimeErr) {
+		t.Errorf("error does not unwrap to the runtime error: %v", err)
+	}
+	var stepErr *PipelineStepError
+	if !errors.As(err, &stepErr) || stepErr.Step != "bad" {
+		t.Errorf("error is not a step error of the panicking step: %v", err)
+	}
+	for _, ev := range rec.getEvents() {
+		if ev.EventType == "step.failed" {
+			if ev.Data["panic"] != true || !strings.Contains(ev.Data["stack"].(string), "newPanickingStep") {
+				t.Fatalf("step.failed = %v", ev.Data)
+			}
+			return
+		}
+	}
+	t.Fatal("no step.failed event recorded")
+}
+
+func TestPipeline_StepPanicRunsOnError(t *testing.T) {
+	p := &Pipeline{
+		Name:    "panicky",
+		Steps:   []PipelineStep{newPanickingStep("bad"), newMockStep("after", map[string]any{"ran": true})},
+		OnError: ErrorStrategySkip,
+	}
+	pc, err := p.Execute(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("skip strategy: %v", err)
+	}
+	if pc.Current["ran"] != true || pc.StepOutputs["bad"]["_skipped"] != true {
+		t.Fatalf("context = %v", pc.StepOutputs)
+	}
+}
+
+func TestPipeline_PropagatePanics(t *testing.T) {
+	p := &Pipeline{Name: "panicky", Steps: []PipelineStep{newPanickingStep("bad")}, PropagatePanics: true}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("panic was recovered with PropagatePanics set")
+		}
+	}()
+	_, _ = p.Execute(context.Background(), nil)
+}
@@ -93,6 +93,10 @@ func (s *AIClassifyStep) Execute(ctx context.Context, pc *PipelineContext) (*Ste
 		return nil, fmt.Errorf("ai_classify step %q: %w", s.name, err)
 	}
 
+	if err := checkAIBudget(ctx); err != nil {
+		return nil, fmt.Errorf("ai_classify step %q: %w", s.name, err)
+	}
+
 	categoriesStr := strings.Join(s.categories, ", ")
 	systemPrompt := fmt.Sprintf(
 		"You are a text classifier. Classify the given text into exactly one of these categories: %s.\n"+
@@ -118,19 +122,17 @@ func (s *AIClassifyStep) Execute(ctx context.Context, pc *PipelineContext) (*Ste
 	// Parse the classification result
 	result := parseClassification(resp.Content, s.categories)
 
+	usage := newAIUsage(s.registry, provider.Name(), s.model, resp.Model, resp.Usage)
 	output := map[string]any{
 		"category":   result.Category,
 		"confidence": result.Confidence,
 		"reasoning":  result.Reasoning,
 		"raw":        resp.Content,
 		"model":      resp.Model,
-		"usage": map[string]any{
-			"input_tokens":  resp.Usage.InputTokens,
-			"output_tokens": resp.Usage.OutputTokens,
-		},
+		"usage":      aiUsageOutput(usage),
 	}
 
-	return &StepResult{Output: output}, nil
+	return &StepResult{Output: output, AIUsage: usage}, nil
 }
 
 type classificationResult struct {
@@ -83,6 +83,10 @@ func (s *AICompleteStep) Execute(ctx context.Context, pc *PipelineContext) (*Ste
 		return nil, fmt.Errorf("ai_complete step %q: %w", s.name, err)
 	}
 
+	if err := checkAIBudget(ctx); err != nil {
+		return nil, fmt.Errorf("ai_complete step %q: %w", s.name, err)
+	}
+
 	// Resolve system prompt template
 	systemPrompt := s.systemPrompt
 	if systemPrompt != "" {
@@ -107,17 +111,15 @@ func (s *AICompleteStep) Execute(ctx context.Context, pc *PipelineContext) (*Ste
 		return nil, fmt.Errorf("ai_complete step %q: completion failed: %w", s.name, err)
 	}
 
+	usage := newAIUsage(s.registry, provider.Name(), s.model, resp.Model, resp.Usage)
 	output := map[string]any{
 		"content":       resp.Content,
 		"model":         resp.Model,
 		"finish_reason": resp.FinishReason,
-		"usage": map[string]any{
-			"input_tokens":  resp.Usage.InputTokens,
-			"output_tokens": resp.Usage.OutputTokens,
-		},
+		"usage":         aiUsageOutput(usage),
 	}
 
-	return &StepResult{Output: output}, nil
+	return &StepResult{Output: output, AIUsage: usage}, nil
 }
 
 func (s *AICompleteStep) resolveInput(pc *PipelineContext) (string, error) {
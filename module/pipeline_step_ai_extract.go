@@ -20,8 +20,13 @@ type AIExtractStep struct {
 	inputFrom    string
 	maxTokens    int
 	temperature  float64
-	registry     *ai.AIModelRegistry
-	tmpl         *TemplateEngine
+	// maxSchemaRetries is how many times invalid output is sent back to the
+	// model with its validation errors before the step fails.
+	maxSchemaRetries int
+	// coerce fixes trivially coercible type mismatches instead of retrying.
+	coerce   bool
+	registry *ai.AIModelRegistry
+	tmpl     *TemplateEngine
 }
 
 // NewAIExtractStepFactory returns a StepFactory that creates AIExtractStep instances.
@@ -68,6 +73,21 @@ func NewAIExtractStepFactory(registry *ai.AIModelRegistry) StepFactory {
 			step.temperature = float64(v)
 		}
 
+		step.maxSchemaRetries = 2
+		switch v := config["max_schema_retries"].(type) {
+		case nil:
+		case int:
+			step.maxSchemaRetries = v
+		case float64:
+			step.maxSchemaRetries = int(v)
+		default:
+			return nil, fmt.Errorf("ai_extract step %q: 'max_schema_retries' must be a number", name)
+		}
+		if step.maxSchemaRetries < 0 {
+			return nil, fmt.Errorf("ai_extract step %q: 'max_schema_retries' must not be negative", name)
+		}
+		step.coerce, _ = config["coerce"].(bool)
+
 		return step, nil
 	}
 }
@@ -89,21 +109,107 @@ func (s *AIExtractStep) Execute(ctx context.Context, pc *PipelineContext) (*Step
 		return nil, fmt.Errorf("ai_extract step %q: %w", s.name, err)
 	}
 
-	// If the provider supports tool use, use tool calling for structured extraction
-	if provider.SupportsToolUse() {
-		return s.executeWithTools(ctx, provider, inputText)
+	if err := checkAIBudget(ctx); err != nil {
+		return nil, fmt.Errorf("ai_extract step %q: %w", s.name, err)
 	}
 
-	// Fall back to prompt-based extraction
-	return s.executeWithPrompt(ctx, provider, inputText)
-}
-
-func (s *AIExtractStep) executeWithTools(ctx context.Context, provider ai.AIProvider, inputText string) (*StepResult, error) {
 	schemaJSON, err := json.Marshal(s.schema)
 	if err != nil {
 		return nil, fmt.Errorf("ai_extract step %q: marshal schema: %w", s.name, err)
 	}
 
+	// Each attempt is validated against the schema. Invalid output is sent
+	// back to the model with the validation errors, up to maxSchemaRetries
+	// times.
+	messages := []ai.Message{{Role: "user", Content: inputText}}
+	var usage ai.TokenUsage
+	var errs []string
+	for attempt := 1; ; attempt++ {
+		var res *aiExtractAttempt
+		// If the provider supports tool use, use tool calling for structured extraction;
+		// otherwise fall back to prompt-based extraction.
+		if provider.SupportsToolUse() {
+			res, err = s.extractWithTools(ctx, provider, string(schemaJSON), messages)
+		} else {
+			res, err = s.extractWithPrompt(ctx, provider, string(schemaJSON), messages)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ai_extract step %q: %w", s.name, err)
+		}
+		usage.InputTokens += res.usage.InputTokens
+		usage.OutputTokens += res.usage.OutputTokens
+		priced := newAIUsage(s.registry, provider.Name(), s.model, res.model, usage)
+
+		var extracted map[string]any
+		if res.parsed {
+			var coerced any
+			coerced, errs = validateExtraction(res.extracted, s.schema, s.coerce)
+			extracted, _ = coerced.(map[string]any)
+		} else {
+			errs = []string{"response is not a JSON object"}
+		}
+
+		if len(errs) == 0 {
+			output := map[string]any{
+				"extracted": extracted,
+				"method":    res.method,
+				"model":     res.model,
+				"attempts":  attempt,
+				"usage":     aiUsageOutput(priced),
+			}
+			if res.method == "prompt" {
+				output["raw"] = res.raw
+			}
+			return &StepResult{Output: output, AIUsage: priced}, nil
+		}
+		if attempt > s.maxSchemaRetries {
+			return nil, &AIExtractSchemaError{Step: s.name, Attempts: attempt, Errors: errs, Usage: usage, priced: priced}
+		}
+
+		messages = append(messages,
+			ai.Message{Role: "assistant", Content: res.raw},
+			ai.Message{Role: "user", Content: schemaCorrectionPrompt(errs)},
+		)
+	}
+}
+
+// aiExtractAttempt is the result of a single extraction call.
+type aiExtractAttempt struct {
+	extracted map[string]any
+	parsed    bool
+	method    string
+	raw       string
+	model     string
+	usage     ai.TokenUsage
+}
+
+// AIExtractSchemaError is returned by step.ai_extract when the model's output
+// still fails schema validation after all retries.
+type AIExtractSchemaError struct {
+	Step     string
+	Attempts int
+	Errors   []string
+	Usage    ai.TokenUsage
+
+	priced *AIUsage // Usage with its provider, model and cost
+}
+
+func (e *AIExtractSchemaError) Error() string {
+	return fmt.Sprintf("ai_extract step %q: extracted data does not match schema after %d attempt(s): %s",
+		e.Step, e.Attempts, strings.Join(e.Errors, "; "))
+}
+
+// AIUsage returns the tokens spent on the failed attempts, so that they are
+// accounted for although the step failed.
+func (e *AIExtractSchemaError) AIUsage() *AIUsage { return e.priced }
+
+// schemaCorrectionPrompt asks the model to fix its previous answer.
+func schemaCorrectionPrompt(errs []string) string {
+	return "Your previous answer did not match the extraction schema:\n- " + strings.Join(errs, "\n- ") +
+		"\nExtract the data again from the original text and return it with these problems fixed."
+}
+
+func (s *AIExtractStep) extractWithTools(ctx context.Context, provider ai.AIProvider, schemaJSON string, messages []ai.Message) (*aiExtractAttempt, error) {
 	tool := ai.ToolDefinition{
 		Name:        "extract_data",
 		Description: "Extract structured data from the provided text according to the schema.",
@@ -111,7 +217,7 @@ func (s *AIExtractStep) executeWithTools(ctx context.Context, provider ai.AIProv
 	}
 
 	systemPrompt := "You are a data extraction assistant. Extract the requested information from the text and call the extract_data tool with the results. " +
-		"The extraction schema is: " + string(schemaJSON)
+		"The extraction schema is: " + schemaJSON
 
 	req := ai.ToolCompletionRequest{
 		CompletionRequest: ai.CompletionRequest{
@@ -119,54 +225,45 @@ func (s *AIExtractStep) executeWithTools(ctx context.Context, provider ai.AIProv
 			MaxTokens:    s.maxTokens,
 			Temperature:  s.temperature,
 			SystemPrompt: systemPrompt,
-			Messages: []ai.Message{
-				{Role: "user", Content: inputText},
-			},
+			Messages:     messages,
 		},
 		Tools: []ai.ToolDefinition{tool},
 	}
 
 	resp, err := provider.ToolComplete(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("ai_extract step %q: tool completion failed: %w", s.name, err)
+		return nil, fmt.Errorf("tool completion failed: %w", err)
 	}
 
-	output := map[string]any{
-		"model": resp.Model,
-		"usage": map[string]any{
-			"input_tokens":  resp.Usage.InputTokens,
-			"output_tokens": resp.Usage.OutputTokens,
-		},
-	}
+	res := &aiExtractAttempt{model: resp.Model, usage: resp.Usage, raw: resp.Content}
 
 	// Extract data from tool calls
 	switch {
 	case len(resp.ToolCalls) > 0:
-		output["extracted"] = resp.ToolCalls[0].Input
-		output["method"] = "tool_use"
+		res.extracted = resp.ToolCalls[0].Input
+		res.parsed = true
+		res.method = "tool_use"
+		if raw, err := json.Marshal(res.extracted); err == nil {
+			res.raw = string(raw)
+		}
 	case resp.Content != "":
 		// Model responded with text instead of tool call; try parsing as JSON
-		extracted := parseExtraction(resp.Content)
-		output["extracted"] = extracted
-		output["method"] = "text_parse"
+		res.extracted, res.parsed = parseExtraction(resp.Content)
+		res.method = "text_parse"
 	default:
-		output["extracted"] = map[string]any{}
-		output["method"] = "empty"
+		res.extracted = map[string]any{}
+		res.parsed = true
+		res.method = "empty"
+		res.raw = "{}"
 	}
-
-	return &StepResult{Output: output}, nil
+	return res, nil
 }
 
-func (s *AIExtractStep) executeWithPrompt(ctx context.Context, provider ai.AIProvider, inputText string) (*StepResult, error) {
-	schemaJSON, err := json.Marshal(s.schema)
-	if err != nil {
-		return nil, fmt.Errorf("ai_extract step %q: marshal schema: %w", s.name, err)
-	}
-
+func (s *AIExtractStep) extractWithPrompt(ctx context.Context, provider ai.AIProvider, schemaJSON string, messages []ai.Message) (*aiExtractAttempt, error) {
 	systemPrompt := fmt.Sprintf(
 		"You are a data extraction assistant. Extract the requested information from the text.\n"+
 			"Respond with ONLY a JSON object matching this schema:\n%s",
-		string(schemaJSON),
+		schemaJSON,
 	)
 
 	req := ai.CompletionRequest{
@@ -174,46 +271,40 @@ func (s *AIExtractStep) executeWithPrompt(ctx context.Context, provider ai.AIPro
 		MaxTokens:    s.maxTokens,
 		Temperature:  s.temperature,
 		SystemPrompt: systemPrompt,
-		Messages: []ai.Message{
-			{Role: "user", Content: inputText},
-		},
+		Messages:     messages,
 	}
 
 	resp, err := provider.Complete(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("ai_extract step %q: completion failed: %w", s.name, err)
-	}
-
-	extracted := parseExtraction(resp.Content)
-
-	output := map[string]any{
-		"extracted": extracted,
-		"method":    "prompt",
-		"raw":       resp.Content,
-		"model":     resp.Model,
-		"usage": map[string]any{
-			"input_tokens":  resp.Usage.InputTokens,
-			"output_tokens": resp.Usage.OutputTokens,
-		},
+		return nil, fmt.Errorf("completion failed: %w", err)
 	}
 
-	return &StepResult{Output: output}, nil
+	extracted, parsed := parseExtraction(resp.Content)
+	return &aiExtractAttempt{
+		extracted: extracted,
+		parsed:    parsed,
+		method:    "prompt",
+		raw:       resp.Content,
+		model:     resp.Model,
+		usage:     resp.Usage,
+	}, nil
 }
 
-// parseExtraction tries to parse JSON from the model's text response.
-func parseExtraction(content string) map[string]any {
+// parseExtraction parses a JSON object from the model's text response,
+// accepting bare JSON, JSON embedded in prose, or a ```json code block.
+func parseExtraction(content string) (map[string]any, bool) {
 	var result map[string]any
 
 	// Try direct parse
 	if err := json.Unmarshal([]byte(content), &result); err == nil {
-		return result
+		return result, true
 	}
 
 	// Try extracting JSON from the content
 	if idx := strings.Index(content, "{"); idx != -1 {
 		if end := strings.LastIndex(content, "}"); end > idx {
 			if err := json.Unmarshal([]byte(content[idx:end+1]), &result); err == nil {
-				return result
+				return result, true
 			}
 		}
 	}
@@ -223,12 +314,12 @@ func parseExtraction(content string) map[string]any {
 		start := idx + len("```json")
 		if end := strings.Index(content[start:], "```"); end != -1 {
 			if err := json.Unmarshal([]byte(strings.TrimSpace(content[start:start+end])), &result); err == nil {
-				return result
+				return result, true
 			}
 		}
 	}
 
-	return map[string]any{"raw": content}
+	return nil, false
 }
 
 func (s *AIExtractStep) resolveInput(pc *PipelineContext) (string, error) {
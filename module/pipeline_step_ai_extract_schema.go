@@ -0,0 +1,230 @@
+package module
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// validateExtraction checks value against a JSON Schema subset: type (a name
+// or list of names), required, properties, items, and enum. It returns the
+// value, with trivially coercible mismatches fixed when coerce is set, and a
+// list of human-readable errors suitable for feeding back to the model.
+func validateExtraction(value any, schema map[string]any, coerce bool) (any, []string) {
+	var errs []string
+	v := validateSchemaValue("$", value, schema, coerce, &errs)
+	return v, errs
+}
+
+func validateSchemaValue(path string, value any, schema map[string]any, coerce bool, errs *[]string) any {
+	if types := schemaTypes(schema["type"]); len(types) > 0 {
+		matched := false
+		for _, t := range types {
+			if matchesSchemaType(value, t) {
+				matched = true
+				break
+			}
+		}
+		if !matched && coerce {
+			for _, t := range types {
+				if c, ok := coerceSchemaType(value, t); ok {
+					value, matched = c, true
+					break
+				}
+			}
+		}
+		if !matched {
+			*errs = append(*errs, fmt.Sprintf("%s: expected %s, got %s", path, strings.Join(types, " or "), jsonTypeName(value)))
+			return value
+		}
+	}
+
+	if enum, ok := schema["enum"].([]any); ok && len(enum) > 0 {
+		value = validateEnum(path, value, enum, coerce, errs)
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		if required, ok := schema["required"].([]any); ok {
+			for _, r := range required {
+				if field, ok := r.(string); ok {
+					if fv, exists := v[field]; !exists || fv == nil {
+						*errs = append(*errs, fmt.Sprintf("%s: missing required field %q", path, field))
+					}
+				}
+			}
+		}
+		if props, ok := schema["properties"].(map[string]any); ok {
+			fields := make([]string, 0, len(props))
+			for field := range props {
+				fields = append(fields, field)
+			}
+			sort.Strings(fields)
+			for _, field := range fields {
+				spec, ok := props[field].(map[string]any)
+				fv, exists := v[field]
+				if !ok || !exists || fv == nil {
+					continue
+				}
+				v[field] = validateSchemaValue(path+"."+field, fv, spec, coerce, errs)
+			}
+		}
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				v[i] = validateSchemaValue(fmt.Sprintf("%s[%d]", path, i), item, items, coerce, errs)
+			}
+		}
+	}
+	return value
+}
+
+func validateEnum(path string, value any, enum []any, coerce bool, errs *[]string) any {
+	for _, e := range enum {
+		if reflect.DeepEqual(value, e) || (isJSONNumber(value) && isJSONNumber(e) && toFloat(value) == toFloat(e)) {
+			return value
+		}
+	}
+	if s, ok := value.(string); ok && coerce {
+		for _, e := range enum {
+			if es, ok := e.(string); ok && strings.EqualFold(strings.TrimSpace(s), es) {
+				return es
+			}
+		}
+	}
+	allowed := make([]string, len(enum))
+	for i, e := range enum {
+		allowed[i] = fmt.Sprintf("%v", e)
+	}
+	*errs = append(*errs, fmt.Sprintf("%s: %v is not one of [%s]", path, value, strings.Join(allowed, ", ")))
+	return value
+}
+
+// schemaTypes normalises the "type" keyword, which may be a string or a list.
+func schemaTypes(raw any) []string {
+	switch t := raw.(type) {
+	case string:
+		return []string{t}
+	case []any:
+		var types []string
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	case []string:
+		return t
+	}
+	return nil
+}
+
+func matchesSchemaType(value any, t string) bool {
+	switch t {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		return isJSONNumber(value)
+	case "integer":
+		return isJSONNumber(value) && toFloat(value) == math.Trunc(toFloat(value))
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "null":
+		return value == nil
+	}
+	// Unknown type names are not enforced.
+	return true
+}
+
+// coerceSchemaType converts values whose intended type is unambiguous:
+// numeric strings to numbers, "yes"/"no"/"true"/"false" to booleans, and
+// scalars to strings.
+func coerceSchemaType(value any, t string) (any, bool) {
+	switch t {
+	case "number", "integer":
+		s, ok := value.(string)
+		if !ok {
+			return nil, false
+		}
+		f, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(s), ",", ""), 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) || (t == "integer" && f != math.Trunc(f)) {
+			return nil, false
+		}
+		return f, true
+	case "boolean":
+		s, ok := value.(string)
+		if !ok {
+			return nil, false
+		}
+		switch strings.ToLower(strings.TrimSpace(s)) {
+		case "true", "yes", "y":
+			return true, true
+		case "false", "no", "n":
+			return false, true
+		}
+	case "string":
+		switch v := value.(type) {
+		case bool:
+			return strconv.FormatBool(v), true
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), true
+		case int:
+			return strconv.Itoa(v), true
+		}
+	}
+	return nil, false
+}
+
+func isJSONNumber(v any) bool {
+	switch v.(type) {
+	case float64, float32, int, int64, int32:
+		return true
+	}
+	return false
+}
+
+func toFloat(v any) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case float32:
+		return float64(n)
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case int32:
+		return float64(n)
+	}
+	return 0
+}
+
+func jsonTypeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	}
+	if isJSONNumber(v) {
+		return "number"
+	}
+	return fmt.Sprintf("%T", v)
+}
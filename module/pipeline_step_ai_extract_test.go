@@ -1,6 +1,10 @@
 package module
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"strings"
 	"testing"
 
@@ -70,3 +74,211 @@ func TestAIExtractStep_DefaultMaxTokens(t *testing.T) {
 		t.Errorf("expected default maxTokens 1024, got %d", s.maxTokens)
 	}
 }
+
+// scriptedExtractProvider returns one scripted response per call and records
+// the requests it receives.
+type scriptedExtractProvider struct {
+	tools     bool
+	responses []string
+	calls     []ai.CompletionRequest
+}
+
+func (p *scriptedExtractProvider) Name() string           { return "scripted" }
+func (p *scriptedExtractProvider) Models() []ai.ModelInfo { return nil }
+func (p *scriptedExtractProvider) SupportsToolUse() bool  { return p.tools }
+func (p *scriptedExtractProvider) next() (string, error) {
+	if len(p.calls) > len(p.responses) {
+		return "", fmt.Errorf("unexpected call %d", len(p.calls))
+	}
+	return p.responses[len(p.calls)-1], nil
+}
+
+func (p *scriptedExtractProvider) Complete(_ context.Context, req ai.CompletionRequest) (*ai.CompletionResponse, error) {
+	p.calls = append(p.calls, req)
+	content, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	return &ai.CompletionResponse{Model: "scripted-1", Content: content, Usage: ai.TokenUsage{InputTokens: 100, OutputTokens: 20}}, nil
+}
+
+func (p *scriptedExtractProvider) CompleteStream(context.Context, ai.CompletionRequest) (<-chan ai.StreamChunk, error) {
+	return nil, fmt.Errorf("not supported")
+}
+
+func (p *scriptedExtractProvider) ToolComplete(_ context.Context, req ai.ToolCompletionRequest) (*ai.ToolCompletionResponse, error) {
+	p.calls = append(p.calls, req.CompletionRequest)
+	content, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	var input map[string]any
+	if err := json.Unmarshal([]byte(content), &input); err != nil {
+		return nil, err
+	}
+	return &ai.ToolCompletionResponse{
+		CompletionResponse: ai.CompletionResponse{Model: "scripted-1", Usage: ai.TokenUsage{InputTokens: 100, OutputTokens: 20}},
+		ToolCalls:          []ai.ToolCall{{ID: "call-1", Name: "extract_data", Input: input}},
+	}, nil
+}
+
+var orderSchema = map[string]any{
+	"type":     "object",
+	"required": []any{"customer", "total", "currency"},
+	"properties": map[string]any{
+		"customer": map[string]any{"type": "string"},
+		"total":    map[string]any{"type": "number"},
+		"currency": map[string]any{"type": "string", "enum": []any{"USD", "EUR"}},
+		"paid":     map[string]any{"type": "boolean"},
+		"items": map[string]any{
+			"type":  "array",
+			"items": map[string]any{"type": "object", "required": []any{"qty"}, "properties": map[string]any{"qty": map[string]any{"type": "integer"}}},
+		},
+	},
+}
+
+func newScriptedExtractStep(t *testing.T, provider *scriptedExtractProvider, extra map[string]any) PipelineStep {
+	t.Helper()
+	registry := ai.NewAIModelRegistry()
+	if err := registry.RegisterProvider(provider); err != nil {
+		t.Fatal(err)
+	}
+	config := map[string]any{"schema": orderSchema}
+	for k, v := range extra {
+		config[k] = v
+	}
+	step, err := NewAIExtractStepFactory(registry)("extract", config, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return step
+}
+
+func TestAIExtractStep_RetriesInvalidOutput(t *testing.T) {
+	for _, tools := range []bool{true, false} {
+		provider := &scriptedExtractProvider{tools: tools, responses: []string{
+			`{"customer": "Acme", "total": "12.50"}`,
+			`{"customer": "Acme", "total": 12.5, "currency": "USD", "items": [{"qty": 2}]}`,
+		}}
+		step := newScriptedExtractStep(t, provider, nil)
+
+		result, err := step.Execute(context.Background(), NewPipelineContext(map[string]any{"text": "Acme owes $12.50"}, nil))
+		if err != nil {
+			t.Fatalf("tools=%v: unexpected error: %v", tools, err)
+		}
+		if len(provider.calls) != 2 || result.Output["attempts"] != 2 {
+			t.Fatalf("tools=%v: expected 2 attempts, got %d calls, output %v", tools, len(provider.calls), result.Output["attempts"])
+		}
+		extracted := result.Output["extracted"].(map[string]any)
+		if extracted["total"] != 12.5 || extracted["currency"] != "USD" {
+			t.Errorf("tools=%v: unexpected extraction %v", tools, extracted)
+		}
+		usage := result.Output["usage"].(map[string]any)
+		if usage["input_tokens"] != 200 || usage["output_tokens"] != 40 {
+			t.Errorf("tools=%v: expected summed usage, got %v", tools, usage)
+		}
+
+		// The retry carries the previous answer and the validation errors.
+		retry := provider.calls[1].Messages
+		if len(retry) != 3 || retry[1].Role != "assistant" || retry[2].Role != "user" {
+			t.Fatalf("tools=%v: unexpected retry messages %+v", tools, retry)
+		}
+		for _, want := range []string{`$.total: expected number, got string`, `$: missing required field "currency"`} {
+			if !strings.Contains(retry[2].Content, want) {
+				t.Errorf("tools=%v: corrective prompt missing %q:\n%s", tools, want, retry[2].Content)
+			}
+		}
+	}
+}
+
+func TestAIExtractStep_FailsAfterMaxSchemaRetries(t *testing.T) {
+	provider := &scriptedExtractProvider{tools: true, responses: []string{
+		`{"customer": "Acme"}`,
+		`{"customer": "Acme", "total": 1, "currency": "GBP"}`,
+	}}
+	step := newScriptedExtractStep(t, provider, map[string]any{"max_schema_retries": 1})
+
+	_, err := step.Execute(context.Background(), NewPipelineContext(map[string]any{"text": "x"}, nil))
+	var schemaErr *AIExtractSchemaError
+	if !errors.As(err, &schemaErr) {
+		t.Fatalf("expected AIExtractSchemaError, got %v", err)
+	}
+	if schemaErr.Attempts != 2 || len(provider.calls) != 2 {
+		t.Errorf("expected 2 attempts, got %d (%d calls)", schemaErr.Attempts, len(provider.calls))
+	}
+	if len(schemaErr.Errors) != 1 || !strings.Contains(schemaErr.Errors[0], `$.currency: GBP is not one of [USD, EUR]`) {
+		t.Errorf("expected the last attempt's errors, got %v", schemaErr.Errors)
+	}
+	if schemaErr.Usage.InputTokens != 200 {
+		t.Errorf("expected summed usage, got %+v", schemaErr.Usage)
+	}
+}
+
+func TestAIExtractStep_CoerceAvoidsRetry(t *testing.T) {
+	provider := &scriptedExtractProvider{responses: []string{
+		"```json\n" + `{"customer": "Acme", "total": "1,250.00", "currency": "usd", "paid": "yes", "items": [{"qty": "3"}]}` + "\n```",
+	}}
+	step := newScriptedExtractStep(t, provider, map[string]any{"coerce": true})
+
+	result, err := step.Execute(context.Background(), NewPipelineContext(map[string]any{"text": "x"}, nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(provider.calls) != 1 {
+		t.Errorf("expected a single call, got %d", len(provider.calls))
+	}
+	extracted := result.Output["extracted"].(map[string]any)
+	if extracted["total"] != 1250.0 || extracted["currency"] != "USD" || extracted["paid"] != true {
+		t.Errorf("unexpected coerced values: %v", extracted)
+	}
+	if qty := extracted["items"].([]any)[0].(map[string]any)["qty"]; qty != 3.0 {
+		t.Errorf("expected nested qty coerced to 3, got %v (%T)", qty, qty)
+	}
+}
+
+func TestAIExtractStep_NonJSONResponseIsRetried(t *testing.T) {
+	provider := &scriptedExtractProvider{responses: []string{
+		"I could not find an order.",
+		`{"customer": "Acme", "total": 3, "currency": "EUR"}`,
+	}}
+	step := newScriptedExtractStep(t, provider, nil)
+
+	result, err := step.Execute(context.Background(), NewPipelineContext(map[string]any{"text": "x"}, nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Output["attempts"] != 2 || !strings.Contains(provider.calls[1].Messages[2].Content, "not a JSON object") {
+		t.Errorf("expected a retry for non-JSON output, got %v", result.Output)
+	}
+}
+
+func TestAIExtractStep_InvalidMaxSchemaRetries(t *testing.T) {
+	registry := ai.NewAIModelRegistry()
+	for _, v := range []any{-1, "two"} {
+		if _, err := NewAIExtractStepFactory(registry)("extract", map[string]any{
+			"schema":             map[string]any{"type": "object"},
+			"max_schema_retries": v,
+		}, nil); err == nil {
+			t.Errorf("expected error for max_schema_retries=%v", v)
+		}
+	}
+}
+
+func TestValidateExtraction_IntegerAndTypeLists(t *testing.T) {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"count": map[string]any{"type": "integer"},
+			"note":  map[string]any{"type": []any{"string", "null"}},
+		},
+	}
+	_, errs := validateExtraction(map[string]any{"count": 2.5, "note": 7.0}, schema, false)
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %v", errs)
+	}
+	// Coercion never loses information: 2.5 is not an integer.
+	v, errs := validateExtraction(map[string]any{"count": "2.5", "note": 7.0}, schema, true)
+	if len(errs) != 1 || v.(map[string]any)["note"] != "7" {
+		t.Errorf("unexpected coercion result %v, errors %v", v, errs)
+	}
+}
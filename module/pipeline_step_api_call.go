@@ -0,0 +1,445 @@
+package module
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow/observability/tracing"
+)
+
+// APICallStep calls an operation of an external API described by an
+// openapi.consumer module. Path, query and header parameters and the request
+// body are validated against the operation's schemas before the request is
+// sent, and the response is validated against the schema declared for its
+// status code. The consumer supplies the base URL and the credentials.
+//
+//	type: step.api_call
+//	name: create-payment
+//	config:
+//	  consumer: payments-api
+//	  operation: createPayment
+//	  params:
+//	    accountId: "{{ .account_id }}"
+//	  body:
+//	    amount: "{{ .amount }}"
+//	    currency: EUR
+type APICallStep struct {
+	name          string
+	consumer      *OpenAPIConsumer
+	operation     *consumerOperation
+	params        map[string]any
+	body          map[string]any
+	bodyFrom      string
+	timeout       time.Duration
+	errorOnStatus bool
+	tmpl          *TemplateEngine
+}
+
+// APISpecViolationError is returned by step.api_call when the request it
+// would send, or the response it received, does not conform to the
+// operation's schema. Phase is "request" or "response"; StatusCode is set for
+// response violations.
+type APISpecViolationError struct {
+	Step        string
+	OperationID string
+	Phase       string
+	StatusCode  int
+	Violations  []string
+}
+
+func (e *APISpecViolationError) Error() string {
+	if e.Phase == "response" {
+		return fmt.Sprintf("api_call step %q: %s response (HTTP %d) violates the spec: %s",
+			e.Step, e.OperationID, e.StatusCode, strings.Join(e.Violations, "; "))
+	}
+	return fmt.Sprintf("api_call step %q: %s request violates the spec: %s",
+		e.Step, e.OperationID, strings.Join(e.Violations, "; "))
+}
+
+// APITransportError is returned by step.api_call when the external API could
+// not be reached or its response could not be read.
+type APITransportError struct {
+	Step        string
+	OperationID string
+	Err         error
+}
+
+func (e *APITransportError) Error() string {
+	return fmt.Sprintf("api_call step %q: %s request failed: %v", e.Step, e.OperationID, e.Err)
+}
+
+func (e *APITransportError) Unwrap() error { return e.Err }
+
+// NewAPICallStepFactory returns a StepFactory that creates APICallStep
+// instances. The consumer module must be initialised before pipelines are
+// built, so unknown consumers, operations and parameters fail the build.
+func NewAPICallStepFactory() StepFactory {
+	return func(name string, config map[string]any, app modular.Application) (PipelineStep, error) {
+		consumerName, _ := config["consumer"].(string)
+		if consumerName == "" {
+			return nil, fmt.Errorf("api_call step %q: 'consumer' is required", name)
+		}
+		operationID, _ := config["operation"].(string)
+		if operationID == "" {
+			return nil, fmt.Errorf("api_call step %q: 'operation' is required", name)
+		}
+		if app == nil {
+			return nil, fmt.Errorf("api_call step %q: no application context", name)
+		}
+		svc, ok := app.SvcRegistry()[consumerName]
+		if !ok {
+			return nil, fmt.Errorf("api_call step %q: openapi.consumer %q not found in service registry", name, consumerName)
+		}
+		consumer, ok := svc.(*OpenAPIConsumer)
+		if !ok {
+			return nil, fmt.Errorf("api_call step %q: service %q is not an openapi.consumer", name, consumerName)
+		}
+		op, err := consumer.operation(operationID)
+		if err != nil {
+			return nil, fmt.Errorf("api_call step %q: consumer %q: %w", name, consumerName, err)
+		}
+
+		step := &APICallStep{
+			name:          name,
+			consumer:      consumer,
+			operation:     op,
+			errorOnStatus: true,
+			tmpl:          NewTemplateEngine(),
+		}
+
+		if raw, ok := config["params"]; ok {
+			params, isMap := raw.(map[string]any)
+			if !isMap {
+				return nil, fmt.Errorf("api_call step %q: 'params' must be a map", name)
+			}
+			for p := range params {
+				if decl := op.parameter(p); decl == nil || decl.In == "cookie" {
+					return nil, fmt.Errorf("api_call step %q: operation %q has no path, query or header parameter %q", name, operationID, p)
+				}
+			}
+			step.params = params
+		}
+		for _, decl := range op.op.Parameters {
+			if decl.In == "path" {
+				if _, ok := step.params[decl.Name]; !ok {
+					return nil, fmt.Errorf("api_call step %q: path parameter %q of operation %q is not set in 'params'", name, decl.Name, operationID)
+				}
+			}
+		}
+
+		if raw, ok := config["body"]; ok {
+			body, isMap := raw.(map[string]any)
+			if !isMap {
+				return nil, fmt.Errorf("api_call step %q: 'body' must be a map", name)
+			}
+			step.body = body
+		}
+		step.bodyFrom, _ = config["body_from"].(string)
+		if step.body != nil && step.bodyFrom != "" {
+			return nil, fmt.Errorf("api_call step %q: 'body' and 'body_from' are mutually exclusive", name)
+		}
+		if (step.body != nil || step.bodyFrom != "") && op.op.RequestBody == nil {
+			return nil, fmt.Errorf("api_call step %q: operation %q does not accept a request body", name, operationID)
+		}
+
+		if v, ok := config["timeout"].(string); ok && v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return nil, fmt.Errorf("api_call step %q: invalid timeout %q: %w", name, v, err)
+			}
+			step.timeout = d
+		}
+		if v, ok := config["error_on_status"].(bool); ok {
+			step.errorOnStatus = v
+		}
+
+		return step, nil
+	}
+}
+
+// Name returns the step name.
+func (s *APICallStep) Name() string { return s.name }
+
+// Execute resolves the parameters and body, validates them, calls the
+// operation and validates the response.
+func (s *APICallStep) Execute(ctx context.Context, pc *PipelineContext) (*StepResult, error) {
+	params, body, err := s.prepare(pc)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+	req, err := s.buildRequest(ctx, params, body)
+	if err != nil {
+		return nil, fmt.Errorf("api_call step %q: %w", s.name, err)
+	}
+	if auth := s.consumer.config.Auth; auth != nil {
+		auth.apply(req)
+	}
+
+	resp, err := s.consumer.client.Do(req) //nolint:gosec // G704: URL from the consumer's OpenAPI spec
+	if err != nil {
+		return nil, &APITransportError{Step: s.name, OperationID: s.operation.id, Err: err}
+	}
+	defer func() { _ = resp.Body.Close() }()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &APITransportError{Step: s.name, OperationID: s.operation.id, Err: fmt.Errorf("failed to read response: %w", err)}
+	}
+
+	if violations := s.validateResponse(resp, respBody); len(violations) > 0 {
+		return nil, &APISpecViolationError{Step: s.name, OperationID: s.operation.id, Phase: "response", StatusCode: resp.StatusCode, Violations: violations}
+	}
+	if s.errorOnStatus && resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("api_call step %q: HTTP %d: %s", s.name, resp.StatusCode, string(respBody))
+	}
+
+	output := parseHTTPResponse(resp, respBody)
+	output["operation"] = s.operation.id
+	return &StepResult{Output: output}, nil
+}
+
+// DryRunPreview returns the method, URL, headers and body of the request the
+// step would send after validating them against the spec. Credentials are
+// not added; the preview names the consumer's auth type instead.
+func (s *APICallStep) DryRunPreview(ctx context.Context, pc *PipelineContext) (map[string]any, error) {
+	params, body, err := s.prepare(pc)
+	if err != nil {
+		return nil, err
+	}
+	req, err := s.buildRequest(ctx, params, body)
+	if err != nil {
+		return nil, fmt.Errorf("api_call step %q: %w", s.name, err)
+	}
+	headers := make(map[string]any, len(req.Header))
+	for k := range req.Header {
+		headers[k] = req.Header.Get(k)
+	}
+	preview := map[string]any{
+		"operation": s.operation.id,
+		"method":    req.Method,
+		"url":       req.URL.String(),
+		"headers":   headers,
+	}
+	if body != nil {
+		preview["body"] = body
+	}
+	if auth := s.consumer.config.Auth; auth != nil {
+		preview["auth"] = auth.Type
+	}
+	return preview, nil
+}
+
+// prepare resolves the parameters and body and validates them against the
+// operation.
+func (s *APICallStep) prepare(pc *PipelineContext) (map[string]any, any, error) {
+	params, err := s.tmpl.ResolveMap(s.params, pc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("api_call step %q: failed to resolve params: %w", s.name, err)
+	}
+	body, err := s.resolveBody(pc)
+	if err != nil {
+		return nil, nil, err
+	}
+	if violations := s.validateRequest(params, body); len(violations) > 0 {
+		return nil, nil, &APISpecViolationError{Step: s.name, OperationID: s.operation.id, Phase: "request", Violations: violations}
+	}
+	return params, body, nil
+}
+
+// resolveBody returns the request body as decoded JSON, so that it is
+// validated exactly as it will be sent. Template results are strings; those
+// whose schema expects a number or boolean are converted first.
+func (s *APICallStep) resolveBody(pc *PipelineContext) (any, error) {
+	var body any
+	switch {
+	case s.bodyFrom != "":
+		body = resolveBodyFrom(s.bodyFrom, pc)
+	case s.body != nil:
+		resolved, err := s.tmpl.ResolveMap(s.body, pc)
+		if err != nil {
+			return nil, fmt.Errorf("api_call step %q: failed to resolve body: %w", s.name, err)
+		}
+		body = coerceToSchema(resolved, s.operation.requestSchema())
+	default:
+		return nil, nil
+	}
+	if body == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("api_call step %q: failed to marshal body: %w", s.name, err)
+	}
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, fmt.Errorf("api_call step %q: failed to decode body: %w", s.name, err)
+	}
+	return decoded, nil
+}
+
+// validateRequest checks the parameters and body against the operation.
+func (s *APICallStep) validateRequest(params map[string]any, body any) []string {
+	var violations []string
+	for _, decl := range s.operation.op.Parameters {
+		val, ok := params[decl.Name]
+		if !ok || val == nil {
+			if decl.Required {
+				violations = append(violations, fmt.Sprintf("%s parameter %q is required", decl.In, decl.Name))
+			}
+			continue
+		}
+		if decl.Schema != nil {
+			violations = append(violations, validateScalarValue(fmt.Sprint(val), decl.Name, "parameter", decl.Schema)...)
+		}
+	}
+
+	rb := s.operation.op.RequestBody
+	if rb == nil {
+		return violations
+	}
+	if body == nil {
+		if rb.Required {
+			violations = append(violations, "request body is required")
+		}
+		return violations
+	}
+	return append(violations, validateAgainstSchema(body, s.operation.requestSchema(), "request body")...)
+}
+
+// validateResponse checks the status code and JSON body of resp against the
+// responses the operation declares.
+func (s *APICallStep) validateResponse(resp *http.Response, body []byte) []string {
+	declared, ok := s.operation.response(resp.StatusCode)
+	if !ok {
+		return []string{fmt.Sprintf("status %d is not declared by the operation", resp.StatusCode)}
+	}
+	schema := jsonMediaSchema(declared.Content)
+	if schema == nil {
+		return nil
+	}
+	var decoded any
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return []string{fmt.Sprintf("response body is not valid JSON: %v", err)}
+	}
+	return validateAgainstSchema(decoded, schema, "response body")
+}
+
+// validateAgainstSchema validates a decoded JSON document against schema.
+func validateAgainstSchema(doc any, schema *openAPISchema, label string) []string {
+	if schema == nil {
+		return nil
+	}
+	if _, isObject := doc.(map[string]any); isObject || schema.Type == "object" {
+		return validateJSONBody(doc, schema, label)
+	}
+	return validateJSONValue(doc, label, schema)
+}
+
+// buildRequest builds the HTTP request from the operation's method and path,
+// the consumer's base URL, and the resolved parameters and body. The caller
+// adds the consumer's credentials.
+func (s *APICallStep) buildRequest(ctx context.Context, params map[string]any, body any) (*http.Request, error) {
+	path := s.operation.path
+	query := url.Values{}
+	headers := http.Header{}
+	// Iterate in declaration order so repeated calls build identical URLs.
+	for _, decl := range s.operation.op.Parameters {
+		val, ok := params[decl.Name]
+		if !ok || val == nil {
+			continue
+		}
+		str := fmt.Sprint(val)
+		switch decl.In {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+decl.Name+"}", url.PathEscape(str))
+		case "query":
+			query.Set(decl.Name, str)
+		case "header":
+			headers.Set(decl.Name, str)
+		}
+	}
+
+	fullURL := s.consumer.baseURL() + path
+	if len(query) > 0 {
+		fullURL += "?" + query.Encode()
+	}
+
+	var bodyReader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal body: %w", err)
+		}
+		bodyReader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, s.operation.method, fullURL, bodyReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	tracing.InjectHeaders(ctx, req.Header)
+	InjectRequestID(ctx, req.Header)
+	for k, v := range headers {
+		req.Header[k] = v
+	}
+	if bodyReader != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
+
+// coerceToSchema converts string values whose schema expects an integer,
+// number or boolean into that type. Values that do not parse are left as
+// they are so that validation reports them.
+func coerceToSchema(val any, schema *openAPISchema) any {
+	if schema == nil {
+		return val
+	}
+	switch v := val.(type) {
+	case map[string]any:
+		for k, item := range v {
+			if ps, ok := schema.Properties[k]; ok {
+				v[k] = coerceToSchema(item, ps)
+			} else if schema.AdditionalProperties != nil {
+				v[k] = coerceToSchema(item, schema.AdditionalProperties.Schema)
+			}
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = coerceToSchema(item, schema.Items)
+		}
+		return v
+	case string:
+		switch schema.Type {
+		case "integer":
+			if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+				return n
+			}
+		case "number":
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				return f
+			}
+		case "boolean":
+			if v == "true" || v == "false" {
+				return v == "true"
+			}
+		}
+	}
+	return val
+}
@@ -0,0 +1,278 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const apiCallTestSpec = `openapi: 3.0.3
+info: {title: Payments, version: "1.0"}
+servers:
+  - url: %s/v1
+paths:
+  /accounts/{accountId}/payments:
+    post:
+      operationId: createPayment
+      parameters:
+        - {name: accountId, in: path, required: true, schema: {type: string, pattern: "^acc-[0-9]+$"}}
+        - {name: dryRun, in: query, schema: {type: boolean}}
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: {$ref: "#/components/schemas/NewPayment"}
+      responses:
+        "201":
+          description: created
+          content:
+            application/json:
+              schema: {$ref: "#/components/schemas/Payment"}
+        4XX:
+          description: rejected
+  /payments/{id}:
+    get:
+      operationId: getPayment
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string}}
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: "#/components/schemas/Payment"}
+components:
+  schemas:
+    NewPayment:
+      type: object
+      required: [amount, currency]
+      properties:
+        amount: {type: integer, minimum: 1}
+        currency: {type: string, enum: [EUR, USD]}
+    Payment:
+      type: object
+      required: [id, amount]
+      properties:
+        id: {type: string}
+        amount: {type: integer}
+        refundOf: {$ref: "#/components/schemas/Payment"}
+`
+
+// newAPICallTestApp starts an API server with handler, and returns an app
+// holding an initialised openapi.consumer named "payments" for it.
+func newAPICallTestApp(t *testing.T, auth *OpenAPIConsumerAuth, handler http.HandlerFunc) (*MockApplication, *httptest.Server) {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	specPath := filepath.Join(t.TempDir(), "payments.yaml")
+	if err := os.WriteFile(specPath, fmt.Appendf(nil, apiCallTestSpec, srv.URL), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	app := NewMockApplication()
+	consumer := NewOpenAPIConsumer("payments", OpenAPIConsumerConfig{SpecFile: specPath, Auth: auth})
+	if err := consumer.Init(app); err != nil {
+		t.Fatalf("consumer init: %v", err)
+	}
+	return app, srv
+}
+
+func createPaymentConfig() map[string]any {
+	return map[string]any{
+		"consumer":  "payments",
+		"operation": "createPayment",
+		"params":    map[string]any{"accountId": "{{ .account }}", "dryRun": "false"},
+		"body":      map[string]any{"amount": "{{ .amount }}", "currency": "EUR"},
+	}
+}
+
+func TestAPICallStep_CallsOperation(t *testing.T) {
+	var gotReq *http.Request
+	var gotBody map[string]any
+	app, _ := newAPICallTestApp(t, &OpenAPIConsumerAuth{Type: OpenAPIAuthBearer, Token: "tok"}, func(w http.ResponseWriter, r *http.Request) {
+		gotReq = r
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &gotBody)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"pay-1","amount":42,"refundOf":{"id":"pay-0"}}`))
+	})
+
+	step, err := NewAPICallStepFactory()("pay", createPaymentConfig(), app)
+	if err != nil {
+		t.Fatalf("factory error: %v", err)
+	}
+	result, err := step.Execute(context.Background(), NewPipelineContext(map[string]any{"account": "acc-7", "amount": 42}, nil))
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	if gotReq.Method != http.MethodPost || gotReq.URL.Path != "/v1/accounts/acc-7/payments" || gotReq.URL.RawQuery != "dryRun=false" {
+		t.Errorf("request = %s %s, want POST /v1/accounts/acc-7/payments?dryRun=false", gotReq.Method, gotReq.URL)
+	}
+	if got := gotReq.Header.Get("Authorization"); got != "Bearer tok" {
+		t.Errorf("Authorization = %q, want the consumer's bearer token", got)
+	}
+	// The templated amount is sent as the integer the schema declares.
+	if gotBody["amount"] != float64(42) || gotBody["currency"] != "EUR" {
+		t.Errorf("body = %v, want amount 42 and currency EUR", gotBody)
+	}
+	if result.Output["status_code"] != http.StatusCreated || result.Output["operation"] != "createPayment" {
+		t.Errorf("output = %v", result.Output)
+	}
+	if body, _ := result.Output["body"].(map[string]any); body["id"] != "pay-1" {
+		t.Errorf("output body = %v, want the parsed response", result.Output["body"])
+	}
+}
+
+func TestAPICallStep_SpecViolations(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     map[string]any
+		response  string
+		status    int
+		wantPhase string
+		wantMsg   string
+	}{
+		{"invalid path parameter", map[string]any{"account": "7", "amount": 5}, "", 0, "request", `parameter "accountId" does not match pattern`},
+		{"body below minimum", map[string]any{"account": "acc-7", "amount": 0}, "", 0, "request", `field "amount" must be >= 1`},
+		{"body of wrong type", map[string]any{"account": "acc-7", "amount": "lots"}, "", 0, "request", `field "amount" must be an integer`},
+		{"response misses required field", map[string]any{"account": "acc-7", "amount": 5}, `{"amount":5}`, http.StatusCreated, "response", `required field "id" is missing`},
+		{"undeclared status", map[string]any{"account": "acc-7", "amount": 5}, `{}`, http.StatusInternalServerError, "response", "status 500 is not declared"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			app, _ := newAPICallTestApp(t, nil, func(w http.ResponseWriter, _ *http.Request) {
+				called = true
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.response))
+			})
+			step, err := NewAPICallStepFactory()("pay", createPaymentConfig(), app)
+			if err != nil {
+				t.Fatalf("factory error: %v", err)
+			}
+			_, err = step.Execute(context.Background(), NewPipelineContext(tt.input, nil))
+
+			var violation *APISpecViolationError
+			if !errors.As(err, &violation) {
+				t.Fatalf("err = %v, want *APISpecViolationError", err)
+			}
+			if violation.Phase != tt.wantPhase || !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("phase %q, err %q; want phase %q containing %q", violation.Phase, err, tt.wantPhase, tt.wantMsg)
+			}
+			if called != (tt.wantPhase == "response") {
+				t.Errorf("server called = %v; a request violation must not be sent", called)
+			}
+		})
+	}
+}
+
+func TestAPICallStep_DeclaredErrorStatus(t *testing.T) {
+	app, _ := newAPICallTestApp(t, nil, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+	})
+	cfg := createPaymentConfig()
+	cfg["error_on_status"] = false
+	step, err := NewAPICallStepFactory()("pay", cfg, app)
+	if err != nil {
+		t.Fatalf("factory error: %v", err)
+	}
+	result, err := step.Execute(context.Background(), NewPipelineContext(map[string]any{"account": "acc-7", "amount": 5}, nil))
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if result.Output["status_code"] != http.StatusConflict {
+		t.Errorf("status_code = %v, want 409 matched by the 4XX response", result.Output["status_code"])
+	}
+}
+
+func TestAPICallStep_TransportError(t *testing.T) {
+	app, srv := newAPICallTestApp(t, nil, func(http.ResponseWriter, *http.Request) {})
+	step, err := NewAPICallStepFactory()("get", map[string]any{
+		"consumer":  "payments",
+		"operation": "getPayment",
+		"params":    map[string]any{"id": "p-1"},
+	}, app)
+	if err != nil {
+		t.Fatalf("factory error: %v", err)
+	}
+	srv.Close()
+
+	_, err = step.Execute(context.Background(), NewPipelineContext(nil, nil))
+	var transport *APITransportError
+	if !errors.As(err, &transport) {
+		t.Fatalf("err = %v, want *APITransportError", err)
+	}
+	var violation *APISpecViolationError
+	if errors.As(err, &violation) {
+		t.Error("a transport error must not be reported as a spec violation")
+	}
+}
+
+func TestAPICallStep_FactoryErrors(t *testing.T) {
+	app, _ := newAPICallTestApp(t, nil, func(http.ResponseWriter, *http.Request) {})
+	tests := []struct {
+		name    string
+		cfg     map[string]any
+		wantErr string
+	}{
+		{"missing consumer", map[string]any{"operation": "getPayment"}, "'consumer' is required"},
+		{"unknown consumer", map[string]any{"consumer": "billing", "operation": "getPayment"}, `openapi.consumer "billing" not found`},
+		{"unknown operation", map[string]any{"consumer": "payments", "operation": "deletePayment"}, `operation "deletePayment" not found`},
+		{"undeclared parameter", map[string]any{"consumer": "payments", "operation": "getPayment", "params": map[string]any{"id": "1", "expand": "all"}}, `no path, query or header parameter "expand"`},
+		{"missing path parameter", map[string]any{"consumer": "payments", "operation": "getPayment"}, `path parameter "id"`},
+		{"body for operation without one", map[string]any{"consumer": "payments", "operation": "getPayment", "params": map[string]any{"id": "1"}, "body": map[string]any{"a": 1}}, "does not accept a request body"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAPICallStepFactory()("call", tt.cfg, app)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOpenAPIConsumerAuthApply(t *testing.T) {
+	tests := []struct {
+		name  string
+		auth  OpenAPIConsumerAuth
+		check func(*http.Request) bool
+	}{
+		{"basic", OpenAPIConsumerAuth{Type: OpenAPIAuthBasic, Username: "u", Password: "p"}, func(r *http.Request) bool {
+			u, p, ok := r.BasicAuth()
+			return ok && u == "u" && p == "p"
+		}},
+		{"api key header", OpenAPIConsumerAuth{Type: OpenAPIAuthAPIKey, Value: "k"}, func(r *http.Request) bool {
+			return r.Header.Get("X-API-Key") == "k"
+		}},
+		{"api key query", OpenAPIConsumerAuth{Type: OpenAPIAuthAPIKey, Name: "key", In: "query", Value: "k"}, func(r *http.Request) bool {
+			return r.URL.Query().Get("key") == "k" && r.URL.Query().Get("page") == "2"
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.auth.validate(); err != nil {
+				t.Fatalf("validate: %v", err)
+			}
+			req := httptest.NewRequest(http.MethodGet, "http://api.example.com/items?page=2", nil)
+			tt.auth.apply(req)
+			if !tt.check(req) {
+				t.Errorf("credentials not applied: %v %v", req.URL, req.Header)
+			}
+		})
+	}
+
+	if err := (&OpenAPIConsumerAuth{Type: "digest"}).validate(); err == nil {
+		t.Error("unsupported auth type was accepted")
+	}
+}
@@ -0,0 +1,129 @@
+package module
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow/interfaces"
+)
+
+// assertion is one condition of an AssertStep.
+type assertion struct {
+	ifExpr  string
+	message string
+}
+
+// AssertStep checks invariants of the pipeline context and fails the
+// pipeline on the first one that does not hold. Each assertion's if is
+// evaluated like the if of step.conditional, where a missing field is
+// false; its message, which may use templates, becomes the error. With
+// warn_only set, violations are logged and reported in the step output
+// instead, and the pipeline continues.
+//
+//	type: step.assert
+//	name: check-order
+//	config:
+//	  status: 422
+//	  assertions:
+//	    - if: "{{ .steps.load.count > 0 }}"
+//	      message: "order {{ .order_id }} has no items"
+//	    - if: "{{ .body.total == .steps.price.total }}"
+//	      message: "total does not match the priced total"
+type AssertStep struct {
+	name       string
+	assertions []assertion
+	status     int
+	warnOnly   bool
+	tmpl       *TemplateEngine
+}
+
+// NewAssertStepFactory returns a StepFactory that creates AssertStep instances.
+func NewAssertStepFactory() StepFactory {
+	return func(name string, config map[string]any, _ modular.Application) (PipelineStep, error) {
+		raw, ok := config["assertions"].([]any)
+		if !ok || len(raw) == 0 {
+			return nil, fmt.Errorf("assert step %q: 'assertions' list is required and must not be empty", name)
+		}
+		assertions := make([]assertion, 0, len(raw))
+		for i, item := range raw {
+			aCfg, ok := item.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("assert step %q: assertions[%d] must be a map", name, i)
+			}
+			a := assertion{}
+			a.ifExpr, _ = aCfg["if"].(string)
+			if a.ifExpr == "" {
+				return nil, fmt.Errorf("assert step %q: assertions[%d]: 'if' is required", name, i)
+			}
+			a.message, _ = aCfg["message"].(string)
+			assertions = append(assertions, a)
+		}
+
+		status := http.StatusInternalServerError
+		if v, ok := config["status"]; ok {
+			n, isInt := intFromAny(v)
+			if !isInt || n < 400 || n > 599 {
+				return nil, fmt.Errorf("assert step %q: 'status' must be an HTTP error status (400-599)", name)
+			}
+			status = n
+		}
+
+		warnOnly := false
+		if v, ok := config["warn_only"]; ok {
+			b, isBool := v.(bool)
+			if !isBool {
+				return nil, fmt.Errorf("assert step %q: 'warn_only' must be a boolean", name)
+			}
+			warnOnly = b
+		}
+
+		return &AssertStep{
+			name:       name,
+			assertions: assertions,
+			status:     status,
+			warnOnly:   warnOnly,
+			tmpl:       NewTemplateEngine(),
+		}, nil
+	}
+}
+
+// Name returns the step name.
+func (s *AssertStep) Name() string { return s.name }
+
+// Execute evaluates the assertions in order. Without warn_only it stops at
+// the first violation and returns it as a ValidationError with the
+// configured status; with warn_only it evaluates every assertion and logs
+// each violation.
+func (s *AssertStep) Execute(_ context.Context, pc *PipelineContext) (*StepResult, error) {
+	violations := []string{}
+	for i, a := range s.assertions {
+		ok, err := evaluateIfExpr(s.tmpl, a.ifExpr, pc)
+		if err != nil {
+			return nil, fmt.Errorf("assert step %q: assertions[%d]: failed to evaluate if expression: %w", s.name, i, err)
+		}
+		if ok {
+			continue
+		}
+		message := "assertion failed: " + a.ifExpr
+		if a.message != "" {
+			if message, err = s.tmpl.Resolve(a.message, pc); err != nil {
+				message = a.message
+			}
+		}
+		if !s.warnOnly {
+			ve := interfaces.NewValidationError(message, s.status)
+			ve.Code = "assertion_failed"
+			return nil, ve
+		}
+		slog.Warn("assertion failed", "step", s.name, "assertion", i, "message", message)
+		violations = append(violations, message)
+	}
+	return &StepResult{Output: map[string]any{
+		"passed":     len(violations) == 0,
+		"checked":    len(s.assertions),
+		"violations": violations,
+	}}, nil
+}
@@ -0,0 +1,109 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/GoCodeAlone/workflow/interfaces"
+)
+
+func assertTestConfig() map[string]any {
+	return map[string]any{
+		"assertions": []any{
+			map[string]any{"if": "{{ .count > 0 }}", "message": "order {{ .order_id }} has no items"},
+			map[string]any{"if": "{{ .customer }}", "message": "customer is required"},
+		},
+	}
+}
+
+func TestAssertStep_Passes(t *testing.T) {
+	step, err := NewAssertStepFactory()("check", assertTestConfig(), nil)
+	if err != nil {
+		t.Fatalf("factory: %v", err)
+	}
+	pc := NewPipelineContext(map[string]any{"count": 3, "customer": "c-1", "order_id": "o-1"}, nil)
+	result, err := step.Execute(context.Background(), pc)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if result.Output["passed"] != true || result.Output["checked"] != 2 {
+		t.Errorf("unexpected output: %v", result.Output)
+	}
+}
+
+func TestAssertStep_FailsOnFirstViolation(t *testing.T) {
+	step, err := NewAssertStepFactory()("check", assertTestConfig(), nil)
+	if err != nil {
+		t.Fatalf("factory: %v", err)
+	}
+	pc := NewPipelineContext(map[string]any{"count": 0, "order_id": "o-7"}, nil)
+	_, err = step.Execute(context.Background(), pc)
+	var ve *interfaces.ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("expected a ValidationError, got %v", err)
+	}
+	if ve.Message != "order o-7 has no items" {
+		t.Errorf("message = %q, want the first violated assertion", ve.Message)
+	}
+	if ve.Status != http.StatusInternalServerError || ve.Code != "assertion_failed" {
+		t.Errorf("status = %d, code = %q", ve.Status, ve.Code)
+	}
+	if p := ProblemFromError(err); p.Status != http.StatusInternalServerError {
+		t.Errorf("problem status = %d, want 500", p.Status)
+	}
+}
+
+func TestAssertStep_CustomStatusAndDefaultMessage(t *testing.T) {
+	cfg := map[string]any{
+		"status":     422,
+		"assertions": []any{map[string]any{"if": "{{ .ok }}"}},
+	}
+	step, err := NewAssertStepFactory()("check", cfg, nil)
+	if err != nil {
+		t.Fatalf("factory: %v", err)
+	}
+	_, err = step.Execute(context.Background(), NewPipelineContext(map[string]any{"ok": false}, nil))
+	if got := interfaces.ValidationErrorStatus(err); got != http.StatusUnprocessableEntity {
+		t.Errorf("status = %d, want 422", got)
+	}
+	if err == nil || err.Error() != "assertion failed: {{ .ok }}" {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestAssertStep_WarnOnly(t *testing.T) {
+	cfg := assertTestConfig()
+	cfg["warn_only"] = true
+	step, err := NewAssertStepFactory()("check", cfg, nil)
+	if err != nil {
+		t.Fatalf("factory: %v", err)
+	}
+	pc := NewPipelineContext(map[string]any{"count": 0, "order_id": "o-7"}, nil)
+	result, err := step.Execute(context.Background(), pc)
+	if err != nil {
+		t.Fatalf("warn_only should not fail: %v", err)
+	}
+	violations, _ := result.Output["violations"].([]string)
+	if result.Output["passed"] != false || len(violations) != 2 || violations[0] != "order o-7 has no items" {
+		t.Errorf("unexpected output: %v", result.Output)
+	}
+}
+
+func TestAssertStep_ConfigErrors(t *testing.T) {
+	tests := map[string]map[string]any{
+		"no assertions": {},
+		"missing if":    {"assertions": []any{map[string]any{"message": "x"}}},
+		"not a map":     {"assertions": []any{"x"}},
+		"bad status":    {"status": 200, "assertions": []any{map[string]any{"if": "{{ .ok }}"}}},
+		"bad warn_only": {"warn_only": "yes", "assertions": []any{map[string]any{"if": "{{ .ok }}"}}},
+	}
+	for name, cfg := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewAssertStepFactory()("check", cfg, nil); err == nil {
+				t.Fatal("expected a config error")
+			}
+		})
+	}
+}
@@ -0,0 +1,327 @@
+package module
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow/artifact"
+	"github.com/GoCodeAlone/workflow/version"
+)
+
+var sha256DigestPattern = regexp.MustCompile(`^(?:sha256:)?([0-9a-f]{64})$`)
+
+// attestMaterial is a build material configured on step.attest.
+type attestMaterial struct {
+	name   string
+	uri    string
+	digest string
+}
+
+// AttestStep creates an in-toto statement carrying SLSA v1 provenance for a
+// built artifact, signs it into a DSSE envelope with a key from a secrets
+// provider, and stores the envelope as an execution artifact. The
+// provenance records the pipeline, its config hash and the engine version,
+// and lists the execution's artifacts, with their checksums, as materials.
+// With push enabled the envelope is attached to the image with cosign.
+type AttestStep struct {
+	name             string
+	subject          string
+	digest           string
+	builderID        string
+	materials        []attestMaterial
+	includeArtifacts bool
+	keyProvider      string
+	keyName          string
+	keyID            string
+	outputPath       string
+	artifactKey      string
+	push             bool
+	app              modular.Application
+	tmpl             *TemplateEngine
+	execCommand      func(ctx context.Context, name string, args ...string) *exec.Cmd
+}
+
+// NewAttestStepFactory returns a StepFactory that creates AttestStep instances.
+func NewAttestStepFactory() StepFactory {
+	return func(name string, config map[string]any, app modular.Application) (PipelineStep, error) {
+		s := &AttestStep{
+			name:             name,
+			builderID:        DefaultWorkflowBuilderID,
+			includeArtifacts: true,
+			artifactKey:      "provenance.intoto.json",
+			app:              app,
+			tmpl:             NewTemplateEngine(),
+			execCommand:      exec.CommandContext,
+		}
+		s.subject, _ = config["subject"].(string)
+		if s.subject == "" {
+			return nil, fmt.Errorf("attest step %q: 'subject' is required", name)
+		}
+		s.digest, _ = config["digest"].(string)
+		if v, _ := config["builder_id"].(string); v != "" {
+			s.builderID = v
+		}
+		if v, ok := config["include_artifacts"].(bool); ok {
+			s.includeArtifacts = v
+		}
+
+		if raw, ok := config["materials"]; ok {
+			list, ok := raw.([]any)
+			if !ok {
+				return nil, fmt.Errorf("attest step %q: 'materials' must be a list", name)
+			}
+			for i, item := range list {
+				m, _ := item.(map[string]any)
+				var mat attestMaterial
+				mat.name, _ = m["name"].(string)
+				mat.uri, _ = m["uri"].(string)
+				mat.digest, _ = m["digest"].(string)
+				if mat.uri == "" && mat.name == "" {
+					return nil, fmt.Errorf("attest step %q: materials[%d] needs a 'uri' or 'name'", name, i)
+				}
+				s.materials = append(s.materials, mat)
+			}
+		}
+
+		s.keyProvider, _ = config["signing_key_provider"].(string)
+		s.keyName, _ = config["signing_key"].(string)
+		if s.keyProvider == "" || s.keyName == "" {
+			return nil, fmt.Errorf("attest step %q: 'signing_key_provider' and 'signing_key' are required", name)
+		}
+		s.keyID, _ = config["key_id"].(string)
+
+		s.outputPath, _ = config["output_path"].(string)
+		if key, ok := config["artifact_key"].(string); ok {
+			s.artifactKey = key
+		}
+		s.push, _ = config["push"].(bool)
+		return s, nil
+	}
+}
+
+// Name returns the step name.
+func (s *AttestStep) Name() string { return s.name }
+
+// Execute builds, signs, stores and optionally pushes the attestation.
+func (s *AttestStep) Execute(ctx context.Context, pc *PipelineContext) (*StepResult, error) {
+	subject, digest, err := s.resolveSubject(pc)
+	if err != nil {
+		return nil, fmt.Errorf("attest step %q: %w", s.name, err)
+	}
+	materials, err := s.resolveMaterials(ctx, pc)
+	if err != nil {
+		return nil, fmt.Errorf("attest step %q: %w", s.name, err)
+	}
+
+	statement := s.buildStatement(pc, subject, digest, materials)
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		return nil, fmt.Errorf("attest step %q: encode statement: %w", s.name, err)
+	}
+	keyPEM, err := LookupSecret(ctx, s.app, s.keyProvider, s.keyName)
+	if err != nil {
+		return nil, fmt.Errorf("attest step %q: signing key: %w", s.name, err)
+	}
+	signer, err := ParseSigningKey([]byte(keyPEM))
+	if err != nil {
+		return nil, fmt.Errorf("attest step %q: signing key: %w", s.name, err)
+	}
+	envelope, err := SignDSSE(DSSEInTotoPayloadType, payload, signer, s.keyID)
+	if err != nil {
+		return nil, fmt.Errorf("attest step %q: %w", s.name, err)
+	}
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return nil, fmt.Errorf("attest step %q: encode envelope: %w", s.name, err)
+	}
+
+	output := map[string]any{
+		"subject":        subject,
+		"digest":         "sha256:" + digest,
+		"predicate_type": SLSAProvenancePredicateType,
+		"materials":      len(materials),
+		"statement":      statement,
+		"pushed":         false,
+	}
+	store, _ := pc.Metadata["artifact_store"].(artifact.Store)
+	executionID, _ := pc.Metadata["execution_id"].(string)
+	if s.artifactKey != "" && store != nil && executionID != "" {
+		if err := store.Put(ctx, executionID, s.artifactKey, bytes.NewReader(data)); err != nil {
+			return nil, fmt.Errorf("attest step %q: store attestation artifact %q: %w", s.name, s.artifactKey, err)
+		}
+		output["artifact"] = s.artifactKey
+	}
+
+	if s.outputPath != "" || s.push {
+		path, err := s.writeEnvelope(pc, data)
+		if err != nil {
+			return nil, fmt.Errorf("attest step %q: %w", s.name, err)
+		}
+		output["path"] = path
+		if s.push {
+			if err := s.attach(ctx, path, subject, digest); err != nil {
+				return nil, fmt.Errorf("attest step %q: %w", s.name, err)
+			}
+			output["pushed"] = true
+		}
+	}
+	return &StepResult{Output: output}, nil
+}
+
+// resolveSubject returns the subject name and its SHA-256 digest in hex.
+// The digest comes from the digest config or, failing that, an
+// image@sha256:... subject reference.
+func (s *AttestStep) resolveSubject(pc *PipelineContext) (string, string, error) {
+	subject, err := s.tmpl.Resolve(s.subject, pc)
+	if err != nil {
+		return "", "", fmt.Errorf("resolve subject: %w", err)
+	}
+	digest, err := s.tmpl.Resolve(s.digest, pc)
+	if err != nil {
+		return "", "", fmt.Errorf("resolve digest: %w", err)
+	}
+	if name, ref, ok := strings.Cut(subject, "@"); ok {
+		subject = name
+		if digest == "" {
+			digest = ref
+		}
+	}
+	if subject == "" {
+		return "", "", fmt.Errorf("subject resolved to an empty name")
+	}
+	m := sha256DigestPattern.FindStringSubmatch(digest)
+	if m == nil {
+		return "", "", fmt.Errorf("subject digest %q is not a sha256 digest", digest)
+	}
+	return subject, m[1], nil
+}
+
+// resolveMaterials returns the configured materials followed by the
+// execution's artifacts, other than this step's own attestation.
+func (s *AttestStep) resolveMaterials(ctx context.Context, pc *PipelineContext) ([]SLSAResourceDescriptor, error) {
+	materials := make([]SLSAResourceDescriptor, 0, len(s.materials))
+	for _, m := range s.materials {
+		var d SLSAResourceDescriptor
+		var err error
+		if d.Name, err = s.tmpl.Resolve(m.name, pc); err != nil {
+			return nil, fmt.Errorf("resolve material name: %w", err)
+		}
+		if d.URI, err = s.tmpl.Resolve(m.uri, pc); err != nil {
+			return nil, fmt.Errorf("resolve material uri: %w", err)
+		}
+		digest, err := s.tmpl.Resolve(m.digest, pc)
+		if err != nil {
+			return nil, fmt.Errorf("resolve material digest: %w", err)
+		}
+		if digest != "" {
+			match := sha256DigestPattern.FindStringSubmatch(digest)
+			if match == nil {
+				return nil, fmt.Errorf("material digest %q is not a sha256 digest", digest)
+			}
+			d.Digest = map[string]string{"sha256": match[1]}
+		}
+		materials = append(materials, d)
+	}
+
+	store, _ := pc.Metadata["artifact_store"].(artifact.Store)
+	executionID, _ := pc.Metadata["execution_id"].(string)
+	if !s.includeArtifacts || store == nil || executionID == "" {
+		return materials, nil
+	}
+	artifacts, err := store.List(ctx, executionID)
+	if err != nil {
+		return nil, fmt.Errorf("list execution artifacts: %w", err)
+	}
+	sort.Slice(artifacts, func(i, j int) bool { return artifacts[i].Key < artifacts[j].Key })
+	for _, a := range artifacts {
+		if a.Key == s.artifactKey || a.Checksum == "" {
+			continue
+		}
+		materials = append(materials, SLSAResourceDescriptor{
+			Name:   a.Key,
+			URI:    "artifact://" + executionID + "/" + a.Key,
+			Digest: map[string]string{"sha256": a.Checksum},
+		})
+	}
+	return materials, nil
+}
+
+func (s *AttestStep) buildStatement(pc *PipelineContext, subject, digest string, materials []SLSAResourceDescriptor) *InTotoStatement {
+	pipeline, _ := pc.Metadata["pipeline"].(string)
+	executionID, _ := pc.Metadata["execution_id"].(string)
+	startedAt, _ := pc.Metadata["started_at"].(string)
+	internal := map[string]any{"engine_version": version.Get().Version}
+	if hash, _ := pc.Metadata["config_hash"].(string); hash != "" {
+		internal["config_hash"] = hash
+	}
+	return &InTotoStatement{
+		Type:          InTotoStatementType,
+		Subject:       []InTotoSubject{{Name: subject, Digest: map[string]string{"sha256": digest}}},
+		PredicateType: SLSAProvenancePredicateType,
+		Predicate: SLSAProvenance{
+			BuildDefinition: SLSABuildDefinition{
+				BuildType:            WorkflowBuildType,
+				ExternalParameters:   map[string]any{"pipeline": pipeline},
+				InternalParameters:   internal,
+				ResolvedDependencies: materials,
+			},
+			RunDetails: SLSARunDetails{
+				Builder: SLSABuilder{ID: s.builderID, Version: map[string]string{"workflow": version.Get().Version}},
+				Metadata: SLSABuildMetadata{
+					InvocationID: executionID,
+					StartedOn:    startedAt,
+					FinishedOn:   time.Now().UTC().Format(time.RFC3339),
+				},
+			},
+		},
+	}
+}
+
+// writeEnvelope writes the envelope to output_path, or to a temporary file
+// when only pushing.
+func (s *AttestStep) writeEnvelope(pc *PipelineContext, data []byte) (string, error) {
+	if s.outputPath == "" {
+		f, err := os.CreateTemp("", "workflow-attestation-*.intoto.json")
+		if err != nil {
+			return "", fmt.Errorf("create attestation file: %w", err)
+		}
+		defer f.Close()
+		if _, err := f.Write(data); err != nil {
+			return "", fmt.Errorf("write attestation: %w", err)
+		}
+		return f.Name(), nil
+	}
+	path, err := s.tmpl.Resolve(s.outputPath, pc)
+	if err != nil {
+		return "", fmt.Errorf("resolve output_path: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return "", fmt.Errorf("create dir for %q: %w", path, err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", fmt.Errorf("write attestation: %w", err)
+	}
+	return path, nil
+}
+
+// attach pushes the envelope to the subject image's registry with
+// `cosign attach attestation`, using cosign's registry credentials.
+func (s *AttestStep) attach(ctx context.Context, path, subject, digest string) error {
+	var stderr bytes.Buffer
+	cmd := s.execCommand(ctx, "cosign", "attach", "attestation", "--attestation", path, subject+"@sha256:"+digest) //nolint:gosec // G204: args from step configuration
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("cosign attach attestation failed: %w\nstderr: %s", err, stderr.String())
+	}
+	return nil
+}
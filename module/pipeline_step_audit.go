@@ -0,0 +1,230 @@
+package module
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow/audit"
+)
+
+// DefaultAuditStoreService is the service name step.audit writes to when no
+// store is configured. The server registers its audit logger under this name.
+const DefaultAuditStoreService = "audit.logger"
+
+// auditWriteTimeout bounds how long step.audit waits for the store.
+const auditWriteTimeout = 10 * time.Second
+
+// stdoutAuditLogger is the fallback store when no audit service is registered.
+var stdoutAuditLogger = audit.NewLogger(os.Stdout)
+
+// AuditStep writes a structured audit entry (actor, action, resource,
+// details) for business events such as approvals or data exports.
+//
+// The entry is written synchronously when the step runs, outside any
+// pipeline transaction and detached from pipeline cancellation, so it
+// persists even if later steps fail. A store error fails the step so an
+// action is never silently left unaudited.
+type AuditStep struct {
+	name      string
+	eventType audit.EventType
+	action    string
+	resource  string
+	actor     string
+	detail    string
+	details   map[string]any
+	success   any
+	storeName string
+	app       modular.Application
+	tmpl      *TemplateEngine
+}
+
+// NewAuditStepFactory returns a StepFactory that creates AuditStep instances.
+func NewAuditStepFactory() StepFactory {
+	return func(name string, config map[string]any, app modular.Application) (PipelineStep, error) {
+		action, _ := config["action"].(string)
+		if action == "" {
+			return nil, fmt.Errorf("audit step %q: 'action' is required", name)
+		}
+
+		eventType := audit.EventBusiness
+		if t, ok := config["type"].(string); ok && t != "" {
+			eventType = audit.EventType(t)
+		}
+
+		var details map[string]any
+		if raw, ok := config["details"]; ok {
+			details, ok = raw.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("audit step %q: 'details' must be a map", name)
+			}
+		}
+
+		success := config["success"]
+		switch success.(type) {
+		case nil, bool, string:
+		default:
+			return nil, fmt.Errorf("audit step %q: 'success' must be a boolean or template string", name)
+		}
+
+		storeName, _ := config["store"].(string)
+		resource, _ := config["resource"].(string)
+		actor, _ := config["actor"].(string)
+		detail, _ := config["detail"].(string)
+
+		return &AuditStep{
+			name:      name,
+			eventType: eventType,
+			action:    action,
+			resource:  resource,
+			actor:     actor,
+			detail:    detail,
+			details:   details,
+			success:   success,
+			storeName: storeName,
+			app:       app,
+			tmpl:      NewTemplateEngine(),
+		}, nil
+	}
+}
+
+// Name returns the step name.
+func (s *AuditStep) Name() string { return s.name }
+
+// Execute resolves the entry templates and writes the entry to the audit store.
+func (s *AuditStep) Execute(ctx context.Context, pc *PipelineContext) (*StepResult, error) {
+	store, err := s.resolveStore()
+	if err != nil {
+		return nil, err
+	}
+
+	event := audit.Event{
+		Timestamp: time.Now().UTC(),
+		Type:      s.eventType,
+		Success:   true,
+	}
+	for _, f := range []struct {
+		field string
+		tmpl  string
+		dst   *string
+	}{
+		{"action", s.action, &event.Action},
+		{"resource", s.resource, &event.Resource},
+		{"actor", s.actor, &event.Actor},
+		{"detail", s.detail, &event.Detail},
+	} {
+		if f.tmpl == "" {
+			continue
+		}
+		v, err := s.tmpl.Resolve(f.tmpl, pc)
+		if err != nil {
+			return nil, fmt.Errorf("audit step %q: failed to resolve %s: %w", s.name, f.field, err)
+		}
+		*f.dst = v
+	}
+	if event.Actor == "" {
+		event.Actor = auditActor(pc)
+	}
+
+	switch v := s.success.(type) {
+	case bool:
+		event.Success = v
+	case string:
+		resolved, err := s.tmpl.Resolve(v, pc)
+		if err != nil {
+			return nil, fmt.Errorf("audit step %q: failed to resolve success: %w", s.name, err)
+		}
+		if event.Success, err = strconv.ParseBool(resolved); err != nil {
+			return nil, fmt.Errorf("audit step %q: success %q is not a boolean", s.name, resolved)
+		}
+	}
+
+	if len(s.details) > 0 {
+		resolved, err := s.tmpl.ResolveMap(s.details, pc)
+		if err != nil {
+			return nil, fmt.Errorf("audit step %q: failed to resolve details: %w", s.name, err)
+		}
+		event.Metadata = resolved
+	}
+	if pipeline, ok := pc.Metadata["pipeline"].(string); ok && pipeline != "" {
+		if event.Metadata == nil {
+			event.Metadata = map[string]any{}
+		}
+		if _, exists := event.Metadata["pipeline"]; !exists {
+			event.Metadata["pipeline"] = pipeline
+		}
+	}
+	if req, ok := pc.Metadata["_http_request"].(*http.Request); ok && req != nil {
+		event.SourceIP = extractClientIP(req)
+	}
+
+	// Detach from pipeline cancellation so a timed-out or cancelled pipeline
+	// cannot abort an audit write that is already under way.
+	writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), auditWriteTimeout)
+	defer cancel()
+	if err := store.Record(writeCtx, event); err != nil {
+		return nil, fmt.Errorf("audit step %q: failed to record entry: %w", s.name, err)
+	}
+
+	return &StepResult{Output: map[string]any{
+		"recorded":  true,
+		"action":    event.Action,
+		"actor":     event.Actor,
+		"resource":  event.Resource,
+		"timestamp": event.Timestamp.Format(time.RFC3339Nano),
+	}}, nil
+}
+
+// resolveStore returns the configured audit store. Without an explicit store
+// it uses the DefaultAuditStoreService when registered, else stdout.
+func (s *AuditStep) resolveStore() (audit.Store, error) {
+	name := s.storeName
+	if name == "" {
+		name = DefaultAuditStoreService
+	}
+	var svc any
+	if s.app != nil {
+		svc = s.app.SvcRegistry()[name]
+	}
+	if svc == nil {
+		if s.storeName != "" {
+			return nil, fmt.Errorf("audit step %q: store %q not found", s.name, s.storeName)
+		}
+		return stdoutAuditLogger, nil
+	}
+	store, ok := svc.(audit.Store)
+	if !ok {
+		return nil, fmt.Errorf("audit step %q: service %q does not implement audit.Store", s.name, name)
+	}
+	return store, nil
+}
+
+// auditActor resolves the acting identity from authentication state in the
+// pipeline: the subject from step.auth_validate, auth middleware claims on
+// the HTTP request, or an mTLS client certificate. It returns "system" for
+// pipelines with no authenticated caller.
+func auditActor(pc *PipelineContext) string {
+	if v, ok := pc.Current["auth_user_id"].(string); ok && v != "" {
+		return v
+	}
+	if req, ok := pc.Metadata["_http_request"].(*http.Request); ok && req != nil {
+		if actor := extractTriggeredBy(req); actor != "" {
+			return actor
+		}
+		if id := ClientCertIdentityFromContext(req.Context()); id != nil {
+			return id.CommonName
+		}
+	}
+	if auth, ok := pc.Current["_auth"].(map[string]any); ok {
+		if cert, ok := auth["client_cert"].(map[string]any); ok {
+			if cn, ok := cert["cn"].(string); ok && cn != "" {
+				return cn
+			}
+		}
+	}
+	return "system"
+}
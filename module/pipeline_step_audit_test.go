@@ -0,0 +1,141 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/GoCodeAlone/workflow/audit"
+)
+
+type recordingAuditStore struct {
+	events []audit.Event
+	ctxErr error
+	err    error
+}
+
+func (s *recordingAuditStore) Record(ctx context.Context, event audit.Event) error {
+	s.ctxErr = ctx.Err()
+	if s.err != nil {
+		return s.err
+	}
+	s.events = append(s.events, event)
+	return nil
+}
+
+func TestAuditStep_RecordsEntry(t *testing.T) {
+	store := &recordingAuditStore{}
+	app := NewMockApplication()
+	app.Services["audit-db"] = store
+
+	step, err := NewAuditStepFactory()("audit-approval", map[string]any{
+		"action":   "order.approved",
+		"resource": "order:{{ .order_id }}",
+		"details":  map[string]any{"amount": "{{ .total }}"},
+		"store":    "audit-db",
+	}, app)
+	if err != nil {
+		t.Fatalf("factory error: %v", err)
+	}
+
+	req := httptest.NewRequest("POST", "/orders/42/approve", nil)
+	req.RemoteAddr = "10.0.0.7:5123"
+	pc := NewPipelineContext(map[string]any{"order_id": "42", "total": "99.50"}, map[string]any{
+		"pipeline":      "approve-order",
+		"_http_request": req,
+	})
+	pc.MergeStepOutput("auth", map[string]any{"auth_user_id": "user-7"})
+
+	// A cancelled pipeline context must not prevent the write.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, err := step.Execute(ctx, pc)
+	if err != nil {
+		t.Fatalf("execute error: %v", err)
+	}
+	if store.ctxErr != nil {
+		t.Errorf("store received cancelled context: %v", store.ctxErr)
+	}
+	if len(store.events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(store.events))
+	}
+	ev := store.events[0]
+	if ev.Type != audit.EventBusiness || ev.Action != "order.approved" || ev.Resource != "order:42" || ev.Actor != "user-7" || !ev.Success {
+		t.Errorf("unexpected event: %+v", ev)
+	}
+	if ev.SourceIP != "10.0.0.7" {
+		t.Errorf("SourceIP = %q, want 10.0.0.7", ev.SourceIP)
+	}
+	if ev.Metadata["amount"] != "99.50" || ev.Metadata["pipeline"] != "approve-order" {
+		t.Errorf("unexpected metadata: %v", ev.Metadata)
+	}
+	if result.Output["recorded"] != true || result.Output["actor"] != "user-7" {
+		t.Errorf("unexpected output: %v", result.Output)
+	}
+}
+
+func TestAuditStep_DefaultStoreAndActor(t *testing.T) {
+	store := &recordingAuditStore{}
+	app := NewMockApplication()
+	app.Services[DefaultAuditStoreService] = store
+
+	step, err := NewAuditStepFactory()("audit", map[string]any{
+		"action":  "export",
+		"success": "{{ .ok }}",
+	}, app)
+	if err != nil {
+		t.Fatalf("factory error: %v", err)
+	}
+
+	pc := NewPipelineContext(map[string]any{
+		"ok":    "false",
+		"_auth": map[string]any{"client_cert": map[string]any{"cn": "billing"}},
+	}, nil)
+	if _, err := step.Execute(context.Background(), pc); err != nil {
+		t.Fatalf("execute error: %v", err)
+	}
+	if len(store.events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(store.events))
+	}
+	if ev := store.events[0]; ev.Actor != "billing" || ev.Success {
+		t.Errorf("unexpected event: %+v", ev)
+	}
+
+	if actor := auditActor(NewPipelineContext(nil, nil)); actor != "system" {
+		t.Errorf("auditActor() = %q, want system", actor)
+	}
+}
+
+func TestAuditStep_Errors(t *testing.T) {
+	factory := NewAuditStepFactory()
+	if _, err := factory("a", map[string]any{}, nil); err == nil {
+		t.Error("expected error for missing action")
+	}
+	if _, err := factory("a", map[string]any{"action": "x", "details": "nope"}, nil); err == nil {
+		t.Error("expected error for non-map details")
+	}
+
+	app := NewMockApplication()
+	app.Services["failing"] = &recordingAuditStore{err: errors.New("disk full")}
+	app.Services["not-a-store"] = struct{}{}
+
+	for _, tt := range []struct {
+		store string
+		want  string
+	}{
+		{"failing", "disk full"},
+		{"not-a-store", "does not implement audit.Store"},
+		{"missing", "not found"},
+	} {
+		step, err := factory("a", map[string]any{"action": "x", "store": tt.store}, app)
+		if err != nil {
+			t.Fatalf("factory error: %v", err)
+		}
+		_, err = step.Execute(context.Background(), NewPipelineContext(nil, nil))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("store %q: error = %v, want containing %q", tt.store, err, tt.want)
+		}
+	}
+}
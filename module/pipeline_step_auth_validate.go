@@ -2,7 +2,6 @@ package module
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
@@ -100,19 +99,9 @@ func (s *AuthValidateStep) Execute(_ context.Context, pc *PipelineContext) (*Ste
 	return &StepResult{Output: output}, nil
 }
 
-// unauthorizedResponse writes a 401 JSON error response and stops the pipeline.
+// unauthorizedResponse writes a 401 problem response and stops the pipeline.
 func (s *AuthValidateStep) unauthorizedResponse(pc *PipelineContext, message string) (*StepResult, error) {
-	errorBody := map[string]any{
-		"error":   "unauthorized",
-		"message": message,
-	}
-
-	if w, ok := pc.Metadata["_http_response_writer"].(http.ResponseWriter); ok {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusUnauthorized)
-		_ = json.NewEncoder(w).Encode(errorBody)
-		pc.Metadata["_response_handled"] = true
-	}
+	writeStepProblem(pc, s.name, NewProblem(ProblemUnauthorized, message))
 
 	return &StepResult{
 		Output: map[string]any{
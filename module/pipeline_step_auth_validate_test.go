@@ -291,11 +291,11 @@ func TestAuthValidateStep_WritesHTTPResponse(t *testing.T) {
 	if w.Code != http.StatusUnauthorized {
 		t.Errorf("expected HTTP 401, got %d", w.Code)
 	}
-	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
-		t.Errorf("expected Content-Type application/json, got %q", ct)
+	if ct := w.Header().Get("Content-Type"); ct != ProblemContentType {
+		t.Errorf("expected Content-Type %s, got %q", ProblemContentType, ct)
 	}
-	if !strings.Contains(w.Body.String(), "unauthorized") {
-		t.Errorf("expected 'unauthorized' in response body, got %q", w.Body.String())
+	if body := w.Body.String(); !strings.Contains(body, `"code":"unauthorized"`) || !strings.Contains(body, `"step":"auth"`) {
+		t.Errorf("expected a unauthorized problem naming the step in response body, got %q", body)
 	}
 	if pc.Metadata["_response_handled"] != true {
 		t.Error("expected _response_handled=true in metadata")
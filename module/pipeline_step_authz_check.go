@@ -118,28 +118,22 @@ func (s *AuthzCheckStep) Execute(ctx context.Context, pc *PipelineContext) (*Ste
 	}}, nil
 }
 
-// forbiddenResponse writes a 403 JSON error response to the HTTP response
-// writer (when present) and stops the pipeline. The response body format
-// matches the expected {"error":"forbidden: ..."} shape described in the issue.
+// forbiddenResponse writes a 403 problem response to the HTTP response
+// writer (when present) and stops the pipeline. The problem is also returned
+// as response_status/response_body for triggers that write the response
+// from the pipeline output.
 func (s *AuthzCheckStep) forbiddenResponse(pc *PipelineContext, message string) (*StepResult, error) {
 	errorMsg := fmt.Sprintf("forbidden: %s", message)
-	errorBody := map[string]any{
-		"error": errorMsg,
-	}
-
-	if w, ok := pc.Metadata["_http_response_writer"].(http.ResponseWriter); ok {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusForbidden)
-		_ = json.NewEncoder(w).Encode(errorBody)
-		pc.Metadata["_response_handled"] = true
-	}
+	p := NewProblem(ProblemForbidden, message)
+	writeStepProblem(pc, s.name, p)
 
+	body, _ := json.Marshal(p)
 	return &StepResult{
 		Output: map[string]any{
 			"response_status": http.StatusForbidden,
-			"response_body":   fmt.Sprintf(`{"error":%q}`, errorMsg),
+			"response_body":   string(body),
 			"response_headers": map[string]string{
-				"Content-Type": "application/json",
+				"Content-Type": ProblemContentType,
 			},
 			"error": errorMsg,
 		},
@@ -102,11 +102,11 @@ func TestAuthzCheckStep_WritesHTTPResponse(t *testing.T) {
 	if w.Code != http.StatusForbidden {
 		t.Errorf("expected HTTP 403, got %d", w.Code)
 	}
-	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
-		t.Errorf("expected Content-Type application/json, got %q", ct)
+	if ct := w.Header().Get("Content-Type"); ct != ProblemContentType {
+		t.Errorf("expected Content-Type %s, got %q", ProblemContentType, ct)
 	}
-	if !strings.Contains(w.Body.String(), "forbidden") {
-		t.Errorf("expected 'forbidden' in response body, got %q", w.Body.String())
+	if body := w.Body.String(); !strings.Contains(body, `"code":"forbidden"`) || !strings.Contains(body, `"step":"authz"`) {
+		t.Errorf("expected a forbidden problem naming the step in response body, got %q", body)
 	}
 	if pc.Metadata["_response_handled"] != true {
 		t.Error("expected _response_handled=true in metadata")
@@ -139,8 +139,8 @@ func TestAuthzCheckStep_WritesHTTPResponse_NoResponseWriter(t *testing.T) {
 		t.Errorf("expected response_status=403, got %v", result.Output["response_status"])
 	}
 	headers, _ := result.Output["response_headers"].(map[string]string)
-	if headers["Content-Type"] != "application/json" {
-		t.Errorf("expected response_headers Content-Type=application/json, got %v", headers)
+	if headers["Content-Type"] != ProblemContentType {
+		t.Errorf("expected response_headers Content-Type=%s, got %v", ProblemContentType, headers)
 	}
 }
 
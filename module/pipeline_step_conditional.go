@@ -133,7 +133,14 @@ func (s *ConditionalStep) Execute(_ context.Context, pc *PipelineContext) (*Step
 }
 
 func (s *ConditionalStep) evaluateIf(pc *PipelineContext) (bool, error) {
-	expr := strings.TrimSpace(s.ifExpr)
+	return evaluateIfExpr(s.tmpl, s.ifExpr, pc)
+}
+
+// evaluateIfExpr evaluates an if condition. A {{ }} expression containing a
+// comparison operator is evaluated by the expression engine; anything else is
+// resolved as a template. The result is interpreted by truthyString.
+func evaluateIfExpr(tmpl *TemplateEngine, ifExpr string, pc *PipelineContext) (bool, error) {
+	expr := strings.TrimSpace(ifExpr)
 	if strings.HasPrefix(expr, "{{") && strings.HasSuffix(expr, "}}") {
 		inner := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(expr, "{{"), "}}"))
 		if strings.Contains(inner, "==") || strings.Contains(inner, "!=") || strings.Contains(inner, ">") || strings.Contains(inner, "<") {
@@ -145,7 +152,7 @@ func (s *ConditionalStep) evaluateIf(pc *PipelineContext) (bool, error) {
 			return truthyString(resolved), nil
 		}
 	}
-	resolved, err := s.tmpl.Resolve(expr, pc)
+	resolved, err := tmpl.Resolve(expr, pc)
 	if err != nil {
 		return false, err
 	}
@@ -154,7 +161,7 @@ func (s *ConditionalStep) evaluateIf(pc *PipelineContext) (bool, error) {
 
 func truthyString(value string) bool {
 	switch strings.ToLower(strings.TrimSpace(value)) {
-	case "", "0", "false", "no", "n", "off", "<nil>":
+	case "", "0", "false", "no", "n", "off", "<nil>", "<no value>":
 		return false
 	default:
 		return true
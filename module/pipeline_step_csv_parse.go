@@ -0,0 +1,395 @@
+package module
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/GoCodeAlone/modular"
+)
+
+const (
+	// defaultCSVMaxRows caps the number of data rows step.csv_parse accepts.
+	defaultCSVMaxRows = 10000
+	// defaultCSVMaxBytes caps the size of the CSV input.
+	defaultCSVMaxBytes = 10 << 20
+)
+
+// CSVParseStep parses CSV data from the pipeline context or from a file
+// uploaded in a multipart/form-data request into a list of row maps.
+//
+// Configuration:
+//
+//	source: "body.csv"        # dot-path to a CSV string or []byte value
+//	file_field: "file"        # or: multipart form field holding the uploaded file
+//	delimiter: ","            # single character; "\t" or "tab" for TSV
+//	header: true              # first row holds column names (default true)
+//	columns: [sku, qty]       # column names; required when header is false
+//	types: {qty: int}         # per-column coercion: string, int, float, bool
+//	trim_space: true          # trim whitespace around values (default true)
+//	max_rows: 10000           # fail when the input has more data rows
+//	max_errors: 0             # row errors tolerated before the step fails
+//	max_bytes: 10485760       # maximum input size
+//	target: "rows"            # output key for the parsed rows
+//
+// Rows that fail coercion, have the wrong number of fields, or are malformed
+// are left out of the result and reported in the "errors" output as maps with
+// line, column, and error keys. The step fails once the number of row errors
+// exceeds max_errors.
+type CSVParseStep struct {
+	name      string
+	source    string
+	fileField string
+	delimiter rune
+	header    bool
+	columns   []string
+	types     map[string]string
+	trimSpace bool
+	maxRows   int
+	maxErrors int
+	maxBytes  int64
+	target    string
+}
+
+// NewCSVParseStepFactory returns a StepFactory that creates CSVParseStep instances.
+func NewCSVParseStepFactory() StepFactory {
+	return func(name string, config map[string]any, _ modular.Application) (PipelineStep, error) {
+		step := &CSVParseStep{
+			name:      name,
+			delimiter: ',',
+			header:    true,
+			trimSpace: true,
+			maxRows:   defaultCSVMaxRows,
+			maxBytes:  defaultCSVMaxBytes,
+			target:    "rows",
+		}
+
+		step.source, _ = config["source"].(string)
+		step.fileField, _ = config["file_field"].(string)
+		switch {
+		case step.source == "" && step.fileField == "":
+			return nil, fmt.Errorf("csv_parse step %q: 'source' or 'file_field' is required", name)
+		case step.source != "" && step.fileField != "":
+			return nil, fmt.Errorf("csv_parse step %q: 'source' and 'file_field' are mutually exclusive", name)
+		}
+
+		if d, ok := config["delimiter"].(string); ok && d != "" {
+			switch d {
+			case `\t`, "tab":
+				d = "\t"
+			}
+			r, size := utf8.DecodeRuneInString(d)
+			if size != len(d) || r == '"' || r == '\r' || r == '\n' || r == utf8.RuneError {
+				return nil, fmt.Errorf("csv_parse step %q: 'delimiter' must be a single character other than a quote or newline", name)
+			}
+			step.delimiter = r
+		}
+		if v, ok := config["header"].(bool); ok {
+			step.header = v
+		}
+		if v, ok := config["trim_space"].(bool); ok {
+			step.trimSpace = v
+		}
+
+		if raw, ok := config["columns"].([]any); ok {
+			for _, c := range raw {
+				col, ok := c.(string)
+				if !ok || col == "" {
+					return nil, fmt.Errorf("csv_parse step %q: 'columns' must be a list of non-empty strings", name)
+				}
+				step.columns = append(step.columns, col)
+			}
+		}
+		if !step.header && len(step.columns) == 0 {
+			return nil, fmt.Errorf("csv_parse step %q: 'columns' is required when 'header' is false", name)
+		}
+
+		if raw, ok := config["types"].(map[string]any); ok {
+			step.types = make(map[string]string, len(raw))
+			for col, t := range raw {
+				typ, _ := t.(string)
+				switch typ {
+				case "string", "int", "float", "bool":
+				default:
+					return nil, fmt.Errorf("csv_parse step %q: column %q has unsupported type %v (expected string, int, float, or bool)", name, col, t)
+				}
+				step.types[col] = typ
+			}
+		}
+
+		for key, dst := range map[string]*int{"max_rows": &step.maxRows, "max_errors": &step.maxErrors} {
+			if v, ok := config[key]; ok {
+				n, ok := csvConfigInt(v)
+				if !ok || n < 0 {
+					return nil, fmt.Errorf("csv_parse step %q: '%s' must be a non-negative integer", name, key)
+				}
+				*dst = n
+			}
+		}
+		if v, ok := config["max_bytes"]; ok {
+			n, ok := csvConfigInt(v)
+			if !ok || n <= 0 {
+				return nil, fmt.Errorf("csv_parse step %q: 'max_bytes' must be a positive integer", name)
+			}
+			step.maxBytes = int64(n)
+		}
+		if t, ok := config["target"].(string); ok && t != "" {
+			step.target = t
+		}
+
+		return step, nil
+	}
+}
+
+func csvConfigInt(v any) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case float64:
+		return int(n), n == float64(int(n))
+	}
+	return 0, false
+}
+
+// Name returns the step name.
+func (s *CSVParseStep) Name() string { return s.name }
+
+// Execute reads the CSV input and parses it into row maps.
+func (s *CSVParseStep) Execute(_ context.Context, pc *PipelineContext) (*StepResult, error) {
+	data, filename, err := s.readInput(pc)
+	if err != nil {
+		return nil, fmt.Errorf("csv_parse step %q: %w", s.name, err)
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comma = s.delimiter
+	r.FieldsPerRecord = -1
+	r.ReuseRecord = true
+
+	columns := s.columns
+	if s.header {
+		record, err := r.Read()
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("csv_parse step %q: failed to read header: %w", s.name, err)
+		}
+		if len(columns) == 0 {
+			columns = make([]string, len(record))
+			for i, col := range record {
+				columns[i] = strings.TrimSpace(col)
+			}
+		}
+	}
+	seen := make(map[string]bool, len(columns))
+	for i, col := range columns {
+		if col == "" {
+			return nil, fmt.Errorf("csv_parse step %q: column %d has no name", s.name, i+1)
+		}
+		if seen[col] {
+			return nil, fmt.Errorf("csv_parse step %q: duplicate column %q", s.name, col)
+		}
+		seen[col] = true
+	}
+
+	rows := make([]any, 0)
+	rowErrors := make([]any, 0)
+	dataRows := 0
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var parseErr *csv.ParseError
+		if err != nil && !errors.As(err, &parseErr) {
+			return nil, fmt.Errorf("csv_parse step %q: %w", s.name, err)
+		}
+
+		dataRows++
+		if dataRows > s.maxRows {
+			return nil, fmt.Errorf("csv_parse step %q: input has more than %d rows (max_rows)", s.name, s.maxRows)
+		}
+
+		var row map[string]any
+		var rowErr map[string]any
+		if parseErr != nil {
+			// The reader skips past a malformed record, so parsing continues.
+			rowErr = map[string]any{"line": parseErr.StartLine, "error": parseErr.Err.Error()}
+		} else {
+			line, _ := r.FieldPos(0)
+			row, rowErr = s.parseRecord(record, columns, line)
+		}
+		if rowErr != nil {
+			rowErrors = append(rowErrors, rowErr)
+			if len(rowErrors) > s.maxErrors {
+				return nil, s.tooManyErrors(rowErrors)
+			}
+			continue
+		}
+		rows = append(rows, row)
+	}
+
+	colList := make([]any, len(columns))
+	for i, c := range columns {
+		colList[i] = c
+	}
+	output := map[string]any{
+		s.target:      rows,
+		"row_count":   len(rows),
+		"columns":     colList,
+		"errors":      rowErrors,
+		"error_count": len(rowErrors),
+	}
+	if filename != "" {
+		output["filename"] = filename
+	}
+	return &StepResult{Output: output}, nil
+}
+
+// parseRecord maps a record onto the column names and applies the configured
+// type coercions.
+func (s *CSVParseStep) parseRecord(record, columns []string, line int) (row, rowErr map[string]any) {
+	if len(record) != len(columns) {
+		return nil, map[string]any{"line": line, "error": fmt.Sprintf("expected %d fields, got %d", len(columns), len(record))}
+	}
+	row = make(map[string]any, len(columns))
+	for i, col := range columns {
+		value := record[i]
+		if s.trimSpace {
+			value = strings.TrimSpace(value)
+		}
+		typ := s.types[col]
+		if typ == "" || typ == "string" {
+			row[col] = value
+			continue
+		}
+		if value == "" {
+			row[col] = nil
+			continue
+		}
+		v, err := coerceCSVValue(value, typ)
+		if err != nil {
+			return nil, map[string]any{"line": line, "column": col, "error": err.Error()}
+		}
+		row[col] = v
+	}
+	return row, nil
+}
+
+func coerceCSVValue(value, typ string) (any, error) {
+	switch typ {
+	case "int":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", value)
+		}
+		return n, nil
+	case "float":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", value)
+		}
+		return f, nil
+	case "bool":
+		switch strings.ToLower(value) {
+		case "true", "yes", "y", "1":
+			return true, nil
+		case "false", "no", "n", "0":
+			return false, nil
+		}
+		return nil, fmt.Errorf("%q is not a boolean", value)
+	}
+	return value, nil
+}
+
+func (s *CSVParseStep) tooManyErrors(rowErrors []any) error {
+	first := rowErrors[0].(map[string]any)
+	at := fmt.Sprintf("line %d", first["line"])
+	if col, ok := first["column"].(string); ok {
+		at += fmt.Sprintf(", column %q", col)
+	}
+	return fmt.Errorf("csv_parse step %q: %d row error(s) exceeds max_errors %d; first at %s: %s",
+		s.name, len(rowErrors), s.maxErrors, at, first["error"])
+}
+
+// readInput returns the CSV bytes and, for uploads, the client's filename.
+func (s *CSVParseStep) readInput(pc *PipelineContext) ([]byte, string, error) {
+	if s.source != "" {
+		var data []byte
+		switch v := resolveBodyFrom(s.source, pc).(type) {
+		case string:
+			data = []byte(v)
+		case []byte:
+			data = v
+		case nil:
+			return nil, "", fmt.Errorf("source %q not found or resolved to nil", s.source)
+		default:
+			return nil, "", fmt.Errorf("source %q resolved to %T, want string or bytes", s.source, v)
+		}
+		if int64(len(data)) > s.maxBytes {
+			return nil, "", fmt.Errorf("input exceeds max_bytes (%d)", s.maxBytes)
+		}
+		return data, "", nil
+	}
+	return s.readUpload(pc)
+}
+
+// readUpload extracts the file_field part from a multipart/form-data request.
+// The request body is cached in _raw_body so later steps can read it again.
+func (s *CSVParseStep) readUpload(pc *PipelineContext) ([]byte, string, error) {
+	req, _ := pc.Metadata["_http_request"].(*http.Request)
+	if req == nil {
+		return nil, "", fmt.Errorf("file_field %q requires an HTTP request", s.fileField)
+	}
+	mediaType, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/form-data" || params["boundary"] == "" {
+		return nil, "", fmt.Errorf("file_field %q requires a multipart/form-data request", s.fileField)
+	}
+
+	body, ok := pc.Metadata["_raw_body"].([]byte)
+	if !ok {
+		if req.Body == nil {
+			return nil, "", fmt.Errorf("request has no body")
+		}
+		// Allow room for the multipart framing around the file.
+		limit := s.maxBytes + 1<<20
+		body, err = io.ReadAll(io.LimitReader(req.Body, limit+1))
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read request body: %w", err)
+		}
+		if int64(len(body)) > limit {
+			return nil, "", fmt.Errorf("upload exceeds max_bytes (%d)", s.maxBytes)
+		}
+		pc.Metadata["_raw_body"] = body
+	}
+
+	mr := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil, "", fmt.Errorf("no file uploaded in form field %q", s.fileField)
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read multipart body: %w", err)
+		}
+		if part.FormName() != s.fileField {
+			continue
+		}
+		data, err := io.ReadAll(io.LimitReader(part, s.maxBytes+1))
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read uploaded file: %w", err)
+		}
+		if int64(len(data)) > s.maxBytes {
+			return nil, "", fmt.Errorf("uploaded file exceeds max_bytes (%d)", s.maxBytes)
+		}
+		return data, part.FileName(), nil
+	}
+}
@@ -0,0 +1,180 @@
+package module
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCSVParseStep_TypedRowsFromContext(t *testing.T) {
+	step, err := NewCSVParseStepFactory()("import", map[string]any{
+		"source": "steps.fetch.csv",
+		"types":  map[string]any{"qty": "int", "price": "float", "active": "bool"},
+		"target": "products",
+	}, nil)
+	if err != nil {
+		t.Fatalf("factory error: %v", err)
+	}
+
+	pc := NewPipelineContext(nil, nil)
+	pc.MergeStepOutput("fetch", map[string]any{
+		"csv": "\xef\xbb\xbfsku, qty ,price,active\n" +
+			"A-1,3,9.99,yes\n" +
+			"\"B,2\", 10 ,0.5,false\n" +
+			"C-3,,1,1\n",
+	})
+	result, err := step.Execute(context.Background(), pc)
+	if err != nil {
+		t.Fatalf("execute error: %v", err)
+	}
+
+	rows := result.Output["products"].([]any)
+	if len(rows) != 3 || result.Output["row_count"] != 3 || result.Output["error_count"] != 0 {
+		t.Fatalf("unexpected output: %v", result.Output)
+	}
+	first := rows[0].(map[string]any)
+	if first["sku"] != "A-1" || first["qty"] != int64(3) || first["price"] != 9.99 || first["active"] != true {
+		t.Errorf("unexpected first row: %v", first)
+	}
+	if second := rows[1].(map[string]any); second["sku"] != "B,2" || second["qty"] != int64(10) {
+		t.Errorf("unexpected second row: %v", second)
+	}
+	if third := rows[2].(map[string]any); third["qty"] != nil {
+		t.Errorf("expected empty typed value to be nil, got %v", third["qty"])
+	}
+	if cols := result.Output["columns"].([]any); len(cols) != 4 || cols[1] != "qty" {
+		t.Errorf("unexpected columns: %v", cols)
+	}
+}
+
+func TestCSVParseStep_NoHeaderAndDelimiter(t *testing.T) {
+	step, err := NewCSVParseStepFactory()("import", map[string]any{
+		"source":    "data",
+		"delimiter": `\t`,
+		"header":    false,
+		"columns":   []any{"name", "score"},
+		"types":     map[string]any{"score": "float"},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pc := NewPipelineContext(map[string]any{"data": []byte("ada\t9.5\nbob\t7\n")}, nil)
+	result, err := step.Execute(context.Background(), pc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := result.Output["rows"].([]any)
+	if len(rows) != 2 || rows[1].(map[string]any)["score"] != 7.0 {
+		t.Errorf("unexpected rows: %v", rows)
+	}
+}
+
+func TestCSVParseStep_RowErrors(t *testing.T) {
+	input := "id,qty\n1,2\n2,two\n3\n4,\"5\n"
+	newStep := func(maxErrors int) PipelineStep {
+		step, err := NewCSVParseStepFactory()("import", map[string]any{
+			"source":     "csv",
+			"types":      map[string]any{"qty": "int"},
+			"max_errors": maxErrors,
+		}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return step
+	}
+
+	result, err := newStep(5).Execute(context.Background(), NewPipelineContext(map[string]any{"csv": input}, nil))
+	if err != nil {
+		t.Fatalf("execute error: %v", err)
+	}
+	if result.Output["row_count"] != 1 || result.Output["error_count"] != 3 {
+		t.Fatalf("unexpected counts: %v", result.Output)
+	}
+	errs := result.Output["errors"].([]any)
+	if e := errs[0].(map[string]any); e["line"] != 3 || e["column"] != "qty" || !strings.Contains(e["error"].(string), "not an integer") {
+		t.Errorf("unexpected coercion error: %v", e)
+	}
+	if e := errs[1].(map[string]any); e["line"] != 4 || e["error"] != "expected 2 fields, got 1" {
+		t.Errorf("unexpected field count error: %v", e)
+	}
+	if e := errs[2].(map[string]any); e["line"] != 5 {
+		t.Errorf("unexpected parse error: %v", e)
+	}
+
+	_, err = newStep(0).Execute(context.Background(), NewPipelineContext(map[string]any{"csv": input}, nil))
+	if err == nil || !strings.Contains(err.Error(), `first at line 3, column "qty"`) {
+		t.Errorf("expected max_errors failure, got %v", err)
+	}
+}
+
+func TestCSVParseStep_MaxRows(t *testing.T) {
+	step, err := NewCSVParseStepFactory()("import", map[string]any{"source": "csv", "max_rows": 2}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = step.Execute(context.Background(), NewPipelineContext(map[string]any{"csv": "a\n1\n2\n3\n"}, nil))
+	if err == nil || !strings.Contains(err.Error(), "more than 2 rows") {
+		t.Errorf("expected max_rows error, got %v", err)
+	}
+}
+
+func TestCSVParseStep_Upload(t *testing.T) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	_ = mw.WriteField("note", "weekly import")
+	fw, _ := mw.CreateFormFile("file", "products.csv")
+	_, _ = fw.Write([]byte("sku,qty\nA,1\nB,2\n"))
+	_ = mw.Close()
+
+	req := httptest.NewRequest("POST", "/import", bytes.NewReader(body.Bytes()))
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	step, err := NewCSVParseStepFactory()("import", map[string]any{"file_field": "file"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pc := NewPipelineContext(nil, map[string]any{"_http_request": req})
+	result, err := step.Execute(context.Background(), pc)
+	if err != nil {
+		t.Fatalf("execute error: %v", err)
+	}
+	if result.Output["filename"] != "products.csv" || result.Output["row_count"] != 2 {
+		t.Errorf("unexpected output: %v", result.Output)
+	}
+	if cached, _ := pc.Metadata["_raw_body"].([]byte); !bytes.Equal(cached, body.Bytes()) {
+		t.Error("expected the request body to be cached for later steps")
+	}
+
+	// A missing field and a non-multipart request are errors.
+	step, _ = NewCSVParseStepFactory()("import", map[string]any{"file_field": "upload"}, nil)
+	if _, err := step.Execute(context.Background(), pc); err == nil || !strings.Contains(err.Error(), `no file uploaded in form field "upload"`) {
+		t.Errorf("expected missing field error, got %v", err)
+	}
+	plain := httptest.NewRequest("POST", "/import", strings.NewReader("a,b"))
+	plain.Header.Set("Content-Type", "text/csv")
+	if _, err := step.Execute(context.Background(), NewPipelineContext(nil, map[string]any{"_http_request": plain})); err == nil {
+		t.Error("expected error for non-multipart request")
+	}
+}
+
+func TestCSVParseStep_FactoryErrors(t *testing.T) {
+	factory := NewCSVParseStepFactory()
+	for _, cfg := range []map[string]any{
+		{},
+		{"source": "a", "file_field": "b"},
+		{"source": "a", "delimiter": ";;"},
+		{"source": "a", "delimiter": `"`},
+		{"source": "a", "header": false},
+		{"source": "a", "types": map[string]any{"qty": "decimal"}},
+		{"source": "a", "max_rows": -1},
+		{"source": "a", "max_errors": 1.5},
+		{"source": "a", "max_bytes": 0},
+	} {
+		if _, err := factory("csv", cfg, nil); err == nil {
+			t.Errorf("expected factory error for %v", cfg)
+		}
+	}
+}
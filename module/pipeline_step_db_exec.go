@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/GoCodeAlone/modular"
 )
@@ -21,6 +22,7 @@ type DBExecStep struct {
 	mode            string // "list" or "single" — used only when returning is true
 	app             modular.Application
 	tmpl            *TemplateEngine
+	queries         *dbQueryRecorder
 }
 
 // NewDBExecStepFactory returns a StepFactory that creates DBExecStep instances.
@@ -72,6 +74,7 @@ func NewDBExecStepFactory() StepFactory {
 			mode:            mode,
 			app:             app,
 			tmpl:            NewTemplateEngine(),
+			queries:         newDBQueryRecorder(name, app),
 		}, nil
 	}
 }
@@ -116,14 +119,9 @@ func (s *DBExecStep) Execute(ctx context.Context, pc *PipelineContext) (*StepRes
 		driver = dp.DriverName()
 	}
 
-	// Resolve template params
-	resolvedParams := make([]any, len(s.params))
-	for i, p := range s.params {
-		resolved, err := s.tmpl.Resolve(p, pc)
-		if err != nil {
-			return nil, fmt.Errorf("db_exec step %q: failed to resolve param %d: %w", s.name, i, err)
-		}
-		resolvedParams[i] = resolved
+	resolvedParams, err := s.resolveParams(pc)
+	if err != nil {
+		return nil, err
 	}
 
 	// Apply automatic tenant scoping when tenantKey is configured.
@@ -155,9 +153,14 @@ func (s *DBExecStep) Execute(ctx context.Context, pc *PipelineContext) (*StepRes
 	// engine converts to ? for SQLite automatically.
 	query = normalizePlaceholders(query, driver)
 
+	dq := dbQuery{database: s.database, db: db, driver: driver, query: query, params: resolvedParams}
+
 	// When returning is true, use QueryContext() so that RETURNING clause rows are available.
 	if s.returning {
-		rows, err := db.QueryContext(ctx, query, resolvedParams...)
+		start := time.Now()
+		results, err := queryRows(ctx, db, query, resolvedParams)
+		dq.duration, dq.rows, dq.err = time.Since(start), int64(len(results)), err
+		stats := s.queries.record(pc, dq)
 		if err != nil {
 			if s.ignoreError {
 				output := map[string]any{"ignored_error": err.Error()}
@@ -168,34 +171,34 @@ func (s *DBExecStep) Execute(ctx context.Context, pc *PipelineContext) (*StepRes
 					output["rows"] = []map[string]any{}
 					output["count"] = 0
 				}
-				return &StepResult{Output: output}, nil
+				return &StepResult{Output: output, DBQuery: stats}, nil
 			}
-			return nil, fmt.Errorf("db_exec step %q: query failed: %w", s.name, err)
-		}
-		defer rows.Close()
-
-		results, err := scanSQLRows(rows)
-		if err != nil {
 			return nil, fmt.Errorf("db_exec step %q: %w", s.name, err)
 		}
 
-		return &StepResult{Output: formatQueryOutput(results, s.mode)}, nil
+		return &StepResult{Output: formatQueryOutput(results, s.mode), DBQuery: stats}, nil
 	}
 
 	// Execute statement
+	start := time.Now()
 	result, err := db.ExecContext(ctx, query, resolvedParams...)
+	dq.duration, dq.err = time.Since(start), err
+	if err == nil {
+		dq.rows, _ = result.RowsAffected()
+	}
+	stats := s.queries.record(pc, dq)
 	if err != nil {
 		if s.ignoreError {
 			return &StepResult{Output: map[string]any{
 				"affected_rows": int64(0),
 				"last_id":       "0",
 				"ignored_error": err.Error(),
-			}}, nil
+			}, DBQuery: stats}, nil
 		}
 		return nil, fmt.Errorf("db_exec step %q: exec failed: %w", s.name, err)
 	}
 
-	affectedRows, _ := result.RowsAffected()
+	affectedRows := dq.rows
 	lastID, _ := result.LastInsertId()
 
 	output := map[string]any{
@@ -203,5 +206,45 @@ func (s *DBExecStep) Execute(ctx context.Context, pc *PipelineContext) (*StepRes
 		"last_id":       fmt.Sprintf("%d", lastID),
 	}
 
-	return &StepResult{Output: output}, nil
+	return &StepResult{Output: output, DBQuery: stats}, nil
+}
+
+// resolveParams resolves the template params against the pipeline context.
+func (s *DBExecStep) resolveParams(pc *PipelineContext) ([]any, error) {
+	resolvedParams := make([]any, len(s.params))
+	for i, p := range s.params {
+		resolved, err := s.tmpl.Resolve(p, pc)
+		if err != nil {
+			return nil, fmt.Errorf("db_exec step %q: failed to resolve param %d: %w", s.name, i, err)
+		}
+		resolvedParams[i] = resolved
+	}
+	return resolvedParams, nil
+}
+
+// DryRunPreview returns the resolved SQL and params the step would execute,
+// without touching the database. Tenant scoping, which depends on the
+// database service, is reported by its tenantKey rather than applied.
+func (s *DBExecStep) DryRunPreview(_ context.Context, pc *PipelineContext) (map[string]any, error) {
+	query := s.query
+	if s.allowDynamicSQL {
+		var err error
+		query, err = resolveDynamicSQL(s.tmpl, query, pc)
+		if err != nil {
+			return nil, fmt.Errorf("db_exec step %q: %w", s.name, err)
+		}
+	}
+	params, err := s.resolveParams(pc)
+	if err != nil {
+		return nil, err
+	}
+	preview := map[string]any{
+		"database": s.database,
+		"query":    query,
+		"params":   params,
+	}
+	if s.tenantKey != "" {
+		preview["tenantKey"] = s.tenantKey
+	}
+	return preview, nil
 }
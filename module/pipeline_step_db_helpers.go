@@ -1,10 +1,21 @@
 package module
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 )
 
+// queryRows runs a query and returns its rows as column→value maps.
+func queryRows(ctx context.Context, db *sql.DB, query string, params []any) ([]map[string]any, error) {
+	rows, err := db.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+	return scanSQLRows(rows)
+}
+
 // scanSQLRows iterates over rows and returns a slice of column→value maps.
 // []byte values are decoded via parseJSONBytesOrString, which transparently
 // handles PostgreSQL json/jsonb columns (returned as raw JSON bytes by pgx)
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/GoCodeAlone/modular"
 )
@@ -23,6 +24,23 @@ type DBDriverProvider interface {
 	DriverName() string
 }
 
+// DBReadProvider is optionally implemented by DBProvider modules that can
+// serve reads from a read replica. ReadDB returns the pool a read-only query
+// should use; writes and transactions use DB().
+type DBReadProvider interface {
+	DBProvider
+	ReadDB() *sql.DB
+}
+
+// readDB returns the pool svc serves reads from, or provider.DB() when svc
+// has no replicas or usePrimary is set.
+func readDB(svc any, provider DBProvider, usePrimary bool) *sql.DB {
+	if rp, ok := svc.(DBReadProvider); ok && !usePrimary {
+		return rp.ReadDB()
+	}
+	return provider.DB()
+}
+
 // DBQueryStep executes a parameterized SQL SELECT against a named database service.
 type DBQueryStep struct {
 	name            string
@@ -32,8 +50,10 @@ type DBQueryStep struct {
 	mode            string // "list" or "single"
 	tenantKey       string // dot-path to resolve tenant value for automatic scoping
 	allowDynamicSQL bool
+	usePrimary      bool // read from the primary even when the database has replicas
 	app             modular.Application
 	tmpl            *TemplateEngine
+	queries         *dbQueryRecorder
 }
 
 // NewDBQueryStepFactory returns a StepFactory that creates DBQueryStep instances.
@@ -68,6 +88,7 @@ func NewDBQueryStepFactory() StepFactory {
 		}
 
 		tenantKey, _ := config["tenantKey"].(string)
+		usePrimary, _ := config["use_primary"].(bool)
 
 		return &DBQueryStep{
 			name:            name,
@@ -77,8 +98,10 @@ func NewDBQueryStepFactory() StepFactory {
 			mode:            mode,
 			tenantKey:       tenantKey,
 			allowDynamicSQL: allowDynamicSQL,
+			usePrimary:      usePrimary,
 			app:             app,
 			tmpl:            NewTemplateEngine(),
+			queries:         newDBQueryRecorder(name, app),
 		}, nil
 	}
 }
@@ -113,7 +136,7 @@ func (s *DBQueryStep) Execute(ctx context.Context, pc *PipelineContext) (*StepRe
 		return nil, fmt.Errorf("db_query step %q: service %q does not implement DBProvider", s.name, s.database)
 	}
 
-	db := provider.DB()
+	db := readDB(svc, provider, s.usePrimary)
 	if db == nil {
 		return nil, fmt.Errorf("db_query step %q: database connection is nil", s.name)
 	}
@@ -158,17 +181,15 @@ func (s *DBQueryStep) Execute(ctx context.Context, pc *PipelineContext) (*StepRe
 	// engine converts to ? for SQLite automatically.
 	query = normalizePlaceholders(query, driver)
 
-	// Execute query
-	rows, err := db.QueryContext(ctx, query, resolvedParams...)
-	if err != nil {
-		return nil, fmt.Errorf("db_query step %q: query failed: %w", s.name, err)
-	}
-	defer rows.Close()
-
-	results, err := scanSQLRows(rows)
+	// Execute query, timing it together with fetching its rows.
+	dq := dbQuery{database: s.database, db: db, driver: driver, query: query, params: resolvedParams}
+	start := time.Now()
+	results, err := queryRows(ctx, db, query, resolvedParams)
+	dq.duration, dq.rows, dq.err = time.Since(start), int64(len(results)), err
+	stats := s.queries.record(pc, dq)
 	if err != nil {
 		return nil, fmt.Errorf("db_query step %q: %w", s.name, err)
 	}
 
-	return &StepResult{Output: formatQueryOutput(results, s.mode)}, nil
+	return &StepResult{Output: formatQueryOutput(results, s.mode), DBQuery: stats}, nil
 }
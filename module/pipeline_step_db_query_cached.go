@@ -182,7 +182,7 @@ func (s *DBQueryCachedStep) runQuery(ctx context.Context, pc *PipelineContext) (
 		return nil, fmt.Errorf("db_query_cached step %q: service %q does not implement DBProvider", s.name, s.database)
 	}
 
-	db := provider.DB()
+	db := readDB(svc, provider, false)
 	if db == nil {
 		return nil, fmt.Errorf("db_query_cached step %q: database connection is nil", s.name)
 	}
@@ -8,19 +8,31 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 
 	"github.com/GoCodeAlone/modular"
 )
 
 // DelegateStep forwards the HTTP request to a named service implementing
-// http.Handler. This is a "passthrough" pipeline step: the delegate service
-// handles the full HTTP response (status, headers, body). Because the
-// delegate writes to the ResponseWriter directly, this step sets
+// http.Handler. By default this is a "passthrough" pipeline step: the
+// delegate service handles the full HTTP response (status, headers, body).
+// Because the delegate writes to the ResponseWriter directly, this step sets
 // _response_handled in pipeline metadata and returns Stop: true.
+//
+// With capture: true the delegate's response is buffered into the step
+// output (status_code, headers, body) instead, and the pipeline continues,
+// so later steps can transform or wrap it before responding. body, body_from
+// and headers replace parts of the forwarded request with values from the
+// pipeline context.
 type DelegateStep struct {
-	name    string
-	service string
-	app     modular.Application
+	name     string
+	service  string
+	capture  bool
+	body     any
+	bodyFrom string
+	headers  map[string]any
+	app      modular.Application
+	tmpl     *TemplateEngine
 }
 
 // NewDelegateStepFactory returns a StepFactory that creates DelegateStep instances.
@@ -31,11 +43,39 @@ func NewDelegateStepFactory() StepFactory {
 			return nil, fmt.Errorf("delegate step %q: 'service' is required", name)
 		}
 
-		return &DelegateStep{
+		step := &DelegateStep{
 			name:    name,
 			service: service,
+			body:    config["body"],
 			app:     app,
-		}, nil
+			tmpl:    NewTemplateEngine(),
+		}
+		if v, ok := config["capture"]; ok {
+			capture, ok := v.(bool)
+			if !ok {
+				return nil, fmt.Errorf("delegate step %q: 'capture' must be a boolean", name)
+			}
+			step.capture = capture
+		}
+		if v, ok := config["body_from"]; ok {
+			from, ok := v.(string)
+			if !ok || from == "" {
+				return nil, fmt.Errorf("delegate step %q: 'body_from' must be a non-empty path", name)
+			}
+			if step.body != nil {
+				return nil, fmt.Errorf("delegate step %q: 'body' and 'body_from' are mutually exclusive", name)
+			}
+			step.bodyFrom = from
+		}
+		if v, ok := config["headers"]; ok {
+			headers, ok := v.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("delegate step %q: 'headers' must be a map", name)
+			}
+			step.headers = headers
+		}
+
+		return step, nil
 	}
 }
 
@@ -44,10 +84,11 @@ func (s *DelegateStep) Name() string { return s.name }
 
 // Execute forwards the request to the delegate service.
 // It reads _http_request and _http_response_writer from the pipeline context
-// metadata. If these are present (live HTTP context), the delegate writes
-// directly to the response writer. If not present (e.g., test context), it
-// uses httptest.ResponseRecorder and returns the captured response as output.
-func (s *DelegateStep) Execute(_ context.Context, pc *PipelineContext) (*StepResult, error) {
+// metadata. If these are present (live HTTP context) and capture is off, the
+// delegate writes directly to the response writer. Otherwise (capture mode,
+// or e.g. a test context) it uses httptest.ResponseRecorder and returns the
+// captured response as output.
+func (s *DelegateStep) Execute(ctx context.Context, pc *PipelineContext) (*StepResult, error) {
 	// Resolve the service from the registry
 	if s.app == nil {
 		return nil, fmt.Errorf("delegate step %q: no application context", s.name)
@@ -63,11 +104,49 @@ func (s *DelegateStep) Execute(_ context.Context, pc *PipelineContext) (*StepRes
 		return nil, fmt.Errorf("delegate step %q: service %q does not implement http.Handler", s.name, s.service)
 	}
 
+	body, hasBody, err := s.requestBody(pc)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check for live HTTP context in metadata
 	req, hasReq := pc.Metadata["_http_request"].(*http.Request)
 	w, hasWriter := pc.Metadata["_http_response_writer"].(http.ResponseWriter)
 
-	if hasReq && hasWriter {
+	if hasReq {
+		req = req.Clone(req.Context())
+	} else {
+		// No live HTTP context: reconstruct a minimal request from trigger data.
+		method, _ := pc.TriggerData["method"].(string)
+		if method == "" {
+			method = "GET"
+		}
+		path, _ := pc.TriggerData["path"].(string)
+		if path == "" {
+			path = "/"
+		}
+		req, err = http.NewRequestWithContext(ctx, method, path, nil)
+		if err != nil {
+			return nil, fmt.Errorf("delegate step %q: failed to create request: %w", s.name, err)
+		}
+		if triggerBody, ok := pc.TriggerData["body"]; ok && !hasBody {
+			body, hasBody = triggerBody, true
+		}
+	}
+	if hasBody {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("delegate step %q: failed to encode request body: %w", s.name, err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(data))
+		req.ContentLength = int64(len(data))
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if err := s.applyHeaders(req, pc); err != nil {
+		return nil, err
+	}
+
+	if hasReq && hasWriter && !s.capture {
 		// Live HTTP context: delegate writes directly to the response writer
 		handler.ServeHTTP(w, req)
 		pc.Metadata["_response_handled"] = true
@@ -79,43 +158,21 @@ func (s *DelegateStep) Execute(_ context.Context, pc *PipelineContext) (*StepRes
 		}, nil
 	}
 
-	// No live HTTP context: use a recorder to capture the response.
-	// Reconstruct a minimal request from trigger data.
-	method, _ := pc.TriggerData["method"].(string)
-	if method == "" {
-		method = "GET"
-	}
-	path, _ := pc.TriggerData["path"].(string)
-	if path == "" {
-		path = "/"
-	}
-
-	var bodyReader io.Reader
-	if body, ok := pc.TriggerData["body"]; ok {
-		data, err := json.Marshal(body)
-		if err == nil {
-			bodyReader = bytes.NewReader(data)
-		}
-	}
-
-	testReq, err := http.NewRequest(method, path, bodyReader)
-	if err != nil {
-		return nil, fmt.Errorf("delegate step %q: failed to create request: %w", s.name, err)
-	}
-	if bodyReader != nil {
-		testReq.Header.Set("Content-Type", "application/json")
-	}
-
 	recorder := httptest.NewRecorder()
-	handler.ServeHTTP(recorder, testReq)
+	handler.ServeHTTP(recorder, req)
 
 	result := recorder.Result()
 	defer result.Body.Close()
 	respBody, _ := io.ReadAll(result.Body)
 
+	headers := make(map[string]any, len(result.Header))
+	for k, v := range result.Header {
+		headers[k] = strings.Join(v, ", ")
+	}
 	output := map[string]any{
 		"delegated_to": s.service,
 		"status_code":  result.StatusCode,
+		"headers":      headers,
 	}
 
 	var jsonResp any
@@ -127,3 +184,59 @@ func (s *DelegateStep) Execute(_ context.Context, pc *PipelineContext) (*StepRes
 
 	return &StepResult{Output: output}, nil
 }
+
+// requestBody resolves the body or body_from config into the body forwarded
+// to the delegate. It reports false when neither is set.
+func (s *DelegateStep) requestBody(pc *PipelineContext) (any, bool, error) {
+	body, ok, err := resolveForwardBody(s.tmpl, s.body, s.bodyFrom, pc)
+	if err != nil {
+		return nil, false, fmt.Errorf("delegate step %q: %w", s.name, err)
+	}
+	return body, ok, nil
+}
+
+// applyHeaders sets the configured headers, resolved against the pipeline
+// context, on the forwarded request.
+func (s *DelegateStep) applyHeaders(req *http.Request, pc *PipelineContext) error {
+	if err := applyForwardHeaders(s.tmpl, s.headers, req, pc); err != nil {
+		return fmt.Errorf("delegate step %q: %w", s.name, err)
+	}
+	return nil
+}
+
+// resolveForwardBody resolves a body (a value whose strings are templates)
+// or bodyFrom (a dotted path) config into the body of an in-process request.
+// It reports false when neither is set.
+func resolveForwardBody(tmpl *TemplateEngine, body any, bodyFrom string, pc *PipelineContext) (any, bool, error) {
+	if bodyFrom != "" {
+		return resolveBodyFrom(bodyFrom, pc), true, nil
+	}
+	if body == nil {
+		return nil, false, nil
+	}
+	resolved, err := tmpl.ResolveMap(map[string]any{"body": body}, pc)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to resolve body: %w", err)
+	}
+	if str, ok := resolved["body"].(string); ok {
+		return decodeJSONObjectOrArray(str), true, nil
+	}
+	return resolved["body"], true, nil
+}
+
+// applyForwardHeaders sets headers, resolved against the pipeline context,
+// on an in-process request.
+func applyForwardHeaders(tmpl *TemplateEngine, headers map[string]any, req *http.Request, pc *PipelineContext) error {
+	for k, v := range headers {
+		str, ok := v.(string)
+		if !ok {
+			str = fmt.Sprint(v)
+		}
+		resolved, err := tmpl.Resolve(str, pc)
+		if err != nil {
+			return fmt.Errorf("failed to resolve header %q: %w", k, err)
+		}
+		req.Header.Set(k, resolved)
+	}
+	return nil
+}
@@ -1,6 +1,11 @@
 package module
 
 import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 )
@@ -39,3 +44,120 @@ func TestDelegateStep_WithApp(t *testing.T) {
 		t.Errorf("expected name 'fwd', got %q", step.Name())
 	}
 }
+
+// echoHandler answers with the request it received, so tests can check what
+// the delegate step forwarded.
+var echoHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Delegate", "orders")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"method": r.Method,
+		"tenant": r.Header.Get("X-Tenant"),
+		"body":   string(body),
+	})
+})
+
+func newDelegateStep(t *testing.T, config map[string]any) PipelineStep {
+	t.Helper()
+	app := NewMockApplication()
+	_ = app.RegisterService("orders-api", echoHandler)
+	config["service"] = "orders-api"
+	step, err := NewDelegateStepFactory()("delegate", config, app)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return step
+}
+
+func liveDelegateContext() (*PipelineContext, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(`{"sku":"a"}`))
+	pc := NewPipelineContext(map[string]any{"tenant": "acme"}, map[string]any{
+		"_http_request":         req,
+		"_http_response_writer": w,
+	})
+	return pc, w
+}
+
+func TestDelegateStep_PassthroughWritesResponse(t *testing.T) {
+	step := newDelegateStep(t, map[string]any{})
+	pc, w := liveDelegateContext()
+
+	result, err := step.Execute(context.Background(), pc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Stop || pc.Metadata["_response_handled"] != true {
+		t.Errorf("expected passthrough to stop the pipeline and mark the response handled")
+	}
+	if w.Code != http.StatusCreated || !strings.Contains(w.Body.String(), `{\"sku\":\"a\"}`) {
+		t.Errorf("delegate response not written: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestDelegateStep_CaptureBuffersResponse(t *testing.T) {
+	step := newDelegateStep(t, map[string]any{
+		"capture":   true,
+		"body_from": "steps.transform.order",
+		"headers":   map[string]any{"X-Tenant": "{{ .tenant }}"},
+	})
+	pc, w := liveDelegateContext()
+	pc.MergeStepOutput("transform", map[string]any{"order": map[string]any{"sku": "b", "qty": 2}})
+
+	result, err := step.Execute(context.Background(), pc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Stop || pc.Metadata["_response_handled"] != nil {
+		t.Error("capture mode must let the pipeline continue")
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("capture mode wrote to the client: %s", w.Body.String())
+	}
+	if result.Output["status_code"] != http.StatusCreated {
+		t.Errorf("status_code = %v, want 201", result.Output["status_code"])
+	}
+	headers, _ := result.Output["headers"].(map[string]any)
+	if headers["X-Delegate"] != "orders" {
+		t.Errorf("headers = %v", headers)
+	}
+	body, _ := result.Output["body"].(map[string]any)
+	if body["method"] != "POST" || body["tenant"] != "acme" || body["body"] != `{"qty":2,"sku":"b"}` {
+		t.Errorf("delegate received %v", body)
+	}
+}
+
+func TestDelegateStep_BodyTemplate(t *testing.T) {
+	step := newDelegateStep(t, map[string]any{
+		"capture": true,
+		"body":    map[string]any{"customer": "{{ .tenant }}", "source": "pipeline"},
+	})
+	pc, _ := liveDelegateContext()
+
+	result, err := step.Execute(context.Background(), pc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	body, _ := result.Output["body"].(map[string]any)
+	if body["body"] != `{"customer":"acme","source":"pipeline"}` {
+		t.Errorf("delegate received body %v", body["body"])
+	}
+}
+
+func TestDelegateStep_InvalidConfig(t *testing.T) {
+	for name, config := range map[string]map[string]any{
+		"capture not bool":   {"capture": "yes"},
+		"body and body_from": {"body": map[string]any{}, "body_from": "steps.x"},
+		"empty body_from":    {"body_from": ""},
+		"headers not a map":  {"headers": []any{"X-A"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			config["service"] = "orders-api"
+			if _, err := NewDelegateStepFactory()("delegate", config, nil); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package module
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/GoCodeAlone/workflow/interfaces"
+)
+
+// Dry-run request headers and query parameters, honored only on the admin
+// trigger route. A trigger that sets X-Workflow-Dry-Run: true (or
+// ?dry_run=true) runs its pipeline in dry-run mode. X-Workflow-Dry-Run-Of
+// (or ?dry_run_of=) names the dry-run execution that a real run was
+// confirmed from, so the two can be compared.
+const (
+	DryRunHeader       = "X-Workflow-Dry-Run"
+	DryRunOfHeader     = "X-Workflow-Dry-Run-Of"
+	dryRunQueryParam   = "dry_run"
+	dryRunOfQueryParam = "dry_run_of"
+)
+
+// dryRunContextKey is the unexported context key type for the dry-run flag.
+type dryRunContextKey struct{}
+
+// WithDryRun returns a context that runs pipelines in dry-run mode:
+// destructive steps report what they would do instead of executing.
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunContextKey{}, true)
+}
+
+// IsDryRun returns true when the context carries the dry-run flag.
+func IsDryRun(ctx context.Context) bool {
+	v, _ := ctx.Value(dryRunContextKey{}).(bool)
+	return v
+}
+
+// adminTriggerRequest reports whether r manually triggers a workflow through
+// POST /api/v1/admin/workflows/{id}/trigger. Dry runs are requested only
+// there: honoring the flag on any route would let every client of a public
+// route skip its pipeline's destructive steps.
+func adminTriggerRequest(r *http.Request) bool {
+	if r == nil || r.URL == nil || r.Method != http.MethodPost {
+		return false
+	}
+	id, ok := strings.CutPrefix(r.URL.Path, "/api/v1/admin/workflows/")
+	if !ok {
+		return false
+	}
+	id, ok = strings.CutSuffix(id, "/trigger")
+	return ok && id != "" && !strings.Contains(id, "/")
+}
+
+// DryRunRequested reports whether r is an admin trigger that asks for a dry
+// run through the X-Workflow-Dry-Run header or the dry_run query parameter.
+func DryRunRequested(r *http.Request) bool {
+	if !adminTriggerRequest(r) {
+		return false
+	}
+	if strings.EqualFold(r.Header.Get(DryRunHeader), "true") {
+		return true
+	}
+	return r.URL != nil && strings.EqualFold(r.URL.Query().Get(dryRunQueryParam), "true")
+}
+
+// dryRunOf returns the dry-run execution ID that the admin trigger r was
+// confirmed from, or "".
+func dryRunOf(r *http.Request) string {
+	if !adminTriggerRequest(r) {
+		return ""
+	}
+	if id := strings.TrimSpace(r.Header.Get(DryRunOfHeader)); id != "" {
+		return id
+	}
+	return strings.TrimSpace(r.URL.Query().Get(dryRunOfQueryParam))
+}
+
+// DryRunPreviewer is implemented by steps that can describe the side effect
+// they would perform without performing it. The preview is resolved against
+// the pipeline context exactly as Execute would resolve it.
+type DryRunPreviewer interface {
+	DryRunPreview(ctx context.Context, pc *PipelineContext) (map[string]any, error)
+}
+
+// DestructiveStep wraps a PipelineStep marked destructive: true. Normally it
+// delegates to the wrapped step. In dry-run mode it does not execute the step
+// and instead outputs {"dry_run": true, "would_execute": <preview>}, where the
+// redacted preview comes from DryRunPreviewer when the step implements it.
+type DestructiveStep struct {
+	inner interfaces.PipelineStep
+}
+
+// NewDestructiveStep wraps inner so that it is not executed in dry-run mode.
+func NewDestructiveStep(inner interfaces.PipelineStep) *DestructiveStep {
+	return &DestructiveStep{inner: inner}
+}
+
+// Name delegates to the wrapped step.
+func (s *DestructiveStep) Name() string {
+	return s.inner.Name()
+}
+
+// Execute runs the wrapped step, or previews it in dry-run mode.
+func (s *DestructiveStep) Execute(ctx context.Context, pc *PipelineContext) (*interfaces.StepResult, error) {
+	if !IsDryRun(ctx) {
+		return s.inner.Execute(ctx, pc)
+	}
+	preview := map[string]any{}
+	if p, ok := s.inner.(DryRunPreviewer); ok {
+		var err error
+		preview, err = p.DryRunPreview(ctx, pc)
+		if err != nil {
+			return nil, fmt.Errorf("step %q: dry run: %w", s.inner.Name(), err)
+		}
+	}
+	// Route pipelines return their context to the caller, so redact resolved
+	// secrets such as authorization headers before the preview is merged.
+	return &interfaces.StepResult{
+		DryRun: true,
+		Output: map[string]any{
+			"dry_run":       true,
+			"would_execute": RedactStepOutput(preview),
+		},
+	}, nil
+}
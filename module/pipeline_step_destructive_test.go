@@ -0,0 +1,147 @@
+package module
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDestructiveStep_ExecutesOutsideDryRun(t *testing.T) {
+	inner := newMockStep("purge", map[string]any{"affected_rows": 3})
+	result, err := NewDestructiveStep(inner).Execute(context.Background(), NewPipelineContext(nil, nil))
+	require.NoError(t, err)
+	require.Len(t, inner.execLog, 1)
+	require.False(t, result.DryRun)
+	require.Equal(t, 3, result.Output["affected_rows"])
+}
+
+func TestDestructiveStep_DryRunPreviewsPublish(t *testing.T) {
+	step, err := NewPublishStepFactory()("notify", map[string]any{
+		"topic":   "accounts.{{ .region }}",
+		"payload": map[string]any{"id": "{{ .id }}"},
+	}, nil)
+	require.NoError(t, err)
+
+	pc := NewPipelineContext(map[string]any{"region": "eu", "id": "42"}, nil)
+	result, err := NewDestructiveStep(step).Execute(WithDryRun(context.Background()), pc)
+	require.NoError(t, err)
+	require.True(t, result.DryRun)
+	require.False(t, result.Skipped)
+	require.Equal(t, true, result.Output["dry_run"])
+	require.Equal(t, map[string]any{
+		"topic":   "accounts.eu",
+		"payload": map[string]any{"id": "42"},
+	}, result.Output["would_execute"])
+}
+
+func TestDestructiveStep_DryRunPreviewsDBExec(t *testing.T) {
+	step, err := NewDBExecStepFactory()("purge", map[string]any{
+		"database": "db",
+		"query":    "DELETE FROM accounts WHERE last_seen < $1",
+		"params":   []any{"{{ .cutoff }}"},
+	}, nil)
+	require.NoError(t, err)
+
+	pc := NewPipelineContext(map[string]any{"cutoff": "2026-01-01"}, nil)
+	result, err := NewDestructiveStep(step).Execute(WithDryRun(context.Background()), pc)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"database": "db",
+		"query":    "DELETE FROM accounts WHERE last_seen < $1",
+		"params":   []any{"2026-01-01"},
+	}, result.Output["would_execute"])
+}
+
+func TestDestructiveStep_DryRunPreviewsHTTPCallRedacted(t *testing.T) {
+	step, err := NewHTTPCallStepFactory()("refund", map[string]any{
+		"url":     "https://payments.example.com/orders/{{ .id }}/refund",
+		"method":  "POST",
+		"headers": map[string]any{"Authorization": "Bearer {{ .key }}", "X-Request": "r-{{ .id }}"},
+		"body":    map[string]any{"amount": "{{ .amount }}"},
+	}, nil)
+	require.NoError(t, err)
+
+	pc := NewPipelineContext(map[string]any{"id": "7", "key": "sk_live", "amount": "10"}, nil)
+	result, err := NewDestructiveStep(step).Execute(WithDryRun(context.Background()), pc)
+	require.NoError(t, err)
+	preview := result.Output["would_execute"].(map[string]any)
+	require.Equal(t, "POST", preview["method"])
+	require.Equal(t, "https://payments.example.com/orders/7/refund", preview["url"])
+	require.Equal(t, map[string]any{"amount": "10"}, preview["body"])
+	headers := preview["headers"].(map[string]any)
+	require.Equal(t, RedactionPlaceholder, headers["Authorization"])
+	require.Equal(t, "r-7", headers["X-Request"])
+}
+
+func TestDryRunRequested(t *testing.T) {
+	const trigger = "/api/v1/admin/workflows/wf-1/trigger"
+	req := httptest.NewRequest("POST", trigger, nil)
+	require.False(t, DryRunRequested(req))
+	req.Header.Set(DryRunHeader, "true")
+	require.True(t, DryRunRequested(req))
+	require.True(t, DryRunRequested(httptest.NewRequest("POST", trigger+"?dry_run=true", nil)))
+	require.Equal(t, "exec-1", dryRunOf(httptest.NewRequest("POST", trigger+"?dry_run_of=exec-1", nil)))
+
+	// Any other route ignores the flag.
+	for _, target := range []string{"/purge?dry_run=true", "/api/v1/admin/workflows/wf-1/x/trigger?dry_run=true"} {
+		require.False(t, DryRunRequested(httptest.NewRequest("POST", target, nil)), target)
+	}
+	require.False(t, DryRunRequested(httptest.NewRequest("GET", trigger+"?dry_run=true", nil)))
+	require.Empty(t, dryRunOf(httptest.NewRequest("POST", "/purge?dry_run_of=exec-1", nil)))
+}
+
+func TestTrackPipelineExecution_DryRun(t *testing.T) {
+	store := setupTestStoreWithWorkflow(t, "test-wf")
+	tracker := &ExecutionTracker{Store: store, WorkflowID: "test-wf"}
+
+	req := httptest.NewRequest("POST", "/api/v1/admin/workflows/test-wf/trigger", nil)
+	req.Header.Set(DryRunHeader, "true")
+	lookup := newMockStep("lookup", map[string]any{"count": 2})
+	purge := newMockStep("purge", map[string]any{"affected_rows": 2})
+	pipeline := &Pipeline{
+		Name:  "purge-accounts",
+		Steps: []PipelineStep{lookup, NewDestructiveStep(purge)},
+	}
+
+	pc, err := tracker.TrackPipelineExecution(context.Background(), pipeline, nil, req)
+	require.NoError(t, err)
+	require.Len(t, lookup.execLog, 1, "non-destructive steps run normally")
+	require.Empty(t, purge.execLog, "destructive steps are not executed in a dry run")
+	require.Equal(t, true, pc.StepOutputs["purge"]["dry_run"])
+
+	var metadata string
+	require.NoError(t, store.DB().QueryRow(
+		"SELECT metadata FROM workflow_executions WHERE workflow_id = 'test-wf'",
+	).Scan(&metadata))
+	require.Contains(t, metadata, `"dry_run":true`)
+
+	var status, output string
+	require.NoError(t, store.DB().QueryRow(
+		"SELECT status, output_data FROM execution_steps WHERE step_name = 'purge'",
+	).Scan(&status, &output))
+	require.Equal(t, "dry_run", status)
+	require.Contains(t, output, `"would_execute"`)
+}
+
+func TestTrackPipelineExecution_DryRunOf(t *testing.T) {
+	store := setupTestStoreWithWorkflow(t, "test-wf")
+	tracker := &ExecutionTracker{Store: store, WorkflowID: "test-wf"}
+
+	req := httptest.NewRequest("POST", "/api/v1/admin/workflows/test-wf/trigger", nil)
+	req.Header.Set(DryRunOfHeader, "dry-exec-1")
+	purge := newMockStep("purge", map[string]any{"affected_rows": 2})
+	pipeline := &Pipeline{Name: "purge-accounts", Steps: []PipelineStep{NewDestructiveStep(purge)}}
+
+	_, err := tracker.TrackPipelineExecution(context.Background(), pipeline, nil, req)
+	require.NoError(t, err)
+	require.Len(t, purge.execLog, 1)
+
+	var metadata string
+	require.NoError(t, store.DB().QueryRow(
+		"SELECT metadata FROM workflow_executions WHERE workflow_id = 'test-wf'",
+	).Scan(&metadata))
+	require.Contains(t, metadata, `"dry_run_of":"dry-exec-1"`)
+	require.NotContains(t, metadata, `"dry_run":true`)
+}
@@ -0,0 +1,201 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/GoCodeAlone/modular"
+	evstore "github.com/GoCodeAlone/workflow/store"
+	"github.com/google/uuid"
+)
+
+// dlqPublishWriteTimeout bounds how long step.dlq_publish waits for the store.
+const dlqPublishWriteTimeout = 10 * time.Second
+
+// DLQPublishStep adds the current record to a dead letter queue with a
+// reason, so a pipeline can park input it rejects, such as a record failing
+// validation, for inspection and reprocessing through the DLQ admin API.
+//
+// The entry holds the pipeline context (or the configured payload), the
+// reason and error type, and the pipeline, step, execution and optional
+// source the record came from. It is written to any evstore.DLQStore, so
+// in-memory and persistent stores work alike.
+type DLQPublishStep struct {
+	name       string
+	dlqName    string
+	reason     string
+	errorType  string
+	source     string
+	payload    map[string]any
+	metadata   map[string]any
+	maxRetries int
+	app        modular.Application
+	tmpl       *TemplateEngine
+}
+
+// NewDLQPublishStepFactory returns a StepFactory that creates DLQPublishStep
+// instances.
+func NewDLQPublishStepFactory() StepFactory {
+	return func(name string, config map[string]any, app modular.Application) (PipelineStep, error) {
+		reason, _ := config["reason"].(string)
+		if reason == "" {
+			return nil, fmt.Errorf("dlq_publish step %q: 'reason' is required", name)
+		}
+
+		var payload, metadata map[string]any
+		for _, f := range []struct {
+			key string
+			dst *map[string]any
+		}{{"payload", &payload}, {"metadata", &metadata}} {
+			raw, ok := config[f.key]
+			if !ok {
+				continue
+			}
+			if *f.dst, ok = raw.(map[string]any); !ok {
+				return nil, fmt.Errorf("dlq_publish step %q: '%s' must be a map", name, f.key)
+			}
+		}
+
+		maxRetries := 3
+		switch v := config["max_retries"].(type) {
+		case nil:
+		case int:
+			maxRetries = v
+		case float64:
+			maxRetries = int(v)
+		default:
+			return nil, fmt.Errorf("dlq_publish step %q: 'max_retries' must be an integer", name)
+		}
+		if maxRetries < 0 {
+			return nil, fmt.Errorf("dlq_publish step %q: 'max_retries' must not be negative", name)
+		}
+
+		errorType, _ := config["error_type"].(string)
+		if errorType == "" {
+			errorType = "rejected"
+		}
+		dlqName, _ := config["dlq"].(string)
+		source, _ := config["source"].(string)
+
+		return &DLQPublishStep{
+			name:       name,
+			dlqName:    dlqName,
+			reason:     reason,
+			errorType:  errorType,
+			source:     source,
+			payload:    payload,
+			metadata:   metadata,
+			maxRetries: maxRetries,
+			app:        app,
+			tmpl:       NewTemplateEngine(),
+		}, nil
+	}
+}
+
+// Name returns the step name.
+func (s *DLQPublishStep) Name() string { return s.name }
+
+// Execute resolves the entry and adds it to the dead letter queue.
+func (s *DLQPublishStep) Execute(ctx context.Context, pc *PipelineContext) (*StepResult, error) {
+	store, err := s.resolveStore()
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &evstore.DLQEntry{
+		ID:         uuid.New(),
+		StepName:   s.name,
+		MaxRetries: s.maxRetries,
+		Status:     evstore.DLQStatusPending,
+		Metadata:   map[string]any{},
+	}
+	entry.PipelineName, _ = pc.Metadata["pipeline"].(string)
+	for _, f := range []struct {
+		field string
+		tmpl  string
+		dst   *string
+	}{
+		{"reason", s.reason, &entry.ErrorMessage},
+		{"error_type", s.errorType, &entry.ErrorType},
+	} {
+		v, err := s.tmpl.Resolve(f.tmpl, pc)
+		if err != nil {
+			return nil, fmt.Errorf("dlq_publish step %q: failed to resolve %s: %w", s.name, f.field, err)
+		}
+		*f.dst = v
+	}
+
+	data := pc.Current
+	if s.payload != nil {
+		if data, err = s.tmpl.ResolveMap(s.payload, pc); err != nil {
+			return nil, fmt.Errorf("dlq_publish step %q: failed to resolve payload: %w", s.name, err)
+		}
+	}
+	if entry.OriginalEvent, err = json.Marshal(data); err != nil {
+		return nil, fmt.Errorf("dlq_publish step %q: payload is not serializable: %w", s.name, err)
+	}
+
+	if s.metadata != nil {
+		resolved, err := s.tmpl.ResolveMap(s.metadata, pc)
+		if err != nil {
+			return nil, fmt.Errorf("dlq_publish step %q: failed to resolve metadata: %w", s.name, err)
+		}
+		entry.Metadata = resolved
+	}
+	if s.source != "" {
+		source, err := s.tmpl.Resolve(s.source, pc)
+		if err != nil {
+			return nil, fmt.Errorf("dlq_publish step %q: failed to resolve source: %w", s.name, err)
+		}
+		entry.Metadata["source"] = source
+	}
+	for _, key := range []string{"execution_id", "request_id"} {
+		if v, ok := pc.Metadata[key].(string); ok && v != "" {
+			entry.Metadata[key] = v
+		}
+	}
+
+	// Detach from pipeline cancellation so a record being rejected by a
+	// cancelled pipeline is still queued.
+	writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), dlqPublishWriteTimeout)
+	defer cancel()
+	if err := store.Add(writeCtx, entry); err != nil {
+		return nil, fmt.Errorf("dlq_publish step %q: failed to add entry: %w", s.name, err)
+	}
+
+	return &StepResult{Output: map[string]any{
+		"queued":     true,
+		"dlq_id":     entry.ID.String(),
+		"reason":     entry.ErrorMessage,
+		"error_type": entry.ErrorType,
+	}}, nil
+}
+
+// resolveStore returns the store of the configured dlq.service module, or a
+// DLQ store service of that name. Without 'dlq' it uses the only DLQ store
+// in the application.
+func (s *DLQPublishStep) resolveStore() (evstore.DLQStore, error) {
+	if s.app == nil {
+		return nil, fmt.Errorf("dlq_publish step %q: no application context", s.name)
+	}
+	registry := s.app.SvcRegistry()
+	if s.dlqName != "" {
+		for _, name := range []string{s.dlqName + DLQStoreServiceSuffix, s.dlqName} {
+			if store, ok := registry[name].(evstore.DLQStore); ok {
+				return store, nil
+			}
+		}
+		return nil, fmt.Errorf("dlq_publish step %q: dlq %q is not a dlq.service module or DLQ store", s.name, s.dlqName)
+	}
+	match, err := FindByInterface[evstore.DLQStore](registry)
+	if errors.Is(err, ErrServiceNotFound) {
+		return nil, fmt.Errorf("dlq_publish step %q: no dlq.service registered", s.name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("dlq_publish step %q: set 'dlq' to choose a dead letter queue: %w", s.name, err)
+	}
+	return match.Service, nil
+}
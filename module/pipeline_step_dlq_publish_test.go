@@ -0,0 +1,133 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	evstore "github.com/GoCodeAlone/workflow/store"
+)
+
+func TestDLQPublishStep_QueuesRejectedRecord(t *testing.T) {
+	sqliteStore, err := evstore.NewSQLiteDLQStore(filepath.Join(t.TempDir(), "dlq.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteDLQStore: %v", err)
+	}
+	t.Cleanup(func() { sqliteStore.Close() })
+
+	for _, tc := range []struct {
+		name  string
+		store evstore.DLQStore
+		dlq   string
+	}{
+		{name: "in-memory dlq.service", dlq: "rejects"},
+		{name: "persistent store service", store: sqliteStore, dlq: "durable-dlq"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			app := NewMockApplication()
+			store := tc.store
+			if store == nil {
+				dlq := NewDLQServiceModule(tc.dlq, DLQServiceConfig{})
+				for _, svc := range dlq.ProvidesServices() {
+					app.Services[svc.Name] = svc.Instance
+				}
+				store = dlq.Store()
+			} else {
+				app.Services[tc.dlq] = store
+			}
+
+			step, err := NewDLQPublishStepFactory()("reject", map[string]any{
+				"dlq":        tc.dlq,
+				"reason":     "invalid amount {{ .amount }}",
+				"error_type": "validation",
+				"source":     "orders-topic:{{ .id }}",
+				"payload":    map[string]any{"id": "{{ .id }}", "amount": "{{ .amount }}"},
+				"metadata":   map[string]any{"field": "amount"},
+			}, app)
+			if err != nil {
+				t.Fatalf("factory error: %v", err)
+			}
+
+			pc := NewPipelineContext(map[string]any{"id": "o-1", "amount": -5, "card": "4111"}, map[string]any{
+				"pipeline":     "ingest-orders",
+				"execution_id": "exec-1",
+			})
+			result, err := step.Execute(context.Background(), pc)
+			if err != nil {
+				t.Fatalf("execute error: %v", err)
+			}
+			if result.Output["queued"] != true || result.Output["error_type"] != "validation" {
+				t.Errorf("unexpected output: %v", result.Output)
+			}
+
+			entries, err := store.List(context.Background(), evstore.DLQFilter{PipelineName: "ingest-orders"})
+			if err != nil || len(entries) != 1 {
+				t.Fatalf("List = %v, %v; want one entry", entries, err)
+			}
+			e := entries[0]
+			if e.ID.String() != result.Output["dlq_id"] || e.StepName != "reject" || e.Status != evstore.DLQStatusPending {
+				t.Errorf("unexpected entry: %+v", e)
+			}
+			if e.ErrorMessage != "invalid amount -5" || e.ErrorType != "validation" || e.MaxRetries != 3 {
+				t.Errorf("unexpected reason: %+v", e)
+			}
+			var payload map[string]any
+			if err := json.Unmarshal(e.OriginalEvent, &payload); err != nil || len(payload) != 2 || payload["id"] != "o-1" {
+				t.Errorf("expected projected payload, got %s", e.OriginalEvent)
+			}
+			if e.Metadata["source"] != "orders-topic:o-1" || e.Metadata["execution_id"] != "exec-1" || e.Metadata["field"] != "amount" {
+				t.Errorf("unexpected metadata: %v", e.Metadata)
+			}
+		})
+	}
+}
+
+func TestDLQPublishStep_DefaultsToCurrentContextAndOnlyDLQ(t *testing.T) {
+	app := NewMockApplication()
+	dlq := NewDLQServiceModule("dlq", DLQServiceConfig{})
+	for _, svc := range dlq.ProvidesServices() {
+		app.Services[svc.Name] = svc.Instance
+	}
+
+	step, err := NewDLQPublishStepFactory()("reject", map[string]any{"reason": "blocked"}, app)
+	if err != nil {
+		t.Fatalf("factory error: %v", err)
+	}
+	pc := NewPipelineContext(map[string]any{"id": "o-2"}, nil)
+	if _, err := step.Execute(context.Background(), pc); err != nil {
+		t.Fatalf("execute error: %v", err)
+	}
+
+	entries, _ := dlq.Store().List(context.Background(), evstore.DLQFilter{})
+	if len(entries) != 1 || entries[0].ErrorType != "rejected" || string(entries[0].OriginalEvent) != `{"id":"o-2"}` {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+}
+
+func TestDLQPublishStep_Errors(t *testing.T) {
+	factory := NewDLQPublishStepFactory()
+	for _, tc := range []struct {
+		config map[string]any
+		want   string
+	}{
+		{map[string]any{}, "'reason' is required"},
+		{map[string]any{"reason": "x", "payload": "nope"}, "'payload' must be a map"},
+		{map[string]any{"reason": "x", "max_retries": -1}, "must not be negative"},
+	} {
+		if _, err := factory("s", tc.config, nil); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("config %v: error %v, want %q", tc.config, err, tc.want)
+		}
+	}
+
+	app := NewMockApplication()
+	step, _ := factory("s", map[string]any{"reason": "x"}, app)
+	if _, err := step.Execute(context.Background(), NewPipelineContext(nil, nil)); err == nil || !strings.Contains(err.Error(), "no dlq.service registered") {
+		t.Errorf("expected missing DLQ error, got %v", err)
+	}
+	step, _ = factory("s", map[string]any{"reason": "x", "dlq": "missing"}, app)
+	if _, err := step.Execute(context.Background(), NewPipelineContext(nil, nil)); err == nil || !strings.Contains(err.Error(), `dlq "missing"`) {
+		t.Errorf("expected unknown DLQ error, got %v", err)
+	}
+}
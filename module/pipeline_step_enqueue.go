@@ -0,0 +1,194 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GoCodeAlone/modular"
+)
+
+// EnqueueStep defers work to a jobqueue.service: it stores the target
+// pipeline name and trigger data on the queue and returns immediately, leaving
+// the queue's worker pool to run the pipeline in the background.
+type EnqueueStep struct {
+	name      string
+	queueName string
+	pipeline  string
+	data      map[string]any
+	delay     string
+	priority  any
+	app       modular.Application
+	tmpl      *TemplateEngine
+}
+
+// NewEnqueueStepFactory returns a StepFactory that creates EnqueueStep instances.
+func NewEnqueueStepFactory() StepFactory {
+	return func(name string, config map[string]any, app modular.Application) (PipelineStep, error) {
+		pipeline, _ := config["pipeline"].(string)
+		if pipeline == "" {
+			return nil, fmt.Errorf("enqueue step %q: 'pipeline' is required", name)
+		}
+
+		var data map[string]any
+		if raw, ok := config["data"]; ok {
+			data, ok = raw.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("enqueue step %q: 'data' must be a map", name)
+			}
+		}
+
+		delay, _ := config["delay"].(string)
+		if delay != "" && !strings.Contains(delay, "{{") {
+			if _, err := time.ParseDuration(delay); err != nil {
+				return nil, fmt.Errorf("enqueue step %q: invalid delay %q: %w", name, delay, err)
+			}
+		}
+
+		priority := config["priority"]
+		switch priority.(type) {
+		case nil, int, float64, string:
+		default:
+			return nil, fmt.Errorf("enqueue step %q: 'priority' must be an integer or template string", name)
+		}
+
+		queueName, _ := config["queue"].(string)
+
+		return &EnqueueStep{
+			name:      name,
+			queueName: queueName,
+			pipeline:  pipeline,
+			data:      data,
+			delay:     delay,
+			priority:  priority,
+			app:       app,
+			tmpl:      NewTemplateEngine(),
+		}, nil
+	}
+}
+
+// Name returns the step name.
+func (s *EnqueueStep) Name() string { return s.name }
+
+// Execute resolves the job and adds it to the queue.
+func (s *EnqueueStep) Execute(ctx context.Context, pc *PipelineContext) (*StepResult, error) {
+	queue, err := s.resolveQueue()
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline, err := s.tmpl.Resolve(s.pipeline, pc)
+	if err != nil {
+		return nil, fmt.Errorf("enqueue step %q: failed to resolve pipeline: %w", s.name, err)
+	}
+
+	var data map[string]any
+	if s.data != nil {
+		if data, err = s.tmpl.ResolveMap(s.data, pc); err != nil {
+			return nil, fmt.Errorf("enqueue step %q: failed to resolve data: %w", s.name, err)
+		}
+	} else {
+		data = pc.Current
+	}
+	// Round-trip through JSON so the job carries a detached, serializable
+	// snapshot and the target pipeline sees the same types on every backend.
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("enqueue step %q: job data is not serializable: %w", s.name, err)
+	}
+	data = nil
+	if err := json.Unmarshal(encoded, &data); err != nil {
+		return nil, fmt.Errorf("enqueue step %q: job data is not serializable: %w", s.name, err)
+	}
+
+	var delay time.Duration
+	if s.delay != "" {
+		resolved, err := s.tmpl.Resolve(s.delay, pc)
+		if err != nil {
+			return nil, fmt.Errorf("enqueue step %q: failed to resolve delay: %w", s.name, err)
+		}
+		if delay, err = time.ParseDuration(resolved); err != nil {
+			return nil, fmt.Errorf("enqueue step %q: invalid delay %q: %w", s.name, resolved, err)
+		}
+	}
+
+	priority, err := s.resolvePriority(pc)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := queue.Enqueue(ctx, pipeline, data, priority, delay)
+	if err != nil {
+		return nil, fmt.Errorf("enqueue step %q: %w", s.name, err)
+	}
+
+	output := map[string]any{
+		"enqueued": true,
+		"job_id":   job.ID,
+		"status":   job.Status,
+		"pipeline": job.Pipeline,
+		"priority": job.Priority,
+		"run_at":   job.RunAt.Format(time.RFC3339Nano),
+	}
+	if path := queue.StatusPath(); path != "" {
+		output["status_path"] = path + "/" + job.ID
+	}
+	return &StepResult{Output: output}, nil
+}
+
+func (s *EnqueueStep) resolvePriority(pc *PipelineContext) (int, error) {
+	switch v := s.priority.(type) {
+	case int:
+		return v, nil
+	case float64:
+		return int(v), nil
+	case string:
+		resolved, err := s.tmpl.Resolve(v, pc)
+		if err != nil {
+			return 0, fmt.Errorf("enqueue step %q: failed to resolve priority: %w", s.name, err)
+		}
+		p, err := strconv.Atoi(strings.TrimSpace(resolved))
+		if err != nil {
+			return 0, fmt.Errorf("enqueue step %q: priority %q is not an integer", s.name, resolved)
+		}
+		return p, nil
+	}
+	return 0, nil
+}
+
+// resolveQueue returns the configured queue, or the only jobqueue.service in
+// the application when none is configured.
+func (s *EnqueueStep) resolveQueue() (*JobQueueService, error) {
+	if s.app == nil {
+		return nil, fmt.Errorf("enqueue step %q: no application context", s.name)
+	}
+	if s.queueName != "" {
+		svc, ok := s.app.SvcRegistry()[s.queueName]
+		if !ok {
+			return nil, fmt.Errorf("enqueue step %q: queue %q not found", s.name, s.queueName)
+		}
+		q, ok := svc.(*JobQueueService)
+		if !ok {
+			return nil, fmt.Errorf("enqueue step %q: service %q is not a jobqueue.service", s.name, s.queueName)
+		}
+		return q, nil
+	}
+	var found *JobQueueService
+	for _, svc := range s.app.SvcRegistry() {
+		q, ok := svc.(*JobQueueService)
+		if !ok || q == found {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("enqueue step %q: multiple job queues registered; set 'queue'", s.name)
+		}
+		found = q
+	}
+	if found == nil {
+		return nil, fmt.Errorf("enqueue step %q: no jobqueue.service registered", s.name)
+	}
+	return found, nil
+}
@@ -0,0 +1,123 @@
+package module
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestJobQueueApp(t *testing.T, names ...string) (*MockApplication, map[string]*JobQueueService) {
+	t.Helper()
+	app := NewMockApplication()
+	queues := make(map[string]*JobQueueService, len(names))
+	for _, name := range names {
+		svc := NewJobQueueService(name, JobQueueConfig{Backend: "memory"})
+		svc.SetQueue(NewInMemoryJobQueue())
+		app.Services[name] = svc
+		queues[name] = svc
+	}
+	return app, queues
+}
+
+func TestEnqueueStep_ProjectionDelayPriority(t *testing.T) {
+	app, queues := newTestJobQueueApp(t, "jobs")
+
+	step, err := NewEnqueueStepFactory()("defer-report", map[string]any{
+		"pipeline": "generate-{{ .kind }}",
+		"data":     map[string]any{"report_id": "{{ .id }}"},
+		"delay":    "1m",
+		"priority": "{{ .prio }}",
+	}, app)
+	if err != nil {
+		t.Fatalf("factory error: %v", err)
+	}
+
+	pc := NewPipelineContext(map[string]any{"kind": "report", "id": "r-1", "prio": "7", "secret": "x"}, nil)
+	result, err := step.Execute(context.Background(), pc)
+	if err != nil {
+		t.Fatalf("execute error: %v", err)
+	}
+	if result.Output["enqueued"] != true || result.Output["pipeline"] != "generate-report" || result.Output["priority"] != 7 {
+		t.Errorf("unexpected output: %v", result.Output)
+	}
+	if result.Output["status"] != JobStatusPending {
+		t.Errorf("status = %v, want pending", result.Output["status"])
+	}
+	if _, ok := result.Output["status_path"]; ok {
+		t.Errorf("status_path set without a queue statusPath: %v", result.Output["status_path"])
+	}
+
+	q := queues["jobs"].Queue()
+	if job, _ := q.Claim(context.Background(), time.Now()); job != nil {
+		t.Fatalf("delayed job claimed early: %+v", job)
+	}
+	job, _ := q.Claim(context.Background(), time.Now().Add(2*time.Minute))
+	if job == nil {
+		t.Fatal("expected job after delay")
+	}
+	if job.ID != result.Output["job_id"] || job.Priority != 7 {
+		t.Errorf("unexpected job: %+v", job)
+	}
+	if len(job.Data) != 1 || job.Data["report_id"] != "r-1" {
+		t.Errorf("expected projected data only, got %v", job.Data)
+	}
+}
+
+func TestEnqueueStep_DefaultsToCurrentContext(t *testing.T) {
+	app, queues := newTestJobQueueApp(t, "jobs")
+
+	step, err := NewEnqueueStepFactory()("send", map[string]any{"pipeline": "send-email"}, app)
+	if err != nil {
+		t.Fatalf("factory error: %v", err)
+	}
+	pc := NewPipelineContext(map[string]any{"to": "a@example.com", "count": 2}, nil)
+	if _, err := step.Execute(context.Background(), pc); err != nil {
+		t.Fatalf("execute error: %v", err)
+	}
+	pc.Current["to"] = "changed@example.com"
+
+	job, _ := queues["jobs"].Queue().Claim(context.Background(), time.Now())
+	if job == nil {
+		t.Fatal("expected job")
+	}
+	// Data is a JSON snapshot: detached from the context, numbers as float64.
+	if job.Data["to"] != "a@example.com" || job.Data["count"] != float64(2) {
+		t.Errorf("unexpected job data: %v", job.Data)
+	}
+}
+
+func TestEnqueueStep_Errors(t *testing.T) {
+	factory := NewEnqueueStepFactory()
+	for _, cfg := range []map[string]any{
+		{},
+		{"pipeline": "p", "delay": "soon"},
+		{"pipeline": "p", "data": "x"},
+		{"pipeline": "p", "priority": true},
+	} {
+		if _, err := factory("e", cfg, nil); err == nil {
+			t.Errorf("expected factory error for %v", cfg)
+		}
+	}
+
+	single, _ := newTestJobQueueApp(t)
+	multi, _ := newTestJobQueueApp(t, "a", "b")
+	for _, tt := range []struct {
+		app   *MockApplication
+		queue string
+		want  string
+	}{
+		{single, "", "no jobqueue.service registered"},
+		{multi, "", "multiple job queues"},
+		{multi, "missing", "not found"},
+	} {
+		step, err := factory("e", map[string]any{"pipeline": "p", "queue": tt.queue}, tt.app)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = step.Execute(context.Background(), NewPipelineContext(nil, nil))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("queue %q: error = %v, want containing %q", tt.queue, err, tt.want)
+		}
+	}
+}
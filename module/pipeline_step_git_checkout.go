@@ -1,47 +1,56 @@
 package module
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
-	"os/exec"
-	"strings"
 
 	"github.com/GoCodeAlone/modular"
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
 )
 
-// GitCheckoutStep checks out a branch, tag, or creates a new branch.
+// GitCheckoutStep switches a local repository to a branch, tag or commit, or
+// creates a new branch. Refs missing from the local clone (for example after
+// a shallow single-branch clone) are fetched from origin first.
 type GitCheckoutStep struct {
-	name        string
-	directory   string
-	branch      string
-	create      bool
-	tmpl        *TemplateEngine
-	execCommand func(ctx context.Context, name string, args ...string) *exec.Cmd
+	name      string
+	directory string
+	branch    string
+	create    bool
+	auth      gitAuthConfig
+	tmpl      *TemplateEngine
 }
 
 // NewGitCheckoutStepFactory returns a StepFactory that creates GitCheckoutStep instances.
 func NewGitCheckoutStepFactory() StepFactory {
 	return func(name string, config map[string]any, _ modular.Application) (PipelineStep, error) {
 		directory, _ := config["directory"].(string)
+		if directory == "" {
+			directory, _ = config["path"].(string)
+		}
 		if directory == "" {
 			return nil, fmt.Errorf("git_checkout step %q: 'directory' is required", name)
 		}
 
 		branch, _ := config["branch"].(string)
 		if branch == "" {
-			return nil, fmt.Errorf("git_checkout step %q: 'branch' is required", name)
+			branch, _ = config["ref"].(string)
+		}
+		if branch == "" {
+			return nil, fmt.Errorf("git_checkout step %q: 'branch' (or 'ref') is required", name)
 		}
 
 		create, _ := config["create"].(bool)
 
 		return &GitCheckoutStep{
-			name:        name,
-			directory:   directory,
-			branch:      branch,
-			create:      create,
-			tmpl:        NewTemplateEngine(),
-			execCommand: exec.CommandContext,
+			name:      name,
+			directory: directory,
+			branch:    branch,
+			create:    create,
+			auth:      parseGitAuthConfig(config),
+			tmpl:      NewTemplateEngine(),
 		}, nil
 	}
 }
@@ -49,58 +58,108 @@ func NewGitCheckoutStepFactory() StepFactory {
 // Name returns the step name.
 func (s *GitCheckoutStep) Name() string { return s.name }
 
-// Execute checks out the configured branch or creates it.
+// Execute checks out the configured ref or creates the configured branch.
 func (s *GitCheckoutStep) Execute(ctx context.Context, pc *PipelineContext) (*StepResult, error) {
 	directory, err := s.tmpl.Resolve(s.directory, pc)
 	if err != nil {
 		return nil, fmt.Errorf("git_checkout step %q: failed to resolve directory: %w", s.name, err)
 	}
 
-	branch, err := s.tmpl.Resolve(s.branch, pc)
+	ref, err := s.tmpl.Resolve(s.branch, pc)
 	if err != nil {
 		return nil, fmt.Errorf("git_checkout step %q: failed to resolve branch: %w", s.name, err)
 	}
 
-	// Build checkout args.
-	args := []string{"-C", directory, "checkout"}
-	if s.create {
-		args = append(args, "-b")
+	repo, err := git.PlainOpen(directory)
+	if err != nil {
+		return nil, fmt.Errorf("git_checkout step %q: failed to open repository %s: %w", s.name, directory, err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("git_checkout step %q: %w", s.name, err)
 	}
-	args = append(args, branch)
-
-	var stdout, stderr bytes.Buffer
-	cmd := s.execCommand(ctx, "git", args...) //nolint:gosec // G204: args from trusted pipeline config
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
 
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("git_checkout step %q: git checkout failed: %w\nstdout: %s\nstderr: %s",
-			s.name, err, stdout.String(), stderr.String())
+	opts, err := s.checkoutOptions(repo, ref)
+	if errors.Is(err, errGitRefNotFound) && !s.create {
+		if err = s.fetch(ctx, repo, pc); err == nil {
+			opts, err = s.checkoutOptions(repo, ref)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("git_checkout step %q: %w", s.name, err)
+	}
+	if err := wt.Checkout(opts); err != nil {
+		return nil, fmt.Errorf("git_checkout step %q: git checkout %s failed: %w", s.name, ref, err)
 	}
 
-	// Get commit SHA after checkout.
-	commitSHA, err := s.getCommitSHA(ctx, directory)
+	head, err := repo.Head()
 	if err != nil {
-		commitSHA = ""
+		return nil, fmt.Errorf("git_checkout step %q: failed to read HEAD: %w", s.name, err)
+	}
+	branch := ""
+	if head.Name().IsBranch() {
+		branch = head.Name().Short()
 	}
 
 	return &StepResult{
 		Output: map[string]any{
 			"branch":     branch,
-			"commit_sha": commitSHA,
+			"ref":        ref,
+			"commit_sha": head.Hash().String(),
+			"detached":   !head.Name().IsBranch(),
 			"created":    s.create,
 			"success":    true,
 		},
 	}, nil
 }
 
-// getCommitSHA returns the HEAD commit SHA for the given directory.
-func (s *GitCheckoutStep) getCommitSHA(ctx context.Context, dir string) (string, error) {
-	var stdout bytes.Buffer
-	cmd := s.execCommand(ctx, "git", "-C", dir, "rev-parse", "HEAD") //nolint:gosec // G204: args from trusted pipeline config
-	cmd.Stdout = &stdout
-	if err := cmd.Run(); err != nil {
-		return "", err
+// checkoutOptions maps ref to checkout options, trying in order a local
+// branch, a remote-tracking branch of origin (creating the local branch), a
+// tag and finally any revision such as a commit SHA (detached HEAD).
+func (s *GitCheckoutStep) checkoutOptions(repo *git.Repository, ref string) (*git.CheckoutOptions, error) {
+	if s.create {
+		return &git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(ref), Create: true}, nil
+	}
+	local := plumbing.NewBranchReferenceName(ref)
+	if _, err := repo.Reference(local, false); err == nil {
+		return &git.CheckoutOptions{Branch: local}, nil
+	}
+	if r, err := repo.Reference(plumbing.NewRemoteReferenceName("origin", ref), true); err == nil {
+		return &git.CheckoutOptions{Branch: local, Hash: r.Hash(), Create: true}, nil
+	}
+	if r, err := repo.Reference(plumbing.NewTagReferenceName(ref), true); err == nil {
+		hash := r.Hash()
+		// Annotated tags point at a tag object; check out its commit.
+		if tag, err := repo.TagObject(hash); err == nil {
+			hash = tag.Target
+		}
+		return &git.CheckoutOptions{Hash: hash}, nil
+	}
+	if hash, err := repo.ResolveRevision(plumbing.Revision(ref)); err == nil {
+		return &git.CheckoutOptions{Hash: *hash}, nil
+	}
+	return nil, fmt.Errorf("%w: %q is not a branch, tag or commit", errGitRefNotFound, ref)
+}
+
+// fetch updates all branches and tags from origin. A repository without an
+// origin has nothing to fetch.
+func (s *GitCheckoutStep) fetch(ctx context.Context, repo *git.Repository, pc *PipelineContext) error {
+	remote, err := repo.Remote("origin")
+	if err != nil || len(remote.Config().URLs) == 0 {
+		return nil
+	}
+	auth, err := s.auth.resolve(s.tmpl, pc, remote.Config().URLs[0])
+	if err != nil {
+		return err
+	}
+	err = repo.FetchContext(ctx, &git.FetchOptions{
+		RemoteName: "origin",
+		RefSpecs:   []gitconfig.RefSpec{"+refs/heads/*:refs/remotes/origin/*"},
+		Tags:       git.AllTags,
+		Auth:       auth,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("fetch from origin failed: %w", err)
 	}
-	return strings.TrimSpace(stdout.String()), nil
+	return nil
 }
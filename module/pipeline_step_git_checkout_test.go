@@ -2,7 +2,7 @@ package module
 
 import (
 	"context"
-	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -59,164 +59,78 @@ func TestGitCheckoutStep_FactoryWithCreate(t *testing.T) {
 	}
 }
 
-func TestGitCheckoutStep_Execute_CheckoutExistingBranch(t *testing.T) {
-	var checkoutArgs []string
-	callCount := 0
-
-	s := &GitCheckoutStep{
-		name:      "checkout",
-		directory: "/tmp/repo",
-		branch:    "main",
-		create:    false,
-		tmpl:      NewTemplateEngine(),
-		execCommand: func(_ context.Context, name string, args ...string) *exec.Cmd {
-			callCount++
-			if callCount == 1 {
-				checkoutArgs = append([]string{name}, args...)
-				return exec.Command("true") // git checkout
-			}
-			return exec.Command("echo", "abc123") // git rev-parse HEAD
-		},
-	}
-
-	result, err := s.Execute(context.Background(), &PipelineContext{
-		Current:     map[string]any{},
-		StepOutputs: map[string]map[string]any{},
-		TriggerData: map[string]any{},
-		Metadata:    map[string]any{},
-	})
+func checkoutForTest(t *testing.T, config map[string]any) (*StepResult, error) {
+	t.Helper()
+	step, err := NewGitCheckoutStepFactory()("checkout", config, nil)
 	if err != nil {
-		t.Fatalf("Execute failed: %v", err)
-	}
-
-	if result.Output["branch"] != "main" {
-		t.Errorf("expected branch=main, got %v", result.Output["branch"])
-	}
-	if result.Output["success"] != true {
-		t.Errorf("expected success=true, got %v", result.Output["success"])
-	}
-	if result.Output["created"] != false {
-		t.Errorf("expected created=false, got %v", result.Output["created"])
+		t.Fatalf("unexpected factory error: %v", err)
 	}
+	return step.Execute(context.Background(), NewPipelineContext(map[string]any{"branch": "develop"}, nil))
+}
 
-	argsStr := strings.Join(checkoutArgs, " ")
-	if !strings.Contains(argsStr, "checkout") {
-		t.Errorf("expected checkout in args, got: %v", checkoutArgs)
-	}
-	if strings.Contains(argsStr, "-b") {
-		t.Errorf("expected no -b flag for existing branch checkout, got: %v", checkoutArgs)
+func TestGitCheckoutStep_Execute_Refs(t *testing.T) {
+	remote := newTestGitRemote(t)
+
+	tests := []struct {
+		ref          string
+		wantCommit   string
+		wantBranch   string
+		wantDetached bool
+	}{
+		{"{{ .branch }}", remote.developHead, "develop", false},
+		{"main", remote.mainHead, "main", false},
+		{"v1.0.0", remote.first, "", true},
+		{remote.first[:8], remote.first, "", true},
+	}
+	for _, tt := range tests {
+		result, err := checkoutForTest(t, map[string]any{"directory": remote.dir, "branch": tt.ref})
+		if err != nil {
+			t.Fatalf("ref %q: %v", tt.ref, err)
+		}
+		if result.Output["commit_sha"] != tt.wantCommit || result.Output["branch"] != tt.wantBranch || result.Output["detached"] != tt.wantDetached {
+			t.Errorf("ref %q: output = %v", tt.ref, result.Output)
+		}
 	}
 }
 
 func TestGitCheckoutStep_Execute_CreateNewBranch(t *testing.T) {
-	var checkoutArgs []string
-	callCount := 0
-
-	s := &GitCheckoutStep{
-		name:      "checkout",
-		directory: "/tmp/repo",
-		branch:    "feature/new",
-		create:    true,
-		tmpl:      NewTemplateEngine(),
-		execCommand: func(_ context.Context, name string, args ...string) *exec.Cmd {
-			callCount++
-			if callCount == 1 {
-				checkoutArgs = append([]string{name}, args...)
-				return exec.Command("true") // git checkout -b
-			}
-			return exec.Command("echo", "abc123") // git rev-parse HEAD
-		},
-	}
-
-	result, err := s.Execute(context.Background(), &PipelineContext{
-		Current:     map[string]any{},
-		StepOutputs: map[string]map[string]any{},
-		TriggerData: map[string]any{},
-		Metadata:    map[string]any{},
-	})
+	remote := newTestGitRemote(t)
+	result, err := checkoutForTest(t, map[string]any{"directory": remote.dir, "branch": "feature/new", "create": true})
 	if err != nil {
-		t.Fatalf("Execute failed: %v", err)
-	}
-
-	if result.Output["created"] != true {
-		t.Errorf("expected created=true, got %v", result.Output["created"])
+		t.Fatal(err)
 	}
-
-	argsStr := strings.Join(checkoutArgs, " ")
-	if !strings.Contains(argsStr, "-b") {
-		t.Errorf("expected -b flag for new branch, got: %v", checkoutArgs)
-	}
-	if !strings.Contains(argsStr, "feature/new") {
-		t.Errorf("expected branch name in args, got: %v", checkoutArgs)
+	if result.Output["branch"] != "feature/new" || result.Output["created"] != true || result.Output["commit_sha"] != remote.mainHead {
+		t.Errorf("output = %v", result.Output)
 	}
 }
 
-func TestGitCheckoutStep_Execute_CheckoutFailure(t *testing.T) {
-	s := &GitCheckoutStep{
-		name:      "checkout",
-		directory: "/tmp/repo",
-		branch:    "nonexistent",
-		tmpl:      NewTemplateEngine(),
-		execCommand: func(_ context.Context, _ string, _ ...string) *exec.Cmd {
-			return exec.Command("false")
-		},
+// A single-branch clone has no develop branch or tags locally; checkout
+// fetches them from origin.
+func TestGitCheckoutStep_Execute_FetchesMissingRefs(t *testing.T) {
+	remote := newTestGitRemote(t)
+	dir := filepath.Join(t.TempDir(), "src")
+	if _, err := cloneForTest(t, map[string]any{"repository": remote.dir, "directory": dir, "ref": "main"}); err != nil {
+		t.Fatal(err)
 	}
 
-	_, err := s.Execute(context.Background(), &PipelineContext{
-		Current:     map[string]any{},
-		StepOutputs: map[string]map[string]any{},
-		TriggerData: map[string]any{},
-		Metadata:    map[string]any{},
-	})
-	if err == nil {
-		t.Fatal("expected error when git checkout fails")
+	result, err := checkoutForTest(t, map[string]any{"path": dir, "ref": "develop"})
+	if err != nil {
+		t.Fatal(err)
 	}
-	if !strings.Contains(err.Error(), "git checkout") {
-		t.Errorf("expected error to mention git checkout, got: %v", err)
+	if result.Output["branch"] != "develop" || result.Output["commit_sha"] != remote.developHead {
+		t.Errorf("output = %v", result.Output)
 	}
 }
 
-func TestGitCheckoutStep_Execute_TemplateResolution(t *testing.T) {
-	var checkoutArgs []string
-	callCount := 0
-
-	s := &GitCheckoutStep{
-		name:      "checkout",
-		directory: "/tmp/workspace/{{ .repo }}",
-		branch:    "feature/{{ .feature_name }}",
-		create:    true,
-		tmpl:      NewTemplateEngine(),
-		execCommand: func(_ context.Context, name string, args ...string) *exec.Cmd {
-			callCount++
-			if callCount == 1 {
-				checkoutArgs = append([]string{name}, args...)
-				return exec.Command("true")
-			}
-			return exec.Command("echo", "abc123")
-		},
-	}
-
-	pc := &PipelineContext{
-		Current:     map[string]any{"repo": "my-repo", "feature_name": "add-logging"},
-		StepOutputs: map[string]map[string]any{},
-		TriggerData: map[string]any{"repo": "my-repo", "feature_name": "add-logging"},
-		Metadata:    map[string]any{},
-	}
-
-	result, err := s.Execute(context.Background(), pc)
-	if err != nil {
-		t.Fatalf("Execute failed: %v", err)
-	}
-
-	if result.Output["branch"] != "feature/add-logging" {
-		t.Errorf("expected branch=feature/add-logging, got %v", result.Output["branch"])
+func TestGitCheckoutStep_Execute_CheckoutFailure(t *testing.T) {
+	remote := newTestGitRemote(t)
+	_, err := checkoutForTest(t, map[string]any{"directory": remote.dir, "branch": "no-such-branch"})
+	if err == nil || !strings.Contains(err.Error(), "not a branch, tag or commit") {
+		t.Fatalf("expected unknown ref error, got: %v", err)
 	}
 
-	argsStr := strings.Join(checkoutArgs, " ")
-	if !strings.Contains(argsStr, "/tmp/workspace/my-repo") {
-		t.Errorf("expected resolved directory in args, got: %v", checkoutArgs)
-	}
-	if !strings.Contains(argsStr, "feature/add-logging") {
-		t.Errorf("expected resolved branch in args, got: %v", checkoutArgs)
+	_, err = checkoutForTest(t, map[string]any{"directory": t.TempDir(), "branch": "main"})
+	if err == nil || !strings.Contains(err.Error(), "failed to open repository") {
+		t.Errorf("expected open error for a non-repository, got: %v", err)
 	}
 }
@@ -1,61 +1,104 @@
 package module
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os"
-	"os/exec"
+	"regexp"
 	"strings"
 
 	"github.com/GoCodeAlone/modular"
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
 )
 
-// GitCloneStep clones a git repository to a local directory.
+// GitCloneStep clones a git repository to a local directory. It uses an
+// in-process git implementation, so no git binary is needed in the image.
 type GitCloneStep struct {
-	name        string
-	repository  string
-	branch      string
-	depth       int
-	directory   string
-	token       string
-	sshKey      string
-	tmpl        *TemplateEngine
-	execCommand func(ctx context.Context, name string, args ...string) *exec.Cmd
+	name       string
+	repository string
+	ref        string
+	depth      int
+	directory  string
+	auth       gitAuthConfig
+	tmpl       *TemplateEngine
+}
+
+// gitAuthConfig holds the (template) credentials shared by the git steps.
+// Values are usually references to secrets fetched earlier in the pipeline,
+// e.g. "{{ .steps.fetch_creds.github_token }}".
+type gitAuthConfig struct {
+	token      string
+	username   string
+	password   string
+	sshKey     string
+	passphrase string
+	knownHosts string
+}
+
+func parseGitAuthConfig(config map[string]any) gitAuthConfig {
+	var a gitAuthConfig
+	a.token, _ = config["token"].(string)
+	a.username, _ = config["username"].(string)
+	a.password, _ = config["password"].(string)
+	a.sshKey, _ = config["ssh_key"].(string)
+	a.passphrase, _ = config["ssh_key_passphrase"].(string)
+	a.knownHosts, _ = config["known_hosts"].(string)
+	return a
 }
 
 // NewGitCloneStepFactory returns a StepFactory that creates GitCloneStep instances.
 func NewGitCloneStepFactory() StepFactory {
 	return func(name string, config map[string]any, _ modular.Application) (PipelineStep, error) {
 		repository, _ := config["repository"].(string)
+		if repository == "" {
+			repository, _ = config["url"].(string)
+		}
 		if repository == "" {
 			return nil, fmt.Errorf("git_clone step %q: 'repository' is required", name)
 		}
 
 		directory, _ := config["directory"].(string)
+		if directory == "" {
+			directory, _ = config["path"].(string)
+		}
 		if directory == "" {
 			return nil, fmt.Errorf("git_clone step %q: 'directory' is required", name)
 		}
 
-		branch, _ := config["branch"].(string)
-		token, _ := config["token"].(string)
-		sshKey, _ := config["ssh_key"].(string)
+		// 'ref' accepts a branch, tag or commit SHA; 'branch' is the older name.
+		ref, _ := config["ref"].(string)
+		if ref == "" {
+			ref, _ = config["branch"].(string)
+		}
 
 		depth := 0
-		if d, ok := config["depth"].(int); ok {
+		switch d := config["depth"].(type) {
+		case int:
 			depth = d
+		case float64:
+			depth = int(d)
+		}
+		if depth < 0 {
+			return nil, fmt.Errorf("git_clone step %q: 'depth' must not be negative", name)
 		}
 
 		return &GitCloneStep{
-			name:        name,
-			repository:  repository,
-			branch:      branch,
-			depth:       depth,
-			directory:   directory,
-			token:       token,
-			sshKey:      sshKey,
-			tmpl:        NewTemplateEngine(),
-			execCommand: exec.CommandContext,
+			name:       name,
+			repository: repository,
+			ref:        ref,
+			depth:      depth,
+			directory:  directory,
+			auth:       parseGitAuthConfig(config),
+			tmpl:       NewTemplateEngine(),
 		}, nil
 	}
 }
@@ -63,7 +106,8 @@ func NewGitCloneStepFactory() StepFactory {
 // Name returns the step name.
 func (s *GitCloneStep) Name() string { return s.name }
 
-// Execute clones the repository to the configured directory.
+// Execute clones the repository to the configured directory and checks out
+// the configured ref.
 func (s *GitCloneStep) Execute(ctx context.Context, pc *PipelineContext) (*StepResult, error) {
 	repository, err := s.tmpl.Resolve(s.repository, pc)
 	if err != nil {
@@ -75,111 +119,206 @@ func (s *GitCloneStep) Execute(ctx context.Context, pc *PipelineContext) (*StepR
 		return nil, fmt.Errorf("git_clone step %q: failed to resolve directory: %w", s.name, err)
 	}
 
-	branch, err := s.tmpl.Resolve(s.branch, pc)
+	ref, err := s.tmpl.Resolve(s.ref, pc)
 	if err != nil {
-		return nil, fmt.Errorf("git_clone step %q: failed to resolve branch: %w", s.name, err)
+		return nil, fmt.Errorf("git_clone step %q: failed to resolve ref: %w", s.name, err)
 	}
 
-	token, err := s.tmpl.Resolve(s.token, pc)
+	auth, err := s.auth.resolve(s.tmpl, pc, repository)
 	if err != nil {
-		return nil, fmt.Errorf("git_clone step %q: failed to resolve token: %w", s.name, err)
+		return nil, fmt.Errorf("git_clone step %q: %w", s.name, err)
 	}
 
-	sshKey, err := s.tmpl.Resolve(s.sshKey, pc)
-	if err != nil {
-		return nil, fmt.Errorf("git_clone step %q: failed to resolve ssh_key: %w", s.name, err)
+	// As with `git clone --depth`, a shallow clone fetches a single branch.
+	opts := &git.CloneOptions{URL: repository, Auth: auth, Depth: s.depth, SingleBranch: s.depth > 0}
+	var commit string
+	if ref != "" {
+		refName, err := resolveRemoteRef(ctx, repository, auth, ref)
+		switch {
+		case err == nil:
+			opts.ReferenceName = refName
+			opts.SingleBranch = true
+		case errors.Is(err, errGitRefNotFound) && isCommitSHA(ref):
+			// A commit is not advertised by the remote; clone the default
+			// branch with full history and check the commit out.
+			commit = ref
+			opts.Depth = 0
+			opts.SingleBranch = false
+		default:
+			return nil, fmt.Errorf("git_clone step %q: %w", s.name, err)
+		}
 	}
 
-	// Inject token into HTTPS URL if provided.
-	cloneURL := repository
-	if token != "" && strings.HasPrefix(repository, "https://") {
-		cloneURL = strings.Replace(repository, "https://", "https://"+token+"@", 1)
+	if opts.SingleBranch {
+		// Only tags on the fetched history, so tags don't deepen a shallow clone.
+		opts.Tags = git.TagFollowing
 	}
 
-	// Build git clone args.
-	args := []string{"clone"}
-	if branch != "" {
-		args = append(args, "--branch", branch)
-	}
-	if s.depth > 0 {
-		args = append(args, "--depth", fmt.Sprintf("%d", s.depth))
+	repo, err := git.PlainCloneContext(ctx, directory, false, opts)
+	if err != nil {
+		return nil, fmt.Errorf("git_clone step %q: git clone %s failed: %w", s.name, redactGitURL(repository), err)
 	}
-	args = append(args, cloneURL, directory)
 
-	var stdout, stderr bytes.Buffer
-	cmd := s.execCommand(ctx, "git", args...) //nolint:gosec // G204: args from trusted pipeline config
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	// Set up SSH key if provided.
-	if sshKey != "" {
-		keyFile, err := os.CreateTemp("", "git-ssh-key-*")
+	if commit != "" {
+		hash, err := repo.ResolveRevision(plumbing.Revision(commit))
 		if err != nil {
-			return nil, fmt.Errorf("git_clone step %q: failed to create SSH key temp file: %w", s.name, err)
+			return nil, fmt.Errorf("git_clone step %q: commit %q not found: %w", s.name, commit, err)
 		}
-		defer os.Remove(keyFile.Name())
-
-		if _, err := keyFile.WriteString(sshKey); err != nil {
-			keyFile.Close()
-			return nil, fmt.Errorf("git_clone step %q: failed to write SSH key: %w", s.name, err)
+		wt, err := repo.Worktree()
+		if err != nil {
+			return nil, fmt.Errorf("git_clone step %q: %w", s.name, err)
 		}
-		keyFile.Close()
-
-		if err := os.Chmod(keyFile.Name(), 0600); err != nil { //nolint:gosec // G304: path from trusted temp file
-			return nil, fmt.Errorf("git_clone step %q: failed to chmod SSH key: %w", s.name, err)
+		if err := wt.Checkout(&git.CheckoutOptions{Hash: *hash}); err != nil {
+			return nil, fmt.Errorf("git_clone step %q: checkout %s failed: %w", s.name, commit, err)
 		}
-
-		cmd.Env = append(os.Environ(),
-			fmt.Sprintf("GIT_SSH_COMMAND=ssh -i %s -o StrictHostKeyChecking=no", keyFile.Name()),
-		)
-	}
-
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("git_clone step %q: git clone failed: %w\nstdout: %s\nstderr: %s",
-			s.name, err, stdout.String(), stderr.String())
 	}
 
-	// Get commit SHA and branch from cloned repo.
-	commitSHA, err := s.getCommitSHA(ctx, directory)
+	head, err := repo.Head()
 	if err != nil {
-		// Non-fatal: return success without SHA.
-		commitSHA = ""
+		return nil, fmt.Errorf("git_clone step %q: failed to read HEAD: %w", s.name, err)
 	}
-
-	resolvedBranch := branch
-	if resolvedBranch == "" {
-		resolvedBranch, _ = s.getCurrentBranch(ctx, directory)
+	branch := ""
+	if head.Name().IsBranch() {
+		branch = head.Name().Short()
 	}
 
 	return &StepResult{
 		Output: map[string]any{
 			"clone_dir":  directory,
-			"commit_sha": commitSHA,
-			"branch":     resolvedBranch,
+			"commit_sha": head.Hash().String(),
+			"branch":     branch,
+			"ref":        ref,
 			"success":    true,
 		},
 	}, nil
 }
 
-// getCommitSHA returns the HEAD commit SHA in the given directory.
-func (s *GitCloneStep) getCommitSHA(ctx context.Context, dir string) (string, error) {
-	var stdout, stderr bytes.Buffer
-	cmd := s.execCommand(ctx, "git", "-C", dir, "rev-parse", "HEAD")
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
-		return "", fmt.Errorf("rev-parse HEAD failed: %w\nstderr: %s", err, stderr.String())
+var errGitRefNotFound = errors.New("ref not found")
+
+// resolveRemoteRef finds ref among the remote's branches and tags.
+func resolveRemoteRef(ctx context.Context, url string, auth transport.AuthMethod, ref string) (plumbing.ReferenceName, error) {
+	remote := git.NewRemote(memory.NewStorage(), &gitconfig.RemoteConfig{Name: "origin", URLs: []string{url}})
+	refs, err := remote.ListContext(ctx, &git.ListOptions{Auth: auth})
+	if err != nil {
+		return "", fmt.Errorf("list refs of %s: %w", redactGitURL(url), err)
 	}
-	return strings.TrimSpace(stdout.String()), nil
+	candidates := []plumbing.ReferenceName{
+		plumbing.ReferenceName(ref),
+		plumbing.NewBranchReferenceName(ref),
+		plumbing.NewTagReferenceName(ref),
+	}
+	for _, want := range candidates {
+		for _, r := range refs {
+			if r.Name() == want && (want.IsBranch() || want.IsTag()) {
+				return want, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%w: %q is not a branch or tag of %s", errGitRefNotFound, ref, redactGitURL(url))
 }
 
-// getCurrentBranch returns the current branch name in the given directory.
-func (s *GitCloneStep) getCurrentBranch(ctx context.Context, dir string) (string, error) {
-	var stdout bytes.Buffer
-	cmd := s.execCommand(ctx, "git", "-C", dir, "rev-parse", "--abbrev-ref", "HEAD")
-	cmd.Stdout = &stdout
-	if err := cmd.Run(); err != nil {
-		return "", err
+var commitSHAPattern = regexp.MustCompile(`^[0-9a-fA-F]{7,40}$`)
+
+func isCommitSHA(ref string) bool { return commitSHAPattern.MatchString(ref) }
+
+// redactGitURL removes userinfo from a URL so credentials don't end up in errors.
+func redactGitURL(url string) string {
+	scheme, rest, ok := strings.Cut(url, "://")
+	if !ok {
+		return url
+	}
+	host, _, _ := strings.Cut(rest, "/")
+	if at := strings.LastIndex(host, "@"); at >= 0 {
+		rest = rest[at+1:]
+	}
+	return scheme + "://" + rest
+}
+
+// resolve builds the go-git auth method for url. An SSH key is used for
+// SSH URLs; a token or username/password for HTTPS. Without credentials it
+// returns nil, which go-git treats as anonymous access.
+func (a gitAuthConfig) resolve(tmpl *TemplateEngine, pc *PipelineContext, url string) (transport.AuthMethod, error) {
+	get := func(key, v string) (string, error) {
+		out, err := tmpl.Resolve(v, pc)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve %s: %w", key, err)
+		}
+		return out, nil
+	}
+	token, err := get("token", a.token)
+	if err != nil {
+		return nil, err
+	}
+	username, err := get("username", a.username)
+	if err != nil {
+		return nil, err
+	}
+	password, err := get("password", a.password)
+	if err != nil {
+		return nil, err
+	}
+	sshKey, err := get("ssh_key", a.sshKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if sshKey != "" && !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		passphrase, err := get("ssh_key_passphrase", a.passphrase)
+		if err != nil {
+			return nil, err
+		}
+		user := username
+		if user == "" {
+			user = "git"
+		}
+		keys, err := gitssh.NewPublicKeys(user, []byte(sshKey), passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ssh_key: %w", err)
+		}
+		knownHostsData, err := get("known_hosts", a.knownHosts)
+		if err != nil {
+			return nil, err
+		}
+		if knownHostsData == "" {
+			// Matches `ssh -o StrictHostKeyChecking=no`; set known_hosts to verify the server.
+			keys.HostKeyCallback = ssh.InsecureIgnoreHostKey() //nolint:gosec // G106: opt-in verification via known_hosts
+		} else if keys.HostKeyCallback, err = knownHostsCallback(knownHostsData); err != nil {
+			return nil, err
+		}
+		return keys, nil
+	}
+
+	switch {
+	case token != "":
+		// Git hosts accept a token as the password of any non-empty user;
+		// GitLab expects "oauth2", which can be set with 'username'.
+		if username == "" {
+			username = "x-access-token"
+		}
+		return &githttp.BasicAuth{Username: username, Password: token}, nil
+	case username != "" || password != "":
+		return &githttp.BasicAuth{Username: username, Password: password}, nil
+	}
+	return nil, nil
+}
+
+// knownHostsCallback parses known_hosts content. knownhosts only reads
+// files, so the content is written to a temporary file that is removed once
+// parsed.
+func knownHostsCallback(data string) (ssh.HostKeyCallback, error) {
+	f, err := os.CreateTemp("", "git-known-hosts-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create known_hosts temp file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(data); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to write known_hosts: %w", err)
+	}
+	f.Close()
+	cb, err := knownhosts.New(f.Name())
+	if err != nil {
+		return nil, fmt.Errorf("invalid known_hosts: %w", err)
 	}
-	return strings.TrimSpace(stdout.String()), nil
+	return cb, nil
 }
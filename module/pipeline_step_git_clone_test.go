@@ -2,9 +2,16 @@ package module
 
 import (
 	"context"
-	"os/exec"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
 )
 
 func TestGitCloneStep_FactoryRequiresRepository(t *testing.T) {
@@ -53,8 +60,8 @@ func TestGitCloneStep_FactoryDefaults(t *testing.T) {
 		t.Fatalf("unexpected factory error: %v", err)
 	}
 	s := raw.(*GitCloneStep)
-	if s.branch != "" {
-		t.Errorf("expected empty default branch, got %q", s.branch)
+	if s.ref != "" {
+		t.Errorf("expected empty default ref, got %q", s.ref)
 	}
 	if s.depth != 0 {
 		t.Errorf("expected depth 0, got %d", s.depth)
@@ -75,227 +82,201 @@ func TestGitCloneStep_FactoryWithAllOptions(t *testing.T) {
 		t.Fatalf("unexpected factory error: %v", err)
 	}
 	s := raw.(*GitCloneStep)
-	if s.branch != "main" {
-		t.Errorf("expected branch main, got %q", s.branch)
+	if s.ref != "main" {
+		t.Errorf("expected ref main, got %q", s.ref)
 	}
 	if s.depth != 1 {
 		t.Errorf("expected depth 1, got %d", s.depth)
 	}
-	if s.token != "mytoken" {
-		t.Errorf("expected token mytoken, got %q", s.token)
+	if s.auth.token != "mytoken" {
+		t.Errorf("expected token mytoken, got %q", s.auth.token)
 	}
 }
 
-func TestGitCloneStep_Execute_Success(t *testing.T) {
-	var capturedArgs [][]string
-	callCount := 0
-
-	s := &GitCloneStep{
-		name:       "clone",
-		repository: "https://github.com/org/repo.git",
-		directory:  "/tmp/repo",
-		tmpl:       NewTemplateEngine(),
-		execCommand: func(_ context.Context, name string, args ...string) *exec.Cmd {
-			callCount++
-			capturedArgs = append(capturedArgs, append([]string{name}, args...))
-			if callCount == 1 {
-				// git clone — succeed
-				return exec.Command("true")
-			}
-			// git rev-parse HEAD and --abbrev-ref HEAD — echo a fake SHA/branch
-			if len(args) > 0 && args[len(args)-1] == "HEAD" {
-				return exec.Command("echo", "abc123def456")
-			}
-			return exec.Command("echo", "main")
-		},
-	}
+// testGitRemote is a local repository used as a clone source. main has two
+// commits, the annotated tag v1.0.0 points at the first, and develop
+// branches off the first commit with one commit of its own.
+type testGitRemote struct {
+	dir                          string
+	first, mainHead, developHead string
+}
 
-	result, err := s.Execute(context.Background(), &PipelineContext{
-		Current:     map[string]any{},
-		StepOutputs: map[string]map[string]any{},
-		TriggerData: map[string]any{},
-		Metadata:    map[string]any{},
+func newTestGitRemote(t *testing.T) *testGitRemote {
+	t.Helper()
+	dir := t.TempDir()
+	repo, err := git.PlainInitWithOptions(dir, &git.PlainInitOptions{
+		InitOptions: git.InitOptions{DefaultBranch: plumbing.NewBranchReferenceName("main")},
 	})
 	if err != nil {
-		t.Fatalf("Execute failed: %v", err)
+		t.Fatal(err)
 	}
-
-	if result.Output["success"] != true {
-		t.Errorf("expected success=true, got %v", result.Output["success"])
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
 	}
-	if result.Output["clone_dir"] != "/tmp/repo" {
-		t.Errorf("expected clone_dir=/tmp/repo, got %v", result.Output["clone_dir"])
+	sig := &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}
+	commit := func(file, content string) plumbing.Hash {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := wt.Add(file); err != nil {
+			t.Fatal(err)
+		}
+		h, err := wt.Commit("update "+file, &git.CommitOptions{Author: sig})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
 	}
 
-	// Verify git clone was called.
-	if callCount < 1 {
-		t.Error("expected at least one exec call")
-	}
-	if len(capturedArgs) < 1 || capturedArgs[0][0] != "git" {
-		t.Error("expected first call to be git")
-	}
-	cloneArgs := capturedArgs[0]
-	foundClone := false
-	for _, a := range cloneArgs {
-		if a == "clone" {
-			foundClone = true
-			break
-		}
+	r := &testGitRemote{dir: dir}
+	first := commit("README.md", "v1")
+	r.first = first.String()
+	if _, err := repo.CreateTag("v1.0.0", first, &git.CreateTagOptions{Tagger: sig, Message: "v1.0.0"}); err != nil {
+		t.Fatal(err)
 	}
-	if !foundClone {
-		t.Errorf("expected git clone in first call args, got %v", cloneArgs)
+	r.mainHead = commit("README.md", "v2").String()
+	if err := wt.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("develop"), Hash: first, Create: true}); err != nil {
+		t.Fatal(err)
 	}
-}
-
-func TestGitCloneStep_Execute_CloneFailure(t *testing.T) {
-	s := &GitCloneStep{
-		name:       "clone",
-		repository: "https://github.com/org/repo.git",
-		directory:  "/tmp/repo",
-		tmpl:       NewTemplateEngine(),
-		execCommand: func(_ context.Context, _ string, _ ...string) *exec.Cmd {
-			return exec.Command("false")
-		},
+	r.developHead = commit("feature.txt", "wip").String()
+	if err := wt.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("main")}); err != nil {
+		t.Fatal(err)
 	}
+	return r
+}
 
-	_, err := s.Execute(context.Background(), &PipelineContext{
-		Current:     map[string]any{},
-		StepOutputs: map[string]map[string]any{},
-		TriggerData: map[string]any{},
-		Metadata:    map[string]any{},
-	})
-	if err == nil {
-		t.Fatal("expected error when git clone fails")
-	}
-	if !strings.Contains(err.Error(), "git clone") {
-		t.Errorf("expected error to mention git clone, got: %v", err)
+func cloneForTest(t *testing.T, config map[string]any) (*StepResult, error) {
+	t.Helper()
+	step, err := NewGitCloneStepFactory()("clone", config, nil)
+	if err != nil {
+		t.Fatalf("unexpected factory error: %v", err)
 	}
+	return step.Execute(context.Background(), NewPipelineContext(map[string]any{"repo": "app"}, nil))
 }
 
-func TestGitCloneStep_Execute_TokenInjectedIntoURL(t *testing.T) {
-	var capturedArgs []string
-	callCount := 0
+func TestGitCloneStep_Execute_Refs(t *testing.T) {
+	remote := newTestGitRemote(t)
 
-	s := &GitCloneStep{
-		name:       "clone",
-		repository: "https://github.com/org/repo.git",
-		directory:  "/tmp/repo",
-		token:      "secret-token",
-		tmpl:       NewTemplateEngine(),
-		execCommand: func(_ context.Context, name string, args ...string) *exec.Cmd {
-			callCount++
-			if callCount == 1 {
-				capturedArgs = append([]string{name}, args...)
-				return exec.Command("true")
-			}
-			return exec.Command("echo", "abc123")
-		},
+	tests := []struct {
+		ref        string
+		wantCommit string
+		wantBranch string
+	}{
+		{"", remote.mainHead, "main"},
+		{"develop", remote.developHead, "develop"},
+		{"v1.0.0", remote.first, ""},
+		{remote.first, remote.first, ""},
+		{remote.first[:10], remote.first, ""},
+	}
+	for _, tt := range tests {
+		dir := filepath.Join(t.TempDir(), "src")
+		result, err := cloneForTest(t, map[string]any{"repository": remote.dir, "directory": dir, "ref": tt.ref})
+		if err != nil {
+			t.Fatalf("ref %q: %v", tt.ref, err)
+		}
+		if result.Output["commit_sha"] != tt.wantCommit {
+			t.Errorf("ref %q: commit_sha = %v, want %s", tt.ref, result.Output["commit_sha"], tt.wantCommit)
+		}
+		if result.Output["branch"] != tt.wantBranch {
+			t.Errorf("ref %q: branch = %v, want %q", tt.ref, result.Output["branch"], tt.wantBranch)
+		}
+		if result.Output["clone_dir"] != dir || result.Output["success"] != true {
+			t.Errorf("ref %q: output = %v", tt.ref, result.Output)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "README.md")); err != nil {
+			t.Errorf("ref %q: worktree not checked out: %v", tt.ref, err)
+		}
 	}
+}
 
-	_, err := s.Execute(context.Background(), &PipelineContext{
-		Current:     map[string]any{},
-		StepOutputs: map[string]map[string]any{},
-		TriggerData: map[string]any{},
-		Metadata:    map[string]any{},
+func TestGitCloneStep_Execute_DepthAndTemplates(t *testing.T) {
+	remote := newTestGitRemote(t)
+	base := t.TempDir()
+
+	result, err := cloneForTest(t, map[string]any{
+		"url":   "file://" + remote.dir,
+		"path":  base + "/{{ .repo }}",
+		"depth": 1,
 	})
 	if err != nil {
-		t.Fatalf("Execute failed: %v", err)
+		t.Fatal(err)
 	}
-
-	// Check that the token-injected URL appears in the clone args.
-	foundToken := false
-	for _, a := range capturedArgs {
-		if strings.Contains(a, "secret-token@") {
-			foundToken = true
-			break
-		}
+	if result.Output["clone_dir"] != base+"/app" {
+		t.Errorf("clone_dir = %v", result.Output["clone_dir"])
 	}
-	if !foundToken {
-		t.Errorf("expected token to be injected into URL, args: %v", capturedArgs)
+	repo, err := git.PlainOpen(base + "/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	commits, err := repo.Log(&git.LogOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	_ = commits.ForEach(func(*object.Commit) error { n++; return nil })
+	if n != 1 {
+		t.Errorf("shallow clone has %d commits, want 1", n)
 	}
 }
 
-func TestGitCloneStep_Execute_WithBranchAndDepth(t *testing.T) {
-	var cloneArgs []string
-	callCount := 0
-
-	s := &GitCloneStep{
-		name:       "clone",
-		repository: "https://github.com/org/repo.git",
-		directory:  "/tmp/repo",
-		branch:     "develop",
-		depth:      1,
-		tmpl:       NewTemplateEngine(),
-		execCommand: func(_ context.Context, name string, args ...string) *exec.Cmd {
-			callCount++
-			if callCount == 1 {
-				cloneArgs = append([]string{name}, args...)
-				return exec.Command("true")
-			}
-			return exec.Command("echo", "abc123")
-		},
-	}
-
-	_, err := s.Execute(context.Background(), &PipelineContext{
-		Current:     map[string]any{},
-		StepOutputs: map[string]map[string]any{},
-		TriggerData: map[string]any{},
-		Metadata:    map[string]any{},
-	})
-	if err != nil {
-		t.Fatalf("Execute failed: %v", err)
+func TestGitCloneStep_Execute_UnknownRef(t *testing.T) {
+	remote := newTestGitRemote(t)
+	_, err := cloneForTest(t, map[string]any{"repository": remote.dir, "directory": t.TempDir() + "/src", "ref": "no-such-branch"})
+	if err == nil || !strings.Contains(err.Error(), "not a branch or tag") {
+		t.Fatalf("expected unknown ref error, got: %v", err)
 	}
+}
 
-	argsStr := strings.Join(cloneArgs, " ")
-	if !strings.Contains(argsStr, "--branch") || !strings.Contains(argsStr, "develop") {
-		t.Errorf("expected --branch develop in args, got: %v", cloneArgs)
+func TestGitCloneStep_Execute_CloneFailure(t *testing.T) {
+	_, err := cloneForTest(t, map[string]any{"repository": filepath.Join(t.TempDir(), "missing"), "directory": t.TempDir() + "/src"})
+	if err == nil {
+		t.Fatal("expected error when the repository does not exist")
 	}
-	if !strings.Contains(argsStr, "--depth") || !strings.Contains(argsStr, "1") {
-		t.Errorf("expected --depth 1 in args, got: %v", cloneArgs)
+	if !strings.Contains(err.Error(), "git clone") {
+		t.Errorf("expected error to mention git clone, got: %v", err)
 	}
 }
 
-func TestGitCloneStep_Execute_TemplateResolution(t *testing.T) {
-	var capturedDir string
-	callCount := 0
+func TestGitAuthConfig_Resolve(t *testing.T) {
+	pc := NewPipelineContext(nil, nil)
+	pc.MergeStepOutput("creds", map[string]any{"token": "s3cret"})
+	tmpl := NewTemplateEngine()
 
-	s := &GitCloneStep{
-		name:       "clone",
-		repository: "https://github.com/org/{{ .repo }}.git",
-		directory:  "/tmp/workspace/{{ .repo }}",
-		tmpl:       NewTemplateEngine(),
-		execCommand: func(_ context.Context, name string, args ...string) *exec.Cmd {
-			callCount++
-			if callCount == 1 {
-				// Last arg before the URL should be the directory.
-				for i, a := range args {
-					if a == "/tmp/workspace/my-repo" {
-						_ = i
-						capturedDir = a
-					}
-				}
-				return exec.Command("true")
-			}
-			return exec.Command("echo", "abc123")
-		},
+	auth, err := gitAuthConfig{token: "{{ .steps.creds.token }}"}.resolve(tmpl, pc, "https://github.com/org/repo.git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	basic, ok := auth.(*githttp.BasicAuth)
+	if !ok || basic.Username != "x-access-token" || basic.Password != "s3cret" {
+		t.Errorf("token auth = %#v", auth)
 	}
 
-	pc := &PipelineContext{
-		Current:     map[string]any{"repo": "my-repo"},
-		StepOutputs: map[string]map[string]any{},
-		TriggerData: map[string]any{"repo": "my-repo"},
-		Metadata:    map[string]any{},
+	auth, _ = gitAuthConfig{token: "t", username: "oauth2"}.resolve(tmpl, pc, "https://gitlab.com/org/repo.git")
+	if basic, ok := auth.(*githttp.BasicAuth); !ok || basic.Username != "oauth2" {
+		t.Errorf("gitlab auth = %#v", auth)
 	}
 
-	result, err := s.Execute(context.Background(), pc)
-	if err != nil {
-		t.Fatalf("Execute failed: %v", err)
+	if auth, _ := (gitAuthConfig{}).resolve(tmpl, pc, "https://github.com/org/repo.git"); auth != nil {
+		t.Errorf("expected anonymous access, got %#v", auth)
 	}
 
-	if result.Output["clone_dir"] != "/tmp/workspace/my-repo" {
-		t.Errorf("expected clone_dir=/tmp/workspace/my-repo, got %v", result.Output["clone_dir"])
+	if _, err := (gitAuthConfig{sshKey: "not a key"}).resolve(tmpl, pc, "git@github.com:org/repo.git"); err == nil {
+		t.Error("expected an error for an invalid ssh_key")
 	}
-	if capturedDir != "/tmp/workspace/my-repo" {
-		t.Errorf("expected directory /tmp/workspace/my-repo in git args, got %q", capturedDir)
+}
+
+func TestRedactGitURL(t *testing.T) {
+	for in, want := range map[string]string{
+		"https://token@github.com/org/repo.git": "https://github.com/org/repo.git",
+		"https://u:p@host/a@b.git":              "https://host/a@b.git",
+		"https://github.com/org/repo.git":       "https://github.com/org/repo.git",
+		"git@github.com:org/repo.git":           "git@github.com:org/repo.git",
+		"ssh://git@github.com/org/repo.git":     "ssh://github.com/org/repo.git",
+	} {
+		if got := redactGitURL(in); got != want {
+			t.Errorf("redactGitURL(%q) = %q, want %q", in, got, want)
+		}
 	}
 }
@@ -15,6 +15,7 @@ import (
 	"golang.org/x/sync/singleflight"
 
 	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow/observability/tracing"
 )
 
 // globalOAuthCache is a process-wide registry of OAuth2 token cache entries, shared across all
@@ -443,6 +444,10 @@ func (s *HTTPCallStep) buildRequest(ctx context.Context, resolvedURL string, bod
 		return nil, fmt.Errorf("http_call step %q: failed to create request: %w", s.name, err)
 	}
 
+	// Propagate trace context, workflow baggage and the request ID;
+	// configured headers below may still override them.
+	tracing.InjectHeaders(ctx, req.Header)
+	InjectRequestID(ctx, req.Header)
 	if bodyReader != nil && !rawBody {
 		req.Header.Set("Content-Type", "application/json")
 	}
@@ -675,3 +680,56 @@ func (s *HTTPCallStep) Execute(ctx context.Context, pc *PipelineContext) (*StepR
 
 	return &StepResult{Output: output}, nil
 }
+
+// DryRunPreview returns the method, URL, headers and body of the request the
+// step would send, without sending it or fetching an OAuth2 token. URL and
+// header templates that reference instance_url resolve as they would before
+// the first token fetch.
+func (s *HTTPCallStep) DryRunPreview(_ context.Context, pc *PipelineContext) (map[string]any, error) {
+	resolvedURL, err := s.tmpl.Resolve(s.url, pc)
+	if err != nil {
+		return nil, fmt.Errorf("http_call step %q: failed to resolve url: %w", s.name, err)
+	}
+	if s.clientRef != "" {
+		hc, err := s.resolveClientRef()
+		if err != nil {
+			return nil, err
+		}
+		if resolvedURL, err = resolveStepURL(resolvedURL, hc.BaseURL()); err != nil {
+			return nil, fmt.Errorf("http_call step %q: failed to resolve url against base: %w", s.name, err)
+		}
+	}
+
+	headers := make(map[string]any, len(s.headers))
+	for k, v := range s.headers {
+		if resolved, err := s.tmpl.Resolve(v, pc); err == nil {
+			v = resolved
+		}
+		headers[k] = v
+	}
+	if s.auth != nil {
+		headers["Authorization"] = "Bearer <token>"
+	}
+
+	preview := map[string]any{
+		"method":  s.method,
+		"url":     resolvedURL,
+		"headers": headers,
+	}
+	bodyReader, rawBody, err := s.buildBodyReader(pc)
+	if err != nil {
+		return nil, err
+	}
+	if bodyReader != nil {
+		data, err := io.ReadAll(bodyReader)
+		if err != nil {
+			return nil, fmt.Errorf("http_call step %q: failed to read body: %w", s.name, err)
+		}
+		var body any
+		if rawBody || json.Unmarshal(data, &body) != nil {
+			body = string(data)
+		}
+		preview["body"] = body
+	}
+	return preview, nil
+}
@@ -12,6 +12,8 @@ import (
 	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/GoCodeAlone/workflow/observability/tracing"
 )
 
 func TestHTTPCallStep_BasicGET(t *testing.T) {
@@ -1462,3 +1464,29 @@ func TestHTTPCallStep_ClientRef_AbsoluteURLBypassesBaseURL(t *testing.T) {
 		t.Errorf("expected path /absolute/path, got %q", gotPath)
 	}
 }
+
+func TestHTTPCallStep_PropagatesBaggage(t *testing.T) {
+	var gotBaggage string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBaggage = r.Header.Get("baggage")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	step, err := NewHTTPCallStepFactory()("notify", map[string]any{"url": srv.URL, "method": "POST"}, nil)
+	if err != nil {
+		t.Fatalf("factory error: %v", err)
+	}
+	step.(*HTTPCallStep).httpClient = srv.Client()
+
+	ctx := tracing.WithBaggage(context.Background(), map[string]string{
+		tracing.BaggageTenantID:    "acme",
+		tracing.BaggageExecutionID: "exec-1",
+	})
+	if _, err := step.Execute(ctx, NewPipelineContext(nil, nil)); err != nil {
+		t.Fatalf("execute error: %v", err)
+	}
+	if !strings.Contains(gotBaggage, "tenant_id=acme") || !strings.Contains(gotBaggage, "execution_id=exec-1") {
+		t.Errorf("baggage header = %q, want tenant_id and execution_id", gotBaggage)
+	}
+}
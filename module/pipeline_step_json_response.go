@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow/pipeline"
 )
 
 // JSONResponseStep writes an HTTP JSON response with a custom status code and stops the pipeline.
@@ -21,13 +22,25 @@ type JSONResponseStep struct {
 	body       map[string]any
 	bodyRaw    any // for non-map bodies (arrays, literals)
 	bodyFrom   string
+	problem    *jsonResponseProblem
 	tmpl       *TemplateEngine
 }
 
+// jsonResponseProblem is the problem config of a json_response step: the
+// step writes an application/problem+json body for a catalog code instead of
+// body or body_from.
+type jsonResponseProblem struct {
+	code       ProblemCode
+	title      string
+	detail     string // template
+	errorsFrom string
+}
+
 // NewJSONResponseStepFactory returns a StepFactory that creates JSONResponseStep instances.
 func NewJSONResponseStepFactory() StepFactory {
 	return func(name string, config map[string]any, _ modular.Application) (PipelineStep, error) {
 		status := 200
+		_, hasStatus := config["status"]
 		if s, ok := config["status"]; ok {
 			switch v := s.(type) {
 			case int:
@@ -58,6 +71,28 @@ func NewJSONResponseStepFactory() StepFactory {
 		bodyFrom, _ := config["body_from"].(string)
 		statusFrom, _ := config["status_from"].(string)
 
+		var problem *jsonResponseProblem
+		if raw, ok := config["problem"]; ok {
+			pm, ok := raw.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("json_response step %q: 'problem' must be a map", name)
+			}
+			if body != nil || bodyRaw != nil || bodyFrom != "" {
+				return nil, fmt.Errorf("json_response step %q: 'problem' cannot be combined with 'body' or 'body_from'", name)
+			}
+			code, _ := pm["code"].(string)
+			if !IsProblemCode(code) {
+				return nil, fmt.Errorf("json_response step %q: unknown problem code %q", name, code)
+			}
+			problem = &jsonResponseProblem{code: ProblemCode(code)}
+			problem.title, _ = pm["title"].(string)
+			problem.detail, _ = pm["detail"].(string)
+			problem.errorsFrom, _ = pm["errors_from"].(string)
+			if !hasStatus {
+				status = NewProblem(problem.code, "").Status
+			}
+		}
+
 		return &JSONResponseStep{
 			name:       name,
 			status:     status,
@@ -66,6 +101,7 @@ func NewJSONResponseStepFactory() StepFactory {
 			body:       body,
 			bodyRaw:    bodyRaw,
 			bodyFrom:   bodyFrom,
+			problem:    problem,
 			tmpl:       NewTemplateEngine(),
 		}, nil
 	}
@@ -108,6 +144,10 @@ func (s *JSONResponseStep) resolveStatus(pc *PipelineContext) int {
 func (s *JSONResponseStep) Execute(_ context.Context, pc *PipelineContext) (*StepResult, error) {
 	status := s.resolveStatus(pc)
 
+	if s.problem != nil {
+		return s.executeProblem(pc, status)
+	}
+
 	w, ok := pc.Metadata["_http_response_writer"].(http.ResponseWriter)
 	if !ok {
 		// No response writer — return the body as output without writing HTTP
@@ -131,6 +171,7 @@ func (s *JSONResponseStep) Execute(_ context.Context, pc *PipelineContext) (*Ste
 
 	// Set headers
 	w.Header().Set("Content-Type", "application/json")
+	setContentLanguage(w, pc)
 	for k, v := range s.headers {
 		w.Header().Set(k, v)
 	}
@@ -156,6 +197,50 @@ func (s *JSONResponseStep) Execute(_ context.Context, pc *PipelineContext) (*Ste
 	}, nil
 }
 
+// executeProblem writes the configured problem. The failing step is the one
+// an on_error skip or compensate branch is handling, if any.
+func (s *JSONResponseStep) executeProblem(pc *PipelineContext, status int) (*StepResult, error) {
+	detail, err := s.tmpl.Resolve(s.problem.detail, pc)
+	if err != nil {
+		return nil, fmt.Errorf("json_response step %q: failed to resolve problem detail: %w", s.name, err)
+	}
+	p := NewProblem(s.problem.code, detail)
+	p.Status = status
+	if s.problem.title != "" {
+		title, err := s.tmpl.Resolve(s.problem.title, pc)
+		if err != nil {
+			return nil, fmt.Errorf("json_response step %q: failed to resolve problem title: %w", s.name, err)
+		}
+		p.Title = title
+	}
+	if s.problem.errorsFrom != "" {
+		p.Errors = resolveBodyFrom(s.problem.errorsFrom, pc)
+	}
+	if w, ok := pc.Metadata["_http_response_writer"].(http.ResponseWriter); ok {
+		setContentLanguage(w, pc)
+		for k, v := range s.headers {
+			w.Header().Set(k, v)
+		}
+	}
+	failedStep, _ := pc.Metadata["_failed_step"].(string)
+	if !writeStepProblem(pc, failedStep, p) {
+		// No response writer: return the problem as the body, as for body.
+		var body map[string]any
+		raw, _ := json.Marshal(p)
+		_ = json.Unmarshal(raw, &body)
+		return &StepResult{Output: map[string]any{"status": status, "body": body}, Stop: true}, nil
+	}
+	return &StepResult{Output: map[string]any{"status": status}, Stop: true}, nil
+}
+
+// setContentLanguage announces the execution's resolved locale when the
+// config declares locales. A Content-Language in the step's headers wins.
+func setContentLanguage(w http.ResponseWriter, pc *PipelineContext) {
+	if _, locale, ok := pipeline.LocaleCatalog(pc); ok {
+		w.Header().Set("Content-Language", locale)
+	}
+}
+
 // resolveResponseBody determines the response body from the step configuration.
 func (s *JSONResponseStep) resolveResponseBody(pc *PipelineContext) any {
 	if s.bodyFrom != "" {
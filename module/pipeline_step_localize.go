@@ -0,0 +1,109 @@
+package module
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow/pipeline"
+)
+
+// LocalizeStep translates a set of catalog messages into the execution's
+// locale and outputs them, so later steps can reference translated text
+// without calling the t template function in every field. It can also
+// switch the execution's locale, e.g. to a language stored on a user.
+type LocalizeStep struct {
+	name     string
+	locale   string // template
+	messages map[string]localizeMessage
+	tmpl     *TemplateEngine
+}
+
+// localizeMessage is one entry of a localize step's messages map.
+type localizeMessage struct {
+	key  string
+	args map[string]any // values may be templates
+}
+
+// NewLocalizeStepFactory returns a StepFactory that creates LocalizeStep instances.
+func NewLocalizeStepFactory() StepFactory {
+	return func(name string, config map[string]any, _ modular.Application) (PipelineStep, error) {
+		locale, _ := config["locale"].(string)
+		raw, ok := config["messages"].(map[string]any)
+		if !ok || len(raw) == 0 {
+			return nil, fmt.Errorf("localize step %q: 'messages' must be a non-empty map", name)
+		}
+		messages := make(map[string]localizeMessage, len(raw))
+		for out, v := range raw {
+			switch m := v.(type) {
+			case string:
+				messages[out] = localizeMessage{key: m}
+			case map[string]any:
+				key, _ := m["key"].(string)
+				if key == "" {
+					return nil, fmt.Errorf("localize step %q: messages.%s: 'key' is required", name, out)
+				}
+				args, _ := m["args"].(map[string]any)
+				messages[out] = localizeMessage{key: key, args: args}
+			default:
+				return nil, fmt.Errorf("localize step %q: messages.%s must be a message key or a map with key and args", name, out)
+			}
+		}
+		return &LocalizeStep{
+			name:     name,
+			locale:   locale,
+			messages: messages,
+			tmpl:     NewTemplateEngine(),
+		}, nil
+	}
+}
+
+// Name returns the step name.
+func (s *LocalizeStep) Name() string { return s.name }
+
+// Execute resolves the configured locale, if any, makes it the execution's
+// locale, and translates every message into it. A message key the catalogs
+// lack renders as the key, or fails the step in strict template mode.
+func (s *LocalizeStep) Execute(_ context.Context, pc *PipelineContext) (*StepResult, error) {
+	catalog, locale, ok := pipeline.LocaleCatalog(pc)
+	if !ok {
+		return nil, fmt.Errorf("localize step %q: the config declares no locales", s.name)
+	}
+	if s.locale != "" {
+		requested, err := s.tmpl.Resolve(s.locale, pc)
+		if err != nil {
+			return nil, fmt.Errorf("localize step %q: failed to resolve locale: %w", s.name, err)
+		}
+		if requested != "" {
+			locale = catalog.Resolve(requested)
+			pc.Metadata["locale"] = locale
+		}
+	}
+
+	names := make([]string, 0, len(s.messages))
+	for out := range s.messages {
+		names = append(names, out)
+	}
+	sort.Strings(names)
+	translated := make(map[string]any, len(s.messages))
+	for _, out := range names {
+		m := s.messages[out]
+		args, err := s.tmpl.ResolveMap(m.args, pc)
+		if err != nil {
+			return nil, fmt.Errorf("localize step %q: messages.%s: %w", s.name, out, err)
+		}
+		text, err := catalog.Translate(locale, m.key, args)
+		if err != nil {
+			if pc.StrictTemplates {
+				return nil, fmt.Errorf("localize step %q: messages.%s: %w", s.name, out, err)
+			}
+			text = m.key
+		}
+		translated[out] = text
+	}
+	return &StepResult{Output: map[string]any{
+		"locale":   locale,
+		"messages": translated,
+	}}, nil
+}
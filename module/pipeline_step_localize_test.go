@@ -0,0 +1,142 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/GoCodeAlone/workflow/i18n"
+)
+
+func newTestCatalog(t *testing.T) *i18n.Catalog {
+	t.Helper()
+	c, err := i18n.Load(i18n.Options{
+		Default: "en",
+		Messages: map[string]map[string]any{
+			"en": {
+				"greeting": "Hello, {name}!",
+				"cart":     map[string]any{"items": map[string]any{"one": "{count} item", "other": "{count} items"}},
+			},
+			"de": {
+				"greeting": "Hallo, {name}!",
+				"cart":     map[string]any{"items": map[string]any{"one": "{count} Artikel", "other": "{count} Artikel"}},
+			},
+			"ru": {
+				"cart": map[string]any{"items": map[string]any{"one": "{count} товар", "few": "{count} товара", "many": "{count} товаров", "other": "{count} товара"}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestPipelineLocaleNegotiation(t *testing.T) {
+	respond, err := NewJSONResponseStepFactory()("respond", map[string]any{
+		"body": map[string]any{
+			"locale":  "{{ .meta.locale }}",
+			"message": `{{ t "greeting" "name" .name }}`,
+			"items":   `{{ t "cart.items" .args }}`,
+		},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &Pipeline{Name: "hello", Steps: []PipelineStep{respond}, Locales: newTestCatalog(t)}
+
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		routeLocale    string
+		wantLocale     string
+		wantMessage    string
+		wantItems      string
+	}{
+		{"accept-language with region", "de-AT, en;q=0.5", "", "de", "Hallo, Ana!", "21 Artikel"},
+		{"unsupported language", "fr-CA", "", "en", "Hello, Ana!", "21 items"},
+		{"fallback to default message", "ru", "", "ru", "Hello, Ana!", "21 товар"},
+		{"route override", "de", "en-GB", "en", "Hello, Ana!", "21 items"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/hello", nil)
+			req.Header.Set("Accept-Language", tc.acceptLanguage)
+			w := httptest.NewRecorder()
+			ctx := context.WithValue(context.Background(), HTTPRequestContextKey, req)
+			ctx = context.WithValue(ctx, HTTPResponseWriterContextKey, w)
+			if tc.routeLocale != "" {
+				ctx = context.WithValue(ctx, routeLocaleContextKey, tc.routeLocale)
+			}
+			if _, err := p.Execute(ctx, map[string]any{"name": "Ana", "args": map[string]any{"count": 21}}); err != nil {
+				t.Fatal(err)
+			}
+			var body map[string]any
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body["locale"] != tc.wantLocale || body["message"] != tc.wantMessage || body["items"] != tc.wantItems {
+				t.Errorf("body = %v, want locale %q, message %q, items %q", body, tc.wantLocale, tc.wantMessage, tc.wantItems)
+			}
+			if got := w.Header().Get("Content-Language"); got != tc.wantLocale {
+				t.Errorf("Content-Language = %q, want %q", got, tc.wantLocale)
+			}
+		})
+	}
+}
+
+func TestTranslateWithoutLocales(t *testing.T) {
+	pc := NewPipelineContext(nil, nil)
+	got, err := NewTemplateEngine().Resolve(`{{ t "greeting" }}`, pc)
+	if err != nil || got != "greeting" {
+		t.Errorf("Resolve = %q, %v; want the key", got, err)
+	}
+	pc.StrictTemplates = true
+	if _, err := NewTemplateEngine().Resolve(`{{ t "greeting" }}`, pc); err == nil {
+		t.Error("expected an error in strict mode")
+	}
+}
+
+func TestLocalizeStep(t *testing.T) {
+	step, err := NewLocalizeStepFactory()("msgs", map[string]any{
+		"locale": "{{ .user.language }}",
+		"messages": map[string]any{
+			"title":   "greeting",
+			"summary": map[string]any{"key": "cart.items", "args": map[string]any{"count": "{{ .count }}"}},
+			"missing": "nope",
+		},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pc := NewPipelineContext(map[string]any{"user": map[string]any{"language": "ru-RU"}, "count": 3}, map[string]any{
+		"_i18n_catalog": newTestCatalog(t),
+		"locale":        "en",
+	})
+	result, err := step.Execute(context.Background(), pc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages, _ := result.Output["messages"].(map[string]any)
+	if result.Output["locale"] != "ru" || pc.Metadata["locale"] != "ru" {
+		t.Errorf("locale = %v (metadata %v), want ru", result.Output["locale"], pc.Metadata["locale"])
+	}
+	if messages["summary"] != "3 товара" || messages["title"] != "Hello, {name}!" || messages["missing"] != "nope" {
+		t.Errorf("messages = %v", messages)
+	}
+
+	pc.StrictTemplates = true
+	if _, err := step.Execute(context.Background(), pc); err == nil || !strings.Contains(err.Error(), `no message "nope"`) {
+		t.Errorf("strict mode error = %v, want missing message", err)
+	}
+
+	if _, err := step.Execute(context.Background(), NewPipelineContext(nil, nil)); err == nil {
+		t.Error("expected an error without locales")
+	}
+	if _, err := NewLocalizeStepFactory()("bad", map[string]any{"messages": map[string]any{"x": map[string]any{}}}, nil); err == nil {
+		t.Error("expected an error for a message without a key")
+	}
+}
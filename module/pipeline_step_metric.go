@@ -0,0 +1,294 @@
+package module
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/GoCodeAlone/modular"
+)
+
+// metricNamePattern matches valid Prometheus metric and label names.
+var metricNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// CustomMetricOtherValue is recorded for a label whose resolved value is not
+// in the label's declared values.
+const CustomMetricOtherValue = "_other"
+
+// maxMetricHashBuckets bounds hash_buckets on a metric label.
+const maxMetricHashBuckets = 1000
+
+// metricLabel is a label value template plus the bound on the values it may
+// produce. A templated label must declare either values (an enum; anything
+// else is recorded as CustomMetricOtherValue) or hashBuckets (the value is
+// hashed into that many buckets), so request data such as user IDs cannot
+// create unbounded series. Labels without a template are constant.
+type metricLabel struct {
+	tmpl        string
+	values      map[string]bool
+	hashBuckets int
+}
+
+// parseMetricLabel parses a labels entry: either a constant string or a map
+// with a value template and one of values or hash_buckets.
+func parseMetricLabel(stepName, label string, raw any) (metricLabel, error) {
+	spec, ok := raw.(map[string]any)
+	if !ok {
+		l := metricLabel{tmpl: fmt.Sprint(raw)}
+		if strings.Contains(l.tmpl, "{{") {
+			return l, fmt.Errorf("metric step %q: label %q is templated and must declare 'values' or 'hash_buckets' to bound its cardinality", stepName, label)
+		}
+		return l, nil
+	}
+
+	l := metricLabel{}
+	l.tmpl, _ = spec["value"].(string)
+	if l.tmpl == "" {
+		return l, fmt.Errorf("metric step %q: label %q: 'value' is required", stepName, label)
+	}
+	rawValues, hasValues := spec["values"]
+	rawBuckets, hasBuckets := spec["hash_buckets"]
+	switch {
+	case hasValues && hasBuckets:
+		return l, fmt.Errorf("metric step %q: label %q: set only one of 'values' and 'hash_buckets'", stepName, label)
+	case hasValues:
+		list, _ := rawValues.([]any)
+		if len(list) == 0 {
+			return l, fmt.Errorf("metric step %q: label %q: 'values' must be a non-empty list", stepName, label)
+		}
+		l.values = make(map[string]bool, len(list))
+		for _, v := range list {
+			l.values[fmt.Sprint(v)] = true
+		}
+	case hasBuckets:
+		// YAML decodes to int, JSON to float64 and some callers pass int64.
+		n, ok := intFromAny(rawBuckets)
+		if f, isFloat := rawBuckets.(float64); isFloat && float64(n) != f {
+			ok = false
+		}
+		if !ok || n < 1 || n > maxMetricHashBuckets {
+			return l, fmt.Errorf("metric step %q: label %q: 'hash_buckets' must be an integer between 1 and %d", stepName, label, maxMetricHashBuckets)
+		}
+		l.hashBuckets = n
+	case strings.Contains(l.tmpl, "{{"):
+		return l, fmt.Errorf("metric step %q: label %q is templated and must declare 'values' or 'hash_buckets' to bound its cardinality", stepName, label)
+	}
+	return l, nil
+}
+
+// bound maps a resolved label value onto the label's declared value set.
+func (l metricLabel) bound(v string) string {
+	switch {
+	case l.values != nil:
+		if l.values[v] {
+			return v
+		}
+		return CustomMetricOtherValue
+	case l.hashBuckets > 0:
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(v))
+		return strconv.Itoa(int(h.Sum32() % uint32(l.hashBuckets)))
+	}
+	return v
+}
+
+// MetricStep records a custom business metric (counter, gauge, or histogram)
+// on the MetricsCollector so it is exposed through the metrics endpoint.
+type MetricStep struct {
+	name      string
+	collector string // service name of the MetricsCollector
+	spec      CustomMetricSpec
+	labels    map[string]metricLabel
+	value     any    // number or template string
+	operation string // gauge operation: set, inc, dec, add
+	app       modular.Application
+	tmpl      *TemplateEngine
+}
+
+// NewMetricStepFactory returns a StepFactory that creates MetricStep instances.
+func NewMetricStepFactory() StepFactory {
+	return func(name string, config map[string]any, app modular.Application) (PipelineStep, error) {
+		metric, _ := config["metric"].(string)
+		if metric == "" {
+			return nil, fmt.Errorf("metric step %q: 'metric' is required", name)
+		}
+		if !metricNamePattern.MatchString(metric) {
+			return nil, fmt.Errorf("metric step %q: invalid metric name %q", name, metric)
+		}
+
+		kind, _ := config["type"].(string)
+		if kind == "" {
+			kind = CustomMetricCounter
+		}
+		switch kind {
+		case CustomMetricCounter, CustomMetricGauge, CustomMetricHistogram:
+		default:
+			return nil, fmt.Errorf("metric step %q: 'type' must be counter, gauge, or histogram, got %q", name, kind)
+		}
+
+		operation, _ := config["operation"].(string)
+		if operation != "" && kind != CustomMetricGauge {
+			return nil, fmt.Errorf("metric step %q: 'operation' is only valid for gauges", name)
+		}
+		switch operation {
+		case "", "set", "inc", "dec", "add":
+		default:
+			return nil, fmt.Errorf("metric step %q: 'operation' must be set, inc, dec, or add, got %q", name, operation)
+		}
+
+		labels := make(map[string]metricLabel)
+		if raw, ok := config["labels"].(map[string]any); ok {
+			for k, v := range raw {
+				if !metricNamePattern.MatchString(k) || strings.HasPrefix(k, "__") {
+					return nil, fmt.Errorf("metric step %q: invalid label name %q", name, k)
+				}
+				l, err := parseMetricLabel(name, k, v)
+				if err != nil {
+					return nil, err
+				}
+				labels[k] = l
+			}
+		}
+		labelNames := make([]string, 0, len(labels))
+		for k := range labels {
+			labelNames = append(labelNames, k)
+		}
+		slices.Sort(labelNames)
+
+		var buckets []float64
+		if raw, ok := config["buckets"].([]any); ok {
+			if kind != CustomMetricHistogram {
+				return nil, fmt.Errorf("metric step %q: 'buckets' is only valid for histograms", name)
+			}
+			for _, b := range raw {
+				f, ok := toFloat64(b)
+				if !ok {
+					return nil, fmt.Errorf("metric step %q: bucket %v is not a number", name, b)
+				}
+				buckets = append(buckets, f)
+			}
+			if !slices.IsSorted(buckets) {
+				return nil, fmt.Errorf("metric step %q: 'buckets' must be in increasing order", name)
+			}
+		}
+
+		maxCard := 0
+		if v, ok := config["max_cardinality"].(int); ok {
+			maxCard = v
+		}
+
+		value := config["value"]
+		if value == nil {
+			value = 1.0
+		}
+
+		collector, _ := config["collector"].(string)
+		if collector == "" {
+			collector = "metrics.collector"
+		}
+
+		help, _ := config["help"].(string)
+
+		return &MetricStep{
+			name:      name,
+			collector: collector,
+			spec: CustomMetricSpec{
+				Name:           metric,
+				Help:           help,
+				Kind:           kind,
+				Labels:         labelNames,
+				Buckets:        buckets,
+				MaxCardinality: maxCard,
+			},
+			labels:    labels,
+			value:     value,
+			operation: operation,
+			app:       app,
+			tmpl:      NewTemplateEngine(),
+		}, nil
+	}
+}
+
+func (s *MetricStep) Name() string { return s.name }
+
+func (s *MetricStep) Execute(_ context.Context, pc *PipelineContext) (*StepResult, error) {
+	mc, err := s.resolveCollector()
+	if err != nil {
+		return nil, err
+	}
+	if mc == nil {
+		// No collector in this application: metrics are optional.
+		return &StepResult{Output: map[string]any{
+			"metric":     s.spec.Name,
+			"recorded":   false,
+			"overflowed": false,
+		}}, nil
+	}
+
+	labelValues := make(map[string]string, len(s.labels))
+	for k, l := range s.labels {
+		v, err := s.tmpl.Resolve(l.tmpl, pc)
+		if err != nil {
+			return nil, fmt.Errorf("metric step %q: failed to resolve label %q: %w", s.name, k, err)
+		}
+		labelValues[k] = l.bound(v)
+	}
+
+	value, err := s.resolveValue(pc)
+	if err != nil {
+		return nil, err
+	}
+
+	overflowed, err := mc.RecordCustomMetric(s.spec, labelValues, s.operation, value)
+	if err != nil {
+		return nil, fmt.Errorf("metric step %q: %w", s.name, err)
+	}
+
+	return &StepResult{Output: map[string]any{
+		"metric":     s.spec.Name,
+		"value":      value,
+		"recorded":   true,
+		"overflowed": overflowed,
+	}}, nil
+}
+
+// resolveValue resolves the configured value to a float64.
+func (s *MetricStep) resolveValue(pc *PipelineContext) (float64, error) {
+	if f, ok := toFloat64(s.value); ok {
+		return f, nil
+	}
+	str, ok := s.value.(string)
+	if !ok {
+		return 0, fmt.Errorf("metric step %q: 'value' must be a number or template string", s.name)
+	}
+	resolved, err := s.tmpl.Resolve(str, pc)
+	if err != nil {
+		return 0, fmt.Errorf("metric step %q: failed to resolve value: %w", s.name, err)
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(resolved), 64)
+	if err != nil {
+		return 0, fmt.Errorf("metric step %q: value %q is not a number", s.name, resolved)
+	}
+	return f, nil
+}
+
+// resolveCollector returns the metrics collector, or nil when the application
+// has none.
+func (s *MetricStep) resolveCollector() (*MetricsCollector, error) {
+	if s.app == nil {
+		return nil, nil
+	}
+	svc, ok := s.app.SvcRegistry()[s.collector]
+	if !ok {
+		return nil, nil
+	}
+	mc, ok := svc.(*MetricsCollector)
+	if !ok {
+		return nil, fmt.Errorf("metric step %q: service %q is not a metrics collector", s.name, s.collector)
+	}
+	return mc, nil
+}
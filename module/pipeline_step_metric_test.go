@@ -0,0 +1,383 @@
+package module
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func findMetricFamily(t *testing.T, mc *MetricsCollector, name string) *dto.MetricFamily {
+	t.Helper()
+	families, err := mc.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	for _, f := range families {
+		if f.GetName() == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func labelValue(m *dto.Metric, name string) string {
+	for _, lp := range m.GetLabel() {
+		if lp.GetName() == name {
+			return lp.GetValue()
+		}
+	}
+	return ""
+}
+
+func TestMetricStep_Counter(t *testing.T) {
+	mc := NewMetricsCollector("metrics")
+	app := NewMockApplication()
+	app.Services["metrics.collector"] = mc
+
+	step, err := NewMetricStepFactory()("count-orders", map[string]any{
+		"metric": "orders_processed_total",
+		"labels": map[string]any{"region": map[string]any{"value": "{{.region}}", "values": []any{"us", "eu"}}},
+	}, app)
+	if err != nil {
+		t.Fatalf("factory error: %v", err)
+	}
+
+	for _, region := range []string{"us", "us", "eu"} {
+		pc := NewPipelineContext(map[string]any{"region": region}, nil)
+		if _, err := step.Execute(context.Background(), pc); err != nil {
+			t.Fatalf("execute error: %v", err)
+		}
+	}
+
+	f := findMetricFamily(t, mc, "workflow_orders_processed_total")
+	if f == nil {
+		t.Fatal("expected workflow_orders_processed_total to be registered")
+	}
+	counts := map[string]float64{}
+	for _, m := range f.GetMetric() {
+		counts[labelValue(m, "region")] = m.GetCounter().GetValue()
+	}
+	if counts["us"] != 2 || counts["eu"] != 1 {
+		t.Errorf("unexpected counts: %v", counts)
+	}
+}
+
+func TestMetricStep_HistogramTemplateValue(t *testing.T) {
+	mc := NewMetricsCollector("metrics")
+	app := NewMockApplication()
+	app.Services["metrics.collector"] = mc
+
+	step, err := NewMetricStepFactory()("revenue", map[string]any{
+		"metric":  "order_revenue",
+		"type":    "histogram",
+		"value":   "{{.total}}",
+		"buckets": []any{10, 100, 1000},
+	}, app)
+	if err != nil {
+		t.Fatalf("factory error: %v", err)
+	}
+
+	pc := NewPipelineContext(map[string]any{"total": 42.5}, nil)
+	result, err := step.Execute(context.Background(), pc)
+	if err != nil {
+		t.Fatalf("execute error: %v", err)
+	}
+	if result.Output["value"] != 42.5 {
+		t.Errorf("expected value 42.5, got %v", result.Output["value"])
+	}
+
+	f := findMetricFamily(t, mc, "workflow_order_revenue")
+	if f == nil || len(f.GetMetric()) != 1 {
+		t.Fatal("expected workflow_order_revenue histogram")
+	}
+	h := f.GetMetric()[0].GetHistogram()
+	if h.GetSampleCount() != 1 || h.GetSampleSum() != 42.5 {
+		t.Errorf("unexpected histogram: count=%d sum=%v", h.GetSampleCount(), h.GetSampleSum())
+	}
+}
+
+func TestMetricStep_GaugeOperations(t *testing.T) {
+	mc := NewMetricsCollector("metrics")
+	app := NewMockApplication()
+	app.Services["metrics.collector"] = mc
+
+	set, err := NewMetricStepFactory()("set-queue", map[string]any{
+		"metric": "queue_depth",
+		"type":   "gauge",
+		"value":  5,
+	}, app)
+	if err != nil {
+		t.Fatalf("factory error: %v", err)
+	}
+	inc, err := NewMetricStepFactory()("inc-queue", map[string]any{
+		"metric":    "queue_depth",
+		"type":      "gauge",
+		"operation": "inc",
+	}, app)
+	if err != nil {
+		t.Fatalf("factory error: %v", err)
+	}
+
+	pc := NewPipelineContext(nil, nil)
+	if _, err := set.Execute(context.Background(), pc); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if _, err := inc.Execute(context.Background(), pc); err != nil {
+		t.Fatalf("inc: %v", err)
+	}
+
+	f := findMetricFamily(t, mc, "workflow_queue_depth")
+	if f == nil || f.GetMetric()[0].GetGauge().GetValue() != 6 {
+		t.Fatalf("expected gauge value 6, got %v", f)
+	}
+}
+
+func TestMetricStep_CardinalityGuard(t *testing.T) {
+	mc := NewMetricsCollector("metrics")
+	app := NewMockApplication()
+	app.Services["metrics.collector"] = mc
+
+	step, err := NewMetricStepFactory()("per-user", map[string]any{
+		"metric":          "logins_total",
+		"labels":          map[string]any{"user": map[string]any{"value": "{{.user}}", "values": []any{"a", "b", "c", "d"}}},
+		"max_cardinality": 2,
+	}, app)
+	if err != nil {
+		t.Fatalf("factory error: %v", err)
+	}
+
+	var overflowed []bool
+	for _, u := range []string{"a", "b", "c", "a", "d"} {
+		result, err := step.Execute(context.Background(), NewPipelineContext(map[string]any{"user": u}, nil))
+		if err != nil {
+			t.Fatalf("execute error: %v", err)
+		}
+		overflowed = append(overflowed, result.Output["overflowed"].(bool))
+	}
+	want := []bool{false, false, true, false, true}
+	for i := range want {
+		if overflowed[i] != want[i] {
+			t.Fatalf("overflowed = %v, want %v", overflowed, want)
+		}
+	}
+
+	f := findMetricFamily(t, mc, "workflow_logins_total")
+	counts := map[string]float64{}
+	for _, m := range f.GetMetric() {
+		counts[labelValue(m, "user")] = m.GetCounter().GetValue()
+	}
+	if len(counts) != 3 || counts[CustomMetricOverflowValue] != 2 {
+		t.Errorf("expected a, b and overflow series, got %v", counts)
+	}
+}
+
+func TestMetricStep_ConflictingRegistration(t *testing.T) {
+	mc := NewMetricsCollector("metrics")
+	app := NewMockApplication()
+	app.Services["metrics.collector"] = mc
+
+	counter, _ := NewMetricStepFactory()("a", map[string]any{"metric": "conflict"}, app)
+	gauge, _ := NewMetricStepFactory()("b", map[string]any{"metric": "conflict", "type": "gauge"}, app)
+
+	pc := NewPipelineContext(nil, nil)
+	if _, err := counter.Execute(context.Background(), pc); err != nil {
+		t.Fatalf("counter: %v", err)
+	}
+	_, err := gauge.Execute(context.Background(), pc)
+	if err == nil || !strings.Contains(err.Error(), "already registered") {
+		t.Errorf("expected already registered error, got %v", err)
+	}
+}
+
+func TestMetricStep_FactoryErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string]any
+	}{
+		{"missing metric", map[string]any{}},
+		{"invalid metric name", map[string]any{"metric": "bad-name"}},
+		{"invalid type", map[string]any{"metric": "m", "type": "summary"}},
+		{"operation on counter", map[string]any{"metric": "m", "operation": "inc"}},
+		{"invalid label", map[string]any{"metric": "m", "labels": map[string]any{"__name": "x"}}},
+		{"buckets on counter", map[string]any{"metric": "m", "buckets": []any{1}}},
+		{"unsorted buckets", map[string]any{"metric": "m", "type": "histogram", "buckets": []any{5, 1}}},
+		{"unbounded label template", map[string]any{"metric": "m", "labels": map[string]any{"user": "{{.user}}"}}},
+		{"label without value", map[string]any{"metric": "m", "labels": map[string]any{"user": map[string]any{"values": []any{"a"}}}}},
+		{"empty label values", map[string]any{"metric": "m", "labels": map[string]any{"user": map[string]any{"value": "{{.user}}", "values": []any{}}}}},
+		{"values and hash buckets", map[string]any{"metric": "m", "labels": map[string]any{"user": map[string]any{"value": "{{.user}}", "values": []any{"a"}, "hash_buckets": 4}}}},
+		{"too many hash buckets", map[string]any{"metric": "m", "labels": map[string]any{"user": map[string]any{"value": "{{.user}}", "hash_buckets": 5000}}}},
+		{"fractional hash buckets", map[string]any{"metric": "m", "labels": map[string]any{"user": map[string]any{"value": "{{.user}}", "hash_buckets": 2.5}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewMetricStepFactory()("s", tt.config, nil); err == nil {
+				t.Error("expected factory error")
+			}
+		})
+	}
+}
+
+func TestMetricStep_HashBucketsNumberTypes(t *testing.T) {
+	for _, n := range []any{4, int64(4), float64(4)} {
+		cfg := map[string]any{"metric": "m", "labels": map[string]any{
+			"user": map[string]any{"value": "{{.user}}", "hash_buckets": n},
+		}}
+		if _, err := NewMetricStepFactory()("s", cfg, nil); err != nil {
+			t.Errorf("hash_buckets %T: unexpected error: %v", n, err)
+		}
+	}
+}
+
+func TestMetricStep_NonNumericValue(t *testing.T) {
+	app := NewMockApplication()
+	app.Services["metrics.collector"] = NewMetricsCollector("metrics")
+
+	step, err := NewMetricStepFactory()("s", map[string]any{"metric": "m", "value": "{{.v}}"}, app)
+	if err != nil {
+		t.Fatalf("factory error: %v", err)
+	}
+	_, err = step.Execute(context.Background(), NewPipelineContext(map[string]any{"v": "abc"}, nil))
+	if err == nil || !strings.Contains(err.Error(), "not a number") {
+		t.Errorf("expected not a number error, got %v", err)
+	}
+}
+
+func TestMetricStep_MissingCollectorIsNoop(t *testing.T) {
+	step, err := NewMetricStepFactory()("s", map[string]any{"metric": "m"}, NewMockApplication())
+	if err != nil {
+		t.Fatalf("factory error: %v", err)
+	}
+	result, err := step.Execute(context.Background(), NewPipelineContext(nil, nil))
+	if err != nil {
+		t.Fatalf("execute error: %v", err)
+	}
+	if result.Output["recorded"] != false {
+		t.Errorf("expected recorded=false without a collector, got %v", result.Output["recorded"])
+	}
+}
+
+func TestMetricStep_BoundedLabels(t *testing.T) {
+	mc := NewMetricsCollector("metrics")
+	app := NewMockApplication()
+	app.Services["metrics.collector"] = mc
+
+	step, err := NewMetricStepFactory()("count", map[string]any{
+		"metric": "orders_created_total",
+		"labels": map[string]any{
+			"country":  map[string]any{"value": "{{.country}}", "values": []any{"US", "DE"}},
+			"customer": map[string]any{"value": "{{.customer}}", "hash_buckets": 4},
+			"source":   "api",
+		},
+	}, app)
+	if err != nil {
+		t.Fatalf("factory error: %v", err)
+	}
+
+	for _, in := range []map[string]any{
+		{"country": "US", "customer": "c-1"},
+		{"country": "FR", "customer": "c-2"},
+		{"country": "DE", "customer": "c-1"},
+	} {
+		if _, err := step.Execute(context.Background(), NewPipelineContext(in, nil)); err != nil {
+			t.Fatalf("execute error: %v", err)
+		}
+	}
+
+	f := findMetricFamily(t, mc, "workflow_orders_created_total")
+	if f == nil {
+		t.Fatal("expected workflow_orders_created_total to be registered")
+	}
+	countries := map[string]bool{}
+	for _, m := range f.GetMetric() {
+		countries[labelValue(m, "country")] = true
+		if c := labelValue(m, "customer"); c < "0" || c > "3" || len(c) != 1 {
+			t.Errorf("customer label %q is not a hash bucket", c)
+		}
+		if labelValue(m, "source") != "api" {
+			t.Errorf("source label = %q, want api", labelValue(m, "source"))
+		}
+	}
+	if !countries["US"] || !countries["DE"] || !countries[CustomMetricOtherValue] || countries["FR"] {
+		t.Errorf("unexpected country labels: %v", countries)
+	}
+}
+
+func TestMetricStep_ScrapePrometheusEndpoint(t *testing.T) {
+	mc := NewMetricsCollector("metrics")
+	app := NewMockApplication()
+	app.Services["metrics.collector"] = mc
+
+	counter, err := NewMetricStepFactory()("count", map[string]any{
+		"metric": "orders_created_total",
+		"help":   "Orders created by country",
+		"labels": map[string]any{"country": map[string]any{"value": "{{.country}}", "values": []any{"US", "DE"}}},
+	}, app)
+	if err != nil {
+		t.Fatalf("factory error: %v", err)
+	}
+	hist, err := NewMetricStepFactory()("amount", map[string]any{
+		"metric":  "order_amount",
+		"type":    "histogram",
+		"value":   "{{.amount}}",
+		"buckets": []any{10, 100},
+	}, app)
+	if err != nil {
+		t.Fatalf("factory error: %v", err)
+	}
+	gauge, err := NewMetricStepFactory()("open", map[string]any{
+		"metric": "open_orders",
+		"type":   "gauge",
+		"value":  "{{.open}}",
+	}, app)
+	if err != nil {
+		t.Fatalf("factory error: %v", err)
+	}
+
+	for _, in := range []map[string]any{
+		{"country": "US", "amount": 5, "open": 3},
+		{"country": "US", "amount": 50, "open": 2},
+		{"country": "DE", "amount": 500, "open": 7},
+	} {
+		pc := NewPipelineContext(in, nil)
+		for _, step := range []PipelineStep{counter, hist, gauge} {
+			if _, err := step.Execute(context.Background(), pc); err != nil {
+				t.Fatalf("%s: %v", step.Name(), err)
+			}
+		}
+	}
+
+	srv := httptest.NewServer(promhttp.HandlerFor(mc.registry, promhttp.HandlerOpts{}))
+	defer srv.Close()
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("scrape: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read scrape: %v", err)
+	}
+	text := string(body)
+	for _, want := range []string{
+		"# HELP workflow_orders_created_total Orders created by country",
+		"# TYPE workflow_orders_created_total counter",
+		`workflow_orders_created_total{country="US"} 2`,
+		`workflow_orders_created_total{country="DE"} 1`,
+		`workflow_order_amount_bucket{le="10"} 1`,
+		`workflow_order_amount_bucket{le="100"} 2`,
+		`workflow_order_amount_bucket{le="+Inf"} 3`,
+		"workflow_order_amount_sum 555",
+		"workflow_order_amount_count 3",
+		"workflow_open_orders 7",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("scrape output missing %q\n%s", want, text)
+		}
+	}
+}
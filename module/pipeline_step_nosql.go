@@ -232,13 +232,19 @@ func (s *NoSQLDeleteStep) Execute(ctx context.Context, pc *PipelineContext) (*St
 // ── nosql_query ──────────────────────────────────────────────────────────────
 
 // NoSQLQueryStep queries items from a named NoSQL store with optional filters.
+// partitionKey, sortKeyPrefix, descending and limit are passed to stores that
+// query by partition and sort key, such as nosql.dynamodb.
 type NoSQLQueryStep struct {
-	name   string
-	store  string
-	prefix string
-	output string
-	app    modular.Application
-	tmpl   *TemplateEngine
+	name          string
+	store         string
+	prefix        string
+	partitionKey  string
+	sortKeyPrefix string
+	descending    bool
+	limit         int
+	output        string
+	app           modular.Application
+	tmpl          *TemplateEngine
 }
 
 // NewNoSQLQueryStepFactory returns a StepFactory for step.nosql_query.
@@ -249,17 +255,32 @@ func NewNoSQLQueryStepFactory() StepFactory {
 			return nil, fmt.Errorf("nosql_query step %q: 'store' is required", name)
 		}
 		prefix, _ := config["prefix"].(string)
+		partitionKey, _ := config["partitionKey"].(string)
+		sortKeyPrefix, _ := config["sortKeyPrefix"].(string)
+		descending, _ := config["descending"].(bool)
+		limit := 0
+		if v, ok := config["limit"]; ok {
+			n, ok := intFromAny(v)
+			if !ok || n < 0 {
+				return nil, fmt.Errorf("nosql_query step %q: 'limit' must be a non-negative integer", name)
+			}
+			limit = n
+		}
 		output, _ := config["output"].(string)
 		if output == "" {
 			output = "items"
 		}
 		return &NoSQLQueryStep{
-			name:   name,
-			store:  store,
-			prefix: prefix,
-			output: output,
-			app:    app,
-			tmpl:   NewTemplateEngine(),
+			name:          name,
+			store:         store,
+			prefix:        prefix,
+			partitionKey:  partitionKey,
+			sortKeyPrefix: sortKeyPrefix,
+			descending:    descending,
+			limit:         limit,
+			output:        output,
+			app:           app,
+			tmpl:          NewTemplateEngine(),
 		}, nil
 	}
 }
@@ -284,6 +305,21 @@ func (s *NoSQLQueryStep) Execute(ctx context.Context, pc *PipelineContext) (*Ste
 	if resolvedPrefix != "" {
 		params["prefix"] = resolvedPrefix
 	}
+	for param, tmpl := range map[string]string{"partitionKey": s.partitionKey, "sortKeyPrefix": s.sortKeyPrefix} {
+		resolved, err := s.tmpl.Resolve(tmpl, pc)
+		if err != nil {
+			return nil, fmt.Errorf("nosql_query step %q: failed to resolve %s: %w", s.name, param, err)
+		}
+		if resolved != "" {
+			params[param] = resolved
+		}
+	}
+	if s.descending {
+		params["descending"] = true
+	}
+	if s.limit > 0 {
+		params["limit"] = s.limit
+	}
 
 	items, err := ns.Query(ctx, params)
 	if err != nil {
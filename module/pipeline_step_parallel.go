@@ -2,21 +2,115 @@ package module
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/GoCodeAlone/modular"
 )
 
-// ParallelStep executes multiple named sub-steps concurrently and collects results.
+// Values of step.parallel's first_error option.
+const (
+	parallelWaitAll    = "wait_all"
+	parallelCancelRest = "cancel_rest"
+)
+
+// parallelReservedKeys are output keys of step.parallel that a branch name
+// may not shadow.
+var parallelReservedKeys = []string{"results", "errors", "completed", "failed", "cancelled"}
+
+// ParallelStep executes named branches concurrently and collects results.
+// Each branch is a list of steps run in order on its own deep copy of the
+// pipeline context, so branches never see each other's writes, and nothing
+// a branch does reaches the pipeline context except through the step output.
+//
+//	type: step.parallel
+//	name: dashboard
+//	config:
+//	  first_error: cancel_rest
+//	  max_concurrency: 3
+//	  timeout: 2s
+//	  branches:
+//	    orders:
+//	      - type: step.db_query
+//	        name: recent
+//	        config: { database: db, query: "SELECT ..." }
+//	    weather:
+//	      timeout: 500ms
+//	      steps:
+//	        - type: step.http_call
+//	          name: call
+//	          config: { url: "https://weather.example.com/now" }
+//
+// The outputs of a branch's steps are merged, in order, under the branch
+// name, e.g. steps.dashboard.orders.rows. The older steps list form runs one
+// step per branch and reports results only under "results".
 //
 // Complexity:
 //   - Time:  O(max(branch_duration)) — wall clock bounded by slowest branch
 //   - Space: O(branches × context_size) — deep copy of PipelineContext per branch
 type ParallelStep struct {
-	name          string
-	subSteps      []PipelineStep
-	errorStrategy string // "fail_fast" or "collect_errors"
+	name           string
+	branches       []parallelBranch
+	errorStrategy  string // "fail_fast" or "collect_errors"
+	firstError     string // parallelWaitAll or parallelCancelRest
+	maxConcurrency int
+	// topLevel exposes each branch's output directly under its name, as the
+	// branches form does.
+	topLevel bool
+}
+
+// parallelBranch is one named sequence of steps run by a ParallelStep.
+type parallelBranch struct {
+	name    string
+	steps   []PipelineStep
+	timeout time.Duration
+}
+
+// ParallelBranchFailure is the error a single step.parallel branch failed
+// with.
+type ParallelBranchFailure struct {
+	Branch string
+	Err    error
+}
+
+// ParallelError is returned by step.parallel when branches fail. Failed is
+// sorted by branch name, so the message does not depend on which branch
+// finished first. Cancelled lists the branches stopped by first_error:
+// cancel_rest before they completed.
+type ParallelError struct {
+	Step      string
+	Branches  int
+	Failed    []ParallelBranchFailure
+	Cancelled []string
+}
+
+func (e *ParallelError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "parallel step %q: %d of %d branches failed: ", e.Step, len(e.Failed), e.Branches)
+	for i, f := range e.Failed {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		fmt.Fprintf(&b, "%s: %v", f.Branch, f.Err)
+	}
+	if len(e.Cancelled) > 0 {
+		fmt.Fprintf(&b, " (cancelled: %s)", strings.Join(e.Cancelled, ", "))
+	}
+	return b.String()
+}
+
+// Unwrap returns the branch errors, so errors.Is and errors.As see them.
+func (e *ParallelError) Unwrap() []error {
+	errs := make([]error, len(e.Failed))
+	for i, f := range e.Failed {
+		errs[i] = f.Err
+	}
+	return errs
 }
 
 // NewParallelStepFactory returns a StepFactory that creates ParallelStep instances.
@@ -24,11 +118,6 @@ type ParallelStep struct {
 // same lazy pattern as ForEachStep and RetryWithBackoffStep.
 func NewParallelStepFactory(registryFn func() *StepRegistry) StepFactory {
 	return func(name string, config map[string]any, app modular.Application) (PipelineStep, error) {
-		stepsRaw, ok := config["steps"].([]any)
-		if !ok || len(stepsRaw) == 0 {
-			return nil, fmt.Errorf("parallel step %q: 'steps' list is required", name)
-		}
-
 		errorStrategy, _ := config["error_strategy"].(string)
 		if errorStrategy == "" {
 			errorStrategy = "fail_fast"
@@ -37,150 +126,348 @@ func NewParallelStepFactory(registryFn func() *StepRegistry) StepFactory {
 			return nil, fmt.Errorf("parallel step %q: error_strategy must be 'fail_fast' or 'collect_errors', got %q", name, errorStrategy)
 		}
 
-		subSteps := make([]PipelineStep, 0, len(stepsRaw))
-		seen := make(map[string]bool)
-		for i, raw := range stepsRaw {
-			stepCfg, ok := raw.(map[string]any)
-			if !ok {
-				return nil, fmt.Errorf("parallel step %q: steps[%d] must be a map", name, i)
+		var timeout time.Duration
+		if str, _ := config["timeout"].(string); str != "" {
+			d, err := time.ParseDuration(str)
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("parallel step %q: invalid timeout %q", name, str)
+			}
+			timeout = d
+		}
+
+		s := &ParallelStep{name: name, errorStrategy: errorStrategy}
+		branchesRaw, hasBranches := config["branches"]
+		stepsRaw, hasSteps := config["steps"]
+		switch {
+		case hasBranches && hasSteps:
+			return nil, fmt.Errorf("parallel step %q: 'branches' and 'steps' are mutually exclusive", name)
+		case hasBranches:
+			branches, err := buildParallelBranches(name, branchesRaw, timeout, registryFn, app)
+			if err != nil {
+				return nil, err
 			}
-			stepName, _ := stepCfg["name"].(string)
-			if stepName == "" {
-				return nil, fmt.Errorf("parallel step %q: steps[%d] requires a 'name'", name, i)
+			s.branches = branches
+			s.topLevel = true
+		case hasSteps:
+			branches, err := buildParallelStepBranches(name, stepsRaw, timeout, registryFn, app)
+			if err != nil {
+				return nil, err
+			}
+			s.branches = branches
+		default:
+			return nil, fmt.Errorf("parallel step %q: 'branches' map or 'steps' list is required", name)
+		}
+
+		// The steps form predates first_error and has always cancelled the
+		// remaining branches on a fail_fast error.
+		s.firstError, _ = config["first_error"].(string)
+		switch s.firstError {
+		case "":
+			s.firstError = parallelWaitAll
+			if !s.topLevel && errorStrategy == "fail_fast" {
+				s.firstError = parallelCancelRest
 			}
-			if seen[stepName] {
-				return nil, fmt.Errorf("parallel step %q: duplicate branch name %q", name, stepName)
+		case parallelWaitAll:
+		case parallelCancelRest:
+			if errorStrategy == "collect_errors" {
+				return nil, fmt.Errorf("parallel step %q: first_error 'cancel_rest' cannot be combined with error_strategy 'collect_errors'", name)
 			}
-			seen[stepName] = true
+		default:
+			return nil, fmt.Errorf("parallel step %q: first_error must be %q or %q, got %q", name, parallelWaitAll, parallelCancelRest, s.firstError)
+		}
 
-			step, err := buildSubStep(name, stepName, stepCfg, registryFn, app)
+		if v, ok := config["max_concurrency"]; ok {
+			n, isNum := toFloat64(v)
+			if !isNum || n < 0 || n != float64(int(n)) {
+				return nil, fmt.Errorf("parallel step %q: 'max_concurrency' must be a non-negative integer", name)
+			}
+			s.maxConcurrency = int(n)
+		}
+		return s, nil
+	}
+}
+
+// buildParallelBranches builds the branches of the branches form: a map of
+// branch name to either a step list or a map with steps and timeout.
+func buildParallelBranches(name string, raw any, timeout time.Duration, registryFn func() *StepRegistry, app modular.Application) ([]parallelBranch, error) {
+	branchMap, ok := raw.(map[string]any)
+	if !ok || len(branchMap) == 0 {
+		return nil, fmt.Errorf("parallel step %q: 'branches' must be a non-empty map of branch name to steps", name)
+	}
+	branches := make([]parallelBranch, 0, len(branchMap))
+	for _, branchName := range slices.Sorted(maps.Keys(branchMap)) {
+		if slices.Contains(parallelReservedKeys, branchName) {
+			return nil, fmt.Errorf("parallel step %q: branch name %q is reserved", name, branchName)
+		}
+		branch := parallelBranch{name: branchName, timeout: timeout}
+		stepsRaw := branchMap[branchName]
+		if cfg, ok := stepsRaw.(map[string]any); ok {
+			stepsRaw = cfg["steps"]
+			if str, _ := cfg["timeout"].(string); str != "" {
+				d, err := time.ParseDuration(str)
+				if err != nil || d <= 0 {
+					return nil, fmt.Errorf("parallel step %q: branch %q: invalid timeout %q", name, branchName, str)
+				}
+				branch.timeout = d
+			}
+		}
+		list, ok := stepsRaw.([]any)
+		if !ok || len(list) == 0 {
+			return nil, fmt.Errorf("parallel step %q: branch %q needs a non-empty steps list", name, branchName)
+		}
+		for i, item := range list {
+			stepCfg, ok := item.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("parallel step %q: branch %q: steps[%d] must be a map", name, branchName, i)
+			}
+			step, err := buildSubStep(name, fmt.Sprintf("%s.steps[%d]", branchName, i), stepCfg, registryFn, app)
 			if err != nil {
-				return nil, fmt.Errorf("parallel step %q: %w", name, err)
+				return nil, fmt.Errorf("parallel step %q: branch %q: %w", name, branchName, err)
 			}
-			subSteps = append(subSteps, step)
+			branch.steps = append(branch.steps, step)
+		}
+		branches = append(branches, branch)
+	}
+	return branches, nil
+}
+
+// buildParallelStepBranches builds the branches of the steps form, one per
+// named step.
+func buildParallelStepBranches(name string, raw any, timeout time.Duration, registryFn func() *StepRegistry, app modular.Application) ([]parallelBranch, error) {
+	stepsRaw, ok := raw.([]any)
+	if !ok || len(stepsRaw) == 0 {
+		return nil, fmt.Errorf("parallel step %q: 'steps' list is required", name)
+	}
+	branches := make([]parallelBranch, 0, len(stepsRaw))
+	seen := make(map[string]bool)
+	for i, raw := range stepsRaw {
+		stepCfg, ok := raw.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("parallel step %q: steps[%d] must be a map", name, i)
 		}
+		stepName, _ := stepCfg["name"].(string)
+		if stepName == "" {
+			return nil, fmt.Errorf("parallel step %q: steps[%d] requires a 'name'", name, i)
+		}
+		if seen[stepName] {
+			return nil, fmt.Errorf("parallel step %q: duplicate branch name %q", name, stepName)
+		}
+		seen[stepName] = true
 
-		return &ParallelStep{
-			name:          name,
-			subSteps:      subSteps,
-			errorStrategy: errorStrategy,
-		}, nil
+		step, err := buildSubStep(name, stepName, stepCfg, registryFn, app)
+		if err != nil {
+			return nil, fmt.Errorf("parallel step %q: %w", name, err)
+		}
+		branches = append(branches, parallelBranch{name: stepName, steps: []PipelineStep{step}, timeout: timeout})
 	}
+	return branches, nil
 }
 
 // Name returns the step name.
 func (s *ParallelStep) Name() string { return s.name }
 
-// Execute runs all sub-steps concurrently and collects their results.
+// branchOutcome is what a branch ended with.
+type branchOutcome struct {
+	output    map[string]any
+	err       error
+	cancelled bool
+}
+
+// Execute runs all branches concurrently and collects their results.
 //
 // Output:
 //
 //	{
+//	  "<branch>":  map[string]any  — branch output (branches form, successful branches)
 //	  "results":   map[string]any  — branch_name → branch output (successful branches)
 //	  "errors":    map[string]any  — branch_name → error string (failed branches)
+//	  "cancelled": []string        — branches stopped by first_error: cancel_rest
 //	  "completed": int             — count of successful branches
 //	  "failed":    int             — count of failed branches
 //	}
+//
+// Each branch is recorded as a nested step event named <step>.<branch>, with
+// parent_step set to this step and the branch's own elapsed time.
 func (s *ParallelStep) Execute(ctx context.Context, pc *PipelineContext) (*StepResult, error) {
-	n := len(s.subSteps)
+	n := len(s.branches)
 	if n == 0 {
 		return &StepResult{
 			Output: map[string]any{
 				"results":   map[string]any{},
 				"errors":    map[string]any{},
+				"cancelled": []string{},
 				"completed": 0,
 				"failed":    0,
 			},
 		}, nil
 	}
 
-	type branchResult struct {
-		name   string
-		output map[string]any
-		err    error
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var cancelOnce sync.Once
+	var cancelledRest bool
+	var mu sync.Mutex // guards cancelledRest
+
+	var sem chan struct{}
+	if s.maxConcurrency > 0 && s.maxConcurrency < n {
+		sem = make(chan struct{}, s.maxConcurrency)
 	}
 
-	results := make([]branchResult, n)
+	outcomes := make([]branchOutcome, n)
 	var wg sync.WaitGroup
 	wg.Add(n)
-
-	// For fail_fast, derive a cancellable context
-	branchCtx, cancel := context.WithCancel(ctx)
-	defer cancel()
-
-	var firstErr error
-	var errOnce sync.Once
-
-	for i, step := range s.subSteps {
-		i, step := i, step
+	for i := range s.branches {
+		branch := &s.branches[i]
 		childPC := buildParallelChildContext(pc)
 		go func() {
 			defer wg.Done()
-			defer func() {
-				if rec := recover(); rec != nil {
-					panicErr := fmt.Errorf("panic in parallel branch %q: %v", step.Name(), rec)
-					results[i] = branchResult{name: step.Name(), err: panicErr}
-					if s.errorStrategy == "fail_fast" {
-						errOnce.Do(func() {
-							firstErr = fmt.Errorf("parallel step %q: branch %q panicked: %v", s.name, step.Name(), rec)
-							cancel()
-						})
-					}
-				}
-			}()
-			result, err := step.Execute(branchCtx, childPC)
-			if err != nil {
-				results[i] = branchResult{name: step.Name(), err: err}
-				if s.errorStrategy == "fail_fast" {
-					errOnce.Do(func() {
-						firstErr = fmt.Errorf("parallel step %q: branch %q failed: %w", s.name, step.Name(), err)
-						cancel()
-					})
+			if sem != nil {
+				select {
+				case sem <- struct{}{}:
+					defer func() { <-sem }()
+				case <-runCtx.Done():
+					outcomes[i] = s.notStarted(ctx, branch, runCtx.Err(), &mu, &cancelledRest)
+					return
 				}
-				return
 			}
-			output := make(map[string]any)
-			if result != nil && result.Output != nil {
-				for k, v := range result.Output {
-					output[k] = v
-				}
+			out := s.runBranch(runCtx, branch, childPC)
+			if out.err != nil {
+				mu.Lock()
+				out.cancelled = cancelledRest && ctx.Err() == nil && errors.Is(out.err, context.Canceled)
+				mu.Unlock()
+			}
+			outcomes[i] = out
+			if out.err != nil && !out.cancelled && s.firstError == parallelCancelRest {
+				cancelOnce.Do(func() {
+					mu.Lock()
+					cancelledRest = true
+					mu.Unlock()
+					cancel()
+				})
 			}
-			results[i] = branchResult{name: step.Name(), output: output}
 		}()
 	}
-
 	wg.Wait()
 
-	// Build output maps
-	successMap := make(map[string]any)
-	errorMap := make(map[string]any)
-	for _, r := range results {
-		if r.err != nil {
-			errorMap[r.name] = r.err.Error()
-		} else {
-			successMap[r.name] = r.output
+	return s.collect(outcomes)
+}
+
+// notStarted records a branch that was still waiting for a concurrency slot
+// when the step was cancelled.
+func (s *ParallelStep) notStarted(ctx context.Context, branch *parallelBranch, err error, mu *sync.Mutex, cancelledRest *bool) branchOutcome {
+	mu.Lock()
+	cancelled := *cancelledRest && ctx.Err() == nil
+	mu.Unlock()
+	recordNestedEvent(ctx, "step.skipped", map[string]any{
+		"step_name":   s.name + "." + branch.name,
+		"parent_step": s.name,
+		"branch":      branch.name,
+		"reason":      "cancelled before start",
+	})
+	return branchOutcome{err: err, cancelled: cancelled}
+}
+
+// runBranch runs branch's steps in order on pc, recording the branch as a
+// nested step. A step that asks to stop ends the branch, not the pipeline.
+func (s *ParallelStep) runBranch(ctx context.Context, branch *parallelBranch, pc *PipelineContext) (out branchOutcome) {
+	eventName := s.name + "." + branch.name
+	recordNestedEvent(ctx, "step.started", map[string]any{
+		"step_name":   eventName,
+		"step_type":   "parallel.branch",
+		"parent_step": s.name,
+		"branch":      branch.name,
+	})
+	start := time.Now()
+	defer func() {
+		if rec := recover(); rec != nil {
+			out = branchOutcome{err: fmt.Errorf("panic in parallel branch %q: %v", branch.name, rec)}
+		}
+		data := map[string]any{
+			"step_name":   eventName,
+			"parent_step": s.name,
+			"branch":      branch.name,
+			"elapsed":     time.Since(start).String(),
+		}
+		if out.err != nil {
+			data["error"] = out.err.Error()
+			recordNestedEvent(ctx, "step.failed", data)
+			return
 		}
+		recordNestedEvent(ctx, "step.completed", data)
+	}()
+
+	branchCtx := ctx
+	if branch.timeout > 0 {
+		var cancel context.CancelFunc
+		branchCtx, cancel = context.WithTimeout(ctx, branch.timeout)
+		defer cancel()
 	}
 
-	completed := len(successMap)
-	failed := len(errorMap)
+	output := make(map[string]any)
+	for _, step := range branch.steps {
+		result, err := step.Execute(branchCtx, pc)
+		if err != nil {
+			if ctx.Err() == nil && errors.Is(branchCtx.Err(), context.DeadlineExceeded) {
+				err = fmt.Errorf("timed out after %s: %w", branch.timeout, err)
+			}
+			if len(branch.steps) > 1 {
+				err = fmt.Errorf("step %q failed: %w", step.Name(), err)
+			}
+			return branchOutcome{err: err}
+		}
+		if result == nil || result.Skipped {
+			continue
+		}
+		stepOut := result.Output
+		if stepOut == nil {
+			stepOut = map[string]any{}
+		}
+		pc.MergeStepOutput(step.Name(), stepOut)
+		maps.Copy(output, stepOut)
+		if result.Stop {
+			break
+		}
+	}
+	return branchOutcome{output: output}
+}
 
-	if s.errorStrategy == "fail_fast" && firstErr != nil {
-		return nil, firstErr
+// collect builds the step result from the branch outcomes, which are in
+// branch order.
+func (s *ParallelStep) collect(outcomes []branchOutcome) (*StepResult, error) {
+	successMap := make(map[string]any)
+	errorMap := make(map[string]any)
+	cancelled := []string{}
+	var failures []ParallelBranchFailure
+	for i, o := range outcomes {
+		name := s.branches[i].name
+		switch {
+		case o.cancelled:
+			cancelled = append(cancelled, name)
+		case o.err != nil:
+			errorMap[name] = o.err.Error()
+			failures = append(failures, ParallelBranchFailure{Branch: name, Err: o.err})
+		default:
+			successMap[name] = o.output
+		}
 	}
+	slices.SortFunc(failures, func(a, b ParallelBranchFailure) int { return strings.Compare(a.Branch, b.Branch) })
+	slices.Sort(cancelled)
 
-	if s.errorStrategy == "collect_errors" && failed == n {
-		return nil, fmt.Errorf("parallel step %q: all %d branches failed", s.name, n)
+	if len(failures) > 0 && (s.errorStrategy == "fail_fast" || len(successMap) == 0) {
+		return nil, &ParallelError{Step: s.name, Branches: len(s.branches), Failed: failures, Cancelled: cancelled}
 	}
 
-	return &StepResult{
-		Output: map[string]any{
-			"results":   successMap,
-			"errors":    errorMap,
-			"completed": completed,
-			"failed":    failed,
-		},
-	}, nil
+	output := map[string]any{
+		"results":   successMap,
+		"errors":    errorMap,
+		"cancelled": cancelled,
+		"completed": len(successMap),
+		"failed":    len(errorMap),
+	}
+	if s.topLevel {
+		maps.Copy(output, successMap)
+	}
+	return &StepResult{Output: output}, nil
 }
 
 // deepCopyValue recursively copies maps and slices so that goroutines operating
@@ -236,10 +523,12 @@ func buildParallelChildContext(parent *PipelineContext) *PipelineContext {
 	}
 
 	return &PipelineContext{
-		TriggerData: childTrigger,
-		StepOutputs: childOutputs,
-		Current:     childCurrent,
-		Metadata:    childMeta,
+		TriggerData:     childTrigger,
+		StepOutputs:     childOutputs,
+		Current:         childCurrent,
+		Metadata:        childMeta,
+		StrictTemplates: parent.StrictTemplates,
+		Logger:          parent.Logger,
 	}
 }
 
@@ -249,9 +538,18 @@ func buildParallelStepDirect(name string, steps []PipelineStep, errorStrategy st
 	if errorStrategy == "" {
 		errorStrategy = "fail_fast"
 	}
+	firstError := parallelWaitAll
+	if errorStrategy == "fail_fast" {
+		firstError = parallelCancelRest
+	}
+	branches := make([]parallelBranch, len(steps))
+	for i, step := range steps {
+		branches[i] = parallelBranch{name: step.Name(), steps: []PipelineStep{step}}
+	}
 	return &ParallelStep{
 		name:          name,
-		subSteps:      steps,
+		branches:      branches,
 		errorStrategy: errorStrategy,
+		firstError:    firstError,
 	}, nil
 }
@@ -2,7 +2,9 @@ package module
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -425,3 +427,258 @@ func TestPanicRecoveryParallelStepFailFast(t *testing.T) {
 		t.Fatal("fail_fast mode: expected error from panicking branch, got nil")
 	}
 }
+
+// parallelFuncStep runs fn as its Execute.
+type parallelFuncStep struct {
+	name string
+	fn   func(ctx context.Context, pc *PipelineContext) (*StepResult, error)
+}
+
+func (s *parallelFuncStep) Name() string { return s.name }
+func (s *parallelFuncStep) Execute(ctx context.Context, pc *PipelineContext) (*StepResult, error) {
+	return s.fn(ctx, pc)
+}
+
+func TestParallelStep_Branches(t *testing.T) {
+	query := &parallelSuccessStep{name: "query", output: map[string]any{"rows": []any{1, 2}, "count": 2}}
+	total := &parallelFuncStep{name: "total", fn: func(_ context.Context, pc *PipelineContext) (*StepResult, error) {
+		pc.Current["scratch"] = true
+		return &StepResult{Output: map[string]any{"count": pc.StepOutputs["query"]["count"].(int) * 10}}, nil
+	}}
+	weather := &parallelSuccessStep{name: "call", output: map[string]any{"temp": 21}}
+	factory := NewParallelStepFactory(buildParallelRegistry(map[string]PipelineStep{
+		"mock.query": query, "mock.total": total, "mock.call": weather,
+	}))
+
+	step, err := factory("dashboard", map[string]any{
+		"branches": map[string]any{
+			"orders": []any{
+				map[string]any{"name": "query", "type": "mock.query"},
+				map[string]any{"name": "total", "type": "mock.total"},
+			},
+			"weather": map[string]any{
+				"timeout": "1s",
+				"steps":   []any{map[string]any{"name": "call", "type": "mock.call"}},
+			},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pc := NewPipelineContext(map[string]any{}, nil)
+	result, err := step.Execute(context.Background(), pc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orders := result.Output["orders"].(map[string]any)
+	if orders["count"] != 20 || orders["rows"] == nil {
+		t.Errorf("orders = %v, want the merged outputs of query and total", orders)
+	}
+	if result.Output["weather"].(map[string]any)["temp"] != 21 {
+		t.Errorf("weather = %v", result.Output["weather"])
+	}
+	if _, leaked := pc.Current["scratch"]; leaked {
+		t.Error("branch write leaked into the pipeline context")
+	}
+	if _, leaked := pc.StepOutputs["query"]; leaked {
+		t.Error("branch step output leaked into the pipeline context")
+	}
+}
+
+func TestParallelStep_WaitAllAggregatesErrors(t *testing.T) {
+	ok := &parallelContextCheckStep{name: "ok", delay: 50 * time.Millisecond}
+	factory := NewParallelStepFactory(buildParallelRegistry(map[string]PipelineStep{
+		"mock.ok":    ok,
+		"mock.fail1": &parallelFailStep{name: "fail1"},
+		"mock.fail2": &parallelFailStep{name: "fail2", delay: 10 * time.Millisecond},
+	}))
+	step, err := factory("par", map[string]any{
+		"branches": map[string]any{
+			"zeta":  []any{map[string]any{"type": "mock.fail1"}},
+			"alpha": []any{map[string]any{"type": "mock.fail2"}},
+			"slow":  []any{map[string]any{"type": "mock.ok"}},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = step.Execute(context.Background(), NewPipelineContext(nil, nil))
+	var perr *ParallelError
+	if !errors.As(err, &perr) {
+		t.Fatalf("err = %v, want a *ParallelError", err)
+	}
+	want := `parallel step "par": 2 of 3 branches failed: alpha: step "fail2" failed; zeta: step "fail1" failed`
+	if err.Error() != want {
+		t.Errorf("err = %q\nwant %q", err.Error(), want)
+	}
+	if ok.cancelled.Load() {
+		t.Error("wait_all cancelled the remaining branch")
+	}
+}
+
+func TestParallelStep_CancelRest(t *testing.T) {
+	slow := &parallelContextCheckStep{name: "slow", delay: 5 * time.Second}
+	factory := NewParallelStepFactory(buildParallelRegistry(map[string]PipelineStep{
+		"mock.slow": slow, "mock.fail": &parallelFailStep{name: "fail"},
+	}))
+	step, err := factory("par", map[string]any{
+		"first_error": "cancel_rest",
+		"branches": map[string]any{
+			"a": []any{map[string]any{"type": "mock.slow"}},
+			"b": []any{map[string]any{"type": "mock.fail"}},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	_, err = step.Execute(context.Background(), NewPipelineContext(nil, nil))
+	if time.Since(start) > time.Second {
+		t.Fatal("cancel_rest waited for the slow branch")
+	}
+	var perr *ParallelError
+	if !errors.As(err, &perr) {
+		t.Fatalf("err = %v, want a *ParallelError", err)
+	}
+	if len(perr.Failed) != 1 || perr.Failed[0].Branch != "b" || len(perr.Cancelled) != 1 || perr.Cancelled[0] != "a" {
+		t.Errorf("failed = %v, cancelled = %v", perr.Failed, perr.Cancelled)
+	}
+	if !slow.cancelled.Load() {
+		t.Error("slow branch was not cancelled")
+	}
+}
+
+func TestParallelStep_BranchTimeout(t *testing.T) {
+	factory := NewParallelStepFactory(buildParallelRegistry(map[string]PipelineStep{
+		"mock.slow": &parallelContextCheckStep{name: "slow", delay: 5 * time.Second},
+		"mock.fast": &parallelSuccessStep{name: "fast", output: map[string]any{"v": 1}},
+	}))
+	step, err := factory("par", map[string]any{
+		"error_strategy": "collect_errors",
+		"timeout":        "5s",
+		"branches": map[string]any{
+			"slow": map[string]any{"timeout": "20ms", "steps": []any{map[string]any{"type": "mock.slow"}}},
+			"fast": []any{map[string]any{"type": "mock.fast"}},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := step.Execute(context.Background(), NewPipelineContext(nil, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, _ := result.Output["errors"].(map[string]any)["slow"].(string)
+	if !strings.Contains(msg, "timed out after 20ms") {
+		t.Errorf("slow branch error = %q, want a timeout", msg)
+	}
+	if result.Output["completed"] != 1 || result.Output["fast"] == nil {
+		t.Errorf("output = %v, want the fast branch to complete", result.Output)
+	}
+}
+
+func TestParallelStep_MaxConcurrency(t *testing.T) {
+	var running, peak atomic.Int32
+	track := &parallelFuncStep{name: "track", fn: func(ctx context.Context, _ *PipelineContext) (*StepResult, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		return &StepResult{Output: map[string]any{}}, nil
+	}}
+	factory := NewParallelStepFactory(buildParallelRegistry(map[string]PipelineStep{"mock.track": track}))
+	branches := map[string]any{}
+	for _, b := range []string{"a", "b", "c", "d", "e"} {
+		branches[b] = []any{map[string]any{"type": "mock.track"}}
+	}
+	step, err := factory("par", map[string]any{"max_concurrency": 2, "branches": branches}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := step.Execute(context.Background(), NewPipelineContext(nil, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if peak.Load() > 2 {
+		t.Errorf("peak concurrency = %d, want at most 2", peak.Load())
+	}
+	if result.Output["completed"] != 5 {
+		t.Errorf("completed = %v, want 5", result.Output["completed"])
+	}
+}
+
+func TestParallelStep_BranchEvents(t *testing.T) {
+	step, err := NewParallelStepFactory(buildParallelRegistry(map[string]PipelineStep{
+		"mock.quick": &parallelSuccessStep{name: "quick", output: map[string]any{}},
+		"mock.slow":  &parallelSuccessStep{name: "slow", output: map[string]any{}, delay: 60 * time.Millisecond},
+	}))("fanout", map[string]any{
+		"branches": map[string]any{
+			"quick": []any{map[string]any{"type": "mock.quick"}},
+			"slow":  []any{map[string]any{"type": "mock.slow"}},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := &mockEventRecorder{}
+	p := &Pipeline{Name: "dash", Steps: []PipelineStep{step}, EventRecorder: rec, ExecutionID: "exec-1"}
+	if _, err := p.Execute(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	elapsed := map[string]time.Duration{}
+	started := map[string]bool{}
+	for _, ev := range rec.getEvents() {
+		name, _ := ev.Data["step_name"].(string)
+		if ev.Data["parent_step"] != "fanout" {
+			continue
+		}
+		switch ev.EventType {
+		case "step.started":
+			started[name] = true
+			if ev.Data["step_type"] != "parallel.branch" {
+				t.Errorf("%s step_type = %v", name, ev.Data["step_type"])
+			}
+		case "step.completed":
+			d, _ := time.ParseDuration(ev.Data["elapsed"].(string))
+			elapsed[name] = d
+		}
+	}
+	if !started["fanout.quick"] || !started["fanout.slow"] {
+		t.Fatalf("started branches = %v", started)
+	}
+	if elapsed["fanout.slow"] < 60*time.Millisecond || elapsed["fanout.quick"] >= 60*time.Millisecond {
+		t.Errorf("branch durations = %v, want each branch timed on its own", elapsed)
+	}
+}
+
+func TestParallelStep_BranchesConfigErrors(t *testing.T) {
+	factory := NewParallelStepFactory(buildParallelRegistry(map[string]PipelineStep{
+		"mock.a": &parallelSuccessStep{name: "a"},
+	}))
+	branch := []any{map[string]any{"type": "mock.a"}}
+	cases := map[string]map[string]any{
+		"reserved name":   {"branches": map[string]any{"results": branch}},
+		"both forms":      {"branches": map[string]any{"x": branch}, "steps": []any{map[string]any{"name": "a", "type": "mock.a"}}},
+		"empty branch":    {"branches": map[string]any{"x": []any{}}},
+		"bad first_error": {"first_error": "sometimes", "branches": map[string]any{"x": branch}},
+		"cancel collect":  {"first_error": "cancel_rest", "error_strategy": "collect_errors", "branches": map[string]any{"x": branch}},
+		"bad timeout":     {"branches": map[string]any{"x": map[string]any{"timeout": "soon", "steps": branch}}},
+		"bad concurrency": {"max_concurrency": -1, "branches": map[string]any{"x": branch}},
+	}
+	for name, cfg := range cases {
+		if _, err := factory("par", cfg, nil); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package module
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/GoCodeAlone/modular"
+)
+
+// PDFRenderStep renders an HTML template against the pipeline context and
+// converts the result to a PDF. The PDF bytes are written to a context key
+// or sent as the HTTP response.
+type PDFRenderStep struct {
+	name        string
+	template    string
+	opts        PDFPageOptions
+	output      string
+	outputKey   string
+	filename    string
+	disposition string
+	tmpl        *TemplateEngine
+}
+
+// NewPDFRenderStepFactory returns a StepFactory that creates PDFRenderStep instances.
+func NewPDFRenderStepFactory() StepFactory {
+	return func(name string, config map[string]any, _ modular.Application) (PipelineStep, error) {
+		tmplStr, _ := config["template"].(string)
+		tmplFile, _ := config["template_file"].(string)
+		switch {
+		case tmplStr == "" && tmplFile == "":
+			return nil, fmt.Errorf("pdf_render step %q: 'template' or 'template_file' is required", name)
+		case tmplStr != "" && tmplFile != "":
+			return nil, fmt.Errorf("pdf_render step %q: 'template' and 'template_file' are mutually exclusive", name)
+		}
+
+		assetsDir, _ := config["assets_dir"].(string)
+		if tmplFile != "" {
+			data, err := os.ReadFile(tmplFile) //nolint:gosec // G304: path from trusted pipeline config
+			if err != nil {
+				return nil, fmt.Errorf("pdf_render step %q: failed to read template_file: %w", name, err)
+			}
+			tmplStr = string(data)
+			if assetsDir == "" {
+				assetsDir = filepath.Dir(tmplFile)
+			}
+		}
+
+		opts := PDFPageOptions{AssetsDir: assetsDir}
+		opts.PageSize, _ = config["page_size"].(string)
+		if opts.PageSize != "" {
+			if _, ok := pdfPageSizes[strings.ToLower(opts.PageSize)]; !ok {
+				return nil, fmt.Errorf("pdf_render step %q: unsupported page_size %q (expected A3, A4, A5, Letter, Legal, or Tabloid)", name, opts.PageSize)
+			}
+		}
+		switch orientation, _ := config["orientation"].(string); orientation {
+		case "", "portrait":
+		case "landscape":
+			opts.Landscape = true
+		default:
+			return nil, fmt.Errorf("pdf_render step %q: orientation must be 'portrait' or 'landscape'", name)
+		}
+		if err := parsePDFMargins(config["margins"], &opts); err != nil {
+			return nil, fmt.Errorf("pdf_render step %q: %w", name, err)
+		}
+
+		output, _ := config["output"].(string)
+		switch output {
+		case "":
+			output = "context"
+		case "context", "response":
+		default:
+			return nil, fmt.Errorf("pdf_render step %q: output must be 'context' or 'response'", name)
+		}
+		outputKey, _ := config["output_key"].(string)
+		if outputKey == "" {
+			outputKey = "pdf"
+		}
+		filename, _ := config["filename"].(string)
+		if filename == "" {
+			filename = "document.pdf"
+		}
+		disposition, _ := config["disposition"].(string)
+		switch disposition {
+		case "":
+			disposition = "attachment"
+		case "attachment", "inline":
+		default:
+			return nil, fmt.Errorf("pdf_render step %q: disposition must be 'attachment' or 'inline'", name)
+		}
+
+		return &PDFRenderStep{
+			name:        name,
+			template:    tmplStr,
+			opts:        opts,
+			output:      output,
+			outputKey:   outputKey,
+			filename:    filename,
+			disposition: disposition,
+			tmpl:        NewTemplateEngine(),
+		}, nil
+	}
+}
+
+// parsePDFMargins reads margins in millimetres: a single number for all
+// sides or a map with top/right/bottom/left. Unset sides default to 15mm.
+func parsePDFMargins(raw any, opts *PDFPageOptions) error {
+	const defaultMargin = 15.0
+	opts.MarginTop, opts.MarginRight, opts.MarginBottom, opts.MarginLeft = defaultMargin, defaultMargin, defaultMargin, defaultMargin
+	if raw == nil {
+		return nil
+	}
+	if v, ok := pdfNumber(raw); ok {
+		opts.MarginTop, opts.MarginRight, opts.MarginBottom, opts.MarginLeft = v, v, v, v
+		return nil
+	}
+	m, ok := raw.(map[string]any)
+	if !ok {
+		return fmt.Errorf("'margins' must be a number or a map of top/right/bottom/left")
+	}
+	for side, dst := range map[string]*float64{
+		"top": &opts.MarginTop, "right": &opts.MarginRight, "bottom": &opts.MarginBottom, "left": &opts.MarginLeft,
+	} {
+		if rv, set := m[side]; set {
+			v, ok := pdfNumber(rv)
+			if !ok {
+				return fmt.Errorf("margin %q must be a non-negative number", side)
+			}
+			*dst = v
+		}
+	}
+	return nil
+}
+
+func pdfNumber(v any) (float64, bool) {
+	var f float64
+	switch n := v.(type) {
+	case int:
+		f = float64(n)
+	case float64:
+		f = n
+	case string:
+		var err error
+		if f, err = strconv.ParseFloat(strings.TrimSuffix(n, "mm"), 64); err != nil {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+	return f, f >= 0
+}
+
+// Name returns the step name.
+func (s *PDFRenderStep) Name() string { return s.name }
+
+// Execute renders the template and produces the PDF.
+func (s *PDFRenderStep) Execute(_ context.Context, pc *PipelineContext) (*StepResult, error) {
+	source, err := s.tmpl.Resolve(s.template, pc)
+	if err != nil {
+		return nil, fmt.Errorf("pdf_render step %q: failed to resolve template: %w", s.name, err)
+	}
+	doc, pages, err := RenderHTMLToPDF(source, s.opts)
+	if err != nil {
+		return nil, fmt.Errorf("pdf_render step %q: %w", s.name, err)
+	}
+	filename, err := s.tmpl.Resolve(s.filename, pc)
+	if err != nil {
+		return nil, fmt.Errorf("pdf_render step %q: failed to resolve filename: %w", s.name, err)
+	}
+
+	output := map[string]any{
+		"content_type": "application/pdf",
+		"filename":     filename,
+		"size":         len(doc),
+		"pages":        pages,
+	}
+
+	w, ok := pc.Metadata["_http_response_writer"].(http.ResponseWriter)
+	if s.output != "response" || !ok {
+		// Without a response writer the document is returned in the context,
+		// as with output: context.
+		output[s.outputKey] = doc
+		return &StepResult{Output: output}, nil
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Length", strconv.Itoa(len(doc)))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("%s; filename=%q", s.disposition, sanitizeFilename(filename)))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(doc); err != nil {
+		return nil, fmt.Errorf("pdf_render step %q: failed to write response: %w", s.name, err)
+	}
+	pc.Metadata["_response_handled"] = true
+	return &StepResult{Output: output, Stop: true}, nil
+}
+
+// sanitizeFilename strips characters that would break a quoted
+// Content-Disposition filename.
+func sanitizeFilename(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '"' || r == '\\' || r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, filepath.Base(name))
+}
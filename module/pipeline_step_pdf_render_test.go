@@ -0,0 +1,165 @@
+package module
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 40, 20))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+const invoiceTemplate = `<html><head><title>Invoice {{ .number }}</title></head><body>
+<h1 style="text-align: right">Invoice {{ .number }}</h1>
+<p>Bill to: <b>{{ .customer }}</b><br>Due: <i>30 days</i></p>
+<img src="{{ .logo }}" width="120">
+<table>
+  <thead><tr><th width="70%">Item</th><th style="text-align:right">Amount</th></tr></thead>
+  <tbody>{{ range .items }}<tr><td>{{ .name }}</td><td align="right">{{ .amount }}</td></tr>{{ end }}</tbody>
+</table>
+<ul><li>Thank you</li><li>Pay via <a href="https://pay.example.com">pay.example.com</a></li></ul>
+<hr><ol start="3"><li>three</li></ol><pre>  preformatted
+  text</pre>
+</body></html>`
+
+func TestPDFRenderStep_ContextOutput(t *testing.T) {
+	step, err := NewPDFRenderStepFactory()("invoice", map[string]any{
+		"template":   invoiceTemplate,
+		"page_size":  "letter",
+		"margins":    map[string]any{"top": 20, "left": 10.5},
+		"output_key": "invoice_pdf",
+		"filename":   "invoice-{{ .number }}.pdf",
+	}, nil)
+	if err != nil {
+		t.Fatalf("factory error: %v", err)
+	}
+
+	pc := NewPipelineContext(map[string]any{
+		"number":   "INV-7",
+		"customer": "Acme Ltd",
+		"logo":     "data:image/png;base64," + base64.StdEncoding.EncodeToString(testPNG(t)),
+		"items":    []any{map[string]any{"name": "Widget", "amount": "10.00"}},
+	}, nil)
+	result, err := step.Execute(context.Background(), pc)
+	if err != nil {
+		t.Fatalf("execute error: %v", err)
+	}
+	doc, ok := result.Output["invoice_pdf"].([]byte)
+	if !ok || !bytes.HasPrefix(doc, []byte("%PDF-")) {
+		t.Fatalf("expected PDF bytes under invoice_pdf, got %T", result.Output["invoice_pdf"])
+	}
+	if result.Output["filename"] != "invoice-INV-7.pdf" || result.Output["pages"] != 1 || result.Output["size"] != len(doc) {
+		t.Errorf("unexpected output: %v", result.Output)
+	}
+	if result.Stop {
+		t.Error("context output should not stop the pipeline")
+	}
+}
+
+func TestPDFRenderStep_ResponseOutput(t *testing.T) {
+	step, err := NewPDFRenderStepFactory()("download", map[string]any{
+		"template": "<p>Receipt {{ .id }}</p>",
+		"output":   "response",
+		"filename": "receipt-{{ .id }}.pdf",
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	pc := NewPipelineContext(map[string]any{"id": "r1"}, map[string]any{"_http_response_writer": rec})
+	result, err := step.Execute(context.Background(), pc)
+	if err != nil {
+		t.Fatalf("execute error: %v", err)
+	}
+	if !result.Stop || pc.Metadata["_response_handled"] != true {
+		t.Error("response output should stop the pipeline and mark the response handled")
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/pdf" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if cd := rec.Header().Get("Content-Disposition"); cd != `attachment; filename="receipt-r1.pdf"` {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+	if !bytes.HasPrefix(rec.Body.Bytes(), []byte("%PDF-")) {
+		t.Error("expected PDF body")
+	}
+	if _, ok := result.Output["pdf"]; ok {
+		t.Error("response output should not copy the document into the context")
+	}
+}
+
+func TestPDFRenderStep_TemplateFileAndAssets(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "logo.png"), testPNG(t), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tmplPath := filepath.Join(dir, "doc.html")
+	if err := os.WriteFile(tmplPath, []byte(`<img src="{{ .img }}">`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	step, err := NewPDFRenderStepFactory()("doc", map[string]any{"template_file": tmplPath}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := step.Execute(context.Background(), NewPipelineContext(map[string]any{"img": "logo.png"}, nil)); err != nil {
+		t.Fatalf("expected image from the template's directory: %v", err)
+	}
+	// Paths are confined to the assets directory.
+	_, err = step.Execute(context.Background(), NewPipelineContext(map[string]any{"img": "../../etc/passwd.png"}, nil))
+	if err == nil || !strings.Contains(err.Error(), filepath.Join(dir, "etc", "passwd.png")) {
+		t.Errorf("expected confined lookup error, got %v", err)
+	}
+	_, err = step.Execute(context.Background(), NewPipelineContext(map[string]any{"img": "https://example.com/a.png"}, nil))
+	if err == nil || !strings.Contains(err.Error(), "remote images") {
+		t.Errorf("expected remote image error, got %v", err)
+	}
+}
+
+func TestRenderHTMLToPDF_PageBreaksAndLongTables(t *testing.T) {
+	var rows strings.Builder
+	for i := 0; i < 120; i++ {
+		rows.WriteString("<tr><td>row</td><td>value</td></tr>")
+	}
+	src := `<table><thead><tr><th>Name</th><th>Value</th></tr></thead><tbody>` + rows.String() + `</tbody></table>` +
+		`<div style="page-break-before: always">last page</div>`
+	doc, pages, err := RenderHTMLToPDF(src, PDFPageOptions{PageSize: "A5", Landscape: true, MarginTop: 10, MarginBottom: 10, MarginLeft: 10, MarginRight: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pages < 4 || !bytes.HasPrefix(doc, []byte("%PDF-")) {
+		t.Errorf("expected a multi-page PDF, got %d pages", pages)
+	}
+}
+
+func TestPDFRenderStep_FactoryErrors(t *testing.T) {
+	factory := NewPDFRenderStepFactory()
+	for _, cfg := range []map[string]any{
+		{},
+		{"template": "x", "template_file": "y"},
+		{"template_file": "/nonexistent/template.html"},
+		{"template": "x", "page_size": "B7"},
+		{"template": "x", "orientation": "sideways"},
+		{"template": "x", "margins": "wide"},
+		{"template": "x", "margins": map[string]any{"top": -1}},
+		{"template": "x", "output": "s3"},
+		{"template": "x", "disposition": "download"},
+	} {
+		if _, err := factory("pdf", cfg, nil); err == nil {
+			t.Errorf("expected factory error for %v", cfg)
+		}
+	}
+}
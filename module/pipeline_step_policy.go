@@ -2,7 +2,10 @@ package module
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"strings"
 
 	"github.com/GoCodeAlone/modular"
 )
@@ -262,3 +265,124 @@ func (s *PolicyTestStep) Execute(ctx context.Context, _ *PipelineContext) (*Step
 
 	return result, nil
 }
+
+// ─── step.policy_check ──────────────────────────────────────────────────────
+
+// PolicyCheckStep evaluates a named policy of a PolicyChecker backend, such
+// as a policy.service module, against the pipeline context.
+type PolicyCheckStep struct {
+	name       string
+	engineName string
+	policy     string
+	input      map[string]any
+	inputFrom  string
+	onDeny     string
+	tmpl       *TemplateEngine
+	app        modular.Application
+}
+
+// NewPolicyCheckStepFactory returns a StepFactory for step.policy_check.
+func NewPolicyCheckStepFactory() StepFactory {
+	return func(name string, cfg map[string]any, app modular.Application) (PipelineStep, error) {
+		engineName, _ := cfg["engine"].(string)
+		if engineName == "" {
+			return nil, fmt.Errorf("policy_check step %q: 'engine' is required", name)
+		}
+		policy, _ := cfg["policy"].(string)
+		if policy == "" {
+			return nil, fmt.Errorf("policy_check step %q: 'policy' is required", name)
+		}
+		input, _ := cfg["input"].(map[string]any)
+		inputFrom, _ := cfg["input_from"].(string)
+		onDeny, _ := cfg["on_deny"].(string)
+		switch onDeny {
+		case "":
+			onDeny = "stop"
+		case "stop", "forbidden", "error", "continue":
+		default:
+			return nil, fmt.Errorf("policy_check step %q: on_deny must be stop, forbidden, error or continue", name)
+		}
+		return &PolicyCheckStep{
+			name:       name,
+			engineName: engineName,
+			policy:     policy,
+			input:      input,
+			inputFrom:  inputFrom,
+			onDeny:     onDeny,
+			tmpl:       NewTemplateEngine(),
+			app:        app,
+		}, nil
+	}
+}
+
+func (s *PolicyCheckStep) Name() string { return s.name }
+
+// Execute evaluates the policy. The input is the resolved 'input' map when
+// configured, else the map at pc.Current[input_from], else pc.Current.
+func (s *PolicyCheckStep) Execute(ctx context.Context, pc *PipelineContext) (*StepResult, error) {
+	eng, err := resolvePolicyEngine(s.app, s.engineName, s.name)
+	if err != nil {
+		return nil, err
+	}
+	checker, ok := eng.Engine().(PolicyChecker)
+	if !ok {
+		return nil, fmt.Errorf("policy_check step %q: %s backend of %q does not evaluate named policies", s.name, eng.Backend(), s.engineName)
+	}
+
+	var input map[string]any
+	switch {
+	case s.input != nil:
+		if input, err = s.tmpl.ResolveMap(s.input, pc); err != nil {
+			return nil, fmt.Errorf("policy_check step %q: resolve input: %w", s.name, err)
+		}
+	case s.inputFrom != "":
+		input, _ = pc.Current[s.inputFrom].(map[string]any)
+	}
+	if input == nil {
+		input = pc.Current
+	}
+
+	decision, err := checker.EvaluatePolicy(ctx, s.policy, input)
+	if err != nil {
+		return nil, fmt.Errorf("policy_check step %q: %w", s.name, err)
+	}
+	if !decision.Allowed {
+		switch s.onDeny {
+		case "error":
+			return nil, fmt.Errorf("policy_check step %q: %s denied: %s", s.name, s.policy, strings.Join(decision.Reasons, "; "))
+		case "forbidden":
+			return policyForbiddenResponse(pc, s.name, decision)
+		}
+	}
+
+	return &StepResult{
+		Output: map[string]any{
+			"allowed":  decision.Allowed,
+			"reasons":  decision.Reasons,
+			"metadata": decision.Metadata,
+			"policy":   s.policy,
+			"engine":   s.engineName,
+		},
+		Stop: !decision.Allowed && s.onDeny == "stop",
+	}, nil
+}
+
+// policyForbiddenResponse writes a 403 problem listing the denial reasons
+// and stops the pipeline.
+func policyForbiddenResponse(pc *PipelineContext, stepName string, decision *PolicyDecision) (*StepResult, error) {
+	p := NewProblem(ProblemForbidden, strings.Join(decision.Reasons, "; "))
+	writeStepProblem(pc, stepName, p)
+	body, _ := json.Marshal(p)
+	return &StepResult{
+		Output: map[string]any{
+			"allowed":         false,
+			"reasons":         decision.Reasons,
+			"response_status": http.StatusForbidden,
+			"response_body":   string(body),
+			"response_headers": map[string]string{
+				"Content-Type": ProblemContentType,
+			},
+		},
+		Stop: true,
+	}, nil
+}
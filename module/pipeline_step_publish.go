@@ -3,8 +3,10 @@ package module
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 
 	"github.com/GoCodeAlone/modular"
 	"github.com/GoCodeAlone/modular/modules/eventbus/v2"
@@ -86,6 +88,12 @@ func (s *PublishStep) publishViaBroker(_ context.Context, topic string, payload
 	if err != nil {
 		return nil, fmt.Errorf("publish step %q: failed to marshal payload: %w", s.name, err)
 	}
+	// Brokers with the topic registry attached validate in their producer.
+	if aware, ok := broker.(TopicRegistryAware); !ok || aware.TopicRegistry() == nil {
+		if err := s.validatePayload(topic, data); err != nil {
+			return nil, err
+		}
+	}
 
 	if err := broker.Producer().SendMessage(topic, data); err != nil {
 		return nil, fmt.Errorf("publish step %q: failed to publish via broker: %w", s.name, err)
@@ -103,9 +111,55 @@ func (s *PublishStep) publishViaEventBus(ctx context.Context, topic string, payl
 		return &StepResult{Output: map[string]any{"published": false, "reason": "eventbus not available"}}, nil
 	}
 
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("publish step %q: failed to marshal payload: %w", s.name, err)
+	}
+	if err := s.validatePayload(topic, data); err != nil {
+		return nil, err
+	}
+
 	if err := eb.Publish(ctx, topic, payload); err != nil {
 		return nil, fmt.Errorf("publish step %q: failed to publish to eventbus: %w", s.name, err)
 	}
 
 	return &StepResult{Output: map[string]any{"published": true, "topic": topic}}, nil
 }
+
+// validatePayload checks data against the topic's schema in the app's
+// messaging.topic_registry, if one is configured.
+func (s *PublishStep) validatePayload(topic string, data []byte) error {
+	registry, err := FindByInterface[*TopicSchemaRegistry](s.app.SvcRegistry())
+	if errors.Is(err, ErrServiceNotFound) {
+		return nil
+	}
+	if err := registry.Service.ValidatePublish(topic, data); err != nil {
+		return fmt.Errorf("publish step %q: %w", s.name, err)
+	}
+	return nil
+}
+
+// DryRunPreview returns the resolved topic and payload the step would
+// publish, and the broker it would publish through, without publishing.
+func (s *PublishStep) DryRunPreview(_ context.Context, pc *PipelineContext) (map[string]any, error) {
+	resolvedTopic, err := s.tmpl.Resolve(s.topic, pc)
+	if err != nil {
+		return nil, fmt.Errorf("publish step %q: failed to resolve topic: %w", s.name, err)
+	}
+	// Copy the current data: the preview is merged back into pc.Current.
+	resolvedPayload := maps.Clone(pc.Current)
+	if s.payload != nil {
+		resolvedPayload, err = s.tmpl.ResolveMap(s.payload, pc)
+		if err != nil {
+			return nil, fmt.Errorf("publish step %q: failed to resolve payload: %w", s.name, err)
+		}
+	}
+	preview := map[string]any{
+		"topic":   resolvedTopic,
+		"payload": resolvedPayload,
+	}
+	if s.broker != "" {
+		preview["broker"] = s.broker
+	}
+	return preview, nil
+}
@@ -170,7 +170,9 @@ func (s *RateLimitStep) Execute(_ context.Context, pc *PipelineContext) (*StepRe
 	s.mu.Unlock()
 
 	if !allowed {
-		return nil, fmt.Errorf("rate_limit step %q: rate limit exceeded for key %q", s.name, key)
+		// A *Problem makes the route answer 429 rate_limited, not 500.
+		return nil, fmt.Errorf("rate_limit step %q: %w", s.name,
+			NewProblem(ProblemRateLimited, fmt.Sprintf("rate limit exceeded for key %q", key)))
 	}
 
 	return &StepResult{
@@ -0,0 +1,246 @@
+package module
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"time"
+
+	"github.com/GoCodeAlone/modular"
+)
+
+// RetryStep runs an inline sub-pipeline and re-runs the whole block when an
+// attempt fails, with exponential backoff and optional jitter between
+// attempts. Unlike step.retry_with_backoff, which wraps a single step, it
+// retries a sequence such as parse → call → transform as a unit.
+//
+// Every attempt starts from the context the step was entered with: outputs
+// of a failed attempt are discarded, and only the successful attempt's step
+// outputs are merged into the pipeline context. Side effects are not undone,
+// so a child that writes (publishes, inserts, calls a non-idempotent API)
+// repeats those writes on every retry.
+//
+// Without retry_if, an attempt is retried when a child step fails. With
+// retry_if, the expression is evaluated after every attempt and the block is
+// retried while it is truthy; it sees the attempt's step outputs plus
+// .retry.attempt, .retry.failed and .retry.error.
+//
+//	type: step.retry
+//	name: fetch-quote
+//	config:
+//	  max_attempts: 4
+//	  initial_delay: 200ms
+//	  max_delay: 5s
+//	  jitter: 0.2
+//	  retry_if: '{{ or .retry.failed (eq .steps.call.status_code 503) }}'
+//	  steps:
+//	    - type: step.http_call
+//	      name: call
+//	      config: { url: "https://quotes.example.com/latest" }
+//	    - type: step.json_parse
+//	      name: parse
+//	      config: { source: steps.call.body }
+type RetryStep struct {
+	name         string
+	steps        []PipelineStep
+	maxAttempts  int
+	initialDelay time.Duration
+	maxDelay     time.Duration
+	multiplier   float64
+	jitter       float64
+	retryIf      string
+	tmpl         *TemplateEngine
+	sleep        func(context.Context, time.Duration) error
+}
+
+// NewRetryStepFactory returns a StepFactory that creates RetryStep instances.
+// registryFn is called at step-creation time (lazy pattern, same as
+// step.switch) so child steps can use any registered step type.
+func NewRetryStepFactory(registryFn func() *StepRegistry) StepFactory {
+	return func(name string, config map[string]any, app modular.Application) (PipelineStep, error) {
+		raw, ok := config["steps"].([]any)
+		if !ok || len(raw) == 0 {
+			return nil, fmt.Errorf("retry step %q: 'steps' list is required and must not be empty", name)
+		}
+		steps := make([]PipelineStep, 0, len(raw))
+		for i, item := range raw {
+			stepCfg, ok := item.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("retry step %q: steps[%d] must be a map", name, i)
+			}
+			step, err := buildSubStep(name, fmt.Sprintf("steps[%d]", i), stepCfg, registryFn, app)
+			if err != nil {
+				return nil, fmt.Errorf("retry step %q: %w", name, err)
+			}
+			steps = append(steps, step)
+		}
+
+		s := &RetryStep{
+			name:         name,
+			steps:        steps,
+			maxAttempts:  3,
+			initialDelay: 100 * time.Millisecond,
+			maxDelay:     10 * time.Second,
+			multiplier:   2,
+			tmpl:         NewTemplateEngine(),
+			sleep:        sleepContext,
+		}
+		if v, ok := config["max_attempts"]; ok {
+			n, isNum := toFloat64(v)
+			if !isNum || n < 1 || n != float64(int(n)) {
+				return nil, fmt.Errorf("retry step %q: 'max_attempts' must be a positive integer", name)
+			}
+			s.maxAttempts = int(n)
+		}
+		for _, d := range []struct {
+			key string
+			dst *time.Duration
+		}{{"initial_delay", &s.initialDelay}, {"max_delay", &s.maxDelay}} {
+			str, ok := config[d.key].(string)
+			if !ok || str == "" {
+				continue
+			}
+			parsed, err := time.ParseDuration(str)
+			if err != nil || parsed < 0 {
+				return nil, fmt.Errorf("retry step %q: invalid %s %q", name, d.key, str)
+			}
+			*d.dst = parsed
+		}
+		if v, ok := config["multiplier"]; ok {
+			m, isNum := toFloat64(v)
+			if !isNum || m < 1 {
+				return nil, fmt.Errorf("retry step %q: 'multiplier' must be a number >= 1", name)
+			}
+			s.multiplier = m
+		}
+		if v, ok := config["jitter"]; ok {
+			j, isNum := toFloat64(v)
+			if !isNum || j < 0 || j > 1 {
+				return nil, fmt.Errorf("retry step %q: 'jitter' must be a number between 0 and 1", name)
+			}
+			s.jitter = j
+		}
+		s.retryIf, _ = config["retry_if"].(string)
+		return s, nil
+	}
+}
+
+// Name returns the step name.
+func (s *RetryStep) Name() string { return s.name }
+
+// Execute runs the child steps, retrying the block until an attempt succeeds
+// (and retry_if, when set, is falsy) or max_attempts is reached.
+func (s *RetryStep) Execute(ctx context.Context, pc *PipelineContext) (*StepResult, error) {
+	delay := s.initialDelay
+	for attempt := 1; ; attempt++ {
+		attemptPC := copyPipelineContext(pc)
+		stopped, err := s.runAttempt(ctx, attemptPC)
+		retry, condErr := s.shouldRetry(attemptPC, attempt, err)
+		if condErr != nil {
+			return nil, condErr
+		}
+		if !retry {
+			if err != nil {
+				return nil, fmt.Errorf("retry step %q: attempt %d: %w", s.name, attempt, err)
+			}
+			for _, step := range s.steps {
+				if out, ok := attemptPC.StepOutputs[step.Name()]; ok {
+					pc.MergeStepOutput(step.Name(), out)
+				}
+			}
+			return &StepResult{
+				Output: map[string]any{"attempts": attempt, "retried": attempt > 1},
+				Stop:   stopped,
+			}, nil
+		}
+		if attempt == s.maxAttempts {
+			if err != nil {
+				return nil, fmt.Errorf("retry step %q: all %d attempts failed: %w", s.name, attempt, err)
+			}
+			return nil, fmt.Errorf("retry step %q: retry_if still true after %d attempts", s.name, attempt)
+		}
+		if err := s.sleep(ctx, s.withJitter(delay)); err != nil {
+			return nil, fmt.Errorf("retry step %q: cancelled after %d attempts: %w", s.name, attempt, err)
+		}
+		delay = min(time.Duration(float64(delay)*s.multiplier), s.maxDelay)
+	}
+}
+
+// runAttempt runs the child steps in order on pc. It reports whether a child
+// asked the pipeline to stop.
+func (s *RetryStep) runAttempt(ctx context.Context, pc *PipelineContext) (bool, error) {
+	for _, step := range s.steps {
+		result, err := step.Execute(ctx, pc)
+		if err != nil {
+			return false, fmt.Errorf("sub-step %q failed: %w", step.Name(), err)
+		}
+		if result != nil && result.Output != nil {
+			pc.MergeStepOutput(step.Name(), result.Output)
+		} else {
+			pc.MergeStepOutput(step.Name(), map[string]any{})
+		}
+		if result != nil && result.Stop {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// shouldRetry decides whether the attempt that ended with err is retried.
+func (s *RetryStep) shouldRetry(pc *PipelineContext, attempt int, err error) (bool, error) {
+	if s.retryIf == "" {
+		return err != nil, nil
+	}
+	errMsg := ""
+	if err != nil {
+		errMsg = err.Error()
+	}
+	pc.Current["retry"] = map[string]any{"attempt": attempt, "failed": err != nil, "error": errMsg}
+	retry, condErr := evaluateIfExpr(s.tmpl, s.retryIf, pc)
+	if condErr != nil {
+		return false, fmt.Errorf("retry step %q: failed to evaluate retry_if: %w", s.name, condErr)
+	}
+	return retry, nil
+}
+
+// withJitter spreads d randomly by up to ±jitter of itself, capped at
+// max_delay.
+func (s *RetryStep) withJitter(d time.Duration) time.Duration {
+	if s.jitter == 0 || d <= 0 {
+		return d
+	}
+	spread := (rand.Float64()*2 - 1) * s.jitter //nolint:gosec // backoff jitter needs no crypto randomness
+	return min(time.Duration(float64(d)*(1+spread)), s.maxDelay)
+}
+
+// copyPipelineContext returns a copy of pc whose maps can be written without
+// affecting pc.
+func copyPipelineContext(pc *PipelineContext) *PipelineContext {
+	outputs := make(map[string]map[string]any, len(pc.StepOutputs))
+	for k, v := range pc.StepOutputs {
+		outputs[k] = maps.Clone(v)
+	}
+	current := make(map[string]any, len(pc.Current))
+	maps.Copy(current, pc.Current)
+	return &PipelineContext{
+		TriggerData:     maps.Clone(pc.TriggerData),
+		StepOutputs:     outputs,
+		Current:         current,
+		Metadata:        maps.Clone(pc.Metadata),
+		StrictTemplates: pc.StrictTemplates,
+		Logger:          pc.Logger,
+	}
+}
+
+// sleepContext waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,181 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GoCodeAlone/modular"
+)
+
+// newRetryTestFactory returns a step.retry factory whose children are
+// "test.flaky" steps. A flaky step appends its name to *ran and fails while
+// fewer than its fail_times attempts have run; its output records the
+// attempt number as "n".
+func newRetryTestFactory(ran *[]string) StepFactory {
+	registry := NewStepRegistry()
+	registry.Register("test.flaky", func(name string, cfg map[string]any, _ modular.Application) (PipelineStep, error) {
+		failTimes, _ := cfg["fail_times"].(int)
+		calls := 0
+		return &funcStep{name: name, fn: func(_ context.Context, _ *PipelineContext) (*StepResult, error) {
+			calls++
+			*ran = append(*ran, name)
+			if calls <= failTimes {
+				return nil, errors.New("unavailable")
+			}
+			return &StepResult{Output: map[string]any{"n": calls}}, nil
+		}}, nil
+	})
+	return NewRetryStepFactory(func() *StepRegistry { return registry })
+}
+
+func flakySteps(failTimes ...int) []any {
+	names := []string{"parse", "call", "transform"}
+	steps := make([]any, 0, len(failTimes))
+	for i, n := range failTimes {
+		steps = append(steps, map[string]any{"type": "test.flaky", "name": names[i], "config": map[string]any{"fail_times": n}})
+	}
+	return steps
+}
+
+// newRetryTestStep builds a step.retry that records its backoff delays
+// instead of sleeping.
+func newRetryTestStep(t *testing.T, ran *[]string, cfg map[string]any) (*RetryStep, *[]time.Duration) {
+	t.Helper()
+	step, err := newRetryTestFactory(ran)("resilient", cfg, nil)
+	if err != nil {
+		t.Fatalf("factory error: %v", err)
+	}
+	rs := step.(*RetryStep)
+	var delays []time.Duration
+	rs.sleep = func(_ context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+	return rs, &delays
+}
+
+func TestRetryStep_RerunsWholeBlock(t *testing.T) {
+	var ran []string
+	step, delays := newRetryTestStep(t, &ran, map[string]any{
+		"max_attempts":  4,
+		"initial_delay": "10ms",
+		"max_delay":     "25ms",
+		"steps":         flakySteps(0, 2, 0),
+	})
+	pc := NewPipelineContext(nil, nil)
+	result, err := step.Execute(context.Background(), pc)
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	want := []string{"parse", "call", "parse", "call", "parse", "call", "transform"}
+	if !slices.Equal(ran, want) {
+		t.Errorf("ran = %v, want %v", ran, want)
+	}
+	if !slices.Equal(*delays, []time.Duration{10 * time.Millisecond, 20 * time.Millisecond}) {
+		t.Errorf("delays = %v, want 10ms then 20ms", *delays)
+	}
+	if result.Output["attempts"] != 3 || result.Output["retried"] != true {
+		t.Errorf("output = %v, want 3 attempts", result.Output)
+	}
+	// Only the successful attempt's outputs reach the pipeline context.
+	if pc.StepOutputs["parse"]["n"] != 3 || pc.StepOutputs["transform"]["n"] != 1 {
+		t.Errorf("step outputs = %v, want those of the third attempt", pc.StepOutputs)
+	}
+}
+
+func TestRetryStep_ExhaustsAttempts(t *testing.T) {
+	var ran []string
+	step, delays := newRetryTestStep(t, &ran, map[string]any{
+		"max_attempts": 2,
+		"steps":        flakySteps(5),
+	})
+	pc := NewPipelineContext(nil, nil)
+	_, err := step.Execute(context.Background(), pc)
+	if err == nil || !strings.Contains(err.Error(), `all 2 attempts failed: sub-step "parse" failed: unavailable`) {
+		t.Fatalf("err = %v, want exhaustion naming the failing child", err)
+	}
+	if len(ran) != 2 || len(*delays) != 1 {
+		t.Errorf("ran %v with delays %v, want 2 attempts and 1 backoff", ran, *delays)
+	}
+	if _, ok := pc.StepOutputs["parse"]; ok {
+		t.Error("failed attempt leaked output into the pipeline context")
+	}
+}
+
+func TestRetryStep_RetryIf(t *testing.T) {
+	tests := []struct {
+		name        string
+		retryIf     string
+		failTimes   int
+		wantRuns    int
+		wantErrPart string
+	}{
+		{"result condition retries successes", "{{ lt .steps.parse.n 3 }}", 0, 3, ""},
+		{"false condition does not retry failure", `{{ eq .retry.error "" }}`, 1, 1, "attempt 1"},
+		{"condition still true when attempts run out", "true", 0, 4, "retry_if still true after 4 attempts"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ran []string
+			step, _ := newRetryTestStep(t, &ran, map[string]any{
+				"max_attempts": 4,
+				"retry_if":     tt.retryIf,
+				"steps":        flakySteps(tt.failTimes),
+			})
+			_, err := step.Execute(context.Background(), NewPipelineContext(nil, nil))
+			if tt.wantErrPart == "" && err != nil {
+				t.Fatalf("execute: %v", err)
+			}
+			if tt.wantErrPart != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErrPart)) {
+				t.Fatalf("err = %v, want containing %q", err, tt.wantErrPart)
+			}
+			if len(ran) != tt.wantRuns {
+				t.Errorf("ran %d attempts, want %d", len(ran), tt.wantRuns)
+			}
+		})
+	}
+}
+
+func TestRetryStep_JitterStaysInBounds(t *testing.T) {
+	var ran []string
+	step, _ := newRetryTestStep(t, &ran, map[string]any{
+		"jitter":    0.5,
+		"max_delay": "120ms",
+		"steps":     flakySteps(0),
+	})
+	for range 100 {
+		d := step.withJitter(100 * time.Millisecond)
+		if d < 50*time.Millisecond || d > 120*time.Millisecond {
+			t.Fatalf("jittered delay %v outside [50ms, 120ms]", d)
+		}
+	}
+}
+
+func TestRetryStep_FactoryErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     map[string]any
+		wantErr string
+	}{
+		{"missing steps", map[string]any{}, "'steps' list is required"},
+		{"zero attempts", map[string]any{"steps": flakySteps(0), "max_attempts": 0}, "'max_attempts' must be a positive integer"},
+		{"bad delay", map[string]any{"steps": flakySteps(0), "initial_delay": "soon"}, `invalid initial_delay "soon"`},
+		{"jitter above one", map[string]any{"steps": flakySteps(0), "jitter": 1.5}, "'jitter' must be a number between 0 and 1"},
+		{"multiplier below one", map[string]any{"steps": flakySteps(0), "multiplier": 0.5}, "'multiplier' must be a number >= 1"},
+		{"unknown child type", map[string]any{"steps": []any{map[string]any{"type": "step.nope"}}}, `retry step "resilient"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ran []string
+			_, err := newRetryTestFactory(&ran)("resilient", tt.cfg, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
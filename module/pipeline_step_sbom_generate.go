@@ -0,0 +1,185 @@
+package module
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow/artifact"
+)
+
+// SBOM generators supported by step.sbom_generate.
+const (
+	SBOMGeneratorSyft    = "syft"
+	SBOMGeneratorBuiltin = "builtin"
+)
+
+// SBOMGenerateStep generates a software bill of materials for a source tree
+// or a built image, writes it to a file and stores it as an execution
+// artifact. The SBOM is generated by syft, or for a Go source tree by the
+// built-in generator, which reads go.mod and needs no external tools.
+type SBOMGenerateStep struct {
+	name        string
+	sourcePath  string
+	image       string
+	generator   string
+	format      string
+	outputPath  string
+	artifactKey string
+	tmpl        *TemplateEngine
+	execCommand func(ctx context.Context, name string, args ...string) *exec.Cmd
+}
+
+// NewSBOMGenerateStepFactory returns a StepFactory that creates SBOMGenerateStep instances.
+func NewSBOMGenerateStepFactory() StepFactory {
+	return func(name string, config map[string]any, _ modular.Application) (PipelineStep, error) {
+		s := &SBOMGenerateStep{
+			name:        name,
+			generator:   SBOMGeneratorSyft,
+			format:      SBOMFormatCycloneDXJSON,
+			tmpl:        NewTemplateEngine(),
+			execCommand: exec.CommandContext,
+		}
+		s.sourcePath, _ = config["source_path"].(string)
+		s.image, _ = config["image"].(string)
+		if (s.sourcePath == "") == (s.image == "") {
+			return nil, fmt.Errorf("sbom_generate step %q: exactly one of 'source_path' or 'image' is required", name)
+		}
+
+		if v, _ := config["generator"].(string); v != "" {
+			s.generator = v
+		}
+		switch s.generator {
+		case SBOMGeneratorSyft:
+		case SBOMGeneratorBuiltin:
+			if s.image != "" {
+				return nil, fmt.Errorf("sbom_generate step %q: the %s generator only supports 'source_path'", name, SBOMGeneratorBuiltin)
+			}
+		default:
+			return nil, fmt.Errorf("sbom_generate step %q: unsupported generator %q (supported: %s, %s)", name, s.generator, SBOMGeneratorSyft, SBOMGeneratorBuiltin)
+		}
+
+		if v, _ := config["format"].(string); v != "" {
+			s.format = v
+		}
+		if s.format != SBOMFormatCycloneDXJSON && s.format != SBOMFormatSPDXJSON {
+			return nil, fmt.Errorf("sbom_generate step %q: unsupported format %q (supported: %s, %s)", name, s.format, SBOMFormatCycloneDXJSON, SBOMFormatSPDXJSON)
+		}
+
+		s.outputPath, _ = config["output_path"].(string)
+		s.artifactKey = "sbom" + sbomFileExtension(s.format)
+		if key, ok := config["artifact_key"].(string); ok {
+			s.artifactKey = key
+		}
+		return s, nil
+	}
+}
+
+// Name returns the step name.
+func (s *SBOMGenerateStep) Name() string { return s.name }
+
+// Execute generates the SBOM and stores it.
+func (s *SBOMGenerateStep) Execute(ctx context.Context, pc *PipelineContext) (*StepResult, error) {
+	sourcePath, err := s.tmpl.Resolve(s.sourcePath, pc)
+	if err != nil {
+		return nil, fmt.Errorf("sbom_generate step %q: resolve source_path: %w", s.name, err)
+	}
+	image, err := s.tmpl.Resolve(s.image, pc)
+	if err != nil {
+		return nil, fmt.Errorf("sbom_generate step %q: resolve image: %w", s.name, err)
+	}
+	outputPath, err := s.resolveOutputPath(pc)
+	if err != nil {
+		return nil, fmt.Errorf("sbom_generate step %q: %w", s.name, err)
+	}
+
+	target := image
+	if sourcePath != "" {
+		target = sourcePath
+	}
+	if s.generator == SBOMGeneratorBuiltin {
+		data, err := GenerateGoSBOM(sourcePath, s.format)
+		if err != nil {
+			return nil, fmt.Errorf("sbom_generate step %q: %w", s.name, err)
+		}
+		if err := os.WriteFile(outputPath, data, 0600); err != nil {
+			return nil, fmt.Errorf("sbom_generate step %q: write SBOM: %w", s.name, err)
+		}
+	} else if err := s.runSyft(ctx, sourcePath, image, outputPath); err != nil {
+		return nil, fmt.Errorf("sbom_generate step %q: %w", s.name, err)
+	}
+
+	data, err := os.ReadFile(outputPath) //nolint:gosec // G304: path from step configuration
+	if err != nil {
+		return nil, fmt.Errorf("sbom_generate step %q: read SBOM: %w", s.name, err)
+	}
+	components, err := SBOMComponentCount(data, s.format)
+	if err != nil {
+		return nil, fmt.Errorf("sbom_generate step %q: %w", s.name, err)
+	}
+	sum := sha256.Sum256(data)
+	output := map[string]any{
+		"path":       outputPath,
+		"format":     s.format,
+		"generator":  s.generator,
+		"target":     target,
+		"sha256":     hex.EncodeToString(sum[:]),
+		"size":       len(data),
+		"components": components,
+	}
+
+	// Like step.test_report, the SBOM is stored only when the execution
+	// has an artifact store and artifact_key is not empty.
+	store, _ := pc.Metadata["artifact_store"].(artifact.Store)
+	executionID, _ := pc.Metadata["execution_id"].(string)
+	if s.artifactKey != "" && store != nil && executionID != "" {
+		if err := store.Put(ctx, executionID, s.artifactKey, bytes.NewReader(data)); err != nil {
+			return nil, fmt.Errorf("sbom_generate step %q: store SBOM artifact %q: %w", s.name, s.artifactKey, err)
+		}
+		output["artifact"] = s.artifactKey
+	}
+	return &StepResult{Output: output}, nil
+}
+
+// resolveOutputPath returns the file the SBOM is written to, creating its
+// directory. Without output_path the SBOM goes to a new temporary file.
+func (s *SBOMGenerateStep) resolveOutputPath(pc *PipelineContext) (string, error) {
+	if s.outputPath == "" {
+		f, err := os.CreateTemp("", "workflow-sbom-*"+sbomFileExtension(s.format))
+		if err != nil {
+			return "", fmt.Errorf("create SBOM file: %w", err)
+		}
+		_ = f.Close()
+		return f.Name(), nil
+	}
+	path, err := s.tmpl.Resolve(s.outputPath, pc)
+	if err != nil {
+		return "", fmt.Errorf("resolve output_path: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return "", fmt.Errorf("create dir for %q: %w", path, err)
+	}
+	return path, nil
+}
+
+// runSyft runs `syft scan` against the source directory or image and
+// writes the SBOM to outputPath.
+func (s *SBOMGenerateStep) runSyft(ctx context.Context, sourcePath, image, outputPath string) error {
+	target := image
+	if sourcePath != "" {
+		target = "dir:" + sourcePath
+	}
+	var stderr bytes.Buffer
+	cmd := s.execCommand(ctx, "syft", "scan", target, "-o", s.format+"="+outputPath) //nolint:gosec // G204: args from step configuration
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("syft failed: %w\nstderr: %s", err, stderr.String())
+	}
+	return nil
+}
@@ -2,7 +2,6 @@ package module
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -53,11 +52,7 @@ func (s *ScaffoldStep) Execute(_ context.Context, pc *PipelineContext) (*StepRes
 	// Read spec bytes from request body or pipeline context.
 	specBytes, err := s.readSpecBytes(req, pc)
 	if err != nil {
-		if hasWriter {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
-			pc.Metadata["_response_handled"] = true
+		if writeStepProblem(pc, s.name, NewProblem(ProblemBadRequest, err.Error())) {
 			return &StepResult{Output: map[string]any{"error": err.Error()}, Stop: true}, nil
 		}
 		return nil, fmt.Errorf("step.ui_scaffold %q: %w", s.name, err)
@@ -71,11 +66,7 @@ func (s *ScaffoldStep) Execute(_ context.Context, pc *PipelineContext) (*StepRes
 	}
 	data, err := scaffold.AnalyzeOnly(specBytes, opts)
 	if err != nil {
-		if hasWriter {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
-			pc.Metadata["_response_handled"] = true
+		if writeStepProblem(pc, s.name, NewProblem(ProblemBadRequest, err.Error())) {
 			return &StepResult{Output: map[string]any{"error": err.Error()}, Stop: true}, nil
 		}
 		return nil, fmt.Errorf("step.ui_scaffold %q: analyzing spec: %w", s.name, err)
@@ -84,11 +75,7 @@ func (s *ScaffoldStep) Execute(_ context.Context, pc *PipelineContext) (*StepRes
 	// Generate ZIP.
 	zipBytes, err := scaffold.GenerateToZip(*data)
 	if err != nil {
-		if hasWriter {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusInternalServerError)
-			_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
-			pc.Metadata["_response_handled"] = true
+		if writeStepProblem(pc, s.name, NewProblem(ProblemInternalError, err.Error())) {
 			return &StepResult{Output: map[string]any{"error": err.Error()}, Stop: true}, nil
 		}
 		return nil, fmt.Errorf("step.ui_scaffold %q: generating zip: %w", s.name, err)
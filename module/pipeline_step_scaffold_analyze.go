@@ -44,11 +44,7 @@ func (s *ScaffoldAnalyzeStep) Execute(_ context.Context, pc *PipelineContext) (*
 	// Read spec bytes from request body or current context.
 	specBytes, err := s.readSpecBytes(req, pc)
 	if err != nil {
-		if hasWriter {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
-			pc.Metadata["_response_handled"] = true
+		if writeStepProblem(pc, s.name, NewProblem(ProblemBadRequest, err.Error())) {
 			return &StepResult{Output: map[string]any{"error": err.Error()}, Stop: true}, nil
 		}
 		return nil, fmt.Errorf("step.ui_scaffold_analyze %q: %w", s.name, err)
@@ -61,11 +57,7 @@ func (s *ScaffoldAnalyzeStep) Execute(_ context.Context, pc *PipelineContext) (*
 	}
 	data, err := scaffold.AnalyzeOnly(specBytes, opts)
 	if err != nil {
-		if hasWriter {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
-			pc.Metadata["_response_handled"] = true
+		if writeStepProblem(pc, s.name, NewProblem(ProblemBadRequest, err.Error())) {
 			return &StepResult{Output: map[string]any{"error": err.Error()}, Stop: true}, nil
 		}
 		return nil, fmt.Errorf("step.ui_scaffold_analyze %q: %w", s.name, err)
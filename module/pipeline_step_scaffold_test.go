@@ -159,12 +159,12 @@ func TestScaffoldAnalyzeStep_InvalidSpec_Returns400(t *testing.T) {
 	}
 
 	body, _ := io.ReadAll(resp.Body)
-	var errResp map[string]string
-	if json.Unmarshal(body, &errResp) != nil {
-		t.Errorf("expected JSON error response, got: %s", body)
+	var problem Problem
+	if json.Unmarshal(body, &problem) != nil {
+		t.Errorf("expected problem+json error response, got: %s", body)
 	}
-	if errResp["error"] == "" {
-		t.Error("expected non-empty 'error' field in response")
+	if problem.Code != string(ProblemBadRequest) || problem.Detail == "" {
+		t.Errorf("expected a bad_request problem with detail, got %+v", problem)
 	}
 }
 
@@ -11,7 +11,8 @@ import (
 // ScanContainerStep runs a container vulnerability scanner (e.g., Trivy)
 // against a target image and evaluates findings against a severity gate.
 // Execution is delegated to a SecurityScannerProvider registered under
-// the "security-scanner" service.
+// the "security-scanner" service. With sbom set, the scanner reads the
+// image's packages from that SBOM instead of pulling the image.
 type ScanContainerStep struct {
 	name              string
 	scanner           string
@@ -19,6 +20,8 @@ type ScanContainerStep struct {
 	severityThreshold string
 	ignoreUnfixed     bool
 	outputFormat      string
+	sbom              string
+	tmpl              *TemplateEngine
 	app               modular.Application
 }
 
@@ -56,6 +59,8 @@ func NewScanContainerStepFactory() StepFactory {
 			outputFormat = "sarif"
 		}
 
+		sbom, _ := config["sbom"].(string)
+
 		return &ScanContainerStep{
 			name:              name,
 			scanner:           scanner,
@@ -63,6 +68,8 @@ func NewScanContainerStepFactory() StepFactory {
 			severityThreshold: severityThreshold,
 			ignoreUnfixed:     ignoreUnfixed,
 			outputFormat:      outputFormat,
+			sbom:              sbom,
+			tmpl:              NewTemplateEngine(),
 			app:               app,
 		}, nil
 	}
@@ -73,7 +80,7 @@ func (s *ScanContainerStep) Name() string { return s.name }
 
 // Execute runs the container scanner via the SecurityScannerProvider and evaluates
 // the severity gate. Returns an error if the gate fails or no provider is configured.
-func (s *ScanContainerStep) Execute(ctx context.Context, _ *PipelineContext) (*StepResult, error) {
+func (s *ScanContainerStep) Execute(ctx context.Context, pc *PipelineContext) (*StepResult, error) {
 	if s.app == nil {
 		return nil, fmt.Errorf("scan_container step %q: no application context", s.name)
 	}
@@ -82,12 +89,18 @@ func (s *ScanContainerStep) Execute(ctx context.Context, _ *PipelineContext) (*S
 		return nil, fmt.Errorf("scan_container step %q: no security scanner provider configured — load a scanner plugin", s.name)
 	}
 
+	sbomPath, err := s.tmpl.Resolve(s.sbom, pc)
+	if err != nil {
+		return nil, fmt.Errorf("scan_container step %q: resolve sbom: %w", s.name, err)
+	}
+
 	result, err := provider.ScanContainer(ctx, ContainerScanOpts{
 		Scanner:           s.scanner,
 		TargetImage:       s.targetImage,
 		SeverityThreshold: s.severityThreshold,
 		IgnoreUnfixed:     s.ignoreUnfixed,
 		OutputFormat:      s.outputFormat,
+		SBOMPath:          sbomPath,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("scan_container step %q: %w", s.name, err)
@@ -10,13 +10,17 @@ import (
 // ScanDepsStep runs a dependency vulnerability scanner (e.g., Grype)
 // against a source path and evaluates findings against a severity gate.
 // Execution is delegated to a SecurityScannerProvider registered under
-// the "security-scanner" service.
+// the "security-scanner" service. With sbom set, the scanner reads the
+// dependencies from that SBOM, such as one written by step.sbom_generate,
+// instead of walking the source tree.
 type ScanDepsStep struct {
 	name           string
 	scanner        string
 	sourcePath     string
 	failOnSeverity string
 	outputFormat   string
+	sbom           string
+	tmpl           *TemplateEngine
 	app            modular.Application
 }
 
@@ -47,12 +51,16 @@ func NewScanDepsStepFactory() StepFactory {
 			outputFormat = "sarif"
 		}
 
+		sbom, _ := config["sbom"].(string)
+
 		return &ScanDepsStep{
 			name:           name,
 			scanner:        scanner,
 			sourcePath:     sourcePath,
 			failOnSeverity: failOnSeverity,
 			outputFormat:   outputFormat,
+			sbom:           sbom,
+			tmpl:           NewTemplateEngine(),
 			app:            app,
 		}, nil
 	}
@@ -63,7 +71,7 @@ func (s *ScanDepsStep) Name() string { return s.name }
 
 // Execute runs the dependency scanner via the SecurityScannerProvider and evaluates
 // the severity gate. Returns an error if the gate fails or no provider is configured.
-func (s *ScanDepsStep) Execute(ctx context.Context, _ *PipelineContext) (*StepResult, error) {
+func (s *ScanDepsStep) Execute(ctx context.Context, pc *PipelineContext) (*StepResult, error) {
 	if s.app == nil {
 		return nil, fmt.Errorf("scan_deps step %q: no application context", s.name)
 	}
@@ -72,11 +80,17 @@ func (s *ScanDepsStep) Execute(ctx context.Context, _ *PipelineContext) (*StepRe
 		return nil, fmt.Errorf("scan_deps step %q: no security scanner provider configured — load a scanner plugin", s.name)
 	}
 
+	sbomPath, err := s.tmpl.Resolve(s.sbom, pc)
+	if err != nil {
+		return nil, fmt.Errorf("scan_deps step %q: resolve sbom: %w", s.name, err)
+	}
+
 	result, err := provider.ScanDeps(ctx, DepsScanOpts{
 		Scanner:        s.scanner,
 		SourcePath:     s.sourcePath,
 		FailOnSeverity: s.failOnSeverity,
 		OutputFormat:   s.outputFormat,
+		SBOMPath:       sbomPath,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("scan_deps step %q: %w", s.name, err)
@@ -0,0 +1,156 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/GoCodeAlone/modular"
+)
+
+// SchemaValidateStep validates data in the pipeline context against a
+// schema registered in a schema.registry module.
+type SchemaValidateStep struct {
+	name         string
+	registryName string
+	schema       string
+	source       string
+	onInvalid    string
+	app          modular.Application
+}
+
+// NewSchemaValidateStepFactory returns a StepFactory for step.schema_validate.
+func NewSchemaValidateStepFactory() StepFactory {
+	return func(name string, cfg map[string]any, app modular.Application) (PipelineStep, error) {
+		registryName, _ := cfg["registry"].(string)
+		if registryName == "" {
+			return nil, fmt.Errorf("schema_validate step %q: 'registry' is required", name)
+		}
+		schema, _ := cfg["schema"].(string)
+		if schema == "" {
+			return nil, fmt.Errorf("schema_validate step %q: 'schema' is required", name)
+		}
+		if _, err := ParseSchemaRef(schema); err != nil {
+			return nil, fmt.Errorf("schema_validate step %q: %w", name, err)
+		}
+		source, _ := cfg["source"].(string)
+		onInvalid, _ := cfg["on_invalid"].(string)
+		switch onInvalid {
+		case "":
+			onInvalid = "error"
+		case "error", "respond", "continue":
+		default:
+			return nil, fmt.Errorf("schema_validate step %q: on_invalid must be error, respond or continue", name)
+		}
+		return &SchemaValidateStep{
+			name:         name,
+			registryName: registryName,
+			schema:       schema,
+			source:       source,
+			onInvalid:    onInvalid,
+			app:          app,
+		}, nil
+	}
+}
+
+// Name returns the step name.
+func (s *SchemaValidateStep) Name() string { return s.name }
+
+// Execute looks the schema up on every run, so that a schema updated in the
+// registry applies to every step that references it.
+func (s *SchemaValidateStep) Execute(_ context.Context, pc *PipelineContext) (*StepResult, error) {
+	registry, err := s.registry()
+	if err != nil {
+		return nil, err
+	}
+	schema, err := registry.Lookup(s.schema)
+	if err != nil {
+		return nil, fmt.Errorf("schema_validate step %q: %w", s.name, err)
+	}
+	data, err := s.data(pc)
+	if err != nil {
+		return nil, fmt.Errorf("schema_validate step %q: source %q: %w", s.name, s.source, err)
+	}
+
+	violations := schema.Validate(data)
+	if len(violations) > 0 {
+		switch s.onInvalid {
+		case "error":
+			return nil, schemaValidationProblem(schema.Ref, violations)
+		case "respond":
+			p := schemaValidationProblem(schema.Ref, violations)
+			writeStepProblem(pc, s.name, p)
+			body, _ := json.Marshal(p)
+			return &StepResult{
+				Output: map[string]any{
+					"valid":           false,
+					"schema":          schema.Ref.String(),
+					"errors":          violations,
+					"response_status": http.StatusBadRequest,
+					"response_body":   string(body),
+					"response_headers": map[string]string{
+						"Content-Type": ProblemContentType,
+					},
+				},
+				Stop: true,
+			}, nil
+		}
+	}
+	return &StepResult{Output: map[string]any{
+		"valid":  len(violations) == 0,
+		"schema": schema.Ref.String(),
+		"errors": nonNilStrings(violations),
+	}}, nil
+}
+
+func (s *SchemaValidateStep) registry() (*SchemaRegistry, error) {
+	if s.app == nil {
+		return nil, fmt.Errorf("schema_validate step %q: no application context", s.name)
+	}
+	svc, ok := s.app.SvcRegistry()[s.registryName]
+	if !ok {
+		return nil, fmt.Errorf("schema_validate step %q: schema registry %q not found", s.name, s.registryName)
+	}
+	registry, ok := svc.(*SchemaRegistry)
+	if !ok {
+		return nil, fmt.Errorf("schema_validate step %q: service %q is not a schema.registry (got %T)", s.name, s.registryName, svc)
+	}
+	return registry, nil
+}
+
+// data returns the value at the dotted source path, which may start with
+// "steps.<name>", or pc.Current when no source is set.
+func (s *SchemaValidateStep) data(pc *PipelineContext) (any, error) {
+	if s.source == "" {
+		return pc.Current, nil
+	}
+	data := make(map[string]any, len(pc.Current)+1)
+	for k, v := range pc.Current {
+		data[k] = v
+	}
+	steps := make(map[string]any, len(pc.StepOutputs))
+	for k, v := range pc.StepOutputs {
+		steps[k] = v
+	}
+	data["steps"] = steps
+	return resolveDottedPath(data, s.source)
+}
+
+// schemaValidationProblem returns a validation_failed problem listing each
+// violation with the location of the offending value.
+func schemaValidationProblem(ref SchemaVersion, violations []string) *Problem {
+	p := NewProblem(ProblemValidationFailed, fmt.Sprintf("data does not match schema %s", ref))
+	entries := make([]map[string]string, len(violations))
+	for i, v := range violations {
+		field, msg, ok := strings.Cut(v, ": ")
+		if !ok {
+			entries[i] = map[string]string{"message": v}
+			continue
+		}
+		entries[i] = map[string]string{"field": field, "message": msg}
+	}
+	p.Errors = entries
+	return p
+}
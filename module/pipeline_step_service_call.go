@@ -0,0 +1,198 @@
+package module
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/GoCodeAlone/modular"
+)
+
+// DefaultServiceCallMaxDepth is how deeply step.service_call calls may nest
+// when max_depth is not set.
+const DefaultServiceCallMaxDepth = 8
+
+// serviceCallDepthKey is the context key of the service call nesting depth.
+type serviceCallDepthKey struct{}
+
+// ServiceCallDepth returns how many step.service_call dispatches ctx is
+// nested in; 0 outside any.
+func ServiceCallDepth(ctx context.Context) int {
+	depth, _ := ctx.Value(serviceCallDepthKey{}).(int)
+	return depth
+}
+
+// ServiceCallStep dispatches a request to another module's HTTP handler,
+// such as an http.router, in-process. The target route runs its pipeline as
+// if it had been called over HTTP, without the network round-trip, and its
+// response is merged into the step output.
+//
+// The nesting depth travels in the request context, so a route that calls
+// itself, directly or through other routes, fails once max_depth is
+// reached instead of recursing forever.
+type ServiceCallStep struct {
+	name           string
+	service        string
+	method         string
+	path           string
+	body           any
+	bodyFrom       string
+	headers        map[string]any
+	forwardHeaders []string
+	errorOnStatus  bool
+	maxDepth       int
+	app            modular.Application
+	tmpl           *TemplateEngine
+}
+
+// NewServiceCallStepFactory returns a StepFactory that creates ServiceCallStep instances.
+func NewServiceCallStepFactory() StepFactory {
+	return func(name string, config map[string]any, app modular.Application) (PipelineStep, error) {
+		service, _ := config["service"].(string)
+		if service == "" {
+			return nil, fmt.Errorf("service_call step %q: 'service' is required", name)
+		}
+		path, _ := config["path"].(string)
+		if path == "" {
+			return nil, fmt.Errorf("service_call step %q: 'path' is required", name)
+		}
+		method, _ := config["method"].(string)
+		if method == "" {
+			method = http.MethodGet
+		}
+
+		step := &ServiceCallStep{
+			name:          name,
+			service:       service,
+			method:        strings.ToUpper(method),
+			path:          path,
+			body:          config["body"],
+			errorOnStatus: true,
+			maxDepth:      DefaultServiceCallMaxDepth,
+			app:           app,
+			tmpl:          NewTemplateEngine(),
+		}
+		if v, ok := config["body_from"]; ok {
+			from, ok := v.(string)
+			if !ok || from == "" {
+				return nil, fmt.Errorf("service_call step %q: 'body_from' must be a non-empty path", name)
+			}
+			if step.body != nil {
+				return nil, fmt.Errorf("service_call step %q: 'body' and 'body_from' are mutually exclusive", name)
+			}
+			step.bodyFrom = from
+		}
+		if v, ok := config["headers"]; ok {
+			headers, ok := v.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("service_call step %q: 'headers' must be a map", name)
+			}
+			step.headers = headers
+		}
+		if v, ok := config["forward_headers"]; ok {
+			headers, err := corsStringList(v)
+			if err != nil {
+				return nil, fmt.Errorf("service_call step %q: 'forward_headers' %w", name, err)
+			}
+			step.forwardHeaders = headers
+		}
+		if v, ok := config["error_on_status"].(bool); ok {
+			step.errorOnStatus = v
+		}
+		if v, ok := config["max_depth"]; ok {
+			depth, ok := intFromAny(v)
+			if !ok || depth < 1 {
+				return nil, fmt.Errorf("service_call step %q: 'max_depth' must be a positive integer", name)
+			}
+			step.maxDepth = depth
+		}
+
+		return step, nil
+	}
+}
+
+// Name returns the step name.
+func (s *ServiceCallStep) Name() string { return s.name }
+
+// Execute dispatches the request to the service and returns its response
+// as status_code, status, headers and body.
+func (s *ServiceCallStep) Execute(ctx context.Context, pc *PipelineContext) (*StepResult, error) {
+	depth := ServiceCallDepth(ctx)
+	if depth >= s.maxDepth {
+		return nil, fmt.Errorf("service_call step %q: call depth %d reached max_depth %d; are routes calling each other recursively?", s.name, depth, s.maxDepth)
+	}
+
+	if s.app == nil {
+		return nil, fmt.Errorf("service_call step %q: no application context", s.name)
+	}
+	svc, ok := s.app.SvcRegistry()[s.service]
+	if !ok {
+		return nil, fmt.Errorf("service_call step %q: service %q not found in registry", s.name, s.service)
+	}
+	handler, ok := svc.(http.Handler)
+	if !ok {
+		return nil, fmt.Errorf("service_call step %q: service %q does not implement http.Handler", s.name, s.service)
+	}
+
+	path, err := s.tmpl.Resolve(s.path, pc)
+	if err != nil {
+		return nil, fmt.Errorf("service_call step %q: failed to resolve path: %w", s.name, err)
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	body, hasBody, err := resolveForwardBody(s.tmpl, s.body, s.bodyFrom, pc)
+	if err != nil {
+		return nil, fmt.Errorf("service_call step %q: %w", s.name, err)
+	}
+	var bodyReader io.Reader
+	if hasBody {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("service_call step %q: failed to encode request body: %w", s.name, err)
+		}
+		bodyReader = bytes.NewReader(data)
+	}
+
+	callCtx := context.WithValue(ctx, serviceCallDepthKey{}, depth+1)
+	req, err := http.NewRequestWithContext(callCtx, s.method, path, bodyReader)
+	if err != nil {
+		return nil, fmt.Errorf("service_call step %q: failed to create request: %w", s.name, err)
+	}
+	req.RemoteAddr = "127.0.0.1:0"
+	if hasBody {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if orig, ok := pc.Metadata["_http_request"].(*http.Request); ok {
+		for _, h := range s.forwardHeaders {
+			if v := orig.Header.Values(h); len(v) > 0 {
+				req.Header[http.CanonicalHeaderKey(h)] = v
+			}
+		}
+		req.RemoteAddr = orig.RemoteAddr
+	}
+	if id := GetRequestID(ctx); id != "" {
+		req.Header.Set(RequestIDHeader, id)
+	}
+	if err := applyForwardHeaders(s.tmpl, s.headers, req, pc); err != nil {
+		return nil, fmt.Errorf("service_call step %q: %w", s.name, err)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	resp := recorder.Result()
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(resp.Body)
+
+	if s.errorOnStatus && resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("service_call step %q: %s %s on %q: HTTP %d: %s", s.name, s.method, path, s.service, resp.StatusCode, string(respBody))
+	}
+	return &StepResult{Output: parseHTTPResponse(resp, respBody)}, nil
+}
+
+var _ PipelineStep = (*ServiceCallStep)(nil)
@@ -0,0 +1,141 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newServiceCallStep(t *testing.T, handler http.Handler, config map[string]any) PipelineStep {
+	t.Helper()
+	app := NewMockApplication()
+	_ = app.RegisterService("router", handler)
+	config["service"] = "router"
+	step, err := NewServiceCallStepFactory()("call", config, app)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return step
+}
+
+func TestServiceCallStep_DispatchesInProcess(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /customers/{id}/orders", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"customer": r.PathValue("id"),
+			"auth":     r.Header.Get("Authorization"),
+			"tenant":   r.Header.Get("X-Tenant"),
+			"request":  r.Header.Get(RequestIDHeader),
+			"depth":    ServiceCallDepth(r.Context()),
+			"body":     string(body),
+		})
+	})
+	step := newServiceCallStep(t, mux, map[string]any{
+		"method":          "post",
+		"path":            "/customers/{{ .customer_id }}/orders",
+		"body_from":       "steps.prepare.order",
+		"headers":         map[string]any{"X-Tenant": "{{ .tenant }}"},
+		"forward_headers": []any{"Authorization"},
+	})
+
+	orig := httptest.NewRequest(http.MethodPost, "/checkout", nil)
+	orig.Header.Set("Authorization", "Bearer abc")
+	pc := NewPipelineContext(map[string]any{"customer_id": "c1", "tenant": "acme"}, map[string]any{"_http_request": orig})
+	pc.MergeStepOutput("prepare", map[string]any{"order": map[string]any{"sku": "a"}})
+
+	result, err := step.Execute(WithRequestID(context.Background(), "req-1"), pc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Output["status_code"] != http.StatusCreated {
+		t.Errorf("status_code = %v, want 201", result.Output["status_code"])
+	}
+	body, _ := result.Output["body"].(map[string]any)
+	want := map[string]any{
+		"customer": "c1",
+		"auth":     "Bearer abc",
+		"tenant":   "acme",
+		"request":  "req-1",
+		"depth":    float64(1),
+		"body":     `{"sku":"a"}`,
+	}
+	for k, v := range want {
+		if body[k] != v {
+			t.Errorf("body[%q] = %v, want %v", k, body[k], v)
+		}
+	}
+}
+
+func TestServiceCallStep_ErrorOnStatus(t *testing.T) {
+	missing := http.NotFoundHandler()
+
+	step := newServiceCallStep(t, missing, map[string]any{"path": "/nope"})
+	if _, err := step.Execute(context.Background(), NewPipelineContext(nil, nil)); err == nil || !strings.Contains(err.Error(), "HTTP 404") {
+		t.Fatalf("err = %v, want HTTP 404 error", err)
+	}
+
+	step = newServiceCallStep(t, missing, map[string]any{"path": "/nope", "error_on_status": false})
+	result, err := step.Execute(context.Background(), NewPipelineContext(nil, nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Output["status_code"] != http.StatusNotFound {
+		t.Errorf("status_code = %v, want 404", result.Output["status_code"])
+	}
+}
+
+func TestServiceCallStep_DepthLimit(t *testing.T) {
+	// A route that calls itself.
+	var step PipelineStep
+	calls := 0
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if _, err := step.Execute(r.Context(), NewPipelineContext(nil, nil)); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	step = newServiceCallStep(t, handler, map[string]any{"path": "/loop", "max_depth": 3})
+
+	_, err := step.Execute(context.Background(), NewPipelineContext(nil, nil))
+	if err == nil || !strings.Contains(err.Error(), "reached max_depth 3") {
+		t.Fatalf("err = %v, want depth limit error", err)
+	}
+	if calls != 3 {
+		t.Errorf("handler called %d times, want 3", calls)
+	}
+}
+
+func TestServiceCallStep_InvalidConfig(t *testing.T) {
+	for name, config := range map[string]map[string]any{
+		"missing service":    {"path": "/x"},
+		"missing path":       {"service": "router"},
+		"body and body_from": {"service": "router", "path": "/x", "body": map[string]any{}, "body_from": "steps.a"},
+		"bad max_depth":      {"service": "router", "path": "/x", "max_depth": 0},
+		"bad headers":        {"service": "router", "path": "/x", "headers": "X-A"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewServiceCallStepFactory()("call", config, nil); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestServiceCallStep_UnknownService(t *testing.T) {
+	step, err := NewServiceCallStepFactory()("call", map[string]any{"service": "missing", "path": "/x"}, NewMockApplication())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := step.Execute(context.Background(), NewPipelineContext(nil, nil)); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("err = %v, want service not found", err)
+	}
+}
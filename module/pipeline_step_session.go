@@ -0,0 +1,183 @@
+package module
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/GoCodeAlone/modular"
+)
+
+// sessionFromPipeline returns the session of the HTTP request that triggered
+// the pipeline. module names the http.session module; empty means the
+// innermost one on the route.
+func sessionFromPipeline(pc *PipelineContext, stepType, stepName, module string) (*Session, error) {
+	req, _ := pc.Metadata["_http_request"].(*http.Request)
+	if req == nil {
+		return nil, fmt.Errorf("%s step %q: no HTTP request in pipeline context", stepType, stepName)
+	}
+	s := SessionFromContext(req.Context(), module)
+	if s == nil {
+		if module != "" {
+			return nil, fmt.Errorf("%s step %q: no session from http.session module %q on this request", stepType, stepName, module)
+		}
+		return nil, fmt.Errorf("%s step %q: no session on this request; add an http.session middleware to the route", stepType, stepName)
+	}
+	return s, nil
+}
+
+// SessionGetStep reads values from the request's session. With a key it
+// outputs value and found; otherwise it outputs all values and is_new.
+type SessionGetStep struct {
+	name    string
+	session string
+	key     string
+	remove  bool // delete the key after reading it, for one-time flash values
+	tmpl    *TemplateEngine
+}
+
+// NewSessionGetStepFactory returns a StepFactory that creates SessionGetStep instances.
+func NewSessionGetStepFactory() StepFactory {
+	return func(name string, config map[string]any, _ modular.Application) (PipelineStep, error) {
+		session, _ := config["session"].(string)
+		key, _ := config["key"].(string)
+		remove, _ := config["delete"].(bool)
+		if remove && key == "" {
+			return nil, fmt.Errorf("session_get step %q: 'delete' requires 'key'", name)
+		}
+		return &SessionGetStep{
+			name:    name,
+			session: session,
+			key:     key,
+			remove:  remove,
+			tmpl:    NewTemplateEngine(),
+		}, nil
+	}
+}
+
+func (s *SessionGetStep) Name() string { return s.name }
+
+func (s *SessionGetStep) Execute(_ context.Context, pc *PipelineContext) (*StepResult, error) {
+	sess, err := sessionFromPipeline(pc, "session_get", s.name, s.session)
+	if err != nil {
+		return nil, err
+	}
+	if s.key == "" {
+		return &StepResult{Output: map[string]any{
+			"values": sess.Values(),
+			"is_new": sess.IsNew(),
+		}}, nil
+	}
+
+	key, err := s.tmpl.Resolve(s.key, pc)
+	if err != nil {
+		return nil, fmt.Errorf("session_get step %q: failed to resolve key template: %w", s.name, err)
+	}
+	value, found := sess.Get(key)
+	if found && s.remove {
+		sess.Delete(key)
+	}
+	return &StepResult{Output: map[string]any{
+		"value": value,
+		"found": found,
+	}}, nil
+}
+
+// SessionSetStep writes and deletes session values and can regenerate the
+// session ID, which should be done whenever the user's privileges change.
+type SessionSetStep struct {
+	name       string
+	session    string
+	values     map[string]any
+	remove     []string
+	regenerate bool
+	tmpl       *TemplateEngine
+}
+
+// NewSessionSetStepFactory returns a StepFactory that creates SessionSetStep instances.
+func NewSessionSetStepFactory() StepFactory {
+	return func(name string, config map[string]any, _ modular.Application) (PipelineStep, error) {
+		session, _ := config["session"].(string)
+		values, _ := config["values"].(map[string]any)
+		var remove []string
+		if raw, ok := config["delete"].([]any); ok {
+			for _, v := range raw {
+				k, ok := v.(string)
+				if !ok || k == "" {
+					return nil, fmt.Errorf("session_set step %q: 'delete' must be a list of keys", name)
+				}
+				remove = append(remove, k)
+			}
+		}
+		regenerate, _ := config["regenerate"].(bool)
+		if len(values) == 0 && len(remove) == 0 && !regenerate {
+			return nil, fmt.Errorf("session_set step %q: one of 'values', 'delete' or 'regenerate' is required", name)
+		}
+		return &SessionSetStep{
+			name:       name,
+			session:    session,
+			values:     values,
+			remove:     remove,
+			regenerate: regenerate,
+			tmpl:       NewTemplateEngine(),
+		}, nil
+	}
+}
+
+func (s *SessionSetStep) Name() string { return s.name }
+
+func (s *SessionSetStep) Execute(_ context.Context, pc *PipelineContext) (*StepResult, error) {
+	sess, err := sessionFromPipeline(pc, "session_set", s.name, s.session)
+	if err != nil {
+		return nil, err
+	}
+	values, err := s.tmpl.ResolveMap(s.values, pc)
+	if err != nil {
+		return nil, fmt.Errorf("session_set step %q: failed to resolve values: %w", s.name, err)
+	}
+
+	keys := make([]string, 0, len(values))
+	for k, v := range values {
+		sess.Set(k, v)
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range s.remove {
+		sess.Delete(k)
+	}
+	if s.regenerate {
+		sess.RegenerateID()
+	}
+	return &StepResult{Output: map[string]any{
+		"set":         keys,
+		"deleted":     append([]string{}, s.remove...),
+		"regenerated": s.regenerate,
+	}}, nil
+}
+
+// SessionDestroyStep ends the request's session, deleting its data and
+// expiring the session cookie.
+type SessionDestroyStep struct {
+	name    string
+	session string
+}
+
+// NewSessionDestroyStepFactory returns a StepFactory that creates SessionDestroyStep instances.
+func NewSessionDestroyStepFactory() StepFactory {
+	return func(name string, config map[string]any, _ modular.Application) (PipelineStep, error) {
+		session, _ := config["session"].(string)
+		return &SessionDestroyStep{name: name, session: session}, nil
+	}
+}
+
+func (s *SessionDestroyStep) Name() string { return s.name }
+
+func (s *SessionDestroyStep) Execute(_ context.Context, pc *PipelineContext) (*StepResult, error) {
+	sess, err := sessionFromPipeline(pc, "session_destroy", s.name, s.session)
+	if err != nil {
+		return nil, err
+	}
+	sess.Destroy()
+	return &StepResult{Output: map[string]any{"destroyed": true}}, nil
+}
@@ -0,0 +1,102 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// runSessionSteps runs fn inside the session middleware with a pipeline
+// context holding the request, and returns the session cookie value set.
+func runSessionSteps(t *testing.T, m *HTTPSessionModule, cookie string, fn func(pc *PipelineContext)) string {
+	t.Helper()
+	h := m.Process(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		fn(NewPipelineContext(nil, map[string]any{"_http_request": r}))
+	}))
+	_, c := sessionRequest(h, "/", cookie)
+	if c == nil {
+		return cookie
+	}
+	return c.Value
+}
+
+func TestSessionSteps(t *testing.T) {
+	m, _ := newTestSessionModule(t, map[string]any{"store": "memory"})
+	ctx := context.Background()
+
+	set, err := NewSessionSetStepFactory()("login", map[string]any{
+		"values":     map[string]any{"user_id": "{{ .user }}", "flash": "Welcome"},
+		"regenerate": true,
+	}, nil)
+	require.NoError(t, err)
+	getFlash, err := NewSessionGetStepFactory()("flash", map[string]any{"key": "flash", "delete": true}, nil)
+	require.NoError(t, err)
+	getAll, err := NewSessionGetStepFactory()("all", map[string]any{}, nil)
+	require.NoError(t, err)
+	destroy, err := NewSessionDestroyStepFactory()("logout", map[string]any{"session": "sessions"}, nil)
+	require.NoError(t, err)
+
+	var outputs []map[string]any
+	cookie := runSessionSteps(t, m, "", func(pc *PipelineContext) {
+		pc.Current["user"] = "u-42"
+		res, err := set.Execute(ctx, pc)
+		require.NoError(t, err)
+		require.Equal(t, []string{"flash", "user_id"}, res.Output["set"])
+		require.Equal(t, true, res.Output["regenerated"])
+		outputs = append(outputs, res.Output)
+	})
+	require.NotEmpty(t, cookie)
+
+	runSessionSteps(t, m, cookie, func(pc *PipelineContext) {
+		res, err := getFlash.Execute(ctx, pc)
+		require.NoError(t, err)
+		require.Equal(t, "Welcome", res.Output["value"])
+		require.Equal(t, true, res.Output["found"])
+		outputs = append(outputs, res.Output)
+	})
+
+	// The flash value was consumed by the previous request.
+	runSessionSteps(t, m, cookie, func(pc *PipelineContext) {
+		res, err := getAll.Execute(ctx, pc)
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"user_id": "u-42"}, res.Output["values"])
+		require.Equal(t, false, res.Output["is_new"])
+		outputs = append(outputs, res.Output)
+
+		res, err = destroy.Execute(ctx, pc)
+		require.NoError(t, err)
+		require.Equal(t, true, res.Output["destroyed"])
+		outputs = append(outputs, res.Output)
+	})
+
+	// Step outputs end up in execution events; the session ID must not.
+	data, err := json.Marshal(outputs)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), cookie)
+
+	runSessionSteps(t, m, cookie, func(pc *PipelineContext) {
+		res, err := getAll.Execute(ctx, pc)
+		require.NoError(t, err)
+		require.Equal(t, true, res.Output["is_new"])
+	})
+}
+
+func TestSessionSteps_Errors(t *testing.T) {
+	_, err := NewSessionSetStepFactory()("s", map[string]any{}, nil)
+	require.ErrorContains(t, err, "one of 'values', 'delete' or 'regenerate' is required")
+	_, err = NewSessionGetStepFactory()("g", map[string]any{"delete": true}, nil)
+	require.ErrorContains(t, err, "'delete' requires 'key'")
+
+	step, err := NewSessionDestroyStepFactory()("d", map[string]any{}, nil)
+	require.NoError(t, err)
+	_, err = step.Execute(context.Background(), NewPipelineContext(nil, nil))
+	require.ErrorContains(t, err, "no HTTP request")
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	_, err = step.Execute(context.Background(), NewPipelineContext(nil, map[string]any{"_http_request": req}))
+	require.ErrorContains(t, err, "add an http.session middleware")
+}
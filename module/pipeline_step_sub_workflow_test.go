@@ -7,6 +7,7 @@ import (
 
 	"github.com/GoCodeAlone/modular"
 	"github.com/GoCodeAlone/workflow/config"
+	"github.com/GoCodeAlone/workflow/observability/tracing"
 	"github.com/GoCodeAlone/workflow/plugin"
 )
 
@@ -366,3 +367,31 @@ func TestSplitDotPath(t *testing.T) {
 		}
 	}
 }
+
+func TestSubWorkflowStep_CarriesBaggage(t *testing.T) {
+	registry := plugin.NewPluginWorkflowRegistry()
+	_ = registry.Register("billing", plugin.EmbeddedWorkflow{Name: "payment-flow", Config: &config.WorkflowConfig{}})
+
+	var childArgs []any
+	builder := func(name string, _ *config.WorkflowConfig, _ modular.Application) (*Pipeline, error) {
+		return &Pipeline{Name: name, Steps: []PipelineStep{&mockStep{name: "child", execFn: func(ctx context.Context, _ *PipelineContext) (*StepResult, error) {
+			childArgs = tracing.BaggageLogArgs(ctx)
+			return &StepResult{}, nil
+		}}}}, nil
+	}
+	step, err := NewSubWorkflowStepFactory(registry, builder)("pay", map[string]any{"workflow": "billing:payment-flow"}, nil)
+	if err != nil {
+		t.Fatalf("factory error: %v", err)
+	}
+
+	ctx := tracing.WithBaggage(context.Background(), map[string]string{
+		tracing.BaggageWorkflowID:  "wf-1",
+		tracing.BaggageExecutionID: "exec-1",
+	})
+	if _, err := step.Execute(ctx, NewPipelineContext(nil, nil)); err != nil {
+		t.Fatalf("execute error: %v", err)
+	}
+	if len(childArgs) != 4 || childArgs[1] != "wf-1" || childArgs[3] != "exec-1" {
+		t.Errorf("child step baggage = %v, want the parent's workflow and execution", childArgs)
+	}
+}
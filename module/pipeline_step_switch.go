@@ -0,0 +1,255 @@
+package module
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/GoCodeAlone/modular"
+)
+
+// switchCase is one case of a SwitchStep. It matches when the switch field
+// resolves to value or, for a case with an if expression, when the
+// expression is truthy.
+type switchCase struct {
+	name         string
+	value        *string
+	ifExpr       string
+	steps        []PipelineStep
+	fallsThrough bool
+}
+
+// SwitchStep evaluates ordered cases and runs the inline sub-pipeline of the
+// first case that matches. Like a Go switch, a matching case whose
+// fallthrough is set also runs the steps of the next case without testing
+// it; fallthrough from the last case runs the default steps. The default
+// steps run when no case matches. Afterwards execution continues at
+// merge_step, or with the next pipeline step when merge_step is empty.
+//
+//	type: step.switch
+//	name: route-order
+//	config:
+//	  field: body.kind
+//	  cases:
+//	    - value: refund
+//	      steps:
+//	        - type: step.set
+//	          name: mark-refund
+//	          config: { values: { refund: true } }
+//	      fallthrough: true
+//	    - name: large
+//	      if: "{{ .body.amount > 1000 }}"
+//	      steps:
+//	        - type: step.set
+//	          name: flag-review
+//	          config: { values: { review: true } }
+//	  default:
+//	    - type: step.log
+//	      name: log-other
+//	      config: { message: "unrouted order" }
+type SwitchStep struct {
+	name         string
+	field        string
+	cases        []switchCase
+	defaultSteps []PipelineStep
+	mergeStep    string
+	tmpl         *TemplateEngine
+}
+
+// NewSwitchStepFactory returns a StepFactory that creates SwitchStep
+// instances. registryFn is called at step-creation time (lazy pattern, same
+// as step.branch) so case sub-steps can use any registered step type.
+func NewSwitchStepFactory(registryFn func() *StepRegistry) StepFactory {
+	return func(name string, config map[string]any, app modular.Application) (PipelineStep, error) {
+		field, _ := config["field"].(string)
+
+		casesRaw, ok := config["cases"].([]any)
+		if !ok || len(casesRaw) == 0 {
+			return nil, fmt.Errorf("switch step %q: 'cases' list is required and must not be empty", name)
+		}
+
+		cases := make([]switchCase, 0, len(casesRaw))
+		for i, raw := range casesRaw {
+			caseCfg, ok := raw.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("switch step %q: cases[%d] must be a map", name, i)
+			}
+			c := switchCase{name: fmt.Sprintf("case-%d", i)}
+			if n, _ := caseCfg["name"].(string); n != "" {
+				c.name = n
+			}
+			c.ifExpr, _ = caseCfg["if"].(string)
+			if v, ok := caseCfg["value"]; ok {
+				value := fmt.Sprint(v)
+				c.value = &value
+			}
+			switch {
+			case c.value != nil && c.ifExpr != "":
+				return nil, fmt.Errorf("switch step %q: case %q: 'value' and 'if' are mutually exclusive", name, c.name)
+			case c.value == nil && c.ifExpr == "":
+				return nil, fmt.Errorf("switch step %q: case %q: 'value' or 'if' is required", name, c.name)
+			case c.value != nil && field == "":
+				return nil, fmt.Errorf("switch step %q: case %q: 'field' is required for cases matching a value", name, c.name)
+			}
+			if ft, ok := caseCfg["fallthrough"]; ok {
+				b, isBool := ft.(bool)
+				if !isBool {
+					return nil, fmt.Errorf("switch step %q: case %q: 'fallthrough' must be a boolean", name, c.name)
+				}
+				c.fallsThrough = b
+			}
+			if stepsRaw, ok := caseCfg["steps"]; ok {
+				steps, err := buildSwitchSteps(name, c.name, stepsRaw, registryFn, app)
+				if err != nil {
+					return nil, err
+				}
+				c.steps = steps
+			}
+			cases = append(cases, c)
+		}
+
+		var defaultSteps []PipelineStep
+		if defaultRaw, ok := config["default"]; ok {
+			steps, err := buildSwitchSteps(name, "default", defaultRaw, registryFn, app)
+			if err != nil {
+				return nil, err
+			}
+			defaultSteps = steps
+		}
+
+		mergeStep, _ := config["merge_step"].(string)
+
+		return &SwitchStep{
+			name:         name,
+			field:        field,
+			cases:        cases,
+			defaultSteps: defaultSteps,
+			mergeStep:    mergeStep,
+			tmpl:         NewTemplateEngine(),
+		}, nil
+	}
+}
+
+// Name returns the step name.
+func (s *SwitchStep) Name() string { return s.name }
+
+// Execute finds the first matching case and runs its sub-steps, and those of
+// the cases it falls through to, sequentially on the shared PipelineContext.
+func (s *SwitchStep) Execute(ctx context.Context, pc *PipelineContext) (*StepResult, error) {
+	var resolved string
+	if s.field != "" {
+		tmplExpr := s.field
+		if !strings.Contains(s.field, "{{") {
+			tmplExpr = buildFieldTemplate(s.field)
+		}
+		var err error
+		resolved, err = s.tmpl.Resolve(tmplExpr, pc)
+		if err != nil {
+			return nil, fmt.Errorf("switch step %q: failed to resolve field %q: %w", s.name, s.field, err)
+		}
+	}
+
+	matched := -1
+	for i := range s.cases {
+		ok, err := s.matches(&s.cases[i], resolved, pc)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = i
+			break
+		}
+	}
+
+	output := map[string]any{}
+	if s.field != "" {
+		output["matched_value"] = resolved
+	}
+
+	// Collect the step lists to run: the matched case, every case reached by
+	// fallthrough, and the default when fallthrough leaves the last case.
+	var runs [][]PipelineStep
+	executed := []string{}
+	if matched < 0 {
+		if s.defaultSteps == nil {
+			return nil, fmt.Errorf("switch step %q: no case matched and no default configured", s.name)
+		}
+		output["case"] = "default"
+		output["used_default"] = true
+		runs = append(runs, s.defaultSteps)
+		executed = append(executed, "default")
+	} else {
+		output["case"] = s.cases[matched].name
+		for i := matched; ; i++ {
+			if i == len(s.cases) {
+				if s.defaultSteps != nil {
+					runs = append(runs, s.defaultSteps)
+					executed = append(executed, "default")
+				}
+				break
+			}
+			runs = append(runs, s.cases[i].steps)
+			executed = append(executed, s.cases[i].name)
+			if !s.cases[i].fallsThrough {
+				break
+			}
+		}
+	}
+	output["executed_cases"] = executed
+
+	for _, steps := range runs {
+		for _, step := range steps {
+			result, err := step.Execute(ctx, pc)
+			if err != nil {
+				return nil, fmt.Errorf("switch step %q: sub-step %q failed: %w", s.name, step.Name(), err)
+			}
+			if result != nil && result.Output != nil {
+				pc.MergeStepOutput(step.Name(), result.Output)
+			} else {
+				pc.MergeStepOutput(step.Name(), map[string]any{})
+			}
+			if result != nil && result.Stop {
+				output["stopped"] = true
+				return &StepResult{Output: output, Stop: true}, nil
+			}
+		}
+	}
+
+	return &StepResult{Output: output, NextStep: s.mergeStep}, nil
+}
+
+// matches reports whether c matches the resolved field value or, for an if
+// case, whether its expression is truthy.
+func (s *SwitchStep) matches(c *switchCase, resolved string, pc *PipelineContext) (bool, error) {
+	if c.value != nil {
+		return *c.value == resolved, nil
+	}
+	ok, err := evaluateIfExpr(s.tmpl, c.ifExpr, pc)
+	if err != nil {
+		return false, fmt.Errorf("switch step %q: case %q: failed to evaluate if expression: %w", s.name, c.name, err)
+	}
+	return ok, nil
+}
+
+// buildSwitchSteps builds the sub-steps of a case or of the default from a
+// list of step configs. An empty list is allowed so a case can do nothing
+// or only fall through.
+func buildSwitchSteps(parentName, caseName string, val any, registryFn func() *StepRegistry, app modular.Application) ([]PipelineStep, error) {
+	raw, ok := val.([]any)
+	if !ok {
+		return nil, fmt.Errorf("switch step %q: case %q steps must be a list of step configs", parentName, caseName)
+	}
+	steps := make([]PipelineStep, 0, len(raw))
+	for i, item := range raw {
+		stepCfg, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("switch step %q: case %q step[%d] must be a map", parentName, caseName, i)
+		}
+		step, err := buildSubStep(parentName, fmt.Sprintf("%s[%d]", caseName, i), stepCfg, registryFn, app)
+		if err != nil {
+			return nil, fmt.Errorf("switch step %q: case %q: %w", parentName, caseName, err)
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
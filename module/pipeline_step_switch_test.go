@@ -0,0 +1,184 @@
+package module
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/GoCodeAlone/modular"
+)
+
+// newSwitchTestFactory returns a step.switch factory whose sub-steps are
+// "test.record" steps that append their name to *ran.
+func newSwitchTestFactory(ran *[]string) StepFactory {
+	registry := NewStepRegistry()
+	registry.Register("test.record", func(name string, cfg map[string]any, _ modular.Application) (PipelineStep, error) {
+		return &funcStep{name: name, fn: func(_ context.Context, _ *PipelineContext) (*StepResult, error) {
+			*ran = append(*ran, name)
+			stop, _ := cfg["stop"].(bool)
+			return &StepResult{Output: map[string]any{"ran": true}, Stop: stop}, nil
+		}}, nil
+	})
+	return NewSwitchStepFactory(func() *StepRegistry { return registry })
+}
+
+// funcStep is a PipelineStep backed by a function.
+type funcStep struct {
+	name string
+	fn   func(context.Context, *PipelineContext) (*StepResult, error)
+}
+
+func (s *funcStep) Name() string { return s.name }
+func (s *funcStep) Execute(ctx context.Context, pc *PipelineContext) (*StepResult, error) {
+	return s.fn(ctx, pc)
+}
+
+func recordSteps(names ...string) []any {
+	steps := make([]any, 0, len(names))
+	for _, n := range names {
+		steps = append(steps, map[string]any{"type": "test.record", "name": n})
+	}
+	return steps
+}
+
+func switchTestConfig() map[string]any {
+	return map[string]any{
+		"field": "kind",
+		"cases": []any{
+			map[string]any{"value": "refund", "steps": recordSteps("refund-1", "refund-2"), "fallthrough": true},
+			map[string]any{"name": "large", "if": "{{ .amount > 1000 }}", "steps": recordSteps("review")},
+			map[string]any{"name": "vip", "if": `${ tier == "vip" }`, "steps": recordSteps("vip"), "fallthrough": true},
+		},
+		"default":    recordSteps("other"),
+		"merge_step": "respond",
+	}
+}
+
+func TestSwitchStep_Cases(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    map[string]any
+		wantRan  []string
+		wantCase string
+		wantExec []string
+	}{
+		{
+			name:     "value case falls through into next case",
+			input:    map[string]any{"kind": "refund", "amount": 10},
+			wantRan:  []string{"refund-1", "refund-2", "review"},
+			wantCase: "case-0",
+			wantExec: []string{"case-0", "large"},
+		},
+		{
+			name:     "first matching if case wins",
+			input:    map[string]any{"kind": "order", "amount": 5000, "tier": "vip"},
+			wantRan:  []string{"review"},
+			wantCase: "large",
+			wantExec: []string{"large"},
+		},
+		{
+			name:     "fallthrough from last case runs default",
+			input:    map[string]any{"kind": "order", "amount": 5, "tier": "vip"},
+			wantRan:  []string{"vip", "other"},
+			wantCase: "vip",
+			wantExec: []string{"vip", "default"},
+		},
+		{
+			name:     "no match runs default",
+			input:    map[string]any{"kind": "order", "amount": 5},
+			wantRan:  []string{"other"},
+			wantCase: "default",
+			wantExec: []string{"default"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ran []string
+			step, err := newSwitchTestFactory(&ran)("route", switchTestConfig(), nil)
+			if err != nil {
+				t.Fatalf("factory error: %v", err)
+			}
+			pc := NewPipelineContext(tt.input, nil)
+			result, err := step.Execute(context.Background(), pc)
+			if err != nil {
+				t.Fatalf("execute: %v", err)
+			}
+			if !slices.Equal(ran, tt.wantRan) {
+				t.Errorf("ran = %v, want %v", ran, tt.wantRan)
+			}
+			if result.Output["case"] != tt.wantCase {
+				t.Errorf("case = %v, want %s", result.Output["case"], tt.wantCase)
+			}
+			if got := result.Output["executed_cases"].([]string); !slices.Equal(got, tt.wantExec) {
+				t.Errorf("executed_cases = %v, want %v", got, tt.wantExec)
+			}
+			if result.NextStep != "respond" {
+				t.Errorf("NextStep = %q, want respond", result.NextStep)
+			}
+			if _, ok := pc.StepOutputs[tt.wantRan[0]]; !ok {
+				t.Errorf("output of sub-step %q not merged into the pipeline context", tt.wantRan[0])
+			}
+		})
+	}
+}
+
+func TestSwitchStep_NoMatchWithoutDefault(t *testing.T) {
+	var ran []string
+	cfg := switchTestConfig()
+	delete(cfg, "default")
+	step, err := newSwitchTestFactory(&ran)("route", cfg, nil)
+	if err != nil {
+		t.Fatalf("factory error: %v", err)
+	}
+	_, err = step.Execute(context.Background(), NewPipelineContext(map[string]any{"kind": "order", "amount": 1}, nil))
+	if err == nil || !strings.Contains(err.Error(), "no case matched") {
+		t.Fatalf("err = %v, want no case matched", err)
+	}
+}
+
+func TestSwitchStep_SubStepStops(t *testing.T) {
+	var ran []string
+	step, err := newSwitchTestFactory(&ran)("route", map[string]any{
+		"cases": []any{
+			map[string]any{"if": "true", "fallthrough": true, "steps": []any{
+				map[string]any{"type": "test.record", "name": "halt", "config": map[string]any{"stop": true}},
+			}},
+			map[string]any{"if": "true", "steps": recordSteps("never")},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("factory error: %v", err)
+	}
+	result, err := step.Execute(context.Background(), NewPipelineContext(nil, nil))
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if !result.Stop || !slices.Equal(ran, []string{"halt"}) {
+		t.Errorf("Stop = %v, ran = %v; want a stop after halt", result.Stop, ran)
+	}
+}
+
+func TestSwitchStep_FactoryErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     map[string]any
+		wantErr string
+	}{
+		{"missing cases", map[string]any{"field": "kind"}, "'cases' list is required"},
+		{"case without condition", map[string]any{"field": "kind", "cases": []any{map[string]any{"steps": recordSteps("a")}}}, "'value' or 'if' is required"},
+		{"value and if", map[string]any{"field": "kind", "cases": []any{map[string]any{"value": "a", "if": "true"}}}, "mutually exclusive"},
+		{"value without field", map[string]any{"cases": []any{map[string]any{"value": "a"}}}, "'field' is required"},
+		{"bad fallthrough", map[string]any{"cases": []any{map[string]any{"if": "true", "fallthrough": "yes"}}}, "'fallthrough' must be a boolean"},
+		{"unknown sub-step type", map[string]any{"cases": []any{map[string]any{"if": "true", "steps": []any{map[string]any{"type": "step.nope"}}}}}, `case "case-0"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ran []string
+			_, err := newSwitchTestFactory(&ran)("route", tt.cfg, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package module
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow/artifact"
+)
+
+// TestReportStep parses test results (JUnit XML or go test -json) into a
+// summary, stores the summary as an execution artifact, and fails when
+// failures exceed a threshold. Results are read from files, an artifact of
+// the current execution, or a value in the pipeline context such as the
+// stdout of a step.shell_exec command.
+type TestReportStep struct {
+	name        string
+	format      string
+	path        string
+	artifactKey string
+	from        string
+	maxFailures int
+	minTests    int
+	reportKey   string
+}
+
+// NewTestReportStepFactory returns a StepFactory that creates TestReportStep instances.
+func NewTestReportStepFactory() StepFactory {
+	return func(name string, config map[string]any, _ modular.Application) (PipelineStep, error) {
+		s := &TestReportStep{name: name, reportKey: "test-report.json"}
+		s.path, _ = config["path"].(string)
+		s.artifactKey, _ = config["artifact"].(string)
+		s.from, _ = config["from"].(string)
+		sources := 0
+		for _, v := range []string{s.path, s.artifactKey, s.from} {
+			if v != "" {
+				sources++
+			}
+		}
+		if sources != 1 {
+			return nil, fmt.Errorf("test_report step %q: exactly one of 'path', 'artifact' or 'from' is required", name)
+		}
+
+		s.format, _ = config["format"].(string)
+		switch s.format {
+		case "", "auto", TestReportFormatJUnit, TestReportFormatGoTestJSON:
+		default:
+			return nil, fmt.Errorf("test_report step %q: unsupported format %q (supported: auto, %s, %s)", name, s.format, TestReportFormatJUnit, TestReportFormatGoTestJSON)
+		}
+
+		var err error
+		if s.maxFailures, err = nonNegativeIntConfig(config, "max_failures"); err != nil {
+			return nil, fmt.Errorf("test_report step %q: %w", name, err)
+		}
+		if s.minTests, err = nonNegativeIntConfig(config, "min_tests"); err != nil {
+			return nil, fmt.Errorf("test_report step %q: %w", name, err)
+		}
+		if key, ok := config["report_key"].(string); ok {
+			s.reportKey = key
+		}
+		return s, nil
+	}
+}
+
+func nonNegativeIntConfig(config map[string]any, key string) (int, error) {
+	var n int
+	switch v := config[key].(type) {
+	case nil:
+		return 0, nil
+	case int:
+		n = v
+	case float64:
+		n = int(v)
+	default:
+		return 0, fmt.Errorf("'%s' must be a number", key)
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("'%s' must not be negative", key)
+	}
+	return n, nil
+}
+
+// Name returns the step name.
+func (s *TestReportStep) Name() string { return s.name }
+
+// Execute parses the results, stores the report and evaluates the gate.
+func (s *TestReportStep) Execute(ctx context.Context, pc *PipelineContext) (*StepResult, error) {
+	inputs, err := s.readInputs(ctx, pc)
+	if err != nil {
+		return nil, fmt.Errorf("test_report step %q: %w", s.name, err)
+	}
+	var report *TestReport
+	for _, in := range inputs {
+		r, err := ParseTestReport(s.format, in.data)
+		if err != nil {
+			return nil, fmt.Errorf("test_report step %q: %s: %w", s.name, in.source, err)
+		}
+		if report == nil {
+			report = r
+		} else {
+			if report.Format != r.Format {
+				report.Format = "mixed"
+			}
+			report.Merge(r)
+		}
+	}
+
+	passed := report.Summary.Failed <= s.maxFailures && report.Summary.Total >= s.minTests
+	output := map[string]any{
+		"passed":   passed,
+		"format":   report.Format,
+		"summary":  report.Summary,
+		"suites":   report.Suites,
+		"failures": report.Failures,
+	}
+	key, err := s.storeReport(ctx, pc, report)
+	if err != nil {
+		return nil, fmt.Errorf("test_report step %q: %w", s.name, err)
+	}
+	if key != "" {
+		output["report_artifact"] = key
+	}
+
+	if !passed {
+		if report.Summary.Total < s.minTests {
+			return nil, fmt.Errorf("test_report step %q: %d tests ran, fewer than min_tests %d", s.name, report.Summary.Total, s.minTests)
+		}
+		return nil, fmt.Errorf("test_report step %q: %d of %d tests failed (max_failures: %d)", s.name, report.Summary.Failed, report.Summary.Total, s.maxFailures)
+	}
+	return &StepResult{Output: output}, nil
+}
+
+type testReportInput struct {
+	source string
+	data   []byte
+}
+
+func (s *TestReportStep) readInputs(ctx context.Context, pc *PipelineContext) ([]testReportInput, error) {
+	switch {
+	case s.from != "":
+		v := resolveBodyFrom(s.from, pc)
+		switch v := v.(type) {
+		case string:
+			return []testReportInput{{source: s.from, data: []byte(v)}}, nil
+		case []byte:
+			return []testReportInput{{source: s.from, data: v}}, nil
+		case nil:
+			return nil, fmt.Errorf("'from' path %q not found in pipeline context", s.from)
+		default:
+			return nil, fmt.Errorf("'from' path %q is a %T, not test output text", s.from, v)
+		}
+	case s.artifactKey != "":
+		store, _ := pc.Metadata["artifact_store"].(artifact.Store)
+		executionID, _ := pc.Metadata["execution_id"].(string)
+		if store == nil || executionID == "" {
+			return nil, fmt.Errorf("artifact store or execution_id not found in pipeline metadata")
+		}
+		rc, err := store.Get(ctx, executionID, s.artifactKey)
+		if err != nil {
+			return nil, fmt.Errorf("read artifact %q: %w", s.artifactKey, err)
+		}
+		defer rc.Close()
+		data, err := io.ReadAll(rc)
+		if err != nil {
+			return nil, fmt.Errorf("read artifact %q: %w", s.artifactKey, err)
+		}
+		return []testReportInput{{source: "artifact " + s.artifactKey, data: data}}, nil
+	default:
+		// path may be a glob so split JUnit reports can be read together.
+		matches, err := filepath.Glob(s.path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path pattern %q: %w", s.path, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no test results match %q", s.path)
+		}
+		sort.Strings(matches)
+		inputs := make([]testReportInput, 0, len(matches))
+		for _, m := range matches {
+			data, err := os.ReadFile(m) //nolint:gosec // G304: path from step configuration
+			if err != nil {
+				return nil, fmt.Errorf("read %s: %w", m, err)
+			}
+			inputs = append(inputs, testReportInput{source: m, data: data})
+		}
+		return inputs, nil
+	}
+}
+
+// storeReport saves the report as JSON in the execution's artifact store so
+// it stays attached to the run. Without an artifact store, or with an empty
+// report_key, it is not stored.
+func (s *TestReportStep) storeReport(ctx context.Context, pc *PipelineContext, report *TestReport) (string, error) {
+	store, _ := pc.Metadata["artifact_store"].(artifact.Store)
+	executionID, _ := pc.Metadata["execution_id"].(string)
+	if s.reportKey == "" || store == nil || executionID == "" {
+		return "", nil
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		return "", fmt.Errorf("encode report: %w", err)
+	}
+	if err := store.Put(ctx, executionID, s.reportKey, bytes.NewReader(data)); err != nil {
+		return "", fmt.Errorf("store report artifact %q: %w", s.reportKey, err)
+	}
+	return s.reportKey, nil
+}
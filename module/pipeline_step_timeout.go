@@ -0,0 +1,41 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/interfaces"
+)
+
+// TimeoutStep wraps a PipelineStep and bounds its execution by the step's
+// timeout setting. The deadline is carried on the context, so steps that
+// make calls to other processes (HTTP, gRPC plugins) pass it on.
+type TimeoutStep struct {
+	inner   interfaces.PipelineStep
+	timeout time.Duration
+}
+
+// NewTimeoutStep wraps inner so that each execution is cancelled after
+// timeout.
+func NewTimeoutStep(inner interfaces.PipelineStep, timeout time.Duration) *TimeoutStep {
+	return &TimeoutStep{inner: inner, timeout: timeout}
+}
+
+// Name delegates to the wrapped step.
+func (s *TimeoutStep) Name() string {
+	return s.inner.Name()
+}
+
+// Execute runs the wrapped step with the timeout applied. An error caused
+// by this step's timeout, rather than the caller's, says so.
+func (s *TimeoutStep) Execute(ctx context.Context, pc *PipelineContext) (*interfaces.StepResult, error) {
+	stepCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	result, err := s.inner.Execute(stepCtx, pc)
+	if err != nil && ctx.Err() == nil && errors.Is(stepCtx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("step %q timed out after %s: %w", s.inner.Name(), s.timeout, err)
+	}
+	return result, err
+}
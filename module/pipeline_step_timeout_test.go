@@ -0,0 +1,41 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeoutStep(t *testing.T) {
+	slow := &mockStep{name: "slow", execFn: func(ctx context.Context, _ *PipelineContext) (*StepResult, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			return nil, errors.New("no deadline on step context")
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+			return &StepResult{Output: map[string]any{"done": true}}, nil
+		}
+	}}
+	pc := NewPipelineContext(nil, nil)
+
+	_, err := NewTimeoutStep(slow, 10*time.Millisecond).Execute(context.Background(), pc)
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), `step "slow" timed out after 10ms`) {
+		t.Fatalf("error = %v", err)
+	}
+
+	res, err := NewTimeoutStep(slow, 5*time.Second).Execute(context.Background(), pc)
+	if err != nil || res.Output["done"] != true {
+		t.Fatalf("within timeout: %+v, %v", res, err)
+	}
+
+	// A caller's cancellation is reported as is.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewTimeoutStep(slow, time.Second).Execute(ctx, pc); !errors.Is(err, context.Canceled) || strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("cancelled: error = %v", err)
+	}
+}
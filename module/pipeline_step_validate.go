@@ -2,6 +2,7 @@ package module
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -9,12 +10,13 @@ import (
 )
 
 // ValidateStep validates data in the pipeline context against a schema or
-// a list of required fields.
+// a list of required fields, and optionally cross-field constraints.
 type ValidateStep struct {
 	name           string
 	strategy       string
 	requiredFields []string
 	schema         map[string]any
+	constraints    []validationConstraint
 	source         string // optional dotted path to validate (e.g. "steps.parse-request.body")
 }
 
@@ -34,6 +36,14 @@ func NewValidateStepFactory() StepFactory {
 			source:   source,
 		}
 
+		if raw, ok := config["constraints"]; ok {
+			constraints, err := parseValidationConstraints(raw)
+			if err != nil {
+				return nil, fmt.Errorf("validate step %q: %w", name, err)
+			}
+			step.constraints = constraints
+		}
+
 		switch strategy {
 		case "json_schema":
 			schema, ok := config["schema"].(map[string]any)
@@ -43,8 +53,8 @@ func NewValidateStepFactory() StepFactory {
 			step.schema = schema
 		case "required_fields":
 			rawFields, _ := config["required_fields"].([]any)
-			if len(rawFields) == 0 {
-				return nil, fmt.Errorf("validate step %q: required_fields strategy requires a non-empty 'required_fields' list", name)
+			if len(rawFields) == 0 && len(step.constraints) == 0 {
+				return nil, fmt.Errorf("validate step %q: required_fields strategy requires a non-empty 'required_fields' list or 'constraints'", name)
 			}
 			fields := make([]string, 0, len(rawFields))
 			for _, f := range rawFields {
@@ -115,6 +125,8 @@ func (s *ValidateStep) resolveSource(pc *PipelineContext) map[string]any {
 }
 
 // executeRequiredFields checks that every listed field exists in the source data.
+// With constraints, missing fields and constraint violations are reported
+// together as one validation_failed problem.
 func (s *ValidateStep) executeRequiredFields(pc *PipelineContext) (*StepResult, error) {
 	data := s.resolveSource(pc)
 	if data == nil {
@@ -126,6 +138,13 @@ func (s *ValidateStep) executeRequiredFields(pc *PipelineContext) (*StepResult,
 			missing = append(missing, field)
 		}
 	}
+	if len(s.constraints) > 0 {
+		violations := make([]map[string]string, 0, len(missing))
+		for _, field := range missing {
+			violations = append(violations, map[string]string{"field": field, "code": "required", "message": field + " is required"})
+		}
+		return s.checkConstraints(data, violations)
+	}
 	if len(missing) > 0 {
 		return nil, fmt.Errorf("validate step %q: missing required fields: %s", s.name, strings.Join(missing, ", "))
 	}
@@ -138,11 +157,40 @@ func (s *ValidateStep) executeJSONSchema(pc *PipelineContext) (*StepResult, erro
 	if data == nil {
 		return nil, fmt.Errorf("validate step %q: source %q resolved to nil", s.name, s.source)
 	}
+	err := checkJSONSchemaObject(data, s.schema)
+	if len(s.constraints) > 0 {
+		var violations []map[string]string
+		if err != nil {
+			violations = append(violations, map[string]string{"code": "schema", "message": err.Error()})
+		}
+		return s.checkConstraints(data, violations)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("validate step %q: %w", s.name, err)
+	}
+	return &StepResult{Output: map[string]any{}}, nil
+}
+
+// checkConstraints adds the constraint violations in data to the strategy's
+// violations and fails with a problem listing all of them, if any.
+func (s *ValidateStep) checkConstraints(data map[string]any, violations []map[string]string) (*StepResult, error) {
+	for _, c := range s.constraints {
+		violations = append(violations, c.check(data)...)
+	}
+	if len(violations) > 0 {
+		return nil, fmt.Errorf("validate step %q: %w", s.name, constraintProblem(violations))
+	}
+	return &StepResult{Output: map[string]any{}}, nil
+}
+
+// checkJSONSchemaObject performs a basic type/required/properties check of data
+// against a JSON Schema. It is shared by step.validate and step.validate_response.
+func checkJSONSchemaObject(data, schema map[string]any) error {
 	// Check required fields from the schema
-	if requiredRaw, ok := s.schema["required"]; ok {
+	if requiredRaw, ok := schema["required"]; ok {
 		requiredList, ok := requiredRaw.([]any)
 		if !ok {
-			return nil, fmt.Errorf("validate step %q: schema 'required' must be an array", s.name)
+			return errors.New("schema 'required' must be an array")
 		}
 		var missing []string
 		for _, r := range requiredList {
@@ -155,15 +203,15 @@ func (s *ValidateStep) executeJSONSchema(pc *PipelineContext) (*StepResult, erro
 			}
 		}
 		if len(missing) > 0 {
-			return nil, fmt.Errorf("validate step %q: missing required fields: %s", s.name, strings.Join(missing, ", "))
+			return fmt.Errorf("missing required fields: %s", strings.Join(missing, ", "))
 		}
 	}
 
 	// Check property types if a properties section is provided
-	if propsRaw, ok := s.schema["properties"]; ok {
+	if propsRaw, ok := schema["properties"]; ok {
 		props, ok := propsRaw.(map[string]any)
 		if !ok {
-			return nil, fmt.Errorf("validate step %q: schema 'properties' must be a map", s.name)
+			return errors.New("schema 'properties' must be a map")
 		}
 		for field, specRaw := range props {
 			val, exists := data[field]
@@ -180,12 +228,12 @@ func (s *ValidateStep) executeJSONSchema(pc *PipelineContext) (*StepResult, erro
 				continue
 			}
 			if err := checkJSONType(field, val, expectedType); err != nil {
-				return nil, fmt.Errorf("validate step %q: %w", s.name, err)
+				return err
 			}
 		}
 	}
 
-	return &StepResult{Output: map[string]any{}}, nil
+	return nil
 }
 
 // checkJSONType validates that val conforms to the given JSON Schema type name.
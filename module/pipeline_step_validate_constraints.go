@@ -0,0 +1,182 @@
+package module
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Cross-field constraint kinds of step.validate.
+const (
+	constraintOneOf             = "one_of"
+	constraintRequiredTogether  = "required_together"
+	constraintMutuallyExclusive = "mutually_exclusive"
+	constraintRequiredIf        = "required_if"
+)
+
+// validationConstraint is a cross-field rule of a validate step. Fields are
+// dotted paths into the validated data; a field is present when it exists
+// and is not null.
+type validationConstraint struct {
+	kind   string
+	fields []string
+	// required_if only: fields are required when the value at when equals
+	// equals, compared as text.
+	when   string
+	equals any
+}
+
+// parseValidationConstraints parses the 'constraints' list of a validate
+// step. Each entry has exactly one key naming its kind:
+//
+//   - one_of: [card_token, bank_account]
+//   - required_together: [street, city, zip]
+//   - mutually_exclusive: [coupon, gift_card]
+//   - required_if: {field: type, equals: business, fields: [tax_id]}
+func parseValidationConstraints(raw any) ([]validationConstraint, error) {
+	list, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("'constraints' must be a list")
+	}
+	out := make([]validationConstraint, 0, len(list))
+	for i, entry := range list {
+		m, ok := entry.(map[string]any)
+		if !ok || len(m) != 1 {
+			return nil, fmt.Errorf("constraints[%d]: must be a map with exactly one of one_of, required_together, mutually_exclusive or required_if", i)
+		}
+		for kind, v := range m {
+			c, err := parseValidationConstraint(kind, v)
+			if err != nil {
+				return nil, fmt.Errorf("constraints[%d]: %w", i, err)
+			}
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func parseValidationConstraint(kind string, v any) (validationConstraint, error) {
+	c := validationConstraint{kind: kind}
+	switch kind {
+	case constraintOneOf, constraintRequiredTogether, constraintMutuallyExclusive:
+		fields, err := constraintFieldList(v)
+		if err != nil {
+			return c, fmt.Errorf("%s: %w", kind, err)
+		}
+		if len(fields) < 2 {
+			return c, fmt.Errorf("%s: needs at least two fields", kind)
+		}
+		c.fields = fields
+	case constraintRequiredIf:
+		m, ok := v.(map[string]any)
+		if !ok {
+			return c, fmt.Errorf("required_if: must be a map with field, equals and fields")
+		}
+		c.when, _ = m["field"].(string)
+		if c.when == "" {
+			return c, fmt.Errorf("required_if: 'field' is required")
+		}
+		var exists bool
+		if c.equals, exists = m["equals"]; !exists {
+			return c, fmt.Errorf("required_if: 'equals' is required")
+		}
+		fields, err := constraintFieldList(m["fields"])
+		if err != nil {
+			return c, fmt.Errorf("required_if: %w", err)
+		}
+		if len(fields) == 0 {
+			return c, fmt.Errorf("required_if: 'fields' must not be empty")
+		}
+		c.fields = fields
+	default:
+		return c, fmt.Errorf("unknown constraint %q (expected one_of, required_together, mutually_exclusive or required_if)", kind)
+	}
+	return c, nil
+}
+
+func constraintFieldList(v any) ([]string, error) {
+	list, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("fields must be a list of strings")
+	}
+	fields := make([]string, 0, len(list))
+	for _, f := range list {
+		s, ok := f.(string)
+		if !ok || s == "" {
+			return nil, fmt.Errorf("fields must be a list of strings")
+		}
+		fields = append(fields, s)
+	}
+	return fields, nil
+}
+
+// fieldPresent reports whether the dotted path exists in data with a
+// non-null value, and returns the value.
+func fieldPresent(data map[string]any, path string) (any, bool) {
+	v, err := resolveDottedPath(data, path)
+	if err != nil || v == nil {
+		return nil, false
+	}
+	return v, true
+}
+
+// check returns the constraint's violations in data as problem error entries
+// with the field, the constraint kind as code, and a message.
+func (c validationConstraint) check(data map[string]any) []map[string]string {
+	var present, missing []string
+	for _, f := range c.fields {
+		if _, ok := fieldPresent(data, f); ok {
+			present = append(present, f)
+		} else {
+			missing = append(missing, f)
+		}
+	}
+	violation := func(field, msg string) map[string]string {
+		return map[string]string{"field": field, "code": c.kind, "message": msg}
+	}
+	all := strings.Join(c.fields, ", ")
+
+	switch c.kind {
+	case constraintOneOf:
+		switch {
+		case len(present) == 0:
+			return []map[string]string{violation(all, "exactly one of "+all+" is required")}
+		case len(present) > 1:
+			return []map[string]string{violation(strings.Join(present, ", "), "exactly one of "+all+" is allowed, got "+strings.Join(present, ", "))}
+		}
+	case constraintMutuallyExclusive:
+		if len(present) > 1 {
+			return []map[string]string{violation(strings.Join(present, ", "), strings.Join(present, ", ")+" cannot be used together")}
+		}
+	case constraintRequiredTogether:
+		if len(present) > 0 && len(missing) > 0 {
+			out := make([]map[string]string, len(missing))
+			for i, f := range missing {
+				out[i] = violation(f, fmt.Sprintf("%s is required together with %s", f, strings.Join(present, ", ")))
+			}
+			return out
+		}
+	case constraintRequiredIf:
+		v, ok := fieldPresent(data, c.when)
+		if !ok || fmt.Sprint(v) != fmt.Sprint(c.equals) {
+			return nil
+		}
+		out := make([]map[string]string, len(missing))
+		for i, f := range missing {
+			out[i] = violation(f, fmt.Sprintf("%s is required when %s is %v", f, c.when, c.equals))
+		}
+		return out
+	}
+	return nil
+}
+
+// constraintProblem returns a validation_failed problem listing every
+// violation; its detail joins their messages.
+func constraintProblem(violations []map[string]string) *Problem {
+	msgs := make([]string, len(violations))
+	for i, v := range violations {
+		msgs[i] = v["message"]
+	}
+	p := NewProblem(ProblemValidationFailed, strings.Join(msgs, "; "))
+	p.Errors = violations
+	return p
+}
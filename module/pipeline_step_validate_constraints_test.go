@@ -0,0 +1,147 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func newConstraintStep(t *testing.T, config map[string]any) PipelineStep {
+	t.Helper()
+	step, err := NewValidateStepFactory()("check-payment", config, nil)
+	if err != nil {
+		t.Fatalf("factory error: %v", err)
+	}
+	return step
+}
+
+var paymentConstraints = []any{
+	map[string]any{"one_of": []any{"card_token", "bank_account"}},
+	map[string]any{"required_together": []any{"billing.street", "billing.city", "billing.zip"}},
+	map[string]any{"mutually_exclusive": []any{"coupon", "gift_card"}},
+	map[string]any{"required_if": map[string]any{"field": "type", "equals": "business", "fields": []any{"tax_id", "company_name"}}},
+}
+
+func TestValidateStep_Constraints(t *testing.T) {
+	step := newConstraintStep(t, map[string]any{"constraints": paymentConstraints})
+
+	tests := []struct {
+		name string
+		data map[string]any
+		want []map[string]string // field -> code of each violation, in order
+	}{
+		{
+			name: "valid",
+			data: map[string]any{"card_token": "tok", "type": "business", "tax_id": "1", "company_name": "Acme",
+				"billing": map[string]any{"street": "1 Main", "city": "X", "zip": "1"}},
+		},
+		{
+			name: "valid without optional groups",
+			data: map[string]any{"bank_account": "ba", "type": "personal", "coupon": nil, "gift_card": "g"},
+		},
+		{
+			name: "none of one_of",
+			data: map[string]any{},
+			want: []map[string]string{{"field": "card_token, bank_account", "code": "one_of"}},
+		},
+		{
+			name: "every violation at once",
+			data: map[string]any{"card_token": "t", "bank_account": "b", "coupon": "c", "gift_card": "g",
+				"type": "business", "tax_id": "1", "billing": map[string]any{"city": "X"}},
+			want: []map[string]string{
+				{"field": "card_token, bank_account", "code": "one_of"},
+				{"field": "billing.street", "code": "required_together"},
+				{"field": "billing.zip", "code": "required_together"},
+				{"field": "coupon, gift_card", "code": "mutually_exclusive"},
+				{"field": "company_name", "code": "required_if"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := step.Execute(context.Background(), NewPipelineContext(tt.data, nil))
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var p *Problem
+			if !errors.As(err, &p) {
+				t.Fatalf("expected a problem, got %v", err)
+			}
+			if p.Code != string(ProblemValidationFailed) || p.Status != http.StatusBadRequest {
+				t.Errorf("problem = %s %d", p.Code, p.Status)
+			}
+			got, _ := p.Errors.([]map[string]string)
+			if len(got) != len(tt.want) {
+				t.Fatalf("violations = %v, want %v", got, tt.want)
+			}
+			for i, w := range tt.want {
+				if got[i]["field"] != w["field"] || got[i]["code"] != w["code"] || got[i]["message"] == "" {
+					t.Errorf("violation %d = %v, want %v", i, got[i], w)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateStep_ConstraintsWithStrategies(t *testing.T) {
+	// Missing required fields are reported with the constraint violations.
+	step := newConstraintStep(t, map[string]any{
+		"required_fields": []any{"amount"},
+		"constraints":     []any{map[string]any{"one_of": []any{"card_token", "bank_account"}}},
+	})
+	_, err := step.Execute(context.Background(), NewPipelineContext(map[string]any{}, nil))
+	p := ProblemFromError(err)
+	if errs, _ := p.Errors.([]map[string]string); len(errs) != 2 || errs[0]["code"] != "required" || errs[0]["field"] != "amount" {
+		t.Errorf("errors = %v", p.Errors)
+	}
+	if !strings.Contains(p.Detail, "amount is required") {
+		t.Errorf("detail = %q", p.Detail)
+	}
+
+	// Constraints apply to the source, like the strategy.
+	step = newConstraintStep(t, map[string]any{
+		"strategy":    "json_schema",
+		"source":      "steps.parse.body",
+		"schema":      map[string]any{"properties": map[string]any{"amount": map[string]any{"type": "number"}}},
+		"constraints": []any{map[string]any{"required_if": map[string]any{"field": "amount", "equals": 0, "fields": []any{"reason"}}}},
+	})
+	pc := NewPipelineContext(nil, nil)
+	pc.MergeStepOutput("parse", map[string]any{"body": map[string]any{"amount": float64(0)}})
+	_, err = step.Execute(context.Background(), pc)
+	if p := ProblemFromError(err); !strings.Contains(p.Detail, "reason is required when amount is 0") {
+		t.Errorf("detail = %q", p.Detail)
+	}
+	pc.MergeStepOutput("parse", map[string]any{"body": map[string]any{"amount": "x", "reason": "r"}})
+	_, err = step.Execute(context.Background(), pc)
+	if errs, _ := ProblemFromError(err).Errors.([]map[string]string); len(errs) != 1 || errs[0]["code"] != "schema" {
+		t.Errorf("schema violation = %v", errs)
+	}
+}
+
+func TestValidateStep_ConstraintConfigErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		constraints any
+		want        string
+	}{
+		{"not a list", map[string]any{}, "must be a list"},
+		{"two kinds", []any{map[string]any{"one_of": []any{"a", "b"}, "mutually_exclusive": []any{"a", "b"}}}, "exactly one"},
+		{"unknown kind", []any{map[string]any{"any_of": []any{"a", "b"}}}, "unknown constraint"},
+		{"one field", []any{map[string]any{"one_of": []any{"a"}}}, "at least two"},
+		{"required_if without equals", []any{map[string]any{"required_if": map[string]any{"field": "a", "fields": []any{"b"}}}}, "'equals'"},
+		{"required_if without fields", []any{map[string]any{"required_if": map[string]any{"field": "a", "equals": "x"}}}, "fields"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewValidateStepFactory()("v", map[string]any{"constraints": tt.constraints}, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
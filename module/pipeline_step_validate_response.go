@@ -0,0 +1,123 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow/interfaces"
+)
+
+// ValidateResponseStep checks the response a pipeline is about to send against
+// a JSON Schema, so that a pipeline bug cannot ship a body that breaks the
+// documented contract. The body is taken from body_from when set, otherwise
+// from the response_body context key used by the HTTP trigger.
+type ValidateResponseStep struct {
+	name     string
+	schema   map[string]any
+	bodyFrom string
+	warnOnly bool
+}
+
+// NewValidateResponseStepFactory returns a StepFactory that creates
+// ValidateResponseStep instances.
+func NewValidateResponseStepFactory() StepFactory {
+	return func(name string, config map[string]any, _ modular.Application) (PipelineStep, error) {
+		schema, ok := config["schema"].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("validate_response step %q: 'schema' map is required", name)
+		}
+		bodyFrom, _ := config["body_from"].(string)
+		warnOnly, _ := config["warn_only"].(bool)
+
+		return &ValidateResponseStep{
+			name:     name,
+			schema:   schema,
+			bodyFrom: bodyFrom,
+			warnOnly: warnOnly,
+		}, nil
+	}
+}
+
+// Name returns the step name.
+func (s *ValidateResponseStep) Name() string { return s.name }
+
+// Execute validates the pending response body. A non-conforming body fails the
+// pipeline with a 500 whose message does not expose the violation; the detail
+// is logged instead. In warn_only mode the violation is only logged and the
+// pipeline continues.
+func (s *ValidateResponseStep) Execute(_ context.Context, pc *PipelineContext) (*StepResult, error) {
+	err := s.validate(s.resolveBody(pc))
+	if err == nil {
+		return &StepResult{Output: map[string]any{"valid": true}}, nil
+	}
+
+	if s.warnOnly {
+		slog.Warn("validate_response: response does not match its schema",
+			"step", s.name, "body_from", s.bodyFrom, "error", err)
+		return &StepResult{Output: map[string]any{"valid": false, "error": err.Error()}}, nil
+	}
+
+	slog.Error("validate_response: response does not match its schema",
+		"step", s.name, "body_from", s.bodyFrom, "error", err)
+	return nil, &interfaces.ValidationError{
+		Message: fmt.Sprintf("validate_response step %q: response does not match its schema", s.name),
+		Status:  http.StatusInternalServerError,
+		Code:    "response_contract_violation",
+	}
+}
+
+// resolveBody returns the pending response body. String bodies (as stored in
+// response_body) are decoded as JSON; a string that is not valid JSON is
+// returned unchanged and fails any object or array schema.
+func (s *ValidateResponseStep) resolveBody(pc *PipelineContext) any {
+	var body any
+	if s.bodyFrom != "" {
+		body = resolveBodyFrom(s.bodyFrom, pc)
+	} else {
+		body = pc.Current["response_body"]
+	}
+	if str, ok := body.(string); ok {
+		var decoded any
+		if err := json.Unmarshal([]byte(str), &decoded); err == nil {
+			return decoded
+		}
+	}
+	return body
+}
+
+// validate checks body against the step's schema using the same type,
+// required and properties rules as step.validate.
+func (s *ValidateResponseStep) validate(body any) error {
+	if body == nil {
+		return errors.New("no response body found")
+	}
+	if expected, _ := s.schema["type"].(string); expected != "" {
+		if err := checkJSONType("body", body, expected); err != nil {
+			return err
+		}
+	}
+	if obj, ok := body.(map[string]any); ok {
+		return checkJSONSchemaObject(obj, s.schema)
+	}
+	if items, ok := s.schema["items"].(map[string]any); ok {
+		list, _ := body.([]any)
+		for i, item := range list {
+			if expected, _ := items["type"].(string); expected != "" {
+				if err := checkJSONType(fmt.Sprintf("body[%d]", i), item, expected); err != nil {
+					return err
+				}
+			}
+			if obj, ok := item.(map[string]any); ok {
+				if err := checkJSONSchemaObject(obj, items); err != nil {
+					return fmt.Errorf("body[%d]: %w", i, err)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package module
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/GoCodeAlone/workflow/interfaces"
+)
+
+var orderResponseSchema = map[string]any{
+	"type":     "object",
+	"required": []any{"id", "status"},
+	"properties": map[string]any{
+		"id":     map[string]any{"type": "string"},
+		"status": map[string]any{"type": "string"},
+		"total":  map[string]any{"type": "number"},
+	},
+}
+
+func TestValidateResponseStepFactory_RequiresSchema(t *testing.T) {
+	_, err := NewValidateResponseStepFactory()("check", map[string]any{}, nil)
+	if err == nil || !strings.Contains(err.Error(), "schema") {
+		t.Fatalf("expected schema error, got %v", err)
+	}
+}
+
+func TestValidateResponseStep_BodyFrom(t *testing.T) {
+	step, err := NewValidateResponseStepFactory()("check", map[string]any{
+		"schema":    orderResponseSchema,
+		"body_from": "steps.load.row",
+	}, nil)
+	if err != nil {
+		t.Fatalf("factory error: %v", err)
+	}
+
+	pc := NewPipelineContext(nil, nil)
+	pc.MergeStepOutput("load", map[string]any{"row": map[string]any{"id": "o-1", "status": "paid", "total": 12.5}})
+	result, err := step.Execute(context.Background(), pc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Output["valid"] != true {
+		t.Errorf("expected valid=true, got %v", result.Output["valid"])
+	}
+
+	pc = NewPipelineContext(nil, nil)
+	pc.MergeStepOutput("load", map[string]any{"row": map[string]any{"id": "o-1", "total": "12.5"}})
+	_, err = step.Execute(context.Background(), pc)
+	if err == nil {
+		t.Fatal("expected a contract violation")
+	}
+	if !interfaces.IsValidationError(err) || interfaces.ValidationErrorStatus(err) != http.StatusInternalServerError {
+		t.Fatalf("expected a 500 validation error, got %v", err)
+	}
+	if strings.Contains(err.Error(), "status") {
+		t.Errorf("violation detail should be logged, not returned to the client: %v", err)
+	}
+}
+
+func TestValidateResponseStep_ResponseBodyKey(t *testing.T) {
+	step, err := NewValidateResponseStepFactory()("check", map[string]any{
+		"schema": map[string]any{
+			"type":  "array",
+			"items": map[string]any{"type": "object", "required": []any{"id"}},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("factory error: %v", err)
+	}
+
+	pc := NewPipelineContext(map[string]any{"response_body": `[{"id":1},{"id":2}]`}, nil)
+	if _, err := step.Execute(context.Background(), pc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pc = NewPipelineContext(map[string]any{"response_body": `[{"id":1},{"name":"x"}]`}, nil)
+	if _, err := step.Execute(context.Background(), pc); err == nil {
+		t.Fatal("expected a contract violation for the second item")
+	}
+
+	pc = NewPipelineContext(map[string]any{"response_body": `{"id":1}`}, nil)
+	if _, err := step.Execute(context.Background(), pc); err == nil {
+		t.Fatal("expected a contract violation for an object body")
+	}
+
+	pc = NewPipelineContext(nil, nil)
+	if _, err := step.Execute(context.Background(), pc); err == nil {
+		t.Fatal("expected an error when there is no response body")
+	}
+}
+
+func TestValidateResponseStep_WarnOnly(t *testing.T) {
+	step, err := NewValidateResponseStepFactory()("check", map[string]any{
+		"schema":    orderResponseSchema,
+		"body_from": "order",
+		"warn_only": true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("factory error: %v", err)
+	}
+
+	pc := NewPipelineContext(map[string]any{"order": map[string]any{"id": 7}}, nil)
+	result, err := step.Execute(context.Background(), pc)
+	if err != nil {
+		t.Fatalf("warn_only should not fail the pipeline: %v", err)
+	}
+	if result.Output["valid"] != false {
+		t.Errorf("expected valid=false, got %v", result.Output["valid"])
+	}
+	if msg, _ := result.Output["error"].(string); !strings.Contains(msg, "status") {
+		t.Errorf("expected the violation in the output, got %q", msg)
+	}
+}
@@ -467,11 +467,9 @@ func (s *WebhookVerifyStep) unauthorized(pc *PipelineContext, reason string) (*S
 	if status == 0 {
 		status = http.StatusUnauthorized
 	}
-	if w, ok := pc.Metadata["_http_response_writer"].(http.ResponseWriter); ok {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(status)
-		_, _ = w.Write([]byte(`{"error":"unauthorized","reason":"webhook signature verification failed"}`))
-	}
+	p := NewProblem(problemCodeForStatus(status), "webhook signature verification failed")
+	p.Status = status
+	writeStepProblem(pc, s.name, p)
 	return &StepResult{
 		Stop:   true,
 		Output: map[string]any{"verified": false, "reason": reason},
@@ -8,6 +8,11 @@ import (
 // Aliased from pipeline.TemplateEngine for backwards compatibility.
 type TemplateEngine = pipeline.TemplateEngine
 
+// UndefinedPathError reports a template reference to a context path that
+// does not exist, in strict template mode.
+// Aliased from pipeline.UndefinedPathError.
+type UndefinedPathError = pipeline.UndefinedPathError
+
 // NewTemplateEngine creates a new TemplateEngine.
 // Delegates to pipeline.NewTemplateEngine.
 var NewTemplateEngine = pipeline.NewTemplateEngine
@@ -20,3 +25,7 @@ var preprocessTemplate = pipeline.PreprocessTemplate
 // templateFuncMap returns the function map available in pipeline templates.
 // Delegates to pipeline.TemplateFuncMap for backwards compatibility.
 var templateFuncMap = pipeline.TemplateFuncMap
+
+// RegisterTemplateFuncs adds an owner's functions to every step template.
+// Delegates to pipeline.RegisterTemplateFuncs.
+var RegisterTemplateFuncs = pipeline.RegisterTemplateFuncs
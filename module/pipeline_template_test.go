@@ -1,10 +1,16 @@
 package module
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/pipeline"
 )
 
 func TestTemplateEngine_ResolveSimpleField(t *testing.T) {
@@ -133,6 +139,39 @@ func TestTemplateEngine_StrictModeReturnsError(t *testing.T) {
 	}
 }
 
+func TestTemplateEngine_StrictModeReportsUndefinedPath(t *testing.T) {
+	tests := []struct {
+		tmpl     string
+		wantPath string
+		wantKey  string
+	}{
+		{"{{ .nonexistent }}", ".nonexistent", "nonexistent"},
+		{"{{ .steps.auth.affilate_id }}", ".steps.auth.affilate_id", "affilate_id"},
+		{"{{ .steps.missing.field }}", ".steps.missing", "missing"},
+		{"{{ upper .body.nmae }}", ".body.nmae", "nmae"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tmpl, func(t *testing.T) {
+			te := NewTemplateEngine()
+			pc := NewPipelineContext(map[string]any{"body": map[string]any{"name": "x"}}, nil)
+			pc.MergeStepOutput("auth", map[string]any{"affiliate_id": "tenant123"})
+			pc.StrictTemplates = true
+
+			_, err := te.Resolve(tt.tmpl, pc)
+			var undef *UndefinedPathError
+			if !errors.As(err, &undef) {
+				t.Fatalf("err = %v, want *UndefinedPathError", err)
+			}
+			if undef.Path != tt.wantPath || undef.Key != tt.wantKey {
+				t.Errorf("path %q key %q, want %q %q", undef.Path, undef.Key, tt.wantPath, tt.wantKey)
+			}
+			if !strings.Contains(err.Error(), "undefined path "+tt.wantPath) {
+				t.Errorf("err = %q, want it to name the path", err)
+			}
+		})
+	}
+}
+
 func TestTemplateEngine_StrictModePassesForPresentKey(t *testing.T) {
 	te := NewTemplateEngine()
 	pc := NewPipelineContext(map[string]any{"name": "Alice"}, nil)
@@ -1343,3 +1382,270 @@ func TestTemplateEngine_FuncMinMaxInt64Precision(t *testing.T) {
 		t.Fatalf("max: expected %d, got %q", large, got)
 	}
 }
+
+func TestTemplateEngine_FuncB64EncDec(t *testing.T) {
+	te := NewTemplateEngine()
+	pc := NewPipelineContext(map[string]any{"secret": "hello", "padded": "aGVsbG8=", "raw": "aGVsbG8"}, nil)
+
+	for expr, want := range map[string]string{
+		`{{ b64enc .secret }}`:          "aGVsbG8=",
+		`{{ b64dec .padded }}`:          "hello",
+		`{{ b64dec .raw }}`:             "hello",
+		`{{ b64dec (b64enc .secret) }}`: "hello",
+	} {
+		got, err := te.Resolve(expr, pc)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", expr, err)
+		}
+		if got != want {
+			t.Errorf("%s = %q, want %q", expr, got, want)
+		}
+	}
+
+	if _, err := te.Resolve(`{{ b64dec "not base64!" }}`, pc); err == nil || !strings.Contains(err.Error(), "b64dec") {
+		t.Errorf("expected b64dec error for invalid input, got %v", err)
+	}
+}
+
+func TestTemplateEngine_FuncEnv(t *testing.T) {
+	t.Setenv("WORKFLOW_TEMPLATE_TEST_REGION", "eu-west-1")
+	te := NewTemplateEngine()
+	pc := NewPipelineContext(nil, nil)
+
+	got, err := te.Resolve(`{{ env "WORKFLOW_TEMPLATE_TEST_REGION" }}`, pc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "eu-west-1" {
+		t.Errorf("expected eu-west-1, got %q", got)
+	}
+
+	got, err = te.Resolve(`{{ default "us-east-1" (env "WORKFLOW_TEMPLATE_TEST_UNSET") }}`, pc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "us-east-1" {
+		t.Errorf("expected default for unset variable, got %q", got)
+	}
+}
+
+func TestTemplateEngine_FuncFormatTime(t *testing.T) {
+	te := NewTemplateEngine()
+	pc := NewPipelineContext(map[string]any{
+		"created": "2024-03-05T14:30:00Z",
+		"unix":    int64(1709649000),
+		"time":    time.Date(2024, 3, 5, 14, 30, 0, 0, time.UTC),
+	}, nil)
+
+	for expr, want := range map[string]string{
+		`{{ formatTime "DateOnly" .created }}`:   "2024-03-05",
+		`{{ formatTime "15:04" .unix }}`:         "14:30",
+		`{{ formatTime "RFC1123" .time }}`:       "Tue, 05 Mar 2024 14:30:00 UTC",
+		`${ formatTime("DateTime", created) }`:   "2024-03-05 14:30:00",
+		`{{ formatTime "2006/01/02" .created }}`: "2024/03/05",
+	} {
+		got, err := te.Resolve(expr, pc)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", expr, err)
+		}
+		if got != want {
+			t.Errorf("%s = %q, want %q", expr, got, want)
+		}
+	}
+
+	if _, err := te.Resolve(`{{ formatTime "DateOnly" "yesterday" }}`, pc); err == nil {
+		t.Error("expected formatTime error for an unparseable time")
+	}
+}
+
+func TestTemplateEngine_FuncHashingAndQuoting(t *testing.T) {
+	te := NewTemplateEngine()
+	pc := NewPipelineContext(map[string]any{"name": ` Say "hi" <b> `, "count": 3}, nil)
+
+	for expr, want := range map[string]string{
+		`{{ trim .name }}`:   `Say "hi" <b>`,
+		`{{ quote .name }}`:  `" Say \"hi\" <b> "`,
+		`{{ quote .count }}`: `"3"`,
+		`{{ sha256 "abc" }}`: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		`${ sha256("abc") }`: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		`{{ formatDate "DateOnly" "2024-03-05T14:30:00Z" }}`: "2024-03-05",
+	} {
+		got, err := te.Resolve(expr, pc)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", expr, err)
+		}
+		if got != want {
+			t.Errorf("%s = %q, want %q", expr, got, want)
+		}
+	}
+}
+
+func TestTemplateEngine_FuncHMAC(t *testing.T) {
+	te := NewTemplateEngine()
+	lookup := pipeline.SecretLookup(func(_ context.Context, provider, key string) (string, error) {
+		if provider == "partner-secrets" && key == "signing_key" {
+			return "key", nil
+		}
+		return "", fmt.Errorf("secret %q not found", key)
+	})
+	pc := NewPipelineContext(map[string]any{"body": "The quick brown fox jumps over the lazy dog"}, map[string]any{"_secret_lookup": lookup})
+
+	got, err := te.Resolve(`{{ hmac "sha256" "partner-secrets" "signing_key" .body }}`, pc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"; got != want {
+		t.Errorf("hmac = %q, want %q", got, want)
+	}
+
+	for expr, wantErr := range map[string]string{
+		`{{ hmac "md5" "partner-secrets" "signing_key" .body }}`:  `unsupported algorithm "md5"`,
+		`{{ hmac "sha256" "partner-secrets" "other_key" .body }}`: `secret "other_key" of "partner-secrets"`,
+	} {
+		if _, err := te.Resolve(expr, pc); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("%s: error = %v, want %q", expr, err, wantErr)
+		}
+	}
+	if _, err := te.Resolve(`{{ hmac "sha256" "partner-secrets" "signing_key" "x" }}`, NewPipelineContext(nil, nil)); err == nil || !strings.Contains(err.Error(), "no secrets") {
+		t.Errorf("without a secret lookup: error = %v", err)
+	}
+}
+
+func TestTemplateEngine_TemplateSources(t *testing.T) {
+	fixed := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	render := func() []string {
+		ctx := pipeline.WithTemplateSources(context.Background(), pipeline.FixedTemplateSources(fixed, 42))
+		var out []string
+		p := &Pipeline{Name: "ids", Steps: []PipelineStep{&mockStep{name: "render", execFn: func(_ context.Context, pc *PipelineContext) (*StepResult, error) {
+			for _, tmpl := range []string{`{{ now "DateTime" }}`, `{{ uuid }}`, `{{ uuidv4 }}`, `${ now("DateOnly") }`, `${ uuid() }`} {
+				s, err := NewTemplateEngine().Resolve(tmpl, pc)
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, s)
+			}
+			return &StepResult{}, nil
+		}}}}
+		if _, err := p.Execute(ctx, nil); err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+
+	first, second := render(), render()
+	if strings.Join(first, ",") != strings.Join(second, ",") {
+		t.Fatalf("renders differ: %v and %v", first, second)
+	}
+	if first[0] != "2026-01-02 03:04:05" || first[3] != "2026-01-02" || first[1] == first[2] || len(first[4]) != 36 {
+		t.Fatalf("render = %v", first)
+	}
+
+	// Without sources, now reads the system clock.
+	got, err := NewTemplateEngine().Resolve(`{{ now "2006" }}`, NewPipelineContext(nil, nil))
+	if err != nil || got != time.Now().UTC().Format("2006") {
+		t.Fatalf("now = %q, %v", got, err)
+	}
+}
+
+// TestTemplateEngine_ValuesAreNotTemplates verifies that user-controlled
+// values are rendered verbatim, never evaluated as template source.
+func TestTemplateEngine_ValuesAreNotTemplates(t *testing.T) {
+	t.Setenv("WORKFLOW_TEMPLATE_TEST_SECRET", "leaked")
+	te := NewTemplateEngine()
+	payloads := []string{
+		`{{ env "WORKFLOW_TEMPLATE_TEST_SECRET" }}`,
+		`}}{{ env "WORKFLOW_TEMPLATE_TEST_SECRET" }}{{ "`,
+		`${ env("WORKFLOW_TEMPLATE_TEST_SECRET") }`,
+		`{{ .steps.my-step.x }}`,
+	}
+	for _, payload := range payloads {
+		pc := NewPipelineContext(map[string]any{"name": payload}, nil)
+		for tmpl, want := range map[string]string{
+			`{{ .name }}`:                  payload,
+			`${ name }`:                    payload,
+			`${ body.name }`:               payload,
+			`${ name } / {{ upper "ok" }}`: payload + " / OK",
+			`{{ upper "ok" }} / ${ name }`: "OK / " + payload,
+			`{"name": {{ quote .name }}}`:  `{"name": ` + jsonQuote(payload) + `}`,
+		} {
+			got, err := te.Resolve(tmpl, pc)
+			if err != nil {
+				t.Fatalf("%s with %q: unexpected error: %v", tmpl, payload, err)
+			}
+			if got != want || strings.Contains(got, "leaked") {
+				t.Errorf("%s with %q = %q, want %q", tmpl, payload, got, want)
+			}
+		}
+	}
+}
+
+// jsonQuote is the JSON encoding of s, as rendered by the quote function.
+func jsonQuote(s string) string {
+	var buf strings.Builder
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func TestRegisterTemplateFuncs(t *testing.T) {
+	t.Cleanup(func() {
+		_ = RegisterTemplateFuncs("funcs-a", nil)
+		_ = RegisterTemplateFuncs("funcs-b", nil)
+	})
+	if err := RegisterTemplateFuncs("funcs-a", map[string]any{
+		"shout": func(s string) string { return strings.ToUpper(s) + "!" },
+		"cents": func(v float64) (int64, error) { return int64(v * 100), nil },
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	te := NewTemplateEngine()
+	pc := NewPipelineContext(map[string]any{"name": "hi", "price": 1.25}, nil)
+	for expr, want := range map[string]string{
+		`{{ shout .name }}`:  "HI!",
+		`${ shout(name) }`:   "HI!",
+		`{{ cents .price }}`: "125",
+	} {
+		if got, err := te.Resolve(expr, pc); err != nil || got != want {
+			t.Errorf("%s = %q, %v; want %q", expr, got, err, want)
+		}
+	}
+
+	for name, tt := range map[string]struct {
+		owner   string
+		funcs   map[string]any
+		wantErr string
+	}{
+		"built-in":       {"funcs-b", map[string]any{"upper": strings.ToUpper}, "taken by a built-in function"},
+		"context-bound":  {"funcs-b", map[string]any{"hmac": strings.ToUpper}, "taken by a built-in function"},
+		"other plugin":   {"funcs-b", map[string]any{"shout": strings.ToUpper}, `taken by "funcs-a"`},
+		"not a function": {"funcs-b", map[string]any{"answer": 42}, "not a function"},
+		"bad result":     {"funcs-b", map[string]any{"pair": func() (int, int) { return 0, 0 }}, "must return one value"},
+		"bad name":       {"funcs-b", map[string]any{"my-func": strings.ToUpper}, "not a valid identifier"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := RegisterTemplateFuncs(tt.owner, tt.funcs)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	// An owner re-registering replaces its functions.
+	if err := RegisterTemplateFuncs("funcs-a", map[string]any{"whisper": strings.ToLower}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := templateFuncMap()["shout"]; ok {
+		t.Error("shout survived re-registration")
+	}
+	found := false
+	for _, d := range TemplateFuncDescriptions() {
+		if d.Name == "whisper" {
+			found = d.Signature == "whisper(string) string" && strings.Contains(d.Description, `"funcs-a"`)
+		}
+	}
+	if !found {
+		t.Error("whisper is not described")
+	}
+}
@@ -3,9 +3,11 @@ package module
 import (
 	"context"
 	"fmt"
+	"os"
 	"sync"
 
 	"github.com/GoCodeAlone/modular"
+	"gopkg.in/yaml.v3"
 )
 
 // PolicyEngine is the interface implemented by all policy backends.
@@ -30,7 +32,8 @@ type PolicyInfo struct {
 }
 
 // PolicyEngineModule is a workflow module wrapping a pluggable PolicyEngine backend.
-// Supported backends: "mock". For OPA or Cedar, use external plugins.
+// Supported backends: "mock" and "opa" (embedded Rego evaluation). For Cedar,
+// use the external plugin.
 type PolicyEngineModule struct {
 	name    string
 	config  map[string]any
@@ -57,7 +60,12 @@ func (m *PolicyEngineModule) Init(app modular.Application) error {
 	case "mock":
 		m.engine = newMockPolicyEngine()
 	case "opa":
-		return fmt.Errorf("opa backend not built-in; use the workflow-plugin-policy-opa external plugin")
+		data, err := policyData(m.config)
+		if err != nil {
+			return fmt.Errorf("policy.engine %q: %w", m.name, err)
+		}
+		decision, _ := m.config["decision"].(string)
+		m.engine = newOPAPolicyEngine(data, decision)
 	case "cedar":
 		return fmt.Errorf("cedar backend not built-in; use the workflow-plugin-policy-cedar external plugin")
 	default:
@@ -73,6 +81,13 @@ func (m *PolicyEngineModule) Init(app modular.Application) error {
 			}
 			pname, _ := pm["name"].(string)
 			content, _ := pm["content"].(string)
+			if file, _ := pm["file"].(string); content == "" && file != "" {
+				raw, err := os.ReadFile(file)
+				if err != nil {
+					return fmt.Errorf("policy.engine %q: policies[%d]: %w", m.name, i, err)
+				}
+				content = string(raw)
+			}
 			if pname == "" {
 				pname = fmt.Sprintf("policy-%d", i)
 			}
@@ -101,6 +116,27 @@ func (m *PolicyEngineModule) Engine() PolicyEngine { return m.engine }
 // Backend returns the configured backend name.
 func (m *PolicyEngineModule) Backend() string { return m.backend }
 
+// policyData returns the data document configured by "data" merged over
+// the JSON or YAML document read from "dataFile".
+func policyData(cfg map[string]any) (map[string]any, error) {
+	data := map[string]any{}
+	if file, _ := cfg["dataFile"].(string); file != "" {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read dataFile: %w", err)
+		}
+		if err := yaml.Unmarshal(raw, &data); err != nil {
+			return nil, fmt.Errorf("parse dataFile %s: %w", file, err)
+		}
+	}
+	if inline, ok := cfg["data"].(map[string]any); ok {
+		for k, v := range inline {
+			data[k] = v
+		}
+	}
+	return data, nil
+}
+
 // ─── Mock backend ────────────────────────────────────────────────────────────
 
 // mockPolicyEngine is an in-memory policy engine for testing.
@@ -0,0 +1,207 @@
+package module
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/open-policy-agent/opa/v1/ast"
+	"github.com/open-policy-agent/opa/v1/rego"
+	"github.com/open-policy-agent/opa/v1/storage"
+	"github.com/open-policy-agent/opa/v1/storage/inmem"
+)
+
+// PolicyChecker is implemented by policy backends that evaluate a named
+// policy rather than every loaded policy.
+type PolicyChecker interface {
+	EvaluatePolicy(ctx context.Context, policy string, input map[string]any) (*PolicyDecision, error)
+}
+
+// opaPolicyEngine evaluates Rego policies with the embedded OPA library.
+//
+// A policy is named by its package path ("authz" or "authz.orders") and
+// evaluated as the query data.<path>. Its decision document is read as:
+//
+//   - a boolean: the decision itself
+//   - an object: "allow" grants (default false), and any entries in "deny"
+//     deny regardless of allow; "deny" and "reasons" supply the reasons
+//
+// Each query is partially evaluated against the loaded policies and data
+// once, leaving only the input unknown; the partial result is cached and
+// reused until a policy or the data document changes.
+type opaPolicyEngine struct {
+	mu      sync.RWMutex
+	sources map[string]string
+	modules []*ast.Module
+	store   storage.Store
+	// decision is the policy evaluated by Evaluate.
+	decision string
+	partials map[string]rego.PartialResult
+	// generation counts policy and data changes, so that a partial result
+	// prepared concurrently with a change is not cached.
+	generation uint64
+}
+
+func newOPAPolicyEngine(data map[string]any, decision string) *opaPolicyEngine {
+	if data == nil {
+		data = map[string]any{}
+	}
+	return &opaPolicyEngine{
+		sources:  make(map[string]string),
+		store:    inmem.NewFromObject(data),
+		decision: decision,
+		partials: make(map[string]rego.PartialResult),
+	}
+}
+
+// LoadPolicy compiles content together with the loaded policies. A policy
+// that fails to parse or compile is rejected and the loaded set is unchanged.
+func (e *opaPolicyEngine) LoadPolicy(name, content string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	sources := make(map[string]string, len(e.sources)+1)
+	for n, c := range e.sources {
+		sources[n] = c
+	}
+	sources[name] = content
+	compiler, err := ast.CompileModules(sources)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(compiler.Modules))
+	for n := range compiler.Modules {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	modules := make([]*ast.Module, len(names))
+	for i, n := range names {
+		modules[i] = compiler.Modules[n]
+	}
+	e.sources = sources
+	e.modules = modules
+	e.generation++
+	clear(e.partials)
+	return nil
+}
+
+// SetData replaces the data document that policies read as data.
+func (e *opaPolicyEngine) SetData(data map[string]any) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.store = inmem.NewFromObject(data)
+	e.generation++
+	clear(e.partials)
+}
+
+func (e *opaPolicyEngine) ListPolicies() []PolicyInfo {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	out := make([]PolicyInfo, 0, len(e.sources))
+	for n, c := range e.sources {
+		out = append(out, PolicyInfo{Name: n, Backend: "opa", Content: c})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Evaluate evaluates the configured decision policy.
+func (e *opaPolicyEngine) Evaluate(ctx context.Context, input map[string]any) (*PolicyDecision, error) {
+	if e.decision == "" {
+		return nil, fmt.Errorf("opa: no decision policy configured; set 'decision' or use step.policy_check")
+	}
+	return e.EvaluatePolicy(ctx, e.decision, input)
+}
+
+// EvaluatePolicy evaluates the policy at the given package path.
+func (e *opaPolicyEngine) EvaluatePolicy(ctx context.Context, policy string, input map[string]any) (*PolicyDecision, error) {
+	query := "data." + strings.Trim(strings.ReplaceAll(policy, "/", "."), ".")
+	pr, err := e.partialResult(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	rs, err := pr.Rego(rego.Input(input)).Eval(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("opa: evaluate %s: %w", query, err)
+	}
+	if len(rs) == 0 || len(rs[0].Expressions) == 0 {
+		return &PolicyDecision{
+			Allowed:  false,
+			Reasons:  []string{fmt.Sprintf("policy %q is undefined", policy)},
+			Metadata: map[string]any{"backend": "opa", "policy": policy},
+		}, nil
+	}
+	return opaDecision(policy, rs[0].Expressions[0].Value)
+}
+
+// partialResult returns the cached partial evaluation of query, computing
+// it on first use.
+func (e *opaPolicyEngine) partialResult(ctx context.Context, query string) (rego.PartialResult, error) {
+	e.mu.RLock()
+	pr, ok := e.partials[query]
+	modules, store, generation := e.modules, e.store, e.generation
+	e.mu.RUnlock()
+	if ok {
+		return pr, nil
+	}
+
+	opts := []func(*rego.Rego){rego.Query(query), rego.Store(store)}
+	for _, m := range modules {
+		opts = append(opts, rego.ParsedModule(m))
+	}
+	pr, err := rego.New(opts...).PartialResult(ctx)
+	if err != nil {
+		return rego.PartialResult{}, fmt.Errorf("opa: prepare %s: %w", query, err)
+	}
+	e.mu.Lock()
+	if e.generation == generation {
+		e.partials[query] = pr
+	}
+	e.mu.Unlock()
+	return pr, nil
+}
+
+func opaDecision(policy string, value any) (*PolicyDecision, error) {
+	meta := map[string]any{"backend": "opa", "policy": policy, "result": value}
+	switch v := value.(type) {
+	case bool:
+		reason := "allowed by " + policy
+		if !v {
+			reason = "denied by " + policy
+		}
+		return &PolicyDecision{Allowed: v, Reasons: []string{reason}, Metadata: meta}, nil
+	case map[string]any:
+		allowed, _ := v["allow"].(bool)
+		deny := opaStrings(v["deny"])
+		reasons := append(deny, opaStrings(v["reasons"])...)
+		if len(deny) > 0 {
+			allowed = false
+		}
+		if len(reasons) == 0 {
+			if allowed {
+				reasons = []string{"allowed by " + policy}
+			} else {
+				reasons = []string{"denied by " + policy}
+			}
+		}
+		return &PolicyDecision{Allowed: allowed, Reasons: reasons, Metadata: meta}, nil
+	default:
+		return nil, fmt.Errorf("opa: policy %q must produce a boolean or an object, got %T", policy, value)
+	}
+}
+
+// opaStrings converts a Rego set or array of messages to strings.
+func opaStrings(v any) []string {
+	items, _ := v.([]any)
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		} else {
+			out = append(out, fmt.Sprint(item))
+		}
+	}
+	return out
+}
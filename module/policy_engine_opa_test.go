@@ -0,0 +1,178 @@
+package module
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const ordersPolicy = `package orders
+
+default allow := false
+
+allow if "admin" in data.roles[input.user]
+
+deny contains msg if {
+	input.amount > data.limit
+	msg := sprintf("amount %v exceeds the limit", [input.amount])
+}
+`
+
+func newOrdersEngine(t *testing.T) *PolicyEngineModule {
+	t.Helper()
+	dir := t.TempDir()
+	dataFile := filepath.Join(dir, "data.yaml")
+	if err := os.WriteFile(dataFile, []byte("limit: 10\nroles:\n  alice: [viewer]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m := NewPolicyEngineModule("policies", map[string]any{
+		"backend":  "opa",
+		"decision": "orders",
+		"dataFile": dataFile,
+		// Inline data takes precedence over the file.
+		"data": map[string]any{"roles": map[string]any{"alice": []any{"admin"}}},
+		"policies": []any{
+			map[string]any{"name": "orders", "content": ordersPolicy},
+		},
+	})
+	if err := m.Init(NewMockApplication()); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	return m
+}
+
+func TestOPAPolicyEngine_Decisions(t *testing.T) {
+	m := newOrdersEngine(t)
+	checker := m.Engine().(PolicyChecker)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		input   map[string]any
+		allowed bool
+		reason  string
+	}{
+		{"admin within limit", map[string]any{"user": "alice", "amount": 5}, true, "allowed by orders"},
+		{"not an admin", map[string]any{"user": "bob", "amount": 5}, false, "denied by orders"},
+		{"deny overrides allow", map[string]any{"user": "alice", "amount": 50}, false, "amount 50 exceeds the limit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := checker.EvaluatePolicy(ctx, "orders", tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d.Allowed != tt.allowed || len(d.Reasons) == 0 || d.Reasons[0] != tt.reason {
+				t.Errorf("decision = %v %v, want %v %q", d.Allowed, d.Reasons, tt.allowed, tt.reason)
+			}
+		})
+	}
+
+	// Evaluate uses the configured decision policy; a rule path evaluates
+	// to a boolean decision.
+	if d, err := m.Engine().Evaluate(ctx, map[string]any{"user": "alice", "amount": 1}); err != nil || !d.Allowed {
+		t.Errorf("Evaluate = %+v, %v", d, err)
+	}
+	if d, err := checker.EvaluatePolicy(ctx, "orders/allow", map[string]any{"user": "bob"}); err != nil || d.Allowed {
+		t.Errorf("orders/allow for bob = %+v, %v", d, err)
+	}
+	if d, err := checker.EvaluatePolicy(ctx, "missing", nil); err != nil || d.Allowed {
+		t.Errorf("undefined policy = %+v, %v", d, err)
+	}
+}
+
+func TestOPAPolicyEngine_CacheInvalidation(t *testing.T) {
+	m := newOrdersEngine(t)
+	eng := m.Engine().(*opaPolicyEngine)
+	ctx := context.Background()
+	input := map[string]any{"user": "alice", "amount": 50}
+
+	if d, _ := eng.EvaluatePolicy(ctx, "orders", input); d.Allowed {
+		t.Fatal("expected deny above the limit")
+	}
+	if len(eng.partials) != 1 {
+		t.Fatalf("cached partial results = %d, want 1", len(eng.partials))
+	}
+
+	eng.SetData(map[string]any{"limit": 100, "roles": map[string]any{"alice": []any{"admin"}}})
+	if len(eng.partials) != 0 {
+		t.Error("SetData did not clear the cache")
+	}
+	if d, _ := eng.EvaluatePolicy(ctx, "orders", input); !d.Allowed {
+		t.Errorf("expected allow after raising the limit: %v", d.Reasons)
+	}
+
+	if err := eng.LoadPolicy("orders", "package orders\n\nallow := false\n"); err != nil {
+		t.Fatal(err)
+	}
+	if d, _ := eng.EvaluatePolicy(ctx, "orders", input); d.Allowed {
+		t.Error("expected the reloaded policy to deny")
+	}
+}
+
+func TestOPAPolicyEngine_RejectsInvalidPolicy(t *testing.T) {
+	m := newOrdersEngine(t)
+	err := m.Engine().LoadPolicy("broken", "package broken\n\nallow if {")
+	if err == nil {
+		t.Fatal("expected compile error")
+	}
+	if got := m.Engine().ListPolicies(); len(got) != 1 || got[0].Name != "orders" {
+		t.Errorf("policies after failed load = %v", got)
+	}
+}
+
+func TestPolicyCheckStep(t *testing.T) {
+	m := newOrdersEngine(t)
+	app := NewMockApplication()
+	app.Services["policies"] = m
+
+	newStep := func(cfg map[string]any) PipelineStep {
+		t.Helper()
+		cfg["engine"] = "policies"
+		cfg["policy"] = "orders"
+		step, err := NewPolicyCheckStepFactory()("check", cfg, app)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return step
+	}
+	ctx := context.Background()
+
+	res, err := newStep(map[string]any{}).Execute(ctx, NewPipelineContext(map[string]any{"user": "alice", "amount": 3}, nil))
+	if err != nil || res.Output["allowed"] != true || res.Stop {
+		t.Fatalf("allow: %+v, %v", res, err)
+	}
+
+	deny := map[string]any{"order": map[string]any{"user": "alice", "amount": 30}}
+	res, err = newStep(map[string]any{"input_from": "order"}).Execute(ctx, NewPipelineContext(deny, nil))
+	if err != nil || res.Output["allowed"] != false || !res.Stop {
+		t.Fatalf("stop on deny: %+v, %v", res, err)
+	}
+
+	res, err = newStep(map[string]any{"input_from": "order", "on_deny": "forbidden"}).Execute(ctx, NewPipelineContext(deny, nil))
+	if err != nil || res.Output["response_status"] != 403 || !strings.Contains(res.Output["response_body"].(string), "exceeds the limit") {
+		t.Fatalf("forbidden on deny: %+v, %v", res, err)
+	}
+
+	if _, err := newStep(map[string]any{"input_from": "order", "on_deny": "error"}).Execute(ctx, NewPipelineContext(deny, nil)); err == nil {
+		t.Fatal("expected error on deny")
+	}
+
+	res, err = newStep(map[string]any{"input": map[string]any{"user": "{{ .name }}"}}).Execute(ctx, NewPipelineContext(map[string]any{"name": "bob"}, nil))
+	if err != nil || res.Output["allowed"] != false {
+		t.Fatalf("templated input: %+v, %v", res, err)
+	}
+}
+
+func TestPolicyCheckStep_RequiresNamedPolicyBackend(t *testing.T) {
+	app := newMockAppWithPolicyEngine("mock", newMockPolicyEngine())
+	step, err := NewPolicyCheckStepFactory()("check", map[string]any{"engine": "mock", "policy": "authz"}, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := step.Execute(context.Background(), NewPipelineContext(nil, nil)); err == nil {
+		t.Fatal("expected error for a backend without named policies")
+	}
+}
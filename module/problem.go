@@ -0,0 +1,398 @@
+package module
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/GoCodeAlone/workflow/interfaces"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem details.
+const ProblemContentType = "application/problem+json"
+
+// ProblemTypeBaseURI prefixes a problem code to form its stable type URI.
+const ProblemTypeBaseURI = "https://docs.gocodealone.com/workflow/problems/"
+
+// ProblemCode identifies an entry in the engine's error code catalog.
+type ProblemCode string
+
+// Error codes returned in problem+json responses. Each maps to a stable type
+// URI (ProblemTypeBaseURI + code), a title and a default HTTP status.
+const (
+	ProblemBadRequest         ProblemCode = "bad_request"
+	ProblemValidationFailed   ProblemCode = "validation_failed"
+	ProblemUnauthorized       ProblemCode = "unauthorized"
+	ProblemForbidden          ProblemCode = "forbidden"
+	ProblemNotFound           ProblemCode = "not_found"
+	ProblemMethodNotAllowed   ProblemCode = "method_not_allowed"
+	ProblemConflict           ProblemCode = "conflict"
+	ProblemPayloadTooLarge    ProblemCode = "payload_too_large"
+	ProblemRateLimited        ProblemCode = "rate_limited"
+	ProblemPipelineError      ProblemCode = "pipeline_error"
+	ProblemInternalError      ProblemCode = "internal_error"
+	ProblemUpstreamError      ProblemCode = "upstream_error"
+	ProblemServiceUnavailable ProblemCode = "service_unavailable"
+	ProblemUpstreamTimeout    ProblemCode = "upstream_timeout"
+)
+
+type problemDef struct {
+	title  string
+	status int
+}
+
+var problemCatalog = map[ProblemCode]problemDef{
+	ProblemBadRequest:         {"Bad Request", http.StatusBadRequest},
+	ProblemValidationFailed:   {"Validation Failed", http.StatusBadRequest},
+	ProblemUnauthorized:       {"Unauthorized", http.StatusUnauthorized},
+	ProblemForbidden:          {"Forbidden", http.StatusForbidden},
+	ProblemNotFound:           {"Not Found", http.StatusNotFound},
+	ProblemMethodNotAllowed:   {"Method Not Allowed", http.StatusMethodNotAllowed},
+	ProblemConflict:           {"Conflict", http.StatusConflict},
+	ProblemPayloadTooLarge:    {"Payload Too Large", http.StatusRequestEntityTooLarge},
+	ProblemRateLimited:        {"Rate Limited", http.StatusTooManyRequests},
+	ProblemPipelineError:      {"Pipeline Error", http.StatusInternalServerError},
+	ProblemInternalError:      {"Internal Error", http.StatusInternalServerError},
+	ProblemUpstreamError:      {"Upstream Error", http.StatusBadGateway},
+	ProblemServiceUnavailable: {"Service Unavailable", http.StatusServiceUnavailable},
+	ProblemUpstreamTimeout:    {"Upstream Timeout", http.StatusGatewayTimeout},
+}
+
+// ProblemCodes returns every code in the catalog, sorted.
+func ProblemCodes() []ProblemCode {
+	codes := make([]ProblemCode, 0, len(problemCatalog))
+	for c := range problemCatalog {
+		codes = append(codes, c)
+	}
+	slices.Sort(codes)
+	return codes
+}
+
+// IsProblemCode reports whether code is in the catalog.
+func IsProblemCode(code string) bool {
+	_, ok := problemCatalog[ProblemCode(code)]
+	return ok
+}
+
+// ProblemDetailLevel controls how much of an error a problem response
+// reveals. It is set engine-wide with engine.errors.detail.
+type ProblemDetailLevel string
+
+const (
+	// ProblemDetailFull includes the error message and the failing step. It
+	// is the default, suited to development.
+	ProblemDetailFull ProblemDetailLevel = "full"
+	// ProblemDetailMinimal omits the failing step and the message of server
+	// errors (5xx), for production. Client errors keep their detail.
+	ProblemDetailMinimal ProblemDetailLevel = "minimal"
+)
+
+// ParseProblemDetailLevel parses an engine.errors.detail value. Empty means
+// ProblemDetailFull.
+func ParseProblemDetailLevel(s string) (ProblemDetailLevel, error) {
+	switch ProblemDetailLevel(s) {
+	case "", ProblemDetailFull:
+		return ProblemDetailFull, nil
+	case ProblemDetailMinimal:
+		return ProblemDetailMinimal, nil
+	}
+	return "", fmt.Errorf("invalid error detail level %q: want %q or %q", s, ProblemDetailFull, ProblemDetailMinimal)
+}
+
+// ProblemDetailProvider is implemented by engines that configure the detail
+// level of problem responses. The HTTP trigger consults it for errors that
+// end a workflow.
+type ProblemDetailProvider interface {
+	ProblemDetailLevel() ProblemDetailLevel
+}
+
+// Problem is an RFC 7807 problem details object. Code, ExecutionID,
+// RequestID, Step and Errors are extension members.
+type Problem struct {
+	Type        string `json:"type"`
+	Title       string `json:"title"`
+	Status      int    `json:"status"`
+	Detail      string `json:"detail,omitempty"`
+	Instance    string `json:"instance,omitempty"`
+	Code        string `json:"code"`
+	ExecutionID string `json:"execution_id,omitempty"`
+	RequestID   string `json:"request_id,omitempty"`
+	Step        string `json:"step,omitempty"`
+	Errors      any    `json:"errors,omitempty"`
+}
+
+// NewProblem returns the catalog problem for code with the given detail.
+// A code outside the catalog gets the pipeline_error title and status.
+func NewProblem(code ProblemCode, detail string) *Problem {
+	def, ok := problemCatalog[code]
+	if !ok {
+		def = problemCatalog[ProblemPipelineError]
+	}
+	return &Problem{
+		Type:   ProblemTypeBaseURI + string(code),
+		Title:  def.title,
+		Status: def.status,
+		Detail: detail,
+		Code:   string(code),
+	}
+}
+
+// Error lets a step return a Problem as its error, so the response that ends
+// the request carries the step's own code and status.
+func (p *Problem) Error() string {
+	if p.Detail == "" {
+		return p.Title
+	}
+	return p.Title + ": " + p.Detail
+}
+
+// PipelineStepError is returned by Pipeline.Execute when a step fails. It
+// names the step and execution so error responses can report them; its
+// message is that of Err.
+type PipelineStepError struct {
+	Pipeline    string
+	Step        string
+	ExecutionID string
+	Err         error
+}
+
+func (e *PipelineStepError) Error() string { return e.Err.Error() }
+
+func (e *PipelineStepError) Unwrap() error { return e.Err }
+
+// ProblemFromError maps an error that ended a pipeline or workflow to a
+// problem: a *Problem in the chain is used as-is, client validation errors
+// become validation_failed, database constraint violations conflict or
+// validation_failed (see dbErrorProblem), timeouts upstream_timeout, and
+// anything else pipeline_error. The failing step and execution ID are taken
+// from a *PipelineStepError in the chain.
+func ProblemFromError(err error) *Problem {
+	var p *Problem
+	var ve *interfaces.ValidationError
+	var netErr net.Error
+	switch dbp := dbErrorProblem(err); {
+	case errors.As(err, &p):
+		cp := *p
+		p = &cp
+	case errors.As(err, &ve):
+		// A validation error with a server status (e.g. a response that
+		// breaks its contract) is the pipeline's fault, not the client's.
+		status := interfaces.ValidationErrorStatus(err)
+		code := ProblemValidationFailed
+		if status >= http.StatusInternalServerError {
+			code = ProblemPipelineError
+		}
+		p = NewProblem(code, ve.Message)
+		p.Status = status
+		if ve.Field != "" || ve.Code != "" {
+			entry := map[string]string{"message": ve.Message}
+			if ve.Field != "" {
+				entry["field"] = ve.Field
+			}
+			if ve.Code != "" {
+				entry["code"] = ve.Code
+			}
+			p.Errors = []map[string]string{entry}
+		}
+	case dbp != nil:
+		p = dbp
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		p = NewProblem(ProblemUpstreamTimeout, err.Error())
+	default:
+		p = NewProblem(ProblemPipelineError, err.Error())
+	}
+	var stepErr *PipelineStepError
+	if errors.As(err, &stepErr) {
+		if p.Step == "" {
+			p.Step = stepErr.Step
+		}
+		if p.ExecutionID == "" {
+			p.ExecutionID = stepErr.ExecutionID
+		}
+	}
+	return p
+}
+
+// sqlStateError is implemented by PostgreSQL driver errors (pgconn.PgError,
+// pq.Error), which carry the SQLSTATE of the failure.
+type sqlStateError interface {
+	SQLState() string
+}
+
+// dbErrorProblem maps a database error to a problem, or returns nil for
+// other errors. Unique, exclusion and foreign key violations and
+// serialization failures are conflicts; not-null and check violations and
+// invalid values are validation failures; a query with no row is not_found.
+// The detail is generic so table and constraint names do not reach clients.
+func dbErrorProblem(err error) *Problem {
+	if errors.Is(err, sql.ErrNoRows) {
+		return NewProblem(ProblemNotFound, "record not found")
+	}
+	var code ProblemCode
+	var detail string
+	var se sqlStateError
+	if errors.As(err, &se) {
+		state := se.SQLState()
+		switch {
+		case state == "23505", state == "23P01":
+			code, detail = ProblemConflict, "a record with the same key already exists"
+		case state == "23503":
+			code, detail = ProblemConflict, "the record references a missing record or is still referenced"
+		case state == "40001", state == "40P01":
+			code, detail = ProblemConflict, "the record was changed concurrently; retry the request"
+		case state == "23502", state == "23514", strings.HasPrefix(state, "22"):
+			code, detail = ProblemValidationFailed, "a value is missing or invalid"
+		case state == "57014":
+			code, detail = ProblemUpstreamTimeout, "the database query timed out"
+		case strings.HasPrefix(state, "08"), state == "53300", state == "57P03":
+			code, detail = ProblemServiceUnavailable, "the database is unavailable"
+		default:
+			return nil
+		}
+		return NewProblem(code, detail)
+	}
+	// SQLite drivers report constraint violations only in the message.
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "UNIQUE constraint failed"), strings.Contains(msg, "PRIMARY KEY constraint failed"):
+		code, detail = ProblemConflict, "a record with the same key already exists"
+	case strings.Contains(msg, "FOREIGN KEY constraint failed"):
+		code, detail = ProblemConflict, "the record references a missing record or is still referenced"
+	case strings.Contains(msg, "NOT NULL constraint failed"), strings.Contains(msg, "CHECK constraint failed"):
+		code, detail = ProblemValidationFailed, "a value is missing or invalid"
+	case strings.Contains(msg, "database is locked"):
+		code, detail = ProblemServiceUnavailable, "the database is busy"
+	default:
+		return nil
+	}
+	return NewProblem(code, detail)
+}
+
+// WriteProblem writes p as an application/problem+json response, trimmed to
+// level.
+func WriteProblem(w http.ResponseWriter, p *Problem, level ProblemDetailLevel) {
+	out := *p
+	if level == ProblemDetailMinimal {
+		out.Step = ""
+		if out.Status >= http.StatusInternalServerError {
+			out.Detail = ""
+			out.Errors = nil
+		}
+	}
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(out.Status)
+	if err := json.NewEncoder(w).Encode(&out); err != nil {
+		slog.Warn("failed to write problem response", "code", out.Code, "error", err)
+	}
+}
+
+// problemDetailLevel returns the detail level a pipeline was configured with.
+func problemDetailLevel(pc *PipelineContext) ProblemDetailLevel {
+	if level, _ := pc.Metadata["_problem_detail"].(string); level != "" {
+		return ProblemDetailLevel(level)
+	}
+	return ProblemDetailFull
+}
+
+// writeStepProblem fills in the execution ID, the request ID, the request
+// path and the step name from pc and writes p to the pipeline's HTTP response writer, marking
+// the response handled. It reports whether a response writer was present.
+func writeStepProblem(pc *PipelineContext, step string, p *Problem) bool {
+	w, ok := pc.Metadata["_http_response_writer"].(http.ResponseWriter)
+	if !ok {
+		return false
+	}
+	if p.ExecutionID == "" {
+		p.ExecutionID, _ = pc.Metadata["execution_id"].(string)
+	}
+	if p.RequestID == "" {
+		p.RequestID, _ = pc.Metadata["request_id"].(string)
+	}
+	if p.Instance == "" {
+		if req, ok := pc.Metadata["_http_request"].(*http.Request); ok {
+			p.Instance = req.URL.Path
+		}
+	}
+	if p.Step == "" {
+		p.Step = step
+	}
+	WriteProblem(w, p, problemDetailLevel(pc))
+	pc.Metadata["_response_handled"] = true
+	return true
+}
+
+// problemOpenAPISchema describes Problem for generated OpenAPI documents.
+func problemOpenAPISchema() *OpenAPISchema {
+	codes := make([]string, 0, len(problemCatalog))
+	for _, c := range ProblemCodes() {
+		codes = append(codes, string(c))
+	}
+	return &OpenAPISchema{
+		Type:        "object",
+		Description: "RFC 7807 problem details. The type URI is " + ProblemTypeBaseURI + "<code>.",
+		Required:    []string{"type", "title", "status", "code"},
+		Properties: map[string]*OpenAPISchema{
+			"type":         {Type: "string", Format: "uri"},
+			"title":        {Type: "string"},
+			"status":       {Type: "integer"},
+			"detail":       {Type: "string"},
+			"instance":     {Type: "string"},
+			"code":         {Type: "string", Enum: codes},
+			"execution_id": {Type: "string"},
+			"request_id":   {Type: "string", Description: "X-Request-ID of the request, when the requestid middleware is configured"},
+			"step":         {Type: "string", Description: "Failing step; omitted when engine.errors.detail is minimal"},
+			"errors":       {Type: "array", Items: &OpenAPISchema{Type: "object"}},
+		},
+	}
+}
+
+// problemCodeForStatus returns the catalog code whose default status is
+// status, for steps whose error status is configurable.
+func problemCodeForStatus(status int) ProblemCode {
+	for _, c := range ProblemCodes() {
+		if c != ProblemValidationFailed && c != ProblemInternalError && problemCatalog[c].status == status {
+			return c
+		}
+	}
+	if status >= http.StatusInternalServerError {
+		return ProblemPipelineError
+	}
+	return ProblemBadRequest
+}
+
+// writeRequestProblem writes the catalog problem for code in response to r,
+// for handlers outside a pipeline such as gateways, proxies and middleware.
+func writeRequestProblem(w http.ResponseWriter, r *http.Request, code ProblemCode, detail string) {
+	p := NewProblem(code, detail)
+	p.Instance = r.URL.Path
+	p.RequestID = GetRequestID(r.Context())
+	WriteProblem(w, p, ProblemDetailFull)
+}
+
+// writeProxyProblem writes the problem for a failed request to a proxied
+// backend: upstream_timeout when it timed out, upstream_error otherwise.
+func writeProxyProblem(w http.ResponseWriter, r *http.Request, backend string, err error) {
+	code := ProblemUpstreamError
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout() {
+		code = ProblemUpstreamTimeout
+	}
+	writeRequestProblem(w, r, code, "backend "+backend+" unavailable")
+}
+
+// writeErrorProblem writes the problem for err, which ended the request r.
+func writeErrorProblem(w http.ResponseWriter, r *http.Request, err error, level ProblemDetailLevel) {
+	p := ProblemFromError(err)
+	p.Instance = r.URL.Path
+	if p.RequestID == "" {
+		p.RequestID = GetRequestID(r.Context())
+	}
+	WriteProblem(w, p, level)
+}
@@ -0,0 +1,153 @@
+package module
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/GoCodeAlone/workflow/interfaces"
+)
+
+func TestProblemFromError(t *testing.T) {
+	stepFailure := func(err error) error {
+		return fmt.Errorf("workflow execution failed: %w", &PipelineStepError{
+			Step: "lookup", ExecutionID: "exec-1", Err: fmt.Errorf("step %q failed: %w", "lookup", err),
+		})
+	}
+	ve := &interfaces.ValidationError{Message: "email is required", Status: 422, Field: "email"}
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	_, _ = db.Exec("CREATE TABLE orders (id TEXT PRIMARY KEY)")
+	_, _ = db.Exec("INSERT INTO orders (id) VALUES ('o1')")
+	_, dupErr := db.Exec("INSERT INTO orders (id) VALUES ('o1')")
+	if dupErr == nil {
+		t.Fatal("expected a constraint violation")
+	}
+	step, err := NewRateLimitStepFactory()("limit", map[string]any{"requests_per_minute": 1, "burst_size": 1}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rateLimit := step.(*RateLimitStep)
+	defer rateLimit.Stop()
+	_, _ = rateLimit.Execute(context.Background(), NewPipelineContext(nil, nil))
+	_, limitErr := rateLimit.Execute(context.Background(), NewPipelineContext(nil, nil))
+
+	tests := []struct {
+		name       string
+		err        error
+		wantCode   ProblemCode
+		wantStatus int
+		wantDetail string
+	}{
+		{"validation", stepFailure(ve), ProblemValidationFailed, 422, "email is required"},
+		{"timeout", stepFailure(context.DeadlineExceeded), ProblemUpstreamTimeout, 504, ""},
+		{"step problem", stepFailure(NewProblem(ProblemNotFound, "order 7 not found")), ProblemNotFound, 404, "order 7 not found"},
+		{"other", stepFailure(errors.New("boom")), ProblemPipelineError, 500, ""},
+		{"sqlite unique", stepFailure(fmt.Errorf("exec failed: %w", dupErr)), ProblemConflict, 409, "a record with the same key already exists"},
+		{"postgres not null", stepFailure(fakeSQLStateError("23502")), ProblemValidationFailed, 400, "a value is missing or invalid"},
+		{"postgres serialization", stepFailure(fakeSQLStateError("40001")), ProblemConflict, 409, ""},
+		{"no rows", stepFailure(sql.ErrNoRows), ProblemNotFound, 404, "record not found"},
+		{"rate limited", stepFailure(limitErr), ProblemRateLimited, 429, `rate limit exceeded for key "global"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := ProblemFromError(tt.err)
+			if ProblemCode(p.Code) != tt.wantCode || p.Status != tt.wantStatus || p.Type != ProblemTypeBaseURI+string(tt.wantCode) {
+				t.Errorf("got %+v, want code %s status %d", p, tt.wantCode, tt.wantStatus)
+			}
+			if tt.wantDetail != "" && p.Detail != tt.wantDetail {
+				t.Errorf("detail = %q, want %q", p.Detail, tt.wantDetail)
+			}
+			if p.Step != "lookup" || p.ExecutionID != "exec-1" {
+				t.Errorf("step = %q, execution_id = %q", p.Step, p.ExecutionID)
+			}
+		})
+	}
+
+	if p := ProblemFromError(ve); p.Errors == nil {
+		t.Error("validation problem should list the invalid field")
+	}
+}
+
+// fakeSQLStateError stands in for a PostgreSQL driver error.
+type fakeSQLStateError string
+
+func (e fakeSQLStateError) Error() string    { return "pq: error " + string(e) }
+func (e fakeSQLStateError) SQLState() string { return string(e) }
+
+func TestWriteProblemMinimal(t *testing.T) {
+	p := NewProblem(ProblemPipelineError, "db password rejected")
+	p.Step, p.ExecutionID = "query", "exec-1"
+	w := httptest.NewRecorder()
+	WriteProblem(w, p, ProblemDetailMinimal)
+
+	var got map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got["detail"]; ok {
+		t.Errorf("minimal 5xx problem leaked detail: %v", got)
+	}
+	if _, ok := got["step"]; ok {
+		t.Errorf("minimal problem leaked step: %v", got)
+	}
+	if got["execution_id"] != "exec-1" || w.Header().Get("Content-Type") != ProblemContentType {
+		t.Errorf("got %v, content type %q", got, w.Header().Get("Content-Type"))
+	}
+
+	// Client errors keep their detail.
+	w = httptest.NewRecorder()
+	WriteProblem(w, NewProblem(ProblemNotFound, "order 7 not found"), ProblemDetailMinimal)
+	if w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), "order 7 not found") {
+		t.Errorf("got %d %s", w.Code, w.Body)
+	}
+
+	if _, err := ParseProblemDetailLevel("verbose"); err == nil {
+		t.Error("expected an error for an unknown detail level")
+	}
+}
+
+func TestJSONResponseStep_Problem(t *testing.T) {
+	factory := NewJSONResponseStepFactory()
+	if _, err := factory("respond", map[string]any{"problem": map[string]any{"code": "teapot"}}, nil); err == nil {
+		t.Error("expected an error for an unknown problem code")
+	}
+	if _, err := factory("respond", map[string]any{"problem": map[string]any{"code": "not_found"}, "body": map[string]any{"a": 1}}, nil); err == nil {
+		t.Error("expected an error when problem is combined with body")
+	}
+
+	step, err := factory("respond", map[string]any{
+		"problem": map[string]any{"code": "not_found", "detail": "order {{ .id }} not found"},
+		"headers": map[string]any{"X-Trace": "t1"},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	pc := NewPipelineContext(map[string]any{"id": "7"}, map[string]any{
+		"_http_response_writer": w,
+		"_http_request":         httptest.NewRequest(http.MethodGet, "/orders/7", nil),
+		"execution_id":          "exec-1",
+	})
+	if _, err := step.Execute(context.Background(), pc); err != nil {
+		t.Fatal(err)
+	}
+	var p Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	want := Problem{Type: ProblemTypeBaseURI + "not_found", Title: "Not Found", Status: 404,
+		Detail: "order 7 not found", Instance: "/orders/7", Code: "not_found", ExecutionID: "exec-1"}
+	if p != want || w.Code != 404 || w.Header().Get("X-Trace") != "t1" {
+		t.Errorf("got %d %+v, want %+v", w.Code, p, want)
+	}
+}
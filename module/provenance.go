@@ -0,0 +1,155 @@
+package module
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+)
+
+// In-toto and SLSA identifiers used by step.attest.
+const (
+	InTotoStatementType         = "https://in-toto.io/Statement/v1"
+	SLSAProvenancePredicateType = "https://slsa.dev/provenance/v1"
+	DSSEInTotoPayloadType       = "application/vnd.in-toto+json"
+	WorkflowBuildType           = "https://github.com/GoCodeAlone/workflow/pipeline@v1"
+	DefaultWorkflowBuilderID    = "https://github.com/GoCodeAlone/workflow"
+)
+
+// InTotoStatement is an in-toto attestation statement about the artifacts
+// in Subject.
+type InTotoStatement struct {
+	Type          string          `json:"_type"`
+	Subject       []InTotoSubject `json:"subject"`
+	PredicateType string          `json:"predicateType"`
+	Predicate     any             `json:"predicate"`
+}
+
+// InTotoSubject identifies an attested artifact by name and digests.
+type InTotoSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// SLSAProvenance is a SLSA v1 provenance predicate.
+type SLSAProvenance struct {
+	BuildDefinition SLSABuildDefinition `json:"buildDefinition"`
+	RunDetails      SLSARunDetails      `json:"runDetails"`
+}
+
+// SLSABuildDefinition describes the inputs of a build.
+type SLSABuildDefinition struct {
+	BuildType            string                   `json:"buildType"`
+	ExternalParameters   map[string]any           `json:"externalParameters"`
+	InternalParameters   map[string]any           `json:"internalParameters,omitempty"`
+	ResolvedDependencies []SLSAResourceDescriptor `json:"resolvedDependencies,omitempty"`
+}
+
+// SLSAResourceDescriptor identifies a build material.
+type SLSAResourceDescriptor struct {
+	Name   string            `json:"name,omitempty"`
+	URI    string            `json:"uri,omitempty"`
+	Digest map[string]string `json:"digest,omitempty"`
+}
+
+// SLSARunDetails describes the builder and the run that produced a build.
+type SLSARunDetails struct {
+	Builder  SLSABuilder       `json:"builder"`
+	Metadata SLSABuildMetadata `json:"metadata"`
+}
+
+// SLSABuilder identifies the builder.
+type SLSABuilder struct {
+	ID      string            `json:"id"`
+	Version map[string]string `json:"version,omitempty"`
+}
+
+// SLSABuildMetadata records the invocation of a build.
+type SLSABuildMetadata struct {
+	InvocationID string `json:"invocationId,omitempty"`
+	StartedOn    string `json:"startedOn,omitempty"`
+	FinishedOn   string `json:"finishedOn,omitempty"`
+}
+
+// DSSEEnvelope is a signed Dead Simple Signing Envelope, the format cosign
+// attaches and verifies attestations in.
+type DSSEEnvelope struct {
+	PayloadType string          `json:"payloadType"`
+	Payload     string          `json:"payload"`
+	Signatures  []DSSESignature `json:"signatures"`
+}
+
+// DSSESignature is a signature of a DSSE envelope.
+type DSSESignature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// DSSEPreAuthEncoding returns the DSSE pre-authentication encoding of a
+// payload, the message its signatures sign.
+func DSSEPreAuthEncoding(payloadType string, payload []byte) []byte {
+	return fmt.Appendf(nil, "DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload)
+}
+
+// SignDSSE signs payload with signer into a DSSE envelope. ECDSA and RSA
+// keys sign the SHA-256 digest of the pre-authentication encoding, Ed25519
+// keys the encoding itself.
+func SignDSSE(payloadType string, payload []byte, signer crypto.Signer, keyID string) (*DSSEEnvelope, error) {
+	msg := DSSEPreAuthEncoding(payloadType, payload)
+	var (
+		sig []byte
+		err error
+	)
+	if _, ok := signer.Public().(ed25519.PublicKey); ok {
+		sig, err = signer.Sign(rand.Reader, msg, crypto.Hash(0))
+	} else {
+		digest := sha256.Sum256(msg)
+		sig, err = signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("sign payload: %w", err)
+	}
+	return &DSSEEnvelope{
+		PayloadType: payloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures:  []DSSESignature{{KeyID: keyID, Sig: base64.StdEncoding.EncodeToString(sig)}},
+	}, nil
+}
+
+// ParseSigningKey parses an unencrypted PEM private key: PKCS#8 (ECDSA,
+// Ed25519 or RSA), SEC 1 EC or PKCS#1 RSA.
+func ParseSigningKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("signing key is not PEM encoded")
+	}
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		switch k := key.(type) {
+		case *ecdsa.PrivateKey:
+			return k, nil
+		case ed25519.PrivateKey:
+			return k, nil
+		case *rsa.PrivateKey:
+			return k, nil
+		default:
+			return nil, fmt.Errorf("unsupported signing key type %T", key)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q (encrypted keys must be decrypted first)", block.Type)
+	}
+}
@@ -1,8 +1,10 @@
 package module
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"mime"
 	"net/http"
 	"strings"
 	"sync"
@@ -14,11 +16,27 @@ import (
 // QueryFunc is a read-only query function that returns data or an error.
 type QueryFunc func(ctx context.Context, r *http.Request) (any, error)
 
+// ResponseExampleRecorder collects a sample successful response per route,
+// for example to document it. *OpenAPIGenerator implements it.
+type ResponseExampleRecorder interface {
+	// HasResponseExample reports whether the route already has an example.
+	HasResponseExample(method, path string) bool
+	// RecordResponseExample records body as the route's example.
+	RecordResponseExample(method, path string, body any)
+}
+
+// maxResponseExampleBytes bounds the size of a response recorded as an
+// example; larger responses are not recorded.
+const maxResponseExampleBytes = 64 << 10
+
 // QueryHandler dispatches GET requests to named query functions.
 // Each query is registered by name and dispatched by extracting the last
 // path segment from the request URL. Route pipelines can be attached for
 // composable per-route processing. A delegate service can be configured
-// to handle requests that don't match any registered query name.
+// to handle requests that don't match any registered query name. When
+// example recording is enabled, the first successful JSON response of each
+// route is passed to a ResponseExampleRecorder. Routes with a
+// QueryETagConfig answer conditional requests.
 type QueryHandler struct {
 	name             string
 	delegate         string // service name to resolve as http.Handler
@@ -27,6 +45,10 @@ type QueryHandler struct {
 	queries          map[string]QueryFunc
 	routePipelines   map[string]interfaces.PipelineRunner
 	executionTracker ExecutionTrackerProvider
+	recordExamples   bool
+	exampleRecorder  ResponseExampleRecorder
+	exampleOnce      sync.Once
+	routeETags       map[string]*QueryETagConfig
 	mu               sync.RWMutex
 }
 
@@ -46,6 +68,15 @@ func (h *QueryHandler) SetRoutePipeline(routePath string, pipeline interfaces.Pi
 	h.routePipelines[routePath] = pipeline
 }
 
+// RunRoutePipeline runs the pipeline attached to routeKey ("METHOD /path")
+// without an HTTP request, recording it with the execution tracker if set.
+func (h *QueryHandler) RunRoutePipeline(ctx context.Context, routeKey string, data map[string]any) (map[string]any, error) {
+	h.mu.RLock()
+	pipeline := h.routePipelines[routeKey]
+	h.mu.RUnlock()
+	return runRoutePipeline(ctx, routeKey, pipeline, h.executionTracker, data)
+}
+
 // Name returns the unique identifier for this module.
 func (h *QueryHandler) Name() string {
 	return h.name
@@ -67,6 +98,19 @@ func (h *QueryHandler) SetExecutionTracker(t ExecutionTrackerProvider) {
 	h.executionTracker = t
 }
 
+// SetRecordExamples enables recording a sample successful response per
+// route with the ResponseExampleRecorder service (the OpenAPI generator),
+// which is looked up on the first request.
+func (h *QueryHandler) SetRecordExamples(enabled bool) {
+	h.recordExamples = enabled
+}
+
+// SetExampleRecorder enables example recording with rec.
+func (h *QueryHandler) SetExampleRecorder(rec ResponseExampleRecorder) {
+	h.recordExamples = true
+	h.exampleRecorder = rec
+}
+
 // Init initializes the query handler and resolves the delegate service.
 func (h *QueryHandler) Init(app modular.Application) error {
 	h.app = app
@@ -116,6 +160,32 @@ func (h *QueryHandler) Handle(w http.ResponseWriter, r *http.Request) {
 // to the last path segment for backward compatibility with registered queries.
 // Dispatch chain: RegisteredQueryFunc -> RoutePipeline -> DelegateHandler -> 404
 func (h *QueryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if cfg := h.routeETag(r); cfg != nil {
+		h.serveConditional(w, r, cfg, h.serveRecording)
+		return
+	}
+	h.serveRecording(w, r)
+}
+
+// serveRecording serves the request, recording its response as the route's
+// example when example recording is enabled.
+func (h *QueryHandler) serveRecording(w http.ResponseWriter, r *http.Request) {
+	if rec := h.responseExampleRecorder(); rec != nil {
+		method, path := exampleRoute(r)
+		if !rec.HasResponseExample(method, path) {
+			tee := &exampleTeeWriter{ResponseWriter: w}
+			h.serve(tee, r)
+			if body, ok := tee.example(); ok {
+				rec.RecordResponseExample(method, path, body)
+			}
+			return
+		}
+	}
+	h.serve(w, r)
+}
+
+// serve dispatches the request; see ServeHTTP.
+func (h *QueryHandler) serve(w http.ResponseWriter, r *http.Request) {
 	queryName := lastPathSegment(r.URL.Path)
 	// Use Go 1.22+ pattern for pipeline lookup (avoids last-segment collisions)
 	routeKey := r.Pattern
@@ -132,9 +202,7 @@ func (h *QueryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if exists {
 		result, err := fn(r.Context(), r)
 		if err != nil {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusInternalServerError)
-			_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			writeErrorProblem(w, r, err, ProblemDetailFull)
 			return
 		}
 		w.Header().Set("Content-Type", "application/json")
@@ -177,9 +245,7 @@ func (h *QueryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			if err != nil {
 				// Only write error if response wasn't already handled by a delegate step
 				if pc == nil || pc.Metadata["_response_handled"] != true {
-					w.Header().Set("Content-Type", "application/json")
-					w.WriteHeader(http.StatusInternalServerError)
-					_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+					writeErrorProblem(w, r, err, concretePipeline.ProblemDetail)
 				}
 				return
 			}
@@ -196,9 +262,7 @@ func (h *QueryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			// Fallback for non-*Pipeline implementations: use the PipelineRunner interface.
 			result, err := pipeline.Run(r.Context(), triggerData)
 			if err != nil {
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusInternalServerError)
-				_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+				writeErrorProblem(w, r, err, ProblemDetailFull)
 				return
 			}
 			// Allow the runner to signal that it has already written the response.
@@ -219,9 +283,9 @@ func (h *QueryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusNotFound)
-	_ = json.NewEncoder(w).Encode(map[string]string{"error": "unknown query: " + queryName})
+	p := NewProblem(ProblemNotFound, "unknown query: "+queryName)
+	p.Instance = r.URL.Path
+	WriteProblem(w, p, ProblemDetailFull)
 }
 
 // ProvidesServices returns a list of services provided by this module.
@@ -257,3 +321,94 @@ func lastPathSegment(path string) string {
 	}
 	return path
 }
+
+// responseExampleRecorder returns the recorder of response examples, or nil
+// when recording is disabled or there is no recorder service.
+func (h *QueryHandler) responseExampleRecorder() ResponseExampleRecorder {
+	if !h.recordExamples {
+		return nil
+	}
+	h.exampleOnce.Do(func() {
+		if h.exampleRecorder != nil || h.app == nil {
+			return
+		}
+		if match, _ := FindByInterface[ResponseExampleRecorder](h.app.SvcRegistry()); match.Service != nil {
+			h.exampleRecorder = match.Service
+		}
+	})
+	return h.exampleRecorder
+}
+
+// exampleRoute returns the method and route path that a request's response
+// example is recorded under: those of the matched ServeMux pattern (e.g.
+// "GET /items/{id}"), or of the request itself.
+func exampleRoute(r *http.Request) (method, path string) {
+	method, path = r.Method, r.URL.Path
+	if r.Pattern != "" {
+		if m, p, ok := strings.Cut(r.Pattern, " "); ok {
+			method, path = m, p
+		} else {
+			path = r.Pattern
+		}
+	}
+	return method, path
+}
+
+// exampleTeeWriter passes a response through unchanged while keeping its
+// status and a copy of its body, up to maxResponseExampleBytes.
+type exampleTeeWriter struct {
+	http.ResponseWriter
+	status    int
+	body      bytes.Buffer
+	truncated bool
+}
+
+func (t *exampleTeeWriter) WriteHeader(code int) {
+	if t.status == 0 {
+		t.status = code
+	}
+	t.ResponseWriter.WriteHeader(code)
+}
+
+func (t *exampleTeeWriter) Write(b []byte) (int, error) {
+	if t.status == 0 {
+		t.status = http.StatusOK
+	}
+	if !t.truncated {
+		if t.body.Len()+len(b) > maxResponseExampleBytes {
+			t.truncated = true
+			t.body.Reset()
+		} else {
+			t.body.Write(b)
+		}
+	}
+	return t.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher when the underlying writer does.
+func (t *exampleTeeWriter) Flush() {
+	if f, ok := t.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying writer for http.ResponseController.
+func (t *exampleTeeWriter) Unwrap() http.ResponseWriter {
+	return t.ResponseWriter
+}
+
+// example returns the decoded body of a complete 200 JSON response.
+func (t *exampleTeeWriter) example() (any, bool) {
+	if t.status != http.StatusOK || t.truncated || t.body.Len() == 0 {
+		return nil, false
+	}
+	mediaType, _, err := mime.ParseMediaType(t.Header().Get("Content-Type"))
+	if err != nil || (mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json")) {
+		return nil, false
+	}
+	var body any
+	if err := json.Unmarshal(t.body.Bytes(), &body); err != nil {
+		return nil, false
+	}
+	return body, true
+}
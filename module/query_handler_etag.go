@@ -0,0 +1,263 @@
+package module
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultQueryCacheControl makes clients revalidate a cached response on
+// every request, which is what the ETag is for.
+const defaultQueryCacheControl = "no-cache"
+
+// QueryETagConfig enables conditional requests on a query route, set with the
+// `etag` key of an HTTP workflow route:
+//
+//	routes:
+//	  - method: GET
+//	    path: /api/orders
+//	    handler: queries
+//	    etag:
+//	      cacheControl: "private, max-age=5"
+//	      lastModified: updated_at
+//
+// `etag: true` enables it with the defaults. Successful GET and HEAD
+// responses get a weak ETag computed over the body, and a request whose
+// If-None-Match matches it is answered with 304 Not Modified and no body.
+// Responses are buffered, so this is not meant for streaming routes.
+type QueryETagConfig struct {
+	// CacheControl is the Cache-Control header of successful responses,
+	// "no-cache" by default. A Cache-Control set by the route wins.
+	CacheControl string
+	// LastModified is the dotted path of a timestamp in the JSON response
+	// body; for an array body, the latest of its items' timestamps is used.
+	// It sets Last-Modified and is compared with If-Modified-Since when the
+	// request has no If-None-Match.
+	LastModified string
+}
+
+// ParseQueryETagConfig parses the `etag` value of a route: a bool, or a map
+// with optional `cacheControl` and `lastModified` keys. It returns nil when
+// conditional requests are disabled.
+func ParseQueryETagConfig(raw any) (*QueryETagConfig, error) {
+	switch v := raw.(type) {
+	case nil:
+		return nil, nil
+	case bool:
+		if !v {
+			return nil, nil
+		}
+		return &QueryETagConfig{CacheControl: defaultQueryCacheControl}, nil
+	case map[string]any:
+		cfg := &QueryETagConfig{CacheControl: defaultQueryCacheControl}
+		for key, val := range v {
+			s, ok := val.(string)
+			switch key {
+			case "cacheControl":
+				if !ok {
+					return nil, fmt.Errorf("etag: 'cacheControl' must be a string")
+				}
+				if s != "" {
+					cfg.CacheControl = s
+				}
+			case "lastModified":
+				if !ok {
+					return nil, fmt.Errorf("etag: 'lastModified' must be a string")
+				}
+				cfg.LastModified = s
+			default:
+				return nil, fmt.Errorf("etag: unknown key %q", key)
+			}
+		}
+		return cfg, nil
+	default:
+		return nil, fmt.Errorf("etag must be a bool or a map, got %T", raw)
+	}
+}
+
+// SetRouteETag enables conditional requests on routeKey ("METHOD /path").
+// A nil cfg disables them.
+func (h *QueryHandler) SetRouteETag(routeKey string, cfg *QueryETagConfig) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	method, path, _ := strings.Cut(routeKey, " ")
+	routeKey = strings.ToUpper(method) + " " + path
+	if cfg == nil {
+		delete(h.routeETags, routeKey)
+		return
+	}
+	if h.routeETags == nil {
+		h.routeETags = make(map[string]*QueryETagConfig)
+	}
+	h.routeETags[routeKey] = cfg
+}
+
+// routeETag returns the conditional request config of the request's route,
+// or nil when it has none or the request is not a GET or HEAD.
+func (h *QueryHandler) routeETag(r *http.Request) *QueryETagConfig {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return nil
+	}
+	method, path := exampleRoute(r)
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if len(h.routeETags) == 0 {
+		return nil
+	}
+	return h.routeETags[strings.ToUpper(method)+" "+path]
+}
+
+// serveConditional serves r with its response buffered, so that a 200
+// response can be tagged and replaced by 304 Not Modified when the client's
+// copy is current.
+func (h *QueryHandler) serveConditional(w http.ResponseWriter, r *http.Request, cfg *QueryETagConfig, next func(http.ResponseWriter, *http.Request)) {
+	buf := &bufferedResponseWriter{header: w.Header()}
+	next(buf, r)
+
+	status := buf.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if status != http.StatusOK {
+		w.WriteHeader(status)
+		_, _ = w.Write(buf.body.Bytes())
+		return
+	}
+
+	body := buf.body.Bytes()
+	hdr := w.Header()
+	etag := weakETag(body)
+	hdr.Set("ETag", etag)
+	if hdr.Get("Cache-Control") == "" && cfg.CacheControl != "" {
+		hdr.Set("Cache-Control", cfg.CacheControl)
+	}
+	var lastModified time.Time
+	if cfg.LastModified != "" {
+		lastModified = responseLastModified(body, cfg.LastModified)
+		if !lastModified.IsZero() {
+			hdr.Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+		}
+	}
+
+	if notModified(r, etag, lastModified) {
+		hdr.Del("Content-Type")
+		hdr.Del("Content-Length")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(body)
+	}
+}
+
+// weakETag returns a weak entity tag for body. It is weak because the body
+// is the JSON encoding of the result, which is only semantically stable.
+func weakETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// notModified reports whether the client's cached response is current:
+// If-None-Match lists etag (compared weakly, as RFC 9110 requires for
+// If-None-Match), or, without If-None-Match, the resource has not changed
+// since If-Modified-Since.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+	if lastModified.IsZero() {
+		return false
+	}
+	ims, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !lastModified.Truncate(time.Second).After(ims)
+}
+
+// responseLastModified returns the timestamp at path in a JSON body, or the
+// latest one among the items of an array body. It returns the zero time when
+// there is none.
+func responseLastModified(body []byte, path string) time.Time {
+	var data any
+	if err := json.Unmarshal(body, &data); err != nil {
+		return time.Time{}
+	}
+	items, ok := data.([]any)
+	if !ok {
+		items = []any{data}
+	}
+	var latest time.Time
+	for _, item := range items {
+		v, err := resolveDottedPath(item, path)
+		if err != nil {
+			continue
+		}
+		if t, ok := parseLastModified(v); ok && t.After(latest) {
+			latest = t
+		}
+	}
+	return latest
+}
+
+// lastModifiedLayouts are the timestamp layouts parseLastModified accepts.
+var lastModifiedLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	http.TimeFormat,
+}
+
+// parseLastModified parses a timestamp string or a number of Unix seconds.
+func parseLastModified(v any) (time.Time, bool) {
+	switch t := v.(type) {
+	case string:
+		for _, layout := range lastModifiedLayouts {
+			if ts, err := time.Parse(layout, t); err == nil {
+				return ts, true
+			}
+		}
+	case float64:
+		if t > 0 && !math.IsInf(t, 0) {
+			sec, frac := math.Modf(t)
+			return time.Unix(int64(sec), int64(frac*1e9)), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// bufferedResponseWriter holds a response until it is complete. Headers go
+// straight to the underlying writer's header map; status and body are kept.
+type bufferedResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponseWriter) Header() http.Header { return b.header }
+
+func (b *bufferedResponseWriter) WriteHeader(code int) {
+	if b.status == 0 {
+		b.status = code
+	}
+}
+
+func (b *bufferedResponseWriter) Write(p []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	return b.body.Write(p)
+}
@@ -0,0 +1,152 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newETagQueryHandler(cfg *QueryETagConfig, result func() any) *http.ServeMux {
+	h := NewQueryHandler("q")
+	h.RegisterQuery("items", func(_ context.Context, r *http.Request) (any, error) {
+		if r.URL.Query().Get("fail") != "" {
+			return nil, errors.New("boom")
+		}
+		return result(), nil
+	})
+	h.SetRouteETag("get /api/items", cfg)
+	mux := http.NewServeMux()
+	mux.Handle("GET /api/items", h)
+	return mux
+}
+
+func TestQueryHandler_ETag(t *testing.T) {
+	items := []any{
+		map[string]any{"id": 1, "updated_at": "2026-03-01T10:00:00Z"},
+		map[string]any{"id": 2, "updated_at": "2026-03-02T08:30:00Z"},
+	}
+	cfg, err := ParseQueryETagConfig(map[string]any{"lastModified": "updated_at"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := newETagQueryHandler(cfg, func() any { return items })
+
+	do := func(method, target string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("GET", "/api/items", nil)
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || !strings.HasPrefix(etag, `W/"`) || !strings.Contains(w.Body.String(), `"id":2`) {
+		t.Fatalf("first response = %d %q %s", w.Code, etag, w.Body)
+	}
+	if got := w.Header().Get("Cache-Control"); got != "no-cache" {
+		t.Errorf("Cache-Control = %q", got)
+	}
+	if got := w.Header().Get("Last-Modified"); got != "Mon, 02 Mar 2026 08:30:00 GMT" {
+		t.Errorf("Last-Modified = %q", got)
+	}
+
+	// A matching If-None-Match, weak or strong, in a list or *, is a 304.
+	for _, inm := range []string{etag, strings.TrimPrefix(etag, "W/"), `"other", ` + etag, "*"} {
+		w = do("GET", "/api/items", http.Header{"If-None-Match": {inm}})
+		if w.Code != http.StatusNotModified || w.Body.Len() != 0 || w.Header().Get("ETag") != etag {
+			t.Errorf("If-None-Match %s = %d %q", inm, w.Code, w.Body)
+		}
+		if w.Header().Get("Content-Type") != "" {
+			t.Errorf("304 Content-Type = %q", w.Header().Get("Content-Type"))
+		}
+	}
+	// A stale tag wins over a current If-Modified-Since.
+	w = do("GET", "/api/items", http.Header{
+		"If-None-Match":     {`W/"stale"`},
+		"If-Modified-Since": {"Tue, 03 Mar 2026 00:00:00 GMT"},
+	})
+	if w.Code != http.StatusOK {
+		t.Errorf("stale tag = %d", w.Code)
+	}
+	w = do("GET", "/api/items", http.Header{"If-Modified-Since": {"Mon, 02 Mar 2026 08:30:00 GMT"}})
+	if w.Code != http.StatusNotModified {
+		t.Errorf("If-Modified-Since current = %d", w.Code)
+	}
+	w = do("GET", "/api/items", http.Header{"If-Modified-Since": {"Sun, 01 Mar 2026 00:00:00 GMT"}})
+	if w.Code != http.StatusOK {
+		t.Errorf("If-Modified-Since stale = %d", w.Code)
+	}
+
+	// A changed body changes the tag.
+	items = append(items, map[string]any{"id": 3})
+	w = do("GET", "/api/items", http.Header{"If-None-Match": {etag}})
+	if w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Errorf("changed body = %d %q", w.Code, w.Header().Get("ETag"))
+	}
+
+	// HEAD gets the headers without the body.
+	w = do("HEAD", "/api/items", nil)
+	if w.Code != http.StatusOK || w.Body.Len() != 0 || w.Header().Get("ETag") == "" {
+		t.Errorf("HEAD = %d %q %q", w.Code, w.Body, w.Header().Get("ETag"))
+	}
+
+	// Errors pass through untagged.
+	w = do("GET", "/api/items?fail=1", http.Header{"If-None-Match": {"*"}})
+	if w.Code != http.StatusInternalServerError || w.Header().Get("ETag") != "" || !strings.Contains(w.Body.String(), "boom") {
+		t.Errorf("error = %d %q %s", w.Code, w.Header().Get("ETag"), w.Body)
+	}
+}
+
+func TestQueryHandler_ETagOptIn(t *testing.T) {
+	h := NewQueryHandler("q")
+	h.RegisterQuery("items", func(context.Context, *http.Request) (any, error) { return []any{1}, nil })
+	mux := http.NewServeMux()
+	mux.Handle("GET /api/items", h)
+
+	req := httptest.NewRequest("GET", "/api/items", nil)
+	req.Header.Set("If-None-Match", "*")
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != "" {
+		t.Errorf("route without etag = %d %q", w.Code, w.Header().Get("ETag"))
+	}
+}
+
+func TestParseQueryETagConfig(t *testing.T) {
+	if cfg, err := ParseQueryETagConfig(false); cfg != nil || err != nil {
+		t.Errorf("false = %v, %v", cfg, err)
+	}
+	cfg, err := ParseQueryETagConfig(true)
+	if err != nil || cfg.CacheControl != "no-cache" || cfg.LastModified != "" {
+		t.Errorf("true = %+v, %v", cfg, err)
+	}
+	cfg, err = ParseQueryETagConfig(map[string]any{"cacheControl": "private, max-age=5"})
+	if err != nil || cfg.CacheControl != "private, max-age=5" {
+		t.Errorf("map = %+v, %v", cfg, err)
+	}
+	for _, bad := range []any{"yes", map[string]any{"lastModifed": "x"}, map[string]any{"cacheControl": 5}} {
+		if _, err := ParseQueryETagConfig(bad); err == nil {
+			t.Errorf("%v: expected error", bad)
+		}
+	}
+}
+
+func TestParseLastModified(t *testing.T) {
+	want := time.Date(2026, 3, 2, 8, 30, 0, 0, time.UTC)
+	for _, v := range []any{"2026-03-02T08:30:00Z", "2026-03-02 08:30:00", "2026-03-02T09:30:00+01:00", float64(want.Unix())} {
+		got, ok := parseLastModified(v)
+		if !ok || !got.Equal(want) {
+			t.Errorf("%v = %v, %v", v, got, ok)
+		}
+	}
+	if _, ok := parseLastModified("yesterday"); ok {
+		t.Error("parsed an invalid timestamp")
+	}
+}
@@ -108,12 +108,12 @@ func TestQueryHandler_DispatchError(t *testing.T) {
 		t.Errorf("expected 500, got %d", rr.Code)
 	}
 
-	var result map[string]string
+	var result Problem
 	if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
 		t.Fatalf("failed to decode response: %v", err)
 	}
-	if result["error"] != "something went wrong" {
-		t.Errorf("expected error message, got %v", result)
+	if result.Code != "pipeline_error" || result.Detail != "something went wrong" {
+		t.Errorf("expected error message, got %+v", result)
 	}
 }
 
@@ -271,12 +271,12 @@ func TestQueryHandler_RoutePipeline_MockRunner_Error(t *testing.T) {
 	if rr.Code != http.StatusInternalServerError {
 		t.Errorf("expected 500, got %d", rr.Code)
 	}
-	var got map[string]string
+	var got Problem
 	if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
 		t.Fatalf("failed to decode error response: %v", err)
 	}
-	if got["error"] != "runner failed" {
-		t.Errorf("expected error=runner failed, got %v", got)
+	if got.Detail != "runner failed" {
+		t.Errorf("expected detail=runner failed, got %+v", got)
 	}
 }
 
@@ -299,3 +299,87 @@ func TestQueryHandler_RoutePipeline_TypedNil(t *testing.T) {
 		t.Errorf("expected 404 for typed-nil pipeline, got %d", rr.Code)
 	}
 }
+
+func TestQueryHandler_RecordsResponseExamples(t *testing.T) {
+	gen := NewOpenAPIGenerator("openapi", OpenAPIGeneratorConfig{})
+	gen.BuildSpecFromRoutes([]RouteDefinition{{Method: "GET", Path: "/api/items/{id}"}})
+
+	h := NewQueryHandler("test-queries")
+	for _, id := range []string{"bad", "first", "second"} {
+		h.RegisterQuery(id, func(_ context.Context, r *http.Request) (any, error) {
+			if r.PathValue("id") == "bad" {
+				return nil, errors.New("boom")
+			}
+			return map[string]any{"id": r.PathValue("id")}, nil
+		})
+	}
+	h.SetExampleRecorder(gen)
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /api/items/{id}", h)
+	for _, id := range []string{"bad", "first", "second"} {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/items/"+id, nil))
+		if id != "bad" && rr.Code != http.StatusOK {
+			t.Fatalf("GET %s: expected 200, got %d", id, rr.Code)
+		}
+	}
+
+	// The failed request is not recorded; the first success is.
+	example, ok := gen.GetSpec().Paths["/api/items/{id}"].Get.Responses["200"].Content["application/json"].Example.(map[string]any)
+	if !ok {
+		t.Fatal("expected a recorded example")
+	}
+	if example["id"] != "first" {
+		t.Errorf("expected the first successful response as example, got %v", example)
+	}
+}
+
+func TestQueryHandler_RecordExamplesDisabled(t *testing.T) {
+	gen := NewOpenAPIGenerator("openapi", OpenAPIGeneratorConfig{})
+	h := NewQueryHandler("test-queries")
+	h.RegisterQuery("items", func(_ context.Context, _ *http.Request) (any, error) {
+		return []any{"a"}, nil
+	})
+	h.SetRecordExamples(false)
+	h.exampleRecorder = gen
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/items", nil))
+	if gen.HasResponseExample("GET", "/api/items") {
+		t.Error("expected no example when recording is disabled")
+	}
+}
+
+func TestQueryHandler_RunRoutePipeline_RecordsTriggerType(t *testing.T) {
+	store := setupTestStoreWithWorkflow(t, "test-wf")
+	h := NewQueryHandler("test-queries")
+	h.SetExecutionTracker(&ExecutionTracker{Store: store, WorkflowID: "test-wf"})
+	h.SetRoutePipeline("GET /api/reports", &Pipeline{
+		Name:         "test-queries:reports",
+		Steps:        []PipelineStep{newMockStep("build", map[string]any{"built": true})},
+		RoutePattern: "/api/reports",
+	})
+
+	result, err := h.RunRoutePipeline(WithTriggerType(context.Background(), "schedule"), "GET /api/reports", nil)
+	if err != nil {
+		t.Fatalf("RunRoutePipeline: %v", err)
+	}
+	if result["built"] != true {
+		t.Errorf("expected step output in result, got %v", result)
+	}
+
+	var triggerType, status string
+	if err := store.DB().QueryRow(
+		"SELECT trigger_type, status FROM workflow_executions WHERE workflow_id = 'test-wf'",
+	).Scan(&triggerType, &status); err != nil {
+		t.Fatalf("query execution: %v", err)
+	}
+	if triggerType != "schedule" || status != "completed" {
+		t.Errorf("expected completed schedule execution, got trigger %q status %q", triggerType, status)
+	}
+
+	if _, err := h.RunRoutePipeline(context.Background(), "GET /api/missing", nil); err == nil {
+		t.Error("expected an error for a route without a pipeline")
+	}
+}
@@ -3,13 +3,33 @@ package module
 import (
 	"context"
 	"net/http"
+	"regexp"
 
 	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow/observability/tracing"
 	"github.com/google/uuid"
 )
 
+// RequestIDHeader is the header a request ID is read from and echoed on.
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestIDRe matches the inbound request IDs the middleware accepts;
+// anything else is replaced, so a client cannot inject arbitrary text into
+// logs, events and outbound baggage.
+var validRequestIDRe = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
 type requestIDKey struct{}
 
+type requestIDHeaderKey struct{}
+
+// WithRequestID returns ctx carrying id as its request ID, both for
+// GetRequestID and as OTEL baggage, so the ID reaches pipeline metadata,
+// step logs, execution events and outbound calls.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	ctx = context.WithValue(ctx, requestIDKey{}, id)
+	return tracing.WithBaggage(ctx, map[string]string{tracing.BaggageRequestID: id})
+}
+
 // GetRequestID extracts the request ID from the context.
 func GetRequestID(ctx context.Context) string {
 	if id, ok := ctx.Value(requestIDKey{}).(string); ok {
@@ -18,8 +38,32 @@ func GetRequestID(ctx context.Context) string {
 	return ""
 }
 
-// RequestIDMiddleware reads X-Request-ID header or generates a UUID,
-// sets it on the context and response header.
+// requestIDHeaderName returns the header the request ID of ctx was read
+// from, X-Request-ID unless the middleware was configured otherwise.
+func requestIDHeaderName(ctx context.Context) string {
+	if h, ok := ctx.Value(requestIDHeaderKey{}).(string); ok && h != "" {
+		return h
+	}
+	return RequestIDHeader
+}
+
+// InjectRequestID sets the request ID of ctx on the headers of an outbound
+// request, under the header it was received in, so the upstream service can
+// log the same ID. A header already set is kept.
+func InjectRequestID(ctx context.Context, h http.Header) {
+	id := GetRequestID(ctx)
+	if id == "" {
+		return
+	}
+	name := requestIDHeaderName(ctx)
+	if h.Get(name) == "" {
+		h.Set(name, id)
+	}
+}
+
+// RequestIDMiddleware reads the X-Request-ID header (or the configured
+// header), or generates a UUID when it is missing or malformed, sets it on
+// the context with WithRequestID and echoes it on the response header.
 type RequestIDMiddleware struct {
 	name       string
 	headerName string
@@ -29,7 +73,15 @@ type RequestIDMiddleware struct {
 func NewRequestIDMiddleware(name string) *RequestIDMiddleware {
 	return &RequestIDMiddleware{
 		name:       name,
-		headerName: "X-Request-ID",
+		headerName: RequestIDHeader,
+	}
+}
+
+// SetHeaderName sets the header the request ID is read from, echoed on and
+// propagated in, instead of X-Request-ID.
+func (m *RequestIDMiddleware) SetHeaderName(name string) {
+	if name != "" {
+		m.headerName = http.CanonicalHeaderKey(name)
 	}
 }
 
@@ -47,12 +99,15 @@ func (m *RequestIDMiddleware) Init(app modular.Application) error {
 func (m *RequestIDMiddleware) Process(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		requestID := r.Header.Get(m.headerName)
-		if requestID == "" {
+		if !validRequestIDRe.MatchString(requestID) {
 			requestID = uuid.New().String()
 		}
 
-		ctx := context.WithValue(r.Context(), requestIDKey{}, requestID)
 		w.Header().Set(m.headerName, requestID)
+		ctx := WithRequestID(r.Context(), requestID)
+		if m.headerName != RequestIDHeader {
+			ctx = context.WithValue(ctx, requestIDHeaderKey{}, m.headerName)
+		}
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
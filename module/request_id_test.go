@@ -1,10 +1,18 @@
 package module
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+
+	"github.com/GoCodeAlone/workflow/store"
+	"github.com/google/uuid"
 )
 
 func TestNewRequestIDMiddleware(t *testing.T) {
@@ -92,3 +100,133 @@ func TestRequestIDMiddleware_ProvidesServices(t *testing.T) {
 		t.Errorf("expected service name 'test-request-id', got %q", svcs[0].Name)
 	}
 }
+
+func TestRequestIDMiddleware_ReplacesMalformedHeader(t *testing.T) {
+	m := NewRequestIDMiddleware("test-request-id")
+	var capturedID string
+	handler := m.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		capturedID = GetRequestID(r.Context())
+	}))
+
+	for _, bad := range []string{"has spaces", "line\nbreak", strings.Repeat("x", 129)} {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("X-Request-ID", bad)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if _, err := uuid.Parse(capturedID); err != nil || rec.Header().Get("X-Request-ID") != capturedID {
+			t.Errorf("header %q: request ID %q, response header %q", bad, capturedID, rec.Header().Get("X-Request-ID"))
+		}
+	}
+}
+
+func TestRequestIDFlowsIntoExecution(t *testing.T) {
+	events := store.NewInMemoryEventStore()
+	var logs bytes.Buffer
+	p := &Pipeline{
+		Name: "orders",
+		Steps: []PipelineStep{
+			newMockStep("a", nil),
+			newFailingStep("b", errors.New("boom")),
+		},
+		Logger:        slog.New(slog.NewJSONHandler(&logs, nil)),
+		EventRecorder: store.NewEventRecorderAdapter(events),
+		ExecutionID:   "5f0c6d1e-8a4b-4b8e-9a43-0d9c6b1f7f21",
+	}
+
+	var pc *PipelineContext
+	handler := NewRequestIDMiddleware("request-id").Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		pc, err = p.Execute(r.Context(), nil)
+		writeErrorProblem(w, r, err, ProblemDetailFull)
+	}))
+	req := httptest.NewRequest("POST", "/orders", nil)
+	req.Header.Set("X-Request-ID", "req-7")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if pc.Metadata["request_id"] != "req-7" {
+		t.Errorf("metadata request_id = %v", pc.Metadata["request_id"])
+	}
+	var problem Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil || problem.RequestID != "req-7" || rec.Header().Get("X-Request-ID") != "req-7" {
+		t.Errorf("response %q, header %q", rec.Body.String(), rec.Header().Get("X-Request-ID"))
+	}
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		if !strings.Contains(line, `"request_id":"req-7"`) {
+			t.Errorf("log line without request ID: %s", line)
+		}
+	}
+
+	evs, _ := events.GetEvents(context.Background(), uuid.MustParse(p.ExecutionID))
+	if len(evs) == 0 {
+		t.Fatal("no events recorded")
+	}
+	for _, ev := range evs {
+		var data map[string]any
+		_ = json.Unmarshal(ev.EventData, &data)
+		if data["request_id"] != "req-7" {
+			t.Errorf("%s event without request ID: %s", ev.EventType, ev.EventData)
+		}
+	}
+	execs, err := events.ListExecutions(context.Background(), store.ExecutionEventFilter{RequestID: "req-7"})
+	if err != nil || len(execs) != 1 || execs[0].ExecutionID.String() != p.ExecutionID {
+		t.Errorf("executions for req-7 = %+v, %v", execs, err)
+	}
+}
+
+func TestRequestIDMiddleware_CustomHeaderPropagates(t *testing.T) {
+	m := NewRequestIDMiddleware("request-id")
+	m.SetHeaderName("x-correlation-id")
+
+	var upstream http.Header
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstream = r.Header.Clone()
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer api.Close()
+
+	var pc *PipelineContext
+	var webhookHeaders map[string]string
+	handler := m.Process(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call, err := NewHTTPCallStepFactory()("call", map[string]any{"url": api.URL, "method": "GET"}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := &Pipeline{Name: "p", Steps: []PipelineStep{call}}
+		if pc, err = p.Execute(r.Context(), map[string]any{"x": 1}); err != nil {
+			t.Fatal(err)
+		}
+		webhookHeaders = withRequestIDHeader(r.Context(), map[string]string{"X-Other": "y"})
+	}))
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Correlation-ID", "corr-1")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Header().Get("X-Correlation-ID") != "corr-1" || rec.Header().Get("X-Request-ID") != "" {
+		t.Errorf("response headers = %v", rec.Header())
+	}
+	if pc.TriggerData["_request_id"] != "corr-1" || pc.TriggerData["x"] != 1 {
+		t.Errorf("trigger data = %v", pc.TriggerData)
+	}
+	if upstream.Get("X-Correlation-ID") != "corr-1" {
+		t.Errorf("outbound headers = %v", upstream)
+	}
+	if webhookHeaders["X-Correlation-Id"] != "corr-1" || webhookHeaders["X-Other"] != "y" {
+		t.Errorf("webhook headers = %v", webhookHeaders)
+	}
+}
+
+func TestInjectRequestID_KeepsExplicitHeader(t *testing.T) {
+	ctx := WithRequestID(context.Background(), "req-1")
+	h := http.Header{"X-Request-Id": {"mine"}}
+	InjectRequestID(ctx, h)
+	if h.Get("X-Request-ID") != "mine" {
+		t.Errorf("header = %q", h.Get("X-Request-ID"))
+	}
+	h = http.Header{}
+	InjectRequestID(context.Background(), h)
+	if len(h) != 0 {
+		t.Errorf("headers without request ID = %v", h)
+	}
+}
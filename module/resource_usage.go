@@ -0,0 +1,151 @@
+package module
+
+import (
+	"database/sql"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ResourceReporter is implemented by modules, and workflow handlers, that
+// attribute the resources they hold. The engine collects the reports into
+// GET /api/workflow/status and the multi-workflow status API, and the
+// metrics collector exports them labeled by module.
+//
+// Implementations must honor this contract:
+//
+//   - ResourceUsage is called on every status request and metrics scrape. It
+//     must be cheap, must not perform I/O, and must be safe to call
+//     concurrently, including before Start and after Stop.
+//   - Values are gauges: current amounts that rise and fall, not running
+//     totals.
+//   - A module reports only what it owns and tracks itself. Goroutines are
+//     counted as the module starts and ends them (see GoroutineCounter), never
+//     by inspecting the runtime, and memory is the module's own estimate of
+//     the data it buffers.
+//   - Gauge names are snake_case. Use the Resource* names where they fit so
+//     per-workflow totals add up, and omit a gauge rather than guess it.
+type ResourceReporter interface {
+	ResourceUsage() ResourceUsage
+}
+
+// ResourceUsage maps gauge names to their current values.
+type ResourceUsage map[string]float64
+
+// Standard resource gauge names.
+const (
+	// ResourceGoroutines counts goroutines the module started and that have
+	// not yet returned.
+	ResourceGoroutines = "goroutines"
+	// ResourceMemoryEstimateBytes estimates the bytes of data the module
+	// buffers, such as retained entries or queued payloads.
+	ResourceMemoryEstimateBytes = "memory_estimate_bytes"
+	// ResourceDBOpenConnections, ResourceDBInUseConnections and
+	// ResourceDBIdleConnections describe a database/sql connection pool.
+	ResourceDBOpenConnections  = "db_open_connections"
+	ResourceDBInUseConnections = "db_in_use_connections"
+	ResourceDBIdleConnections  = "db_idle_connections"
+	// ResourceQueueLength counts work waiting to be processed.
+	ResourceQueueLength = "queue_length"
+	// ResourceBufferedMessages counts messages or entries held in memory.
+	ResourceBufferedMessages = "buffered_messages"
+	// ResourceCacheEntries counts entries held in a cache or registry.
+	ResourceCacheEntries = "cache_entries"
+	// ResourceInFlight counts operations currently executing.
+	ResourceInFlight = "in_flight"
+)
+
+// GoroutineCounter counts the goroutines a module starts so it can report
+// them as ResourceGoroutines. The zero value is ready to use.
+type GoroutineCounter struct {
+	n atomic.Int64
+}
+
+// Go runs fn in a new goroutine that is counted until fn returns.
+func (c *GoroutineCounter) Go(fn func()) {
+	c.n.Add(1)
+	go func() {
+		defer c.n.Add(-1)
+		fn()
+	}()
+}
+
+// Track counts one goroutine until the returned function is called. Call it
+// before starting a goroutine some other way, e.g. with a sync.WaitGroup,
+// and call done when that goroutine returns.
+func (c *GoroutineCounter) Track() (done func()) {
+	c.n.Add(1)
+	return func() { c.n.Add(-1) }
+}
+
+// Count returns the number of counted goroutines still running.
+func (c *GoroutineCounter) Count() int64 {
+	return c.n.Load()
+}
+
+// addDBPoolUsage adds the connection pool gauges of db to usage. A nil db,
+// one not yet opened, adds nothing.
+func addDBPoolUsage(usage ResourceUsage, db *sql.DB) {
+	if db == nil {
+		return
+	}
+	addDBStatsUsage(usage, db.Stats())
+}
+
+// addDBStatsUsage adds the connection pool gauges in stats to usage.
+func addDBStatsUsage(usage ResourceUsage, stats sql.DBStats) {
+	usage[ResourceDBOpenConnections] = float64(stats.OpenConnections)
+	usage[ResourceDBInUseConnections] = float64(stats.InUse)
+	usage[ResourceDBIdleConnections] = float64(stats.Idle)
+}
+
+// CollectResourceUsage asks each reporter for its usage, keyed by the
+// reporter's module name. Reporters with nothing to report are omitted.
+func CollectResourceUsage(reporters map[string]ResourceReporter) map[string]ResourceUsage {
+	out := make(map[string]ResourceUsage, len(reporters))
+	for name, r := range reporters {
+		if usage := r.ResourceUsage(); len(usage) > 0 {
+			out[name] = usage
+		}
+	}
+	return out
+}
+
+// TotalResourceUsage sums per-module usage by gauge name.
+func TotalResourceUsage(byModule map[string]ResourceUsage) ResourceUsage {
+	total := ResourceUsage{}
+	for _, usage := range byModule {
+		for name, v := range usage {
+			total[name] += v
+		}
+	}
+	return total
+}
+
+// moduleResourceMetrics exports module resource usage as the
+// module_resource gauge, labeled by module and resource. usage is called
+// on every scrape.
+type moduleResourceMetrics struct {
+	usage func() map[string]ResourceUsage
+	desc  *prometheus.Desc
+}
+
+func newModuleResourceMetrics(namespace, subsystem string, usage func() map[string]ResourceUsage) *moduleResourceMetrics {
+	return &moduleResourceMetrics{
+		usage: usage,
+		desc:  prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "module_resource"), "Resource usage self-reported by a module (see ResourceReporter)", []string{"module", "resource"}, nil),
+	}
+}
+
+// Describe sends no descriptors, making the collector unchecked since the
+// reported resources change as modules come and go.
+func (m *moduleResourceMetrics) Describe(chan<- *prometheus.Desc) {}
+
+// Collect implements prometheus.Collector.
+func (m *moduleResourceMetrics) Collect(ch chan<- prometheus.Metric) {
+	for module, usage := range m.usage() {
+		for resource, v := range usage {
+			ch <- prometheus.MustNewConstMetric(m.desc, prometheus.GaugeValue, v, module, resource)
+		}
+	}
+}
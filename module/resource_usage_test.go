@@ -0,0 +1,151 @@
+package module
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGoroutineCounter(t *testing.T) {
+	var c GoroutineCounter
+	release := make(chan struct{})
+	c.Go(func() { <-release })
+	done := c.Track()
+	if got := c.Count(); got != 2 {
+		t.Fatalf("Count = %d, want 2", got)
+	}
+	done()
+	close(release)
+	deadline := time.Now().Add(time.Second)
+	for c.Count() != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := c.Count(); got != 0 {
+		t.Errorf("Count after return = %d, want 0", got)
+	}
+}
+
+func TestTotalResourceUsage(t *testing.T) {
+	total := TotalResourceUsage(map[string]ResourceUsage{
+		"a": {ResourceGoroutines: 2, ResourceDBOpenConnections: 3},
+		"b": {ResourceGoroutines: 1},
+	})
+	if total[ResourceGoroutines] != 3 || total[ResourceDBOpenConnections] != 3 || len(total) != 2 {
+		t.Errorf("total = %v", total)
+	}
+}
+
+func TestPartitionedDatabaseResourceUsage(t *testing.T) {
+	pd := NewPartitionedDatabase("db", PartitionedDatabaseConfig{
+		Driver:       "sqlite",
+		DSN:          ":memory:",
+		PartitionKey: "tenant_id",
+		Tables:       []string{"forms"},
+		SourceTable:  "tenants",
+		AutoSync:     boolPtr(false),
+		SyncInterval: "1h",
+	})
+	if usage := pd.ResourceUsage(); usage[ResourceGoroutines] != 0 || len(usage) != 1 {
+		t.Errorf("usage before start = %v, want no pool and no goroutines", usage)
+	}
+	if err := pd.Init(NewMockApplication()); err != nil {
+		t.Fatal(err)
+	}
+	if err := pd.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	usage := pd.ResourceUsage()
+	if usage[ResourceGoroutines] != 1 {
+		t.Errorf("goroutines = %v, want the periodic sync", usage[ResourceGoroutines])
+	}
+	if _, ok := usage[ResourceDBOpenConnections]; !ok {
+		t.Errorf("usage = %v, want the connection pool", usage)
+	}
+	if err := pd.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := pd.ResourceUsage()[ResourceGoroutines]; got != 0 {
+		t.Errorf("goroutines after stop = %v, want 0", got)
+	}
+}
+
+func TestLogCollectorResourceUsage(t *testing.T) {
+	lc := NewLogCollector("logs", LogCollectorConfig{MaxEntries: 2})
+	lc.AddEntry(LogEntry{Level: "info", Module: "api", Message: "hello"})
+	usage := lc.ResourceUsage()
+	if usage[ResourceBufferedMessages] != 1 {
+		t.Errorf("buffered = %v, want 1", usage[ResourceBufferedMessages])
+	}
+	one := usage[ResourceMemoryEstimateBytes]
+	if one <= float64(len("infoapihello")) {
+		t.Errorf("memory estimate = %v, want more than the entry's strings", one)
+	}
+
+	// The ring keeps two entries; replacing one keeps the estimate bounded.
+	for range 3 {
+		lc.AddEntry(LogEntry{Level: "info", Module: "api", Message: "hello"})
+	}
+	usage = lc.ResourceUsage()
+	if usage[ResourceBufferedMessages] != 2 || usage[ResourceMemoryEstimateBytes] != 2*one {
+		t.Errorf("usage = %v, want two entries of %v bytes", usage, one)
+	}
+
+	cancel := lc.StartCollectionLoop(context.Background(), time.Hour)
+	if got := lc.ResourceUsage()[ResourceGoroutines]; got != 1 {
+		t.Errorf("goroutines = %v, want the collection loop", got)
+	}
+	cancel()
+}
+
+func TestMetricsCollectorExportsModuleResources(t *testing.T) {
+	mc := NewMetricsCollector("metrics")
+	app := NewMockApplication()
+	broker := NewInMemoryMessageBroker("broker")
+	_ = broker.Subscribe("orders", NewFunctionMessageHandler(func([]byte) error { return nil }))
+	app.RegisterModule(broker)
+	if err := mc.Init(app); err != nil {
+		t.Fatal(err)
+	}
+
+	families, err := mc.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, f := range families {
+		if f.GetName() != "workflow_module_resource" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			labels := map[string]string{}
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			if labels["module"] == "broker" && labels["resource"] == "subscriptions" && m.GetGauge().GetValue() == 1 {
+				found = true
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("workflow_module_resource{module=broker,resource=subscriptions} not exported: %v", families)
+	}
+
+	mc.SetResourceUsageFunc(func() map[string]ResourceUsage {
+		return map[string]ResourceUsage{"handler": {ResourceInFlight: 4}}
+	})
+	families, _ = mc.Gather()
+	var names []string
+	for _, f := range families {
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "module" {
+					names = append(names, l.GetValue())
+				}
+			}
+		}
+	}
+	if got := strings.Join(names, ","); got != "handler" {
+		t.Errorf("modules exported after SetResourceUsageFunc = %q, want handler", got)
+	}
+}
@@ -0,0 +1,48 @@
+package module
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/GoCodeAlone/workflow/interfaces"
+)
+
+// RoutePipelineRunner is implemented by handlers (QueryHandler,
+// CommandHandler) that can run a route pipeline without an HTTP request, for
+// example on a schedule.
+type RoutePipelineRunner interface {
+	RunRoutePipeline(ctx context.Context, routeKey string, data map[string]any) (map[string]any, error)
+}
+
+// runRoutePipeline executes a route pipeline without an HTTP request. A
+// *Pipeline runs through tracker, when set, so the run is recorded like a
+// request-triggered one.
+func runRoutePipeline(ctx context.Context, routeKey string, pipeline interfaces.PipelineRunner, tracker ExecutionTrackerProvider, data map[string]any) (map[string]any, error) {
+	if pipeline == nil {
+		return nil, fmt.Errorf("no pipeline attached to route %q", routeKey)
+	}
+	p, ok := pipeline.(*Pipeline)
+	if !ok {
+		return pipeline.Run(ctx, data)
+	}
+	if p == nil {
+		return nil, fmt.Errorf("no pipeline attached to route %q", routeKey)
+	}
+	// Run a copy: the shared pipeline's Metadata belongs to HTTP requests.
+	run := *p
+	run.Metadata = nil
+	if run.RoutePattern != "" {
+		run.Metadata = map[string]any{"_route_pattern": run.RoutePattern}
+	}
+	var pc *PipelineContext
+	var err error
+	if tracker != nil {
+		pc, err = tracker.TrackPipelineExecution(ctx, &run, data, nil)
+	} else {
+		pc, err = run.Execute(ctx, data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pc.Current, nil
+}
@@ -2,13 +2,29 @@ package module
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/observability"
 )
 
+// defaultHealthHistory is how many past reports the health endpoint returns
+// when the request does not set ?limit.
+const defaultHealthHistory = 10
+
+// RuntimeHealthReader reads stored instance health reports.
+// *observability.V1IngestStore implements it.
+type RuntimeHealthReader interface {
+	InstanceHealth(instance string, limit int, now time.Time) (*observability.InstanceHealth, error)
+}
+
 // RuntimeHandler exposes HTTP endpoints for managing runtime workflow instances.
 type RuntimeHandler struct {
 	manager *RuntimeManager
+	health  RuntimeHealthReader
 }
 
 // NewRuntimeHandler creates a new handler backed by a RuntimeManager.
@@ -16,10 +32,17 @@ func NewRuntimeHandler(manager *RuntimeManager) *RuntimeHandler {
 	return &RuntimeHandler{manager: manager}
 }
 
+// SetHealthStore sets where instance health history is read from. Without
+// one, the health endpoint serves only the manager's latest report.
+func (h *RuntimeHandler) SetHealthStore(store RuntimeHealthReader) {
+	h.health = store
+}
+
 // RegisterRoutes registers runtime management routes on the given mux.
 func (h *RuntimeHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /api/v1/admin/runtime/instances", h.handleList)
 	mux.HandleFunc("POST /api/v1/admin/runtime/instances/{id}/stop", h.handleStop)
+	mux.HandleFunc("GET /api/v1/admin/runtime/instances/{id}/health", h.handleHealth)
 }
 
 // ServeHTTP implements http.Handler for delegate dispatch.
@@ -37,6 +60,9 @@ func (h *RuntimeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	case r.Method == http.MethodPost && strings.HasSuffix(path, "/stop"):
 		id := extractID(path, "/stop")
 		h.stopInstance(w, r, id)
+	case r.Method == http.MethodGet && strings.HasSuffix(path, "/health"):
+		id := extractID(path, "/health")
+		h.instanceHealth(w, r, id)
 	default:
 		http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
 	}
@@ -53,6 +79,12 @@ func (h *RuntimeHandler) handleStop(w http.ResponseWriter, r *http.Request) {
 	h.stopInstance(w, r, id)
 }
 
+func (h *RuntimeHandler) handleHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	id := r.PathValue("id")
+	h.instanceHealth(w, r, id)
+}
+
 func (h *RuntimeHandler) listInstances(w http.ResponseWriter) {
 	instances := h.manager.ListInstances()
 	resp := map[string]any{
@@ -74,6 +106,57 @@ func (h *RuntimeHandler) stopInstance(w http.ResponseWriter, r *http.Request, id
 	_ = json.NewEncoder(w).Encode(map[string]string{"status": "stopped"})
 }
 
+// instanceHealth serves the latest health report for an instance plus a
+// short history. Reports from the health store take precedence; an instance
+// the manager knows but that has not reported yet is unknown.
+func (h *RuntimeHandler) instanceHealth(w http.ResponseWriter, r *http.Request, id string) {
+	if id == "" {
+		http.Error(w, `{"error":"missing instance id"}`, http.StatusBadRequest)
+		return
+	}
+	limit := defaultHealthHistory
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			http.Error(w, `{"error":"limit must be a positive integer"}`, http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	now := time.Now()
+
+	if h.health != nil {
+		health, err := h.health.InstanceHealth(id, limit, now)
+		switch {
+		case err == nil:
+			_ = json.NewEncoder(w).Encode(health)
+			return
+		case !errors.Is(err, observability.ErrNoInstanceHealth):
+			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	inst, ok := h.manager.GetInstance(id)
+	if !ok {
+		http.Error(w, `{"error":"workflow instance `+id+` not found"}`, http.StatusNotFound)
+		return
+	}
+	health := observability.InstanceHealth{History: []observability.InstanceHealthReport{}}
+	if inst.Health != nil {
+		health.Latest = inst.Health.AsOf(now, observability.DefaultStaleHeartbeats)
+		health.History = append(health.History, *inst.Health)
+	} else {
+		health.Latest = observability.InstanceHealthReport{
+			Instance: id,
+			Name:     inst.Name,
+			Status:   observability.InstanceUnknown,
+			Message:  "no health report yet",
+		}
+	}
+	_ = json.NewEncoder(w).Encode(health)
+}
+
 // extractID pulls the segment before the given suffix from a URL path.
 // e.g., "/some-id/stop" with suffix "/stop" returns "some-id".
 func extractID(path, suffix string) string {
@@ -0,0 +1,149 @@
+package module
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/config"
+	"github.com/GoCodeAlone/workflow/observability"
+)
+
+// RuntimeHealthSink receives a health report for each running instance on
+// every heartbeat. *observability.IngestHandler implements it, so local
+// instances are recorded the same way as remote workers' heartbeats.
+type RuntimeHealthSink interface {
+	RecordHeartbeat(ctx context.Context, instance string, at time.Time, health *observability.InstanceHealthReport)
+}
+
+// StartHealthMonitor probes every running instance's HTTP servers once per
+// interval until ctx is cancelled, keeping the latest report on the instance
+// and forwarding it to sink when one is given.
+func (rm *RuntimeManager) StartHealthMonitor(ctx context.Context, interval time.Duration, sink RuntimeHealthSink) {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	client := &http.Client{Timeout: min(interval, 5*time.Second)}
+	go func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				rm.logger.Error("panic in runtime health monitor", "panic", rec)
+			}
+		}()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				rm.probeInstances(ctx, client, interval, sink)
+			}
+		}
+	}()
+}
+
+// runtimeProbeTarget is one running instance to probe.
+type runtimeProbeTarget struct {
+	id, name string
+	cfg      *config.WorkflowConfig
+}
+
+// probeInstances probes each running instance once and records the reports.
+func (rm *RuntimeManager) probeInstances(ctx context.Context, client *http.Client, interval time.Duration, sink RuntimeHealthSink) {
+	rm.mu.RLock()
+	targets := make([]runtimeProbeTarget, 0, len(rm.instances))
+	for id, inst := range rm.instances {
+		if inst.Status == "running" {
+			targets = append(targets, runtimeProbeTarget{id: id, name: inst.Name, cfg: inst.Config})
+		}
+	}
+	rm.mu.RUnlock()
+
+	for _, t := range targets {
+		probe, modules := probeRuntimeInstance(ctx, client, t.cfg)
+		now := time.Now()
+
+		rm.mu.Lock()
+		// The instance may have stopped while it was probed; its tracker is
+		// gone and must not be recreated.
+		if inst, ok := rm.instances[t.id]; !ok || inst.Status != "running" {
+			rm.mu.Unlock()
+			continue
+		}
+		tracker, ok := rm.healthTrackers[t.id]
+		if !ok {
+			tracker = &observability.HealthTracker{}
+			rm.healthTrackers[t.id] = tracker
+		}
+		report := tracker.Report(t.id, t.name, probe, modules, interval, now)
+		rm.instances[t.id].Health = &report
+		rm.mu.Unlock()
+
+		if sink != nil {
+			sink.RecordHeartbeat(ctx, t.id, now, &report)
+		}
+	}
+}
+
+// probeRuntimeInstance probes the health path of each http.server in cfg.
+// The overall status is the worst server status; modules maps each server
+// and each health check the servers returned to its status.
+func probeRuntimeInstance(ctx context.Context, client *http.Client, cfg *config.WorkflowConfig) (observability.HealthProbe, map[string]string) {
+	if cfg == nil {
+		return observability.HealthProbe{Status: observability.InstanceHealthy, Message: "no HTTP server to probe"}, nil
+	}
+	healthPath := "/healthz"
+	for _, mod := range cfg.Modules {
+		if mod.Type != "health.checker" {
+			continue
+		}
+		if p, ok := mod.Config["healthPath"].(string); ok && p != "" {
+			healthPath = p
+		}
+	}
+
+	overall := observability.HealthProbe{Status: observability.InstanceHealthy}
+	modules := make(map[string]string)
+	probed := 0
+	for _, mod := range cfg.Modules {
+		if mod.Type != "http.server" {
+			continue
+		}
+		addr, _ := mod.Config["address"].(string)
+		host, ok := probeHost(addr)
+		if !ok {
+			continue
+		}
+		probed++
+		probe := observability.ProbeHealth(ctx, client, "http://"+host+healthPath)
+		modules[mod.Name] = probe.Status
+		for name, status := range probe.Checks {
+			modules[name] = status
+		}
+		if probe.Status != observability.InstanceHealthy && overall.Message == "" {
+			overall.Message = fmt.Sprintf("%s: %s", mod.Name, cmp.Or(probe.Message, probe.Status))
+		}
+		overall.Status = observability.WorstStatus(overall.Status, probe.Status)
+	}
+	if probed == 0 {
+		overall.Message = "no HTTP server to probe"
+	}
+	return overall, modules
+}
+
+// probeHost turns an http.server listen address into a host:port the admin
+// can reach, using loopback for unspecified hosts.
+func probeHost(addr string) (string, bool) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || port == "" || port == "0" {
+		return "", false
+	}
+	if ip := net.ParseIP(host); host == "" || ip != nil && ip.IsUnspecified() {
+		host = "127.0.0.1"
+	}
+	return net.JoinHostPort(host, port), true
+}
@@ -0,0 +1,223 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/config"
+	"github.com/GoCodeAlone/workflow/observability"
+)
+
+type recordingHealthSink struct {
+	mu      sync.Mutex
+	reports []observability.InstanceHealthReport
+}
+
+func (s *recordingHealthSink) RecordHeartbeat(_ context.Context, _ string, _ time.Time, health *observability.InstanceHealthReport) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reports = append(s.reports, *health)
+}
+
+// newHealthTestManager returns a manager with one running instance whose
+// http.server listens on srv and whose health checker serves healthPath.
+func newHealthTestManager(t *testing.T, srv *httptest.Server, healthPath string) *RuntimeManager {
+	t.Helper()
+	rm := NewRuntimeManager(nil, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	cfg := &config.WorkflowConfig{Modules: []config.ModuleConfig{
+		{Name: "server", Type: "http.server", Config: map[string]any{"address": strings.TrimPrefix(srv.URL, "http://")}},
+		{Name: "health", Type: "health.checker", Config: map[string]any{"healthPath": healthPath}},
+	}}
+	rm.instances["inst-1"] = &RuntimeInstance{ID: "inst-1", Name: "orders", Status: "running", Config: cfg}
+	rm.instances["inst-2"] = &RuntimeInstance{ID: "inst-2", Name: "stopped", Status: "stopped", Config: cfg}
+	return rm
+}
+
+func TestRuntimeManager_ProbeInstancesForwardsHealth(t *testing.T) {
+	var mu sync.Mutex
+	up := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path != "/status" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if !up {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"status":"unhealthy","checks":{"db":{"status":"unhealthy"}}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"healthy","checks":{"db":{"status":"healthy"}}}`))
+	}))
+	defer srv.Close()
+
+	rm := newHealthTestManager(t, srv, "/status")
+	sink := &recordingHealthSink{}
+	ctx := context.Background()
+
+	rm.probeInstances(ctx, srv.Client(), time.Second, sink)
+	mu.Lock()
+	up = false
+	mu.Unlock()
+	rm.probeInstances(ctx, srv.Client(), time.Second, sink)
+	rm.probeInstances(ctx, srv.Client(), time.Second, sink)
+
+	if len(sink.reports) != 3 {
+		t.Fatalf("forwarded %d reports, want 3 (stopped instances are not probed)", len(sink.reports))
+	}
+	first, last := sink.reports[0], sink.reports[2]
+	if first.Instance != "inst-1" || first.Name != "orders" || first.Status != observability.InstanceHealthy || first.Modules["db"] != observability.InstanceHealthy {
+		t.Errorf("first report = %+v", first)
+	}
+	if last.Status != observability.InstanceUnhealthy || last.ConsecutiveFailures != 2 || last.Modules["server"] != observability.InstanceUnhealthy {
+		t.Errorf("last report = %+v", last)
+	}
+
+	listed := rm.ListInstances()
+	for _, inst := range listed {
+		if inst.ID == "inst-1" && (inst.Health == nil || inst.Health.ConsecutiveFailures != 2) {
+			t.Errorf("listed health = %+v, want the latest report", inst.Health)
+		}
+	}
+}
+
+func TestRuntimeManager_StopRemovesHealthTracker(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	rm := newHealthTestManager(t, srv, "/healthz")
+	ctx := context.Background()
+	rm.probeInstances(ctx, srv.Client(), time.Second, nil)
+	if _, ok := rm.healthTrackers["inst-1"]; !ok {
+		t.Fatal("expected a tracker for the running instance")
+	}
+
+	if err := rm.StopWorkflow(ctx, "inst-1"); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(rm.healthTrackers); n != 0 {
+		t.Errorf("%d trackers left after the instance stopped, want 0", n)
+	}
+	rm.probeInstances(ctx, srv.Client(), time.Second, nil)
+	if n := len(rm.healthTrackers); n != 0 {
+		t.Errorf("probing recreated %d trackers for stopped instances", n)
+	}
+}
+
+func TestRuntimeManager_ProbeInstanceWithoutServer(t *testing.T) {
+	probe, modules := probeRuntimeInstance(context.Background(), http.DefaultClient, &config.WorkflowConfig{
+		Modules: []config.ModuleConfig{{Name: "server", Type: "http.server", Config: map[string]any{"address": ":0"}}},
+	})
+	if probe.Status != observability.InstanceHealthy || probe.Message != "no HTTP server to probe" || len(modules) != 0 {
+		t.Errorf("probe = %+v, modules = %v", probe, modules)
+	}
+}
+
+func TestProbeHost(t *testing.T) {
+	tests := map[string]string{
+		":8080":         "127.0.0.1:8080",
+		"0.0.0.0:8080":  "127.0.0.1:8080",
+		"[::]:8080":     "127.0.0.1:8080",
+		"example:9000":  "example:9000",
+		"127.0.0.1:443": "127.0.0.1:443",
+	}
+	for addr, want := range tests {
+		if got, ok := probeHost(addr); !ok || got != want {
+			t.Errorf("probeHost(%q) = %q, %v; want %q", addr, got, ok, want)
+		}
+	}
+	for _, addr := range []string{"", "8080", ":0"} {
+		if _, ok := probeHost(addr); ok {
+			t.Errorf("probeHost(%q) should not be probeable", addr)
+		}
+	}
+}
+
+type stubHealthReader struct {
+	health *observability.InstanceHealth
+	limit  int
+}
+
+func (s *stubHealthReader) InstanceHealth(_ string, limit int, _ time.Time) (*observability.InstanceHealth, error) {
+	s.limit = limit
+	if s.health == nil {
+		return nil, observability.ErrNoInstanceHealth
+	}
+	return s.health, nil
+}
+
+func getInstanceHealth(t *testing.T, h *RuntimeHandler, path string) (int, observability.InstanceHealth) {
+	t.Helper()
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	var body observability.InstanceHealth
+	if rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return rec.Code, body
+}
+
+func TestRuntimeHandler_InstanceHealth(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	rm := newHealthTestManager(t, srv, "/healthz")
+	h := NewRuntimeHandler(rm)
+
+	code, body := getInstanceHealth(t, h, "/api/v1/admin/runtime/instances/inst-1/health")
+	if code != http.StatusOK || body.Latest.Status != observability.InstanceUnknown || len(body.History) != 0 {
+		t.Errorf("before first probe: %d %+v, want unknown with no history", code, body)
+	}
+
+	// An in-memory report that missed its heartbeats is reported as unknown.
+	stale := observability.InstanceHealthReport{Instance: "inst-1", Status: observability.InstanceHealthy, IntervalMs: 10, ReportedAt: time.Now().Add(-time.Second)}
+	rm.instances["inst-1"].Health = &stale
+	_, body = getInstanceHealth(t, h, "/api/v1/admin/runtime/instances/inst-1/health")
+	if body.Latest.Status != observability.InstanceUnknown || len(body.History) != 1 || body.History[0].Status != observability.InstanceHealthy {
+		t.Errorf("stale report: %+v", body)
+	}
+
+	if code, _ := getInstanceHealth(t, h, "/api/v1/admin/runtime/instances/missing/health"); code != http.StatusNotFound {
+		t.Errorf("unknown instance: status %d, want 404", code)
+	}
+
+	reader := &stubHealthReader{health: &observability.InstanceHealth{
+		Latest:  observability.InstanceHealthReport{Instance: "inst-1", Status: observability.InstanceDegraded},
+		History: []observability.InstanceHealthReport{{Status: observability.InstanceDegraded}, {Status: observability.InstanceHealthy}},
+	}}
+	h.SetHealthStore(reader)
+	_, body = getInstanceHealth(t, h, "/api/v1/admin/runtime/instances/inst-1/health?limit=2")
+	if body.Latest.Status != observability.InstanceDegraded || len(body.History) != 2 || reader.limit != 2 {
+		t.Errorf("stored health: %+v (limit %d)", body, reader.limit)
+	}
+	if code, _ := getInstanceHealth(t, h, "/api/v1/admin/runtime/instances/inst-1/health?limit=x"); code != http.StatusBadRequest {
+		t.Errorf("bad limit: status %d, want 400", code)
+	}
+}
+
+func TestRuntimeHandler_ServeHTTPHealth(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	h := NewRuntimeHandler(newHealthTestManager(t, srv, "/healthz"))
+	h.SetHealthStore(&stubHealthReader{})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/runtime/instances/inst-1/health", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"status":"unknown"`) {
+		t.Errorf("ServeHTTP health = %d %s", rec.Code, rec.Body.String())
+	}
+}
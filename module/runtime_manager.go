@@ -2,6 +2,7 @@ package module
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -10,6 +11,7 @@ import (
 	"time"
 
 	"github.com/GoCodeAlone/workflow/config"
+	"github.com/GoCodeAlone/workflow/observability"
 	"github.com/google/uuid"
 )
 
@@ -23,7 +25,9 @@ type RuntimeInstance struct {
 	StartedAt  time.Time      `json:"started_at"`
 	Error      string         `json:"error,omitempty"`
 	Ports      map[string]int `json:"ports,omitempty"`
-	Config     *config.WorkflowConfig
+	// Health is the latest report from the health monitor, if it is running.
+	Health *observability.InstanceHealthReport `json:"health,omitempty"`
+	Config *config.WorkflowConfig
 
 	cancel context.CancelFunc
 }
@@ -43,19 +47,43 @@ type RuntimeManager struct {
 	builder       RuntimeEngineBuilder
 	logger        *slog.Logger
 	portAllocator *PortAllocator
+
+	// healthTrackers count consecutive failed probes per running instance.
+	// An instance's tracker is removed when it stops.
+	healthTrackers map[string]*observability.HealthTracker
+
+	// lifecycle serializes launch, stop and restart of each workflow ID so
+	// concurrent requests (e.g. bulk operations) cannot interleave them.
+	lifecycle map[string]*sync.Mutex
 }
 
 // NewRuntimeManager creates a new runtime manager.
 func NewRuntimeManager(store *V1Store, builder RuntimeEngineBuilder, logger *slog.Logger) *RuntimeManager {
 	return &RuntimeManager{
-		instances: make(map[string]*RuntimeInstance),
-		stopFuncs: make(map[string]func(context.Context) error),
-		store:     store,
-		builder:   builder,
-		logger:    logger,
+		instances:      make(map[string]*RuntimeInstance),
+		stopFuncs:      make(map[string]func(context.Context) error),
+		store:          store,
+		builder:        builder,
+		logger:         logger,
+		healthTrackers: make(map[string]*observability.HealthTracker),
+		lifecycle:      make(map[string]*sync.Mutex),
 	}
 }
 
+// lockWorkflow acquires the lifecycle lock of a workflow ID and returns the
+// function that releases it.
+func (rm *RuntimeManager) lockWorkflow(id string) func() {
+	rm.mu.Lock()
+	l, ok := rm.lifecycle[id]
+	if !ok {
+		l = &sync.Mutex{}
+		rm.lifecycle[id] = l
+	}
+	rm.mu.Unlock()
+	l.Lock()
+	return l.Unlock
+}
+
 // SetPortAllocator configures the port allocator for automatic port assignment.
 func (rm *RuntimeManager) SetPortAllocator(pa *PortAllocator) {
 	rm.portAllocator = pa
@@ -201,6 +229,7 @@ func (rm *RuntimeManager) loadWorkflow(ctx context.Context, configPath, workDir
 		rm.mu.Lock()
 		if inst, ok := rm.instances[id]; ok {
 			inst.Status = "stopped"
+			delete(rm.healthTrackers, id)
 		}
 		rm.mu.Unlock()
 	}()
@@ -278,7 +307,26 @@ func (rm *RuntimeManager) LaunchFromYAML(ctx context.Context, id, name, yamlCont
 
 // LaunchFromWorkspace creates and starts a workflow engine from a YAML config string,
 // optionally setting the workspace directory for relative path resolution.
+// It fails if the workflow is already starting or running.
 func (rm *RuntimeManager) LaunchFromWorkspace(ctx context.Context, id, name, yamlContent, workspaceDir string) error {
+	defer rm.lockWorkflow(id)()
+	return rm.launch(id, name, yamlContent, workspaceDir)
+}
+
+// RestartWorkflow stops a workflow's running instance, if any, and launches
+// it again from the given config. No other launch or stop of the workflow
+// can run in between.
+func (rm *RuntimeManager) RestartWorkflow(ctx context.Context, id, name, yamlContent, workspaceDir string) error {
+	defer rm.lockWorkflow(id)()
+	if err := rm.stop(ctx, id); err != nil && !errors.Is(err, errInstanceNotFound) {
+		return err
+	}
+	return rm.launch(id, name, yamlContent, workspaceDir)
+}
+
+// launch starts a workflow engine. The caller holds the workflow's
+// lifecycle lock.
+func (rm *RuntimeManager) launch(id, name, yamlContent, workspaceDir string) error {
 	cfg, err := config.LoadFromString(yamlContent)
 	if err != nil {
 		return fmt.Errorf("invalid config: %w", err)
@@ -290,6 +338,9 @@ func (rm *RuntimeManager) LaunchFromWorkspace(ctx context.Context, id, name, yam
 
 	ports := rewritePorts(cfg, rm.portAllocator, name)
 
+	// Use a background context for the engine lifecycle — the caller's context
+	// (typically an HTTP request) should not cancel the long-running engine.
+	engineCtx, cancel := context.WithCancel(context.Background())
 	instance := &RuntimeInstance{
 		ID:        id,
 		Name:      name,
@@ -298,21 +349,18 @@ func (rm *RuntimeManager) LaunchFromWorkspace(ctx context.Context, id, name, yam
 		StartedAt: time.Now(),
 		Config:    cfg,
 		Ports:     ports,
+		cancel:    cancel,
 	}
 
 	rm.mu.Lock()
-	if existing, ok := rm.instances[id]; ok && existing.Status == "running" {
+	if existing, ok := rm.instances[id]; ok && (existing.Status == "running" || existing.Status == "starting") {
 		rm.mu.Unlock()
-		return fmt.Errorf("workflow %s is already running", id)
+		cancel()
+		return fmt.Errorf("workflow %s is already %s", id, existing.Status)
 	}
 	rm.instances[id] = instance
 	rm.mu.Unlock()
 
-	// Use a background context for the engine lifecycle — the caller's context
-	// (typically an HTTP request) should not cancel the long-running engine.
-	engineCtx, cancel := context.WithCancel(context.Background())
-	instance.cancel = cancel
-
 	stopFunc, buildErr := rm.builder(cfg, rm.logger)
 	if buildErr != nil {
 		cancel()
@@ -344,8 +392,10 @@ func (rm *RuntimeManager) LaunchFromWorkspace(ctx context.Context, id, name, yam
 		}()
 		<-engineCtx.Done()
 		rm.mu.Lock()
-		if inst, ok := rm.instances[id]; ok && inst.Status == "running" {
+		// A restart may already have replaced this instance.
+		if inst, ok := rm.instances[id]; ok && inst == instance && inst.Status == "running" {
 			inst.Status = "stopped"
+			delete(rm.healthTrackers, id)
 		}
 		rm.mu.Unlock()
 	}()
@@ -400,15 +450,26 @@ func (rm *RuntimeManager) LaunchFromPath(ctx context.Context, path string) (*Run
 	return &copy, nil
 }
 
+// errInstanceNotFound is returned when stopping a workflow that has no
+// instance.
+var errInstanceNotFound = errors.New("workflow instance not found")
+
 // StopWorkflow stops a specific running workflow.
 func (rm *RuntimeManager) StopWorkflow(ctx context.Context, id string) error {
+	defer rm.lockWorkflow(id)()
+	return rm.stop(ctx, id)
+}
+
+// stop stops a workflow's instance. The caller holds the workflow's
+// lifecycle lock.
+func (rm *RuntimeManager) stop(ctx context.Context, id string) error {
 	rm.mu.Lock()
 	inst, ok := rm.instances[id]
 	stopFunc := rm.stopFuncs[id]
 	rm.mu.Unlock()
 
 	if !ok {
-		return fmt.Errorf("workflow instance %s not found", id)
+		return fmt.Errorf("%w: %s", errInstanceNotFound, id)
 	}
 
 	if inst.cancel != nil {
@@ -425,6 +486,7 @@ func (rm *RuntimeManager) StopWorkflow(ctx context.Context, id string) error {
 	rm.mu.Lock()
 	inst.Status = "stopped"
 	delete(rm.stopFuncs, id)
+	delete(rm.healthTrackers, id)
 	rm.mu.Unlock()
 
 	if rm.portAllocator != nil {
@@ -455,13 +517,20 @@ func (rm *RuntimeManager) StopAll(ctx context.Context) error {
 	return lastErr
 }
 
-// ListInstances returns all workflow instances.
+// ListInstances returns all workflow instances. Health reports older than
+// observability.DefaultStaleHeartbeats intervals are returned as unknown.
 func (rm *RuntimeManager) ListInstances() []RuntimeInstance {
 	rm.mu.RLock()
 	defer rm.mu.RUnlock()
 
+	now := time.Now()
 	result := make([]RuntimeInstance, 0, len(rm.instances))
 	for _, inst := range rm.instances {
+		var health *observability.InstanceHealthReport
+		if inst.Health != nil {
+			h := inst.Health.AsOf(now, observability.DefaultStaleHeartbeats)
+			health = &h
+		}
 		result = append(result, RuntimeInstance{
 			ID:         inst.ID,
 			Name:       inst.Name,
@@ -471,6 +540,7 @@ func (rm *RuntimeManager) ListInstances() []RuntimeInstance {
 			StartedAt:  inst.StartedAt,
 			Error:      inst.Error,
 			Ports:      inst.Ports,
+			Health:     health,
 		})
 	}
 	return result
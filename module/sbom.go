@@ -0,0 +1,185 @@
+package module
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/version"
+	"github.com/google/uuid"
+	"golang.org/x/mod/modfile"
+)
+
+// SBOM document formats produced by step.sbom_generate.
+const (
+	SBOMFormatCycloneDXJSON = "cyclonedx-json"
+	SBOMFormatSPDXJSON      = "spdx-json"
+)
+
+// sbomFileExtension returns the conventional file extension of an SBOM format.
+func sbomFileExtension(format string) string {
+	if format == SBOMFormatSPDXJSON {
+		return ".spdx.json"
+	}
+	return ".cdx.json"
+}
+
+// sbomModule is a Go module listed by the built-in SBOM generator.
+type sbomModule struct {
+	path     string
+	version  string
+	indirect bool
+}
+
+func (m sbomModule) purl() string {
+	if m.version == "" {
+		return "pkg:golang/" + m.path
+	}
+	return "pkg:golang/" + m.path + "@" + m.version
+}
+
+// GenerateGoSBOM is the built-in SBOM generator: it lists the module in dir
+// and the modules its go.mod requires, with replacements applied, as an
+// SBOM in the given format. It reads go.mod only, so it needs neither the
+// module cache nor network access.
+func GenerateGoSBOM(dir, format string) ([]byte, error) {
+	path := filepath.Join(dir, "go.mod")
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path from step configuration
+	if err != nil {
+		return nil, fmt.Errorf("read go.mod: %w", err)
+	}
+	mf, err := modfile.Parse(path, data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("parse go.mod: %w", err)
+	}
+	if mf.Module == nil {
+		return nil, fmt.Errorf("%s has no module directive", path)
+	}
+
+	replaced := make(map[string]*modfile.Replace, len(mf.Replace))
+	for _, r := range mf.Replace {
+		replaced[r.Old.Path+"@"+r.Old.Version] = r
+	}
+	root := sbomModule{path: mf.Module.Mod.Path}
+	deps := make([]sbomModule, 0, len(mf.Require))
+	for _, req := range mf.Require {
+		m := sbomModule{path: req.Mod.Path, version: req.Mod.Version, indirect: req.Indirect}
+		r := replaced[req.Mod.Path+"@"+req.Mod.Version]
+		if r == nil {
+			r = replaced[req.Mod.Path+"@"]
+		}
+		// A replacement by a local directory keeps the required version.
+		if r != nil && r.New.Version != "" {
+			m.path, m.version = r.New.Path, r.New.Version
+		}
+		deps = append(deps, m)
+	}
+	sort.Slice(deps, func(i, j int) bool { return deps[i].purl() < deps[j].purl() })
+
+	switch format {
+	case SBOMFormatSPDXJSON:
+		return json.MarshalIndent(spdxDocument(root, deps), "", "  ")
+	case SBOMFormatCycloneDXJSON:
+		return json.MarshalIndent(cycloneDXDocument(root, deps), "", "  ")
+	default:
+		return nil, fmt.Errorf("unsupported SBOM format %q", format)
+	}
+}
+
+func cycloneDXDocument(root sbomModule, deps []sbomModule) map[string]any {
+	components := make([]map[string]any, 0, len(deps))
+	direct := []string{}
+	for _, d := range deps {
+		c := map[string]any{
+			"type":     "library",
+			"bom-ref":  d.purl(),
+			"name":     d.path,
+			"version":  d.version,
+			"purl":     d.purl(),
+			"scope":    "required",
+			"evidence": map[string]any{"identity": map[string]any{"field": "purl", "methods": []map[string]any{{"technique": "manifest-analysis", "confidence": 1}}}},
+		}
+		if !d.indirect {
+			direct = append(direct, d.purl())
+		}
+		components = append(components, c)
+	}
+	return map[string]any{
+		"bomFormat":    "CycloneDX",
+		"specVersion":  "1.5",
+		"serialNumber": "urn:uuid:" + uuid.NewString(),
+		"version":      1,
+		"metadata": map[string]any{
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+			"tools": map[string]any{"components": []map[string]any{
+				{"type": "application", "name": "workflow", "version": version.Get().Version},
+			}},
+			"component": map[string]any{"type": "application", "bom-ref": root.purl(), "name": root.path, "purl": root.purl()},
+		},
+		"components":   components,
+		"dependencies": []map[string]any{{"ref": root.purl(), "dependsOn": direct}},
+	}
+}
+
+func spdxDocument(root sbomModule, deps []sbomModule) map[string]any {
+	pkg := func(id string, m sbomModule) map[string]any {
+		p := map[string]any{
+			"SPDXID":           id,
+			"name":             m.path,
+			"downloadLocation": "NOASSERTION",
+			"filesAnalyzed":    false,
+			"externalRefs": []map[string]any{
+				{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": m.purl()},
+			},
+		}
+		if m.version != "" {
+			p["versionInfo"] = m.version
+		}
+		return p
+	}
+	packages := []map[string]any{pkg("SPDXRef-Package-root", root)}
+	relationships := []map[string]any{
+		{"spdxElementId": "SPDXRef-DOCUMENT", "relationshipType": "DESCRIBES", "relatedSpdxElement": "SPDXRef-Package-root"},
+	}
+	for i, d := range deps {
+		id := fmt.Sprintf("SPDXRef-Package-%d", i+1)
+		packages = append(packages, pkg(id, d))
+		relationships = append(relationships, map[string]any{
+			"spdxElementId": "SPDXRef-Package-root", "relationshipType": "DEPENDS_ON", "relatedSpdxElement": id,
+		})
+	}
+	return map[string]any{
+		"spdxVersion":       "SPDX-2.3",
+		"dataLicense":       "CC0-1.0",
+		"SPDXID":            "SPDXRef-DOCUMENT",
+		"name":              root.path,
+		"documentNamespace": "https://spdx.org/spdxdocs/" + strings.ReplaceAll(root.path, "/", "-") + "-" + uuid.NewString(),
+		"creationInfo": map[string]any{
+			"created":  time.Now().UTC().Format(time.RFC3339),
+			"creators": []string{"Tool: workflow-" + version.Get().Version},
+		},
+		"packages":      packages,
+		"relationships": relationships,
+	}
+}
+
+// SBOMComponentCount returns the number of components of a CycloneDX JSON
+// SBOM or packages of an SPDX JSON SBOM, to report the size of an SBOM
+// whichever generator produced it.
+func SBOMComponentCount(data []byte, format string) (int, error) {
+	var doc struct {
+		Components []json.RawMessage `json:"components"`
+		Packages   []json.RawMessage `json:"packages"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return 0, fmt.Errorf("parse %s SBOM: %w", format, err)
+	}
+	if format == SBOMFormatSPDXJSON {
+		return len(doc.Packages), nil
+	}
+	return len(doc.Components), nil
+}
@@ -0,0 +1,153 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/GoCodeAlone/workflow/artifact"
+)
+
+const sbomTestGoMod = `module example.com/app
+
+go 1.22
+
+require (
+	github.com/google/uuid v1.6.0
+	golang.org/x/mod v0.37.0 // indirect
+	example.com/forked v1.0.0
+	example.com/local v0.1.0
+)
+
+replace example.com/forked => github.com/someone/forked v1.0.1
+
+replace example.com/local => ../local
+`
+
+func writeGoMod(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(sbomTestGoMod), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestGenerateGoSBOM(t *testing.T) {
+	dir := writeGoMod(t)
+
+	data, err := GenerateGoSBOM(dir, SBOMFormatCycloneDXJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bom struct {
+		BOMFormat string `json:"bomFormat"`
+		Metadata  struct {
+			Component struct{ Name string } `json:"component"`
+		} `json:"metadata"`
+		Components []struct {
+			Name, Version, Purl string
+		} `json:"components"`
+		Dependencies []struct {
+			DependsOn []string `json:"dependsOn"`
+		} `json:"dependencies"`
+	}
+	if err := json.Unmarshal(data, &bom); err != nil {
+		t.Fatal(err)
+	}
+	if bom.BOMFormat != "CycloneDX" || bom.Metadata.Component.Name != "example.com/app" {
+		t.Fatalf("unexpected BOM header %+v", bom)
+	}
+	purls := make([]string, 0, len(bom.Components))
+	for _, c := range bom.Components {
+		purls = append(purls, c.Purl)
+	}
+	want := []string{
+		"pkg:golang/example.com/local@v0.1.0",
+		"pkg:golang/github.com/google/uuid@v1.6.0",
+		"pkg:golang/github.com/someone/forked@v1.0.1",
+		"pkg:golang/golang.org/x/mod@v0.37.0",
+	}
+	if strings.Join(purls, " ") != strings.Join(want, " ") {
+		t.Errorf("expected components %v, got %v", want, purls)
+	}
+	// Only direct requirements are dependencies of the root module.
+	if len(bom.Dependencies) != 1 || len(bom.Dependencies[0].DependsOn) != 3 {
+		t.Errorf("unexpected dependencies %+v", bom.Dependencies)
+	}
+
+	data, err = GenerateGoSBOM(dir, SBOMFormatSPDXJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := SBOMComponentCount(data, SBOMFormatSPDXJSON); err != nil || n != 5 {
+		t.Errorf("expected the root and 4 SPDX packages, got %d, %v", n, err)
+	}
+
+	if _, err := GenerateGoSBOM(t.TempDir(), SBOMFormatCycloneDXJSON); err == nil {
+		t.Error("expected a directory without go.mod to fail")
+	}
+}
+
+func TestSBOMGenerateStep(t *testing.T) {
+	dir := writeGoMod(t)
+	store := artifact.NewLocalStore(t.TempDir())
+	pc := NewPipelineContext(map[string]any{"src": dir}, map[string]any{"artifact_store": store, "execution_id": "exec-1"})
+
+	step, err := NewSBOMGenerateStepFactory()("sbom", map[string]any{
+		"source_path": "{{ .src }}",
+		"generator":   "builtin",
+		"output_path": filepath.Join(t.TempDir(), "out", "app.cdx.json"),
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := step.Execute(context.Background(), pc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := result.Output
+	if out["components"] != 4 || out["artifact"] != "sbom.cdx.json" || out["target"] != dir {
+		t.Fatalf("unexpected output %v", out)
+	}
+	artifacts, _ := store.List(context.Background(), "exec-1")
+	if len(artifacts) != 1 || artifacts[0].Checksum != out["sha256"] {
+		t.Errorf("expected the SBOM stored with its checksum, got %+v", artifacts)
+	}
+
+	// syft writes the SBOM itself; the fake writes an SPDX document to the
+	// path after "-o spdx-json=".
+	var args []string
+	s, err := NewSBOMGenerateStepFactory()("sbom", map[string]any{"image": "app@sha256:abc", "format": "spdx-json", "artifact_key": ""}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.(*SBOMGenerateStep).execCommand = func(ctx context.Context, name string, a ...string) *exec.Cmd {
+		args = append([]string{name}, a...)
+		path := strings.TrimPrefix(a[len(a)-1], "spdx-json=")
+		return exec.CommandContext(ctx, "sh", "-c", `echo '{"packages":[{},{}]}' > "$0"`, path)
+	}
+	result, err = s.Execute(context.Background(), pc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(args[:3], " ") != "syft scan app@sha256:abc" || result.Output["components"] != 2 || result.Output["artifact"] != nil {
+		t.Errorf("unexpected syft run %v with output %v", args, result.Output)
+	}
+	_ = os.Remove(result.Output["path"].(string))
+
+	for _, cfg := range []map[string]any{
+		{},
+		{"source_path": ".", "image": "app"},
+		{"image": "app", "generator": "builtin"},
+		{"source_path": ".", "format": "xml"},
+	} {
+		if _, err := NewSBOMGenerateStepFactory()("sbom", cfg, nil); err == nil {
+			t.Errorf("expected config %v to be rejected", cfg)
+		}
+	}
+}
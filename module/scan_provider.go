@@ -25,6 +25,9 @@ type ContainerScanOpts struct {
 	SeverityThreshold string
 	IgnoreUnfixed     bool
 	OutputFormat      string
+	// SBOMPath, when set, is an SBOM of TargetImage (e.g. written by
+	// step.sbom_generate) to scan instead of pulling and unpacking the image.
+	SBOMPath string
 }
 
 // DepsScanOpts configures a dependency vulnerability scan.
@@ -33,6 +36,9 @@ type DepsScanOpts struct {
 	SourcePath     string
 	FailOnSeverity string
 	OutputFormat   string
+	// SBOMPath, when set, is an SBOM of SourcePath to scan instead of
+	// walking the source tree for dependency manifests.
+	SBOMPath string
 }
 
 // SeverityRank returns a numeric rank for severity comparison (higher = more severe).
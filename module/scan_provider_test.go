@@ -313,6 +313,43 @@ func TestScanDepsStep_GateFails(t *testing.T) {
 	}
 }
 
+// TestScanSteps_SBOM verifies that an SBOM from a previous step is passed to
+// the scanner in place of the source tree or image.
+func TestScanSteps_SBOM(t *testing.T) {
+	mock := &mockSecurityScanner{
+		DepsResult:      &ScanResult{Scanner: "grype"},
+		ContainerResult: &ScanResult{Scanner: "trivy"},
+	}
+	app := newScanApp(mock)
+	pc := NewPipelineContext(nil, nil)
+	pc.MergeStepOutput("sbom", map[string]any{"path": "/tmp/app.cdx.json"})
+
+	deps, err := NewScanDepsStepFactory()("deps-step", map[string]any{"sbom": "{{ .steps.sbom.path }}"}, app)
+	if err != nil {
+		t.Fatalf("factory error: %v", err)
+	}
+	if _, err := deps.Execute(context.Background(), pc); err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+	if mock.DepsCallOpts.SBOMPath != "/tmp/app.cdx.json" {
+		t.Errorf("expected sbom path passed to ScanDeps, got %q", mock.DepsCallOpts.SBOMPath)
+	}
+
+	container, err := NewScanContainerStepFactory()("container-step", map[string]any{
+		"target_image": "app:latest",
+		"sbom":         "{{ .steps.sbom.path }}",
+	}, app)
+	if err != nil {
+		t.Fatalf("factory error: %v", err)
+	}
+	if _, err := container.Execute(context.Background(), pc); err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+	if mock.ContainerCallOpts.SBOMPath != "/tmp/app.cdx.json" {
+		t.Errorf("expected sbom path passed to ScanContainer, got %q", mock.ContainerCallOpts.SBOMPath)
+	}
+}
+
 // TestSeverityRank verifies severity ranking.
 func TestSeverityRank(t *testing.T) {
 	cases := []struct {
@@ -0,0 +1,52 @@
+package module
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// DefaultScheduleJobsPreview is how many upcoming run times the scheduler
+// jobs endpoint reports per job unless the request asks for another count.
+const DefaultScheduleJobsPreview = 5
+
+// maxScheduleJobsPreview bounds the ?next= query parameter.
+const maxScheduleJobsPreview = 100
+
+// ScheduleJobsHandler serves the scheduled jobs of the running engine's
+// schedule triggers with their next run times and last run outcome.
+type ScheduleJobsHandler struct {
+	triggers func() []*ScheduleTrigger
+}
+
+// NewScheduleJobsHandler creates a ScheduleJobsHandler. triggers is called on
+// every request so the handler follows engine reloads.
+func NewScheduleJobsHandler(triggers func() []*ScheduleTrigger) *ScheduleJobsHandler {
+	return &ScheduleJobsHandler{triggers: triggers}
+}
+
+// RegisterRoutes registers the scheduler jobs route on the given mux.
+func (h *ScheduleJobsHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/admin/scheduler/jobs", h.handleList)
+}
+
+func (h *ScheduleJobsHandler) handleList(w http.ResponseWriter, r *http.Request) {
+	n := DefaultScheduleJobsPreview
+	if v := r.URL.Query().Get("next"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1 || parsed > maxScheduleJobsPreview {
+			writeJSONError(w, http.StatusBadRequest, "next must be an integer between 1 and "+strconv.Itoa(maxScheduleJobsPreview))
+			return
+		}
+		n = parsed
+	}
+
+	jobs := make([]ScheduleJobStatus, 0)
+	for _, t := range h.triggers() {
+		jobs = append(jobs, t.JobStatuses(n)...)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	writeJSON(w, http.StatusOK, map[string]any{
+		"jobs":  jobs,
+		"total": len(jobs),
+	})
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"maps"
+	"sync"
 	"time"
 
 	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow/scheduler/cron"
 )
 
 const (
@@ -21,19 +23,46 @@ type ScheduleTriggerConfig struct {
 
 // ScheduleTriggerJob represents a single scheduled job configuration
 type ScheduleTriggerJob struct {
-	Cron     string         `json:"cron" yaml:"cron"`
-	Workflow string         `json:"workflow" yaml:"workflow"`
-	Action   string         `json:"action" yaml:"action"`
+	Cron     string `json:"cron" yaml:"cron"`
+	Workflow string `json:"workflow" yaml:"workflow"`
+	Action   string `json:"action" yaml:"action"`
+	// Timezone is the IANA zone the cron fields are evaluated in; empty
+	// means UTC. Across DST changes, times that do not exist are skipped and
+	// times that occur twice run once.
+	Timezone string         `json:"timezone,omitempty" yaml:"timezone,omitempty"`
 	Params   map[string]any `json:"params,omitempty" yaml:"params,omitempty"`
 }
 
+// ScheduleJobRun describes one execution of a scheduled job.
+type ScheduleJobRun struct {
+	StartedAt time.Time `json:"startedAt"`
+	Duration  string    `json:"duration"`
+	Status    string    `json:"status"` // "success" or "failed"
+	Error     string    `json:"error,omitempty"`
+}
+
+// ScheduleJobStatus reports a scheduled job with its upcoming run times and
+// the outcome of its last run.
+type ScheduleJobStatus struct {
+	Workflow string          `json:"workflow"`
+	Action   string          `json:"action,omitempty"`
+	Cron     string          `json:"cron"`
+	Timezone string          `json:"timezone,omitempty"`
+	NextRuns []time.Time     `json:"nextRuns"`
+	LastRun  *ScheduleJobRun `json:"lastRun,omitempty"`
+}
+
 // ScheduleTrigger implements a trigger that starts workflows based on a schedule
 type ScheduleTrigger struct {
 	name      string
 	namespace ModuleNamespaceProvider
 	jobs      []ScheduleTriggerJob
+	schedules []*cron.Schedule // parsed jobs[i].Cron in jobs[i].Timezone
 	engine    WorkflowEngine
 	scheduler Scheduler
+
+	mu       sync.Mutex
+	lastRuns map[int]*ScheduleJobRun
 }
 
 // NewScheduleTrigger creates a new schedule trigger
@@ -52,6 +81,7 @@ func NewScheduleTriggerWithNamespace(namespace ModuleNamespaceProvider) *Schedul
 		name:      namespace.FormatName(ScheduleTriggerName),
 		namespace: namespace,
 		jobs:      make([]ScheduleTriggerJob, 0),
+		lastRuns:  make(map[int]*ScheduleJobRun),
 	}
 }
 
@@ -82,13 +112,22 @@ func (t *ScheduleTrigger) Start(ctx context.Context) error {
 		return fmt.Errorf("workflow engine not configured for schedule trigger")
 	}
 
-	// Register all jobs with the scheduler
-	for _, job := range t.jobs {
+	// Register all jobs with the scheduler. Schedulers that support per-job
+	// schedules run each job on its own cron expression; others run every
+	// job on their scheduler-wide expression.
+	cronScheduler, perJob := t.scheduler.(CronJobScheduler)
+	for i, job := range t.jobs {
 		// Create a job that will trigger the workflow
-		scheduledJob := t.createJob(job)
+		scheduledJob := t.createJob(i)
 
 		// Schedule the job
-		if err := t.scheduler.Schedule(scheduledJob); err != nil {
+		var err error
+		if perJob {
+			err = cronScheduler.ScheduleCron(t.schedules[i], scheduledJob)
+		} else {
+			err = t.scheduler.Schedule(scheduledJob)
+		}
+		if err != nil {
 			return fmt.Errorf("failed to schedule job for workflow '%s': %w", job.Workflow, err)
 		}
 	}
@@ -117,28 +156,28 @@ func (t *ScheduleTrigger) Configure(app modular.Application, triggerConfig any)
 	}
 
 	// Find the scheduler — try well-known names first, then scan all services
-	var scheduler Scheduler
+	var sched Scheduler
 	schedulerNames := []string{"cronScheduler", "scheduler"}
 
 	for _, name := range schedulerNames {
 		var svc any
 		if err := app.GetService(name, &svc); err == nil && svc != nil {
 			if s, ok := svc.(Scheduler); ok {
-				scheduler = s
+				sched = s
 				break
 			}
 		}
 	}
-	if scheduler == nil {
+	if sched == nil {
 		for _, svc := range app.SvcRegistry() {
 			if s, ok := svc.(Scheduler); ok {
-				scheduler = s
+				sched = s
 				break
 			}
 		}
 	}
 
-	if scheduler == nil {
+	if sched == nil {
 		return fmt.Errorf("scheduler not found")
 	}
 
@@ -169,7 +208,7 @@ func (t *ScheduleTrigger) Configure(app modular.Application, triggerConfig any)
 	}
 
 	// Store scheduler and engine references
-	t.scheduler = scheduler
+	t.scheduler = sched
 	t.engine = engine
 
 	// Parse jobs
@@ -179,42 +218,94 @@ func (t *ScheduleTrigger) Configure(app modular.Application, triggerConfig any)
 			return fmt.Errorf("invalid job configuration at index %d", i)
 		}
 
-		cron, _ := jobMap["cron"].(string)
+		expr, _ := jobMap["cron"].(string)
 		workflow, _ := jobMap["workflow"].(string)
 		action, _ := jobMap["action"].(string)
+		timezone, _ := jobMap["timezone"].(string)
 
-		if cron == "" || workflow == "" || action == "" {
+		if expr == "" || workflow == "" || action == "" {
 			return fmt.Errorf("incomplete job configuration at index %d: cron, workflow and action are required", i)
 		}
 
+		schedule, err := cron.ParseInLocation(expr, timezone)
+		if err != nil {
+			return fmt.Errorf("invalid schedule for job at index %d: %w", i, err)
+		}
+
 		// Get optional params
 		params, _ := jobMap["params"].(map[string]any)
 
 		// Add the job
 		t.jobs = append(t.jobs, ScheduleTriggerJob{
-			Cron:     cron,
+			Cron:     expr,
 			Workflow: workflow,
 			Action:   action,
+			Timezone: timezone,
 			Params:   params,
 		})
+		t.schedules = append(t.schedules, schedule)
 	}
 
 	return nil
 }
 
-// createJob creates a job for a specific scheduled trigger
-func (t *ScheduleTrigger) createJob(job ScheduleTriggerJob) Job {
+// JobStatuses returns every configured job with its next n run times and
+// the outcome of its last run.
+func (t *ScheduleTrigger) JobStatuses(n int) []ScheduleJobStatus {
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	statuses := make([]ScheduleJobStatus, 0, len(t.jobs))
+	for i, job := range t.jobs {
+		status := ScheduleJobStatus{
+			Workflow: job.Workflow,
+			Action:   job.Action,
+			Cron:     job.Cron,
+			Timezone: job.Timezone,
+			NextRuns: t.schedules[i].NextN(now, n),
+		}
+		if run := t.lastRuns[i]; run != nil {
+			r := *run
+			status.LastRun = &r
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// createJob creates a job for the i-th scheduled trigger job
+func (t *ScheduleTrigger) createJob(i int) Job {
+	job := t.jobs[i]
 	return NewFunctionJob(func(ctx context.Context) error {
+		start := time.Now()
+
 		// Create the data to pass to the workflow
 		data := make(map[string]any)
 
 		// Add current timestamp
-		data["trigger_time"] = time.Now().Format(time.RFC3339)
+		data["trigger_time"] = start.Format(time.RFC3339)
 
 		// Add any static params from the job configuration
 		maps.Copy(data, job.Params)
 
 		// Call the workflow engine to trigger the workflow
-		return t.engine.TriggerWorkflow(ctx, job.Workflow, job.Action, data)
+		err := t.engine.TriggerWorkflow(ctx, job.Workflow, job.Action, data)
+		t.recordRun(i, start, err)
+		return err
 	})
 }
+
+func (t *ScheduleTrigger) recordRun(i int, start time.Time, err error) {
+	run := &ScheduleJobRun{
+		StartedAt: start.UTC(),
+		Duration:  time.Since(start).String(),
+		Status:    "success",
+	}
+	if err != nil {
+		run.Status = "failed"
+		run.Error = err.Error()
+	}
+	t.mu.Lock()
+	t.lastRuns[i] = run
+	t.mu.Unlock()
+}
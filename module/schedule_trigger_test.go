@@ -2,7 +2,15 @@ package module
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/scheduler/cron"
 )
 
 // TestScheduleTrigger tests the Schedule trigger functionality
@@ -140,3 +148,121 @@ func TestScheduleTrigger(t *testing.T) {
 		t.Fatalf("Failed to stop trigger: %v", err)
 	}
 }
+
+// cronJobMockScheduler records per-job schedules handed to ScheduleCron.
+type cronJobMockScheduler struct {
+	MockScheduler
+	schedules []*cron.Schedule
+	jobs      []Job
+}
+
+func (s *cronJobMockScheduler) ScheduleCron(schedule *cron.Schedule, job Job) error {
+	s.schedules = append(s.schedules, schedule)
+	s.jobs = append(s.jobs, job)
+	return nil
+}
+
+type failingWorkflowEngine struct{}
+
+func (failingWorkflowEngine) TriggerWorkflow(context.Context, string, string, map[string]any) error {
+	return errors.New("pipeline failed")
+}
+
+func newConfiguredScheduleTrigger(t *testing.T, sched Scheduler, engine WorkflowEngine, jobs ...any) (*ScheduleTrigger, error) {
+	t.Helper()
+	app := NewMockApplication()
+	if err := app.RegisterService("cronScheduler", sched); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.RegisterService("workflowEngine", engine); err != nil {
+		t.Fatal(err)
+	}
+	trigger := NewScheduleTrigger()
+	return trigger, trigger.Configure(app, map[string]any{"jobs": jobs})
+}
+
+func TestScheduleTrigger_InvalidSchedule(t *testing.T) {
+	tests := []struct {
+		job  map[string]any
+		want string
+	}{
+		{map[string]any{"cron": "0 24 * * *", "workflow": "w", "action": "a"}, `job at index 0: field 2 (hour) "24"`},
+		{map[string]any{"cron": "@daily", "timezone": "Nowhere/City", "workflow": "w", "action": "a"}, `timezone "Nowhere/City"`},
+	}
+	for _, tc := range tests {
+		_, err := newConfiguredScheduleTrigger(t, NewMockScheduler(), NewMockWorkflowEngine(), tc.job)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("Configure(%v) error = %v, want it to contain %q", tc.job, err, tc.want)
+		}
+	}
+}
+
+func TestScheduleTrigger_PerJobSchedules(t *testing.T) {
+	sched := &cronJobMockScheduler{}
+	trigger, err := newConfiguredScheduleTrigger(t, sched, failingWorkflowEngine{},
+		map[string]any{"cron": "0 9 * * *", "timezone": "Asia/Tokyo", "workflow": "report", "action": "run"},
+		map[string]any{"cron": "@every 10m", "workflow": "poll", "action": "run"},
+	)
+	if err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	if err := trigger.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if len(sched.schedules) != 2 || len(sched.scheduledJobs) != 0 {
+		t.Fatalf("expected 2 per-job schedules and no scheduler-wide jobs, got %d and %d", len(sched.schedules), len(sched.scheduledJobs))
+	}
+	if loc := sched.schedules[0].Location().String(); loc != "Asia/Tokyo" {
+		t.Errorf("first job location = %s, want Asia/Tokyo", loc)
+	}
+
+	if err := sched.jobs[0].Execute(context.Background()); err == nil {
+		t.Fatal("expected the job to report the workflow error")
+	}
+	statuses := trigger.JobStatuses(3)
+	if len(statuses) != 2 || len(statuses[0].NextRuns) != 3 {
+		t.Fatalf("statuses = %+v", statuses)
+	}
+	if run := statuses[0].LastRun; run == nil || run.Status != "failed" || run.Error != "pipeline failed" {
+		t.Errorf("last run = %+v, want a failed run", run)
+	}
+	if statuses[1].LastRun != nil {
+		t.Errorf("job that never ran has last run %+v", statuses[1].LastRun)
+	}
+	if next := statuses[0].NextRuns[0].In(time.UTC); next.Hour() != 0 || next.Minute() != 0 {
+		t.Errorf("09:00 Asia/Tokyo should be 00:00 UTC, got %v", next)
+	}
+}
+
+func TestScheduleJobsHandler(t *testing.T) {
+	trigger, err := newConfiguredScheduleTrigger(t, NewMockScheduler(), NewMockWorkflowEngine(),
+		map[string]any{"cron": "@hourly", "workflow": "pipeline:sync", "action": "execute"},
+	)
+	if err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	mux := http.NewServeMux()
+	NewScheduleJobsHandler(func() []*ScheduleTrigger { return []*ScheduleTrigger{trigger} }).RegisterRoutes(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/scheduler/jobs", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Jobs  []ScheduleJobStatus `json:"jobs"`
+		Total int                 `json:"total"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Total != 1 || len(resp.Jobs[0].NextRuns) != DefaultScheduleJobsPreview || resp.Jobs[0].Workflow != "pipeline:sync" {
+		t.Fatalf("response = %+v", resp)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/scheduler/jobs?next=0", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("next=0: status %d", rec.Code)
+	}
+}
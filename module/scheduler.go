@@ -9,6 +9,7 @@ import (
 
 	"github.com/GoCodeAlone/modular"
 	"github.com/GoCodeAlone/workflow/scheduler"
+	"github.com/GoCodeAlone/workflow/scheduler/cron"
 )
 
 // Job represents a scheduled job
@@ -23,15 +24,32 @@ type Scheduler interface {
 	Stop(ctx context.Context) error
 }
 
+// CronJobScheduler is implemented by schedulers that can run each job on its
+// own cron schedule rather than on a single scheduler-wide expression. The
+// schedule trigger uses it when available so per-job cron expressions and
+// timezones take effect.
+type CronJobScheduler interface {
+	ScheduleCron(schedule *cron.Schedule, job Job) error
+}
+
 // CronScheduler implements a cron-based scheduler
 type CronScheduler struct {
 	name           string
 	cronExpression string
 	jobs           []Job
+	cronJobs       []cronJob
 	jobsMu         sync.Mutex
 	running        atomic.Bool
 	stopCh         chan struct{}
-	stopMu         sync.Mutex // protects stopCh lifecycle
+	runCtx         context.Context // context passed to Start; guarded by stopMu
+	stopMu         sync.Mutex      // protects stopCh lifecycle
+	logger         modular.Logger
+}
+
+// cronJob is a job with its own schedule, added through ScheduleCron.
+type cronJob struct {
+	schedule *cron.Schedule
+	job      Job
 }
 
 // NewCronScheduler creates a new cron scheduler
@@ -41,6 +59,7 @@ func NewCronScheduler(name string, cronExpression string) *CronScheduler {
 		cronExpression: cronExpression,
 		jobs:           make([]Job, 0),
 		stopCh:         make(chan struct{}),
+		logger:         &noopLogger{},
 	}
 }
 
@@ -56,6 +75,7 @@ func (s *CronScheduler) Name() string {
 
 // Init initializes the scheduler
 func (s *CronScheduler) Init(app modular.Application) error {
+	s.logger = app.Logger()
 	// Register ourselves in the service registry
 	return app.RegisterService(s.name, s)
 }
@@ -66,16 +86,32 @@ func (s *CronScheduler) Start(ctx context.Context) error {
 		return nil
 	}
 
-	if err := scheduler.ValidateCron(s.cronExpression); err != nil {
-		return fmt.Errorf("invalid cron expression %q: %w", s.cronExpression, err)
+	s.stopMu.Lock()
+	// The scheduler-wide expression is only required when jobs rely on it;
+	// a scheduler used solely through ScheduleCron may leave it empty.
+	s.jobsMu.Lock()
+	legacy := s.cronExpression != "" || len(s.jobs) > 0
+	cronJobs := append([]cronJob(nil), s.cronJobs...)
+	s.jobsMu.Unlock()
+	if legacy {
+		if err := scheduler.ValidateCron(s.cronExpression); err != nil {
+			s.stopMu.Unlock()
+			return fmt.Errorf("invalid cron expression %q: %w", s.cronExpression, err)
+		}
 	}
 
-	s.stopMu.Lock()
 	s.resetStopCh()
 	stopCh := s.stopCh
+	s.runCtx = ctx
+	s.running.Store(true)
+	for _, cj := range cronJobs {
+		go s.runCronJob(ctx, stopCh, cj)
+	}
 	s.stopMu.Unlock()
 
-	s.running.Store(true)
+	if !legacy {
+		return nil
+	}
 
 	go func() {
 		for {
@@ -92,16 +128,7 @@ func (s *CronScheduler) Start(ctx context.Context) error {
 				copy(jobs, s.jobs)
 				s.jobsMu.Unlock()
 				for _, job := range jobs {
-					go func(j Job) {
-						defer func() {
-							if rec := recover(); rec != nil {
-								fmt.Printf("panic in cron job execution: %v\n", rec)
-							}
-						}()
-						if err := j.Execute(ctx); err != nil {
-							fmt.Printf("Job execution failed: %v\n", err)
-						}
-					}(job)
+					go s.executeCronJob(ctx, job)
 				}
 			case <-stopCh:
 				timer.Stop()
@@ -139,6 +166,60 @@ func (s *CronScheduler) Schedule(job Job) error {
 	return nil
 }
 
+// ScheduleCron adds a job that runs on its own schedule, independent of the
+// scheduler-wide cron expression. Jobs added while the scheduler is running
+// start immediately.
+func (s *CronScheduler) ScheduleCron(schedule *cron.Schedule, job Job) error {
+	if schedule == nil {
+		return fmt.Errorf("schedule is required")
+	}
+	cj := cronJob{schedule: schedule, job: job}
+	s.stopMu.Lock()
+	defer s.stopMu.Unlock()
+	s.jobsMu.Lock()
+	s.cronJobs = append(s.cronJobs, cj)
+	s.jobsMu.Unlock()
+	if s.running.Load() {
+		go s.runCronJob(s.runCtx, s.stopCh, cj)
+	}
+	return nil
+}
+
+// runCronJob fires cj at each of its scheduled times until the scheduler
+// stops.
+func (s *CronScheduler) runCronJob(ctx context.Context, stopCh chan struct{}, cj cronJob) {
+	for {
+		next := cj.schedule.Next(time.Now())
+		if next.IsZero() {
+			return
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-timer.C:
+			go s.executeCronJob(ctx, cj.job)
+		case <-stopCh:
+			timer.Stop()
+			return
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// executeCronJob runs one firing of j, logging its error or panic so a
+// failing job does not take down the scheduler.
+func (s *CronScheduler) executeCronJob(ctx context.Context, j Job) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			s.logger.Error("Panic in cron job execution", "scheduler", s.name, "panic", rec)
+		}
+	}()
+	if err := j.Execute(ctx); err != nil {
+		s.logger.Error("Cron job execution failed", "scheduler", s.name, "error", err)
+	}
+}
+
 // FunctionJob is a Job implementation that executes a function
 type FunctionJob struct {
 	fn func(context.Context) error
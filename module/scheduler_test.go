@@ -8,6 +8,7 @@ import (
 	"time"
 
 	wscheduler "github.com/GoCodeAlone/workflow/scheduler"
+	"github.com/GoCodeAlone/workflow/scheduler/cron"
 )
 
 func TestNewCronScheduler(t *testing.T) {
@@ -236,3 +237,32 @@ func TestCronScheduler_ExecutesJobs(t *testing.T) {
 		t.Errorf("expected job to be executed once, got %d", executed.Load())
 	}
 }
+
+func TestCronScheduler_ScheduleCron(t *testing.T) {
+	// A scheduler used only for per-job schedules needs no expression of its own.
+	s := NewCronScheduler("test", "")
+	every, err := cron.Parse("@every 1s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ran := make(chan struct{}, 1)
+	if err := s.ScheduleCron(every, NewFunctionJob(func(ctx context.Context) error {
+		select {
+		case ran <- struct{}{}:
+		default:
+		}
+		return nil
+	})); err != nil {
+		t.Fatalf("ScheduleCron: %v", err)
+	}
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer func() { _ = s.Stop(context.Background()) }()
+
+	select {
+	case <-ran:
+	case <-time.After(3 * time.Second):
+		t.Fatal("per-job schedule did not fire")
+	}
+}
@@ -0,0 +1,313 @@
+package module
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/GoCodeAlone/modular"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrSchemaNotRegistered is returned when a schema name has no registered
+// version.
+var ErrSchemaNotRegistered = errors.New("schema not registered")
+
+// SchemaSourceConfig declares one named schema, inline or from a file.
+type SchemaSourceConfig struct {
+	// Schema is the inline JSON Schema.
+	Schema map[string]any `json:"schema,omitempty" yaml:"schema,omitempty"`
+	// File is a JSON or YAML file holding the schema.
+	File string `json:"file,omitempty" yaml:"file,omitempty"`
+}
+
+// SchemaRegistryConfig holds the configuration of a schema.registry module.
+type SchemaRegistryConfig struct {
+	// Schemas maps schema names, optionally versioned as name@version, to
+	// their sources.
+	Schemas map[string]SchemaSourceConfig `json:"schemas,omitempty" yaml:"schemas,omitempty"`
+	// Dir is a directory of *.json, *.yaml and *.yml schema files, each
+	// registered under its file name without the extension.
+	Dir string `json:"dir,omitempty" yaml:"dir,omitempty"`
+}
+
+// ParseSchemaRegistryConfig reads a schema.registry config map. Values are
+// checked when the module initializes.
+func ParseSchemaRegistryConfig(cfg map[string]any) SchemaRegistryConfig {
+	rc := SchemaRegistryConfig{Schemas: make(map[string]SchemaSourceConfig)}
+	rc.Dir, _ = cfg["dir"].(string)
+	schemas, _ := cfg["schemas"].(map[string]any)
+	for name, raw := range schemas {
+		sm, _ := raw.(map[string]any)
+		var sc SchemaSourceConfig
+		sc.Schema, _ = sm["schema"].(map[string]any)
+		sc.File, _ = sm["file"].(string)
+		rc.Schemas[name] = sc
+	}
+	return rc
+}
+
+// SchemaVersion identifies a version of a registered schema.
+type SchemaVersion struct {
+	Name    string `json:"name"`
+	Version int    `json:"version"`
+}
+
+// String returns the version as name@version.
+func (v SchemaVersion) String() string { return v.Name + "@" + strconv.Itoa(v.Version) }
+
+// ParseSchemaRef splits a schema reference of the form name or
+// name@version. The version is a positive integer, optionally prefixed with
+// "v"; it is 0 when the reference names no version.
+func ParseSchemaRef(ref string) (SchemaVersion, error) {
+	name, version, versioned := strings.Cut(ref, "@")
+	if name == "" {
+		return SchemaVersion{}, fmt.Errorf("invalid schema reference %q: empty name", ref)
+	}
+	if !versioned {
+		return SchemaVersion{Name: name}, nil
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(version, "v"))
+	if err != nil || n < 1 {
+		return SchemaVersion{}, fmt.Errorf("invalid schema reference %q: version must be a positive integer such as 2 or v2", ref)
+	}
+	return SchemaVersion{Name: name, Version: n}, nil
+}
+
+// RegisteredSchema is a compiled schema version.
+type RegisteredSchema struct {
+	Ref    SchemaVersion
+	Schema map[string]any
+
+	compiled *jsonschema.Schema
+}
+
+// Validate checks data against the schema and returns the violations found,
+// each as "<instance location>: <message>".
+func (s *RegisteredSchema) Validate(data any) []string {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return []string{"data is not JSON-encodable: " + err.Error()}
+	}
+	return schemaViolations(s.compiled, raw)
+}
+
+// SchemaRegistry holds named, versioned JSON Schemas that validation steps
+// reference by name, so that a schema shared by several routes is declared
+// once. A name without a version resolves to its latest version.
+type SchemaRegistry struct {
+	name   string
+	config SchemaRegistryConfig
+
+	mu       sync.RWMutex
+	versions map[string][]*RegisteredSchema // ascending by version
+}
+
+// NewSchemaRegistry creates a schema registry module.
+func NewSchemaRegistry(name string, cfg SchemaRegistryConfig) *SchemaRegistry {
+	return &SchemaRegistry{
+		name:     name,
+		config:   cfg,
+		versions: make(map[string][]*RegisteredSchema),
+	}
+}
+
+// Name implements modular.Module.
+func (r *SchemaRegistry) Name() string { return r.name }
+
+// Init implements modular.Module. It loads and compiles every declared
+// schema. A name declared without a version is version 1.
+func (r *SchemaRegistry) Init(_ modular.Application) error {
+	sources := make(map[string]SchemaSourceConfig, len(r.config.Schemas))
+	maps.Copy(sources, r.config.Schemas)
+	if r.config.Dir != "" {
+		entries, err := os.ReadDir(r.config.Dir)
+		if err != nil {
+			return fmt.Errorf("schema registry %q: read dir: %w", r.name, err)
+		}
+		for _, e := range entries {
+			ext := filepath.Ext(e.Name())
+			if e.IsDir() || (ext != ".json" && ext != ".yaml" && ext != ".yml") {
+				continue
+			}
+			key := strings.TrimSuffix(e.Name(), ext)
+			if _, dup := sources[key]; dup {
+				return fmt.Errorf("schema registry %q: schema %q is declared in config and in %s", r.name, key, r.config.Dir)
+			}
+			sources[key] = SchemaSourceConfig{File: filepath.Join(r.config.Dir, e.Name())}
+		}
+	}
+
+	loaded := make(map[string][]*RegisteredSchema)
+	for _, key := range slices.Sorted(maps.Keys(sources)) {
+		ref, err := ParseSchemaRef(key)
+		if err != nil {
+			return fmt.Errorf("schema registry %q: %w", r.name, err)
+		}
+		if ref.Version == 0 {
+			ref.Version = 1
+		}
+		for _, s := range loaded[ref.Name] {
+			if s.Ref.Version == ref.Version {
+				return fmt.Errorf("schema registry %q: %s is declared more than once", r.name, ref)
+			}
+		}
+		schema, err := loadSchemaSource(sources[key])
+		if err != nil {
+			return fmt.Errorf("schema registry %q: %s: %w", r.name, key, err)
+		}
+		rs, err := compileRegisteredSchema(ref, schema)
+		if err != nil {
+			return fmt.Errorf("schema registry %q: %s: %w", r.name, key, err)
+		}
+		loaded[ref.Name] = insertSchemaVersion(loaded[ref.Name], rs)
+	}
+
+	r.mu.Lock()
+	r.versions = loaded
+	r.mu.Unlock()
+	return nil
+}
+
+// ProvidesServices implements modular.Module.
+func (r *SchemaRegistry) ProvidesServices() []modular.ServiceProvider {
+	return []modular.ServiceProvider{
+		{Name: r.name, Description: "Schema registry: " + r.name, Instance: r},
+	}
+}
+
+// RequiresServices implements modular.Module.
+func (r *SchemaRegistry) RequiresServices() []modular.ServiceDependency {
+	return nil
+}
+
+// Lookup returns the schema a reference names: the given version of
+// name@version, or the latest version of a bare name.
+func (r *SchemaRegistry) Lookup(ref string) (*RegisteredSchema, error) {
+	sr, err := ParseSchemaRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	versions := r.versions[sr.Name]
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrSchemaNotRegistered, sr.Name)
+	}
+	if sr.Version == 0 {
+		return versions[len(versions)-1], nil
+	}
+	for _, s := range versions {
+		if s.Ref.Version == sr.Version {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrSchemaNotRegistered, sr)
+}
+
+// Register compiles schema and registers it under ref. A bare name gets the
+// next version of that name; an existing version is replaced. Steps resolve
+// schemas on every execution, so they pick up the change immediately.
+func (r *SchemaRegistry) Register(ref string, schema map[string]any) (SchemaVersion, error) {
+	sr, err := ParseSchemaRef(ref)
+	if err != nil {
+		return SchemaVersion{}, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if sr.Version == 0 {
+		sr.Version = 1
+		if versions := r.versions[sr.Name]; len(versions) > 0 {
+			sr.Version = versions[len(versions)-1].Ref.Version + 1
+		}
+	}
+	rs, err := compileRegisteredSchema(sr, schema)
+	if err != nil {
+		return SchemaVersion{}, err
+	}
+	r.versions[sr.Name] = insertSchemaVersion(r.versions[sr.Name], rs)
+	return sr, nil
+}
+
+// Schemas returns the refs of every registered schema version, sorted by
+// name then version.
+func (r *SchemaRegistry) Schemas() []SchemaVersion {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var refs []SchemaVersion
+	for _, name := range slices.Sorted(maps.Keys(r.versions)) {
+		for _, s := range r.versions[name] {
+			refs = append(refs, s.Ref)
+		}
+	}
+	return refs
+}
+
+// insertSchemaVersion adds rs to versions, which is sorted by version,
+// replacing an entry with the same version.
+func insertSchemaVersion(versions []*RegisteredSchema, rs *RegisteredSchema) []*RegisteredSchema {
+	i, found := slices.BinarySearchFunc(versions, rs.Ref.Version, func(s *RegisteredSchema, v int) int {
+		return s.Ref.Version - v
+	})
+	if found {
+		out := slices.Clone(versions)
+		out[i] = rs
+		return out
+	}
+	return slices.Insert(slices.Clone(versions), i, rs)
+}
+
+// loadSchemaSource returns the inline schema of src or reads its file.
+func loadSchemaSource(src SchemaSourceConfig) (map[string]any, error) {
+	switch {
+	case src.Schema != nil && src.File != "":
+		return nil, fmt.Errorf("set either 'schema' or 'file', not both")
+	case src.Schema != nil:
+		return src.Schema, nil
+	case src.File == "":
+		return nil, fmt.Errorf("'schema' or 'file' is required")
+	}
+	raw, err := os.ReadFile(src.File)
+	if err != nil {
+		return nil, err
+	}
+	var schema map[string]any
+	if err := yaml.Unmarshal(raw, &schema); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", src.File, err)
+	}
+	if schema == nil {
+		return nil, fmt.Errorf("%s is empty", src.File)
+	}
+	return schema, nil
+}
+
+func compileRegisteredSchema(ref SchemaVersion, schema map[string]any) (*RegisteredSchema, error) {
+	raw, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	loc := "mem://schemas/" + url.PathEscape(ref.String()) + ".json"
+	c := jsonschema.NewCompiler()
+	if err := c.AddResource(loc, doc); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	compiled, err := c.Compile(loc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	return &RegisteredSchema{Ref: ref, Schema: schema, compiled: compiled}, nil
+}
@@ -0,0 +1,144 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newOrderSchemaRegistry(t *testing.T) *SchemaRegistry {
+	t.Helper()
+	dir := t.TempDir()
+	v2 := "type: object\nrequired: [id, total]\nproperties:\n  id: {type: string}\n  total: {type: number, minimum: 0}\n"
+	if err := os.WriteFile(filepath.Join(dir, "order@v2.yaml"), []byte(v2), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("ignored"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	r := NewSchemaRegistry("schemas", ParseSchemaRegistryConfig(map[string]any{
+		"dir": dir,
+		"schemas": map[string]any{
+			"order": map[string]any{"schema": map[string]any{
+				"type":     "object",
+				"required": []any{"id"},
+			}},
+		},
+	}))
+	if err := r.Init(NewMockApplication()); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	return r
+}
+
+func TestSchemaRegistry_Versions(t *testing.T) {
+	r := newOrderSchemaRegistry(t)
+
+	if got := r.Schemas(); len(got) != 2 || got[0].String() != "order@1" || got[1].String() != "order@2" {
+		t.Fatalf("Schemas() = %v", got)
+	}
+	latest, err := r.Lookup("order")
+	if err != nil || latest.Ref.Version != 2 {
+		t.Fatalf("Lookup(order) = %+v, %v; want version 2", latest, err)
+	}
+	v1, err := r.Lookup("order@1")
+	if err != nil || v1.Ref.Version != 1 {
+		t.Fatalf("Lookup(order@1) = %+v, %v", v1, err)
+	}
+	if got := v1.Validate(map[string]any{"id": "a"}); len(got) != 0 {
+		t.Errorf("v1 violations = %v", got)
+	}
+	if got := latest.Validate(map[string]any{"id": "a", "total": -1}); len(got) != 1 || !strings.HasPrefix(got[0], "/total: ") {
+		t.Errorf("v2 violations = %v", got)
+	}
+	if _, err := r.Lookup("order@3"); !errors.Is(err, ErrSchemaNotRegistered) {
+		t.Errorf("Lookup(order@3) error = %v", err)
+	}
+	if _, err := r.Lookup("order@latest"); err == nil {
+		t.Error("expected invalid version error")
+	}
+
+	ref, err := r.Register("order", map[string]any{"type": "object", "required": []any{"id", "currency"}})
+	if err != nil || ref.Version != 3 {
+		t.Fatalf("Register = %v, %v; want version 3", ref, err)
+	}
+	if latest, _ := r.Lookup("order"); latest.Ref.Version != 3 {
+		t.Errorf("latest after Register = %v", latest.Ref)
+	}
+	if _, err := r.Register("order@1", map[string]any{"type": 5}); err == nil {
+		t.Error("expected compile error")
+	}
+}
+
+func TestSchemaRegistry_InitErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  map[string]any
+		want string
+	}{
+		{"duplicate version", map[string]any{"schemas": map[string]any{
+			"a":    map[string]any{"schema": map[string]any{}},
+			"a@v1": map[string]any{"schema": map[string]any{}},
+		}}, "more than once"},
+		{"no source", map[string]any{"schemas": map[string]any{"a": map[string]any{}}}, "'schema' or 'file' is required"},
+		{"invalid schema", map[string]any{"schemas": map[string]any{"a": map[string]any{"schema": map[string]any{"type": 1}}}}, "invalid schema"},
+		{"missing file", map[string]any{"schemas": map[string]any{"a": map[string]any{"file": "missing.json"}}}, "missing.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewSchemaRegistry("schemas", ParseSchemaRegistryConfig(tt.cfg)).Init(NewMockApplication())
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Init error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchemaValidateStep(t *testing.T) {
+	app := NewMockApplication()
+	app.Services["schemas"] = newOrderSchemaRegistry(t)
+	newStep := func(cfg map[string]any) PipelineStep {
+		t.Helper()
+		cfg["registry"] = "schemas"
+		step, err := NewSchemaValidateStepFactory()("check", cfg, app)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return step
+	}
+	ctx := context.Background()
+	invalid := NewPipelineContext(map[string]any{"id": "a"}, nil)
+
+	res, err := newStep(map[string]any{"schema": "order@1"}).Execute(ctx, invalid)
+	if err != nil || res.Output["valid"] != true || res.Output["schema"] != "order@1" {
+		t.Fatalf("order@1: %+v, %v", res, err)
+	}
+
+	_, err = newStep(map[string]any{"schema": "order"}).Execute(ctx, invalid)
+	var p *Problem
+	if !errors.As(err, &p) || p.Code != string(ProblemValidationFailed) || !strings.Contains(p.Detail, "order@2") {
+		t.Fatalf("latest order: error = %v", err)
+	}
+
+	res, err = newStep(map[string]any{"schema": "order", "on_invalid": "respond"}).Execute(ctx, invalid)
+	if err != nil || !res.Stop || res.Output["response_status"] != 400 || !strings.Contains(res.Output["response_body"].(string), `"field":"/"`) {
+		t.Fatalf("respond: %+v, %v", res, err)
+	}
+
+	pc := NewPipelineContext(nil, nil)
+	pc.MergeStepOutput("parse", map[string]any{"body": map[string]any{"id": "a", "total": 5}})
+	res, err = newStep(map[string]any{"schema": "order", "source": "steps.parse.body", "on_invalid": "continue"}).Execute(ctx, pc)
+	if err != nil || res.Output["valid"] != true {
+		t.Fatalf("source: %+v, %v", res, err)
+	}
+
+	if _, err := newStep(map[string]any{"schema": "customer"}).Execute(ctx, invalid); !errors.Is(err, ErrSchemaNotRegistered) {
+		t.Fatalf("unknown schema: error = %v", err)
+	}
+	if _, err := NewSchemaValidateStepFactory()("check", map[string]any{"registry": "schemas", "schema": "order", "on_invalid": "ignore"}, app); err == nil {
+		t.Fatal("expected error for invalid on_invalid")
+	}
+}
@@ -0,0 +1,11 @@
+package module
+
+import "github.com/GoCodeAlone/workflow/config"
+
+// ServiceContractDeclarer is implemented by modules that declare in code the
+// services they provide and need, as the provides: and needs: keys of a
+// module config do. The engine combines both when ordering modules and
+// checking that every need has exactly one provider.
+type ServiceContractDeclarer interface {
+	ServiceContracts() config.ModuleContracts
+}
@@ -0,0 +1,119 @@
+package module
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// ErrServiceNotFound is returned by the service locator functions when no
+// service matches.
+var ErrServiceNotFound = errors.New("service not found")
+
+// AmbiguousServiceError is returned by the service locator functions when
+// more than one distinct service matches. Names lists every candidate,
+// sorted.
+type AmbiguousServiceError struct {
+	Type   string // the requested type, e.g. "*evstore.InMemoryDLQStore"
+	Suffix string // the required name suffix, if any
+	Names  []string
+}
+
+func (e *AmbiguousServiceError) Error() string {
+	what := e.Type
+	if e.Suffix != "" {
+		what += fmt.Sprintf(" named *%s", e.Suffix)
+	}
+	return fmt.Sprintf("%d services match %s: %s", len(e.Names), what, strings.Join(e.Names, ", "))
+}
+
+// ServiceMatch is a service found in a service registry, with the name it is
+// registered under.
+type ServiceMatch[T any] struct {
+	Name    string
+	Service T
+}
+
+// FindAllByInterface returns every service in registry that implements (or
+// is) T, sorted by name. An instance registered under several names, as
+// modules commonly do for aliases, is returned once under the name that
+// sorts first.
+func FindAllByInterface[T any](registry map[string]any) []ServiceMatch[T] {
+	return findServices[T](registry, "")
+}
+
+// FindByInterface returns the service in registry that implements (or is) T.
+// It returns ErrServiceNotFound when there is none. When several distinct
+// services match, it returns the one whose name sorts first together with
+// an *AmbiguousServiceError naming every candidate, so callers can warn and
+// carry on deterministically.
+func FindByInterface[T any](registry map[string]any) (ServiceMatch[T], error) {
+	return single(findServices[T](registry, ""), "")
+}
+
+// FindByNameSuffixAndType is like FindByInterface but only considers
+// services whose name ends in suffix, e.g. DLQStoreServiceSuffix, and
+// names more than the bare suffix.
+func FindByNameSuffixAndType[T any](registry map[string]any, suffix string) (ServiceMatch[T], error) {
+	return single(findServices[T](registry, suffix), suffix)
+}
+
+func findServices[T any](registry map[string]any, suffix string) []ServiceMatch[T] {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		if suffix == "" || (strings.HasSuffix(name, suffix) && len(name) > len(suffix)) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	var matches []ServiceMatch[T]
+	for _, name := range names {
+		svc, ok := registry[name].(T)
+		if !ok || isNilService(registry[name]) {
+			continue
+		}
+		if slices.ContainsFunc(matches, func(m ServiceMatch[T]) bool { return sameService(m.Service, svc) }) {
+			continue
+		}
+		matches = append(matches, ServiceMatch[T]{Name: name, Service: svc})
+	}
+	return matches
+}
+
+func single[T any](matches []ServiceMatch[T], suffix string) (ServiceMatch[T], error) {
+	switch len(matches) {
+	case 0:
+		return ServiceMatch[T]{}, ErrServiceNotFound
+	case 1:
+		return matches[0], nil
+	}
+	names := make([]string, len(matches))
+	for i, m := range matches {
+		names[i] = m.Name
+	}
+	return matches[0], &AmbiguousServiceError{
+		Type:   reflect.TypeFor[T]().String(),
+		Suffix: suffix,
+		Names:  names,
+	}
+}
+
+// isNilService reports whether svc is a typed nil pointer, which would match
+// a type assertion but cannot be used.
+func isNilService(svc any) bool {
+	v := reflect.ValueOf(svc)
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
+
+// sameService reports whether a and b are the same instance. Values that
+// cannot be compared, such as maps and funcs, are never the same.
+func sameService(a, b any) bool {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.Type() != vb.Type() || !va.Comparable() {
+		return false
+	}
+	return va.Equal(vb)
+}
@@ -0,0 +1,120 @@
+package module
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+
+	evstore "github.com/GoCodeAlone/workflow/store"
+)
+
+func TestFindByInterface(t *testing.T) {
+	tracker := &ExecutionTracker{}
+	registry := map[string]any{
+		"tracker":  tracker,
+		"settings": map[string]any{"a": 1},
+	}
+
+	match, err := FindByInterface[*ExecutionTracker](registry)
+	if err != nil || match.Name != "tracker" || match.Service != tracker {
+		t.Errorf("match = %+v, err = %v", match, err)
+	}
+
+	if _, err := FindByInterface[http.Handler](registry); !errors.Is(err, ErrServiceNotFound) {
+		t.Errorf("err = %v, want ErrServiceNotFound", err)
+	}
+}
+
+func TestFindByInterface_AliasesAreNotAmbiguous(t *testing.T) {
+	// Modules register one instance under several names, e.g. the event
+	// store under "events" and "events.admin".
+	store := evstore.NewInMemoryDLQStore()
+	registry := map[string]any{"dlq.store": store, "dlq-alias": store}
+
+	match, err := FindByInterface[*evstore.InMemoryDLQStore](registry)
+	if err != nil {
+		t.Fatalf("aliases of one instance reported as ambiguous: %v", err)
+	}
+	if match.Name != "dlq-alias" {
+		t.Errorf("name = %q, want the alias that sorts first", match.Name)
+	}
+}
+
+func TestFindByInterface_Ambiguous(t *testing.T) {
+	first, second := evstore.NewInMemoryDLQStore(), evstore.NewInMemoryDLQStore()
+	registry := map[string]any{"orders-dlq.store": second, "billing-dlq.store": first}
+
+	match, err := FindByInterface[*evstore.InMemoryDLQStore](registry)
+	var ambiguous *AmbiguousServiceError
+	if !errors.As(err, &ambiguous) {
+		t.Fatalf("err = %v, want *AmbiguousServiceError", err)
+	}
+	if !slices.Equal(ambiguous.Names, []string{"billing-dlq.store", "orders-dlq.store"}) {
+		t.Errorf("candidates = %v", ambiguous.Names)
+	}
+	if match.Name != "billing-dlq.store" || match.Service != first {
+		t.Errorf("match = %q, want the candidate that sorts first", match.Name)
+	}
+	if got := err.Error(); got != "2 services match *store.InMemoryDLQStore: billing-dlq.store, orders-dlq.store" {
+		t.Errorf("error = %q", got)
+	}
+}
+
+func TestFindByNameSuffixAndType(t *testing.T) {
+	dlqStore := evstore.NewInMemoryDLQStore()
+	registry := map[string]any{
+		"dlq.store":    dlqStore,
+		"dlq.admin":    http.NewServeMux(),
+		"backup.store": &ExecutionTracker{},           // right suffix, wrong type
+		"flags.store":  map[string]any{},              // right suffix, wrong type
+		"events.admin": evstore.NewInMemoryDLQStore(), // right type, wrong suffix
+	}
+
+	match, err := FindByNameSuffixAndType[*evstore.InMemoryDLQStore](registry, DLQStoreServiceSuffix)
+	if err != nil || match.Name != "dlq.store" || match.Service != dlqStore {
+		t.Errorf("match = %+v, err = %v", match, err)
+	}
+
+	// Any http.Handler registered under .admin is a candidate, so a second
+	// one is reported rather than picked at random.
+	registry["orders.admin"] = http.NewServeMux()
+	_, err = FindByNameSuffixAndType[http.Handler](registry, DLQAdminServiceSuffix)
+	var ambiguous *AmbiguousServiceError
+	if !errors.As(err, &ambiguous) || !slices.Equal(ambiguous.Names, []string{"dlq.admin", "orders.admin"}) {
+		t.Fatalf("err = %v, want dlq.admin and orders.admin as candidates", err)
+	}
+	if ambiguous.Suffix != ".admin" || ambiguous.Type != "http.Handler" {
+		t.Errorf("ambiguous = %+v", ambiguous)
+	}
+
+	if _, err := FindByNameSuffixAndType[*evstore.InMemoryDLQStore](registry, ".missing"); !errors.Is(err, ErrServiceNotFound) {
+		t.Errorf("err = %v, want ErrServiceNotFound", err)
+	}
+}
+
+func TestFindAllByInterface(t *testing.T) {
+	var nilTracker *ExecutionTracker
+	a, b := &ExecutionTracker{}, &ExecutionTracker{}
+	registry := map[string]any{
+		"b":       b,
+		"a":       a,
+		"a-alias": a,
+		"nil":     nilTracker,
+		"other":   "not a tracker",
+	}
+
+	var names []string
+	for _, m := range FindAllByInterface[*ExecutionTracker](registry) {
+		names = append(names, m.Name)
+	}
+	if !slices.Equal(names, []string{"a", "b"}) {
+		t.Errorf("names = %v, want sorted, deduplicated and without typed nils", names)
+	}
+
+	// Uncomparable services are kept, not compared.
+	registry = map[string]any{"m1": map[string]any{}, "m2": map[string]any{}}
+	if got := FindAllByInterface[map[string]any](registry); len(got) != 2 {
+		t.Errorf("got %d maps, want 2", len(got))
+	}
+}
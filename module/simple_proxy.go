@@ -2,7 +2,6 @@ package module
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httputil"
@@ -42,14 +41,8 @@ func (p *SimpleProxy) SetTargets(targets map[string]string) error {
 		p.targets[prefix] = backend
 		rp := httputil.NewSingleHostReverseProxy(backend)
 		backendHost := backend.Host
-		rp.ErrorHandler = func(w http.ResponseWriter, r *http.Request, _ error) {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadGateway)
-			_ = json.NewEncoder(w).Encode(map[string]string{
-				"error":   "backend unavailable",
-				"backend": backendHost,
-				"path":    r.URL.Path,
-			})
+		rp.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+			writeProxyProblem(w, r, backendHost, err)
 		}
 		p.proxies[prefix] = rp
 	}
@@ -114,5 +107,5 @@ func (p *SimpleProxy) Handle(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	http.Error(w, "no backend configured for path", http.StatusBadGateway)
+	writeRequestProblem(w, r, ProblemUpstreamError, "no backend configured for path")
 }
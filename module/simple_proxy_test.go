@@ -119,24 +119,16 @@ func TestSimpleProxy_HandleBackendUnavailable(t *testing.T) {
 		t.Errorf("expected 502, got %d", resp.StatusCode)
 	}
 
-	ct := resp.Header.Get("Content-Type")
-	if ct != "application/json" {
-		t.Errorf("expected Content-Type application/json, got %q", ct)
+	if ct := resp.Header.Get("Content-Type"); ct != ProblemContentType {
+		t.Errorf("expected Content-Type %s, got %q", ProblemContentType, ct)
 	}
 
-	var errResp map[string]string
-	if err := json.Unmarshal(body, &errResp); err != nil {
-		t.Fatalf("failed to parse JSON response: %v\nbody: %s", err, string(body))
+	var prob Problem
+	if err := json.Unmarshal(body, &prob); err != nil {
+		t.Fatalf("failed to parse problem response: %v\nbody: %s", err, string(body))
 	}
-
-	if errResp["error"] != "backend unavailable" {
-		t.Errorf("expected error 'backend unavailable', got %q", errResp["error"])
-	}
-	if errResp["backend"] != "127.0.0.1:59999" {
-		t.Errorf("expected backend '127.0.0.1:59999', got %q", errResp["backend"])
-	}
-	if errResp["path"] != "/api/orders/123" {
-		t.Errorf("expected path '/api/orders/123', got %q", errResp["path"])
+	if prob.Code != string(ProblemUpstreamError) || prob.Detail != "backend 127.0.0.1:59999 unavailable" || prob.Instance != "/api/orders/123" {
+		t.Errorf("unexpected problem: %+v", prob)
 	}
 }
 
@@ -0,0 +1,371 @@
+package module
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// maxSQLStatementLength bounds the SQL recorded for a statement, so that a
+// generated query cannot bloat execution events or the slow-query log.
+const maxSQLStatementLength = 2048
+
+// sqlLiteralPlaceholder replaces literal values in a normalized statement.
+const sqlLiteralPlaceholder = "?"
+
+type sqlTokenKind int
+
+const (
+	sqlWord    sqlTokenKind = iota // identifier or keyword, possibly qualified
+	sqlLiteral                     // string or numeric literal
+	sqlParam                       // $1, ? or :name placeholder
+	sqlPunct                       // operator or punctuation
+)
+
+// sqlToken is a token of a SQL statement. space records whether whitespace
+// or a comment preceded it, so statements render with their own layout.
+type sqlToken struct {
+	kind  sqlTokenKind
+	text  string
+	space bool
+}
+
+// sqlOperatorChars are the characters SQL operators are made of.
+const sqlOperatorChars = "<>=!|&~^#@+-*/%:"
+
+// tokenizeSQL splits a statement into tokens, dropping whitespace and
+// comments. It understands enough of the PostgreSQL, MySQL and SQLite
+// dialects to tell literals from identifiers; it does not validate SQL.
+func tokenizeSQL(q string) []sqlToken {
+	var tokens []sqlToken
+	space := false
+	for i := 0; i < len(q); {
+		c := q[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			space = true
+			i++
+			continue
+		case strings.HasPrefix(q[i:], "--"):
+			if end := strings.IndexByte(q[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(q)
+			}
+			space = true
+			continue
+		case strings.HasPrefix(q[i:], "/*"):
+			if end := strings.Index(q[i+2:], "*/"); end >= 0 {
+				i += end + 4
+			} else {
+				i = len(q)
+			}
+			space = true
+			continue
+		}
+
+		start, kind := i, sqlPunct
+		switch {
+		case c == '\'':
+			i, kind = scanSQLQuoted(q, i, false), sqlLiteral
+		case strings.IndexByte("EeNnXxBb", c) >= 0 && i+1 < len(q) && q[i+1] == '\'':
+			i, kind = scanSQLQuoted(q, i+1, c == 'E' || c == 'e'), sqlLiteral
+		case c == '$' && i+1 < len(q) && isSQLDigit(q[i+1]):
+			for i++; i < len(q) && isSQLDigit(q[i]); i++ {
+			}
+			kind = sqlParam
+		case c == '$':
+			if end, ok := scanSQLDollarQuoted(q, i); ok {
+				i, kind = end, sqlLiteral
+			} else {
+				i++
+			}
+		case c == '?':
+			i, kind = i+1, sqlParam
+		case c == ':' && i+1 < len(q) && isSQLIdentStart(q[i+1]) && (i == 0 || q[i-1] != ':'):
+			for i++; i < len(q) && isSQLIdentChar(q[i]); i++ {
+			}
+			kind = sqlParam
+		case isSQLDigit(c) || (c == '.' && i+1 < len(q) && isSQLDigit(q[i+1])):
+			i, kind = scanSQLNumber(q, i), sqlLiteral
+		case isSQLIdentStart(c) || c == '"' || c == '`':
+			i, kind = scanSQLIdentifier(q, i), sqlWord
+		case strings.IndexByte(sqlOperatorChars, c) >= 0:
+			// A sign after another operator starts a new token, so that
+			// "=-1" compares with a negative number.
+			for i++; i < len(q) && strings.IndexByte(sqlOperatorChars, q[i]) >= 0 && q[i] != '-' && q[i] != '+'; i++ {
+			}
+		default:
+			i++
+		}
+		tokens = append(tokens, sqlToken{kind: kind, text: q[start:i], space: space})
+		space = false
+	}
+	return tokens
+}
+
+func isSQLDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func isSQLIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= utf8.RuneSelf
+}
+
+func isSQLIdentChar(c byte) bool { return isSQLIdentStart(c) || isSQLDigit(c) || c == '$' }
+
+// scanSQLQuoted returns the end of the quoted string or identifier starting
+// at q[i]. A doubled quote escapes the quote, as does a backslash when
+// backslashEscapes is set.
+func scanSQLQuoted(q string, i int, backslashEscapes bool) int {
+	quote := q[i]
+	for i++; i < len(q); i++ {
+		switch {
+		case backslashEscapes && q[i] == '\\':
+			i++
+		case q[i] == quote && i+1 < len(q) && q[i+1] == quote:
+			i++
+		case q[i] == quote:
+			return i + 1
+		}
+	}
+	return len(q)
+}
+
+// scanSQLDollarQuoted returns the end of the PostgreSQL dollar-quoted string
+// ($$...$$ or $tag$...$tag$) starting at q[i].
+func scanSQLDollarQuoted(q string, i int) (int, bool) {
+	j := i + 1
+	for j < len(q) && isSQLIdentChar(q[j]) && q[j] != '$' {
+		j++
+	}
+	if j >= len(q) || q[j] != '$' {
+		return 0, false
+	}
+	tag := q[i : j+1]
+	if end := strings.Index(q[j+1:], tag); end >= 0 {
+		return j + 1 + end + len(tag), true
+	}
+	return len(q), true
+}
+
+func scanSQLNumber(q string, i int) int {
+	for i < len(q) && (isSQLDigit(q[i]) || q[i] == '.') {
+		i++
+	}
+	if i < len(q) && (q[i] == 'e' || q[i] == 'E') {
+		j := i + 1
+		if j < len(q) && (q[j] == '+' || q[j] == '-') {
+			j++
+		}
+		if j < len(q) && isSQLDigit(q[j]) {
+			for i = j; i < len(q) && isSQLDigit(q[i]); i++ {
+			}
+		}
+	}
+	return i
+}
+
+// scanSQLIdentifier returns the end of the possibly qualified and quoted
+// identifier starting at q[i], such as orders, o.total or "Orders"."Total".
+func scanSQLIdentifier(q string, i int) int {
+	for {
+		if q[i] == '"' || q[i] == '`' {
+			i = scanSQLQuoted(q, i, false)
+		} else {
+			for i++; i < len(q) && isSQLIdentChar(q[i]); i++ {
+			}
+		}
+		if i+1 >= len(q) || q[i] != '.' {
+			return i
+		}
+		switch next := q[i+1]; {
+		case next == '*':
+			return i + 2
+		case isSQLIdentStart(next) || next == '"' || next == '`':
+			i++
+		default:
+			return i
+		}
+	}
+}
+
+// renderSQL joins tokens into a statement, separating them by one space
+// where the original had whitespace or a comment, and caps its length.
+func renderSQL(tokens []sqlToken, text func(i int) string) string {
+	var b strings.Builder
+	for i, t := range tokens {
+		if t.kind == sqlPunct && t.text == ";" && i == len(tokens)-1 {
+			break
+		}
+		if t.space && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(text(i))
+		if b.Len() > maxSQLStatementLength {
+			return strings.ToValidUTF8(b.String()[:maxSQLStatementLength], "") + "..."
+		}
+	}
+	return b.String()
+}
+
+// normalizeSQLStatement returns the statement with comments removed,
+// whitespace collapsed and every literal replaced by "?", so that statements
+// differing only in their values group together.
+func normalizeSQLStatement(tokens []sqlToken) string {
+	return renderSQL(tokens, func(i int) string {
+		if tokens[i].kind == sqlLiteral {
+			return sqlLiteralPlaceholder
+		}
+		return tokens[i].text
+	})
+}
+
+// redactSQLStatement returns the statement with comments removed and
+// whitespace collapsed, replacing the literals that are compared with,
+// assigned to or inserted into a column whose name matches patterns, such as
+// password = 'hunter2', with RedactionPlaceholder. Parameter values are never
+// part of a statement, so this only catches literals written into the SQL.
+func redactSQLStatement(tokens []sqlToken, patterns []string) string {
+	sensitive := sensitiveSQLLiterals(tokens, patterns)
+	return renderSQL(tokens, func(i int) string {
+		if sensitive[i] {
+			return "'" + RedactionPlaceholder + "'"
+		}
+		return tokens[i].text
+	})
+}
+
+// sqlParen is an open parenthesis while scanning for sensitive literals.
+type sqlParen struct {
+	inList string // column an IN list is compared with
+	values bool   // a row of INSERT ... VALUES
+	pos    int    // position in the VALUES row
+}
+
+// sensitiveSQLLiterals returns the indexes of the literal tokens whose
+// column matches patterns.
+func sensitiveSQLLiterals(tokens []sqlToken, patterns []string) map[int]bool {
+	sensitive := map[int]bool{}
+	columns := insertColumns(tokens)
+	var parens []sqlParen
+	inValues := false
+	for i, t := range tokens {
+		switch {
+		case t.kind == sqlPunct && t.text == "(":
+			p := sqlParen{values: inValues && len(parens) == 0}
+			if i >= 2 && strings.EqualFold(tokens[i-1].text, "IN") {
+				p.inList = sqlComparedColumn(tokens, i-1)
+			}
+			parens = append(parens, p)
+		case t.kind == sqlPunct && t.text == ")" && len(parens) > 0:
+			parens = parens[:len(parens)-1]
+		case t.kind == sqlPunct && t.text == "," && len(parens) > 0:
+			parens[len(parens)-1].pos++
+		case t.kind == sqlWord && strings.EqualFold(t.text, "VALUES"):
+			inValues = columns != nil
+		case t.kind == sqlLiteral:
+			column := literalColumn(tokens, i)
+			if column == "" && len(parens) > 0 {
+				if top := parens[len(parens)-1]; top.inList != "" {
+					column = top.inList
+				}
+				if row := parens[0]; row.values && row.pos < len(columns) {
+					column = columns[row.pos]
+				}
+			}
+			if column != "" && isSensitiveField(unqualifiedSQLName(column), patterns) {
+				sensitive[i] = true
+			}
+		}
+	}
+	return sensitive
+}
+
+// literalColumn returns the column the literal at tokens[i] is compared with
+// or assigned to, as in col = 'x', col LIKE 'x' or 'x' = col.
+func literalColumn(tokens []sqlToken, i int) string {
+	if i >= 2 && isSQLComparison(tokens[i-1]) {
+		if column := sqlComparedColumn(tokens, i-1); column != "" {
+			return column
+		}
+	}
+	if i+2 < len(tokens) && isSQLComparison(tokens[i+1]) && tokens[i+2].kind == sqlWord {
+		return tokens[i+2].text
+	}
+	return ""
+}
+
+// sqlComparedColumn returns the word before the operator at tokens[op],
+// skipping NOT, or "" when it is not a column.
+func sqlComparedColumn(tokens []sqlToken, op int) string {
+	j := op - 1
+	if j > 0 && strings.EqualFold(tokens[j].text, "NOT") {
+		j--
+	}
+	if j < 0 || tokens[j].kind != sqlWord {
+		return ""
+	}
+	return tokens[j].text
+}
+
+func isSQLComparison(t sqlToken) bool {
+	switch strings.ToUpper(t.text) {
+	case "=", "==", "<>", "!=", "<", ">", "<=", ">=", "LIKE", "ILIKE":
+		return true
+	}
+	return false
+}
+
+// insertColumns returns the column list of an INSERT INTO t (a, b) VALUES
+// statement, or nil for other statements.
+func insertColumns(tokens []sqlToken) []string {
+	if len(tokens) < 4 || !strings.EqualFold(tokens[0].text, "INSERT") {
+		return nil
+	}
+	i := 1
+	if strings.EqualFold(tokens[i].text, "INTO") {
+		i++
+	}
+	if i+1 >= len(tokens) || tokens[i].kind != sqlWord || tokens[i+1].text != "(" {
+		return nil
+	}
+	var columns []string
+	for i += 2; i < len(tokens) && tokens[i].text != ")"; i++ {
+		if tokens[i].kind == sqlWord {
+			columns = append(columns, tokens[i].text)
+		}
+	}
+	return columns
+}
+
+// unqualifiedSQLName returns the last part of a qualified identifier without
+// its quotes.
+func unqualifiedSQLName(name string) string {
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+	return strings.Trim(name, "\"`")
+}
+
+// sqlOperation returns the kind of statement: select, insert, update,
+// delete, or other. A WITH query is classified by its main statement.
+func sqlOperation(tokens []sqlToken) string {
+	depth := 0
+	for i, t := range tokens {
+		switch {
+		case t.text == "(":
+			depth++
+		case t.text == ")":
+			depth--
+		case t.kind == sqlWord && (depth == 0 || i == 0):
+			switch op := strings.ToLower(t.text); op {
+			case "select", "insert", "update", "delete":
+				return op
+			case "with", "recursive":
+			default:
+				if i == 0 {
+					return "other"
+				}
+			}
+		}
+	}
+	return "other"
+}
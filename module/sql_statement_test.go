@@ -0,0 +1,66 @@
+package module
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeSQLStatement(t *testing.T) {
+	for _, tc := range []struct {
+		query string
+		want  string
+	}{
+		{"SELECT id FROM orders WHERE id = $1", "SELECT id FROM orders WHERE id = $1"},
+		{"SELECT id\n\t FROM orders -- recent\n WHERE status = 'paid' AND total > 10.5;", "SELECT id FROM orders WHERE status = ? AND total > ?"},
+		{"select * /* hint */ from t where a in (1, 2, -3) and b = ?", "select * from t where a in (?, ?, -?) and b = ?"},
+		{"SELECT 'it''s', E'a\\'b', $$x$$, $tag$y$tag$, x::text FROM t", "SELECT ?, ?, ?, ?, x::text FROM t"},
+		{`SELECT "Orders"."Total", o.id2 FROM "Orders" o WHERE o.v = :name`, `SELECT "Orders"."Total", o.id2 FROM "Orders" o WHERE o.v = :name`},
+		{"UPDATE t SET n = n+1 WHERE id = 7", "UPDATE t SET n = n+? WHERE id = ?"},
+	} {
+		if got := normalizeSQLStatement(tokenizeSQL(tc.query)); got != tc.want {
+			t.Errorf("normalize(%q)\n got %q\nwant %q", tc.query, got, tc.want)
+		}
+	}
+
+	long := "SELECT " + strings.Repeat("a, ", maxSQLStatementLength) + "b FROM t"
+	if got := normalizeSQLStatement(tokenizeSQL(long)); len(got) != maxSQLStatementLength+3 || !strings.HasSuffix(got, "...") {
+		t.Errorf("expected a statement capped at %d bytes, got %d", maxSQLStatementLength, len(got))
+	}
+}
+
+func TestRedactSQLStatement(t *testing.T) {
+	for _, tc := range []struct {
+		query string
+		want  string
+	}{
+		{"SELECT id FROM users WHERE email = 'a@b.c' AND password = 'hunter2'", "SELECT id FROM users WHERE email = 'a@b.c' AND password = '[REDACTED]'"},
+		{"SELECT id FROM users WHERE 'hunter2' = u.password_hash", "SELECT id FROM users WHERE '[REDACTED]' = u.password_hash"},
+		{"DELETE FROM keys WHERE api_key NOT IN ('k1', 'k2') AND id = 3", "DELETE FROM keys WHERE api_key NOT IN ('[REDACTED]', '[REDACTED]') AND id = 3"},
+		{"UPDATE users SET reset_token = 'abc', name = 'Bo' WHERE id = $1", "UPDATE users SET reset_token = '[REDACTED]', name = 'Bo' WHERE id = $1"},
+		{"INSERT INTO users (name, secret, age) VALUES ('Al', 'x', 3), ('Bo', lower('Y'), 4)", "INSERT INTO users (name, secret, age) VALUES ('Al', '[REDACTED]', 3), ('Bo', lower('[REDACTED]'), 4)"},
+		{"SELECT id FROM users WHERE ssn = '123'", "SELECT id FROM users WHERE ssn = '123'"},
+	} {
+		if got := redactSQLStatement(tokenizeSQL(tc.query), SensitiveFieldPatterns); got != tc.want {
+			t.Errorf("redact(%q)\n got %q\nwant %q", tc.query, got, tc.want)
+		}
+	}
+
+	patterns := append([]string{"ssn"}, SensitiveFieldPatterns...)
+	if got := redactSQLStatement(tokenizeSQL("SELECT id FROM users WHERE ssn = '123'"), patterns); got != "SELECT id FROM users WHERE ssn = '[REDACTED]'" {
+		t.Errorf("expected extra pattern to redact, got %q", got)
+	}
+}
+
+func TestSQLOperation(t *testing.T) {
+	for query, want := range map[string]string{
+		"SELECT 1":                   "select",
+		"  insert into t values (1)": "insert",
+		"WITH x AS (SELECT id FROM t) DELETE FROM t":     "delete",
+		"WITH RECURSIVE r AS (SELECT 1) SELECT * FROM r": "select",
+		"CREATE TABLE t (id int)":                        "other",
+	} {
+		if got := sqlOperation(tokenizeSQL(query)); got != want {
+			t.Errorf("sqlOperation(%q) = %q, want %q", query, got, want)
+		}
+	}
+}
@@ -91,11 +91,17 @@ type StateMachineEngine struct {
 	instances         map[string]*WorkflowInstance
 	instancesByType   map[string][]string // workflowType -> []instanceID
 	transitionHandler TransitionHandler
+	commitListeners   []CommitListener
 	mutex             sync.RWMutex
 	persistence       *PersistenceStore // optional write-through backend
 	wg                sync.WaitGroup    // tracks in-flight goroutines
+	goroutines        GoroutineCounter  // counts them for ResourceUsage
 	maxInstances      int               // maximum concurrent workflow instances
 	instanceTTL       time.Duration     // TTL for idle workflow instances
+	// transitionCounts counts recent transitions by workflow type and
+	// transition name for Stats.
+	transitionCounts    map[string]map[string]*transitionCounter
+	lastTransitionPrune time.Time
 }
 
 // NewStateMachineEngine creates a new state machine engine
@@ -186,11 +192,26 @@ func (e *StateMachineEngine) SetPersistence(ps *PersistenceStore) {
 // TrackGoroutine spawns a goroutine tracked by the engine's WaitGroup so
 // that Stop() can drain in-flight work before shutdown.
 func (e *StateMachineEngine) TrackGoroutine(fn func()) {
+	done := e.goroutines.Track()
 	e.wg.Go(func() {
+		defer done()
 		fn()
 	})
 }
 
+// ResourceUsage reports the workflow instances and definitions held in
+// memory and the goroutines started with TrackGoroutine.
+func (e *StateMachineEngine) ResourceUsage() ResourceUsage {
+	e.mutex.RLock()
+	instances, definitions := len(e.instances), len(e.definitions)
+	e.mutex.RUnlock()
+	return ResourceUsage{
+		"instances":        float64(instances),
+		"definitions":      float64(definitions),
+		ResourceGoroutines: float64(e.goroutines.Count()),
+	}
+}
+
 // SetMaxInstances sets the maximum number of concurrent workflow instances.
 func (e *StateMachineEngine) SetMaxInstances(n int) {
 	e.maxInstances = n
@@ -500,6 +521,64 @@ func (e *StateMachineEngine) GetInstancesByType(workflowType string) ([]*Workflo
 	return instances, nil
 }
 
+// ExpiredInstances returns up to limit completed instances of a workflow
+// type last updated before the given time, from memory and persistence.
+func (e *StateMachineEngine) ExpiredInstances(workflowType string, before time.Time, limit int) ([]*WorkflowInstance, error) {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	seen := make(map[string]bool)
+	var out []*WorkflowInstance
+	add := func(inst *WorkflowInstance) {
+		if len(out) >= limit || seen[inst.ID] || !inst.Completed || !inst.LastUpdated.Before(before) {
+			return
+		}
+		seen[inst.ID] = true
+		out = append(out, inst)
+	}
+	for _, id := range e.instancesByType[workflowType] {
+		if inst, ok := e.instances[id]; ok {
+			add(inst)
+		}
+	}
+	if e.persistence != nil && len(out) < limit {
+		persisted, err := e.persistence.LoadWorkflowInstances(workflowType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load instances for %q: %w", workflowType, err)
+		}
+		for _, inst := range persisted {
+			// The in-memory copy is authoritative when both exist.
+			if _, inMemory := e.instances[inst.ID]; !inMemory {
+				add(inst)
+			}
+		}
+	}
+	return out, nil
+}
+
+// DeleteInstance removes a workflow instance from memory and persistence.
+func (e *StateMachineEngine) DeleteInstance(id string) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if inst, ok := e.instances[id]; ok {
+		delete(e.instances, id)
+		ids := e.instancesByType[inst.WorkflowType]
+		for i, other := range ids {
+			if other == id {
+				e.instancesByType[inst.WorkflowType] = append(ids[:i], ids[i+1:]...)
+				break
+			}
+		}
+	}
+	if e.persistence != nil {
+		if err := e.persistence.DeleteWorkflowInstance(id); err != nil {
+			return fmt.Errorf("failed to delete persisted instance %q: %w", id, err)
+		}
+	}
+	return nil
+}
+
 // TriggerTransition attempts to transition a workflow's state
 func (e *StateMachineEngine) TriggerTransition(
 	ctx context.Context,
@@ -581,6 +660,15 @@ func (e *StateMachineEngine) TriggerTransition(
 	if e.persistence != nil {
 		_ = e.persistence.SaveWorkflowInstance(instance)
 	}
+	e.recordTransitionLocked(instance.WorkflowType, event)
+
+	if len(e.commitListeners) > 0 {
+		snapshot := *instance
+		snapshot.Data = maps.Clone(instance.Data)
+		for _, listener := range e.commitListeners {
+			e.TrackGoroutine(func() { listener(snapshot, event) })
+		}
+	}
 
 	// Check for auto-transform transitions from the new state.
 	// If any transition has AutoTransform=true and its FromState matches
@@ -647,6 +735,20 @@ func (e *StateMachineEngine) AddTransitionListener(listener TransitionListener)
 	}
 }
 
+// CommitListener is a function called after a transition has been committed,
+// with a snapshot of the instance in its new state.
+type CommitListener func(instance WorkflowInstance, event TransitionEvent)
+
+// AddCommitListener registers a function to be called after every committed
+// transition. Unlike transition handlers and listeners, which run before the
+// state change and can veto it, commit listeners only see transitions that
+// happened. They run on tracked goroutines so they do not delay the caller.
+func (e *StateMachineEngine) AddCommitListener(listener CommitListener) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.commitListeners = append(e.commitListeners, listener)
+}
+
 // GetTransitionHandler returns the current transition handler
 func (e *StateMachineEngine) GetTransitionHandler() TransitionHandler {
 	e.mutex.RLock()
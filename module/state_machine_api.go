@@ -0,0 +1,163 @@
+package module
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// StateMachineAPIServiceName is the service name the statemachine plugin
+// registers its StateMachineAPIHandler under.
+const StateMachineAPIServiceName = "statemachine.api"
+
+// Page sizes of GET /api/v1/admin/statemachine/instances.
+const (
+	defaultStateMachineInstancePage = 50
+	maxStateMachineInstancePage     = 500
+)
+
+// StateMachineAPIHandler serves the data the admin UI needs to draw state
+// machine diagrams: the parsed definitions, live instance counts per state
+// and instance listings. The engines function is called on every request so
+// the handler follows engine reloads.
+type StateMachineAPIHandler struct {
+	engines func() []*StateMachineEngine
+	mux     *http.ServeMux
+}
+
+// NewStateMachineAPIHandler creates a handler over the engines returned by
+// engines.
+func NewStateMachineAPIHandler(engines func() []*StateMachineEngine) *StateMachineAPIHandler {
+	h := &StateMachineAPIHandler{engines: engines, mux: http.NewServeMux()}
+	h.RegisterRoutes(h.mux)
+	return h
+}
+
+// RegisterRoutes registers the state machine API routes on the given mux.
+func (h *StateMachineAPIHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/admin/statemachine/definitions", h.handleDefinitions)
+	mux.HandleFunc("GET /api/v1/admin/statemachine/definitions/{type}/stats", h.handleStats)
+	mux.HandleFunc("GET /api/v1/admin/statemachine/instances", h.handleInstances)
+}
+
+// ServeHTTP implements http.Handler for delegate dispatch. The delegate step
+// passes the full original path, which the routes match.
+func (h *StateMachineAPIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// stateMachineDefinitionView is a definition with the engine it belongs to.
+type stateMachineDefinitionView struct {
+	Engine string `json:"engine"`
+	*StateMachineDefinition
+}
+
+func (h *StateMachineAPIHandler) handleDefinitions(w http.ResponseWriter, _ *http.Request) {
+	views := []stateMachineDefinitionView{}
+	for _, engine := range h.engines() {
+		for _, def := range engine.Definitions() {
+			views = append(views, stateMachineDefinitionView{Engine: engine.Name(), StateMachineDefinition: def})
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"definitions": views, "total": len(views)})
+}
+
+func (h *StateMachineAPIHandler) handleStats(w http.ResponseWriter, r *http.Request) {
+	workflowType := r.PathValue("type")
+	engine := h.engineFor(workflowType)
+	if engine == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "workflow type not found"})
+		return
+	}
+	stats, err := engine.Stats(workflowType, time.Now())
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// handleInstances lists instances filtered by ?type= and ?state=, paginated
+// by ?limit= (default 50, at most 500) and ?offset=.
+func (h *StateMachineAPIHandler) handleInstances(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := StateMachineInstanceFilter{
+		WorkflowType: q.Get("type"),
+		State:        q.Get("state"),
+		Limit:        defaultStateMachineInstancePage,
+	}
+	for _, p := range []struct {
+		key string
+		dst *int
+		min int
+	}{{"limit", &filter.Limit, 1}, {"offset", &filter.Offset, 0}} {
+		v := q.Get(p.key)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < p.min {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": p.key + " must be an integer of at least " + strconv.Itoa(p.min)})
+			return
+		}
+		*p.dst = n
+	}
+	filter.Limit = min(filter.Limit, maxStateMachineInstancePage)
+
+	engines := h.engines()
+	if filter.WorkflowType != "" {
+		engine := h.engineFor(filter.WorkflowType)
+		if engine == nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "workflow type not found"})
+			return
+		}
+		engines = []*StateMachineEngine{engine}
+	}
+
+	var instances []*WorkflowInstance
+	var total int
+	if len(engines) == 1 {
+		var err error
+		if instances, total, err = engines[0].ListInstances(filter); err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+	} else {
+		// Merge the first offset+limit instances of every engine, then
+		// take the requested page.
+		perEngine := filter
+		perEngine.Offset, perEngine.Limit = 0, filter.Offset+filter.Limit
+		for _, engine := range engines {
+			page, n, err := engine.ListInstances(perEngine)
+			if err != nil {
+				writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+				return
+			}
+			instances = append(instances, page...)
+			total += n
+		}
+		sortInstancesByUpdate(instances)
+		start := min(filter.Offset, len(instances))
+		instances = instances[start:min(start+filter.Limit, len(instances))]
+	}
+	if instances == nil {
+		instances = []*WorkflowInstance{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"instances": instances,
+		"total":     total,
+		"limit":     filter.Limit,
+		"offset":    filter.Offset,
+	})
+}
+
+// engineFor returns the engine defining a workflow type, or nil.
+func (h *StateMachineAPIHandler) engineFor(workflowType string) *StateMachineEngine {
+	for _, engine := range h.engines() {
+		if engine.HasDefinition(workflowType) {
+			return engine
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package module
+
+import (
+	"context"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/store"
+)
+
+// StateMachineRetentionSource expires completed state machine instances for
+// retention enforcement. A workflow owns the instances of the state machine
+// definitions it declares. Engines is called on every query so the source
+// follows engine reloads.
+type StateMachineRetentionSource struct {
+	Engines func() []*StateMachineEngine
+}
+
+// Expired implements store.RetentionSource.
+func (s *StateMachineRetentionSource) Expired(_ context.Context, scope store.RetentionScope, before time.Time, limit int) ([]store.RetentionRecord, error) {
+	var out []store.RetentionRecord
+	for _, engine := range s.Engines() {
+		for _, def := range scope.StateMachines {
+			instances, err := engine.ExpiredInstances(def, before, limit-len(out))
+			if err != nil {
+				return nil, err
+			}
+			for _, inst := range instances {
+				out = append(out, store.RetentionRecord{ID: inst.ID, Data: inst})
+			}
+			if len(out) >= limit {
+				return out, nil
+			}
+		}
+	}
+	return out, nil
+}
+
+// Delete implements store.RetentionSource. Instance IDs are unique, so the
+// instance is removed from whichever engine holds it.
+func (s *StateMachineRetentionSource) Delete(_ context.Context, rec store.RetentionRecord) (int64, error) {
+	for _, engine := range s.Engines() {
+		if err := engine.DeleteInstance(rec.ID); err != nil {
+			return 0, err
+		}
+	}
+	return 1, nil
+}
@@ -0,0 +1,85 @@
+package module
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/store"
+)
+
+func TestStateMachineRetentionSource(t *testing.T) {
+	engine := NewStateMachineEngine("sm")
+	ps := newTestPersistenceStore(t)
+	engine.SetPersistence(ps)
+	for _, name := range []string{"order", "invoice"} {
+		if err := engine.RegisterDefinition(&StateMachineDefinition{
+			Name:         name,
+			InitialState: "new",
+			States:       map[string]*State{"new": {Name: "new"}, "done": {Name: "done", IsFinal: true}},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	old := time.Now().Add(-48 * time.Hour)
+	for _, tc := range []struct {
+		id, def   string
+		completed bool
+		updated   time.Time
+	}{
+		{"o-done-old", "order", true, old},
+		{"o-open-old", "order", false, old},
+		{"o-done-new", "order", true, time.Now()},
+		{"i-done-old", "invoice", true, old},
+	} {
+		inst, err := engine.CreateWorkflow(tc.def, tc.id, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		inst.Completed = tc.completed
+		inst.LastUpdated = tc.updated
+		if err := ps.SaveWorkflowInstance(inst); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// An instance that only exists in persistence (not loaded into memory).
+	if err := ps.SaveWorkflowInstance(&WorkflowInstance{
+		ID: "o-persisted-old", WorkflowType: "order", CurrentState: "done",
+		StartTime: old, LastUpdated: old, Completed: true, Data: map[string]any{},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	src := &StateMachineRetentionSource{Engines: func() []*StateMachineEngine { return []*StateMachineEngine{engine} }}
+	scope := store.RetentionScope{Workflow: "orders", StateMachines: []string{"order"}}
+	ctx := context.Background()
+
+	recs, err := src.Expired(ctx, scope, time.Now().Add(-24*time.Hour), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]bool{}
+	for _, r := range recs {
+		got[r.ID] = true
+	}
+	if len(got) != 2 || !got["o-done-old"] || !got["o-persisted-old"] {
+		t.Fatalf("expired instances = %v, want o-done-old and o-persisted-old", got)
+	}
+
+	for _, r := range recs {
+		if _, err := src.Delete(ctx, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := engine.GetInstance("o-done-old"); err == nil {
+		t.Error("deleted instance is still in memory")
+	}
+	persisted, _ := ps.LoadWorkflowInstances("order")
+	if len(persisted) != 2 {
+		t.Errorf("persisted order instances = %d, want 2 (open and recent)", len(persisted))
+	}
+	if _, err := engine.GetInstance("i-done-old"); err != nil {
+		t.Error("instance of a definition outside the scope was deleted")
+	}
+}
@@ -0,0 +1,267 @@
+package module
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+)
+
+// StateMachineStatsWindow is the period transition counts are reported for.
+const StateMachineStatsWindow = 24 * time.Hour
+
+// transitionLogPruneInterval is how often the persisted transition log is
+// pruned of entries older than StateMachineStatsWindow.
+const transitionLogPruneInterval = time.Hour
+
+// transitionCounterBuckets is the number of one-minute buckets a
+// transitionCounter keeps: one per minute of StateMachineStatsWindow.
+const transitionCounterBuckets = int64(StateMachineStatsWindow / time.Minute)
+
+// StateMachineStateStats counts the instances in one state. OldestSince is
+// the earliest time an instance still in the state was last updated, i.e.
+// how long the longest-waiting instance has been there.
+type StateMachineStateStats struct {
+	State            string     `json:"state"`
+	Count            int64      `json:"count"`
+	OldestSince      *time.Time `json:"oldestSince,omitempty"`
+	OldestAgeSeconds float64    `json:"oldestAgeSeconds"`
+}
+
+// StateMachineTransitionStats counts the times a transition fired within
+// StateMachineStatsWindow.
+type StateMachineTransitionStats struct {
+	Name      string `json:"name"`
+	FromState string `json:"fromState,omitempty"`
+	ToState   string `json:"toState,omitempty"`
+	Count     int64  `json:"count"`
+}
+
+// StateMachineStats is the instance distribution of a workflow type. Source
+// is "persistence" when the counts come from the persisted instance store and
+// "memory" when they come from the engine's in-memory instances.
+type StateMachineStats struct {
+	WorkflowType string                        `json:"workflowType"`
+	Total        int64                         `json:"total"`
+	States       []StateMachineStateStats      `json:"states"`
+	Transitions  []StateMachineTransitionStats `json:"transitions"`
+	Window       string                        `json:"window"`
+	Source       string                        `json:"source"`
+}
+
+// StateMachineInstanceFilter selects a page of workflow instances. Empty
+// fields match everything.
+type StateMachineInstanceFilter struct {
+	WorkflowType string
+	State        string
+	Limit        int
+	Offset       int
+}
+
+// transitionCounter counts transitions in one-minute buckets over the last
+// StateMachineStatsWindow. Each bucket remembers which minute it counts, so
+// stale buckets are reset on reuse and skipped when summing.
+type transitionCounter struct {
+	minutes [transitionCounterBuckets]int64
+	counts  [transitionCounterBuckets]int64
+}
+
+func (c *transitionCounter) add(t time.Time) {
+	minute := t.Unix() / 60
+	i := minute % transitionCounterBuckets
+	if c.minutes[i] != minute {
+		c.minutes[i] = minute
+		c.counts[i] = 0
+	}
+	c.counts[i]++
+}
+
+func (c *transitionCounter) total(now time.Time) int64 {
+	current := now.Unix() / 60
+	var n int64
+	for i, minute := range c.minutes {
+		if minute > current-transitionCounterBuckets && minute <= current {
+			n += c.counts[i]
+		}
+	}
+	return n
+}
+
+// recordTransitionLocked counts a committed transition and appends it to the
+// persisted transition log. The caller holds e.mutex.
+func (e *StateMachineEngine) recordTransitionLocked(workflowType string, event TransitionEvent) {
+	if e.transitionCounts == nil {
+		e.transitionCounts = make(map[string]map[string]*transitionCounter)
+	}
+	byName := e.transitionCounts[workflowType]
+	if byName == nil {
+		byName = make(map[string]*transitionCounter)
+		e.transitionCounts[workflowType] = byName
+	}
+	counter := byName[event.TransitionID]
+	if counter == nil {
+		counter = &transitionCounter{}
+		byName[event.TransitionID] = counter
+	}
+	counter.add(event.Timestamp)
+
+	if e.persistence == nil {
+		return
+	}
+	_ = e.persistence.SaveWorkflowTransition(workflowType, event)
+	if event.Timestamp.Sub(e.lastTransitionPrune) >= transitionLogPruneInterval {
+		e.lastTransitionPrune = event.Timestamp
+		ps, before := e.persistence, event.Timestamp.Add(-StateMachineStatsWindow)
+		e.TrackGoroutine(func() { _, _ = ps.PruneWorkflowTransitions(before) })
+	}
+}
+
+// Definitions returns the registered state machine definitions sorted by name.
+func (e *StateMachineEngine) Definitions() []*StateMachineDefinition {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return slices.SortedFunc(maps.Values(e.definitions), func(a, b *StateMachineDefinition) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+}
+
+// HasDefinition reports whether a workflow type is registered.
+func (e *StateMachineEngine) HasDefinition(workflowType string) bool {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	_, ok := e.definitions[workflowType]
+	return ok
+}
+
+// Stats returns the number of instances of a workflow type in each state, how
+// long the oldest has been there, and how often each transition fired within
+// StateMachineStatsWindow. With persistence the counts are aggregated by the
+// instance store's indexes and cover instances not loaded in memory.
+func (e *StateMachineEngine) Stats(workflowType string, now time.Time) (*StateMachineStats, error) {
+	e.mutex.RLock()
+	def, ok := e.definitions[workflowType]
+	if !ok {
+		e.mutex.RUnlock()
+		return nil, fmt.Errorf("workflow type '%s' not found", workflowType)
+	}
+	ps := e.persistence
+	var states []StateMachineStateStats
+	transitions := make(map[string]int64)
+	if ps == nil {
+		byState := make(map[string]*StateMachineStateStats)
+		for _, id := range e.instancesByType[workflowType] {
+			inst, ok := e.instances[id]
+			if !ok {
+				continue
+			}
+			st := byState[inst.CurrentState]
+			if st == nil {
+				st = &StateMachineStateStats{State: inst.CurrentState}
+				byState[inst.CurrentState] = st
+			}
+			st.Count++
+			if st.OldestSince == nil || inst.LastUpdated.Before(*st.OldestSince) {
+				updated := inst.LastUpdated
+				st.OldestSince = &updated
+			}
+		}
+		for _, st := range byState {
+			states = append(states, *st)
+		}
+		for name, counter := range e.transitionCounts[workflowType] {
+			transitions[name] = counter.total(now)
+		}
+	}
+	e.mutex.RUnlock()
+
+	stats := &StateMachineStats{WorkflowType: workflowType, Window: StateMachineStatsWindow.String(), Source: "memory"}
+	if ps != nil {
+		stats.Source = "persistence"
+		var err error
+		if states, err = ps.WorkflowInstanceStateCounts(workflowType); err != nil {
+			return nil, fmt.Errorf("failed to count instances of %q: %w", workflowType, err)
+		}
+		if transitions, err = ps.WorkflowTransitionCounts(workflowType, now.Add(-StateMachineStatsWindow)); err != nil {
+			return nil, fmt.Errorf("failed to count transitions of %q: %w", workflowType, err)
+		}
+	}
+
+	// Every defined state is reported, followed by any states instances are
+	// in that the definition no longer has.
+	counted := make(map[string]StateMachineStateStats, len(states))
+	for _, st := range states {
+		counted[st.State] = st
+		stats.Total += st.Count
+	}
+	for _, name := range slices.Sorted(maps.Keys(def.States)) {
+		st, ok := counted[name]
+		if !ok {
+			st = StateMachineStateStats{State: name}
+		}
+		delete(counted, name)
+		stats.States = append(stats.States, st)
+	}
+	for _, name := range slices.Sorted(maps.Keys(counted)) {
+		stats.States = append(stats.States, counted[name])
+	}
+	for i := range stats.States {
+		if since := stats.States[i].OldestSince; since != nil {
+			stats.States[i].OldestAgeSeconds = now.Sub(*since).Seconds()
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(def.Transitions)) {
+		t := def.Transitions[name]
+		stats.Transitions = append(stats.Transitions, StateMachineTransitionStats{
+			Name: name, FromState: t.FromState, ToState: t.ToState, Count: transitions[name],
+		})
+		delete(transitions, name)
+	}
+	for _, name := range slices.Sorted(maps.Keys(transitions)) {
+		stats.Transitions = append(stats.Transitions, StateMachineTransitionStats{Name: name, Count: transitions[name]})
+	}
+	return stats, nil
+}
+
+// ListInstances returns one page of the instances matching filter, most
+// recently updated first, and the number of matching instances. With
+// persistence the page is read from the instance store; otherwise it is
+// taken from the in-memory instances. Returned instances are snapshots.
+func (e *StateMachineEngine) ListInstances(filter StateMachineInstanceFilter) ([]*WorkflowInstance, int, error) {
+	if e.persistence != nil {
+		return e.persistence.ListWorkflowInstances(filter)
+	}
+
+	e.mutex.RLock()
+	var matched []*WorkflowInstance
+	for _, inst := range e.instances {
+		if (filter.WorkflowType == "" || inst.WorkflowType == filter.WorkflowType) &&
+			(filter.State == "" || inst.CurrentState == filter.State) {
+			snapshot := *inst
+			snapshot.Data = maps.Clone(inst.Data)
+			matched = append(matched, &snapshot)
+		}
+	}
+	e.mutex.RUnlock()
+
+	sortInstancesByUpdate(matched)
+	total := len(matched)
+	start := min(filter.Offset, total)
+	end := total
+	if filter.Limit > 0 {
+		end = min(start+filter.Limit, total)
+	}
+	return matched[start:end], total, nil
+}
+
+// sortInstancesByUpdate sorts instances most recently updated first, then
+// by ID.
+func sortInstancesByUpdate(instances []*WorkflowInstance) {
+	slices.SortFunc(instances, func(a, b *WorkflowInstance) int {
+		if c := b.LastUpdated.Compare(a.LastUpdated); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+}
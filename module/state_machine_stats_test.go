@@ -0,0 +1,226 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newOrderStateMachine(t *testing.T, ps *PersistenceStore) *StateMachineEngine {
+	t.Helper()
+	engine := NewStateMachineEngine("sm")
+	if ps != nil {
+		engine.SetPersistence(ps)
+	}
+	if err := engine.RegisterDefinition(&StateMachineDefinition{
+		Name:         "order",
+		InitialState: "new",
+		States: map[string]*State{
+			"new":      {Name: "new"},
+			"paid":     {Name: "paid"},
+			"shipped":  {Name: "shipped", IsFinal: true},
+			"rejected": {Name: "rejected", IsFinal: true, IsError: true},
+		},
+		Transitions: map[string]*Transition{
+			"pay":    {Name: "pay", FromState: "new", ToState: "paid"},
+			"ship":   {Name: "ship", FromState: "paid", ToState: "shipped"},
+			"reject": {Name: "reject", FromState: "new", ToState: "rejected"},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for _, id := range []string{"o1", "o2", "o3", "o4"} {
+		if _, err := engine.CreateWorkflow("order", id, map[string]any{"id": id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, step := range []struct{ id, transition string }{
+		{"o1", "pay"}, {"o1", "ship"}, {"o2", "pay"}, {"o3", "reject"},
+	} {
+		if err := engine.TriggerTransition(ctx, step.id, step.transition, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return engine
+}
+
+func checkOrderStats(t *testing.T, stats *StateMachineStats, source string) {
+	t.Helper()
+	if stats.Source != source || stats.Total != 4 || stats.Window != "24h0m0s" {
+		t.Fatalf("stats = %+v", stats)
+	}
+	counts := map[string]int64{}
+	for _, st := range stats.States {
+		counts[st.State] = st.Count
+		if st.Count > 0 && (st.OldestSince == nil || st.OldestAgeSeconds < 0) {
+			t.Errorf("state %s: missing oldest age: %+v", st.State, st)
+		}
+	}
+	want := map[string]int64{"new": 1, "paid": 1, "shipped": 1, "rejected": 1}
+	if len(stats.States) != len(want) {
+		t.Errorf("states = %+v, want one entry per defined state", stats.States)
+	}
+	for state, n := range want {
+		if counts[state] != n {
+			t.Errorf("count[%s] = %d, want %d", state, counts[state], n)
+		}
+	}
+	transitions := map[string]int64{}
+	for _, tr := range stats.Transitions {
+		transitions[tr.Name] = tr.Count
+	}
+	if transitions["pay"] != 2 || transitions["ship"] != 1 || transitions["reject"] != 1 {
+		t.Errorf("transitions = %+v", stats.Transitions)
+	}
+}
+
+func TestStateMachineStats_Memory(t *testing.T) {
+	engine := newOrderStateMachine(t, nil)
+	stats, err := engine.Stats("order", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkOrderStats(t, stats, "memory")
+
+	// Transitions older than the window are no longer counted.
+	stats, err = engine.Stats("order", time.Now().Add(StateMachineStatsWindow+time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tr := range stats.Transitions {
+		if tr.Count != 0 {
+			t.Errorf("transition %s counted outside the window: %d", tr.Name, tr.Count)
+		}
+	}
+
+	if _, err := engine.Stats("missing", time.Now()); err == nil {
+		t.Error("expected an error for an unknown workflow type")
+	}
+}
+
+func TestStateMachineStats_Persistence(t *testing.T) {
+	ps := newTestPersistenceStore(t)
+	engine := newOrderStateMachine(t, ps)
+
+	// Instances only in the store are counted too.
+	old := time.Now().Add(-2 * time.Hour)
+	if err := ps.SaveWorkflowInstance(&WorkflowInstance{
+		ID: "o-old", WorkflowType: "order", CurrentState: "archived",
+		StartTime: old, LastUpdated: old, Data: map[string]any{},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	stats, err := engine.Stats("order", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Total != 5 {
+		t.Fatalf("total = %d, want 5", stats.Total)
+	}
+	last := stats.States[len(stats.States)-1]
+	if last.State != "archived" || last.Count != 1 || last.OldestAgeSeconds < 7000 {
+		t.Errorf("undefined state should be reported last with its age: %+v", last)
+	}
+	stats.States = stats.States[:len(stats.States)-1]
+	stats.Total--
+	checkOrderStats(t, stats, "persistence")
+
+	if n, err := ps.PruneWorkflowTransitions(time.Now().Add(time.Minute)); err != nil || n != 4 {
+		t.Errorf("pruned %d transitions (err %v), want 4", n, err)
+	}
+}
+
+func TestStateMachineListInstances(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		ps   func(t *testing.T) *PersistenceStore
+	}{
+		{"memory", func(*testing.T) *PersistenceStore { return nil }},
+		{"persistence", newTestPersistenceStore},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			engine := newOrderStateMachine(t, tc.ps(t))
+
+			page, total, err := engine.ListInstances(StateMachineInstanceFilter{WorkflowType: "order", Limit: 2})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != 4 || len(page) != 2 {
+				t.Fatalf("got %d of %d instances, want 2 of 4", len(page), total)
+			}
+			if page[0].LastUpdated.Before(page[1].LastUpdated) {
+				t.Error("instances should be listed most recently updated first")
+			}
+			rest, _, err := engine.ListInstances(StateMachineInstanceFilter{WorkflowType: "order", Limit: 2, Offset: 2})
+			if err != nil {
+				t.Fatal(err)
+			}
+			seen := map[string]bool{}
+			for _, inst := range append(page, rest...) {
+				seen[inst.ID] = true
+			}
+			if len(seen) != 4 {
+				t.Errorf("pages overlap or miss instances: %v", seen)
+			}
+
+			page, total, err = engine.ListInstances(StateMachineInstanceFilter{State: "paid", Limit: 10})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != 1 || len(page) != 1 || page[0].ID != "o2" || page[0].Data["id"] != "o2" {
+				t.Errorf("state filter: got %d instances %+v", total, page)
+			}
+		})
+	}
+}
+
+func TestStateMachineAPIHandler(t *testing.T) {
+	engine := newOrderStateMachine(t, nil)
+	h := NewStateMachineAPIHandler(func() []*StateMachineEngine { return []*StateMachineEngine{engine} })
+	get := func(path string) (int, map[string]any) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		var body map[string]any
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%s: %v: %s", path, err, rec.Body.String())
+		}
+		return rec.Code, body
+	}
+
+	code, body := get("/api/v1/admin/statemachine/definitions")
+	if code != http.StatusOK || body["total"] != float64(1) {
+		t.Fatalf("definitions: %d %v", code, body)
+	}
+	def := body["definitions"].([]any)[0].(map[string]any)
+	if def["engine"] != "sm" || def["initialState"] != "new" {
+		t.Errorf("definition = %v", def)
+	}
+	if rejected := def["states"].(map[string]any)["rejected"].(map[string]any); rejected["isError"] != true || rejected["isFinal"] != true {
+		t.Errorf("rejected state flags = %v", rejected)
+	}
+
+	code, body = get("/api/v1/admin/statemachine/definitions/order/stats")
+	if code != http.StatusOK || body["total"] != float64(4) || len(body["states"].([]any)) != 4 {
+		t.Errorf("stats: %d %v", code, body)
+	}
+	if code, _ = get("/api/v1/admin/statemachine/definitions/missing/stats"); code != http.StatusNotFound {
+		t.Errorf("stats of unknown type: %d, want 404", code)
+	}
+
+	code, body = get("/api/v1/admin/statemachine/instances?type=order&state=new")
+	if code != http.StatusOK || body["total"] != float64(1) || body["limit"] != float64(defaultStateMachineInstancePage) {
+		t.Errorf("instances: %d %v", code, body)
+	}
+	code, body = get("/api/v1/admin/statemachine/instances?limit=100000&offset=3")
+	if code != http.StatusOK || body["limit"] != float64(maxStateMachineInstancePage) || len(body["instances"].([]any)) != 1 {
+		t.Errorf("instances page: %d %v", code, body)
+	}
+	if code, _ = get("/api/v1/admin/statemachine/instances?limit=0"); code != http.StatusBadRequest {
+		t.Errorf("limit=0: %d, want 400", code)
+	}
+}
@@ -11,6 +11,7 @@ var SensitiveFieldPatterns = []string{
 	"credential",
 	"authorization",
 	"cookie",
+	"session_id",
 	"signature",
 	"request_body",
 	"raw_body",
@@ -100,6 +100,13 @@ func (s *SQLiteStorage) DB() *sql.DB {
 	return s.db
 }
 
+// ResourceUsage reports the connection pool once the database is open.
+func (s *SQLiteStorage) ResourceUsage() ResourceUsage {
+	usage := ResourceUsage{}
+	addDBPoolUsage(usage, s.db)
+	return usage
+}
+
 // DriverName returns "sqlite3" for placeholder normalization.
 func (s *SQLiteStorage) DriverName() string {
 	return "sqlite3"
@@ -1,5 +1,11 @@
 package module
 
+import (
+	"fmt"
+
+	"github.com/GoCodeAlone/workflow/pipeline"
+)
+
 // TemplateFuncDef describes a single template function available in pipeline templates.
 type TemplateFuncDef struct {
 	Name        string `json:"name"`
@@ -8,9 +14,19 @@ type TemplateFuncDef struct {
 	Example     string `json:"example"`
 }
 
-// TemplateFuncDescriptions returns descriptions for all built-in pipeline template functions.
+// TemplateFuncDescriptions returns descriptions for all built-in pipeline template
+// functions, followed by the functions registered by plugins.
 func TemplateFuncDescriptions() []TemplateFuncDef {
-	return buildTemplateFuncDefs()
+	defs := buildTemplateFuncDefs()
+	for _, f := range pipeline.PluginTemplateFuncs() {
+		defs = append(defs, TemplateFuncDef{
+			Name:        f.Name,
+			Signature:   f.Signature,
+			Description: fmt.Sprintf("Registered by plugin %q.", f.Owner),
+			Example:     "{{ " + f.Name + " }}",
+		})
+	}
+	return defs
 }
 
 func buildTemplateFuncDefs() []TemplateFuncDef {
@@ -123,6 +139,24 @@ func buildTemplateFuncDefs() []TemplateFuncDef {
 			Description: "Removes leading and trailing whitespace from a string.",
 			Example:     `{{ .input | trimSpace }}`,
 		},
+		{
+			Name:        "trim",
+			Signature:   "trim(s string) string",
+			Description: "Removes leading and trailing whitespace. Alias for trimSpace.",
+			Example:     `{{ trim .name }}`,
+		},
+		{
+			Name:        "quote",
+			Signature:   "quote(v any) string",
+			Description: "Renders a value as a JSON string literal, quotes included, so that user-controlled values can be embedded in JSON bodies safely.",
+			Example:     `{"name": {{ quote .body.name }}}`,
+		},
+		{
+			Name:        "sha256",
+			Signature:   "sha256(s string) string",
+			Description: "Returns the hex SHA-256 digest of a string.",
+			Example:     `{{ sha256 .body.email }}`,
+		},
 		{
 			Name:        "urlEncode",
 			Signature:   "urlEncode(s string) string",
@@ -135,6 +169,36 @@ func buildTemplateFuncDefs() []TemplateFuncDef {
 			Description: "Encodes a string as standard base64 (RFC 4648). Typical use: HTTP Basic auth header from an id:secret pair.",
 			Example:     `Basic {{ b64 (printf "%s:%s" .client_id .client_secret) }}`,
 		},
+		{
+			Name:        "b64enc",
+			Signature:   "b64enc(s string) string",
+			Description: "Encodes a string as standard base64. Alias for b64.",
+			Example:     `{{ b64enc .payload }}`,
+		},
+		{
+			Name:        "b64dec",
+			Signature:   "b64dec(s string) (string, error)",
+			Description: "Decodes a standard base64 string, padded or unpadded. Fails the template on invalid input.",
+			Example:     `{{ b64dec .body.data }}`,
+		},
+		{
+			Name:        "env",
+			Signature:   "env(name string) string",
+			Description: "Returns the value of an environment variable, or an empty string when it is unset.",
+			Example:     `{{ default "us-east-1" (env "AWS_REGION") }}`,
+		},
+		{
+			Name:        "formatTime",
+			Signature:   "formatTime(layout string, t any) (string, error)",
+			Description: "Formats a time.Time, RFC 3339 string or Unix seconds with a Go time layout or named constant (e.g. RFC3339, DateOnly).",
+			Example:     `{{ formatTime "DateOnly" .created_at }}`,
+		},
+		{
+			Name:        "formatDate",
+			Signature:   "formatDate(layout string, t any) (string, error)",
+			Description: "Formats a time.Time, RFC 3339 string or Unix seconds with a Go time layout or named constant. Alias for formatTime.",
+			Example:     `{{ formatDate "2006-01-02" .created_at }}`,
+		},
 		{
 			Name:        "add",
 			Signature:   "add(a any, b any) any",
@@ -261,5 +325,17 @@ func buildTemplateFuncDefs() []TemplateFuncDef {
 			Description: "Accesses trigger data by nested keys. Returns nil if keys do not exist. Context-bound: only available during pipeline execution.",
 			Example:     `{{ trigger "path_params" "id" }}`,
 		},
+		{
+			Name:        "t",
+			Signature:   "t(key string, args ...any) string",
+			Description: "Translates a message key from the locales: catalogs into the request's locale (meta.locale), falling back through parent locales to the default. Placeholder values are a map or name/value pairs; a \"count\" value selects the CLDR plural form. A key without a message renders as the key, or fails in strict mode. Context-bound: only available during pipeline execution.",
+			Example:     `{{ t "cart.items" "count" .count }} or {{ t "greeting" .args }}`,
+		},
+		{
+			Name:        "hmac",
+			Signature:   "hmac(algorithm string, provider string, key string, message string) (string, error)",
+			Description: "Returns the hex HMAC (sha1, sha256 or sha512) of a message, keyed with the secret key of the named secrets provider module. Secrets are never written inline. Context-bound: only available during pipeline execution.",
+			Example:     `{{ hmac "sha256" "partner-secrets" "signing_key" .raw_body }}`,
+		},
 	}
 }
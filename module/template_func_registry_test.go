@@ -5,14 +5,14 @@ import (
 )
 
 // TestTemplateFuncDescriptionsCoversFuncMap verifies that every key in templateFuncMap()
-// has a matching TemplateFuncDef entry, and vice versa (with exception for step/trigger
+// has a matching TemplateFuncDef entry, and vice versa (with exception for step/trigger/t/hmac
 // which are context-bound and not in templateFuncMap).
 func TestTemplateFuncDescriptionsCoversFuncMap(t *testing.T) {
 	funcMap := templateFuncMap()
 	defs := TemplateFuncDescriptions()
 
-	// Build a set of def names (excluding context-bound step/trigger).
-	contextBound := map[string]bool{"step": true, "trigger": true}
+	// Build a set of def names (excluding context-bound step/trigger/t/hmac).
+	contextBound := map[string]bool{"step": true, "trigger": true, "t": true, "hmac": true}
 	defNames := make(map[string]bool, len(defs))
 	for _, d := range defs {
 		if !contextBound[d.Name] {
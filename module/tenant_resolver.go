@@ -8,6 +8,7 @@ import (
 	"net/http"
 
 	"github.com/GoCodeAlone/workflow/interfaces"
+	"github.com/GoCodeAlone/workflow/tenant"
 )
 
 // tenantContextKeyType is the context key type for the resolved tenant.
@@ -15,8 +16,13 @@ type tenantContextKeyType struct{}
 
 var tenantContextKey = tenantContextKeyType{}
 
-// WithTenant stores t in ctx and returns the updated context.
+// WithTenant stores t in ctx and returns the updated context. The tenant ID
+// is also stored for tenant.TenantFromContext, which scopes execution
+// events and the timeline API.
 func WithTenant(ctx context.Context, t interfaces.Tenant) context.Context {
+	if !t.IsZero() {
+		ctx = tenant.ContextWithTenant(ctx, t.ID)
+	}
 	return context.WithValue(ctx, tenantContextKey, t)
 }
 
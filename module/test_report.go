@@ -0,0 +1,341 @@
+package module
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Test report formats understood by ParseTestReport.
+const (
+	TestReportFormatJUnit      = "junit"
+	TestReportFormatGoTestJSON = "go-test-json"
+)
+
+// maxTestFailureMessage bounds the message kept per failed test.
+const maxTestFailureMessage = 4096
+
+// TestReport summarizes the results of a test run.
+type TestReport struct {
+	Format   string            `json:"format"`
+	Summary  TestSummary       `json:"summary"`
+	Suites   []TestSuiteResult `json:"suites"`
+	Failures []TestFailure     `json:"failures"`
+}
+
+// TestSummary counts test outcomes. Failed includes tests that errored.
+type TestSummary struct {
+	Total           int     `json:"total"`
+	Passed          int     `json:"passed"`
+	Failed          int     `json:"failed"`
+	Skipped         int     `json:"skipped"`
+	DurationSeconds float64 `json:"duration_seconds"`
+}
+
+// TestSuiteResult is the summary of one JUnit suite or Go package.
+type TestSuiteResult struct {
+	Name string `json:"name"`
+	TestSummary
+}
+
+// TestFailure describes one failed test. For a Go package that failed
+// without a failing test, such as a build failure, Test is empty.
+type TestFailure struct {
+	Suite           string  `json:"suite"`
+	Test            string  `json:"test,omitempty"`
+	Message         string  `json:"message,omitempty"`
+	DurationSeconds float64 `json:"duration_seconds"`
+}
+
+// DetectTestReportFormat guesses the format of data: XML is JUnit,
+// anything else go test -json.
+func DetectTestReportFormat(data []byte) string {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("<")) {
+		return TestReportFormatJUnit
+	}
+	return TestReportFormatGoTestJSON
+}
+
+// ParseTestReport parses test results in the given format. An empty format
+// is detected from the content.
+func ParseTestReport(format string, data []byte) (*TestReport, error) {
+	if format == "" || format == "auto" {
+		format = DetectTestReportFormat(data)
+	}
+	var report *TestReport
+	var err error
+	switch format {
+	case TestReportFormatJUnit:
+		report, err = parseJUnitReport(data)
+	case TestReportFormatGoTestJSON:
+		report, err = parseGoTestJSON(bytes.NewReader(data))
+	default:
+		return nil, fmt.Errorf("unsupported test report format %q (supported: %s, %s)", format, TestReportFormatJUnit, TestReportFormatGoTestJSON)
+	}
+	if err != nil {
+		return nil, err
+	}
+	report.Format = format
+	return report, nil
+}
+
+// Merge adds the suites and failures of other to r, for runs split across
+// several result files.
+func (r *TestReport) Merge(other *TestReport) {
+	r.Suites = append(r.Suites, other.Suites...)
+	r.Failures = append(r.Failures, other.Failures...)
+	r.Summary.add(other.Summary)
+}
+
+func (s *TestSummary) add(o TestSummary) {
+	s.Total += o.Total
+	s.Passed += o.Passed
+	s.Failed += o.Failed
+	s.Skipped += o.Skipped
+	s.DurationSeconds += o.DurationSeconds
+}
+
+// --- JUnit XML ---
+
+type junitSuite struct {
+	Name   string       `xml:"name,attr"`
+	Time   string       `xml:"time,attr"`
+	Cases  []junitCase  `xml:"testcase"`
+	Suites []junitSuite `xml:"testsuite"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failures  []junitResult `xml:"failure"`
+	Errors    []junitResult `xml:"error"`
+	Skipped   *junitResult  `xml:"skipped"`
+}
+
+type junitResult struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+func parseJUnitReport(data []byte) (*TestReport, error) {
+	var root struct {
+		XMLName xml.Name
+		junitSuite
+	}
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("parse JUnit XML: %w", err)
+	}
+	var suites []junitSuite
+	switch root.XMLName.Local {
+	case "testsuites":
+		suites = root.junitSuite.Suites
+	case "testsuite":
+		suites = []junitSuite{root.junitSuite}
+	default:
+		return nil, fmt.Errorf("parse JUnit XML: unexpected root element <%s>", root.XMLName.Local)
+	}
+
+	report := &TestReport{Suites: []TestSuiteResult{}, Failures: []TestFailure{}}
+	var walk func(s *junitSuite)
+	walk = func(s *junitSuite) {
+		result := TestSuiteResult{Name: s.Name}
+		for i := range s.Cases {
+			c := &s.Cases[i]
+			result.Total++
+			secs := parseJUnitSeconds(c.Time)
+			switch {
+			case len(c.Failures) > 0 || len(c.Errors) > 0:
+				result.Failed++
+				r := c.Errors
+				if len(c.Failures) > 0 {
+					r = c.Failures
+				}
+				report.Failures = append(report.Failures, TestFailure{
+					Suite:           s.Name,
+					Test:            junitTestName(c),
+					Message:         truncateTestMessage(strings.TrimSpace(strings.Join(nonEmpty(r[0].Message, strings.TrimSpace(r[0].Body)), "\n"))),
+					DurationSeconds: secs,
+				})
+			case c.Skipped != nil:
+				result.Skipped++
+			default:
+				result.Passed++
+			}
+			result.DurationSeconds += secs
+		}
+		// A suite's own time attribute covers setup too; prefer it.
+		if t := parseJUnitSeconds(s.Time); t > 0 {
+			result.DurationSeconds = t
+		}
+		if result.Total > 0 || len(s.Suites) == 0 {
+			report.Suites = append(report.Suites, result)
+			report.Summary.add(result.TestSummary)
+		}
+		for i := range s.Suites {
+			walk(&s.Suites[i])
+		}
+	}
+	for i := range suites {
+		walk(&suites[i])
+	}
+	return report, nil
+}
+
+func junitTestName(c *junitCase) string {
+	if c.ClassName != "" && !strings.HasPrefix(c.Name, c.ClassName) {
+		return c.ClassName + "." + c.Name
+	}
+	return c.Name
+}
+
+func parseJUnitSeconds(s string) float64 {
+	f, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(s), ",", ""), 64)
+	if err != nil {
+		return 0
+	}
+	return f
+}
+
+// --- go test -json ---
+
+// goTestEvent is one line of go test -json output (see go doc test2json).
+type goTestEvent struct {
+	Action  string
+	Package string
+	Test    string
+	Elapsed float64
+	Output  string
+}
+
+func parseGoTestJSON(r io.Reader) (*TestReport, error) {
+	type pkgState struct {
+		result  TestSuiteResult
+		output  map[string]*strings.Builder // test name ("" for the package) → output
+		failed  bool                        // package reported fail
+		elapsed float64
+	}
+	pkgs := map[string]*pkgState{}
+	var order []string
+	state := func(name string) *pkgState {
+		p, ok := pkgs[name]
+		if !ok {
+			p = &pkgState{result: TestSuiteResult{Name: name}, output: map[string]*strings.Builder{}}
+			pkgs[name] = p
+			order = append(order, name)
+		}
+		return p
+	}
+
+	report := &TestReport{Suites: []TestSuiteResult{}, Failures: []TestFailure{}}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	events := 0
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		// go test -json interleaves non-JSON lines such as build errors.
+		if len(text) == 0 || text[0] != '{' {
+			continue
+		}
+		var ev goTestEvent
+		if err := json.Unmarshal(text, &ev); err != nil {
+			return nil, fmt.Errorf("parse go test JSON line %d: %w", line, err)
+		}
+		events++
+		if ev.Package == "" {
+			continue
+		}
+		p := state(ev.Package)
+		switch ev.Action {
+		case "output":
+			b := p.output[ev.Test]
+			if b == nil {
+				b = &strings.Builder{}
+				p.output[ev.Test] = b
+			}
+			if b.Len() < maxTestFailureMessage {
+				b.WriteString(ev.Output)
+			}
+		case "pass", "fail", "skip":
+			if ev.Test == "" {
+				p.failed = ev.Action == "fail"
+				p.elapsed = ev.Elapsed
+				continue
+			}
+			p.result.Total++
+			switch ev.Action {
+			case "pass":
+				p.result.Passed++
+			case "skip":
+				p.result.Skipped++
+			case "fail":
+				p.result.Failed++
+				report.Failures = append(report.Failures, TestFailure{
+					Suite:           ev.Package,
+					Test:            ev.Test,
+					Message:         truncateTestMessage(strings.TrimSpace(outputOf(p.output[ev.Test]))),
+					DurationSeconds: ev.Elapsed,
+				})
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read go test JSON: %w", err)
+	}
+	if events == 0 {
+		return nil, fmt.Errorf("parse go test JSON: no test events found")
+	}
+
+	for _, name := range order {
+		p := pkgs[name]
+		p.result.DurationSeconds = p.elapsed
+		// A package that failed with no failing test did not build or
+		// panicked outside a test; count it so it cannot pass the gate.
+		if p.failed && p.result.Failed == 0 {
+			p.result.Total++
+			p.result.Failed++
+			report.Failures = append(report.Failures, TestFailure{
+				Suite:           name,
+				Message:         truncateTestMessage(strings.TrimSpace(outputOf(p.output[""]))),
+				DurationSeconds: p.elapsed,
+			})
+		}
+		if p.result.Total == 0 && !p.failed {
+			continue // "no test files"
+		}
+		report.Suites = append(report.Suites, p.result)
+		report.Summary.add(p.result.TestSummary)
+	}
+	sort.SliceStable(report.Failures, func(i, j int) bool { return report.Failures[i].Suite < report.Failures[j].Suite })
+	return report, nil
+}
+
+func outputOf(b *strings.Builder) string {
+	if b == nil {
+		return ""
+	}
+	return b.String()
+}
+
+func truncateTestMessage(s string) string {
+	if len(s) > maxTestFailureMessage {
+		return s[:maxTestFailureMessage] + "…"
+	}
+	return s
+}
+
+func nonEmpty(values ...string) []string {
+	var out []string
+	for _, v := range values {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,185 @@
+package module
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/GoCodeAlone/workflow/artifact"
+)
+
+const testJUnitXML = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="api" time="1.5">
+    <testcase classname="api.Orders" name="TestCreate" time="0.4"/>
+    <testcase classname="api.Orders" name="TestDelete" time="0.2">
+      <failure message="expected 204, got 500">stack trace</failure>
+    </testcase>
+    <testcase classname="api.Orders" name="TestArchive">
+      <skipped/>
+    </testcase>
+  </testsuite>
+  <testsuite name="db">
+    <testcase name="TestMigrate" time="0.1">
+      <error message="connection refused"/>
+    </testcase>
+  </testsuite>
+</testsuites>`
+
+const testGoJSON = `{"Action":"run","Package":"example.com/app","Test":"TestOK"}
+{"Action":"pass","Package":"example.com/app","Test":"TestOK","Elapsed":0.01}
+{"Action":"run","Package":"example.com/app","Test":"TestBad"}
+{"Action":"output","Package":"example.com/app","Test":"TestBad","Output":"    app_test.go:12: want 2, got 3\n"}
+{"Action":"fail","Package":"example.com/app","Test":"TestBad","Elapsed":0.02}
+{"Action":"skip","Package":"example.com/app","Test":"TestSlow","Elapsed":0}
+{"Action":"fail","Package":"example.com/app","Elapsed":0.5}
+# example.com/broken
+broken/broken.go:3:1: syntax error
+{"Action":"output","Package":"example.com/broken","Output":"FAIL\texample.com/broken [build failed]\n"}
+{"Action":"fail","Package":"example.com/broken","Elapsed":0}
+{"Action":"output","Package":"example.com/empty","Output":"?   \texample.com/empty\t[no test files]\n"}
+{"Action":"skip","Package":"example.com/empty","Elapsed":0}
+`
+
+func TestParseTestReport_JUnit(t *testing.T) {
+	r, err := ParseTestReport("auto", []byte(testJUnitXML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Format != TestReportFormatJUnit {
+		t.Errorf("format = %q", r.Format)
+	}
+	want := TestSummary{Total: 4, Passed: 1, Failed: 2, Skipped: 1, DurationSeconds: 1.6}
+	if r.Summary != want {
+		t.Errorf("summary = %+v, want %+v", r.Summary, want)
+	}
+	if len(r.Suites) != 2 || r.Suites[0].Name != "api" || r.Suites[0].Failed != 1 {
+		t.Errorf("suites = %+v", r.Suites)
+	}
+	if len(r.Failures) != 2 {
+		t.Fatalf("failures = %+v", r.Failures)
+	}
+	if f := r.Failures[0]; f.Test != "api.Orders.TestDelete" || !strings.HasPrefix(f.Message, "expected 204, got 500") {
+		t.Errorf("failure = %+v", f)
+	}
+	if r.Failures[1].Message != "connection refused" {
+		t.Errorf("error message = %q", r.Failures[1].Message)
+	}
+}
+
+func TestParseTestReport_GoTestJSON(t *testing.T) {
+	r, err := ParseTestReport("", []byte(testGoJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Format != TestReportFormatGoTestJSON {
+		t.Errorf("format = %q", r.Format)
+	}
+	// The build failure counts as one failed test; the package without
+	// test files is left out.
+	want := TestSummary{Total: 4, Passed: 1, Failed: 2, Skipped: 1, DurationSeconds: 0.5}
+	if r.Summary != want {
+		t.Errorf("summary = %+v, want %+v", r.Summary, want)
+	}
+	if len(r.Suites) != 2 {
+		t.Errorf("suites = %+v", r.Suites)
+	}
+	if len(r.Failures) != 2 {
+		t.Fatalf("failures = %+v", r.Failures)
+	}
+	if f := r.Failures[0]; f.Test != "TestBad" || f.Message != "app_test.go:12: want 2, got 3" {
+		t.Errorf("test failure = %+v", f)
+	}
+	if f := r.Failures[1]; f.Suite != "example.com/broken" || f.Test != "" || !strings.Contains(f.Message, "build failed") {
+		t.Errorf("build failure = %+v", f)
+	}
+}
+
+func TestParseTestReport_Invalid(t *testing.T) {
+	for name, tc := range map[string]struct{ format, data string }{
+		"bad xml":        {TestReportFormatJUnit, "<testsuites><testsuite>"},
+		"wrong root":     {TestReportFormatJUnit, "<html></html>"},
+		"no events":      {TestReportFormatGoTestJSON, "ok  \texample.com/app\n"},
+		"unknown format": {"tap", "ok 1"},
+	} {
+		if _, err := ParseTestReport(tc.format, []byte(tc.data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestTestReportStep_Path(t *testing.T) {
+	dir := t.TempDir()
+	for i, suite := range []string{`<testsuite name="a"><testcase name="T1"/></testsuite>`, `<testsuite name="b"><testcase name="T2"/><testcase name="T3"><failure/></testcase></testsuite>`} {
+		if err := os.WriteFile(filepath.Join(dir, "junit-"+string(rune('0'+i))+".xml"), []byte(suite), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store := artifact.NewLocalStore(t.TempDir())
+	pc := NewPipelineContext(nil, map[string]any{"artifact_store": store, "execution_id": "exec-1"})
+
+	step, err := NewTestReportStepFactory()("report", map[string]any{"path": filepath.Join(dir, "*.xml"), "max_failures": 1}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := step.Execute(context.Background(), pc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Output["passed"] != true {
+		t.Errorf("passed = %v", result.Output["passed"])
+	}
+	if s := result.Output["summary"].(TestSummary); s.Total != 3 || s.Failed != 1 {
+		t.Errorf("summary = %+v", s)
+	}
+	rc, err := store.Get(context.Background(), "exec-1", "test-report.json")
+	if err != nil {
+		t.Fatalf("report artifact not stored: %v", err)
+	}
+	defer rc.Close()
+	data, _ := io.ReadAll(rc)
+	if !strings.Contains(string(data), `"failed":1`) {
+		t.Errorf("stored report = %s", data)
+	}
+
+	// With the default max_failures of 0 the same results fail the step.
+	strict, _ := NewTestReportStepFactory()("report", map[string]any{"path": filepath.Join(dir, "*.xml")}, nil)
+	if _, err := strict.Execute(context.Background(), pc); err == nil || !strings.Contains(err.Error(), "1 of 3 tests failed") {
+		t.Errorf("err = %v, want gate failure", err)
+	}
+}
+
+func TestTestReportStep_From(t *testing.T) {
+	pc := NewPipelineContext(nil, nil)
+	pc.MergeStepOutput("test", map[string]any{"stdout": testGoJSON})
+
+	step, err := NewTestReportStepFactory()("report", map[string]any{"from": "steps.test.stdout", "max_failures": 5, "min_tests": 10}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := step.Execute(context.Background(), pc); err == nil || !strings.Contains(err.Error(), "fewer than min_tests") {
+		t.Errorf("err = %v, want min_tests failure", err)
+	}
+
+	missing, _ := NewTestReportStepFactory()("report", map[string]any{"from": "steps.nope.stdout"}, nil)
+	if _, err := missing.Execute(context.Background(), pc); err == nil {
+		t.Error("expected an error for a missing 'from' path")
+	}
+}
+
+func TestTestReportStepFactory_Validation(t *testing.T) {
+	for name, cfg := range map[string]map[string]any{
+		"no source":        {},
+		"two sources":      {"path": "a.xml", "from": "steps.x.stdout"},
+		"bad format":       {"path": "a.xml", "format": "tap"},
+		"negative failure": {"path": "a.xml", "max_failures": -1},
+		"string min_tests": {"path": "a.xml", "min_tests": "3"},
+	} {
+		if _, err := NewTestReportStepFactory()("report", cfg, nil); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
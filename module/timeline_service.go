@@ -10,7 +10,8 @@ import (
 
 // TimelineServiceModule wraps evstore.TimelineHandler and evstore.ReplayHandler
 // as a modular.Module. It provides HTTP muxes for timeline and replay features
-// via the service registry.
+// via the service registry. The muxes reject requests that carry neither a
+// tenant nor unscoped access, so mount them behind AdminTenantScope.
 type TimelineServiceModule struct {
 	name            string
 	eventStore      evstore.EventStore
@@ -20,6 +21,7 @@ type TimelineServiceModule struct {
 	timelineMux     *http.ServeMux
 	replayMux       *http.ServeMux
 	backfillMux     *http.ServeMux
+	app             modular.Application
 }
 
 // NewTimelineServiceModule creates a new timeline service module.
@@ -47,7 +49,7 @@ func NewTimelineServiceModule(name string, eventStore evstore.EventStore) *Timel
 
 	logger.Info("Created timeline, replay, and backfill/mock/diff handlers", "module", name)
 
-	return &TimelineServiceModule{
+	m := &TimelineServiceModule{
 		name:            name,
 		eventStore:      eventStore,
 		timelineHandler: timelineHandler,
@@ -57,13 +59,43 @@ func NewTimelineServiceModule(name string, eventStore evstore.EventStore) *Timel
 		replayMux:       replayMux,
 		backfillMux:     backfillMux,
 	}
+	runner := evstore.NewBackfillRunner(backfillStore, mockStore, eventStore, BackfillRunFunc(m.lookupPipeline), logger)
+	backfillHandler.WithRunner(runner)
+	return m
+}
+
+// lookupPipeline resolves a pipeline of the workflow engine, which
+// registers itself as the "workflowEngine" service, for backfills.
+func (m *TimelineServiceModule) lookupPipeline(name string) (*Pipeline, bool) {
+	if m.app == nil {
+		return nil, false
+	}
+	engine, ok := m.app.SvcRegistry()["workflowEngine"].(interface {
+		GetPipeline(name string) (*Pipeline, bool)
+	})
+	if !ok {
+		return nil, false
+	}
+	return engine.GetPipeline(name)
 }
 
 // Name implements modular.Module.
 func (m *TimelineServiceModule) Name() string { return m.name }
 
 // Init implements modular.Module.
-func (m *TimelineServiceModule) Init(_ modular.Application) error { return nil }
+func (m *TimelineServiceModule) Init(app modular.Application) error {
+	m.app = app
+	return nil
+}
+
+// Service name suffixes under which a timeline service module named <name>
+// registers its timeline, replay and backfill handler muxes, e.g.
+// "timeline.replay".
+const (
+	TimelineServiceSuffix = ".timeline"
+	ReplayServiceSuffix   = ".replay"
+	BackfillServiceSuffix = ".backfill"
+)
 
 // ProvidesServices implements modular.Module. Registers the timeline, replay,
 // and backfill muxes as services so the server can delegate routes to them.
@@ -75,17 +107,17 @@ func (m *TimelineServiceModule) ProvidesServices() []modular.ServiceProvider {
 			Instance:    m.timelineMux,
 		},
 		{
-			Name:        m.name + ".timeline",
+			Name:        m.name + TimelineServiceSuffix,
 			Description: "Timeline handler mux: " + m.name,
 			Instance:    http.Handler(m.timelineMux),
 		},
 		{
-			Name:        m.name + ".replay",
+			Name:        m.name + ReplayServiceSuffix,
 			Description: "Replay handler mux: " + m.name,
 			Instance:    http.Handler(m.replayMux),
 		},
 		{
-			Name:        m.name + ".backfill",
+			Name:        m.name + BackfillServiceSuffix,
 			Description: "Backfill/mock/diff handler mux: " + m.name,
 			Instance:    http.Handler(m.backfillMux),
 		},
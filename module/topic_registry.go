@@ -0,0 +1,552 @@
+package module
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/GoCodeAlone/modular"
+	evstore "github.com/GoCodeAlone/workflow/store"
+	"github.com/google/uuid"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// Compatibility modes of a registered topic schema. They govern which schema
+// changes UpdateSchema accepts.
+const (
+	// TopicCompatibilityNone accepts any new schema.
+	TopicCompatibilityNone = "none"
+	// TopicCompatibilityBackward accepts a new schema only if every message
+	// valid under the current schema is valid under the new one, so
+	// consumers can upgrade before producers.
+	TopicCompatibilityBackward = "backward"
+	// TopicCompatibilityForward accepts a new schema only if every message
+	// valid under the new schema is valid under the current one, so
+	// producers can upgrade before consumers.
+	TopicCompatibilityForward = "forward"
+)
+
+// What a TopicSchemaRegistry does with a message that does not match its
+// topic's schema.
+const (
+	// TopicOnInvalidReject fails the publish.
+	TopicOnInvalidReject = "reject"
+	// TopicOnInvalidWarn logs the violations and lets the message through.
+	TopicOnInvalidWarn = "warn"
+	// TopicOnInvalidDLQ skips the handler and adds the message, with its
+	// violations, to the dead letter queue.
+	TopicOnInvalidDLQ = "dlq"
+)
+
+// TopicSchemaConfig declares the schema of one topic.
+type TopicSchemaConfig struct {
+	// Schema is the JSON Schema every message on the topic must match.
+	Schema map[string]any `json:"schema" yaml:"schema"`
+	// Compatibility is none, backward (default) or forward.
+	Compatibility string `json:"compatibility,omitempty" yaml:"compatibility,omitempty"`
+}
+
+// TopicRegistryConfig holds the configuration of a messaging.topic_registry
+// module.
+type TopicRegistryConfig struct {
+	// Topics maps topic names to their schemas.
+	Topics map[string]TopicSchemaConfig `json:"topics" yaml:"topics"`
+	// OnInvalidPublish is reject (default) or warn.
+	OnInvalidPublish string `json:"onInvalidPublish,omitempty" yaml:"onInvalidPublish,omitempty"`
+	// OnInvalidDelivery is dlq (default) or warn.
+	OnInvalidDelivery string `json:"onInvalidDelivery,omitempty" yaml:"onInvalidDelivery,omitempty"`
+	// DLQ names the dlq.service module invalid deliveries go to. When empty,
+	// the only configured dlq.service is used.
+	DLQ string `json:"dlq,omitempty" yaml:"dlq,omitempty"`
+}
+
+// ParseTopicRegistryConfig reads a messaging.topic_registry config map.
+// Values are checked when the module initializes.
+func ParseTopicRegistryConfig(cfg map[string]any) TopicRegistryConfig {
+	rc := TopicRegistryConfig{Topics: make(map[string]TopicSchemaConfig)}
+	rc.OnInvalidPublish, _ = cfg["onInvalidPublish"].(string)
+	rc.OnInvalidDelivery, _ = cfg["onInvalidDelivery"].(string)
+	rc.DLQ, _ = cfg["dlq"].(string)
+	topics, _ := cfg["topics"].(map[string]any)
+	for name, raw := range topics {
+		tm, _ := raw.(map[string]any)
+		var tc TopicSchemaConfig
+		tc.Schema, _ = tm["schema"].(map[string]any)
+		tc.Compatibility, _ = tm["compatibility"].(string)
+		rc.Topics[name] = tc
+	}
+	return rc
+}
+
+// TopicRegistryAware is implemented by message brokers that validate
+// published and delivered messages against an attached TopicSchemaRegistry.
+// The messaging plugin attaches the registry to every such broker.
+type TopicRegistryAware interface {
+	SetTopicRegistry(r *TopicSchemaRegistry)
+	TopicRegistry() *TopicSchemaRegistry
+}
+
+// TopicSchemaError is returned when a message does not match the schema of
+// its topic.
+type TopicSchemaError struct {
+	Topic  string
+	Errors []string
+}
+
+func (e *TopicSchemaError) Error() string {
+	return fmt.Sprintf("message for topic %q does not match its schema: %s", e.Topic, strings.Join(e.Errors, "; "))
+}
+
+// SchemaCompatibilityError is returned by UpdateSchema when a new schema
+// breaks the topic's compatibility mode.
+type SchemaCompatibilityError struct {
+	Topic         string
+	Compatibility string
+	Problems      []string
+}
+
+func (e *SchemaCompatibilityError) Error() string {
+	return fmt.Sprintf("schema for topic %q is not %s compatible: %s", e.Topic, e.Compatibility, strings.Join(e.Problems, "; "))
+}
+
+// ErrTopicNotRegistered is returned by UpdateSchema for a topic the registry
+// does not declare.
+var ErrTopicNotRegistered = errors.New("topic not registered")
+
+// TopicEndpoints lists the producers and consumers of a topic found in the
+// config, e.g. "pipeline:create-order" or "handler:order-handler".
+type TopicEndpoints struct {
+	Producers []string `json:"producers"`
+	Consumers []string `json:"consumers"`
+}
+
+// TopicValidationFailure describes the most recent message rejected for a
+// topic.
+type TopicValidationFailure struct {
+	At        time.Time `json:"at"`
+	Direction string    `json:"direction"` // "publish" or "delivery"
+	Errors    []string  `json:"errors"`
+}
+
+// TopicValidationStats counts the messages validated against a topic's schema
+// since the registry started.
+type TopicValidationStats struct {
+	Published        int64                   `json:"published"`
+	PublishFailures  int64                   `json:"publishFailures"`
+	Delivered        int64                   `json:"delivered"`
+	DeliveryFailures int64                   `json:"deliveryFailures"`
+	LastFailure      *TopicValidationFailure `json:"lastFailure,omitempty"`
+}
+
+// TopicInfo is the registry's view of one topic.
+type TopicInfo struct {
+	Topic         string               `json:"topic"`
+	Compatibility string               `json:"compatibility"`
+	Version       int                  `json:"version"`
+	UpdatedAt     time.Time            `json:"updatedAt"`
+	Schema        map[string]any       `json:"schema"`
+	Producers     []string             `json:"producers"`
+	Consumers     []string             `json:"consumers"`
+	Validation    TopicValidationStats `json:"validation"`
+}
+
+// registeredTopic is a topic's current schema and its counters.
+type registeredTopic struct {
+	compatibility string
+	version       int
+	updatedAt     time.Time
+	schema        map[string]any
+	compiled      *jsonschema.Schema
+
+	published, publishFailures  atomic.Int64
+	delivered, deliveryFailures atomic.Int64
+	lastFailure                 atomic.Pointer[TopicValidationFailure]
+}
+
+// TopicSchemaRegistry declares a JSON Schema per message topic and enforces
+// it: step.publish and the brokers' producers check outgoing messages, and
+// brokers receiving from outside the process check messages before invoking
+// their handlers. Topics without a declared schema are not checked.
+type TopicSchemaRegistry struct {
+	name   string
+	config TopicRegistryConfig
+	logger modular.Logger
+
+	mu        sync.RWMutex
+	topics    map[string]*registeredTopic
+	endpoints map[string]TopicEndpoints
+	dlq       evstore.DLQStore
+	now       func() time.Time
+}
+
+// NewTopicSchemaRegistry creates a topic schema registry module. Zero config
+// values take the documented defaults.
+func NewTopicSchemaRegistry(name string, cfg TopicRegistryConfig) *TopicSchemaRegistry {
+	if cfg.OnInvalidPublish == "" {
+		cfg.OnInvalidPublish = TopicOnInvalidReject
+	}
+	if cfg.OnInvalidDelivery == "" {
+		cfg.OnInvalidDelivery = TopicOnInvalidDLQ
+	}
+	return &TopicSchemaRegistry{
+		name:      name,
+		config:    cfg,
+		logger:    &noopLogger{},
+		topics:    make(map[string]*registeredTopic),
+		endpoints: make(map[string]TopicEndpoints),
+		now:       time.Now,
+	}
+}
+
+// Name implements modular.Module.
+func (r *TopicSchemaRegistry) Name() string { return r.name }
+
+// Init implements modular.Module. It checks the config and compiles every
+// declared schema.
+func (r *TopicSchemaRegistry) Init(app modular.Application) error {
+	r.logger = app.Logger()
+	if r.config.OnInvalidPublish != TopicOnInvalidReject && r.config.OnInvalidPublish != TopicOnInvalidWarn {
+		return fmt.Errorf("topic registry %q: onInvalidPublish must be %q or %q, got %q", r.name, TopicOnInvalidReject, TopicOnInvalidWarn, r.config.OnInvalidPublish)
+	}
+	if r.config.OnInvalidDelivery != TopicOnInvalidDLQ && r.config.OnInvalidDelivery != TopicOnInvalidWarn {
+		return fmt.Errorf("topic registry %q: onInvalidDelivery must be %q or %q, got %q", r.name, TopicOnInvalidDLQ, TopicOnInvalidWarn, r.config.OnInvalidDelivery)
+	}
+
+	now := r.now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, topic := range slices.Sorted(maps.Keys(r.config.Topics)) {
+		tc := r.config.Topics[topic]
+		compat := tc.Compatibility
+		switch compat {
+		case "":
+			compat = TopicCompatibilityBackward
+		case TopicCompatibilityNone, TopicCompatibilityBackward, TopicCompatibilityForward:
+		default:
+			return fmt.Errorf("topic registry %q: topic %q: compatibility must be none, backward or forward, got %q", r.name, topic, compat)
+		}
+		if tc.Schema == nil {
+			return fmt.Errorf("topic registry %q: topic %q: 'schema' is required", r.name, topic)
+		}
+		compiled, err := compileTopicSchema(topic, tc.Schema)
+		if err != nil {
+			return fmt.Errorf("topic registry %q: topic %q: %w", r.name, topic, err)
+		}
+		r.topics[topic] = &registeredTopic{
+			compatibility: compat,
+			version:       1,
+			updatedAt:     now,
+			schema:        tc.Schema,
+			compiled:      compiled,
+		}
+	}
+	return nil
+}
+
+// ProvidesServices implements modular.Module.
+func (r *TopicSchemaRegistry) ProvidesServices() []modular.ServiceProvider {
+	return []modular.ServiceProvider{
+		{Name: r.name, Description: "Topic schema registry: " + r.name, Instance: r},
+	}
+}
+
+// RequiresServices implements modular.Module.
+func (r *TopicSchemaRegistry) RequiresServices() []modular.ServiceDependency {
+	return nil
+}
+
+// DLQName returns the configured dlq.service module name, if any.
+func (r *TopicSchemaRegistry) DLQName() string { return r.config.DLQ }
+
+// SetDLQStore sets the store invalid deliveries are added to.
+func (r *TopicSchemaRegistry) SetDLQStore(store evstore.DLQStore) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dlq = store
+}
+
+// SetEndpoints records the producers and consumers of each topic, as found
+// in the config, for the admin API.
+func (r *TopicSchemaRegistry) SetEndpoints(endpoints map[string]TopicEndpoints) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.endpoints = endpoints
+}
+
+// Validate checks payload against the schema of topic. It reports false when
+// the topic has no schema; otherwise it returns the violations found, if any.
+func (r *TopicSchemaRegistry) Validate(topic string, payload []byte) (violations []string, registered bool) {
+	t := r.topic(topic)
+	if t == nil {
+		return nil, false
+	}
+	return validateTopicPayload(t, payload), true
+}
+
+// ValidatePublish checks a message about to be published to topic. An
+// invalid message fails with a *TopicSchemaError, or is only logged when
+// onInvalidPublish is warn.
+func (r *TopicSchemaRegistry) ValidatePublish(topic string, payload []byte) error {
+	t := r.topic(topic)
+	if t == nil {
+		return nil
+	}
+	t.published.Add(1)
+	violations := validateTopicPayload(t, payload)
+	if len(violations) == 0 {
+		return nil
+	}
+	t.publishFailures.Add(1)
+	r.recordFailure(t, "publish", violations)
+	if r.config.OnInvalidPublish == TopicOnInvalidWarn {
+		r.logger.Warn("Published message does not match its topic schema", "topic", topic, "errors", violations)
+		return nil
+	}
+	return &TopicSchemaError{Topic: topic, Errors: violations}
+}
+
+// AcceptDelivery checks a message received on topic before it is handed to
+// its handler and reports whether the handler should be invoked. An invalid
+// message is added to the dead letter queue with its violations, or is only
+// logged when onInvalidDelivery is warn. source names the broker.
+func (r *TopicSchemaRegistry) AcceptDelivery(topic string, payload []byte, source string) bool {
+	t := r.topic(topic)
+	if t == nil {
+		return true
+	}
+	t.delivered.Add(1)
+	violations := validateTopicPayload(t, payload)
+	if len(violations) == 0 {
+		return true
+	}
+	t.deliveryFailures.Add(1)
+	r.recordFailure(t, "delivery", violations)
+	if r.config.OnInvalidDelivery == TopicOnInvalidWarn {
+		r.logger.Warn("Delivered message does not match its topic schema", "topic", topic, "broker", source, "errors", violations)
+		return true
+	}
+
+	r.mu.RLock()
+	dlq, version := r.dlq, t.version
+	r.mu.RUnlock()
+	if dlq == nil {
+		r.logger.Error("Dropping message that does not match its topic schema: no DLQ configured", "topic", topic, "broker", source, "errors", violations)
+		return false
+	}
+	original := json.RawMessage(payload)
+	if !json.Valid(payload) {
+		original, _ = json.Marshal(string(payload))
+	}
+	now := r.now().UTC()
+	entry := &evstore.DLQEntry{
+		ID:            uuid.New(),
+		OriginalEvent: original,
+		ErrorMessage:  (&TopicSchemaError{Topic: topic, Errors: violations}).Error(),
+		ErrorType:     "schema_validation",
+		Status:        evstore.DLQStatusPending,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		Metadata: map[string]any{
+			"topic":             topic,
+			"broker":            source,
+			"schema_version":    version,
+			"validation_errors": violations,
+		},
+	}
+	if err := dlq.Add(context.Background(), entry); err != nil {
+		r.logger.Error("Failed to add invalid message to the DLQ", "topic", topic, "error", err)
+	}
+	return false
+}
+
+// UpdateSchema replaces the schema of a registered topic after checking it
+// against the topic's compatibility mode. It returns ErrTopicNotRegistered
+// for an unknown topic and a *SchemaCompatibilityError for a breaking
+// change.
+func (r *TopicSchemaRegistry) UpdateSchema(topic string, schema map[string]any) (TopicInfo, error) {
+	compiled, err := compileTopicSchema(topic, schema)
+	if err != nil {
+		return TopicInfo{}, err
+	}
+
+	r.mu.Lock()
+	t, ok := r.topics[topic]
+	if !ok {
+		r.mu.Unlock()
+		return TopicInfo{}, fmt.Errorf("%w: %q", ErrTopicNotRegistered, topic)
+	}
+	if problems := SchemaCompatibilityProblems(t.compatibility, t.schema, schema); len(problems) > 0 {
+		r.mu.Unlock()
+		return TopicInfo{}, &SchemaCompatibilityError{Topic: topic, Compatibility: t.compatibility, Problems: problems}
+	}
+	t.schema = schema
+	t.compiled = compiled
+	t.version++
+	t.updatedAt = r.now()
+	version := t.version
+	r.mu.Unlock()
+
+	r.logger.Info("Topic schema updated", "topic", topic, "version", version)
+	info, _ := r.Topic(topic)
+	return info, nil
+}
+
+// Topic returns the registry's view of one topic.
+func (r *TopicSchemaRegistry) Topic(topic string) (TopicInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.topics[topic]
+	if !ok {
+		return TopicInfo{}, false
+	}
+	return r.topicInfo(topic, t), true
+}
+
+// Topics returns every registered topic, sorted by name.
+func (r *TopicSchemaRegistry) Topics() []TopicInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	infos := make([]TopicInfo, 0, len(r.topics))
+	for _, name := range slices.Sorted(maps.Keys(r.topics)) {
+		infos = append(infos, r.topicInfo(name, r.topics[name]))
+	}
+	return infos
+}
+
+// topicInfo builds a TopicInfo; r.mu must be held.
+func (r *TopicSchemaRegistry) topicInfo(name string, t *registeredTopic) TopicInfo {
+	ep := r.endpoints[name]
+	return TopicInfo{
+		Topic:         name,
+		Compatibility: t.compatibility,
+		Version:       t.version,
+		UpdatedAt:     t.updatedAt,
+		Schema:        t.schema,
+		Producers:     nonNilStrings(ep.Producers),
+		Consumers:     nonNilStrings(ep.Consumers),
+		Validation: TopicValidationStats{
+			Published:        t.published.Load(),
+			PublishFailures:  t.publishFailures.Load(),
+			Delivered:        t.delivered.Load(),
+			DeliveryFailures: t.deliveryFailures.Load(),
+			LastFailure:      t.lastFailure.Load(),
+		},
+	}
+}
+
+func (r *TopicSchemaRegistry) topic(name string) *registeredTopic {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.topics[name]
+}
+
+func (r *TopicSchemaRegistry) recordFailure(t *registeredTopic, direction string, violations []string) {
+	t.lastFailure.Store(&TopicValidationFailure{At: r.now(), Direction: direction, Errors: violations})
+}
+
+// validateTopicPayload returns the violations of payload against t's current
+// schema.
+func validateTopicPayload(t *registeredTopic, payload []byte) []string {
+	return schemaViolations(t.compiled, payload)
+}
+
+// schemaViolations returns the violations of the JSON document payload
+// against schema, each as "<instance location>: <message>".
+func schemaViolations(schema *jsonschema.Schema, payload []byte) []string {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(payload))
+	if err != nil {
+		return []string{"payload is not valid JSON: " + err.Error()}
+	}
+	err = schema.Validate(doc)
+	if err == nil {
+		return nil
+	}
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) {
+		return []string{err.Error()}
+	}
+	var violations []string
+	for _, unit := range ve.BasicOutput().Errors {
+		if unit.Error == nil {
+			continue
+		}
+		loc := unit.InstanceLocation
+		if loc == "" {
+			loc = "/"
+		}
+		violations = append(violations, loc+": "+unit.Error.String())
+	}
+	if len(violations) == 0 {
+		violations = []string{ve.Error()}
+	}
+	return violations
+}
+
+// compileTopicSchema compiles a topic's JSON Schema.
+func compileTopicSchema(topic string, schema map[string]any) (*jsonschema.Schema, error) {
+	raw, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	loc := "mem://topics/" + url.PathEscape(topic) + ".json"
+	c := jsonschema.NewCompiler()
+	if err := c.AddResource(loc, doc); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	compiled, err := c.Compile(loc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	return compiled, nil
+}
+
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+// topicSchemaGuard is embedded by brokers to implement TopicRegistryAware.
+type topicSchemaGuard struct {
+	topicRegistry atomic.Pointer[TopicSchemaRegistry]
+}
+
+// SetTopicRegistry attaches the registry messages are validated against.
+func (g *topicSchemaGuard) SetTopicRegistry(r *TopicSchemaRegistry) {
+	g.topicRegistry.Store(r)
+}
+
+// TopicRegistry returns the attached registry, or nil.
+func (g *topicSchemaGuard) TopicRegistry() *TopicSchemaRegistry {
+	return g.topicRegistry.Load()
+}
+
+// checkPublish validates an outgoing message when a registry is attached.
+func (g *topicSchemaGuard) checkPublish(topic string, message []byte) error {
+	if r := g.topicRegistry.Load(); r != nil {
+		return r.ValidatePublish(topic, message)
+	}
+	return nil
+}
+
+// acceptDelivery validates an incoming message when a registry is attached.
+func (g *topicSchemaGuard) acceptDelivery(topic string, message []byte, broker string) bool {
+	if r := g.topicRegistry.Load(); r != nil {
+		return r.AcceptDelivery(topic, message, broker)
+	}
+	return true
+}
@@ -0,0 +1,83 @@
+package module
+
+import (
+	"errors"
+	"net/http"
+)
+
+// TopicRegistryAPIServiceName is the service name the messaging plugin
+// registers its TopicRegistryAPIHandler under.
+const TopicRegistryAPIServiceName = "messaging.topics.api"
+
+// TopicRegistryAPIHandler serves the topics of a TopicSchemaRegistry with
+// their schemas, the producers and consumers found in the config and their
+// validation counts, and accepts schema changes that keep each topic's
+// compatibility mode.
+type TopicRegistryAPIHandler struct {
+	registry *TopicSchemaRegistry
+	mux      *http.ServeMux
+}
+
+// NewTopicRegistryAPIHandler creates a handler over registry.
+func NewTopicRegistryAPIHandler(registry *TopicSchemaRegistry) *TopicRegistryAPIHandler {
+	h := &TopicRegistryAPIHandler{registry: registry, mux: http.NewServeMux()}
+	h.RegisterRoutes(h.mux)
+	return h
+}
+
+// RegisterRoutes registers the topic registry API routes on the given mux.
+func (h *TopicRegistryAPIHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/admin/topics", h.handleList)
+	mux.HandleFunc("GET /api/v1/admin/topics/{topic}", h.handleGet)
+	mux.HandleFunc("PUT /api/v1/admin/topics/{topic}/schema", h.handleUpdateSchema)
+}
+
+// ServeHTTP implements http.Handler for delegate dispatch. The delegate step
+// passes the full original path, which the routes match.
+func (h *TopicRegistryAPIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *TopicRegistryAPIHandler) handleList(w http.ResponseWriter, _ *http.Request) {
+	topics := h.registry.Topics()
+	writeJSON(w, http.StatusOK, map[string]any{"topics": topics, "total": len(topics)})
+}
+
+func (h *TopicRegistryAPIHandler) handleGet(w http.ResponseWriter, r *http.Request) {
+	info, ok := h.registry.Topic(r.PathValue("topic"))
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "topic not found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+// handleUpdateSchema replaces a topic's schema with the "schema" of the
+// request body. A change that breaks the topic's compatibility mode is
+// refused with 409 and the list of problems.
+func (h *TopicRegistryAPIHandler) handleUpdateSchema(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Schema map[string]any `json:"schema"`
+	}
+	if err := decodeBody(r, &body); err != nil || body.Schema == nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "request body must be a JSON object with a 'schema' object"})
+		return
+	}
+
+	info, err := h.registry.UpdateSchema(r.PathValue("topic"), body.Schema)
+	var compatErr *SchemaCompatibilityError
+	switch {
+	case err == nil:
+		writeJSON(w, http.StatusOK, info)
+	case errors.Is(err, ErrTopicNotRegistered):
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "topic not found"})
+	case errors.As(err, &compatErr):
+		writeJSON(w, http.StatusConflict, map[string]any{
+			"error":         compatErr.Error(),
+			"compatibility": compatErr.Compatibility,
+			"problems":      compatErr.Problems,
+		})
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+}
@@ -0,0 +1,111 @@
+package module
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// SchemaCompatibilityProblems lists the ways next breaks the compatibility
+// mode relative to current; an empty result means the change is accepted.
+//
+// Backward compatibility requires every message valid under current to stay
+// valid under next; forward compatibility requires the reverse. The check is
+// structural over the keywords producers and consumers rely on — type, enum,
+// required, properties, additionalProperties and items — and is recursive
+// into nested objects and arrays. Other keywords are not compared.
+func SchemaCompatibilityProblems(mode string, current, next map[string]any) []string {
+	switch mode {
+	case TopicCompatibilityBackward:
+		return schemaReadProblems(next, current, "", "new schema")
+	case TopicCompatibilityForward:
+		return schemaReadProblems(current, next, "", "current schema")
+	}
+	return nil
+}
+
+// schemaReadProblems lists the messages valid under writer that reader
+// rejects, as descriptions prefixed with their JSON pointer. who names the
+// reader in the descriptions.
+func schemaReadProblems(reader, writer map[string]any, path, who string) []string {
+	at := path
+	if at == "" {
+		at = "/"
+	}
+	var problems []string
+
+	readerTypes, writerTypes := schemaTypes(reader["type"]), schemaTypes(writer["type"])
+	if len(readerTypes) > 0 {
+		if len(writerTypes) == 0 {
+			problems = append(problems, fmt.Sprintf("%s: %s only accepts type %s", at, who, strings.Join(readerTypes, ", ")))
+		}
+		for _, t := range writerTypes {
+			if !slices.Contains(readerTypes, t) && (t != "integer" || !slices.Contains(readerTypes, "number")) {
+				problems = append(problems, fmt.Sprintf("%s: %s rejects type %q", at, who, t))
+			}
+		}
+	}
+
+	if readerEnum, ok := reader["enum"].([]any); ok {
+		writerEnum, ok := writer["enum"].([]any)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s: %s restricts values to an enum", at, who))
+		}
+		for _, v := range writerEnum {
+			// Compare printed values: YAML config decodes 1 as int, JSON as float64.
+			if !slices.ContainsFunc(readerEnum, func(r any) bool { return fmt.Sprint(r) == fmt.Sprint(v) }) {
+				problems = append(problems, fmt.Sprintf("%s: %s rejects enum value %v", at, who, v))
+			}
+		}
+	}
+
+	writerRequired := schemaStrings(writer["required"])
+	for _, field := range schemaStrings(reader["required"]) {
+		if !slices.Contains(writerRequired, field) {
+			problems = append(problems, fmt.Sprintf("%s: %s requires field %q", at, who, field))
+		}
+	}
+
+	readerProps, _ := reader["properties"].(map[string]any)
+	writerProps, _ := writer["properties"].(map[string]any)
+	readerClosed := reader["additionalProperties"] == false
+	for _, name := range slices.Sorted(maps.Keys(writerProps)) {
+		ws, _ := writerProps[name].(map[string]any)
+		rs, declared := readerProps[name].(map[string]any)
+		switch {
+		case declared:
+			problems = append(problems, schemaReadProblems(rs, ws, path+"/"+name, who)...)
+		case readerClosed:
+			problems = append(problems, fmt.Sprintf("%s: %s rejects field %q (additionalProperties is false)", at, who, name))
+		}
+	}
+	if readerClosed && writer["additionalProperties"] != false {
+		problems = append(problems, fmt.Sprintf("%s: %s rejects additional properties", at, who))
+	}
+
+	readerItems, rok := reader["items"].(map[string]any)
+	writerItems, wok := writer["items"].(map[string]any)
+	if rok {
+		if !wok {
+			writerItems = map[string]any{}
+		}
+		problems = append(problems, schemaReadProblems(readerItems, writerItems, path+"/items", who)...)
+	}
+	return problems
+}
+
+func schemaStrings(v any) []string {
+	var out []string
+	switch list := v.(type) {
+	case []any:
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+	case []string:
+		out = append(out, list...)
+	}
+	return out
+}
@@ -0,0 +1,266 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	evstore "github.com/GoCodeAlone/workflow/store"
+)
+
+func orderCreatedSchema() map[string]any {
+	return map[string]any{
+		"type":     "object",
+		"required": []any{"order_id"},
+		"properties": map[string]any{
+			"order_id": map[string]any{"type": "string"},
+			"total":    map[string]any{"type": "number"},
+		},
+	}
+}
+
+func newTestTopicRegistry(t *testing.T, cfg TopicRegistryConfig) *TopicSchemaRegistry {
+	t.Helper()
+	if cfg.Topics == nil {
+		cfg.Topics = map[string]TopicSchemaConfig{"orders.created": {Schema: orderCreatedSchema()}}
+	}
+	r := NewTopicSchemaRegistry("topics", cfg)
+	app, _ := NewTestApplication()
+	if err := r.Init(app); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	return r
+}
+
+func TestTopicSchemaRegistry_ValidatePublish(t *testing.T) {
+	r := newTestTopicRegistry(t, TopicRegistryConfig{})
+
+	if err := r.ValidatePublish("orders.created", []byte(`{"order_id":"o-1","total":12.5}`)); err != nil {
+		t.Fatalf("valid message rejected: %v", err)
+	}
+	if err := r.ValidatePublish("orders.other", []byte(`not json`)); err != nil {
+		t.Fatalf("topic without a schema must not be checked, got %v", err)
+	}
+
+	err := r.ValidatePublish("orders.created", []byte(`{"orderId":"o-1","total":"12"}`))
+	var schemaErr *TopicSchemaError
+	if !errors.As(err, &schemaErr) {
+		t.Fatalf("err = %v, want *TopicSchemaError", err)
+	}
+	joined := strings.Join(schemaErr.Errors, "\n")
+	if !strings.Contains(joined, "order_id") || !strings.Contains(joined, "/total") {
+		t.Errorf("violations should name the missing field and the mistyped one:\n%s", joined)
+	}
+
+	info, _ := r.Topic("orders.created")
+	if info.Validation.Published != 2 || info.Validation.PublishFailures != 1 {
+		t.Errorf("stats = %+v, want 2 published and 1 failure", info.Validation)
+	}
+	if info.Validation.LastFailure == nil || info.Validation.LastFailure.Direction != "publish" {
+		t.Errorf("last failure = %+v, want a publish failure", info.Validation.LastFailure)
+	}
+
+	warn := newTestTopicRegistry(t, TopicRegistryConfig{OnInvalidPublish: TopicOnInvalidWarn})
+	if err := warn.ValidatePublish("orders.created", []byte(`{}`)); err != nil {
+		t.Errorf("warn mode must not reject, got %v", err)
+	}
+}
+
+func TestTopicSchemaRegistry_AcceptDeliveryRoutesToDLQ(t *testing.T) {
+	r := newTestTopicRegistry(t, TopicRegistryConfig{})
+	dlq := evstore.NewInMemoryDLQStore()
+	r.SetDLQStore(dlq)
+
+	if !r.AcceptDelivery("orders.created", []byte(`{"order_id":"o-1"}`), "kafka") {
+		t.Fatal("valid message should be delivered")
+	}
+	if r.AcceptDelivery("orders.created", []byte(`{"total":3}`), "kafka") {
+		t.Fatal("invalid message should not be delivered")
+	}
+
+	entries, err := dlq.List(context.Background(), evstore.DLQFilter{})
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("DLQ entries = %d (err %v), want 1", len(entries), err)
+	}
+	entry := entries[0]
+	if entry.ErrorType != "schema_validation" || entry.Metadata["topic"] != "orders.created" || entry.Metadata["broker"] != "kafka" {
+		t.Errorf("unexpected DLQ entry: %+v", entry)
+	}
+	if violations, _ := entry.Metadata["validation_errors"].([]string); len(violations) == 0 {
+		t.Errorf("DLQ entry should carry the validation errors: %+v", entry.Metadata)
+	}
+	if string(entry.OriginalEvent) != `{"total":3}` {
+		t.Errorf("original event = %s", entry.OriginalEvent)
+	}
+
+	warn := newTestTopicRegistry(t, TopicRegistryConfig{OnInvalidDelivery: TopicOnInvalidWarn})
+	if !warn.AcceptDelivery("orders.created", []byte(`{}`), "nats") {
+		t.Error("warn mode should deliver invalid messages")
+	}
+}
+
+func TestTopicSchemaRegistry_InitErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  TopicRegistryConfig
+		want string
+	}{
+		{"bad publish mode", TopicRegistryConfig{OnInvalidPublish: "drop"}, "onInvalidPublish"},
+		{"bad delivery mode", TopicRegistryConfig{OnInvalidDelivery: "reject"}, "onInvalidDelivery"},
+		{"bad compatibility", TopicRegistryConfig{Topics: map[string]TopicSchemaConfig{"t": {Schema: orderCreatedSchema(), Compatibility: "full"}}}, "compatibility"},
+		{"missing schema", TopicRegistryConfig{Topics: map[string]TopicSchemaConfig{"t": {}}}, "'schema' is required"},
+		{"invalid schema", TopicRegistryConfig{Topics: map[string]TopicSchemaConfig{"t": {Schema: map[string]any{"type": 7}}}}, "invalid schema"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			if cfg.Topics == nil {
+				cfg.Topics = map[string]TopicSchemaConfig{"t": {Schema: orderCreatedSchema()}}
+			}
+			app, _ := NewTestApplication()
+			err := NewTopicSchemaRegistry("topics", cfg).Init(app)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Init error = %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchemaCompatibilityProblems(t *testing.T) {
+	withProps := func(required []any, props map[string]any, extra map[string]any) map[string]any {
+		s := map[string]any{"type": "object", "required": required, "properties": props}
+		for k, v := range extra {
+			s[k] = v
+		}
+		return s
+	}
+	str := map[string]any{"type": "string"}
+	num := map[string]any{"type": "number"}
+	integer := map[string]any{"type": "integer"}
+
+	tests := []struct {
+		name          string
+		mode          string
+		current, next map[string]any
+		want          string // substring of the first problem; empty for compatible
+	}{
+		{"backward: add optional field", TopicCompatibilityBackward,
+			withProps([]any{"id"}, map[string]any{"id": str}, nil),
+			withProps([]any{"id"}, map[string]any{"id": str, "note": str}, nil), ""},
+		{"backward: add required field", TopicCompatibilityBackward,
+			withProps([]any{"id"}, map[string]any{"id": str}, nil),
+			withProps([]any{"id", "note"}, map[string]any{"id": str, "note": str}, nil), `requires field "note"`},
+		{"backward: widen integer to number", TopicCompatibilityBackward,
+			withProps(nil, map[string]any{"qty": integer}, nil),
+			withProps(nil, map[string]any{"qty": num}, nil), ""},
+		{"backward: change field type", TopicCompatibilityBackward,
+			withProps(nil, map[string]any{"qty": num}, nil),
+			withProps(nil, map[string]any{"qty": str}, nil), `/qty: new schema rejects type "number"`},
+		{"backward: remove field from closed schema", TopicCompatibilityBackward,
+			withProps(nil, map[string]any{"id": str, "note": str}, map[string]any{"additionalProperties": false}),
+			withProps(nil, map[string]any{"id": str}, map[string]any{"additionalProperties": false}), `rejects field "note"`},
+		{"backward: remove enum value", TopicCompatibilityBackward,
+			map[string]any{"enum": []any{"a", "b"}}, map[string]any{"enum": []any{"a"}}, "rejects enum value b"},
+		{"forward: add required field", TopicCompatibilityForward,
+			withProps([]any{"id"}, map[string]any{"id": str}, nil),
+			withProps([]any{"id", "note"}, map[string]any{"id": str, "note": str}, nil), ""},
+		{"forward: drop required field", TopicCompatibilityForward,
+			withProps([]any{"id", "note"}, map[string]any{"id": str, "note": str}, nil),
+			withProps([]any{"id"}, map[string]any{"id": str, "note": str}, nil), `current schema requires field "note"`},
+		{"none: anything goes", TopicCompatibilityNone,
+			withProps(nil, map[string]any{"qty": num}, nil), map[string]any{"type": "string"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := SchemaCompatibilityProblems(tt.mode, tt.current, tt.next)
+			if tt.want == "" {
+				if len(problems) > 0 {
+					t.Errorf("expected compatible, got %v", problems)
+				}
+				return
+			}
+			if len(problems) == 0 || !strings.Contains(problems[0], tt.want) {
+				t.Errorf("problems = %v, want one containing %q", problems, tt.want)
+			}
+		})
+	}
+}
+
+func TestTopicRegistryAPIHandler(t *testing.T) {
+	r := newTestTopicRegistry(t, TopicRegistryConfig{})
+	r.SetEndpoints(map[string]TopicEndpoints{"orders.created": {Producers: []string{"pipeline:checkout/publish"}}})
+	h := NewTopicRegistryAPIHandler(r)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := do(http.MethodGet, "/api/v1/admin/topics", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"pipeline:checkout/publish"`) || !strings.Contains(rec.Body.String(), `"total":1`) {
+		t.Fatalf("list: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodGet, "/api/v1/admin/topics/orders.missing", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown topic: %d", rec.Code)
+	}
+
+	breaking := `{"schema":{"type":"object","required":["order_id","currency"],"properties":{"order_id":{"type":"string"}}}}`
+	rec = do(http.MethodPut, "/api/v1/admin/topics/orders.created/schema", breaking)
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), `requires field \"currency\"`) {
+		t.Fatalf("breaking change: %d %s", rec.Code, rec.Body.String())
+	}
+
+	compatible := `{"schema":{"type":"object","required":["order_id"],"properties":{"order_id":{"type":"string"},"currency":{"type":"string"}}}}`
+	rec = do(http.MethodPut, "/api/v1/admin/topics/orders.created/schema", compatible)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"version":2`) {
+		t.Fatalf("compatible change: %d %s", rec.Code, rec.Body.String())
+	}
+	if err := r.ValidatePublish("orders.created", []byte(`{"order_id":"o-1","currency":5}`)); err == nil {
+		t.Error("the updated schema should be enforced")
+	}
+
+	if rec := do(http.MethodPut, "/api/v1/admin/topics/orders.missing/schema", compatible); rec.Code != http.StatusNotFound {
+		t.Errorf("update of unknown topic: %d", rec.Code)
+	}
+	if rec := do(http.MethodPut, "/api/v1/admin/topics/orders.created/schema", `{}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("missing schema: %d", rec.Code)
+	}
+}
+
+func TestTopicRegistry_BrokerAndPublishStep(t *testing.T) {
+	r := newTestTopicRegistry(t, TopicRegistryConfig{})
+
+	broker := NewInMemoryMessageBroker("events")
+	broker.SetTopicRegistry(r)
+	if err := broker.SendMessage("orders.created", []byte(`{"total":1}`)); err == nil {
+		t.Error("broker producer should reject an invalid message")
+	}
+	if err := broker.SendMessage("orders.created", []byte(`{"order_id":"o-1"}`)); err != nil {
+		t.Errorf("broker producer rejected a valid message: %v", err)
+	}
+
+	// A broker without the registry attached is checked by the step.
+	mock := newMockBroker()
+	app := mockAppWithBroker("mock-broker", mock)
+	app.Services["topics"] = r
+	step, err := NewPublishStepFactory()("publish", map[string]any{
+		"topic":   "orders.created",
+		"broker":  "mock-broker",
+		"payload": map[string]any{"total": 1},
+	}, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = step.Execute(context.Background(), NewPipelineContext(nil, nil))
+	var schemaErr *TopicSchemaError
+	if !errors.As(err, &schemaErr) {
+		t.Fatalf("err = %v, want *TopicSchemaError", err)
+	}
+	if len(mock.producer.published) != 0 {
+		t.Error("an invalid message must not be published")
+	}
+}
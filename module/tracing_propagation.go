@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow/observability/tracing"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/propagation"
@@ -209,6 +210,7 @@ func (m *PipelineTracingMiddleware) Execute(ctx context.Context, pc *PipelineCon
 	ctx, span := m.tracer.Start(ctx, "pipeline.step."+m.step.Name(),
 		trace.WithSpanKind(trace.SpanKindInternal),
 		trace.WithAttributes(attribute.String("pipeline.step.name", m.step.Name())),
+		trace.WithAttributes(tracing.BaggageSpanAttributes(ctx)...),
 	)
 	defer span.End()
 
@@ -0,0 +1,455 @@
+package module
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	evstore "github.com/GoCodeAlone/workflow/store"
+	"github.com/google/uuid"
+)
+
+// Headers set on every webhook delivery. X-Workflow-Signature is the hex
+// HMAC-SHA256 of the request body keyed with the subscription secret, so
+// receivers can verify it with step.webhook_verify (provider: generic,
+// header: X-Workflow-Signature). The body carries the event ID and time,
+// which receivers should use to reject replays.
+const (
+	WebhookSignatureHeader = "X-Workflow-Signature"
+	WebhookEventHeader     = "X-Workflow-Event"
+	WebhookDeliveryHeader  = "X-Workflow-Delivery"
+)
+
+// maxConcurrentWebhookDeliveries bounds the HTTP requests in flight at once.
+const maxConcurrentWebhookDeliveries = 16
+
+// webhookDLQErrorType is the DLQ error type of dead-lettered deliveries.
+const webhookDLQErrorType = "webhook_delivery"
+
+// WebhookEvent is an event delivered to matching webhook subscriptions.
+// Data carries the fields subscription filters match on: "pipeline" and
+// "status" for executions, "state_machine", "transition" and "to_state" for
+// state transitions.
+type WebhookEvent struct {
+	ID         string         `json:"id"`
+	Type       string         `json:"type"`
+	WorkflowID string         `json:"workflow_id"`
+	Time       time.Time      `json:"created_at"`
+	Data       map[string]any `json:"data"`
+}
+
+// ExecutionWebhookEvent builds the event published when a pipeline execution
+// finishes with status "completed" or "failed".
+func ExecutionWebhookEvent(workflowID, executionID, pipeline, status, errMsg string, durationMs int64) WebhookEvent {
+	data := map[string]any{
+		"execution_id": executionID,
+		"pipeline":     pipeline,
+		"status":       status,
+		"duration_ms":  durationMs,
+	}
+	if errMsg != "" {
+		data["error"] = errMsg
+	}
+	return WebhookEvent{Type: "execution." + status, WorkflowID: workflowID, Data: data}
+}
+
+// TransitionWebhookEvent builds the event published when a state machine
+// instance commits a transition.
+func TransitionWebhookEvent(workflowID string, instance WorkflowInstance, event TransitionEvent) WebhookEvent {
+	data := map[string]any{
+		"instance_id":   instance.ID,
+		"state_machine": instance.WorkflowType,
+		"transition":    event.TransitionID,
+		"from_state":    event.FromState,
+		"to_state":      event.ToState,
+		"completed":     instance.Completed,
+	}
+	if len(event.Data) > 0 {
+		data["data"] = event.Data
+	}
+	return WebhookEvent{Type: WebhookEventStateTransition, WorkflowID: workflowID, Time: event.Timestamp, Data: data}
+}
+
+// WebhookDispatcher delivers workflow events to the webhook subscriptions
+// stored in a V1Store. Each matching subscription gets a delivery record that
+// is attempted in the background with exponential backoff; deliveries that
+// exhaust their attempts are marked dead_lettered and, when a DLQ store is
+// set, added to it. Unfinished deliveries are resumed by Start. Deliveries
+// never connect to private, loopback or link-local addresses unless
+// AllowPrivateIPs is called.
+type WebhookDispatcher struct {
+	store           *V1Store
+	dlq             evstore.DLQStore
+	client          *http.Client
+	logger          *slog.Logger
+	sem             chan struct{}
+	allowPrivateIPs bool
+
+	mu      sync.Mutex
+	stopCh  chan struct{}
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+// NewWebhookDispatcher creates a dispatcher for the subscriptions in store.
+func NewWebhookDispatcher(store *V1Store, logger *slog.Logger) *WebhookDispatcher {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &WebhookDispatcher{
+		store:  store,
+		client: newPublicHTTPClient(),
+		logger: logger,
+		sem:    make(chan struct{}, maxConcurrentWebhookDeliveries),
+		stopCh: make(chan struct{}),
+	}
+}
+
+// SetDLQStore sets the store that permanently failing deliveries are added to.
+func (d *WebhookDispatcher) SetDLQStore(dlq evstore.DLQStore) {
+	d.dlq = dlq
+}
+
+// newPublicHTTPClient returns a client whose connections are refused when
+// the target resolves to a private address. It ignores proxy settings, since
+// the check must see the target rather than the proxy.
+func newPublicHTTPClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   publicDialControl,
+	}).DialContext
+	return &http.Client{Transport: transport}
+}
+
+// SetClient sets a custom HTTP client (useful for testing). Per-subscription
+// timeouts are applied on top of it. The client is used as given, so it
+// bypasses the private address check of the default client.
+func (d *WebhookDispatcher) SetClient(client *http.Client) {
+	d.client = client
+}
+
+// AllowPrivateIPs lets subscriptions target private and loopback addresses,
+// disabling SSRF protection. Use it only for testing and development.
+func (d *WebhookDispatcher) AllowPrivateIPs() {
+	d.allowPrivateIPs = true
+	d.client = &http.Client{}
+}
+
+// validateSubscription is sub.Validate, permitting private targets when the
+// dispatcher allows them.
+func (d *WebhookDispatcher) validateSubscription(sub *WebhookSubscription) error {
+	return sub.validate(d.allowPrivateIPs)
+}
+
+// Start resumes the deliveries that were pending or retrying when the
+// previous dispatcher stopped.
+func (d *WebhookDispatcher) Start(_ context.Context) error {
+	unfinished, err := d.store.ListUnfinishedWebhookDeliveries()
+	if err != nil {
+		return fmt.Errorf("list unfinished webhook deliveries: %w", err)
+	}
+	for i := range unfinished {
+		d.schedule(&unfinished[i])
+	}
+	if len(unfinished) > 0 {
+		d.logger.Info("Resumed webhook deliveries", "count", len(unfinished))
+	}
+	return nil
+}
+
+// Stop abandons waiting retries, which stay recorded as retrying and are
+// resumed by the next Start, and waits for requests in flight to finish.
+func (d *WebhookDispatcher) Stop(_ context.Context) error {
+	d.mu.Lock()
+	if !d.stopped {
+		d.stopped = true
+		close(d.stopCh)
+	}
+	d.mu.Unlock()
+	d.wg.Wait()
+	return nil
+}
+
+// Publish records a delivery of ev for every enabled subscription on its
+// workflow or the workflow's project whose filter matches, and delivers them
+// in the background.
+func (d *WebhookDispatcher) Publish(_ context.Context, ev WebhookEvent) error {
+	subs, err := d.store.ListWebhookSubscriptionsForWorkflow(ev.WorkflowID)
+	if err != nil {
+		return fmt.Errorf("list webhook subscriptions: %w", err)
+	}
+	var payload []byte
+	for _, sub := range subs {
+		if !sub.Filter.Matches(ev.Type, ev.Data) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = d.encode(&ev); err != nil {
+				return err
+			}
+		}
+		delivery := &WebhookSubscriptionDelivery{
+			SubscriptionID: sub.ID,
+			EventID:        ev.ID,
+			EventType:      ev.Type,
+			Payload:        payload,
+			Status:         WebhookDeliveryPending,
+		}
+		if err := d.store.InsertWebhookDelivery(delivery); err != nil {
+			return fmt.Errorf("record webhook delivery for subscription %s: %w", sub.ID, err)
+		}
+		d.schedule(delivery)
+	}
+	return nil
+}
+
+// SendTest records a webhook.test event for a subscription, regardless of its
+// filter and enabled flag, and delivers it once in the background. It returns
+// the pending delivery without waiting, so the endpoint's response is only
+// visible in the delivery log. Test deliveries are not retried or
+// dead-lettered.
+func (d *WebhookDispatcher) SendTest(_ context.Context, subscriptionID string) (*WebhookSubscriptionDelivery, error) {
+	sub, err := d.store.GetWebhookSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	ev := WebhookEvent{
+		Type:       WebhookEventTest,
+		WorkflowID: sub.WorkflowID,
+		Data: map[string]any{
+			"subscription_id": sub.ID,
+			"message":         "Test event sent to verify the webhook endpoint and signature.",
+		},
+	}
+	payload, err := d.encode(&ev)
+	if err != nil {
+		return nil, err
+	}
+	delivery := &WebhookSubscriptionDelivery{
+		SubscriptionID: sub.ID,
+		EventID:        ev.ID,
+		EventType:      ev.Type,
+		Payload:        payload,
+		Status:         WebhookDeliveryPending,
+	}
+	if err := d.store.InsertWebhookDelivery(delivery); err != nil {
+		return nil, err
+	}
+
+	attempt := *delivery
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stopped {
+		return delivery, nil
+	}
+	d.wg.Go(func() {
+		policy, _ := sub.Delivery.policy()
+		d.attempt(context.Background(), sub, &attempt, policy.timeout)
+		if attempt.Status != WebhookDeliveryDelivered {
+			attempt.Status = WebhookDeliveryFailed
+		}
+		if err := d.store.UpdateWebhookDelivery(&attempt); err != nil {
+			d.logger.Warn("Failed to record webhook test delivery", "delivery", attempt.ID, "error", err)
+		}
+	})
+	return delivery, nil
+}
+
+// WatchStateMachine publishes every transition committed by engine as a
+// state.transition event of the given workflow.
+func (d *WebhookDispatcher) WatchStateMachine(engine *StateMachineEngine, workflowID string) {
+	engine.AddCommitListener(func(instance WorkflowInstance, event TransitionEvent) {
+		if err := d.Publish(context.Background(), TransitionWebhookEvent(workflowID, instance, event)); err != nil {
+			d.logger.Warn("Failed to publish state transition webhook", "instance", instance.ID, "error", err)
+		}
+	})
+}
+
+// encode assigns the event's ID and time if unset and marshals it.
+func (d *WebhookDispatcher) encode(ev *WebhookEvent) ([]byte, error) {
+	if ev.ID == "" {
+		ev.ID = uuid.New().String()
+	}
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	ev.Time = ev.Time.UTC()
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return nil, fmt.Errorf("encode webhook event: %w", err)
+	}
+	return payload, nil
+}
+
+// schedule delivers in the background unless the dispatcher is stopped, in
+// which case the delivery stays recorded for the next Start.
+func (d *WebhookDispatcher) schedule(delivery *WebhookSubscriptionDelivery) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stopped {
+		return
+	}
+	d.wg.Go(func() { d.deliver(delivery) })
+}
+
+// deliver attempts a delivery until it succeeds, exhausts its subscription's
+// attempts, or the dispatcher stops. The subscription is reloaded before each
+// attempt so URL and secret changes apply to retries; deliveries of deleted
+// or disabled subscriptions are abandoned.
+func (d *WebhookDispatcher) deliver(delivery *WebhookSubscriptionDelivery) {
+	for {
+		if !d.waitUntil(delivery.NextAttemptAt) {
+			return
+		}
+		sub, err := d.store.GetWebhookSubscription(delivery.SubscriptionID)
+		if err != nil {
+			if !isNotFound(err) {
+				d.logger.Warn("Failed to load webhook subscription", "subscription", delivery.SubscriptionID, "error", err)
+			}
+			return
+		}
+		if !sub.Enabled {
+			return
+		}
+		policy, err := sub.Delivery.policy()
+		if err != nil {
+			d.logger.Warn("Invalid webhook delivery settings; using defaults", "subscription", sub.ID, "error", err)
+		}
+
+		select {
+		case d.sem <- struct{}{}:
+		case <-d.stopCh:
+			return
+		}
+		d.attempt(context.Background(), sub, delivery, policy.timeout)
+		<-d.sem
+
+		switch {
+		case delivery.Status == WebhookDeliveryDelivered:
+		case delivery.Attempts >= policy.maxAttempts:
+			delivery.Status = WebhookDeliveryDeadLettered
+			delivery.NextAttemptAt = ""
+			d.deadLetter(sub, delivery)
+		default:
+			delivery.Status = WebhookDeliveryRetrying
+			delivery.NextAttemptAt = time.Now().Add(policy.backoff(delivery.Attempts)).UTC().Format(time.RFC3339Nano)
+		}
+		if err := d.store.UpdateWebhookDelivery(delivery); err != nil {
+			d.logger.Warn("Failed to record webhook delivery attempt", "delivery", delivery.ID, "error", err)
+		}
+		if delivery.Status != WebhookDeliveryRetrying {
+			return
+		}
+	}
+}
+
+// waitUntil waits for an RFC 3339 time, returning false if the dispatcher
+// stops first. An empty or unparsable time does not wait.
+func (d *WebhookDispatcher) waitUntil(at string) bool {
+	var wait time.Duration
+	if t, err := time.Parse(time.RFC3339Nano, at); err == nil {
+		wait = time.Until(t)
+	}
+	if wait <= 0 {
+		select {
+		case <-d.stopCh:
+			return false
+		default:
+			return true
+		}
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-d.stopCh:
+		return false
+	}
+}
+
+// attempt makes one signed POST of the delivery's payload and records the
+// outcome on delivery. A 2xx response marks it delivered.
+func (d *WebhookDispatcher) attempt(ctx context.Context, sub *WebhookSubscription, delivery *WebhookSubscriptionDelivery, timeout time.Duration) {
+	delivery.Attempts++
+	delivery.LastStatusCode = 0
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		delivery.LastError = fmt.Sprintf("create request: %v", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "workflow-webhooks")
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID)
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(sub.Secret, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		delivery.LastError = fmt.Sprintf("request failed: %v", err)
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	delivery.LastStatusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		delivery.LastError = fmt.Sprintf("unexpected status %d", resp.StatusCode)
+		return
+	}
+	delivery.Status = WebhookDeliveryDelivered
+	delivery.LastError = ""
+	delivery.DeliveredAt = nowStr()
+}
+
+// deadLetter adds a permanently failed delivery to the DLQ store, if set.
+func (d *WebhookDispatcher) deadLetter(sub *WebhookSubscription, delivery *WebhookSubscriptionDelivery) {
+	d.logger.Warn("Webhook delivery failed permanently",
+		"subscription", sub.ID, "delivery", delivery.ID, "attempts", delivery.Attempts, "error", delivery.LastError)
+	if d.dlq == nil {
+		return
+	}
+	now := time.Now()
+	entry := &evstore.DLQEntry{
+		ID:            uuid.New(),
+		OriginalEvent: delivery.Payload,
+		PipelineName:  "webhooks",
+		StepName:      sub.Name,
+		ErrorMessage:  delivery.LastError,
+		ErrorType:     webhookDLQErrorType,
+		RetryCount:    delivery.Attempts,
+		MaxRetries:    delivery.Attempts,
+		Status:        evstore.DLQStatusPending,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		Metadata: map[string]any{
+			"subscription_id": sub.ID,
+			"delivery_id":     delivery.ID,
+			"event_type":      delivery.EventType,
+			"url":             sub.URL,
+		},
+	}
+	if err := d.dlq.Add(context.Background(), entry); err != nil {
+		d.logger.Warn("Failed to add webhook delivery to DLQ", "delivery", delivery.ID, "error", err)
+	}
+}
+
+// SignWebhookPayload returns the X-Workflow-Signature value for payload: the
+// hex-encoded HMAC-SHA256 keyed with secret.
+func SignWebhookPayload(secret string, payload []byte) string {
+	return hex.EncodeToString(computeHMACSHA256([]byte(secret), payload))
+}
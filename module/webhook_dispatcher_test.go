@@ -0,0 +1,343 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	evstore "github.com/GoCodeAlone/workflow/store"
+	"github.com/stretchr/testify/require"
+)
+
+const testWebhookSecret = "whsec-0123456789abcdef"
+
+// webhookReceiver is a test endpoint that records deliveries and answers
+// with the next status in statuses (200 once they run out).
+type webhookReceiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []receivedWebhook
+}
+
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+}
+
+func newWebhookReceiver(t *testing.T, statuses ...int) (*webhookReceiver, *httptest.Server) {
+	rcv := &webhookReceiver{statuses: statuses}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		rcv.mu.Lock()
+		rcv.requests = append(rcv.requests, receivedWebhook{header: r.Header.Clone(), body: body})
+		status := http.StatusOK
+		if len(rcv.statuses) > 0 {
+			status, rcv.statuses = rcv.statuses[0], rcv.statuses[1:]
+		}
+		rcv.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return rcv, srv
+}
+
+func (rcv *webhookReceiver) received() []receivedWebhook {
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	return append([]receivedWebhook(nil), rcv.requests...)
+}
+
+func createTestSubscription(t *testing.T, store *V1Store, sub WebhookSubscription) *WebhookSubscription {
+	t.Helper()
+	if sub.Name == "" {
+		sub.Name = "partner"
+	}
+	sub.Secret = testWebhookSecret
+	sub.Enabled = true
+	// Test receivers listen on loopback, which Validate rejects.
+	require.NoError(t, sub.validate(true))
+	require.NoError(t, store.CreateWebhookSubscription(&sub))
+	return &sub
+}
+
+// waitForWebhookDelivery polls the delivery log until the subscription's
+// latest delivery reaches status.
+func waitForWebhookDelivery(t *testing.T, store *V1Store, subscriptionID, status string) WebhookSubscriptionDelivery {
+	t.Helper()
+	var last []WebhookSubscriptionDelivery
+	require.Eventually(t, func() bool {
+		var err error
+		last, err = store.ListWebhookDeliveries(subscriptionID, "", 1)
+		return err == nil && len(last) == 1 && last[0].Status == status
+	}, 5*time.Second, 5*time.Millisecond, "delivery never reached %q: %+v", status, last)
+	return last[0]
+}
+
+func startTestDispatcher(t *testing.T, store *V1Store) *WebhookDispatcher {
+	t.Helper()
+	d := NewWebhookDispatcher(store, nil)
+	d.AllowPrivateIPs()
+	require.NoError(t, d.Start(context.Background()))
+	t.Cleanup(func() { _ = d.Stop(context.Background()) })
+	return d
+}
+
+func TestWebhookDispatcher_DeliversSignedMatchingEvents(t *testing.T) {
+	store := setupTestStoreWithWorkflow(t, "wf-1")
+	rcv, srv := newWebhookReceiver(t)
+	sub := createTestSubscription(t, store, WebhookSubscription{
+		WorkflowID: "wf-1",
+		URL:        srv.URL,
+		Filter:     WebhookFilter{Pipelines: []string{"orders"}},
+	})
+	d := startTestDispatcher(t, store)
+
+	require.NoError(t, d.Publish(context.Background(), ExecutionWebhookEvent("wf-1", "exec-1", "billing", "completed", "", 3)))
+	require.NoError(t, d.Publish(context.Background(), ExecutionWebhookEvent("wf-1", "exec-2", "orders", "failed", "boom", 7)))
+	require.NoError(t, d.Publish(context.Background(), ExecutionWebhookEvent("wf-other", "exec-3", "orders", "completed", "", 1)))
+
+	delivery := waitForWebhookDelivery(t, store, sub.ID, WebhookDeliveryDelivered)
+	require.Equal(t, 1, delivery.Attempts)
+	require.Equal(t, http.StatusOK, delivery.LastStatusCode)
+
+	all, err := store.ListWebhookDeliveries(sub.ID, "", 0)
+	require.NoError(t, err)
+	require.Len(t, all, 1, "only the orders event of wf-1 matches")
+
+	got := rcv.received()
+	require.Len(t, got, 1)
+	require.Equal(t, SignWebhookPayload(testWebhookSecret, got[0].body), got[0].header.Get(WebhookSignatureHeader))
+	require.Equal(t, WebhookEventExecutionFailed, got[0].header.Get(WebhookEventHeader))
+	require.Equal(t, delivery.ID, got[0].header.Get(WebhookDeliveryHeader))
+
+	var payload map[string]any
+	require.NoError(t, json.Unmarshal(got[0].body, &payload))
+	require.Equal(t, delivery.EventID, payload["id"])
+	require.Equal(t, "wf-1", payload["workflow_id"])
+	require.Equal(t, map[string]any{
+		"execution_id": "exec-2", "pipeline": "orders", "status": "failed", "error": "boom", "duration_ms": float64(7),
+	}, payload["data"])
+}
+
+func TestWebhookDispatcher_ProjectSubscriptionsReceiveWorkflowEvents(t *testing.T) {
+	store := setupTestStoreWithWorkflow(t, "wf-1") // in project "test-project"
+	_, srv := newWebhookReceiver(t)
+	_, err := store.DB().Exec("PRAGMA foreign_keys = OFF")
+	require.NoError(t, err)
+	sub := createTestSubscription(t, store, WebhookSubscription{ProjectID: "test-project", URL: srv.URL})
+	_, err = store.DB().Exec("PRAGMA foreign_keys = ON")
+	require.NoError(t, err)
+	d := startTestDispatcher(t, store)
+
+	require.NoError(t, d.Publish(context.Background(), ExecutionWebhookEvent("wf-1", "exec-1", "orders", "completed", "", 1)))
+	waitForWebhookDelivery(t, store, sub.ID, WebhookDeliveryDelivered)
+}
+
+func TestWebhookDispatcher_RetriesThenDelivers(t *testing.T) {
+	store := setupTestStoreWithWorkflow(t, "wf-1")
+	rcv, srv := newWebhookReceiver(t, http.StatusInternalServerError, http.StatusBadGateway)
+	sub := createTestSubscription(t, store, WebhookSubscription{
+		WorkflowID: "wf-1",
+		URL:        srv.URL,
+		Delivery:   WebhookDeliverySettings{MaxAttempts: 5, InitialBackoff: "1ms", MaxBackoff: "5ms"},
+	})
+	d := startTestDispatcher(t, store)
+
+	require.NoError(t, d.Publish(context.Background(), ExecutionWebhookEvent("wf-1", "exec-1", "orders", "completed", "", 1)))
+	delivery := waitForWebhookDelivery(t, store, sub.ID, WebhookDeliveryDelivered)
+	require.Equal(t, 3, delivery.Attempts)
+	require.Empty(t, delivery.LastError)
+
+	got := rcv.received()
+	require.Len(t, got, 3)
+	require.Equal(t, got[0].body, got[2].body, "retries resend the same signed payload")
+}
+
+func TestWebhookDispatcher_DeadLettersPermanentFailures(t *testing.T) {
+	store := setupTestStoreWithWorkflow(t, "wf-1")
+	_, srv := newWebhookReceiver(t, 500, 500, 500, 500)
+	sub := createTestSubscription(t, store, WebhookSubscription{
+		WorkflowID: "wf-1",
+		Name:       "acme",
+		URL:        srv.URL,
+		Delivery:   WebhookDeliverySettings{MaxAttempts: 3, InitialBackoff: "1ms", MaxBackoff: "2ms"},
+	})
+	dlq := evstore.NewInMemoryDLQStore()
+	d := NewWebhookDispatcher(store, nil)
+	d.AllowPrivateIPs()
+	d.SetDLQStore(dlq)
+	t.Cleanup(func() { _ = d.Stop(context.Background()) })
+
+	require.NoError(t, d.Publish(context.Background(), ExecutionWebhookEvent("wf-1", "exec-1", "orders", "completed", "", 1)))
+	delivery := waitForWebhookDelivery(t, store, sub.ID, WebhookDeliveryDeadLettered)
+	require.Equal(t, 3, delivery.Attempts)
+	require.Equal(t, http.StatusInternalServerError, delivery.LastStatusCode)
+	require.Equal(t, "unexpected status 500", delivery.LastError)
+
+	entries, err := dlq.List(context.Background(), evstore.DLQFilter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, webhookDLQErrorType, entries[0].ErrorType)
+	require.Equal(t, "acme", entries[0].StepName)
+	require.Equal(t, delivery.ID, entries[0].Metadata["delivery_id"])
+	require.JSONEq(t, string(delivery.Payload), string(entries[0].OriginalEvent))
+}
+
+func TestWebhookDispatcher_StartResumesUnfinishedDeliveries(t *testing.T) {
+	store := setupTestStoreWithWorkflow(t, "wf-1")
+	rcv, srv := newWebhookReceiver(t)
+	sub := createTestSubscription(t, store, WebhookSubscription{WorkflowID: "wf-1", URL: srv.URL})
+	require.NoError(t, store.InsertWebhookDelivery(&WebhookSubscriptionDelivery{
+		SubscriptionID: sub.ID,
+		EventID:        "evt-1",
+		EventType:      WebhookEventExecutionCompleted,
+		Payload:        json.RawMessage(`{"id":"evt-1"}`),
+		Status:         WebhookDeliveryRetrying,
+		Attempts:       2,
+		NextAttemptAt:  time.Now().Add(-time.Minute).UTC().Format(time.RFC3339Nano),
+	}))
+
+	startTestDispatcher(t, store)
+	delivery := waitForWebhookDelivery(t, store, sub.ID, WebhookDeliveryDelivered)
+	require.Equal(t, 3, delivery.Attempts)
+	require.Len(t, rcv.received(), 1)
+}
+
+func TestWebhookDispatcher_SendTest(t *testing.T) {
+	store := setupTestStoreWithWorkflow(t, "wf-1")
+	rcv, srv := newWebhookReceiver(t, http.StatusUnauthorized)
+	sub := createTestSubscription(t, store, WebhookSubscription{
+		WorkflowID: "wf-1",
+		URL:        srv.URL,
+		Filter:     WebhookFilter{EventTypes: []string{WebhookEventStateTransition}},
+	})
+	d := startTestDispatcher(t, store)
+
+	delivery, err := d.SendTest(context.Background(), sub.ID)
+	require.NoError(t, err)
+	require.Equal(t, WebhookDeliveryPending, delivery.Status, "test events are sent in the background")
+	require.Zero(t, delivery.LastStatusCode)
+	failed := waitForWebhookDelivery(t, store, sub.ID, WebhookDeliveryFailed)
+	require.Equal(t, 1, failed.Attempts, "test events are attempted once")
+	require.Equal(t, http.StatusUnauthorized, failed.LastStatusCode)
+
+	_, err = d.SendTest(context.Background(), sub.ID)
+	require.NoError(t, err)
+	waitForWebhookDelivery(t, store, sub.ID, WebhookDeliveryDelivered)
+	require.Equal(t, WebhookEventTest, rcv.received()[1].header.Get(WebhookEventHeader))
+}
+
+func TestWebhookDispatcher_RefusesPrivateTargets(t *testing.T) {
+	store := setupTestStoreWithWorkflow(t, "wf-1")
+	rcv, srv := newWebhookReceiver(t)
+	sub := createTestSubscription(t, store, WebhookSubscription{WorkflowID: "wf-1", URL: srv.URL})
+	require.ErrorContains(t, sub.Validate(), "private")
+
+	// A target that resolved to a public address at creation is still
+	// checked at connection time.
+	d := NewWebhookDispatcher(store, nil)
+	require.NoError(t, d.Start(context.Background()))
+	t.Cleanup(func() { _ = d.Stop(context.Background()) })
+	_, err := d.SendTest(context.Background(), sub.ID)
+	require.NoError(t, err)
+	delivery := waitForWebhookDelivery(t, store, sub.ID, WebhookDeliveryFailed)
+	require.Contains(t, delivery.LastError, "not allowed")
+	require.Zero(t, delivery.LastStatusCode)
+	require.Empty(t, rcv.received())
+}
+
+func TestWebhookDispatcher_WatchStateMachine(t *testing.T) {
+	store := setupTestStoreWithWorkflow(t, "wf-1")
+	rcv, srv := newWebhookReceiver(t)
+	sub := createTestSubscription(t, store, WebhookSubscription{
+		WorkflowID: "wf-1",
+		URL:        srv.URL,
+		Filter:     WebhookFilter{StateMachines: []string{"order-workflow"}, States: []string{"shipped"}},
+	})
+	d := startTestDispatcher(t, store)
+
+	engine := NewStateMachineEngine("orders")
+	require.NoError(t, engine.RegisterDefinition(newTestDefinition()))
+	d.WatchStateMachine(engine, "wf-1")
+	_, err := engine.CreateWorkflow("order-workflow", "order-1", map[string]any{})
+	require.NoError(t, err)
+	require.NoError(t, engine.TriggerTransition(context.Background(), "order-1", "process", nil))
+	require.NoError(t, engine.TriggerTransition(context.Background(), "order-1", "ship", map[string]any{"carrier": "ups"}))
+
+	waitForWebhookDelivery(t, store, sub.ID, WebhookDeliveryDelivered)
+	require.NoError(t, engine.Stop(context.Background()))
+	got := rcv.received()
+	require.Len(t, got, 1, "only the transition into shipped matches")
+
+	var payload WebhookEvent
+	require.NoError(t, json.Unmarshal(got[0].body, &payload))
+	require.Equal(t, WebhookEventStateTransition, payload.Type)
+	require.Equal(t, "order-1", payload.Data["instance_id"])
+	require.Equal(t, "processing", payload.Data["from_state"])
+	require.Equal(t, map[string]any{"carrier": "ups"}, payload.Data["data"])
+}
+
+func TestTrackPipelineExecution_PublishesWebhooks(t *testing.T) {
+	store := setupTestStoreWithWorkflow(t, "wf-1")
+	rcv, srv := newWebhookReceiver(t)
+	sub := createTestSubscription(t, store, WebhookSubscription{WorkflowID: "wf-1", URL: srv.URL})
+	tracker := &ExecutionTracker{Store: store, WorkflowID: "wf-1"}
+	tracker.SetWebhookDispatcher(startTestDispatcher(t, store))
+	pipeline := &Pipeline{Name: "orders", Steps: []PipelineStep{newMockStep("a", map[string]any{})}}
+
+	_, err := tracker.TrackPipelineExecution(WithDryRun(context.Background()), pipeline, nil, httptest.NewRequest("POST", "/orders", nil))
+	require.NoError(t, err)
+	_, err = tracker.TrackPipelineExecution(context.Background(), pipeline, nil, httptest.NewRequest("POST", "/orders", nil))
+	require.NoError(t, err)
+
+	delivery := waitForWebhookDelivery(t, store, sub.ID, WebhookDeliveryDelivered)
+	require.Equal(t, WebhookEventExecutionCompleted, delivery.EventType)
+	require.Len(t, rcv.received(), 1, "dry runs are not published")
+}
+
+func TestWebhookFilter_Matches(t *testing.T) {
+	execution := ExecutionWebhookEvent("wf", "e", "orders", "completed", "", 1)
+	transition := TransitionWebhookEvent("wf", WorkflowInstance{ID: "i", WorkflowType: "order"},
+		TransitionEvent{TransitionID: "ship", FromState: "packed", ToState: "shipped"})
+
+	tests := []struct {
+		name       string
+		filter     WebhookFilter
+		execution  bool
+		transition bool
+	}{
+		{"empty matches all", WebhookFilter{}, true, true},
+		{"event type", WebhookFilter{EventTypes: []string{WebhookEventStateTransition}}, false, true},
+		{"pipeline", WebhookFilter{Pipelines: []string{"orders"}}, true, false},
+		{"other pipeline", WebhookFilter{Pipelines: []string{"billing"}}, false, false},
+		{"status", WebhookFilter{Statuses: []string{"failed"}}, false, false},
+		{"state", WebhookFilter{States: []string{"shipped"}}, false, true},
+		{"machine and transition", WebhookFilter{StateMachines: []string{"order"}, Transitions: []string{"ship"}}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.execution, tt.filter.Matches(execution.Type, execution.Data))
+			require.Equal(t, tt.transition, tt.filter.Matches(transition.Type, transition.Data))
+		})
+	}
+}
+
+func TestWebhookDeliverySettings_Backoff(t *testing.T) {
+	p, err := WebhookDeliverySettings{InitialBackoff: "1s", MaxBackoff: "5s"}.policy()
+	require.NoError(t, err)
+	require.Equal(t, 6, p.maxAttempts)
+	require.Equal(t, time.Second, p.backoff(1))
+	require.Equal(t, 2*time.Second, p.backoff(2))
+	require.Equal(t, 4*time.Second, p.backoff(3))
+	require.Equal(t, 5*time.Second, p.backoff(4))
+
+	_, err = WebhookDeliverySettings{InitialBackoff: "1m", MaxBackoff: "1s"}.policy()
+	require.Error(t, err)
+	_, err = WebhookDeliverySettings{Timeout: "soon"}.policy()
+	require.Error(t, err)
+}
@@ -5,9 +5,11 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"maps"
 	"math"
 	"net/http"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -170,7 +172,7 @@ func (ws *WebhookSender) Send(ctx context.Context, url string, payload []byte, h
 		ID:        id,
 		URL:       url,
 		Payload:   payload,
-		Headers:   headers,
+		Headers:   withRequestIDHeader(ctx, headers),
 		Status:    "pending",
 		Attempts:  0,
 		CreatedAt: time.Now(),
@@ -193,6 +195,26 @@ func (ws *WebhookSender) Send(ctx context.Context, url string, payload []byte, h
 	return delivery, nil
 }
 
+// withRequestIDHeader returns headers with the request ID of ctx added, so
+// that it is kept on the delivery for retries of dead letters too. A request
+// ID header already in headers is kept.
+func withRequestIDHeader(ctx context.Context, headers map[string]string) map[string]string {
+	id := GetRequestID(ctx)
+	if id == "" {
+		return headers
+	}
+	name := requestIDHeaderName(ctx)
+	for k := range headers {
+		if strings.EqualFold(k, name) {
+			return headers
+		}
+	}
+	out := make(map[string]string, len(headers)+1)
+	maps.Copy(out, headers)
+	out[name] = id
+	return out
+}
+
 // sendWithRetry attempts to deliver a webhook with exponential backoff
 func (ws *WebhookSender) sendWithRetry(ctx context.Context, delivery *WebhookDelivery) error {
 	var lastErr error
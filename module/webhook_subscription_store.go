@@ -0,0 +1,465 @@
+package module
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Webhook event types that subscriptions can filter on.
+const (
+	WebhookEventExecutionCompleted = "execution.completed"
+	WebhookEventExecutionFailed    = "execution.failed"
+	WebhookEventStateTransition    = "state.transition"
+	WebhookEventAIBudgetExceeded   = "ai.budget_exceeded"
+	WebhookEventTest               = "webhook.test"
+)
+
+// Webhook delivery statuses. A delivery is pending until its first attempt,
+// retrying while attempts remain, and ends delivered or dead_lettered. Test
+// deliveries are attempted once and end delivered or failed.
+const (
+	WebhookDeliveryPending      = "pending"
+	WebhookDeliveryRetrying     = "retrying"
+	WebhookDeliveryDelivered    = "delivered"
+	WebhookDeliveryDeadLettered = "dead_lettered"
+	WebhookDeliveryFailed       = "failed"
+)
+
+// minWebhookSecretLength is the shortest accepted HMAC signing secret.
+const minWebhookSecretLength = 16
+
+// WebhookSubscription registers a callback URL to be notified of events from
+// one workflow (WorkflowID) or from every workflow in a project (ProjectID).
+// The signing secret is write-only and is never serialized.
+type WebhookSubscription struct {
+	ID         string                  `json:"id"`
+	WorkflowID string                  `json:"workflow_id,omitempty"`
+	ProjectID  string                  `json:"project_id,omitempty"`
+	Name       string                  `json:"name"`
+	URL        string                  `json:"url"`
+	Secret     string                  `json:"-"`
+	Filter     WebhookFilter           `json:"filter"`
+	Delivery   WebhookDeliverySettings `json:"delivery"`
+	Enabled    bool                    `json:"enabled"`
+	CreatedBy  string                  `json:"created_by"`
+	CreatedAt  string                  `json:"created_at"`
+	UpdatedAt  string                  `json:"updated_at"`
+}
+
+// WebhookFilter selects the events a subscription receives. Every non-empty
+// list must contain the event's value, so a pipeline filter only matches
+// execution events and a state filter only matches transitions. An empty
+// filter matches every event.
+type WebhookFilter struct {
+	EventTypes    []string `json:"event_types,omitempty"`    // execution.completed, execution.failed, state.transition
+	Pipelines     []string `json:"pipelines,omitempty"`      // pipeline names
+	Statuses      []string `json:"statuses,omitempty"`       // execution statuses: completed, failed
+	StateMachines []string `json:"state_machines,omitempty"` // state machine definition names
+	Transitions   []string `json:"transitions,omitempty"`    // transition names
+	States        []string `json:"states,omitempty"`         // states entered
+}
+
+// Matches reports whether an event of the given type and data passes the filter.
+func (f WebhookFilter) Matches(eventType string, data map[string]any) bool {
+	field := func(key string) string {
+		v, _ := data[key].(string)
+		return v
+	}
+	return matchesAny(f.EventTypes, eventType) &&
+		matchesAny(f.Pipelines, field("pipeline")) &&
+		matchesAny(f.Statuses, field("status")) &&
+		matchesAny(f.StateMachines, field("state_machine")) &&
+		matchesAny(f.Transitions, field("transition")) &&
+		matchesAny(f.States, field("to_state"))
+}
+
+func matchesAny(allowed []string, value string) bool {
+	return len(allowed) == 0 || (value != "" && slices.Contains(allowed, value))
+}
+
+// WebhookDeliverySettings controls how a subscription's deliveries are
+// attempted. Durations are Go duration strings such as "30s"; zero values
+// take the defaults.
+type WebhookDeliverySettings struct {
+	MaxAttempts    int    `json:"max_attempts,omitempty"`    // default 6
+	InitialBackoff string `json:"initial_backoff,omitempty"` // default "10s"
+	MaxBackoff     string `json:"max_backoff,omitempty"`     // default "1h"
+	Timeout        string `json:"timeout,omitempty"`         // default "10s"
+}
+
+// webhookDeliveryPolicy is the parsed form of WebhookDeliverySettings.
+type webhookDeliveryPolicy struct {
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	timeout        time.Duration
+}
+
+// policy parses the settings and applies defaults.
+func (s WebhookDeliverySettings) policy() (webhookDeliveryPolicy, error) {
+	p := webhookDeliveryPolicy{
+		maxAttempts:    6,
+		initialBackoff: 10 * time.Second,
+		maxBackoff:     time.Hour,
+		timeout:        10 * time.Second,
+	}
+	if s.MaxAttempts < 0 || s.MaxAttempts > 20 {
+		return p, fmt.Errorf("max_attempts must be between 1 and 20")
+	}
+	if s.MaxAttempts > 0 {
+		p.maxAttempts = s.MaxAttempts
+	}
+	for _, d := range []struct {
+		key string
+		raw string
+		dst *time.Duration
+	}{
+		{"initial_backoff", s.InitialBackoff, &p.initialBackoff},
+		{"max_backoff", s.MaxBackoff, &p.maxBackoff},
+		{"timeout", s.Timeout, &p.timeout},
+	} {
+		if d.raw == "" {
+			continue
+		}
+		v, err := time.ParseDuration(d.raw)
+		if err != nil || v <= 0 {
+			return p, fmt.Errorf("%s must be a positive duration such as \"30s\"", d.key)
+		}
+		*d.dst = v
+	}
+	if p.initialBackoff > p.maxBackoff {
+		return p, fmt.Errorf("initial_backoff must not exceed max_backoff")
+	}
+	return p, nil
+}
+
+// backoff returns the wait after the given number of failed attempts:
+// initialBackoff doubled for each attempt after the first, capped at maxBackoff.
+func (p webhookDeliveryPolicy) backoff(attempts int) time.Duration {
+	d := p.initialBackoff
+	for i := 1; i < attempts && d < p.maxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, p.maxBackoff)
+}
+
+// Validate checks that the subscription can be stored and delivered. Its URL
+// must not target a private, loopback or link-local address.
+func (sub *WebhookSubscription) Validate() error {
+	return sub.validate(false)
+}
+
+// validate is Validate, permitting private targets when allowPrivate is set.
+func (sub *WebhookSubscription) validate(allowPrivate bool) error {
+	if (sub.WorkflowID == "") == (sub.ProjectID == "") {
+		return fmt.Errorf("subscription must be scoped to exactly one workflow or project")
+	}
+	if sub.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	u, err := url.Parse(sub.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https URL")
+	}
+	if !allowPrivate {
+		if err := validateWebhookHost(u.Hostname()); err != nil {
+			return fmt.Errorf("url: %w", err)
+		}
+	}
+	if len(sub.Secret) < minWebhookSecretLength {
+		return fmt.Errorf("secret must be at least %d characters", minWebhookSecretLength)
+	}
+	for _, t := range sub.Filter.EventTypes {
+		switch t {
+		case WebhookEventExecutionCompleted, WebhookEventExecutionFailed, WebhookEventStateTransition, WebhookEventAIBudgetExceeded:
+		default:
+			return fmt.Errorf("unknown event type %q", t)
+		}
+	}
+	for _, st := range sub.Filter.Statuses {
+		if st != "completed" && st != "failed" {
+			return fmt.Errorf("unknown execution status %q (must be completed or failed)", st)
+		}
+	}
+	if _, err := sub.Delivery.policy(); err != nil {
+		return fmt.Errorf("delivery: %w", err)
+	}
+	return nil
+}
+
+// validateWebhookHost rejects webhook hosts that are, or resolve to, private,
+// loopback or link-local addresses. A host that does not resolve yet is
+// accepted: the dispatcher checks every address it connects to.
+func validateWebhookHost(host string) error {
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("host %q is not allowed", host)
+	}
+	ips := []net.IP{net.ParseIP(host)}
+	if ips[0] == nil {
+		ips, _ = net.LookupIP(host)
+	}
+	for _, ip := range ips {
+		if ip.IsUnspecified() || isPrivateIP(ip) {
+			return fmt.Errorf("private/internal IP address is not allowed: %s resolves to %s", host, ip)
+		}
+	}
+	return nil
+}
+
+// WebhookSubscriptionDelivery records the delivery of one event to one
+// subscription.
+type WebhookSubscriptionDelivery struct {
+	ID             string          `json:"id"`
+	SubscriptionID string          `json:"subscription_id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	LastError      string          `json:"last_error,omitempty"`
+	LastStatusCode int             `json:"last_status_code,omitempty"`
+	NextAttemptAt  string          `json:"next_attempt_at,omitempty"`
+	CreatedAt      string          `json:"created_at"`
+	UpdatedAt      string          `json:"updated_at"`
+	DeliveredAt    string          `json:"delivered_at,omitempty"`
+}
+
+// --- Webhook subscriptions ---
+
+const webhookSubscriptionColumns = `id, COALESCE(workflow_id, ''), COALESCE(project_id, ''), name, url, secret, filter, delivery, enabled, created_by, created_at, updated_at`
+
+// CreateWebhookSubscription stores a new subscription, assigning its ID and
+// timestamps.
+func (s *V1Store) CreateWebhookSubscription(sub *WebhookSubscription) error {
+	filter, delivery, err := marshalWebhookSettings(sub)
+	if err != nil {
+		return err
+	}
+	now := nowStr()
+	sub.ID = newID()
+	sub.CreatedAt = now
+	sub.UpdatedAt = now
+	_, err = s.db.Exec(
+		`INSERT INTO webhook_subscriptions (id, workflow_id, project_id, name, url, secret, filter, delivery, enabled, created_by, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		sub.ID, nullIfEmpty(sub.WorkflowID), nullIfEmpty(sub.ProjectID), sub.Name, sub.URL, sub.Secret,
+		filter, delivery, boolToInt(sub.Enabled), sub.CreatedBy, sub.CreatedAt, sub.UpdatedAt,
+	)
+	return err
+}
+
+// GetWebhookSubscription retrieves a subscription by ID.
+func (s *V1Store) GetWebhookSubscription(id string) (*WebhookSubscription, error) {
+	return scanWebhookSubscription(s.db.QueryRow(
+		`SELECT `+webhookSubscriptionColumns+` FROM webhook_subscriptions WHERE id = ?`, id,
+	))
+}
+
+// ListWebhookSubscriptions lists the subscriptions scoped to a workflow, or to
+// a project when workflowID is empty.
+func (s *V1Store) ListWebhookSubscriptions(workflowID, projectID string) ([]WebhookSubscription, error) {
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions WHERE workflow_id = ? ORDER BY created_at, id`
+	arg := workflowID
+	if workflowID == "" {
+		query = `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions WHERE project_id = ? ORDER BY created_at, id`
+		arg = projectID
+	}
+	return s.queryWebhookSubscriptions(query, arg)
+}
+
+// ListWebhookSubscriptionsForWorkflow lists the enabled subscriptions that
+// receive a workflow's events: those on the workflow and those on its project.
+func (s *V1Store) ListWebhookSubscriptionsForWorkflow(workflowID string) ([]WebhookSubscription, error) {
+	return s.queryWebhookSubscriptions(
+		`SELECT `+webhookSubscriptionColumns+` FROM webhook_subscriptions
+		 WHERE enabled = 1 AND (workflow_id = ? OR project_id = (SELECT project_id FROM workflows WHERE id = ?))
+		 ORDER BY created_at, id`,
+		workflowID, workflowID,
+	)
+}
+
+// UpdateWebhookSubscription saves a subscription's name, URL, secret, filter,
+// delivery settings and enabled flag. Its scope cannot change.
+func (s *V1Store) UpdateWebhookSubscription(sub *WebhookSubscription) error {
+	filter, delivery, err := marshalWebhookSettings(sub)
+	if err != nil {
+		return err
+	}
+	sub.UpdatedAt = nowStr()
+	res, err := s.db.Exec(
+		`UPDATE webhook_subscriptions SET name = ?, url = ?, secret = ?, filter = ?, delivery = ?, enabled = ?, updated_at = ? WHERE id = ?`,
+		sub.Name, sub.URL, sub.Secret, filter, delivery, boolToInt(sub.Enabled), sub.UpdatedAt, sub.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeleteWebhookSubscription removes a subscription and its delivery log.
+func (s *V1Store) DeleteWebhookSubscription(id string) error {
+	res, err := s.db.Exec(`DELETE FROM webhook_subscriptions WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (s *V1Store) queryWebhookSubscriptions(query string, args ...any) ([]WebhookSubscription, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []WebhookSubscription
+	for rows.Next() {
+		sub, err := scanWebhookSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *sub)
+	}
+	return result, rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanWebhookSubscription(row rowScanner) (*WebhookSubscription, error) {
+	sub := &WebhookSubscription{}
+	var filter, delivery string
+	var enabled int
+	if err := row.Scan(&sub.ID, &sub.WorkflowID, &sub.ProjectID, &sub.Name, &sub.URL, &sub.Secret,
+		&filter, &delivery, &enabled, &sub.CreatedBy, &sub.CreatedAt, &sub.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(filter), &sub.Filter); err != nil {
+		return nil, fmt.Errorf("webhook subscription %s: decode filter: %w", sub.ID, err)
+	}
+	if err := json.Unmarshal([]byte(delivery), &sub.Delivery); err != nil {
+		return nil, fmt.Errorf("webhook subscription %s: decode delivery settings: %w", sub.ID, err)
+	}
+	sub.Enabled = enabled == 1
+	return sub, nil
+}
+
+func marshalWebhookSettings(sub *WebhookSubscription) (filter, delivery string, err error) {
+	f, err := json.Marshal(sub.Filter)
+	if err != nil {
+		return "", "", err
+	}
+	d, err := json.Marshal(sub.Delivery)
+	if err != nil {
+		return "", "", err
+	}
+	return string(f), string(d), nil
+}
+
+// --- Webhook deliveries ---
+
+const webhookDeliveryColumns = `id, subscription_id, event_id, event_type, payload, status, attempts, last_error, last_status_code, next_attempt_at, created_at, updated_at, delivered_at`
+
+// InsertWebhookDelivery stores a new delivery, assigning its ID and timestamps.
+func (s *V1Store) InsertWebhookDelivery(d *WebhookSubscriptionDelivery) error {
+	now := nowStr()
+	d.ID = newID()
+	d.CreatedAt = now
+	d.UpdatedAt = now
+	_, err := s.db.Exec(
+		`INSERT INTO webhook_deliveries (`+webhookDeliveryColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		d.ID, d.SubscriptionID, d.EventID, d.EventType, string(d.Payload), d.Status, d.Attempts,
+		d.LastError, d.LastStatusCode, d.NextAttemptAt, d.CreatedAt, d.UpdatedAt, d.DeliveredAt,
+	)
+	return err
+}
+
+// UpdateWebhookDelivery saves a delivery's status and attempt bookkeeping.
+func (s *V1Store) UpdateWebhookDelivery(d *WebhookSubscriptionDelivery) error {
+	d.UpdatedAt = nowStr()
+	_, err := s.db.Exec(
+		`UPDATE webhook_deliveries SET status = ?, attempts = ?, last_error = ?, last_status_code = ?, next_attempt_at = ?, updated_at = ?, delivered_at = ? WHERE id = ?`,
+		d.Status, d.Attempts, d.LastError, d.LastStatusCode, d.NextAttemptAt, d.UpdatedAt, d.DeliveredAt, d.ID,
+	)
+	return err
+}
+
+// ListWebhookDeliveries lists a subscription's deliveries, newest first,
+// optionally filtered by status. A limit of 0 or less means 100.
+func (s *V1Store) ListWebhookDeliveries(subscriptionID, status string, limit int) ([]WebhookSubscriptionDelivery, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE subscription_id = ?`
+	args := []any{subscriptionID}
+	if status != "" {
+		query += ` AND status = ?`
+		args = append(args, status)
+	}
+	query += ` ORDER BY created_at DESC, rowid DESC LIMIT ?`
+	args = append(args, limit)
+	return s.queryWebhookDeliveries(query, args...)
+}
+
+// ListUnfinishedWebhookDeliveries lists the deliveries still pending or
+// retrying, oldest first, so a dispatcher can resume them after a restart.
+func (s *V1Store) ListUnfinishedWebhookDeliveries() ([]WebhookSubscriptionDelivery, error) {
+	return s.queryWebhookDeliveries(
+		`SELECT `+webhookDeliveryColumns+` FROM webhook_deliveries WHERE status IN (?, ?) ORDER BY created_at, rowid`,
+		WebhookDeliveryPending, WebhookDeliveryRetrying,
+	)
+}
+
+func (s *V1Store) queryWebhookDeliveries(query string, args ...any) ([]WebhookSubscriptionDelivery, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []WebhookSubscriptionDelivery
+	for rows.Next() {
+		var d WebhookSubscriptionDelivery
+		var payload string
+		if err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &payload, &d.Status, &d.Attempts,
+			&d.LastError, &d.LastStatusCode, &d.NextAttemptAt, &d.CreatedAt, &d.UpdatedAt, &d.DeliveredAt); err != nil {
+			return nil, err
+		}
+		d.Payload = json.RawMessage(payload)
+		result = append(result, d)
+	}
+	return result, rows.Err()
+}
+
+// isNotFound reports whether err is the store's not-found error.
+func isNotFound(err error) bool {
+	return errors.Is(err, sql.ErrNoRows)
+}
+
+func nullIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
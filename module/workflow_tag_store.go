@@ -0,0 +1,139 @@
+package module
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// workflowTagRE matches valid workflow tags: lowercase letters, digits and
+// the separators . _ : -, starting with a letter or digit.
+var workflowTagRE = regexp.MustCompile(`^[a-z0-9][a-z0-9._:-]{0,62}$`)
+
+// normalizeWorkflowTags lowercases, trims, validates, sorts and de-duplicates
+// tags.
+func normalizeWorkflowTags(tags []string) ([]string, error) {
+	out := make([]string, 0, len(tags))
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if !workflowTagRE.MatchString(t) {
+			return nil, fmt.Errorf("invalid tag %q: tags are 1-63 characters of a-z, 0-9, '.', '_', ':' or '-'", t)
+		}
+		out = append(out, t)
+	}
+	slices.Sort(out)
+	return slices.Compact(out), nil
+}
+
+// WorkflowTags returns a workflow's tags in sorted order.
+func (s *V1Store) WorkflowTags(workflowID string) ([]string, error) {
+	rows, err := s.db.Query(`SELECT tag FROM workflow_tags WHERE workflow_id = ? ORDER BY tag`, workflowID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []string
+	for rows.Next() {
+		var t string
+		if err := rows.Scan(&t); err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+	return tags, rows.Err()
+}
+
+// attachWorkflowTags fills in the tags of wfs with a single query.
+func (s *V1Store) attachWorkflowTags(wfs []V1Workflow) error {
+	if len(wfs) == 0 {
+		return nil
+	}
+	rows, err := s.db.Query(`SELECT workflow_id, tag FROM workflow_tags ORDER BY tag`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	byWorkflow := make(map[string][]string)
+	for rows.Next() {
+		var id, t string
+		if err := rows.Scan(&id, &t); err != nil {
+			return err
+		}
+		byWorkflow[id] = append(byWorkflow[id], t)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for i := range wfs {
+		wfs[i].Tags = byWorkflow[wfs[i].ID]
+	}
+	return nil
+}
+
+// SetWorkflowTags replaces a workflow's tags and returns the stored set.
+func (s *V1Store) SetWorkflowTags(workflowID string, tags []string) ([]string, error) {
+	tags, err := normalizeWorkflowTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`DELETE FROM workflow_tags WHERE workflow_id = ?`, workflowID); err != nil {
+		return nil, err
+	}
+	now := nowStr()
+	for _, t := range tags {
+		if _, err := tx.Exec(`INSERT INTO workflow_tags (workflow_id, tag, created_at) VALUES (?, ?, ?)`, workflowID, t, now); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return s.WorkflowTags(workflowID)
+}
+
+// AddWorkflowTags adds tags to a workflow, keeping the ones it already has,
+// and returns the stored set.
+func (s *V1Store) AddWorkflowTags(workflowID string, tags ...string) ([]string, error) {
+	tags, err := normalizeWorkflowTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	now := nowStr()
+	for _, t := range tags {
+		if _, err := s.db.Exec(`INSERT OR IGNORE INTO workflow_tags (workflow_id, tag, created_at) VALUES (?, ?, ?)`, workflowID, t, now); err != nil {
+			return nil, err
+		}
+	}
+	return s.WorkflowTags(workflowID)
+}
+
+// RemoveWorkflowTags removes tags from a workflow and returns the remaining
+// set. Tags the workflow does not have are ignored.
+func (s *V1Store) RemoveWorkflowTags(workflowID string, tags ...string) ([]string, error) {
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if _, err := s.db.Exec(`DELETE FROM workflow_tags WHERE workflow_id = ? AND tag = ?`, workflowID, t); err != nil {
+			return nil, err
+		}
+	}
+	return s.WorkflowTags(workflowID)
+}
+
+// hasAllTags reports whether wf carries every tag in tags.
+func (wf *V1Workflow) hasAllTags(tags []string) bool {
+	for _, t := range tags {
+		if !slices.Contains(wf.Tags, t) {
+			return false
+		}
+	}
+	return true
+}
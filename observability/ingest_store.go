@@ -3,6 +3,8 @@ package observability
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -26,6 +28,16 @@ func NewV1IngestStore(db *sql.DB) *V1IngestStore {
 			registered_at TEXT NOT NULL
 		)
 	`)
+	_, _ = db.Exec(`
+		CREATE TABLE IF NOT EXISTS instance_health (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			instance    TEXT NOT NULL,
+			status      TEXT NOT NULL,
+			reported_at TEXT NOT NULL,
+			report      TEXT NOT NULL
+		)
+	`)
+	_, _ = db.Exec(`CREATE INDEX IF NOT EXISTS idx_instance_health_instance ON instance_health (instance, id)`)
 	return &V1IngestStore{db: db}
 }
 
@@ -108,8 +120,9 @@ func (s *V1IngestStore) IngestEvents(_ context.Context, _ string, items []EventR
 	for _, ev := range items {
 		fieldsJSON := "{}"
 		if ev.EventData != nil {
-			// Best effort
-			fieldsJSON = "{}"
+			if raw, err := json.Marshal(ev.EventData); err == nil {
+				fieldsJSON = string(raw)
+			}
 		}
 		// Use execution_id as workflow_id fallback (we'll need both in production)
 		_, _ = stmt.Exec(
@@ -138,6 +151,84 @@ func (s *V1IngestStore) Heartbeat(_ context.Context, name string, timestamp time
 	return err
 }
 
+// ErrNoInstanceHealth is returned by InstanceHealth for an instance that has
+// not reported any health.
+var ErrNoInstanceHealth = errors.New("no health reports for instance")
+
+// instanceHealthRetention is how many health reports are kept per instance.
+const instanceHealthRetention = 20
+
+// RecordHealth stores a health report and prunes the instance's history to
+// the most recent reports.
+func (s *V1IngestStore) RecordHealth(_ context.Context, report InstanceHealthReport) error {
+	if report.ReportedAt.IsZero() {
+		report.ReportedAt = time.Now()
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(
+		`INSERT INTO instance_health (instance, status, reported_at, report) VALUES (?, ?, ?, ?)`,
+		report.Instance, report.Status, report.ReportedAt.UTC().Format(time.RFC3339Nano), string(data),
+	); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(
+		`DELETE FROM instance_health WHERE instance = ? AND id NOT IN (
+			SELECT id FROM instance_health WHERE instance = ? ORDER BY id DESC LIMIT ?)`,
+		report.Instance, report.Instance, instanceHealthRetention,
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// InstanceHealth returns the latest health report for an instance and up to
+// limit reports of history, newest first. A latest report older than
+// DefaultStaleHeartbeats intervals at now is returned with status unknown.
+// It returns ErrNoInstanceHealth when the instance has never reported.
+func (s *V1IngestStore) InstanceHealth(instance string, limit int, now time.Time) (*InstanceHealth, error) {
+	if limit <= 0 || limit > instanceHealthRetention {
+		limit = instanceHealthRetention
+	}
+	rows, err := s.db.Query(
+		`SELECT report FROM instance_health WHERE instance = ? ORDER BY id DESC LIMIT ?`,
+		instance, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []InstanceHealthReport
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var report InstanceHealthReport
+		if err := json.Unmarshal([]byte(data), &report); err != nil {
+			continue
+		}
+		history = append(history, report)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(history) == 0 {
+		return nil, ErrNoInstanceHealth
+	}
+
+	return &InstanceHealth{Latest: history[0].AsOf(now, DefaultStaleHeartbeats), History: history}, nil
+}
+
 // ListInstances returns all registered worker instances.
 func (s *V1IngestStore) ListInstances() ([]WorkerInstance, error) {
 	rows, err := s.db.Query("SELECT name, status, last_seen, registered_at FROM worker_instances ORDER BY name")
@@ -165,8 +256,11 @@ type WorkerInstance struct {
 	RegisteredAt string `json:"registered_at"`
 }
 
-// Ensure V1IngestStore implements IngestStore.
-var _ IngestStore = (*V1IngestStore)(nil)
+// Ensure V1IngestStore implements IngestStore and InstanceHealthStore.
+var (
+	_ IngestStore         = (*V1IngestStore)(nil)
+	_ InstanceHealthStore = (*V1IngestStore)(nil)
+)
 
 // Ensure uuid is used (for future event ID generation).
 var _ = uuid.New
@@ -0,0 +1,175 @@
+package observability
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"time"
+)
+
+// Instance health statuses. The first three come from probes; unknown is
+// assigned by the admin when an instance stops reporting.
+const (
+	InstanceHealthy   = "healthy"
+	InstanceDegraded  = "degraded"
+	InstanceUnhealthy = "unhealthy"
+	InstanceUnknown   = "unknown"
+)
+
+// DefaultStaleHeartbeats is how many heartbeat intervals may pass without a
+// report before an instance's health is reported as unknown.
+const DefaultStaleHeartbeats = 3
+
+// InstanceHealthReport is the health summary an instance sends with each
+// heartbeat. Local instances run by the RuntimeManager and remote workers
+// running a Reporter send the same payload.
+type InstanceHealthReport struct {
+	// Instance identifies the instance: the runtime instance ID for local
+	// instances, the reporter's instance name for remote workers.
+	Instance string `json:"instance"`
+	// Name is a display name, e.g. the workflow name.
+	Name                string            `json:"name,omitempty"`
+	Status              string            `json:"status"`
+	Message             string            `json:"message,omitempty"`
+	LastProbeAt         time.Time         `json:"last_probe_at,omitzero"`
+	ConsecutiveFailures int               `json:"consecutive_failures"`
+	Modules             map[string]string `json:"modules,omitempty"`
+	// IntervalMs is the sender's heartbeat interval, used to detect stale
+	// reports.
+	IntervalMs int64     `json:"interval_ms,omitempty"`
+	ReportedAt time.Time `json:"reported_at"`
+}
+
+// Stale reports whether more than intervals heartbeat intervals have passed
+// since the report was sent. Reports without an interval never go stale.
+func (r InstanceHealthReport) Stale(now time.Time, intervals int) bool {
+	if r.IntervalMs <= 0 || r.ReportedAt.IsZero() {
+		return false
+	}
+	window := time.Duration(r.IntervalMs) * time.Millisecond * time.Duration(intervals)
+	return now.Sub(r.ReportedAt) > window
+}
+
+// AsOf returns the report as it should be shown at now: unchanged, or with
+// status unknown when it is stale by the given number of intervals.
+func (r InstanceHealthReport) AsOf(now time.Time, intervals int) InstanceHealthReport {
+	if !r.Stale(now, intervals) {
+		return r
+	}
+	r.Status = InstanceUnknown
+	r.Message = fmt.Sprintf("no health report within %s", time.Duration(r.IntervalMs)*time.Millisecond*time.Duration(intervals))
+	return r
+}
+
+// InstanceHealthStore is implemented by ingest stores that keep instance
+// health reports. The ingest handler records the health carried by
+// heartbeats when its store implements it.
+type InstanceHealthStore interface {
+	RecordHealth(ctx context.Context, report InstanceHealthReport) error
+}
+
+// InstanceHealth is the latest health report for an instance plus recent
+// history, newest first.
+type InstanceHealth struct {
+	Latest  InstanceHealthReport   `json:"latest"`
+	History []InstanceHealthReport `json:"history"`
+}
+
+// HealthProbe is the outcome of probing one health endpoint.
+type HealthProbe struct {
+	Status  string
+	Message string
+	// Checks are the individual check statuses the endpoint returned, when
+	// it serves the health checker's JSON format.
+	Checks map[string]string
+}
+
+// ProbeHealth GETs a health endpoint. A health checker response
+// ({"status": ..., "checks": {...}}) is reported as is; any other HTTP
+// response below 500 means the server is serving and counts as healthy, so
+// servers without a health checker still report. Connection errors and 5xx
+// responses without a health body are unhealthy.
+func ProbeHealth(ctx context.Context, client *http.Client, url string) HealthProbe {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return HealthProbe{Status: InstanceUnhealthy, Message: err.Error()}
+	}
+	resp, err := client.Do(req) //nolint:gosec // G704: URL built from the instance's own server address
+	if err != nil {
+		return HealthProbe{Status: InstanceUnhealthy, Message: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Status string `json:"status"`
+		Checks map[string]struct {
+			Status string `json:"status"`
+		} `json:"checks"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if json.Unmarshal(data, &body) == nil && body.Status != "" && body.Checks != nil {
+		probe := HealthProbe{Status: body.Status, Checks: make(map[string]string, len(body.Checks))}
+		for name, c := range body.Checks {
+			probe.Checks[name] = c.Status
+		}
+		return probe
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return HealthProbe{Status: InstanceUnhealthy, Message: fmt.Sprintf("health endpoint returned %d", resp.StatusCode)}
+	}
+	return HealthProbe{Status: InstanceHealthy}
+}
+
+// WorstStatus returns the more severe of two health statuses.
+func WorstStatus(a, b string) string {
+	rank := func(s string) int {
+		switch s {
+		case InstanceHealthy:
+			return 0
+		case InstanceDegraded:
+			return 1
+		case InstanceUnknown:
+			return 2
+		default:
+			return 3
+		}
+	}
+	if rank(b) > rank(a) {
+		return b
+	}
+	return a
+}
+
+// HealthTracker turns probe results for one instance into reports, counting
+// consecutive unhealthy probes.
+type HealthTracker struct {
+	failures int
+}
+
+// Report builds the report for a probe taken at now.
+func (t *HealthTracker) Report(instance, name string, probe HealthProbe, modules map[string]string, interval time.Duration, now time.Time) InstanceHealthReport {
+	if probe.Status == InstanceUnhealthy {
+		t.failures++
+	} else {
+		t.failures = 0
+	}
+	report := InstanceHealthReport{
+		Instance:            instance,
+		Name:                name,
+		Status:              probe.Status,
+		Message:             probe.Message,
+		LastProbeAt:         now,
+		ConsecutiveFailures: t.failures,
+		IntervalMs:          interval.Milliseconds(),
+		ReportedAt:          now,
+	}
+	if len(modules) > 0 || len(probe.Checks) > 0 {
+		report.Modules = make(map[string]string, len(modules)+len(probe.Checks))
+		maps.Copy(report.Modules, probe.Checks)
+		maps.Copy(report.Modules, modules)
+	}
+	return report
+}
@@ -0,0 +1,225 @@
+package observability
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+func newTestIngestStore(t *testing.T) *V1IngestStore {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "ingest.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewV1IngestStore(db)
+}
+
+func TestProbeHealth(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/checker", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"status":"degraded","checks":{"db":{"status":"degraded","message":"slow"}}}`))
+	})
+	mux.HandleFunc("/broken", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	ctx := context.Background()
+
+	probe := ProbeHealth(ctx, srv.Client(), srv.URL+"/checker")
+	if probe.Status != InstanceDegraded || probe.Checks["db"] != InstanceDegraded {
+		t.Errorf("health checker probe = %+v, want degraded with db check", probe)
+	}
+	// A server without a health checker still counts as serving.
+	if probe := ProbeHealth(ctx, srv.Client(), srv.URL+"/missing"); probe.Status != InstanceHealthy {
+		t.Errorf("404 probe = %+v, want healthy", probe)
+	}
+	if probe := ProbeHealth(ctx, srv.Client(), srv.URL+"/broken"); probe.Status != InstanceUnhealthy || !strings.Contains(probe.Message, "502") {
+		t.Errorf("502 probe = %+v, want unhealthy", probe)
+	}
+
+	srv.Close()
+	if probe := ProbeHealth(ctx, http.DefaultClient, srv.URL+"/checker"); probe.Status != InstanceUnhealthy || probe.Message == "" {
+		t.Errorf("closed server probe = %+v, want unhealthy with error", probe)
+	}
+}
+
+func TestHealthTracker_CountsConsecutiveFailures(t *testing.T) {
+	t.Parallel()
+
+	var tracker HealthTracker
+	now := time.Now()
+	down := HealthProbe{Status: InstanceUnhealthy, Message: "connection refused"}
+	tracker.Report("i1", "app", down, nil, time.Second, now)
+	r := tracker.Report("i1", "app", down, map[string]string{"server": InstanceUnhealthy}, time.Second, now)
+	if r.ConsecutiveFailures != 2 || r.IntervalMs != 1000 || r.Modules["server"] != InstanceUnhealthy {
+		t.Errorf("report = %+v", r)
+	}
+	r = tracker.Report("i1", "app", HealthProbe{Status: InstanceDegraded}, nil, time.Second, now)
+	if r.ConsecutiveFailures != 0 {
+		t.Errorf("ConsecutiveFailures = %d after a degraded probe, want 0", r.ConsecutiveFailures)
+	}
+}
+
+func TestInstanceHealthReport_AsOf(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := InstanceHealthReport{Status: InstanceHealthy, IntervalMs: 1000, ReportedAt: at}
+	if got := r.AsOf(at.Add(3*time.Second), 3); got.Status != InstanceHealthy {
+		t.Errorf("status after 3 intervals = %q, want healthy", got.Status)
+	}
+	got := r.AsOf(at.Add(3*time.Second+time.Millisecond), 3)
+	if got.Status != InstanceUnknown || got.Message != "no health report within 3s" {
+		t.Errorf("stale report = %+v, want unknown", got)
+	}
+	if r.Status != InstanceHealthy {
+		t.Error("AsOf modified the receiver")
+	}
+	r.IntervalMs = 0
+	if got := r.AsOf(at.Add(time.Hour), 3); got.Status != InstanceHealthy {
+		t.Error("report without an interval should never go stale")
+	}
+}
+
+func TestV1IngestStore_InstanceHealth(t *testing.T) {
+	t.Parallel()
+
+	store := newTestIngestStore(t)
+	ctx := context.Background()
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if _, err := store.InstanceHealth("i1", 5, start); !errors.Is(err, ErrNoInstanceHealth) {
+		t.Fatalf("err = %v, want ErrNoInstanceHealth", err)
+	}
+
+	for i := range instanceHealthRetention + 5 {
+		status := InstanceHealthy
+		if i%2 == 1 {
+			status = InstanceUnhealthy
+		}
+		report := InstanceHealthReport{
+			Instance: "i1", Status: status, ConsecutiveFailures: i,
+			IntervalMs: 1000, ReportedAt: start.Add(time.Duration(i) * time.Second),
+		}
+		if err := store.RecordHealth(ctx, report); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.RecordHealth(ctx, InstanceHealthReport{Instance: "i2", Status: InstanceHealthy, ReportedAt: start}); err != nil {
+		t.Fatal(err)
+	}
+
+	last := start.Add(time.Duration(instanceHealthRetention+4) * time.Second)
+	health, err := store.InstanceHealth("i1", 5, last)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(health.History) != 5 || health.History[0].ConsecutiveFailures != instanceHealthRetention+4 {
+		t.Fatalf("history = %+v, want the 5 newest reports", health.History)
+	}
+	if health.Latest.Status != InstanceHealthy {
+		t.Errorf("latest status = %q, want healthy", health.Latest.Status)
+	}
+
+	var kept int
+	if err := store.db.QueryRow(`SELECT COUNT(*) FROM instance_health WHERE instance = 'i1'`).Scan(&kept); err != nil {
+		t.Fatal(err)
+	}
+	if kept != instanceHealthRetention {
+		t.Errorf("kept %d reports, want %d", kept, instanceHealthRetention)
+	}
+
+	// Three missed heartbeats flip the latest report to unknown.
+	health, err = store.InstanceHealth("i1", 5, last.Add(4*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if health.Latest.Status != InstanceUnknown || health.History[0].Status != InstanceHealthy {
+		t.Errorf("stale latest = %q, history[0] = %q; want unknown and the stored status", health.Latest.Status, health.History[0].Status)
+	}
+}
+
+func TestIngestHandler_HeartbeatRecordsHealth(t *testing.T) {
+	t.Parallel()
+
+	store := newTestIngestStore(t)
+	handler := NewIngestHandler(store, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	body := `{"instance_name":"worker-1","timestamp":"2026-01-01T00:00:00Z","health":{"status":"degraded","consecutive_failures":0,"modules":{"db":"degraded"},"interval_ms":30000}}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/instances/heartbeat", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+
+	health, err := store.InstanceHealth("worker-1", 1, time.Date(2026, 1, 1, 0, 0, 1, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	if l := health.Latest; l.Instance != "worker-1" || l.Status != InstanceDegraded || l.Modules["db"] != InstanceDegraded || !l.ReportedAt.Equal(want) {
+		t.Errorf("latest = %+v", l)
+	}
+}
+
+func TestReporter_HeartbeatCarriesHealth(t *testing.T) {
+	t.Parallel()
+
+	app := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer app.Close()
+
+	heartbeats := make(chan map[string]json.RawMessage, 2)
+	admin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/admin/instances/heartbeat" {
+			var payload map[string]json.RawMessage
+			_ = json.NewDecoder(r.Body).Decode(&payload)
+			heartbeats <- payload
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer admin.Close()
+
+	r := NewReporter(ReporterConfig{
+		AdminURL:          admin.URL,
+		InstanceName:      "worker-1",
+		HeartbeatInterval: time.Minute,
+		HealthURL:         app.URL + "/healthz",
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	for want := 1; want <= 2; want++ {
+		r.heartbeat(context.Background())
+		payload := <-heartbeats
+		var status string
+		_ = json.Unmarshal(payload["status"], &status)
+		var health InstanceHealthReport
+		if err := json.Unmarshal(payload["health"], &health); err != nil {
+			t.Fatalf("heartbeat health: %v", err)
+		}
+		if status != InstanceUnhealthy || health.Instance != "worker-1" || health.Status != InstanceUnhealthy ||
+			health.ConsecutiveFailures != want || health.IntervalMs != time.Minute.Milliseconds() {
+			t.Errorf("heartbeat %d: status %q, health %+v", want, status, health)
+		}
+	}
+}
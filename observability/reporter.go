@@ -8,10 +8,16 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strconv"
 	"sync"
 	"time"
 )
 
+// spoolFileName is the file inside ReporterConfig.SpoolDir that holds unsent
+// reports across restarts.
+const spoolFileName = "reporter-spool.json"
+
 // ReporterConfig configures the built-in observability reporter.
 type ReporterConfig struct {
 	// AdminURL is the base URL of the admin server (e.g., "http://admin-server:8081").
@@ -24,6 +30,20 @@ type ReporterConfig struct {
 	InstanceName string `yaml:"instance_name" json:"instance_name"`
 	// HeartbeatInterval is how often to send heartbeats to the admin server.
 	HeartbeatInterval time.Duration `yaml:"heartbeat_interval" json:"heartbeat_interval"`
+	// MaxBufferSize caps the number of buffered items per report kind. When the
+	// cap is exceeded the oldest items are dropped.
+	MaxBufferSize int `yaml:"max_buffer_size" json:"max_buffer_size"`
+	// RetryBackoff is the initial delay before retrying after a failed flush.
+	// The delay doubles after each consecutive failure up to MaxRetryBackoff.
+	RetryBackoff time.Duration `yaml:"retry_backoff" json:"retry_backoff"`
+	// MaxRetryBackoff caps the delay between flush retries.
+	MaxRetryBackoff time.Duration `yaml:"max_retry_backoff" json:"max_retry_backoff"`
+	// SpoolDir, when set, is a directory where unsent data is persisted
+	// whenever a flush fails and on shutdown, and reloaded on the next start.
+	SpoolDir string `yaml:"spool_dir" json:"spool_dir"`
+	// HealthURL, when set, is probed before each heartbeat and the result is
+	// sent with it. Without it heartbeats report the worker as healthy.
+	HealthURL string `yaml:"health_url" json:"health_url"`
 }
 
 // DefaultReporterConfig returns a config with sensible defaults.
@@ -34,6 +54,9 @@ func DefaultReporterConfig() ReporterConfig {
 		BatchSize:         100,
 		InstanceName:      hostname,
 		HeartbeatInterval: 30 * time.Second,
+		MaxBufferSize:     10000,
+		RetryBackoff:      time.Second,
+		MaxRetryBackoff:   time.Minute,
 	}
 }
 
@@ -69,7 +92,27 @@ type EventReport struct {
 	CreatedAt   string         `json:"created_at"`
 }
 
+// ReporterHealth is a point-in-time snapshot of the reporter's delivery state.
+type ReporterHealth struct {
+	LastFlushAt         time.Time `json:"last_flush_at,omitzero"`
+	LastErrorAt         time.Time `json:"last_error_at,omitzero"`
+	LastError           string    `json:"last_error,omitempty"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	NextRetryAt         time.Time `json:"next_retry_at,omitzero"`
+	Buffered            int       `json:"buffered"`
+	Dropped             int64     `json:"dropped"`
+	Registered          bool      `json:"registered"`
+}
+
+// reporterSpool is the on-disk representation of unsent reports.
+type reporterSpool struct {
+	Executions []ExecutionReport `json:"executions,omitempty"`
+	Logs       []LogReport       `json:"logs,omitempty"`
+	Events     []EventReport     `json:"events,omitempty"`
+}
+
 // Reporter buffers observability data and periodically flushes it to the admin server.
+// Failed batches stay buffered and are retried with exponential backoff.
 type Reporter struct {
 	config ReporterConfig
 	client *http.Client
@@ -81,20 +124,46 @@ type Reporter struct {
 	events     []EventReport
 	registered bool
 	cancel     context.CancelFunc
+	done       chan struct{}
+
+	// flushMu serializes flushes so retries never interleave.
+	flushMu     sync.Mutex
+	lastFlushAt time.Time
+	lastErrorAt time.Time
+	lastError   string
+	failures    int
+	nextRetryAt time.Time
+	dropped     int64
+
+	// health tracks consecutive failed probes of HealthURL; heartbeat
+	// goroutine only.
+	health HealthTracker
 }
 
 // NewReporter creates a new observability reporter.
 func NewReporter(config ReporterConfig, logger *slog.Logger) *Reporter {
-	if config.FlushInterval == 0 {
+	if config.FlushInterval <= 0 {
 		config.FlushInterval = 5 * time.Second
 	}
-	if config.BatchSize == 0 {
+	if config.BatchSize <= 0 {
 		config.BatchSize = 100
 	}
-	if config.HeartbeatInterval == 0 {
+	if config.HeartbeatInterval <= 0 {
 		config.HeartbeatInterval = 30 * time.Second
 	}
-	return &Reporter{
+	if config.MaxBufferSize <= 0 {
+		config.MaxBufferSize = 10000
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = time.Second
+	}
+	if config.MaxRetryBackoff <= 0 {
+		config.MaxRetryBackoff = time.Minute
+	}
+	if config.MaxRetryBackoff < config.RetryBackoff {
+		config.MaxRetryBackoff = config.RetryBackoff
+	}
+	r := &Reporter{
 		config:     config,
 		client:     &http.Client{Timeout: 10 * time.Second},
 		logger:     logger,
@@ -102,25 +171,32 @@ func NewReporter(config ReporterConfig, logger *slog.Logger) *Reporter {
 		logs:       make([]LogReport, 0),
 		events:     make([]EventReport, 0),
 	}
+	r.loadSpool()
+	return r
 }
 
 // Start begins the background flush and heartbeat loops.
 func (r *Reporter) Start(ctx context.Context) {
 	ctx, r.cancel = context.WithCancel(ctx)
+	r.done = make(chan struct{})
 
 	// Register this instance with the admin
 	go r.register(ctx)
 
 	// Flush loop
 	go func() {
+		defer close(r.done)
 		ticker := time.NewTicker(r.config.FlushInterval)
 		defer ticker.Stop()
 		for {
 			select {
 			case <-ctx.Done():
-				r.flush(context.Background()) // Final flush
+				r.flush(context.Background()) // Final flush; spools what fails
 				return
 			case <-ticker.C:
+				if r.retryPending(time.Now()) {
+					continue
+				}
 				r.flush(ctx)
 			}
 		}
@@ -141,11 +217,19 @@ func (r *Reporter) Start(ctx context.Context) {
 	}()
 }
 
-// Stop shuts down the reporter, performing a final flush.
+// Stop shuts down the reporter, performing a final flush. Data that could not
+// be delivered is written to SpoolDir when one is configured.
 func (r *Reporter) Stop() {
 	if r.cancel != nil {
 		r.cancel()
 	}
+	if r.done != nil {
+		select {
+		case <-r.done:
+		case <-time.After(15 * time.Second):
+			r.logger.Warn("Timed out waiting for reporter final flush")
+		}
+	}
 }
 
 // ReportExecution buffers an execution record for the next flush.
@@ -153,6 +237,7 @@ func (r *Reporter) ReportExecution(exec ExecutionReport) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	r.executions = append(r.executions, exec)
+	r.executions = trimBuffer(r, r.executions)
 }
 
 // ReportLog buffers a log entry for the next flush.
@@ -160,6 +245,7 @@ func (r *Reporter) ReportLog(log LogReport) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	r.logs = append(r.logs, log)
+	r.logs = trimBuffer(r, r.logs)
 }
 
 // ReportEvent buffers an event for the next flush.
@@ -167,10 +253,59 @@ func (r *Reporter) ReportEvent(event EventReport) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	r.events = append(r.events, event)
+	r.events = trimBuffer(r, r.events)
 }
 
-// flush sends all buffered data to the admin server.
+// Health returns a snapshot of the reporter's delivery state.
+func (r *Reporter) Health() ReporterHealth {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return ReporterHealth{
+		LastFlushAt:         r.lastFlushAt,
+		LastErrorAt:         r.lastErrorAt,
+		LastError:           r.lastError,
+		ConsecutiveFailures: r.failures,
+		NextRetryAt:         r.nextRetryAt,
+		Buffered:            len(r.executions) + len(r.logs) + len(r.events),
+		Dropped:             r.dropped,
+		Registered:          r.registered,
+	}
+}
+
+// HealthHandler returns an HTTP handler that serves Health as JSON.
+func (r *Reporter) HealthHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(r.Health())
+	}
+}
+
+// trimBuffer drops the oldest items once buf exceeds MaxBufferSize.
+// The caller must hold r.mu.
+func trimBuffer[T any](r *Reporter, buf []T) []T {
+	if over := len(buf) - r.config.MaxBufferSize; over > 0 {
+		r.dropped += int64(over)
+		return append(buf[:0:0], buf[over:]...)
+	}
+	return buf
+}
+
+// retryPending reports whether a previous failure's backoff is still in effect.
+func (r *Reporter) retryPending(now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return now.Before(r.nextRetryAt)
+}
+
+// flush sends all buffered data to the admin server in batches of at most
+// BatchSize items. Batches that fail are returned to the front of the buffer
+// and retried on a later flush once the backoff delay has elapsed. With a
+// SpoolDir, the buffer is written to the spool when a flush fails, so a crash
+// does not lose it, and the spool is removed once everything is delivered.
 func (r *Reporter) flush(ctx context.Context) {
+	r.flushMu.Lock()
+	defer r.flushMu.Unlock()
+
 	r.mu.Lock()
 	execs := r.executions
 	logs := r.logs
@@ -180,46 +315,166 @@ func (r *Reporter) flush(ctx context.Context) {
 	r.events = make([]EventReport, 0)
 	r.mu.Unlock()
 
-	if len(execs) > 0 {
-		r.sendBatch(ctx, "/api/v1/admin/ingest/executions", execs)
+	if len(execs)+len(logs)+len(events) == 0 {
+		return
+	}
+
+	unsentExecs, errExecs := sendBatches(ctx, r, "/api/v1/admin/ingest/executions", execs)
+	unsentLogs, errLogs := sendBatches(ctx, r, "/api/v1/admin/ingest/logs", logs)
+	unsentEvents, errEvents := sendBatches(ctx, r, "/api/v1/admin/ingest/events", events)
+
+	defer r.saveSpool()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.executions = trimBuffer(r, append(unsentExecs, r.executions...))
+	r.logs = trimBuffer(r, append(unsentLogs, r.logs...))
+	r.events = trimBuffer(r, append(unsentEvents, r.events...))
+
+	now := time.Now()
+	err := errExecs
+	if err == nil {
+		err = errLogs
+	}
+	if err == nil {
+		err = errEvents
+	}
+	if err == nil {
+		r.lastFlushAt = now
+		r.failures = 0
+		r.nextRetryAt = time.Time{}
+		return
 	}
-	if len(logs) > 0 {
-		r.sendBatch(ctx, "/api/v1/admin/ingest/logs", logs)
+	r.failures++
+	r.lastErrorAt = now
+	r.lastError = err.Error()
+	r.nextRetryAt = now.Add(r.backoff(r.failures))
+	r.logger.Debug("Reporter flush failed, will retry",
+		"error", err, "failures", r.failures, "next_retry", r.nextRetryAt)
+}
+
+// backoff returns the retry delay after the given number of consecutive failures.
+func (r *Reporter) backoff(failures int) time.Duration {
+	d := r.config.RetryBackoff
+	for i := 1; i < failures && d < r.config.MaxRetryBackoff; i++ {
+		d *= 2
 	}
-	if len(events) > 0 {
-		r.sendBatch(ctx, "/api/v1/admin/ingest/events", events)
+	return min(d, r.config.MaxRetryBackoff)
+}
+
+// sendBatches sends items in BatchSize chunks. On the first failure it stops
+// and returns the unsent remainder along with the error.
+func sendBatches[T any](ctx context.Context, r *Reporter, path string, items []T) ([]T, error) {
+	for len(items) > 0 {
+		n := min(len(items), r.config.BatchSize)
+		if err := r.sendBatch(ctx, path, items[:n]); err != nil {
+			return items, err
+		}
+		items = items[n:]
 	}
+	return nil, nil
 }
 
 // sendBatch POSTs a batch of data to the admin server.
-func (r *Reporter) sendBatch(ctx context.Context, path string, data any) {
+func (r *Reporter) sendBatch(ctx context.Context, path string, data any) error {
 	body, err := json.Marshal(map[string]any{
 		"instance": r.config.InstanceName,
 		"items":    data,
 	})
 	if err != nil {
+		// A batch that cannot be marshaled will never succeed; drop it.
 		r.logger.Warn("Failed to marshal batch", "path", path, "error", err)
-		return
+		return nil
 	}
 
 	url := r.config.AdminURL + path
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
 	if err != nil {
 		r.logger.Warn("Failed to create request", "url", url, "error", err)
-		return
+		return fmt.Errorf("create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := r.client.Do(req) //nolint:gosec // G704: URL from configured admin endpoint
 	if err != nil {
 		r.logger.Debug("Failed to send batch to admin", "url", url, "error", err)
-		return
+		return fmt.Errorf("send batch to %s: %w", path, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
 		r.logger.Warn("Admin rejected batch", "url", url, "status", resp.StatusCode)
+		return fmt.Errorf("admin rejected batch to %s: status %d", path, resp.StatusCode)
+	}
+	return nil
+}
+
+// loadSpool restores reports persisted by a previous run. The spool file is
+// kept until a flush delivers them, so a crash before then does not lose them.
+func (r *Reporter) loadSpool() {
+	if r.config.SpoolDir == "" {
+		return
+	}
+	path := filepath.Join(r.config.SpoolDir, spoolFileName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			r.logger.Warn("Failed to read reporter spool", "path", path, "error", err)
+		}
+		return
+	}
+	var spool reporterSpool
+	if err := json.Unmarshal(data, &spool); err != nil {
+		r.logger.Warn("Discarding corrupt reporter spool", "path", path, "error", err)
+		_ = os.Remove(path)
+		return
 	}
+	r.mu.Lock()
+	r.executions = trimBuffer(r, append(spool.Executions, r.executions...))
+	r.logs = trimBuffer(r, append(spool.Logs, r.logs...))
+	r.events = trimBuffer(r, append(spool.Events, r.events...))
+	r.mu.Unlock()
+	r.logger.Info("Restored unsent reports from spool",
+		"executions", len(spool.Executions), "logs", len(spool.Logs), "events", len(spool.Events))
+}
+
+// saveSpool replaces the spool in SpoolDir with the still-buffered reports,
+// or removes it when nothing is buffered. Callers hold flushMu.
+func (r *Reporter) saveSpool() {
+	if r.config.SpoolDir == "" {
+		return
+	}
+	r.mu.Lock()
+	spool := reporterSpool{Executions: r.executions, Logs: r.logs, Events: r.events}
+	r.mu.Unlock()
+	path := filepath.Join(r.config.SpoolDir, spoolFileName)
+	if len(spool.Executions)+len(spool.Logs)+len(spool.Events) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			r.logger.Warn("Failed to remove reporter spool", "path", path, "error", err)
+		}
+		return
+	}
+
+	data, err := json.Marshal(spool)
+	if err != nil {
+		r.logger.Warn("Failed to marshal reporter spool", "error", err)
+		return
+	}
+	if err := os.MkdirAll(r.config.SpoolDir, 0o750); err != nil {
+		r.logger.Warn("Failed to create reporter spool dir", "dir", r.config.SpoolDir, "error", err)
+		return
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		r.logger.Warn("Failed to write reporter spool", "path", tmp, "error", err)
+		return
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		r.logger.Warn("Failed to finalize reporter spool", "path", path, "error", err)
+		return
+	}
+	r.logger.Debug("Persisted unsent reports to spool", "path", path,
+		"executions", len(spool.Executions), "logs", len(spool.Logs), "events", len(spool.Events))
 }
 
 // register sends an initial registration to the admin server.
@@ -251,12 +506,15 @@ func (r *Reporter) register(ctx context.Context) {
 	}
 }
 
-// heartbeat sends a periodic health check to the admin server.
+// heartbeat sends a periodic health check to the admin server, carrying the
+// same health report the RuntimeManager sends for local instances.
 func (r *Reporter) heartbeat(ctx context.Context) {
+	report := r.healthReport(ctx, time.Now())
 	body, _ := json.Marshal(map[string]any{
 		"instance_name": r.config.InstanceName,
-		"timestamp":     time.Now().UTC().Format(time.RFC3339),
-		"status":        "healthy",
+		"timestamp":     report.ReportedAt.UTC().Format(time.RFC3339),
+		"status":        report.Status,
+		"health":        report,
 	})
 
 	url := r.config.AdminURL + "/api/v1/admin/instances/heartbeat"
@@ -273,6 +531,16 @@ func (r *Reporter) heartbeat(ctx context.Context) {
 	resp.Body.Close()
 }
 
+// healthReport probes HealthURL, if configured, and builds the heartbeat's
+// health report.
+func (r *Reporter) healthReport(ctx context.Context, now time.Time) InstanceHealthReport {
+	probe := HealthProbe{Status: InstanceHealthy}
+	if r.config.HealthURL != "" {
+		probe = ProbeHealth(ctx, r.client, r.config.HealthURL)
+	}
+	return r.health.Report(r.config.InstanceName, r.config.InstanceName, probe, nil, r.config.HeartbeatInterval, now)
+}
+
 // ReporterFromEnv creates a Reporter from environment variables.
 // Returns nil if WORKFLOW_ADMIN_URL is not set.
 func ReporterFromEnv(logger *slog.Logger) *Reporter {
@@ -289,6 +557,28 @@ func ReporterFromEnv(logger *slog.Logger) *Reporter {
 			cfg.FlushInterval = d
 		}
 	}
+	if v := os.Getenv("WORKFLOW_REPORTER_BATCH_SIZE"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.BatchSize = n
+		}
+	}
+	if v := os.Getenv("WORKFLOW_REPORTER_MAX_BUFFER"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.MaxBufferSize = n
+		}
+	}
+	if v := os.Getenv("WORKFLOW_REPORTER_RETRY_BACKOFF"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.RetryBackoff = d
+		}
+	}
+	if v := os.Getenv("WORKFLOW_REPORTER_MAX_RETRY_BACKOFF"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.MaxRetryBackoff = d
+		}
+	}
+	cfg.SpoolDir = os.Getenv("WORKFLOW_REPORTER_SPOOL_DIR")
+	cfg.HealthURL = os.Getenv("WORKFLOW_REPORTER_HEALTH_URL")
 
 	return NewReporter(cfg, logger)
 }
@@ -298,6 +588,14 @@ func ReporterFromEnv(logger *slog.Logger) *Reporter {
 type IngestHandler struct {
 	store  IngestStore
 	logger *slog.Logger
+	events EventRecorder
+}
+
+// EventRecorder receives ingested execution events, so that executions run
+// on remote workers appear in the execution timelines.
+// *store.EventRecorderAdapter satisfies it.
+type EventRecorder interface {
+	RecordEvent(ctx context.Context, executionID string, eventType string, data map[string]any) error
 }
 
 // IngestStore defines the storage interface for ingested observability data.
@@ -314,6 +612,11 @@ func NewIngestHandler(store IngestStore, logger *slog.Logger) *IngestHandler {
 	return &IngestHandler{store: store, logger: logger}
 }
 
+// SetEventRecorder sets the recorder ingested events are also appended to.
+func (h *IngestHandler) SetEventRecorder(r EventRecorder) {
+	h.events = r
+}
+
 // RegisterRoutes registers the ingest API routes on the given mux.
 func (h *IngestHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("POST /api/v1/admin/ingest/executions", h.handleIngestExecutions)
@@ -374,6 +677,13 @@ func (h *IngestHandler) handleIngestEvents(w http.ResponseWriter, r *http.Reques
 		http.Error(w, fmt.Sprintf(`{"error":%q}`, err), http.StatusInternalServerError)
 		return
 	}
+	if h.events != nil {
+		for _, ev := range payload.Items {
+			if err := h.events.RecordEvent(r.Context(), ev.ExecutionID, ev.EventType, ev.EventData); err != nil {
+				h.logger.Warn("Failed to record ingested event", "execution_id", ev.ExecutionID, "event_type", ev.EventType, "error", err)
+			}
+		}
+	}
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(map[string]int{"accepted": len(payload.Items)})
 }
@@ -405,8 +715,9 @@ func (h *IngestHandler) handleRegister(w http.ResponseWriter, r *http.Request) {
 
 func (h *IngestHandler) handleHeartbeat(w http.ResponseWriter, r *http.Request) {
 	var payload struct {
-		InstanceName string `json:"instance_name"`
-		Timestamp    string `json:"timestamp"`
+		InstanceName string                `json:"instance_name"`
+		Timestamp    string                `json:"timestamp"`
+		Health       *InstanceHealthReport `json:"health"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
 		http.Error(w, `{"error":"invalid JSON"}`, http.StatusBadRequest)
@@ -416,9 +727,32 @@ func (h *IngestHandler) handleHeartbeat(w http.ResponseWriter, r *http.Request)
 	if t.IsZero() {
 		t = time.Now()
 	}
-	if err := h.store.Heartbeat(r.Context(), payload.InstanceName, t); err != nil {
-		h.logger.Warn("Failed to heartbeat", "error", err)
-	}
+	h.RecordHeartbeat(r.Context(), payload.InstanceName, t, payload.Health)
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 }
+
+// RecordHeartbeat records a heartbeat and, when the store keeps instance
+// health, its health report. The heartbeat endpoint uses it for remote
+// workers; the RuntimeManager calls it directly for local instances.
+// Failures are logged rather than returned, as for HTTP heartbeats.
+func (h *IngestHandler) RecordHeartbeat(ctx context.Context, instance string, at time.Time, health *InstanceHealthReport) {
+	if err := h.store.Heartbeat(ctx, instance, at); err != nil {
+		h.logger.Warn("Failed to heartbeat", "error", err)
+	}
+	if health == nil {
+		return
+	}
+	hs, ok := h.store.(InstanceHealthStore)
+	if !ok {
+		return
+	}
+	report := *health
+	report.Instance = instance
+	if report.ReportedAt.IsZero() {
+		report.ReportedAt = at
+	}
+	if err := hs.RecordHealth(ctx, report); err != nil {
+		h.logger.Warn("Failed to record instance health", "instance", instance, "error", err)
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"testing"
@@ -273,6 +275,234 @@ func TestReporter_Stop_NilCancel(t *testing.T) {
 	reporter.Stop()
 }
 
+func TestReporter_BatchesBySize(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var batchSizes []int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Items []ExecutionReport `json:"items"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		batchSizes = append(batchSizes, len(payload.Items))
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	reporter := NewReporter(ReporterConfig{
+		AdminURL:     server.URL,
+		BatchSize:    3,
+		InstanceName: "test",
+	}, logger)
+
+	for i := range 7 {
+		reporter.ReportExecution(ExecutionReport{ID: fmt.Sprintf("exec-%d", i)})
+	}
+	reporter.flush(context.Background())
+
+	mu.Lock()
+	defer mu.Unlock()
+	if fmt.Sprint(batchSizes) != "[3 3 1]" {
+		t.Errorf("expected batches [3 3 1], got %v", batchSizes)
+	}
+	if h := reporter.Health(); h.Buffered != 0 || h.LastFlushAt.IsZero() {
+		t.Errorf("expected empty buffer and successful flush, got %+v", h)
+	}
+}
+
+func TestReporter_RetryKeepsDataAndBacksOff(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	failing := true
+	received := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if failing {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var payload struct {
+			Items []ExecutionReport `json:"items"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		received += len(payload.Items)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	reporter := NewReporter(ReporterConfig{
+		AdminURL:        server.URL,
+		BatchSize:       2,
+		InstanceName:    "test",
+		RetryBackoff:    time.Second,
+		MaxRetryBackoff: 4 * time.Second,
+	}, logger)
+
+	for i := range 5 {
+		reporter.ReportExecution(ExecutionReport{ID: fmt.Sprintf("exec-%d", i)})
+	}
+
+	reporter.flush(context.Background())
+	h := reporter.Health()
+	if h.Buffered != 5 {
+		t.Errorf("expected 5 items retained after failure, got %d", h.Buffered)
+	}
+	if h.ConsecutiveFailures != 1 || h.LastError == "" {
+		t.Errorf("expected failure recorded, got %+v", h)
+	}
+	if !reporter.retryPending(time.Now()) {
+		t.Error("expected retry to be pending after failure")
+	}
+
+	reporter.flush(context.Background())
+	if got := reporter.backoff(reporter.Health().ConsecutiveFailures); got != 2*time.Second {
+		t.Errorf("expected backoff to double to 2s, got %v", got)
+	}
+	if got := reporter.backoff(10); got != 4*time.Second {
+		t.Errorf("expected backoff capped at 4s, got %v", got)
+	}
+
+	mu.Lock()
+	failing = false
+	mu.Unlock()
+
+	reporter.flush(context.Background())
+	h = reporter.Health()
+	if h.Buffered != 0 || h.ConsecutiveFailures != 0 {
+		t.Errorf("expected recovery after successful flush, got %+v", h)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if received != 5 {
+		t.Errorf("expected all 5 items delivered, got %d", received)
+	}
+}
+
+func TestReporter_MaxBufferDropsOldest(t *testing.T) {
+	t.Parallel()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	reporter := NewReporter(ReporterConfig{
+		InstanceName:  "test",
+		MaxBufferSize: 3,
+	}, logger)
+
+	for i := range 5 {
+		reporter.ReportLog(LogReport{Message: fmt.Sprintf("log-%d", i)})
+	}
+
+	reporter.mu.Lock()
+	first := reporter.logs[0].Message
+	reporter.mu.Unlock()
+	if first != "log-2" {
+		t.Errorf("expected oldest logs dropped, first remaining is %q", first)
+	}
+	if h := reporter.Health(); h.Buffered != 3 || h.Dropped != 2 {
+		t.Errorf("expected 3 buffered and 2 dropped, got %+v", h)
+	}
+}
+
+func TestReporter_SpoolPersistsAcrossRestart(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer down.Close()
+
+	reporter := NewReporter(ReporterConfig{
+		AdminURL:          down.URL,
+		FlushInterval:     time.Hour,
+		HeartbeatInterval: time.Hour,
+		InstanceName:      "test",
+		SpoolDir:          dir,
+	}, logger)
+	reporter.ReportExecution(ExecutionReport{ID: "exec-spooled"})
+	reporter.ReportEvent(EventReport{ExecutionID: "exec-spooled", EventType: "step_completed"})
+	reporter.Start(context.Background())
+	reporter.Stop()
+
+	if _, err := os.Stat(filepath.Join(dir, spoolFileName)); err != nil {
+		t.Fatalf("expected spool file after failed final flush: %v", err)
+	}
+
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer up.Close()
+
+	restarted := NewReporter(ReporterConfig{AdminURL: up.URL, InstanceName: "test", SpoolDir: dir}, logger)
+	restarted.mu.Lock()
+	execs, events := len(restarted.executions), len(restarted.events)
+	restarted.mu.Unlock()
+	if execs != 1 || events != 1 {
+		t.Errorf("expected spooled items restored, got %d executions and %d events", execs, events)
+	}
+	if _, err := os.Stat(filepath.Join(dir, spoolFileName)); err != nil {
+		t.Errorf("expected spool file kept until the items are delivered: %v", err)
+	}
+	restarted.flush(context.Background())
+	if _, err := os.Stat(filepath.Join(dir, spoolFileName)); !os.IsNotExist(err) {
+		t.Errorf("expected spool file removed after delivery, stat err: %v", err)
+	}
+}
+
+func TestReporter_FailedFlushSpoolsWithoutStop(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer down.Close()
+
+	// A negative batch size falls back to the default instead of looping.
+	reporter := NewReporter(ReporterConfig{AdminURL: down.URL, BatchSize: -1, InstanceName: "test", SpoolDir: dir}, logger)
+	if reporter.config.BatchSize != 100 {
+		t.Errorf("expected the default batch size, got %d", reporter.config.BatchSize)
+	}
+	reporter.ReportExecution(ExecutionReport{ID: "exec-1"})
+	reporter.flush(context.Background())
+
+	// The failed batch is on disk without Stop, as it would be after a crash.
+	crashed := NewReporter(ReporterConfig{InstanceName: "test", SpoolDir: dir}, logger)
+	if h := crashed.Health(); h.Buffered != 1 {
+		t.Errorf("expected the failed batch restored from the spool, got %d buffered", h.Buffered)
+	}
+}
+
+func TestReporter_HealthHandler(t *testing.T) {
+	t.Parallel()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	reporter := NewReporter(ReporterConfig{InstanceName: "test"}, logger)
+	reporter.ReportExecution(ExecutionReport{ID: "exec-1"})
+
+	rec := httptest.NewRecorder()
+	reporter.HealthHandler()(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/reporter/health", nil))
+
+	var h ReporterHealth
+	if err := json.NewDecoder(rec.Body).Decode(&h); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if h.Buffered != 1 {
+		t.Errorf("expected 1 buffered item, got %d", h.Buffered)
+	}
+}
+
 // --- IngestHandler tests ---
 
 type mockIngestStore struct {
@@ -404,6 +634,49 @@ func TestIngestHandler_Events(t *testing.T) {
 	}
 }
 
+type recordedEvent struct {
+	executionID, eventType string
+	data                   map[string]any
+}
+
+type mockEventRecorder struct {
+	events []recordedEvent
+}
+
+func (m *mockEventRecorder) RecordEvent(_ context.Context, executionID, eventType string, data map[string]any) error {
+	m.events = append(m.events, recordedEvent{executionID, eventType, data})
+	return nil
+}
+
+func TestIngestHandler_EventsForwardedToRecorder(t *testing.T) {
+	t.Parallel()
+
+	store := newMockIngestStore()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	handler := NewIngestHandler(store, logger)
+	recorder := &mockEventRecorder{}
+	handler.SetEventRecorder(recorder)
+
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	body := `{"instance":"worker-1","items":[{"execution_id":"e1","event_type":"step.completed","event_data":{"step":"build"}}]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/ingest/events", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if len(recorder.events) != 1 {
+		t.Fatalf("expected 1 recorded event, got %d", len(recorder.events))
+	}
+	got := recorder.events[0]
+	if got.executionID != "e1" || got.eventType != "step.completed" || got.data["step"] != "build" {
+		t.Errorf("unexpected recorded event: %+v", got)
+	}
+}
+
 func TestIngestHandler_InvalidJSON(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,101 @@
+package tracing
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"slices"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Baggage member keys that identify the tenant, workflow, execution and
+// request a request belongs to. They are set at ingress, carried on the
+// context through pipeline steps and sub-workflows, and propagated on
+// outbound HTTP calls.
+const (
+	BaggageTenantID    = "tenant_id"
+	BaggageWorkflowID  = "workflow_id"
+	BaggageExecutionID = "execution_id"
+	BaggageRequestID   = "request_id"
+)
+
+// baggageSpanKeys maps each workflow baggage member to the span attribute it
+// is recorded as.
+var baggageSpanKeys = []struct {
+	member string
+	attr   string
+}{
+	{BaggageTenantID, "tenant.id"},
+	{BaggageWorkflowID, "workflow.id"},
+	{BaggageExecutionID, "execution.id"},
+	{BaggageRequestID, "request.id"},
+}
+
+// WithBaggage returns ctx with members set in its OTEL baggage, replacing
+// members with the same key. An empty value removes the member, so ingress
+// can discard a value an inbound request carried but the engine cannot
+// vouch for. Values that are not valid baggage are skipped.
+func WithBaggage(ctx context.Context, members map[string]string) context.Context {
+	bag := baggage.FromContext(ctx)
+	for _, key := range slices.Sorted(maps.Keys(members)) {
+		value := members[key]
+		if value == "" {
+			bag = bag.DeleteMember(key)
+			continue
+		}
+		m, err := baggage.NewMemberRaw(key, value)
+		if err != nil {
+			continue
+		}
+		if next, err := bag.SetMember(m); err == nil {
+			bag = next
+		}
+	}
+	return baggage.ContextWithBaggage(ctx, bag)
+}
+
+// BaggageSpanAttributes returns the workflow baggage members in ctx as span
+// attributes (tenant.id, workflow.id, execution.id, request.id).
+func BaggageSpanAttributes(ctx context.Context) []attribute.KeyValue {
+	bag := baggage.FromContext(ctx)
+	var attrs []attribute.KeyValue
+	for _, k := range baggageSpanKeys {
+		if v := bag.Member(k.member).Value(); v != "" {
+			attrs = append(attrs, attribute.String(k.attr, v))
+		}
+	}
+	return attrs
+}
+
+// BaggageLogArgs returns the workflow baggage members in ctx as slog
+// key/value pairs, for use with slog.Logger.With.
+func BaggageLogArgs(ctx context.Context) []any {
+	bag := baggage.FromContext(ctx)
+	var args []any
+	for _, k := range baggageSpanKeys {
+		if v := bag.Member(k.member).Value(); v != "" {
+			args = append(args, k.member, v)
+		}
+	}
+	return args
+}
+
+// Propagator returns the global text map propagator, extended with W3C
+// baggage when it does not already carry it, so workflow baggage propagates
+// even when no tracer provider is configured.
+func Propagator() propagation.TextMapPropagator {
+	p := otel.GetTextMapPropagator()
+	if slices.Contains(p.Fields(), "baggage") {
+		return p
+	}
+	return propagation.NewCompositeTextMapPropagator(p, propagation.Baggage{})
+}
+
+// InjectHeaders writes the trace context and baggage of ctx into h.
+func InjectHeaders(ctx context.Context, h http.Header) {
+	Propagator().Inject(ctx, propagation.HeaderCarrier(h))
+}
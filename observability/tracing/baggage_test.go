@@ -0,0 +1,57 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+func TestWithBaggage(t *testing.T) {
+	ctx := WithBaggage(context.Background(), map[string]string{
+		BaggageTenantID:   "acme",
+		BaggageWorkflowID: "",
+	})
+	ctx = WithBaggage(ctx, map[string]string{BaggageExecutionID: "exec-1", BaggageTenantID: "globex"})
+
+	bag := baggage.FromContext(ctx)
+	if bag.Len() != 2 || bag.Member(BaggageTenantID).Value() != "globex" {
+		t.Errorf("baggage = %s, want tenant replaced and empty workflow skipped", bag)
+	}
+	if cleared := WithBaggage(ctx, map[string]string{BaggageTenantID: ""}); BaggageLogArgs(cleared)[0] != "execution_id" {
+		t.Errorf("empty value did not remove the member: %v", BaggageLogArgs(cleared))
+	}
+	wantAttrs := []attribute.KeyValue{attribute.String("tenant.id", "globex"), attribute.String("execution.id", "exec-1")}
+	if got := BaggageSpanAttributes(ctx); !slices.Equal(got, wantAttrs) {
+		t.Errorf("span attributes = %v, want %v", got, wantAttrs)
+	}
+	if got := BaggageLogArgs(ctx); !slices.Equal(got, []any{"tenant_id", "globex", "execution_id", "exec-1"}) {
+		t.Errorf("log args = %v", got)
+	}
+}
+
+func TestBaggageRoundTripsOverHTTP(t *testing.T) {
+	// Without a tracer provider the global propagator is a no-op; baggage
+	// must still propagate.
+	ctx := WithBaggage(context.Background(), map[string]string{BaggageTenantID: "acme", BaggageWorkflowID: "wf 1"})
+	h := http.Header{}
+	InjectHeaders(ctx, h)
+	if h.Get("baggage") == "" {
+		t.Fatalf("no baggage header injected: %v", h)
+	}
+
+	var got []any
+	handler := SpanMiddleware(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		got = BaggageLogArgs(r.Context())
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header = h
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if !slices.Equal(got, []any{"tenant_id", "acme", "workflow_id", "wf 1"}) {
+		t.Errorf("extracted baggage = %v", got)
+	}
+}
@@ -0,0 +1,67 @@
+package tracing
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+)
+
+// ContextHandler is a slog.Handler that adds the workflow baggage members
+// of a record's context (tenant_id, workflow_id, execution_id, request_id)
+// to the record, so that any log line emitted with a *Context method during
+// an execution can be correlated with its request. Members already set on
+// the logger with With, or on the record, are not repeated.
+type ContextHandler struct {
+	next  slog.Handler
+	bound []string // top-level attribute keys added with WithAttrs
+	group bool     // WithGroup was called; members are added to the group
+}
+
+// NewContextHandler wraps next in a ContextHandler.
+func NewContextHandler(next slog.Handler) *ContextHandler {
+	return &ContextHandler{next: next}
+}
+
+// Enabled reports whether next handles records at level.
+func (h *ContextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle adds the baggage members of ctx to r and passes it to next.
+func (h *ContextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		args := BaggageLogArgs(ctx)
+		if len(args) > 0 {
+			var present []string
+			r.Attrs(func(a slog.Attr) bool {
+				present = append(present, a.Key)
+				return true
+			})
+			r = r.Clone()
+			for i := 0; i+1 < len(args); i += 2 {
+				key := args[i].(string)
+				if !h.group && slices.Contains(h.bound, key) || slices.Contains(present, key) {
+					continue
+				}
+				r.AddAttrs(slog.String(key, args[i+1].(string)))
+			}
+		}
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs returns a ContextHandler whose next handler has attrs.
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := &ContextHandler{next: h.next.WithAttrs(attrs), bound: slices.Clone(h.bound), group: h.group}
+	if !h.group {
+		for _, a := range attrs {
+			out.bound = append(out.bound, a.Key)
+		}
+	}
+	return out
+}
+
+// WithGroup returns a ContextHandler whose next handler has the group.
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{next: h.next.WithGroup(name), bound: h.bound, group: true}
+}
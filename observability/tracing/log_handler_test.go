@@ -0,0 +1,41 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestContextHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewContextHandler(slog.NewJSONHandler(&buf, nil)))
+	ctx := WithBaggage(context.Background(), map[string]string{
+		BaggageRequestID:   "req-1",
+		BaggageExecutionID: "exec-1",
+	})
+
+	logger.InfoContext(ctx, "with context")
+	logger.Info("without context")
+	logger.With("request_id", "bound").InfoContext(ctx, "bound")
+	logger.InfoContext(ctx, "explicit", "execution_id", "own")
+	logger.WithGroup("g").InfoContext(ctx, "grouped")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("lines = %d\n%s", len(lines), buf.String())
+	}
+	wants := []struct{ has, lacks string }{
+		{`"execution_id":"exec-1","request_id":"req-1"`, ""},
+		{`"msg":"without context"}`, "request_id"},
+		{`"request_id":"bound","execution_id":"exec-1"}`, "req-1"},
+		{`"execution_id":"own","request_id":"req-1"}`, "exec-1"},
+		{`"g":{"execution_id":"exec-1","request_id":"req-1"}`, ""},
+	}
+	for i, want := range wants {
+		if !strings.Contains(lines[i], want.has) || (want.lacks != "" && strings.Contains(lines[i], want.lacks)) {
+			t.Errorf("line %d = %s, want %s without %q", i, lines[i], want.has, want.lacks)
+		}
+	}
+}
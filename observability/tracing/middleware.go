@@ -12,11 +12,10 @@ import (
 )
 
 // SpanMiddleware returns HTTP middleware that creates a span for each request
-// and propagates trace context from incoming headers.
+// and propagates trace context and baggage from incoming headers.
 func SpanMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		propagator := otel.GetTextMapPropagator()
-		ctx := propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx := Propagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
 
 		tracer := otel.GetTracerProvider().Tracer("workflow.http")
 		spanName := fmt.Sprintf("%s %s", r.Method, r.URL.Path)
@@ -29,6 +28,7 @@ func SpanMiddleware(next http.Handler) http.Handler {
 				semconv.ServerAddress(r.Host),
 				attribute.String("http.scheme", scheme(r)),
 			),
+			trace.WithAttributes(BaggageSpanAttributes(ctx)...),
 		)
 		defer span.End()
 
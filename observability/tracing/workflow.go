@@ -24,7 +24,9 @@ func NewWorkflowTracer(tracer trace.Tracer) *WorkflowTracer {
 	return &WorkflowTracer{tracer: tracer}
 }
 
-// StartWorkflow begins a new span for a workflow execution.
+// StartWorkflow begins a new span for a workflow execution. This and the
+// other Start methods record the tenant, workflow and execution baggage of
+// ctx as span attributes.
 func (w *WorkflowTracer) StartWorkflow(ctx context.Context, workflowType, action string) (context.Context, trace.Span) {
 	ctx, span := w.tracer.Start(ctx, "workflow.execute",
 		trace.WithSpanKind(trace.SpanKindInternal),
@@ -32,6 +34,7 @@ func (w *WorkflowTracer) StartWorkflow(ctx context.Context, workflowType, action
 			attribute.String("workflow.type", workflowType),
 			attribute.String("workflow.action", action),
 		),
+		trace.WithAttributes(BaggageSpanAttributes(ctx)...),
 	)
 	return ctx, span
 }
@@ -44,6 +47,7 @@ func (w *WorkflowTracer) StartStep(ctx context.Context, stepName, stepType strin
 			attribute.String("workflow.step.name", stepName),
 			attribute.String("workflow.step.type", stepType),
 		),
+		trace.WithAttributes(BaggageSpanAttributes(ctx)...),
 	)
 	return ctx, span
 }
@@ -56,6 +60,7 @@ func (w *WorkflowTracer) StartTrigger(ctx context.Context, triggerName, triggerT
 			attribute.String("workflow.trigger.name", triggerName),
 			attribute.String("workflow.trigger.type", triggerType),
 		),
+		trace.WithAttributes(BaggageSpanAttributes(ctx)...),
 	)
 	return ctx, span
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"maps"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/GoCodeAlone/workflow/interfaces"
@@ -32,6 +33,7 @@ func (e *ExprEngine) exprEnv(pc *interfaces.PipelineContext) map[string]any {
 
 	// Functions registered first; everything below overrides name conflicts.
 	maps.Copy(env, TemplateFuncMap())
+	applyTemplateSources(env, pc)
 
 	// Context-aware config lookup.
 	env["config"] = func(key string) string {
@@ -95,6 +97,13 @@ var exprBlockRe = regexp.MustCompile(`\$\{([^}]+)\}`)
 // ResolveExprBlocks replaces all ${ ... } blocks in s by evaluating each
 // expression against pc. Returns the substituted string or the first error.
 func ResolveExprBlocks(s string, pc *interfaces.PipelineContext, ee *ExprEngine) (string, error) {
+	return resolveExprBlocks(s, pc, ee, false)
+}
+
+// resolveExprBlocks is ResolveExprBlocks; with quoteBraces, results that
+// contain braces are replaced by a Go template action printing them, so that
+// the Go template pass cannot parse them.
+func resolveExprBlocks(s string, pc *interfaces.PipelineContext, ee *ExprEngine, quoteBraces bool) (string, error) {
 	var firstErr error
 	result := exprBlockRe.ReplaceAllStringFunc(s, func(match string) string {
 		if firstErr != nil {
@@ -106,7 +115,19 @@ func ResolveExprBlocks(s string, pc *interfaces.PipelineContext, ee *ExprEngine)
 			firstErr = err
 			return match
 		}
+		if quoteBraces && strings.ContainsAny(val, "{}") {
+			return templateLiteral(val)
+		}
 		return val
 	})
 	return result, firstErr
 }
+
+// braceEscaper escapes braces inside a quoted Go string literal, so that the
+// literal cannot contain the {{ or }} of a template action.
+var braceEscaper = strings.NewReplacer("{", `\x7b`, "}", `\x7d`)
+
+// templateLiteral returns a Go template action that prints s verbatim.
+func templateLiteral(s string) string {
+	return "{{ " + braceEscaper.Replace(strconv.Quote(s)) + " }}"
+}
@@ -0,0 +1,77 @@
+package pipeline
+
+import (
+	"fmt"
+
+	"github.com/GoCodeAlone/workflow/i18n"
+	"github.com/GoCodeAlone/workflow/interfaces"
+)
+
+// LocaleCatalog returns the message catalog and resolved locale of a
+// pipeline execution. Pipeline.Execute seeds them into
+// PipelineContext.Metadata["_i18n_catalog"] and Metadata["locale"] when the
+// config declares locales; ok is false otherwise.
+func LocaleCatalog(pc *interfaces.PipelineContext) (catalog *i18n.Catalog, locale string, ok bool) {
+	catalog, ok = pc.Metadata["_i18n_catalog"].(*i18n.Catalog)
+	if !ok {
+		return nil, "", false
+	}
+	locale, _ = pc.Metadata["locale"].(string)
+	if locale == "" {
+		locale = catalog.Default()
+	}
+	return catalog, locale, true
+}
+
+// TranslateArgs converts the optional arguments of the t template function
+// into placeholder values: a single map, or alternating name/value pairs.
+func TranslateArgs(args []any) (map[string]any, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+	if len(args) == 1 {
+		switch m := args[0].(type) {
+		case map[string]any:
+			return m, nil
+		case nil:
+			return nil, nil
+		}
+	}
+	if len(args)%2 != 0 {
+		return nil, fmt.Errorf("expected a map or name/value pairs, got %d arguments", len(args))
+	}
+	out := make(map[string]any, len(args)/2)
+	for i := 0; i < len(args); i += 2 {
+		name, ok := args[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("placeholder name must be a string, got %T", args[i])
+		}
+		out[name] = args[i+1]
+	}
+	return out, nil
+}
+
+// translate implements the t template function for pc. A key without a
+// message renders as the key itself, or fails in strict mode, as does a
+// pipeline without locales.
+func translate(pc *interfaces.PipelineContext, key string, args []any) (string, error) {
+	values, err := TranslateArgs(args)
+	if err != nil {
+		return "", fmt.Errorf("t %q: %w", key, err)
+	}
+	catalog, locale, ok := LocaleCatalog(pc)
+	if !ok {
+		if pc.StrictTemplates {
+			return "", fmt.Errorf("t %q: no locales are configured", key)
+		}
+		return key, nil
+	}
+	msg, err := catalog.Translate(locale, key, values)
+	if err != nil {
+		if pc.StrictTemplates {
+			return "", fmt.Errorf("t: %w", err)
+		}
+		return key, nil
+	}
+	return msg, nil
+}
@@ -5,11 +5,14 @@ package pipeline
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net/url"
+	"os"
 	"reflect"
 	"regexp"
 	"sort"
@@ -195,7 +198,7 @@ func PreprocessTemplate(tmplStr string) string {
 }
 
 // funcMapWithContext returns the base template functions plus context-aware
-// helper functions (step, trigger) that access PipelineContext data directly.
+// helper functions (step, trigger, t) that access PipelineContext data directly.
 func (te *TemplateEngine) funcMapWithContext(pc *interfaces.PipelineContext) template.FuncMap {
 	fm := TemplateFuncMap()
 
@@ -266,6 +269,23 @@ func (te *TemplateEngine) funcMapWithContext(pc *interfaces.PipelineContext) tem
 		return val, nil
 	}
 
+	// t translates a message key into the execution's locale (meta.locale)
+	// using the catalogs of the config's locales: section. Placeholder values
+	// are a map or name/value pairs; "count" selects a plural form.
+	// Usage: {{ t "cart.items" .args }} or {{ t "greeting" "name" .name }}
+	// A key without a message renders as the key, or fails in strict mode.
+	fm["t"] = func(key string, args ...any) (string, error) {
+		return translate(pc, key, args)
+	}
+
+	// hmac signs a message with a secret from a secrets provider module,
+	// never an inline key, and returns the hex digest.
+	// Usage: {{ hmac "sha256" "partner-secrets" "signing_key" .raw_body }}
+	fm["hmac"] = func(algorithm, provider, key, message string) (string, error) {
+		return templateHMAC(pc, algorithm, provider, key, message)
+	}
+
+	applyTemplateSources(fm, pc)
 	return fm
 }
 
@@ -277,6 +297,50 @@ func isMissingKeyError(err error) bool {
 	return strings.Contains(err.Error(), "map has no entry for key")
 }
 
+// UndefinedPathError is returned in strict template mode when a template
+// references a context path that does not exist.
+type UndefinedPathError struct {
+	// Path is the referenced path up to and including the missing key, e.g.
+	// ".steps.auth.affilate_id". For expressions other than a plain field
+	// chain it is the expression text/template reported.
+	Path string
+	// Key is the missing map key.
+	Key string
+	// Err is the underlying text/template error.
+	Err error
+}
+
+func (e *UndefinedPathError) Error() string {
+	return fmt.Sprintf("undefined path %s (no key %q)", e.Path, e.Key)
+}
+
+func (e *UndefinedPathError) Unwrap() error { return e.Err }
+
+// missingKeyRe extracts the failing expression and key from a text/template
+// missing-key error: `executing "" at <.steps.a.b>: map has no entry for key "b"`.
+var missingKeyRe = regexp.MustCompile(`at <([^>]*)>: map has no entry for key "([^"]*)"`)
+
+// undefinedPath converts a text/template missing-key error into an
+// *UndefinedPathError, or returns nil when err is not one.
+func undefinedPath(err error) *UndefinedPathError {
+	m := missingKeyRe.FindStringSubmatch(err.Error())
+	if m == nil {
+		return nil
+	}
+	expr, key := m[1], m[2]
+	path := expr
+	if strings.HasPrefix(expr, ".") && !strings.ContainsAny(expr, " ()") {
+		segs := strings.Split(expr[1:], ".")
+		for i, seg := range segs {
+			if seg == key {
+				path = "." + strings.Join(segs[:i+1], ".")
+				break
+			}
+		}
+	}
+	return &UndefinedPathError{Path: path, Key: key, Err: err}
+}
+
 // Resolve evaluates a template string against a PipelineContext.
 // Supports two syntaxes that may be mixed in a single string:
 //
@@ -284,14 +348,19 @@ func isMissingKeyError(err error) bool {
 //   - Expr expressions: ${ body.name }, ${ upper(name) }, etc.
 //
 // ${ } blocks are evaluated first; the resulting string is then passed through
-// the Go template engine if any {{ }} blocks remain.
+// the Go template engine if the original string has {{ }} blocks.
+//
+// Values from the context are never evaluated as templates: the output of
+// {{ .name }} is not parsed again, and a ${ } result that contains braces is
+// embedded in the Go template as a string literal. A user-controlled value
+// such as "{{ env \"HOME\" }}" therefore renders verbatim.
 //
 // If the string contains neither {{ }} nor ${ }, it is returned as-is.
 //
 // Missing key behaviour (direct map access via {{ .steps.foo.bar }}):
 //   - When pc.StrictTemplates is true (Option A), any reference to a missing
 //     map key causes an immediate error via missingkey=error, surfacing typos
-//     as failures.
+//     as failures. The error wraps an *UndefinedPathError naming the path.
 //   - When pc.StrictTemplates is false (the default, Option C), a missing key
 //     resolves to the zero value AND a WARN log is emitted via pc.Logger (or
 //     slog.Default() when no logger is set) so that the silent failure is
@@ -309,23 +378,20 @@ func (te *TemplateEngine) Resolve(tmplStr string, pc *interfaces.PipelineContext
 		return tmplStr, nil
 	}
 
-	// Process ${ } blocks first so their output can flow into Go template blocks.
+	// Process ${ } blocks first. Their results are data, never template
+	// source: without {{ }} blocks the result is final, and with them any
+	// result containing braces is embedded as a quoted literal.
 	if hasExpr {
 		var err error
-		tmplStr, err = ResolveExprBlocks(tmplStr, pc, NewExprEngine())
+		tmplStr, err = resolveExprBlocks(tmplStr, pc, NewExprEngine(), hasGoTmpl)
 		if err != nil {
 			return "", err
 		}
-		// Re-check after substitution; expr results may have consumed all content.
-		if !strings.Contains(tmplStr, "{{") {
+		if !hasGoTmpl {
 			return tmplStr, nil
 		}
 	}
 
-	if !strings.Contains(tmplStr, "{{") {
-		return tmplStr, nil
-	}
-
 	tmplStr = PreprocessTemplate(tmplStr)
 
 	// Parse once; we may execute with different missingkey options below.
@@ -340,6 +406,9 @@ func (te *TemplateEngine) Resolve(tmplStr string, pc *interfaces.PipelineContext
 	if pc != nil && pc.StrictTemplates {
 		var buf bytes.Buffer
 		if err := t.Option("missingkey=error").Execute(&buf, data); err != nil {
+			if undef := undefinedPath(err); undef != nil {
+				return "", fmt.Errorf("template exec error: %w", undef)
+			}
 			return "", fmt.Errorf("template exec error: %w", err)
 		}
 		return buf.String(), nil
@@ -367,10 +436,11 @@ func (te *TemplateEngine) Resolve(tmplStr string, pc *interfaces.PipelineContext
 				pipelineName = fmt.Sprint(v)
 			}
 		}
-		logger.Warn("template resolved missing key to zero value",
-			"pipeline", pipelineName,
-			"error", execErr,
-		)
+		attrs := []any{"pipeline", pipelineName, "error", execErr}
+		if undef := undefinedPath(execErr); undef != nil {
+			attrs = append(attrs, "path", undef.Path)
+		}
+		logger.Warn("template resolved missing key to zero value", attrs...)
 
 		// Re-execute with zero mode to preserve backward-compatible output.
 		buf.Reset()
@@ -584,8 +654,74 @@ func classifyForSort(v any) (rank int, num float64, str string) {
 	}
 }
 
-// TemplateFuncMap returns the function map available in pipeline templates.
+// formatTime implements the formatTime template function.
+func formatTime(layout string, t any) (string, error) {
+	if l, ok := timeLayouts[layout]; ok {
+		layout = l
+	}
+	var tm time.Time
+	switch v := t.(type) {
+	case time.Time:
+		tm = v
+	case *time.Time:
+		if v == nil {
+			return "", fmt.Errorf("formatTime: nil time")
+		}
+		tm = *v
+	case string:
+		parsed, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return "", fmt.Errorf("formatTime: %w", err)
+		}
+		tm = parsed
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		secs := toFloat64(v)
+		whole := int64(secs)
+		tm = time.Unix(whole, int64((secs-float64(whole))*float64(time.Second))).UTC()
+	default:
+		return "", fmt.Errorf("formatTime: unsupported time value of type %T", t)
+	}
+	return tm.Format(layout), nil
+}
+
+// TemplateFuncMap returns the function map available in pipeline templates:
+// the built-in functions plus those registered with RegisterTemplateFuncs.
 func TemplateFuncMap() template.FuncMap {
+	fm := builtinTemplateFuncMap()
+	addPluginFuncs(fm)
+	return fm
+}
+
+// nowFunc returns the now template function reading clock.
+func nowFunc(clock func() time.Time) func(args ...string) string {
+	return func(args ...string) string {
+		layout := time.RFC3339
+		if len(args) > 0 && args[0] != "" {
+			if l, ok := timeLayouts[args[0]]; ok {
+				layout = l
+			} else {
+				layout = args[0]
+			}
+		}
+		return clock().UTC().Format(layout)
+	}
+}
+
+// quote renders v as a JSON string literal.
+func quote(v any) string {
+	s, ok := v.(string)
+	if !ok && v != nil {
+		s = fmt.Sprintf("%v", v)
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s) // encoding a string cannot fail
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// builtinTemplateFuncMap returns the built-in template functions.
+func builtinTemplateFuncMap() template.FuncMap {
 	return template.FuncMap{
 		// uuid generates a new UUID v4 string.
 		"uuid": func() string {
@@ -598,17 +734,7 @@ func TemplateFuncMap() template.FuncMap {
 		// now returns the current UTC time formatted with the given Go time layout
 		// string or named constant (e.g. "RFC3339", "2006-01-02").
 		// When called with no argument it defaults to RFC3339.
-		"now": func(args ...string) string {
-			layout := time.RFC3339
-			if len(args) > 0 && args[0] != "" {
-				if l, ok := timeLayouts[args[0]]; ok {
-					layout = l
-				} else {
-					layout = args[0]
-				}
-			}
-			return time.Now().UTC().Format(layout)
-		},
+		"now": nowFunc(time.Now),
 		// lower converts a string to lowercase.
 		"lower": strings.ToLower,
 		// default returns the fallback value if the primary value is empty.
@@ -686,6 +812,16 @@ func TemplateFuncMap() template.FuncMap {
 		},
 		// trimSpace removes leading and trailing whitespace.
 		"trimSpace": strings.TrimSpace,
+		// trim removes leading and trailing whitespace (alias for trimSpace).
+		"trim": strings.TrimSpace,
+		// quote renders a value as a JSON string literal, for embedding
+		// user-controlled values in JSON bodies.
+		"quote": quote,
+		// sha256 returns the hex SHA-256 digest of a string.
+		"sha256": func(s string) string {
+			sum := sha256.Sum256([]byte(s))
+			return hex.EncodeToString(sum[:])
+		},
 		// urlEncode percent-encodes a string for use in URLs.
 		"urlEncode": url.QueryEscape,
 		// b64 encodes a string as standard base64 (RFC 4648). Typical use:
@@ -693,6 +829,30 @@ func TemplateFuncMap() template.FuncMap {
 		"b64": func(s string) string {
 			return base64.StdEncoding.EncodeToString([]byte(s))
 		},
+		// b64enc encodes a string as standard base64 (alias for b64).
+		"b64enc": func(s string) string {
+			return base64.StdEncoding.EncodeToString([]byte(s))
+		},
+		// b64dec decodes standard base64, padded or not. Invalid input fails
+		// the template rather than producing a garbled value.
+		"b64dec": func(s string) (string, error) {
+			b, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				b, err = base64.RawStdEncoding.DecodeString(s)
+			}
+			if err != nil {
+				return "", fmt.Errorf("b64dec: %w", err)
+			}
+			return string(b), nil
+		},
+		// env returns the value of the named environment variable, or an empty
+		// string if it is unset.
+		"env": os.Getenv,
+		// formatTime formats a time with the given Go time layout or named
+		// constant. t may be a time.Time, an RFC 3339 string or Unix seconds.
+		"formatTime": formatTime,
+		// formatDate formats a time like formatTime (alias for formatTime).
+		"formatDate": formatTime,
 
 		// --- Math functions ---
 
@@ -0,0 +1,239 @@
+package pipeline
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1" //nolint:gosec // offered for partner APIs that still sign with HMAC-SHA1
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"math/rand"
+	"reflect"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/interfaces"
+	"github.com/google/uuid"
+)
+
+// contextFuncNames are the template functions bound to a PipelineContext by
+// funcMapWithContext, and reservedFuncNames the names the ExprEngine binds to
+// context namespaces. Plugins may register neither.
+var (
+	contextFuncNames  = []string{"step", "trigger", "t", "hmac"}
+	reservedFuncNames = []string{"steps", "body", "meta", "current"}
+)
+
+// funcNameRe matches the identifiers text/template accepts as function names.
+var funcNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// pluginFunc is a template function registered by a plugin.
+type pluginFunc struct {
+	owner string
+	fn    any
+}
+
+var (
+	pluginFuncsMu sync.RWMutex
+	pluginFuncs   = map[string]pluginFunc{}
+)
+
+// RegisterTemplateFuncs adds the functions of owner, typically an engine
+// plugin's name, to every step template and ${ } expression. Functions must
+// be pure: same arguments, same result, no side effects. Each must return
+// one value, or a value and an error.
+//
+// Registration fails, and registers nothing, when a name is not a valid
+// identifier, a value is not such a function, or a name is taken by a
+// built-in function or another owner's function. Registering again for the
+// same owner replaces its previous functions.
+func RegisterTemplateFuncs(owner string, funcs map[string]any) error {
+	builtins := builtinTemplateFuncMap()
+	for _, name := range contextFuncNames {
+		builtins[name] = nil
+	}
+	for _, name := range reservedFuncNames {
+		builtins[name] = nil
+	}
+
+	pluginFuncsMu.Lock()
+	defer pluginFuncsMu.Unlock()
+	for name, fn := range funcs {
+		if !funcNameRe.MatchString(name) {
+			return fmt.Errorf("template function %q of %q: name is not a valid identifier", name, owner)
+		}
+		if _, taken := builtins[name]; taken {
+			return fmt.Errorf("template function %q of %q: name is taken by a built-in function", name, owner)
+		}
+		if existing, taken := pluginFuncs[name]; taken && existing.owner != owner {
+			return fmt.Errorf("template function %q of %q: name is taken by %q", name, owner, existing.owner)
+		}
+		if err := checkTemplateFunc(fn); err != nil {
+			return fmt.Errorf("template function %q of %q: %w", name, owner, err)
+		}
+	}
+	for name, f := range pluginFuncs {
+		if f.owner == owner {
+			delete(pluginFuncs, name)
+		}
+	}
+	for name, fn := range funcs {
+		pluginFuncs[name] = pluginFunc{owner: owner, fn: fn}
+	}
+	return nil
+}
+
+// checkTemplateFunc reports why fn cannot be called from a template.
+func checkTemplateFunc(fn any) error {
+	t := reflect.TypeOf(fn)
+	if t == nil || t.Kind() != reflect.Func || reflect.ValueOf(fn).IsNil() {
+		return fmt.Errorf("value of type %T is not a function", fn)
+	}
+	errorType := reflect.TypeFor[error]()
+	switch {
+	case t.NumOut() == 1 && t.Out(0) != errorType:
+	case t.NumOut() == 2 && t.Out(1) == errorType:
+	default:
+		return fmt.Errorf("%s must return one value, or a value and an error", t)
+	}
+	return nil
+}
+
+// PluginTemplateFunc describes a template function registered with
+// RegisterTemplateFuncs.
+type PluginTemplateFunc struct {
+	Name      string
+	Owner     string
+	Signature string
+}
+
+// PluginTemplateFuncs returns the functions registered with
+// RegisterTemplateFuncs, sorted by name.
+func PluginTemplateFuncs() []PluginTemplateFunc {
+	pluginFuncsMu.RLock()
+	defer pluginFuncsMu.RUnlock()
+	out := make([]PluginTemplateFunc, 0, len(pluginFuncs))
+	for name, f := range pluginFuncs {
+		sig := reflect.TypeOf(f.fn).String()
+		out = append(out, PluginTemplateFunc{Name: name, Owner: f.owner, Signature: name + sig[len("func"):]})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// addPluginFuncs copies the registered plugin functions into fm.
+func addPluginFuncs(fm map[string]any) {
+	pluginFuncsMu.RLock()
+	defer pluginFuncsMu.RUnlock()
+	for name, f := range pluginFuncs {
+		fm[name] = f.fn
+	}
+}
+
+// TemplateSources supplies the clock of the now function and the randomness
+// of the uuid and uuidv4 functions, so that tests and replays render the same
+// values on every run. A nil field keeps the system source.
+type TemplateSources struct {
+	Now    func() time.Time
+	Random io.Reader
+}
+
+type templateSourcesKey struct{}
+
+// WithTemplateSources returns a context whose pipeline executions render
+// templates with src. Pipeline.Execute seeds it into
+// PipelineContext.Metadata["_template_sources"].
+func WithTemplateSources(ctx context.Context, src TemplateSources) context.Context {
+	return context.WithValue(ctx, templateSourcesKey{}, src)
+}
+
+// TemplateSourcesFromContext returns the sources set by WithTemplateSources.
+func TemplateSourcesFromContext(ctx context.Context) (TemplateSources, bool) {
+	src, ok := ctx.Value(templateSourcesKey{}).(TemplateSources)
+	return src, ok
+}
+
+// FixedTemplateSources returns sources whose clock always reads now and whose
+// random stream is determined by seed.
+func FixedTemplateSources(now time.Time, seed int64) TemplateSources {
+	return TemplateSources{
+		Now:    func() time.Time { return now },
+		Random: &lockedReader{r: rand.New(rand.NewSource(seed))}, //nolint:gosec // deterministic by design
+	}
+}
+
+// lockedReader serialises reads, since parallel steps resolve templates
+// concurrently.
+type lockedReader struct {
+	mu sync.Mutex
+	r  io.Reader
+}
+
+func (l *lockedReader) Read(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Read(p)
+}
+
+// applyTemplateSources replaces the now, uuid and uuidv4 functions of fm
+// with ones reading the sources seeded into pc, if any.
+func applyTemplateSources(fm map[string]any, pc *interfaces.PipelineContext) {
+	if pc == nil {
+		return
+	}
+	src, ok := pc.Metadata["_template_sources"].(TemplateSources)
+	if !ok {
+		return
+	}
+	if src.Now != nil {
+		fm["now"] = nowFunc(src.Now)
+	}
+	if src.Random != nil {
+		newUUID := func() (string, error) {
+			id, err := uuid.NewRandomFromReader(src.Random)
+			if err != nil {
+				return "", fmt.Errorf("uuid: %w", err)
+			}
+			return id.String(), nil
+		}
+		fm["uuid"] = newUUID
+		fm["uuidv4"] = newUUID
+	}
+}
+
+// SecretLookup returns the value of key in the secrets provider module named
+// provider. Pipeline.Execute seeds the engine's lookup into
+// PipelineContext.Metadata["_secret_lookup"] for the hmac function.
+type SecretLookup func(ctx context.Context, provider, key string) (string, error)
+
+// hmacHashes are the algorithms of the hmac function.
+var hmacHashes = map[string]func() hash.Hash{
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// templateHMAC implements the hmac template function: the hex HMAC of
+// message under the secret key of provider.
+func templateHMAC(pc *interfaces.PipelineContext, algorithm, provider, key, message string) (string, error) {
+	newHash, ok := hmacHashes[algorithm]
+	if !ok {
+		return "", fmt.Errorf("hmac: unsupported algorithm %q (want sha1, sha256 or sha512)", algorithm)
+	}
+	lookup, _ := pc.Metadata["_secret_lookup"].(SecretLookup)
+	if lookup == nil {
+		return "", fmt.Errorf("hmac: no secrets are available to this pipeline")
+	}
+	secret, err := lookup(context.Background(), provider, key)
+	if err != nil {
+		return "", fmt.Errorf("hmac: secret %q of %q: %w", key, provider, err)
+	}
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write([]byte(message))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
@@ -130,42 +130,58 @@ func (d *Deployer) Delete(ctx context.Context, appName, namespace string) error
 	return nil
 }
 
-// Diff compares artifacts against live state and returns a human-readable diff.
-func (d *Deployer) Diff(ctx context.Context, artifacts *deploy.DeployArtifacts) (string, error) {
-	var diff string
-
+// Plan reports, for each object in the artifacts, whether applying it would
+// create a new resource or update an existing one. Status is "create",
+// "update" or "unknown" (with Message set) when the live object cannot be
+// read. Nothing is changed in the cluster.
+func (d *Deployer) Plan(ctx context.Context, artifacts *deploy.DeployArtifacts) []deploy.ResourceStatus {
+	var plan []deploy.ResourceStatus
 	for _, obj := range artifacts.Objects {
 		u, ok := obj.(*unstructured.Unstructured)
 		if !ok {
 			continue
 		}
+		rs := deploy.ResourceStatus{Kind: u.GetKind(), Name: u.GetName(), Namespace: u.GetNamespace()}
 
 		gvr, err := gvrFromUnstructured(u)
 		if err != nil {
+			rs.Status, rs.Message = "unknown", err.Error()
+			plan = append(plan, rs)
 			continue
 		}
 
 		var resource dynamic.ResourceInterface
-		ns := u.GetNamespace()
-		if ns != "" {
-			resource = d.client.Dynamic.Resource(gvr).Namespace(ns)
+		if rs.Namespace != "" {
+			resource = d.client.Dynamic.Resource(gvr).Namespace(rs.Namespace)
 		} else {
 			resource = d.client.Dynamic.Resource(gvr)
 		}
 
-		live, err := resource.Get(ctx, u.GetName(), metav1.GetOptions{})
-		if errors.IsNotFound(err) {
-			diff += fmt.Sprintf("+ %s/%s (new)\n", u.GetKind(), u.GetName())
-			continue
-		}
-		if err != nil {
-			diff += fmt.Sprintf("? %s/%s (error: %v)\n", u.GetKind(), u.GetName(), err)
-			continue
+		_, err = resource.Get(ctx, u.GetName(), metav1.GetOptions{})
+		switch {
+		case errors.IsNotFound(err):
+			rs.Status = "create"
+		case err != nil:
+			rs.Status, rs.Message = "unknown", err.Error()
+		default:
+			rs.Status = "update"
 		}
+		plan = append(plan, rs)
+	}
+	return plan
+}
 
-		// Compare resource versions
-		if live.GetResourceVersion() != "" {
-			diff += fmt.Sprintf("~ %s/%s (update)\n", u.GetKind(), u.GetName())
+// Diff compares artifacts against live state and returns a human-readable diff.
+func (d *Deployer) Diff(ctx context.Context, artifacts *deploy.DeployArtifacts) (string, error) {
+	var diff string
+	for _, rs := range d.Plan(ctx, artifacts) {
+		switch rs.Status {
+		case "create":
+			diff += fmt.Sprintf("+ %s/%s (new)\n", rs.Kind, rs.Name)
+		case "update":
+			diff += fmt.Sprintf("~ %s/%s (update)\n", rs.Kind, rs.Name)
+		case "unknown":
+			diff += fmt.Sprintf("? %s/%s (error: %s)\n", rs.Kind, rs.Name, rs.Message)
 		}
 	}
 
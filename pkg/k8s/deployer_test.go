@@ -0,0 +1,53 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/GoCodeAlone/workflow/deploy"
+)
+
+func TestDeployer_Plan(t *testing.T) {
+	obj := func(apiVersion, kind, name string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata":   map[string]any{"name": name, "namespace": "prod"},
+		}}
+	}
+	live := obj("v1", "ConfigMap", "app-config")
+	client := &Client{Dynamic: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			{Version: "v1", Resource: "configmaps"}:                 "ConfigMapList",
+			{Group: "apps", Version: "v1", Resource: "deployments"}: "DeploymentList",
+		}, live)}
+
+	artifacts := &deploy.DeployArtifacts{Namespace: "prod", Objects: []any{
+		obj("v1", "ConfigMap", "app-config"),
+		obj("apps/v1", "Deployment", "app"),
+		obj("example.com/v1", "Widget", "w"),
+	}}
+	plan := NewDeployer(client).Plan(context.Background(), artifacts)
+	want := []string{"update", "create", "unknown"}
+	if len(plan) != len(want) {
+		t.Fatalf("plan = %+v", plan)
+	}
+	for i, rs := range plan {
+		if rs.Status != want[i] {
+			t.Errorf("%s/%s: status %q, want %q", rs.Kind, rs.Name, rs.Status, want[i])
+		}
+	}
+
+	diff, err := NewDeployer(client).Diff(context.Background(), artifacts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff != "~ ConfigMap/app-config (update)\n+ Deployment/app (new)\n? Widget/w (error: unknown kind \"Widget\")\n" {
+		t.Errorf("diff = %q", diff)
+	}
+}
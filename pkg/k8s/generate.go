@@ -0,0 +1,305 @@
+package k8s
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/GoCodeAlone/workflow/config"
+	"github.com/GoCodeAlone/workflow/manifest"
+	"github.com/GoCodeAlone/workflow/manifest/render"
+	"github.com/GoCodeAlone/workflow/pkg/k8s/resources"
+	"github.com/GoCodeAlone/workflow/platform"
+)
+
+// serverWorkDir is the working directory of the workflow server image;
+// relative storage.local roots resolve against it.
+const serverWorkDir = "/app"
+
+// ConfigHashAnnotation records the hash of the config a manifest was
+// generated from. It is set on the server pod template so that changing
+// the config rolls the pods.
+const ConfigHashAnnotation = "workflow.gocodealone.com/config-hash"
+
+// GenerateOpts configures Generate.
+type GenerateOpts struct {
+	// Name of the server Deployment; also the prefix of its companion objects.
+	Name      string
+	Namespace string
+	// Image of the workflow server.
+	Image string
+	// ConfigFileData is the workflow config file, shipped in a ConfigMap.
+	ConfigFileData []byte
+}
+
+// Generate builds the Kubernetes objects that run the workflow server for
+// cfg, plus a Deployment and Service for each application-tier
+// platform.resource of type container_runtime:
+//
+//   - a ConfigMap holding the config file, mounted at /etc/workflow
+//   - a Secret for variables referenced from sensitive fields and a
+//     ConfigMap for the other referenced variables, with empty values to
+//     fill in before applying
+//   - a PersistentVolumeClaim per storage.local root
+//   - the server Deployment, and a Service for its http.server ports
+//
+// It returns the objects in apply order with warnings about parts of the
+// config that could not be translated.
+func Generate(cfg *config.WorkflowConfig, opts GenerateOpts) (*ManifestSet, []string, error) {
+	if opts.Name == "" {
+		return nil, nil, fmt.Errorf("name is required")
+	}
+	if opts.Image == "" {
+		return nil, nil, fmt.Errorf("image is required")
+	}
+	ns := opts.Namespace
+	if ns == "" {
+		ns = "default"
+	}
+	m := manifest.AnalyzeWithName(cfg, opts.Name)
+	env := render.AnalyzeEnv(cfg)
+	warnings := append([]string(nil), env.Warnings...)
+	labels := func(name string) map[string]string {
+		return map[string]string{"app": name, "app.kubernetes.io/managed-by": "wfctl"}
+	}
+
+	ms := &ManifestSet{}
+
+	if ns != "default" {
+		if err := ms.AddRuntime(resources.NewNamespace(ns, nil)); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := ms.AddRuntime(resources.NewConfigMap(resources.ConfigMapOpts{
+		Name:      opts.Name,
+		Namespace: ns,
+		Labels:    labels(opts.Name),
+		Data:      map[string]string{"app.yaml": string(opts.ConfigFileData)},
+	})); err != nil {
+		return nil, nil, err
+	}
+
+	// envSources maps each referenced variable to the ConfigMap or Secret key
+	// that supplies it.
+	envSources := make(map[string]*corev1.EnvVarSource)
+	var envVars []corev1.EnvVar
+	if len(env.Plain) > 0 {
+		cmName := opts.Name + "-env"
+		data := make(map[string]string, len(env.Plain))
+		for _, n := range env.Plain {
+			data[n] = ""
+			envSources[n] = &corev1.EnvVarSource{
+				ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: cmName}, Key: n},
+			}
+			envVars = append(envVars, corev1.EnvVar{Name: n, ValueFrom: envSources[n]})
+		}
+		if err := ms.AddRuntime(resources.NewConfigMap(resources.ConfigMapOpts{Name: cmName, Namespace: ns, Labels: labels(opts.Name), Data: data})); err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(env.Secrets) > 0 {
+		secretName := opts.Name + "-secrets"
+		data := make(map[string]string, len(env.Secrets))
+		for _, n := range env.Secrets {
+			data[n] = ""
+			envSources[n] = &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: secretName}, Key: n},
+			}
+			envVars = append(envVars, corev1.EnvVar{Name: n, ValueFrom: envSources[n]})
+		}
+		if err := ms.AddRuntime(resources.NewSecret(resources.SecretOpts{Name: secretName, Namespace: ns, Labels: labels(opts.Name), StringData: data})); err != nil {
+			return nil, nil, err
+		}
+	}
+	sort.Slice(envVars, func(i, j int) bool { return envVars[i].Name < envVars[j].Name })
+
+	var volumes []corev1.Volume
+	var mounts []corev1.VolumeMount
+	for _, st := range m.Storage {
+		if st.Type != "local" {
+			continue
+		}
+		if st.RootDir == "" {
+			warnings = append(warnings, fmt.Sprintf("storage %q has no rootDir; no volume generated", st.ModuleName))
+			continue
+		}
+		claim := opts.Name + "-" + st.ModuleName
+		if err := ms.AddRuntime(resources.NewPVC(resources.PVCOpts{Name: claim, Namespace: ns, Labels: labels(opts.Name)})); err != nil {
+			return nil, nil, err
+		}
+		mountPath := st.RootDir
+		if !path.IsAbs(mountPath) {
+			mountPath = path.Join(serverWorkDir, mountPath)
+		}
+		volumes = append(volumes, corev1.Volume{Name: st.ModuleName, VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim},
+		}})
+		mounts = append(mounts, corev1.VolumeMount{Name: st.ModuleName, MountPath: mountPath})
+	}
+
+	var ports []int32
+	var svcPorts []resources.ServicePort
+	seen := make(map[int]bool)
+	for _, p := range m.Ports {
+		if seen[p.Port] {
+			continue
+		}
+		seen[p.Port] = true
+		ports = append(ports, int32(p.Port))                                                                                                     //nolint:gosec // G115 — port parsed from a listen address
+		svcPorts = append(svcPorts, resources.ServicePort{Name: fmt.Sprintf("http-%d", p.Port), Port: int32(p.Port), TargetPort: int32(p.Port)}) //nolint:gosec // G115 — port parsed from a listen address
+	}
+
+	health := healthPath(cfg)
+	dep := resources.NewDeployment(resources.DeploymentOpts{
+		Name:          opts.Name,
+		Namespace:     ns,
+		Image:         opts.Image,
+		Labels:        labels(opts.Name),
+		Env:           envVars,
+		Ports:         ports,
+		CPURequest:    fmt.Sprintf("%dm", int(m.ResourceEst.CPUCores*1000)),
+		MemoryRequest: fmt.Sprintf("%dMi", m.ResourceEst.MemoryMB),
+		ConfigMapName: opts.Name,
+		Args:          []string{"-config", "/etc/workflow/app.yaml"},
+		HealthPath:    health,
+	})
+	if health == "" {
+		dep.Spec.Template.Spec.Containers[0].LivenessProbe = nil
+		dep.Spec.Template.Spec.Containers[0].ReadinessProbe = nil
+	}
+	dep.Spec.Template.Annotations = map[string]string{ConfigHashAnnotation: render.ConfigHash(opts.ConfigFileData)}
+	dep.Spec.Template.Spec.Volumes = append(dep.Spec.Template.Spec.Volumes, volumes...)
+	dep.Spec.Template.Spec.Containers[0].VolumeMounts = append(dep.Spec.Template.Spec.Containers[0].VolumeMounts, mounts...)
+	if err := ms.AddRuntime(dep); err != nil {
+		return nil, nil, err
+	}
+	if len(svcPorts) > 0 {
+		if err := ms.AddRuntime(resources.NewService(resources.ServiceOpts{Name: opts.Name, Namespace: ns, Labels: labels(opts.Name), Ports: svcPorts})); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	platformResources, err := render.PlatformResources(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, res := range platformResources {
+		decl := res.Declaration
+		if decl.Tier != platform.TierApplication {
+			continue
+		}
+		if decl.Type != "container_runtime" {
+			warnings = append(warnings, fmt.Sprintf("platform.resource %q: type %q has no kubernetes mapping", res.ModuleName, decl.Type))
+			continue
+		}
+		if err := addContainerRuntime(ms, decl, ns, labels(decl.Name), envSources); err != nil {
+			return nil, nil, fmt.Errorf("platform.resource %q: %w", res.ModuleName, err)
+		}
+	}
+
+	ms.Sort()
+	return ms, warnings, nil
+}
+
+// healthPath returns the path served by the config's health.checker
+// module, or "" when the config has none.
+func healthPath(cfg *config.WorkflowConfig) string {
+	for _, mod := range cfg.Modules {
+		if mod.Type != "health.checker" {
+			continue
+		}
+		if p, ok := mod.Config["healthPath"].(string); ok && p != "" {
+			return p
+		}
+		return "/healthz"
+	}
+	return ""
+}
+
+// envPlaceholder matches an env value that is a single ${NAME} reference.
+var envPlaceholder = regexp.MustCompile(`^\$\{([A-Za-z_][A-Za-z0-9_]*)\}$`)
+
+// addContainerRuntime adds a Deployment, and a Service when ports are
+// declared, for a container_runtime capability. Env values that reference
+// a variable are read from the key in envSources that supplies it.
+func addContainerRuntime(ms *ManifestSet, decl platform.CapabilityDeclaration, ns string, labels map[string]string, envSources map[string]*corev1.EnvVarSource) error {
+	props := decl.Properties
+	image, _ := props["image"].(string)
+	if image == "" {
+		return fmt.Errorf("'image' property is required")
+	}
+	opts := resources.DeploymentOpts{
+		Name:      decl.Name,
+		Namespace: ns,
+		Image:     image,
+		Labels:    labels,
+	}
+	if n, ok := intProp(props["replicas"]); ok {
+		opts.Replicas = int32(n) //nolint:gosec // G115 — replica count from config
+	}
+	opts.CPURequest, _ = props["cpu"].(string)
+	opts.MemoryRequest, _ = props["memory"].(string)
+	if cmd, ok := props["command"].(string); ok && cmd != "" {
+		opts.Command = strings.Fields(cmd)
+	}
+	if env, ok := props["env"].(map[string]any); ok {
+		for k, v := range env {
+			value := fmt.Sprint(v)
+			if m := envPlaceholder.FindStringSubmatch(value); m != nil && envSources[m[1]] != nil {
+				opts.Env = append(opts.Env, corev1.EnvVar{Name: k, ValueFrom: envSources[m[1]]})
+				continue
+			}
+			opts.Env = append(opts.Env, corev1.EnvVar{Name: k, Value: value})
+		}
+		sort.Slice(opts.Env, func(i, j int) bool { return opts.Env[i].Name < opts.Env[j].Name })
+	}
+	var svcPorts []resources.ServicePort
+	if raw, ok := props["ports"].([]any); ok {
+		for _, item := range raw {
+			port, ok := intProp(item)
+			if !ok {
+				if m, isMap := item.(map[string]any); isMap {
+					port, ok = intProp(m["container_port"])
+				}
+			}
+			if !ok || port <= 0 {
+				return fmt.Errorf("invalid port %v", item)
+			}
+			opts.Ports = append(opts.Ports, int32(port))                                                                                       //nolint:gosec // G115 — port from config
+			svcPorts = append(svcPorts, resources.ServicePort{Name: fmt.Sprintf("port-%d", port), Port: int32(port), TargetPort: int32(port)}) //nolint:gosec // G115 — port from config
+		}
+	}
+
+	dep := resources.NewDeployment(opts)
+	hc, _ := props["health_check"].(map[string]any)
+	if p, ok := hc["path"].(string); ok && p != "" && len(opts.Ports) > 0 {
+		dep.Spec.Template.Spec.Containers[0].LivenessProbe.HTTPGet.Path = p
+		dep.Spec.Template.Spec.Containers[0].ReadinessProbe.HTTPGet.Path = p
+	} else {
+		dep.Spec.Template.Spec.Containers[0].LivenessProbe = nil
+		dep.Spec.Template.Spec.Containers[0].ReadinessProbe = nil
+	}
+	if err := ms.AddRuntime(dep); err != nil {
+		return err
+	}
+	if len(svcPorts) == 0 {
+		return nil
+	}
+	return ms.AddRuntime(resources.NewService(resources.ServiceOpts{Name: decl.Name, Namespace: ns, Labels: labels, Ports: svcPorts}))
+}
+
+func intProp(v any) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	case float64:
+		return int(n), true
+	}
+	return 0, false
+}
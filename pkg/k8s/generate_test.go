@@ -0,0 +1,108 @@
+package k8s
+
+import (
+	"bytes"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/GoCodeAlone/workflow/config"
+)
+
+func generateConfig() *config.WorkflowConfig {
+	return &config.WorkflowConfig{
+		Modules: []config.ModuleConfig{
+			{Name: "server", Type: "http.server", Config: map[string]any{"address": ":8080"}},
+			{Name: "db", Type: "database.workflow", Config: map[string]any{"driver": "postgres", "dsn": "${DATABASE_URL}"}},
+			{Name: "files", Type: "storage.local", Config: map[string]any{"rootDir": "./data"}},
+			{Name: "worker", Type: "platform.resource", Config: map[string]any{
+				"type": "container_runtime",
+				"capabilities": map[string]any{
+					"image":    "example/worker:1",
+					"replicas": 2,
+					"ports":    []any{9000},
+					"env":      map[string]any{"DB": "${DATABASE_URL}", "MODE": "batch"},
+				},
+			}},
+			{Name: "queue", Type: "platform.resource", Config: map[string]any{"type": "message_queue"}},
+		},
+	}
+}
+
+func findObject(ms *ManifestSet, kind, name string) *unstructured.Unstructured {
+	for _, obj := range ms.Objects {
+		if obj.GetKind() == kind && obj.GetName() == name {
+			return obj
+		}
+	}
+	return nil
+}
+
+func TestGenerate(t *testing.T) {
+	opts := GenerateOpts{Name: "shop", Image: "shop:v1", ConfigFileData: []byte("modules: []\n")}
+	ms, warnings, err := Generate(generateConfig(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("warnings = %v, want one for the message_queue resource", warnings)
+	}
+
+	for _, want := range []struct{ kind, name string }{
+		{"ConfigMap", "shop"},
+		{"Secret", "shop-secrets"},
+		{"PersistentVolumeClaim", "shop-files"},
+		{"Deployment", "shop"},
+		{"Service", "shop"},
+		{"Deployment", "worker"},
+		{"Service", "worker"},
+	} {
+		if findObject(ms, want.kind, want.name) == nil {
+			t.Errorf("missing %s %s", want.kind, want.name)
+		}
+	}
+	if findObject(ms, "ConfigMap", "shop-env") != nil {
+		t.Error("unexpected env ConfigMap: the config references no plain variables")
+	}
+
+	dep := findObject(ms, "Deployment", "shop")
+	containers, _, _ := unstructured.NestedSlice(dep.Object, "spec", "template", "spec", "containers")
+	c := containers[0].(map[string]any)
+	env := c["env"].([]any)[0].(map[string]any)
+	if ref, _, _ := unstructured.NestedString(env, "valueFrom", "secretKeyRef", "name"); env["name"] != "DATABASE_URL" || ref != "shop-secrets" {
+		t.Errorf("server env = %v, want DATABASE_URL from shop-secrets", env)
+	}
+	mounts := c["volumeMounts"].([]any)
+	if got := mounts[len(mounts)-1].(map[string]any)["mountPath"]; got != "/app/data" {
+		t.Errorf("storage mount path = %v, want /app/data", got)
+	}
+	if _, ok := c["livenessProbe"]; ok {
+		t.Error("probes set without a health.checker module")
+	}
+	if hash, _, _ := unstructured.NestedString(dep.Object, "spec", "template", "metadata", "annotations", ConfigHashAnnotation); hash == "" {
+		t.Error("missing config hash annotation")
+	}
+
+	worker := findObject(ms, "Deployment", "worker")
+	containers, _, _ = unstructured.NestedSlice(worker.Object, "spec", "template", "spec", "containers")
+	workerEnv := containers[0].(map[string]any)["env"].([]any)
+	if ref, _, _ := unstructured.NestedString(workerEnv[0].(map[string]any), "valueFrom", "secretKeyRef", "key"); ref != "DATABASE_URL" {
+		t.Errorf("worker DB env = %v, want secret ref", workerEnv[0])
+	}
+	if v := workerEnv[1].(map[string]any)["value"]; v != "batch" {
+		t.Errorf("worker MODE env = %v", workerEnv[1])
+	}
+
+	first, _ := ms.Render()
+	again, _, _ := Generate(generateConfig(), opts)
+	second, _ := again.Render()
+	if !bytes.Equal(first, second) {
+		t.Error("output is not deterministic")
+	}
+}
+
+func TestGenerate_RequiresImage(t *testing.T) {
+	if _, _, err := Generate(generateConfig(), GenerateOpts{Name: "shop"}); err == nil {
+		t.Fatal("expected error without image")
+	}
+}
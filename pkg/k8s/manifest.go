@@ -85,6 +85,16 @@ func (m *ManifestSet) WriteYAML(dir string, singleFile bool) error {
 }
 
 func (m *ManifestSet) writeMultiDocYAML(path string) error {
+	data, err := m.Render()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// Render returns all objects as one multi-document YAML stream, in the
+// order they would be applied.
+func (m *ManifestSet) Render() ([]byte, error) {
 	var buf bytes.Buffer
 	for i, obj := range m.Objects {
 		if i > 0 {
@@ -92,11 +102,11 @@ func (m *ManifestSet) writeMultiDocYAML(path string) error {
 		}
 		data, err := toYAML(obj)
 		if err != nil {
-			return fmt.Errorf("marshal %s/%s: %w", obj.GetKind(), obj.GetName(), err)
+			return nil, fmt.Errorf("marshal %s/%s: %w", obj.GetKind(), obj.GetName(), err)
 		}
 		buf.Write(data)
 	}
-	return os.WriteFile(path, buf.Bytes(), 0600)
+	return buf.Bytes(), nil
 }
 
 // WriteJSON writes all objects as a JSON array to the given path.
@@ -3,6 +3,7 @@ package k8s
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -134,3 +135,23 @@ func TestToLowerKind(t *testing.T) {
 		}
 	}
 }
+
+func TestManifestSet_Render(t *testing.T) {
+	ms := &ManifestSet{}
+	for _, name := range []string{"cm1", "cm2"} {
+		ms.Add(&unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]any{"name": name},
+		}})
+	}
+
+	data, err := ms.Render()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	docs := strings.Split(string(data), "---\n")
+	if len(docs) != 2 || !strings.Contains(docs[0], `"cm1"`) || !strings.Contains(docs[1], `"cm2"`) {
+		t.Errorf("expected two documents in order, got:\n%s", data)
+	}
+}
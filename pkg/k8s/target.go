@@ -118,6 +118,16 @@ func (t *K8sDeployTarget) Diff(ctx context.Context, artifacts *deploy.DeployArti
 	return deployer.Diff(ctx, artifacts)
 }
 
+// Plan reports whether each artifact object would be created or updated,
+// reading live state without changing it.
+func (t *K8sDeployTarget) Plan(ctx context.Context, artifacts *deploy.DeployArtifacts) ([]deploy.ResourceStatus, error) {
+	client, err := t.ensureClient(artifacts.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	return NewDeployer(client).Plan(ctx, artifacts), nil
+}
+
 func (t *K8sDeployTarget) Logs(ctx context.Context, appName, namespace string, opts deploy.LogOpts) (io.ReadCloser, error) {
 	client, err := t.ensureClient(namespace)
 	if err != nil {
@@ -11,6 +11,12 @@ import (
 	"github.com/GoCodeAlone/workflow/schema"
 )
 
+// ExternalPlugin is implemented by engine plugins that run out of process.
+// Plugin inventories use it to tell external plugins apart from builtin ones.
+type ExternalPlugin interface {
+	IsExternal() bool
+}
+
 // EnginePlugin extends NativePlugin with engine-level contributions:
 // module type factories, step type factories, trigger factories,
 // workflow handlers, capability contracts, and wiring hooks.
@@ -114,6 +120,14 @@ type PipelineTriggerConfigProvider interface {
 	PipelineTriggerConfigWrappers() map[string]TriggerConfigWrapperFunc
 }
 
+// TemplateFuncProvider is optionally implemented by EnginePlugins that add
+// functions to step templates and ${ } expressions. Functions must be pure
+// and return one value, or a value and an error. A name taken by a built-in
+// function or another plugin's function fails the plugin's load.
+type TemplateFuncProvider interface {
+	TemplateFuncs() map[string]any
+}
+
 // ModernizeRulesProvider is optionally implemented by EnginePlugins that
 // wish to supply custom modernize rules for the wfctl modernize command.
 // The rules returned by this interface are intended to be collected by the
@@ -42,6 +42,7 @@ type ExternalPluginAdapter struct {
 	configFragment      []byte
 	pluginDir           string
 	triggerSetupErr     error
+	steps               *stepRouter // routes step executions; switched by UpgradePlugin
 }
 
 type contractDescriptorCache struct {
@@ -162,6 +163,7 @@ func NewExternalPluginAdapter(name string, client *PluginClient, diskManifest *p
 		manifest:        manifest,
 		diskManifest:    diskManifest,
 		triggerSetupErr: triggerSetupErr,
+		steps:           newStepRouter(client.client),
 	}
 	if registry, registryErr := client.client.GetContractRegistry(ctx, &emptypb.Empty{}); registryErr == nil {
 		a.contractRegistry = registry
@@ -363,6 +365,9 @@ func (a *ExternalPluginAdapter) RegisterRoutes(_ *http.ServeMux)         {}
 func (a *ExternalPluginAdapter) OnEnable(_ plugin.PluginContext) error   { return nil }
 func (a *ExternalPluginAdapter) OnDisable(_ plugin.PluginContext) error  { return nil }
 
+// IsExternal implements plugin.ExternalPlugin.
+func (a *ExternalPluginAdapter) IsExternal() bool { return true }
+
 // --- EnginePlugin interface ---
 
 func (a *ExternalPluginAdapter) EngineManifest() *plugin.PluginManifest {
@@ -507,7 +512,11 @@ func (a *ExternalPluginAdapter) ModuleFactories() map[string]plugin.ModuleFactor
 
 func (a *ExternalPluginAdapter) StepFactories() map[string]plugin.StepFactory {
 	ctx := context.Background()
-	resp, err := a.client.client.GetStepTypes(ctx, &emptypb.Empty{})
+	router := a.steps
+	if router == nil {
+		router = newStepRouter(a.client.client)
+	}
+	resp, err := router.client().GetStepTypes(ctx, &emptypb.Empty{})
 	if err != nil || resp == nil {
 		return nil
 	}
@@ -520,19 +529,26 @@ func (a *ExternalPluginAdapter) StepFactories() map[string]plugin.StepFactory {
 			if configErr != nil {
 				return nil, fmt.Errorf("create remote step %s: %w", tn, configErr)
 			}
-			createResp, createErr := a.client.client.CreateStep(ctx, &pb.CreateStepRequest{
-				Type:        tn,
-				Name:        name,
-				Config:      config,
-				TypedConfig: typedConfig,
-			})
-			if createErr != nil {
-				return nil, fmt.Errorf("create remote step %s: %w", tn, createErr)
+			create := func(ctx context.Context, client pb.PluginServiceClient) (string, error) {
+				createResp, createErr := client.CreateStep(ctx, &pb.CreateStepRequest{
+					Type:        tn,
+					Name:        name,
+					Config:      config,
+					TypedConfig: typedConfig,
+				})
+				if createErr != nil {
+					return "", fmt.Errorf("create remote step %s: %w", tn, createErr)
+				}
+				if createResp.Error != "" {
+					return "", fmt.Errorf("create remote step %s: %s", tn, createResp.Error)
+				}
+				return createResp.HandleId, nil
 			}
-			if createResp.Error != "" {
-				return nil, fmt.Errorf("create remote step %s: %s", tn, createResp.Error)
+			step, err := newRoutedRemoteStep(ctx, name, router, cfg, contract, a.contractTypes, create)
+			if err != nil {
+				return nil, err
 			}
-			return NewRemoteStepWithContractTypes(name, createResp.HandleId, a.client.client, cfg, contract, a.contractTypes), nil
+			return step, nil
 		}
 	}
 	return factories
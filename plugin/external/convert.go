@@ -80,6 +80,20 @@ func mapToTypedAnyWithOptions(messageName string, values map[string]any, resolve
 	if err != nil {
 		return nil, err
 	}
+	if values != nil && isWrapperMessage(msg.ProtoReflect().Descriptor()) {
+		// Wrapper types encode as their bare value in protobuf JSON, so
+		// they are decoded from the map's "value" key.
+		if v, ok := values["value"]; ok {
+			raw, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("marshal %s input as JSON: %w", messageName, err)
+			}
+			if err := protojson.Unmarshal(raw, msg); err != nil {
+				return nil, fmt.Errorf("decode %s input as protobuf JSON: %w", messageName, err)
+			}
+		}
+		values = nil
+	}
 	if values != nil {
 		if filterUnknown {
 			values = filterMapToMessageFields(values, msg.ProtoReflect().Descriptor())
@@ -260,6 +274,13 @@ func typedAnyToMap(payload *anypb.Any, messageName string, resolver protoregistr
 	if err != nil {
 		return nil, fmt.Errorf("marshal %s typed payload as JSON: %w", messageName, err)
 	}
+	if isWrapperMessage(msg.ProtoReflect().Descriptor()) {
+		var value any
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, fmt.Errorf("decode %s typed JSON: %w", messageName, err)
+		}
+		return map[string]any{"value": value}, nil
+	}
 	var values map[string]any
 	if err := json.Unmarshal(raw, &values); err != nil {
 		return nil, fmt.Errorf("decode %s typed JSON as map: %w", messageName, err)
@@ -268,6 +289,19 @@ func typedAnyToMap(payload *anypb.Any, messageName string, resolver protoregistr
 	return values, nil
 }
 
+// isWrapperMessage reports whether descriptor is one of the
+// google.protobuf wrapper types, such as StringValue, whose JSON form is the
+// bare wrapped value. They map to and from {"value": <value>}.
+func isWrapperMessage(descriptor protoreflect.MessageDescriptor) bool {
+	switch descriptor.FullName() {
+	case "google.protobuf.StringValue", "google.protobuf.BytesValue", "google.protobuf.BoolValue",
+		"google.protobuf.Int32Value", "google.protobuf.Int64Value", "google.protobuf.UInt32Value",
+		"google.protobuf.UInt64Value", "google.protobuf.FloatValue", "google.protobuf.DoubleValue":
+		return true
+	}
+	return false
+}
+
 func normalizeTypedJSONMap(values map[string]any, descriptor protoreflect.MessageDescriptor) {
 	if values == nil || descriptor == nil {
 		return
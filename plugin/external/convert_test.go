@@ -147,3 +147,20 @@ func TestMapToStruct_NormalizesTypedSlices(t *testing.T) {
 		t.Fatalf("label = %#v, want normalized typed map", labels[0])
 	}
 }
+
+func TestTypedAny_WrapperMessagesMapToValueKey(t *testing.T) {
+	typed, err := mapToTypedAnyKnownFields("google.protobuf.StringValue", map[string]any{"value": "hello", "other": 1}, nil)
+	if err != nil {
+		t.Fatalf("mapToTypedAnyKnownFields: %v", err)
+	}
+	out, err := typedAnyToMap(typed, "google.protobuf.StringValue", nil)
+	if err != nil {
+		t.Fatalf("typedAnyToMap: %v", err)
+	}
+	if out["value"] != "hello" || len(out) != 1 {
+		t.Fatalf("round trip = %v", out)
+	}
+	if _, err := mapToTypedAny("google.protobuf.BoolValue", map[string]any{}, nil); err != nil {
+		t.Fatalf("wrapper without value should decode to the default: %v", err)
+	}
+}
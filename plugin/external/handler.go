@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"sort"
+	"time"
 )
 
 // PluginHandler provides HTTP API endpoints for managing external plugins.
@@ -23,6 +24,10 @@ func (h *PluginHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("POST /api/v1/plugins/external/{name}/load", h.handleLoad)
 	mux.HandleFunc("POST /api/v1/plugins/external/{name}/unload", h.handleUnload)
 	mux.HandleFunc("POST /api/v1/plugins/external/{name}/reload", h.handleReload)
+	mux.HandleFunc("POST /api/v1/plugins/external/{name}/upgrade", h.handleUpgrade)
+	mux.HandleFunc("GET /api/v1/plugins/external/{name}/upgrade", h.handleUpgradeStatus)
+	mux.HandleFunc("GET /api/v1/plugins/external/health", h.handleHealthAll)
+	mux.HandleFunc("GET /api/v1/plugins/external/{name}/health", h.handleHealth)
 }
 
 // apiResponse is the standard JSON response envelope.
@@ -134,3 +139,78 @@ func (h *PluginHandler) handleReload(w http.ResponseWriter, r *http.Request) {
 
 	writeOK(w, map[string]string{"name": name, "action": "reloaded"})
 }
+
+// handleUpgrade upgrades a loaded external plugin to the version staged in
+// its upgrade directory, draining in-flight step executions on the old
+// process. The optional JSON body {"drainTimeout": "30s"} bounds the drain.
+func (h *PluginHandler) handleUpgrade(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "plugin name is required")
+		return
+	}
+
+	var req struct {
+		DrainTimeout string `json:"drainTimeout"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+			return
+		}
+	}
+	var opts UpgradeOptions
+	if req.DrainTimeout != "" {
+		d, err := time.ParseDuration(req.DrainTimeout)
+		if err != nil || d <= 0 {
+			writeError(w, http.StatusBadRequest, "drainTimeout must be a positive duration")
+			return
+		}
+		opts.DrainTimeout = d
+	}
+
+	if !h.manager.IsLoaded(name) {
+		writeError(w, http.StatusNotFound, "plugin "+name+" is not loaded")
+		return
+	}
+	status, err := h.manager.UpgradePlugin(name, opts)
+	if err != nil {
+		if status != nil {
+			writeJSON(w, http.StatusUnprocessableEntity, apiResponse{Status: "error", Data: status, Error: err.Error()})
+			return
+		}
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, apiResponse{Status: "ok", Data: status})
+}
+
+// handleUpgradeStatus returns the status of the latest upgrade of a plugin.
+func (h *PluginHandler) handleUpgradeStatus(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	status, ok := h.manager.UpgradeStatus(name)
+	if !ok {
+		writeError(w, http.StatusNotFound, "no upgrade recorded for plugin "+name)
+		return
+	}
+	writeOK(w, status)
+}
+
+// handleHealthAll returns the health of every loaded plugin.
+func (h *PluginHandler) handleHealthAll(w http.ResponseWriter, _ *http.Request) {
+	writeOK(w, h.manager.AllPluginHealth())
+}
+
+// handleHealth returns the health of a loaded plugin: whether its process is
+// healthy, unhealthy or restarting, its circuit state, last error and call
+// statistics.
+func (h *PluginHandler) handleHealth(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	health, ok := h.manager.PluginHealth(name)
+	if !ok {
+		writeError(w, http.StatusNotFound, "plugin "+name+" is not loaded")
+		return
+	}
+	writeOK(w, health)
+}
@@ -0,0 +1,299 @@
+package external
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"time"
+
+	goplugin "github.com/GoCodeAlone/go-plugin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PluginHealthState is the health of a loaded plugin's process.
+type PluginHealthState string
+
+const (
+	// PluginHealthy means the process answered its last health check.
+	PluginHealthy PluginHealthState = "healthy"
+	// PluginUnhealthy means the process is running but failed its last
+	// health check.
+	PluginUnhealthy PluginHealthState = "unhealthy"
+	// PluginRestarting means the process died and is being restarted.
+	PluginRestarting PluginHealthState = "restarting"
+)
+
+// PluginHealth reports the health and call statistics of a loaded plugin.
+type PluginHealth struct {
+	Plugin      string            `json:"plugin"`
+	State       PluginHealthState `json:"state"`
+	Circuit     string            `json:"circuit"`
+	LastError   string            `json:"lastError,omitempty"`
+	LastErrorAt *time.Time        `json:"lastErrorAt,omitempty"`
+	LastCheckAt *time.Time        `json:"lastCheckAt,omitempty"`
+	Calls       int64             `json:"calls"`
+	Failures    int64             `json:"failures"`
+	Rejected    int64             `json:"rejected"`
+	Restarts    int64             `json:"restarts"`
+	AvgLatency  string            `json:"avgLatency,omitempty"`
+	PID         int               `json:"pid,omitempty"`
+}
+
+// errPluginExited is reported by a health check when the plugin process is
+// gone.
+var errPluginExited = errors.New("plugin process exited")
+
+// pingPlugin returns the health check of a launched plugin process.
+func pingPlugin(client *goplugin.Client, rpcClient goplugin.ClientProtocol) func() error {
+	return func() error {
+		if client.Exited() {
+			return errPluginExited
+		}
+		return rpcClient.Ping()
+	}
+}
+
+// SetResilienceOptions configures call deadlines, circuit breaking and
+// process supervision for plugins loaded afterwards. Call it before loading
+// plugins.
+func (m *ExternalPluginManager) SetResilienceOptions(opts ResilienceOptions) {
+	m.mu.Lock()
+	m.resilience = opts
+	m.mu.Unlock()
+}
+
+// guardFor returns the guard of the named plugin, creating it on first use.
+// Callers must hold m.mu.
+func (m *ExternalPluginManager) guardFor(name string) *pluginGuard {
+	g, ok := m.guards[name]
+	if !ok {
+		g = newPluginGuard(name)
+		g.configure(m.resilience)
+		m.guards[name] = g
+	}
+	return g
+}
+
+// registerLaunch records launch as the process serving the named plugin and
+// starts supervising it. Callers must hold m.opsMu.
+func (m *ExternalPluginManager) registerLaunch(name string, launch *pluginLaunch) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clients[name] = launch.client
+	m.adapters[name] = launch.adapter
+	m.pings[name] = launch.ping
+	g := m.guardFor(name)
+	if launch.adapter.steps != nil {
+		launch.adapter.steps.guard = g
+	}
+	if _, running := m.supervisors[name]; running || g.options().HealthInterval < 0 {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.supervisors[name] = cancel
+	go m.supervise(ctx, name, g)
+}
+
+// forgetPlugin stops supervising the named plugin and drops its state.
+// Callers must hold m.mu.
+func (m *ExternalPluginManager) forgetPlugin(name string) {
+	if cancel, ok := m.supervisors[name]; ok {
+		cancel()
+		delete(m.supervisors, name)
+	}
+	delete(m.clients, name)
+	delete(m.adapters, name)
+	delete(m.pings, name)
+	delete(m.guards, name)
+}
+
+// supervise health-checks the named plugin every HealthInterval, and right
+// after a failed call, restarting its process when it has died.
+func (m *ExternalPluginManager) supervise(ctx context.Context, name string, g *pluginGuard) {
+	ticker := time.NewTicker(g.options().HealthInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-g.failed:
+		}
+		m.checkPlugin(ctx, name, g)
+	}
+}
+
+// checkPlugin pings the named plugin and restarts its process if it exited.
+func (m *ExternalPluginManager) checkPlugin(ctx context.Context, name string, g *pluginGuard) {
+	m.mu.RLock()
+	client, loaded := m.clients[name]
+	ping := m.pings[name]
+	m.mu.RUnlock()
+	if !loaded || ping == nil {
+		return
+	}
+	err := ping()
+	switch {
+	case err == nil:
+		g.setHealth(PluginHealthy, nil)
+	case errors.Is(err, errPluginExited):
+		g.setHealth(PluginRestarting, err)
+		m.logger.Printf("plugin %q process exited; restarting", name)
+		m.restartPlugin(ctx, name, client, g)
+	default:
+		g.setHealth(PluginUnhealthy, err)
+		m.logger.Printf("plugin %q health check failed: %v", name, err)
+	}
+}
+
+// restartPlugin replaces the dead process of the named plugin, retrying
+// with exponential backoff until it succeeds, the plugin is unloaded or
+// replaced, or ctx ends.
+func (m *ExternalPluginManager) restartPlugin(ctx context.Context, name string, dead *goplugin.Client, g *pluginGuard) {
+	opts := g.options()
+	delay := opts.RestartBackoff
+	for attempt := 1; ; attempt++ {
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		done, err := m.replaceExited(name, dead, g)
+		if done {
+			return
+		}
+		g.setHealth(PluginRestarting, err)
+		m.logger.Printf("plugin %q restart attempt %d failed: %v", name, attempt, err)
+		delay = min(delay*2, opts.MaxRestartBackoff)
+	}
+}
+
+// replaceExited starts a new process for the named plugin and routes its
+// steps there, provided dead is still the registered process. It reports
+// whether restarting is finished.
+func (m *ExternalPluginManager) replaceExited(name string, dead *goplugin.Client, g *pluginGuard) (bool, error) {
+	m.opsMu.Lock()
+	defer m.opsMu.Unlock()
+
+	m.mu.RLock()
+	current, loaded := m.clients[name]
+	adapter := m.adapters[name]
+	m.mu.RUnlock()
+	if !loaded || current != dead {
+		return true, nil
+	}
+
+	launch, err := m.startPluginUnlocked(name)
+	if err == nil {
+		err = validatePluginLaunch(name, launch)
+	}
+	if err != nil {
+		return false, err
+	}
+	// As with upgrades, the adapter the engine registered keeps serving and
+	// only its step router moves to the new process.
+	if adapter != nil && adapter.steps != nil {
+		adapter.steps.switchTo(launch.adapter.client.client)
+	}
+	m.mu.Lock()
+	m.clients[name] = launch.client
+	m.pings[name] = launch.ping
+	m.mu.Unlock()
+	dead.Kill()
+	g.restarted()
+	m.logger.Printf("plugin %q restarted", name)
+	return true, nil
+}
+
+// PluginHealth returns the health of the named loaded plugin.
+func (m *ExternalPluginManager) PluginHealth(name string) (*PluginHealth, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if _, loaded := m.clients[name]; !loaded {
+		return nil, false
+	}
+	g, ok := m.guards[name]
+	if !ok {
+		return nil, false
+	}
+	h := g.health()
+	h.PID = pluginPID(m.clients[name])
+	return h, true
+}
+
+// AllPluginHealth returns the health of every loaded plugin, sorted by name.
+func (m *ExternalPluginManager) AllPluginHealth() []*PluginHealth {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]*PluginHealth, 0, len(m.clients))
+	for _, name := range slices.Sorted(maps.Keys(m.clients)) {
+		if g, ok := m.guards[name]; ok {
+			h := g.health()
+			h.PID = pluginPID(m.clients[name])
+			out = append(out, h)
+		}
+	}
+	return out
+}
+
+// pluginPID returns the process ID of a launched plugin, or 0 if unknown.
+func pluginPID(client *goplugin.Client) int {
+	if rc := client.ReattachConfig(); rc != nil {
+		return rc.Pid
+	}
+	return 0
+}
+
+// MetricsCollector returns a Prometheus collector exporting the step call,
+// failure, latency and restart counts of every loaded plugin.
+func (m *ExternalPluginManager) MetricsCollector(namespace, subsystem string) prometheus.Collector {
+	labels := []string{"plugin"}
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, name), help, labels, nil)
+	}
+	return &pluginMetrics{
+		manager:  m,
+		up:       desc("external_plugin_up", "Whether the external plugin process passed its last health check"),
+		calls:    desc("external_plugin_calls_total", "Total number of step calls (create and execute) made to the external plugin"),
+		failures: desc("external_plugin_call_failures_total", "Total number of step calls the external plugin failed to serve"),
+		rejected: desc("external_plugin_calls_rejected_total", "Total number of step calls failed fast by an open circuit"),
+		latency:  desc("external_plugin_call_seconds_total", "Total time spent in step calls made to the external plugin"),
+		restarts: desc("external_plugin_restarts_total", "Total number of times the external plugin process was restarted"),
+	}
+}
+
+type pluginMetrics struct {
+	manager                                          *ExternalPluginManager
+	up, calls, failures, rejected, latency, restarts *prometheus.Desc
+}
+
+// Describe sends no descriptors, making the collector unchecked so that
+// several managers can export the same metric names.
+func (c *pluginMetrics) Describe(chan<- *prometheus.Desc) {}
+
+// Collect implements prometheus.Collector.
+func (c *pluginMetrics) Collect(ch chan<- prometheus.Metric) {
+	c.manager.mu.RLock()
+	guards := make([]*pluginGuard, 0, len(c.manager.guards))
+	for _, g := range c.manager.guards {
+		guards = append(guards, g)
+	}
+	c.manager.mu.RUnlock()
+	for _, g := range guards {
+		up := 0.0
+		g.mu.RLock()
+		if g.state == PluginHealthy {
+			up = 1
+		}
+		g.mu.RUnlock()
+		ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, up, g.name)
+		ch <- prometheus.MustNewConstMetric(c.calls, prometheus.CounterValue, float64(g.calls.Load()), g.name)
+		ch <- prometheus.MustNewConstMetric(c.failures, prometheus.CounterValue, float64(g.failures.Load()), g.name)
+		ch <- prometheus.MustNewConstMetric(c.rejected, prometheus.CounterValue, float64(g.rejected.Load()), g.name)
+		ch <- prometheus.MustNewConstMetric(c.latency, prometheus.CounterValue, time.Duration(g.latencyNanos.Load()).Seconds(), g.name)
+		ch <- prometheus.MustNewConstMetric(c.restarts, prometheus.CounterValue, float64(g.restarts.Load()), g.name)
+	}
+}
@@ -1,6 +1,7 @@
 package external
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -23,6 +24,16 @@ type ExternalPluginManager struct {
 	opsMu   sync.Mutex
 	mu      sync.RWMutex
 	clients map[string]*goplugin.Client
+	// adapters holds the adapter handed out for each loaded plugin; upgrades
+	// switch its step router to the replacement process.
+	adapters map[string]*ExternalPluginAdapter
+	upgrades map[string]*PluginUpgradeStatus
+	// pings health-check each loaded plugin's process; guards protect calls
+	// to it and supervisors stop its health-check loop.
+	pings       map[string]func() error
+	guards      map[string]*pluginGuard
+	supervisors map[string]context.CancelFunc
+	resilience  ResilienceOptions
 
 	callbackServer *CallbackServer
 
@@ -32,6 +43,8 @@ type ExternalPluginManager struct {
 type pluginLaunch struct {
 	client  *goplugin.Client
 	adapter *ExternalPluginAdapter
+	// ping health-checks the process; nil skips health checks.
+	ping func() error
 }
 
 // NewExternalPluginManager creates a new manager that scans the given directory for plugins.
@@ -40,9 +53,14 @@ func NewExternalPluginManager(pluginsDir string, logger *log.Logger) *ExternalPl
 		logger = log.New(os.Stderr, "[external-plugins] ", log.LstdFlags)
 	}
 	return &ExternalPluginManager{
-		pluginsDir: pluginsDir,
-		logger:     logger,
-		clients:    make(map[string]*goplugin.Client),
+		pluginsDir:  pluginsDir,
+		logger:      logger,
+		clients:     make(map[string]*goplugin.Client),
+		adapters:    make(map[string]*ExternalPluginAdapter),
+		upgrades:    make(map[string]*PluginUpgradeStatus),
+		pings:       make(map[string]func() error),
+		guards:      make(map[string]*pluginGuard),
+		supervisors: make(map[string]context.CancelFunc),
 	}
 }
 
@@ -117,19 +135,26 @@ func (m *ExternalPluginManager) LoadPlugin(name string) (*ExternalPluginAdapter,
 		launch.client.Kill()
 		return nil, fmt.Errorf("plugin %q is already loaded", name)
 	}
-	m.clients[name] = launch.client
 	m.mu.Unlock()
+	m.registerLaunch(name, launch)
 	m.logger.Printf("plugin %q loaded successfully", name)
 
 	return launch.adapter, nil
 }
 
 func (m *ExternalPluginManager) startPluginUnlocked(name string) (*pluginLaunch, error) {
+	return m.startPluginFrom(name, m.pluginsDir)
+}
+
+// startPluginFrom starts the plugin whose manifest and binary live in
+// root/<name>. The process always runs in the live plugin directory so the
+// working directory survives promotion of a staged upgrade.
+func (m *ExternalPluginManager) startPluginFrom(name, root string) (*pluginLaunch, error) {
 	if m.startPlugin != nil {
 		return m.startPlugin(name)
 	}
 
-	pluginDir := filepath.Join(m.pluginsDir, name)
+	pluginDir := filepath.Join(root, name)
 	manifestPath := filepath.Join(pluginDir, "plugin.json")
 	// Resolve the binary path to absolute. os/exec.Cmd.Start evaluates a
 	// relative Path *inside* cmd.Dir, so a relative binary path + relative
@@ -154,7 +179,7 @@ func (m *ExternalPluginManager) startPluginUnlocked(name string) (*pluginLaunch,
 	// Verify binary integrity against the lockfile checksum before loading.
 	// A mismatch is logged as a warning and the plugin is skipped, rather than
 	// crashing the engine so other plugins can still be loaded.
-	if err := pluginpkg.VerifyPluginIntegrity(m.pluginsDir, name); err != nil {
+	if err := pluginpkg.VerifyPluginIntegrity(root, name); err != nil {
 		m.logger.Printf("WARNING: skipping plugin %q — integrity check failed: %v", name, err)
 		return nil, fmt.Errorf("integrity check failed for plugin %q: %w", name, err)
 	}
@@ -179,7 +204,7 @@ func (m *ExternalPluginManager) startPluginUnlocked(name string) (*pluginLaunch,
 	// their own directory rather than inheriting the parent's working directory,
 	// which may not be writable (e.g. /app owned by root, process runs as nonroot).
 	cmd := exec.Command(binaryPath) //nolint:gosec // G204: plugin binary path is from trusted data/plugins directory
-	cmd.Dir = pluginDir
+	cmd.Dir = filepath.Join(m.pluginsDir, name)
 	pluginStderr := newPluginStderrForwarder(name, m.logger)
 
 	client := goplugin.NewClient(&goplugin.ClientConfig{
@@ -217,7 +242,7 @@ func (m *ExternalPluginManager) startPluginUnlocked(name string) (*pluginLaunch,
 		return nil, fmt.Errorf("create adapter for plugin %q: %w", name, err)
 	}
 
-	return &pluginLaunch{client: client, adapter: adapter}, nil
+	return &pluginLaunch{client: client, adapter: adapter, ping: pingPlugin(client, rpcClient)}, nil
 }
 
 type pluginStderrForwarder struct {
@@ -259,7 +284,7 @@ func (m *ExternalPluginManager) UnloadPlugin(name string) error {
 		m.mu.Unlock()
 		return fmt.Errorf("plugin %q is not loaded", name)
 	}
-	delete(m.clients, name)
+	m.forgetPlugin(name)
 	m.mu.Unlock()
 
 	m.logger.Printf("unloading plugin %q", name)
@@ -286,9 +311,7 @@ func (m *ExternalPluginManager) ReloadPlugin(name string) (*ExternalPluginAdapte
 		if err := validatePluginLaunch(name, launch); err != nil {
 			return nil, err
 		}
-		m.mu.Lock()
-		m.clients[name] = launch.client
-		m.mu.Unlock()
+		m.registerLaunch(name, launch)
 		m.logger.Printf("plugin %q loaded successfully", name)
 		return launch.adapter, nil
 	}
@@ -303,9 +326,7 @@ func (m *ExternalPluginManager) ReloadPlugin(name string) (*ExternalPluginAdapte
 		return nil, fmt.Errorf("reload plugin %q: %w", name, err)
 	}
 
-	m.mu.Lock()
-	m.clients[name] = launch.client
-	m.mu.Unlock()
+	m.registerLaunch(name, launch)
 	oldClient.Kill()
 	m.logger.Printf("plugin %q reloaded successfully", name)
 	return launch.adapter, nil
@@ -351,7 +372,9 @@ func (m *ExternalPluginManager) Shutdown() {
 
 	m.mu.Lock()
 	clients := m.clients
-	m.clients = make(map[string]*goplugin.Client)
+	for name := range clients {
+		m.forgetPlugin(name)
+	}
 	m.mu.Unlock()
 
 	for name, client := range clients {
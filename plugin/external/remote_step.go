@@ -3,6 +3,7 @@ package external
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"github.com/GoCodeAlone/workflow/module"
 	pb "github.com/GoCodeAlone/workflow/plugin/external/proto"
@@ -19,6 +20,13 @@ type RemoteStep struct {
 	contract *pb.ContractDescriptor
 	types    protoregistry.MessageTypeResolver
 	tmpl     *module.TemplateEngine
+
+	// router, when set, picks the plugin process for each execution. The
+	// step is recreated on a new process the first time it runs there.
+	router  *stepRouter
+	create  func(context.Context, pb.PluginServiceClient) (string, error)
+	mu      sync.Mutex
+	backend *pluginBackend
 }
 
 // NewRemoteStep creates a remote step proxy.
@@ -44,11 +52,78 @@ func NewRemoteStepWithContractTypes(name, handleID string, client pb.PluginServi
 	}
 }
 
+// newRoutedRemoteStep creates the step on the router's current process and
+// returns a proxy that follows the router to replacement processes.
+func newRoutedRemoteStep(ctx context.Context, name string, router *stepRouter, config map[string]any, contract *pb.ContractDescriptor, types protoregistry.MessageTypeResolver, create func(context.Context, pb.PluginServiceClient) (string, error)) (*RemoteStep, error) {
+	b := router.acquire()
+	defer b.release()
+	var handleID string
+	err := router.guard.call(ctx, func(ctx context.Context) error {
+		var createErr error
+		handleID, createErr = create(ctx, b.client)
+		return createErr
+	})
+	if err != nil {
+		return nil, err
+	}
+	s := NewRemoteStepWithContractTypes(name, handleID, b.client, config, contract, types)
+	s.router = router
+	s.create = create
+	s.backend = b
+	return s, nil
+}
+
+// handleOn returns the step's handle on backend b, creating the step there
+// if it was created on a different process.
+func (s *RemoteStep) handleOn(ctx context.Context, b *pluginBackend) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.backend != b {
+		var handleID string
+		err := s.guard().call(ctx, func(ctx context.Context) error {
+			var createErr error
+			handleID, createErr = s.create(ctx, b.client)
+			return createErr
+		})
+		if err != nil {
+			return "", fmt.Errorf("remote step %q: recreate on upgraded plugin: %w", s.name, err)
+		}
+		s.handleID = handleID
+		s.client = b.client
+		s.backend = b
+	}
+	return s.handleID, nil
+}
+
+// guard returns the plugin's call guard, or nil for an unrouted step.
+func (s *RemoteStep) guard() *pluginGuard {
+	if s.router == nil {
+		return nil
+	}
+	return s.router.guard
+}
+
 func (s *RemoteStep) Name() string {
 	return s.name
 }
 
 func (s *RemoteStep) Execute(ctx context.Context, pc *module.PipelineContext) (*module.StepResult, error) {
+	var (
+		client   pb.PluginServiceClient
+		handleID string
+	)
+	if s.router != nil {
+		b := s.router.acquire()
+		defer b.release()
+		var err error
+		if handleID, err = s.handleOn(ctx, b); err != nil {
+			return nil, err
+		}
+		client = b.client
+	} else {
+		client, handleID = s.client, s.handleID
+	}
+
 	// Resolve template expressions in the step config against the current
 	// pipeline context so that dynamic values (e.g. outputs of earlier steps)
 	// are available to the plugin. When no config was provided, skip resolution
@@ -58,7 +133,7 @@ func (s *RemoteStep) Execute(ctx context.Context, pc *module.PipelineContext) (*
 		var err error
 		resolvedConfig, err = s.tmpl.ResolveMap(s.config, pc)
 		if err != nil {
-			return nil, fmt.Errorf("remote step %q (handle %s) config resolve: %w", s.name, s.handleID, err)
+			return nil, fmt.Errorf("remote step %q (handle %s) config resolve: %w", s.name, handleID, err)
 		}
 	}
 
@@ -67,17 +142,22 @@ func (s *RemoteStep) Execute(ctx context.Context, pc *module.PipelineContext) (*
 	for k, v := range pc.StepOutputs {
 		out, err := mapToStruct(v)
 		if err != nil {
-			return nil, fmt.Errorf("remote step %q (handle %s) encode step output %q as Struct: %w", s.name, s.handleID, k, err)
+			return nil, fmt.Errorf("remote step %q (handle %s) encode step output %q as Struct: %w", s.name, handleID, k, err)
 		}
 		stepOutputs[k] = out
 	}
 
-	req, err := s.executeRequest(pc, resolvedConfig, stepOutputs)
+	req, err := s.executeRequest(pc, handleID, resolvedConfig, stepOutputs)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := s.client.ExecuteStep(ctx, req)
+	var resp *pb.ExecuteStepResponse
+	err = s.guard().call(ctx, func(ctx context.Context) error {
+		var callErr error
+		resp, callErr = client.ExecuteStep(ctx, req)
+		return callErr
+	})
 	if err != nil {
 		return nil, fmt.Errorf("remote step execute: %w", err)
 	}
@@ -104,19 +184,19 @@ func (s *RemoteStep) Execute(ctx context.Context, pc *module.PipelineContext) (*
 	}, nil
 }
 
-func (s *RemoteStep) executeRequest(pc *module.PipelineContext, resolvedConfig map[string]any, stepOutputs map[string]*structpb.Struct) (*pb.ExecuteStepRequest, error) {
+func (s *RemoteStep) executeRequest(pc *module.PipelineContext, handleID string, resolvedConfig map[string]any, stepOutputs map[string]*structpb.Struct) (*pb.ExecuteStepRequest, error) {
 	// trigger_data and metadata are always sent as Struct — there's no
 	// typed alternative — so encode them up front.
 	triggerData, err := mapToStruct(pc.TriggerData)
 	if err != nil {
-		return nil, fmt.Errorf("remote step %q (handle %s) encode trigger_data as Struct: %w", s.name, s.handleID, err)
+		return nil, fmt.Errorf("remote step %q (handle %s) encode trigger_data as Struct: %w", s.name, handleID, err)
 	}
 	metadata, err := mapToStruct(remotePluginMetadata(pc.Metadata))
 	if err != nil {
-		return nil, fmt.Errorf("remote step %q (handle %s) encode metadata as Struct: %w", s.name, s.handleID, err)
+		return nil, fmt.Errorf("remote step %q (handle %s) encode metadata as Struct: %w", s.name, handleID, err)
 	}
 	req := &pb.ExecuteStepRequest{
-		HandleId:    s.handleID,
+		HandleId:    handleID,
 		TriggerData: triggerData,
 		StepOutputs: stepOutputs,
 		Metadata:    metadata,
@@ -134,11 +214,11 @@ func (s *RemoteStep) executeRequest(pc *module.PipelineContext, resolvedConfig m
 	if encodeLegacyStruct {
 		current, err := mapToStruct(pc.Current)
 		if err != nil {
-			return nil, fmt.Errorf("remote step %q (handle %s) encode current as Struct: %w", s.name, s.handleID, err)
+			return nil, fmt.Errorf("remote step %q (handle %s) encode current as Struct: %w", s.name, handleID, err)
 		}
 		configStruct, err := mapToStruct(resolvedConfig)
 		if err != nil {
-			return nil, fmt.Errorf("remote step %q (handle %s) encode config as Struct: %w", s.name, s.handleID, err)
+			return nil, fmt.Errorf("remote step %q (handle %s) encode config as Struct: %w", s.name, handleID, err)
 		}
 		req.Current = current
 		req.Config = configStruct
@@ -193,8 +273,11 @@ func remotePluginMetadata(metadata map[string]any) map[string]any {
 
 // Destroy releases the remote step resources.
 func (s *RemoteStep) Destroy() error {
-	resp, err := s.client.DestroyStep(context.Background(), &pb.HandleRequest{
-		HandleId: s.handleID,
+	s.mu.Lock()
+	client, handleID := s.client, s.handleID
+	s.mu.Unlock()
+	resp, err := client.DestroyStep(context.Background(), &pb.HandleRequest{
+		HandleId: handleID,
 	})
 	if err != nil {
 		return fmt.Errorf("remote step destroy: %w", err)
@@ -0,0 +1,275 @@
+package external
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/middleware"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Defaults for ResilienceOptions.
+const (
+	DefaultPluginCallTimeout       = 60 * time.Second
+	DefaultPluginFailureThreshold  = 5
+	DefaultPluginOpenTimeout       = 30 * time.Second
+	DefaultPluginHealthInterval    = 10 * time.Second
+	DefaultPluginRestartBackoff    = time.Second
+	DefaultPluginMaxRestartBackoff = 30 * time.Second
+)
+
+// ResilienceOptions configures how calls to plugin processes are guarded
+// and how the manager supervises the processes it launches. Zero values
+// take the defaults.
+type ResilienceOptions struct {
+	// CallTimeout is the deadline of a step execution whose context has
+	// none, e.g. a step without a timeout setting.
+	CallTimeout time.Duration
+	// FailureThreshold is the number of consecutive failed calls that open
+	// the plugin's circuit breaker.
+	FailureThreshold int
+	// OpenTimeout is how long an open circuit fails calls fast before
+	// letting a probe call through.
+	OpenTimeout time.Duration
+	// HealthInterval is the interval between health-check pings. A negative
+	// value disables supervision.
+	HealthInterval time.Duration
+	// RestartBackoff is the delay before the first restart attempt after the
+	// process dies; it doubles per failed attempt up to MaxRestartBackoff.
+	RestartBackoff    time.Duration
+	MaxRestartBackoff time.Duration
+}
+
+func (o ResilienceOptions) withDefaults() ResilienceOptions {
+	if o.CallTimeout <= 0 {
+		o.CallTimeout = DefaultPluginCallTimeout
+	}
+	if o.FailureThreshold <= 0 {
+		o.FailureThreshold = DefaultPluginFailureThreshold
+	}
+	if o.OpenTimeout <= 0 {
+		o.OpenTimeout = DefaultPluginOpenTimeout
+	}
+	if o.HealthInterval == 0 {
+		o.HealthInterval = DefaultPluginHealthInterval
+	}
+	if o.RestartBackoff <= 0 {
+		o.RestartBackoff = DefaultPluginRestartBackoff
+	}
+	if o.MaxRestartBackoff < o.RestartBackoff {
+		o.MaxRestartBackoff = max(DefaultPluginMaxRestartBackoff, o.RestartBackoff)
+	}
+	return o
+}
+
+// PluginUnavailableError is returned without calling the plugin while its
+// circuit breaker is open.
+type PluginUnavailableError struct {
+	Plugin    string
+	Failures  int
+	LastError string
+	RetryIn   time.Duration
+}
+
+func (e *PluginUnavailableError) Error() string {
+	msg := fmt.Sprintf("external plugin %q is unavailable: circuit open after %d consecutive failures", e.Plugin, e.Failures)
+	if e.LastError != "" {
+		msg += "; last error: " + e.LastError
+	}
+	if e.RetryIn > 0 {
+		msg += fmt.Sprintf("; next probe in %s", e.RetryIn.Round(time.Second))
+	}
+	return msg
+}
+
+// pluginGuard protects the calls made to one plugin, across restarts and
+// upgrades of its process: it applies the call deadline, trips a circuit
+// breaker on repeated failures, and keeps the call statistics and health
+// reported by the management API and metrics.
+type pluginGuard struct {
+	name string
+
+	mu          sync.RWMutex
+	opts        ResilienceOptions
+	breaker     *middleware.CircuitBreaker
+	state       PluginHealthState
+	lastError   string
+	lastErrorAt time.Time
+	lastCheckAt time.Time
+
+	// openedAt is when the circuit last opened, in Unix nanoseconds. It is
+	// atomic because the breaker sets it while holding its own lock.
+	openedAt atomic.Int64
+
+	calls, failures, rejected, restarts atomic.Int64
+	latencyNanos                        atomic.Int64
+
+	// failed is signalled after a call fails so the supervisor checks the
+	// process at once instead of waiting for the next ping.
+	failed chan struct{}
+}
+
+func newPluginGuard(name string) *pluginGuard {
+	return &pluginGuard{name: name, state: PluginHealthy, failed: make(chan struct{}, 1)}
+}
+
+// configure applies opts, resetting the circuit breaker.
+func (g *pluginGuard) configure(opts ResilienceOptions) {
+	opts = opts.withDefaults()
+	breaker := middleware.NewCircuitBreaker(middleware.CircuitBreakerConfig{
+		Name:             g.name,
+		FailureThreshold: opts.FailureThreshold,
+		SuccessThreshold: 1,
+		Timeout:          opts.OpenTimeout,
+	})
+	breaker.OnStateChange(func(_, to middleware.CircuitState) {
+		if to == middleware.CircuitOpen {
+			g.openedAt.Store(time.Now().UnixNano())
+		}
+	})
+	g.mu.Lock()
+	g.opts = opts
+	g.breaker = breaker
+	g.mu.Unlock()
+}
+
+func (g *pluginGuard) options() ResilienceOptions {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.opts
+}
+
+// call runs fn against the plugin; a nil guard just runs fn. It fails fast
+// while the circuit is open, gives ctx the configured deadline when it has
+// none, and records the outcome. Only failures of the plugin itself (unreachable process,
+// deadline exceeded, internal errors) count against the circuit; errors the
+// step reports and cancellations by the caller do not.
+func (g *pluginGuard) call(ctx context.Context, fn func(context.Context) error) error {
+	if g == nil {
+		return fn(ctx)
+	}
+	g.mu.RLock()
+	breaker, opts := g.breaker, g.opts
+	g.mu.RUnlock()
+
+	var err error
+	callErr := breaker.Execute(ctx, func(ctx context.Context) error {
+		if _, ok := ctx.Deadline(); !ok {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, opts.CallTimeout)
+			defer cancel()
+		}
+		start := time.Now()
+		err = fn(ctx)
+		g.calls.Add(1)
+		g.latencyNanos.Add(int64(time.Since(start)))
+		if ctx.Err() == context.DeadlineExceeded && status.Code(err) != codes.OK {
+			err = fmt.Errorf("external plugin %q did not respond before the deadline: %w", g.name, err)
+		}
+		if isPluginFailure(err) && !errors.Is(context.Cause(ctx), context.Canceled) {
+			g.recordFailure(err)
+			return err
+		}
+		return nil
+	})
+	if errors.Is(callErr, middleware.ErrCircuitOpen) {
+		g.rejected.Add(1)
+		return g.unavailable(opts)
+	}
+	return err
+}
+
+// isPluginFailure reports whether err means the plugin process failed to
+// serve the call, as opposed to the step failing.
+func isPluginFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Internal, codes.Unknown, codes.ResourceExhausted:
+		return true
+	}
+	return false
+}
+
+func (g *pluginGuard) recordFailure(err error) {
+	g.failures.Add(1)
+	g.mu.Lock()
+	g.lastError = err.Error()
+	g.lastErrorAt = time.Now()
+	g.mu.Unlock()
+	select {
+	case g.failed <- struct{}{}:
+	default:
+	}
+}
+
+func (g *pluginGuard) unavailable(opts ResilienceOptions) error {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	e := &PluginUnavailableError{Plugin: g.name, Failures: opts.FailureThreshold, LastError: g.lastError}
+	if opened := g.openedAt.Load(); opened != 0 {
+		e.RetryIn = max(time.Until(time.Unix(0, opened).Add(opts.OpenTimeout)), 0)
+	}
+	return e
+}
+
+// setHealth records the outcome of a health check or restart.
+func (g *pluginGuard) setHealth(state PluginHealthState, err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.state = state
+	g.lastCheckAt = time.Now()
+	if err != nil {
+		g.lastError = err.Error()
+		g.lastErrorAt = g.lastCheckAt
+	}
+}
+
+// restarted records a successful restart. The circuit is closed because the
+// failures it counted were against the old process.
+func (g *pluginGuard) restarted() {
+	g.restarts.Add(1)
+	g.mu.Lock()
+	g.state = PluginHealthy
+	g.lastCheckAt = time.Now()
+	breaker := g.breaker
+	g.mu.Unlock()
+	breaker.Reset()
+}
+
+// health returns a snapshot of the plugin's health and call statistics.
+func (g *pluginGuard) health() *PluginHealth {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	h := &PluginHealth{
+		Plugin:    g.name,
+		State:     g.state,
+		Circuit:   g.breaker.State().String(),
+		LastError: g.lastError,
+		Calls:     g.calls.Load(),
+		Failures:  g.failures.Load(),
+		Rejected:  g.rejected.Load(),
+		Restarts:  g.restarts.Load(),
+	}
+	if !g.lastErrorAt.IsZero() {
+		t := g.lastErrorAt.UTC()
+		h.LastErrorAt = &t
+	}
+	if !g.lastCheckAt.IsZero() {
+		t := g.lastCheckAt.UTC()
+		h.LastCheckAt = &t
+	}
+	if h.Calls > 0 {
+		h.AvgLatency = (time.Duration(g.latencyNanos.Load()) / time.Duration(h.Calls)).String()
+	}
+	return h
+}
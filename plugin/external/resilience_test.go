@@ -0,0 +1,228 @@
+package external
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	goplugin "github.com/GoCodeAlone/go-plugin"
+	"github.com/GoCodeAlone/workflow/module"
+	pb "github.com/GoCodeAlone/workflow/plugin/external/proto"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// failingTestClient is a fake plugin process whose step executions fail
+// with err, or hang until the call's deadline when hang is set.
+type failingTestClient struct {
+	upgradeTestClient
+	err  error
+	hang bool
+}
+
+func (c *failingTestClient) ExecuteStep(ctx context.Context, req *pb.ExecuteStepRequest, opts ...grpc.CallOption) (*pb.ExecuteStepResponse, error) {
+	if c.hang {
+		<-ctx.Done()
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.upgradeTestClient.ExecuteStep(ctx, req, opts...)
+}
+
+func newResilienceTestStep(t *testing.T, manager *ExternalPluginManager, client pb.PluginServiceClient) *RemoteStep {
+	t.Helper()
+	manager.startPlugin = func(string) (*pluginLaunch, error) {
+		a, err := NewExternalPluginAdapter("up-plugin", &PluginClient{client: client}, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &pluginLaunch{client: &goplugin.Client{}, adapter: a}, nil
+	}
+	adapter, err := manager.LoadPlugin("up-plugin")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	t.Cleanup(manager.Shutdown)
+	raw, err := adapter.StepFactories()["step.up"]("s1", nil, nil)
+	if err != nil {
+		t.Fatalf("create step: %v", err)
+	}
+	return raw.(*RemoteStep)
+}
+
+func TestRemoteStepCircuitOpensOnPluginFailures(t *testing.T) {
+	manager := NewExternalPluginManager(t.TempDir(), log.Default())
+	manager.SetResilienceOptions(ResilienceOptions{FailureThreshold: 2, OpenTimeout: time.Hour, HealthInterval: -1})
+	client := &failingTestClient{
+		upgradeTestClient: upgradeTestClient{adapterTestPluginServiceClient: adapterTestPluginServiceClient{stepTypes: []string{"step.up"}}},
+		err:               status.Error(codes.Unavailable, "connection refused"),
+	}
+	step := newResilienceTestStep(t, manager, client)
+	pc := module.NewPipelineContext(nil, nil)
+
+	for i := range 2 {
+		if _, err := step.Execute(context.Background(), pc); status.Code(err) != codes.Unavailable {
+			t.Fatalf("call %d: error = %v, want Unavailable", i, err)
+		}
+	}
+	_, err := step.Execute(context.Background(), pc)
+	var unavailable *PluginUnavailableError
+	if !errors.As(err, &unavailable) || !strings.Contains(err.Error(), "connection refused") || unavailable.RetryIn <= 0 {
+		t.Fatalf("expected fail-fast PluginUnavailableError, got %v", err)
+	}
+
+	health, ok := manager.PluginHealth("up-plugin")
+	if !ok || health.Circuit != "open" || health.Calls != 3 || health.Failures != 2 || health.Rejected != 1 {
+		t.Fatalf("health = %+v", health)
+	}
+}
+
+func TestRemoteStepStepErrorsDoNotTripCircuit(t *testing.T) {
+	manager := NewExternalPluginManager(t.TempDir(), log.Default())
+	manager.SetResilienceOptions(ResilienceOptions{FailureThreshold: 1, HealthInterval: -1})
+	client := &failingTestClient{
+		upgradeTestClient: upgradeTestClient{adapterTestPluginServiceClient: adapterTestPluginServiceClient{stepTypes: []string{"step.up"}}},
+		err:               status.Error(codes.InvalidArgument, "bad input"),
+	}
+	step := newResilienceTestStep(t, manager, client)
+	for range 3 {
+		if _, err := step.Execute(context.Background(), module.NewPipelineContext(nil, nil)); status.Code(err) != codes.InvalidArgument {
+			t.Fatalf("error = %v, want InvalidArgument", err)
+		}
+	}
+	if health, _ := manager.PluginHealth("up-plugin"); health.Circuit != "closed" || health.Failures != 0 {
+		t.Fatalf("health = %+v", health)
+	}
+}
+
+func TestRemoteStepDefaultCallDeadline(t *testing.T) {
+	manager := NewExternalPluginManager(t.TempDir(), log.Default())
+	manager.SetResilienceOptions(ResilienceOptions{CallTimeout: 20 * time.Millisecond, HealthInterval: -1})
+	client := &failingTestClient{
+		upgradeTestClient: upgradeTestClient{adapterTestPluginServiceClient: adapterTestPluginServiceClient{stepTypes: []string{"step.up"}}},
+		hang:              true,
+	}
+	step := newResilienceTestStep(t, manager, client)
+
+	start := time.Now()
+	_, err := step.Execute(context.Background(), module.NewPipelineContext(nil, nil))
+	if err == nil || !strings.Contains(err.Error(), "did not respond before the deadline") {
+		t.Fatalf("error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("call took %s", elapsed)
+	}
+
+	// A deadline set by the caller, e.g. a step timeout, takes precedence.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if _, err := step.Execute(ctx, module.NewPipelineContext(nil, nil)); status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("caller deadline: error = %v", err)
+	}
+}
+
+func TestManagerRestartsExitedPlugin(t *testing.T) {
+	manager := NewExternalPluginManager(t.TempDir(), log.Default())
+	manager.SetResilienceOptions(ResilienceOptions{HealthInterval: 5 * time.Millisecond, RestartBackoff: 5 * time.Millisecond})
+
+	var launches atomic.Int32
+	var exited atomic.Bool
+	manager.startPlugin = func(string) (*pluginLaunch, error) {
+		n := launches.Add(1)
+		if n == 2 {
+			return nil, errors.New("binary busy")
+		}
+		proc := &upgradeTestClient{
+			adapterTestPluginServiceClient: adapterTestPluginServiceClient{stepTypes: []string{"step.up"}},
+			process:                        map[int32]string{1: "first", 3: "second"}[n],
+		}
+		ping := func() error { return nil }
+		if n == 1 {
+			ping = func() error {
+				if exited.Load() {
+					return errPluginExited
+				}
+				return nil
+			}
+		}
+		return &pluginLaunch{client: &goplugin.Client{}, adapter: newUpgradeTestAdapter(t, proc, "1.0.0"), ping: ping}, nil
+	}
+	adapter, err := manager.LoadPlugin("up-plugin")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	t.Cleanup(manager.Shutdown)
+	raw, err := adapter.StepFactories()["step.up"]("s1", nil, nil)
+	if err != nil {
+		t.Fatalf("create step: %v", err)
+	}
+	step := raw.(*RemoteStep)
+
+	exited.Store(true)
+	var health *PluginHealth
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if health, _ = manager.PluginHealth("up-plugin"); health.Restarts == 1 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if health.Restarts != 1 || health.State != PluginHealthy || !strings.Contains(health.LastError, "binary busy") {
+		t.Fatalf("health after restart = %+v", health)
+	}
+	if launches.Load() != 3 {
+		t.Fatalf("launches = %d, want 3 (one failed restart attempt)", launches.Load())
+	}
+
+	res, err := step.Execute(context.Background(), module.NewPipelineContext(nil, nil))
+	if err != nil || res.Output["process"] != "second" {
+		t.Fatalf("execute after restart: %v, %v", res, err)
+	}
+
+	mux := http.NewServeMux()
+	NewPluginHandler(manager).RegisterRoutes(mux)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/plugins/external/health", nil))
+	var resp struct {
+		Data []PluginHealth `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || rec.Code != http.StatusOK || len(resp.Data) != 1 || resp.Data[0].Restarts != 1 {
+		t.Fatalf("GET health: %d %s", rec.Code, rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/plugins/external/missing/health", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("GET health of unloaded plugin: %d", rec.Code)
+	}
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(manager.MetricsCollector("workflow", ""))
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := make(map[string]float64)
+	for _, f := range families {
+		for _, m := range f.GetMetric() {
+			if m.GetCounter() != nil {
+				values[f.GetName()] = m.GetCounter().GetValue()
+			} else {
+				values[f.GetName()] = m.GetGauge().GetValue()
+			}
+		}
+	}
+	if values["workflow_external_plugin_restarts_total"] != 1 || values["workflow_external_plugin_calls_total"] != 3 || values["workflow_external_plugin_up"] != 1 {
+		t.Fatalf("metrics = %v", values)
+	}
+}
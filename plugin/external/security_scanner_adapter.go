@@ -60,6 +60,7 @@ func (p *remoteSecurityScannerProvider) ScanContainer(ctx context.Context, opts
 		"severity_threshold": opts.SeverityThreshold,
 		"ignore_unfixed":     opts.IgnoreUnfixed,
 		"output_format":      opts.OutputFormat,
+		"sbom_path":          opts.SBOMPath,
 	}
 	result, err := p.module.InvokeService("ScanContainer", args)
 	if err != nil {
@@ -74,6 +75,7 @@ func (p *remoteSecurityScannerProvider) ScanDeps(ctx context.Context, opts modul
 		"source_path":      opts.SourcePath,
 		"fail_on_severity": opts.FailOnSeverity,
 		"output_format":    opts.OutputFormat,
+		"sbom_path":        opts.SBOMPath,
 	}
 	result, err := p.module.InvokeService("ScanDeps", args)
 	if err != nil {
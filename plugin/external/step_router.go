@@ -0,0 +1,105 @@
+package external
+
+import (
+	"sync"
+	"time"
+
+	pb "github.com/GoCodeAlone/workflow/plugin/external/proto"
+)
+
+// stepRouter points a plugin's step executions at the process currently
+// serving them. An upgrade switches the router to a replacement process;
+// executions already running keep the backend they acquired, so the old
+// process can be drained before it is terminated.
+type stepRouter struct {
+	mu      sync.RWMutex
+	current *pluginBackend
+
+	// guard, when set, applies the plugin's call deadline and circuit
+	// breaker to executions. It is shared by every router of the plugin.
+	guard *pluginGuard
+}
+
+// pluginBackend is one plugin process as seen by the step router. It counts
+// the step executions in flight against it.
+type pluginBackend struct {
+	client pb.PluginServiceClient
+
+	mu       sync.Mutex
+	inFlight int
+	retired  bool
+	idle     chan struct{} // closed once retired with nothing in flight
+}
+
+func newStepRouter(client pb.PluginServiceClient) *stepRouter {
+	return &stepRouter{current: newPluginBackend(client)}
+}
+
+func newPluginBackend(client pb.PluginServiceClient) *pluginBackend {
+	return &pluginBackend{client: client, idle: make(chan struct{})}
+}
+
+// client returns the client of the process currently serving new executions.
+func (r *stepRouter) client() pb.PluginServiceClient {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.current.client
+}
+
+// acquire returns the current backend with one more execution counted
+// against it. Callers must call release when the execution finishes.
+func (r *stepRouter) acquire() *pluginBackend {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	b := r.current
+	b.mu.Lock()
+	b.inFlight++
+	b.mu.Unlock()
+	return b
+}
+
+// switchTo routes all later executions to client and retires the previous
+// backend, which is returned so the caller can wait for it to drain.
+func (r *stepRouter) switchTo(client pb.PluginServiceClient) *pluginBackend {
+	r.mu.Lock()
+	old := r.current
+	r.current = newPluginBackend(client)
+	r.mu.Unlock()
+
+	old.mu.Lock()
+	old.retired = true
+	if old.inFlight == 0 {
+		close(old.idle)
+	}
+	old.mu.Unlock()
+	return old
+}
+
+func (b *pluginBackend) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.inFlight--
+	if b.retired && b.inFlight == 0 {
+		close(b.idle)
+	}
+}
+
+// InFlight reports how many executions are still running on the backend.
+func (b *pluginBackend) InFlight() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.inFlight
+}
+
+// wait blocks until a retired backend has no executions in flight or the
+// timeout passes. It reports whether the backend drained.
+func (b *pluginBackend) wait(timeout time.Duration) bool {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-b.idle:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
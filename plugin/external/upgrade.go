@@ -0,0 +1,272 @@
+package external
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	goplugin "github.com/GoCodeAlone/go-plugin"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// UpgradeStagingDir is the directory under the plugins directory where a new
+// plugin version is installed alongside the running one before an upgrade:
+// <pluginsDir>/.upgrades/<name>/plugin.json and <pluginsDir>/.upgrades/<name>/<name>.
+const UpgradeStagingDir = ".upgrades"
+
+// DefaultUpgradeDrainTimeout bounds how long an upgrade waits for in-flight
+// step executions on the old process before terminating it.
+const DefaultUpgradeDrainTimeout = 30 * time.Second
+
+// UpgradeState is the phase of a plugin upgrade.
+type UpgradeState string
+
+const (
+	// UpgradeChecking means the staged version is being started and health-checked.
+	UpgradeChecking UpgradeState = "checking"
+	// UpgradeDraining means new executions go to the new version while the
+	// old process finishes its in-flight executions.
+	UpgradeDraining UpgradeState = "draining"
+	// UpgradeSwitched means the new version is serving and the old process
+	// has been terminated.
+	UpgradeSwitched UpgradeState = "switched"
+	// UpgradeRolledBack means the staged version failed its health check and
+	// the old version kept serving untouched.
+	UpgradeRolledBack UpgradeState = "rolled_back"
+)
+
+// UpgradeOptions configures UpgradePlugin.
+type UpgradeOptions struct {
+	// DrainTimeout bounds the wait for in-flight executions on the old
+	// process. Zero means DefaultUpgradeDrainTimeout.
+	DrainTimeout time.Duration
+}
+
+// PluginUpgradeStatus reports the progress of the latest upgrade of a plugin.
+type PluginUpgradeStatus struct {
+	Plugin       string       `json:"plugin"`
+	State        UpgradeState `json:"state"`
+	FromVersion  string       `json:"fromVersion,omitempty"`
+	ToVersion    string       `json:"toVersion,omitempty"`
+	InFlight     int          `json:"inFlight"`
+	DrainTimeout string       `json:"drainTimeout,omitempty"`
+	Warnings     []string     `json:"warnings,omitempty"`
+	Error        string       `json:"error,omitempty"`
+	StartedAt    time.Time    `json:"startedAt"`
+	FinishedAt   *time.Time   `json:"finishedAt,omitempty"`
+
+	draining *pluginBackend
+}
+
+// snapshot returns a copy of the status safe to hand to callers.
+func (s *PluginUpgradeStatus) snapshot() *PluginUpgradeStatus {
+	out := *s
+	out.Warnings = append([]string(nil), s.Warnings...)
+	if s.draining != nil {
+		out.InFlight = s.draining.InFlight()
+	}
+	out.draining = nil
+	return &out
+}
+
+// StagedUpgradeDir returns the directory where the next version of the named
+// plugin must be installed before calling UpgradePlugin.
+func (m *ExternalPluginManager) StagedUpgradeDir(name string) string {
+	return filepath.Join(m.pluginsDir, UpgradeStagingDir, name)
+}
+
+// UpgradePlugin replaces a loaded plugin with the version staged in
+// StagedUpgradeDir without interrupting step executions. The staged version
+// is started alongside the old one and health-checked; if that fails it is
+// stopped and the old version keeps serving untouched. Otherwise new step
+// executions are routed to the new process at once, and in the background
+// the old process is drained for up to the drain timeout, terminated, and
+// its binary replaced by the staged one.
+//
+// The returned status is in the draining state on success and in the
+// rolled_back state when the health check failed; UpgradeStatus reports
+// later progress.
+func (m *ExternalPluginManager) UpgradePlugin(name string, opts UpgradeOptions) (*PluginUpgradeStatus, error) {
+	m.opsMu.Lock()
+	defer m.opsMu.Unlock()
+
+	timeout := opts.DrainTimeout
+	if timeout <= 0 {
+		timeout = DefaultUpgradeDrainTimeout
+	}
+
+	m.mu.RLock()
+	oldClient, loaded := m.clients[name]
+	oldAdapter := m.adapters[name]
+	prev := m.upgrades[name]
+	upgrading := prev != nil && prev.State == UpgradeDraining
+	m.mu.RUnlock()
+	if !loaded {
+		return nil, fmt.Errorf("plugin %q is not loaded", name)
+	}
+	if oldAdapter == nil || oldAdapter.steps == nil {
+		return nil, fmt.Errorf("plugin %q cannot be upgraded in place: no step router", name)
+	}
+	if upgrading {
+		return nil, fmt.Errorf("plugin %q is already being upgraded", name)
+	}
+
+	status := &PluginUpgradeStatus{
+		Plugin:       name,
+		State:        UpgradeChecking,
+		FromVersion:  oldAdapter.Version(),
+		DrainTimeout: timeout.String(),
+		StartedAt:    time.Now().UTC(),
+	}
+	m.setUpgradeStatus(status)
+	m.logger.Printf("upgrading plugin %q from version %s", name, status.FromVersion)
+
+	launch, err := m.startPluginFrom(name, filepath.Join(m.pluginsDir, UpgradeStagingDir))
+	if err == nil {
+		err = validatePluginLaunch(name, launch)
+	}
+	if err == nil {
+		var warnings []string
+		warnings, err = checkUpgradeCandidate(oldAdapter, launch.adapter)
+		m.mu.Lock()
+		status.ToVersion = launch.adapter.Version()
+		status.Warnings = warnings
+		m.mu.Unlock()
+	}
+	if err != nil {
+		if launch != nil && launch.client != nil {
+			launch.client.Kill()
+		}
+		m.logger.Printf("plugin %q upgrade rolled back; keeping version %s active: %v", name, status.FromVersion, err)
+		m.finishUpgradeStatus(status, UpgradeRolledBack, err)
+		return status.snapshot(), fmt.Errorf("upgrade plugin %q: %w", name, err)
+	}
+
+	// The adapter the engine registered keeps serving; only its step router
+	// moves to the new process.
+	old := oldAdapter.steps.switchTo(launch.adapter.client.client)
+	m.mu.Lock()
+	m.clients[name] = launch.client
+	m.pings[name] = launch.ping
+	status.State = UpgradeDraining
+	status.draining = old
+	result := status.snapshot()
+	m.mu.Unlock()
+	m.logger.Printf("plugin %q switched to version %s; draining %d in-flight execution(s)", name, status.ToVersion, result.InFlight)
+
+	go m.completeUpgrade(name, oldClient, old, status, timeout)
+	return result, nil
+}
+
+// UpgradeStatus returns the status of the latest upgrade of the named plugin.
+func (m *ExternalPluginManager) UpgradeStatus(name string) (*PluginUpgradeStatus, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	status, ok := m.upgrades[name]
+	if !ok {
+		return nil, false
+	}
+	return status.snapshot(), true
+}
+
+// completeUpgrade drains and terminates the old process, then promotes the
+// staged files over the old ones.
+func (m *ExternalPluginManager) completeUpgrade(name string, oldClient *goplugin.Client, old *pluginBackend, status *PluginUpgradeStatus, timeout time.Duration) {
+	if !old.wait(timeout) {
+		msg := fmt.Sprintf("drain timed out after %s with %d execution(s) still in flight on the old process", timeout, old.InFlight())
+		m.logger.Printf("plugin %q upgrade: %s", name, msg)
+		m.addUpgradeWarning(status, msg)
+	}
+	oldClient.Kill()
+	if err := m.promoteStagedPlugin(name); err != nil {
+		m.logger.Printf("plugin %q upgrade: %v", name, err)
+		m.addUpgradeWarning(status, err.Error())
+	}
+	m.finishUpgradeStatus(status, UpgradeSwitched, nil)
+	m.logger.Printf("plugin %q upgrade complete", name)
+}
+
+// promoteStagedPlugin moves the staged manifest and binary into the live
+// plugin directory, replacing the old binary, and removes the staging
+// directory. Renaming over a running binary is safe; the new process keeps
+// its open file.
+func (m *ExternalPluginManager) promoteStagedPlugin(name string) error {
+	staged := m.StagedUpgradeDir(name)
+	if _, err := os.Stat(staged); os.IsNotExist(err) {
+		return nil
+	}
+	live := filepath.Join(m.pluginsDir, name)
+	for _, file := range []string{name, "plugin.json"} {
+		if err := os.Rename(filepath.Join(staged, file), filepath.Join(live, file)); err != nil {
+			return fmt.Errorf("promote staged %s: %w", file, err)
+		}
+	}
+	if err := os.RemoveAll(staged); err != nil {
+		return fmt.Errorf("remove staging directory: %w", err)
+	}
+	return nil
+}
+
+// checkUpgradeCandidate compares the step types the old and new versions
+// serve. Steps whose type the new version dropped would fail on their next
+// execution, so they are reported as warnings rather than blocking the
+// upgrade.
+func checkUpgradeCandidate(old, candidate *ExternalPluginAdapter) ([]string, error) {
+	ctx := context.Background()
+	newTypes, err := candidate.client.client.GetStepTypes(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, fmt.Errorf("health check: get step types: %w", err)
+	}
+	oldTypes, err := old.steps.client().GetStepTypes(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, fmt.Errorf("health check: get step types from running version: %w", err)
+	}
+
+	served := make(map[string]bool, len(newTypes.GetTypes()))
+	for _, t := range newTypes.GetTypes() {
+		served[t] = true
+	}
+	var removed []string
+	for _, t := range oldTypes.GetTypes() {
+		if !served[t] {
+			removed = append(removed, t)
+		}
+	}
+	sort.Strings(removed)
+
+	var warnings []string
+	for _, t := range removed {
+		warnings = append(warnings, fmt.Sprintf("step type %q is not served by the new version; existing steps of this type will fail", t))
+	}
+	if modTypes, err := old.steps.client().GetModuleTypes(ctx, &emptypb.Empty{}); err == nil && len(modTypes.GetTypes()) > 0 {
+		warnings = append(warnings, "module instances created by the old version are not migrated and stop when the old process is terminated")
+	}
+	return warnings, nil
+}
+
+func (m *ExternalPluginManager) setUpgradeStatus(status *PluginUpgradeStatus) {
+	m.mu.Lock()
+	m.upgrades[status.Plugin] = status
+	m.mu.Unlock()
+}
+
+func (m *ExternalPluginManager) addUpgradeWarning(status *PluginUpgradeStatus, warning string) {
+	m.mu.Lock()
+	status.Warnings = append(status.Warnings, warning)
+	m.mu.Unlock()
+}
+
+func (m *ExternalPluginManager) finishUpgradeStatus(status *PluginUpgradeStatus, state UpgradeState, err error) {
+	now := time.Now().UTC()
+	m.mu.Lock()
+	status.State = state
+	status.FinishedAt = &now
+	if err != nil {
+		status.Error = err.Error()
+	}
+	status.draining = nil
+	m.mu.Unlock()
+}
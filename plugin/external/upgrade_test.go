@@ -0,0 +1,221 @@
+package external
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	goplugin "github.com/GoCodeAlone/go-plugin"
+	"github.com/GoCodeAlone/workflow/module"
+	pb "github.com/GoCodeAlone/workflow/plugin/external/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// upgradeTestClient is one fake plugin process. ExecuteStep blocks on block
+// when it is set, and reports which process served the call.
+type upgradeTestClient struct {
+	adapterTestPluginServiceClient
+	process string
+	block   chan struct{}
+	started chan struct{}
+
+	mu      sync.Mutex
+	creates int
+}
+
+func (c *upgradeTestClient) CreateStep(_ context.Context, _ *pb.CreateStepRequest, _ ...grpc.CallOption) (*pb.HandleResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.creates++
+	return &pb.HandleResponse{HandleId: c.process + "-handle"}, nil
+}
+
+func (c *upgradeTestClient) ExecuteStep(_ context.Context, req *pb.ExecuteStepRequest, _ ...grpc.CallOption) (*pb.ExecuteStepResponse, error) {
+	if c.started != nil {
+		c.started <- struct{}{}
+	}
+	if c.block != nil {
+		<-c.block
+	}
+	out, _ := structpb.NewStruct(map[string]any{"process": c.process, "handle": req.HandleId})
+	return &pb.ExecuteStepResponse{Output: out}, nil
+}
+
+func newUpgradeTestAdapter(t *testing.T, client *upgradeTestClient, version string) *ExternalPluginAdapter {
+	t.Helper()
+	client.manifest = &pb.Manifest{Name: "up-plugin", Version: version}
+	a, err := NewExternalPluginAdapter("up-plugin", &PluginClient{client: client}, nil)
+	if err != nil {
+		t.Fatalf("NewExternalPluginAdapter: %v", err)
+	}
+	return a
+}
+
+func waitForUpgradeState(t *testing.T, m *ExternalPluginManager, state UpgradeState) *PluginUpgradeStatus {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if status, ok := m.UpgradeStatus("up-plugin"); ok && status.State == state {
+			return status
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	status, _ := m.UpgradeStatus("up-plugin")
+	t.Fatalf("upgrade did not reach %s: %+v", state, status)
+	return nil
+}
+
+func TestUpgradePluginDrainsInFlightAndRoutesNewExecutions(t *testing.T) {
+	oldProc := &upgradeTestClient{
+		adapterTestPluginServiceClient: adapterTestPluginServiceClient{stepTypes: []string{"step.up", "step.legacy"}},
+		process:                        "old",
+		block:                          make(chan struct{}),
+		started:                        make(chan struct{}, 1),
+	}
+	newProc := &upgradeTestClient{
+		adapterTestPluginServiceClient: adapterTestPluginServiceClient{stepTypes: []string{"step.up"}},
+		process:                        "new",
+	}
+	manager := NewExternalPluginManager(t.TempDir(), log.Default())
+	manager.startPlugin = func(string) (*pluginLaunch, error) {
+		return &pluginLaunch{client: &goplugin.Client{}, adapter: newUpgradeTestAdapter(t, oldProc, "1.0.0")}, nil
+	}
+	active, err := manager.LoadPlugin("up-plugin")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	raw, err := active.StepFactories()["step.up"]("s1", nil, nil)
+	if err != nil {
+		t.Fatalf("create step: %v", err)
+	}
+	step := raw.(*RemoteStep)
+
+	inFlight := make(chan *module.StepResult)
+	go func() {
+		res, _ := step.Execute(context.Background(), module.NewPipelineContext(nil, nil))
+		inFlight <- res
+	}()
+	<-oldProc.started
+
+	manager.startPlugin = func(string) (*pluginLaunch, error) {
+		return &pluginLaunch{client: &goplugin.Client{}, adapter: newUpgradeTestAdapter(t, newProc, "2.0.0")}, nil
+	}
+	status, err := manager.UpgradePlugin("up-plugin", UpgradeOptions{DrainTimeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("upgrade: %v", err)
+	}
+	if status.State != UpgradeDraining || status.InFlight != 1 || status.ToVersion != "2.0.0" {
+		t.Fatalf("status after switch = %+v", status)
+	}
+	if len(status.Warnings) != 1 || !strings.Contains(status.Warnings[0], `"step.legacy"`) {
+		t.Fatalf("expected a removed step type warning, got %v", status.Warnings)
+	}
+
+	res, err := step.Execute(context.Background(), module.NewPipelineContext(nil, nil))
+	if err != nil {
+		t.Fatalf("execute after switch: %v", err)
+	}
+	if res.Output["process"] != "new" || res.Output["handle"] != "new-handle" {
+		t.Fatalf("new execution should run on the new process: %v", res.Output)
+	}
+	if _, err := step.Execute(context.Background(), module.NewPipelineContext(nil, nil)); err != nil || newProc.creates != 1 {
+		t.Fatalf("step should be recreated on the new process once, got %d creates (err %v)", newProc.creates, err)
+	}
+	if current, _ := manager.UpgradeStatus("up-plugin"); current.State != UpgradeDraining {
+		t.Fatal("upgrade should stay draining while an execution is in flight")
+	}
+
+	close(oldProc.block)
+	if res := <-inFlight; res.Output["process"] != "old" {
+		t.Fatalf("in-flight execution should finish on the old process: %v", res.Output)
+	}
+	final := waitForUpgradeState(t, manager, UpgradeSwitched)
+	if final.InFlight != 0 || final.FinishedAt == nil {
+		t.Fatalf("final status = %+v", final)
+	}
+}
+
+func TestUpgradePluginHealthCheckFailureRollsBack(t *testing.T) {
+	oldProc := &upgradeTestClient{
+		adapterTestPluginServiceClient: adapterTestPluginServiceClient{stepTypes: []string{"step.up"}},
+		process:                        "old",
+	}
+	manager := NewExternalPluginManager(t.TempDir(), log.Default())
+	oldClient := &goplugin.Client{}
+	manager.startPlugin = func(string) (*pluginLaunch, error) {
+		return &pluginLaunch{client: oldClient, adapter: newUpgradeTestAdapter(t, oldProc, "1.0.0")}, nil
+	}
+	active, err := manager.LoadPlugin("up-plugin")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	raw, err := active.StepFactories()["step.up"]("s1", nil, nil)
+	if err != nil {
+		t.Fatalf("create step: %v", err)
+	}
+
+	manager.startPlugin = func(string) (*pluginLaunch, error) {
+		return nil, errors.New("handshake failed")
+	}
+	status, err := manager.UpgradePlugin("up-plugin", UpgradeOptions{})
+	if err == nil {
+		t.Fatal("expected upgrade failure")
+	}
+	if status.State != UpgradeRolledBack || !strings.Contains(status.Error, "handshake failed") {
+		t.Fatalf("status = %+v", status)
+	}
+	if manager.clients["up-plugin"] != oldClient {
+		t.Fatal("failed upgrade replaced the active plugin client")
+	}
+	res, err := raw.(*RemoteStep).Execute(context.Background(), module.NewPipelineContext(nil, nil))
+	if err != nil || res.Output["process"] != "old" {
+		t.Fatalf("old version should keep serving: %v, %v", res, err)
+	}
+}
+
+func TestPluginHandlerUpgrade(t *testing.T) {
+	manager := NewExternalPluginManager(t.TempDir(), log.Default())
+	manager.startPlugin = func(string) (*pluginLaunch, error) {
+		proc := &upgradeTestClient{process: "p"}
+		return &pluginLaunch{client: &goplugin.Client{}, adapter: newUpgradeTestAdapter(t, proc, "1.0.0")}, nil
+	}
+	mux := http.NewServeMux()
+	NewPluginHandler(manager).RegisterRoutes(mux)
+	do := func(method, path, body string) (int, apiResponse) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		var resp apiResponse
+		_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec.Code, resp
+	}
+
+	if code, _ := do("POST", "/api/v1/plugins/external/up-plugin/upgrade", ""); code != http.StatusNotFound {
+		t.Fatalf("upgrade of an unloaded plugin: status %d", code)
+	}
+	if _, err := manager.LoadPlugin("up-plugin"); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if code, _ := do("POST", "/api/v1/plugins/external/up-plugin/upgrade", `{"drainTimeout":"soon"}`); code != http.StatusBadRequest {
+		t.Fatalf("invalid drainTimeout: status %d", code)
+	}
+	code, resp := do("POST", "/api/v1/plugins/external/up-plugin/upgrade", `{"drainTimeout":"1s"}`)
+	if code != http.StatusAccepted || resp.Status != "ok" {
+		t.Fatalf("upgrade: status %d, %+v", code, resp)
+	}
+	waitForUpgradeState(t, manager, UpgradeSwitched)
+
+	code, resp = do("GET", "/api/v1/plugins/external/up-plugin/upgrade", "")
+	data, _ := resp.Data.(map[string]any)
+	if code != http.StatusOK || data["state"] != string(UpgradeSwitched) || data["drainTimeout"] != "1s" {
+		t.Fatalf("upgrade status: %d, %+v", code, resp)
+	}
+}
@@ -149,16 +149,9 @@ func (i *PluginInstaller) InstallFromBundle(bundlePath string) error {
 		// Attempt to load component via dynamic loader
 		var comp *dynamic.DynamicComponent
 		if i.loader != nil {
-			sourceFiles, _ := filepath.Glob(filepath.Join(destDir, "*.go"))
-			for _, sf := range sourceFiles {
-				base := filepath.Base(sf)
-				if strings.HasSuffix(base, "_test.go") {
-					continue
-				}
-				c, loadErr := i.loader.LoadFromFile(manifest.Name, sf)
-				if loadErr == nil {
+			if files, readErr := dynamic.ReadComponentDir(destDir); readErr == nil && len(files) > 0 {
+				if c, loadErr := i.loader.LoadFromFiles(manifest.Name, files); loadErr == nil {
 					comp = c
-					break
 				}
 			}
 		}
@@ -126,7 +126,8 @@ func (r *LocalRegistry) CheckDependencies(manifest *PluginManifest) error {
 }
 
 // ScanDirectory scans a directory for plugin subdirectories.
-// Each subdirectory should contain a plugin.json manifest and a .go source file.
+// Each subdirectory should contain a plugin.json manifest and the .go source
+// files of the plugin's component.
 func (r *LocalRegistry) ScanDirectory(dir string, loader *dynamic.Loader) ([]*PluginEntry, error) {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
@@ -151,21 +152,19 @@ func (r *LocalRegistry) ScanDirectory(dir string, loader *dynamic.Loader) ([]*Pl
 			return loaded, fmt.Errorf("load manifest from %s: %w", pluginDir, err)
 		}
 
-		// Load the component source from the plugin directory
+		// Load the component from the plugin directory's .go files
 		var comp *dynamic.DynamicComponent
 		if loader != nil {
-			sourceFiles, _ := filepath.Glob(filepath.Join(pluginDir, "*.go"))
-			for _, sf := range sourceFiles {
-				base := filepath.Base(sf)
-				if base == "plugin_test.go" || filepath.Ext(base) != ".go" {
-					continue
-				}
-				c, loadErr := loader.LoadFromFile(manifest.Name, sf)
+			files, readErr := dynamic.ReadComponentDir(pluginDir)
+			if readErr != nil {
+				return loaded, fmt.Errorf("load component from %s: %w", pluginDir, readErr)
+			}
+			if len(files) > 0 {
+				c, loadErr := loader.LoadFromFiles(manifest.Name, files)
 				if loadErr != nil {
-					return loaded, fmt.Errorf("load component from %s: %w", sf, loadErr)
+					return loaded, fmt.Errorf("load component from %s: %w", pluginDir, loadErr)
 				}
 				comp = c
-				break // Load the first .go file as the component
 			}
 		}
 
@@ -1,11 +1,14 @@
 package plugin
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
 	"sync"
 	"testing"
+
+	"github.com/GoCodeAlone/workflow/dynamic"
 )
 
 func validManifest(name, version string) *PluginManifest {
@@ -208,6 +211,54 @@ func TestLocalRegistryScanDirectory(t *testing.T) {
 	}
 }
 
+func TestLocalRegistryScanDirectoryMultiFileComponent(t *testing.T) {
+	dir := t.TempDir()
+	pluginDir := filepath.Join(dir, "multi-plugin")
+	if err := os.MkdirAll(pluginDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.MarshalIndent(validManifest("multi-plugin", "1.0.0"), "", "  ")
+	files := map[string]string{
+		"plugin.json": string(data),
+		"main.go": `package component
+
+import "context"
+
+func Name() string { return "multi-plugin" }
+
+func Execute(ctx context.Context, params map[string]interface{}) (map[string]interface{}, error) {
+	return map[string]interface{}{"answer": answer()}, nil
+}
+`,
+		"helper.go": "package component\n\nfunc answer() int { return 42 }\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(pluginDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loader := dynamic.NewLoader(dynamic.NewInterpreterPool(), dynamic.NewComponentRegistry())
+	r := NewLocalRegistry()
+	loaded, err := r.ScanDirectory(dir, loader)
+	if err != nil {
+		t.Fatalf("ScanDirectory error: %v", err)
+	}
+	if len(loaded) != 1 || loaded[0].Component == nil {
+		t.Fatalf("expected 1 plugin with a component, got %+v", loaded)
+	}
+	out, err := loaded[0].Component.Execute(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+	if out["answer"] != 42 {
+		t.Errorf("answer = %v, want 42", out["answer"])
+	}
+	if got := loaded[0].Component.Info().Files; len(got) != 2 {
+		t.Errorf("component files = %v, want helper.go and main.go", got)
+	}
+}
+
 func TestLocalRegistryScanDirectorySkipsNonPlugin(t *testing.T) {
 	dir := t.TempDir()
 
@@ -1,9 +1,11 @@
 // Package ai provides a plugin that registers AI pipeline step types
-// (ai_complete, ai_classify, ai_extract), the dynamic.component module type,
-// and the sub_workflow step.
+// (ai_complete, ai_classify, ai_extract), the ai.pricing and
+// dynamic.component module types, and the sub_workflow step.
 package ai
 
 import (
+	"log/slog"
+
 	"github.com/GoCodeAlone/modular"
 	aiPkg "github.com/GoCodeAlone/workflow/ai"
 	"github.com/GoCodeAlone/workflow/capability"
@@ -40,7 +42,7 @@ func New() *Plugin {
 				Author:      "GoCodeAlone",
 				Description: "AI pipeline steps (complete, classify, extract), dynamic components, and sub-workflow orchestration",
 				Tier:        pluginPkg.TierCore,
-				ModuleTypes: []string{"dynamic.component"},
+				ModuleTypes: []string{"ai.pricing", "dynamic.component"},
 				StepTypes:   []string{"step.ai_complete", "step.ai_classify", "step.ai_extract", "step.sub_workflow"},
 				Capabilities: []pluginPkg.CapabilityDecl{
 					{Name: "ai-completion", Role: "provider", Priority: 50},
@@ -83,9 +85,19 @@ func (p *Plugin) SetWorkflowRegistry(reg *pluginPkg.PluginWorkflowRegistry) {
 	p.workflowRegistry = reg
 }
 
-// ModuleFactories returns module factories for the dynamic.component type.
+// ModuleFactories returns module factories for the ai.pricing and
+// dynamic.component types.
 func (p *Plugin) ModuleFactories() map[string]pluginPkg.ModuleFactory {
 	return map[string]pluginPkg.ModuleFactory{
+		"ai.pricing": func(name string, cfg map[string]any) modular.Module {
+			mod, err := module.NewAIPricingModule(name, cfg, p.aiRegistry)
+			if err != nil {
+				// The engine reports a nil module as a factory failure.
+				slog.Error("failed to create ai.pricing module", "name", name, "error", err)
+				return nil
+			}
+			return mod
+		},
 		"dynamic.component": func(name string, cfg map[string]any) modular.Module {
 			if p.dynamicRegistry == nil {
 				return nil
@@ -94,11 +106,12 @@ func (p *Plugin) ModuleFactories() map[string]pluginPkg.ModuleFactory {
 			if id, ok := cfg["componentId"].(string); ok && id != "" {
 				componentID = id
 			}
-			// Load from source if loader is available
+			// Load from source if loader is available. A directory source
+			// is a multi-file component.
 			if p.dynamicLoader != nil {
 				if sourcePath, ok := cfg["source"].(string); ok && sourcePath != "" {
 					sourcePath = config.ResolvePathInConfig(cfg, sourcePath)
-					_, _ = p.dynamicLoader.LoadFromFile(componentID, sourcePath)
+					_, _ = p.dynamicLoader.LoadFromPath(componentID, sourcePath)
 				}
 			}
 			comp, ok := p.dynamicRegistry.Get(componentID)
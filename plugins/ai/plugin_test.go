@@ -30,11 +30,13 @@ func TestModuleFactories(t *testing.T) {
 	p := New()
 	factories := p.ModuleFactories()
 
-	if _, ok := factories["dynamic.component"]; !ok {
-		t.Error("missing module factory: dynamic.component")
+	for _, modType := range []string{"ai.pricing", "dynamic.component"} {
+		if _, ok := factories[modType]; !ok {
+			t.Errorf("missing module factory: %s", modType)
+		}
 	}
-	if len(factories) != 1 {
-		t.Errorf("expected 1 module factory, got %d", len(factories))
+	if len(factories) != 2 {
+		t.Errorf("expected 2 module factories, got %d", len(factories))
 	}
 }
 
@@ -68,8 +70,8 @@ func TestPluginLoads(t *testing.T) {
 	}
 
 	modules := loader.ModuleFactories()
-	if len(modules) != 1 {
-		t.Fatalf("expected 1 module factory after load, got %d", len(modules))
+	if len(modules) != 2 {
+		t.Fatalf("expected 2 module factories after load, got %d", len(modules))
 	}
 
 	steps := loader.StepFactories()
@@ -195,6 +195,11 @@ func (p *Plugin) ModuleFactories() map[string]plugin.ModuleFactory {
 					qh.SetDelegate(delegate)
 				}
 			}
+			if qh, ok := mod.(interface{ SetRecordExamples(bool) }); ok {
+				if record, ok2 := cfg["recordExamples"].(bool); ok2 {
+					qh.SetRecordExamples(record)
+				}
+			}
 			return mod
 		},
 		"api.command": func(name string, cfg map[string]any) modular.Module {
@@ -425,6 +430,7 @@ func (p *Plugin) ModuleSchemas() []*schema.ModuleSchema {
 			ConfigFields: []schema.ConfigFieldDef{
 				{Key: "delegate", Label: "Delegate Service", Type: schema.FieldTypeString, Description: "Name of a service (implementing http.Handler) to delegate unmatched requests to", Placeholder: "my-service-name", InheritFrom: "dependency.name"},
 				{Key: "routes", Label: "Route Pipelines", Type: schema.FieldTypeArray, Description: "Per-route processing pipelines with composable steps (validate, transform, http_call, etc.)", Group: "routes"},
+				{Key: "recordExamples", Label: "Record Response Examples", Type: schema.FieldTypeBool, DefaultValue: false, Description: "Record the first successful JSON response of each route as its example in the OpenAPI spec, with fields the response schema marks sensitive redacted"},
 			},
 		},
 		{
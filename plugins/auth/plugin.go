@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow/auth/password"
 	"github.com/GoCodeAlone/workflow/capability"
 	"github.com/GoCodeAlone/workflow/config"
 	"github.com/GoCodeAlone/workflow/module"
@@ -107,10 +108,29 @@ func (p *Plugin) ModuleFactories() map[string]plugin.ModuleFactory {
 			if ar, ok := cfg["allowRegistration"].(bool); ok && ar {
 				authMod.SetAllowRegistration(true)
 			}
+			hasher, err := passwordHasherFromConfig(cfg)
+			if err != nil {
+				log.Printf("ERROR: auth.jwt module %q: %v", name, err)
+				return nil
+			}
+			authMod.SetPasswordHasher(hasher)
+			policy, err := passwordPolicyFromConfig(cfg)
+			if err != nil {
+				log.Printf("ERROR: auth.jwt module %q: %v", name, err)
+				return nil
+			}
+			authMod.SetPasswordPolicy(policy)
 			return authMod
 		},
-		"auth.user-store": func(name string, _ map[string]any) modular.Module {
-			return module.NewUserStore(name)
+		"auth.user-store": func(name string, cfg map[string]any) modular.Module {
+			store := module.NewUserStore(name)
+			hasher, err := passwordHasherFromConfig(cfg)
+			if err != nil {
+				log.Printf("ERROR: auth.user-store module %q: %v", name, err)
+				return nil
+			}
+			store.SetPasswordHasher(hasher)
+			return store
 		},
 		"auth.oauth2": func(name string, cfg map[string]any) modular.Module {
 			var providerCfgs []module.OAuth2ProviderConfig
@@ -274,6 +294,41 @@ func (p *Plugin) ModuleFactories() map[string]plugin.ModuleFactory {
 }
 
 // stringFromMap is a helper that extracts a string value from a map by key.
+// passwordHasherFromConfig builds the hasher of the passwordHashing block,
+// or returns nil for the default bcrypt hasher when it is absent.
+func passwordHasherFromConfig(cfg map[string]any) (*password.Hasher, error) {
+	block, ok := cfg["passwordHashing"].(map[string]any)
+	if !ok {
+		return nil, nil
+	}
+	hashCfg, err := password.HashConfigFromMap(block)
+	if err != nil {
+		return nil, fmt.Errorf("passwordHashing: %w", err)
+	}
+	return password.NewHasher(hashCfg)
+}
+
+// passwordPolicyFromConfig builds the policy of the passwordPolicy block,
+// resolving breach list paths relative to the config file, or returns nil
+// when it is absent.
+func passwordPolicyFromConfig(cfg map[string]any) (*password.Policy, error) {
+	block, ok := cfg["passwordPolicy"].(map[string]any)
+	if !ok {
+		return nil, nil
+	}
+	policyCfg, err := password.PolicyConfigFromMap(block)
+	if err != nil {
+		return nil, fmt.Errorf("passwordPolicy: %w", err)
+	}
+	if policyCfg.BreachedRangeDir != "" {
+		policyCfg.BreachedRangeDir = config.ResolvePathInConfig(cfg, policyCfg.BreachedRangeDir)
+	}
+	if policyCfg.BreachedBloomFilter != "" {
+		policyCfg.BreachedBloomFilter = config.ResolvePathInConfig(cfg, policyCfg.BreachedBloomFilter)
+	}
+	return password.NewPolicy(policyCfg)
+}
+
 func stringFromMap(m map[string]any, key string) string {
 	if v, ok := m[key].(string); ok {
 		return v
@@ -395,17 +450,21 @@ func (p *Plugin) ModuleSchemas() []*schema.ModuleSchema {
 				{Key: "seedFile", Label: "Seed Users File", Type: schema.FieldTypeString, Description: "Path to JSON file with initial user accounts", Placeholder: "data/users.json"},
 				{Key: "responseFormat", Label: "Response Format", Type: schema.FieldTypeSelect, Options: []string{"standard", "oauth2"}, Description: "Format of authentication response payloads"},
 				{Key: "allowRegistration", Label: "Allow Open Registration", Type: schema.FieldTypeBool, DefaultValue: false, Description: "When true, any visitor may register without admin intervention"},
+				{Key: "passwordHashing", Label: "Password Hashing", Type: schema.FieldTypeMap, Description: "Password hash algorithm: algorithm (bcrypt|argon2id), cost (bcrypt), memory in KiB, iterations, parallelism (argon2id). Older hashes are replaced on login"},
+				{Key: "passwordPolicy", Label: "Password Policy", Type: schema.FieldTypeMap, Description: "Requirements for new passwords: minLength, requireUppercase, requireLowercase, requireDigit, requireSymbol, disallowEmail, breachedRangeDir, breachedBloomFilter"},
 			},
 			DefaultConfig: map[string]any{"tokenExpiry": "24h", "issuer": "workflow"},
 		},
 		{
-			Type:         "auth.user-store",
-			Label:        "User Store",
-			Category:     "infrastructure",
-			Description:  "In-memory user store with optional persistence write-through for user CRUD operations",
-			Inputs:       []schema.ServiceIODef{{Name: "credentials", Type: "Credentials", Description: "User credentials for CRUD operations"}},
-			Outputs:      []schema.ServiceIODef{{Name: "user-store", Type: "UserStore", Description: "User storage service for auth modules"}},
-			ConfigFields: []schema.ConfigFieldDef{},
+			Type:        "auth.user-store",
+			Label:       "User Store",
+			Category:    "infrastructure",
+			Description: "In-memory user store with optional persistence write-through for user CRUD operations",
+			Inputs:      []schema.ServiceIODef{{Name: "credentials", Type: "Credentials", Description: "User credentials for CRUD operations"}},
+			Outputs:     []schema.ServiceIODef{{Name: "user-store", Type: "UserStore", Description: "User storage service for auth modules"}},
+			ConfigFields: []schema.ConfigFieldDef{
+				{Key: "passwordHashing", Label: "Password Hashing", Type: schema.FieldTypeMap, Description: "Password hash algorithm: algorithm (bcrypt|argon2id), cost (bcrypt), memory in KiB, iterations, parallelism (argon2id). Older hashes are replaced on login"},
+			},
 		},
 		{
 			Type:        "auth.oauth2",
@@ -95,6 +95,32 @@ func TestModuleFactoryJWTWithConfig(t *testing.T) {
 	}
 }
 
+func TestModuleFactoryJWTWithPasswordSettings(t *testing.T) {
+	factories := New().ModuleFactories()
+
+	mod := factories["auth.jwt"]("jwt-test", map[string]any{
+		"secret":          "test-secret",
+		"passwordHashing": map[string]any{"algorithm": "argon2id", "memory": 19456, "iterations": 2, "parallelism": 1},
+		"passwordPolicy":  map[string]any{"minLength": 12, "requireDigit": true, "disallowEmail": true},
+	})
+	if mod == nil {
+		t.Fatal("auth.jwt factory returned nil with password settings")
+	}
+	if mod := factories["auth.user-store"]("users", map[string]any{"passwordHashing": map[string]any{"algorithm": "bcrypt", "cost": 12}}); mod == nil {
+		t.Fatal("auth.user-store factory returned nil with password hashing")
+	}
+
+	for _, cfg := range []map[string]any{
+		{"secret": "test-secret", "passwordHashing": map[string]any{"algorithm": "md5"}},
+		{"secret": "test-secret", "passwordPolicy": map[string]any{"minLength": "twelve"}},
+		{"secret": "test-secret", "passwordPolicy": map[string]any{"breachedBloomFilter": "/nonexistent/pwned.bloom"}},
+	} {
+		if mod := factories["auth.jwt"]("jwt-test", cfg); mod != nil {
+			t.Errorf("expected auth.jwt factory to reject %v", cfg)
+		}
+	}
+}
+
 func TestWiringHooks(t *testing.T) {
 	p := New()
 	hooks := p.WiringHooks()
@@ -69,6 +69,9 @@ func New() *Plugin {
 					"step.deploy_verify",
 					"step.deploy_rollback",
 					"step.container_build",
+					"step.test_report",
+					"step.sbom_generate",
+					"step.attest",
 				},
 				Capabilities: []plugin.CapabilityDecl{
 					{Name: "cicd-pipeline", Role: "provider", Priority: 50},
@@ -131,6 +134,9 @@ func (p *Plugin) StepFactories() map[string]plugin.StepFactory {
 		"step.deploy_verify":            wrapStepFactory(module.NewDeployVerifyStepFactory()),
 		"step.deploy_rollback":          wrapStepFactory(module.NewDeployRollbackStepFactory()),
 		"step.container_build":          wrapStepFactory(module.NewContainerBuildStepFactory()),
+		"step.test_report":              wrapStepFactory(module.NewTestReportStepFactory()),
+		"step.sbom_generate":            wrapStepFactory(module.NewSBOMGenerateStepFactory()),
+		"step.attest":                   wrapStepFactory(module.NewAttestStepFactory()),
 	}
 }
 
@@ -62,6 +62,9 @@ func TestStepFactories(t *testing.T) {
 		"step.deploy_verify",
 		"step.deploy_rollback",
 		"step.container_build",
+		"step.test_report",
+		"step.sbom_generate",
+		"step.attest",
 	}
 
 	for _, stepType := range expectedSteps {
@@ -96,7 +99,7 @@ func TestPluginLoads(t *testing.T) {
 	}
 
 	steps := loader.StepFactories()
-	if len(steps) != 31 {
-		t.Fatalf("expected 31 step factories after load, got %d", len(steps))
+	if len(steps) != 34 {
+		t.Fatalf("expected 34 step factories after load, got %d", len(steps))
 	}
 }
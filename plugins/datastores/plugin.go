@@ -71,9 +71,15 @@ func (p *Plugin) ModuleFactories() map[string]plugin.ModuleFactory {
 		"nosql.dynamodb": func(name string, cfg map[string]any) modular.Module {
 			c := module.DynamoDBNoSQLConfig{}
 			c.TableName, _ = cfg["tableName"].(string)
+			if c.TableName == "" {
+				c.TableName, _ = cfg["table"].(string)
+			}
 			c.Region, _ = cfg["region"].(string)
 			c.Endpoint, _ = cfg["endpoint"].(string)
 			c.Credentials, _ = cfg["credentials"].(string)
+			c.PartitionKey, _ = cfg["partitionKey"].(string)
+			c.SortKey, _ = cfg["sortKey"].(string)
+			c.KeySeparator, _ = cfg["keySeparator"].(string)
 			return module.NewDynamoDBNoSQL(name, c)
 		},
 		"nosql.mongodb": func(name string, cfg map[string]any) modular.Module {
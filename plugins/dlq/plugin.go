@@ -1,5 +1,6 @@
 // Package dlq provides a plugin that registers the dlq.service module type
-// for config-driven dead letter queue initialization.
+// for config-driven dead letter queue initialization, and the
+// step.dlq_publish step that adds records to a dead letter queue.
 package dlq
 
 import (
@@ -8,7 +9,14 @@ import (
 	"github.com/GoCodeAlone/workflow/plugin"
 )
 
-// Plugin registers the dlq.service module type.
+// Service name suffixes under which a dlq.service module named <name>
+// registers its admin handler mux and its store, e.g. "dlq.store".
+const (
+	AdminServiceSuffix = module.DLQAdminServiceSuffix
+	StoreServiceSuffix = module.DLQStoreServiceSuffix
+)
+
+// Plugin registers the dlq.service module type and the step.dlq_publish step.
 type Plugin struct {
 	plugin.BaseEnginePlugin
 }
@@ -29,6 +37,7 @@ func New() *Plugin {
 				Description: "Dead letter queue service module for failed message management",
 				Tier:        plugin.TierCore,
 				ModuleTypes: []string{"dlq.service"},
+				StepTypes:   []string{"step.dlq_publish"},
 			},
 		},
 	}
@@ -56,3 +65,12 @@ func (p *Plugin) ModuleFactories() map[string]plugin.ModuleFactory {
 		},
 	}
 }
+
+// StepFactories returns the step factory for publishing to a DLQ.
+func (p *Plugin) StepFactories() map[string]plugin.StepFactory {
+	return map[string]plugin.StepFactory{
+		"step.dlq_publish": func(name string, cfg map[string]any, app modular.Application) (any, error) {
+			return module.NewDLQPublishStepFactory()(name, cfg, app)
+		},
+	}
+}
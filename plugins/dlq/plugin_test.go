@@ -39,3 +39,15 @@ func TestPlugin_ModuleFactory_Creates(t *testing.T) {
 		t.Errorf("Name() = %q, want %q", mod.Name(), "test-dlq")
 	}
 }
+
+func TestPlugin_StepFactories(t *testing.T) {
+	p := New()
+	factory, ok := p.StepFactories()["step.dlq_publish"]
+	if !ok {
+		t.Fatal("StepFactories() missing step.dlq_publish")
+	}
+	step, err := factory("reject", map[string]any{"reason": "invalid"}, nil)
+	if err != nil || step == nil {
+		t.Fatalf("factory() = %v, %v", step, err)
+	}
+}
@@ -1,16 +1,20 @@
 // Package eventstore provides a plugin that registers the eventstore.service
-// module type for config-driven event store initialization.
+// module type for config-driven event store initialization, and the
+// eventstore.projection module type for materialized views of its events.
 package eventstore
 
 import (
 	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow/interfaces"
 	"github.com/GoCodeAlone/workflow/module"
 	"github.com/GoCodeAlone/workflow/plugin"
 )
 
-// Plugin registers the eventstore.service module type.
+// Plugin registers the eventstore.service and eventstore.projection module
+// types and the step.projection_rebuild step.
 type Plugin struct {
 	plugin.BaseEnginePlugin
+	stepRegistry interfaces.StepRegistrar
 }
 
 // New creates a new eventstore plugin.
@@ -20,21 +24,31 @@ func New() *Plugin {
 			BaseNativePlugin: plugin.BaseNativePlugin{
 				PluginName:        "eventstore",
 				PluginVersion:     "1.0.0",
-				PluginDescription: "Event store service module for execution event persistence",
+				PluginDescription: "Event store service and projection modules for execution event persistence and materialized views",
 			},
 			Manifest: plugin.PluginManifest{
 				Name:        "eventstore",
 				Version:     "1.0.0",
 				Author:      "GoCodeAlone",
-				Description: "Event store service module for execution event persistence",
+				Description: "Event store service and projection modules for execution event persistence and materialized views",
 				Tier:        plugin.TierCore,
-				ModuleTypes: []string{"eventstore.service"},
+				ModuleTypes: []string{"eventstore.service", "eventstore.projection"},
+				StepTypes:   []string{"step.projection_rebuild"},
 			},
 		},
 	}
 }
 
-// ModuleFactories returns the module factories for the event store service.
+// SetStepRegistry is called by the engine to inject the step registry that
+// projection reduce steps are created from.
+func (p *Plugin) SetStepRegistry(registry interfaces.StepRegistryProvider) {
+	if r, ok := registry.(interfaces.StepRegistrar); ok {
+		p.stepRegistry = r
+	}
+}
+
+// ModuleFactories returns the module factories for the event store service
+// and projections.
 func (p *Plugin) ModuleFactories() map[string]plugin.ModuleFactory {
 	return map[string]plugin.ModuleFactory{
 		"eventstore.service": func(name string, config map[string]any) modular.Module {
@@ -50,11 +64,52 @@ func (p *Plugin) ModuleFactories() map[string]plugin.ModuleFactory {
 			} else if v, ok := config["retention_days"].(float64); ok {
 				cfg.RetentionDays = int(v)
 			}
+			if t, ok := config["tiering"].(map[string]any); ok {
+				cfg.Tiering = tieringConfig(t)
+			}
 			mod, err := module.NewEventStoreServiceModule(name, cfg)
 			if err != nil {
 				return nil
 			}
 			return mod
 		},
+		"eventstore.projection": func(name string, config map[string]any) modular.Module {
+			mod := module.NewProjectionModule(name, config)
+			if p.stepRegistry != nil {
+				mod.SetStepRegistry(p.stepRegistry)
+			}
+			return mod
+		},
+	}
+}
+
+// StepFactories returns the step factories for event store projections.
+func (p *Plugin) StepFactories() map[string]plugin.StepFactory {
+	return map[string]plugin.StepFactory{
+		"step.projection_rebuild": func(name string, cfg map[string]any, app modular.Application) (any, error) {
+			return module.NewProjectionRebuildStepFactory()(name, cfg, app)
+		},
+	}
+}
+
+// tieringConfig reads the tiering block of an eventstore.service config.
+func tieringConfig(raw map[string]any) *module.EventStoreTieringConfig {
+	t := &module.EventStoreTieringConfig{}
+	t.After, _ = raw["after"].(string)
+	t.Storage, _ = raw["storage"].(string)
+	t.Prefix, _ = raw["prefix"].(string)
+	t.Interval, _ = raw["interval"].(string)
+	t.BatchSize = intFromConfig(raw["batch_size"])
+	t.CacheBatches = intFromConfig(raw["cache_batches"])
+	return t
+}
+
+func intFromConfig(v any) int {
+	switch n := v.(type) {
+	case int:
+		return n
+	case float64:
+		return int(n)
 	}
+	return 0
 }
@@ -40,3 +40,25 @@ func TestPlugin_ModuleFactory_Creates(t *testing.T) {
 		t.Errorf("Name() = %q, want %q", mod.Name(), "test-es")
 	}
 }
+
+func TestPlugin_ProjectionFactories(t *testing.T) {
+	p := New()
+	mod := p.ModuleFactories()["eventstore.projection"]("orders", map[string]any{
+		"database":  "db",
+		"table":     "orders",
+		"key":       "{{ .event.pipeline }}",
+		"columns":   map[string]any{"runs": "INTEGER"},
+		"component": "reducer",
+	})
+	if mod == nil || mod.Name() != "orders" {
+		t.Fatalf("eventstore.projection factory returned %v", mod)
+	}
+
+	step, err := p.StepFactories()["step.projection_rebuild"]("rebuild", map[string]any{"projection": "orders"}, nil)
+	if err != nil || step == nil {
+		t.Fatalf("step.projection_rebuild factory: %v", err)
+	}
+	if _, err := p.StepFactories()["step.projection_rebuild"]("rebuild", map[string]any{}, nil); err == nil {
+		t.Error("expected an error without 'projection'")
+	}
+}
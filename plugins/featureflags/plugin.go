@@ -9,6 +9,10 @@ import (
 	"github.com/GoCodeAlone/workflow/plugin"
 )
 
+// AdminServiceSuffix is the service name suffix under which a
+// featureflag.service module named <name> registers its FeatureFlagAdmin.
+const AdminServiceSuffix = module.FeatureFlagAdminServiceSuffix
+
 // Plugin registers the featureflag.service module type and step.feature_flag / step.ff_gate step factories.
 type Plugin struct {
 	plugin.BaseEnginePlugin
@@ -74,6 +78,12 @@ func (p *Plugin) ModuleFactories() map[string]plugin.ModuleFactory {
 			if v, ok := config["db_path"].(string); ok {
 				ffCfg.DBPath = v
 			}
+			if v, ok := config["admin_url"].(string); ok {
+				ffCfg.AdminURL = v
+			}
+			if v, ok := config["engine_name"].(string); ok {
+				ffCfg.EngineName = v
+			}
 			ffMod, err := module.NewFeatureFlagModule(name, ffCfg)
 			if err != nil {
 				// Return nil; the engine will catch missing module
@@ -35,6 +35,9 @@ func moduleFactories() map[string]plugin.ModuleFactory {
 		"http.middleware.cors":            corsMiddlewareFactory,
 		"http.middleware.requestid":       requestIDMiddlewareFactory,
 		"http.middleware.securityheaders": securityHeadersMiddlewareFactory,
+		"http.middleware.clientcert":      clientCertMiddlewareFactory,
+
+		"http.session": httpSessionFactory,
 	}
 }
 
@@ -51,9 +54,44 @@ func httpServerFactory(name string, cfg map[string]any) modular.Module {
 		return 0
 	}
 	srv.SetTimeouts(parseDuration("readTimeout"), parseDuration("writeTimeout"), parseDuration("idleTimeout"))
+	srv.SetBodyLimits(module.BodyLimitsFromConfig(cfg))
+	srv.SetInFlightLimits(module.InFlightLimitsFromConfig(cfg))
+	if tlsCfg, ok := cfg["tls"].(map[string]any); ok {
+		srv.SetTLSConfig(httpServerTLSConfig(tlsCfg))
+	}
+	if caCfg, ok := cfg["clientAuth"].(map[string]any); ok {
+		ca := module.HTTPServerClientAuthConfig{}
+		ca.Mode, _ = caCfg["mode"].(string)
+		ca.CAFile, _ = caCfg["caFile"].(string)
+		ca.CRLFile, _ = caCfg["crlFile"].(string)
+		if v, ok := caCfg["reloadInterval"].(string); ok {
+			if d, err := time.ParseDuration(v); err == nil {
+				ca.ReloadInterval = d
+			}
+		}
+		srv.SetClientAuthConfig(ca)
+	}
 	return srv
 }
 
+// httpServerTLSConfig converts the http.server "tls" config block.
+func httpServerTLSConfig(cfg map[string]any) module.HTTPServerTLSConfig {
+	tlsCfg := module.HTTPServerTLSConfig{}
+	tlsCfg.Mode, _ = cfg["mode"].(string)
+	tlsCfg.Manual.CertFile, _ = cfg["certFile"].(string)
+	tlsCfg.Manual.KeyFile, _ = cfg["keyFile"].(string)
+	tlsCfg.Autocert.CacheDir, _ = cfg["cacheDir"].(string)
+	tlsCfg.Autocert.Email, _ = cfg["email"].(string)
+	if domains, ok := cfg["domains"].([]any); ok {
+		for _, d := range domains {
+			if s, ok := d.(string); ok {
+				tlsCfg.Autocert.Domains = append(tlsCfg.Autocert.Domains, s)
+			}
+		}
+	}
+	return tlsCfg
+}
+
 func httpServerAddress(cfg map[string]any) string {
 	if addr, ok := cfg["address"].(string); ok && strings.TrimSpace(addr) != "" {
 		return addr
@@ -206,7 +244,69 @@ func authMiddlewareFactory(name string, cfg map[string]any) modular.Module {
 	if at, ok := cfg["authType"].(string); ok {
 		authType = at
 	}
-	return module.NewAuthMiddleware(name, authType)
+	am := module.NewAuthMiddleware(name, authType)
+	jwks, hasJWKS := cfg["jwks"].(map[string]any)
+	introspection, hasIntrospection := cfg["introspection"].(map[string]any)
+	if hasJWKS || hasIntrospection {
+		am.SetExternalTokenConfig(externalTokenConfig(jwks, introspection))
+	}
+	return am
+}
+
+// externalTokenConfig builds the external IdP settings of http.middleware.auth
+// from its jwks and introspection maps. Issuer, audience and clockSkew apply
+// to both and may be set in either map.
+func externalTokenConfig(jwks, introspection map[string]any) module.ExternalTokenConfig {
+	var c module.ExternalTokenConfig
+	c.JWKSURL, _ = jwks["url"].(string)
+	c.Algorithms = stringList(jwks["algorithms"])
+	c.JWKSRefreshInterval = configDuration(jwks["refreshInterval"])
+	c.IntrospectionURL, _ = introspection["url"].(string)
+	c.ClientID, _ = introspection["clientId"].(string)
+	c.ClientSecret, _ = introspection["clientSecret"].(string)
+	c.IntrospectionCacheTTL = configDuration(introspection["cacheTTL"])
+	for _, m := range []map[string]any{introspection, jwks} {
+		if iss, ok := m["issuer"].(string); ok && iss != "" {
+			c.Issuer = iss
+		}
+		if aud := stringList(m["audience"]); len(aud) > 0 {
+			c.Audiences = aud
+		}
+		if d := configDuration(m["clockSkew"]); d > 0 {
+			c.ClockSkew = d
+		}
+	}
+	return c
+}
+
+// stringList accepts a string or a list of strings.
+func stringList(v any) []string {
+	switch v := v.(type) {
+	case string:
+		if v != "" {
+			return []string{v}
+		}
+	case []string:
+		return v
+	case []any:
+		var out []string
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func configDuration(v any) time.Duration {
+	if s, ok := v.(string); ok {
+		if d, err := time.ParseDuration(s); err == nil {
+			return d
+		}
+	}
+	return 0
 }
 
 func loggingMiddlewareFactory(name string, cfg map[string]any) modular.Module {
@@ -256,47 +356,15 @@ func rateLimitMiddlewareFactory(name string, cfg map[string]any) modular.Module
 }
 
 func corsMiddlewareFactory(name string, cfg map[string]any) modular.Module {
-	corsCfg := module.CORSMiddlewareConfig{
-		AllowedOrigins: []string{"*"},
-		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-	}
-	if origins, ok := cfg["allowedOrigins"].([]any); ok {
-		corsCfg.AllowedOrigins = make([]string, len(origins))
-		for i, origin := range origins {
-			if str, ok := origin.(string); ok {
-				corsCfg.AllowedOrigins[i] = str
-			}
-		}
-	}
-	if methods, ok := cfg["allowedMethods"].([]any); ok {
-		corsCfg.AllowedMethods = make([]string, len(methods))
-		for i, method := range methods {
-			if str, ok := method.(string); ok {
-				corsCfg.AllowedMethods[i] = str
-			}
-		}
-	}
-	if headers, ok := cfg["allowedHeaders"].([]any); ok {
-		corsCfg.AllowedHeaders = make([]string, len(headers))
-		for i, header := range headers {
-			if str, ok := header.(string); ok {
-				corsCfg.AllowedHeaders[i] = str
-			}
-		}
-	}
-	if allowCreds, ok := cfg["allowCredentials"].(bool); ok {
-		corsCfg.AllowCredentials = allowCreds
-	}
-	if maxAge, ok := cfg["maxAge"].(int); ok {
-		corsCfg.MaxAge = maxAge
-	} else if maxAgeFloat, ok := cfg["maxAge"].(float64); ok {
-		corsCfg.MaxAge = int(maxAgeFloat)
-	}
-	return module.NewCORSMiddlewareWithConfig(name, corsCfg)
+	return module.NewCORSMiddlewareWithConfig(name, module.CORSModuleConfig(cfg))
 }
 
-func requestIDMiddlewareFactory(name string, _ map[string]any) modular.Module {
-	return module.NewRequestIDMiddleware(name)
+func requestIDMiddlewareFactory(name string, cfg map[string]any) modular.Module {
+	m := module.NewRequestIDMiddleware(name)
+	if header, ok := cfg["header"].(string); ok {
+		m.SetHeaderName(header)
+	}
+	return m
 }
 
 func securityHeadersMiddlewareFactory(name string, cfg map[string]any) modular.Module {
@@ -322,6 +390,50 @@ func securityHeadersMiddlewareFactory(name string, cfg map[string]any) modular.M
 	return module.NewSecurityHeadersMiddleware(name, secCfg)
 }
 
+func clientCertMiddlewareFactory(name string, cfg map[string]any) modular.Module {
+	ccCfg := module.ClientCertMiddlewareConfig{}
+	ccCfg.CAFile, _ = cfg["caFile"].(string)
+	ccCfg.CRLFile, _ = cfg["crlFile"].(string)
+	if v, ok := cfg["reloadInterval"].(string); ok {
+		if d, err := time.ParseDuration(v); err == nil {
+			ccCfg.ReloadInterval = d
+		}
+	}
+	if rules, ok := cfg["rules"].([]any); ok {
+		for _, raw := range rules {
+			rm, ok := raw.(map[string]any)
+			if !ok {
+				continue
+			}
+			rule := module.ClientCertRule{}
+			rule.Path, _ = rm["path"].(string)
+			rule.Subjects = stringSlice(rm["subjects"])
+			rule.SANs = stringSlice(rm["sans"])
+			ccCfg.Rules = append(ccCfg.Rules, rule)
+		}
+	}
+	return module.NewClientCertMiddleware(name, ccCfg)
+}
+
+func httpSessionFactory(name string, cfg map[string]any) modular.Module {
+	return module.NewHTTPSessionModule(name, cfg)
+}
+
+// stringSlice converts a YAML list to []string, skipping non-string items.
+func stringSlice(v any) []string {
+	items, ok := v.([]any)
+	if !ok {
+		return nil
+	}
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
 // httpClientModuleFactory creates an HTTPClientModule from the plugin module config map.
 func httpClientModuleFactory(name string, cfg map[string]any) modular.Module {
 	return module.HTTPClientModuleFactory(name, cfg)
@@ -45,16 +45,22 @@ func New() *HTTPPlugin {
 					"http.middleware.cors",
 					"http.middleware.requestid",
 					"http.middleware.securityheaders",
+					"http.middleware.clientcert",
+					"http.session",
 				},
 				StepTypes: []string{
 					"step.rate_limit",
 					"step.circuit_breaker",
+					"step.session_get",
+					"step.session_set",
+					"step.session_destroy",
 				},
 				TriggerTypes:  []string{"http"},
 				WorkflowTypes: []string{"http"},
 				WiringHooks: []string{
 					"http-auth-provider-wiring",
 					"http-cors-global-wiring",
+					"http-session-global-wiring",
 					"http-static-fileserver-registration",
 				},
 				Capabilities: []plugin.CapabilityDecl{
@@ -166,6 +172,17 @@ func (p *HTTPPlugin) PipelineTriggerConfigWrappers() map[string]plugin.TriggerCo
 			if middlewares, ok := cfg["middlewares"]; ok {
 				route["middlewares"] = middlewares
 			}
+			if locale, ok := cfg["locale"]; ok {
+				route["locale"] = locale
+			}
+			if cors, ok := cfg["cors"]; ok {
+				route["cors"] = cors
+			}
+			for _, key := range []string{"maxRequestBytes", "maxResponseBytes", "maxInFlight", "maxQueued", "queueTimeout", "retryAfter", "execution", "workerLabels"} {
+				if v, ok := cfg[key]; ok {
+					route[key] = v
+				}
+			}
 			if includeRawBody, ok := cfg["include_raw_body"]; ok {
 				route["include_raw_body"] = includeRawBody
 			} else if rawBody, ok := cfg["raw_body"]; ok {
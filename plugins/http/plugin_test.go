@@ -3,6 +3,7 @@ package http
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/GoCodeAlone/workflow/capability"
 	"github.com/GoCodeAlone/workflow/module"
@@ -127,6 +128,8 @@ func TestModuleFactories(t *testing.T) {
 		"http.middleware.cors",
 		"http.middleware.requestid",
 		"http.middleware.securityheaders",
+		"http.middleware.clientcert",
+		"http.session",
 	}
 
 	for _, mt := range expectedTypes {
@@ -143,6 +146,9 @@ func TestStepFactories(t *testing.T) {
 	expectedSteps := []string{
 		"step.rate_limit",
 		"step.circuit_breaker",
+		"step.session_get",
+		"step.session_set",
+		"step.session_destroy",
 	}
 
 	for _, st := range expectedSteps {
@@ -195,6 +201,8 @@ func TestModuleSchemas(t *testing.T) {
 		"http.middleware.cors",
 		"http.middleware.requestid",
 		"http.middleware.securityheaders",
+		"http.middleware.clientcert",
+		"http.session",
 	}
 
 	for _, et := range expectedTypes {
@@ -207,8 +215,8 @@ func TestModuleSchemas(t *testing.T) {
 func TestWiringHooks(t *testing.T) {
 	p := New()
 	hooks := p.WiringHooks()
-	if len(hooks) != 3 {
-		t.Errorf("WiringHooks() returned %d hooks, want 3", len(hooks))
+	if len(hooks) != 4 {
+		t.Errorf("WiringHooks() returned %d hooks, want 4", len(hooks))
 	}
 
 	hookNames := make(map[string]bool)
@@ -219,6 +227,7 @@ func TestWiringHooks(t *testing.T) {
 	expectedHooks := []string{
 		"http-auth-provider-wiring",
 		"http-cors-global-wiring",
+		"http-session-global-wiring",
 		"http-static-fileserver-registration",
 	}
 
@@ -238,11 +247,11 @@ func TestEngineManifest(t *testing.T) {
 	if m.Name != "workflow-plugin-http" {
 		t.Errorf("manifest.Name = %q, want %q", m.Name, "workflow-plugin-http")
 	}
-	if len(m.ModuleTypes) != 14 {
-		t.Errorf("manifest has %d module types, want 14", len(m.ModuleTypes))
+	if len(m.ModuleTypes) != 16 {
+		t.Errorf("manifest has %d module types, want 16", len(m.ModuleTypes))
 	}
-	if len(m.StepTypes) != 2 {
-		t.Errorf("manifest has %d step types, want 2", len(m.StepTypes))
+	if len(m.StepTypes) != 5 {
+		t.Errorf("manifest has %d step types, want 5", len(m.StepTypes))
 	}
 	if len(m.TriggerTypes) != 1 {
 		t.Errorf("manifest has %d trigger types, want 1", len(m.TriggerTypes))
@@ -275,6 +284,8 @@ func TestModuleFactorySmoke(t *testing.T) {
 		{"http.middleware.cors", map[string]any{}},
 		{"http.middleware.requestid", map[string]any{}},
 		{"http.middleware.securityheaders", map[string]any{"frameOptions": "SAMEORIGIN"}},
+		{"http.middleware.clientcert", map[string]any{"rules": []any{map[string]any{"path": "/admin/*", "subjects": []any{"admin-*"}}}}},
+		{"http.session", map[string]any{"store": "memory"}},
 	}
 
 	for _, tt := range tests {
@@ -314,6 +325,21 @@ func TestStepFactorySmoke(t *testing.T) {
 			map[string]any{"failure_threshold": 5, "timeout": "30s"},
 			"test-circuit-breaker",
 		},
+		{
+			"step.session_get",
+			map[string]any{"key": "user_id"},
+			"test-session-get",
+		},
+		{
+			"step.session_set",
+			map[string]any{"values": map[string]any{"user_id": "42"}, "regenerate": true},
+			"test-session-set",
+		},
+		{
+			"step.session_destroy",
+			map[string]any{},
+			"test-session-destroy",
+		},
 	}
 
 	for _, tt := range tests {
@@ -444,6 +470,28 @@ func TestRateLimitMiddlewareFactory_InvalidValues(t *testing.T) {
 	}
 }
 
+func TestExternalTokenConfig(t *testing.T) {
+	got := externalTokenConfig(
+		map[string]any{"url": "https://idp/jwks", "issuer": "https://idp/", "audience": []any{"a", "b"}, "algorithms": "ES256", "refreshInterval": "10m"},
+		map[string]any{"url": "https://idp/introspect", "clientId": "api", "clientSecret": "s", "cacheTTL": "30s", "clockSkew": "1m"},
+	)
+	want := module.ExternalTokenConfig{
+		JWKSURL:               "https://idp/jwks",
+		Issuer:                "https://idp/",
+		Audiences:             []string{"a", "b"},
+		Algorithms:            []string{"ES256"},
+		ClockSkew:             time.Minute,
+		JWKSRefreshInterval:   10 * time.Minute,
+		IntrospectionURL:      "https://idp/introspect",
+		ClientID:              "api",
+		ClientSecret:          "s",
+		IntrospectionCacheTTL: 30 * time.Second,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("externalTokenConfig =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
 func TestStaticFileServerFactory_SPAConfigKey(t *testing.T) {
 	factories := moduleFactories()
 	factory, ok := factories["static.fileserver"]
@@ -613,7 +661,7 @@ func TestPluginLoaderIntegration(t *testing.T) {
 	}
 
 	hooks := loader.WiringHooks()
-	if len(hooks) != 3 {
-		t.Errorf("loader has %d wiring hooks, want 3", len(hooks))
+	if len(hooks) != 4 {
+		t.Errorf("loader has %d wiring hooks, want 4", len(hooks))
 	}
 }
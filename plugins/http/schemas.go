@@ -19,6 +19,8 @@ func moduleSchemas() []*schema.ModuleSchema {
 		corsMiddlewareSchema(),
 		requestIDMiddlewareSchema(),
 		securityHeadersMiddlewareSchema(),
+		clientCertMiddlewareSchema(),
+		httpSessionSchema(),
 	}
 }
 
@@ -32,6 +34,14 @@ func httpServerSchema() *schema.ModuleSchema {
 		ConfigFields: []schema.ConfigFieldDef{
 			{Key: "address", Label: "Listen Address", Type: schema.FieldTypeString, Description: "Canonical host:port to listen on (e.g. :8080, 0.0.0.0:80)", DefaultValue: ":8080", Placeholder: ":8080"},
 			{Key: "port", Label: "Port Alias", Type: schema.FieldTypeNumber, Description: "Alias for address; normalized to :<port> when address is omitted", Placeholder: "8080"},
+			{Key: "tls", Label: "TLS", Type: schema.FieldTypeMap, Description: "TLS settings: mode (manual|autocert), certFile, keyFile, domains, cacheDir, email", Group: "tls"},
+			{Key: "clientAuth", Label: "Client Auth (mTLS)", Type: schema.FieldTypeMap, Description: "Client certificate auth: mode (request|require|verify), caFile, crlFile, reloadInterval", Group: "tls"},
+			{Key: "maxRequestBytes", Label: "Max Request Bytes", Type: schema.FieldTypeNumber, Description: "Largest request body accepted by any route, in bytes; larger requests get 413. Routes can override it. 0 means no limit", Placeholder: "10485760", Group: "limits"},
+			{Key: "maxResponseBytes", Label: "Max Response Bytes", Type: schema.FieldTypeNumber, Description: "Largest response body any route may send, in bytes; larger responses fail with 500, or are truncated once streaming. Routes can override it. 0 means no limit", Placeholder: "52428800", Group: "limits"},
+			{Key: "maxInFlight", Label: "Max In-Flight Requests", Type: schema.FieldTypeNumber, Description: "Most requests handled at once across all routes; excess requests queue, then get 503 with Retry-After. Routes can set their own limit too. 0 means no limit", Placeholder: "1000", Group: "limits"},
+			{Key: "maxQueued", Label: "Max Queued Requests", Type: schema.FieldTypeNumber, Description: "Requests that may wait for an in-flight slot; further requests get 503 at once. 0 sheds every request over maxInFlight", Placeholder: "100", Group: "limits"},
+			{Key: "queueTimeout", Label: "Queue Timeout", Type: schema.FieldTypeDuration, Description: "Longest a queued request waits for an in-flight slot before getting 503", DefaultValue: "10s", Placeholder: "10s", Group: "limits"},
+			{Key: "retryAfter", Label: "Retry After", Type: schema.FieldTypeDuration, Description: "Retry-After sent with 503 responses to shed requests, rounded up to whole seconds", DefaultValue: "1s", Placeholder: "1s", Group: "limits"},
 		},
 		DefaultConfig: map[string]any{"address": ":8080"},
 		MaxIncoming:   intPtr(0),
@@ -132,6 +142,8 @@ func authMiddlewareSchema() *schema.ModuleSchema {
 		Outputs:     []schema.ServiceIODef{{Name: "authed", Type: "http.Request", Description: "Authenticated HTTP request with claims"}},
 		ConfigFields: []schema.ConfigFieldDef{
 			{Key: "authType", Label: "Auth Type", Type: schema.FieldTypeSelect, Options: []string{"Bearer", "Basic", "ApiKey"}, DefaultValue: "Bearer", Description: "Authentication scheme to enforce"},
+			{Key: "jwks", Label: "JWKS", Type: schema.FieldTypeMap, Description: "Verify JWTs from an external identity provider: url, issuer, audience (string or list), algorithms, clockSkew, refreshInterval (default 1h)", Group: "external"},
+			{Key: "introspection", Label: "Token Introspection", Type: schema.FieldTypeMap, Description: "Validate opaque tokens with RFC 7662 introspection: url, clientId, clientSecret, cacheTTL; issuer, audience and clockSkew as for jwks", Group: "external"},
 		},
 		DefaultConfig: map[string]any{"authType": "Bearer"},
 	}
@@ -181,7 +193,7 @@ func corsMiddlewareSchema() *schema.ModuleSchema {
 			{Key: "allowedOrigins", Label: "Allowed Origins", Type: schema.FieldTypeArray, ArrayItemType: "string", DefaultValue: []string{"*"}, Description: "Allowed origins (e.g. https://example.com, http://localhost:3000, *.example.com)"},
 			{Key: "allowedMethods", Label: "Allowed Methods", Type: schema.FieldTypeArray, ArrayItemType: "string", DefaultValue: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}, Description: "Allowed HTTP methods"},
 			{Key: "allowedHeaders", Label: "Allowed Headers", Type: schema.FieldTypeArray, ArrayItemType: "string", DefaultValue: []string{"Content-Type", "Authorization"}, Description: "Allowed request headers (e.g. Authorization, X-CSRF-Token, X-Request-Id)"},
-			{Key: "allowCredentials", Label: "Allow Credentials", Type: schema.FieldTypeBool, DefaultValue: false, Description: "Whether to allow requests with credentials (cookies, authorization headers). When true, the actual Origin is reflected instead of *"},
+			{Key: "allowCredentials", Label: "Allow Credentials", Type: schema.FieldTypeBool, DefaultValue: false, Description: "Whether to allow requests with credentials (cookies, authorization headers). When true, the actual Origin is reflected instead of *, and allowedOrigins must not contain *"},
 			{Key: "maxAge", Label: "Max Age (sec)", Type: schema.FieldTypeNumber, DefaultValue: 0, Description: "How long (in seconds) the preflight response may be cached. 0 means no caching directive is sent"},
 		},
 		DefaultConfig: map[string]any{
@@ -196,13 +208,15 @@ func corsMiddlewareSchema() *schema.ModuleSchema {
 
 func requestIDMiddlewareSchema() *schema.ModuleSchema {
 	return &schema.ModuleSchema{
-		Type:         "http.middleware.requestid",
-		Label:        "Request ID Middleware",
-		Category:     "middleware",
-		Description:  "Adds a unique request ID header to each request for tracing",
-		Inputs:       []schema.ServiceIODef{{Name: "request", Type: "http.Request", Description: "HTTP request without request ID"}},
-		Outputs:      []schema.ServiceIODef{{Name: "tagged", Type: "http.Request", Description: "HTTP request with X-Request-ID header"}},
-		ConfigFields: []schema.ConfigFieldDef{},
+		Type:        "http.middleware.requestid",
+		Label:       "Request ID Middleware",
+		Category:    "middleware",
+		Description: "Reads or generates an X-Request-ID, echoes it on the response and tags the request's executions, step logs and events with it",
+		Inputs:      []schema.ServiceIODef{{Name: "request", Type: "http.Request", Description: "HTTP request without request ID"}},
+		Outputs:     []schema.ServiceIODef{{Name: "tagged", Type: "http.Request", Description: "HTTP request with X-Request-ID header"}},
+		ConfigFields: []schema.ConfigFieldDef{
+			{Key: "header", Label: "Header", Type: schema.FieldTypeString, DefaultValue: "X-Request-ID", Description: "Header the request ID is read from, echoed on and propagated in on outbound calls"},
+		},
 	}
 }
 
@@ -253,3 +267,43 @@ func httpClientSchema() *schema.ModuleSchema {
 }
 
 func intPtr(v int) *int { return &v }
+
+func clientCertMiddlewareSchema() *schema.ModuleSchema {
+	return &schema.ModuleSchema{
+		Type:        "http.middleware.clientcert",
+		Label:       "Client Certificate Auth",
+		Category:    "middleware",
+		Description: "Authenticates requests by TLS client certificate, authorizes subject/SAN patterns per route, and exposes the identity as _auth.client_cert",
+		Inputs:      []schema.ServiceIODef{{Name: "request", Type: "http.Request", Description: "HTTPS request with a client certificate"}},
+		Outputs:     []schema.ServiceIODef{{Name: "authenticated", Type: "http.Request", Description: "HTTP request with client certificate identity in context"}},
+		ConfigFields: []schema.ConfigFieldDef{
+			{Key: "caFile", Label: "CA Bundle", Type: schema.FieldTypeFilePath, Description: "PEM bundle of CAs trusted to issue client certificates (omit when http.server clientAuth.mode is verify)"},
+			{Key: "crlFile", Label: "CRL File", Type: schema.FieldTypeFilePath, Description: "PEM or DER certificate revocation list"},
+			{Key: "reloadInterval", Label: "Reload Interval", Type: schema.FieldTypeDuration, DefaultValue: "30s", Description: "How often the CA bundle and CRL are checked for changes"},
+			{Key: "rules", Label: "Rules", Type: schema.FieldTypeArray, Description: "Per-route allow rules: path, subjects, sans ('*' wildcards)"},
+		},
+	}
+}
+
+func httpSessionSchema() *schema.ModuleSchema {
+	return &schema.ModuleSchema{
+		Type:        "http.session",
+		Label:       "HTTP Session",
+		Category:    "middleware",
+		Description: "Cookie-based browser sessions: loads the request's session for session steps and auth.jwt, saves it and sets the cookie. Session IDs are never logged or exposed to steps",
+		Inputs:      []schema.ServiceIODef{{Name: "request", Type: "http.Request", Description: "HTTP request with an optional session cookie"}},
+		Outputs:     []schema.ServiceIODef{{Name: "session", Type: "http.Request", Description: "HTTP request with its session in context"}},
+		ConfigFields: []schema.ConfigFieldDef{
+			{Key: "store", Label: "Store", Type: schema.FieldTypeSelect, Options: []string{"cookie", "memory", "sqlite", "cache"}, DefaultValue: "cookie", Description: "Where session data lives: an encrypted cookie, or server-side with only a random session ID in the cookie"},
+			{Key: "secret", Label: "Secret", Type: schema.FieldTypeString, Description: "Key material (at least 32 characters) for encrypting cookie sessions; required for the cookie store", Sensitive: true},
+			{Key: "dbPath", Label: "Database Path", Type: schema.FieldTypeFilePath, DefaultValue: "./data/sessions.db", Description: "SQLite database file of the sqlite store"},
+			{Key: "cache", Label: "Cache Module", Type: schema.FieldTypeString, Description: "Name of the cache module (e.g. cache.redis) of the cache store", InheritFrom: "dependency.name"},
+			{Key: "cookie", Label: "Cookie", Type: schema.FieldTypeMap, Description: "Cookie attributes: name (wf_session), path (/), domain, secure (true), httpOnly (true), sameSite (lax|strict|none), persistent (false)"},
+			{Key: "idleTimeout", Label: "Idle Timeout", Type: schema.FieldTypeDuration, DefaultValue: "30m", Description: "Ends a session unused for this long"},
+			{Key: "absoluteTimeout", Label: "Absolute Timeout", Type: schema.FieldTypeDuration, DefaultValue: "24h", Description: "Ends a session this long after it was created, however active"},
+			{Key: "rolling", Label: "Rolling", Type: schema.FieldTypeBool, DefaultValue: true, Description: "Renew the idle timeout on every request, not only when the session changes"},
+			{Key: "cleanupInterval", Label: "Cleanup Interval", Type: schema.FieldTypeDuration, DefaultValue: "10m", Description: "How often the memory and sqlite stores drop expired sessions"},
+		},
+		DefaultConfig: map[string]any{"store": "cookie"},
+	}
+}
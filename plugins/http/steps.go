@@ -19,5 +19,17 @@ func stepFactories() map[string]plugin.StepFactory {
 			factory := module.NewCircuitBreakerStepFactory()
 			return factory(name, cfg, app)
 		},
+		"step.session_get": func(name string, cfg map[string]any, app modular.Application) (any, error) {
+			factory := module.NewSessionGetStepFactory()
+			return factory(name, cfg, app)
+		},
+		"step.session_set": func(name string, cfg map[string]any, app modular.Application) (any, error) {
+			factory := module.NewSessionSetStepFactory()
+			return factory(name, cfg, app)
+		},
+		"step.session_destroy": func(name string, cfg map[string]any, app modular.Application) (any, error) {
+			factory := module.NewSessionDestroyStepFactory()
+			return factory(name, cfg, app)
+		},
 	}
 }
@@ -25,6 +25,11 @@ func wiringHooks() []plugin.WiringHook {
 			Priority: 80, // Run before static file server registration
 			Hook:     wireCORSGlobal,
 		},
+		{
+			Name:     "http-session-global-wiring",
+			Priority: 75, // After CORS, so preflights skip sessions
+			Hook:     wireSessionsGlobal,
+		},
 		{
 			Name:     "http-static-fileserver-registration",
 			Priority: 50,
@@ -58,75 +63,100 @@ func wireAuthProviders(app modular.Application, _ *config.WorkflowConfig) error
 // for method-specific routes. The association is determined by dependsOn config; if no router
 // is declared in dependsOn, the CORS middleware is registered on all available routers.
 func wireCORSGlobal(app modular.Application, cfg *config.WorkflowConfig) error {
-	// Build lookup maps: router names and CORS module → dependsOn
-	routerNames := make(map[string]bool)
-	serverToRouter := make(map[string]string)
-	corsDeps := make(map[string][]string) // cors module name → dependsOn
-	for _, modCfg := range cfg.Modules {
-		switch modCfg.Type {
-		case "http.router":
-			routerNames[modCfg.Name] = true
-			for _, dep := range modCfg.DependsOn {
-				serverToRouter[dep] = modCfg.Name
+	targets := newGlobalMiddlewareTargets(app, cfg, "http.middleware.cors")
+	for svcName, svc := range app.SvcRegistry() {
+		if corsMW, ok := svc.(*module.CORSMiddleware); ok {
+			for _, router := range targets.routersFor(svcName) {
+				router.AddGlobalMiddleware(corsMW)
 			}
-		case "http.middleware.cors":
-			corsDeps[modCfg.Name] = modCfg.DependsOn
 		}
 	}
+	return nil
+}
 
-	// Collect all routers from service registry
-	allRouters := make(map[string]*module.StandardHTTPRouter)
+// wireSessionsGlobal registers each http.session module as a global
+// middleware on its associated router, so pipeline routes, which cannot
+// list middlewares, see the session too. Routes that also list the module
+// are not wrapped twice. The router is chosen as for CORS middlewares.
+func wireSessionsGlobal(app modular.Application, cfg *config.WorkflowConfig) error {
+	targets := newGlobalMiddlewareTargets(app, cfg, "http.session")
 	for svcName, svc := range app.SvcRegistry() {
-		if router, ok := svc.(*module.StandardHTTPRouter); ok {
-			allRouters[svcName] = router
+		if sessions, ok := svc.(*module.HTTPSessionModule); ok {
+			for _, router := range targets.routersFor(svcName) {
+				router.AddGlobalMiddleware(sessions)
+			}
 		}
 	}
+	return nil
+}
 
-	if len(allRouters) == 0 {
-		return nil // No routers to wire
-	}
+// globalMiddlewareTargets resolves the routers a global middleware module
+// attaches to from the dependsOn of the modules in the config.
+type globalMiddlewareTargets struct {
+	routerNames    map[string]bool
+	serverToRouter map[string]string
+	deps           map[string][]string // middleware module name → dependsOn
+	routers        map[string]*module.StandardHTTPRouter
+}
 
-	// For each CORS middleware, register it as a global middleware on its target router(s).
+func newGlobalMiddlewareTargets(app modular.Application, cfg *config.WorkflowConfig, moduleType string) *globalMiddlewareTargets {
+	t := &globalMiddlewareTargets{
+		routerNames:    make(map[string]bool),
+		serverToRouter: make(map[string]string),
+		deps:           make(map[string][]string),
+		routers:        make(map[string]*module.StandardHTTPRouter),
+	}
+	for _, modCfg := range cfg.Modules {
+		switch modCfg.Type {
+		case "http.router":
+			t.routerNames[modCfg.Name] = true
+			for _, dep := range modCfg.DependsOn {
+				t.serverToRouter[dep] = modCfg.Name
+			}
+		case moduleType:
+			t.deps[modCfg.Name] = modCfg.DependsOn
+		}
+	}
 	for svcName, svc := range app.SvcRegistry() {
-		corsMW, ok := svc.(*module.CORSMiddleware)
-		if !ok {
-			continue
+		if router, ok := svc.(*module.StandardHTTPRouter); ok {
+			t.routers[svcName] = router
 		}
+	}
+	return t
+}
 
-		deps := corsDeps[svcName]
-		var matched bool
+// routersFor returns the routers of the named middleware module: the routers
+// it depends on, else the routers of the servers it depends on, else all.
+func (t *globalMiddlewareTargets) routersFor(name string) []*module.StandardHTTPRouter {
+	var matched []*module.StandardHTTPRouter
 
-		// 1) Check dependsOn for a direct router reference
-		for _, dep := range deps {
-			if routerNames[dep] {
-				if router, ok := allRouters[dep]; ok {
-					router.AddGlobalMiddleware(corsMW)
-					matched = true
-				}
+	// 1) Check dependsOn for a direct router reference
+	for _, dep := range t.deps[name] {
+		if t.routerNames[dep] {
+			if router, ok := t.routers[dep]; ok {
+				matched = append(matched, router)
 			}
 		}
+	}
 
-		// 2) Check dependsOn for a server reference, then follow server→router
-		if !matched {
-			for _, dep := range deps {
-				if rName, ok := serverToRouter[dep]; ok {
-					if router, ok := allRouters[rName]; ok {
-						router.AddGlobalMiddleware(corsMW)
-						matched = true
-					}
+	// 2) Check dependsOn for a server reference, then follow server→router
+	if len(matched) == 0 {
+		for _, dep := range t.deps[name] {
+			if rName, ok := t.serverToRouter[dep]; ok {
+				if router, ok := t.routers[rName]; ok {
+					matched = append(matched, router)
 				}
 			}
 		}
+	}
 
-		// 3) Fall back: register on all routers
-		if !matched {
-			for _, router := range allRouters {
-				router.AddGlobalMiddleware(corsMW)
-			}
+	// 3) Fall back: register on all routers
+	if len(matched) == 0 {
+		for _, router := range t.routers {
+			matched = append(matched, router)
 		}
 	}
-
-	return nil
+	return matched
 }
 
 // wireStaticFileServers registers static file servers as catch-all routes on their associated routers.
@@ -0,0 +1,42 @@
+package messaging
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow/config"
+	"github.com/GoCodeAlone/workflow/module"
+)
+
+// jobStatusWiringHook adds the job status endpoint of every jobqueue.service
+// with a statusPath to its router: the one it names, else the router whose
+// name sorts first.
+func jobStatusWiringHook(app modular.Application, _ *config.WorkflowConfig) error {
+	for _, match := range module.FindAllByInterface[*module.JobQueueService](app.SvcRegistry()) {
+		svc := match.Service
+		if svc.StatusPath() == "" {
+			continue
+		}
+		var router *module.StandardHTTPRouter
+		if name := svc.RouterName(); name != "" {
+			r, ok := app.SvcRegistry()[name].(*module.StandardHTTPRouter)
+			if !ok {
+				return fmt.Errorf("jobqueue.service %q: router %q is not an http.router module", svc.Name(), name)
+			}
+			router = r
+		} else {
+			r, err := module.FindByInterface[*module.StandardHTTPRouter](app.SvcRegistry())
+			if errors.Is(err, module.ErrServiceNotFound) {
+				return fmt.Errorf("jobqueue.service %q: statusPath is set but no http.router is configured", svc.Name())
+			}
+			router = r.Service
+		}
+		path := svc.StatusPath() + "/{id}"
+		if !router.HasRoute(http.MethodGet, path) {
+			router.AddRoute(http.MethodGet, path, &module.JobStatusHTTPHandler{Handler: svc.ServeJobStatus})
+		}
+	}
+	return nil
+}
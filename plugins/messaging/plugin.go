@@ -40,6 +40,8 @@ func New() *Plugin {
 					"messaging.handler",
 					"messaging.nats",
 					"messaging.kafka",
+					"messaging.topic_registry",
+					"jobqueue.service",
 					"notification.slack",
 					"webhook.sender",
 				},
@@ -106,8 +108,33 @@ func (p *Plugin) ModuleFactories() map[string]plugin.ModuleFactory {
 			if groupID, ok := cfg["groupId"].(string); ok && groupID != "" {
 				kb.SetGroupID(groupID)
 			}
+			consumerCfg, err := module.ParseKafkaConsumerConfig(cfg)
+			if err == nil {
+				err = kb.SetConsumerConfig(consumerCfg)
+			}
+			if err != nil {
+				kb.SetInitErr(err)
+			}
 			return kb
 		},
+		"messaging.topic_registry": func(name string, cfg map[string]any) modular.Module {
+			return module.NewTopicSchemaRegistry(name, module.ParseTopicRegistryConfig(cfg))
+		},
+		"jobqueue.service": func(name string, cfg map[string]any) modular.Module {
+			qc := module.JobQueueConfig{}
+			qc.Backend, _ = cfg["backend"].(string)
+			qc.Storage, _ = cfg["storage"].(string)
+			qc.DBPath, _ = cfg["dbPath"].(string)
+			qc.Workers = intFromConfig(cfg["workers"])
+			qc.MaxAttempts = intFromConfig(cfg["maxAttempts"])
+			qc.PollInterval = durationFromConfig(cfg["pollInterval"])
+			qc.RetryBackoff = durationFromConfig(cfg["retryBackoff"])
+			qc.JobTimeout = durationFromConfig(cfg["jobTimeout"])
+			qc.Retention = durationFromConfig(cfg["retention"])
+			qc.StatusPath, _ = cfg["statusPath"].(string)
+			qc.Router, _ = cfg["router"].(string)
+			return module.NewJobQueueService(name, qc)
+		},
 		"notification.slack": func(name string, _ map[string]any) modular.Module {
 			return module.NewSlackNotification(name)
 		},
@@ -121,6 +148,45 @@ func (p *Plugin) ModuleFactories() map[string]plugin.ModuleFactory {
 	}
 }
 
+// intFromConfig reads an integer config value decoded from YAML or JSON.
+func intFromConfig(v any) int {
+	switch n := v.(type) {
+	case int:
+		return n
+	case float64:
+		return int(n)
+	}
+	return 0
+}
+
+// durationFromConfig reads a duration string config value; invalid or missing
+// values yield zero so the module default applies.
+func durationFromConfig(v any) time.Duration {
+	if s, ok := v.(string); ok {
+		if d, err := time.ParseDuration(s); err == nil {
+			return d
+		}
+	}
+	return 0
+}
+
+// WiringHooks returns the post-init wiring of the topic schema registry and
+// of job status endpoints.
+func (p *Plugin) WiringHooks() []plugin.WiringHook {
+	return []plugin.WiringHook{
+		{
+			Name:     "topic-registry-wiring",
+			Priority: 10,
+			Hook:     topicRegistryWiringHook,
+		},
+		{
+			Name:     "job-status-wiring",
+			Priority: 10,
+			Hook:     jobStatusWiringHook,
+		},
+	}
+}
+
 // TriggerFactories returns trigger constructors for messaging-related triggers.
 func (p *Plugin) TriggerFactories() map[string]plugin.TriggerFactory {
 	return map[string]plugin.TriggerFactory{
@@ -238,7 +304,46 @@ func (p *Plugin) ModuleSchemas() []*schema.ModuleSchema {
 			ConfigFields: []schema.ConfigFieldDef{
 				{Key: "brokers", Label: "Broker Addresses", Type: schema.FieldTypeArray, ArrayItemType: "string", Description: "Kafka broker addresses (e.g. localhost:9092)", Placeholder: "localhost:9092"},
 				{Key: "groupId", Label: "Consumer Group ID", Type: schema.FieldTypeString, Description: "Kafka consumer group identifier", Placeholder: "my-consumer-group"},
+				{Key: "autoOffsetReset", Label: "Auto Offset Reset", Type: schema.FieldTypeSelect, Options: []string{"latest", "earliest"}, DefaultValue: "latest", Description: "Where to start consuming a partition that has no committed offset"},
+				{Key: "commitMode", Label: "Commit Mode", Type: schema.FieldTypeSelect, Options: []string{"auto", "manual-after-handler"}, DefaultValue: "auto", Description: "auto commits every consumed message; manual-after-handler commits only after the handler succeeds and retries failures (at-least-once)"},
+				{Key: "partitions", Label: "Partition Assignment", Type: schema.FieldTypeMap, MapValueType: "array", Description: "Explicit partitions per topic (e.g. orders -> [0, 1] or \"0,1\") instead of consumer group rebalancing; offsets are still committed under groupId", Group: "advanced"},
+			},
+		},
+		{
+			Type:        "messaging.topic_registry",
+			Label:       "Topic Schema Registry",
+			Category:    "messaging",
+			Description: "JSON Schema per message topic, enforced on publish and on delivery, with compatibility-checked schema changes",
+			Outputs:     []schema.ServiceIODef{{Name: "registry", Type: "TopicSchemaRegistry", Description: "Topic schemas checked by step.publish and the message brokers"}},
+			ConfigFields: []schema.ConfigFieldDef{
+				{Key: "topics", Label: "Topics", Type: schema.FieldTypeMap, Required: true, Description: "Map of topic name to {schema, compatibility}; compatibility is none, backward (default) or forward"},
+				{Key: "onInvalidPublish", Label: "On Invalid Publish", Type: schema.FieldTypeSelect, Options: []string{"reject", "warn"}, DefaultValue: "reject", Description: "reject fails the publish; warn logs the violations and publishes anyway"},
+				{Key: "onInvalidDelivery", Label: "On Invalid Delivery", Type: schema.FieldTypeSelect, Options: []string{"dlq", "warn"}, DefaultValue: "dlq", Description: "dlq skips the handler and adds the message with its violations to the dead letter queue; warn logs and delivers"},
+				{Key: "dlq", Label: "Dead Letter Queue", Type: schema.FieldTypeString, Description: "dlq.service module for invalid deliveries (optional when only one is configured)", InheritFrom: "dependency.name"},
+			},
+			DefaultConfig: map[string]any{"onInvalidPublish": "reject", "onInvalidDelivery": "dlq"},
+		},
+		{
+			Type:        "jobqueue.service",
+			Label:       "Job Queue",
+			Category:    "messaging",
+			Description: "Durable background job queue with a worker pool that runs pipelines enqueued by step.enqueue",
+			Inputs:      []schema.ServiceIODef{{Name: "job", Type: "QueuedJob", Description: "Job enqueued by step.enqueue"}},
+			Outputs:     []schema.ServiceIODef{{Name: "queue", Type: "JobQueueService", Description: "Job queue service for step.enqueue"}},
+			ConfigFields: []schema.ConfigFieldDef{
+				{Key: "backend", Label: "Backend", Type: schema.FieldTypeSelect, Options: []string{"sqlite", "memory"}, DefaultValue: "sqlite", Description: "Queue backend; memory is not durable"},
+				{Key: "storage", Label: "Storage", Type: schema.FieldTypeString, Description: "Name of a storage.sqlite module to hold the queue (leave empty for a standalone DB)", InheritFrom: "dependency.name"},
+				{Key: "dbPath", Label: "Database Path", Type: schema.FieldTypeString, DefaultValue: "./data/job-queue.db", Description: "Standalone SQLite database path when storage is not set"},
+				{Key: "workers", Label: "Workers", Type: schema.FieldTypeNumber, DefaultValue: 4, Description: "Number of jobs run concurrently"},
+				{Key: "pollInterval", Label: "Poll Interval", Type: schema.FieldTypeDuration, DefaultValue: "1s", Description: "How often idle workers check for delayed jobs"},
+				{Key: "maxAttempts", Label: "Max Attempts", Type: schema.FieldTypeNumber, DefaultValue: 3, Description: "Runs per job before it is parked as failed"},
+				{Key: "retryBackoff", Label: "Retry Backoff", Type: schema.FieldTypeDuration, DefaultValue: "5s", Description: "Delay before the first retry; doubles per attempt"},
+				{Key: "jobTimeout", Label: "Job Timeout", Type: schema.FieldTypeDuration, DefaultValue: "5m", Description: "Maximum run time for a single job"},
+				{Key: "retention", Label: "Retention", Type: schema.FieldTypeDuration, DefaultValue: "24h", Description: "How long succeeded and failed jobs are kept for status lookups"},
+				{Key: "statusPath", Label: "Status Path", Type: schema.FieldTypeString, Placeholder: "/jobs", Description: "Serve job status at GET <statusPath>/{id} (disabled when empty)"},
+				{Key: "router", Label: "Router", Type: schema.FieldTypeString, Description: "http.router the status endpoint is added to (first router when empty)", InheritFrom: "dependency.name"},
 			},
+			DefaultConfig: map[string]any{"backend": "sqlite", "workers": 4, "maxAttempts": 3},
 		},
 		{
 			Type:        "notification.slack",
@@ -1,11 +1,18 @@
 package messaging
 
 import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/GoCodeAlone/workflow/capability"
+	"github.com/GoCodeAlone/workflow/config"
+	"github.com/GoCodeAlone/workflow/module"
 	"github.com/GoCodeAlone/workflow/plugin"
 	"github.com/GoCodeAlone/workflow/schema"
+	evstore "github.com/GoCodeAlone/workflow/store"
 )
 
 func TestNew(t *testing.T) {
@@ -65,6 +72,8 @@ func TestModuleFactories(t *testing.T) {
 		"messaging.handler",
 		"messaging.nats",
 		"messaging.kafka",
+		"messaging.topic_registry",
+		"jobqueue.service",
 		"notification.slack",
 		"webhook.sender",
 	}
@@ -93,6 +102,9 @@ func TestModuleFactoriesCreateModules(t *testing.T) {
 		{"messaging.handler", map[string]any{}},
 		{"messaging.nats", map[string]any{}},
 		{"messaging.kafka", map[string]any{"brokers": []any{"localhost:9092"}, "groupId": "test-group"}},
+		{"messaging.kafka", map[string]any{"autoOffsetReset": "earliest", "commitMode": "manual-after-handler", "partitions": map[string]any{"orders": []any{0, 1}}}},
+		{"messaging.topic_registry", map[string]any{"topics": map[string]any{"orders.created": map[string]any{"schema": map[string]any{"type": "object"}}}}},
+		{"jobqueue.service", map[string]any{"backend": "memory", "workers": float64(2), "pollInterval": "500ms"}},
 		{"notification.slack", map[string]any{}},
 		{"webhook.sender", map[string]any{"maxRetries": float64(5)}},
 	}
@@ -114,6 +126,16 @@ func TestModuleFactoriesCreateModules(t *testing.T) {
 	}
 }
 
+func TestKafkaFactoryInvalidConsumerConfigFailsInit(t *testing.T) {
+	factory := New().ModuleFactories()["messaging.kafka"]
+	mod := factory("events", map[string]any{"commitMode": "manual"})
+	app, _ := module.NewTestApplication()
+	err := mod.Init(app)
+	if err == nil || !strings.Contains(err.Error(), `commitMode must be "auto" or "manual-after-handler"`) {
+		t.Errorf("Init error = %v, want invalid commitMode", err)
+	}
+}
+
 func TestTriggerFactories(t *testing.T) {
 	p := New()
 	factories := p.TriggerFactories()
@@ -167,6 +189,8 @@ func TestModuleSchemas(t *testing.T) {
 		"messaging.handler":         true,
 		"messaging.nats":            true,
 		"messaging.kafka":           true,
+		"messaging.topic_registry":  true,
+		"jobqueue.service":          true,
 		"notification.slack":        true,
 		"webhook.sender":            true,
 	}
@@ -197,7 +221,7 @@ func TestPluginLoaderIntegration(t *testing.T) {
 
 	// Verify all module factories were loaded
 	moduleFactories := loader.ModuleFactories()
-	expectedModuleCount := 7
+	expectedModuleCount := 9
 	if len(moduleFactories) != expectedModuleCount {
 		t.Errorf("expected %d module factories after load, got %d", expectedModuleCount, len(moduleFactories))
 	}
@@ -213,6 +237,8 @@ func TestManifestModuleTypes(t *testing.T) {
 		"messaging.handler",
 		"messaging.nats",
 		"messaging.kafka",
+		"messaging.topic_registry",
+		"jobqueue.service",
 		"notification.slack",
 		"webhook.sender",
 	}
@@ -354,3 +380,90 @@ func TestPipelineTriggerConfigWrapper_EventBus_WithEventFilter(t *testing.T) {
 		t.Errorf("unexpected event filter: %v", sub["event"])
 	}
 }
+
+func TestTopicRegistryWiringHook(t *testing.T) {
+	app, _ := module.NewTestApplication()
+	registry := module.NewTopicSchemaRegistry("topics", module.ParseTopicRegistryConfig(map[string]any{
+		"topics": map[string]any{
+			"orders.created": map[string]any{"schema": map[string]any{"type": "object", "required": []any{"order_id"}}},
+		},
+	}))
+	if err := registry.Init(app); err != nil {
+		t.Fatal(err)
+	}
+	broker := module.NewInMemoryMessageBroker("events")
+	dlq := module.NewDLQServiceModule("dlq", module.DLQServiceConfig{})
+	for name, svc := range map[string]any{
+		"topics":    registry,
+		"events":    broker,
+		"dlq.store": dlq.Store(),
+	} {
+		if err := app.RegisterService(name, svc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := &config.WorkflowConfig{
+		Workflows: map[string]any{"messaging": map[string]any{
+			"subscriptions": []any{map[string]any{"topic": "orders.created", "handler": "order-handler"}},
+		}},
+		Pipelines: map[string]any{
+			"checkout": map[string]any{"steps": []any{
+				map[string]any{"name": "announce", "type": "step.publish", "config": map[string]any{"topic": "orders.created"}},
+				map[string]any{"name": "per-tenant", "type": "step.publish", "config": map[string]any{"topic": "orders.{{ .tenant }}"}},
+			}},
+			"fulfil": map[string]any{"trigger": map[string]any{"type": "event", "config": map[string]any{"topic": "orders.created"}}},
+		},
+	}
+	if err := topicRegistryWiringHook(app, cfg); err != nil {
+		t.Fatalf("wiring hook: %v", err)
+	}
+
+	if broker.TopicRegistry() != registry {
+		t.Error("registry not attached to the broker")
+	}
+	if _, ok := app.SvcRegistry()[module.TopicRegistryAPIServiceName].(http.Handler); !ok {
+		t.Error("topic registry API not registered")
+	}
+	info, _ := registry.Topic("orders.created")
+	if !slices.Equal(info.Producers, []string{"pipeline:checkout/announce"}) {
+		t.Errorf("producers = %v", info.Producers)
+	}
+	if !slices.Equal(info.Consumers, []string{"handler:order-handler", "pipeline:fulfil"}) {
+		t.Errorf("consumers = %v", info.Consumers)
+	}
+	if registry.AcceptDelivery("orders.created", []byte(`{}`), "events") {
+		t.Error("invalid delivery should be routed to the DLQ")
+	}
+	if n, _ := dlq.Store().Count(context.Background(), evstore.DLQFilter{}); n != 1 {
+		t.Errorf("DLQ entries = %d, want 1", n)
+	}
+}
+
+func TestJobStatusWiringHook(t *testing.T) {
+	app, _ := module.NewTestApplication()
+	router := module.NewStandardHTTPRouter("api")
+	for name, svc := range map[string]any{
+		"api":       router,
+		"jobs":      module.NewJobQueueService("jobs", module.JobQueueConfig{StatusPath: "/jobs"}),
+		"mail-jobs": module.NewJobQueueService("mail-jobs", module.JobQueueConfig{}),
+	} {
+		if err := app.RegisterService(name, svc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := jobStatusWiringHook(app, nil); err != nil {
+		t.Fatalf("wiring hook: %v", err)
+	}
+	if !router.HasRoute(http.MethodGet, "/jobs/{id}") {
+		t.Error("job status route not added to the router")
+	}
+
+	named := module.NewJobQueueService("named", module.JobQueueConfig{StatusPath: "/named", Router: "missing"})
+	if err := app.RegisterService("named", named); err != nil {
+		t.Fatal(err)
+	}
+	if err := jobStatusWiringHook(app, nil); err == nil || !strings.Contains(err.Error(), `router "missing"`) {
+		t.Errorf("expected an error for an unknown router, got %v", err)
+	}
+}
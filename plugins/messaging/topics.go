@@ -0,0 +1,138 @@
+package messaging
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow/config"
+	"github.com/GoCodeAlone/workflow/module"
+	evstore "github.com/GoCodeAlone/workflow/store"
+)
+
+// topicRegistryWiringHook attaches the messaging.topic_registry, if one is
+// configured, to every broker that validates messages, points it at its
+// dead letter queue, records the producers and consumers of each topic
+// found in cfg and registers its admin API.
+func topicRegistryWiringHook(app modular.Application, cfg *config.WorkflowConfig) error {
+	match, err := module.FindByInterface[*module.TopicSchemaRegistry](app.SvcRegistry())
+	if errors.Is(err, module.ErrServiceNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("only one messaging.topic_registry may be configured: %w", err)
+	}
+	registry := match.Service
+
+	if name := registry.DLQName(); name != "" {
+		store, ok := app.SvcRegistry()[name+module.DLQStoreServiceSuffix].(evstore.DLQStore)
+		if !ok {
+			return fmt.Errorf("topic registry %q: dlq %q is not a dlq.service module", registry.Name(), name)
+		}
+		registry.SetDLQStore(store)
+	} else if dlq, err := module.FindByNameSuffixAndType[evstore.DLQStore](app.SvcRegistry(), module.DLQStoreServiceSuffix); err == nil {
+		registry.SetDLQStore(dlq.Service)
+	} else if !errors.Is(err, module.ErrServiceNotFound) {
+		return fmt.Errorf("topic registry %q: set 'dlq' to choose a dead letter queue: %w", registry.Name(), err)
+	}
+
+	for _, broker := range module.FindAllByInterface[module.TopicRegistryAware](app.SvcRegistry()) {
+		broker.Service.SetTopicRegistry(registry)
+	}
+	if cfg != nil {
+		registry.SetEndpoints(DiscoverTopicEndpoints(cfg))
+	}
+	return app.RegisterService(module.TopicRegistryAPIServiceName, module.NewTopicRegistryAPIHandler(registry))
+}
+
+// DiscoverTopicEndpoints lists the producers and consumers of each topic
+// named literally in cfg: messaging workflow subscriptions and producers,
+// event and eventbus trigger subscriptions, pipelines triggered by those
+// triggers, and step.publish steps. Topics built from templates are not
+// listed.
+func DiscoverTopicEndpoints(cfg *config.WorkflowConfig) map[string]module.TopicEndpoints {
+	producers := map[string]map[string]bool{}
+	consumers := map[string]map[string]bool{}
+	add := func(index map[string]map[string]bool, topic, endpoint string) {
+		if topic == "" || strings.Contains(topic, "{{") {
+			return
+		}
+		if index[topic] == nil {
+			index[topic] = map[string]bool{}
+		}
+		index[topic][endpoint] = true
+	}
+
+	if msg, ok := cfg.Workflows["messaging"].(map[string]any); ok {
+		for _, raw := range listOfMaps(msg["subscriptions"]) {
+			topic, _ := raw["topic"].(string)
+			handler, _ := raw["handler"].(string)
+			add(consumers, topic, "handler:"+handler)
+		}
+		for _, raw := range listOfMaps(msg["producers"]) {
+			topic, _ := raw["topic"].(string)
+			name, _ := raw["name"].(string)
+			add(producers, topic, "producer:"+name)
+		}
+	}
+
+	for _, triggerType := range []string{"event", "eventbus"} {
+		trigger, _ := cfg.Triggers[triggerType].(map[string]any)
+		for _, raw := range listOfMaps(trigger["subscriptions"]) {
+			topic, _ := raw["topic"].(string)
+			workflow, _ := raw["workflow"].(string)
+			if !strings.HasPrefix(workflow, "pipeline:") {
+				workflow = "workflow:" + workflow
+			}
+			add(consumers, topic, workflow)
+		}
+	}
+
+	for name, raw := range cfg.Pipelines {
+		pipeline, _ := raw.(map[string]any)
+		if trigger, ok := pipeline["trigger"].(map[string]any); ok {
+			if t, _ := trigger["type"].(string); t == "event" || t == "eventbus" {
+				tc, _ := trigger["config"].(map[string]any)
+				topic, _ := tc["topic"].(string)
+				add(consumers, topic, "pipeline:"+name)
+			}
+		}
+		for _, step := range listOfMaps(pipeline["steps"]) {
+			if t, _ := step["type"].(string); t != "step.publish" {
+				continue
+			}
+			sc, _ := step["config"].(map[string]any)
+			topic, _ := sc["topic"].(string)
+			stepName, _ := step["name"].(string)
+			add(producers, topic, "pipeline:"+name+"/"+stepName)
+		}
+	}
+
+	endpoints := map[string]module.TopicEndpoints{}
+	for topic := range producers {
+		ep := endpoints[topic]
+		ep.Producers = slices.Sorted(maps.Keys(producers[topic]))
+		endpoints[topic] = ep
+	}
+	for topic := range consumers {
+		ep := endpoints[topic]
+		ep.Consumers = slices.Sorted(maps.Keys(consumers[topic]))
+		endpoints[topic] = ep
+	}
+	return endpoints
+}
+
+// listOfMaps returns the map elements of a decoded YAML list.
+func listOfMaps(v any) []map[string]any {
+	list, _ := v.([]any)
+	out := make([]map[string]any, 0, len(list))
+	for _, item := range list {
+		if m, ok := item.(map[string]any); ok {
+			out = append(out, m)
+		}
+	}
+	return out
+}
@@ -16,8 +16,10 @@ func moduleFactories() map[string]plugin.ModuleFactory {
 		"log.collector":        logCollectorFactory,
 		"observability.otel":   otelTracingFactory,
 		"openapi.generator":    openAPIGeneratorFactory,
+		"openapi.consumer":     openAPIConsumerFactory,
 		"http.middleware.otel": otelMiddlewareFactory,
 		"tracing.propagation":  tracePropagationFactory,
+		"alerting.rules":       alertingRulesFactory,
 	}
 }
 
@@ -75,12 +77,48 @@ func logCollectorFactory(name string, cfg map[string]any) modular.Module {
 	if v, ok := cfg["outputFormat"].(string); ok {
 		lcCfg.OutputFormat = v
 	}
-	if v, ok := cfg["retentionDays"].(int); ok {
-		lcCfg.RetentionDays = v
+	if v, ok := configNumber(cfg["retentionDays"]); ok {
+		lcCfg.RetentionDays = int(v)
+	}
+	if v, ok := cfg["storage"].(string); ok {
+		lcCfg.Storage = v
+	}
+	if v, ok := configNumber(cfg["maxEntries"]); ok {
+		lcCfg.MaxEntries = int(v)
+	}
+	if v, ok := cfg["directory"].(string); ok {
+		lcCfg.Directory = v
+	}
+	if v, ok := configNumber(cfg["maxSegmentBytes"]); ok {
+		lcCfg.MaxSegmentBytes = int64(v)
+	}
+	if v, ok := configNumber(cfg["maxSegmentEntries"]); ok {
+		lcCfg.MaxSegmentEntries = int(v)
+	}
+	if v, ok := cfg["maxSegmentAge"].(string); ok {
+		if d, err := time.ParseDuration(v); err == nil {
+			lcCfg.MaxSegmentAge = d
+		}
+	}
+	if v, ok := configNumber(cfg["diskQuotaBytes"]); ok {
+		lcCfg.DiskQuotaBytes = int64(v)
 	}
 	return module.NewLogCollector(name, lcCfg)
 }
 
+// configNumber reads a number decoded from YAML (int) or JSON (float64).
+func configNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
 func otelTracingFactory(name string, cfg map[string]any) modular.Module {
 	m := module.NewOTelTracing(name)
 	if cfg == nil {
@@ -116,6 +154,34 @@ func openAPIGeneratorFactory(name string, cfg map[string]any) modular.Module {
 	return module.NewOpenAPIGenerator(name, genConfig)
 }
 
+func openAPIConsumerFactory(name string, cfg map[string]any) modular.Module {
+	consumerCfg := module.OpenAPIConsumerConfig{}
+	consumerCfg.SpecURL, _ = cfg["specUrl"].(string)
+	consumerCfg.SpecFile, _ = cfg["specFile"].(string)
+	if auth, ok := cfg["auth"].(map[string]any); ok {
+		a := &module.OpenAPIConsumerAuth{}
+		a.Type, _ = auth["type"].(string)
+		a.Token, _ = auth["token"].(string)
+		a.Username, _ = auth["username"].(string)
+		a.Password, _ = auth["password"].(string)
+		a.Name, _ = auth["name"].(string)
+		a.In, _ = auth["in"].(string)
+		a.Value, _ = auth["value"].(string)
+		consumerCfg.Auth = a
+	}
+	c := module.NewOpenAPIConsumer(name, consumerCfg)
+	if mapping, ok := cfg["fieldMapping"].(map[string]any); ok {
+		fm := module.NewFieldMapping()
+		for logical, actual := range mapping {
+			if s, ok := actual.(string); ok {
+				fm.Set(logical, s)
+			}
+		}
+		c.SetFieldMapping(fm)
+	}
+	return c
+}
+
 func otelMiddlewareFactory(name string, cfg map[string]any) modular.Module {
 	serverName := "workflow-http"
 	if v, ok := cfg["serverName"].(string); ok && v != "" {
@@ -127,3 +193,7 @@ func otelMiddlewareFactory(name string, cfg map[string]any) modular.Module {
 func tracePropagationFactory(name string, cfg map[string]any) modular.Module {
 	return module.NewTracePropagationModule(name, cfg)
 }
+
+func alertingRulesFactory(name string, cfg map[string]any) modular.Module {
+	return module.NewAlertingModule(name, cfg)
+}
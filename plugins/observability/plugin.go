@@ -1,7 +1,7 @@
 // Package observability provides an EnginePlugin that contributes all
 // observability-related module types: metrics collector, health checker,
 // log collector, OpenTelemetry tracing, OpenAPI generator/consumer,
-// and distributed trace propagation.
+// distributed trace propagation, and alerting rules.
 package observability
 
 import (
@@ -39,8 +39,10 @@ func New() *ObservabilityPlugin {
 				"log.collector",
 				"observability.otel",
 				"openapi.generator",
+				"openapi.consumer",
 				"http.middleware.otel",
 				"tracing.propagation",
+				"alerting.rules",
 			},
 			StepTypes: []string{
 				"step.trace_start",
@@ -48,12 +50,14 @@ func New() *ObservabilityPlugin {
 				"step.trace_extract",
 				"step.trace_annotate",
 				"step.trace_link",
+				"step.metric",
 			},
 			WiringHooks: []string{
 				"observability.otel-middleware",
 				"observability.health-endpoints",
 				"observability.log-endpoint",
 				"observability.openapi-endpoints",
+				"observability.alert-endpoints",
 			},
 		},
 	}
@@ -96,7 +100,7 @@ func (p *ObservabilityPlugin) ModuleSchemas() []*schema.ModuleSchema {
 	return moduleSchemas()
 }
 
-// StepFactories returns the tracing pipeline step factories.
+// StepFactories returns the tracing and custom metric pipeline step factories.
 func (p *ObservabilityPlugin) StepFactories() map[string]plugin.StepFactory {
 	return map[string]plugin.StepFactory{
 		"step.trace_start": func(name string, cfg map[string]any, app modular.Application) (any, error) {
@@ -114,6 +118,9 @@ func (p *ObservabilityPlugin) StepFactories() map[string]plugin.StepFactory {
 		"step.trace_link": func(name string, cfg map[string]any, app modular.Application) (any, error) {
 			return module.NewTraceLinkStepFactory()(name, cfg, app)
 		},
+		"step.metric": func(name string, cfg map[string]any, app modular.Application) (any, error) {
+			return module.NewMetricStepFactory()(name, cfg, app)
+		},
 	}
 }
 
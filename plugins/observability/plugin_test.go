@@ -40,8 +40,8 @@ func TestManifestValidation(t *testing.T) {
 	if m.Name != "observability" {
 		t.Errorf("manifest Name = %q, want %q", m.Name, "observability")
 	}
-	if len(m.ModuleTypes) != 7 {
-		t.Errorf("manifest ModuleTypes count = %d, want 7", len(m.ModuleTypes))
+	if len(m.ModuleTypes) != 9 {
+		t.Errorf("manifest ModuleTypes count = %d, want 9", len(m.ModuleTypes))
 	}
 }
 
@@ -83,8 +83,10 @@ func TestModuleFactories(t *testing.T) {
 		"log.collector",
 		"observability.otel",
 		"openapi.generator",
+		"openapi.consumer",
 		"http.middleware.otel",
 		"tracing.propagation",
+		"alerting.rules",
 	}
 
 	if len(factories) != len(expectedTypes) {
@@ -159,6 +161,21 @@ func TestModuleFactoriesWithConfig(t *testing.T) {
 		}
 	})
 
+	t.Run("openapi.consumer with config", func(t *testing.T) {
+		mod := factories["openapi.consumer"]("oc", map[string]any{
+			"specFile":     "specs/payments.yaml",
+			"auth":         map[string]any{"type": "apiKey", "name": "X-Key", "in": "query", "value": "secret"},
+			"fieldMapping": map[string]any{"customerId": "customer_id"},
+		})
+		c, ok := mod.(*module.OpenAPIConsumer)
+		if !ok {
+			t.Fatalf("factory returned %T, want *module.OpenAPIConsumer", mod)
+		}
+		if actual := c.GetFieldMapping().Primary("customerId"); actual != "customer_id" {
+			t.Errorf("field mapping customerId = %q, want customer_id", actual)
+		}
+	})
+
 }
 
 func TestModuleSchemas(t *testing.T) {
@@ -171,8 +188,10 @@ func TestModuleSchemas(t *testing.T) {
 		"log.collector":        false,
 		"observability.otel":   false,
 		"openapi.generator":    false,
+		"openapi.consumer":     false,
 		"http.middleware.otel": false,
 		"tracing.propagation":  false,
+		"alerting.rules":       false,
 	}
 
 	if len(schemas) != len(expectedTypes) {
@@ -252,8 +271,8 @@ func TestWiringHooks(t *testing.T) {
 	p := New()
 	hooks := p.WiringHooks()
 
-	if len(hooks) != 5 {
-		t.Fatalf("WiringHooks() count = %d, want 5", len(hooks))
+	if len(hooks) != 6 {
+		t.Fatalf("WiringHooks() count = %d, want 6", len(hooks))
 	}
 
 	expectedNames := map[string]bool{
@@ -262,6 +281,7 @@ func TestWiringHooks(t *testing.T) {
 		"observability.health-endpoints":  false,
 		"observability.log-endpoint":      false,
 		"observability.openapi-endpoints": false,
+		"observability.alert-endpoints":   false,
 	}
 
 	for _, h := range hooks {
@@ -334,6 +354,7 @@ func TestStepFactories(t *testing.T) {
 		"step.trace_extract",
 		"step.trace_annotate",
 		"step.trace_link",
+		"step.metric",
 	}
 	if len(steps) != len(expectedSteps) {
 		t.Errorf("StepFactories() count = %d, want %d", len(steps), len(expectedSteps))
@@ -14,9 +14,9 @@ func moduleSchemas() []*schema.ModuleSchema {
 			ConfigFields: []schema.ConfigFieldDef{
 				{Key: "namespace", Label: "Namespace", Type: schema.FieldTypeString, DefaultValue: "workflow", Description: "Prometheus metric namespace prefix", Placeholder: "workflow"},
 				{Key: "subsystem", Label: "Subsystem", Type: schema.FieldTypeString, Description: "Prometheus metric subsystem", Placeholder: "api"},
-				{Key: "enabledMetrics", Label: "Enabled Metrics", Type: schema.FieldTypeArray, ArrayItemType: "string", DefaultValue: []string{"workflow", "http", "module", "active_workflows"}, Description: "Which metric groups to register (workflow, http, module, active_workflows)"},
+				{Key: "enabledMetrics", Label: "Enabled Metrics", Type: schema.FieldTypeArray, ArrayItemType: "string", DefaultValue: []string{"workflow", "http", "module", "active_workflows", "module_resources"}, Description: "Which metric groups to register (workflow, http, module, active_workflows, module_resources)"},
 			},
-			DefaultConfig: map[string]any{"namespace": "workflow", "enabledMetrics": []string{"workflow", "http", "module", "active_workflows"}},
+			DefaultConfig: map[string]any{"namespace": "workflow", "enabledMetrics": []string{"workflow", "http", "module", "active_workflows", "module_resources"}},
 		},
 		{
 			Type:        "health.checker",
@@ -57,8 +57,15 @@ func moduleSchemas() []*schema.ModuleSchema {
 				{Key: "logLevel", Label: "Log Level", Type: schema.FieldTypeSelect, Options: []string{"debug", "info", "warn", "error"}, DefaultValue: "info", Description: "Minimum log level to collect"},
 				{Key: "outputFormat", Label: "Output Format", Type: schema.FieldTypeSelect, Options: []string{"json", "text"}, DefaultValue: "json", Description: "Format for log output"},
 				{Key: "retentionDays", Label: "Retention Days", Type: schema.FieldTypeNumber, DefaultValue: 7, Description: "Number of days to retain log entries"},
+				{Key: "storage", Label: "Storage", Type: schema.FieldTypeSelect, Options: []string{"memory", "file"}, DefaultValue: "memory", Description: "Keep entries in memory or in rotated JSONL segment files on disk"},
+				{Key: "maxEntries", Label: "Max Entries", Type: schema.FieldTypeNumber, DefaultValue: 10000, Description: "In-memory entry cap; the oldest entries are dropped beyond it"},
+				{Key: "directory", Label: "Directory", Type: schema.FieldTypeFilePath, Description: "Directory of the JSONL segments (file storage)", Placeholder: "data/logs"},
+				{Key: "maxSegmentBytes", Label: "Max Segment Bytes", Type: schema.FieldTypeNumber, DefaultValue: 16777216, Description: "Rotate the active segment once it reaches this size (file storage)", Group: "rotation"},
+				{Key: "maxSegmentEntries", Label: "Max Segment Entries", Type: schema.FieldTypeNumber, Description: "Rotate the active segment once it holds this many entries (file storage)", Group: "rotation"},
+				{Key: "maxSegmentAge", Label: "Max Segment Age", Type: schema.FieldTypeDuration, DefaultValue: "1h", Description: "Rotate the active segment once it is this old (file storage)", Placeholder: "1h", Group: "rotation"},
+				{Key: "diskQuotaBytes", Label: "Disk Quota Bytes", Type: schema.FieldTypeNumber, DefaultValue: 268435456, Description: "Total segment size above which the oldest segments are deleted; -1 disables (file storage)", Group: "rotation"},
 			},
-			DefaultConfig: map[string]any{"logLevel": "info", "outputFormat": "json", "retentionDays": 7},
+			DefaultConfig: map[string]any{"logLevel": "info", "outputFormat": "json", "retentionDays": 7, "storage": "memory"},
 		},
 		{
 			Type:        "openapi.generator",
@@ -75,6 +82,20 @@ func moduleSchemas() []*schema.ModuleSchema {
 			},
 			DefaultConfig: map[string]any{"title": "Workflow API", "version": "1.0.0"},
 		},
+		{
+			Type:        "openapi.consumer",
+			Label:       "OpenAPI Consumer",
+			Category:    "integration",
+			Description: "Parses an external OpenAPI spec and provides a typed HTTP client for calling its operations",
+			Inputs:      []schema.ServiceIODef{{Name: "spec", Type: "OpenAPISpec", Description: "External OpenAPI specification to consume"}},
+			Outputs:     []schema.ServiceIODef{{Name: "client", Type: "ExternalAPIClient", Description: "HTTP client with operations matching the spec"}},
+			ConfigFields: []schema.ConfigFieldDef{
+				{Key: "specUrl", Label: "Spec URL", Type: schema.FieldTypeString, Description: "URL to fetch the OpenAPI spec from", Placeholder: "https://api.example.com/openapi.json"},
+				{Key: "specFile", Label: "Spec File", Type: schema.FieldTypeFilePath, Description: "Local file path to the OpenAPI spec (JSON or YAML)", Placeholder: "specs/external-api.json"},
+				{Key: "fieldMapping", Label: "Field Mapping", Type: schema.FieldTypeMap, MapValueType: "string", Description: "Custom field name mapping between local workflow data and external API schemas", Group: "advanced"},
+				{Key: "auth", Label: "Auth", Type: schema.FieldTypeMap, Description: "Credentials added to every request: {type: bearer, token}, {type: basic, username, password} or {type: apiKey, value, name (default X-API-Key), in: header|query}"},
+			},
+		},
 		{
 			Type:        "http.middleware.otel",
 			Label:       "OTEL HTTP Middleware",
@@ -95,5 +116,24 @@ func moduleSchemas() []*schema.ModuleSchema {
 			},
 			DefaultConfig: map[string]any{"format": "w3c"},
 		},
+		{
+			Type:        "alerting.rules",
+			Label:       "Alerting Rules",
+			Category:    "observability",
+			Description: "Evaluates alert rules on execution error rate and latency, job queue depth and DLQ size, and notifies through pipelines, Slack or webhooks",
+			Inputs:      []schema.ServiceIODef{{Name: "executions", Type: "EventStore", Description: "Execution history the rules are evaluated against"}},
+			Outputs:     []schema.ServiceIODef{{Name: "alerts", Type: "[]AlertStatus", Description: "Alert state served for dashboards"}},
+			ConfigFields: []schema.ConfigFieldDef{
+				{Key: "eventStore", Label: "Event Store", Type: schema.FieldTypeString, Description: "Event store execution metrics are read from (first event store when empty)", InheritFrom: "dependency.name"},
+				{Key: "interval", Label: "Interval", Type: schema.FieldTypeDuration, DefaultValue: "30s", Description: "How often the rules are evaluated"},
+				{Key: "repeatInterval", Label: "Repeat Interval", Type: schema.FieldTypeDuration, Description: "Repeat the notification of a still-firing alert this often (notify once when empty)"},
+				{Key: "dbPath", Label: "Database Path", Type: schema.FieldTypeString, DefaultValue: "./data/alerts.db", Description: "SQLite database alert state is persisted in"},
+				{Key: "path", Label: "API Path", Type: schema.FieldTypeString, Placeholder: "/api/alerts", Description: "Serve alert state at GET <path> and GET <path>/{rule} (disabled when empty)"},
+				{Key: "router", Label: "Router", Type: schema.FieldTypeString, Description: "http.router the API is added to (first router when empty)", InheritFrom: "dependency.name"},
+				{Key: "rules", Label: "Rules", Type: schema.FieldTypeArray, ArrayItemType: "object", Description: "Alert rules, each with name, metric (error_rate, p95_latency, queue_depth, dlq_size), scope (pipeline, route, workflow), target, window, threshold, for, minSamples and actions (pipeline, slack or webhook)"},
+				{Key: "silences", Label: "Silences", Type: schema.FieldTypeArray, ArrayItemType: "object", Description: "Windows that suppress notifications, each with rules (all when empty), start, end and comment"},
+			},
+			DefaultConfig: map[string]any{"interval": "30s", "dbPath": "./data/alerts.db"},
+		},
 	}
 }
@@ -2,6 +2,9 @@ package observability
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/GoCodeAlone/modular"
@@ -38,6 +41,11 @@ func wiringHooks() []plugin.WiringHook {
 			Priority: 40, // run after health/log endpoints so routes are stable
 			Hook:     wireOpenAPIEndpoints,
 		},
+		{
+			Name:     "observability.alert-endpoints",
+			Priority: 50,
+			Hook:     wireAlertEndpoints,
+		},
 		{
 			Name:     "observability.telemetry-bridge",
 			Priority: 10,
@@ -206,3 +214,34 @@ func wireOpenAPIEndpoints(app modular.Application, cfg *config.WorkflowConfig) e
 	}
 	return nil
 }
+
+// wireAlertEndpoints adds the alert state API of every alerting.rules module
+// with a path to its router: the one it names, else the router whose name
+// sorts first.
+func wireAlertEndpoints(app modular.Application, _ *config.WorkflowConfig) error {
+	for _, match := range module.FindAllByInterface[*module.AlertingModule](app.SvcRegistry()) {
+		am := match.Service
+		if am.Path() == "" {
+			continue
+		}
+		var router *module.StandardHTTPRouter
+		if name := am.RouterName(); name != "" {
+			r, ok := app.SvcRegistry()[name].(*module.StandardHTTPRouter)
+			if !ok {
+				return fmt.Errorf("alerting.rules %q: router %q is not an http.router module", am.Name(), name)
+			}
+			router = r
+		} else {
+			r, err := module.FindByInterface[*module.StandardHTTPRouter](app.SvcRegistry())
+			if errors.Is(err, module.ErrServiceNotFound) {
+				return fmt.Errorf("alerting.rules %q: path is set but no http.router is configured", am.Name())
+			}
+			router = r.Service
+		}
+		if !router.HasRoute(http.MethodGet, am.Path()) {
+			router.AddRoute(http.MethodGet, am.Path(), &module.AlertsHTTPHandler{Handler: am.ServeAlerts})
+			router.AddRoute(http.MethodGet, am.Path()+"/{rule}", &module.AlertsHTTPHandler{Handler: am.ServeAlert})
+		}
+	}
+	return nil
+}
@@ -3,7 +3,7 @@
 // http_call, http_proxy, request_parse, db_query, db_exec, db_query_cached, json_response,
 // response,
 // raw_response, json_parse, static_file, validate_path_param, validate_pagination,
-// validate_request_body, foreach, while, webhook_verify, base64_decode, ui_scaffold,
+// validate_request_body, validate_response, schema_validate, foreach, while, webhook_verify, base64_decode, ui_scaffold,
 // ui_scaffold_analyze, dlq_send, dlq_replay, retry_with_backoff, circuit_breaker (wrapping),
 // auth_validate, authz_check, token_revoke, sandbox_exec.
 // It also provides the PipelineWorkflowHandler for composable pipelines.
@@ -44,7 +44,7 @@ func New() *Plugin {
 			BaseNativePlugin: plugin.BaseNativePlugin{
 				PluginName:        "pipeline-steps",
 				PluginVersion:     "1.0.0",
-				PluginDescription: "Generic pipeline step types (validate, transform, conditional, set, log, delegate, jq, base64_decode, validate_path_param, validate_pagination, validate_request_body, foreach, while, webhook_verify, etc.)",
+				PluginDescription: "Generic pipeline step types (validate, transform, conditional, set, log, delegate, jq, base64_decode, validate_path_param, validate_pagination, validate_request_body, validate_response, foreach, while, webhook_verify, etc.)",
 			},
 			Manifest: plugin.PluginManifest{
 				Name:        "pipeline-steps",
@@ -52,18 +52,23 @@ func New() *Plugin {
 				Author:      "GoCodeAlone",
 				Description: "Generic pipeline step types, pre-processing validators, and pipeline workflow handler (including base64_decode)",
 				Tier:        plugin.TierCore,
-				ModuleTypes: []string{"sandbox.remote_runners"},
+				ModuleTypes: []string{"sandbox.remote_runners", "schema.registry"},
 				StepTypes: []string{
 					"step.validate",
 					"step.transform",
 					"step.conditional",
+					"step.assert",
 					"step.set",
 					"step.log",
+					"step.audit",
 					"step.delegate",
+					"step.service_call",
 					"step.jq",
 					"step.publish",
+					"step.enqueue",
 					"step.event_publish",
 					"step.http_call",
+					"step.api_call",
 					"step.request_parse",
 					"step.db_query",
 					"step.db_exec",
@@ -73,13 +78,18 @@ func New() *Plugin {
 					"step.json_response",
 					"step.response",
 					"step.raw_response",
+					"step.pdf_render",
 					"step.pipeline_output",
 					"step.json_parse",
+					"step.csv_parse",
 					"step.static_file",
 					"step.workflow_call",
 					"step.validate_path_param",
 					"step.validate_pagination",
 					"step.validate_request_body",
+					"step.validate_response",
+					"step.schema_validate",
+					"step.localize",
 					"step.foreach",
 					"step.while",
 					"step.webhook_verify",
@@ -105,6 +115,8 @@ func New() *Plugin {
 					"step.cli_invoke",
 					"step.parallel",
 					"step.branch",
+					"step.switch",
+					"step.retry",
 					"step.graphql",
 					"step.event_decrypt",
 					"step.secret_fetch",
@@ -136,6 +148,9 @@ func (p *Plugin) ModuleFactories() map[string]plugin.ModuleFactory {
 		"sandbox.remote_runners": func(name string, cfg map[string]any) modular.Module {
 			return module.NewSandboxRemoteRunnersModule(name, cfg)
 		},
+		"schema.registry": func(name string, cfg map[string]any) modular.Module {
+			return module.NewSchemaRegistry(name, module.ParseSchemaRegistryConfig(cfg))
+		},
 	}
 }
 
@@ -145,13 +160,18 @@ func (p *Plugin) StepFactories() map[string]plugin.StepFactory {
 		"step.validate":              wrapStepFactory(module.NewValidateStepFactory()),
 		"step.transform":             wrapStepFactory(module.NewTransformStepFactory()),
 		"step.conditional":           wrapStepFactory(module.NewConditionalStepFactory()),
+		"step.assert":                wrapStepFactory(module.NewAssertStepFactory()),
 		"step.set":                   wrapStepFactory(module.NewSetStepFactory()),
 		"step.log":                   wrapStepFactory(module.NewLogStepFactory()),
+		"step.audit":                 wrapStepFactory(module.NewAuditStepFactory()),
 		"step.delegate":              wrapStepFactory(module.NewDelegateStepFactory()),
+		"step.service_call":          wrapStepFactory(module.NewServiceCallStepFactory()),
 		"step.jq":                    wrapStepFactory(module.NewJQStepFactory()),
 		"step.publish":               wrapStepFactory(module.NewPublishStepFactory()),
+		"step.enqueue":               wrapStepFactory(module.NewEnqueueStepFactory()),
 		"step.event_publish":         wrapStepFactory(module.NewEventPublishStepFactory()),
 		"step.http_call":             wrapStepFactory(module.NewHTTPCallStepFactory()),
+		"step.api_call":              wrapStepFactory(module.NewAPICallStepFactory()),
 		"step.request_parse":         wrapStepFactory(module.NewRequestParseStepFactory()),
 		"step.db_query":              wrapStepFactory(module.NewDBQueryStepFactory()),
 		"step.db_exec":               wrapStepFactory(module.NewDBExecStepFactory()),
@@ -161,12 +181,17 @@ func (p *Plugin) StepFactories() map[string]plugin.StepFactory {
 		"step.json_response":         wrapStepFactory(module.NewJSONResponseStepFactory()),
 		"step.response":              wrapStepFactory(module.NewJSONResponseStepFactory()),
 		"step.raw_response":          wrapStepFactory(module.NewRawResponseStepFactory()),
+		"step.pdf_render":            wrapStepFactory(module.NewPDFRenderStepFactory()),
 		"step.pipeline_output":       wrapStepFactory(module.NewPipelineOutputStepFactory()),
 		"step.json_parse":            wrapStepFactory(module.NewJSONParseStepFactory()),
+		"step.csv_parse":             wrapStepFactory(module.NewCSVParseStepFactory()),
 		"step.static_file":           wrapStepFactory(module.NewStaticFileStepFactory()),
 		"step.validate_path_param":   wrapStepFactory(module.NewValidatePathParamStepFactory()),
 		"step.validate_pagination":   wrapStepFactory(module.NewValidatePaginationStepFactory()),
 		"step.validate_request_body": wrapStepFactory(module.NewValidateRequestBodyStepFactory()),
+		"step.validate_response":     wrapStepFactory(module.NewValidateResponseStepFactory()),
+		"step.schema_validate":       wrapStepFactory(module.NewSchemaValidateStepFactory()),
+		"step.localize":              wrapStepFactory(module.NewLocalizeStepFactory()),
 		// step.foreach uses a lazy registry getter so it can reference any registered step type,
 		// including types registered by other plugins loaded after this one.
 		"step.foreach": wrapStepFactory(module.NewForEachStepFactory(func() *module.StepRegistry {
@@ -211,6 +236,14 @@ func (p *Plugin) StepFactories() map[string]plugin.StepFactory {
 		"step.branch": wrapStepFactory(module.NewBranchStepFactory(func() *module.StepRegistry {
 			return p.concreteStepRegistry
 		})),
+		// step.switch uses a lazy registry getter so case sub-steps can reference any registered type.
+		"step.switch": wrapStepFactory(module.NewSwitchStepFactory(func() *module.StepRegistry {
+			return p.concreteStepRegistry
+		})),
+		// step.retry uses a lazy registry getter so child steps can reference any registered type.
+		"step.retry": wrapStepFactory(module.NewRetryStepFactory(func() *module.StepRegistry {
+			return p.concreteStepRegistry
+		})),
 		"step.graphql":       wrapStepFactory(module.NewGraphQLStepFactory()),
 		"step.event_decrypt": wrapStepFactory(module.NewEventDecryptStepFactory()),
 		"step.secret_fetch":  wrapStepFactory(module.NewSecretFetchStepFactory()),
@@ -34,14 +34,19 @@ func TestStepFactories(t *testing.T) {
 		"step.validate",
 		"step.transform",
 		"step.conditional",
+		"step.assert",
 		"step.set",
 		"step.log",
+		"step.audit",
 		"step.delegate",
+		"step.service_call",
 		"step.jq",
 		"step.publish",
+		"step.enqueue",
 		"step.event_publish",
 		"step.event_decrypt",
 		"step.http_call",
+		"step.api_call",
 		"step.request_parse",
 		"step.db_query",
 		"step.db_exec",
@@ -51,12 +56,17 @@ func TestStepFactories(t *testing.T) {
 		"step.json_response",
 		"step.response",
 		"step.raw_response",
+		"step.pdf_render",
 		"step.pipeline_output",
 		"step.json_parse",
+		"step.csv_parse",
 		"step.static_file",
 		"step.validate_path_param",
 		"step.validate_pagination",
 		"step.validate_request_body",
+		"step.validate_response",
+		"step.schema_validate",
+		"step.localize",
 		"step.foreach",
 		"step.while",
 		"step.webhook_verify",
@@ -85,6 +95,8 @@ func TestStepFactories(t *testing.T) {
 		"step.secret_fetch",
 		"step.secret_set",
 		"step.branch",
+		"step.switch",
+		"step.retry",
 	}
 
 	for _, stepType := range expectedSteps {
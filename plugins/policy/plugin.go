@@ -1,7 +1,9 @@
-// Package policy provides an EnginePlugin that registers the policy.mock module
-// type and policy pipeline step types (step.policy_evaluate, step.policy_load,
-// step.policy_list, step.policy_test). For OPA or Cedar backends, use the
-// workflow-plugin-policy-opa or workflow-plugin-policy-cedar external plugins.
+// Package policy provides an EnginePlugin that registers the policy.mock and
+// policy.service module types and policy pipeline step types
+// (step.policy_evaluate, step.policy_check, step.policy_load,
+// step.policy_list, step.policy_test). policy.service evaluates Rego policies
+// with embedded OPA. For Cedar, use the workflow-plugin-policy-cedar external
+// plugin.
 package policy
 
 import (
@@ -24,19 +26,21 @@ func New() *Plugin {
 			BaseNativePlugin: plugin.BaseNativePlugin{
 				PluginName:        "policy",
 				PluginVersion:     "1.0.0",
-				PluginDescription: "Policy engine plugin providing mock backend; OPA and Cedar via external plugins",
+				PluginDescription: "Policy engine plugin providing mock and OPA/Rego backends; Cedar via external plugin",
 			},
 			Manifest: plugin.PluginManifest{
 				Name:        "policy",
 				Version:     "1.0.0",
 				Author:      "GoCodeAlone",
-				Description: "Policy engine plugin with mock backend for testing and development",
+				Description: "Policy engine plugin with OPA/Rego evaluation and a mock backend for testing",
 				Tier:        plugin.TierCore,
 				ModuleTypes: []string{
 					"policy.mock",
+					"policy.service",
 				},
 				StepTypes: []string{
 					"step.policy_evaluate",
+					"step.policy_check",
 					"step.policy_load",
 					"step.policy_list",
 					"step.policy_test",
@@ -54,14 +58,13 @@ func (p *Plugin) Capabilities() []capability.Contract {
 	return []capability.Contract{
 		{
 			Name:        "policy-enforcement",
-			Description: "Policy evaluation using mock backend for testing and development",
+			Description: "Policy evaluation using embedded OPA/Rego or a mock backend for testing",
 		},
 	}
 }
 
-// ModuleFactories returns factories for policy.mock.
-// For OPA or Cedar backends, use the workflow-plugin-policy-opa or
-// workflow-plugin-policy-cedar external plugins.
+// ModuleFactories returns factories for policy.mock and policy.service.
+// For a Cedar backend, use the workflow-plugin-policy-cedar external plugin.
 func (p *Plugin) ModuleFactories() map[string]plugin.ModuleFactory {
 	return map[string]plugin.ModuleFactory{
 		"policy.mock": func(name string, cfg map[string]any) modular.Module {
@@ -71,6 +74,13 @@ func (p *Plugin) ModuleFactories() map[string]plugin.ModuleFactory {
 			cfg["backend"] = "mock"
 			return module.NewPolicyEngineModule(name, cfg)
 		},
+		"policy.service": func(name string, cfg map[string]any) modular.Module {
+			if cfg == nil {
+				cfg = map[string]any{}
+			}
+			cfg["backend"] = "opa"
+			return module.NewPolicyEngineModule(name, cfg)
+		},
 	}
 }
 
@@ -80,6 +90,9 @@ func (p *Plugin) StepFactories() map[string]plugin.StepFactory {
 		"step.policy_evaluate": func(name string, cfg map[string]any, app modular.Application) (any, error) {
 			return module.NewPolicyEvaluateStepFactory()(name, cfg, app)
 		},
+		"step.policy_check": func(name string, cfg map[string]any, app modular.Application) (any, error) {
+			return module.NewPolicyCheckStepFactory()(name, cfg, app)
+		},
 		"step.policy_load": func(name string, cfg map[string]any, app modular.Application) (any, error) {
 			return module.NewPolicyLoadStepFactory()(name, cfg, app)
 		},
@@ -108,5 +121,23 @@ func (p *Plugin) ModuleSchemas() []*schema.ModuleSchema {
 			Outputs:      []schema.ServiceIODef{{Name: "decision", Type: "PolicyDecision", Description: "Policy decision"}},
 			ConfigFields: sharedPolicyFields,
 		},
+		policyServiceSchema(),
+	}
+}
+
+func policyServiceSchema() *schema.ModuleSchema {
+	return &schema.ModuleSchema{
+		Type:        "policy.service",
+		Label:       "OPA Policy Service",
+		Category:    "security",
+		Description: "Compiles Rego policies and evaluates them with embedded OPA. Queries are partially evaluated against the policies and data once and cached until either changes.",
+		Inputs:      []schema.ServiceIODef{{Name: "input", Type: "PolicyInput", Description: "Evaluation input"}},
+		Outputs:     []schema.ServiceIODef{{Name: "decision", Type: "PolicyDecision", Description: "Policy decision"}},
+		ConfigFields: []schema.ConfigFieldDef{
+			{Key: "policies", Label: "Policies", Type: schema.FieldTypeArray, ArrayItemType: "object", Description: "Rego modules to compile at startup, each with a name and inline content or a file path: [{\"name\": \"authz\", \"file\": \"policies/authz.rego\"}]"},
+			{Key: "data", Label: "Data", Type: schema.FieldTypeMap, Description: "Data document available to policies as data"},
+			{Key: "dataFile", Label: "Data File", Type: schema.FieldTypeFilePath, Description: "JSON or YAML data document; keys in data take precedence"},
+			{Key: "decision", Label: "Default Decision", Type: schema.FieldTypeString, Description: "Package path evaluated by step.policy_evaluate and step.authz_check (e.g. authz)", Placeholder: "authz"},
+		},
 	}
 }
@@ -32,6 +32,7 @@ func TestStepFactories(t *testing.T) {
 
 	expectedSteps := []string{
 		"step.policy_evaluate",
+		"step.policy_check",
 		"step.policy_load",
 		"step.policy_list",
 		"step.policy_test",
@@ -54,6 +55,7 @@ func TestModuleFactories(t *testing.T) {
 
 	expectedModules := []string{
 		"policy.mock",
+		"policy.service",
 	}
 
 	for _, modType := range expectedModules {
@@ -74,10 +74,14 @@ func (p *Plugin) PipelineTriggerConfigWrappers() map[string]plugin.TriggerConfig
 		"schedule": func(pipelineName string, cfg map[string]any) map[string]any {
 			job := map[string]any{
 				"workflow": "pipeline:" + pipelineName,
+				"action":   "execute",
 			}
 			if c, ok := cfg["cron"]; ok {
 				job["cron"] = c
 			}
+			if tz, ok := cfg["timezone"]; ok {
+				job["timezone"] = tz
+			}
 			return map[string]any{
 				"jobs": []any{job},
 			}
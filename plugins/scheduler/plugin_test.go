@@ -85,3 +85,24 @@ func TestPluginLoads(t *testing.T) {
 		t.Fatalf("expected 1 trigger factory after load, got %d", len(triggers))
 	}
 }
+
+func TestPipelineTriggerConfigWrapper(t *testing.T) {
+	wrap := New().PipelineTriggerConfigWrappers()["schedule"]
+	got := wrap("nightly", map[string]any{"cron": "0 2 * * *", "timezone": "Europe/Berlin"})
+	jobs, _ := got["jobs"].([]any)
+	if len(jobs) != 1 {
+		t.Fatalf("expected 1 job, got %v", got)
+	}
+	job := jobs[0].(map[string]any)
+	want := map[string]any{
+		"workflow": "pipeline:nightly",
+		"action":   "execute",
+		"cron":     "0 2 * * *",
+		"timezone": "Europe/Berlin",
+	}
+	for k, v := range want {
+		if job[k] != v {
+			t.Errorf("job[%q] = %v, want %v", k, job[k], v)
+		}
+	}
+}
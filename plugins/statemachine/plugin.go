@@ -5,12 +5,17 @@ import (
 
 	"github.com/GoCodeAlone/modular"
 	"github.com/GoCodeAlone/workflow/capability"
+	"github.com/GoCodeAlone/workflow/config"
 	"github.com/GoCodeAlone/workflow/handlers"
 	"github.com/GoCodeAlone/workflow/module"
 	"github.com/GoCodeAlone/workflow/plugin"
 	"github.com/GoCodeAlone/workflow/schema"
 )
 
+// APIServiceName is the service name of the state machine visualization
+// API handler registered by the plugin's wiring hook.
+const APIServiceName = module.StateMachineAPIServiceName
+
 // Plugin provides state machine workflow capabilities: statemachine.engine,
 // state.tracker, state.connector modules and the statemachine workflow handler.
 type Plugin struct {
@@ -102,6 +107,36 @@ func (p *Plugin) WorkflowHandlers() map[string]plugin.WorkflowHandlerFactory {
 	}
 }
 
+// WiringHooks returns the hook that registers the state machine
+// visualization API handler over the application's engines.
+func (p *Plugin) WiringHooks() []plugin.WiringHook {
+	return []plugin.WiringHook{
+		{
+			Name:     "statemachine-api-wiring",
+			Priority: 10,
+			Hook:     stateMachineAPIWiringHook,
+		},
+	}
+}
+
+// stateMachineAPIWiringHook registers a StateMachineAPIHandler serving the
+// definitions, stats and instances of every statemachine.engine in app. Apps
+// without an engine get no handler.
+func stateMachineAPIWiringHook(app modular.Application, _ *config.WorkflowConfig) error {
+	if len(module.FindAllByInterface[*module.StateMachineEngine](app.SvcRegistry())) == 0 {
+		return nil
+	}
+	handler := module.NewStateMachineAPIHandler(func() []*module.StateMachineEngine {
+		matches := module.FindAllByInterface[*module.StateMachineEngine](app.SvcRegistry())
+		engines := make([]*module.StateMachineEngine, 0, len(matches))
+		for _, m := range matches {
+			engines = append(engines, m.Service)
+		}
+		return engines
+	})
+	return app.RegisterService(APIServiceName, handler)
+}
+
 // StepFactories returns the pipeline step factories for state machine operations.
 func (p *Plugin) StepFactories() map[string]plugin.StepFactory {
 	return map[string]plugin.StepFactory{
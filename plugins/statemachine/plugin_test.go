@@ -1,8 +1,13 @@
 package statemachine
 
 import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/GoCodeAlone/modular"
+	"github.com/GoCodeAlone/workflow/module"
 	"github.com/GoCodeAlone/workflow/plugin"
 )
 
@@ -135,3 +140,34 @@ func TestModuleSchemas(t *testing.T) {
 		}
 	}
 }
+
+func TestWiringHookRegistersAPI(t *testing.T) {
+	hooks := New().WiringHooks()
+	if len(hooks) != 1 || hooks[0].Name != "statemachine-api-wiring" {
+		t.Fatalf("unexpected wiring hooks: %+v", hooks)
+	}
+
+	app := modular.NewStdApplication(modular.NewStdConfigProvider(nil), slog.Default())
+	if err := hooks[0].Hook(app, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := app.SvcRegistry()[APIServiceName]; ok {
+		t.Fatal("the API should not be registered without a state machine engine")
+	}
+	engine := module.NewStateMachineEngine("orders-sm")
+	if err := app.RegisterService(engine.Name(), engine); err != nil {
+		t.Fatal(err)
+	}
+	if err := hooks[0].Hook(app, nil); err != nil {
+		t.Fatal(err)
+	}
+	handler, ok := app.SvcRegistry()[APIServiceName].(http.Handler)
+	if !ok {
+		t.Fatalf("service %q is not an http.Handler", APIServiceName)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/statemachine/definitions", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("definitions: status %d: %s", rec.Code, rec.Body.String())
+	}
+}
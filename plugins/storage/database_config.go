@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/GoCodeAlone/workflow/module"
+)
+
+// parseDatabaseConfig reads a database.workflow config map. Numbers may be
+// ints (YAML) or float64s (JSON); durations are strings such as "30m".
+func parseDatabaseConfig(cfg map[string]any) module.DatabaseConfig {
+	dbConfig := module.DatabaseConfig{}
+	dbConfig.Driver, _ = cfg["driver"].(string)
+	dbConfig.DSN, _ = cfg["dsn"].(string)
+	dbConfig.MaxOpenConns = configInt(cfg["maxOpenConns"])
+	dbConfig.MaxIdleConns = configInt(cfg["maxIdleConns"])
+	dbConfig.ConnMaxLifetime = configDuration(cfg["connMaxLifetime"])
+	dbConfig.ConnMaxIdleTime = configDuration(cfg["connMaxIdleTime"])
+	dbConfig.ReplicaHealthInterval = configDuration(cfg["replicaHealthInterval"])
+
+	replicas, _ := cfg["replicas"].([]any)
+	for _, item := range replicas {
+		switch r := item.(type) {
+		case string:
+			dbConfig.Replicas = append(dbConfig.Replicas, module.DatabaseReplicaConfig{DSN: r})
+		case map[string]any:
+			rc := module.DatabaseReplicaConfig{}
+			rc.Name, _ = r["name"].(string)
+			rc.DSN, _ = r["dsn"].(string)
+			dbConfig.Replicas = append(dbConfig.Replicas, rc)
+		}
+	}
+	return dbConfig
+}
+
+func configInt(v any) int {
+	switch n := v.(type) {
+	case int:
+		return n
+	case int64:
+		return int(n)
+	case float64:
+		return int(n)
+	}
+	return 0
+}
+
+// configDuration parses a duration string, returning 0 when it is missing or
+// invalid.
+func configDuration(v any) time.Duration {
+	s, _ := v.(string)
+	d, _ := time.ParseDuration(s)
+	return d
+}
+
+// parseQueryInsightsConfig converts the raw YAML config map of a
+// database.query_insights module into a module.DBQueryInsightsConfig.
+func parseQueryInsightsConfig(cfg map[string]any) module.DBQueryInsightsConfig {
+	insightsCfg := module.DefaultDBQueryInsightsConfig()
+	insightsCfg.Databases = configStrings(cfg["databases"])
+	if rate, ok := configFloat(cfg["sampleRate"]); ok {
+		insightsCfg.SampleRate = rate
+	}
+	if rate, ok := configFloat(cfg["slowSampleRate"]); ok {
+		insightsCfg.SlowSampleRate = rate
+	}
+	if d := configDuration(cfg["slowThreshold"]); d > 0 {
+		insightsCfg.SlowThreshold = d
+	}
+	if d := configDuration(cfg["retention"]); d > 0 {
+		insightsCfg.Retention = d
+	}
+	if n := configInt(cfg["maxSlowQueries"]); n > 0 {
+		insightsCfg.MaxSlowQueries = n
+	}
+	if n := configInt(cfg["maxStatements"]); n > 0 {
+		insightsCfg.MaxStatements = n
+	}
+	if explain, ok := cfg["explain"].(bool); ok {
+		insightsCfg.Explain = explain
+	}
+	insightsCfg.RedactPatterns = configStrings(cfg["redactPatterns"])
+	return insightsCfg
+}
+
+func configFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func configStrings(v any) []string {
+	items, _ := v.([]any)
+	var out []string
+	for _, item := range items {
+		if s, ok := item.(string); ok && s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -1,8 +1,11 @@
 package storage
 
 import (
+	"reflect"
 	"testing"
+	"time"
 
+	"github.com/GoCodeAlone/workflow/module"
 	"github.com/GoCodeAlone/workflow/plugin"
 )
 
@@ -21,8 +24,8 @@ func TestPluginManifest(t *testing.T) {
 	if m.Name != "storage" {
 		t.Errorf("expected name %q, got %q", "storage", m.Name)
 	}
-	if len(m.ModuleTypes) != 7 {
-		t.Errorf("expected 7 module types, got %d", len(m.ModuleTypes))
+	if len(m.ModuleTypes) != 8 {
+		t.Errorf("expected 8 module types, got %d", len(m.ModuleTypes))
 	}
 	if len(m.StepTypes) != 4 {
 		t.Errorf("expected 4 step types, got %d", len(m.StepTypes))
@@ -52,8 +55,8 @@ func TestModuleFactories(t *testing.T) {
 
 	expectedTypes := []string{
 		"storage.local",
-		"storage.sqlite", "database.workflow", "persistence.store",
-		"cache.redis",
+		"storage.sqlite", "database.workflow", "database.query_insights",
+		"persistence.store", "cache.redis",
 	}
 	for _, typ := range expectedTypes {
 		factory, ok := factories[typ]
@@ -103,6 +106,61 @@ func TestModuleFactoryWithConfig(t *testing.T) {
 
 }
 
+func TestParseDatabaseConfig(t *testing.T) {
+	got := parseDatabaseConfig(map[string]any{
+		"driver":                "pgx",
+		"dsn":                   "postgres://primary/app",
+		"maxOpenConns":          20,
+		"maxIdleConns":          float64(4),
+		"connMaxLifetime":       "30m",
+		"connMaxIdleTime":       "5m",
+		"replicaHealthInterval": "15s",
+		"replicas": []any{
+			map[string]any{"name": "east", "dsn": "postgres://east/app"},
+			"postgres://west/app",
+		},
+	})
+	want := module.DatabaseConfig{
+		Driver:                "pgx",
+		DSN:                   "postgres://primary/app",
+		MaxOpenConns:          20,
+		MaxIdleConns:          4,
+		ConnMaxLifetime:       30 * time.Minute,
+		ConnMaxIdleTime:       5 * time.Minute,
+		ReplicaHealthInterval: 15 * time.Second,
+		Replicas: []module.DatabaseReplicaConfig{
+			{Name: "east", DSN: "postgres://east/app"},
+			{DSN: "postgres://west/app"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseDatabaseConfig() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseQueryInsightsConfig(t *testing.T) {
+	got := parseQueryInsightsConfig(map[string]any{
+		"databases":      []any{"orders-db"},
+		"sampleRate":     0.1,
+		"slowThreshold":  "200ms",
+		"slowSampleRate": 0,
+		"explain":        false,
+		"maxStatements":  float64(50),
+		"redactPatterns": []any{"ssn"},
+	})
+	want := module.DefaultDBQueryInsightsConfig()
+	want.Databases = []string{"orders-db"}
+	want.SampleRate = 0.1
+	want.SlowThreshold = 200 * time.Millisecond
+	want.SlowSampleRate = 0
+	want.Explain = false
+	want.MaxStatements = 50
+	want.RedactPatterns = []string{"ssn"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseQueryInsightsConfig() = %+v, want %+v", got, want)
+	}
+}
+
 func TestStepFactories(t *testing.T) {
 	p := New()
 	stepFactories := p.StepFactories()
@@ -115,8 +173,8 @@ func TestStepFactories(t *testing.T) {
 func TestModuleSchemas(t *testing.T) {
 	p := New()
 	schemas := p.ModuleSchemas()
-	if len(schemas) != 7 {
-		t.Fatalf("expected 7 module schemas, got %d", len(schemas))
+	if len(schemas) != 8 {
+		t.Fatalf("expected 8 module schemas, got %d", len(schemas))
 	}
 
 	types := map[string]bool{}
@@ -126,7 +184,7 @@ func TestModuleSchemas(t *testing.T) {
 	expectedTypes := []string{
 		"storage.local",
 		"storage.sqlite", "database.workflow", "database.partitioned",
-		"persistence.store", "cache.redis",
+		"database.query_insights", "persistence.store", "cache.redis",
 	}
 	for _, expected := range expectedTypes {
 		if !types[expected] {
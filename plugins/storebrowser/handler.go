@@ -342,6 +342,9 @@ func (h *handler) listEvents(w http.ResponseWriter, r *http.Request) {
 	if status := r.URL.Query().Get("status"); status != "" {
 		filter.Status = status
 	}
+	if requestID := r.URL.Query().Get("request_id"); requestID != "" {
+		filter.RequestID = requestID
+	}
 
 	executions, err := h.eventStore.ListExecutions(r.Context(), filter)
 	if err != nil {
@@ -9,6 +9,14 @@ import (
 	evstore "github.com/GoCodeAlone/workflow/store"
 )
 
+// Service name suffixes under which a timeline.service module named <name>
+// registers its timeline, replay and backfill handler muxes.
+const (
+	TimelineServiceSuffix = module.TimelineServiceSuffix
+	ReplayServiceSuffix   = module.ReplayServiceSuffix
+	BackfillServiceSuffix = module.BackfillServiceSuffix
+)
+
 // Plugin registers the timeline.service module type.
 type Plugin struct {
 	plugin.BaseEnginePlugin
@@ -62,6 +70,13 @@ type deferredTimelineModule struct {
 func (m *deferredTimelineModule) Name() string { return m.name }
 
 func (m *deferredTimelineModule) Init(app modular.Application) error {
+	// Prefer the tiered store of an event store with tiering, so timelines
+	// read through to archived executions.
+	if tiered, ok := app.SvcRegistry()[m.eventStoreName+module.TieredEventStoreServiceSuffix].(*evstore.TieredEventStore); ok {
+		m.inner = module.NewTimelineServiceModule(m.name, tiered)
+		return nil
+	}
+
 	// Look up the event store from the service registry
 	var store *evstore.SQLiteEventStore
 	if err := app.GetService(m.eventStoreName, &store); err != nil || store == nil {
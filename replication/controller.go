@@ -0,0 +1,538 @@
+package replication
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Role is a server's replication role.
+type Role string
+
+// Replication roles.
+const (
+	// RolePrimary serves traffic and snapshots.
+	RolePrimary Role = "primary"
+	// RoleStandby replicates from the primary and waits for promotion.
+	RoleStandby Role = "standby"
+	// RolePromoting has claimed the primary leases and is starting its engines.
+	RolePromoting Role = "promoting"
+	// RoleFenced was primary but lost its primary lease.
+	RoleFenced Role = "fenced"
+)
+
+// Replication states reported in Status.
+const (
+	StateServing   = "serving"
+	StateInSync    = "in_sync"
+	StateStale     = "stale"
+	StatePromoting = "promoting"
+	StateFenced    = "fenced"
+)
+
+// ErrNotStandby is returned when promoting a server that is not a standby.
+var ErrNotStandby = errors.New("server is not a standby")
+
+// Options configures a Controller.
+type Options struct {
+	// PrimaryURL is the base URL of the primary's replication API. A standby
+	// without one replicates nothing, e.g. when its state is in shared
+	// PostgreSQL.
+	PrimaryURL string
+	// Token authorizes snapshot requests to the primary.
+	Token string
+	// Interval is how often a standby fetches snapshots. Default 15s.
+	Interval time.Duration
+	// MaxLag is the lag above which a standby is stale. Default 1m.
+	MaxLag time.Duration
+	// Client fetches snapshots. Default: a client with a 5 minute timeout.
+	Client *http.Client
+}
+
+// StoreStatus is the replication state of one store on a standby.
+type StoreStatus struct {
+	Store string `json:"store"`
+	// SyncedAt is when the standby last fetched the store; its data is at
+	// least as recent as this.
+	SyncedAt time.Time `json:"syncedAt,omitzero"`
+	// AppliedAt is when a changed snapshot was last applied.
+	AppliedAt time.Time `json:"appliedAt,omitzero"`
+	SHA256    string    `json:"sha256,omitempty"`
+	Bytes     int64     `json:"bytes,omitempty"`
+	Failures  int64     `json:"failures"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Status is a server's replication state. Ready is what a load balancer
+// health check should route on.
+type Status struct {
+	Role  Role   `json:"role"`
+	State string `json:"state"`
+	Ready bool   `json:"ready"`
+	// Summary is a one-line description, e.g. "standby, in sync, lag=3s".
+	Summary string `json:"summary"`
+	// LagSeconds is the age of the least recently synced store; -1 until
+	// every store has synced. Only reported by a standby.
+	LagSeconds    float64       `json:"lagSeconds"`
+	MaxLagSeconds float64       `json:"maxLagSeconds,omitempty"`
+	PrimaryURL    string        `json:"primaryURL,omitempty"`
+	Stores        []StoreStatus `json:"stores,omitempty"`
+	Leases        []string      `json:"leases"`
+	PromotedAt    time.Time     `json:"promotedAt,omitzero"`
+	Error         string        `json:"error,omitempty"`
+}
+
+// replica is a standby's copy of one store.
+type replica struct {
+	db     *sql.DB
+	status StoreStatus
+}
+
+// Controller tracks a server's replication role. A standby replicates the
+// primary's stores into its replicas until promoted; a primary serves
+// snapshots from its Source. Leases, when set, keep two servers from acting
+// as primary at once.
+type Controller struct {
+	opts   Options
+	logger *slog.Logger
+	source *Source
+	leases *Leases
+
+	mu         sync.RWMutex
+	role       Role
+	ready      bool
+	replicas   map[string]*replica
+	promotedAt time.Time
+	lastErr    string
+	onFenced   func()
+
+	syncMu    sync.Mutex // serializes sync rounds
+	stopCh    chan struct{}
+	loopDone  chan struct{}
+	promoteCh chan struct{} // closed once leases are claimed for promotion
+	activeCh  chan error    // receives the engine start result after promotion
+	now       func() time.Time
+}
+
+// NewController creates a controller in the given role. A primary is ready
+// once MarkActive is called.
+func NewController(role Role, opts Options, logger *slog.Logger) *Controller {
+	if opts.Interval <= 0 {
+		opts.Interval = 15 * time.Second
+	}
+	if opts.MaxLag <= 0 {
+		opts.MaxLag = time.Minute
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 5 * time.Minute}
+	}
+	opts.PrimaryURL = strings.TrimRight(opts.PrimaryURL, "/")
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Controller{
+		opts:      opts,
+		logger:    logger,
+		source:    NewSource(),
+		role:      role,
+		replicas:  make(map[string]*replica),
+		promoteCh: make(chan struct{}),
+		activeCh:  make(chan error, 1),
+		now:       time.Now,
+	}
+}
+
+// Source returns the snapshot source a primary serves.
+func (c *Controller) Source() *Source { return c.source }
+
+// SetLeases sets the leases claimed on promotion and held by a primary.
+// A lost primary lease fences the server: it stops being ready and the
+// OnFenced function is called.
+func (c *Controller) SetLeases(l *Leases) {
+	c.leases = l
+	l.OnLost(func(name string, _ error) {
+		if name != LeasePrimary {
+			return
+		}
+		c.mu.Lock()
+		c.role = RoleFenced
+		c.ready = false
+		c.lastErr = "primary lease lost"
+		onFenced := c.onFenced
+		c.mu.Unlock()
+		if onFenced != nil {
+			onFenced()
+		}
+	})
+}
+
+// OnFenced sets the function called when the server loses its primary
+// lease, which should stop it from serving.
+func (c *Controller) OnFenced(fn func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onFenced = fn
+}
+
+// Leases returns the controller's leases, or nil.
+func (c *Controller) Leases() *Leases { return c.leases }
+
+// AddReplica registers a standby's copy of a store to replicate into.
+func (c *Controller) AddReplica(name string, db *sql.DB) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.replicas[name] = &replica{db: db, status: StoreStatus{Store: name}}
+}
+
+// Role returns the current role.
+func (c *Controller) Role() Role {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.role
+}
+
+// ClaimPrimary claims the primary leases for a server starting as primary.
+// It fails with ErrLeaseHeld when another server, such as a promoted
+// standby, is primary.
+func (c *Controller) ClaimPrimary(ctx context.Context) error {
+	if c.leases == nil {
+		return nil
+	}
+	return c.leases.Claim(ctx, PrimaryLeases...)
+}
+
+// MarkActive records that the primary's engines are serving.
+func (c *Controller) MarkActive() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.role = RolePrimary
+	c.ready = true
+	c.lastErr = ""
+}
+
+// Start begins replicating on a standby with a primary URL.
+func (c *Controller) Start() {
+	if c.Role() != RoleStandby || c.opts.PrimaryURL == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stopCh != nil {
+		return
+	}
+	c.stopCh = make(chan struct{})
+	c.loopDone = make(chan struct{})
+	go c.loop(c.stopCh, c.loopDone)
+}
+
+// Stop ends replication and releases held leases.
+func (c *Controller) Stop() {
+	c.stopLoop()
+	if c.leases != nil {
+		c.leases.Release()
+	}
+}
+
+func (c *Controller) stopLoop() {
+	c.mu.Lock()
+	stopCh, done := c.stopCh, c.loopDone
+	c.stopCh, c.loopDone = nil, nil
+	c.mu.Unlock()
+	if stopCh != nil {
+		close(stopCh)
+		<-done
+	}
+}
+
+func (c *Controller) loop(stop, done chan struct{}) {
+	defer close(done)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	_ = c.Sync(ctx)
+	ticker := time.NewTicker(c.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			_ = c.Sync(ctx)
+		}
+	}
+}
+
+// Sync fetches every replica's store from the primary once. Stores are
+// applied independently; the first error is returned.
+func (c *Controller) Sync(ctx context.Context) error {
+	c.syncMu.Lock()
+	defer c.syncMu.Unlock()
+
+	c.mu.RLock()
+	names := make([]string, 0, len(c.replicas))
+	for name := range c.replicas {
+		names = append(names, name)
+	}
+	c.mu.RUnlock()
+	slices.Sort(names)
+
+	var firstErr error
+	for _, name := range names {
+		if err := c.syncStore(ctx, name); err != nil {
+			c.logger.Warn("Replication sync failed", "store", name, "error", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// syncStore fetches one store's snapshot and applies it when it changed.
+func (c *Controller) syncStore(ctx context.Context, name string) error {
+	c.mu.RLock()
+	r := c.replicas[name]
+	known := r.status.SHA256
+	c.mu.RUnlock()
+
+	started := c.now()
+	applied, size, sum, err := c.fetch(ctx, name, r.db, known)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		r.status.Failures++
+		r.status.Error = err.Error()
+		return err
+	}
+	r.status.Error = ""
+	r.status.SyncedAt = started
+	if applied {
+		r.status.AppliedAt = c.now()
+		r.status.SHA256 = sum
+		r.status.Bytes = size
+	}
+	return nil
+}
+
+// fetch downloads a store's snapshot from the primary and applies it. A
+// snapshot matching known is not downloaded again.
+func (c *Controller) fetch(ctx context.Context, name string, db *sql.DB, known string) (applied bool, size int64, sum string, err error) {
+	u := c.opts.PrimaryURL + "/api/v1/replication/snapshots/" + url.PathEscape(name)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return false, 0, "", err
+	}
+	if c.opts.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.opts.Token)
+	}
+	if known != "" {
+		req.Header.Set("If-None-Match", `"`+known+`"`)
+	}
+	resp, err := c.opts.Client.Do(req) //nolint:gosec // G107: operator-configured primary URL
+	if err != nil {
+		return false, 0, "", err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return false, 0, "", nil
+	case http.StatusOK:
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return false, 0, "", fmt.Errorf("primary returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	f, err := os.CreateTemp("", "workflow-replica-*.db")
+	if err != nil {
+		return false, 0, "", err
+	}
+	defer os.Remove(f.Name())
+	h := sha256.New()
+	size, err = io.Copy(io.MultiWriter(f, h), resp.Body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return false, 0, "", fmt.Errorf("download snapshot: %w", err)
+	}
+	sum = hex.EncodeToString(h.Sum(nil))
+	if want := strings.Trim(resp.Header.Get("ETag"), `"`); want != "" && want != sum {
+		return false, 0, "", fmt.Errorf("snapshot checksum mismatch: got %s, want %s", sum, want)
+	}
+	if err := Apply(ctx, db, f.Name()); err != nil {
+		return false, 0, "", fmt.Errorf("apply snapshot: %w", err)
+	}
+	return true, size, sum, nil
+}
+
+// Promote turns a standby into the primary. It stops replication, makes a
+// last attempt to sync, and claims the primary leases, failing with
+// ErrLeaseHeld while the old primary still holds them. It then signals
+// Promoted and waits until the server reports its engines started with
+// Activated.
+func (c *Controller) Promote(ctx context.Context) error {
+	c.mu.Lock()
+	if c.role != RoleStandby {
+		role := c.role
+		c.mu.Unlock()
+		return fmt.Errorf("%w (role %s)", ErrNotStandby, role)
+	}
+	c.role = RolePromoting
+	replicating := c.stopCh != nil
+	c.mu.Unlock()
+
+	c.stopLoop()
+	if c.opts.PrimaryURL != "" {
+		// The primary is usually gone; a failed final sync still promotes
+		// with the last replicated state.
+		syncCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		if err := c.Sync(syncCtx); err != nil {
+			c.logger.Warn("Final sync before promotion failed; promoting with the last replicated state", "error", err)
+		}
+		cancel()
+	}
+
+	if c.leases != nil {
+		if err := c.leases.Claim(ctx, PrimaryLeases...); err != nil {
+			c.mu.Lock()
+			c.role = RoleStandby
+			c.lastErr = err.Error()
+			c.mu.Unlock()
+			if replicating {
+				c.Start()
+			}
+			return err
+		}
+	}
+
+	c.mu.Lock()
+	c.promotedAt = c.now()
+	c.mu.Unlock()
+	close(c.promoteCh)
+	c.logger.Info("Standby promoted; starting engines")
+
+	select {
+	case err := <-c.activeCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Promoted is closed once a promotion has claimed the primary leases.
+func (c *Controller) Promoted() <-chan struct{} { return c.promoteCh }
+
+// Activated reports the result of starting the engines after promotion.
+// On success the server becomes a ready primary.
+func (c *Controller) Activated(err error) {
+	if err == nil {
+		c.MarkActive()
+	} else {
+		c.mu.Lock()
+		c.lastErr = err.Error()
+		c.mu.Unlock()
+	}
+	select {
+	case c.activeCh <- err:
+	default:
+	}
+}
+
+// Status returns the current replication state.
+func (c *Controller) Status() Status {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	st := Status{
+		Role:       c.role,
+		Ready:      c.ready,
+		PromotedAt: c.promotedAt,
+		Error:      c.lastErr,
+		Leases:     []string{},
+	}
+	if c.leases != nil {
+		st.Leases = c.leases.Held()
+	}
+	switch c.role {
+	case RolePrimary:
+		st.State = StateServing
+		st.Summary = "primary, serving"
+	case RolePromoting:
+		st.State = StatePromoting
+		st.Summary = "promoting"
+	case RoleFenced:
+		st.State = StateFenced
+		st.Summary = "fenced, primary lease lost"
+	case RoleStandby:
+		st.PrimaryURL = c.opts.PrimaryURL
+		st.MaxLagSeconds = c.opts.MaxLag.Seconds()
+		lag, synced := c.lagLocked()
+		for _, name := range c.replicaNamesLocked() {
+			st.Stores = append(st.Stores, c.replicas[name].status)
+		}
+		switch {
+		case !synced:
+			st.State = StateStale
+			st.LagSeconds = -1
+			st.Summary = "standby, stale, not yet synced"
+		case lag > c.opts.MaxLag:
+			st.State = StateStale
+			st.LagSeconds = lag.Seconds()
+			st.Summary = fmt.Sprintf("standby, stale, lag=%s", lag.Round(time.Second))
+		default:
+			st.State = StateInSync
+			st.LagSeconds = lag.Seconds()
+			st.Summary = fmt.Sprintf("standby, in sync, lag=%s", lag.Round(time.Second))
+		}
+	}
+	return st
+}
+
+// lagLocked returns the age of the least recently synced replica, and
+// whether every replica has synced. A standby with nothing to replicate has
+// no lag.
+func (c *Controller) lagLocked() (time.Duration, bool) {
+	var oldest time.Time
+	for _, r := range c.replicas {
+		if r.status.SyncedAt.IsZero() {
+			return 0, false
+		}
+		if oldest.IsZero() || r.status.SyncedAt.Before(oldest) {
+			oldest = r.status.SyncedAt
+		}
+	}
+	if oldest.IsZero() {
+		return 0, true
+	}
+	return c.now().Sub(oldest), true
+}
+
+func (c *Controller) replicaNamesLocked() []string {
+	names := make([]string, 0, len(c.replicas))
+	for name := range c.replicas {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
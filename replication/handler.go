@@ -0,0 +1,154 @@
+package replication
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Handler serves the replication API of a primary or standby: health and
+// readiness probes, status, lag metrics, snapshots and promotion.
+type Handler struct {
+	c       *Controller
+	token   string
+	logger  *slog.Logger
+	metrics http.Handler
+}
+
+// NewHandler creates a replication API handler. Snapshot and promotion
+// requests must carry token as a bearer token; without a token they are
+// refused.
+func NewHandler(c *Controller, token string, logger *slog.Logger) *Handler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(NewCollector(c))
+	return &Handler{
+		c:       c,
+		token:   token,
+		logger:  logger,
+		metrics: promhttp.HandlerFor(reg, promhttp.HandlerOpts{}),
+	}
+}
+
+// RegisterRoutes registers the replication API on mux.
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /healthz", h.healthz)
+	mux.HandleFunc("GET /readyz", h.readyz)
+	mux.Handle("GET /metrics", h.metrics)
+	mux.HandleFunc("GET /api/v1/replication/status", h.status)
+	mux.HandleFunc("GET /api/v1/replication/snapshots/{store}", h.snapshot)
+	mux.HandleFunc("POST /api/v1/replication/promote", h.promote)
+}
+
+// healthz reports liveness: the process is up, whatever its role.
+func (h *Handler) healthz(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// readyz answers 200 only while the server is a serving primary, so a load
+// balancer health check routes to whichever server is primary.
+func (h *Handler) readyz(w http.ResponseWriter, _ *http.Request) {
+	st := h.c.Status()
+	code := http.StatusOK
+	if !st.Ready {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, st)
+}
+
+func (h *Handler) status(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, h.c.Status())
+}
+
+// snapshot streams a consistent copy of a store. The ETag is the snapshot's
+// SHA-256; a matching If-None-Match gets 304.
+func (h *Handler) snapshot(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+	if role := h.c.Role(); role != RolePrimary {
+		writeError(w, http.StatusConflict, "not the primary (role "+string(role)+")")
+		return
+	}
+	name := r.PathValue("store")
+	if !slices.Contains(h.c.Source().Stores(), name) {
+		writeError(w, http.StatusNotFound, "unknown store "+name)
+		return
+	}
+	snap, err := h.c.Source().Snapshot(r.Context(), name)
+	if err != nil {
+		h.logger.Error("Replication snapshot failed", "store", name, "error", err)
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer func() { _ = snap.Remove() }()
+
+	etag := `"` + snap.SHA256 + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("X-Snapshot-Time", snap.TakenAt.Format(time.RFC3339Nano))
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	f, err := snap.Open()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer f.Close()
+	w.Header().Set("Content-Type", "application/vnd.sqlite3")
+	w.Header().Set("Content-Length", strconv.FormatInt(snap.Size, 10))
+	_, _ = io.Copy(w, f)
+}
+
+// promote promotes a standby and answers once its engines are started.
+func (h *Handler) promote(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+	err := h.c.Promote(r.Context())
+	switch {
+	case err == nil:
+		writeJSON(w, http.StatusOK, h.c.Status())
+	case errors.Is(err, ErrNotStandby), errors.Is(err, ErrLeaseHeld):
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// authorize checks the bearer token of a snapshot or promotion request.
+func (h *Handler) authorize(w http.ResponseWriter, r *http.Request) bool {
+	if h.token == "" {
+		writeError(w, http.StatusForbidden, "replication token not configured")
+		return false
+	}
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(h.token)) != 1 {
+		writeError(w, http.StatusUnauthorized, "invalid replication token")
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, map[string]string{"error": msg})
+}
@@ -0,0 +1,197 @@
+package replication
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/scale"
+)
+
+// Leases claimed by the primary. The primary lease guards against two
+// primaries; the scheduler and retention leases guard the singleton work
+// only a primary does.
+const (
+	LeasePrimary   = "primary"
+	LeaseScheduler = "scheduler"
+	LeaseRetention = "retention"
+)
+
+// PrimaryLeases are the leases a server claims to act as primary.
+var PrimaryLeases = []string{LeasePrimary, LeaseScheduler, LeaseRetention}
+
+// ErrLeaseHeld is returned when another server holds a lease.
+var ErrLeaseHeld = errors.New("lease held by another server")
+
+// heldLease is one acquired lease.
+type heldLease struct {
+	renew   func(context.Context) error
+	release func()
+}
+
+// Leases holds named singleton leases through a scale.DistributedLock. With
+// a scale.RenewableLock (Redis) the leases expire after their TTL and are
+// renewed while held; other locks (PostgreSQL advisory locks) are held by
+// their session until released.
+type Leases struct {
+	lock   scale.DistributedLock
+	prefix string
+	ttl    time.Duration
+	logger *slog.Logger
+
+	mu     sync.Mutex
+	held   map[string]heldLease
+	onLost func(name string, err error)
+	stopCh chan struct{}
+	done   chan struct{}
+}
+
+// NewLeases creates a lease set. Keys are prefix + lease name. ttl defaults
+// to 15s.
+func NewLeases(lock scale.DistributedLock, prefix string, ttl time.Duration, logger *slog.Logger) *Leases {
+	if ttl <= 0 {
+		ttl = 15 * time.Second
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Leases{lock: lock, prefix: prefix, ttl: ttl, logger: logger, held: make(map[string]heldLease)}
+}
+
+// OnLost sets the function called when a held lease cannot be renewed. The
+// lease is dropped before it is called.
+func (l *Leases) OnLost(fn func(name string, err error)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.onLost = fn
+}
+
+// Claim acquires the named leases that are not already held, all or none.
+// It fails with ErrLeaseHeld when another server holds one of them.
+func (l *Leases) Claim(ctx context.Context, names ...string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	renewable, _ := l.lock.(scale.RenewableLock)
+	acquired := map[string]heldLease{}
+	rollback := func() {
+		for _, h := range acquired {
+			h.release()
+		}
+	}
+	for _, name := range names {
+		if _, ok := l.held[name]; ok {
+			continue
+		}
+		key := l.prefix + name
+		var h heldLease
+		var ok bool
+		var err error
+		if renewable != nil {
+			h.renew, h.release, ok, err = renewable.TryAcquireRenewable(ctx, key, l.ttl)
+		} else {
+			// Session-held locks need no TTL; a TTL would release them.
+			h.release, ok, err = l.lock.TryAcquire(ctx, key, 0)
+		}
+		if err != nil {
+			rollback()
+			return fmt.Errorf("claim %s lease: %w", name, err)
+		}
+		if !ok {
+			rollback()
+			return fmt.Errorf("claim %s lease: %w", name, ErrLeaseHeld)
+		}
+		acquired[name] = h
+	}
+	for name, h := range acquired {
+		l.held[name] = h
+	}
+	if renewable != nil && l.stopCh == nil && len(l.held) > 0 {
+		l.stopCh = make(chan struct{})
+		l.done = make(chan struct{})
+		go l.renewLoop(l.stopCh, l.done)
+	}
+	return nil
+}
+
+// Held returns the names of the held leases, sorted.
+func (l *Leases) Held() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	names := make([]string, 0, len(l.held))
+	for name := range l.held {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Release stops renewing and releases every held lease.
+func (l *Leases) Release() {
+	l.mu.Lock()
+	stopCh, done := l.stopCh, l.done
+	l.stopCh, l.done = nil, nil
+	held := l.held
+	l.held = make(map[string]heldLease)
+	l.mu.Unlock()
+
+	if stopCh != nil {
+		close(stopCh)
+		<-done
+	}
+	for _, h := range held {
+		h.release()
+	}
+}
+
+// renewLoop renews the held leases at a third of their TTL.
+func (l *Leases) renewLoop(stop, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			l.renew()
+		}
+	}
+}
+
+// renew extends every held lease, dropping and reporting those lost.
+func (l *Leases) renew() {
+	l.mu.Lock()
+	held := make(map[string]heldLease, len(l.held))
+	for name, h := range l.held {
+		held[name] = h
+	}
+	onLost := l.onLost
+	l.mu.Unlock()
+
+	for name, h := range held {
+		ctx, cancel := context.WithTimeout(context.Background(), l.ttl/3)
+		err := h.renew(ctx)
+		cancel()
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, scale.ErrLockLost) {
+			// The lock backend may be briefly unreachable; the lease is
+			// only lost once it has expired.
+			l.logger.Warn("Failed to renew lease", "lease", name, "error", err)
+			continue
+		}
+		l.mu.Lock()
+		delete(l.held, name)
+		l.mu.Unlock()
+		l.logger.Error("Lost lease", "lease", name, "error", err)
+		if onLost != nil {
+			onLost(name, err)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package replication
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector exports a controller's replication state as Prometheus metrics.
+type Collector struct {
+	c *Controller
+
+	role      *prometheus.Desc
+	ready     *prometheus.Desc
+	lag       *prometheus.Desc
+	storeLag  *prometheus.Desc
+	lastSync  *prometheus.Desc
+	failures  *prometheus.Desc
+	snapBytes *prometheus.Desc
+}
+
+// NewCollector creates a collector for c.
+func NewCollector(c *Controller) *Collector {
+	return &Collector{
+		c: c,
+		role: prometheus.NewDesc("workflow_replication_role",
+			"Replication role of this server (1 for the current role).", []string{"role"}, nil),
+		ready: prometheus.NewDesc("workflow_replication_ready",
+			"Whether this server is a serving primary.", nil, nil),
+		lag: prometheus.NewDesc("workflow_replication_lag_seconds",
+			"Age of the least recently synced store on a standby; -1 until every store has synced.", nil, nil),
+		storeLag: prometheus.NewDesc("workflow_replication_store_lag_seconds",
+			"Age of a store's last successful sync on a standby.", []string{"store"}, nil),
+		lastSync: prometheus.NewDesc("workflow_replication_last_sync_timestamp_seconds",
+			"Unix time of a store's last successful sync on a standby.", []string{"store"}, nil),
+		failures: prometheus.NewDesc("workflow_replication_sync_failures_total",
+			"Failed sync attempts of a store on a standby.", []string{"store"}, nil),
+		snapBytes: prometheus.NewDesc("workflow_replication_snapshot_bytes",
+			"Size of a store's last applied snapshot on a standby.", []string{"store"}, nil),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (m *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- m.role
+	ch <- m.ready
+	ch <- m.lag
+	ch <- m.storeLag
+	ch <- m.lastSync
+	ch <- m.failures
+	ch <- m.snapBytes
+}
+
+// Collect implements prometheus.Collector.
+func (m *Collector) Collect(ch chan<- prometheus.Metric) {
+	st := m.c.Status()
+	for _, role := range []Role{RolePrimary, RoleStandby, RolePromoting, RoleFenced} {
+		v := 0.0
+		if st.Role == role {
+			v = 1
+		}
+		ch <- prometheus.MustNewConstMetric(m.role, prometheus.GaugeValue, v, string(role))
+	}
+	ready := 0.0
+	if st.Ready {
+		ready = 1
+	}
+	ch <- prometheus.MustNewConstMetric(m.ready, prometheus.GaugeValue, ready)
+	if st.Role != RoleStandby {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(m.lag, prometheus.GaugeValue, st.LagSeconds)
+	now := m.c.now()
+	for _, s := range st.Stores {
+		ch <- prometheus.MustNewConstMetric(m.failures, prometheus.CounterValue, float64(s.Failures), s.Store)
+		if s.SyncedAt.IsZero() {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(m.storeLag, prometheus.GaugeValue, now.Sub(s.SyncedAt).Seconds(), s.Store)
+		ch <- prometheus.MustNewConstMetric(m.lastSync, prometheus.GaugeValue, float64(s.SyncedAt.UnixNano())/1e9, s.Store)
+		ch <- prometheus.MustNewConstMetric(m.snapBytes, prometheus.GaugeValue, float64(s.Bytes), s.Store)
+	}
+}
@@ -0,0 +1,260 @@
+package replication
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/scale"
+	_ "modernc.org/sqlite"
+)
+
+const testSchema = `
+CREATE TABLE workflows (id TEXT PRIMARY KEY, name TEXT NOT NULL);
+CREATE TABLE executions (
+	id TEXT PRIMARY KEY,
+	workflow_id TEXT NOT NULL REFERENCES workflows(id) ON DELETE CASCADE,
+	status TEXT NOT NULL
+);`
+
+func openTestDB(t *testing.T, name string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Exec("PRAGMA foreign_keys = ON"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(testSchema); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func mustExec(t *testing.T, db *sql.DB, q string, args ...any) {
+	t.Helper()
+	if _, err := db.Exec(q, args...); err != nil {
+		t.Fatalf("%s: %v", q, err)
+	}
+}
+
+func count(t *testing.T, db *sql.DB, table string) int {
+	t.Helper()
+	var n int
+	if err := db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+// newPair starts a primary serving db over HTTP and returns a standby
+// replicating it into its own database.
+func newPair(t *testing.T, lock scale.DistributedLock) (primary, standby *Controller, standbyDB *sql.DB, primaryDB *sql.DB) {
+	t.Helper()
+	primaryDB = openTestDB(t, "primary.db")
+	primary = NewController(RolePrimary, Options{}, nil)
+	primary.Source().Add(StoreWorkflows, primaryDB)
+	primary.SetLeases(NewLeases(lock, "test/", time.Second, nil))
+	if err := primary.ClaimPrimary(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	primary.MarkActive()
+	t.Cleanup(primary.Stop)
+
+	mux := http.NewServeMux()
+	NewHandler(primary, "secret", nil).RegisterRoutes(mux)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	standbyDB = openTestDB(t, "standby.db")
+	standby = NewController(RoleStandby, Options{PrimaryURL: srv.URL, Token: "secret", MaxLag: time.Minute}, nil)
+	standby.AddReplica(StoreWorkflows, standbyDB)
+	standby.SetLeases(NewLeases(lock, "test/", time.Second, nil))
+	t.Cleanup(standby.Stop)
+	return primary, standby, standbyDB, primaryDB
+}
+
+func TestController_SyncReplicatesSnapshots(t *testing.T) {
+	ctx := context.Background()
+	_, standby, standbyDB, primaryDB := newPair(t, scale.NewInMemoryLock())
+
+	if st := standby.Status(); st.State != StateStale || st.Ready {
+		t.Fatalf("unsynced standby status = %+v", st)
+	}
+
+	mustExec(t, primaryDB, "INSERT INTO workflows (id, name) VALUES ('w1', 'orders')")
+	mustExec(t, primaryDB, "INSERT INTO executions (id, workflow_id, status) VALUES ('e1', 'w1', 'completed')")
+	// Rows only the standby has are replaced.
+	mustExec(t, standbyDB, "INSERT INTO workflows (id, name) VALUES ('stale', 'old')")
+
+	if err := standby.Sync(ctx); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if count(t, standbyDB, "workflows") != 1 || count(t, standbyDB, "executions") != 1 {
+		t.Fatalf("standby has %d workflows, %d executions", count(t, standbyDB, "workflows"), count(t, standbyDB, "executions"))
+	}
+	st := standby.Status()
+	if st.State != StateInSync || st.Ready || !strings.HasPrefix(st.Summary, "standby, in sync, lag=") {
+		t.Errorf("synced standby status = %+v", st)
+	}
+	applied := st.Stores[0].AppliedAt
+
+	// An unchanged store is not downloaded again but counts as synced.
+	if err := standby.Sync(ctx); err != nil {
+		t.Fatalf("second Sync: %v", err)
+	}
+	if again := standby.Status().Stores[0]; !again.AppliedAt.Equal(applied) || !again.SyncedAt.After(applied) {
+		t.Errorf("unchanged sync = %+v, applied before at %v", again, applied)
+	}
+
+	mustExec(t, primaryDB, "UPDATE executions SET status = 'failed'")
+	if err := standby.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	var status string
+	if err := standbyDB.QueryRow("SELECT status FROM executions WHERE id = 'e1'").Scan(&status); err != nil || status != "failed" {
+		t.Errorf("replicated status = %q, %v", status, err)
+	}
+
+	// Lag past MaxLag makes the standby stale.
+	standby.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	if st := standby.Status(); st.State != StateStale || st.LagSeconds < 60 {
+		t.Errorf("lagging standby status = %+v", st)
+	}
+}
+
+func TestController_SyncRejectsBadToken(t *testing.T) {
+	_, standby, _, _ := newPair(t, scale.NewInMemoryLock())
+	standby.opts.Token = "wrong"
+	if err := standby.Sync(context.Background()); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("Sync with a bad token = %v", err)
+	}
+	if st := standby.Status(); st.Stores[0].Failures != 1 {
+		t.Errorf("failures = %d, want 1", st.Stores[0].Failures)
+	}
+}
+
+func TestController_PromoteNeedsPrimaryLease(t *testing.T) {
+	ctx := context.Background()
+	primary, standby, _, _ := newPair(t, scale.NewInMemoryLock())
+
+	// The primary still holds its leases: no split brain.
+	if err := standby.Promote(ctx); !errors.Is(err, ErrLeaseHeld) {
+		t.Fatalf("Promote while primary holds the lease = %v", err)
+	}
+	if role := standby.Role(); role != RoleStandby {
+		t.Fatalf("role after refused promotion = %s", role)
+	}
+
+	primary.Stop()
+	go func() {
+		<-standby.Promoted()
+		standby.Activated(nil)
+	}()
+	if err := standby.Promote(ctx); err != nil {
+		t.Fatalf("Promote: %v", err)
+	}
+	st := standby.Status()
+	if st.Role != RolePrimary || !st.Ready || len(st.Leases) != len(PrimaryLeases) {
+		t.Errorf("promoted status = %+v", st)
+	}
+	if err := standby.Promote(ctx); !errors.Is(err, ErrNotStandby) {
+		t.Errorf("second Promote = %v", err)
+	}
+}
+
+func TestHandler_ReadinessAndPromotion(t *testing.T) {
+	_, standby, _, _ := newPair(t, scale.NewInMemoryLock())
+	mux := http.NewServeMux()
+	NewHandler(standby, "secret", nil).RegisterRoutes(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	var st Status
+	if err := json.NewDecoder(rec.Body).Decode(&st); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusServiceUnavailable || st.Role != RoleStandby {
+		t.Errorf("standby readyz = %d %+v", rec.Code, st)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/replication/promote", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("promote without token = %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/replication/promote", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("promote while primary holds the lease = %d %s", rec.Code, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	if !strings.Contains(string(body), "workflow_replication_store_lag_seconds{store=\"workflows\"}") ||
+		!strings.Contains(string(body), `workflow_replication_role{role="standby"} 1`) {
+		t.Errorf("metrics = %s", body)
+	}
+}
+
+func TestLeases_LostLeaseFencesPrimary(t *testing.T) {
+	lock := &losingLock{}
+	c := NewController(RolePrimary, Options{}, nil)
+	leases := NewLeases(lock, "test/", 30*time.Millisecond, nil)
+	c.SetLeases(leases)
+	fenced := make(chan struct{})
+	c.OnFenced(func() { close(fenced) })
+	if err := c.ClaimPrimary(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	c.MarkActive()
+	defer c.Stop()
+
+	lock.lose()
+	select {
+	case <-fenced:
+	case <-time.After(2 * time.Second):
+		t.Fatal("primary was not fenced after losing its lease")
+	}
+	if st := c.Status(); st.Role != RoleFenced || st.Ready {
+		t.Errorf("fenced status = %+v", st)
+	}
+}
+
+// losingLock is a renewable lock whose renewals fail once lose is called.
+type losingLock struct {
+	scale.InMemoryLock
+	lost chan struct{}
+}
+
+func (l *losingLock) lose() { close(l.lost) }
+
+func (l *losingLock) TryAcquireRenewable(context.Context, string, time.Duration) (func(context.Context) error, func(), bool, error) {
+	if l.lost == nil {
+		l.lost = make(chan struct{})
+	}
+	renew := func(context.Context) error {
+		select {
+		case <-l.lost:
+			return scale.ErrLockLost
+		default:
+			return nil
+		}
+	}
+	return renew, func() {}, true, nil
+}
@@ -0,0 +1,247 @@
+// Package replication keeps a warm standby workflow server in sync with its
+// primary and promotes it when the primary is lost.
+//
+// The primary serves consistent snapshots of the SQLite stores it cannot
+// share; a standby fetches them on an interval and applies them to its own
+// copies. Leases held through a scale.DistributedLock make sure only one
+// server at a time acts as primary.
+package replication
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Names of the replicated stores.
+const (
+	StoreWorkflows    = "workflows"    // V1 store: companies, projects, workflows, executions
+	StoreEvents       = "events"       // execution event store
+	StoreEnvironments = "environments" // environment management store
+)
+
+// Source serves consistent snapshots of a primary's SQLite databases.
+type Source struct {
+	mu  sync.RWMutex
+	dbs map[string]*sql.DB
+}
+
+// NewSource creates an empty snapshot source.
+func NewSource() *Source {
+	return &Source{dbs: make(map[string]*sql.DB)}
+}
+
+// Add registers a SQLite database under a store name.
+func (s *Source) Add(name string, db *sql.DB) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dbs[name] = db
+}
+
+// Stores returns the registered store names, sorted.
+func (s *Source) Stores() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	names := make([]string, 0, len(s.dbs))
+	for name := range s.dbs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Snapshot is a consistent copy of one store, written to a temporary file.
+type Snapshot struct {
+	Store   string
+	TakenAt time.Time
+	SHA256  string
+	Size    int64
+	path    string
+}
+
+// Open opens the snapshot file for reading.
+func (s *Snapshot) Open() (io.ReadCloser, error) {
+	return os.Open(s.path)
+}
+
+// Remove deletes the snapshot file.
+func (s *Snapshot) Remove() error {
+	return os.RemoveAll(filepath.Dir(s.path))
+}
+
+// Snapshot copies a store with VACUUM INTO, which reads it in a single
+// transaction, so the copy is consistent while writes continue. The caller
+// removes the snapshot when done.
+func (s *Source) Snapshot(ctx context.Context, name string) (*Snapshot, error) {
+	s.mu.RLock()
+	db, ok := s.dbs[name]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown store %q", name)
+	}
+
+	dir, err := os.MkdirTemp("", "workflow-snapshot-")
+	if err != nil {
+		return nil, fmt.Errorf("create snapshot directory: %w", err)
+	}
+	snap := &Snapshot{Store: name, TakenAt: time.Now().UTC(), path: filepath.Join(dir, name+".db")}
+	if _, err := db.ExecContext(ctx, "VACUUM INTO ?", snap.path); err != nil {
+		_ = snap.Remove()
+		return nil, fmt.Errorf("snapshot %s: %w", name, err)
+	}
+
+	f, err := os.Open(snap.path)
+	if err != nil {
+		_ = snap.Remove()
+		return nil, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		_ = snap.Remove()
+		return nil, fmt.Errorf("hash snapshot %s: %w", name, err)
+	}
+	snap.SHA256 = hex.EncodeToString(h.Sum(nil))
+	snap.Size = n
+	return snap, nil
+}
+
+// Apply replaces the contents of db with those of the SQLite snapshot at
+// path, in one transaction. Tables are matched by name and copied over the
+// columns both databases have, so the replica keeps its own schema; tables
+// the snapshot lacks are left as they are.
+func Apply(ctx context.Context, db *sql.DB, path string) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "ATTACH DATABASE ? AS snapshot", path); err != nil {
+		return fmt.Errorf("attach snapshot: %w", err)
+	}
+	defer func() { _, _ = conn.ExecContext(context.Background(), "DETACH DATABASE snapshot") }()
+
+	tables, err := sharedTables(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Foreign keys are checked at commit, once every table is copied.
+	if _, err := tx.ExecContext(ctx, "PRAGMA defer_foreign_keys = ON"); err != nil {
+		return err
+	}
+	// Empty every table before filling any, so cascading deletes cannot
+	// remove rows already copied.
+	for _, t := range tables {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM main."+quoteIdent(t.name)); err != nil {
+			return fmt.Errorf("clear %s: %w", t.name, err)
+		}
+	}
+	for _, t := range tables {
+		cols := make([]string, len(t.columns))
+		for i, c := range t.columns {
+			cols[i] = quoteIdent(c)
+		}
+		list := strings.Join(cols, ", ")
+		q := "INSERT INTO main." + quoteIdent(t.name) + " (" + list + ") SELECT " + list + " FROM snapshot." + quoteIdent(t.name)
+		if _, err := tx.ExecContext(ctx, q); err != nil {
+			return fmt.Errorf("copy %s: %w", t.name, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// sharedTable is a table present in both the replica and the snapshot.
+type sharedTable struct {
+	name    string
+	columns []string
+}
+
+// sharedTables lists the ordinary tables of the attached snapshot that the
+// replica also has, with the columns they share.
+func sharedTables(ctx context.Context, conn *sql.Conn) ([]sharedTable, error) {
+	const q = `SELECT name FROM snapshot.sqlite_master
+		WHERE type = 'table' AND name NOT LIKE 'sqlite_%' AND sql NOT LIKE 'CREATE VIRTUAL TABLE%'
+		ORDER BY name`
+	rows, err := conn.QueryContext(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("list snapshot tables: %w", err)
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var tables []sharedTable
+	for _, name := range names {
+		mainCols, err := tableColumns(ctx, conn, name, "main")
+		if err != nil {
+			return nil, err
+		}
+		if len(mainCols) == 0 {
+			continue
+		}
+		snapCols, err := tableColumns(ctx, conn, name, "snapshot")
+		if err != nil {
+			return nil, err
+		}
+		var cols []string
+		for _, c := range snapCols {
+			if slices.Contains(mainCols, c) {
+				cols = append(cols, c)
+			}
+		}
+		if len(cols) > 0 {
+			tables = append(tables, sharedTable{name: name, columns: cols})
+		}
+	}
+	return tables, nil
+}
+
+// tableColumns returns the column names of a table in the given schema.
+func tableColumns(ctx context.Context, conn *sql.Conn, table, schema string) ([]string, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT name FROM pragma_table_info(?, ?)", table, schema)
+	if err != nil {
+		return nil, fmt.Errorf("columns of %s.%s: %w", schema, table, err)
+	}
+	defer rows.Close()
+	var cols []string
+	for rows.Next() {
+		var c string
+		if err := rows.Scan(&c); err != nil {
+			return nil, err
+		}
+		cols = append(cols, c)
+	}
+	return cols, rows.Err()
+}
+
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
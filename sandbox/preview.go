@@ -0,0 +1,109 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Preview error codes reported alongside a failed preview's message.
+const (
+	PreviewCodeNotAllowed      = "not_allowed"
+	PreviewCodeInvalidConfig   = "invalid_config"
+	PreviewCodeExecutionFailed = "execution_failed"
+	PreviewCodeTimeout         = "timeout"
+)
+
+// ErrPreviewNotAllowed is returned when a step type or component import is
+// rejected by a PreviewPolicy.
+var ErrPreviewNotAllowed = errors.New("not allowed in preview")
+
+// PreviewPolicy decides what may run in a preview: a one-off execution of a
+// single pipeline step or dynamic component, used by editors to show what a
+// step would produce. Previews run outside any pipeline and must not reach
+// databases, networks, brokers, or engine state, so only pure step types and
+// component imports are accepted.
+//
+// The management API's step preview and the dynamic component preview share
+// this policy.
+type PreviewPolicy struct {
+	// AllowedStepTypes lists the step types that can be previewed. Any other
+	// type is rejected.
+	AllowedStepTypes map[string]bool
+	// BlockedImports lists packages a dynamic component may not import to be
+	// previewed, on top of the loader's own import allowlist.
+	BlockedImports map[string]bool
+	// Timeout bounds a single preview execution.
+	Timeout time.Duration
+	// MaxRequestBytes bounds the size of a preview request body.
+	MaxRequestBytes int64
+}
+
+// DefaultPreviewPolicy returns the policy used by the management API: data
+// shaping, validation, routing, and template rendering steps only, components
+// without network or filesystem access, and a 5s timeout.
+func DefaultPreviewPolicy() PreviewPolicy {
+	return PreviewPolicy{
+		AllowedStepTypes: map[string]bool{
+			"step.jq":            true,
+			"step.transform":     true,
+			"step.validate":      true,
+			"step.set":           true,
+			"step.conditional":   true,
+			"step.json_response": true,
+			"step.json_parse":    true,
+			"step.regex_match":   true,
+			"step.hash":          true,
+		},
+		BlockedImports: map[string]bool{
+			"net":          true,
+			"net/http":     true,
+			"os":           true,
+			"os/exec":      true,
+			"database/sql": true,
+			"syscall":      true,
+			"unsafe":       true,
+		},
+		Timeout:         5 * time.Second,
+		MaxRequestBytes: 1 << 20,
+	}
+}
+
+// CheckStepType returns an error wrapping ErrPreviewNotAllowed when stepType
+// cannot be previewed.
+func (p PreviewPolicy) CheckStepType(stepType string) error {
+	if p.AllowedStepTypes[stepType] {
+		return nil
+	}
+	return fmt.Errorf("step type %q is %w: only side-effect-free steps can be previewed (%s)",
+		stepType, ErrPreviewNotAllowed, strings.Join(p.StepTypes(), ", "))
+}
+
+// CheckImports returns an error wrapping ErrPreviewNotAllowed for the first
+// blocked package in imports.
+func (p PreviewPolicy) CheckImports(imports []string) error {
+	for _, pkg := range imports {
+		if p.BlockedImports[pkg] {
+			return fmt.Errorf("import %q is %w: components that reach the network, filesystem, or databases cannot be previewed", pkg, ErrPreviewNotAllowed)
+		}
+	}
+	return nil
+}
+
+// StepTypes returns the previewable step types in sorted order.
+func (p PreviewPolicy) StepTypes() []string {
+	return slices.Sorted(maps.Keys(p.AllowedStepTypes))
+}
+
+// WithTimeout returns ctx bounded by the policy timeout. A zero timeout leaves
+// ctx unbounded.
+func (p PreviewPolicy) WithTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if p.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, p.Timeout)
+}
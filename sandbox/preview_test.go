@@ -0,0 +1,37 @@
+package sandbox
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPreviewPolicy_CheckStepType(t *testing.T) {
+	p := DefaultPreviewPolicy()
+	for _, st := range []string{"step.jq", "step.transform", "step.validate", "step.set", "step.conditional", "step.json_response"} {
+		if err := p.CheckStepType(st); err != nil {
+			t.Errorf("%s: unexpected error %v", st, err)
+		}
+	}
+	for _, st := range []string{"step.db_query", "step.db_exec", "step.http_call", "step.publish", "step.event_publish", "step.unknown"} {
+		err := p.CheckStepType(st)
+		if !errors.Is(err, ErrPreviewNotAllowed) {
+			t.Errorf("%s: error = %v, want ErrPreviewNotAllowed", st, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), "step.jq") {
+			t.Errorf("%s: error %q should list previewable types", st, err)
+		}
+	}
+}
+
+func TestPreviewPolicy_CheckImports(t *testing.T) {
+	p := DefaultPreviewPolicy()
+	if err := p.CheckImports([]string{"context", "fmt", "strings", "encoding/json"}); err != nil {
+		t.Errorf("pure imports: unexpected error %v", err)
+	}
+	err := p.CheckImports([]string{"context", "net/http"})
+	if !errors.Is(err, ErrPreviewNotAllowed) || !strings.Contains(err.Error(), `"net/http"`) {
+		t.Errorf("net/http: error = %v, want ErrPreviewNotAllowed naming the import", err)
+	}
+}
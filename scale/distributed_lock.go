@@ -5,6 +5,7 @@ import (
 	"crypto/rand"
 	"database/sql"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"hash/fnv"
 	"log"
@@ -25,6 +26,21 @@ type DistributedLock interface {
 	TryAcquire(ctx context.Context, key string, ttl time.Duration) (release func(), acquired bool, err error)
 }
 
+// ErrLockLost is returned when renewing a lock that has expired or passed to
+// another holder.
+var ErrLockLost = errors.New("lock lost")
+
+// RenewableLock is a DistributedLock whose locks expire after their TTL
+// unless the holder renews them, so a lock can be held for as long as its
+// holder is alive.
+type RenewableLock interface {
+	DistributedLock
+	// TryAcquireRenewable is TryAcquire that also returns a function
+	// extending the held lock by ttl. Renewing fails with ErrLockLost once the
+	// lock has expired or been taken by another holder.
+	TryAcquireRenewable(ctx context.Context, key string, ttl time.Duration) (renew func(context.Context) error, release func(), acquired bool, err error)
+}
+
 // --- InMemoryLock ---
 
 // InMemoryLock implements DistributedLock for testing and single-server deployments.
@@ -306,6 +322,15 @@ else
 end
 `)
 
+// redisRenewScript atomically extends a Redis lock only if the caller holds it.
+var redisRenewScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+    return redis.call("pexpire", KEYS[1], ARGV[2])
+else
+    return 0
+end
+`)
+
 // RedisLock implements DistributedLock using Redis SET NX with TTL.
 // Uses a unique token per acquisition to ensure only the holder can release.
 type RedisLock struct {
@@ -425,3 +450,34 @@ func (l *RedisLock) TryAcquire(ctx context.Context, key string, ttl time.Duratio
 	}
 	return l.buildRelease(key, token), true, nil
 }
+
+// TryAcquireRenewable attempts to acquire a Redis lock for the given key
+// without blocking, returning a function that extends its TTL while the
+// caller still holds it.
+func (l *RedisLock) TryAcquireRenewable(ctx context.Context, key string, ttl time.Duration) (func(context.Context) error, func(), bool, error) {
+	l.connect()
+
+	token, err := randomToken()
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	cmd := l.client.SetArgs(ctx, key, token, redis.SetArgs{Mode: "NX", TTL: ttl})
+	if err := cmd.Err(); err != nil && err != redis.Nil {
+		return nil, nil, false, fmt.Errorf("try acquire redis lock for %s: %w", key, err)
+	}
+	if cmd.Val() != "OK" {
+		return nil, nil, false, nil
+	}
+	renew := func(ctx context.Context) error {
+		n, err := redisRenewScript.Run(ctx, l.client, []string{key}, token, ttl.Milliseconds()).Int()
+		if err != nil {
+			return fmt.Errorf("renew redis lock for %s: %w", key, err)
+		}
+		if n == 0 {
+			return fmt.Errorf("renew redis lock for %s: %w", key, ErrLockLost)
+		}
+		return nil
+	}
+	return renew, l.buildRelease(key, token), true, nil
+}
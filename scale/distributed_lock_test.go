@@ -2,6 +2,7 @@ package scale
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"sync/atomic"
@@ -399,6 +400,41 @@ func TestRedisLockTTLExpiry(t *testing.T) {
 	}
 }
 
+func TestRedisLockRenewable(t *testing.T) {
+	mr := miniredis.RunT(t)
+	lock := NewRedisLock(mr.Addr())
+	defer lock.Close() //nolint:errcheck
+
+	ctx := context.Background()
+
+	renew, release, acquired, err := lock.TryAcquireRenewable(ctx, "lease-key", time.Second)
+	if err != nil || !acquired {
+		t.Fatalf("TryAcquireRenewable = %v, %v", acquired, err)
+	}
+	defer release()
+
+	// Renewing before the TTL runs out keeps the lock held.
+	mr.FastForward(800 * time.Millisecond)
+	if err := renew(ctx); err != nil {
+		t.Fatalf("renew failed: %v", err)
+	}
+	mr.FastForward(800 * time.Millisecond)
+	if _, acquired, _ := lock.TryAcquire(ctx, "lease-key", time.Second); acquired {
+		t.Fatal("expected the renewed lock to still be held")
+	}
+
+	// Once it has expired and passed to another holder, renewing fails.
+	mr.FastForward(time.Second)
+	other, acquired, err := lock.TryAcquire(ctx, "lease-key", time.Second)
+	if err != nil || !acquired {
+		t.Fatalf("TryAcquire after expiry = %v, %v", acquired, err)
+	}
+	defer other()
+	if err := renew(ctx); !errors.Is(err, ErrLockLost) {
+		t.Errorf("renew after losing the lock = %v, want ErrLockLost", err)
+	}
+}
+
 func TestRedisLockAcquireContextCancel(t *testing.T) {
 	mr := miniredis.RunT(t)
 	lock := NewRedisLock(mr.Addr())
@@ -445,6 +481,7 @@ func TestDistributedLockInterface(t *testing.T) {
 	var _ DistributedLock = (*InMemoryLock)(nil)
 	var _ DistributedLock = (*PGAdvisoryLock)(nil)
 	var _ DistributedLock = (*RedisLock)(nil)
+	var _ RenewableLock = (*RedisLock)(nil)
 }
 
 func TestInMemoryLock_StaleEntryEviction(t *testing.T) {
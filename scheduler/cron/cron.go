@@ -0,0 +1,369 @@
+// Package cron parses cron expressions and computes their next run times.
+// It depends on the standard library only, so packages that just validate
+// schedules (such as schema) can use it without the scheduler.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxSearchYears bounds the search for a schedule's next run. An expression
+// that matches no real date within this window (e.g. "0 0 30 2 *") never
+// fires and is rejected when parsed.
+const MaxSearchYears = 5
+
+// Schedule is a parsed cron expression, optionally bound to a time zone.
+//
+// Supported syntax:
+//   - 5 fields (minute hour day-of-month month day-of-week) or 6 fields with
+//     a leading seconds field
+//   - "*", "?", lists (1,15), ranges (1-5), steps (*/5, 1-30/10, 5/15) and
+//     month (JAN-DEC) and weekday (SUN-SAT) names; weekday 7 is Sunday
+//   - descriptors @yearly, @annually, @monthly, @weekly, @daily, @midnight,
+//     @hourly and @every <duration>
+//
+// When both day-of-month and day-of-week are restricted a day matches if
+// either does, as in standard cron.
+//
+// Daylight saving transitions are handled on the wall clock of the
+// schedule's time zone: a time that does not exist because clocks jump
+// forward is skipped, and a time that occurs twice because clocks fall back
+// runs once, at its first occurrence. @every schedules are fixed intervals
+// and ignore the wall clock.
+type Schedule struct {
+	expr     string
+	location *time.Location
+	every    time.Duration
+
+	second, minute, hour, dom, month, dow uint64
+	domStar, dowStar                      bool
+}
+
+// cronField describes one position of a cron expression.
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	secondField = cronField{name: "second", min: 0, max: 59}
+	minuteField = cronField{name: "minute", min: 0, max: 59}
+	hourField   = cronField{name: "hour", min: 0, max: 23}
+	domField    = cronField{name: "day-of-month", min: 1, max: 31}
+	monthField  = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+		"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+	}}
+	dowField = cronField{name: "day-of-week", min: 0, max: 7, names: map[string]int{
+		"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+	}}
+)
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses a cron expression. The schedule runs in the location of
+// the time passed to Next.
+func Parse(expr string) (*Schedule, error) {
+	return parse(expr, nil)
+}
+
+// ParseInLocation parses a cron expression that runs in the named IANA
+// time zone. An empty timezone behaves like Parse.
+func ParseInLocation(expr, timezone string) (*Schedule, error) {
+	if timezone == "" {
+		return parse(expr, nil)
+	}
+	loc, err := LoadTimezone(timezone)
+	if err != nil {
+		return nil, err
+	}
+	return parse(expr, loc)
+}
+
+// LoadTimezone loads an IANA time zone such as "America/New_York". Unlike
+// time.LoadLocation it rejects "Local" and reports missing zone data
+// explicitly rather than letting callers fall back to UTC.
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, fmt.Errorf("invalid timezone %q: use an IANA name such as \"UTC\" or \"Europe/Berlin\"", name)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("timezone %q: %w (the name is unknown or the zone database is not installed; build with -tags timetzdata to embed it)", name, err)
+	}
+	return loc, nil
+}
+
+func parse(expr string, loc *time.Location) (*Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	s := &Schedule{expr: expr, location: loc}
+
+	if strings.HasPrefix(expr, "@") {
+		if rest, ok := strings.CutPrefix(expr, "@every"); ok {
+			d, err := time.ParseDuration(strings.TrimSpace(rest))
+			if err != nil {
+				return nil, fmt.Errorf("@every: invalid duration %q: %w", strings.TrimSpace(rest), err)
+			}
+			if d < time.Second {
+				return nil, fmt.Errorf("@every: duration %s is shorter than 1s", d)
+			}
+			s.every = d
+			return s, nil
+		}
+		spec, ok := cronDescriptors[strings.ToLower(expr)]
+		if !ok {
+			return nil, fmt.Errorf("unknown descriptor %q", expr)
+		}
+		expr = spec
+	}
+
+	fields := strings.Fields(expr)
+	var layout []cronField
+	switch len(fields) {
+	case 5:
+		layout = []cronField{minuteField, hourField, domField, monthField, dowField}
+		s.second = 1 // second 0
+	case 6:
+		layout = []cronField{secondField, minuteField, hourField, domField, monthField, dowField}
+	default:
+		return nil, fmt.Errorf("expected 5 fields (or 6 with seconds), got %d", len(fields))
+	}
+
+	for i, f := range layout {
+		bits, star, err := parseCronField(fields[i], f)
+		if err != nil {
+			return nil, fmt.Errorf("field %d (%s) %q: %w", i+1, f.name, fields[i], err)
+		}
+		switch f.name {
+		case "second":
+			s.second = bits
+		case "minute":
+			s.minute = bits
+		case "hour":
+			s.hour = bits
+		case "day-of-month":
+			s.dom, s.domStar = bits, star
+		case "month":
+			s.month = bits
+		case "day-of-week":
+			// 7 is an alias for Sunday.
+			if bits&(1<<7) != 0 {
+				bits = bits&^(1<<7) | 1
+			}
+			s.dow, s.dowStar = bits, star
+		}
+	}
+
+	// Reject expressions such as "0 0 31 2 *" that never fire.
+	probe := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	if s.nextCivil(probe).IsZero() {
+		return nil, fmt.Errorf("expression never matches a real date (day-of-month does not occur in the selected months)")
+	}
+	return s, nil
+}
+
+// parseCronField parses one field into a bit set. star reports whether the
+// field is unrestricted ("*" or "?"), which matters for day matching.
+func parseCronField(field string, f cronField) (bits uint64, star bool, err error) {
+	if field == "?" {
+		if f.name != "day-of-month" && f.name != "day-of-week" {
+			return 0, false, fmt.Errorf("\"?\" is only allowed in day-of-month and day-of-week")
+		}
+		field = "*"
+	}
+	star = field == "*" || strings.HasPrefix(field, "*/")
+	for _, part := range strings.Split(field, ",") {
+		b, err := parseCronPart(part, f)
+		if err != nil {
+			return 0, false, err
+		}
+		bits |= b
+	}
+	return bits, star, nil
+}
+
+func parseCronPart(part string, f cronField) (uint64, error) {
+	if part == "" {
+		return 0, fmt.Errorf("empty list item")
+	}
+	rangePart, stepPart, hasStep := strings.Cut(part, "/")
+	step := 1
+	if hasStep {
+		n, err := strconv.Atoi(stepPart)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid step %q", stepPart)
+		}
+		if n > f.max {
+			return 0, fmt.Errorf("step %d out of range 1-%d", n, f.max)
+		}
+		step = n
+	}
+
+	lo, hi := f.min, f.max
+	switch {
+	case rangePart == "*":
+	case strings.Contains(rangePart, "-"):
+		a, b, _ := strings.Cut(rangePart, "-")
+		var err error
+		if lo, err = parseCronValue(a, f); err != nil {
+			return 0, err
+		}
+		if hi, err = parseCronValue(b, f); err != nil {
+			return 0, err
+		}
+		if lo > hi {
+			return 0, fmt.Errorf("range %d-%d is backwards", lo, hi)
+		}
+	default:
+		v, err := parseCronValue(rangePart, f)
+		if err != nil {
+			return 0, err
+		}
+		lo = v
+		if !hasStep {
+			hi = v
+		}
+	}
+
+	var bits uint64
+	for v := lo; v <= hi; v += step {
+		bits |= 1 << uint(v)
+	}
+	return bits, nil
+}
+
+func parseCronValue(s string, f cronField) (int, error) {
+	if s == "" {
+		return 0, fmt.Errorf("empty value")
+	}
+	if v, ok := f.names[strings.ToUpper(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("value %d out of range %d-%d", v, f.min, f.max)
+	}
+	return v, nil
+}
+
+// String returns the expression the schedule was parsed from.
+func (s *Schedule) String() string { return s.expr }
+
+// Location returns the schedule's time zone, or nil when it runs in the
+// location of the times passed to Next.
+func (s *Schedule) Location() *time.Location { return s.location }
+
+// Next returns the first run strictly after from, in the schedule's time
+// zone. It returns the zero time if there is none within five years.
+func (s *Schedule) Next(from time.Time) time.Time {
+	if s.location != nil {
+		from = from.In(s.location)
+	}
+	if s.every > 0 {
+		return from.Truncate(time.Second).Add(s.every)
+	}
+	loc := from.Location()
+
+	// Search wall-clock ("civil") times, represented in UTC so no DST
+	// applies, then map each match back into the location.
+	civil := time.Date(from.Year(), from.Month(), from.Day(), from.Hour(), from.Minute(), from.Second(), 0, time.UTC)
+	for {
+		civil = s.nextCivil(civil)
+		if civil.IsZero() {
+			return time.Time{}
+		}
+		t, ok := firstInstant(civil, loc)
+		if ok && t.After(from) {
+			return t
+		}
+		// Skipped by a forward jump, or the repeat of an hour that already
+		// ran before the clocks fell back.
+	}
+}
+
+// NextN returns the next n runs after from.
+func (s *Schedule) NextN(from time.Time, n int) []time.Time {
+	out := make([]time.Time, 0, n)
+	for len(out) < n {
+		next := s.Next(from)
+		if next.IsZero() {
+			break
+		}
+		out = append(out, next)
+		from = next
+	}
+	return out
+}
+
+// nextCivil returns the first civil time strictly after t that matches the
+// schedule's fields, or the zero time if none matches within the search
+// window.
+func (s *Schedule) nextCivil(t time.Time) time.Time {
+	t = t.Add(time.Second)
+	limit := t.Year() + MaxSearchYears
+	for t.Year() <= limit {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Truncate(time.Minute).Add(time.Minute)
+		case s.second&(1<<uint(t.Second())) == 0:
+			t = t.Add(time.Second)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domOK := s.dom&(1<<uint(t.Day())) != 0
+	dowOK := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domOK && dowOK
+	}
+	return domOK || dowOK
+}
+
+// firstInstant maps a civil time to the earliest instant in loc that shows
+// that wall clock. ok is false when the civil time does not exist in loc.
+func firstInstant(civil time.Time, loc *time.Location) (time.Time, bool) {
+	var best time.Time
+	found := false
+	// The offsets in effect a day either side cover any single transition.
+	for _, probe := range []time.Time{civil.Add(-24 * time.Hour), civil.Add(24 * time.Hour)} {
+		_, offset := time.Unix(probe.Unix(), 0).In(loc).Zone()
+		t := time.Unix(civil.Unix()-int64(offset), 0).In(loc)
+		if !sameWallClock(t, civil) {
+			continue
+		}
+		if !found || t.Before(best) {
+			best, found = t, true
+		}
+	}
+	return best, found
+}
+
+func sameWallClock(t, civil time.Time) bool {
+	return t.Year() == civil.Year() && t.Month() == civil.Month() && t.Day() == civil.Day() &&
+		t.Hour() == civil.Hour() && t.Minute() == civil.Minute() && t.Second() == civil.Second()
+}
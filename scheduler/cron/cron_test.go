@@ -0,0 +1,116 @@
+package cron
+
+import (
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata" // DST tests must not depend on the host's zone database
+)
+
+func TestParseErrorsNameTheField(t *testing.T) {
+	tests := []struct {
+		expr, want string
+	}{
+		{"* 25 * * *", `field 2 (hour) "25": value 25 out of range 0-23`},
+		{"0 0 * JANUARY *", `field 4 (month) "JANUARY": invalid value "JANUARY"`},
+		{"0 0 0 * * MON-SUN/0", `field 6 (day-of-week) "MON-SUN/0": invalid step "0"`},
+		{"0 5-1 * * *", `field 2 (hour) "5-1": range 5-1 is backwards`},
+		{"? * * * *", `field 1 (minute) "?"`},
+		{"0 0 31 2 *", "never matches a real date"},
+		{"* * *", "expected 5 fields (or 6 with seconds), got 3"},
+		{"@fortnightly", `unknown descriptor "@fortnightly"`},
+		{"@every 500ms", "shorter than 1s"},
+	}
+	for _, tc := range tests {
+		_, err := Parse(tc.expr)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("Parse(%q) error = %v, want it to contain %q", tc.expr, err, tc.want)
+		}
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	from := time.Date(2025, 1, 1, 12, 0, 30, 0, time.UTC) // a Wednesday
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"@daily", time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2025, 1, 1, 13, 0, 0, 0, time.UTC)},
+		{"@every 5m", time.Date(2025, 1, 1, 12, 5, 30, 0, time.UTC)},
+		{"*/15 * * * * *", time.Date(2025, 1, 1, 12, 0, 45, 0, time.UTC)},
+		{"0 9 * * MON-FRI", time.Date(2025, 1, 2, 9, 0, 0, 0, time.UTC)},
+		{"0 0 1 */3 *", time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either may match.
+		{"0 0 15 * FRI", time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range tests {
+		s, err := Parse(tc.expr)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tc.expr, err)
+		}
+		if got := s.Next(from); !got.Equal(tc.want) {
+			t.Errorf("%q: Next = %v, want %v", tc.expr, got, tc.want)
+		}
+	}
+}
+
+func TestScheduleTimezone(t *testing.T) {
+	s, err := ParseInLocation("0 9 * * *", "Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := s.Next(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	if want := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC); !got.Equal(want) || got.Location().String() != "Asia/Tokyo" {
+		t.Errorf("Next = %v, want %v in Asia/Tokyo", got, want)
+	}
+
+	for _, tz := range []string{"Mars/Olympus_Mons", "Local"} {
+		if _, err := ParseInLocation("0 9 * * *", tz); err == nil {
+			t.Errorf("timezone %q should be rejected", tz)
+		}
+	}
+}
+
+func TestScheduleDSTSpringForwardSkips(t *testing.T) {
+	// Clocks in New York jump from 02:00 to 03:00 on 2025-03-09.
+	s, err := ParseInLocation("30 2 * * *", "America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ny, _ := time.LoadLocation("America/New_York")
+	runs := s.NextN(time.Date(2025, 3, 8, 12, 0, 0, 0, ny), 2)
+	want := []time.Time{
+		time.Date(2025, 3, 10, 2, 30, 0, 0, ny),
+		time.Date(2025, 3, 11, 2, 30, 0, 0, ny),
+	}
+	for i := range want {
+		if !runs[i].Equal(want[i]) {
+			t.Errorf("run %d = %v, want %v (02:30 on 2025-03-09 does not exist and is skipped)", i, runs[i], want[i])
+		}
+	}
+}
+
+func TestScheduleDSTFallBackRunsOnce(t *testing.T) {
+	// Clocks in New York fall back from 02:00 EDT to 01:00 EST on 2025-11-02,
+	// so 01:30 happens twice.
+	s, err := ParseInLocation("30 1 * * *", "America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ny, _ := time.LoadLocation("America/New_York")
+	runs := s.NextN(time.Date(2025, 11, 2, 0, 0, 0, 0, ny), 2)
+	first := time.Date(2025, 11, 2, 5, 30, 0, 0, time.UTC) // 01:30 EDT
+	second := time.Date(2025, 11, 3, 6, 30, 0, 0, time.UTC)
+	if !runs[0].Equal(first) || !runs[1].Equal(second) {
+		t.Errorf("runs = %v, want %v then %v", runs, first, second)
+	}
+
+	// Starting inside the repeated hour, after the first 01:30 already ran,
+	// does not run it again.
+	if got := s.Next(time.Date(2025, 11, 2, 6, 10, 0, 0, time.UTC)); !got.Equal(second) {
+		t.Errorf("Next from 01:10 EST = %v, want %v", got, second)
+	}
+}
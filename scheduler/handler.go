@@ -65,6 +65,7 @@ func (h *Handler) updateJob(w http.ResponseWriter, r *http.Request) {
 	var body struct {
 		Name         string         `json:"name"`
 		CronExpr     string         `json:"cronExpr"`
+		Timezone     *string        `json:"timezone"`
 		WorkflowType string         `json:"workflowType"`
 		Action       string         `json:"action"`
 		Params       map[string]any `json:"params"`
@@ -78,6 +79,12 @@ func (h *Handler) updateJob(w http.ResponseWriter, r *http.Request) {
 		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
 		return
 	}
+	if body.Timezone != nil {
+		if err := h.scheduler.SetTimezone(id, *body.Timezone); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+	}
 
 	job, _ := h.scheduler.Get(id)
 	writeJSON(w, http.StatusOK, job)
@@ -142,13 +149,18 @@ func (h *Handler) previewNextRuns(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	times, err := h.scheduler.NextRuns(cronExpr, count)
+	timezone := r.URL.Query().Get("timezone")
+	times, err := h.scheduler.NextRunsIn(cronExpr, timezone, count)
 	if err != nil {
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]any{"cronExpr": cronExpr, "nextRuns": times})
+	resp := map[string]any{"cronExpr": cronExpr, "nextRuns": times}
+	if timezone != "" {
+		resp["timezone"] = timezone
+	}
+	writeJSON(w, http.StatusOK, resp)
 }
 
 func writeJSON(w http.ResponseWriter, status int, v any) {
@@ -6,9 +6,10 @@ import (
 	"encoding/hex"
 	"fmt"
 	"sort"
-	"strings"
 	"sync"
 	"time"
+
+	"github.com/GoCodeAlone/workflow/scheduler/cron"
 )
 
 // JobStatus represents the status of a scheduled job.
@@ -34,6 +35,7 @@ type ScheduledJob struct {
 	ID           string         `json:"id"`
 	Name         string         `json:"name"`
 	CronExpr     string         `json:"cronExpr"`
+	Timezone     string         `json:"timezone,omitempty"`
 	WorkflowType string         `json:"workflowType"`
 	Action       string         `json:"action"`
 	Params       map[string]any `json:"params,omitempty"`
@@ -102,6 +104,11 @@ func (s *CronScheduler) Create(job *ScheduledJob) error {
 	if err := ValidateCron(job.CronExpr); err != nil {
 		return fmt.Errorf("invalid cron expression: %w", err)
 	}
+	if job.Timezone != "" {
+		if _, err := cron.LoadTimezone(job.Timezone); err != nil {
+			return err
+		}
+	}
 
 	id, err := generateID("sj")
 	if err != nil {
@@ -117,7 +124,7 @@ func (s *CronScheduler) Create(job *ScheduledJob) error {
 	job.CreatedAt = now
 	job.UpdatedAt = now
 
-	next, err := s.nextRunFn(job.CronExpr, now)
+	next, err := s.nextRunFn(job.CronExpr, jobTime(job, now))
 	if err == nil {
 		job.NextRunAt = &next
 	}
@@ -165,7 +172,7 @@ func (s *CronScheduler) Update(id string, name, cronExpr, workflowType, action s
 			return fmt.Errorf("invalid cron expression: %w", err)
 		}
 		job.CronExpr = cronExpr
-		next, err := s.nextRunFn(cronExpr, time.Now())
+		next, err := s.nextRunFn(cronExpr, jobTime(job, time.Now()))
 		if err == nil {
 			job.NextRunAt = &next
 		}
@@ -186,6 +193,43 @@ func (s *CronScheduler) Update(id string, name, cronExpr, workflowType, action s
 	return nil
 }
 
+// SetTimezone changes the IANA time zone a job's cron expression runs in.
+// An empty timezone uses the server's local time.
+func (s *CronScheduler) SetTimezone(id, timezone string) error {
+	if timezone != "" {
+		if _, err := cron.LoadTimezone(timezone); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok || job.Status == JobStatusDeleted {
+		return fmt.Errorf("job %q not found", id)
+	}
+	job.Timezone = timezone
+	next, err := s.nextRunFn(job.CronExpr, jobTime(job, time.Now()))
+	if err == nil {
+		job.NextRunAt = &next
+	}
+	job.UpdatedAt = time.Now()
+	return nil
+}
+
+// jobTime returns t in the job's time zone. The zone was validated when it
+// was set, so a load failure leaves t unchanged.
+func jobTime(job *ScheduledJob, t time.Time) time.Time {
+	if job.Timezone == "" {
+		return t
+	}
+	loc, err := cron.LoadTimezone(job.Timezone)
+	if err != nil {
+		return t
+	}
+	return t.In(loc)
+}
+
 // Delete soft-deletes a job and stops its execution loop.
 func (s *CronScheduler) Delete(id string) error {
 	s.mu.Lock()
@@ -245,7 +289,7 @@ func (s *CronScheduler) Resume(id string) error {
 	job.Status = JobStatusActive
 	job.UpdatedAt = time.Now()
 
-	next, err := s.nextRunFn(job.CronExpr, time.Now())
+	next, err := s.nextRunFn(job.CronExpr, jobTime(job, time.Now()))
 	if err == nil {
 		job.NextRunAt = &next
 	}
@@ -267,15 +311,28 @@ func (s *CronScheduler) History(jobID string) []*ExecutionRecord {
 
 // NextRuns returns up to n upcoming execution times for a given cron expression.
 func (s *CronScheduler) NextRuns(cronExpr string, n int) ([]time.Time, error) {
+	return s.NextRunsIn(cronExpr, "", n)
+}
+
+// NextRunsIn returns up to n upcoming execution times for a cron expression
+// evaluated in the given IANA time zone (server local time when empty).
+func (s *CronScheduler) NextRunsIn(cronExpr, timezone string, n int) ([]time.Time, error) {
 	if err := ValidateCron(cronExpr); err != nil {
 		return nil, err
 	}
+	from := time.Now()
+	if timezone != "" {
+		loc, err := cron.LoadTimezone(timezone)
+		if err != nil {
+			return nil, err
+		}
+		from = from.In(loc)
+	}
 	s.mu.RLock()
 	fn := s.nextRunFn
 	s.mu.RUnlock()
 
 	times := make([]time.Time, 0, n)
-	from := time.Now()
 	for i := 0; i < n; i++ {
 		next, err := fn(cronExpr, from)
 		if err != nil {
@@ -329,7 +386,7 @@ func (s *CronScheduler) executeJob(ctx context.Context, job *ScheduledJob) *Exec
 	s.mu.Lock()
 	now := time.Now()
 	job.LastRunAt = &now
-	next, err := s.nextRunFn(job.CronExpr, now)
+	next, err := s.nextRunFn(job.CronExpr, jobTime(job, now))
 	if err == nil {
 		job.NextRunAt = &next
 	}
@@ -355,128 +412,29 @@ func mustGenerateID(prefix string) string {
 	return id
 }
 
-// NextRun computes the next run time for a cron expression from a given point in time.
+// NextRun computes the next run time for a cron expression from a given
+// point in time, in from's location.
 func NextRun(cronExpr string, from time.Time) (time.Time, error) {
 	return defaultNextRun(cronExpr, from)
 }
 
-// --- Simple cron parser (supports standard 5-field cron) ---
-
-// ValidateCron validates a standard 5-field cron expression.
+// ValidateCron validates a cron expression. See cron.Schedule for the
+// supported syntax; errors name the offending field and its position.
 func ValidateCron(expr string) error {
-	fields := strings.Fields(expr)
-	if len(fields) != 5 {
-		return fmt.Errorf("expected 5 fields, got %d", len(fields))
-	}
-
-	limits := []struct{ min, max int }{
-		{0, 59}, // minute
-		{0, 23}, // hour
-		{1, 31}, // day of month
-		{1, 12}, // month
-		{0, 7},  // day of week (0 and 7 are Sunday)
-	}
-
-	for i, field := range fields {
-		if err := validateCronField(field, limits[i].min, limits[i].max); err != nil {
-			return fmt.Errorf("field %d (%q): %w", i+1, field, err)
-		}
-	}
-	return nil
-}
-
-func validateCronField(field string, min, max int) error {
-	if field == "*" {
-		return nil
-	}
-	// Handle */N step values
-	if strings.HasPrefix(field, "*/") {
-		stepStr := field[2:]
-		step := 0
-		for _, c := range stepStr {
-			if c < '0' || c > '9' {
-				return fmt.Errorf("invalid step value %q", stepStr)
-			}
-			step = step*10 + int(c-'0')
-		}
-		if step <= 0 || step > max {
-			return fmt.Errorf("step %d out of range [1-%d]", step, max)
-		}
-		return nil
-	}
-	// Handle comma-separated values
-	parts := strings.Split(field, ",")
-	for _, part := range parts {
-		// Handle ranges like 1-5
-		if strings.Contains(part, "-") {
-			rangeParts := strings.SplitN(part, "-", 2)
-			lo, err := parseCronInt(rangeParts[0])
-			if err != nil {
-				return err
-			}
-			hi, err := parseCronInt(rangeParts[1])
-			if err != nil {
-				return err
-			}
-			if lo < min || hi > max || lo > hi {
-				return fmt.Errorf("range %d-%d out of bounds [%d-%d]", lo, hi, min, max)
-			}
-			continue
-		}
-		v, err := parseCronInt(part)
-		if err != nil {
-			return err
-		}
-		if v < min || v > max {
-			return fmt.Errorf("value %d out of range [%d-%d]", v, min, max)
-		}
-	}
-	return nil
-}
-
-func parseCronInt(s string) (int, error) {
-	if s == "" {
-		return 0, fmt.Errorf("empty value")
-	}
-	v := 0
-	for _, c := range s {
-		if c < '0' || c > '9' {
-			return 0, fmt.Errorf("invalid number %q", s)
-		}
-		v = v*10 + int(c-'0')
-	}
-	return v, nil
+	_, err := cron.Parse(expr)
+	return err
 }
 
-// defaultNextRun computes the next run time for a simple cron expression.
-// It supports: every-minute, hourly, daily, and step expressions for minute field.
+// defaultNextRun computes the next run time for a cron expression in from's
+// location.
 func defaultNextRun(cronExpr string, from time.Time) (time.Time, error) {
-	fields := strings.Fields(cronExpr)
-	if len(fields) != 5 {
-		return time.Time{}, fmt.Errorf("invalid cron expression")
-	}
-
-	// Simple cases
-	switch cronExpr {
-	case "* * * * *":
-		return from.Add(time.Minute).Truncate(time.Minute), nil
-	case "0 * * * *":
-		next := from.Truncate(time.Hour).Add(time.Hour)
-		return next, nil
-	case "0 0 * * *":
-		next := time.Date(from.Year(), from.Month(), from.Day()+1, 0, 0, 0, 0, from.Location())
-		return next, nil
-	}
-
-	// Handle */N minute patterns
-	if strings.HasPrefix(fields[0], "*/") && fields[1] == "*" && fields[2] == "*" && fields[3] == "*" && fields[4] == "*" {
-		step, _ := parseCronInt(fields[0][2:])
-		if step > 0 {
-			next := from.Truncate(time.Minute).Add(time.Duration(step) * time.Minute)
-			return next, nil
-		}
+	sched, err := cron.Parse(cronExpr)
+	if err != nil {
+		return time.Time{}, err
 	}
-
-	// Fallback: next minute
-	return from.Add(time.Minute).Truncate(time.Minute), nil
+	next := sched.Next(from)
+	if next.IsZero() {
+		return time.Time{}, fmt.Errorf("cron expression %q has no run within %d years", cronExpr, cron.MaxSearchYears)
+	}
+	return next, nil
 }
@@ -0,0 +1,61 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/GoCodeAlone/workflow/config"
+)
+
+// validateCORS checks the CORS policies of http.middleware.cors modules and
+// of route cors blocks: every allowedOrigins entry must be an origin a
+// browser can send, and "*" must not be combined with allowCredentials.
+// Policies are checked where they are declared, so a typo such as a trailing
+// slash fails the build instead of silently blocking every request.
+func validateCORS(cfg *config.WorkflowConfig, errs *ValidationErrors) {
+	for i, mod := range cfg.Modules {
+		if mod.Type != "http.middleware.cors" {
+			continue
+		}
+		// The module allows every origin when allowedOrigins is not set.
+		origins := []any{"*"}
+		if list, ok := mod.Config["allowedOrigins"].([]any); ok {
+			origins = list
+		}
+		validateCORSPolicy(fmt.Sprintf("modules[%d].config", i), origins, mod.Config["allowCredentials"], errs)
+	}
+	for _, route := range cfg.HTTPRoutes() {
+		block, ok := route.CORS.(map[string]any)
+		if !ok {
+			continue
+		}
+		origins, _ := block["allowedOrigins"].([]any)
+		validateCORSPolicy(route.Path+".cors", origins, block["allowCredentials"], errs)
+	}
+}
+
+func validateCORSPolicy(path string, origins []any, allowCredentials any, errs *ValidationErrors) {
+	wildcard := false
+	for j, raw := range origins {
+		origin, ok := raw.(string)
+		if !ok {
+			continue
+		}
+		wildcard = wildcard || origin == "*"
+		if strings.Contains(origin, "${") {
+			continue // expanded from the environment when the module is built
+		}
+		if err := config.ValidateCORSOrigin(origin); err != nil {
+			*errs = append(*errs, &ValidationError{
+				Path:    fmt.Sprintf("%s.allowedOrigins[%d]", path, j),
+				Message: err.Error(),
+			})
+		}
+	}
+	if creds, _ := allowCredentials.(bool); creds && wildcard {
+		*errs = append(*errs, &ValidationError{
+			Path:    path + ".allowCredentials",
+			Message: `allowedOrigins "*" cannot be combined with allowCredentials; list the trusted origins instead`,
+		})
+	}
+}
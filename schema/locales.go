@@ -0,0 +1,35 @@
+package schema
+
+import (
+	"sync"
+
+	"github.com/GoCodeAlone/workflow/config"
+)
+
+// LocaleValidator checks a config's locales: section and the locales and
+// message keys the config refers to.
+type LocaleValidator func(cfg *config.WorkflowConfig) ValidationErrors
+
+// localeValidator is the validator ValidateConfig runs for locales. The i18n
+// package registers it, so schema does not depend on the message catalogs.
+var (
+	localeValidatorMu sync.RWMutex
+	localeValidator   LocaleValidator
+)
+
+// RegisterLocaleValidator sets the validator ValidateConfig runs for
+// locales. Without one, locales are not validated.
+func RegisterLocaleValidator(v LocaleValidator) {
+	localeValidatorMu.Lock()
+	defer localeValidatorMu.Unlock()
+	localeValidator = v
+}
+
+func validateLocales(cfg *config.WorkflowConfig, errs *ValidationErrors) {
+	localeValidatorMu.RLock()
+	v := localeValidator
+	localeValidatorMu.RUnlock()
+	if v != nil {
+		*errs = append(*errs, v(cfg)...)
+	}
+}
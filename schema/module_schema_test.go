@@ -56,16 +56,16 @@ func TestModuleSchemaRegistry_ConfigFieldsMatchEngine(t *testing.T) {
 		moduleType string
 		wantFields []string
 	}{
-		{"http.server", []string{"address", "port"}},
+		{"http.server", []string{"address", "port", "tls", "clientAuth", "maxRequestBytes", "maxResponseBytes", "maxInFlight", "maxQueued", "queueTimeout", "retryAfter"}},
 		{"http.handler", []string{"contentType"}},
 		{"http.middleware.ratelimit", []string{"requestsPerMinute", "burstSize"}},
 		{"http.middleware.cors", []string{"allowedOrigins", "allowedMethods"}},
-		{"http.middleware.auth", []string{"authType"}},
+		{"http.middleware.auth", []string{"authType", "jwks", "introspection"}},
 		{"http.middleware.logging", []string{"logLevel"}},
 		{"api.handler", []string{"resourceName", "workflowType", "workflowEngine", "initialTransition", "seedFile", "sourceResourceName", "stateFilter", "fieldMapping", "transitionMap", "summaryFields"}},
-		{"database.workflow", []string{"driver", "dsn", "maxOpenConns", "maxIdleConns"}},
-		{"messaging.kafka", []string{"brokers", "groupId"}},
-		{"auth.jwt", []string{"secret", "tokenExpiry", "issuer", "seedFile", "responseFormat", "allowRegistration"}},
+		{"database.workflow", []string{"driver", "dsn", "maxOpenConns", "maxIdleConns", "connMaxLifetime", "connMaxIdleTime", "replicas", "replicaHealthInterval"}},
+		{"messaging.kafka", []string{"brokers", "groupId", "autoOffsetReset", "commitMode", "partitions"}},
+		{"auth.jwt", []string{"secret", "tokenExpiry", "issuer", "seedFile", "responseFormat", "allowRegistration", "passwordHashing", "passwordPolicy"}},
 		{"static.fileserver", []string{"root", "prefix", "spaFallback", "cacheMaxAge", "router"}},
 		{"processing.step", []string{"componentId", "successTransition", "compensateTransition", "maxRetries", "retryBackoffMs", "timeoutSeconds"}},
 		{"http.middleware.securityheaders", []string{"contentSecurityPolicy", "frameOptions", "contentTypeOptions", "hstsMaxAge", "referrerPolicy", "permissionsPolicy"}},
@@ -73,6 +73,7 @@ func TestModuleSchemaRegistry_ConfigFieldsMatchEngine(t *testing.T) {
 		{"persistence.store", []string{"database"}},
 		{"dynamic.component", []string{"componentId", "source", "provides", "requires"}},
 		{"http.simple_proxy", []string{"targets"}},
+		{"http.middleware.clientcert", []string{"caFile", "crlFile", "reloadInterval", "rules"}},
 	}
 
 	for _, tt := range tests {
@@ -145,6 +145,8 @@ func configFieldDefToSchema(f ConfigFieldDef) *Schema {
 var coreModuleTypes = []string{
 	"actor.pool",
 	"actor.system",
+	"ai.pricing",
+	"alerting.rules",
 	"api.command",
 	"api.gateway",
 	"api.handler",
@@ -163,15 +165,18 @@ var coreModuleTypes = []string{
 	"config.provider",
 	"data.transformer",
 	"database.partitioned",
+	"database.query_insights",
 	"database.workflow",
 	"dlq.service",
 	"dynamic.component",
+	"eventstore.projection",
 	"eventstore.service",
 	"featureflag.service",
 	"health.checker",
 	"http.client",
 	"http.handler",
 	"http.middleware.auth",
+	"http.middleware.clientcert",
 	"http.middleware.cors",
 	"http.middleware.logging",
 	"http.middleware.otel",
@@ -181,9 +186,11 @@ var coreModuleTypes = []string{
 	"http.proxy",
 	"http.router",
 	"http.server",
+	"http.session",
 	"http.simple_proxy",
 	"iac.provider",
 	"iac.state",
+	"jobqueue.service",
 	"jsonschema.modular",
 	"license.validator",
 	"log.collector",
@@ -192,6 +199,7 @@ var coreModuleTypes = []string{
 	"messaging.handler",
 	"messaging.kafka",
 	"messaging.nats",
+	"messaging.topic_registry",
 	"metrics.collector",
 	"nosql.dynamodb",
 	"nosql.memory",
@@ -211,10 +219,12 @@ var coreModuleTypes = []string{
 	"platform.region_router",
 	"platform.resource",
 	"policy.mock",
+	"policy.service",
 	"processing.step",
 	"reverseproxy",
 	"sandbox.remote_runners",
 	"scheduler.modular",
+	"schema.registry",
 	"secrets.aws",
 	"secrets.vault",
 	"security.field-protection",
@@ -227,6 +237,7 @@ var coreModuleTypes = []string{
 	"step.ai_classify",
 	"step.ai_complete",
 	"step.ai_extract",
+	"step.api_call",
 	"step.app_deploy",
 	"step.app_rollback",
 	"step.app_status",
@@ -241,6 +252,9 @@ var coreModuleTypes = []string{
 	"step.artifact_pull",
 	"step.artifact_push",
 	"step.artifact_upload",
+	"step.assert",
+	"step.attest",
+	"step.audit",
 	"step.auth_required",
 	"step.auth_validate",
 	"step.authz_check",
@@ -265,6 +279,7 @@ var coreModuleTypes = []string{
 	"step.conditional",
 	"step.constraint_check",
 	"step.container_build",
+	"step.csv_parse",
 	"step.db_create_partition",
 	"step.db_exec",
 	"step.db_query",
@@ -277,6 +292,7 @@ var coreModuleTypes = []string{
 	"step.deploy_rollback",
 	"step.deploy_rolling",
 	"step.deploy_verify",
+	"step.dlq_publish",
 	"step.dlq_replay",
 	"step.dlq_send",
 	"step.dns_apply",
@@ -286,6 +302,7 @@ var coreModuleTypes = []string{
 	"step.docker_push",
 	"step.docker_run",
 	"step.drift_check",
+	"step.enqueue",
 	"step.event_decrypt",
 	"step.event_publish",
 	"step.feature_flag",
@@ -323,6 +340,7 @@ var coreModuleTypes = []string{
 	"step.k8s_destroy",
 	"step.k8s_plan",
 	"step.k8s_status",
+	"step.localize",
 	"step.log",
 	"step.m2m_token",
 	"step.marketplace_detail",
@@ -331,6 +349,7 @@ var coreModuleTypes = []string{
 	"step.marketplace_search",
 	"step.marketplace_uninstall",
 	"step.marketplace_update",
+	"step.metric",
 	"step.nosql_delete",
 	"step.nosql_get",
 	"step.nosql_put",
@@ -338,15 +357,18 @@ var coreModuleTypes = []string{
 	"step.oidc_auth_url",
 	"step.oidc_callback",
 	"step.parallel",
+	"step.pdf_render",
 	"step.pipeline_output",
 	"step.platform_apply",
 	"step.platform_destroy",
 	"step.platform_plan",
 	"step.platform_template",
+	"step.policy_check",
 	"step.policy_evaluate",
 	"step.policy_list",
 	"step.policy_load",
 	"step.policy_test",
+	"step.projection_rebuild",
 	"step.publish",
 	"step.rate_limit",
 	"step.raw_response",
@@ -360,21 +382,30 @@ var coreModuleTypes = []string{
 	"step.request_parse",
 	"step.resilient_circuit_breaker",
 	"step.response",
+	"step.retry",
 	"step.retry_with_backoff",
 	"step.s3_upload",
 	"step.sandbox_exec",
+	"step.sbom_generate",
 	"step.scan_container",
 	"step.scan_deps",
 	"step.scan_sast",
+	"step.schema_validate",
 	"step.secret_fetch",
 	"step.secret_rotate",
 	"step.secret_set",
+	"step.service_call",
+	"step.session_destroy",
+	"step.session_get",
+	"step.session_set",
 	"step.set",
 	"step.shell_exec",
 	"step.statemachine_get",
 	"step.statemachine_transition",
 	"step.static_file",
 	"step.sub_workflow",
+	"step.switch",
+	"step.test_report",
 	"step.token_revoke",
 	"step.trace_annotate",
 	"step.trace_extract",
@@ -388,6 +419,7 @@ var coreModuleTypes = []string{
 	"step.validate_pagination",
 	"step.validate_path_param",
 	"step.validate_request_body",
+	"step.validate_response",
 	"step.webhook_verify",
 	"step.while",
 	"step.workflow_call",
@@ -637,6 +669,20 @@ func registerPluginManifestTypes(data []byte) bool {
 	return true
 }
 
+// serviceContractSchema describes one provides/needs entry: a bare service
+// name or an object with a name and an interface hint.
+func serviceContractSchema() *Schema {
+	obj := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"name":      {Type: "string", Description: "Service registry name"},
+			"interface": {Type: "string", Description: "Go interface the service implements, e.g. http.Handler"},
+		},
+	}
+	obj.setAdditionalPropertiesBool(false)
+	return &Schema{OneOf: []*Schema{{Type: "string"}, obj}}
+}
+
 // moduleIfThen builds an if/then conditional schema for a specific module type
 // that adds per-type config property validation.
 func moduleIfThen(moduleType string, ms *ModuleSchema) *Schema {
@@ -702,10 +748,29 @@ func GenerateWorkflowSchema() *Schema {
 				Description: "List of module names this module depends on",
 				Items:       &Schema{Type: "string"},
 			},
+			"provides": {
+				Type:        "array",
+				Description: "Services this module registers: a service name, or {name, interface}",
+				Items:       serviceContractSchema(),
+			},
+			"needs": {
+				Type:        "array",
+				Description: "Services this module consumes, each resolved to exactly one module's provides; the module is ordered after its providers",
+				Items:       serviceContractSchema(),
+			},
 			"branches": {
 				Type:        "object",
 				Description: "Branch configuration for conditional routing",
 			},
+			"enabled": {
+				Type:        "boolean",
+				Description: "Set to false to skip building this module while keeping its config",
+			},
+			"startPolicy": {
+				Type:        "string",
+				Description: "required (default) aborts engine start when the module fails to start; optional reports it as degraded and starts the rest",
+				Enum:        []string{"required", "optional"},
+			},
 		},
 	}
 	moduleBase.setAdditionalPropertiesBool(false)
@@ -255,6 +255,24 @@ func TestValidateConfig_DependsOnEmpty(t *testing.T) {
 	assertContains(t, err.Error(), "dependency name must not be empty")
 }
 
+func TestValidateConfig_StartPolicy(t *testing.T) {
+	cfg := &config.WorkflowConfig{
+		Modules: []config.ModuleConfig{
+			{Name: "router", Type: "http.router", StartPolicy: config.StartPolicyOptional},
+			{Name: "server", Type: "http.server", StartPolicy: "best-effort", Config: map[string]any{"address": ":8080"}},
+		},
+	}
+	err := ValidateConfig(cfg)
+	if err == nil {
+		t.Fatal("expected error for invalid startPolicy")
+	}
+	assertContains(t, err.Error(), `modules[1].startPolicy`)
+	assertContains(t, err.Error(), `invalid startPolicy "best-effort"`)
+	if strings.Contains(err.Error(), "modules[0].startPolicy") {
+		t.Errorf("optional startPolicy rejected: %v", err)
+	}
+}
+
 func TestValidateConfig_DependsOnValid(t *testing.T) {
 	cfg := &config.WorkflowConfig{
 		Modules: []config.ModuleConfig{
@@ -270,6 +288,76 @@ func TestValidateConfig_DependsOnValid(t *testing.T) {
 	}
 }
 
+func TestValidateConfig_ScheduleJobs(t *testing.T) {
+	cfg := &config.WorkflowConfig{
+		Modules: []config.ModuleConfig{
+			{Name: "server", Type: "http.server", Config: map[string]any{"address": ":8080"}},
+		},
+		Triggers: map[string]any{
+			"schedule": map[string]any{
+				"jobs": []any{
+					map[string]any{"cron": "0 25 * * *", "workflow": "w", "action": "a"},
+					map[string]any{"cron": "@daily", "timezone": "Mars/Base", "workflow": "w", "action": "a"},
+					map[string]any{"cron": "0 9 * * MON-FRI", "timezone": "Europe/Berlin", "workflow": "w", "action": "a"},
+				},
+			},
+		},
+		Pipelines: map[string]any{
+			"nightly": map[string]any{
+				"trigger": map[string]any{"type": "schedule", "config": map[string]any{"cron": "0 0 31 2 *"}},
+			},
+		},
+	}
+	err := ValidateConfig(cfg, WithAllowNoEntryPoints())
+	if err == nil {
+		t.Fatal("expected schedule validation errors")
+	}
+	assertContains(t, err.Error(), `triggers.schedule.jobs[0].cron: field 2 (hour) "25": value 25 out of range 0-23`)
+	assertContains(t, err.Error(), `triggers.schedule.jobs[1].timezone: timezone "Mars/Base"`)
+	assertContains(t, err.Error(), "pipelines.nightly.trigger.config.cron: expression never matches a real date")
+	if strings.Contains(err.Error(), "jobs[2]") {
+		t.Errorf("valid job reported: %v", err)
+	}
+}
+
+func TestValidateConfig_CORS(t *testing.T) {
+	cfg := &config.WorkflowConfig{
+		Modules: []config.ModuleConfig{
+			{Name: "server", Type: "http.server", Config: map[string]any{"address": ":8080"}},
+			{Name: "cors", Type: "http.middleware.cors", Config: map[string]any{"allowCredentials": true}},
+			{Name: "app-cors", Type: "http.middleware.cors", Config: map[string]any{
+				"allowedOrigins": []any{"https://app.example.com/", "app.example.com", "${APP_ORIGIN}", "*.example.com"},
+			}},
+		},
+		Triggers: map[string]any{
+			"http": map[string]any{"routes": []any{
+				map[string]any{"method": "GET", "path": "/items", "cors": map[string]any{
+					"allowedOrigins":   []any{"*", "https://admin.example.com/api"},
+					"allowCredentials": true,
+				}},
+				map[string]any{"method": "GET", "path": "/ok", "cors": map[string]any{
+					"allowedOrigins":   []any{"https://admin.example.com"},
+					"allowCredentials": true,
+				}},
+			}},
+		},
+	}
+	err := ValidateConfig(cfg, WithAllowNoEntryPoints())
+	if err == nil {
+		t.Fatal("expected CORS validation errors")
+	}
+	assertContains(t, err.Error(), `modules[1].config.allowCredentials: allowedOrigins "*" cannot be combined with allowCredentials`)
+	assertContains(t, err.Error(), "modules[2].config.allowedOrigins[0]: origin \"https://app.example.com/\" has a trailing slash")
+	assertContains(t, err.Error(), "modules[2].config.allowedOrigins[1]: origin \"app.example.com\" has no scheme")
+	assertContains(t, err.Error(), "triggers.http.routes[0].cors.allowedOrigins[1]: origin \"https://admin.example.com/api\" is not scheme://host[:port]")
+	assertContains(t, err.Error(), "triggers.http.routes[0].cors.allowCredentials:")
+	for _, ok := range []string{"allowedOrigins[2]", "allowedOrigins[3]", "routes[1]"} {
+		if strings.Contains(err.Error(), ok) {
+			t.Errorf("valid entry %s reported: %v", ok, err)
+		}
+	}
+}
+
 func TestValidateConfig_UnknownWorkflowType(t *testing.T) {
 	cfg := &config.WorkflowConfig{
 		Modules: []config.ModuleConfig{
@@ -464,6 +552,36 @@ func TestValidateConfig_SimpleProxy_InvalidTarget(t *testing.T) {
 	assertContains(t, err.Error(), "proxy target must be a string URL")
 }
 
+func TestValidateConfig_Kafka_ConsumerOptions(t *testing.T) {
+	cfg := &config.WorkflowConfig{
+		Modules: []config.ModuleConfig{
+			{Name: "kafka", Type: "messaging.kafka", Config: map[string]any{
+				"brokers":         []any{"localhost:9092"},
+				"autoOffsetReset": "oldest",
+				"commitMode":      "manual",
+				"partitions":      []any{0, 1},
+			}},
+		},
+	}
+	err := ValidateConfig(cfg)
+	if err == nil {
+		t.Fatal("expected errors for invalid consumer options")
+	}
+	assertContains(t, err.Error(), `autoOffsetReset must be "earliest" or "latest"`)
+	assertContains(t, err.Error(), `commitMode must be "auto" or "manual-after-handler"`)
+	assertContains(t, err.Error(), "partitions must be a map")
+
+	cfg.Modules[0].Config = map[string]any{
+		"brokers":         []any{"localhost:9092"},
+		"autoOffsetReset": "earliest",
+		"commitMode":      "manual-after-handler",
+		"partitions":      map[string]any{"orders": []any{0, 1}},
+	}
+	if err := ValidateConfig(cfg, WithAllowNoEntryPoints()); err != nil {
+		t.Errorf("valid consumer options rejected: %v", err)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Multiple errors accumulation test
 // ---------------------------------------------------------------------------
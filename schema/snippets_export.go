@@ -187,3 +187,25 @@ func extractJetBrainsVars(lines []string) []jetbrainsVariable {
 	}
 	return vars
 }
+
+// ExportSnippetsNeovim returns the LSP snippet set as a Lua file for the
+// LuaSnip snippet engine. Sourcing the file registers the snippets for YAML
+// buffers.
+func ExportSnippetsNeovim() ([]byte, error) {
+	var b strings.Builder
+	b.WriteString("-- Workflow configuration snippets, generated by `wfctl snippets --format nvim`.\n")
+	b.WriteString("local ls = require(\"luasnip\")\n")
+	b.WriteString("local parse = ls.parser.parse_snippet\n\n")
+	b.WriteString("ls.add_snippets(\"yaml\", {\n")
+	for _, s := range GetLSPSnippets() {
+		fmt.Fprintf(&b, "  parse({ trig = %s, name = %s, dscr = %s }, %s),\n",
+			luaQuote(s.Prefix), luaQuote(s.Name), luaQuote(s.Description), luaQuote(strings.Join(s.Body, "\n")))
+	}
+	b.WriteString("})\n")
+	return []byte(b.String()), nil
+}
+
+// luaQuote returns s as a double-quoted Lua string literal.
+func luaQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`).Replace(s) + `"`
+}
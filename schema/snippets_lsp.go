@@ -0,0 +1,225 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Snippet scopes name the place in a workflow config where a snippet's body
+// can be inserted.
+const (
+	SnippetScopeTopLevel  = "top-level" // a top-level key
+	SnippetScopeModules   = "modules"   // an item of the modules list
+	SnippetScopePipelines = "pipelines" // a pipeline under pipelines
+	SnippetScopePipeline  = "pipeline"  // a key of a pipeline definition
+	SnippetScopeSteps     = "steps"     // an item of a pipeline's steps list
+)
+
+// LSPSnippetsVersion is the format version of the LSP snippet export.
+const LSPSnippetsVersion = 1
+
+// LSPSnippet is a snippet in the set served by the language server. Body uses
+// LSP snippet syntax (${N:placeholder}), which VSCode-style snippets share.
+type LSPSnippet struct {
+	Prefix      string   `json:"prefix"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Scope       string   `json:"scope"`
+	Body        []string `json:"body"`
+}
+
+// LSPSnippetSet is the JSON document written by ExportSnippetsLSP.
+type LSPSnippetSet struct {
+	Version  int          `json:"version"`
+	Snippets []LSPSnippet `json:"snippets"`
+}
+
+// GetLSPSnippets returns the canonical snippets followed by one generated
+// snippet per module and step type in the schema registries that has no
+// canonical snippet. Generated snippets fill in the type's required config
+// fields, using their defaults or options as placeholders.
+func GetLSPSnippets() []LSPSnippet {
+	canonical := GetSnippets()
+	out := make([]LSPSnippet, 0, len(canonical))
+	covered := make(map[string]bool)
+	prefixes := make(map[string]bool)
+	for _, s := range canonical {
+		prefixes[s.Prefix] = true
+		out = append(out, LSPSnippet{
+			Prefix:      s.Prefix,
+			Name:        s.Name,
+			Description: s.Description,
+			Scope:       snippetScope(s.Prefix),
+			Body:        append([]string(nil), s.Body...),
+		})
+		for _, line := range s.Body {
+			if t, ok := strings.CutPrefix(strings.TrimLeft(line, " -"), "type: "); ok {
+				covered[t] = true
+			}
+		}
+	}
+
+	modules := NewModuleSchemaRegistry().All()
+	sort.Slice(modules, func(i, j int) bool { return modules[i].Type < modules[j].Type })
+	for _, ms := range modules {
+		prefix := "mod-" + snippetSlug(ms.Type)
+		if covered[ms.Type] || prefixes[prefix] {
+			continue
+		}
+		prefixes[prefix] = true
+		out = append(out, LSPSnippet{
+			Prefix:      prefix,
+			Name:        "Module: " + ms.Type,
+			Description: ms.Description,
+			Scope:       SnippetScopeModules,
+			Body:        typeSnippetBody(snippetSlug(lastTypeSegment(ms.Type)), ms.Type, ms.ConfigFields),
+		})
+	}
+
+	steps := GetStepSchemaRegistry().All()
+	sort.Slice(steps, func(i, j int) bool { return steps[i].Type < steps[j].Type })
+	for _, ss := range steps {
+		name := snippetSlug(strings.TrimPrefix(ss.Type, "step."))
+		if covered[ss.Type] || prefixes["step-"+name] {
+			continue
+		}
+		prefixes["step-"+name] = true
+		out = append(out, LSPSnippet{
+			Prefix:      "step-" + name,
+			Name:        "Step: " + ss.Type,
+			Description: ss.Description,
+			Scope:       SnippetScopeSteps,
+			Body:        typeSnippetBody(name, ss.Type, ss.ConfigFields),
+		})
+	}
+	return out
+}
+
+// ExportSnippetsLSP returns the LSP snippet set as JSON. The language server
+// serves the same set and can load an edited copy of this export.
+func ExportSnippetsLSP() ([]byte, error) {
+	return json.MarshalIndent(LSPSnippetSet{Version: LSPSnippetsVersion, Snippets: GetLSPSnippets()}, "", "  ")
+}
+
+// ParseSnippetsLSP parses a snippet set written by ExportSnippetsLSP.
+func ParseSnippetsLSP(data []byte) ([]LSPSnippet, error) {
+	var set LSPSnippetSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("parse snippets: %w", err)
+	}
+	if set.Version != LSPSnippetsVersion {
+		return nil, fmt.Errorf("unsupported snippets version %d (want %d)", set.Version, LSPSnippetsVersion)
+	}
+	for i, s := range set.Snippets {
+		if s.Prefix == "" || len(s.Body) == 0 {
+			return nil, fmt.Errorf("snippets[%d]: prefix and body are required", i)
+		}
+		switch s.Scope {
+		case SnippetScopeTopLevel, SnippetScopeModules, SnippetScopePipelines, SnippetScopePipeline, SnippetScopeSteps:
+		default:
+			return nil, fmt.Errorf("snippets[%d] %q: unknown scope %q", i, s.Prefix, s.Scope)
+		}
+	}
+	return set.Snippets, nil
+}
+
+// snippetScope returns the scope of a canonical snippet from its prefix.
+func snippetScope(prefix string) string {
+	switch {
+	case strings.HasPrefix(prefix, "mod-"):
+		return SnippetScopeModules
+	case strings.HasPrefix(prefix, "step-"):
+		return SnippetScopeSteps
+	case strings.HasPrefix(prefix, "trigger-"):
+		return SnippetScopePipeline
+	case prefix == "pipeline":
+		return SnippetScopePipelines
+	default:
+		return SnippetScopeTopLevel
+	}
+}
+
+// typeSnippetBody builds a list-item snippet declaring a module or step of
+// the given type with its required config fields.
+func typeSnippetBody(name, typ string, fields []ConfigFieldDef) []string {
+	body := []string{
+		"- name: ${1:" + escapeSnippetText(name) + "}",
+		"  type: " + typ,
+	}
+	tab := 2
+	for i := range fields {
+		f := &fields[i]
+		if !f.Required {
+			continue
+		}
+		if tab == 2 {
+			body = append(body, "  config:")
+		}
+		body = append(body, fmt.Sprintf("    %s: %s", f.Key, fieldPlaceholder(tab, f)))
+		tab++
+	}
+	return body
+}
+
+// fieldPlaceholder returns the tab stop for a required config field.
+func fieldPlaceholder(tab int, f *ConfigFieldDef) string {
+	var text string
+	switch {
+	case f.DefaultValue != nil && isScalar(f.DefaultValue):
+		text = fmt.Sprint(f.DefaultValue)
+	case len(f.Options) > 0:
+		return fmt.Sprintf("${%d|%s|}", tab, strings.Join(escapeSnippetChoices(f.Options), ","))
+	case f.Placeholder != "":
+		text = f.Placeholder
+	default:
+		text = f.Key
+	}
+	if strings.Contains(text, "\n") {
+		text = f.Key
+	}
+	switch f.Type {
+	case FieldTypeArray:
+		return fmt.Sprintf("[${%d:%s}]", tab, escapeSnippetText(text))
+	case FieldTypeMap, FieldTypeJSON:
+		return fmt.Sprintf("{${%d}}", tab)
+	}
+	return fmt.Sprintf("${%d:%s}", tab, escapeSnippetText(text))
+}
+
+func isScalar(v any) bool {
+	switch v.(type) {
+	case string, bool, int, int64, float64:
+		return true
+	}
+	return false
+}
+
+// escapeSnippetText escapes the characters that are special inside a
+// snippet placeholder.
+func escapeSnippetText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `$`, `\$`, `}`, `\}`).Replace(s)
+}
+
+func escapeSnippetChoices(options []string) []string {
+	out := make([]string, len(options))
+	r := strings.NewReplacer(`\`, `\\`, `$`, `\$`, `}`, `\}`, `,`, `\,`, `|`, `\|`)
+	for i, o := range options {
+		out[i] = r.Replace(o)
+	}
+	return out
+}
+
+// snippetSlug turns a type name such as "storage.sqlite" or "db_query" into a
+// snippet prefix segment.
+func snippetSlug(s string) string {
+	return strings.NewReplacer(".", "-", "_", "-").Replace(s)
+}
+
+func lastTypeSegment(t string) string {
+	if i := strings.LastIndex(t, "."); i >= 0 {
+		return t[i+1:]
+	}
+	return t
+}
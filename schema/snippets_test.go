@@ -126,3 +126,80 @@ func TestExportSnippetsVSCode_BodyIsStringArray(t *testing.T) {
 		}
 	}
 }
+
+func TestGetLSPSnippets(t *testing.T) {
+	snips := GetLSPSnippets()
+	byPrefix := make(map[string]LSPSnippet, len(snips))
+	for _, s := range snips {
+		if _, dup := byPrefix[s.Prefix]; dup {
+			t.Errorf("duplicate prefix %q", s.Prefix)
+		}
+		byPrefix[s.Prefix] = s
+	}
+
+	scopes := map[string]string{
+		"mod-http-server":       SnippetScopeModules,
+		"step-set":              SnippetScopeSteps,
+		"trigger-http":          SnippetScopePipeline,
+		"pipeline":              SnippetScopePipelines,
+		"app":                   SnippetScopeTopLevel,
+		"workflow-http":         SnippetScopeTopLevel,
+		"mod-database-workflow": SnippetScopeModules,
+		"step-db-query-cached":  SnippetScopeSteps,
+	}
+	for prefix, scope := range scopes {
+		s, ok := byPrefix[prefix]
+		if !ok {
+			t.Errorf("missing snippet %q", prefix)
+			continue
+		}
+		if s.Scope != scope {
+			t.Errorf("snippet %q scope = %q, want %q", prefix, s.Scope, scope)
+		}
+	}
+
+	// Types with a canonical snippet are not generated again.
+	if _, ok := byPrefix["mod-storage-sqlite"]; ok {
+		t.Error("storage.sqlite has a canonical snippet but was generated")
+	}
+
+	// Generated snippets fill in required fields, offering options as choices.
+	got := strings.Join(byPrefix["mod-database-workflow"].Body, "\n")
+	want := "- name: ${1:workflow}\n  type: database.workflow\n  config:\n    driver: ${2|postgres,mysql,sqlite3|}"
+	if !strings.HasPrefix(got, want) {
+		t.Errorf("database.workflow body:\n%s\nwant prefix:\n%s", got, want)
+	}
+}
+
+func TestExportSnippetsLSP_RoundTrip(t *testing.T) {
+	data, err := ExportSnippetsLSP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	snips, err := ParseSnippetsLSP(data)
+	if err != nil {
+		t.Fatalf("ParseSnippetsLSP: %v", err)
+	}
+	if len(snips) != len(GetLSPSnippets()) {
+		t.Errorf("round trip has %d snippets, want %d", len(snips), len(GetLSPSnippets()))
+	}
+
+	if _, err := ParseSnippetsLSP([]byte(`{"version": 2, "snippets": []}`)); err == nil {
+		t.Error("expected error for unsupported version")
+	}
+}
+
+func TestExportSnippetsNeovim(t *testing.T) {
+	data, err := ExportSnippetsNeovim()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(data)
+	if !strings.Contains(s, `ls.add_snippets("yaml", {`) {
+		t.Error("nvim export should register yaml snippets with LuaSnip")
+	}
+	want := `parse({ trig = "mod-http-server", name = "HTTP Server Module", dscr = "HTTP server module listening on a configurable address" }, "- name: ${1:server}\n  type: http.server\n  config:\n    address: ${2::8080}"),`
+	if !strings.Contains(s, want) {
+		t.Errorf("nvim export missing http server snippet line %s", want)
+	}
+}
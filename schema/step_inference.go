@@ -277,6 +277,22 @@ func inferParallelOutputs(stepConfig map[string]any) []InferredOutput {
 		{Key: "errors", Type: "map", Description: "Map of branch_name → error string"},
 		{Key: "completed", Type: "integer", Description: "Count of successful branches"},
 		{Key: "failed", Type: "integer", Description: "Count of failed branches"},
+		{Key: "cancelled", Type: "[]string", Description: "Branches stopped by first_error: cancel_rest"},
+	}
+	// The branches form exposes each branch under its own name.
+	if branches, ok := stepConfig["branches"].(map[string]any); ok {
+		names := make([]string, 0, len(branches))
+		for name := range branches {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			outputs = append(outputs, InferredOutput{
+				Key:         name,
+				Type:        "(dynamic)",
+				Description: "Merged outputs of branch " + name,
+			})
+		}
 	}
 	// If steps are provided in config, list branch names
 	if stepsRaw, ok := stepConfig["steps"].([]any); ok {
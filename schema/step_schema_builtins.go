@@ -26,6 +26,29 @@ func (r *StepSchemaRegistry) registerBuiltins() {
 		},
 	})
 
+	r.Register(&StepSchema{
+		Type:        "step.audit",
+		Plugin:      "pipelinesteps",
+		Description: "Writes a structured audit entry (actor, action, resource, details) to the audit store. The entry is persisted when the step runs, so it survives failures in later steps.",
+		ConfigFields: []ConfigFieldDef{
+			{Key: "action", Type: FieldTypeString, Description: "Action being audited (template expressions supported)", Required: true},
+			{Key: "resource", Type: FieldTypeString, Description: "Resource acted upon, e.g. order:{{ .order_id }}"},
+			{Key: "actor", Type: FieldTypeString, Description: "Acting identity; defaults to the authenticated caller's claims or client certificate"},
+			{Key: "type", Type: FieldTypeString, Description: "Audit event type", DefaultValue: "business"},
+			{Key: "detail", Type: FieldTypeString, Description: "Human-readable detail message"},
+			{Key: "details", Type: FieldTypeMap, Description: "Structured details (values support templates)"},
+			{Key: "success", Type: FieldTypeBool, Description: "Whether the audited action succeeded (boolean or template)", DefaultValue: true},
+			{Key: "store", Type: FieldTypeString, Description: "Audit store service name; defaults to audit.logger, then stdout"},
+		},
+		Outputs: []StepOutputDef{
+			{Key: "recorded", Type: "boolean", Description: "Always true when the entry was persisted"},
+			{Key: "action", Type: "string", Description: "Resolved action"},
+			{Key: "actor", Type: "string", Description: "Resolved actor"},
+			{Key: "resource", Type: "string", Description: "Resolved resource"},
+			{Key: "timestamp", Type: "string", Description: "Entry timestamp (RFC 3339)"},
+		},
+	})
+
 	r.Register(&StepSchema{
 		Type:        "step.validate",
 		Plugin:      "pipelinesteps",
@@ -34,6 +57,7 @@ func (r *StepSchemaRegistry) registerBuiltins() {
 			{Key: "rules", Type: FieldTypeMap, Description: "Validation rules per field"},
 			{Key: "required", Type: FieldTypeArray, Description: "List of required field names"},
 			{Key: "schema", Type: FieldTypeString, Description: "JSON Schema for request body validation"},
+			{Key: "constraints", Type: FieldTypeArray, Description: "Cross-field rules: one_of, required_together, mutually_exclusive and required_if ({field, equals, fields})"},
 		},
 		Outputs: []StepOutputDef{
 			{Key: "valid", Type: "boolean", Description: "Whether validation passed"},
@@ -43,13 +67,14 @@ func (r *StepSchemaRegistry) registerBuiltins() {
 	r.Register(&StepSchema{
 		Type:        "step.transform",
 		Plugin:      "pipelinesteps",
-		Description: "Transforms pipeline context values using field mapping or template expressions.",
+		Description: "Transforms pipeline data with a sequence of operations: extract, map, filter, convert, rename (deep key renaming), default (fill missing keys), pick, and omit.",
 		ConfigFields: []ConfigFieldDef{
-			{Key: "mapping", Type: FieldTypeMap, Description: "Field mapping from source to target keys"},
-			{Key: "template", Type: FieldTypeString, Description: "Go template string for complex transformations"},
+			{Key: "transformer", Type: FieldTypeString, Description: "Name of a DataTransformer service to use"},
+			{Key: "pipeline", Type: FieldTypeString, Description: "Named pipeline within the transformer"},
+			{Key: "operations", Type: FieldTypeArray, Description: "Inline operations applied in order, each {type, config}. rename takes mappings (old → new) and deep (default true); default takes values; pick and omit take fields, which may be dot-separated paths"},
 		},
 		Outputs: []StepOutputDef{
-			{Key: "(dynamic)", Type: "any", Description: "Output keys match the mapping target keys or template result"},
+			{Key: "data", Type: "any", Description: "Result of the last operation"},
 		},
 	})
 
@@ -69,6 +94,22 @@ func (r *StepSchemaRegistry) registerBuiltins() {
 		},
 	})
 
+	r.Register(&StepSchema{
+		Type:        "step.assert",
+		Plugin:      "pipelinesteps",
+		Description: "Checks invariants of the pipeline context: evaluates each assertion's if condition in order and fails the pipeline with the first violated assertion's message, or logs violations and continues with warn_only.",
+		ConfigFields: []ConfigFieldDef{
+			{Key: "assertions", Type: FieldTypeArray, Description: "Ordered assertions, each with an if condition (as in step.conditional) and an optional templated message", Required: true},
+			{Key: "status", Type: FieldTypeNumber, Description: "HTTP status of the error when an assertion fails", DefaultValue: 500},
+			{Key: "warn_only", Type: FieldTypeBool, Description: "Log violated assertions and continue instead of failing"},
+		},
+		Outputs: []StepOutputDef{
+			{Key: "passed", Type: "boolean", Description: "True when every assertion held"},
+			{Key: "checked", Type: "number", Description: "Number of assertions evaluated"},
+			{Key: "violations", Type: "[]string", Description: "Messages of the violated assertions (warn_only only; otherwise the step fails)"},
+		},
+	})
+
 	r.Register(&StepSchema{
 		Type:        "step.http_call",
 		Plugin:      "pipelinesteps",
@@ -92,6 +133,28 @@ func (r *StepSchemaRegistry) registerBuiltins() {
 		},
 	})
 
+	r.Register(&StepSchema{
+		Type:        "step.api_call",
+		Plugin:      "pipelinesteps",
+		Description: "Calls an operation of an openapi.consumer by operationId. Parameters and body are validated against the spec before sending and the response against the schema declared for its status; spec violations and transport failures are reported as distinct errors.",
+		ConfigFields: []ConfigFieldDef{
+			{Key: "consumer", Type: FieldTypeString, Description: "Name of the openapi.consumer module", Required: true},
+			{Key: "operation", Type: FieldTypeString, Description: "operationId from the consumer's spec (unknown operations fail the build)", Required: true},
+			{Key: "params", Type: FieldTypeMap, Description: "Path, query and header parameters by name (template expressions supported)"},
+			{Key: "body", Type: FieldTypeMap, Description: "Request body (template expressions supported; strings are converted to the numbers and booleans the schema declares)"},
+			{Key: "body_from", Type: FieldTypeString, Description: "Dotted path to the request body in the pipeline context"},
+			{Key: "timeout", Type: FieldTypeDuration, Description: "Request timeout duration (e.g. 30s)"},
+			{Key: "error_on_status", Type: FieldTypeBool, Description: "When true (default), 4xx/5xx responses fail the step after they pass response validation", DefaultValue: "true"},
+		},
+		Outputs: []StepOutputDef{
+			{Key: "status_code", Type: "number", Description: "HTTP response status code"},
+			{Key: "status", Type: "string", Description: "HTTP response status text"},
+			{Key: "headers", Type: "map", Description: "Response headers"},
+			{Key: "body", Type: "any", Description: "Response body, validated against the declared response schema"},
+			{Key: "operation", Type: "string", Description: "operationId that was called"},
+		},
+	})
+
 	r.Register(&StepSchema{
 		Type:        "step.json_response",
 		Plugin:      "pipelinesteps",
@@ -102,6 +165,7 @@ func (r *StepSchemaRegistry) registerBuiltins() {
 			{Key: "body", Type: FieldTypeMap, Description: "Response body (static JSON object or template expression)"},
 			{Key: "body_from", Type: FieldTypeString, Description: "Dotted path to resolve body from pipeline context or step outputs; use '.' for the full current context (e.g. 'steps.query.rows')"},
 			{Key: "headers", Type: FieldTypeMap, Description: "Additional response headers"},
+			{Key: "problem", Type: FieldTypeMap, Description: "Send an application/problem+json error instead of body: code (catalog code such as not_found, validation_failed, pipeline_error), detail (template), title (template), errors_from (dotted path). Status defaults to the code's status."},
 		},
 		Outputs: []StepOutputDef{
 			{Key: "sent", Type: "boolean", Description: "Whether the response was sent successfully"},
@@ -118,6 +182,7 @@ func (r *StepSchemaRegistry) registerBuiltins() {
 			{Key: "body", Type: FieldTypeMap, Description: "Response body (static JSON object or template expression)"},
 			{Key: "body_from", Type: FieldTypeString, Description: "Dotted path to resolve body from pipeline context or step outputs; use '.' for the full current context (e.g. 'steps.query.rows')"},
 			{Key: "headers", Type: FieldTypeMap, Description: "Additional response headers"},
+			{Key: "problem", Type: FieldTypeMap, Description: "Send an application/problem+json error instead of body: code (catalog code such as not_found, validation_failed, pipeline_error), detail (template), title (template), errors_from (dotted path). Status defaults to the code's status."},
 		},
 		Outputs: []StepOutputDef{
 			{Key: "sent", Type: "boolean", Description: "Whether the response was sent successfully"},
@@ -250,17 +315,61 @@ func (r *StepSchemaRegistry) registerBuiltins() {
 		},
 	})
 
+	r.Register(&StepSchema{
+		Type:        "step.switch",
+		Plugin:      "pipelinesteps",
+		Description: "Ordered switch/case: runs the inline sub-pipeline of the first case whose value or if condition matches, continuing into the next case when the case sets fallthrough, or the default sub-pipeline when no case matches.",
+		ConfigFields: []ConfigFieldDef{
+			{Key: "field", Type: FieldTypeString, Description: "Dot-path field compared with the value of value cases"},
+			{Key: "cases", Type: FieldTypeArray, Description: "Ordered cases, each with a value or an if condition, an optional name, a steps list and an optional fallthrough flag", Required: true},
+			{Key: "default", Type: FieldTypeArray, Description: "Sub-steps to run when no case matches, or after fallthrough from the last case"},
+			{Key: "merge_step", Type: FieldTypeString, Description: "Step name to jump to after the switch completes (empty = continue sequentially)"},
+		},
+		Outputs: []StepOutputDef{
+			{Key: "case", Type: "string", Description: "Name of the matched case, or default"},
+			{Key: "executed_cases", Type: "[]string", Description: "Cases whose steps ran, in order, including those reached by fallthrough"},
+			{Key: "matched_value", Type: "string", Description: "Resolved field value (only when field is set)"},
+			{Key: "used_default", Type: "boolean", Description: "True when no case matched"},
+		},
+	})
+
+	r.Register(&StepSchema{
+		Type:        "step.retry",
+		Plugin:      "pipelinesteps",
+		Description: "Runs an inline sub-pipeline and re-runs the whole block on failure, or while retry_if is truthy, with exponential backoff and jitter. Side effects of failed attempts are not undone.",
+		ConfigFields: []ConfigFieldDef{
+			{Key: "steps", Type: FieldTypeArray, Description: "Child steps run in order as one attempt", Required: true},
+			{Key: "max_attempts", Type: FieldTypeNumber, Description: "Maximum number of attempts, including the first", DefaultValue: 3},
+			{Key: "initial_delay", Type: FieldTypeDuration, Description: "Delay before the second attempt", DefaultValue: "100ms"},
+			{Key: "max_delay", Type: FieldTypeDuration, Description: "Upper bound for the delay between attempts", DefaultValue: "10s"},
+			{Key: "multiplier", Type: FieldTypeNumber, Description: "Factor applied to the delay after each attempt (>= 1)", DefaultValue: 2},
+			{Key: "jitter", Type: FieldTypeNumber, Description: "Randomize each delay by up to this fraction of itself (0-1)", DefaultValue: 0},
+			{Key: "retry_if", Type: FieldTypeString, Description: "Condition evaluated after every attempt; retry while truthy. Sees the attempt's step outputs and .retry.attempt, .retry.failed, .retry.error. Default: retry when a child step fails"},
+		},
+		Outputs: []StepOutputDef{
+			{Key: "attempts", Type: "integer", Description: "Number of attempts made"},
+			{Key: "retried", Type: "boolean", Description: "True when more than one attempt ran"},
+			{Key: "(children)", Type: "any", Description: "Outputs of the child steps from the successful attempt, under their own step names"},
+		},
+	})
+
 	r.Register(&StepSchema{
 		Type:        "step.parallel",
 		Plugin:      "pipelinesteps",
-		Description: "Execute multiple named sub-steps concurrently and collect results. Time: O(max(branch)). Space: O(branches × context_size).",
+		Description: "Execute named branches of steps concurrently, each on an isolated copy of the context, and merge their outputs. Time: O(max(branch)). Space: O(branches × context_size).",
 		ConfigFields: []ConfigFieldDef{
-			{Key: "steps", Type: FieldTypeArray, Required: true, Description: "List of sub-steps to run concurrently. Each must have a unique 'name'."},
-			{Key: "error_strategy", Type: FieldTypeSelect, Required: false, DefaultValue: "fail_fast", Options: []string{"fail_fast", "collect_errors"}, Description: "fail_fast: cancel on first error. collect_errors: run all, collect partial results."},
+			{Key: "branches", Type: FieldTypeMap, Description: "Map of branch name to a list of steps, or to {steps, timeout}. Each branch's merged outputs appear under steps.<name>.<branch>. Mutually exclusive with steps."},
+			{Key: "steps", Type: FieldTypeArray, Description: "Legacy form: list of sub-steps, each run as its own branch. Each must have a unique 'name'."},
+			{Key: "error_strategy", Type: FieldTypeSelect, Required: false, DefaultValue: "fail_fast", Options: []string{"fail_fast", "collect_errors"}, Description: "fail_fast: fail when any branch fails. collect_errors: succeed with partial results unless every branch fails."},
+			{Key: "first_error", Type: FieldTypeSelect, Options: []string{"wait_all", "cancel_rest"}, Description: "wait_all: let every branch finish before reporting failures. cancel_rest: cancel the other branches on the first failure. Default: wait_all (cancel_rest for the steps form with fail_fast)"},
+			{Key: "timeout", Type: FieldTypeDuration, Description: "Timeout applied to each branch; a branch's own timeout overrides it"},
+			{Key: "max_concurrency", Type: FieldTypeNumber, Description: "Maximum number of branches running at once (0 = unlimited)"},
 		},
 		Outputs: []StepOutputDef{
+			{Key: "(dynamic)", Type: "map", Description: "Branches form: merged outputs of each successful branch under its name"},
 			{Key: "results", Type: "map", Description: "Map of branch_name → branch output (successful branches)"},
 			{Key: "errors", Type: "map", Description: "Map of branch_name → error string (failed branches)"},
+			{Key: "cancelled", Type: "[]string", Description: "Branches stopped by first_error: cancel_rest"},
 			{Key: "completed", Type: "integer", Description: "Count of successful branches"},
 			{Key: "failed", Type: "integer", Description: "Count of failed branches"},
 		},
@@ -273,9 +382,39 @@ func (r *StepSchemaRegistry) registerBuiltins() {
 		ConfigFields: []ConfigFieldDef{
 			{Key: "service", Type: FieldTypeString, Description: "Name of the service module to delegate to", Required: true},
 			{Key: "action", Type: FieldTypeString, Description: "Action to invoke on the service"},
+			{Key: "capture", Type: FieldTypeBool, Description: "Buffer the delegate's response into the step output instead of writing it to the client, so later steps can transform it"},
+			{Key: "body", Type: FieldTypeMap, Description: "Request body forwarded to the delegate; string values are templates"},
+			{Key: "body_from", Type: FieldTypeString, Description: "Dotted path whose value is forwarded as the request body (e.g. steps.transform.order)"},
+			{Key: "headers", Type: FieldTypeMap, Description: "Headers set on the forwarded request; values are templates"},
 		},
 		Outputs: []StepOutputDef{
-			{Key: "(dynamic)", Type: "any", Description: "Output depends on the delegated service"},
+			{Key: "delegated_to", Type: "string", Description: "Name of the service the request was delegated to"},
+			{Key: "status_code", Type: "integer", Description: "Status of the captured response (capture mode or no live request)"},
+			{Key: "headers", Type: "map", Description: "Headers of the captured response"},
+			{Key: "body", Type: "any", Description: "Body of the captured response, parsed as JSON when possible"},
+		},
+	})
+
+	r.Register(&StepSchema{
+		Type:        "step.service_call",
+		Plugin:      "pipelinesteps",
+		Description: "Calls a route of another module's HTTP handler in-process, without a network round-trip.",
+		ConfigFields: []ConfigFieldDef{
+			{Key: "service", Type: FieldTypeString, Description: "Name of the module whose HTTP handler is called (e.g. an http.router)", Required: true},
+			{Key: "method", Type: FieldTypeString, Description: "HTTP method", DefaultValue: "GET"},
+			{Key: "path", Type: FieldTypeString, Description: "Request path and query (template expressions supported)", Required: true},
+			{Key: "body", Type: FieldTypeMap, Description: "Request body sent as JSON; string values are templates"},
+			{Key: "body_from", Type: FieldTypeString, Description: "Dotted path whose value is sent as the request body"},
+			{Key: "headers", Type: FieldTypeMap, Description: "Request headers; values are templates"},
+			{Key: "forward_headers", Type: FieldTypeArray, ArrayItemType: "string", Description: "Headers copied from the current request, e.g. Authorization"},
+			{Key: "error_on_status", Type: FieldTypeBool, Description: "Fail the step on a 4xx/5xx response", DefaultValue: true},
+			{Key: "max_depth", Type: FieldTypeNumber, Description: "How deeply service calls may nest before failing", DefaultValue: 8},
+		},
+		Outputs: []StepOutputDef{
+			{Key: "status_code", Type: "integer", Description: "HTTP status code of the response"},
+			{Key: "status", Type: "string", Description: "HTTP status text"},
+			{Key: "headers", Type: "map", Description: "Response headers"},
+			{Key: "body", Type: "any", Description: "Response body, parsed as JSON when possible"},
 		},
 	})
 
@@ -294,6 +433,28 @@ func (r *StepSchemaRegistry) registerBuiltins() {
 		},
 	})
 
+	r.Register(&StepSchema{
+		Type:        "step.enqueue",
+		Plugin:      "pipelinesteps",
+		Description: "Defers work to a jobqueue.service: stores a target pipeline and its input on the queue and returns immediately. The queue's workers run the pipeline in the background.",
+		ConfigFields: []ConfigFieldDef{
+			{Key: "pipeline", Type: FieldTypeString, Description: "Pipeline to run (template expressions supported)", Required: true},
+			{Key: "queue", Type: FieldTypeString, Description: "jobqueue.service module name (optional when only one is configured)"},
+			{Key: "data", Type: FieldTypeMap, Description: "Projection of the context passed to the pipeline (defaults to the whole current context)"},
+			{Key: "delay", Type: FieldTypeDuration, Description: "Delay before the job may run, e.g. 10m (template expressions supported)"},
+			{Key: "priority", Type: FieldTypeNumber, Description: "Job priority; higher runs first", DefaultValue: 0},
+		},
+		Outputs: []StepOutputDef{
+			{Key: "enqueued", Type: "boolean", Description: "Always true when the job was stored"},
+			{Key: "job_id", Type: "string", Description: "Job ID"},
+			{Key: "status", Type: "string", Description: "Job status: pending"},
+			{Key: "status_path", Type: "string", Description: "Path of the job status endpoint when the queue sets statusPath"},
+			{Key: "pipeline", Type: "string", Description: "Resolved target pipeline"},
+			{Key: "priority", Type: "number", Description: "Job priority"},
+			{Key: "run_at", Type: "string", Description: "Earliest run time (RFC 3339)"},
+		},
+	})
+
 	r.Register(&StepSchema{
 		Type:        "step.event_publish",
 		Plugin:      "pipelinesteps",
@@ -494,6 +655,20 @@ func (r *StepSchemaRegistry) registerBuiltins() {
 		},
 	})
 
+	r.Register(&StepSchema{
+		Type:        "step.localize",
+		Plugin:      "pipelinesteps",
+		Description: "Translates catalog messages from the locales: section into the execution's locale.",
+		ConfigFields: []ConfigFieldDef{
+			{Key: "messages", Type: FieldTypeMap, Description: "Output name to message key, or to a map with key and args (placeholder values, templates allowed; count selects the plural form)", Required: true},
+			{Key: "locale", Type: FieldTypeString, Description: "Locale to switch the execution to (template), e.g. a language stored on the user; resolved against the catalogs like Accept-Language"},
+		},
+		Outputs: []StepOutputDef{
+			{Key: "locale", Type: "string", Description: "Locale the messages were translated into"},
+			{Key: "messages", Type: "map", Description: "Translated text keyed by output name"},
+		},
+	})
+
 	r.Register(&StepSchema{
 		Type:        "step.validate_request_body",
 		Plugin:      "pipelinesteps",
@@ -507,6 +682,38 @@ func (r *StepSchemaRegistry) registerBuiltins() {
 		},
 	})
 
+	r.Register(&StepSchema{
+		Type:        "step.validate_response",
+		Plugin:      "pipelinesteps",
+		Description: "Validates the pending response body against a JSON Schema before it is sent.",
+		ConfigFields: []ConfigFieldDef{
+			{Key: "schema", Type: FieldTypeMap, Description: "JSON Schema the response body must conform to", Required: true},
+			{Key: "body_from", Type: FieldTypeString, Description: "Dotted path to the response body; defaults to the response_body context key"},
+			{Key: "warn_only", Type: FieldTypeBool, Description: "Log violations instead of failing the pipeline"},
+		},
+		Outputs: []StepOutputDef{
+			{Key: "valid", Type: "boolean", Description: "Whether the response passed validation"},
+			{Key: "error", Type: "string", Description: "Violation detail (warn_only mode only)"},
+		},
+	})
+
+	r.Register(&StepSchema{
+		Type:        "step.schema_validate",
+		Plugin:      "pipelinesteps",
+		Description: "Validates pipeline data against a named JSON Schema registered in a schema.registry module. The schema is looked up on every run, so updating it in the registry updates every step that references it.",
+		ConfigFields: []ConfigFieldDef{
+			{Key: "registry", Type: FieldTypeString, Description: "Name of the schema.registry module", Required: true},
+			{Key: "schema", Type: FieldTypeString, Description: "Schema name, or name@version to pin a version; a bare name uses the latest version", Required: true},
+			{Key: "source", Type: FieldTypeString, Description: "Dotted path to the data to validate, e.g. steps.parse.body; defaults to the current pipeline context"},
+			{Key: "on_invalid", Type: FieldTypeSelect, Options: []string{"error", "respond", "continue"}, DefaultValue: "error", Description: "On violations: fail with a 400 validation_failed problem, respond 400 and stop, or continue with valid false"},
+		},
+		Outputs: []StepOutputDef{
+			{Key: "valid", Type: "boolean", Description: "Whether the data matches the schema"},
+			{Key: "schema", Type: "string", Description: "Schema version used, as name@version"},
+			{Key: "errors", Type: "[]string", Description: "Violations, each as <location>: <message>"},
+		},
+	})
+
 	r.Register(&StepSchema{
 		Type:        "step.dlq_send",
 		Plugin:      "pipelinesteps",
@@ -523,6 +730,27 @@ func (r *StepSchemaRegistry) registerBuiltins() {
 		},
 	})
 
+	r.Register(&StepSchema{
+		Type:        "step.dlq_publish",
+		Plugin:      "dlq",
+		Description: "Adds the current record to a dlq.service (or any DLQ store) with a reason and its source, so rejected input is queued for inspection and reprocessing.",
+		ConfigFields: []ConfigFieldDef{
+			{Key: "reason", Type: FieldTypeString, Description: "Why the record was rejected (template expressions supported)", Required: true},
+			{Key: "dlq", Type: FieldTypeString, Description: "dlq.service module or DLQ store service name (optional when only one is configured)"},
+			{Key: "error_type", Type: FieldTypeString, Description: "Error type stored on the entry (template expressions supported)", DefaultValue: "rejected"},
+			{Key: "payload", Type: FieldTypeMap, Description: "Projection of the context to store (defaults to the whole current context)"},
+			{Key: "source", Type: FieldTypeString, Description: "Identity of the record's source, e.g. topic and key (template expressions supported)"},
+			{Key: "metadata", Type: FieldTypeMap, Description: "Extra metadata stored on the entry (template expressions supported)"},
+			{Key: "max_retries", Type: FieldTypeNumber, Description: "Retry limit recorded on the entry", DefaultValue: 3},
+		},
+		Outputs: []StepOutputDef{
+			{Key: "queued", Type: "boolean", Description: "Always true when the entry was stored"},
+			{Key: "dlq_id", Type: "string", Description: "ID of the DLQ entry"},
+			{Key: "reason", Type: "string", Description: "Resolved reason"},
+			{Key: "error_type", Type: "string", Description: "Resolved error type"},
+		},
+	})
+
 	r.Register(&StepSchema{
 		Type:        "step.dlq_replay",
 		Plugin:      "pipelinesteps",
@@ -571,10 +799,14 @@ func (r *StepSchemaRegistry) registerBuiltins() {
 	r.Register(&StepSchema{
 		Type:        "step.nosql_query",
 		Plugin:      "datastores",
-		Description: "Queries documents from a NoSQL store by key prefix.",
+		Description: "Queries documents from a NoSQL store by key prefix or partition/sort key.",
 		ConfigFields: []ConfigFieldDef{
 			{Key: "store", Type: FieldTypeString, Description: "NoSQL store module name", Required: true},
 			{Key: "prefix", Type: FieldTypeString, Description: "Key prefix to filter documents"},
+			{Key: "partitionKey", Type: FieldTypeString, Description: "Partition key value to query (nosql.dynamodb)"},
+			{Key: "sortKeyPrefix", Type: FieldTypeString, Description: "Sort key prefix within the partition (nosql.dynamodb)"},
+			{Key: "descending", Type: FieldTypeBool, Description: "Return a partition in descending sort key order"},
+			{Key: "limit", Type: FieldTypeNumber, Description: "Maximum number of documents to return"},
 			{Key: "output", Type: FieldTypeString, Description: "Context key to store results"},
 		},
 		Outputs: []StepOutputDef{
@@ -771,7 +1003,7 @@ func (r *StepSchemaRegistry) registerBuiltins() {
 			{Key: "content", Type: "string", Description: "Generated text"},
 			{Key: "model", Type: "string", Description: "Model used"},
 			{Key: "finish_reason", Type: "string", Description: "Completion finish reason"},
-			{Key: "usage", Type: "map", Description: "Token usage (input_tokens, output_tokens)"},
+			{Key: "usage", Type: "map", Description: "Token usage and priced cost (input_tokens, output_tokens, cost)"},
 		},
 	})
 
@@ -791,7 +1023,7 @@ func (r *StepSchemaRegistry) registerBuiltins() {
 			{Key: "category", Type: "string", Description: "Predicted category"},
 			{Key: "confidence", Type: "number", Description: "Confidence score (0-1)"},
 			{Key: "reasoning", Type: "string", Description: "Explanation of classification"},
-			{Key: "usage", Type: "map", Description: "Token usage stats"},
+			{Key: "usage", Type: "map", Description: "Token usage and priced cost (input_tokens, output_tokens, cost)"},
 		},
 	})
 
@@ -806,11 +1038,14 @@ func (r *StepSchemaRegistry) registerBuiltins() {
 			{Key: "input_from", Type: FieldTypeString, Description: "Dot-path to input text"},
 			{Key: "max_tokens", Type: FieldTypeNumber, Description: "Token limit", DefaultValue: 1024},
 			{Key: "temperature", Type: FieldTypeNumber, Description: "Temperature parameter"},
+			{Key: "max_schema_retries", Type: FieldTypeNumber, Description: "Retries with a corrective prompt when the output does not match the schema", DefaultValue: 2},
+			{Key: "coerce", Type: FieldTypeBool, Description: "Fix trivially coercible type mismatches (numeric strings, yes/no booleans) instead of retrying", DefaultValue: false},
 		},
 		Outputs: []StepOutputDef{
-			{Key: "extracted", Type: "map", Description: "Extracted structured data"},
+			{Key: "extracted", Type: "map", Description: "Extracted structured data, validated against the schema"},
 			{Key: "method", Type: "string", Description: "Extraction method used"},
-			{Key: "usage", Type: "map", Description: "Token usage"},
+			{Key: "attempts", Type: "number", Description: "Number of model calls, including schema retries"},
+			{Key: "usage", Type: "map", Description: "Token usage and priced cost summed across attempts (input_tokens, output_tokens, cost)"},
 		},
 	})
 
@@ -992,6 +1227,7 @@ func (r *StepSchemaRegistry) registerBuiltins() {
 		ConfigFields: []ConfigFieldDef{
 			{Key: "target_image", Type: FieldTypeString, Description: "Image to scan", Required: true},
 			{Key: "fail_on_severity", Type: FieldTypeSelect, Description: "Minimum severity to fail", Options: []string{"critical", "high", "medium", "low"}},
+			{Key: "sbom", Type: FieldTypeString, Description: "SBOM of the image to scan instead of the image, e.g. from step.sbom_generate"},
 		},
 		Outputs: []StepOutputDef{
 			{Key: "vulnerabilities", Type: "[]any", Description: "Found vulnerabilities"},
@@ -1006,6 +1242,7 @@ func (r *StepSchemaRegistry) registerBuiltins() {
 		ConfigFields: []ConfigFieldDef{
 			{Key: "manifest_path", Type: FieldTypeString, Description: "Package manifest file path"},
 			{Key: "fail_on_severity", Type: FieldTypeSelect, Description: "Minimum severity to fail", Options: []string{"critical", "high", "medium", "low"}},
+			{Key: "sbom", Type: FieldTypeString, Description: "SBOM to scan instead of the source tree, e.g. from step.sbom_generate"},
 		},
 		Outputs: []StepOutputDef{
 			{Key: "vulnerabilities", Type: "[]any", Description: "Vulnerable dependencies found"},
@@ -1013,6 +1250,82 @@ func (r *StepSchemaRegistry) registerBuiltins() {
 		},
 	})
 
+	r.Register(&StepSchema{
+		Type:        "step.test_report",
+		Plugin:      "cicd",
+		Description: "Parses JUnit XML or go test -json results, stores the report as an artifact, and fails when failures exceed max_failures.",
+		ConfigFields: []ConfigFieldDef{
+			{Key: "path", Type: FieldTypeString, Description: "Results file or glob"},
+			{Key: "artifact", Type: FieldTypeString, Description: "Artifact key of the current execution to read results from"},
+			{Key: "from", Type: FieldTypeString, Description: "Pipeline context path holding the results text"},
+			{Key: "format", Type: FieldTypeSelect, Description: "Results format", Options: []string{"auto", "junit", "go-test-json"}, DefaultValue: "auto"},
+			{Key: "max_failures", Type: FieldTypeNumber, Description: "Failures tolerated before the step fails", DefaultValue: 0},
+			{Key: "min_tests", Type: FieldTypeNumber, Description: "Minimum number of tests that must run", DefaultValue: 0},
+			{Key: "report_key", Type: FieldTypeString, Description: "Artifact key for the JSON report (empty to skip)", DefaultValue: "test-report.json"},
+		},
+		Outputs: []StepOutputDef{
+			{Key: "passed", Type: "boolean", Description: "Whether the gate passed"},
+			{Key: "format", Type: "string", Description: "Parsed format"},
+			{Key: "summary", Type: "map", Description: "Counts (total, passed, failed, skipped) and duration_seconds"},
+			{Key: "suites", Type: "[]any", Description: "Per-suite or per-package summaries"},
+			{Key: "failures", Type: "[]any", Description: "Failed tests with suite, test, message and duration_seconds"},
+			{Key: "report_artifact", Type: "string", Description: "Artifact key of the stored report, when stored"},
+		},
+	})
+
+	r.Register(&StepSchema{
+		Type:        "step.sbom_generate",
+		Plugin:      "cicd",
+		Description: "Generates a CycloneDX or SPDX JSON SBOM for a source path or image with syft or the built-in Go generator, and stores it as an artifact.",
+		ConfigFields: []ConfigFieldDef{
+			{Key: "source_path", Type: FieldTypeString, Description: "Source tree to describe"},
+			{Key: "image", Type: FieldTypeString, Description: "Image to describe (syft only)"},
+			{Key: "generator", Type: FieldTypeSelect, Description: "SBOM generator", Options: []string{"syft", "builtin"}, DefaultValue: "syft"},
+			{Key: "format", Type: FieldTypeSelect, Description: "SBOM format", Options: []string{"cyclonedx-json", "spdx-json"}, DefaultValue: "cyclonedx-json"},
+			{Key: "output_path", Type: FieldTypeString, Description: "File to write the SBOM to (default: a temporary file)"},
+			{Key: "artifact_key", Type: FieldTypeString, Description: "Artifact key for the SBOM (empty to skip)"},
+		},
+		Outputs: []StepOutputDef{
+			{Key: "path", Type: "string", Description: "SBOM file path"},
+			{Key: "format", Type: "string", Description: "SBOM format"},
+			{Key: "generator", Type: "string", Description: "Generator used"},
+			{Key: "target", Type: "string", Description: "Described source path or image"},
+			{Key: "sha256", Type: "string", Description: "SHA-256 of the SBOM"},
+			{Key: "size", Type: "number", Description: "SBOM size in bytes"},
+			{Key: "components", Type: "number", Description: "Number of components (CycloneDX) or packages (SPDX)"},
+			{Key: "artifact", Type: "string", Description: "Artifact key of the stored SBOM, when stored"},
+		},
+	})
+
+	r.Register(&StepSchema{
+		Type:        "step.attest",
+		Plugin:      "cicd",
+		Description: "Creates an in-toto SLSA provenance statement for a built artifact, signs it into a DSSE envelope, stores it as an artifact and optionally attaches it to the image with cosign.",
+		ConfigFields: []ConfigFieldDef{
+			{Key: "subject", Type: FieldTypeString, Description: "Attested artifact name or image@sha256 reference", Required: true},
+			{Key: "digest", Type: FieldTypeString, Description: "sha256 digest of the subject"},
+			{Key: "signing_key_provider", Type: FieldTypeString, Description: "Secrets provider holding the signing key", Required: true},
+			{Key: "signing_key", Type: FieldTypeString, Description: "Secret holding the PEM private key", Required: true},
+			{Key: "key_id", Type: FieldTypeString, Description: "Key ID recorded on the signature"},
+			{Key: "builder_id", Type: FieldTypeString, Description: "SLSA builder ID", DefaultValue: "https://github.com/GoCodeAlone/workflow"},
+			{Key: "materials", Type: FieldTypeArray, Description: "Extra materials with name, uri and digest"},
+			{Key: "include_artifacts", Type: FieldTypeBool, Description: "List execution artifacts as materials", DefaultValue: true},
+			{Key: "artifact_key", Type: FieldTypeString, Description: "Artifact key for the envelope (empty to skip)", DefaultValue: "provenance.intoto.json"},
+			{Key: "output_path", Type: FieldTypeString, Description: "File to write the envelope to"},
+			{Key: "push", Type: FieldTypeBool, Description: "Attach the attestation with cosign"},
+		},
+		Outputs: []StepOutputDef{
+			{Key: "subject", Type: "string", Description: "Attested subject name"},
+			{Key: "digest", Type: "string", Description: "Attested subject digest"},
+			{Key: "predicate_type", Type: "string", Description: "SLSA provenance predicate type"},
+			{Key: "materials", Type: "number", Description: "Number of materials recorded"},
+			{Key: "statement", Type: "map", Description: "The unsigned in-toto statement"},
+			{Key: "artifact", Type: "string", Description: "Artifact key of the stored envelope, when stored"},
+			{Key: "path", Type: "string", Description: "Envelope file path, when written"},
+			{Key: "pushed", Type: "boolean", Description: "Whether the attestation was attached to the image"},
+		},
+	})
+
 	// --- Platform plugin steps ---
 
 	r.Register(&StepSchema{
@@ -1116,6 +1429,69 @@ func (r *StepSchemaRegistry) registerBuiltins() {
 		},
 	})
 
+	r.Register(&StepSchema{
+		Type:        "step.session_get",
+		Plugin:      "http",
+		Description: "Reads a value, or all values, from the request's http.session.",
+		ConfigFields: []ConfigFieldDef{
+			{Key: "key", Type: FieldTypeString, Description: "Session key to read (template expressions supported); omit to read all values"},
+			{Key: "delete", Type: FieldTypeBool, Description: "Remove the key once read (flash messages)"},
+			{Key: "session", Type: FieldTypeString, Description: "http.session module name (default: the innermost on the route)"},
+		},
+		Outputs: []StepOutputDef{
+			{Key: "value", Type: "any", Description: "The value under key (nil if not set)"},
+			{Key: "found", Type: "boolean", Description: "Whether key was set"},
+			{Key: "values", Type: "map", Description: "All session values (when key is omitted)"},
+			{Key: "is_new", Type: "boolean", Description: "Whether the request had no valid session (when key is omitted)"},
+		},
+	})
+
+	r.Register(&StepSchema{
+		Type:        "step.session_set",
+		Plugin:      "http",
+		Description: "Writes and deletes session values and can regenerate the session ID.",
+		ConfigFields: []ConfigFieldDef{
+			{Key: "values", Type: FieldTypeMap, Description: "Values to store (template expressions supported)"},
+			{Key: "delete", Type: FieldTypeArray, Description: "Keys to remove"},
+			{Key: "regenerate", Type: FieldTypeBool, Description: "Give the session a new ID after a privilege change"},
+			{Key: "session", Type: FieldTypeString, Description: "http.session module name (default: the innermost on the route)"},
+		},
+		Outputs: []StepOutputDef{
+			{Key: "set", Type: "[]string", Description: "Keys written, sorted"},
+			{Key: "deleted", Type: "[]string", Description: "Keys removed"},
+			{Key: "regenerated", Type: "boolean", Description: "Whether the session ID is regenerated"},
+		},
+	})
+
+	r.Register(&StepSchema{
+		Type:        "step.session_destroy",
+		Plugin:      "http",
+		Description: "Ends the request's http.session and expires its cookie.",
+		ConfigFields: []ConfigFieldDef{
+			{Key: "session", Type: FieldTypeString, Description: "http.session module name (default: the innermost on the route)"},
+		},
+		Outputs: []StepOutputDef{
+			{Key: "destroyed", Type: "boolean", Description: "Always true"},
+		},
+	})
+
+	r.Register(&StepSchema{
+		Type:        "step.projection_rebuild",
+		Plugin:      "eventstore",
+		Description: "Drops an eventstore.projection's table and replays the event log into it.",
+		ConfigFields: []ConfigFieldDef{
+			{Key: "projection", Type: FieldTypeString, Description: "eventstore.projection module name", Required: true},
+			{Key: "wait", Type: FieldTypeBool, Description: "Return once the replay has caught up (default: rebuild in the background)"},
+		},
+		Outputs: []StepOutputDef{
+			{Key: "projection", Type: "string", Description: "Projection name"},
+			{Key: "started", Type: "boolean", Description: "Set when the rebuild runs in the background"},
+			{Key: "rebuilt", Type: "boolean", Description: "Set when wait is true and the replay finished"},
+			{Key: "position", Type: "number", Description: "Event log position reached (wait only)"},
+			{Key: "lag", Type: "number", Description: "Events behind the head of the log (wait only)"},
+		},
+	})
+
 	r.Register(&StepSchema{
 		Type:        "step.constraint_check",
 		Plugin:      "pipelinesteps",
@@ -1244,6 +1620,31 @@ func (r *StepSchemaRegistry) registerBuiltins() {
 		},
 	})
 
+	r.Register(&StepSchema{
+		Type:        "step.pdf_render",
+		Plugin:      "pipelinesteps",
+		Description: "Renders an HTML template against the pipeline context and converts it to a PDF. The document is stored in the context or sent as the HTTP response.",
+		ConfigFields: []ConfigFieldDef{
+			{Key: "template", Type: FieldTypeString, Description: "Inline HTML template (Go template syntax); mutually exclusive with template_file"},
+			{Key: "template_file", Type: FieldTypeString, Description: "Path to an HTML template file; mutually exclusive with template"},
+			{Key: "assets_dir", Type: FieldTypeString, Description: "Directory that relative image paths resolve against; defaults to the template file's directory"},
+			{Key: "page_size", Type: FieldTypeSelect, Description: "Page size", Options: []string{"A3", "A4", "A5", "Letter", "Legal", "Tabloid"}, DefaultValue: "A4"},
+			{Key: "orientation", Type: FieldTypeSelect, Description: "Page orientation", Options: []string{"portrait", "landscape"}, DefaultValue: "portrait"},
+			{Key: "margins", Type: FieldTypeMap, Description: "Page margins in mm: a number for all sides or a map of top/right/bottom/left", DefaultValue: 15},
+			{Key: "output", Type: FieldTypeSelect, Description: "Where to send the PDF", Options: []string{"context", "response"}, DefaultValue: "context"},
+			{Key: "output_key", Type: FieldTypeString, Description: "Output key holding the PDF bytes in context mode", DefaultValue: "pdf"},
+			{Key: "filename", Type: FieldTypeString, Description: "Document filename (template expressions supported)", DefaultValue: "document.pdf"},
+			{Key: "disposition", Type: FieldTypeSelect, Description: "Content-Disposition type in response mode", Options: []string{"attachment", "inline"}, DefaultValue: "attachment"},
+		},
+		Outputs: []StepOutputDef{
+			{Key: "pdf", Type: "bytes", Description: "PDF document (context mode; key set by output_key)"},
+			{Key: "content_type", Type: "string", Description: "Always application/pdf"},
+			{Key: "filename", Type: "string", Description: "Resolved filename"},
+			{Key: "size", Type: "number", Description: "Document size in bytes"},
+			{Key: "pages", Type: "number", Description: "Number of pages rendered"},
+		},
+	})
+
 	r.Register(&StepSchema{
 		Type:        "step.pipeline_output",
 		Plugin:      "pipelinesteps",
@@ -1270,6 +1671,33 @@ func (r *StepSchemaRegistry) registerBuiltins() {
 		},
 	})
 
+	r.Register(&StepSchema{
+		Type:        "step.csv_parse",
+		Plugin:      "pipelinesteps",
+		Description: "Parses CSV from a context value or an uploaded multipart file into a list of row maps, with per-column type coercion and row/error caps.",
+		ConfigFields: []ConfigFieldDef{
+			{Key: "source", Type: FieldTypeString, Description: "Dot-path to a CSV string or bytes value; mutually exclusive with file_field"},
+			{Key: "file_field", Type: FieldTypeString, Description: "Multipart form field holding the uploaded CSV file; mutually exclusive with source"},
+			{Key: "delimiter", Type: FieldTypeString, Description: "Field delimiter (single character; \\t or tab for TSV)", DefaultValue: ","},
+			{Key: "header", Type: FieldTypeBool, Description: "Whether the first row holds column names", DefaultValue: true},
+			{Key: "columns", Type: FieldTypeArray, Description: "Column names; override the header row and are required when header is false"},
+			{Key: "types", Type: FieldTypeMap, Description: "Per-column type coercion: string, int, float, or bool"},
+			{Key: "trim_space", Type: FieldTypeBool, Description: "Trim whitespace around values", DefaultValue: true},
+			{Key: "max_rows", Type: FieldTypeNumber, Description: "Fail when the input has more data rows", DefaultValue: 10000},
+			{Key: "max_errors", Type: FieldTypeNumber, Description: "Row errors tolerated before the step fails", DefaultValue: 0},
+			{Key: "max_bytes", Type: FieldTypeNumber, Description: "Maximum input size in bytes", DefaultValue: 10485760},
+			{Key: "target", Type: FieldTypeString, Description: "Output key for the parsed rows", DefaultValue: "rows"},
+		},
+		Outputs: []StepOutputDef{
+			{Key: "rows", Type: "array", Description: "Parsed rows as maps keyed by column (key set by target)"},
+			{Key: "row_count", Type: "number", Description: "Number of rows parsed"},
+			{Key: "columns", Type: "array", Description: "Column names"},
+			{Key: "errors", Type: "array", Description: "Rejected rows as {line, column, error}"},
+			{Key: "error_count", Type: "number", Description: "Number of rejected rows"},
+			{Key: "filename", Type: "string", Description: "Uploaded file name (file_field only)"},
+		},
+	})
+
 	r.Register(&StepSchema{
 		Type:        "step.auth_validate",
 		Plugin:      "pipelinesteps",
@@ -1887,14 +2315,20 @@ func (r *StepSchemaRegistry) registerBuiltins() {
 	r.Register(&StepSchema{
 		Type:        "step.git_checkout",
 		Plugin:      "cicd",
-		Description: "Checks out a Git branch, tag, or commit.",
+		Description: "Checks out a Git branch, tag, or commit, fetching it from origin when missing locally.",
 		ConfigFields: []ConfigFieldDef{
-			{Key: "path", Type: FieldTypeString, Description: "Local repository path", Required: true},
-			{Key: "ref", Type: FieldTypeString, Description: "Branch, tag, or commit SHA to checkout", Required: true},
+			{Key: "directory", Type: FieldTypeString, Description: "Local repository path", Required: true},
+			{Key: "branch", Type: FieldTypeString, Description: "Branch, tag, or commit SHA to checkout", Required: true},
+			{Key: "create", Type: FieldTypeBool, Description: "Create the branch from the current HEAD"},
+			{Key: "token", Type: FieldTypeString, Description: "Auth token for fetching from a private origin", Sensitive: true},
+			{Key: "ssh_key", Type: FieldTypeString, Description: "SSH private key for fetching from an SSH origin", Sensitive: true},
 		},
 		Outputs: []StepOutputDef{
-			{Key: "commit", Type: "string", Description: "HEAD commit SHA after checkout"},
-			{Key: "branch", Type: "string", Description: "Current branch name"},
+			{Key: "commit_sha", Type: "string", Description: "HEAD commit SHA after checkout"},
+			{Key: "branch", Type: "string", Description: "Current branch name (empty when detached)"},
+			{Key: "ref", Type: "string", Description: "Requested ref"},
+			{Key: "detached", Type: "boolean", Description: "Whether HEAD is detached at a tag or commit"},
+			{Key: "created", Type: "boolean", Description: "Whether a new branch was created"},
 		},
 	})
 
@@ -1903,17 +2337,24 @@ func (r *StepSchemaRegistry) registerBuiltins() {
 	r.Register(&StepSchema{
 		Type:        "step.git_clone",
 		Plugin:      "cicd",
-		Description: "Clones a Git repository to a local path.",
+		Description: "Clones a Git repository to a local path without needing a git binary.",
 		ConfigFields: []ConfigFieldDef{
-			{Key: "url", Type: FieldTypeString, Description: "Repository URL to clone", Required: true},
-			{Key: "path", Type: FieldTypeString, Description: "Local path to clone into", Required: true},
-			{Key: "branch", Type: FieldTypeString, Description: "Branch to clone (default: main)"},
+			{Key: "repository", Type: FieldTypeString, Description: "Repository URL to clone", Required: true},
+			{Key: "directory", Type: FieldTypeString, Description: "Local path to clone into", Required: true},
+			{Key: "ref", Type: FieldTypeString, Description: "Branch, tag, or commit SHA (default: the remote's default branch)"},
 			{Key: "depth", Type: FieldTypeNumber, Description: "Clone depth (0 = full history)"},
 			{Key: "token", Type: FieldTypeString, Description: "Auth token for private repos", Sensitive: true},
+			{Key: "username", Type: FieldTypeString, Description: "HTTPS username (default: x-access-token with a token)"},
+			{Key: "password", Type: FieldTypeString, Description: "HTTPS password", Sensitive: true},
+			{Key: "ssh_key", Type: FieldTypeString, Description: "SSH private key (PEM) for SSH URLs", Sensitive: true},
+			{Key: "ssh_key_passphrase", Type: FieldTypeString, Description: "Passphrase for ssh_key", Sensitive: true},
+			{Key: "known_hosts", Type: FieldTypeString, Description: "known_hosts content to verify the SSH server"},
 		},
 		Outputs: []StepOutputDef{
-			{Key: "commit", Type: "string", Description: "HEAD commit SHA"},
-			{Key: "path", Type: "string", Description: "Local path where repo was cloned"},
+			{Key: "commit_sha", Type: "string", Description: "Resolved HEAD commit SHA"},
+			{Key: "clone_dir", Type: "string", Description: "Local path where repo was cloned"},
+			{Key: "branch", Type: "string", Description: "Checked out branch (empty for a tag or commit)"},
+			{Key: "ref", Type: "string", Description: "Requested ref"},
 		},
 	})
 
@@ -2426,6 +2867,26 @@ func (r *StepSchemaRegistry) registerBuiltins() {
 		},
 	})
 
+	// ---- Policy Check ----
+
+	r.Register(&StepSchema{
+		Type:        "step.policy_check",
+		Plugin:      "policy",
+		Description: "Evaluates a named Rego policy of a policy.service module against the pipeline context and allows or denies with the policy's reasons.",
+		ConfigFields: []ConfigFieldDef{
+			{Key: "engine", Type: FieldTypeString, Description: "Name of the policy.service module", Required: true},
+			{Key: "policy", Type: FieldTypeString, Description: "Package path of the policy decision, e.g. authz or authz.orders", Required: true},
+			{Key: "input", Type: FieldTypeMap, Description: "Policy input; values support templates. Defaults to the current pipeline context"},
+			{Key: "input_from", Type: FieldTypeString, Description: "Key in the pipeline context holding the policy input map"},
+			{Key: "on_deny", Type: FieldTypeSelect, Options: []string{"stop", "forbidden", "error", "continue"}, DefaultValue: "stop", Description: "On denial: stop the pipeline, respond 403 and stop, fail the step, or continue"},
+		},
+		Outputs: []StepOutputDef{
+			{Key: "allowed", Type: "boolean", Description: "Whether the policy allows the action"},
+			{Key: "reasons", Type: "[]string", Description: "Reasons reported by the policy"},
+			{Key: "metadata", Type: "map", Description: "Backend, policy and raw decision document"},
+		},
+	})
+
 	// ---- Policy List ----
 
 	r.Register(&StepSchema{
@@ -2642,6 +3103,31 @@ func (r *StepSchemaRegistry) registerBuiltins() {
 		},
 	})
 
+	// ---- Metric ----
+
+	r.Register(&StepSchema{
+		Type:        "step.metric",
+		Plugin:      "observability",
+		Description: "Records a custom business metric (counter, gauge, or histogram) on the metrics collector. Metrics are registered lazily on first use; the step is a no-op when no collector is registered.",
+		ConfigFields: []ConfigFieldDef{
+			{Key: "metric", Type: FieldTypeString, Description: "Metric name (prefixed with the collector namespace)", Required: true},
+			{Key: "type", Type: FieldTypeSelect, Description: "Metric type", Options: []string{"counter", "gauge", "histogram"}, DefaultValue: "counter"},
+			{Key: "value", Type: FieldTypeString, Description: "Numeric value or template expression (default 1)"},
+			{Key: "labels", Type: FieldTypeMap, Description: "Label names to constant values, or to {value, values} (enum; other values record as _other) or {value, hash_buckets} for templated values"},
+			{Key: "operation", Type: FieldTypeSelect, Description: "Gauge operation", Options: []string{"set", "inc", "dec", "add"}, DefaultValue: "set"},
+			{Key: "buckets", Type: FieldTypeArray, Description: "Histogram bucket upper bounds"},
+			{Key: "help", Type: FieldTypeString, Description: "Metric help text"},
+			{Key: "max_cardinality", Type: FieldTypeNumber, Description: "Maximum distinct label sets before values collapse to _overflow", DefaultValue: 100},
+			{Key: "collector", Type: FieldTypeString, Description: "Metrics collector service name", DefaultValue: "metrics.collector"},
+		},
+		Outputs: []StepOutputDef{
+			{Key: "metric", Type: "string", Description: "Metric name"},
+			{Key: "value", Type: "number", Description: "Recorded value"},
+			{Key: "recorded", Type: "boolean", Description: "Whether the metric was recorded"},
+			{Key: "overflowed", Type: "boolean", Description: "Whether label values were collapsed by the cardinality guard"},
+		},
+	})
+
 	// ---- Trace Start ----
 
 	r.Register(&StepSchema{
@@ -7,6 +7,7 @@ import (
 	"unicode"
 
 	"github.com/GoCodeAlone/workflow/config"
+	"github.com/GoCodeAlone/workflow/scheduler/cron"
 )
 
 // ValidationError represents a single validation failure with the path to the
@@ -183,6 +184,30 @@ func ValidateConfig(cfg *config.WorkflowConfig, opts ...ValidationOption) error
 				})
 			}
 		}
+
+		switch mod.StartPolicy {
+		case "", config.StartPolicyRequired, config.StartPolicyOptional:
+		default:
+			errs = append(errs, &ValidationError{
+				Path:    prefix + ".startPolicy",
+				Message: fmt.Sprintf("invalid startPolicy %q (expected %q or %q)", mod.StartPolicy, config.StartPolicyRequired, config.StartPolicyOptional),
+			})
+		}
+
+		// provides/needs entries must name a service or an interface
+		for _, field := range []struct {
+			key       string
+			contracts []config.ServiceContract
+		}{{"provides", mod.Provides}, {"needs", mod.Needs}} {
+			for j, c := range field.contracts {
+				if c.Name == "" && c.Interface == "" {
+					errs = append(errs, &ValidationError{
+						Path:    fmt.Sprintf("%s.%s[%d]", prefix, field.key, j),
+						Message: "service contract must set name or interface",
+					})
+				}
+			}
+		}
 	}
 
 	// Cross-validate dependsOn references point to defined module names.
@@ -237,6 +262,10 @@ func ValidateConfig(cfg *config.WorkflowConfig, opts ...ValidationOption) error
 		}
 	}
 
+	validateScheduleJobs(cfg, &errs)
+	validateLocales(cfg, &errs)
+	validateCORS(cfg, &errs)
+
 	// Check for entry points (unless in lenient mode or explicitly allowed)
 	if !o.allowEmptyModules && !o.allowNoEntryPoints {
 		checkEntryPoints(cfg, &errs)
@@ -248,6 +277,32 @@ func ValidateConfig(cfg *config.WorkflowConfig, opts ...ValidationOption) error
 	return nil
 }
 
+// validateScheduleJobs checks the cron expression and timezone of every
+// scheduled job so a bad schedule fails the build instead of silently never
+// firing. Missing cron fields are left to the trigger's own configuration.
+func validateScheduleJobs(cfg *config.WorkflowConfig, errs *ValidationErrors) {
+	for _, job := range cfg.ScheduleJobs() {
+		if job.Timezone != "" {
+			if _, err := cron.LoadTimezone(job.Timezone); err != nil {
+				*errs = append(*errs, &ValidationError{
+					Path:    job.Path + ".timezone",
+					Message: err.Error(),
+				})
+				continue
+			}
+		}
+		if job.Cron == "" {
+			continue
+		}
+		if _, err := cron.ParseInLocation(job.Cron, job.Timezone); err != nil {
+			*errs = append(*errs, &ValidationError{
+				Path:    job.Path + ".cron",
+				Message: err.Error(),
+			})
+		}
+	}
+}
+
 // schemaRegistry is used by validation for schema-driven config checks.
 var schemaRegistry = NewModuleSchemaRegistry()
 
@@ -416,6 +471,26 @@ func validateModuleConfig(mod config.ModuleConfig, prefix string, errs *Validati
 					})
 				}
 			}
+			if v, ok := mod.Config["autoOffsetReset"].(string); ok && v != "earliest" && v != "latest" {
+				*errs = append(*errs, &ValidationError{
+					Path:    prefix + ".config.autoOffsetReset",
+					Message: fmt.Sprintf("autoOffsetReset must be \"earliest\" or \"latest\", got %q", v),
+				})
+			}
+			if v, ok := mod.Config["commitMode"].(string); ok && v != "auto" && v != "manual-after-handler" {
+				*errs = append(*errs, &ValidationError{
+					Path:    prefix + ".config.commitMode",
+					Message: fmt.Sprintf("commitMode must be \"auto\" or \"manual-after-handler\", got %q", v),
+				})
+			}
+			if v, ok := mod.Config["partitions"]; ok && v != nil {
+				if _, ok := v.(map[string]any); !ok {
+					*errs = append(*errs, &ValidationError{
+						Path:    prefix + ".config.partitions",
+						Message: "partitions must be a map of topic to a list of partition numbers",
+					})
+				}
+			}
 		}
 	case "http.simple_proxy":
 		if mod.Config == nil {
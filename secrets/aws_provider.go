@@ -2,9 +2,6 @@ package secrets
 
 import (
 	"context"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -12,6 +9,8 @@ import (
 	"os"
 	"strings"
 	"time"
+
+	"github.com/GoCodeAlone/workflow/internal/awssigv4"
 )
 
 // HTTPClient is an interface for HTTP requests (allows testing).
@@ -246,54 +245,10 @@ type awsGetSecretResponse struct {
 
 // signRequest signs an HTTP request using AWS Signature V4.
 func (p *AWSSecretsManagerProvider) signRequest(req *http.Request, payload []byte, now time.Time) {
-	service := "secretsmanager"
-	region := p.config.Region
-
-	datestamp := now.Format("20060102")
-	amzdate := now.Format("20060102T150405Z")
-
-	req.Header.Set("X-Amz-Date", amzdate)
-
-	// Step 1: Create canonical request
-	payloadHash := sha256Hex(payload)
-	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
-
-	signedHeaders := "content-type;host;x-amz-content-sha256;x-amz-date;x-amz-target"
-	canonicalHeaders := fmt.Sprintf("content-type:%s\nhost:%s\nx-amz-content-sha256:%s\nx-amz-date:%s\nx-amz-target:%s\n",
-		req.Header.Get("Content-Type"),
-		req.Header.Get("Host"),
-		payloadHash,
-		amzdate,
-		req.Header.Get("X-Amz-Target"),
-	)
-
-	canonicalRequest := fmt.Sprintf("%s\n/\n\n%s\n%s\n%s",
-		req.Method,
-		canonicalHeaders,
-		signedHeaders,
-		payloadHash,
-	)
-
-	// Step 2: Create string to sign
-	credentialScope := fmt.Sprintf("%s/%s/%s/aws4_request", datestamp, region, service)
-	stringToSign := fmt.Sprintf("AWS4-HMAC-SHA256\n%s\n%s\n%s",
-		amzdate,
-		credentialScope,
-		sha256Hex([]byte(canonicalRequest)),
-	)
-
-	// Step 3: Calculate signature
-	signingKey := deriveSigningKey(p.config.SecretAccessKey, datestamp, region, service)
-	signature := hex.EncodeToString(hmacSHA256(signingKey, []byte(stringToSign)))
-
-	// Step 4: Add authorization header
-	authHeader := fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
-		p.config.AccessKeyID,
-		credentialScope,
-		signedHeaders,
-		signature,
-	)
-	req.Header.Set("Authorization", authHeader)
+	awssigv4.Sign(req, payload, awssigv4.Credentials{
+		AccessKeyID:     p.config.AccessKeyID,
+		SecretAccessKey: p.config.SecretAccessKey,
+	}, p.config.Region, "secretsmanager", now)
 }
 
 // parseAWSKey splits "secret-name#field" into (secretName, field).
@@ -342,25 +297,3 @@ func (p *AWSSecretsManagerProvider) CheckAccess(ctx context.Context) error {
 	}
 	return nil
 }
-
-// sha256Hex computes hex-encoded SHA-256 hash.
-func sha256Hex(data []byte) string {
-	h := sha256.Sum256(data)
-	return hex.EncodeToString(h[:])
-}
-
-// hmacSHA256 computes HMAC-SHA256.
-func hmacSHA256(key, data []byte) []byte {
-	mac := hmac.New(sha256.New, key)
-	mac.Write(data)
-	return mac.Sum(nil)
-}
-
-// deriveSigningKey derives the AWS Signature V4 signing key.
-func deriveSigningKey(secret, datestamp, region, service string) []byte {
-	kDate := hmacSHA256([]byte("AWS4"+secret), []byte(datestamp))
-	kRegion := hmacSHA256(kDate, []byte(region))
-	kService := hmacSHA256(kRegion, []byte(service))
-	kSigning := hmacSHA256(kService, []byte("aws4_request"))
-	return kSigning
-}
@@ -308,33 +308,3 @@ func TestExtractJSONField(t *testing.T) {
 		}
 	}
 }
-
-func TestDeriveSigningKey(t *testing.T) {
-	// This is a basic sanity check that the signing key derivation produces
-	// consistent output (deterministic).
-	key1 := deriveSigningKey("secret", "20260101", "us-east-1", "secretsmanager")
-	key2 := deriveSigningKey("secret", "20260101", "us-east-1", "secretsmanager")
-
-	if len(key1) != 32 { // HMAC-SHA256 produces 32 bytes
-		t.Errorf("expected 32 bytes, got %d", len(key1))
-	}
-
-	for i := range key1 {
-		if key1[i] != key2[i] {
-			t.Fatal("signing key derivation is not deterministic")
-		}
-	}
-
-	// Different inputs should produce different keys
-	key3 := deriveSigningKey("different", "20260101", "us-east-1", "secretsmanager")
-	same := true
-	for i := range key1 {
-		if key1[i] != key3[i] {
-			same = false
-			break
-		}
-	}
-	if same {
-		t.Error("different secrets should produce different signing keys")
-	}
-}
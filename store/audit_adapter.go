@@ -0,0 +1,64 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/GoCodeAlone/workflow/audit"
+	"github.com/google/uuid"
+)
+
+// AuditStoreAdapter wraps an AuditStore to satisfy audit.Store, so audit
+// events written by pipelines (step.audit) land in the audit_log table.
+//
+// Event fields map onto AuditEntry as follows: Actor becomes UserID when it
+// is a UUID; Resource "type:id" is split into ResourceType and ResourceID
+// (ResourceID only when the id is a UUID); everything else is kept in Details.
+type AuditStoreAdapter struct {
+	store AuditStore
+}
+
+// NewAuditStoreAdapter creates an adapter that bridges AuditStore to audit.Store.
+func NewAuditStoreAdapter(store AuditStore) *AuditStoreAdapter {
+	return &AuditStoreAdapter{store: store}
+}
+
+// Record implements audit.Store.
+func (a *AuditStoreAdapter) Record(ctx context.Context, e audit.Event) error {
+	details := map[string]any{
+		"type":     e.Type,
+		"actor":    e.Actor,
+		"resource": e.Resource,
+		"success":  e.Success,
+	}
+	if e.Detail != "" {
+		details["detail"] = e.Detail
+	}
+	if len(e.Metadata) > 0 {
+		details["metadata"] = e.Metadata
+	}
+	raw, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Errorf("marshal audit details: %w", err)
+	}
+
+	entry := &AuditEntry{
+		Action:       e.Action,
+		ResourceType: e.Resource,
+		Details:      raw,
+		IPAddress:    e.SourceIP,
+		CreatedAt:    e.Timestamp,
+	}
+	if id, err := uuid.Parse(e.Actor); err == nil {
+		entry.UserID = &id
+	}
+	if typ, id, ok := strings.Cut(e.Resource, ":"); ok {
+		entry.ResourceType = typ
+		if rid, err := uuid.Parse(id); err == nil {
+			entry.ResourceID = &rid
+		}
+	}
+	return a.store.Record(ctx, entry)
+}
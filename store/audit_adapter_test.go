@@ -0,0 +1,64 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/GoCodeAlone/workflow/audit"
+	"github.com/google/uuid"
+)
+
+func TestAuditStoreAdapter_Record(t *testing.T) {
+	mock := NewMockAuditStore()
+	adapter := NewAuditStoreAdapter(mock)
+
+	actor := uuid.New()
+	order := uuid.New()
+	err := adapter.Record(context.Background(), audit.Event{
+		Type:     audit.EventBusiness,
+		Action:   "order.approve",
+		Actor:    actor.String(),
+		Resource: "order:" + order.String(),
+		SourceIP: "10.0.0.1",
+		Success:  true,
+		Metadata: map[string]any{"amount": 42},
+	})
+	if err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	entries, _ := mock.Query(context.Background(), AuditFilter{})
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	e := entries[0]
+	if e.Action != "order.approve" || e.ResourceType != "order" || e.IPAddress != "10.0.0.1" {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+	if e.UserID == nil || *e.UserID != actor || e.ResourceID == nil || *e.ResourceID != order {
+		t.Errorf("expected actor and resource UUIDs, got user=%v resource=%v", e.UserID, e.ResourceID)
+	}
+	var details map[string]any
+	if err := json.Unmarshal(e.Details, &details); err != nil {
+		t.Fatalf("details: %v", err)
+	}
+	if details["type"] != "business" || details["metadata"].(map[string]any)["amount"] != float64(42) {
+		t.Errorf("unexpected details: %v", details)
+	}
+}
+
+func TestAuditStoreAdapter_NonUUIDActorAndResource(t *testing.T) {
+	mock := NewMockAuditStore()
+	if err := NewAuditStoreAdapter(mock).Record(context.Background(), audit.Event{
+		Action:   "export",
+		Actor:    "alice@example.com",
+		Resource: "report",
+	}); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	entries, _ := mock.Query(context.Background(), AuditFilter{})
+	if e := entries[0]; e.UserID != nil || e.ResourceID != nil || e.ResourceType != "report" {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+}
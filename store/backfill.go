@@ -39,6 +39,57 @@ type BackfillRequest struct {
 	Processed    int64          `json:"processed"`
 	Failed       int64          `json:"failed"`
 	ErrorMsg     string         `json:"error_message,omitempty"`
+	// TenantID is the tenant the backfill was requested for; callers scoped
+	// to a tenant only see their own tenant's backfills.
+	TenantID string `json:"tenant_id,omitempty"`
+
+	// SourcePipeline is the pipeline whose recorded executions are replayed
+	// through PipelineName; empty means PipelineName itself, to shadow test
+	// a new version of a pipeline against its own history.
+	SourcePipeline string `json:"source_pipeline,omitempty"`
+	// SampleRate is the fraction of the executions in the time range that
+	// are replayed, between 0 and 1; 0 replays all of them. The sample is
+	// chosen by execution ID, so rerunning a backfill replays the same ones.
+	SampleRate float64 `json:"sample_rate,omitempty"`
+	// Concurrency is the number of executions replayed at once; 0 means
+	// DefaultBackfillConcurrency.
+	Concurrency int `json:"concurrency,omitempty"`
+	// MockSteps names steps that return their originally recorded output
+	// instead of running, in addition to the step mocks of the pipeline,
+	// so side effects are not repeated.
+	MockSteps []string `json:"mock_steps,omitempty"`
+	// Skipped counts executions that could not be replayed because their
+	// input was not recorded.
+	Skipped int64 `json:"skipped"`
+	// Report compares the replayed outputs with the recorded ones. It is set
+	// when the backfill finishes.
+	Report *BackfillReport `json:"report,omitempty"`
+}
+
+// BackfillReport is the diff report of a backfill: how many replayed
+// executions produced the originally recorded step outputs, and the step
+// diffs of those that did not.
+type BackfillReport struct {
+	Compared  int64 `json:"compared"`
+	Same      int64 `json:"same"`
+	Different int64 `json:"different"`
+	Failed    int64 `json:"failed"`
+	// Executions lists the executions that differed or failed, at most
+	// MaxBackfillReportExecutions of them.
+	Executions []BackfillExecutionDiff `json:"executions"`
+	// Truncated is set when more executions differed or failed than
+	// Executions lists.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// BackfillExecutionDiff compares one replayed execution with the recorded
+// original.
+type BackfillExecutionDiff struct {
+	ExecutionID uuid.UUID   `json:"execution_id"`
+	Status      string      `json:"status"` // "different" or "failed"
+	Error       string      `json:"error,omitempty"`
+	StepDiffs   []StepDiff  `json:"step_diffs,omitempty"`
+	Summary     DiffSummary `json:"summary"`
 }
 
 // ---------------------------------------------------------------------------
@@ -59,6 +110,10 @@ type BackfillStore interface {
 	UpdateStatus(ctx context.Context, id uuid.UUID, status BackfillStatus, errMsg string) error
 	// Cancel cancels a pending or running backfill request.
 	Cancel(ctx context.Context, id uuid.UUID) error
+	// SetTotal sets the number of events a backfill request replays.
+	SetTotal(ctx context.Context, id uuid.UUID, total int64) error
+	// SetReport stores the diff report and skipped count of a backfill request.
+	SetReport(ctx context.Context, id uuid.UUID, skipped int64, report *BackfillReport) error
 }
 
 // ===========================================================================
@@ -180,6 +235,31 @@ func (s *InMemoryBackfillStore) Cancel(_ context.Context, id uuid.UUID) error {
 	return nil
 }
 
+func (s *InMemoryBackfillStore) SetTotal(_ context.Context, id uuid.UUID, total int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	req, ok := s.requests[id]
+	if !ok {
+		return ErrNotFound
+	}
+	req.TotalEvents = total
+	return nil
+}
+
+func (s *InMemoryBackfillStore) SetReport(_ context.Context, id uuid.UUID, skipped int64, report *BackfillReport) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	req, ok := s.requests[id]
+	if !ok {
+		return ErrNotFound
+	}
+	req.Skipped = skipped
+	req.Report = report
+	return nil
+}
+
 // ---------------------------------------------------------------------------
 // Compile-time interface assertion
 // ---------------------------------------------------------------------------
@@ -1,6 +1,7 @@
 package store
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"log/slog"
@@ -11,11 +12,15 @@ import (
 )
 
 // BackfillMockDiffHandler provides HTTP endpoints for backfill, step mock,
-// and execution diff management.
+// and execution diff management. Requests scoped to a tenant only see that
+// tenant's backfills and executions, and cannot manage step mocks, which
+// apply to every tenant.
 type BackfillMockDiffHandler struct {
 	backfillStore BackfillStore
 	mockStore     StepMockStore
 	diffCalc      *DiffCalculator
+	runner        *BackfillRunner
+	tenantOf      TenantResolver
 	logger        *slog.Logger
 }
 
@@ -33,27 +38,58 @@ func NewBackfillMockDiffHandler(
 		backfillStore: backfillStore,
 		mockStore:     mockStore,
 		diffCalc:      diffCalc,
+		tenantOf:      RequestTenant,
 		logger:        logger,
 	}
 }
 
+// WithTenantResolver replaces RequestTenant as the resolver of the tenant a
+// request is scoped to.
+func (h *BackfillMockDiffHandler) WithTenantResolver(fn TenantResolver) *BackfillMockDiffHandler {
+	h.tenantOf = fn
+	return h
+}
+
+// WithRunner makes the handler run every backfill request it creates in
+// the background with runner. Without a runner, requests are only stored.
+func (h *BackfillMockDiffHandler) WithRunner(runner *BackfillRunner) *BackfillMockDiffHandler {
+	h.runner = runner
+	return h
+}
+
 // RegisterRoutes registers all backfill, mock, and diff API routes on the given mux.
 func (h *BackfillMockDiffHandler) RegisterRoutes(mux *http.ServeMux) {
+	handle := func(pattern string, fn http.HandlerFunc) {
+		mux.HandleFunc(pattern, requireTenantScope(h.tenantOf, fn))
+	}
+
 	// Backfill routes
-	mux.HandleFunc("GET /api/v1/admin/backfill", h.handleBackfillList)
-	mux.HandleFunc("POST /api/v1/admin/backfill", h.handleBackfillCreate)
-	mux.HandleFunc("GET /api/v1/admin/backfill/{id}", h.handleBackfillGet)
-	mux.HandleFunc("POST /api/v1/admin/backfill/{id}/cancel", h.handleBackfillCancel)
+	handle("GET /api/v1/admin/backfill", h.handleBackfillList)
+	handle("POST /api/v1/admin/backfill", h.handleBackfillCreate)
+	handle("GET /api/v1/admin/backfill/{id}", h.handleBackfillGet)
+	handle("POST /api/v1/admin/backfill/{id}/cancel", h.handleBackfillCancel)
 
 	// Mock routes
-	mux.HandleFunc("GET /api/v1/admin/mocks", h.handleMockList)
-	mux.HandleFunc("POST /api/v1/admin/mocks", h.handleMockSet)
-	mux.HandleFunc("DELETE /api/v1/admin/mocks", h.handleMockClearAll)
-	mux.HandleFunc("GET /api/v1/admin/mocks/{pipeline}", h.handleMockListPipeline)
-	mux.HandleFunc("DELETE /api/v1/admin/mocks/{pipeline}/{step}", h.handleMockRemove)
+	handle("GET /api/v1/admin/mocks", h.unscoped(h.handleMockList))
+	handle("POST /api/v1/admin/mocks", h.unscoped(h.handleMockSet))
+	handle("DELETE /api/v1/admin/mocks", h.unscoped(h.handleMockClearAll))
+	handle("GET /api/v1/admin/mocks/{pipeline}", h.unscoped(h.handleMockListPipeline))
+	handle("DELETE /api/v1/admin/mocks/{pipeline}/{step}", h.unscoped(h.handleMockRemove))
 
 	// Diff routes
-	mux.HandleFunc("GET /api/v1/admin/executions/diff", h.handleExecutionDiff)
+	handle("GET /api/v1/admin/executions/diff", h.handleExecutionDiff)
+}
+
+// unscoped wraps a handler that may only be used by callers that are not
+// scoped to a tenant.
+func (h *BackfillMockDiffHandler) unscoped(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if h.tenantOf(r) != "" {
+			writeHandlerError(w, http.StatusForbidden, "not available to tenant-scoped callers")
+			return
+		}
+		next(w, r)
+	}
 }
 
 // ---------------------------------------------------------------------------
@@ -67,9 +103,31 @@ func (h *BackfillMockDiffHandler) handleBackfillList(w http.ResponseWriter, r *h
 		writeHandlerError(w, http.StatusInternalServerError, "internal error")
 		return
 	}
+	if scope := h.tenantOf(r); scope != "" {
+		scoped := make([]*BackfillRequest, 0, len(requests))
+		for _, req := range requests {
+			if req.TenantID == scope {
+				scoped = append(scoped, req)
+			}
+		}
+		requests = scoped
+	}
 	writeHandlerJSON(w, http.StatusOK, requests)
 }
 
+// getBackfill returns the backfill request with the given ID, reporting a
+// request of another tenant than the caller's as not found.
+func (h *BackfillMockDiffHandler) getBackfill(r *http.Request, id uuid.UUID) (*BackfillRequest, error) {
+	req, err := h.backfillStore.Get(r.Context(), id)
+	if err != nil {
+		return nil, err
+	}
+	if !inTenantScope(h.tenantOf(r), req.TenantID) {
+		return nil, ErrNotFound
+	}
+	return req, nil
+}
+
 func (h *BackfillMockDiffHandler) handleBackfillCreate(w http.ResponseWriter, r *http.Request) {
 	var req BackfillRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -81,12 +139,30 @@ func (h *BackfillMockDiffHandler) handleBackfillCreate(w http.ResponseWriter, r
 		writeHandlerError(w, http.StatusBadRequest, "pipeline_name is required")
 		return
 	}
+	if err := ValidateBackfillRequest(&req); err != nil {
+		writeHandlerError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	// Progress and the report are set by the runner.
+	req.Status, req.TotalEvents, req.Processed, req.Failed, req.Skipped, req.Report = "", 0, 0, 0, 0, nil
+	if scope := h.tenantOf(r); scope != "" {
+		if req.TenantID != "" && req.TenantID != scope {
+			writeHandlerError(w, http.StatusForbidden, "cross-tenant access denied")
+			return
+		}
+		req.TenantID = scope
+	}
 
 	if err := h.backfillStore.Create(r.Context(), &req); err != nil {
 		h.logger.Error("create backfill request", "error", err)
 		writeHandlerError(w, http.StatusInternalServerError, "internal error")
 		return
 	}
+	if h.runner != nil {
+		go func(id uuid.UUID) {
+			_ = h.runner.Run(context.Background(), id)
+		}(req.ID)
+	}
 
 	writeHandlerJSON(w, http.StatusCreated, req)
 }
@@ -98,7 +174,7 @@ func (h *BackfillMockDiffHandler) handleBackfillGet(w http.ResponseWriter, r *ht
 		return
 	}
 
-	req, err := h.backfillStore.Get(r.Context(), id)
+	req, err := h.getBackfill(r, id)
 	if err != nil {
 		if errors.Is(err, ErrNotFound) {
 			writeHandlerError(w, http.StatusNotFound, "backfill request not found")
@@ -119,6 +195,15 @@ func (h *BackfillMockDiffHandler) handleBackfillCancel(w http.ResponseWriter, r
 		return
 	}
 
+	if _, err := h.getBackfill(r, id); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			writeHandlerError(w, http.StatusNotFound, "backfill request not found")
+			return
+		}
+		h.logger.Error("get backfill request", "error", err)
+		writeHandlerError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
 	if err := h.backfillStore.Cancel(r.Context(), id); err != nil {
 		if errors.Is(err, ErrNotFound) {
 			writeHandlerError(w, http.StatusNotFound, "backfill request not found")
@@ -279,6 +364,22 @@ func (h *BackfillMockDiffHandler) handleExecutionDiff(w http.ResponseWriter, r *
 		return
 	}
 
+	// A tenant-scoped caller may only compare its own tenant's executions.
+	if scope := h.tenantOf(r); scope != "" {
+		for _, id := range []uuid.UUID{execA, execB} {
+			events, err := h.diffCalc.eventStore.GetEvents(r.Context(), id)
+			if err != nil {
+				h.logger.Error("compare executions", "error", err)
+				writeHandlerError(w, http.StatusInternalServerError, "internal error")
+				return
+			}
+			if len(events) > 0 && !inTenantScope(scope, executionTenant(events)) {
+				writeHandlerError(w, http.StatusNotFound, "execution "+id.String()+" not found")
+				return
+			}
+		}
+	}
+
 	diff, err := h.diffCalc.Compare(r.Context(), execA, execB)
 	if err != nil {
 		if errors.Is(err, ErrNotFound) {
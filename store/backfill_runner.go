@@ -0,0 +1,339 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"math"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultBackfillConcurrency is the number of executions a backfill
+	// replays at once when its request does not set one.
+	DefaultBackfillConcurrency = 4
+	// MaxBackfillConcurrency bounds the concurrency of a backfill request.
+	MaxBackfillConcurrency = 64
+	// MaxBackfillReportExecutions bounds the executions a backfill report
+	// lists.
+	MaxBackfillReportExecutions = 100
+
+	// backfillPageSize is the number of executions listed per page.
+	backfillPageSize = 100
+)
+
+// BackfillRun is one recorded execution a backfill replays through its
+// target pipeline.
+type BackfillRun struct {
+	Pipeline    string
+	TenantID    string
+	ExecutionID uuid.UUID
+	// Input is the trigger data the original execution started with.
+	Input map[string]any
+	// Mocks replace steps of the pipeline, keyed by step name.
+	Mocks map[string]*StepMock
+}
+
+// BackfillRunFunc runs a recorded execution's input through a pipeline
+// without recording it, and returns the output of each step that ran, keyed
+// by step name. When the pipeline fails it returns the error together with
+// the outputs of the steps that ran before.
+type BackfillRunFunc func(ctx context.Context, run BackfillRun) (map[string]map[string]any, error)
+
+// ValidateBackfillRequest checks the sampling, concurrency and time range
+// of a backfill request.
+func ValidateBackfillRequest(req *BackfillRequest) error {
+	if req.SampleRate < 0 || req.SampleRate > 1 || math.IsNaN(req.SampleRate) {
+		return fmt.Errorf("sample_rate must be between 0 and 1")
+	}
+	if req.Concurrency < 0 || req.Concurrency > MaxBackfillConcurrency {
+		return fmt.Errorf("concurrency must be between 0 and %d", MaxBackfillConcurrency)
+	}
+	if req.StartTime != nil && req.EndTime != nil && req.EndTime.Before(*req.StartTime) {
+		return fmt.Errorf("end_time must not be before start_time")
+	}
+	return nil
+}
+
+// BackfillRunner runs backfill requests: it reads the executions of the
+// source pipeline recorded in the request's time range, replays the input
+// of each through the target pipeline with the pipeline's step mocks in
+// place of side effects, and diffs the step outputs against the recorded
+// ones. Only executions recorded with explicit tracing have their input and
+// step outputs in the event store; others are counted as skipped.
+type BackfillRunner struct {
+	backfills BackfillStore
+	mocks     StepMockStore
+	events    EventStore
+	run       BackfillRunFunc
+	logger    *slog.Logger
+}
+
+// NewBackfillRunner creates a BackfillRunner. mocks may be nil.
+func NewBackfillRunner(backfills BackfillStore, mocks StepMockStore, events EventStore, run BackfillRunFunc, logger *slog.Logger) *BackfillRunner {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &BackfillRunner{backfills: backfills, mocks: mocks, events: events, run: run, logger: logger}
+}
+
+// Run runs the backfill request with the given ID to completion, recording
+// its progress, final status and report in the backfill store. It stops
+// early when the request is cancelled or ctx is done.
+func (r *BackfillRunner) Run(ctx context.Context, id uuid.UUID) error {
+	req, err := r.backfills.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if req.Status != BackfillStatusPending {
+		return fmt.Errorf("cannot run backfill in status %q: %w", req.Status, ErrConflict)
+	}
+	if err := r.backfills.UpdateStatus(ctx, id, BackfillStatusRunning, ""); err != nil {
+		return err
+	}
+
+	skipped, report, err := r.replay(ctx, req)
+	if serr := r.backfills.SetReport(ctx, id, skipped, report); serr != nil && err == nil {
+		err = serr
+	}
+	if current, gerr := r.backfills.Get(ctx, id); gerr == nil && current.Status == BackfillStatusCancelled {
+		return nil
+	}
+	if err != nil {
+		r.logger.Error("Backfill failed", "backfill", id, "error", err)
+		_ = r.backfills.UpdateStatus(ctx, id, BackfillStatusFailed, err.Error())
+		return err
+	}
+	return r.backfills.UpdateStatus(ctx, id, BackfillStatusCompleted, "")
+}
+
+// replay lists the sampled executions of the request and replays them with
+// the request's concurrency.
+func (r *BackfillRunner) replay(ctx context.Context, req *BackfillRequest) (int64, *BackfillReport, error) {
+	source := req.SourcePipeline
+	if source == "" {
+		source = req.PipelineName
+	}
+	var ids []uuid.UUID
+	for offset := 0; ; offset += backfillPageSize {
+		page, err := r.events.ListExecutions(ctx, ExecutionEventFilter{
+			Pipeline: source,
+			TenantID: req.TenantID,
+			Since:    req.StartTime,
+			Until:    req.EndTime,
+			Limit:    backfillPageSize,
+			Offset:   offset,
+		})
+		if err != nil {
+			return 0, nil, fmt.Errorf("list executions of %s: %w", source, err)
+		}
+		for i := range page {
+			if sampled(page[i].ExecutionID, req.SampleRate) {
+				ids = append(ids, page[i].ExecutionID)
+			}
+		}
+		if len(page) < backfillPageSize {
+			break
+		}
+	}
+	if err := r.backfills.SetTotal(ctx, req.ID, int64(len(ids))); err != nil {
+		return 0, nil, err
+	}
+
+	mocks := map[string]*StepMock{}
+	if r.mocks != nil {
+		list, err := r.mocks.List(ctx, req.PipelineName)
+		if err != nil {
+			return 0, nil, fmt.Errorf("list step mocks of %s: %w", req.PipelineName, err)
+		}
+		for _, m := range list {
+			if m.Enabled {
+				mocks[m.StepName] = m
+			}
+		}
+	}
+
+	concurrency := req.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultBackfillConcurrency
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu                 sync.Mutex
+		processed, skipped int64
+		report             = &BackfillReport{Executions: []BackfillExecutionDiff{}}
+		firstErr           error
+		wg                 sync.WaitGroup
+	)
+	work := make(chan uuid.UUID)
+	for range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for execID := range work {
+				diff, err := r.replayExecution(ctx, req, execID, mocks)
+
+				mu.Lock()
+				switch {
+				case errors.Is(err, errNoRecordedInput):
+					skipped++
+				case err != nil:
+					if firstErr == nil {
+						firstErr = err
+						cancel()
+					}
+				default:
+					processed++
+					report.add(diff)
+				}
+				p, f := processed, report.Failed
+				mu.Unlock()
+				if err == nil {
+					_ = r.backfills.UpdateProgress(ctx, req.ID, p, f)
+				}
+			}
+		}()
+	}
+
+	for _, execID := range ids {
+		if ctx.Err() != nil || r.cancelled(ctx, req.ID) {
+			break
+		}
+		work <- execID
+	}
+	close(work)
+	wg.Wait()
+
+	if firstErr == nil && ctx.Err() != nil && !r.cancelled(context.WithoutCancel(ctx), req.ID) {
+		firstErr = ctx.Err()
+	}
+	return skipped, report, firstErr
+}
+
+// cancelled reports whether the backfill request has been cancelled.
+func (r *BackfillRunner) cancelled(ctx context.Context, id uuid.UUID) bool {
+	req, err := r.backfills.Get(ctx, id)
+	return err == nil && req.Status == BackfillStatusCancelled
+}
+
+// errNoRecordedInput marks an execution recorded without its input.
+var errNoRecordedInput = errors.New("execution input not recorded")
+
+// replayExecution replays one recorded execution and diffs its step outputs
+// against the recorded ones. A failing replay is reported in the diff, not
+// as an error.
+func (r *BackfillRunner) replayExecution(ctx context.Context, req *BackfillRequest, execID uuid.UUID, mocks map[string]*StepMock) (BackfillExecutionDiff, error) {
+	original, err := r.events.GetTimeline(ctx, execID)
+	if err != nil {
+		return BackfillExecutionDiff{}, fmt.Errorf("get execution %s: %w", execID, err)
+	}
+
+	var input map[string]any
+	recorded := make(map[string]*stepInfo)
+	for i := range original.Steps {
+		step := &original.Steps[i]
+		if step.Parent != "" {
+			continue
+		}
+		if input == nil && len(step.InputData) > 0 {
+			_ = json.Unmarshal(step.InputData, &input)
+		}
+		info := &stepInfo{startedAt: step.StartedAt, completedAt: step.CompletedAt}
+		if len(step.OutputData) > 0 {
+			_ = json.Unmarshal(step.OutputData, &info.output)
+		}
+		recorded[step.StepName] = info
+	}
+	if input == nil {
+		return BackfillExecutionDiff{}, errNoRecordedInput
+	}
+
+	runMocks := mocks
+	if len(req.MockSteps) > 0 {
+		runMocks = make(map[string]*StepMock, len(mocks)+len(req.MockSteps))
+		for name, m := range mocks {
+			runMocks[name] = m
+		}
+		for _, name := range req.MockSteps {
+			if info, ok := recorded[name]; ok {
+				runMocks[name] = &StepMock{PipelineName: req.PipelineName, StepName: name, Response: info.output, Enabled: true}
+			}
+		}
+	}
+
+	outputs, runErr := r.run(ctx, BackfillRun{
+		Pipeline:    req.PipelineName,
+		TenantID:    original.TenantID,
+		ExecutionID: execID,
+		Input:       input,
+		Mocks:       runMocks,
+	})
+	replayed := make(map[string]*stepInfo, len(outputs))
+	for name, out := range outputs {
+		replayed[name] = &stepInfo{output: normalizeOutput(out)}
+	}
+
+	diff := BackfillExecutionDiff{ExecutionID: execID}
+	diff.StepDiffs, diff.Summary = diffSteps(recorded, replayed)
+	switch {
+	case runErr != nil:
+		diff.Status = "failed"
+		diff.Error = runErr.Error()
+	case diff.Summary.DiffSteps+diff.Summary.AddedSteps+diff.Summary.RemovedSteps > 0:
+		diff.Status = "different"
+	default:
+		diff.Status = "same"
+	}
+	return diff, nil
+}
+
+// add counts a replayed execution, listing it when it differed or failed.
+func (rep *BackfillReport) add(diff BackfillExecutionDiff) {
+	rep.Compared++
+	switch diff.Status {
+	case "same":
+		rep.Same++
+		return
+	case "failed":
+		rep.Failed++
+	default:
+		rep.Different++
+	}
+	if len(rep.Executions) >= MaxBackfillReportExecutions {
+		rep.Truncated = true
+		return
+	}
+	rep.Executions = append(rep.Executions, diff)
+}
+
+// normalizeOutput round-trips a step output through JSON, so it compares
+// with a recorded output the way the event store would have recorded it.
+func normalizeOutput(out map[string]any) map[string]any {
+	raw, err := json.Marshal(out)
+	if err != nil {
+		return out
+	}
+	var normalized map[string]any
+	if err := json.Unmarshal(raw, &normalized); err != nil {
+		return out
+	}
+	return normalized
+}
+
+// sampled reports whether an execution is in a sample of the given rate,
+// deciding by its ID so the same executions are sampled every time.
+func sampled(id uuid.UUID, rate float64) bool {
+	if rate <= 0 || rate >= 1 {
+		return true
+	}
+	h := fnv.New64a()
+	_, _ = h.Write(id[:])
+	return float64(h.Sum64())/float64(math.MaxUint64) < rate
+}
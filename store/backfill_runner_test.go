@@ -0,0 +1,210 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// recordExecution appends the events of a traced execution of pipeline
+// whose single step "price" started from input and produced output. A nil
+// input records an untraced execution.
+func recordExecution(t *testing.T, s EventStore, pipeline string, input, output map[string]any) uuid.UUID {
+	t.Helper()
+	ctx := context.Background()
+	id := uuid.New()
+	type event struct {
+		typ  string
+		data map[string]any
+	}
+	events := []event{
+		{EventExecutionStarted, map[string]any{"pipeline": pipeline}},
+		{EventStepStarted, map[string]any{"step_name": "price"}},
+	}
+	if input != nil {
+		events = append(events,
+			event{EventStepInputRecorded, map[string]any{"step_name": "price", "input": input}},
+			event{EventStepOutputRecorded, map[string]any{"step_name": "price", "output": output}},
+		)
+	}
+	events = append(events, event{EventStepCompleted, map[string]any{"step_name": "price"}})
+	for _, ev := range events {
+		if err := s.Append(ctx, id, ev.typ, ev.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return id
+}
+
+func TestBackfillRunnerDiffReport(t *testing.T) {
+	ctx := context.Background()
+	events := NewInMemoryEventStore()
+	same := recordExecution(t, events, "orders", map[string]any{"qty": 2}, map[string]any{"total": 20})
+	changed := recordExecution(t, events, "orders", map[string]any{"qty": 3}, map[string]any{"total": 30})
+	recordExecution(t, events, "orders", nil, nil)
+	recordExecution(t, events, "invoices", map[string]any{"qty": 1}, map[string]any{"total": 10})
+
+	mocks := NewInMemoryStepMockStore()
+	_ = mocks.Set(ctx, &StepMock{PipelineName: "orders", StepName: "charge", Response: map[string]any{"charged": true}, Enabled: true})
+	_ = mocks.Set(ctx, &StepMock{PipelineName: "orders", StepName: "email", Enabled: false})
+
+	// The new version of the pipeline prices a quantity of 3 differently.
+	var runs atomic.Int64
+	run := func(_ context.Context, r BackfillRun) (map[string]map[string]any, error) {
+		runs.Add(1)
+		if r.Pipeline != "orders" || len(r.Mocks) != 1 || r.Mocks["charge"] == nil {
+			t.Errorf("unexpected run %+v", r)
+		}
+		qty := r.Input["qty"].(float64)
+		total := qty * 10
+		if qty == 3 {
+			total = 27
+		}
+		return map[string]map[string]any{"price": {"total": total}}, nil
+	}
+
+	backfills := NewInMemoryBackfillStore()
+	req := &BackfillRequest{PipelineName: "orders", Concurrency: 2}
+	if err := backfills.Create(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewBackfillRunner(backfills, mocks, events, run, nil).Run(ctx, req.ID); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	got, _ := backfills.Get(ctx, req.ID)
+	if got.Status != BackfillStatusCompleted || got.CompletedAt == nil {
+		t.Fatalf("expected a completed backfill, got %q", got.Status)
+	}
+	if got.TotalEvents != 3 || got.Processed != 2 || got.Skipped != 1 || got.Failed != 0 || runs.Load() != 2 {
+		t.Errorf("unexpected counts total=%d processed=%d skipped=%d failed=%d runs=%d",
+			got.TotalEvents, got.Processed, got.Skipped, got.Failed, runs.Load())
+	}
+	rep := got.Report
+	if rep == nil || rep.Compared != 2 || rep.Same != 1 || rep.Different != 1 || len(rep.Executions) != 1 {
+		t.Fatalf("unexpected report %+v", rep)
+	}
+	diff := rep.Executions[0]
+	if diff.ExecutionID != changed || diff.ExecutionID == same || diff.Status != "different" {
+		t.Errorf("expected execution %s to differ, got %+v", changed, diff)
+	}
+	if len(diff.StepDiffs) != 1 || len(diff.StepDiffs[0].Changes) != 1 ||
+		diff.StepDiffs[0].Changes[0].Path != "total" || diff.StepDiffs[0].Changes[0].ValueB != float64(27) {
+		t.Errorf("unexpected step diffs %+v", diff.StepDiffs)
+	}
+}
+
+func TestBackfillRunnerMockStepsAndFailures(t *testing.T) {
+	ctx := context.Background()
+	events := NewInMemoryEventStore()
+	recordExecution(t, events, "orders-v1", map[string]any{"qty": 1}, map[string]any{"total": 10})
+
+	// The replayed pipeline gets the recorded output of a mocked step, and
+	// fails after it.
+	run := func(_ context.Context, r BackfillRun) (map[string]map[string]any, error) {
+		m := r.Mocks["price"]
+		if r.Pipeline != "orders-v2" || m == nil || m.Response["total"] != float64(10) {
+			t.Errorf("expected the recorded output as mock, got %+v", r)
+		}
+		return map[string]map[string]any{"price": m.Response}, errors.New(`step "ship" failed`)
+	}
+
+	backfills := NewInMemoryBackfillStore()
+	req := &BackfillRequest{PipelineName: "orders-v2", SourcePipeline: "orders-v1", MockSteps: []string{"price"}}
+	_ = backfills.Create(ctx, req)
+	if err := NewBackfillRunner(backfills, nil, events, run, nil).Run(ctx, req.ID); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	got, _ := backfills.Get(ctx, req.ID)
+	if got.Status != BackfillStatusCompleted || got.Processed != 1 || got.Failed != 1 {
+		t.Fatalf("unexpected backfill %+v", got)
+	}
+	if rep := got.Report; rep.Failed != 1 || len(rep.Executions) != 1 || rep.Executions[0].Error != `step "ship" failed` {
+		t.Errorf("unexpected report %+v", rep)
+	}
+
+	if err := NewBackfillRunner(backfills, nil, events, run, nil).Run(ctx, req.ID); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected a finished backfill not to run again, got %v", err)
+	}
+}
+
+func TestBackfillRunnerTimeRangeAndCancel(t *testing.T) {
+	ctx := context.Background()
+	events := NewInMemoryEventStore()
+	for i := 0; i < 5; i++ {
+		recordExecution(t, events, "orders", map[string]any{"qty": i}, map[string]any{"total": i * 10})
+	}
+
+	// A range ending before the executions were recorded replays nothing.
+	backfills := NewInMemoryBackfillStore()
+	end := time.Now().Add(-time.Hour)
+	req := &BackfillRequest{PipelineName: "orders", EndTime: &end}
+	_ = backfills.Create(ctx, req)
+	never := func(context.Context, BackfillRun) (map[string]map[string]any, error) {
+		t.Error("expected no execution to be replayed")
+		return nil, nil
+	}
+	if err := NewBackfillRunner(backfills, nil, events, never, nil).Run(ctx, req.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := backfills.Get(ctx, req.ID); got.TotalEvents != 0 || got.Report.Compared != 0 {
+		t.Errorf("unexpected backfill %+v", got)
+	}
+
+	// Cancelling a running backfill stops it before the next execution.
+	req = &BackfillRequest{PipelineName: "orders", Concurrency: 1}
+	_ = backfills.Create(ctx, req)
+	var runs atomic.Int64
+	cancelling := func(context.Context, BackfillRun) (map[string]map[string]any, error) {
+		if runs.Add(1) == 1 {
+			if err := backfills.Cancel(ctx, req.ID); err != nil {
+				t.Error(err)
+			}
+		}
+		return nil, nil
+	}
+	if err := NewBackfillRunner(backfills, nil, events, cancelling, nil).Run(ctx, req.ID); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := backfills.Get(ctx, req.ID)
+	if got.Status != BackfillStatusCancelled || runs.Load() >= 5 {
+		t.Errorf("expected the backfill to stop when cancelled, got %q after %d runs", got.Status, runs.Load())
+	}
+}
+
+func TestBackfillSampling(t *testing.T) {
+	ids := make([]uuid.UUID, 2000)
+	for i := range ids {
+		ids[i] = uuid.New()
+	}
+	n := 0
+	for _, id := range ids {
+		if sampled(id, 0.25) {
+			n++
+		}
+		if sampled(id, 0.25) != sampled(id, 0.25) || !sampled(id, 0) || !sampled(id, 1) {
+			t.Fatal("expected sampling to be deterministic and 0 or 1 to sample all")
+		}
+	}
+	if n < 400 || n > 600 {
+		t.Errorf("expected about 500 of 2000 executions sampled at 0.25, got %d", n)
+	}
+
+	start := time.Now()
+	end := start.Add(-time.Minute)
+	for _, req := range []BackfillRequest{
+		{SampleRate: 1.5},
+		{SampleRate: -0.1},
+		{Concurrency: MaxBackfillConcurrency + 1},
+		{StartTime: &start, EndTime: &end},
+	} {
+		if err := ValidateBackfillRequest(&req); err == nil {
+			t.Errorf("expected %+v to be rejected", req)
+		}
+	}
+}
@@ -50,8 +50,10 @@ type DLQFilter struct {
 	StepName     string
 	Status       DLQStatus
 	ErrorType    string
-	Limit        int
-	Offset       int
+	// CreatedBefore limits results to entries created before this time.
+	CreatedBefore *time.Time
+	Limit         int
+	Offset        int
 }
 
 // ---------------------------------------------------------------------------
@@ -78,6 +80,8 @@ type DLQStore interface {
 	Resolve(ctx context.Context, id uuid.UUID) error
 	// Purge removes resolved/discarded entries older than the given duration.
 	Purge(ctx context.Context, olderThan time.Duration) (int64, error)
+	// Delete removes a single entry regardless of its status.
+	Delete(ctx context.Context, id uuid.UUID) error
 }
 
 // ===========================================================================
@@ -255,6 +259,16 @@ func (s *InMemoryDLQStore) Purge(_ context.Context, olderThan time.Duration) (in
 	return count, nil
 }
 
+func (s *InMemoryDLQStore) Delete(_ context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.entries, id)
+	return nil
+}
+
 // matchesDLQFilter checks whether a DLQ entry matches the given filter criteria.
 func matchesDLQFilter(entry *DLQEntry, filter DLQFilter) bool {
 	if filter.PipelineName != "" && entry.PipelineName != filter.PipelineName {
@@ -269,6 +283,9 @@ func matchesDLQFilter(entry *DLQEntry, filter DLQFilter) bool {
 	if filter.ErrorType != "" && entry.ErrorType != filter.ErrorType {
 		return false
 	}
+	if filter.CreatedBefore != nil && !entry.CreatedAt.Before(*filter.CreatedBefore) {
+		return false
+	}
 	return true
 }
 
@@ -552,6 +569,24 @@ func (s *SQLiteDLQStore) Purge(ctx context.Context, olderThan time.Duration) (in
 	return result.RowsAffected()
 }
 
+func (s *SQLiteDLQStore) Delete(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM dlq_entries WHERE id = ?`, id.String())
+	if err != nil {
+		return fmt.Errorf("delete dlq entry: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
 // ---------------------------------------------------------------------------
 // SQLite helpers
 // ---------------------------------------------------------------------------
@@ -585,6 +620,10 @@ func buildDLQQuery(base string, filter DLQFilter) (string, []any) {
 		conditions = append(conditions, "error_type = ?")
 		args = append(args, filter.ErrorType)
 	}
+	if filter.CreatedBefore != nil {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, filter.CreatedBefore.UTC().Format(time.RFC3339Nano))
+	}
 
 	query := base
 	if len(conditions) > 0 {
@@ -438,6 +438,42 @@ func TestDLQResolve(t *testing.T) {
 	}
 }
 
+func TestDLQDelete(t *testing.T) {
+	for _, f := range dlqStoreFactories(t) {
+		t.Run(f.name, func(t *testing.T) {
+			s := f.create(t)
+			ctx := context.Background()
+
+			entry := makeDLQEntry("pipeline-a", "step-1", "transient error", "step_failure")
+			if err := s.Add(ctx, entry); err != nil {
+				t.Fatalf("Add: %v", err)
+			}
+
+			// Entries created before a future cutoff are listed.
+			future := time.Now().Add(time.Hour)
+			listed, err := s.List(ctx, DLQFilter{CreatedBefore: &future})
+			if err != nil || len(listed) != 1 {
+				t.Fatalf("List CreatedBefore future: %d entries, err %v", len(listed), err)
+			}
+			past := time.Now().Add(-time.Hour)
+			if listed, _ := s.List(ctx, DLQFilter{CreatedBefore: &past}); len(listed) != 0 {
+				t.Errorf("List CreatedBefore past: expected 0 entries, got %d", len(listed))
+			}
+
+			// Pending entries can be deleted, unlike Purge.
+			if err := s.Delete(ctx, entry.ID); err != nil {
+				t.Fatalf("Delete: %v", err)
+			}
+			if _, err := s.Get(ctx, entry.ID); err != ErrNotFound {
+				t.Fatalf("expected ErrNotFound after Delete, got %v", err)
+			}
+			if err := s.Delete(ctx, entry.ID); err != ErrNotFound {
+				t.Fatalf("expected ErrNotFound deleting twice, got %v", err)
+			}
+		})
+	}
+}
+
 // ===========================================================================
 // TestDLQPurge
 // ===========================================================================
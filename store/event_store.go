@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -35,6 +36,14 @@ const (
 	EventExecutionCancelled = "execution.cancelled"
 	EventSagaCompensating   = "saga.compensating"
 	EventSagaCompensated    = "saga.compensated"
+
+	// Durable execution: a step.journaled event is recorded before each step
+	// of a durable pipeline, and the startup recovery pass records
+	// execution.interrupted or execution.resumed for executions the server
+	// stopped in the middle of.
+	EventStepJournaled        = "step.journaled"
+	EventExecutionInterrupted = "execution.interrupted"
+	EventExecutionResumed     = "execution.resumed"
 )
 
 // ---------------------------------------------------------------------------
@@ -49,12 +58,19 @@ type ExecutionEvent struct {
 	EventType   string          `json:"event_type"`
 	EventData   json.RawMessage `json:"event_data"`
 	CreatedAt   time.Time       `json:"created_at"`
+	// TenantID is the tenant of the execution. It is taken from the
+	// tenant_id of the first event appended for the execution, normally
+	// execution.started, and is the same for every event of the execution.
+	TenantID string `json:"tenant_id,omitempty"`
 }
 
 // MaterializedStep is a read-optimized view of a single step within an execution.
 type MaterializedStep struct {
-	StepName    string          `json:"step_name"`
-	StepType    string          `json:"step_type,omitempty"`
+	StepName string `json:"step_name"`
+	StepType string `json:"step_type,omitempty"`
+	// Parent names the step this one ran inside, such as the step.parallel
+	// that ran a branch.
+	Parent      string          `json:"parent,omitempty"`
 	Status      string          `json:"status"`
 	InputData   json.RawMessage `json:"input_data,omitempty"`
 	OutputData  json.RawMessage `json:"output_data,omitempty"`
@@ -68,26 +84,46 @@ type MaterializedStep struct {
 // MaterializedExecution is a read-optimized view of a complete execution,
 // materialized from the event stream.
 type MaterializedExecution struct {
-	ExecutionID uuid.UUID          `json:"execution_id"`
-	Pipeline    string             `json:"pipeline,omitempty"`
-	TenantID    string             `json:"tenant_id,omitempty"`
+	ExecutionID uuid.UUID `json:"execution_id"`
+	Pipeline    string    `json:"pipeline,omitempty"`
+	TenantID    string    `json:"tenant_id,omitempty"`
+	// RequestID is the ID of the HTTP request that started the execution,
+	// as set by the http.middleware.requestid middleware.
+	RequestID string `json:"request_id,omitempty"`
+	// Route is the path pattern of the HTTP route whose pipeline ran, e.g.
+	// "/orders/{id}"; empty for pipelines not started by a route.
+	Route string `json:"route,omitempty"`
+	// EngineVersion and ConfigHash identify the engine build and config
+	// revision that produced the execution.
+	EngineVersion string `json:"engine_version,omitempty"`
+	ConfigHash    string `json:"config_hash,omitempty"`
+	// Durable is set for executions of durable pipelines, which journal
+	// each step and are recovered after a server restart.
+	Durable bool `json:"durable,omitempty"`
+	// Resumed counts the times recovery resumed the execution.
+	Resumed     int                `json:"resumed,omitempty"`
 	Status      string             `json:"status"`
 	Steps       []MaterializedStep `json:"steps,omitempty"`
 	Error       string             `json:"error,omitempty"`
 	StartedAt   *time.Time         `json:"started_at,omitempty"`
 	CompletedAt *time.Time         `json:"completed_at,omitempty"`
 	EventCount  int                `json:"event_count"`
+	// Archived is set when the execution was read from the cold tier of a
+	// TieredEventStore rather than the hot store.
+	Archived bool `json:"archived,omitempty"`
 }
 
 // ExecutionEventFilter specifies criteria for listing materialized executions.
 type ExecutionEventFilter struct {
 	Pipeline string
 	TenantID string
-	Status   string
-	Since    *time.Time
-	Until    *time.Time
-	Limit    int
-	Offset   int
+	// RequestID selects the executions started by one HTTP request.
+	RequestID string
+	Status    string
+	Since     *time.Time
+	Until     *time.Time
+	Limit     int
+	Offset    int
 }
 
 // ---------------------------------------------------------------------------
@@ -107,10 +143,38 @@ type EventStore interface {
 	ListExecutions(ctx context.Context, filter ExecutionEventFilter) ([]MaterializedExecution, error)
 }
 
+// LoggedEvent is an event read from the global event log: an execution
+// event together with its position in the log and the pipeline of its
+// execution.
+type LoggedEvent struct {
+	ExecutionEvent
+	Position int64  `json:"position"`
+	Pipeline string `json:"pipeline,omitempty"`
+}
+
+// EventLog reads events across all executions in the order they were
+// appended. Positions increase with every append, so a reader such as a
+// projection can resume after the last position it processed.
+type EventLog interface {
+	// ReadEvents returns up to limit events with a position after the given
+	// one, ordered by position.
+	ReadEvents(ctx context.Context, after int64, limit int) ([]LoggedEvent, error)
+	// HeadPosition returns the position of the last appended event, or 0
+	// when the log is empty.
+	HeadPosition(ctx context.Context) (int64, error)
+}
+
 // ---------------------------------------------------------------------------
 // Materialization helper
 // ---------------------------------------------------------------------------
 
+// eventTenant returns the tenant_id of an event's data, which sets the
+// tenant of the execution when the event is the first one appended for it.
+func eventTenant(data map[string]any) string {
+	v, _ := data["tenant_id"].(string)
+	return v
+}
+
 // materialize replays a sequence of events into a MaterializedExecution.
 func materialize(events []ExecutionEvent) *MaterializedExecution {
 	if len(events) == 0 {
@@ -134,6 +198,9 @@ func materialize(events []ExecutionEvent) *MaterializedExecution {
 		if data == nil {
 			data = map[string]any{}
 		}
+		if m.TenantID == "" {
+			m.TenantID = ev.TenantID
+		}
 
 		switch ev.EventType {
 		case EventExecutionStarted:
@@ -143,9 +210,22 @@ func materialize(events []ExecutionEvent) *MaterializedExecution {
 			if v, ok := data["pipeline"].(string); ok {
 				m.Pipeline = v
 			}
-			if v, ok := data["tenant_id"].(string); ok {
+			if v, ok := data["tenant_id"].(string); ok && v != "" {
 				m.TenantID = v
 			}
+			if v, ok := data["request_id"].(string); ok {
+				m.RequestID = v
+			}
+			if v, ok := data["route"].(string); ok {
+				m.Route = v
+			}
+			if v, ok := data["engine_version"].(string); ok {
+				m.EngineVersion = v
+			}
+			if v, ok := data["config_hash"].(string); ok {
+				m.ConfigHash = v
+			}
+			m.Durable, _ = data["durable"].(bool)
 
 		case EventStepStarted:
 			stepName, _ := data["step_name"].(string)
@@ -161,6 +241,7 @@ func materialize(events []ExecutionEvent) *MaterializedExecution {
 			if v, ok := data["step_type"].(string); ok {
 				step.StepType = v
 			}
+			step.Parent, _ = data["parent_step"].(string)
 			stepIndex[stepName] = len(m.Steps)
 			m.Steps = append(m.Steps, step)
 
@@ -211,6 +292,7 @@ func materialize(events []ExecutionEvent) *MaterializedExecution {
 			if v, ok := data["reason"].(string); ok {
 				step.Error = v
 			}
+			step.Parent, _ = data["parent_step"].(string)
 			stepIndex[stepName] = len(m.Steps)
 			m.Steps = append(m.Steps, step)
 
@@ -253,6 +335,18 @@ func materialize(events []ExecutionEvent) *MaterializedExecution {
 			t := ev.CreatedAt
 			m.CompletedAt = &t
 
+		case EventExecutionInterrupted:
+			m.Status = "interrupted"
+			t := ev.CreatedAt
+			m.CompletedAt = &t
+			if v, ok := data["reason"].(string); ok {
+				m.Error = v
+			}
+
+		case EventExecutionResumed:
+			m.Status = "running"
+			m.Resumed++
+
 		case EventSagaCompensating:
 			m.Status = "compensating"
 
@@ -276,6 +370,8 @@ type InMemoryEventStore struct {
 	mu     sync.RWMutex
 	events map[uuid.UUID][]ExecutionEvent // executionID -> events
 	seqs   map[uuid.UUID]int64            // executionID -> last sequence number
+	log    []LoggedEvent                  // all events in append order
+	head   int64                          // position of the last appended event
 }
 
 // NewInMemoryEventStore creates a new InMemoryEventStore.
@@ -298,6 +394,11 @@ func (s *InMemoryEventStore) Append(_ context.Context, executionID uuid.UUID, ev
 	s.seqs[executionID]++
 	seq := s.seqs[executionID]
 
+	tenantID := eventTenant(data)
+	if prev := s.events[executionID]; len(prev) > 0 {
+		tenantID = prev[0].TenantID
+	}
+
 	ev := ExecutionEvent{
 		ID:          uuid.New(),
 		ExecutionID: executionID,
@@ -305,9 +406,12 @@ func (s *InMemoryEventStore) Append(_ context.Context, executionID uuid.UUID, ev
 		EventType:   eventType,
 		EventData:   raw,
 		CreatedAt:   time.Now(),
+		TenantID:    tenantID,
 	}
 
 	s.events[executionID] = append(s.events[executionID], ev)
+	s.head++
+	s.log = append(s.log, LoggedEvent{ExecutionEvent: ev, Position: s.head})
 	return nil
 }
 
@@ -356,6 +460,9 @@ func (s *InMemoryEventStore) ListExecutions(_ context.Context, filter ExecutionE
 		if len(events) == 0 {
 			continue
 		}
+		if filter.TenantID != "" && events[0].TenantID != filter.TenantID {
+			continue
+		}
 		cp := make([]ExecutionEvent, len(events))
 		copy(cp, events)
 		m := materialize(cp)
@@ -370,6 +477,9 @@ func (s *InMemoryEventStore) ListExecutions(_ context.Context, filter ExecutionE
 		if filter.TenantID != "" && m.TenantID != filter.TenantID {
 			continue
 		}
+		if filter.RequestID != "" && m.RequestID != filter.RequestID {
+			continue
+		}
 		if filter.Status != "" && m.Status != filter.Status {
 			continue
 		}
@@ -413,6 +523,69 @@ func (s *InMemoryEventStore) ListExecutions(_ context.Context, filter ExecutionE
 	return results, nil
 }
 
+// Volume counts the stored executions and events.
+func (s *InMemoryEventStore) Volume(_ context.Context) (TierVolume, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var v TierVolume
+	for _, events := range s.events {
+		if len(events) > 0 {
+			v.Executions++
+			v.Events += int64(len(events))
+		}
+	}
+	return v, nil
+}
+
+// DeleteExecution removes every event of an execution and returns the
+// number of events deleted.
+func (s *InMemoryEventStore) DeleteExecution(_ context.Context, executionID uuid.UUID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := int64(len(s.events[executionID]))
+	delete(s.events, executionID)
+	delete(s.seqs, executionID)
+	kept := s.log[:0]
+	for _, ev := range s.log {
+		if ev.ExecutionID != executionID {
+			kept = append(kept, ev)
+		}
+	}
+	s.log = kept
+	return n, nil
+}
+
+// ReadEvents implements EventLog.
+func (s *InMemoryEventStore) ReadEvents(_ context.Context, after int64, limit int) ([]LoggedEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i := sort.Search(len(s.log), func(i int) bool { return s.log[i].Position > after })
+	var result []LoggedEvent
+	for ; i < len(s.log) && (limit <= 0 || len(result) < limit); i++ {
+		ev := s.log[i]
+		if events := s.events[ev.ExecutionID]; len(events) > 0 && events[0].EventType == EventExecutionStarted {
+			var data struct {
+				Pipeline string `json:"pipeline"`
+			}
+			_ = json.Unmarshal(events[0].EventData, &data)
+			ev.Pipeline = data.Pipeline
+		}
+		result = append(result, ev)
+	}
+	return result, nil
+}
+
+// HeadPosition implements EventLog.
+func (s *InMemoryEventStore) HeadPosition(_ context.Context) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.log) == 0 {
+		return 0, nil
+	}
+	return s.head, nil
+}
+
 // ===========================================================================
 // SQLiteEventStore
 // ===========================================================================
@@ -473,19 +646,67 @@ func (s *SQLiteEventStore) init() error {
 		event_type    TEXT NOT NULL,
 		event_data    TEXT,
 		created_at    TEXT NOT NULL,
+		tenant_id     TEXT NOT NULL DEFAULT '',
 		UNIQUE(execution_id, sequence_num)
 	);
 	CREATE INDEX IF NOT EXISTS idx_execution_events_execution_id ON execution_events(execution_id);
 	CREATE INDEX IF NOT EXISTS idx_execution_events_event_type ON execution_events(event_type);
 	CREATE INDEX IF NOT EXISTS idx_execution_events_created_at ON execution_events(created_at);
+	CREATE INDEX IF NOT EXISTS idx_execution_events_request_id
+		ON execution_events(json_extract(event_data, '$.request_id'))
+		WHERE event_type = 'execution.started';
 	`
 	_, err := s.db.Exec(schema)
 	if err != nil {
 		return fmt.Errorf("create execution_events table: %w", err)
 	}
+	if err := s.migrateTenantColumn(); err != nil {
+		return err
+	}
+	if _, err := s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_execution_events_tenant_id ON execution_events(tenant_id)`); err != nil {
+		return fmt.Errorf("create tenant index: %w", err)
+	}
 	return nil
 }
 
+// migrateTenantColumn adds the tenant_id column to an execution_events
+// table created before it existed, and backfills it for every event from the
+// tenant_id recorded on the execution.started event of its execution.
+func (s *SQLiteEventStore) migrateTenantColumn() error {
+	var n int
+	err := s.db.QueryRow(
+		`SELECT COUNT(*) FROM pragma_table_info('execution_events') WHERE name = 'tenant_id'`,
+	).Scan(&n)
+	if err != nil {
+		return fmt.Errorf("inspect execution_events columns: %w", err)
+	}
+	if n > 0 {
+		return nil
+	}
+	if _, err := s.db.Exec(`ALTER TABLE execution_events ADD COLUMN tenant_id TEXT NOT NULL DEFAULT ''`); err != nil {
+		return fmt.Errorf("add tenant_id column: %w", err)
+	}
+	_, err = s.db.Exec(`
+	UPDATE execution_events SET tenant_id = COALESCE((
+		SELECT json_extract(st.event_data, '$.tenant_id') FROM execution_events st
+		WHERE st.execution_id = execution_events.execution_id AND st.event_type = 'execution.started'
+		LIMIT 1), '')`)
+	if err != nil {
+		return fmt.Errorf("backfill tenant_id column: %w", err)
+	}
+	return nil
+}
+
+// DB returns the underlying *sql.DB connection.
+func (s *SQLiteEventStore) DB() *sql.DB {
+	return s.db
+}
+
+// DBStats returns the statistics of the store's connection pool.
+func (s *SQLiteEventStore) DBStats() sql.DBStats {
+	return s.db.Stats()
+}
+
 // Close closes the underlying database connection.
 func (s *SQLiteEventStore) Close() error {
 	return s.db.Close()
@@ -507,28 +728,31 @@ func (s *SQLiteEventStore) Append(ctx context.Context, executionID uuid.UUID, ev
 	}
 	defer func() { _ = tx.Rollback() }()
 
-	// Get next sequence number for this execution.
+	// Get next sequence number and the tenant of this execution.
 	var maxSeq sql.NullInt64
+	var tenant sql.NullString
 	err = tx.QueryRowContext(ctx,
-		`SELECT MAX(sequence_num) FROM execution_events WHERE execution_id = ?`,
+		`SELECT MAX(sequence_num), MIN(tenant_id) FROM execution_events WHERE execution_id = ?`,
 		executionID.String(),
-	).Scan(&maxSeq)
+	).Scan(&maxSeq, &tenant)
 	if err != nil {
 		return fmt.Errorf("get max sequence: %w", err)
 	}
 
 	seq := int64(1)
+	tenantID := eventTenant(data)
 	if maxSeq.Valid {
 		seq = maxSeq.Int64 + 1
+		tenantID = tenant.String
 	}
 
 	now := time.Now().UTC()
 	id := uuid.New()
 
 	_, err = tx.ExecContext(ctx,
-		`INSERT INTO execution_events (id, execution_id, sequence_num, event_type, event_data, created_at)
-		 VALUES (?, ?, ?, ?, ?, ?)`,
-		id.String(), executionID.String(), seq, eventType, string(raw), now.Format(time.RFC3339Nano),
+		`INSERT INTO execution_events (id, execution_id, sequence_num, event_type, event_data, created_at, tenant_id)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		id.String(), executionID.String(), seq, eventType, string(raw), now.Format(time.RFC3339Nano), tenantID,
 	)
 	if err != nil {
 		return fmt.Errorf("insert event: %w", err)
@@ -539,7 +763,7 @@ func (s *SQLiteEventStore) Append(ctx context.Context, executionID uuid.UUID, ev
 
 func (s *SQLiteEventStore) GetEvents(ctx context.Context, executionID uuid.UUID) ([]ExecutionEvent, error) {
 	rows, err := s.db.QueryContext(ctx,
-		`SELECT id, execution_id, sequence_num, event_type, event_data, created_at
+		`SELECT id, execution_id, sequence_num, event_type, event_data, created_at, tenant_id
 		 FROM execution_events
 		 WHERE execution_id = ?
 		 ORDER BY sequence_num ASC`,
@@ -555,7 +779,7 @@ func (s *SQLiteEventStore) GetEvents(ctx context.Context, executionID uuid.UUID)
 		var ev ExecutionEvent
 		var idStr, execIDStr, dataStr, createdStr string
 
-		if err := rows.Scan(&idStr, &execIDStr, &ev.SequenceNum, &ev.EventType, &dataStr, &createdStr); err != nil {
+		if err := rows.Scan(&idStr, &execIDStr, &ev.SequenceNum, &ev.EventType, &dataStr, &createdStr, &ev.TenantID); err != nil {
 			return nil, fmt.Errorf("scan event: %w", err)
 		}
 
@@ -569,6 +793,30 @@ func (s *SQLiteEventStore) GetEvents(ctx context.Context, executionID uuid.UUID)
 	return events, rows.Err()
 }
 
+// DeleteExecution removes every event of an execution and returns the
+// number of events deleted.
+func (s *SQLiteEventStore) DeleteExecution(ctx context.Context, executionID uuid.UUID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res, err := s.db.ExecContext(ctx, `DELETE FROM execution_events WHERE execution_id = ?`, executionID.String())
+	if err != nil {
+		return 0, fmt.Errorf("delete execution events: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// Volume counts the stored executions and events.
+func (s *SQLiteEventStore) Volume(ctx context.Context) (TierVolume, error) {
+	var v TierVolume
+	err := s.db.QueryRowContext(ctx,
+		`SELECT COUNT(DISTINCT execution_id), COUNT(*) FROM execution_events`,
+	).Scan(&v.Executions, &v.Events)
+	if err != nil {
+		return TierVolume{}, fmt.Errorf("count events: %w", err)
+	}
+	return v, nil
+}
+
 func (s *SQLiteEventStore) GetTimeline(ctx context.Context, executionID uuid.UUID) (*MaterializedExecution, error) {
 	events, err := s.GetEvents(ctx, executionID)
 	if err != nil {
@@ -585,9 +833,23 @@ func (s *SQLiteEventStore) GetTimeline(ctx context.Context, executionID uuid.UUI
 }
 
 func (s *SQLiteEventStore) ListExecutions(ctx context.Context, filter ExecutionEventFilter) ([]MaterializedExecution, error) {
-	// Get distinct execution IDs.
-	rows, err := s.db.QueryContext(ctx,
-		`SELECT DISTINCT execution_id FROM execution_events ORDER BY execution_id`)
+	// Get distinct execution IDs, looking a request ID and a tenant up in
+	// their indexes.
+	var where []string
+	var args []any
+	if filter.RequestID != "" {
+		where = append(where, `event_type = 'execution.started' AND json_extract(event_data, '$.request_id') = ?`)
+		args = append(args, filter.RequestID)
+	}
+	if filter.TenantID != "" {
+		where = append(where, `tenant_id = ?`)
+		args = append(args, filter.TenantID)
+	}
+	query := `SELECT DISTINCT execution_id FROM execution_events`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, ` AND `)
+	}
+	rows, err := s.db.QueryContext(ctx, query+` ORDER BY execution_id`, args...)
 	if err != nil {
 		return nil, fmt.Errorf("query execution IDs: %w", err)
 	}
@@ -624,6 +886,9 @@ func (s *SQLiteEventStore) ListExecutions(ctx context.Context, filter ExecutionE
 		if filter.TenantID != "" && m.TenantID != filter.TenantID {
 			continue
 		}
+		if filter.RequestID != "" && m.RequestID != filter.RequestID {
+			continue
+		}
 		if filter.Status != "" && m.Status != filter.Status {
 			continue
 		}
@@ -667,6 +932,53 @@ func (s *SQLiteEventStore) ListExecutions(ctx context.Context, filter ExecutionE
 	return results, nil
 }
 
+// ReadEvents implements EventLog. The position of an event is its rowid,
+// which SQLite assigns in increasing order as events are appended.
+func (s *SQLiteEventStore) ReadEvents(ctx context.Context, after int64, limit int) ([]LoggedEvent, error) {
+	if limit <= 0 {
+		limit = -1 // no limit
+	}
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT e.rowid, e.id, e.execution_id, e.sequence_num, e.event_type, e.event_data, e.created_at, e.tenant_id,
+		        COALESCE((SELECT json_extract(st.event_data, '$.pipeline') FROM execution_events st
+		                  WHERE st.execution_id = e.execution_id AND st.event_type = 'execution.started'
+		                  LIMIT 1), '')
+		 FROM execution_events e
+		 WHERE e.rowid > ?
+		 ORDER BY e.rowid ASC
+		 LIMIT ?`,
+		after, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query event log: %w", err)
+	}
+	defer rows.Close()
+
+	var events []LoggedEvent
+	for rows.Next() {
+		var ev LoggedEvent
+		var idStr, execIDStr, dataStr, createdStr string
+		if err := rows.Scan(&ev.Position, &idStr, &execIDStr, &ev.SequenceNum, &ev.EventType, &dataStr, &createdStr, &ev.TenantID, &ev.Pipeline); err != nil {
+			return nil, fmt.Errorf("scan event: %w", err)
+		}
+		ev.ID, _ = uuid.Parse(idStr)
+		ev.ExecutionID, _ = uuid.Parse(execIDStr)
+		ev.EventData = json.RawMessage(dataStr)
+		ev.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdStr)
+		events = append(events, ev)
+	}
+	return events, rows.Err()
+}
+
+// HeadPosition implements EventLog.
+func (s *SQLiteEventStore) HeadPosition(ctx context.Context) (int64, error) {
+	var head int64
+	if err := s.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(rowid), 0) FROM execution_events`).Scan(&head); err != nil {
+		return 0, fmt.Errorf("query event log head: %w", err)
+	}
+	return head, nil
+}
+
 // ---------------------------------------------------------------------------
 // Compile-time interface assertions
 // ---------------------------------------------------------------------------
@@ -674,4 +986,6 @@ func (s *SQLiteEventStore) ListExecutions(ctx context.Context, filter ExecutionE
 var (
 	_ EventStore = (*InMemoryEventStore)(nil)
 	_ EventStore = (*SQLiteEventStore)(nil)
+	_ EventLog   = (*InMemoryEventStore)(nil)
+	_ EventLog   = (*SQLiteEventStore)(nil)
 )
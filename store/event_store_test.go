@@ -48,7 +48,13 @@ func eventStoreFactories(t *testing.T) []eventStoreFactory {
 
 func appendStarted(t *testing.T, s EventStore, execID uuid.UUID, pipeline, tenantID string) {
 	t.Helper()
-	data := map[string]any{"pipeline": pipeline, "tenant_id": tenantID}
+	data := map[string]any{
+		"pipeline":       pipeline,
+		"tenant_id":      tenantID,
+		"engine_version": "v1.2.3",
+		"config_hash":    "sha256:abc",
+		"route":          "/orders/{id}",
+	}
 	if err := s.Append(context.Background(), execID, EventExecutionStarted, data); err != nil {
 		t.Fatalf("Append execution.started: %v", err)
 	}
@@ -205,6 +211,12 @@ func TestGetTimeline(t *testing.T) {
 			if timeline.TenantID != "tenant-1" {
 				t.Errorf("expected tenantID 'tenant-1', got %q", timeline.TenantID)
 			}
+			if timeline.EngineVersion != "v1.2.3" || timeline.ConfigHash != "sha256:abc" {
+				t.Errorf("expected engine stamp v1.2.3/sha256:abc, got %q/%q", timeline.EngineVersion, timeline.ConfigHash)
+			}
+			if timeline.Route != "/orders/{id}" {
+				t.Errorf("expected route '/orders/{id}', got %q", timeline.Route)
+			}
 			if timeline.Status != "completed" {
 				t.Errorf("expected status 'completed', got %q", timeline.Status)
 			}
@@ -356,6 +368,47 @@ func TestGetTimeline_SkippedAndRetry(t *testing.T) {
 	}
 }
 
+func TestGetTimeline_NestedBranches(t *testing.T) {
+	for _, f := range eventStoreFactories(t) {
+		t.Run(f.name, func(t *testing.T) {
+			s := f.create(t)
+			execID := uuid.New()
+
+			appendStarted(t, s, execID, "dashboard", "")
+			appendStepStarted(t, s, execID, "fanout")
+			for _, branch := range []string{"orders", "weather"} {
+				if err := s.Append(context.Background(), execID, EventStepStarted, map[string]any{
+					"step_name":   "fanout." + branch,
+					"step_type":   "parallel.branch",
+					"parent_step": "fanout",
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			appendStepCompleted(t, s, execID, "fanout.weather")
+			appendStepCompleted(t, s, execID, "fanout.orders")
+			appendStepCompleted(t, s, execID, "fanout")
+			appendCompleted(t, s, execID)
+
+			timeline, err := s.GetTimeline(context.Background(), execID)
+			if err != nil {
+				t.Fatalf("GetTimeline: %v", err)
+			}
+			if len(timeline.Steps) != 3 {
+				t.Fatalf("expected 3 steps, got %d", len(timeline.Steps))
+			}
+			if timeline.Steps[0].Parent != "" {
+				t.Errorf("fanout parent = %q, want none", timeline.Steps[0].Parent)
+			}
+			for _, step := range timeline.Steps[1:] {
+				if step.Parent != "fanout" || step.Status != "completed" || step.CompletedAt == nil {
+					t.Errorf("branch %s = %+v, want a completed child of fanout", step.StepName, step)
+				}
+			}
+		})
+	}
+}
+
 func TestGetTimeline_SagaCompensation(t *testing.T) {
 	for _, f := range eventStoreFactories(t) {
 		t.Run(f.name, func(t *testing.T) {
@@ -482,6 +535,72 @@ func TestListExecutions(t *testing.T) {
 	}
 }
 
+func TestListExecutions_RequestID(t *testing.T) {
+	for _, f := range eventStoreFactories(t) {
+		t.Run(f.name, func(t *testing.T) {
+			s := f.create(t)
+			ctx := context.Background()
+
+			exec1, exec2 := uuid.New(), uuid.New()
+			for id, requestID := range map[uuid.UUID]string{exec1: "req-1", exec2: "req-2"} {
+				if err := s.Append(ctx, id, EventExecutionStarted, map[string]any{"pipeline": "orders", "request_id": requestID}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			// A step event of another execution mentioning req-1 is no match.
+			other := uuid.New()
+			appendStarted(t, s, other, "orders", "")
+			if err := s.Append(ctx, other, EventStepStarted, map[string]any{"step_name": "a", "request_id": "req-1"}); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := s.ListExecutions(ctx, ExecutionEventFilter{RequestID: "req-1"})
+			if err != nil {
+				t.Fatalf("ListExecutions (request ID): %v", err)
+			}
+			if len(got) != 1 || got[0].ExecutionID != exec1 || got[0].RequestID != "req-1" {
+				t.Fatalf("executions for req-1 = %+v", got)
+			}
+		})
+	}
+}
+
+func TestListExecutions_Tenant(t *testing.T) {
+	for _, f := range eventStoreFactories(t) {
+		t.Run(f.name, func(t *testing.T) {
+			s := f.create(t)
+			ctx := context.Background()
+
+			acme, globex := uuid.New(), uuid.New()
+			appendStarted(t, s, acme, "orders", "acme")
+			appendStarted(t, s, globex, "orders", "globex")
+			// A later event cannot move an execution to another tenant.
+			if err := s.Append(ctx, acme, EventStepStarted, map[string]any{"step_name": "a", "tenant_id": "globex"}); err != nil {
+				t.Fatal(err)
+			}
+			appendCompleted(t, s, acme)
+
+			events, err := s.GetEvents(ctx, acme)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, ev := range events {
+				if ev.TenantID != "acme" {
+					t.Errorf("event %s tenant = %q, want acme", ev.EventType, ev.TenantID)
+				}
+			}
+
+			got, err := s.ListExecutions(ctx, ExecutionEventFilter{TenantID: "globex"})
+			if err != nil {
+				t.Fatalf("ListExecutions (tenant): %v", err)
+			}
+			if len(got) != 1 || got[0].ExecutionID != globex || got[0].TenantID != "globex" {
+				t.Fatalf("executions for globex = %+v", got)
+			}
+		})
+	}
+}
+
 func TestListExecutions_Pagination(t *testing.T) {
 	for _, f := range eventStoreFactories(t) {
 		t.Run(f.name, func(t *testing.T) {
@@ -666,6 +785,68 @@ func TestSequenceOrdering_IndependentExecutions(t *testing.T) {
 	}
 }
 
+// ===========================================================================
+// TestEventLog
+// ===========================================================================
+
+func TestEventLog_ReadEvents(t *testing.T) {
+	for _, f := range eventStoreFactories(t) {
+		t.Run(f.name, func(t *testing.T) {
+			s := f.create(t)
+			log := s.(EventLog)
+			ctx := context.Background()
+
+			head, err := log.HeadPosition(ctx)
+			if err != nil || head != 0 {
+				t.Fatalf("HeadPosition on empty log = %d, %v", head, err)
+			}
+
+			// Interleave two executions; the log keeps append order.
+			exec1, exec2 := uuid.New(), uuid.New()
+			appendStarted(t, s, exec1, "orders", "")
+			appendStarted(t, s, exec2, "refunds", "")
+			appendStepCompleted(t, s, exec1, "validate")
+			appendCompleted(t, s, exec2)
+			appendCompleted(t, s, exec1)
+
+			events, err := log.ReadEvents(ctx, 0, 0)
+			if err != nil {
+				t.Fatalf("ReadEvents: %v", err)
+			}
+			if len(events) != 5 {
+				t.Fatalf("expected 5 events, got %d", len(events))
+			}
+			wantExec := []uuid.UUID{exec1, exec2, exec1, exec2, exec1}
+			wantPipeline := []string{"orders", "refunds", "orders", "refunds", "orders"}
+			for i, ev := range events {
+				if ev.ExecutionID != wantExec[i] || ev.Pipeline != wantPipeline[i] {
+					t.Errorf("event[%d] = %v/%q, want %v/%q", i, ev.ExecutionID, ev.Pipeline, wantExec[i], wantPipeline[i])
+				}
+				if i > 0 && ev.Position <= events[i-1].Position {
+					t.Errorf("event[%d]: position %d not after %d", i, ev.Position, events[i-1].Position)
+				}
+			}
+
+			head, err = log.HeadPosition(ctx)
+			if err != nil || head != events[4].Position {
+				t.Fatalf("HeadPosition = %d, %v; want %d", head, err, events[4].Position)
+			}
+
+			// Resume after the second event, two at a time.
+			page, err := log.ReadEvents(ctx, events[1].Position, 2)
+			if err != nil {
+				t.Fatalf("ReadEvents (page): %v", err)
+			}
+			if len(page) != 2 || page[0].Position != events[2].Position || page[1].EventType != EventExecutionCompleted {
+				t.Fatalf("page = %+v", page)
+			}
+			if rest, _ := log.ReadEvents(ctx, head, 10); len(rest) != 0 {
+				t.Errorf("expected no events after head, got %d", len(rest))
+			}
+		})
+	}
+}
+
 // ===========================================================================
 // TestConcurrentAppend
 // ===========================================================================
@@ -885,6 +1066,54 @@ func TestSQLiteEventStore_FromDB(t *testing.T) {
 	}
 }
 
+func TestSQLiteEventStore_MigratesTenantColumn(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "legacy.db"))
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	defer db.Close()
+
+	// An execution_events table from before the tenant_id column.
+	execID := uuid.New()
+	_, err = db.Exec(`
+	CREATE TABLE execution_events (
+		id            TEXT PRIMARY KEY,
+		execution_id  TEXT NOT NULL,
+		sequence_num  INTEGER NOT NULL,
+		event_type    TEXT NOT NULL,
+		event_data    TEXT,
+		created_at    TEXT NOT NULL,
+		UNIQUE(execution_id, sequence_num)
+	);
+	INSERT INTO execution_events VALUES
+		('e1', ?, 1, 'execution.started', '{"pipeline":"orders","tenant_id":"acme"}', '2026-01-01T00:00:00Z'),
+		('e2', ?, 2, 'execution.completed', '{}', '2026-01-01T00:00:01Z');
+	`, execID.String(), execID.String())
+	if err != nil {
+		t.Fatalf("create legacy table: %v", err)
+	}
+
+	s, err := NewSQLiteEventStoreFromDB(db)
+	if err != nil {
+		t.Fatalf("NewSQLiteEventStoreFromDB: %v", err)
+	}
+	events, err := s.GetEvents(context.Background(), execID)
+	if err != nil {
+		t.Fatalf("GetEvents: %v", err)
+	}
+	if len(events) != 2 || events[0].TenantID != "acme" || events[1].TenantID != "acme" {
+		t.Fatalf("backfilled events = %+v", events)
+	}
+	appendCompleted(t, s, execID)
+	got, err := s.ListExecutions(context.Background(), ExecutionEventFilter{TenantID: "acme"})
+	if err != nil {
+		t.Fatalf("ListExecutions: %v", err)
+	}
+	if len(got) != 1 || got[0].EventCount != 3 {
+		t.Fatalf("executions for acme = %+v", got)
+	}
+}
+
 func TestSQLiteEventStore_BadPath(t *testing.T) {
 	// Using a path that cannot exist.
 	_, err := NewSQLiteEventStore(filepath.Join(os.DevNull, "impossible", "path.db"))
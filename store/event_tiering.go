@@ -0,0 +1,882 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ColdStorage is the object storage a TieredEventStore moves archived
+// executions to. Keys are slash-separated paths.
+type ColdStorage interface {
+	// Put writes an object, replacing any object with the same key.
+	Put(ctx context.Context, key string, reader io.Reader) error
+	// Get reads an object.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// List returns the keys of the objects directly under prefix.
+	List(ctx context.Context, prefix string) ([]string, error)
+	// Delete removes an object.
+	Delete(ctx context.Context, key string) error
+}
+
+// TieringOptions configures a TieredEventStore.
+type TieringOptions struct {
+	// After is the age, measured from an execution's last event, at which a
+	// finished execution is moved to cold storage.
+	After time.Duration
+	// Prefix is the key prefix of the archive. Default "event-archive".
+	Prefix string
+	// BatchSize is the number of executions per archived batch. Default 500.
+	BatchSize int
+	// MaxPerRun bounds the executions archived by one run. Default 50000.
+	MaxPerRun int
+	// CacheBatches is the number of fetched batches kept in memory for
+	// read-through. Default 8.
+	CacheBatches int
+}
+
+// ArchiveManifest indexes one archived batch. It is written after the batch
+// object has been read back and verified, so a manifest exists only for
+// complete batches; Complete records that the batch's executions have also
+// been removed from the hot store.
+type ArchiveManifest struct {
+	Batch     string    `json:"batch"`
+	Object    string    `json:"object"`
+	SHA256    string    `json:"sha256"`
+	Bytes     int64     `json:"bytes"`
+	Events    int64     `json:"events"`
+	CreatedAt time.Time `json:"created_at"`
+	Complete  bool      `json:"complete"`
+	// Executions are the batch's executions, materialized without steps, so
+	// lists can be filtered without fetching the batch.
+	Executions []MaterializedExecution `json:"executions"`
+}
+
+// ArchiveRun is the report of one archival run.
+type ArchiveRun struct {
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Cutoff     time.Time `json:"cutoff"`
+	Batches    []string  `json:"batches,omitempty"`
+	Archived   int64     `json:"archived"`
+	Events     int64     `json:"events"`
+	Bytes      int64     `json:"bytes"`
+	// Resumed counts executions of batches an earlier, interrupted run had
+	// archived, removed from the hot store by this run.
+	Resumed int64 `json:"resumed"`
+	// OrphansRemoved counts batch objects an interrupted run wrote without a
+	// manifest, deleted by this run.
+	OrphansRemoved int `json:"orphans_removed"`
+	// Verified is true when every batch this run wrote was read back with a
+	// matching checksum before its executions left the hot store.
+	Verified bool   `json:"verified"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ArchiveBatchFailure is a batch that failed verification.
+type ArchiveBatchFailure struct {
+	Batch string `json:"batch"`
+	Error string `json:"error"`
+}
+
+// ArchiveVerification is the report of re-reading every archived batch.
+type ArchiveVerification struct {
+	CheckedAt  time.Time             `json:"checked_at"`
+	Batches    int                   `json:"batches"`
+	Executions int64                 `json:"executions"`
+	Failed     []ArchiveBatchFailure `json:"failed,omitempty"`
+	Verified   bool                  `json:"verified"`
+}
+
+// TierVolume counts the data held by one tier of the event store. Batches
+// and Bytes are reported for the cold tier only.
+type TierVolume struct {
+	Executions int64 `json:"executions"`
+	Events     int64 `json:"events"`
+	Batches    int64 `json:"batches,omitempty"`
+	Bytes      int64 `json:"bytes,omitempty"`
+}
+
+// EventStoreStats reports the volume of each tier of an event store. Cold is
+// nil for stores without tiering.
+type EventStoreStats struct {
+	Hot     TierVolume  `json:"hot"`
+	Cold    *TierVolume `json:"cold,omitempty"`
+	After   string      `json:"after,omitempty"`
+	LastRun *ArchiveRun `json:"last_run,omitempty"`
+}
+
+// volumeCounter is implemented by event stores that can count their
+// executions and events without materializing them.
+type volumeCounter interface {
+	Volume(ctx context.Context) (TierVolume, error)
+}
+
+// archivedExecution is one NDJSON line of an archived batch.
+type archivedExecution struct {
+	ExecutionID uuid.UUID        `json:"execution_id"`
+	Events      []ExecutionEvent `json:"events"`
+}
+
+// terminalStatuses are the execution statuses that can be archived; running
+// executions stay in the hot store however old they are.
+var terminalStatuses = map[string]bool{
+	"completed":   true,
+	"failed":      true,
+	"cancelled":   true,
+	"interrupted": true,
+}
+
+// TieredEventStore is an EventStore that moves finished executions older
+// than a configured age from a hot store to cold object storage, as
+// gzip-compressed NDJSON batches indexed by manifests. Reads go to the hot
+// store first and fall through to the archive, fetching and caching the
+// batches they need; results served from the archive are marked Archived.
+//
+// Archival assumes a single writer per archive prefix.
+type TieredEventStore struct {
+	hot    ExecutionDeleter
+	opts   TieringOptions
+	now    func() time.Time
+	logger *slog.Logger
+
+	runMu sync.Mutex // serializes archival and verification runs
+
+	mu        sync.RWMutex
+	cold      ColdStorage
+	loaded    bool
+	manifests map[string]*ArchiveManifest
+	index     map[uuid.UUID]string // execution ID -> batch
+	lastRun   *ArchiveRun
+
+	cacheMu sync.Mutex
+	cache   map[string]*list.Element
+	lru     *list.List
+
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+// cachedBatch is a fetched batch held in the read-through cache.
+type cachedBatch struct {
+	batch  string
+	events map[uuid.UUID][]ExecutionEvent
+}
+
+// NewTieredEventStore creates a tiered store over hot. cold may be nil and
+// set later with SetColdStorage; until then the store serves the hot tier
+// only.
+func NewTieredEventStore(hot ExecutionDeleter, cold ColdStorage, opts TieringOptions, logger *slog.Logger) *TieredEventStore {
+	if opts.Prefix == "" {
+		opts.Prefix = "event-archive"
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+	if opts.MaxPerRun <= 0 {
+		opts.MaxPerRun = 50000
+	}
+	if opts.CacheBatches <= 0 {
+		opts.CacheBatches = 8
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &TieredEventStore{
+		hot:       hot,
+		opts:      opts,
+		now:       time.Now,
+		logger:    logger,
+		cold:      cold,
+		manifests: make(map[string]*ArchiveManifest),
+		index:     make(map[uuid.UUID]string),
+		cache:     make(map[string]*list.Element),
+		lru:       list.New(),
+	}
+}
+
+// SetColdStorage sets the cold storage and forgets the loaded index.
+func (s *TieredEventStore) SetColdStorage(cold ColdStorage) {
+	s.mu.Lock()
+	s.cold = cold
+	s.loaded = false
+	s.manifests = make(map[string]*ArchiveManifest)
+	s.index = make(map[uuid.UUID]string)
+	s.mu.Unlock()
+
+	s.cacheMu.Lock()
+	s.cache = make(map[string]*list.Element)
+	s.lru.Init()
+	s.cacheMu.Unlock()
+}
+
+// Hot returns the hot store.
+func (s *TieredEventStore) Hot() ExecutionDeleter { return s.hot }
+
+func (s *TieredEventStore) manifestKey(batch string) string {
+	return path.Join(s.opts.Prefix, "manifests", batch+".json")
+}
+
+func (s *TieredEventStore) batchKey(batch string) string {
+	return path.Join(s.opts.Prefix, "batches", batch+".ndjson.gz")
+}
+
+// LoadIndex reads every manifest from cold storage and replaces the index.
+func (s *TieredEventStore) LoadIndex(ctx context.Context) error {
+	s.mu.RLock()
+	cold := s.cold
+	s.mu.RUnlock()
+	if cold == nil {
+		return fmt.Errorf("no cold storage configured")
+	}
+
+	keys, err := cold.List(ctx, path.Join(s.opts.Prefix, "manifests"))
+	if err != nil {
+		return fmt.Errorf("list manifests: %w", err)
+	}
+	manifests := make(map[string]*ArchiveManifest, len(keys))
+	index := make(map[uuid.UUID]string)
+	for _, key := range keys {
+		if !strings.HasSuffix(key, ".json") {
+			continue
+		}
+		m, err := readManifest(ctx, cold, key)
+		if err != nil {
+			return err
+		}
+		manifests[m.Batch] = m
+		for i := range m.Executions {
+			index[m.Executions[i].ExecutionID] = m.Batch
+		}
+	}
+
+	s.mu.Lock()
+	s.manifests = manifests
+	s.index = index
+	s.loaded = true
+	s.mu.Unlock()
+	return nil
+}
+
+func readManifest(ctx context.Context, cold ColdStorage, key string) (*ArchiveManifest, error) {
+	rc, err := cold.Get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("read manifest %s: %w", key, err)
+	}
+	defer rc.Close()
+	var m ArchiveManifest
+	if err := json.NewDecoder(rc).Decode(&m); err != nil {
+		return nil, fmt.Errorf("decode manifest %s: %w", key, err)
+	}
+	return &m, nil
+}
+
+func writeManifest(ctx context.Context, cold ColdStorage, key string, m *ArchiveManifest) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return cold.Put(ctx, key, bytes.NewReader(data))
+}
+
+// ensureIndex loads the index on first use of the archive. A failure is
+// logged and leaves the store serving the hot tier.
+func (s *TieredEventStore) ensureIndex(ctx context.Context) {
+	s.mu.RLock()
+	ready := s.loaded || s.cold == nil
+	s.mu.RUnlock()
+	if ready {
+		return
+	}
+	if err := s.LoadIndex(ctx); err != nil {
+		s.logger.Warn("Failed to load event archive index", "error", err)
+	}
+}
+
+// archivedBatch returns the batch holding an archived execution, ignoring
+// batches whose executions may still be in the hot store.
+func (s *TieredEventStore) archivedBatch(ctx context.Context, id uuid.UUID) (*ArchiveManifest, bool) {
+	s.ensureIndex(ctx)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	batch, ok := s.index[id]
+	if !ok {
+		return nil, false
+	}
+	m := s.manifests[batch]
+	return m, m != nil && m.Complete
+}
+
+// IsArchived reports whether an execution is served from cold storage.
+func (s *TieredEventStore) IsArchived(ctx context.Context, id uuid.UUID) bool {
+	_, ok := s.archivedBatch(ctx, id)
+	return ok
+}
+
+// fetchBatch returns the events of a batch, from the cache or from cold
+// storage. Fetched batches are checked against their manifest checksum.
+func (s *TieredEventStore) fetchBatch(ctx context.Context, m *ArchiveManifest) (map[uuid.UUID][]ExecutionEvent, error) {
+	s.cacheMu.Lock()
+	if el, ok := s.cache[m.Batch]; ok {
+		s.lru.MoveToFront(el)
+		events := el.Value.(*cachedBatch).events
+		s.cacheMu.Unlock()
+		return events, nil
+	}
+	s.cacheMu.Unlock()
+
+	s.mu.RLock()
+	cold := s.cold
+	s.mu.RUnlock()
+	if cold == nil {
+		return nil, fmt.Errorf("no cold storage configured")
+	}
+	events, err := readBatch(ctx, cold, m)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+	if _, ok := s.cache[m.Batch]; !ok {
+		s.cache[m.Batch] = s.lru.PushFront(&cachedBatch{batch: m.Batch, events: events})
+		for s.lru.Len() > s.opts.CacheBatches {
+			oldest := s.lru.Back()
+			s.lru.Remove(oldest)
+			delete(s.cache, oldest.Value.(*cachedBatch).batch)
+		}
+	}
+	return events, nil
+}
+
+// readBatch reads and decodes a batch object, verifying its checksum.
+func readBatch(ctx context.Context, cold ColdStorage, m *ArchiveManifest) (map[uuid.UUID][]ExecutionEvent, error) {
+	rc, err := cold.Get(ctx, m.Object)
+	if err != nil {
+		return nil, fmt.Errorf("fetch batch %s: %w", m.Batch, err)
+	}
+	data, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		return nil, fmt.Errorf("fetch batch %s: %w", m.Batch, err)
+	}
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != m.SHA256 {
+		return nil, fmt.Errorf("batch %s: checksum %s does not match manifest %s", m.Batch, got, m.SHA256)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("batch %s: %w", m.Batch, err)
+	}
+	defer zr.Close()
+	out := make(map[uuid.UUID][]ExecutionEvent)
+	sc := bufio.NewScanner(zr)
+	sc.Buffer(make([]byte, 0, 64*1024), 256*1024*1024)
+	for sc.Scan() {
+		var line archivedExecution
+		if err := json.Unmarshal(sc.Bytes(), &line); err != nil {
+			return nil, fmt.Errorf("batch %s: decode: %w", m.Batch, err)
+		}
+		out[line.ExecutionID] = line.Events
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("batch %s: %w", m.Batch, err)
+	}
+	return out, nil
+}
+
+// ---------------------------------------------------------------------------
+// EventStore
+// ---------------------------------------------------------------------------
+
+// Append implements EventStore. Events are always written to the hot store.
+func (s *TieredEventStore) Append(ctx context.Context, executionID uuid.UUID, eventType string, data map[string]any) error {
+	return s.hot.Append(ctx, executionID, eventType, data)
+}
+
+// GetEvents implements EventStore, reading through to the archive for
+// executions no longer in the hot store.
+func (s *TieredEventStore) GetEvents(ctx context.Context, executionID uuid.UUID) ([]ExecutionEvent, error) {
+	events, err := s.hot.GetEvents(ctx, executionID)
+	if err != nil || len(events) > 0 {
+		return events, err
+	}
+	m, ok := s.archivedBatch(ctx, executionID)
+	if !ok {
+		return events, nil
+	}
+	batch, err := s.fetchBatch(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+	return batch[executionID], nil
+}
+
+// GetTimeline implements EventStore, reading through to the archive for
+// executions no longer in the hot store.
+func (s *TieredEventStore) GetTimeline(ctx context.Context, executionID uuid.UUID) (*MaterializedExecution, error) {
+	timeline, err := s.hot.GetTimeline(ctx, executionID)
+	if !errors.Is(err, ErrNotFound) {
+		return timeline, err
+	}
+	m, ok := s.archivedBatch(ctx, executionID)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	batch, err := s.fetchBatch(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+	timeline = materialize(batch[executionID])
+	if timeline == nil {
+		return nil, ErrNotFound
+	}
+	timeline.Archived = true
+	return timeline, nil
+}
+
+// ListExecutions implements EventStore. Archived executions are matched
+// against the manifests, and only the batches holding executions on the
+// requested page are fetched.
+func (s *TieredEventStore) ListExecutions(ctx context.Context, filter ExecutionEventFilter) ([]MaterializedExecution, error) {
+	hotFilter := filter
+	hotFilter.Offset = 0
+	if filter.Limit > 0 {
+		hotFilter.Limit = filter.Offset + filter.Limit
+	}
+	results, err := s.hot.ListExecutions(ctx, hotFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	s.ensureIndex(ctx)
+	s.mu.RLock()
+	for _, m := range s.manifests {
+		if !m.Complete {
+			continue
+		}
+		for i := range m.Executions {
+			if matchesExecutionFilter(&m.Executions[i], filter) {
+				summary := m.Executions[i]
+				summary.Archived = true
+				results = append(results, summary)
+			}
+		}
+	}
+	s.mu.RUnlock()
+
+	sortExecutions(results)
+	if filter.Offset > 0 {
+		if filter.Offset >= len(results) {
+			return nil, nil
+		}
+		results = results[filter.Offset:]
+	}
+	if filter.Limit > 0 && filter.Limit < len(results) {
+		results = results[:filter.Limit]
+	}
+
+	for i := range results {
+		if !results[i].Archived {
+			continue
+		}
+		m, ok := s.archivedBatch(ctx, results[i].ExecutionID)
+		if !ok {
+			continue
+		}
+		batch, err := s.fetchBatch(ctx, m)
+		if err != nil {
+			return nil, err
+		}
+		if full := materialize(batch[results[i].ExecutionID]); full != nil {
+			full.Archived = true
+			results[i] = *full
+		}
+	}
+	return results, nil
+}
+
+// matchesExecutionFilter reports whether an execution matches every
+// criterion of filter except paging.
+func matchesExecutionFilter(m *MaterializedExecution, filter ExecutionEventFilter) bool {
+	switch {
+	case filter.Pipeline != "" && m.Pipeline != filter.Pipeline,
+		filter.TenantID != "" && m.TenantID != filter.TenantID,
+		filter.RequestID != "" && m.RequestID != filter.RequestID,
+		filter.Status != "" && m.Status != filter.Status,
+		filter.Since != nil && (m.StartedAt == nil || m.StartedAt.Before(*filter.Since)),
+		filter.Until != nil && (m.StartedAt == nil || m.StartedAt.After(*filter.Until)):
+		return false
+	}
+	return true
+}
+
+// ---------------------------------------------------------------------------
+// Archival
+// ---------------------------------------------------------------------------
+
+// Archive moves finished executions whose last event is older than the
+// configured age to cold storage. It first completes any run that was
+// interrupted: batches with a manifest are re-verified and their executions
+// removed from the hot store, and batch objects without a manifest are
+// deleted, so their executions are archived again from the hot store.
+func (s *TieredEventStore) Archive(ctx context.Context) (*ArchiveRun, error) {
+	s.mu.RLock()
+	cold := s.cold
+	s.mu.RUnlock()
+	if cold == nil {
+		return nil, fmt.Errorf("no cold storage configured")
+	}
+	if s.opts.After <= 0 {
+		return nil, fmt.Errorf("tiering age must be positive")
+	}
+
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
+	run := &ArchiveRun{StartedAt: s.now().UTC(), Verified: true}
+	run.Cutoff = run.StartedAt.Add(-s.opts.After)
+	finish := func(err error) (*ArchiveRun, error) {
+		if err != nil {
+			run.Error = err.Error()
+			run.Verified = false
+		}
+		run.FinishedAt = s.now().UTC()
+		s.mu.Lock()
+		s.lastRun = run
+		s.mu.Unlock()
+		s.logger.Info("event archive run",
+			"archived", run.Archived, "batches", len(run.Batches), "resumed", run.Resumed,
+			"orphans_removed", run.OrphansRemoved, "verified", run.Verified, "error", run.Error)
+		return run, err
+	}
+
+	if err := s.LoadIndex(ctx); err != nil {
+		return finish(err)
+	}
+	if err := s.resume(ctx, cold, run); err != nil {
+		return finish(err)
+	}
+
+	candidates, err := s.expired(ctx, run.Cutoff)
+	if err != nil {
+		return finish(err)
+	}
+	for start := 0; start < len(candidates); start += s.opts.BatchSize {
+		end := min(start+s.opts.BatchSize, len(candidates))
+		if err := s.archiveBatch(ctx, cold, candidates[start:end], run); err != nil {
+			return finish(err)
+		}
+	}
+	return finish(nil)
+}
+
+// resume completes batches of an interrupted run and removes orphaned batch
+// objects.
+func (s *TieredEventStore) resume(ctx context.Context, cold ColdStorage, run *ArchiveRun) error {
+	s.mu.RLock()
+	var pending []*ArchiveManifest
+	known := make(map[string]bool, len(s.manifests))
+	for batch, m := range s.manifests {
+		known[batch] = true
+		if !m.Complete {
+			pending = append(pending, m)
+		}
+	}
+	s.mu.RUnlock()
+
+	for _, m := range pending {
+		if _, err := readBatch(ctx, cold, m); err != nil {
+			// The hot store still has the executions: drop the batch so
+			// they are archived again.
+			s.logger.Warn("Discarding unverifiable archive batch", "batch", m.Batch, "error", err)
+			if err := s.dropBatch(ctx, cold, m); err != nil {
+				return err
+			}
+			continue
+		}
+		for i := range m.Executions {
+			if _, err := s.hot.DeleteExecution(ctx, m.Executions[i].ExecutionID); err != nil && !errors.Is(err, ErrNotFound) {
+				return fmt.Errorf("batch %s: delete %s: %w", m.Batch, m.Executions[i].ExecutionID, err)
+			}
+			run.Resumed++
+		}
+		if err := s.completeBatch(ctx, cold, m); err != nil {
+			return err
+		}
+	}
+
+	objects, err := cold.List(ctx, path.Join(s.opts.Prefix, "batches"))
+	if err != nil {
+		return fmt.Errorf("list batches: %w", err)
+	}
+	for _, key := range objects {
+		batch := strings.TrimSuffix(path.Base(key), ".ndjson.gz")
+		if known[batch] {
+			continue
+		}
+		if err := cold.Delete(ctx, key); err != nil {
+			return fmt.Errorf("delete orphaned batch %s: %w", key, err)
+		}
+		run.OrphansRemoved++
+	}
+	return nil
+}
+
+// dropBatch removes a batch's manifest, object and index entries.
+func (s *TieredEventStore) dropBatch(ctx context.Context, cold ColdStorage, m *ArchiveManifest) error {
+	if err := cold.Delete(ctx, s.manifestKey(m.Batch)); err != nil {
+		return fmt.Errorf("delete manifest of batch %s: %w", m.Batch, err)
+	}
+	_ = cold.Delete(ctx, m.Object)
+	s.mu.Lock()
+	delete(s.manifests, m.Batch)
+	for i := range m.Executions {
+		if s.index[m.Executions[i].ExecutionID] == m.Batch {
+			delete(s.index, m.Executions[i].ExecutionID)
+		}
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// completeBatch records in the manifest that the batch's executions have
+// left the hot store.
+func (s *TieredEventStore) completeBatch(ctx context.Context, cold ColdStorage, m *ArchiveManifest) error {
+	done := *m
+	done.Complete = true
+	if err := writeManifest(ctx, cold, s.manifestKey(m.Batch), &done); err != nil {
+		return fmt.Errorf("complete manifest of batch %s: %w", m.Batch, err)
+	}
+	s.mu.Lock()
+	s.manifests[m.Batch] = &done
+	s.mu.Unlock()
+	return nil
+}
+
+// expired returns the hot store's finished executions whose last event is
+// older than cutoff, with their events.
+func (s *TieredEventStore) expired(ctx context.Context, cutoff time.Time) ([]archivedExecution, error) {
+	execs, err := s.hot.ListExecutions(ctx, ExecutionEventFilter{Until: &cutoff})
+	if err != nil {
+		return nil, fmt.Errorf("list expired executions: %w", err)
+	}
+	var out []archivedExecution
+	for i := range execs {
+		if len(out) >= s.opts.MaxPerRun {
+			break
+		}
+		if !terminalStatuses[execs[i].Status] {
+			continue
+		}
+		events, err := s.hot.GetEvents(ctx, execs[i].ExecutionID)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", execs[i].ExecutionID, err)
+		}
+		if len(events) == 0 || !events[len(events)-1].CreatedAt.Before(cutoff) {
+			continue
+		}
+		out = append(out, archivedExecution{ExecutionID: execs[i].ExecutionID, Events: events})
+	}
+	return out, nil
+}
+
+// archiveBatch writes one batch, reads it back to verify it, writes its
+// manifest, and only then removes its executions from the hot store.
+func (s *TieredEventStore) archiveBatch(ctx context.Context, cold ColdStorage, execs []archivedExecution, run *ArchiveRun) error {
+	created := s.now().UTC()
+	batch := created.Format("20060102T150405.000000000Z") + "-" + uuid.NewString()[:8]
+	m := &ArchiveManifest{Batch: batch, Object: s.batchKey(batch), CreatedAt: created}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	enc := json.NewEncoder(zw)
+	for _, e := range execs {
+		if err := enc.Encode(e); err != nil {
+			return fmt.Errorf("encode %s: %w", e.ExecutionID, err)
+		}
+		summary := materialize(e.Events)
+		if summary == nil {
+			continue
+		}
+		summary.Steps = nil
+		m.Executions = append(m.Executions, *summary)
+		m.Events += int64(len(e.Events))
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	m.SHA256 = hex.EncodeToString(sum[:])
+	m.Bytes = int64(buf.Len())
+
+	if err := cold.Put(ctx, m.Object, bytes.NewReader(buf.Bytes())); err != nil {
+		return fmt.Errorf("write batch %s: %w", batch, err)
+	}
+	if _, err := readBatch(ctx, cold, m); err != nil {
+		return fmt.Errorf("verify batch %s: %w", batch, err)
+	}
+	if err := writeManifest(ctx, cold, s.manifestKey(batch), m); err != nil {
+		return fmt.Errorf("write manifest of batch %s: %w", batch, err)
+	}
+	s.mu.Lock()
+	s.manifests[batch] = m
+	for i := range m.Executions {
+		s.index[m.Executions[i].ExecutionID] = batch
+	}
+	s.mu.Unlock()
+
+	for _, e := range execs {
+		if _, err := s.hot.DeleteExecution(ctx, e.ExecutionID); err != nil && !errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("batch %s: delete %s: %w", batch, e.ExecutionID, err)
+		}
+	}
+	if err := s.completeBatch(ctx, cold, m); err != nil {
+		return err
+	}
+
+	run.Batches = append(run.Batches, batch)
+	run.Archived += int64(len(m.Executions))
+	run.Events += m.Events
+	run.Bytes += m.Bytes
+	return nil
+}
+
+// Verify re-reads every archived batch and checks it against its manifest
+// checksum and execution list.
+func (s *TieredEventStore) Verify(ctx context.Context) (*ArchiveVerification, error) {
+	s.mu.RLock()
+	cold := s.cold
+	s.mu.RUnlock()
+	if cold == nil {
+		return nil, fmt.Errorf("no cold storage configured")
+	}
+
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	if err := s.LoadIndex(ctx); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	manifests := make([]*ArchiveManifest, 0, len(s.manifests))
+	for _, m := range s.manifests {
+		manifests = append(manifests, m)
+	}
+	s.mu.RUnlock()
+
+	report := &ArchiveVerification{CheckedAt: s.now().UTC(), Batches: len(manifests)}
+	for _, m := range manifests {
+		batch, err := readBatch(ctx, cold, m)
+		if err == nil {
+			for i := range m.Executions {
+				if len(batch[m.Executions[i].ExecutionID]) == 0 {
+					err = fmt.Errorf("execution %s is missing", m.Executions[i].ExecutionID)
+					break
+				}
+			}
+		}
+		if err != nil {
+			report.Failed = append(report.Failed, ArchiveBatchFailure{Batch: m.Batch, Error: err.Error()})
+			continue
+		}
+		report.Executions += int64(len(m.Executions))
+	}
+	report.Verified = len(report.Failed) == 0
+	return report, nil
+}
+
+// LastRun returns the report of the most recent archival run.
+func (s *TieredEventStore) LastRun() *ArchiveRun {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lastRun
+}
+
+// Stats reports the volume of the hot and cold tiers.
+func (s *TieredEventStore) Stats(ctx context.Context) (*EventStoreStats, error) {
+	hot, err := storeVolume(ctx, s.hot)
+	if err != nil {
+		return nil, err
+	}
+	s.ensureIndex(ctx)
+	stats := &EventStoreStats{Hot: hot, Cold: &TierVolume{}, After: s.opts.After.String()}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, m := range s.manifests {
+		if !m.Complete {
+			continue
+		}
+		stats.Cold.Batches++
+		stats.Cold.Executions += int64(len(m.Executions))
+		stats.Cold.Events += m.Events
+		stats.Cold.Bytes += m.Bytes
+	}
+	stats.LastRun = s.lastRun
+	return stats, nil
+}
+
+// storeVolume counts a store's executions and events, materializing them
+// when the store cannot count them directly.
+func storeVolume(ctx context.Context, es EventStore) (TierVolume, error) {
+	if vc, ok := es.(volumeCounter); ok {
+		return vc.Volume(ctx)
+	}
+	execs, err := es.ListExecutions(ctx, ExecutionEventFilter{})
+	if err != nil {
+		return TierVolume{}, err
+	}
+	v := TierVolume{Executions: int64(len(execs))}
+	for i := range execs {
+		v.Events += int64(execs[i].EventCount)
+	}
+	return v, nil
+}
+
+// Start runs Archive every interval until Stop is called.
+func (s *TieredEventStore) Start(interval time.Duration) {
+	s.stopCh = make(chan struct{})
+	s.doneCh = make(chan struct{})
+	go func() {
+		defer close(s.doneCh)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stopCh:
+				return
+			case <-ticker.C:
+				if _, err := s.Archive(context.Background()); err != nil {
+					s.logger.Error("event archive run failed", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop ends the periodic loop started by Start and waits for it to exit.
+func (s *TieredEventStore) Stop() {
+	if s.stopCh == nil {
+		return
+	}
+	close(s.stopCh)
+	<-s.doneCh
+	s.stopCh = nil
+}
+
+var _ EventStore = (*TieredEventStore)(nil)
@@ -0,0 +1,271 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// memColdStorage is an in-memory ColdStorage.
+type memColdStorage struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	gets    int
+}
+
+func newMemColdStorage() *memColdStorage {
+	return &memColdStorage{objects: make(map[string][]byte)}
+}
+
+func (m *memColdStorage) Put(_ context.Context, key string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[key] = data
+	return nil
+}
+
+func (m *memColdStorage) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.objects[key]
+	if !ok {
+		return nil, errors.New("no such object")
+	}
+	m.gets++
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *memColdStorage) List(_ context.Context, prefix string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var keys []string
+	for k := range m.objects {
+		if path.Dir(k) == prefix {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+
+func (m *memColdStorage) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, key)
+	return nil
+}
+
+func (m *memColdStorage) keys(prefix string) []string {
+	keys, _ := m.List(context.Background(), prefix)
+	return keys
+}
+
+// newTieredTestStore returns a tiered store whose clock is two days ahead,
+// so executions recorded now are past its one-day age.
+func newTieredTestStore() (*TieredEventStore, *InMemoryEventStore, *memColdStorage) {
+	hot := NewInMemoryEventStore()
+	cold := newMemColdStorage()
+	s := NewTieredEventStore(hot, cold, TieringOptions{After: 24 * time.Hour, BatchSize: 2, CacheBatches: 1}, nil)
+	s.now = func() time.Time { return time.Now().Add(48 * time.Hour) }
+	return s, hot, cold
+}
+
+func TestTieredEventStore_ArchiveAndReadThrough(t *testing.T) {
+	ctx := context.Background()
+	s, hot, cold := newTieredTestStore()
+
+	var finished []uuid.UUID
+	for range 3 {
+		id := uuid.New()
+		appendStarted(t, hot, id, "orders", "")
+		appendStepStarted(t, hot, id, "load")
+		appendStepCompleted(t, hot, id, "load")
+		appendCompleted(t, hot, id)
+		finished = append(finished, id)
+	}
+	running := uuid.New()
+	appendStarted(t, hot, running, "orders", "")
+
+	run, err := s.Archive(ctx)
+	if err != nil {
+		t.Fatalf("Archive: %v", err)
+	}
+	if run.Archived != 3 || len(run.Batches) != 2 || !run.Verified {
+		t.Fatalf("run = %+v", run)
+	}
+	if v, _ := hot.Volume(ctx); v.Executions != 1 {
+		t.Fatalf("hot store keeps %d executions, want only the running one", v.Executions)
+	}
+	if len(cold.keys("event-archive/manifests")) != 2 || len(cold.keys("event-archive/batches")) != 2 {
+		t.Fatalf("cold objects = %v", cold.objects)
+	}
+
+	timeline, err := s.GetTimeline(ctx, finished[0])
+	if err != nil {
+		t.Fatalf("GetTimeline: %v", err)
+	}
+	if !timeline.Archived || timeline.Status != "completed" || len(timeline.Steps) != 1 {
+		t.Errorf("archived timeline = %+v", timeline)
+	}
+	events, err := s.GetEvents(ctx, finished[1])
+	if err != nil || len(events) != 4 {
+		t.Fatalf("GetEvents = %d events, %v", len(events), err)
+	}
+	if live, err := s.GetTimeline(ctx, running); err != nil || live.Archived {
+		t.Errorf("hot timeline = %+v, %v", live, err)
+	}
+	if _, err := s.GetTimeline(ctx, uuid.New()); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unknown execution err = %v", err)
+	}
+
+	all, err := s.ListExecutions(ctx, ExecutionEventFilter{Pipeline: "orders"})
+	if err != nil {
+		t.Fatalf("ListExecutions: %v", err)
+	}
+	archived := 0
+	for _, e := range all {
+		if e.Archived {
+			archived++
+			if len(e.Steps) != 1 {
+				t.Errorf("archived list entry has %d steps, want 1", len(e.Steps))
+			}
+		}
+	}
+	if len(all) != 4 || archived != 3 {
+		t.Errorf("list = %d executions, %d archived", len(all), archived)
+	}
+	page, err := s.ListExecutions(ctx, ExecutionEventFilter{Pipeline: "orders", Limit: 2, Offset: 1})
+	if err != nil || len(page) != 2 {
+		t.Fatalf("paged list = %d, %v", len(page), err)
+	}
+
+	stats, err := s.Stats(ctx)
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if stats.Hot.Executions != 1 || stats.Cold.Executions != 3 || stats.Cold.Events != 12 || stats.Cold.Batches != 2 || stats.LastRun != run {
+		t.Errorf("stats = %+v, cold %+v", stats, stats.Cold)
+	}
+
+	// A second run finds nothing new.
+	again, err := s.Archive(ctx)
+	if err != nil || again.Archived != 0 || again.Resumed != 0 {
+		t.Errorf("second run = %+v, %v", again, err)
+	}
+}
+
+func TestTieredEventStore_KeepsRecentExecutions(t *testing.T) {
+	s, hot, _ := newTieredTestStore()
+	s.now = time.Now
+	id := uuid.New()
+	appendStarted(t, hot, id, "orders", "")
+	appendCompleted(t, hot, id)
+
+	run, err := s.Archive(context.Background())
+	if err != nil || run.Archived != 0 {
+		t.Fatalf("run = %+v, %v", run, err)
+	}
+}
+
+func TestTieredEventStore_ResumesInterruptedRun(t *testing.T) {
+	ctx := context.Background()
+	s, hot, cold := newTieredTestStore()
+	id := uuid.New()
+	appendStarted(t, hot, id, "orders", "")
+	appendCompleted(t, hot, id)
+
+	// Simulate a run that wrote its batch and manifest but stopped before
+	// removing the execution from the hot store, plus an orphaned object.
+	run := &ArchiveRun{}
+	events, _ := hot.GetEvents(ctx, id)
+	if err := s.archiveBatch(ctx, cold, []archivedExecution{{ExecutionID: id, Events: events}}, run); err != nil {
+		t.Fatal(err)
+	}
+	manifestKey := s.manifestKey(run.Batches[0])
+	m, err := readManifest(ctx, cold, manifestKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Complete = false
+	if err := writeManifest(ctx, cold, manifestKey, m); err != nil {
+		t.Fatal(err)
+	}
+	appendStarted(t, hot, id, "orders", "")
+	_ = cold.Put(ctx, s.batchKey("orphan"), strings.NewReader("partial"))
+
+	resumed, err := s.Archive(ctx)
+	if err != nil {
+		t.Fatalf("Archive: %v", err)
+	}
+	if resumed.Resumed != 1 || resumed.OrphansRemoved != 1 || resumed.Archived != 0 {
+		t.Fatalf("run = %+v", resumed)
+	}
+	if remaining, _ := hot.GetEvents(ctx, id); len(remaining) != 0 {
+		t.Errorf("hot store still has %d events", len(remaining))
+	}
+	if m, _ := readManifest(ctx, cold, manifestKey); !m.Complete {
+		t.Error("manifest not marked complete")
+	}
+	if timeline, err := s.GetTimeline(ctx, id); err != nil || !timeline.Archived {
+		t.Errorf("timeline = %+v, %v", timeline, err)
+	}
+}
+
+func TestTieredEventStore_VerifyDetectsCorruption(t *testing.T) {
+	ctx := context.Background()
+	s, hot, cold := newTieredTestStore()
+	id := uuid.New()
+	appendStarted(t, hot, id, "orders", "")
+	appendCompleted(t, hot, id)
+	run, err := s.Archive(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := s.Verify(ctx)
+	if err != nil || !report.Verified || report.Executions != 1 {
+		t.Fatalf("verify = %+v, %v", report, err)
+	}
+
+	object := s.batchKey(run.Batches[0])
+	cold.objects[object] = append(cold.objects[object], 0)
+	report, err = s.Verify(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Verified || len(report.Failed) != 1 || !strings.Contains(report.Failed[0].Error, "checksum") {
+		t.Errorf("verify = %+v", report)
+	}
+}
+
+func TestTieredEventStore_CachesBatches(t *testing.T) {
+	ctx := context.Background()
+	s, hot, cold := newTieredTestStore()
+	id := uuid.New()
+	appendStarted(t, hot, id, "orders", "")
+	appendCompleted(t, hot, id)
+	if _, err := s.Archive(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	gets := cold.gets
+	for range 3 {
+		if _, err := s.GetEvents(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if cold.gets-gets != 1 {
+		t.Errorf("fetched the batch %d times, want 1", cold.gets-gets)
+	}
+}
@@ -92,14 +92,23 @@ func (d *DiffCalculator) Compare(ctx context.Context, execA, execB uuid.UUID) (*
 	stepsA := extractSteps(eventsA)
 	stepsB := extractSteps(eventsB)
 
-	// Collect all unique step names preserving order of first appearance.
-	allSteps := mergeStepNames(stepsA, stepsB)
-
 	diff := &ExecutionDiff{
 		ExecutionA: execA,
 		ExecutionB: execB,
 	}
+	diff.StepDiffs, diff.Summary = diffSteps(stepsA, stepsB)
+	return diff, nil
+}
 
+// diffSteps compares the steps of two executions by name.
+func diffSteps(stepsA, stepsB map[string]*stepInfo) ([]StepDiff, DiffSummary) {
+	// Collect all unique step names preserving order of first appearance.
+	allSteps := mergeStepNames(stepsA, stepsB)
+
+	var (
+		diffs   []StepDiff
+		summary DiffSummary
+	)
 	for _, stepName := range allSteps {
 		infoA, inA := stepsA[stepName]
 		infoB, inB := stepsB[stepName]
@@ -116,30 +125,30 @@ func (d *DiffCalculator) Compare(ctx context.Context, execA, execB uuid.UUID) (*
 			sd.Changes = DiffMaps(infoA.output, infoB.output)
 			if len(sd.Changes) == 0 {
 				sd.Status = "same"
-				diff.Summary.SameSteps++
+				summary.SameSteps++
 			} else {
 				sd.Status = "different"
-				diff.Summary.DiffSteps++
+				summary.DiffSteps++
 			}
 
 		case inA && !inB:
 			sd.Status = "removed"
 			sd.OutputA = infoA.output
 			sd.DurationA = stepDuration(infoA)
-			diff.Summary.RemovedSteps++
+			summary.RemovedSteps++
 
 		case !inA && inB:
 			sd.Status = "added"
 			sd.OutputB = infoB.output
 			sd.DurationB = stepDuration(infoB)
-			diff.Summary.AddedSteps++
+			summary.AddedSteps++
 		}
 
-		diff.StepDiffs = append(diff.StepDiffs, sd)
+		diffs = append(diffs, sd)
 	}
 
-	diff.Summary.TotalSteps = len(allSteps)
-	return diff, nil
+	summary.TotalSteps = len(allSteps)
+	return diffs, summary
 }
 
 // extractSteps groups events by step name and extracts output data and timing.
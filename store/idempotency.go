@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -100,6 +101,36 @@ func (s *InMemoryIdempotencyStore) Cleanup(_ context.Context) (int64, error) {
 	return count, nil
 }
 
+// ListCreatedBefore returns up to limit records created before the given
+// time whose key sorts after afterKey, ordered by key.
+func (s *InMemoryIdempotencyStore) ListCreatedBefore(_ context.Context, before time.Time, afterKey string, limit int) ([]*IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var results []*IdempotencyRecord
+	for key, rec := range s.records {
+		if key > afterKey && rec.CreatedAt.Before(before) {
+			cp := *rec
+			results = append(results, &cp)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Key < results[j].Key })
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// Delete removes a key whether or not it has expired.
+func (s *InMemoryIdempotencyStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.records[key]; !ok {
+		return ErrNotFound
+	}
+	delete(s.records, key)
+	return nil
+}
+
 // ---------------------------------------------------------------------------
 // SQLiteIdempotencyStore
 // ---------------------------------------------------------------------------
@@ -213,6 +244,54 @@ func (s *SQLiteIdempotencyStore) Cleanup(ctx context.Context) (int64, error) {
 	return res.RowsAffected()
 }
 
+// ListCreatedBefore returns up to limit records created before the given
+// time whose key sorts after afterKey, ordered by key.
+func (s *SQLiteIdempotencyStore) ListCreatedBefore(ctx context.Context, before time.Time, afterKey string, limit int) ([]*IdempotencyRecord, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT key, execution_id, step_name, result, created_at, expires_at
+		 FROM idempotency_keys
+		 WHERE created_at < ? AND key > ?
+		 ORDER BY key
+		 LIMIT ?`,
+		before.UTC().Format("2006-01-02 15:04:05"), afterKey, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list idempotency keys: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*IdempotencyRecord
+	for rows.Next() {
+		var rec IdempotencyRecord
+		var execID string
+		var result sql.NullString
+		var createdAt, expiresAt string
+		if err := rows.Scan(&rec.Key, &execID, &rec.StepName, &result, &createdAt, &expiresAt); err != nil {
+			return nil, fmt.Errorf("scan idempotency key: %w", err)
+		}
+		rec.ExecutionID, _ = uuid.Parse(execID)
+		if result.Valid {
+			rec.Result = json.RawMessage(result.String)
+		}
+		rec.CreatedAt, _ = parseSQLiteTime(createdAt)
+		rec.ExpiresAt, _ = parseSQLiteTime(expiresAt)
+		results = append(results, &rec)
+	}
+	return results, rows.Err()
+}
+
+// Delete removes a key whether or not it has expired.
+func (s *SQLiteIdempotencyStore) Delete(ctx context.Context, key string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE key = ?`, key)
+	if err != nil {
+		return fmt.Errorf("delete idempotency key: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
 // isUniqueViolation checks if an error is a SQLite UNIQUE constraint violation.
 func isUniqueViolation(err error) bool {
 	if err == nil {
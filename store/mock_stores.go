@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"encoding/json"
 	"sort"
 	"sync"
 	"sync/atomic"
@@ -1131,6 +1132,9 @@ func (s *MockAuditStore) Query(_ context.Context, f AuditFilter) ([]*AuditEntry,
 		if f.Until != nil && e.CreatedAt.After(*f.Until) {
 			continue
 		}
+		if f.Pipeline != "" && auditEntryPipeline(e) != f.Pipeline {
+			continue
+		}
 		cp := *e
 		if e.UserID != nil {
 			uid := *e.UserID
@@ -1145,6 +1149,29 @@ func (s *MockAuditStore) Query(_ context.Context, f AuditFilter) ([]*AuditEntry,
 	return applyPagination(results, f.Pagination), nil
 }
 
+func (s *MockAuditStore) Delete(_ context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, e := range s.entries {
+		if e.ID == id {
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// auditEntryPipeline returns details.metadata.pipeline of an audit entry.
+func auditEntryPipeline(e *AuditEntry) string {
+	var details struct {
+		Metadata struct {
+			Pipeline string `json:"pipeline"`
+		} `json:"metadata"`
+	}
+	_ = json.Unmarshal(e.Details, &details)
+	return details.Metadata.Pipeline
+}
+
 // ---------------------------------------------------------------------------
 // MockIAMStore
 // ---------------------------------------------------------------------------
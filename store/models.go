@@ -307,9 +307,12 @@ type AuditFilter struct {
 	Action       string
 	ResourceType string
 	ResourceID   *uuid.UUID
-	Since        *time.Time
-	Until        *time.Time
-	Pagination   Pagination
+	// Pipeline matches entries written by step.audit in the named pipeline
+	// (details.metadata.pipeline).
+	Pipeline   string
+	Since      *time.Time
+	Until      *time.Time
+	Pagination Pagination
 }
 
 // IAMProviderFilter specifies criteria for listing IAM providers.
@@ -50,6 +50,11 @@ func (s *PGAuditStore) Query(ctx context.Context, f AuditFilter) ([]*AuditEntry,
 		args = append(args, *f.ResourceID)
 		idx++
 	}
+	if f.Pipeline != "" {
+		query += fmt.Sprintf(` AND details->'metadata'->>'pipeline' = $%d`, idx)
+		args = append(args, f.Pipeline)
+		idx++
+	}
 	if f.Since != nil {
 		query += fmt.Sprintf(` AND created_at >= $%d`, idx)
 		args = append(args, *f.Since)
@@ -86,3 +91,15 @@ func (s *PGAuditStore) Query(ctx context.Context, f AuditFilter) ([]*AuditEntry,
 	}
 	return entries, rows.Err()
 }
+
+// Delete removes a single audit entry. It is used by retention enforcement.
+func (s *PGAuditStore) Delete(ctx context.Context, id int64) error {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM audit_log WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete audit entry: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
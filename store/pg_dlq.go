@@ -249,6 +249,17 @@ func (s *PGDLQStore) Purge(ctx context.Context, olderThan time.Duration) (int64,
 	return tag.RowsAffected(), nil
 }
 
+func (s *PGDLQStore) Delete(ctx context.Context, id uuid.UUID) error {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM dlq_entries WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete dlq entry: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
 // ---------------------------------------------------------------------------
 // PostgreSQL helpers
 // ---------------------------------------------------------------------------
@@ -277,6 +288,11 @@ func buildPGDLQQuery(base string, filter DLQFilter) (string, []any) {
 	if filter.ErrorType != "" {
 		conditions = append(conditions, fmt.Sprintf("error_type = $%d", idx))
 		args = append(args, filter.ErrorType)
+		idx++
+	}
+	if filter.CreatedBefore != nil {
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", idx))
+		args = append(args, filter.CreatedBefore.UTC())
 	}
 
 	query := base
@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -36,15 +37,54 @@ func (s *PGEventStore) init(ctx context.Context) error {
 			event_type    TEXT        NOT NULL,
 			event_data    JSONB,
 			created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			tenant_id     TEXT        NOT NULL DEFAULT '',
 			UNIQUE(execution_id, sequence_num)
 		);
 		CREATE INDEX IF NOT EXISTS idx_execution_events_execution_id ON execution_events(execution_id);
 		CREATE INDEX IF NOT EXISTS idx_execution_events_event_type   ON execution_events(event_type);
 		CREATE INDEX IF NOT EXISTS idx_execution_events_created_at   ON execution_events(created_at);
+		CREATE INDEX IF NOT EXISTS idx_execution_events_request_id   ON execution_events((event_data->>'request_id'))
+			WHERE event_type = 'execution.started';
 	`)
 	if err != nil {
 		return fmt.Errorf("create execution_events table: %w", err)
 	}
+	if err := s.migrateTenantColumn(ctx); err != nil {
+		return err
+	}
+	if _, err := s.pool.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_execution_events_tenant_id ON execution_events(tenant_id)`); err != nil {
+		return fmt.Errorf("create tenant index: %w", err)
+	}
+	return nil
+}
+
+// migrateTenantColumn adds the tenant_id column to an execution_events
+// table created before it existed, and backfills it for every event from the
+// tenant_id recorded on the execution.started event of its execution.
+func (s *PGEventStore) migrateTenantColumn(ctx context.Context) error {
+	var exists bool
+	err := s.pool.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM information_schema.columns
+			WHERE table_name = 'execution_events' AND column_name = 'tenant_id'
+		)`).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("inspect execution_events columns: %w", err)
+	}
+	if exists {
+		return nil
+	}
+	_, err = s.pool.Exec(ctx, `
+		ALTER TABLE execution_events ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT '';
+		UPDATE execution_events e SET tenant_id = st.event_data->>'tenant_id'
+		FROM execution_events st
+		WHERE st.execution_id = e.execution_id
+		  AND st.event_type = 'execution.started'
+		  AND COALESCE(st.event_data->>'tenant_id', '') <> '';
+	`)
+	if err != nil {
+		return fmt.Errorf("add tenant_id column: %w", err)
+	}
 	return nil
 }
 
@@ -63,28 +103,33 @@ func (s *PGEventStore) Append(ctx context.Context, executionID uuid.UUID, eventT
 		return fmt.Errorf("acquire advisory lock: %w", err)
 	}
 
-	// Get next sequence number for this execution.
+	// Get next sequence number and the tenant of this execution.
 	var maxSeq *int64
+	var tenant *string
 	err = tx.QueryRow(ctx,
-		`SELECT MAX(sequence_num) FROM execution_events WHERE execution_id = $1`,
+		`SELECT MAX(sequence_num), MIN(tenant_id) FROM execution_events WHERE execution_id = $1`,
 		executionID,
-	).Scan(&maxSeq)
+	).Scan(&maxSeq, &tenant)
 	if err != nil {
 		return fmt.Errorf("get max sequence: %w", err)
 	}
 
 	seq := int64(1)
+	tenantID := eventTenant(data)
 	if maxSeq != nil {
 		seq = *maxSeq + 1
+		if tenant != nil {
+			tenantID = *tenant
+		}
 	}
 
 	id := uuid.New()
 	now := time.Now().UTC()
 
 	_, err = tx.Exec(ctx,
-		`INSERT INTO execution_events (id, execution_id, sequence_num, event_type, event_data, created_at)
-		 VALUES ($1, $2, $3, $4, $5, $6)`,
-		id, executionID, seq, eventType, data, now,
+		`INSERT INTO execution_events (id, execution_id, sequence_num, event_type, event_data, created_at, tenant_id)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		id, executionID, seq, eventType, data, now, tenantID,
 	)
 	if err != nil {
 		return fmt.Errorf("insert event: %w", err)
@@ -103,7 +148,7 @@ func executionIDToLockKey(id uuid.UUID) int64 {
 
 func (s *PGEventStore) GetEvents(ctx context.Context, executionID uuid.UUID) ([]ExecutionEvent, error) {
 	rows, err := s.pool.Query(ctx,
-		`SELECT id, execution_id, sequence_num, event_type, event_data, created_at
+		`SELECT id, execution_id, sequence_num, event_type, event_data, created_at, tenant_id
 		 FROM execution_events
 		 WHERE execution_id = $1
 		 ORDER BY sequence_num ASC`,
@@ -118,7 +163,7 @@ func (s *PGEventStore) GetEvents(ctx context.Context, executionID uuid.UUID) ([]
 	for rows.Next() {
 		var ev ExecutionEvent
 		var data []byte
-		if err := rows.Scan(&ev.ID, &ev.ExecutionID, &ev.SequenceNum, &ev.EventType, &data, &ev.CreatedAt); err != nil {
+		if err := rows.Scan(&ev.ID, &ev.ExecutionID, &ev.SequenceNum, &ev.EventType, &data, &ev.CreatedAt, &ev.TenantID); err != nil {
 			return nil, fmt.Errorf("scan event: %w", err)
 		}
 		if data != nil {
@@ -145,9 +190,23 @@ func (s *PGEventStore) GetTimeline(ctx context.Context, executionID uuid.UUID) (
 }
 
 func (s *PGEventStore) ListExecutions(ctx context.Context, filter ExecutionEventFilter) ([]MaterializedExecution, error) {
-	// Get distinct execution IDs.
-	rows, err := s.pool.Query(ctx,
-		`SELECT DISTINCT execution_id FROM execution_events ORDER BY execution_id`)
+	// Get distinct execution IDs, looking a request ID and a tenant up in
+	// their indexes.
+	var where []string
+	var args []any
+	if filter.RequestID != "" {
+		args = append(args, filter.RequestID)
+		where = append(where, fmt.Sprintf(`event_type = 'execution.started' AND event_data->>'request_id' = $%d`, len(args)))
+	}
+	if filter.TenantID != "" {
+		args = append(args, filter.TenantID)
+		where = append(where, fmt.Sprintf(`tenant_id = $%d`, len(args)))
+	}
+	query := `SELECT DISTINCT execution_id FROM execution_events`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, ` AND `)
+	}
+	rows, err := s.pool.Query(ctx, query+` ORDER BY execution_id`, args...)
 	if err != nil {
 		return nil, fmt.Errorf("query execution IDs: %w", err)
 	}
@@ -183,6 +242,9 @@ func (s *PGEventStore) ListExecutions(ctx context.Context, filter ExecutionEvent
 		if filter.TenantID != "" && m.TenantID != filter.TenantID {
 			continue
 		}
+		if filter.RequestID != "" && m.RequestID != filter.RequestID {
+			continue
+		}
 		if filter.Status != "" && m.Status != filter.Status {
 			continue
 		}
@@ -213,6 +275,28 @@ func (s *PGEventStore) ListExecutions(ctx context.Context, filter ExecutionEvent
 	return results, nil
 }
 
+// DeleteExecution removes every event of an execution and returns the
+// number of events deleted.
+func (s *PGEventStore) DeleteExecution(ctx context.Context, executionID uuid.UUID) (int64, error) {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM execution_events WHERE execution_id = $1`, executionID)
+	if err != nil {
+		return 0, fmt.Errorf("delete execution events: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// Volume counts the stored executions and events.
+func (s *PGEventStore) Volume(ctx context.Context) (TierVolume, error) {
+	var v TierVolume
+	err := s.pool.QueryRow(ctx,
+		`SELECT COUNT(DISTINCT execution_id), COUNT(*) FROM execution_events`,
+	).Scan(&v.Executions, &v.Events)
+	if err != nil {
+		return TierVolume{}, fmt.Errorf("count events: %w", err)
+	}
+	return v, nil
+}
+
 // sortExecutions sorts MaterializedExecution slice by StartedAt descending.
 func sortExecutions(results []MaterializedExecution) {
 	sort.Slice(results, func(i, j int) bool {
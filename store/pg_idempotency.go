@@ -115,6 +115,49 @@ func (s *PGIdempotencyStore) Cleanup(ctx context.Context) (int64, error) {
 	return tag.RowsAffected(), nil
 }
 
+// ListCreatedBefore returns up to limit records created before the given
+// time whose key sorts after afterKey, ordered by key.
+func (s *PGIdempotencyStore) ListCreatedBefore(ctx context.Context, before time.Time, afterKey string, limit int) ([]*IdempotencyRecord, error) {
+	rows, err := s.pool.Query(ctx,
+		`SELECT key, execution_id, step_name, result, created_at, expires_at
+		 FROM idempotency_keys
+		 WHERE created_at < $1 AND key > $2
+		 ORDER BY key
+		 LIMIT $3`,
+		before, afterKey, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list idempotency keys: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*IdempotencyRecord
+	for rows.Next() {
+		var rec IdempotencyRecord
+		var resultJSON []byte
+		if err := rows.Scan(&rec.Key, &rec.ExecutionID, &rec.StepName, &resultJSON, &rec.CreatedAt, &rec.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("scan idempotency key: %w", err)
+		}
+		if resultJSON != nil {
+			rec.Result = json.RawMessage(resultJSON)
+		}
+		results = append(results, &rec)
+	}
+	return results, rows.Err()
+}
+
+// Delete removes a key whether or not it has expired.
+func (s *PGIdempotencyStore) Delete(ctx context.Context, key string) error {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM idempotency_keys WHERE key = $1`, key)
+	if err != nil {
+		return fmt.Errorf("delete idempotency key: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
 // ---------------------------------------------------------------------------
 // Compile-time interface assertion
 // ---------------------------------------------------------------------------
@@ -0,0 +1,624 @@
+package store
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/config"
+	"github.com/google/uuid"
+)
+
+// RetentionKind identifies a category of persisted data governed by a
+// retention policy.
+type RetentionKind string
+
+const (
+	RetentionIdempotency  RetentionKind = "idempotency"
+	RetentionDLQ          RetentionKind = "dlq"
+	RetentionAudit        RetentionKind = "audit"
+	RetentionStateHistory RetentionKind = "state_history"
+	RetentionExecutions   RetentionKind = "executions"
+)
+
+// retentionKindOrder is the order kinds are enforced in. Executions go last
+// because idempotency keys are attributed to workflows through the
+// execution that wrote them.
+var retentionKindOrder = []RetentionKind{
+	RetentionIdempotency,
+	RetentionDLQ,
+	RetentionAudit,
+	RetentionStateHistory,
+	RetentionExecutions,
+}
+
+// RetentionScope identifies the data a workflow owns: rows written by its
+// pipelines and instances of its state machine definitions.
+type RetentionScope struct {
+	Workflow      string
+	Pipelines     []string
+	StateMachines []string
+}
+
+// ownsPipeline reports whether pipeline belongs to the scope.
+func (s RetentionScope) ownsPipeline(pipeline string) bool {
+	for _, p := range s.Pipelines {
+		if p == pipeline {
+			return true
+		}
+	}
+	return false
+}
+
+// RetentionRecord is one expired row found by a RetentionSource.
+type RetentionRecord struct {
+	// ID identifies the record to its source's Delete.
+	ID string
+	// Data is the full record, written as one NDJSON line when archiving.
+	Data any
+}
+
+// RetentionSource finds and deletes expired data of one kind.
+type RetentionSource interface {
+	// Expired returns up to limit records owned by scope that expired
+	// before the given time.
+	Expired(ctx context.Context, scope RetentionScope, before time.Time, limit int) ([]RetentionRecord, error)
+	// Delete removes a record and returns the number of underlying rows
+	// removed (an execution is stored as many events).
+	Delete(ctx context.Context, rec RetentionRecord) (int64, error)
+}
+
+// RetentionArchiver receives archived records. StorageProvider satisfies it.
+type RetentionArchiver interface {
+	Put(ctx context.Context, path string, reader io.Reader) error
+}
+
+// RetentionRule is a parsed per-kind retention rule.
+type RetentionRule struct {
+	MaxAge time.Duration `json:"max_age"`
+	Action string        `json:"action"`
+}
+
+// RetentionPolicy is a workflow's effective retention policy.
+type RetentionPolicy struct {
+	RetentionScope
+	Rules          map[RetentionKind]RetentionRule
+	ArchiveStorage string
+	ArchivePrefix  string
+}
+
+// NewRetentionPolicy converts a config retention policy.
+func NewRetentionPolicy(p *config.RetentionPolicy) (RetentionPolicy, error) {
+	if err := config.ValidateRetention(p.Retention); err != nil {
+		return RetentionPolicy{}, fmt.Errorf("workflow %q: %w", p.Workflow, err)
+	}
+	policy := RetentionPolicy{
+		RetentionScope: RetentionScope{
+			Workflow:      p.Workflow,
+			Pipelines:     p.Pipelines,
+			StateMachines: p.StateMachines,
+		},
+		Rules:         make(map[RetentionKind]RetentionRule),
+		ArchivePrefix: "retention",
+	}
+	for kind, rule := range p.Retention.Rules() {
+		age, _ := config.ParseRetentionAge(rule.MaxAge)
+		action := rule.Action
+		if action == "" {
+			action = config.RetentionActionDelete
+		}
+		policy.Rules[RetentionKind(kind)] = RetentionRule{MaxAge: age, Action: action}
+	}
+	if a := p.Retention.Archive; a != nil {
+		policy.ArchiveStorage = a.Storage
+		if a.Prefix != "" {
+			policy.ArchivePrefix = a.Prefix
+		}
+	}
+	return policy, nil
+}
+
+// RetentionKindReport records what one enforcement run did to one kind of
+// data. Verified is true when every matched record was archived as required
+// and deleted, and a follow-up query found no expired records left.
+type RetentionKindReport struct {
+	Kind        RetentionKind `json:"kind"`
+	Action      string        `json:"action"`
+	MaxAge      string        `json:"max_age"`
+	Cutoff      time.Time     `json:"cutoff"`
+	Matched     int64         `json:"matched"`
+	Archived    int64         `json:"archived"`
+	Deleted     int64         `json:"deleted"`
+	RowsDeleted int64         `json:"rows_deleted"`
+	Remaining   int64         `json:"remaining"`
+	Archives    []string      `json:"archives,omitempty"`
+	Verified    bool          `json:"verified"`
+	Skipped     string        `json:"skipped,omitempty"`
+	Error       string        `json:"error,omitempty"`
+}
+
+// RetentionRun is the report of one enforcement run for one workflow.
+type RetentionRun struct {
+	Workflow   string                `json:"workflow"`
+	StartedAt  time.Time             `json:"started_at"`
+	FinishedAt time.Time             `json:"finished_at"`
+	Kinds      []RetentionKindReport `json:"kinds"`
+	Verified   bool                  `json:"verified"`
+}
+
+// RetentionJanitor enforces per-workflow retention policies across the
+// registered sources. Runs are serialized so the periodic loop and manual
+// runs never delete the same rows concurrently.
+type RetentionJanitor struct {
+	runMu sync.Mutex // serializes enforcement runs
+
+	mu        sync.RWMutex
+	sources   map[RetentionKind]RetentionSource
+	policies  map[string]RetentionPolicy
+	lastRuns  map[string]*RetentionRun
+	archiver  func(name string) (RetentionArchiver, error)
+	batchSize int
+	maxPerRun int
+	now       func() time.Time
+	logger    *slog.Logger
+
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+// NewRetentionJanitor creates a janitor with no sources or policies.
+func NewRetentionJanitor(logger *slog.Logger) *RetentionJanitor {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &RetentionJanitor{
+		sources:   make(map[RetentionKind]RetentionSource),
+		policies:  make(map[string]RetentionPolicy),
+		lastRuns:  make(map[string]*RetentionRun),
+		batchSize: 500,
+		maxPerRun: 50000,
+		now:       time.Now,
+		logger:    logger,
+	}
+}
+
+// SetSource registers the source enforcing a kind of data. Kinds without a
+// source are reported as skipped.
+func (j *RetentionJanitor) SetSource(kind RetentionKind, src RetentionSource) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.sources[kind] = src
+}
+
+// SetArchiveResolver sets the function that resolves archive storage names.
+func (j *RetentionJanitor) SetArchiveResolver(fn func(name string) (RetentionArchiver, error)) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.archiver = fn
+}
+
+// SetPolicies replaces the enforced policies. Last-run reports of workflows
+// that still have a policy are kept.
+func (j *RetentionJanitor) SetPolicies(policies []*config.RetentionPolicy) error {
+	parsed := make(map[string]RetentionPolicy, len(policies))
+	var errs []error
+	for _, p := range policies {
+		policy, err := NewRetentionPolicy(p)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		parsed[p.Workflow] = policy
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.policies = parsed
+	return nil
+}
+
+// Policies returns the effective policies sorted by workflow name.
+func (j *RetentionJanitor) Policies() []RetentionPolicy {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	out := make([]RetentionPolicy, 0, len(j.policies))
+	for _, p := range j.policies {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(a, b int) bool { return out[a].Workflow < out[b].Workflow })
+	return out
+}
+
+// Policy returns the effective policy of a workflow.
+func (j *RetentionJanitor) Policy(workflow string) (RetentionPolicy, bool) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	p, ok := j.policies[workflow]
+	return p, ok
+}
+
+// LastRun returns the most recent enforcement report for a workflow.
+func (j *RetentionJanitor) LastRun(workflow string) *RetentionRun {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.lastRuns[workflow]
+}
+
+// Start runs enforcement every interval until Stop is called.
+func (j *RetentionJanitor) Start(interval time.Duration) {
+	j.stopCh = make(chan struct{})
+	j.doneCh = make(chan struct{})
+	go func() {
+		defer close(j.doneCh)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-j.stopCh:
+				return
+			case <-ticker.C:
+				j.RunAll(context.Background())
+			}
+		}
+	}()
+}
+
+// Stop ends the periodic loop started by Start and waits for it to exit.
+func (j *RetentionJanitor) Stop() {
+	if j.stopCh == nil {
+		return
+	}
+	close(j.stopCh)
+	<-j.doneCh
+	j.stopCh = nil
+}
+
+// RunAll enforces every policy and returns the reports.
+func (j *RetentionJanitor) RunAll(ctx context.Context) []*RetentionRun {
+	var runs []*RetentionRun
+	for _, p := range j.Policies() {
+		run, err := j.Run(ctx, p.Workflow)
+		if err != nil {
+			j.logger.Error("retention run failed", "workflow", p.Workflow, "error", err)
+			continue
+		}
+		runs = append(runs, run)
+	}
+	return runs
+}
+
+// Run enforces one workflow's policy and records the report as its last run.
+func (j *RetentionJanitor) Run(ctx context.Context, workflow string) (*RetentionRun, error) {
+	policy, ok := j.Policy(workflow)
+	if !ok {
+		return nil, fmt.Errorf("%w: no retention policy for workflow %q", ErrNotFound, workflow)
+	}
+
+	j.runMu.Lock()
+	defer j.runMu.Unlock()
+
+	run := &RetentionRun{Workflow: workflow, StartedAt: j.now().UTC(), Verified: true}
+	for _, kind := range retentionKindOrder {
+		rule, ok := policy.Rules[kind]
+		if !ok {
+			continue
+		}
+		report := j.enforce(ctx, policy, kind, rule)
+		if !report.Verified {
+			run.Verified = false
+		}
+		run.Kinds = append(run.Kinds, report)
+	}
+	run.FinishedAt = j.now().UTC()
+
+	j.mu.Lock()
+	j.lastRuns[workflow] = run
+	j.mu.Unlock()
+
+	for _, k := range run.Kinds {
+		j.logger.Info("retention enforced",
+			"workflow", workflow, "kind", k.Kind, "action", k.Action,
+			"matched", k.Matched, "archived", k.Archived, "deleted", k.Deleted,
+			"remaining", k.Remaining, "verified", k.Verified, "error", k.Error, "skipped", k.Skipped)
+	}
+	return run, nil
+}
+
+// enforce archives (when required) and deletes one kind of expired data in
+// batches, then re-queries to verify nothing expired is left.
+func (j *RetentionJanitor) enforce(ctx context.Context, policy RetentionPolicy, kind RetentionKind, rule RetentionRule) RetentionKindReport {
+	cutoff := j.now().Add(-rule.MaxAge).UTC()
+	report := RetentionKindReport{Kind: kind, Action: rule.Action, MaxAge: rule.MaxAge.String(), Cutoff: cutoff}
+
+	j.mu.RLock()
+	src := j.sources[kind]
+	resolve := j.archiver
+	j.mu.RUnlock()
+	if src == nil {
+		report.Skipped = "no store configured for this kind of data"
+		report.Verified = true
+		return report
+	}
+
+	var archiver RetentionArchiver
+	if rule.Action == config.RetentionActionArchive {
+		var err error
+		if resolve == nil {
+			err = fmt.Errorf("no archive storage available")
+		} else {
+			archiver, err = resolve(policy.ArchiveStorage)
+		}
+		if err != nil {
+			// Never delete what cannot be archived.
+			report.Error = fmt.Sprintf("archive storage %q: %v", policy.ArchiveStorage, err)
+			return report
+		}
+	}
+
+	for batch := 0; report.Matched < int64(j.maxPerRun); batch++ {
+		recs, err := src.Expired(ctx, policy.RetentionScope, cutoff, j.batchSize)
+		if err != nil {
+			report.Error = fmt.Sprintf("list expired: %v", err)
+			return report
+		}
+		if len(recs) == 0 {
+			break
+		}
+		report.Matched += int64(len(recs))
+
+		if archiver != nil {
+			objectPath := path.Join(policy.ArchivePrefix, policy.Workflow, string(kind),
+				cutoff.Format("20060102T150405.000000000Z")+"-"+strconv.Itoa(batch)+".ndjson.gz")
+			if err := writeRetentionArchive(ctx, archiver, objectPath, recs); err != nil {
+				report.Error = fmt.Sprintf("archive: %v", err)
+				return report
+			}
+			report.Archived += int64(len(recs))
+			report.Archives = append(report.Archives, objectPath)
+		}
+
+		deleted := int64(0)
+		for _, rec := range recs {
+			rows, err := src.Delete(ctx, rec)
+			if err != nil && !errors.Is(err, ErrNotFound) {
+				report.Error = fmt.Sprintf("delete %s: %v", rec.ID, err)
+				return report
+			}
+			deleted++
+			report.RowsDeleted += rows
+		}
+		report.Deleted += deleted
+	}
+
+	remaining, err := src.Expired(ctx, policy.RetentionScope, cutoff, j.batchSize)
+	if err != nil {
+		report.Error = fmt.Sprintf("verify: %v", err)
+		return report
+	}
+	report.Remaining = int64(len(remaining))
+	report.Verified = report.Deleted == report.Matched &&
+		(archiver == nil || report.Archived == report.Matched) &&
+		report.Remaining == 0
+	return report
+}
+
+// writeRetentionArchive writes records as gzip-compressed NDJSON.
+func writeRetentionArchive(ctx context.Context, archiver RetentionArchiver, objectPath string, recs []RetentionRecord) error {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	enc := json.NewEncoder(zw)
+	for _, rec := range recs {
+		if err := enc.Encode(rec.Data); err != nil {
+			return fmt.Errorf("encode %s: %w", rec.ID, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return archiver.Put(ctx, objectPath, &buf)
+}
+
+// ---------------------------------------------------------------------------
+// Sources
+// ---------------------------------------------------------------------------
+
+// ExecutionDeleter is implemented by event stores that can remove an
+// execution's event log.
+type ExecutionDeleter interface {
+	EventStore
+	DeleteExecution(ctx context.Context, executionID uuid.UUID) (int64, error)
+}
+
+// ExecutionRetentionSource expires executions whose most recent event is
+// older than the cutoff, so long-running executions are kept while active.
+type ExecutionRetentionSource struct {
+	Store ExecutionDeleter
+}
+
+// Expired implements RetentionSource.
+func (s *ExecutionRetentionSource) Expired(ctx context.Context, scope RetentionScope, before time.Time, limit int) ([]RetentionRecord, error) {
+	var out []RetentionRecord
+	for _, pipeline := range scope.Pipelines {
+		execs, err := s.Store.ListExecutions(ctx, ExecutionEventFilter{Pipeline: pipeline, Until: &before})
+		if err != nil {
+			return nil, err
+		}
+		for i := range execs {
+			events, err := s.Store.GetEvents(ctx, execs[i].ExecutionID)
+			if err != nil {
+				return nil, err
+			}
+			if len(events) == 0 || !events[len(events)-1].CreatedAt.Before(before) {
+				continue
+			}
+			out = append(out, RetentionRecord{
+				ID:   execs[i].ExecutionID.String(),
+				Data: map[string]any{"execution": execs[i], "events": events},
+			})
+			if len(out) >= limit {
+				return out, nil
+			}
+		}
+	}
+	return out, nil
+}
+
+// Delete implements RetentionSource.
+func (s *ExecutionRetentionSource) Delete(ctx context.Context, rec RetentionRecord) (int64, error) {
+	id, err := uuid.Parse(rec.ID)
+	if err != nil {
+		return 0, err
+	}
+	return s.Store.DeleteExecution(ctx, id)
+}
+
+// DLQRetentionSource expires DLQ entries by creation time, whatever their
+// status.
+type DLQRetentionSource struct {
+	Store DLQStore
+}
+
+// Expired implements RetentionSource.
+func (s *DLQRetentionSource) Expired(ctx context.Context, scope RetentionScope, before time.Time, limit int) ([]RetentionRecord, error) {
+	var out []RetentionRecord
+	for _, pipeline := range scope.Pipelines {
+		entries, err := s.Store.List(ctx, DLQFilter{PipelineName: pipeline, CreatedBefore: &before, Limit: limit - len(out)})
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			out = append(out, RetentionRecord{ID: e.ID.String(), Data: e})
+		}
+		if len(out) >= limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+// Delete implements RetentionSource.
+func (s *DLQRetentionSource) Delete(ctx context.Context, rec RetentionRecord) (int64, error) {
+	id, err := uuid.Parse(rec.ID)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.Store.Delete(ctx, id); err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+// AuditDeleter is implemented by audit stores that can remove entries.
+type AuditDeleter interface {
+	AuditStore
+	Delete(ctx context.Context, id int64) error
+}
+
+// AuditRetentionSource expires audit entries written by step.audit, which
+// records the owning pipeline in details.metadata.pipeline. Entries with no
+// pipeline (e.g. admin operations) are never matched.
+type AuditRetentionSource struct {
+	Store AuditDeleter
+}
+
+// Expired implements RetentionSource.
+func (s *AuditRetentionSource) Expired(ctx context.Context, scope RetentionScope, before time.Time, limit int) ([]RetentionRecord, error) {
+	var out []RetentionRecord
+	until := before.Add(-time.Nanosecond)
+	for _, pipeline := range scope.Pipelines {
+		entries, err := s.Store.Query(ctx, AuditFilter{Pipeline: pipeline, Until: &until, Pagination: Pagination{Limit: limit - len(out)}})
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			out = append(out, RetentionRecord{ID: strconv.FormatInt(e.ID, 10), Data: e})
+		}
+		if len(out) >= limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+// Delete implements RetentionSource.
+func (s *AuditRetentionSource) Delete(ctx context.Context, rec RetentionRecord) (int64, error) {
+	id, err := strconv.ParseInt(rec.ID, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.Store.Delete(ctx, id); err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+// IdempotencyLister is implemented by idempotency stores that support
+// retention enforcement.
+type IdempotencyLister interface {
+	ListCreatedBefore(ctx context.Context, before time.Time, afterKey string, limit int) ([]*IdempotencyRecord, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// IdempotencyRetentionSource expires idempotency keys by creation time. A
+// key belongs to the workflow whose pipeline ran the execution that stored
+// it, resolved through the event store; keys whose execution is no longer
+// recorded are left to the store's own expiry cleanup.
+type IdempotencyRetentionSource struct {
+	Store  IdempotencyLister
+	Events EventStore
+}
+
+// Expired implements RetentionSource.
+func (s *IdempotencyRetentionSource) Expired(ctx context.Context, scope RetentionScope, before time.Time, limit int) ([]RetentionRecord, error) {
+	var out []RetentionRecord
+	owners := make(map[uuid.UUID]string)
+	after := ""
+	for {
+		page, err := s.Store.ListCreatedBefore(ctx, before, after, limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, rec := range page {
+			after = rec.Key
+			pipeline, seen := owners[rec.ExecutionID]
+			if !seen {
+				if m, err := s.Events.GetTimeline(ctx, rec.ExecutionID); err == nil {
+					pipeline = m.Pipeline
+				} else if !errors.Is(err, ErrNotFound) {
+					return nil, err
+				}
+				owners[rec.ExecutionID] = pipeline
+			}
+			if pipeline == "" || !scope.ownsPipeline(pipeline) {
+				continue
+			}
+			out = append(out, RetentionRecord{ID: rec.Key, Data: rec})
+			if len(out) >= limit {
+				return out, nil
+			}
+		}
+		if len(page) < limit {
+			return out, nil
+		}
+	}
+}
+
+// Delete implements RetentionSource.
+func (s *IdempotencyRetentionSource) Delete(ctx context.Context, rec RetentionRecord) (int64, error) {
+	if err := s.Store.Delete(ctx, rec.ID); err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
@@ -0,0 +1,100 @@
+package store
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+)
+
+// RetentionHandler provides HTTP endpoints that report effective retention
+// policies and enforcement runs, and trigger a run on demand.
+type RetentionHandler struct {
+	janitor *RetentionJanitor
+	logger  *slog.Logger
+}
+
+// NewRetentionHandler creates a new RetentionHandler.
+func NewRetentionHandler(janitor *RetentionJanitor, logger *slog.Logger) *RetentionHandler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &RetentionHandler{janitor: janitor, logger: logger}
+}
+
+// RegisterRoutes registers the retention API routes on the given mux.
+func (h *RetentionHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/admin/retention", h.handleList)
+	mux.HandleFunc("GET /api/v1/admin/retention/{workflow}", h.handleGet)
+	mux.HandleFunc("POST /api/v1/admin/retention/{workflow}/run", h.handleRun)
+}
+
+// retentionRuleView is the JSON form of a RetentionRule.
+type retentionRuleView struct {
+	MaxAge string `json:"max_age"`
+	Action string `json:"action"`
+}
+
+// retentionPolicyView is the JSON form of a workflow's effective policy and
+// its last enforcement run.
+type retentionPolicyView struct {
+	Workflow       string                              `json:"workflow"`
+	Pipelines      []string                            `json:"pipelines"`
+	StateMachines  []string                            `json:"state_machines,omitempty"`
+	Rules          map[RetentionKind]retentionRuleView `json:"rules"`
+	ArchiveStorage string                              `json:"archive_storage,omitempty"`
+	ArchivePrefix  string                              `json:"archive_prefix,omitempty"`
+	LastRun        *RetentionRun                       `json:"last_run"`
+}
+
+func (h *RetentionHandler) view(p RetentionPolicy) retentionPolicyView {
+	v := retentionPolicyView{
+		Workflow:       p.Workflow,
+		Pipelines:      p.Pipelines,
+		StateMachines:  p.StateMachines,
+		Rules:          make(map[RetentionKind]retentionRuleView, len(p.Rules)),
+		ArchiveStorage: p.ArchiveStorage,
+		LastRun:        h.janitor.LastRun(p.Workflow),
+	}
+	if p.ArchiveStorage != "" {
+		v.ArchivePrefix = p.ArchivePrefix
+	}
+	for kind, rule := range p.Rules {
+		v.Rules[kind] = retentionRuleView{MaxAge: rule.MaxAge.String(), Action: rule.Action}
+	}
+	return v
+}
+
+func (h *RetentionHandler) handleList(w http.ResponseWriter, _ *http.Request) {
+	policies := h.janitor.Policies()
+	views := make([]retentionPolicyView, 0, len(policies))
+	for _, p := range policies {
+		views = append(views, h.view(p))
+	}
+	writeHandlerJSON(w, http.StatusOK, map[string]any{
+		"policies": views,
+		"total":    len(views),
+	})
+}
+
+func (h *RetentionHandler) handleGet(w http.ResponseWriter, r *http.Request) {
+	p, ok := h.janitor.Policy(r.PathValue("workflow"))
+	if !ok {
+		writeHandlerError(w, http.StatusNotFound, "no retention policy for workflow")
+		return
+	}
+	writeHandlerJSON(w, http.StatusOK, h.view(p))
+}
+
+func (h *RetentionHandler) handleRun(w http.ResponseWriter, r *http.Request) {
+	run, err := h.janitor.Run(r.Context(), r.PathValue("workflow"))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			writeHandlerError(w, http.StatusNotFound, "no retention policy for workflow")
+			return
+		}
+		h.logger.Error("retention run", "error", err)
+		writeHandlerError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	writeHandlerJSON(w, http.StatusOK, run)
+}
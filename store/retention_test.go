@@ -0,0 +1,308 @@
+package store
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/config"
+	"github.com/google/uuid"
+)
+
+// retentionFixture holds in-memory stores populated with data from two
+// workflows: "orders" (pipelines create-order, refund-order) and "reports"
+// (pipeline nightly-report).
+type retentionFixture struct {
+	events      *InMemoryEventStore
+	dlq         *InMemoryDLQStore
+	idempotency *InMemoryIdempotencyStore
+	audit       *MockAuditStore
+	janitor     *RetentionJanitor
+	archiveDir  string
+}
+
+func newRetentionFixture(t *testing.T) *retentionFixture {
+	t.Helper()
+	ctx := context.Background()
+	f := &retentionFixture{
+		events:      NewInMemoryEventStore(),
+		dlq:         NewInMemoryDLQStore(),
+		idempotency: NewInMemoryIdempotencyStore(),
+		audit:       NewMockAuditStore(),
+		archiveDir:  t.TempDir(),
+	}
+	for i, pipeline := range []string{"create-order", "refund-order", "nightly-report"} {
+		execID := uuid.New()
+		_ = f.events.Append(ctx, execID, EventExecutionStarted, map[string]any{"pipeline": pipeline})
+		_ = f.events.Append(ctx, execID, EventExecutionCompleted, map[string]any{})
+		_ = f.dlq.Add(ctx, &DLQEntry{PipelineName: pipeline, StepName: "s", ErrorMessage: "boom"})
+		_ = f.idempotency.Store(ctx, &IdempotencyRecord{
+			Key: pipeline + "-key", ExecutionID: execID, StepName: "s",
+			ExpiresAt: time.Now().Add(24 * time.Hour),
+		})
+		details, _ := json.Marshal(map[string]any{"metadata": map[string]any{"pipeline": pipeline}})
+		_ = f.audit.Record(ctx, &AuditEntry{Action: "order.placed", Details: details})
+		if i == 0 {
+			// An admin audit entry owned by no pipeline.
+			_ = f.audit.Record(ctx, &AuditEntry{Action: "config.changed"})
+		}
+	}
+
+	archive, err := NewLocalStorage(f.archiveDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.janitor = NewRetentionJanitor(nil)
+	f.janitor.SetSource(RetentionExecutions, &ExecutionRetentionSource{Store: f.events})
+	f.janitor.SetSource(RetentionDLQ, &DLQRetentionSource{Store: f.dlq})
+	f.janitor.SetSource(RetentionIdempotency, &IdempotencyRetentionSource{Store: f.idempotency, Events: f.events})
+	f.janitor.SetSource(RetentionAudit, &AuditRetentionSource{Store: f.audit})
+	f.janitor.SetArchiveResolver(func(name string) (RetentionArchiver, error) {
+		if name != "archive" {
+			return nil, errors.New("service not found")
+		}
+		return archive, nil
+	})
+	// Everything written above is two days old as far as the janitor knows.
+	f.janitor.now = func() time.Time { return time.Now().Add(48 * time.Hour) }
+	return f
+}
+
+func ordersPolicy(rules *config.RetentionConfig) []*config.RetentionPolicy {
+	return []*config.RetentionPolicy{{
+		Workflow:  "orders",
+		Pipelines: []string{"create-order", "refund-order"},
+		Retention: rules,
+	}}
+}
+
+func TestRetentionJanitor_DeletesOnlyOwnedExpiredData(t *testing.T) {
+	ctx := context.Background()
+	f := newRetentionFixture(t)
+	err := f.janitor.SetPolicies(ordersPolicy(&config.RetentionConfig{
+		Executions:  &config.RetentionRule{MaxAge: "1d"},
+		DLQ:         &config.RetentionRule{MaxAge: "1d"},
+		Idempotency: &config.RetentionRule{MaxAge: "1d"},
+		Audit:       &config.RetentionRule{MaxAge: "30d"},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run, err := f.janitor.Run(ctx, "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !run.Verified {
+		t.Errorf("expected verified run, got %+v", run)
+	}
+	byKind := make(map[RetentionKind]RetentionKindReport)
+	for _, k := range run.Kinds {
+		byKind[k.Kind] = k
+	}
+	for _, kind := range []RetentionKind{RetentionExecutions, RetentionDLQ, RetentionIdempotency} {
+		r := byKind[kind]
+		if r.Matched != 2 || r.Deleted != 2 || r.Remaining != 0 || !r.Verified {
+			t.Errorf("%s: unexpected report %+v", kind, r)
+		}
+	}
+	if r := byKind[RetentionExecutions]; r.RowsDeleted != 4 {
+		t.Errorf("executions: rows deleted = %d, want 4 events", r.RowsDeleted)
+	}
+	if r := byKind[RetentionAudit]; r.Matched != 0 || r.Deleted != 0 {
+		t.Errorf("audit inside its max age should be kept: %+v", r)
+	}
+
+	// The reports workflow's data is untouched.
+	execs, _ := f.events.ListExecutions(ctx, ExecutionEventFilter{})
+	if len(execs) != 1 || execs[0].Pipeline != "nightly-report" {
+		t.Errorf("remaining executions = %+v", execs)
+	}
+	if n, _ := f.dlq.Count(ctx, DLQFilter{}); n != 1 {
+		t.Errorf("remaining DLQ entries = %d, want 1", n)
+	}
+	if rec, _ := f.idempotency.Check(ctx, "nightly-report-key"); rec == nil {
+		t.Error("reports idempotency key was deleted")
+	}
+	if entries, _ := f.audit.Query(ctx, AuditFilter{}); len(entries) != 4 {
+		t.Errorf("audit entries = %d, want 4", len(entries))
+	}
+
+	if last := f.janitor.LastRun("orders"); last != run {
+		t.Error("LastRun does not return the latest run")
+	}
+}
+
+func TestRetentionJanitor_ArchivesBeforeDeleting(t *testing.T) {
+	ctx := context.Background()
+	f := newRetentionFixture(t)
+	err := f.janitor.SetPolicies(ordersPolicy(&config.RetentionConfig{
+		Archive: &config.RetentionArchiveConfig{Storage: "archive"},
+		Audit:   &config.RetentionRule{MaxAge: "1d", Action: "archive"},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run, err := f.janitor.Run(ctx, "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := run.Kinds[0]
+	if r.Matched != 2 || r.Archived != 2 || r.Deleted != 2 || !r.Verified || len(r.Archives) != 1 {
+		t.Fatalf("unexpected report %+v", r)
+	}
+	if !strings.HasPrefix(r.Archives[0], "retention/orders/audit/") || !strings.HasSuffix(r.Archives[0], ".ndjson.gz") {
+		t.Errorf("archive path = %q", r.Archives[0])
+	}
+
+	file, err := os.Open(filepath.Join(f.archiveDir, r.Archives[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []AuditEntry
+	sc := bufio.NewScanner(zr)
+	for sc.Scan() {
+		var e AuditEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			t.Fatalf("archive line is not JSON: %v", err)
+		}
+		lines = append(lines, e)
+	}
+	if len(lines) != 2 || lines[0].Action != "order.placed" {
+		t.Errorf("archived entries = %+v", lines)
+	}
+}
+
+func TestRetentionJanitor_ArchiveFailureKeepsData(t *testing.T) {
+	ctx := context.Background()
+	f := newRetentionFixture(t)
+	err := f.janitor.SetPolicies(ordersPolicy(&config.RetentionConfig{
+		Archive: &config.RetentionArchiveConfig{Storage: "missing"},
+		DLQ:     &config.RetentionRule{MaxAge: "1d", Action: "archive"},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	run, err := f.janitor.Run(ctx, "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.Verified || run.Kinds[0].Error == "" || run.Kinds[0].Deleted != 0 {
+		t.Errorf("expected unverified run with error and no deletions, got %+v", run.Kinds[0])
+	}
+	if n, _ := f.dlq.Count(ctx, DLQFilter{}); n != 3 {
+		t.Errorf("DLQ entries = %d, want all 3 kept", n)
+	}
+}
+
+func TestRetentionJanitor_SkipsKindsWithoutStore(t *testing.T) {
+	f := newRetentionFixture(t)
+	_ = f.janitor.SetPolicies(ordersPolicy(&config.RetentionConfig{
+		StateHistory: &config.RetentionRule{MaxAge: "1d"},
+	}))
+	run, err := f.janitor.Run(context.Background(), "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.Kinds[0].Skipped == "" {
+		t.Errorf("expected state_history to be skipped, got %+v", run.Kinds[0])
+	}
+	if _, err := f.janitor.Run(context.Background(), "unknown"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown workflow, got %v", err)
+	}
+}
+
+func TestRetentionHandler(t *testing.T) {
+	f := newRetentionFixture(t)
+	_ = f.janitor.SetPolicies(ordersPolicy(&config.RetentionConfig{
+		DLQ: &config.RetentionRule{MaxAge: "1d"},
+	}))
+	mux := http.NewServeMux()
+	NewRetentionHandler(f.janitor, nil).RegisterRoutes(mux)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	do := func(method, path string) (int, map[string]any) {
+		t.Helper()
+		req, _ := http.NewRequest(method, srv.URL+path, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		var body map[string]any
+		_ = json.Unmarshal(data, &body)
+		return resp.StatusCode, body
+	}
+
+	code, body := do(http.MethodGet, "/api/v1/admin/retention/orders")
+	if code != http.StatusOK || body["last_run"] != nil {
+		t.Fatalf("GET policy: %d %v", code, body)
+	}
+	rules, _ := body["rules"].(map[string]any)
+	if dlq, _ := rules["dlq"].(map[string]any); dlq["max_age"] != "24h0m0s" || dlq["action"] != "delete" {
+		t.Errorf("rules = %v", rules)
+	}
+
+	code, body = do(http.MethodPost, "/api/v1/admin/retention/orders/run")
+	if code != http.StatusOK || body["verified"] != true {
+		t.Fatalf("POST run: %d %v", code, body)
+	}
+
+	code, body = do(http.MethodGet, "/api/v1/admin/retention")
+	if code != http.StatusOK || body["total"] != float64(1) {
+		t.Fatalf("GET list: %d %v", code, body)
+	}
+	policies, _ := body["policies"].([]any)
+	first, _ := policies[0].(map[string]any)
+	lastRun, _ := first["last_run"].(map[string]any)
+	kinds, _ := lastRun["kinds"].([]any)
+	if len(kinds) != 1 || kinds[0].(map[string]any)["deleted"] != float64(2) {
+		t.Errorf("last run = %v", lastRun)
+	}
+
+	if code, _ := do(http.MethodPost, "/api/v1/admin/retention/unknown/run"); code != http.StatusNotFound {
+		t.Errorf("unknown workflow: status %d, want 404", code)
+	}
+}
+
+func TestSQLiteEventStore_DeleteExecution(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewSQLiteEventStore(filepath.Join(t.TempDir(), "events.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	keep, drop := uuid.New(), uuid.New()
+	for _, id := range []uuid.UUID{keep, drop} {
+		_ = s.Append(ctx, id, EventExecutionStarted, map[string]any{"pipeline": "p"})
+		_ = s.Append(ctx, id, EventExecutionCompleted, map[string]any{})
+	}
+	n, err := s.DeleteExecution(ctx, drop)
+	if err != nil || n != 2 {
+		t.Fatalf("DeleteExecution = %d, %v; want 2 events", n, err)
+	}
+	if events, _ := s.GetEvents(ctx, drop); len(events) != 0 {
+		t.Errorf("events left for deleted execution: %d", len(events))
+	}
+	if events, _ := s.GetEvents(ctx, keep); len(events) != 2 {
+		t.Errorf("other execution lost events: %d", len(events))
+	}
+}
@@ -0,0 +1,64 @@
+package store
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/GoCodeAlone/workflow/tenant"
+)
+
+// TenantResolver returns the tenant an admin API request is scoped to, or ""
+// when the caller is not scoped to a tenant. A request without a tenant is
+// served only when its context was marked with WithUnscopedAccess.
+type TenantResolver func(r *http.Request) string
+
+// RequestTenant is the default TenantResolver of the timeline, replay and
+// backfill handlers. It returns the tenant ID that tenant.TenantIsolation or
+// module.TenantMiddleware stored in the request context.
+func RequestTenant(r *http.Request) string {
+	return tenant.TenantFromContext(r.Context())
+}
+
+type unscopedAccessKey struct{}
+
+// WithUnscopedAccess marks ctx as belonging to a privileged caller that may
+// read the executions of every tenant. Whoever authenticates the caller sets
+// it; without it, a request that resolves to no tenant is rejected.
+func WithUnscopedAccess(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unscopedAccessKey{}, true)
+}
+
+// HasUnscopedAccess reports whether ctx was marked with WithUnscopedAccess.
+func HasUnscopedAccess(ctx context.Context) bool {
+	v, _ := ctx.Value(unscopedAccessKey{}).(bool)
+	return v
+}
+
+// requireTenantScope rejects requests that resolve to no tenant unless the
+// caller was granted unscoped access, so a handler mounted without an
+// authenticating wrapper fails closed.
+func requireTenantScope(tenantOf TenantResolver, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if tenantOf(r) == "" && !HasUnscopedAccess(r.Context()) {
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": "tenant scope required"})
+			return
+		}
+		next(w, r)
+	}
+}
+
+// inTenantScope reports whether a caller scoped to scope may access an
+// execution of the given tenant. Unscoped callers may access every
+// execution; scoped callers only those of their own tenant, so executions
+// recorded without a tenant are hidden from them.
+func inTenantScope(scope, tenantID string) bool {
+	return scope == "" || scope == tenantID
+}
+
+// executionTenant returns the tenant of the execution the events belong to.
+func executionTenant(events []ExecutionEvent) string {
+	if m := materialize(events); m != nil {
+		return m.TenantID
+	}
+	return ""
+}
@@ -0,0 +1,142 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GoCodeAlone/workflow/tenant"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// tenantScopeFixture serves the timeline, replay and backfill APIs over an
+// event store holding one execution of tenant acme and one of globex.
+type tenantScopeFixture struct {
+	mux    *http.ServeMux
+	acme   uuid.UUID
+	globex uuid.UUID
+}
+
+func newTenantScopeFixture(t *testing.T) *tenantScopeFixture {
+	t.Helper()
+	s := NewInMemoryEventStore()
+	f := &tenantScopeFixture{mux: http.NewServeMux(), acme: uuid.New(), globex: uuid.New()}
+	appendStarted(t, s, f.acme, "orders", "acme")
+	appendCompleted(t, s, f.acme)
+	appendStarted(t, s, f.globex, "orders", "globex")
+	appendCompleted(t, s, f.globex)
+
+	NewTimelineHandler(s, slog.Default()).RegisterRoutes(f.mux)
+	NewReplayHandler(s, slog.Default()).RegisterRoutes(f.mux)
+	NewBackfillMockDiffHandler(NewInMemoryBackfillStore(), NewInMemoryStepMockStore(), NewDiffCalculator(s), slog.Default()).RegisterRoutes(f.mux)
+	return f
+}
+
+// do serves a request made by a caller scoped to tenantID, or by a caller
+// with unscoped access when tenantID is empty.
+func (f *tenantScopeFixture) do(method, path, tenantID string, body []byte) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewReader(body))
+	if tenantID != "" {
+		req = req.WithContext(tenant.ContextWithTenant(req.Context(), tenantID))
+	} else {
+		req = req.WithContext(WithUnscopedAccess(req.Context()))
+	}
+	w := httptest.NewRecorder()
+	f.mux.ServeHTTP(w, req)
+	return w
+}
+
+func TestTimelineHandler_TenantScope(t *testing.T) {
+	f := newTenantScopeFixture(t)
+
+	w := f.do("GET", "/api/v1/admin/executions", "acme", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var list struct {
+		Executions []MaterializedExecution `json:"executions"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Executions, 1)
+	require.Equal(t, f.acme, list.Executions[0].ExecutionID)
+
+	w = f.do("GET", "/api/v1/admin/executions?tenant_id=globex", "acme", nil)
+	require.Equal(t, http.StatusForbidden, w.Code)
+
+	w = f.do("GET", "/api/v1/admin/executions?tenant_id=globex", "", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Executions, 1)
+	require.Equal(t, f.globex, list.Executions[0].ExecutionID)
+
+	for _, path := range []string{"/timeline", "/events", "/replay"} {
+		w = f.do("GET", "/api/v1/admin/executions/"+f.acme.String()+path, "acme", nil)
+		require.Equal(t, http.StatusOK, w.Code, path)
+		w = f.do("GET", "/api/v1/admin/executions/"+f.globex.String()+path, "acme", nil)
+		require.Equal(t, http.StatusNotFound, w.Code, path)
+	}
+
+	w = f.do("POST", "/api/v1/admin/executions/"+f.globex.String()+"/replay", "acme", []byte(`{}`))
+	require.Equal(t, http.StatusNotFound, w.Code)
+	w = f.do("POST", "/api/v1/admin/executions/"+f.acme.String()+"/replay", "acme", []byte(`{}`))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var replay ReplayResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &replay))
+	// The replay belongs to the tenant of the original execution.
+	w = f.do("GET", "/api/v1/admin/executions/"+replay.NewExecutionID.String()+"/replay", "acme", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	w = f.do("GET", "/api/v1/admin/executions/"+replay.NewExecutionID.String()+"/replay", "globex", nil)
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	w = f.do("GET", "/api/v1/admin/executions/stats", "acme", nil)
+	require.Equal(t, http.StatusForbidden, w.Code)
+	w = f.do("POST", "/api/v1/admin/executions/archive", "acme", nil)
+	require.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestBackfillMockDiffHandler_TenantScope(t *testing.T) {
+	f := newTenantScopeFixture(t)
+
+	w := f.do("GET", "/api/v1/admin/executions/diff?a="+f.acme.String()+"&b="+f.globex.String(), "acme", nil)
+	require.Equal(t, http.StatusNotFound, w.Code)
+	w = f.do("GET", "/api/v1/admin/executions/diff?a="+f.acme.String()+"&b="+f.globex.String(), "", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = f.do("POST", "/api/v1/admin/backfill", "acme", []byte(`{"pipeline_name":"orders","tenant_id":"globex"}`))
+	require.Equal(t, http.StatusForbidden, w.Code)
+	w = f.do("POST", "/api/v1/admin/backfill", "acme", []byte(`{"pipeline_name":"orders"}`))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created BackfillRequest
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	require.Equal(t, "acme", created.TenantID)
+
+	w = f.do("GET", "/api/v1/admin/backfill/"+created.ID.String(), "globex", nil)
+	require.Equal(t, http.StatusNotFound, w.Code)
+	w = f.do("POST", "/api/v1/admin/backfill/"+created.ID.String()+"/cancel", "globex", nil)
+	require.Equal(t, http.StatusNotFound, w.Code)
+	w = f.do("GET", "/api/v1/admin/backfill", "globex", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `[]`, w.Body.String())
+	w = f.do("GET", "/api/v1/admin/backfill/"+created.ID.String(), "acme", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = f.do("GET", "/api/v1/admin/mocks", "acme", nil)
+	require.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestTenantScope_RequiresTenantOrUnscopedAccess(t *testing.T) {
+	f := newTenantScopeFixture(t)
+
+	for _, path := range []string{
+		"/api/v1/admin/executions",
+		"/api/v1/admin/executions/" + f.acme.String() + "/timeline",
+		"/api/v1/admin/executions/" + f.acme.String() + "/replay",
+		"/api/v1/admin/backfill",
+	} {
+		w := httptest.NewRecorder()
+		f.mux.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		require.Equal(t, http.StatusForbidden, w.Code, path)
+	}
+}
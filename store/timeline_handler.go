@@ -1,6 +1,7 @@
 package store
 
 import (
+	"context"
 	"encoding/json"
 	"log/slog"
 	"net/http"
@@ -19,9 +20,11 @@ type LogQuerier interface {
 }
 
 // TimelineHandler provides HTTP endpoints for the Execution Timeline API.
+// Requests scoped to a tenant only see that tenant's executions.
 type TimelineHandler struct {
 	store      EventStore
 	logQuerier LogQuerier // optional; enables GET /executions/{id}/logs
+	tenantOf   TenantResolver
 	logger     *slog.Logger
 }
 
@@ -30,7 +33,7 @@ func NewTimelineHandler(store EventStore, logger *slog.Logger) *TimelineHandler
 	if logger == nil {
 		logger = slog.Default()
 	}
-	return &TimelineHandler{store: store, logger: logger}
+	return &TimelineHandler{store: store, tenantOf: RequestTenant, logger: logger}
 }
 
 // WithLogQuerier sets the optional LogQuerier used to serve the logs endpoint.
@@ -39,12 +42,130 @@ func (h *TimelineHandler) WithLogQuerier(lq LogQuerier) *TimelineHandler {
 	return h
 }
 
+// WithTenantResolver replaces RequestTenant as the resolver of the tenant a
+// request is scoped to.
+func (h *TimelineHandler) WithTenantResolver(fn TenantResolver) *TimelineHandler {
+	h.tenantOf = fn
+	return h
+}
+
+// authorizeExecution writes a 404 and returns false when the request is
+// scoped to a tenant other than the execution's. A missing execution is
+// reported the same way, so a scoped caller cannot tell another tenant's
+// executions from unknown ones.
+func (h *TimelineHandler) authorizeExecution(w http.ResponseWriter, r *http.Request, id uuid.UUID) bool {
+	scope := h.tenantOf(r)
+	if scope == "" {
+		return true
+	}
+	events, err := h.store.GetEvents(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to get events", "error", err, "execution_id", id)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return false
+	}
+	if len(events) == 0 || !inTenantScope(scope, executionTenant(events)) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "execution not found"})
+		return false
+	}
+	return true
+}
+
+// rejectTenantScoped writes a 403 and returns true when the request is
+// scoped to a tenant, for endpoints that span every tenant's executions.
+func (h *TimelineHandler) rejectTenantScoped(w http.ResponseWriter, r *http.Request) bool {
+	if h.tenantOf(r) == "" {
+		return false
+	}
+	writeJSON(w, http.StatusForbidden, map[string]string{"error": "not available to tenant-scoped callers"})
+	return true
+}
+
 // RegisterRoutes registers the timeline API routes on the given mux.
 func (h *TimelineHandler) RegisterRoutes(mux *http.ServeMux) {
-	mux.HandleFunc("GET /api/v1/admin/executions", h.listExecutions)
-	mux.HandleFunc("GET /api/v1/admin/executions/{id}/timeline", h.getTimeline)
-	mux.HandleFunc("GET /api/v1/admin/executions/{id}/events", h.getEvents)
-	mux.HandleFunc("GET /api/v1/admin/executions/{id}/logs", h.getExecutionLogs)
+	handle := func(pattern string, fn http.HandlerFunc) {
+		mux.HandleFunc(pattern, requireTenantScope(h.tenantOf, fn))
+	}
+	handle("GET /api/v1/admin/executions", h.listExecutions)
+	handle("GET /api/v1/admin/executions/{id}/timeline", h.getTimeline)
+	handle("GET /api/v1/admin/executions/{id}/events", h.getEvents)
+	handle("GET /api/v1/admin/executions/{id}/logs", h.getExecutionLogs)
+	handle("GET /api/v1/admin/executions/stats", h.getStats)
+	handle("POST /api/v1/admin/executions/archive", h.runArchive)
+	handle("POST /api/v1/admin/executions/archive/verify", h.verifyArchive)
+}
+
+// TieredStore is implemented by event stores with a cold tier, such as
+// *TieredEventStore, to serve the archive endpoints.
+type TieredStore interface {
+	Archive(ctx context.Context) (*ArchiveRun, error)
+	Verify(ctx context.Context) (*ArchiveVerification, error)
+	Stats(ctx context.Context) (*EventStoreStats, error)
+	IsArchived(ctx context.Context, id uuid.UUID) bool
+}
+
+// getStats handles GET /api/v1/admin/executions/stats
+func (h *TimelineHandler) getStats(w http.ResponseWriter, r *http.Request) {
+	if h.rejectTenantScoped(w, r) {
+		return
+	}
+	var stats *EventStoreStats
+	var err error
+	if ts, ok := h.store.(TieredStore); ok {
+		stats, err = ts.Stats(r.Context())
+	} else {
+		var hot TierVolume
+		hot, err = storeVolume(r.Context(), h.store)
+		stats = &EventStoreStats{Hot: hot}
+	}
+	if err != nil {
+		h.logger.Error("Failed to compute event store stats", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// runArchive handles POST /api/v1/admin/executions/archive
+func (h *TimelineHandler) runArchive(w http.ResponseWriter, r *http.Request) {
+	if h.rejectTenantScoped(w, r) {
+		return
+	}
+	ts, ok := h.store.(TieredStore)
+	if !ok {
+		writeJSON(w, http.StatusNotImplemented, map[string]string{"error": "event store tiering not configured"})
+		return
+	}
+	run, err := ts.Archive(r.Context())
+	if err != nil && run == nil {
+		h.logger.Error("Failed to archive executions", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	status := http.StatusOK
+	if err != nil {
+		status = http.StatusInternalServerError
+	}
+	writeJSON(w, status, run)
+}
+
+// verifyArchive handles POST /api/v1/admin/executions/archive/verify
+func (h *TimelineHandler) verifyArchive(w http.ResponseWriter, r *http.Request) {
+	if h.rejectTenantScoped(w, r) {
+		return
+	}
+	ts, ok := h.store.(TieredStore)
+	if !ok {
+		writeJSON(w, http.StatusNotImplemented, map[string]string{"error": "event store tiering not configured"})
+		return
+	}
+	report, err := ts.Verify(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to verify event archive", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
 }
 
 // listExecutions handles GET /api/v1/admin/executions
@@ -52,9 +173,20 @@ func (h *TimelineHandler) listExecutions(w http.ResponseWriter, r *http.Request)
 	q := r.URL.Query()
 
 	filter := ExecutionEventFilter{
-		Pipeline: q.Get("pipeline"),
-		TenantID: q.Get("tenant_id"),
-		Status:   q.Get("status"),
+		Pipeline:  q.Get("pipeline"),
+		TenantID:  q.Get("tenant_id"),
+		RequestID: q.Get("request_id"),
+		Status:    q.Get("status"),
+	}
+
+	// A tenant-scoped caller only lists its own tenant's executions and
+	// may not ask for another's.
+	if scope := h.tenantOf(r); scope != "" {
+		if filter.TenantID != "" && filter.TenantID != scope {
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": "cross-tenant access denied"})
+			return
+		}
+		filter.TenantID = scope
 	}
 
 	if limitStr := q.Get("limit"); limitStr != "" {
@@ -104,6 +236,9 @@ func (h *TimelineHandler) getTimeline(w http.ResponseWriter, r *http.Request) {
 	}
 
 	timeline, err := h.store.GetTimeline(r.Context(), id)
+	if err == nil && !inTenantScope(h.tenantOf(r), timeline.TenantID) {
+		err = ErrNotFound
+	}
 	if err != nil {
 		if err == ErrNotFound {
 			writeJSON(w, http.StatusNotFound, map[string]string{"error": "execution not found"})
@@ -138,6 +273,10 @@ func (h *TimelineHandler) getEvents(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if len(events) > 0 && !inTenantScope(h.tenantOf(r), executionTenant(events)) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "execution not found"})
+		return
+	}
 	if events == nil {
 		events = []ExecutionEvent{}
 	}
@@ -153,10 +292,14 @@ func (h *TimelineHandler) getEvents(w http.ResponseWriter, r *http.Request) {
 		events = filtered
 	}
 
-	writeJSON(w, http.StatusOK, map[string]any{
+	resp := map[string]any{
 		"events": events,
 		"count":  len(events),
-	})
+	}
+	if ts, ok := h.store.(TieredStore); ok && ts.IsArchived(r.Context(), id) {
+		resp["archived"] = true
+	}
+	writeJSON(w, http.StatusOK, resp)
 }
 
 // getExecutionLogs handles GET /api/v1/admin/executions/{id}/logs
@@ -172,6 +315,16 @@ func (h *TimelineHandler) getExecutionLogs(w http.ResponseWriter, r *http.Reques
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "missing execution ID"})
 		return
 	}
+	if h.tenantOf(r) != "" {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid execution ID"})
+			return
+		}
+		if !h.authorizeExecution(w, r, id) {
+			return
+		}
+	}
 
 	q := r.URL.Query()
 	level := q.Get("level")
@@ -232,8 +385,10 @@ type ReplayResult struct {
 }
 
 // ReplayHandler provides HTTP endpoints for the Request Replay API.
+// Requests scoped to a tenant may only replay that tenant's executions.
 type ReplayHandler struct {
 	eventStore EventStore
+	tenantOf   TenantResolver
 	logger     *slog.Logger
 	// ReplayFunc is called to actually replay an execution. It receives the
 	// original execution's timeline and returns a new execution ID.
@@ -246,13 +401,23 @@ func NewReplayHandler(store EventStore, logger *slog.Logger) *ReplayHandler {
 	if logger == nil {
 		logger = slog.Default()
 	}
-	return &ReplayHandler{eventStore: store, logger: logger}
+	return &ReplayHandler{eventStore: store, tenantOf: RequestTenant, logger: logger}
+}
+
+// WithTenantResolver replaces RequestTenant as the resolver of the tenant a
+// request is scoped to.
+func (h *ReplayHandler) WithTenantResolver(fn TenantResolver) *ReplayHandler {
+	h.tenantOf = fn
+	return h
 }
 
 // RegisterRoutes registers replay API routes.
 func (h *ReplayHandler) RegisterRoutes(mux *http.ServeMux) {
-	mux.HandleFunc("POST /api/v1/admin/executions/{id}/replay", h.replayExecution)
-	mux.HandleFunc("GET /api/v1/admin/executions/{id}/replay", h.getReplayInfo)
+	handle := func(pattern string, fn http.HandlerFunc) {
+		mux.HandleFunc(pattern, requireTenantScope(h.tenantOf, fn))
+	}
+	handle("POST /api/v1/admin/executions/{id}/replay", h.replayExecution)
+	handle("GET /api/v1/admin/executions/{id}/replay", h.getReplayInfo)
 }
 
 // replayExecution handles POST /api/v1/admin/executions/{id}/replay
@@ -289,6 +454,9 @@ func (h *ReplayHandler) replayExecution(w http.ResponseWriter, r *http.Request)
 
 	// Get the original execution
 	original, err := h.eventStore.GetTimeline(r.Context(), originalID)
+	if err == nil && !inTenantScope(h.tenantOf(r), original.TenantID) {
+		err = ErrNotFound
+	}
 	if err != nil {
 		if err == ErrNotFound {
 			writeJSON(w, http.StatusNotFound, map[string]string{"error": "original execution not found"})
@@ -314,12 +482,17 @@ func (h *ReplayHandler) replayExecution(w http.ResponseWriter, r *http.Request)
 		status = "started"
 	}
 
-	// Record a replay event in the event store
-	_ = h.eventStore.Append(r.Context(), newExecID, "execution.replay", map[string]any{
+	// Record a replay event in the event store. The replay belongs to the
+	// tenant of the original execution.
+	replayData := map[string]any{
 		"original_execution_id": originalID.String(),
 		"mode":                  req.Mode,
 		"type":                  "replay",
-	})
+	}
+	if original.TenantID != "" {
+		replayData["tenant_id"] = original.TenantID
+	}
+	_ = h.eventStore.Append(r.Context(), newExecID, "execution.replay", replayData)
 
 	result := ReplayResult{
 		OriginalExecutionID: originalID,
@@ -353,6 +526,10 @@ func (h *ReplayHandler) getReplayInfo(w http.ResponseWriter, r *http.Request) {
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
 		return
 	}
+	if len(events) > 0 && !inTenantScope(h.tenantOf(r), executionTenant(events)) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "execution not found"})
+		return
+	}
 
 	var replayEvents []map[string]any
 	for _, ev := range events {
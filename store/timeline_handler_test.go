@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
@@ -32,6 +33,13 @@ func (m *mockLogQuerier) ListExecutionLogs(executionID string, level string, lim
 	return result, nil
 }
 
+// adminRequest returns a request made by a caller with unscoped access,
+// which may read the executions of every tenant.
+func adminRequest(method, target string, body io.Reader) *http.Request {
+	req := httptest.NewRequest(method, target, body)
+	return req.WithContext(WithUnscopedAccess(req.Context()))
+}
+
 func seedExecution(t *testing.T, store EventStore, execID uuid.UUID, pipeline string) {
 	t.Helper()
 	ctx := context.Background()
@@ -67,7 +75,7 @@ func TestTimelineHandler_ListExecutions(t *testing.T) {
 	mux := http.NewServeMux()
 	h.RegisterRoutes(mux)
 
-	req := httptest.NewRequest("GET", "/api/v1/admin/executions", nil)
+	req := adminRequest("GET", "/api/v1/admin/executions", nil)
 	w := httptest.NewRecorder()
 	mux.ServeHTTP(w, req)
 
@@ -97,7 +105,7 @@ func TestTimelineHandler_ListExecutions_FilterPipeline(t *testing.T) {
 	mux := http.NewServeMux()
 	h.RegisterRoutes(mux)
 
-	req := httptest.NewRequest("GET", "/api/v1/admin/executions?pipeline=pipeline-a", nil)
+	req := adminRequest("GET", "/api/v1/admin/executions?pipeline=pipeline-a", nil)
 	w := httptest.NewRecorder()
 	mux.ServeHTTP(w, req)
 
@@ -113,13 +121,37 @@ func TestTimelineHandler_ListExecutions_FilterPipeline(t *testing.T) {
 	}
 }
 
+func TestTimelineHandler_ListExecutions_FilterRequestID(t *testing.T) {
+	store := NewInMemoryEventStore()
+	exec1 := uuid.New()
+	_ = store.Append(context.Background(), exec1, EventExecutionStarted, map[string]any{"pipeline": "pipeline-a", "request_id": "req-42"})
+	_ = store.Append(context.Background(), exec1, EventExecutionCompleted, map[string]any{})
+	seedExecution(t, store, uuid.New(), "pipeline-a")
+
+	h := NewTimelineHandler(store, nil)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	req := adminRequest("GET", "/api/v1/admin/executions?request_id=req-42", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	var resp struct {
+		Executions []MaterializedExecution `json:"executions"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	if len(resp.Executions) != 1 || resp.Executions[0].ExecutionID != exec1 || resp.Executions[0].RequestID != "req-42" {
+		t.Errorf("executions for req-42 = %+v", resp.Executions)
+	}
+}
+
 func TestTimelineHandler_ListExecutions_Empty(t *testing.T) {
 	store := NewInMemoryEventStore()
 	h := NewTimelineHandler(store, nil)
 	mux := http.NewServeMux()
 	h.RegisterRoutes(mux)
 
-	req := httptest.NewRequest("GET", "/api/v1/admin/executions", nil)
+	req := adminRequest("GET", "/api/v1/admin/executions", nil)
 	w := httptest.NewRecorder()
 	mux.ServeHTTP(w, req)
 
@@ -144,7 +176,7 @@ func TestTimelineHandler_GetTimeline(t *testing.T) {
 	mux := http.NewServeMux()
 	h.RegisterRoutes(mux)
 
-	req := httptest.NewRequest("GET", "/api/v1/admin/executions/"+execID.String()+"/timeline", nil)
+	req := adminRequest("GET", "/api/v1/admin/executions/"+execID.String()+"/timeline", nil)
 	w := httptest.NewRecorder()
 	mux.ServeHTTP(w, req)
 
@@ -174,7 +206,7 @@ func TestTimelineHandler_GetTimeline_NotFound(t *testing.T) {
 	mux := http.NewServeMux()
 	h.RegisterRoutes(mux)
 
-	req := httptest.NewRequest("GET", "/api/v1/admin/executions/"+uuid.New().String()+"/timeline", nil)
+	req := adminRequest("GET", "/api/v1/admin/executions/"+uuid.New().String()+"/timeline", nil)
 	w := httptest.NewRecorder()
 	mux.ServeHTTP(w, req)
 
@@ -189,7 +221,7 @@ func TestTimelineHandler_GetTimeline_InvalidID(t *testing.T) {
 	mux := http.NewServeMux()
 	h.RegisterRoutes(mux)
 
-	req := httptest.NewRequest("GET", "/api/v1/admin/executions/invalid-id/timeline", nil)
+	req := adminRequest("GET", "/api/v1/admin/executions/invalid-id/timeline", nil)
 	w := httptest.NewRecorder()
 	mux.ServeHTTP(w, req)
 
@@ -207,7 +239,7 @@ func TestTimelineHandler_GetEvents(t *testing.T) {
 	mux := http.NewServeMux()
 	h.RegisterRoutes(mux)
 
-	req := httptest.NewRequest("GET", "/api/v1/admin/executions/"+execID.String()+"/events", nil)
+	req := adminRequest("GET", "/api/v1/admin/executions/"+execID.String()+"/events", nil)
 	w := httptest.NewRecorder()
 	mux.ServeHTTP(w, req)
 
@@ -232,7 +264,7 @@ func TestTimelineHandler_GetEvents_TypeFilter(t *testing.T) {
 	mux := http.NewServeMux()
 	h.RegisterRoutes(mux)
 
-	req := httptest.NewRequest("GET", "/api/v1/admin/executions/"+execID.String()+"/events?type=step.started", nil)
+	req := adminRequest("GET", "/api/v1/admin/executions/"+execID.String()+"/events?type=step.started", nil)
 	w := httptest.NewRecorder()
 	mux.ServeHTTP(w, req)
 
@@ -260,7 +292,7 @@ func TestReplayHandler_ReplayExact(t *testing.T) {
 	h.RegisterRoutes(mux)
 
 	body := `{"mode": "exact"}`
-	req := httptest.NewRequest("POST", "/api/v1/admin/executions/"+execID.String()+"/replay", bytes.NewBufferString(body))
+	req := adminRequest("POST", "/api/v1/admin/executions/"+execID.String()+"/replay", bytes.NewBufferString(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	mux.ServeHTTP(w, req)
@@ -306,7 +338,7 @@ func TestReplayHandler_ReplayWithFunc(t *testing.T) {
 	h.RegisterRoutes(mux)
 
 	body := `{"mode": "exact"}`
-	req := httptest.NewRequest("POST", "/api/v1/admin/executions/"+execID.String()+"/replay", bytes.NewBufferString(body))
+	req := adminRequest("POST", "/api/v1/admin/executions/"+execID.String()+"/replay", bytes.NewBufferString(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	mux.ServeHTTP(w, req)
@@ -333,7 +365,7 @@ func TestReplayHandler_ReplayNotFound(t *testing.T) {
 	h.RegisterRoutes(mux)
 
 	body := `{"mode": "exact"}`
-	req := httptest.NewRequest("POST", "/api/v1/admin/executions/"+uuid.New().String()+"/replay", bytes.NewBufferString(body))
+	req := adminRequest("POST", "/api/v1/admin/executions/"+uuid.New().String()+"/replay", bytes.NewBufferString(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	mux.ServeHTTP(w, req)
@@ -353,7 +385,7 @@ func TestReplayHandler_ReplayInvalidMode(t *testing.T) {
 	h.RegisterRoutes(mux)
 
 	body := `{"mode": "invalid"}`
-	req := httptest.NewRequest("POST", "/api/v1/admin/executions/"+execID.String()+"/replay", bytes.NewBufferString(body))
+	req := adminRequest("POST", "/api/v1/admin/executions/"+execID.String()+"/replay", bytes.NewBufferString(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	mux.ServeHTTP(w, req)
@@ -373,7 +405,7 @@ func TestReplayHandler_ReplayDefaultMode(t *testing.T) {
 	h.RegisterRoutes(mux)
 
 	// Empty body - should default to exact
-	req := httptest.NewRequest("POST", "/api/v1/admin/executions/"+execID.String()+"/replay", bytes.NewBufferString("{}"))
+	req := adminRequest("POST", "/api/v1/admin/executions/"+execID.String()+"/replay", bytes.NewBufferString("{}"))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	mux.ServeHTTP(w, req)
@@ -400,7 +432,7 @@ func TestReplayHandler_GetReplayInfo(t *testing.T) {
 
 	// First create a replay
 	body := `{"mode": "exact"}`
-	req := httptest.NewRequest("POST", "/api/v1/admin/executions/"+execID.String()+"/replay", bytes.NewBufferString(body))
+	req := adminRequest("POST", "/api/v1/admin/executions/"+execID.String()+"/replay", bytes.NewBufferString(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	mux.ServeHTTP(w, req)
@@ -409,7 +441,7 @@ func TestReplayHandler_GetReplayInfo(t *testing.T) {
 	_ = json.Unmarshal(w.Body.Bytes(), &result)
 
 	// Now get replay info for the new execution
-	req = httptest.NewRequest("GET", "/api/v1/admin/executions/"+result.NewExecutionID.String()+"/replay", nil)
+	req = adminRequest("GET", "/api/v1/admin/executions/"+result.NewExecutionID.String()+"/replay", nil)
 	w = httptest.NewRecorder()
 	mux.ServeHTTP(w, req)
 
@@ -434,7 +466,7 @@ func TestReplayHandler_GetReplayInfo_NotReplay(t *testing.T) {
 	mux := http.NewServeMux()
 	h.RegisterRoutes(mux)
 
-	req := httptest.NewRequest("GET", "/api/v1/admin/executions/"+execID.String()+"/replay", nil)
+	req := adminRequest("GET", "/api/v1/admin/executions/"+execID.String()+"/replay", nil)
 	w := httptest.NewRecorder()
 	mux.ServeHTTP(w, req)
 
@@ -470,7 +502,7 @@ func TestTimelineHandler_GetExecutionLogs(t *testing.T) {
 	mux := http.NewServeMux()
 	h.RegisterRoutes(mux)
 
-	req := httptest.NewRequest("GET", "/api/v1/admin/executions/"+execID+"/logs", nil)
+	req := adminRequest("GET", "/api/v1/admin/executions/"+execID+"/logs", nil)
 	w := httptest.NewRecorder()
 	mux.ServeHTTP(w, req)
 
@@ -504,7 +536,7 @@ func TestTimelineHandler_GetExecutionLogs_LevelFilter(t *testing.T) {
 	mux := http.NewServeMux()
 	h.RegisterRoutes(mux)
 
-	req := httptest.NewRequest("GET", "/api/v1/admin/executions/"+execID+"/logs?level=error", nil)
+	req := adminRequest("GET", "/api/v1/admin/executions/"+execID+"/logs?level=error", nil)
 	w := httptest.NewRecorder()
 	mux.ServeHTTP(w, req)
 
@@ -529,7 +561,7 @@ func TestTimelineHandler_GetExecutionLogs_NoQuerier(t *testing.T) {
 	mux := http.NewServeMux()
 	h.RegisterRoutes(mux)
 
-	req := httptest.NewRequest("GET", "/api/v1/admin/executions/"+execID+"/logs", nil)
+	req := adminRequest("GET", "/api/v1/admin/executions/"+execID+"/logs", nil)
 	w := httptest.NewRecorder()
 	mux.ServeHTTP(w, req)
 
@@ -547,7 +579,7 @@ func TestTimelineHandler_GetExecutionLogs_Empty(t *testing.T) {
 	mux := http.NewServeMux()
 	h.RegisterRoutes(mux)
 
-	req := httptest.NewRequest("GET", "/api/v1/admin/executions/"+execID+"/logs", nil)
+	req := adminRequest("GET", "/api/v1/admin/executions/"+execID+"/logs", nil)
 	w := httptest.NewRecorder()
 	mux.ServeHTTP(w, req)
 
@@ -594,7 +626,7 @@ func TestAPI_ExplicitTrace_EndToEnd(t *testing.T) {
 	mux := http.NewServeMux()
 	h.RegisterRoutes(mux)
 
-	req := httptest.NewRequest("GET", "/api/v1/admin/executions/"+execID+"/logs", nil)
+	req := adminRequest("GET", "/api/v1/admin/executions/"+execID+"/logs", nil)
 	w := httptest.NewRecorder()
 	mux.ServeHTTP(w, req)
 
@@ -630,3 +662,58 @@ func TestAPI_ExplicitTrace_EndToEnd(t *testing.T) {
 		t.Error("expected step.output_recorded in logs")
 	}
 }
+
+func TestTimelineHandler_StatsAndArchive(t *testing.T) {
+	hotOnly := NewInMemoryEventStore()
+	seedExecution(t, hotOnly, uuid.New(), "pipeline-a")
+	mux := http.NewServeMux()
+	NewTimelineHandler(hotOnly, slog.Default()).RegisterRoutes(mux)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, adminRequest("GET", "/api/v1/admin/executions/stats", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var stats EventStoreStats
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	require.Equal(t, TierVolume{Executions: 1, Events: 6}, stats.Hot)
+	require.Nil(t, stats.Cold)
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, adminRequest("POST", "/api/v1/admin/executions/archive", nil))
+	require.Equal(t, http.StatusNotImplemented, w.Code)
+
+	tiered, hot, _ := newTieredTestStore()
+	execID := uuid.New()
+	seedExecution(t, hot, execID, "pipeline-a")
+	mux = http.NewServeMux()
+	NewTimelineHandler(tiered, slog.Default()).RegisterRoutes(mux)
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, adminRequest("POST", "/api/v1/admin/executions/archive", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var run ArchiveRun
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &run))
+	require.EqualValues(t, 1, run.Archived)
+	require.True(t, run.Verified)
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, adminRequest("GET", "/api/v1/admin/executions/stats", nil))
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	require.Equal(t, TierVolume{}, stats.Hot)
+	require.NotNil(t, stats.Cold)
+	require.EqualValues(t, 1, stats.Cold.Executions)
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, adminRequest("GET", "/api/v1/admin/executions/"+execID.String()+"/events", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var events map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &events))
+	require.Equal(t, true, events["archived"])
+	require.EqualValues(t, 6, events["count"])
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, adminRequest("POST", "/api/v1/admin/executions/archive/verify", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var report ArchiveVerification
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	require.True(t, report.Verified)
+}
@@ -9,6 +9,10 @@ const STATUS_COLORS: Record<string, string> = {
   active: '#a6e3a1',
   stopped: '#f9e2af',
   error: '#f38ba8',
+  healthy: '#a6e3a1',
+  degraded: '#f9e2af',
+  unhealthy: '#f38ba8',
+  unknown: '#6c7086',
 };
 
 const EXEC_STATUS_COLORS: Record<string, string> = {
@@ -313,6 +317,16 @@ export default function SystemDashboard() {
                 </div>
                 <div style={{ display: 'flex', alignItems: 'center', gap: 8 }}>
                   <StatusBadge status={inst.status} />
+                  {inst.status === 'running' && inst.health && (
+                    <span
+                      title={
+                        inst.health.message ??
+                        `${inst.health.consecutive_failures} consecutive failed probe(s)`
+                      }
+                    >
+                      <StatusBadge status={inst.health.status} />
+                    </span>
+                  )}
                   {inst.status === 'running' && (
                     <button
                       onClick={() =>
@@ -10,6 +10,7 @@ const STATUS_COLORS: Record<string, string> = {
   failed: '#f38ba8',
   cancelled: '#f9e2af',
   skipped: '#6c7086',
+  dry_run: '#fab387',
 };
 
 function StatusBadge({ status }: { status: string }) {
@@ -172,6 +173,8 @@ function StepTimeline({ steps }: { steps: ExecutionStep[] }) {
   );
 }
 
+type TraceRequestOptions = { method: string; path: string; headers: Record<string, string>; body: string };
+
 function TraceRequestModal({
   open,
   onClose,
@@ -181,7 +184,7 @@ function TraceRequestModal({
 }: {
   open: boolean;
   onClose: () => void;
-  onSend: (opts: { method: string; path: string; headers: Record<string, string>; body: string }) => void;
+  onSend: (opts: TraceRequestOptions) => void;
   sending: boolean;
   error: string | null;
 }) {
@@ -190,6 +193,7 @@ function TraceRequestModal({
   const [headersText, setHeadersText] = useState('{}');
   const [body, setBody] = useState('');
   const [headersError, setHeadersError] = useState('');
+  const [dryRun, setDryRun] = useState(false);
 
   if (!open) return null;
 
@@ -208,6 +212,7 @@ function TraceRequestModal({
         }
       }
       headers = parsed as Record<string, string>;
+      if (dryRun) headers = { ...headers, 'X-Workflow-Dry-Run': 'true' };
       setHeadersError('');
     } catch {
       setHeadersError('Headers must be valid JSON');
@@ -344,6 +349,12 @@ function TraceRequestModal({
           />
         </div>
 
+        {/* Dry run */}
+        <label style={{ display: 'flex', alignItems: 'center', gap: 6, color: '#a6adc8', fontSize: 12, marginBottom: 16 }}>
+          <input type="checkbox" checked={dryRun} onChange={(e) => setDryRun(e.target.checked)} />
+          Dry run: preview steps marked destructive instead of executing them
+        </label>
+
         {error && (
           <div
             style={{
@@ -423,6 +434,8 @@ export default function WorkflowDashboard() {
   const [traceModalOpen, setTraceModalOpen] = useState(false);
   const [traceSending, setTraceSending] = useState(false);
   const [traceError, setTraceError] = useState<string | null>(null);
+  // The last dry-run request, so it can be repeated for real.
+  const [lastDryRun, setLastDryRun] = useState<{ opts: TraceRequestOptions; executionId: string } | null>(null);
 
   const handleDeploy = useCallback(async () => {
     if (!selectedWorkflowId) return;
@@ -491,13 +504,14 @@ export default function WorkflowDashboard() {
   }, [setActiveView, setSelectedWorkflowId]);
 
   const handleSendTrace = useCallback(
-    async (opts: { method: string; path: string; headers: Record<string, string>; body: string }) => {
+    async (opts: TraceRequestOptions) => {
       if (!selectedWorkflowId) return;
       setTraceSending(true);
       setTraceError(null);
       try {
         const exec = await apiTriggerTracedExecution(selectedWorkflowId, opts);
         setTraceModalOpen(false);
+        setLastDryRun(opts.headers['X-Workflow-Dry-Run'] === 'true' ? { opts, executionId: exec.id } : null);
         setSelectedTraceExecutionId(exec.id);
       } catch (err) {
         setTraceError(err instanceof Error ? err.message : 'Request failed');
@@ -508,6 +522,17 @@ export default function WorkflowDashboard() {
     [selectedWorkflowId, setSelectedTraceExecutionId],
   );
 
+  // Repeat the last dry run for real, referencing it so the two executions
+  // can be compared.
+  const handleExecuteForReal = useCallback(() => {
+    if (!lastDryRun) return;
+    const { opts, executionId } = lastDryRun;
+    if (!window.confirm(`Execute ${opts.method} ${opts.path} for real? Steps marked destructive will run.`)) return;
+    const headers = { ...opts.headers, 'X-Workflow-Dry-Run-Of': executionId };
+    delete headers['X-Workflow-Dry-Run'];
+    handleSendTrace({ ...opts, headers });
+  }, [lastDryRun, handleSendTrace]);
+
   const wfName = workflowDashboard?.workflow?.name ?? 'Workflow';
   const wfStatus = workflowDashboard?.workflow?.status ?? '';
 
@@ -621,6 +646,28 @@ export default function WorkflowDashboard() {
             Trace Request
           </button>
         )}
+        {selectedWorkflowId && lastDryRun && (
+          <button
+            onClick={handleExecuteForReal}
+            disabled={traceSending}
+            title={`Repeat dry run ${lastDryRun.executionId} without dry-run mode`}
+            style={{
+              background: 'rgba(250, 179, 135, 0.2)',
+              border: '1px solid rgba(250, 179, 135, 0.4)',
+              borderRadius: 6,
+              color: '#fab387',
+              padding: '6px 16px',
+              fontSize: 13,
+              fontWeight: 600,
+              cursor: traceSending ? 'default' : 'pointer',
+            }}
+          >
+            Execute for Real
+          </button>
+        )}
+        {!traceModalOpen && traceError && (
+          <span style={{ color: '#f38ba8', fontSize: 12, alignSelf: 'center' }}>{traceError}</span>
+        )}
       </div>
 
       {/* Stats row */}
@@ -9,6 +9,7 @@ const STATUS_COLORS: Record<string, string> = {
   failed: '#f38ba8',
   cancelled: '#f9e2af',
   skipped: '#6c7086',
+  dry_run: '#fab387',
 };
 
 function StatusBadge({ status }: { status: string }) {
@@ -189,9 +190,10 @@ export default function ExecutionDetail() {
     setActiveView('executions');
   }, [selectedExecution, setSelectedTraceExecutionId, setActiveView]);
 
-  const hasExplicitTrace =
-    selectedExecution != null &&
-    (selectedExecution.metadata as Record<string, unknown> | null | undefined)?.explicit_trace === true;
+  const metadata = selectedExecution?.metadata as Record<string, unknown> | null | undefined;
+  const hasExplicitTrace = metadata?.explicit_trace === true;
+  const isDryRun = metadata?.dry_run === true;
+  const dryRunOf = typeof metadata?.dry_run_of === 'string' ? metadata.dry_run_of : '';
   const [execIdInput, setExecIdInput] = useState('');
 
   const handleLoad = () => {
@@ -315,7 +317,34 @@ export default function ExecutionDetail() {
             <div>
               <div style={{ color: '#a6adc8', fontSize: 11 }}>Status</div>
               <StatusBadge status={selectedExecution.status} />
+              {isDryRun && (
+                <span style={{ marginLeft: 6 }}>
+                  <StatusBadge status="dry_run" />
+                </span>
+              )}
             </div>
+            {dryRunOf && (
+              <div>
+                <div style={{ color: '#a6adc8', fontSize: 11 }}>Confirmed From Dry Run</div>
+                <button
+                  onClick={() => {
+                    fetchExecutionDetail(dryRunOf);
+                    fetchExecutionSteps(dryRunOf);
+                  }}
+                  style={{
+                    background: 'none',
+                    border: 'none',
+                    padding: 0,
+                    color: '#89b4fa',
+                    fontSize: 13,
+                    fontFamily: 'monospace',
+                    cursor: 'pointer',
+                  }}
+                >
+                  {dryRunOf}
+                </button>
+              </div>
+            )}
             <div>
               <div style={{ color: '#a6adc8', fontSize: 11 }}>Duration</div>
               <div style={{ color: '#cdd6f4', fontSize: 13 }}>
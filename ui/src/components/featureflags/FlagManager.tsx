@@ -544,7 +544,9 @@ export default function FlagManager() {
   const loading = useFeatureFlagStore((s) => s.loading);
   const error = useFeatureFlagStore((s) => s.error);
   const sseConnected = useFeatureFlagStore((s) => s.sseConnected);
+  const engines = useFeatureFlagStore((s) => s.engines);
   const fetchFlags = useFeatureFlagStore((s) => s.fetchFlags);
+  const fetchEngines = useFeatureFlagStore((s) => s.fetchEngines);
   const createFlag = useFeatureFlagStore((s) => s.createFlag);
   const updateFlag = useFeatureFlagStore((s) => s.updateFlag);
   const deleteFlag = useFeatureFlagStore((s) => s.deleteFlag);
@@ -562,7 +564,12 @@ export default function FlagManager() {
   useEffect(() => {
     fetchFlags();
     connectSSE();
-    return () => disconnectSSE();
+    fetchEngines();
+    const enginePoll = setInterval(fetchEngines, 10000);
+    return () => {
+      clearInterval(enginePoll);
+      disconnectSSE();
+    };
   }, []); // eslint-disable-line react-hooks/exhaustive-deps
 
   const handleCreate = useCallback(async (data: FlagFormData) => {
@@ -667,6 +674,12 @@ export default function FlagManager() {
                 (live)
               </span>
             )}
+            <span
+              title={engines.map((e) => e.engine).join(', ')}
+              style={{ marginLeft: 8, fontSize: 11, color: engines.length > 0 ? '#a6e3a1' : '#6c7086' }}
+            >
+              {engines.length} {engines.length === 1 ? 'engine' : 'engines'} subscribed
+            </span>
           </p>
         </div>
         <button onClick={() => setShowCreateForm(true)} style={primaryBtnStyle}>
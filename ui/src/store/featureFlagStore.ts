@@ -58,6 +58,12 @@ export interface EvalContext {
 
 export type FlagValue = unknown;
 
+export interface EngineSubscription {
+  engine: string;
+  remote_addr: string;
+  connected_at: string;
+}
+
 // ---------------------------------------------------------------------------
 // Store
 // ---------------------------------------------------------------------------
@@ -67,8 +73,10 @@ interface FeatureFlagState {
   loading: boolean;
   error: string | null;
   sseConnected: boolean;
+  engines: EngineSubscription[];
 
   fetchFlags: () => Promise<void>;
+  fetchEngines: () => Promise<void>;
   createFlag: (flag: CreateFlagRequest) => Promise<void>;
   updateFlag: (key: string, updates: Partial<FlagDefinition>) => Promise<void>;
   deleteFlag: (key: string) => Promise<void>;
@@ -96,6 +104,7 @@ const useFeatureFlagStore = create<FeatureFlagState>((set, get) => ({
   loading: false,
   error: null,
   sseConnected: false,
+  engines: [],
 
   fetchFlags: async () => {
     if (get().loading) return;
@@ -114,6 +123,17 @@ const useFeatureFlagStore = create<FeatureFlagState>((set, get) => ({
     }
   },
 
+  fetchEngines: async () => {
+    try {
+      const res = await fetch(`${API_BASE}/engines`, { headers: authHeaders() });
+      if (!res.ok) return;
+      const data = await res.json();
+      set({ engines: Array.isArray(data.engines) ? data.engines : [] });
+    } catch {
+      // engine count is informational; keep the last known value
+    }
+  },
+
   createFlag: async (flag: CreateFlagRequest) => {
     set({ error: null });
     try {
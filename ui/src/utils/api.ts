@@ -389,6 +389,10 @@ export async function apiTriggerTracedExecution(
     'X-Workflow-Trace': 'true',
   };
   if (token) reqHeaders['Authorization'] = `Bearer ${token}`;
+  // The server honors the dry-run headers only on the trigger request itself.
+  for (const h of ['X-Workflow-Dry-Run', 'X-Workflow-Dry-Run-Of']) {
+    if (opts.headers[h]) reqHeaders[h] = opts.headers[h];
+  }
   const res = await fetch(`${baseUrl}/admin/workflows/${encodeURIComponent(workflowId)}/trigger`, {
     method: 'POST',
     headers: reqHeaders,
@@ -505,12 +509,35 @@ export interface RuntimeInstanceResponse {
   status: string;
   started_at: string;
   error?: string;
+  health?: InstanceHealthReport;
+}
+
+export interface InstanceHealthReport {
+  instance: string;
+  name?: string;
+  status: 'healthy' | 'degraded' | 'unhealthy' | 'unknown';
+  message?: string;
+  last_probe_at?: string;
+  consecutive_failures: number;
+  modules?: Record<string, string>;
+  interval_ms?: number;
+  reported_at: string;
 }
 
 export function apiFetchRuntimeInstances(): Promise<{ instances: RuntimeInstanceResponse[]; total: number }> {
   return apiGet<{ instances: RuntimeInstanceResponse[]; total: number }>('/admin/runtime/instances');
 }
 
+export function apiFetchRuntimeInstanceHealth(
+  instanceId: string,
+  limit?: number,
+): Promise<{ latest: InstanceHealthReport; history: InstanceHealthReport[] }> {
+  const query = limit ? `?limit=${limit}` : '';
+  return apiGet<{ latest: InstanceHealthReport; history: InstanceHealthReport[] }>(
+    `/admin/runtime/instances/${encodeURIComponent(instanceId)}/health${query}`,
+  );
+}
+
 export function apiStopRuntimeInstance(instanceId: string): Promise<{ status: string }> {
   return apiPost<{ status: string }>(`/admin/runtime/instances/${encodeURIComponent(instanceId)}/stop`);
 }
@@ -0,0 +1,102 @@
+package validation
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// metricDecl is one step.metric declaration found in a pipeline.
+type metricDecl struct {
+	where  string // pipeline/step path, for messages
+	kind   string
+	labels []string
+}
+
+// ValidateMetricSteps checks that every step.metric declaring the same metric
+// on the same collector agrees on its type and label names. Prometheus refuses
+// a second registration with a different shape, so a mismatch would otherwise
+// only surface as a failing step the first time both pipelines run.
+//
+// The pipelines parameter has the same shape as for ValidatePipelineTemplateRefs.
+// One error is returned per conflicting declaration, in a stable order.
+func ValidateMetricSteps(pipelines map[string]any) []string {
+	v := metricStepValidator{first: make(map[string]metricDecl)}
+	for _, pipelineName := range slices.Sorted(maps.Keys(pipelines)) {
+		pipelineMap, ok := pipelines[pipelineName].(map[string]any)
+		if !ok {
+			continue
+		}
+		v.walk(pipelineName, pipelineMap["steps"])
+	}
+	return v.errs
+}
+
+// metricStepValidator collects the first declaration of each metric and the
+// conflicts found against it.
+type metricStepValidator struct {
+	first map[string]metricDecl // collector/metric -> first declaration
+	errs  []string
+}
+
+// walk visits every step in raw, including the steps nested in the config of
+// composite steps such as step.foreach, step.parallel, step.branch and
+// step.switch. Any map with a "step." type inside a step's config is treated
+// as a nested step; maps are visited in key order so errors are stable.
+func (v *metricStepValidator) walk(where string, raw any) {
+	switch node := raw.(type) {
+	case []any:
+		for _, item := range node {
+			v.walk(where, item)
+		}
+	case map[string]any:
+		if kind, _ := node["type"].(string); strings.HasPrefix(kind, "step.") {
+			stepName, _ := node["name"].(string)
+			where += "/" + stepName
+			cfg, _ := node["config"].(map[string]any)
+			if kind == "step.metric" {
+				v.check(where, cfg)
+				return
+			}
+			raw = cfg
+		}
+		m, _ := raw.(map[string]any)
+		for _, key := range slices.Sorted(maps.Keys(m)) {
+			v.walk(where, m[key])
+		}
+	}
+}
+
+// check records a step.metric declaration, reporting a conflict with an
+// earlier declaration of the same metric.
+func (v *metricStepValidator) check(where string, cfg map[string]any) {
+	metric, _ := cfg["metric"].(string)
+	if metric == "" {
+		return
+	}
+	decl := metricDecl{where: where, kind: "counter"}
+	if kind, _ := cfg["type"].(string); kind != "" {
+		decl.kind = kind
+	}
+	if labels, ok := cfg["labels"].(map[string]any); ok {
+		decl.labels = slices.Sorted(maps.Keys(labels))
+	}
+	collector, _ := cfg["collector"].(string)
+	if collector == "" {
+		collector = "metrics.collector"
+	}
+
+	key := collector + "/" + metric
+	prev, seen := v.first[key]
+	if !seen {
+		v.first[key] = decl
+		return
+	}
+	if prev.kind != decl.kind || !slices.Equal(prev.labels, decl.labels) {
+		v.errs = append(v.errs, fmt.Sprintf(
+			"step %s declares metric %q as %s with labels [%s], but step %s declares it as %s with labels [%s]",
+			decl.where, metric, decl.kind, strings.Join(decl.labels, ", "),
+			prev.where, prev.kind, strings.Join(prev.labels, ", ")))
+	}
+}
@@ -0,0 +1,81 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+)
+
+func metricStep(name string, cfg map[string]any) map[string]any {
+	return map[string]any{"name": name, "type": "step.metric", "config": cfg}
+}
+
+func TestValidateMetricSteps_Consistent(t *testing.T) {
+	pipelines := map[string]any{
+		"create-order": map[string]any{"steps": []any{
+			metricStep("count", map[string]any{"metric": "orders_created_total", "labels": map[string]any{"country": "US"}}),
+		}},
+		"import-orders": map[string]any{"steps": []any{
+			metricStep("count", map[string]any{"metric": "orders_created_total", "type": "counter", "labels": map[string]any{"country": "DE"}}),
+			// Same name on another collector is a separate metric.
+			metricStep("gauge", map[string]any{"metric": "orders_created_total", "type": "gauge", "collector": "billing.metrics"}),
+		}},
+	}
+	if errs := ValidateMetricSteps(pipelines); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+}
+
+func TestValidateMetricSteps_MismatchedLabels(t *testing.T) {
+	pipelines := map[string]any{
+		"a": map[string]any{"steps": []any{
+			metricStep("count", map[string]any{"metric": "orders_created_total", "labels": map[string]any{"country": "US"}}),
+		}},
+		"b": map[string]any{"steps": []any{
+			metricStep("count", map[string]any{"metric": "orders_created_total", "labels": map[string]any{"region": "eu"}}),
+			metricStep("size", map[string]any{"metric": "orders_created_total", "type": "histogram", "labels": map[string]any{"country": "US"}}),
+		}},
+	}
+	errs := ValidateMetricSteps(pipelines)
+	if len(errs) != 2 {
+		t.Fatalf("got %d errors, want 2: %v", len(errs), errs)
+	}
+	if !strings.Contains(errs[0], "step b/count") || !strings.Contains(errs[0], "[region]") || !strings.Contains(errs[0], "step a/count") {
+		t.Errorf("unexpected label error: %s", errs[0])
+	}
+	if !strings.Contains(errs[1], "as histogram") {
+		t.Errorf("unexpected type error: %s", errs[1])
+	}
+}
+
+func TestValidateMetricSteps_NestedSteps(t *testing.T) {
+	pipelines := map[string]any{
+		"a": map[string]any{"steps": []any{
+			metricStep("count", map[string]any{"metric": "orders_created_total", "labels": map[string]any{"country": "US"}}),
+		}},
+		"b": map[string]any{"steps": []any{
+			map[string]any{"name": "each", "type": "step.foreach", "config": map[string]any{
+				"step": metricStep("count", map[string]any{"metric": "orders_created_total", "labels": map[string]any{"region": "eu"}}),
+			}},
+			map[string]any{"name": "route", "type": "step.switch", "config": map[string]any{
+				"cases": []any{map[string]any{"value": "x", "steps": []any{
+					metricStep("size", map[string]any{"metric": "orders_created_total", "type": "gauge", "labels": map[string]any{"country": "US"}}),
+				}}},
+			}},
+			map[string]any{"name": "fan", "type": "step.parallel", "config": map[string]any{
+				"branches": map[string]any{"left": map[string]any{"steps": []any{
+					metricStep("count", map[string]any{"metric": "orders_created_total", "labels": map[string]any{"country": "DE"}}),
+				}}},
+			}},
+		}},
+	}
+	errs := ValidateMetricSteps(pipelines)
+	if len(errs) != 2 {
+		t.Fatalf("got %d errors, want 2: %v", len(errs), errs)
+	}
+	if !strings.Contains(errs[0], "step b/each/count") || !strings.Contains(errs[0], "[region]") {
+		t.Errorf("unexpected foreach error: %s", errs[0])
+	}
+	if !strings.Contains(errs[1], "step b/route/size") || !strings.Contains(errs[1], "as gauge") {
+		t.Errorf("unexpected switch error: %s", errs[1])
+	}
+}
@@ -6,6 +6,7 @@ package validation
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/GoCodeAlone/workflow/schema"
@@ -22,14 +23,33 @@ const (
 	RefWarningSelfReference       RefWarningCode = "self_reference"
 	RefWarningForwardReference    RefWarningCode = "forward_reference"
 	RefWarningSQLColumnMismatch   RefWarningCode = "sql_column_mismatch"
+	// RefWarningUnverifiedOutput marks a reference to a field that is not
+	// among the declared outputs of a step whose other outputs are dynamic,
+	// so it may still exist at runtime.
+	RefWarningUnverifiedOutput RefWarningCode = "unverified_output"
 )
 
+// RefFinding is the structured form of one warning: where the reference is,
+// what it references, and the closest known path when there is one.
+type RefFinding struct {
+	Code     RefWarningCode
+	Pipeline string
+	Step     string
+	// Ref is the referenced context path, e.g. "steps.fetch.rows".
+	Ref string
+	// Suggestion is the closest known path to Ref, e.g. "steps.fetch.row".
+	Suggestion string
+	Message    string
+}
+
 // RefValidationResult holds the outcome of pipeline template reference validation.
 // Warnings are suspicious but non-fatal references; WarningCodes classifies each
-// warning at the same index. Errors are definitively wrong.
+// warning at the same index and Findings locates it. Errors are definitively
+// wrong.
 type RefValidationResult struct {
 	Warnings     []string
 	WarningCodes []RefWarningCode
+	Findings     []RefFinding
 	Errors       []string
 }
 
@@ -40,8 +60,22 @@ func (r *RefValidationResult) HasIssues() bool {
 
 // AddWarning records a warning and its stable machine-readable code.
 func (r *RefValidationResult) AddWarning(code RefWarningCode, message string) {
-	r.Warnings = append(r.Warnings, message)
-	r.WarningCodes = append(r.WarningCodes, code)
+	r.addFinding(RefFinding{Code: code, Message: message})
+}
+
+// addFinding records a located warning. A reference reported more than once
+// for the same step, e.g. by both the dot-path and field checks, is kept once.
+func (r *RefValidationResult) addFinding(f RefFinding) {
+	if f.Ref != "" {
+		for _, existing := range r.Findings {
+			if existing.Code == f.Code && existing.Pipeline == f.Pipeline && existing.Step == f.Step && existing.Ref == f.Ref {
+				return
+			}
+		}
+	}
+	r.Warnings = append(r.Warnings, f.Message)
+	r.WarningCodes = append(r.WarningCodes, f.Code)
+	r.Findings = append(r.Findings, f)
 }
 
 // BlockingWarningMessages returns warning messages that represent deterministic
@@ -310,28 +344,41 @@ func validateStepOutputField(pipelineName, currentStep, refStepName, refField st
 		return // no declared outputs for this step type — nothing to check
 	}
 
-	// If any output key is a placeholder (wrapped in parentheses), the step
-	// has dynamic/config-dependent outputs and we cannot validate statically.
-	for _, o := range outputs {
-		if len(o.Key) > 1 && o.Key[0] == '(' && o.Key[len(o.Key)-1] == ')' {
-			return
-		}
-	}
-
 	for _, o := range outputs {
 		if o.Key == refField {
 			return // field found — all good
 		}
 	}
 
-	// Build a suggestion list from declared outputs
-	keys := make([]string, 0, len(outputs))
-	for _, o := range outputs {
-		keys = append(keys, o.Key)
+	ref := "steps." + refStepName + "." + refField
+	suggestion := suggestOutputPath(refStepName, refField, outputs)
+	// A step with placeholder outputs (wrapped in parentheses) also emits
+	// config-dependent fields, so an unlisted field may still exist.
+	if hasDynamicOutputs(outputs) {
+		reportUnverifiedOutput(pipelineName, currentStep, refStepName, refField, meta.typ, ref, suggestion, outputs, result)
+		return
 	}
-	result.AddWarning(RefWarningUnknownOutput,
-		fmt.Sprintf("pipeline %q step %q: references %s.%s but step %q (%s) declares outputs: %s",
-			pipelineName, currentStep, refStepName, refField, refStepName, meta.typ, strings.Join(keys, ", ")))
+
+	result.addFinding(RefFinding{
+		Code: RefWarningUnknownOutput, Pipeline: pipelineName, Step: currentStep, Ref: ref, Suggestion: suggestion,
+		Message: fmt.Sprintf("pipeline %q step %q: references %s.%s but step %q (%s) declares outputs: %s%s",
+			pipelineName, currentStep, refStepName, refField, refStepName, meta.typ, joinOutputKeys(outputs), didYouMean(suggestion)),
+	})
+}
+
+// reportUnverifiedOutput warns about a reference to a field that a step with
+// dynamic outputs does not declare. Steps whose outputs are all dynamic have
+// nothing to compare against and are not reported.
+func reportUnverifiedOutput(pipelineName, currentStep, refStepName, refField, stepType, ref, suggestion string, outputs []schema.InferredOutput, result *RefValidationResult) {
+	known := joinOutputKeys(outputs)
+	if known == "" {
+		return
+	}
+	result.addFinding(RefFinding{
+		Code: RefWarningUnverifiedOutput, Pipeline: pipelineName, Step: currentStep, Ref: ref, Suggestion: suggestion,
+		Message: fmt.Sprintf("pipeline %q step %q: references step %q output field %q, which step type %q does not declare (declared: %s; its other outputs are dynamic)%s",
+			pipelineName, currentStep, refStepName, refField, stepType, known, didYouMean(suggestion)),
+	})
 }
 
 // validateStepRef checks that a referenced step name exists and appears before the
@@ -339,19 +386,38 @@ func validateStepOutputField(pipelineName, currentStep, refStepName, refField st
 // also validates the first output field name against the step's known outputs, and
 // for db_query steps it performs best-effort SQL alias checking for "row.<col>" paths.
 func validateStepRef(pipelineName, currentStep, refName, fieldPath string, currentIdx int, stepNames map[string]int, stepInfos map[string]stepBuildInfo, reg *schema.StepSchemaRegistry, result *RefValidationResult) {
+	ref := "steps." + refName + fieldPath
 	refIdx, exists := stepNames[refName]
 	switch {
 	case !exists:
-		result.AddWarning(RefWarningMissingStep,
-			fmt.Sprintf("pipeline %q step %q: references step %q which does not exist in this pipeline", pipelineName, currentStep, refName))
+		// Suggest the closest step that runs before the current one.
+		earlier := make([]string, 0, len(stepNames))
+		for name, idx := range stepNames {
+			if idx < currentIdx {
+				earlier = append(earlier, name)
+			}
+		}
+		sort.Strings(earlier)
+		suggestion := ""
+		if best := closestMatch(refName, earlier); best != "" {
+			suggestion = "steps." + best + fieldPath
+		}
+		result.addFinding(RefFinding{
+			Code: RefWarningMissingStep, Pipeline: pipelineName, Step: currentStep, Ref: ref, Suggestion: suggestion,
+			Message: fmt.Sprintf("pipeline %q step %q: references step %q which does not exist in this pipeline%s", pipelineName, currentStep, refName, didYouMean(suggestion)),
+		})
 		return
 	case refIdx == currentIdx:
-		result.AddWarning(RefWarningSelfReference,
-			fmt.Sprintf("pipeline %q step %q: references itself; a step cannot use its own outputs because they are not available until after execution", pipelineName, currentStep))
+		result.addFinding(RefFinding{
+			Code: RefWarningSelfReference, Pipeline: pipelineName, Step: currentStep, Ref: ref,
+			Message: fmt.Sprintf("pipeline %q step %q: references itself; a step cannot use its own outputs because they are not available until after execution", pipelineName, currentStep),
+		})
 		return
 	case refIdx > currentIdx:
-		result.AddWarning(RefWarningForwardReference,
-			fmt.Sprintf("pipeline %q step %q: references step %q which has not executed yet (appears later in pipeline)", pipelineName, currentStep, refName))
+		result.addFinding(RefFinding{
+			Code: RefWarningForwardReference, Pipeline: pipelineName, Step: currentStep, Ref: ref,
+			Message: fmt.Sprintf("pipeline %q step %q: references step %q which has not executed yet (appears later in pipeline)", pipelineName, currentStep, refName),
+		})
 		return
 	}
 
@@ -370,13 +436,6 @@ func validateStepRef(pipelineName, currentStep, refName, fieldPath string, curre
 		return // no schema information available; skip
 	}
 
-	// If any output key is a placeholder (e.g. "(key)", "(dynamic)", "(nested)"),
-	// the step emits dynamic fields whose names cannot be statically determined.
-	// Skip field-path validation for such steps to avoid false positives.
-	if hasDynamicOutputs(outputs) {
-		return
-	}
-
 	// Split ".row.auth_token" → ["row", "auth_token"]
 	parts := strings.Split(strings.TrimPrefix(fieldPath, "."), ".")
 	if len(parts) == 0 || parts[0] == "" {
@@ -393,9 +452,20 @@ func validateStepRef(pipelineName, currentStep, refName, fieldPath string, curre
 		}
 	}
 	if matchedOutput == nil {
-		result.AddWarning(RefWarningUnknownOutput,
-			fmt.Sprintf("pipeline %q step %q: references step %q output field %q which is not a known output of step type %q (known outputs: %s)",
-				pipelineName, currentStep, refName, firstField, info.stepType, joinOutputKeys(outputs)))
+		firstRef := "steps." + refName + "." + firstField
+		suggestion := suggestOutputPath(refName, firstField, outputs)
+		// If any output key is a placeholder (e.g. "(key)", "(dynamic)"), the
+		// step emits fields whose names cannot be statically determined, so
+		// an unlisted field is only a warning.
+		if hasDynamicOutputs(outputs) {
+			reportUnverifiedOutput(pipelineName, currentStep, refName, firstField, info.stepType, firstRef, suggestion, outputs, result)
+			return
+		}
+		result.addFinding(RefFinding{
+			Code: RefWarningUnknownOutput, Pipeline: pipelineName, Step: currentStep, Ref: firstRef, Suggestion: suggestion,
+			Message: fmt.Sprintf("pipeline %q step %q: references step %q output field %q which is not a known output of step type %q (known outputs: %s)%s",
+				pipelineName, currentStep, refName, firstField, info.stepType, joinOutputKeys(outputs), didYouMean(suggestion)),
+		})
 		return
 	}
 
@@ -414,9 +484,15 @@ func validateStepRef(pipelineName, currentStep, refName, fieldPath string, curre
 					}
 				}
 				if !found {
-					result.AddWarning(RefWarningSQLColumnMismatch,
-						fmt.Sprintf("pipeline %q step %q: references step %q output field \"row.%s\" but the SQL query does not select column %q (available: %s)",
-							pipelineName, currentStep, refName, columnName, columnName, strings.Join(sqlCols, ", ")))
+					suggestion := ""
+					if best := closestMatch(columnName, sqlCols); best != "" {
+						suggestion = "steps." + refName + ".row." + best
+					}
+					result.addFinding(RefFinding{
+						Code: RefWarningSQLColumnMismatch, Pipeline: pipelineName, Step: currentStep, Ref: "steps." + refName + ".row." + columnName, Suggestion: suggestion,
+						Message: fmt.Sprintf("pipeline %q step %q: references step %q output field \"row.%s\" but the SQL query does not select column %q (available: %s)%s",
+							pipelineName, currentStep, refName, columnName, columnName, strings.Join(sqlCols, ", "), didYouMean(suggestion)),
+					})
 				}
 			}
 		}
@@ -425,11 +501,17 @@ func validateStepRef(pipelineName, currentStep, refName, fieldPath string, curre
 
 // validatePlainStepRefs checks plain-string config values that contain bare step
 // context-key references (e.g. "steps.STEP_NAME.field") in config fields known to
-// accept such paths: secret_from, backend_url_key, and field (conditional/branch).
+// accept such paths: every *_from key (body_from, input_from, secret_from, ...),
+// backend_url_key, and field (conditional/branch).
 func validatePlainStepRefs(pipelineName, stepName string, stepIdx int, stepCfg map[string]any, stepNames map[string]int, stepInfos map[string]stepBuildInfo, reg *schema.StepSchemaRegistry, result *RefValidationResult) {
-	// Config keys that are documented to accept a bare "steps.X.y" context path.
-	plainRefKeys := []string{"secret_from", "backend_url_key", "field"}
-	for _, key := range plainRefKeys {
+	keys := make([]string, 0, len(stepCfg))
+	for key := range stepCfg {
+		if strings.HasSuffix(key, "_from") || key == "backend_url_key" || key == "field" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
 		val, ok := stepCfg[key].(string)
 		if !ok || val == "" {
 			continue
@@ -490,6 +572,29 @@ func isPlaceholderOutputKey(key string) bool {
 	return len(key) >= 2 && key[0] == '(' && key[len(key)-1] == ')'
 }
 
+// suggestOutputPath returns the path of the declared output of step closest
+// to field, or "" when none is close.
+func suggestOutputPath(step, field string, outputs []schema.InferredOutput) string {
+	keys := make([]string, 0, len(outputs))
+	for _, o := range outputs {
+		if !isPlaceholderOutputKey(o.Key) {
+			keys = append(keys, o.Key)
+		}
+	}
+	if best := closestMatch(field, keys); best != "" {
+		return "steps." + step + "." + best
+	}
+	return ""
+}
+
+// didYouMean formats a suggested path for a warning message.
+func didYouMean(suggestion string) string {
+	if suggestion == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean %q?)", suggestion)
+}
+
 // hasDynamicOutputs reports whether any output in the list is a wildcard
 // placeholder, meaning the step emits fields that are not statically known.
 func hasDynamicOutputs(outputs []schema.InferredOutput) bool {
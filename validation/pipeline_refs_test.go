@@ -480,3 +480,92 @@ func TestRefValidationResult_HasIssues(t *testing.T) {
 		t.Error("expected HasIssues()=true after adding error")
 	}
 }
+
+// TestValidatePipelineTemplateRefs_FromPathSuggestion checks that *_from
+// config paths are validated and that findings carry the closest known path.
+func TestValidatePipelineTemplateRefs_FromPathSuggestion(t *testing.T) {
+	pipelines := map[string]any{
+		"api": map[string]any{
+			"steps": []any{
+				map[string]any{
+					"name":   "get-company",
+					"type":   "step.db_query",
+					"config": map[string]any{"mode": "list", "query": "SELECT id FROM companies"},
+				},
+				map[string]any{
+					"name":   "respond",
+					"type":   "step.json_response",
+					"config": map[string]any{"body_from": "steps.get-company.row"},
+				},
+				map[string]any{
+					"name":   "respond-typo",
+					"type":   "step.json_response",
+					"config": map[string]any{"body_from": "steps.get-compny.rows"},
+				},
+			},
+		},
+	}
+	result := validation.ValidatePipelineTemplateRefs(pipelines)
+	if len(result.Findings) != 2 || len(result.Findings) != len(result.Warnings) {
+		t.Fatalf("expected 2 findings aligned with warnings, got %+v", result.Findings)
+	}
+	output, step := result.Findings[0], result.Findings[1]
+	if output.Code != validation.RefWarningUnknownOutput || output.Step != "respond" || output.Ref != "steps.get-company.row" || output.Suggestion != "steps.get-company.rows" {
+		t.Errorf("unexpected output finding: %+v", output)
+	}
+	if step.Code != validation.RefWarningMissingStep || step.Pipeline != "api" || step.Suggestion != "steps.get-company.rows" {
+		t.Errorf("unexpected step finding: %+v", step)
+	}
+	if !strings.Contains(step.Message, `did you mean "steps.get-company.rows"`) {
+		t.Errorf("expected the suggestion in the message, got %q", step.Message)
+	}
+}
+
+// TestValidatePipelineTemplateRefs_ReportsReferenceOnce checks that a
+// reference matched by several template patterns is reported once.
+func TestValidatePipelineTemplateRefs_ReportsReferenceOnce(t *testing.T) {
+	pipelines := map[string]any{
+		"api": map[string]any{
+			"steps": []any{
+				map[string]any{"name": "query", "type": "step.db_query", "config": map[string]any{"mode": "single"}},
+				map[string]any{
+					"name":   "respond",
+					"type":   "step.set",
+					"config": map[string]any{"values": map[string]any{"x": "{{ .steps.query.rows }}"}},
+				},
+			},
+		},
+	}
+	result := validation.ValidatePipelineTemplateRefs(pipelines)
+	if len(result.Warnings) != 1 {
+		t.Errorf("expected one warning, got %v", result.Warnings)
+	}
+}
+
+// TestValidatePipelineTemplateRefs_DynamicOutputsWarn checks that a field
+// not declared by a step with dynamic outputs is a non-blocking warning.
+func TestValidatePipelineTemplateRefs_DynamicOutputsWarn(t *testing.T) {
+	pipelines := map[string]any{
+		"api": map[string]any{
+			"steps": []any{
+				map[string]any{
+					"name":   "lookup",
+					"type":   "step.db_query_cached",
+					"config": map[string]any{"query": "SELECT * FROM users"},
+				},
+				map[string]any{
+					"name":   "respond",
+					"type":   "step.set",
+					"config": map[string]any{"values": map[string]any{"hit": "{{ .steps.lookup.cache_hit }}", "email": "{{ .steps.lookup.email }}"}},
+				},
+			},
+		},
+	}
+	result := validation.ValidatePipelineTemplateRefs(pipelines)
+	if len(result.Findings) != 1 || result.Findings[0].Code != validation.RefWarningUnverifiedOutput {
+		t.Fatalf("expected one unverified_output finding, got %+v", result.Findings)
+	}
+	if blocking := result.BlockingWarningMessages(); len(blocking) != 0 {
+		t.Errorf("dynamic output warnings must not block, got %v", blocking)
+	}
+}
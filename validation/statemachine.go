@@ -0,0 +1,435 @@
+package validation
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// StateMachineCheck names one static check of statemachine workflow
+// definitions. The names are the keys of engine.validation.stateMachines.
+type StateMachineCheck string
+
+const (
+	// StateMachineUnreachableState flags states no transition path leads to
+	// from the initial state.
+	StateMachineUnreachableState StateMachineCheck = "unreachable_state"
+	// StateMachineUndeclaredState flags an initial state or a transition
+	// fromState/toState that is not declared in states.
+	StateMachineUndeclaredState StateMachineCheck = "undeclared_state"
+	// StateMachineFinalStateTransitions flags final states that have
+	// outgoing transitions.
+	StateMachineFinalStateTransitions StateMachineCheck = "final_state_transitions"
+	// StateMachineAbsorbingState flags states with no outgoing transitions
+	// that are not marked final, so instances reaching them never complete.
+	StateMachineAbsorbingState StateMachineCheck = "absorbing_state"
+	// StateMachineNameCollision flags definitions sharing a name (the engine
+	// keeps only the last) and states or transitions whose names differ
+	// only in case.
+	StateMachineNameCollision StateMachineCheck = "name_collision"
+)
+
+// StateMachineChecks lists every check in reporting order.
+var StateMachineChecks = []StateMachineCheck{
+	StateMachineUndeclaredState,
+	StateMachineNameCollision,
+	StateMachineUnreachableState,
+	StateMachineFinalStateTransitions,
+	StateMachineAbsorbingState,
+}
+
+// Severities of a state machine check.
+const (
+	SeverityOff   = "off"
+	SeverityWarn  = "warn"
+	SeverityError = "error"
+)
+
+// defaultStateMachineSeverities: definitions the engine would run incorrectly
+// are errors, merely suspicious ones warnings.
+var defaultStateMachineSeverities = map[StateMachineCheck]string{
+	StateMachineUndeclaredState:       SeverityError,
+	StateMachineNameCollision:         SeverityError,
+	StateMachineUnreachableState:      SeverityWarn,
+	StateMachineFinalStateTransitions: SeverityWarn,
+	StateMachineAbsorbingState:        SeverityWarn,
+}
+
+// StateMachineDef is the part of a statemachine workflow definition the
+// checks, graph output and simulation need.
+type StateMachineDef struct {
+	Name         string
+	InitialState string
+	States       map[string]StateMachineStateDef
+	Transitions  map[string]StateMachineTransitionDef
+}
+
+// StateMachineStateDef is one declared state.
+type StateMachineStateDef struct {
+	IsFinal bool
+	IsError bool
+}
+
+// StateMachineTransitionDef is one declared transition.
+type StateMachineTransitionDef struct {
+	FromState     string
+	ToState       string
+	AutoTransform bool
+}
+
+// StateMachineResult holds the findings of ValidateStateMachines, split by
+// the severity configured for their check.
+type StateMachineResult struct {
+	Warnings []string
+	Errors   []string
+}
+
+// HasIssues returns true when there are any warnings or errors.
+func (r *StateMachineResult) HasIssues() bool {
+	return len(r.Warnings) > 0 || len(r.Errors) > 0
+}
+
+// StateMachineDefinitions extracts the definitions of the statemachine
+// workflow in workflows (the config's workflows section), in config order.
+// Malformed entries are skipped; the handler reports them when it builds.
+func StateMachineDefinitions(workflows map[string]any) []StateMachineDef {
+	sm, _ := workflows["statemachine"].(map[string]any)
+	rawDefs, _ := sm["definitions"].([]any)
+	defs := make([]StateMachineDef, 0, len(rawDefs))
+	for _, raw := range rawDefs {
+		m, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		def := StateMachineDef{
+			States:      make(map[string]StateMachineStateDef),
+			Transitions: make(map[string]StateMachineTransitionDef),
+		}
+		def.Name, _ = m["name"].(string)
+		def.InitialState, _ = m["initialState"].(string)
+		states, _ := m["states"].(map[string]any)
+		for name, s := range states {
+			sm, _ := s.(map[string]any)
+			var st StateMachineStateDef
+			st.IsFinal, _ = sm["isFinal"].(bool)
+			st.IsError, _ = sm["isError"].(bool)
+			def.States[name] = st
+		}
+		transitions, _ := m["transitions"].(map[string]any)
+		for name, t := range transitions {
+			tm, _ := t.(map[string]any)
+			var tr StateMachineTransitionDef
+			tr.FromState, _ = tm["fromState"].(string)
+			tr.ToState, _ = tm["toState"].(string)
+			tr.AutoTransform, _ = tm["autoTransform"].(bool)
+			def.Transitions[name] = tr
+		}
+		defs = append(defs, def)
+	}
+	return defs
+}
+
+// ParseStateMachineSeverities merges severity overrides, keyed by check name
+// or "default" (applied to every check not named), over the defaults.
+func ParseStateMachineSeverities(overrides map[string]string) (map[StateMachineCheck]string, error) {
+	severities := maps.Clone(defaultStateMachineSeverities)
+	if def, ok := overrides["default"]; ok {
+		if err := checkSeverity("default", def); err != nil {
+			return nil, err
+		}
+		for _, c := range StateMachineChecks {
+			severities[c] = def
+		}
+	}
+	for key, sev := range overrides {
+		if key == "default" {
+			continue
+		}
+		if _, ok := defaultStateMachineSeverities[StateMachineCheck(key)]; !ok {
+			return nil, fmt.Errorf("unknown state machine check %q (known: default, %s)", key, joinChecks(StateMachineChecks))
+		}
+		if err := checkSeverity(key, sev); err != nil {
+			return nil, err
+		}
+		severities[StateMachineCheck(key)] = sev
+	}
+	return severities, nil
+}
+
+func checkSeverity(key, sev string) error {
+	switch sev {
+	case SeverityOff, SeverityWarn, SeverityError:
+		return nil
+	}
+	return fmt.Errorf("%s: invalid severity %q, must be one of: off, warn, error", key, sev)
+}
+
+func joinChecks(checks []StateMachineCheck) string {
+	s := make([]string, len(checks))
+	for i, c := range checks {
+		s[i] = string(c)
+	}
+	return strings.Join(s, ", ")
+}
+
+// ValidateStateMachines runs the static checks on the statemachine workflow
+// in workflows. Each finding is a warning or an error according to
+// severities (see ParseStateMachineSeverities); checks set to "off" are
+// skipped. Findings are ordered by definition, then by state or transition
+// name within each check.
+func ValidateStateMachines(workflows map[string]any, severities map[StateMachineCheck]string) *StateMachineResult {
+	result := &StateMachineResult{}
+	report := func(check StateMachineCheck, def, format string, args ...any) {
+		msg := fmt.Sprintf("statemachine definition %q: ", def) + fmt.Sprintf(format, args...) + " [" + string(check) + "]"
+		switch severities[check] {
+		case SeverityError:
+			result.Errors = append(result.Errors, msg)
+		case SeverityWarn:
+			result.Warnings = append(result.Warnings, msg)
+		}
+	}
+
+	defs := StateMachineDefinitions(workflows)
+	seen := make(map[string]bool, len(defs))
+	for _, def := range defs {
+		if seen[def.Name] {
+			report(StateMachineNameCollision, def.Name, "defined more than once; only the last definition is used")
+		}
+		seen[def.Name] = true
+		checkStateMachineDef(def, report)
+	}
+	return result
+}
+
+func checkStateMachineDef(def StateMachineDef, report func(StateMachineCheck, string, string, ...any)) {
+	stateNames := slices.Sorted(maps.Keys(def.States))
+	transNames := slices.Sorted(maps.Keys(def.Transitions))
+
+	// undeclared_state
+	if _, ok := def.States[def.InitialState]; !ok {
+		report(StateMachineUndeclaredState, def.Name, "initialState %q is not a declared state%s", def.InitialState, suggestState(def.InitialState, stateNames))
+	}
+	for _, name := range transNames {
+		t := def.Transitions[name]
+		if _, ok := def.States[t.FromState]; !ok {
+			report(StateMachineUndeclaredState, def.Name, "transition %q: fromState %q is not a declared state%s", name, t.FromState, suggestState(t.FromState, stateNames))
+		}
+		if _, ok := def.States[t.ToState]; !ok {
+			report(StateMachineUndeclaredState, def.Name, "transition %q: toState %q is not a declared state%s", name, t.ToState, suggestState(t.ToState, stateNames))
+		}
+	}
+
+	// name_collision
+	for _, group := range []struct {
+		kind  string
+		names []string
+	}{{"states", stateNames}, {"transitions", transNames}} {
+		kind, names := group.kind, group.names
+		byFold := make(map[string][]string)
+		for _, n := range names {
+			byFold[strings.ToLower(n)] = append(byFold[strings.ToLower(n)], n)
+		}
+		for _, n := range names {
+			if same := byFold[strings.ToLower(n)]; len(same) > 1 && same[0] == n {
+				report(StateMachineNameCollision, def.Name, "%s %s differ only in case", kind, quoteJoin(same))
+			}
+		}
+	}
+
+	// unreachable_state
+	if _, ok := def.States[def.InitialState]; ok {
+		reachable := def.Reachable()
+		for _, s := range stateNames {
+			if !reachable[s] {
+				report(StateMachineUnreachableState, def.Name, "state %q is unreachable from initialState %q", s, def.InitialState)
+			}
+		}
+	}
+
+	outgoing := make(map[string][]string)
+	for _, name := range transNames {
+		from := def.Transitions[name].FromState
+		outgoing[from] = append(outgoing[from], name)
+	}
+
+	// final_state_transitions and absorbing_state
+	for _, s := range stateNames {
+		switch {
+		case def.States[s].IsFinal && len(outgoing[s]) > 0:
+			report(StateMachineFinalStateTransitions, def.Name, "final state %q has outgoing transitions %s", s, quoteJoin(outgoing[s]))
+		case !def.States[s].IsFinal && len(outgoing[s]) == 0:
+			report(StateMachineAbsorbingState, def.Name, "state %q has no outgoing transitions but is not marked isFinal", s)
+		}
+	}
+}
+
+// Reachable returns the declared states reachable from the initial state.
+func (d StateMachineDef) Reachable() map[string]bool {
+	reachable := map[string]bool{}
+	if _, ok := d.States[d.InitialState]; !ok {
+		return reachable
+	}
+	reachable[d.InitialState] = true
+	queue := []string{d.InitialState}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, t := range d.Transitions {
+			if t.FromState != cur || reachable[t.ToState] {
+				continue
+			}
+			if _, ok := d.States[t.ToState]; ok {
+				reachable[t.ToState] = true
+				queue = append(queue, t.ToState)
+			}
+		}
+	}
+	return reachable
+}
+
+// maxAutoTransitions bounds the chain of autoTransform transitions Simulate
+// follows after one transition, so a cycle of them cannot loop forever.
+const maxAutoTransitions = 100
+
+// SimulationStep is one transition fired by Simulate.
+type SimulationStep struct {
+	Transition string `json:"transition"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+	// Auto is set for autoTransform transitions, which fire on their own.
+	Auto bool `json:"auto,omitempty"`
+}
+
+// SimulationResult is the outcome of Simulate.
+type SimulationResult struct {
+	Steps      []SimulationStep `json:"steps"`
+	FinalState string           `json:"finalState"`
+	Completed  bool             `json:"completed"`
+	// FailedAt is the index in the simulated sequence of the transition that
+	// could not fire, or -1 when every transition fired.
+	FailedAt int    `json:"failedAt"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Simulate fires the named transitions in order from the initial state the
+// way the engine does: a transition fires only from its fromState, and after
+// each one the first (by name) autoTransform transition leaving the new
+// state fires as well, unless that state is final. Simulation stops at the
+// first transition that cannot fire.
+func (d StateMachineDef) Simulate(transitions []string) SimulationResult {
+	res := SimulationResult{FinalState: d.InitialState, FailedAt: -1}
+	if _, ok := d.States[d.InitialState]; !ok {
+		res.FailedAt = 0
+		res.Error = fmt.Sprintf("initialState %q is not a declared state", d.InitialState)
+		return res
+	}
+	var autoNames []string
+	for _, name := range slices.Sorted(maps.Keys(d.Transitions)) {
+		if d.Transitions[name].AutoTransform {
+			autoNames = append(autoNames, name)
+		}
+	}
+	fire := func(name string, auto bool) {
+		t := d.Transitions[name]
+		res.Steps = append(res.Steps, SimulationStep{Transition: name, From: res.FinalState, To: t.ToState, Auto: auto})
+		res.FinalState = t.ToState
+	}
+
+	for i, name := range transitions {
+		t, ok := d.Transitions[name]
+		switch {
+		case !ok:
+			res.Error = fmt.Sprintf("transition %q is not defined", name)
+		case t.FromState != res.FinalState:
+			res.Error = fmt.Sprintf("transition %q cannot fire from state %q (fromState is %q)", name, res.FinalState, t.FromState)
+		case !d.hasState(t.ToState):
+			res.Error = fmt.Sprintf("transition %q leads to undeclared state %q", name, t.ToState)
+		}
+		if res.Error != "" {
+			res.FailedAt = i
+			return res
+		}
+		fire(name, false)
+
+		for n := 0; !d.States[res.FinalState].IsFinal; n++ {
+			next := ""
+			for _, a := range autoNames {
+				if d.Transitions[a].FromState == res.FinalState {
+					next = a
+					break
+				}
+			}
+			if next == "" {
+				break
+			}
+			switch {
+			case !d.hasState(d.Transitions[next].ToState):
+				res.Error = fmt.Sprintf("autoTransform transition %q leads to undeclared state %q", next, d.Transitions[next].ToState)
+			case n == maxAutoTransitions:
+				res.Error = fmt.Sprintf("autoTransform transitions loop; still firing %q from state %q after %d", next, res.FinalState, n)
+			}
+			if res.Error != "" {
+				res.FailedAt = i
+				return res
+			}
+			fire(next, true)
+		}
+	}
+	res.Completed = d.States[res.FinalState].IsFinal
+	return res
+}
+
+func (d StateMachineDef) hasState(name string) bool {
+	_, ok := d.States[name]
+	return ok
+}
+
+// suggestState returns a "did you mean" hint for a typo'd state name.
+func suggestState(name string, states []string) string {
+	best := closestMatch(name, states)
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean %q?)", best)
+}
+
+// closestMatch returns the candidate nearest to name by edit distance,
+// ignoring case, or "" when none is within two edits.
+func closestMatch(name string, candidates []string) string {
+	best, bestDist := "", 3 // suggest only close matches
+	for _, c := range candidates {
+		if d := levenshtein(strings.ToLower(name), strings.ToLower(c)); d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func quoteJoin(names []string) string {
+	q := make([]string, len(names))
+	for i, n := range names {
+		q[i] = fmt.Sprintf("%q", n)
+	}
+	return strings.Join(q, ", ")
+}
@@ -0,0 +1,162 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+)
+
+// orderWorkflows returns a statemachine workflow config whose definition
+// "orders" has one problem of every kind.
+func orderWorkflows() map[string]any {
+	state := func(final bool) map[string]any { return map[string]any{"isFinal": final} }
+	trans := func(from, to string) map[string]any { return map[string]any{"fromState": from, "toState": to} }
+	return map[string]any{
+		"statemachine": map[string]any{
+			"engine": "orders-engine",
+			"definitions": []any{
+				map[string]any{
+					"name":         "orders",
+					"initialState": "new",
+					"states": map[string]any{
+						"new":      state(false),
+						"paid":     state(false),
+						"shipped":  state(true),
+						"archived": state(false), // unreachable and absorbing
+						"Paid":     state(false), // collides with paid
+					},
+					"transitions": map[string]any{
+						"pay":    trans("new", "paid"),
+						"ship":   trans("paid", "shipped"),
+						"return": trans("shipped", "paid"),  // from a final state
+						"cancel": trans("new", "cancelled"), // undeclared toState
+						"refund": trans("shiped", "new"),    // typo'd fromState
+					},
+				},
+				map[string]any{"name": "orders", "initialState": "a", "states": map[string]any{"a": state(true)}},
+			},
+		},
+	}
+}
+
+func TestValidateStateMachines(t *testing.T) {
+	severities, err := ParseStateMachineSeverities(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := ValidateStateMachines(orderWorkflows(), severities)
+
+	wantErrors := []string{
+		`transition "cancel": toState "cancelled" is not a declared state`,
+		`transition "refund": fromState "shiped" is not a declared state (did you mean "shipped"?)`,
+		`states "Paid", "paid" differ only in case`,
+		`defined more than once`,
+	}
+	wantWarnings := []string{
+		`state "Paid" is unreachable`,
+		`state "archived" is unreachable from initialState "new" [unreachable_state]`,
+		`final state "shipped" has outgoing transitions "return"`,
+		`state "archived" has no outgoing transitions but is not marked isFinal [absorbing_state]`,
+	}
+	assertFindings(t, "errors", res.Errors, wantErrors)
+	assertFindings(t, "warnings", res.Warnings, wantWarnings)
+	if len(res.Errors) != len(wantErrors) {
+		t.Errorf("errors = %d, want %d:\n%s", len(res.Errors), len(wantErrors), strings.Join(res.Errors, "\n"))
+	}
+}
+
+func assertFindings(t *testing.T, kind string, got, want []string) {
+	t.Helper()
+	for _, w := range want {
+		found := false
+		for _, g := range got {
+			if strings.Contains(g, w) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("%s missing %q in:\n%s", kind, w, strings.Join(got, "\n"))
+		}
+	}
+}
+
+func TestValidateStateMachines_Severities(t *testing.T) {
+	severities, err := ParseStateMachineSeverities(map[string]string{
+		"default":           "off",
+		"unreachable_state": "error",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := ValidateStateMachines(orderWorkflows(), severities)
+	if len(res.Warnings) != 0 {
+		t.Errorf("warnings = %v, want none", res.Warnings)
+	}
+	for _, e := range res.Errors {
+		if !strings.HasSuffix(e, "[unreachable_state]") {
+			t.Errorf("unexpected error %q", e)
+		}
+	}
+	if len(res.Errors) != 2 {
+		t.Errorf("errors = %v, want the two unreachable states", res.Errors)
+	}
+
+	for _, bad := range []map[string]string{{"unreachable": "warn"}, {"absorbing_state": "fatal"}, {"default": "loud"}} {
+		if _, err := ParseStateMachineSeverities(bad); err == nil {
+			t.Errorf("%v: expected an error", bad)
+		}
+	}
+}
+
+func TestValidateStateMachines_CleanDefinition(t *testing.T) {
+	wf := map[string]any{"statemachine": map[string]any{"definitions": []any{map[string]any{
+		"name":         "ticket",
+		"initialState": "open",
+		"states": map[string]any{
+			"open":   map[string]any{},
+			"closed": map[string]any{"isFinal": true},
+		},
+		"transitions": map[string]any{"close": map[string]any{"fromState": "open", "toState": "closed"}},
+	}}}}
+	severities, _ := ParseStateMachineSeverities(nil)
+	if res := ValidateStateMachines(wf, severities); res.HasIssues() {
+		t.Errorf("clean definition reported %+v", res)
+	}
+}
+
+func TestStateMachineDef_Simulate(t *testing.T) {
+	def := StateMachineDef{
+		Name:         "orders",
+		InitialState: "new",
+		States: map[string]StateMachineStateDef{
+			"new": {}, "paid": {}, "packed": {}, "shipped": {IsFinal: true},
+		},
+		Transitions: map[string]StateMachineTransitionDef{
+			"pay":  {FromState: "new", ToState: "paid"},
+			"pack": {FromState: "paid", ToState: "packed", AutoTransform: true},
+			"ship": {FromState: "packed", ToState: "shipped"},
+		},
+	}
+
+	res := def.Simulate([]string{"pay", "ship"})
+	if res.Error != "" || res.FailedAt != -1 {
+		t.Fatalf("unexpected failure: %+v", res)
+	}
+	if res.FinalState != "shipped" || !res.Completed || len(res.Steps) != 3 || !res.Steps[1].Auto {
+		t.Errorf("result = %+v", res)
+	}
+
+	res = def.Simulate([]string{"pay", "pay"})
+	if res.FailedAt != 1 || !strings.Contains(res.Error, `cannot fire from state "packed"`) {
+		t.Errorf("result = %+v", res)
+	}
+	if res = def.Simulate([]string{"teleport"}); res.FailedAt != 0 || !strings.Contains(res.Error, "not defined") {
+		t.Errorf("result = %+v", res)
+	}
+
+	// A cycle of auto transitions is reported rather than followed forever.
+	def.Transitions["unpack"] = StateMachineTransitionDef{FromState: "packed", ToState: "paid", AutoTransform: true}
+	if res = def.Simulate([]string{"pay"}); !strings.Contains(res.Error, "loop") {
+		t.Errorf("result = %+v", res)
+	}
+}
@@ -0,0 +1,151 @@
+// Package version reports the build identity of workflow binaries.
+//
+// Release builds stamp the variables below via -ldflags:
+//
+//	go build -ldflags "\
+//	  -X github.com/GoCodeAlone/workflow/version.Version=v1.2.3 \
+//	  -X github.com/GoCodeAlone/workflow/version.Commit=$(git rev-parse HEAD) \
+//	  -X github.com/GoCodeAlone/workflow/version.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Unstamped builds fall back to Go build metadata (module version and VCS
+// settings), so `go install` and local builds still report something useful.
+// The server, wfctl, the MCP server, and the LSP server all report through
+// this package so every binary agrees on what it is.
+package version
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+// Build identity, overridden at link time. Keep these as plain string
+// initializers so the linker can replace them.
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildDate = ""
+)
+
+// Plugin sources reported in PluginInfo.Source.
+const (
+	SourceBuiltin  = "builtin"
+	SourceExternal = "external"
+)
+
+// Info is the resolved build identity of the running binary.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildDate string `json:"buildDate,omitempty"`
+	GoVersion string `json:"goVersion"`
+}
+
+// PluginInfo describes a plugin loaded into an engine.
+type PluginInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Source  string `json:"source"` // builtin | external
+}
+
+// Report is the engine build identity together with the active config
+// revision and the loaded plugin inventory.
+type Report struct {
+	Info
+	ConfigHash string       `json:"configHash,omitempty"`
+	Plugins    []PluginInfo `json:"plugins"`
+}
+
+// readBuildInfo caches debug.ReadBuildInfo; Get is called on every pipeline
+// execution.
+var readBuildInfo = sync.OnceValues(debug.ReadBuildInfo)
+
+// SetVersion overrides Version when v is a real version string. Binaries that
+// historically stamped main.version call this so existing release pipelines
+// keep working.
+func SetVersion(v string) {
+	if v != "" && v != "dev" && v != "(devel)" {
+		Version = v
+	}
+}
+
+// Get returns the build identity, filling unstamped fields from Go build
+// metadata where available.
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+	}
+	bi, ok := readBuildInfo()
+	if !ok {
+		return info
+	}
+	if info.Version == "dev" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+		// Go appends +dirty for modified working trees; it is not part of a
+		// valid module version.
+		info.Version = strings.TrimSuffix(bi.Main.Version, "+dirty")
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = s.Value
+			}
+		case "vcs.time":
+			if info.BuildDate == "" {
+				info.BuildDate = s.Value
+			}
+		}
+	}
+	return info
+}
+
+// String returns a one-line description such as "v1.2.3 (abc1234, 2026-01-02T15:04:05Z)".
+func (i Info) String() string {
+	var details []string
+	if i.Commit != "" {
+		details = append(details, ShortCommit(i.Commit))
+	}
+	if i.BuildDate != "" {
+		details = append(details, i.BuildDate)
+	}
+	if len(details) == 0 {
+		return i.Version
+	}
+	return fmt.Sprintf("%s (%s)", i.Version, strings.Join(details, ", "))
+}
+
+// String returns the one-line build description of the running binary.
+func String() string {
+	return Get().String()
+}
+
+// ShortCommit abbreviates a commit hash to 7 characters.
+func ShortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
+
+// LogArgs returns slog key/value pairs describing the report, suitable for
+// startup log lines.
+func (r Report) LogArgs() []any {
+	plugins := make([]string, 0, len(r.Plugins))
+	for _, p := range r.Plugins {
+		entry := p.Name + "@" + p.Version
+		if p.Source == SourceExternal {
+			entry += " (external)"
+		}
+		plugins = append(plugins, entry)
+	}
+	args := []any{"version", r.Version, "commit", ShortCommit(r.Commit), "build_date", r.BuildDate}
+	if r.ConfigHash != "" {
+		args = append(args, "config_hash", r.ConfigHash)
+	}
+	return append(args, "plugins", plugins)
+}
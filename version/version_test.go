@@ -0,0 +1,63 @@
+package version
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestGet_UsesStampedValues(t *testing.T) {
+	origV, origC, origD := Version, Commit, BuildDate
+	t.Cleanup(func() { Version, Commit, BuildDate = origV, origC, origD })
+
+	Version, Commit, BuildDate = "v1.2.3", "0123456789abcdef", "2026-01-02T15:04:05Z"
+	info := Get()
+	if info.Version != "v1.2.3" || info.Commit != "0123456789abcdef" || info.BuildDate != "2026-01-02T15:04:05Z" {
+		t.Errorf("unexpected info: %+v", info)
+	}
+	if info.GoVersion != runtime.Version() {
+		t.Errorf("GoVersion = %q, want %q", info.GoVersion, runtime.Version())
+	}
+	if got, want := info.String(), "v1.2.3 (0123456, 2026-01-02T15:04:05Z)"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestSetVersion_IgnoresDevSentinels(t *testing.T) {
+	orig := Version
+	t.Cleanup(func() { Version = orig })
+
+	Version = "v0.1.0"
+	for _, v := range []string{"", "dev", "(devel)"} {
+		SetVersion(v)
+		if Version != "v0.1.0" {
+			t.Fatalf("SetVersion(%q) changed Version to %q", v, Version)
+		}
+	}
+	SetVersion("v0.2.0")
+	if Version != "v0.2.0" {
+		t.Errorf("Version = %q, want v0.2.0", Version)
+	}
+}
+
+func TestReport_LogArgs(t *testing.T) {
+	r := Report{
+		Info:       Info{Version: "v1.0.0", Commit: "abcdef0123"},
+		ConfigHash: "sha256:abc",
+		Plugins: []PluginInfo{
+			{Name: "http", Version: "1.0.0", Source: SourceBuiltin},
+			{Name: "payments", Version: "0.3.1", Source: SourceExternal},
+		},
+	}
+	args := r.LogArgs()
+	kv := map[string]any{}
+	for i := 0; i+1 < len(args); i += 2 {
+		kv[args[i].(string)] = args[i+1]
+	}
+	if kv["commit"] != "abcdef0" || kv["config_hash"] != "sha256:abc" {
+		t.Errorf("unexpected args: %v", kv)
+	}
+	plugins := kv["plugins"].([]string)
+	if len(plugins) != 2 || plugins[0] != "http@1.0.0" || plugins[1] != "payments@0.3.1 (external)" {
+		t.Errorf("unexpected plugins: %v", plugins)
+	}
+}
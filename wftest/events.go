@@ -27,7 +27,7 @@ func (h *Harness) FireEvent(topic string, data map[string]any) *Result {
 
 	resultHolder := &module.PipelineResultHolder{}
 	ctxHolder := &module.PipelineContextHolder{}
-	ctx := context.WithValue(h.t.Context(), module.PipelineResultContextKey, resultHolder)
+	ctx := context.WithValue(h.context(), module.PipelineResultContextKey, resultHolder)
 	ctx = context.WithValue(ctx, module.PipelineContextKey, ctxHolder)
 
 	start := time.Now()
@@ -74,7 +74,7 @@ func (h *Harness) FireSchedule(pipelineName string, params map[string]any) *Resu
 	}
 
 	start := time.Now()
-	pc, err := h.engine.ExecutePipelineContext(h.t.Context(), pipelineName, params)
+	pc, err := h.engine.ExecutePipelineContext(h.context(), pipelineName, params)
 	if err != nil {
 		return &Result{Error: err, Duration: time.Since(start)}
 	}
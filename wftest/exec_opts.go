@@ -73,7 +73,7 @@ func (h *Harness) executePipelineWithStopAfter(name string, data map[string]any,
 	}()
 
 	// Execute using the same logic as ExecutePipeline.
-	ctx := h.t.Context()
+	ctx := h.context()
 	start := time.Now()
 	pc, err := pipeline.Execute(ctx, data)
 	if err != nil {
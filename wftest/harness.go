@@ -12,6 +12,8 @@ import (
 	"github.com/GoCodeAlone/modular/modules/eventbus/v2"
 	"github.com/GoCodeAlone/workflow"
 	"github.com/GoCodeAlone/workflow/config"
+	"github.com/GoCodeAlone/workflow/module"
+	"github.com/GoCodeAlone/workflow/pipeline"
 	"github.com/GoCodeAlone/workflow/plugin"
 	pluginactors "github.com/GoCodeAlone/workflow/plugins/actors"
 	pluginai "github.com/GoCodeAlone/workflow/plugins/ai"
@@ -60,6 +62,8 @@ type Harness struct {
 	mockSteps   map[string]StepHandler
 	mockModules []*MockModule
 	state       *StateStore
+	// templateSources is set by WithTemplateSources.
+	templateSources *pipeline.TemplateSources
 }
 
 // New creates a test harness with the given options.
@@ -137,6 +141,16 @@ func (h *Harness) init() {
 	}
 }
 
+// context returns the test's context, carrying the template sources set by
+// WithTemplateSources.
+func (h *Harness) context() context.Context {
+	ctx := h.t.Context()
+	if h.templateSources != nil {
+		ctx = pipeline.WithTemplateSources(ctx, *h.templateSources)
+	}
+	return ctx
+}
+
 // ensureStarted starts the engine once. It is a no-op if the engine was
 // already started by startServer() (WithServer mode sets h.httpHandler).
 func (h *Harness) ensureStarted() {
@@ -146,7 +160,7 @@ func (h *Harness) ensureStarted() {
 			// WithServer mode already started the engine.
 			return
 		}
-		ctx := h.t.Context()
+		ctx := h.context()
 		if err := h.engine.Start(ctx); err != nil {
 			h.t.Fatalf("wftest: engine.Start failed: %v", err)
 		}
@@ -156,6 +170,12 @@ func (h *Harness) ensureStarted() {
 	})
 }
 
+// httpRouterHandler is an HTTP router that serves its routes.
+type httpRouterHandler interface {
+	http.Handler
+	module.HTTPRouter
+}
+
 // getHTTPHandler returns the engine's HTTP router as an http.Handler for
 // in-process request injection. The engine is started (once) on the first call;
 // if WithServer() already started it, the cached handler is returned directly.
@@ -167,11 +187,12 @@ func (h *Harness) getHTTPHandler() http.Handler {
 			return
 		}
 		h.ensureStarted()
-		for _, svc := range h.engine.App().SvcRegistry() {
-			if handler, ok := svc.(http.Handler); ok {
-				h.httpHandler = handler
-				break
-			}
+		// Prefer the router over other handler services such as admin APIs.
+		registry := h.engine.App().SvcRegistry()
+		if router, err := module.FindByInterface[httpRouterHandler](registry); err == nil {
+			h.httpHandler = router.Service
+		} else if handler, err := module.FindByInterface[http.Handler](registry); err == nil {
+			h.httpHandler = handler.Service
 		}
 		if h.httpHandler == nil {
 			h.t.Fatalf("wftest: no http.Handler found in service registry; ensure an http.router module is configured")
@@ -205,7 +226,7 @@ func (h *Harness) RecordStep(stepType string) *Recorder {
 // StepResults in the returned Result is populated with per-step outputs.
 func (h *Harness) ExecutePipeline(name string, data map[string]any) *Result {
 	h.t.Helper()
-	ctx := h.t.Context()
+	ctx := h.context()
 	start := time.Now()
 	pc, err := h.engine.ExecutePipelineContext(ctx, name, data)
 	if err != nil {
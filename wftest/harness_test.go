@@ -2,7 +2,9 @@ package wftest_test
 
 import (
 	"testing"
+	"time"
 
+	"github.com/GoCodeAlone/workflow/pipeline"
 	"github.com/GoCodeAlone/workflow/wftest"
 )
 
@@ -172,6 +174,47 @@ pipelines:
 	}
 }
 
+func TestHarness_WithTemplateSources(t *testing.T) {
+	yaml := `
+modules:
+  - name: router
+    type: http.router
+pipelines:
+  stamp:
+    trigger:
+      type: http
+      config:
+        path: /stamp
+        method: POST
+    steps:
+      - name: stamp
+        type: step.set
+        config:
+          values:
+            id: "{{ uuid }}"
+            at: "{{ now \"DateTime\" }}"
+      - name: respond
+        type: step.json_response
+        config:
+          body:
+            id: "{{ .id }}"
+            at: "{{ .at }}"
+`
+	fixed := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	run := func() (map[string]any, map[string]any) {
+		h := wftest.New(t, wftest.WithYAML(yaml), wftest.WithTemplateSources(pipeline.FixedTemplateSources(fixed, 7)))
+		return h.ExecutePipeline("stamp", nil).Output, h.POST("/stamp", `{}`).JSON()
+	}
+	out1, body1 := run()
+	out2, body2 := run()
+	if out1["at"] != "2026-01-02 03:04:05" || out1["id"] != out2["id"] {
+		t.Errorf("ExecutePipeline outputs %v and %v", out1, out2)
+	}
+	if body1["at"] != "2026-01-02 03:04:05" || body1["id"] != body2["id"] {
+		t.Errorf("HTTP responses %v and %v", body1, body2)
+	}
+}
+
 func TestHarness_WithConfig_LoadsFile(t *testing.T) {
 	h := wftest.New(t, wftest.WithYAML(`
 pipelines:
@@ -1,10 +1,13 @@
 package wftest
 
 import (
+	"context"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+
+	"github.com/GoCodeAlone/workflow/module"
 )
 
 // RequestOption configures an HTTP test request.
@@ -75,6 +78,9 @@ func (h *Harness) doHTTP(method, path, body string, opts []RequestOption) *Resul
 	if body != "" && req.Header.Get("Content-Type") == "" {
 		req.Header.Set("Content-Type", "application/json")
 	}
+	// Capture the route pipeline's context for its step outputs.
+	holder := &module.PipelineContextHolder{}
+	req = req.WithContext(context.WithValue(h.context(), module.PipelineContextKey, holder))
 	for _, opt := range opts {
 		opt(req)
 	}
@@ -92,9 +98,13 @@ func (h *Harness) doHTTP(method, path, body string, opts []RequestOption) *Resul
 		}
 	}
 
-	return &Result{
+	result := &Result{
 		StatusCode: resp.StatusCode,
 		Headers:    headers,
 		RawBody:    rawBody,
 	}
+	if pc := holder.Get(); pc != nil {
+		result.StepResults = pc.StepOutputs
+	}
+	return result
 }
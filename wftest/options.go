@@ -1,6 +1,9 @@
 package wftest
 
-import "github.com/GoCodeAlone/workflow/plugin"
+import (
+	"github.com/GoCodeAlone/workflow/pipeline"
+	"github.com/GoCodeAlone/workflow/plugin"
+)
 
 // Option configures a Harness when passed to New.
 // All built-in option constructors (WithYAML, MockStep, etc.) implement this
@@ -62,3 +65,12 @@ func WithServer() Option {
 func WithState() Option {
 	return optionFunc(func(h *Harness) { h.state = NewStateStore() })
 }
+
+// WithTemplateSources renders the now, uuid and uuidv4 template functions of
+// every execution started by the harness with src, so their values are the
+// same on every run:
+//
+//	wftest.WithTemplateSources(pipeline.FixedTemplateSources(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), 1))
+func WithTemplateSources(src pipeline.TemplateSources) Option {
+	return optionFunc(func(h *Harness) { h.templateSources = &src })
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/GoCodeAlone/workflow/module"
+	"github.com/GoCodeAlone/workflow/pipeline"
 	"github.com/gorilla/websocket"
 )
 
@@ -38,7 +39,14 @@ func (h *Harness) startServer() {
 	}
 
 	h.httpHandler = router
-	h.httpServer = httptest.NewServer(router)
+	var handler http.Handler = router
+	if h.templateSources != nil {
+		src := *h.templateSources
+		handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			router.ServeHTTP(w, r.WithContext(pipeline.WithTemplateSources(r.Context(), src)))
+		})
+	}
+	h.httpServer = httptest.NewServer(handler)
 	h.baseURL = h.httpServer.URL
 
 	h.t.Cleanup(func() {
@@ -0,0 +1,451 @@
+package wftest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultSnapshotDir is where snapshots are written, relative to the cases
+// file, when snapshot_dir is not set.
+const DefaultSnapshotDir = "__snapshots__"
+
+// SnapshotFile is the structure of a snapshot cases file: the config under
+// test, step mocks, and the cases whose results are recorded as snapshots.
+//
+//	config: ../app.yaml
+//	mocks:
+//	  fixtures:
+//	    step.http_call: fixtures/payments.json
+//	normalize:
+//	  - path: response.body.order_number
+//	    replace: <order-number>
+//	cases:
+//	  create-order:
+//	    trigger: {type: http.post, path: /orders, data: {sku: A-1, qty: 2}}
+//	    steps: [price, persist]
+type SnapshotFile struct {
+	// Config is a file path to a workflow YAML config, relative to the
+	// cases file. Mutually exclusive with YAML.
+	Config string `yaml:"config"`
+	// YAML is an inline workflow YAML config string.
+	YAML string `yaml:"yaml"`
+	// Dir is the directory snapshots are written to, relative to the cases
+	// file. Defaults to DefaultSnapshotDir.
+	Dir string `yaml:"snapshot_dir"`
+	// Mocks are step-level mocks shared across all cases.
+	Mocks MockConfig `yaml:"mocks"`
+	// Normalize rules apply to every case, before the built-in timestamp
+	// and UUID normalizers.
+	Normalize []NormalizeRule `yaml:"normalize"`
+	// Cases maps case names to their definitions. A case name is also the
+	// snapshot file name.
+	Cases map[string]SnapshotCase `yaml:"cases"`
+}
+
+// SnapshotCase is one recorded execution: a trigger and the parts of its
+// result that are kept.
+type SnapshotCase struct {
+	// Description is an optional human-readable label.
+	Description string `yaml:"description"`
+	// Trigger is a pipeline or HTTP trigger.
+	Trigger TriggerDef `yaml:"trigger"`
+	// Steps lists the steps whose outputs are included in the snapshot.
+	Steps []string `yaml:"steps"`
+	// Mocks overrides the file-level mocks for this case only.
+	Mocks *MockConfig `yaml:"mocks"`
+	// Normalize rules apply after the file-level rules.
+	Normalize []NormalizeRule `yaml:"normalize"`
+}
+
+// NormalizeRule replaces values that change between runs, such as
+// generated order numbers, before a snapshot is written or compared. A rule
+// with a Path replaces the whole value at that path; a rule with a Pattern
+// replaces every match in string values, only under Path when both are set.
+type NormalizeRule struct {
+	// Path is a dot path into the snapshot, e.g. "response.body.id" or
+	// "steps.persist.rows.*.created". "*" matches any key or array index.
+	Path string `yaml:"path"`
+	// Pattern is a regular expression matched against string values.
+	Pattern string `yaml:"pattern"`
+	// Replace is the replacement; "$1" refers to a Pattern group.
+	Replace string `yaml:"replace"`
+
+	re *regexp.Regexp
+}
+
+// Built-in normalizers, applied to every string value after the rules of a
+// snapshot file.
+var (
+	snapshotUUIDRe      = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+	snapshotTimestampRe = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`)
+)
+
+// LoadSnapshotFile reads a cases file. Relative config and fixture paths
+// and the snapshot directory are resolved against the file's directory.
+func LoadSnapshotFile(path string) (*SnapshotFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	var f SnapshotFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	dir := filepath.Dir(path)
+	if f.Config != "" && !filepath.IsAbs(f.Config) {
+		f.Config = filepath.Join(dir, f.Config)
+	}
+	if f.Dir == "" {
+		f.Dir = DefaultSnapshotDir
+	}
+	if !filepath.IsAbs(f.Dir) {
+		f.Dir = filepath.Join(dir, f.Dir)
+	}
+	f.Mocks.resolveFixtures(dir)
+	if err := compileNormalizeRules(f.Normalize); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for name, c := range f.Cases {
+		if c.Mocks != nil {
+			c.Mocks.resolveFixtures(dir)
+		}
+		if err := compileNormalizeRules(c.Normalize); err != nil {
+			return nil, fmt.Errorf("%s: case %q: %w", path, name, err)
+		}
+		f.Cases[name] = c
+	}
+	return &f, nil
+}
+
+func compileNormalizeRules(rules []NormalizeRule) error {
+	for i := range rules {
+		r := &rules[i]
+		if r.Path == "" && r.Pattern == "" {
+			return fmt.Errorf("normalize[%d]: set 'path', 'pattern' or both", i)
+		}
+		if r.Pattern != "" {
+			re, err := regexp.Compile(r.Pattern)
+			if err != nil {
+				return fmt.Errorf("normalize[%d]: invalid pattern: %w", i, err)
+			}
+			r.re = re
+		}
+	}
+	return nil
+}
+
+// CaseNames returns the case names, sorted.
+func (f *SnapshotFile) CaseNames() []string {
+	names := make([]string, 0, len(f.Cases))
+	for name := range f.Cases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SnapshotPath returns the path of a case's snapshot.
+func (f *SnapshotFile) SnapshotPath(name string) string {
+	return filepath.Join(f.Dir, name+".snap.json")
+}
+
+// CaseMocks returns the step outputs mocked for a case by step type: the
+// file-level mocks overridden by the case's own.
+func (f *SnapshotFile) CaseMocks(name string) (map[string]map[string]any, error) {
+	c := f.Cases[name]
+	return mergeMockConfigs(&f.Mocks, c.Mocks).stepOutputs()
+}
+
+// Snapshot renders a case's result as a canonical, normalized snapshot.
+func (f *SnapshotFile) Snapshot(name string, result *Result) ([]byte, error) {
+	c := f.Cases[name]
+	doc := BuildSnapshot(result, c.Steps)
+	rules := append(append([]NormalizeRule{}, f.Normalize...), c.Normalize...)
+	return MarshalSnapshot(NormalizeSnapshot(doc, rules))
+}
+
+// BuildSnapshot returns the parts of a result that a snapshot keeps: the
+// error of a failed execution, the response of an HTTP trigger (status,
+// content type and body) or the output of a pipeline trigger, and the
+// outputs of the named steps. A step that did not run is recorded as null.
+// Values that cannot be encoded as JSON are left out.
+func BuildSnapshot(result *Result, steps []string) map[string]any {
+	doc := make(map[string]any)
+	if result.Error != nil {
+		doc["error"] = result.Error.Error()
+	}
+	if result.StatusCode != 0 {
+		response := map[string]any{"status": result.StatusCode}
+		if ct := result.Header("Content-Type"); ct != "" {
+			response["content_type"] = ct
+		}
+		if len(result.RawBody) > 0 {
+			var body any
+			if err := json.Unmarshal(result.RawBody, &body); err == nil {
+				response["body"] = body
+			} else {
+				response["body"] = string(result.RawBody)
+			}
+		}
+		doc["response"] = response
+	} else if result.Error == nil {
+		doc["output"] = jsonSafeMap(result.Output)
+	}
+	if len(steps) > 0 {
+		outputs := make(map[string]any, len(steps))
+		for _, name := range steps {
+			if out := result.StepOutput(name); out != nil {
+				outputs[name] = jsonSafeMap(out)
+			} else {
+				outputs[name] = nil
+			}
+		}
+		doc["steps"] = outputs
+	}
+
+	// Round-trip so every backend and step yields the same JSON types.
+	data, _ := json.Marshal(doc)
+	var out map[string]any
+	_ = json.Unmarshal(data, &out)
+	return out
+}
+
+func jsonSafeMap(m map[string]any) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		if _, err := json.Marshal(v); err == nil {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// NormalizeSnapshot applies rules, then replaces UUIDs with "<uuid>" and
+// RFC 3339 timestamps with "<timestamp>" in every string value. It returns
+// the normalized document; doc must hold JSON types.
+func NormalizeSnapshot(doc any, rules []NormalizeRule) any {
+	for _, r := range rules {
+		var segments []string
+		if r.Path != "" {
+			segments = strings.Split(r.Path, ".")
+		}
+		doc = normalizeAt(doc, segments, r)
+	}
+	return mapStrings(doc, func(s string) string {
+		s = snapshotUUIDRe.ReplaceAllString(s, "<uuid>")
+		return snapshotTimestampRe.ReplaceAllString(s, "<timestamp>")
+	})
+}
+
+// normalizeAt applies r to the values of v at path.
+func normalizeAt(v any, path []string, r NormalizeRule) any {
+	if len(path) == 0 {
+		if r.re == nil {
+			return r.Replace
+		}
+		return mapStrings(v, func(s string) string { return r.re.ReplaceAllString(s, r.Replace) })
+	}
+	seg, rest := path[0], path[1:]
+	switch node := v.(type) {
+	case map[string]any:
+		for k, child := range node {
+			if seg == "*" || seg == k {
+				node[k] = normalizeAt(child, rest, r)
+			}
+		}
+	case []any:
+		for i, child := range node {
+			if seg == "*" || seg == strconv.Itoa(i) {
+				node[i] = normalizeAt(child, rest, r)
+			}
+		}
+	}
+	return v
+}
+
+func mapStrings(v any, fn func(string) string) any {
+	switch node := v.(type) {
+	case string:
+		return fn(node)
+	case map[string]any:
+		for k, child := range node {
+			node[k] = mapStrings(child, fn)
+		}
+	case []any:
+		for i, child := range node {
+			node[i] = mapStrings(child, fn)
+		}
+	}
+	return v
+}
+
+// MarshalSnapshot encodes a snapshot as indented JSON with sorted keys.
+// Whole numbers are written without a fraction and other floats are rounded
+// to 12 significant digits, so the same result encodes identically on every
+// platform.
+func MarshalSnapshot(doc any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(canonicalNumbers(doc)); err != nil {
+		return nil, fmt.Errorf("encode snapshot: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func canonicalNumbers(v any) any {
+	switch node := v.(type) {
+	case float64:
+		if node == math.Trunc(node) && math.Abs(node) < 1<<53 {
+			return int64(node)
+		}
+		rounded, _ := strconv.ParseFloat(strconv.FormatFloat(node, 'g', 12, 64), 64)
+		return rounded
+	case map[string]any:
+		out := make(map[string]any, len(node))
+		for k, child := range node {
+			out[k] = canonicalNumbers(child)
+		}
+		return out
+	case []any:
+		out := make([]any, len(node))
+		for i, child := range node {
+			out[i] = canonicalNumbers(child)
+		}
+		return out
+	}
+	return v
+}
+
+// DiffSnapshots compares a recorded snapshot with a new one and returns one
+// line per difference, sorted by path: "- path: value" for a value only in
+// the recorded snapshot, "+ path: value" for one only in the new snapshot
+// and "~ path: old -> new" for a changed value. It returns nil when they
+// match.
+func DiffSnapshots(recorded, current []byte) ([]string, error) {
+	var want, got any
+	if err := json.Unmarshal(recorded, &want); err != nil {
+		return nil, fmt.Errorf("decode recorded snapshot: %w", err)
+	}
+	if err := json.Unmarshal(current, &got); err != nil {
+		return nil, fmt.Errorf("decode new snapshot: %w", err)
+	}
+	var lines []string
+	diffSnapshotValues("", want, got, &lines)
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i][2:] < lines[j][2:] })
+	return lines, nil
+}
+
+func diffSnapshotValues(path string, want, got any, lines *[]string) {
+	switch w := want.(type) {
+	case map[string]any:
+		if g, ok := got.(map[string]any); ok {
+			for k, wv := range w {
+				gv, ok := g[k]
+				if !ok {
+					*lines = append(*lines, fmt.Sprintf("- %s: %s", joinSnapshotPath(path, k), snapshotValue(wv)))
+					continue
+				}
+				diffSnapshotValues(joinSnapshotPath(path, k), wv, gv, lines)
+			}
+			for k, gv := range g {
+				if _, ok := w[k]; !ok {
+					*lines = append(*lines, fmt.Sprintf("+ %s: %s", joinSnapshotPath(path, k), snapshotValue(gv)))
+				}
+			}
+			return
+		}
+	case []any:
+		if g, ok := got.([]any); ok {
+			for i := 0; i < len(w) || i < len(g); i++ {
+				p := fmt.Sprintf("%s[%d]", path, i)
+				switch {
+				case i >= len(g):
+					*lines = append(*lines, fmt.Sprintf("- %s: %s", p, snapshotValue(w[i])))
+				case i >= len(w):
+					*lines = append(*lines, fmt.Sprintf("+ %s: %s", p, snapshotValue(g[i])))
+				default:
+					diffSnapshotValues(p, w[i], g[i], lines)
+				}
+			}
+			return
+		}
+	}
+	if snapshotValue(want) != snapshotValue(got) {
+		p := path
+		if p == "" {
+			p = "(root)"
+		}
+		*lines = append(*lines, fmt.Sprintf("~ %s: %s -> %s", p, snapshotValue(want), snapshotValue(got)))
+	}
+}
+
+func joinSnapshotPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// snapshotValue renders a value as compact JSON, shortened for diff lines.
+func snapshotValue(v any) string {
+	data, _ := json.Marshal(v)
+	s := string(data)
+	if len(s) > 120 {
+		s = s[:117] + "..."
+	}
+	return s
+}
+
+// HTTPMethod returns the HTTP method of an HTTP trigger and true, or "" and
+// false for other trigger types. Type "http" uses Method, GET when empty;
+// "http.post" and "post" are POST, and so on.
+func (td TriggerDef) HTTPMethod() (string, bool) {
+	t := strings.ToLower(td.Type)
+	if t == "http" {
+		if td.Method == "" {
+			return http.MethodGet, true
+		}
+		return strings.ToUpper(td.Method), true
+	}
+	switch strings.TrimPrefix(t, "http.") {
+	case "get":
+		return http.MethodGet, true
+	case "post":
+		return http.MethodPost, true
+	case "put":
+		return http.MethodPut, true
+	case "patch":
+		return http.MethodPatch, true
+	case "delete":
+		return http.MethodDelete, true
+	case "head":
+		return http.MethodHead, true
+	}
+	return "", false
+}
+
+// HTTPBody returns the JSON request body of an HTTP trigger: Data encoded
+// as JSON, or "" when the trigger has no data.
+func (td TriggerDef) HTTPBody() (string, error) {
+	if td.Data == nil {
+		return "", nil
+	}
+	b, err := json.Marshal(td.Data)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal trigger.data: %w", err)
+	}
+	return string(b), nil
+}
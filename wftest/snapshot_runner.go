@@ -0,0 +1,160 @@
+package wftest
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// UpdateSnapshotsEnv is the environment variable that makes MatchSnapshot
+// and RunSnapshotFile rewrite snapshots instead of comparing them.
+const UpdateSnapshotsEnv = "UPDATE_SNAPSHOTS"
+
+// SnapshotOption configures MatchSnapshot.
+type SnapshotOption func(*snapshotOptions)
+
+type snapshotOptions struct {
+	steps []string
+	rules []NormalizeRule
+}
+
+// SnapshotSteps includes the outputs of the named steps in the snapshot.
+func SnapshotSteps(names ...string) SnapshotOption {
+	return func(o *snapshotOptions) { o.steps = append(o.steps, names...) }
+}
+
+// SnapshotNormalize applies rules before the built-in timestamp and UUID
+// normalizers.
+func SnapshotNormalize(rules ...NormalizeRule) SnapshotOption {
+	return func(o *snapshotOptions) { o.rules = append(o.rules, rules...) }
+}
+
+// MatchSnapshot compares result with the snapshot at path. The first run
+// records the snapshot; later runs fail with a diff when the result
+// changed. Run with UPDATE_SNAPSHOTS=1 to rewrite snapshots after an
+// intended change:
+//
+//	res := h.POST("/orders", `{"sku":"A-1"}`)
+//	wftest.MatchSnapshot(t, "testdata/create-order.snap.json", res, wftest.SnapshotSteps("price"))
+func MatchSnapshot(t *testing.T, path string, result *Result, opts ...SnapshotOption) {
+	t.Helper()
+	var o snapshotOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if err := compileNormalizeRules(o.rules); err != nil {
+		t.Fatalf("MatchSnapshot: %v", err)
+	}
+	got, err := MarshalSnapshot(NormalizeSnapshot(BuildSnapshot(result, o.steps), o.rules))
+	if err != nil {
+		t.Fatalf("MatchSnapshot: %v", err)
+	}
+	matchSnapshotBytes(t, path, got)
+}
+
+// RunSnapshotFile runs every case of a snapshot cases file as a subtest and
+// compares its result with the case's snapshot, recording snapshots that do
+// not exist yet. It is the go test counterpart of wfctl pipeline snapshot
+// verify.
+func RunSnapshotFile(t *testing.T, casesPath string) {
+	t.Helper()
+	f, err := LoadSnapshotFile(casesPath)
+	if err != nil {
+		t.Fatalf("RunSnapshotFile: %v", err)
+	}
+	for _, name := range f.CaseNames() {
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			c := f.Cases[name]
+			if c.Description != "" {
+				t.Log(c.Description)
+			}
+			h := New(t, snapshotHarnessOptions(t, f, name)...)
+			got, err := f.Snapshot(name, fireSnapshotTrigger(t, h, &c.Trigger))
+			if err != nil {
+				t.Fatalf("snapshot %s: %v", name, err)
+			}
+			matchSnapshotBytes(t, f.SnapshotPath(name), got)
+		})
+	}
+}
+
+func snapshotHarnessOptions(t *testing.T, f *SnapshotFile, name string) []Option {
+	t.Helper()
+	var opts []Option
+	switch {
+	case f.YAML != "":
+		opts = append(opts, WithYAML(f.YAML))
+	case f.Config != "":
+		opts = append(opts, WithConfig(f.Config))
+	default:
+		t.Fatal("RunSnapshotFile: either 'yaml' or 'config' must be set in the cases file")
+	}
+	mocks, err := f.CaseMocks(name)
+	if err != nil {
+		t.Fatalf("RunSnapshotFile: %v", err)
+	}
+	for stepType, output := range mocks {
+		opts = append(opts, MockStep(stepType, Returns(output)))
+	}
+	return opts
+}
+
+// fireSnapshotTrigger runs a case's trigger. HTTP triggers honour Method
+// for type "http"; other types behave as in RunYAMLTests.
+func fireSnapshotTrigger(t *testing.T, h *Harness, td *TriggerDef) *Result {
+	t.Helper()
+	method, ok := td.HTTPMethod()
+	if !ok {
+		return fireTrigger(t, h, &TestCase{Trigger: *td})
+	}
+	if td.Path == "" {
+		t.Fatal("RunSnapshotFile: trigger.path is required for http triggers")
+	}
+	body, err := td.HTTPBody()
+	if err != nil {
+		t.Fatalf("RunSnapshotFile: %v", err)
+	}
+	var reqOpts []RequestOption
+	for k, v := range td.Headers {
+		reqOpts = append(reqOpts, Header(k, v))
+	}
+	return h.doHTTP(method, td.Path, body, reqOpts)
+}
+
+func matchSnapshotBytes(t *testing.T, path string, got []byte) {
+	t.Helper()
+	want, err := os.ReadFile(path)
+	missing := errors.Is(err, fs.ErrNotExist)
+	if err != nil && !missing {
+		t.Fatalf("read snapshot: %v", err)
+	}
+	if missing || os.Getenv(UpdateSnapshotsEnv) != "" {
+		if !missing && bytes.Equal(want, got) {
+			return
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("write snapshot: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil { //nolint:gosec // G306: snapshots are committed test data
+			t.Fatalf("write snapshot: %v", err)
+		}
+		t.Logf("wrote snapshot %s", path)
+		return
+	}
+	if bytes.Equal(want, got) {
+		return
+	}
+	lines, err := DiffSnapshots(want, got)
+	if err != nil {
+		t.Fatalf("snapshot %s: %v", path, err)
+	}
+	if len(lines) == 0 {
+		lines = []string{"the recorded snapshot is not in canonical form; re-record it"}
+	}
+	t.Errorf("snapshot %s changed (run with %s=1 to update):\n  %s", path, UpdateSnapshotsEnv, strings.Join(lines, "\n  "))
+}
@@ -0,0 +1,202 @@
+package wftest_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/GoCodeAlone/workflow/wftest"
+)
+
+func TestNormalizeSnapshot(t *testing.T) {
+	doc := map[string]any{
+		"id":      "0b6f1a52-3c1e-4d1e-9b7a-8f0e2c3d4a5b",
+		"created": "created at 2026-03-01T10:15:00.123Z",
+		"order":   "ORD-1842",
+		"items": []any{
+			map[string]any{"ref": "ref-991", "qty": float64(1)},
+			map[string]any{"ref": "ref-104", "qty": float64(2)},
+		},
+	}
+	f := writeSnapshotCases(t, "cases: {}\nnormalize:\n  - {path: order, replace: <order>}\n  - {path: items.*.ref, pattern: 'ref-\\d+', replace: ref-N}\n")
+	got := wftest.NormalizeSnapshot(doc, f.Normalize)
+	want := map[string]any{
+		"id":      "<uuid>",
+		"created": "created at <timestamp>",
+		"order":   "<order>",
+		"items": []any{
+			map[string]any{"ref": "ref-N", "qty": float64(1)},
+			map[string]any{"ref": "ref-N", "qty": float64(2)},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NormalizeSnapshot = %#v, want %#v", got, want)
+	}
+}
+
+func TestNormalizeSnapshot_InvalidPattern(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cases.yaml")
+	if err := os.WriteFile(path, []byte("normalize:\n  - {pattern: '(', replace: x}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wftest.LoadSnapshotFile(path); err == nil {
+		t.Fatal("expected an error for an invalid pattern")
+	}
+}
+
+func TestMarshalSnapshot_Canonical(t *testing.T) {
+	doc := map[string]any{
+		"b":     float64(2),
+		"a":     0.1 + 0.2,
+		"html":  "<b>&</b>",
+		"list":  []any{float64(1e15), 1.5},
+		"inner": map[string]any{"z": nil, "y": true},
+	}
+	got, err := wftest.MarshalSnapshot(doc)
+	if err != nil {
+		t.Fatalf("MarshalSnapshot: %v", err)
+	}
+	want := `{
+  "a": 0.3,
+  "b": 2,
+  "html": "<b>&</b>",
+  "inner": {
+    "y": true,
+    "z": null
+  },
+  "list": [
+    1000000000000000,
+    1.5
+  ]
+}
+`
+	if string(got) != want {
+		t.Errorf("MarshalSnapshot =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	recorded := []byte(`{"response":{"status":200,"body":{"total":10,"items":[1,2]}},"old":true}`)
+	current := []byte(`{"response":{"status":200,"body":{"total":12,"items":[1]}},"new":"x"}`)
+	lines, err := wftest.DiffSnapshots(recorded, current)
+	if err != nil {
+		t.Fatalf("DiffSnapshots: %v", err)
+	}
+	want := []string{
+		`+ new: "x"`,
+		`- old: true`,
+		`- response.body.items[1]: 2`,
+		`~ response.body.total: 10 -> 12`,
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("DiffSnapshots =\n%s\nwant\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+
+	if lines, _ := wftest.DiffSnapshots(recorded, recorded); len(lines) != 0 {
+		t.Errorf("expected no diff for identical snapshots, got %v", lines)
+	}
+}
+
+func TestMatchSnapshot_RecordsThenMatches(t *testing.T) {
+	h := wftest.New(t, wftest.WithYAML(`
+pipelines:
+  quote:
+    steps:
+      - name: price
+        type: step.set
+        config:
+          values:
+            total: 12.5
+            quoted_at: "2026-03-01T10:15:00Z"
+`))
+	path := filepath.Join(t.TempDir(), "quote.snap.json")
+
+	wftest.MatchSnapshot(t, path, h.ExecutePipeline("quote", nil), wftest.SnapshotSteps("price"))
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("snapshot not recorded: %v", err)
+	}
+	for _, want := range []string{`"quoted_at": "<timestamp>"`, `"price": {`, `"total": 12.5`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("snapshot missing %s:\n%s", want, data)
+		}
+	}
+
+	// A second run with the same result matches the recorded snapshot.
+	wftest.MatchSnapshot(t, path, h.ExecutePipeline("quote", nil), wftest.SnapshotSteps("price"))
+}
+
+func TestRunSnapshotFile_HTTPAndFixtures(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "fixtures"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "fixtures", "rates.json"), []byte(`{"rate": 1.25}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cases := `yaml: |
+  modules:
+    - name: router
+      type: http.router
+  pipelines:
+    convert:
+      trigger:
+        type: http
+        config:
+          path: /convert
+          method: POST
+      steps:
+        - name: rates
+          type: step.fx_rates
+        - name: respond
+          type: step.json_response
+          config:
+            status: 200
+            body:
+              rate: "{{ .steps.rates.rate }}"
+mocks:
+  fixtures:
+    step.fx_rates: fixtures/rates.json
+cases:
+  convert-usd:
+    trigger:
+      type: http.post
+      path: /convert
+      data: {amount: 10}
+    steps: [rates]
+`
+	casesPath := filepath.Join(dir, "cases.yaml")
+	if err := os.WriteFile(casesPath, []byte(cases), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	wftest.RunSnapshotFile(t, casesPath)
+
+	data, err := os.ReadFile(filepath.Join(dir, wftest.DefaultSnapshotDir, "convert-usd.snap.json"))
+	if err != nil {
+		t.Fatalf("snapshot not recorded: %v", err)
+	}
+	for _, want := range []string{`"status": 200`, `"rates": {`, `"rate": 1.25`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("snapshot missing %s:\n%s", want, data)
+		}
+	}
+
+	// Verifying against the recorded snapshot passes.
+	wftest.RunSnapshotFile(t, casesPath)
+}
+
+func writeSnapshotCases(t *testing.T, content string) *wftest.SnapshotFile {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "cases.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := wftest.LoadSnapshotFile(path)
+	if err != nil {
+		t.Fatalf("LoadSnapshotFile: %v", err)
+	}
+	return f
+}
@@ -76,23 +76,33 @@ func (s *StateStore) Seed(store string, data map[string]any) {
 // LoadFixture loads state from a JSON or YAML file into a named store.
 // The file must unmarshal to map[string]any.
 func (s *StateStore) LoadFixture(path string, store string) error {
+	m, err := readMapFile(path)
+	if err != nil {
+		return fmt.Errorf("LoadFixture: %w", err)
+	}
+	s.Seed(store, m)
+	return nil
+}
+
+// readMapFile reads a JSON or YAML file holding a map; the format follows
+// the extension, JSON unless .yaml or .yml.
+func readMapFile(path string) (map[string]any, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return fmt.Errorf("LoadFixture: read %s: %w", path, err)
+		return nil, fmt.Errorf("read %s: %w", path, err)
 	}
 	var m map[string]any
 	switch strings.ToLower(filepath.Ext(path)) {
 	case ".yaml", ".yml":
 		if err := yaml.Unmarshal(data, &m); err != nil {
-			return fmt.Errorf("LoadFixture: parse %s: %w", path, err)
+			return nil, fmt.Errorf("parse %s: %w", path, err)
 		}
 	default: // JSON
 		if err := json.Unmarshal(data, &m); err != nil {
-			return fmt.Errorf("LoadFixture: parse %s: %w", path, err)
+			return nil, fmt.Errorf("parse %s: %w", path, err)
 		}
 	}
-	s.Seed(store, m)
-	return nil
+	return m, nil
 }
 
 // Assert checks that each key/value pair in expected matches the actual state
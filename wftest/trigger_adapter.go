@@ -41,7 +41,7 @@ func (h *Harness) InjectTrigger(name, event string, data map[string]any) *Result
 		h.t.Fatalf("wftest: no TriggerAdapter registered with name %q; call wftest.RegisterTriggerAdapter first", name)
 		return &Result{}
 	}
-	result, err := adapter.Inject(h.t.Context(), h, event, data)
+	result, err := adapter.Inject(h.context(), h, event, data)
 	if err != nil {
 		return &Result{Error: err}
 	}
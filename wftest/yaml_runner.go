@@ -33,10 +33,17 @@ func RunYAMLTests(t *testing.T, testFilePath string) {
 		return
 	}
 
-	// Resolve relative config paths relative to the test file's directory.
+	// Resolve relative config and fixture paths relative to the test file's directory.
 	if tf.Config != "" && !filepath.IsAbs(tf.Config) {
 		tf.Config = filepath.Join(filepath.Dir(testFilePath), tf.Config)
 	}
+	tf.Mocks.resolveFixtures(filepath.Dir(testFilePath))
+	for name, tc := range tf.Tests {
+		if tc.Mocks != nil {
+			tc.Mocks.resolveFixtures(filepath.Dir(testFilePath))
+			tf.Tests[name] = tc
+		}
+	}
 
 	for name := range tf.Tests {
 		tc := tf.Tests[name]
@@ -137,8 +144,11 @@ func buildHarnessOptions(t *testing.T, tf *TestFile, mocks *MockConfig) []Option
 
 	// Install mock steps.
 	if mocks != nil {
-		for stepType, output := range mocks.Steps {
-			output := output // capture
+		outputs, err := mocks.stepOutputs()
+		if err != nil {
+			t.Fatalf("RunYAMLTests: %v", err)
+		}
+		for stepType, output := range outputs {
 			opts = append(opts, MockStep(stepType, Returns(output)))
 		}
 	}
@@ -153,17 +163,52 @@ func mergeMockConfigs(base *MockConfig, override *MockConfig) *MockConfig {
 		return base
 	}
 	merged := &MockConfig{
-		Steps: make(map[string]map[string]any),
+		Steps:    make(map[string]map[string]any),
+		Fixtures: make(map[string]string),
 	}
 	for k, v := range base.Steps {
 		merged.Steps[k] = v
 	}
+	for k, v := range base.Fixtures {
+		merged.Fixtures[k] = v
+	}
 	for k, v := range override.Steps {
 		merged.Steps[k] = v
+		delete(merged.Fixtures, k)
+	}
+	for k, v := range override.Fixtures {
+		merged.Fixtures[k] = v
+		delete(merged.Steps, k)
 	}
 	return merged
 }
 
+// resolveFixtures makes relative fixture paths relative to dir.
+func (m *MockConfig) resolveFixtures(dir string) {
+	for stepType, path := range m.Fixtures {
+		if !filepath.IsAbs(path) {
+			m.Fixtures[stepType] = filepath.Join(dir, path)
+		}
+	}
+}
+
+// stepOutputs returns the mocked output of each step type, reading fixture
+// files.
+func (m *MockConfig) stepOutputs() (map[string]map[string]any, error) {
+	outputs := make(map[string]map[string]any, len(m.Steps)+len(m.Fixtures))
+	for stepType, path := range m.Fixtures {
+		output, err := readMapFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("mock fixture for %s: %w", stepType, err)
+		}
+		outputs[stepType] = output
+	}
+	for stepType, output := range m.Steps {
+		outputs[stepType] = output
+	}
+	return outputs, nil
+}
+
 // fireTrigger dispatches to the right harness method based on the trigger type.
 func fireTrigger(t *testing.T, h *Harness, tc *TestCase) *Result {
 	t.Helper()
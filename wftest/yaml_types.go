@@ -19,6 +19,10 @@ type TestFile struct {
 // Each entry maps a step type (e.g. "step.db_query") to a fixed output map.
 type MockConfig struct {
 	Steps map[string]map[string]any `yaml:"steps"`
+	// Fixtures maps a step type to a JSON or YAML file holding its
+	// recorded output, relative to the test file. Steps wins when both
+	// mock the same type.
+	Fixtures map[string]string `yaml:"fixtures"`
 }
 
 // TestCase defines one test within a TestFile.
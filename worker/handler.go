@@ -0,0 +1,97 @@
+package worker
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// Handler serves the worker pool dashboard: the registered workers, the
+// queue depth and the queued jobs.
+type Handler struct {
+	queue Queue
+}
+
+// NewHandler creates a handler for queue.
+func NewHandler(queue Queue) *Handler {
+	return &Handler{queue: queue}
+}
+
+// RegisterRoutes registers the worker pool routes on mux.
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/admin/workers", h.handleWorkers)
+	mux.HandleFunc("GET /api/v1/admin/worker-jobs", h.handleJobs)
+	mux.HandleFunc("GET /api/v1/admin/worker-jobs/{id}", h.handleJob)
+}
+
+// handleWorkers returns the workers and the queue's stats in one response,
+// so a dashboard can draw both from a single poll.
+func (h *Handler) handleWorkers(w http.ResponseWriter, r *http.Request) {
+	workers, err := h.queue.Workers(r.Context())
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	stats, err := h.queue.Stats(r.Context())
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	active := 0
+	for _, wk := range workers {
+		if wk.Status == WorkerActive {
+			active++
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"workers": workers,
+		"total":   len(workers),
+		"active":  active,
+		"queue":   stats,
+	})
+}
+
+func (h *Handler) handleJobs(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := JobFilter{
+		Status:   JobStatus(q.Get("status")),
+		Pipeline: q.Get("pipeline"),
+		WorkerID: q.Get("worker"),
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 1000 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "limit must be between 1 and 1000"})
+			return
+		}
+		filter.Limit = n
+	}
+	jobs, err := h.queue.List(r.Context(), filter)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	if jobs == nil {
+		jobs = []*Job{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"jobs": jobs, "total": len(jobs)})
+}
+
+func (h *Handler) handleJob(w http.ResponseWriter, r *http.Request) {
+	job, err := h.queue.Get(r.Context(), r.PathValue("id"))
+	switch {
+	case errors.Is(err, ErrJobNotFound):
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+	case err != nil:
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	default:
+		writeJSON(w, http.StatusOK, job)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
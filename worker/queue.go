@@ -0,0 +1,135 @@
+// Package worker runs pipeline executions on remote worker processes.
+//
+// The server enqueues executions of routes placed on workers into a durable
+// Queue; workflow-worker processes claim them, execute the pipeline and
+// stream the execution back to the server's ingest API. Delivery is
+// at-least-once: a job claimed by a worker holds a lease that the worker's
+// heartbeats renew, and a job whose lease expires, because its worker died,
+// is put back in the queue for another worker.
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/module"
+)
+
+// JobStatus is the state of a queued execution.
+type JobStatus string
+
+const (
+	JobPending   JobStatus = "pending"
+	JobRunning   JobStatus = "running"
+	JobCompleted JobStatus = "completed"
+	JobFailed    JobStatus = "failed"
+)
+
+// Worker statuses reported by Workers.
+const (
+	WorkerActive = "active"
+	WorkerDead   = "dead"
+)
+
+// DefaultMaxAttempts is how many times a job is delivered before it fails
+// when Job.MaxAttempts is not set.
+const DefaultMaxAttempts = 3
+
+var (
+	// ErrJobNotFound is returned for an unknown job ID.
+	ErrJobNotFound = module.ErrJobNotFound
+	// ErrLeaseLost is returned when a worker reports the outcome of a job it
+	// no longer holds, because its lease expired and the job was requeued.
+	// The outcome is discarded; the job's current holder reports its own.
+	ErrLeaseLost = module.ErrJobLeaseLost
+)
+
+// Job is a pipeline execution waiting for or running on a worker.
+type Job struct {
+	ID string `json:"id"`
+	// IdempotencyKey deduplicates enqueues: enqueuing a key that is already
+	// queued returns the existing job. It is passed to the pipeline as
+	// _idempotency_key, so steps with side effects can deduplicate the
+	// redeliveries at-least-once execution allows.
+	IdempotencyKey string         `json:"idempotency_key,omitempty"`
+	WorkflowID     string         `json:"workflow_id,omitempty"`
+	Pipeline       string         `json:"pipeline"`
+	Input          map[string]any `json:"input,omitempty"`
+	// Labels a worker must all have to claim the job.
+	Labels      []string  `json:"labels,omitempty"`
+	Status      JobStatus `json:"status"`
+	Attempts    int       `json:"attempts"`
+	MaxAttempts int       `json:"max_attempts"`
+	// WorkerID and ExecutionID identify the current or last attempt.
+	WorkerID       string         `json:"worker_id,omitempty"`
+	ExecutionID    string         `json:"execution_id,omitempty"`
+	LeaseExpiresAt time.Time      `json:"lease_expires_at,omitzero"`
+	Result         map[string]any `json:"result,omitempty"`
+	Error          string         `json:"error,omitempty"`
+	EnqueuedAt     time.Time      `json:"enqueued_at"`
+	StartedAt      time.Time      `json:"started_at,omitzero"`
+	CompletedAt    time.Time      `json:"completed_at,omitzero"`
+}
+
+// Info describes a worker process, as registered by its heartbeats.
+type Info struct {
+	ID       string    `json:"id"`
+	Labels   []string  `json:"labels,omitempty"`
+	Status   string    `json:"status"`
+	Started  time.Time `json:"started_at"`
+	LastSeen time.Time `json:"last_seen"`
+	// Running is the number of jobs the worker holds.
+	Running int `json:"running"`
+}
+
+// Stats summarizes the queue.
+type Stats struct {
+	Pending   int `json:"pending"`
+	Running   int `json:"running"`
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+	// OldestPending is when the longest-waiting pending job was enqueued.
+	OldestPending time.Time `json:"oldest_pending,omitzero"`
+	// PendingByPipeline is the queue depth of each pipeline.
+	PendingByPipeline map[string]int `json:"pending_by_pipeline,omitempty"`
+}
+
+// JobFilter selects jobs for List.
+type JobFilter struct {
+	Status   JobStatus
+	Pipeline string
+	WorkerID string
+	Limit    int
+}
+
+// Queue is a durable queue of pipeline executions shared by the server and
+// its workers.
+type Queue interface {
+	// Enqueue adds job as pending and fills in its ID and timestamps. When
+	// job.IdempotencyKey matches a job already queued, that job is returned
+	// with duplicate set and nothing is added.
+	Enqueue(ctx context.Context, job *Job) (existing *Job, duplicate bool, err error)
+	// Claim leases the oldest pending job whose labels the worker has, for
+	// lease. It returns nil when there is none.
+	Claim(ctx context.Context, workerID string, labels []string, lease time.Duration) (*Job, error)
+	// Complete records the result of a job the worker holds.
+	Complete(ctx context.Context, jobID, workerID string, result map[string]any) error
+	// Fail records a failed attempt of a job the worker holds. The job is
+	// retried while it has attempts left.
+	Fail(ctx context.Context, jobID, workerID, message string) error
+	// Heartbeat registers the worker as alive and extends the leases of the
+	// jobs it holds by lease.
+	Heartbeat(ctx context.Context, worker Info, lease time.Duration) error
+	// Requeue returns jobs whose lease expired before now to the queue, or
+	// fails them when they have no attempts left, and marks workers not
+	// seen for workerTTL dead. It returns the number of expired leases.
+	Requeue(ctx context.Context, now time.Time, workerTTL time.Duration) (int, error)
+	// Get returns a job by ID.
+	Get(ctx context.Context, id string) (*Job, error)
+	// List returns jobs matching filter, most recently enqueued first.
+	List(ctx context.Context, filter JobFilter) ([]*Job, error)
+	// Stats summarizes the queue.
+	Stats(ctx context.Context) (*Stats, error)
+	// Workers returns the registered workers, most recently seen first.
+	Workers(ctx context.Context) ([]Info, error)
+}
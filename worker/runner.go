@@ -0,0 +1,311 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"maps"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/module"
+	"github.com/GoCodeAlone/workflow/observability"
+	"github.com/GoCodeAlone/workflow/observability/tracing"
+	"github.com/google/uuid"
+)
+
+// Config configures a Runner.
+type Config struct {
+	// ID identifies the worker; the hostname plus a random suffix by default.
+	ID string
+	// Labels advertise the worker's capabilities, such as "docker-capable".
+	// The worker only claims jobs whose labels it all has.
+	Labels []string
+	// Concurrency is the number of jobs run at once. Defaults to 4.
+	Concurrency int
+	// PollInterval is how often an idle worker checks the queue. Defaults
+	// to 1s.
+	PollInterval time.Duration
+	// Lease is how long a claimed job stays with the worker without a
+	// heartbeat. Defaults to 30s.
+	Lease time.Duration
+	// HeartbeatInterval is how often the worker renews its leases. It must
+	// be well below Lease; defaults to a third of it.
+	HeartbeatInterval time.Duration
+	// JobTimeout bounds a single job run. Zero means no limit.
+	JobTimeout time.Duration
+}
+
+// PipelineSource looks up the pipelines a worker can run.
+// *workflow.StdEngine satisfies it.
+type PipelineSource interface {
+	GetPipeline(name string) (*module.Pipeline, bool)
+}
+
+// Reporter streams executions and their events to the server.
+// *observability.Reporter satisfies it.
+type Reporter interface {
+	ReportExecution(exec observability.ExecutionReport)
+	ReportEvent(event observability.EventReport)
+}
+
+// Runner claims jobs from a Queue and executes their pipelines.
+type Runner struct {
+	cfg       Config
+	queue     Queue
+	pipelines PipelineSource
+	reporter  Reporter
+	logger    *slog.Logger
+	started   time.Time
+}
+
+// NewRunner creates a runner. reporter may be nil, in which case executions
+// are only recorded in the queue.
+func NewRunner(cfg Config, queue Queue, pipelines PipelineSource, reporter Reporter, logger *slog.Logger) *Runner {
+	if cfg.ID == "" {
+		host, _ := os.Hostname()
+		cfg.ID = host + "-" + uuid.NewString()[:8]
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 4
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.Lease <= 0 {
+		cfg.Lease = 30 * time.Second
+	}
+	if cfg.HeartbeatInterval <= 0 || cfg.HeartbeatInterval >= cfg.Lease {
+		cfg.HeartbeatInterval = cfg.Lease / 3
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Runner{cfg: cfg, queue: queue, pipelines: pipelines, reporter: reporter, logger: logger}
+}
+
+// ID returns the worker ID.
+func (r *Runner) ID() string { return r.cfg.ID }
+
+// Run claims and executes jobs until ctx is done, then waits for the jobs
+// it holds to finish. Heartbeats continue while they drain, so their leases
+// do not expire; a worker killed instead has its jobs requeued once their
+// leases expire.
+func (r *Runner) Run(ctx context.Context) error {
+	r.started = time.Now()
+	if err := r.heartbeat(ctx); err != nil {
+		return err
+	}
+	r.logger.Info("Worker started", "worker", r.cfg.ID, "labels", r.cfg.Labels, "concurrency", r.cfg.Concurrency)
+
+	stopHeartbeat := make(chan struct{})
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		ticker := time.NewTicker(r.cfg.HeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopHeartbeat:
+				return
+			case <-ticker.C:
+				if err := r.heartbeat(context.Background()); err != nil {
+					r.logger.Warn("Worker heartbeat failed", "worker", r.cfg.ID, "error", err)
+				}
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, r.cfg.Concurrency)
+	jobCtx := context.WithoutCancel(ctx)
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case slots <- struct{}{}:
+		}
+		job, err := r.queue.Claim(ctx, r.cfg.ID, r.cfg.Labels, r.cfg.Lease)
+		if err != nil || job == nil {
+			<-slots
+			if err != nil && ctx.Err() == nil {
+				r.logger.Warn("Failed to claim job", "worker", r.cfg.ID, "error", err)
+			}
+			select {
+			case <-ctx.Done():
+				break loop
+			case <-time.After(r.cfg.PollInterval):
+			}
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			r.runJob(jobCtx, job)
+		}()
+	}
+
+	wg.Wait()
+	close(stopHeartbeat)
+	<-heartbeatDone
+	r.logger.Info("Worker stopped", "worker", r.cfg.ID)
+	return nil
+}
+
+func (r *Runner) heartbeat(ctx context.Context) error {
+	return r.queue.Heartbeat(ctx, Info{ID: r.cfg.ID, Labels: r.cfg.Labels, Started: r.started}, r.cfg.Lease)
+}
+
+// runJob executes one attempt of job and records its outcome.
+func (r *Runner) runJob(ctx context.Context, job *Job) {
+	logger := r.logger.With("job", job.ID, "pipeline", job.Pipeline, "execution_id", job.ExecutionID, "attempt", job.Attempts)
+	pipeline, ok := r.pipelines.GetPipeline(job.Pipeline)
+	if !ok {
+		// Another worker, running a newer config, may have the pipeline.
+		logger.Warn("Job pipeline is not configured on this worker")
+		r.finish(ctx, logger, job, nil, errors.New("pipeline "+job.Pipeline+" is not configured on worker "+r.cfg.ID))
+		return
+	}
+	data := maps.Clone(job.Input)
+	if data == nil {
+		data = map[string]any{}
+	}
+	data["_job_id"] = job.ID
+	if job.IdempotencyKey != "" {
+		data["_idempotency_key"] = job.IdempotencyKey
+	}
+	runCtx := ctx
+	if id, _ := data["_request_id"].(string); id != "" {
+		runCtx = module.WithRequestID(runCtx, id)
+	}
+	runCtx = tracing.WithBaggage(runCtx, map[string]string{
+		tracing.BaggageWorkflowID:  job.WorkflowID,
+		tracing.BaggageExecutionID: job.ExecutionID,
+	})
+	if r.cfg.JobTimeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(runCtx, r.cfg.JobTimeout)
+		defer cancel()
+	}
+
+	// Run a copy so the execution ID and recorder are per attempt.
+	exec := *pipeline
+	exec.ExecutionID = job.ExecutionID
+	if r.reporter != nil {
+		exec.EventRecorder = reportingRecorder{r.reporter}
+	}
+
+	started := time.Now()
+	logger.Info("Running job")
+	pc, err := exec.Execute(runCtx, data)
+	r.report(job, started, err)
+	var result map[string]any
+	if err == nil {
+		result = jobResult(pc)
+	}
+	r.finish(ctx, logger, job, result, err)
+}
+
+// finish records the outcome of a job attempt in the queue.
+func (r *Runner) finish(ctx context.Context, logger *slog.Logger, job *Job, result map[string]any, runErr error) {
+	var err error
+	if runErr != nil {
+		logger.Warn("Job failed", "error", runErr)
+		err = r.queue.Fail(ctx, job.ID, r.cfg.ID, runErr.Error())
+	} else {
+		logger.Info("Job completed")
+		err = r.queue.Complete(ctx, job.ID, r.cfg.ID, result)
+	}
+	switch {
+	case errors.Is(err, ErrLeaseLost):
+		logger.Warn("Job was requeued while running; its outcome was discarded")
+	case err != nil:
+		logger.Error("Failed to record job outcome", "error", err)
+	}
+}
+
+// report sends the execution record of an attempt to the server.
+func (r *Runner) report(job *Job, started time.Time, err error) {
+	if r.reporter == nil {
+		return
+	}
+	completed := time.Now()
+	rep := observability.ExecutionReport{
+		ID:          job.ExecutionID,
+		WorkflowID:  job.WorkflowID,
+		TriggerType: "worker " + job.Pipeline,
+		Status:      "completed",
+		TriggeredBy: r.cfg.ID,
+		StartedAt:   started,
+		CompletedAt: completed,
+		DurationMs:  completed.Sub(started).Milliseconds(),
+	}
+	if err != nil {
+		rep.Status = "failed"
+		rep.ErrorMessage = err.Error()
+	}
+	r.reporter.ReportExecution(rep)
+}
+
+// jobResult returns what a job's pipeline produced: the step.pipeline_output
+// output when there is one, otherwise the merged state. Values that cannot
+// be stored as JSON, such as the HTTP response writer, are left out.
+func jobResult(pc *module.PipelineContext) map[string]any {
+	if pc == nil {
+		return nil
+	}
+	if out, ok := pc.Current["_pipeline_output"].(map[string]any); ok {
+		return out
+	}
+	result := make(map[string]any, len(pc.Current))
+	for k, v := range pc.Current {
+		if _, err := json.Marshal(v); err == nil {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// reportingRecorder streams pipeline events to the server's ingest API.
+type reportingRecorder struct {
+	reporter Reporter
+}
+
+// RecordEvent implements module.EventRecorder.
+func (r reportingRecorder) RecordEvent(_ context.Context, executionID, eventType string, data map[string]any) error {
+	r.reporter.ReportEvent(observability.EventReport{
+		ExecutionID: executionID,
+		EventType:   eventType,
+		EventData:   data,
+		CreatedAt:   time.Now().UTC().Format(time.RFC3339Nano),
+	})
+	return nil
+}
+
+// Supervise requeues the jobs of dead workers every interval until ctx is
+// done. A job is requeued when its lease expires, and a worker is marked
+// dead when it has not sent a heartbeat for workerTTL. The server runs it;
+// running it in more than one process is harmless.
+func Supervise(ctx context.Context, queue Queue, interval, workerTTL time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			n, err := queue.Requeue(ctx, now, workerTTL)
+			if err != nil {
+				logger.Warn("Failed to requeue expired jobs", "error", err)
+				continue
+			}
+			if n > 0 {
+				logger.Warn("Requeued jobs of unresponsive workers", "jobs", n)
+			}
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/module"
+	"github.com/GoCodeAlone/workflow/observability"
+)
+
+type funcStep struct {
+	name string
+	fn   func(pc *module.PipelineContext) (map[string]any, error)
+}
+
+func (s funcStep) Name() string { return s.name }
+
+func (s funcStep) Execute(_ context.Context, pc *module.PipelineContext) (*module.StepResult, error) {
+	out, err := s.fn(pc)
+	return &module.StepResult{Output: out}, err
+}
+
+type pipelineMap map[string]*module.Pipeline
+
+func (m pipelineMap) GetPipeline(name string) (*module.Pipeline, bool) {
+	p, ok := m[name]
+	return p, ok
+}
+
+type recordingReporter struct {
+	mu     sync.Mutex
+	execs  []observability.ExecutionReport
+	events []observability.EventReport
+}
+
+func (r *recordingReporter) ReportExecution(e observability.ExecutionReport) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.execs = append(r.execs, e)
+}
+
+func (r *recordingReporter) ReportEvent(e observability.EventReport) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+}
+
+// runUntil runs a runner until every job in ids is finished.
+func runUntil(t *testing.T, q *SQLQueue, r *Runner, ids ...string) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- r.Run(ctx) }()
+	deadline := time.Now().Add(5 * time.Second)
+	for _, id := range ids {
+		for {
+			job, err := q.Get(context.Background(), id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if job.Status == JobCompleted || job.Status == JobFailed {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("job %s still %s", id, job.Status)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunner_ExecutesJobs(t *testing.T) {
+	ctx := context.Background()
+	q := newTestQueue(t)
+	pipelines := pipelineMap{
+		"build": {Name: "build", Steps: []module.PipelineStep{funcStep{"compile", func(pc *module.PipelineContext) (map[string]any, error) {
+			return map[string]any{
+				"image": pc.Current["repo"].(string) + ":1",
+				"job":   pc.Current["_job_id"],
+				"key":   pc.Current["_idempotency_key"],
+			}, nil
+		}}}},
+		"broken": {Name: "broken", Steps: []module.PipelineStep{funcStep{"fail", func(*module.PipelineContext) (map[string]any, error) {
+			return nil, errors.New("boom")
+		}}}},
+	}
+	ok, _, _ := q.Enqueue(ctx, &Job{Pipeline: "build", WorkflowID: "wf-1", IdempotencyKey: "k1", Input: map[string]any{"repo": "app"}})
+	failing, _, _ := q.Enqueue(ctx, &Job{Pipeline: "broken", MaxAttempts: 1})
+	missing, _, _ := q.Enqueue(ctx, &Job{Pipeline: "unknown", MaxAttempts: 1})
+
+	reporter := &recordingReporter{}
+	r := NewRunner(Config{ID: "w1", PollInterval: 10 * time.Millisecond}, q, pipelines, reporter, nil)
+	runUntil(t, q, r, ok.ID, failing.ID, missing.ID)
+
+	job, _ := q.Get(ctx, ok.ID)
+	if job.Status != JobCompleted || job.Result["image"] != "app:1" || job.Result["job"] != ok.ID || job.Result["key"] != "k1" {
+		t.Errorf("build job = %+v", job)
+	}
+	job, _ = q.Get(ctx, failing.ID)
+	if job.Status != JobFailed || job.Error == "" {
+		t.Errorf("broken job = %+v", job)
+	}
+	job, _ = q.Get(ctx, missing.ID)
+	if job.Status != JobFailed || job.Error != "pipeline unknown is not configured on worker w1" {
+		t.Errorf("unknown pipeline job = %+v", job)
+	}
+
+	reporter.mu.Lock()
+	defer reporter.mu.Unlock()
+	var sawBuild bool
+	for _, e := range reporter.execs {
+		if e.ID == job.ExecutionID {
+			t.Errorf("execution reported for a pipeline the worker does not have: %+v", e)
+		}
+		if e.WorkflowID == "wf-1" {
+			sawBuild = e.Status == "completed" && e.TriggeredBy == "w1"
+		}
+	}
+	if !sawBuild || len(reporter.execs) != 2 {
+		t.Errorf("reported executions = %+v", reporter.execs)
+	}
+	if len(reporter.events) == 0 {
+		t.Error("no pipeline events were streamed to the reporter")
+	}
+
+	workers, _ := q.Workers(ctx)
+	if len(workers) != 1 || workers[0].ID != "w1" {
+		t.Errorf("workers = %+v", workers)
+	}
+}
@@ -0,0 +1,371 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GoCodeAlone/workflow/module"
+	"github.com/google/uuid"
+)
+
+// QueueName is the job_queue partition holding the executions placed on
+// workers.
+const QueueName = "executions"
+
+// workflowIDKey is the reserved job data key a job's WorkflowID is stored
+// under.
+const workflowIDKey = "_workflow_id"
+
+// SQLQueue is a Queue stored in the job_queue table of a module.SQLJobQueue,
+// the same table jobqueue.service modules use, with the workers' heartbeats
+// in a worker_processes table. PostgreSQL is the backend for workers on
+// other hosts; SQLite suits workers sharing the server's disk.
+//
+// Jobs are leased to workers (module.SQLJobQueue.ClaimLease), so two workers
+// never claim the same job, and a worker whose lease expired cannot
+// overwrite the outcome of the job's next attempt.
+type SQLQueue struct {
+	jobs  *module.SQLJobQueue
+	owned bool // close jobs on Close
+	now   func() time.Time
+}
+
+// NewSQLQueue creates a queue on an existing database and ensures the schema
+// exists.
+func NewSQLQueue(db *sql.DB, dialect module.JobQueueDialect) (*SQLQueue, error) {
+	jobs, err := module.NewSQLJobQueue(db, dialect, QueueName)
+	if err != nil {
+		return nil, err
+	}
+	return newSQLQueue(jobs, false)
+}
+
+// OpenQueue opens the queue at dsn: a postgres:// or postgresql:// URL, or
+// the path of a SQLite database.
+func OpenQueue(dsn string) (*SQLQueue, error) {
+	jobs, err := module.OpenJobQueue(dsn, QueueName)
+	if err != nil {
+		return nil, fmt.Errorf("open execution queue: %w", err)
+	}
+	q, err := newSQLQueue(jobs, true)
+	if err != nil {
+		jobs.Close()
+		return nil, err
+	}
+	return q, nil
+}
+
+func newSQLQueue(jobs *module.SQLJobQueue, owned bool) (*SQLQueue, error) {
+	q := &SQLQueue{jobs: jobs, owned: owned, now: time.Now}
+	if _, err := jobs.DB().Exec(`CREATE TABLE IF NOT EXISTS worker_processes (
+		id         TEXT PRIMARY KEY,
+		labels     TEXT NOT NULL DEFAULT '[]',
+		status     TEXT NOT NULL,
+		started_at BIGINT NOT NULL,
+		last_seen  BIGINT NOT NULL
+	)`); err != nil {
+		return nil, fmt.Errorf("init execution queue schema: %w", err)
+	}
+	return q, nil
+}
+
+// Close closes the database if the queue opened it.
+func (q *SQLQueue) Close() error {
+	if q.owned {
+		return q.jobs.Close()
+	}
+	return nil
+}
+
+// rebind rewrites ? placeholders to PostgreSQL's $n.
+func (q *SQLQueue) rebind(query string) string {
+	if q.jobs.Dialect() != module.JobQueuePostgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func (q *SQLQueue) exec(ctx context.Context, query string, args ...any) (int64, error) {
+	res, err := q.jobs.DB().ExecContext(ctx, q.rebind(query), args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// nanos stores t as Unix nanoseconds, 0 for the zero time.
+func nanos(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func fromNanos(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n).UTC()
+}
+
+// toQueuedJob converts a Job to the job_queue row it is stored as.
+func toQueuedJob(job *Job) *module.QueuedJob {
+	data := make(map[string]any, len(job.Input)+1)
+	for k, v := range job.Input {
+		data[k] = v
+	}
+	if job.WorkflowID != "" {
+		data[workflowIDKey] = job.WorkflowID
+	}
+	return &module.QueuedJob{
+		ID:             job.ID,
+		Pipeline:       job.Pipeline,
+		Data:           data,
+		RunAt:          job.EnqueuedAt,
+		EnqueuedAt:     job.EnqueuedAt,
+		IdempotencyKey: job.IdempotencyKey,
+		Labels:         job.Labels,
+		MaxAttempts:    job.MaxAttempts,
+	}
+}
+
+// fromQueuedJob converts a job_queue row to a Job.
+func fromQueuedJob(qj *module.QueuedJob) *Job {
+	job := &Job{
+		ID:             qj.ID,
+		IdempotencyKey: qj.IdempotencyKey,
+		Pipeline:       qj.Pipeline,
+		Labels:         qj.Labels,
+		Status:         JobStatus(qj.Status),
+		Attempts:       qj.Attempts,
+		MaxAttempts:    qj.MaxAttempts,
+		WorkerID:       qj.WorkerID,
+		Result:         qj.Result,
+		Error:          qj.LastError,
+		EnqueuedAt:     qj.EnqueuedAt,
+	}
+	if qj.Status == module.JobStatusSucceeded {
+		job.Status = JobCompleted
+	}
+	if len(qj.Data) > 0 {
+		job.Input = make(map[string]any, len(qj.Data))
+		for k, v := range qj.Data {
+			if k == workflowIDKey {
+				job.WorkflowID, _ = v.(string)
+				continue
+			}
+			job.Input[k] = v
+		}
+	}
+	// Each attempt gets its own execution ID, derived from the job and the
+	// attempt so every reader of the job agrees on it.
+	if qj.Attempts > 0 {
+		job.ExecutionID = uuid.NewSHA1(uuid.NameSpaceURL, []byte(qj.ID+"#"+strconv.Itoa(qj.Attempts))).String()
+	}
+	if qj.LeaseExpiresAt != nil {
+		job.LeaseExpiresAt = *qj.LeaseExpiresAt
+	}
+	if qj.StartedAt != nil {
+		job.StartedAt = *qj.StartedAt
+	}
+	if qj.FinishedAt != nil {
+		job.CompletedAt = *qj.FinishedAt
+	}
+	return job
+}
+
+// Enqueue implements Queue.
+func (q *SQLQueue) Enqueue(ctx context.Context, job *Job) (*Job, bool, error) {
+	if job.IdempotencyKey != "" {
+		if existing, err := q.jobs.GetByIdempotencyKey(ctx, job.IdempotencyKey); err != nil || existing != nil {
+			if existing == nil {
+				return nil, false, err
+			}
+			return fromQueuedJob(existing), true, nil
+		}
+	}
+	if job.ID == "" {
+		job.ID = uuid.NewString()
+	}
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = DefaultMaxAttempts
+	}
+	job.Status = JobPending
+	job.EnqueuedAt = q.now().UTC()
+	if err := q.jobs.Enqueue(ctx, toQueuedJob(job)); err != nil {
+		// A concurrent enqueue of the same key won the insert.
+		if errors.Is(err, module.ErrDuplicateJob) {
+			if existing, getErr := q.jobs.GetByIdempotencyKey(ctx, job.IdempotencyKey); getErr == nil && existing != nil {
+				return fromQueuedJob(existing), true, nil
+			}
+		}
+		return nil, false, err
+	}
+	cp := *job
+	return &cp, false, nil
+}
+
+// Claim implements Queue.
+func (q *SQLQueue) Claim(ctx context.Context, workerID string, labels []string, lease time.Duration) (*Job, error) {
+	qj, err := q.jobs.ClaimLease(ctx, q.now(), workerID, labels, lease)
+	if err != nil || qj == nil {
+		return nil, err
+	}
+	return fromQueuedJob(qj), nil
+}
+
+// Complete implements Queue.
+func (q *SQLQueue) Complete(ctx context.Context, jobID, workerID string, result map[string]any) error {
+	return q.jobs.CompleteLease(ctx, jobID, workerID, result)
+}
+
+// Fail implements Queue.
+func (q *SQLQueue) Fail(ctx context.Context, jobID, workerID, message string) error {
+	return q.jobs.FailLease(ctx, jobID, workerID, message)
+}
+
+// Heartbeat implements Queue.
+func (q *SQLQueue) Heartbeat(ctx context.Context, w Info, lease time.Duration) error {
+	now := q.now()
+	labels, err := json.Marshal(w.Labels)
+	if err != nil {
+		return fmt.Errorf("encode worker labels: %w", err)
+	}
+	n, err := q.exec(ctx,
+		`UPDATE worker_processes SET labels = ?, status = ?, last_seen = ? WHERE id = ?`,
+		string(labels), WorkerActive, nanos(now), w.ID)
+	if err != nil {
+		return fmt.Errorf("heartbeat worker %q: %w", w.ID, err)
+	}
+	if n == 0 {
+		started := w.Started
+		if started.IsZero() {
+			started = now
+		}
+		if _, err := q.exec(ctx,
+			`INSERT INTO worker_processes (id, labels, status, started_at, last_seen) VALUES (?, ?, ?, ?, ?)`,
+			w.ID, string(labels), WorkerActive, nanos(started), nanos(now)); err != nil {
+			return fmt.Errorf("register worker %q: %w", w.ID, err)
+		}
+	}
+	return q.jobs.RenewLeases(ctx, w.ID, now.Add(lease))
+}
+
+// Requeue implements Queue.
+func (q *SQLQueue) Requeue(ctx context.Context, now time.Time, workerTTL time.Duration) (int, error) {
+	n, err := q.jobs.ExpireLeases(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := q.exec(ctx,
+		`UPDATE worker_processes SET status = ? WHERE status = ? AND last_seen < ?`,
+		WorkerDead, WorkerActive, nanos(now.Add(-workerTTL))); err != nil {
+		return n, fmt.Errorf("mark dead workers: %w", err)
+	}
+	return n, nil
+}
+
+// Get implements Queue.
+func (q *SQLQueue) Get(ctx context.Context, id string) (*Job, error) {
+	qj, err := q.jobs.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if qj == nil {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, id)
+	}
+	return fromQueuedJob(qj), nil
+}
+
+// List implements Queue.
+func (q *SQLQueue) List(ctx context.Context, filter JobFilter) ([]*Job, error) {
+	status := string(filter.Status)
+	if filter.Status == JobCompleted {
+		status = module.JobStatusSucceeded
+	}
+	queued, err := q.jobs.List(ctx, module.JobQueueFilter{
+		Status:   status,
+		Pipeline: filter.Pipeline,
+		WorkerID: filter.WorkerID,
+		Limit:    filter.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	jobs := make([]*Job, 0, len(queued))
+	for _, qj := range queued {
+		jobs = append(jobs, fromQueuedJob(qj))
+	}
+	return jobs, nil
+}
+
+// Stats implements Queue.
+func (q *SQLQueue) Stats(ctx context.Context) (*Stats, error) {
+	s, err := q.jobs.Stats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stats := &Stats{
+		Pending:           s.Pending,
+		Running:           s.Running,
+		Completed:         s.Succeeded,
+		Failed:            s.Failed,
+		PendingByPipeline: s.PendingByPipeline,
+	}
+	if stats.PendingByPipeline == nil {
+		stats.PendingByPipeline = map[string]int{}
+	}
+	if s.OldestPending != nil {
+		stats.OldestPending = *s.OldestPending
+	}
+	return stats, nil
+}
+
+// Workers implements Queue.
+func (q *SQLQueue) Workers(ctx context.Context) ([]Info, error) {
+	s, err := q.jobs.Stats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := q.jobs.DB().QueryContext(ctx,
+		`SELECT id, labels, status, started_at, last_seen FROM worker_processes ORDER BY last_seen DESC, id`)
+	if err != nil {
+		return nil, fmt.Errorf("list workers: %w", err)
+	}
+	defer rows.Close()
+	workers := []Info{}
+	for rows.Next() {
+		var (
+			w             Info
+			labels        string
+			started, seen int64
+		)
+		if err := rows.Scan(&w.ID, &labels, &w.Status, &started, &seen); err != nil {
+			return nil, fmt.Errorf("list workers: %w", err)
+		}
+		_ = json.Unmarshal([]byte(labels), &w.Labels)
+		w.Started = fromNanos(started)
+		w.LastSeen = fromNanos(seen)
+		w.Running = s.RunningByWorker[w.ID]
+		workers = append(workers, w)
+	}
+	return workers, rows.Err()
+}
+
+var _ Queue = (*SQLQueue)(nil)
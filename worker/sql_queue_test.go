@@ -0,0 +1,196 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestQueue(t *testing.T) *SQLQueue {
+	t.Helper()
+	q, err := OpenQueue(filepath.Join(t.TempDir(), "queue.db"))
+	if err != nil {
+		t.Fatalf("open queue: %v", err)
+	}
+	t.Cleanup(func() { _ = q.Close() })
+	return q
+}
+
+func TestSQLQueue_EnqueueDeduplicatesIdempotencyKey(t *testing.T) {
+	ctx := context.Background()
+	q := newTestQueue(t)
+
+	first, dup, err := q.Enqueue(ctx, &Job{Pipeline: "build", IdempotencyKey: "k1", Input: map[string]any{"n": 1.0}})
+	if err != nil || dup {
+		t.Fatalf("first enqueue: dup=%v err=%v", dup, err)
+	}
+	again, dup, err := q.Enqueue(ctx, &Job{Pipeline: "build", IdempotencyKey: "k1", Input: map[string]any{"n": 2.0}})
+	if err != nil || !dup {
+		t.Fatalf("second enqueue: dup=%v err=%v", dup, err)
+	}
+	if again.ID != first.ID || again.Input["n"] != 1.0 {
+		t.Errorf("duplicate enqueue returned %+v, want job %s with the first input", again, first.ID)
+	}
+	// Jobs without a key are never deduplicated.
+	for range 2 {
+		if _, dup, err := q.Enqueue(ctx, &Job{Pipeline: "build"}); err != nil || dup {
+			t.Fatalf("keyless enqueue: dup=%v err=%v", dup, err)
+		}
+	}
+	stats, err := q.Stats(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Pending != 3 || stats.PendingByPipeline["build"] != 3 {
+		t.Errorf("stats = %+v, want 3 pending build jobs", stats)
+	}
+}
+
+func TestSQLQueue_ClaimMatchesLabels(t *testing.T) {
+	ctx := context.Background()
+	q := newTestQueue(t)
+	docker, _, _ := q.Enqueue(ctx, &Job{Pipeline: "build", Labels: []string{"docker-capable"}})
+	plain, _, _ := q.Enqueue(ctx, &Job{Pipeline: "notify"})
+
+	job, err := q.Claim(ctx, "w1", nil, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job == nil || job.ID != plain.ID {
+		t.Fatalf("unlabelled worker claimed %+v, want %s", job, plain.ID)
+	}
+	if job.Status != JobRunning || job.WorkerID != "w1" || job.Attempts != 1 || job.ExecutionID == "" {
+		t.Errorf("claimed job = %+v", job)
+	}
+	if job, err := q.Claim(ctx, "w1", nil, time.Minute); err != nil || job != nil {
+		t.Fatalf("claim with no matching job = %+v, %v", job, err)
+	}
+	job, err = q.Claim(ctx, "w2", []string{"docker-capable", "gpu"}, time.Minute)
+	if err != nil || job == nil || job.ID != docker.ID {
+		t.Fatalf("labelled worker claimed %+v, %v; want %s", job, err, docker.ID)
+	}
+}
+
+func TestSQLQueue_OutcomeRequiresLease(t *testing.T) {
+	ctx := context.Background()
+	q := newTestQueue(t)
+	queued, _, _ := q.Enqueue(ctx, &Job{Pipeline: "build"})
+	if _, err := q.Claim(ctx, "w1", nil, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := q.Complete(ctx, queued.ID, "w2", nil); !errors.Is(err, ErrLeaseLost) {
+		t.Errorf("complete by another worker: err = %v, want ErrLeaseLost", err)
+	}
+	if err := q.Complete(ctx, "missing", "w1", nil); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("complete unknown job: err = %v, want ErrJobNotFound", err)
+	}
+	if err := q.Complete(ctx, queued.ID, "w1", map[string]any{"image": "app:1"}); err != nil {
+		t.Fatal(err)
+	}
+	job, err := q.Get(ctx, queued.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != JobCompleted || job.Result["image"] != "app:1" || job.CompletedAt.IsZero() {
+		t.Errorf("completed job = %+v", job)
+	}
+	if err := q.Fail(ctx, queued.ID, "w1", "late"); !errors.Is(err, ErrLeaseLost) {
+		t.Errorf("fail after completion: err = %v, want ErrLeaseLost", err)
+	}
+}
+
+func TestSQLQueue_FailRetriesUntilMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	q := newTestQueue(t)
+	queued, _, _ := q.Enqueue(ctx, &Job{Pipeline: "build", MaxAttempts: 2})
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		job, err := q.Claim(ctx, "w1", nil, time.Minute)
+		if err != nil || job == nil {
+			t.Fatalf("attempt %d: claim = %+v, %v", attempt, job, err)
+		}
+		if err := q.Fail(ctx, queued.ID, "w1", "boom"); err != nil {
+			t.Fatal(err)
+		}
+		job, _ = q.Get(ctx, queued.ID)
+		want := JobPending
+		if attempt == 2 {
+			want = JobFailed
+		}
+		if job.Status != want || job.Attempts != attempt || job.Error != "boom" {
+			t.Errorf("after attempt %d: job = %+v, want status %s", attempt, job, want)
+		}
+	}
+}
+
+func TestSQLQueue_RequeueExpiredLeases(t *testing.T) {
+	ctx := context.Background()
+	q := newTestQueue(t)
+	queued, _, _ := q.Enqueue(ctx, &Job{Pipeline: "build"})
+	if err := q.Heartbeat(ctx, Info{ID: "w1", Labels: []string{"docker-capable"}}, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.Claim(ctx, "w1", nil, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	workers, err := q.Workers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(workers) != 1 || workers[0].Status != WorkerActive || workers[0].Running != 1 {
+		t.Fatalf("workers = %+v, want w1 active with 1 running job", workers)
+	}
+
+	// Before the lease expires nothing is requeued.
+	if n, err := q.Requeue(ctx, time.Now(), time.Hour); err != nil || n != 0 {
+		t.Fatalf("early requeue = %d, %v", n, err)
+	}
+	later := time.Now().Add(2 * time.Minute)
+	if n, err := q.Requeue(ctx, later, time.Minute); err != nil || n != 1 {
+		t.Fatalf("requeue = %d, %v; want 1", n, err)
+	}
+	job, _ := q.Get(ctx, queued.ID)
+	if job.Status != JobPending || job.Error != "lease expired on worker w1" {
+		t.Errorf("requeued job = %+v", job)
+	}
+	workers, _ = q.Workers(ctx)
+	if workers[0].Status != WorkerDead || workers[0].Running != 0 {
+		t.Errorf("worker after TTL = %+v, want dead with no running jobs", workers[0])
+	}
+
+	// The old holder's outcome is discarded; another worker picks the job up.
+	if err := q.Complete(ctx, queued.ID, "w1", nil); !errors.Is(err, ErrLeaseLost) {
+		t.Errorf("complete by expired holder: err = %v, want ErrLeaseLost", err)
+	}
+	job, err = q.Claim(ctx, "w2", nil, time.Minute)
+	if err != nil || job == nil || job.Attempts != 2 {
+		t.Fatalf("reclaim = %+v, %v; want second attempt", job, err)
+	}
+}
+
+func TestSQLQueue_ListFilters(t *testing.T) {
+	ctx := context.Background()
+	q := newTestQueue(t)
+	_, _, _ = q.Enqueue(ctx, &Job{Pipeline: "build"})
+	_, _, _ = q.Enqueue(ctx, &Job{Pipeline: "notify"})
+	if _, err := q.Claim(ctx, "w1", nil, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	jobs, err := q.List(ctx, JobFilter{Pipeline: "notify"})
+	if err != nil || len(jobs) != 1 || jobs[0].Pipeline != "notify" {
+		t.Fatalf("list by pipeline = %+v, %v", jobs, err)
+	}
+	jobs, err = q.List(ctx, JobFilter{WorkerID: "w1", Status: JobRunning})
+	if err != nil || len(jobs) != 1 || jobs[0].Pipeline != "build" {
+		t.Fatalf("list by worker = %+v, %v", jobs, err)
+	}
+	jobs, err = q.List(ctx, JobFilter{Limit: 1})
+	if err != nil || len(jobs) != 1 {
+		t.Fatalf("list with limit = %+v, %v", jobs, err)
+	}
+}